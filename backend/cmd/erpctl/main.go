@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/config"
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/db/seeds"
+	"ERP-ONSMART/backend/internal/logger"
+	apikeysService "ERP-ONSMART/backend/internal/modules/apikeys/service"
+	bankingService "ERP-ONSMART/backend/internal/modules/banking/service"
+	productsService "ERP-ONSMART/backend/internal/modules/products/service"
+	salesService "ERP-ONSMART/backend/internal/modules/sales/service"
+	shippingService "ERP-ONSMART/backend/internal/modules/shipping/service"
+	userModels "ERP-ONSMART/backend/internal/modules/users/models"
+	usersService "ERP-ONSMART/backend/internal/modules/users/service"
+	webhookModels "ERP-ONSMART/backend/internal/modules/webhooks/models"
+	webhooksService "ERP-ONSMART/backend/internal/modules/webhooks/service"
+	"ERP-ONSMART/backend/internal/tenant"
+)
+
+// cfg é a configuração carregada antes de executar o comando
+var cfg *config.Config
+
+// command é um subcomando do erpctl
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"migrate", "aplica as migrações pendentes do banco", runMigrate},
+	{"create-admin", "cria um administrador ativo, sem convite", runCreateAdmin},
+	{"rotate-api-key", "emite uma nova chave de API e revoga a antiga", runRotateAPIKey},
+	{"recompute-profitability", "recalcula o valor e o lucro dos processos de venda de um período", runRecomputeProfitability},
+	{"reindex", "recalcula o índice de busca do catálogo de produtos", runReindex},
+	{"webhook-replay", "processa de novo os webhooks recebidos que falharam", runWebhookReplay},
+	{"wipe", "apaga os dados de negócio de todas as empresas (só em development e test)", runWipe},
+}
+
+// erpctl executa as tarefas de manutenção do ERP sem subir o servidor HTTP, com a mesma
+// configuração (.env, config.yaml e variáveis de ambiente) do backend.
+// Uso (na raiz do repositório): go run ./backend/cmd/erpctl <comando> [flags]
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == os.Args[1] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "erpctl: comando desconhecido %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if _, err := logger.InitLogger(); err != nil {
+		log.Fatalf("[erpctl]: Erro ao inicializar logger: %v", err)
+	}
+	defer logger.Logger.Sync()
+	var err error
+	if cfg, err = config.LoadConfig(); err != nil {
+		log.Fatalf("[erpctl]: Erro ao carregar configurações: %v", err)
+	}
+	// Os horários são gravados em UTC, como no servidor
+	time.Local = time.UTC
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		log.Fatalf("[erpctl] %s: %v", cmd.name, err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Uso: erpctl <comando> [flags]")
+	fmt.Fprintln(os.Stderr, "\nComandos:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nUse erpctl <comando> -h para as flags de cada comando.")
+}
+
+// companyContext é o contexto das operações: a empresa informada ou, com 0, todas as empresas
+func companyContext(companyID int) context.Context {
+	if companyID > 0 {
+		return tenant.WithCompany(context.Background(), companyID)
+	}
+	return tenant.AllCompanies(context.Background())
+}
+
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Parse(args)
+
+	if err := db.RunMigrations(); err != nil {
+		return err
+	}
+	log.Println("[erpctl]: Migrações aplicadas")
+	return nil
+}
+
+func runCreateAdmin(args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	username := flags.String("username", "", "Username do administrador")
+	email := flags.String("email", "", "E-mail do administrador")
+	name := flags.String("name", "", "Nome do administrador (padrão: o username)")
+	companyID := flags.Int("company", tenant.DefaultCompanyID, "Empresa do administrador")
+	flags.Parse(args)
+
+	// A senha é lida da entrada padrão, para não ficar no histórico do shell nem na lista de processos
+	fmt.Fprint(os.Stderr, "Senha: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("falha ao ler a senha: %w", err)
+	}
+
+	user, err := usersService.CreateAdmin(userModels.CreateAdminInput{
+		CompanyID: *companyID,
+		Username:  *username,
+		Email:     *email,
+		Nome:      *name,
+		Password:  strings.TrimRight(password, "\r\n"),
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[erpctl]: Administrador %q criado (id %d, empresa %d)", user.Username, user.ID, user.CompanyID)
+	return nil
+}
+
+func runRotateAPIKey(args []string) error {
+	flags := flag.NewFlagSet("rotate-api-key", flag.ExitOnError)
+	id := flags.Int("id", 0, "ID da chave de API")
+	companyID := flags.Int("company", 0, "Empresa da chave (0: qualquer empresa)")
+	flags.Parse(args)
+	if *id <= 0 {
+		return fmt.Errorf("informe o ID da chave com -id")
+	}
+
+	rotated, err := apikeysService.RotateKey(companyContext(*companyID), *id, "erpctl")
+	if err != nil {
+		return err
+	}
+	log.Printf("[erpctl]: Chave %d revogada; nova chave %d (%s)", *id, rotated.APIKey.ID, rotated.APIKey.Prefix)
+	// A chave em texto claro só é exibida aqui: configure-a na integração antes de descartar a saída
+	fmt.Println(rotated.Key)
+	return nil
+}
+
+func runRecomputeProfitability(args []string) error {
+	flags := flag.NewFlagSet("recompute-profitability", flag.ExitOnError)
+	from := flags.String("from", "", "Primeiro dia do período (AAAA-MM-DD)")
+	to := flags.String("to", "", "Último dia do período, inclusive (AAAA-MM-DD)")
+	companyID := flags.Int("company", 0, "Empresa dos processos (0: todas)")
+	flags.Parse(args)
+
+	start, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		return fmt.Errorf("-from inválido, use AAAA-MM-DD: %w", err)
+	}
+	end, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		return fmt.Errorf("-to inválido, use AAAA-MM-DD: %w", err)
+	}
+
+	count, err := salesService.RecomputeProfitability(companyContext(*companyID), start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	log.Printf("[erpctl]: Lucratividade recalculada em %d processos de %s a %s", count, *from, *to)
+	return nil
+}
+
+func runReindex(args []string) error {
+	flags := flag.NewFlagSet("reindex", flag.ExitOnError)
+	flags.Parse(args)
+
+	count, err := productsService.RebuildSearchIndex(tenant.AllCompanies(context.Background()))
+	if err != nil {
+		return err
+	}
+	log.Printf("[erpctl]: Índice de busca reconstruído; %d produtos estavam desatualizados", count)
+	return nil
+}
+
+// webhookProcessors reprocessa os webhooks gravados de cada origem
+var webhookProcessors = map[string]webhooksService.Processor{
+	webhookModels.SourcePix:     bankingService.ReplayPixWebhook,
+	webhookModels.SourceCarrier: shippingService.ReplayCarrierWebhook,
+}
+
+func runWebhookReplay(args []string) error {
+	flags := flag.NewFlagSet("webhook-replay", flag.ExitOnError)
+	id := flags.Int("id", 0, "ID do webhook com falha (0: todos os do filtro)")
+	source := flags.String("source", "", "Origem dos webhooks ("+webhookModels.SourcePix+" ou "+webhookModels.SourceCarrier+"; vazio: todas)")
+	since := flags.String("since", "", "Recebidos a partir do dia (AAAA-MM-DD)")
+	limit := flags.Int("limit", webhookModels.DefaultReplayLimit, "Quantidade máxima de webhooks")
+	flags.Parse(args)
+
+	filter := webhookModels.ReplayFilter{ID: *id, Source: *source, Limit: *limit}
+	if *since != "" {
+		start, err := time.Parse("2006-01-02", *since)
+		if err != nil {
+			return fmt.Errorf("-since inválido, use AAAA-MM-DD: %w", err)
+		}
+		filter.Since = start
+	}
+
+	summary, err := webhooksService.Replay(context.Background(), filter, webhookProcessors)
+	if err != nil {
+		return err
+	}
+	for _, failure := range summary.Failures {
+		log.Printf("[erpctl]: Webhook %d falhou de novo: %s", failure.DeliveryID, failure.Error)
+	}
+	log.Printf("[erpctl]: %d webhooks reprocessados, %d com sucesso", summary.Replayed, summary.Processed)
+	if len(summary.Failures) > 0 {
+		return fmt.Errorf("%d webhooks continuam com falha", len(summary.Failures))
+	}
+	return nil
+}
+
+func runWipe(args []string) error {
+	flags := flag.NewFlagSet("wipe", flag.ExitOnError)
+	confirm := flags.Bool("yes", false, "Confirma que os dados de negócio de todas as empresas serão apagados")
+	profile := flags.String("profile", "", "Perfil de dados a carregar depois ("+strings.Join(seeds.ProfileNames(), ", ")+")")
+	flags.Parse(args)
+
+	if err := seeds.CheckWipeAllowed(cfg.Server.Env); err != nil {
+		return err
+	}
+	if !*confirm {
+		return fmt.Errorf("os dados de negócio de todas as empresas serão apagados; confirme com -yes")
+	}
+
+	database, err := db.OpenDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := seeds.Wipe(database, cfg.Server.Env); err != nil {
+		return err
+	}
+	if *profile != "" {
+		if err := seeds.ExecuteProfile(database, *profile, time.Now()); err != nil {
+			return err
+		}
+		log.Printf("[erpctl]: Perfil %q carregado", *profile)
+	}
+	return nil
+}
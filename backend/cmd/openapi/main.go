@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"ERP-ONSMART/backend/internal/openapi"
+	"ERP-ONSMART/backend/internal/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gera backend/internal/openapi/openapi.json a partir das rotas de routes.SetupRoutes.
+// Uso (na raiz do repositório): make openapi
+func main() {
+	root := flag.String("root", ".", "Diretório raiz do módulo (onde fica o go.mod)")
+	out := flag.String("out", "backend/internal/openapi/openapi.json", "Arquivo de saída, relativo à raiz")
+	flag.Parse()
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	routes.SetupRoutes(router)
+
+	annotations, err := openapi.ParseAnnotations(*root, filepath.Join(*root, "backend", "internal"))
+	if err != nil {
+		log.Fatalf("[openapi]: Erro ao ler a documentação dos handlers: %v", err)
+	}
+
+	data, err := openapi.Marshal(openapi.Generate(router.Routes(), annotations))
+	if err != nil {
+		log.Fatalf("[openapi]: Erro ao serializar a especificação: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(*root, *out), data, 0o644); err != nil {
+		log.Fatalf("[openapi]: Erro ao gravar a especificação: %v", err)
+	}
+	log.Printf("[openapi]: %d rotas documentadas em %s", len(router.Routes()), *out)
+}
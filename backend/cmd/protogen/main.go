@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"ERP-ONSMART/backend/internal/grpcserver"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/models"
+)
+
+// Gera backend/proto/erp/v1/erp.proto a partir do descritor da API gRPC interna, para os
+// clientes gerarem os stubs com o protoc. Uso (na raiz do repositório): make proto
+func main() {
+	root := flag.String("root", ".", "Diretório raiz do módulo (onde fica o go.mod)")
+	out := flag.String("out", filepath.Join("backend", "proto", models.ProtoPath), "Arquivo de saída, relativo à raiz")
+	flag.Parse()
+
+	file, err := models.NewFile()
+	if err != nil {
+		log.Fatalf("[protogen]: Descritor inválido: %v", err)
+	}
+
+	path := filepath.Join(*root, *out)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Fatalf("[protogen]: Erro ao criar o diretório de saída: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(grpcserver.PrintProto(file, models.ProtoHeader)), 0o644); err != nil {
+		log.Fatalf("[protogen]: Erro ao gravar o arquivo: %v", err)
+	}
+	log.Printf("[protogen]: %d serviços gerados em %s", file.Services().Len(), *out)
+}
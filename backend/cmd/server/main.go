@@ -1,14 +1,35 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"flag"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"ERP-ONSMART/backend/internal/config"
 	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/db/seeds"
 	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	analyticsService "ERP-ONSMART/backend/internal/modules/analytics/service"
+	backupsService "ERP-ONSMART/backend/internal/modules/backups/service"
+	contactService "ERP-ONSMART/backend/internal/modules/contact/service"
+	contractsService "ERP-ONSMART/backend/internal/modules/contracts/service"
+	ecommerceService "ERP-ONSMART/backend/internal/modules/ecommerce/service"
+	featureFlags "ERP-ONSMART/backend/internal/modules/featureflags/service"
+	fileExchangeService "ERP-ONSMART/backend/internal/modules/fileexchange/service"
+	financeService "ERP-ONSMART/backend/internal/modules/finance/service"
+	followupService "ERP-ONSMART/backend/internal/modules/followup/service"
+	grpcAPI "ERP-ONSMART/backend/internal/modules/grpcapi/handler"
+	purchasingService "ERP-ONSMART/backend/internal/modules/purchasing/service"
+	reportsService "ERP-ONSMART/backend/internal/modules/reports/service"
+	salesService "ERP-ONSMART/backend/internal/modules/sales/service"
+	shippingService "ERP-ONSMART/backend/internal/modules/shipping/service"
+	slaService "ERP-ONSMART/backend/internal/modules/sla/service"
+	tasksService "ERP-ONSMART/backend/internal/modules/tasks/service"
 	"ERP-ONSMART/backend/internal/routes"
 
 	"github.com/gin-contrib/cors"
@@ -28,6 +49,7 @@ func main() {
 	seedRentals := flag.Int("rentals", 100, "Número de aluguéis a serem gerados")
 	seedSales := flag.Int("sales", 400, "Número de vendas a serem geradas")
 	seedValue := flag.Int64("seed-value", 42, "Valor da seed para reprodutibilidade")
+	seedProfile := flag.String("seed-profile", "", "Perfil de dados a carregar ("+strings.Join(seeds.ProfileNames(), ", ")+")")
 	flag.Parse()
 
 	// Inicializa o logger
@@ -36,12 +58,19 @@ func main() {
 	}
 	defer logger.Logger.Sync()
 
-	// Carrega configurações do .env
+	// Carrega configurações do .env, do config.yaml e das variáveis de ambiente
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Erro ao carregar configurações: %v", err)
 	}
 
+	// Os horários são calculados e gravados em UTC; o fuso de cada empresa é aplicado só nas
+	// datas de calendário (vencimentos, relatórios diários), ver o pacote localtime
+	time.Local = time.UTC
+
+	// Padrões das feature flags; os valores gravados no banco prevalecem
+	featureFlags.Configure(cfg.Features, cfg.FeatureFlagsRefresh)
+
 	// Executa as migrations
 	if err := db.RunMigrations(); err != nil {
 		// Não aborta a execução em caso de erro nas migrations
@@ -49,14 +78,16 @@ func main() {
 	}
 
 	// Executa seeds se solicitado via flag
-	if *runSeeds {
+	if *runSeeds || *seedProfile != "" {
 		log.Println("[main.go]: Iniciando geração de dados mock para desenvolvimento...")
 
 		// Obtém conexão com o banco de dados
 		database, err := db.OpenDB()
 		if err != nil {
 			log.Printf("[main.go]: Erro ao conectar ao banco para seeds: %v", err)
-		} else {
+		} else if err := seedProfileData(database, *seedProfile); err != nil {
+			log.Printf("[main.go]: Erro ao executar seeds: %v", err)
+		} else if *runSeeds {
 			// Configura os parâmetros de seed
 			seedConfig := seeds.SeedConfig{
 				CustomersCount:    *seedCustomers,
@@ -80,24 +111,131 @@ func main() {
 		}
 	}
 
+	// Estado das conexões do primário e da réplica de leitura nas métricas db_pool_* (GET /metrics)
+	if provider, err := db.OpenProvider(); err != nil {
+		log.Printf("[main.go]: Aviso ao abrir o banco para as métricas do pool: %v", err)
+	} else {
+		if err := metrics.RegisterPool(provider.Writer()); err != nil {
+			log.Printf("[main.go]: Aviso ao registrar o pool do primário: %v", err)
+		}
+		if provider.HasReplica() {
+			if err := metrics.RegisterPool(provider.Reader()); err != nil {
+				log.Printf("[main.go]: Aviso ao registrar o pool da réplica: %v", err)
+			}
+		}
+	}
+
+	// Serviços do processo de venda (pedidos, faturas e entregas), montados uma vez com os
+	// repositórios e o relógio do sistema; sem eles, os repositórios abrem na primeira requisição
+	if services, err := salesService.NewServices(); err != nil {
+		log.Printf("[main.go]: Aviso ao montar os serviços de vendas: %v", err)
+	} else {
+		salesService.Configure(services)
+	}
+
 	router := gin.Default()
 
 	// Middleware CORS manual (substitui cors.New)
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // ou {"*"} se não usar credenciais
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Idempotency-Key", "X-Company-ID", "X-API-Key"},
+		ExposeHeaders:    []string{"Idempotent-Replayed", "X-Query-Count", "Server-Timing"},
 		AllowCredentials: true,
 	}))
 
 	// Configura rotas
 	routes.SetupRoutes(router)
 
-	fmt.Printf("Ambiente: %s\n", cfg.Env)
-	fmt.Printf("Servidor rodando em http://localhost:%s\n", cfg.Port)
+	// Consulta periódica do rastreamento nas transportadoras
+	if cfg.Jobs.TrackingPollInterval > 0 {
+		shippingService.StartTrackingPoller(context.Background(), cfg.Jobs.TrackingPollInterval)
+	}
+
+	// Geração periódica de sugestões de compra pelo ponto de reposição
+	if cfg.Jobs.ReorderScanInterval > 0 {
+		purchasingService.StartReorderScheduler(context.Background(), cfg.Jobs.ReorderScanInterval)
+	}
+
+	// Sincronização periódica das lojas virtuais (pedidos, estoque, preços e rastreamento)
+	if cfg.Jobs.EcommerceSyncInterval > 0 {
+		ecommerceService.StartSyncScheduler(context.Background(), cfg.Jobs.EcommerceSyncInterval)
+	}
+
+	// Recálculo periódico das pontuações RFM e do segmento dos clientes
+	if cfg.Jobs.RFMScoreInterval > 0 {
+		analyticsService.StartRFMScheduler(context.Background(), cfg.Jobs.RFMScoreInterval)
+	}
+
+	// Envio por e-mail dos relatórios agendados
+	if cfg.Jobs.ReportScheduleInterval > 0 {
+		reportsService.StartReportScheduler(context.Background(), cfg.Jobs.ReportScheduleInterval)
+	}
+
+	// Alertas de vencimento dos contratos com clientes e fornecedores
+	if cfg.Jobs.ContractExpiryInterval > 0 {
+		contractsService.StartContractExpiryScheduler(context.Background(), cfg.Jobs.ContractExpiryInterval)
+	}
+
+	// Avaliação periódica do SLA das entregas e processos de venda
+	if cfg.Jobs.SLAEvalInterval > 0 {
+		slaService.StartSLAScheduler(context.Background(), cfg.Jobs.SLAEvalInterval)
+	}
+
+	// Acompanhamento dos processos de venda parados (tarefas, lembretes e cancelamentos)
+	if cfg.Jobs.FollowupInterval > 0 {
+		followupService.StartFollowupScheduler(context.Background(), cfg.Jobs.FollowupInterval)
+	}
+
+	// Tarefas de cobrança das faturas vencidas para os vendedores
+	if cfg.Jobs.TaskAutomationInterval > 0 {
+		tasksService.StartTaskAutomationScheduler(context.Background(), cfg.Jobs.TaskAutomationInterval)
+	}
+
+	// Revalidação periódica dos CNPJs dos contatos na Receita Federal
+	if cfg.Jobs.CNPJRevalidationInterval > 0 {
+		contactService.StartCNPJRevalidationScheduler(context.Background(), cfg.Jobs.CNPJRevalidationInterval)
+	}
+
+	// Rotinas de troca de arquivos por SFTP/FTP e a fila das execuções, com novas tentativas
+	if cfg.Jobs.FileExchangeInterval > 0 {
+		fileExchangeService.StartFileExchangeRunner(context.Background(), cfg.Jobs.FileExchangeInterval)
+	}
+
+	// Cotações diárias das moedas (PTAX/BCE) e reavaliação cambial das faturas no fim do mês
+	if cfg.Jobs.ExchangeRateInterval > 0 {
+		financeService.StartExchangeRateScheduler(context.Background(), cfg.Jobs.ExchangeRateInterval)
+	}
+
+	// Fila dos backups do banco, das restaurações e das exportações dos dados das empresas
+	if cfg.Backup.RunnerInterval > 0 {
+		backupsService.StartBackupRunner(context.Background(), cfg.Backup.RunnerInterval)
+	}
+
+	// API gRPC interna (PDV, backend mobile), ao lado do servidor HTTP
+	if cfg.Server.GRPCPort != "" {
+		go func() {
+			if err := grpcAPI.Serve(context.Background(), ":"+cfg.Server.GRPCPort); err != nil {
+				log.Fatalf("Erro ao iniciar o servidor gRPC: %v", err)
+			}
+		}()
+		fmt.Printf("API gRPC rodando em localhost:%s\n", cfg.Server.GRPCPort)
+	}
+
+	fmt.Printf("Ambiente: %s\n", cfg.Server.Env)
+	fmt.Printf("Servidor rodando em http://localhost:%s\n", cfg.Server.Port)
 
 	// Inicia o servidor
-	if err := router.Run(":" + cfg.Port); err != nil {
+	if err := router.Run(":" + cfg.Server.Port); err != nil {
 		log.Fatalf("Erro ao iniciar o servidor: %v", err)
 	}
 }
+
+// seedProfileData carrega o perfil de dados informado, antes dos seeds aleatórios. Para recarregar
+// um perfil, apague antes os dados de negócio com erpctl wipe.
+func seedProfileData(database *sql.DB, profile string) error {
+	if profile != "" {
+		return seeds.ExecuteProfile(database, profile, time.Now())
+	}
+	return nil
+}
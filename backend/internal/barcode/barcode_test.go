@@ -0,0 +1,259 @@
+package barcode
+
+import (
+	"bytes"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode128Patterns(t *testing.T) {
+	for value, pattern := range code128Patterns {
+		total := 0
+		for _, width := range pattern {
+			total += int(width - '0')
+		}
+		if value == code128Stop {
+			assert.Equal(t, 13, total, "o símbolo de parada tem 13 módulos")
+			continue
+		}
+		assert.Equal(t, 11, total, "o valor %d deveria ter 11 módulos", value)
+	}
+}
+
+func TestCode128(t *testing.T) {
+	t.Run("subconjunto B com dígito verificador", func(t *testing.T) {
+		values, err := code128Values("Wikipedia")
+		require.NoError(t, err)
+		assert.Equal(t, code128StartB, values[0])
+
+		symbol, err := Code128("Wikipedia")
+		require.NoError(t, err)
+		// início + 9 caracteres + verificador (11 módulos cada) + parada (13)
+		assert.Equal(t, 11*11+13, symbol.Width)
+		assert.True(t, symbol.Linear())
+		assert.True(t, symbol.Dark(0, 0), "o código começa com barra")
+		assert.True(t, symbol.Dark(symbol.Width-1, 0), "o código termina com barra")
+
+		// verificador: (104 + Σ valor x posição) mod 103 = 88
+		assert.Equal(t, code128Patterns[88], widthsAt(symbol, 10*11, 11))
+	})
+
+	t.Run("dígitos usam o subconjunto C", func(t *testing.T) {
+		values, err := code128Values("7891234")
+		require.NoError(t, err)
+		assert.Equal(t, []int{code128StartC, 78, 91, 23, code128CodeB, int('4') - 32}, values)
+	})
+
+	t.Run("rejeita caracteres fora do ASCII imprimível", func(t *testing.T) {
+		_, err := Code128("Ação")
+		assert.Error(t, err)
+		_, err = Code128("")
+		assert.Error(t, err)
+	})
+}
+
+func TestITF(t *testing.T) {
+	t.Run("boleto de 44 dígitos", func(t *testing.T) {
+		symbol, err := ITF("23793381286000000000300000000000000000000000")
+		require.NoError(t, err)
+		// início (4) + 22 pares de 18 módulos + parada (5)
+		assert.Equal(t, 4+22*18+5, symbol.Width)
+		assert.True(t, symbol.Linear())
+		assert.Equal(t, "1111", widthsAt(symbol, 0, 4), "guarda de início")
+		assert.Equal(t, "311", widthsAt(symbol, symbol.Width-5, 5), "guarda de parada")
+	})
+
+	t.Run("par 2 e 3 intercalado", func(t *testing.T) {
+		symbol, err := ITF("23")
+		require.NoError(t, err)
+		// barras do 2 (NWNNW) intercaladas com os espaços do 3 (WWNNN)
+		assert.Equal(t, "1333111131", widthsAt(symbol, 4, 18))
+	})
+
+	t.Run("rejeita letras e quantidade ímpar", func(t *testing.T) {
+		_, err := ITF("12A4")
+		assert.Error(t, err)
+		_, err = ITF("123")
+		assert.Error(t, err)
+	})
+}
+
+// widthsAt lê as larguras das barras e espaços de um símbolo de 11 módulos a partir de start
+func widthsAt(symbol *Symbol, start, length int) string {
+	var b strings.Builder
+	run := 1
+	for x := start + 1; x <= start+length; x++ {
+		if x < start+length && symbol.Dark(x, 0) == symbol.Dark(x-1, 0) {
+			run++
+			continue
+		}
+		b.WriteByte(byte('0' + run))
+		run = 1
+	}
+	return b.String()
+}
+
+func TestReedSolomon(t *testing.T) {
+	// exemplo "HELLO WORLD" 1-M da norma
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ec := reedSolomonRemainder(data, reedSolomonDivisor(10))
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ec)
+}
+
+func TestQRFormatBits(t *testing.T) {
+	// nível M, máscaras 0 e 5, conforme a tabela de informações de formato da norma
+	assert.Equal(t, 0b101010000010010, qrFormatBits(0))
+	assert.Equal(t, 0b100000011001110, qrFormatBits(5))
+}
+
+func TestQRVersionBits(t *testing.T) {
+	// versões 7 e 13, conforme a tabela de informações de versão da norma
+	assert.Equal(t, 0x07C94, qrVersionBits(7))
+	assert.Equal(t, 0x0D847, qrVersionBits(13))
+}
+
+func TestQRAlignmentPositions(t *testing.T) {
+	assert.Equal(t, []int{6, 18}, newQRMatrix(2).alignmentPositions())
+	assert.Equal(t, []int{6, 22, 38}, newQRMatrix(7).alignmentPositions())
+	assert.Equal(t, []int{6, 28, 50}, newQRMatrix(10).alignmentPositions())
+	assert.Equal(t, []int{6, 34, 62}, newQRMatrix(13).alignmentPositions())
+}
+
+func TestQR(t *testing.T) {
+	t.Run("escolhe a menor versão", func(t *testing.T) {
+		symbol, err := QR("PRD-1")
+		require.NoError(t, err)
+		assert.Equal(t, 21, symbol.Width)
+		assert.Equal(t, 21, symbol.Height)
+
+		symbol, err = QR(strings.Repeat("A", 40))
+		require.NoError(t, err)
+		assert.Equal(t, 29, symbol.Width, "40 bytes exigem a versão 3")
+
+		symbol, err = QR(strings.Repeat("A", 106))
+		require.NoError(t, err)
+		assert.Equal(t, 41, symbol.Width, "106 bytes cabem na versão 6")
+
+		symbol, err = QR(strings.Repeat("A", QRMaxBytes))
+		require.NoError(t, err)
+		assert.Equal(t, 69, symbol.Width)
+
+		_, err = QR(strings.Repeat("A", QRMaxBytes+1))
+		assert.Error(t, err)
+	})
+
+	t.Run("padrões de localização e módulo escuro", func(t *testing.T) {
+		symbol, err := QR("DLV-2026-000001-P1")
+		require.NoError(t, err)
+		size := symbol.Width
+		for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+			assert.True(t, symbol.Dark(corner[0], corner[1]))
+			assert.True(t, symbol.Dark(corner[0]+3, corner[1]+3), "centro do padrão de localização")
+			assert.False(t, symbol.Dark(corner[0]+1, corner[1]+1))
+		}
+		assert.True(t, symbol.Dark(8, size-8), "módulo escuro fixo")
+	})
+
+	t.Run("dados lidos de volta batem com as palavras-código", func(t *testing.T) {
+		for _, text := range []string{"PRD-42", "BIN-A-01-03", strings.Repeat("x", 60), strings.Repeat("9", 100),
+			strings.Repeat("p", 150), strings.Repeat("7", 213), strings.Repeat("z", QRMaxBytes)} {
+			symbol, err := QR(text)
+			require.NoError(t, err)
+
+			version := (symbol.Width - 17) / 4
+			q := newQRMatrix(version)
+			q.drawFunctionPatterns()
+			q.modules = append([]bool(nil), symbol.modules...)
+
+			mask := -1
+			for m := range 8 {
+				if readFormatBits(symbol) == qrFormatBits(m) {
+					mask = m
+				}
+			}
+			require.GreaterOrEqual(t, mask, 0, "informação de formato inválida para %q", text)
+			q.applyMask(mask)
+
+			assert.Equal(t, qrCodewords(version, []byte(text)), readCodewords(q), "conteúdo %q", text)
+		}
+	})
+}
+
+// readFormatBits lê a primeira cópia da informação de formato
+func readFormatBits(symbol *Symbol) int {
+	positions := [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}}
+	bits := 0
+	for i, p := range positions {
+		if symbol.Dark(p[0], p[1]) {
+			bits |= 1 << i
+		}
+	}
+	return bits
+}
+
+// readCodewords percorre a grade na ordem de posicionamento e remonta as palavras-código
+func readCodewords(q *qrMatrix) []byte {
+	total := qrVersions[q.version-1].total
+	var bits bitBuffer
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range q.size {
+			for j := range 2 {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !q.function[y*q.size+x] && len(bits) < total*8 {
+					bits = append(bits, q.get(x, y))
+				}
+			}
+		}
+	}
+	return bits.bytes()
+}
+
+func TestWritePNG(t *testing.T) {
+	symbol, err := QR("PRD-7")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, symbol.WritePNG(&buf, 4))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, (21+2*qrQuietZone)*4, img.Bounds().Dx())
+}
+
+func TestWriteLabelsPDF(t *testing.T) {
+	bar, err := Code128("7891234567895")
+	require.NoError(t, err)
+	qr, err := QR("BIN-A-01")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteLabelsPDF(&buf, []Label{
+		{Symbol: bar, Lines: []string{"Parafuso (caixa)", "SKU PAR-10"}},
+		{Symbol: qr, Lines: []string{"Corredor A"}},
+	}))
+	pdf := buf.String()
+
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	assert.Contains(t, pdf, "/Count 2")
+	assert.Contains(t, pdf, `(Parafuso \(caixa\)) Tj`, "parênteses escapados no texto")
+
+	// startxref aponta para a tabela xref
+	start := strings.LastIndex(pdf, "startxref\n")
+	require.Greater(t, start, 0)
+	xref := strings.Index(pdf, "xref\n0 ")
+	assert.Contains(t, pdf[start:], "\n"+strconv.Itoa(xref)+"\n")
+
+	assert.Error(t, WriteLabelsPDF(&buf, nil))
+}
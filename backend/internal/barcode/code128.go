@@ -0,0 +1,92 @@
+package barcode
+
+import (
+	"fmt"
+)
+
+// Símbolos especiais do Code 128
+const (
+	code128CodeB  = 100
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// code128Patterns são as larguras (barra, espaço, barra, ...) de cada valor do Code 128, em módulos
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code128 codifica o texto em Code 128. Textos só com dígitos usam o subconjunto C (dois dígitos
+// por símbolo, com o último dígito ímpar no subconjunto B); os demais usam o subconjunto B, que
+// aceita os caracteres ASCII imprimíveis.
+func Code128(text string) (*Symbol, error) {
+	if text == "" {
+		return nil, fmt.Errorf("barcode: texto vazio")
+	}
+
+	values, err := code128Values(text)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := values[0]
+	for i, value := range values[1:] {
+		checksum += value * (i + 1)
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	var modules []bool
+	for _, value := range values {
+		for i, width := range code128Patterns[value] {
+			for range int(width - '0') {
+				modules = append(modules, i%2 == 0)
+			}
+		}
+	}
+
+	return &Symbol{Kind: KindCode128, Text: text, Width: len(modules), Height: 1, modules: modules}, nil
+}
+
+// code128Values converte o texto nos valores de símbolo, a partir do caractere de início
+func code128Values(text string) ([]int, error) {
+	if isDigits(text) && len(text) >= 4 {
+		values := []int{code128StartC}
+		pairs := len(text) / 2 * 2
+		for i := 0; i < pairs; i += 2 {
+			values = append(values, int(text[i]-'0')*10+int(text[i+1]-'0'))
+		}
+		if pairs < len(text) {
+			values = append(values, code128CodeB, int(text[pairs])-32)
+		}
+		return values, nil
+	}
+
+	values := []int{code128StartB}
+	for _, r := range text {
+		if r < 32 || r > 126 {
+			return nil, fmt.Errorf("barcode: caractere %q não suportado no Code 128", r)
+		}
+		values = append(values, int(r)-32)
+	}
+	return values, nil
+}
+
+func isDigits(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] < '0' || text[i] > '9' {
+			return false
+		}
+	}
+	return text != ""
+}
@@ -0,0 +1,50 @@
+package barcode
+
+import (
+	"fmt"
+)
+
+// itfWide é a largura das barras e espaços largos do Intercalado 2 de 5, em módulos (razão 3:1)
+const itfWide = 3
+
+// itfPatterns são as larguras (N estreito, W largo) das cinco barras ou espaços de cada dígito
+var itfPatterns = [10]string{
+	"NNWWN", "WNNNW", "NWNNW", "WWNNN", "NNWNW", "WNWNN", "NWWNN", "NNNWW", "WNNWN", "NWNWN",
+}
+
+// ITF codifica os dígitos em Intercalado 2 de 5, o código de barras dos boletos: os dígitos vão
+// aos pares, o primeiro nas barras e o segundo nos espaços, entre a guarda de início (barra e
+// espaço estreitos, duas vezes) e a de parada (barra larga, espaço e barra estreitos)
+func ITF(text string) (*Symbol, error) {
+	if !isDigits(text) {
+		return nil, fmt.Errorf("barcode: o Intercalado 2 de 5 só aceita dígitos")
+	}
+	if len(text)%2 != 0 {
+		return nil, fmt.Errorf("barcode: o Intercalado 2 de 5 exige um número par de dígitos")
+	}
+
+	modules := []bool{true, false, true, false}
+	for i := 0; i < len(text); i += 2 {
+		bars, spaces := itfPatterns[text[i]-'0'], itfPatterns[text[i+1]-'0']
+		for j := range 5 {
+			modules = appendITF(modules, bars[j], true)
+			modules = appendITF(modules, spaces[j], false)
+		}
+	}
+	modules = appendITF(modules, 'W', true)
+	modules = append(modules, false, true)
+
+	return &Symbol{Kind: KindITF, Text: text, Width: len(modules), Height: 1, modules: modules}, nil
+}
+
+// appendITF acrescenta uma barra (dark) ou espaço estreito ou largo
+func appendITF(modules []bool, width byte, dark bool) []bool {
+	size := 1
+	if width == 'W' {
+		size = itfWide
+	}
+	for range size {
+		modules = append(modules, dark)
+	}
+	return modules
+}
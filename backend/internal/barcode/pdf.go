@@ -0,0 +1,107 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"ERP-ONSMART/backend/internal/pdf"
+)
+
+// Dimensões da etiqueta PDF (100 x 50 mm), em pontos
+const (
+	labelWidth  = 283.46
+	labelHeight = 141.73
+	labelMargin = 8.0
+
+	// maxLabelLines é o número de linhas de texto que cabem abaixo (ou ao lado) do código
+	maxLabelLines = 4
+)
+
+// Label é uma etiqueta: o código e as linhas de texto impressas junto dele (descrição, SKU...)
+type Label struct {
+	Symbol *Symbol
+	Lines  []string
+}
+
+// WriteLabelsPDF grava as etiquetas em um PDF com uma página de 100 x 50 mm por etiqueta. O
+// código linear ocupa a largura da etiqueta com o texto embaixo; o QR fica à esquerda com o
+// texto ao lado.
+func WriteLabelsPDF(w io.Writer, labels []Label) error {
+	if len(labels) == 0 {
+		return fmt.Errorf("barcode: nenhuma etiqueta para gerar")
+	}
+
+	pages := make([]string, 0, len(labels))
+	for _, label := range labels {
+		pages = append(pages, labelContent(label))
+	}
+	return pdf.Write(w, labelWidth, labelHeight, pages)
+}
+
+// labelContent monta o fluxo de desenho de uma etiqueta
+func labelContent(label Label) string {
+	var b bytes.Buffer
+	symbol := label.Symbol
+	lines := label.Lines
+	if len(lines) > maxLabelLines {
+		lines = lines[:maxLabelLines]
+	}
+
+	if symbol.Linear() {
+		available := labelWidth - 2*labelMargin
+		module := min(available/float64(symbol.Width+2*symbol.QuietZone()), 2.0)
+		barHeight := 60.0
+		x0 := (labelWidth - float64(symbol.Width)*module) / 2
+		y0 := labelHeight - labelMargin - barHeight
+
+		for x := 0; x < symbol.Width; {
+			if !symbol.Dark(x, 0) {
+				x++
+				continue
+			}
+			start := x
+			for x < symbol.Width && symbol.Dark(x, 0) {
+				x++
+			}
+			pdf.Rect(&b, x0+float64(start)*module, y0, float64(x-start)*module, barHeight)
+		}
+
+		pdf.Text(&b, x0, y0-12, 10, symbol.Text)
+		for i, line := range lines {
+			pdf.Text(&b, labelMargin, y0-26-float64(i)*11, 9, line)
+		}
+		return b.String()
+	}
+
+	side := labelHeight - 2*labelMargin
+	symbol.DrawPDF(&b, labelMargin, labelMargin, side)
+
+	textX := labelMargin + side + 6
+	pdf.Text(&b, textX, labelHeight-labelMargin-14, 11, symbol.Text)
+	for i, line := range lines {
+		pdf.Text(&b, textX, labelHeight-labelMargin-30-float64(i)*12, 9, line)
+	}
+	return b.String()
+}
+
+// DrawPDF desenha o QR, com a zona de silêncio, no quadrado de lado side cujo canto inferior
+// esquerdo está em (x, y)
+func (s *Symbol) DrawPDF(b *bytes.Buffer, x, y, side float64) {
+	module := side / float64(s.Width+2*s.QuietZone())
+	x0 := x + float64(s.QuietZone())*module
+	top := y + side - float64(s.QuietZone())*module
+	for row := range s.Height {
+		for col := 0; col < s.Width; {
+			if !s.Dark(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < s.Width && s.Dark(col, row) {
+				col++
+			}
+			pdf.Rect(b, x0+float64(start)*module, top-float64(row+1)*module, float64(col-start)*module, module)
+		}
+	}
+}
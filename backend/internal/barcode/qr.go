@@ -0,0 +1,479 @@
+package barcode
+
+import (
+	"fmt"
+)
+
+// qrVersion descreve uma versão do QR Code no nível de correção M: total de palavras-código,
+// palavras de correção por bloco e número de blocos. Quando o total não divide igualmente, os
+// últimos blocos têm uma palavra de dados a mais.
+type qrVersion struct {
+	total      int
+	ecPerBlock int
+	blocks     int
+}
+
+// qrVersions lista as versões 1 a 13 no nível M, suficientes para até 331 bytes (um BR Code do
+// Pix dinâmico cabe com folga)
+var qrVersions = [...]qrVersion{
+	{26, 10, 1},
+	{44, 16, 1},
+	{70, 26, 1},
+	{100, 18, 2},
+	{134, 24, 2},
+	{172, 16, 4},
+	{196, 18, 4},
+	{242, 22, 4},
+	{292, 22, 5},
+	{346, 26, 5},
+	{404, 30, 5},
+	{466, 22, 8},
+	{532, 22, 9},
+}
+
+// QRMaxBytes é o maior conteúdo aceito por QR, em bytes
+const QRMaxBytes = 331
+
+// qrCountBits é o tamanho do campo de contagem de bytes na versão: 8 bits até a 9, 16 depois
+func qrCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// QR codifica o texto em QR Code (modo byte, correção M), na menor versão que comporta o
+// conteúdo, com a máscara de menor penalidade
+func QR(text string) (*Symbol, error) {
+	data := []byte(text)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("barcode: texto vazio")
+	}
+
+	version := 0
+	for v, spec := range qrVersions {
+		capacity := spec.total - spec.ecPerBlock*spec.blocks
+		if 4+qrCountBits(v+1)+len(data)*8 <= capacity*8 {
+			version = v + 1
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("barcode: conteúdo de %d bytes excede o limite de %d do QR", len(data), QRMaxBytes)
+	}
+
+	q := newQRMatrix(version)
+	q.drawFunctionPatterns()
+	q.drawCodewords(qrCodewords(version, data))
+
+	bestMask, bestPenalty := 0, -1
+	for mask := range 8 {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask)
+	}
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+
+	return &Symbol{Kind: KindQR, Text: text, Width: q.size, Height: q.size, modules: q.modules}, nil
+}
+
+// qrCodewords monta as palavras de dados (modo, tamanho, conteúdo e preenchimento), calcula a
+// correção de erros de cada bloco e intercala os blocos
+func qrCodewords(version int, data []byte) []byte {
+	spec := qrVersions[version-1]
+	capacity := spec.total - spec.ecPerBlock*spec.blocks
+
+	var bits bitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	bits.append(0, min(4, capacity*8-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity*8; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	codewords := bits.bytes()
+
+	// os blocos curtos vêm primeiro; os longos têm uma palavra de dados a mais
+	shortBlocks := spec.blocks - spec.total%spec.blocks
+	shortData := spec.total/spec.blocks - spec.ecPerBlock
+	divisor := reedSolomonDivisor(spec.ecPerBlock)
+	dataBlocks := make([][]byte, spec.blocks)
+	ecBlocks := make([][]byte, spec.blocks)
+	offset := 0
+	for i := range spec.blocks {
+		size := shortData
+		if i >= shortBlocks {
+			size++
+		}
+		dataBlocks[i] = codewords[offset : offset+size]
+		ecBlocks[i] = reedSolomonRemainder(dataBlocks[i], divisor)
+		offset += size
+	}
+
+	result := make([]byte, 0, spec.total)
+	for i := range shortData + 1 {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := range spec.ecPerBlock {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// bitBuffer acumula bits, do mais significativo para o menos significativo
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor retorna o polinômio gerador de grau informado (sem o coeficiente líder)
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder calcula as palavras de correção de erros de um bloco de dados
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplica no corpo GF(2^8) com o polinômio 0x11D
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// qrMatrix é a grade do símbolo em construção; function marca os módulos de padrões fixos,
+// que não recebem dados nem máscara
+type qrMatrix struct {
+	version  int
+	size     int
+	modules  []bool
+	function []bool
+}
+
+func newQRMatrix(version int) *qrMatrix {
+	size := version*4 + 17
+	return &qrMatrix{
+		version:  version,
+		size:     size,
+		modules:  make([]bool, size*size),
+		function: make([]bool, size*size),
+	}
+}
+
+func (q *qrMatrix) get(x, y int) bool {
+	return q.modules[y*q.size+x]
+}
+
+func (q *qrMatrix) setFunction(x, y int, dark bool) {
+	q.modules[y*q.size+x] = dark
+	q.function[y*q.size+x] = true
+}
+
+// drawFunctionPatterns desenha os padrões de localização, de temporização e de alinhamento, a
+// informação de versão (a partir da 7) e reserva a área das informações de formato
+func (q *qrMatrix) drawFunctionPatterns() {
+	for i := range q.size {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinder(3, 3)
+	q.drawFinder(q.size-4, 3)
+	q.drawFinder(3, q.size-4)
+
+	positions := q.alignmentPositions()
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			// os cantos ocupados pelos padrões de localização não recebem alinhamento
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			q.drawAlignment(x, y)
+		}
+	}
+
+	q.drawFormatBits(0)
+	q.drawVersionBits()
+}
+
+// alignmentPositions retorna as coordenadas (linhas e colunas) dos centros dos padrões de
+// alinhamento: a primeira em 6 e as demais com passo par, terminando em size-7
+func (q *qrMatrix) alignmentPositions() []int {
+	if q.version == 1 {
+		return nil
+	}
+	count := q.version/7 + 2
+	step := (q.version*8 + count*3 + 5) / (count*4 - 4) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for i := count - 1; i >= 1; i-- {
+		positions[i] = q.size - 7 - (count-1-i)*step
+	}
+	return positions
+}
+
+func (q *qrMatrix) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawVersionBits grava as duas cópias da informação de versão (18 bits, BCH(18,6)), exigidas a
+// partir da versão 7, nos blocos 6 x 3 junto dos padrões de localização superior direito e
+// inferior esquerdo
+func (q *qrMatrix) drawVersionBits() {
+	if q.version < 7 {
+		return
+	}
+	bits := qrVersionBits(q.version)
+	for i := range 18 {
+		dark := (bits>>i)&1 == 1
+		a, b := q.size-11+i%3, i/3
+		q.setFunction(a, b, dark)
+		q.setFunction(b, a, dark)
+	}
+}
+
+func (q *qrMatrix) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.size || yy < 0 || yy >= q.size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			q.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// drawFormatBits grava as duas cópias da informação de formato (nível M e máscara)
+func (q *qrMatrix) drawFormatBits(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// qrFormatBits calcula os 15 bits de formato: nível M (00), máscara e BCH(15,5), com a máscara
+// fixa 0x5412
+func qrFormatBits(mask int) int {
+	data := mask
+	rem := data
+	for range 10 {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// qrVersionBits calcula os 18 bits de versão: a versão em 6 bits e o BCH(18,6) com o polinômio
+// 0x1F25
+func qrVersionBits(version int) int {
+	rem := version
+	for range 12 {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// drawCodewords posiciona os bits em zigue-zague a partir do canto inferior direito, em pares
+// de colunas, pulando a coluna de temporização
+func (q *qrMatrix) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range q.size {
+			for j := range 2 {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !q.function[y*q.size+x] && i < len(data)*8 {
+					q.modules[y*q.size+x] = (data[i>>3]>>(7-i&7))&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverte os módulos de dados pela máscara informada; aplicar duas vezes desfaz
+func (q *qrMatrix) applyMask(mask int) {
+	for y := range q.size {
+		for x := range q.size {
+			if q.function[y*q.size+x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				q.modules[y*q.size+x] = !q.modules[y*q.size+x]
+			}
+		}
+	}
+}
+
+// penalty pontua o símbolo pelas quatro regras da norma: sequências da mesma cor, blocos 2x2,
+// padrões parecidos com o de localização e desequilíbrio entre módulos escuros e claros
+func (q *qrMatrix) penalty() int {
+	result := 0
+	for i := range q.size {
+		result += q.linePenalty(func(j int) bool { return q.get(j, i) })
+		result += q.linePenalty(func(j int) bool { return q.get(i, j) })
+	}
+
+	dark := 0
+	for y := range q.size {
+		for x := range q.size {
+			if q.get(x, y) {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size {
+				color := q.get(x, y)
+				if color == q.get(x+1, y) && color == q.get(x, y+1) && color == q.get(x+1, y+1) {
+					result += 3
+				}
+			}
+		}
+	}
+
+	total := q.size * q.size
+	percent := dark * 100 / total
+	result += abs(percent-50) / 5 * 10
+	return result
+}
+
+// finderLike é o padrão 1:1:3:1:1 com quatro módulos claros de um dos lados
+var finderLike = [...][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+func (q *qrMatrix) linePenalty(at func(int) bool) int {
+	result := 0
+	run := 1
+	for j := 1; j <= q.size; j++ {
+		if j < q.size && at(j) == at(j-1) {
+			run++
+			continue
+		}
+		if run >= 5 {
+			result += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for j := 0; j+11 <= q.size; j++ {
+		for _, pattern := range finderLike {
+			matched := true
+			for k, dark := range pattern {
+				if at(j+k) != dark {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				result += 40
+			}
+		}
+	}
+	return result
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
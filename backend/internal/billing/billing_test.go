@@ -0,0 +1,119 @@
+package billing
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDueFactorRestartsAfter9999(t *testing.T) {
+	assert.Equal(t, 1000, dueFactor(time.Date(2000, 7, 3, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 9999, dueFactor(time.Date(2025, 2, 21, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1000, dueFactor(time.Date(2025, 2, 22, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestNewBoletoBuildsBarcodeAndDigitableLine(t *testing.T) {
+	config := BoletoConfig{BankCode: "237", Agency: "1234", Wallet: "9", Account: "56789"}
+	due := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+
+	boleto, err := NewBoleto(config, 4321, due, money.MustParse("1500.5"))
+	require.NoError(t, err)
+	require.Len(t, boleto.Barcode, 44)
+	assert.Equal(t, "00000004321", boleto.OurNumber)
+	assert.Equal(t, "2379", boleto.Barcode[:4])
+	assert.Equal(t, "0000150050", boleto.Barcode[9:19], "valor em centavos")
+	assert.Equal(t, "1234"+"09"+"00000004321"+"0056789"+"0", boleto.Barcode[19:])
+
+	checkDigit := int(boleto.Barcode[4] - '0')
+	assert.Equal(t, barcodeCheckDigit(boleto.Barcode[:4]+boleto.Barcode[5:]), checkDigit)
+
+	fields := strings.Fields(boleto.DigitableLine)
+	require.Len(t, fields, 5)
+	for _, field := range fields[:3] {
+		number := strings.ReplaceAll(field, ".", "")
+		assert.Equal(t, mod10(number[:len(number)-1]), int(number[len(number)-1]-'0'))
+	}
+	assert.Equal(t, boleto.Barcode[4:5], fields[3])
+	assert.Equal(t, boleto.Barcode[5:19], fields[4])
+
+	_, err = NewBoleto(BoletoConfig{}, 1, due, money.FromInt(10))
+	assert.Error(t, err, "sem conta de cobrança não há boleto")
+}
+
+func TestPixPayload(t *testing.T) {
+	// exemplo do manual do BR Code do Banco Central
+	example := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***6304"
+	assert.Equal(t, uint16(0x1D3D), crc16(example))
+
+	config := PixConfig{Key: "contato@empresa.com.br", MerchantName: "Comércio São João", MerchantCity: "São Paulo"}
+	payload, err := PixPayload(config, money.MustParse("99.9"), "FAT-0001/2")
+	require.NoError(t, err)
+	assert.Contains(t, payload, "0122contato@empresa.com.br")
+	assert.Contains(t, payload, "540599.90")
+	assert.Contains(t, payload, "5917Comercio Sao Joao")
+	assert.Contains(t, payload, "6009Sao Paulo")
+	assert.Contains(t, payload, "62120508FAT00012")
+	crc, err := strconv.ParseUint(payload[len(payload)-4:], 16, 16)
+	require.NoError(t, err)
+	assert.Equal(t, crc16(payload[:len(payload)-4]), uint16(crc))
+}
+
+func TestPixDynamicPayload(t *testing.T) {
+	config := PixConfig{Key: "contato@empresa.com.br", MerchantName: "Comércio São João", MerchantCity: "São Paulo"}
+	payload, err := PixDynamicPayload(config, "https://pix.psp.com.br/qr/v2/cobv/9d36b84fc70b478fb95c12729b90ca25")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(payload, "000201010212"), "ponto de iniciação de uso único")
+	assert.Contains(t, payload, "2558pix.psp.com.br/qr/v2/cobv/9d36b84fc70b478fb95c12729b90ca25")
+	assert.NotContains(t, payload, "contato@empresa.com.br", "o QR dinâmico não leva a chave")
+	assert.NotContains(t, payload, "98654", "nem o valor")
+	assert.Contains(t, payload, "62070503***")
+	crc, err := strconv.ParseUint(payload[len(payload)-4:], 16, 16)
+	require.NoError(t, err)
+	assert.Equal(t, crc16(payload[:len(payload)-4]), uint16(crc))
+
+	_, err = PixDynamicPayload(config, "")
+	assert.Error(t, err)
+	_, err = PixDynamicPayload(PixConfig{}, "pix.psp.com.br/qr/v2/1")
+	assert.Error(t, err)
+}
+
+func TestBankCodeDigit(t *testing.T) {
+	for code, digit := range map[string]int{"237": 2, "341": 7, "001": 9, "104": 0, "077": 9, "033": 7} {
+		assert.Equal(t, digit, bankCodeDigit(code), "banco %s", code)
+	}
+}
+
+func TestWriteBoletoPDF(t *testing.T) {
+	config := BoletoConfig{BankCode: "237", Agency: "1234", Wallet: "9", Account: "56789"}
+	due := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+	boleto, err := NewBoleto(config, 4321, due, money.MustParse("1500.5"))
+	require.NoError(t, err)
+
+	doc := BoletoDocument{
+		Boleto: *boleto, BankCode: "237", BankName: "Bradesco", AgencyAccount: "1234-0 / 0056789-7", Wallet: "09",
+		BeneficiaryName: "Onsmart Comércio Ltda", BeneficiaryDocument: "12.345.678/0001-95", DocumentNo: "INV-0001/1",
+		IssueDate: due.AddDate(0, 0, -30), DueDate: due, Amount: money.MustParse("1500.5"), Discount: money.FromInt(50),
+		PayerName: "José da Conceição", PayerDocument: "987.654.321-00", PayerAddress: "Rua São João, 100 - São Paulo/SP",
+		Instructions: []string{"Até 10/11/2026 conceder desconto de R$ 50,00"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBoletoPDF(&buf, doc))
+	content := buf.String()
+	assert.True(t, strings.HasPrefix(content, "%PDF-1.4"))
+	assert.Contains(t, content, "(237-2)")
+	assert.Contains(t, content, "("+boleto.DigitableLine+")")
+	assert.Contains(t, content, "(1.500,50)")
+	assert.Contains(t, content, "(50,00)", "desconto na coluna de deduções")
+	assert.Contains(t, content, "Ficha de Compensa")
+
+	doc.Boleto.Barcode = "123"
+	assert.Error(t, WriteBoletoPDF(&buf, doc), "código de barras sem 44 dígitos")
+}
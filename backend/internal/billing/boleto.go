@@ -0,0 +1,144 @@
+// Package billing gera os meios de cobrança das faturas: o boleto bancário (código de barras e
+// linha digitável no padrão FEBRABAN) e o Pix copia e cola (BR Code do Banco Central).
+package billing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// BoletoConfig são os dados da conta de cobrança do beneficiário no banco
+type BoletoConfig struct {
+	BankCode string // código do banco, 3 dígitos (237)
+	Agency   string // agência, até 4 dígitos, sem o dígito verificador
+	Wallet   string // carteira, até 2 dígitos
+	Account  string // conta, até 7 dígitos, sem o dígito verificador
+}
+
+// Enabled indica se a cobrança por boleto está configurada
+func (c BoletoConfig) Enabled() bool {
+	return c.BankCode != "" && c.Agency != "" && c.Account != ""
+}
+
+// Boleto é a representação do título para pagamento
+type Boleto struct {
+	OurNumber     string `json:"our_number"`
+	Barcode       string `json:"barcode"`
+	DigitableLine string `json:"digitable_line"`
+}
+
+// baseDueDate é a data-base do fator de vencimento; o fator volta a 1000 ao passar de 9999
+var baseDueDate = time.Date(1997, 10, 7, 0, 0, 0, 0, time.UTC)
+
+// NewBoleto monta o código de barras de 44 posições e a linha digitável do título. O campo
+// livre segue o leiaute agência + carteira + nosso número + conta + zero.
+func NewBoleto(config BoletoConfig, ourNumber int, dueDate time.Time, amount money.Decimal) (*Boleto, error) {
+	if !config.Enabled() {
+		return nil, fmt.Errorf("billing: conta de cobrança do boleto não configurada")
+	}
+	cents := amount.Round(2).Cents()
+	if cents <= 0 || cents > 9_999_999_999 {
+		return nil, fmt.Errorf("billing: valor do boleto fora do limite: %s", amount)
+	}
+
+	bank, err := digits(config.BankCode, 3)
+	if err != nil {
+		return nil, err
+	}
+	agency, err := digits(config.Agency, 4)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := digits(config.Wallet, 2)
+	if err != nil {
+		return nil, err
+	}
+	account, err := digits(config.Account, 7)
+	if err != nil {
+		return nil, err
+	}
+	number := fmt.Sprintf("%011d", ourNumber)
+	if len(number) > 11 {
+		return nil, fmt.Errorf("billing: nosso número com mais de 11 dígitos: %d", ourNumber)
+	}
+
+	freeField := agency + wallet + number + account + "0"
+	factor := fmt.Sprintf("%04d", dueFactor(dueDate))
+	value := fmt.Sprintf("%010d", cents)
+
+	partial := bank + "9" + factor + value + freeField
+	checkDigit := barcodeCheckDigit(partial)
+	barcode := partial[:4] + strconv.Itoa(checkDigit) + partial[4:]
+
+	return &Boleto{
+		OurNumber:     number,
+		Barcode:       barcode,
+		DigitableLine: digitableLine(barcode),
+	}, nil
+}
+
+// dueFactor é o número de dias entre a data-base e o vencimento, no ciclo de 1000 a 9999
+func dueFactor(dueDate time.Time) int {
+	day := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC)
+	factor := int(day.Sub(baseDueDate).Hours() / 24)
+	for factor > 9999 {
+		factor -= 9000
+	}
+	return factor
+}
+
+// digitableLine converte o código de barras nos cinco campos da linha digitável
+func digitableLine(barcode string) string {
+	field1 := barcode[0:4] + barcode[19:24]
+	field2 := barcode[24:34]
+	field3 := barcode[34:44]
+	field1 += strconv.Itoa(mod10(field1))
+	field2 += strconv.Itoa(mod10(field2))
+	field3 += strconv.Itoa(mod10(field3))
+
+	return fmt.Sprintf("%s.%s %s.%s %s.%s %s %s",
+		field1[:5], field1[5:], field2[:5], field2[5:], field3[:5], field3[5:], barcode[4:5], barcode[5:19])
+}
+
+// mod10 é o dígito verificador dos campos da linha digitável (pesos 2 e 1 da direita para a
+// esquerda, somando os algarismos dos produtos)
+func mod10(number string) int {
+	sum, weight := 0, 2
+	for i := len(number) - 1; i >= 0; i-- {
+		product := int(number[i]-'0') * weight
+		sum += product/10 + product%10
+		weight = 3 - weight
+	}
+	return (10 - sum%10) % 10
+}
+
+// barcodeCheckDigit é o dígito geral do código de barras (módulo 11, pesos 2 a 9); os
+// resultados 0, 10 e 11 viram 1
+func barcodeCheckDigit(number string) int {
+	sum, weight := 0, 2
+	for i := len(number) - 1; i >= 0; i-- {
+		sum += int(number[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	digit := 11 - sum%11
+	if digit == 0 || digit == 10 || digit == 11 {
+		return 1
+	}
+	return digit
+}
+
+// digits completa com zeros à esquerda o número configurado, que só pode ter algarismos
+func digits(value string, size int) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > size || strings.Trim(value, "0123456789") != "" {
+		return "", fmt.Errorf("billing: valor %q inválido para %d dígitos", value, size)
+	}
+	return strings.Repeat("0", size-len(value)) + value, nil
+}
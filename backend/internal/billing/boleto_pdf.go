@@ -0,0 +1,265 @@
+package billing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/barcode"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/pdf"
+)
+
+// BoletoDocument são os dados impressos no boleto: o título já registrado, o beneficiário, o
+// pagador e as instruções de cobrança
+type BoletoDocument struct {
+	Boleto   Boleto
+	BankCode string
+	BankName string
+	// Agência e código do beneficiário como o banco imprime (1234-0 / 0056789-7)
+	AgencyAccount string
+	Wallet        string
+
+	BeneficiaryName     string
+	BeneficiaryDocument string
+	DocumentNo          string
+	IssueDate           time.Time
+	DueDate             time.Time
+	Amount              money.Decimal
+	Discount            money.Decimal
+
+	PayerName     string
+	PayerDocument string
+	PayerAddress  string
+	// Instruções de responsabilidade do beneficiário (desconto, protesto, juros); até cinco linhas
+	Instructions []string
+}
+
+// Medidas do boleto na página A4, em pontos
+const (
+	boletoLeft      = 40.0
+	boletoWidth     = pdf.A4Width - 2*boletoLeft
+	boletoRightCol  = 140.0
+	boletoRowHeight = 24.0
+	// O código de barras tem 103 mm de largura por 13 mm de altura
+	boletoBarWidth  = 292.0
+	boletoBarHeight = 37.0
+)
+
+// boletoPaymentPlace é o texto padrão do local de pagamento dos boletos registrados
+const boletoPaymentPlace = "Pagável em qualquer banco ou app até o vencimento"
+
+// WriteBoletoPDF grava o boleto em uma página A4 com o recibo do pagador e a ficha de
+// compensação, que traz a linha digitável e o código de barras Intercalado 2 de 5
+func WriteBoletoPDF(w io.Writer, doc BoletoDocument) error {
+	if len(doc.Boleto.Barcode) != 44 {
+		return fmt.Errorf("billing: código de barras do boleto com %d dígitos", len(doc.Boleto.Barcode))
+	}
+	symbol, err := barcode.ITF(doc.Boleto.Barcode)
+	if err != nil {
+		return fmt.Errorf("billing: código de barras do boleto inválido: %w", err)
+	}
+
+	var b bytes.Buffer
+	bank := doc.BankCode + "-" + strconv.Itoa(bankCodeDigit(doc.BankCode))
+	amount := boletoAmount(doc.Amount)
+	due := doc.DueDate.Format("02/01/2006")
+	payer := doc.PayerName
+	if doc.PayerDocument != "" {
+		payer += " - CPF/CNPJ " + doc.PayerDocument
+	}
+	beneficiary := doc.BeneficiaryName
+	if doc.BeneficiaryDocument != "" {
+		beneficiary += " - CNPJ " + doc.BeneficiaryDocument
+	}
+
+	// Recibo do pagador
+	y := pdf.A4Height - 50
+	boletoHeader(&b, y, doc.BankName, bank, "Recibo do Pagador")
+	y -= 6
+	y = boletoRow(&b, y, boletoCell{label: "Beneficiário", value: beneficiary},
+		boletoCell{width: 120, label: "Agência/Código do beneficiário", value: doc.AgencyAccount},
+		boletoCell{width: boletoRightCol, label: "Vencimento", value: due, bold: true})
+	y = boletoRow(&b, y, boletoCell{label: "Pagador", value: payer},
+		boletoCell{width: 120, label: "Nosso número", value: doc.Boleto.OurNumber},
+		boletoCell{width: boletoRightCol, label: "(=) Valor do documento", value: amount, bold: true})
+	y = boletoRow(&b, y, boletoCell{label: "Número do documento", value: doc.DocumentNo},
+		boletoCell{width: 120, label: "Data do documento", value: doc.IssueDate.Format("02/01/2006")},
+		boletoCell{width: boletoRightCol, label: "Carteira", value: doc.Wallet})
+	pdf.Text(&b, boletoLeft, y-10, 8, doc.Boleto.DigitableLine)
+	pdf.Text(&b, boletoLeft+boletoWidth-110, y-10, 6, "Autenticação mecânica")
+
+	// Linha de corte
+	y -= 50
+	pdf.Text(&b, boletoLeft, y, 6, strings.Repeat("- ", 112))
+	pdf.Text(&b, boletoLeft+boletoWidth-90, y-8, 6, "Corte na linha pontilhada")
+
+	// Ficha de compensação
+	y -= 40
+	boletoHeader(&b, y, doc.BankName, bank, doc.Boleto.DigitableLine)
+	y -= 6
+	y = boletoRow(&b, y, boletoCell{label: "Local de pagamento", value: boletoPaymentPlace},
+		boletoCell{width: boletoRightCol, label: "Vencimento", value: due, bold: true})
+	y = boletoRow(&b, y, boletoCell{label: "Beneficiário", value: beneficiary},
+		boletoCell{width: boletoRightCol, label: "Agência/Código do beneficiário", value: doc.AgencyAccount})
+	y = boletoRow(&b, y,
+		boletoCell{width: 80, label: "Data do documento", value: doc.IssueDate.Format("02/01/2006")},
+		boletoCell{label: "Nº do documento", value: doc.DocumentNo},
+		boletoCell{width: 50, label: "Espécie doc.", value: "DM"},
+		boletoCell{width: 40, label: "Aceite", value: "N"},
+		boletoCell{width: 80, label: "Data processamento", value: doc.IssueDate.Format("02/01/2006")},
+		boletoCell{width: boletoRightCol, label: "Nosso número", value: doc.Boleto.OurNumber})
+	y = boletoRow(&b, y,
+		boletoCell{width: 80, label: "Uso do banco"},
+		boletoCell{width: 60, label: "Carteira", value: doc.Wallet},
+		boletoCell{width: 50, label: "Espécie", value: "R$"},
+		boletoCell{label: "Quantidade"},
+		boletoCell{width: 80, label: "Valor"},
+		boletoCell{width: boletoRightCol, label: "(=) Valor do documento", value: amount, bold: true})
+
+	// Instruções à esquerda e deduções e acréscimos na coluna da direita
+	top := y
+	discount := ""
+	if doc.Discount.IsPositive() {
+		discount = boletoAmount(doc.Discount)
+	}
+	column := boletoLeft + boletoWidth - boletoRightCol
+	pdf.Rect(&b, boletoLeft, top, boletoWidth, 0.5)
+	for i, cell := range []boletoCell{
+		{label: "(-) Desconto/Abatimento", value: discount},
+		{label: "(+) Juros/Multa"},
+		{label: "(=) Valor cobrado"},
+	} {
+		rowY := top - float64(i)*boletoRowHeight
+		if i > 0 {
+			pdf.Rect(&b, column, rowY, boletoRightCol, 0.5)
+		}
+		pdf.Text(&b, column+3, rowY-8, 6, cell.label)
+		pdf.Text(&b, column+3, rowY-19, 9, cell.value)
+	}
+	y = top - 3*boletoRowHeight
+	pdf.Rect(&b, column, y, 0.5, top-y)
+	pdf.Text(&b, boletoLeft+3, top-8, 6, "Instruções (texto de responsabilidade do beneficiário)")
+	for i, line := range doc.Instructions {
+		if i == 5 {
+			break
+		}
+		pdf.Text(&b, boletoLeft+3, top-20-float64(i)*10, 8, line)
+	}
+
+	pdf.Rect(&b, boletoLeft, y, boletoWidth, 0.5)
+	pdf.Text(&b, boletoLeft+3, y-8, 6, "Pagador")
+	pdf.Text(&b, boletoLeft+3, y-19, 9, payer)
+	pdf.Text(&b, boletoLeft+3, y-30, 8, doc.PayerAddress)
+	y -= 36
+	pdf.Rect(&b, boletoLeft, y, boletoWidth, 0.5)
+	pdf.Text(&b, boletoLeft+boletoWidth-170, y-8, 6, "Autenticação mecânica - Ficha de Compensação")
+
+	// Código de barras
+	module := boletoBarWidth / float64(symbol.Width)
+	barY := y - 14 - boletoBarHeight
+	for x := 0; x < symbol.Width; {
+		if !symbol.Dark(x, 0) {
+			x++
+			continue
+		}
+		start := x
+		for x < symbol.Width && symbol.Dark(x, 0) {
+			x++
+		}
+		pdf.Rect(&b, boletoLeft+float64(start)*module, barY, float64(x-start)*module, boletoBarHeight)
+	}
+
+	return pdf.Write(w, pdf.A4Width, pdf.A4Height, []string{b.String()})
+}
+
+// boletoCell é um campo da grade do boleto; sem largura, ocupa o espaço que sobra na linha
+type boletoCell struct {
+	width float64
+	label string
+	value string
+	bold  bool
+}
+
+// boletoHeader escreve o banco, o código com o dígito e o título da parte do boleto
+func boletoHeader(b *bytes.Buffer, y float64, bankName, bank, title string) {
+	pdf.BoldText(b, boletoLeft, y, 11, bankName)
+	pdf.Rect(b, boletoLeft+150, y-4, 1, 18)
+	pdf.BoldText(b, boletoLeft+158, y, 14, bank)
+	pdf.Rect(b, boletoLeft+210, y-4, 1, 18)
+	pdf.BoldText(b, boletoLeft+218, y, 10, title)
+}
+
+// boletoRow desenha uma linha da grade com os campos lado a lado e retorna a posição da linha
+// seguinte
+func boletoRow(b *bytes.Buffer, y float64, cells ...boletoCell) float64 {
+	fixed := 0.0
+	flexible := 0
+	for _, cell := range cells {
+		if cell.width == 0 {
+			flexible++
+		}
+		fixed += cell.width
+	}
+	remaining := boletoWidth - fixed
+	if flexible > 0 {
+		remaining /= float64(flexible)
+	}
+
+	pdf.Rect(b, boletoLeft, y, boletoWidth, 0.5)
+	x := boletoLeft
+	for i, cell := range cells {
+		width := cell.width
+		if width == 0 {
+			width = remaining
+		}
+		if i > 0 {
+			pdf.Rect(b, x, y-boletoRowHeight, 0.5, boletoRowHeight)
+		}
+		pdf.Text(b, x+3, y-8, 6, cell.label)
+		if cell.bold {
+			pdf.BoldText(b, x+3, y-19, 9, cell.value)
+		} else {
+			pdf.Text(b, x+3, y-19, 9, cell.value)
+		}
+		x += width
+	}
+	return y - boletoRowHeight
+}
+
+// bankCodeDigit é o dígito do código do banco impresso no boleto (módulo 11, pesos 2 a 9); os
+// restos que dariam 10 ou 11 viram 0
+func bankCodeDigit(code string) int {
+	sum, weight := 0, 2
+	for i := len(code) - 1; i >= 0; i-- {
+		if code[i] < '0' || code[i] > '9' {
+			continue
+		}
+		sum += int(code[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	digit := 11 - sum%11
+	if digit >= 10 {
+		return 0
+	}
+	return digit
+}
+
+// boletoAmount formata o valor com os separadores brasileiros: 1.500,50
+func boletoAmount(value money.Decimal) string {
+	integer, cents, _ := strings.Cut(value.Round(2).StringFixed(2), ".")
+	var b strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String() + "," + cents
+}
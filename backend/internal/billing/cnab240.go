@@ -0,0 +1,377 @@
+package billing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// CNABRecordSize é o tamanho dos registros do layout FEBRABAN 240
+const CNABRecordSize = 240
+
+// Códigos de movimento do retorno de cobrança CNAB 240 tratados pelo ERP
+const (
+	CNABEntryConfirmed      = "02"
+	CNABEntryRejected       = "03"
+	CNABLiquidation         = "06"
+	CNABWrittenOff          = "09"
+	CNABLiquidationAfterOff = "17"
+	CNABFeeDebit            = "28"
+)
+
+// Códigos de movimento da remessa (FEBRABAN, nota C004): a entrada do título e as instruções
+// enviadas depois do registro
+const (
+	CNABMovementEntry    = "01"
+	CNABMovementWriteOff = "02"
+	CNABMovementDiscount = "07"
+	CNABMovementProtest  = "09"
+)
+
+// cnabOccurrences descreve os códigos de movimento do retorno (FEBRABAN, nota G015)
+var cnabOccurrences = map[string]string{
+	"02": "Entrada confirmada",
+	"03": "Entrada rejeitada",
+	"04": "Transferência de carteira/entrada",
+	"05": "Transferência de carteira/baixa",
+	"06": "Liquidação",
+	"09": "Baixa",
+	"11": "Títulos em carteira (em ser)",
+	"12": "Confirmação de recebimento de instrução de abatimento",
+	"13": "Confirmação de recebimento de instrução de cancelamento de abatimento",
+	"14": "Confirmação de recebimento de instrução de alteração de vencimento",
+	"17": "Liquidação após baixa ou liquidação de título não registrado",
+	"19": "Confirmação de recebimento de instrução de protesto",
+	"20": "Confirmação de recebimento de instrução de sustação de protesto",
+	"23": "Remessa a cartório",
+	"24": "Retirada de cartório e manutenção em carteira",
+	"25": "Protestado e baixado",
+	"26": "Instrução rejeitada",
+	"27": "Confirmação do pedido de alteração de outros dados",
+	"28": "Débito de tarifas/custas",
+	"30": "Alteração de dados rejeitada",
+}
+
+// CNABOccurrenceDescription descreve o código de movimento do retorno
+func CNABOccurrenceDescription(code string) string {
+	if description, ok := cnabOccurrences[code]; ok {
+		return description
+	}
+	return "Movimento " + code
+}
+
+// CNABRemittance são os dados do arquivo de remessa de cobrança: a conta do beneficiário, a
+// empresa e os boletos a registrar
+type CNABRemittance struct {
+	Boleto       BoletoConfig
+	AgencyDigit  string
+	AccountDigit string
+	// Código do beneficiário (convênio) no banco
+	Agreement       string
+	BankName        string
+	CompanyDocument string // CNPJ
+	CompanyName     string
+	// Número sequencial do arquivo (NSA), crescente a cada remessa
+	Sequence    int
+	GeneratedAt time.Time
+	Slips       []CNABSlip
+}
+
+// CNABSlip é um boleto da remessa. A entrada leva os segmentos P (título) e Q (pagador); as
+// instruções (baixa, desconto, protesto) levam só o segmento P, com o código de movimento.
+type CNABSlip struct {
+	// Código de movimento; vazio é a entrada do título
+	Movement  string
+	OurNumber string
+	// Seu número: o número do documento de cobrança na empresa (até 15 posições)
+	DocumentNo string
+	// Identificação do título na empresa, devolvida no retorno (até 25 posições)
+	Reference string
+	IssueDate time.Time
+	DueDate   time.Time
+	Amount    money.Decimal
+	// Desconto de valor fixo para pagamento até DiscountUntil
+	DiscountAmount money.Decimal
+	DiscountUntil  time.Time
+	// Dias corridos depois do vencimento para o protesto; zero é não protestar
+	ProtestDays int
+
+	PayerDocument string // CPF ou CNPJ
+	PayerName     string
+	Street        string
+	Neighborhood  string
+	ZipCode       string
+	City          string
+	State         string
+}
+
+// cnabLayoutVersion e cnabBatchVersion são as versões do leiaute do arquivo e do lote de cobrança
+const (
+	cnabLayoutVersion = "103"
+	cnabBatchVersion  = "060"
+)
+
+// WriteCNAB240Remittance gera o arquivo de remessa de cobrança no layout FEBRABAN 240: header
+// de arquivo, um lote com o segmento P de cada título (e o Q, do pagador, nas entradas), trailer
+// de lote e trailer de arquivo, com registros separados por CRLF
+func WriteCNAB240Remittance(r CNABRemittance) ([]byte, error) {
+	if !r.Boleto.Enabled() {
+		return nil, fmt.Errorf("billing: conta de cobrança do boleto não configurada")
+	}
+	if len(r.Slips) == 0 {
+		return nil, fmt.Errorf("billing: remessa sem boletos")
+	}
+
+	w := &cnabWriter{}
+	bank := w.num(r.Boleto.BankCode, 3)
+	account := w.num(r.Boleto.Agency, 5) + w.alpha(r.AgencyDigit, 1) + w.num(r.Boleto.Account, 12) +
+		w.alpha(r.AccountDigit, 1) + " "
+	company := "2" + w.num(r.CompanyDocument, 14)
+	generated := r.GeneratedAt
+
+	var records []string
+	records = append(records, bank+"0000"+"0"+w.blank(9)+company+w.alpha(r.Agreement, 20)+account+
+		w.alpha(r.CompanyName, 30)+w.alpha(r.BankName, 30)+w.blank(10)+"1"+generated.Format("02012006")+
+		generated.Format("150405")+w.num(strconv.Itoa(r.Sequence), 6)+cnabLayoutVersion+"00000"+w.blank(69))
+	records = append(records, bank+"0001"+"1"+"R"+"01"+w.blank(2)+cnabBatchVersion+" "+"2"+
+		w.num(r.CompanyDocument, 15)+w.alpha(r.Agreement, 20)+account+w.alpha(r.CompanyName, 30)+w.blank(80)+
+		w.num(strconv.Itoa(r.Sequence), 8)+generated.Format("02012006")+w.zeros(8)+w.blank(33))
+
+	total := money.Zero
+	segments := 0
+	for _, slip := range r.Slips {
+		cents := slip.Amount.Round(2).Cents()
+		if cents <= 0 {
+			w.fail(fmt.Errorf("billing: boleto %s sem valor", slip.OurNumber))
+		}
+		total = total.Add(slip.Amount.Round(2))
+
+		movement := slip.Movement
+		if movement == "" {
+			movement = CNABMovementEntry
+		}
+		discount := "0" + w.zeros(23)
+		if slip.DiscountAmount.IsPositive() {
+			discount = "1" + slip.DiscountUntil.Format("02012006") +
+				w.num(strconv.FormatInt(slip.DiscountAmount.Round(2).Cents(), 10), 15)
+		}
+		protest := "3" + "00"
+		if slip.ProtestDays > 0 {
+			protest = "1" + w.num(strconv.Itoa(slip.ProtestDays), 2)
+		}
+
+		// Segmento P: título, sem juros, com o desconto e o protesto informados
+		segments++
+		records = append(records, bank+"0001"+"3"+w.num(strconv.Itoa(segments), 5)+"P"+" "+movement+account+
+			w.alpha(slip.OurNumber, 20)+"1"+"1"+"1"+"2"+"2"+w.alpha(slip.DocumentNo, 15)+
+			slip.DueDate.Format("02012006")+w.num(strconv.FormatInt(cents, 10), 15)+w.zeros(5)+" "+"02"+"N"+
+			slip.IssueDate.Format("02012006")+"3"+w.zeros(23)+discount+w.zeros(30)+w.alpha(slip.Reference, 25)+
+			protest+"0"+"000"+"09"+w.zeros(10)+" ")
+		if movement != CNABMovementEntry {
+			continue
+		}
+
+		payerType := "2"
+		if len(onlyDigits(slip.PayerDocument)) <= 11 {
+			payerType = "1"
+		}
+		zipCode := w.num(slip.ZipCode, 8)
+
+		// Segmento Q: pagador
+		segments++
+		records = append(records, bank+"0001"+"3"+w.num(strconv.Itoa(segments), 5)+"Q"+" "+movement+payerType+
+			w.num(slip.PayerDocument, 15)+w.alpha(slip.PayerName, 40)+w.alpha(slip.Street, 40)+
+			w.alpha(slip.Neighborhood, 15)+zipCode+w.alpha(slip.City, 15)+w.alpha(slip.State, 2)+"0"+w.zeros(15)+
+			w.blank(40)+"000"+w.blank(20)+w.blank(8))
+	}
+
+	// O lote tem o header, os segmentos e o trailer; o arquivo, também os seus header e trailer
+	batchRecords := segments + 2
+	records = append(records, bank+"0001"+"5"+w.blank(9)+w.num(strconv.Itoa(batchRecords), 6)+
+		w.num(strconv.Itoa(len(r.Slips)), 6)+w.num(strconv.FormatInt(total.Cents(), 10), 17)+w.zeros(69)+
+		w.blank(8)+w.blank(117))
+	records = append(records, bank+"9999"+"9"+w.blank(9)+"000001"+w.num(strconv.Itoa(batchRecords+2), 6)+
+		"000000"+w.blank(205))
+
+	if w.err != nil {
+		return nil, w.err
+	}
+	for i, record := range records {
+		if len(record) != CNABRecordSize {
+			return nil, fmt.Errorf("billing: registro %d da remessa com %d posições", i+1, len(record))
+		}
+	}
+	return []byte(strings.Join(records, "\r\n") + "\r\n"), nil
+}
+
+// cnabWriter formata os campos dos registros, guardando o primeiro erro
+type cnabWriter struct {
+	err error
+}
+
+func (w *cnabWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// num completa com zeros à esquerda os algarismos do valor
+func (w *cnabWriter) num(value string, size int) string {
+	value = onlyDigits(value)
+	if len(value) > size {
+		w.fail(fmt.Errorf("billing: valor %q excede %d dígitos", value, size))
+		return strings.Repeat("0", size)
+	}
+	return strings.Repeat("0", size-len(value)) + value
+}
+
+// alpha completa com brancos à direita o texto em maiúsculas e sem acentos, cortando o excesso
+func (w *cnabWriter) alpha(value string, size int) string {
+	value = strings.ToUpper(plain(value, size))
+	return value + strings.Repeat(" ", size-len(value))
+}
+
+func (w *cnabWriter) blank(size int) string {
+	return strings.Repeat(" ", size)
+}
+
+func (w *cnabWriter) zeros(size int) string {
+	return strings.Repeat("0", size)
+}
+
+func onlyDigits(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// CNABReturnRecord é um título do arquivo de retorno de cobrança (segmento T seguido do U)
+type CNABReturnRecord struct {
+	// Linha do segmento T no arquivo
+	Line       int
+	Occurrence string
+	OurNumber  string
+	DocumentNo string
+	// Motivos da ocorrência (códigos de 2 posições), nas rejeições
+	Reasons    string
+	DueDate    time.Time
+	Amount     money.Decimal
+	Fee        money.Decimal
+	Interest   money.Decimal
+	Discount   money.Decimal
+	PaidAmount money.Decimal
+	NetAmount  money.Decimal
+	OccurredAt time.Time
+	// Data do crédito na conta; vazia nos movimentos sem crédito
+	CreditedAt time.Time
+}
+
+// IsLiquidation indica se o movimento é o pagamento do título
+func (r CNABReturnRecord) IsLiquidation() bool {
+	return r.Occurrence == CNABLiquidation || r.Occurrence == CNABLiquidationAfterOff
+}
+
+// SettledAt é a data do crédito ou, sem ela, a da ocorrência
+func (r CNABReturnRecord) SettledAt() time.Time {
+	if !r.CreditedAt.IsZero() {
+		return r.CreditedAt
+	}
+	return r.OccurredAt
+}
+
+// ParseCNAB240Return lê o arquivo de retorno de cobrança no layout FEBRABAN 240 e devolve os
+// títulos na ordem do arquivo. Campos numéricos em branco valem zero; datas em branco ou
+// zeradas ficam vazias.
+func ParseCNAB240Return(content []byte) ([]CNABReturnRecord, error) {
+	var (
+		records []CNABReturnRecord
+		pending *CNABReturnRecord
+	)
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(line) < CNABRecordSize {
+			return nil, fmt.Errorf("linha %d do CNAB 240 com %d posições (esperadas %d)", i+1, len(line), CNABRecordSize)
+		}
+		if i == 0 && line[7] != '0' {
+			return nil, fmt.Errorf("arquivo CNAB 240 sem o header de arquivo")
+		}
+		// Só os registros de detalhe (tipo 3) têm segmentos
+		if line[7] != '3' {
+			continue
+		}
+
+		p := cnabParser{line: line, number: i + 1}
+		switch line[13] {
+		case 'T':
+			pending = &CNABReturnRecord{
+				Line:       i + 1,
+				Occurrence: line[15:17],
+				OurNumber:  strings.TrimSpace(line[37:57]),
+				DocumentNo: strings.TrimSpace(line[58:73]),
+				DueDate:    p.date(73, 81),
+				Amount:     p.amount(81, 96),
+				Fee:        p.amount(198, 213),
+				Reasons:    strings.TrimSpace(line[213:223]),
+			}
+		case 'U':
+			if pending == nil {
+				continue
+			}
+			pending.Interest = p.amount(17, 32)
+			pending.Discount = p.amount(32, 47)
+			pending.PaidAmount = p.amount(77, 92)
+			pending.NetAmount = p.amount(92, 107)
+			pending.OccurredAt = p.date(137, 145)
+			pending.CreditedAt = p.date(145, 153)
+			records = append(records, *pending)
+			pending = nil
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+	}
+
+	return records, nil
+}
+
+// cnabParser lê os campos de um registro, guardando o primeiro erro
+type cnabParser struct {
+	line   string
+	number int
+	err    error
+}
+
+func (p *cnabParser) amount(start, end int) money.Decimal {
+	field := strings.TrimSpace(p.line[start:end])
+	if field == "" {
+		return money.Zero
+	}
+	cents, err := strconv.ParseInt(field, 10, 64)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("valor inválido na linha %d do CNAB 240: %q", p.number, p.line[start:end])
+	}
+	return money.FromCents(cents)
+}
+
+func (p *cnabParser) date(start, end int) time.Time {
+	field := strings.TrimSpace(p.line[start:end])
+	if field == "" || strings.Trim(field, "0") == "" {
+		return time.Time{}
+	}
+	date, err := time.Parse("02012006", field)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("data inválida na linha %d do CNAB 240: %q", p.number, field)
+	}
+	return date
+}
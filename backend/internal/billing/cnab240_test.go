@@ -0,0 +1,194 @@
+package billing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleRemittance() CNABRemittance {
+	issue := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	return CNABRemittance{
+		Boleto:          BoletoConfig{BankCode: "237", Agency: "1234", Wallet: "9", Account: "56789"},
+		AgencyDigit:     "0",
+		AccountDigit:    "7",
+		Agreement:       "998877",
+		BankName:        "Banco Bradesco",
+		CompanyDocument: "12.345.678/0001-95",
+		CompanyName:     "Onsmart Comércio Ltda",
+		Sequence:        42,
+		GeneratedAt:     time.Date(2026, 3, 5, 14, 30, 0, 0, time.UTC),
+		Slips: []CNABSlip{
+			{OurNumber: "00000004321", DocumentNo: "INV-2026-0001/1", Reference: "4321", IssueDate: issue,
+				DueDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Amount: money.MustParse("1500.5"),
+				PayerDocument: "987.654.321-00", PayerName: "José da Conceição", Street: "Rua São João, 100",
+				Neighborhood: "Centro", ZipCode: "01010-000", City: "São Paulo", State: "SP"},
+			{OurNumber: "00000004322", DocumentNo: "INV-2026-0002/1", Reference: "4322", IssueDate: issue,
+				DueDate: time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC), Amount: money.FromInt(320),
+				PayerDocument: "11222333000181", PayerName: "Cliente PJ Ltda", ZipCode: "20000000",
+				City: "Rio de Janeiro", State: "RJ"},
+		},
+	}
+}
+
+func TestWriteCNAB240Remittance(t *testing.T) {
+	content, err := WriteCNAB240Remittance(sampleRemittance())
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(string(content), "\r\n"))
+
+	records := strings.Split(strings.TrimSuffix(string(content), "\r\n"), "\r\n")
+	require.Len(t, records, 8, "header, lote, P e Q de cada boleto, trailer de lote e de arquivo")
+	for i, record := range records {
+		assert.Len(t, record, CNABRecordSize, "registro %d", i+1)
+		assert.Equal(t, "237", record[:3])
+	}
+
+	header := records[0]
+	assert.Equal(t, "0", header[7:8])
+	assert.Equal(t, "212345678000195", header[17:32])
+	assert.Equal(t, "ONSMART COMERCIO LTDA", strings.TrimSpace(header[72:102]))
+	assert.Equal(t, "05032026143000", header[143:157])
+	assert.Equal(t, "000042", header[157:163])
+
+	p := records[2]
+	assert.Equal(t, "P", p[13:14])
+	assert.Equal(t, "00000004321", strings.TrimSpace(p[37:57]))
+	assert.Equal(t, "INV-2026-0001/1", p[62:77])
+	assert.Equal(t, "01042026", p[77:85])
+	assert.Equal(t, "000000000150050", p[85:100])
+
+	q := records[3]
+	assert.Equal(t, "Q", q[13:14])
+	assert.Equal(t, "1", q[17:18], "pagador pessoa física")
+	assert.Equal(t, "000098765432100", q[18:33])
+	assert.Equal(t, "JOSE DA CONCEICAO", strings.TrimSpace(q[33:73]))
+	assert.Equal(t, "01010000", q[128:136])
+	assert.Equal(t, "SAO PAULO", strings.TrimSpace(q[136:151]))
+	assert.Equal(t, "2", records[5][17:18], "pagador pessoa jurídica")
+
+	batchTrailer := records[6]
+	assert.Equal(t, "000006", batchTrailer[17:23])
+	assert.Equal(t, "000002", batchTrailer[23:29])
+	assert.Equal(t, "00000000000182050", batchTrailer[29:46])
+
+	fileTrailer := records[7]
+	assert.Equal(t, "000001", fileTrailer[17:23])
+	assert.Equal(t, "000008", fileTrailer[23:29])
+}
+
+func TestWriteCNAB240RemittanceInstructions(t *testing.T) {
+	remittance := sampleRemittance()
+	remittance.Slips[0].Movement = CNABMovementDiscount
+	remittance.Slips[0].DiscountAmount = money.MustParse("25.5")
+	remittance.Slips[0].DiscountUntil = time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)
+	remittance.Slips[1].Movement = CNABMovementProtest
+	remittance.Slips[1].ProtestDays = 5
+
+	content, err := WriteCNAB240Remittance(remittance)
+	require.NoError(t, err)
+	records := strings.Split(strings.TrimSuffix(string(content), "\r\n"), "\r\n")
+	require.Len(t, records, 6, "instruções levam só o segmento P")
+
+	discount := records[2]
+	assert.Equal(t, "P", discount[13:14])
+	assert.Equal(t, "00001", discount[8:13])
+	assert.Equal(t, CNABMovementDiscount, discount[15:17])
+	assert.Equal(t, "1", discount[141:142], "desconto de valor fixo até a data")
+	assert.Equal(t, "25032026", discount[142:150])
+	assert.Equal(t, "000000000002550", discount[150:165])
+	assert.Equal(t, "300", discount[220:223], "sem protesto")
+
+	protest := records[3]
+	assert.Equal(t, "00002", protest[8:13])
+	assert.Equal(t, CNABMovementProtest, protest[15:17])
+	assert.Equal(t, "105", protest[220:223], "protestar 5 dias corridos após o vencimento")
+	assert.Equal(t, "000004", records[4][17:23])
+}
+
+func TestWriteCNAB240RemittanceErrors(t *testing.T) {
+	remittance := sampleRemittance()
+	remittance.Boleto = BoletoConfig{}
+	_, err := WriteCNAB240Remittance(remittance)
+	assert.Error(t, err, "sem conta de cobrança")
+
+	remittance = sampleRemittance()
+	remittance.Slips = nil
+	_, err = WriteCNAB240Remittance(remittance)
+	assert.Error(t, err, "sem boletos")
+
+	remittance = sampleRemittance()
+	remittance.Slips[1].Amount = money.Zero
+	_, err = WriteCNAB240Remittance(remittance)
+	assert.ErrorContains(t, err, "00000004322 sem valor")
+
+	remittance = sampleRemittance()
+	remittance.CompanyDocument = "123456789012345"
+	_, err = WriteCNAB240Remittance(remittance)
+	assert.Error(t, err, "CNPJ com mais de 14 dígitos")
+}
+
+// returnRecord monta um registro de 240 posições com os campos nas posições (1-based) informadas
+func returnRecord(fields map[int]string) string {
+	record := []byte(strings.Repeat(" ", CNABRecordSize))
+	for position, value := range fields {
+		copy(record[position-1:], value)
+	}
+	return string(record)
+}
+
+func TestParseCNAB240Return(t *testing.T) {
+	content := strings.Join([]string{
+		returnRecord(map[int]string{1: "23700000"}),
+		returnRecord(map[int]string{1: "23700011"}),
+		// Liquidação com juros e tarifa
+		returnRecord(map[int]string{1: "23700013", 14: "T", 16: "06", 38: "00000004321", 59: "INV-2026-0001/1",
+			74: "01042026", 82: "000000000150050", 199: "000000000000250"}),
+		returnRecord(map[int]string{1: "23700013", 14: "U", 16: "06", 18: "000000000000500", 78: "000000000150550",
+			93: "000000000150300", 138: "02042026", 146: "03042026"}),
+		// Entrada rejeitada com os motivos
+		returnRecord(map[int]string{1: "23700013", 14: "T", 16: "03", 38: "00000004322", 82: "000000000032000",
+			214: "0845"}),
+		returnRecord(map[int]string{1: "23700013", 14: "U", 16: "03", 138: "06032026", 146: "00000000"}),
+		returnRecord(map[int]string{1: "23700015"}),
+		returnRecord(map[int]string{1: "23799999"}),
+	}, "\r\n")
+
+	records, err := ParseCNAB240Return([]byte(content))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	paid := records[0]
+	assert.Equal(t, 3, paid.Line)
+	assert.True(t, paid.IsLiquidation())
+	assert.Equal(t, "00000004321", paid.OurNumber)
+	assert.Equal(t, "INV-2026-0001/1", paid.DocumentNo)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), paid.DueDate)
+	assert.True(t, paid.Amount.Equal(money.MustParse("1500.50")))
+	assert.True(t, paid.Fee.Equal(money.MustParse("2.50")))
+	assert.True(t, paid.Interest.Equal(money.MustParse("5")))
+	assert.True(t, paid.PaidAmount.Equal(money.MustParse("1505.50")))
+	assert.True(t, paid.NetAmount.Equal(money.MustParse("1503")))
+	assert.Equal(t, time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC), paid.SettledAt())
+
+	rejected := records[1]
+	assert.False(t, rejected.IsLiquidation())
+	assert.Equal(t, CNABEntryRejected, rejected.Occurrence)
+	assert.Equal(t, "0845", rejected.Reasons)
+	assert.True(t, rejected.PaidAmount.IsZero())
+	assert.True(t, rejected.CreditedAt.IsZero())
+	assert.Equal(t, time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC), rejected.SettledAt())
+	assert.Equal(t, "Entrada rejeitada", CNABOccurrenceDescription(rejected.Occurrence))
+}
+
+func TestParseCNAB240ReturnInvalidAmount(t *testing.T) {
+	content := returnRecord(map[int]string{1: "23700000"}) + "\n" +
+		returnRecord(map[int]string{1: "23700013", 14: "T", 16: "06", 82: "0000000001500X0"})
+
+	_, err := ParseCNAB240Return([]byte(content))
+	assert.ErrorContains(t, err, "valor inválido na linha 2")
+}
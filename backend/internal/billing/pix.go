@@ -0,0 +1,136 @@
+package billing
+
+import (
+	"fmt"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// PixConfig são os dados do recebedor do Pix
+type PixConfig struct {
+	Key          string // chave Pix (CNPJ, e-mail, telefone ou aleatória)
+	MerchantName string
+	MerchantCity string
+}
+
+// Enabled indica se a cobrança por Pix está configurada
+func (c PixConfig) Enabled() bool {
+	return c.Key != "" && c.MerchantName != "" && c.MerchantCity != ""
+}
+
+// PixPayload monta o Pix copia e cola (BR Code estático) com valor e identificador da cobrança
+func PixPayload(config PixConfig, amount money.Decimal, txID string) (string, error) {
+	if !config.Enabled() {
+		return "", fmt.Errorf("billing: recebedor do Pix não configurado")
+	}
+	if !amount.IsPositive() {
+		return "", fmt.Errorf("billing: valor do Pix deve ser positivo: %s", amount)
+	}
+
+	txID = alphanumeric(txID, 25)
+	if txID == "" {
+		txID = "***"
+	}
+
+	var payload strings.Builder
+	payload.WriteString(emv("00", "01"))
+	payload.WriteString(emv("26", emv("00", "br.gov.bcb.pix")+emv("01", config.Key)))
+	payload.WriteString(emv("52", "0000"))
+	payload.WriteString(emv("53", "986"))
+	payload.WriteString(emv("54", amount.StringFixed(2)))
+	payload.WriteString(emv("58", "BR"))
+	payload.WriteString(emv("59", plain(config.MerchantName, 25)))
+	payload.WriteString(emv("60", plain(config.MerchantCity, 15)))
+	payload.WriteString(emv("62", emv("05", txID)))
+	payload.WriteString("6304")
+
+	return payload.String() + fmt.Sprintf("%04X", crc16(payload.String())), nil
+}
+
+// PixDynamicPayload monta o Pix copia e cola do QR dinâmico: em vez da chave e do valor, o BR Code
+// leva a location da cobrança criada no PSP (sem o https://), de onde o app do pagador lê o
+// valor, o vencimento e o txid. O código é de uso único (ponto de iniciação 12).
+func PixDynamicPayload(config PixConfig, location string) (string, error) {
+	if config.MerchantName == "" || config.MerchantCity == "" {
+		return "", fmt.Errorf("billing: recebedor do Pix não configurado")
+	}
+	location = strings.TrimPrefix(strings.TrimSpace(location), "https://")
+	if location == "" || len(location) > 77 {
+		return "", fmt.Errorf("billing: location da cobrança Pix inválida: %q", location)
+	}
+
+	var payload strings.Builder
+	payload.WriteString(emv("00", "01"))
+	payload.WriteString(emv("01", "12"))
+	payload.WriteString(emv("26", emv("00", "br.gov.bcb.pix")+emv("25", location)))
+	payload.WriteString(emv("52", "0000"))
+	payload.WriteString(emv("53", "986"))
+	payload.WriteString(emv("58", "BR"))
+	payload.WriteString(emv("59", plain(config.MerchantName, 25)))
+	payload.WriteString(emv("60", plain(config.MerchantCity, 15)))
+	payload.WriteString(emv("62", emv("05", "***")))
+	payload.WriteString("6304")
+
+	return payload.String() + fmt.Sprintf("%04X", crc16(payload.String())), nil
+}
+
+// emv formata um campo ID + tamanho + valor do BR Code
+func emv(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// crc16 é o CRC-16/CCITT-FALSE (polinômio 0x1021, valor inicial 0xFFFF) exigido no campo 63
+func crc16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// unaccent troca as letras acentuadas do português pela letra sem acento
+var unaccent = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c",
+	"Á", "A", "À", "A", "Â", "A", "Ã", "A", "É", "E", "Ê", "E", "Í", "I",
+	"Ó", "O", "Ô", "O", "Õ", "O", "Ú", "U", "Ü", "U", "Ç", "C",
+)
+
+// plain remove acentos e caracteres fora do ASCII e corta em size caracteres
+func plain(text string, size int) string {
+	var out strings.Builder
+	for _, r := range unaccent.Replace(strings.TrimSpace(text)) {
+		if r < 0x20 || r > 0x7E {
+			continue
+		}
+		out.WriteRune(r)
+	}
+	result := out.String()
+	if len(result) > size {
+		result = result[:size]
+	}
+	return result
+}
+
+// alphanumeric mantém só letras e números ASCII, até size caracteres
+func alphanumeric(text string, size int) string {
+	var out strings.Builder
+	for _, r := range plain(text, len(text)) {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			out.WriteRune(r)
+		}
+	}
+	result := out.String()
+	if len(result) > size {
+		result = result[:size]
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+// Package cache guarda leituras frequentes de dados que mudam pouco (produtos e contatos por
+// ID), com expiração e invalidação pelos métodos de escrita dos repositórios.
+package cache
+
+import (
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Cache é o contrato usado pelos repositórios. A implementação em memória (LRU) atende uma
+// instância; um cache compartilhado entre instâncias (ex.: Redis) pode implementá-lo sem
+// alterar os repositórios.
+type Cache[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Set(key K, value V)
+	Delete(keys ...K)
+	Purge()
+}
+
+var (
+	_ Cache[int, int] = (*LRU[int, int])(nil)
+	_ Cache[int, int] = (*Reference[int, int])(nil)
+)
+
+// Reference é o cache de um tipo de dado de referência. É criado na primeira utilização com
+// REFERENCE_CACHE_SIZE e REFERENCE_CACHE_TTL, pois os repositórios o declaram como variável
+// de pacote, antes de a configuração ser carregada.
+type Reference[K comparable, V any] struct {
+	name string
+	once sync.Once
+	lru  *LRU[K, V]
+}
+
+// NewReference declara o cache; name identifica as métricas (cache_requests_total)
+func NewReference[K comparable, V any](name string) *Reference[K, V] {
+	return &Reference[K, V]{name: name}
+}
+
+func (r *Reference[K, V]) cache() *LRU[K, V] {
+	r.once.Do(func() {
+		r.lru = NewLRU[K, V](r.name, viper.GetInt("REFERENCE_CACHE_SIZE"), viper.GetDuration("REFERENCE_CACHE_TTL"))
+	})
+	return r.lru
+}
+
+// Get retorna o valor em cache
+func (r *Reference[K, V]) Get(key K) (V, bool) {
+	return r.cache().Get(key)
+}
+
+// Set guarda o valor lido do banco
+func (r *Reference[K, V]) Set(key K, value V) {
+	r.cache().Set(key, value)
+}
+
+// Delete invalida as chaves alteradas por uma escrita
+func (r *Reference[K, V]) Delete(keys ...K) {
+	r.cache().Delete(keys...)
+}
+
+// Purge esvazia o cache
+func (r *Reference[K, V]) Purge() {
+	r.cache().Purge()
+}
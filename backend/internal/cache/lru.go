@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/metrics"
+)
+
+// now é substituído nos testes para simular a expiração
+var now = time.Now
+
+// LRU é um cache em memória com tamanho máximo e expiração. Ao atingir o tamanho, descarta a
+// entrada usada há mais tempo. Tamanho zero desativa o cache; TTL zero não expira as entradas.
+type LRU[K comparable, V any] struct {
+	name string
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	order   *list.List // da mais recente para a mais antiga
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU cria o cache com até size entradas, cada uma válida por ttl
+func NewLRU[K comparable, V any](name string, size int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		name:    name,
+		size:    size,
+		ttl:     ttl,
+		entries: map[K]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Get retorna o valor se ele estiver no cache e não tiver expirado
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c.size <= 0 {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		metrics.CacheRequests.Inc(c.name, "miss")
+		return zero, false
+	}
+	e := elem.Value.(*entry[K, V])
+	if !e.expiresAt.IsZero() && !now().Before(e.expiresAt) {
+		c.remove(elem)
+		metrics.CacheEvictions.Inc(c.name)
+		metrics.CacheRequests.Inc(c.name, "miss")
+		return zero, false
+	}
+
+	c.order.MoveToFront(elem)
+	metrics.CacheRequests.Inc(c.name, "hit")
+	return e.value, true
+}
+
+// Set guarda o valor, descartando a entrada mais antiga se o cache estiver cheio
+func (c *LRU[K, V]) Set(key K, value V) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = now().Add(c.ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		metrics.CacheEvictions.Inc(c.name)
+	}
+}
+
+// Delete remove as chaves do cache
+func (c *LRU[K, V]) Delete(keys ...K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+	}
+}
+
+// Purge remove todas as entradas
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[K]*list.Element{}
+	c.order.Init()
+}
+
+// Len retorna a quantidade de entradas guardadas, inclusive as expiradas ainda não lidas
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU[K, V]) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*entry[K, V]).key)
+}
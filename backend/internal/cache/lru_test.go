@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeClock(t *testing.T) *time.Time {
+	current := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	original := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = original })
+	return &current
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[int, string]("test", 2, time.Minute)
+
+	c.Set(1, "um")
+	c.Set(2, "dois")
+	_, _ = c.Get(1) // 2 passa a ser a menos usada
+	c.Set(3, "três")
+
+	_, ok := c.Get(2)
+	assert.False(t, ok)
+	value, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "um", value)
+	_, ok = c.Get(3)
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	clock := fakeClock(t)
+	c := NewLRU[int, string]("test", 10, time.Minute)
+
+	c.Set(1, "um")
+	*clock = clock.Add(59 * time.Second)
+	_, ok := c.Get(1)
+	assert.True(t, ok)
+
+	*clock = clock.Add(time.Second)
+	_, ok = c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRUSetRefreshesValueAndExpiration(t *testing.T) {
+	clock := fakeClock(t)
+	c := NewLRU[int, string]("test", 10, time.Minute)
+
+	c.Set(1, "um")
+	*clock = clock.Add(50 * time.Second)
+	c.Set(1, "novo")
+	*clock = clock.Add(50 * time.Second)
+
+	value, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "novo", value)
+}
+
+func TestLRUDeleteAndPurge(t *testing.T) {
+	c := NewLRU[int, string]("test", 10, time.Minute)
+	c.Set(1, "um")
+	c.Set(2, "dois")
+	c.Set(3, "três")
+
+	c.Delete(1, 99)
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRUDisabledWithZeroSize(t *testing.T) {
+	c := NewLRU[int, string]("test", 0, time.Minute)
+
+	c.Set(1, "um")
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestReferenceReadsConfigOnFirstUse(t *testing.T) {
+	viper.Set("REFERENCE_CACHE_SIZE", 1)
+	viper.Set("REFERENCE_CACHE_TTL", "1m")
+	t.Cleanup(func() {
+		viper.Set("REFERENCE_CACHE_SIZE", nil)
+		viper.Set("REFERENCE_CACHE_TTL", nil)
+	})
+	ref := NewReference[int, string]("test")
+
+	ref.Set(1, "um")
+	ref.Set(2, "dois")
+
+	_, ok := ref.Get(1)
+	assert.False(t, ok, "tamanho 1 mantém só a última entrada")
+	value, ok := ref.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, "dois", value)
+}
@@ -1,31 +1,222 @@
 package config
 
 import (
+	"encoding/base64"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"ERP-ONSMART/backend/internal/money"
+
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
 // Config é a estrutura que armazena todas as configurações do sistema.
 type Config struct {
-	Port             string
-	Env              string
-	DBHost           string
-	DBPort           string
-	DBUser           string
-	DBPassword       string
-	DBName           string
+	Server   ServerConfig
+	Database DatabaseConfig
+	Auth     AuthConfig
+	SMTP     SMTPConfig
+	Storage  StorageConfig
+	Gateways GatewaysConfig
+	Jobs     JobsConfig
+	Backup   BackupConfig
+	Tenant   TenantConfig
+	Cache    CacheConfig
+	// Valores padrão das feature flags (seção features do YAML ou FEATURE_<NOME>); o valor
+	// gravado no banco, quando existe, prevalece (ver modules/featureflags)
+	Features map[string]bool
+	// Intervalo de recarga das feature flags gravadas no banco
+	FeatureFlagsRefresh time.Duration
+}
+
+// ServerConfig reúne as configurações do servidor HTTP
+type ServerConfig struct {
+	Port string
+	Env  string
+	// Porta da API gRPC interna; vazia desativa o servidor gRPC
+	GRPCPort string
+}
+
+// DatabaseConfig reúne os dados de conexão com o PostgreSQL
+type DatabaseConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	// Réplica somente leitura usada pelas consultas de relatórios; vazio usa o primário
+	ReplicaHost string
+	ReplicaPort string
+}
+
+// AuthConfig reúne as configurações dos tokens JWT e da gestão de usuários
+type AuthConfig struct {
 	JWTSecret        string
 	TokenExpiresIn   time.Duration
 	RefreshExpiresIn time.Duration
-	// Outras configurações podem ser adicionadas aqui
+	// Tentativas de login erradas seguidas até o bloqueio da conta (0 desativa o bloqueio)
+	MaxFailedLogins  int
+	LockoutDuration  time.Duration
+	InviteTTL        time.Duration
+	PasswordResetTTL time.Duration
+	// Validade dos links de compartilhamento de cotações
+	QuotationShareTTL time.Duration
+	// Endereço do front-end usado nos links dos e-mails de convite e redefinição de senha
+	FrontendURL string
+	// Chave do cofre que cifra as credenciais gravadas no banco (base64 de 32 bytes); sem ela não
+	// é possível cadastrar servidores de troca de arquivos
+	VaultKey string
+}
+
+// SMTPConfig reúne os dados do servidor de e-mail (Host vazio desativa o envio)
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+}
+
+// StorageConfig reúne as configurações de armazenamento dos anexos
+type StorageConfig struct {
+	Driver    string // local ou s3
+	Dir       string
+	MaxSizeMB int64
+	S3        S3Config
+}
+
+// S3Config reúne os dados do bucket usado quando Storage.Driver é s3
+type S3Config struct {
+	Bucket          string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// GatewaysConfig reúne as integrações externas
+type GatewaysConfig struct {
+	CorreiosURL          string
+	CorreiosToken        string
+	JadlogURL            string
+	JadlogToken          string
+	CarrierWebhookSecret string
+	// Cotação de frete: APIs de preço e prazo dos Correios, conta de embarcador da Jadlog,
+	// serviços cotados (código:nome) e CEP de origem das remessas
+	CorreiosRateURL      string
+	CorreiosRateServices string
+	JadlogCNPJ           string
+	JadlogAccount        string
+	JadlogRateServices   string
+	ShippingOriginCEP    string
+	// Segredo dos webhooks de e-mail de entrada (notas fiscais de fornecedores)
+	InboundEmailSecret string
+	// Consulta de CNPJ e CEP no cadastro de contatos: provedores de CNPJ em ordem de tentativa,
+	// endereços das APIs, tokens das APIs comerciais e validade do cache local das consultas
+	CNPJProviders    string
+	BrasilAPIURL     string
+	ReceitaWSURL     string
+	ReceitaWSToken   string
+	CNPJaURL         string
+	CNPJaToken       string
+	ViaCEPURL        string
+	RegistryCacheTTL time.Duration
+}
+
+// JobsConfig reúne as rotinas em segundo plano e seus parâmetros
+type JobsConfig struct {
+	// Método de custeio padrão dos produtos (average ou fifo)
+	DefaultCostingMethod string
+	// Intervalo de consulta automática do rastreamento nas transportadoras (0 desativa)
+	TrackingPollInterval time.Duration
+	// Intervalo da geração automática de sugestões de compra pelo ponto de reposição (0 desativa)
+	ReorderScanInterval time.Duration
+	// Prazo padrão de entrega dos fornecedores, em dias
+	PurchaseLeadTimeDays int
+	// Tolerâncias da conferência de três vias (pedido, recebimento e conta), em percentual
+	ThreeWayMatchQtyTolerance   money.Decimal
+	ThreeWayMatchPriceTolerance money.Decimal
+	// Intervalo da sincronização automática das lojas virtuais (0 desativa)
+	EcommerceSyncInterval time.Duration
+	// Intervalo do recálculo das pontuações RFM e do segmento dos clientes (0 desativa)
+	RFMScoreInterval time.Duration
+	// Intervalo da verificação dos relatórios com envio agendado por e-mail (0 desativa)
+	ReportScheduleInterval time.Duration
+	// Intervalo da verificação dos contratos a vencer para os alertas (0 desativa)
+	ContractExpiryInterval time.Duration
+	// Intervalo do cálculo do status de SLA das entregas e processos de venda (0 desativa)
+	SLAEvalInterval time.Duration
+	// Intervalo da execução das regras de acompanhamento dos processos de venda parados (0 desativa)
+	FollowupInterval time.Duration
+	// Intervalo da geração das tarefas de cobrança das faturas vencidas (0 desativa) e dias de
+	// atraso a partir dos quais a fatura gera a ligação de cobrança
+	TaskAutomationInterval time.Duration
+	TaskDunningDays        int
+	// Intervalo da revalidação dos CNPJs dos contatos na Receita Federal (0 desativa) e quantidade
+	// de CNPJs consultados por execução
+	CNPJRevalidationInterval time.Duration
+	CNPJRevalidationBatch    int
+	// Intervalo do processamento das rotinas e da fila de troca de arquivos por SFTP/FTP (0 desativa)
+	FileExchangeInterval time.Duration
+	// Intervalo da consulta das cotações das moedas e da reavaliação cambial do fim do mês (0 desativa)
+	ExchangeRateInterval time.Duration
 }
 
-// LoadConfig carrega as configurações a partir do arquivo .env e das variáveis de ambiente.
+// BackupConfig reúne a fila dos backups do banco, das restaurações e das exportações dos dados
+// das empresas
+type BackupConfig struct {
+	// Diretório dos arquivos dos backups e das exportações (subdiretório exports)
+	Dir string
+	// Intervalo de processamento da fila (0 desativa: os pedidos ficam na fila sem execução)
+	RunnerInterval time.Duration
+	// Intervalo dos backups automáticos (0 desativa) e quantidade de backups automáticos
+	// mantidos (0 mantém todos)
+	ScheduleInterval time.Duration
+	Retention        int
+	// Tempo máximo de cada job; os jobs em andamento além dele são dados como interrompidos
+	JobTimeout time.Duration
+	// Executáveis do pg_dump e do pg_restore
+	PgDumpPath    string
+	PgRestorePath string
+}
+
+// TenantConfig reúne as configurações do isolamento por empresa
+type TenantConfig struct {
+	// Empresa usada quando a requisição não informa uma (0 torna a empresa obrigatória)
+	DefaultCompanyID int
+	// Recusa queries sem empresa no contexto em vez de executá-las sem filtro (padrão: true)
+	Strict bool
+	// Fuso horário (nome IANA) das empresas sem fuso cadastrado e das rotinas sem empresa
+	DefaultTimezone string
+}
+
+// CacheConfig reúne o cache em memória das leituras de dados de referência (produtos e
+// contatos por ID)
+type CacheConfig struct {
+	// Quantidade máxima de entradas por cache (0 desativa)
+	Size int
+	// Tempo máximo que uma entrada fica no cache; limita a defasagem entre instâncias
+	TTL time.Duration
+}
+
+// Ambientes aceitos em ENV
+var validEnvs = []string{"development", "test", "staging", "production"}
+
+// JWT_SECRET padrão, aceito apenas fora de produção
+const defaultJWTSecret = "changemejwtkey"
+
+var featureNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// LoadConfig carrega as configurações a partir do arquivo .env, do YAML opcional (CONFIG_FILE,
+// padrão config.yaml) e das variáveis de ambiente, que prevalecem sobre o YAML. Valores
+// inválidos interrompem a inicialização com a lista completa de problemas.
 func LoadConfig() (*Config, error) {
 	// Obtém o diretório atual onde o comando foi executado
 	wd, err := os.Getwd()
@@ -44,8 +235,24 @@ func LoadConfig() (*Config, error) {
 
 	// Habilita o Viper para capturar variáveis de ambiente automaticamente.
 	viper.AutomaticEnv()
+	setDefaults()
+
+	if err := readConfigFile(wd); err != nil {
+		return nil, err
+	}
+
+	cfg, err := build()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
-	// Define valores padrão para as variáveis, caso não estejam definidas.
+// setDefaults define valores padrão para as variáveis, caso não estejam definidas.
+func setDefaults() {
 	viper.SetDefault("PORT", "8080")
 	viper.SetDefault("ENV", "development")
 	viper.SetDefault("DB_HOST", "localhost")
@@ -53,23 +260,495 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("DB_USER", "erp_user")
 	viper.SetDefault("DB_PASSWORD", "changeme")
 	viper.SetDefault("DB_NAME", "erp_db")
-	viper.SetDefault("JWT_SECRET", "changemejwtkey")
+	viper.SetDefault("JWT_SECRET", defaultJWTSecret)
 	viper.SetDefault("TOKEN_EXPIRES_IN", "15m")
 	viper.SetDefault("REFRESH_EXPIRES_IN", "7d")
+	viper.SetDefault("USER_MAX_FAILED_LOGINS", 5)
+	viper.SetDefault("USER_LOCKOUT_DURATION", "15m")
+	viper.SetDefault("USER_INVITE_TTL", "72h")
+	viper.SetDefault("PASSWORD_RESET_TTL", "1h")
+	viper.SetDefault("QUOTATION_SHARE_TTL", "168h")
+	viper.SetDefault("FRONTEND_URL", "http://localhost:3000")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("DEFAULT_COSTING_METHOD", "average")
+	viper.SetDefault("CORREIOS_API_URL", "https://api.correios.com.br/srorastro/v1")
+	viper.SetDefault("JADLOG_API_URL", "https://www.jadlog.com.br/embarcador/api")
+	viper.SetDefault("CORREIOS_RATE_API_URL", "https://api.correios.com.br")
+	viper.SetDefault("CORREIOS_RATE_SERVICES", "03220:SEDEX,03298:PAC")
+	viper.SetDefault("JADLOG_RATE_SERVICES", "3:.Package")
+	viper.SetDefault("TRACKING_POLL_INTERVAL", "0")
+	viper.SetDefault("REORDER_SCAN_INTERVAL", "0")
+	viper.SetDefault("PURCHASE_LEAD_TIME_DAYS", 7)
+	viper.SetDefault("THREE_WAY_MATCH_QTY_TOLERANCE", 0)
+	viper.SetDefault("THREE_WAY_MATCH_PRICE_TOLERANCE", 2)
+	viper.SetDefault("ECOMMERCE_SYNC_INTERVAL", "0")
+	viper.SetDefault("RFM_SCORE_INTERVAL", "0")
+	viper.SetDefault("REPORT_SCHEDULE_INTERVAL", "0")
+	viper.SetDefault("CONTRACT_EXPIRY_INTERVAL", "0")
+	viper.SetDefault("SLA_EVAL_INTERVAL", "0")
+	viper.SetDefault("FOLLOWUP_INTERVAL", "0")
+	viper.SetDefault("TASKS_AUTOMATION_INTERVAL", "0")
+	viper.SetDefault("TASKS_DUNNING_DAYS", 3)
+	viper.SetDefault("CNPJ_PROVIDERS", "brasilapi,receitaws")
+	viper.SetDefault("BRASILAPI_URL", "https://brasilapi.com.br/api")
+	viper.SetDefault("RECEITAWS_URL", "https://receitaws.com.br/v1")
+	viper.SetDefault("CNPJA_URL", "https://open.cnpja.com")
+	viper.SetDefault("VIACEP_URL", "https://viacep.com.br/ws")
+	viper.SetDefault("REGISTRY_CACHE_TTL", "720h")
+	viper.SetDefault("CNPJ_REVALIDATION_INTERVAL", "0")
+	viper.SetDefault("CNPJ_REVALIDATION_BATCH", 200)
+	viper.SetDefault("FILE_EXCHANGE_INTERVAL", "0")
+	viper.SetDefault("EXCHANGE_RATE_INTERVAL", "0")
+	viper.SetDefault("EXCHANGE_RATE_PROVIDERS", "ptax,ecb")
+	viper.SetDefault("EXCHANGE_RATE_CURRENCIES", "USD,EUR")
+	viper.SetDefault("PTAX_URL", "https://olinda.bcb.gov.br/olinda/servico/PTAX/versao/v1/odata")
+	viper.SetDefault("ECB_RATES_URL", "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist-90d.xml")
+	viper.SetDefault("BACKUP_DIR", "backups")
+	viper.SetDefault("BACKUP_RUNNER_INTERVAL", "30s")
+	viper.SetDefault("BACKUP_SCHEDULE_INTERVAL", "0")
+	viper.SetDefault("BACKUP_RETENTION", 7)
+	viper.SetDefault("BACKUP_JOB_TIMEOUT", "6h")
+	viper.SetDefault("PG_DUMP_PATH", "pg_dump")
+	viper.SetDefault("PG_RESTORE_PATH", "pg_restore")
+	viper.SetDefault("ATTACHMENTS_STORAGE", "local")
+	viper.SetDefault("ATTACHMENTS_DIR", "uploads")
+	viper.SetDefault("ATTACHMENTS_MAX_SIZE_MB", 20)
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	viper.SetDefault("QUERY_BUDGET", 50)
+	viper.SetDefault("METRICS_TOKEN", "")
+	viper.SetDefault("FEATURE_FLAGS_REFRESH", "30s")
+	viper.SetDefault("DEFAULT_COMPANY_ID", 1)
+	viper.SetDefault("TENANT_STRICT", true)
+	viper.SetDefault("DEFAULT_TIMEZONE", "America/Sao_Paulo")
+	viper.SetDefault("REFERENCE_CACHE_SIZE", 1000)
+	viper.SetDefault("REFERENCE_CACHE_TTL", "5m")
+}
+
+// readConfigFile lê o YAML de configuração. As chaves são as mesmas das variáveis de ambiente
+// (em minúsculas ou maiúsculas) e as feature flags ficam na seção features. Sem CONFIG_FILE,
+// o config.yaml do diretório atual é opcional.
+func readConfigFile(wd string) error {
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = filepath.Join(wd, "config.yaml")
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+	}
+
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("[config.go]: erro ao ler arquivo de configuração %s: %w", path, err)
+	}
+	log.Printf("[config.go]: configurações carregadas de %s\n", path)
+	return nil
+}
+
+// build monta a configuração tipada a partir do Viper
+func build() (*Config, error) {
+	var problems []string
+	duration := func(key string) time.Duration {
+		d, err := ParseDuration(viper.GetString(key))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+		}
+		return d
+	}
+	integer := func(key string) int64 {
+		n, err := strconv.ParseInt(strings.TrimSpace(viper.GetString(key)), 10, 64)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: número inteiro inválido %q", key, viper.GetString(key)))
+		}
+		return n
+	}
+	decimal := func(key string) money.Decimal {
+		d, err := money.Parse(viper.GetString(key))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: número decimal inválido %q", key, viper.GetString(key)))
+		}
+		return d
+	}
 
-	// Cria a instância de configuração
 	cfg := &Config{
-		Port:             viper.GetString("PORT"),
-		Env:              viper.GetString("ENV"),
-		DBHost:           viper.GetString("DB_HOST"),
-		DBPort:           viper.GetString("DB_PORT"),
-		DBUser:           viper.GetString("DB_USER"),
-		DBPassword:       viper.GetString("DB_PASSWORD"),
-		DBName:           viper.GetString("DB_NAME"),
-		JWTSecret:        viper.GetString("JWT_SECRET"),
-		TokenExpiresIn:   viper.GetDuration("TOKEN_EXPIRES_IN"),
-		RefreshExpiresIn: viper.GetDuration("REFRESH_EXPIRES_IN"),
+		Server: ServerConfig{
+			Port:     viper.GetString("PORT"),
+			Env:      viper.GetString("ENV"),
+			GRPCPort: viper.GetString("GRPC_PORT"),
+		},
+		Database: DatabaseConfig{
+			Host:     viper.GetString("DB_HOST"),
+			Port:     viper.GetString("DB_PORT"),
+			User:     viper.GetString("DB_USER"),
+			Password: viper.GetString("DB_PASSWORD"),
+			Name:     viper.GetString("DB_NAME"),
+
+			ReplicaHost: viper.GetString("DB_REPLICA_HOST"),
+			ReplicaPort: viper.GetString("DB_REPLICA_PORT"),
+		},
+		Auth: AuthConfig{
+			JWTSecret:         viper.GetString("JWT_SECRET"),
+			TokenExpiresIn:    duration("TOKEN_EXPIRES_IN"),
+			RefreshExpiresIn:  duration("REFRESH_EXPIRES_IN"),
+			MaxFailedLogins:   int(integer("USER_MAX_FAILED_LOGINS")),
+			LockoutDuration:   duration("USER_LOCKOUT_DURATION"),
+			InviteTTL:         duration("USER_INVITE_TTL"),
+			PasswordResetTTL:  duration("PASSWORD_RESET_TTL"),
+			QuotationShareTTL: duration("QUOTATION_SHARE_TTL"),
+			FrontendURL:       viper.GetString("FRONTEND_URL"),
+			VaultKey:          viper.GetString("VAULT_KEY"),
+		},
+		SMTP: SMTPConfig{
+			Host:     viper.GetString("SMTP_HOST"),
+			Port:     int(integer("SMTP_PORT")),
+			User:     viper.GetString("SMTP_USER"),
+			Password: viper.GetString("SMTP_PASSWORD"),
+			From:     viper.GetString("SMTP_FROM"),
+		},
+		Storage: StorageConfig{
+			Driver:    viper.GetString("ATTACHMENTS_STORAGE"),
+			Dir:       viper.GetString("ATTACHMENTS_DIR"),
+			MaxSizeMB: integer("ATTACHMENTS_MAX_SIZE_MB"),
+			S3: S3Config{
+				Bucket:          viper.GetString("S3_BUCKET"),
+				Region:          viper.GetString("S3_REGION"),
+				Endpoint:        viper.GetString("S3_ENDPOINT"),
+				AccessKeyID:     viper.GetString("S3_ACCESS_KEY_ID"),
+				SecretAccessKey: viper.GetString("S3_SECRET_ACCESS_KEY"),
+			},
+		},
+		Gateways: GatewaysConfig{
+			CorreiosURL:          viper.GetString("CORREIOS_API_URL"),
+			CorreiosToken:        viper.GetString("CORREIOS_API_TOKEN"),
+			JadlogURL:            viper.GetString("JADLOG_API_URL"),
+			JadlogToken:          viper.GetString("JADLOG_API_TOKEN"),
+			CarrierWebhookSecret: viper.GetString("CARRIER_WEBHOOK_SECRET"),
+			InboundEmailSecret:   viper.GetString("INBOUND_EMAIL_SECRET"),
+			CorreiosRateURL:      viper.GetString("CORREIOS_RATE_API_URL"),
+			CorreiosRateServices: viper.GetString("CORREIOS_RATE_SERVICES"),
+			JadlogCNPJ:           viper.GetString("JADLOG_CNPJ"),
+			JadlogAccount:        viper.GetString("JADLOG_ACCOUNT"),
+			JadlogRateServices:   viper.GetString("JADLOG_RATE_SERVICES"),
+			ShippingOriginCEP:    viper.GetString("SHIPPING_ORIGIN_CEP"),
+			CNPJProviders:        viper.GetString("CNPJ_PROVIDERS"),
+			BrasilAPIURL:         viper.GetString("BRASILAPI_URL"),
+			ReceitaWSURL:         viper.GetString("RECEITAWS_URL"),
+			ReceitaWSToken:       viper.GetString("RECEITAWS_TOKEN"),
+			CNPJaURL:             viper.GetString("CNPJA_URL"),
+			CNPJaToken:           viper.GetString("CNPJA_TOKEN"),
+			ViaCEPURL:            viper.GetString("VIACEP_URL"),
+			RegistryCacheTTL:     duration("REGISTRY_CACHE_TTL"),
+		},
+		Jobs: JobsConfig{
+			DefaultCostingMethod:        viper.GetString("DEFAULT_COSTING_METHOD"),
+			TrackingPollInterval:        duration("TRACKING_POLL_INTERVAL"),
+			ReorderScanInterval:         duration("REORDER_SCAN_INTERVAL"),
+			PurchaseLeadTimeDays:        int(integer("PURCHASE_LEAD_TIME_DAYS")),
+			ThreeWayMatchQtyTolerance:   decimal("THREE_WAY_MATCH_QTY_TOLERANCE"),
+			ThreeWayMatchPriceTolerance: decimal("THREE_WAY_MATCH_PRICE_TOLERANCE"),
+			EcommerceSyncInterval:       duration("ECOMMERCE_SYNC_INTERVAL"),
+			RFMScoreInterval:            duration("RFM_SCORE_INTERVAL"),
+			ReportScheduleInterval:      duration("REPORT_SCHEDULE_INTERVAL"),
+			ContractExpiryInterval:      duration("CONTRACT_EXPIRY_INTERVAL"),
+			SLAEvalInterval:             duration("SLA_EVAL_INTERVAL"),
+			FollowupInterval:            duration("FOLLOWUP_INTERVAL"),
+			TaskAutomationInterval:      duration("TASKS_AUTOMATION_INTERVAL"),
+			TaskDunningDays:             int(integer("TASKS_DUNNING_DAYS")),
+			CNPJRevalidationInterval:    duration("CNPJ_REVALIDATION_INTERVAL"),
+			CNPJRevalidationBatch:       int(integer("CNPJ_REVALIDATION_BATCH")),
+			FileExchangeInterval:        duration("FILE_EXCHANGE_INTERVAL"),
+			ExchangeRateInterval:        duration("EXCHANGE_RATE_INTERVAL"),
+		},
+		Backup: BackupConfig{
+			Dir:              viper.GetString("BACKUP_DIR"),
+			RunnerInterval:   duration("BACKUP_RUNNER_INTERVAL"),
+			ScheduleInterval: duration("BACKUP_SCHEDULE_INTERVAL"),
+			Retention:        int(integer("BACKUP_RETENTION")),
+			JobTimeout:       duration("BACKUP_JOB_TIMEOUT"),
+			PgDumpPath:       viper.GetString("PG_DUMP_PATH"),
+			PgRestorePath:    viper.GetString("PG_RESTORE_PATH"),
+		},
+		Tenant: TenantConfig{
+			DefaultCompanyID: int(integer("DEFAULT_COMPANY_ID")),
+			Strict:           viper.GetBool("TENANT_STRICT"),
+			DefaultTimezone:  viper.GetString("DEFAULT_TIMEZONE"),
+		},
+		Cache: CacheConfig{
+			Size: int(integer("REFERENCE_CACHE_SIZE")),
+			TTL:  duration("REFERENCE_CACHE_TTL"),
+		},
+		FeatureFlagsRefresh: duration("FEATURE_FLAGS_REFRESH"),
 	}
 
+	features, featureProblems := loadFeatures()
+	cfg.Features = features
+	problems = append(problems, featureProblems...)
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
 	return cfg, nil
 }
+
+// loadFeatures lê a seção features do YAML e as variáveis FEATURE_<NOME> (que prevalecem)
+func loadFeatures() (map[string]bool, []string) {
+	features := map[string]bool{}
+	var problems []string
+
+	for name, value := range viper.GetStringMap("features") {
+		enabled, err := strconv.ParseBool(fmt.Sprint(value))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("features.%s: valor booleano inválido %q", name, fmt.Sprint(value)))
+			continue
+		}
+		features[strings.ToLower(name)] = enabled
+	}
+
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		name, ok := strings.CutPrefix(key, "FEATURE_")
+		if !ok || key == "FEATURE_FLAGS_REFRESH" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: valor booleano inválido %q", key, value))
+			continue
+		}
+		features[strings.ToLower(name)] = enabled
+	}
+	return features, problems
+}
+
+// Validate verifica a consistência das configurações e devolve todos os problemas encontrados
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		add("PORT: porta inválida %q", c.Server.Port)
+	}
+	if c.Server.GRPCPort != "" {
+		if port, err := strconv.Atoi(c.Server.GRPCPort); err != nil || port < 1 || port > 65535 {
+			add("GRPC_PORT: porta inválida %q", c.Server.GRPCPort)
+		} else if c.Server.GRPCPort == c.Server.Port {
+			add("GRPC_PORT: deve ser diferente de PORT")
+		}
+	}
+	if !contains(validEnvs, c.Server.Env) {
+		add("ENV: ambiente inválido %q (aceitos: %s)", c.Server.Env, strings.Join(validEnvs, ", "))
+	}
+
+	if c.Database.Host == "" {
+		add("DB_HOST: obrigatório")
+	}
+	if c.Database.Name == "" {
+		add("DB_NAME: obrigatório")
+	}
+	if c.Database.User == "" {
+		add("DB_USER: obrigatório")
+	}
+	if c.Database.ReplicaPort != "" {
+		if port, err := strconv.Atoi(c.Database.ReplicaPort); err != nil || port < 1 || port > 65535 {
+			add("DB_REPLICA_PORT: porta inválida %q", c.Database.ReplicaPort)
+		}
+	}
+
+	if c.Auth.JWTSecret == "" {
+		add("JWT_SECRET: obrigatório")
+	} else if c.Server.Env == "production" && c.Auth.JWTSecret == defaultJWTSecret {
+		add("JWT_SECRET: o valor padrão não pode ser usado em produção")
+	}
+	if c.Auth.TokenExpiresIn <= 0 {
+		add("TOKEN_EXPIRES_IN: deve ser maior que zero")
+	}
+	if c.Auth.RefreshExpiresIn < c.Auth.TokenExpiresIn {
+		add("REFRESH_EXPIRES_IN: deve ser maior ou igual a TOKEN_EXPIRES_IN")
+	}
+	if c.Auth.MaxFailedLogins < 0 {
+		add("USER_MAX_FAILED_LOGINS: não pode ser negativo")
+	}
+	if c.Auth.MaxFailedLogins > 0 && c.Auth.LockoutDuration <= 0 {
+		add("USER_LOCKOUT_DURATION: deve ser maior que zero")
+	}
+	if c.Auth.InviteTTL <= 0 {
+		add("USER_INVITE_TTL: deve ser maior que zero")
+	}
+	if c.Auth.PasswordResetTTL <= 0 {
+		add("PASSWORD_RESET_TTL: deve ser maior que zero")
+	}
+	if c.Auth.QuotationShareTTL <= 0 {
+		add("QUOTATION_SHARE_TTL: deve ser maior que zero")
+	}
+	if c.Auth.VaultKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(c.Auth.VaultKey); err != nil || len(key) != 32 {
+			add("VAULT_KEY: deve ser o base64 de uma chave de 32 bytes")
+		}
+	}
+
+	if c.SMTP.Host != "" {
+		if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+			add("SMTP_PORT: porta inválida %d", c.SMTP.Port)
+		}
+		if c.SMTP.From == "" {
+			add("SMTP_FROM: obrigatório quando SMTP_HOST é definido")
+		}
+	}
+
+	switch c.Storage.Driver {
+	case "local":
+		if c.Storage.Dir == "" {
+			add("ATTACHMENTS_DIR: obrigatório com ATTACHMENTS_STORAGE=local")
+		}
+	case "s3":
+		if c.Storage.S3.Bucket == "" {
+			add("S3_BUCKET: obrigatório com ATTACHMENTS_STORAGE=s3")
+		}
+	default:
+		add("ATTACHMENTS_STORAGE: armazenamento inválido %q (aceitos: local, s3)", c.Storage.Driver)
+	}
+	if c.Storage.MaxSizeMB <= 0 {
+		add("ATTACHMENTS_MAX_SIZE_MB: deve ser maior que zero")
+	}
+
+	if c.Jobs.DefaultCostingMethod != "average" && c.Jobs.DefaultCostingMethod != "fifo" {
+		add("DEFAULT_COSTING_METHOD: método inválido %q (aceitos: average, fifo)", c.Jobs.DefaultCostingMethod)
+	}
+	if c.Jobs.TrackingPollInterval < 0 {
+		add("TRACKING_POLL_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.ReorderScanInterval < 0 {
+		add("REORDER_SCAN_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.PurchaseLeadTimeDays < 0 {
+		add("PURCHASE_LEAD_TIME_DAYS: não pode ser negativo")
+	}
+	if c.Jobs.ThreeWayMatchQtyTolerance.IsNegative() {
+		add("THREE_WAY_MATCH_QTY_TOLERANCE: não pode ser negativo")
+	}
+	if c.Jobs.ThreeWayMatchPriceTolerance.IsNegative() {
+		add("THREE_WAY_MATCH_PRICE_TOLERANCE: não pode ser negativo")
+	}
+	if c.Jobs.EcommerceSyncInterval < 0 {
+		add("ECOMMERCE_SYNC_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.RFMScoreInterval < 0 {
+		add("RFM_SCORE_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.ReportScheduleInterval < 0 {
+		add("REPORT_SCHEDULE_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.ContractExpiryInterval < 0 {
+		add("CONTRACT_EXPIRY_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.SLAEvalInterval < 0 {
+		add("SLA_EVAL_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.FollowupInterval < 0 {
+		add("FOLLOWUP_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.TaskAutomationInterval < 0 {
+		add("TASKS_AUTOMATION_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.TaskDunningDays < 0 {
+		add("TASKS_DUNNING_DAYS: não pode ser negativo")
+	}
+	if c.Jobs.CNPJRevalidationInterval < 0 {
+		add("CNPJ_REVALIDATION_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.CNPJRevalidationInterval > 0 && c.Jobs.CNPJRevalidationBatch < 1 {
+		add("CNPJ_REVALIDATION_BATCH: deve ser maior que zero com a revalidação ativa")
+	}
+	if c.Jobs.FileExchangeInterval < 0 {
+		add("FILE_EXCHANGE_INTERVAL: não pode ser negativo")
+	}
+	if c.Jobs.ExchangeRateInterval < 0 {
+		add("EXCHANGE_RATE_INTERVAL: não pode ser negativo")
+	}
+	if c.Backup.Dir == "" {
+		add("BACKUP_DIR: obrigatório")
+	}
+	if c.Backup.RunnerInterval < 0 {
+		add("BACKUP_RUNNER_INTERVAL: não pode ser negativo")
+	}
+	if c.Backup.ScheduleInterval < 0 {
+		add("BACKUP_SCHEDULE_INTERVAL: não pode ser negativo")
+	}
+	if c.Backup.ScheduleInterval > 0 && c.Backup.RunnerInterval == 0 {
+		add("BACKUP_SCHEDULE_INTERVAL: exige BACKUP_RUNNER_INTERVAL maior que zero")
+	}
+	if c.Backup.Retention < 0 {
+		add("BACKUP_RETENTION: não pode ser negativo")
+	}
+	if c.Backup.JobTimeout <= 0 {
+		add("BACKUP_JOB_TIMEOUT: deve ser maior que zero")
+	}
+	if c.Gateways.RegistryCacheTTL < 0 {
+		add("REGISTRY_CACHE_TTL: não pode ser negativo")
+	}
+
+	if c.Tenant.DefaultCompanyID < 0 {
+		add("DEFAULT_COMPANY_ID: não pode ser negativo")
+	}
+	if _, err := time.LoadLocation(c.Tenant.DefaultTimezone); err != nil || c.Tenant.DefaultTimezone == "" {
+		add("DEFAULT_TIMEZONE: fuso horário inválido %q (use um nome IANA, como America/Sao_Paulo)", c.Tenant.DefaultTimezone)
+	}
+
+	if c.Cache.Size < 0 {
+		add("REFERENCE_CACHE_SIZE: não pode ser negativo")
+	}
+	if c.Cache.Size > 0 && c.Cache.TTL <= 0 {
+		add("REFERENCE_CACHE_TTL: deve ser maior que zero com o cache ativo")
+	}
+
+	for name := range c.Features {
+		if !featureNamePattern.MatchString(name) {
+			add("features.%s: nome inválido (use letras minúsculas, números e _)", name)
+		}
+	}
+	if c.FeatureFlagsRefresh <= 0 {
+		add("FEATURE_FLAGS_REFRESH: deve ser maior que zero")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidationError lista todos os problemas encontrados nas configurações
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "configuração inválida:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// ParseDuration aceita os formatos de time.ParseDuration e também dias (ex.: 7d)
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("duração inválida %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("duração inválida %q", value)
+	}
+	return d, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Server:   ServerConfig{Port: "8080", Env: "development"},
+		Database: DatabaseConfig{Host: "localhost", Port: "5432", User: "erp_user", Name: "erp_db"},
+		Auth: AuthConfig{
+			JWTSecret: defaultJWTSecret, TokenExpiresIn: 15 * time.Minute, RefreshExpiresIn: 7 * 24 * time.Hour,
+			MaxFailedLogins: 5, LockoutDuration: 15 * time.Minute, InviteTTL: 72 * time.Hour, PasswordResetTTL: time.Hour,
+			QuotationShareTTL: 7 * 24 * time.Hour,
+		},
+		SMTP:     SMTPConfig{Port: 587},
+		Storage:  StorageConfig{Driver: "local", Dir: "uploads", MaxSizeMB: 20},
+		Jobs:     JobsConfig{DefaultCostingMethod: "average", PurchaseLeadTimeDays: 7},
+		Backup:   BackupConfig{Dir: "backups", RunnerInterval: 30 * time.Second, Retention: 7, JobTimeout: 6 * time.Hour},
+		Tenant:   TenantConfig{DefaultCompanyID: 1, DefaultTimezone: "America/Sao_Paulo"},
+		Cache:    CacheConfig{Size: 1000, TTL: 5 * time.Minute},
+		Features: map[string]bool{"tax_engine": true},
+
+		FeatureFlagsRefresh: 30 * time.Second,
+	}
+}
+
+func TestValidateAcceptsDefaults(t *testing.T) {
+	assert.NoError(t, validConfig().Validate())
+}
+
+func TestValidateListsAllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.Env = "production"
+	cfg.Server.Port = "porta"
+	cfg.Server.GRPCPort = "70000"
+	cfg.Database.ReplicaHost = "replica"
+	cfg.Database.ReplicaPort = "0"
+	cfg.SMTP.Host = "smtp.exemplo.com"
+	cfg.Storage.Driver = "s3"
+	cfg.Jobs.DefaultCostingMethod = "lifo"
+	cfg.Tenant.DefaultTimezone = "Brasil/Brasilia"
+	cfg.Cache.TTL = 0
+	cfg.Features["Tax-Engine"] = true
+
+	err := cfg.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []string{
+		`PORT: porta inválida "porta"`,
+		`GRPC_PORT: porta inválida "70000"`,
+		`DB_REPLICA_PORT: porta inválida "0"`,
+		"JWT_SECRET: o valor padrão não pode ser usado em produção",
+		"SMTP_FROM: obrigatório quando SMTP_HOST é definido",
+		"S3_BUCKET: obrigatório com ATTACHMENTS_STORAGE=s3",
+		`DEFAULT_COSTING_METHOD: método inválido "lifo" (aceitos: average, fifo)`,
+		`DEFAULT_TIMEZONE: fuso horário inválido "Brasil/Brasilia" (use um nome IANA, como America/Sao_Paulo)`,
+		"REFERENCE_CACHE_TTL: deve ser maior que zero com o cache ativo",
+		"features.Tax-Engine: nome inválido (use letras minúsculas, números e _)",
+	}, validationErr.Problems)
+	assert.Contains(t, err.Error(), "configuração inválida:\n  - ")
+}
+
+func TestValidateGRPCPortDiffersFromHTTPPort(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.GRPCPort = "9090"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Server.GRPCPort = cfg.Server.Port
+	assert.ErrorContains(t, cfg.Validate(), "GRPC_PORT: deve ser diferente de PORT")
+}
+
+func TestValidateBackupSchedule(t *testing.T) {
+	cfg := validConfig()
+	cfg.Backup.ScheduleInterval = 24 * time.Hour
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Backup.RunnerInterval = 0
+	cfg.Backup.JobTimeout = 0
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "BACKUP_SCHEDULE_INTERVAL: exige BACKUP_RUNNER_INTERVAL maior que zero")
+	assert.ErrorContains(t, err, "BACKUP_JOB_TIMEOUT: deve ser maior que zero")
+}
+
+func TestValidateVaultKey(t *testing.T) {
+	cfg := validConfig()
+	cfg.Auth.VaultKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Auth.VaultKey = "c2VncmVkbw=="
+	assert.ErrorContains(t, cfg.Validate(), "VAULT_KEY: deve ser o base64 de uma chave de 32 bytes")
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := ParseDuration("7d")
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	d, err = ParseDuration("15m")
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, d)
+
+	_, err = ParseDuration("sete dias")
+	assert.Error(t, err)
+}
+
+func TestBuildReadsFeaturesFromEnvAndFile(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.AutomaticEnv()
+	setDefaults()
+	viper.Set("features", map[string]interface{}{"tax_engine": "false", "api_docs": true})
+	t.Setenv("FEATURE_TAX_ENGINE", "true")
+
+	cfg, err := build()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"tax_engine": true, "api_docs": true}, cfg.Features)
+	assert.Equal(t, 7*24*time.Hour, cfg.Auth.RefreshExpiresIn)
+	assert.NoError(t, cfg.Validate())
+
+	t.Setenv("TOKEN_EXPIRES_IN", "quinze")
+	_, err = build()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{`TOKEN_EXPIRES_IN: duração inválida "quinze"`}, validationErr.Problems)
+}
@@ -6,6 +6,11 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db/querystats"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/tenant"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres" // Driver do PostgreSQL
@@ -34,8 +39,8 @@ func OpenDB() (*sql.DB, error) {
 		return nil, fmt.Errorf("variáveis de ambiente do banco de dados não definidas corretamente")
 	}
 
-	// Cria a string de conexão.
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+	// Cria a string de conexão; a sessão usa UTC, o fuso em que os horários são gravados.
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable timezone=UTC",
 		host, port, user, password, dbname)
 
 	// Abre a conexão com o banco.
@@ -70,20 +75,85 @@ func OpenGormDB() (*gorm.DB, error) {
 		return nil, fmt.Errorf("variáveis de ambiente do banco de dados não definidas corretamente")
 	}
 
-	// Cria a string de conexão.
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+	// Cria a string de conexão; a sessão usa UTC, o fuso em que os horários são gravados.
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC",
 		host, port, user, password, dbname)
 
-	// Abre a conexão com o banco usando Gorm.
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := openGorm(dsn)
 	if err != nil {
-		return nil, fmt.Errorf("[db.go]: erro ao conectar ao banco de dados com Gorm: %v", err)
+		return nil, err
 	}
 
 	log.Println("Conexão com o banco de dados via Gorm estabelecida com sucesso!")
 	return db, nil
 }
 
+// OpenReplicaGormDB abre a conexão com a réplica somente leitura definida em DB_REPLICA_HOST.
+// Usuário, senha e banco são os do primário; a porta padrão também. Retorna nil sem erro
+// quando não há réplica configurada.
+func OpenReplicaGormDB() (*gorm.DB, error) {
+	viper.AutomaticEnv()
+
+	host := viper.GetString("DB_REPLICA_HOST")
+	if host == "" {
+		return nil, nil
+	}
+	port := viper.GetString("DB_REPLICA_PORT")
+	if port == "" {
+		port = viper.GetString("DB_PORT")
+	}
+	user := viper.GetString("DB_USER")
+	password := viper.GetString("DB_PASSWORD")
+	dbname := viper.GetString("DB_NAME")
+
+	if port == "" || user == "" || password == "" || dbname == "" {
+		return nil, fmt.Errorf("variáveis de ambiente da réplica do banco de dados não definidas corretamente")
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC default_transaction_read_only=on",
+		host, port, user, password, dbname)
+
+	db, err := openGorm(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Conexão com a réplica de leitura via Gorm estabelecida com sucesso!")
+	return db, nil
+}
+
+// openGorm abre a conexão e registra os plugins usados por todos os repositórios GORM
+func openGorm(dsn string) (*gorm.DB, error) {
+	// Abre a conexão com o banco usando Gorm; created_at e updated_at são gravados em UTC.
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{NowFunc: NowUTC})
+	if err != nil {
+		return nil, fmt.Errorf("[db.go]: erro ao conectar ao banco de dados com Gorm: %v", err)
+	}
+
+	// Contabiliza as queries e o tempo gasto no banco por requisição (ver middleware.QueryStats)
+	if err := db.Use(querystats.Plugin{}); err != nil {
+		return nil, fmt.Errorf("[db.go]: erro ao registrar métricas de queries: %v", err)
+	}
+
+	// Duração das queries por método de repositório e estado do pool (GET /metrics)
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("[db.go]: erro ao registrar métricas do banco: %v", err)
+	}
+
+	// Filtra e preenche company_id pela empresa do contexto da query (ver tenant.Plugin)
+	if err := db.Use(tenant.Plugin{Strict: viper.GetBool("TENANT_STRICT")}); err != nil {
+		return nil, fmt.Errorf("[db.go]: erro ao registrar isolamento por empresa: %v", err)
+	}
+
+	return db, nil
+}
+
+// NowUTC é o horário atual em UTC, usado nos timestamps preenchidos pelo GORM. As colunas
+// TIMESTAMP não guardam fuso: gravar sempre em UTC evita depender do fuso do servidor.
+func NowUTC() time.Time {
+	return time.Now().UTC()
+}
+
 // RunMigrations executa as migrações do banco de dados usando variáveis de ambiente do Viper
 func RunMigrations() error {
 	// Garante que o Viper está lendo as variáveis de ambiente
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_payments_invoice_id;
+DROP INDEX IF EXISTS idx_invoices_contact_id;
+
+DROP TABLE IF EXISTS supplier_bills;
+DROP TABLE IF EXISTS credit_notes;
@@ -0,0 +1,43 @@
+-- Credit notes issued to customers (reduce the receivable balance)
+CREATE TABLE IF NOT EXISTS credit_notes (
+    id SERIAL PRIMARY KEY,
+    credit_note_no VARCHAR(50) NOT NULL UNIQUE,
+    invoice_id INTEGER REFERENCES invoices(id),
+    contact_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'issued',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    issue_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    reason TEXT,
+    CONSTRAINT valid_credit_note_status CHECK (status IN ('issued', 'applied', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_notes_contact_id ON credit_notes(contact_id);
+CREATE INDEX IF NOT EXISTS idx_credit_notes_invoice_id ON credit_notes(invoice_id);
+
+-- Supplier bills (contas a pagar)
+CREATE TABLE IF NOT EXISTS supplier_bills (
+    id SERIAL PRIMARY KEY,
+    bill_no VARCHAR(50) NOT NULL UNIQUE,
+    purchase_order_id INTEGER REFERENCES purchase_orders(id),
+    contact_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    issue_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    due_date TIMESTAMP NOT NULL,
+    subtotal DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    tax_total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    grand_total DECIMAL(12, 2) NOT NULL,
+    amount_paid DECIMAL(12, 2) DEFAULT 0,
+    notes TEXT,
+    CONSTRAINT valid_supplier_bill_status CHECK (status IN ('open', 'partial', 'paid', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_bills_contact_id ON supplier_bills(contact_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_bills_purchase_order_id ON supplier_bills(purchase_order_id);
+
+-- Indexes used by the contact statement and balance queries
+CREATE INDEX IF NOT EXISTS idx_invoices_contact_id ON invoices(contact_id);
+CREATE INDEX IF NOT EXISTS idx_payments_invoice_id ON payments(invoice_id);
@@ -0,0 +1,39 @@
+-- Imported bank statements (OFX/CSV)
+CREATE TABLE IF NOT EXISTS bank_statements (
+    id SERIAL PRIMARY KEY,
+    bank_account VARCHAR(100) NOT NULL,
+    file_name VARCHAR(255),
+    format VARCHAR(10) NOT NULL,
+    start_date TIMESTAMP,
+    end_date TIMESTAMP,
+    line_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_bank_statement_format CHECK (format IN ('ofx', 'csv'))
+);
+
+-- Bank statement lines and their reconciliation status
+CREATE TABLE IF NOT EXISTS bank_statement_lines (
+    id SERIAL PRIMARY KEY,
+    statement_id INTEGER NOT NULL REFERENCES bank_statements(id) ON DELETE CASCADE,
+    transaction_date TIMESTAMP NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL,
+    description TEXT,
+    reference VARCHAR(100),
+    external_id VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'unmatched',
+    suggested_type VARCHAR(20),
+    suggested_id INTEGER,
+    match_score INTEGER DEFAULT 0,
+    matched_type VARCHAR(20),
+    matched_id INTEGER,
+    reconciled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_bank_line_status CHECK (status IN ('unmatched', 'suggested', 'matched', 'ignored')),
+    CONSTRAINT valid_bank_line_suggested_type CHECK (suggested_type IS NULL OR suggested_type IN ('payment', 'supplier_bill')),
+    CONSTRAINT valid_bank_line_matched_type CHECK (matched_type IS NULL OR matched_type IN ('payment', 'supplier_bill'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_statement_id ON bank_statement_lines(statement_id);
+CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_status ON bank_statement_lines(status);
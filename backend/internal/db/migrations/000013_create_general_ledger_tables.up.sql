@@ -0,0 +1,74 @@
+-- Plano de contas
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(150) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    parent_id INTEGER REFERENCES ledger_accounts(id),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_ledger_account_type CHECK (type IN ('asset', 'liability', 'equity', 'revenue', 'expense'))
+);
+
+-- Mapeamento das contas usadas na contabilização automática de documentos
+CREATE TABLE IF NOT EXISTS ledger_account_mappings (
+    key VARCHAR(50) PRIMARY KEY,
+    account_id INTEGER NOT NULL REFERENCES ledger_accounts(id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Lançamentos contábeis (partidas dobradas)
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id SERIAL PRIMARY KEY,
+    entry_date TIMESTAMP NOT NULL,
+    description TEXT,
+    source_type VARCHAR(30),
+    source_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_journal_entries_source UNIQUE (source_type, source_id)
+);
+
+CREATE TABLE IF NOT EXISTS journal_lines (
+    id SERIAL PRIMARY KEY,
+    entry_id INTEGER NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    account_id INTEGER NOT NULL REFERENCES ledger_accounts(id),
+    debit DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    credit DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    description TEXT,
+    CONSTRAINT journal_line_one_side CHECK (debit >= 0 AND credit >= 0 AND (debit = 0 OR credit = 0))
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_entry_date ON journal_entries(entry_date);
+CREATE INDEX IF NOT EXISTS idx_journal_lines_entry_id ON journal_lines(entry_id);
+CREATE INDEX IF NOT EXISTS idx_journal_lines_account_id ON journal_lines(account_id);
+
+-- Plano de contas padrão
+INSERT INTO ledger_accounts (code, name, type) VALUES
+    ('1.1.01', 'Caixa e Bancos', 'asset'),
+    ('1.1.02', 'Clientes a Receber', 'asset'),
+    ('1.1.03', 'Impostos a Recuperar', 'asset'),
+    ('2.1.01', 'Fornecedores a Pagar', 'liability'),
+    ('2.1.02', 'Impostos a Recolher', 'liability'),
+    ('3.1.01', 'Capital Social', 'equity'),
+    ('4.1.01', 'Receita de Vendas', 'revenue'),
+    ('4.2.01', 'Descontos Concedidos', 'revenue'),
+    ('4.2.02', 'Devoluções e Abatimentos', 'revenue'),
+    ('5.1.01', 'Compras e Custos', 'expense')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO ledger_account_mappings (key, account_id)
+SELECT m.key, a.id
+FROM (VALUES
+    ('cash', '1.1.01'),
+    ('receivables', '1.1.02'),
+    ('tax_recoverable', '1.1.03'),
+    ('payables', '2.1.01'),
+    ('tax_payable', '2.1.02'),
+    ('sales_revenue', '4.1.01'),
+    ('sales_discounts', '4.2.01'),
+    ('sales_returns', '4.2.02'),
+    ('purchases', '5.1.01')
+) AS m(key, code)
+JOIN ledger_accounts a ON a.code = m.code
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS cogs_entries;
+DROP TABLE IF EXISTS cost_layers;
+DROP TABLE IF EXISTS product_costing;
@@ -0,0 +1,44 @@
+-- Configuração e saldo de custo por produto
+CREATE TABLE IF NOT EXISTS product_costing (
+    product_id INTEGER PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL DEFAULT 'average',
+    average_cost DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    quantity_on_hand INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_costing_method CHECK (method IN ('fifo', 'average'))
+);
+
+-- Camadas de custo geradas pelo recebimento de pedidos de compra
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    purchase_order_id INTEGER REFERENCES purchase_orders(id),
+    po_item_id INTEGER UNIQUE REFERENCES purchase_order_items(id),
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    remaining_quantity INTEGER NOT NULL CHECK (remaining_quantity >= 0),
+    unit_cost DECIMAL(14, 4) NOT NULL CHECK (unit_cost >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_layers_product_remaining ON cost_layers(product_id, received_at) WHERE remaining_quantity > 0;
+
+-- Custo das mercadorias vendidas apurado por item entregue ou faturado
+CREATE TABLE IF NOT EXISTS cogs_entries (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    sales_order_id INTEGER REFERENCES sales_orders(id),
+    source_type VARCHAR(20) NOT NULL,
+    source_id INTEGER NOT NULL,
+    source_item_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(14, 4) NOT NULL,
+    total_cost DECIMAL(14, 2) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_cogs_source CHECK (source_type IN ('delivery', 'invoice')),
+    CONSTRAINT uq_cogs_source_item UNIQUE (source_type, source_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cogs_entries_sales_order_id ON cogs_entries(sales_order_id);
+CREATE INDEX IF NOT EXISTS idx_cogs_entries_product_id ON cogs_entries(product_id);
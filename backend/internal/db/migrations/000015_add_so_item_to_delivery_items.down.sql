@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_deliveries_sales_order_id;
+DROP INDEX IF EXISTS idx_delivery_items_so_item_id;
+ALTER TABLE delivery_items DROP COLUMN IF EXISTS so_item_id;
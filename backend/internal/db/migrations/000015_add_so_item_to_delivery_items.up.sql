@@ -0,0 +1,5 @@
+-- Vincula cada item entregue à linha do pedido de venda (entregas parciais)
+ALTER TABLE delivery_items ADD COLUMN IF NOT EXISTS so_item_id INTEGER REFERENCES sales_order_items(id);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_items_so_item_id ON delivery_items(so_item_id);
+CREATE INDEX IF NOT EXISTS idx_deliveries_sales_order_id ON deliveries(sales_order_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_deliveries_tracking_number;
+DROP INDEX IF EXISTS idx_delivery_tracking_events_delivery;
+DROP TABLE IF EXISTS delivery_tracking_events;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS carrier;
@@ -0,0 +1,22 @@
+-- Transportadora responsável pela entrega (correios, jadlog, webhook)
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS carrier VARCHAR(30);
+
+-- Histórico de eventos de rastreamento recebidos das transportadoras
+CREATE TABLE IF NOT EXISTS delivery_tracking_events (
+    id SERIAL PRIMARY KEY,
+    delivery_id INTEGER NOT NULL REFERENCES deliveries(id) ON DELETE CASCADE,
+    carrier VARCHAR(30) NOT NULL,
+    external_id VARCHAR(150) NOT NULL,
+    event_code VARCHAR(30),
+    status VARCHAR(30) NOT NULL,
+    description TEXT,
+    location VARCHAR(255),
+    occurred_at TIMESTAMP NOT NULL,
+    raw_payload TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_delivery_tracking_event UNIQUE (delivery_id, carrier, external_id),
+    CONSTRAINT valid_tracking_event_status CHECK (status IN ('posted', 'in_transit', 'out_for_delivery', 'delivered', 'exception', 'returned'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_tracking_events_delivery ON delivery_tracking_events(delivery_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_deliveries_tracking_number ON deliveries(tracking_number);
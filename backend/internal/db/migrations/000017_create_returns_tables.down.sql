@@ -0,0 +1,3 @@
+ALTER TABLE cost_layers DROP COLUMN IF EXISTS return_item_id;
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS return_requests;
@@ -0,0 +1,57 @@
+-- Solicitações de devolução (RMA) vinculadas a entregas e faturas
+CREATE TABLE IF NOT EXISTS return_requests (
+    id SERIAL PRIMARY KEY,
+    return_no VARCHAR(50) NOT NULL UNIQUE,
+    contact_id INTEGER NOT NULL REFERENCES contacts(id),
+    sales_order_id INTEGER REFERENCES sales_orders(id),
+    delivery_id INTEGER REFERENCES deliveries(id),
+    invoice_id INTEGER REFERENCES invoices(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    reason TEXT,
+    notes TEXT,
+    rejection_reason TEXT,
+    total_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    credit_note_id INTEGER REFERENCES credit_notes(id),
+    requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approved_at TIMESTAMP,
+    received_at TIMESTAMP,
+    credited_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_return_status CHECK (status IN ('requested', 'approved', 'rejected', 'received', 'credited', 'cancelled')),
+    CONSTRAINT return_source_required CHECK (delivery_id IS NOT NULL OR invoice_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_return_requests_contact_id ON return_requests(contact_id);
+CREATE INDEX IF NOT EXISTS idx_return_requests_status ON return_requests(status);
+CREATE INDEX IF NOT EXISTS idx_return_requests_delivery_id ON return_requests(delivery_id);
+CREATE INDEX IF NOT EXISTS idx_return_requests_invoice_id ON return_requests(invoice_id);
+
+-- Itens devolvidos com quantidade, motivo e condição no recebimento
+CREATE TABLE IF NOT EXISTS return_items (
+    id SERIAL PRIMARY KEY,
+    return_id INTEGER NOT NULL REFERENCES return_requests(id) ON DELETE CASCADE,
+    delivery_item_id INTEGER REFERENCES delivery_items(id),
+    invoice_item_id INTEGER REFERENCES invoice_items(id),
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    product_name VARCHAR(255),
+    product_code VARCHAR(100),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    received_qty INTEGER NOT NULL DEFAULT 0 CHECK (received_qty >= 0),
+    unit_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    total DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    reason_code VARCHAR(30) NOT NULL,
+    condition VARCHAR(20),
+    restocked BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    CONSTRAINT valid_return_reason CHECK (reason_code IN ('damaged', 'defective', 'wrong_item', 'not_as_described', 'no_longer_needed', 'other')),
+    CONSTRAINT valid_return_condition CHECK (condition IS NULL OR condition IN ('resellable', 'damaged'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_return_items_return_id ON return_items(return_id);
+CREATE INDEX IF NOT EXISTS idx_return_items_delivery_item_id ON return_items(delivery_item_id);
+CREATE INDEX IF NOT EXISTS idx_return_items_invoice_item_id ON return_items(invoice_item_id);
+CREATE INDEX IF NOT EXISTS idx_return_items_product_id ON return_items(product_id);
+
+-- Camadas de custo geradas pela reentrada de itens devolvidos
+ALTER TABLE cost_layers ADD COLUMN IF NOT EXISTS return_item_id INTEGER UNIQUE REFERENCES return_items(id);
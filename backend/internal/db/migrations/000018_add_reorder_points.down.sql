@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_purchase_orders_auto_generated;
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS auto_generated;
+
+DROP INDEX IF EXISTS idx_products_preferred_supplier_id;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_reorder_point_check;
+ALTER TABLE products DROP COLUMN IF EXISTS preferred_supplier_id;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_quantity;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_point;
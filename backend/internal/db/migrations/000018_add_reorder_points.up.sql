@@ -0,0 +1,12 @@
+-- Ponto de reposição e fornecedor preferencial dos produtos
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_point INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_quantity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS preferred_supplier_id INTEGER REFERENCES contacts(id);
+ALTER TABLE products ADD CONSTRAINT products_reorder_point_check CHECK (reorder_point >= 0 AND reorder_quantity >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_products_preferred_supplier_id ON products(preferred_supplier_id);
+
+-- Pedidos de compra sugeridos automaticamente a partir do ponto de reposição
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS auto_generated BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_auto_generated ON purchase_orders(auto_generated) WHERE auto_generated;
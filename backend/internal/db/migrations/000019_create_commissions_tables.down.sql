@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS commission_statement_lines;
+DROP TABLE IF EXISTS commission_statements;
+DROP TABLE IF EXISTS commission_periods;
+DROP TABLE IF EXISTS sales_quotas;
+DROP TABLE IF EXISTS commission_rules;
+
+DROP INDEX IF EXISTS idx_invoices_salesperson_id;
+DROP INDEX IF EXISTS idx_sales_orders_salesperson_id;
+DROP INDEX IF EXISTS idx_quotations_salesperson_id;
+
+ALTER TABLE invoices DROP COLUMN IF EXISTS salesperson_id;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS salesperson_id;
+ALTER TABLE quotations DROP COLUMN IF EXISTS salesperson_id;
@@ -0,0 +1,81 @@
+-- Vendedor responsável pelos documentos de venda
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS salesperson_id INTEGER REFERENCES users(id);
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS salesperson_id INTEGER REFERENCES users(id);
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS salesperson_id INTEGER REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_quotations_salesperson_id ON quotations(salesperson_id);
+CREATE INDEX IF NOT EXISTS idx_sales_orders_salesperson_id ON sales_orders(salesperson_id);
+CREATE INDEX IF NOT EXISTS idx_invoices_salesperson_id ON invoices(salesperson_id);
+
+-- Regras de comissão: percentual por categoria de produto, escalonado pelo atingimento da meta
+CREATE TABLE IF NOT EXISTS commission_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    product_category VARCHAR(100),
+    min_attainment DECIMAL(6, 2) NOT NULL DEFAULT 0,
+    rate DECIMAL(5, 2) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_commission_rate CHECK (rate >= 0 AND rate <= 100),
+    CONSTRAINT valid_commission_min_attainment CHECK (min_attainment >= 0)
+);
+
+-- Metas mensais de vendas por vendedor (período no formato AAAA-MM)
+CREATE TABLE IF NOT EXISTS sales_quotas (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period CHAR(7) NOT NULL,
+    target_amount DECIMAL(12, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT sales_quotas_user_period_unique UNIQUE (user_id, period),
+    CONSTRAINT valid_sales_quota_target CHECK (target_amount >= 0)
+);
+
+-- Períodos de comissão: calculados enquanto abertos, aprovados e fechados pela gerência
+CREATE TABLE IF NOT EXISTS commission_periods (
+    id SERIAL PRIMARY KEY,
+    period CHAR(7) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    calculated_at TIMESTAMP,
+    approved_by VARCHAR(50),
+    approved_at TIMESTAMP,
+    closed_by VARCHAR(50),
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_commission_period_status CHECK (status IN ('open', 'approved', 'closed'))
+);
+
+-- Extrato mensal de comissão por vendedor
+CREATE TABLE IF NOT EXISTS commission_statements (
+    id SERIAL PRIMARY KEY,
+    period_id INTEGER NOT NULL REFERENCES commission_periods(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    sales_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    quota_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    attainment DECIMAL(8, 2) NOT NULL DEFAULT 0,
+    commission_amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT commission_statements_period_user_unique UNIQUE (period_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_commission_statements_user_id ON commission_statements(user_id);
+
+-- Linhas do extrato: comissão de cada item faturado
+CREATE TABLE IF NOT EXISTS commission_statement_lines (
+    id SERIAL PRIMARY KEY,
+    statement_id INTEGER NOT NULL REFERENCES commission_statements(id) ON DELETE CASCADE,
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+    invoice_item_id INTEGER NOT NULL REFERENCES invoice_items(id),
+    product_id INTEGER NOT NULL,
+    product_category VARCHAR(100),
+    rule_id INTEGER REFERENCES commission_rules(id) ON DELETE SET NULL,
+    amount DECIMAL(12, 2) NOT NULL,
+    rate DECIMAL(5, 2) NOT NULL,
+    commission DECIMAL(12, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_commission_statement_lines_statement_id ON commission_statement_lines(statement_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS crm_opportunity_items;
+DROP TABLE IF EXISTS crm_opportunities;
+DROP TABLE IF EXISTS crm_leads;
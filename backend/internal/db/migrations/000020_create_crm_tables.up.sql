@@ -0,0 +1,57 @@
+-- Leads: potenciais clientes ainda não cadastrados como contato
+CREATE TABLE IF NOT EXISTS crm_leads (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    company_name VARCHAR(150),
+    email VARCHAR(100) NOT NULL,
+    phone VARCHAR(20),
+    document VARCHAR(20),
+    source VARCHAR(50),
+    status VARCHAR(20) NOT NULL DEFAULT 'new',
+    owner_id INTEGER REFERENCES users(id),
+    contact_id INTEGER REFERENCES contacts(id),
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_crm_lead_status CHECK (status IN ('new', 'contacted', 'qualified', 'disqualified', 'converted'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_crm_leads_status ON crm_leads(status);
+CREATE INDEX IF NOT EXISTS idx_crm_leads_owner_id ON crm_leads(owner_id);
+
+-- Oportunidades: negociações em andamento no funil de vendas
+CREATE TABLE IF NOT EXISTS crm_opportunities (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(150) NOT NULL,
+    lead_id INTEGER REFERENCES crm_leads(id),
+    contact_id INTEGER REFERENCES contacts(id),
+    owner_id INTEGER REFERENCES users(id),
+    stage VARCHAR(20) NOT NULL DEFAULT 'prospecting',
+    amount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    probability INTEGER NOT NULL DEFAULT 10,
+    expected_close_date DATE,
+    quotation_id INTEGER REFERENCES quotations(id),
+    lost_reason TEXT,
+    closed_at TIMESTAMP,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_crm_opportunity_stage CHECK (stage IN ('prospecting', 'qualification', 'proposal', 'negotiation', 'won', 'lost')),
+    CONSTRAINT valid_crm_opportunity_probability CHECK (probability BETWEEN 0 AND 100),
+    CONSTRAINT crm_opportunity_party CHECK (lead_id IS NOT NULL OR contact_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_crm_opportunities_stage ON crm_opportunities(stage);
+CREATE INDEX IF NOT EXISTS idx_crm_opportunities_expected_close_date ON crm_opportunities(expected_close_date);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_crm_opportunities_quotation_id ON crm_opportunities(quotation_id);
+
+-- Itens previstos da oportunidade, copiados para a cotação na conversão
+CREATE TABLE IF NOT EXISTS crm_opportunity_items (
+    id SERIAL PRIMARY KEY,
+    opportunity_id INTEGER NOT NULL REFERENCES crm_opportunities(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL,
+    unit_price DECIMAL(12, 2) NOT NULL,
+    discount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    CONSTRAINT valid_crm_opportunity_item_quantity CHECK (quantity > 0)
+);
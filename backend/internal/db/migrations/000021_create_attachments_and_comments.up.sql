@@ -0,0 +1,32 @@
+-- Anexos de qualquer documento do ERP (entidade polimórfica entity_type/entity_id)
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100),
+    size BIGINT NOT NULL DEFAULT 0,
+    storage_backend VARCHAR(10) NOT NULL,
+    storage_key VARCHAR(500) NOT NULL UNIQUE,
+    description TEXT,
+    uploaded_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_attachment_storage_backend CHECK (storage_backend IN ('local', 's3'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_entity ON attachments(entity_type, entity_id);
+
+-- Comentários internos em threads; respostas apontam para o comentário de origem
+CREATE TABLE IF NOT EXISTS comments (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+    author VARCHAR(50) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_entity ON comments(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments(parent_id);
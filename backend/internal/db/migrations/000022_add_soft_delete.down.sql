@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_deliveries_deleted_at;
+DROP INDEX IF EXISTS idx_invoices_deleted_at;
+DROP INDEX IF EXISTS idx_sales_orders_deleted_at;
+DROP INDEX IF EXISTS idx_quotations_deleted_at;
+DROP INDEX IF EXISTS idx_contacts_deleted_at;
+
+ALTER TABLE deliveries DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE invoices DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE quotations DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,13 @@
+-- Soft delete: registros removidos vão para a lixeira (deleted_at preenchido) e podem ser restaurados.
+-- A tabela products já possui deleted_at (gorm.Model).
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_deleted_at ON contacts(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_quotations_deleted_at ON quotations(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_sales_orders_deleted_at ON sales_orders(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_invoices_deleted_at ON invoices(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_deliveries_deleted_at ON deliveries(deleted_at);
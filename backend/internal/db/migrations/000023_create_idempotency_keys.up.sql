@@ -0,0 +1,19 @@
+-- Respostas de requisições de escrita enviadas com o header Idempotency-Key;
+-- reenvios com a mesma chave devolvem a resposta gravada em vez de duplicar documentos
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'processing',
+    status_code INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(100),
+    response_body BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    CONSTRAINT uq_idempotency_keys_request UNIQUE (idempotency_key, method, path),
+    CONSTRAINT valid_idempotency_key_status CHECK (status IN ('processing', 'completed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
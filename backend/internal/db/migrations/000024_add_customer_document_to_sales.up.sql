@@ -0,0 +1,2 @@
+-- CPF/CNPJ do cliente da venda simples (apenas dígitos, opcional)
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS customer_document VARCHAR(14);
@@ -0,0 +1,11 @@
+-- Feature flags alteráveis em tempo de execução; o valor gravado aqui prevalece sobre o padrão
+-- da configuração (seção features do YAML ou FEATURE_<NOME>)
+CREATE TABLE IF NOT EXISTS feature_flags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT,
+    updated_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
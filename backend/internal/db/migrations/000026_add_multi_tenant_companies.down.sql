@@ -0,0 +1,38 @@
+ALTER TABLE commission_periods DROP CONSTRAINT IF EXISTS uq_commission_periods_company_period;
+ALTER TABLE commission_periods ADD CONSTRAINT commission_periods_period_key UNIQUE (period);
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS uq_ledger_accounts_company_code;
+ALTER TABLE ledger_accounts ADD CONSTRAINT ledger_accounts_code_key UNIQUE (code);
+ALTER TABLE return_requests DROP CONSTRAINT IF EXISTS uq_return_requests_company_no;
+ALTER TABLE return_requests ADD CONSTRAINT return_requests_return_no_key UNIQUE (return_no);
+ALTER TABLE supplier_bills DROP CONSTRAINT IF EXISTS uq_supplier_bills_company_no;
+ALTER TABLE supplier_bills ADD CONSTRAINT supplier_bills_bill_no_key UNIQUE (bill_no);
+ALTER TABLE credit_notes DROP CONSTRAINT IF EXISTS uq_credit_notes_company_no;
+ALTER TABLE credit_notes ADD CONSTRAINT credit_notes_credit_note_no_key UNIQUE (credit_note_no);
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS uq_invoices_company_no;
+ALTER TABLE invoices ADD CONSTRAINT invoices_invoice_no_key UNIQUE (invoice_no);
+ALTER TABLE deliveries DROP CONSTRAINT IF EXISTS uq_deliveries_company_no;
+ALTER TABLE deliveries ADD CONSTRAINT deliveries_delivery_no_key UNIQUE (delivery_no);
+ALTER TABLE purchase_orders DROP CONSTRAINT IF EXISTS uq_purchase_orders_company_no;
+ALTER TABLE purchase_orders ADD CONSTRAINT purchase_orders_po_no_key UNIQUE (po_no);
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS uq_sales_orders_company_no;
+ALTER TABLE sales_orders ADD CONSTRAINT sales_orders_so_no_key UNIQUE (so_no);
+ALTER TABLE quotations DROP CONSTRAINT IF EXISTS uq_quotations_company_no;
+ALTER TABLE quotations ADD CONSTRAINT quotations_quotation_no_key UNIQUE (quotation_no);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'acc_transaction', 'sales', 'contacts', 'products', 'rentals', 'campaigns',
+        'warranties', 'dropshipping', 'sales_processes', 'quotations', 'sales_orders',
+        'purchase_orders', 'deliveries', 'invoices', 'payments', 'credit_notes', 'supplier_bills',
+        'bank_statements', 'ledger_accounts', 'journal_entries', 'return_requests',
+        'commission_rules', 'commission_periods', 'commission_statements', 'sales_quotas',
+        'crm_leads', 'crm_opportunities', 'attachments', 'comments'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS company_id', t);
+    END LOOP;
+END $$;
+
+DROP TABLE IF EXISTS companies;
@@ -0,0 +1,55 @@
+-- Empresas (entidades legais) atendidas pela mesma instalação; os dados de negócio passam a
+-- pertencer a uma empresa (company_id) e as consultas são filtradas pela empresa da requisição
+CREATE TABLE IF NOT EXISTS companies (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    legal_name VARCHAR(200),
+    document VARCHAR(20) UNIQUE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Empresa padrão, dona dos dados já existentes
+INSERT INTO companies (id, name) VALUES (1, 'Empresa padrão') ON CONFLICT (id) DO NOTHING;
+SELECT setval('companies_id_seq', GREATEST((SELECT MAX(id) FROM companies), 1));
+
+-- Tabelas principais; itens, linhas e eventos seguem a empresa do documento pai
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'users', 'acc_transaction', 'sales', 'contacts', 'products', 'rentals', 'campaigns',
+        'warranties', 'dropshipping', 'sales_processes', 'quotations', 'sales_orders',
+        'purchase_orders', 'deliveries', 'invoices', 'payments', 'credit_notes', 'supplier_bills',
+        'bank_statements', 'ledger_accounts', 'journal_entries', 'return_requests',
+        'commission_rules', 'commission_periods', 'commission_statements', 'sales_quotas',
+        'crm_leads', 'crm_opportunities', 'attachments', 'comments'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id)', t);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I(company_id)', 'idx_' || t || '_company_id', t);
+    END LOOP;
+END $$;
+
+-- Numeração dos documentos e códigos passam a ser únicos por empresa
+ALTER TABLE quotations DROP CONSTRAINT IF EXISTS quotations_quotation_no_key;
+ALTER TABLE quotations ADD CONSTRAINT uq_quotations_company_no UNIQUE (company_id, quotation_no);
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS sales_orders_so_no_key;
+ALTER TABLE sales_orders ADD CONSTRAINT uq_sales_orders_company_no UNIQUE (company_id, so_no);
+ALTER TABLE purchase_orders DROP CONSTRAINT IF EXISTS purchase_orders_po_no_key;
+ALTER TABLE purchase_orders ADD CONSTRAINT uq_purchase_orders_company_no UNIQUE (company_id, po_no);
+ALTER TABLE deliveries DROP CONSTRAINT IF EXISTS deliveries_delivery_no_key;
+ALTER TABLE deliveries ADD CONSTRAINT uq_deliveries_company_no UNIQUE (company_id, delivery_no);
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_invoice_no_key;
+ALTER TABLE invoices ADD CONSTRAINT uq_invoices_company_no UNIQUE (company_id, invoice_no);
+ALTER TABLE credit_notes DROP CONSTRAINT IF EXISTS credit_notes_credit_note_no_key;
+ALTER TABLE credit_notes ADD CONSTRAINT uq_credit_notes_company_no UNIQUE (company_id, credit_note_no);
+ALTER TABLE supplier_bills DROP CONSTRAINT IF EXISTS supplier_bills_bill_no_key;
+ALTER TABLE supplier_bills ADD CONSTRAINT uq_supplier_bills_company_no UNIQUE (company_id, bill_no);
+ALTER TABLE return_requests DROP CONSTRAINT IF EXISTS return_requests_return_no_key;
+ALTER TABLE return_requests ADD CONSTRAINT uq_return_requests_company_no UNIQUE (company_id, return_no);
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS ledger_accounts_code_key;
+ALTER TABLE ledger_accounts ADD CONSTRAINT uq_ledger_accounts_company_code UNIQUE (company_id, code);
+ALTER TABLE commission_periods DROP CONSTRAINT IF EXISTS commission_periods_period_key;
+ALTER TABLE commission_periods ADD CONSTRAINT uq_commission_periods_company_period UNIQUE (company_id, period);
@@ -0,0 +1,30 @@
+-- Chaves de API das integrações (e-commerce, BI): autenticam sistemas externos separadamente
+-- das sessões JWT dos usuários. Apenas o hash SHA-256 da chave é gravado; o prefixo identifica
+-- a chave nas listagens e nos logs
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by VARCHAR(50),
+    created_by VARCHAR(50),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    usage_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_company_id ON api_keys(company_id);
+
+-- Requisições por chave e por dia
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    api_key_id INTEGER NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ecommerce_sync_runs;
+DROP TABLE IF EXISTS ecommerce_orders;
+DROP TABLE IF EXISTS ecommerce_channels;
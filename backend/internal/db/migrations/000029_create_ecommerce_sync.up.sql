@@ -0,0 +1,48 @@
+-- Canais de e-commerce (lojas Shopify, VTEX, WooCommerce) sincronizados com o ERP. As
+-- credenciais são as da API da loja e só são usadas nas chamadas feitas pelo servidor
+CREATE TABLE IF NOT EXISTS ecommerce_channels (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    platform VARCHAR(20) NOT NULL CHECK (platform IN ('shopify', 'vtex', 'woocommerce')),
+    base_url VARCHAR(255) NOT NULL,
+    api_key TEXT,
+    api_secret TEXT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_order_sync_at TIMESTAMP WITH TIME ZONE,
+    last_stock_sync_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_ecommerce_channels_company_id ON ecommerce_channels(company_id);
+
+-- Pedidos da loja já importados: evita duplicar pedidos de venda e guarda o rastreamento enviado
+CREATE TABLE IF NOT EXISTS ecommerce_orders (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    channel_id INTEGER NOT NULL REFERENCES ecommerce_channels(id) ON DELETE CASCADE,
+    external_id VARCHAR(100) NOT NULL,
+    external_number VARCHAR(100),
+    sales_order_id INTEGER NOT NULL REFERENCES sales_orders(id),
+    tracking_number VARCHAR(100),
+    tracking_pushed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_ecommerce_orders_channel_external UNIQUE (channel_id, external_id)
+);
+CREATE INDEX IF NOT EXISTS idx_ecommerce_orders_sales_order_id ON ecommerce_orders(sales_order_id);
+
+-- Histórico das sincronizações por canal e tipo (pedidos, estoque, preços, rastreamento)
+CREATE TABLE IF NOT EXISTS ecommerce_sync_runs (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    channel_id INTEGER NOT NULL REFERENCES ecommerce_channels(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    processed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_ecommerce_sync_runs_channel ON ecommerce_sync_runs(channel_id, started_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS etl_runs;
+DROP TABLE IF EXISTS etl_mappings;
@@ -0,0 +1,35 @@
+-- Mapeamentos de importação: cada um descreve como as colunas de um arquivo CSV ou JSON de
+-- terceiros viram os campos de contatos, produtos ou pedidos de venda do ERP
+CREATE TABLE IF NOT EXISTS etl_mappings (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    target VARCHAR(20) NOT NULL CHECK (target IN ('contacts', 'products', 'sales_orders')),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'json')),
+    config JSONB NOT NULL,
+    created_by VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_etl_mappings_company_name UNIQUE (company_id, name)
+);
+
+-- Execuções de um mapeamento sobre um arquivo enviado; as simulações (dry_run) também são
+-- registradas, com os erros por linha
+CREATE TABLE IF NOT EXISTS etl_runs (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    mapping_id INTEGER NOT NULL REFERENCES etl_mappings(id) ON DELETE CASCADE,
+    file_name VARCHAR(255),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]',
+    created_by VARCHAR(50),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_etl_runs_mapping ON etl_runs(mapping_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_etl_runs_company_id ON etl_runs(company_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_contacts_rfm_segment;
+ALTER TABLE contacts DROP COLUMN IF EXISTS rfm_segment;
+
+DROP TABLE IF EXISTS contact_rfm_scores;
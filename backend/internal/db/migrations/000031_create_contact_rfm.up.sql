@@ -0,0 +1,21 @@
+-- Pontuação RFM (recência, frequência e valor) dos clientes, recalculada periodicamente a partir
+-- das faturas emitidas; cada nota vai de 1 a 5 pelo quintil do cliente entre os da empresa
+CREATE TABLE IF NOT EXISTS contact_rfm_scores (
+    contact_id INTEGER PRIMARY KEY REFERENCES contacts(id) ON DELETE CASCADE,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    last_invoice_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recency_days INTEGER NOT NULL,
+    frequency INTEGER NOT NULL,
+    monetary NUMERIC(15,2) NOT NULL,
+    recency_score SMALLINT NOT NULL CHECK (recency_score BETWEEN 1 AND 5),
+    frequency_score SMALLINT NOT NULL CHECK (frequency_score BETWEEN 1 AND 5),
+    monetary_score SMALLINT NOT NULL CHECK (monetary_score BETWEEN 1 AND 5),
+    segment VARCHAR(20) NOT NULL,
+    scored_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_contact_rfm_scores_company_segment ON contact_rfm_scores(company_id, segment);
+
+-- Segmento RFM gravado no próprio contato para filtrar os públicos das campanhas
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS rfm_segment VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_contacts_rfm_segment ON contacts(company_id, rfm_segment) WHERE rfm_segment <> '';
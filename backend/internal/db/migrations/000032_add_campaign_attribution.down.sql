@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS process_campaigns;
+
+DROP INDEX IF EXISTS idx_quotations_campaign_id;
+ALTER TABLE quotations DROP COLUMN IF EXISTS campaign_id;
@@ -0,0 +1,14 @@
+-- Atribuição de vendas às campanhas de marketing: a cotação guarda a campanha que a originou
+-- e os processos de venda são vinculados às campanhas pela tabela de vínculo
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS campaign_id INTEGER REFERENCES campaigns(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_quotations_campaign_id ON quotations(campaign_id) WHERE campaign_id IS NOT NULL;
+
+-- Vínculo entre processos de venda e campanhas (cada campanha vinculada recebe o crédito integral)
+CREATE TABLE IF NOT EXISTS process_campaigns (
+    process_id INTEGER NOT NULL REFERENCES sales_processes(id) ON DELETE CASCADE,
+    campaign_id INTEGER NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    linked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (process_id, campaign_id)
+);
+CREATE INDEX IF NOT EXISTS idx_process_campaigns_campaign_id ON process_campaigns(campaign_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS service_order_labor;
+DROP TABLE IF EXISTS service_order_parts;
+DROP TABLE IF EXISTS service_orders;
@@ -0,0 +1,60 @@
+-- Ordens de serviço (assistência técnica pós-venda): cada ordem atende um item faturado,
+-- identificado pelo número de série, e registra o agendamento do técnico, as peças e as horas
+CREATE TABLE IF NOT EXISTS service_orders (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    order_no VARCHAR(50) NOT NULL,
+    contact_id INTEGER NOT NULL REFERENCES contacts(id),
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+    invoice_item_id INTEGER NOT NULL REFERENCES invoice_items(id),
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    serial_number VARCHAR(100) NOT NULL,
+    issue TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'scheduled', 'in_progress', 'completed', 'billed', 'cancelled')),
+    under_warranty BOOLEAN NOT NULL DEFAULT FALSE,
+    warranty_until DATE,
+    technician_id INTEGER REFERENCES users(id),
+    scheduled_start TIMESTAMP WITH TIME ZONE,
+    scheduled_end TIMESTAMP WITH TIME ZONE,
+    labor_rate NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    resolution TEXT,
+    billed_invoice_id INTEGER REFERENCES invoices(id),
+    opened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_service_orders_company_no UNIQUE (company_id, order_no),
+    CONSTRAINT chk_service_orders_schedule CHECK (scheduled_end IS NULL OR scheduled_end > scheduled_start)
+);
+CREATE INDEX IF NOT EXISTS idx_service_orders_company_id ON service_orders(company_id);
+CREATE INDEX IF NOT EXISTS idx_service_orders_serial_number ON service_orders(serial_number);
+CREATE INDEX IF NOT EXISTS idx_service_orders_invoice_item_id ON service_orders(invoice_item_id);
+CREATE INDEX IF NOT EXISTS idx_service_orders_technician_schedule ON service_orders(technician_id, scheduled_start)
+    WHERE technician_id IS NOT NULL;
+
+-- Peças consumidas no atendimento; cada linha baixa o estoque do produto
+CREATE TABLE IF NOT EXISTS service_order_parts (
+    id SERIAL PRIMARY KEY,
+    service_order_id INTEGER NOT NULL REFERENCES service_orders(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    product_name VARCHAR(255) NOT NULL,
+    product_code VARCHAR(50),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    total NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    consumed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_service_order_parts_order ON service_order_parts(service_order_id);
+
+-- Horas de mão de obra apontadas pelos técnicos
+CREATE TABLE IF NOT EXISTS service_order_labor (
+    id SERIAL PRIMARY KEY,
+    service_order_id INTEGER NOT NULL REFERENCES service_orders(id) ON DELETE CASCADE,
+    technician_id INTEGER REFERENCES users(id),
+    work_date DATE NOT NULL,
+    hours NUMERIC(6, 2) NOT NULL CHECK (hours > 0),
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_service_order_labor_order ON service_order_labor(service_order_id);
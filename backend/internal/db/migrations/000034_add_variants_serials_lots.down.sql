@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS serial_numbers;
+
+DROP INDEX IF EXISTS idx_delivery_items_lot_number;
+ALTER TABLE delivery_items DROP COLUMN IF EXISTS lot_number;
+
+DROP INDEX IF EXISTS idx_cost_layers_expiry_date;
+DROP INDEX IF EXISTS idx_cost_layers_lot_number;
+ALTER TABLE cost_layers DROP COLUMN IF EXISTS expiry_date;
+ALTER TABLE cost_layers DROP COLUMN IF EXISTS lot_number;
+
+DROP TABLE IF EXISTS product_variants;
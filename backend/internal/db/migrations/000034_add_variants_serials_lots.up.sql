@@ -0,0 +1,49 @@
+-- Grade de variantes do produto (tamanho x cor), cada uma com SKU e estoque próprios
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku VARCHAR(100) NOT NULL,
+    size VARCHAR(30) NOT NULL DEFAULT '',
+    color VARCHAR(30) NOT NULL DEFAULT '',
+    barcode VARCHAR(50),
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_product_variants_combination UNIQUE (product_id, size, color),
+    CONSTRAINT uq_product_variants_company_sku UNIQUE (company_id, sku)
+);
+CREATE INDEX IF NOT EXISTS idx_product_variants_company_id ON product_variants(company_id);
+
+-- Lote e validade nas entradas de estoque (camadas de custo) e nas saídas (itens entregues)
+ALTER TABLE cost_layers ADD COLUMN IF NOT EXISTS lot_number VARCHAR(60);
+ALTER TABLE cost_layers ADD COLUMN IF NOT EXISTS expiry_date DATE;
+CREATE INDEX IF NOT EXISTS idx_cost_layers_lot_number ON cost_layers(lot_number) WHERE lot_number IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_cost_layers_expiry_date ON cost_layers(expiry_date)
+    WHERE expiry_date IS NOT NULL AND remaining_quantity > 0;
+
+ALTER TABLE delivery_items ADD COLUMN IF NOT EXISTS lot_number VARCHAR(60);
+CREATE INDEX IF NOT EXISTS idx_delivery_items_lot_number ON delivery_items(lot_number) WHERE lot_number IS NOT NULL;
+
+-- Números de série: entrada pelo recebimento do pedido de compra e saída pelo item entregue
+CREATE TABLE IF NOT EXISTS serial_numbers (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    serial_number VARCHAR(100) NOT NULL,
+    lot_number VARCHAR(60),
+    status VARCHAR(20) NOT NULL DEFAULT 'in_stock' CHECK (status IN ('in_stock', 'shipped')),
+    purchase_order_id INTEGER REFERENCES purchase_orders(id),
+    po_item_id INTEGER REFERENCES purchase_order_items(id),
+    received_at TIMESTAMP WITH TIME ZONE,
+    delivery_id INTEGER REFERENCES deliveries(id),
+    delivery_item_id INTEGER REFERENCES delivery_items(id),
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_serial_numbers_company_product_serial UNIQUE (company_id, product_id, serial_number)
+);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_company_id ON serial_numbers(company_id);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_serial_number ON serial_numbers(serial_number);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_delivery_item_id ON serial_numbers(delivery_item_id);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_cogs_entries_kit_product_id;
+ALTER TABLE cogs_entries DROP CONSTRAINT IF EXISTS uq_cogs_source_item;
+ALTER TABLE cogs_entries ADD CONSTRAINT uq_cogs_source_item UNIQUE (source_type, source_item_id);
+ALTER TABLE cogs_entries DROP COLUMN IF EXISTS kit_product_id;
+
+DROP INDEX IF EXISTS idx_invoice_items_kit_product_id;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS kit_product_id;
+ALTER TABLE sales_order_items DROP COLUMN IF EXISTS kit_product_id;
+ALTER TABLE quotation_items DROP COLUMN IF EXISTS kit_product_id;
+
+DROP TABLE IF EXISTS product_kit_components;
+DROP TABLE IF EXISTS product_kits;
@@ -0,0 +1,33 @@
+-- Kits de produtos: um produto composto por outros produtos em quantidades fixas. O modo define
+-- se o kit é desdobrado nos componentes ao entrar nos documentos de venda ou mantido como uma linha
+CREATE TABLE IF NOT EXISTS product_kits (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    mode VARCHAR(10) NOT NULL DEFAULT 'keep' CHECK (mode IN ('explode', 'keep')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_product_kits_product UNIQUE (product_id)
+);
+CREATE INDEX IF NOT EXISTS idx_product_kits_company_id ON product_kits(company_id);
+
+CREATE TABLE IF NOT EXISTS product_kit_components (
+    id SERIAL PRIMARY KEY,
+    kit_id INTEGER NOT NULL REFERENCES product_kits(id) ON DELETE CASCADE,
+    component_product_id INTEGER NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    CONSTRAINT uq_product_kit_components UNIQUE (kit_id, component_product_id)
+);
+CREATE INDEX IF NOT EXISTS idx_product_kit_components_component ON product_kit_components(component_product_id);
+
+-- Linhas de componentes geradas pelo desdobramento de um kit apontam para o produto do kit
+ALTER TABLE quotation_items ADD COLUMN IF NOT EXISTS kit_product_id INTEGER REFERENCES products(id);
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS kit_product_id INTEGER REFERENCES products(id);
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS kit_product_id INTEGER REFERENCES products(id);
+CREATE INDEX IF NOT EXISTS idx_invoice_items_kit_product_id ON invoice_items(kit_product_id) WHERE kit_product_id IS NOT NULL;
+
+-- O CMV de um kit mantido é apurado por componente: um item vendido gera uma entrada por produto
+ALTER TABLE cogs_entries ADD COLUMN IF NOT EXISTS kit_product_id INTEGER REFERENCES products(id);
+ALTER TABLE cogs_entries DROP CONSTRAINT IF EXISTS uq_cogs_source_item;
+ALTER TABLE cogs_entries ADD CONSTRAINT uq_cogs_source_item UNIQUE (source_type, source_item_id, product_id);
+CREATE INDEX IF NOT EXISTS idx_cogs_entries_kit_product_id ON cogs_entries(kit_product_id) WHERE kit_product_id IS NOT NULL;
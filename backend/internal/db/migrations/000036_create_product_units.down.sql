@@ -0,0 +1,11 @@
+ALTER TABLE delivery_items DROP COLUMN IF EXISTS unit;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS unit_factor;
+ALTER TABLE invoice_items DROP COLUMN IF EXISTS unit;
+ALTER TABLE sales_order_items DROP COLUMN IF EXISTS unit_factor;
+ALTER TABLE sales_order_items DROP COLUMN IF EXISTS unit;
+ALTER TABLE quotation_items DROP COLUMN IF EXISTS unit_factor;
+ALTER TABLE quotation_items DROP COLUMN IF EXISTS unit;
+ALTER TABLE purchase_order_items DROP COLUMN IF EXISTS unit_factor;
+ALTER TABLE purchase_order_items DROP COLUMN IF EXISTS unit;
+
+DROP TABLE IF EXISTS product_units;
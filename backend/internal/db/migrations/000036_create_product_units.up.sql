@@ -0,0 +1,28 @@
+-- Unidades de medida por produto: a unidade de estoque (fator 1) e as embalagens de compra e
+-- venda, com a quantidade de unidades de estoque que cada uma contém
+CREATE TABLE IF NOT EXISTS product_units (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    unit VARCHAR(10) NOT NULL,
+    name VARCHAR(50),
+    factor INTEGER NOT NULL CHECK (factor > 0),
+    is_purchase BOOLEAN NOT NULL DEFAULT FALSE,
+    is_sales BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_product_units UNIQUE (product_id, unit)
+);
+CREATE INDEX IF NOT EXISTS idx_product_units_company_id ON product_units(company_id);
+
+-- A quantidade das linhas fica na unidade informada; quantity * unit_factor é a quantidade em
+-- unidades de estoque. Os itens de entrega são gravados sempre na unidade de estoque.
+ALTER TABLE purchase_order_items ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'UN';
+ALTER TABLE purchase_order_items ADD COLUMN IF NOT EXISTS unit_factor INTEGER NOT NULL DEFAULT 1 CHECK (unit_factor > 0);
+ALTER TABLE quotation_items ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'UN';
+ALTER TABLE quotation_items ADD COLUMN IF NOT EXISTS unit_factor INTEGER NOT NULL DEFAULT 1 CHECK (unit_factor > 0);
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'UN';
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS unit_factor INTEGER NOT NULL DEFAULT 1 CHECK (unit_factor > 0);
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'UN';
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS unit_factor INTEGER NOT NULL DEFAULT 1 CHECK (unit_factor > 0);
+ALTER TABLE delivery_items ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'UN';
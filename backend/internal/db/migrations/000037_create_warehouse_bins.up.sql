@@ -0,0 +1,17 @@
+-- Endereços de estoque (corredor/prateleira/posição) identificados por etiqueta, usados pelos
+-- aplicativos de armazém para receber e separar com leitor de código de barras
+CREATE TABLE IF NOT EXISTS warehouse_bins (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    code VARCHAR(40) NOT NULL,
+    name VARCHAR(100),
+    zone VARCHAR(50),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_warehouse_bins_code UNIQUE (company_id, code)
+);
+CREATE INDEX IF NOT EXISTS idx_warehouse_bins_company_id ON warehouse_bins(company_id);
+
+-- Leitura de códigos: busca de produtos pelo código de barras
+CREATE INDEX IF NOT EXISTS idx_products_barcode ON products(barcode) WHERE barcode IS NOT NULL AND barcode <> '';
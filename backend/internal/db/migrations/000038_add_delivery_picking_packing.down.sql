@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS delivery_package_items;
+DROP TABLE IF EXISTS delivery_packages;
+
+ALTER TABLE delivery_items DROP COLUMN IF EXISTS picked_qty;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS packed_at;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS picking_started_at;
+
+ALTER TABLE products DROP COLUMN IF EXISTS bin_id;
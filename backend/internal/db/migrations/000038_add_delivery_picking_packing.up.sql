@@ -0,0 +1,30 @@
+-- Endereço de estoque padrão do produto, usado para agrupar a lista de separação
+ALTER TABLE products ADD COLUMN IF NOT EXISTS bin_id INTEGER REFERENCES warehouse_bins(id) ON DELETE SET NULL;
+
+-- Separação e embalagem das entregas de pedidos de venda: pending -> picking -> packed -> shipped
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS picking_started_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS packed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE delivery_items ADD COLUMN IF NOT EXISTS picked_qty INTEGER NOT NULL DEFAULT 0 CHECK (picked_qty >= 0);
+
+-- Volumes da entrega, com peso e dimensões, e as quantidades de cada item embaladas no volume
+CREATE TABLE IF NOT EXISTS delivery_packages (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    delivery_id INTEGER NOT NULL REFERENCES deliveries(id) ON DELETE CASCADE,
+    package_no INTEGER NOT NULL,
+    weight_kg NUMERIC(10,3) NOT NULL CHECK (weight_kg > 0),
+    length_cm NUMERIC(10,2) NOT NULL DEFAULT 0,
+    width_cm NUMERIC(10,2) NOT NULL DEFAULT 0,
+    height_cm NUMERIC(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_delivery_packages UNIQUE (delivery_id, package_no)
+);
+CREATE INDEX IF NOT EXISTS idx_delivery_packages_company_id ON delivery_packages(company_id);
+
+CREATE TABLE IF NOT EXISTS delivery_package_items (
+    id SERIAL PRIMARY KEY,
+    package_id INTEGER NOT NULL REFERENCES delivery_packages(id) ON DELETE CASCADE,
+    delivery_item_id INTEGER NOT NULL REFERENCES delivery_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+CREATE INDEX IF NOT EXISTS idx_delivery_package_items_package_id ON delivery_package_items(package_id);
@@ -0,0 +1,11 @@
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS shipping_days;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS shipping_cost;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS shipping_service;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS shipping_service_code;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS shipping_carrier;
+
+ALTER TABLE quotations DROP COLUMN IF EXISTS shipping_days;
+ALTER TABLE quotations DROP COLUMN IF EXISTS shipping_cost;
+ALTER TABLE quotations DROP COLUMN IF EXISTS shipping_service;
+ALTER TABLE quotations DROP COLUMN IF EXISTS shipping_service_code;
+ALTER TABLE quotations DROP COLUMN IF EXISTS shipping_carrier;
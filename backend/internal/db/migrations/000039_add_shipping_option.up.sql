@@ -0,0 +1,13 @@
+-- Opção de frete escolhida na cotação e no pedido de venda (transportadora, serviço, custo e
+-- prazo em dias úteis); o custo entra na lucratividade do processo de venda
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS shipping_carrier VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS shipping_service_code VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS shipping_service VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS shipping_cost NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (shipping_cost >= 0);
+ALTER TABLE quotations ADD COLUMN IF NOT EXISTS shipping_days INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS shipping_carrier VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS shipping_service_code VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS shipping_service VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS shipping_cost NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (shipping_cost >= 0);
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS shipping_days INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS support_tickets;
+DROP TABLE IF EXISTS portal_accesses;
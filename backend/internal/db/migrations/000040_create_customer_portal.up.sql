@@ -0,0 +1,32 @@
+-- Acessos do portal do cliente: o código de acesso é entregue ao contato e trocado por um
+-- token JWT de escopo "portal", que só abre as rotas /portal. Apenas o hash do código é gravado
+CREATE TABLE IF NOT EXISTS portal_accesses (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    contact_id INTEGER NOT NULL REFERENCES contacts(id),
+    prefix VARCHAR(16) NOT NULL,
+    code_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(50),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_portal_accesses_company_id ON portal_accesses(company_id);
+CREATE INDEX IF NOT EXISTS idx_portal_accesses_contact_id ON portal_accesses(contact_id);
+
+-- Chamados de suporte abertos pelos clientes no portal, opcionalmente ligados a um documento
+CREATE TABLE IF NOT EXISTS support_tickets (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    contact_id INTEGER NOT NULL REFERENCES contacts(id),
+    subject VARCHAR(200) NOT NULL,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    document_type VARCHAR(20) NOT NULL DEFAULT '',
+    document_id INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_support_tickets_company_id ON support_tickets(company_id);
+CREATE INDEX IF NOT EXISTS idx_support_tickets_contact_id ON support_tickets(contact_id);
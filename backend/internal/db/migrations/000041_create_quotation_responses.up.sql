@@ -0,0 +1,15 @@
+-- Respostas do cliente às cotações (aceite ou recusa), pelo portal ou pelo link de
+-- compartilhamento, com o comentário, o IP e o instante da resposta
+CREATE TABLE IF NOT EXISTS quotation_responses (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    quotation_id INTEGER NOT NULL REFERENCES quotations(id),
+    decision VARCHAR(20) NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    channel VARCHAR(20) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    responded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_quotation_responses_company_id ON quotation_responses(company_id);
+CREATE INDEX IF NOT EXISTS idx_quotation_responses_quotation_id ON quotation_responses(quotation_id);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS inbound_documents;
+
+DROP INDEX IF EXISTS uq_supplier_bills_company_nfe_key;
+ALTER TABLE supplier_bills DROP COLUMN IF EXISTS nfe_key;
+UPDATE supplier_bills SET status = 'open' WHERE status = 'draft';
+ALTER TABLE supplier_bills DROP CONSTRAINT IF EXISTS valid_supplier_bill_status;
+ALTER TABLE supplier_bills ADD CONSTRAINT valid_supplier_bill_status
+    CHECK (status IN ('open', 'partial', 'paid', 'cancelled'));
@@ -0,0 +1,35 @@
+-- Contas a pagar importadas de NF-e entram como rascunho até a conferência; a chave de acesso
+-- evita importar a mesma nota duas vezes
+ALTER TABLE supplier_bills DROP CONSTRAINT IF EXISTS valid_supplier_bill_status;
+ALTER TABLE supplier_bills ADD CONSTRAINT valid_supplier_bill_status
+    CHECK (status IN ('draft', 'open', 'partial', 'paid', 'cancelled'));
+ALTER TABLE supplier_bills ADD COLUMN IF NOT EXISTS nfe_key VARCHAR(44) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_supplier_bills_company_nfe_key
+    ON supplier_bills(company_id, nfe_key) WHERE nfe_key <> '';
+
+-- Anexos recebidos por e-mail (XML/PDF de notas de fornecedores). O arquivo fica nos anexos
+-- (entity_type inbound_document); os que não viram conta a pagar aguardam revisão manual
+CREATE TABLE IF NOT EXISTS inbound_documents (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    provider VARCHAR(20) NOT NULL,
+    message_id VARCHAR(255) NOT NULL DEFAULT '',
+    sender VARCHAR(255) NOT NULL DEFAULT '',
+    subject VARCHAR(255) NOT NULL DEFAULT '',
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    nfe_key VARCHAR(44) NOT NULL DEFAULT '',
+    supplier_bill_id INTEGER REFERENCES supplier_bills(id),
+    purchase_order_id INTEGER REFERENCES purchase_orders(id),
+    error TEXT NOT NULL DEFAULT '',
+    reviewed_by VARCHAR(50) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_inbound_document_status
+        CHECK (status IN ('processed', 'duplicate', 'pending_review', 'resolved', 'discarded'))
+);
+CREATE INDEX IF NOT EXISTS idx_inbound_documents_company_id ON inbound_documents(company_id);
+CREATE INDEX IF NOT EXISTS idx_inbound_documents_status ON inbound_documents(status);
@@ -0,0 +1,12 @@
+-- Campos das respostas da API restritos por perfil: hide remove o campo e mask devolve null.
+-- Administradores nunca são restringidos.
+CREATE TABLE IF NOT EXISTS field_permissions (
+    id SERIAL PRIMARY KEY,
+    role VARCHAR(50) NOT NULL,
+    field VARCHAR(100) NOT NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('hide', 'mask')),
+    updated_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (role, field)
+);
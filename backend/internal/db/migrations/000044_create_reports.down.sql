@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS report_schedules;
+DROP TABLE IF EXISTS reports;
@@ -0,0 +1,36 @@
+-- Relatórios compostos pelos administradores: a definição (entidade, filtros, agrupamentos e
+-- agregações) é validada contra o catálogo de entidades do código antes de ser gravada
+CREATE TABLE IF NOT EXISTS reports (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(150) NOT NULL,
+    description TEXT,
+    definition JSONB NOT NULL,
+    created_by VARCHAR(100),
+    updated_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reports_company ON reports(company_id);
+
+-- Envios periódicos por e-mail; next_run_at é remarcado a cada envio, com ou sem sucesso
+CREATE TABLE IF NOT EXISTS report_schedules (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    report_id INTEGER NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'pdf')),
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+    hour SMALLINT NOT NULL DEFAULT 0 CHECK (hour BETWEEN 0 AND 23),
+    recipients JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP NOT NULL,
+    last_run_at TIMESTAMP,
+    last_error TEXT,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedules_due ON report_schedules(next_run_at) WHERE active;
+CREATE INDEX IF NOT EXISTS idx_report_schedules_report ON report_schedules(report_id);
@@ -0,0 +1,18 @@
+-- Lançamentos previstos que não vêm dos documentos (folha de pagamento, aluguel, impostos):
+-- entram na projeção de fluxo de caixa na frequência informada, entre start_date e end_date
+CREATE TABLE IF NOT EXISTS cashflow_items (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(150) NOT NULL,
+    category VARCHAR(50) NOT NULL DEFAULT 'other',
+    direction VARCHAR(3) NOT NULL CHECK (direction IN ('in', 'out')),
+    amount NUMERIC(15, 2) NOT NULL CHECK (amount > 0),
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('once', 'weekly', 'monthly')),
+    start_date DATE NOT NULL,
+    end_date DATE,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_cashflow_items_company ON cashflow_items(company_id);
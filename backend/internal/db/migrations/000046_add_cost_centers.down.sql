@@ -0,0 +1,7 @@
+ALTER TABLE ledger_accounts DROP COLUMN IF EXISTS dre_group;
+DROP INDEX IF EXISTS idx_journal_entries_cost_center;
+ALTER TABLE journal_entries DROP COLUMN IF EXISTS cost_center_id;
+ALTER TABLE supplier_bills DROP COLUMN IF EXISTS cost_center_id;
+ALTER TABLE credit_notes DROP COLUMN IF EXISTS cost_center_id;
+ALTER TABLE invoices DROP COLUMN IF EXISTS cost_center_id;
+DROP TABLE IF EXISTS cost_centers;
@@ -0,0 +1,24 @@
+-- Centros de custo: classificam faturas, notas de crédito e contas a pagar, e os lançamentos
+-- contábeis gerados a partir delas, para a DRE por centro de custo
+CREATE TABLE IF NOT EXISTS cost_centers (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    code VARCHAR(30) NOT NULL,
+    name VARCHAR(150) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_cost_centers_company_code UNIQUE (company_id, code)
+);
+
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS cost_center_id INTEGER REFERENCES cost_centers(id);
+ALTER TABLE credit_notes ADD COLUMN IF NOT EXISTS cost_center_id INTEGER REFERENCES cost_centers(id);
+ALTER TABLE supplier_bills ADD COLUMN IF NOT EXISTS cost_center_id INTEGER REFERENCES cost_centers(id);
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS cost_center_id INTEGER REFERENCES cost_centers(id);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_cost_center ON journal_entries(cost_center_id);
+
+-- Linha da DRE em que a conta entra; vazia, a conta segue o tipo e os mapeamentos da
+-- contabilização automática
+ALTER TABLE ledger_accounts ADD COLUMN IF NOT EXISTS dre_group VARCHAR(30)
+    CHECK (dre_group IN ('gross_revenue', 'deductions', 'cogs', 'operating_expenses', 'financial_result'));
@@ -0,0 +1,19 @@
+-- Orçamento mensal por conta de resultado e centro de custo (opcional); o realizado vem das
+-- faturas e contas a pagar no relatório de variação
+CREATE TABLE IF NOT EXISTS budgets (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    cost_center_id INTEGER REFERENCES cost_centers(id),
+    account_id INTEGER NOT NULL REFERENCES ledger_accounts(id),
+    month VARCHAR(7) NOT NULL CHECK (month ~ '^[0-9]{4}-(0[1-9]|1[0-2])$'),
+    amount NUMERIC(15, 2) NOT NULL CHECK (amount >= 0),
+    notes TEXT,
+    created_by VARCHAR(100),
+    updated_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Uma linha por empresa, centro de custo (inclusive sem centro), conta e mês
+CREATE UNIQUE INDEX IF NOT EXISTS uq_budgets_line
+    ON budgets(company_id, COALESCE(cost_center_id, 0), account_id, month);
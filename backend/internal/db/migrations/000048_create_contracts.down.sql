@@ -0,0 +1,4 @@
+ALTER TABLE purchase_order_items DROP COLUMN IF EXISTS contract_id;
+ALTER TABLE quotation_items DROP COLUMN IF EXISTS contract_id;
+DROP TABLE IF EXISTS contract_items;
+DROP TABLE IF EXISTS contracts;
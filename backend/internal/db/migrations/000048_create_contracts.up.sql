@@ -0,0 +1,38 @@
+-- Contratos com preços negociados por cliente (sales) ou fornecedor (purchase): enquanto vigentes,
+-- cotações e pedidos de compra do contato usam o preço contratado dos produtos
+CREATE TABLE IF NOT EXISTS contracts (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    contract_no VARCHAR(50) NOT NULL,
+    contact_id INTEGER NOT NULL REFERENCES contacts(id),
+    type VARCHAR(10) NOT NULL CHECK (type IN ('sales', 'purchase')),
+    title VARCHAR(150) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    notes TEXT,
+    alert_emails JSONB NOT NULL DEFAULT '[]',
+    expiry_alerted_at TIMESTAMP,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_contract_period CHECK (end_date >= start_date),
+    UNIQUE (company_id, contract_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contracts_contact ON contracts(company_id, contact_id, type);
+CREATE INDEX IF NOT EXISTS idx_contracts_end_date ON contracts(end_date) WHERE status = 'active';
+
+-- Preço fixo por unidade de estoque e volume mínimo comprometido no período do contrato
+CREATE TABLE IF NOT EXISTS contract_items (
+    id SERIAL PRIMARY KEY,
+    contract_id INTEGER NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    unit_price NUMERIC(15, 2) NOT NULL CHECK (unit_price > 0),
+    min_quantity INTEGER NOT NULL DEFAULT 0 CHECK (min_quantity >= 0),
+    UNIQUE (contract_id, product_id)
+);
+
+-- Contrato de onde veio o preço da linha
+ALTER TABLE quotation_items ADD COLUMN IF NOT EXISTS contract_id INTEGER REFERENCES contracts(id) ON DELETE SET NULL;
+ALTER TABLE purchase_order_items ADD COLUMN IF NOT EXISTS contract_id INTEGER REFERENCES contracts(id) ON DELETE SET NULL;
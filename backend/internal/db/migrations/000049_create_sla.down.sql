@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_sales_processes_sla_status;
+DROP INDEX IF EXISTS idx_deliveries_sla_status;
+
+ALTER TABLE sales_processes DROP COLUMN IF EXISTS sla_due_at;
+ALTER TABLE sales_processes DROP COLUMN IF EXISTS sla_status;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS sla_due_at;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS sla_status;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS shipped_at;
+
+DROP TABLE IF EXISTS sla_definitions;
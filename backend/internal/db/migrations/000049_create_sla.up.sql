@@ -0,0 +1,28 @@
+-- Prazos de SLA por empresa: um por alvo (envio da entrega a partir do pedido de venda ou
+-- conclusão do processo de venda a partir da abertura), em dias úteis, dias corridos ou horas
+CREATE TABLE IF NOT EXISTS sla_definitions (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    target VARCHAR(30) NOT NULL CHECK (target IN ('delivery_shipment', 'process_completion')),
+    duration INTEGER NOT NULL CHECK (duration > 0),
+    unit VARCHAR(20) NOT NULL CHECK (unit IN ('business_days', 'days', 'hours')),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, target)
+);
+
+-- Momento do envio da entrega, base do SLA de envio (delivery_date guarda a data prevista)
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS shipped_at TIMESTAMP;
+UPDATE deliveries SET shipped_at = COALESCE(delivery_date, updated_at)
+WHERE status IN ('shipped', 'delivered', 'returned') AND shipped_at IS NULL;
+
+-- Status de SLA calculado pela rotina periódica: on_track, met ou breached (NULL sem SLA)
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS sla_status VARCHAR(20);
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS sla_due_at TIMESTAMP;
+ALTER TABLE sales_processes ADD COLUMN IF NOT EXISTS sla_status VARCHAR(20);
+ALTER TABLE sales_processes ADD COLUMN IF NOT EXISTS sla_due_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_deliveries_sla_status ON deliveries(company_id, sla_status);
+CREATE INDEX IF NOT EXISTS idx_sales_processes_sla_status ON sales_processes(company_id, sla_status);
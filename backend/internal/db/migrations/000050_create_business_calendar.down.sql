@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_holidays_unique;
+DROP TABLE IF EXISTS holidays;
+DROP TABLE IF EXISTS business_calendars;
@@ -0,0 +1,28 @@
+-- Calendário de expediente da empresa: dias da semana úteis (0 = domingo), horário de
+-- funcionamento e UF dos feriados estaduais. Sem linha, vale segunda a sexta, das 08:00 às 18:00.
+CREATE TABLE IF NOT EXISTS business_calendars (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    state CHAR(2),
+    working_days JSONB NOT NULL DEFAULT '[1,2,3,4,5]',
+    opens_at VARCHAR(5) NOT NULL DEFAULT '08:00',
+    closes_at VARCHAR(5) NOT NULL DEFAULT '18:00',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id)
+);
+
+-- Feriados cadastrados pela empresa, além dos nacionais embutidos: sem UF valem sempre; com UF,
+-- só quando o calendário é do estado. Os recorrentes repetem o dia e o mês todo ano.
+CREATE TABLE IF NOT EXISTS holidays (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    date DATE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    state CHAR(2),
+    recurring BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holidays_unique ON holidays(company_id, date, COALESCE(state, ''));
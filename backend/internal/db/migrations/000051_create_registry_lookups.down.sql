@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_contacts_registry_checked_at;
+
+ALTER TABLE contacts DROP COLUMN IF EXISTS registry_checked_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS registry_status;
+ALTER TABLE contacts DROP COLUMN IF EXISTS cnae;
+
+DROP TABLE IF EXISTS cep_lookups;
+DROP TABLE IF EXISTS cnpj_lookups;
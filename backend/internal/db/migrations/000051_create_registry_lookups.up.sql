@@ -0,0 +1,24 @@
+-- Cache local das consultas aos cadastros públicos (Receita Federal pelo CNPJ e ViaCEP pelo CEP).
+-- Os dados são públicos e valem para todas as empresas.
+CREATE TABLE IF NOT EXISTS cnpj_lookups (
+    cnpj CHAR(14) PRIMARY KEY,
+    data JSONB NOT NULL,
+    active BOOLEAN NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS cep_lookups (
+    cep CHAR(8) PRIMARY KEY,
+    data JSONB NOT NULL,
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- CNAE principal preenchido pela consulta do CNPJ
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS cnae VARCHAR(10) NOT NULL DEFAULT '';
+
+-- Situação cadastral do CNPJ na última revalidação (ATIVA, BAIXADA, INAPTA...) e a data da consulta
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS registry_status VARCHAR(40) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS registry_checked_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_registry_checked_at ON contacts(registry_checked_at) WHERE person_type = 'pj';
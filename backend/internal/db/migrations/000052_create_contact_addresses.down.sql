@@ -0,0 +1,9 @@
+ALTER TABLE invoices DROP COLUMN IF EXISTS billing_address;
+ALTER TABLE invoices DROP COLUMN IF EXISTS billing_address_id;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS shipping_address_id;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS billing_address;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS billing_address_id;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS shipping_address_id;
+DROP INDEX IF EXISTS idx_contact_addresses_default;
+DROP INDEX IF EXISTS idx_contact_addresses_contact;
+DROP TABLE IF EXISTS contact_addresses;
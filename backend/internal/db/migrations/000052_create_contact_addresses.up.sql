@@ -0,0 +1,43 @@
+-- Catálogo de endereços dos contatos: cobrança, entrega e demais unidades (filiais, obras,
+-- depósitos), com coordenadas para o roteiro das entregas. Um padrão por tipo.
+CREATE TABLE IF NOT EXISTS contact_addresses (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    contact_id INTEGER NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    label VARCHAR(100) NOT NULL DEFAULT '',
+    zip_code CHAR(8) NOT NULL,
+    street VARCHAR(255) NOT NULL,
+    number VARCHAR(20) NOT NULL DEFAULT '',
+    complement VARCHAR(100) NOT NULL DEFAULT '',
+    neighborhood VARCHAR(100) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL,
+    state CHAR(2) NOT NULL,
+    latitude NUMERIC(9,6),
+    longitude NUMERIC(9,6),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_addresses_contact ON contact_addresses(contact_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contact_addresses_default ON contact_addresses(contact_id, type) WHERE is_default;
+
+-- O endereço cadastrado no contato vira o endereço padrão de cobrança e de entrega
+INSERT INTO contact_addresses (company_id, contact_id, type, zip_code, street, number, complement, neighborhood, city, state, is_default)
+SELECT c.company_id, c.id, t.type, regexp_replace(c.zip_code, '\D', '', 'g'), c.street, COALESCE(c.number, ''),
+       COALESCE(c.complement, ''), COALESCE(c.neighborhood, ''), c.city, c.state, TRUE
+FROM contacts c
+CROSS JOIN (VALUES ('billing'), ('shipping')) AS t(type)
+WHERE length(regexp_replace(COALESCE(c.zip_code, ''), '\D', '', 'g')) = 8
+  AND COALESCE(c.street, '') <> '' AND COALESCE(c.city, '') <> '' AND length(COALESCE(c.state, '')) = 2
+  AND NOT EXISTS (SELECT 1 FROM contact_addresses a WHERE a.contact_id = c.id);
+
+-- Endereços do catálogo usados nos documentos; shipping_address e billing_address (texto) guardam
+-- o endereço como estava na emissão
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS shipping_address_id INTEGER REFERENCES contact_addresses(id) ON DELETE SET NULL;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS billing_address_id INTEGER REFERENCES contact_addresses(id) ON DELETE SET NULL;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS billing_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS shipping_address_id INTEGER REFERENCES contact_addresses(id) ON DELETE SET NULL;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS billing_address_id INTEGER REFERENCES contact_addresses(id) ON DELETE SET NULL;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS billing_address TEXT NOT NULL DEFAULT '';
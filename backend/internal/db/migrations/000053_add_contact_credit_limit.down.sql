@@ -0,0 +1,8 @@
+UPDATE sales_orders SET status = 'draft' WHERE status = 'credit_hold';
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS valid_so_status;
+ALTER TABLE sales_orders ADD CONSTRAINT valid_so_status
+    CHECK (status IN ('draft', 'confirmed', 'processing', 'completed', 'cancelled'));
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS credit_approved_at;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS credit_approved_by;
+ALTER TABLE contacts DROP COLUMN IF EXISTS credit_policy;
+ALTER TABLE contacts DROP COLUMN IF EXISTS credit_limit;
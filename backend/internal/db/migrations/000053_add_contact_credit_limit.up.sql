@@ -0,0 +1,13 @@
+-- Limite de crédito do cliente (NULL = sem limite) e o que fazer quando um pedido o ultrapassa:
+-- block recusa o pedido; approval deixa o pedido em credit_hold até a liberação
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS credit_limit DECIMAL(12,2);
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS credit_policy VARCHAR(10) NOT NULL DEFAULT 'approval';
+
+-- Liberação de crédito dos pedidos retidos
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS credit_approved_by VARCHAR(100);
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS credit_approved_at TIMESTAMP;
+
+-- Pedido retido pelo limite de crédito, aguardando liberação
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS valid_so_status;
+ALTER TABLE sales_orders ADD CONSTRAINT valid_so_status
+    CHECK (status IN ('draft', 'credit_hold', 'confirmed', 'processing', 'completed', 'cancelled'));
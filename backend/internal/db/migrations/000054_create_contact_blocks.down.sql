@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_contact_block_events_contact;
+DROP TABLE IF EXISTS contact_block_events;
+ALTER TABLE contacts DROP COLUMN IF EXISTS blocked_by;
+ALTER TABLE contacts DROP COLUMN IF EXISTS blocked_from;
+ALTER TABLE contacts DROP COLUMN IF EXISTS block_notes;
+ALTER TABLE contacts DROP COLUMN IF EXISTS block_reason;
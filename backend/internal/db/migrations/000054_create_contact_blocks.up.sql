@@ -0,0 +1,21 @@
+-- Bloqueio de contatos (fraude, litígio, sanções): a partir de blocked_from o contato não
+-- recebe novas cotações, pedidos, entregas ou faturas
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS block_reason VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS block_notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS blocked_from DATE;
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS blocked_by VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Trilha de auditoria dos bloqueios e desbloqueios
+CREATE TABLE IF NOT EXISTS contact_block_events (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    contact_id INTEGER NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('block', 'unblock')),
+    reason VARCHAR(20) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    effective_date DATE,
+    username VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_block_events_contact ON contact_block_events(contact_id, created_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_sales_orders_custom_fields;
+DROP INDEX IF EXISTS idx_contacts_custom_fields;
+DROP INDEX IF EXISTS idx_products_custom_fields;
+ALTER TABLE sales_orders DROP COLUMN IF EXISTS custom_fields;
+ALTER TABLE contacts DROP COLUMN IF EXISTS custom_fields;
+ALTER TABLE products DROP COLUMN IF EXISTS custom_fields;
+DROP TABLE IF EXISTS custom_field_definitions;
@@ -0,0 +1,25 @@
+-- Campos personalizados: o administrador define os campos de produtos, contatos e pedidos de
+-- venda, e os valores ficam na coluna JSONB custom_fields de cada registro
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    entity VARCHAR(20) NOT NULL CHECK (entity IN ('product', 'contact', 'sales_order')),
+    key VARCHAR(50) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('text', 'number', 'boolean', 'date', 'select')),
+    options JSONB NOT NULL DEFAULT '[]',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, entity, key)
+);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+-- Filtros das listagens (?cf.<chave>=valor) usam custom_fields @> '{"chave": valor}'
+CREATE INDEX IF NOT EXISTS idx_products_custom_fields ON products USING GIN (custom_fields);
+CREATE INDEX IF NOT EXISTS idx_contacts_custom_fields ON contacts USING GIN (custom_fields);
+CREATE INDEX IF NOT EXISTS idx_sales_orders_custom_fields ON sales_orders USING GIN (custom_fields);
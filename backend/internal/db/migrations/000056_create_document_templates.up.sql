@@ -0,0 +1,13 @@
+-- Modelos de documento (corpos de e-mail, termos da cotação, rodapé da fatura). Cada alteração
+-- grava uma nova versão; vale a maior versão da empresa e, sem nenhuma, o modelo padrão do sistema
+CREATE TABLE IF NOT EXISTS document_templates (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    key VARCHAR(60) NOT NULL,
+    version INTEGER NOT NULL,
+    subject VARCHAR(200) NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, key, version)
+);
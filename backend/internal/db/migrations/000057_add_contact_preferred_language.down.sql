@@ -0,0 +1,7 @@
+ALTER TABLE document_templates DROP CONSTRAINT IF EXISTS document_templates_company_id_key_language_version_key;
+DELETE FROM document_templates WHERE language <> 'pt-BR';
+ALTER TABLE document_templates ADD CONSTRAINT document_templates_company_id_key_version_key
+    UNIQUE (company_id, key, version);
+ALTER TABLE document_templates DROP COLUMN IF EXISTS language;
+
+ALTER TABLE contacts DROP COLUMN IF EXISTS preferred_language;
@@ -0,0 +1,9 @@
+-- Idioma preferido do contato (pt-BR ou en-US) para o PDF da fatura, os termos da cotação e os
+-- e-mails enviados a ele; vazio segue o idioma da requisição
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS preferred_language VARCHAR(5) NOT NULL DEFAULT '';
+
+-- Cada idioma tem as suas versões dos modelos de documento
+ALTER TABLE document_templates ADD COLUMN IF NOT EXISTS language VARCHAR(5) NOT NULL DEFAULT 'pt-BR';
+ALTER TABLE document_templates DROP CONSTRAINT IF EXISTS document_templates_company_id_key_version_key;
+ALTER TABLE document_templates ADD CONSTRAINT document_templates_company_id_key_language_version_key
+    UNIQUE (company_id, key, language, version);
@@ -0,0 +1,3 @@
+-- Fuso horário da empresa (nome IANA), usado para decidir o que está vencido ou expirado, os
+-- processos abandonados e os limites dos relatórios diários; os instantes seguem em UTC
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'America/Sao_Paulo';
@@ -0,0 +1,6 @@
+ALTER TABLE acc_transaction ALTER COLUMN amount TYPE NUMERIC(10, 2);
+ALTER TABLE return_items ALTER COLUMN unit_price TYPE DECIMAL(12, 2);
+ALTER TABLE invoice_items ALTER COLUMN unit_price TYPE DECIMAL(12, 2);
+ALTER TABLE purchase_order_items ALTER COLUMN unit_price TYPE DECIMAL(12, 2);
+ALTER TABLE sales_order_items ALTER COLUMN unit_price TYPE DECIMAL(12, 2);
+ALTER TABLE quotation_items ALTER COLUMN unit_price TYPE DECIMAL(12, 2);
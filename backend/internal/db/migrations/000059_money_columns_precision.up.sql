@@ -0,0 +1,8 @@
+-- Valores monetários passam a ser decimais de ponto fixo na aplicação; preços unitários guardam
+-- 4 casas (rateio de kits e conversão de unidades) e os totais continuam arredondados em centavos
+ALTER TABLE quotation_items ALTER COLUMN unit_price TYPE DECIMAL(14, 4);
+ALTER TABLE sales_order_items ALTER COLUMN unit_price TYPE DECIMAL(14, 4);
+ALTER TABLE purchase_order_items ALTER COLUMN unit_price TYPE DECIMAL(14, 4);
+ALTER TABLE invoice_items ALTER COLUMN unit_price TYPE DECIMAL(14, 4);
+ALTER TABLE return_items ALTER COLUMN unit_price TYPE DECIMAL(14, 4);
+ALTER TABLE acc_transaction ALTER COLUMN amount TYPE DECIMAL(14, 2);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payment_allocations_company_id;
+DROP INDEX IF EXISTS idx_payment_allocations_invoice;
+DROP TABLE IF EXISTS payment_allocations;
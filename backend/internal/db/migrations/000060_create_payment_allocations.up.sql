@@ -0,0 +1,20 @@
+-- Alocação dos pagamentos entre faturas: um pagamento pode quitar várias faturas do mesmo
+-- contato e o valor pago da fatura passa a ser a soma das suas alocações
+CREATE TABLE IF NOT EXISTS payment_allocations (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    payment_id INTEGER NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_payment_allocations_payment_invoice UNIQUE (payment_id, invoice_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_invoice ON payment_allocations(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_payment_allocations_company_id ON payment_allocations(company_id);
+
+-- Os pagamentos existentes ficam alocados inteiros na própria fatura
+INSERT INTO payment_allocations (company_id, payment_id, invoice_id, amount)
+SELECT company_id, id, invoice_id, amount FROM payments WHERE amount > 0
+ON CONFLICT (payment_id, invoice_id) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_invoice_installments_company_id;
+DROP INDEX IF EXISTS idx_invoice_installments_due_date;
+DROP TABLE IF EXISTS invoice_installments;
@@ -0,0 +1,22 @@
+-- Parcelas das faturas: cada parcela tem vencimento, valor, valor pago e status próprios, e a
+-- cobrança gerada para ela (boleto e Pix copia e cola)
+CREATE TABLE IF NOT EXISTS invoice_installments (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL CHECK (number > 0),
+    due_date TIMESTAMP NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    amount_paid DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    boleto_our_number VARCHAR(20),
+    boleto_barcode VARCHAR(44),
+    boleto_digitable_line VARCHAR(60),
+    pix_payload TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_invoice_installments_invoice_number UNIQUE (invoice_id, number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_installments_due_date ON invoice_installments(due_date);
+CREATE INDEX IF NOT EXISTS idx_invoice_installments_company_id ON invoice_installments(company_id);
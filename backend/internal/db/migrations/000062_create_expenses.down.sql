@@ -0,0 +1,8 @@
+DELETE FROM ledger_account_mappings WHERE key IN ('expenses', 'reimbursements_payable');
+DROP INDEX IF EXISTS idx_expense_categories_company_id;
+DROP INDEX IF EXISTS idx_expenses_company_id;
+DROP INDEX IF EXISTS idx_expenses_expense_date;
+DROP INDEX IF EXISTS idx_expenses_status;
+DROP INDEX IF EXISTS idx_expenses_employee;
+DROP TABLE IF EXISTS expenses;
+DROP TABLE IF EXISTS expense_categories;
@@ -0,0 +1,59 @@
+-- Despesas dos colaboradores: lançadas com comprovante (anexos do tipo "expense"), aprovadas
+-- pelos gestores e reembolsadas pelo financeiro. As categorias agrupam os relatórios e podem
+-- apontar a conta contábil da despesa.
+CREATE TABLE IF NOT EXISTS expense_categories (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    ledger_account_id INTEGER REFERENCES ledger_accounts(id),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_expense_categories_company_name UNIQUE (company_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS expenses (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    employee VARCHAR(100) NOT NULL,
+    category_id INTEGER NOT NULL REFERENCES expense_categories(id),
+    description VARCHAR(255) NOT NULL,
+    expense_date TIMESTAMP NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    cost_center_id INTEGER REFERENCES cost_centers(id),
+    notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    submitted_at TIMESTAMP,
+    approved_by VARCHAR(100),
+    approved_at TIMESTAMP,
+    rejected_by VARCHAR(100),
+    rejected_at TIMESTAMP,
+    rejection_reason TEXT,
+    reimbursed_by VARCHAR(100),
+    reimbursed_at TIMESTAMP,
+    reimbursement_reference VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_expenses_employee ON expenses(employee);
+CREATE INDEX IF NOT EXISTS idx_expenses_status ON expenses(status);
+CREATE INDEX IF NOT EXISTS idx_expenses_expense_date ON expenses(expense_date);
+CREATE INDEX IF NOT EXISTS idx_expenses_company_id ON expenses(company_id);
+CREATE INDEX IF NOT EXISTS idx_expense_categories_company_id ON expense_categories(company_id);
+
+-- Contas da contabilização das despesas: a despesa aprovada vai para "expenses" (ou para a conta
+-- da categoria) contra "reimbursements_payable", baixada no reembolso contra o caixa
+INSERT INTO ledger_accounts (company_id, code, name, type) VALUES
+    (1, '2.1.03', 'Reembolsos a Pagar', 'liability'),
+    (1, '5.2.01', 'Despesas com Colaboradores', 'expense')
+ON CONFLICT (company_id, code) DO NOTHING;
+
+INSERT INTO ledger_account_mappings (key, account_id)
+SELECT m.key, a.id
+FROM (VALUES
+    ('reimbursements_payable', '2.1.03'),
+    ('expenses', '5.2.01')
+) AS m(key, code)
+JOIN ledger_accounts a ON a.code = m.code AND a.company_id = 1
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,9 @@
+DELETE FROM ledger_account_mappings WHERE key IN ('fixed_assets', 'accumulated_depreciation', 'depreciation_expense', 'asset_disposal_gain', 'asset_disposal_loss');
+DROP INDEX IF EXISTS idx_asset_movements_asset_id;
+DROP INDEX IF EXISTS idx_asset_depreciations_company_id;
+DROP INDEX IF EXISTS idx_assets_purchase_order_item_id;
+DROP INDEX IF EXISTS idx_assets_status;
+DROP INDEX IF EXISTS idx_assets_company_id;
+DROP TABLE IF EXISTS asset_movements;
+DROP TABLE IF EXISTS asset_depreciations;
+DROP TABLE IF EXISTS assets;
@@ -0,0 +1,89 @@
+-- Ativo imobilizado: bens patrimoniais (opcionalmente adquiridos por um item de pedido de
+-- compra), movimentações de local e de responsável e a depreciação linear de cada mês
+CREATE TABLE IF NOT EXISTS assets (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    asset_no VARCHAR(30) NOT NULL,
+    name VARCHAR(150) NOT NULL,
+    description TEXT,
+    category VARCHAR(60),
+    purchase_order_id INTEGER REFERENCES purchase_orders(id),
+    purchase_order_item_id INTEGER REFERENCES purchase_order_items(id),
+    acquisition_date TIMESTAMP NOT NULL,
+    acquisition_cost DECIMAL(14, 2) NOT NULL CHECK (acquisition_cost > 0),
+    residual_value DECIMAL(14, 2) NOT NULL DEFAULT 0 CHECK (residual_value >= 0),
+    useful_life_months INTEGER NOT NULL CHECK (useful_life_months > 0),
+    accumulated_depreciation DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    depreciated_through VARCHAR(7) NOT NULL DEFAULT '',
+    location VARCHAR(100),
+    custodian VARCHAR(100),
+    cost_center_id INTEGER REFERENCES cost_centers(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    disposal_type VARCHAR(20),
+    disposed_at TIMESTAMP,
+    disposed_by VARCHAR(100),
+    disposal_proceeds DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    disposal_gain_loss DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    disposal_reason TEXT,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_assets_company_asset_no UNIQUE (company_id, asset_no),
+    CONSTRAINT chk_assets_residual_value CHECK (residual_value < acquisition_cost)
+);
+
+CREATE TABLE IF NOT EXISTS asset_depreciations (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    asset_id INTEGER NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    period VARCHAR(7) NOT NULL,
+    entry_date TIMESTAMP NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    accumulated_depreciation DECIMAL(14, 2) NOT NULL,
+    book_value DECIMAL(14, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_asset_depreciations_asset_period UNIQUE (asset_id, period)
+);
+
+CREATE TABLE IF NOT EXISTS asset_movements (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    asset_id INTEGER NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    from_location VARCHAR(100),
+    to_location VARCHAR(100),
+    from_custodian VARCHAR(100),
+    to_custodian VARCHAR(100),
+    notes TEXT,
+    moved_by VARCHAR(100),
+    moved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_assets_company_id ON assets(company_id);
+CREATE INDEX IF NOT EXISTS idx_assets_status ON assets(status);
+CREATE INDEX IF NOT EXISTS idx_assets_purchase_order_item_id ON assets(purchase_order_item_id);
+CREATE INDEX IF NOT EXISTS idx_asset_depreciations_company_id ON asset_depreciations(company_id);
+CREATE INDEX IF NOT EXISTS idx_asset_movements_asset_id ON asset_movements(asset_id);
+
+-- Contas do imobilizado: a capitalização transfere o custo de "purchases" para "fixed_assets",
+-- a depreciação mensal vai para "depreciation_expense" contra "accumulated_depreciation" e a
+-- baixa apura o resultado em "asset_disposal_gain" ou "asset_disposal_loss". O ganho na baixa
+-- é apresentado na DRE entre as despesas operacionais, reduzindo-as.
+INSERT INTO ledger_accounts (company_id, code, name, type, dre_group) VALUES
+    (1, '1.2.01', 'Imobilizado', 'asset', NULL),
+    (1, '1.2.02', '(-) Depreciação Acumulada', 'asset', NULL),
+    (1, '4.3.01', 'Ganho na Baixa de Imobilizado', 'revenue', 'operating_expenses'),
+    (1, '5.3.01', 'Despesa de Depreciação', 'expense', NULL),
+    (1, '5.3.02', 'Perda na Baixa de Imobilizado', 'expense', NULL)
+ON CONFLICT (company_id, code) DO NOTHING;
+
+INSERT INTO ledger_account_mappings (key, account_id)
+SELECT m.key, a.id
+FROM (VALUES
+    ('fixed_assets', '1.2.01'),
+    ('accumulated_depreciation', '1.2.02'),
+    ('asset_disposal_gain', '4.3.01'),
+    ('depreciation_expense', '5.3.01'),
+    ('asset_disposal_loss', '5.3.02')
+) AS m(key, code)
+JOIN ledger_accounts a ON a.code = m.code AND a.company_id = 1
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,10 @@
+DELETE FROM ledger_account_mappings WHERE key IN ('labor_costs', 'payroll_payable');
+DROP INDEX IF EXISTS idx_deliveries_driver_id;
+DROP INDEX IF EXISTS idx_labor_cost_allocations_company_period;
+DROP INDEX IF EXISTS idx_employees_department_id;
+DROP INDEX IF EXISTS idx_employees_company_id;
+ALTER TABLE deliveries DROP COLUMN IF EXISTS driver_id;
+DROP TABLE IF EXISTS labor_cost_allocations;
+DROP TABLE IF EXISTS employee_cost_splits;
+DROP TABLE IF EXISTS employees;
+DROP TABLE IF EXISTS departments;
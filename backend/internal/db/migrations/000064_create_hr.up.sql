@@ -0,0 +1,77 @@
+-- Colaboradores e departamentos, à parte dos usuários do sistema: o colaborador pode estar
+-- ligado a um usuário (vendedor, técnico) e tem o custo mensal rateado entre centros de custo.
+-- O rateio de cada mês é gravado por colaborador e centro de custo e contabilizado pelo razão.
+CREATE TABLE IF NOT EXISTS departments (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    code VARCHAR(30) NOT NULL,
+    name VARCHAR(150) NOT NULL,
+    cost_center_id INTEGER REFERENCES cost_centers(id),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_departments_company_code UNIQUE (company_id, code)
+);
+
+CREATE TABLE IF NOT EXISTS employees (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(150) NOT NULL,
+    email VARCHAR(150),
+    document VARCHAR(20),
+    position VARCHAR(100),
+    department_id INTEGER REFERENCES departments(id),
+    user_id INTEGER REFERENCES users(id),
+    monthly_cost DECIMAL(14, 2) NOT NULL DEFAULT 0 CHECK (monthly_cost >= 0),
+    hire_date TIMESTAMP NOT NULL,
+    termination_date TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_employees_company_user UNIQUE (company_id, user_id),
+    CONSTRAINT chk_employees_termination CHECK (termination_date IS NULL OR termination_date >= hire_date)
+);
+
+CREATE TABLE IF NOT EXISTS employee_cost_splits (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    employee_id INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    cost_center_id INTEGER NOT NULL REFERENCES cost_centers(id),
+    percent DECIMAL(5, 2) NOT NULL CHECK (percent > 0 AND percent <= 100),
+    CONSTRAINT uq_employee_cost_splits_center UNIQUE (employee_id, cost_center_id)
+);
+
+CREATE TABLE IF NOT EXISTS labor_cost_allocations (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    period VARCHAR(7) NOT NULL,
+    employee_id INTEGER NOT NULL REFERENCES employees(id),
+    department_id INTEGER REFERENCES departments(id),
+    cost_center_id INTEGER REFERENCES cost_centers(id),
+    days INTEGER NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Motorista da entrega; vendedores e técnicos continuam ligados pelo usuário do colaborador
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS driver_id INTEGER REFERENCES employees(id);
+
+CREATE INDEX IF NOT EXISTS idx_employees_company_id ON employees(company_id);
+CREATE INDEX IF NOT EXISTS idx_employees_department_id ON employees(department_id);
+CREATE INDEX IF NOT EXISTS idx_labor_cost_allocations_company_period ON labor_cost_allocations(company_id, period);
+CREATE INDEX IF NOT EXISTS idx_deliveries_driver_id ON deliveries(driver_id);
+
+-- Contas da mão de obra: o custo rateado vai para "labor_costs", no centro de custo da linha,
+-- contra "payroll_payable"
+INSERT INTO ledger_accounts (company_id, code, name, type) VALUES
+    (1, '2.1.04', 'Salários e Encargos a Pagar', 'liability'),
+    (1, '5.4.01', 'Salários e Encargos', 'expense')
+ON CONFLICT (company_id, code) DO NOTHING;
+
+INSERT INTO ledger_account_mappings (key, account_id)
+SELECT m.key, a.id
+FROM (VALUES
+    ('payroll_payable', '2.1.04'),
+    ('labor_costs', '5.4.01')
+) AS m(key, code)
+JOIN ledger_accounts a ON a.code = m.code AND a.company_id = 1
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_supplier_bills_match_status;
+DROP INDEX IF EXISTS idx_supplier_bill_match_lines_bill_id;
+DROP INDEX IF EXISTS idx_supplier_bill_items_po_item_id;
+DROP INDEX IF EXISTS idx_supplier_bill_items_bill_id;
+DROP TABLE IF EXISTS supplier_bill_match_lines;
+DROP TABLE IF EXISTS supplier_bill_items;
+ALTER TABLE supplier_bills DROP COLUMN IF EXISTS match_notes;
+ALTER TABLE supplier_bills DROP COLUMN IF EXISTS match_reviewed_at;
+ALTER TABLE supplier_bills DROP COLUMN IF EXISTS match_reviewed_by;
+ALTER TABLE supplier_bills DROP COLUMN IF EXISTS matched_at;
+ALTER TABLE supplier_bills DROP COLUMN IF EXISTS match_status;
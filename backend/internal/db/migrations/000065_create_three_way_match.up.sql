@@ -0,0 +1,45 @@
+-- Conferência de três vias das contas a pagar ligadas a pedido de compra: os itens cobrados na
+-- conta (da NF-e) são conferidos com o pedido (preço) e com o recebido nas entregas
+-- (quantidade). Divergências acima da tolerância aguardam a revisão do comprador, e a conta só é
+-- aprovada e paga depois de conferida ou com as divergências aceitas. As contas existentes ficam
+-- com a conferência dispensada (not_required).
+ALTER TABLE supplier_bills ADD COLUMN IF NOT EXISTS match_status VARCHAR(20) NOT NULL DEFAULT 'not_required';
+ALTER TABLE supplier_bills ADD COLUMN IF NOT EXISTS matched_at TIMESTAMP;
+ALTER TABLE supplier_bills ADD COLUMN IF NOT EXISTS match_reviewed_by VARCHAR(100);
+ALTER TABLE supplier_bills ADD COLUMN IF NOT EXISTS match_reviewed_at TIMESTAMP;
+ALTER TABLE supplier_bills ADD COLUMN IF NOT EXISTS match_notes TEXT;
+
+CREATE TABLE IF NOT EXISTS supplier_bill_items (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    supplier_bill_id INTEGER NOT NULL REFERENCES supplier_bills(id) ON DELETE CASCADE,
+    po_item_id INTEGER REFERENCES purchase_order_items(id) ON DELETE SET NULL,
+    product_code VARCHAR(60),
+    description VARCHAR(255),
+    unit VARCHAR(10),
+    quantity DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    unit_price DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    total DECIMAL(14, 2) NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS supplier_bill_match_lines (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    supplier_bill_id INTEGER NOT NULL REFERENCES supplier_bills(id) ON DELETE CASCADE,
+    po_item_id INTEGER REFERENCES purchase_order_items(id) ON DELETE SET NULL,
+    bill_item_id INTEGER REFERENCES supplier_bill_items(id) ON DELETE SET NULL,
+    product_code VARCHAR(60),
+    description VARCHAR(255),
+    ordered_qty DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    received_qty DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    billed_qty DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    po_unit_price DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    billed_unit_price DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    message TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_bill_items_bill_id ON supplier_bill_items(supplier_bill_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_bill_items_po_item_id ON supplier_bill_items(po_item_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_bill_match_lines_bill_id ON supplier_bill_match_lines(supplier_bill_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_bills_match_status ON supplier_bills(company_id, match_status);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_followup_log_process;
+DROP INDEX IF EXISTS idx_followup_log_rule_process_idle;
+DROP INDEX IF EXISTS idx_followup_tasks_process;
+DROP INDEX IF EXISTS idx_followup_tasks_assigned_status;
+DROP INDEX IF EXISTS idx_followup_rules_company_active;
+DROP TABLE IF EXISTS followup_log;
+DROP TABLE IF EXISTS followup_tasks;
+DROP TABLE IF EXISTS followup_rules;
@@ -0,0 +1,54 @@
+-- Acompanhamento dos processos de venda parados: regras que, para os processos sem atualização
+-- há mais de N dias, criam tarefas de retorno para o vendedor, enviam lembretes por e-mail ou
+-- cancelam o processo (prazo máximo). Cada ação fica registrada em followup_log, uma vez por
+-- regra e período parado (idle_since é a última atualização do processo quando a regra agiu).
+CREATE TABLE IF NOT EXISTS followup_rules (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    idle_days INTEGER NOT NULL CHECK (idle_days > 0),
+    action VARCHAR(20) NOT NULL,
+    stages JSONB NOT NULL DEFAULT '[]',
+    task_due_days INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS followup_tasks (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    rule_id INTEGER REFERENCES followup_rules(id) ON DELETE SET NULL,
+    sales_process_id INTEGER NOT NULL REFERENCES sales_processes(id) ON DELETE CASCADE,
+    contact_id INTEGER NOT NULL,
+    assigned_to INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    due_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    notes TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    completed_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS followup_log (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    rule_id INTEGER NOT NULL,
+    sales_process_id INTEGER NOT NULL REFERENCES sales_processes(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    idle_since TIMESTAMP NOT NULL,
+    idle_days INTEGER NOT NULL,
+    task_id INTEGER REFERENCES followup_tasks(id) ON DELETE SET NULL,
+    recipient VARCHAR(255),
+    message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_followup_rules_company_active ON followup_rules(company_id, active);
+CREATE INDEX IF NOT EXISTS idx_followup_tasks_assigned_status ON followup_tasks(assigned_to, status);
+CREATE INDEX IF NOT EXISTS idx_followup_tasks_process ON followup_tasks(sales_process_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_followup_log_rule_process_idle ON followup_log(rule_id, sales_process_id, idle_since);
+CREATE INDEX IF NOT EXISTS idx_followup_log_process ON followup_log(sales_process_id);
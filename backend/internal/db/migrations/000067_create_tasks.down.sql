@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS followup_tasks (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    rule_id INTEGER REFERENCES followup_rules(id) ON DELETE SET NULL,
+    sales_process_id INTEGER NOT NULL REFERENCES sales_processes(id) ON DELETE CASCADE,
+    contact_id INTEGER NOT NULL,
+    assigned_to INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    due_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    notes TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    completed_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_followup_tasks_assigned_status ON followup_tasks(assigned_to, status);
+CREATE INDEX IF NOT EXISTS idx_followup_tasks_process ON followup_tasks(sales_process_id);
+
+INSERT INTO followup_tasks (id, company_id, sales_process_id, contact_id, assigned_to, title, due_date, status,
+                            notes, completed_at, completed_by, created_at, updated_at)
+SELECT t.id, t.company_id, t.entity_id, p.contact_id, t.assigned_to, t.title, COALESCE(t.due_date, t.created_at::date),
+       t.status, t.notes, t.completed_at, t.completed_by, t.created_at, t.updated_at
+FROM tasks t
+JOIN sales_processes p ON p.id = t.entity_id
+WHERE t.source = 'followup' AND t.entity_type = 'sales_process';
+
+SELECT setval(pg_get_serial_sequence('followup_tasks', 'id'), GREATEST((SELECT MAX(id) FROM followup_tasks), 1));
+
+ALTER TABLE followup_log DROP CONSTRAINT IF EXISTS followup_log_task_id_fkey;
+UPDATE followup_log SET task_id = NULL WHERE task_id NOT IN (SELECT id FROM followup_tasks);
+ALTER TABLE followup_log ADD CONSTRAINT followup_log_task_id_fkey FOREIGN KEY (task_id) REFERENCES followup_tasks(id) ON DELETE SET NULL;
+
+DROP INDEX IF EXISTS idx_tasks_open_source_key;
+DROP INDEX IF EXISTS idx_tasks_source;
+DROP INDEX IF EXISTS idx_tasks_entity;
+DROP INDEX IF EXISTS idx_tasks_assigned_status;
+DROP TABLE IF EXISTS tasks;
@@ -0,0 +1,48 @@
+-- Tarefas dos usuários (título, responsável, prazo), opcionalmente ligadas a um registro do ERP
+-- (entity_type e entity_id). As automações (acompanhamento dos processos parados, aprovações
+-- pendentes e cobrança das faturas vencidas) identificam as suas tarefas por source_key: há no
+-- máximo uma tarefa aberta por chave.
+CREATE TABLE IF NOT EXISTS tasks (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    assigned_to INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    due_date DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    entity_type VARCHAR(30),
+    entity_id INTEGER,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual',
+    source_key VARCHAR(100),
+    created_by VARCHAR(100),
+    notes TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    completed_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assigned_status ON tasks(assigned_to, status);
+CREATE INDEX IF NOT EXISTS idx_tasks_entity ON tasks(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_source ON tasks(source, status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_open_source_key ON tasks(company_id, source_key)
+    WHERE status = 'open' AND source_key IS NOT NULL;
+
+-- As tarefas de retorno do acompanhamento passam para a tabela geral, com os mesmos IDs
+INSERT INTO tasks (id, company_id, title, description, assigned_to, due_date, status, entity_type, entity_id,
+                   source, notes, completed_at, completed_by, created_at, updated_at)
+SELECT t.id, t.company_id, t.title, COALESCE('Criada pela regra de acompanhamento "' || r.name || '".', ''),
+       t.assigned_to, t.due_date, t.status, 'sales_process', t.sales_process_id, 'followup',
+       t.notes, t.completed_at, t.completed_by, t.created_at, t.updated_at
+FROM followup_tasks t
+LEFT JOIN followup_rules r ON r.id = t.rule_id
+WHERE NOT EXISTS (SELECT 1 FROM tasks x WHERE x.id = t.id);
+
+SELECT setval(pg_get_serial_sequence('tasks', 'id'), GREATEST((SELECT MAX(id) FROM tasks), 1));
+
+ALTER TABLE followup_log DROP CONSTRAINT IF EXISTS followup_log_task_id_fkey;
+ALTER TABLE followup_log ADD CONSTRAINT followup_log_task_id_fkey FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE SET NULL;
+
+DROP INDEX IF EXISTS idx_followup_tasks_process;
+DROP INDEX IF EXISTS idx_followup_tasks_assigned_status;
+DROP TABLE IF EXISTS followup_tasks;
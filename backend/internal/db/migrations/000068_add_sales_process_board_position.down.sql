@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sales_processes_board;
+ALTER TABLE sales_processes DROP COLUMN IF EXISTS board_position;
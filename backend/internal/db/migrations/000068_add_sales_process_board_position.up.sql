@@ -0,0 +1,13 @@
+-- Posição do processo de venda na coluna da sua etapa no quadro (1 é o topo). Os processos que
+-- chegam a uma etapa ficam com 0, no topo da coluna, até serem movidos no quadro.
+ALTER TABLE sales_processes ADD COLUMN IF NOT EXISTS board_position INTEGER NOT NULL DEFAULT 0;
+
+-- Os processos existentes entram nas colunas do mais recente para o mais antigo
+UPDATE sales_processes p SET board_position = ranked.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY company_id, status ORDER BY updated_at DESC, id DESC) AS position
+    FROM sales_processes
+) ranked
+WHERE p.id = ranked.id;
+
+CREATE INDEX IF NOT EXISTS idx_sales_processes_board ON sales_processes(company_id, status, board_position, id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_tenant_exports_status;
+DROP INDEX IF EXISTS idx_tenant_exports_company;
+DROP TABLE IF EXISTS tenant_exports;
+
+DROP INDEX IF EXISTS idx_backup_jobs_kind;
+DROP INDEX IF EXISTS idx_backup_jobs_status;
+DROP TABLE IF EXISTS backup_jobs;
@@ -0,0 +1,45 @@
+-- Fila dos backups lógicos do banco (pg_dump) e das restaurações (pg_restore), agendados ou
+-- pedidos pelos administradores. Os backups abrangem todas as empresas; size_bytes e checksum
+-- (SHA-256) são gravados ao concluir e conferidos antes de restaurar.
+CREATE TABLE IF NOT EXISTS backup_jobs (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL DEFAULT 'backup',
+    trigger VARCHAR(20) NOT NULL DEFAULT 'manual',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    backup_id INTEGER REFERENCES backup_jobs(id) ON DELETE SET NULL,
+    file_name VARCHAR(255),
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64),
+    error TEXT,
+    requested_by VARCHAR(100),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    expired_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_backup_jobs_status ON backup_jobs(status, id);
+CREATE INDEX IF NOT EXISTS idx_backup_jobs_kind ON backup_jobs(kind, created_at);
+
+-- Exportações completas dos dados de uma empresa (JSON ou SQL) para o encerramento da conta,
+-- com o andamento por tabela
+CREATE TABLE IF NOT EXISTS tenant_exports (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    format VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    tables_total INTEGER NOT NULL DEFAULT 0,
+    tables_done INTEGER NOT NULL DEFAULT 0,
+    rows_exported BIGINT NOT NULL DEFAULT 0,
+    file_name VARCHAR(255),
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64),
+    error TEXT,
+    requested_by VARCHAR(100),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_exports_company ON tenant_exports(company_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_tenant_exports_status ON tenant_exports(status, id);
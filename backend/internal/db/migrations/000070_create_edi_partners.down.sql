@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_edi_transmissions_document;
+DROP INDEX IF EXISTS idx_edi_transmissions_partner;
+DROP TABLE IF EXISTS edi_transmissions;
+
+DROP INDEX IF EXISTS idx_edi_partners_company_id;
+DROP TABLE IF EXISTS edi_partners;
@@ -0,0 +1,38 @@
+-- Parceiros B2B que recebem faturas e entregas em UBL 2.1 no próprio servidor SFTP. A senha e
+-- a chave privada só são usadas nas conexões feitas pelo servidor; host_key é a chave pública
+-- do servidor do parceiro, conferida em toda conexão. Com contact_id, o parceiro só recebe os
+-- documentos desse cliente.
+CREATE TABLE IF NOT EXISTS edi_partners (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    contact_id INTEGER REFERENCES contacts(id) ON DELETE SET NULL,
+    host VARCHAR(255) NOT NULL,
+    port INTEGER NOT NULL DEFAULT 22,
+    username VARCHAR(100) NOT NULL,
+    password TEXT,
+    private_key TEXT,
+    host_key TEXT NOT NULL,
+    remote_dir VARCHAR(255) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_edi_partners_company_id ON edi_partners(company_id);
+
+-- Histórico dos envios de documentos aos parceiros, com o erro dos que falharam
+CREATE TABLE IF NOT EXISTS edi_transmissions (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    partner_id INTEGER NOT NULL REFERENCES edi_partners(id) ON DELETE CASCADE,
+    document_type VARCHAR(20) NOT NULL CHECK (document_type IN ('invoice', 'delivery')),
+    document_id INTEGER NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    size_bytes INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    sent_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_edi_transmissions_partner ON edi_transmissions(partner_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_edi_transmissions_document ON edi_transmissions(document_type, document_id);
@@ -0,0 +1,14 @@
+DELETE FROM bank_statements WHERE format = 'cnab240';
+ALTER TABLE bank_statements DROP CONSTRAINT IF EXISTS valid_bank_statement_format;
+ALTER TABLE bank_statements ADD CONSTRAINT valid_bank_statement_format CHECK (format IN ('ofx', 'csv'));
+
+DROP INDEX IF EXISTS idx_file_exchange_runs_schedule;
+DROP INDEX IF EXISTS idx_file_exchange_runs_queue;
+DROP TABLE IF EXISTS file_exchange_runs;
+
+DROP INDEX IF EXISTS idx_file_exchange_schedules_due;
+DROP INDEX IF EXISTS idx_file_exchange_schedules_company_id;
+DROP TABLE IF EXISTS file_exchange_schedules;
+
+DROP INDEX IF EXISTS idx_file_exchange_endpoints_company_id;
+DROP TABLE IF EXISTS file_exchange_endpoints;
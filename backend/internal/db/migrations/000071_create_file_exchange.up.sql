@@ -0,0 +1,77 @@
+-- Servidores SFTP/FTP dos parceiros para a troca periódica de arquivos. Senha e chave privada
+-- ficam cifradas pelo cofre da aplicação (VAULT_KEY); host_key é a chave pública do servidor
+-- SFTP, conferida em toda conexão. Os arquivos coletados em inbound_dir são movidos para
+-- archive_dir depois de importados.
+CREATE TABLE IF NOT EXISTS file_exchange_endpoints (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    protocol VARCHAR(10) NOT NULL CHECK (protocol IN ('sftp', 'ftp')),
+    host VARCHAR(255) NOT NULL,
+    port INTEGER NOT NULL,
+    username VARCHAR(100) NOT NULL,
+    password_sealed TEXT NOT NULL DEFAULT '',
+    private_key_sealed TEXT NOT NULL DEFAULT '',
+    host_key TEXT NOT NULL DEFAULT '',
+    inbound_dir VARCHAR(255) NOT NULL DEFAULT '',
+    outbound_dir VARCHAR(255) NOT NULL DEFAULT '',
+    archive_dir VARCHAR(255) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_file_exchange_endpoints_company_id ON file_exchange_endpoints(company_id);
+
+-- Rotinas periódicas de envio (edi_invoices, price_list) e coleta (sales_orders, cnab_return)
+CREATE TABLE IF NOT EXISTS file_exchange_schedules (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    endpoint_id INTEGER NOT NULL REFERENCES file_exchange_endpoints(id),
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('edi_invoices', 'price_list', 'sales_orders', 'cnab_return')),
+    file_pattern VARCHAR(100) NOT NULL DEFAULT '',
+    contact_id INTEGER REFERENCES contacts(id) ON DELETE SET NULL,
+    mapping_id INTEGER REFERENCES etl_mappings(id),
+    bank_account VARCHAR(50) NOT NULL DEFAULT '',
+    interval_minutes INTEGER NOT NULL CHECK (interval_minutes > 0),
+    max_attempts INTEGER NOT NULL DEFAULT 3 CHECK (max_attempts > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_success_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_file_exchange_schedules_company_id ON file_exchange_schedules(company_id);
+CREATE INDEX IF NOT EXISTS idx_file_exchange_schedules_due ON file_exchange_schedules(next_run_at) WHERE active;
+
+-- Fila das execuções: as com falha voltam para queued com next_attempt_at crescente até
+-- esgotar max_attempts
+CREATE TABLE IF NOT EXISTS file_exchange_runs (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    schedule_id INTEGER NOT NULL REFERENCES file_exchange_schedules(id) ON DELETE CASCADE,
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    since TIMESTAMP WITH TIME ZONE,
+    until TIMESTAMP WITH TIME ZONE NOT NULL,
+    file_count INTEGER NOT NULL DEFAULT 0,
+    log TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    requested_by VARCHAR(100),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_file_exchange_runs_queue ON file_exchange_runs(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_file_exchange_runs_schedule ON file_exchange_runs(schedule_id, created_at);
+
+-- Retornos de cobrança CNAB 240 importados como extrato bancário
+ALTER TABLE bank_statements DROP CONSTRAINT IF EXISTS valid_bank_statement_format;
+ALTER TABLE bank_statements ADD CONSTRAINT valid_bank_statement_format CHECK (format IN ('ofx', 'csv', 'cnab240'));
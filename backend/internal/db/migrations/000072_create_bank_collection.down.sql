@@ -0,0 +1,12 @@
+DELETE FROM ledger_account_mappings WHERE key = 'bank_fees';
+
+DROP INDEX IF EXISTS idx_bank_return_items_return_id;
+DROP INDEX IF EXISTS idx_bank_returns_company_id;
+DROP TABLE IF EXISTS bank_return_items;
+DROP TABLE IF EXISTS bank_returns;
+
+DROP INDEX IF EXISTS idx_bank_slips_our_number;
+DROP INDEX IF EXISTS idx_bank_slips_installment_id;
+DROP INDEX IF EXISTS idx_bank_slips_remittance_id;
+DROP TABLE IF EXISTS bank_slips;
+DROP TABLE IF EXISTS bank_remittances;
@@ -0,0 +1,95 @@
+-- Cobrança registrada: cada remessa CNAB 240 gerada (sequence é o NSA do arquivo na empresa) e
+-- os boletos enviados nela, com o status e o último retorno do banco. Uma parcela só volta a
+-- uma remessa depois de recusada ou baixada sem pagamento.
+CREATE TABLE IF NOT EXISTS bank_remittances (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    file_name VARCHAR(50) NOT NULL,
+    bank_code VARCHAR(3) NOT NULL,
+    slip_count INTEGER NOT NULL DEFAULT 0,
+    total_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    content TEXT NOT NULL,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_bank_remittances_company_sequence UNIQUE (company_id, sequence)
+);
+
+CREATE TABLE IF NOT EXISTS bank_slips (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    remittance_id INTEGER NOT NULL REFERENCES bank_remittances(id) ON DELETE CASCADE,
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+    installment_id INTEGER NOT NULL REFERENCES invoice_installments(id) ON DELETE CASCADE,
+    our_number VARCHAR(20) NOT NULL,
+    document_no VARCHAR(30) NOT NULL DEFAULT '',
+    due_date TIMESTAMP NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'remitted'
+        CHECK (status IN ('remitted', 'registered', 'rejected', 'paid', 'written_off')),
+    paid_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    fee_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    paid_at TIMESTAMP,
+    payment_id INTEGER REFERENCES payments(id) ON DELETE SET NULL,
+    last_occurrence VARCHAR(2) NOT NULL DEFAULT '',
+    last_message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_slips_remittance_id ON bank_slips(remittance_id);
+CREATE INDEX IF NOT EXISTS idx_bank_slips_installment_id ON bank_slips(installment_id);
+CREATE INDEX IF NOT EXISTS idx_bank_slips_our_number ON bank_slips(company_id, (LTRIM(our_number, '0')));
+
+-- Retornos processados, com o resultado de cada título; file_hash (sha256 do conteúdo) impede
+-- processar o mesmo arquivo duas vezes
+CREATE TABLE IF NOT EXISTS bank_returns (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    file_hash CHAR(64) NOT NULL,
+    record_count INTEGER NOT NULL DEFAULT 0,
+    paid_count INTEGER NOT NULL DEFAULT 0,
+    paid_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    fee_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    rejected_count INTEGER NOT NULL DEFAULT 0,
+    error_count INTEGER NOT NULL DEFAULT 0,
+    processed_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_bank_returns_company_hash UNIQUE (company_id, file_hash)
+);
+
+CREATE TABLE IF NOT EXISTS bank_return_items (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    return_id INTEGER NOT NULL REFERENCES bank_returns(id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    occurrence VARCHAR(2) NOT NULL,
+    occurrence_description VARCHAR(100) NOT NULL DEFAULT '',
+    our_number VARCHAR(20) NOT NULL DEFAULT '',
+    document_no VARCHAR(30) NOT NULL DEFAULT '',
+    slip_id INTEGER REFERENCES bank_slips(id) ON DELETE SET NULL,
+    invoice_id INTEGER REFERENCES invoices(id) ON DELETE SET NULL,
+    payment_id INTEGER REFERENCES payments(id) ON DELETE SET NULL,
+    amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    paid_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    fee_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    occurred_at TIMESTAMP,
+    result VARCHAR(20) NOT NULL
+        CHECK (result IN ('paid', 'registered', 'rejected', 'written_off', 'fee', 'ignored', 'error')),
+    message TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_returns_company_id ON bank_returns(company_id);
+CREATE INDEX IF NOT EXISTS idx_bank_return_items_return_id ON bank_return_items(return_id);
+
+-- Tarifas de cobrança: cada título do retorno com tarifa é lançado em "bank_fees" contra "cash"
+INSERT INTO ledger_accounts (company_id, code, name, type, dre_group) VALUES
+    (1, '5.5.01', 'Despesas Bancárias', 'expense', 'financial_result')
+ON CONFLICT (company_id, code) DO NOTHING;
+
+INSERT INTO ledger_account_mappings (key, account_id)
+SELECT 'bank_fees', a.id
+FROM ledger_accounts a
+WHERE a.code = '5.5.01' AND a.company_id = 1
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,9 @@
+ALTER TABLE bank_remittances DROP COLUMN IF EXISTS instruction_count;
+
+DROP INDEX IF EXISTS idx_boleto_instructions_pending;
+DROP INDEX IF EXISTS idx_boleto_instructions_boleto_id;
+DROP TABLE IF EXISTS boleto_instructions;
+
+DROP INDEX IF EXISTS idx_boletos_installment_id;
+DROP INDEX IF EXISTS idx_boletos_invoice_id;
+DROP TABLE IF EXISTS boletos;
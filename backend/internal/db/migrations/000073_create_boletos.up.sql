@@ -0,0 +1,52 @@
+-- Boletos registrados das parcelas de fatura, por provedor (cnab: cobrança por arquivo, pendente
+-- até o retorno confirmar a entrada; inter: API do banco). Uma parcela só recebe outro boleto
+-- depois que o anterior é cancelado.
+CREATE TABLE IF NOT EXISTS boletos (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+    installment_id INTEGER NOT NULL REFERENCES invoice_installments(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    external_id VARCHAR(60) NOT NULL DEFAULT '',
+    our_number VARCHAR(20) NOT NULL,
+    barcode CHAR(44) NOT NULL,
+    digitable_line VARCHAR(60) NOT NULL,
+    document_no VARCHAR(30) NOT NULL DEFAULT '',
+    issue_date TIMESTAMP NOT NULL,
+    due_date TIMESTAMP NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    discount_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    discount_until TIMESTAMP,
+    protest_days INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'registered', 'cancelled')),
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_boletos_invoice_id ON boletos(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_boletos_installment_id ON boletos(installment_id);
+
+-- Instruções enviadas sobre os boletos; as da cobrança por arquivo ficam pendentes até a remessa
+-- que as leva ao banco
+CREATE TABLE IF NOT EXISTS boleto_instructions (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    boleto_id INTEGER NOT NULL REFERENCES boletos(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('discount', 'protest', 'cancel')),
+    discount_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    discount_until TIMESTAMP,
+    protest_days INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent')),
+    remittance_id INTEGER REFERENCES bank_remittances(id) ON DELETE SET NULL,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_boleto_instructions_boleto_id ON boleto_instructions(boleto_id);
+CREATE INDEX IF NOT EXISTS idx_boleto_instructions_pending ON boleto_instructions(company_id) WHERE status = 'pending';
+
+ALTER TABLE bank_remittances ADD COLUMN IF NOT EXISTS instruction_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pix_charges_end_to_end_id;
+DROP INDEX IF EXISTS idx_pix_charges_invoice_id;
+DROP TABLE IF EXISTS pix_charges;
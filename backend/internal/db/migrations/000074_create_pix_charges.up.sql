@@ -0,0 +1,25 @@
+-- Cobranças Pix dinâmicas do saldo em aberto das faturas, criadas no PSP pela API Pix do Banco
+-- Central. O webhook do PSP marca a cobrança paga com o endToEndId do Pix recebido, que não se
+-- repete: uma notificação reenviada não registra o pagamento duas vezes.
+CREATE TABLE IF NOT EXISTS pix_charges (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+    provider VARCHAR(20) NOT NULL,
+    txid VARCHAR(35) NOT NULL UNIQUE,
+    location VARCHAR(77) NOT NULL DEFAULT '',
+    payload TEXT NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL CHECK (amount > 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paid')),
+    paid_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    end_to_end_id VARCHAR(35) NOT NULL DEFAULT '',
+    payment_id INTEGER REFERENCES payments(id) ON DELETE SET NULL,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pix_charges_invoice_id ON pix_charges(invoice_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pix_charges_end_to_end_id ON pix_charges(end_to_end_id) WHERE end_to_end_id <> '';
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_product_images_product_id;
+DROP TABLE IF EXISTS product_images;
+
+DROP INDEX IF EXISTS idx_products_published;
+DROP INDEX IF EXISTS idx_products_category_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS published_at;
+ALTER TABLE products DROP COLUMN IF EXISTS featured;
+ALTER TABLE products DROP COLUMN IF EXISTS published;
+ALTER TABLE products DROP COLUMN IF EXISTS rich_description;
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
+
+DROP INDEX IF EXISTS idx_product_categories_parent_id;
+DROP TABLE IF EXISTS product_categories;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_products_replacement_product_id;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_replacement_not_self;
+ALTER TABLE products DROP COLUMN IF EXISTS replacement_product_id;
+
+UPDATE products SET status = 'descontinuado' WHERE status = 'fim_de_vida';
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_status_check;
+ALTER TABLE products ADD CONSTRAINT products_status_check
+    CHECK (status IN ('ativo', 'desativado', 'descontinuado'));
@@ -0,0 +1,10 @@
+-- Ciclo de vida dos produtos: fim_de_vida se junta aos estados existentes, e o produto
+-- descontinuado ou em fim de vida pode apontar o substituto sugerido nas novas cotações.
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_status_check;
+ALTER TABLE products ADD CONSTRAINT products_status_check
+    CHECK (status IN ('ativo', 'desativado', 'descontinuado', 'fim_de_vida'));
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS replacement_product_id INTEGER REFERENCES products(id) ON DELETE SET NULL;
+ALTER TABLE products ADD CONSTRAINT products_replacement_not_self CHECK (replacement_product_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_products_replacement_product_id ON products(replacement_product_id);
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_stock_movements_transfer;
+DROP INDEX IF EXISTS idx_stock_movements_product;
+DROP INDEX IF EXISTS idx_stock_movements_warehouse;
+DROP TABLE IF EXISTS stock_movements;
+
+DROP INDEX IF EXISTS idx_stock_transfer_items_transfer;
+DROP TABLE IF EXISTS stock_transfer_items;
+
+DROP INDEX IF EXISTS idx_stock_transfers_status;
+DROP INDEX IF EXISTS idx_stock_transfers_destination;
+DROP INDEX IF EXISTS idx_stock_transfers_source;
+DROP TABLE IF EXISTS stock_transfers;
+
+DROP TABLE IF EXISTS warehouse_stock;
+
+DROP INDEX IF EXISTS idx_warehouses_company_id;
+DROP TABLE IF EXISTS warehouses;
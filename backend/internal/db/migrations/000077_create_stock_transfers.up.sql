@@ -0,0 +1,80 @@
+-- Depósitos da empresa (matriz, filiais, centros de distribuição) com o saldo de cada produto por
+-- depósito. O contato do depósito é a filial como destinatária da nota interna de transferência.
+CREATE TABLE IF NOT EXISTS warehouses (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    address TEXT NOT NULL DEFAULT '',
+    contact_id INTEGER REFERENCES contacts(id) ON DELETE SET NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_warehouses_code UNIQUE (company_id, code)
+);
+CREATE INDEX IF NOT EXISTS idx_warehouses_company_id ON warehouses(company_id);
+
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (warehouse_id, product_id)
+);
+
+-- Transferências entre depósitos: solicitada, enviada (gera a entrega de saída, a de entrada e a
+-- nota interna opcional) e recebida
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    transfer_no VARCHAR(50) NOT NULL,
+    source_warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    destination_warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'shipped', 'received', 'cancelled')),
+    internal_invoice BOOLEAN NOT NULL DEFAULT FALSE,
+    outgoing_delivery_id INTEGER REFERENCES deliveries(id),
+    incoming_delivery_id INTEGER REFERENCES deliveries(id),
+    invoice_id INTEGER REFERENCES invoices(id),
+    notes TEXT NOT NULL DEFAULT '',
+    requested_by VARCHAR(100) NOT NULL DEFAULT '',
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    received_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_stock_transfers_no UNIQUE (company_id, transfer_no),
+    CONSTRAINT chk_stock_transfers_warehouses CHECK (source_warehouse_id <> destination_warehouse_id)
+);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_source ON stock_transfers(source_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_destination ON stock_transfers(destination_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON stock_transfers(company_id, status);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id SERIAL PRIMARY KEY,
+    stock_transfer_id INTEGER NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    product_name VARCHAR(255) NOT NULL DEFAULT '',
+    product_code VARCHAR(50) NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_transfer ON stock_transfer_items(stock_transfer_id);
+
+-- Histórico de movimentações de estoque por depósito, com o saldo após cada movimentação
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    movement_type VARCHAR(20) NOT NULL CHECK (movement_type IN ('adjustment', 'transfer_out', 'transfer_in')),
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    balance_after INTEGER NOT NULL,
+    stock_transfer_id INTEGER REFERENCES stock_transfers(id) ON DELETE SET NULL,
+    delivery_id INTEGER REFERENCES deliveries(id) ON DELETE SET NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_warehouse ON stock_movements(warehouse_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements(product_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_transfer ON stock_movements(stock_transfer_id);
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_stock_movements_stocktake;
+UPDATE stock_movements SET movement_type = 'adjustment' WHERE movement_type = 'stocktake';
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_movement_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_movement_type_check
+    CHECK (movement_type IN ('adjustment', 'transfer_out', 'transfer_in'));
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS stocktake_id;
+
+DROP INDEX IF EXISTS idx_stocktake_counts_line;
+DROP INDEX IF EXISTS idx_stocktake_counts_stocktake;
+DROP TABLE IF EXISTS stocktake_counts;
+
+DROP TABLE IF EXISTS stocktake_lines;
+
+DROP INDEX IF EXISTS idx_stocktakes_counting;
+DROP INDEX IF EXISTS idx_stocktakes_warehouse;
+DROP TABLE IF EXISTS stocktakes;
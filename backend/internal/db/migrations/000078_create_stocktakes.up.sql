@@ -0,0 +1,55 @@
+-- Inventários físicos por depósito: a abertura congela o saldo de cada produto (expected_qty), os
+-- contadores registram as quantidades (digitadas ou lidas pelo código de barras) e a aprovação
+-- lança no depósito a diferença entre o contado e o congelado
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    stocktake_no VARCHAR(50) NOT NULL,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'counting' CHECK (status IN ('counting', 'approved', 'cancelled')),
+    notes TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(100) NOT NULL DEFAULT '',
+    frozen_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    approved_by VARCHAR(100) NOT NULL DEFAULT '',
+    approved_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_stocktakes_no UNIQUE (company_id, stocktake_no)
+);
+CREATE INDEX IF NOT EXISTS idx_stocktakes_warehouse ON stocktakes(warehouse_id);
+-- Um inventário em contagem por depósito
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stocktakes_counting ON stocktakes(warehouse_id) WHERE status = 'counting';
+
+CREATE TABLE IF NOT EXISTS stocktake_lines (
+    id SERIAL PRIMARY KEY,
+    stocktake_id INTEGER NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    product_name VARCHAR(255) NOT NULL DEFAULT '',
+    product_code VARCHAR(50) NOT NULL DEFAULT '',
+    barcode VARCHAR(50) NOT NULL DEFAULT '',
+    bin_code VARCHAR(40) NOT NULL DEFAULT '',
+    expected_qty INTEGER NOT NULL DEFAULT 0,
+    unit_cost DECIMAL(14, 4) NOT NULL DEFAULT 0,
+    CONSTRAINT uq_stocktake_lines_product UNIQUE (stocktake_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS stocktake_counts (
+    id SERIAL PRIMARY KEY,
+    stocktake_id INTEGER NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+    line_id INTEGER NOT NULL REFERENCES stocktake_lines(id) ON DELETE CASCADE,
+    counter VARCHAR(100) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    source VARCHAR(10) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'scan')),
+    code VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_stocktake_counts_stocktake ON stocktake_counts(stocktake_id);
+CREATE INDEX IF NOT EXISTS idx_stocktake_counts_line ON stocktake_counts(line_id);
+
+-- Os ajustes aprovados entram no histórico do depósito ligados ao inventário
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS stocktake_id INTEGER REFERENCES stocktakes(id) ON DELETE SET NULL;
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_movement_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_movement_type_check
+    CHECK (movement_type IN ('adjustment', 'transfer_out', 'transfer_in', 'stocktake'));
+CREATE INDEX IF NOT EXISTS idx_stock_movements_stocktake ON stock_movements(stocktake_id);
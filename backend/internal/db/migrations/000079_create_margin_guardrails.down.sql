@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS invoice_margin_approvals;
+DROP TABLE IF EXISTS category_margin_thresholds;
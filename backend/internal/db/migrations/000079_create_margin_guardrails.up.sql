@@ -0,0 +1,24 @@
+-- Margem mínima por categoria de produto, conferida na contabilização das faturas. A categoria
+-- sem margem própria segue a da categoria mãe mais próxima que tiver.
+CREATE TABLE IF NOT EXISTS category_margin_thresholds (
+    category_id INTEGER PRIMARY KEY REFERENCES product_categories(id) ON DELETE CASCADE,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    min_margin_percent DECIMAL(6, 2) NOT NULL CHECK (min_margin_percent >= -100 AND min_margin_percent < 100),
+    updated_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_category_margin_thresholds_company_id ON category_margin_thresholds(company_id);
+
+-- Liberação da fatura com margem abaixo do mínimo, com quem aprovou e o motivo
+CREATE TABLE IF NOT EXISTS invoice_margin_approvals (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    approved_by VARCHAR(100) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    margin_percent DECIMAL(7, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_invoice_margin_approvals_invoice UNIQUE (invoice_id)
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_margin_approvals_company_id ON invoice_margin_approvals(company_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sales_targets;
+DROP TABLE IF EXISTS sales_team_members;
+DROP TABLE IF EXISTS sales_teams;
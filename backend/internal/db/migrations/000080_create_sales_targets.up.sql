@@ -0,0 +1,44 @@
+-- Equipes de vendas: agrupam vendedores para as metas de equipe
+CREATE TABLE IF NOT EXISTS sales_teams (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    leader_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_sales_teams_company_id ON sales_teams(company_id);
+
+CREATE TABLE IF NOT EXISTS sales_team_members (
+    id SERIAL PRIMARY KEY,
+    team_id INTEGER NOT NULL REFERENCES sales_teams(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_sales_team_members UNIQUE (team_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_sales_team_members_user_id ON sales_team_members(user_id);
+
+-- Metas de vendas mensais (AAAA-MM) ou trimestrais (AAAA-Qn) por vendedor, equipe ou linha de
+-- produto (products.product_group). Cada meta tem só a chave do seu escopo.
+CREATE TABLE IF NOT EXISTS sales_targets (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('salesperson', 'team', 'product_line')),
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    team_id INTEGER REFERENCES sales_teams(id) ON DELETE CASCADE,
+    product_line VARCHAR(100) NOT NULL DEFAULT '',
+    period VARCHAR(7) NOT NULL,
+    period_type VARCHAR(10) NOT NULL CHECK (period_type IN ('monthly', 'quarterly')),
+    target_amount DECIMAL(14, 2) NOT NULL CHECK (target_amount >= 0),
+    updated_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_sales_target_scope_key CHECK (
+        (scope = 'salesperson' AND user_id IS NOT NULL AND team_id IS NULL AND product_line = '') OR
+        (scope = 'team' AND team_id IS NOT NULL AND user_id IS NULL AND product_line = '') OR
+        (scope = 'product_line' AND product_line <> '' AND user_id IS NULL AND team_id IS NULL)
+    )
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sales_targets_scope_period
+    ON sales_targets(company_id, scope, COALESCE(user_id, 0), COALESCE(team_id, 0), product_line, period);
+CREATE INDEX IF NOT EXISTS idx_sales_targets_period ON sales_targets(company_id, period);
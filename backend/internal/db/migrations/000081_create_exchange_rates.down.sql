@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS invoice_revaluations;
+DROP TABLE IF EXISTS invoice_currencies;
+DROP TABLE IF EXISTS exchange_rates;
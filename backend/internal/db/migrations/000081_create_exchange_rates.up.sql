@@ -0,0 +1,48 @@
+-- Cotações diárias das moedas estrangeiras em reais (PTAX de venda, BCE ou informadas
+-- manualmente). Os dados são públicos e valem para todas as empresas; cada dia guarda uma
+-- cotação por moeda, e os dias sem cotação usam a do último dia anterior.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id SERIAL PRIMARY KEY,
+    currency CHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate DECIMAL(18, 8) NOT NULL CHECK (rate > 0),
+    source VARCHAR(20) NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_exchange_rates_currency_date UNIQUE (currency, rate_date)
+);
+
+-- Moeda estrangeira da fatura: o total em reais foi convertido pela cotação da data de emissão e
+-- foreign_amount é o valor a receber na moeda
+CREATE TABLE IF NOT EXISTS invoice_currencies (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL CHECK (currency <> 'BRL'),
+    foreign_amount DECIMAL(15, 2) NOT NULL,
+    issue_rate DECIMAL(18, 8) NOT NULL CHECK (issue_rate > 0),
+    rate_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_invoice_currencies_invoice UNIQUE (invoice_id)
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_currencies_company_id ON invoice_currencies(company_id);
+
+-- Reavaliação das faturas em aberto em moeda estrangeira pela cotação do fim do mês; variance é
+-- a variação cambial do saldo em aberto desde a avaliação anterior (emissão ou mês anterior)
+CREATE TABLE IF NOT EXISTS invoice_revaluations (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    period CHAR(7) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    open_foreign_amount DECIMAL(15, 2) NOT NULL,
+    previous_rate DECIMAL(18, 8) NOT NULL,
+    rate DECIMAL(18, 8) NOT NULL,
+    rate_date DATE NOT NULL,
+    book_value DECIMAL(15, 2) NOT NULL,
+    revalued_value DECIMAL(15, 2) NOT NULL,
+    variance DECIMAL(15, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_invoice_revaluations_invoice_period UNIQUE (invoice_id, period)
+);
+CREATE INDEX IF NOT EXISTS idx_invoice_revaluations_company_period ON invoice_revaluations(company_id, period);
@@ -0,0 +1,73 @@
+-- Linha do tempo dos processos de venda: os eventos são gravados quando documentos são vinculados
+-- ao processo, quando a etapa muda e quando pagamentos das faturas são recebidos. Os eventos
+-- manuais (ligações, reuniões, notas) são lançados pelos usuários.
+CREATE TABLE IF NOT EXISTS process_events (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    process_id INTEGER NOT NULL REFERENCES sales_processes(id) ON DELETE CASCADE,
+    event_type VARCHAR(40) NOT NULL,
+    description TEXT NOT NULL,
+    document_type VARCHAR(30),
+    document_id INTEGER,
+    document_no VARCHAR(50),
+    value DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    manual BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_process_events_process_occurred ON process_events(process_id, occurred_at, id);
+CREATE INDEX IF NOT EXISTS idx_process_events_company_id ON process_events(company_id);
+-- Cada documento gera um só evento por processo, mesmo vinculado de novo
+CREATE UNIQUE INDEX IF NOT EXISTS uq_process_events_document
+    ON process_events(process_id, event_type, document_id) WHERE document_id IS NOT NULL;
+
+-- Eventos dos processos existentes, como a linha do tempo era montada a cada leitura
+INSERT INTO process_events (company_id, process_id, event_type, description, document_type, document_id, occurred_at)
+SELECT p.company_id, p.id, 'process_created', 'Processo de venda iniciado', 'sales_process', p.id, p.created_at
+FROM sales_processes p
+ON CONFLICT DO NOTHING;
+
+INSERT INTO process_events (company_id, process_id, event_type, description, document_type, document_id, document_no, value, occurred_at)
+SELECT p.company_id, p.id, 'quotation_created', 'Cotação ' || q.quotation_no || ' criada', 'quotation', q.id, q.quotation_no, q.grand_total, q.created_at
+FROM process_quotations pq
+JOIN sales_processes p ON p.id = pq.process_id
+JOIN quotations q ON q.id = pq.quotation_id AND q.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO process_events (company_id, process_id, event_type, description, document_type, document_id, document_no, value, occurred_at)
+SELECT p.company_id, p.id, 'sales_order_created', 'Pedido de venda ' || so.so_no || ' criado', 'sales_order', so.id, so.so_no, so.grand_total, so.created_at
+FROM process_sales_orders pso
+JOIN sales_processes p ON p.id = pso.process_id
+JOIN sales_orders so ON so.id = pso.sales_order_id AND so.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO process_events (company_id, process_id, event_type, description, document_type, document_id, document_no, value, occurred_at)
+SELECT p.company_id, p.id, 'purchase_order_created', 'Ordem de compra ' || po.po_no || ' criada', 'purchase_order', po.id, po.po_no, po.grand_total, po.created_at
+FROM process_sales_orders pso
+JOIN sales_processes p ON p.id = pso.process_id
+JOIN purchase_orders po ON po.sales_order_id = pso.sales_order_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO process_events (company_id, process_id, event_type, description, document_type, document_id, document_no, occurred_at)
+SELECT p.company_id, p.id, 'delivery_created', 'Entrega ' || d.delivery_no || ' criada', 'delivery', d.id, d.delivery_no, d.created_at
+FROM process_deliveries pd
+JOIN sales_processes p ON p.id = pd.process_id
+JOIN deliveries d ON d.id = pd.delivery_id AND d.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO process_events (company_id, process_id, event_type, description, document_type, document_id, document_no, value, occurred_at)
+SELECT p.company_id, p.id, 'invoice_created', 'Fatura ' || i.invoice_no || ' criada', 'invoice', i.id, i.invoice_no, i.grand_total, i.created_at
+FROM process_invoices pi
+JOIN sales_processes p ON p.id = pi.process_id
+JOIN invoices i ON i.id = pi.invoice_id AND i.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO process_events (company_id, process_id, event_type, description, document_type, document_id, value, occurred_at)
+SELECT p.company_id, p.id, 'payment_received', 'Pagamento de ' || pm.amount || ' recebido', 'payment', pm.id, pm.amount,
+    COALESCE(pm.payment_date, p.created_at)
+FROM process_invoices pi
+JOIN sales_processes p ON p.id = pi.process_id
+JOIN invoices i ON i.id = pi.invoice_id AND i.deleted_at IS NULL
+JOIN payments pm ON pm.invoice_id = i.id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,29 @@
+-- Contestações das faturas (ou de uma parcela): enquanto aberta, a fatura fica fora da cobrança
+-- automática e o valor contestado não entra nos indicadores de vencidos. A resolução registra a
+-- ação tomada: nota de crédito, novo vencimento ou baixa do valor.
+CREATE TABLE IF NOT EXISTS invoice_disputes (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    installment_id INTEGER REFERENCES invoice_installments(id) ON DELETE SET NULL,
+    raised_by VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    opened_by VARCHAR(100),
+    resolution VARCHAR(30),
+    resolution_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    credit_note_id INTEGER REFERENCES credit_notes(id),
+    new_due_date DATE,
+    resolution_notes TEXT,
+    resolved_by VARCHAR(100),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_disputes_invoice ON invoice_disputes(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_invoice_disputes_company_status ON invoice_disputes(company_id, status);
+-- Uma contestação aberta por fatura (installment_id nulo) ou por parcela
+CREATE UNIQUE INDEX IF NOT EXISTS uq_invoice_disputes_open
+    ON invoice_disputes(invoice_id, COALESCE(installment_id, 0)) WHERE status = 'open';
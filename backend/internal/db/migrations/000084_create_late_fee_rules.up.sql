@@ -0,0 +1,17 @@
+-- Multa e juros de mora das faturas vencidas. A regra sem contato é a padrão da empresa; a regra
+-- do contato a substitui. A multa (percentual sobre o saldo vencido mais um valor fixo) é cobrada
+-- uma vez e os juros ao mês correm pró-rata por dia de atraso, depois da carência.
+CREATE TABLE IF NOT EXISTS late_fee_rules (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    contact_id INTEGER REFERENCES contacts(id) ON DELETE CASCADE,
+    fine_percent DECIMAL(6, 2) NOT NULL DEFAULT 0 CHECK (fine_percent >= 0 AND fine_percent <= 100),
+    fine_amount DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (fine_amount >= 0),
+    interest_percent DECIMAL(7, 4) NOT NULL DEFAULT 0 CHECK (interest_percent >= 0 AND interest_percent <= 100),
+    grace_days INTEGER NOT NULL DEFAULT 0 CHECK (grace_days >= 0),
+    updated_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_late_fee_rules_company_default ON late_fee_rules(company_id) WHERE contact_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_late_fee_rules_contact ON late_fee_rules(contact_id) WHERE contact_id IS NOT NULL;
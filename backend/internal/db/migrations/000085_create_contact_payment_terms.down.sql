@@ -0,0 +1,3 @@
+ALTER TABLE invoices DROP COLUMN IF EXISTS early_discount_until;
+ALTER TABLE invoices DROP COLUMN IF EXISTS early_discount_amount;
+DROP TABLE IF EXISTS contact_payment_terms;
@@ -0,0 +1,21 @@
+-- Condição de pagamento estruturada do contato: prazo único (net_days) ou parcelas em dias a partir
+-- da emissão (installment_days), em dias corridos ou úteis, e desconto por pagamento antecipado.
+-- Usada ao faturar os pedidos de venda para calcular o vencimento e as parcelas.
+CREATE TABLE IF NOT EXISTS contact_payment_terms (
+    id SERIAL PRIMARY KEY,
+    company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id),
+    contact_id INTEGER NOT NULL UNIQUE REFERENCES contacts(id) ON DELETE CASCADE,
+    net_days INTEGER NOT NULL DEFAULT 0 CHECK (net_days >= 0),
+    business_days BOOLEAN NOT NULL DEFAULT FALSE,
+    installment_days JSONB NOT NULL DEFAULT '[]',
+    discount_percent DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK (discount_percent >= 0 AND discount_percent < 100),
+    discount_days INTEGER NOT NULL DEFAULT 0 CHECK (discount_days >= 0),
+    updated_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_contact_payment_terms_company ON contact_payment_terms(company_id);
+
+-- Desconto por antecipação herdado da condição do contato ao faturar
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS early_discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS early_discount_until DATE;
@@ -0,0 +1,4 @@
+DELETE FROM ledger_account_mappings WHERE key = 'customer_advances';
+DROP INDEX IF EXISTS idx_invoices_sales_order_advance;
+ALTER TABLE invoices DROP COLUMN IF EXISTS advance_deduction;
+ALTER TABLE invoices DROP COLUMN IF EXISTS kind;
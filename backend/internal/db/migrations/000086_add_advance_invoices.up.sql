@@ -0,0 +1,18 @@
+-- Faturas de adiantamento dos pedidos de venda: kind = 'advance', sem itens. As faturas seguintes
+-- do pedido abatem o adiantamento ainda não deduzido em advance_deduction, com grand_total já
+-- líquido do abatimento.
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'standard';
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS advance_deduction DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (advance_deduction >= 0);
+CREATE INDEX IF NOT EXISTS idx_invoices_sales_order_advance ON invoices(sales_order_id) WHERE kind = 'advance';
+
+-- Adiantamentos: a fatura de adiantamento credita "customer_advances" contra "receivables"; o
+-- abatimento na fatura dos itens debita a conta no lugar do cliente
+INSERT INTO ledger_accounts (company_id, code, name, type) VALUES
+    (1, '2.1.05', 'Adiantamentos de Clientes', 'liability')
+ON CONFLICT (company_id, code) DO NOTHING;
+
+INSERT INTO ledger_account_mappings (key, account_id)
+SELECT 'customer_advances', a.id
+FROM ledger_accounts a
+WHERE a.code = '2.1.05' AND a.company_id = 1
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,5 @@
+DELETE FROM idempotency_keys;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS uq_idempotency_keys_request;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS caller;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS company_id;
+ALTER TABLE idempotency_keys ADD CONSTRAINT uq_idempotency_keys_request UNIQUE (idempotency_key, method, path);
@@ -0,0 +1,11 @@
+-- As chaves de idempotência passam a valer por empresa e por quem enviou a requisição (usuário
+-- ou chave de API): a mesma chave enviada por outro usuário não devolve a resposta gravada.
+-- As chaves existentes não identificam o caller e são descartadas (valem por poucas horas)
+DELETE FROM idempotency_keys;
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS company_id INTEGER NOT NULL DEFAULT 1 REFERENCES companies(id);
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS caller VARCHAR(150) NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS uq_idempotency_keys_request;
+ALTER TABLE idempotency_keys ADD CONSTRAINT uq_idempotency_keys_request
+    UNIQUE (company_id, caller, idempotency_key, method, path);
@@ -0,0 +1,28 @@
+-- Índice de busca do catálogo: o vetor de texto do produto (nome, nome detalhado e tags, com os
+-- radicais do português) é mantido pela trigger a cada gravação e consultado pelo índice GIN.
+-- Cargas feitas com as triggers desligadas (restauração de backup, session_replication_role =
+-- replica) deixam o vetor desatualizado: `erpctl reindex` recalcula os vetores e reconstrói o índice.
+CREATE OR REPLACE FUNCTION product_search_vector(name TEXT, detailed_name TEXT, tags TEXT[])
+RETURNS tsvector LANGUAGE sql IMMUTABLE AS $$
+    SELECT setweight(to_tsvector('portuguese', COALESCE(name, '')), 'A') ||
+           setweight(to_tsvector('portuguese', COALESCE(detailed_name, '')), 'B') ||
+           setweight(to_tsvector('portuguese', COALESCE(array_to_string(tags, ' '), '')), 'C')
+$$;
+
+CREATE OR REPLACE FUNCTION products_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.search_vector := product_search_vector(NEW.name, NEW.detailed_name, NEW.tags);
+    RETURN NEW;
+END
+$$;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+UPDATE products SET search_vector = product_search_vector(name, detailed_name, tags);
+
+DROP TRIGGER IF EXISTS trg_products_search_vector ON products;
+CREATE TRIGGER trg_products_search_vector
+    BEFORE INSERT OR UPDATE OF name, detailed_name, tags ON products
+    FOR EACH ROW EXECUTE FUNCTION products_search_vector_trigger();
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
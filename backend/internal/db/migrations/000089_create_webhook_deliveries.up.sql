@@ -0,0 +1,17 @@
+-- Webhooks recebidos dos parceiros (PSP do Pix e transportadoras), gravados depois de conferida a
+-- assinatura e antes do processamento. Os que falham ficam com status 'failed' e o erro, e
+-- `erpctl webhook-replay` os processa de novo. As notificações valem para qualquer empresa,
+-- então a tabela não tem company_id.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    source VARCHAR(20) NOT NULL,
+    provider VARCHAR(50) NOT NULL DEFAULT '',
+    payload BYTEA NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'received' CHECK (status IN ('received', 'processed', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_failed ON webhook_deliveries(source, received_at) WHERE status = 'failed';
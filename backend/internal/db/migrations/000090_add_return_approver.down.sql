@@ -0,0 +1 @@
+ALTER TABLE return_requests DROP COLUMN IF EXISTS approved_by;
@@ -0,0 +1,2 @@
+-- Aprovador da devolução: o usuário autenticado que aprovou a solicitação
+ALTER TABLE return_requests ADD COLUMN IF NOT EXISTS approved_by VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// Provider entrega as conexões usadas pelos repositórios: o primário para escritas e leituras
+// transacionais e a réplica somente leitura para as consultas analíticas pesadas (relatórios).
+// Sem réplica, Reader devolve o próprio primário.
+type Provider struct {
+	writer *gorm.DB
+	reader *gorm.DB
+}
+
+// NewProvider monta o provider com as conexões informadas; reader nil usa o primário
+func NewProvider(writer, reader *gorm.DB) *Provider {
+	if reader == nil {
+		reader = writer
+	}
+	return &Provider{writer: writer, reader: reader}
+}
+
+// OpenProvider abre o primário e, se DB_REPLICA_HOST estiver definido, a réplica de leitura.
+// Uma réplica indisponível não impede a inicialização: as leituras seguem no primário.
+func OpenProvider() (*Provider, error) {
+	writer, err := OpenGormDB()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := OpenReplicaGormDB()
+	if err != nil {
+		log.Printf("⚠️  réplica de leitura indisponível, usando o primário: %v", err)
+		reader = nil
+	}
+
+	return NewProvider(writer, reader), nil
+}
+
+// Writer retorna a conexão com o primário
+func (p *Provider) Writer() *gorm.DB {
+	return p.writer
+}
+
+// Reader retorna a conexão com a réplica de leitura, ou o primário quando não há réplica
+func (p *Provider) Reader() *gorm.DB {
+	return p.reader
+}
+
+// HasReplica informa se as leituras vão para uma réplica separada do primário
+func (p *Provider) HasReplica() bool {
+	return p.reader != p.writer
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func mockGormDB(t *testing.T) *gorm.DB {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB
+}
+
+func TestProviderRoutesReadsToReplica(t *testing.T) {
+	writer, reader := mockGormDB(t), mockGormDB(t)
+
+	provider := NewProvider(writer, reader)
+
+	assert.Same(t, writer, provider.Writer())
+	assert.Same(t, reader, provider.Reader())
+	assert.True(t, provider.HasReplica())
+}
+
+func TestProviderWithoutReplicaReadsFromPrimary(t *testing.T) {
+	writer := mockGormDB(t)
+
+	provider := NewProvider(writer, nil)
+
+	assert.Same(t, writer, provider.Reader())
+	assert.False(t, provider.HasReplica())
+}
+
+func TestOpenReplicaGormDBNotConfigured(t *testing.T) {
+	original := viper.GetString("DB_REPLICA_HOST")
+	viper.Set("DB_REPLICA_HOST", "")
+	t.Cleanup(func() { viper.Set("DB_REPLICA_HOST", original) })
+
+	replica, err := OpenReplicaGormDB()
+
+	assert.NoError(t, err)
+	assert.Nil(t, replica)
+}
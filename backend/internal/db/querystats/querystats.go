@@ -0,0 +1,104 @@
+package querystats
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Stats acumula a quantidade de queries e o tempo gasto no banco durante uma requisição
+type Stats struct {
+	queries atomic.Int64
+	nanos   atomic.Int64
+}
+
+// Queries retorna quantas queries foram executadas
+func (s *Stats) Queries() int64 {
+	return s.queries.Load()
+}
+
+// Duration retorna o tempo total gasto nas queries
+func (s *Stats) Duration() time.Duration {
+	return time.Duration(s.nanos.Load())
+}
+
+func (s *Stats) record(d time.Duration) {
+	s.queries.Add(1)
+	s.nanos.Add(int64(d))
+}
+
+type contextKey struct{}
+
+// NewContext devolve um contexto que acumula as estatísticas das queries executadas com ele
+// (db.WithContext(ctx)); as queries sem esse contexto não são contabilizadas
+func NewContext(ctx context.Context) (context.Context, *Stats) {
+	stats := &Stats{}
+	return context.WithValue(ctx, contextKey{}, stats), stats
+}
+
+// FromContext retorna as estatísticas associadas ao contexto, ou nil
+func FromContext(ctx context.Context) *Stats {
+	if ctx == nil {
+		return nil
+	}
+	stats, _ := ctx.Value(contextKey{}).(*Stats)
+	return stats
+}
+
+const startKey = "querystats:start"
+
+// Plugin registra no GORM os callbacks que medem cada query (db.Use(querystats.Plugin{}))
+type Plugin struct{}
+
+// Name identifica o plugin no GORM
+func (Plugin) Name() string {
+	return "querystats"
+}
+
+// Initialize envolve a etapa que executa o SQL de cada operação. O fim da medição fica antes
+// dos preloads e associações, que rodam queries próprias e são contabilizadas separadamente.
+func (Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	operations := []struct {
+		name          string
+		before, after interface {
+			Register(name string, fn func(*gorm.DB)) error
+		}
+	}{
+		{"query", cb.Query().Before("gorm:query"), cb.Query().After("gorm:query").Before("gorm:preload")},
+		{"create", cb.Create().Before("gorm:create"), cb.Create().After("gorm:create").Before("gorm:save_after_associations")},
+		{"update", cb.Update().Before("gorm:update"), cb.Update().After("gorm:update").Before("gorm:save_after_associations")},
+		{"delete", cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete")},
+		{"row", cb.Row().Before("gorm:row"), cb.Row().After("gorm:row")},
+		{"raw", cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw")},
+	}
+	for _, op := range operations {
+		if err := op.before.Register("querystats:before_"+op.name, startTimer); err != nil {
+			return err
+		}
+		if err := op.after.Register("querystats:after_"+op.name, stopTimer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startTimer(db *gorm.DB) {
+	if FromContext(db.Statement.Context) != nil {
+		db.InstanceSet(startKey, time.Now())
+	}
+}
+
+func stopTimer(db *gorm.DB) {
+	stats := FromContext(db.Statement.Context)
+	if stats == nil {
+		return
+	}
+	start, ok := db.InstanceGet(startKey)
+	if !ok {
+		return
+	}
+	stats.record(time.Since(start.(time.Time)))
+}
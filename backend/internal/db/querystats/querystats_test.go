@@ -0,0 +1,60 @@
+package querystats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type author struct {
+	ID    int
+	Books []book
+}
+
+type book struct {
+	ID       int
+	AuthorID int
+}
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(Plugin{}))
+	return db
+}
+
+func TestPluginCountsQueriesOfTheContext(t *testing.T) {
+	db := dryRunDB(t)
+	ctx, stats := NewContext(context.Background())
+
+	var total int64
+	var authors []author
+	db.WithContext(ctx).Model(&author{}).Count(&total)
+	db.WithContext(ctx).Find(&authors)
+	db.WithContext(ctx).Create(&book{AuthorID: 1})
+	db.WithContext(ctx).Exec("UPDATE books SET author_id = 2")
+
+	assert.EqualValues(t, 4, stats.Queries())
+	assert.GreaterOrEqual(t, stats.Duration().Nanoseconds(), int64(0))
+}
+
+func TestPluginIgnoresQueriesWithoutStats(t *testing.T) {
+	db := dryRunDB(t)
+	_, stats := NewContext(context.Background())
+
+	var authors []author
+	db.Find(&authors)
+	db.WithContext(context.Background()).Find(&authors)
+
+	assert.Zero(t, stats.Queries())
+	assert.Nil(t, FromContext(context.Background()))
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/lib/pq"
@@ -65,9 +66,9 @@ func SeedProducts(db *sql.DB, count int) error {
 
 	for i := range count {
 		// Gerar preços realistas
-		basePrice := gofakeit.Price(100, 10000)
-		costPrice := basePrice * 0.65 // Custo é aproximadamente 65% do preço base
-		salesPrice := basePrice * 0.9 // Preço promocional é 90% do preço base
+		basePrice := money.FromCents(int64(gofakeit.Number(10000, 1000000)))
+		costPrice := money.Round(basePrice.Mul(money.MustParse("0.65"))) // Custo é aproximadamente 65% do preço base
+		salesPrice := money.Round(basePrice.Mul(money.MustParse("0.9"))) // Preço promocional é 90% do preço base
 
 		// Gerar tags para o produto
 		numTags := gofakeit.Number(1, 5)
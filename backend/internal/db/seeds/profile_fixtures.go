@@ -0,0 +1,118 @@
+package seeds
+
+import "ERP-ONSMART/backend/internal/money"
+
+// demoRetailProfile é uma loja com processos em todas as etapas do funil, do orçamento enviado à
+// venda quitada
+var demoRetailProfile = Profile{
+	Name:        "demo-retail",
+	Description: "Loja de informática com processos de venda em todas as etapas",
+	Prefix:      "DEMO",
+	Contacts: []ContactFixture{
+		{Name: "Mercado Bom Preço Ltda", PersonType: "pj", Document: "11222333000181", Email: "compras@bompreco.example.com", City: "São Paulo", State: "SP"},
+		{Name: "Escola Aprender Mais", PersonType: "pj", Document: "22333444000172", Email: "financeiro@aprendermais.example.com", City: "Campinas", State: "SP"},
+		{Name: "Clínica Vida Plena", PersonType: "pj", Document: "33444555000163", Email: "adm@vidaplena.example.com", City: "Belo Horizonte", State: "MG"},
+		{Name: "Ana Souza", PersonType: "pf", Document: "12345678909", Email: "ana.souza@example.com", City: "Curitiba", State: "PR"},
+		{Name: "Carlos Lima", PersonType: "pf", Document: "98765432100", Email: "carlos.lima@example.com", City: "Rio de Janeiro", State: "RJ"},
+	},
+	Products: []ProductFixture{
+		{Name: "Notebook Pro 14", SKU: "DEMO-NB14", Coin: "BRL", Price: money.MustParse("5499.00"), Cost: money.MustParse("3900.00"), Stock: 40},
+		{Name: "Monitor 27 IPS", SKU: "DEMO-MN27", Coin: "BRL", Price: money.MustParse("1899.90"), Cost: money.MustParse("1250.00"), Stock: 60},
+		{Name: "Teclado Sem Fio", SKU: "DEMO-TCL", Coin: "BRL", Price: money.MustParse("249.90"), Cost: money.MustParse("140.00"), Stock: 200},
+		{Name: "Mouse Óptico", SKU: "DEMO-MOU", Coin: "BRL", Price: money.MustParse("89.90"), Cost: money.MustParse("42.00"), Stock: 300},
+		{Name: "Impressora Laser", SKU: "DEMO-IMP", Coin: "BRL", Price: money.MustParse("1599.00"), Cost: money.MustParse("1100.00"), Stock: 25},
+		{Name: "Roteador Wi-Fi 6", SKU: "DEMO-RTW6", Coin: "BRL", Price: money.MustParse("699.00"), Cost: money.MustParse("430.00"), Stock: 80},
+	},
+	Processes: []ProcessFixture{
+		{Contact: 0, Stage: stageQuotation, StartedDaysAgo: 3, TermDays: 30,
+			Items: []ItemFixture{{Product: 0, Quantity: 10, DiscountPercent: 5}, {Product: 2, Quantity: 10}}},
+		{Contact: 1, Stage: stageSalesOrder, StartedDaysAgo: 4, TermDays: 28,
+			Items: []ItemFixture{{Product: 1, Quantity: 20, DiscountPercent: 8}}},
+		{Contact: 2, Stage: stageDelivery, StartedDaysAgo: 6, TermDays: 30,
+			Items: []ItemFixture{{Product: 4, Quantity: 3}, {Product: 5, Quantity: 2}}},
+		{Contact: 3, Stage: stageInvoicing, StartedDaysAgo: 12, TermDays: 30,
+			Items: []ItemFixture{{Product: 0, Quantity: 1}, {Product: 3, Quantity: 1}}},
+		{Contact: 0, Stage: stagePayment, StartedDaysAgo: 40, TermDays: 60, PaidPercent: 50,
+			Items: []ItemFixture{{Product: 1, Quantity: 15, DiscountPercent: 5}, {Product: 2, Quantity: 15}, {Product: 3, Quantity: 15}}},
+		{Contact: 4, Stage: stageCompleted, StartedDaysAgo: 45, TermDays: 15,
+			Items: []ItemFixture{{Product: 5, Quantity: 1}}},
+		{Contact: 1, Stage: stageCompleted, StartedDaysAgo: 70, TermDays: 30,
+			Items: []ItemFixture{{Product: 0, Quantity: 5, DiscountPercent: 10}, {Product: 4, Quantity: 1}}},
+	},
+}
+
+// overdueInvoicesProfile é uma carteira com faturas vencidas em várias faixas de atraso, para
+// a régua de cobrança, o aging e os relatórios de inadimplência
+var overdueInvoicesProfile = Profile{
+	Name:        "overdue-invoices",
+	Description: "Carteira de faturas vencidas em várias faixas de atraso, com pagamentos parciais",
+	Prefix:      "OVD",
+	Contacts: []ContactFixture{
+		{Name: "Construtora Alicerce S.A.", PersonType: "pj", Document: "44555666000154", Email: "contas@alicerce.example.com", City: "Salvador", State: "BA"},
+		{Name: "Padaria Pão Quente", PersonType: "pj", Document: "55666777000145", Email: "contato@paoquente.example.com", City: "Recife", State: "PE"},
+		{Name: "Transportes Rota Sul", PersonType: "pj", Document: "66777888000136", Email: "financeiro@rotasul.example.com", City: "Porto Alegre", State: "RS"},
+		{Name: "Marina Costa", PersonType: "pf", Document: "11144477735", Email: "marina.costa@example.com", City: "Goiânia", State: "GO"},
+	},
+	Products: []ProductFixture{
+		{Name: "Licença ERP Anual", SKU: "OVD-LIC", Coin: "BRL", Price: money.MustParse("4800.00"), Cost: money.MustParse("1200.00"), Stock: 1000},
+		{Name: "Consultoria de Implantação (hora)", SKU: "OVD-CONS", Coin: "BRL", Price: money.MustParse("250.00"), Cost: money.MustParse("120.00"), Stock: 1000},
+		{Name: "Terminal de Ponto de Venda", SKU: "OVD-PDV", Coin: "BRL", Price: money.MustParse("2350.00"), Cost: money.MustParse("1700.00"), Stock: 30},
+	},
+	Processes: []ProcessFixture{
+		// vencida há poucos dias
+		{Contact: 0, Stage: stageInvoicing, StartedDaysAgo: 45, TermDays: 30,
+			Items: []ItemFixture{{Product: 0, Quantity: 2}, {Product: 1, Quantity: 16}}},
+		// vencida há mais de 30 dias, paga em parte
+		{Contact: 0, Stage: stagePayment, StartedDaysAgo: 90, TermDays: 30, PaidPercent: 40,
+			Items: []ItemFixture{{Product: 2, Quantity: 4}}},
+		// vencida há mais de 60 dias
+		{Contact: 1, Stage: stageInvoicing, StartedDaysAgo: 110, TermDays: 28,
+			Items: []ItemFixture{{Product: 2, Quantity: 1}, {Product: 1, Quantity: 4}}},
+		// vencida há mais de 90 dias, paga em parte
+		{Contact: 2, Stage: stagePayment, StartedDaysAgo: 160, TermDays: 30, PaidPercent: 25,
+			Items: []ItemFixture{{Product: 0, Quantity: 5, DiscountPercent: 10}}},
+		// vencida há mais de 120 dias
+		{Contact: 3, Stage: stageInvoicing, StartedDaysAgo: 200, TermDays: 15,
+			Items: []ItemFixture{{Product: 1, Quantity: 6}}},
+		// ainda no prazo, para comparação
+		{Contact: 1, Stage: stageInvoicing, StartedDaysAgo: 10, TermDays: 30,
+			Items: []ItemFixture{{Product: 0, Quantity: 1}}},
+		// quitada
+		{Contact: 2, Stage: stageCompleted, StartedDaysAgo: 80, TermDays: 30,
+			Items: []ItemFixture{{Product: 1, Quantity: 10}}},
+	},
+}
+
+// multiCurrencyProfile é um importador com produtos cotados em dólar e euro. Os documentos de
+// venda não têm moeda (são em money.Default): os preços são convertidos para reais com as taxas
+// fixas do perfil, registradas na descrição de cada item.
+var multiCurrencyProfile = Profile{
+	Name:        "multi-currency",
+	Description: "Importador com produtos em USD e EUR, vendidos em reais por taxas fixas",
+	Prefix:      "FX",
+	Rates: map[string]money.Decimal{
+		"USD": money.MustParse("5.4000"),
+		"EUR": money.MustParse("5.9000"),
+	},
+	Contacts: []ContactFixture{
+		{Name: "Laboratório Análise Certa", PersonType: "pj", Document: "77888999000127", Email: "compras@analisecerta.example.com", City: "Florianópolis", State: "SC"},
+		{Name: "Estúdio Som & Imagem", PersonType: "pj", Document: "88999000000118", Email: "producao@someimagem.example.com", City: "São Paulo", State: "SP"},
+		{Name: "Universidade Horizonte", PersonType: "pj", Document: "99000111000109", Email: "suprimentos@horizonte.example.com", City: "Brasília", State: "DF"},
+	},
+	Products: []ProductFixture{
+		{Name: "Microscópio Digital", SKU: "FX-MIC", Coin: "USD", Price: money.MustParse("1250.00"), Cost: money.MustParse("870.00"), Stock: 12},
+		{Name: "Placa de Áudio Profissional", SKU: "FX-AUD", Coin: "EUR", Price: money.MustParse("690.00"), Cost: money.MustParse("455.00"), Stock: 20},
+		{Name: "Licença de Software de Edição", SKU: "FX-SW", Coin: "USD", Price: money.MustParse("299.00"), Cost: money.MustParse("180.00"), Stock: 500},
+		{Name: "Cabo de Sinal Blindado", SKU: "FX-CAB", Coin: "BRL", Price: money.MustParse("79.90"), Cost: money.MustParse("35.00"), Stock: 400},
+	},
+	Processes: []ProcessFixture{
+		{Contact: 0, Stage: stageQuotation, StartedDaysAgo: 2, TermDays: 30,
+			Items: []ItemFixture{{Product: 0, Quantity: 2}}},
+		{Contact: 1, Stage: stageDelivery, StartedDaysAgo: 6, TermDays: 30,
+			Items: []ItemFixture{{Product: 1, Quantity: 3}, {Product: 3, Quantity: 6}}},
+		{Contact: 2, Stage: stagePayment, StartedDaysAgo: 35, TermDays: 45, PaidPercent: 60,
+			Items: []ItemFixture{{Product: 2, Quantity: 20, DiscountPercent: 15}, {Product: 0, Quantity: 1}}},
+		{Contact: 1, Stage: stageCompleted, StartedDaysAgo: 60, TermDays: 30,
+			Items: []ItemFixture{{Product: 2, Quantity: 2}, {Product: 3, Quantity: 10}}},
+	},
+}
@@ -0,0 +1,439 @@
+package seeds
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// Profile é um cenário nomeado de dados de demonstração: contatos, produtos e processos de venda
+// com os documentos ligados entre si (cotação → pedido → entrega → fatura → pagamento). Os
+// valores e as relações são fixos; as datas são relativas ao dia da carga, para que as faturas
+// vencidas continuem vencidas e as cotações em aberto continuem válidas.
+type Profile struct {
+	Name        string
+	Description string
+	Prefix      string // prefixo dos números dos documentos (QT-<Prefix>-0001)
+	Contacts    []ContactFixture
+	Products    []ProductFixture
+	Processes   []ProcessFixture
+	// Rates são as taxas de conversão para reais dos produtos em moeda estrangeira: os documentos
+	// são sempre em money.Default
+	Rates map[string]money.Decimal
+}
+
+// ContactFixture é um cliente do cenário
+type ContactFixture struct {
+	Name       string
+	PersonType string // pf ou pj
+	Document   string
+	Email      string
+	City       string
+	State      string
+}
+
+// ProductFixture é um produto do cenário, com o preço na moeda do produto (coin)
+type ProductFixture struct {
+	Name  string
+	SKU   string
+	Coin  string
+	Price money.Decimal
+	Cost  money.Decimal
+	Stock int
+}
+
+// ItemFixture é um item do processo: o índice do produto em Profile.Products
+type ItemFixture struct {
+	Product         int
+	Quantity        int
+	DiscountPercent int
+}
+
+// ProcessFixture é um processo de venda do cenário. Stage é a etapa em que o processo está e
+// define até onde a cadeia de documentos é emitida: quotation (cotação enviada), sales_order
+// (pedido confirmado), delivery (mercadoria despachada), invoicing (fatura emitida, sem
+// pagamentos), payment (fatura paga em parte) e completed (fatura quitada).
+type ProcessFixture struct {
+	Contact        int // índice em Profile.Contacts
+	Items          []ItemFixture
+	Stage          string
+	StartedDaysAgo int // dias entre a cotação e o dia da carga
+	TermDays       int // prazo de pagamento da fatura
+	PaidPercent    int // parte da fatura paga na etapa payment
+}
+
+// Etapas do processo de venda (repository.ProcessStatus*; o pacote de vendas importa os seeds
+// nos testes, então as etapas são repetidas aqui)
+const (
+	stageQuotation  = "quotation"
+	stageSalesOrder = "sales_order"
+	stageDelivery   = "delivery"
+	stageInvoicing  = "invoicing"
+	stagePayment    = "payment"
+	stageCompleted  = "completed"
+)
+
+// chainStages é a ordem das etapas em que os documentos do processo são emitidos
+var chainStages = map[string]int{
+	stageQuotation:  1,
+	stageSalesOrder: 2,
+	stageDelivery:   3,
+	stageInvoicing:  4,
+	stagePayment:    5,
+	stageCompleted:  6,
+}
+
+// Intervalos, em dias a partir da cotação, entre os documentos da cadeia
+const (
+	orderAfterDays     = 2
+	shipAfterDays      = 5
+	deliverAfterDays   = 7
+	quotationValidDays = 30
+)
+
+// chainLine é um item da cadeia, com o preço já convertido para reais
+type chainLine struct {
+	Product   ProductFixture
+	Quantity  int
+	UnitPrice money.Decimal
+	Discount  money.Decimal
+	Total     money.Decimal
+	Cost      money.Decimal
+	Note      string
+}
+
+// chain são os documentos de um processo do cenário: os status vazios são de documentos que
+// ainda não foram emitidos na etapa do processo
+type chain struct {
+	Fixture         ProcessFixture
+	Lines           []chainLine
+	SubTotal        money.Decimal
+	DiscountTotal   money.Decimal
+	GrandTotal      money.Decimal
+	Profit          money.Decimal
+	Paid            money.Decimal
+	QuotationStatus string
+	OrderStatus     string
+	DeliveryStatus  string
+	InvoiceStatus   string
+	QuotedAt        time.Time
+	OrderedAt       time.Time
+	ShippedAt       time.Time
+	DeliveredAt     time.Time
+	DueAt           time.Time
+	PaidAt          time.Time
+	UpdatedAt       time.Time
+}
+
+// profiles são os perfis disponíveis, pelo nome (ver profile_fixtures.go)
+var profiles = indexProfiles(demoRetailProfile, overdueInvoicesProfile, multiCurrencyProfile)
+
+func indexProfiles(list ...Profile) map[string]Profile {
+	index := make(map[string]Profile, len(list))
+	for _, profile := range list {
+		index[profile.Name] = profile
+	}
+	return index
+}
+
+// ProfileNames lista os perfis disponíveis, em ordem alfabética
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupProfile busca o perfil pelo nome
+func LookupProfile(name string) (Profile, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("[seeds:profiles] perfil %q desconhecido; disponíveis: %s", name, strings.Join(ProfileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// planChain monta os documentos do processo: converte os preços, soma os totais e define as
+// datas e os status de cada documento pela etapa do processo
+func planChain(profile Profile, fixture ProcessFixture, base time.Time) (chain, error) {
+	rank, ok := chainStages[fixture.Stage]
+	if !ok {
+		return chain{}, fmt.Errorf("[seeds:profiles] etapa %q não gera cadeia de documentos", fixture.Stage)
+	}
+	if fixture.Contact < 0 || fixture.Contact >= len(profile.Contacts) {
+		return chain{}, fmt.Errorf("[seeds:profiles] contato %d fora do perfil %q", fixture.Contact, profile.Name)
+	}
+
+	c := chain{Fixture: fixture}
+	var cost money.Decimal
+	for _, item := range fixture.Items {
+		if item.Product < 0 || item.Product >= len(profile.Products) {
+			return chain{}, fmt.Errorf("[seeds:profiles] produto %d fora do perfil %q", item.Product, profile.Name)
+		}
+		product := profile.Products[item.Product]
+		line := chainLine{Product: product, Quantity: item.Quantity, UnitPrice: product.Price, Cost: product.Cost}
+		if product.Coin != string(money.Default) {
+			rate, ok := profile.Rates[product.Coin]
+			if !ok {
+				return chain{}, fmt.Errorf("[seeds:profiles] sem taxa de conversão para %s no perfil %q", product.Coin, profile.Name)
+			}
+			line.UnitPrice = product.Price.Mul(rate).Round(2)
+			line.Cost = product.Cost.Mul(rate).Round(2)
+			line.Note = fmt.Sprintf("Preço convertido de %s %s à taxa %s", product.Coin, product.Price.StringFixed(2), rate.StringFixed(4))
+		}
+		gross := line.UnitPrice.MulInt(item.Quantity)
+		line.Discount = money.Round(gross.MulInt(item.DiscountPercent).DivInt(100))
+		line.Total = gross.Sub(line.Discount)
+
+		c.Lines = append(c.Lines, line)
+		c.SubTotal = c.SubTotal.Add(gross)
+		c.DiscountTotal = c.DiscountTotal.Add(line.Discount)
+		cost = cost.Add(line.Cost.MulInt(item.Quantity))
+	}
+	c.GrandTotal = c.SubTotal.Sub(c.DiscountTotal)
+	c.Profit = c.GrandTotal.Sub(cost)
+
+	day := func(offset int) time.Time {
+		at := base.AddDate(0, 0, offset-fixture.StartedDaysAgo)
+		if at.After(base) {
+			return base
+		}
+		return at
+	}
+	c.QuotedAt, c.UpdatedAt = day(0), day(0)
+	c.QuotationStatus = models.QuotationStatusSent
+	if rank >= chainStages[stageSalesOrder] {
+		c.QuotationStatus, c.OrderStatus = models.QuotationStatusAccepted, models.SOStatusConfirmed
+		c.OrderedAt, c.UpdatedAt = day(orderAfterDays), day(orderAfterDays)
+	}
+	if rank >= chainStages[stageDelivery] {
+		c.OrderStatus, c.DeliveryStatus = models.SOStatusProcessing, models.DeliveryStatusShipped
+		c.ShippedAt, c.UpdatedAt = day(shipAfterDays), day(shipAfterDays)
+	}
+	if rank >= chainStages[stageInvoicing] {
+		c.DeliveryStatus, c.DeliveredAt = models.DeliveryStatusDelivered, day(deliverAfterDays)
+		c.DueAt = c.DeliveredAt.AddDate(0, 0, fixture.TermDays)
+		c.UpdatedAt = c.DeliveredAt
+		c.InvoiceStatus = models.InvoiceStatusSent
+		if c.DueAt.Before(base) {
+			c.InvoiceStatus = models.InvoiceStatusOverdue
+		}
+	}
+	if rank >= chainStages[stagePayment] {
+		c.PaidAt = day(deliverAfterDays + fixture.TermDays/2)
+		c.UpdatedAt = c.PaidAt
+		c.Paid = money.Round(c.GrandTotal.MulInt(fixture.PaidPercent).DivInt(100))
+		if c.InvoiceStatus != models.InvoiceStatusOverdue {
+			c.InvoiceStatus = models.InvoiceStatusPartial
+		}
+	}
+	if rank == chainStages[stageCompleted] {
+		c.Paid, c.InvoiceStatus, c.OrderStatus = c.GrandTotal, models.InvoiceStatusPaid, models.SOStatusCompleted
+	}
+	return c, nil
+}
+
+// ExecuteProfile carrega o perfil na empresa padrão, numa transação: os contatos, os produtos e
+// os processos de venda com os documentos. O perfil não é carregado duas vezes sobre os mesmos
+// dados (use erpctl wipe antes para recarregá-lo).
+func ExecuteProfile(db *sql.DB, name string, now time.Time) error {
+	profile, err := LookupProfile(name)
+	if err != nil {
+		return err
+	}
+	base := time.Date(now.Year(), now.Month(), now.Day(), 10, 0, 0, 0, time.UTC)
+
+	chains := make([]chain, len(profile.Processes))
+	for i, fixture := range profile.Processes {
+		if chains[i], err = planChain(profile, fixture, base); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[seeds:profiles] Carregando o perfil %q: %d contatos, %d produtos, %d processos...",
+		profile.Name, len(profile.Contacts), len(profile.Products), len(chains))
+	startTime := time.Now()
+
+	var exists bool
+	err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM quotations WHERE quotation_no LIKE $1)", "QT-"+profile.Prefix+"-%").Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("[seeds:profiles] Erro ao verificar cargas anteriores do perfil: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("[seeds:profiles] o perfil %q já foi carregado; use --wipe para recarregá-lo", profile.Name)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("[seeds:profiles] Erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	contactIDs := make([]int, len(profile.Contacts))
+	for i, contact := range profile.Contacts {
+		err := tx.QueryRow(`INSERT INTO contacts (person_type, type, name, document, email, city, state, created_at, updated_at)
+			VALUES ($1, 'cliente', $2, $3, $4, $5, $6, $7, $7) RETURNING id`,
+			contact.PersonType, contact.Name, contact.Document, contact.Email, contact.City, contact.State, base).Scan(&contactIDs[i])
+		if err != nil {
+			return fmt.Errorf("[seeds:profiles] Erro ao inserir contato %q: %w", contact.Name, err)
+		}
+	}
+
+	productIDs := make(map[string]int, len(profile.Products))
+	for _, product := range profile.Products {
+		var id int
+		err := tx.QueryRow(`INSERT INTO products (name, detailed_name, status, sku, coin, price, sales_price, cost_price, stock, created_at, updated_at)
+			VALUES ($1, $1, 'ativo', $2, $3, $4, $4, $5, $6, $7, $7) RETURNING id`,
+			product.Name, product.SKU, product.Coin, product.Price, product.Cost, product.Stock, base).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("[seeds:profiles] Erro ao inserir produto %q: %w", product.Name, err)
+		}
+		productIDs[product.SKU] = id
+	}
+
+	for i, c := range chains {
+		number := func(kind string) string { return fmt.Sprintf("%s-%s-%04d", kind, profile.Prefix, i+1) }
+		if err := insertChain(tx, c, contactIDs[c.Fixture.Contact], productIDs, number); err != nil {
+			return fmt.Errorf("[seeds:profiles] Erro ao inserir o processo %s: %w", number("QT"), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("[seeds:profiles] Erro ao confirmar a carga do perfil: %w", err)
+	}
+	log.Printf("[seeds:profiles] Perfil %q carregado em %v.", profile.Name, time.Since(startTime))
+	return nil
+}
+
+// insertChain grava o processo de venda e os documentos já emitidos na etapa, ligados entre si
+// e ao processo
+func insertChain(tx *sql.Tx, c chain, contactID int, productIDs map[string]int, number func(kind string) string) error {
+	var processID, quotationID int
+	err := tx.QueryRow(`INSERT INTO sales_processes (contact_id, status, total_value, profit, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		contactID, c.Fixture.Stage, c.GrandTotal, c.Profit, "Processo de demonstração", c.QuotedAt, c.UpdatedAt).Scan(&processID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`INSERT INTO quotations (quotation_no, contact_id, status, expiry_date, subtotal, tax_total, discount_total,
+			grand_total, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $8, $8) RETURNING id`,
+		number("QT"), contactID, c.QuotationStatus, c.QuotedAt.AddDate(0, 0, quotationValidDays),
+		c.SubTotal, c.DiscountTotal, c.GrandTotal, c.QuotedAt).Scan(&quotationID)
+	if err != nil {
+		return err
+	}
+	if err := insertItems(tx, "quotation_items", "quotation_id", quotationID, c.Lines, productIDs, nil); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO process_quotations (process_id, quotation_id) VALUES ($1, $2)", processID, quotationID); err != nil {
+		return err
+	}
+	if c.OrderStatus == "" {
+		return nil
+	}
+
+	var orderID int
+	err = tx.QueryRow(`INSERT INTO sales_orders (so_no, quotation_id, contact_id, status, expected_date, subtotal, tax_total,
+			discount_total, grand_total, payment_terms, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8, $9, $10, $10) RETURNING id`,
+		number("SO"), quotationID, contactID, c.OrderStatus, c.OrderedAt.AddDate(0, 0, deliverAfterDays-orderAfterDays),
+		c.SubTotal, c.DiscountTotal, c.GrandTotal, fmt.Sprintf("%d dias", c.Fixture.TermDays), c.OrderedAt).Scan(&orderID)
+	if err != nil {
+		return err
+	}
+	orderItemIDs := make([]int, len(c.Lines))
+	if err := insertItems(tx, "sales_order_items", "sales_order_id", orderID, c.Lines, productIDs, orderItemIDs); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO process_sales_orders (process_id, sales_order_id) VALUES ($1, $2)", processID, orderID); err != nil {
+		return err
+	}
+	if c.DeliveryStatus == "" {
+		return nil
+	}
+
+	var deliveryID int
+	var deliveredAt *time.Time
+	if !c.DeliveredAt.IsZero() {
+		deliveredAt = &c.DeliveredAt
+	}
+	err = tx.QueryRow(`INSERT INTO deliveries (delivery_no, sales_order_id, so_no, status, delivery_date, received_date,
+			shipping_method, shipped_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'Transportadora', $5, $5, $7) RETURNING id`,
+		number("DLV"), orderID, number("SO"), c.DeliveryStatus, c.ShippedAt, deliveredAt, c.UpdatedAt).Scan(&deliveryID)
+	if err != nil {
+		return err
+	}
+	for i, line := range c.Lines {
+		received := 0
+		if deliveredAt != nil {
+			received = line.Quantity
+		}
+		_, err := tx.Exec(`INSERT INTO delivery_items (delivery_id, so_item_id, product_id, product_name, product_code, quantity,
+				received_qty, picked_qty)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $6)`,
+			deliveryID, orderItemIDs[i], productIDs[line.Product.SKU], line.Product.Name, line.Product.SKU, line.Quantity, received)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("INSERT INTO process_deliveries (process_id, delivery_id) VALUES ($1, $2)", processID, deliveryID); err != nil {
+		return err
+	}
+	if c.InvoiceStatus == "" {
+		return nil
+	}
+
+	var invoiceID int
+	err = tx.QueryRow(`INSERT INTO invoices (invoice_no, sales_order_id, so_no, contact_id, status, issue_date, due_date, subtotal,
+			tax_total, discount_total, grand_total, amount_paid, payment_terms, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 0, $9, $10, $11, $12, $6, $13) RETURNING id`,
+		number("INV"), orderID, number("SO"), contactID, c.InvoiceStatus, c.DeliveredAt, c.DueAt, c.SubTotal,
+		c.DiscountTotal, c.GrandTotal, c.Paid, fmt.Sprintf("%d dias", c.Fixture.TermDays), c.UpdatedAt).Scan(&invoiceID)
+	if err != nil {
+		return err
+	}
+	if err := insertItems(tx, "invoice_items", "invoice_id", invoiceID, c.Lines, productIDs, nil); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO process_invoices (process_id, invoice_id) VALUES ($1, $2)", processID, invoiceID); err != nil {
+		return err
+	}
+	if c.Paid.IsPositive() {
+		_, err := tx.Exec(`INSERT INTO payments (invoice_id, amount, payment_date, payment_method, reference)
+			VALUES ($1, $2, $3, 'pix', $4)`, invoiceID, c.Paid, c.PaidAt, "PIX-"+number("INV"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertItems grava os itens do documento; com ids, guarda os ids gerados na ordem das linhas
+func insertItems(tx *sql.Tx, table, parentColumn string, parentID int, lines []chainLine, productIDs map[string]int, ids []int) error {
+	query := fmt.Sprintf(`INSERT INTO %s (%s, product_id, product_name, product_code, description, quantity, unit_price,
+			discount, tax, total)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 0, $9) RETURNING id`, table, parentColumn)
+	for i, line := range lines {
+		var id int
+		err := tx.QueryRow(query, parentID, productIDs[line.Product.SKU], line.Product.Name, line.Product.SKU, line.Note,
+			line.Quantity, line.UnitPrice, line.Discount, line.Total).Scan(&id)
+		if err != nil {
+			return err
+		}
+		if ids != nil {
+			ids[i] = id
+		}
+	}
+	return nil
+}
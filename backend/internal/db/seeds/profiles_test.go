@@ -0,0 +1,115 @@
+package seeds
+
+import (
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBase = time.Date(2026, 5, 20, 10, 0, 0, 0, time.UTC)
+
+func TestLookupProfile(t *testing.T) {
+	assert.Equal(t, []string{"demo-retail", "multi-currency", "overdue-invoices"}, ProfileNames())
+
+	profile, err := LookupProfile("demo-retail")
+	require.NoError(t, err)
+	assert.Equal(t, "DEMO", profile.Prefix)
+
+	_, err = LookupProfile("desconhecido")
+	assert.ErrorContains(t, err, "demo-retail, multi-currency, overdue-invoices")
+}
+
+func TestProfilesPlanValidChains(t *testing.T) {
+	prefixes := map[string]string{}
+	for _, name := range ProfileNames() {
+		profile, _ := LookupProfile(name)
+		if other, ok := prefixes[profile.Prefix]; ok {
+			t.Errorf("perfis %s e %s com o mesmo prefixo %s", other, name, profile.Prefix)
+		}
+		prefixes[profile.Prefix] = name
+
+		for i, fixture := range profile.Processes {
+			c, err := planChain(profile, fixture, testBase)
+			require.NoError(t, err, "%s: processo %d", name, i+1)
+			assert.True(t, c.GrandTotal.IsPositive(), "%s: processo %d sem valor", name, i+1)
+			assert.False(t, c.UpdatedAt.After(testBase), "%s: processo %d com data futura", name, i+1)
+		}
+	}
+}
+
+func TestPlanChainStages(t *testing.T) {
+	profile := Profile{
+		Name:     "teste",
+		Contacts: []ContactFixture{{Name: "Cliente"}},
+		Products: []ProductFixture{
+			{Name: "Produto", SKU: "P1", Coin: "BRL", Price: money.MustParse("100.00"), Cost: money.MustParse("60.00")},
+			{Name: "Importado", SKU: "P2", Coin: "USD", Price: money.MustParse("10.00"), Cost: money.MustParse("5.00")},
+		},
+		Rates: map[string]money.Decimal{"USD": money.MustParse("5.50")},
+	}
+	items := []ItemFixture{{Product: 0, Quantity: 3, DiscountPercent: 10}, {Product: 1, Quantity: 2}}
+
+	t.Run("cotação enviada", func(t *testing.T) {
+		c, err := planChain(profile, ProcessFixture{Stage: stageQuotation, Items: items, StartedDaysAgo: 1}, testBase)
+		require.NoError(t, err)
+		assert.Equal(t, "410.00", c.SubTotal.StringFixed(2))
+		assert.Equal(t, "30.00", c.DiscountTotal.StringFixed(2))
+		assert.Equal(t, "380.00", c.GrandTotal.StringFixed(2))
+		assert.Equal(t, "145.00", c.Profit.StringFixed(2))
+		assert.Equal(t, "55.00", c.Lines[1].UnitPrice.StringFixed(2))
+		assert.Contains(t, c.Lines[1].Note, "USD 10.00")
+		assert.Equal(t, models.QuotationStatusSent, c.QuotationStatus)
+		assert.Empty(t, c.OrderStatus)
+	})
+
+	t.Run("fatura vencida paga em parte", func(t *testing.T) {
+		c, err := planChain(profile, ProcessFixture{Stage: stagePayment, Items: items,
+			StartedDaysAgo: 60, TermDays: 30, PaidPercent: 50}, testBase)
+		require.NoError(t, err)
+		assert.Equal(t, models.QuotationStatusAccepted, c.QuotationStatus)
+		assert.Equal(t, models.SOStatusProcessing, c.OrderStatus)
+		assert.Equal(t, models.DeliveryStatusDelivered, c.DeliveryStatus)
+		assert.Equal(t, models.InvoiceStatusOverdue, c.InvoiceStatus)
+		assert.Equal(t, "190.00", c.Paid.StringFixed(2))
+		assert.Equal(t, testBase.AddDate(0, 0, -23), c.DueAt)
+	})
+
+	t.Run("processo concluído", func(t *testing.T) {
+		c, err := planChain(profile, ProcessFixture{Stage: stageCompleted, Items: items,
+			StartedDaysAgo: 60, TermDays: 30}, testBase)
+		require.NoError(t, err)
+		assert.Equal(t, models.InvoiceStatusPaid, c.InvoiceStatus)
+		assert.Equal(t, models.SOStatusCompleted, c.OrderStatus)
+		assert.True(t, c.Paid.Equal(c.GrandTotal))
+	})
+
+	t.Run("etapa sem documentos", func(t *testing.T) {
+		_, err := planChain(profile, ProcessFixture{Stage: "draft", Items: items}, testBase)
+		assert.Error(t, err)
+	})
+
+	t.Run("moeda sem taxa", func(t *testing.T) {
+		noRates := profile
+		noRates.Rates = nil
+		_, err := planChain(noRates, ProcessFixture{Stage: stageQuotation, Items: items}, testBase)
+		assert.ErrorContains(t, err, "USD")
+	})
+}
+
+func TestWipeTables(t *testing.T) {
+	tables := []string{"users", "quotations", "schema_migrations", "contacts", "companies", "ledger_accounts", "invoices"}
+	assert.Equal(t, []string{"contacts", "invoices", "quotations"}, WipeTables(tables))
+}
+
+func TestCheckWipeAllowed(t *testing.T) {
+	assert.NoError(t, CheckWipeAllowed("development"))
+	assert.NoError(t, CheckWipeAllowed("test"))
+	assert.Error(t, CheckWipeAllowed("staging"))
+	assert.Error(t, CheckWipeAllowed("production"))
+	assert.Error(t, CheckWipeAllowed(""))
+}
@@ -1,6 +1,7 @@
 package seeds
 
 import (
+	"ERP-ONSMART/backend/internal/money"
 	"database/sql"
 	"fmt"
 	"log"
@@ -71,15 +72,20 @@ func SeedTransactions(db *sql.DB, count int) error {
 			amount = -gofakeit.Price(100, 5000) // Despesa (valor negativo)
 		}
 
+		value, err := money.FromFloat(amount)
+		if err != nil {
+			return fmt.Errorf("[seeds:transactions] Valor inválido para a transação #%d: %w", i+1, err)
+		}
+
 		// Cria a transação usando o modelo
 		transaction := models.Transaction{
 			Description: descriptions[gofakeit.Number(0, len(descriptions)-1)],
-			Amount:      amount,
+			Amount:      value,
 			Date:        formattedDateForDB,
 		}
 
 		// Insere no banco usando o formato que o PostgreSQL espera
-		_, err := stmt.Exec(
+		_, err = stmt.Exec(
 			transaction.Description,
 			transaction.Amount,
 			transaction.Date,
@@ -0,0 +1,117 @@
+package seeds
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// preservedTables não são apagadas por Wipe: o controle das migrações, as empresas, os usuários
+// e os acessos, as feature flags, as configurações que os dados de negócio usam (plano de
+// contas, centros de custo, calendários, SLAs, regras, modelos e relatórios), os caches de
+// consultas externas e o histórico dos backups
+var preservedTables = map[string]bool{
+	"schema_migrations":        true,
+	"companies":                true,
+	"users":                    true,
+	"user_invitations":         true,
+	"password_reset_tokens":    true,
+	"api_keys":                 true,
+	"api_key_usage":            true,
+	"feature_flags":            true,
+	"field_permissions":        true,
+	"custom_field_definitions": true,
+	"document_templates":       true,
+	"ledger_accounts":          true,
+	"ledger_account_mappings":  true,
+	"cost_centers":             true,
+	"departments":              true,
+	"expense_categories":       true,
+	"business_calendars":       true,
+	"holidays":                 true,
+	"sla_definitions":          true,
+	"followup_rules":           true,
+	"commission_rules":         true,
+	"ecommerce_channels":       true,
+	"edi_partners":             true,
+	"etl_mappings":             true,
+	"file_exchange_endpoints":  true,
+	"reports":                  true,
+	"report_schedules":         true,
+	"cnpj_lookups":             true,
+	"cep_lookups":              true,
+	"backup_jobs":              true,
+	"tenant_exports":           true,
+}
+
+// WipeTables retorna, em ordem alfabética, as tabelas de tables que Wipe apaga
+func WipeTables(tables []string) []string {
+	var wiped []string
+	for _, table := range tables {
+		if !preservedTables[table] {
+			wiped = append(wiped, table)
+		}
+	}
+	sort.Strings(wiped)
+	return wiped
+}
+
+// wipeEnvs são os ambientes em que Wipe pode apagar os dados
+var wipeEnvs = map[string]bool{"development": true, "test": true}
+
+// CheckWipeAllowed recusa apagar os dados fora dos ambientes de desenvolvimento e de testes
+func CheckWipeAllowed(env string) error {
+	if !wipeEnvs[env] {
+		return fmt.Errorf("[seeds:wipe] Apagar os dados só é permitido em development ou test (ENV=%q)", env)
+	}
+	return nil
+}
+
+// Wipe apaga os dados de negócio de todas as empresas, reiniciando as sequências, antes de
+// carregar um perfil do zero; env é o ambiente da configuração (ENV) e só development e test são
+// aceitos. As tabelas são truncadas num único comando e sem CASCADE: uma tabela preservada que
+// referencie uma apagada faz o comando falhar em vez de ser esvaziada.
+func Wipe(db *sql.DB, env string) error {
+	if err := CheckWipeAllowed(env); err != nil {
+		return err
+	}
+	log.Println("[seeds:wipe] Apagando os dados de negócio...")
+
+	rows, err := db.Query(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'`)
+	if err != nil {
+		return fmt.Errorf("[seeds:wipe] Erro ao listar as tabelas: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return fmt.Errorf("[seeds:wipe] Erro ao ler as tabelas: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("[seeds:wipe] Erro ao ler as tabelas: %w", err)
+	}
+
+	wiped := WipeTables(tables)
+	if len(wiped) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(wiped))
+	for i, table := range wiped {
+		quoted[i] = pq.QuoteIdentifier(table)
+	}
+	if _, err := db.Exec("TRUNCATE TABLE " + strings.Join(quoted, ", ") + " RESTART IDENTITY"); err != nil {
+		return fmt.Errorf("[seeds:wipe] Erro ao apagar as tabelas: %w", err)
+	}
+
+	log.Printf("[seeds:wipe] %d tabelas apagadas.", len(wiped))
+	return nil
+}
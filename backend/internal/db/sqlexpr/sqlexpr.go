@@ -0,0 +1,32 @@
+// Package sqlexpr monta trechos de SQL que variam entre os bancos suportados pelo GORM, para que
+// os repositórios não dependam de funções de um dialeto específico (ex.: JULIANDAY do SQLite).
+package sqlexpr
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DaysBetween retorna a expressão com a diferença em dias, com fração, entre duas colunas de
+// data/hora (end - start). O PostgreSQL é o padrão; o SQLite é mantido para testes locais.
+func DaysBetween(db *gorm.DB, start, end string) string {
+	switch dialect(db) {
+	case "sqlite":
+		return fmt.Sprintf("(JULIANDAY(%s) - JULIANDAY(%s))", end, start)
+	default:
+		return fmt.Sprintf("(EXTRACT(EPOCH FROM (%s - %s)) / 86400.0)", end, start)
+	}
+}
+
+// AvgDaysBetween retorna a média de DaysBetween, zero quando não há linhas
+func AvgDaysBetween(db *gorm.DB, start, end string) string {
+	return fmt.Sprintf("COALESCE(AVG(%s), 0)", DaysBetween(db, start, end))
+}
+
+func dialect(db *gorm.DB) string {
+	if db == nil || db.Dialector == nil {
+		return ""
+	}
+	return db.Dialector.Name()
+}
@@ -0,0 +1,33 @@
+package sqlexpr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type sqliteDialector struct {
+	postgres.Dialector
+}
+
+func (sqliteDialector) Name() string {
+	return "sqlite"
+}
+
+func TestDaysBetween(t *testing.T) {
+	pg := &gorm.DB{Config: &gorm.Config{Dialector: postgres.Dialector{}}}
+	sqlite := &gorm.DB{Config: &gorm.Config{Dialector: sqliteDialector{}}}
+
+	assert.Equal(t, "(EXTRACT(EPOCH FROM (updated_at - created_at)) / 86400.0)", DaysBetween(pg, "created_at", "updated_at"))
+	assert.Equal(t, "(JULIANDAY(updated_at) - JULIANDAY(created_at))", DaysBetween(sqlite, "created_at", "updated_at"))
+	assert.Equal(t, DaysBetween(pg, "a", "b"), DaysBetween(nil, "a", "b"), "sem dialeto usa o PostgreSQL")
+}
+
+func TestAvgDaysBetween(t *testing.T) {
+	pg := &gorm.DB{Config: &gorm.Config{Dialector: postgres.Dialector{}}}
+
+	assert.Equal(t, "COALESCE(AVG((EXTRACT(EPOCH FROM (received_date - delivery_date)) / 86400.0)), 0)",
+		AvgDaysBetween(pg, "delivery_date", "received_date"))
+}
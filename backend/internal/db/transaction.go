@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// TxManager coordena uma unidade de trabalho: as chamadas de repositório feitas com o contexto
+// recebido por fn compartilham a mesma transação, confirmada só quando fn termina sem erro.
+// Assim um fluxo do serviço (converter a cotação, gerar a entrega, vincular o processo...) não
+// deixa metade dos registros gravados quando uma etapa falha.
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager abre a conexão usada pelas unidades de trabalho
+func NewTxManager() (TxManager, error) {
+	gormDB, err := OpenGormDB()
+	if err != nil {
+		return nil, err
+	}
+	return NewTxManagerWithDB(gormDB), nil
+}
+
+// NewTxManagerWithDB cria o coordenador sobre uma conexão já aberta
+func NewTxManagerWithDB(gormDB *gorm.DB) TxManager {
+	return &txManager{db: gormDB}
+}
+
+// WithinTransaction executa fn em uma transação. Dentro de uma unidade de trabalho já aberta,
+// fn roda em um savepoint da transação externa, que decide o commit.
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx.Transaction(func(nested *gorm.DB) error {
+			return fn(WithTx(ctx, nested))
+		})
+	}
+
+	tx, committer, err := BeginUnitOfWork(ctx, m.db)
+	if err != nil {
+		return err
+	}
+
+	panicked := true
+	defer func() {
+		if panicked || err != nil {
+			_ = committer.Rollback()
+		}
+	}()
+
+	err = fn(WithTx(ctx, tx))
+	panicked = false
+	if err != nil {
+		return err
+	}
+	if err := committer.Commit(); err != nil {
+		return fmt.Errorf("[transaction.go]: erro ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// BeginUnitOfWork abre a transação de uma unidade de trabalho: o Begin e o Transaction feitos
+// sobre ela viram savepoints, e só o TxCommitter devolvido confirma ou desfaz a transação.
+// WithinTransaction a usa; os testes de integração também, para desfazer tudo ao final.
+func BeginUnitOfWork(ctx context.Context, gormDB *gorm.DB) (*gorm.DB, gorm.TxCommitter, error) {
+	tx := gormDB.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, nil, fmt.Errorf("[transaction.go]: erro ao iniciar transação: %w", tx.Error)
+	}
+	committer, ok := tx.Statement.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("[transaction.go]: conexão sem suporte a transação")
+	}
+	tx.Statement.ConnPool = &unitOfWork{ConnPool: tx.Statement.ConnPool}
+	return tx, committer, nil
+}
+
+type txKey struct{}
+
+// WithTx devolve um contexto que carrega a transação da unidade de trabalho
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext retorna a transação da unidade de trabalho do contexto, ou nil fora de uma
+func TxFromContext(ctx context.Context) *gorm.DB {
+	tx, _ := ctx.Value(txKey{}).(*gorm.DB)
+	return tx
+}
+
+// Conn é a conexão que o repositório deve usar com o contexto da requisição: a transação da
+// unidade de trabalho quando houver uma, ou a conexão do próprio repositório. O Begin e o
+// Transaction feitos sobre ela dentro de uma unidade de trabalho viram savepoints.
+func Conn(ctx context.Context, fallback *gorm.DB) *gorm.DB {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return fallback.WithContext(ctx)
+}
+
+// unitOfWork é a conexão da transação de uma unidade de trabalho. O Begin de um repositório
+// abre um savepoint em vez de uma nova transação; Commit e Rollback só liberam ou desfazem o
+// savepoint, e a transação de fato é confirmada ou desfeita por WithinTransaction.
+type unitOfWork struct {
+	gorm.ConnPool
+	savepoint string
+	depth     *int
+}
+
+// BeginTx abre um savepoint na transação da unidade de trabalho
+func (u *unitOfWork) BeginTx(ctx context.Context, _ *sql.TxOptions) (gorm.ConnPool, error) {
+	depth := u.depth
+	if depth == nil {
+		depth = new(int)
+		u.depth = depth
+	}
+	*depth++
+	name := fmt.Sprintf("uow_%d", *depth)
+	if _, err := u.ConnPool.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &unitOfWork{ConnPool: u.ConnPool, savepoint: name, depth: depth}, nil
+}
+
+// Commit libera o savepoint do repositório
+func (u *unitOfWork) Commit() error {
+	if u.savepoint == "" {
+		return nil
+	}
+	_, err := u.ConnPool.ExecContext(context.Background(), "RELEASE SAVEPOINT "+u.savepoint)
+	return err
+}
+
+// Rollback desfaz só o que o repositório gravou desde o savepoint
+func (u *unitOfWork) Rollback() error {
+	if u.savepoint == "" {
+		return nil
+	}
+	_, err := u.ConnPool.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+u.savepoint)
+	return err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func mockTxDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+	return gormDB, mock
+}
+
+func TestWithinTransactionSharesTransactionWithRepositories(t *testing.T) {
+	gormDB, mock := mockTxDB(t)
+	repoDB, _ := mockTxDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE quotations").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SAVEPOINT uow_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO sales_orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT uow_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := NewTxManagerWithDB(gormDB).WithinTransaction(context.Background(), func(ctx context.Context) error {
+		if err := Conn(ctx, repoDB).Exec("UPDATE quotations SET status = 'accepted'").Error; err != nil {
+			return err
+		}
+
+		// repositório que abre a própria transação
+		tx := Conn(ctx, repoDB).Begin()
+		if err := tx.Exec("INSERT INTO sales_orders (id) VALUES (1)").Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit().Error
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTransactionRollsBackWhenAStepFails(t *testing.T) {
+	gormDB, mock := mockTxDB(t)
+	stepErr := errors.New("estoque insuficiente")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO sales_orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("SAVEPOINT uow_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT uow_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := NewTxManagerWithDB(gormDB).WithinTransaction(context.Background(), func(ctx context.Context) error {
+		if err := Conn(ctx, gormDB).Exec("INSERT INTO sales_orders (id) VALUES (1)").Error; err != nil {
+			return err
+		}
+		tx := Conn(ctx, gormDB).Begin()
+		tx.Rollback()
+		return stepErr
+	})
+
+	assert.ErrorIs(t, err, stepErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConnOutsideUnitOfWorkUsesRepositoryConnection(t *testing.T) {
+	gormDB, _ := mockTxDB(t)
+
+	assert.Nil(t, TxFromContext(context.Background()))
+	assert.Same(t, gormDB.ConnPool, Conn(context.Background(), gormDB).Statement.ConnPool)
+}
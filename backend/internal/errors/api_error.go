@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"net/http"
+
+	"ERP-ONSMART/backend/internal/i18n"
+)
+
+// Código dos erros sem entrada no catálogo
+const (
+	CodeInternal         = "internal_error"
+	CodeInvalidParameter = "invalid_parameter"
+)
+
+// FieldError descreve um campo inválido da requisição
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError é o envelope padrão das respostas de erro da API
+type APIError struct {
+	Status      int          `json:"-"`
+	Code        string       `json:"code"`
+	Message     string       `json:"message"`
+	Details     string       `json:"details,omitempty"`
+	FieldErrors []FieldError `json:"field_errors,omitempty"`
+
+	cause error
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// Unwrap expõe o erro de origem (ex.: erros de validação do binding)
+func (e *APIError) Unwrap() error {
+	return e.cause
+}
+
+// NewAPIError cria um erro de API com status e código explícitos
+func NewAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// WithDetails retorna uma cópia do erro com o detalhe informado
+func (e *APIError) WithDetails(details string) *APIError {
+	result := *e
+	result.Details = details
+	return &result
+}
+
+// WithFieldError retorna uma cópia do erro acrescentando um campo inválido
+func (e *APIError) WithFieldError(field, message string) *APIError {
+	result := *e
+	result.FieldErrors = append(append([]FieldError{}, e.FieldErrors...), FieldError{Field: field, Message: message})
+	return &result
+}
+
+// Localize retorna uma cópia do erro com a mensagem no idioma informado. Fora do português, a
+// mensagem original, com o contexto acrescentado pelo serviço, passa para details (exceto quando
+// os field_errors já explicam o erro).
+func (e *APIError) Localize(lang string) *APIError {
+	message, ok := i18n.ErrorMessage(lang, e.Code)
+	if !ok || message == e.Message {
+		return e
+	}
+	result := *e
+	result.Message = message
+	if result.Details == "" && len(result.FieldErrors) == 0 {
+		result.Details = e.Message
+	}
+	return &result
+}
+
+// InvalidRequest indica corpo ou parâmetros que não passaram no binding/validação
+func InvalidRequest(err error) *APIError {
+	return &APIError{
+		Status:  http.StatusBadRequest,
+		Code:    catalog[ErrInvalidRequest].code,
+		Message: ErrInvalidRequest.Error(),
+		Details: err.Error(),
+		cause:   err,
+	}
+}
+
+// InvalidParam indica um parâmetro de rota ou de consulta inválido
+func InvalidParam(message string) *APIError {
+	return NewAPIError(http.StatusBadRequest, CodeInvalidParameter, message)
+}
+
+// ToAPIError converte qualquer erro no envelope padrão. Erros fora do catálogo viram
+// 500 com a mensagem de contexto informada e o erro original em details.
+func ToAPIError(err error, fallback string) *APIError {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr
+	}
+	if status, code, ok := Lookup(err); ok {
+		return &APIError{Status: status, Code: code, Message: err.Error(), cause: err}
+	}
+
+	if fallback == "" {
+		fallback = "erro interno do servidor"
+	}
+	return &APIError{
+		Status:  http.StatusInternalServerError,
+		Code:    CodeInternal,
+		Message: fallback,
+		Details: err.Error(),
+		cause:   err,
+	}
+}
@@ -0,0 +1,521 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// catalogEntry associa um erro sentinela ao status HTTP e ao código legível por máquina
+type catalogEntry struct {
+	status int
+	code   string
+}
+
+// Catálogo dos erros sentinela expostos pela API
+var catalog = map[error]catalogEntry{
+	// Banco de dados
+	ErrDatabaseConnection: {http.StatusServiceUnavailable, "database_unavailable"},
+	ErrTransactionFailed:  {http.StatusInternalServerError, "transaction_failed"},
+
+	// Validação
+	ErrInvalidPagination:     {http.StatusBadRequest, "invalid_pagination"},
+	ErrInvalidDateRange:      {http.StatusBadRequest, "invalid_date_range"},
+	ErrInvalidID:             {http.StatusBadRequest, "invalid_id"},
+	ErrInvalidRequest:        {http.StatusBadRequest, "invalid_request"},
+	ErrInvalidSortField:      {http.StatusBadRequest, "invalid_sort_field"},
+	ErrInvalidFieldSelection: {http.StatusBadRequest, "invalid_field_selection"},
+	money.ErrOverflow:        {http.StatusBadRequest, "money_out_of_range"},
+	money.ErrInvalid:         {http.StatusBadRequest, "invalid_money_value"},
+
+	// Autenticação e autorização
+	ErrMissingToken:       {http.StatusUnauthorized, "missing_token"},
+	ErrInvalidToken:       {http.StatusUnauthorized, "invalid_token"},
+	ErrForbidden:          {http.StatusForbidden, "forbidden"},
+	ErrInvalidCredentials: {http.StatusUnauthorized, "invalid_credentials"},
+	ErrUserNotFound:       {http.StatusNotFound, "user_not_found"},
+
+	// Entidades não encontradas
+	ErrQuotationNotFound:     {http.StatusNotFound, "quotation_not_found"},
+	ErrSalesOrderNotFound:    {http.StatusNotFound, "sales_order_not_found"},
+	ErrPurchaseOrderNotFound: {http.StatusNotFound, "purchase_order_not_found"},
+	ErrDeliveryNotFound:      {http.StatusNotFound, "delivery_not_found"},
+	ErrInvoiceNotFound:       {http.StatusNotFound, "invoice_not_found"},
+	ErrPaymentNotFound:       {http.StatusNotFound, "payment_not_found"},
+	ErrSalesProcessNotFound:  {http.StatusNotFound, "sales_process_not_found"},
+	ErrDeliveryItemNotFound:  {http.StatusNotFound, "delivery_item_not_found"},
+	ErrContactNotFound:       {http.StatusNotFound, "contact_not_found"},
+	ErrSupplierBillNotFound:  {http.StatusNotFound, "supplier_bill_not_found"},
+	ErrBankStatementNotFound: {http.StatusNotFound, "bank_statement_not_found"},
+	ErrBankLineNotFound:      {http.StatusNotFound, "bank_line_not_found"},
+	ErrCreditNoteNotFound:    {http.StatusNotFound, "credit_note_not_found"},
+	ErrLedgerAccountNotFound: {http.StatusNotFound, "ledger_account_not_found"},
+	ErrJournalEntryNotFound:  {http.StatusNotFound, "journal_entry_not_found"},
+	ErrProductNotFound:       {http.StatusNotFound, "product_not_found"},
+	ErrReturnNotFound:        {http.StatusNotFound, "return_not_found"},
+	ErrSaleNotFound:          {http.StatusNotFound, "sale_not_found"},
+	ErrTransactionNotFound:   {http.StatusNotFound, "transaction_not_found"},
+	ErrDropshippingNotFound:  {http.StatusNotFound, "dropshipping_not_found"},
+	ErrCampaignNotFound:      {http.StatusNotFound, "campaign_not_found"},
+	ErrServiceOrderNotFound:  {http.StatusNotFound, "service_order_not_found"},
+	ErrVariantNotFound:       {http.StatusNotFound, "variant_not_found"},
+	ErrSerialNotFound:        {http.StatusNotFound, "serial_not_found"},
+	ErrLotNotFound:           {http.StatusNotFound, "lot_not_found"},
+	ErrKitNotFound:           {http.StatusNotFound, "kit_not_found"},
+	ErrBinNotFound:           {http.StatusNotFound, "bin_not_found"},
+	ErrScanCodeNotFound:      {http.StatusNotFound, "scan_code_not_found"},
+
+	// Lógica de negócio
+	ErrRelatedRecordsExist: {http.StatusConflict, "related_records_exist"},
+
+	// Conciliação bancária
+	ErrUnsupportedStatementFormat: {http.StatusBadRequest, "unsupported_statement_format"},
+	ErrEmptyStatement:             {http.StatusBadRequest, "empty_statement"},
+	ErrLineAlreadyReconciled:      {http.StatusConflict, "line_already_reconciled"},
+	ErrNoMatchSuggestion:          {http.StatusBadRequest, "no_match_suggestion"},
+	ErrInvalidMatchTarget:         {http.StatusBadRequest, "invalid_match_target"},
+
+	// Contabilidade
+	ErrUnbalancedEntry:       {http.StatusBadRequest, "unbalanced_entry"},
+	ErrAccountMappingMissing: {http.StatusBadRequest, "account_mapping_missing"},
+	ErrDocumentAlreadyPosted: {http.StatusConflict, "document_already_posted"},
+	ErrDocumentNotPostable:   {http.StatusBadRequest, "document_not_postable"},
+	ErrInvalidAccountType:    {http.StatusBadRequest, "invalid_account_type"},
+	ErrDuplicateAccountCode:  {http.StatusConflict, "duplicate_account_code"},
+	ErrInvalidDREGroup:       {http.StatusBadRequest, "invalid_dre_group"},
+
+	// Custeio de estoque
+	ErrInvalidCostingMethod:       {http.StatusBadRequest, "invalid_costing_method"},
+	ErrPurchaseOrderNotReceivable: {http.StatusBadRequest, "purchase_order_not_receivable"},
+	ErrDeliveryNotOutbound:        {http.StatusBadRequest, "delivery_not_outbound"},
+
+	// Rastreabilidade
+	ErrEmptyVariantMatrix:    {http.StatusBadRequest, "empty_variant_matrix"},
+	ErrDuplicateVariantSKU:   {http.StatusConflict, "duplicate_variant_sku"},
+	ErrReceiptItemNotInOrder: {http.StatusBadRequest, "receipt_item_not_in_order"},
+	ErrTooManySerials:        {http.StatusBadRequest, "too_many_serials"},
+	ErrDuplicateSerial:       {http.StatusBadRequest, "duplicate_serial"},
+	ErrSerialAlreadyReceived: {http.StatusConflict, "serial_already_received"},
+	ErrSerialAlreadyShipped:  {http.StatusConflict, "serial_already_shipped"},
+
+	// Kits de produtos
+	ErrKitContainsItself:     {http.StatusBadRequest, "kit_contains_itself"},
+	ErrDuplicateKitComponent: {http.StatusBadRequest, "duplicate_kit_component"},
+	ErrNestedKit:             {http.StatusBadRequest, "nested_kit"},
+
+	// Unidades de medida
+	ErrUnknownUnit:          {http.StatusBadRequest, "unknown_unit"},
+	ErrBaseUnitRequired:     {http.StatusBadRequest, "base_unit_required"},
+	ErrDuplicateUnit:        {http.StatusBadRequest, "duplicate_unit"},
+	ErrMultipleDefaultUnits: {http.StatusBadRequest, "multiple_default_units"},
+
+	// Etiquetas e endereços de estoque
+	ErrDuplicateBinCode:      {http.StatusConflict, "duplicate_bin_code"},
+	ErrInvalidLabelFormat:    {http.StatusBadRequest, "invalid_label_format"},
+	ErrInvalidBarcodeType:    {http.StatusBadRequest, "invalid_barcode_type"},
+	ErrLabelCodeNotEncodable: {http.StatusUnprocessableEntity, "label_code_not_encodable"},
+
+	// Separação e embalagem de entregas
+	ErrDeliveryNotPending: {http.StatusConflict, "delivery_not_pending"},
+	ErrDeliveryNotPicking: {http.StatusConflict, "delivery_not_picking"},
+	ErrDeliveryNotPacked:  {http.StatusConflict, "delivery_not_packed"},
+	ErrOverPick:           {http.StatusUnprocessableEntity, "over_pick"},
+	ErrPickingIncomplete:  {http.StatusConflict, "picking_incomplete"},
+	ErrPackingMismatch:    {http.StatusUnprocessableEntity, "packing_mismatch"},
+
+	// Atendimento de pedidos
+	ErrOverShipment:           {http.StatusBadRequest, "over_shipment"},
+	ErrDeliveryItemNotInOrder: {http.StatusBadRequest, "delivery_item_not_in_order"},
+	ErrSalesOrderNotShippable: {http.StatusConflict, "sales_order_not_shippable"},
+
+	// Conversão entre documentos
+	ErrQuotationNotConvertible:   {http.StatusConflict, "quotation_not_convertible"},
+	ErrQuotationAlreadyConverted: {http.StatusConflict, "quotation_already_converted"},
+	ErrSalesOrderNotInvoiceable:  {http.StatusConflict, "sales_order_not_invoiceable"},
+	ErrNothingToInvoice:          {http.StatusConflict, "nothing_to_invoice"},
+	ErrNothingToDeliver:          {http.StatusConflict, "nothing_to_deliver"},
+
+	// Rastreamento de transportadoras
+	ErrUnknownCarrier:          {http.StatusNotFound, "unknown_carrier"},
+	ErrTrackingNotSupported:    {http.StatusBadRequest, "tracking_not_supported"},
+	ErrWebhookNotSupported:     {http.StatusBadRequest, "webhook_not_supported"},
+	ErrInvalidWebhookSignature: {http.StatusUnauthorized, "invalid_webhook_signature"},
+	ErrInvalidWebhookPayload:   {http.StatusBadRequest, "invalid_webhook_payload"},
+	ErrMissingTrackingNumber:   {http.StatusBadRequest, "missing_tracking_number"},
+	ErrUnknownWebhookSource:    {http.StatusBadRequest, "unknown_webhook_source"},
+
+	// Cotação de frete
+	ErrInvalidCEP:                  {http.StatusBadRequest, "invalid_cep"},
+	ErrShippingOriginNotConfigured: {http.StatusUnprocessableEntity, "shipping_origin_not_configured"},
+	ErrRatesNotSupported:           {http.StatusBadRequest, "rates_not_supported"},
+	ErrShippingQuoteUnavailable:    {http.StatusBadGateway, "shipping_quote_unavailable"},
+	ErrShippingNotEditable:         {http.StatusConflict, "shipping_not_editable"},
+
+	// Devoluções (RMA)
+	ErrReturnSourceRequired:    {http.StatusBadRequest, "return_source_required"},
+	ErrEmptyReturn:             {http.StatusBadRequest, "empty_return"},
+	ErrInvalidReturnReason:     {http.StatusBadRequest, "invalid_return_reason"},
+	ErrInvalidReturnCondition:  {http.StatusBadRequest, "invalid_return_condition"},
+	ErrReturnItemNotInDocument: {http.StatusBadRequest, "return_item_not_in_document"},
+	ErrReturnQuantityExceeded:  {http.StatusBadRequest, "return_quantity_exceeded"},
+	ErrInvalidReturnStatus:     {http.StatusConflict, "invalid_return_status"},
+	ErrDocumentNotReturnable:   {http.StatusConflict, "document_not_returnable"},
+
+	// Ordens de serviço (assistência técnica)
+	ErrInvalidServiceOrderStatus: {http.StatusConflict, "invalid_service_order_status"},
+	ErrServiceItemNotInvoiced:    {http.StatusBadRequest, "service_item_not_invoiced"},
+	ErrInvalidSchedule:           {http.StatusBadRequest, "invalid_schedule"},
+	ErrTechnicianUnavailable:     {http.StatusConflict, "technician_unavailable"},
+	ErrInsufficientStock:         {http.StatusConflict, "insufficient_stock"},
+	ErrServiceOrderUnderWarranty: {http.StatusConflict, "service_order_under_warranty"},
+	ErrNothingToBill:             {http.StatusConflict, "nothing_to_bill"},
+
+	// Sugestão de compras
+	ErrNotSuggestedOrder:  {http.StatusConflict, "not_suggested_order"},
+	ErrEmptyPurchaseOrder: {http.StatusBadRequest, "empty_purchase_order"},
+
+	// Comissões
+	ErrCommissionRuleNotFound:        {http.StatusNotFound, "commission_rule_not_found"},
+	ErrCommissionPeriodNotFound:      {http.StatusNotFound, "commission_period_not_found"},
+	ErrCommissionStatementNotFound:   {http.StatusNotFound, "commission_statement_not_found"},
+	ErrInvalidCommissionPeriod:       {http.StatusBadRequest, "invalid_commission_period"},
+	ErrCommissionPeriodLocked:        {http.StatusConflict, "commission_period_locked"},
+	ErrCommissionPeriodNotCalculated: {http.StatusConflict, "commission_period_not_calculated"},
+	ErrInvalidCommissionStatus:       {http.StatusConflict, "invalid_commission_status"},
+
+	// CRM
+	ErrLeadNotFound:                {http.StatusNotFound, "lead_not_found"},
+	ErrOpportunityNotFound:         {http.StatusNotFound, "opportunity_not_found"},
+	ErrLeadAlreadyConverted:        {http.StatusConflict, "lead_already_converted"},
+	ErrLeadIncomplete:              {http.StatusBadRequest, "lead_incomplete"},
+	ErrInvalidOpportunityStage:     {http.StatusBadRequest, "invalid_opportunity_stage"},
+	ErrOpportunityWithoutParty:     {http.StatusBadRequest, "opportunity_without_party"},
+	ErrOpportunityClosed:           {http.StatusConflict, "opportunity_closed"},
+	ErrOpportunityNotWon:           {http.StatusConflict, "opportunity_not_won"},
+	ErrOpportunityAlreadyConverted: {http.StatusConflict, "opportunity_already_converted"},
+	ErrEmptyOpportunity:            {http.StatusBadRequest, "empty_opportunity"},
+
+	// Anexos e comentários
+	ErrAttachmentNotFound:    {http.StatusNotFound, "attachment_not_found"},
+	ErrCommentNotFound:       {http.StatusNotFound, "comment_not_found"},
+	ErrEntityNotFound:        {http.StatusNotFound, "entity_not_found"},
+	ErrUnsupportedEntityType: {http.StatusBadRequest, "unsupported_entity_type"},
+	ErrUnknownStorageBackend: {http.StatusInternalServerError, "unknown_storage_backend"},
+	ErrAttachmentTooLarge:    {http.StatusRequestEntityTooLarge, "attachment_too_large"},
+	ErrInvalidCommentParent:  {http.StatusBadRequest, "invalid_comment_parent"},
+
+	// Lixeira (soft delete)
+	ErrUnsupportedTrashResource: {http.StatusBadRequest, "unsupported_trash_resource"},
+	ErrNotInTrash:               {http.StatusConflict, "not_in_trash"},
+
+	// Idempotência
+	ErrInvalidIdempotencyKey:  {http.StatusBadRequest, "invalid_idempotency_key"},
+	ErrIdempotencyKeyInUse:    {http.StatusConflict, "idempotency_key_in_use"},
+	ErrIdempotencyKeyMismatch: {http.StatusUnprocessableEntity, "idempotency_key_mismatch"},
+
+	// Feature flags
+	ErrInvalidFeatureFlag: {http.StatusBadRequest, "invalid_feature_flag"},
+	ErrFeatureDisabled:    {http.StatusNotFound, "feature_disabled"},
+
+	// Permissões de campos
+	ErrInvalidFieldPermission: {http.StatusBadRequest, "invalid_field_permission"},
+
+	// Empresas (multi-tenant)
+	ErrCompanyNotFound: {http.StatusNotFound, "company_not_found"},
+	ErrCompanyInactive: {http.StatusForbidden, "company_inactive"},
+	ErrTenantRequired:  {http.StatusBadRequest, "tenant_required"},
+	ErrTenantMismatch:  {http.StatusForbidden, "tenant_mismatch"},
+	ErrInvalidTimezone: {http.StatusBadRequest, "invalid_timezone"},
+
+	// Gestão de usuários
+	ErrUserInactive:     {http.StatusForbidden, "user_inactive"},
+	ErrUserLocked:       {http.StatusLocked, "user_locked"},
+	ErrInvalidRole:      {http.StatusBadRequest, "invalid_role"},
+	ErrWeakPassword:     {http.StatusBadRequest, "weak_password"},
+	ErrUsernameTaken:    {http.StatusConflict, "username_taken"},
+	ErrEmailTaken:       {http.StatusConflict, "email_taken"},
+	ErrInvalidUserToken: {http.StatusBadRequest, "invalid_user_token"},
+	ErrCannotChangeSelf: {http.StatusConflict, "cannot_change_self"},
+
+	// Chaves de API
+	ErrMissingAPIKey:     {http.StatusUnauthorized, "missing_api_key"},
+	ErrInvalidAPIKey:     {http.StatusUnauthorized, "invalid_api_key"},
+	ErrAPIKeyRevoked:     {http.StatusUnauthorized, "api_key_revoked"},
+	ErrAPIKeyExpired:     {http.StatusUnauthorized, "api_key_expired"},
+	ErrInsufficientScope: {http.StatusForbidden, "insufficient_scope"},
+	ErrInvalidScope:      {http.StatusBadRequest, "invalid_scope"},
+	ErrAPIKeyNotFound:    {http.StatusNotFound, "api_key_not_found"},
+
+	// Operações em lote
+	ErrBatchEmpty:        {http.StatusBadRequest, "batch_empty"},
+	ErrBatchTooLarge:     {http.StatusBadRequest, "batch_too_large"},
+	ErrInvoiceNotPayable: {http.StatusConflict, "invoice_not_payable"},
+
+	// Integração com e-commerce
+	ErrUnknownPlatform:      {http.StatusBadRequest, "unknown_ecommerce_platform"},
+	ErrPlatformNotSupported: {http.StatusBadRequest, "ecommerce_platform_not_supported"},
+	ErrChannelNotFound:      {http.StatusNotFound, "ecommerce_channel_not_found"},
+	ErrChannelInactive:      {http.StatusConflict, "ecommerce_channel_inactive"},
+	ErrProductSKUNotFound:   {http.StatusUnprocessableEntity, "product_sku_not_found"},
+
+	// Importações configuráveis (ETL)
+	ErrMappingNotFound:   {http.StatusNotFound, "etl_mapping_not_found"},
+	ErrMappingNameTaken:  {http.StatusConflict, "etl_mapping_name_taken"},
+	ErrInvalidMapping:    {http.StatusUnprocessableEntity, "invalid_etl_mapping"},
+	ErrEtlRunNotFound:    {http.StatusNotFound, "etl_run_not_found"},
+	ErrInvalidImportFile: {http.StatusUnprocessableEntity, "invalid_import_file"},
+	ErrImportFileEmpty:   {http.StatusUnprocessableEntity, "import_file_empty"},
+	ErrImportTooManyRows: {http.StatusRequestEntityTooLarge, "import_too_many_rows"},
+
+	// Portal do cliente
+	ErrInvalidPortalAccess:       {http.StatusUnauthorized, "invalid_portal_access"},
+	ErrPortalTokenRequired:       {http.StatusForbidden, "portal_token_required"},
+	ErrPortalTokenNotAllowed:     {http.StatusForbidden, "portal_token_not_allowed"},
+	ErrPortalAccessNotFound:      {http.StatusNotFound, "portal_access_not_found"},
+	ErrQuotationNotAwaitingReply: {http.StatusConflict, "quotation_not_awaiting_reply"},
+	ErrQuotationExpired:          {http.StatusConflict, "quotation_expired"},
+	ErrInvalidTicketDocument:     {http.StatusBadRequest, "invalid_ticket_document"},
+	ErrInvalidShareLink:          {http.StatusNotFound, "invalid_share_link"},
+	ErrShareLinkExpired:          {http.StatusGone, "share_link_expired"},
+	ErrQuotationNotShareable:     {http.StatusConflict, "quotation_not_shareable"},
+
+	ErrUnknownInboundProvider:    {http.StatusNotFound, "unknown_inbound_provider"},
+	ErrInvalidInboundSignature:   {http.StatusUnauthorized, "invalid_inbound_signature"},
+	ErrInvalidInboundEmail:       {http.StatusBadRequest, "invalid_inbound_email"},
+	ErrInvalidNFe:                {http.StatusBadRequest, "invalid_nfe"},
+	ErrInboundDocumentNotFound:   {http.StatusNotFound, "inbound_document_not_found"},
+	ErrInboundDocumentNotPending: {http.StatusConflict, "inbound_document_not_pending"},
+	ErrSupplierBillNotDraft:      {http.StatusConflict, "supplier_bill_not_draft"},
+
+	ErrInvalidReportDefinition: {http.StatusBadRequest, "invalid_report_definition"},
+	ErrReportNotFound:          {http.StatusNotFound, "report_not_found"},
+	ErrReportScheduleNotFound:  {http.StatusNotFound, "report_schedule_not_found"},
+
+	ErrInvalidCashflowItem:       {http.StatusBadRequest, "invalid_cashflow_item"},
+	ErrCashflowItemNotFound:      {http.StatusNotFound, "cashflow_item_not_found"},
+	ErrCostCenterNotFound:        {http.StatusNotFound, "cost_center_not_found"},
+	ErrDuplicateCostCenterCode:   {http.StatusConflict, "duplicate_cost_center_code"},
+	ErrCostCenterInactive:        {http.StatusBadRequest, "cost_center_inactive"},
+	ErrInvalidCostCenterDocument: {http.StatusBadRequest, "invalid_cost_center_document"},
+	ErrInvalidBudget:             {http.StatusBadRequest, "invalid_budget"},
+	ErrBudgetNotFound:            {http.StatusNotFound, "budget_not_found"},
+	ErrDuplicateBudget:           {http.StatusConflict, "duplicate_budget"},
+
+	ErrInvalidContract:     {http.StatusBadRequest, "invalid_contract"},
+	ErrContractNotFound:    {http.StatusNotFound, "contract_not_found"},
+	ErrDuplicateContractNo: {http.StatusConflict, "duplicate_contract_no"},
+
+	ErrInvalidSLADefinition:   {http.StatusBadRequest, "invalid_sla_definition"},
+	ErrSLADefinitionNotFound:  {http.StatusNotFound, "sla_definition_not_found"},
+	ErrDuplicateSLADefinition: {http.StatusConflict, "duplicate_sla_definition"},
+	ErrInvalidCalendar:        {http.StatusBadRequest, "invalid_calendar"},
+	ErrHolidayNotFound:        {http.StatusNotFound, "holiday_not_found"},
+	ErrDuplicateHoliday:       {http.StatusConflict, "duplicate_holiday"},
+	ErrInvalidContactMerge:    {http.StatusBadRequest, "invalid_contact_merge"},
+	ErrInvalidCNPJ:            {http.StatusBadRequest, "invalid_cnpj"},
+	ErrCNPJNotFound:           {http.StatusNotFound, "cnpj_not_found"},
+	ErrCEPNotFound:            {http.StatusNotFound, "cep_not_found"},
+	ErrRegistryUnavailable:    {http.StatusBadGateway, "registry_unavailable"},
+	ErrInvalidAddress:         {http.StatusBadRequest, "invalid_address"},
+	ErrAddressNotFound:        {http.StatusNotFound, "address_not_found"},
+	ErrInvalidCreditLimit:     {http.StatusBadRequest, "invalid_credit_limit"},
+	ErrCreditLimitExceeded:    {http.StatusUnprocessableEntity, "credit_limit_exceeded"},
+	ErrNotOnCreditHold:        {http.StatusConflict, "not_on_credit_hold"},
+	ErrInvalidContactBlock:    {http.StatusBadRequest, "invalid_contact_block"},
+	ErrContactBlocked:         {http.StatusUnprocessableEntity, "contact_blocked"},
+	ErrContactNotBlocked:      {http.StatusConflict, "contact_not_blocked"},
+
+	ErrInvalidCustomField:      {http.StatusBadRequest, "invalid_custom_field"},
+	ErrCustomFieldNotFound:     {http.StatusNotFound, "custom_field_not_found"},
+	ErrDuplicateCustomField:    {http.StatusConflict, "duplicate_custom_field"},
+	ErrInvalidCustomFieldValue: {http.StatusBadRequest, "invalid_custom_field_value"},
+
+	ErrInvalidTemplate:  {http.StatusBadRequest, "invalid_template"},
+	ErrTemplateNotFound: {http.StatusNotFound, "template_not_found"},
+
+	ErrTotalsMismatch: {http.StatusUnprocessableEntity, "totals_mismatch"},
+
+	ErrAllocationExceedsPayment:  {http.StatusUnprocessableEntity, "allocation_exceeds_payment"},
+	ErrAllocationExceedsBalance:  {http.StatusUnprocessableEntity, "allocation_exceeds_balance"},
+	ErrAllocationContactMismatch: {http.StatusUnprocessableEntity, "allocation_contact_mismatch"},
+	ErrInvalidAllocation:         {http.StatusBadRequest, "invalid_allocation"},
+
+	ErrInstallmentNotFound:    {http.StatusNotFound, "installment_not_found"},
+	ErrInvalidInstallmentPlan: {http.StatusBadRequest, "invalid_installment_plan"},
+	ErrInstallmentsMismatch:   {http.StatusUnprocessableEntity, "installments_mismatch"},
+	ErrChargeNotConfigured:    {http.StatusServiceUnavailable, "charge_not_configured"},
+
+	ErrInvalidExpense:           {http.StatusBadRequest, "invalid_expense"},
+	ErrExpenseNotFound:          {http.StatusNotFound, "expense_not_found"},
+	ErrExpenseCategoryNotFound:  {http.StatusNotFound, "expense_category_not_found"},
+	ErrInvalidExpenseTransition: {http.StatusConflict, "invalid_expense_transition"},
+	ErrExpenseReceiptRequired:   {http.StatusUnprocessableEntity, "expense_receipt_required"},
+	ErrExpenseSelfApproval:      {http.StatusForbidden, "expense_self_approval"},
+
+	ErrInvalidAsset:             {http.StatusBadRequest, "invalid_asset"},
+	ErrAssetNotFound:            {http.StatusNotFound, "asset_not_found"},
+	ErrDuplicateAssetNo:         {http.StatusConflict, "duplicate_asset_no"},
+	ErrAssetNotActive:           {http.StatusConflict, "asset_not_active"},
+	ErrAssetAlreadyDepreciated:  {http.StatusConflict, "asset_already_depreciated"},
+	ErrInvalidAssetDisposal:     {http.StatusBadRequest, "invalid_asset_disposal"},
+	ErrPurchaseItemNotFound:     {http.StatusNotFound, "purchase_item_not_found"},
+	ErrPurchaseItemFullyTracked: {http.StatusConflict, "purchase_item_fully_tracked"},
+
+	ErrInvalidEmployee:         {http.StatusBadRequest, "invalid_employee"},
+	ErrEmployeeNotFound:        {http.StatusNotFound, "employee_not_found"},
+	ErrDepartmentNotFound:      {http.StatusNotFound, "department_not_found"},
+	ErrDuplicateDepartmentCode: {http.StatusConflict, "duplicate_department_code"},
+	ErrEmployeeUserTaken:       {http.StatusConflict, "employee_user_taken"},
+	ErrEmployeeWithoutUser:     {http.StatusUnprocessableEntity, "employee_without_user"},
+	ErrInvalidCostSplit:        {http.StatusBadRequest, "invalid_cost_split"},
+	ErrLaborCostsPosted:        {http.StatusConflict, "labor_costs_posted"},
+	ErrInvalidEmployeeDocument: {http.StatusBadRequest, "invalid_employee_document"},
+
+	ErrInvalidReceipt:           {http.StatusBadRequest, "invalid_receipt"},
+	ErrDeliveryNotReceivable:    {http.StatusConflict, "delivery_not_receivable"},
+	ErrSupplierBillItemNotFound: {http.StatusNotFound, "supplier_bill_item_not_found"},
+	ErrBillWithoutPurchaseOrder: {http.StatusUnprocessableEntity, "bill_without_purchase_order"},
+	ErrThreeWayMatchRequired:    {http.StatusConflict, "three_way_match_required"},
+	ErrBillMatchNotInReview:     {http.StatusConflict, "bill_match_not_in_review"},
+
+	ErrFollowupRuleNotFound: {http.StatusNotFound, "followup_rule_not_found"},
+	ErrInvalidFollowupRule:  {http.StatusBadRequest, "invalid_followup_rule"},
+
+	ErrTaskNotFound: {http.StatusNotFound, "task_not_found"},
+	ErrInvalidTask:  {http.StatusBadRequest, "invalid_task"},
+	ErrTaskNotOpen:  {http.StatusConflict, "task_not_open"},
+
+	ErrInvalidProcessMove:  {http.StatusConflict, "invalid_process_move"},
+	ErrInvalidProcessEvent: {http.StatusBadRequest, "invalid_process_event"},
+
+	ErrBackupNotFound:            {http.StatusNotFound, "backup_not_found"},
+	ErrBackupNotReady:            {http.StatusConflict, "backup_not_ready"},
+	ErrBackupChecksumMismatch:    {http.StatusConflict, "backup_checksum_mismatch"},
+	ErrRestoreConfirmationNeeded: {http.StatusBadRequest, "restore_confirmation_needed"},
+	ErrBackupJobActive:           {http.StatusConflict, "backup_job_active"},
+	ErrTenantExportNotFound:      {http.StatusNotFound, "tenant_export_not_found"},
+	ErrTenantExportNotReady:      {http.StatusConflict, "tenant_export_not_ready"},
+	ErrInvalidExportFormat:       {http.StatusBadRequest, "invalid_export_format"},
+
+	ErrEDIPartnerNotFound:     {http.StatusNotFound, "edi_partner_not_found"},
+	ErrEDIPartnerInactive:     {http.StatusConflict, "edi_partner_inactive"},
+	ErrEDIDocumentNotAllowed:  {http.StatusUnprocessableEntity, "edi_document_not_allowed"},
+	ErrEDICredentialsRequired: {http.StatusBadRequest, "edi_credentials_required"},
+	ErrInvalidEDIKey:          {http.StatusBadRequest, "invalid_edi_key"},
+	ErrEDITransmissionFailed:  {http.StatusBadGateway, "edi_transmission_failed"},
+
+	ErrVaultNotConfigured: {http.StatusServiceUnavailable, "vault_not_configured"},
+
+	ErrFileEndpointNotFound:     {http.StatusNotFound, "file_endpoint_not_found"},
+	ErrFileEndpointInactive:     {http.StatusConflict, "file_endpoint_inactive"},
+	ErrFileEndpointCredentials:  {http.StatusBadRequest, "file_endpoint_credentials_required"},
+	ErrInvalidFileEndpointKey:   {http.StatusBadRequest, "invalid_file_endpoint_key"},
+	ErrFileScheduleNotFound:     {http.StatusNotFound, "file_schedule_not_found"},
+	ErrInvalidFileSchedule:      {http.StatusBadRequest, "invalid_file_schedule"},
+	ErrFileExchangeRunNotFound:  {http.StatusNotFound, "file_exchange_run_not_found"},
+	ErrFileExchangeRunNotFailed: {http.StatusConflict, "file_exchange_run_not_failed"},
+	ErrFileExchangeRunPending:   {http.StatusConflict, "file_exchange_run_pending"},
+
+	ErrInvalidRemittance:      {http.StatusBadRequest, "invalid_remittance"},
+	ErrNoSlipsToRemit:         {http.StatusUnprocessableEntity, "no_slips_to_remit"},
+	ErrBankRemittanceNotFound: {http.StatusNotFound, "bank_remittance_not_found"},
+	ErrBankReturnNotFound:     {http.StatusNotFound, "bank_return_not_found"},
+	ErrBankReturnDuplicate:    {http.StatusConflict, "bank_return_duplicate"},
+	ErrEmptyBankReturn:        {http.StatusUnprocessableEntity, "empty_bank_return"},
+
+	ErrUnknownBoletoProvider:         {http.StatusServiceUnavailable, "unknown_boleto_provider"},
+	ErrBoletoNotFound:                {http.StatusNotFound, "boleto_not_found"},
+	ErrNoInstallmentsToRegister:      {http.StatusUnprocessableEntity, "no_installments_to_register"},
+	ErrInvalidBoletoInstruction:      {http.StatusBadRequest, "invalid_boleto_instruction"},
+	ErrBoletoInstructionNotSupported: {http.StatusUnprocessableEntity, "boleto_instruction_not_supported"},
+	ErrBoletoNotActive:               {http.StatusConflict, "boleto_not_active"},
+	ErrBoletoProviderFailed:          {http.StatusBadGateway, "boleto_provider_failed"},
+
+	ErrUnknownPixProvider: {http.StatusServiceUnavailable, "unknown_pix_provider"},
+	ErrPixChargeNotFound:  {http.StatusNotFound, "pix_charge_not_found"},
+	ErrPixProviderFailed:  {http.StatusBadGateway, "pix_provider_failed"},
+
+	// Catálogo de produtos
+	ErrCategoryNotFound:      {http.StatusNotFound, "category_not_found"},
+	ErrInvalidCategorySlug:   {http.StatusBadRequest, "invalid_category_slug"},
+	ErrDuplicateCategorySlug: {http.StatusConflict, "duplicate_category_slug"},
+	ErrCategoryCycle:         {http.StatusBadRequest, "category_cycle"},
+	ErrCategoryHasChildren:   {http.StatusConflict, "category_has_children"},
+	ErrProductImageNotFound:  {http.StatusNotFound, "product_image_not_found"},
+	ErrInvalidProductImage:   {http.StatusBadRequest, "invalid_product_image"},
+	ErrInvalidImageOrder:     {http.StatusBadRequest, "invalid_image_order"},
+
+	// Ciclo de vida dos produtos
+	ErrProductDiscontinued:        {http.StatusUnprocessableEntity, "product_discontinued"},
+	ErrInvalidLifecycleTransition: {http.StatusConflict, "invalid_lifecycle_transition"},
+	ErrInvalidReplacementProduct:  {http.StatusBadRequest, "invalid_replacement_product"},
+
+	// Transferências entre depósitos
+	ErrWarehouseNotFound:          {http.StatusNotFound, "warehouse_not_found"},
+	ErrDuplicateWarehouseCode:     {http.StatusConflict, "duplicate_warehouse_code"},
+	ErrStockTransferNotFound:      {http.StatusNotFound, "stock_transfer_not_found"},
+	ErrInvalidStockTransfer:       {http.StatusBadRequest, "invalid_stock_transfer"},
+	ErrInvalidTransferStatus:      {http.StatusConflict, "invalid_transfer_status"},
+	ErrInsufficientWarehouseStock: {http.StatusUnprocessableEntity, "insufficient_warehouse_stock"},
+	ErrWarehouseWithoutContact:    {http.StatusUnprocessableEntity, "warehouse_without_contact"},
+
+	// Inventários físicos
+	ErrStocktakeNotFound:      {http.StatusNotFound, "stocktake_not_found"},
+	ErrStocktakeCountNotFound: {http.StatusNotFound, "stocktake_count_not_found"},
+	ErrStocktakeInProgress:    {http.StatusConflict, "stocktake_in_progress"},
+	ErrStocktakeNotCounting:   {http.StatusConflict, "stocktake_not_counting"},
+	ErrInvalidStocktakeCount:  {http.StatusBadRequest, "invalid_stocktake_count"},
+
+	ErrInvalidQuotationSimulation: {http.StatusBadRequest, "invalid_quotation_simulation"},
+
+	// Margem mínima no faturamento
+	ErrMarginThresholdNotFound:   {http.StatusNotFound, "margin_threshold_not_found"},
+	ErrMarginBelowMinimum:        {http.StatusUnprocessableEntity, "margin_below_minimum"},
+	ErrMarginApprovalNotRequired: {http.StatusConflict, "margin_approval_not_required"},
+
+	// Metas de vendas
+	ErrSalesTeamNotFound:   {http.StatusNotFound, "sales_team_not_found"},
+	ErrSalesTargetNotFound: {http.StatusNotFound, "sales_target_not_found"},
+	ErrInvalidTargetPeriod: {http.StatusBadRequest, "invalid_target_period"},
+	ErrInvalidSalesTarget:  {http.StatusBadRequest, "invalid_sales_target"},
+
+	// Cotações de moedas e reavaliação cambial
+	ErrExchangeRateNotFound:     {http.StatusNotFound, "exchange_rate_not_found"},
+	ErrExchangeRateUnavailable:  {http.StatusBadGateway, "exchange_rate_unavailable"},
+	ErrInvalidCurrency:          {http.StatusBadRequest, "invalid_currency"},
+	ErrInvoiceCurrencyNotFound:  {http.StatusNotFound, "invoice_currency_not_found"},
+	ErrInvalidRevaluationPeriod: {http.StatusBadRequest, "invalid_revaluation_period"},
+
+	// Contestações de faturas
+	ErrInvoiceDisputeNotFound:   {http.StatusNotFound, "invoice_dispute_not_found"},
+	ErrInvalidInvoiceDispute:    {http.StatusBadRequest, "invalid_invoice_dispute"},
+	ErrInvoiceNotDisputable:     {http.StatusConflict, "invoice_not_disputable"},
+	ErrInvoiceAlreadyDisputed:   {http.StatusConflict, "invoice_already_disputed"},
+	ErrInvoiceDisputeClosed:     {http.StatusConflict, "invoice_dispute_closed"},
+	ErrInvalidDisputeResolution: {http.StatusBadRequest, "invalid_dispute_resolution"},
+
+	// Multa e juros por atraso
+	ErrLateFeeRuleNotFound: {http.StatusNotFound, "late_fee_rule_not_found"},
+	ErrInvalidLateFeeRule:  {http.StatusBadRequest, "invalid_late_fee_rule"},
+
+	// Condição de pagamento dos contatos
+	ErrPaymentTermsNotFound: {http.StatusNotFound, "payment_terms_not_found"},
+	ErrInvalidPaymentTerms:  {http.StatusBadRequest, "invalid_payment_terms"},
+
+	// Adiantamentos dos pedidos de venda
+	ErrInvalidDownPayment:     {http.StatusBadRequest, "invalid_down_payment"},
+	ErrAdvanceInvoiceDeducted: {http.StatusConflict, "advance_invoice_deducted"},
+}
+
+// Lookup retorna o status HTTP e o código do erro catalogado, inclusive quando
+// o sentinela foi encadeado com fmt.Errorf("...: %w", err)
+func Lookup(err error) (int, string, bool) {
+	for err != nil {
+		if entry, ok := catalog[err]; ok {
+			return entry.status, entry.code, true
+		}
+		err = errors.Unwrap(err)
+	}
+	return 0, "", false
+}
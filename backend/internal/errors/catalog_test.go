@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupUnwrapsChain(t *testing.T) {
+	status, code, ok := Lookup(fmt.Errorf("pedido 3: %w", ErrSalesOrderNotShippable))
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "sales_order_not_shippable", code)
+
+	_, _, ok = Lookup(fmt.Errorf("erro qualquer"))
+	assert.False(t, ok)
+}
+
+func TestNotFoundErrorsMapTo404(t *testing.T) {
+	for err := range catalog {
+		if IsNotFound(err) {
+			status, _, _ := Lookup(err)
+			assert.Equal(t, http.StatusNotFound, status, err.Error())
+		}
+	}
+}
+
+func TestToAPIError(t *testing.T) {
+	apiErr := ToAPIError(ErrInvalidID, "")
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+	assert.Equal(t, "invalid_id", apiErr.Code)
+
+	apiErr = ToAPIError(fmt.Errorf("timeout"), "erro ao buscar venda")
+	assert.Equal(t, http.StatusInternalServerError, apiErr.Status)
+	assert.Equal(t, CodeInternal, apiErr.Code)
+	assert.Equal(t, "erro ao buscar venda", apiErr.Message)
+	assert.Equal(t, "timeout", apiErr.Details)
+
+	custom := InvalidParam("data inválida")
+	assert.Same(t, custom, ToAPIError(custom, "ignorado"))
+}
@@ -11,7 +11,21 @@ var (
 	ErrTransactionFailed  = errors.New("falha na transação do banco de dados")
 
 	// Erros de validação
-	ErrInvalidPagination = errors.New("parâmetros de paginação inválidos")
+	ErrInvalidPagination     = errors.New("parâmetros de paginação inválidos")
+	ErrInvalidDateRange      = errors.New("intervalo de datas inválido")
+	ErrInvalidID             = errors.New("ID inválido")
+	ErrInvalidRequest        = errors.New("dados inválidos")
+	ErrInvalidSortField      = errors.New("campo de ordenação inválido")
+	ErrInvalidFieldSelection = errors.New("seleção de campos inválida")
+
+	// Erros de autenticação e autorização
+	ErrMissingToken = errors.New("token não fornecido")
+	ErrInvalidToken = errors.New("token inválido ou expirado")
+	ErrForbidden    = errors.New("acesso negado: permissões insuficientes")
+
+	// Erros de usuários
+	ErrInvalidCredentials = errors.New("usuário ou senha inválidos")
+	ErrUserNotFound       = errors.New("usuário não encontrado")
 
 	// Erros de entidade não encontrada
 	ErrQuotationNotFound     = errors.New("cotação não encontrada")
@@ -22,9 +36,504 @@ var (
 	ErrPaymentNotFound       = errors.New("pagamento não encontrado")
 	ErrSalesProcessNotFound  = errors.New("processo de vendas não encontrado")
 	ErrDeliveryItemNotFound  = errors.New("delivery item not found")
+	ErrContactNotFound       = errors.New("contato não encontrado")
+	ErrSupplierBillNotFound  = errors.New("conta a pagar não encontrada")
+	ErrBankStatementNotFound = errors.New("extrato bancário não encontrado")
+	ErrBankLineNotFound      = errors.New("lançamento bancário não encontrado")
+	ErrCreditNoteNotFound    = errors.New("nota de crédito não encontrada")
+	ErrLedgerAccountNotFound = errors.New("conta contábil não encontrada")
+	ErrJournalEntryNotFound  = errors.New("lançamento contábil não encontrado")
+	ErrProductNotFound       = errors.New("produto não encontrado")
+	ErrReturnNotFound        = errors.New("devolução não encontrada")
+	ErrSaleNotFound          = errors.New("venda não encontrada")
+	ErrTransactionNotFound   = errors.New("transação não encontrada")
+	ErrDropshippingNotFound  = errors.New("dropshipping não encontrado")
+	ErrCampaignNotFound      = errors.New("campanha não encontrada")
+	ErrServiceOrderNotFound  = errors.New("ordem de serviço não encontrada")
+	ErrVariantNotFound       = errors.New("variante do produto não encontrada")
+	ErrSerialNotFound        = errors.New("número de série não encontrado")
+	ErrLotNotFound           = errors.New("lote não encontrado")
+	ErrKitNotFound           = errors.New("kit não encontrado")
+	ErrBinNotFound           = errors.New("endereço de estoque não encontrado")
+	ErrScanCodeNotFound      = errors.New("nenhum registro encontrado para o código lido")
 
 	// Erros de lógica de negócio
 	ErrRelatedRecordsExist = errors.New("não é possível excluir devido a registros relacionados")
+
+	// Erros de conciliação bancária
+	ErrUnsupportedStatementFormat = errors.New("formato de extrato não suportado")
+	ErrEmptyStatement             = errors.New("extrato sem lançamentos")
+	ErrLineAlreadyReconciled      = errors.New("lançamento já conciliado")
+	ErrNoMatchSuggestion          = errors.New("lançamento sem sugestão de conciliação")
+	ErrInvalidMatchTarget         = errors.New("documento de conciliação inválido")
+
+	// Erros de contabilidade
+	ErrUnbalancedEntry       = errors.New("lançamento contábil não está balanceado")
+	ErrAccountMappingMissing = errors.New("conta contábil não configurada para a operação")
+	ErrDocumentAlreadyPosted = errors.New("documento já contabilizado")
+	ErrDocumentNotPostable   = errors.New("documento não pode ser contabilizado no status atual")
+	ErrInvalidAccountType    = errors.New("tipo de conta contábil inválido")
+	ErrDuplicateAccountCode  = errors.New("código de conta contábil já existe")
+	ErrInvalidDREGroup       = errors.New("linha da DRE inválida para a conta")
+
+	// Erros de custeio de estoque
+	ErrInvalidCostingMethod       = errors.New("método de custeio inválido")
+	ErrPurchaseOrderNotReceivable = errors.New("pedido de compra não pode ser recebido no status atual")
+	ErrDeliveryNotOutbound        = errors.New("entrega não está vinculada a um pedido de venda")
+
+	// Erros de rastreabilidade (variantes, números de série e lotes)
+	ErrEmptyVariantMatrix    = errors.New("informe ao menos um tamanho ou uma cor da grade")
+	ErrDuplicateVariantSKU   = errors.New("SKU de variante já existe")
+	ErrReceiptItemNotInOrder = errors.New("item do recebimento não pertence ao pedido de compra")
+	ErrTooManySerials        = errors.New("quantidade de números de série maior que a do item")
+	ErrDuplicateSerial       = errors.New("número de série repetido")
+	ErrSerialAlreadyReceived = errors.New("número de série já recebido")
+	ErrSerialAlreadyShipped  = errors.New("número de série já entregue")
+
+	// Erros de kits de produtos
+	ErrKitContainsItself     = errors.New("o kit não pode ser componente de si mesmo")
+	ErrDuplicateKitComponent = errors.New("componente repetido na composição do kit")
+	ErrNestedKit             = errors.New("um kit não pode ser componente de outro kit")
+
+	// Erros de unidades de medida
+	ErrUnknownUnit          = errors.New("unidade de medida não cadastrada para o produto")
+	ErrBaseUnitRequired     = errors.New("informe exatamente uma unidade de estoque (fator 1)")
+	ErrDuplicateUnit        = errors.New("unidade de medida repetida")
+	ErrMultipleDefaultUnits = errors.New("informe no máximo uma unidade padrão de compra e uma de venda")
+
+	// Erros de etiquetas e endereços de estoque
+	ErrDuplicateBinCode      = errors.New("já existe um endereço de estoque com este código")
+	ErrInvalidLabelFormat    = errors.New("formato de etiqueta inválido: use png ou pdf")
+	ErrInvalidBarcodeType    = errors.New("tipo de código inválido: use code128 ou qr")
+	ErrLabelCodeNotEncodable = errors.New("o código não pode ser representado no tipo de código escolhido")
+
+	// Erros de separação e embalagem de entregas
+	ErrDeliveryNotPending = errors.New("a entrega não está pendente")
+	ErrDeliveryNotPicking = errors.New("a entrega não está em separação")
+	ErrDeliveryNotPacked  = errors.New("embale a entrega antes de marcá-la como enviada")
+	ErrOverPick           = errors.New("quantidade separada excede a quantidade do item")
+	ErrPickingIncomplete  = errors.New("separe todos os itens antes de embalar")
+	ErrPackingMismatch    = errors.New("as quantidades embaladas devem somar a quantidade separada de cada item")
+
+	// Erros de atendimento de pedidos
+	ErrOverShipment           = errors.New("quantidade entregue excede o saldo do pedido de venda")
+	ErrDeliveryItemNotInOrder = errors.New("item da entrega não pertence ao pedido de venda")
+	ErrSalesOrderNotShippable = errors.New("pedido de venda não pode ser entregue no status atual")
+
+	// Erros de conversão entre documentos
+	ErrQuotationNotConvertible   = errors.New("cotação não pode ser convertida no status atual")
+	ErrQuotationAlreadyConverted = errors.New("cotação já convertida em pedido de venda")
+	ErrSalesOrderNotInvoiceable  = errors.New("pedido de venda não pode ser faturado no status atual")
+	ErrNothingToInvoice          = errors.New("pedido de venda não possui saldo a faturar")
+	ErrNothingToDeliver          = errors.New("pedido de venda não possui saldo a entregar")
+
+	// Erros de rastreamento de transportadoras
+	ErrUnknownCarrier          = errors.New("transportadora não suportada")
+	ErrTrackingNotSupported    = errors.New("transportadora não suporta consulta de rastreamento")
+	ErrWebhookNotSupported     = errors.New("transportadora não suporta webhook de rastreamento")
+	ErrInvalidWebhookSignature = errors.New("assinatura do webhook inválida")
+	ErrInvalidWebhookPayload   = errors.New("payload do webhook inválido")
+	ErrMissingTrackingNumber   = errors.New("entrega sem código de rastreamento")
+	ErrUnknownWebhookSource    = errors.New("origem de webhook desconhecida")
+
+	// Erros de cotação de frete
+	ErrInvalidCEP                  = errors.New("CEP inválido")
+	ErrShippingOriginNotConfigured = errors.New("CEP de origem do frete não configurado")
+	ErrRatesNotSupported           = errors.New("transportadora não suporta cotação de frete")
+	ErrShippingQuoteUnavailable    = errors.New("nenhuma transportadora retornou cotação de frete")
+	ErrShippingNotEditable         = errors.New("o frete do documento não pode ser alterado no status atual")
+
+	// Erros de devoluções (RMA)
+	ErrReturnSourceRequired    = errors.New("informe a entrega ou a fatura de origem da devolução")
+	ErrEmptyReturn             = errors.New("devolução sem itens")
+	ErrInvalidReturnReason     = errors.New("motivo de devolução inválido")
+	ErrInvalidReturnCondition  = errors.New("condição do item devolvido inválida")
+	ErrReturnItemNotInDocument = errors.New("item devolvido não pertence ao documento de origem")
+	ErrReturnQuantityExceeded  = errors.New("quantidade devolvida excede o saldo disponível para devolução")
+	ErrInvalidReturnStatus     = errors.New("operação não permitida no status atual da devolução")
+	ErrDocumentNotReturnable   = errors.New("documento de origem não permite devolução no status atual")
+
+	// Erros de ordens de serviço (assistência técnica)
+	ErrInvalidServiceOrderStatus = errors.New("operação não permitida no status atual da ordem de serviço")
+	ErrServiceItemNotInvoiced    = errors.New("item não pertence a uma fatura emitida para o contato")
+	ErrInvalidSchedule           = errors.New("agendamento inválido: o fim deve ser posterior ao início")
+	ErrTechnicianUnavailable     = errors.New("técnico já possui atendimento agendado no horário")
+	ErrInsufficientStock         = errors.New("estoque insuficiente para a peça")
+	ErrServiceOrderUnderWarranty = errors.New("ordem de serviço em garantia não gera cobrança")
+	ErrNothingToBill             = errors.New("ordem de serviço sem peças ou horas a cobrar")
+
+	// Erros de sugestão de compras
+	ErrNotSuggestedOrder  = errors.New("pedido de compra não é uma sugestão pendente de revisão")
+	ErrEmptyPurchaseOrder = errors.New("pedido de compra sem itens")
+
+	// Erros de comissões
+	ErrCommissionRuleNotFound        = errors.New("regra de comissão não encontrada")
+	ErrCommissionPeriodNotFound      = errors.New("período de comissão não encontrado")
+	ErrCommissionStatementNotFound   = errors.New("extrato de comissão não encontrado")
+	ErrInvalidCommissionPeriod       = errors.New("período de comissão inválido, use o formato AAAA-MM")
+	ErrCommissionPeriodLocked        = errors.New("período de comissão já aprovado ou fechado")
+	ErrCommissionPeriodNotCalculated = errors.New("período de comissão ainda não calculado")
+	ErrInvalidCommissionStatus       = errors.New("operação não permitida no status atual do período de comissão")
+
+	// Erros de CRM
+	ErrLeadNotFound                = errors.New("lead não encontrado")
+	ErrOpportunityNotFound         = errors.New("oportunidade não encontrada")
+	ErrLeadAlreadyConverted        = errors.New("lead já convertido")
+	ErrLeadIncomplete              = errors.New("lead sem documento para cadastro do contato")
+	ErrInvalidOpportunityStage     = errors.New("estágio da oportunidade inválido")
+	ErrOpportunityWithoutParty     = errors.New("oportunidade precisa de um lead ou contato vinculado")
+	ErrOpportunityClosed           = errors.New("oportunidade já ganha ou perdida")
+	ErrOpportunityNotWon           = errors.New("apenas oportunidades ganhas podem ser convertidas em cotação")
+	ErrOpportunityAlreadyConverted = errors.New("oportunidade já convertida em cotação")
+	ErrEmptyOpportunity            = errors.New("oportunidade sem itens para gerar a cotação")
+
+	// Erros de anexos e comentários
+	ErrAttachmentNotFound    = errors.New("anexo não encontrado")
+	ErrCommentNotFound       = errors.New("comentário não encontrado")
+	ErrEntityNotFound        = errors.New("documento não encontrado")
+	ErrUnsupportedEntityType = errors.New("tipo de documento não suporta anexos e comentários")
+	ErrUnknownStorageBackend = errors.New("armazenamento de arquivos não configurado")
+	ErrAttachmentTooLarge    = errors.New("arquivo excede o tamanho máximo permitido")
+	ErrInvalidCommentParent  = errors.New("comentário de origem pertence a outro documento")
+
+	// Erros da lixeira (soft delete)
+	ErrUnsupportedTrashResource = errors.New("recurso não possui lixeira")
+	ErrNotInTrash               = errors.New("registro não está na lixeira")
+
+	// Erros de idempotência
+	ErrInvalidIdempotencyKey  = errors.New("chave de idempotência inválida")
+	ErrIdempotencyKeyInUse    = errors.New("requisição com a mesma chave de idempotência ainda em processamento")
+	ErrIdempotencyKeyMismatch = errors.New("chave de idempotência já utilizada em outra requisição")
+
+	// Erros das feature flags
+	ErrInvalidFeatureFlag = errors.New("nome de feature flag inválido")
+	ErrFeatureDisabled    = errors.New("funcionalidade desativada neste ambiente")
+
+	// Erros das permissões de campos
+	ErrInvalidFieldPermission = errors.New("permissão de campo inválida: informe um perfil diferente de admin, um campo e a ação hide ou mask")
+
+	// Erros de empresas (multi-tenant)
+	ErrCompanyNotFound = errors.New("empresa não encontrada")
+	ErrCompanyInactive = errors.New("empresa inativa")
+	ErrTenantRequired  = errors.New("empresa da requisição não informada")
+	ErrTenantMismatch  = errors.New("empresa informada difere da empresa do usuário")
+	ErrInvalidTimezone = errors.New("fuso horário inválido: informe um nome IANA, como America/Sao_Paulo")
+
+	// Erros de gestão de usuários
+	ErrUserInactive     = errors.New("usuário desativado")
+	ErrUserLocked       = errors.New("conta bloqueada temporariamente após tentativas de login sem sucesso")
+	ErrInvalidRole      = errors.New("perfil de acesso inválido")
+	ErrWeakPassword     = errors.New("a senha deve ter ao menos 8 caracteres, com letras e números")
+	ErrUsernameTaken    = errors.New("nome de usuário já utilizado")
+	ErrEmailTaken       = errors.New("e-mail já cadastrado")
+	ErrInvalidUserToken = errors.New("convite ou link de redefinição inválido ou expirado")
+	ErrCannotChangeSelf = errors.New("administradores não podem desativar ou rebaixar a própria conta")
+
+	// Erros de chaves de API (integrações)
+	ErrMissingAPIKey     = errors.New("chave de API não fornecida")
+	ErrInvalidAPIKey     = errors.New("chave de API inválida")
+	ErrAPIKeyRevoked     = errors.New("chave de API revogada")
+	ErrAPIKeyExpired     = errors.New("chave de API expirada")
+	ErrInsufficientScope = errors.New("a chave de API não possui o escopo exigido")
+	ErrInvalidScope      = errors.New("escopo de chave de API inválido")
+	ErrAPIKeyNotFound    = errors.New("chave de API não encontrada")
+
+	// Erros de operações em lote
+	ErrBatchEmpty        = errors.New("o lote não possui itens")
+	ErrBatchTooLarge     = errors.New("o lote excede a quantidade máxima de itens")
+	ErrInvoiceNotPayable = errors.New("fatura não aceita pagamentos no status atual")
+
+	// Erros da integração com e-commerce
+	ErrUnknownPlatform      = errors.New("plataforma de e-commerce desconhecida")
+	ErrPlatformNotSupported = errors.New("plataforma de e-commerce ainda não possui conector")
+	ErrChannelNotFound      = errors.New("canal de e-commerce não encontrado")
+	ErrChannelInactive      = errors.New("canal de e-commerce inativo")
+	ErrProductSKUNotFound   = errors.New("nenhum produto do ERP possui o SKU do item do pedido")
+
+	// Erros das importações configuráveis (ETL)
+	ErrMappingNotFound   = errors.New("mapeamento de importação não encontrado")
+	ErrMappingNameTaken  = errors.New("já existe um mapeamento de importação com este nome")
+	ErrInvalidMapping    = errors.New("mapeamento de importação inválido")
+	ErrEtlRunNotFound    = errors.New("execução de importação não encontrada")
+	ErrInvalidImportFile = errors.New("arquivo de importação inválido")
+	ErrImportFileEmpty   = errors.New("arquivo de importação sem registros")
+	ErrImportTooManyRows = errors.New("arquivo de importação excede o número máximo de registros")
+
+	// Erros do portal do cliente
+	ErrInvalidPortalAccess       = errors.New("código de acesso ao portal inválido, revogado ou expirado")
+	ErrPortalTokenRequired       = errors.New("rota exclusiva do portal do cliente")
+	ErrPortalTokenNotAllowed     = errors.New("o token do portal do cliente não dá acesso às rotas internas")
+	ErrPortalAccessNotFound      = errors.New("acesso ao portal não encontrado")
+	ErrQuotationNotAwaitingReply = errors.New("a cotação não está aguardando resposta do cliente")
+	ErrQuotationExpired          = errors.New("a cotação está vencida")
+	ErrInvalidTicketDocument     = errors.New("documento do chamado inválido")
+	ErrInvalidShareLink          = errors.New("link de cotação inválido")
+	ErrShareLinkExpired          = errors.New("link de cotação expirado")
+	ErrQuotationNotShareable     = errors.New("cotação não pode ser compartilhada no status atual")
+
+	// Erros da entrada de documentos por e-mail
+	ErrUnknownInboundProvider    = errors.New("provedor de e-mail de entrada não suportado")
+	ErrInvalidInboundSignature   = errors.New("assinatura do e-mail de entrada inválida")
+	ErrInvalidInboundEmail       = errors.New("e-mail de entrada inválido")
+	ErrInvalidNFe                = errors.New("XML de NF-e inválido")
+	ErrInboundDocumentNotFound   = errors.New("documento recebido por e-mail não encontrado")
+	ErrInboundDocumentNotPending = errors.New("o documento não está aguardando revisão")
+	ErrSupplierBillNotDraft      = errors.New("o documento não tem conta a pagar em rascunho para aprovar")
+
+	// Erros do construtor de relatórios
+	ErrInvalidReportDefinition = errors.New("definição de relatório inválida")
+	ErrReportNotFound          = errors.New("relatório não encontrado")
+	ErrReportScheduleNotFound  = errors.New("agendamento de relatório não encontrado")
+
+	// Erros financeiros (fluxo de caixa, centros de custo, DRE e orçamento)
+	ErrInvalidCashflowItem       = errors.New("lançamento previsto inválido")
+	ErrCashflowItemNotFound      = errors.New("lançamento previsto não encontrado")
+	ErrCostCenterNotFound        = errors.New("centro de custo não encontrado")
+	ErrDuplicateCostCenterCode   = errors.New("código de centro de custo já existe")
+	ErrCostCenterInactive        = errors.New("centro de custo inativo")
+	ErrInvalidCostCenterDocument = errors.New("tipo de documento não aceita centro de custo")
+	ErrInvalidBudget             = errors.New("linha de orçamento inválida")
+	ErrBudgetNotFound            = errors.New("linha de orçamento não encontrada")
+	ErrDuplicateBudget           = errors.New("já existe orçamento para a conta, centro de custo e mês")
+
+	// Erros de contratos
+	ErrInvalidContract     = errors.New("contrato inválido")
+	ErrContractNotFound    = errors.New("contrato não encontrado")
+	ErrDuplicateContractNo = errors.New("número de contrato já existe")
+
+	// Erros de SLA
+	ErrInvalidSLADefinition   = errors.New("definição de SLA inválida")
+	ErrSLADefinitionNotFound  = errors.New("definição de SLA não encontrada")
+	ErrDuplicateSLADefinition = errors.New("já existe SLA para este alvo")
+
+	// Erros do calendário de dias úteis
+	ErrInvalidCalendar  = errors.New("calendário inválido")
+	ErrHolidayNotFound  = errors.New("feriado não encontrado")
+	ErrDuplicateHoliday = errors.New("já existe feriado nesta data")
+
+	// Erros da deduplicação de contatos
+	ErrInvalidContactMerge = errors.New("mesclagem de contatos inválida")
+
+	// Erros da consulta de CNPJ e CEP nos cadastros públicos
+	ErrInvalidCNPJ         = errors.New("CNPJ inválido")
+	ErrCNPJNotFound        = errors.New("CNPJ não encontrado na Receita Federal")
+	ErrCEPNotFound         = errors.New("CEP não encontrado")
+	ErrRegistryUnavailable = errors.New("consulta de CNPJ indisponível")
+
+	// Erros do catálogo de endereços dos contatos
+	ErrInvalidAddress  = errors.New("endereço inválido")
+	ErrAddressNotFound = errors.New("endereço não encontrado")
+
+	// Erros do limite de crédito dos clientes
+	ErrInvalidCreditLimit  = errors.New("limite de crédito inválido")
+	ErrCreditLimitExceeded = errors.New("pedido ultrapassa o limite de crédito do cliente")
+	ErrNotOnCreditHold     = errors.New("pedido de venda não está retido por crédito")
+
+	// Erros do bloqueio de contatos
+	ErrInvalidContactBlock = errors.New("bloqueio de contato inválido")
+	ErrContactBlocked      = errors.New("contato bloqueado para novos documentos")
+	ErrContactNotBlocked   = errors.New("contato não está bloqueado")
+
+	// Erros dos campos personalizados
+	ErrInvalidCustomField      = errors.New("campo personalizado inválido")
+	ErrCustomFieldNotFound     = errors.New("campo personalizado não encontrado")
+	ErrDuplicateCustomField    = errors.New("já existe campo personalizado com esta chave")
+	ErrInvalidCustomFieldValue = errors.New("valor de campo personalizado inválido")
+
+	// Erros dos modelos de documento
+	ErrInvalidTemplate  = errors.New("modelo de documento inválido")
+	ErrTemplateNotFound = errors.New("modelo de documento não encontrado")
+
+	// Erros dos totais dos documentos de venda
+	ErrTotalsMismatch = errors.New("totais informados não conferem com os itens do documento")
+
+	// Erros da alocação de pagamentos entre faturas
+	ErrAllocationExceedsPayment  = errors.New("alocações excedem o valor do pagamento")
+	ErrAllocationExceedsBalance  = errors.New("alocação excede o saldo em aberto da fatura")
+	ErrAllocationContactMismatch = errors.New("faturas da alocação devem ser do mesmo contato do pagamento")
+	ErrInvalidAllocation         = errors.New("alocação inválida: informe faturas distintas com valor positivo")
+
+	// Erros das parcelas das faturas
+	ErrInstallmentNotFound    = errors.New("parcela não encontrada")
+	ErrInvalidInstallmentPlan = errors.New("plano de parcelas inválido")
+	ErrInstallmentsMismatch   = errors.New("soma das parcelas difere do total da fatura")
+	ErrChargeNotConfigured    = errors.New("cobrança por boleto ou Pix não configurada")
+
+	// Erros das despesas dos colaboradores
+	ErrInvalidExpense           = errors.New("despesa inválida")
+	ErrExpenseNotFound          = errors.New("despesa não encontrada")
+	ErrExpenseCategoryNotFound  = errors.New("categoria de despesa não encontrada")
+	ErrInvalidExpenseTransition = errors.New("transição de status da despesa inválida")
+	ErrExpenseReceiptRequired   = errors.New("anexe o comprovante antes de enviar a despesa")
+	ErrExpenseSelfApproval      = errors.New("a despesa deve ser aprovada por outro usuário")
+
+	// Erros do ativo imobilizado
+	ErrInvalidAsset             = errors.New("bem patrimonial inválido")
+	ErrAssetNotFound            = errors.New("bem patrimonial não encontrado")
+	ErrDuplicateAssetNo         = errors.New("já existe um bem com este número de patrimônio")
+	ErrAssetNotActive           = errors.New("bem patrimonial já baixado")
+	ErrAssetAlreadyDepreciated  = errors.New("o custo, a vida útil e a aquisição do bem não mudam depois da primeira depreciação")
+	ErrInvalidAssetDisposal     = errors.New("baixa do bem patrimonial inválida")
+	ErrPurchaseItemNotFound     = errors.New("item do pedido de compra não encontrado")
+	ErrPurchaseItemFullyTracked = errors.New("todas as unidades do item do pedido de compra já foram patrimoniadas")
+
+	// Erros dos colaboradores e departamentos
+	ErrInvalidEmployee         = errors.New("colaborador inválido")
+	ErrEmployeeNotFound        = errors.New("colaborador não encontrado")
+	ErrDepartmentNotFound      = errors.New("departamento não encontrado")
+	ErrDuplicateDepartmentCode = errors.New("código de departamento já existe")
+	ErrEmployeeUserTaken       = errors.New("usuário já vinculado a outro colaborador")
+	ErrEmployeeWithoutUser     = errors.New("colaborador sem usuário vinculado não pode ser vendedor ou técnico")
+	ErrInvalidCostSplit        = errors.New("rateio inválido: informe centros de custo distintos com percentuais somando 100")
+	ErrLaborCostsPosted        = errors.New("o rateio de mão de obra do mês já foi contabilizado")
+	ErrInvalidEmployeeDocument = errors.New("tipo de documento sem colaborador responsável")
+
+	// Erros do recebimento e da conferência de três vias
+	ErrInvalidReceipt           = errors.New("recebimento inválido: informe itens da entrega com quantidade entre zero e a quantidade enviada")
+	ErrDeliveryNotReceivable    = errors.New("a entrega não é de pedido de compra ou foi devolvida")
+	ErrSupplierBillItemNotFound = errors.New("item da conta a pagar não encontrado")
+	ErrBillWithoutPurchaseOrder = errors.New("a conta a pagar não está ligada a um pedido de compra")
+	ErrThreeWayMatchRequired    = errors.New("a conta a pagar precisa conferir com o pedido e o recebimento, ou ter as divergências aceitas, antes da aprovação e do pagamento")
+	ErrBillMatchNotInReview     = errors.New("a conferência da conta a pagar não tem divergências aguardando revisão")
+
+	// Erros das regras de acompanhamento dos processos de venda parados
+	ErrFollowupRuleNotFound = errors.New("regra de acompanhamento não encontrada")
+	ErrInvalidFollowupRule  = errors.New("regra de acompanhamento inválida")
+
+	// Erros das tarefas
+	ErrTaskNotFound = errors.New("tarefa não encontrada")
+	ErrInvalidTask  = errors.New("tarefa inválida")
+	ErrTaskNotOpen  = errors.New("a tarefa já foi concluída ou cancelada")
+
+	// Erros do quadro e da linha do tempo dos processos de venda
+	ErrInvalidProcessMove  = errors.New("movimentação do processo de venda inválida")
+	ErrInvalidProcessEvent = errors.New("evento da linha do tempo inválido: informe o tipo (call, meeting, note ou email), a descrição e uma data que não esteja no futuro")
+
+	// Erros dos backups e das exportações de dados da empresa
+	ErrBackupNotFound            = errors.New("backup não encontrado")
+	ErrBackupNotReady            = errors.New("o backup ainda não foi concluído ou o arquivo não está mais disponível")
+	ErrBackupChecksumMismatch    = errors.New("o arquivo do backup não confere com o checksum registrado")
+	ErrRestoreConfirmationNeeded = errors.New("confirme a restauração informando o nome do arquivo do backup")
+	ErrBackupJobActive           = errors.New("já existe um backup ou restauração na fila ou em andamento")
+	ErrTenantExportNotFound      = errors.New("exportação não encontrada")
+	ErrTenantExportNotReady      = errors.New("a exportação ainda não foi concluída")
+	ErrInvalidExportFormat       = errors.New("formato de exportação inválido: use json ou sql")
+
+	// Erros do EDI com parceiros B2B (UBL por SFTP)
+	ErrEDIPartnerNotFound     = errors.New("parceiro EDI não encontrado")
+	ErrEDIPartnerInactive     = errors.New("o parceiro EDI está inativo")
+	ErrEDIDocumentNotAllowed  = errors.New("o documento é de outro cliente e não pode ser enviado a este parceiro")
+	ErrEDICredentialsRequired = errors.New("informe a senha ou a chave privada do SFTP do parceiro")
+	ErrInvalidEDIKey          = errors.New("chave SSH inválida: use a chave pública do servidor no formato authorized_keys e a chave privada em PEM")
+	ErrEDITransmissionFailed  = errors.New("falha ao enviar o documento ao servidor SFTP do parceiro")
+
+	// Erros do cofre de credenciais
+	ErrVaultNotConfigured = errors.New("cofre de credenciais não configurado: defina VAULT_KEY")
+
+	// Erros da troca de arquivos com servidores SFTP/FTP
+	ErrFileEndpointNotFound     = errors.New("servidor de troca de arquivos não encontrado")
+	ErrFileEndpointInactive     = errors.New("o servidor de troca de arquivos está inativo")
+	ErrFileEndpointCredentials  = errors.New("informe a senha do servidor (ou a chave privada, no SFTP)")
+	ErrInvalidFileEndpointKey   = errors.New("chave SSH inválida: use a chave pública do servidor no formato authorized_keys e a chave privada em PEM")
+	ErrFileScheduleNotFound     = errors.New("rotina de troca de arquivos não encontrada")
+	ErrInvalidFileSchedule      = errors.New("rotina de troca de arquivos inválida")
+	ErrFileExchangeRunNotFound  = errors.New("execução da troca de arquivos não encontrada")
+	ErrFileExchangeRunNotFailed = errors.New("só execuções com falha podem voltar para a fila")
+	ErrFileExchangeRunPending   = errors.New("a rotina já tem uma execução na fila ou em andamento")
+
+	// Erros da cobrança registrada (remessa e retorno CNAB 240)
+	ErrInvalidRemittance      = errors.New("remessa de cobrança inválida")
+	ErrNoSlipsToRemit         = errors.New("nenhum boleto em aberto para enviar ao banco")
+	ErrBankRemittanceNotFound = errors.New("remessa de cobrança não encontrada")
+	ErrBankReturnNotFound     = errors.New("retorno de cobrança não encontrado")
+	ErrBankReturnDuplicate    = errors.New("arquivo de retorno já processado")
+	ErrEmptyBankReturn        = errors.New("arquivo de retorno sem títulos")
+
+	// Erros do registro de boletos e das instruções enviadas ao banco
+	ErrUnknownBoletoProvider         = errors.New("provedor de boletos desconhecido")
+	ErrBoletoNotFound                = errors.New("boleto não encontrado")
+	ErrNoInstallmentsToRegister      = errors.New("nenhuma parcela em aberto sem boleto registrado na fatura")
+	ErrInvalidBoletoInstruction      = errors.New("instrução de boleto inválida")
+	ErrBoletoInstructionNotSupported = errors.New("o provedor do boleto não aceita esta instrução")
+	ErrBoletoNotActive               = errors.New("o boleto foi cancelado e não aceita instruções")
+	ErrBoletoProviderFailed          = errors.New("falha na comunicação com o provedor de boletos")
+
+	// Erros das cobranças Pix dinâmicas
+	ErrUnknownPixProvider = errors.New("provedor Pix desconhecido")
+	ErrPixChargeNotFound  = errors.New("cobrança Pix não encontrada")
+	ErrPixProviderFailed  = errors.New("falha na comunicação com o PSP do Pix")
+
+	// Erros do catálogo de produtos (categorias, imagens e publicação)
+	ErrCategoryNotFound      = errors.New("categoria de produto não encontrada")
+	ErrInvalidCategorySlug   = errors.New("slug da categoria inválido: informe letras ou números")
+	ErrDuplicateCategorySlug = errors.New("já existe uma categoria com este slug")
+	ErrCategoryCycle         = errors.New("a categoria não pode ficar abaixo dela mesma ou de uma subcategoria")
+	ErrCategoryHasChildren   = errors.New("a categoria tem subcategorias")
+	ErrProductImageNotFound  = errors.New("imagem do produto não encontrada")
+	ErrInvalidProductImage   = errors.New("a imagem do produto deve ser JPEG, PNG, WebP ou GIF")
+	ErrInvalidImageOrder     = errors.New("informe todas as imagens do produto, sem repetição, na nova ordem")
+
+	// Erros do ciclo de vida dos produtos
+	ErrProductDiscontinued        = errors.New("produto descontinuado não pode entrar em novas cotações")
+	ErrInvalidLifecycleTransition = errors.New("mudança de estado do produto não permitida")
+	ErrInvalidReplacementProduct  = errors.New("produto substituto inválido")
+
+	// Erros das transferências entre depósitos
+	ErrWarehouseNotFound          = errors.New("depósito não encontrado")
+	ErrDuplicateWarehouseCode     = errors.New("já existe um depósito com este código")
+	ErrStockTransferNotFound      = errors.New("transferência de estoque não encontrada")
+	ErrInvalidStockTransfer       = errors.New("transferência de estoque inválida")
+	ErrInvalidTransferStatus      = errors.New("a transferência não está na situação exigida para esta operação")
+	ErrInsufficientWarehouseStock = errors.New("saldo insuficiente no depósito de origem")
+	ErrWarehouseWithoutContact    = errors.New("a nota interna exige o contato da filial no depósito de destino")
+
+	// Erros dos inventários físicos
+	ErrStocktakeNotFound      = errors.New("inventário não encontrado")
+	ErrStocktakeCountNotFound = errors.New("contagem do inventário não encontrada")
+	ErrStocktakeInProgress    = errors.New("já existe um inventário em contagem para este depósito")
+	ErrStocktakeNotCounting   = errors.New("o inventário não está em contagem")
+	ErrInvalidStocktakeCount  = errors.New("contagem inválida: informe o produto, a quantidade positiva e o contador")
+
+	// Erros da simulação de margem das cotações
+	ErrInvalidQuotationSimulation = errors.New("simulação da cotação inválida")
+
+	// Erros da margem mínima no faturamento
+	ErrMarginThresholdNotFound   = errors.New("a categoria não tem margem mínima própria")
+	ErrMarginBelowMinimum        = errors.New("margem da fatura abaixo do mínimo da categoria")
+	ErrMarginApprovalNotRequired = errors.New("a margem da fatura está dentro do mínimo das categorias")
+
+	// Erros das metas de vendas por vendedor, equipe e linha de produto
+	ErrSalesTeamNotFound   = errors.New("equipe de vendas não encontrada")
+	ErrSalesTargetNotFound = errors.New("meta de vendas não encontrada")
+	ErrInvalidTargetPeriod = errors.New("período da meta inválido, use AAAA-MM (mensal) ou AAAA-Qn (trimestral)")
+	ErrInvalidSalesTarget  = errors.New("meta de vendas inválida: informe só o vendedor, a equipe ou a linha de produto, conforme o escopo")
+
+	// Erros das cotações de moedas e da reavaliação cambial das faturas
+	ErrExchangeRateNotFound     = errors.New("cotação da moeda não encontrada para a data")
+	ErrExchangeRateUnavailable  = errors.New("consulta de cotações indisponível")
+	ErrInvalidCurrency          = errors.New("moeda inválida: informe o código ISO 4217 de uma moeda estrangeira (ex.: USD)")
+	ErrInvoiceCurrencyNotFound  = errors.New("a fatura não está em moeda estrangeira")
+	ErrInvalidRevaluationPeriod = errors.New("período da reavaliação inválido: informe um mês encerrado no formato AAAA-MM")
+
+	// Erros das contestações de faturas
+	ErrInvoiceDisputeNotFound   = errors.New("contestação da fatura não encontrada")
+	ErrInvalidInvoiceDispute    = errors.New("contestação inválida: informe quem contesta (customer ou ar), o motivo e um valor positivo até o saldo em aberto")
+	ErrInvoiceNotDisputable     = errors.New("só faturas emitidas com saldo em aberto podem ser contestadas")
+	ErrInvoiceAlreadyDisputed   = errors.New("já existe uma contestação em aberto para a fatura ou a parcela")
+	ErrInvoiceDisputeClosed     = errors.New("a contestação já foi resolvida")
+	ErrInvalidDisputeResolution = errors.New("resolução inválida: informe credit_note ou write_off com valor até o contestado, ou adjusted_due_date com um vencimento a partir de hoje")
+
+	// Erros das regras de multa e juros por atraso
+	ErrLateFeeRuleNotFound = errors.New("regra de multa e juros não encontrada")
+	ErrInvalidLateFeeRule  = errors.New("regra de multa e juros inválida: percentuais entre 0 e 100, multa fixa e carência não negativas")
+
+	// Erros da condição de pagamento dos contatos
+	ErrPaymentTermsNotFound = errors.New("condição de pagamento do contato não encontrada")
+	ErrInvalidPaymentTerms  = errors.New("condição de pagamento inválida")
+
+	// Erros dos adiantamentos dos pedidos de venda
+	ErrInvalidDownPayment     = errors.New("adiantamento inválido: informe percent ou amount, até o saldo do pedido ainda não adiantado")
+	ErrAdvanceInvoiceDeducted = errors.New("fatura de adiantamento já deduzida em fatura do pedido")
 )
 
 // WrapError adiciona um contexto a um erro
@@ -40,5 +549,85 @@ func IsNotFound(err error) bool {
 		err == ErrDeliveryNotFound ||
 		err == ErrInvoiceNotFound ||
 		err == ErrPaymentNotFound ||
-		err == ErrSalesProcessNotFound
+		err == ErrSalesProcessNotFound ||
+		err == ErrContactNotFound ||
+		err == ErrAddressNotFound ||
+		err == ErrCustomFieldNotFound ||
+		err == ErrTemplateNotFound ||
+		err == ErrSupplierBillNotFound ||
+		err == ErrBankStatementNotFound ||
+		err == ErrBankLineNotFound ||
+		err == ErrCreditNoteNotFound ||
+		err == ErrLedgerAccountNotFound ||
+		err == ErrJournalEntryNotFound ||
+		err == ErrProductNotFound ||
+		err == ErrReturnNotFound ||
+		err == ErrSaleNotFound ||
+		err == ErrTransactionNotFound ||
+		err == ErrDropshippingNotFound ||
+		err == ErrCampaignNotFound ||
+		err == ErrServiceOrderNotFound ||
+		err == ErrVariantNotFound ||
+		err == ErrSerialNotFound ||
+		err == ErrLotNotFound ||
+		err == ErrKitNotFound ||
+		err == ErrBinNotFound ||
+		err == ErrWarehouseNotFound ||
+		err == ErrStockTransferNotFound ||
+		err == ErrStocktakeNotFound ||
+		err == ErrStocktakeCountNotFound ||
+		err == ErrMarginThresholdNotFound ||
+		err == ErrSalesTeamNotFound ||
+		err == ErrSalesTargetNotFound ||
+		err == ErrExchangeRateNotFound ||
+		err == ErrInvoiceCurrencyNotFound ||
+		err == ErrInvoiceDisputeNotFound ||
+		err == ErrLateFeeRuleNotFound ||
+		err == ErrPaymentTermsNotFound ||
+		err == ErrScanCodeNotFound ||
+		err == ErrCommissionRuleNotFound ||
+		err == ErrCommissionPeriodNotFound ||
+		err == ErrCommissionStatementNotFound ||
+		err == ErrLeadNotFound ||
+		err == ErrOpportunityNotFound ||
+		err == ErrAttachmentNotFound ||
+		err == ErrCommentNotFound ||
+		err == ErrEntityNotFound ||
+		err == ErrCompanyNotFound ||
+		err == ErrUserNotFound ||
+		err == ErrAPIKeyNotFound ||
+		err == ErrPortalAccessNotFound ||
+		err == ErrInboundDocumentNotFound ||
+		err == ErrReportNotFound ||
+		err == ErrReportScheduleNotFound ||
+		err == ErrCashflowItemNotFound ||
+		err == ErrCostCenterNotFound ||
+		err == ErrBudgetNotFound ||
+		err == ErrContractNotFound ||
+		err == ErrSLADefinitionNotFound ||
+		err == ErrHolidayNotFound ||
+		err == ErrCNPJNotFound ||
+		err == ErrCEPNotFound ||
+		err == ErrInstallmentNotFound ||
+		err == ErrExpenseNotFound ||
+		err == ErrExpenseCategoryNotFound ||
+		err == ErrAssetNotFound ||
+		err == ErrPurchaseItemNotFound ||
+		err == ErrEmployeeNotFound ||
+		err == ErrDepartmentNotFound ||
+		err == ErrSupplierBillItemNotFound ||
+		err == ErrFollowupRuleNotFound ||
+		err == ErrTaskNotFound ||
+		err == ErrBackupNotFound ||
+		err == ErrTenantExportNotFound ||
+		err == ErrEDIPartnerNotFound ||
+		err == ErrFileEndpointNotFound ||
+		err == ErrFileScheduleNotFound ||
+		err == ErrFileExchangeRunNotFound ||
+		err == ErrBankRemittanceNotFound ||
+		err == ErrBankReturnNotFound ||
+		err == ErrBoletoNotFound ||
+		err == ErrPixChargeNotFound ||
+		err == ErrCategoryNotFound ||
+		err == ErrProductImageNotFound
 }
@@ -0,0 +1,349 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Request é o corpo de uma requisição GraphQL
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Response é a resposta no formato GraphQL: data com os campos resolvidos e errors com as falhas
+type Response struct {
+	Data   any     `json:"data"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Error é uma falha de validação ou de um resolvedor; Path aponta o campo que falhou
+type Error struct {
+	Message string   `json:"message"`
+	Path    []string `json:"path,omitempty"`
+}
+
+// Execute interpreta, valida e executa a consulta. Erros de sintaxe ou validação retornam só
+// errors; falhas de um resolvedor anulam o campo e são listadas em errors, sem interromper os demais.
+func (s *Schema) Execute(ctx context.Context, request Request) *Response {
+	doc, err := Parse(request.Query)
+	if err != nil {
+		return errorResponse(err)
+	}
+	operation, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if operation.Type != "query" {
+		return errorResponse(fmt.Errorf("somente consultas (query) são suportadas; use a API REST para gravações"))
+	}
+
+	variables, err := operationVariables(operation, request.Variables)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if err := s.validate(s.Query, operation.Selections, variables, 1); err != nil {
+		return errorResponse(err)
+	}
+
+	e := &executor{variables: variables}
+	data := e.executeSelections(ctx, s.Query, []any{nil}, operation.Selections, nil)
+	return &Response{Data: data[0], Errors: e.errors}
+}
+
+func errorResponse(err error) *Response {
+	return &Response{Errors: []Error{{Message: err.Error()}}}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, fmt.Errorf("informe operationName: a consulta tem mais de uma operação")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, operation := range doc.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+	return nil, fmt.Errorf("operação %q não encontrada", name)
+}
+
+func operationVariables(operation *Operation, provided map[string]any) (map[string]any, error) {
+	variables := make(map[string]any, len(operation.Variables))
+	for _, definition := range operation.Variables {
+		if value, ok := provided[definition.Name]; ok {
+			variables[definition.Name] = value
+		} else if definition.HasDefault {
+			variables[definition.Name] = definition.Default
+		}
+	}
+	for name := range provided {
+		if _, declared := variables[name]; !declared {
+			return nil, fmt.Errorf("variável $%s não declarada na operação", name)
+		}
+	}
+	return variables, nil
+}
+
+// validate confere os campos, argumentos, variáveis e a profundidade antes de executar,
+// para que uma consulta inválida não chegue ao banco
+func (s *Schema) validate(object *Object, selections []*Selection, variables map[string]any, depth int) error {
+	if s.MaxDepth > 0 && depth > s.MaxDepth {
+		return fmt.Errorf("consulta excede a profundidade máxima de %d níveis", s.MaxDepth)
+	}
+	for _, selection := range selections {
+		if selection.Name == "__typename" {
+			continue
+		}
+		field, ok := object.Fields[selection.Name]
+		if !ok {
+			return fmt.Errorf("campo %q não existe no tipo %s", selection.Name, object.Name)
+		}
+		for name, value := range selection.Arguments {
+			if !containsString(field.Args, name) {
+				return fmt.Errorf("argumento %q não existe no campo %s.%s", name, object.Name, selection.Name)
+			}
+			if err := checkVariables(value, variables); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case field.Type == nil && len(selection.Selections) > 0:
+			return fmt.Errorf("campo %s.%s é escalar e não aceita subcampos", object.Name, selection.Name)
+		case field.Type != nil && len(selection.Selections) == 0:
+			return fmt.Errorf("selecione os subcampos de %s.%s", object.Name, selection.Name)
+		case field.Type != nil:
+			if err := s.validate(field.Type, selection.Selections, variables, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkVariables(value any, variables map[string]any) error {
+	switch v := value.(type) {
+	case variable:
+		if _, ok := variables[string(v)]; !ok {
+			return fmt.Errorf("variável $%s não informada", string(v))
+		}
+	case []any:
+		for _, item := range v {
+			if err := checkVariables(item, variables); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, item := range v {
+			if err := checkVariables(item, variables); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type executor struct {
+	variables map[string]any
+	errors    []Error
+}
+
+// executeSelections resolve as seleções para todos os objetos do nível, campo a campo,
+// e desce para os subcampos com os filhos de todos os objetos juntos
+func (e *executor) executeSelections(ctx context.Context, object *Object, sources []any, selections []*Selection, path []string) []*orderedMap {
+	results := make([]*orderedMap, len(sources))
+	for i := range results {
+		results[i] = newOrderedMap(len(selections))
+	}
+
+	for _, selection := range selections {
+		key := selection.ResponseKey()
+		fieldPath := append(append([]string{}, path...), key)
+		if selection.Name == "__typename" {
+			for _, result := range results {
+				result.set(key, object.Name)
+			}
+			continue
+		}
+
+		field := object.Fields[selection.Name]
+		values, err := e.resolve(ctx, field, selection, sources)
+		if err != nil {
+			e.errors = append(e.errors, Error{Message: err.Error(), Path: fieldPath})
+			for _, result := range results {
+				result.set(key, nil)
+			}
+			continue
+		}
+
+		if field.Type == nil {
+			for i, result := range results {
+				result.set(key, values[i])
+			}
+			continue
+		}
+		for i, value := range e.completeObjects(ctx, field, selection, values, fieldPath) {
+			results[i].set(key, value)
+		}
+	}
+	return results
+}
+
+func (e *executor) resolve(ctx context.Context, field *Field, selection *Selection, sources []any) ([]any, error) {
+	args := make(Args, len(selection.Arguments))
+	for name, value := range selection.Arguments {
+		args[name] = e.resolveValue(value)
+	}
+
+	resolve := field.Resolve
+	if resolve == nil {
+		resolve = defaultResolver(selection.Name)
+	}
+	values, err := resolve(ctx, sources, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(sources) {
+		return nil, fmt.Errorf("resolvedor de %s retornou %d valores para %d objetos", selection.Name, len(values), len(sources))
+	}
+	return values, nil
+}
+
+// completeObjects executa os subcampos de uma só vez para os filhos de todos os objetos
+// (achatando as listas) e remonta o resultado de cada objeto. Os itens das listas chegam
+// aos resolvedores como ponteiros para os elementos.
+func (e *executor) completeObjects(ctx context.Context, field *Field, selection *Selection, values []any, path []string) []any {
+	completed := make([]any, len(values))
+	var children []any
+	owners := make([][]int, len(values))
+	for i, value := range values {
+		if isNil(value) {
+			if field.List {
+				completed[i] = []any{}
+			}
+			continue
+		}
+		if !field.List {
+			owners[i] = []int{len(children)}
+			children = append(children, value)
+			continue
+		}
+		list := reflect.ValueOf(value)
+		if list.Kind() != reflect.Slice {
+			e.errors = append(e.errors, Error{Message: fmt.Sprintf("campo %s deveria retornar uma lista", selection.Name), Path: path})
+			continue
+		}
+		owners[i] = make([]int, 0, list.Len())
+		for j := 0; j < list.Len(); j++ {
+			owners[i] = append(owners[i], len(children))
+			children = append(children, list.Index(j).Addr().Interface())
+		}
+	}
+	if len(children) == 0 {
+		return completed
+	}
+
+	childResults := e.executeSelections(ctx, field.Type, children, selection.Selections, path)
+	for i, indexes := range owners {
+		if indexes == nil {
+			continue
+		}
+		if !field.List {
+			completed[i] = childResults[indexes[0]]
+			continue
+		}
+		items := make([]any, len(indexes))
+		for j, index := range indexes {
+			items[j] = childResults[index]
+		}
+		completed[i] = items
+	}
+	return completed
+}
+
+// resolveValue substitui as variáveis pelos valores informados na requisição
+func (e *executor) resolveValue(value any) any {
+	switch v := value.(type) {
+	case variable:
+		return normalizeVariable(e.variables[string(v)])
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = e.resolveValue(item)
+		}
+		return list
+	case map[string]any:
+		object := make(map[string]any, len(v))
+		for name, item := range v {
+			object[name] = e.resolveValue(item)
+		}
+		return object
+	}
+	return value
+}
+
+// normalizeVariable converte os números inteiros vindos do JSON (float64) para int64,
+// como os literais da consulta
+func normalizeVariable(value any) any {
+	if number, ok := value.(float64); ok && number == float64(int64(number)) {
+		return int64(number)
+	}
+	return value
+}
+
+// orderedMap mantém os campos na ordem da seleção ao serializar a resposta
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap(size int) *orderedMap {
+	return &orderedMap{keys: make([]string, 0, size), values: make(map[string]any, size)}
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// MarshalJSON serializa os campos na ordem em que foram selecionados
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
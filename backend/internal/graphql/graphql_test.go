@@ -0,0 +1,151 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type author struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type book struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	AuthorID int    `json:"author_id"`
+}
+
+// testSchema monta um schema de livros e autores que conta as chamadas de cada resolvedor
+func testSchema(calls map[string]int) *Schema {
+	authors := map[int]author{1: {ID: 1, Name: "Machado"}, 2: {ID: 2, Name: "Clarice"}}
+	books := []book{{ID: 10, Title: "Dom Casmurro", AuthorID: 1}, {ID: 11, Title: "A Hora da Estrela", AuthorID: 2}, {ID: 12, Title: "Memórias Póstumas", AuthorID: 1}}
+
+	authorType := NewObject("Author", "id", "name")
+	bookType := NewObject("Book", "id", "title")
+	bookType.Fields["author"] = &Field{Type: authorType, Resolve: func(_ context.Context, sources []any, _ Args) ([]any, error) {
+		calls["author"]++
+		values := make([]any, len(sources))
+		for i, source := range sources {
+			if a, ok := authors[source.(*book).AuthorID]; ok {
+				values[i] = &a
+			}
+		}
+		return values, nil
+	}}
+	authorType.Fields["books"] = &Field{Type: bookType, List: true, Resolve: func(_ context.Context, sources []any, _ Args) ([]any, error) {
+		calls["books"]++
+		values := make([]any, len(sources))
+		for i, source := range sources {
+			var list []book
+			for _, b := range books {
+				if b.AuthorID == source.(*author).ID {
+					list = append(list, b)
+				}
+			}
+			values[i] = list
+		}
+		return values, nil
+	}}
+
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"books": {Type: bookType, List: true, Args: []string{"limit"}, Resolve: func(_ context.Context, sources []any, args Args) ([]any, error) {
+			limit, err := args.Int("limit", len(books))
+			if err != nil {
+				return nil, err
+			}
+			return []any{books[:limit]}, nil
+		}},
+		"author": {Type: authorType, Args: []string{"id"}, Resolve: func(_ context.Context, sources []any, args Args) ([]any, error) {
+			id, err := args.Int("id", 0)
+			if err != nil {
+				return nil, err
+			}
+			if a, ok := authors[id]; ok {
+				return []any{&a}, nil
+			}
+			return []any{nil}, nil
+		}},
+		"failing": {Resolve: func(context.Context, []any, Args) ([]any, error) {
+			return nil, fmt.Errorf("falha proposital")
+		}},
+	}}
+	return &Schema{Query: query, MaxDepth: 4}
+}
+
+func execute(t *testing.T, schema *Schema, request Request) string {
+	t.Helper()
+	body, err := json.Marshal(schema.Execute(context.Background(), request))
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestExecuteResolvesEachLevelInOneBatch(t *testing.T) {
+	calls := map[string]int{}
+	body := execute(t, testSchema(calls), Request{Query: `
+		# livros com o autor e os demais livros do autor
+		{ books { title author { name books { id } } } }`})
+
+	assert.JSONEq(t, `{"data": {"books": [
+		{"title": "Dom Casmurro", "author": {"name": "Machado", "books": [{"id": 10}, {"id": 12}]}},
+		{"title": "A Hora da Estrela", "author": {"name": "Clarice", "books": [{"id": 11}]}},
+		{"title": "Memórias Póstumas", "author": {"name": "Machado", "books": [{"id": 10}, {"id": 12}]}}
+	]}}`, body)
+	assert.Equal(t, map[string]int{"author": 1, "books": 1}, calls, "um resolvedor por nível, não por objeto")
+}
+
+func TestExecuteKeepsSelectionOrderAndAliases(t *testing.T) {
+	body := execute(t, testSchema(map[string]int{}), Request{
+		Query:     `query Autor($id: Int = 1, $limit: Int) { first: books(limit: $limit) { __typename title } autor: author(id: $id) { name id } }`,
+		Variables: map[string]any{"limit": float64(1)},
+	})
+
+	assert.Equal(t, `{"data":{"first":[{"__typename":"Book","title":"Dom Casmurro"}],"autor":{"name":"Machado","id":1}}}`, body)
+}
+
+func TestExecuteReportsResolverErrorsWithPath(t *testing.T) {
+	body := execute(t, testSchema(map[string]int{}), Request{Query: `{ author(id: 3) { name } failing }`})
+
+	assert.JSONEq(t, `{"data": {"author": null, "failing": null},
+		"errors": [{"message": "falha proposital", "path": ["failing"]}]}`, body)
+}
+
+func TestExecuteRejectsInvalidQueries(t *testing.T) {
+	schema := testSchema(map[string]int{})
+	cases := map[string]Request{
+		`campo "price" não existe no tipo Book`:                                   {Query: `{ books { price } }`},
+		`argumento "order" não existe no campo Query.books`:                       {Query: `{ books(order: ASC) { id } }`},
+		`selecione os subcampos de Query.author`:                                  {Query: `{ author(id: 1) }`},
+		`campo Book.title é escalar e não aceita subcampos`:                       {Query: `{ books { title { x } } }`},
+		`variável $id não informada`:                                              {Query: `query ($id: Int!) { author(id: $id) { id } }`},
+		`somente consultas (query) são suportadas; use a API REST para gravações`: {Query: `mutation { books { id } }`},
+		`consulta excede a profundidade máxima de 4 níveis`:                       {Query: `{ books { author { books { author { name } } } } }`},
+		`fragmentos não são suportados (posição 10)`:                              {Query: `{ books { ...bookFields } }`},
+		`fim inesperado da consulta, esperado um nome`:                            {Query: `{ books { id }`},
+		`informe operationName: a consulta tem mais de uma operação`:              {Query: `query A { books { id } } query B { books { id } }`},
+	}
+	for message, request := range cases {
+		response := schema.Execute(context.Background(), request)
+		assert.Nil(t, response.Data, message)
+		require.Len(t, response.Errors, 1, message)
+		assert.Equal(t, message, response.Errors[0].Message)
+	}
+}
+
+func TestParseArgumentValues(t *testing.T) {
+	doc, err := Parse(`{ f(a: -2, b: 1.5, c: "x\"y", d: [1, $v], e: {k: true, n: null}, g: ABC) { id } }`)
+	require.NoError(t, err)
+
+	args := doc.Operations[0].Selections[0].Arguments
+	assert.Equal(t, int64(-2), args["a"])
+	assert.Equal(t, 1.5, args["b"])
+	assert.Equal(t, `x"y`, args["c"])
+	assert.Equal(t, []any{int64(1), variable("v")}, args["d"])
+	assert.Equal(t, map[string]any{"k": true, "n": nil}, args["e"])
+	assert.Equal(t, enumValue("ABC"), args["g"])
+}
@@ -0,0 +1,430 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Document é uma consulta já interpretada, com uma ou mais operações
+type Document struct {
+	Operations []*Operation
+}
+
+// Operation é uma operação do documento. Somente consultas (query) são executadas.
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Selections []*Selection
+}
+
+// VariableDefinition declara uma variável da operação e seu valor padrão opcional
+type VariableDefinition struct {
+	Name       string
+	Default    any
+	HasDefault bool
+}
+
+// Selection é um campo selecionado, com apelido, argumentos e os subcampos
+type Selection struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]any
+	Selections []*Selection
+}
+
+// ResponseKey é a chave do campo na resposta: o apelido, se houver, ou o nome do campo
+func (s *Selection) ResponseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// variable é a referência a uma variável ($nome) dentro de um argumento
+type variable string
+
+// enumValue é um valor de enum (nome sem aspas) dentro de um argumento
+type enumValue string
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// Parse interpreta o texto da consulta. Fragmentos e diretivas não são suportados.
+func Parse(query string) (*Document, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	doc := &Document{}
+	for p.peek().kind != tokenEOF {
+		operation, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.Operations = append(doc.Operations, operation)
+	}
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("consulta vazia")
+	}
+	return doc, nil
+}
+
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == ',' || unicode.IsSpace(r) || r == '\uFEFF':
+			i++
+		case r == '.':
+			if i+2 < len(runes) && runes[i+1] == '.' && runes[i+2] == '.' {
+				return nil, fmt.Errorf("fragmentos não são suportados (posição %d)", i)
+			}
+			return nil, fmt.Errorf("caractere inesperado %q na posição %d", r, i)
+		case strings.ContainsRune("{}():$![]=@", r):
+			tokens = append(tokens, token{kind: tokenPunct, value: string(r), pos: i})
+			i++
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, value: string(runes[start:i]), pos: start})
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			i++
+			kind := tokenInt
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE+-", runes[i])) {
+				if !unicode.IsDigit(runes[i]) {
+					kind = tokenFloat
+				}
+				i++
+			}
+			tokens = append(tokens, token{kind: kind, value: string(runes[start:i]), pos: start})
+		case r == '"':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' {
+					i++
+				}
+				if i < len(runes) && runes[i] == '\n' {
+					break
+				}
+				i++
+			}
+			if i >= len(runes) || runes[i] != '"' {
+				return nil, fmt.Errorf("texto sem aspas de fechamento na posição %d", start)
+			}
+			i++
+			var value string
+			if err := json.Unmarshal([]byte(string(runes[start:i])), &value); err != nil {
+				return nil, fmt.Errorf("texto inválido na posição %d", start)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: start})
+		default:
+			return nil, fmt.Errorf("caractere inesperado %q na posição %d", r, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(value string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.value == value
+}
+
+func (p *parser) expectPunct(value string) error {
+	t := p.next()
+	if t.kind != tokenPunct || t.value != value {
+		return p.unexpected(t, fmt.Sprintf("%q", value))
+	}
+	return nil
+}
+
+func (p *parser) expectName() (string, error) {
+	t := p.next()
+	if t.kind != tokenName {
+		return "", p.unexpected(t, "um nome")
+	}
+	return t.value, nil
+}
+
+func (p *parser) unexpected(t token, expected string) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("fim inesperado da consulta, esperado %s", expected)
+	}
+	return fmt.Errorf("%q inesperado na posição %d, esperado %s", t.value, t.pos, expected)
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	operation := &Operation{Type: "query"}
+	if p.isPunct("{") {
+		selections, err := p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+		operation.Selections = selections
+		return operation, nil
+	}
+
+	t := p.next()
+	if t.kind != tokenName || (t.value != "query" && t.value != "mutation" && t.value != "subscription") {
+		if t.kind == tokenName && t.value == "fragment" {
+			return nil, fmt.Errorf("fragmentos não são suportados (posição %d)", t.pos)
+		}
+		return nil, p.unexpected(t, "uma operação")
+	}
+	operation.Type = t.value
+	if p.peek().kind == tokenName {
+		operation.Name = p.next().value
+	}
+
+	if p.isPunct("(") {
+		p.next()
+		for !p.isPunct(")") {
+			definition, err := p.parseVariableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			operation.Variables = append(operation.Variables, definition)
+		}
+		p.next()
+	}
+	if p.isPunct("@") {
+		return nil, fmt.Errorf("diretivas não são suportadas (posição %d)", p.peek().pos)
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	operation.Selections = selections
+	return operation, nil
+}
+
+func (p *parser) parseVariableDefinition() (*VariableDefinition, error) {
+	if err := p.expectPunct("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct(":"); err != nil {
+		return nil, err
+	}
+	if err := p.skipType(); err != nil {
+		return nil, err
+	}
+
+	definition := &VariableDefinition{Name: name}
+	if p.isPunct("=") {
+		p.next()
+		value, err := p.parseValue(true)
+		if err != nil {
+			return nil, err
+		}
+		definition.Default, definition.HasDefault = value, true
+	}
+	return definition, nil
+}
+
+// skipType consome o tipo da variável (Int, [Int!]!, ...); os valores são conferidos pelos resolvedores
+func (p *parser) skipType() error {
+	if p.isPunct("[") {
+		p.next()
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return err
+	}
+	if p.isPunct("!") {
+		p.next()
+	}
+	return nil
+}
+
+func (p *parser) parseSelectionSet() ([]*Selection, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+
+	var selections []*Selection
+	for !p.isPunct("}") {
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	p.next()
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("seleção de campos vazia")
+	}
+	return selections, nil
+}
+
+func (p *parser) parseSelection() (*Selection, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	selection := &Selection{Name: name}
+	if p.isPunct(":") {
+		p.next()
+		selection.Alias = name
+		if selection.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isPunct("(") {
+		p.next()
+		selection.Arguments = make(map[string]any)
+		for !p.isPunct(")") {
+			argument, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			selection.Arguments[argument] = value
+		}
+		p.next()
+	}
+	if p.isPunct("@") {
+		return nil, fmt.Errorf("diretivas não são suportadas (posição %d)", p.peek().pos)
+	}
+
+	if p.isPunct("{") {
+		if selection.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return selection, nil
+}
+
+// parseValue lê um valor literal; variáveis não são aceitas nos valores padrão (constant)
+func (p *parser) parseValue(constant bool) (any, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenInt:
+		value, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inteiro inválido %q na posição %d", t.value, t.pos)
+		}
+		return value, nil
+	case tokenFloat:
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inválido %q na posição %d", t.value, t.pos)
+		}
+		return value, nil
+	case tokenString:
+		return t.value, nil
+	case tokenName:
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return enumValue(t.value), nil
+	case tokenPunct:
+		switch t.value {
+		case "$":
+			if constant {
+				return nil, fmt.Errorf("variável não permitida na posição %d", t.pos)
+			}
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			return variable(name), nil
+		case "[":
+			list := make([]any, 0)
+			for !p.isPunct("]") {
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			object := make(map[string]any)
+			for !p.isPunct("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				object[name] = value
+			}
+			p.next()
+			return object, nil
+		}
+	}
+	return nil, p.unexpected(t, "um valor")
+}
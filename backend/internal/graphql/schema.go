@@ -0,0 +1,142 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Resolver resolve um campo para todos os objetos do mesmo nível de uma só vez e retorna
+// um valor por objeto, na mesma ordem. Carregar os relacionamentos em lote (WHERE id IN ...)
+// é o que evita uma query por objeto (N+1).
+type Resolver func(ctx context.Context, sources []any, args Args) ([]any, error)
+
+// Object é um tipo de objeto do schema
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field é um campo de um objeto. Sem Type, o campo é escalar; sem Resolve, o valor é lido
+// do campo da struct com a tag json de mesmo nome.
+type Field struct {
+	Type    *Object
+	List    bool
+	Args    []string
+	Resolve Resolver
+}
+
+// Schema reúne o tipo raiz das consultas e os limites de execução
+type Schema struct {
+	Query *Object
+	// MaxDepth limita o aninhamento das seleções; zero não limita
+	MaxDepth int
+}
+
+// NewObject cria um tipo de objeto com os campos escalares informados
+func NewObject(name string, scalars ...string) *Object {
+	object := &Object{Name: name, Fields: make(map[string]*Field, len(scalars))}
+	for _, scalar := range scalars {
+		object.Fields[scalar] = &Field{}
+	}
+	return object
+}
+
+// Args são os argumentos de um campo, com as variáveis já substituídas
+type Args map[string]any
+
+// Int retorna o argumento inteiro ou o padrão, se ausente
+func (a Args) Int(name string, fallback int) (int, error) {
+	value, ok := a[name]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+	switch number := value.(type) {
+	case int64:
+		return int(number), nil
+	case float64:
+		if number == float64(int(number)) {
+			return int(number), nil
+		}
+	}
+	return 0, fmt.Errorf("argumento %s deve ser um número inteiro", name)
+}
+
+// String retorna o argumento texto (ou enum) ou o padrão, se ausente
+func (a Args) String(name, fallback string) (string, error) {
+	value, ok := a[name]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+	switch text := value.(type) {
+	case string:
+		return text, nil
+	case enumValue:
+		return string(text), nil
+	}
+	return "", fmt.Errorf("argumento %s deve ser um texto", name)
+}
+
+// jsonFields guarda, por tipo, o índice de cada campo da struct pela tag json
+var jsonFields sync.Map
+
+// defaultResolver lê de cada objeto o campo da struct com a tag json igual ao nome do campo
+func defaultResolver(name string) Resolver {
+	return func(_ context.Context, sources []any, _ Args) ([]any, error) {
+		values := make([]any, len(sources))
+		for i, source := range sources {
+			value := reflect.ValueOf(source)
+			for value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					break
+				}
+				value = value.Elem()
+			}
+			if value.Kind() != reflect.Struct {
+				continue
+			}
+			index, ok := structFields(value.Type())[name]
+			if !ok {
+				return nil, fmt.Errorf("campo %s não encontrado em %s", name, value.Type())
+			}
+			values[i] = value.FieldByIndex(index).Interface()
+		}
+		return values, nil
+	}
+}
+
+func structFields(t reflect.Type) map[string][]int {
+	if cached, ok := jsonFields.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, exists := fields[name]; !exists {
+			fields[name] = field.Index
+		}
+	}
+	jsonFields.Store(t, fields)
+	return fields
+}
+
+// isNil trata ponteiros, slices e mapas nulos como null
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
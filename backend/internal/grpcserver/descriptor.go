@@ -0,0 +1,220 @@
+package grpcserver
+
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Sem o protoc no build, os arquivos .proto são descritos em Go com os construtores abaixo;
+// o .proto publicado para os clientes é gerado a partir do mesmo descritor (ver PrintProto).
+
+// File monta o descritor de um arquivo proto3 do pacote informado
+func File(path, pkg string, messages []*descriptorpb.DescriptorProto, services ...*descriptorpb.ServiceDescriptorProto) *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(path),
+		Package:     proto.String(pkg),
+		Syntax:      proto.String("proto3"),
+		MessageType: messages,
+		Service:     services,
+	}
+}
+
+// Message monta o descritor de uma mensagem
+func Message(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+}
+
+// Field monta um campo escalar; o nome deve ser igual à tag json do modelo, para o Fill
+func Field(number int32, name string, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     kind.Enum(),
+		JsonName: proto.String(jsonName(name)),
+	}
+}
+
+// MessageField monta um campo do tipo de outra mensagem do pacote (typeName totalmente qualificado)
+func MessageField(number int32, name, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := Field(number, name, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	field.TypeName = proto.String("." + typeName)
+	return field
+}
+
+// Repeated transforma o campo em lista
+func Repeated(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return field
+}
+
+// Service monta o descritor de um serviço
+func Service(name string, methods ...*descriptorpb.MethodDescriptorProto) *descriptorpb.ServiceDescriptorProto {
+	return &descriptorpb.ServiceDescriptorProto{Name: proto.String(name), Method: methods}
+}
+
+// Method monta um método unário (input e output totalmente qualificados)
+func Method(name, input, output string) *descriptorpb.MethodDescriptorProto {
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String("." + input),
+		OutputType: proto.String("." + output),
+	}
+}
+
+// BuildFile valida o descritor e o converte para uso em tempo de execução
+func BuildFile(file *descriptorpb.FileDescriptorProto) (protoreflect.FileDescriptor, error) {
+	return protodesc.NewFile(file, nil)
+}
+
+// jsonName converte snake_case para lowerCamelCase, como o protoc faz
+func jsonName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// jsonFields guarda, por tipo, o índice de cada campo da struct pela tag json
+var jsonFields sync.Map
+
+func structFields(t reflect.Type) map[string][]int {
+	if cached, ok := jsonFields.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, exists := fields[name]; !exists {
+			fields[name] = field.Index
+		}
+	}
+	jsonFields.Store(t, fields)
+	return fields
+}
+
+// Fill preenche a mensagem com os campos da struct de mesmo nome na tag json. Datas viram texto
+// RFC 3339 (vazio quando zeradas), ponteiros nulos ficam com o valor padrão e slices de structs
+// viram listas de mensagens.
+func Fill(message protoreflect.Message, source any) error {
+	value := reflect.ValueOf(source)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("%s: origem deve ser uma struct, recebido %s", message.Descriptor().FullName(), value.Kind())
+	}
+
+	fields := structFields(value.Type())
+	descriptors := message.Descriptor().Fields()
+	for i := 0; i < descriptors.Len(); i++ {
+		field := descriptors.Get(i)
+		index, ok := fields[string(field.Name())]
+		if !ok {
+			return fmt.Errorf("%s: campo %s sem correspondente em %s", message.Descriptor().FullName(), field.Name(), value.Type())
+		}
+		if err := setField(message, field, value.FieldByIndex(index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setField(message protoreflect.Message, field protoreflect.FieldDescriptor, value reflect.Value) error {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if field.IsList() {
+		if value.Kind() != reflect.Slice {
+			return fmt.Errorf("campo %s: esperado slice, recebido %s", field.FullName(), value.Kind())
+		}
+		list := message.Mutable(field).List()
+		for i := 0; i < value.Len(); i++ {
+			if field.Kind() == protoreflect.MessageKind {
+				item := list.NewElement()
+				if err := Fill(item.Message(), value.Index(i).Interface()); err != nil {
+					return err
+				}
+				list.Append(item)
+				continue
+			}
+			item, err := scalarValue(field, value.Index(i))
+			if err != nil {
+				return err
+			}
+			list.Append(item)
+		}
+		return nil
+	}
+
+	if field.Kind() == protoreflect.MessageKind {
+		return Fill(message.Mutable(field).Message(), value.Interface())
+	}
+	item, err := scalarValue(field, value)
+	if err != nil {
+		return err
+	}
+	message.Set(field, item)
+	return nil
+}
+
+func scalarValue(field protoreflect.FieldDescriptor, value reflect.Value) (protoreflect.Value, error) {
+	if date, ok := value.Interface().(time.Time); ok {
+		if date.IsZero() {
+			return protoreflect.ValueOfString(""), nil
+		}
+		return protoreflect.ValueOfString(date.Format(time.RFC3339)), nil
+	}
+
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		if value.Kind() == reflect.String {
+			return protoreflect.ValueOfString(value.String()), nil
+		}
+	case protoreflect.BoolKind:
+		if value.Kind() == reflect.Bool {
+			return protoreflect.ValueOfBool(value.Bool()), nil
+		}
+	case protoreflect.Int64Kind:
+		if value.CanInt() {
+			return protoreflect.ValueOfInt64(value.Int()), nil
+		}
+	case protoreflect.Int32Kind:
+		if value.CanInt() {
+			return protoreflect.ValueOfInt32(int32(value.Int())), nil
+		}
+	case protoreflect.DoubleKind:
+		if amount, ok := value.Interface().(money.Decimal); ok {
+			return protoreflect.ValueOfFloat64(amount.Float64()), nil
+		}
+		if value.CanFloat() {
+			return protoreflect.ValueOfFloat64(value.Float()), nil
+		}
+	}
+	return protoreflect.Value{}, fmt.Errorf("campo %s: tipo %s incompatível com %s", field.FullName(), value.Type(), field.Kind())
+}
@@ -0,0 +1,269 @@
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type testItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+type testRecord struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	Tags      []string   `json:"tags"`
+	Items     []testItem `json:"items"`
+	Main      *testItem  `json:"main"`
+}
+
+func testFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	file, err := BuildFile(File("test/v1/test.proto", "test.v1", []*descriptorpb.DescriptorProto{
+		Message("GetRecordRequest", Field(1, "id", descriptorpb.FieldDescriptorProto_TYPE_INT64)),
+		Message("Item",
+			Field(1, "name", descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			Field(2, "price", descriptorpb.FieldDescriptorProto_TYPE_DOUBLE)),
+		Message("Record",
+			Field(1, "id", descriptorpb.FieldDescriptorProto_TYPE_INT64),
+			Field(2, "name", descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			Field(3, "active", descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+			Field(4, "created_at", descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			Field(5, "deleted_at", descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			Repeated(Field(6, "tags", descriptorpb.FieldDescriptorProto_TYPE_STRING)),
+			Repeated(MessageField(7, "items", "test.v1.Item")),
+			MessageField(8, "main", "test.v1.Item")),
+	}, Service("RecordService", Method("GetRecord", "test.v1.GetRecordRequest", "test.v1.Record"))))
+	require.NoError(t, err)
+	return file
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	file := testFile(t)
+	server := NewServer()
+	require.NoError(t, server.RegisterFile(file))
+
+	method := file.Services().Get(0).Methods().Get(0)
+	server.HandleUnary(method, func(ctx context.Context, request *dynamicpb.Message) (proto.Message, error) {
+		id := request.Get(request.Descriptor().Fields().ByName("id")).Int()
+		if id != 1 {
+			return nil, errors.ErrContactNotFound
+		}
+		response := dynamicpb.NewMessage(method.Output())
+		err := Fill(response, testRecord{ID: 1, Name: Metadata(ctx).Get("X-Caller"), Active: true,
+			CreatedAt: time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC), Tags: []string{"a", "b"},
+			Items: []testItem{{Name: "x", Price: 1.5}}, Main: &testItem{Name: "y"}})
+		return response, err
+	})
+
+	ts := httptest.NewUnstartedServer(server)
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func frame(message []byte) []byte {
+	data := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(data[1:], uint32(len(message)))
+	return append(data, message...)
+}
+
+// call faz a chamada gRPC e retorna as mensagens da resposta, o grpc-status e o grpc-message
+func call(t *testing.T, ts *httptest.Server, path string, messages ...[]byte) ([][]byte, string, string) {
+	t.Helper()
+	var body bytes.Buffer
+	for _, message := range messages {
+		body.Write(frame(message))
+	}
+	request, err := http.NewRequest(http.MethodPost, ts.URL+path, &body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/grpc")
+	request.Header.Set("X-Caller", "pdv")
+
+	response, err := ts.Client().Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, 2, response.ProtoMajor)
+
+	data, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	var frames [][]byte
+	for len(data) >= 5 {
+		size := binary.BigEndian.Uint32(data[1:5])
+		frames = append(frames, data[5:5+size])
+		data = data[5+size:]
+	}
+	message, err := url.PathUnescape(response.Trailer.Get("Grpc-Message"))
+	require.NoError(t, err)
+	return frames, response.Trailer.Get("Grpc-Status"), message
+}
+
+func TestUnaryCallFillsResponseFromStruct(t *testing.T) {
+	ts := newTestServer(t)
+	file := testFile(t)
+
+	request := dynamicpb.NewMessage(file.Messages().ByName("GetRecordRequest"))
+	request.Set(request.Descriptor().Fields().ByName("id"), protoreflect.ValueOfInt64(1))
+	data, err := proto.Marshal(request)
+	require.NoError(t, err)
+
+	frames, status, _ := call(t, ts, "/test.v1.RecordService/GetRecord", data)
+	assert.Equal(t, "0", status)
+	require.Len(t, frames, 1)
+
+	record := dynamicpb.NewMessage(file.Messages().ByName("Record"))
+	require.NoError(t, proto.Unmarshal(frames[0], record))
+	fields := record.Descriptor().Fields()
+	assert.Equal(t, "pdv", record.Get(fields.ByName("name")).String())
+	assert.True(t, record.Get(fields.ByName("active")).Bool())
+	assert.Equal(t, "2026-05-10T12:00:00Z", record.Get(fields.ByName("created_at")).String())
+	assert.Equal(t, "", record.Get(fields.ByName("deleted_at")).String())
+	assert.Equal(t, 2, record.Get(fields.ByName("tags")).List().Len())
+	items := record.Get(fields.ByName("items")).List()
+	require.Equal(t, 1, items.Len())
+	assert.Equal(t, 1.5, items.Get(0).Message().Get(items.Get(0).Message().Descriptor().Fields().ByName("price")).Float())
+	main := record.Get(fields.ByName("main")).Message()
+	assert.Equal(t, "y", main.Get(main.Descriptor().Fields().ByName("name")).String())
+}
+
+func TestUnaryCallMapsErrorsToStatus(t *testing.T) {
+	ts := newTestServer(t)
+
+	var request []byte
+	request = protowire.AppendTag(request, 1, protowire.VarintType)
+	request = protowire.AppendVarint(request, 2)
+	frames, status, message := call(t, ts, "/test.v1.RecordService/GetRecord", request)
+	assert.Empty(t, frames)
+	assert.Equal(t, "5", status)
+	assert.Equal(t, "contato não encontrado", message)
+
+	_, status, message = call(t, ts, "/test.v1.RecordService/DeleteRecord", request)
+	assert.Equal(t, "12", status)
+	assert.Equal(t, "método /test.v1.RecordService/DeleteRecord não implementado", message)
+
+	_, status, _ = call(t, ts, "/test.v1.RecordService/GetRecord")
+	assert.Equal(t, "3", status)
+}
+
+func TestHealthCheck(t *testing.T) {
+	ts := newTestServer(t)
+
+	frames, status, _ := call(t, ts, "/grpc.health.v1.Health/Check", nil)
+	assert.Equal(t, "0", status)
+	require.Len(t, frames, 1)
+	assert.Equal(t, []byte{0x08, 0x01}, frames[0], "status SERVING")
+
+	var request []byte
+	request = protowire.AppendTag(request, 1, protowire.BytesType)
+	request = protowire.AppendString(request, "test.v1.Unknown")
+	_, status, _ = call(t, ts, "/grpc.health.v1.Health/Check", request)
+	assert.Equal(t, "5", status)
+}
+
+func TestReflectionListsServicesAndFiles(t *testing.T) {
+	ts := newTestServer(t)
+
+	var listServices, fileBySymbol []byte
+	listServices = protowire.AppendTag(listServices, reflectionListServices, protowire.BytesType)
+	listServices = protowire.AppendString(listServices, "*")
+	fileBySymbol = protowire.AppendTag(fileBySymbol, reflectionFileContainingSymbol, protowire.BytesType)
+	fileBySymbol = protowire.AppendString(fileBySymbol, "test.v1.RecordService")
+
+	frames, status, _ := call(t, ts, "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", listServices, fileBySymbol)
+	assert.Equal(t, "0", status)
+	require.Len(t, frames, 2)
+
+	for _, service := range []string{"grpc.health.v1.Health", "grpc.reflection.v1.ServerReflection", "test.v1.RecordService"} {
+		assert.Contains(t, string(frames[0]), service)
+	}
+
+	var fileProto descriptorpb.FileDescriptorProto
+	files := fieldBytes(t, frames[1], reflectionFileDescriptorResp)
+	require.NoError(t, proto.Unmarshal(fieldBytes(t, files, 1), &fileProto))
+	assert.Equal(t, "test/v1/test.proto", fileProto.GetName())
+}
+
+// fieldBytes retorna o primeiro campo do tipo bytes com o número informado
+func fieldBytes(t *testing.T, data []byte, field protowire.Number) []byte {
+	t.Helper()
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		require.Positive(t, n)
+		data = data[n:]
+		if wireType == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(data)
+			require.Positive(t, n)
+			if number == field {
+				return value
+			}
+			data = data[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(number, wireType, data)
+		require.Positive(t, n)
+		data = data[n:]
+	}
+	t.Fatalf("campo %d ausente", field)
+	return nil
+}
+
+func TestPrintProto(t *testing.T) {
+	file, err := BuildFile(healthFile())
+	require.NoError(t, err)
+
+	assert.Equal(t, `// Health check
+syntax = "proto3";
+
+package grpc.health.v1;
+
+message HealthCheckRequest {
+  string service = 1;
+}
+
+message HealthCheckResponse {
+  enum ServingStatus {
+    UNKNOWN = 0;
+    SERVING = 1;
+    NOT_SERVING = 2;
+    SERVICE_UNKNOWN = 3;
+  }
+  HealthCheckResponse.ServingStatus status = 1;
+}
+
+service Health {
+  rpc Check(HealthCheckRequest) returns (HealthCheckResponse);
+}
+`, PrintProto(file, "Health check"))
+}
+
+func TestParseTimeout(t *testing.T) {
+	timeout, ok := parseTimeout("500m")
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, timeout)
+
+	_, ok = parseTimeout("10x")
+	assert.False(t, ok)
+}
@@ -0,0 +1,60 @@
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// HealthService é o serviço padrão de health check do gRPC
+const HealthService = "grpc.health.v1.Health"
+
+// healthServing é o valor SERVING de HealthCheckResponse.ServingStatus
+const healthServing = 1
+
+// healthFile descreve grpc/health/v1/health.proto (somente o método Check)
+func healthFile() *descriptorpb.FileDescriptorProto {
+	file := File("grpc/health/v1/health.proto", "grpc.health.v1", []*descriptorpb.DescriptorProto{
+		Message("HealthCheckRequest", Field(1, "service", descriptorpb.FieldDescriptorProto_TYPE_STRING)),
+		Message("HealthCheckResponse", Field(1, "status", descriptorpb.FieldDescriptorProto_TYPE_ENUM)),
+	}, Service("Health", Method("Check", "grpc.health.v1.HealthCheckRequest", "grpc.health.v1.HealthCheckResponse")))
+
+	response := file.MessageType[1]
+	response.Field[0].TypeName = proto.String(".grpc.health.v1.HealthCheckResponse.ServingStatus")
+	response.EnumType = []*descriptorpb.EnumDescriptorProto{{
+		Name: proto.String("ServingStatus"),
+		Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+			{Name: proto.String("SERVING"), Number: proto.Int32(1)},
+			{Name: proto.String("NOT_SERVING"), Number: proto.Int32(2)},
+			{Name: proto.String("SERVICE_UNKNOWN"), Number: proto.Int32(3)},
+		},
+	}}
+	return file
+}
+
+// registerHealth responde Check com SERVING para o servidor ("") e para os serviços registrados
+func (s *Server) registerHealth() {
+	file, err := BuildFile(healthFile())
+	if err != nil {
+		panic("grpcserver: descritor de health inválido: " + err.Error())
+	}
+	if err := s.RegisterFile(file); err != nil {
+		panic("grpcserver: " + err.Error())
+	}
+
+	method := file.Services().Get(0).Methods().ByName("Check")
+	s.HandleUnary(method, func(_ context.Context, request *dynamicpb.Message) (proto.Message, error) {
+		service := request.Get(request.Descriptor().Fields().ByName("service")).String()
+		if service != "" && !s.services[service] {
+			return nil, Errorf(NotFound, "serviço %s desconhecido", service)
+		}
+
+		response := dynamicpb.NewMessage(method.Output())
+		response.Set(method.Output().Fields().ByName("status"), protoreflect.ValueOfEnum(healthServing))
+		return response, nil
+	})
+}
@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// PrintProto gera o texto .proto do arquivo, para os clientes gerarem os stubs com o protoc.
+// Cobre o que os descritores deste servidor usam: mensagens, enums, campos repetidos e métodos unários.
+func PrintProto(file protoreflect.FileDescriptor, header string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(header), "\n") {
+		fmt.Fprintf(&b, "// %s\n", line)
+	}
+	fmt.Fprintf(&b, "syntax = \"proto3\";\n\npackage %s;\n", file.Package())
+
+	pkg := string(file.Package())
+	for i := 0; i < file.Messages().Len(); i++ {
+		b.WriteString("\n")
+		printMessage(&b, file.Messages().Get(i), pkg, "")
+	}
+	for i := 0; i < file.Services().Len(); i++ {
+		service := file.Services().Get(i)
+		fmt.Fprintf(&b, "\nservice %s {\n", service.Name())
+		for j := 0; j < service.Methods().Len(); j++ {
+			method := service.Methods().Get(j)
+			fmt.Fprintf(&b, "  rpc %s(%s) returns (%s);\n", method.Name(),
+				typeName(method.Input().FullName(), pkg), typeName(method.Output().FullName(), pkg))
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func printMessage(b *strings.Builder, message protoreflect.MessageDescriptor, pkg, indent string) {
+	fmt.Fprintf(b, "%smessage %s {\n", indent, message.Name())
+	for i := 0; i < message.Enums().Len(); i++ {
+		enum := message.Enums().Get(i)
+		fmt.Fprintf(b, "%s  enum %s {\n", indent, enum.Name())
+		for j := 0; j < enum.Values().Len(); j++ {
+			value := enum.Values().Get(j)
+			fmt.Fprintf(b, "%s    %s = %d;\n", indent, value.Name(), value.Number())
+		}
+		fmt.Fprintf(b, "%s  }\n", indent)
+	}
+	for i := 0; i < message.Fields().Len(); i++ {
+		field := message.Fields().Get(i)
+		label := ""
+		if field.IsList() {
+			label = "repeated "
+		}
+		fmt.Fprintf(b, "%s  %s%s %s = %d;\n", indent, label, fieldType(field, pkg), field.Name(), field.Number())
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+func fieldType(field protoreflect.FieldDescriptor, pkg string) string {
+	switch field.Kind() {
+	case protoreflect.MessageKind:
+		return typeName(field.Message().FullName(), pkg)
+	case protoreflect.EnumKind:
+		return typeName(field.Enum().FullName(), pkg)
+	}
+	return field.Kind().String()
+}
+
+// typeName encurta o nome dos tipos do próprio pacote
+func typeName(name protoreflect.FullName, pkg string) string {
+	return strings.TrimPrefix(string(name), pkg+".")
+}
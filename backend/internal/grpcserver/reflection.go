@@ -0,0 +1,144 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Serviços de reflection (grpcurl, Postman, Evans); as duas versões têm as mesmas mensagens
+var reflectionServices = []string{"grpc.reflection.v1.ServerReflection", "grpc.reflection.v1alpha.ServerReflection"}
+
+// Números dos campos de ServerReflectionRequest e ServerReflectionResponse
+const (
+	reflectionHost                 = 1
+	reflectionFileByFilename       = 3
+	reflectionFileContainingSymbol = 4
+	reflectionFileContainingExt    = 5
+	reflectionAllExtensionNumbers  = 6
+	reflectionListServices         = 7
+
+	reflectionValidHost          = 1
+	reflectionOriginalRequest    = 2
+	reflectionFileDescriptorResp = 4
+	reflectionListServicesResp   = 6
+	reflectionErrorResp          = 7
+)
+
+func (s *Server) registerReflection() {
+	for _, service := range reflectionServices {
+		s.handleStream(service, "ServerReflectionInfo", s.serveReflection)
+	}
+}
+
+// serveReflection responde cada pedido do stream bidirecional até o cliente encerrar o envio
+func (s *Server) serveReflection(_ context.Context, stream *Stream) error {
+	for {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(s.reflectionResponse(request)); err != nil {
+			return err
+		}
+	}
+}
+
+// reflectionResponse monta a ServerReflectionResponse do pedido, codificando direto no formato do protobuf
+func (s *Server) reflectionResponse(request []byte) []byte {
+	var host string
+	var kind protowire.Number
+	var argument string
+	for data := request; len(data) > 0; {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return reflectionError(request, InvalidArgument, "pedido de reflection inválido")
+		}
+		data = data[n:]
+		if wireType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				return reflectionError(request, InvalidArgument, "pedido de reflection inválido")
+			}
+			data = data[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return reflectionError(request, InvalidArgument, "pedido de reflection inválido")
+		}
+		data = data[n:]
+		if number == reflectionHost {
+			host = string(value)
+		} else {
+			kind, argument = number, string(value)
+		}
+	}
+
+	var response []byte
+	response = protowire.AppendTag(response, reflectionValidHost, protowire.BytesType)
+	response = protowire.AppendString(response, host)
+	response = protowire.AppendTag(response, reflectionOriginalRequest, protowire.BytesType)
+	response = protowire.AppendBytes(response, request)
+
+	switch kind {
+	case reflectionListServices:
+		var services []byte
+		for _, name := range s.Services() {
+			var service []byte
+			service = protowire.AppendTag(service, 1, protowire.BytesType)
+			service = protowire.AppendString(service, name)
+			services = protowire.AppendTag(services, 1, protowire.BytesType)
+			services = protowire.AppendBytes(services, service)
+		}
+		response = protowire.AppendTag(response, reflectionListServicesResp, protowire.BytesType)
+		return protowire.AppendBytes(response, services)
+	case reflectionFileByFilename:
+		file, err := s.files.FindFileByPath(argument)
+		if err != nil {
+			return reflectionError(request, NotFound, "arquivo "+argument+" não encontrado")
+		}
+		return appendFileDescriptor(response, file)
+	case reflectionFileContainingSymbol:
+		descriptor, err := s.files.FindDescriptorByName(protoreflect.FullName(argument))
+		if err != nil {
+			return reflectionError(request, NotFound, "símbolo "+argument+" não encontrado")
+		}
+		return appendFileDescriptor(response, descriptor.ParentFile())
+	case reflectionFileContainingExt, reflectionAllExtensionNumbers:
+		return reflectionError(request, NotFound, "extensões não são usadas por este servidor")
+	}
+	return reflectionError(request, Unimplemented, "pedido de reflection não suportado")
+}
+
+func appendFileDescriptor(response []byte, file protoreflect.FileDescriptor) []byte {
+	data, err := proto.Marshal(protodesc.ToFileDescriptorProto(file))
+	if err != nil {
+		return response
+	}
+	var files []byte
+	files = protowire.AppendTag(files, 1, protowire.BytesType)
+	files = protowire.AppendBytes(files, data)
+	response = protowire.AppendTag(response, reflectionFileDescriptorResp, protowire.BytesType)
+	return protowire.AppendBytes(response, files)
+}
+
+func reflectionError(request []byte, code Code, message string) []byte {
+	var response, errorResponse []byte
+	response = protowire.AppendTag(response, reflectionOriginalRequest, protowire.BytesType)
+	response = protowire.AppendBytes(response, request)
+
+	errorResponse = protowire.AppendTag(errorResponse, 1, protowire.VarintType)
+	errorResponse = protowire.AppendVarint(errorResponse, uint64(code))
+	errorResponse = protowire.AppendTag(errorResponse, 2, protowire.BytesType)
+	errorResponse = protowire.AppendString(errorResponse, message)
+	response = protowire.AppendTag(response, reflectionErrorResp, protowire.BytesType)
+	return protowire.AppendBytes(response, errorResponse)
+}
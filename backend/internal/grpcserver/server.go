@@ -0,0 +1,283 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/binary"
+	stderrors "errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// maxMessageSize limita o tamanho de cada mensagem recebida (o mesmo padrão do grpc-go)
+const maxMessageSize = 4 << 20
+
+// UnaryHandler atende um método unário: recebe a requisição já decodificada pelo descritor do método
+type UnaryHandler func(ctx context.Context, request *dynamicpb.Message) (proto.Message, error)
+
+// StreamHandler atende um método com streaming, lendo e enviando mensagens pelo Stream
+type StreamHandler func(ctx context.Context, stream *Stream) error
+
+type unaryMethod struct {
+	desc    protoreflect.MethodDescriptor
+	handler UnaryHandler
+}
+
+// Server é um servidor gRPC mínimo sobre o HTTP/2 do net/http: métodos unários decodificados
+// pelos descritores registrados, mais os serviços de health e reflection
+type Server struct {
+	files    *protoregistry.Files
+	unary    map[string]unaryMethod
+	streams  map[string]StreamHandler
+	services map[string]bool
+	logger   *zap.Logger
+}
+
+// NewServer cria o servidor já com grpc.health.v1.Health e o serviço de reflection
+func NewServer() *Server {
+	s := &Server{
+		files:    new(protoregistry.Files),
+		unary:    make(map[string]unaryMethod),
+		streams:  make(map[string]StreamHandler),
+		services: make(map[string]bool),
+		logger:   logger.WithModule("grpc_server"),
+	}
+	s.registerHealth()
+	s.registerReflection()
+	return s
+}
+
+// RegisterFile publica o arquivo .proto no reflection
+func (s *Server) RegisterFile(file protoreflect.FileDescriptor) error {
+	return s.files.RegisterFile(file)
+}
+
+// HandleUnary registra o handler do método unário
+func (s *Server) HandleUnary(method protoreflect.MethodDescriptor, handler UnaryHandler) {
+	service := string(method.Parent().FullName())
+	s.unary["/"+service+"/"+string(method.Name())] = unaryMethod{desc: method, handler: handler}
+	s.services[service] = true
+}
+
+func (s *Server) handleStream(service, method string, handler StreamHandler) {
+	s.streams["/"+service+"/"+method] = handler
+	s.services[service] = true
+}
+
+// Services lista os serviços registrados, em ordem alfabética
+func (s *Server) Services() []string {
+	services := make([]string, 0, len(s.services))
+	for service := range s.services {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// ListenAndServe atende as chamadas em HTTP/2 sem TLS (h2c, como os clientes gRPC internos usam)
+// até o contexto ser cancelado
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		Protocols:         &protocols,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !stderrors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+type metadataKey struct{}
+
+type peerKey struct{}
+
+// WithMetadata associa os metadados ao contexto, como o ServeHTTP faz com os headers da chamada
+func WithMetadata(ctx context.Context, md http.Header) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// Metadata retorna os metadados (headers HTTP/2) da chamada
+func Metadata(ctx context.Context) http.Header {
+	md, _ := ctx.Value(metadataKey{}).(http.Header)
+	return md
+}
+
+// Peer retorna o IP do cliente da chamada
+func Peer(ctx context.Context) string {
+	peer, _ := ctx.Value(peerKey{}).(string)
+	return peer
+}
+
+// ServeHTTP atende uma chamada gRPC: path /pacote.Serviço/Método, mensagens com prefixo de
+// 5 bytes (compressão e tamanho) e o status nos trailers grpc-status e grpc-message
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requer HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "content-type deve ser application/grpc", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	ctx := WithMetadata(r.Context(), r.Header)
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ctx = context.WithValue(ctx, peerKey{}, host)
+	}
+	if timeout, ok := parseTimeout(r.Header.Get("Grpc-Timeout")); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	w.Header().Set("Content-Type", "application/grpc+proto")
+	stream := &Stream{body: r.Body, w: w}
+
+	var err error
+	if method, ok := s.unary[r.URL.Path]; ok {
+		err = s.serveUnary(ctx, method, stream)
+	} else if handler, ok := s.streams[r.URL.Path]; ok {
+		err = handler(ctx, stream)
+	} else {
+		err = Errorf(Unimplemented, "método %s não implementado", r.URL.Path)
+	}
+	if err == nil && ctx.Err() == context.DeadlineExceeded {
+		err = Errorf(DeadlineExceeded, "prazo da chamada esgotado")
+	}
+
+	status := &Status{Code: OK}
+	if err != nil {
+		status = FromError(err)
+		if status.Code == Internal || status.Code == Unknown {
+			s.logger.Error("erro na chamada gRPC", zap.String("method", r.URL.Path), zap.Error(err))
+		}
+	}
+
+	stream.writeHeader()
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(int(status.Code)))
+	if status.Message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeMessage(status.Message))
+	}
+}
+
+func (s *Server) serveUnary(ctx context.Context, method unaryMethod, stream *Stream) error {
+	data, err := stream.Recv()
+	if err == io.EOF {
+		return Errorf(InvalidArgument, "mensagem da requisição ausente")
+	}
+	if err != nil {
+		return err
+	}
+
+	request := dynamicpb.NewMessage(method.desc.Input())
+	if err := proto.Unmarshal(data, request); err != nil {
+		return Errorf(InvalidArgument, "mensagem inválida para %s: %v", method.desc.Input().FullName(), err)
+	}
+
+	response, err := method.handler(ctx, request)
+	if err != nil {
+		return err
+	}
+	data, err = proto.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return stream.Send(data)
+}
+
+// Stream lê as mensagens da requisição e envia as da resposta de uma chamada
+type Stream struct {
+	body        io.Reader
+	w           http.ResponseWriter
+	wroteHeader bool
+}
+
+// Recv lê a próxima mensagem; io.EOF indica que o cliente encerrou o envio
+func (s *Stream) Recv() ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(s.body, prefix[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, Errorf(InvalidArgument, "mensagem incompleta: %v", err)
+	}
+	if prefix[0] != 0 {
+		return nil, Errorf(Unimplemented, "mensagens comprimidas não são suportadas")
+	}
+
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxMessageSize {
+		return nil, Errorf(ResourceExhausted, "mensagem de %d bytes excede o limite de %d", size, maxMessageSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(s.body, data); err != nil {
+		return nil, Errorf(InvalidArgument, "mensagem incompleta: %v", err)
+	}
+	return data, nil
+}
+
+// Send envia uma mensagem da resposta imediatamente
+func (s *Stream) Send(data []byte) error {
+	s.writeHeader()
+	var prefix [5]byte
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(data)))
+	if _, err := s.w.Write(prefix[:]); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return err
+	}
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+func (s *Stream) writeHeader() {
+	if !s.wroteHeader {
+		s.wroteHeader = true
+		s.w.WriteHeader(http.StatusOK)
+	}
+}
+
+// parseTimeout interpreta o header grpc-timeout (ex.: 500m, 10S)
+func parseTimeout(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(amount) * unit, true
+}
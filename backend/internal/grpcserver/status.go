@@ -0,0 +1,90 @@
+package grpcserver
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+)
+
+// Code é o código de status do gRPC, enviado no trailer grpc-status
+type Code int
+
+// Códigos de status usados pelo servidor
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFound           Code = 5
+	AlreadyExists      Code = 6
+	PermissionDenied   Code = 7
+	ResourceExhausted  Code = 8
+	FailedPrecondition Code = 9
+	Unimplemented      Code = 12
+	Internal           Code = 13
+	Unavailable        Code = 14
+	Unauthenticated    Code = 16
+)
+
+// Status é um erro com código gRPC
+type Status struct {
+	Code    Code
+	Message string
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("grpc %d: %s", s.Code, s.Message)
+}
+
+// Errorf cria um erro com o código gRPC informado
+func Errorf(code Code, format string, args ...any) error {
+	return &Status{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// httpCodes converte o status HTTP dos erros do catálogo para o código gRPC equivalente
+var httpCodes = map[int]Code{
+	http.StatusBadRequest:            InvalidArgument,
+	http.StatusUnauthorized:          Unauthenticated,
+	http.StatusForbidden:             PermissionDenied,
+	http.StatusNotFound:              NotFound,
+	http.StatusConflict:              AlreadyExists,
+	http.StatusRequestEntityTooLarge: ResourceExhausted,
+	http.StatusUnprocessableEntity:   InvalidArgument,
+	http.StatusTooManyRequests:       ResourceExhausted,
+	http.StatusNotImplemented:        Unimplemented,
+	http.StatusServiceUnavailable:    Unavailable,
+}
+
+// FromError converte o erro de um método no status gRPC. Erros do catálogo (errors.APIError)
+// mantêm a mensagem e ganham o código equivalente ao status HTTP; os demais viram Internal.
+func FromError(err error) *Status {
+	var status *Status
+	if stderrors.As(err, &status) {
+		return status
+	}
+
+	apiErr := errors.ToAPIError(err, "")
+	code, ok := httpCodes[apiErr.Status]
+	if !ok {
+		code = Internal
+	}
+	return &Status{Code: code, Message: apiErr.Message}
+}
+
+// encodeMessage aplica o percent-encoding exigido no trailer grpc-message
+func encodeMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
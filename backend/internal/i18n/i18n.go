@@ -0,0 +1,104 @@
+// Package i18n traduz o que a API devolve ao usuário (mensagens de erro, rótulos dos status) e
+// os documentos gerados (PDF, e-mails). O idioma da requisição vem do Accept-Language e viaja no
+// context.Context (ver middleware.LanguageMiddleware); os documentos de um cliente usam o idioma
+// preferido do contato, quando cadastrado.
+package i18n
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Idiomas suportados
+const (
+	PtBR = "pt-BR"
+	EnUS = "en-US"
+)
+
+// Default é o idioma sem Accept-Language ou com um idioma não suportado
+const Default = PtBR
+
+// Languages lista os idiomas suportados, o padrão primeiro
+var Languages = []string{PtBR, EnUS}
+
+type languageKey struct{}
+
+// Normalize converte a etiqueta informada ("pt", "pt_br", "EN-gb") no idioma suportado
+// correspondente; devolve "" para idiomas não suportados
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, "_", "-")))
+	switch strings.SplitN(tag, "-", 2)[0] {
+	case "pt":
+		return PtBR
+	case "en":
+		return EnUS
+	default:
+		return ""
+	}
+}
+
+// IsSupported indica se a etiqueta corresponde a um idioma suportado
+func IsSupported(tag string) bool {
+	return Normalize(tag) != ""
+}
+
+// Parse escolhe o idioma a partir do header Accept-Language
+// (ex.: "en-US,en;q=0.9,pt-BR;q=0.8"), respeitando os pesos q
+func Parse(acceptLanguage string) string {
+	best, bestQ := Default, -1.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, q := parseLanguageRange(part)
+		lang := Normalize(tag)
+		if lang == "" || q <= 0 {
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+func parseLanguageRange(part string) (string, float64) {
+	fields := strings.Split(strings.TrimSpace(part), ";")
+	q := 1.0
+	for _, param := range fields[1:] {
+		param = strings.TrimSpace(param)
+		if strings.HasPrefix(param, "q=") {
+			if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+				q = value
+			}
+		}
+	}
+	return strings.TrimSpace(fields[0]), q
+}
+
+// WithLanguage associa o idioma ao contexto; etiquetas não suportadas mantêm o contexto
+func WithLanguage(ctx context.Context, tag string) context.Context {
+	lang := Normalize(tag)
+	if lang == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// FromContext retorna o idioma do contexto ou o padrão
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return Default
+	}
+	if lang, ok := ctx.Value(languageKey{}).(string); ok {
+		return lang
+	}
+	return Default
+}
+
+// Preferred escolhe o idioma de um documento: o preferido do destinatário, quando cadastrado,
+// ou o da requisição
+func Preferred(ctx context.Context, preferred string) string {
+	if lang := Normalize(preferred); lang != "" {
+		return lang
+	}
+	return FromContext(ctx)
+}
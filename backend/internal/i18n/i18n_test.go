@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert.Equal(t, PtBR, Parse(""))
+	assert.Equal(t, EnUS, Parse("en-US,en;q=0.9"))
+	assert.Equal(t, PtBR, Parse("pt-BR,pt;q=0.9,en;q=0.8"))
+	assert.Equal(t, EnUS, Parse("fr-FR,en-GB;q=0.5,pt;q=0.3"))
+	assert.Equal(t, PtBR, Parse("de-DE"))
+	assert.Equal(t, PtBR, Parse("en;q=0,pt;q=0.1"))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, PtBR, Normalize("pt"))
+	assert.Equal(t, PtBR, Normalize("pt_br"))
+	assert.Equal(t, EnUS, Normalize(" EN-gb "))
+	assert.Empty(t, Normalize("es"))
+	assert.False(t, IsSupported(""))
+}
+
+func TestContextLanguage(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Default, FromContext(ctx))
+	assert.Equal(t, EnUS, FromContext(WithLanguage(ctx, "en")))
+	assert.Equal(t, Default, FromContext(WithLanguage(ctx, "es")))
+
+	english := WithLanguage(ctx, EnUS)
+	assert.Equal(t, PtBR, Preferred(english, "pt-BR"))
+	assert.Equal(t, EnUS, Preferred(english, ""))
+}
+
+func TestErrorMessage(t *testing.T) {
+	_, ok := ErrorMessage(PtBR, "quotation_not_found")
+	assert.False(t, ok)
+
+	message, ok := ErrorMessage(EnUS, "quotation_not_found")
+	assert.True(t, ok)
+	assert.Equal(t, "Quotation not found", message)
+
+	message, _ = ErrorMessage(EnUS, "invalid_cnpj")
+	assert.Equal(t, "Invalid CNPJ", message)
+	message, _ = ErrorMessage(EnUS, "forbidden")
+	assert.Equal(t, "Access denied: insufficient permissions", message)
+}
+
+func TestLabels(t *testing.T) {
+	assert.Equal(t, "Paga parcialmente", Label(PtBR, InvoiceStatus, "partial"))
+	assert.Equal(t, "Partially paid", Label(EnUS, InvoiceStatus, "partial"))
+	assert.Equal(t, "unknown", Label(EnUS, InvoiceStatus, "unknown"))
+
+	labels := Labels(EnUS)
+	assert.Equal(t, "Credit hold", labels[SalesOrderStatus]["credit_hold"])
+	assert.Equal(t, "Customer", labels[ContactType]["cliente"])
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "Vencimento", Text(PtBR, DocDueDate))
+	assert.Equal(t, "Due date", Text(EnUS, DocDueDate))
+	assert.Equal(t, "01/02/2006", DateLayout(EnUS))
+}
@@ -0,0 +1,263 @@
+package i18n
+
+// Enumerações com rótulos traduzidos (GET /i18n/labels)
+const (
+	QuotationStatus     = "quotation_status"
+	SalesOrderStatus    = "sales_order_status"
+	PurchaseOrderStatus = "purchase_order_status"
+	DeliveryStatus      = "delivery_status"
+	TrackingStatus      = "tracking_status"
+	InvoiceStatus       = "invoice_status"
+	InstallmentStatus   = "installment_status"
+	CreditNoteStatus    = "credit_note_status"
+	SupplierBillStatus  = "supplier_bill_status"
+	BillMatchStatus     = "bill_match_status"
+	ReturnStatus        = "return_status"
+	ServiceOrderStatus  = "service_order_status"
+	ContractStatus      = "contract_status"
+	ExpenseStatus       = "expense_status"
+	TicketStatus        = "ticket_status"
+	ContactType         = "contact_type"
+	PersonType          = "person_type"
+	SalesProcessStatus  = "sales_process_status"
+	TaskStatus          = "task_status"
+)
+
+// label é o rótulo de um valor nos idiomas suportados
+type label struct {
+	pt, en string
+}
+
+var (
+	draft     = label{"Rascunho", "Draft"}
+	sent      = label{"Enviado", "Sent"}
+	confirmed = label{"Confirmado", "Confirmed"}
+	cancelled = label{"Cancelado", "Cancelled"}
+	completed = label{"Concluído", "Completed"}
+	partial   = label{"Parcial", "Partial"}
+	paid      = label{"Pago", "Paid"}
+	delivered = label{"Entregue", "Delivered"}
+	returned  = label{"Devolvido", "Returned"}
+	received  = label{"Recebido", "Received"}
+	open      = label{"Aberto", "Open"}
+)
+
+// enums são os rótulos de cada valor das enumerações, com os valores gravados no banco
+var enums = map[string]map[string]label{
+	QuotationStatus: {
+		"draft":     draft,
+		"sent":      label{"Enviada", "Sent"},
+		"accepted":  label{"Aceita", "Accepted"},
+		"rejected":  label{"Recusada", "Rejected"},
+		"expired":   label{"Expirada", "Expired"},
+		"cancelled": label{"Cancelada", "Cancelled"},
+	},
+	SalesOrderStatus: {
+		"draft":       draft,
+		"confirmed":   confirmed,
+		"processing":  label{"Em andamento", "Processing"},
+		"completed":   completed,
+		"cancelled":   cancelled,
+		"credit_hold": label{"Bloqueado por crédito", "Credit hold"},
+	},
+	PurchaseOrderStatus: {
+		"draft":     draft,
+		"sent":      sent,
+		"confirmed": confirmed,
+		"received":  received,
+		"cancelled": cancelled,
+	},
+	DeliveryStatus: {
+		"pending":   label{"Pendente", "Pending"},
+		"picking":   label{"Em separação", "Picking"},
+		"packed":    label{"Embalada", "Packed"},
+		"shipped":   label{"Despachada", "Shipped"},
+		"delivered": label{"Entregue", "Delivered"},
+		"returned":  label{"Devolvida", "Returned"},
+	},
+	TrackingStatus: {
+		"posted":           label{"Postado", "Posted"},
+		"in_transit":       label{"Em trânsito", "In transit"},
+		"out_for_delivery": label{"Saiu para entrega", "Out for delivery"},
+		"delivered":        delivered,
+		"exception":        label{"Ocorrência", "Exception"},
+		"returned":         returned,
+	},
+	InvoiceStatus: {
+		"draft":     draft,
+		"sent":      label{"Emitida", "Issued"},
+		"partial":   label{"Paga parcialmente", "Partially paid"},
+		"paid":      label{"Paga", "Paid"},
+		"overdue":   label{"Vencida", "Overdue"},
+		"cancelled": label{"Cancelada", "Cancelled"},
+	},
+	InstallmentStatus: {
+		"open":    label{"Em aberto", "Open"},
+		"partial": label{"Paga parcialmente", "Partially paid"},
+		"paid":    label{"Paga", "Paid"},
+		"overdue": label{"Vencida", "Overdue"},
+	},
+	CreditNoteStatus: {
+		"issued":    label{"Emitida", "Issued"},
+		"applied":   label{"Aplicada", "Applied"},
+		"cancelled": label{"Cancelada", "Cancelled"},
+	},
+	SupplierBillStatus: {
+		"draft":     draft,
+		"open":      open,
+		"partial":   partial,
+		"paid":      paid,
+		"cancelled": cancelled,
+	},
+	BillMatchStatus: {
+		"not_required": label{"Dispensada", "Not required"},
+		"pending":      label{"Aguardando conferência", "Pending"},
+		"matched":      label{"Conferida", "Matched"},
+		"discrepancy":  label{"Com divergências", "Discrepancy"},
+		"accepted":     label{"Divergências aceitas", "Accepted"},
+		"rejected":     label{"Recusada", "Rejected"},
+	},
+	SalesProcessStatus: {
+		"draft":       draft,
+		"quotation":   label{"Cotação", "Quotation"},
+		"sales_order": label{"Pedido de venda", "Sales order"},
+		"purchase":    label{"Compra", "Purchase"},
+		"delivery":    label{"Entrega", "Delivery"},
+		"invoicing":   label{"Faturamento", "Invoicing"},
+		"payment":     label{"Pagamento", "Payment"},
+		"completed":   completed,
+		"cancelled":   cancelled,
+	},
+	TaskStatus: {
+		"open":      open,
+		"done":      label{"Concluída", "Done"},
+		"cancelled": label{"Cancelada", "Cancelled"},
+	},
+	ReturnStatus: {
+		"requested": label{"Solicitada", "Requested"},
+		"approved":  label{"Aprovada", "Approved"},
+		"rejected":  label{"Recusada", "Rejected"},
+		"received":  label{"Recebida", "Received"},
+		"credited":  label{"Creditada", "Credited"},
+		"cancelled": label{"Cancelada", "Cancelled"},
+	},
+	ServiceOrderStatus: {
+		"open":        open,
+		"scheduled":   label{"Agendada", "Scheduled"},
+		"in_progress": label{"Em execução", "In progress"},
+		"completed":   label{"Concluída", "Completed"},
+		"billed":      label{"Faturada", "Billed"},
+		"cancelled":   label{"Cancelada", "Cancelled"},
+	},
+	ContractStatus: {
+		"active":    label{"Ativo", "Active"},
+		"cancelled": cancelled,
+	},
+	ExpenseStatus: {
+		"draft":      label{"Rascunho", "Draft"},
+		"submitted":  label{"Enviada", "Submitted"},
+		"approved":   label{"Aprovada", "Approved"},
+		"rejected":   label{"Rejeitada", "Rejected"},
+		"reimbursed": label{"Reembolsada", "Reimbursed"},
+	},
+	TicketStatus: {
+		"open":     open,
+		"answered": label{"Respondido", "Answered"},
+		"closed":   label{"Encerrado", "Closed"},
+	},
+	ContactType: {
+		"cliente":    label{"Cliente", "Customer"},
+		"fornecedor": label{"Fornecedor", "Supplier"},
+		"lead":       label{"Lead", "Lead"},
+	},
+	PersonType: {
+		"pf": label{"Pessoa física", "Individual"},
+		"pj": label{"Pessoa jurídica", "Company"},
+	},
+}
+
+func (l label) in(lang string) string {
+	if Normalize(lang) == EnUS {
+		return l.en
+	}
+	return l.pt
+}
+
+// Label devolve o rótulo do valor da enumeração no idioma; valores desconhecidos voltam como estão
+func Label(lang, enum, value string) string {
+	if l, ok := enums[enum][value]; ok {
+		return l.in(lang)
+	}
+	return value
+}
+
+// Labels devolve os rótulos de todas as enumerações no idioma, por enumeração e valor
+func Labels(lang string) map[string]map[string]string {
+	result := make(map[string]map[string]string, len(enums))
+	for enum, values := range enums {
+		result[enum] = make(map[string]string, len(values))
+		for value, l := range values {
+			result[enum][value] = l.in(lang)
+		}
+	}
+	return result
+}
+
+// Textos fixos dos documentos gerados
+const (
+	DocInvoice          = "doc.invoice"
+	DocIssueDate        = "doc.issue_date"
+	DocDueDate          = "doc.due_date"
+	DocOrder            = "doc.order"
+	DocCustomer         = "doc.customer"
+	DocDocument         = "doc.document"
+	DocProduct          = "doc.product"
+	DocQuantity         = "doc.quantity"
+	DocUnitPrice        = "doc.unit_price"
+	DocSubtotal         = "doc.subtotal"
+	DocDiscounts        = "doc.discounts"
+	DocTaxes            = "doc.taxes"
+	DocTotal            = "doc.total"
+	DocPaid             = "doc.paid"
+	DocBalance          = "doc.balance"
+	DocPaymentsReceived = "doc.payments_received"
+	DocPayWithPix       = "doc.pay_with_pix"
+	DocPixCopyPaste     = "doc.pix_copy_paste"
+)
+
+var texts = map[string]label{
+	DocInvoice:          {"Fatura", "Invoice"},
+	DocIssueDate:        {"Emissão", "Issue date"},
+	DocDueDate:          {"Vencimento", "Due date"},
+	DocOrder:            {"Pedido", "Order"},
+	DocCustomer:         {"Cliente", "Customer"},
+	DocDocument:         {"Documento", "Tax ID"},
+	DocProduct:          {"Produto", "Product"},
+	DocQuantity:         {"Qtd.", "Qty."},
+	DocUnitPrice:        {"Preço unit.", "Unit price"},
+	DocSubtotal:         {"Subtotal", "Subtotal"},
+	DocDiscounts:        {"Descontos", "Discounts"},
+	DocTaxes:            {"Impostos", "Taxes"},
+	DocTotal:            {"Total", "Total"},
+	DocPaid:             {"Pago", "Paid"},
+	DocBalance:          {"Saldo", "Balance"},
+	DocPaymentsReceived: {"Pagamentos recebidos", "Payments received"},
+	DocPayWithPix:       {"Pague com Pix", "Pay with Pix"},
+	DocPixCopyPaste:     {"Pix copia e cola", "Pix copy and paste code"},
+}
+
+// Text devolve o texto fixo do documento no idioma; chaves desconhecidas voltam como estão
+func Text(lang, key string) string {
+	if l, ok := texts[key]; ok {
+		return l.in(lang)
+	}
+	return key
+}
+
+// DateLayout é o formato das datas nos documentos do idioma
+func DateLayout(lang string) string {
+	if Normalize(lang) == EnUS {
+		return "01/02/2006"
+	}
+	return "02/01/2006"
+}
@@ -0,0 +1,68 @@
+package i18n
+
+import "strings"
+
+// englishMessages traz as mensagens em inglês que não saem bem do próprio código do erro; os
+// demais códigos do catálogo (quotation_not_found) viram frase (Quotation not found)
+var englishMessages = map[string]string{
+	"internal_error":           "Internal server error",
+	"invalid_parameter":        "Invalid parameter",
+	"database_unavailable":     "Database connection failed",
+	"transaction_failed":       "Database transaction failed",
+	"invalid_request":          "Invalid request data",
+	"missing_token":            "Token not provided",
+	"invalid_token":            "Invalid or expired token",
+	"forbidden":                "Access denied: insufficient permissions",
+	"invalid_credentials":      "Invalid username or password",
+	"related_records_exist":    "The record has related records and cannot be removed",
+	"over_pick":                "Picked quantity exceeds the ordered quantity",
+	"over_shipment":            "Shipped quantity exceeds the ordered quantity",
+	"nothing_to_invoice":       "There is nothing left to invoice",
+	"nothing_to_deliver":       "There is nothing left to deliver",
+	"nothing_to_bill":          "There is nothing left to bill",
+	"totals_mismatch":          "The informed totals do not match the document items",
+	"batch_empty":              "The batch has no items",
+	"batch_too_large":          "The batch has too many items",
+	"feature_disabled":         "This feature is disabled",
+	"tenant_required":          "Company not informed",
+	"tenant_mismatch":          "The token does not belong to the informed company",
+	"weak_password":            "The password does not meet the minimum requirements",
+	"cannot_change_self":       "You cannot change your own user",
+	"insufficient_scope":       "The API key does not have the required scope",
+	"portal_token_required":    "A customer portal token is required",
+	"portal_token_not_allowed": "Customer portal tokens are not allowed on this route",
+	"credit_limit_exceeded":    "The customer's credit limit was exceeded",
+	"contact_blocked":          "The contact is blocked",
+	"import_too_many_rows":     "The import file has too many rows",
+}
+
+// acronyms são as palavras dos códigos escritas em maiúsculas na mensagem
+var acronyms = map[string]string{
+	"id": "ID", "api": "API", "cep": "CEP", "cnpj": "CNPJ", "dre": "DRE", "etl": "ETL",
+	"nfe": "NF-e", "sku": "SKU", "sla": "SLA", "so": "SO", "po": "PO",
+}
+
+// ErrorMessage traduz a mensagem do erro do catálogo pelo código. Em português vale a mensagem
+// original do erro, devolvida como está (ok = false).
+func ErrorMessage(lang, code string) (string, bool) {
+	if Normalize(lang) != EnUS || code == "" {
+		return "", false
+	}
+	if message, ok := englishMessages[code]; ok {
+		return message, true
+	}
+	return sentence(code), true
+}
+
+// sentence converte o código (invalid_cnpj) em frase (Invalid CNPJ)
+func sentence(code string) string {
+	words := strings.Split(code, "_")
+	for i, word := range words {
+		if acronym, ok := acronyms[word]; ok {
+			words[i] = acronym
+		} else if i == 0 {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,123 @@
+package boleto
+
+import (
+	"ERP-ONSMART/backend/internal/billing"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTitle() Title {
+	return Title{
+		ID:         4321,
+		DocumentNo: "INV-2026-0001/1",
+		IssueDate:  time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		DueDate:    time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Amount:     money.MustParse("1500.5"),
+		Payer: Payer{Name: "José da Conceição", Document: "98765432100", Street: "Rua São João", Number: "100",
+			Neighborhood: "Centro", City: "São Paulo", State: "SP", ZipCode: "01010000"},
+	}
+}
+
+func TestNew(t *testing.T) {
+	account := billing.BoletoConfig{BankCode: "237", Agency: "1234", Wallet: "9", Account: "56789"}
+
+	provider, err := New("", Credentials{}, account)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderCNAB, provider.Name(), "a cobrança por arquivo é o padrão")
+
+	_, err = New(ProviderCNAB, Credentials{}, billing.BoletoConfig{})
+	assert.Equal(t, errors.ErrChargeNotConfigured, err)
+
+	provider, err = New("Inter", Credentials{BaseURL: "https://cdpj.partners.bancointer.com.br"}, account)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderInter, provider.Name())
+
+	_, err = New("itau", Credentials{}, account)
+	assert.Equal(t, errors.ErrUnknownBoletoProvider, err)
+}
+
+func TestCNABRegisterAndInstruct(t *testing.T) {
+	provider := NewCNAB(billing.BoletoConfig{BankCode: "237", Agency: "1234", Wallet: "9", Account: "56789"})
+
+	registration, err := provider.Register(context.Background(), sampleTitle())
+	require.NoError(t, err)
+	assert.True(t, registration.Pending, "o banco recebe o título na remessa")
+	assert.Equal(t, "00000004321", registration.OurNumber)
+	assert.Len(t, registration.Barcode, 44)
+	assert.NotEmpty(t, registration.DigitableLine)
+
+	for _, kind := range InstructionKinds {
+		result, err := provider.Instruct(context.Background(), Instruction{Kind: kind, OurNumber: registration.OurNumber})
+		require.NoError(t, err)
+		assert.True(t, result.Pending)
+		assert.NotEmpty(t, CNABMovement(kind))
+	}
+	_, err = provider.Instruct(context.Background(), Instruction{Kind: "extend"})
+	assert.Equal(t, errors.ErrBoletoInstructionNotSupported, err)
+}
+
+func TestInterRegisterAndCancel(t *testing.T) {
+	tokens := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/v2/token":
+			tokens++
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "id-inter", r.PostForm.Get("client_id"))
+			w.Write([]byte(`{"access_token":"token-inter","token_type":"Bearer","expires_in":3600}`))
+
+		case "/cobranca/v2/boletos":
+			assert.Equal(t, "Bearer token-inter", r.Header.Get("Authorization"))
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "INV-2026-0001/1", body["seuNumero"])
+			assert.Equal(t, 1500.5, body["valorNominal"])
+			assert.Equal(t, "2026-04-01", body["dataVencimento"])
+			assert.Equal(t, "FISICA", body["pagador"].(map[string]interface{})["tipoPessoa"])
+			w.Write([]byte(`{"seuNumero":"INV-2026-0001/1","nossoNumero":"00712345678",
+				"codigoBarras":"07791000000000150501112345678000000071234567",
+				"linhaDigitavel":"07791112384567800000207123456707910000000015050"}`))
+
+		case "/cobranca/v2/boletos/00712345678/cancelar":
+			assert.Equal(t, http.MethodPost, r.Method)
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, interCancelReason, body["motivoCancelamento"])
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title":"Boleto não encontrado"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewInter(Credentials{BaseURL: server.URL, ClientID: "id-inter", ClientSecret: "secret"}, server.Client())
+	registration, err := provider.Register(context.Background(), sampleTitle())
+	require.NoError(t, err)
+	assert.False(t, registration.Pending)
+	assert.Equal(t, "00712345678", registration.OurNumber)
+	assert.Equal(t, "00712345678", registration.ExternalID)
+	assert.Equal(t, "07791.11238 45678.000002 07123.456707 9 10000000015050", registration.DigitableLine)
+
+	_, err = provider.Instruct(context.Background(), Instruction{Kind: InstructionCancel, ExternalID: "00712345678"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, tokens, "o token é reaproveitado até expirar")
+
+	_, err = provider.Instruct(context.Background(), Instruction{Kind: InstructionDiscount, ExternalID: "00712345678"})
+	assert.Equal(t, errors.ErrBoletoInstructionNotSupported, err)
+
+	_, err = provider.Instruct(context.Background(), Instruction{Kind: InstructionCancel, ExternalID: "999"})
+	assert.ErrorIs(t, err, errors.ErrBoletoProviderFailed)
+	assert.ErrorContains(t, err, "status 404")
+}
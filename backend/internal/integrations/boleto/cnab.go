@@ -0,0 +1,63 @@
+package boleto
+
+import (
+	"ERP-ONSMART/backend/internal/billing"
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+)
+
+// CNAB registra os boletos pela cobrança por arquivo: o código de barras é montado no ERP com a
+// conta de cobrança e o título e as instruções chegam ao banco na remessa CNAB 240 seguinte
+type CNAB struct {
+	account billing.BoletoConfig
+}
+
+// NewCNAB cria o provedor da cobrança por arquivo
+func NewCNAB(account billing.BoletoConfig) *CNAB {
+	return &CNAB{account: account}
+}
+
+// Name retorna o identificador do provedor
+func (c *CNAB) Name() string {
+	return ProviderCNAB
+}
+
+// Register monta o boleto com o id da parcela como nosso número; o registro fica pendente até a
+// remessa
+func (c *CNAB) Register(ctx context.Context, title Title) (*Registration, error) {
+	b, err := billing.NewBoleto(c.account, title.ID, title.DueDate, title.Amount)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao gerar boleto da parcela")
+	}
+	return &Registration{
+		OurNumber:     b.OurNumber,
+		Barcode:       b.Barcode,
+		DigitableLine: b.DigitableLine,
+		Pending:       true,
+	}, nil
+}
+
+// Instruct aceita as três instruções, que seguem na próxima remessa como pedido de baixa,
+// concessão de desconto ou protesto
+func (c *CNAB) Instruct(ctx context.Context, instruction Instruction) (*InstructionResult, error) {
+	switch instruction.Kind {
+	case InstructionDiscount, InstructionProtest, InstructionCancel:
+		return &InstructionResult{Pending: true}, nil
+	default:
+		return nil, errors.ErrBoletoInstructionNotSupported
+	}
+}
+
+// CNABMovement é o código de movimento da remessa para a instrução
+func CNABMovement(kind string) string {
+	switch kind {
+	case InstructionDiscount:
+		return billing.CNABMovementDiscount
+	case InstructionProtest:
+		return billing.CNABMovementProtest
+	case InstructionCancel:
+		return billing.CNABMovementWriteOff
+	default:
+		return ""
+	}
+}
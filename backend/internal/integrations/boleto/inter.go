@@ -0,0 +1,252 @@
+package boleto
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// interScopes são os escopos do token da API de cobrança do Banco Inter
+const interScopes = "boleto-cobranca.read boleto-cobranca.write"
+
+// interCancelReason é o motivo do cancelamento pedido pelo beneficiário
+const interCancelReason = "APEDIDODOCLIENTE"
+
+// Inter registra os boletos pela API Cobrança v2 do Banco Inter, autenticada por OAuth
+// (client credentials) sobre mTLS com o certificado da conta. Depois da emissão a API só aceita
+// o cancelamento; desconto e protesto não podem ser alterados.
+type Inter struct {
+	baseURL      string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewInter cria a integração com o Banco Inter
+func NewInter(creds Credentials, client *http.Client) *Inter {
+	return &Inter{
+		baseURL:      strings.TrimRight(creds.BaseURL, "/"),
+		clientID:     creds.ClientID,
+		clientSecret: creds.ClientSecret,
+		client:       client,
+	}
+}
+
+// Name retorna o identificador do provedor
+func (i *Inter) Name() string {
+	return ProviderInter
+}
+
+type interPayer struct {
+	CPFCNPJ     string `json:"cpfCnpj"`
+	TipoPessoa  string `json:"tipoPessoa"`
+	Nome        string `json:"nome"`
+	Endereco    string `json:"endereco"`
+	Numero      string `json:"numero,omitempty"`
+	Complemento string `json:"complemento,omitempty"`
+	Bairro      string `json:"bairro,omitempty"`
+	Cidade      string `json:"cidade"`
+	UF          string `json:"uf"`
+	CEP         string `json:"cep"`
+	Email       string `json:"email,omitempty"`
+}
+
+type interBoletoRequest struct {
+	SeuNumero      string      `json:"seuNumero"`
+	ValorNominal   json.Number `json:"valorNominal"`
+	DataVencimento string      `json:"dataVencimento"`
+	NumDiasAgenda  int         `json:"numDiasAgenda"`
+	Pagador        interPayer  `json:"pagador"`
+}
+
+type interBoletoResponse struct {
+	SeuNumero      string `json:"seuNumero"`
+	NossoNumero    string `json:"nossoNumero"`
+	CodigoBarras   string `json:"codigoBarras"`
+	LinhaDigitavel string `json:"linhaDigitavel"`
+}
+
+// interDaysAfterDue é o prazo, em dias depois do vencimento, em que o boleto ainda pode ser pago
+const interDaysAfterDue = 30
+
+// Register emite o boleto; o Inter devolve o nosso número, o código de barras e a linha digitável
+func (i *Inter) Register(ctx context.Context, title Title) (*Registration, error) {
+	personType := "JURIDICA"
+	if len(title.Payer.Document) <= 11 {
+		personType = "FISICA"
+	}
+	seuNumero := title.DocumentNo
+	if len(seuNumero) > 15 {
+		seuNumero = seuNumero[len(seuNumero)-15:]
+	}
+
+	request := interBoletoRequest{
+		SeuNumero:      seuNumero,
+		ValorNominal:   json.Number(title.Amount.Round(2).StringFixed(2)),
+		DataVencimento: title.DueDate.Format("2006-01-02"),
+		NumDiasAgenda:  interDaysAfterDue,
+		Pagador: interPayer{
+			CPFCNPJ:     title.Payer.Document,
+			TipoPessoa:  personType,
+			Nome:        title.Payer.Name,
+			Endereco:    title.Payer.Street,
+			Numero:      title.Payer.Number,
+			Complemento: title.Payer.Complement,
+			Bairro:      title.Payer.Neighborhood,
+			Cidade:      title.Payer.City,
+			UF:          title.Payer.State,
+			CEP:         title.Payer.ZipCode,
+			Email:       title.Payer.Email,
+		},
+	}
+
+	var response interBoletoResponse
+	if err := i.do(ctx, http.MethodPost, "/cobranca/v2/boletos", request, &response); err != nil {
+		return nil, err
+	}
+	if response.NossoNumero == "" || len(response.CodigoBarras) != 44 {
+		return nil, fmt.Errorf("%w: resposta do Banco Inter sem nosso número ou código de barras", errors.ErrBoletoProviderFailed)
+	}
+
+	return &Registration{
+		OurNumber:     response.NossoNumero,
+		Barcode:       response.CodigoBarras,
+		DigitableLine: formatDigitableLine(response.LinhaDigitavel),
+		ExternalID:    response.NossoNumero,
+	}, nil
+}
+
+// Instruct cancela o boleto; desconto e protesto depois da emissão não são aceitos pela API
+func (i *Inter) Instruct(ctx context.Context, instruction Instruction) (*InstructionResult, error) {
+	if instruction.Kind != InstructionCancel {
+		return nil, errors.ErrBoletoInstructionNotSupported
+	}
+
+	id := instruction.ExternalID
+	if id == "" {
+		id = instruction.OurNumber
+	}
+	payload := map[string]string{"motivoCancelamento": interCancelReason}
+	if err := i.do(ctx, http.MethodPost, "/cobranca/v2/boletos/"+url.PathEscape(id)+"/cancelar", payload, nil); err != nil {
+		return nil, err
+	}
+	return &InstructionResult{}, nil
+}
+
+// accessToken retorna o token vigente ou pede um novo, um minuto antes de expirar
+func (i *Inter) accessToken(ctx context.Context) (string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.token != "" && time.Now().Before(i.expiresAt) {
+		return i.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("client_id", i.clientID)
+	form.Set("client_secret", i.clientSecret)
+	form.Set("grant_type", "client_credentials")
+	form.Set("scope", interScopes)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.baseURL+"/oauth/v2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.WrapError(err, "falha ao montar requisição de token ao Banco Inter")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errors.ErrBoletoProviderFailed, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.WrapError(err, "falha ao ler resposta do Banco Inter")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token do Banco Inter recusado com status %d: %s", errors.ErrBoletoProviderFailed, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("%w: token inválido na resposta do Banco Inter", errors.ErrBoletoProviderFailed)
+	}
+
+	i.token = token.AccessToken
+	i.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return i.token, nil
+}
+
+// do chama a API de cobrança com o token e decodifica a resposta em out, quando informado
+func (i *Inter) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	token, err := i.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return errors.WrapError(err, "falha ao montar requisição ao Banco Inter")
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, i.baseURL+path, body)
+	if err != nil {
+		return errors.WrapError(err, "falha ao montar requisição ao Banco Inter")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrBoletoProviderFailed, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.WrapError(err, "falha ao ler resposta do Banco Inter")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: Banco Inter retornou status %d em %s %s: %s", errors.ErrBoletoProviderFailed, resp.StatusCode, method, path, strings.TrimSpace(string(data)))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return errors.WrapError(err, "resposta inválida do Banco Inter")
+		}
+	}
+	return nil
+}
+
+// formatDigitableLine separa os 47 dígitos da linha digitável nos campos impressos no boleto;
+// outros formatos voltam sem alteração
+func formatDigitableLine(line string) string {
+	if len(line) != 47 || strings.Trim(line, "0123456789") != "" {
+		return line
+	}
+	return fmt.Sprintf("%s.%s %s.%s %s.%s %s %s",
+		line[0:5], line[5:10], line[10:15], line[15:21], line[21:26], line[26:32], line[32:33], line[33:47])
+}
@@ -0,0 +1,134 @@
+// Package boleto registra os boletos das parcelas no banco ou no PSP de cobrança e envia as
+// instruções sobre os títulos já registrados (desconto, protesto e cancelamento). Cada banco é
+// um Provider: a cobrança por arquivo CNAB 240 ou a API de cobrança do Banco Inter.
+package boleto
+
+import (
+	"ERP-ONSMART/backend/internal/billing"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Provedores de registro de boletos
+const (
+	ProviderCNAB  = "cnab"
+	ProviderInter = "inter"
+)
+
+// Tipos de instrução enviados sobre um boleto registrado
+const (
+	InstructionDiscount = "discount"
+	InstructionProtest  = "protest"
+	InstructionCancel   = "cancel"
+)
+
+// InstructionKinds lista as instruções aceitas
+var InstructionKinds = []string{InstructionDiscount, InstructionProtest, InstructionCancel}
+
+// defaultTimeout limita o tempo das chamadas às APIs de cobrança
+const defaultTimeout = 30 * time.Second
+
+// Payer é o pagador do boleto
+type Payer struct {
+	Name         string
+	Document     string // CPF ou CNPJ, só dígitos
+	Email        string
+	Street       string
+	Number       string
+	Complement   string
+	Neighborhood string
+	City         string
+	State        string
+	ZipCode      string
+}
+
+// Title é o título a registrar: uma parcela de fatura com o saldo em aberto
+type Title struct {
+	// ID da parcela; na cobrança por arquivo é também o nosso número
+	ID         int
+	DocumentNo string // seu número, até 15 posições
+	IssueDate  time.Time
+	DueDate    time.Time
+	Amount     money.Decimal
+	Payer      Payer
+}
+
+// Registration é o boleto registrado. Pending indica que o banco só recebe o título na próxima
+// remessa de cobrança.
+type Registration struct {
+	OurNumber     string
+	Barcode       string
+	DigitableLine string
+	// Identificador do título no provedor, quando diferente do nosso número
+	ExternalID string
+	Pending    bool
+}
+
+// Instruction é uma instrução sobre um boleto já registrado
+type Instruction struct {
+	Kind       string
+	OurNumber  string
+	ExternalID string
+	// Desconto de valor fixo para pagamento até DiscountUntil
+	DiscountAmount money.Decimal
+	DiscountUntil  time.Time
+	// Dias corridos depois do vencimento para o protesto
+	ProtestDays int
+	Reason      string
+}
+
+// InstructionResult é a confirmação da instrução. Pending indica que ela segue na próxima
+// remessa de cobrança.
+type InstructionResult struct {
+	Pending bool
+}
+
+// Provider é a integração com o banco ou PSP que registra os boletos
+type Provider interface {
+	Name() string
+	// Register registra o título e devolve o nosso número, o código de barras e a linha digitável
+	Register(ctx context.Context, title Title) (*Registration, error)
+	// Instruct envia a instrução sobre o título; instruções que o provedor não aceita retornam
+	// errors.ErrBoletoInstructionNotSupported
+	Instruct(ctx context.Context, instruction Instruction) (*InstructionResult, error)
+}
+
+// Credentials são os dados de acesso à API de cobrança e o certificado do cliente (mTLS)
+type Credentials struct {
+	BaseURL      string
+	ClientID     string
+	ClientSecret string
+	CertFile     string
+	KeyFile      string
+}
+
+// New retorna o provedor configurado; a cobrança por arquivo usa a conta de cobrança dos boletos
+func New(provider string, creds Credentials, account billing.BoletoConfig) (Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "", ProviderCNAB:
+		if !account.Enabled() {
+			return nil, errors.ErrChargeNotConfigured
+		}
+		return NewCNAB(account), nil
+	case ProviderInter:
+		client := &http.Client{Timeout: defaultTimeout}
+		if creds.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(creds.CertFile, creds.KeyFile)
+			if err != nil {
+				return nil, errors.WrapError(err, "falha ao carregar certificado da API do Banco Inter")
+			}
+			client.Transport = &http.Transport{TLSClientConfig: &tls.Config{
+				Certificates: []tls.Certificate{cert},
+				MinVersion:   tls.VersionTLS12,
+			}}
+		}
+		return NewInter(creds, client), nil
+	default:
+		return nil, errors.ErrUnknownBoletoProvider
+	}
+}
@@ -0,0 +1,141 @@
+// Package ecommerce conecta as lojas virtuais (Shopify, VTEX, WooCommerce) ao ERP: os pedidos
+// da loja são lidos para virarem pedidos de venda, e o estoque, os preços e o rastreamento do
+// ERP são enviados de volta à loja.
+package ecommerce
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Plataformas de e-commerce conhecidas
+const (
+	PlatformShopify     = "shopify"
+	PlatformVTEX        = "vtex"
+	PlatformWooCommerce = "woocommerce"
+)
+
+// Platforms lista as plataformas aceitas no cadastro dos canais
+var Platforms = []string{PlatformShopify, PlatformVTEX, PlatformWooCommerce}
+
+// Address é o endereço de cobrança ou entrega informado no pedido da loja
+type Address struct {
+	ZipCode      string
+	Street       string
+	Number       string
+	Complement   string
+	Neighborhood string
+	City         string
+	State        string
+}
+
+// String formata o endereço em uma linha, como o shipping_address dos pedidos de venda
+func (a Address) String() string {
+	street := a.Street
+	if a.Number != "" {
+		street += ", " + a.Number
+	}
+
+	parts := make([]string, 0, 6)
+	for _, part := range []string{street, a.Complement, a.Neighborhood, a.City, a.State, a.ZipCode} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
+
+// Customer é o comprador do pedido da loja
+type Customer struct {
+	Name     string
+	Company  string
+	Email    string
+	Phone    string
+	Document string // CPF ou CNPJ, quando a loja coleta
+	Address  Address
+}
+
+// OrderItem é uma linha do pedido da loja, identificada pelo SKU do produto
+type OrderItem struct {
+	SKU       string
+	Name      string
+	Quantity  int
+	UnitPrice float64
+	Discount  float64
+}
+
+// Order é um pedido da loja já normalizado
+type Order struct {
+	ExternalID      string
+	Number          string
+	Status          string
+	PlacedAt        time.Time
+	Customer        Customer
+	ShippingAddress Address
+	Items           []OrderItem
+	Notes           string
+}
+
+// StockLevel é o saldo de estoque de um SKU enviado à loja
+type StockLevel struct {
+	SKU      string
+	Quantity int
+}
+
+// PriceUpdate é o preço de venda de um SKU enviado à loja
+type PriceUpdate struct {
+	SKU   string
+	Price money.Decimal
+}
+
+// Shipment é o envio de um pedido da loja, com o código de rastreamento
+type Shipment struct {
+	OrderExternalID string
+	Carrier         string
+	TrackingNumber  string
+	ShippedAt       time.Time
+}
+
+// PushResult resume um envio de estoque ou preços; SKUs que não existem na loja são ignorados
+type PushResult struct {
+	Updated int
+	Missing []string
+}
+
+// Credentials são os dados de acesso à API da loja
+type Credentials struct {
+	BaseURL string
+	Key     string
+	Secret  string
+}
+
+// Connector é a integração com uma plataforma de e-commerce
+type Connector interface {
+	Platform() string
+	// PullOrders retorna os pedidos pagos criados na loja a partir de since
+	PullOrders(ctx context.Context, since time.Time) ([]Order, error)
+	PushStock(ctx context.Context, levels []StockLevel) (*PushResult, error)
+	PushPrices(ctx context.Context, prices []PriceUpdate) (*PushResult, error)
+	PushTracking(ctx context.Context, shipment Shipment) error
+}
+
+// defaultTimeout limita o tempo das chamadas às APIs das lojas
+const defaultTimeout = 30 * time.Second
+
+// New retorna o conector da plataforma com as credenciais do canal
+func New(platform string, creds Credentials) (Connector, error) {
+	client := &http.Client{Timeout: defaultTimeout}
+
+	switch strings.ToLower(strings.TrimSpace(platform)) {
+	case PlatformWooCommerce:
+		return NewWooCommerce(creds, client), nil
+	case PlatformShopify, PlatformVTEX:
+		return nil, errors.ErrPlatformNotSupported
+	default:
+		return nil, errors.ErrUnknownPlatform
+	}
+}
@@ -0,0 +1,310 @@
+package ecommerce
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wooTimeLayout é o formato das datas da API REST do WooCommerce (sem fuso, em *_gmt)
+const wooTimeLayout = "2006-01-02T15:04:05"
+
+// wooPageSize é o máximo de registros por página e de itens por lote aceito pela API
+const wooPageSize = 100
+
+// WooCommerce integra lojas WordPress/WooCommerce pela API REST v3, autenticada com a chave
+// e o segredo do consumidor (HTTP Basic sobre HTTPS)
+type WooCommerce struct {
+	baseURL string
+	key     string
+	secret  string
+	client  *http.Client
+}
+
+// NewWooCommerce cria a integração com a loja WooCommerce
+func NewWooCommerce(creds Credentials, client *http.Client) *WooCommerce {
+	return &WooCommerce{
+		baseURL: strings.TrimRight(creds.BaseURL, "/") + "/wp-json/wc/v3",
+		key:     creds.Key,
+		secret:  creds.Secret,
+		client:  client,
+	}
+}
+
+// Platform retorna o identificador da plataforma
+func (w *WooCommerce) Platform() string {
+	return PlatformWooCommerce
+}
+
+type wooAddress struct {
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Company      string `json:"company"`
+	Address1     string `json:"address_1"`
+	Address2     string `json:"address_2"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	Postcode     string `json:"postcode"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Number       string `json:"number"`       // plugin Brazilian Market
+	Neighborhood string `json:"neighborhood"` // plugin Brazilian Market
+	CPF          string `json:"cpf"`          // plugin Brazilian Market
+	CNPJ         string `json:"cnpj"`         // plugin Brazilian Market
+}
+
+type wooOrder struct {
+	ID             int        `json:"id"`
+	Number         string     `json:"number"`
+	Status         string     `json:"status"`
+	DateCreatedGMT string     `json:"date_created_gmt"`
+	CustomerNote   string     `json:"customer_note"`
+	Billing        wooAddress `json:"billing"`
+	Shipping       wooAddress `json:"shipping"`
+	LineItems      []struct {
+		Name     string      `json:"name"`
+		SKU      string      `json:"sku"`
+		Quantity int         `json:"quantity"`
+		Price    json.Number `json:"price"`
+		Subtotal string      `json:"subtotal"`
+		Total    string      `json:"total"`
+	} `json:"line_items"`
+}
+
+type wooProduct struct {
+	ID  int    `json:"id"`
+	SKU string `json:"sku"`
+}
+
+// PullOrders lê os pedidos pagos (processing e completed) criados a partir de since
+func (w *WooCommerce) PullOrders(ctx context.Context, since time.Time) ([]Order, error) {
+	query := url.Values{}
+	query.Set("status", "processing,completed")
+	query.Set("after", since.UTC().Format(wooTimeLayout))
+	query.Set("dates_are_gmt", "true")
+	query.Set("orderby", "date")
+	query.Set("order", "asc")
+	query.Set("per_page", strconv.Itoa(wooPageSize))
+
+	orders := make([]Order, 0)
+	for page, totalPages := 1, 1; page <= totalPages; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var parsed []wooOrder
+		header, err := w.do(ctx, http.MethodGet, "/orders", query, nil, &parsed)
+		if err != nil {
+			return nil, err
+		}
+		if total, err := strconv.Atoi(header.Get("X-WP-TotalPages")); err == nil {
+			totalPages = total
+		}
+
+		for _, order := range parsed {
+			normalized, err := order.normalize()
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, normalized)
+		}
+	}
+	return orders, nil
+}
+
+// PushStock atualiza o estoque gerenciado dos produtos da loja pelo SKU
+func (w *WooCommerce) PushStock(ctx context.Context, levels []StockLevel) (*PushResult, error) {
+	quantities := make(map[string]int, len(levels))
+	for _, level := range levels {
+		quantities[level.SKU] = max(level.Quantity, 0)
+	}
+	return updateProducts(ctx, w, quantities, func(id int, quantity int) map[string]interface{} {
+		return map[string]interface{}{"id": id, "manage_stock": true, "stock_quantity": quantity}
+	})
+}
+
+// PushPrices atualiza o preço regular dos produtos da loja pelo SKU
+func (w *WooCommerce) PushPrices(ctx context.Context, prices []PriceUpdate) (*PushResult, error) {
+	values := make(map[string]money.Decimal, len(prices))
+	for _, price := range prices {
+		values[price.SKU] = price.Price
+	}
+	return updateProducts(ctx, w, values, func(id int, price money.Decimal) map[string]interface{} {
+		return map[string]interface{}{"id": id, "regular_price": price.StringFixed(2)}
+	})
+}
+
+// PushTracking conclui o pedido na loja e registra o rastreamento em uma nota visível ao cliente
+func (w *WooCommerce) PushTracking(ctx context.Context, shipment Shipment) error {
+	path := "/orders/" + url.PathEscape(shipment.OrderExternalID)
+	if _, err := w.do(ctx, http.MethodPut, path, nil, map[string]string{"status": "completed"}, nil); err != nil {
+		return err
+	}
+
+	note := fmt.Sprintf("Pedido enviado. Código de rastreamento: %s", shipment.TrackingNumber)
+	if shipment.Carrier != "" {
+		note = fmt.Sprintf("Pedido enviado por %s. Código de rastreamento: %s", shipment.Carrier, shipment.TrackingNumber)
+	}
+	_, err := w.do(ctx, http.MethodPost, path+"/notes", nil, map[string]interface{}{"note": note, "customer_note": true}, nil)
+	return err
+}
+
+// updateProducts localiza os produtos da loja pelos SKUs e envia as alterações em lotes
+func updateProducts[V any](ctx context.Context, w *WooCommerce, values map[string]V, change func(id int, value V) map[string]interface{}) (*PushResult, error) {
+	skus := make([]string, 0, len(values))
+	for sku := range values {
+		skus = append(skus, sku)
+	}
+	sort.Strings(skus)
+
+	result := &PushResult{Missing: make([]string, 0)}
+	for start := 0; start < len(skus); start += wooPageSize {
+		chunk := skus[start:min(start+wooPageSize, len(skus))]
+
+		query := url.Values{}
+		query.Set("sku", strings.Join(chunk, ","))
+		query.Set("per_page", strconv.Itoa(wooPageSize))
+		var products []wooProduct
+		if _, err := w.do(ctx, http.MethodGet, "/products", query, nil, &products); err != nil {
+			return nil, err
+		}
+
+		found := make(map[string]bool, len(products))
+		updates := make([]map[string]interface{}, 0, len(products))
+		for _, product := range products {
+			if value, ok := values[product.SKU]; ok && !found[product.SKU] {
+				found[product.SKU] = true
+				updates = append(updates, change(product.ID, value))
+			}
+		}
+		for _, sku := range chunk {
+			if !found[sku] {
+				result.Missing = append(result.Missing, sku)
+			}
+		}
+
+		if len(updates) > 0 {
+			if _, err := w.do(ctx, http.MethodPost, "/products/batch", nil, map[string]interface{}{"update": updates}, nil); err != nil {
+				return nil, err
+			}
+			result.Updated += len(updates)
+		}
+	}
+	return result, nil
+}
+
+// do executa a chamada à API e decodifica a resposta em out (quando informado)
+func (w *WooCommerce) do(ctx context.Context, method, path string, query url.Values, payload, out interface{}) (http.Header, error) {
+	endpoint := w.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, errors.WrapError(err, "falha ao montar requisição ao WooCommerce")
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao montar requisição ao WooCommerce")
+	}
+	req.SetBasicAuth(w.key, w.secret)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao chamar a API do WooCommerce")
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao ler resposta do WooCommerce")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("woocommerce retornou status %d em %s %s: %s", resp.StatusCode, method, path, strings.TrimSpace(string(data)))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, errors.WrapError(err, "resposta inválida do WooCommerce")
+		}
+	}
+	return resp.Header, nil
+}
+
+// normalize converte o pedido do WooCommerce para o formato comum dos conectores
+func (o wooOrder) normalize() (Order, error) {
+	placedAt, err := time.Parse(wooTimeLayout, o.DateCreatedGMT)
+	if err != nil {
+		return Order{}, errors.WrapError(err, "data de pedido inválida na resposta do WooCommerce")
+	}
+
+	document := o.Billing.CPF
+	if document == "" {
+		document = o.Billing.CNPJ
+	}
+
+	order := Order{
+		ExternalID: strconv.Itoa(o.ID),
+		Number:     o.Number,
+		Status:     o.Status,
+		PlacedAt:   placedAt,
+		Notes:      o.CustomerNote,
+		Customer: Customer{
+			Name:     strings.TrimSpace(o.Billing.FirstName + " " + o.Billing.LastName),
+			Company:  o.Billing.Company,
+			Email:    o.Billing.Email,
+			Phone:    o.Billing.Phone,
+			Document: document,
+			Address:  o.Billing.address(),
+		},
+		ShippingAddress: o.Shipping.address(),
+		Items:           make([]OrderItem, 0, len(o.LineItems)),
+	}
+	if order.ShippingAddress == (Address{}) {
+		order.ShippingAddress = order.Customer.Address
+	}
+
+	for _, line := range o.LineItems {
+		price, _ := line.Price.Float64()
+		subtotal, _ := strconv.ParseFloat(line.Subtotal, 64)
+		total, _ := strconv.ParseFloat(line.Total, 64)
+		order.Items = append(order.Items, OrderItem{
+			SKU:       line.SKU,
+			Name:      line.Name,
+			Quantity:  line.Quantity,
+			UnitPrice: price,
+			Discount:  max(subtotal-total, 0),
+		})
+	}
+	return order, nil
+}
+
+func (a wooAddress) address() Address {
+	return Address{
+		ZipCode:      a.Postcode,
+		Street:       a.Address1,
+		Number:       a.Number,
+		Complement:   a.Address2,
+		Neighborhood: a.Neighborhood,
+		City:         a.City,
+		State:        a.State,
+	}
+}
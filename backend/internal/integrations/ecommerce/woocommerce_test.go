@@ -0,0 +1,140 @@
+package ecommerce
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUnknownAndUnsupportedPlatforms(t *testing.T) {
+	_, err := New("magento", Credentials{})
+	assert.Equal(t, errors.ErrUnknownPlatform, err)
+
+	_, err = New(PlatformShopify, Credentials{})
+	assert.Equal(t, errors.ErrPlatformNotSupported, err)
+
+	connector, err := New(" WooCommerce ", Credentials{BaseURL: "https://loja.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, PlatformWooCommerce, connector.Platform())
+}
+
+func TestWooCommercePullOrders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/wp-json/wc/v3/orders", r.URL.Path)
+		key, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ck_123", key)
+		assert.Equal(t, "cs_456", secret)
+		assert.Equal(t, "2026-05-01T12:00:00", r.URL.Query().Get("after"))
+		assert.Equal(t, "processing,completed", r.URL.Query().Get("status"))
+
+		w.Header().Set("X-WP-TotalPages", "2")
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{
+			"id": 727, "number": "727", "status": "processing", "date_created_gmt": "2026-05-02T09:30:00",
+			"customer_note": "Entregar à tarde",
+			"billing": {"first_name": "Maria", "last_name": "Silva", "email": "maria@example.com", "phone": "11999990000",
+				"cpf": "123.456.789-09", "address_1": "Rua A", "number": "10", "neighborhood": "Centro",
+				"city": "Campinas", "state": "SP", "postcode": "13000-000"},
+			"shipping": {"address_1": "", "city": ""},
+			"line_items": [
+				{"name": "Notebook", "sku": "NB-01", "quantity": 2, "price": 1500, "subtotal": "3000.00", "total": "2900.00"},
+				{"name": "Mouse", "sku": "MS-02", "quantity": 1, "price": 19.9, "subtotal": "19.90", "total": "19.90"}
+			]
+		}]`))
+	}))
+	defer server.Close()
+
+	woo := NewWooCommerce(Credentials{BaseURL: server.URL + "/", Key: "ck_123", Secret: "cs_456"}, server.Client())
+	orders, err := woo.PullOrders(context.Background(), time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+
+	order := orders[0]
+	assert.Equal(t, "727", order.ExternalID)
+	assert.Equal(t, time.Date(2026, 5, 2, 9, 30, 0, 0, time.UTC), order.PlacedAt)
+	assert.Equal(t, "Maria Silva", order.Customer.Name)
+	assert.Equal(t, "123.456.789-09", order.Customer.Document)
+	assert.Equal(t, "Rua A, 10 - Centro - Campinas - SP - 13000-000", order.ShippingAddress.String(), "sem endereço de entrega, usa o de cobrança")
+	require.Len(t, order.Items, 2)
+	assert.Equal(t, OrderItem{SKU: "NB-01", Name: "Notebook", Quantity: 2, UnitPrice: 1500, Discount: 100}, order.Items[0])
+	assert.Equal(t, 0.0, order.Items[1].Discount)
+}
+
+func TestWooCommercePushStockReportsMissingSKUs(t *testing.T) {
+	var batch struct {
+		Update []map[string]interface{} `json:"update"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/products":
+			assert.Equal(t, "MS-02,NB-01,XX-99", r.URL.Query().Get("sku"))
+			w.Write([]byte(`[{"id": 93, "sku": "NB-01"}, {"id": 94, "sku": "MS-02"}]`))
+		case "/wp-json/wc/v3/products/batch":
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &batch))
+			w.Write([]byte(`{"update": []}`))
+		default:
+			t.Fatalf("rota inesperada %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	woo := NewWooCommerce(Credentials{BaseURL: server.URL}, server.Client())
+	result, err := woo.PushStock(context.Background(), []StockLevel{
+		{SKU: "NB-01", Quantity: 5}, {SKU: "MS-02", Quantity: -1}, {SKU: "XX-99", Quantity: 3},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, []string{"XX-99"}, result.Missing)
+	require.Len(t, batch.Update, 2)
+	assert.Equal(t, map[string]interface{}{"id": 93.0, "manage_stock": true, "stock_quantity": 5.0}, batch.Update[0])
+	assert.Equal(t, 0.0, batch.Update[1]["stock_quantity"], "estoque negativo é enviado como zero")
+}
+
+func TestWooCommercePushTracking(t *testing.T) {
+	var calls []string
+	var note map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/wp-json/wc/v3/orders/727/notes" {
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &note))
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	woo := NewWooCommerce(Credentials{BaseURL: server.URL}, server.Client())
+	err := woo.PushTracking(context.Background(), Shipment{OrderExternalID: "727", Carrier: "correios", TrackingNumber: "AA123456789BR"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"PUT /wp-json/wc/v3/orders/727", "POST /wp-json/wc/v3/orders/727/notes"}, calls)
+	assert.Equal(t, "Pedido enviado por correios. Código de rastreamento: AA123456789BR", note["note"])
+	assert.Equal(t, true, note["customer_note"])
+}
+
+func TestWooCommerceHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code": "woocommerce_rest_cannot_view"}`))
+	}))
+	defer server.Close()
+
+	woo := NewWooCommerce(Credentials{BaseURL: server.URL}, server.Client())
+	_, err := woo.PullOrders(context.Background(), time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+}
@@ -0,0 +1,69 @@
+package exchange
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"encoding/xml"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ECB consulta as taxas de referência do Banco Central Europeu (arquivo dos últimos 90 dias), em
+// unidades da moeda por euro. A cotação em reais é a taxa do real dividida pela taxa da moeda.
+type ECB struct {
+	url    string
+	client *http.Client
+}
+
+// NewECB cria a integração com as taxas de referência do BCE
+func NewECB(url string, client *http.Client) *ECB {
+	return &ECB{url: url, client: client}
+}
+
+// Name retorna o identificador do provedor
+func (e *ECB) Name() string {
+	return ProviderECB
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Rates converte para reais as taxas dos dias do período em que o BCE publicou a moeda e o real
+func (e *ECB) Rates(ctx context.Context, currency string, from, to time.Time) ([]Rate, error) {
+	body, err := get(ctx, e.client, e.url, "application/xml")
+	if err != nil {
+		return nil, err
+	}
+	var envelope ecbEnvelope
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		return nil, errors.WrapError(err, "resposta inválida")
+	}
+
+	var rates []Rate
+	for _, cube := range envelope.Days {
+		date, err := time.Parse(DateLayout, cube.Time)
+		if err != nil || !inRange(date, from, to) {
+			continue
+		}
+		perEuro := map[string]float64{"EUR": 1}
+		for _, rate := range cube.Rates {
+			perEuro[rate.Currency] = rate.Rate
+		}
+		brl, target := perEuro["BRL"], perEuro[currency]
+		if brl <= 0 || target <= 0 {
+			continue
+		}
+		rates = append(rates, Rate{Currency: currency, Date: date, Rate: math.Round(brl/target*1e8) / 1e8, Source: ProviderECB})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Date.Before(rates[j].Date) })
+	return rates, nil
+}
@@ -0,0 +1,127 @@
+// Package exchange consulta as cotações diárias das moedas estrangeiras em reais: a PTAX de venda
+// do Banco Central (API Olinda) e as taxas de referência do Banco Central Europeu, convertidas
+// para o real pela cotação do euro.
+package exchange
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Provedores de cotações
+const (
+	ProviderPTAX   = "ptax"
+	ProviderECB    = "ecb"
+	ProviderManual = "manual"
+)
+
+// DateLayout é o formato das datas das cotações
+const DateLayout = "2006-01-02"
+
+// defaultTimeout limita o tempo das consultas às cotações
+const defaultTimeout = 15 * time.Second
+
+// Rate é a cotação da moeda no dia, em reais por unidade da moeda
+type Rate struct {
+	Currency string
+	Date     time.Time
+	Rate     float64
+	Source   string
+}
+
+// Provider consulta as cotações diárias da moeda entre from e to (inclusive). Dias sem cotação
+// publicada (fins de semana, feriados) ficam fora do resultado.
+type Provider interface {
+	Name() string
+	Rates(ctx context.Context, currency string, from, to time.Time) ([]Rate, error)
+}
+
+// Chain consulta os provedores em ordem até um trazer cotações; falhas e respostas vazias passam
+// ao próximo provedor
+type Chain []Provider
+
+// Name identifica a cadeia de provedores
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, provider := range c {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Rates consulta os provedores em ordem. Sem cotações em nenhum, o resultado é vazio se algum
+// respondeu e ErrExchangeRateUnavailable se todos falharam.
+func (c Chain) Rates(ctx context.Context, currency string, from, to time.Time) ([]Rate, error) {
+	var failures []string
+	for _, provider := range c {
+		rates, err := provider.Rates(ctx, currency, from, to)
+		if err != nil {
+			failures = append(failures, provider.Name()+": "+err.Error())
+			continue
+		}
+		if len(rates) > 0 {
+			return rates, nil
+		}
+	}
+	if len(c) > 0 && len(failures) == len(c) {
+		return nil, fmt.Errorf("%w: %s", errors.ErrExchangeRateUnavailable, strings.Join(failures, "; "))
+	}
+	return nil, nil
+}
+
+// FromConfig monta os provedores configurados em EXCHANGE_RATE_PROVIDERS
+func FromConfig() Provider {
+	client := &http.Client{Timeout: defaultTimeout}
+
+	var chain Chain
+	for _, name := range strings.Split(viper.GetString("EXCHANGE_RATE_PROVIDERS"), ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case ProviderPTAX:
+			chain = append(chain, NewPTAX(viper.GetString("PTAX_URL"), client))
+		case ProviderECB:
+			chain = append(chain, NewECB(viper.GetString("ECB_RATES_URL"), client))
+		}
+	}
+	return chain
+}
+
+// day trunca o instante para o dia, em UTC
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// inRange indica se o dia está entre from e to, inclusive
+func inRange(date, from, to time.Time) bool {
+	return !date.Before(day(from)) && !date.After(day(to))
+}
+
+// get faz a consulta e devolve o corpo da resposta de sucesso
+func get(ctx context.Context, client *http.Client, endpoint, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao montar consulta")
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha na consulta")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao ler resposta")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,118 @@
+package exchange
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(value string) time.Time {
+	d, _ := time.Parse(DateLayout, value)
+	return d
+}
+
+func TestPTAXRates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/CotacaoMoedaPeriodo(")
+		assert.Equal(t, "'USD'", r.URL.Query().Get("@moeda"))
+		assert.Equal(t, "'10-14-2026'", r.URL.Query().Get("@dataInicial"))
+		assert.Equal(t, "'10-15-2026'", r.URL.Query().Get("@dataFinalCotacao"))
+		w.Write([]byte(`{"value":[
+			{"cotacaoCompra":5.4301,"cotacaoVenda":5.4307,"dataHoraCotacao":"2026-10-14 10:08:21.337","tipoBoletim":"Abertura"},
+			{"cotacaoCompra":5.4402,"cotacaoVenda":5.4408,"dataHoraCotacao":"2026-10-14 13:09:27.112","tipoBoletim":"Fechamento PTAX"},
+			{"cotacaoCompra":5.4501,"cotacaoVenda":5.4507,"dataHoraCotacao":"2026-10-15 11:03:20.504","tipoBoletim":"Intermediário"}
+		]}`))
+	}))
+	defer server.Close()
+
+	rates, err := NewPTAX(server.URL, server.Client()).Rates(context.Background(), "USD", date("2026-10-14"), date("2026-10-15"))
+	require.NoError(t, err)
+	// Só o boletim de fechamento vale como PTAX do dia
+	require.Len(t, rates, 1)
+	assert.Equal(t, date("2026-10-14"), rates[0].Date)
+	assert.Equal(t, 5.4408, rates[0].Rate)
+	assert.Equal(t, ProviderPTAX, rates[0].Source)
+}
+
+func TestECBRates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-10-15">
+			<Cube currency="USD" rate="1.0800"/>
+			<Cube currency="BRL" rate="5.8860"/>
+		</Cube>
+		<Cube time="2026-10-14">
+			<Cube currency="USD" rate="1.1000"/>
+			<Cube currency="BRL" rate="5.9950"/>
+		</Cube>
+		<Cube time="2026-10-01">
+			<Cube currency="USD" rate="1.1000"/>
+			<Cube currency="BRL" rate="6.0000"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`))
+	}))
+	defer server.Close()
+
+	provider := NewECB(server.URL, server.Client())
+	rates, err := provider.Rates(context.Background(), "USD", date("2026-10-14"), date("2026-10-15"))
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, date("2026-10-14"), rates[0].Date)
+	assert.Equal(t, 5.45, rates[0].Rate)
+	assert.Equal(t, 5.45, rates[1].Rate)
+
+	euro, err := provider.Rates(context.Background(), "EUR", date("2026-10-15"), date("2026-10-15"))
+	require.NoError(t, err)
+	require.Len(t, euro, 1)
+	assert.Equal(t, 5.886, euro[0].Rate)
+
+	// Moeda não publicada pelo BCE
+	none, err := provider.Rates(context.Background(), "ARS", date("2026-10-14"), date("2026-10-15"))
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+type stubProvider struct {
+	name  string
+	rates []Rate
+	err   error
+}
+
+func (s stubProvider) Name() string { return s.name }
+
+func (s stubProvider) Rates(ctx context.Context, currency string, from, to time.Time) ([]Rate, error) {
+	return s.rates, s.err
+}
+
+func TestChainFallsBackToNextProvider(t *testing.T) {
+	ecbRate := Rate{Currency: "USD", Date: date("2026-10-15"), Rate: 5.45, Source: ProviderECB}
+	chain := Chain{
+		stubProvider{name: ProviderPTAX, err: stderrors.New("status 503")},
+		stubProvider{name: ProviderECB, rates: []Rate{ecbRate}},
+	}
+	rates, err := chain.Rates(context.Background(), "USD", date("2026-10-15"), date("2026-10-15"))
+	require.NoError(t, err)
+	assert.Equal(t, []Rate{ecbRate}, rates)
+	assert.Equal(t, "ptax,ecb", chain.Name())
+
+	// Fim de semana: o provedor responde sem cotações, sem erro
+	empty := Chain{stubProvider{name: ProviderPTAX}, stubProvider{name: ProviderECB, err: stderrors.New("timeout")}}
+	rates, err = empty.Rates(context.Background(), "USD", date("2026-10-17"), date("2026-10-18"))
+	require.NoError(t, err)
+	assert.Empty(t, rates)
+
+	failing := Chain{stubProvider{name: ProviderPTAX, err: stderrors.New("status 503")}}
+	_, err = failing.Rates(context.Background(), "USD", date("2026-10-15"), date("2026-10-15"))
+	assert.True(t, stderrors.Is(err, errors.ErrExchangeRateUnavailable))
+}
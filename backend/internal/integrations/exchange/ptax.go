@@ -0,0 +1,73 @@
+package exchange
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ptaxDateLayout é o formato das datas nos parâmetros da API Olinda (MM-DD-AAAA)
+const ptaxDateLayout = "01-02-2006"
+
+// PTAX consulta a cotação PTAX de venda do Banco Central pela API Olinda. Só o boletim de
+// fechamento é a PTAX do dia; os boletins intermediários são ignorados.
+type PTAX struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewPTAX cria a integração com a API Olinda do Banco Central
+func NewPTAX(baseURL string, client *http.Client) *PTAX {
+	return &PTAX{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+// Name retorna o identificador do provedor
+func (p *PTAX) Name() string {
+	return ProviderPTAX
+}
+
+type ptaxResponse struct {
+	Value []struct {
+		CotacaoVenda    float64 `json:"cotacaoVenda"`
+		DataHoraCotacao string  `json:"dataHoraCotacao"`
+		TipoBoletim     string  `json:"tipoBoletim"`
+	} `json:"value"`
+}
+
+// Rates consulta os boletins de fechamento da moeda no período
+func (p *PTAX) Rates(ctx context.Context, currency string, from, to time.Time) ([]Rate, error) {
+	quote := func(value string) string { return url.QueryEscape("'" + value + "'") }
+	endpoint := fmt.Sprintf("%s/CotacaoMoedaPeriodo(moeda=@moeda,dataInicial=@dataInicial,dataFinalCotacao=@dataFinalCotacao)"+
+		"?@moeda=%s&@dataInicial=%s&@dataFinalCotacao=%s&$format=json",
+		p.baseURL, quote(currency), quote(from.Format(ptaxDateLayout)), quote(to.Format(ptaxDateLayout)))
+
+	body, err := get(ctx, p.client, endpoint, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	var resp ptaxResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.WrapError(err, "resposta inválida")
+	}
+
+	rates := make([]Rate, 0, len(resp.Value))
+	for _, quote := range resp.Value {
+		if !strings.HasPrefix(strings.ToLower(quote.TipoBoletim), "fechamento") || quote.CotacaoVenda <= 0 {
+			continue
+		}
+		if len(quote.DataHoraCotacao) < len(DateLayout) {
+			continue
+		}
+		date, err := time.Parse(DateLayout, quote.DataHoraCotacao[:len(DateLayout)])
+		if err != nil || !inRange(date, from, to) {
+			continue
+		}
+		rates = append(rates, Rate{Currency: currency, Date: date, Rate: quote.CotacaoVenda, Source: ProviderPTAX})
+	}
+	return rates, nil
+}
@@ -0,0 +1,284 @@
+package ftp
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cliente mínimo do FTP (RFC 959) em modo passivo, com EPSV (RFC 2428) e MLSD (RFC 3659)
+// quando o servidor os oferece. O FTP trafega a senha e os arquivos sem criptografia: use SFTP
+// sempre que o parceiro permitir e FTP só em redes privadas ou VPN.
+
+// DefaultPort é a porta do FTP quando o servidor não informa outra
+const DefaultPort = 21
+
+// defaultTimeout limita a conexão e as transferências quando o contexto não tem prazo
+const defaultTimeout = 30 * time.Second
+
+// maxFileSize limita os arquivos lidos com Get
+const maxFileSize = 32 * 1024 * 1024
+
+// Config são os dados de conexão ao servidor FTP
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Timeout  time.Duration
+}
+
+// Client é uma sessão FTP autenticada; deve ser fechada com Close
+type Client struct {
+	conn     net.Conn
+	text     *textproto.Conn
+	host     string
+	timeout  time.Duration
+	deadline time.Time
+	// noEPSV registra que o servidor recusou o EPSV, para usar o PASV direto
+	noEPSV bool
+}
+
+var (
+	epsvPattern = regexp.MustCompile(`\(\|\|\|(\d+)\|\)`)
+	pasvPattern = regexp.MustCompile(`(\d+),(\d+),(\d+),(\d+),(\d+),(\d+)`)
+)
+
+// Dial conecta e autentica no servidor, em modo binário. O prazo do contexto (ou Timeout,
+// quando o contexto não tem prazo) vale para a sessão inteira.
+func Dial(ctx context.Context, cfg Config) (*Client, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao conectar a %s: %w", addr, err)
+	}
+	conn.SetDeadline(deadline)
+
+	c := &Client{conn: conn, text: textproto.NewConn(conn), host: cfg.Host, timeout: timeout, deadline: deadline}
+	if err := c.login(cfg.User, cfg.Password); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) login(user, password string) error {
+	if _, _, err := c.text.ReadResponse(220); err != nil {
+		return fmt.Errorf("resposta inesperada do servidor FTP: %w", err)
+	}
+	code, _, err := c.cmd(0, "USER %s", user)
+	if err != nil {
+		return err
+	}
+	if code == 331 {
+		if _, _, err := c.cmd(230, "PASS %s", password); err != nil {
+			return fmt.Errorf("falha na autenticação FTP: %w", err)
+		}
+	} else if code != 230 {
+		return fmt.Errorf("falha na autenticação FTP: código %d", code)
+	}
+	if _, _, err := c.cmd(200, "TYPE I"); err != nil {
+		return fmt.Errorf("o servidor FTP não aceitou o modo binário: %w", err)
+	}
+	return nil
+}
+
+// Close encerra a sessão
+func (c *Client) Close() error {
+	c.cmd(221, "QUIT")
+	return c.conn.Close()
+}
+
+// Put grava data em remotePath. O conteúdo é escrito em remotePath.part e renomeado ao fim,
+// para o parceiro nunca ler um arquivo pela metade; um arquivo anterior com o mesmo nome é
+// substituído.
+func (c *Client) Put(remotePath string, data []byte) error {
+	partial := remotePath + ".part"
+	err := c.transfer("STOR "+partial, func(conn net.Conn) error {
+		_, err := conn.Write(data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao gravar %s: %w", partial, err)
+	}
+
+	// Alguns servidores não renomeiam sobre um arquivo existente: o envio anterior é removido
+	// antes; a falha da remoção (em geral, arquivo inexistente) aparece no rename, se for o caso
+	c.cmd(250, "DELE %s", remotePath)
+	return c.Rename(partial, remotePath)
+}
+
+// Get lê o arquivo inteiro, até o limite de maxFileSize
+func (c *Client) Get(remotePath string) ([]byte, error) {
+	var data []byte
+	err := c.transfer("RETR "+remotePath, func(conn net.Conn) error {
+		var err error
+		data, err = io.ReadAll(io.LimitReader(conn, maxFileSize+1))
+		if err == nil && len(data) > maxFileSize {
+			return fmt.Errorf("%s excede o limite de %d MiB", remotePath, maxFileSize>>20)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler %s: %w", remotePath, err)
+	}
+	return data, nil
+}
+
+// List retorna os nomes dos arquivos comuns do diretório, em ordem alfabética. Com MLSD os
+// subdiretórios ficam de fora; servidores sem MLSD são listados com NLST, que não distingue
+// arquivos de diretórios.
+func (c *Client) List(dir string) ([]string, error) {
+	var listing []byte
+	read := func(conn net.Conn) error {
+		var err error
+		listing, err = io.ReadAll(io.LimitReader(conn, maxFileSize))
+		return err
+	}
+
+	mlsd := true
+	err := c.transfer("MLSD "+dir, read)
+	var protoErr *textproto.Error
+	if stderrors.As(err, &protoErr) && (protoErr.Code == 500 || protoErr.Code == 502) {
+		mlsd = false
+		err = c.transfer("NLST "+dir, read)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(string(listing), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		if !mlsd {
+			names = append(names, path.Base(line))
+			continue
+		}
+		facts, name, ok := strings.Cut(line, " ")
+		if !ok || !strings.Contains(strings.ToLower(facts), "type=file;") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Rename move o arquivo
+func (c *Client) Rename(from, to string) error {
+	if _, _, err := c.cmd(350, "RNFR %s", from); err != nil {
+		return fmt.Errorf("falha ao renomear %s: %w", from, err)
+	}
+	if _, _, err := c.cmd(250, "RNTO %s", to); err != nil {
+		return fmt.Errorf("falha ao renomear %s: %w", from, err)
+	}
+	return nil
+}
+
+// Remove apaga o arquivo
+func (c *Client) Remove(remotePath string) error {
+	if _, _, err := c.cmd(250, "DELE %s", remotePath); err != nil {
+		return fmt.Errorf("falha ao apagar %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// cmd envia o comando e lê a resposta; expect 0 aceita qualquer código
+func (c *Client) cmd(expect int, format string, args ...any) (int, string, error) {
+	line := fmt.Sprintf(format, args...)
+	// Uma quebra de linha no caminho viraria um segundo comando
+	if strings.ContainsAny(line, "\r\n") {
+		return 0, "", fmt.Errorf("comando FTP com quebra de linha recusado")
+	}
+	if err := c.text.PrintfLine("%s", line); err != nil {
+		return 0, "", err
+	}
+	return c.text.ReadResponse(expect)
+}
+
+// transfer abre a conexão de dados passiva, envia o comando e entrega a conexão a fn
+func (c *Client) transfer(command string, fn func(conn net.Conn) error) error {
+	dataConn, err := c.openData()
+	if err != nil {
+		return err
+	}
+	defer dataConn.Close()
+
+	if _, _, err := c.cmd(1, "%s", command); err != nil {
+		return err
+	}
+	// O fim da transferência é sinalizado pelo fechamento da conexão de dados; a resposta final
+	// é lida mesmo quando fn falha, para a sessão continuar utilizável
+	fnErr := fn(dataConn)
+	dataConn.Close()
+	_, _, err = c.text.ReadResponse(2)
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// openData pede a porta passiva ao servidor e conecta nela. O endereço devolvido pelo PASV é
+// ignorado: a conexão vai sempre ao host do controle, o que evita o ataque de bounce e funciona
+// com servidores atrás de NAT.
+func (c *Client) openData() (net.Conn, error) {
+	var port int
+	if !c.noEPSV {
+		code, message, err := c.cmd(0, "EPSV")
+		if err != nil {
+			return nil, err
+		}
+		if match := epsvPattern.FindStringSubmatch(message); code == 229 && match != nil {
+			port, _ = strconv.Atoi(match[1])
+		} else {
+			c.noEPSV = true
+		}
+	}
+	if port == 0 {
+		_, message, err := c.cmd(227, "PASV")
+		if err != nil {
+			return nil, fmt.Errorf("o servidor FTP recusou o modo passivo: %w", err)
+		}
+		match := pasvPattern.FindStringSubmatch(message)
+		if match == nil {
+			return nil, fmt.Errorf("resposta PASV inválida: %q", message)
+		}
+		high, _ := strconv.Atoi(match[5])
+		low, _ := strconv.Atoi(match[6])
+		port = high<<8 | low
+	}
+
+	dialer := net.Dialer{Timeout: c.timeout, Deadline: c.deadline}
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(c.host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir a conexão de dados: %w", err)
+	}
+	conn.SetDeadline(c.deadline)
+	return conn, nil
+}
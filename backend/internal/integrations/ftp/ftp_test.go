@@ -0,0 +1,282 @@
+package ftp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer é um servidor FTP local com arquivos em memória. legacy simula um servidor antigo,
+// sem EPSV nem MLSD.
+type testServer struct {
+	addr     string
+	password string
+	legacy   bool
+
+	mu    sync.Mutex
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+func newTestServer(t *testing.T, password string, legacy bool) *testServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	srv := &testServer{
+		addr:     listener.Addr().String(),
+		password: password,
+		legacy:   legacy,
+		files:    map[string][]byte{},
+		dirs:     map[string]bool{},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+func (s *testServer) config(password string) Config {
+	host, port, _ := net.SplitHostPort(s.addr)
+	p, _ := strconv.Atoi(port)
+	return Config{Host: host, Port: p, User: "parceiro", Password: password}
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	reply := func(code int, message string) { text.PrintfLine("%d %s", code, message) }
+	reply(220, "pronto")
+
+	var (
+		data       net.Listener
+		renameFrom string
+		loggedIn   bool
+	)
+	defer func() {
+		if data != nil {
+			data.Close()
+		}
+	}()
+	// transfer aceita a conexão de dados aberta pelo EPSV ou PASV anterior
+	transfer := func(fn func(net.Conn)) {
+		if data == nil {
+			reply(425, "use PASV primeiro")
+			return
+		}
+		reply(150, "abrindo conexão de dados")
+		dataConn, err := data.Accept()
+		data.Close()
+		data = nil
+		if err != nil {
+			reply(425, "falha na conexão de dados")
+			return
+		}
+		fn(dataConn)
+		dataConn.Close()
+		reply(226, "transferência concluída")
+	}
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		if !loggedIn && command != "USER" && command != "PASS" {
+			reply(530, "faça login")
+			continue
+		}
+
+		switch command {
+		case "USER":
+			reply(331, "informe a senha")
+		case "PASS":
+			if arg != s.password {
+				reply(530, "login incorreto")
+				continue
+			}
+			loggedIn = true
+			reply(230, "bem-vindo")
+		case "TYPE":
+			reply(200, "modo "+arg)
+		case "EPSV", "PASV":
+			if s.legacy && command == "EPSV" {
+				reply(500, "comando desconhecido")
+				continue
+			}
+			data, _ = net.Listen("tcp", "127.0.0.1:0")
+			port := data.Addr().(*net.TCPAddr).Port
+			if command == "EPSV" {
+				reply(229, fmt.Sprintf("Entering Extended Passive Mode (|||%d|)", port))
+			} else {
+				// Endereço interno do servidor, que o cliente deve ignorar
+				reply(227, fmt.Sprintf("Entering Passive Mode (10,0,0,9,%d,%d)", port>>8, port&0xff))
+			}
+		case "MLSD", "NLST":
+			if s.legacy && command == "MLSD" {
+				reply(500, "comando desconhecido")
+				continue
+			}
+			listing := s.listing(arg, command == "MLSD")
+			transfer(func(c net.Conn) { io.WriteString(c, listing) })
+		case "RETR":
+			content, ok := s.file(arg)
+			if !ok {
+				reply(550, "arquivo não encontrado")
+				continue
+			}
+			transfer(func(c net.Conn) { c.Write(content) })
+		case "STOR":
+			transfer(func(c net.Conn) {
+				content, _ := io.ReadAll(bufio.NewReader(c))
+				s.put(arg, content)
+			})
+		case "DELE":
+			s.mu.Lock()
+			_, ok := s.files[arg]
+			delete(s.files, arg)
+			s.mu.Unlock()
+			if !ok {
+				reply(550, "arquivo não encontrado")
+				continue
+			}
+			reply(250, "apagado")
+		case "RNFR":
+			if _, ok := s.file(arg); !ok {
+				reply(550, "arquivo não encontrado")
+				continue
+			}
+			renameFrom = arg
+			reply(350, "informe o destino")
+		case "RNTO":
+			s.mu.Lock()
+			s.files[arg] = s.files[renameFrom]
+			delete(s.files, renameFrom)
+			s.mu.Unlock()
+			reply(250, "renomeado")
+		case "QUIT":
+			reply(221, "até logo")
+			return
+		default:
+			reply(502, "não implementado")
+		}
+	}
+}
+
+// listing monta a resposta do MLSD (com um subdiretório) ou do NLST (caminhos completos)
+func (s *testServer) listing(dir string, mlsd bool) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	for name := range s.dirs {
+		if path.Dir(name) == dir && mlsd {
+			lines = append(lines, "type=dir;modify=20300101000000; "+path.Base(name))
+		}
+	}
+	for name, content := range s.files {
+		if path.Dir(name) != dir {
+			continue
+		}
+		if mlsd {
+			lines = append(lines, fmt.Sprintf("type=file;size=%d; %s", len(content), path.Base(name)))
+		} else {
+			lines = append(lines, name)
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func (s *testServer) put(name string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = content
+}
+
+func (s *testServer) file(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.files[name]
+	return content, ok
+}
+
+func TestClientPutListGetAndRename(t *testing.T) {
+	for _, legacy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("legacy=%v", legacy), func(t *testing.T) {
+			srv := newTestServer(t, "s3nha", legacy)
+			srv.dirs["/outbox/processados"] = true
+			srv.put("/outbox/pedido-1.csv", []byte("antigo"))
+
+			client, err := Dial(context.Background(), srv.config("s3nha"))
+			require.NoError(t, err)
+			defer client.Close()
+
+			// O envio substitui o arquivo anterior sem deixar o .part
+			require.NoError(t, client.Put("/outbox/pedido-1.csv", []byte("pedido;1")))
+			require.NoError(t, client.Put("/outbox/pedido-2.csv", []byte("pedido;2")))
+			_, partial := srv.file("/outbox/pedido-1.csv.part")
+			assert.False(t, partial)
+
+			names, err := client.List("/outbox")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"pedido-1.csv", "pedido-2.csv"}, names)
+
+			content, err := client.Get("/outbox/pedido-1.csv")
+			require.NoError(t, err)
+			assert.Equal(t, "pedido;1", string(content))
+
+			require.NoError(t, client.Rename("/outbox/pedido-1.csv", "/outbox/processados/pedido-1.csv"))
+			moved, ok := srv.file("/outbox/processados/pedido-1.csv")
+			require.True(t, ok)
+			assert.Equal(t, "pedido;1", string(moved))
+			require.NoError(t, client.Remove("/outbox/pedido-2.csv"))
+
+			// A sessão segue utilizável depois de um erro do servidor
+			_, err = client.Get("/outbox/inexistente.csv")
+			var protoErr *textproto.Error
+			require.ErrorAs(t, err, &protoErr)
+			assert.Equal(t, 550, protoErr.Code)
+			names, err = client.List("/outbox")
+			require.NoError(t, err)
+			assert.Empty(t, names)
+		})
+	}
+}
+
+func TestDialReportsAuthenticationFailure(t *testing.T) {
+	srv := newTestServer(t, "s3nha", false)
+
+	_, err := Dial(context.Background(), srv.config("errada"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "falha na autenticação FTP")
+}
+
+func TestClientRejectsLineBreaksInPaths(t *testing.T) {
+	srv := newTestServer(t, "s3nha", false)
+	client, err := Dial(context.Background(), srv.config("s3nha"))
+	require.NoError(t, err)
+	defer client.Close()
+
+	err = client.Remove("/outbox/a.csv\r\nDELE /outbox/b.csv")
+	assert.ErrorContains(t, err, "quebra de linha")
+}
@@ -0,0 +1,210 @@
+package pix
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bcbScopes são os escopos do token para criar e consultar cobranças imediatas
+const bcbScopes = "cob.write cob.read"
+
+// BCB cria as cobranças pela API Pix padronizada pelo Banco Central (PUT /cob/{txid}), com o
+// token OAuth (client credentials) pedido ao endpoint de token do PSP
+type BCB struct {
+	baseURL      string
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewBCB cria a integração com o PSP; sem TokenURL, o token é pedido em {BaseURL}/oauth/token
+func NewBCB(creds Credentials, client *http.Client) *BCB {
+	baseURL := strings.TrimRight(creds.BaseURL, "/")
+	tokenURL := creds.TokenURL
+	if tokenURL == "" {
+		tokenURL = baseURL + "/oauth/token"
+	}
+	return &BCB{
+		baseURL:      baseURL,
+		tokenURL:     tokenURL,
+		clientID:     creds.ClientID,
+		clientSecret: creds.ClientSecret,
+		client:       client,
+	}
+}
+
+// Name retorna o identificador do provedor
+func (b *BCB) Name() string {
+	return ProviderBCB
+}
+
+type bcbChargeRequest struct {
+	Calendario struct {
+		Expiracao int `json:"expiracao"`
+	} `json:"calendario"`
+	Devedor *bcbDebtor `json:"devedor,omitempty"`
+	Valor   struct {
+		Original string `json:"original"`
+	} `json:"valor"`
+	Chave              string `json:"chave"`
+	SolicitacaoPagador string `json:"solicitacaoPagador,omitempty"`
+}
+
+type bcbDebtor struct {
+	CPF  string `json:"cpf,omitempty"`
+	CNPJ string `json:"cnpj,omitempty"`
+	Nome string `json:"nome"`
+}
+
+type bcbChargeResponse struct {
+	TxID     string `json:"txid"`
+	Location string `json:"location"`
+	Loc      struct {
+		ID       json.Number `json:"id"`
+		Location string      `json:"location"`
+	} `json:"loc"`
+	PixCopiaECola string `json:"pixCopiaECola"`
+}
+
+// solicitacaoMax é o tamanho máximo do texto exibido ao pagador
+const solicitacaoMax = 140
+
+// CreateCharge cria a cobrança imediata; o devedor só vai quando o documento é um CPF ou CNPJ
+func (b *BCB) CreateCharge(ctx context.Context, charge Charge) (*ChargeResult, error) {
+	var request bcbChargeRequest
+	request.Calendario.Expiracao = int(charge.Expiration.Seconds())
+	request.Valor.Original = charge.Amount.Round(2).StringFixed(2)
+	request.Chave = charge.Key
+	request.SolicitacaoPagador = charge.Description
+	if len([]rune(request.SolicitacaoPagador)) > solicitacaoMax {
+		request.SolicitacaoPagador = string([]rune(request.SolicitacaoPagador)[:solicitacaoMax])
+	}
+	switch len(charge.PayerDocument) {
+	case 11:
+		request.Devedor = &bcbDebtor{CPF: charge.PayerDocument, Nome: charge.PayerName}
+	case 14:
+		request.Devedor = &bcbDebtor{CNPJ: charge.PayerDocument, Nome: charge.PayerName}
+	}
+
+	var response bcbChargeResponse
+	if err := b.do(ctx, http.MethodPut, "/cob/"+url.PathEscape(charge.TxID), request, &response); err != nil {
+		return nil, err
+	}
+
+	location := response.Location
+	if location == "" {
+		location = response.Loc.Location
+	}
+	if location == "" {
+		return nil, fmt.Errorf("%w: resposta do PSP sem a location da cobrança", errors.ErrPixProviderFailed)
+	}
+	txID := response.TxID
+	if txID == "" {
+		txID = charge.TxID
+	}
+	return &ChargeResult{
+		TxID:       txID,
+		Location:   strings.TrimPrefix(location, "https://"),
+		CopyPaste:  response.PixCopiaECola,
+		ExternalID: response.Loc.ID.String(),
+	}, nil
+}
+
+// accessToken retorna o token vigente ou pede um novo, um minuto antes de expirar
+func (b *BCB) accessToken(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.token != "" && time.Now().Before(b.expiresAt) {
+		return b.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("scope", bcbScopes)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.WrapError(err, "falha ao montar requisição de token ao PSP")
+	}
+	req.SetBasicAuth(b.clientID, b.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errors.ErrPixProviderFailed, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.WrapError(err, "falha ao ler resposta do PSP")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token recusado com status %d: %s", errors.ErrPixProviderFailed, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("%w: token inválido na resposta do PSP", errors.ErrPixProviderFailed)
+	}
+
+	b.token = token.AccessToken
+	b.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return b.token, nil
+}
+
+// do chama a API Pix com o token e decodifica a resposta em out
+func (b *BCB) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	token, err := b.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errors.WrapError(err, "falha ao montar requisição ao PSP")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return errors.WrapError(err, "falha ao montar requisição ao PSP")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrPixProviderFailed, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.WrapError(err, "falha ao ler resposta do PSP")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: PSP retornou status %d em %s %s: %s", errors.ErrPixProviderFailed, resp.StatusCode, method, path, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return errors.WrapError(err, "resposta inválida do PSP")
+	}
+	return nil
+}
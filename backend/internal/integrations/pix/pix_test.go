@@ -0,0 +1,97 @@
+package pix
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New("", Credentials{})
+	assert.Equal(t, errors.ErrChargeNotConfigured, err)
+
+	provider, err := New("BCB", Credentials{BaseURL: "https://pix.psp.com.br/api/v2"})
+	require.NoError(t, err)
+	assert.Equal(t, ProviderBCB, provider.Name())
+
+	_, err = New("gerencianet", Credentials{})
+	assert.Equal(t, errors.ErrUnknownPixProvider, err)
+}
+
+func TestBCBCreateCharge(t *testing.T) {
+	var tokens int
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			tokens++
+			user, pass, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "client", user)
+			assert.Equal(t, "secret", pass)
+			assert.Equal(t, bcbScopes, r.FormValue("scope"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "abc", "expires_in": 3600})
+		case "/v2/cob/INV8T7f3a9c1d2e4b5a6978c0d1e2f3a4b5":
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"txid":   "INV8T7f3a9c1d2e4b5a6978c0d1e2f3a4b5",
+				"status": "ATIVA",
+				"loc":    map[string]interface{}{"id": 789, "location": "pix.psp.com.br/qr/v2/9d36b84f"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewBCB(Credentials{BaseURL: server.URL + "/v2", TokenURL: server.URL + "/oauth/token",
+		ClientID: "client", ClientSecret: "secret"}, server.Client())
+	charge := Charge{
+		TxID: "INV8T7f3a9c1d2e4b5a6978c0d1e2f3a4b5", Key: "12345678000195", Amount: money.MustParse("750"),
+		Expiration: time.Hour, PayerName: "Cliente PJ Ltda", PayerDocument: "11222333000181",
+		Description: "Fatura INV-2026-0008",
+	}
+
+	result, err := provider.CreateCharge(context.Background(), charge)
+	require.NoError(t, err)
+	assert.Equal(t, "pix.psp.com.br/qr/v2/9d36b84f", result.Location)
+	assert.Equal(t, "789", result.ExternalID)
+	assert.Equal(t, "750.00", received["valor"].(map[string]interface{})["original"])
+	assert.Equal(t, float64(3600), received["calendario"].(map[string]interface{})["expiracao"])
+	assert.Equal(t, "11222333000181", received["devedor"].(map[string]interface{})["cnpj"])
+	assert.Equal(t, "12345678000195", received["chave"])
+
+	_, err = provider.CreateCharge(context.Background(), charge)
+	require.NoError(t, err)
+	assert.Equal(t, 1, tokens, "token reaproveitado até expirar")
+
+	charge.TxID = "desconhecido00000000000000"
+	_, err = provider.CreateCharge(context.Background(), charge)
+	assert.ErrorIs(t, err, errors.ErrPixProviderFailed)
+}
+
+func TestParseWebhook(t *testing.T) {
+	body := []byte(`{"pix":[{"endToEndId":"E12345678202610161200abcdefghijk","txid":"INV8T7f3a9c1d2e4b5a6978c0d1e2f3a4b5",
+		"valor":"750.00","horario":"2026-10-16T12:00:00.000-03:00","infoPagador":"fatura 8"}]}`)
+	payments, err := ParseWebhook(body)
+	require.NoError(t, err)
+	require.Len(t, payments, 1)
+	assert.Equal(t, "E12345678202610161200abcdefghijk", payments[0].EndToEndID)
+	assert.True(t, payments[0].Amount.Equal(money.FromInt(750)))
+	assert.Equal(t, 15, payments[0].PaidAt.UTC().Hour())
+
+	_, err = ParseWebhook([]byte(`{"pix":[{"txid":"x","valor":"1.00"}]}`))
+	assert.Equal(t, errors.ErrInvalidWebhookPayload, err)
+	_, err = ParseWebhook([]byte(`nada`))
+	assert.Equal(t, errors.ErrInvalidWebhookPayload, err)
+}
@@ -0,0 +1,130 @@
+// Package pix cria as cobranças Pix dinâmicas (cob) no PSP recebedor e interpreta as
+// notificações de pagamento enviadas pelo webhook. Os PSPs seguem a API Pix padronizada pelo
+// Banco Central, com autenticação OAuth sobre mTLS.
+package pix
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Provedores de cobrança Pix
+const (
+	// ProviderBCB é qualquer PSP que implemente a API Pix do Banco Central (Inter, Itaú,
+	// Santander, Sicoob...), com a URL base e o endpoint de token do PSP
+	ProviderBCB = "bcb"
+)
+
+// defaultTimeout limita o tempo das chamadas ao PSP
+const defaultTimeout = 30 * time.Second
+
+// Charge é a cobrança imediata a criar no PSP
+type Charge struct {
+	// TxID identifica a cobrança: de 26 a 35 letras e números
+	TxID          string
+	Key           string
+	Amount        money.Decimal
+	Expiration    time.Duration
+	PayerName     string
+	PayerDocument string // CPF ou CNPJ, só dígitos
+	Description   string
+}
+
+// ChargeResult é a cobrança criada. Location é a URL do payload (sem o https://) que vai no QR
+// dinâmico; alguns PSPs já devolvem também o copia e cola pronto.
+type ChargeResult struct {
+	TxID       string
+	Location   string
+	CopyPaste  string
+	ExternalID string
+}
+
+// Provider é a integração com o PSP recebedor
+type Provider interface {
+	Name() string
+	// CreateCharge cria a cobrança imediata com o txid informado
+	CreateCharge(ctx context.Context, charge Charge) (*ChargeResult, error)
+}
+
+// Credentials são os dados de acesso à API Pix do PSP e o certificado do cliente (mTLS)
+type Credentials struct {
+	BaseURL      string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	CertFile     string
+	KeyFile      string
+}
+
+// New retorna o provedor configurado
+func New(provider string, creds Credentials) (Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "":
+		return nil, errors.ErrChargeNotConfigured
+	case ProviderBCB:
+		client := &http.Client{Timeout: defaultTimeout}
+		if creds.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(creds.CertFile, creds.KeyFile)
+			if err != nil {
+				return nil, errors.WrapError(err, "falha ao carregar certificado da API Pix")
+			}
+			client.Transport = &http.Transport{TLSClientConfig: &tls.Config{
+				Certificates: []tls.Certificate{cert},
+				MinVersion:   tls.VersionTLS12,
+			}}
+		}
+		return NewBCB(creds, client), nil
+	default:
+		return nil, errors.ErrUnknownPixProvider
+	}
+}
+
+// Payment é um Pix recebido, como notificado pelo webhook
+type Payment struct {
+	EndToEndID string
+	TxID       string
+	Amount     money.Decimal
+	PaidAt     time.Time
+	PayerInfo  string
+}
+
+// webhookPayload é o corpo do webhook da API Pix: os Pix recebidos desde a última notificação
+type webhookPayload struct {
+	Pix []struct {
+		EndToEndID  string        `json:"endToEndId"`
+		TxID        string        `json:"txid"`
+		Valor       money.Decimal `json:"valor"`
+		Horario     time.Time     `json:"horario"`
+		InfoPagador string        `json:"infoPagador"`
+	} `json:"pix"`
+}
+
+// ParseWebhook lê os Pix recebidos do corpo do webhook; os sem txid (recebidos por chave, fora
+// de uma cobrança) também voltam, para o chamador decidir o que fazer com eles
+func ParseWebhook(body []byte) ([]Payment, error) {
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.ErrInvalidWebhookPayload
+	}
+
+	payments := make([]Payment, 0, len(payload.Pix))
+	for _, pix := range payload.Pix {
+		if pix.EndToEndID == "" || !pix.Valor.IsPositive() {
+			return nil, errors.ErrInvalidWebhookPayload
+		}
+		payments = append(payments, Payment{
+			EndToEndID: pix.EndToEndID,
+			TxID:       pix.TxID,
+			Amount:     pix.Valor,
+			PaidAt:     pix.Horario,
+			PayerInfo:  pix.InfoPagador,
+		})
+	}
+	return payments, nil
+}
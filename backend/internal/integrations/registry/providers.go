@@ -0,0 +1,323 @@
+package registry
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/utils/validation"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// flexString aceita texto ou número no JSON (CEP e CNAE variam entre os provedores)
+type flexString string
+
+func (f *flexString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*f = ""
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*f = flexString(text)
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*f = flexString(number.String())
+	return nil
+}
+
+// BrasilAPI consulta o CNPJ na BrasilAPI (dados abertos da Receita Federal)
+type BrasilAPI struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewBrasilAPI cria a integração com a BrasilAPI
+func NewBrasilAPI(baseURL string, client *http.Client) *BrasilAPI {
+	return &BrasilAPI{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+// Name retorna o identificador do provedor
+func (b *BrasilAPI) Name() string {
+	return ProviderBrasilAPI
+}
+
+type brasilAPIResponse struct {
+	CNPJ                flexString `json:"cnpj"`
+	RazaoSocial         string     `json:"razao_social"`
+	NomeFantasia        string     `json:"nome_fantasia"`
+	SituacaoCadastral   string     `json:"descricao_situacao_cadastral"`
+	CNAEFiscal          flexString `json:"cnae_fiscal"`
+	CNAEFiscalDescricao string     `json:"cnae_fiscal_descricao"`
+	TipoLogradouro      string     `json:"descricao_tipo_de_logradouro"`
+	Logradouro          string     `json:"logradouro"`
+	Numero              string     `json:"numero"`
+	Complemento         string     `json:"complemento"`
+	Bairro              string     `json:"bairro"`
+	Municipio           string     `json:"municipio"`
+	UF                  string     `json:"uf"`
+	CEP                 flexString `json:"cep"`
+	Email               *string    `json:"email"`
+	DDDTelefone1        string     `json:"ddd_telefone_1"`
+}
+
+// LookupCNPJ consulta o CNPJ
+func (b *BrasilAPI) LookupCNPJ(ctx context.Context, cnpj string) (*Company, error) {
+	var parsed brasilAPIResponse
+	if err := getJSON(ctx, b.client, fmt.Sprintf("%s/cnpj/v1/%s", b.baseURL, cnpj), "", errors.ErrCNPJNotFound, &parsed); err != nil {
+		return nil, err
+	}
+
+	street := strings.TrimSpace(parsed.Logradouro)
+	if parsed.TipoLogradouro != "" && !strings.HasPrefix(strings.ToUpper(street), strings.ToUpper(parsed.TipoLogradouro)) {
+		street = parsed.TipoLogradouro + " " + street
+	}
+	company := &Company{
+		CNPJ:            validation.OnlyDigits(string(parsed.CNPJ)),
+		LegalName:       parsed.RazaoSocial,
+		TradeName:       parsed.NomeFantasia,
+		Status:          parsed.SituacaoCadastral,
+		Active:          isActive(parsed.SituacaoCadastral),
+		CNAE:            validation.OnlyDigits(string(parsed.CNAEFiscal)),
+		CNAEDescription: parsed.CNAEFiscalDescricao,
+		Phone:           validation.OnlyDigits(parsed.DDDTelefone1),
+		Address: Address{
+			ZipCode:      validation.OnlyDigits(string(parsed.CEP)),
+			Street:       street,
+			Number:       parsed.Numero,
+			Complement:   parsed.Complemento,
+			Neighborhood: parsed.Bairro,
+			City:         parsed.Municipio,
+			State:        parsed.UF,
+		},
+		Source: ProviderBrasilAPI,
+	}
+	if parsed.Email != nil {
+		company.Email = strings.ToLower(*parsed.Email)
+	}
+	return company, nil
+}
+
+// ReceitaWS consulta o CNPJ na ReceitaWS; com token, usa a API comercial
+type ReceitaWS struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewReceitaWS cria a integração com a ReceitaWS
+func NewReceitaWS(baseURL, token string, client *http.Client) *ReceitaWS {
+	return &ReceitaWS{baseURL: strings.TrimRight(baseURL, "/"), token: token, client: client}
+}
+
+// Name retorna o identificador do provedor
+func (r *ReceitaWS) Name() string {
+	return ProviderReceitaWS
+}
+
+type receitaWSResponse struct {
+	Status             string `json:"status"`
+	Message            string `json:"message"`
+	CNPJ               string `json:"cnpj"`
+	Nome               string `json:"nome"`
+	Fantasia           string `json:"fantasia"`
+	Situacao           string `json:"situacao"`
+	AtividadePrincipal []struct {
+		Code string `json:"code"`
+		Text string `json:"text"`
+	} `json:"atividade_principal"`
+	Logradouro  string `json:"logradouro"`
+	Numero      string `json:"numero"`
+	Complemento string `json:"complemento"`
+	Bairro      string `json:"bairro"`
+	Municipio   string `json:"municipio"`
+	UF          string `json:"uf"`
+	CEP         string `json:"cep"`
+	Email       string `json:"email"`
+	Telefone    string `json:"telefone"`
+}
+
+// LookupCNPJ consulta o CNPJ. A ReceitaWS responde 200 com status ERROR para CNPJ inexistente.
+func (r *ReceitaWS) LookupCNPJ(ctx context.Context, cnpj string) (*Company, error) {
+	authorization := ""
+	if r.token != "" {
+		authorization = "Bearer " + r.token
+	}
+	var parsed receitaWSResponse
+	if err := getJSON(ctx, r.client, fmt.Sprintf("%s/cnpj/%s", r.baseURL, cnpj), authorization, errors.ErrCNPJNotFound, &parsed); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(parsed.Status, "ERROR") {
+		if strings.Contains(strings.ToLower(parsed.Message), "inválido") || strings.Contains(strings.ToLower(parsed.Message), "não encontrado") {
+			return nil, errors.ErrCNPJNotFound
+		}
+		return nil, fmt.Errorf("receitaws: %s", parsed.Message)
+	}
+
+	company := &Company{
+		CNPJ:      validation.OnlyDigits(parsed.CNPJ),
+		LegalName: parsed.Nome,
+		TradeName: parsed.Fantasia,
+		Status:    parsed.Situacao,
+		Active:    isActive(parsed.Situacao),
+		Email:     strings.ToLower(parsed.Email),
+		Phone:     validation.OnlyDigits(strings.Split(parsed.Telefone, "/")[0]),
+		Address: Address{
+			ZipCode:      validation.OnlyDigits(parsed.CEP),
+			Street:       parsed.Logradouro,
+			Number:       parsed.Numero,
+			Complement:   parsed.Complemento,
+			Neighborhood: parsed.Bairro,
+			City:         parsed.Municipio,
+			State:        parsed.UF,
+		},
+		Source: ProviderReceitaWS,
+	}
+	if len(parsed.AtividadePrincipal) > 0 {
+		company.CNAE = validation.OnlyDigits(parsed.AtividadePrincipal[0].Code)
+		company.CNAEDescription = parsed.AtividadePrincipal[0].Text
+	}
+	return company, nil
+}
+
+// CNPJa consulta o CNPJ na CNPJá, que informa também as inscrições estaduais; sem token, usa a
+// API aberta (open.cnpja.com)
+type CNPJa struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewCNPJa cria a integração com a CNPJá
+func NewCNPJa(baseURL, token string, client *http.Client) *CNPJa {
+	return &CNPJa{baseURL: strings.TrimRight(baseURL, "/"), token: token, client: client}
+}
+
+// Name retorna o identificador do provedor
+func (c *CNPJa) Name() string {
+	return ProviderCNPJa
+}
+
+type cnpjaResponse struct {
+	TaxID   string `json:"taxId"`
+	Alias   string `json:"alias"`
+	Company struct {
+		Name string `json:"name"`
+	} `json:"company"`
+	Status struct {
+		Text string `json:"text"`
+	} `json:"status"`
+	Address struct {
+		Street   string     `json:"street"`
+		Number   string     `json:"number"`
+		Details  string     `json:"details"`
+		District string     `json:"district"`
+		City     string     `json:"city"`
+		State    string     `json:"state"`
+		Zip      flexString `json:"zip"`
+	} `json:"address"`
+	MainActivity struct {
+		ID   flexString `json:"id"`
+		Text string     `json:"text"`
+	} `json:"mainActivity"`
+	Phones []struct {
+		Area   string `json:"area"`
+		Number string `json:"number"`
+	} `json:"phones"`
+	Emails []struct {
+		Address string `json:"address"`
+	} `json:"emails"`
+	Registrations []struct {
+		Number  string `json:"number"`
+		State   string `json:"state"`
+		Enabled bool   `json:"enabled"`
+	} `json:"registrations"`
+}
+
+// LookupCNPJ consulta o estabelecimento do CNPJ
+func (c *CNPJa) LookupCNPJ(ctx context.Context, cnpj string) (*Company, error) {
+	var parsed cnpjaResponse
+	if err := getJSON(ctx, c.client, fmt.Sprintf("%s/office/%s", c.baseURL, cnpj), c.token, errors.ErrCNPJNotFound, &parsed); err != nil {
+		return nil, err
+	}
+
+	company := &Company{
+		CNPJ:            validation.OnlyDigits(parsed.TaxID),
+		LegalName:       parsed.Company.Name,
+		TradeName:       parsed.Alias,
+		Status:          parsed.Status.Text,
+		Active:          isActive(parsed.Status.Text),
+		CNAE:            validation.OnlyDigits(string(parsed.MainActivity.ID)),
+		CNAEDescription: parsed.MainActivity.Text,
+		Address: Address{
+			ZipCode:      validation.OnlyDigits(string(parsed.Address.Zip)),
+			Street:       parsed.Address.Street,
+			Number:       parsed.Address.Number,
+			Complement:   parsed.Address.Details,
+			Neighborhood: parsed.Address.District,
+			City:         parsed.Address.City,
+			State:        parsed.Address.State,
+		},
+		Source: ProviderCNPJa,
+	}
+	if len(parsed.Phones) > 0 {
+		company.Phone = validation.OnlyDigits(parsed.Phones[0].Area + parsed.Phones[0].Number)
+	}
+	if len(parsed.Emails) > 0 {
+		company.Email = strings.ToLower(parsed.Emails[0].Address)
+	}
+	for _, registration := range parsed.Registrations {
+		if registration.Enabled && strings.EqualFold(registration.State, parsed.Address.State) {
+			company.StateRegistration = registration.Number
+			break
+		}
+	}
+	return company, nil
+}
+
+// ViaCEP consulta o endereço do CEP no ViaCEP
+type ViaCEP struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewViaCEP cria a integração com o ViaCEP
+func NewViaCEP(baseURL string, client *http.Client) *ViaCEP {
+	return &ViaCEP{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+type viaCEPResponse struct {
+	CEP         string `json:"cep"`
+	Logradouro  string `json:"logradouro"`
+	Complemento string `json:"complemento"`
+	Bairro      string `json:"bairro"`
+	Localidade  string `json:"localidade"`
+	UF          string `json:"uf"`
+	// Erro vem como true (ou "true") para CEP inexistente
+	Erro interface{} `json:"erro"`
+}
+
+// LookupCEP consulta o CEP. O ViaCEP responde 200 com erro=true para CEP inexistente.
+func (v *ViaCEP) LookupCEP(ctx context.Context, cep string) (*Address, error) {
+	var parsed viaCEPResponse
+	if err := getJSON(ctx, v.client, fmt.Sprintf("%s/%s/json/", v.baseURL, cep), "", errors.ErrCEPNotFound, &parsed); err != nil {
+		return nil, err
+	}
+	if parsed.Erro != nil && fmt.Sprint(parsed.Erro) != "false" {
+		return nil, errors.ErrCEPNotFound
+	}
+
+	return &Address{
+		ZipCode:      validation.OnlyDigits(parsed.CEP),
+		Street:       parsed.Logradouro,
+		Complement:   parsed.Complemento,
+		Neighborhood: parsed.Bairro,
+		City:         parsed.Localidade,
+		State:        parsed.UF,
+	}, nil
+}
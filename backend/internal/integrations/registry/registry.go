@@ -0,0 +1,151 @@
+// Package registry consulta os cadastros públicos usados no preenchimento dos contatos: os dados
+// do CNPJ na Receita Federal (BrasilAPI, ReceitaWS ou CNPJá) e os endereços pelo CEP (ViaCEP).
+package registry
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Provedores de consulta de CNPJ
+const (
+	ProviderBrasilAPI = "brasilapi"
+	ProviderReceitaWS = "receitaws"
+	ProviderCNPJa     = "cnpja"
+)
+
+// defaultTimeout limita o tempo das consultas aos cadastros públicos
+const defaultTimeout = 10 * time.Second
+
+// Address é o endereço retornado pela consulta de CNPJ ou de CEP
+type Address struct {
+	ZipCode      string `json:"zip_code"`
+	Street       string `json:"street"`
+	Number       string `json:"number,omitempty"`
+	Complement   string `json:"complement,omitempty"`
+	Neighborhood string `json:"neighborhood"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+}
+
+// Company são os dados cadastrais do CNPJ
+type Company struct {
+	CNPJ            string `json:"cnpj"`
+	LegalName       string `json:"legal_name"`
+	TradeName       string `json:"trade_name"`
+	Status          string `json:"status"`
+	Active          bool   `json:"active"`
+	CNAE            string `json:"cnae"`
+	CNAEDescription string `json:"cnae_description"`
+	// StateRegistration é a inscrição estadual ativa na UF do endereço, quando o provedor informa
+	StateRegistration string  `json:"state_registration,omitempty"`
+	Email             string  `json:"email,omitempty"`
+	Phone             string  `json:"phone,omitempty"`
+	Address           Address `json:"address"`
+	Source            string  `json:"source"`
+}
+
+// CNPJProvider consulta os dados de um CNPJ (só dígitos)
+type CNPJProvider interface {
+	Name() string
+	LookupCNPJ(ctx context.Context, cnpj string) (*Company, error)
+}
+
+// CEPProvider consulta o endereço de um CEP (só dígitos)
+type CEPProvider interface {
+	LookupCEP(ctx context.Context, cep string) (*Address, error)
+}
+
+// Chain consulta os provedores de CNPJ em ordem até um responder. CNPJ inexistente encerra a
+// consulta; falhas (indisponibilidade, limite de requisições) passam ao próximo provedor.
+type Chain []CNPJProvider
+
+// Name identifica a cadeia de provedores
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, provider := range c {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// LookupCNPJ consulta os provedores em ordem
+func (c Chain) LookupCNPJ(ctx context.Context, cnpj string) (*Company, error) {
+	var failures []string
+	for _, provider := range c {
+		company, err := provider.LookupCNPJ(ctx, cnpj)
+		if err == nil {
+			return company, nil
+		}
+		if err == errors.ErrCNPJNotFound {
+			return nil, err
+		}
+		failures = append(failures, provider.Name()+": "+err.Error())
+	}
+	return nil, fmt.Errorf("%w: %s", errors.ErrRegistryUnavailable, strings.Join(failures, "; "))
+}
+
+// FromConfig monta os provedores configurados em CNPJ_PROVIDERS e o ViaCEP
+func FromConfig() (CNPJProvider, CEPProvider) {
+	client := &http.Client{Timeout: defaultTimeout}
+
+	var chain Chain
+	for _, name := range strings.Split(viper.GetString("CNPJ_PROVIDERS"), ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case ProviderBrasilAPI:
+			chain = append(chain, NewBrasilAPI(viper.GetString("BRASILAPI_URL"), client))
+		case ProviderReceitaWS:
+			chain = append(chain, NewReceitaWS(viper.GetString("RECEITAWS_URL"), viper.GetString("RECEITAWS_TOKEN"), client))
+		case ProviderCNPJa:
+			chain = append(chain, NewCNPJa(viper.GetString("CNPJA_URL"), viper.GetString("CNPJA_TOKEN"), client))
+		}
+	}
+	return chain, NewViaCEP(viper.GetString("VIACEP_URL"), client)
+}
+
+// getJSON faz a consulta e decodifica a resposta; 404 vira notFound. authorization vazio
+// consulta a API pública do provedor.
+func getJSON(ctx context.Context, client *http.Client, endpoint, authorization string, notFound error, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errors.WrapError(err, "falha ao montar consulta")
+	}
+	req.Header.Set("Accept", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WrapError(err, "falha na consulta")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return errors.WrapError(err, "falha ao ler resposta")
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return notFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return errors.WrapError(err, "resposta inválida")
+	}
+	return nil
+}
+
+// isActive indica se a situação cadastral é ativa
+func isActive(status string) bool {
+	return strings.EqualFold(strings.TrimSpace(status), "ativa")
+}
@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrasilAPILookupCNPJ(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cnpj/v1/19131243000197", r.URL.Path)
+		w.Write([]byte(`{"cnpj":"19131243000197","razao_social":"OPEN KNOWLEDGE BRASIL","nome_fantasia":"REDE PELO CONHECIMENTO LIVRE",
+			"descricao_situacao_cadastral":"ATIVA","cnae_fiscal":9430800,"cnae_fiscal_descricao":"Atividades de associações de defesa de direitos sociais",
+			"descricao_tipo_de_logradouro":"AVENIDA","logradouro":"PAULISTA 37","numero":"37","complemento":"ANDAR 4",
+			"bairro":"BELA VISTA","municipio":"SAO PAULO","uf":"SP","cep":"01311902","email":null,"ddd_telefone_1":"11 23851939"}`))
+	}))
+	defer server.Close()
+
+	company, err := NewBrasilAPI(server.URL, server.Client()).LookupCNPJ(context.Background(), "19131243000197")
+	require.NoError(t, err)
+	assert.Equal(t, "OPEN KNOWLEDGE BRASIL", company.LegalName)
+	assert.True(t, company.Active)
+	assert.Equal(t, "9430800", company.CNAE)
+	assert.Equal(t, "AVENIDA PAULISTA 37", company.Address.Street)
+	assert.Equal(t, "01311902", company.Address.ZipCode)
+	assert.Equal(t, "1123851939", company.Phone)
+	assert.Equal(t, ProviderBrasilAPI, company.Source)
+}
+
+func TestReceitaWSLookupCNPJ(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-receita", r.Header.Get("Authorization"))
+		if r.URL.Path == "/cnpj/11111111111111" {
+			w.Write([]byte(`{"status":"ERROR","message":"CNPJ inválido"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"OK","cnpj":"19.131.243/0001-97","nome":"OPEN KNOWLEDGE BRASIL","fantasia":"",
+			"situacao":"BAIXADA","atividade_principal":[{"code":"94.30-8-00","text":"Atividades de associações"}],
+			"logradouro":"AV PAULISTA","numero":"37","bairro":"BELA VISTA","municipio":"SAO PAULO","uf":"SP",
+			"cep":"01.311-902","email":"Contato@OKBR.org","telefone":"(11) 2385-1939 / (11) 9999-9999"}`))
+	}))
+	defer server.Close()
+
+	provider := NewReceitaWS(server.URL, "token-receita", server.Client())
+	company, err := provider.LookupCNPJ(context.Background(), "19131243000197")
+	require.NoError(t, err)
+	assert.Equal(t, "19131243000197", company.CNPJ)
+	assert.False(t, company.Active)
+	assert.Equal(t, "9430800", company.CNAE)
+	assert.Equal(t, "01311902", company.Address.ZipCode)
+	assert.Equal(t, "contato@okbr.org", company.Email)
+	assert.Equal(t, "1123851939", company.Phone)
+
+	_, err = provider.LookupCNPJ(context.Background(), "11111111111111")
+	assert.Equal(t, errors.ErrCNPJNotFound, err)
+}
+
+func TestCNPJaLookupCNPJStateRegistration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/office/19131243000197", r.URL.Path)
+		w.Write([]byte(`{"taxId":"19131243000197","alias":"OKBR","company":{"name":"OPEN KNOWLEDGE BRASIL"},
+			"status":{"text":"Ativa"},"mainActivity":{"id":9430800,"text":"Associações"},
+			"address":{"street":"Avenida Paulista","number":"37","district":"Bela Vista","city":"São Paulo","state":"SP","zip":"01311902"},
+			"registrations":[{"number":"111","state":"RJ","enabled":true},{"number":"222","state":"SP","enabled":false},{"number":"333","state":"SP","enabled":true}]}`))
+	}))
+	defer server.Close()
+
+	company, err := NewCNPJa(server.URL, "", server.Client()).LookupCNPJ(context.Background(), "19131243000197")
+	require.NoError(t, err)
+	assert.True(t, company.Active)
+	assert.Equal(t, "333", company.StateRegistration)
+	assert.Equal(t, "OKBR", company.TradeName)
+}
+
+func TestViaCEPLookupCEP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/99999999/json/" {
+			w.Write([]byte(`{"erro":"true"}`))
+			return
+		}
+		assert.Equal(t, "/01001000/json/", r.URL.Path)
+		w.Write([]byte(`{"cep":"01001-000","logradouro":"Praça da Sé","complemento":"lado ímpar","bairro":"Sé","localidade":"São Paulo","uf":"SP"}`))
+	}))
+	defer server.Close()
+
+	provider := NewViaCEP(server.URL, server.Client())
+	address, err := provider.LookupCEP(context.Background(), "01001000")
+	require.NoError(t, err)
+	assert.Equal(t, "01001000", address.ZipCode)
+	assert.Equal(t, "Praça da Sé", address.Street)
+	assert.Equal(t, "São Paulo", address.City)
+
+	_, err = provider.LookupCEP(context.Background(), "99999999")
+	assert.Equal(t, errors.ErrCEPNotFound, err)
+}
+
+type stubProvider struct {
+	name    string
+	company *Company
+	err     error
+	calls   int
+}
+
+func (s *stubProvider) Name() string { return s.name }
+
+func (s *stubProvider) LookupCNPJ(ctx context.Context, cnpj string) (*Company, error) {
+	s.calls++
+	return s.company, s.err
+}
+
+func TestChainFallsBackOnFailure(t *testing.T) {
+	failing := &stubProvider{name: "a", err: stderrors.New("status 429")}
+	working := &stubProvider{name: "b", company: &Company{CNPJ: "19131243000197"}}
+
+	company, err := Chain{failing, working}.LookupCNPJ(context.Background(), "19131243000197")
+	require.NoError(t, err)
+	assert.Equal(t, "19131243000197", company.CNPJ)
+
+	notFound := &stubProvider{name: "c", err: errors.ErrCNPJNotFound}
+	untouched := &stubProvider{name: "d", company: &Company{}}
+	_, err = Chain{notFound, untouched}.LookupCNPJ(context.Background(), "19131243000197")
+	assert.Equal(t, errors.ErrCNPJNotFound, err)
+	assert.Equal(t, 0, untouched.calls)
+
+	_, err = Chain{failing}.LookupCNPJ(context.Background(), "19131243000197")
+	assert.True(t, stderrors.Is(err, errors.ErrRegistryUnavailable))
+}
@@ -0,0 +1,547 @@
+package sftp
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Cliente mínimo do SFTP versão 3 (draft-ietf-secsh-filexfer-02) sobre o subsistema "sftp" do
+// SSH: só o necessário para gravar, listar, ler e mover arquivos no servidor do parceiro.
+
+// DefaultPort é a porta do SSH quando o parceiro não informa outra
+const DefaultPort = 22
+
+// defaultTimeout limita a conexão e o envio quando o contexto não tem prazo
+const defaultTimeout = 30 * time.Second
+
+// Tipos de pacote e flags usados do protocolo
+const (
+	fxpInit    = 1
+	fxpVersion = 2
+	fxpOpen    = 3
+	fxpClose   = 4
+	fxpRead    = 5
+	fxpWrite   = 6
+	fxpOpenDir = 11
+	fxpReadDir = 12
+	fxpRemove  = 13
+	fxpRename  = 18
+	fxpStatus  = 101
+	fxpHandle  = 102
+	fxpData    = 103
+	fxpName    = 104
+
+	flagRead     = 0x01
+	flagWrite    = 0x02
+	flagCreate   = 0x08
+	flagTruncate = 0x10
+
+	attrSize        = 0x01
+	attrUIDGID      = 0x02
+	attrPermissions = 0x04
+	attrACModTime   = 0x08
+	attrExtended    = 0x80000000
+
+	// Bits de tipo do campo permissions (os mesmos do st_mode)
+	modeType    = 0o170000
+	modeRegular = 0o100000
+
+	protocolVersion = 3
+	statusOK        = 0
+	statusEOF       = 1
+)
+
+// chunkSize é o tamanho de cada escrita; 32 KiB é aceito por todos os servidores
+const chunkSize = 32 * 1024
+
+// maxPacket limita as respostas lidas do servidor
+const maxPacket = 256 * 1024
+
+// maxFileSize limita os arquivos lidos com Get
+const maxFileSize = 32 * 1024 * 1024
+
+// Config são os dados de conexão ao servidor SFTP. HostKey é a chave pública do servidor no
+// formato authorized_keys: conexões a servidores com outra chave são recusadas.
+type Config struct {
+	Host       string
+	Port       int
+	User       string
+	Password   string
+	PrivateKey string
+	HostKey    string
+	Timeout    time.Duration
+}
+
+// StatusError é uma resposta de erro do servidor SFTP
+type StatusError struct {
+	Code    uint32
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("servidor SFTP respondeu %q (código %d)", e.Message, e.Code)
+}
+
+// ParseHostKey valida a chave pública do servidor informada no formato authorized_keys
+// ("ssh-ed25519 AAAA...")
+func ParseHostKey(text string) (ssh.PublicKey, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(text)))
+	return key, err
+}
+
+// Client é uma sessão SFTP aberta; deve ser fechada com Close
+type Client struct {
+	netConn net.Conn
+	ssh     *ssh.Client
+	session *ssh.Session
+	conn    *conn
+}
+
+// Dial conecta ao servidor, confere a chave dele e abre o subsistema SFTP. O prazo do contexto
+// (ou Timeout, quando o contexto não tem prazo) vale para a sessão inteira.
+func Dial(ctx context.Context, cfg Config) (*Client, error) {
+	clientConfig, err := cfg.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	dialer := net.Dialer{Timeout: timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao conectar a %s: %w", addr, err)
+	}
+	netConn.SetDeadline(deadline)
+
+	client := &Client{netConn: netConn}
+	if err := client.open(addr, clientConfig); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func (c *Client) open(addr string, clientConfig *ssh.ClientConfig) error {
+	sshConn, chans, reqs, err := ssh.NewClientConn(c.netConn, addr, clientConfig)
+	if err != nil {
+		return fmt.Errorf("falha na autenticação SSH em %s: %w", addr, err)
+	}
+	c.ssh = ssh.NewClient(sshConn, chans, reqs)
+
+	c.session, err = c.ssh.NewSession()
+	if err != nil {
+		return fmt.Errorf("falha ao abrir sessão SSH: %w", err)
+	}
+	w, err := c.session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	r, err := c.session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := c.session.RequestSubsystem("sftp"); err != nil {
+		return fmt.Errorf("o servidor não oferece SFTP: %w", err)
+	}
+
+	c.conn = &conn{r: r, w: w}
+	return c.conn.handshake()
+}
+
+// Close encerra a sessão e a conexão
+func (c *Client) Close() error {
+	if c.session != nil {
+		c.session.Close()
+	}
+	if c.ssh != nil {
+		c.ssh.Close()
+	}
+	return c.netConn.Close()
+}
+
+// Put grava data em remotePath. O conteúdo é escrito em remotePath.part e renomeado ao fim,
+// para o parceiro nunca ler um arquivo pela metade; um arquivo anterior com o mesmo nome é
+// substituído.
+func (c *Client) Put(remotePath string, data []byte) error {
+	return c.conn.put(remotePath, data)
+}
+
+// Get lê o arquivo inteiro, até o limite de maxFileSize
+func (c *Client) Get(remotePath string) ([]byte, error) {
+	return c.conn.get(remotePath)
+}
+
+// List retorna os nomes dos arquivos comuns do diretório, em ordem alfabética; subdiretórios e
+// links ficam de fora
+func (c *Client) List(dir string) ([]string, error) {
+	return c.conn.list(dir)
+}
+
+// Rename move o arquivo; o destino não pode existir
+func (c *Client) Rename(from, to string) error {
+	if err := c.conn.call(fxpRename, from, to); err != nil {
+		return fmt.Errorf("falha ao renomear %s: %w", from, err)
+	}
+	return nil
+}
+
+// Remove apaga o arquivo
+func (c *Client) Remove(remotePath string) error {
+	if err := c.conn.call(fxpRemove, remotePath); err != nil {
+		return fmt.Errorf("falha ao apagar %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+// Upload abre uma sessão só para gravar data em remotePath (ver Client.Put)
+func Upload(ctx context.Context, cfg Config, remotePath string, data []byte) error {
+	client, err := Dial(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Put(remotePath, data)
+}
+
+// Validate confere a chave do servidor, a chave privada e se há alguma credencial
+func (cfg Config) Validate() error {
+	_, err := cfg.clientConfig()
+	return err
+}
+
+func (cfg Config) clientConfig() (*ssh.ClientConfig, error) {
+	hostKey, err := ParseHostKey(cfg.HostKey)
+	if err != nil {
+		return nil, fmt.Errorf("chave do servidor SFTP inválida: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if cfg.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(cfg.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("chave privada inválida: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("informe a senha ou a chave privada")
+	}
+
+	return &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         cfg.Timeout,
+	}, nil
+}
+
+// conn fala o protocolo SFTP sobre a entrada e a saída do subsistema
+type conn struct {
+	r      io.Reader
+	w      io.Writer
+	nextID uint32
+}
+
+func (c *conn) handshake() error {
+	if err := c.send(fxpInit, uint32(protocolVersion)); err != nil {
+		return err
+	}
+	kind, payload, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if kind != fxpVersion || len(payload) < 4 {
+		return fmt.Errorf("resposta inesperada ao iniciar o SFTP (tipo %d)", kind)
+	}
+	if version := binary.BigEndian.Uint32(payload); version < protocolVersion {
+		return fmt.Errorf("versão %d do SFTP não suportada", version)
+	}
+	return nil
+}
+
+func (c *conn) put(remotePath string, data []byte) error {
+	partial := remotePath + ".part"
+
+	handle, err := c.openHandle(fxpOpen, partial, uint32(flagWrite|flagCreate|flagTruncate), uint32(0))
+	if err != nil {
+		return fmt.Errorf("falha ao criar %s: %w", partial, err)
+	}
+
+	for offset := 0; offset < len(data); offset += chunkSize {
+		end := min(offset+chunkSize, len(data))
+		if err := c.call(fxpWrite, handle, uint64(offset), data[offset:end]); err != nil {
+			return fmt.Errorf("falha ao gravar %s: %w", partial, err)
+		}
+	}
+	if err := c.call(fxpClose, handle); err != nil {
+		return fmt.Errorf("falha ao fechar %s: %w", partial, err)
+	}
+
+	// O SFTP 3 não renomeia sobre um arquivo existente: o envio anterior é removido antes; a
+	// falha da remoção (em geral, arquivo inexistente) aparece no rename, se for o caso
+	c.call(fxpRemove, remotePath)
+	if err := c.call(fxpRename, partial, remotePath); err != nil {
+		return fmt.Errorf("falha ao renomear %s: %w", partial, err)
+	}
+	return nil
+}
+
+func (c *conn) get(remotePath string) ([]byte, error) {
+	handle, err := c.openHandle(fxpOpen, remotePath, uint32(flagRead), uint32(0))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir %s: %w", remotePath, err)
+	}
+	defer c.call(fxpClose, handle)
+
+	var data []byte
+	for {
+		kind, payload, err := c.request(fxpRead, handle, uint64(len(data)), uint32(chunkSize))
+		if err != nil {
+			return nil, err
+		}
+		if kind == fxpStatus && isEOF(payload) {
+			return data, nil
+		}
+		if kind != fxpData {
+			return nil, fmt.Errorf("falha ao ler %s: %w", remotePath, status(kind, payload))
+		}
+		chunk, _, ok := readString(payload)
+		if !ok {
+			return nil, fmt.Errorf("resposta inválida do servidor SFTP ao ler %s", remotePath)
+		}
+		if len(data)+len(chunk) > maxFileSize {
+			return nil, fmt.Errorf("%s excede o limite de %d MiB", remotePath, maxFileSize>>20)
+		}
+		data = append(data, chunk...)
+	}
+}
+
+func (c *conn) list(dir string) ([]string, error) {
+	handle, err := c.openHandle(fxpOpenDir, dir)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir o diretório %s: %w", dir, err)
+	}
+	defer c.call(fxpClose, handle)
+
+	var names []string
+	for {
+		kind, payload, err := c.request(fxpReadDir, handle)
+		if err != nil {
+			return nil, err
+		}
+		if kind == fxpStatus && isEOF(payload) {
+			sort.Strings(names)
+			return names, nil
+		}
+		if kind != fxpName || len(payload) < 4 {
+			return nil, fmt.Errorf("falha ao listar %s: %w", dir, status(kind, payload))
+		}
+
+		count := binary.BigEndian.Uint32(payload)
+		rest := payload[4:]
+		for range count {
+			var name string
+			var ok bool
+			if name, rest, ok = readString(rest); !ok {
+				return nil, fmt.Errorf("resposta inválida do servidor SFTP ao listar %s", dir)
+			}
+			if _, rest, ok = readString(rest); !ok { // longname, só para exibição
+				return nil, fmt.Errorf("resposta inválida do servidor SFTP ao listar %s", dir)
+			}
+			var mode uint32
+			if mode, rest, ok = readAttrs(rest); !ok {
+				return nil, fmt.Errorf("resposta inválida do servidor SFTP ao listar %s", dir)
+			}
+			// Sem permissões informadas, só "." e ".." são descartados
+			if name == "." || name == ".." || (mode != 0 && mode&modeType != modeRegular) {
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+}
+
+// openHandle executa OPEN ou OPENDIR e devolve o handle
+func (c *conn) openHandle(kind byte, fields ...any) (string, error) {
+	reply, payload, err := c.request(kind, fields...)
+	if err != nil {
+		return "", err
+	}
+	if reply != fxpHandle {
+		return "", status(reply, payload)
+	}
+	handle, _, ok := readString(payload)
+	if !ok {
+		return "", fmt.Errorf("handle inválido na resposta do servidor SFTP")
+	}
+	return handle, nil
+}
+
+// call executa uma requisição que responde só com o status
+func (c *conn) call(kind byte, fields ...any) error {
+	kind, payload, err := c.request(kind, fields...)
+	if err != nil {
+		return err
+	}
+	return status(kind, payload)
+}
+
+// request envia a requisição com o próximo id e devolve a resposta, sem o id
+func (c *conn) request(kind byte, fields ...any) (byte, []byte, error) {
+	c.nextID++
+	id := c.nextID
+	if err := c.send(kind, append([]any{id}, fields...)...); err != nil {
+		return 0, nil, err
+	}
+	reply, payload, err := c.recv()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(payload) < 4 || binary.BigEndian.Uint32(payload) != id {
+		return 0, nil, fmt.Errorf("resposta fora de ordem do servidor SFTP")
+	}
+	return reply, payload[4:], nil
+}
+
+func (c *conn) send(kind byte, fields ...any) error {
+	packet := make([]byte, 4, 64)
+	packet = append(packet, kind)
+	for _, field := range fields {
+		switch v := field.(type) {
+		case uint32:
+			packet = binary.BigEndian.AppendUint32(packet, v)
+		case uint64:
+			packet = binary.BigEndian.AppendUint64(packet, v)
+		case string:
+			packet = binary.BigEndian.AppendUint32(packet, uint32(len(v)))
+			packet = append(packet, v...)
+		case []byte:
+			packet = binary.BigEndian.AppendUint32(packet, uint32(len(v)))
+			packet = append(packet, v...)
+		default:
+			panic(fmt.Sprintf("sftp: campo de tipo %T", field))
+		}
+	}
+	binary.BigEndian.PutUint32(packet, uint32(len(packet)-4))
+	_, err := c.w.Write(packet)
+	return err
+}
+
+func (c *conn) recv() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, fmt.Errorf("falha ao ler resposta do servidor SFTP: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > maxPacket {
+		return 0, nil, fmt.Errorf("pacote SFTP de %d bytes recusado", length)
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, fmt.Errorf("falha ao ler resposta do servidor SFTP: %w", err)
+	}
+	return header[4], payload, nil
+}
+
+// status converte a resposta FXP_STATUS em erro; outras respostas também são erro
+func status(kind byte, payload []byte) error {
+	if kind != fxpStatus || len(payload) < 4 {
+		return fmt.Errorf("resposta inesperada do servidor SFTP (tipo %d)", kind)
+	}
+	code := binary.BigEndian.Uint32(payload)
+	if code == statusOK {
+		return nil
+	}
+	message, _, _ := readString(payload[4:])
+	return &StatusError{Code: code, Message: message}
+}
+
+func isEOF(payload []byte) bool {
+	return len(payload) >= 4 && binary.BigEndian.Uint32(payload) == statusEOF
+}
+
+// readAttrs pula a estrutura ATTRS e devolve o campo permissions (0 quando ausente)
+func readAttrs(payload []byte) (uint32, []byte, bool) {
+	if len(payload) < 4 {
+		return 0, nil, false
+	}
+	flags := binary.BigEndian.Uint32(payload)
+	rest := payload[4:]
+
+	skip := 0
+	if flags&attrSize != 0 {
+		skip += 8
+	}
+	if flags&attrUIDGID != 0 {
+		skip += 8
+	}
+	if len(rest) < skip {
+		return 0, nil, false
+	}
+	rest = rest[skip:]
+
+	var mode uint32
+	if flags&attrPermissions != 0 {
+		if len(rest) < 4 {
+			return 0, nil, false
+		}
+		mode = binary.BigEndian.Uint32(rest)
+		rest = rest[4:]
+	}
+	if flags&attrACModTime != 0 {
+		if len(rest) < 8 {
+			return 0, nil, false
+		}
+		rest = rest[8:]
+	}
+	if flags&attrExtended != 0 {
+		if len(rest) < 4 {
+			return 0, nil, false
+		}
+		count := binary.BigEndian.Uint32(rest)
+		rest = rest[4:]
+		for range 2 * count {
+			var ok bool
+			if _, rest, ok = readString(rest); !ok {
+				return 0, nil, false
+			}
+		}
+	}
+	return mode, rest, true
+}
+
+func readString(payload []byte) (string, []byte, bool) {
+	if len(payload) < 4 {
+		return "", nil, false
+	}
+	n := binary.BigEndian.Uint32(payload)
+	if uint32(len(payload)-4) < n {
+		return "", nil, false
+	}
+	return string(payload[4 : 4+n]), payload[4+n:], true
+}
@@ -0,0 +1,154 @@
+// Package localtime resolve as datas de calendário no fuso horário de cada empresa. Os
+// instantes são gravados em UTC; "vencido", "expirado", "abandonado há N dias" e os limites
+// dos relatórios diários dependem do dia no fuso da empresa, e não do fuso do servidor.
+//
+// As colunas de data (due_date, expiry_date, delivery_date) guardam o dia à meia-noite, sem
+// fuso: compare-as com Today. Colunas de instante (created_at, payment_date) são comparadas
+// com os limites do dia em UTC (DayBounds, StartOfDay).
+package localtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/cache"
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DefaultTimezone é o fuso das empresas sem fuso cadastrado e das rotinas sem empresa no
+// contexto, quando DEFAULT_TIMEZONE não é definido
+const DefaultTimezone = "America/Sao_Paulo"
+
+// timezones guarda o fuso de cada empresa; companies.UpdateCompany invalida a entrada
+var timezones = cache.NewReference[int, string]("company_timezones")
+
+// lookup lê o fuso cadastrado da empresa; substituído nos testes
+var lookup = companyTimezone
+
+// now é substituído nos testes
+var now = time.Now
+
+// Load valida o nome IANA do fuso (ex.: America/Sao_Paulo); vazio é o fuso padrão
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		return DefaultLocation(), nil
+	}
+	return time.LoadLocation(name)
+}
+
+// DefaultLocation é o fuso de DEFAULT_TIMEZONE ou, sem ele ou com um nome inválido,
+// DefaultTimezone
+func DefaultLocation() *time.Location {
+	if name := viper.GetString("DEFAULT_TIMEZONE"); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Location retorna o fuso da empresa do contexto ou o padrão
+func Location(ctx context.Context) *time.Location {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return DefaultLocation()
+	}
+	return CompanyLocation(companyID)
+}
+
+// CompanyLocation retorna o fuso cadastrado da empresa. Sem fuso cadastrado, com um nome
+// inválido ou sem acesso ao banco, usa o padrão.
+func CompanyLocation(companyID int) *time.Location {
+	name, ok := timezones.Get(companyID)
+	if !ok {
+		var err error
+		name, err = lookup(companyID)
+		if err != nil {
+			logger.WithModule("localtime").Warn("erro ao buscar fuso horário da empresa",
+				zap.Error(err), zap.Int("company_id", companyID))
+			return DefaultLocation()
+		}
+		timezones.Set(companyID, name)
+	}
+
+	loc, err := Load(name)
+	if err != nil {
+		return DefaultLocation()
+	}
+	return loc
+}
+
+// Forget descarta do cache o fuso das empresas alteradas
+func Forget(companyIDs ...int) {
+	timezones.Delete(companyIDs...)
+}
+
+// Now retorna o instante atual no fuso da empresa do contexto
+func Now(ctx context.Context) time.Time {
+	return now().In(Location(ctx))
+}
+
+// Today retorna o dia atual no fuso da empresa do contexto (ver Date)
+func Today(ctx context.Context) time.Time {
+	return Date(Now(ctx))
+}
+
+// Date retorna o dia de calendário de t, no fuso de t, à meia-noite em UTC: o mesmo formato
+// das colunas de data sem horário (due_date, expiry_date), gravadas em UTC
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// StartOfDay retorna, em UTC, o instante em que o dia (ver Date) começa no fuso informado
+func StartOfDay(day time.Time, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).UTC()
+}
+
+// DayBounds retorna, em UTC, o início do dia atual da empresa do contexto e o início do dia
+// seguinte, para filtrar colunas de instante (created_at >= start AND created_at < end)
+func DayBounds(ctx context.Context) (time.Time, time.Time) {
+	loc := Location(ctx)
+	today := Date(now().In(loc))
+	return StartOfDay(today, loc), StartOfDay(today.AddDate(0, 0, 1), loc)
+}
+
+// DaysAgo retorna, em UTC, o início do dia de N dias atrás no fuso da empresa do contexto:
+// "sem atualização há N dias" conta dias de calendário da empresa
+func DaysAgo(ctx context.Context, days int) time.Time {
+	loc := Location(ctx)
+	return StartOfDay(Date(now().In(loc)).AddDate(0, 0, -days), loc)
+}
+
+var (
+	conn   *gorm.DB
+	connMu sync.Mutex
+)
+
+// companyTimezone lê companies.timezone, abrindo a conexão na primeira consulta
+func companyTimezone(companyID int) (string, error) {
+	connMu.Lock()
+	if conn == nil {
+		gormDB, err := db.OpenGormDB()
+		if err != nil {
+			connMu.Unlock()
+			return "", err
+		}
+		conn = gormDB
+	}
+	connMu.Unlock()
+
+	var name string
+	err := conn.Raw("SELECT timezone FROM companies WHERE id = ?", companyID).Scan(&name).Error
+	return name, err
+}
@@ -0,0 +1,75 @@
+package localtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTimezones substitui a consulta ao banco pelos fusos informados por empresa
+func stubTimezones(t *testing.T, zones map[int]string, at time.Time) {
+	t.Helper()
+	origLookup, origNow := lookup, now
+	lookup = func(companyID int) (string, error) {
+		name, ok := zones[companyID]
+		if !ok {
+			return "", errors.New("empresa não encontrada")
+		}
+		return name, nil
+	}
+	now = func() time.Time { return at }
+	t.Cleanup(func() {
+		lookup, now = origLookup, origNow
+		timezones.Purge()
+	})
+}
+
+func TestTodayUsesCompanyTimezone(t *testing.T) {
+	// 02:30 UTC de 16/10 ainda é 15/10 em São Paulo e já é 16/10 em Lisboa
+	stubTimezones(t, map[int]string{1: "America/Sao_Paulo", 2: "Europe/Lisbon"}, time.Date(2026, 10, 16, 2, 30, 0, 0, time.UTC))
+
+	saoPaulo := tenant.WithCompany(context.Background(), 1)
+	lisbon := tenant.WithCompany(context.Background(), 2)
+
+	assert.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), Today(saoPaulo))
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), Today(lisbon))
+}
+
+func TestLocationFallsBackToDefault(t *testing.T) {
+	stubTimezones(t, map[int]string{1: "", 2: "Mars/Olympus"}, time.Now())
+
+	assert.Equal(t, DefaultTimezone, Location(context.Background()).String())
+	assert.Equal(t, DefaultTimezone, Location(tenant.WithCompany(context.Background(), 1)).String())
+	assert.Equal(t, DefaultTimezone, Location(tenant.WithCompany(context.Background(), 2)).String())
+	assert.Equal(t, DefaultTimezone, Location(tenant.WithCompany(context.Background(), 3)).String())
+}
+
+func TestDayBoundsAreCompanyMidnightInUTC(t *testing.T) {
+	stubTimezones(t, map[int]string{1: "America/Sao_Paulo"}, time.Date(2026, 10, 16, 2, 30, 0, 0, time.UTC))
+	ctx := tenant.WithCompany(context.Background(), 1)
+
+	start, end := DayBounds(ctx)
+	assert.Equal(t, time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC), end)
+
+	assert.Equal(t, time.Date(2026, 10, 8, 3, 0, 0, 0, time.UTC), DaysAgo(ctx, 7))
+}
+
+func TestLoadValidatesTimezone(t *testing.T) {
+	loc, err := Load("Europe/Lisbon")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Lisbon", loc.String())
+
+	loc, err = Load("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimezone, loc.String())
+
+	_, err = Load("Mars/Olympus")
+	assert.Error(t, err)
+}
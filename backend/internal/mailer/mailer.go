@@ -0,0 +1,107 @@
+// Package mailer envia e-mails transacionais (convites, redefinição de senha) pelo servidor
+// SMTP configurado em SMTP_HOST. Sem SMTP_HOST o envio fica desativado e a mensagem é apenas
+// registrada no log, o que permite testar os fluxos em desenvolvimento.
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/logger"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Message é um e-mail de texto simples, opcionalmente com anexos
+type Message struct {
+	To          string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Attachment é um arquivo anexado à mensagem
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// sendMail é substituído nos testes
+var sendMail = smtp.SendMail
+
+// Send envia a mensagem pelo SMTP configurado
+func Send(msg Message) error {
+	host := viper.GetString("SMTP_HOST")
+	if host == "" {
+		logger.WithModule("mailer").Info("envio de e-mail desativado (SMTP_HOST vazio)",
+			zap.String("to", msg.To), zap.String("subject", msg.Subject))
+		return nil
+	}
+
+	from := viper.GetString("SMTP_FROM")
+	addr := host + ":" + strconv.Itoa(viper.GetInt("SMTP_PORT"))
+
+	var auth smtp.Auth
+	if user := viper.GetString("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, viper.GetString("SMTP_PASSWORD"), host)
+	}
+
+	if err := sendMail(addr, auth, from, []string{msg.To}, build(from, msg, time.Now())); err != nil {
+		logger.WithModule("mailer").Error("erro ao enviar e-mail",
+			zap.Error(err), zap.String("to", msg.To), zap.String("subject", msg.Subject))
+		return fmt.Errorf("falha ao enviar e-mail: %w", err)
+	}
+	return nil
+}
+
+// build monta a mensagem no formato RFC 5322, com o assunto codificado para aceitar acentos
+func build(from string, msg Message, now time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	if len(msg.Attachments) == 0 {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("\r\n")
+		buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+		return buf.Bytes()
+	}
+
+	// Com anexos a mensagem vira multipart/mixed: o texto na primeira parte e cada arquivo em base64
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	text, _ := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	text.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n")))
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, _ := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName})},
+		})
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded + "\r\n"))
+	}
+	writer.Close()
+	return buf.Bytes()
+}
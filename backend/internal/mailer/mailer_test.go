@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildEncodesHeaders(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	raw := string(build("erp@onsmart.com", Message{
+		To:      "ana@exemplo.com",
+		Subject: "Convite de acesso",
+		Body:    "Olá\nAcesse o link",
+	}, now))
+
+	assert.Contains(t, raw, "From: erp@onsmart.com\r\n")
+	assert.Contains(t, raw, "To: ana@exemplo.com\r\n")
+	assert.Contains(t, raw, "Subject: Convite de acesso\r\n")
+	assert.Contains(t, raw, "Date: Sun, 01 Mar 2026 10:00:00 +0000\r\n")
+	assert.True(t, strings.HasSuffix(raw, "\r\n\r\nOlá\r\nAcesse o link"))
+
+	raw = string(build("erp@onsmart.com", Message{To: "ana@exemplo.com", Subject: "Redefinição de senha"}, now))
+	assert.Contains(t, raw, "Subject: =?utf-8?q?Redefini=C3=A7=C3=A3o_de_senha?=\r\n")
+}
+
+func TestSend(t *testing.T) {
+	var gotAddr, gotFrom string
+	var gotTo []string
+	sendMail = func(addr string, _ smtp.Auth, from string, to []string, _ []byte) error {
+		gotAddr, gotFrom, gotTo = addr, from, to
+		return nil
+	}
+	t.Cleanup(func() {
+		sendMail = smtp.SendMail
+		viper.Set("SMTP_HOST", nil)
+		viper.Set("SMTP_PORT", nil)
+		viper.Set("SMTP_FROM", nil)
+	})
+
+	msg := Message{To: "ana@exemplo.com", Subject: "Teste", Body: "corpo"}
+
+	require.NoError(t, Send(msg))
+	assert.Empty(t, gotAddr, "sem SMTP_HOST nada é enviado")
+
+	viper.Set("SMTP_HOST", "smtp.exemplo.com")
+	viper.Set("SMTP_PORT", 587)
+	viper.Set("SMTP_FROM", "erp@onsmart.com")
+	require.NoError(t, Send(msg))
+	assert.Equal(t, "smtp.exemplo.com:587", gotAddr)
+	assert.Equal(t, "erp@onsmart.com", gotFrom)
+	assert.Equal(t, []string{"ana@exemplo.com"}, gotTo)
+}
+
+func TestBuildWithAttachments(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	raw := build("erp@onsmart.com", Message{
+		To:          "ana@exemplo.com",
+		Subject:     "Relatório semanal",
+		Body:        "Segue o relatório",
+		Attachments: []Attachment{{FileName: "vendas-20260301.csv", ContentType: "text/csv", Data: []byte("status,count\npaid,3\n")}},
+	}, now)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	text, err := reader.NextPart()
+	require.NoError(t, err)
+	body, _ := io.ReadAll(text)
+	assert.Equal(t, "Segue o relatório", string(body))
+
+	file, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "vendas-20260301.csv", file.FileName())
+	content, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, file))
+	assert.Equal(t, "status,count\npaid,3\n", string(content))
+}
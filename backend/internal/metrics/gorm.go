@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Pools acompanhados em db_pool_connections, registrados na inicialização (RegisterPool). Os
+// pools abertos pelo GORM são só contados: cada chamada a db.OpenGormDB cria um pool próprio, e
+// guardá-los aqui impediria que fossem liberados.
+var (
+	poolsMu     sync.Mutex
+	pools       []*sql.DB
+	openedPools atomic.Int64
+)
+
+func init() {
+	Default.NewFuncVec("db_pools", "Pools de conexão abertos pela aplicação.", "gauge", "", func() map[string]float64 {
+		return map[string]float64{"": float64(openedPools.Load())}
+	})
+	Default.NewFuncVec("db_pool_connections", "Conexões dos pools do GORM por estado.", "gauge", "state", func() map[string]float64 {
+		stats := poolStats()
+		return map[string]float64{
+			"open":   float64(stats.OpenConnections),
+			"in_use": float64(stats.InUse),
+			"idle":   float64(stats.Idle),
+		}
+	})
+	Default.NewFuncVec("db_pool_wait_total", "Vezes em que uma query esperou por conexão livre.", "counter", "", func() map[string]float64 {
+		return map[string]float64{"": float64(poolStats().WaitCount)}
+	})
+	Default.NewFuncVec("db_pool_wait_seconds_total", "Tempo total de espera por conexão livre.", "counter", "", func() map[string]float64 {
+		return map[string]float64{"": poolStats().WaitDuration.Seconds()}
+	})
+}
+
+// RegisterPool passa a acompanhar o pool de conexões do banco nas métricas db_pool_*. Deve ser
+// chamado uma vez na inicialização para cada conexão de longa duração (primário e réplica).
+func RegisterPool(db *gorm.DB) error {
+	pool, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	for _, registered := range pools {
+		if registered == pool {
+			return nil
+		}
+	}
+	pools = append(pools, pool)
+	return nil
+}
+
+// poolStats soma as estatísticas de todos os pools registrados
+func poolStats() sql.DBStats {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+
+	var total sql.DBStats
+	for _, pool := range pools {
+		stats := pool.Stats()
+		total.OpenConnections += stats.OpenConnections
+		total.InUse += stats.InUse
+		total.Idle += stats.Idle
+		total.WaitCount += stats.WaitCount
+		total.WaitDuration += stats.WaitDuration
+	}
+	return total
+}
+
+const startKey = "metrics:start"
+
+// GormPlugin mede a duração de cada query por método de repositório e conta os pools abertos
+// (db.Use(metrics.GormPlugin{})). O estado das conexões vem dos pools de RegisterPool.
+type GormPlugin struct{}
+
+// Name identifica o plugin no GORM
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize conta o pool e registra os callbacks que envolvem a execução do SQL de cada operação
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	openedPools.Add(1)
+
+	cb := db.Callback()
+	operations := []struct {
+		name          string
+		before, after interface {
+			Register(name string, fn func(*gorm.DB)) error
+		}
+	}{
+		{"query", cb.Query().Before("gorm:query"), cb.Query().After("gorm:query").Before("gorm:preload")},
+		{"create", cb.Create().Before("gorm:create"), cb.Create().After("gorm:create").Before("gorm:save_after_associations")},
+		{"update", cb.Update().Before("gorm:update"), cb.Update().After("gorm:update").Before("gorm:save_after_associations")},
+		{"delete", cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete")},
+		{"row", cb.Row().Before("gorm:row"), cb.Row().After("gorm:row")},
+		{"raw", cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw")},
+	}
+	for _, op := range operations {
+		if err := op.before.Register("metrics:before_"+op.name, startQuery); err != nil {
+			return err
+		}
+		if err := op.after.Register("metrics:after_"+op.name, observeQuery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func observeQuery(db *gorm.DB) {
+	start, ok := db.InstanceGet(startKey)
+	if !ok {
+		return
+	}
+	repository, method := callerRepository()
+	DBQueryDuration.Observe(time.Since(start.(time.Time)).Seconds(), repository, method)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		DBQueryErrors.Inc(repository, method)
+	}
+}
+
+// callerRepository encontra na pilha o método de repositório que disparou a query, ex.:
+// ".../modules/sales/repository.(*salesProcessRepository).GetAllSalesProcesses" vira
+// ("sales.salesProcessRepository", "GetAllSalesProcesses")
+func callerRepository() (string, string) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if repository, method, ok := parseRepositoryFunc(frame.Function); ok {
+			return repository, method
+		}
+		if !more {
+			return "other", "other"
+		}
+	}
+}
+
+func parseRepositoryFunc(function string) (string, string, bool) {
+	pkgPath, name, ok := strings.Cut(function[strings.LastIndex(function, "/")+1:], ".")
+	if !ok || pkgPath != "repository" {
+		return "", "", false
+	}
+	dir := strings.TrimSuffix(function[:strings.LastIndex(function, "/")], "/")
+	module := dir[strings.LastIndex(dir, "/")+1:]
+
+	// Remove closures (".func1") e separa o tipo do método
+	parts := strings.Split(name, ".")
+	for len(parts) > 1 && strings.HasPrefix(parts[len(parts)-1], "func") {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 1 {
+		return module, parts[0], true
+	}
+	receiver := strings.Trim(parts[0], "(*)")
+	return module + "." + receiver, parts[1], true
+}
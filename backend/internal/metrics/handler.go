@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// ContentType é o formato de texto lido pelo Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Expõe as métricas no formato do Prometheus
+//
+// Com METRICS_TOKEN definido, o scrape precisa enviar "Authorization: Bearer <token>".
+//
+// @Produce plain
+func ScrapeHandler(c *gin.Context) {
+	if token := viper.GetString("METRICS_TOKEN"); token != "" {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Error(errors.ErrMissingToken)
+			c.Abort()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, "Bearer ")), []byte(token)) != 1 {
+			c.Error(errors.ErrInvalidToken)
+			c.Abort()
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Default.Write(&buf); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, ContentType, buf.Bytes())
+}
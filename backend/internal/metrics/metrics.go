@@ -0,0 +1,46 @@
+package metrics
+
+// Default é o registro exposto em GET /metrics
+var Default = NewRegistry()
+
+// Métricas HTTP (ver middleware.Metrics)
+var (
+	HTTPRequestDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"Latência das requisições HTTP por rota.", DefaultBuckets, "method", "route", "status")
+	HTTPRequestsTotal = Default.NewCounterVec("http_requests_total",
+		"Requisições HTTP atendidas por rota.", "method", "route", "status")
+)
+
+// Métricas de banco (ver GormPlugin)
+var (
+	DBQueryDuration = Default.NewHistogramVec("db_query_duration_seconds",
+		"Duração das queries por método de repositório.", DefaultBuckets, "repository", "method")
+	DBQueryErrors = Default.NewCounterVec("db_query_errors_total",
+		"Queries com erro (exceto registro não encontrado) por método de repositório.", "repository", "method")
+)
+
+// Métricas do cache de dados de referência (ver pacote cache)
+var (
+	CacheRequests = Default.NewCounterVec("cache_requests_total",
+		"Leituras no cache por resultado (hit ou miss).", "cache", "result")
+	CacheEvictions = Default.NewCounterVec("cache_evictions_total",
+		"Entradas removidas por falta de espaço ou expiração.", "cache")
+)
+
+// Métricas das rotinas em segundo plano (poller de rastreamento, sugestões de compra, ...)
+var (
+	JobRuns = Default.NewCounterVec("background_job_runs_total",
+		"Execuções das rotinas em segundo plano.", "job")
+	JobFailures = Default.NewCounterVec("background_job_failures_total",
+		"Execuções com erro das rotinas em segundo plano.", "job")
+	QueueDepth = Default.NewGaugeVec("queue_depth",
+		"Itens pendentes nas filas de processamento, medidos a cada execução da rotina.", "queue")
+)
+
+// ObserveJob contabiliza uma execução da rotina e, se houver erro, a falha
+func ObserveJob(job string, err error) {
+	JobRuns.Inc(job)
+	if err != nil {
+		JobFailures.Inc(job)
+	}
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestRegistryWritesPrometheusFormat(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requisições.", "route")
+	depth := r.NewGaugeVec("queue_depth", "Fila.", "queue")
+	latency := r.NewHistogramVec("latency_seconds", "Latência.", []float64{0.1, 1}, "route")
+	r.NewFuncVec("pool_connections", "Conexões.", "gauge", "state", func() map[string]float64 {
+		return map[string]float64{"idle": 2, "in_use": 1}
+	})
+
+	requests.Inc(`/a"b`)
+	requests.Add(2, "/c")
+	depth.Set(7, "tracking_poll")
+	latency.Observe(0.05, "/c")
+	latency.Observe(0.5, "/c")
+	latency.Observe(3, "/c")
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Write(&buf))
+	assert.Equal(t, `# HELP requests_total Requisições.
+# TYPE requests_total counter
+requests_total{route="/a\"b"} 1
+requests_total{route="/c"} 2
+# HELP queue_depth Fila.
+# TYPE queue_depth gauge
+queue_depth{queue="tracking_poll"} 7
+# HELP latency_seconds Latência.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/c",le="0.1"} 1
+latency_seconds_bucket{route="/c",le="1"} 2
+latency_seconds_bucket{route="/c",le="+Inf"} 3
+latency_seconds_sum{route="/c"} 3.55
+latency_seconds_count{route="/c"} 3
+# HELP pool_connections Conexões.
+# TYPE pool_connections gauge
+pool_connections{state="idle"} 2
+pool_connections{state="in_use"} 1
+`, buf.String())
+}
+
+func TestCounterPanicsOnWrongLabelCount(t *testing.T) {
+	c := NewRegistry().NewCounterVec("x_total", "X.", "a", "b")
+	assert.Panics(t, func() { c.Inc("só um") })
+}
+
+func TestObserveJob(t *testing.T) {
+	ObserveJob("test_job", nil)
+	ObserveJob("test_job", errors.New("falha"))
+
+	assert.Equal(t, 2.0, JobRuns.counts[JobRuns.key([]string{"test_job"})])
+	assert.Equal(t, 1.0, JobFailures.counts[JobFailures.key([]string{"test_job"})])
+}
+
+func TestParseRepositoryFunc(t *testing.T) {
+	tests := []struct {
+		function   string
+		repository string
+		method     string
+		ok         bool
+	}{
+		{"ERP-ONSMART/backend/internal/modules/sales/repository.(*salesProcessRepository).GetAllSalesProcesses", "sales.salesProcessRepository", "GetAllSalesProcesses", true},
+		{"ERP-ONSMART/backend/internal/modules/sales/repository.(*salesProcessRepository).GetCompleteProcessFlow.func2", "sales.salesProcessRepository", "GetCompleteProcessFlow", true},
+		{"ERP-ONSMART/backend/internal/modules/shipping/repository.(*trackingRepository).SaveEvents.func1.1", "shipping.trackingRepository", "SaveEvents", true},
+		{"ERP-ONSMART/backend/internal/modules/contact/repository.ListContacts", "contact", "ListContacts", true},
+		{"ERP-ONSMART/backend/internal/modules/sales/service.ListSalesOrders", "", "", false},
+		{"gorm.io/gorm.(*DB).Find", "", "", false},
+	}
+	for _, tt := range tests {
+		repository, method, ok := parseRepositoryFunc(tt.function)
+		assert.Equal(t, tt.ok, ok, tt.function)
+		assert.Equal(t, tt.repository, repository, tt.function)
+		assert.Equal(t, tt.method, method, tt.function)
+	}
+}
+
+type widget struct {
+	ID int
+}
+
+func TestGormPluginObservesQueries(t *testing.T) {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	opened := openedPools.Load()
+	require.NoError(t, db.Use(GormPlugin{}))
+	assert.Equal(t, opened+1, openedPools.Load())
+
+	key := DBQueryDuration.key([]string{"other", "other"})
+	before := DBQueryDuration.totals[key]
+
+	var widgets []widget
+	db.Find(&widgets)
+	db.Create(&widget{ID: 1})
+
+	// Fora de um pacote de repositório a query é contabilizada como "other"
+	assert.Equal(t, before+2, DBQueryDuration.totals[key])
+	assert.Empty(t, pools, "o plugin não deve reter o pool")
+}
+
+func TestRegisterPool(t *testing.T) {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	t.Cleanup(func() { pools = nil })
+
+	require.NoError(t, RegisterPool(db))
+	require.NoError(t, RegisterPool(db))
+	assert.Len(t, pools, 1)
+}
@@ -0,0 +1,279 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry reúne as métricas expostas no formato texto do Prometheus (versão 0.0.4)
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// NewRegistry cria um registro vazio
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write escreve todas as métricas registradas no formato de exposição do Prometheus
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector{}, r.collectors...)
+	r.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(buf)
+	}
+	return buf.Flush()
+}
+
+// series guarda os valores de uma métrica por combinação de labels
+type series struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string][]string // chave -> valores dos labels
+}
+
+func newSeries(name, help, kind string, labels []string) series {
+	return series{name: name, help: help, kind: kind, labels: labels, values: map[string][]string{}}
+}
+
+// key valida a quantidade de labels e devolve a chave da combinação
+func (s *series) key(labelValues []string) string {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("métrica %s espera %d labels, recebeu %d", s.name, len(s.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	if _, ok := s.values[key]; !ok {
+		s.values[key] = append([]string{}, labelValues...)
+	}
+	return key
+}
+
+func (s *series) sortedKeys() []string {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *series) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, s.help, s.name, s.kind)
+}
+
+// CounterVec é um contador crescente por combinação de labels
+type CounterVec struct {
+	series
+	counts map[string]float64
+}
+
+// NewCounterVec registra um contador
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{series: newSeries(name, help, "counter", labels), counts: map[string]float64{}}
+	r.register(c)
+	return c
+}
+
+// Inc soma 1 ao contador dos labels informados
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add soma v (não negativo) ao contador dos labels informados
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[c.key(labelValues)] += v
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeHeader(w)
+	for _, key := range c.sortedKeys() {
+		writeSample(w, c.name, c.labels, c.values[key], "", "", c.counts[key])
+	}
+}
+
+// GaugeVec é um valor que sobe e desce por combinação de labels
+type GaugeVec struct {
+	series
+	gauges map[string]float64
+}
+
+// NewGaugeVec registra um gauge
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{series: newSeries(name, help, "gauge", labels), gauges: map[string]float64{}}
+	r.register(g)
+	return g
+}
+
+// Set define o valor do gauge dos labels informados
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.gauges[g.key(labelValues)] = v
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.writeHeader(w)
+	for _, key := range g.sortedKeys() {
+		writeSample(w, g.name, g.labels, g.values[key], "", "", g.gauges[key])
+	}
+}
+
+// FuncVec calcula os valores no momento da coleta (ex.: estatísticas do pool de conexões)
+type FuncVec struct {
+	series
+	collect func() map[string]float64 // valor do único label -> valor
+}
+
+// NewFuncVec registra uma métrica (gauge ou counter) calculada na coleta; com label vazio,
+// collect deve devolver uma única série com a chave ""
+func (r *Registry) NewFuncVec(name, help, kind, label string, collect func() map[string]float64) *FuncVec {
+	var labels []string
+	if label != "" {
+		labels = []string{label}
+	}
+	g := &FuncVec{series: newSeries(name, help, kind, labels), collect: collect}
+	r.register(g)
+	return g
+}
+
+func (g *FuncVec) write(w *bufio.Writer) {
+	values := g.collect()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	g.writeHeader(w)
+	for _, key := range keys {
+		var labelValues []string
+		if len(g.labels) > 0 {
+			labelValues = []string{key}
+		}
+		writeSample(w, g.name, g.labels, labelValues, "", "", values[key])
+	}
+}
+
+// DefaultBuckets são os limites (em segundos) usados nas latências HTTP e de banco
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HistogramVec distribui as observações em buckets por combinação de labels
+type HistogramVec struct {
+	series
+	buckets []float64
+	counts  map[string][]uint64 // contagem por bucket (não cumulativa)
+	sums    map[string]float64
+	totals  map[string]uint64
+}
+
+// NewHistogramVec registra um histograma com os buckets informados (em ordem crescente)
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		series:  newSeries(name, help, "histogram", labels),
+		buckets: buckets,
+		counts:  map[string][]uint64{},
+		sums:    map[string]float64{},
+		totals:  map[string]uint64{},
+	}
+	r.register(h)
+	return h
+}
+
+// Observe registra uma observação nos labels informados
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := h.key(labelValues)
+	if h.counts[key] == nil {
+		h.counts[key] = make([]uint64, len(h.buckets))
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[key][i]++
+	}
+	h.sums[key] += v
+	h.totals[key]++
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(w)
+	for _, key := range h.sortedKeys() {
+		labelValues := h.values[key]
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += h.counts[key][i]
+			writeSample(w, h.name+"_bucket", h.labels, labelValues, "le", formatFloat(upper), float64(cumulative))
+		}
+		writeSample(w, h.name+"_bucket", h.labels, labelValues, "le", "+Inf", float64(h.totals[key]))
+		writeSample(w, h.name+"_sum", h.labels, labelValues, "", "", h.sums[key])
+		writeSample(w, h.name+"_count", h.labels, labelValues, "", "", float64(h.totals[key]))
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels, labelValues []string, extraLabel, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, label, labelEscaper.Replace(labelValues[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// labelEscaper aplica o escape exigido nos valores de label (barra, aspas e quebra de linha)
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
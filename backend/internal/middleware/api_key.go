@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/apikeys/models"
+	"ERP-ONSMART/backend/internal/modules/apikeys/service"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader é o header com a chave de API das integrações
+const APIKeyHeader = "X-API-Key"
+
+// authenticateAPIKey é substituído nos testes
+var authenticateAPIKey = service.Authenticate
+
+// APIKeyMiddleware autentica sistemas externos pela chave de API, separadamente das sessões JWT
+// dos usuários, e exige o escopo informado. A requisição passa a atuar na empresa da chave; um
+// header X-Company-ID de outra empresa é recusado.
+func APIKeyMiddleware(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := strings.TrimSpace(c.GetHeader(APIKeyHeader))
+		if rawKey == "" {
+			AbortWithError(c, errors.ErrMissingAPIKey)
+			return
+		}
+
+		key, err := authenticatedAPIKey(c, rawKey, scope)
+		if err != nil {
+			AbortWithError(c, errors.ToAPIError(err, "erro ao validar chave de API"))
+			return
+		}
+
+		headerID, hasHeader, err := companyFromHeader(c)
+		if err != nil {
+			AbortWithError(c, err)
+			return
+		}
+		if hasHeader && headerID != key.CompanyID {
+			AbortWithError(c, errors.ErrTenantMismatch)
+			return
+		}
+
+		c.Set("api_key", key)
+		c.Set(UserKey, "api-key:"+key.Prefix)
+		c.Set("company_id", key.CompanyID)
+		c.Request = c.Request.WithContext(tenant.WithCompany(c.Request.Context(), key.CompanyID))
+		c.Next()
+	}
+}
+
+// authenticatedAPIKey autentica a chave exigindo o escopo. Se o IdempotencyMiddleware já a
+// autenticou nesta requisição, só o escopo é conferido, sem registrar o uso duas vezes.
+func authenticatedAPIKey(c *gin.Context, rawKey, scope string) (*models.APIKey, error) {
+	if value, ok := c.Get("api_key"); ok {
+		if key, ok := value.(*models.APIKey); ok {
+			if !key.HasScope(scope) {
+				return nil, errors.ErrInsufficientScope
+			}
+			return key, nil
+		}
+	}
+	return authenticateAPIKey(c.Request.Context(), rawKey, scope, c.ClientIP())
+}
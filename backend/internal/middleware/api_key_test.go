@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/apikeys/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func apiKeyRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	original := authenticateAPIKey
+	authenticateAPIKey = func(_ context.Context, rawKey, scope, _ string) (*models.APIKey, error) {
+		if rawKey != "erp_valida" {
+			return nil, errors.ErrInvalidAPIKey
+		}
+		key := &models.APIKey{ID: 1, CompanyID: 5, Prefix: "erp_valida", Scopes: []string{models.ScopeProductsRead}}
+		if !key.HasScope(scope) {
+			return nil, errors.ErrInsufficientScope
+		}
+		return key, nil
+	}
+	t.Cleanup(func() { authenticateAPIKey = original })
+
+	router := gin.New()
+	router.GET("/products", APIKeyMiddleware(models.ScopeProductsRead), func(c *gin.Context) {
+		companyID, _ := tenant.CompanyID(c.Request.Context())
+		user, _ := c.Get("user")
+		c.JSON(http.StatusOK, gin.H{"company_id": companyID, "user": user})
+	})
+	router.GET("/contacts", APIKeyMiddleware(models.ScopeContactsRead), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func requestWithKey(router *gin.Engine, path, key, company string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if key != "" {
+		req.Header.Set(APIKeyHeader, key)
+	}
+	if company != "" {
+		req.Header.Set(CompanyHeader, company)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestAPIKeyMiddlewareAuthenticatesIntoKeyCompany(t *testing.T) {
+	router := apiKeyRouter(t)
+
+	resp := requestWithKey(router, "/products", "erp_valida", "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"company_id": 5, "user": "api-key:erp_valida"}`, resp.Body.String())
+}
+
+func TestAPIKeyMiddlewareRejections(t *testing.T) {
+	router := apiKeyRouter(t)
+
+	tests := []struct {
+		name, path, key, company, code string
+		status                         int
+	}{
+		{"sem chave", "/products", "", "", "missing_api_key", http.StatusUnauthorized},
+		{"chave inválida", "/products", "erp_outra", "", "invalid_api_key", http.StatusUnauthorized},
+		{"sem escopo", "/contacts", "erp_valida", "", "insufficient_scope", http.StatusForbidden},
+		{"outra empresa", "/products", "erp_valida", "6", "tenant_mismatch", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := requestWithKey(router, tt.path, tt.key, tt.company)
+			assert.Equal(t, tt.status, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.code)
+		})
+	}
+}
@@ -20,7 +20,7 @@ func AuditMiddleware(logger *zap.Logger) gin.HandlerFunc {
 		duration := time.Since(startTime)
 
 		// Recupera informações do usuário, se definidas (p.ex.: pelo AuthMiddleware)
-		user, exists := c.Get("user")
+		user, exists := c.Get(UserKey)
 		if !exists {
 			user = "desconhecido"
 		}
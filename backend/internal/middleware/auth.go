@@ -5,51 +5,97 @@ import (
 	"net/http"
 	"strings"
 
+	"ERP-ONSMART/backend/internal/errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/spf13/viper"
 )
 
+// Chaves do contexto do gin preenchidas pela autenticação
+const (
+	// ClaimsKey guarda as claims (jwt.MapClaims) do token validado
+	ClaimsKey = "claims"
+	// UserKey identifica quem fez a requisição nos registros de auditoria
+	UserKey = "user"
+)
+
 // AuthMiddleware verifica a presença e validade do token JWT no header Authorization.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Obtém o header Authorization
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token não fornecido"})
+			AbortWithError(c, errors.ErrMissingToken)
 			return
 		}
 
 		// Espera o formato "Bearer <token>"
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token inválido: falta o prefixo Bearer"})
+			AbortWithError(c, errors.ErrInvalidToken)
 			return
 		}
 
 		// Obtem a chave secreta do JWT a partir da configuração
 		secret := viper.GetString("JWT_SECRET")
 		if secret == "" {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "chave JWT não configurada"})
+			AbortWithError(c, errors.NewAPIError(http.StatusInternalServerError, errors.CodeInternal, "chave JWT não configurada"))
 			return
 		}
 
 		// Faz o parsing do token usando a chave secreta
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Verifica o método de assinatura HMAC
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("algoritmo inesperado: %v", token.Header["alg"])
-			}
-			return []byte(secret), nil
-		})
-
+		token, err := parseToken(tokenString, secret)
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
+			AbortWithError(c, errors.ErrInvalidToken)
+			return
+		}
+
+		// Tokens do portal do cliente só abrem as rotas /portal
+		if isPortalToken(token.Claims) {
+			AbortWithError(c, errors.ErrPortalTokenNotAllowed)
 			return
 		}
 
 		// Opcional: Você pode armazenar as claims no contexto para uso posterior
-		c.Set("claims", token.Claims)
+		c.Set(ClaimsKey, token.Claims)
 		c.Next()
 	}
 }
+
+// CurrentUsername retorna o usuário do token JWT validado pelo AuthMiddleware (claim username),
+// ou "" em rotas sem autenticação
+func CurrentUsername(c *gin.Context) string {
+	username, _ := currentClaim(c, "username")
+	return username
+}
+
+// CurrentRole retorna o perfil do token JWT validado pelo AuthMiddleware (claim role)
+func CurrentRole(c *gin.Context) string {
+	role, _ := currentClaim(c, "role")
+	return role
+}
+
+func currentClaim(c *gin.Context, name string) (string, bool) {
+	claims, ok := c.Get(ClaimsKey)
+	if !ok {
+		return "", false
+	}
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return "", false
+	}
+	value, ok := mapClaims[name].(string)
+	return value, ok
+}
+
+// parseToken valida a assinatura HMAC do token JWT
+func parseToken(tokenString, secret string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Verifica o método de assinatura HMAC
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("algoritmo inesperado: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+}
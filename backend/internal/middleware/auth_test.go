@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestAuthMiddleware_NoToken(t *testing.T) {
@@ -25,4 +26,19 @@ func TestAuthMiddleware_NoToken(t *testing.T) {
 	}
 }
 
+func TestCurrentUsernameAndRole(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if CurrentUsername(c) != "" || CurrentRole(c) != "" {
+		t.Errorf("sem token, usuário e perfil devem ser vazios")
+	}
+
+	c.Set(ClaimsKey, jwt.MapClaims{"username": "maria", "role": "admin"})
+	if got := CurrentUsername(c); got != "maria" {
+		t.Errorf("esperado maria, obtido %q", got)
+	}
+	if got := CurrentRole(c); got != "admin" {
+		t.Errorf("esperado admin, obtido %q", got)
+	}
+}
+
 // Caso queira testar com um token inválido, você pode criar outro teste similar.
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	stderrors "errors"
+	"net/http"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+var registerValidationRules sync.Once
+
+// ErrorHandler responde no envelope padrão ({"error": {code, message, details, field_errors}})
+// o último erro registrado pelos handlers com c.Error. Para erros fora do catálogo, a mensagem
+// de contexto vai no Meta: c.Error(err).SetMeta("erro ao listar leads"). O pânico de um cálculo
+// com money.Decimal sobre valores da requisição (ErrOverflow, ErrInvalid) vira 400; os demais
+// seguem para o Recovery.
+func ErrorHandler() gin.HandlerFunc {
+	// Os field_errors usam o nome JSON dos campos, e as tags `binding` ganham as regras de domínio (cpf, cnpj)
+	registerValidationRules.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			validation.Register(v)
+		}
+	})
+
+	return func(c *gin.Context) {
+		// Os middlewares seguintes (permissões de campos) trocam o writer e só o devolvem ao fim
+		// da cadeia, o que o pânico interrompe
+		writer := c.Writer
+		var moneyErr error
+		defer func() {
+			if moneyErr != nil {
+				c.Writer = writer
+				if !writer.Written() {
+					AbortWithError(c, moneyErr)
+				}
+			}
+		}()
+		defer money.Recover(&moneyErr)
+
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		last := c.Errors.Last()
+		fallback, _ := last.Meta.(string)
+		writeAPIError(c, last.Err, fallback)
+	}
+}
+
+// AbortWithError interrompe a requisição respondendo o erro no envelope padrão
+func AbortWithError(c *gin.Context, err error) {
+	writeAPIError(c, err, "")
+	c.Abort()
+}
+
+func writeAPIError(c *gin.Context, err error, fallback string) {
+	apiErr := errors.ToAPIError(err, fallback)
+	lang := i18n.Parse(c.GetHeader("Accept-Language"))
+
+	var validationErrors validator.ValidationErrors
+	if stderrors.As(err, &validationErrors) {
+		apiErr = withFieldErrors(apiErr, validationErrors, validation.Short(lang))
+	}
+
+	if apiErr.Status >= http.StatusInternalServerError {
+		logger.WithModule("error_handler").Error(apiErr.Message,
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("details", apiErr.Details))
+	}
+	c.JSON(apiErr.Status, gin.H{"error": apiErr.Localize(lang)})
+}
+
+// withFieldErrors lista os campos reprovados na validação, com mensagens no idioma do Accept-Language
+func withFieldErrors(apiErr *errors.APIError, validationErrors validator.ValidationErrors, lang string) *errors.APIError {
+	result := *apiErr
+	result.Details = ""
+	result.FieldErrors = make([]errors.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		result.FieldErrors = append(result.FieldErrors, errors.FieldError{
+			Field:   validation.FieldPath(fe),
+			Message: validation.Message(fe, lang),
+		})
+	}
+	return &result
+}
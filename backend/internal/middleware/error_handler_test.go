@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errorEnvelope struct {
+	Error struct {
+		Code        string              `json:"code"`
+		Message     string              `json:"message"`
+		Details     string              `json:"details"`
+		FieldErrors []errors.FieldError `json:"field_errors"`
+	} `json:"error"`
+}
+
+func newErrorRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	return router
+}
+
+func serveError(t *testing.T, router *gin.Engine, method, path, body string, headers ...string) (*httptest.ResponseRecorder, errorEnvelope) {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var envelope errorEnvelope
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &envelope))
+	return resp, envelope
+}
+
+func TestErrorHandlerMapsCatalogErrors(t *testing.T) {
+	router := newErrorRouter()
+	router.GET("/invoices/:id", func(c *gin.Context) {
+		c.Error(fmt.Errorf("buscando fatura %s: %w", c.Param("id"), errors.ErrInvoiceNotFound))
+	})
+
+	resp, envelope := serveError(t, router, "GET", "/invoices/7", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "invoice_not_found", envelope.Error.Code)
+	assert.Equal(t, "buscando fatura 7: fatura não encontrada", envelope.Error.Message)
+}
+
+func TestErrorHandlerTranslatesMessages(t *testing.T) {
+	router := newErrorRouter()
+	router.GET("/invoices/:id", func(c *gin.Context) {
+		c.Error(fmt.Errorf("buscando fatura %s: %w", c.Param("id"), errors.ErrInvoiceNotFound))
+	})
+
+	_, envelope := serveError(t, router, "GET", "/invoices/7", "", "Accept-Language", "en-US,en;q=0.9")
+	assert.Equal(t, "invoice_not_found", envelope.Error.Code)
+	assert.Equal(t, "Invoice not found", envelope.Error.Message)
+	assert.Equal(t, "buscando fatura 7: fatura não encontrada", envelope.Error.Details)
+}
+
+func TestErrorHandlerFallbackUsesMeta(t *testing.T) {
+	router := newErrorRouter()
+	router.GET("/leads", func(c *gin.Context) {
+		c.Error(fmt.Errorf("conexão recusada")).SetMeta("erro ao listar leads")
+	})
+
+	resp, envelope := serveError(t, router, "GET", "/leads", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, errors.CodeInternal, envelope.Error.Code)
+	assert.Equal(t, "erro ao listar leads", envelope.Error.Message)
+	assert.Equal(t, "conexão recusada", envelope.Error.Details)
+}
+
+func TestErrorHandlerFieldErrors(t *testing.T) {
+	type item struct {
+		Quantity int `json:"quantity" binding:"gt=0"`
+	}
+	type payload struct {
+		Name  string `json:"name" binding:"required"`
+		Email string `json:"email" binding:"omitempty,email"`
+		Items []item `json:"items" binding:"dive"`
+	}
+
+	router := newErrorRouter()
+	router.POST("/leads", func(c *gin.Context) {
+		var p payload
+		if err := c.ShouldBindJSON(&p); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	resp, envelope := serveError(t, router, "POST", "/leads", `{"email":"x","items":[{"quantity":0}]}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "invalid_request", envelope.Error.Code)
+	assert.Equal(t, "dados inválidos", envelope.Error.Message)
+	assert.ElementsMatch(t, []errors.FieldError{
+		{Field: "name", Message: "campo obrigatório"},
+		{Field: "email", Message: "e-mail inválido"},
+		{Field: "items[0].quantity", Message: "valor deve ser maior que 0"},
+	}, envelope.Error.FieldErrors)
+}
+
+func TestErrorHandlerMapsMoneyErrors(t *testing.T) {
+	router := newErrorRouter()
+	router.GET("/overflow", func(c *gin.Context) {
+		money.MustParse("922337203685477").MulInt(1000)
+	})
+	router.GET("/invalid", func(c *gin.Context) {
+		_, err := money.Parse(c.Query("amount"))
+		c.Error(fmt.Errorf("amount: %w", err))
+	})
+
+	resp, envelope := serveError(t, router, "GET", "/overflow", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "money_out_of_range", envelope.Error.Code)
+
+	resp, envelope = serveError(t, router, "GET", "/invalid?amount=abc", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "invalid_money_value", envelope.Error.Code)
+
+	// o writer trocado por um middleware seguinte não recebe a resposta do erro
+	buffered := router.Group("/buffered", func(c *gin.Context) {
+		c.Writer = &discardWriter{ResponseWriter: c.Writer}
+		c.Next()
+	})
+	buffered.GET("/overflow", func(c *gin.Context) {
+		money.MustParse("922337203685477").MulInt(1000)
+	})
+	resp, envelope = serveError(t, router, "GET", "/buffered/overflow", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "money_out_of_range", envelope.Error.Code)
+
+	router.GET("/bug", func(c *gin.Context) { panic("nil map") })
+	assert.PanicsWithValue(t, "nil map", func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/bug", nil))
+	}, "outros pânicos seguem para o Recovery")
+}
+
+type discardWriter struct {
+	gin.ResponseWriter
+}
+
+func (w *discardWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func TestErrorHandlerKeepsWrittenResponse(t *testing.T) {
+	router := newErrorRouter()
+	router.GET("/partial", func(c *gin.Context) {
+		c.Error(errors.ErrProductNotFound)
+		c.JSON(http.StatusAccepted, gin.H{"message": "processando"})
+	})
+
+	req, _ := http.NewRequest("GET", "/partial", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{"message":"processando"}`, resp.Body.String())
+}
+
+func TestAbortWithError(t *testing.T) {
+	router := newErrorRouter()
+	router.GET("/admin", func(c *gin.Context) {
+		AbortWithError(c, errors.ErrForbidden)
+	}, func(c *gin.Context) {
+		t.Error("handler não deveria ser executado após AbortWithError")
+	})
+
+	resp, envelope := serveError(t, router, "GET", "/admin", "")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "forbidden", envelope.Error.Code)
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/featureflags/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireFeature responde 404 (feature_disabled) nas rotas cuja feature flag está desativada.
+// Handlers que só mudam de comportamento consultam service.IsEnabled diretamente.
+func RequireFeature(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !service.IsEnabled(name) {
+			AbortWithError(c, errors.ErrFeatureDisabled)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// FieldPermissionsMiddleware remove ou mascara, nas respostas JSON, os campos restritos ao perfil
+// do usuário (custo, lucro e margem, configurados em /field-permissions). O perfil vem das claims
+// do AuthMiddleware ou, nas rotas sem autenticação obrigatória, do token enviado. Respostas de
+// outros tipos (PDF, CSV) e perfis sem restrição seguem direto para o cliente, sem buffer.
+func FieldPermissionsMiddleware(policyFor func(role string) models.Policy) gin.HandlerFunc {
+	log := logger.WithModule("field_permissions_middleware")
+
+	return func(c *gin.Context) {
+		writer := &fieldMaskWriter{ResponseWriter: c.Writer, c: c, policyFor: policyFor}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		if !writer.buffering {
+			return
+		}
+
+		body, err := writer.policy.Apply(writer.body.Bytes())
+		if err != nil {
+			// Corpo que não é um JSON válido não tem campos a restringir
+			log.Warn("resposta JSON inválida; enviada sem aplicar permissões de campos", zap.Error(err), zap.String("path", c.FullPath()))
+			body = writer.body.Bytes()
+		}
+		writer.Header().Del("Content-Length")
+		if _, err := writer.ResponseWriter.Write(body); err != nil {
+			log.Warn("erro ao enviar resposta com permissões de campos", zap.Error(err))
+		}
+	}
+}
+
+// fieldMaskWriter decide na primeira escrita se o corpo precisa passar pela política do perfil;
+// nesse caso guarda o corpo para reescrevê-lo ao fim da requisição
+type fieldMaskWriter struct {
+	gin.ResponseWriter
+	c         *gin.Context
+	policyFor func(role string) models.Policy
+
+	decided   bool
+	buffering bool
+	policy    models.Policy
+	body      bytes.Buffer
+}
+
+func (w *fieldMaskWriter) Write(data []byte) (int, error) {
+	if w.decide() {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *fieldMaskWriter) WriteString(s string) (int, error) {
+	if w.decide() {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush em respostas com buffer é adiado até o fim da requisição
+func (w *fieldMaskWriter) Flush() {
+	if !w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *fieldMaskWriter) decide() bool {
+	if w.decided {
+		return w.buffering
+	}
+	w.decided = true
+	if !strings.Contains(w.Header().Get("Content-Type"), "json") {
+		return false
+	}
+	w.policy = w.policyFor(requestRole(w.c))
+	w.buffering = len(w.policy) > 0
+	return w.buffering
+}
+
+// requestRole retorna o perfil das claims validadas pelo AuthMiddleware ou, na falta delas, do
+// token enviado na requisição
+func requestRole(c *gin.Context) string {
+	var claims jwt.MapClaims
+	if value, ok := c.Get(ClaimsKey); ok {
+		claims, _ = value.(jwt.MapClaims)
+	} else if parsed, ok := tokenClaims(c); ok {
+		claims = parsed
+	}
+	role, _ := claims["role"].(string)
+	return role
+}
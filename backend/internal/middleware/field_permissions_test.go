@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFieldPermissionsRouter(role string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(FieldPermissionsMiddleware(func(r string) models.Policy {
+		if r == "sales_user" {
+			return models.Policy{"profit": models.ActionHide, "cost_price": models.ActionMask}
+		}
+		return nil
+	}))
+	auth := func(c *gin.Context) {
+		c.Set("claims", jwt.MapClaims{"role": role})
+		c.Next()
+	}
+	router.GET("/sales/1", auth, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": 1, "profit": 40.5, "items": []gin.H{{"cost_price": 10}}})
+	})
+	router.GET("/sales/1/export", auth, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/csv", []byte("id;profit\n1;40.5\n"))
+	})
+	return router
+}
+
+func TestFieldPermissionsMiddlewareAppliesRolePolicy(t *testing.T) {
+	resp := httptest.NewRecorder()
+	newFieldPermissionsRouter("sales_user").ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/sales/1", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"id":1,"items":[{"cost_price":null}]}`, resp.Body.String())
+}
+
+func TestFieldPermissionsMiddlewareWithoutRestriction(t *testing.T) {
+	resp := httptest.NewRecorder()
+	newFieldPermissionsRouter("admin").ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/sales/1", nil))
+
+	assert.JSONEq(t, `{"id":1,"profit":40.5,"items":[{"cost_price":10}]}`, resp.Body.String())
+}
+
+func TestFieldPermissionsMiddlewareSkipsNonJSON(t *testing.T) {
+	resp := httptest.NewRecorder()
+	newFieldPermissionsRouter("sales_user").ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/sales/1/export", nil))
+
+	assert.Equal(t, "id;profit\n1;40.5\n", resp.Body.String())
+}
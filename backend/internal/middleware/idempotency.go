@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/idempotency/models"
+	"ERP-ONSMART/backend/internal/modules/idempotency/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Headers do controle de idempotência
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotencyMiddleware evita documentos duplicados em reenvios de POST/PUT/PATCH com o header
+// Idempotency-Key: a primeira resposta fica gravada e é devolvida nas requisições repetidas da
+// mesma empresa e do mesmo usuário ou chave de API. Requisições sem o header, ou com credenciais
+// inválidas (recusadas depois pela rota), seguem sem o controle.
+//
+// Deve ser registrado depois do TenantMiddleware, que define a empresa, e antes do ErrorHandler,
+// para que a resposta gravada inclua os erros escritos por ele.
+func IdempotencyMiddleware(newRepo func() (repository.IdempotencyRepository, error)) gin.HandlerFunc {
+	log := logger.WithModule("idempotency_middleware")
+
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if key == "" || !isIdempotentMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > models.MaxKeyLength {
+			AbortWithError(c, errors.ErrInvalidIdempotencyKey)
+			return
+		}
+
+		ctx, caller, ok := idempotencyCaller(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// Lê o corpo para compor o hash e o devolve para o handler
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				AbortWithError(c, errors.InvalidRequest(err))
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		repo, err := newRepo()
+		if err != nil {
+			AbortWithError(c, errors.ToAPIError(err, "erro ao verificar chave de idempotência"))
+			return
+		}
+
+		record := models.NewIdempotencyKey(key, caller, c.Request.Method, c.Request.URL.Path, body, time.Now(), idempotencyTTL())
+		existing, err := repo.Reserve(ctx, record)
+		if err != nil {
+			AbortWithError(c, errors.ToAPIError(err, "erro ao verificar chave de idempotência"))
+			return
+		}
+		if existing != nil {
+			replayIdempotentResponse(c, existing, record.RequestHash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			// Falhas do servidor (ou pânico no handler) liberam a chave para uma nova tentativa
+			if !completed {
+				if err := repo.Release(ctx, record); err != nil {
+					log.Warn("erro ao liberar chave de idempotência", zap.Error(err), zap.String("key", key))
+				}
+			}
+		}()
+
+		c.Next()
+
+		// Credenciais recusadas pela rota não consomem a chave
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusUnauthorized || status == http.StatusForbidden {
+			return
+		}
+		record.Complete(status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err := repo.Complete(ctx, record); err != nil {
+			log.Warn("erro ao gravar resposta da chave de idempotência", zap.Error(err), zap.String("key", key))
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyCaller identifica quem envia a requisição com as mesmas credenciais conferidas pelo
+// APIKeyMiddleware e pelo AuthMiddleware, e retorna o contexto com a empresa da chave. Chaves de API
+// valem pelo ID e atuam na empresa da chave; tokens valem pelo usuário (ou pelo contato, no portal).
+// Sem credenciais o caller é anônimo; ok é false quando a credencial enviada é inválida.
+func idempotencyCaller(c *gin.Context) (ctx context.Context, caller string, ok bool) {
+	ctx = c.Request.Context()
+
+	if rawKey := strings.TrimSpace(c.GetHeader(APIKeyHeader)); rawKey != "" {
+		apiKey, err := authenticateAPIKey(ctx, rawKey, "", c.ClientIP())
+		if err != nil {
+			return ctx, "", false
+		}
+		// O APIKeyMiddleware reaproveita a chave autenticada e só confere o escopo
+		c.Set("api_key", apiKey)
+		return tenant.WithCompany(ctx, apiKey.CompanyID), fmt.Sprintf("api-key:%d", apiKey.ID), true
+	}
+
+	if c.GetHeader("Authorization") == "" {
+		return ctx, "", true
+	}
+	claims, valid := tokenClaims(c)
+	if !valid {
+		return ctx, "", false
+	}
+	if username, _ := claims["username"].(string); username != "" {
+		return ctx, "user:" + username, true
+	}
+	if subject, _ := claims["sub"].(string); subject != "" {
+		return ctx, subject, true
+	}
+	return ctx, "", false
+}
+
+// replayIdempotentResponse responde a uma requisição repetida com a resposta gravada
+func replayIdempotentResponse(c *gin.Context, existing *models.IdempotencyKey, requestHash string) {
+	switch {
+	case !existing.Matches(requestHash):
+		AbortWithError(c, errors.ErrIdempotencyKeyMismatch)
+	case existing.Status != models.StatusCompleted:
+		AbortWithError(c, errors.ErrIdempotencyKeyInUse)
+	default:
+		c.Header(IdempotencyReplayedHeader, "true")
+		contentType := existing.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		c.Data(existing.StatusCode, contentType, existing.ResponseBody)
+		c.Abort()
+	}
+}
+
+// idempotencyTTL retorna por quanto tempo as chaves são mantidas (IDEMPOTENCY_KEY_TTL)
+func idempotencyTTL() time.Duration {
+	if ttl := viper.GetDuration("IDEMPOTENCY_KEY_TTL"); ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+func isIdempotentMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// responseRecorder copia o corpo da resposta enquanto ela é enviada ao cliente
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
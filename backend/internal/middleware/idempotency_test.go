@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/idempotency/models"
+	"ERP-ONSMART/backend/internal/modules/idempotency/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotencyRepository guarda as chaves em memória para os testes
+type memoryIdempotencyRepository struct {
+	mu     sync.Mutex
+	nextID int
+	keys   map[string]*models.IdempotencyKey
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{keys: map[string]*models.IdempotencyKey{}}
+}
+
+// recordID reproduz a restrição única da tabela: empresa, caller, chave, método e rota
+func recordID(ctx context.Context, key *models.IdempotencyKey) string {
+	companyID, _ := tenant.CompanyID(ctx)
+	return fmt.Sprintf("%d|%s|%s|%s|%s", companyID, key.Caller, key.Key, key.Method, key.Path)
+}
+
+func (r *memoryIdempotencyRepository) Reserve(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := recordID(ctx, key)
+	if existing, ok := r.keys[id]; ok {
+		found := *existing
+		return &found, nil
+	}
+	r.nextID++
+	key.ID = r.nextID
+	stored := *key
+	r.keys[id] = &stored
+	return nil, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(ctx context.Context, key *models.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *key
+	r.keys[recordID(ctx, key)] = &stored
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Release(ctx context.Context, key *models.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, recordID(ctx, key))
+	return nil
+}
+
+func newIdempotencyRouter(t *testing.T, repo *memoryIdempotencyRepository, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	viper.Set("JWT_SECRET", tenantTestSecret)
+	viper.Set("DEFAULT_COMPANY_ID", 1)
+	t.Cleanup(func() {
+		viper.Set("JWT_SECRET", nil)
+		viper.Set("DEFAULT_COMPANY_ID", nil)
+	})
+
+	router := gin.New()
+	router.Use(TenantMiddleware())
+	router.Use(IdempotencyMiddleware(func() (repository.IdempotencyRepository, error) { return repo, nil }))
+	router.POST("/invoices", handler)
+	return router
+}
+
+func sendWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	return sendWithKeyAs(router, "", key, body)
+}
+
+// sendWithKeyAs envia a requisição autenticada com o token informado
+func sendWithKeyAs(router *gin.Engine, token, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/invoices", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	created := 0
+	router := newIdempotencyRouter(t, newMemoryIdempotencyRepository(), func(c *gin.Context) {
+		created++
+		c.JSON(http.StatusCreated, gin.H{"invoice_no": "INV-2025-000001"})
+	})
+
+	first := sendWithKey(router, "abc-123", `{"sales_order_id":1}`)
+	second := sendWithKey(router, "abc-123", `{"sales_order_id":1}`)
+
+	assert.Equal(t, 1, created)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(IdempotencyReplayedHeader))
+	assert.Empty(t, first.Header().Get(IdempotencyReplayedHeader))
+}
+
+func TestIdempotencyWithoutHeader(t *testing.T) {
+	created := 0
+	router := newIdempotencyRouter(t, newMemoryIdempotencyRepository(), func(c *gin.Context) {
+		created++
+		c.Status(http.StatusCreated)
+	})
+
+	sendWithKey(router, "", `{}`)
+	sendWithKey(router, "", `{}`)
+	assert.Equal(t, 2, created)
+}
+
+func TestIdempotencyKeyReusedWithDifferentBody(t *testing.T) {
+	router := newIdempotencyRouter(t, newMemoryIdempotencyRepository(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	sendWithKey(router, "abc-123", `{"sales_order_id":1}`)
+	resp := sendWithKey(router, "abc-123", `{"sales_order_id":2}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestIdempotencyKeyStillProcessing(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	repo.Reserve(tenant.WithCompany(context.Background(), 1), models.NewIdempotencyKey("abc-123", "", http.MethodPost, "/invoices", []byte(`{}`), time.Now(), idempotencyTTL()))
+
+	router := newIdempotencyRouter(t, repo, func(c *gin.Context) {
+		t.Fatal("o handler não deve ser executado enquanto a chave está em processamento")
+	})
+	resp := sendWithKey(router, "abc-123", `{}`)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	calls := 0
+	router := newIdempotencyRouter(t, newMemoryIdempotencyRepository(), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha temporária"})
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, sendWithKey(router, "abc-123", `{}`).Code)
+	assert.Equal(t, http.StatusCreated, sendWithKey(router, "abc-123", `{}`).Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyRejectsLongKey(t *testing.T) {
+	router := newIdempotencyRouter(t, newMemoryIdempotencyRepository(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	resp := sendWithKey(router, strings.Repeat("k", models.MaxKeyLength+1), `{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestIdempotencyKeyIsScopedByUserAndCompany(t *testing.T) {
+	created := 0
+	router := newIdempotencyRouter(t, newMemoryIdempotencyRepository(), func(c *gin.Context) {
+		created++
+		c.JSON(http.StatusCreated, gin.H{"invoice_no": fmt.Sprintf("INV-2025-%06d", created)})
+	})
+
+	first := sendWithKeyAs(router, tenantToken(t, "user", 1), "abc-123", `{}`)
+	otherCompany := sendWithKeyAs(router, tenantToken(t, "user", 2), "abc-123", `{}`)
+	anonymous := sendWithKeyAs(router, "", "abc-123", `{}`)
+	replayed := sendWithKeyAs(router, tenantToken(t, "user", 1), "abc-123", `{}`)
+
+	assert.Equal(t, 3, created)
+	assert.NotEqual(t, first.Body.String(), otherCompany.Body.String())
+	assert.NotEqual(t, first.Body.String(), anonymous.Body.String())
+	assert.Equal(t, first.Body.String(), replayed.Body.String())
+	assert.Equal(t, "true", replayed.Header().Get(IdempotencyReplayedHeader))
+}
+
+func TestIdempotencyIgnoresInvalidToken(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	router := newIdempotencyRouter(t, repo, func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	sendWithKeyAs(router, "token-invalido", "abc-123", `{}`)
+	sendWithKeyAs(router, "token-invalido", "abc-123", `{}`)
+	assert.Empty(t, repo.keys)
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"ERP-ONSMART/backend/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LanguageMiddleware define o idioma da requisição pelo header Accept-Language (pt-BR, padrão,
+// ou en-US) e o coloca no contexto (i18n.WithLanguage), de onde os serviços traduzem rótulos e
+// documentos. O idioma escolhido volta no header Content-Language.
+func LanguageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Parse(c.GetHeader("Accept-Language"))
+		c.Set("language", lang)
+		c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+		c.Header("Content-Language", lang)
+		c.Next()
+	}
+}
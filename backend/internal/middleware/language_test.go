@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(LanguageMiddleware())
+	router.GET("/language", func(c *gin.Context) {
+		c.String(http.StatusOK, i18n.FromContext(c.Request.Context()))
+	})
+
+	cases := map[string]string{
+		"":                     i18n.PtBR,
+		"en-US,en;q=0.9":       i18n.EnUS,
+		"es-ES,pt-BR;q=0.8":    i18n.PtBR,
+		"pt-BR;q=0.5,en;q=0.9": i18n.EnUS,
+	}
+	for header, want := range cases {
+		req, _ := http.NewRequest("GET", "/language", nil)
+		req.Header.Set("Accept-Language", header)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, want, resp.Body.String(), header)
+		assert.Equal(t, want, resp.Header().Get("Content-Language"), header)
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"ERP-ONSMART/backend/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics registra a latência e o total de requisições por método, rota e status (GET /metrics).
+// A rota é o padrão registrado no Gin (ex.: /sales-orders/:id), para manter a cardinalidade baixa.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), c.Request.Method, route, status)
+		metrics.HTTPRequestsTotal.Inc(c.Request.Method, route, status)
+	}
+}
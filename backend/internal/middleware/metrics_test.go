@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRecordsRoutePattern(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics())
+	router.Use(ErrorHandler())
+	router.GET("/metrics-test/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/metrics", metrics.ScrapeHandler)
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/nao-existe"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, metrics.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `http_requests_total{method="GET",route="/metrics-test/:id",status="204"} 2`)
+	assert.Contains(t, w.Body.String(), `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, w.Body.String(), `http_request_duration_seconds_count{method="GET",route="/metrics-test/:id",status="204"} 2`)
+}
+
+func TestMetricsHandlerRequiresToken(t *testing.T) {
+	viper.Set("METRICS_TOKEN", "segredo")
+	defer viper.Set("METRICS_TOKEN", "")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/metrics", metrics.ScrapeHandler)
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"sem token", "", http.StatusUnauthorized},
+		{"token errado", "Bearer outro", http.StatusUnauthorized},
+		{"token correto", "Bearer segredo", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
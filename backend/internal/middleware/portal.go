@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+	portal "ERP-ONSMART/backend/internal/modules/portal/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+)
+
+// PortalAuthMiddleware autentica o cliente pelo token do portal (claim scope=portal), separado
+// das sessões dos usuários: tokens internos são recusados aqui e os do portal no AuthMiddleware.
+// A requisição passa a atuar na empresa do token, e o contato fica em "contact_id".
+func PortalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			AbortWithError(c, errors.ErrMissingToken)
+			return
+		}
+		tokenString, ok := strings.CutPrefix(authHeader, "Bearer ")
+		if !ok {
+			AbortWithError(c, errors.ErrInvalidToken)
+			return
+		}
+
+		secret := viper.GetString("JWT_SECRET")
+		if secret == "" {
+			AbortWithError(c, errors.NewAPIError(http.StatusInternalServerError, errors.CodeInternal, "chave JWT não configurada"))
+			return
+		}
+
+		token, err := parseToken(tokenString, secret)
+		if err != nil || !token.Valid {
+			AbortWithError(c, errors.ErrInvalidToken)
+			return
+		}
+		if !isPortalToken(token.Claims) {
+			AbortWithError(c, errors.ErrPortalTokenRequired)
+			return
+		}
+
+		claims := token.Claims.(jwt.MapClaims)
+		contactID, hasContact := claims["contact_id"].(float64)
+		companyID, hasCompany := companyFromClaims(claims)
+		if !hasContact || contactID <= 0 || !hasCompany {
+			AbortWithError(c, errors.ErrInvalidToken)
+			return
+		}
+
+		c.Set(ClaimsKey, claims)
+		c.Set("contact_id", int(contactID))
+		c.Set(UserKey, fmt.Sprintf("portal:contact:%d", int(contactID)))
+		c.Set("company_id", companyID)
+		c.Request = c.Request.WithContext(tenant.WithCompany(c.Request.Context(), companyID))
+		c.Next()
+	}
+}
+
+// isPortalToken indica se o token foi emitido para o portal do cliente
+func isPortalToken(claims jwt.Claims) bool {
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	scope, _ := mapClaims["scope"].(string)
+	return scope == portal.TokenScope
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func portalRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	viper.Set("JWT_SECRET", tenantTestSecret)
+	t.Cleanup(func() { viper.Set("JWT_SECRET", nil) })
+
+	router := gin.New()
+	router.GET("/portal/me", PortalAuthMiddleware(), func(c *gin.Context) {
+		companyID, _ := tenant.CompanyID(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"contact_id": c.GetInt("contact_id"), "company_id": companyID})
+	})
+	router.GET("/internal", AuthMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "acessado"})
+	})
+	return router
+}
+
+func portalToken(t *testing.T, contactID, companyID int) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"scope":      "portal",
+		"contact_id": contactID,
+		"company_id": companyID,
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(tenantTestSecret))
+	require.NoError(t, err)
+	return signed
+}
+
+func portalRequest(router *gin.Engine, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestPortalAuthMiddlewareScopesContactAndCompany(t *testing.T) {
+	router := portalRouter(t)
+
+	resp := portalRequest(router, "/portal/me", portalToken(t, 42, 3))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"contact_id": 42, "company_id": 3}`, resp.Body.String())
+}
+
+func TestPortalAuthMiddlewareRejectsInternalToken(t *testing.T) {
+	router := portalRouter(t)
+
+	resp := portalRequest(router, "/portal/me", tenantToken(t, "admin", 1))
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Contains(t, resp.Body.String(), "portal_token_required")
+
+	assert.Equal(t, http.StatusUnauthorized, portalRequest(router, "/portal/me", "").Code)
+}
+
+func TestAuthMiddlewareRejectsPortalToken(t *testing.T) {
+	router := portalRouter(t)
+
+	resp := portalRequest(router, "/internal", portalToken(t, 42, 3))
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Contains(t, resp.Body.String(), "portal_token_not_allowed")
+
+	assert.Equal(t, http.StatusOK, portalRequest(router, "/internal", tenantToken(t, "Colaborador", 1)).Code)
+}
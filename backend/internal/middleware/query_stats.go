@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/db/querystats"
+	"ERP-ONSMART/backend/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Headers com as métricas de banco da requisição
+const (
+	QueryCountHeader   = "X-Query-Count"
+	ServerTimingHeader = "Server-Timing"
+)
+
+// DefaultQueryBudget é o limite de queries por requisição quando QUERY_BUDGET não é definido
+const DefaultQueryBudget = 50
+
+// QueryStats conta as queries e o tempo gasto no banco em cada requisição. As queries precisam
+// usar o contexto da requisição (db.WithContext(c.Request.Context())) para serem contabilizadas.
+// As métricas vão nos headers X-Query-Count e Server-Timing e no log; requisições acima do
+// orçamento (QUERY_BUDGET) geram um aviso, para que regressões do tipo N+1 apareçam cedo.
+func QueryStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, stats := querystats.NewContext(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &queryStatsWriter{ResponseWriter: c.Writer, stats: stats}
+
+		c.Next()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()),
+			zap.Int("status", c.Writer.Status()),
+			zap.Int64("queries", stats.Queries()),
+			zap.Duration("db_duration", stats.Duration()),
+		}
+		log := logger.WithModule("query_stats")
+		if budget := queryBudget(); stats.Queries() > budget {
+			log.Warn("orçamento de queries excedido", append(fields, zap.Int64("budget", budget))...)
+			return
+		}
+		log.Debug("queries da requisição", fields...)
+	}
+}
+
+// queryBudget retorna o máximo de queries esperado por requisição (QUERY_BUDGET)
+func queryBudget() int64 {
+	if budget := viper.GetInt64("QUERY_BUDGET"); budget > 0 {
+		return budget
+	}
+	return DefaultQueryBudget
+}
+
+// queryStatsWriter grava os headers de métricas antes de o status e o corpo serem enviados
+type queryStatsWriter struct {
+	gin.ResponseWriter
+	stats *querystats.Stats
+}
+
+func (w *queryStatsWriter) WriteHeaderNow() {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *queryStatsWriter) Write(data []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *queryStatsWriter) WriteString(s string) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *queryStatsWriter) setHeaders() {
+	if w.Written() {
+		return
+	}
+	queries := w.stats.Queries()
+	millis := float64(w.stats.Duration().Microseconds()) / 1000
+	w.Header().Set(QueryCountHeader, strconv.FormatInt(queries, 10))
+	w.Header().Set(ServerTimingHeader, fmt.Sprintf(`db;dur=%.2f;desc="%d queries"`, millis, queries))
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/db/querystats"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type queryStatsRow struct {
+	ID int
+}
+
+func TestQueryStatsHeaders(t *testing.T) {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(querystats.Plugin{}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(QueryStats())
+	router.Use(ErrorHandler())
+	router.GET("/rows", func(c *gin.Context) {
+		var rows []queryStatsRow
+		for i := 0; i < 3; i++ {
+			db.WithContext(c.Request.Context()).Find(&rows)
+		}
+		// Queries sem o contexto da requisição não entram na conta
+		db.Find(&rows)
+		c.JSON(http.StatusOK, gin.H{"rows": rows})
+	})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/rows", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "3", resp.Header().Get(QueryCountHeader))
+	assert.Contains(t, resp.Header().Get(ServerTimingHeader), `desc="3 queries"`)
+}
+
+func TestQueryStatsHeadersOnErrorResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(QueryStats())
+	router.Use(ErrorHandler())
+	router.GET("/missing", func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusNotFound)
+	})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "0", resp.Header().Get(QueryCountHeader))
+}
@@ -1,9 +1,10 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
+	"ERP-ONSMART/backend/internal/errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -12,23 +13,23 @@ import (
 func RBACMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Obtém as claims definidas pelo middleware de autenticação.
-		claims, exists := c.Get("claims")
+		claims, exists := c.Get(ClaimsKey)
 		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "claims não encontrados"})
+			AbortWithError(c, errors.ErrMissingToken)
 			return
 		}
 
 		// Faz a conversão das claims para o tipo jwt.MapClaims
 		mapClaims, ok := claims.(jwt.MapClaims)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "formato de claims inválido"})
+			AbortWithError(c, errors.ErrInvalidToken)
 			return
 		}
 
 		// Presume que a role do usuário esteja armazenada na claim "role"
 		userRole, exists := mapClaims["role"].(string)
 		if !exists || userRole == "" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "função do usuário não definida"})
+			AbortWithError(c, errors.ErrForbidden)
 			return
 		}
 
@@ -42,7 +43,7 @@ func RBACMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		}
 
 		if !authorized {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "acesso negado: permissões insuficientes"})
+			AbortWithError(c, errors.ErrForbidden)
 			return
 		}
 
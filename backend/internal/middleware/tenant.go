@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+)
+
+// CompanyHeader permite escolher a empresa da requisição
+const CompanyHeader = "X-Company-ID"
+
+// TenantMiddleware define a empresa da requisição e a coloca no contexto (tenant.WithCompany),
+// de onde o tenant.Plugin a aplica nas queries. A empresa vem da claim company_id do token; o
+// header X-Company-ID só pode apontar outra empresa para administradores. Sem token, vale o
+// header e, na falta dele, DEFAULT_COMPANY_ID. Sem empresa (DEFAULT_COMPANY_ID=0) a requisição
+// segue sem tenant e, com TENANT_STRICT (padrão), as queries das tabelas por empresa falham com tenant_required.
+func TenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		headerID, hasHeader, err := companyFromHeader(c)
+		if err != nil {
+			AbortWithError(c, err)
+			return
+		}
+
+		companyID := headerID
+		if claims, ok := tokenClaims(c); ok {
+			claimID, hasClaim := companyFromClaims(claims)
+			role, _ := claims["role"].(string)
+			switch {
+			case hasClaim && !hasHeader:
+				companyID = claimID
+			case hasClaim && headerID != claimID && !strings.EqualFold(role, "admin"):
+				AbortWithError(c, errors.ErrTenantMismatch)
+				return
+			}
+		}
+
+		if companyID == 0 {
+			companyID = viper.GetInt("DEFAULT_COMPANY_ID")
+		}
+		if companyID <= 0 {
+			c.Next()
+			return
+		}
+
+		c.Set("company_id", companyID)
+		c.Request = c.Request.WithContext(tenant.WithCompany(c.Request.Context(), companyID))
+		c.Next()
+	}
+}
+
+func companyFromHeader(c *gin.Context) (int, bool, error) {
+	value := strings.TrimSpace(c.GetHeader(CompanyHeader))
+	if value == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, false, errors.InvalidParam(CompanyHeader + " deve ser um número inteiro positivo")
+	}
+	return id, true, nil
+}
+
+// tokenClaims lê as claims de um token válido; tokens ausentes ou inválidos ficam a cargo do AuthMiddleware
+func tokenClaims(c *gin.Context) (jwt.MapClaims, bool) {
+	tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	secret := viper.GetString("JWT_SECRET")
+	if !ok || secret == "" {
+		return nil, false
+	}
+	token, err := parseToken(tokenString, secret)
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	return claims, ok
+}
+
+func companyFromClaims(claims jwt.MapClaims) (int, bool) {
+	// Números das claims JSON chegam como float64
+	id, ok := claims["company_id"].(float64)
+	if !ok || id <= 0 {
+		return 0, false
+	}
+	return int(id), true
+}
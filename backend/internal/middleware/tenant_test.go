@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tenantTestSecret = "tenant-test-secret"
+
+func tenantRouter(t *testing.T, defaultCompany int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	viper.Set("JWT_SECRET", tenantTestSecret)
+	viper.Set("DEFAULT_COMPANY_ID", defaultCompany)
+	t.Cleanup(func() {
+		viper.Set("JWT_SECRET", nil)
+		viper.Set("DEFAULT_COMPANY_ID", nil)
+	})
+
+	router := gin.New()
+	router.Use(TenantMiddleware())
+	router.GET("/company", func(c *gin.Context) {
+		companyID, ok := tenant.CompanyID(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"company_id": companyID, "scoped": ok})
+	})
+	return router
+}
+
+func tenantToken(t *testing.T, role string, companyID int) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"username":   "tenant_user",
+		"role":       role,
+		"company_id": companyID,
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(tenantTestSecret))
+	require.NoError(t, err)
+	return signed
+}
+
+func requestCompany(router *gin.Engine, token, header string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/company", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if header != "" {
+		req.Header.Set(CompanyHeader, header)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestTenantMiddlewareUsesTokenClaim(t *testing.T) {
+	router := tenantRouter(t, 1)
+
+	resp := requestCompany(router, tenantToken(t, "Colaborador", 7), "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"company_id": 7, "scoped": true}`, resp.Body.String())
+}
+
+func TestTenantMiddlewareRejectsHeaderOfAnotherCompany(t *testing.T) {
+	router := tenantRouter(t, 1)
+
+	resp := requestCompany(router, tenantToken(t, "Colaborador", 7), "8")
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Contains(t, resp.Body.String(), "tenant_mismatch")
+}
+
+func TestTenantMiddlewareLetsAdminSwitchCompany(t *testing.T) {
+	router := tenantRouter(t, 1)
+
+	resp := requestCompany(router, tenantToken(t, "admin", 7), "8")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"company_id": 8, "scoped": true}`, resp.Body.String())
+}
+
+func TestTenantMiddlewareWithoutToken(t *testing.T) {
+	router := tenantRouter(t, 1)
+
+	resp := requestCompany(router, "", "3")
+	assert.JSONEq(t, `{"company_id": 3, "scoped": true}`, resp.Body.String())
+
+	resp = requestCompany(router, "", "")
+	assert.JSONEq(t, `{"company_id": 1, "scoped": true}`, resp.Body.String())
+}
+
+func TestTenantMiddlewareWithoutDefaultCompany(t *testing.T) {
+	router := tenantRouter(t, 0)
+
+	resp := requestCompany(router, "", "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"company_id": 0, "scoped": false}`, resp.Body.String())
+}
+
+func TestTenantMiddlewareRejectsInvalidHeader(t *testing.T) {
+	router := tenantRouter(t, 1)
+
+	resp := requestCompany(router, "", "abc")
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -4,9 +4,11 @@ import (
 	"net/http"
 	"strconv"
 
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/logger"
 	"ERP-ONSMART/backend/internal/modules/accounting/models"
 	"ERP-ONSMART/backend/internal/modules/accounting/service"
+	"ERP-ONSMART/backend/internal/utils/validation"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -17,72 +19,77 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	validation.Register(validate)
 }
 
+// Lista as transações financeiras
 func ListTransactionsHandler(c *gin.Context) {
-	transactions, err := service.ListTransactions()
+	transactions, err := service.ListTransactions(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": transactions})
 }
 
+// Registra uma transação financeira
 func CreateTransactionHandler(c *gin.Context) {
 	var trans models.Transaction
 	if err := c.ShouldBindJSON(&trans); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 	if err := validate.Struct(trans); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
-	created, err := service.AddTransaction(trans)
+	created, err := service.AddTransaction(c.Request.Context(), trans)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	logger.Logger.Info("Transação criada", zap.Int("id", created.ID))
 	c.JSON(http.StatusCreated, created)
 }
 
+// Atualiza uma transação financeira
 func UpdateTransactionHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 	var trans models.Transaction
 	if err := c.ShouldBindJSON(&trans); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 	if err := validate.Struct(trans); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
-	updated, err := service.ModifyTransaction(id, trans)
+	updated, err := service.ModifyTransaction(c.Request.Context(), id, trans)
 	if err != nil {
 		// Se o erro for de linha não encontrada, responde com 404, senão com 500
 		if err.Error() == "sql: no rows in result set" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Transação não encontrada"})
+			c.Error(errors.ErrTransactionNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 		}
 		return
 	}
 	c.JSON(http.StatusOK, updated)
 }
 
+// Remove uma transação financeira
 func DeleteTransactionHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
-	if err := service.RemoveTransaction(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao deletar transação", "details": err.Error()})
+	if err := service.RemoveTransaction(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao deletar transação")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Transação deletado com sucesso"})
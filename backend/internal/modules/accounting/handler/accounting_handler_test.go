@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
 	"encoding/json"
 	"net/http"
@@ -36,6 +37,8 @@ func TestMain(m *testing.M) {
 func TestCreateTransactionHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.POST("/accounting", CreateTransactionHandler)
 
 	body := []byte(`{
@@ -45,6 +48,7 @@ func TestCreateTransactionHandler(t *testing.T) {
 	}`)
 
 	req, _ := http.NewRequest("POST", "/accounting", bytes.NewBuffer(body))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
@@ -57,9 +61,12 @@ func TestCreateTransactionHandler(t *testing.T) {
 func TestListTransactionsHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.GET("/accounting", ListTransactionsHandler)
 
 	req, _ := http.NewRequest("GET", "/accounting", nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
@@ -84,6 +91,8 @@ func TestListTransactionsHandler(t *testing.T) {
 func TestUpdateTransactionHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	// Cria as rotas necessárias
 	router.POST("/accounting", CreateTransactionHandler)
 	router.PUT("/accounting/:id", UpdateTransactionHandler)
@@ -96,6 +105,7 @@ func TestUpdateTransactionHandler(t *testing.T) {
 		"date": "08/04/2025"
 	}`)
 	req, _ := http.NewRequest("POST", "/accounting", bytes.NewBuffer(createBody))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	createResp := httptest.NewRecorder()
 	router.ServeHTTP(createResp, req)
@@ -106,6 +116,7 @@ func TestUpdateTransactionHandler(t *testing.T) {
 	// Recupera o ID da transação criada via GET
 	listResp := httptest.NewRecorder()
 	reqList, _ := http.NewRequest("GET", "/accounting", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	router.ServeHTTP(listResp, reqList)
 	var result struct {
 		Data []struct {
@@ -127,6 +138,7 @@ func TestUpdateTransactionHandler(t *testing.T) {
 		"date": "09/04/2025"
 	}`)
 	reqUpdate, _ := http.NewRequest("PUT", "/accounting/"+strconv.Itoa(id), bytes.NewBuffer(updateBody))
+	reqUpdate.Header.Set(middleware.CompanyHeader, "1")
 	reqUpdate.Header.Set("Content-Type", "application/json")
 	updateResp := httptest.NewRecorder()
 	router.ServeHTTP(updateResp, reqUpdate)
@@ -138,6 +150,8 @@ func TestUpdateTransactionHandler(t *testing.T) {
 func TestDeleteTransactionHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	// Cria as rotas necessárias
 	router.POST("/accounting", CreateTransactionHandler)
 	router.DELETE("/accounting/:id", DeleteTransactionHandler)
@@ -150,6 +164,7 @@ func TestDeleteTransactionHandler(t *testing.T) {
 		"date": "08/04/2025"
 	}`)
 	req, _ := http.NewRequest("POST", "/accounting", bytes.NewBuffer(body))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	createResp := httptest.NewRecorder()
 	router.ServeHTTP(createResp, req)
@@ -160,6 +175,7 @@ func TestDeleteTransactionHandler(t *testing.T) {
 	// Recupera o ID da última transação criada
 	listResp := httptest.NewRecorder()
 	reqList, _ := http.NewRequest("GET", "/accounting", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	router.ServeHTTP(listResp, reqList)
 	var result struct {
 		Data []struct {
@@ -176,6 +192,7 @@ func TestDeleteTransactionHandler(t *testing.T) {
 
 	// Deleta a transação
 	reqDel, _ := http.NewRequest("DELETE", "/accounting/"+strconv.Itoa(id), nil)
+	reqDel.Header.Set(middleware.CompanyHeader, "1")
 	delResp := httptest.NewRecorder()
 	router.ServeHTTP(delResp, reqDel)
 	if delResp.Code != http.StatusOK {
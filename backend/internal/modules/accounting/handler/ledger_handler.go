@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/accounting/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Formato aceito pelos parâmetros de data dos relatórios contábeis
+const ledgerDateLayout = "2006-01-02"
+
+// Lista o plano de contas
+// @Security BearerAuth
+func ListLedgerAccountsHandler(c *gin.Context) {
+	accounts, err := service.ListLedgerAccounts(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar plano de contas")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// Cria uma conta no plano de contas
+// @Security BearerAuth
+func CreateLedgerAccountHandler(c *gin.Context) {
+	var account models.LedgerAccount
+	if err := c.ShouldBindJSON(&account); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+	if err := validate.Struct(account); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.CreateLedgerAccount(c.Request.Context(), &account); err != nil {
+		c.Error(err).SetMeta("erro ao criar conta contábil")
+		return
+	}
+	c.JSON(http.StatusCreated, account)
+}
+
+// Atualiza nome, conta pai e situação de uma conta
+// @Security BearerAuth
+func UpdateLedgerAccountHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var account models.LedgerAccount
+	if err := c.ShouldBindJSON(&account); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+	account.ID = id
+
+	if err := service.UpdateLedgerAccount(c.Request.Context(), &account); err != nil {
+		c.Error(err).SetMeta("erro ao atualizar conta contábil")
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// Retorna as contas configuradas para a contabilização automática
+// @Security BearerAuth
+func GetAccountMappingsHandler(c *gin.Context) {
+	mappings, err := service.GetAccountMappings(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar mapeamento de contas")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": mappings})
+}
+
+// Altera a conta usada por uma operação (ex.: receivables, sales_revenue)
+// @Security BearerAuth
+func SetAccountMappingHandler(c *gin.Context) {
+	var req struct {
+		AccountID int `json:"account_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.SetAccountMapping(c.Request.Context(), c.Param("key"), req.AccountID); err != nil {
+		c.Error(err).SetMeta("erro ao salvar mapeamento de conta")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Mapeamento atualizado com sucesso"})
+}
+
+// Lista os lançamentos contábeis do período
+// @Param from query date false "Data inicial (AAAA-MM-DD)"
+// @Param to query date false "Data final (AAAA-MM-DD)"
+// @Security BearerAuth
+func ListJournalEntriesHandler(c *gin.Context) {
+	from, err := parseLedgerDate(c.Query("from"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data inicial inválida").WithDetails(err.Error()))
+		return
+	}
+	to, err := parseLedgerDate(c.Query("to"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data final inválida").WithDetails(err.Error()))
+		return
+	}
+
+	params := pagination.NewPaginationParams(c.Request)
+	result, err := service.GetAllJournalEntries(c.Request.Context(), from, to, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar lançamentos")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna um lançamento contábil com suas partidas
+// @Security BearerAuth
+func GetJournalEntryHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	entry, err := service.GetJournalEntry(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar lançamento")
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// Registra um lançamento manual balanceado
+// @Security BearerAuth
+func CreateJournalEntryHandler(c *gin.Context) {
+	var entry models.JournalEntry
+	if err := c.ShouldBindJSON(&entry); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.CreateManualJournalEntry(c.Request.Context(), &entry); err != nil {
+		c.Error(err).SetMeta("erro ao registrar lançamento")
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// Contabiliza um documento (invoice, payment, credit_note, supplier_bill, expense ou
+// expense_reimbursement). A fatura com margem abaixo do mínimo da categoria é recusada até a
+// liberação em POST /invoices/:id/approve-margin.
+// @Security BearerAuth
+func PostDocumentHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	entry, err := service.PostDocument(c.Request.Context(), c.Param("source"), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao contabilizar documento")
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// Contabiliza todos os documentos financeiros pendentes
+// @Security BearerAuth
+func PostPendingDocumentsHandler(c *gin.Context) {
+	result, err := service.PostPendingDocuments(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao contabilizar documentos")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna o balancete de verificação na data (as_of)
+// @Param as_of query date false "Data de referência (AAAA-MM-DD); padrão: hoje"
+// @Security BearerAuth
+func GetTrialBalanceHandler(c *gin.Context) {
+	asOf, err := parseLedgerDate(c.Query("as_of"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data inválida").WithDetails(err.Error()))
+		return
+	}
+
+	tb, err := service.GetTrialBalance(c.Request.Context(), asOf)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar balancete")
+		return
+	}
+	c.JSON(http.StatusOK, tb)
+}
+
+// Retorna a demonstração de resultado do período
+// @Param from query date false "Data inicial (AAAA-MM-DD)"
+// @Param to query date false "Data final (AAAA-MM-DD)"
+// @Security BearerAuth
+func GetProfitAndLossHandler(c *gin.Context) {
+	from, err := parseLedgerDate(c.Query("from"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data inicial inválida").WithDetails(err.Error()))
+		return
+	}
+	to, err := parseLedgerDate(c.Query("to"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data final inválida").WithDetails(err.Error()))
+		return
+	}
+
+	pl, err := service.GetProfitAndLoss(c.Request.Context(), from, to)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar demonstração de resultado")
+		return
+	}
+	c.JSON(http.StatusOK, pl)
+}
+
+// parseLedgerDate converte o parâmetro de data; vazio resulta em data zero
+func parseLedgerDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(ledgerDateLayout, value)
+}
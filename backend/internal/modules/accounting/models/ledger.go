@@ -0,0 +1,174 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// Tipos de conta do plano de contas
+const (
+	AccountTypeAsset     = "asset"
+	AccountTypeLiability = "liability"
+	AccountTypeEquity    = "equity"
+	AccountTypeRevenue   = "revenue"
+	AccountTypeExpense   = "expense"
+)
+
+// Linhas da DRE em que as contas de resultado são apresentadas
+const (
+	DREGrossRevenue      = "gross_revenue"
+	DREDeductions        = "deductions"
+	DRECOGS              = "cogs"
+	DREOperatingExpenses = "operating_expenses"
+	DREFinancialResult   = "financial_result"
+)
+
+// Chaves de mapeamento usadas pela contabilização automática de documentos
+const (
+	MappingCash           = "cash"
+	MappingReceivables    = "receivables"
+	MappingTaxRecoverable = "tax_recoverable"
+	MappingPayables       = "payables"
+	MappingTaxPayable     = "tax_payable"
+	MappingSalesRevenue   = "sales_revenue"
+	MappingSalesDiscounts = "sales_discounts"
+	MappingSalesReturns   = "sales_returns"
+	MappingPurchases      = "purchases"
+	// Despesas dos colaboradores sem conta própria na categoria e reembolsos a pagar a eles
+	MappingExpenses              = "expenses"
+	MappingReimbursementsPayable = "reimbursements_payable"
+	// Ativo imobilizado: custo dos bens, depreciação acumulada (retificadora do ativo), despesa
+	// de depreciação e resultado da baixa
+	MappingFixedAssets             = "fixed_assets"
+	MappingAccumulatedDepreciation = "accumulated_depreciation"
+	MappingDepreciationExpense     = "depreciation_expense"
+	MappingAssetDisposalGain       = "asset_disposal_gain"
+	MappingAssetDisposalLoss       = "asset_disposal_loss"
+	// Custo mensal dos colaboradores rateado por centro de custo e salários e encargos a pagar
+	MappingLaborCosts     = "labor_costs"
+	MappingPayrollPayable = "payroll_payable"
+	// Tarifas de cobrança debitadas pelo banco no retorno CNAB
+	MappingBankFees = "bank_fees"
+	// Adiantamentos de clientes faturados antes da entrega, abatidos nas faturas dos itens
+	MappingCustomerAdvances = "customer_advances"
+)
+
+// Tipos de documento de origem de um lançamento
+const (
+	SourceInvoice      = "invoice"
+	SourcePayment      = "payment"
+	SourceCreditNote   = "credit_note"
+	SourceSupplierBill = "supplier_bill"
+	SourceManual       = "manual"
+	// A despesa aprovada e o seu reembolso são lançados separadamente
+	SourceExpense              = "expense"
+	SourceExpenseReimbursement = "expense_reimbursement"
+	// A capitalização do bem, cada depreciação mensal e a baixa são lançadas separadamente
+	SourceAssetAcquisition  = "asset_acquisition"
+	SourceAssetDepreciation = "asset_depreciation"
+	SourceAssetDisposal     = "asset_disposal"
+	// Cada linha do rateio mensal de mão de obra é lançada no centro de custo dela
+	SourceLaborCost = "labor_cost"
+	// Cada título do retorno de cobrança com tarifa gera um lançamento da tarifa
+	SourceBankFee = "bank_fee"
+)
+
+// LedgerAccount representa uma conta do plano de contas
+type LedgerAccount struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	Code      string    `json:"code" validate:"required" gorm:"uniqueIndex"`
+	Name      string    `json:"name" validate:"required"`
+	Type      string    `json:"type" validate:"required,oneof=asset liability equity revenue expense"`
+	ParentID  *int      `json:"parent_id,omitempty"`
+	DREGroup  *string   `json:"dre_group,omitempty" gorm:"column:dre_group"`
+	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// IsDebitNormal indica se a conta aumenta com débitos (ativo e despesa)
+func (a LedgerAccount) IsDebitNormal() bool {
+	return a.Type == AccountTypeAsset || a.Type == AccountTypeExpense
+}
+
+// AccountMapping associa uma chave de operação a uma conta do plano
+type AccountMapping struct {
+	Key       string    `json:"key" gorm:"primaryKey"`
+	AccountID int       `json:"account_id" validate:"required"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Account *LedgerAccount `json:"account,omitempty" gorm:"foreignKey:AccountID"`
+}
+
+// TableName define o nome da tabela de mapeamentos
+func (AccountMapping) TableName() string {
+	return "ledger_account_mappings"
+}
+
+// JournalEntry representa um lançamento contábil em partidas dobradas
+type JournalEntry struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	CompanyID    int       `json:"company_id" gorm:"<-:create"`
+	EntryDate    time.Time `json:"entry_date"`
+	Description  string    `json:"description"`
+	SourceType   string    `json:"source_type,omitempty"`
+	SourceID     *int      `json:"source_id,omitempty"`
+	CostCenterID *int      `json:"cost_center_id,omitempty" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Lines []JournalLine `json:"lines" gorm:"foreignKey:EntryID"`
+}
+
+// JournalLine representa uma partida (débito ou crédito) de um lançamento
+type JournalLine struct {
+	ID          int           `json:"id" gorm:"primaryKey"`
+	EntryID     int           `json:"entry_id" gorm:"index"`
+	AccountID   int           `json:"account_id" validate:"required"`
+	Debit       money.Decimal `json:"debit"`
+	Credit      money.Decimal `json:"credit"`
+	Description string        `json:"description,omitempty"`
+
+	// Relationships
+	Account *LedgerAccount `json:"account,omitempty" gorm:"foreignKey:AccountID"`
+}
+
+// TrialBalanceRow representa o saldo de uma conta no balancete
+type TrialBalanceRow struct {
+	AccountID   int           `json:"account_id"`
+	AccountCode string        `json:"account_code"`
+	AccountName string        `json:"account_name"`
+	AccountType string        `json:"account_type"`
+	Debit       money.Decimal `json:"debit"`
+	Credit      money.Decimal `json:"credit"`
+	Balance     money.Decimal `json:"balance"`
+}
+
+// TrialBalance representa o balancete de verificação em uma data
+type TrialBalance struct {
+	AsOf        time.Time         `json:"as_of"`
+	Rows        []TrialBalanceRow `json:"rows"`
+	TotalDebit  money.Decimal     `json:"total_debit"`
+	TotalCredit money.Decimal     `json:"total_credit"`
+	Balanced    bool              `json:"balanced"`
+}
+
+// ProfitAndLoss representa a demonstração de resultado de um período
+type ProfitAndLoss struct {
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Revenues     []TrialBalanceRow `json:"revenues"`
+	Expenses     []TrialBalanceRow `json:"expenses"`
+	TotalRevenue money.Decimal     `json:"total_revenue"`
+	TotalExpense money.Decimal     `json:"total_expense"`
+	NetIncome    money.Decimal     `json:"net_income"`
+}
+
+// PostingResult resume a contabilização em lote dos documentos pendentes
+type PostingResult struct {
+	Posted  map[string]int `json:"posted"`
+	Skipped int            `json:"skipped"`
+	Errors  []string       `json:"errors,omitempty"`
+}
@@ -1,8 +1,10 @@
 package models
 
+import "ERP-ONSMART/backend/internal/money"
+
 type Transaction struct {
-	ID          int     `json:"id,omitempty"`
-	Description string  `json:"description" validate:"required"`
-	Amount      float64 `json:"amount" validate:"required"`
-	Date        string  `json:"date" validate:"required,datetime=02/01/2006"`
+	ID          int           `json:"id,omitempty"`
+	Description string        `json:"description" validate:"required"`
+	Amount      money.Decimal `json:"amount" validate:"required"`
+	Date        string        `json:"date" validate:"required,datetime=02/01/2006"`
 }
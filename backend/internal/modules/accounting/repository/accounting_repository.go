@@ -2,13 +2,21 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"database/sql"
 	"fmt"
 )
 
 // GetAllTransactions retorna todas as transações armazenadas no banco.
-func GetAllTransactions() ([]models.Transaction, error) {
+func GetAllTransactions(ctx context.Context) ([]models.Transaction, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
@@ -18,10 +26,11 @@ func GetAllTransactions() ([]models.Transaction, error) {
 	query := `
 		SELECT id, description, amount, date
 		FROM acc_transaction
+		WHERE ` + company + `
 		ORDER BY id
 	`
 
-	rows, err := conn.Query(query)
+	rows, err := conn.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +54,12 @@ func GetAllTransactions() ([]models.Transaction, error) {
 }
 
 // CreateTransaction insere uma nova transação e retorna a transação criada com o ID gerado.
-func CreateTransaction(t models.Transaction) (models.Transaction, error) {
+func CreateTransaction(ctx context.Context, t models.Transaction) (models.Transaction, error) {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return models.Transaction{}, errors.ErrTenantRequired
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return models.Transaction{}, err
@@ -53,12 +67,12 @@ func CreateTransaction(t models.Transaction) (models.Transaction, error) {
 	defer conn.Close()
 
 	query := `
-		INSERT INTO acc_transaction (description, amount, date)
-		VALUES ($1, $2, TO_DATE($3, 'DD/MM/YYYY'))
+		INSERT INTO acc_transaction (description, amount, date, company_id)
+		VALUES ($1, $2, TO_DATE($3, 'DD/MM/YYYY'), $4)
 		RETURNING id
 	`
 
-	err = conn.QueryRow(query, t.Description, t.Amount, t.Date).Scan(&t.ID)
+	err = conn.QueryRowContext(ctx, query, t.Description, t.Amount, t.Date, companyID).Scan(&t.ID)
 	if err != nil {
 		return models.Transaction{}, err
 	}
@@ -67,7 +81,12 @@ func CreateTransaction(t models.Transaction) (models.Transaction, error) {
 }
 
 // UpdateTransaction atualiza os dados de uma transação existente.
-func UpdateTransaction(id int, updated models.Transaction) (models.Transaction, error) {
+func UpdateTransaction(ctx context.Context, id int, updated models.Transaction) (models.Transaction, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return models.Transaction{}, err
@@ -79,10 +98,9 @@ func UpdateTransaction(id int, updated models.Transaction) (models.Transaction,
 		SET description = $1,
 		    amount = $2,
 		    date = TO_DATE($3, 'DD/MM/YYYY')
-		WHERE id = $4
-	`
+		WHERE id = $4 AND ` + company
 
-	result, err := conn.Exec(query, updated.Description, updated.Amount, updated.Date, id)
+	result, err := conn.ExecContext(ctx, query, updated.Description, updated.Amount, updated.Date, id)
 	if err != nil {
 		return models.Transaction{}, err
 	}
@@ -100,16 +118,21 @@ func UpdateTransaction(id int, updated models.Transaction) (models.Transaction,
 }
 
 // DeleteTransaction remove uma transação a partir de seu ID.
-func DeleteTransaction(id int) error {
+func DeleteTransaction(ctx context.Context, id int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	query := `DELETE FROM acc_transaction WHERE id = $1`
+	query := `DELETE FROM acc_transaction WHERE id = $1 AND ` + company
 
-	result, err := conn.Exec(query, id)
+	result, err := conn.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
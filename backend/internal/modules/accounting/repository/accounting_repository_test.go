@@ -3,6 +3,9 @@ package repository
 import (
 	"ERP-ONSMART/backend/internal/logger"
 	"ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -31,11 +34,11 @@ func TestMain(m *testing.M) {
 func TestCreateTransaction(t *testing.T) {
 	trans := models.Transaction{
 		Description: "Teste Create",
-		Amount:      10,
+		Amount:      money.FromInt(10),
 		Date:        "2023-01-01", // Formato ISO: yyyy-mm-dd
 	}
 
-	created, err := CreateTransaction(trans)
+	created, err := CreateTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), trans)
 	if err != nil {
 		t.Fatalf("Erro ao criar transação: %v", err)
 	}
@@ -48,15 +51,15 @@ func TestGetAllTransactions(t *testing.T) {
 	// Cria uma transação para garantir que haja pelo menos um registro na listagem
 	trans := models.Transaction{
 		Description: "Teste Get",
-		Amount:      10,
+		Amount:      money.FromInt(10),
 		Date:        "2023-01-01", // Formato ISO: yyyy-mm-dd
 	}
-	created, err := CreateTransaction(trans)
+	created, err := CreateTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), trans)
 	if err != nil {
 		t.Fatalf("Erro ao criar transação: %v", err)
 	}
 
-	transactions, err := GetAllTransactions()
+	transactions, err := GetAllTransactions(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao obter transações: %v", err)
 	}
@@ -78,10 +81,10 @@ func TestUpdateTransaction(t *testing.T) {
 	// Cria uma transação
 	trans := models.Transaction{
 		Description: "Para Update",
-		Amount:      20,
+		Amount:      money.FromInt(20),
 		Date:        "2023-01-01",
 	}
-	created, err := CreateTransaction(trans)
+	created, err := CreateTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), trans)
 	if err != nil {
 		t.Fatalf("Erro ao criar transação: %v", err)
 	}
@@ -89,10 +92,10 @@ func TestUpdateTransaction(t *testing.T) {
 	// Dados para atualização
 	newData := models.Transaction{
 		Description: "Atualizado",
-		Amount:      25,
+		Amount:      money.FromInt(25),
 		Date:        "2023-01-02",
 	}
-	updated, err := UpdateTransaction(created.ID, newData)
+	updated, err := UpdateTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID, newData)
 	if err != nil {
 		t.Fatalf("Erro ao atualizar transação: %v", err)
 	}
@@ -111,22 +114,22 @@ func TestDeleteTransaction(t *testing.T) {
 	// Cria uma transação para remoção
 	trans := models.Transaction{
 		Description: "Para Deletar",
-		Amount:      30,
+		Amount:      money.FromInt(30),
 		Date:        "2023-01-01",
 	}
-	created, err := CreateTransaction(trans)
+	created, err := CreateTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), trans)
 	if err != nil {
 		t.Fatalf("Erro ao criar transação: %v", err)
 	}
 
 	// Remove a transação
-	err = DeleteTransaction(created.ID)
+	err = DeleteTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Errorf("Erro ao remover transação: %v", err)
 	}
 
 	// Tenta remover novamente: deve retornar erro indicando que a transação não existe
-	err = DeleteTransaction(created.ID)
+	err = DeleteTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err == nil {
 		t.Errorf("Esperava erro ao deletar transação inexistente, mas não houve erro")
 	} else {
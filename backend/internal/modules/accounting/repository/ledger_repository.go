@@ -0,0 +1,501 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/accounting/models"
+	assets "ERP-ONSMART/backend/internal/modules/assets/models"
+	banking "ERP-ONSMART/backend/internal/modules/banking/models"
+	expenses "ERP-ONSMART/backend/internal/modules/expenses/models"
+	hr "ERP-ONSMART/backend/internal/modules/hr/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LedgerRepository define as operações do razão geral e do plano de contas
+type LedgerRepository interface {
+	// Plano de contas
+	CreateAccount(ctx context.Context, account *models.LedgerAccount) error
+	GetAccountByID(ctx context.Context, id int) (*models.LedgerAccount, error)
+	GetAllAccounts(ctx context.Context) ([]models.LedgerAccount, error)
+	UpdateAccount(ctx context.Context, account *models.LedgerAccount) error
+	GetAccountMappings(ctx context.Context) (map[string]int, error)
+	SetAccountMapping(ctx context.Context, key string, accountID int) error
+
+	// Lançamentos
+	CreateJournalEntry(ctx context.Context, entry *models.JournalEntry) error
+	GetJournalEntryByID(ctx context.Context, id int) (*models.JournalEntry, error)
+	GetAllJournalEntries(ctx context.Context, from, to time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	IsDocumentPosted(ctx context.Context, sourceType string, sourceID int) (bool, error)
+
+	// Documentos de origem
+	GetInvoiceByID(ctx context.Context, id int) (*sales.Invoice, error)
+	GetPaymentByID(ctx context.Context, id int) (*sales.Payment, error)
+	GetCreditNoteByID(ctx context.Context, id int) (*sales.CreditNote, error)
+	GetSupplierBillByID(ctx context.Context, id int) (*sales.SupplierBill, error)
+	GetExpenseByID(ctx context.Context, id int) (*expenses.Expense, error)
+	GetAssetByID(ctx context.Context, id int) (*assets.Asset, error)
+	GetAssetDepreciationByID(ctx context.Context, id int) (*assets.AssetDepreciation, error)
+	GetLaborCostAllocationByID(ctx context.Context, id int) (*hr.LaborCostAllocation, error)
+	GetBankFeeByID(ctx context.Context, id int) (*banking.BankReturnItem, error)
+	GetUnpostedDocumentIDs(ctx context.Context, sourceType string) ([]int, error)
+
+	// Saldos
+	GetAccountTotals(ctx context.Context, from, to time.Time) ([]models.TrialBalanceRow, error)
+}
+
+type ledgerRepository struct {
+	db     *gorm.DB
+	reader *gorm.DB // réplica de leitura para as consultas analíticas (ver db.Provider)
+	logger *zap.Logger
+}
+
+// NewLedgerRepository cria uma nova instância do repositório
+func NewLedgerRepository() (LedgerRepository, error) {
+	provider, err := db.OpenProvider()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &ledgerRepository{
+		db:     provider.Writer(),
+		reader: provider.Reader(),
+		logger: logger.WithModule("ledger_repository"),
+	}, nil
+}
+
+// unpostedQuery é a consulta dos documentos de um tipo ainda sem lançamento; o filtro da empresa
+// (tenant.Condition sobre o alias) entra no %s
+type unpostedQuery struct {
+	alias string
+	sql   string
+}
+
+// Consultas dos documentos ainda não contabilizados, por tipo de origem
+var unpostedDocumentQueries = map[string]unpostedQuery{
+	models.SourceInvoice: {"i", `
+SELECT i.id FROM invoices i
+WHERE i.status NOT IN ('draft', 'cancelled')
+  AND %s
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'invoice' AND e.source_id = i.id)
+ORDER BY i.id`},
+	models.SourcePayment: {"p", `
+SELECT p.id FROM payments p
+WHERE NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'payment' AND e.source_id = p.id)
+  AND %s
+ORDER BY p.id`},
+	models.SourceCreditNote: {"n", `
+SELECT n.id FROM credit_notes n
+WHERE n.status <> 'cancelled'
+  AND %s
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'credit_note' AND e.source_id = n.id)
+ORDER BY n.id`},
+	models.SourceSupplierBill: {"b", `
+SELECT b.id FROM supplier_bills b
+WHERE b.status NOT IN ('cancelled', 'draft')
+  AND %s
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'supplier_bill' AND e.source_id = b.id)
+ORDER BY b.id`},
+	models.SourceExpense: {"x", `
+SELECT x.id FROM expenses x
+WHERE x.status IN ('approved', 'reimbursed')
+  AND %s
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'expense' AND e.source_id = x.id)
+ORDER BY x.id`},
+	models.SourceExpenseReimbursement: {"x", `
+SELECT x.id FROM expenses x
+WHERE x.status = 'reimbursed'
+  AND %s
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'expense_reimbursement' AND e.source_id = x.id)
+ORDER BY x.id`},
+	models.SourceAssetAcquisition: {"a", `
+SELECT a.id FROM assets a
+WHERE NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'asset_acquisition' AND e.source_id = a.id)
+  AND %s
+ORDER BY a.id`},
+	models.SourceAssetDepreciation: {"d", `
+SELECT d.id FROM asset_depreciations d
+WHERE NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'asset_depreciation' AND e.source_id = d.id)
+  AND %s
+ORDER BY d.period, d.id`},
+	models.SourceAssetDisposal: {"a", `
+SELECT a.id FROM assets a
+WHERE a.status IN ('disposed', 'written_off')
+  AND %s
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'asset_disposal' AND e.source_id = a.id)
+ORDER BY a.id`},
+	models.SourceLaborCost: {"l", `
+SELECT l.id FROM labor_cost_allocations l
+WHERE l.amount > 0
+  AND %s
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'labor_cost' AND e.source_id = l.id)
+ORDER BY l.period, l.id`},
+	models.SourceBankFee: {"t", `
+SELECT t.id FROM bank_return_items t
+WHERE t.fee_amount > 0
+  AND %s
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.source_type = 'bank_fee' AND e.source_id = t.id)
+ORDER BY t.id`},
+}
+
+// CreateAccount cria uma nova conta contábil
+func (r *ledgerRepository) CreateAccount(ctx context.Context, account *models.LedgerAccount) error {
+	var count int64
+	if err := db.Conn(ctx, r.db).Model(&models.LedgerAccount{}).Where("code = ?", account.Code).Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar código da conta")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateAccountCode
+	}
+
+	if err := db.Conn(ctx, r.db).Create(account).Error; err != nil {
+		r.logger.Error("erro ao criar conta contábil", zap.Error(err), zap.String("code", account.Code))
+		return errors.WrapError(err, "falha ao criar conta contábil")
+	}
+
+	r.logger.Info("conta contábil criada", zap.Int("id", account.ID), zap.String("code", account.Code))
+	return nil
+}
+
+// GetAccountByID busca uma conta contábil pelo ID
+func (r *ledgerRepository) GetAccountByID(ctx context.Context, id int) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	if err := db.Conn(ctx, r.db).First(&account, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrLedgerAccountNotFound
+		}
+		r.logger.Error("erro ao buscar conta contábil", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar conta contábil")
+	}
+	return &account, nil
+}
+
+// GetAllAccounts retorna o plano de contas ordenado pelo código
+func (r *ledgerRepository) GetAllAccounts(ctx context.Context) ([]models.LedgerAccount, error) {
+	var accounts []models.LedgerAccount
+	if err := db.Conn(ctx, r.db).Order("code ASC").Find(&accounts).Error; err != nil {
+		r.logger.Error("erro ao listar plano de contas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar plano de contas")
+	}
+	return accounts, nil
+}
+
+// UpdateAccount atualiza nome, conta pai e situação de uma conta
+func (r *ledgerRepository) UpdateAccount(ctx context.Context, account *models.LedgerAccount) error {
+	result := db.Conn(ctx, r.db).Model(&models.LedgerAccount{}).Where("id = ?", account.ID).
+		Updates(map[string]interface{}{
+			"name":      account.Name,
+			"parent_id": account.ParentID,
+			"dre_group": account.DREGroup,
+			"is_active": account.IsActive,
+		})
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar conta contábil", zap.Error(result.Error), zap.Int("id", account.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar conta contábil")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrLedgerAccountNotFound
+	}
+	return nil
+}
+
+// GetAccountMappings retorna as contas configuradas para cada chave de operação
+func (r *ledgerRepository) GetAccountMappings(ctx context.Context) (map[string]int, error) {
+	var mappings []models.AccountMapping
+	if err := db.Conn(ctx, r.db).Find(&mappings).Error; err != nil {
+		r.logger.Error("erro ao buscar mapeamento de contas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar mapeamento de contas")
+	}
+
+	result := make(map[string]int, len(mappings))
+	for _, m := range mappings {
+		result[m.Key] = m.AccountID
+	}
+	return result, nil
+}
+
+// SetAccountMapping define (ou substitui) a conta usada por uma chave de operação
+func (r *ledgerRepository) SetAccountMapping(ctx context.Context, key string, accountID int) error {
+	if _, err := r.GetAccountByID(ctx, accountID); err != nil {
+		return err
+	}
+
+	mapping := models.AccountMapping{Key: key, AccountID: accountID}
+	if err := db.Conn(ctx, r.db).Save(&mapping).Error; err != nil {
+		r.logger.Error("erro ao salvar mapeamento de conta", zap.Error(err), zap.String("key", key))
+		return errors.WrapError(err, "falha ao salvar mapeamento de conta")
+	}
+	return nil
+}
+
+// CreateJournalEntry grava o lançamento e suas partidas em uma transação.
+// Lançamentos de documentos são únicos por origem.
+func (r *ledgerRepository) CreateJournalEntry(ctx context.Context, entry *models.JournalEntry) error {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	if entry.SourceType != "" && entry.SourceID != nil {
+		var count int64
+		if err := tx.Model(&models.JournalEntry{}).
+			Where("source_type = ? AND source_id = ?", entry.SourceType, *entry.SourceID).
+			Count(&count).Error; err != nil {
+			tx.Rollback()
+			return errors.WrapError(err, "falha ao verificar contabilização do documento")
+		}
+		if count > 0 {
+			tx.Rollback()
+			return errors.ErrDocumentAlreadyPosted
+		}
+	}
+
+	lines := entry.Lines
+	entry.Lines = nil
+
+	if err := tx.Create(entry).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar lançamento contábil", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar lançamento contábil")
+	}
+
+	for i := range lines {
+		lines[i].EntryID = entry.ID
+		lines[i].Account = nil
+	}
+
+	if err := tx.Create(&lines).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar partidas do lançamento", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar partidas do lançamento")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	entry.Lines = lines
+	r.logger.Info("lançamento contábil criado",
+		zap.Int("id", entry.ID), zap.String("source_type", entry.SourceType))
+	return nil
+}
+
+// GetJournalEntryByID busca um lançamento com suas partidas e contas
+func (r *ledgerRepository) GetJournalEntryByID(ctx context.Context, id int) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	if err := db.Conn(ctx, r.db).Preload("Lines.Account").First(&entry, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrJournalEntryNotFound
+		}
+		r.logger.Error("erro ao buscar lançamento contábil", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar lançamento contábil")
+	}
+	return &entry, nil
+}
+
+// GetAllJournalEntries lista os lançamentos do período (limite superior exclusivo)
+func (r *ledgerRepository) GetAllJournalEntries(ctx context.Context, from, to time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var entries []models.JournalEntry
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.JournalEntry{})
+	if !from.IsZero() {
+		query = query.Where("entry_date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("entry_date < ?", to)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar lançamentos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar lançamentos")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Preload("Lines").
+		Order("entry_date DESC, id DESC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&entries).Error; err != nil {
+		r.logger.Error("erro ao buscar lançamentos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar lançamentos")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, entries), nil
+}
+
+// IsDocumentPosted verifica se o documento já possui lançamento contábil
+func (r *ledgerRepository) IsDocumentPosted(ctx context.Context, sourceType string, sourceID int) (bool, error) {
+	var count int64
+	if err := db.Conn(ctx, r.db).Model(&models.JournalEntry{}).
+		Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+		Count(&count).Error; err != nil {
+		return false, errors.WrapError(err, "falha ao verificar contabilização do documento")
+	}
+	return count > 0, nil
+}
+
+// GetInvoiceByID busca a fatura a ser contabilizada
+func (r *ledgerRepository) GetInvoiceByID(ctx context.Context, id int) (*sales.Invoice, error) {
+	var invoice sales.Invoice
+	if err := db.Conn(ctx, r.db).First(&invoice, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar invoice")
+	}
+	return &invoice, nil
+}
+
+// GetPaymentByID busca o pagamento a ser contabilizado, com a fatura
+func (r *ledgerRepository) GetPaymentByID(ctx context.Context, id int) (*sales.Payment, error) {
+	var payment sales.Payment
+	if err := db.Conn(ctx, r.db).Preload("Invoice").First(&payment, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrPaymentNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar payment")
+	}
+	return &payment, nil
+}
+
+// GetCreditNoteByID busca a nota de crédito a ser contabilizada
+func (r *ledgerRepository) GetCreditNoteByID(ctx context.Context, id int) (*sales.CreditNote, error) {
+	var note sales.CreditNote
+	if err := db.Conn(ctx, r.db).First(&note, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCreditNoteNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar nota de crédito")
+	}
+	return &note, nil
+}
+
+// GetSupplierBillByID busca a conta a pagar a ser contabilizada
+func (r *ledgerRepository) GetSupplierBillByID(ctx context.Context, id int) (*sales.SupplierBill, error) {
+	var bill sales.SupplierBill
+	if err := db.Conn(ctx, r.db).First(&bill, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrSupplierBillNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar conta a pagar")
+	}
+	return &bill, nil
+}
+
+// GetExpenseByID busca a despesa de colaborador a ser contabilizada, com a categoria
+func (r *ledgerRepository) GetExpenseByID(ctx context.Context, id int) (*expenses.Expense, error) {
+	var expense expenses.Expense
+	if err := db.Conn(ctx, r.db).Preload("Category").First(&expense, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrExpenseNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar despesa")
+	}
+	return &expense, nil
+}
+
+// GetAssetByID busca o bem patrimonial a ser contabilizado (capitalização ou baixa)
+func (r *ledgerRepository) GetAssetByID(ctx context.Context, id int) (*assets.Asset, error) {
+	var asset assets.Asset
+	if err := db.Conn(ctx, r.db).First(&asset, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrAssetNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar bem patrimonial")
+	}
+	return &asset, nil
+}
+
+// GetAssetDepreciationByID busca a depreciação mensal a ser contabilizada, com o bem
+func (r *ledgerRepository) GetAssetDepreciationByID(ctx context.Context, id int) (*assets.AssetDepreciation, error) {
+	var depreciation assets.AssetDepreciation
+	if err := db.Conn(ctx, r.db).Preload("Asset").First(&depreciation, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrAssetNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar depreciação do bem")
+	}
+	return &depreciation, nil
+}
+
+// GetLaborCostAllocationByID busca a linha do rateio de mão de obra a ser contabilizada, com o
+// colaborador
+func (r *ledgerRepository) GetLaborCostAllocationByID(ctx context.Context, id int) (*hr.LaborCostAllocation, error) {
+	var allocation hr.LaborCostAllocation
+	if err := db.Conn(ctx, r.db).Preload("Employee").First(&allocation, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrEmployeeNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar rateio de mão de obra")
+	}
+	return &allocation, nil
+}
+
+// GetBankFeeByID busca o título do retorno de cobrança com a tarifa a ser contabilizada, com o
+// arquivo de retorno
+func (r *ledgerRepository) GetBankFeeByID(ctx context.Context, id int) (*banking.BankReturnItem, error) {
+	var item banking.BankReturnItem
+	if err := db.Conn(ctx, r.db).Preload("Return").First(&item, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrBankReturnNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar tarifa do retorno de cobrança")
+	}
+	return &item, nil
+}
+
+// GetUnpostedDocumentIDs retorna os IDs dos documentos do tipo ainda sem lançamento
+func (r *ledgerRepository) GetUnpostedDocumentIDs(ctx context.Context, sourceType string) ([]int, error) {
+	query, ok := unpostedDocumentQueries[sourceType]
+	if !ok {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	company, err := tenant.Condition(ctx, query.alias)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	if err := db.Conn(ctx, r.db).Raw(fmt.Sprintf(query.sql, company)).Scan(&ids).Error; err != nil {
+		r.logger.Error("erro ao buscar documentos pendentes", zap.Error(err), zap.String("source_type", sourceType))
+		return nil, errors.WrapError(err, "falha ao buscar documentos pendentes de contabilização")
+	}
+	return ids, nil
+}
+
+// GetAccountTotals soma débitos e créditos por conta no período.
+// Datas zero removem o respectivo limite; o limite superior é exclusivo.
+func (r *ledgerRepository) GetAccountTotals(ctx context.Context, from, to time.Time) ([]models.TrialBalanceRow, error) {
+	var rows []models.TrialBalanceRow
+
+	query := r.reader.WithContext(ctx).Table("ledger_accounts a").
+		Select(`a.id AS account_id, a.code AS account_code, a.name AS account_name, a.type AS account_type,
+			COALESCE(SUM(l.debit), 0) AS debit, COALESCE(SUM(l.credit), 0) AS credit`).
+		Joins("JOIN journal_lines l ON l.account_id = a.id").
+		Joins("JOIN journal_entries e ON e.id = l.entry_id").
+		Scopes(tenant.Scope(ctx, "e"))
+
+	if !from.IsZero() {
+		query = query.Where("e.entry_date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("e.entry_date < ?", to)
+	}
+
+	if err := query.Group("a.id, a.code, a.name, a.type").
+		Order("a.code ASC").
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("erro ao calcular saldos das contas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao calcular saldos das contas")
+	}
+
+	return rows, nil
+}
@@ -3,24 +3,25 @@ package service
 import (
 	"ERP-ONSMART/backend/internal/modules/accounting/models"
 	"ERP-ONSMART/backend/internal/modules/accounting/repository"
+	"context"
 )
 
 // ListTransactions retorna todas as transações ou um erro, caso ocorra.
-func ListTransactions() ([]models.Transaction, error) {
-	return repository.GetAllTransactions()
+func ListTransactions(ctx context.Context) ([]models.Transaction, error) {
+	return repository.GetAllTransactions(ctx)
 }
 
 // AddTransaction adiciona uma nova transação e retorna a transação criada ou um erro.
-func AddTransaction(t models.Transaction) (models.Transaction, error) {
-	return repository.CreateTransaction(t)
+func AddTransaction(ctx context.Context, t models.Transaction) (models.Transaction, error) {
+	return repository.CreateTransaction(ctx, t)
 }
 
 // ModifyTransaction atualiza uma transação existente e retorna a transação atualizada ou um erro.
-func ModifyTransaction(id int, t models.Transaction) (models.Transaction, error) {
-	return repository.UpdateTransaction(id, t)
+func ModifyTransaction(ctx context.Context, id int, t models.Transaction) (models.Transaction, error) {
+	return repository.UpdateTransaction(ctx, id, t)
 }
 
 // RemoveTransaction remove uma transação e retorna um erro caso a remoção não ocorra.
-func RemoveTransaction(id int) error {
-	return repository.DeleteTransaction(id)
+func RemoveTransaction(ctx context.Context, id int) error {
+	return repository.DeleteTransaction(ctx, id)
 }
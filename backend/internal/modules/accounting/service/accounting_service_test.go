@@ -3,6 +3,9 @@ package service
 import (
 	"ERP-ONSMART/backend/internal/logger"
 	"ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"os"
 	"testing"
 
@@ -31,11 +34,11 @@ func TestMain(m *testing.M) {
 func TestAddTransaction(t *testing.T) {
 	trans := models.Transaction{
 		Description: "Compra",
-		Amount:      50,
+		Amount:      money.FromInt(50),
 		Date:        "02/01/2023", // Data no formato dd/mm/yyyy, conforme definido no modelo
 	}
 
-	added, err := AddTransaction(trans)
+	added, err := AddTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), trans)
 	if err != nil {
 		t.Fatalf("Erro ao adicionar transação: %v", err)
 	}
@@ -52,15 +55,15 @@ func TestListTransactions(t *testing.T) {
 	// Adiciona uma transação para garantir que haja ao menos um registro
 	trans := models.Transaction{
 		Description: "Compra List",
-		Amount:      100,
+		Amount:      money.FromInt(100),
 		Date:        "03/01/2023",
 	}
-	added, err := AddTransaction(trans)
+	added, err := AddTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), trans)
 	if err != nil {
 		t.Fatalf("Erro ao adicionar transação: %v", err)
 	}
 
-	list, err := ListTransactions()
+	list, err := ListTransactions(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao listar transações: %v", err)
 	}
@@ -83,10 +86,10 @@ func TestModifyTransaction(t *testing.T) {
 	// Cria uma transação inicial
 	trans := models.Transaction{
 		Description: "Compra Teste",
-		Amount:      75,
+		Amount:      money.FromInt(75),
 		Date:        "04/01/2023",
 	}
-	added, err := AddTransaction(trans)
+	added, err := AddTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), trans)
 	if err != nil {
 		t.Fatalf("Erro ao adicionar transação: %v", err)
 	}
@@ -94,10 +97,10 @@ func TestModifyTransaction(t *testing.T) {
 	// Dados para atualização
 	newData := models.Transaction{
 		Description: "Compra Atualizada",
-		Amount:      80,
+		Amount:      money.FromInt(80),
 		Date:        "05/01/2023",
 	}
-	updated, err := ModifyTransaction(added.ID, newData)
+	updated, err := ModifyTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), added.ID, newData)
 	if err != nil {
 		t.Fatalf("Erro ao atualizar transação: %v", err)
 	}
@@ -114,22 +117,22 @@ func TestRemoveTransaction(t *testing.T) {
 	// Cria uma transação para remoção
 	trans := models.Transaction{
 		Description: "Compra Remover",
-		Amount:      150,
+		Amount:      money.FromInt(150),
 		Date:        "06/01/2023",
 	}
-	added, err := AddTransaction(trans)
+	added, err := AddTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), trans)
 	if err != nil {
 		t.Fatalf("Erro ao adicionar transação: %v", err)
 	}
 
 	// Remove a transação
-	err = RemoveTransaction(added.ID)
+	err = RemoveTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), added.ID)
 	if err != nil {
 		t.Errorf("Erro ao remover transação: %v", err)
 	}
 
 	// Tenta remover novamente para confirmar que não existe (deve retornar erro)
-	err = RemoveTransaction(added.ID)
+	err = RemoveTransaction(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), added.ID)
 	if err == nil {
 		t.Errorf("Esperado erro ao remover transação inexistente, mas erro não ocorreu")
 	}
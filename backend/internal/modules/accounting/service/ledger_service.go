@@ -0,0 +1,470 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/accounting/repository"
+	salesService "ERP-ONSMART/backend/internal/modules/sales/service"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"fmt"
+	"time"
+)
+
+// ensureInvoiceMargin recusa a fatura com margem abaixo do mínimo da categoria e sem liberação;
+// substituída nos testes
+var ensureInvoiceMargin = salesService.EnsureInvoiceMargin
+
+// Ordem em que os documentos são contabilizados no lote
+var postableSources = []string{
+	models.SourceInvoice,
+	models.SourcePayment,
+	models.SourceCreditNote,
+	models.SourceSupplierBill,
+	models.SourceExpense,
+	models.SourceExpenseReimbursement,
+	models.SourceAssetAcquisition,
+	models.SourceAssetDepreciation,
+	models.SourceAssetDisposal,
+	models.SourceLaborCost,
+	models.SourceBankFee,
+}
+
+// ListLedgerAccounts retorna o plano de contas
+func ListLedgerAccounts(ctx context.Context) ([]models.LedgerAccount, error) {
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAllAccounts(ctx)
+}
+
+// CreateLedgerAccount cria uma conta no plano de contas
+func CreateLedgerAccount(ctx context.Context, account *models.LedgerAccount) error {
+	if !isValidAccountType(account.Type) {
+		return errors.ErrInvalidAccountType
+	}
+	if !normalizeDREGroup(account) {
+		return errors.ErrInvalidDREGroup
+	}
+
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return err
+	}
+
+	if account.ParentID != nil {
+		parent, err := repo.GetAccountByID(ctx, *account.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.Type != account.Type {
+			return errors.ErrInvalidAccountType
+		}
+	}
+
+	account.IsActive = true
+	return repo.CreateAccount(ctx, account)
+}
+
+// UpdateLedgerAccount atualiza nome, conta pai, linha da DRE e situação de uma conta
+func UpdateLedgerAccount(ctx context.Context, account *models.LedgerAccount) error {
+	if !normalizeDREGroup(account) {
+		return errors.ErrInvalidDREGroup
+	}
+
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return err
+	}
+	return repo.UpdateAccount(ctx, account)
+}
+
+// GetAccountMappings retorna a configuração de contas da contabilização automática
+func GetAccountMappings(ctx context.Context) (map[string]int, error) {
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAccountMappings(ctx)
+}
+
+// SetAccountMapping altera a conta usada por uma chave de operação
+func SetAccountMapping(ctx context.Context, key string, accountID int) error {
+	if !isKnownMapping(key) {
+		return errors.ErrAccountMappingMissing
+	}
+
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return err
+	}
+	return repo.SetAccountMapping(ctx, key, accountID)
+}
+
+// CreateManualJournalEntry registra um lançamento manual após validar o balanceamento
+func CreateManualJournalEntry(ctx context.Context, entry *models.JournalEntry) error {
+	entry.SourceType = models.SourceManual
+	entry.SourceID = nil
+	if entry.EntryDate.IsZero() {
+		entry.EntryDate = time.Now()
+	}
+
+	for i := range entry.Lines {
+		entry.Lines[i].Debit = money.Round(entry.Lines[i].Debit)
+		entry.Lines[i].Credit = money.Round(entry.Lines[i].Credit)
+	}
+
+	if err := ValidateEntry(entry); err != nil {
+		return err
+	}
+
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return err
+	}
+
+	for _, line := range entry.Lines {
+		if _, err := repo.GetAccountByID(ctx, line.AccountID); err != nil {
+			return err
+		}
+	}
+
+	return repo.CreateJournalEntry(ctx, entry)
+}
+
+// GetJournalEntry retorna um lançamento com suas partidas
+func GetJournalEntry(ctx context.Context, id int) (*models.JournalEntry, error) {
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetJournalEntryByID(ctx, id)
+}
+
+// GetAllJournalEntries lista os lançamentos do período (datas inclusivas)
+func GetAllJournalEntries(ctx context.Context, from, to time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, errors.ErrInvalidDateRange
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAllJournalEntries(ctx, from, to, params)
+}
+
+// PostDocument contabiliza um documento financeiro específico
+func PostDocument(ctx context.Context, sourceType string, sourceID int) (*models.JournalEntry, error) {
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := repo.GetAccountMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return postDocument(ctx, repo, mappings, sourceType, sourceID)
+}
+
+// PostPendingDocuments contabiliza todos os documentos que ainda não possuem lançamento.
+// Falhas individuais não interrompem o lote e são devolvidas no resultado, inclusive as faturas
+// retidas pela margem mínima.
+func PostPendingDocuments(ctx context.Context) (*models.PostingResult, error) {
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := repo.GetAccountMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.PostingResult{Posted: make(map[string]int)}
+
+	for _, sourceType := range postableSources {
+		ids, err := repo.GetUnpostedDocumentIDs(ctx, sourceType)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			if _, err := postDocument(ctx, repo, mappings, sourceType, id); err != nil {
+				if err == errors.ErrDocumentAlreadyPosted || err == errors.ErrDocumentNotPostable {
+					result.Skipped++
+					continue
+				}
+				result.Errors = append(result.Errors, fmt.Sprintf("%s #%d: %v", sourceType, id, err))
+				continue
+			}
+			result.Posted[sourceType]++
+		}
+	}
+
+	return result, nil
+}
+
+// GetTrialBalance retorna o balancete de verificação até a data informada (inclusiva)
+func GetTrialBalance(ctx context.Context, asOf time.Time) (*models.TrialBalance, error) {
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.GetAccountTotals(ctx, time.Time{}, truncateToDay(asOf).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildTrialBalance(rows, asOf), nil
+}
+
+// GetProfitAndLoss retorna a demonstração de resultado do período (datas inclusivas)
+func GetProfitAndLoss(ctx context.Context, from, to time.Time) (*models.ProfitAndLoss, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = time.Date(to.Year(), 1, 1, 0, 0, 0, 0, to.Location())
+	}
+	from, to = truncateToDay(from), truncateToDay(to)
+	if to.Before(from) {
+		return nil, errors.ErrInvalidDateRange
+	}
+
+	repo, err := repository.NewLedgerRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.GetAccountTotals(ctx, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildProfitAndLoss(rows, from, to), nil
+}
+
+// BuildTrialBalance calcula o saldo de cada conta conforme sua natureza e os totais do balancete
+func BuildTrialBalance(rows []models.TrialBalanceRow, asOf time.Time) *models.TrialBalance {
+	tb := &models.TrialBalance{AsOf: asOf, Rows: []models.TrialBalanceRow{}}
+
+	for _, row := range rows {
+		row.Balance = accountBalance(row)
+		tb.TotalDebit = tb.TotalDebit.Add(row.Debit)
+		tb.TotalCredit = tb.TotalCredit.Add(row.Credit)
+		tb.Rows = append(tb.Rows, row)
+	}
+
+	tb.TotalDebit = money.Round(tb.TotalDebit)
+	tb.TotalCredit = money.Round(tb.TotalCredit)
+	tb.Balanced = tb.TotalDebit.Equal(tb.TotalCredit)
+	return tb
+}
+
+// BuildProfitAndLoss separa receitas e despesas e apura o resultado líquido
+func BuildProfitAndLoss(rows []models.TrialBalanceRow, from, to time.Time) *models.ProfitAndLoss {
+	pl := &models.ProfitAndLoss{
+		From:     from,
+		To:       to,
+		Revenues: []models.TrialBalanceRow{},
+		Expenses: []models.TrialBalanceRow{},
+	}
+
+	for _, row := range rows {
+		row.Balance = accountBalance(row)
+		switch row.AccountType {
+		case models.AccountTypeRevenue:
+			pl.Revenues = append(pl.Revenues, row)
+			pl.TotalRevenue = pl.TotalRevenue.Add(row.Balance)
+		case models.AccountTypeExpense:
+			pl.Expenses = append(pl.Expenses, row)
+			pl.TotalExpense = pl.TotalExpense.Add(row.Balance)
+		}
+	}
+
+	pl.TotalRevenue = money.Round(pl.TotalRevenue)
+	pl.TotalExpense = money.Round(pl.TotalExpense)
+	pl.NetIncome = pl.TotalRevenue.Sub(pl.TotalExpense)
+	return pl
+}
+
+// postDocument carrega o documento, gera o lançamento pela regra do tipo e o grava
+func postDocument(ctx context.Context, repo repository.LedgerRepository, mappings map[string]int, sourceType string, sourceID int) (*models.JournalEntry, error) {
+	posted, err := repo.IsDocumentPosted(ctx, sourceType, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if posted {
+		return nil, errors.ErrDocumentAlreadyPosted
+	}
+
+	var entry *models.JournalEntry
+
+	switch sourceType {
+	case models.SourceInvoice:
+		invoice, err := repo.GetInvoiceByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		entry, err = BuildInvoiceEntry(invoice, mappings)
+		if err != nil {
+			return nil, err
+		}
+		// A fatura abaixo da margem mínima da categoria só é contabilizada com liberação
+		if err := ensureInvoiceMargin(ctx, sourceID); err != nil {
+			return nil, err
+		}
+	case models.SourcePayment:
+		payment, err := repo.GetPaymentByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		entry, err = BuildPaymentEntry(payment, mappings)
+		if err != nil {
+			return nil, err
+		}
+	case models.SourceCreditNote:
+		note, err := repo.GetCreditNoteByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		entry, err = BuildCreditNoteEntry(note, mappings)
+		if err != nil {
+			return nil, err
+		}
+	case models.SourceSupplierBill:
+		bill, err := repo.GetSupplierBillByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		entry, err = BuildSupplierBillEntry(bill, mappings)
+		if err != nil {
+			return nil, err
+		}
+	case models.SourceExpense, models.SourceExpenseReimbursement:
+		expense, err := repo.GetExpenseByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if sourceType == models.SourceExpense {
+			entry, err = BuildExpenseEntry(expense, mappings)
+		} else {
+			entry, err = BuildExpenseReimbursementEntry(expense, mappings)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case models.SourceAssetAcquisition, models.SourceAssetDisposal:
+		asset, err := repo.GetAssetByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if sourceType == models.SourceAssetAcquisition {
+			entry, err = BuildAssetAcquisitionEntry(asset, mappings)
+		} else {
+			entry, err = BuildAssetDisposalEntry(asset, mappings)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case models.SourceAssetDepreciation:
+		depreciation, err := repo.GetAssetDepreciationByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		entry, err = BuildAssetDepreciationEntry(depreciation, mappings)
+		if err != nil {
+			return nil, err
+		}
+	case models.SourceLaborCost:
+		allocation, err := repo.GetLaborCostAllocationByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		entry, err = BuildLaborCostEntry(allocation, mappings)
+		if err != nil {
+			return nil, err
+		}
+	case models.SourceBankFee:
+		item, err := repo.GetBankFeeByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		entry, err = BuildBankFeeEntry(item, mappings)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	if err := repo.CreateJournalEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// accountBalance devolve o saldo pela natureza da conta (devedora ou credora)
+func accountBalance(row models.TrialBalanceRow) money.Decimal {
+	account := models.LedgerAccount{Type: row.AccountType}
+	if account.IsDebitNormal() {
+		return money.Round(row.Debit.Sub(row.Credit))
+	}
+	return money.Round(row.Credit.Sub(row.Debit))
+}
+
+func isValidAccountType(accountType string) bool {
+	switch accountType {
+	case models.AccountTypeAsset, models.AccountTypeLiability, models.AccountTypeEquity,
+		models.AccountTypeRevenue, models.AccountTypeExpense:
+		return true
+	}
+	return false
+}
+
+// normalizeDREGroup valida a linha da DRE da conta; vazia, a linha é removida e a conta entra na
+// DRE pelo tipo e pelos mapeamentos da contabilização automática
+func normalizeDREGroup(account *models.LedgerAccount) bool {
+	if account.DREGroup == nil || *account.DREGroup == "" {
+		account.DREGroup = nil
+		return true
+	}
+	switch *account.DREGroup {
+	case models.DREGrossRevenue, models.DREDeductions, models.DRECOGS,
+		models.DREOperatingExpenses, models.DREFinancialResult:
+		return true
+	}
+	return false
+}
+
+func isKnownMapping(key string) bool {
+	switch key {
+	case models.MappingCash, models.MappingReceivables, models.MappingTaxRecoverable,
+		models.MappingPayables, models.MappingTaxPayable, models.MappingSalesRevenue,
+		models.MappingSalesDiscounts, models.MappingSalesReturns, models.MappingPurchases,
+		models.MappingExpenses, models.MappingReimbursementsPayable,
+		models.MappingFixedAssets, models.MappingAccumulatedDepreciation, models.MappingDepreciationExpense,
+		models.MappingAssetDisposalGain, models.MappingAssetDisposalLoss,
+		models.MappingLaborCosts, models.MappingPayrollPayable, models.MappingBankFees,
+		models.MappingCustomerAdvances:
+		return true
+	}
+	return false
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/accounting/models"
+	assets "ERP-ONSMART/backend/internal/modules/assets/models"
+	banking "ERP-ONSMART/backend/internal/modules/banking/models"
+	expenses "ERP-ONSMART/backend/internal/modules/expenses/models"
+	hr "ERP-ONSMART/backend/internal/modules/hr/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"time"
+)
+
+// BuildInvoiceEntry gera o lançamento de uma fatura emitida:
+// D Clientes (total) / D Descontos / D Adiantamentos de Clientes (abatido) / C Receita de Vendas /
+// C Impostos a Recolher. A fatura de adiantamento não é receita: D Clientes / C Adiantamentos de
+// Clientes, baixado quando o adiantamento é abatido das faturas dos itens.
+func BuildInvoiceEntry(invoice *sales.Invoice, mappings map[string]int) (*models.JournalEntry, error) {
+	if invoice.Status == sales.InvoiceStatusDraft || invoice.Status == sales.InvoiceStatusCancelled {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	if invoice.Kind == sales.InvoiceKindAdvance {
+		lines, err := buildLines(mappings,
+			lineSpec{models.MappingReceivables, invoice.GrandTotal, money.Zero},
+			lineSpec{models.MappingCustomerAdvances, money.Zero, invoice.GrandTotal},
+		)
+		if err != nil {
+			return nil, err
+		}
+		return newDocumentEntry(models.SourceInvoice, invoice.ID, documentDate(invoice.IssueDate, invoice.CreatedAt),
+			fmt.Sprintf("Fatura de adiantamento %s", invoice.InvoiceNo), invoice.CostCenterID, lines)
+	}
+
+	revenue := money.Round(invoice.GrandTotal.Add(invoice.AdvanceDeduction).Sub(invoice.TaxTotal).Add(invoice.DiscountTotal))
+
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingReceivables, invoice.GrandTotal, money.Zero},
+		lineSpec{models.MappingSalesDiscounts, invoice.DiscountTotal, money.Zero},
+		lineSpec{models.MappingCustomerAdvances, invoice.AdvanceDeduction, money.Zero},
+		lineSpec{models.MappingSalesRevenue, money.Zero, revenue},
+		lineSpec{models.MappingTaxPayable, money.Zero, invoice.TaxTotal},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceInvoice, invoice.ID, documentDate(invoice.IssueDate, invoice.CreatedAt),
+		fmt.Sprintf("Fatura %s", invoice.InvoiceNo), invoice.CostCenterID, lines)
+}
+
+// BuildPaymentEntry gera o lançamento de um recebimento: D Caixa e Bancos / C Clientes
+func BuildPaymentEntry(payment *sales.Payment, mappings map[string]int) (*models.JournalEntry, error) {
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingCash, payment.Amount, money.Zero},
+		lineSpec{models.MappingReceivables, money.Zero, payment.Amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Recebimento da fatura #%d", payment.InvoiceID)
+	if payment.Invoice != nil && payment.Invoice.InvoiceNo != "" {
+		description = fmt.Sprintf("Recebimento da fatura %s", payment.Invoice.InvoiceNo)
+	}
+
+	return newDocumentEntry(models.SourcePayment, payment.ID, payment.PaymentDate, description, nil, lines)
+}
+
+// BuildCreditNoteEntry gera o lançamento de uma nota de crédito: D Devoluções e Abatimentos / C Clientes
+func BuildCreditNoteEntry(note *sales.CreditNote, mappings map[string]int) (*models.JournalEntry, error) {
+	if note.Status == sales.CreditNoteStatusCancelled {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingSalesReturns, note.Amount, money.Zero},
+		lineSpec{models.MappingReceivables, money.Zero, note.Amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceCreditNote, note.ID, documentDate(note.IssueDate, note.CreatedAt),
+		fmt.Sprintf("Nota de crédito %s", note.CreditNoteNo), note.CostCenterID, lines)
+}
+
+// BuildSupplierBillEntry gera o lançamento de uma conta a pagar:
+// D Compras / D Impostos a Recuperar / C Fornecedores (total)
+func BuildSupplierBillEntry(bill *sales.SupplierBill, mappings map[string]int) (*models.JournalEntry, error) {
+	if bill.Status == sales.SupplierBillStatusCancelled || bill.Status == sales.SupplierBillStatusDraft {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	purchases := money.Round(bill.GrandTotal.Sub(bill.TaxTotal))
+
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingPurchases, purchases, money.Zero},
+		lineSpec{models.MappingTaxRecoverable, bill.TaxTotal, money.Zero},
+		lineSpec{models.MappingPayables, money.Zero, bill.GrandTotal},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceSupplierBill, bill.ID, documentDate(bill.IssueDate, bill.CreatedAt),
+		fmt.Sprintf("Conta a pagar %s", bill.BillNo), bill.CostCenterID, lines)
+}
+
+// BuildExpenseEntry gera o lançamento da despesa aprovada de um colaborador, na data da despesa:
+// D Despesas (a conta da categoria ou a mapeada em "expenses") / C Reembolsos a Pagar
+func BuildExpenseEntry(expense *expenses.Expense, mappings map[string]int) (*models.JournalEntry, error) {
+	if expense.Status != expenses.StatusApproved && expense.Status != expenses.StatusReimbursed {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	if expense.Category != nil && expense.Category.LedgerAccountID != nil {
+		categoryMappings := make(map[string]int, len(mappings)+1)
+		for key, accountID := range mappings {
+			categoryMappings[key] = accountID
+		}
+		categoryMappings[models.MappingExpenses] = *expense.Category.LedgerAccountID
+		mappings = categoryMappings
+	}
+
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingExpenses, expense.Amount, money.Zero},
+		lineSpec{models.MappingReimbursementsPayable, money.Zero, expense.Amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceExpense, expense.ID, documentDate(expense.ExpenseDate, expense.CreatedAt),
+		fmt.Sprintf("Despesa #%d de %s: %s", expense.ID, expense.Employee, expense.Description), expense.CostCenterID, lines)
+}
+
+// BuildExpenseReimbursementEntry gera o lançamento do reembolso ao colaborador:
+// D Reembolsos a Pagar / C Caixa e Bancos
+func BuildExpenseReimbursementEntry(expense *expenses.Expense, mappings map[string]int) (*models.JournalEntry, error) {
+	if expense.Status != expenses.StatusReimbursed || expense.ReimbursedAt == nil {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingReimbursementsPayable, expense.Amount, money.Zero},
+		lineSpec{models.MappingCash, money.Zero, expense.Amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceExpenseReimbursement, expense.ID, *expense.ReimbursedAt,
+		fmt.Sprintf("Reembolso da despesa #%d a %s", expense.ID, expense.Employee), nil, lines)
+}
+
+// BuildAssetAcquisitionEntry gera a capitalização do bem na data da aquisição. A conta a pagar
+// do fornecedor lança a compra em Compras; a capitalização transfere o custo ao imobilizado:
+// D Imobilizado / C Compras
+func BuildAssetAcquisitionEntry(asset *assets.Asset, mappings map[string]int) (*models.JournalEntry, error) {
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingFixedAssets, asset.AcquisitionCost, money.Zero},
+		lineSpec{models.MappingPurchases, money.Zero, asset.AcquisitionCost},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceAssetAcquisition, asset.ID, asset.AcquisitionDate,
+		fmt.Sprintf("Aquisição do bem %s - %s", asset.AssetNo, asset.Name), asset.CostCenterID, lines)
+}
+
+// BuildAssetDepreciationEntry gera o lançamento da depreciação mensal do bem, no último dia do
+// mês: D Despesa de Depreciação / C Depreciação Acumulada
+func BuildAssetDepreciationEntry(depreciation *assets.AssetDepreciation, mappings map[string]int) (*models.JournalEntry, error) {
+	if depreciation.Asset == nil {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingDepreciationExpense, depreciation.Amount, money.Zero},
+		lineSpec{models.MappingAccumulatedDepreciation, money.Zero, depreciation.Amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceAssetDepreciation, depreciation.ID, depreciation.EntryDate,
+		fmt.Sprintf("Depreciação %s do bem %s", depreciation.Period, depreciation.Asset.AssetNo),
+		depreciation.Asset.CostCenterID, lines)
+}
+
+// BuildAssetDisposalEntry gera o lançamento da baixa do bem na data da baixa:
+// D Depreciação Acumulada / D Caixa e Bancos (valor recebido) / D Perda na Baixa /
+// C Imobilizado (custo) / C Ganho na Baixa
+func BuildAssetDisposalEntry(asset *assets.Asset, mappings map[string]int) (*models.JournalEntry, error) {
+	if (asset.Status != assets.StatusDisposed && asset.Status != assets.StatusWrittenOff) || asset.DisposedAt == nil {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	gain := money.Max(asset.DisposalGainLoss, money.Zero)
+	loss := money.Max(asset.DisposalGainLoss.Neg(), money.Zero)
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingAccumulatedDepreciation, asset.AccumulatedDepreciation, money.Zero},
+		lineSpec{models.MappingCash, asset.DisposalProceeds, money.Zero},
+		lineSpec{models.MappingAssetDisposalLoss, loss, money.Zero},
+		lineSpec{models.MappingFixedAssets, money.Zero, asset.AcquisitionCost},
+		lineSpec{models.MappingAssetDisposalGain, money.Zero, gain},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Venda do bem %s - %s", asset.AssetNo, asset.Name)
+	if asset.DisposalType == assets.DisposalWriteOff {
+		description = fmt.Sprintf("Baixa por perda do bem %s - %s", asset.AssetNo, asset.Name)
+	}
+	return newDocumentEntry(models.SourceAssetDisposal, asset.ID, *asset.DisposedAt, description, asset.CostCenterID, lines)
+}
+
+// BuildLaborCostEntry gera o lançamento da linha do rateio de mão de obra no último dia do mês,
+// no centro de custo da linha: D Salários e Encargos / C Salários e Encargos a Pagar
+func BuildLaborCostEntry(allocation *hr.LaborCostAllocation, mappings map[string]int) (*models.JournalEntry, error) {
+	if allocation.Employee == nil {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingLaborCosts, allocation.Amount, money.Zero},
+		lineSpec{models.MappingPayrollPayable, money.Zero, allocation.Amount},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceLaborCost, allocation.ID, hr.PeriodEnd(allocation.Period),
+		fmt.Sprintf("Mão de obra %s - %s", allocation.Period, allocation.Employee.Name),
+		allocation.CostCenterID, lines)
+}
+
+// BuildBankFeeEntry gera o lançamento da tarifa de cobrança do título do retorno, na data da
+// ocorrência (ou do processamento do arquivo): D Despesas Bancárias / C Caixa e Bancos
+func BuildBankFeeEntry(item *banking.BankReturnItem, mappings map[string]int) (*models.JournalEntry, error) {
+	if !item.FeeAmount.IsPositive() {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	date := time.Now()
+	if item.OccurredAt != nil {
+		date = *item.OccurredAt
+	} else if item.Return != nil {
+		date = item.Return.CreatedAt
+	}
+
+	lines, err := buildLines(mappings,
+		lineSpec{models.MappingBankFees, item.FeeAmount, money.Zero},
+		lineSpec{models.MappingCash, money.Zero, item.FeeAmount},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDocumentEntry(models.SourceBankFee, item.ID, date,
+		fmt.Sprintf("Tarifa de cobrança do boleto %s (ocorrência %s)", item.OurNumber, item.Occurrence),
+		nil, lines)
+}
+
+// ValidateEntry garante que o lançamento tenha ao menos duas partidas e débitos iguais aos créditos
+func ValidateEntry(entry *models.JournalEntry) error {
+	if len(entry.Lines) < 2 {
+		return errors.ErrUnbalancedEntry
+	}
+
+	var debit, credit money.Decimal
+	for _, line := range entry.Lines {
+		if line.Debit.IsNegative() || line.Credit.IsNegative() || (line.Debit.IsPositive() && line.Credit.IsPositive()) {
+			return errors.ErrUnbalancedEntry
+		}
+		debit = debit.Add(line.Debit)
+		credit = credit.Add(line.Credit)
+	}
+
+	if debit.IsZero() || !money.Round(debit).Equal(money.Round(credit)) {
+		return errors.ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// lineSpec descreve uma partida pela chave de mapeamento da conta
+type lineSpec struct {
+	mapping string
+	debit   money.Decimal
+	credit  money.Decimal
+}
+
+// buildLines resolve as contas e ignora partidas com valor zero
+func buildLines(mappings map[string]int, specs ...lineSpec) ([]models.JournalLine, error) {
+	var lines []models.JournalLine
+	for _, spec := range specs {
+		debit, credit := money.Round(spec.debit), money.Round(spec.credit)
+		if debit.IsZero() && credit.IsZero() {
+			continue
+		}
+
+		accountID, ok := mappings[spec.mapping]
+		if !ok {
+			return nil, errors.ErrAccountMappingMissing
+		}
+
+		// Valores negativos invertem o lado da partida
+		if debit.IsNegative() {
+			debit, credit = money.Zero, debit.Neg()
+		} else if credit.IsNegative() {
+			debit, credit = credit.Neg(), money.Zero
+		}
+
+		lines = append(lines, models.JournalLine{AccountID: accountID, Debit: debit, Credit: credit})
+	}
+	return lines, nil
+}
+
+// newDocumentEntry monta o lançamento do documento, herdando seu centro de custo para a DRE
+func newDocumentEntry(sourceType string, sourceID int, date time.Time, description string, costCenterID *int, lines []models.JournalLine) (*models.JournalEntry, error) {
+	id := sourceID
+	entry := &models.JournalEntry{
+		EntryDate:    date,
+		Description:  description,
+		SourceType:   sourceType,
+		SourceID:     &id,
+		CostCenterID: costCenterID,
+		Lines:        lines,
+	}
+
+	if err := ValidateEntry(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func documentDate(issueDate, createdAt time.Time) time.Time {
+	if !issueDate.IsZero() {
+		return issueDate
+	}
+	if !createdAt.IsZero() {
+		return createdAt
+	}
+	return time.Now()
+}
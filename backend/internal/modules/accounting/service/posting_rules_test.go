@@ -0,0 +1,365 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/accounting/models"
+	assets "ERP-ONSMART/backend/internal/modules/assets/models"
+	banking "ERP-ONSMART/backend/internal/modules/banking/models"
+	expenses "ERP-ONSMART/backend/internal/modules/expenses/models"
+	hr "ERP-ONSMART/backend/internal/modules/hr/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+)
+
+var testMappings = map[string]int{
+	models.MappingCash:           1,
+	models.MappingReceivables:    2,
+	models.MappingTaxRecoverable: 3,
+	models.MappingPayables:       4,
+	models.MappingTaxPayable:     5,
+	models.MappingSalesRevenue:   6,
+	models.MappingSalesDiscounts: 7,
+	models.MappingSalesReturns:   8,
+	models.MappingPurchases:      9,
+
+	models.MappingExpenses:              10,
+	models.MappingReimbursementsPayable: 11,
+
+	models.MappingFixedAssets:             12,
+	models.MappingAccumulatedDepreciation: 13,
+	models.MappingDepreciationExpense:     14,
+	models.MappingAssetDisposalGain:       15,
+	models.MappingAssetDisposalLoss:       16,
+
+	models.MappingLaborCosts:     17,
+	models.MappingPayrollPayable: 18,
+
+	models.MappingBankFees: 19,
+
+	models.MappingCustomerAdvances: 20,
+}
+
+func sumLines(entry *models.JournalEntry) (money.Decimal, money.Decimal) {
+	var debit, credit money.Decimal
+	for _, l := range entry.Lines {
+		debit = debit.Add(l.Debit)
+		credit = credit.Add(l.Credit)
+	}
+	return debit, credit
+}
+
+// TestBuildInvoiceEntry valida o lançamento balanceado de uma fatura com desconto e impostos.
+func TestBuildInvoiceEntry(t *testing.T) {
+	invoice := &sales.Invoice{
+		ID:            10,
+		InvoiceNo:     "INV-10",
+		Status:        sales.InvoiceStatusSent,
+		IssueDate:     time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		SubTotal:      money.FromInt(1000),
+		DiscountTotal: money.FromInt(100),
+		TaxTotal:      money.FromInt(90),
+		GrandTotal:    money.FromInt(990),
+	}
+
+	entry, err := BuildInvoiceEntry(invoice, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento da fatura: %v", err)
+	}
+
+	if len(entry.Lines) != 4 {
+		t.Errorf("Esperado 4 partidas, obtido %d", len(entry.Lines))
+	}
+
+	debit, credit := sumLines(entry)
+	if !debit.Equal(money.FromInt(1090)) || !credit.Equal(money.FromInt(1090)) {
+		t.Errorf("Esperado débito e crédito de 1090, obtido %s/%s", debit, credit)
+	}
+
+	if entry.SourceType != models.SourceInvoice || entry.SourceID == nil || *entry.SourceID != 10 {
+		t.Errorf("Origem do lançamento incorreta: %s %v", entry.SourceType, entry.SourceID)
+	}
+}
+
+// TestBuildInvoiceEntryDraft garante que faturas em rascunho não são contabilizadas.
+// TestBuildAdvanceInvoiceEntries valida o adiantamento como passivo e o abatimento na fatura final.
+func TestBuildAdvanceInvoiceEntries(t *testing.T) {
+	advance := &sales.Invoice{ID: 11, InvoiceNo: "INV-11", Status: sales.InvoiceStatusPaid, Kind: sales.InvoiceKindAdvance,
+		SubTotal: money.FromInt(300), GrandTotal: money.FromInt(300)}
+
+	entry, err := BuildInvoiceEntry(advance, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento do adiantamento: %v", err)
+	}
+	if len(entry.Lines) != 2 || entry.Lines[0].AccountID != 2 || entry.Lines[1].AccountID != 20 ||
+		!entry.Lines[1].Credit.Equal(money.FromInt(300)) {
+		t.Errorf("Adiantamento deve ser D Clientes / C Adiantamentos de Clientes: %+v", entry.Lines)
+	}
+
+	final := &sales.Invoice{ID: 12, InvoiceNo: "INV-12", Status: sales.InvoiceStatusSent,
+		SubTotal: money.FromInt(1000), TaxTotal: money.FromInt(90), GrandTotal: money.FromInt(790), AdvanceDeduction: money.FromInt(300)}
+
+	entry, err = BuildInvoiceEntry(final, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento da fatura final: %v", err)
+	}
+	var advances, revenue money.Decimal
+	for _, line := range entry.Lines {
+		switch line.AccountID {
+		case 20:
+			advances = line.Debit
+		case 6:
+			revenue = line.Credit
+		}
+	}
+	if !advances.Equal(money.FromInt(300)) || !revenue.Equal(money.FromInt(1000)) {
+		t.Errorf("Esperado débito de 300 em adiantamentos e receita de 1000, obtido %s/%s", advances, revenue)
+	}
+}
+
+func TestBuildInvoiceEntryDraft(t *testing.T) {
+	_, err := BuildInvoiceEntry(&sales.Invoice{Status: sales.InvoiceStatusDraft, GrandTotal: money.FromInt(10)}, testMappings)
+	if err != errors.ErrDocumentNotPostable {
+		t.Errorf("Esperado ErrDocumentNotPostable, obtido %v", err)
+	}
+}
+
+// TestBuildPaymentEntry valida o lançamento de recebimento.
+func TestBuildPaymentEntry(t *testing.T) {
+	payment := &sales.Payment{ID: 3, InvoiceID: 10, Amount: money.MustParse("250.5"), PaymentDate: time.Now()}
+
+	entry, err := BuildPaymentEntry(payment, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento do pagamento: %v", err)
+	}
+
+	if entry.Lines[0].AccountID != testMappings[models.MappingCash] || !entry.Lines[0].Debit.Equal(money.MustParse("250.5")) {
+		t.Errorf("Esperado débito de 250.50 em Caixa, obtido %+v", entry.Lines[0])
+	}
+	if entry.Lines[1].AccountID != testMappings[models.MappingReceivables] || !entry.Lines[1].Credit.Equal(money.MustParse("250.5")) {
+		t.Errorf("Esperado crédito de 250.50 em Clientes, obtido %+v", entry.Lines[1])
+	}
+}
+
+// TestBuildSupplierBillEntry valida o lançamento de uma conta a pagar.
+func TestBuildSupplierBillEntry(t *testing.T) {
+	costCenter := 3
+	bill := &sales.SupplierBill{ID: 5, BillNo: "B-5", Status: sales.SupplierBillStatusOpen, SubTotal: money.FromInt(500), TaxTotal: money.FromInt(50), GrandTotal: money.FromInt(550), CostCenterID: &costCenter}
+
+	entry, err := BuildSupplierBillEntry(bill, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento da conta a pagar: %v", err)
+	}
+
+	debit, credit := sumLines(entry)
+	if !debit.Equal(money.FromInt(550)) || !credit.Equal(money.FromInt(550)) {
+		t.Errorf("Esperado débito e crédito de 550, obtido %s/%s", debit, credit)
+	}
+	if entry.CostCenterID == nil || *entry.CostCenterID != costCenter {
+		t.Errorf("Esperado o centro de custo da conta no lançamento, obtido %v", entry.CostCenterID)
+	}
+}
+
+// TestBuildEntryMissingMapping garante erro quando a conta da operação não está configurada.
+func TestBuildEntryMissingMapping(t *testing.T) {
+	note := &sales.CreditNote{ID: 1, Status: sales.CreditNoteStatusIssued, Amount: money.FromInt(20)}
+
+	_, err := BuildCreditNoteEntry(note, map[string]int{models.MappingReceivables: 2})
+	if err != errors.ErrAccountMappingMissing {
+		t.Errorf("Esperado ErrAccountMappingMissing, obtido %v", err)
+	}
+}
+
+// TestValidateEntry valida a regra das partidas dobradas.
+func TestValidateEntry(t *testing.T) {
+	unbalanced := &models.JournalEntry{Lines: []models.JournalLine{
+		{AccountID: 1, Debit: money.FromInt(100)},
+		{AccountID: 2, Credit: money.FromInt(90)},
+	}}
+	if err := ValidateEntry(unbalanced); err != errors.ErrUnbalancedEntry {
+		t.Errorf("Esperado ErrUnbalancedEntry, obtido %v", err)
+	}
+
+	bothSides := &models.JournalEntry{Lines: []models.JournalLine{
+		{AccountID: 1, Debit: money.FromInt(100), Credit: money.FromInt(100)},
+		{AccountID: 2, Debit: money.Zero, Credit: money.Zero},
+	}}
+	if err := ValidateEntry(bothSides); err != errors.ErrUnbalancedEntry {
+		t.Errorf("Esperado ErrUnbalancedEntry para partida com débito e crédito, obtido %v", err)
+	}
+}
+
+// TestBuildProfitAndLoss valida a apuração do resultado a partir dos saldos.
+func TestBuildProfitAndLoss(t *testing.T) {
+	rows := []models.TrialBalanceRow{
+		{AccountID: 2, AccountType: models.AccountTypeAsset, Debit: money.FromInt(990), Credit: money.FromInt(250)},
+		{AccountID: 6, AccountType: models.AccountTypeRevenue, Credit: money.FromInt(1000)},
+		{AccountID: 7, AccountType: models.AccountTypeRevenue, Debit: money.FromInt(100)},
+		{AccountID: 9, AccountType: models.AccountTypeExpense, Debit: money.FromInt(500)},
+	}
+
+	pl := BuildProfitAndLoss(rows, time.Time{}, time.Time{})
+	if !pl.TotalRevenue.Equal(money.FromInt(900)) || !pl.TotalExpense.Equal(money.FromInt(500)) || !pl.NetIncome.Equal(money.FromInt(400)) {
+		t.Errorf("Esperado receita 900, despesa 500 e resultado 400, obtido %s/%s/%s",
+			pl.TotalRevenue, pl.TotalExpense, pl.NetIncome)
+	}
+
+	tb := BuildTrialBalance(rows, time.Now())
+	if !tb.Rows[0].Balance.Equal(money.FromInt(740)) {
+		t.Errorf("Esperado saldo devedor de 740, obtido %s", tb.Rows[0].Balance)
+	}
+}
+
+// TestBuildExpenseEntries valida a despesa aprovada na conta da categoria e o reembolso contra o caixa.
+func TestBuildExpenseEntries(t *testing.T) {
+	categoryAccount := 42
+	reimbursedAt := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	expense := &expenses.Expense{
+		ID:          3,
+		Employee:    "ana",
+		Description: "Táxi",
+		ExpenseDate: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC),
+		Amount:      money.FromInt(80),
+		Status:      expenses.StatusSubmitted,
+		Category:    &expenses.ExpenseCategory{ID: 1, LedgerAccountID: &categoryAccount},
+	}
+
+	if _, err := BuildExpenseEntry(expense, testMappings); err != errors.ErrDocumentNotPostable {
+		t.Errorf("Esperado ErrDocumentNotPostable para despesa não aprovada, obtido %v", err)
+	}
+
+	expense.Status = expenses.StatusApproved
+	entry, err := BuildExpenseEntry(expense, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento da despesa: %v", err)
+	}
+	if entry.Lines[0].AccountID != categoryAccount || entry.Lines[1].AccountID != 11 {
+		t.Errorf("Esperado D conta da categoria / C reembolsos a pagar, obtido %+v", entry.Lines)
+	}
+	if !entry.EntryDate.Equal(expense.ExpenseDate) || entry.SourceType != models.SourceExpense {
+		t.Errorf("Lançamento da despesa com data ou origem incorreta: %+v", entry)
+	}
+	if testMappings[models.MappingExpenses] != 10 {
+		t.Error("A conta da categoria não deve alterar os mapeamentos")
+	}
+
+	if _, err := BuildExpenseReimbursementEntry(expense, testMappings); err != errors.ErrDocumentNotPostable {
+		t.Errorf("Esperado ErrDocumentNotPostable para despesa não reembolsada, obtido %v", err)
+	}
+
+	expense.Status = expenses.StatusReimbursed
+	expense.ReimbursedAt = &reimbursedAt
+	entry, err = BuildExpenseReimbursementEntry(expense, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento do reembolso: %v", err)
+	}
+	if entry.Lines[0].AccountID != 11 || entry.Lines[1].AccountID != 1 || !entry.EntryDate.Equal(reimbursedAt) {
+		t.Errorf("Esperado D reembolsos a pagar / C caixa na data do reembolso, obtido %+v", entry)
+	}
+}
+
+// TestBuildAssetEntries valida a capitalização, a depreciação mensal e a baixa com perda de um bem.
+func TestBuildAssetEntries(t *testing.T) {
+	disposedAt := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+	asset := &assets.Asset{
+		ID:                      7,
+		AssetNo:                 "PAT-7",
+		Name:                    "Notebook",
+		AcquisitionDate:         time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+		AcquisitionCost:         money.FromInt(3600),
+		AccumulatedDepreciation: money.FromInt(800),
+		Status:                  assets.StatusActive,
+	}
+
+	entry, err := BuildAssetAcquisitionEntry(asset, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar capitalização do bem: %v", err)
+	}
+	if entry.Lines[0].AccountID != 12 || entry.Lines[1].AccountID != 9 || !entry.EntryDate.Equal(asset.AcquisitionDate) {
+		t.Errorf("Esperado D imobilizado / C compras na data da aquisição, obtido %+v", entry)
+	}
+
+	depreciation := &assets.AssetDepreciation{ID: 3, Period: "2026-02", EntryDate: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), Amount: money.FromInt(100), Asset: asset}
+	entry, err = BuildAssetDepreciationEntry(depreciation, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento da depreciação: %v", err)
+	}
+	if entry.Lines[0].AccountID != 14 || entry.Lines[1].AccountID != 13 || entry.SourceType != models.SourceAssetDepreciation {
+		t.Errorf("Esperado D despesa de depreciação / C depreciação acumulada, obtido %+v", entry)
+	}
+
+	if _, err := BuildAssetDisposalEntry(asset, testMappings); err != errors.ErrDocumentNotPostable {
+		t.Errorf("Esperado ErrDocumentNotPostable para bem ativo, obtido %v", err)
+	}
+
+	// Valor contábil 2800, vendido por 2500: perda de 300
+	asset.Status = assets.StatusDisposed
+	asset.DisposalType = assets.DisposalSale
+	asset.DisposedAt = &disposedAt
+	asset.DisposalProceeds = money.FromInt(2500)
+	asset.DisposalGainLoss = money.FromInt(-300)
+	entry, err = BuildAssetDisposalEntry(asset, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento da baixa: %v", err)
+	}
+	if err := ValidateEntry(entry); err != nil {
+		t.Fatalf("Lançamento da baixa desbalanceado: %+v", entry.Lines)
+	}
+	debit, _ := sumLines(entry)
+	if len(entry.Lines) != 4 || !debit.Equal(money.FromInt(3600)) || entry.Lines[2].AccountID != 16 {
+		t.Errorf("Esperado D depreciação 800 / D caixa 2500 / D perda 300 / C imobilizado 3600, obtido %+v", entry.Lines)
+	}
+}
+
+func TestBuildLaborCostEntry(t *testing.T) {
+	centerID := 4
+	allocation := &hr.LaborCostAllocation{ID: 9, Period: "2026-02", EmployeeID: 2, CostCenterID: &centerID, Amount: money.FromInt(2500)}
+	if _, err := BuildLaborCostEntry(allocation, testMappings); err != errors.ErrDocumentNotPostable {
+		t.Errorf("Esperado ErrDocumentNotPostable sem o colaborador, obtido %v", err)
+	}
+
+	allocation.Employee = &hr.Employee{ID: 2, Name: "Ana"}
+	entry, err := BuildLaborCostEntry(allocation, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento da mão de obra: %v", err)
+	}
+	if entry.Lines[0].AccountID != 17 || entry.Lines[1].AccountID != 18 || entry.SourceType != models.SourceLaborCost {
+		t.Errorf("Esperado D salários e encargos / C salários a pagar, obtido %+v", entry)
+	}
+	if !entry.EntryDate.Equal(time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Esperado lançamento no último dia do mês, obtido %v", entry.EntryDate)
+	}
+	if entry.CostCenterID == nil || *entry.CostCenterID != centerID {
+		t.Errorf("Esperado o centro de custo da linha do rateio, obtido %v", entry.CostCenterID)
+	}
+}
+
+func TestBuildBankFeeEntry(t *testing.T) {
+	item := &banking.BankReturnItem{ID: 5, OurNumber: "00000000042", Occurrence: "06", FeeAmount: money.Zero}
+	if _, err := BuildBankFeeEntry(item, testMappings); err != errors.ErrDocumentNotPostable {
+		t.Errorf("Esperado ErrDocumentNotPostable sem tarifa, obtido %v", err)
+	}
+
+	processed := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	item.FeeAmount = money.MustParse("2.50")
+	item.Return = &banking.BankReturn{ID: 1, CreatedAt: processed}
+	entry, err := BuildBankFeeEntry(item, testMappings)
+	if err != nil {
+		t.Fatalf("Erro ao gerar lançamento da tarifa: %v", err)
+	}
+	debit, credit := sumLines(entry)
+	if entry.Lines[0].AccountID != 19 || entry.Lines[1].AccountID != 1 || !debit.Equal(credit) || !debit.Equal(item.FeeAmount) {
+		t.Errorf("Esperado D despesas bancárias / C caixa de 2,50, obtido %+v", entry.Lines)
+	}
+	if entry.SourceType != models.SourceBankFee || !entry.EntryDate.Equal(processed) {
+		t.Errorf("Esperado lançamento bank_fee na data do processamento, obtido %+v", entry)
+	}
+
+	occurred := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	item.OccurredAt = &occurred
+	entry, err = BuildBankFeeEntry(item, testMappings)
+	if err != nil || !entry.EntryDate.Equal(occurred) {
+		t.Errorf("Esperado lançamento na data da ocorrência, obtido %v (%v)", entry, err)
+	}
+}
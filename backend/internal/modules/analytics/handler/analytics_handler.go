@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/analytics/models"
+	"ERP-ONSMART/backend/internal/modules/analytics/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Retorna a previsão mensal de receita e de pedidos de venda, no total e por categoria e segmento
+// O histórico são os últimos 36 meses completos de faturas emitidas e pedidos de venda; a previsão
+// começa no mês corrente e usa Holt-Winters (sazonalidade anual) ou média móvel nas séries curtas.
+// @Param horizon query string false "horizonte em meses ou anos, ex.: 6m, 1y (padrão 6m, máx. 24 meses)"
+// @Security BearerAuth
+func GetSalesForecastHandler(c *gin.Context) {
+	forecast, err := service.GetSalesForecast(c.Request.Context(), c.Query("horizon"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar previsão de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// Lista as pontuações RFM (recência, frequência e valor) dos clientes para os públicos das campanhas
+// Cada nota vai de 1 a 5 pelo quintil do cliente entre os da empresa, na última pontuação calculada.
+// @Param segment query string false "segmentos separados por vírgula (champion, loyal, promising, need_attention, at_risk, dormant)"
+// @Param min_recency query int false "nota mínima de recência (1 a 5)"
+// @Param min_frequency query int false "nota mínima de frequência (1 a 5)"
+// @Param min_monetary query int false "nota mínima de valor (1 a 5)"
+// @Param search query string false "busca por nome, e-mail ou documento do contato"
+// @Security BearerAuth
+func ListRFMScoresHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	segments, err := models.ParseSegments(c.Query("segment"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	filter := models.RFMFilter{Segments: segments, Search: strings.TrimSpace(c.Query("search"))}
+	for _, param := range []struct {
+		name string
+		dest *int
+	}{
+		{"min_recency", &filter.MinRecency},
+		{"min_frequency", &filter.MinFrequency},
+		{"min_monetary", &filter.MinMonetary},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		score, err := strconv.Atoi(value)
+		if err != nil || score < 1 || score > models.RFMScoreCount {
+			c.Error(errors.InvalidParam(param.name + " deve ser uma nota de 1 a 5"))
+			return
+		}
+		*param.dest = score
+	}
+
+	result, err := service.ListRFMScores(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar pontuações RFM")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna a quantidade de clientes e o valor faturado de cada segmento RFM
+// @Security BearerAuth
+func GetRFMSummaryHandler(c *gin.Context) {
+	summary, err := service.GetRFMSummary(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao resumir segmentos RFM")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"segments": summary})
+}
+
+// Recalcula as pontuações RFM e o segmento dos clientes da empresa a partir das faturas emitidas
+// @Success 200 "Resumo do recálculo (empresas, contatos e clientes por segmento)"
+// @Security BearerAuth
+func ScoreRFMHandler(c *gin.Context) {
+	run, err := service.ScoreRFM(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao recalcular pontuações RFM")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Horizonte da previsão, em meses
+const (
+	DefaultHorizonMonths = 6
+	MaxHorizonMonths     = 24
+
+	// HistoryMonths é a janela de histórico usada pelos modelos (três temporadas completas)
+	HistoryMonths = 36
+	// SeasonLength é a sazonalidade anual das séries mensais
+	SeasonLength = 12
+)
+
+// Métodos de previsão: Holt-Winters aditivo quando a série tem ao menos duas temporadas,
+// senão a média móvel dos últimos meses
+const (
+	MethodHoltWinters   = "holt_winters"
+	MethodMovingAverage = "moving_average"
+)
+
+// Grupos usados para os registros sem categoria de produto ou sem tipo de pessoa no contato
+const (
+	NoCategory = "sem_categoria"
+	NoSegment  = "sem_segmento"
+)
+
+// MonthLayout é o formato dos meses na resposta (AAAA-MM)
+const MonthLayout = "2006-01"
+
+// MonthlyValue é o total de um mês para um grupo (categoria ou segmento)
+type MonthlyValue struct {
+	Month time.Time
+	Key   string
+	Value float64
+}
+
+// ForecastHistory reúne o histórico mensal de receita (itens das faturas emitidas) e de
+// volume de pedidos de venda, por categoria de produto e por segmento de cliente
+type ForecastHistory struct {
+	RevenueByCategory []MonthlyValue
+	RevenueBySegment  []MonthlyValue
+	OrdersByCategory  []MonthlyValue
+	OrdersBySegment   []MonthlyValue
+}
+
+// SalesForecast é a previsão mensal de receita e de pedidos para o painel de planejamento
+type SalesForecast struct {
+	HorizonMonths int              `json:"horizon_months"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+	HistoryFrom   string           `json:"history_from"`
+	HistoryTo     string           `json:"history_to"`
+	Total         SeriesForecast   `json:"total"`
+	ByCategory    []SeriesForecast `json:"by_category"`
+	BySegment     []SeriesForecast `json:"by_segment"`
+}
+
+// SeriesForecast contém o histórico e a previsão de um grupo. Os pedidos por categoria
+// contam cada pedido em todas as categorias dos seus itens, então não somam o total.
+type SeriesForecast struct {
+	Key      string          `json:"key,omitempty"`
+	Method   ForecastMethods `json:"method"`
+	History  []MonthlyPoint  `json:"history"`
+	Forecast []MonthlyPoint  `json:"forecast"`
+}
+
+// ForecastMethods indica o método aplicado em cada série do grupo
+type ForecastMethods struct {
+	Revenue string `json:"revenue"`
+	Orders  string `json:"orders"`
+}
+
+// MonthlyPoint é o valor de receita e de pedidos de um mês
+type MonthlyPoint struct {
+	Month   string  `json:"month"`
+	Revenue float64 `json:"revenue"`
+	Orders  float64 `json:"orders"`
+}
+
+// ParseHorizon interpreta o horizonte em meses ("6m", "1y" ou "6"); vazio usa o padrão
+func ParseHorizon(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return DefaultHorizonMonths, nil
+	}
+
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(value, "m"):
+		value = strings.TrimSuffix(value, "m")
+	case strings.HasSuffix(value, "y"):
+		value = strings.TrimSuffix(value, "y")
+		multiplier = 12
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, errors.InvalidParam("horizonte inválido: use meses (6m) ou anos (1y)")
+	}
+	if n*multiplier > MaxHorizonMonths {
+		return 0, errors.InvalidParam("horizonte máximo de " + strconv.Itoa(MaxHorizonMonths) + " meses")
+	}
+	return n * multiplier, nil
+}
+
+// MonthStart retorna o primeiro dia do mês da data
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
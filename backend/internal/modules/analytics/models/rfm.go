@@ -0,0 +1,125 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"strings"
+	"time"
+)
+
+// Segmentos RFM gravados no contato (contacts.rfm_segment)
+const (
+	SegmentChampion      = "champion"       // compra com frequência, recentemente e com valor alto
+	SegmentLoyal         = "loyal"          // compra com frequência e ainda recentemente
+	SegmentPromising     = "promising"      // comprou há pouco, mas poucas vezes
+	SegmentNeedAttention = "need_attention" // recência e frequência medianas
+	SegmentAtRisk        = "at_risk"        // comprava com frequência e parou
+	SegmentDormant       = "dormant"        // poucas compras, a última há muito tempo
+)
+
+// Segments lista os segmentos aceitos no filtro de GET /analytics/rfm
+var Segments = []string{
+	SegmentChampion, SegmentLoyal, SegmentPromising, SegmentNeedAttention, SegmentAtRisk, SegmentDormant,
+}
+
+// RFMScoreCount é a quantidade de notas de cada dimensão (quintis)
+const RFMScoreCount = 5
+
+// ContactRFM é a pontuação RFM de um cliente, calculada a partir das faturas emitidas
+type ContactRFM struct {
+	ContactID      int       `json:"contact_id" gorm:"primaryKey;autoIncrement:false"`
+	CompanyID      int       `json:"company_id" gorm:"<-:create"`
+	LastInvoiceAt  time.Time `json:"last_invoice_at"`
+	RecencyDays    int       `json:"recency_days"`
+	Frequency      int       `json:"frequency"`
+	Monetary       float64   `json:"monetary"`
+	RecencyScore   int       `json:"recency_score"`
+	FrequencyScore int       `json:"frequency_score"`
+	MonetaryScore  int       `json:"monetary_score"`
+	Segment        string    `json:"segment"`
+	ScoredAt       time.Time `json:"scored_at"`
+
+	// Relationships
+	Contact *contact.Contact `json:"contact,omitempty" gorm:"foreignKey:ContactID"`
+}
+
+// TableName define o nome da tabela para o modelo ContactRFM
+func (ContactRFM) TableName() string {
+	return "contact_rfm_scores"
+}
+
+// CustomerHistory resume as faturas emitidas de um cliente
+type CustomerHistory struct {
+	CompanyID     int
+	ContactID     int
+	LastInvoiceAt time.Time
+	Frequency     int
+	Monetary      float64
+}
+
+// RFMFilter são os filtros de GET /analytics/rfm; as notas mínimas valem de 1 a 5 (0 ignora)
+type RFMFilter struct {
+	Segments     []string
+	MinRecency   int
+	MinFrequency int
+	MinMonetary  int
+	Search       string
+}
+
+// RFMSummary conta os clientes de cada segmento na última pontuação
+type RFMSummary struct {
+	Segment  string  `json:"segment"`
+	Contacts int     `json:"contacts"`
+	Monetary float64 `json:"monetary"`
+}
+
+// RFMRun resume um recálculo das pontuações
+type RFMRun struct {
+	Companies int            `json:"companies"`
+	Contacts  int            `json:"contacts"`
+	Segments  map[string]int `json:"segments"`
+	ScoredAt  time.Time      `json:"scored_at"`
+}
+
+// SegmentFor classifica o cliente pelas notas de recência, frequência e valor
+func SegmentFor(recency, frequency, monetary int) string {
+	switch {
+	case recency >= 4 && frequency >= 4 && monetary >= 4:
+		return SegmentChampion
+	case recency >= 3 && frequency >= 3:
+		return SegmentLoyal
+	case recency >= 4:
+		return SegmentPromising
+	case recency <= 2 && frequency >= 3:
+		return SegmentAtRisk
+	case recency <= 2:
+		return SegmentDormant
+	default:
+		return SegmentNeedAttention
+	}
+}
+
+// ParseSegments interpreta a lista de segmentos separados por vírgula
+func ParseSegments(value string) ([]string, error) {
+	var segments []string
+	for _, segment := range strings.Split(value, ",") {
+		segment = strings.ToLower(strings.TrimSpace(segment))
+		if segment == "" {
+			continue
+		}
+		if !isSegment(segment) {
+			return nil, errors.InvalidParam("segmento inválido: " + segment + " (aceitos: " + strings.Join(Segments, ", ") + ")")
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+func isSegment(value string) bool {
+	for _, segment := range Segments {
+		if segment == value {
+			return true
+		}
+	}
+	return false
+}
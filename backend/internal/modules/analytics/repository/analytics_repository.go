@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/analytics/models"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// rfmBatchSize limita a quantidade de pontuações por INSERT
+const rfmBatchSize = 500
+
+// AnalyticsRepository lê as séries históricas usadas nas previsões de vendas e mantém as
+// pontuações RFM dos clientes
+type AnalyticsRepository interface {
+	GetForecastHistory(ctx context.Context, from, to time.Time) (*models.ForecastHistory, error)
+	GetCustomerHistories(ctx context.Context) ([]models.CustomerHistory, error)
+	SaveRFMScores(ctx context.Context, companyID int, scores []models.ContactRFM) error
+	ListRFMScores(ctx context.Context, filter models.RFMFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetRFMSummary(ctx context.Context) ([]models.RFMSummary, error)
+}
+
+// Faturas em rascunho ou canceladas e pedidos em rascunho ou cancelados não entram no histórico
+var (
+	excludedInvoiceStatuses = []string{sales.InvoiceStatusDraft, sales.InvoiceStatusCancelled}
+	excludedOrderStatuses   = []string{sales.SOStatusDraft, sales.SOStatusCancelled}
+)
+
+// Expressões de agrupamento: categoria do produto e tipo de pessoa (pf/pj) do contato
+const (
+	categoryExpr = "COALESCE(NULLIF(p.product_category, ''), '" + models.NoCategory + "')"
+	segmentExpr  = "COALESCE(NULLIF(c.person_type, ''), '" + models.NoSegment + "')"
+)
+
+type analyticsRepository struct {
+	db     *gorm.DB
+	reader *gorm.DB // réplica de leitura para as consultas analíticas (ver db.Provider)
+	logger *zap.Logger
+}
+
+// NewAnalyticsRepository cria uma nova instância do repositório
+func NewAnalyticsRepository() (AnalyticsRepository, error) {
+	provider, err := db.OpenProvider()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &analyticsRepository{
+		db:     provider.Writer(),
+		reader: provider.Reader(),
+		logger: logger.WithModule("analytics_repository"),
+	}, nil
+}
+
+// GetForecastHistory agrupa por mês a receita das faturas emitidas (pela data de emissão) e
+// os pedidos de venda (pela data de criação) no período [from, to)
+func (r *analyticsRepository) GetForecastHistory(ctx context.Context, from, to time.Time) (*models.ForecastHistory, error) {
+	history := &models.ForecastHistory{}
+
+	revenue := func() *gorm.DB {
+		return r.reader.WithContext(ctx).Table("invoice_items ii").
+			Joins("JOIN invoices i ON i.id = ii.invoice_id").
+			Joins("LEFT JOIN products p ON p.id = ii.product_id").
+			Joins("LEFT JOIN contacts c ON c.id = i.contact_id").
+			Scopes(tenant.Scope(ctx, "i")).
+			Where("i.deleted_at IS NULL AND i.status NOT IN ? AND i.issue_date >= ? AND i.issue_date < ?", excludedInvoiceStatuses, from, to)
+	}
+	orders := func() *gorm.DB {
+		return r.reader.WithContext(ctx).Table("sales_orders so").
+			Joins("LEFT JOIN contacts c ON c.id = so.contact_id").
+			Scopes(tenant.Scope(ctx, "so")).
+			Where("so.deleted_at IS NULL AND so.status NOT IN ? AND so.created_at >= ? AND so.created_at < ?", excludedOrderStatuses, from, to)
+	}
+
+	const (
+		invoiceMonth = "date_trunc('month', i.issue_date)"
+		orderMonth   = "date_trunc('month', so.created_at)"
+	)
+	queries := []struct {
+		query *gorm.DB
+		month string
+		key   string
+		value string
+		dest  *[]models.MonthlyValue
+		what  string
+	}{
+		{revenue(), invoiceMonth, categoryExpr, "COALESCE(SUM(ii.total), 0)", &history.RevenueByCategory, "receita por categoria"},
+		{revenue(), invoiceMonth, segmentExpr, "COALESCE(SUM(ii.total), 0)", &history.RevenueBySegment, "receita por segmento"},
+		{orders().
+			Joins("JOIN sales_order_items soi ON soi.sales_order_id = so.id").
+			Joins("LEFT JOIN products p ON p.id = soi.product_id"),
+			orderMonth, categoryExpr, "COUNT(DISTINCT so.id)", &history.OrdersByCategory, "pedidos por categoria"},
+		{orders(), orderMonth, segmentExpr, "COUNT(so.id)", &history.OrdersBySegment, "pedidos por segmento"},
+	}
+	for _, q := range queries {
+		if err := q.query.
+			Select(q.month + " AS month, " + q.key + " AS key, " + q.value + " AS value").
+			Group("1, 2").
+			Order("1, 2").
+			Scan(q.dest).Error; err != nil {
+			r.logger.Error("erro ao carregar histórico de "+q.what, zap.Error(err))
+			return nil, errors.WrapError(err, "falha ao carregar histórico de "+q.what)
+		}
+	}
+	return history, nil
+}
+
+// GetCustomerHistories resume as faturas emitidas de cada cliente (contatos fora da lixeira)
+// das empresas do contexto; o recálculo agendado usa tenant.AllCompanies
+func (r *analyticsRepository) GetCustomerHistories(ctx context.Context) ([]models.CustomerHistory, error) {
+	var histories []models.CustomerHistory
+	if err := r.reader.WithContext(ctx).Table("invoices i").
+		Joins("JOIN contacts c ON c.id = i.contact_id AND c.deleted_at IS NULL").
+		Scopes(tenant.Scope(ctx, "i")).
+		Where("i.deleted_at IS NULL AND i.status NOT IN ?", excludedInvoiceStatuses).
+		Select("i.company_id, i.contact_id, MAX(i.issue_date) AS last_invoice_at, COUNT(*) AS frequency, COALESCE(SUM(i.grand_total), 0) AS monetary").
+		Group("i.company_id, i.contact_id").
+		Order("i.company_id, i.contact_id").
+		Scan(&histories).Error; err != nil {
+		r.logger.Error("erro ao resumir faturas por cliente", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao resumir faturas por cliente")
+	}
+	return histories, nil
+}
+
+// SaveRFMScores substitui as pontuações da empresa e grava o segmento de cada contato; os
+// contatos que deixaram de ser pontuados ficam sem segmento
+func (r *analyticsRepository) SaveRFMScores(ctx context.Context, companyID int, scores []models.ContactRFM) error {
+	ctx = tenant.WithCompany(ctx, companyID)
+	tx := r.db.WithContext(ctx).Begin()
+
+	var segmented []int
+	if err := tx.Table("contacts").
+		Where("company_id = ? AND rfm_segment <> ''", companyID).
+		Pluck("id", &segmented).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao listar contatos segmentados", zap.Error(err), zap.Int("company_id", companyID))
+		return errors.WrapError(err, "falha ao listar contatos segmentados")
+	}
+
+	if err := tx.Where("company_id = ?", companyID).Delete(&models.ContactRFM{}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao remover pontuações RFM", zap.Error(err), zap.Int("company_id", companyID))
+		return errors.WrapError(err, "falha ao remover pontuações RFM")
+	}
+	if len(scores) > 0 {
+		if err := tx.Omit("Contact").CreateInBatches(scores, rfmBatchSize).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao gravar pontuações RFM", zap.Error(err), zap.Int("company_id", companyID))
+			return errors.WrapError(err, "falha ao gravar pontuações RFM")
+		}
+	}
+
+	if err := tx.Exec("UPDATE contacts SET rfm_segment = '' WHERE company_id = ? AND rfm_segment <> ''", companyID).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao limpar segmento RFM dos contatos", zap.Error(err), zap.Int("company_id", companyID))
+		return errors.WrapError(err, "falha ao limpar segmento RFM dos contatos")
+	}
+	if err := tx.Exec(`UPDATE contacts c SET rfm_segment = s.segment
+		FROM contact_rfm_scores s
+		WHERE s.contact_id = c.id AND s.company_id = ?`, companyID).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao gravar segmento RFM dos contatos", zap.Error(err), zap.Int("company_id", companyID))
+		return errors.WrapError(err, "falha ao gravar segmento RFM dos contatos")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	for _, score := range scores {
+		segmented = append(segmented, score.ContactID)
+	}
+	contactRepository.InvalidateContacts(segmented...)
+
+	r.logger.Info("pontuações RFM gravadas", zap.Int("company_id", companyID), zap.Int("contacts", len(scores)))
+	return nil
+}
+
+// ListRFMScores lista as pontuações da empresa com o contato, das maiores notas para as menores
+func (r *analyticsRepository) ListRFMScores(ctx context.Context, filter models.RFMFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := r.reader.WithContext(ctx).Model(&models.ContactRFM{})
+	if len(filter.Segments) > 0 {
+		query = query.Where("contact_rfm_scores.segment IN ?", filter.Segments)
+	}
+	if filter.MinRecency > 0 {
+		query = query.Where("contact_rfm_scores.recency_score >= ?", filter.MinRecency)
+	}
+	if filter.MinFrequency > 0 {
+		query = query.Where("contact_rfm_scores.frequency_score >= ?", filter.MinFrequency)
+	}
+	if filter.MinMonetary > 0 {
+		query = query.Where("contact_rfm_scores.monetary_score >= ?", filter.MinMonetary)
+	}
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		query = query.Joins("JOIN contacts ON contacts.id = contact_rfm_scores.contact_id").
+			Where("contacts.name ILIKE ? OR contacts.email ILIKE ? OR contacts.document ILIKE ?", search, search, search)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar pontuações RFM", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar pontuações RFM")
+	}
+
+	var scores []models.ContactRFM
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Preload("Contact").
+		Order("contact_rfm_scores.recency_score + contact_rfm_scores.frequency_score + contact_rfm_scores.monetary_score DESC, contact_rfm_scores.monetary DESC, contact_rfm_scores.contact_id").
+		Offset(offset).Limit(params.PageSize).
+		Find(&scores).Error; err != nil {
+		r.logger.Error("erro ao listar pontuações RFM", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar pontuações RFM")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, scores), nil
+}
+
+// GetRFMSummary conta os clientes e soma o valor faturado de cada segmento da empresa
+func (r *analyticsRepository) GetRFMSummary(ctx context.Context) ([]models.RFMSummary, error) {
+	summary := make([]models.RFMSummary, 0)
+	if err := r.reader.WithContext(ctx).Model(&models.ContactRFM{}).
+		Select("segment, COUNT(*) AS contacts, COALESCE(SUM(monetary), 0) AS monetary").
+		Group("segment").
+		Order("contacts DESC").
+		Scan(&summary).Error; err != nil {
+		r.logger.Error("erro ao resumir segmentos RFM", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao resumir segmentos RFM")
+	}
+	return summary, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/analytics/models"
+	"ERP-ONSMART/backend/internal/modules/analytics/repository"
+)
+
+// GetSalesForecast prevê a receita e os pedidos dos próximos meses (a partir do mês corrente,
+// que ainda não fechou) com base nos últimos models.HistoryMonths meses completos
+func GetSalesForecast(ctx context.Context, horizon string) (*models.SalesForecast, error) {
+	months, err := models.ParseHorizon(horizon)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	to := models.MonthStart(now)
+	from := to.AddDate(0, -models.HistoryMonths, 0)
+
+	repo, err := repository.NewAnalyticsRepository()
+	if err != nil {
+		return nil, err
+	}
+	history, err := repo.GetForecastHistory(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return buildForecast(history, from, months, now), nil
+}
+
+// buildForecast monta as séries mensais a partir de from e aplica a previsão ao total e a cada
+// categoria e segmento. O total soma os segmentos, pois cada fatura e pedido tem um só contato.
+func buildForecast(history *models.ForecastHistory, from time.Time, horizon int, now time.Time) *models.SalesForecast {
+	months := make([]string, models.HistoryMonths)
+	for i := range months {
+		months[i] = from.AddDate(0, i, 0).Format(models.MonthLayout)
+	}
+	ahead := make([]string, horizon)
+	for i := range ahead {
+		ahead[i] = from.AddDate(0, models.HistoryMonths+i, 0).Format(models.MonthLayout)
+	}
+
+	revenueBySegment := groupSeries(history.RevenueBySegment, months)
+	ordersBySegment := groupSeries(history.OrdersBySegment, months)
+
+	totalRevenue := make([]float64, len(months))
+	totalOrders := make([]float64, len(months))
+	for _, series := range revenueBySegment {
+		addSeries(totalRevenue, series)
+	}
+	for _, series := range ordersBySegment {
+		addSeries(totalOrders, series)
+	}
+
+	return &models.SalesForecast{
+		HorizonMonths: horizon,
+		GeneratedAt:   now,
+		HistoryFrom:   months[0],
+		HistoryTo:     months[len(months)-1],
+		Total:         seriesForecast("", totalRevenue, totalOrders, months, ahead),
+		ByCategory:    groupForecasts(groupSeries(history.RevenueByCategory, months), groupSeries(history.OrdersByCategory, months), months, ahead),
+		BySegment:     groupForecasts(revenueBySegment, ordersBySegment, months, ahead),
+	}
+}
+
+// groupSeries distribui os valores de cada grupo nos meses do histórico (meses sem movimento ficam zerados)
+func groupSeries(values []models.MonthlyValue, months []string) map[string][]float64 {
+	index := make(map[string]int, len(months))
+	for i, month := range months {
+		index[month] = i
+	}
+
+	groups := make(map[string][]float64)
+	for _, value := range values {
+		i, ok := index[value.Month.Format(models.MonthLayout)]
+		if !ok {
+			continue
+		}
+		if groups[value.Key] == nil {
+			groups[value.Key] = make([]float64, len(months))
+		}
+		groups[value.Key][i] += value.Value
+	}
+	return groups
+}
+
+func addSeries(total, series []float64) {
+	for i, value := range series {
+		total[i] += value
+	}
+}
+
+// groupForecasts prevê cada grupo presente na receita ou nos pedidos, do maior faturamento para o menor
+func groupForecasts(revenue, orders map[string][]float64, months, ahead []string) []models.SeriesForecast {
+	keys := make(map[string]bool)
+	for key := range revenue {
+		keys[key] = true
+	}
+	for key := range orders {
+		keys[key] = true
+	}
+
+	totals := make(map[string]float64, len(keys))
+	forecasts := make([]models.SeriesForecast, 0, len(keys))
+	for key := range keys {
+		r, o := revenue[key], orders[key]
+		if r == nil {
+			r = make([]float64, len(months))
+		}
+		if o == nil {
+			o = make([]float64, len(months))
+		}
+		for _, value := range r {
+			totals[key] += value
+		}
+		forecasts = append(forecasts, seriesForecast(key, r, o, months, ahead))
+	}
+
+	sort.Slice(forecasts, func(i, j int) bool {
+		a, b := forecasts[i].Key, forecasts[j].Key
+		if totals[a] != totals[b] {
+			return totals[a] > totals[b]
+		}
+		return a < b
+	})
+	return forecasts
+}
+
+// seriesForecast descarta os meses anteriores ao primeiro movimento do grupo (para que produtos
+// e segmentos novos não sejam previstos com zeros) e prevê receita e pedidos
+func seriesForecast(key string, revenue, orders []float64, months, ahead []string) models.SeriesForecast {
+	start := len(months)
+	for i := range months {
+		if revenue[i] != 0 || orders[i] != 0 {
+			start = i
+			break
+		}
+	}
+	revenue, orders, months = revenue[start:], orders[start:], months[start:]
+
+	revenueForecast, revenueMethod := forecastSeries(revenue, len(ahead))
+	ordersForecast, ordersMethod := forecastSeries(orders, len(ahead))
+
+	result := models.SeriesForecast{
+		Key:      key,
+		Method:   models.ForecastMethods{Revenue: revenueMethod, Orders: ordersMethod},
+		History:  make([]models.MonthlyPoint, len(months)),
+		Forecast: make([]models.MonthlyPoint, len(ahead)),
+	}
+	for i, month := range months {
+		result.History[i] = models.MonthlyPoint{Month: month, Revenue: round2(revenue[i]), Orders: orders[i]}
+	}
+	for i, month := range ahead {
+		result.Forecast[i] = models.MonthlyPoint{Month: month, Revenue: revenueForecast[i], Orders: ordersForecast[i]}
+	}
+	return result
+}
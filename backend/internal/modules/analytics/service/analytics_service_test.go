@@ -0,0 +1,104 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/analytics/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecastSeriesShortHistoryUsesMovingAverage(t *testing.T) {
+	forecast, method := forecastSeries([]float64{100, 10, 20, 30}, 2)
+
+	assert.Equal(t, models.MethodMovingAverage, method)
+	assert.Equal(t, []float64{20, 20}, forecast)
+
+	forecast, _ = forecastSeries(nil, 3)
+	assert.Equal(t, []float64{0, 0, 0}, forecast)
+}
+
+func TestForecastSeriesFollowsSeasonAndTrend(t *testing.T) {
+	// Três anos com pico em dezembro e crescimento de 10 por mês
+	series := make([]float64, 36)
+	for i := range series {
+		series[i] = 1000 + 10*float64(i)
+		if i%12 == 11 {
+			series[i] += 500
+		}
+	}
+
+	forecast, method := forecastSeries(series, 12)
+	require.Len(t, forecast, 12)
+	assert.Equal(t, models.MethodHoltWinters, method)
+	assert.InDelta(t, 1360, forecast[0], 1)
+	assert.InDelta(t, 1470+500, forecast[11], 1)
+}
+
+func TestForecastSeriesNeverNegative(t *testing.T) {
+	series := make([]float64, 24)
+	for i := range series {
+		series[i] = 240 - 10*float64(i)
+	}
+
+	forecast, _ := forecastSeries(series, 24)
+	for _, value := range forecast {
+		assert.GreaterOrEqual(t, value, 0.0)
+	}
+}
+
+func TestBuildForecastTotalsAndGroups(t *testing.T) {
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	month := func(i int) time.Time { return from.AddDate(0, i, 0) }
+	history := &models.ForecastHistory{
+		RevenueByCategory: []models.MonthlyValue{
+			{Month: month(34), Key: "eletronicos", Value: 300},
+			{Month: month(35), Key: "eletronicos", Value: 300},
+			{Month: month(35), Key: models.NoCategory, Value: 50},
+		},
+		RevenueBySegment: []models.MonthlyValue{
+			{Month: month(34), Key: "pj", Value: 300},
+			{Month: month(35), Key: "pj", Value: 200},
+			{Month: month(35), Key: "pf", Value: 150},
+		},
+		OrdersBySegment: []models.MonthlyValue{
+			{Month: month(34), Key: "pj", Value: 2},
+			{Month: month(35), Key: "pj", Value: 1},
+			{Month: month(35), Key: "pf", Value: 3},
+		},
+	}
+
+	forecast := buildForecast(history, from, 3, from.AddDate(0, 36, 10))
+
+	assert.Equal(t, "2023-01", forecast.HistoryFrom)
+	assert.Equal(t, "2025-12", forecast.HistoryTo)
+	require.Len(t, forecast.Total.History, 2, "meses anteriores ao primeiro movimento são descartados")
+	assert.Equal(t, models.MonthlyPoint{Month: "2025-12", Revenue: 350, Orders: 4}, forecast.Total.History[1])
+	require.Len(t, forecast.Total.Forecast, 3)
+	assert.Equal(t, models.MonthlyPoint{Month: "2026-01", Revenue: 325, Orders: 3}, forecast.Total.Forecast[0])
+	assert.Equal(t, "2026-03", forecast.Total.Forecast[2].Month)
+
+	require.Len(t, forecast.ByCategory, 2)
+	assert.Equal(t, "eletronicos", forecast.ByCategory[0].Key)
+	assert.Equal(t, models.NoCategory, forecast.ByCategory[1].Key)
+	assert.Len(t, forecast.ByCategory[1].History, 1)
+
+	require.Len(t, forecast.BySegment, 2)
+	assert.Equal(t, "pj", forecast.BySegment[0].Key)
+	assert.Equal(t, 250.0, forecast.BySegment[0].Forecast[0].Revenue)
+}
+
+func TestParseHorizon(t *testing.T) {
+	for value, expected := range map[string]int{"": models.DefaultHorizonMonths, "6m": 6, "1y": 12, "3": 3, " 12M ": 12} {
+		months, err := models.ParseHorizon(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, months, value)
+	}
+
+	for _, value := range []string{"0m", "-1m", "abc", "3y", "6d"} {
+		_, err := models.ParseHorizon(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"math"
+
+	"ERP-ONSMART/backend/internal/modules/analytics/models"
+)
+
+// movingAverageWindow é a quantidade de meses da média móvel usada nas séries curtas
+const movingAverageWindow = 3
+
+// smoothingGrid são os valores testados para alfa, beta e gama do Holt-Winters; fica a
+// combinação com o menor erro quadrático das previsões de um passo sobre o histórico
+var smoothingGrid = []float64{0.1, 0.3, 0.5, 0.7, 0.9}
+
+// forecastSeries prevê os próximos meses da série: Holt-Winters aditivo com sazonalidade
+// anual quando há ao menos duas temporadas de histórico, senão a média móvel dos últimos meses.
+// Previsões negativas (tendência de queda) são limitadas a zero.
+func forecastSeries(series []float64, horizon int) ([]float64, string) {
+	var forecast []float64
+	method := models.MethodMovingAverage
+	if len(series) >= 2*models.SeasonLength {
+		forecast = holtWinters(series, models.SeasonLength, horizon)
+		method = models.MethodHoltWinters
+	} else {
+		forecast = movingAverage(series, movingAverageWindow, horizon)
+	}
+
+	for i, value := range forecast {
+		forecast[i] = round2(math.Max(value, 0))
+	}
+	return forecast, method
+}
+
+// movingAverage repete a média dos últimos window meses em todo o horizonte
+func movingAverage(series []float64, window, horizon int) []float64 {
+	forecast := make([]float64, horizon)
+	if len(series) == 0 {
+		return forecast
+	}
+	if window > len(series) {
+		window = len(series)
+	}
+
+	sum := 0.0
+	for _, value := range series[len(series)-window:] {
+		sum += value
+	}
+	for i := range forecast {
+		forecast[i] = sum / float64(window)
+	}
+	return forecast
+}
+
+// holtWinters aplica o Holt-Winters aditivo com os parâmetros de menor erro em smoothingGrid
+func holtWinters(series []float64, season, horizon int) []float64 {
+	best := hwState{sse: math.Inf(1)}
+	for _, alpha := range smoothingGrid {
+		for _, beta := range smoothingGrid {
+			for _, gamma := range smoothingGrid {
+				if state := fitHoltWinters(series, season, alpha, beta, gamma); state.sse < best.sse {
+					best = state
+				}
+			}
+		}
+	}
+
+	forecast := make([]float64, horizon)
+	n := len(series)
+	for h := 1; h <= horizon; h++ {
+		forecast[h-1] = best.level + float64(h)*best.trend + best.seasonal[n-season+(h-1)%season]
+	}
+	return forecast
+}
+
+// hwState guarda o estado final do ajuste e o erro quadrático das previsões de um passo
+type hwState struct {
+	level    float64
+	trend    float64
+	seasonal []float64
+	sse      float64
+}
+
+// fitHoltWinters inicializa a tendência com a diferença entre as médias das duas primeiras
+// temporadas, o nível no fim da primeira e os índices sazonais com os desvios médios sobre a
+// reta de tendência; depois suaviza a partir da segunda temporada
+func fitHoltWinters(series []float64, season int, alpha, beta, gamma float64) hwState {
+	first, second := mean(series[:season]), mean(series[season:2*season])
+	trend := (second - first) / float64(season)
+	center := float64(season-1) / 2
+
+	state := hwState{
+		level:    first + trend*center,
+		trend:    trend,
+		seasonal: make([]float64, len(series)),
+	}
+	for i := 0; i < season; i++ {
+		deviation := series[i] - (first + trend*(float64(i)-center))
+		deviation += series[season+i] - (second + trend*(float64(i)-center))
+		state.seasonal[i] = deviation / 2
+	}
+
+	for t := season; t < len(series); t++ {
+		predicted := state.level + state.trend + state.seasonal[t-season]
+		state.sse += (series[t] - predicted) * (series[t] - predicted)
+
+		level := alpha*(series[t]-state.seasonal[t-season]) + (1-alpha)*(state.level+state.trend)
+		state.trend = beta*(level-state.level) + (1-beta)*state.trend
+		state.level = level
+		state.seasonal[t] = gamma*(series[t]-level) + (1-gamma)*state.seasonal[t-season]
+	}
+	return state
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/modules/analytics/models"
+	"ERP-ONSMART/backend/internal/modules/analytics/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"go.uber.org/zap"
+)
+
+// ScoreRFM recalcula as pontuações RFM dos clientes da empresa do contexto
+func ScoreRFM(ctx context.Context) (*models.RFMRun, error) {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return scoreRFM(ctx, nil)
+	}
+	return scoreRFM(ctx, []int{companyID})
+}
+
+// StartRFMScheduler recalcula periodicamente as pontuações RFM de todas as empresas
+func StartRFMScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("rfm_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run, err := scoreRFM(tenant.AllCompanies(ctx), nil)
+				metrics.ObserveJob("rfm_scheduler", err)
+				if err != nil {
+					log.Error("erro ao recalcular pontuações RFM", zap.Error(err))
+					continue
+				}
+				log.Info("recálculo das pontuações RFM concluído",
+					zap.Int("companies", run.Companies),
+					zap.Int("contacts", run.Contacts))
+			}
+		}
+	}()
+}
+
+// ListRFMScores lista as pontuações da empresa com os filtros de segmento, notas mínimas e busca
+func ListRFMScores(ctx context.Context, filter models.RFMFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewAnalyticsRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListRFMScores(ctx, filter, params)
+}
+
+// GetRFMSummary conta os clientes de cada segmento da empresa
+func GetRFMSummary(ctx context.Context) ([]models.RFMSummary, error) {
+	repo, err := repository.NewAnalyticsRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRFMSummary(ctx)
+}
+
+// scoreRFM pontua os clientes de cada empresa com faturas no contexto; as empresas em companies
+// são gravadas mesmo sem faturas, para limpar as pontuações anteriores
+func scoreRFM(ctx context.Context, companies []int) (*models.RFMRun, error) {
+	repo, err := repository.NewAnalyticsRepository()
+	if err != nil {
+		return nil, err
+	}
+	histories, err := repo.GetCustomerHistories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byCompany := make(map[int][]models.CustomerHistory)
+	for _, companyID := range companies {
+		byCompany[companyID] = nil
+	}
+	for _, history := range histories {
+		byCompany[history.CompanyID] = append(byCompany[history.CompanyID], history)
+	}
+
+	now := time.Now()
+	run := &models.RFMRun{Segments: make(map[string]int), ScoredAt: now}
+	for companyID, customers := range byCompany {
+		scores := scoreCustomers(customers, now)
+		if err := repo.SaveRFMScores(ctx, companyID, scores); err != nil {
+			return nil, err
+		}
+		run.Companies++
+		run.Contacts += len(scores)
+		for _, score := range scores {
+			run.Segments[score.Segment]++
+		}
+	}
+	return run, nil
+}
+
+// scoreCustomers dá a cada cliente as notas de 1 a 5 pelo quintil da recência (dias desde a
+// última fatura, menos é melhor), da frequência (faturas) e do valor faturado entre os clientes
+// da empresa, e classifica o segmento
+func scoreCustomers(customers []models.CustomerHistory, now time.Time) []models.ContactRFM {
+	recency := make([]float64, len(customers))
+	frequency := make([]float64, len(customers))
+	monetary := make([]float64, len(customers))
+	days := make([]int, len(customers))
+	for i, customer := range customers {
+		days[i] = int(math.Max(0, now.Sub(customer.LastInvoiceAt).Hours()/24))
+		recency[i] = -float64(days[i])
+		frequency[i] = float64(customer.Frequency)
+		monetary[i] = customer.Monetary
+	}
+
+	recencyScores := quintileScores(recency)
+	frequencyScores := quintileScores(frequency)
+	monetaryScores := quintileScores(monetary)
+
+	scores := make([]models.ContactRFM, len(customers))
+	for i, customer := range customers {
+		scores[i] = models.ContactRFM{
+			ContactID:      customer.ContactID,
+			CompanyID:      customer.CompanyID,
+			LastInvoiceAt:  customer.LastInvoiceAt,
+			RecencyDays:    days[i],
+			Frequency:      customer.Frequency,
+			Monetary:       round2(customer.Monetary),
+			RecencyScore:   recencyScores[i],
+			FrequencyScore: frequencyScores[i],
+			MonetaryScore:  monetaryScores[i],
+			Segment:        models.SegmentFor(recencyScores[i], frequencyScores[i], monetaryScores[i]),
+			ScoredAt:       now,
+		}
+	}
+	return scores
+}
+
+// quintileScores converte cada valor (maior é melhor) na nota de 1 a 5 do seu percentil; valores
+// empatados recebem o percentil médio do empate, então uma empresa com um só cliente, ou clientes
+// iguais, fica com nota 3
+func quintileScores(values []float64) []int {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := float64(len(values))
+	scores := make([]int, len(values))
+	for i, value := range values {
+		below := sort.SearchFloat64s(sorted, value)
+		equal := sort.SearchFloat64s(sorted, math.Nextafter(value, math.Inf(1))) - below
+		percentile := (float64(below) + float64(equal)/2) / n
+
+		score := int(math.Ceil(percentile * models.RFMScoreCount))
+		scores[i] = min(max(score, 1), models.RFMScoreCount)
+	}
+	return scores
+}
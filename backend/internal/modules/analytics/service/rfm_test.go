@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/analytics/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuintileScores(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, quintileScores([]float64{10, 20, 30, 40, 50}))
+	assert.Equal(t, []int{5, 1, 3}, quintileScores([]float64{900, 1, 50}))
+	assert.Equal(t, []int{3}, quintileScores([]float64{100}), "cliente único fica no meio")
+	assert.Equal(t, []int{3, 3, 3}, quintileScores([]float64{7, 7, 7}), "empates recebem a mesma nota")
+	assert.Empty(t, quintileScores(nil))
+}
+
+func TestScoreCustomersSegments(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	customers := []models.CustomerHistory{
+		{CompanyID: 1, ContactID: 1, LastInvoiceAt: now.AddDate(0, 0, -2), Frequency: 40, Monetary: 90000},
+		{CompanyID: 1, ContactID: 2, LastInvoiceAt: now.AddDate(0, 0, -400), Frequency: 30, Monetary: 50000},
+		{CompanyID: 1, ContactID: 3, LastInvoiceAt: now.AddDate(0, 0, -700), Frequency: 1, Monetary: 100},
+		{CompanyID: 1, ContactID: 4, LastInvoiceAt: now.AddDate(0, 0, -5), Frequency: 1, Monetary: 300},
+		{CompanyID: 1, ContactID: 5, LastInvoiceAt: now.AddDate(0, 0, -60), Frequency: 1, Monetary: 2000.555},
+	}
+
+	scores := scoreCustomers(customers, now)
+	require.Len(t, scores, 5)
+
+	segments := make(map[int]string)
+	for _, score := range scores {
+		segments[score.ContactID] = score.Segment
+	}
+	assert.Equal(t, models.SegmentChampion, segments[1])
+	assert.Equal(t, models.SegmentAtRisk, segments[2])
+	assert.Equal(t, models.SegmentDormant, segments[3])
+	assert.Equal(t, models.SegmentPromising, segments[4])
+	assert.Equal(t, models.SegmentNeedAttention, segments[5])
+
+	assert.Equal(t, 2, scores[0].RecencyDays)
+	assert.Equal(t, 5, scores[0].RecencyScore)
+	assert.Equal(t, 2000.56, scores[4].Monetary)
+	assert.Equal(t, now, scores[0].ScoredAt)
+}
+
+func TestSegmentFor(t *testing.T) {
+	assert.Equal(t, models.SegmentChampion, models.SegmentFor(5, 4, 4))
+	assert.Equal(t, models.SegmentLoyal, models.SegmentFor(5, 4, 2))
+	assert.Equal(t, models.SegmentLoyal, models.SegmentFor(3, 3, 1))
+	assert.Equal(t, models.SegmentPromising, models.SegmentFor(4, 1, 1))
+	assert.Equal(t, models.SegmentNeedAttention, models.SegmentFor(3, 2, 5))
+	assert.Equal(t, models.SegmentAtRisk, models.SegmentFor(2, 5, 5))
+	assert.Equal(t, models.SegmentDormant, models.SegmentFor(1, 1, 1))
+}
+
+func TestParseSegments(t *testing.T) {
+	segments, err := models.ParseSegments(" champion, AT_RISK,,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.SegmentChampion, models.SegmentAtRisk}, segments)
+
+	segments, err = models.ParseSegments("")
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+
+	_, err = models.ParseSegments("vip")
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/apikeys/models"
+	"ERP-ONSMART/backend/internal/modules/apikeys/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as chaves de API da empresa, com último uso e total de requisições
+// @Security BearerAuth
+func ListAPIKeysHandler(c *gin.Context) {
+	keys, err := service.ListKeys(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar chaves de API")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys, "scopes": models.Scopes})
+}
+
+// Retorna uma chave de API
+// @Security BearerAuth
+func GetAPIKeyHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	key, err := service.GetKey(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar chave de API")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_key": key})
+}
+
+// Cria uma chave de API para uma integração; a chave só é exibida nesta resposta
+// @Security BearerAuth
+func CreateAPIKeyHandler(c *gin.Context) {
+	var input models.CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	created, err := service.CreateKey(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar chave de API")
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// Revoga a chave de API; as requisições seguintes com ela são recusadas
+// @Security BearerAuth
+func RevokeAPIKeyHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	key, err := service.RevokeKey(c.Request.Context(), id, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao revogar chave de API")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_key": key})
+}
+
+// Retorna as requisições por dia feitas com a chave de API
+// @Security BearerAuth
+// @Param days query int false "últimos N dias (padrão 30, máx. 365)"
+func GetAPIKeyUsageHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var days int
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.Error(errors.InvalidParam("days deve ser um número inteiro positivo"))
+			return
+		}
+		days = parsed
+	}
+
+	usage, err := service.GetUsage(c.Request.Context(), id, days)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar uso da chave de API")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"usage": usage})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
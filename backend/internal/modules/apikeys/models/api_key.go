@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Escopos concedidos às chaves de API; cada rota de /integrations exige um deles
+const (
+	ScopeProductsRead  = "products:read"
+	ScopeContactsRead  = "contacts:read"
+	ScopeContactsWrite = "contacts:write"
+	ScopeSalesRead     = "sales:read"
+	ScopeSalesWrite    = "sales:write"
+	ScopeReportsRead   = "reports:read"
+)
+
+// Scopes lista os escopos que podem ser concedidos
+var Scopes = []string{ScopeProductsRead, ScopeContactsRead, ScopeContactsWrite, ScopeSalesRead, ScopeSalesWrite, ScopeReportsRead}
+
+// ValidScope indica se o escopo é conhecido
+func ValidScope(scope string) bool {
+	for _, valid := range Scopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey é a credencial de uma conta de serviço (e-commerce, BI) para acessar /integrations.
+// Apenas o hash da chave é gravado; o prefixo identifica a chave sem expô-la.
+type APIKey struct {
+	ID          int            `json:"id" gorm:"primaryKey"`
+	CompanyID   int            `json:"company_id" gorm:"<-:create"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Prefix      string         `json:"prefix"`
+	KeyHash     string         `json:"-"`
+	Scopes      pq.StringArray `json:"scopes" gorm:"type:text[]"`
+	ExpiresAt   *time.Time     `json:"expires_at"`
+	RevokedAt   *time.Time     `json:"revoked_at"`
+	RevokedBy   string         `json:"revoked_by,omitempty"`
+	CreatedBy   string         `json:"created_by"`
+	LastUsedAt  *time.Time     `json:"last_used_at"`
+	LastUsedIP  string         `json:"last_used_ip,omitempty" gorm:"column:last_used_ip"`
+	UsageCount  int64          `json:"usage_count"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// HasScope indica se a chave concede o escopo
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired indica se a chave já expirou no instante informado
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(now)
+}
+
+// APIKeyUsage é a quantidade de requisições da chave em um dia
+type APIKeyUsage struct {
+	APIKeyID int       `json:"-" gorm:"primaryKey;column:api_key_id"`
+	Day      time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Requests int64     `json:"requests"`
+}
+
+func (APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
+
+// CreateAPIKeyInput são os dados da nova chave
+type CreateAPIKeyInput struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	Description string     `json:"description"`
+	Scopes      []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey devolve a chave em texto claro, exibida somente na criação
+type CreatedAPIKey struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/apikeys/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository define as operações das chaves de API e do registro de uso
+type APIKeyRepository interface {
+	List(ctx context.Context) ([]models.APIKey, error)
+	GetByID(ctx context.Context, id int) (*models.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	Create(ctx context.Context, key *models.APIKey) error
+	Revoke(ctx context.Context, id int, revokedBy string, now time.Time) error
+	RecordUsage(ctx context.Context, id int, ip string, now time.Time) error
+	Usage(ctx context.Context, id int, since time.Time) ([]models.APIKeyUsage, error)
+}
+
+type apiKeyRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewAPIKeyRepository cria uma nova instância do repositório
+func NewAPIKeyRepository() (APIKeyRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &apiKeyRepository{
+		db:     db,
+		logger: logger.WithModule("api_key_repository"),
+	}, nil
+}
+
+// List retorna as chaves da empresa, mais recentes primeiro
+func (r *apiKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&keys).Error; err != nil {
+		r.logger.Error("erro ao listar chaves de API", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar chaves de API")
+	}
+	return keys, nil
+}
+
+// GetByID busca uma chave pelo ID
+func (r *apiKeyRepository) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).First(&key, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAPIKeyNotFound
+		}
+		r.logger.Error("erro ao buscar chave de API", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar chave de API")
+	}
+	return &key, nil
+}
+
+// FindByHash busca a chave pelo hash, usado na autenticação das integrações
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidAPIKey
+		}
+		r.logger.Error("erro ao buscar chave de API pelo hash", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao validar chave de API")
+	}
+	return &key, nil
+}
+
+// Create grava a nova chave
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		r.logger.Error("erro ao criar chave de API", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar chave de API")
+	}
+	return nil
+}
+
+// Revoke revoga a chave; revogar uma chave já revogada mantém a data original
+func (r *apiKeyRepository) Revoke(ctx context.Context, id int, revokedBy string, now time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_by": revokedBy, "updated_at": now})
+	if result.Error != nil {
+		r.logger.Error("erro ao revogar chave de API", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao revogar chave de API")
+	}
+	if result.RowsAffected == 0 {
+		_, err := r.GetByID(ctx, id)
+		return err
+	}
+	return nil
+}
+
+// RecordUsage registra o último uso da chave e soma a requisição no total do dia
+func (r *apiKeyRepository) RecordUsage(ctx context.Context, id int, ip string, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.APIKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+			"usage_count":  gorm.Expr("usage_count + 1"),
+		}).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar uso da chave de API")
+		}
+
+		if err := tx.Exec(`
+			INSERT INTO api_key_usage (api_key_id, day, requests) VALUES (?, ?, 1)
+			ON CONFLICT (api_key_id, day) DO UPDATE SET requests = api_key_usage.requests + 1`,
+			id, now.Format("2006-01-02")).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar uso da chave de API")
+		}
+		return nil
+	})
+}
+
+// Usage retorna as requisições por dia da chave a partir da data informada
+func (r *apiKeyRepository) Usage(ctx context.Context, id int, since time.Time) ([]models.APIKeyUsage, error) {
+	var usage []models.APIKeyUsage
+	if err := r.db.WithContext(ctx).
+		Where("api_key_id = ? AND day >= ?", id, since.Format("2006-01-02")).
+		Order("day ASC").
+		Find(&usage).Error; err != nil {
+		r.logger.Error("erro ao buscar uso da chave de API", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar uso da chave de API")
+	}
+	return usage, nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/apikeys/models"
+	"ERP-ONSMART/backend/internal/modules/apikeys/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// KeyPrefix identifica as chaves de API do ERP (ex.: em varreduras de segredos vazados)
+const KeyPrefix = "erp_"
+
+// prefixLength é o trecho inicial da chave exibido nas listagens
+const prefixLength = len(KeyPrefix) + 8
+
+// maxUsageDays limita o período consultado em GetUsage
+const maxUsageDays = 365
+
+// Service emite e valida as chaves de API. O repositório é aberto uma única vez e reutilizado,
+// pois Authenticate roda em todas as requisições das integrações.
+type Service struct {
+	newRepo func() (repository.APIKeyRepository, error)
+	now     func() time.Time
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.APIKeyRepository
+}
+
+// NewService cria o serviço sobre o repositório informado
+func NewService(newRepo func() (repository.APIKeyRepository, error)) *Service {
+	return &Service{
+		newRepo: newRepo,
+		now:     time.Now,
+		logger:  logger.WithModule("api_key_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewAPIKeyRepository)
+
+// ListKeys lista as chaves da empresa da requisição
+func ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	return defaultService.List(ctx)
+}
+
+// GetKey busca uma chave da empresa da requisição
+func GetKey(ctx context.Context, id int) (*models.APIKey, error) {
+	return defaultService.Get(ctx, id)
+}
+
+// CreateKey emite uma nova chave na empresa da requisição
+func CreateKey(ctx context.Context, input models.CreateAPIKeyInput, createdBy string) (*models.CreatedAPIKey, error) {
+	return defaultService.Create(ctx, input, createdBy)
+}
+
+// RevokeKey revoga a chave; as requisições seguintes com ela são recusadas
+func RevokeKey(ctx context.Context, id int, revokedBy string) (*models.APIKey, error) {
+	return defaultService.Revoke(ctx, id, revokedBy)
+}
+
+// RotateKey emite uma chave nova com os dados da chave informada e revoga a antiga
+func RotateKey(ctx context.Context, id int, rotatedBy string) (*models.CreatedAPIKey, error) {
+	return defaultService.Rotate(ctx, id, rotatedBy)
+}
+
+// GetUsage retorna as requisições por dia da chave nos últimos dias
+func GetUsage(ctx context.Context, id int, days int) ([]models.APIKeyUsage, error) {
+	return defaultService.Usage(ctx, id, days)
+}
+
+// Authenticate valida a chave enviada pela integração e o escopo exigido pela rota
+func Authenticate(ctx context.Context, rawKey, scope, ip string) (*models.APIKey, error) {
+	return defaultService.Authenticate(ctx, rawKey, scope, ip)
+}
+
+func (s *Service) repository() (repository.APIKeyRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// List lista as chaves da empresa
+func (s *Service) List(ctx context.Context) ([]models.APIKey, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.List(ctx)
+}
+
+// Get busca uma chave da empresa
+func (s *Service) Get(ctx context.Context, id int) (*models.APIKey, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+// Create valida os escopos, gera a chave e grava apenas o hash; a chave em texto claro só é
+// devolvida nesta resposta
+func (s *Service) Create(ctx context.Context, input models.CreateAPIKeyInput, createdBy string) (*models.CreatedAPIKey, error) {
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return nil, errors.InvalidParam("expires_at deve ser uma data futura")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	rawKey, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		companyID = tenant.DefaultCompanyID
+	}
+	key := &models.APIKey{
+		CompanyID:   companyID,
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+		Prefix:      rawKey[:prefixLength],
+		KeyHash:     hashKey(rawKey),
+		Scopes:      pq.StringArray(scopes),
+		ExpiresAt:   input.ExpiresAt,
+		CreatedBy:   createdBy,
+	}
+	if err := repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("chave de API criada", zap.Int("id", key.ID), zap.String("prefix", key.Prefix),
+		zap.Strings("scopes", scopes), zap.String("created_by", createdBy))
+	return &models.CreatedAPIKey{APIKey: key, Key: rawKey}, nil
+}
+
+// Revoke revoga a chave da empresa
+func (s *Service) Revoke(ctx context.Context, id int, revokedBy string) (*models.APIKey, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Revoke(ctx, id, revokedBy, s.now()); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("chave de API revogada", zap.Int("id", id), zap.String("revoked_by", revokedBy))
+	return repo.GetByID(ctx, id)
+}
+
+// Rotate substitui a chave da empresa: emite uma nova com o mesmo nome, descrição, escopos e
+// validade e revoga a antiga. A chave revogada ou expirada não é rotacionada.
+func (s *Service) Rotate(ctx context.Context, id int, rotatedBy string) (*models.CreatedAPIKey, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	old, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if old.RevokedAt != nil || old.IsExpired(s.now()) {
+		return nil, errors.InvalidParam("a chave revogada ou expirada não pode ser rotacionada")
+	}
+
+	created, err := s.Create(tenant.WithCompany(ctx, old.CompanyID), models.CreateAPIKeyInput{
+		Name:        old.Name,
+		Description: old.Description,
+		Scopes:      []string(old.Scopes),
+		ExpiresAt:   old.ExpiresAt,
+	}, rotatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Revoke(ctx, id, rotatedBy, s.now()); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("chave de API rotacionada", zap.Int("id", id), zap.Int("new_id", created.APIKey.ID),
+		zap.String("rotated_by", rotatedBy))
+	return created, nil
+}
+
+// Usage retorna as requisições por dia da chave nos últimos dias (padrão 30, máximo 365)
+func (s *Service) Usage(ctx context.Context, id int, days int) ([]models.APIKeyUsage, error) {
+	if days <= 0 {
+		days = 30
+	}
+	if days > maxUsageDays {
+		days = maxUsageDays
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	// Garante que a chave pertence à empresa da requisição
+	if _, err := repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	since := s.now().AddDate(0, 0, -(days - 1))
+	return repo.Usage(ctx, id, since)
+}
+
+// Authenticate busca a chave pelo hash em todas as empresas (a empresa da requisição passa a
+// ser a da chave), recusa chaves revogadas, expiradas ou sem o escopo e registra o uso
+func (s *Service) Authenticate(ctx context.Context, rawKey, scope, ip string) (*models.APIKey, error) {
+	rawKey = strings.TrimSpace(rawKey)
+	if !strings.HasPrefix(rawKey, KeyPrefix) {
+		return nil, errors.ErrInvalidAPIKey
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = tenant.AllCompanies(ctx)
+	key, err := repo.FindByHash(ctx, hashKey(rawKey))
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	switch {
+	case key.RevokedAt != nil:
+		return nil, errors.ErrAPIKeyRevoked
+	case key.IsExpired(now):
+		return nil, errors.ErrAPIKeyExpired
+	case scope != "" && !key.HasScope(scope):
+		return nil, errors.ErrInsufficientScope
+	}
+
+	// Falha no registro de uso não impede a integração de operar
+	if err := repo.RecordUsage(ctx, key.ID, ip, now); err != nil {
+		s.logger.Warn("erro ao registrar uso da chave de API", zap.Error(err), zap.Int("id", key.ID))
+	}
+	return key, nil
+}
+
+// normalizeScopes valida os escopos e remove repetições, mantendo a ordem informada
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !models.ValidScope(scope) {
+			return nil, fmt.Errorf("%w: %s (aceitos: %s)", errors.ErrInvalidScope, scope, strings.Join(models.Scopes, ", "))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, errors.ErrInvalidScope
+	}
+	return normalized, nil
+}
+
+// generateKey gera a chave no formato erp_<43 caracteres base64url>
+func generateKey() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("falha ao gerar chave de API: %w", err)
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
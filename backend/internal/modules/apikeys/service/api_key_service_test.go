@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/apikeys/models"
+	"ERP-ONSMART/backend/internal/modules/apikeys/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	keys       map[int]*models.APIKey
+	usage      []int
+	usageSince time.Time
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{keys: map[int]*models.APIKey{}}
+}
+
+func (f *fakeRepo) List(context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	for _, key := range f.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (f *fakeRepo) GetByID(_ context.Context, id int) (*models.APIKey, error) {
+	key, ok := f.keys[id]
+	if !ok {
+		return nil, errors.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (f *fakeRepo) FindByHash(_ context.Context, keyHash string) (*models.APIKey, error) {
+	for _, key := range f.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return nil, errors.ErrInvalidAPIKey
+}
+
+func (f *fakeRepo) Create(_ context.Context, key *models.APIKey) error {
+	key.ID = len(f.keys) + 1
+	f.keys[key.ID] = key
+	return nil
+}
+
+func (f *fakeRepo) Revoke(_ context.Context, id int, revokedBy string, now time.Time) error {
+	key, ok := f.keys[id]
+	if !ok {
+		return errors.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt, key.RevokedBy = &now, revokedBy
+	}
+	return nil
+}
+
+func (f *fakeRepo) RecordUsage(_ context.Context, id int, _ string, _ time.Time) error {
+	f.usage = append(f.usage, id)
+	return nil
+}
+
+func (f *fakeRepo) Usage(_ context.Context, _ int, since time.Time) ([]models.APIKeyUsage, error) {
+	f.usageSince = since
+	return nil, nil
+}
+
+func newTestService(repo *fakeRepo, now time.Time) *Service {
+	s := NewService(func() (repository.APIKeyRepository, error) { return repo, nil })
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestCreateStoresOnlyTheHash(t *testing.T) {
+	repo := newFakeRepo()
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestService(repo, now)
+	ctx := tenant.WithCompany(context.Background(), 3)
+
+	created, err := s.Create(ctx, models.CreateAPIKeyInput{
+		Name:   " Loja virtual ",
+		Scopes: []string{"Products:Read", "sales:read", "products:read"},
+	}, "admin")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(created.Key, KeyPrefix))
+	assert.Len(t, created.Key, len(KeyPrefix)+43)
+	key := repo.keys[created.APIKey.ID]
+	assert.Equal(t, "Loja virtual", key.Name)
+	assert.Equal(t, 3, key.CompanyID)
+	assert.Equal(t, created.Key[:prefixLength], key.Prefix)
+	assert.Equal(t, hashKey(created.Key), key.KeyHash)
+	assert.NotContains(t, key.KeyHash, created.Key)
+	assert.Equal(t, []string{"products:read", "sales:read"}, []string(key.Scopes))
+	assert.Equal(t, "admin", key.CreatedBy)
+}
+
+func TestCreateValidatesInput(t *testing.T) {
+	now := time.Now()
+	s := newTestService(newFakeRepo(), now)
+
+	_, err := s.Create(context.Background(), models.CreateAPIKeyInput{Name: "BI", Scopes: []string{"admin"}}, "admin")
+	assert.ErrorIs(t, err, errors.ErrInvalidScope)
+
+	past := now.Add(-time.Hour)
+	_, err = s.Create(context.Background(), models.CreateAPIKeyInput{Name: "BI", Scopes: []string{models.ScopeReportsRead}, ExpiresAt: &past}, "admin")
+	assert.Error(t, err)
+}
+
+func TestAuthenticate(t *testing.T) {
+	repo := newFakeRepo()
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestService(repo, now)
+
+	created, err := s.Create(context.Background(), models.CreateAPIKeyInput{Name: "Loja", Scopes: []string{models.ScopeProductsRead}}, "admin")
+	require.NoError(t, err)
+
+	key, err := s.Authenticate(context.Background(), created.Key, models.ScopeProductsRead, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, created.APIKey.ID, key.ID)
+	assert.Equal(t, []int{key.ID}, repo.usage)
+
+	_, err = s.Authenticate(context.Background(), created.Key, models.ScopeContactsWrite, "10.0.0.1")
+	assert.ErrorIs(t, err, errors.ErrInsufficientScope)
+
+	_, err = s.Authenticate(context.Background(), "erp_desconhecida", models.ScopeProductsRead, "10.0.0.1")
+	assert.ErrorIs(t, err, errors.ErrInvalidAPIKey)
+
+	_, err = s.Authenticate(context.Background(), "Bearer token", models.ScopeProductsRead, "10.0.0.1")
+	assert.ErrorIs(t, err, errors.ErrInvalidAPIKey)
+
+	expired := now
+	repo.keys[key.ID].ExpiresAt = &expired
+	_, err = s.Authenticate(context.Background(), created.Key, models.ScopeProductsRead, "10.0.0.1")
+	assert.ErrorIs(t, err, errors.ErrAPIKeyExpired)
+
+	_, err = s.Revoke(context.Background(), key.ID, "admin")
+	require.NoError(t, err)
+	_, err = s.Authenticate(context.Background(), created.Key, models.ScopeProductsRead, "10.0.0.1")
+	assert.ErrorIs(t, err, errors.ErrAPIKeyRevoked)
+	assert.Len(t, repo.usage, 1, "requisições recusadas não contam como uso")
+}
+
+func TestUsageLimitsPeriod(t *testing.T) {
+	repo := newFakeRepo()
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestService(repo, now)
+	created, err := s.Create(context.Background(), models.CreateAPIKeyInput{Name: "BI", Scopes: []string{models.ScopeReportsRead}}, "admin")
+	require.NoError(t, err)
+
+	_, err = s.Usage(context.Background(), created.APIKey.ID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -29), repo.usageSince)
+
+	_, err = s.Usage(context.Background(), created.APIKey.ID, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -364), repo.usageSince)
+
+	_, err = s.Usage(context.Background(), 99, 7)
+	assert.ErrorIs(t, err, errors.ErrAPIKeyNotFound)
+}
+
+func TestRotateReplacesTheKey(t *testing.T) {
+	repo := newFakeRepo()
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	s := newTestService(repo, now)
+	expires := now.AddDate(1, 0, 0)
+
+	old, err := s.Create(tenant.WithCompany(context.Background(), 3), models.CreateAPIKeyInput{
+		Name: "Loja", Description: "e-commerce", Scopes: []string{models.ScopeProductsRead}, ExpiresAt: &expires,
+	}, "admin")
+	require.NoError(t, err)
+
+	rotated, err := s.Rotate(tenant.AllCompanies(context.Background()), old.APIKey.ID, "erpctl")
+	require.NoError(t, err)
+	assert.NotEqual(t, old.Key, rotated.Key)
+	assert.Equal(t, 3, rotated.APIKey.CompanyID)
+	assert.Equal(t, "Loja", rotated.APIKey.Name)
+	assert.Equal(t, []string{models.ScopeProductsRead}, []string(rotated.APIKey.Scopes))
+	assert.Equal(t, &expires, rotated.APIKey.ExpiresAt)
+	assert.NotNil(t, repo.keys[old.APIKey.ID].RevokedAt)
+	assert.Equal(t, "erpctl", repo.keys[old.APIKey.ID].RevokedBy)
+
+	_, err = s.Authenticate(context.Background(), old.Key, models.ScopeProductsRead, "10.0.0.1")
+	assert.Error(t, err)
+
+	_, err = s.Rotate(context.Background(), old.APIKey.ID, "erpctl")
+	assert.Error(t, err, "a chave revogada não é rotacionada")
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/assets/models"
+	"ERP-ONSMART/backend/internal/modules/assets/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os bens do ativo imobilizado pelo número de patrimônio
+// @Security BearerAuth
+// @Param status query string false "active, disposed ou written_off"
+// @Param category query string false "Categoria do bem"
+// @Param location query string false "Local do bem"
+// @Param custodian query string false "Responsável pelo bem"
+func ListAssetsHandler(c *gin.Context) {
+	filter := models.AssetFilter{
+		Status:    c.Query("status"),
+		Category:  c.Query("category"),
+		Location:  c.Query("location"),
+		Custodian: c.Query("custodian"),
+	}
+
+	assets, err := service.ListAssets(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar bens patrimoniais")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assets": assets})
+}
+
+// Busca um bem com o histórico de movimentações
+// @Security BearerAuth
+// @Param id path int true "ID do bem"
+func GetAssetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	asset, err := service.GetAsset(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar bem patrimonial")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"asset": asset})
+}
+
+// Cadastra um bem, opcionalmente a partir do item de um pedido de compra recebido
+// @Security BearerAuth
+func CreateAssetHandler(c *gin.Context) {
+	var input models.AssetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	asset, err := service.CreateAsset(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cadastrar bem patrimonial")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"asset": asset})
+}
+
+// Altera o cadastro de um bem ativo; local e responsável mudam pela transferência
+// @Security BearerAuth
+// @Param id path int true "ID do bem"
+func UpdateAssetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.AssetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	asset, err := service.UpdateAsset(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar bem patrimonial")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"asset": asset})
+}
+
+// Transfere o bem para outro local ou responsável, registrando a movimentação
+// @Security BearerAuth
+// @Param id path int true "ID do bem"
+func TransferAssetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.TransferInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	asset, err := service.TransferAsset(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao transferir bem patrimonial")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"asset": asset})
+}
+
+// Mostra o plano de depreciação linear do bem, mês a mês, e as depreciações já registradas
+// @Security BearerAuth
+// @Param id path int true "ID do bem"
+func GetDepreciationScheduleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	schedule, err := service.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao montar plano de depreciação")
+		return
+	}
+	depreciations, err := service.ListDepreciations(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar depreciações do bem")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedule": schedule, "depreciations": depreciations})
+}
+
+// Baixa o bem por venda ou perda, depreciando até o mês da baixa e apurando o ganho ou a perda
+// @Security BearerAuth
+// @Param id path int true "ID do bem"
+func DisposeAssetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.DisposalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	asset, err := service.DisposeAsset(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao baixar bem patrimonial")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"asset": asset})
+}
+
+// Deprecia os bens ativos até o mês informado, incluindo os meses em atraso
+// @Security BearerAuth
+// @Param period query string true "Mês da depreciação (AAAA-MM)"
+func RunDepreciationHandler(c *gin.Context) {
+	period := c.Query("period")
+	if period == "" {
+		c.Error(errors.InvalidParam("informe o mês da depreciação (AAAA-MM)"))
+		return
+	}
+
+	run, err := service.RunDepreciation(c.Request.Context(), period)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao depreciar bens patrimoniais")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
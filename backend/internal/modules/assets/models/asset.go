@@ -0,0 +1,350 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Um bem do ativo imobilizado é depreciado pelo método linear, em parcelas mensais iguais do
+// custo menos o valor residual ao longo da vida útil, a partir do mês seguinte ao da aquisição.
+// Cada mês depreciado vira um registro de depreciação, contabilizado pelo razão; a baixa por
+// venda ou perda deprecia até o mês da baixa e apura o ganho ou a perda sobre o valor contábil.
+
+// Status do bem
+const (
+	StatusActive     = "active"
+	StatusDisposed   = "disposed"
+	StatusWrittenOff = "written_off"
+)
+
+// Tipos de baixa: venda (com valor recebido) ou baixa por perda, furto ou obsolescência
+const (
+	DisposalSale     = "sale"
+	DisposalWriteOff = "write_off"
+)
+
+// PeriodLayout é o formato dos meses de depreciação (AAAA-MM)
+const PeriodLayout = "2006-01"
+
+// MaxUsefulLifeMonths é a maior vida útil aceita (50 anos)
+const MaxUsefulLifeMonths = 600
+
+// Asset é um bem do ativo imobilizado
+type Asset struct {
+	ID                      int           `json:"id" gorm:"primaryKey"`
+	CompanyID               int           `json:"company_id" gorm:"<-:create"`
+	AssetNo                 string        `json:"asset_no"`
+	Name                    string        `json:"name"`
+	Description             string        `json:"description,omitempty"`
+	Category                string        `json:"category,omitempty"`
+	PurchaseOrderID         *int          `json:"purchase_order_id,omitempty"`
+	PurchaseOrderItemID     *int          `json:"purchase_order_item_id,omitempty"`
+	AcquisitionDate         time.Time     `json:"acquisition_date"`
+	AcquisitionCost         money.Decimal `json:"acquisition_cost"`
+	ResidualValue           money.Decimal `json:"residual_value"`
+	UsefulLifeMonths        int           `json:"useful_life_months"`
+	AccumulatedDepreciation money.Decimal `json:"accumulated_depreciation"`
+	DepreciatedThrough      string        `json:"depreciated_through,omitempty"`
+	Location                string        `json:"location,omitempty"`
+	Custodian               string        `json:"custodian,omitempty"`
+	CostCenterID            *int          `json:"cost_center_id,omitempty"`
+	Status                  string        `json:"status"`
+	DisposalType            string        `json:"disposal_type,omitempty"`
+	DisposedAt              *time.Time    `json:"disposed_at,omitempty"`
+	DisposedBy              string        `json:"disposed_by,omitempty"`
+	DisposalProceeds        money.Decimal `json:"disposal_proceeds"`
+	DisposalGainLoss        money.Decimal `json:"disposal_gain_loss"`
+	DisposalReason          string        `json:"disposal_reason,omitempty"`
+	CreatedBy               string        `json:"created_by,omitempty"`
+	CreatedAt               time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt               time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Movements []AssetMovement `json:"movements,omitempty" gorm:"foreignKey:AssetID"`
+}
+
+// AssetDepreciation é a depreciação de um mês do bem, com o acumulado e o valor contábil
+// depois dela
+type AssetDepreciation struct {
+	ID                      int           `json:"id" gorm:"primaryKey"`
+	CompanyID               int           `json:"company_id" gorm:"<-:create"`
+	AssetID                 int           `json:"asset_id"`
+	Period                  string        `json:"period"`
+	EntryDate               time.Time     `json:"entry_date"`
+	Amount                  money.Decimal `json:"amount"`
+	AccumulatedDepreciation money.Decimal `json:"accumulated_depreciation"`
+	BookValue               money.Decimal `json:"book_value"`
+	CreatedAt               time.Time     `json:"created_at" gorm:"autoCreateTime"`
+
+	Asset *Asset `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+}
+
+// AssetMovement registra a troca de local ou de responsável pelo bem
+type AssetMovement struct {
+	ID            int       `json:"id" gorm:"primaryKey"`
+	CompanyID     int       `json:"company_id" gorm:"<-:create"`
+	AssetID       int       `json:"asset_id"`
+	FromLocation  string    `json:"from_location,omitempty"`
+	ToLocation    string    `json:"to_location,omitempty"`
+	FromCustodian string    `json:"from_custodian,omitempty"`
+	ToCustodian   string    `json:"to_custodian,omitempty"`
+	Notes         string    `json:"notes,omitempty"`
+	MovedBy       string    `json:"moved_by,omitempty"`
+	MovedAt       time.Time `json:"moved_at"`
+}
+
+// AssetInput é o corpo aceito em POST e PUT /assets. Com pedido de compra, o item deve ser
+// informado e, sem custo, vale o custo unitário do item.
+type AssetInput struct {
+	AssetNo             string        `json:"asset_no" binding:"required,max=30"`
+	Name                string        `json:"name" binding:"required,max=150"`
+	Description         string        `json:"description"`
+	Category            string        `json:"category" binding:"max=60"`
+	PurchaseOrderID     *int          `json:"purchase_order_id"`
+	PurchaseOrderItemID *int          `json:"purchase_order_item_id"`
+	AcquisitionDate     time.Time     `json:"acquisition_date" binding:"required"`
+	AcquisitionCost     money.Decimal `json:"acquisition_cost"`
+	ResidualValue       money.Decimal `json:"residual_value"`
+	UsefulLifeMonths    int           `json:"useful_life_months" binding:"required"`
+	Location            string        `json:"location" binding:"max=100"`
+	Custodian           string        `json:"custodian" binding:"max=100"`
+	CostCenterID        *int          `json:"cost_center_id"`
+}
+
+// TransferInput é o corpo aceito em POST /assets/:id/transfer; campos vazios mantêm o atual
+type TransferInput struct {
+	Location  string `json:"location" binding:"max=100"`
+	Custodian string `json:"custodian" binding:"max=100"`
+	Notes     string `json:"notes"`
+}
+
+// DisposalInput é o corpo aceito em POST /assets/:id/dispose
+type DisposalInput struct {
+	Type     string        `json:"type" binding:"required,oneof=sale write_off"`
+	Date     time.Time     `json:"date" binding:"required"`
+	Proceeds money.Decimal `json:"proceeds"`
+	Reason   string        `json:"reason"`
+}
+
+// AssetFilter restringe a listagem de bens; campos vazios não filtram
+type AssetFilter struct {
+	Status    string
+	Category  string
+	Location  string
+	Custodian string
+}
+
+// ScheduleLine é um mês do plano de depreciação do bem
+type ScheduleLine struct {
+	Period                  string        `json:"period"`
+	Amount                  money.Decimal `json:"amount"`
+	AccumulatedDepreciation money.Decimal `json:"accumulated_depreciation"`
+	BookValue               money.Decimal `json:"book_value"`
+	Posted                  bool          `json:"posted"`
+}
+
+// DepreciationRun resume a depreciação do mês: os bens depreciados e o total lançado
+type DepreciationRun struct {
+	Period  string        `json:"period"`
+	Assets  int           `json:"assets"`
+	Entries int           `json:"entries"`
+	Amount  money.Decimal `json:"amount"`
+}
+
+// Validate confere os valores do bem; a aquisição não pode ser posterior a today (o dia da
+// empresa, à meia-noite UTC)
+func (in AssetInput) Validate(today time.Time) error {
+	if strings.TrimSpace(in.AssetNo) == "" || strings.TrimSpace(in.Name) == "" {
+		return errors.ErrInvalidAsset
+	}
+	if (in.PurchaseOrderID == nil) != (in.PurchaseOrderItemID == nil) {
+		return fmt.Errorf("%w: informe o pedido de compra e o item juntos", errors.ErrInvalidAsset)
+	}
+	if in.PurchaseOrderID == nil && !in.AcquisitionCost.IsPositive() {
+		return fmt.Errorf("%w: informe o custo de aquisição", errors.ErrInvalidAsset)
+	}
+	if in.AcquisitionCost.IsNegative() || in.ResidualValue.IsNegative() {
+		return errors.ErrInvalidAsset
+	}
+	if in.UsefulLifeMonths < 1 || in.UsefulLifeMonths > MaxUsefulLifeMonths {
+		return fmt.Errorf("%w: a vida útil deve ser de 1 a %d meses", errors.ErrInvalidAsset, MaxUsefulLifeMonths)
+	}
+	if localtime.Date(in.AcquisitionDate).After(today) {
+		return fmt.Errorf("%w: a aquisição não pode ser futura", errors.ErrInvalidAsset)
+	}
+	return nil
+}
+
+// Apply copia os campos do cadastro para o bem; custo, valor residual, vida útil e aquisição
+// só mudam enquanto o bem não foi depreciado (o serviço confere)
+func (in AssetInput) Apply(asset *Asset) {
+	asset.AssetNo = strings.TrimSpace(in.AssetNo)
+	asset.Name = strings.TrimSpace(in.Name)
+	asset.Description = in.Description
+	asset.Category = strings.TrimSpace(in.Category)
+	asset.PurchaseOrderID = in.PurchaseOrderID
+	asset.PurchaseOrderItemID = in.PurchaseOrderItemID
+	asset.AcquisitionDate = localtime.Date(in.AcquisitionDate)
+	asset.AcquisitionCost = in.AcquisitionCost.Round(2)
+	asset.ResidualValue = in.ResidualValue.Round(2)
+	asset.UsefulLifeMonths = in.UsefulLifeMonths
+	asset.Location = strings.TrimSpace(in.Location)
+	asset.Custodian = strings.TrimSpace(in.Custodian)
+	asset.CostCenterID = in.CostCenterID
+}
+
+// DepreciableAmount é o valor a depreciar: o custo menos o valor residual
+func (a Asset) DepreciableAmount() money.Decimal {
+	return a.AcquisitionCost.Sub(a.ResidualValue)
+}
+
+// BookValue é o valor contábil do bem: o custo menos a depreciação acumulada
+func (a Asset) BookValue() money.Decimal {
+	return a.AcquisitionCost.Sub(a.AccumulatedDepreciation)
+}
+
+// MonthlyDepreciation é a parcela mensal do método linear, arredondada em centavos
+func (a Asset) MonthlyDepreciation() money.Decimal {
+	if a.UsefulLifeMonths <= 0 {
+		return money.Zero
+	}
+	return a.DepreciableAmount().DivInt(a.UsefulLifeMonths).Round(2)
+}
+
+// FirstPeriod é o primeiro mês depreciado: o seguinte ao da aquisição
+func (a Asset) FirstPeriod() string {
+	acquired := time.Date(a.AcquisitionDate.Year(), a.AcquisitionDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return acquired.AddDate(0, 1, 0).Format(PeriodLayout)
+}
+
+// Validate confere os valores do bem já montado, inclusive o custo vindo do pedido de compra
+func (a Asset) Validate() error {
+	if !a.AcquisitionCost.IsPositive() || a.ResidualValue.IsNegative() || a.ResidualValue.GreaterThanOrEqual(a.AcquisitionCost) {
+		return fmt.Errorf("%w: o valor residual deve ser menor que o custo", errors.ErrInvalidAsset)
+	}
+	return nil
+}
+
+// BuildDepreciationSchedule monta o plano de depreciação do bem, mês a mês. Os centavos que
+// sobram do arredondamento das parcelas entram na última, que zera o valor a depreciar.
+func BuildDepreciationSchedule(asset Asset) []ScheduleLine {
+	depreciable := asset.DepreciableAmount()
+	monthly := asset.MonthlyDepreciation()
+	if asset.UsefulLifeMonths <= 0 || !depreciable.IsPositive() {
+		return []ScheduleLine{}
+	}
+
+	first, _ := time.Parse(PeriodLayout, asset.FirstPeriod())
+	lines := make([]ScheduleLine, 0, asset.UsefulLifeMonths)
+	accumulated := money.Zero
+	for i := 0; i < asset.UsefulLifeMonths; i++ {
+		amount := monthly
+		if i == asset.UsefulLifeMonths-1 {
+			amount = depreciable.Sub(accumulated)
+		}
+		amount = money.Min(amount, depreciable.Sub(accumulated))
+		if !amount.IsPositive() {
+			continue
+		}
+		accumulated = accumulated.Add(amount)
+		period := first.AddDate(0, i, 0).Format(PeriodLayout)
+		lines = append(lines, ScheduleLine{
+			Period:                  period,
+			Amount:                  amount,
+			AccumulatedDepreciation: accumulated,
+			BookValue:               asset.AcquisitionCost.Sub(accumulated),
+			Posted:                  asset.DepreciatedThrough != "" && period <= asset.DepreciatedThrough,
+		})
+	}
+	return lines
+}
+
+// PendingDepreciation devolve as depreciações ainda não registradas do bem até o mês through
+// (AAAA-MM, inclusivo), na ordem do plano. Cada uma é lançada no último dia do seu mês.
+func PendingDepreciation(asset Asset, through string) []AssetDepreciation {
+	var pending []AssetDepreciation
+	for _, line := range BuildDepreciationSchedule(asset) {
+		if line.Posted {
+			continue
+		}
+		if line.Period > through {
+			break
+		}
+		pending = append(pending, AssetDepreciation{
+			AssetID:                 asset.ID,
+			Period:                  line.Period,
+			EntryDate:               PeriodEnd(line.Period),
+			Amount:                  line.Amount,
+			AccumulatedDepreciation: line.AccumulatedDepreciation,
+			BookValue:               line.BookValue,
+		})
+	}
+	return pending
+}
+
+// ApplyDepreciation atualiza o acumulado e o último mês depreciado do bem
+func (a *Asset) ApplyDepreciation(depreciations []AssetDepreciation) {
+	if len(depreciations) == 0 {
+		return
+	}
+	last := depreciations[len(depreciations)-1]
+	a.AccumulatedDepreciation = last.AccumulatedDepreciation
+	a.DepreciatedThrough = last.Period
+}
+
+// Dispose baixa o bem na data informada e apura o ganho (positivo) ou a perda (negativo): o
+// valor recebido menos o valor contábil. Na baixa por perda não há valor recebido. O bem já
+// deve estar depreciado até o mês da baixa.
+func (a *Asset) Dispose(input DisposalInput, by string) error {
+	if a.Status != StatusActive {
+		return errors.ErrAssetNotActive
+	}
+	date := localtime.Date(input.Date)
+	if date.Before(a.AcquisitionDate) {
+		return fmt.Errorf("%w: a baixa não pode ser anterior à aquisição", errors.ErrInvalidAssetDisposal)
+	}
+	if a.DepreciatedThrough > date.Format(PeriodLayout) {
+		return fmt.Errorf("%w: o bem já foi depreciado depois da data da baixa", errors.ErrInvalidAssetDisposal)
+	}
+
+	proceeds := input.Proceeds.Round(2)
+	status := StatusDisposed
+	if input.Type == DisposalWriteOff {
+		if !proceeds.IsZero() {
+			return fmt.Errorf("%w: a baixa por perda não tem valor recebido", errors.ErrInvalidAssetDisposal)
+		}
+		status = StatusWrittenOff
+	} else if proceeds.IsNegative() {
+		return errors.ErrInvalidAssetDisposal
+	}
+
+	a.Status = status
+	a.DisposalType = input.Type
+	a.DisposedAt = &date
+	a.DisposedBy = by
+	a.DisposalProceeds = proceeds
+	a.DisposalGainLoss = proceeds.Sub(a.BookValue())
+	a.DisposalReason = strings.TrimSpace(input.Reason)
+	return nil
+}
+
+// ParsePeriod valida o mês no formato AAAA-MM
+func ParsePeriod(period string) (time.Time, error) {
+	month, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return time.Time{}, errors.InvalidParam("o mês deve estar no formato AAAA-MM")
+	}
+	return month, nil
+}
+
+// PeriodEnd é o último dia do mês AAAA-MM, à meia-noite UTC
+func PeriodEnd(period string) time.Time {
+	month, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return time.Time{}
+	}
+	return month.AddDate(0, 1, -1)
+}
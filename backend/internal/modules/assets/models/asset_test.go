@@ -0,0 +1,105 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAsset() Asset {
+	return Asset{
+		ID:               1,
+		AssetNo:          "PAT-1",
+		AcquisitionDate:  time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC),
+		AcquisitionCost:  money.FromInt(1000),
+		ResidualValue:    money.FromInt(100),
+		UsefulLifeMonths: 7,
+		Status:           StatusActive,
+	}
+}
+
+func TestBuildDepreciationSchedule(t *testing.T) {
+	asset := newAsset()
+	schedule := BuildDepreciationSchedule(asset)
+	require.Len(t, schedule, 7)
+
+	// 900 / 7 = 128.57; a última parcela absorve os centavos: 900 - 6 × 128.57 = 128.58
+	assert.Equal(t, "2026-02", schedule[0].Period, "começa no mês seguinte à aquisição")
+	assert.Equal(t, "128.57", schedule[0].Amount.StringFixed(2))
+	assert.Equal(t, "2026-08", schedule[6].Period)
+	assert.Equal(t, "128.58", schedule[6].Amount.StringFixed(2))
+	assert.Equal(t, "900.00", schedule[6].AccumulatedDepreciation.StringFixed(2))
+	assert.Equal(t, "100.00", schedule[6].BookValue.StringFixed(2), "termina no valor residual")
+
+	asset.DepreciatedThrough = "2026-03"
+	schedule = BuildDepreciationSchedule(asset)
+	assert.True(t, schedule[1].Posted)
+	assert.False(t, schedule[2].Posted)
+}
+
+func TestPendingDepreciation(t *testing.T) {
+	asset := newAsset()
+	pending := PendingDepreciation(asset, "2026-04")
+	require.Len(t, pending, 3, "fevereiro a abril, inclusive os meses em atraso")
+	assert.Equal(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), pending[0].EntryDate, "lançada no último dia do mês")
+
+	asset.ApplyDepreciation(pending)
+	assert.Equal(t, "2026-04", asset.DepreciatedThrough)
+	assert.Equal(t, "385.71", asset.AccumulatedDepreciation.StringFixed(2))
+	assert.Empty(t, PendingDepreciation(asset, "2026-04"), "o mesmo mês não é depreciado duas vezes")
+	assert.Len(t, PendingDepreciation(asset, "2030-01"), 4, "o plano termina na vida útil")
+}
+
+func TestAssetDispose(t *testing.T) {
+	asset := newAsset()
+	asset.ApplyDepreciation(PendingDepreciation(asset, "2026-05"))
+
+	// Valor contábil 1000 - 514.28 = 485.72; vendido por 600: ganho de 114.28
+	sale := asset
+	require.NoError(t, sale.Dispose(DisposalInput{Type: DisposalSale, Date: time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC), Proceeds: money.FromInt(600)}, "ana"))
+	assert.Equal(t, StatusDisposed, sale.Status)
+	assert.Equal(t, "114.28", sale.DisposalGainLoss.StringFixed(2))
+	assert.ErrorIs(t, sale.Dispose(DisposalInput{Type: DisposalSale, Date: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)}, "ana"), errors.ErrAssetNotActive)
+
+	writeOff := asset
+	require.NoError(t, writeOff.Dispose(DisposalInput{Type: DisposalWriteOff, Date: time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)}, "ana"))
+	assert.Equal(t, StatusWrittenOff, writeOff.Status)
+	assert.Equal(t, "-485.72", writeOff.DisposalGainLoss.StringFixed(2), "a perda é o valor contábil")
+
+	withProceeds := asset
+	err := withProceeds.Dispose(DisposalInput{Type: DisposalWriteOff, Date: time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC), Proceeds: money.FromInt(1)}, "ana")
+	assert.ErrorIs(t, err, errors.ErrInvalidAssetDisposal)
+
+	early := asset
+	err = early.Dispose(DisposalInput{Type: DisposalSale, Date: time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)}, "ana")
+	assert.ErrorIs(t, err, errors.ErrInvalidAssetDisposal, "já depreciado depois da data da baixa")
+}
+
+func TestAssetInputValidate(t *testing.T) {
+	today := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	orderID := 3
+	input := AssetInput{AssetNo: "PAT-1", Name: "Empilhadeira", AcquisitionDate: today, AcquisitionCost: money.FromInt(50000), UsefulLifeMonths: 120}
+	assert.NoError(t, input.Validate(today))
+
+	fromOrder := input
+	fromOrder.AcquisitionCost = money.Zero
+	fromOrder.PurchaseOrderID = &orderID
+	assert.ErrorIs(t, fromOrder.Validate(today), errors.ErrInvalidAsset, "pedido sem o item")
+	fromOrder.PurchaseOrderItemID = &orderID
+	assert.NoError(t, fromOrder.Validate(today), "o custo vem do item do pedido")
+
+	future := input
+	future.AcquisitionDate = today.AddDate(0, 0, 1)
+	assert.ErrorIs(t, future.Validate(today), errors.ErrInvalidAsset)
+
+	noLife := input
+	noLife.UsefulLifeMonths = 0
+	assert.ErrorIs(t, noLife.Validate(today), errors.ErrInvalidAsset)
+
+	asset := Asset{AcquisitionCost: money.FromInt(100), ResidualValue: money.FromInt(100)}
+	assert.ErrorIs(t, asset.Validate(), errors.ErrInvalidAsset, "residual igual ao custo")
+}
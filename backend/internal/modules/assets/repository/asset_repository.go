@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/assets/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"context"
+	stderrors "errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AssetRepository mantém os bens do ativo imobilizado, as movimentações de local e de
+// responsável e as depreciações mensais
+type AssetRepository interface {
+	ListAssets(ctx context.Context, filter models.AssetFilter) ([]models.Asset, error)
+	GetAsset(ctx context.Context, id int) (*models.Asset, error)
+	CreateAsset(ctx context.Context, asset *models.Asset) error
+	UpdateAsset(ctx context.Context, asset *models.Asset) error
+	TransferAsset(ctx context.Context, asset *models.Asset, movement *models.AssetMovement) error
+
+	GetPurchaseOrderItem(ctx context.Context, orderID, itemID int) (*sales.PurchaseOrder, *sales.POItem, error)
+	CountItemAssets(ctx context.Context, itemID, excludeID int) (int64, error)
+
+	ListActiveAssets(ctx context.Context) ([]models.Asset, error)
+	ListDepreciations(ctx context.Context, assetID int) ([]models.AssetDepreciation, error)
+	RecordDepreciation(ctx context.Context, asset *models.Asset, depreciations []models.AssetDepreciation, previous string) error
+	DisposeAsset(ctx context.Context, asset *models.Asset, depreciations []models.AssetDepreciation, previous string) error
+}
+
+type assetRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewAssetRepository cria uma nova instância do repositório
+func NewAssetRepository() (AssetRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &assetRepository{
+		db:     db,
+		logger: logger.WithModule("asset_repository"),
+	}, nil
+}
+
+// ListAssets lista os bens da empresa pelo número de patrimônio
+func (r *assetRepository) ListAssets(ctx context.Context, filter models.AssetFilter) ([]models.Asset, error) {
+	query := r.db.WithContext(ctx)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Location != "" {
+		query = query.Where("location = ?", filter.Location)
+	}
+	if filter.Custodian != "" {
+		query = query.Where("custodian = ?", filter.Custodian)
+	}
+
+	var assets []models.Asset
+	if err := query.Order("asset_no ASC").Find(&assets).Error; err != nil {
+		r.logger.Error("erro ao listar bens patrimoniais", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar bens patrimoniais")
+	}
+	return assets, nil
+}
+
+// GetAsset busca o bem com o histórico de movimentações, o mais recente primeiro
+func (r *assetRepository) GetAsset(ctx context.Context, id int) (*models.Asset, error) {
+	var asset models.Asset
+	err := r.db.WithContext(ctx).
+		Preload("Movements", func(db *gorm.DB) *gorm.DB { return db.Order("moved_at DESC, id DESC") }).
+		First(&asset, id).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAssetNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar bem patrimonial")
+	}
+	return &asset, nil
+}
+
+// CreateAsset grava o bem; o número de patrimônio é único na empresa
+func (r *assetRepository) CreateAsset(ctx context.Context, asset *models.Asset) error {
+	if err := r.checkAssetNo(ctx, asset); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Omit("Movements").Create(asset).Error; err != nil {
+		r.logger.Error("erro ao criar bem patrimonial", zap.Error(err), zap.String("asset_no", asset.AssetNo))
+		return errors.WrapError(err, "falha ao criar bem patrimonial")
+	}
+	return nil
+}
+
+// UpdateAsset altera o cadastro do bem ativo; local e responsável mudam pela transferência
+func (r *assetRepository) UpdateAsset(ctx context.Context, asset *models.Asset) error {
+	if err := r.checkAssetNo(ctx, asset); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(asset).
+		Where("status = ?", models.StatusActive).
+		Select("asset_no", "name", "description", "category", "purchase_order_id", "purchase_order_item_id",
+			"acquisition_date", "acquisition_cost", "residual_value", "useful_life_months", "cost_center_id", "updated_at").
+		Updates(asset)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar bem patrimonial", zap.Error(result.Error), zap.Int("id", asset.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar bem patrimonial")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrAssetNotActive
+	}
+	return nil
+}
+
+// checkAssetNo recusa o número de patrimônio já usado por outro bem
+func (r *assetRepository) checkAssetNo(ctx context.Context, asset *models.Asset) error {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Asset{}).
+		Where("asset_no = ? AND id <> ?", asset.AssetNo, asset.ID).
+		Count(&count).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao verificar número de patrimônio")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateAssetNo
+	}
+	return nil
+}
+
+// TransferAsset grava o novo local e responsável do bem junto com a movimentação
+func (r *assetRepository) TransferAsset(ctx context.Context, asset *models.Asset, movement *models.AssetMovement) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(asset).
+			Where("status = ?", models.StatusActive).
+			Select("location", "custodian", "updated_at").
+			Updates(asset)
+		if result.Error != nil {
+			return errors.WrapError(result.Error, "falha ao transferir bem patrimonial")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrAssetNotActive
+		}
+		if err := tx.Create(movement).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar movimentação do bem")
+		}
+		return nil
+	})
+}
+
+// GetPurchaseOrderItem busca o item do pedido de compra do qual o bem foi adquirido
+func (r *assetRepository) GetPurchaseOrderItem(ctx context.Context, orderID, itemID int) (*sales.PurchaseOrder, *sales.POItem, error) {
+	conn := r.db.WithContext(ctx)
+	var order sales.PurchaseOrder
+	if err := conn.First(&order, orderID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.ErrPurchaseOrderNotFound
+		}
+		return nil, nil, errors.WrapError(err, "falha ao buscar pedido de compra")
+	}
+
+	var item sales.POItem
+	if err := conn.Where("purchase_order_id = ?", orderID).First(&item, itemID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.ErrPurchaseItemNotFound
+		}
+		return nil, nil, errors.WrapError(err, "falha ao buscar item do pedido de compra")
+	}
+	return &order, &item, nil
+}
+
+// CountItemAssets conta os bens já patrimoniados a partir do item do pedido, fora excludeID
+func (r *assetRepository) CountItemAssets(ctx context.Context, itemID, excludeID int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Asset{}).
+		Where("purchase_order_item_id = ? AND id <> ?", itemID, excludeID).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.WrapError(err, "falha ao contar bens do item do pedido de compra")
+	}
+	return count, nil
+}
+
+// ListActiveAssets lista os bens ainda em uso, candidatos à depreciação do mês
+func (r *assetRepository) ListActiveAssets(ctx context.Context) ([]models.Asset, error) {
+	var assets []models.Asset
+	err := r.db.WithContext(ctx).
+		Where("status = ?", models.StatusActive).
+		Order("id ASC").
+		Find(&assets).Error
+	if err != nil {
+		r.logger.Error("erro ao listar bens ativos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar bens ativos")
+	}
+	return assets, nil
+}
+
+// ListDepreciations lista as depreciações registradas do bem em ordem cronológica
+func (r *assetRepository) ListDepreciations(ctx context.Context, assetID int) ([]models.AssetDepreciation, error) {
+	var depreciations []models.AssetDepreciation
+	err := r.db.WithContext(ctx).
+		Where("asset_id = ?", assetID).
+		Order("period ASC").
+		Find(&depreciations).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao listar depreciações do bem")
+	}
+	return depreciations, nil
+}
+
+// RecordDepreciation grava as depreciações do bem e o novo acumulado, desde que o bem ainda
+// esteja depreciado até previous: duas rodadas simultâneas não depreciam o mesmo mês duas vezes
+func (r *assetRepository) RecordDepreciation(ctx context.Context, asset *models.Asset, depreciations []models.AssetDepreciation, previous string) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := r.saveDepreciation(tx, asset, depreciations, previous, "accumulated_depreciation", "depreciated_through", "updated_at"); err != nil {
+			return err
+		}
+		r.logger.Info("bem depreciado", zap.Int("id", asset.ID), zap.String("through", asset.DepreciatedThrough))
+		return nil
+	})
+}
+
+// DisposeAsset grava as depreciações até o mês da baixa e a baixa do bem numa única transação
+func (r *assetRepository) DisposeAsset(ctx context.Context, asset *models.Asset, depreciations []models.AssetDepreciation, previous string) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := r.saveDepreciation(tx, asset, depreciations, previous,
+			"accumulated_depreciation", "depreciated_through", "status", "disposal_type", "disposed_at", "disposed_by",
+			"disposal_proceeds", "disposal_gain_loss", "disposal_reason", "updated_at")
+		if err != nil {
+			return err
+		}
+		r.logger.Info("bem baixado", zap.Int("id", asset.ID), zap.String("type", asset.DisposalType))
+		return nil
+	})
+}
+
+// saveDepreciation insere as depreciações e grava os campos do bem ainda ativo
+func (r *assetRepository) saveDepreciation(tx *gorm.DB, asset *models.Asset, depreciations []models.AssetDepreciation, previous string, fields ...string) error {
+	if len(depreciations) > 0 {
+		if err := tx.Omit("Asset").Create(&depreciations).Error; err != nil {
+			r.logger.Error("erro ao gravar depreciação", zap.Error(err), zap.Int("asset_id", asset.ID))
+			return errors.WrapError(err, "falha ao gravar depreciação do bem")
+		}
+	}
+
+	result := tx.Model(asset).
+		Where("status = ? AND depreciated_through = ?", models.StatusActive, previous).
+		Select(fields).
+		Updates(asset)
+	if result.Error != nil {
+		return errors.WrapError(result.Error, "falha ao atualizar bem patrimonial")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrAssetNotActive
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/assets/models"
+	"ERP-ONSMART/backend/internal/modules/assets/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// AssetService cadastra os bens do ativo imobilizado, acompanha local e responsável, deprecia os
+// bens mês a mês e registra a baixa por venda ou perda
+type AssetService struct {
+	newRepo func() (repository.AssetRepository, error)
+	now     func() time.Time
+	today   func(ctx context.Context) time.Time
+
+	mu   sync.Mutex
+	repo repository.AssetRepository
+}
+
+// NewAssetService cria o serviço sobre o repositório informado
+func NewAssetService(newRepo func() (repository.AssetRepository, error)) *AssetService {
+	return &AssetService{
+		newRepo: newRepo,
+		now:     time.Now,
+		today:   localtime.Today,
+	}
+}
+
+var defaultService = NewAssetService(repository.NewAssetRepository)
+
+// ListAssets lista os bens patrimoniais
+func ListAssets(ctx context.Context, filter models.AssetFilter) ([]models.Asset, error) {
+	return defaultService.ListAssets(ctx, filter)
+}
+
+// GetAsset busca um bem com as movimentações
+func GetAsset(ctx context.Context, id int) (*models.Asset, error) {
+	return defaultService.GetAsset(ctx, id)
+}
+
+// CreateAsset valida e grava um bem
+func CreateAsset(ctx context.Context, input models.AssetInput, username string) (*models.Asset, error) {
+	return defaultService.CreateAsset(ctx, input, username)
+}
+
+// UpdateAsset valida e altera o cadastro de um bem ativo
+func UpdateAsset(ctx context.Context, id int, input models.AssetInput) (*models.Asset, error) {
+	return defaultService.UpdateAsset(ctx, id, input)
+}
+
+// TransferAsset muda o local ou o responsável pelo bem
+func TransferAsset(ctx context.Context, id int, input models.TransferInput, username string) (*models.Asset, error) {
+	return defaultService.Transfer(ctx, id, input, username)
+}
+
+// GetSchedule monta o plano de depreciação do bem
+func GetSchedule(ctx context.Context, id int) ([]models.ScheduleLine, error) {
+	return defaultService.Schedule(ctx, id)
+}
+
+// ListDepreciations lista as depreciações registradas do bem
+func ListDepreciations(ctx context.Context, id int) ([]models.AssetDepreciation, error) {
+	return defaultService.ListDepreciations(ctx, id)
+}
+
+// RunDepreciation deprecia os bens ativos até o mês informado
+func RunDepreciation(ctx context.Context, period string) (*models.DepreciationRun, error) {
+	return defaultService.RunDepreciation(ctx, period)
+}
+
+// DisposeAsset baixa o bem por venda ou perda
+func DisposeAsset(ctx context.Context, id int, input models.DisposalInput, username string) (*models.Asset, error) {
+	return defaultService.Dispose(ctx, id, input, username)
+}
+
+func (s *AssetService) repository() (repository.AssetRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListAssets lista os bens conforme o filtro
+func (s *AssetService) ListAssets(ctx context.Context, filter models.AssetFilter) ([]models.Asset, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListAssets(ctx, filter)
+}
+
+// GetAsset busca o bem com o histórico de movimentações
+func (s *AssetService) GetAsset(ctx context.Context, id int) (*models.Asset, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAsset(ctx, id)
+}
+
+// CreateAsset grava o bem ativo. Adquirido por pedido de compra, o pedido deve estar recebido,
+// cada unidade do item vira no máximo um bem e, sem custo informado, vale o custo unitário do
+// item (total do item pela quantidade).
+func (s *AssetService) CreateAsset(ctx context.Context, input models.AssetInput, username string) (*models.Asset, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := input.Validate(s.today(ctx)); err != nil {
+		return nil, err
+	}
+
+	asset := &models.Asset{Status: models.StatusActive, CreatedBy: username}
+	input.Apply(asset)
+	if err := s.applyPurchaseOrder(ctx, repo, asset); err != nil {
+		return nil, err
+	}
+	if err := asset.Validate(); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// UpdateAsset altera o cadastro do bem ativo. Depois da primeira depreciação, os valores que
+// formam o plano (custo, valor residual, vida útil, aquisição e pedido de compra) ficam fixos.
+func (s *AssetService) UpdateAsset(ctx context.Context, id int, input models.AssetInput) (*models.Asset, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := input.Validate(s.today(ctx)); err != nil {
+		return nil, err
+	}
+	asset, err := repo.GetAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if asset.Status != models.StatusActive {
+		return nil, errors.ErrAssetNotActive
+	}
+
+	previous := *asset
+	location, custodian := asset.Location, asset.Custodian
+	input.Apply(asset)
+	asset.Location, asset.Custodian = location, custodian
+	if err := s.applyPurchaseOrder(ctx, repo, asset); err != nil {
+		return nil, err
+	}
+	if previous.DepreciatedThrough != "" && scheduleChanged(previous, *asset) {
+		return nil, errors.ErrAssetAlreadyDepreciated
+	}
+	if err := asset.Validate(); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// scheduleChanged indica se a alteração muda o plano de depreciação do bem
+func scheduleChanged(before, after models.Asset) bool {
+	return !before.AcquisitionCost.Equal(after.AcquisitionCost) ||
+		!before.ResidualValue.Equal(after.ResidualValue) ||
+		before.UsefulLifeMonths != after.UsefulLifeMonths ||
+		!before.AcquisitionDate.Equal(after.AcquisitionDate) ||
+		!equalIntPtr(before.PurchaseOrderItemID, after.PurchaseOrderItemID)
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// applyPurchaseOrder confere o item do pedido de compra de origem do bem e completa o custo
+func (s *AssetService) applyPurchaseOrder(ctx context.Context, repo repository.AssetRepository, asset *models.Asset) error {
+	if asset.PurchaseOrderID == nil {
+		return nil
+	}
+	order, item, err := repo.GetPurchaseOrderItem(ctx, *asset.PurchaseOrderID, *asset.PurchaseOrderItemID)
+	if err != nil {
+		return err
+	}
+	if order.Status != sales.POStatusReceived {
+		return fmt.Errorf("%w: o pedido de compra ainda não foi recebido", errors.ErrInvalidAsset)
+	}
+	registered, err := repo.CountItemAssets(ctx, item.ID, asset.ID)
+	if err != nil {
+		return err
+	}
+	if registered >= int64(item.Quantity) {
+		return errors.ErrPurchaseItemFullyTracked
+	}
+	if asset.AcquisitionCost.IsZero() && item.Quantity > 0 {
+		asset.AcquisitionCost = item.Total.DivInt(item.Quantity).Round(2)
+	}
+	return nil
+}
+
+// Transfer registra a troca de local ou de responsável pelo bem ativo
+func (s *AssetService) Transfer(ctx context.Context, id int, input models.TransferInput, username string) (*models.Asset, error) {
+	location, custodian := strings.TrimSpace(input.Location), strings.TrimSpace(input.Custodian)
+	if location == "" && custodian == "" {
+		return nil, fmt.Errorf("%w: informe o novo local ou o novo responsável", errors.ErrInvalidAsset)
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	asset, err := repo.GetAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if asset.Status != models.StatusActive {
+		return nil, errors.ErrAssetNotActive
+	}
+	if location == "" {
+		location = asset.Location
+	}
+	if custodian == "" {
+		custodian = asset.Custodian
+	}
+	if location == asset.Location && custodian == asset.Custodian {
+		return asset, nil
+	}
+
+	movement := &models.AssetMovement{
+		AssetID:       asset.ID,
+		FromLocation:  asset.Location,
+		ToLocation:    location,
+		FromCustodian: asset.Custodian,
+		ToCustodian:   custodian,
+		Notes:         strings.TrimSpace(input.Notes),
+		MovedBy:       username,
+		MovedAt:       s.now(),
+	}
+	asset.Location, asset.Custodian = location, custodian
+	if err := repo.TransferAsset(ctx, asset, movement); err != nil {
+		return nil, err
+	}
+	asset.Movements = append([]models.AssetMovement{*movement}, asset.Movements...)
+	return asset, nil
+}
+
+// Schedule monta o plano de depreciação do bem, marcando os meses já registrados
+func (s *AssetService) Schedule(ctx context.Context, id int) ([]models.ScheduleLine, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	asset, err := repo.GetAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildDepreciationSchedule(*asset), nil
+}
+
+// ListDepreciations lista as depreciações registradas do bem
+func (s *AssetService) ListDepreciations(ctx context.Context, id int) ([]models.AssetDepreciation, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GetAsset(ctx, id); err != nil {
+		return nil, err
+	}
+	return repo.ListDepreciations(ctx, id)
+}
+
+// RunDepreciation registra, para cada bem ativo, os meses do plano ainda não depreciados até
+// period (AAAA-MM), inclusive os atrasados. Rodar de novo o mesmo mês não deprecia nada. As
+// depreciações são contabilizadas depois pelo lote do razão (POST /ledger/post).
+func (s *AssetService) RunDepreciation(ctx context.Context, period string) (*models.DepreciationRun, error) {
+	month, err := models.ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	today := s.today(ctx)
+	if month.After(time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		return nil, errors.InvalidParam("o mês da depreciação não pode ser futuro")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	assets, err := repo.ListActiveAssets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.DepreciationRun{Period: period, Amount: money.Zero}
+	for i := range assets {
+		asset := &assets[i]
+		pending := models.PendingDepreciation(*asset, period)
+		if len(pending) == 0 {
+			continue
+		}
+		previous := asset.DepreciatedThrough
+		asset.ApplyDepreciation(pending)
+		if err := repo.RecordDepreciation(ctx, asset, pending, previous); err != nil {
+			return nil, err
+		}
+		run.Assets++
+		run.Entries += len(pending)
+		for _, depreciation := range pending {
+			run.Amount = run.Amount.Add(depreciation.Amount)
+		}
+	}
+	return run, nil
+}
+
+// Dispose baixa o bem ativo por venda ou perda na data informada. O bem é depreciado até o mês
+// da baixa e o ganho ou a perda é apurado sobre o valor contábil resultante.
+func (s *AssetService) Dispose(ctx context.Context, id int, input models.DisposalInput, username string) (*models.Asset, error) {
+	if localtime.Date(input.Date).After(s.today(ctx)) {
+		return nil, fmt.Errorf("%w: a baixa não pode ser futura", errors.ErrInvalidAssetDisposal)
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	asset, err := repo.GetAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if asset.Status != models.StatusActive {
+		return nil, errors.ErrAssetNotActive
+	}
+
+	previous := asset.DepreciatedThrough
+	pending := models.PendingDepreciation(*asset, localtime.Date(input.Date).Format(models.PeriodLayout))
+	asset.ApplyDepreciation(pending)
+	if err := asset.Dispose(input, username); err != nil {
+		return nil, err
+	}
+	if err := repo.DisposeAsset(ctx, asset, pending, previous); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/assets/models"
+	"ERP-ONSMART/backend/internal/modules/assets/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	assets        map[int]*models.Asset
+	depreciations []models.AssetDepreciation
+	movements     []models.AssetMovement
+	order         sales.PurchaseOrder
+	item          sales.POItem
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		assets: map[int]*models.Asset{},
+		order:  sales.PurchaseOrder{ID: 5, Status: sales.POStatusReceived},
+		item:   sales.POItem{ID: 8, PurchaseOrderID: 5, Quantity: 2, Total: money.FromInt(7000)},
+	}
+}
+
+func (r *fakeRepo) ListAssets(ctx context.Context, filter models.AssetFilter) ([]models.Asset, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetAsset(ctx context.Context, id int) (*models.Asset, error) {
+	asset, ok := r.assets[id]
+	if !ok {
+		return nil, appErrors.ErrAssetNotFound
+	}
+	copied := *asset
+	return &copied, nil
+}
+
+func (r *fakeRepo) CreateAsset(ctx context.Context, asset *models.Asset) error {
+	asset.ID = len(r.assets) + 1
+	copied := *asset
+	r.assets[asset.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) UpdateAsset(ctx context.Context, asset *models.Asset) error {
+	copied := *asset
+	r.assets[asset.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) TransferAsset(ctx context.Context, asset *models.Asset, movement *models.AssetMovement) error {
+	r.movements = append(r.movements, *movement)
+	return r.UpdateAsset(ctx, asset)
+}
+
+func (r *fakeRepo) GetPurchaseOrderItem(ctx context.Context, orderID, itemID int) (*sales.PurchaseOrder, *sales.POItem, error) {
+	if orderID != r.order.ID || itemID != r.item.ID {
+		return nil, nil, appErrors.ErrPurchaseItemNotFound
+	}
+	order, item := r.order, r.item
+	return &order, &item, nil
+}
+
+func (r *fakeRepo) CountItemAssets(ctx context.Context, itemID, excludeID int) (int64, error) {
+	var count int64
+	for _, asset := range r.assets {
+		if asset.PurchaseOrderItemID != nil && *asset.PurchaseOrderItemID == itemID && asset.ID != excludeID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeRepo) ListActiveAssets(ctx context.Context) ([]models.Asset, error) {
+	var assets []models.Asset
+	for id := 1; id <= len(r.assets); id++ {
+		if asset := r.assets[id]; asset.Status == models.StatusActive {
+			assets = append(assets, *asset)
+		}
+	}
+	return assets, nil
+}
+
+func (r *fakeRepo) ListDepreciations(ctx context.Context, assetID int) ([]models.AssetDepreciation, error) {
+	return r.depreciations, nil
+}
+
+func (r *fakeRepo) RecordDepreciation(ctx context.Context, asset *models.Asset, depreciations []models.AssetDepreciation, previous string) error {
+	if r.assets[asset.ID].DepreciatedThrough != previous {
+		return appErrors.ErrAssetNotActive
+	}
+	r.depreciations = append(r.depreciations, depreciations...)
+	return r.UpdateAsset(ctx, asset)
+}
+
+func (r *fakeRepo) DisposeAsset(ctx context.Context, asset *models.Asset, depreciations []models.AssetDepreciation, previous string) error {
+	return r.RecordDepreciation(ctx, asset, depreciations, previous)
+}
+
+func newTestService(repo *fakeRepo) *AssetService {
+	s := NewAssetService(func() (repository.AssetRepository, error) { return repo, nil })
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC) }
+	s.today = func(context.Context) time.Time { return time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func assetInput(assetNo string) models.AssetInput {
+	return models.AssetInput{
+		AssetNo:          assetNo,
+		Name:             "Notebook",
+		AcquisitionDate:  time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC),
+		AcquisitionCost:  money.FromInt(3600),
+		UsefulLifeMonths: 36,
+		Location:         "Matriz",
+		Custodian:        "ana",
+	}
+}
+
+func TestCreateAssetFromPurchaseOrder(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	orderID, itemID := 5, 8
+	input := assetInput("PAT-1")
+	input.AcquisitionCost = money.Zero
+	input.PurchaseOrderID, input.PurchaseOrderItemID = &orderID, &itemID
+
+	asset, err := s.CreateAsset(ctx, input, "joao")
+	require.NoError(t, err)
+	assert.Equal(t, "3500.00", asset.AcquisitionCost.StringFixed(2), "custo unitário do item")
+	assert.Equal(t, models.StatusActive, asset.Status)
+
+	input.AssetNo = "PAT-2"
+	_, err = s.CreateAsset(ctx, input, "joao")
+	require.NoError(t, err)
+
+	input.AssetNo = "PAT-3"
+	_, err = s.CreateAsset(ctx, input, "joao")
+	assert.ErrorIs(t, err, appErrors.ErrPurchaseItemFullyTracked, "o item tem duas unidades")
+
+	repo.order.Status = sales.POStatusSent
+	repo.item.Quantity = 5
+	_, err = s.CreateAsset(ctx, input, "joao")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidAsset, "pedido ainda não recebido")
+}
+
+func TestRunDepreciation(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	_, err := s.CreateAsset(ctx, assetInput("PAT-1"), "joao")
+	require.NoError(t, err)
+
+	// Julho a setembro: 3 × 100
+	run, err := s.RunDepreciation(ctx, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, 1, run.Assets)
+	assert.Equal(t, 3, run.Entries)
+	assert.Equal(t, "300.00", run.Amount.StringFixed(2))
+	assert.Equal(t, "2026-09", repo.assets[1].DepreciatedThrough)
+
+	run, err = s.RunDepreciation(ctx, "2026-09")
+	require.NoError(t, err)
+	assert.Zero(t, run.Entries, "rodar de novo o mesmo mês não deprecia nada")
+
+	_, err = s.RunDepreciation(ctx, "2026-11")
+	assert.Error(t, err, "mês futuro")
+	_, err = s.RunDepreciation(ctx, "09/2026")
+	assert.Error(t, err)
+
+	_, err = s.UpdateAsset(ctx, 1, models.AssetInput{AssetNo: "PAT-1", Name: "Notebook", AcquisitionDate: time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC),
+		AcquisitionCost: money.FromInt(4000), UsefulLifeMonths: 36})
+	assert.ErrorIs(t, err, appErrors.ErrAssetAlreadyDepreciated)
+
+	updated, err := s.UpdateAsset(ctx, 1, models.AssetInput{AssetNo: "PAT-1", Name: "Notebook Pro", AcquisitionDate: time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC),
+		AcquisitionCost: money.FromInt(3600), UsefulLifeMonths: 36})
+	require.NoError(t, err)
+	assert.Equal(t, "Notebook Pro", updated.Name)
+	assert.Equal(t, "Matriz", updated.Location, "o local muda só pela transferência")
+}
+
+func TestTransferAsset(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	_, err := s.CreateAsset(ctx, assetInput("PAT-1"), "joao")
+	require.NoError(t, err)
+
+	asset, err := s.Transfer(ctx, 1, models.TransferInput{Custodian: "bruno"}, "joao")
+	require.NoError(t, err)
+	assert.Equal(t, "Matriz", asset.Location)
+	assert.Equal(t, "bruno", asset.Custodian)
+	require.Len(t, repo.movements, 1)
+	assert.Equal(t, "ana", repo.movements[0].FromCustodian)
+
+	_, err = s.Transfer(ctx, 1, models.TransferInput{}, "joao")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidAsset)
+}
+
+func TestDisposeAsset(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	_, err := s.CreateAsset(ctx, assetInput("PAT-1"), "joao")
+	require.NoError(t, err)
+
+	// Depreciado de julho a outubro (400) ao baixar: valor contábil 3200, vendido por 3000
+	asset, err := s.Dispose(ctx, 1, models.DisposalInput{Type: models.DisposalSale, Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), Proceeds: money.FromInt(3000)}, "joao")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDisposed, asset.Status)
+	assert.Equal(t, "400.00", asset.AccumulatedDepreciation.StringFixed(2))
+	assert.Equal(t, "-200.00", asset.DisposalGainLoss.StringFixed(2))
+	assert.Len(t, repo.depreciations, 4)
+
+	_, err = s.Dispose(ctx, 1, models.DisposalInput{Type: models.DisposalWriteOff, Date: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)}, "joao")
+	assert.ErrorIs(t, err, appErrors.ErrAssetNotActive)
+
+	_, err = s.Transfer(ctx, 1, models.TransferInput{Location: "Filial"}, "joao")
+	assert.ErrorIs(t, err, appErrors.ErrAssetNotActive)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/attachments/models"
+	"ERP-ONSMART/backend/internal/modules/attachments/service"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os anexos do documento
+func ListAttachmentsHandler(c *gin.Context) {
+	entityType, entityID, ok := parseEntityParams(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := service.GetAttachments(c.Request.Context(), entityType, entityID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar anexos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+// Anexa um arquivo ao documento, enviado como multipart (campo "file"; opcionais "description" e "uploaded_by")
+// @Accept multipart/form-data
+func UploadAttachmentHandler(c *gin.Context) {
+	entityType, entityID, ok := parseEntityParams(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(errors.InvalidRequest(err).WithFieldError("file", "campo obrigatório"))
+		return
+	}
+	if max := service.MaxUploadSize(); max > 0 && fileHeader.Size > max {
+		c.Error(errors.ErrAttachmentTooLarge)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+	defer file.Close()
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	attachment, err := service.UploadAttachment(c.Request.Context(), entityType, entityID, service.Upload{
+		FileName:    fileHeader.Filename,
+		ContentType: contentType,
+		Size:        fileHeader.Size,
+		Content:     file,
+		Description: c.PostForm("description"),
+		UploadedBy:  c.PostForm("uploaded_by"),
+	})
+	if err != nil {
+		c.Error(err).SetMeta("erro ao anexar arquivo")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"attachment": attachment})
+}
+
+// Baixa o arquivo anexado
+func DownloadAttachmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attachment, content, err := service.OpenAttachment(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao baixar anexo")
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Header("Content-Type", attachment.ContentType)
+	if attachment.Size > 0 {
+		c.Header("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	}
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, content)
+}
+
+// Remove o anexo e o arquivo armazenado
+func DeleteAttachmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteAttachment(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover anexo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "anexo removido"})
+}
+
+// Lista os comentários internos do documento em threads
+func ListCommentsHandler(c *gin.Context) {
+	entityType, entityID, ok := parseEntityParams(c)
+	if !ok {
+		return
+	}
+
+	comments, err := service.GetComments(c.Request.Context(), entityType, entityID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar comentários")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+// Adiciona um comentário interno ao documento (parent_id opcional para responder a outro comentário)
+func AddCommentHandler(c *gin.Context) {
+	entityType, entityID, ok := parseEntityParams(c)
+	if !ok {
+		return
+	}
+
+	var comment models.Comment
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.AddComment(c.Request.Context(), entityType, entityID, &comment); err != nil {
+		c.Error(err).SetMeta("erro ao adicionar comentário")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"comment": comment})
+}
+
+// Remove o comentário e as respostas
+func DeleteCommentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteComment(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover comentário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "comentário removido"})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+// parseEntityParams lê o tipo e o ID do documento da rota
+func parseEntityParams(c *gin.Context) (string, int, bool) {
+	entityID, ok := parseIDParam(c, "entity_id")
+	if !ok {
+		return "", 0, false
+	}
+	return c.Param("entity_type"), entityID, true
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// entityTables relaciona os tipos de documento que aceitam anexos e comentários às suas tabelas
+var entityTables = map[string]string{
+	"quotation":      "quotations",
+	"sales_order":    "sales_orders",
+	"purchase_order": "purchase_orders",
+	"delivery":       "deliveries",
+	"invoice":        "invoices",
+	"credit_note":    "credit_notes",
+	"supplier_bill":  "supplier_bills",
+	"return":         "return_requests",
+	"service_order":  "service_orders",
+	"contact":        "contacts",
+	"product":        "products",
+	"lead":           "crm_leads",
+	"opportunity":    "crm_opportunities",
+	// Notas de fornecedores recebidas por e-mail (ver purchasing/models.InboundDocument)
+	"inbound_document": "inbound_documents",
+	// Comprovantes das despesas dos colaboradores (ver expenses/models.Expense)
+	"expense": "expenses",
+	// Notas fiscais, fotos e termos de responsabilidade dos bens do imobilizado
+	"asset": "assets",
+}
+
+// Attachment é um arquivo anexado a um documento do ERP
+type Attachment struct {
+	ID             int       `json:"id" gorm:"primaryKey"`
+	CompanyID      int       `json:"company_id" gorm:"<-:create"`
+	EntityType     string    `json:"entity_type"`
+	EntityID       int       `json:"entity_id"`
+	FileName       string    `json:"file_name"`
+	ContentType    string    `json:"content_type"`
+	Size           int64     `json:"size"`
+	StorageBackend string    `json:"storage_backend"`
+	StorageKey     string    `json:"-"`
+	Description    string    `json:"description,omitempty"`
+	UploadedBy     string    `json:"uploaded_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Comment é um comentário interno em um documento; respostas apontam para o comentário de origem
+type Comment struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	CompanyID  int       `json:"company_id" gorm:"<-:create"`
+	EntityType string    `json:"entity_type"`
+	EntityID   int       `json:"entity_id"`
+	ParentID   *int      `json:"parent_id,omitempty"`
+	Author     string    `json:"author" binding:"required"`
+	Body       string    `json:"body" binding:"required"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	Replies    []Comment `json:"replies,omitempty" gorm:"-"`
+}
+
+// EntityTable retorna a tabela do tipo de documento ou erro se o tipo não aceitar anexos
+func EntityTable(entityType string) (string, error) {
+	table, ok := entityTables[entityType]
+	if !ok {
+		return "", errors.ErrUnsupportedEntityType
+	}
+	return table, nil
+}
+
+// SanitizeFileName remove diretórios e caracteres problemáticos do nome enviado pelo usuário
+func SanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 32, r == '/', r == ':', r == '*', r == '?', r == '"', r == '<', r == '>', r == '|':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+
+	if name == "" || name == "." || name == ".." {
+		return "arquivo"
+	}
+	return name
+}
+
+// BuildThread organiza os comentários em threads, com as respostas sob o comentário de origem
+// e em ordem cronológica
+func BuildThread(comments []Comment) []Comment {
+	sorted := make([]Comment, len(comments))
+	copy(sorted, comments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	children := make(map[int][]Comment)
+	known := make(map[int]bool, len(sorted))
+	for _, comment := range sorted {
+		known[comment.ID] = true
+	}
+
+	var roots []Comment
+	for _, comment := range sorted {
+		if comment.ParentID != nil && known[*comment.ParentID] {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+			continue
+		}
+		roots = append(roots, comment)
+	}
+
+	var attach func(comment Comment) Comment
+	attach = func(comment Comment) Comment {
+		for _, reply := range children[comment.ID] {
+			comment.Replies = append(comment.Replies, attach(reply))
+		}
+		return comment
+	}
+
+	thread := make([]Comment, 0, len(roots))
+	for _, root := range roots {
+		thread = append(thread, attach(root))
+	}
+	return thread
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntityTable(t *testing.T) {
+	table, err := EntityTable("sales_order")
+	require.NoError(t, err)
+	assert.Equal(t, "sales_orders", table)
+
+	table, err = EntityTable("delivery")
+	require.NoError(t, err)
+	assert.Equal(t, "deliveries", table)
+
+	_, err = EntityTable("users")
+	assert.Equal(t, errors.ErrUnsupportedEntityType, err)
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	assert.Equal(t, "contrato assinado.pdf", SanitizeFileName("contrato assinado.pdf"))
+	assert.Equal(t, "passwd", SanitizeFileName("../../etc/passwd"))
+	assert.Equal(t, "foto.jpg", SanitizeFileName(`C:\Users\joao\foto.jpg`))
+	assert.Equal(t, "a_b_.txt", SanitizeFileName("a*b?.txt"))
+	assert.Equal(t, "arquivo", SanitizeFileName(".."))
+	assert.Equal(t, "arquivo", SanitizeFileName("  "))
+}
+
+func TestBuildThread(t *testing.T) {
+	base := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	parent := 1
+	reply := 2
+	comments := []Comment{
+		{ID: 3, Body: "segundo assunto", CreatedAt: base.Add(2 * time.Minute)},
+		{ID: 2, ParentID: &parent, Body: "resposta", CreatedAt: base.Add(time.Minute)},
+		{ID: 1, Body: "primeiro assunto", CreatedAt: base},
+		{ID: 4, ParentID: &reply, Body: "resposta da resposta", CreatedAt: base.Add(3 * time.Minute)},
+		{ID: 5, ParentID: &parent, Body: "outra resposta", CreatedAt: base.Add(4 * time.Minute)},
+	}
+
+	thread := BuildThread(comments)
+	require.Len(t, thread, 2)
+	assert.Equal(t, 1, thread[0].ID)
+	assert.Equal(t, 3, thread[1].ID)
+
+	require.Len(t, thread[0].Replies, 2)
+	assert.Equal(t, 2, thread[0].Replies[0].ID)
+	assert.Equal(t, 5, thread[0].Replies[1].ID)
+	require.Len(t, thread[0].Replies[0].Replies, 1)
+	assert.Equal(t, 4, thread[0].Replies[0].Replies[0].ID)
+}
+
+func TestBuildThreadOrphanReply(t *testing.T) {
+	// Respostas cujo comentário de origem não foi carregado aparecem na raiz
+	missing := 99
+	thread := BuildThread([]Comment{{ID: 1, ParentID: &missing, Body: "resposta"}})
+	require.Len(t, thread, 1)
+	assert.Equal(t, 1, thread[0].ID)
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/attachments/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AttachmentRepository define as operações de anexos e comentários dos documentos
+type AttachmentRepository interface {
+	EntityExists(ctx context.Context, entityType string, entityID int) error
+	CreateAttachment(ctx context.Context, attachment *models.Attachment) error
+	GetAttachments(ctx context.Context, entityType string, entityID int) ([]models.Attachment, error)
+	GetAttachmentByID(ctx context.Context, id int) (*models.Attachment, error)
+	DeleteAttachment(ctx context.Context, id int) error
+	CreateComment(ctx context.Context, comment *models.Comment) error
+	GetComments(ctx context.Context, entityType string, entityID int) ([]models.Comment, error)
+	GetCommentByID(ctx context.Context, id int) (*models.Comment, error)
+	DeleteComment(ctx context.Context, id int) error
+}
+
+type attachmentRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewAttachmentRepository cria uma nova instância do repositório
+func NewAttachmentRepository() (AttachmentRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &attachmentRepository{
+		db:     db,
+		logger: logger.WithModule("attachment_repository"),
+	}, nil
+}
+
+// EntityExists verifica se o documento existe antes de receber anexos ou comentários
+func (r *attachmentRepository) EntityExists(ctx context.Context, entityType string, entityID int) error {
+	table, err := models.EntityTable(entityType)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := db.Conn(ctx, r.db).Table(table).Scopes(tenant.Scope(ctx, table)).Where("id = ?", entityID).Count(&count).Error; err != nil {
+		r.logger.Error("erro ao verificar documento", zap.Error(err), zap.String("entity_type", entityType), zap.Int("entity_id", entityID))
+		return errors.WrapError(err, "falha ao verificar documento")
+	}
+	if count == 0 {
+		return errors.ErrEntityNotFound
+	}
+	return nil
+}
+
+// CreateAttachment registra o anexo já gravado no armazenamento
+func (r *attachmentRepository) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	if err := db.Conn(ctx, r.db).Create(attachment).Error; err != nil {
+		r.logger.Error("erro ao registrar anexo", zap.Error(err), zap.String("key", attachment.StorageKey))
+		return errors.WrapError(err, "falha ao registrar anexo")
+	}
+
+	r.logger.Info("anexo registrado",
+		zap.Int("id", attachment.ID),
+		zap.String("entity_type", attachment.EntityType),
+		zap.Int("entity_id", attachment.EntityID))
+	return nil
+}
+
+// GetAttachments lista os anexos do documento, do mais recente para o mais antigo
+func (r *attachmentRepository) GetAttachments(ctx context.Context, entityType string, entityID int) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if err := db.Conn(ctx, r.db).Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at DESC, id DESC").
+		Find(&attachments).Error; err != nil {
+		r.logger.Error("erro ao listar anexos", zap.Error(err), zap.String("entity_type", entityType), zap.Int("entity_id", entityID))
+		return nil, errors.WrapError(err, "falha ao listar anexos")
+	}
+	return attachments, nil
+}
+
+// GetAttachmentByID busca um anexo pelo ID
+func (r *attachmentRepository) GetAttachmentByID(ctx context.Context, id int) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := db.Conn(ctx, r.db).First(&attachment, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrAttachmentNotFound
+		}
+		r.logger.Error("erro ao buscar anexo", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar anexo")
+	}
+	return &attachment, nil
+}
+
+// DeleteAttachment remove o registro do anexo
+func (r *attachmentRepository) DeleteAttachment(ctx context.Context, id int) error {
+	result := db.Conn(ctx, r.db).Delete(&models.Attachment{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover anexo", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover anexo")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// CreateComment grava um comentário; respostas precisam pertencer ao mesmo documento do comentário de origem
+func (r *attachmentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	if comment.ParentID != nil {
+		parent, err := r.GetCommentByID(ctx, *comment.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.EntityType != comment.EntityType || parent.EntityID != comment.EntityID {
+			return errors.ErrInvalidCommentParent
+		}
+	}
+
+	if err := db.Conn(ctx, r.db).Create(comment).Error; err != nil {
+		r.logger.Error("erro ao criar comentário", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar comentário")
+	}
+	return nil
+}
+
+// GetComments lista os comentários do documento em ordem cronológica
+func (r *attachmentRepository) GetComments(ctx context.Context, entityType string, entityID int) ([]models.Comment, error) {
+	var comments []models.Comment
+	if err := db.Conn(ctx, r.db).Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at ASC, id ASC").
+		Find(&comments).Error; err != nil {
+		r.logger.Error("erro ao listar comentários", zap.Error(err), zap.String("entity_type", entityType), zap.Int("entity_id", entityID))
+		return nil, errors.WrapError(err, "falha ao listar comentários")
+	}
+	return comments, nil
+}
+
+// GetCommentByID busca um comentário pelo ID
+func (r *attachmentRepository) GetCommentByID(ctx context.Context, id int) (*models.Comment, error) {
+	var comment models.Comment
+	if err := db.Conn(ctx, r.db).First(&comment, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCommentNotFound
+		}
+		r.logger.Error("erro ao buscar comentário", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar comentário")
+	}
+	return &comment, nil
+}
+
+// DeleteComment remove o comentário e, em cascata, as respostas
+func (r *attachmentRepository) DeleteComment(ctx context.Context, id int) error {
+	result := db.Conn(ctx, r.db).Delete(&models.Comment{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover comentário", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover comentário")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrCommentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/attachments/models"
+	"ERP-ONSMART/backend/internal/modules/attachments/repository"
+	"ERP-ONSMART/backend/internal/modules/attachments/storage"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Upload traz o arquivo enviado e os dados do anexo
+type Upload struct {
+	FileName    string
+	ContentType string
+	Size        int64
+	Content     io.Reader
+	Description string
+	UploadedBy  string
+}
+
+// MaxUploadSize retorna o tamanho máximo dos anexos em bytes (ATTACHMENTS_MAX_SIZE_MB)
+func MaxUploadSize() int64 {
+	return viper.GetInt64("ATTACHMENTS_MAX_SIZE_MB") << 20
+}
+
+// UploadAttachment grava o arquivo no armazenamento configurado e registra o anexo no documento
+func UploadAttachment(ctx context.Context, entityType string, entityID int, upload Upload) (*models.Attachment, error) {
+	if max := MaxUploadSize(); max > 0 && upload.Size > max {
+		return nil, errors.ErrAttachmentTooLarge
+	}
+
+	repo, err := repository.NewAttachmentRepository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.EntityExists(ctx, entityType, entityID); err != nil {
+		return nil, err
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := models.SanitizeFileName(upload.FileName)
+	key, err := storage.NewKey(entityType, entityID, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Put(ctx, key, upload.Content, upload.Size, upload.ContentType); err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		FileName:       fileName,
+		ContentType:    upload.ContentType,
+		Size:           upload.Size,
+		StorageBackend: store.Name(),
+		StorageKey:     key,
+		Description:    strings.TrimSpace(upload.Description),
+		UploadedBy:     strings.TrimSpace(upload.UploadedBy),
+	}
+	if err := repo.CreateAttachment(ctx, attachment); err != nil {
+		// Sem o registro, o arquivo gravado ficaria órfão no armazenamento
+		if delErr := store.Delete(ctx, key); delErr != nil {
+			logger.WithModule("attachment_service").Warn("erro ao remover arquivo órfão",
+				zap.Error(delErr), zap.String("key", key))
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// GetAttachments lista os anexos do documento
+func GetAttachments(ctx context.Context, entityType string, entityID int) ([]models.Attachment, error) {
+	if _, err := models.EntityTable(entityType); err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewAttachmentRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAttachments(ctx, entityType, entityID)
+}
+
+// OpenAttachment retorna o anexo e o conteúdo do arquivo para download
+func OpenAttachment(ctx context.Context, id int) (*models.Attachment, io.ReadCloser, error) {
+	repo, err := repository.NewAttachmentRepository()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := repo.GetAttachmentByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := storage.Get(attachment.StorageBackend)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment remove o anexo e o arquivo do armazenamento
+func DeleteAttachment(ctx context.Context, id int) error {
+	repo, err := repository.NewAttachmentRepository()
+	if err != nil {
+		return err
+	}
+
+	attachment, err := repo.GetAttachmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := repo.DeleteAttachment(ctx, id); err != nil {
+		return err
+	}
+
+	// O registro já foi removido; falhas no armazenamento deixam apenas um arquivo órfão
+	store, err := storage.Get(attachment.StorageBackend)
+	if err == nil {
+		err = store.Delete(ctx, attachment.StorageKey)
+	}
+	if err != nil {
+		logger.WithModule("attachment_service").Warn("erro ao remover arquivo do anexo",
+			zap.Error(err), zap.Int("id", id), zap.String("key", attachment.StorageKey))
+	}
+	return nil
+}
+
+// AddComment grava um comentário ou resposta no documento
+func AddComment(ctx context.Context, entityType string, entityID int, comment *models.Comment) error {
+	repo, err := repository.NewAttachmentRepository()
+	if err != nil {
+		return err
+	}
+	if err := repo.EntityExists(ctx, entityType, entityID); err != nil {
+		return err
+	}
+
+	comment.ID = 0
+	comment.EntityType = entityType
+	comment.EntityID = entityID
+	comment.Author = strings.TrimSpace(comment.Author)
+	comment.Replies = nil
+	return repo.CreateComment(ctx, comment)
+}
+
+// GetComments retorna os comentários do documento organizados em threads
+func GetComments(ctx context.Context, entityType string, entityID int) ([]models.Comment, error) {
+	if _, err := models.EntityTable(entityType); err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewAttachmentRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := repo.GetComments(ctx, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildThread(comments), nil
+}
+
+// DeleteComment remove o comentário e as respostas
+func DeleteComment(ctx context.Context, id int) error {
+	repo, err := repository.NewAttachmentRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteComment(ctx, id)
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultLocalDir é o diretório usado quando ATTACHMENTS_DIR não está definido
+const defaultLocalDir = "uploads"
+
+// Local grava os anexos em um diretório do servidor
+type Local struct {
+	dir string
+}
+
+// NewLocal cria o armazenamento em disco no diretório informado
+func NewLocal(dir string) *Local {
+	if strings.TrimSpace(dir) == "" {
+		dir = defaultLocalDir
+	}
+	return &Local{dir: dir}
+}
+
+// Name identifica o armazenamento
+func (l *Local) Name() string {
+	return BackendLocal
+}
+
+// Put grava o arquivo, criando os diretórios do documento quando necessário
+func (l *Local) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.WrapError(err, "falha ao criar diretório do anexo")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return errors.WrapError(err, "falha ao criar arquivo do anexo")
+	}
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		os.Remove(path)
+		return errors.WrapError(err, "falha ao gravar arquivo do anexo")
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return errors.WrapError(err, "falha ao gravar arquivo do anexo")
+	}
+	return nil
+}
+
+// Get abre o arquivo para leitura
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrAttachmentNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao abrir arquivo do anexo")
+	}
+	return file, nil
+}
+
+// Delete remove o arquivo; arquivos já inexistentes são ignorados
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.WrapError(err, "falha ao remover arquivo do anexo")
+	}
+	return nil
+}
+
+// path resolve a chave dentro do diretório, impedindo caminhos fora dele
+func (l *Local) path(key string) (string, error) {
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	rel, err := filepath.Rel(l.dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", errors.ErrAttachmentNotFound
+	}
+	return path, nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Nomes dos armazenamentos de arquivos suportados
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// defaultTimeout limita o tempo das chamadas ao armazenamento remoto
+const defaultTimeout = 60 * time.Second
+
+// Storage grava, lê e remove os arquivos anexados
+type Storage interface {
+	Name() string
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Default retorna o armazenamento configurado para novos anexos (ATTACHMENTS_STORAGE)
+func Default() (Storage, error) {
+	return Get(viper.GetString("ATTACHMENTS_STORAGE"))
+}
+
+// Get retorna o armazenamento pelo nome, configurado nas variáveis de ambiente
+func Get(name string) (Storage, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case BackendLocal, "":
+		return NewLocal(viper.GetString("ATTACHMENTS_DIR")), nil
+	case BackendS3:
+		bucket := viper.GetString("S3_BUCKET")
+		if bucket == "" {
+			return nil, errors.ErrUnknownStorageBackend
+		}
+		return NewS3(S3Config{
+			Endpoint:        viper.GetString("S3_ENDPOINT"),
+			Region:          viper.GetString("S3_REGION"),
+			Bucket:          bucket,
+			AccessKeyID:     viper.GetString("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: viper.GetString("S3_SECRET_ACCESS_KEY"),
+		}, &http.Client{Timeout: defaultTimeout}), nil
+	default:
+		return nil, errors.ErrUnknownStorageBackend
+	}
+}
+
+// NewKey gera a chave única do arquivo agrupada pelo documento (ex.: sales_order/42/9f3c...-contrato.pdf)
+func NewKey(entityType string, entityID int, fileName string) (string, error) {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.WrapError(err, "falha ao gerar identificador do arquivo")
+	}
+	return fmt.Sprintf("%s/%d/%s-%s", entityType, entityID, hex.EncodeToString(random), fileName), nil
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUnknownBackend(t *testing.T) {
+	_, err := Get("ftp")
+	assert.Equal(t, errors.ErrUnknownStorageBackend, err)
+}
+
+func TestNewKey(t *testing.T) {
+	key, err := NewKey("sales_order", 42, "contrato.pdf")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "sales_order/42/"))
+	assert.True(t, strings.HasSuffix(key, "-contrato.pdf"))
+
+	other, err := NewKey("sales_order", 42, "contrato.pdf")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocal(t.TempDir())
+
+	require.NoError(t, store.Put(ctx, "delivery/7/abc-foto.jpg", strings.NewReader("conteúdo"), 9, "image/jpeg"))
+
+	content, err := store.Get(ctx, "delivery/7/abc-foto.jpg")
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	content.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "conteúdo", string(data))
+
+	require.NoError(t, store.Delete(ctx, "delivery/7/abc-foto.jpg"))
+	_, err = store.Get(ctx, "delivery/7/abc-foto.jpg")
+	assert.Equal(t, errors.ErrAttachmentNotFound, err)
+
+	// Remover um arquivo inexistente não é erro
+	assert.NoError(t, store.Delete(ctx, "delivery/7/abc-foto.jpg"))
+}
+
+func TestLocalRejectsPathOutsideDir(t *testing.T) {
+	store := NewLocal(t.TempDir())
+	_, err := store.Get(context.Background(), "../../etc/passwd")
+	assert.Equal(t, errors.ErrAttachmentNotFound, err)
+}
+
+func TestS3PutAndGet(t *testing.T) {
+	var stored string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/erp-anexos/sales_order/1/abc-contrato%20assinado.pdf", r.URL.EscapedPath())
+		assert.Equal(t, "UNSIGNED-PAYLOAD", r.Header.Get("X-Amz-Content-Sha256"))
+		assert.Equal(t, "20250501T120000Z", r.Header.Get("X-Amz-Date"))
+
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth,
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250501/sa-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="))
+
+		switch r.Method {
+		case http.MethodPut:
+			assert.Equal(t, "application/pdf", r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			stored = string(body)
+		case http.MethodGet:
+			w.Write([]byte(stored))
+		}
+	}))
+	defer server.Close()
+
+	store := NewS3(S3Config{
+		Endpoint:        server.URL,
+		Region:          "sa-east-1",
+		Bucket:          "erp-anexos",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "segredo",
+	}, server.Client())
+	store.now = func() time.Time { return time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "sales_order/1/abc-contrato assinado.pdf", strings.NewReader("%PDF"), 4, "application/pdf"))
+	assert.Equal(t, "%PDF", stored)
+
+	content, err := store.Get(ctx, "sales_order/1/abc-contrato assinado.pdf")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(content)
+	content.Close()
+	assert.Equal(t, "%PDF", string(data))
+}
+
+func TestS3Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("<Error><Code>AccessDenied</Code></Error>"))
+	}))
+	defer server.Close()
+
+	store := NewS3(S3Config{Endpoint: server.URL, Bucket: "erp-anexos"}, server.Client())
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, "invoice/1/x.pdf")
+	assert.Equal(t, errors.ErrAttachmentNotFound, err)
+
+	err = store.Put(ctx, "invoice/1/x.pdf", strings.NewReader("x"), 1, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
+
+func TestS3SignatureIsDeterministic(t *testing.T) {
+	store := NewS3(S3Config{Bucket: "b", Region: "us-east-1", AccessKeyID: "id", SecretAccessKey: "secret"}, http.DefaultClient)
+	store.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	sign := func() string {
+		req, err := store.newRequest(context.Background(), http.MethodGet, "a/b.txt", nil)
+		require.NoError(t, err)
+		store.sign(req)
+		return req.Header.Get("Authorization")
+	}
+
+	first := sign()
+	assert.Equal(t, first, sign())
+	assert.Contains(t, first, "Credential=id/20250102/us-east-1/s3/aws4_request")
+	assert.Equal(t, "https://s3.us-east-1.amazonaws.com", store.config.Endpoint)
+}
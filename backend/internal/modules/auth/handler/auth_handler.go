@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/auth/models"
 	"ERP-ONSMART/backend/internal/modules/auth/service"
 	"fmt"
@@ -12,55 +13,63 @@ import (
 	"github.com/spf13/viper"
 )
 
+// Autentica o usuário e retorna o token JWT (válido por 2 horas)
+// @Success 200 "Token gerado"
 func LoginHandler(c *gin.Context) {
 	var creds models.LoginRequest
 	if err := c.ShouldBindJSON(&creds); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 
 	user, err := service.Authenticate(creds.Username, creds.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	jwtSecret := viper.GetString("JWT_SECRET")
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"username": user.Username,
-		"exp":      time.Now().Add(2 * time.Hour).Unix(),
+		"username":   user.Username,
+		"role":       user.Cargo,
+		"company_id": user.CompanyID,
+		"exp":        time.Now().Add(2 * time.Hour).Unix(),
 	})
 	tokenStr, err := token.SignedString([]byte(jwtSecret))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao gerar token"})
+		c.Error(err).SetMeta("erro ao gerar token")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Login realizado com sucesso", "token": tokenStr})
 }
 
+// Registra um novo usuário (cargo padrão: Colaborador)
+// @Success 200 "Usuário registrado"
 func RegisterHandler(c *gin.Context) {
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 	if err := service.Register(user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err).SetMeta("erro ao registrar usuário")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Usuário registrado com sucesso"})
 }
 
+// Retorna o perfil do usuário autenticado
+// @Security BearerAuth
 func ProfileHandler(c *gin.Context) {
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token não fornecido"})
+		c.Error(errors.ErrMissingToken)
 		return
 	}
 	var tokenString string
 	_, err := fmt.Sscanf(authHeader, "Bearer %s", &tokenString)
 	if err != nil || tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token malformado"})
+		c.Error(errors.ErrInvalidToken)
 		return
 	}
 	jwtSecret := viper.GetString("JWT_SECRET")
@@ -68,17 +77,17 @@ func ProfileHandler(c *gin.Context) {
 		return []byte(jwtSecret), nil
 	})
 	if err != nil || !token.Valid {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
+		c.Error(errors.ErrInvalidToken)
 		return
 	}
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok || claims["username"] == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Claims inválidas"})
+		c.Error(errors.ErrInvalidToken)
 		return
 	}
 	user, err := service.GetUserProfile(claims["username"].(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar perfil"})
+		c.Error(err).SetMeta("Erro ao buscar perfil")
 		return
 	}
 
@@ -89,14 +98,12 @@ func ProfileHandler(c *gin.Context) {
 	})
 }
 
+// Remove um usuário pelo username
 func DeleteUserHandler(c *gin.Context) {
 	username := c.Param("username")
 
 	if err := service.DeleteUser(username); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Erro ao deletar usuário",
-			"details": err.Error(),
-		})
+		c.Error(err).SetMeta("Erro ao deletar usuário")
 		return
 	}
 
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
 	"encoding/json"
 	"net/http"
@@ -36,6 +37,7 @@ func TestMain(m *testing.M) {
 func TestRegisterAndLoginHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 	r.POST("/auth/register", RegisterHandler)
 	r.POST("/auth/login", LoginHandler)
 
@@ -73,6 +75,7 @@ func TestRegisterAndLoginHandler(t *testing.T) {
 func TestProfileHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 	r.POST("/auth/register", RegisterHandler)
 	r.POST("/auth/login", LoginHandler)
 	r.GET("/auth/profile", ProfileHandler)
@@ -110,6 +113,7 @@ func TestProfileHandler(t *testing.T) {
 func TestDeleteUserHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 	r.POST("/auth/register", RegisterHandler)
 	r.DELETE("/auth/:username", DeleteUserHandler)
 
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Usado apenas para login
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -7,10 +9,15 @@ type LoginRequest struct {
 }
 
 type User struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Nome     string `json:"nome" binding:"required"`
-	Telefone string `json:"telefone"` // opcional
-	Cargo    string `json:"cargo"`    // default controlado no backend/admin
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	Nome      string `json:"nome" binding:"required"`
+	Telefone  string `json:"telefone"`   // opcional
+	Cargo     string `json:"cargo"`      // default controlado no backend/admin
+	CompanyID int    `json:"company_id"` // empresa do usuário; 0 usa a empresa padrão
+
+	// Situação da conta, lida no login (ver modules/users)
+	Active      bool       `json:"-"`
+	LockedUntil *time.Time `json:"-"`
 }
@@ -3,7 +3,9 @@ package repository
 import (
 	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/modules/auth/models"
+	"database/sql"
 	"fmt"
+	"time"
 )
 
 // FindUserByUsername busca um usuário pelo username e retorna senha também.
@@ -16,15 +18,53 @@ func FindUserByUsername(username string) (models.User, error) {
 
 	var user models.User
 	err = conn.QueryRow(`
-		SELECT username, password, email, nome, telefone, cargo 
+		SELECT username, password, email, nome, telefone, cargo, company_id, active, locked_until
 		FROM users WHERE username = $1`, username).
-		Scan(&user.Username, &user.Password, &user.Email, &user.Nome, &user.Telefone, &user.Cargo)
+		Scan(&user.Username, &user.Password, &user.Email, &user.Nome, &user.Telefone, &user.Cargo, &user.CompanyID,
+			&user.Active, &user.LockedUntil)
 	if err != nil {
 		return models.User{}, err
 	}
 	return user, nil
 }
 
+// RegisterFailedLogin soma uma tentativa de login sem sucesso. Ao atingir maxAttempts a conta
+// fica bloqueada até lockUntil e a contagem recomeça; retorna o bloqueio vigente, se houver.
+func RegisterFailedLogin(username string, maxAttempts int, lockUntil time.Time) (*time.Time, error) {
+	conn, err := db.OpenDB()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var lockedUntil *time.Time
+	err = conn.QueryRow(`
+		UPDATE users SET
+			failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2 THEN 0 ELSE failed_login_attempts + 1 END,
+			locked_until = CASE WHEN failed_login_attempts + 1 >= $2 THEN $3 ELSE locked_until END
+		WHERE username = $1
+		RETURNING locked_until`, username, maxAttempts, lockUntil).
+		Scan(&lockedUntil)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return lockedUntil, err
+}
+
+// RegisterSuccessfulLogin zera as tentativas sem sucesso e registra o último acesso
+func RegisterSuccessfulLogin(username string) error {
+	conn, err := db.OpenDB()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec(`
+		UPDATE users SET failed_login_attempts = 0, locked_until = NULL, last_login_at = NOW()
+		WHERE username = $1`, username)
+	return err
+}
+
 // InsertUser insere um novo usuário no banco.
 func InsertUser(user models.User) error {
 	conn, err := db.OpenDB()
@@ -34,9 +74,9 @@ func InsertUser(user models.User) error {
 	defer conn.Close()
 
 	_, err = conn.Exec(`
-		INSERT INTO users (username, password, email, nome, telefone, cargo)
-		VALUES ($1, $2, $3, $4, $5, $6)`,
-		user.Username, user.Password, user.Email, user.Nome, user.Telefone, user.Cargo)
+		INSERT INTO users (username, password, email, nome, telefone, cargo, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, 0), 1))`,
+		user.Username, user.Password, user.Email, user.Nome, user.Telefone, user.Cargo, user.CompanyID)
 	return err
 }
 
@@ -50,9 +90,9 @@ func GetProfile(username string) (models.User, error) {
 
 	var user models.User
 	err = conn.QueryRow(`
-		SELECT username, email, nome, telefone, cargo 
+		SELECT username, email, nome, telefone, cargo, company_id
 		FROM users WHERE username = $1`, username).
-		Scan(&user.Username, &user.Email, &user.Nome, &user.Telefone, &user.Cargo)
+		Scan(&user.Username, &user.Email, &user.Nome, &user.Telefone, &user.Cargo, &user.CompanyID)
 	return user, err
 }
 
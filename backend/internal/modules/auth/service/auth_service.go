@@ -1,27 +1,70 @@
 package service
 
 import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
 	"ERP-ONSMART/backend/internal/modules/auth/models"
 	"ERP-ONSMART/backend/internal/modules/auth/repository"
-	"errors"
+	"time"
 
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// Authenticate verifica as credenciais do usuário.
+const defaultLockoutDuration = 15 * time.Minute
+
+// Authenticate verifica as credenciais do usuário. Após USER_MAX_FAILED_LOGINS tentativas
+// erradas seguidas a conta fica bloqueada por USER_LOCKOUT_DURATION; contas desativadas
+// não fazem login.
 func Authenticate(username, password string) (models.User, error) {
 	user, err := repository.FindUserByUsername(username)
 	if err != nil {
-		return models.User{}, errors.New("usuário ou senha inválidos")
+		return models.User{}, errors.ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return models.User{}, errors.ErrUserLocked
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return models.User{}, errors.New("usuário ou senha inválidos")
+		if registerFailedLogin(username, now) {
+			return models.User{}, errors.ErrUserLocked
+		}
+		return models.User{}, errors.ErrInvalidCredentials
+	}
+
+	if !user.Active {
+		return models.User{}, errors.ErrUserInactive
+	}
+
+	if err := repository.RegisterSuccessfulLogin(username); err != nil {
+		logger.WithModule("auth_service").Warn("erro ao registrar login", zap.Error(err), zap.String("username", username))
 	}
 	return user, nil
 }
 
+// registerFailedLogin conta a tentativa errada e indica se ela bloqueou a conta
+func registerFailedLogin(username string, now time.Time) bool {
+	maxAttempts := viper.GetInt("USER_MAX_FAILED_LOGINS")
+	if maxAttempts <= 0 {
+		return false
+	}
+	lockout := viper.GetDuration("USER_LOCKOUT_DURATION")
+	if lockout <= 0 {
+		lockout = defaultLockoutDuration
+	}
+
+	lockedUntil, err := repository.RegisterFailedLogin(username, maxAttempts, now.Add(lockout))
+	if err != nil {
+		logger.WithModule("auth_service").Warn("erro ao registrar tentativa de login", zap.Error(err), zap.String("username", username))
+		return false
+	}
+	return lockedUntil != nil && lockedUntil.After(now)
+}
+
 // Register cria um novo usuário com senha criptografada.
 func Register(user models.User) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
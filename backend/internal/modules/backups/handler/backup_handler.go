@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/backups/models"
+	"ERP-ONSMART/backend/internal/modules/backups/service"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Coloca na fila um backup lógico do banco (pg_dump de todas as empresas); acompanhe o job em
+// GET /admin/backups/:id
+// @Security BearerAuth
+func RequestBackupHandler(c *gin.Context) {
+	job, err := service.RequestBackup(c.Request.Context(), middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao pedir backup")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"backup": job})
+}
+
+// Lista os backups, dos mais recentes, com tamanho, checksum (SHA-256) e se o arquivo ainda
+// está disponível para restauração
+// @Security BearerAuth
+func ListBackupsHandler(c *gin.Context) {
+	backups, err := service.ListBackups(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar backups")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backups": backups})
+}
+
+// Lista as restaurações, das mais recentes
+// @Security BearerAuth
+func ListRestoresHandler(c *gin.Context) {
+	restores, err := service.ListRestores(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar restaurações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"restores": restores})
+}
+
+// Busca um backup ou uma restauração, com o status do job
+// @Security BearerAuth
+// @Param id path int true "ID do backup ou da restauração"
+func GetBackupHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := service.GetBackupJob(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar backup")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backup": job})
+}
+
+// Coloca na fila a restauração do backup (pg_restore), que substitui os dados de todas as
+// empresas; confirm deve trazer o nome do arquivo do backup. O checksum é conferido antes.
+// @Security BearerAuth
+// @Param id path int true "ID do backup"
+func RestoreBackupHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.RestoreInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	job, err := service.RequestRestore(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao pedir restauração")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"restore": job})
+}
+
+// Coloca na fila a exportação completa dos dados da empresa (format json ou sql), para o
+// encerramento da conta; acompanhe o andamento em GET /admin/exports/:id
+// @Security BearerAuth
+func RequestExportHandler(c *gin.Context) {
+	var input models.ExportInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	export, err := service.RequestExport(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao pedir exportação")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"export": export})
+}
+
+// Lista as exportações da empresa com o andamento
+// @Security BearerAuth
+func ListExportsHandler(c *gin.Context) {
+	exports, err := service.ListExports(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar exportações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exports": exports})
+}
+
+// Busca a exportação com o andamento (tabelas exportadas, linhas e percentual)
+// @Security BearerAuth
+// @Param id path int true "ID da exportação"
+func GetExportHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	export, err := service.GetExport(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar exportação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"export": export})
+}
+
+// Baixa o arquivo da exportação concluída, em streaming
+// @Security BearerAuth
+// @Param id path int true "ID da exportação"
+func DownloadExportHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	export, file, err := service.OpenExport(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao baixar exportação")
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.FileName}))
+	c.Header("Content-Type", export.ContentType())
+	c.Header("Content-Length", strconv.FormatInt(export.SizeBytes, 10))
+	c.Header("X-Checksum-SHA256", export.Checksum)
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, file)
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
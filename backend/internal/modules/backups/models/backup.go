@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tipos dos jobs de backup: o backup gera o arquivo do pg_dump; a restauração aplica um backup
+// concluído com o pg_restore
+const (
+	KindBackup  = "backup"
+	KindRestore = "restore"
+)
+
+// Origens dos backups
+const (
+	TriggerManual    = "manual"
+	TriggerScheduled = "scheduled"
+)
+
+// Status dos jobs de backup e das exportações
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Formatos da exportação dos dados da empresa
+const (
+	FormatJSON = "json"
+	FormatSQL  = "sql"
+)
+
+// BackupJob é um backup lógico do banco (todas as empresas) ou a restauração de um backup,
+// executado em segundo plano pela fila. ExpiredAt marca os backups agendados removidos pela
+// retenção: o registro fica, mas o arquivo não está mais disponível.
+type BackupJob struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	Kind        string     `json:"kind"`
+	Trigger     string     `json:"trigger"`
+	Status      string     `json:"status"`
+	BackupID    *int       `json:"backup_id,omitempty"`
+	FileName    string     `json:"file_name,omitempty"`
+	SizeBytes   int64      `json:"size_bytes"`
+	Checksum    string     `json:"checksum,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	RequestedBy string     `json:"requested_by,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ExpiredAt   *time.Time `json:"expired_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	Available   bool       `json:"available" gorm:"-"`
+}
+
+func (BackupJob) TableName() string {
+	return "backup_jobs"
+}
+
+// IsAvailable indica se o arquivo do backup pode ser restaurado
+func (j BackupJob) IsAvailable() bool {
+	return j.Kind == KindBackup && j.Status == StatusCompleted && j.ExpiredAt == nil && j.FileName != ""
+}
+
+// BackupFileName é o nome do arquivo do backup, pela data de início e pelo ID do job
+func BackupFileName(id int, startedAt time.Time) string {
+	return fmt.Sprintf("backup-%s-%d.dump", startedAt.UTC().Format("20060102-150405"), id)
+}
+
+// RestoreInput confirma a restauração com o nome do arquivo do backup, que substitui os dados
+// de todas as empresas
+type RestoreInput struct {
+	Confirm string `json:"confirm" binding:"required"`
+}
+
+// TenantExport é a exportação completa dos dados de uma empresa, para o encerramento da conta,
+// com o andamento por tabela
+type TenantExport struct {
+	ID           int        `json:"id" gorm:"primaryKey"`
+	CompanyID    int        `json:"company_id" gorm:"<-:create"`
+	Format       string     `json:"format"`
+	Status       string     `json:"status"`
+	TablesTotal  int        `json:"tables_total"`
+	TablesDone   int        `json:"tables_done"`
+	RowsExported int64      `json:"rows_exported"`
+	Progress     int        `json:"progress" gorm:"-"`
+	FileName     string     `json:"file_name,omitempty"`
+	SizeBytes    int64      `json:"size_bytes"`
+	Checksum     string     `json:"checksum,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	RequestedBy  string     `json:"requested_by,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (TenantExport) TableName() string {
+	return "tenant_exports"
+}
+
+// ExportInput pede a exportação dos dados da empresa
+type ExportInput struct {
+	Format string `json:"format"`
+}
+
+// IsValidFormat indica se o formato de exportação é suportado
+func IsValidFormat(format string) bool {
+	return format == FormatJSON || format == FormatSQL
+}
+
+// ComputeProgress preenche o percentual concluído pelas tabelas exportadas
+func (e *TenantExport) ComputeProgress() {
+	switch {
+	case e.Status == StatusCompleted:
+		e.Progress = 100
+	case e.TablesTotal > 0:
+		e.Progress = e.TablesDone * 100 / e.TablesTotal
+	default:
+		e.Progress = 0
+	}
+}
+
+// ExportFileName é o nome do arquivo da exportação
+func ExportFileName(companyID, id int, format string) string {
+	return fmt.Sprintf("export-company-%d-%d.%s", companyID, id, format)
+}
+
+// ContentType é o tipo do arquivo da exportação
+func (e TenantExport) ContentType() string {
+	if e.Format == FormatSQL {
+		return "application/sql"
+	}
+	return "application/json"
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// excludedTables não entram na exportação da empresa: controle das migrações, filas e chaves
+// técnicas que não são dados do negócio
+var excludedTables = map[string]bool{
+	"schema_migrations":  true,
+	"backup_jobs":        true,
+	"tenant_exports":     true,
+	"idempotency_keys":   true,
+	"webhook_deliveries": true,
+}
+
+// sensitiveColumns são as colunas com senhas, segredos e hashes de tokens, omitidas na exportação
+var sensitiveColumns = map[string]bool{
+	"password":           true,
+	"password_sealed":    true,
+	"api_secret":         true,
+	"private_key":        true,
+	"private_key_sealed": true,
+	"code_hash":          true,
+	"key_hash":           true,
+	"token_hash":         true,
+}
+
+// SchemaColumn é uma coluna de uma tabela do banco, na ordem da tabela
+type SchemaColumn struct {
+	Table  string
+	Column string
+}
+
+// ForeignKey é uma chave estrangeira de uma coluna
+type ForeignKey struct {
+	Table     string
+	Column    string
+	RefTable  string
+	RefColumn string
+}
+
+// ExportTable é uma tabela da exportação: as colunas exportadas e a condição SQL que seleciona
+// as linhas da empresa (parâmetro nomeado @company)
+type ExportTable struct {
+	Name    string
+	Columns []string
+	Filter  string
+}
+
+// PlanExport define as tabelas da exportação da empresa: a própria empresa, as tabelas com
+// company_id e, seguindo as chaves estrangeiras, os itens, linhas e eventos que pertencem à
+// empresa pelo documento pai. As tabelas vêm com os pais antes dos filhos; as colunas
+// sensíveis são omitidas.
+func PlanExport(columns []SchemaColumn, foreignKeys []ForeignKey) []ExportTable {
+	tableColumns := make(map[string][]string)
+	var names []string
+	for _, column := range columns {
+		if excludedTables[column.Table] {
+			continue
+		}
+		if _, ok := tableColumns[column.Table]; !ok {
+			names = append(names, column.Table)
+		}
+		tableColumns[column.Table] = append(tableColumns[column.Table], column.Column)
+	}
+	sort.Strings(names)
+
+	filters := make(map[string]string)
+	var plan []ExportTable
+	add := func(name, filter string) {
+		filters[name] = filter
+		var exported []string
+		for _, column := range tableColumns[name] {
+			if !sensitiveColumns[column] {
+				exported = append(exported, column)
+			}
+		}
+		plan = append(plan, ExportTable{Name: name, Columns: exported, Filter: filter})
+	}
+
+	if _, ok := tableColumns["companies"]; ok {
+		add("companies", "id = @company")
+	}
+	for _, name := range names {
+		if name != "companies" && hasColumn(tableColumns[name], "company_id") {
+			add(name, "company_id = @company")
+		}
+	}
+
+	references := append([]ForeignKey(nil), foreignKeys...)
+	sort.Slice(references, func(i, j int) bool {
+		if references[i].Table != references[j].Table {
+			return references[i].Table < references[j].Table
+		}
+		return references[i].Column < references[j].Column
+	})
+	for added := true; added; {
+		added = false
+		for _, fk := range references {
+			parent, ok := filters[fk.RefTable]
+			if _, done := filters[fk.Table]; done || !ok {
+				continue
+			}
+			if _, known := tableColumns[fk.Table]; !known {
+				continue
+			}
+			add(fk.Table, fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s)",
+				pq.QuoteIdentifier(fk.Column), pq.QuoteIdentifier(fk.RefColumn), pq.QuoteIdentifier(fk.RefTable), parent))
+			added = true
+		}
+	}
+	return plan
+}
+
+func hasColumn(columns []string, name string) bool {
+	for _, column := range columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportWriter grava a exportação da empresa no formato pedido: em JSON, um objeto com as
+// linhas de cada tabela; em SQL, os INSERTs das linhas numa transação
+type ExportWriter struct {
+	w      io.Writer
+	format string
+	table  ExportTable
+	tables int
+	rows   int
+	err    error
+}
+
+// NewExportWriter cria o gravador da exportação sobre w
+func NewExportWriter(w io.Writer, format string) *ExportWriter {
+	return &ExportWriter{w: w, format: format}
+}
+
+func (e *ExportWriter) write(parts ...string) {
+	for _, part := range parts {
+		if e.err != nil {
+			return
+		}
+		_, e.err = io.WriteString(e.w, part)
+	}
+}
+
+// Begin grava o cabeçalho da exportação
+func (e *ExportWriter) Begin(companyID int, exportedAt time.Time) error {
+	stamp := exportedAt.UTC().Format(time.RFC3339)
+	if e.format == FormatSQL {
+		e.write(fmt.Sprintf("-- Exportação dos dados da empresa %d em %s\n", companyID, stamp), "BEGIN;\n")
+	} else {
+		e.write(fmt.Sprintf(`{"company_id":%d,"exported_at":%q,"tables":{`, companyID, stamp))
+	}
+	return e.err
+}
+
+// BeginTable inicia as linhas de uma tabela
+func (e *ExportWriter) BeginTable(table ExportTable) error {
+	e.table, e.rows = table, 0
+	if e.format == FormatSQL {
+		e.write("\n-- ", table.Name, "\n")
+	} else {
+		if e.tables > 0 {
+			e.write(",")
+		}
+		name, _ := json.Marshal(table.Name)
+		e.write(string(name), ":[")
+	}
+	e.tables++
+	return e.err
+}
+
+// Row grava uma linha da tabela, recebida como objeto JSON (row_to_json)
+func (e *ExportWriter) Row(row []byte) error {
+	if e.format == FormatSQL {
+		columns := make([]string, len(e.table.Columns))
+		for i, column := range e.table.Columns {
+			columns[i] = pq.QuoteIdentifier(column)
+		}
+		list := strings.Join(columns, ", ")
+		table := pq.QuoteIdentifier(e.table.Name)
+		e.write(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM json_populate_record(NULL::%s, %s);\n",
+			table, list, list, table, pq.QuoteLiteral(string(row))))
+	} else {
+		if e.rows > 0 {
+			e.write(",")
+		}
+		e.write(string(row))
+	}
+	e.rows++
+	return e.err
+}
+
+// EndTable encerra as linhas da tabela
+func (e *ExportWriter) EndTable() error {
+	if e.format != FormatSQL {
+		e.write("]")
+	}
+	return e.err
+}
+
+// End encerra a exportação
+func (e *ExportWriter) End() error {
+	if e.format == FormatSQL {
+		e.write("\nCOMMIT;\n")
+	} else {
+		e.write("}}\n")
+	}
+	return e.err
+}
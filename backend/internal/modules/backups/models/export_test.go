@@ -0,0 +1,91 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanExportFollowsCompanyAndParents(t *testing.T) {
+	columns := []SchemaColumn{
+		{"companies", "id"}, {"companies", "name"},
+		{"users", "id"}, {"users", "company_id"}, {"users", "username"}, {"users", "password"},
+		{"invoices", "id"}, {"invoices", "company_id"},
+		{"invoice_items", "id"}, {"invoice_items", "invoice_id"},
+		{"invoice_item_taxes", "id"}, {"invoice_item_taxes", "item_id"},
+		{"feature_flags", "name"},
+		{"backup_jobs", "id"},
+	}
+	foreignKeys := []ForeignKey{
+		{"invoice_item_taxes", "item_id", "invoice_items", "id"},
+		{"invoice_items", "invoice_id", "invoices", "id"},
+		{"users", "company_id", "companies", "id"},
+	}
+
+	plan := PlanExport(columns, foreignKeys)
+	names := make([]string, len(plan))
+	for i, table := range plan {
+		names[i] = table.Name
+	}
+	assert.Equal(t, []string{"companies", "invoices", "users", "invoice_items", "invoice_item_taxes"}, names,
+		"a empresa, as tabelas com company_id e os filhos depois dos pais; as globais e a fila ficam de fora")
+
+	assert.Equal(t, "id = @company", plan[0].Filter)
+	assert.Equal(t, []string{"id", "company_id", "username"}, plan[2].Columns, "a senha não é exportada")
+	assert.Equal(t, `"invoice_id" IN (SELECT "id" FROM "invoices" WHERE company_id = @company)`, plan[3].Filter)
+	assert.Equal(t, `"item_id" IN (SELECT "id" FROM "invoice_items" WHERE `+plan[3].Filter+`)`, plan[4].Filter)
+}
+
+func TestExportWriterJSON(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewExportWriter(&out, FormatJSON)
+	require.NoError(t, writer.Begin(3, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)))
+	require.NoError(t, writer.BeginTable(ExportTable{Name: "contacts"}))
+	require.NoError(t, writer.Row([]byte(`{"id":1,"name":"Ana"}`)))
+	require.NoError(t, writer.Row([]byte(`{"id":2,"name":"Bruno"}`)))
+	require.NoError(t, writer.EndTable())
+	require.NoError(t, writer.BeginTable(ExportTable{Name: "tasks"}))
+	require.NoError(t, writer.EndTable())
+	require.NoError(t, writer.End())
+
+	var export struct {
+		CompanyID  int                         `json:"company_id"`
+		ExportedAt string                      `json:"exported_at"`
+		Tables     map[string][]map[string]any `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &export))
+	assert.Equal(t, 3, export.CompanyID)
+	assert.Equal(t, "2026-10-16T12:00:00Z", export.ExportedAt)
+	assert.Len(t, export.Tables["contacts"], 2)
+	assert.NotNil(t, export.Tables["tasks"])
+	assert.Empty(t, export.Tables["tasks"])
+}
+
+func TestExportWriterSQL(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewExportWriter(&out, FormatSQL)
+	require.NoError(t, writer.Begin(3, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)))
+	require.NoError(t, writer.BeginTable(ExportTable{Name: "contacts", Columns: []string{"id", "name"}}))
+	require.NoError(t, writer.Row([]byte(`{"id":1,"name":"D'Ávila"}`)))
+	require.NoError(t, writer.EndTable())
+	require.NoError(t, writer.End())
+
+	sql := out.String()
+	assert.Contains(t, sql, "BEGIN;\n")
+	assert.Contains(t, sql, `INSERT INTO "contacts" ("id", "name") SELECT "id", "name" FROM json_populate_record(NULL::"contacts", '{"id":1,"name":"D''Ávila"}');`)
+	assert.Contains(t, sql, "COMMIT;\n")
+}
+
+func TestExportProgress(t *testing.T) {
+	export := TenantExport{Status: StatusRunning, TablesTotal: 8, TablesDone: 2}
+	export.ComputeProgress()
+	assert.Equal(t, 25, export.Progress)
+
+	export.Status = StatusCompleted
+	export.ComputeProgress()
+	assert.Equal(t, 100, export.Progress)
+}
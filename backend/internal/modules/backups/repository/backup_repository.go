@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/backups/models"
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// listLimit é a quantidade de jobs devolvida nas listagens, dos mais recentes
+const listLimit = 100
+
+// BackupRepository mantém a fila dos backups, restaurações e exportações e lê os dados da
+// empresa para a exportação
+type BackupRepository interface {
+	CreateBackupJob(ctx context.Context, job *models.BackupJob) error
+	ListBackupJobs(ctx context.Context, kind string) ([]models.BackupJob, error)
+	GetBackupJob(ctx context.Context, id int) (*models.BackupJob, error)
+	HasActiveBackupJob(ctx context.Context) (bool, error)
+	LastScheduledBackup(ctx context.Context) (*time.Time, error)
+	ClaimBackupJob(ctx context.Context, now time.Time) (*models.BackupJob, error)
+	SaveBackupJob(ctx context.Context, job *models.BackupJob) error
+	ExpiredBackups(ctx context.Context, keep int) ([]models.BackupJob, error)
+	FailStaleJobs(ctx context.Context, startedBefore, now time.Time) (int64, error)
+
+	CreateExport(ctx context.Context, export *models.TenantExport) error
+	ListExports(ctx context.Context) ([]models.TenantExport, error)
+	GetExport(ctx context.Context, id int) (*models.TenantExport, error)
+	ClaimExport(ctx context.Context, now time.Time) (*models.TenantExport, error)
+	SaveExport(ctx context.Context, export *models.TenantExport) error
+
+	SchemaColumns(ctx context.Context) ([]models.SchemaColumn, error)
+	ForeignKeys(ctx context.Context) ([]models.ForeignKey, error)
+	StreamRows(ctx context.Context, table models.ExportTable, companyID int, fn func(row []byte) error) (int64, error)
+}
+
+type backupRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewBackupRepository cria uma nova instância do repositório
+func NewBackupRepository() (BackupRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &backupRepository{
+		db:     db,
+		logger: logger.WithModule("backup_repository"),
+	}, nil
+}
+
+// CreateBackupJob coloca o backup ou a restauração na fila
+func (r *backupRepository) CreateBackupJob(ctx context.Context, job *models.BackupJob) error {
+	if err := db.Conn(ctx, r.db).Create(job).Error; err != nil {
+		r.logger.Error("erro ao criar job de backup", zap.Error(err), zap.String("kind", job.Kind))
+		return errors.WrapError(err, "falha ao criar job de backup")
+	}
+	return nil
+}
+
+// ListBackupJobs lista os jobs do tipo, dos mais recentes
+func (r *backupRepository) ListBackupJobs(ctx context.Context, kind string) ([]models.BackupJob, error) {
+	var jobs []models.BackupJob
+	err := db.Conn(ctx, r.db).Where("kind = ?", kind).Order("id DESC").Limit(listLimit).Find(&jobs).Error
+	if err != nil {
+		r.logger.Error("erro ao listar backups", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar backups")
+	}
+	return jobs, nil
+}
+
+// GetBackupJob busca um job de backup ou restauração
+func (r *backupRepository) GetBackupJob(ctx context.Context, id int) (*models.BackupJob, error) {
+	var job models.BackupJob
+	if err := db.Conn(ctx, r.db).First(&job, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrBackupNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar backup")
+	}
+	return &job, nil
+}
+
+// HasActiveBackupJob indica se há backup ou restauração na fila ou em andamento
+func (r *backupRepository) HasActiveBackupJob(ctx context.Context) (bool, error) {
+	var count int64
+	err := db.Conn(ctx, r.db).Model(&models.BackupJob{}).
+		Where("status IN ?", []string{models.StatusQueued, models.StatusRunning}).Count(&count).Error
+	if err != nil {
+		return false, errors.WrapError(err, "falha ao consultar a fila de backups")
+	}
+	return count > 0, nil
+}
+
+// LastScheduledBackup retorna quando o último backup agendado entrou na fila (nil sem nenhum)
+func (r *backupRepository) LastScheduledBackup(ctx context.Context) (*time.Time, error) {
+	var last sql.NullTime
+	err := db.Conn(ctx, r.db).Model(&models.BackupJob{}).
+		Where("kind = ? AND trigger = ?", models.KindBackup, models.TriggerScheduled).
+		Select("MAX(created_at)").Scan(&last).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar o último backup agendado")
+	}
+	if !last.Valid {
+		return nil, nil
+	}
+	return &last.Time, nil
+}
+
+// ClaimBackupJob retira o job mais antigo da fila e o marca em andamento; nil quando a fila está
+// vazia. As linhas travadas por outra instância são puladas.
+func (r *backupRepository) ClaimBackupJob(ctx context.Context, now time.Time) (*models.BackupJob, error) {
+	var claimed *models.BackupJob
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var job models.BackupJob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.StatusQueued).Order("id ASC").Take(&job).Error
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		job.Status, job.StartedAt = models.StatusRunning, &now
+		if err := tx.Model(&job).Updates(map[string]interface{}{"status": job.Status, "started_at": now}).Error; err != nil {
+			return err
+		}
+		claimed = &job
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("erro ao retirar job de backup da fila", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao retirar job de backup da fila")
+	}
+	return claimed, nil
+}
+
+// SaveBackupJob grava o andamento e o resultado do job
+func (r *backupRepository) SaveBackupJob(ctx context.Context, job *models.BackupJob) error {
+	err := db.Conn(ctx, r.db).Model(job).Select("status", "backup_id", "file_name", "size_bytes", "checksum",
+		"error", "started_at", "finished_at", "expired_at").Updates(job).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar job de backup", zap.Error(err), zap.Int("id", job.ID))
+		return errors.WrapError(err, "falha ao gravar job de backup")
+	}
+	return nil
+}
+
+// ExpiredBackups retorna os backups agendados concluídos além dos keep mais recentes, cujos
+// arquivos ainda não foram removidos
+func (r *backupRepository) ExpiredBackups(ctx context.Context, keep int) ([]models.BackupJob, error) {
+	var jobs []models.BackupJob
+	err := db.Conn(ctx, r.db).
+		Where("kind = ? AND trigger = ? AND status = ? AND expired_at IS NULL",
+			models.KindBackup, models.TriggerScheduled, models.StatusCompleted).
+		Order("id DESC").Offset(keep).Find(&jobs).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar backups expirados")
+	}
+	return jobs, nil
+}
+
+// FailStaleJobs marca como falhos os backups, restaurações e exportações em andamento desde
+// antes de startedBefore: a instância que os executava parou antes de concluí-los. O contexto
+// deve vir de tenant.AllCompanies.
+func (r *backupRepository) FailStaleJobs(ctx context.Context, startedBefore, now time.Time) (int64, error) {
+	updates := map[string]interface{}{"status": models.StatusFailed, "error": "interrompido antes da conclusão", "finished_at": now}
+	jobs := db.Conn(ctx, r.db).Model(&models.BackupJob{}).
+		Where("status = ? AND started_at < ?", models.StatusRunning, startedBefore).Updates(updates)
+	if jobs.Error != nil {
+		return 0, errors.WrapError(jobs.Error, "falha ao encerrar os backups interrompidos")
+	}
+	exports := db.Conn(ctx, r.db).Model(&models.TenantExport{}).
+		Where("status = ? AND started_at < ?", models.StatusRunning, startedBefore).Updates(updates)
+	if exports.Error != nil {
+		return 0, errors.WrapError(exports.Error, "falha ao encerrar as exportações interrompidas")
+	}
+	if total := jobs.RowsAffected + exports.RowsAffected; total > 0 {
+		r.logger.Warn("jobs de backup interrompidos marcados como falhos", zap.Int64("count", total))
+		return total, nil
+	}
+	return 0, nil
+}
+
+// CreateExport coloca a exportação da empresa na fila
+func (r *backupRepository) CreateExport(ctx context.Context, export *models.TenantExport) error {
+	if err := db.Conn(ctx, r.db).Create(export).Error; err != nil {
+		r.logger.Error("erro ao criar exportação", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar exportação")
+	}
+	return nil
+}
+
+// ListExports lista as exportações da empresa, das mais recentes
+func (r *backupRepository) ListExports(ctx context.Context) ([]models.TenantExport, error) {
+	var exports []models.TenantExport
+	if err := db.Conn(ctx, r.db).Order("id DESC").Limit(listLimit).Find(&exports).Error; err != nil {
+		r.logger.Error("erro ao listar exportações", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar exportações")
+	}
+	return exports, nil
+}
+
+// GetExport busca uma exportação da empresa
+func (r *backupRepository) GetExport(ctx context.Context, id int) (*models.TenantExport, error) {
+	var export models.TenantExport
+	if err := db.Conn(ctx, r.db).First(&export, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrTenantExportNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar exportação")
+	}
+	return &export, nil
+}
+
+// ClaimExport retira a exportação mais antiga da fila, de qualquer empresa, e a marca em
+// andamento; nil quando a fila está vazia. O contexto deve vir de tenant.AllCompanies.
+func (r *backupRepository) ClaimExport(ctx context.Context, now time.Time) (*models.TenantExport, error) {
+	var claimed *models.TenantExport
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var export models.TenantExport
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.StatusQueued).Order("id ASC").Take(&export).Error
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		export.Status, export.StartedAt = models.StatusRunning, &now
+		if err := tx.Model(&export).Updates(map[string]interface{}{"status": export.Status, "started_at": now}).Error; err != nil {
+			return err
+		}
+		claimed = &export
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("erro ao retirar exportação da fila", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao retirar exportação da fila")
+	}
+	return claimed, nil
+}
+
+// SaveExport grava o andamento e o resultado da exportação
+func (r *backupRepository) SaveExport(ctx context.Context, export *models.TenantExport) error {
+	err := db.Conn(ctx, r.db).Model(export).Select("status", "tables_total", "tables_done", "rows_exported",
+		"file_name", "size_bytes", "checksum", "error", "finished_at").Updates(export).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar exportação", zap.Error(err), zap.Int("id", export.ID))
+		return errors.WrapError(err, "falha ao gravar exportação")
+	}
+	return nil
+}
+
+// SchemaColumns lista as colunas das tabelas do esquema atual, na ordem de cada tabela
+func (r *backupRepository) SchemaColumns(ctx context.Context) ([]models.SchemaColumn, error) {
+	var columns []models.SchemaColumn
+	err := db.Conn(ctx, r.db).Raw(`SELECT c.table_name AS "table", c.column_name AS "column"
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_name, c.ordinal_position`).Scan(&columns).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao ler as colunas do banco")
+	}
+	return columns, nil
+}
+
+// ForeignKeys lista as chaves estrangeiras de uma coluna do esquema atual
+func (r *backupRepository) ForeignKeys(ctx context.Context) ([]models.ForeignKey, error) {
+	var keys []models.ForeignKey
+	err := db.Conn(ctx, r.db).Raw(`SELECT src.relname AS "table", a.attname AS "column",
+			ref.relname AS ref_table, af.attname AS ref_column
+		FROM pg_constraint c
+		JOIN pg_class src ON src.oid = c.conrelid
+		JOIN pg_class ref ON ref.oid = c.confrelid
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		JOIN pg_attribute af ON af.attrelid = c.confrelid AND af.attnum = c.confkey[1]
+		WHERE c.contype = 'f' AND array_length(c.conkey, 1) = 1
+			AND c.connamespace = (SELECT oid FROM pg_namespace WHERE nspname = current_schema())`).Scan(&keys).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao ler as chaves estrangeiras do banco")
+	}
+	return keys, nil
+}
+
+// StreamRows lê as linhas da empresa na tabela, como objetos JSON com as colunas da exportação,
+// e as entrega uma a uma a fn; retorna a quantidade de linhas
+func (r *backupRepository) StreamRows(ctx context.Context, table models.ExportTable, companyID int, fn func(row []byte) error) (int64, error) {
+	columns := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		columns[i] = pq.QuoteIdentifier(column)
+	}
+	query := fmt.Sprintf("SELECT row_to_json(t)::text FROM (SELECT %s FROM %s WHERE %s ORDER BY 1) t",
+		strings.Join(columns, ", "), pq.QuoteIdentifier(table.Name), table.Filter)
+
+	rows, err := db.Conn(ctx, r.db).Raw(query, sql.Named("company", companyID)).Rows()
+	if err != nil {
+		r.logger.Error("erro ao exportar tabela", zap.Error(err), zap.String("table", table.Name))
+		return 0, errors.WrapError(err, "falha ao exportar a tabela "+table.Name)
+	}
+	defer rows.Close()
+
+	var count int64
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return count, errors.WrapError(err, "falha ao ler a tabela "+table.Name)
+		}
+		if err := fn(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, errors.WrapError(err, "falha ao ler a tabela "+table.Name)
+	}
+	return count, nil
+}
@@ -0,0 +1,609 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/modules/backups/models"
+	"ERP-ONSMART/backend/internal/modules/backups/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// BackupService coloca na fila e executa os backups do banco, as restaurações e as exportações
+// dos dados das empresas
+type BackupService struct {
+	newRepo   func() (repository.BackupRepository, error)
+	dump      func(ctx context.Context, file string) error
+	restore   func(ctx context.Context, file string) error
+	dir       func() string
+	schedule  func() time.Duration
+	retention func() int
+	timeout   func() time.Duration
+	now       func() time.Time
+	logger    *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.BackupRepository
+}
+
+// NewBackupService cria o serviço sobre o repositório informado
+func NewBackupService(newRepo func() (repository.BackupRepository, error)) *BackupService {
+	return &BackupService{
+		newRepo:   newRepo,
+		dump:      pgDump,
+		restore:   pgRestore,
+		dir:       func() string { return viper.GetString("BACKUP_DIR") },
+		schedule:  func() time.Duration { return viper.GetDuration("BACKUP_SCHEDULE_INTERVAL") },
+		retention: func() int { return viper.GetInt("BACKUP_RETENTION") },
+		timeout:   func() time.Duration { return viper.GetDuration("BACKUP_JOB_TIMEOUT") },
+		now:       time.Now,
+		logger:    logger.WithModule("backup_service"),
+	}
+}
+
+var defaultService = NewBackupService(repository.NewBackupRepository)
+
+// RequestBackup coloca um backup do banco na fila
+func RequestBackup(ctx context.Context, requestedBy string) (*models.BackupJob, error) {
+	return defaultService.RequestBackup(ctx, requestedBy)
+}
+
+// ListBackups lista os backups com tamanho, checksum e disponibilidade do arquivo
+func ListBackups(ctx context.Context) ([]models.BackupJob, error) {
+	return defaultService.ListJobs(ctx, models.KindBackup)
+}
+
+// ListRestores lista as restaurações
+func ListRestores(ctx context.Context) ([]models.BackupJob, error) {
+	return defaultService.ListJobs(ctx, models.KindRestore)
+}
+
+// GetBackupJob busca um backup ou uma restauração
+func GetBackupJob(ctx context.Context, id int) (*models.BackupJob, error) {
+	return defaultService.GetJob(ctx, id)
+}
+
+// RequestRestore coloca na fila a restauração do backup
+func RequestRestore(ctx context.Context, backupID int, input models.RestoreInput, requestedBy string) (*models.BackupJob, error) {
+	return defaultService.RequestRestore(ctx, backupID, input, requestedBy)
+}
+
+// RequestExport coloca na fila a exportação dos dados da empresa
+func RequestExport(ctx context.Context, input models.ExportInput, requestedBy string) (*models.TenantExport, error) {
+	return defaultService.RequestExport(ctx, input, requestedBy)
+}
+
+// ListExports lista as exportações da empresa com o andamento
+func ListExports(ctx context.Context) ([]models.TenantExport, error) {
+	return defaultService.ListExports(ctx)
+}
+
+// GetExport busca uma exportação da empresa com o andamento
+func GetExport(ctx context.Context, id int) (*models.TenantExport, error) {
+	return defaultService.GetExport(ctx, id)
+}
+
+// OpenExport abre o arquivo da exportação concluída para download
+func OpenExport(ctx context.Context, id int) (*models.TenantExport, *os.File, error) {
+	return defaultService.OpenExport(ctx, id)
+}
+
+// StartBackupRunner processa periodicamente a fila dos backups, restaurações e exportações,
+// coloca na fila os backups agendados e remove os arquivos além da retenção
+func StartBackupRunner(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("backup_runner")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				processed, err := defaultService.RunPending(ctx)
+				metrics.ObserveJob("backup_runner", err)
+				if err != nil {
+					log.Error("erro ao processar a fila de backups", zap.Error(err))
+					continue
+				}
+				if processed > 0 {
+					log.Info("fila de backups processada", zap.Int("processed", processed))
+				}
+			}
+		}
+	}()
+}
+
+func (s *BackupService) repository() (repository.BackupRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// RequestBackup coloca um backup na fila; recusa enquanto houver backup ou restauração pendente
+func (s *BackupService) RequestBackup(ctx context.Context, requestedBy string) (*models.BackupJob, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return s.enqueue(ctx, repo, &models.BackupJob{Kind: models.KindBackup, Trigger: models.TriggerManual, RequestedBy: requestedBy})
+}
+
+func (s *BackupService) enqueue(ctx context.Context, repo repository.BackupRepository, job *models.BackupJob) (*models.BackupJob, error) {
+	active, err := repo.HasActiveBackupJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, errors.ErrBackupJobActive
+	}
+	job.Status = models.StatusQueued
+	if err := repo.CreateBackupJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ListJobs lista os jobs do tipo, marcando os backups com arquivo disponível
+func (s *BackupService) ListJobs(ctx context.Context, kind string) ([]models.BackupJob, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := repo.ListBackupJobs(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs {
+		jobs[i].Available = jobs[i].IsAvailable()
+	}
+	return jobs, nil
+}
+
+// GetJob busca um backup ou uma restauração
+func (s *BackupService) GetJob(ctx context.Context, id int) (*models.BackupJob, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	job, err := repo.GetBackupJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job.Available = job.IsAvailable()
+	return job, nil
+}
+
+// RequestRestore confere o backup e a confirmação (o nome do arquivo) e coloca a restauração
+// na fila. A restauração substitui os dados de todas as empresas pelos do backup.
+func (s *BackupService) RequestRestore(ctx context.Context, backupID int, input models.RestoreInput, requestedBy string) (*models.BackupJob, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	backup, err := repo.GetBackupJob(ctx, backupID)
+	if err != nil {
+		return nil, err
+	}
+	if backup.Kind != models.KindBackup {
+		return nil, errors.ErrBackupNotFound
+	}
+	if !backup.IsAvailable() {
+		return nil, errors.ErrBackupNotReady
+	}
+	if strings.TrimSpace(input.Confirm) != backup.FileName {
+		return nil, errors.ErrRestoreConfirmationNeeded
+	}
+	return s.enqueue(ctx, repo, &models.BackupJob{
+		Kind: models.KindRestore, Trigger: models.TriggerManual, BackupID: &backup.ID,
+		FileName: backup.FileName, RequestedBy: requestedBy,
+	})
+}
+
+// RequestExport coloca na fila a exportação dos dados da empresa do contexto (JSON por padrão)
+func (s *BackupService) RequestExport(ctx context.Context, input models.ExportInput, requestedBy string) (*models.TenantExport, error) {
+	format := strings.ToLower(strings.TrimSpace(input.Format))
+	if format == "" {
+		format = models.FormatJSON
+	}
+	if !models.IsValidFormat(format) {
+		return nil, errors.ErrInvalidExportFormat
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	export := &models.TenantExport{Format: format, Status: models.StatusQueued, RequestedBy: requestedBy}
+	if err := repo.CreateExport(ctx, export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// ListExports lista as exportações da empresa com o percentual concluído
+func (s *BackupService) ListExports(ctx context.Context) ([]models.TenantExport, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	exports, err := repo.ListExports(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range exports {
+		exports[i].ComputeProgress()
+	}
+	return exports, nil
+}
+
+// GetExport busca a exportação com o percentual concluído
+func (s *BackupService) GetExport(ctx context.Context, id int) (*models.TenantExport, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	export, err := repo.GetExport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	export.ComputeProgress()
+	return export, nil
+}
+
+// OpenExport abre o arquivo da exportação concluída
+func (s *BackupService) OpenExport(ctx context.Context, id int) (*models.TenantExport, *os.File, error) {
+	export, err := s.GetExport(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != models.StatusCompleted {
+		return nil, nil, errors.ErrTenantExportNotReady
+	}
+	file, err := os.Open(s.exportPath(export.FileName))
+	if err != nil {
+		return nil, nil, errors.WrapError(err, "falha ao abrir o arquivo da exportação")
+	}
+	return export, file, nil
+}
+
+// RunPending marca como falhos os jobs em andamento além do tempo limite (interrompidos com a
+// instância), coloca na fila o backup agendado quando vence o intervalo, executa os jobs da fila
+// (backups e restaurações, depois as exportações) e remove os backups agendados além da
+// retenção. Retorna a quantidade de jobs executados; as falhas de cada job ficam gravadas nele.
+func (s *BackupService) RunPending(ctx context.Context) (int, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, err
+	}
+	ctx = tenant.AllCompanies(ctx)
+
+	now := s.now()
+	if _, err := repo.FailStaleJobs(ctx, now.Add(-s.timeout()), now); err != nil {
+		return 0, err
+	}
+	if err := s.scheduleBackup(ctx, repo); err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for {
+		job, err := repo.ClaimBackupJob(ctx, s.now())
+		if err != nil {
+			return processed, err
+		}
+		if job == nil {
+			break
+		}
+		s.runBackupJob(ctx, repo, job)
+		processed++
+	}
+	for {
+		export, err := repo.ClaimExport(ctx, s.now())
+		if err != nil {
+			return processed, err
+		}
+		if export == nil {
+			break
+		}
+		s.runExport(ctx, repo, export)
+		processed++
+	}
+
+	return processed, s.pruneBackups(ctx, repo)
+}
+
+func (s *BackupService) scheduleBackup(ctx context.Context, repo repository.BackupRepository) error {
+	interval := s.schedule()
+	if interval <= 0 {
+		return nil
+	}
+	last, err := repo.LastScheduledBackup(ctx)
+	if err != nil {
+		return err
+	}
+	if last != nil && s.now().Sub(*last) < interval {
+		return nil
+	}
+	_, err = s.enqueue(ctx, repo, &models.BackupJob{Kind: models.KindBackup, Trigger: models.TriggerScheduled})
+	if err == errors.ErrBackupJobActive {
+		return nil
+	}
+	return err
+}
+
+func (s *BackupService) runBackupJob(ctx context.Context, repo repository.BackupRepository, job *models.BackupJob) {
+	jobCtx, cancel := context.WithTimeout(ctx, s.timeout())
+	defer cancel()
+
+	var err error
+	if job.Kind == models.KindRestore {
+		err = s.runRestore(jobCtx, repo, job)
+	} else {
+		err = s.runBackup(jobCtx, job)
+	}
+
+	finished := s.now()
+	job.FinishedAt = &finished
+	job.Status = models.StatusCompleted
+	if err != nil {
+		job.Status, job.Error = models.StatusFailed, err.Error()
+		s.logger.Error("falha no job de backup", zap.Error(err), zap.Int("id", job.ID), zap.String("kind", job.Kind))
+	}
+	if err := repo.SaveBackupJob(ctx, job); err != nil {
+		s.logger.Error("erro ao gravar o resultado do job de backup", zap.Error(err), zap.Int("id", job.ID))
+	}
+}
+
+// runBackup grava o pg_dump num arquivo temporário e, concluído, o renomeia com o tamanho e o
+// checksum registrados no job
+func (s *BackupService) runBackup(ctx context.Context, job *models.BackupJob) error {
+	if err := os.MkdirAll(s.dir(), 0o700); err != nil {
+		return errors.WrapError(err, "falha ao criar o diretório dos backups")
+	}
+	job.FileName = models.BackupFileName(job.ID, *job.StartedAt)
+	path := s.backupPath(job.FileName)
+	partial := path + ".part"
+	defer os.Remove(partial)
+
+	if err := s.dump(ctx, partial); err != nil {
+		return err
+	}
+	size, checksum, err := fileChecksum(partial)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return errors.WrapError(err, "falha ao gravar o arquivo do backup")
+	}
+	job.SizeBytes, job.Checksum = size, checksum
+	return nil
+}
+
+// runRestore confere o arquivo do backup pelo checksum antes de restaurá-lo
+func (s *BackupService) runRestore(ctx context.Context, repo repository.BackupRepository, job *models.BackupJob) error {
+	if job.BackupID == nil {
+		return errors.ErrBackupNotFound
+	}
+	backup, err := repo.GetBackupJob(ctx, *job.BackupID)
+	if err != nil {
+		return err
+	}
+	if !backup.IsAvailable() {
+		return errors.ErrBackupNotReady
+	}
+	path := s.backupPath(backup.FileName)
+	_, checksum, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if checksum != backup.Checksum {
+		return errors.ErrBackupChecksumMismatch
+	}
+	return s.restore(ctx, path)
+}
+
+// runExport grava os dados da empresa tabela a tabela, registrando o andamento a cada tabela
+func (s *BackupService) runExport(ctx context.Context, repo repository.BackupRepository, export *models.TenantExport) {
+	companyCtx := tenant.WithCompany(ctx, export.CompanyID)
+	jobCtx, cancel := context.WithTimeout(companyCtx, s.timeout())
+	defer cancel()
+	err := s.writeExport(jobCtx, repo, export)
+
+	finished := s.now()
+	export.FinishedAt = &finished
+	export.Status = models.StatusCompleted
+	if err != nil {
+		export.Status, export.Error = models.StatusFailed, err.Error()
+		s.logger.Error("falha na exportação", zap.Error(err), zap.Int("id", export.ID), zap.Int("company_id", export.CompanyID))
+	}
+	if err := repo.SaveExport(companyCtx, export); err != nil {
+		s.logger.Error("erro ao gravar o resultado da exportação", zap.Error(err), zap.Int("id", export.ID))
+	}
+}
+
+func (s *BackupService) writeExport(ctx context.Context, repo repository.BackupRepository, export *models.TenantExport) error {
+	columns, err := repo.SchemaColumns(ctx)
+	if err != nil {
+		return err
+	}
+	foreignKeys, err := repo.ForeignKeys(ctx)
+	if err != nil {
+		return err
+	}
+	plan := models.PlanExport(columns, foreignKeys)
+	export.TablesTotal = len(plan)
+	if err := repo.SaveExport(ctx, export); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.exportDir(), 0o700); err != nil {
+		return errors.WrapError(err, "falha ao criar o diretório das exportações")
+	}
+	export.FileName = models.ExportFileName(export.CompanyID, export.ID, export.Format)
+	path := s.exportPath(export.FileName)
+	partial := path + ".part"
+	defer os.Remove(partial)
+
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.WrapError(err, "falha ao criar o arquivo da exportação")
+	}
+	defer file.Close()
+	hash := sha256.New()
+	buffered := bufio.NewWriter(io.MultiWriter(file, hash))
+	writer := models.NewExportWriter(buffered, export.Format)
+
+	if err := writer.Begin(export.CompanyID, s.now()); err != nil {
+		return errors.WrapError(err, "falha ao gravar a exportação")
+	}
+	for _, table := range plan {
+		if err := writer.BeginTable(table); err != nil {
+			return errors.WrapError(err, "falha ao gravar a exportação")
+		}
+		rows, err := repo.StreamRows(ctx, table, export.CompanyID, writer.Row)
+		if err != nil {
+			return err
+		}
+		if err := writer.EndTable(); err != nil {
+			return errors.WrapError(err, "falha ao gravar a exportação")
+		}
+		export.TablesDone++
+		export.RowsExported += rows
+		if err := repo.SaveExport(ctx, export); err != nil {
+			return err
+		}
+	}
+	if err := writer.End(); err != nil {
+		return errors.WrapError(err, "falha ao gravar a exportação")
+	}
+	if err := buffered.Flush(); err != nil {
+		return errors.WrapError(err, "falha ao gravar a exportação")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return errors.WrapError(err, "falha ao gravar a exportação")
+	}
+	if err := file.Close(); err != nil {
+		return errors.WrapError(err, "falha ao gravar a exportação")
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return errors.WrapError(err, "falha ao gravar o arquivo da exportação")
+	}
+	export.SizeBytes, export.Checksum = info.Size(), hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// pruneBackups remove os arquivos dos backups agendados além dos mais recentes da retenção
+// (0 guarda todos); os backups manuais não expiram
+func (s *BackupService) pruneBackups(ctx context.Context, repo repository.BackupRepository) error {
+	keep := s.retention()
+	if keep <= 0 {
+		return nil
+	}
+	expired, err := repo.ExpiredBackups(ctx, keep)
+	if err != nil {
+		return err
+	}
+	for i := range expired {
+		job := &expired[i]
+		if err := os.Remove(s.backupPath(job.FileName)); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn("erro ao remover arquivo de backup expirado", zap.Error(err), zap.Int("id", job.ID))
+			continue
+		}
+		now := s.now()
+		job.ExpiredAt = &now
+		if err := repo.SaveBackupJob(ctx, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BackupService) backupPath(fileName string) string {
+	return filepath.Join(s.dir(), filepath.Base(fileName))
+}
+
+func (s *BackupService) exportDir() string {
+	return filepath.Join(s.dir(), "exports")
+}
+
+func (s *BackupService) exportPath(fileName string) string {
+	return filepath.Join(s.exportDir(), filepath.Base(fileName))
+}
+
+// fileChecksum retorna o tamanho e o SHA-256 do arquivo
+func fileChecksum(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", errors.WrapError(err, "falha ao abrir o arquivo do backup")
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", errors.WrapError(err, "falha ao ler o arquivo do backup")
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// pgDump grava o backup lógico do banco no formato custom do pg_dump. A fila dos backups fica
+// de fora para não voltar no tempo ao restaurar.
+func pgDump(ctx context.Context, file string) error {
+	return runPostgresTool(ctx, viper.GetString("PG_DUMP_PATH"),
+		"--format=custom", "--no-owner", "--no-privileges",
+		"--exclude-table=backup_jobs", "--exclude-table=backup_jobs_id_seq",
+		"--file", file)
+}
+
+// pgRestore aplica o backup numa única transação, recriando os objetos do arquivo
+func pgRestore(ctx context.Context, file string) error {
+	return runPostgresTool(ctx, viper.GetString("PG_RESTORE_PATH"),
+		"--clean", "--if-exists", "--no-owner", "--no-privileges", "--single-transaction", file)
+}
+
+// runPostgresTool executa o utilitário do PostgreSQL com a conexão do banco (DB_*); a senha vai
+// pela variável PGPASSWORD, fora da linha de comando
+func runPostgresTool(ctx context.Context, tool string, args ...string) error {
+	args = append([]string{
+		"--host", viper.GetString("DB_HOST"), "--port", viper.GetString("DB_PORT"),
+		"--username", viper.GetString("DB_USER"), "--dbname", viper.GetString("DB_NAME"),
+	}, args...)
+	cmd := exec.CommandContext(ctx, tool, args...)
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+viper.GetString("DB_PASSWORD"))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if len(message) > 500 {
+			message = message[len(message)-500:]
+		}
+		return fmt.Errorf("%s: %w: %s", filepath.Base(tool), err, message)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/backups/models"
+	"ERP-ONSMART/backend/internal/modules/backups/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackupRepo mantém a fila em memória; os demais métodos não são chamados
+type fakeBackupRepo struct {
+	repository.BackupRepository
+	jobs     []models.BackupJob
+	exports  []models.TenantExport
+	progress []int
+	rows     map[string][]string
+}
+
+func (r *fakeBackupRepo) CreateBackupJob(ctx context.Context, job *models.BackupJob) error {
+	job.ID = len(r.jobs) + 1
+	job.CreatedAt = day(16)
+	r.jobs = append(r.jobs, *job)
+	return nil
+}
+
+func (r *fakeBackupRepo) GetBackupJob(ctx context.Context, id int) (*models.BackupJob, error) {
+	if id < 1 || id > len(r.jobs) {
+		return nil, appErrors.ErrBackupNotFound
+	}
+	job := r.jobs[id-1]
+	return &job, nil
+}
+
+func (r *fakeBackupRepo) HasActiveBackupJob(ctx context.Context) (bool, error) {
+	for _, job := range r.jobs {
+		if job.Status == models.StatusQueued || job.Status == models.StatusRunning {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeBackupRepo) LastScheduledBackup(ctx context.Context) (*time.Time, error) {
+	var last *time.Time
+	for _, job := range r.jobs {
+		if job.Trigger == models.TriggerScheduled {
+			created := job.CreatedAt
+			last = &created
+		}
+	}
+	return last, nil
+}
+
+func (r *fakeBackupRepo) ClaimBackupJob(ctx context.Context, now time.Time) (*models.BackupJob, error) {
+	for i := range r.jobs {
+		if r.jobs[i].Status == models.StatusQueued {
+			r.jobs[i].Status, r.jobs[i].StartedAt = models.StatusRunning, &now
+			job := r.jobs[i]
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeBackupRepo) SaveBackupJob(ctx context.Context, job *models.BackupJob) error {
+	r.jobs[job.ID-1] = *job
+	return nil
+}
+
+func (r *fakeBackupRepo) ExpiredBackups(ctx context.Context, keep int) ([]models.BackupJob, error) {
+	var expired []models.BackupJob
+	kept := 0
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		job := r.jobs[i]
+		if job.Trigger != models.TriggerScheduled || job.Status != models.StatusCompleted || job.ExpiredAt != nil {
+			continue
+		}
+		if kept++; kept > keep {
+			expired = append(expired, job)
+		}
+	}
+	return expired, nil
+}
+
+func (r *fakeBackupRepo) FailStaleJobs(ctx context.Context, startedBefore, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeBackupRepo) CreateExport(ctx context.Context, export *models.TenantExport) error {
+	export.ID = len(r.exports) + 1
+	export.CompanyID = 3
+	r.exports = append(r.exports, *export)
+	return nil
+}
+
+func (r *fakeBackupRepo) ClaimExport(ctx context.Context, now time.Time) (*models.TenantExport, error) {
+	for i := range r.exports {
+		if r.exports[i].Status == models.StatusQueued {
+			r.exports[i].Status = models.StatusRunning
+			export := r.exports[i]
+			return &export, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeBackupRepo) SaveExport(ctx context.Context, export *models.TenantExport) error {
+	r.exports[export.ID-1] = *export
+	r.progress = append(r.progress, export.TablesDone)
+	return nil
+}
+
+func (r *fakeBackupRepo) SchemaColumns(ctx context.Context) ([]models.SchemaColumn, error) {
+	return []models.SchemaColumn{
+		{Table: "companies", Column: "id"},
+		{Table: "contacts", Column: "id"}, {Table: "contacts", Column: "company_id"}, {Table: "contacts", Column: "name"},
+	}, nil
+}
+
+func (r *fakeBackupRepo) ForeignKeys(ctx context.Context) ([]models.ForeignKey, error) {
+	return nil, nil
+}
+
+func (r *fakeBackupRepo) StreamRows(ctx context.Context, table models.ExportTable, companyID int, fn func(row []byte) error) (int64, error) {
+	for _, row := range r.rows[table.Name] {
+		if err := fn([]byte(row)); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(r.rows[table.Name])), nil
+}
+
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 3, 0, 0, 0, time.UTC)
+}
+
+func newTestService(t *testing.T, repo *fakeBackupRepo, now time.Time) *BackupService {
+	dir := t.TempDir()
+	s := NewBackupService(func() (repository.BackupRepository, error) { return repo, nil })
+	s.dir = func() string { return dir }
+	s.now = func() time.Time { return now }
+	s.schedule = func() time.Duration { return 0 }
+	s.retention = func() int { return 0 }
+	s.timeout = func() time.Duration { return time.Hour }
+	s.dump = func(ctx context.Context, file string) error { return os.WriteFile(file, []byte("dump"), 0o600) }
+	s.restore = func(ctx context.Context, file string) error { return nil }
+	return s
+}
+
+func TestBackupRunsAndRecordsChecksum(t *testing.T) {
+	repo := &fakeBackupRepo{}
+	s := newTestService(t, repo, day(16))
+	ctx := context.Background()
+
+	job, err := s.RequestBackup(ctx, "admin")
+	require.NoError(t, err)
+	_, err = s.RequestBackup(ctx, "admin")
+	assert.ErrorIs(t, err, appErrors.ErrBackupJobActive)
+
+	processed, err := s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	backup, err := s.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCompleted, backup.Status)
+	assert.Equal(t, "backup-20261016-030000-1.dump", backup.FileName)
+	assert.Equal(t, int64(4), backup.SizeBytes)
+	sum := sha256.Sum256([]byte("dump"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), backup.Checksum)
+	assert.True(t, backup.Available)
+	assert.FileExists(t, filepath.Join(s.dir(), backup.FileName))
+}
+
+func TestRestoreNeedsConfirmationAndMatchingChecksum(t *testing.T) {
+	repo := &fakeBackupRepo{}
+	s := newTestService(t, repo, day(16))
+	ctx := context.Background()
+	var restored string
+	s.restore = func(ctx context.Context, file string) error { restored = file; return nil }
+
+	backup, err := s.RequestBackup(ctx, "admin")
+	require.NoError(t, err)
+	_, err = s.RequestRestore(ctx, backup.ID, models.RestoreInput{Confirm: "x"}, "admin")
+	assert.ErrorIs(t, err, appErrors.ErrBackupNotReady, "o backup na fila ainda não tem arquivo")
+	_, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	fileName := repo.jobs[0].FileName
+
+	_, err = s.RequestRestore(ctx, backup.ID, models.RestoreInput{Confirm: "outro.dump"}, "admin")
+	assert.ErrorIs(t, err, appErrors.ErrRestoreConfirmationNeeded)
+
+	restore, err := s.RequestRestore(ctx, backup.ID, models.RestoreInput{Confirm: fileName}, "admin")
+	require.NoError(t, err)
+	_, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCompleted, repo.jobs[restore.ID-1].Status)
+	assert.Equal(t, filepath.Join(s.dir(), fileName), restored)
+
+	require.NoError(t, os.WriteFile(filepath.Join(s.dir(), fileName), []byte("alterado"), 0o600))
+	restored = ""
+	restore, err = s.RequestRestore(ctx, backup.ID, models.RestoreInput{Confirm: fileName}, "admin")
+	require.NoError(t, err)
+	_, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusFailed, repo.jobs[restore.ID-1].Status)
+	assert.Equal(t, appErrors.ErrBackupChecksumMismatch.Error(), repo.jobs[restore.ID-1].Error)
+	assert.Empty(t, restored, "o arquivo alterado não é restaurado")
+}
+
+func TestScheduledBackupsAndRetention(t *testing.T) {
+	repo := &fakeBackupRepo{}
+	s := newTestService(t, repo, day(16))
+	s.schedule = func() time.Duration { return 24 * time.Hour }
+	s.retention = func() int { return 1 }
+	ctx := context.Background()
+
+	_, err := s.RunPending(ctx)
+	require.NoError(t, err)
+	require.Len(t, repo.jobs, 1)
+	assert.Equal(t, models.TriggerScheduled, repo.jobs[0].Trigger)
+
+	_, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Len(t, repo.jobs, 1, "o intervalo ainda não venceu")
+
+	// o próximo backup entra na fila num dia seguinte e a retenção remove o anterior
+	repo.jobs[0].CreatedAt = day(14)
+	repo.jobs[0].FileName = "backup-antigo.dump"
+	require.NoError(t, os.WriteFile(filepath.Join(s.dir(), "backup-antigo.dump"), []byte("dump"), 0o600))
+	_, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	require.Len(t, repo.jobs, 2)
+	assert.NotNil(t, repo.jobs[0].ExpiredAt)
+	assert.NoFileExists(t, filepath.Join(s.dir(), "backup-antigo.dump"))
+	assert.Nil(t, repo.jobs[1].ExpiredAt)
+}
+
+func TestExportWritesTenantDataWithProgress(t *testing.T) {
+	repo := &fakeBackupRepo{rows: map[string][]string{
+		"companies": {`{"id":3}`},
+		"contacts":  {`{"id":1,"company_id":3,"name":"Ana"}`, `{"id":2,"company_id":3,"name":"Bruno"}`},
+	}}
+	s := newTestService(t, repo, day(16))
+	ctx := context.Background()
+
+	_, err := s.RequestExport(ctx, models.ExportInput{Format: "xml"}, "admin")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidExportFormat)
+	export, err := s.RequestExport(ctx, models.ExportInput{}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, models.FormatJSON, export.Format)
+
+	_, err = s.RunPending(ctx)
+	require.NoError(t, err)
+	done := repo.exports[0]
+	assert.Equal(t, models.StatusCompleted, done.Status, done.Error)
+	assert.Equal(t, 2, done.TablesTotal)
+	assert.Equal(t, int64(3), done.RowsExported)
+	assert.Equal(t, []int{0, 1, 2, 2}, repo.progress, "o andamento é gravado a cada tabela")
+
+	content, err := os.ReadFile(filepath.Join(s.dir(), "exports", done.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), done.SizeBytes)
+	var data struct {
+		Tables map[string][]json.RawMessage `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal(content, &data))
+	assert.Len(t, data.Tables["contacts"], 2)
+}
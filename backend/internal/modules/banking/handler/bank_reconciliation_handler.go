@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/banking/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tamanho máximo aceito para o arquivo de extrato (10 MB)
+const maxStatementFileSize = 10 << 20
+
+// Importa um extrato bancário OFX, CSV ou retorno de cobrança CNAB 240 enviado como multipart (campo "file")
+// @Accept multipart/form-data
+func ImportBankStatementHandler(c *gin.Context) {
+	bankAccount := c.PostForm("bank_account")
+	if bankAccount == "" {
+		c.Error(errors.ToAPIError(errors.ErrInvalidRequest, "").WithFieldError("bank_account", "campo obrigatório"))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(errors.InvalidRequest(err).WithFieldError("file", "campo obrigatório"))
+		return
+	}
+
+	if fileHeader.Size > maxStatementFileSize {
+		c.Error(errors.NewAPIError(http.StatusRequestEntityTooLarge, "statement_too_large", "arquivo excede o tamanho máximo permitido"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(errors.InvalidParam("erro ao abrir arquivo").WithDetails(err.Error()))
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(errors.InvalidParam("erro ao ler arquivo").WithDetails(err.Error()))
+		return
+	}
+
+	statement, err := service.ImportBankStatement(c.Request.Context(), bankAccount, fileHeader.Filename, c.PostForm("format"), content)
+	if err != nil {
+		if _, _, known := errors.Lookup(err); known {
+			c.Error(err)
+			return
+		}
+		// Falhas de leitura do arquivo (OFX/CSV/CNAB malformado) não são erros internos
+		c.Error(errors.NewAPIError(http.StatusUnprocessableEntity, "invalid_statement", "erro ao importar extrato").WithDetails(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"statement": statement})
+}
+
+// Lista os extratos importados
+func ListBankStatementsHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.GetAllBankStatements(c.Request.Context(), &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar extratos")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna um extrato com seus lançamentos e status de conciliação
+func GetBankStatementHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	statement, err := service.GetBankStatement(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar extrato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statement": statement})
+}
+
+// Gera sugestões de conciliação para os lançamentos em aberto do extrato
+func SuggestReconciliationsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	suggestions, err := service.SuggestReconciliations(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar sugestões")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
+
+// Confirma a sugestão de conciliação de um lançamento
+func ConfirmBankLineHandler(c *gin.Context) {
+	lineID, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.ConfirmSuggestion(c.Request.Context(), lineID); err != nil {
+		c.Error(err).SetMeta("erro ao processar conciliação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lançamento conciliado com sucesso"})
+}
+
+// Concilia manualmente um lançamento com um pagamento ou conta a pagar
+func MatchBankLineHandler(c *gin.Context) {
+	lineID, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MatchType string `json:"match_type" binding:"required"`
+		MatchID   int    `json:"match_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.MatchLineManually(c.Request.Context(), lineID, req.MatchType, req.MatchID); err != nil {
+		c.Error(err).SetMeta("erro ao processar conciliação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lançamento conciliado com sucesso"})
+}
+
+// Desfaz a conciliação de um lançamento
+func UnmatchBankLineHandler(c *gin.Context) {
+	lineID, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.UnmatchLine(c.Request.Context(), lineID); err != nil {
+		c.Error(err).SetMeta("erro ao processar conciliação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conciliação desfeita com sucesso"})
+}
+
+// Marca um lançamento como ignorado na conciliação
+func IgnoreBankLineHandler(c *gin.Context) {
+	lineID, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.IgnoreLine(c.Request.Context(), lineID); err != nil {
+		c.Error(err).SetMeta("erro ao processar conciliação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lançamento ignorado"})
+}
+
+func parseID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
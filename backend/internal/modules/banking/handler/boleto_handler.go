@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/modules/banking/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Registra no provedor configurado os boletos das parcelas em aberto da fatura (das informadas em
+// installment_numbers ou de todas); a fatura sem parcelas recebe uma parcela única
+// @Security BearerAuth
+func RegisterBoletosHandler(c *gin.Context) {
+	var input models.BoletoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	boletos, err := service.RegisterBoletos(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao registrar boletos")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"boletos": boletos})
+}
+
+// Lista os boletos registrados, filtrando por fatura (invoice_id) e status
+// @Security BearerAuth
+func ListBoletosHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	filter := models.BoletoFilter{Status: c.Query("status")}
+	if raw := c.Query("invoice_id"); raw != "" {
+		invoiceID, err := strconv.Atoi(raw)
+		if err != nil || invoiceID <= 0 {
+			c.Error(errors.InvalidParam("invoice_id inválido"))
+			return
+		}
+		filter.InvoiceID = invoiceID
+	}
+
+	result, err := service.ListBoletos(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar boletos")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna o boleto com as instruções enviadas
+// @Security BearerAuth
+// @Param id path int true "ID do boleto"
+func GetBoletoHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	boleto, err := service.GetBoleto(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar boleto")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"boleto": boleto})
+}
+
+// Baixa o PDF do boleto com o recibo do pagador e a ficha de compensação
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path int true "ID do boleto"
+func DownloadBoletoPDFHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	data, filename, err := service.RenderBoletoPDF(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar PDF do boleto")
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// Envia ao banco a instrução de desconto, protesto ou cancelamento do boleto; na cobrança por
+// arquivo a instrução segue na próxima remessa
+// @Security BearerAuth
+// @Param id path int true "ID do boleto"
+func SendBoletoInstructionHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var input models.BoletoInstructionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	boleto, err := service.SendBoletoInstruction(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao enviar instrução do boleto")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"boleto": boleto})
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/modules/banking/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gera a remessa CNAB 240 com os boletos em aberto ainda não enviados ao banco (das faturas em
+// invoice_ids ou de todas); faturas em aberto sem parcelas recebem uma parcela única com boleto
+// @Security BearerAuth
+func GenerateRemittanceHandler(c *gin.Context) {
+	var input models.RemittanceInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	remittance, err := service.GenerateRemittance(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar remessa de cobrança")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"remittance": remittance})
+}
+
+// Lista as remessas de cobrança geradas
+// @Security BearerAuth
+func ListRemittancesHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.ListRemittances(c.Request.Context(), &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar remessas de cobrança")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna a remessa com os boletos enviados e o último retorno do banco de cada um
+// @Security BearerAuth
+// @Param id path int true "ID da remessa"
+func GetRemittanceHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	remittance, err := service.GetRemittance(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar remessa de cobrança")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"remittance": remittance})
+}
+
+// Baixa o arquivo da remessa para envio ao banco
+// @Security BearerAuth
+// @Produce text/plain
+// @Param id path int true "ID da remessa"
+func DownloadRemittanceHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	remittance, err := service.GetRemittance(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao baixar remessa de cobrança")
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": remittance.FileName}))
+	c.Data(http.StatusOK, "text/plain; charset=us-ascii", []byte(remittance.Content))
+}
+
+// Processa o arquivo de retorno CNAB 240 enviado como multipart (campo "file"): registra os
+// pagamentos das liquidações e as tarifas e devolve o relatório com o resultado de cada título
+// @Security BearerAuth
+// @Accept multipart/form-data
+func ProcessBankReturnHandler(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(errors.InvalidRequest(err).WithFieldError("file", "campo obrigatório"))
+		return
+	}
+
+	if fileHeader.Size > maxStatementFileSize {
+		c.Error(errors.NewAPIError(http.StatusRequestEntityTooLarge, "bank_return_too_large", "arquivo excede o tamanho máximo permitido"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(errors.InvalidParam("erro ao abrir arquivo").WithDetails(err.Error()))
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(errors.InvalidParam("erro ao ler arquivo").WithDetails(err.Error()))
+		return
+	}
+
+	bankReturn, err := service.ProcessBankReturn(c.Request.Context(), fileHeader.Filename, content, middleware.CurrentUsername(c))
+	if err != nil {
+		if _, _, known := errors.Lookup(err); known {
+			c.Error(err)
+			return
+		}
+		// Arquivos fora do layout CNAB 240 não são erros internos
+		c.Error(errors.NewAPIError(http.StatusUnprocessableEntity, "invalid_bank_return", "erro ao processar retorno de cobrança").WithDetails(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"return": bankReturn})
+}
+
+// Lista os retornos de cobrança processados, com os totais de cada arquivo
+// @Security BearerAuth
+func ListBankReturnsHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.ListBankReturns(c.Request.Context(), &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar retornos de cobrança")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna o relatório do retorno de cobrança com o resultado de cada título
+// @Security BearerAuth
+// @Param id path int true "ID do retorno"
+func GetBankReturnHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	bankReturn, err := service.GetBankReturn(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar retorno de cobrança")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": bankReturn})
+}
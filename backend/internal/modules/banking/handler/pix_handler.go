@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/modules/banking/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cria no PSP a cobrança Pix dinâmica do saldo em aberto da fatura; uma cobrança ativa do mesmo
+// valor é devolvida em vez de criar outra
+// @Security BearerAuth
+func CreatePixChargeHandler(c *gin.Context) {
+	var input models.PixChargeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	charge, err := service.CreatePixCharge(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar cobrança Pix")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"charge": charge})
+}
+
+// Lista as cobranças Pix, filtrando por fatura (invoice_id) e status
+// @Security BearerAuth
+func ListPixChargesHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	filter := models.PixChargeFilter{Status: c.Query("status")}
+	if raw := c.Query("invoice_id"); raw != "" {
+		invoiceID, err := strconv.Atoi(raw)
+		if err != nil || invoiceID <= 0 {
+			c.Error(errors.InvalidParam("invoice_id inválido"))
+			return
+		}
+		filter.InvoiceID = invoiceID
+	}
+
+	result, err := service.ListPixCharges(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar cobranças Pix")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna a cobrança Pix com o copia e cola
+// @Security BearerAuth
+// @Param id path int true "ID da cobrança Pix"
+func GetPixChargeHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	charge, err := service.GetPixCharge(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar cobrança Pix")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"charge": charge})
+}
+
+// Retorna o QR code da cobrança Pix em PNG
+// @Security BearerAuth
+// @Produce image/png
+// @Param id path int true "ID da cobrança Pix"
+func GetPixChargeQRCodeHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	charge, err := service.GetPixCharge(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar cobrança Pix")
+		return
+	}
+	data, err := service.PixQRCodePNG(charge.Payload)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar QR code da cobrança Pix")
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", data)
+}
+
+// Recebe as notificações de Pix do PSP e registra o pagamento das faturas cobradas. O token do
+// caminho deve ser o PIX_WEBHOOK_SECRET; o PSP acrescenta /pix à URL cadastrada.
+// @Param token path string true "Token do webhook"
+func PixWebhookHandler(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	results, err := service.ProcessPixWebhook(c.Request.Context(), c.Param("token"), body)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao processar webhook Pix")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Formatos de extrato suportados
+const (
+	StatementFormatOFX = "ofx"
+	StatementFormatCSV = "csv"
+	// Retorno de cobrança no layout FEBRABAN 240
+	StatementFormatCNAB240 = "cnab240"
+)
+
+// Status de conciliação de um lançamento bancário
+const (
+	LineStatusUnmatched = "unmatched"
+	LineStatusSuggested = "suggested"
+	LineStatusMatched   = "matched"
+	LineStatusIgnored   = "ignored"
+)
+
+// Tipos de documento aos quais um lançamento pode ser conciliado
+const (
+	MatchTypePayment      = "payment"
+	MatchTypeSupplierBill = "supplier_bill"
+)
+
+// BankStatement representa um extrato bancário importado
+type BankStatement struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	CompanyID   int       `json:"company_id" gorm:"<-:create"`
+	BankAccount string    `json:"bank_account" validate:"required"`
+	FileName    string    `json:"file_name"`
+	Format      string    `json:"format" validate:"required,oneof=ofx csv cnab240"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	LineCount   int       `json:"line_count"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Lines []BankStatementLine `json:"lines,omitempty" gorm:"foreignKey:StatementID"`
+}
+
+// BankStatementLine representa um lançamento do extrato e seu status de conciliação.
+// Valores positivos são créditos (entradas) e negativos são débitos (saídas).
+type BankStatementLine struct {
+	ID              int        `json:"id" gorm:"primaryKey"`
+	StatementID     int        `json:"statement_id" gorm:"index"`
+	TransactionDate time.Time  `json:"transaction_date"`
+	Amount          float64    `json:"amount"`
+	Description     string     `json:"description"`
+	Reference       string     `json:"reference"`
+	ExternalID      string     `json:"external_id"`
+	Status          string     `json:"status" gorm:"default:unmatched"`
+	SuggestedType   *string    `json:"suggested_type,omitempty"`
+	SuggestedID     *int       `json:"suggested_id,omitempty"`
+	MatchScore      int        `json:"match_score"`
+	MatchedType     *string    `json:"matched_type,omitempty"`
+	MatchedID       *int       `json:"matched_id,omitempty"`
+	ReconciledAt    *time.Time `json:"reconciled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Statement *BankStatement `json:"-" gorm:"foreignKey:StatementID"`
+}
+
+// MatchCandidate representa um documento (pagamento ou conta a pagar) candidato à conciliação
+type MatchCandidate struct {
+	Type        string    `json:"type"`
+	ID          int       `json:"id"`
+	Amount      float64   `json:"amount"`
+	Date        time.Time `json:"date"`
+	DocumentNo  string    `json:"document_no"`
+	Reference   string    `json:"reference,omitempty"`
+	ContactName string    `json:"contact_name,omitempty"`
+}
+
+// MatchSuggestion representa uma sugestão de conciliação com sua pontuação
+type MatchSuggestion struct {
+	LineID    int            `json:"line_id"`
+	Candidate MatchCandidate `json:"candidate"`
+	Score     int            `json:"score"`
+	Reasons   []string       `json:"reasons"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// Status do boleto registrado. Na cobrança por arquivo o boleto fica pendente até o banco
+// confirmar a entrada no retorno.
+const (
+	BoletoStatusPending    = "pending"
+	BoletoStatusRegistered = "registered"
+	BoletoStatusCancelled  = "cancelled"
+)
+
+// Status das instruções: as da cobrança por arquivo ficam pendentes até a próxima remessa
+const (
+	InstructionStatusPending = "pending"
+	InstructionStatusSent    = "sent"
+)
+
+// Boleto é o boleto de uma parcela de fatura registrado no banco ou no PSP de cobrança, com o
+// código de barras, a linha digitável e as condições vigentes de desconto e protesto
+type Boleto struct {
+	ID             int           `json:"id" gorm:"primaryKey"`
+	CompanyID      int           `json:"company_id" gorm:"<-:create"`
+	InvoiceID      int           `json:"invoice_id"`
+	InstallmentID  int           `json:"installment_id"`
+	Provider       string        `json:"provider"`
+	ExternalID     string        `json:"external_id,omitempty"`
+	OurNumber      string        `json:"our_number"`
+	Barcode        string        `json:"barcode"`
+	DigitableLine  string        `json:"digitable_line"`
+	DocumentNo     string        `json:"document_no"`
+	IssueDate      time.Time     `json:"issue_date"`
+	DueDate        time.Time     `json:"due_date"`
+	Amount         money.Decimal `json:"amount"`
+	DiscountAmount money.Decimal `json:"discount_amount" gorm:"default:0"`
+	DiscountUntil  *time.Time    `json:"discount_until,omitempty"`
+	ProtestDays    int           `json:"protest_days"`
+	Status         string        `json:"status"`
+	CancelledAt    *time.Time    `json:"cancelled_at,omitempty"`
+	CreatedBy      string        `json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Instructions []BoletoInstruction `json:"instructions,omitempty" gorm:"foreignKey:BoletoID"`
+}
+
+// BoletoInstruction é uma instrução enviada sobre o boleto (desconto, protesto ou cancelamento).
+// RemittanceID é a remessa que levou a instrução ao banco, na cobrança por arquivo.
+type BoletoInstruction struct {
+	ID             int           `json:"id" gorm:"primaryKey"`
+	CompanyID      int           `json:"company_id" gorm:"<-:create"`
+	BoletoID       int           `json:"boleto_id" gorm:"index"`
+	Kind           string        `json:"kind"`
+	DiscountAmount money.Decimal `json:"discount_amount" gorm:"default:0"`
+	DiscountUntil  *time.Time    `json:"discount_until,omitempty"`
+	ProtestDays    int           `json:"protest_days"`
+	Reason         string        `json:"reason,omitempty"`
+	Status         string        `json:"status"`
+	RemittanceID   *int          `json:"remittance_id,omitempty"`
+	CreatedBy      string        `json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Boleto *Boleto `json:"-" gorm:"foreignKey:BoletoID"`
+}
+
+// BoletoInput pede o registro dos boletos das parcelas em aberto da fatura (das informadas em
+// installment_numbers ou de todas) que ainda não têm boleto ativo
+type BoletoInput struct {
+	InvoiceID          int   `json:"invoice_id" binding:"required"`
+	InstallmentNumbers []int `json:"installment_numbers"`
+}
+
+// BoletoInstructionInput é a instrução sobre o boleto: kind discount (discount_amount até
+// discount_until), protest (protest_days depois do vencimento) ou cancel
+type BoletoInstructionInput struct {
+	Kind           string        `json:"kind" binding:"required"`
+	DiscountAmount money.Decimal `json:"discount_amount"`
+	DiscountUntil  *time.Time    `json:"discount_until"`
+	ProtestDays    int           `json:"protest_days"`
+	Reason         string        `json:"reason"`
+}
+
+// BoletoFilter filtra a lista de boletos
+type BoletoFilter struct {
+	InvoiceID int
+	Status    string
+}
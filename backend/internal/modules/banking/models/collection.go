@@ -0,0 +1,143 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// Status de um boleto enviado ao banco em remessa CNAB
+const (
+	SlipStatusRemitted   = "remitted"
+	SlipStatusRegistered = "registered"
+	SlipStatusRejected   = "rejected"
+	SlipStatusPaid       = "paid"
+	SlipStatusWrittenOff = "written_off"
+)
+
+// Resultado do processamento de cada título do arquivo de retorno
+const (
+	ReturnResultPaid       = "paid"
+	ReturnResultRegistered = "registered"
+	ReturnResultRejected   = "rejected"
+	ReturnResultWrittenOff = "written_off"
+	ReturnResultFee        = "fee"
+	ReturnResultIgnored    = "ignored"
+	ReturnResultError      = "error"
+)
+
+// BankRemittance é um arquivo de remessa CNAB 240 gerado para registro de boletos no banco e
+// envio das instruções pendentes. Sequence é o número sequencial do arquivo (NSA) na empresa.
+type BankRemittance struct {
+	ID               int           `json:"id" gorm:"primaryKey"`
+	CompanyID        int           `json:"company_id" gorm:"<-:create"`
+	Sequence         int           `json:"sequence"`
+	FileName         string        `json:"file_name"`
+	BankCode         string        `json:"bank_code"`
+	SlipCount        int           `json:"slip_count"`
+	InstructionCount int           `json:"instruction_count"`
+	TotalAmount      money.Decimal `json:"total_amount"`
+	Content          string        `json:"-"`
+	CreatedBy        string        `json:"created_by"`
+	CreatedAt        time.Time     `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Slips []BankSlip `json:"slips,omitempty" gorm:"foreignKey:RemittanceID"`
+}
+
+// BankSlip é o boleto de uma parcela de fatura enviado em remessa, com o último retorno do banco
+type BankSlip struct {
+	ID             int           `json:"id" gorm:"primaryKey"`
+	CompanyID      int           `json:"company_id" gorm:"<-:create"`
+	RemittanceID   int           `json:"remittance_id" gorm:"index"`
+	InvoiceID      int           `json:"invoice_id"`
+	InstallmentID  int           `json:"installment_id"`
+	OurNumber      string        `json:"our_number"`
+	DocumentNo     string        `json:"document_no"`
+	DueDate        time.Time     `json:"due_date"`
+	Amount         money.Decimal `json:"amount"`
+	Status         string        `json:"status" gorm:"default:remitted"`
+	PaidAmount     money.Decimal `json:"paid_amount" gorm:"default:0"`
+	FeeAmount      money.Decimal `json:"fee_amount" gorm:"default:0"`
+	PaidAt         *time.Time    `json:"paid_at,omitempty"`
+	PaymentID      *int          `json:"payment_id,omitempty"`
+	LastOccurrence string        `json:"last_occurrence,omitempty"`
+	LastMessage    string        `json:"last_message,omitempty"`
+	CreatedAt      time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// RemittanceInput restringe a remessa às faturas informadas; vazio, entram todas as faturas em
+// aberto com boleto ainda não enviado
+type RemittanceInput struct {
+	InvoiceIDs []int `json:"invoice_ids"`
+}
+
+// RemittanceCandidate é uma parcela em aberto com boleto, com os dados do pagador para a remessa
+type RemittanceCandidate struct {
+	InstallmentID int
+	InvoiceID     int
+	InvoiceNo     string
+	Number        int
+	IssueDate     time.Time
+	DueDate       time.Time
+	Amount        money.Decimal
+	AmountPaid    money.Decimal
+	OurNumber     string
+
+	PersonType   string
+	Name         string
+	CompanyName  string
+	Document     string
+	ZipCode      string
+	Street       string
+	StreetNumber string
+	Complement   string
+	Neighborhood string
+	City         string
+	State        string
+}
+
+// BankReturn é um arquivo de retorno CNAB 240 processado, com o resumo do que foi baixado.
+// O mesmo arquivo (pelo hash do conteúdo) não é processado duas vezes.
+type BankReturn struct {
+	ID            int           `json:"id" gorm:"primaryKey"`
+	CompanyID     int           `json:"company_id" gorm:"<-:create"`
+	FileName      string        `json:"file_name"`
+	FileHash      string        `json:"-"`
+	RecordCount   int           `json:"record_count"`
+	PaidCount     int           `json:"paid_count"`
+	PaidAmount    money.Decimal `json:"paid_amount"`
+	FeeAmount     money.Decimal `json:"fee_amount"`
+	RejectedCount int           `json:"rejected_count"`
+	ErrorCount    int           `json:"error_count"`
+	ProcessedBy   string        `json:"processed_by"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Items []BankReturnItem `json:"items,omitempty" gorm:"foreignKey:ReturnID"`
+}
+
+// BankReturnItem é o resultado de um título do retorno: a ocorrência do banco, o boleto e a
+// fatura encontrados e o pagamento ou a tarifa registrados
+type BankReturnItem struct {
+	ID                    int           `json:"id" gorm:"primaryKey"`
+	CompanyID             int           `json:"company_id" gorm:"<-:create"`
+	ReturnID              int           `json:"return_id" gorm:"index"`
+	Line                  int           `json:"line"`
+	Occurrence            string        `json:"occurrence"`
+	OccurrenceDescription string        `json:"occurrence_description"`
+	OurNumber             string        `json:"our_number"`
+	DocumentNo            string        `json:"document_no,omitempty"`
+	SlipID                *int          `json:"slip_id,omitempty"`
+	InvoiceID             *int          `json:"invoice_id,omitempty"`
+	PaymentID             *int          `json:"payment_id,omitempty"`
+	Amount                money.Decimal `json:"amount"`
+	PaidAmount            money.Decimal `json:"paid_amount"`
+	FeeAmount             money.Decimal `json:"fee_amount"`
+	OccurredAt            *time.Time    `json:"occurred_at,omitempty"`
+	Result                string        `json:"result"`
+	Message               string        `json:"message,omitempty"`
+
+	// Relationships
+	Return *BankReturn `json:"-" gorm:"foreignKey:ReturnID"`
+}
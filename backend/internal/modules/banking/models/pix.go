@@ -0,0 +1,89 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// Status da cobrança Pix dinâmica
+const (
+	PixStatusActive = "active"
+	PixStatusPaid   = "paid"
+)
+
+// PixCharge é a cobrança Pix dinâmica de uma fatura, criada no PSP pelo saldo em aberto. O
+// Payload é o BR Code (copia e cola) do QR; o webhook do PSP informa o pagamento, que é
+// registrado na fatura (PaymentID).
+type PixCharge struct {
+	ID         int           `json:"id" gorm:"primaryKey"`
+	CompanyID  int           `json:"company_id" gorm:"<-:create"`
+	InvoiceID  int           `json:"invoice_id"`
+	Provider   string        `json:"provider"`
+	TxID       string        `json:"txid" gorm:"column:txid"`
+	Location   string        `json:"location"`
+	Payload    string        `json:"payload"`
+	Amount     money.Decimal `json:"amount"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	Status     string        `json:"status"`
+	PaidAmount money.Decimal `json:"paid_amount" gorm:"default:0"`
+	PaidAt     *time.Time    `json:"paid_at,omitempty"`
+	EndToEndID string        `json:"end_to_end_id,omitempty" gorm:"column:end_to_end_id"`
+	PaymentID  *int          `json:"payment_id,omitempty"`
+	CreatedBy  string        `json:"created_by"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Payable indica se a cobrança ainda pode ser paga no instante informado
+func (c *PixCharge) Payable(now time.Time) bool {
+	return c.Status == PixStatusActive && now.Before(c.ExpiresAt)
+}
+
+// PixChargeInput pede a cobrança Pix do saldo em aberto da fatura
+type PixChargeInput struct {
+	InvoiceID int `json:"invoice_id" binding:"required"`
+}
+
+// PixChargeFilter filtra a lista de cobranças Pix
+type PixChargeFilter struct {
+	InvoiceID int
+	Status    string
+}
+
+// PixInvoice é a fatura cobrada por Pix, com o pagador e os processos de venda ligados a ela
+type PixInvoice struct {
+	ID          int
+	InvoiceNo   string
+	Status      string
+	GrandTotal  money.Decimal
+	AmountPaid  money.Decimal
+	PersonType  string
+	Name        string
+	CompanyName string
+	Document    string
+}
+
+// Balance é o saldo em aberto da fatura
+func (i PixInvoice) Balance() money.Decimal {
+	return i.GrandTotal.Sub(i.AmountPaid)
+}
+
+// PixWebhookResult é o resultado de cada Pix notificado pelo webhook: paid (pagamento
+// registrado), duplicate (já registrado), ignored (sem cobrança do ERP) ou error
+type PixWebhookResult struct {
+	EndToEndID string        `json:"end_to_end_id"`
+	TxID       string        `json:"txid,omitempty"`
+	Amount     money.Decimal `json:"amount"`
+	Result     string        `json:"result"`
+	InvoiceID  int           `json:"invoice_id,omitempty"`
+	PaymentID  int           `json:"payment_id,omitempty"`
+	Message    string        `json:"message,omitempty"`
+}
+
+// Resultados do processamento do webhook Pix
+const (
+	PixResultPaid      = "paid"
+	PixResultDuplicate = "duplicate"
+	PixResultIgnored   = "ignored"
+	PixResultError     = "error"
+)
@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BankReconciliationRepository define as operações do repositório de conciliação bancária
+type BankReconciliationRepository interface {
+	CreateStatement(ctx context.Context, statement *models.BankStatement) error
+	GetStatementByID(ctx context.Context, id int) (*models.BankStatement, error)
+	GetAllStatements(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetLineByID(ctx context.Context, id int) (*models.BankStatementLine, error)
+	GetOpenLines(ctx context.Context, statementID int) ([]models.BankStatementLine, error)
+
+	// Candidatos à conciliação
+	GetPaymentCandidates(ctx context.Context, startDate, endDate time.Time) ([]models.MatchCandidate, error)
+	GetSupplierBillCandidates(ctx context.Context) ([]models.MatchCandidate, error)
+
+	// Atualização do status de conciliação
+	SaveSuggestion(ctx context.Context, lineID int, matchType string, matchID int, score int) error
+	MatchLine(ctx context.Context, lineID int, matchType string, matchID int) error
+	UnmatchLine(ctx context.Context, lineID int) error
+	IgnoreLine(ctx context.Context, lineID int) error
+}
+
+type bankReconciliationRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewBankReconciliationRepository cria uma nova instância do repositório
+func NewBankReconciliationRepository() (BankReconciliationRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &bankReconciliationRepository{
+		db:     db,
+		logger: logger.WithModule("bank_reconciliation_repository"),
+	}, nil
+}
+
+// CreateStatement grava o extrato e seus lançamentos em uma única transação
+func (r *bankReconciliationRepository) CreateStatement(ctx context.Context, statement *models.BankStatement) error {
+	lines := statement.Lines
+	statement.Lines = nil
+	statement.LineCount = len(lines)
+
+	tx := db.Conn(ctx, r.db).Begin()
+
+	if err := tx.Create(statement).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar extrato bancário", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar extrato bancário")
+	}
+
+	for i := range lines {
+		lines[i].StatementID = statement.ID
+		if lines[i].Status == "" {
+			lines[i].Status = models.LineStatusUnmatched
+		}
+	}
+
+	if len(lines) > 0 {
+		if err := tx.CreateInBatches(lines, 100).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao criar lançamentos do extrato", zap.Error(err))
+			return errors.WrapError(err, "falha ao criar lançamentos do extrato")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	statement.Lines = lines
+	r.logger.Info("extrato bancário importado com sucesso",
+		zap.Int("id", statement.ID), zap.Int("lines", statement.LineCount))
+	return nil
+}
+
+// GetStatementByID busca um extrato pelo ID com seus lançamentos
+func (r *bankReconciliationRepository) GetStatementByID(ctx context.Context, id int) (*models.BankStatement, error) {
+	var statement models.BankStatement
+
+	query := db.Conn(ctx, r.db).Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("transaction_date ASC, id ASC")
+	})
+
+	if err := query.First(&statement, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrBankStatementNotFound
+		}
+		r.logger.Error("erro ao buscar extrato por ID", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar extrato bancário")
+	}
+
+	return &statement, nil
+}
+
+// GetAllStatements retorna os extratos importados com paginação
+func (r *bankReconciliationRepository) GetAllStatements(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var statements []models.BankStatement
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.BankStatement{})
+
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar extratos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar extratos")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("created_at DESC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&statements).Error; err != nil {
+		r.logger.Error("erro ao buscar extratos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar extratos")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, statements), nil
+}
+
+// GetLineByID busca um lançamento bancário pelo ID
+func (r *bankReconciliationRepository) GetLineByID(ctx context.Context, id int) (*models.BankStatementLine, error) {
+	var line models.BankStatementLine
+	if err := db.Conn(ctx, r.db).First(&line, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrBankLineNotFound
+		}
+		r.logger.Error("erro ao buscar lançamento por ID", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar lançamento bancário")
+	}
+	return &line, nil
+}
+
+// GetOpenLines retorna os lançamentos ainda não conciliados de um extrato
+func (r *bankReconciliationRepository) GetOpenLines(ctx context.Context, statementID int) ([]models.BankStatementLine, error) {
+	var lines []models.BankStatementLine
+	if err := db.Conn(ctx, r.db).Where("statement_id = ? AND status IN ?", statementID,
+		[]string{models.LineStatusUnmatched, models.LineStatusSuggested}).
+		Order("transaction_date ASC, id ASC").
+		Find(&lines).Error; err != nil {
+		r.logger.Error("erro ao buscar lançamentos em aberto", zap.Error(err), zap.Int("statement_id", statementID))
+		return nil, errors.WrapError(err, "falha ao buscar lançamentos em aberto")
+	}
+	return lines, nil
+}
+
+// GetPaymentCandidates retorna os pagamentos do período ainda não conciliados com nenhum lançamento
+func (r *bankReconciliationRepository) GetPaymentCandidates(ctx context.Context, startDate, endDate time.Time) ([]models.MatchCandidate, error) {
+	company, err := tenant.Condition(ctx, "p")
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.MatchCandidate
+
+	query := `
+SELECT 'payment' AS type, p.id, p.amount, p.payment_date AS date,
+       i.invoice_no AS document_no, COALESCE(p.reference, '') AS reference,
+       COALESCE(c.name, '') AS contact_name
+FROM payments p
+JOIN invoices i ON i.id = p.invoice_id
+LEFT JOIN contacts c ON c.id = i.contact_id
+WHERE p.payment_date BETWEEN ? AND ? AND ` + company + `
+  AND NOT EXISTS (
+	SELECT 1 FROM bank_statement_lines l
+	WHERE l.matched_type = 'payment' AND l.matched_id = p.id
+  )`
+
+	if err := db.Conn(ctx, r.db).Raw(query, startDate, endDate).Scan(&candidates).Error; err != nil {
+		r.logger.Error("erro ao buscar pagamentos candidatos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar pagamentos candidatos")
+	}
+	return candidates, nil
+}
+
+// GetSupplierBillCandidates retorna as contas a pagar em aberto, com o saldo devedor como valor;
+// as ligadas a pedido de compra só entram depois da conferência de três vias
+func (r *bankReconciliationRepository) GetSupplierBillCandidates(ctx context.Context) ([]models.MatchCandidate, error) {
+	company, err := tenant.Condition(ctx, "b")
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.MatchCandidate
+
+	query := `
+SELECT 'supplier_bill' AS type, b.id, (b.grand_total - b.amount_paid) AS amount, b.due_date AS date,
+       b.bill_no AS document_no, '' AS reference, COALESCE(c.name, '') AS contact_name
+FROM supplier_bills b
+LEFT JOIN contacts c ON c.id = b.contact_id
+WHERE b.status IN ('open', 'partial') AND b.match_status IN ('not_required', 'matched', 'accepted')
+  AND ` + company
+
+	if err := db.Conn(ctx, r.db).Raw(query).Scan(&candidates).Error; err != nil {
+		r.logger.Error("erro ao buscar contas a pagar candidatas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar contas a pagar candidatas")
+	}
+	return candidates, nil
+}
+
+// SaveSuggestion registra a melhor sugestão de conciliação encontrada para o lançamento
+func (r *bankReconciliationRepository) SaveSuggestion(ctx context.Context, lineID int, matchType string, matchID int, score int) error {
+	result := db.Conn(ctx, r.db).Model(&models.BankStatementLine{}).
+		Where("id = ? AND status IN ?", lineID, []string{models.LineStatusUnmatched, models.LineStatusSuggested}).
+		Updates(map[string]interface{}{
+			"status":         models.LineStatusSuggested,
+			"suggested_type": matchType,
+			"suggested_id":   matchID,
+			"match_score":    score,
+		})
+	if result.Error != nil {
+		r.logger.Error("erro ao salvar sugestão", zap.Error(result.Error), zap.Int("line_id", lineID))
+		return errors.WrapError(result.Error, "falha ao salvar sugestão de conciliação")
+	}
+	return nil
+}
+
+// MatchLine concilia o lançamento com um pagamento ou conta a pagar.
+// Para contas a pagar, o valor do lançamento é baixado como pagamento da conta.
+func (r *bankReconciliationRepository) MatchLine(ctx context.Context, lineID int, matchType string, matchID int) error {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var line models.BankStatementLine
+	if err := tx.First(&line, lineID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrBankLineNotFound
+		}
+		return errors.WrapError(err, "falha ao buscar lançamento bancário")
+	}
+
+	if line.Status == models.LineStatusMatched {
+		tx.Rollback()
+		return errors.ErrLineAlreadyReconciled
+	}
+
+	switch matchType {
+	case models.MatchTypePayment:
+		var payment sales.Payment
+		if err := tx.First(&payment, matchID).Error; err != nil {
+			tx.Rollback()
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrPaymentNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar payment")
+		}
+
+		// Pagamentos sem referência recebem a referência do banco (ver GetPendingReconciliations)
+		if payment.Reference == "" {
+			reference := line.Reference
+			if reference == "" {
+				reference = fmt.Sprintf("BANK-%d", line.ID)
+			}
+			if err := tx.Model(&sales.Payment{}).Where("id = ?", payment.ID).
+				Update("reference", reference).Error; err != nil {
+				tx.Rollback()
+				return errors.WrapError(err, "falha ao atualizar referência do payment")
+			}
+		}
+
+	case models.MatchTypeSupplierBill:
+		var bill sales.SupplierBill
+		if err := tx.First(&bill, matchID).Error; err != nil {
+			tx.Rollback()
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrSupplierBillNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar conta a pagar")
+		}
+		if !sales.BillMatchAllowsApproval(bill.MatchStatus) {
+			tx.Rollback()
+			return errors.ErrThreeWayMatchRequired
+		}
+
+		paid, err := money.FromFloat(math.Abs(line.Amount))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := applyBillPayment(tx, &bill, paid); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+	default:
+		tx.Rollback()
+		return errors.ErrInvalidMatchTarget
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.BankStatementLine{}).Where("id = ?", line.ID).
+		Updates(map[string]interface{}{
+			"status":        models.LineStatusMatched,
+			"matched_type":  matchType,
+			"matched_id":    matchID,
+			"reconciled_at": now,
+		}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao conciliar lançamento", zap.Error(err), zap.Int("line_id", lineID))
+		return errors.WrapError(err, "falha ao conciliar lançamento")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("lançamento conciliado com sucesso",
+		zap.Int("line_id", lineID), zap.String("match_type", matchType), zap.Int("match_id", matchID))
+	return nil
+}
+
+// UnmatchLine desfaz a conciliação de um lançamento, estornando a baixa de contas a pagar
+func (r *bankReconciliationRepository) UnmatchLine(ctx context.Context, lineID int) error {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var line models.BankStatementLine
+	if err := tx.First(&line, lineID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrBankLineNotFound
+		}
+		return errors.WrapError(err, "falha ao buscar lançamento bancário")
+	}
+
+	if line.Status == models.LineStatusMatched && line.MatchedType != nil && line.MatchedID != nil &&
+		*line.MatchedType == models.MatchTypeSupplierBill {
+		var bill sales.SupplierBill
+		if err := tx.First(&bill, *line.MatchedID).Error; err == nil {
+			reversal, err := money.FromFloat(-math.Abs(line.Amount))
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			if err := applyBillPayment(tx, &bill, reversal); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	if err := tx.Model(&models.BankStatementLine{}).Where("id = ?", line.ID).
+		Updates(map[string]interface{}{
+			"status":         models.LineStatusUnmatched,
+			"matched_type":   nil,
+			"matched_id":     nil,
+			"suggested_type": nil,
+			"suggested_id":   nil,
+			"match_score":    0,
+			"reconciled_at":  nil,
+		}).Error; err != nil {
+		tx.Rollback()
+		return errors.WrapError(err, "falha ao desfazer conciliação")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("conciliação desfeita", zap.Int("line_id", lineID))
+	return nil
+}
+
+// IgnoreLine marca o lançamento como ignorado (tarifas, transferências internas etc.)
+func (r *bankReconciliationRepository) IgnoreLine(ctx context.Context, lineID int) error {
+	result := db.Conn(ctx, r.db).Model(&models.BankStatementLine{}).
+		Where("id = ? AND status <> ?", lineID, models.LineStatusMatched).
+		Update("status", models.LineStatusIgnored)
+	if result.Error != nil {
+		return errors.WrapError(result.Error, "falha ao ignorar lançamento")
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetLineByID(ctx, lineID); err != nil {
+			return err
+		}
+		return errors.ErrLineAlreadyReconciled
+	}
+	return nil
+}
+
+// applyBillPayment soma (ou estorna, se negativo) um valor pago na conta a pagar e ajusta o status
+func applyBillPayment(tx *gorm.DB, bill *sales.SupplierBill, amount money.Decimal) error {
+	amountPaid := money.Max(bill.AmountPaid.Add(amount), money.Zero)
+
+	status := sales.SupplierBillStatusOpen
+	if amountPaid.GreaterThanOrEqual(bill.GrandTotal) {
+		status = sales.SupplierBillStatusPaid
+	} else if amountPaid.IsPositive() {
+		status = sales.SupplierBillStatusPartial
+	}
+
+	if err := tx.Model(&sales.SupplierBill{}).Where("id = ?", bill.ID).
+		Updates(map[string]interface{}{"amount_paid": amountPaid, "status": status}).Error; err != nil {
+		return errors.WrapError(err, "falha ao atualizar conta a pagar")
+	}
+
+	bill.AmountPaid = amountPaid
+	bill.Status = status
+	return nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Status de boleto que impedem um novo registro da parcela
+var activeBoletoStatuses = []string{models.BoletoStatusPending, models.BoletoStatusRegistered}
+
+// GetBoletoCandidates retorna as parcelas em aberto da fatura (as de numbers, se houver) sem
+// boleto ativo, com os dados do pagador
+func (r *collectionRepository) GetBoletoCandidates(ctx context.Context, invoiceID int, numbers []int) ([]models.RemittanceCandidate, error) {
+	query := r.payerQuery(ctx).
+		Where("ins.invoice_id = ? AND i.status IN ?", invoiceID, remittableInvoiceStatuses).
+		Where("ins.status <> ? AND ins.amount > ins.amount_paid", sales.InstallmentStatusPaid).
+		Where("NOT EXISTS (SELECT 1 FROM boletos b WHERE b.installment_id = ins.id AND b.status IN ?)", activeBoletoStatuses)
+	if len(numbers) > 0 {
+		query = query.Where("ins.number IN ?", numbers)
+	}
+
+	var candidates []models.RemittanceCandidate
+	if err := query.Order("ins.number").Scan(&candidates).Error; err != nil {
+		r.logger.Error("erro ao buscar parcelas para registro de boleto", zap.Error(err), zap.Int("invoice_id", invoiceID))
+		return nil, errors.WrapError(err, "falha ao buscar parcelas para registro de boleto")
+	}
+	return candidates, nil
+}
+
+// GetBoletoPayer retorna a parcela com os dados do pagador, para imprimir o boleto
+func (r *collectionRepository) GetBoletoPayer(ctx context.Context, installmentID int) (*models.RemittanceCandidate, error) {
+	var candidates []models.RemittanceCandidate
+	if err := r.payerQuery(ctx).Where("ins.id = ?", installmentID).Limit(1).Scan(&candidates).Error; err != nil {
+		r.logger.Error("erro ao buscar pagador do boleto", zap.Error(err), zap.Int("installment_id", installmentID))
+		return nil, errors.WrapError(err, "falha ao buscar pagador do boleto")
+	}
+	if len(candidates) == 0 {
+		return nil, errors.ErrInstallmentNotFound
+	}
+	return &candidates[0], nil
+}
+
+// CreateBoleto grava o boleto e copia o nosso número, o código de barras e a linha digitável
+// para a parcela, de onde o portal e a remessa os leem
+func (r *collectionRepository) CreateBoleto(ctx context.Context, boleto *models.Boleto) error {
+	conn := db.Conn(ctx, r.db)
+	if err := conn.Create(boleto).Error; err != nil {
+		r.logger.Error("erro ao gravar boleto", zap.Error(err), zap.Int("installment_id", boleto.InstallmentID))
+		return errors.WrapError(err, "falha ao gravar boleto")
+	}
+
+	if err := conn.Model(&sales.InvoiceInstallment{}).Where("id = ?", boleto.InstallmentID).Updates(map[string]interface{}{
+		"boleto_our_number":     boleto.OurNumber,
+		"boleto_barcode":        boleto.Barcode,
+		"boleto_digitable_line": boleto.DigitableLine,
+	}).Error; err != nil {
+		r.logger.Error("erro ao gravar boleto na parcela", zap.Error(err), zap.Int("installment_id", boleto.InstallmentID))
+		return errors.WrapError(err, "falha ao gravar boleto na parcela")
+	}
+
+	r.logger.Info("boleto registrado",
+		zap.Int("id", boleto.ID), zap.String("provider", boleto.Provider), zap.String("our_number", boleto.OurNumber))
+	return nil
+}
+
+// GetBoleto busca o boleto com as instruções enviadas
+func (r *collectionRepository) GetBoleto(ctx context.Context, id int) (*models.Boleto, error) {
+	var boleto models.Boleto
+	err := db.Conn(ctx, r.db).Preload("Instructions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&boleto, id).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrBoletoNotFound
+		}
+		r.logger.Error("erro ao buscar boleto", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar boleto")
+	}
+	return &boleto, nil
+}
+
+// ListBoletos lista os boletos, do mais recente para o mais antigo
+func (r *collectionRepository) ListBoletos(ctx context.Context, filter models.BoletoFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var boletos []models.Boleto
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.Boleto{})
+	if filter.InvoiceID > 0 {
+		query = query.Where("invoice_id = ?", filter.InvoiceID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar boletos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar boletos")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("id DESC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&boletos).Error; err != nil {
+		r.logger.Error("erro ao buscar boletos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar boletos")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, boletos), nil
+}
+
+// UpdateBoleto grava as condições ou o status do boleto alterados por uma instrução
+func (r *collectionRepository) UpdateBoleto(ctx context.Context, id int, fields map[string]interface{}) error {
+	if err := db.Conn(ctx, r.db).Model(&models.Boleto{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		r.logger.Error("erro ao atualizar boleto", zap.Error(err), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao atualizar boleto")
+	}
+	return nil
+}
+
+// ClearInstallmentCharge apaga o boleto cancelado da parcela, que deixa de aparecer no portal e
+// de entrar nas remessas
+func (r *collectionRepository) ClearInstallmentCharge(ctx context.Context, installmentID int) error {
+	if err := db.Conn(ctx, r.db).Model(&sales.InvoiceInstallment{}).Where("id = ?", installmentID).Updates(map[string]interface{}{
+		"boleto_our_number":     "",
+		"boleto_barcode":        "",
+		"boleto_digitable_line": "",
+	}).Error; err != nil {
+		r.logger.Error("erro ao remover boleto da parcela", zap.Error(err), zap.Int("installment_id", installmentID))
+		return errors.WrapError(err, "falha ao remover boleto da parcela")
+	}
+	return nil
+}
+
+// CreateBoletoInstruction grava a instrução enviada sobre o boleto
+func (r *collectionRepository) CreateBoletoInstruction(ctx context.Context, instruction *models.BoletoInstruction) error {
+	if err := db.Conn(ctx, r.db).Omit("Boleto").Create(instruction).Error; err != nil {
+		r.logger.Error("erro ao gravar instrução do boleto", zap.Error(err), zap.Int("boleto_id", instruction.BoletoID))
+		return errors.WrapError(err, "falha ao gravar instrução do boleto")
+	}
+	return nil
+}
+
+// GetPendingInstructions retorna as instruções da cobrança por arquivo que ainda não foram
+// enviadas em remessa (das faturas informadas, se houver), com o boleto
+func (r *collectionRepository) GetPendingInstructions(ctx context.Context, invoiceIDs []int) ([]models.BoletoInstruction, error) {
+	query := db.Conn(ctx, r.db).Preload("Boleto").
+		Where("status = ?", models.InstructionStatusPending)
+	if len(invoiceIDs) > 0 {
+		query = query.Where("boleto_id IN (SELECT id FROM boletos WHERE invoice_id IN ?)", invoiceIDs)
+	}
+
+	var instructions []models.BoletoInstruction
+	if err := query.Order("id").Find(&instructions).Error; err != nil {
+		r.logger.Error("erro ao buscar instruções pendentes", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar instruções pendentes")
+	}
+	return instructions, nil
+}
+
+// MarkInstructionsSent registra a remessa que levou as instruções ao banco
+func (r *collectionRepository) MarkInstructionsSent(ctx context.Context, ids []int, remittanceID int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := db.Conn(ctx, r.db).Model(&models.BoletoInstruction{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"status":        models.InstructionStatusSent,
+		"remittance_id": remittanceID,
+	}).Error; err != nil {
+		r.logger.Error("erro ao marcar instruções enviadas", zap.Error(err), zap.Int("remittance_id", remittanceID))
+		return errors.WrapError(err, "falha ao marcar instruções enviadas")
+	}
+	return nil
+}
+
+// MarkBoletoRegistered confirma o registro do boleto pendente da parcela, quando o retorno
+// informa a entrada do título
+func (r *collectionRepository) MarkBoletoRegistered(ctx context.Context, installmentID int) error {
+	if err := db.Conn(ctx, r.db).Model(&models.Boleto{}).
+		Where("installment_id = ? AND status = ?", installmentID, models.BoletoStatusPending).
+		Update("status", models.BoletoStatusRegistered).Error; err != nil {
+		r.logger.Error("erro ao confirmar registro do boleto", zap.Error(err), zap.Int("installment_id", installmentID))
+		return errors.WrapError(err, "falha ao confirmar registro do boleto")
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	companies "ERP-ONSMART/backend/internal/modules/companies/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Status de fatura que ainda aceitam cobrança por boleto
+var remittableInvoiceStatuses = []string{
+	sales.InvoiceStatusSent, sales.InvoiceStatusPartial, sales.InvoiceStatusOverdue,
+}
+
+// Status de boleto que impedem um novo envio da parcela ao banco
+var activeSlipStatuses = []string{
+	models.SlipStatusRemitted, models.SlipStatusRegistered, models.SlipStatusPaid,
+}
+
+// CollectionRepository define as operações da cobrança registrada: remessas, boletos e retornos
+type CollectionRepository interface {
+	GetCompany(ctx context.Context) (*companies.Company, error)
+	GetInvoicesWithoutInstallments(ctx context.Context, invoiceIDs []int) ([]int, error)
+	GetRemittanceCandidates(ctx context.Context, invoiceIDs []int, from time.Time) ([]models.RemittanceCandidate, error)
+	NextRemittanceSequence(ctx context.Context) (int, error)
+	CreateRemittance(ctx context.Context, remittance *models.BankRemittance) error
+	GetRemittance(ctx context.Context, id int) (*models.BankRemittance, error)
+	ListRemittances(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+
+	FindSlipByOurNumber(ctx context.Context, ourNumber string) (*models.BankSlip, error)
+	UpdateSlip(ctx context.Context, id int, fields map[string]interface{}) error
+	ReturnExists(ctx context.Context, fileHash string) (bool, error)
+	CreateReturn(ctx context.Context, bankReturn *models.BankReturn) error
+	GetReturn(ctx context.Context, id int) (*models.BankReturn, error)
+	ListReturns(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+
+	GetBoletoCandidates(ctx context.Context, invoiceID int, numbers []int) ([]models.RemittanceCandidate, error)
+	GetBoletoPayer(ctx context.Context, installmentID int) (*models.RemittanceCandidate, error)
+	CreateBoleto(ctx context.Context, boleto *models.Boleto) error
+	GetBoleto(ctx context.Context, id int) (*models.Boleto, error)
+	ListBoletos(ctx context.Context, filter models.BoletoFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	UpdateBoleto(ctx context.Context, id int, fields map[string]interface{}) error
+	ClearInstallmentCharge(ctx context.Context, installmentID int) error
+	CreateBoletoInstruction(ctx context.Context, instruction *models.BoletoInstruction) error
+	GetPendingInstructions(ctx context.Context, invoiceIDs []int) ([]models.BoletoInstruction, error)
+	MarkInstructionsSent(ctx context.Context, ids []int, remittanceID int) error
+	MarkBoletoRegistered(ctx context.Context, installmentID int) error
+}
+
+type collectionRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCollectionRepository cria uma nova instância do repositório da cobrança registrada
+func NewCollectionRepository() (CollectionRepository, error) {
+	conn, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &collectionRepository{
+		db:     conn,
+		logger: logger.WithModule("collection_repository"),
+	}, nil
+}
+
+// GetCompany busca a empresa do contexto, beneficiária dos boletos
+func (r *collectionRepository) GetCompany(ctx context.Context) (*companies.Company, error) {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		companyID = tenant.DefaultCompanyID
+	}
+
+	var company companies.Company
+	if err := db.Conn(ctx, r.db).First(&company, companyID).Error; err != nil {
+		r.logger.Error("erro ao buscar empresa beneficiária", zap.Error(err), zap.Int("company_id", companyID))
+		return nil, errors.WrapError(err, "falha ao buscar empresa beneficiária")
+	}
+	return &company, nil
+}
+
+// GetInvoicesWithoutInstallments retorna as faturas em aberto (entre as informadas, se houver)
+// que ainda não foram divididas em parcelas
+func (r *collectionRepository) GetInvoicesWithoutInstallments(ctx context.Context, invoiceIDs []int) ([]int, error) {
+	query := db.Conn(ctx, r.db).Model(&sales.Invoice{}).
+		Where("status IN ? AND grand_total > amount_paid", remittableInvoiceStatuses).
+		Where("NOT EXISTS (SELECT 1 FROM invoice_installments ins WHERE ins.invoice_id = invoices.id)")
+	if len(invoiceIDs) > 0 {
+		query = query.Where("id IN ?", invoiceIDs)
+	}
+
+	var ids []int
+	if err := query.Order("id").Pluck("id", &ids).Error; err != nil {
+		r.logger.Error("erro ao buscar faturas sem parcelas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar faturas sem parcelas")
+	}
+	return ids, nil
+}
+
+// GetRemittanceCandidates retorna as parcelas em aberto com boleto, vencendo a partir de from,
+// que ainda não têm boleto ativo no banco nem boleto registrado por API em outro provedor
+func (r *collectionRepository) GetRemittanceCandidates(ctx context.Context, invoiceIDs []int, from time.Time) ([]models.RemittanceCandidate, error) {
+	query := r.payerQuery(ctx).
+		Where("i.status IN ?", remittableInvoiceStatuses).
+		Where("ins.status <> ? AND ins.amount > ins.amount_paid", sales.InstallmentStatusPaid).
+		Where("COALESCE(ins.boleto_our_number, '') <> '' AND ins.due_date >= ?", from).
+		Where("NOT EXISTS (SELECT 1 FROM bank_slips s WHERE s.installment_id = ins.id AND s.status IN ?)", activeSlipStatuses).
+		Where("NOT EXISTS (SELECT 1 FROM boletos b WHERE b.installment_id = ins.id AND b.provider <> 'cnab' AND b.status <> ?)",
+			models.BoletoStatusCancelled)
+	if len(invoiceIDs) > 0 {
+		query = query.Where("ins.invoice_id IN ?", invoiceIDs)
+	}
+
+	var candidates []models.RemittanceCandidate
+	if err := query.Order("ins.due_date, ins.id").Scan(&candidates).Error; err != nil {
+		r.logger.Error("erro ao buscar parcelas para remessa", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar parcelas para remessa")
+	}
+	return candidates, nil
+}
+
+// payerQuery parte das parcelas com a fatura e o pagador. O endereço do pagador é o de cobrança
+// da fatura ou, sem ele, o do cadastro do contato.
+func (r *collectionRepository) payerQuery(ctx context.Context) *gorm.DB {
+	return db.Conn(ctx, r.db).Table("invoice_installments ins").
+		Select(`ins.id AS installment_id, ins.invoice_id, i.invoice_no, ins.number, i.issue_date, ins.due_date,
+       ins.amount, ins.amount_paid, ins.boleto_our_number AS our_number,
+       c.person_type, c.name, COALESCE(c.company_name, '') AS company_name, c.document,
+       COALESCE(a.zip_code, c.zip_code, '') AS zip_code, COALESCE(a.street, c.street, '') AS street,
+       COALESCE(a.number, c.number, '') AS street_number, COALESCE(a.complement, c.complement, '') AS complement,
+       COALESCE(a.neighborhood, c.neighborhood, '') AS neighborhood, COALESCE(a.city, c.city, '') AS city,
+       COALESCE(a.state, c.state, '') AS state`).
+		Joins("JOIN invoices i ON i.id = ins.invoice_id AND i.deleted_at IS NULL").
+		Joins("JOIN contacts c ON c.id = i.contact_id").
+		Joins("LEFT JOIN contact_addresses a ON a.id = i.billing_address_id").
+		Scopes(tenant.Scope(ctx, "ins"))
+}
+
+// NextRemittanceSequence retorna o próximo número sequencial de arquivo (NSA) da empresa
+func (r *collectionRepository) NextRemittanceSequence(ctx context.Context) (int, error) {
+	var sequence int
+	if err := db.Conn(ctx, r.db).Model(&models.BankRemittance{}).
+		Select("COALESCE(MAX(sequence), 0) + 1").Scan(&sequence).Error; err != nil {
+		r.logger.Error("erro ao calcular sequencial da remessa", zap.Error(err))
+		return 0, errors.WrapError(err, "falha ao calcular sequencial da remessa")
+	}
+	return sequence, nil
+}
+
+// CreateRemittance grava a remessa com seus boletos
+func (r *collectionRepository) CreateRemittance(ctx context.Context, remittance *models.BankRemittance) error {
+	if err := db.Conn(ctx, r.db).Create(remittance).Error; err != nil {
+		r.logger.Error("erro ao criar remessa de cobrança", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar remessa de cobrança")
+	}
+
+	r.logger.Info("remessa de cobrança gerada",
+		zap.Int("id", remittance.ID), zap.Int("sequence", remittance.Sequence), zap.Int("slips", remittance.SlipCount))
+	return nil
+}
+
+// GetRemittance busca a remessa com o arquivo e os boletos
+func (r *collectionRepository) GetRemittance(ctx context.Context, id int) (*models.BankRemittance, error) {
+	var remittance models.BankRemittance
+	err := db.Conn(ctx, r.db).Preload("Slips", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&remittance, id).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrBankRemittanceNotFound
+		}
+		r.logger.Error("erro ao buscar remessa de cobrança", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar remessa de cobrança")
+	}
+	return &remittance, nil
+}
+
+// ListRemittances lista as remessas geradas, da mais recente para a mais antiga
+func (r *collectionRepository) ListRemittances(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var remittances []models.BankRemittance
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.BankRemittance{})
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar remessas de cobrança", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar remessas de cobrança")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Omit("content").Order("sequence DESC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&remittances).Error; err != nil {
+		r.logger.Error("erro ao buscar remessas de cobrança", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar remessas de cobrança")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, remittances), nil
+}
+
+// FindSlipByOurNumber busca o boleto mais recente com o nosso número, ignorando os zeros à
+// esquerda que cada banco completa de um jeito; retorna nil se não houver
+func (r *collectionRepository) FindSlipByOurNumber(ctx context.Context, ourNumber string) (*models.BankSlip, error) {
+	normalized := strings.TrimLeft(strings.TrimSpace(ourNumber), "0")
+	if normalized == "" {
+		return nil, nil
+	}
+
+	var slip models.BankSlip
+	err := db.Conn(ctx, r.db).Where("LTRIM(our_number, '0') = ?", normalized).
+		Order("id DESC").First(&slip).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("erro ao buscar boleto pelo nosso número", zap.Error(err), zap.String("our_number", ourNumber))
+		return nil, errors.WrapError(err, "falha ao buscar boleto")
+	}
+	return &slip, nil
+}
+
+// UpdateSlip grava o status e os valores informados pelo retorno do banco
+func (r *collectionRepository) UpdateSlip(ctx context.Context, id int, fields map[string]interface{}) error {
+	if err := db.Conn(ctx, r.db).Model(&models.BankSlip{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		r.logger.Error("erro ao atualizar boleto", zap.Error(err), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao atualizar boleto")
+	}
+	return nil
+}
+
+// ReturnExists indica se um arquivo de retorno com o mesmo conteúdo já foi processado
+func (r *collectionRepository) ReturnExists(ctx context.Context, fileHash string) (bool, error) {
+	var count int64
+	if err := db.Conn(ctx, r.db).Model(&models.BankReturn{}).
+		Where("file_hash = ?", fileHash).Count(&count).Error; err != nil {
+		r.logger.Error("erro ao verificar retorno de cobrança", zap.Error(err))
+		return false, errors.WrapError(err, "falha ao verificar retorno de cobrança")
+	}
+	return count > 0, nil
+}
+
+// CreateReturn grava o retorno processado com o resultado de cada título
+func (r *collectionRepository) CreateReturn(ctx context.Context, bankReturn *models.BankReturn) error {
+	if err := db.Conn(ctx, r.db).Create(bankReturn).Error; err != nil {
+		r.logger.Error("erro ao gravar retorno de cobrança", zap.Error(err))
+		return errors.WrapError(err, "falha ao gravar retorno de cobrança")
+	}
+
+	r.logger.Info("retorno de cobrança processado",
+		zap.Int("id", bankReturn.ID), zap.Int("records", bankReturn.RecordCount), zap.Int("paid", bankReturn.PaidCount))
+	return nil
+}
+
+// GetReturn busca o retorno com o resultado de cada título
+func (r *collectionRepository) GetReturn(ctx context.Context, id int) (*models.BankReturn, error) {
+	var bankReturn models.BankReturn
+	err := db.Conn(ctx, r.db).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("line ASC")
+	}).First(&bankReturn, id).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrBankReturnNotFound
+		}
+		r.logger.Error("erro ao buscar retorno de cobrança", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar retorno de cobrança")
+	}
+	return &bankReturn, nil
+}
+
+// ListReturns lista os retornos processados, do mais recente para o mais antigo
+func (r *collectionRepository) ListReturns(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var returns []models.BankReturn
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.BankReturn{})
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar retornos de cobrança", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar retornos de cobrança")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("created_at DESC, id DESC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&returns).Error; err != nil {
+		r.logger.Error("erro ao buscar retornos de cobrança", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar retornos de cobrança")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, returns), nil
+}
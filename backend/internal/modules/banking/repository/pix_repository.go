@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PixRepository define as operações das cobranças Pix dinâmicas
+type PixRepository interface {
+	GetInvoice(ctx context.Context, invoiceID int) (*models.PixInvoice, error)
+	GetProcessIDs(ctx context.Context, invoiceID int) ([]int, error)
+	FindReusableCharge(ctx context.Context, invoiceID int, now time.Time) (*models.PixCharge, error)
+	CreateCharge(ctx context.Context, charge *models.PixCharge) error
+	GetCharge(ctx context.Context, id int) (*models.PixCharge, error)
+	ListCharges(ctx context.Context, filter models.PixChargeFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	FindChargeByTxID(ctx context.Context, txID string) (*models.PixCharge, error)
+	MarkChargePaid(ctx context.Context, id int, endToEndID string, amount money.Decimal, paidAt time.Time) (bool, error)
+	UpdateCharge(ctx context.Context, id int, fields map[string]interface{}) error
+}
+
+type pixRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewPixRepository cria uma nova instância do repositório das cobranças Pix
+func NewPixRepository() (PixRepository, error) {
+	conn, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &pixRepository{
+		db:     conn,
+		logger: logger.WithModule("pix_repository"),
+	}, nil
+}
+
+// GetInvoice busca a fatura com o saldo e o pagador
+func (r *pixRepository) GetInvoice(ctx context.Context, invoiceID int) (*models.PixInvoice, error) {
+	var invoices []models.PixInvoice
+	err := db.Conn(ctx, r.db).Table("invoices i").
+		Select(`i.id, i.invoice_no, i.status, i.grand_total, i.amount_paid,
+       c.person_type, c.name, COALESCE(c.company_name, '') AS company_name, c.document`).
+		Joins("JOIN contacts c ON c.id = i.contact_id").
+		Where("i.id = ? AND i.deleted_at IS NULL", invoiceID).
+		Scopes(tenant.Scope(ctx, "i")).
+		Limit(1).Scan(&invoices).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar fatura da cobrança Pix", zap.Error(err), zap.Int("invoice_id", invoiceID))
+		return nil, errors.WrapError(err, "falha ao buscar fatura da cobrança Pix")
+	}
+	if len(invoices) == 0 {
+		return nil, errors.ErrInvoiceNotFound
+	}
+	return &invoices[0], nil
+}
+
+// GetProcessIDs retorna os processos de venda ligados à fatura
+func (r *pixRepository) GetProcessIDs(ctx context.Context, invoiceID int) ([]int, error) {
+	var ids []int
+	if err := db.Conn(ctx, r.db).Table("process_invoices").
+		Where("invoice_id = ?", invoiceID).
+		Order("process_id").
+		Pluck("process_id", &ids).Error; err != nil {
+		r.logger.Error("erro ao buscar processos da fatura", zap.Error(err), zap.Int("invoice_id", invoiceID))
+		return nil, errors.WrapError(err, "falha ao buscar processos da fatura")
+	}
+	return ids, nil
+}
+
+// FindReusableCharge retorna a cobrança ativa mais recente da fatura que ainda não expirou, ou
+// nil se não houver
+func (r *pixRepository) FindReusableCharge(ctx context.Context, invoiceID int, now time.Time) (*models.PixCharge, error) {
+	var charges []models.PixCharge
+	if err := db.Conn(ctx, r.db).
+		Where("invoice_id = ? AND status = ? AND expires_at > ?", invoiceID, models.PixStatusActive, now).
+		Order("id DESC").Limit(1).Find(&charges).Error; err != nil {
+		r.logger.Error("erro ao buscar cobrança Pix ativa", zap.Error(err), zap.Int("invoice_id", invoiceID))
+		return nil, errors.WrapError(err, "falha ao buscar cobrança Pix ativa")
+	}
+	if len(charges) == 0 {
+		return nil, nil
+	}
+	return &charges[0], nil
+}
+
+// CreateCharge grava a cobrança criada no PSP
+func (r *pixRepository) CreateCharge(ctx context.Context, charge *models.PixCharge) error {
+	if err := db.Conn(ctx, r.db).Create(charge).Error; err != nil {
+		r.logger.Error("erro ao gravar cobrança Pix", zap.Error(err), zap.Int("invoice_id", charge.InvoiceID))
+		return errors.WrapError(err, "falha ao gravar cobrança Pix")
+	}
+	r.logger.Info("cobrança Pix criada",
+		zap.Int("id", charge.ID), zap.Int("invoice_id", charge.InvoiceID), zap.String("txid", charge.TxID))
+	return nil
+}
+
+// GetCharge busca a cobrança Pix
+func (r *pixRepository) GetCharge(ctx context.Context, id int) (*models.PixCharge, error) {
+	var charge models.PixCharge
+	if err := db.Conn(ctx, r.db).First(&charge, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrPixChargeNotFound
+		}
+		r.logger.Error("erro ao buscar cobrança Pix", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar cobrança Pix")
+	}
+	return &charge, nil
+}
+
+// ListCharges lista as cobranças Pix, da mais recente para a mais antiga
+func (r *pixRepository) ListCharges(ctx context.Context, filter models.PixChargeFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var charges []models.PixCharge
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.PixCharge{})
+	if filter.InvoiceID > 0 {
+		query = query.Where("invoice_id = ?", filter.InvoiceID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar cobranças Pix", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar cobranças Pix")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("id DESC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&charges).Error; err != nil {
+		r.logger.Error("erro ao buscar cobranças Pix", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar cobranças Pix")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, charges), nil
+}
+
+// FindChargeByTxID busca a cobrança pelo txid, ou nil se não houver. O webhook chega sem
+// empresa, então o chamador usa um contexto tenant.AllCompanies.
+func (r *pixRepository) FindChargeByTxID(ctx context.Context, txID string) (*models.PixCharge, error) {
+	var charges []models.PixCharge
+	if err := db.Conn(ctx, r.db).Where("txid = ?", txID).Limit(1).Find(&charges).Error; err != nil {
+		r.logger.Error("erro ao buscar cobrança Pix pelo txid", zap.Error(err), zap.String("txid", txID))
+		return nil, errors.WrapError(err, "falha ao buscar cobrança Pix")
+	}
+	if len(charges) == 0 {
+		return nil, nil
+	}
+	return &charges[0], nil
+}
+
+// MarkChargePaid marca a cobrança ativa como paga pelo Pix informado. Retorna false quando ela
+// já estava paga, o que torna o webhook repetido inofensivo mesmo com notificações simultâneas.
+func (r *pixRepository) MarkChargePaid(ctx context.Context, id int, endToEndID string, amount money.Decimal, paidAt time.Time) (bool, error) {
+	result := db.Conn(ctx, r.db).Model(&models.PixCharge{}).
+		Where("id = ? AND status = ?", id, models.PixStatusActive).
+		Updates(map[string]interface{}{
+			"status":        models.PixStatusPaid,
+			"end_to_end_id": endToEndID,
+			"paid_amount":   amount,
+			"paid_at":       paidAt,
+		})
+	if result.Error != nil {
+		r.logger.Error("erro ao marcar cobrança Pix paga", zap.Error(result.Error), zap.Int("id", id))
+		return false, errors.WrapError(result.Error, "falha ao marcar cobrança Pix paga")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateCharge grava o pagamento da fatura registrado para a cobrança
+func (r *pixRepository) UpdateCharge(ctx context.Context, id int, fields map[string]interface{}) error {
+	if err := db.Conn(ctx, r.db).Model(&models.PixCharge{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		r.logger.Error("erro ao atualizar cobrança Pix", zap.Error(err), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao atualizar cobrança Pix")
+	}
+	return nil
+}
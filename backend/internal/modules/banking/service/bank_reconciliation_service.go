@@ -0,0 +1,229 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/modules/banking/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"time"
+)
+
+// newTxManager abre o coordenador da unidade de trabalho; substituído nos testes
+var newTxManager = db.NewTxManager
+
+// Janela, em dias, usada para buscar pagamentos candidatos ao redor das datas do extrato
+const candidateWindowDays = 7
+
+// ImportBankStatement interpreta o arquivo e grava o extrato com seus lançamentos, na empresa do
+// contexto. Se o formato não for informado, é deduzido pela extensão do arquivo.
+func ImportBankStatement(ctx context.Context, bankAccount, fileName, format string, content []byte) (*models.BankStatement, error) {
+	if format == "" {
+		detected, err := DetectStatementFormat(fileName)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	lines, err := ParseStatement(format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &models.BankStatement{
+		BankAccount: bankAccount,
+		FileName:    fileName,
+		Format:      format,
+		Lines:       lines,
+	}
+	statement.StartDate, statement.EndDate = statementPeriod(lines)
+
+	repo, err := repository.NewBankReconciliationRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.CreateStatement(ctx, statement); err != nil {
+		return nil, err
+	}
+
+	return statement, nil
+}
+
+// GetBankStatement retorna um extrato com seus lançamentos
+func GetBankStatement(ctx context.Context, id int) (*models.BankStatement, error) {
+	repo, err := repository.NewBankReconciliationRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetStatementByID(ctx, id)
+}
+
+// GetAllBankStatements lista os extratos importados
+func GetAllBankStatements(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewBankReconciliationRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAllStatements(ctx, params)
+}
+
+// SuggestReconciliations gera sugestões para os lançamentos em aberto do extrato.
+// Cada documento é sugerido para no máximo um lançamento, priorizando as maiores pontuações.
+func SuggestReconciliations(ctx context.Context, statementID int) ([]models.MatchSuggestion, error) {
+	repo, err := repository.NewBankReconciliationRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := repo.GetStatementByID(ctx, statementID); err != nil {
+		return nil, err
+	}
+
+	lines, err := repo.GetOpenLines(ctx, statementID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return []models.MatchSuggestion{}, nil
+	}
+
+	start, end := statementPeriod(lines)
+	payments, err := repo.GetPaymentCandidates(ctx,
+		start.AddDate(0, 0, -candidateWindowDays),
+		end.AddDate(0, 0, candidateWindowDays),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	bills, err := repo.GetSupplierBillCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := append(payments, bills...)
+	suggestions := AssignBestMatches(lines, candidates)
+
+	// As sugestões do extrato são gravadas juntas: uma falha no meio não deixa parte delas salva
+	txManager, err := newTxManager()
+	if err != nil {
+		return nil, err
+	}
+	err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, suggestion := range suggestions {
+			if err := repo.SaveSuggestion(ctx, suggestion.LineID, suggestion.Candidate.Type,
+				suggestion.Candidate.ID, suggestion.Score); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// AssignBestMatches escolhe a melhor sugestão de cada lançamento sem repetir documentos
+func AssignBestMatches(lines []models.BankStatementLine, candidates []models.MatchCandidate) []models.MatchSuggestion {
+	var all []models.MatchSuggestion
+	for _, line := range lines {
+		all = append(all, SuggestMatches(line, candidates)...)
+	}
+
+	sortSuggestionsByScore(all)
+
+	usedLines := make(map[int]bool)
+	usedCandidates := make(map[string]bool)
+	result := []models.MatchSuggestion{}
+
+	for _, suggestion := range all {
+		key := candidateKey(suggestion.Candidate)
+		if usedLines[suggestion.LineID] || usedCandidates[key] {
+			continue
+		}
+		usedLines[suggestion.LineID] = true
+		usedCandidates[key] = true
+		result = append(result, suggestion)
+	}
+
+	return result
+}
+
+// ConfirmSuggestion aceita a sugestão registrada para o lançamento
+func ConfirmSuggestion(ctx context.Context, lineID int) error {
+	repo, err := repository.NewBankReconciliationRepository()
+	if err != nil {
+		return err
+	}
+
+	line, err := repo.GetLineByID(ctx, lineID)
+	if err != nil {
+		return err
+	}
+
+	if line.Status == models.LineStatusMatched {
+		return errors.ErrLineAlreadyReconciled
+	}
+	if line.Status != models.LineStatusSuggested || line.SuggestedType == nil || line.SuggestedID == nil {
+		return errors.ErrNoMatchSuggestion
+	}
+
+	return repo.MatchLine(ctx, lineID, *line.SuggestedType, *line.SuggestedID)
+}
+
+// MatchLineManually concilia o lançamento com o documento escolhido pelo usuário
+func MatchLineManually(ctx context.Context, lineID int, matchType string, matchID int) error {
+	if matchType != models.MatchTypePayment && matchType != models.MatchTypeSupplierBill {
+		return errors.ErrInvalidMatchTarget
+	}
+	if matchID <= 0 {
+		return errors.ErrInvalidMatchTarget
+	}
+
+	repo, err := repository.NewBankReconciliationRepository()
+	if err != nil {
+		return err
+	}
+	return repo.MatchLine(ctx, lineID, matchType, matchID)
+}
+
+// UnmatchLine desfaz a conciliação do lançamento
+func UnmatchLine(ctx context.Context, lineID int) error {
+	repo, err := repository.NewBankReconciliationRepository()
+	if err != nil {
+		return err
+	}
+	return repo.UnmatchLine(ctx, lineID)
+}
+
+// IgnoreLine marca o lançamento como não conciliável
+func IgnoreLine(ctx context.Context, lineID int) error {
+	repo, err := repository.NewBankReconciliationRepository()
+	if err != nil {
+		return err
+	}
+	return repo.IgnoreLine(ctx, lineID)
+}
+
+// statementPeriod retorna a menor e a maior data dos lançamentos
+func statementPeriod(lines []models.BankStatementLine) (time.Time, time.Time) {
+	var start, end time.Time
+	for i, line := range lines {
+		if i == 0 || line.TransactionDate.Before(start) {
+			start = line.TransactionDate
+		}
+		if i == 0 || line.TransactionDate.After(end) {
+			end = line.TransactionDate
+		}
+	}
+	return start, end
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/billing"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/boleto"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	salesModels "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// newBoletoProvider cria o provedor de boletos pelo nome; substituído nos testes
+var newBoletoProvider = boletoProvider
+
+// boletoProvider monta o provedor com as credenciais da API de cobrança e a conta de cobrança
+// da configuração
+func boletoProvider(name string) (boleto.Provider, error) {
+	return boleto.New(name, boleto.Credentials{
+		BaseURL:      viper.GetString("BOLETO_API_URL"),
+		ClientID:     viper.GetString("BOLETO_API_CLIENT_ID"),
+		ClientSecret: viper.GetString("BOLETO_API_CLIENT_SECRET"),
+		CertFile:     viper.GetString("BOLETO_API_CERT_FILE"),
+		KeyFile:      viper.GetString("BOLETO_API_KEY_FILE"),
+	}, remittanceConfig().Boleto)
+}
+
+// bankNames são os nomes impressos nos boletos dos bancos das APIs de cobrança
+var bankNames = map[string]string{"077": "Banco Inter"}
+
+// maxProtestDays é o maior prazo de protesto aceito na remessa (dois dígitos)
+const maxProtestDays = 99
+
+// RegisterBoletos registra no provedor configurado (BOLETO_PROVIDER) os boletos das parcelas em
+// aberto da fatura que ainda não têm boleto ativo; a fatura sem parcelas recebe antes uma parcela
+// única. O boleto da parcela vencida é atualizado: vence hoje e leva a multa e os juros por
+// atraso. Cada boleto é gravado assim que o provedor o registra, de modo que uma falha no meio
+// não descarta os já registrados.
+func RegisterBoletos(ctx context.Context, input models.BoletoInput, createdBy string) ([]models.Boleto, error) {
+	provider, err := newBoletoProvider(viper.GetString("BOLETO_PROVIDER"))
+	if err != nil {
+		return nil, err
+	}
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	txManager, err := newTxManager()
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := repo.GetInvoicesWithoutInstallments(ctx, []int{input.InvoiceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		if _, err := splitInvoice(ctx, input.InvoiceID, salesModels.InstallmentPlan{Count: 1}); err != nil {
+			return nil, err
+		}
+	}
+
+	candidates, err := repo.GetBoletoCandidates(ctx, input.InvoiceID, input.InstallmentNumbers)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, errors.ErrNoInstallmentsToRegister
+	}
+	due, err := invoiceAmountDue(ctx, input.InvoiceID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make([]models.Boleto, 0, len(candidates))
+	for _, candidate := range candidates {
+		title := boletoTitle(ctx, candidate, due)
+		registration, err := provider.Register(ctx, title)
+		if err != nil {
+			return nil, err
+		}
+
+		record := models.Boleto{
+			InvoiceID:     candidate.InvoiceID,
+			InstallmentID: candidate.InstallmentID,
+			Provider:      provider.Name(),
+			ExternalID:    registration.ExternalID,
+			OurNumber:     registration.OurNumber,
+			Barcode:       registration.Barcode,
+			DigitableLine: registration.DigitableLine,
+			DocumentNo:    title.DocumentNo,
+			IssueDate:     title.IssueDate,
+			DueDate:       title.DueDate,
+			Amount:        title.Amount,
+			Status:        models.BoletoStatusRegistered,
+			CreatedBy:     createdBy,
+		}
+		if registration.Pending {
+			record.Status = models.BoletoStatusPending
+		}
+		err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			return repo.CreateBoleto(ctx, &record)
+		})
+		if err != nil {
+			return nil, err
+		}
+		registered = append(registered, record)
+	}
+	return registered, nil
+}
+
+// boletoTitle monta o título com o saldo em aberto da parcela e o pagador da fatura; vencida, a
+// parcela vai com o valor devido na data do cálculo, que passa a ser o vencimento
+func boletoTitle(ctx context.Context, candidate models.RemittanceCandidate, due *salesModels.AmountDue) boleto.Title {
+	slip := remittanceSlip(candidate)
+	issueDate := candidate.IssueDate
+	if issueDate.IsZero() {
+		issueDate = localtime.Today(ctx)
+	}
+
+	dueDate, amount := candidate.DueDate, slip.Amount
+	if item, ok := due.Item(candidate.Number); ok && item.Overdue() {
+		dueDate, amount = due.AsOf, amount.Add(item.Fine).Add(item.Interest)
+	}
+
+	return boleto.Title{
+		ID:         candidate.InstallmentID,
+		DocumentNo: slip.DocumentNo,
+		IssueDate:  issueDate,
+		DueDate:    dueDate,
+		Amount:     amount,
+		Payer: boleto.Payer{
+			Name:         slip.PayerName,
+			Document:     onlyDigits(candidate.Document),
+			Street:       candidate.Street,
+			Number:       candidate.StreetNumber,
+			Complement:   candidate.Complement,
+			Neighborhood: candidate.Neighborhood,
+			City:         candidate.City,
+			State:        candidate.State,
+			ZipCode:      onlyDigits(candidate.ZipCode),
+		},
+	}
+}
+
+// GetBoleto retorna o boleto com as instruções enviadas
+func GetBoleto(ctx context.Context, id int) (*models.Boleto, error) {
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetBoleto(ctx, id)
+}
+
+// ListBoletos lista os boletos registrados
+func ListBoletos(ctx context.Context, filter models.BoletoFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListBoletos(ctx, filter, params)
+}
+
+// SendBoletoInstruction envia ao provedor do boleto a instrução de desconto, protesto ou
+// cancelamento e grava as novas condições. Na cobrança por arquivo a instrução fica pendente
+// e segue na próxima remessa; o cancelamento tira o boleto da parcela na hora.
+func SendBoletoInstruction(ctx context.Context, id int, input models.BoletoInstructionInput, createdBy string) (*models.Boleto, error) {
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	record, err := repo.GetBoleto(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status == models.BoletoStatusCancelled {
+		return nil, errors.ErrBoletoNotActive
+	}
+
+	instruction := models.BoletoInstruction{
+		BoletoID:  record.ID,
+		Kind:      input.Kind,
+		Reason:    strings.TrimSpace(input.Reason),
+		CreatedBy: createdBy,
+	}
+	fields := map[string]interface{}{}
+	switch input.Kind {
+	case boleto.InstructionDiscount:
+		if !input.DiscountAmount.IsPositive() || !input.DiscountAmount.LessThan(record.Amount) || input.DiscountUntil == nil {
+			return nil, fmt.Errorf("%w: informe discount_amount menor que o valor do boleto e discount_until", errors.ErrInvalidBoletoInstruction)
+		}
+		until := localtime.Date(*input.DiscountUntil)
+		if until.After(record.DueDate) {
+			return nil, fmt.Errorf("%w: o desconto vale no máximo até o vencimento", errors.ErrInvalidBoletoInstruction)
+		}
+		instruction.DiscountAmount = input.DiscountAmount.Round(2)
+		instruction.DiscountUntil = &until
+		fields["discount_amount"] = instruction.DiscountAmount
+		fields["discount_until"] = until
+
+	case boleto.InstructionProtest:
+		if input.ProtestDays < 1 || input.ProtestDays > maxProtestDays {
+			return nil, fmt.Errorf("%w: protest_days deve estar entre 1 e %d", errors.ErrInvalidBoletoInstruction, maxProtestDays)
+		}
+		instruction.ProtestDays = input.ProtestDays
+		fields["protest_days"] = input.ProtestDays
+
+	case boleto.InstructionCancel:
+		fields["status"] = models.BoletoStatusCancelled
+		fields["cancelled_at"] = localtime.Now(ctx)
+
+	default:
+		return nil, fmt.Errorf("%w: kind deve ser %s", errors.ErrInvalidBoletoInstruction, strings.Join(boleto.InstructionKinds, ", "))
+	}
+
+	provider, err := newBoletoProvider(record.Provider)
+	if err != nil {
+		return nil, err
+	}
+	request := boleto.Instruction{
+		Kind:           instruction.Kind,
+		OurNumber:      record.OurNumber,
+		ExternalID:     record.ExternalID,
+		DiscountAmount: instruction.DiscountAmount,
+		ProtestDays:    instruction.ProtestDays,
+		Reason:         instruction.Reason,
+	}
+	if instruction.DiscountUntil != nil {
+		request.DiscountUntil = *instruction.DiscountUntil
+	}
+	result, err := provider.Instruct(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	instruction.Status = models.InstructionStatusSent
+	if result.Pending {
+		instruction.Status = models.InstructionStatusPending
+	}
+
+	txManager, err := newTxManager()
+	if err != nil {
+		return nil, err
+	}
+	err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := repo.CreateBoletoInstruction(ctx, &instruction); err != nil {
+			return err
+		}
+		if err := repo.UpdateBoleto(ctx, record.ID, fields); err != nil {
+			return err
+		}
+		if input.Kind == boleto.InstructionCancel {
+			return repo.ClearInstallmentCharge(ctx, record.InstallmentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetBoleto(ctx, id)
+}
+
+// RenderBoletoPDF gera o PDF do boleto com o recibo do pagador e a ficha de compensação
+func RenderBoletoPDF(ctx context.Context, id int) ([]byte, string, error) {
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, "", err
+	}
+	record, err := repo.GetBoleto(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if record.Status == models.BoletoStatusCancelled {
+		return nil, "", errors.ErrBoletoNotActive
+	}
+	payer, err := repo.GetBoletoPayer(ctx, record.InstallmentID)
+	if err != nil {
+		return nil, "", err
+	}
+	company, err := repo.GetCompany(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	config := remittanceConfig()
+	slip := remittanceSlip(*payer)
+	doc := billing.BoletoDocument{
+		Boleto: billing.Boleto{
+			OurNumber:     record.OurNumber,
+			Barcode:       record.Barcode,
+			DigitableLine: record.DigitableLine,
+		},
+		BankCode:        record.Barcode[:min(3, len(record.Barcode))],
+		BeneficiaryName: company.LegalName,
+		DocumentNo:      record.DocumentNo,
+		IssueDate:       record.IssueDate,
+		DueDate:         record.DueDate,
+		Amount:          record.Amount,
+		Discount:        record.DiscountAmount,
+		PayerName:       slip.PayerName,
+		PayerDocument:   payer.Document,
+		PayerAddress:    payerAddress(slip),
+		Instructions:    boletoInstructions(record),
+	}
+	if doc.BeneficiaryName == "" {
+		doc.BeneficiaryName = company.Name
+	}
+	if company.Document != nil {
+		doc.BeneficiaryDocument = *company.Document
+	}
+	if record.Provider == boleto.ProviderCNAB {
+		doc.BankName = config.BankName
+		doc.Wallet = config.Boleto.Wallet
+		doc.AgencyAccount = joinDigit(config.Boleto.Agency, config.AgencyDigit) + " / " +
+			joinDigit(config.Boleto.Account, config.AccountDigit)
+	} else {
+		doc.BankName = bankNames[doc.BankCode]
+	}
+
+	var buf bytes.Buffer
+	if err := billing.WriteBoletoPDF(&buf, doc); err != nil {
+		return nil, "", errors.WrapError(err, "falha ao gerar PDF do boleto")
+	}
+	return buf.Bytes(), "boleto-" + strings.ReplaceAll(record.DocumentNo, "/", "-") + ".pdf", nil
+}
+
+// boletoInstructions são as linhas de instrução impressas no boleto
+func boletoInstructions(record *models.Boleto) []string {
+	var lines []string
+	if record.DiscountAmount.IsPositive() && record.DiscountUntil != nil {
+		lines = append(lines, fmt.Sprintf("Até %s conceder desconto de R$ %s",
+			record.DiscountUntil.Format("02/01/2006"), strings.Replace(record.DiscountAmount.StringFixed(2), ".", ",", 1)))
+	}
+	if record.ProtestDays > 0 {
+		lines = append(lines, fmt.Sprintf("Sujeito a protesto %d dias corridos após o vencimento", record.ProtestDays))
+	}
+	return lines
+}
+
+// payerAddress formata o endereço do pagador em uma linha
+func payerAddress(slip billing.CNABSlip) string {
+	parts := make([]string, 0, 4)
+	for _, part := range []string{slip.Street, slip.Neighborhood, slip.City + "/" + slip.State, slip.ZipCode} {
+		if part = strings.Trim(strings.TrimSpace(part), "/"); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
+
+// joinDigit junta o número ao dígito verificador, quando houver
+func joinDigit(number, digit string) string {
+	if digit == "" {
+		return number
+	}
+	return number + "-" + digit
+}
+
+// instructionSlip monta o título da remessa que leva a instrução pendente ao banco
+func instructionSlip(instruction models.BoletoInstruction) billing.CNABSlip {
+	record := instruction.Boleto
+	slip := billing.CNABSlip{
+		Movement:       boleto.CNABMovement(instruction.Kind),
+		OurNumber:      record.OurNumber,
+		DocumentNo:     record.DocumentNo,
+		Reference:      strconv.Itoa(record.InstallmentID),
+		IssueDate:      record.IssueDate,
+		DueDate:        record.DueDate,
+		Amount:         record.Amount,
+		DiscountAmount: instruction.DiscountAmount,
+		ProtestDays:    instruction.ProtestDays,
+	}
+	if instruction.DiscountUntil != nil {
+		slip.DiscountUntil = *instruction.DiscountUntil
+	}
+	return slip
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/boleto"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	companies "ERP-ONSMART/backend/internal/modules/companies/models"
+	salesModels "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"bytes"
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBoletoProvider simula uma API de cobrança que registra na hora e só aceita cancelamento
+type fakeBoletoProvider struct {
+	instructions []boleto.Instruction
+}
+
+func (f *fakeBoletoProvider) Name() string {
+	return boleto.ProviderInter
+}
+
+func (f *fakeBoletoProvider) Register(ctx context.Context, title boleto.Title) (*boleto.Registration, error) {
+	return &boleto.Registration{
+		OurNumber:     "00987654321",
+		Barcode:       "07791000000500000000100000001234567800987654",
+		DigitableLine: "07790.00116 00000.001231 45678.009873 1 00000000050000",
+		ExternalID:    "00987654321",
+	}, nil
+}
+
+func (f *fakeBoletoProvider) Instruct(ctx context.Context, instruction boleto.Instruction) (*boleto.InstructionResult, error) {
+	if instruction.Kind != boleto.InstructionCancel {
+		return nil, errors.ErrBoletoInstructionNotSupported
+	}
+	f.instructions = append(f.instructions, instruction)
+	return &boleto.InstructionResult{}, nil
+}
+
+func boletoCandidate() models.RemittanceCandidate {
+	return models.RemittanceCandidate{
+		InstallmentID: 4321, InvoiceID: 8, InvoiceNo: "INV-2026-0008", Number: 1,
+		IssueDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Amount: money.FromInt(1000), AmountPaid: money.FromInt(250),
+		PersonType: "pj", Name: "Contato", CompanyName: "Cliente PJ Ltda", Document: "11.222.333/0001-81",
+		ZipCode: "01010-000", Street: "Rua A", StreetNumber: "10", City: "São Paulo", State: "SP",
+	}
+}
+
+func TestRegisterBoletosCNAB(t *testing.T) {
+	useBoletoConfig(t)
+	repo := newFakeCollectionRepository()
+	useFakeCollection(t, repo)
+	repo.boletoCandidates = []models.RemittanceCandidate{boletoCandidate()}
+
+	boletos, err := RegisterBoletos(context.Background(), models.BoletoInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+	require.Len(t, boletos, 1)
+	registered := boletos[0]
+	assert.Equal(t, boleto.ProviderCNAB, registered.Provider)
+	assert.Equal(t, models.BoletoStatusPending, registered.Status, "cobrança por arquivo aguarda o retorno")
+	assert.Equal(t, "INV-2026-0008/1", registered.DocumentNo)
+	assert.True(t, registered.Amount.Equal(money.FromInt(750)), "saldo em aberto da parcela")
+	assert.Len(t, registered.Barcode, 44)
+	assert.Equal(t, "237", registered.Barcode[:3])
+	assert.Equal(t, "financeiro", registered.CreatedBy)
+
+	repo.boletoCandidates = nil
+	_, err = RegisterBoletos(context.Background(), models.BoletoInput{InvoiceID: 8}, "financeiro")
+	assert.Equal(t, errors.ErrNoInstallmentsToRegister, err)
+}
+
+func TestRegisterOverdueBoletoWithLateCharges(t *testing.T) {
+	useBoletoConfig(t)
+	repo := newFakeCollectionRepository()
+	useFakeCollection(t, repo)
+	repo.boletoCandidates = []models.RemittanceCandidate{boletoCandidate()}
+	today := time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC)
+	invoiceAmountDue = func(ctx context.Context, invoiceID int, asOf time.Time) (*salesModels.AmountDue, error) {
+		return &salesModels.AmountDue{InvoiceID: invoiceID, AsOf: today, Items: []salesModels.LateCharge{
+			{Number: 1, DueDate: boletoCandidate().DueDate, DaysOverdue: 15, Principal: money.FromInt(750),
+				Fine: money.FromInt(15), Interest: money.MustParse("3.75")},
+		}}, nil
+	}
+
+	boletos, err := RegisterBoletos(context.Background(), models.BoletoInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+	require.Len(t, boletos, 1)
+	assert.Equal(t, "768.75", boletos[0].Amount.String(), "saldo da parcela com multa e juros")
+	assert.Equal(t, today, boletos[0].DueDate, "boleto atualizado vence no dia do cálculo")
+}
+
+func TestRegisterBoletosWithAPIProvider(t *testing.T) {
+	repo := newFakeCollectionRepository()
+	useFakeCollection(t, repo)
+	repo.boletoCandidates = []models.RemittanceCandidate{boletoCandidate()}
+
+	provider := &fakeBoletoProvider{}
+	original := newBoletoProvider
+	t.Cleanup(func() { newBoletoProvider = original })
+	newBoletoProvider = func(name string) (boleto.Provider, error) { return provider, nil }
+
+	boletos, err := RegisterBoletos(context.Background(), models.BoletoInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+	require.Len(t, boletos, 1)
+	assert.Equal(t, models.BoletoStatusRegistered, boletos[0].Status)
+	assert.Equal(t, "00987654321", boletos[0].ExternalID)
+
+	_, err = SendBoletoInstruction(context.Background(), boletos[0].ID,
+		models.BoletoInstructionInput{Kind: boleto.InstructionProtest, ProtestDays: 5}, "financeiro")
+	assert.Equal(t, errors.ErrBoletoInstructionNotSupported, err)
+	assert.Empty(t, repo.instructions, "instrução recusada não é gravada")
+
+	cancelled, err := SendBoletoInstruction(context.Background(), boletos[0].ID,
+		models.BoletoInstructionInput{Kind: boleto.InstructionCancel, Reason: "renegociado"}, "financeiro")
+	require.NoError(t, err)
+	assert.Equal(t, models.BoletoStatusCancelled, cancelled.Status)
+	require.Len(t, cancelled.Instructions, 1)
+	assert.Equal(t, models.InstructionStatusSent, cancelled.Instructions[0].Status)
+	require.Len(t, provider.instructions, 1)
+	assert.Equal(t, "00987654321", provider.instructions[0].ExternalID)
+	assert.Equal(t, []int{4321}, repo.clearedCharges, "cancelamento tira o boleto da parcela")
+
+	_, err = SendBoletoInstruction(context.Background(), boletos[0].ID,
+		models.BoletoInstructionInput{Kind: boleto.InstructionCancel}, "financeiro")
+	assert.Equal(t, errors.ErrBoletoNotActive, err)
+}
+
+func TestSendBoletoInstructionValidation(t *testing.T) {
+	useBoletoConfig(t)
+	repo := newFakeCollectionRepository()
+	useFakeCollection(t, repo)
+	repo.boletoCandidates = []models.RemittanceCandidate{boletoCandidate()}
+
+	boletos, err := RegisterBoletos(context.Background(), models.BoletoInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+	id := boletos[0].ID
+
+	afterDue := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+	beforeDue := time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)
+	invalid := []models.BoletoInstructionInput{
+		{Kind: "extend"},
+		{Kind: boleto.InstructionDiscount, DiscountAmount: money.FromInt(50)},
+		{Kind: boleto.InstructionDiscount, DiscountAmount: money.FromInt(750), DiscountUntil: &beforeDue},
+		{Kind: boleto.InstructionDiscount, DiscountAmount: money.FromInt(50), DiscountUntil: &afterDue},
+		{Kind: boleto.InstructionProtest},
+		{Kind: boleto.InstructionProtest, ProtestDays: 100},
+	}
+	for _, input := range invalid {
+		_, err := SendBoletoInstruction(context.Background(), id, input, "financeiro")
+		assert.True(t, stderrors.Is(err, errors.ErrInvalidBoletoInstruction), "%+v: %v", input, err)
+	}
+
+	updated, err := SendBoletoInstruction(context.Background(), id, models.BoletoInstructionInput{
+		Kind: boleto.InstructionDiscount, DiscountAmount: money.MustParse("50.00"), DiscountUntil: &beforeDue,
+	}, "financeiro")
+	require.NoError(t, err)
+	assert.True(t, updated.DiscountAmount.Equal(money.FromInt(50)))
+	require.Len(t, updated.Instructions, 1)
+	assert.Equal(t, models.InstructionStatusPending, updated.Instructions[0].Status, "segue na próxima remessa")
+
+	_, err = SendBoletoInstruction(context.Background(), 99, models.BoletoInstructionInput{Kind: boleto.InstructionCancel}, "")
+	assert.Equal(t, errors.ErrBoletoNotFound, err)
+}
+
+func TestGenerateRemittanceSendsPendingInstructions(t *testing.T) {
+	useBoletoConfig(t)
+	repo := newFakeCollectionRepository()
+	useFakeCollection(t, repo)
+	cnpj := "12.345.678/0001-95"
+	repo.company = companies.Company{ID: 1, Name: "Onsmart", LegalName: "Onsmart Comércio Ltda", Document: &cnpj}
+	repo.boletoCandidates = []models.RemittanceCandidate{boletoCandidate()}
+
+	boletos, err := RegisterBoletos(context.Background(), models.BoletoInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+	_, err = SendBoletoInstruction(context.Background(), boletos[0].ID,
+		models.BoletoInstructionInput{Kind: boleto.InstructionProtest, ProtestDays: 5}, "financeiro")
+	require.NoError(t, err)
+
+	remittance, err := GenerateRemittance(context.Background(), models.RemittanceInput{}, "financeiro")
+	require.NoError(t, err, "instruções pendentes bastam para gerar a remessa")
+	assert.Equal(t, 1, remittance.InstructionCount)
+	assert.Empty(t, remittance.Slips)
+
+	records := strings.Split(strings.TrimSpace(remittance.Content), "\r\n")
+	require.Len(t, records, 5, "header, lote, segmento P, trailer do lote e do arquivo")
+	assert.Equal(t, "P", records[2][13:14])
+	assert.Equal(t, "09", records[2][15:17], "movimento de protesto")
+
+	assert.Equal(t, models.InstructionStatusSent, repo.instructions[0].Status)
+	require.NotNil(t, repo.instructions[0].RemittanceID)
+	assert.Equal(t, remittance.ID, *repo.instructions[0].RemittanceID)
+}
+
+func TestRenderBoletoPDF(t *testing.T) {
+	useBoletoConfig(t)
+	repo := newFakeCollectionRepository()
+	useFakeCollection(t, repo)
+	repo.company = companies.Company{ID: 1, Name: "Onsmart", LegalName: "Onsmart Comércio Ltda"}
+	repo.boletoCandidates = []models.RemittanceCandidate{boletoCandidate()}
+
+	boletos, err := RegisterBoletos(context.Background(), models.BoletoInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+
+	data, filename, err := RenderBoletoPDF(context.Background(), boletos[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "boleto-INV-2026-0008-1.pdf", filename)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+
+	_, err = SendBoletoInstruction(context.Background(), boletos[0].ID,
+		models.BoletoInstructionInput{Kind: boleto.InstructionCancel}, "financeiro")
+	require.NoError(t, err)
+	_, _, err = RenderBoletoPDF(context.Background(), boletos[0].ID)
+	assert.Equal(t, errors.ErrBoletoNotActive, err)
+}
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/billing"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/modules/banking/repository"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	salesModels "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesService "ERP-ONSMART/backend/internal/modules/sales/service"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Dependências da cobrança registrada; substituídas nos testes
+var (
+	newCollectionRepository = repository.NewCollectionRepository
+	registerPayments        = salesService.CreatePaymentsBatch
+	splitInvoice            = salesService.ReplaceInvoiceInstallments
+	invoiceAmountDue        = salesService.GetInvoiceAmountDue
+)
+
+// Forma de pagamento dos pagamentos baixados pelo retorno de cobrança
+const paymentMethodBoleto = "boleto"
+
+// remittanceConfig lê da configuração a conta de cobrança e o convênio usados na remessa; a conta
+// é a mesma dos boletos das parcelas
+func remittanceConfig() billing.CNABRemittance {
+	return billing.CNABRemittance{
+		Boleto: billing.BoletoConfig{
+			BankCode: viper.GetString("BOLETO_BANK_CODE"),
+			Agency:   viper.GetString("BOLETO_AGENCY"),
+			Wallet:   viper.GetString("BOLETO_WALLET"),
+			Account:  viper.GetString("BOLETO_ACCOUNT"),
+		},
+		AgencyDigit:  viper.GetString("BOLETO_AGENCY_DIGIT"),
+		AccountDigit: viper.GetString("BOLETO_ACCOUNT_DIGIT"),
+		Agreement:    viper.GetString("BOLETO_AGREEMENT"),
+		BankName:     viper.GetString("BOLETO_BANK_NAME"),
+	}
+}
+
+// GenerateRemittance gera a remessa CNAB 240 com os boletos das parcelas em aberto ainda não
+// enviados ao banco e as instruções pendentes (das faturas informadas ou de todas). Faturas em
+// aberto sem parcelas recebem antes uma parcela única com boleto. A remessa, os boletos e as
+// instruções enviadas são gravados juntos.
+func GenerateRemittance(ctx context.Context, input models.RemittanceInput, createdBy string) (*models.BankRemittance, error) {
+	remittance := remittanceConfig()
+	if !remittance.Boleto.Enabled() {
+		return nil, errors.ErrChargeNotConfigured
+	}
+
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	company, err := repo.GetCompany(ctx)
+	if err != nil {
+		return nil, err
+	}
+	document := ""
+	if company.Document != nil {
+		document = onlyDigits(*company.Document)
+	}
+	if len(document) != 14 {
+		return nil, fmt.Errorf("%w: cadastre o CNPJ da empresa beneficiária", errors.ErrInvalidRemittance)
+	}
+	remittance.CompanyDocument = document
+	remittance.CompanyName = company.LegalName
+	if remittance.CompanyName == "" {
+		remittance.CompanyName = company.Name
+	}
+
+	txManager, err := newTxManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *models.BankRemittance
+	err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		pending, err := repo.GetInvoicesWithoutInstallments(ctx, input.InvoiceIDs)
+		if err != nil {
+			return err
+		}
+		for _, invoiceID := range pending {
+			if _, err := splitInvoice(ctx, invoiceID, salesModels.InstallmentPlan{Count: 1}); err != nil {
+				return err
+			}
+		}
+
+		now := localtime.Now(ctx)
+		candidates, err := repo.GetRemittanceCandidates(ctx, input.InvoiceIDs, localtime.Date(now))
+		if err != nil {
+			return err
+		}
+		instructions, err := repo.GetPendingInstructions(ctx, input.InvoiceIDs)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 && len(instructions) == 0 {
+			return errors.ErrNoSlipsToRemit
+		}
+
+		sequence, err := repo.NextRemittanceSequence(ctx)
+		if err != nil {
+			return err
+		}
+		remittance.Sequence = sequence
+		remittance.GeneratedAt = now
+
+		record := &models.BankRemittance{
+			Sequence:    sequence,
+			FileName:    fmt.Sprintf("CB%s%02d.REM", now.Format("0201"), sequence%100),
+			BankCode:    remittance.Boleto.BankCode,
+			SlipCount:   len(candidates),
+			TotalAmount: money.Zero,
+			CreatedBy:   createdBy,
+		}
+		for _, candidate := range candidates {
+			slip := remittanceSlip(candidate)
+			remittance.Slips = append(remittance.Slips, slip)
+			record.TotalAmount = record.TotalAmount.Add(slip.Amount)
+			record.Slips = append(record.Slips, models.BankSlip{
+				InvoiceID:     candidate.InvoiceID,
+				InstallmentID: candidate.InstallmentID,
+				OurNumber:     candidate.OurNumber,
+				DocumentNo:    slip.DocumentNo,
+				DueDate:       candidate.DueDate,
+				Amount:        slip.Amount,
+				Status:        models.SlipStatusRemitted,
+			})
+		}
+		instructionIDs := make([]int, 0, len(instructions))
+		for _, instruction := range instructions {
+			remittance.Slips = append(remittance.Slips, instructionSlip(instruction))
+			instructionIDs = append(instructionIDs, instruction.ID)
+		}
+		record.InstructionCount = len(instructions)
+
+		content, err := billing.WriteCNAB240Remittance(remittance)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errors.ErrInvalidRemittance, err)
+		}
+		record.Content = string(content)
+
+		if err := repo.CreateRemittance(ctx, record); err != nil {
+			return err
+		}
+		if err := repo.MarkInstructionsSent(ctx, instructionIDs, record.ID); err != nil {
+			return err
+		}
+		result = record
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// remittanceSlip monta o título da remessa com o saldo em aberto da parcela; o número do
+// documento é o número da fatura com o da parcela e o uso da empresa guarda o id da parcela
+func remittanceSlip(candidate models.RemittanceCandidate) billing.CNABSlip {
+	payerName := candidate.Name
+	if candidate.PersonType == "pj" && candidate.CompanyName != "" {
+		payerName = candidate.CompanyName
+	}
+	street := candidate.Street
+	if candidate.StreetNumber != "" {
+		street += ", " + candidate.StreetNumber
+	}
+	if candidate.Complement != "" {
+		street += " " + candidate.Complement
+	}
+
+	return billing.CNABSlip{
+		OurNumber:     candidate.OurNumber,
+		DocumentNo:    fmt.Sprintf("%s/%d", candidate.InvoiceNo, candidate.Number),
+		Reference:     strconv.Itoa(candidate.InstallmentID),
+		IssueDate:     candidate.IssueDate,
+		DueDate:       candidate.DueDate,
+		Amount:        candidate.Amount.Sub(candidate.AmountPaid),
+		PayerDocument: candidate.Document,
+		PayerName:     payerName,
+		Street:        street,
+		Neighborhood:  candidate.Neighborhood,
+		ZipCode:       candidate.ZipCode,
+		City:          candidate.City,
+		State:         candidate.State,
+	}
+}
+
+// GetRemittance retorna a remessa com seus boletos
+func GetRemittance(ctx context.Context, id int) (*models.BankRemittance, error) {
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRemittance(ctx, id)
+}
+
+// ListRemittances lista as remessas geradas
+func ListRemittances(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListRemittances(ctx, params)
+}
+
+// ProcessBankReturn processa o arquivo de retorno CNAB 240: confirma ou recusa o registro dos
+// boletos, baixa as liquidações como pagamentos das faturas e guarda as tarifas cobradas pelo
+// banco, que o razão lança como despesa bancária. Títulos que não puderem ser baixados ficam no
+// relatório com o motivo, sem impedir os demais; o mesmo arquivo não é processado duas vezes.
+func ProcessBankReturn(ctx context.Context, fileName string, content []byte, processedBy string) (*models.BankReturn, error) {
+	records, err := billing.ParseCNAB240Return(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.ErrEmptyBankReturn
+	}
+
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(content)
+	fileHash := hex.EncodeToString(hash[:])
+	exists, err := repo.ReturnExists(ctx, fileHash)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.ErrBankReturnDuplicate
+	}
+
+	txManager, err := newTxManager()
+	if err != nil {
+		return nil, err
+	}
+
+	bankReturn := &models.BankReturn{
+		FileName:    fileName,
+		FileHash:    fileHash,
+		RecordCount: len(records),
+		PaidAmount:  money.Zero,
+		FeeAmount:   money.Zero,
+		ProcessedBy: processedBy,
+	}
+	err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, record := range records {
+			item, err := processReturnRecord(ctx, repo, fileName, record)
+			if err != nil {
+				return err
+			}
+
+			switch item.Result {
+			case models.ReturnResultPaid:
+				bankReturn.PaidCount++
+				bankReturn.PaidAmount = bankReturn.PaidAmount.Add(item.PaidAmount)
+			case models.ReturnResultRejected:
+				bankReturn.RejectedCount++
+			case models.ReturnResultError:
+				bankReturn.ErrorCount++
+			}
+			bankReturn.FeeAmount = bankReturn.FeeAmount.Add(item.FeeAmount)
+			bankReturn.Items = append(bankReturn.Items, item)
+		}
+		return repo.CreateReturn(ctx, bankReturn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bankReturn, nil
+}
+
+// processReturnRecord aplica a ocorrência do título ao boleto. Só falhas de acesso ao banco de
+// dados retornam erro; o resto vira o resultado do item.
+func processReturnRecord(ctx context.Context, repo repository.CollectionRepository, fileName string, record billing.CNABReturnRecord) (models.BankReturnItem, error) {
+	item := models.BankReturnItem{
+		Line:                  record.Line,
+		Occurrence:            record.Occurrence,
+		OccurrenceDescription: billing.CNABOccurrenceDescription(record.Occurrence),
+		OurNumber:             strings.TrimSpace(record.OurNumber),
+		DocumentNo:            strings.TrimSpace(record.DocumentNo),
+		Amount:                record.Amount,
+		PaidAmount:            money.Zero,
+		FeeAmount:             record.Fee,
+	}
+	if settled := record.SettledAt(); !settled.IsZero() {
+		item.OccurredAt = &settled
+	}
+
+	slip, err := repo.FindSlipByOurNumber(ctx, item.OurNumber)
+	if err != nil {
+		return item, err
+	}
+	if slip == nil {
+		item.Result = models.ReturnResultError
+		item.Message = "boleto não encontrado nas remessas"
+		return item, nil
+	}
+	item.SlipID = &slip.ID
+	item.InvoiceID = &slip.InvoiceID
+
+	fields := map[string]interface{}{
+		"last_occurrence": record.Occurrence,
+		"last_message":    item.OccurrenceDescription,
+	}
+	if record.Fee.IsPositive() {
+		fields["fee_amount"] = slip.FeeAmount.Add(record.Fee)
+	}
+
+	switch record.Occurrence {
+	case billing.CNABEntryConfirmed:
+		item.Result = models.ReturnResultRegistered
+		if slip.Status == models.SlipStatusRemitted {
+			fields["status"] = models.SlipStatusRegistered
+		}
+		if err := repo.MarkBoletoRegistered(ctx, slip.InstallmentID); err != nil {
+			return item, err
+		}
+
+	case billing.CNABEntryRejected:
+		item.Result = models.ReturnResultRejected
+		item.Message = item.OccurrenceDescription
+		if reasons := strings.TrimSpace(record.Reasons); reasons != "" {
+			item.Message += " (motivos " + reasons + ")"
+		}
+		fields["status"] = models.SlipStatusRejected
+		fields["last_message"] = item.Message
+
+	case billing.CNABLiquidation, billing.CNABLiquidationAfterOff:
+		if slip.Status == models.SlipStatusPaid {
+			item.Result = models.ReturnResultIgnored
+			item.Message = "boleto já baixado como pago"
+			break
+		}
+		paymentID, message, err := registerSlipPayment(ctx, slip, fileName, record)
+		if err != nil {
+			return item, err
+		}
+		if message != "" {
+			item.Result = models.ReturnResultError
+			item.Message = message
+			break
+		}
+		item.Result = models.ReturnResultPaid
+		item.PaymentID = &paymentID
+		item.PaidAmount = slipPaidAmount(record)
+		fields["status"] = models.SlipStatusPaid
+		fields["paid_amount"] = item.PaidAmount
+		fields["paid_at"] = paymentDate(ctx, record)
+		fields["payment_id"] = paymentID
+
+	case billing.CNABWrittenOff:
+		item.Result = models.ReturnResultWrittenOff
+		if slip.Status != models.SlipStatusPaid {
+			fields["status"] = models.SlipStatusWrittenOff
+		}
+
+	case billing.CNABFeeDebit:
+		item.Result = models.ReturnResultFee
+
+	default:
+		item.Result = models.ReturnResultIgnored
+	}
+
+	if err := repo.UpdateSlip(ctx, slip.ID, fields); err != nil {
+		return item, err
+	}
+	return item, nil
+}
+
+// slipPaidAmount é o valor pago pelo pagador (com juros e descontos); bancos que não o informam
+// no segmento U têm o valor nominal do título como pago
+func slipPaidAmount(record billing.CNABReturnRecord) money.Decimal {
+	if record.PaidAmount.IsPositive() {
+		return record.PaidAmount
+	}
+	return record.Amount
+}
+
+// paymentDate é a data do crédito ou, sem ela no retorno, a de hoje
+func paymentDate(ctx context.Context, record billing.CNABReturnRecord) time.Time {
+	if settled := record.SettledAt(); !settled.IsZero() {
+		return settled
+	}
+	return localtime.Today(ctx)
+}
+
+// registerSlipPayment baixa a liquidação como pagamento da fatura do boleto. Um pagamento
+// recusado (fatura cancelada ou já quitada, por exemplo) volta como mensagem, sem erro.
+func registerSlipPayment(ctx context.Context, slip *models.BankSlip, fileName string, record billing.CNABReturnRecord) (int, string, error) {
+	result, err := registerPayments(ctx, dtos.PaymentBatchDTO{
+		Atomic: true,
+		Items: []dtos.PaymentCreateDTO{{
+			InvoiceID:     slip.InvoiceID,
+			Amount:        slipPaidAmount(record),
+			PaymentDate:   paymentDate(ctx, record),
+			PaymentMethod: paymentMethodBoleto,
+			Reference:     "BOLETO-" + slip.OurNumber,
+			Notes:         fmt.Sprintf("Retorno de cobrança %s, linha %d", fileName, record.Line),
+		}},
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	if len(result.Items) == 0 {
+		return 0, "pagamento não registrado", nil
+	}
+	registered := result.Items[0]
+	if registered.Status != salesModels.BatchItemOK {
+		message := "pagamento recusado"
+		if registered.Error != nil {
+			message += ": " + registered.Error.Message
+		}
+		return 0, message, nil
+	}
+	return registered.ID, "", nil
+}
+
+// GetBankReturn retorna o retorno processado com o resultado de cada título
+func GetBankReturn(ctx context.Context, id int) (*models.BankReturn, error) {
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetReturn(ctx, id)
+}
+
+// ListBankReturns lista os retornos processados
+func ListBankReturns(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := newCollectionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListReturns(ctx, params)
+}
+
+func onlyDigits(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
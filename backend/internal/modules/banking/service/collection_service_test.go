@@ -0,0 +1,423 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/modules/banking/repository"
+	companies "ERP-ONSMART/backend/internal/modules/companies/models"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	salesModels "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCollectionRepository guarda remessas, boletos e retornos em memória
+type fakeCollectionRepository struct {
+	company     companies.Company
+	unsplit     []int
+	candidates  []models.RemittanceCandidate
+	remittances []*models.BankRemittance
+	slips       map[string]*models.BankSlip
+	returns     []*models.BankReturn
+
+	boletoCandidates []models.RemittanceCandidate
+	boletos          map[int]*models.Boleto
+	instructions     []*models.BoletoInstruction
+	clearedCharges   []int
+	confirmed        []int
+}
+
+func newFakeCollectionRepository() *fakeCollectionRepository {
+	return &fakeCollectionRepository{slips: map[string]*models.BankSlip{}, boletos: map[int]*models.Boleto{}}
+}
+
+func (f *fakeCollectionRepository) GetCompany(ctx context.Context) (*companies.Company, error) {
+	return &f.company, nil
+}
+
+func (f *fakeCollectionRepository) GetInvoicesWithoutInstallments(ctx context.Context, invoiceIDs []int) ([]int, error) {
+	return f.unsplit, nil
+}
+
+func (f *fakeCollectionRepository) GetRemittanceCandidates(ctx context.Context, invoiceIDs []int, from time.Time) ([]models.RemittanceCandidate, error) {
+	return f.candidates, nil
+}
+
+func (f *fakeCollectionRepository) NextRemittanceSequence(ctx context.Context) (int, error) {
+	return len(f.remittances) + 1, nil
+}
+
+func (f *fakeCollectionRepository) CreateRemittance(ctx context.Context, remittance *models.BankRemittance) error {
+	remittance.ID = len(f.remittances) + 1
+	f.remittances = append(f.remittances, remittance)
+	return nil
+}
+
+func (f *fakeCollectionRepository) GetRemittance(ctx context.Context, id int) (*models.BankRemittance, error) {
+	return nil, errors.ErrBankRemittanceNotFound
+}
+
+func (f *fakeCollectionRepository) ListRemittances(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	return nil, nil
+}
+
+func (f *fakeCollectionRepository) FindSlipByOurNumber(ctx context.Context, ourNumber string) (*models.BankSlip, error) {
+	slip, ok := f.slips[strings.TrimLeft(ourNumber, "0")]
+	if !ok {
+		return nil, nil
+	}
+	copied := *slip
+	return &copied, nil
+}
+
+func (f *fakeCollectionRepository) UpdateSlip(ctx context.Context, id int, fields map[string]interface{}) error {
+	for _, slip := range f.slips {
+		if slip.ID != id {
+			continue
+		}
+		if status, ok := fields["status"].(string); ok {
+			slip.Status = status
+		}
+		if fee, ok := fields["fee_amount"].(money.Decimal); ok {
+			slip.FeeAmount = fee
+		}
+		if paymentID, ok := fields["payment_id"].(int); ok {
+			slip.PaymentID = &paymentID
+		}
+		slip.LastOccurrence, _ = fields["last_occurrence"].(string)
+		slip.LastMessage, _ = fields["last_message"].(string)
+	}
+	return nil
+}
+
+func (f *fakeCollectionRepository) ReturnExists(ctx context.Context, fileHash string) (bool, error) {
+	for _, r := range f.returns {
+		if r.FileHash == fileHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeCollectionRepository) CreateReturn(ctx context.Context, bankReturn *models.BankReturn) error {
+	bankReturn.ID = len(f.returns) + 1
+	f.returns = append(f.returns, bankReturn)
+	return nil
+}
+
+func (f *fakeCollectionRepository) GetReturn(ctx context.Context, id int) (*models.BankReturn, error) {
+	return nil, errors.ErrBankReturnNotFound
+}
+
+func (f *fakeCollectionRepository) ListReturns(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	return nil, nil
+}
+
+func (f *fakeCollectionRepository) GetBoletoCandidates(ctx context.Context, invoiceID int, numbers []int) ([]models.RemittanceCandidate, error) {
+	return f.boletoCandidates, nil
+}
+
+func (f *fakeCollectionRepository) GetBoletoPayer(ctx context.Context, installmentID int) (*models.RemittanceCandidate, error) {
+	for _, candidate := range f.boletoCandidates {
+		if candidate.InstallmentID == installmentID {
+			return &candidate, nil
+		}
+	}
+	return nil, errors.ErrInstallmentNotFound
+}
+
+func (f *fakeCollectionRepository) CreateBoleto(ctx context.Context, boleto *models.Boleto) error {
+	boleto.ID = len(f.boletos) + 1
+	copied := *boleto
+	f.boletos[boleto.ID] = &copied
+	return nil
+}
+
+func (f *fakeCollectionRepository) GetBoleto(ctx context.Context, id int) (*models.Boleto, error) {
+	boleto, ok := f.boletos[id]
+	if !ok {
+		return nil, errors.ErrBoletoNotFound
+	}
+	copied := *boleto
+	copied.Instructions = nil
+	for _, instruction := range f.instructions {
+		if instruction.BoletoID == id {
+			copied.Instructions = append(copied.Instructions, *instruction)
+		}
+	}
+	return &copied, nil
+}
+
+func (f *fakeCollectionRepository) ListBoletos(ctx context.Context, filter models.BoletoFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	return nil, nil
+}
+
+func (f *fakeCollectionRepository) UpdateBoleto(ctx context.Context, id int, fields map[string]interface{}) error {
+	boleto := f.boletos[id]
+	if status, ok := fields["status"].(string); ok {
+		boleto.Status = status
+	}
+	if discount, ok := fields["discount_amount"].(money.Decimal); ok {
+		boleto.DiscountAmount = discount
+	}
+	if until, ok := fields["discount_until"].(time.Time); ok {
+		boleto.DiscountUntil = &until
+	}
+	if days, ok := fields["protest_days"].(int); ok {
+		boleto.ProtestDays = days
+	}
+	return nil
+}
+
+func (f *fakeCollectionRepository) ClearInstallmentCharge(ctx context.Context, installmentID int) error {
+	f.clearedCharges = append(f.clearedCharges, installmentID)
+	return nil
+}
+
+func (f *fakeCollectionRepository) CreateBoletoInstruction(ctx context.Context, instruction *models.BoletoInstruction) error {
+	instruction.ID = len(f.instructions) + 1
+	f.instructions = append(f.instructions, instruction)
+	return nil
+}
+
+func (f *fakeCollectionRepository) GetPendingInstructions(ctx context.Context, invoiceIDs []int) ([]models.BoletoInstruction, error) {
+	var pending []models.BoletoInstruction
+	for _, instruction := range f.instructions {
+		if instruction.Status == models.InstructionStatusPending {
+			copied := *instruction
+			copied.Boleto = f.boletos[instruction.BoletoID]
+			pending = append(pending, copied)
+		}
+	}
+	return pending, nil
+}
+
+func (f *fakeCollectionRepository) MarkInstructionsSent(ctx context.Context, ids []int, remittanceID int) error {
+	for _, instruction := range f.instructions {
+		for _, id := range ids {
+			if instruction.ID == id {
+				instruction.Status = models.InstructionStatusSent
+				instruction.RemittanceID = &remittanceID
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeCollectionRepository) MarkBoletoRegistered(ctx context.Context, installmentID int) error {
+	f.confirmed = append(f.confirmed, installmentID)
+	return nil
+}
+
+type inlineTx struct{}
+
+func (inlineTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// useFakeCollection troca o repositório, a transação e as chamadas ao módulo de vendas
+func useFakeCollection(t *testing.T, repo *fakeCollectionRepository) *[]dtos.PaymentCreateDTO {
+	originalRepo, originalTx, originalPayments, originalSplit := newCollectionRepository, newTxManager, registerPayments, splitInvoice
+	originalAmountDue := invoiceAmountDue
+	t.Cleanup(func() {
+		newCollectionRepository, newTxManager, registerPayments, splitInvoice = originalRepo, originalTx, originalPayments, originalSplit
+		invoiceAmountDue = originalAmountDue
+	})
+	invoiceAmountDue = noLateCharges
+
+	newCollectionRepository = func() (repository.CollectionRepository, error) { return repo, nil }
+	newTxManager = func() (db.TxManager, error) { return inlineTx{}, nil }
+
+	var payments []dtos.PaymentCreateDTO
+	registerPayments = func(ctx context.Context, req dtos.PaymentBatchDTO) (*salesModels.BatchResult, error) {
+		item := req.Items[0]
+		if item.InvoiceID == 99 {
+			return &salesModels.BatchResult{Items: []salesModels.BatchItemResult{
+				salesModels.BatchItemFailed(0, errors.ErrAllocationExceedsBalance),
+			}}, nil
+		}
+		payments = append(payments, item)
+		return &salesModels.BatchResult{Items: []salesModels.BatchItemResult{
+			salesModels.BatchItemSucceeded(0, 500+len(payments), ""),
+		}}, nil
+	}
+	splitInvoice = func(ctx context.Context, invoiceID int, plan salesModels.InstallmentPlan) ([]salesModels.InvoiceInstallment, error) {
+		t.Fatalf("fatura %d dividida sem ser esperada", invoiceID)
+		return nil, nil
+	}
+	return &payments
+}
+
+func useBoletoConfig(t *testing.T) {
+	values := map[string]string{
+		"BOLETO_BANK_CODE": "237", "BOLETO_AGENCY": "1234", "BOLETO_WALLET": "9", "BOLETO_ACCOUNT": "56789",
+	}
+	for key, value := range values {
+		viper.Set(key, value)
+	}
+	t.Cleanup(func() {
+		for key := range values {
+			viper.Set(key, "")
+		}
+	})
+}
+
+func TestGenerateRemittance(t *testing.T) {
+	useBoletoConfig(t)
+	repo := newFakeCollectionRepository()
+	useFakeCollection(t, repo)
+
+	cnpj := "12.345.678/0001-95"
+	repo.company = companies.Company{ID: 1, Name: "Onsmart", LegalName: "Onsmart Comércio Ltda", Document: &cnpj}
+	repo.unsplit = []int{8}
+	repo.candidates = []models.RemittanceCandidate{{
+		InstallmentID: 4321, InvoiceID: 8, InvoiceNo: "INV-2026-0008", Number: 1,
+		IssueDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Amount: money.FromInt(1000), AmountPaid: money.FromInt(250), OurNumber: "00000004321",
+		PersonType: "pj", Name: "Contato", CompanyName: "Cliente PJ Ltda", Document: "11222333000181",
+		ZipCode: "01010000", Street: "Rua A", StreetNumber: "10", City: "São Paulo", State: "SP",
+	}}
+
+	var split []int
+	splitInvoice = func(ctx context.Context, invoiceID int, plan salesModels.InstallmentPlan) ([]salesModels.InvoiceInstallment, error) {
+		assert.Equal(t, 1, plan.Count)
+		split = append(split, invoiceID)
+		return nil, nil
+	}
+
+	remittance, err := GenerateRemittance(context.Background(), models.RemittanceInput{}, "financeiro")
+	require.NoError(t, err)
+	assert.Equal(t, []int{8}, split, "fatura sem parcelas recebe parcela única")
+	assert.Equal(t, 1, remittance.Sequence)
+	assert.Equal(t, "237", remittance.BankCode)
+	assert.Equal(t, "financeiro", remittance.CreatedBy)
+	assert.True(t, remittance.TotalAmount.Equal(money.FromInt(750)), "saldo em aberto da parcela")
+	require.Len(t, remittance.Slips, 1)
+	assert.Equal(t, models.SlipStatusRemitted, remittance.Slips[0].Status)
+	assert.Equal(t, "INV-2026-0008/1", remittance.Slips[0].DocumentNo)
+
+	records := strings.Split(strings.TrimSpace(remittance.Content), "\r\n")
+	require.Len(t, records, 6)
+	assert.Equal(t, "CLIENTE PJ LTDA", strings.TrimSpace(records[3][33:73]))
+	assert.Equal(t, "RUA A, 10", strings.TrimSpace(records[3][73:113]))
+
+	repo.candidates = nil
+	repo.unsplit = nil
+	_, err = GenerateRemittance(context.Background(), models.RemittanceInput{}, "financeiro")
+	assert.Equal(t, errors.ErrNoSlipsToRemit, err)
+}
+
+func TestGenerateRemittanceRequiresConfiguration(t *testing.T) {
+	repo := newFakeCollectionRepository()
+	useFakeCollection(t, repo)
+
+	_, err := GenerateRemittance(context.Background(), models.RemittanceInput{}, "")
+	assert.Equal(t, errors.ErrChargeNotConfigured, err)
+
+	useBoletoConfig(t)
+	repo.company = companies.Company{ID: 1, Name: "Sem CNPJ"}
+	_, err = GenerateRemittance(context.Background(), models.RemittanceInput{}, "")
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidRemittance), "empresa sem CNPJ: %v", err)
+}
+
+func TestProcessBankReturn(t *testing.T) {
+	repo := newFakeCollectionRepository()
+	payments := useFakeCollection(t, repo)
+	for i, number := range []string{"4321", "4322", "4323", "4324"} {
+		repo.slips[number] = &models.BankSlip{ID: i + 1, InvoiceID: 10 + i, InstallmentID: 100 + i, OurNumber: "0000000" + number,
+			Status: models.SlipStatusRemitted, FeeAmount: money.Zero}
+	}
+	repo.slips["4323"].InvoiceID = 99
+
+	content := strings.Join([]string{
+		cnabRecord(map[int]string{1: "23700000"}),
+		// Registro confirmado
+		cnabRecord(map[int]string{1: "23700013", 14: "T", 16: "02", 38: "00000004321", 199: "000000000000150"}),
+		cnabRecord(map[int]string{1: "23700013", 14: "U", 16: "02", 138: "06032026"}),
+		// Liquidação com juros: o pagamento é o valor pago, na data do crédito
+		cnabRecord(map[int]string{1: "23700013", 14: "T", 16: "06", 38: "00000004322", 82: "000000000075000", 199: "000000000000250"}),
+		cnabRecord(map[int]string{1: "23700013", 14: "U", 16: "06", 78: "000000000075500", 93: "000000000075250", 138: "01042026", 146: "02042026"}),
+		// Liquidação recusada pelas vendas
+		cnabRecord(map[int]string{1: "23700013", 14: "T", 16: "06", 38: "00000004323", 82: "000000000010000"}),
+		cnabRecord(map[int]string{1: "23700013", 14: "U", 16: "06", 78: "000000000010000", 138: "01042026"}),
+		// Entrada rejeitada
+		cnabRecord(map[int]string{1: "23700013", 14: "T", 16: "03", 38: "00000004324", 214: "0845"}),
+		cnabRecord(map[int]string{1: "23700013", 14: "U", 16: "03", 138: "06032026"}),
+		// Nosso número que não está em nenhuma remessa
+		cnabRecord(map[int]string{1: "23700013", 14: "T", 16: "06", 38: "00000009999", 82: "000000000001000"}),
+		cnabRecord(map[int]string{1: "23700013", 14: "U", 16: "06", 78: "000000000001000", 138: "01042026"}),
+		cnabRecord(map[int]string{1: "23799999"}),
+	}, "\r\n")
+
+	bankReturn, err := ProcessBankReturn(context.Background(), "CB020401.RET", []byte(content), "financeiro")
+	require.NoError(t, err)
+	require.Len(t, bankReturn.Items, 5)
+	assert.Equal(t, 5, bankReturn.RecordCount)
+	assert.Equal(t, 1, bankReturn.PaidCount)
+	assert.True(t, bankReturn.PaidAmount.Equal(money.MustParse("755")))
+	assert.True(t, bankReturn.FeeAmount.Equal(money.MustParse("4")))
+	assert.Equal(t, 1, bankReturn.RejectedCount)
+	assert.Equal(t, 2, bankReturn.ErrorCount)
+
+	assert.Equal(t, models.ReturnResultRegistered, bankReturn.Items[0].Result)
+	assert.Equal(t, models.SlipStatusRegistered, repo.slips["4321"].Status)
+	assert.True(t, repo.slips["4321"].FeeAmount.Equal(money.MustParse("1.50")))
+	assert.Equal(t, []int{100}, repo.confirmed, "entrada confirmada registra o boleto da parcela")
+
+	paid := bankReturn.Items[1]
+	assert.Equal(t, models.ReturnResultPaid, paid.Result)
+	require.NotNil(t, paid.PaymentID)
+	assert.Equal(t, 501, *paid.PaymentID)
+	assert.Equal(t, models.SlipStatusPaid, repo.slips["4322"].Status)
+	require.Len(t, *payments, 1)
+	payment := (*payments)[0]
+	assert.Equal(t, 11, payment.InvoiceID)
+	assert.True(t, payment.Amount.Equal(money.MustParse("755")))
+	assert.Equal(t, time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC), payment.PaymentDate)
+	assert.Equal(t, "boleto", payment.PaymentMethod)
+	assert.Equal(t, "BOLETO-00000004322", payment.Reference)
+
+	assert.Equal(t, models.ReturnResultError, bankReturn.Items[2].Result)
+	assert.Contains(t, bankReturn.Items[2].Message, "pagamento recusado")
+	assert.Equal(t, models.SlipStatusRemitted, repo.slips["4323"].Status)
+
+	assert.Equal(t, models.ReturnResultRejected, bankReturn.Items[3].Result)
+	assert.Equal(t, "Entrada rejeitada (motivos 0845)", bankReturn.Items[3].Message)
+	assert.Equal(t, models.SlipStatusRejected, repo.slips["4324"].Status)
+
+	assert.Equal(t, models.ReturnResultError, bankReturn.Items[4].Result)
+	assert.Nil(t, bankReturn.Items[4].SlipID)
+
+	_, err = ProcessBankReturn(context.Background(), "CB020401.RET", []byte(content), "financeiro")
+	assert.Equal(t, errors.ErrBankReturnDuplicate, err)
+}
+
+func TestProcessBankReturnSkipsPaidSlips(t *testing.T) {
+	repo := newFakeCollectionRepository()
+	payments := useFakeCollection(t, repo)
+	repo.slips["4321"] = &models.BankSlip{ID: 1, InvoiceID: 10, OurNumber: "00000004321", Status: models.SlipStatusPaid, FeeAmount: money.Zero}
+
+	content := strings.Join([]string{
+		cnabRecord(map[int]string{1: "23700000"}),
+		cnabRecord(map[int]string{1: "23700013", 14: "T", 16: "17", 38: "00000004321", 82: "000000000010000"}),
+		cnabRecord(map[int]string{1: "23700013", 14: "U", 16: "17", 78: "000000000010000", 138: "01042026"}),
+	}, "\r\n")
+
+	bankReturn, err := ProcessBankReturn(context.Background(), "retorno.ret", []byte(content), "")
+	require.NoError(t, err)
+	assert.Equal(t, models.ReturnResultIgnored, bankReturn.Items[0].Result)
+	assert.Empty(t, *payments, "boleto já pago não gera outro pagamento")
+
+	_, err = ProcessBankReturn(context.Background(), "vazio.ret", []byte(cnabRecord(map[int]string{1: "23700000"})), "")
+	assert.Equal(t, errors.ErrEmptyBankReturn, err)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Pesos usados na pontuação das sugestões de conciliação
+const (
+	scoreExactAmount   = 50
+	scoreCloseAmount   = 25
+	scoreSameDay       = 30
+	scoreWithin3Days   = 20
+	scoreWithin7Days   = 10
+	scoreReferenceHit  = 20
+	minSuggestionScore = 50
+
+	// Tolerância relativa para considerar valores próximos (tarifas, arredondamentos)
+	amountTolerance = 0.01
+)
+
+// SuggestMatches pontua os candidatos para um lançamento e retorna os que atingem a pontuação mínima,
+// do mais para o menos provável. Créditos são comparados a pagamentos e débitos a contas a pagar.
+func SuggestMatches(line models.BankStatementLine, candidates []models.MatchCandidate) []models.MatchSuggestion {
+	expectedType := models.MatchTypePayment
+	if line.Amount < 0 {
+		expectedType = models.MatchTypeSupplierBill
+	}
+
+	var suggestions []models.MatchSuggestion
+	for _, candidate := range candidates {
+		if candidate.Type != expectedType {
+			continue
+		}
+
+		score, reasons := scoreCandidate(line, candidate)
+		if score < minSuggestionScore {
+			continue
+		}
+
+		suggestions = append(suggestions, models.MatchSuggestion{
+			LineID:    line.ID,
+			Candidate: candidate,
+			Score:     score,
+			Reasons:   reasons,
+		})
+	}
+
+	sortSuggestionsByScore(suggestions)
+
+	return suggestions
+}
+
+// scoreCandidate calcula a pontuação de um candidato por valor, data e referência
+func scoreCandidate(line models.BankStatementLine, candidate models.MatchCandidate) (int, []string) {
+	var (
+		score   int
+		reasons []string
+	)
+
+	lineAmount := math.Abs(line.Amount)
+	diff := math.Abs(lineAmount - candidate.Amount)
+	switch {
+	case diff < 0.005:
+		score += scoreExactAmount
+		reasons = append(reasons, "valor idêntico")
+	case candidate.Amount > 0 && diff/candidate.Amount <= amountTolerance:
+		score += scoreCloseAmount
+		reasons = append(reasons, "valor aproximado")
+	default:
+		// Sem correspondência de valor a sugestão não é confiável
+		return 0, nil
+	}
+
+	if !candidate.Date.IsZero() {
+		days := math.Abs(truncateToDay(line.TransactionDate).Sub(truncateToDay(candidate.Date)).Hours() / 24)
+		switch {
+		case days < 1:
+			score += scoreSameDay
+			reasons = append(reasons, "mesma data")
+		case days <= 3:
+			score += scoreWithin3Days
+			reasons = append(reasons, "data em até 3 dias")
+		case days <= 7:
+			score += scoreWithin7Days
+			reasons = append(reasons, "data em até 7 dias")
+		}
+	}
+
+	if referenceMatches(line, candidate) {
+		score += scoreReferenceHit
+		reasons = append(reasons, "referência encontrada")
+	}
+
+	return score, reasons
+}
+
+// referenceMatches verifica se o número do documento ou a referência aparecem no lançamento
+func referenceMatches(line models.BankStatementLine, candidate models.MatchCandidate) bool {
+	haystack := strings.ToUpper(line.Description + " " + line.Reference)
+	for _, needle := range []string{candidate.DocumentNo, candidate.Reference} {
+		needle = strings.ToUpper(strings.TrimSpace(needle))
+		if len(needle) >= 3 && strings.Contains(haystack, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortSuggestionsByScore(suggestions []models.MatchSuggestion) {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+}
+
+func candidateKey(candidate models.MatchCandidate) string {
+	return candidate.Type + ":" + strconv.Itoa(candidate.ID)
+}
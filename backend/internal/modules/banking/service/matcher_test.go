@@ -0,0 +1,67 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestMatchesRanksByScore(t *testing.T) {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	line := models.BankStatementLine{
+		ID:              1,
+		TransactionDate: day,
+		Amount:          1500,
+		Description:     "PIX RECEBIDO INV-2025-0001",
+	}
+
+	candidates := []models.MatchCandidate{
+		{Type: models.MatchTypePayment, ID: 10, Amount: 1500, Date: day.AddDate(0, 0, -5)},
+		{Type: models.MatchTypePayment, ID: 11, Amount: 1500, Date: day, DocumentNo: "INV-2025-0001"},
+		{Type: models.MatchTypePayment, ID: 12, Amount: 900, Date: day},
+		{Type: models.MatchTypeSupplierBill, ID: 13, Amount: 1500, Date: day},
+	}
+
+	suggestions := SuggestMatches(line, candidates)
+	require.Len(t, suggestions, 2)
+	assert.Equal(t, 11, suggestions[0].Candidate.ID)
+	assert.Equal(t, 100, suggestions[0].Score)
+	assert.Equal(t, 10, suggestions[1].Candidate.ID)
+	assert.Equal(t, 60, suggestions[1].Score)
+}
+
+func TestSuggestMatchesDebitUsesSupplierBills(t *testing.T) {
+	day := time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)
+	line := models.BankStatementLine{ID: 2, TransactionDate: day, Amount: -320.50}
+
+	candidates := []models.MatchCandidate{
+		{Type: models.MatchTypePayment, ID: 1, Amount: 320.50, Date: day},
+		{Type: models.MatchTypeSupplierBill, ID: 2, Amount: 322, Date: day.AddDate(0, 0, 2)},
+	}
+
+	suggestions := SuggestMatches(line, candidates)
+	require.Len(t, suggestions, 0, "valor aproximado com data distante não atinge a pontuação mínima")
+
+	candidates[1].Amount = 320.50
+	suggestions = SuggestMatches(line, candidates)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, models.MatchTypeSupplierBill, suggestions[0].Candidate.Type)
+}
+
+func TestAssignBestMatchesUsesEachCandidateOnce(t *testing.T) {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	lines := []models.BankStatementLine{
+		{ID: 1, TransactionDate: day.AddDate(0, 0, 2), Amount: 100},
+		{ID: 2, TransactionDate: day, Amount: 100},
+	}
+	candidates := []models.MatchCandidate{
+		{Type: models.MatchTypePayment, ID: 7, Amount: 100, Date: day},
+	}
+
+	suggestions := AssignBestMatches(lines, candidates)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, 2, suggestions[0].LineID)
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/barcode"
+	"ERP-ONSMART/backend/internal/billing"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/pix"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/modules/banking/repository"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	salesModels "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	webhooks "ERP-ONSMART/backend/internal/modules/webhooks/models"
+	webhooksService "ERP-ONSMART/backend/internal/modules/webhooks/service"
+	"ERP-ONSMART/backend/internal/realtime"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Dependências das cobranças Pix; substituídas nos testes
+var (
+	newPixRepository = repository.NewPixRepository
+	newPixProvider   = pixProvider
+	publishEvent     = realtime.Publish
+	receiveWebhook   = webhooksService.Receive
+)
+
+// Forma de pagamento dos pagamentos registrados pelo webhook Pix
+const paymentMethodPix = "pix"
+
+// defaultPixExpiration é a validade da cobrança quando PIX_CHARGE_EXPIRATION não é informado
+const defaultPixExpiration = 24 * time.Hour
+
+// pixReuseMargin é a validade mínima restante para reaproveitar uma cobrança já criada
+const pixReuseMargin = 10 * time.Minute
+
+// pixQRScale é o tamanho, em pixels, de cada módulo do QR em PNG
+const pixQRScale = 8
+
+// Status de fatura que aceitam cobrança Pix
+var pixPayableStatuses = map[string]bool{
+	salesModels.InvoiceStatusSent:    true,
+	salesModels.InvoiceStatusPartial: true,
+	salesModels.InvoiceStatusOverdue: true,
+}
+
+// pixProvider monta o PSP configurado em PIX_PROVIDER com as credenciais da API Pix
+func pixProvider() (pix.Provider, error) {
+	return pix.New(viper.GetString("PIX_PROVIDER"), pix.Credentials{
+		BaseURL:      viper.GetString("PIX_API_URL"),
+		TokenURL:     viper.GetString("PIX_API_TOKEN_URL"),
+		ClientID:     viper.GetString("PIX_API_CLIENT_ID"),
+		ClientSecret: viper.GetString("PIX_API_CLIENT_SECRET"),
+		CertFile:     viper.GetString("PIX_API_CERT_FILE"),
+		KeyFile:      viper.GetString("PIX_API_KEY_FILE"),
+	})
+}
+
+// pixReceiver lê o recebedor do Pix da configuração (a mesma chave do Pix das parcelas)
+func pixReceiver() billing.PixConfig {
+	return billing.PixConfig{
+		Key:          viper.GetString("PIX_KEY"),
+		MerchantName: viper.GetString("PIX_MERCHANT_NAME"),
+		MerchantCity: viper.GetString("PIX_MERCHANT_CITY"),
+	}
+}
+
+// pixExpiration é a validade das cobranças criadas
+func pixExpiration() time.Duration {
+	if expiration := viper.GetDuration("PIX_CHARGE_EXPIRATION"); expiration > 0 {
+		return expiration
+	}
+	return defaultPixExpiration
+}
+
+// CreatePixCharge cria no PSP a cobrança Pix dinâmica do saldo em aberto da fatura, com a multa
+// e os juros das parcelas vencidas. Uma cobrança ativa do mesmo valor, com pelo menos dez minutos
+// de validade, é devolvida em vez de criar outra, de modo que abrir o portal ou o PDF várias vezes
+// não gera cobranças repetidas.
+func CreatePixCharge(ctx context.Context, input models.PixChargeInput, createdBy string) (*models.PixCharge, error) {
+	receiver := pixReceiver()
+	if !receiver.Enabled() {
+		return nil, errors.ErrChargeNotConfigured
+	}
+	provider, err := newPixProvider()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := newPixRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := repo.GetInvoice(ctx, input.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	balance := invoice.Balance()
+	if !pixPayableStatuses[invoice.Status] || !balance.IsPositive() {
+		return nil, errors.ErrInvoiceNotPayable
+	}
+	due, err := invoiceAmountDue(ctx, invoice.ID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	amount := balance.Add(due.Charges())
+
+	now := localtime.Now(ctx)
+	existing, err := repo.FindReusableCharge(ctx, invoice.ID, now.Add(pixReuseMargin))
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Amount.Equal(amount) {
+		return existing, nil
+	}
+
+	txID, err := pixTxID(invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+	payerName := invoice.Name
+	if invoice.PersonType == "pj" && invoice.CompanyName != "" {
+		payerName = invoice.CompanyName
+	}
+	expiration := pixExpiration()
+	result, err := provider.CreateCharge(ctx, pix.Charge{
+		TxID:          txID,
+		Key:           receiver.Key,
+		Amount:        amount,
+		Expiration:    expiration,
+		PayerName:     payerName,
+		PayerDocument: onlyDigits(invoice.Document),
+		Description:   "Fatura " + invoice.InvoiceNo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := result.CopyPaste
+	if payload == "" {
+		payload, err = billing.PixDynamicPayload(receiver, result.Location)
+		if err != nil {
+			return nil, errors.WrapError(err, "falha ao gerar o QR da cobrança Pix")
+		}
+	}
+
+	charge := &models.PixCharge{
+		InvoiceID: invoice.ID,
+		Provider:  provider.Name(),
+		TxID:      result.TxID,
+		Location:  result.Location,
+		Payload:   payload,
+		Amount:    amount,
+		ExpiresAt: now.Add(expiration),
+		Status:    models.PixStatusActive,
+		CreatedBy: createdBy,
+	}
+	if err := repo.CreateCharge(ctx, charge); err != nil {
+		return nil, err
+	}
+	return charge, nil
+}
+
+// pixTxID gera o txid da cobrança: FAT, o id da fatura e dígitos aleatórios, com 32 caracteres
+func pixTxID(invoiceID int) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.WrapError(err, "falha ao gerar txid da cobrança Pix")
+	}
+	prefix := "FAT" + strconv.Itoa(invoiceID)
+	return prefix + hex.EncodeToString(random)[:32-len(prefix)], nil
+}
+
+// GetPixCharge retorna a cobrança Pix
+func GetPixCharge(ctx context.Context, id int) (*models.PixCharge, error) {
+	repo, err := newPixRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetCharge(ctx, id)
+}
+
+// ListPixCharges lista as cobranças Pix
+func ListPixCharges(ctx context.Context, filter models.PixChargeFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := newPixRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListCharges(ctx, filter, params)
+}
+
+// PixQRCodePNG desenha o QR do copia e cola em PNG
+func PixQRCodePNG(payload string) ([]byte, error) {
+	symbol, err := barcode.QR(payload)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao gerar o QR da cobrança Pix")
+	}
+	var buf bytes.Buffer
+	if err := symbol.WritePNG(&buf, pixQRScale); err != nil {
+		return nil, errors.WrapError(err, "falha ao gerar o QR da cobrança Pix")
+	}
+	return buf.Bytes(), nil
+}
+
+// ProcessPixWebhook confere o token do webhook (PIX_WEBHOOK_SECRET) e registra como pagamento da
+// fatura cada Pix recebido de uma cobrança do ERP, avisando em tempo real os processos de venda
+// da fatura. Pix sem cobrança do ERP são ignorados e um Pix já registrado não é registrado de
+// novo, então o PSP pode repetir a notificação. A notificação fica gravada, e uma que falhou pode
+// ser processada de novo por `erpctl webhook-replay`.
+func ProcessPixWebhook(ctx context.Context, token string, body []byte) ([]models.PixWebhookResult, error) {
+	secret := viper.GetString("PIX_WEBHOOK_SECRET")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(token)) != 1 {
+		return nil, errors.ErrInvalidWebhookSignature
+	}
+
+	var results []models.PixWebhookResult
+	err := receiveWebhook(ctx, webhooks.SourcePix, "", body, func(ctx context.Context) error {
+		var err error
+		results, err = processPixNotification(ctx, body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ReplayPixWebhook processa de novo uma notificação Pix gravada, com o token já conferido
+func ReplayPixWebhook(ctx context.Context, delivery *webhooks.WebhookDelivery) error {
+	_, err := processPixNotification(ctx, delivery.Payload)
+	return err
+}
+
+// processPixNotification registra os Pix de uma notificação do PSP
+func processPixNotification(ctx context.Context, body []byte) ([]models.PixWebhookResult, error) {
+	payments, err := pix.ParseWebhook(body)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := newPixRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.PixWebhookResult, 0, len(payments))
+	for _, payment := range payments {
+		result, err := processPixPayment(ctx, repo, payment)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// processPixPayment registra o pagamento de um Pix. A cobrança é marcada paga antes do
+// pagamento da fatura: um webhook repetido encontra a cobrança paga e para ali. A marcação e o
+// pagamento formam uma unidade de trabalho, então uma falha ao registrar o pagamento devolve a
+// cobrança para ativa e a notificação repetida (ou o reprocessamento) tenta de novo. Um pagamento
+// recusado pela fatura (já quitada ou cancelada, por exemplo) volta como erro no resultado.
+func processPixPayment(ctx context.Context, repo repository.PixRepository, payment pix.Payment) (models.PixWebhookResult, error) {
+	result := models.PixWebhookResult{EndToEndID: payment.EndToEndID, TxID: payment.TxID, Amount: payment.Amount}
+	if payment.TxID == "" {
+		result.Result, result.Message = models.PixResultIgnored, "Pix recebido sem cobrança"
+		return result, nil
+	}
+
+	charge, err := repo.FindChargeByTxID(tenant.AllCompanies(ctx), payment.TxID)
+	if err != nil {
+		return result, err
+	}
+	if charge == nil {
+		result.Result, result.Message = models.PixResultIgnored, "cobrança não encontrada"
+		return result, nil
+	}
+	result.InvoiceID = charge.InvoiceID
+	ctx = tenant.WithCompany(ctx, charge.CompanyID)
+
+	paidAt := payment.PaidAt
+	if paidAt.IsZero() {
+		paidAt = localtime.Now(ctx)
+	}
+
+	txManager, err := newTxManager()
+	if err != nil {
+		return result, err
+	}
+	var claimed bool
+	var registered *salesModels.BatchResult
+	err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		claimed, err = repo.MarkChargePaid(ctx, charge.ID, payment.EndToEndID, payment.Amount, paidAt)
+		if err != nil || !claimed {
+			return err
+		}
+
+		registered, err = registerPayments(ctx, dtos.PaymentBatchDTO{
+			Atomic: true,
+			Items: []dtos.PaymentCreateDTO{{
+				InvoiceID:     charge.InvoiceID,
+				Amount:        payment.Amount,
+				PaymentDate:   paidAt,
+				PaymentMethod: paymentMethodPix,
+				Reference:     "PIX-" + payment.EndToEndID,
+				Notes:         "Cobrança Pix " + charge.TxID,
+			}},
+		})
+		if err != nil {
+			return err
+		}
+		if len(registered.Items) == 0 || registered.Items[0].Status != salesModels.BatchItemOK {
+			return nil
+		}
+		return repo.UpdateCharge(ctx, charge.ID, map[string]interface{}{"payment_id": registered.Items[0].ID})
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if !claimed {
+		result.Result = models.PixResultDuplicate
+		if charge.EndToEndID != "" && charge.EndToEndID != payment.EndToEndID {
+			result.Result, result.Message = models.PixResultError, "cobrança já paga por outro Pix"
+		}
+		return result, nil
+	}
+
+	if len(registered.Items) == 0 || registered.Items[0].Status != salesModels.BatchItemOK {
+		result.Result, result.Message = models.PixResultError, "pagamento recusado"
+		if len(registered.Items) > 0 && registered.Items[0].Error != nil {
+			result.Message += ": " + registered.Items[0].Error.Message
+		}
+		logger.WithModule("pix_service").Warn("Pix recebido sem pagamento registrado",
+			zap.String("end_to_end_id", payment.EndToEndID), zap.Int("invoice_id", charge.InvoiceID), zap.String("motivo", result.Message))
+		return result, nil
+	}
+
+	result.Result, result.PaymentID = models.PixResultPaid, registered.Items[0].ID
+	notifyPixPayment(ctx, repo, charge, result, paidAt)
+	return result, nil
+}
+
+// notifyPixPayment publica o pagamento nos processos de venda da fatura, como o evento que a linha
+// do tempo do processo passa a mostrar; uma falha aqui não desfaz o pagamento
+func notifyPixPayment(ctx context.Context, repo repository.PixRepository, charge *models.PixCharge, result models.PixWebhookResult, paidAt time.Time) {
+	processIDs, err := repo.GetProcessIDs(ctx, charge.InvoiceID)
+	if err != nil {
+		logger.WithModule("pix_service").Warn("processos da fatura não notificados", zap.Error(err), zap.Int("invoice_id", charge.InvoiceID))
+		return
+	}
+	event := realtime.Event{
+		Type: "payment_received",
+		Data: salesRepository.ProcessEvent{
+			Timestamp:    paidAt,
+			EventType:    "payment_received",
+			Description:  fmt.Sprintf("Pagamento de %s recebido", result.Amount),
+			DocumentType: "payment",
+			DocumentID:   result.PaymentID,
+			Value:        result.Amount,
+		},
+		OccurredAt: paidAt,
+	}
+	for _, processID := range processIDs {
+		publishEvent(realtime.ProcessTopic(charge.CompanyID, processID), event)
+	}
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/pix"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"ERP-ONSMART/backend/internal/modules/banking/repository"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	salesModels "ERP-ONSMART/backend/internal/modules/sales/models"
+	webhooks "ERP-ONSMART/backend/internal/modules/webhooks/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/realtime"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"bytes"
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePixRepository guarda as cobranças em memória
+type fakePixRepository struct {
+	invoice    models.PixInvoice
+	processIDs []int
+	charges    []*models.PixCharge
+}
+
+func (f *fakePixRepository) GetInvoice(ctx context.Context, invoiceID int) (*models.PixInvoice, error) {
+	if invoiceID != f.invoice.ID {
+		return nil, errors.ErrInvoiceNotFound
+	}
+	invoice := f.invoice
+	return &invoice, nil
+}
+
+func (f *fakePixRepository) GetProcessIDs(ctx context.Context, invoiceID int) ([]int, error) {
+	return f.processIDs, nil
+}
+
+func (f *fakePixRepository) FindReusableCharge(ctx context.Context, invoiceID int, now time.Time) (*models.PixCharge, error) {
+	for i := len(f.charges) - 1; i >= 0; i-- {
+		if charge := f.charges[i]; charge.InvoiceID == invoiceID && charge.Payable(now) {
+			return charge, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePixRepository) CreateCharge(ctx context.Context, charge *models.PixCharge) error {
+	charge.ID = len(f.charges) + 1
+	charge.CompanyID = 1
+	f.charges = append(f.charges, charge)
+	return nil
+}
+
+func (f *fakePixRepository) GetCharge(ctx context.Context, id int) (*models.PixCharge, error) {
+	if id < 1 || id > len(f.charges) {
+		return nil, errors.ErrPixChargeNotFound
+	}
+	return f.charges[id-1], nil
+}
+
+func (f *fakePixRepository) ListCharges(ctx context.Context, filter models.PixChargeFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	return pagination.NewPaginatedResult(int64(len(f.charges)), params.Page, params.PageSize, f.charges), nil
+}
+
+func (f *fakePixRepository) FindChargeByTxID(ctx context.Context, txID string) (*models.PixCharge, error) {
+	for _, charge := range f.charges {
+		if charge.TxID == txID {
+			copied := *charge
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePixRepository) MarkChargePaid(ctx context.Context, id int, endToEndID string, amount money.Decimal, paidAt time.Time) (bool, error) {
+	charge := f.charges[id-1]
+	if charge.Status != models.PixStatusActive {
+		return false, nil
+	}
+	charge.Status, charge.EndToEndID, charge.PaidAmount, charge.PaidAt = models.PixStatusPaid, endToEndID, amount, &paidAt
+	return true, nil
+}
+
+func (f *fakePixRepository) UpdateCharge(ctx context.Context, id int, fields map[string]interface{}) error {
+	if paymentID, ok := fields["payment_id"].(int); ok {
+		f.charges[id-1].PaymentID = &paymentID
+	}
+	return nil
+}
+
+// pixRollbackTx simula a unidade de trabalho sobre as cobranças em memória: um erro desfaz as
+// alterações feitas nelas
+type pixRollbackTx struct {
+	repo *fakePixRepository
+}
+
+func (tx pixRollbackTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make([]models.PixCharge, len(tx.repo.charges))
+	for i, charge := range tx.repo.charges {
+		snapshot[i] = *charge
+	}
+	err := fn(ctx)
+	if err != nil {
+		for i := range snapshot {
+			*tx.repo.charges[i] = snapshot[i]
+		}
+	}
+	return err
+}
+
+// fakePixProvider simula o PSP, que devolve a location do payload dinâmico
+type fakePixProvider struct {
+	charges []pix.Charge
+}
+
+func (f *fakePixProvider) Name() string {
+	return pix.ProviderBCB
+}
+
+func (f *fakePixProvider) CreateCharge(ctx context.Context, charge pix.Charge) (*pix.ChargeResult, error) {
+	f.charges = append(f.charges, charge)
+	return &pix.ChargeResult{TxID: charge.TxID, Location: "pix.psp.com.br/qr/v2/" + charge.TxID}, nil
+}
+
+// usePixFakes troca o repositório, o PSP e a publicação de eventos e configura o recebedor
+func usePixFakes(t *testing.T, repo *fakePixRepository) (*fakePixProvider, *[]string) {
+	originalRepo, originalProvider, originalPublish, originalAmountDue := newPixRepository, newPixProvider, publishEvent, invoiceAmountDue
+	originalReceive := receiveWebhook
+	values := map[string]string{
+		"PIX_KEY": "financeiro@onsmart.com.br", "PIX_MERCHANT_NAME": "Onsmart", "PIX_MERCHANT_CITY": "Sao Paulo",
+		"PIX_WEBHOOK_SECRET": "segredo",
+	}
+	for key, value := range values {
+		viper.Set(key, value)
+	}
+	t.Cleanup(func() {
+		newPixRepository, newPixProvider, publishEvent, invoiceAmountDue = originalRepo, originalProvider, originalPublish, originalAmountDue
+		receiveWebhook = originalReceive
+		for key := range values {
+			viper.Set(key, "")
+		}
+	})
+
+	provider := &fakePixProvider{}
+	var topics []string
+	newPixRepository = func() (repository.PixRepository, error) { return repo, nil }
+	newPixProvider = func() (pix.Provider, error) { return provider, nil }
+	invoiceAmountDue = noLateCharges
+	receiveWebhook = func(ctx context.Context, source, provider string, payload []byte, process func(ctx context.Context) error) error {
+		return process(ctx)
+	}
+	publishEvent = func(topic string, event realtime.Event) int {
+		topics = append(topics, topic)
+		return 1
+	}
+	return provider, &topics
+}
+
+// noLateCharges é o valor devido das faturas em dia, sem multa nem juros
+func noLateCharges(ctx context.Context, invoiceID int, asOf time.Time) (*salesModels.AmountDue, error) {
+	return &salesModels.AmountDue{InvoiceID: invoiceID}, nil
+}
+
+func pixInvoice() models.PixInvoice {
+	return models.PixInvoice{
+		ID: 8, InvoiceNo: "INV-2026-0008", Status: "partial",
+		GrandTotal: money.FromInt(1000), AmountPaid: money.FromInt(250),
+		PersonType: "pj", Name: "Contato", CompanyName: "Cliente PJ Ltda", Document: "11.222.333/0001-81",
+	}
+}
+
+func TestCreatePixCharge(t *testing.T) {
+	repo := &fakePixRepository{invoice: pixInvoice()}
+	provider, _ := usePixFakes(t, repo)
+
+	charge, err := CreatePixCharge(context.Background(), models.PixChargeInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+	assert.True(t, charge.Amount.Equal(money.FromInt(750)), "saldo em aberto da fatura")
+	assert.Len(t, charge.TxID, 32)
+	assert.True(t, strings.HasPrefix(charge.TxID, "FAT8"))
+	assert.True(t, strings.HasPrefix(charge.Payload, "000201010212"), "payload dinâmico")
+	assert.Contains(t, charge.Payload, "pix.psp.com.br/qr/v2/"+charge.TxID)
+	assert.Equal(t, models.PixStatusActive, charge.Status)
+	require.Len(t, provider.charges, 1)
+	assert.Equal(t, "Cliente PJ Ltda", provider.charges[0].PayerName)
+	assert.Equal(t, "11222333000181", provider.charges[0].PayerDocument)
+
+	again, err := CreatePixCharge(context.Background(), models.PixChargeInput{InvoiceID: 8}, "portal")
+	require.NoError(t, err)
+	assert.Equal(t, charge.ID, again.ID, "cobrança ativa do mesmo valor é reaproveitada")
+	assert.Len(t, provider.charges, 1)
+
+	repo.invoice.AmountPaid = money.FromInt(500)
+	changed, err := CreatePixCharge(context.Background(), models.PixChargeInput{InvoiceID: 8}, "portal")
+	require.NoError(t, err)
+	assert.NotEqual(t, charge.ID, changed.ID, "saldo alterado pede nova cobrança")
+
+	repo.invoice.Status = "paid"
+	_, err = CreatePixCharge(context.Background(), models.PixChargeInput{InvoiceID: 8}, "portal")
+	assert.Equal(t, errors.ErrInvoiceNotPayable, err)
+
+	png, err := PixQRCodePNG(charge.Payload)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(png, []byte("\x89PNG")))
+}
+
+func TestCreatePixChargeWithLateCharges(t *testing.T) {
+	repo := &fakePixRepository{invoice: pixInvoice()}
+	provider, _ := usePixFakes(t, repo)
+	invoiceAmountDue = func(ctx context.Context, invoiceID int, asOf time.Time) (*salesModels.AmountDue, error) {
+		return &salesModels.AmountDue{InvoiceID: invoiceID, Fine: money.FromInt(15), Interest: money.MustParse("7.50")}, nil
+	}
+
+	charge, err := CreatePixCharge(context.Background(), models.PixChargeInput{InvoiceID: 8}, "portal")
+	require.NoError(t, err)
+	assert.Equal(t, "772.50", charge.Amount.String(), "saldo em aberto com multa e juros")
+	require.Len(t, provider.charges, 1)
+	assert.Equal(t, "772.50", provider.charges[0].Amount.String())
+}
+
+func TestProcessPixWebhook(t *testing.T) {
+	repo := &fakePixRepository{invoice: pixInvoice(), processIDs: []int{3, 5}}
+	_, topics := usePixFakes(t, repo)
+	payments := useFakeCollection(t, newFakeCollectionRepository())
+	var received []string
+	receiveWebhook = func(ctx context.Context, source, provider string, payload []byte, process func(ctx context.Context) error) error {
+		received = append(received, source)
+		return process(ctx)
+	}
+
+	charge, err := CreatePixCharge(context.Background(), models.PixChargeInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+
+	body := []byte(`{"pix":[
+		{"endToEndId":"E1234567820260410120000000000001","txid":"` + charge.TxID + `","valor":"750.00","horario":"2026-04-10T12:00:00.000Z"},
+		{"endToEndId":"E1234567820260410120000000000002","valor":"10.00","horario":"2026-04-10T12:05:00.000Z"}
+	]}`)
+
+	_, err = ProcessPixWebhook(context.Background(), "outro", body)
+	assert.Equal(t, errors.ErrInvalidWebhookSignature, err)
+	assert.Empty(t, received, "notificação com token inválido não é gravada")
+
+	results, err := ProcessPixWebhook(context.Background(), "segredo", body)
+	require.NoError(t, err)
+	assert.Equal(t, []string{webhooks.SourcePix}, received)
+	require.Len(t, results, 2)
+	assert.Equal(t, models.PixResultPaid, results[0].Result)
+	assert.Equal(t, 8, results[0].InvoiceID)
+	assert.Equal(t, models.PixResultIgnored, results[1].Result, "Pix sem cobrança do ERP")
+
+	require.Len(t, *payments, 1)
+	payment := (*payments)[0]
+	assert.Equal(t, "pix", payment.PaymentMethod)
+	assert.Equal(t, "PIX-E1234567820260410120000000000001", payment.Reference)
+	assert.True(t, payment.Amount.Equal(money.FromInt(750)))
+	assert.Equal(t, models.PixStatusPaid, repo.charges[0].Status)
+	require.NotNil(t, repo.charges[0].PaymentID)
+	assert.Equal(t, results[0].PaymentID, *repo.charges[0].PaymentID)
+	assert.Equal(t, []string{realtime.ProcessTopic(1, 3), realtime.ProcessTopic(1, 5)}, *topics)
+
+	results, err = ProcessPixWebhook(context.Background(), "segredo", body)
+	require.NoError(t, err)
+	assert.Equal(t, models.PixResultDuplicate, results[0].Result, "notificação repetida pelo PSP")
+	assert.Len(t, *payments, 1)
+	assert.Len(t, *topics, 2)
+
+	_, err = ProcessPixWebhook(context.Background(), "segredo", []byte(`{"pix":[{"txid":"x"}]}`))
+	assert.Equal(t, errors.ErrInvalidWebhookPayload, err)
+}
+
+func TestProcessPixWebhookRetriesFailedRegistration(t *testing.T) {
+	repo := &fakePixRepository{invoice: pixInvoice()}
+	usePixFakes(t, repo)
+	payments := useFakeCollection(t, newFakeCollectionRepository())
+	newTxManager = func() (db.TxManager, error) { return pixRollbackTx{repo: repo}, nil }
+
+	charge, err := CreatePixCharge(context.Background(), models.PixChargeInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+	body := []byte(`{"pix":[
+		{"endToEndId":"E1234567820260410120000000000004","txid":"` + charge.TxID + `","valor":"750.00","horario":"2026-04-10T12:00:00.000Z"}
+	]}`)
+
+	register := registerPayments
+	registerPayments = func(ctx context.Context, req dtos.PaymentBatchDTO) (*salesModels.BatchResult, error) {
+		return nil, stderrors.New("banco indisponível")
+	}
+	_, err = ProcessPixWebhook(context.Background(), "segredo", body)
+	require.Error(t, err)
+	assert.Equal(t, models.PixStatusActive, repo.charges[0].Status, "a falha no pagamento desfaz a baixa da cobrança")
+	assert.Empty(t, repo.charges[0].EndToEndID)
+
+	registerPayments = register
+	results, err := ProcessPixWebhook(context.Background(), "segredo", body)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, models.PixResultPaid, results[0].Result, "a notificação repetida registra o pagamento")
+	require.Len(t, *payments, 1)
+	assert.Equal(t, models.PixStatusPaid, repo.charges[0].Status)
+	require.NotNil(t, repo.charges[0].PaymentID)
+}
+
+func TestReplayPixWebhook(t *testing.T) {
+	repo := &fakePixRepository{invoice: pixInvoice()}
+	usePixFakes(t, repo)
+	payments := useFakeCollection(t, newFakeCollectionRepository())
+
+	charge, err := CreatePixCharge(context.Background(), models.PixChargeInput{InvoiceID: 8}, "financeiro")
+	require.NoError(t, err)
+
+	delivery := &webhooks.WebhookDelivery{Source: webhooks.SourcePix, Payload: []byte(`{"pix":[
+		{"endToEndId":"E1234567820260410120000000000003","txid":"` + charge.TxID + `","valor":"750.00","horario":"2026-04-10T12:00:00.000Z"}
+	]}`)}
+	require.NoError(t, ReplayPixWebhook(context.Background(), delivery))
+	require.Len(t, *payments, 1)
+	assert.Equal(t, models.PixStatusPaid, repo.charges[0].Status)
+
+	require.NoError(t, ReplayPixWebhook(context.Background(), delivery), "Pix já registrado")
+	assert.Len(t, *payments, 1)
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/billing"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DetectStatementFormat identifica o formato do extrato pela extensão do arquivo
+func DetectStatementFormat(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ofx", ".qfx":
+		return models.StatementFormatOFX, nil
+	case ".csv", ".txt":
+		return models.StatementFormatCSV, nil
+	case ".ret":
+		return models.StatementFormatCNAB240, nil
+	}
+	return "", errors.ErrUnsupportedStatementFormat
+}
+
+// ParseStatement converte o conteúdo do arquivo em lançamentos, conforme o formato
+func ParseStatement(format string, content []byte) ([]models.BankStatementLine, error) {
+	var (
+		lines []models.BankStatementLine
+		err   error
+	)
+
+	switch format {
+	case models.StatementFormatOFX:
+		lines, err = ParseOFX(content)
+	case models.StatementFormatCSV:
+		lines, err = ParseCSV(content)
+	case models.StatementFormatCNAB240:
+		lines, err = ParseCNAB240(content)
+	default:
+		return nil, errors.ErrUnsupportedStatementFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.ErrEmptyStatement
+	}
+	return lines, nil
+}
+
+var (
+	ofxTransactionRegex = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	ofxTagRegex         = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+)
+
+// ParseOFX lê os blocos <STMTTRN> de um arquivo OFX (SGML 1.x ou XML 2.x)
+func ParseOFX(content []byte) ([]models.BankStatementLine, error) {
+	var lines []models.BankStatementLine
+
+	for _, block := range ofxTransactionRegex.FindAllSubmatch(content, -1) {
+		fields := make(map[string]string)
+		for _, tag := range ofxTagRegex.FindAllSubmatch(block[1], -1) {
+			fields[strings.ToUpper(string(tag[1]))] = strings.TrimSpace(string(tag[2]))
+		}
+
+		date, err := parseOFXDate(fields["DTPOSTED"])
+		if err != nil {
+			return nil, fmt.Errorf("data inválida no lançamento OFX: %w", err)
+		}
+
+		amount, err := parseAmount(fields["TRNAMT"])
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido no lançamento OFX: %w", err)
+		}
+
+		description := fields["MEMO"]
+		if name := fields["NAME"]; name != "" && !strings.Contains(description, name) {
+			description = strings.TrimSpace(name + " " + description)
+		}
+
+		reference := fields["CHECKNUM"]
+		if reference == "" {
+			reference = fields["REFNUM"]
+		}
+
+		lines = append(lines, models.BankStatementLine{
+			TransactionDate: date,
+			Amount:          amount,
+			Description:     description,
+			Reference:       reference,
+			ExternalID:      fields["FITID"],
+		})
+	}
+
+	return lines, nil
+}
+
+// parseOFXDate converte datas OFX no formato AAAAMMDD[HHMMSS[.XXX]][[-3:BRT]]
+func parseOFXDate(value string) (time.Time, error) {
+	if idx := strings.IndexAny(value, ".["); idx >= 0 {
+		value = value[:idx]
+	}
+	switch {
+	case len(value) >= 14:
+		return time.Parse("20060102150405", value[:14])
+	case len(value) >= 8:
+		return time.Parse("20060102", value[:8])
+	}
+	return time.Time{}, fmt.Errorf("formato de data desconhecido: %q", value)
+}
+
+// Cabeçalhos aceitos no CSV, em português ou inglês
+var csvHeaderAliases = map[string][]string{
+	"date":        {"date", "data", "data lancamento", "data lançamento", "dt"},
+	"description": {"description", "descricao", "descrição", "historico", "histórico", "memo"},
+	"amount":      {"amount", "valor", "value"},
+	"reference":   {"reference", "referencia", "referência", "documento", "doc", "num doc"},
+	"external_id": {"id", "external_id", "fitid", "identificador"},
+}
+
+var csvDateLayouts = []string{"2006-01-02", "02/01/2006", "02/01/06", "02-01-2006"}
+
+// ParseCSV lê extratos CSV com cabeçalho. Aceita separador "," ou ";" e valores no padrão brasileiro.
+func ParseCSV(content []byte) ([]models.BankStatementLine, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = detectCSVSeparator(content)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.ErrEmptyStatement
+	}
+
+	columns := mapCSVColumns(records[0])
+	if _, ok := columns["date"]; !ok {
+		return nil, fmt.Errorf("coluna de data não encontrada no CSV")
+	}
+	if _, ok := columns["amount"]; !ok {
+		return nil, fmt.Errorf("coluna de valor não encontrada no CSV")
+	}
+
+	var lines []models.BankStatementLine
+	for i, record := range records[1:] {
+		get := func(key string) string {
+			idx, ok := columns[key]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+
+		if get("date") == "" && get("amount") == "" {
+			continue
+		}
+
+		date, err := parseCSVDate(get("date"))
+		if err != nil {
+			return nil, fmt.Errorf("data inválida na linha %d: %w", i+2, err)
+		}
+
+		amount, err := parseAmount(get("amount"))
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido na linha %d: %w", i+2, err)
+		}
+
+		lines = append(lines, models.BankStatementLine{
+			TransactionDate: date,
+			Amount:          amount,
+			Description:     get("description"),
+			Reference:       get("reference"),
+			ExternalID:      get("external_id"),
+		})
+	}
+
+	return lines, nil
+}
+
+// ParseCNAB240 lê o arquivo de retorno de cobrança no layout FEBRABAN 240. Cada título liquidado
+// vira um crédito com o valor líquido na data do crédito; os demais movimentos (entrada
+// confirmada, baixa, alteração) são ignorados.
+func ParseCNAB240(content []byte) ([]models.BankStatementLine, error) {
+	records, err := billing.ParseCNAB240Return(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []models.BankStatementLine
+	for _, record := range records {
+		if !record.IsLiquidation() {
+			continue
+		}
+		if record.SettledAt().IsZero() {
+			return nil, fmt.Errorf("data inválida na linha %d do CNAB 240", record.Line+1)
+		}
+		label := "Liquidação"
+		if record.Occurrence == billing.CNABLiquidationAfterOff {
+			label = "Liquidação após baixa"
+		}
+		lines = append(lines, models.BankStatementLine{
+			TransactionDate: record.SettledAt(),
+			Amount:          record.NetAmount.Float64(),
+			Description:     fmt.Sprintf("%s do título %s (nosso número %s)", label, record.DocumentNo, record.OurNumber),
+			Reference:       record.DocumentNo,
+			ExternalID:      record.OurNumber,
+		})
+	}
+
+	return lines, nil
+}
+
+func detectCSVSeparator(content []byte) rune {
+	firstLine := content
+	if idx := bytes.IndexByte(content, '\n'); idx >= 0 {
+		firstLine = content[:idx]
+	}
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+func mapCSVColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for idx, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for key, aliases := range csvHeaderAliases {
+			if _, exists := columns[key]; exists {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[key] = idx
+				}
+			}
+		}
+	}
+	return columns
+}
+
+func parseCSVDate(value string) (time.Time, error) {
+	for _, layout := range csvDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("formato de data desconhecido: %q", value)
+}
+
+// parseAmount aceita "1234.56", "-1234.56", "1.234,56" e "R$ -1.234,56"
+func parseAmount(value string) (float64, error) {
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "R$"))
+	value = strings.ReplaceAll(value, " ", "")
+
+	lastComma := strings.LastIndex(value, ",")
+	lastDot := strings.LastIndex(value, ".")
+	if lastComma > lastDot {
+		// Padrão brasileiro: ponto como milhar e vírgula como decimal
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
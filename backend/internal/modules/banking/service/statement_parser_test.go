@@ -0,0 +1,164 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/banking/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleOFX = `OFXHEADER:100
+DATA:OFXSGML
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250310120000[-3:BRT]
+<TRNAMT>1500.00
+<FITID>A001
+<NAME>CLIENTE XPTO
+<MEMO>PIX RECEBIDO INV-2025-0001
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250311
+<TRNAMT>-320.50
+<FITID>A002
+<CHECKNUM>8812
+<MEMO>PAGTO BOLETO
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+func TestParseOFX(t *testing.T) {
+	lines, err := ParseOFX([]byte(sampleOFX))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC), lines[0].TransactionDate)
+	assert.Equal(t, 1500.00, lines[0].Amount)
+	assert.Equal(t, "A001", lines[0].ExternalID)
+	assert.Equal(t, "CLIENTE XPTO PIX RECEBIDO INV-2025-0001", lines[0].Description)
+
+	assert.Equal(t, -320.50, lines[1].Amount)
+	assert.Equal(t, "8812", lines[1].Reference)
+}
+
+func TestParseCSV(t *testing.T) {
+	content := "\xef\xbb\xbfData;Histórico;Valor;Documento\n" +
+		"10/03/2025;PIX RECEBIDO;1.500,00;INV-1\n" +
+		"11/03/2025;PAGTO FORNECEDOR;-320,50;8812\n" +
+		";;;\n"
+
+	lines, err := ParseCSV([]byte(content))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), lines[0].TransactionDate)
+	assert.Equal(t, 1500.00, lines[0].Amount)
+	assert.Equal(t, "PIX RECEBIDO", lines[0].Description)
+	assert.Equal(t, "INV-1", lines[0].Reference)
+	assert.Equal(t, -320.50, lines[1].Amount)
+}
+
+func TestParseCSVMissingAmountColumn(t *testing.T) {
+	_, err := ParseCSV([]byte("date,description\n2025-03-10,teste\n"))
+	assert.Error(t, err)
+}
+
+// cnabRecord monta um registro de 240 posições com os campos nas posições (1-based) informadas
+func cnabRecord(fields map[int]string) string {
+	record := []byte(strings.Repeat(" ", 240))
+	for position, value := range fields {
+		copy(record[position-1:], value)
+	}
+	return string(record)
+}
+
+func TestParseCNAB240(t *testing.T) {
+	content := strings.Join([]string{
+		cnabRecord(map[int]string{1: "00100000", 143: "2"}),
+		cnabRecord(map[int]string{1: "00100011"}),
+		// Título liquidado: T com o movimento 06 e U com o valor líquido e a data do crédito
+		cnabRecord(map[int]string{1: "00100013", 9: "00001", 14: "T", 16: "06", 38: "00000000000000012345", 59: "INV-2025-0001  "}),
+		cnabRecord(map[int]string{1: "00100013", 9: "00002", 14: "U", 16: "06", 78: "000000000150000", 93: "000000000149550", 138: "10032025", 146: "11032025"}),
+		// Entrada confirmada (02): ignorada
+		cnabRecord(map[int]string{1: "00100013", 9: "00003", 14: "T", 16: "02", 38: "00000000000000012346", 59: "INV-2025-0002"}),
+		cnabRecord(map[int]string{1: "00100013", 9: "00004", 14: "U", 16: "02", 93: "000000000050000", 138: "10032025"}),
+		// Liquidação após baixa sem data do crédito: vale a data da ocorrência
+		cnabRecord(map[int]string{1: "00100013", 9: "00005", 14: "T", 16: "17", 38: "00000000000000012347", 59: "INV-2025-0003"}),
+		cnabRecord(map[int]string{1: "00100013", 9: "00006", 14: "U", 16: "17", 93: "000000000032050", 138: "12032025", 146: "00000000"}),
+		cnabRecord(map[int]string{1: "00100015"}),
+		cnabRecord(map[int]string{1: "00199999"}),
+	}, "\r\n") + "\r\n"
+
+	lines, err := ParseStatement(models.StatementFormatCNAB240, []byte(content))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), lines[0].TransactionDate)
+	assert.Equal(t, 1495.50, lines[0].Amount)
+	assert.Equal(t, "INV-2025-0001", lines[0].Reference)
+	assert.Equal(t, "00000000000000012345", lines[0].ExternalID)
+	assert.Equal(t, "Liquidação do título INV-2025-0001 (nosso número 00000000000000012345)", lines[0].Description)
+
+	assert.Equal(t, time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC), lines[1].TransactionDate)
+	assert.Equal(t, 320.50, lines[1].Amount)
+}
+
+func TestParseCNAB240RejectsOtherLayouts(t *testing.T) {
+	// Header de um retorno CNAB 400
+	cnab400 := "02RETORNO01COBRANCA" + strings.Repeat(" ", 381)
+	_, err := ParseCNAB240([]byte(cnab400 + "\n" + cnab400))
+	assert.ErrorContains(t, err, "sem o header de arquivo")
+
+	_, err = ParseCNAB240([]byte(cnabRecord(map[int]string{1: "00100000"}) + "\n" + strings.Repeat("0", 200)))
+	assert.ErrorContains(t, err, "linha 2 do CNAB 240 com 200 posições")
+}
+
+func TestParseStatementUnsupportedFormat(t *testing.T) {
+	_, err := ParseStatement("xls", []byte("x"))
+	assert.Equal(t, errors.ErrUnsupportedStatementFormat, err)
+
+	_, err = ParseStatement(models.StatementFormatOFX, []byte("<OFX></OFX>"))
+	assert.Equal(t, errors.ErrEmptyStatement, err)
+}
+
+func TestDetectStatementFormat(t *testing.T) {
+	format, err := DetectStatementFormat("extrato.OFX")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatementFormatOFX, format)
+
+	format, err = DetectStatementFormat("extrato.csv")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatementFormatCSV, format)
+
+	format, err = DetectStatementFormat("COB2503.RET")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatementFormatCNAB240, format)
+
+	_, err = DetectStatementFormat("extrato.pdf")
+	assert.Equal(t, errors.ErrUnsupportedStatementFormat, err)
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]float64{
+		"1234.56":      1234.56,
+		"-1234.56":     -1234.56,
+		"1.234,56":     1234.56,
+		"R$ -1.234,56": -1234.56,
+		"1,234.56":     1234.56,
+		"10":           10,
+	}
+	for input, expected := range cases {
+		amount, err := parseAmount(input)
+		require.NoError(t, err, input)
+		assert.InDelta(t, expected, amount, 0.001, input)
+	}
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/calendar/models"
+	"ERP-ONSMART/backend/internal/modules/calendar/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+// Retorna o calendário de expediente da empresa: UF, dias úteis (0 = domingo a 6 = sábado) e
+// horário de funcionamento. Sem configuração, vale segunda a sexta das 08:00 às 18:00.
+// @Security BearerAuth
+func GetCalendarHandler(c *gin.Context) {
+	calendar, err := service.GetCalendar(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar calendário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"calendar": calendar})
+}
+
+// Configura o calendário de expediente da empresa; a UF define quais feriados estaduais valem
+// @Security BearerAuth
+func UpdateCalendarHandler(c *gin.Context) {
+	var input models.CalendarInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	calendar, err := service.UpdateCalendar(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar calendário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"calendar": calendar})
+}
+
+// Lista os feriados do ano que valem para a empresa: os nacionais e os cadastrados sem UF ou da UF
+// do calendário
+// @Security BearerAuth
+// @Param year query int false "Ano (padrão ano corrente)"
+func ListHolidaysHandler(c *gin.Context) {
+	year := 0
+	if value := c.Query("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1900 || parsed > 2200 {
+			c.Error(errors.InvalidParam("year inválido"))
+			return
+		}
+		year = parsed
+	}
+
+	holidays, err := service.ListHolidays(c.Request.Context(), year)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar feriados")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"holidays": holidays})
+}
+
+// Cadastra um feriado da empresa, estadual (com UF) ou municipal; recurring repete a data todo ano
+// @Security BearerAuth
+func CreateHolidayHandler(c *gin.Context) {
+	var input models.HolidayInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	holiday, err := service.CreateHoliday(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar feriado")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"holiday": holiday})
+}
+
+// Altera um feriado cadastrado
+// @Security BearerAuth
+// @Param id path int true "ID do feriado"
+func UpdateHolidayHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.HolidayInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	holiday, err := service.UpdateHoliday(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar feriado")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"holiday": holiday})
+}
+
+// Remove um feriado cadastrado
+// @Security BearerAuth
+// @Param id path int true "ID do feriado"
+func DeleteHolidayHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteHoliday(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover feriado")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feriado removido com sucesso"})
+}
+
+// Soma dias úteis a uma data pelo calendário da empresa, a mesma conta dos vencimentos das faturas
+// @Security BearerAuth
+func AddBusinessDaysHandler(c *gin.Context) {
+	var input models.AddBusinessDaysInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+	date, err := time.Parse(dateLayout, input.Date)
+	if err != nil {
+		c.Error(errors.InvalidParam("date inválido, use o formato AAAA-MM-DD"))
+		return
+	}
+
+	result, err := service.AddBusinessDays(c.Request.Context(), date, input.Days)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao somar dias úteis")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"date": result.Format(dateLayout)})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,301 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// maxSearchDays limita a busca do próximo dia útil (um ano e meio sem dia útil indica
+	// calendário inconsistente)
+	maxSearchDays = 550
+)
+
+// DefaultWorkingDays são os dias úteis do calendário padrão: segunda a sexta
+var DefaultWorkingDays = []int{1, 2, 3, 4, 5}
+
+// Horário de funcionamento do calendário padrão
+const (
+	DefaultOpensAt  = "08:00"
+	DefaultClosesAt = "18:00"
+)
+
+// Calendar é o calendário de expediente da empresa
+type Calendar struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	CompanyID   int       `json:"company_id" gorm:"<-:create"`
+	State       *string   `json:"state"`
+	WorkingDays []int     `json:"working_days" gorm:"serializer:json"`
+	OpensAt     string    `json:"opens_at"`
+	ClosesAt    string    `json:"closes_at"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela
+func (Calendar) TableName() string {
+	return "business_calendars"
+}
+
+// DefaultCalendar é o calendário das empresas que não configuraram o seu
+func DefaultCalendar() Calendar {
+	return Calendar{
+		WorkingDays: append([]int(nil), DefaultWorkingDays...),
+		OpensAt:     DefaultOpensAt,
+		ClosesAt:    DefaultClosesAt,
+	}
+}
+
+// CalendarInput é o corpo aceito em PUT /calendar
+type CalendarInput struct {
+	State       string `json:"state" binding:"omitempty,len=2"`
+	WorkingDays []int  `json:"working_days" binding:"required,min=1,max=7,dive,min=0,max=6"`
+	OpensAt     string `json:"opens_at" binding:"required"`
+	ClosesAt    string `json:"closes_at" binding:"required"`
+}
+
+// Holiday é um feriado cadastrado pela empresa
+type Holiday struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	Date      time.Time `json:"date"`
+	Name      string    `json:"name"`
+	State     *string   `json:"state"`
+	Recurring bool      `json:"recurring"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela
+func (Holiday) TableName() string {
+	return "holidays"
+}
+
+// HolidayInput é o corpo aceito em POST e PUT /calendar/holidays
+type HolidayInput struct {
+	Date      string `json:"date" binding:"required"`
+	Name      string `json:"name" binding:"required,max=100"`
+	State     string `json:"state" binding:"omitempty,len=2"`
+	Recurring bool   `json:"recurring"`
+}
+
+// HolidayEntry é um feriado de um ano, cadastrado ou nacional embutido
+type HolidayEntry struct {
+	ID       int     `json:"id,omitempty"`
+	Date     string  `json:"date"`
+	Name     string  `json:"name"`
+	State    *string `json:"state,omitempty"`
+	National bool    `json:"national"`
+}
+
+// AddBusinessDaysInput é o corpo aceito em POST /calendar/add-business-days
+type AddBusinessDaysInput struct {
+	Date string `json:"date" binding:"required"`
+	Days int    `json:"days" binding:"gte=0,lte=3650"`
+}
+
+// nationalHolidays são os feriados nacionais de data fixa (MM-DD)
+var nationalHolidays = map[string]string{
+	"01-01": "Confraternização Universal",
+	"04-21": "Tiradentes",
+	"05-01": "Dia do Trabalho",
+	"09-07": "Independência do Brasil",
+	"10-12": "Nossa Senhora Aparecida",
+	"11-02": "Finados",
+	"11-15": "Proclamação da República",
+	"11-20": "Dia Nacional de Zumbi e da Consciência Negra",
+	"12-25": "Natal",
+}
+
+// NationalHolidays retorna os feriados nacionais do ano: os de data fixa e a Sexta-feira Santa
+func NationalHolidays(year int) map[string]string {
+	holidays := make(map[string]string, len(nationalHolidays)+1)
+	for monthDay, name := range nationalHolidays {
+		if monthDay == "11-20" && year < 2024 {
+			continue
+		}
+		holidays[fmt.Sprintf("%04d-%s", year, monthDay)] = name
+	}
+	holidays[Easter(year).AddDate(0, 0, -2).Format(dateLayout)] = "Sexta-feira Santa"
+	return holidays
+}
+
+// Easter calcula o domingo de Páscoa do ano (algoritmo de Meeus/Jones/Butcher)
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// ParseClock converte um horário HH:MM em minutos desde a meia-noite
+func ParseClock(value string) (int, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("horário %q inválido, use HH:MM", value)
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// Schedule é o calendário pronto para as contas de dias úteis: dias da semana úteis, horário de
+// funcionamento e feriados (nacionais, da UF do calendário e os sem UF cadastrados)
+type Schedule struct {
+	workingDays [7]bool
+	opens       int
+	closes      int
+	holidays    map[string]string
+	recurring   map[string]string
+}
+
+// NewSchedule monta o calendário a partir da configuração e dos feriados cadastrados. Feriados de
+// outras UFs são ignorados.
+func NewSchedule(calendar Calendar, holidays []Holiday) *Schedule {
+	schedule := &Schedule{holidays: map[string]string{}, recurring: map[string]string{}}
+	for _, day := range calendar.WorkingDays {
+		if day >= 0 && day <= 6 {
+			schedule.workingDays[day] = true
+		}
+	}
+	schedule.opens, _ = ParseClock(calendar.OpensAt)
+	schedule.closes, _ = ParseClock(calendar.ClosesAt)
+
+	for _, holiday := range holidays {
+		if holiday.State != nil && (calendar.State == nil || !strings.EqualFold(*holiday.State, *calendar.State)) {
+			continue
+		}
+		if holiday.Recurring {
+			schedule.recurring[holiday.Date.Format("01-02")] = holiday.Name
+		} else {
+			schedule.holidays[holiday.Date.Format(dateLayout)] = holiday.Name
+		}
+	}
+	return schedule
+}
+
+// DefaultSchedule é o calendário padrão (segunda a sexta, 08:00 às 18:00, feriados nacionais)
+func DefaultSchedule() *Schedule {
+	return NewSchedule(DefaultCalendar(), nil)
+}
+
+// HolidayName retorna o nome do feriado na data, se houver
+func (s *Schedule) HolidayName(date time.Time) (string, bool) {
+	key := date.Format(dateLayout)
+	if name, ok := s.holidays[key]; ok {
+		return name, true
+	}
+	if name, ok := s.recurring[date.Format("01-02")]; ok {
+		return name, true
+	}
+	name, ok := NationalHolidays(date.Year())[key]
+	return name, ok
+}
+
+// IsBusinessDay indica se a data é dia útil: dia da semana útil e sem feriado
+func (s *Schedule) IsBusinessDay(date time.Time) bool {
+	if !s.workingDays[date.Weekday()] {
+		return false
+	}
+	_, holiday := s.HolidayName(date)
+	return !holiday
+}
+
+// NextBusinessDay retorna a própria data, se for dia útil, ou o próximo dia útil no mesmo horário
+func (s *Schedule) NextBusinessDay(date time.Time) time.Time {
+	for i := 0; i < maxSearchDays && !s.IsBusinessDay(date); i++ {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// AddBusinessDays soma dias úteis mantendo o horário. A contagem começa no próprio dia se ele for
+// útil e o horário não passar do fechamento; senão, na abertura do próximo dia útil.
+func (s *Schedule) AddBusinessDays(start time.Time, days int) time.Time {
+	minutes := start.Hour()*60 + start.Minute()
+	if !s.IsBusinessDay(start) || (s.closes > s.opens && minutes >= s.closes) {
+		next := s.NextBusinessDay(start.AddDate(0, 0, 1))
+		start = time.Date(next.Year(), next.Month(), next.Day(), s.opens/60, s.opens%60, 0, 0, start.Location())
+	}
+
+	due := start
+	for added := 0; added < days; added++ {
+		due = s.NextBusinessDay(due.AddDate(0, 0, 1))
+	}
+	return due
+}
+
+// DefaultPaymentDays é o prazo de pagamento das faturas sem condição reconhecível
+const DefaultPaymentDays = 30
+
+// maxPaymentDays limita o prazo lido da condição de pagamento (dez anos)
+const maxPaymentDays = 3650
+
+var paymentDaysPattern = regexp.MustCompile(`\d+`)
+
+// PaymentDueDate calcula o vencimento da fatura pela condição de pagamento: "à vista" vence na
+// emissão, "N dias úteis" soma dias úteis e "N dias" soma dias corridos, adiando o vencimento que
+// cair em dia não útil. Nas condições parceladas (30/60/90) vale a primeira parcela; sem prazo
+// reconhecível, vence em 30 dias.
+func (s *Schedule) PaymentDueDate(issue time.Time, terms string) time.Time {
+	terms = strings.ToLower(strings.TrimSpace(terms))
+	if strings.Contains(terms, "vista") {
+		return issue
+	}
+
+	days := DefaultPaymentDays
+	if match := paymentDaysPattern.FindString(terms); match != "" {
+		days, _ = strconv.Atoi(match)
+		if days > maxPaymentDays || len(match) > 4 {
+			days = maxPaymentDays
+		}
+	}
+	if strings.Contains(terms, "úteis") || strings.Contains(terms, "uteis") {
+		return s.AddBusinessDays(issue, days)
+	}
+	return s.NextBusinessDay(issue.AddDate(0, 0, days))
+}
+
+// Holidays lista os feriados do ano em ordem de data: os nacionais embutidos e os cadastrados que
+// valem para o calendário
+func Holidays(year int, calendar Calendar, registered []Holiday) []HolidayEntry {
+	entries := []HolidayEntry{}
+	seen := map[string]bool{}
+	for date, name := range NationalHolidays(year) {
+		entries = append(entries, HolidayEntry{Date: date, Name: name, National: true})
+		seen[date] = true
+	}
+	for _, holiday := range registered {
+		if holiday.State != nil && (calendar.State == nil || !strings.EqualFold(*holiday.State, *calendar.State)) {
+			continue
+		}
+		date := holiday.Date
+		if holiday.Recurring {
+			date = time.Date(year, date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		} else if date.Year() != year {
+			continue
+		}
+		key := date.Format(dateLayout)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, HolidayEntry{ID: holiday.ID, Date: key, Name: holiday.Name, State: holiday.State})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Date < entries[j].Date })
+	return entries
+}
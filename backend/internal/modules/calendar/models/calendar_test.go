@@ -0,0 +1,91 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestEaster(t *testing.T) {
+	assert.Equal(t, at(2024, 3, 31, 0, 0), Easter(2024))
+	assert.Equal(t, at(2025, 4, 20, 0, 0), Easter(2025))
+	assert.Equal(t, at(2026, 4, 5, 0, 0), Easter(2026))
+}
+
+func TestNationalHolidays(t *testing.T) {
+	holidays := NationalHolidays(2024)
+	assert.Equal(t, "Sexta-feira Santa", holidays["2024-03-29"])
+	assert.Equal(t, "Tiradentes", holidays["2024-04-21"])
+	assert.Contains(t, holidays, "2024-11-20")
+
+	assert.NotContains(t, NationalHolidays(2023), "2023-11-20")
+}
+
+func TestAddBusinessDaysSkipsWeekendsAndHolidays(t *testing.T) {
+	schedule := DefaultSchedule()
+
+	thursday := at(2024, 5, 16, 14, 30)
+	assert.Equal(t, at(2024, 5, 20, 14, 30), schedule.AddBusinessDays(thursday, 2))
+
+	// Sexta-feira Santa (29/03/2024) e o fim de semana ficam de fora
+	assert.Equal(t, at(2024, 4, 1, 10, 0), schedule.AddBusinessDays(at(2024, 3, 28, 10, 0), 1))
+}
+
+func TestAddBusinessDaysAfterHoursStartsNextDay(t *testing.T) {
+	schedule := DefaultSchedule()
+
+	// depois do fechamento, a contagem começa na abertura do dia útil seguinte
+	assert.Equal(t, at(2024, 5, 21, 8, 0), schedule.AddBusinessDays(at(2024, 5, 16, 19, 0), 2))
+	// no fim de semana, também
+	assert.Equal(t, at(2024, 5, 20, 8, 0), schedule.AddBusinessDays(at(2024, 5, 18, 9, 0), 0))
+	// antes da abertura, o próprio dia conta
+	assert.Equal(t, at(2024, 5, 17, 7, 0), schedule.AddBusinessDays(at(2024, 5, 16, 7, 0), 1))
+}
+
+func TestNewScheduleFiltersHolidaysByState(t *testing.T) {
+	sp, rj := "SP", "RJ"
+	holidays := []Holiday{
+		{Date: at(2024, 7, 9, 0, 0), Name: "Revolução Constitucionalista", State: &sp},
+		{Date: at(2024, 4, 23, 0, 0), Name: "São Jorge", State: &rj},
+		{Date: at(2020, 1, 25, 0, 0), Name: "Aniversário da cidade", Recurring: true},
+	}
+	schedule := NewSchedule(Calendar{State: &sp, WorkingDays: DefaultWorkingDays, OpensAt: "08:00", ClosesAt: "18:00"}, holidays)
+
+	assert.False(t, schedule.IsBusinessDay(at(2024, 7, 9, 0, 0)))
+	assert.True(t, schedule.IsBusinessDay(at(2024, 4, 23, 0, 0)))
+	name, ok := schedule.HolidayName(at(2024, 1, 25, 0, 0))
+	assert.True(t, ok)
+	assert.Equal(t, "Aniversário da cidade", name)
+}
+
+func TestPaymentDueDate(t *testing.T) {
+	schedule := DefaultSchedule()
+	issue := at(2024, 5, 16, 10, 0)
+
+	assert.Equal(t, issue, schedule.PaymentDueDate(issue, "À vista"))
+	assert.Equal(t, at(2024, 5, 20, 10, 0), schedule.PaymentDueDate(issue, "2 dias úteis"))
+	// 30 dias corridos caem no sábado e o vencimento passa para segunda
+	assert.Equal(t, at(2024, 6, 17, 10, 0), schedule.PaymentDueDate(issue, "30 dias"))
+	assert.Equal(t, at(2024, 6, 17, 10, 0), schedule.PaymentDueDate(issue, "30/60/90"))
+	assert.Equal(t, at(2024, 6, 17, 10, 0), schedule.PaymentDueDate(issue, ""))
+}
+
+func TestHolidaysListsNationalAndRegistered(t *testing.T) {
+	sp := "SP"
+	entries := Holidays(2024, Calendar{State: &sp}, []Holiday{
+		{ID: 7, Date: at(2024, 7, 9, 0, 0), Name: "Revolução Constitucionalista", State: &sp},
+		{ID: 8, Date: at(2023, 12, 8, 0, 0), Name: "Não recorrente"},
+	})
+
+	require.Len(t, entries, len(NationalHolidays(2024))+1)
+	for i := 1; i < len(entries); i++ {
+		assert.LessOrEqual(t, entries[i-1].Date, entries[i].Date)
+	}
+	assert.Contains(t, entries, HolidayEntry{ID: 7, Date: "2024-07-09", Name: "Revolução Constitucionalista", State: &sp})
+}
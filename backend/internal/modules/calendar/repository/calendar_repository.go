@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/calendar/models"
+	"context"
+	stderrors "errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CalendarRepository mantém o calendário de expediente e os feriados da empresa
+type CalendarRepository interface {
+	GetCalendar(ctx context.Context) (*models.Calendar, error)
+	SaveCalendar(ctx context.Context, calendar *models.Calendar) error
+
+	ListHolidays(ctx context.Context) ([]models.Holiday, error)
+	GetHoliday(ctx context.Context, id int) (*models.Holiday, error)
+	CreateHoliday(ctx context.Context, holiday *models.Holiday) error
+	UpdateHoliday(ctx context.Context, holiday *models.Holiday) error
+	DeleteHoliday(ctx context.Context, id int) error
+
+	Schedule(ctx context.Context) (*models.Schedule, error)
+}
+
+type calendarRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCalendarRepository cria uma nova instância do repositório
+func NewCalendarRepository() (CalendarRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &calendarRepository{
+		db:     db,
+		logger: logger.WithModule("calendar_repository"),
+	}, nil
+}
+
+// GetCalendar retorna o calendário da empresa ou o padrão, se ela não configurou o seu
+func (r *calendarRepository) GetCalendar(ctx context.Context) (*models.Calendar, error) {
+	return loadCalendar(r.db.WithContext(ctx))
+}
+
+// SaveCalendar grava o calendário da empresa, criando-o na primeira configuração
+func (r *calendarRepository) SaveCalendar(ctx context.Context, calendar *models.Calendar) error {
+	var err error
+	if calendar.ID == 0 {
+		err = r.db.WithContext(ctx).Create(calendar).Error
+	} else {
+		err = r.db.WithContext(ctx).Model(calendar).
+			Select("state", "working_days", "opens_at", "closes_at", "updated_at").
+			Updates(calendar).Error
+	}
+	if err != nil {
+		r.logger.Error("erro ao gravar calendário", zap.Error(err))
+		return errors.WrapError(err, "falha ao gravar calendário")
+	}
+	return nil
+}
+
+// ListHolidays lista os feriados cadastrados pela empresa
+func (r *calendarRepository) ListHolidays(ctx context.Context) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	if err := r.db.WithContext(ctx).Order("date ASC, id ASC").Find(&holidays).Error; err != nil {
+		r.logger.Error("erro ao listar feriados", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar feriados")
+	}
+	return holidays, nil
+}
+
+// GetHoliday busca um feriado cadastrado
+func (r *calendarRepository) GetHoliday(ctx context.Context, id int) (*models.Holiday, error) {
+	var holiday models.Holiday
+	if err := r.db.WithContext(ctx).First(&holiday, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrHolidayNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar feriado")
+	}
+	return &holiday, nil
+}
+
+// CreateHoliday grava o feriado; a data é única por UF (ou sem UF) na empresa
+func (r *calendarRepository) CreateHoliday(ctx context.Context, holiday *models.Holiday) error {
+	if err := r.checkDate(ctx, holiday); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(holiday).Error; err != nil {
+		r.logger.Error("erro ao criar feriado", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar feriado")
+	}
+	return nil
+}
+
+// UpdateHoliday altera o feriado
+func (r *calendarRepository) UpdateHoliday(ctx context.Context, holiday *models.Holiday) error {
+	if err := r.checkDate(ctx, holiday); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(holiday).
+		Select("date", "name", "state", "recurring", "updated_at").
+		Updates(holiday)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar feriado", zap.Error(result.Error), zap.Int("id", holiday.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar feriado")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrHolidayNotFound
+	}
+	return nil
+}
+
+// DeleteHoliday remove o feriado
+func (r *calendarRepository) DeleteHoliday(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.Holiday{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover feriado", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover feriado")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrHolidayNotFound
+	}
+	return nil
+}
+
+func (r *calendarRepository) checkDate(ctx context.Context, holiday *models.Holiday) error {
+	query := r.db.WithContext(ctx).Model(&models.Holiday{}).
+		Where("date = ? AND id <> ?", holiday.Date, holiday.ID)
+	if holiday.State == nil {
+		query = query.Where("state IS NULL")
+	} else {
+		query = query.Where("state = ?", *holiday.State)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar feriado")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateHoliday
+	}
+	return nil
+}
+
+// Schedule monta o calendário de dias úteis da empresa
+func (r *calendarRepository) Schedule(ctx context.Context) (*models.Schedule, error) {
+	return LoadSchedule(r.db.WithContext(ctx))
+}
+
+// LoadSchedule monta o calendário de dias úteis da empresa dentro de uma transação de outro
+// módulo (vencimento das faturas, prazos de SLA); a transação deve carregar o contexto da empresa
+func LoadSchedule(tx *gorm.DB) (*models.Schedule, error) {
+	calendar, err := loadCalendar(tx)
+	if err != nil {
+		return nil, err
+	}
+	var holidays []models.Holiday
+	if err := tx.Find(&holidays).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao carregar feriados")
+	}
+	return models.NewSchedule(*calendar, holidays), nil
+}
+
+func loadCalendar(tx *gorm.DB) (*models.Calendar, error) {
+	var calendar models.Calendar
+	if err := tx.First(&calendar).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			calendar = models.DefaultCalendar()
+			return &calendar, nil
+		}
+		return nil, errors.WrapError(err, "falha ao buscar calendário")
+	}
+	return &calendar, nil
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/calendar/models"
+	"ERP-ONSMART/backend/internal/modules/calendar/repository"
+
+	"go.uber.org/zap"
+)
+
+const dateLayout = "2006-01-02"
+
+// CalendarService mantém o calendário de expediente e os feriados da empresa e faz as contas de
+// dias úteis
+type CalendarService struct {
+	newRepo func() (repository.CalendarRepository, error)
+	now     func() time.Time
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.CalendarRepository
+}
+
+// NewCalendarService cria o serviço sobre o repositório informado
+func NewCalendarService(newRepo func() (repository.CalendarRepository, error)) *CalendarService {
+	return &CalendarService{
+		newRepo: newRepo,
+		now:     time.Now,
+		logger:  logger.WithModule("calendar_service"),
+	}
+}
+
+var defaultService = NewCalendarService(repository.NewCalendarRepository)
+
+// GetCalendar retorna o calendário da empresa
+func GetCalendar(ctx context.Context) (*models.Calendar, error) {
+	return defaultService.GetCalendar(ctx)
+}
+
+// UpdateCalendar valida e grava o calendário da empresa
+func UpdateCalendar(ctx context.Context, input models.CalendarInput) (*models.Calendar, error) {
+	return defaultService.UpdateCalendar(ctx, input)
+}
+
+// ListHolidays lista os feriados que valem para a empresa no ano
+func ListHolidays(ctx context.Context, year int) ([]models.HolidayEntry, error) {
+	return defaultService.ListHolidays(ctx, year)
+}
+
+// CreateHoliday valida e grava um feriado
+func CreateHoliday(ctx context.Context, input models.HolidayInput) (*models.Holiday, error) {
+	return defaultService.CreateHoliday(ctx, input)
+}
+
+// UpdateHoliday valida e altera um feriado
+func UpdateHoliday(ctx context.Context, id int, input models.HolidayInput) (*models.Holiday, error) {
+	return defaultService.UpdateHoliday(ctx, id, input)
+}
+
+// DeleteHoliday remove um feriado
+func DeleteHoliday(ctx context.Context, id int) error {
+	return defaultService.DeleteHoliday(ctx, id)
+}
+
+// Schedule monta o calendário de dias úteis da empresa do contexto
+func Schedule(ctx context.Context) (*models.Schedule, error) {
+	return defaultService.Schedule(ctx)
+}
+
+// AddBusinessDays soma dias úteis à data pelo calendário da empresa
+func AddBusinessDays(ctx context.Context, date time.Time, days int) (time.Time, error) {
+	return defaultService.AddBusinessDays(ctx, date, days)
+}
+
+func (s *CalendarService) repository() (repository.CalendarRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// GetCalendar retorna o calendário da empresa (o padrão, se ela não configurou o seu)
+func (s *CalendarService) GetCalendar(ctx context.Context) (*models.Calendar, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetCalendar(ctx)
+}
+
+// UpdateCalendar grava os dias úteis, o horário de funcionamento e a UF do calendário
+func (s *CalendarService) UpdateCalendar(ctx context.Context, input models.CalendarInput) (*models.Calendar, error) {
+	opens, err := models.ParseClock(input.OpensAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidCalendar, err)
+	}
+	closes, err := models.ParseClock(input.ClosesAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidCalendar, err)
+	}
+	if closes <= opens {
+		return nil, fmt.Errorf("%w: o fechamento deve ser depois da abertura", errors.ErrInvalidCalendar)
+	}
+
+	seen := map[int]bool{}
+	workingDays := []int{}
+	for _, day := range input.WorkingDays {
+		if day < 0 || day > 6 {
+			return nil, fmt.Errorf("%w: dia da semana %d inválido, use 0 (domingo) a 6 (sábado)", errors.ErrInvalidCalendar, day)
+		}
+		if !seen[day] {
+			seen[day] = true
+			workingDays = append(workingDays, day)
+		}
+	}
+	if len(workingDays) == 0 {
+		return nil, fmt.Errorf("%w: informe ao menos um dia útil", errors.ErrInvalidCalendar)
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := repo.GetCalendar(ctx)
+	if err != nil {
+		return nil, err
+	}
+	calendar.State = normalizeState(input.State)
+	calendar.WorkingDays = workingDays
+	calendar.OpensAt = fmt.Sprintf("%02d:%02d", opens/60, opens%60)
+	calendar.ClosesAt = fmt.Sprintf("%02d:%02d", closes/60, closes%60)
+	if err := repo.SaveCalendar(ctx, calendar); err != nil {
+		return nil, err
+	}
+	return calendar, nil
+}
+
+// ListHolidays lista os feriados nacionais e os cadastrados que valem para o calendário no ano
+// (padrão: ano corrente)
+func (s *CalendarService) ListHolidays(ctx context.Context, year int) ([]models.HolidayEntry, error) {
+	if year == 0 {
+		year = s.now().Year()
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := repo.GetCalendar(ctx)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := repo.ListHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.Holidays(year, *calendar, holidays), nil
+}
+
+// CreateHoliday grava o feriado
+func (s *CalendarService) CreateHoliday(ctx context.Context, input models.HolidayInput) (*models.Holiday, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	holiday := &models.Holiday{}
+	if err := applyHolidayInput(holiday, input); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateHoliday(ctx, holiday); err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// UpdateHoliday altera o feriado
+func (s *CalendarService) UpdateHoliday(ctx context.Context, id int, input models.HolidayInput) (*models.Holiday, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	holiday, err := repo.GetHoliday(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyHolidayInput(holiday, input); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateHoliday(ctx, holiday); err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// DeleteHoliday remove o feriado
+func (s *CalendarService) DeleteHoliday(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteHoliday(ctx, id)
+}
+
+// Schedule monta o calendário de dias úteis da empresa do contexto
+func (s *CalendarService) Schedule(ctx context.Context) (*models.Schedule, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Schedule(ctx)
+}
+
+// AddBusinessDays soma dias úteis à data pelo calendário da empresa
+func (s *CalendarService) AddBusinessDays(ctx context.Context, date time.Time, days int) (time.Time, error) {
+	if days < 0 {
+		return time.Time{}, errors.InvalidParam("days não pode ser negativo")
+	}
+	schedule, err := s.Schedule(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.AddBusinessDays(date, days), nil
+}
+
+func applyHolidayInput(holiday *models.Holiday, input models.HolidayInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: informe o nome do feriado", errors.ErrInvalidCalendar)
+	}
+	date, err := time.Parse(dateLayout, strings.TrimSpace(input.Date))
+	if err != nil {
+		return fmt.Errorf("%w: data %q inválida, use o formato AAAA-MM-DD", errors.ErrInvalidCalendar, input.Date)
+	}
+
+	holiday.Date = date
+	holiday.Name = name
+	holiday.State = normalizeState(input.State)
+	holiday.Recurring = input.Recurring
+	return nil
+}
+
+func normalizeState(state string) *string {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if state == "" {
+		return nil
+	}
+	return &state
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/calendar/models"
+	"ERP-ONSMART/backend/internal/modules/calendar/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCalendarRepo struct {
+	calendar *models.Calendar
+	holidays []models.Holiday
+	saved    *models.Calendar
+}
+
+func (r *fakeCalendarRepo) GetCalendar(ctx context.Context) (*models.Calendar, error) {
+	if r.calendar == nil {
+		calendar := models.DefaultCalendar()
+		return &calendar, nil
+	}
+	calendar := *r.calendar
+	return &calendar, nil
+}
+
+func (r *fakeCalendarRepo) SaveCalendar(ctx context.Context, calendar *models.Calendar) error {
+	r.saved = calendar
+	return nil
+}
+
+func (r *fakeCalendarRepo) ListHolidays(ctx context.Context) ([]models.Holiday, error) {
+	return r.holidays, nil
+}
+
+func (r *fakeCalendarRepo) GetHoliday(ctx context.Context, id int) (*models.Holiday, error) {
+	for i := range r.holidays {
+		if r.holidays[i].ID == id {
+			holiday := r.holidays[i]
+			return &holiday, nil
+		}
+	}
+	return nil, appErrors.ErrHolidayNotFound
+}
+
+func (r *fakeCalendarRepo) CreateHoliday(ctx context.Context, holiday *models.Holiday) error {
+	holiday.ID = len(r.holidays) + 1
+	r.holidays = append(r.holidays, *holiday)
+	return nil
+}
+
+func (r *fakeCalendarRepo) UpdateHoliday(ctx context.Context, holiday *models.Holiday) error {
+	return nil
+}
+
+func (r *fakeCalendarRepo) DeleteHoliday(ctx context.Context, id int) error {
+	return nil
+}
+
+func (r *fakeCalendarRepo) Schedule(ctx context.Context) (*models.Schedule, error) {
+	calendar, _ := r.GetCalendar(ctx)
+	return models.NewSchedule(*calendar, r.holidays), nil
+}
+
+func newTestService(repo *fakeCalendarRepo, now time.Time) *CalendarService {
+	s := NewCalendarService(func() (repository.CalendarRepository, error) { return repo, nil })
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestUpdateCalendarNormalizesInput(t *testing.T) {
+	repo := &fakeCalendarRepo{}
+	s := newTestService(repo, time.Now())
+
+	calendar, err := s.UpdateCalendar(context.Background(), models.CalendarInput{
+		State: "sp", WorkingDays: []int{1, 2, 2, 6}, OpensAt: "7:30", ClosesAt: "17:00",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, repo.saved)
+	assert.Equal(t, "SP", *calendar.State)
+	assert.Equal(t, []int{1, 2, 6}, calendar.WorkingDays)
+	assert.Equal(t, "07:30", calendar.OpensAt)
+}
+
+func TestUpdateCalendarRejectsInvalidHours(t *testing.T) {
+	s := newTestService(&fakeCalendarRepo{}, time.Now())
+
+	_, err := s.UpdateCalendar(context.Background(), models.CalendarInput{WorkingDays: []int{1}, OpensAt: "18:00", ClosesAt: "08:00"})
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidCalendar))
+
+	_, err = s.UpdateCalendar(context.Background(), models.CalendarInput{WorkingDays: []int{7}, OpensAt: "08:00", ClosesAt: "18:00"})
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidCalendar))
+}
+
+func TestAddBusinessDaysUsesRegisteredHolidays(t *testing.T) {
+	repo := &fakeCalendarRepo{}
+	s := newTestService(repo, time.Now())
+
+	_, err := s.CreateHoliday(context.Background(), models.HolidayInput{Date: "2024-05-17", Name: "Feriado municipal"})
+	require.NoError(t, err)
+
+	due, err := s.AddBusinessDays(context.Background(), time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-20", due.Format(dateLayout))
+}
+
+func TestListHolidaysDefaultsToCurrentYear(t *testing.T) {
+	s := newTestService(&fakeCalendarRepo{}, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	holidays, err := s.ListHolidays(context.Background(), 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, holidays)
+	assert.Equal(t, "2025-01-01", holidays[0].Date)
+}
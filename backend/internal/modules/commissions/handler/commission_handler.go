@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/commissions/models"
+	"ERP-ONSMART/backend/internal/modules/commissions/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as regras de comissão
+// @Security BearerAuth
+func ListCommissionRulesHandler(c *gin.Context) {
+	rules, err := service.GetRules(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar regras de comissão")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// Cria uma regra de comissão (percentual por categoria, escalonado pelo atingimento da meta)
+// @Security BearerAuth
+func CreateCommissionRuleHandler(c *gin.Context) {
+	var input service.RuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	rule, err := service.CreateRule(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar regra de comissão")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rule": rule})
+}
+
+// Atualiza uma regra de comissão
+// @Security BearerAuth
+func UpdateCommissionRuleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.RuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	rule, err := service.UpdateRule(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar regra de comissão")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+// Remove uma regra de comissão
+// @Security BearerAuth
+func DeleteCommissionRuleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteRule(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover regra de comissão")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "regra de comissão removida"})
+}
+
+// Lista as metas de vendas (filtro opcional ?period=AAAA-MM)
+// @Security BearerAuth
+func ListSalesQuotasHandler(c *gin.Context) {
+	quotas, err := service.GetQuotas(c.Request.Context(), c.Query("period"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar metas de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotas": quotas})
+}
+
+// Define a meta de vendas de um vendedor no período
+// @Security BearerAuth
+func SetSalesQuotaHandler(c *gin.Context) {
+	var quota models.SalesQuota
+	if err := c.ShouldBindJSON(&quota); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.SetQuota(c.Request.Context(), &quota); err != nil {
+		c.Error(err).SetMeta("erro ao gravar meta de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quota": quota})
+}
+
+// Calcula (ou recalcula) as comissões do período enquanto ele estiver aberto
+// @Security BearerAuth
+func CalculateCommissionPeriodHandler(c *gin.Context) {
+	period, err := service.CalculatePeriod(c.Request.Context(), c.Param("period"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao calcular comissões do período")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"period": period})
+}
+
+// Retorna o período de comissão com os extratos dos vendedores
+// @Security BearerAuth
+func GetCommissionPeriodHandler(c *gin.Context) {
+	period, err := service.GetPeriod(c.Request.Context(), c.Param("period"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar período de comissão")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"period": period})
+}
+
+// Aprova as comissões calculadas do período
+// O aprovador é o usuário autenticado (administrador).
+// @Security BearerAuth
+func ApproveCommissionPeriodHandler(c *gin.Context) {
+	period, err := service.ApprovePeriod(c.Request.Context(), c.Param("period"), middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao aprovar comissões do período")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"period": period})
+}
+
+// Fecha o período de comissão aprovado
+// O responsável pelo fechamento é o usuário autenticado (administrador).
+// @Security BearerAuth
+func CloseCommissionPeriodHandler(c *gin.Context) {
+	period, err := service.ClosePeriod(c.Request.Context(), c.Param("period"), middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao fechar período de comissão")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"period": period})
+}
+
+// Lista os extratos de comissão (filtros opcionais ?period=AAAA-MM&user_id=)
+// @Security BearerAuth
+func ListCommissionStatementsHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	var userID int
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("user_id inválido"))
+			return
+		}
+		userID = id
+	}
+
+	result, err := service.GetStatements(c.Request.Context(), c.Query("period"), userID, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar extratos de comissão")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna o extrato de comissão com as linhas por item faturado
+// @Security BearerAuth
+func GetCommissionStatementHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	statement, err := service.GetStatement(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar extrato de comissão")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statement": statement})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
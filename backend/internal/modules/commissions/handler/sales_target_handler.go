@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/commissions/models"
+	"ERP-ONSMART/backend/internal/modules/commissions/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as equipes de vendas com os vendedores
+// @Security BearerAuth
+func ListSalesTeamsHandler(c *gin.Context) {
+	teams, err := service.ListTeams(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar equipes de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"teams": teams})
+}
+
+// Retorna a equipe de vendas com os vendedores
+// @Param id path int true "ID da equipe"
+// @Security BearerAuth
+func GetSalesTeamHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	team, err := service.GetTeam(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar equipe de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"team": team})
+}
+
+// Cria uma equipe de vendas; member_ids são os usuários vendedores da equipe
+// @Security BearerAuth
+func CreateSalesTeamHandler(c *gin.Context) {
+	var input models.SalesTeamInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	team, err := service.CreateTeam(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar equipe de vendas")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"team": team})
+}
+
+// Atualiza a equipe de vendas; member_ids substitui os vendedores da equipe
+// @Param id path int true "ID da equipe"
+// @Security BearerAuth
+func UpdateSalesTeamHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.SalesTeamInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	team, err := service.UpdateTeam(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar equipe de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"team": team})
+}
+
+// Remove a equipe de vendas e as metas da equipe
+// @Param id path int true "ID da equipe"
+// @Security BearerAuth
+func DeleteSalesTeamHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteTeam(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover equipe de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "equipe de vendas removida"})
+}
+
+// Lista as metas de vendas por vendedor, equipe e linha de produto
+// @Param period query string false "Período: AAAA-MM (mensal) ou AAAA-Qn (trimestral)"
+// @Param scope query string false "Escopo: salesperson, team ou product_line"
+// @Security BearerAuth
+func ListSalesTargetsHandler(c *gin.Context) {
+	targets, err := service.ListTargets(c.Request.Context(), c.Query("period"), c.Query("scope"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar metas de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"targets": targets})
+}
+
+// Define a meta mensal ou trimestral de um vendedor, de uma equipe ou de uma linha de produto
+// (grupo do produto); a meta já definida para o escopo no período tem o valor substituído
+// @Security BearerAuth
+func SetSalesTargetHandler(c *gin.Context) {
+	var input models.SalesTargetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	target, err := service.SetTarget(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar meta de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"target": target})
+}
+
+// Remove a meta de vendas
+// @Param id path int true "ID da meta"
+// @Security BearerAuth
+func DeleteSalesTargetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteTarget(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover meta de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "meta de vendas removida"})
+}
+
+// Painel de atingimento das metas do período: faturado, funil ponderado do CRM, projeção, situação
+// pelo ritmo do período e classificação dos participantes de cada escopo. Sem período, usa o mês
+// corrente.
+// @Param period query string false "Período: AAAA-MM (mensal) ou AAAA-Qn (trimestral)"
+// @Param scope query string false "Escopo: salesperson, team ou product_line"
+// @Security BearerAuth
+func GetSalesTargetAttainmentHandler(c *gin.Context) {
+	dashboard, err := service.GetAttainment(c.Request.Context(), c.Query("period"), c.Query("scope"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao calcular atingimento das metas")
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
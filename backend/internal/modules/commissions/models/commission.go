@@ -0,0 +1,212 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PeriodLayout é o formato dos períodos de comissão e das metas (AAAA-MM)
+const PeriodLayout = "2006-01"
+
+// Status do período de comissão
+const (
+	PeriodStatusOpen     = "open"
+	PeriodStatusApproved = "approved"
+	PeriodStatusClosed   = "closed"
+)
+
+// CommissionRule define o percentual de comissão de uma categoria de produto a partir
+// de um atingimento mínimo da meta. Sem categoria, a regra vale para todos os produtos.
+type CommissionRule struct {
+	ID              int       `json:"id" gorm:"primaryKey"`
+	CompanyID       int       `json:"company_id" gorm:"<-:create"`
+	Name            string    `json:"name"`
+	ProductCategory *string   `json:"product_category,omitempty"`
+	MinAttainment   float64   `json:"min_attainment"`
+	Rate            float64   `json:"rate"`
+	Active          bool      `json:"active"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// SalesQuota é a meta mensal de vendas de um vendedor
+type SalesQuota struct {
+	ID           int           `json:"id" gorm:"primaryKey"`
+	CompanyID    int           `json:"company_id" gorm:"<-:create"`
+	UserID       int           `json:"user_id" binding:"required"`
+	Period       string        `json:"period" binding:"required"`
+	TargetAmount money.Decimal `json:"target_amount" binding:"gte=0"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CommissionPeriod controla o cálculo, a aprovação e o fechamento das comissões do mês
+type CommissionPeriod struct {
+	ID           int                   `json:"id" gorm:"primaryKey"`
+	CompanyID    int                   `json:"company_id" gorm:"<-:create"`
+	Period       string                `json:"period"`
+	Status       string                `json:"status" gorm:"default:open"`
+	CalculatedAt *time.Time            `json:"calculated_at,omitempty"`
+	ApprovedBy   string                `json:"approved_by,omitempty"`
+	ApprovedAt   *time.Time            `json:"approved_at,omitempty"`
+	ClosedBy     string                `json:"closed_by,omitempty"`
+	ClosedAt     *time.Time            `json:"closed_at,omitempty"`
+	CreatedAt    time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+	Statements   []CommissionStatement `json:"statements,omitempty" gorm:"foreignKey:PeriodID"`
+}
+
+// CommissionStatement é o extrato mensal de comissão de um vendedor
+type CommissionStatement struct {
+	ID               int                       `json:"id" gorm:"primaryKey"`
+	CompanyID        int                       `json:"company_id" gorm:"<-:create"`
+	PeriodID         int                       `json:"period_id"`
+	UserID           int                       `json:"user_id"`
+	SalesAmount      money.Decimal             `json:"sales_amount"`
+	QuotaAmount      money.Decimal             `json:"quota_amount"`
+	Attainment       float64                   `json:"attainment"`
+	CommissionAmount money.Decimal             `json:"commission_amount"`
+	CreatedAt        time.Time                 `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time                 `json:"updated_at" gorm:"autoUpdateTime"`
+	Period           *CommissionPeriod         `json:"period,omitempty" gorm:"foreignKey:PeriodID"`
+	Lines            []CommissionStatementLine `json:"lines,omitempty" gorm:"foreignKey:StatementID"`
+}
+
+// CommissionStatementLine é a comissão de um item faturado
+type CommissionStatementLine struct {
+	ID              int           `json:"id" gorm:"primaryKey"`
+	StatementID     int           `json:"statement_id"`
+	InvoiceID       int           `json:"invoice_id"`
+	InvoiceItemID   int           `json:"invoice_item_id"`
+	ProductID       int           `json:"product_id"`
+	ProductCategory string        `json:"product_category,omitempty"`
+	RuleID          *int          `json:"rule_id,omitempty"`
+	Amount          money.Decimal `json:"amount"`
+	Rate            float64       `json:"rate"`
+	Commission      money.Decimal `json:"commission"`
+}
+
+// InvoicedLine é um item faturado por um vendedor no período, base do cálculo da comissão
+type InvoicedLine struct {
+	InvoiceID       int
+	InvoiceItemID   int
+	SalespersonID   int
+	ProductID       int
+	ProductCategory string
+	Amount          money.Decimal
+}
+
+// ParsePeriod valida o período (AAAA-MM) e retorna o primeiro instante do mês e do mês seguinte
+func ParsePeriod(period string) (time.Time, time.Time, error) {
+	start, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.ErrInvalidCommissionPeriod
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// Attainment retorna o percentual de atingimento da meta; sem meta, o atingimento é zero
+func Attainment(sales, quota money.Decimal) float64 {
+	if !quota.IsPositive() {
+		return 0
+	}
+	return round2(sales.Float64() / quota.Float64() * 100)
+}
+
+// SelectRule escolhe a regra aplicável ao item: regras da categoria têm prioridade sobre as gerais
+// e, entre elas, vale a de maior atingimento mínimo já alcançado
+func SelectRule(rules []CommissionRule, category string, attainment float64) *CommissionRule {
+	var selected *CommissionRule
+	selectedSpecific := false
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Active || rule.MinAttainment > attainment {
+			continue
+		}
+
+		specific := rule.ProductCategory != nil && *rule.ProductCategory != ""
+		if specific && !strings.EqualFold(*rule.ProductCategory, category) {
+			continue
+		}
+
+		switch {
+		case selected == nil,
+			specific && !selectedSpecific,
+			specific == selectedSpecific && rule.MinAttainment > selected.MinAttainment:
+			selected = rule
+			selectedSpecific = specific
+		}
+	}
+
+	return selected
+}
+
+// BuildStatements agrupa os itens faturados por vendedor, calcula o atingimento da meta
+// e aplica a regra de comissão de cada item
+func BuildStatements(lines []InvoicedLine, quotas map[int]money.Decimal, rules []CommissionRule) []CommissionStatement {
+	byUser := make(map[int]*CommissionStatement)
+	for _, line := range lines {
+		statement, ok := byUser[line.SalespersonID]
+		if !ok {
+			statement = &CommissionStatement{UserID: line.SalespersonID}
+			byUser[line.SalespersonID] = statement
+		}
+		statement.SalesAmount = statement.SalesAmount.Add(line.Amount)
+	}
+
+	for userID, statement := range byUser {
+		statement.QuotaAmount = quotas[userID]
+		statement.Attainment = Attainment(statement.SalesAmount, statement.QuotaAmount)
+	}
+
+	for _, line := range lines {
+		statement := byUser[line.SalespersonID]
+
+		commissionLine := CommissionStatementLine{
+			InvoiceID:       line.InvoiceID,
+			InvoiceItemID:   line.InvoiceItemID,
+			ProductID:       line.ProductID,
+			ProductCategory: line.ProductCategory,
+			Amount:          line.Amount,
+		}
+		if rule := SelectRule(rules, line.ProductCategory, statement.Attainment); rule != nil {
+			ruleID := rule.ID
+			commissionLine.RuleID = &ruleID
+			commissionLine.Rate = rule.Rate
+			commissionLine.Commission = money.Round(line.Amount.MulFloat(rule.Rate / 100))
+		}
+
+		statement.Lines = append(statement.Lines, commissionLine)
+		statement.CommissionAmount = statement.CommissionAmount.Add(commissionLine.Commission)
+	}
+
+	statements := make([]CommissionStatement, 0, len(byUser))
+	for _, statement := range byUser {
+		statements = append(statements, *statement)
+	}
+	sort.Slice(statements, func(i, j int) bool {
+		return statements[i].UserID < statements[j].UserID
+	})
+
+	return statements
+}
+
+// CanTransition indica se o período pode passar do status atual para o novo status
+func CanTransition(from, to string) bool {
+	switch to {
+	case PeriodStatusApproved:
+		return from == PeriodStatusOpen
+	case PeriodStatusClosed:
+		return from == PeriodStatusApproved
+	}
+	return false
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
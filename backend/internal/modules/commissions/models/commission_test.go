@@ -0,0 +1,104 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(v string) *string {
+	return &v
+}
+
+func TestParsePeriod(t *testing.T) {
+	start, end, err := ParsePeriod("2024-12")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	_, _, err = ParsePeriod("2024-13")
+	assert.Equal(t, errors.ErrInvalidCommissionPeriod, err)
+	_, _, err = ParsePeriod("12/2024")
+	assert.Equal(t, errors.ErrInvalidCommissionPeriod, err)
+}
+
+func TestAttainment(t *testing.T) {
+	assert.Equal(t, 125.0, Attainment(money.FromInt(12500), money.FromInt(10000)))
+	assert.Equal(t, 33.33, Attainment(money.FromInt(1000), money.FromInt(3000)))
+	// Sem meta cadastrada, só valem as regras sem atingimento mínimo
+	assert.Equal(t, 0.0, Attainment(money.FromInt(5000), money.Zero))
+}
+
+func TestSelectRule(t *testing.T) {
+	rules := []CommissionRule{
+		{ID: 1, MinAttainment: 0, Rate: 2, Active: true},
+		{ID: 2, MinAttainment: 100, Rate: 4, Active: true},
+		{ID: 3, ProductCategory: strPtr("Hardware"), MinAttainment: 0, Rate: 3, Active: true},
+		{ID: 4, ProductCategory: strPtr("Hardware"), MinAttainment: 120, Rate: 6, Active: true},
+		{ID: 5, MinAttainment: 50, Rate: 10, Active: false},
+	}
+
+	assert.Equal(t, 1, SelectRule(rules, "Software", 80).ID)
+	assert.Equal(t, 2, SelectRule(rules, "Software", 100).ID)
+
+	// Regras da categoria têm prioridade sobre as gerais, mesmo com atingimento menor
+	assert.Equal(t, 3, SelectRule(rules, "hardware", 110).ID)
+	assert.Equal(t, 4, SelectRule(rules, "Hardware", 130).ID)
+
+	assert.Nil(t, SelectRule(rules[1:2], "Software", 90))
+	assert.Nil(t, SelectRule(nil, "Software", 90))
+}
+
+func TestBuildStatements(t *testing.T) {
+	rules := []CommissionRule{
+		{ID: 1, MinAttainment: 0, Rate: 2, Active: true},
+		{ID: 2, MinAttainment: 100, Rate: 5, Active: true},
+		{ID: 3, ProductCategory: strPtr("Serviços"), MinAttainment: 0, Rate: 10, Active: true},
+	}
+	lines := []InvoicedLine{
+		{InvoiceID: 1, InvoiceItemID: 1, SalespersonID: 7, ProductID: 1, ProductCategory: "Hardware", Amount: money.FromInt(800)},
+		{InvoiceID: 1, InvoiceItemID: 2, SalespersonID: 7, ProductID: 2, ProductCategory: "Serviços", Amount: money.FromInt(400)},
+		{InvoiceID: 2, InvoiceItemID: 3, SalespersonID: 3, ProductID: 1, ProductCategory: "Hardware", Amount: money.FromInt(500)},
+	}
+	quotas := map[int]money.Decimal{7: money.FromInt(1000), 3: money.FromInt(1000)}
+
+	statements := BuildStatements(lines, quotas, rules)
+	require.Len(t, statements, 2)
+
+	first := statements[0]
+	assert.Equal(t, 3, first.UserID)
+	assert.Equal(t, 50.0, first.Attainment)
+	assert.Equal(t, "10.00", first.CommissionAmount.String())
+
+	second := statements[1]
+	assert.Equal(t, 7, second.UserID)
+	assert.Equal(t, "1200.00", second.SalesAmount.String())
+	assert.Equal(t, 120.0, second.Attainment)
+	require.Len(t, second.Lines, 2)
+	assert.Equal(t, 5.0, second.Lines[0].Rate)
+	assert.Equal(t, "40.00", second.Lines[0].Commission.String())
+	assert.Equal(t, 10.0, second.Lines[1].Rate)
+	assert.Equal(t, "80.00", second.CommissionAmount.String())
+}
+
+func TestBuildStatementsWithoutRule(t *testing.T) {
+	lines := []InvoicedLine{{InvoiceID: 1, InvoiceItemID: 1, SalespersonID: 1, Amount: money.FromInt(300)}}
+
+	statements := BuildStatements(lines, nil, nil)
+	require.Len(t, statements, 1)
+	assert.Nil(t, statements[0].Lines[0].RuleID)
+	assert.True(t, statements[0].CommissionAmount.IsZero())
+	assert.Equal(t, "300.00", statements[0].SalesAmount.String())
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(PeriodStatusOpen, PeriodStatusApproved))
+	assert.True(t, CanTransition(PeriodStatusApproved, PeriodStatusClosed))
+	assert.False(t, CanTransition(PeriodStatusOpen, PeriodStatusClosed))
+	assert.False(t, CanTransition(PeriodStatusClosed, PeriodStatusApproved))
+	assert.False(t, CanTransition(PeriodStatusApproved, PeriodStatusOpen))
+}
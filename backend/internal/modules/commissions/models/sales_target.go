@@ -0,0 +1,399 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Escopos das metas de vendas
+const (
+	TargetScopeSalesperson = "salesperson"
+	TargetScopeTeam        = "team"
+	TargetScopeProductLine = "product_line"
+)
+
+// Tipos de período das metas
+const (
+	TargetPeriodMonthly   = "monthly"
+	TargetPeriodQuarterly = "quarterly"
+)
+
+// Situação da meta no painel de atingimento
+const (
+	TargetStatusAchieved = "achieved"
+	TargetStatusOnTrack  = "on_track"
+	TargetStatusAtRisk   = "at_risk"
+	TargetStatusBehind   = "behind"
+)
+
+// targetScopeOrder é a ordem dos escopos no painel
+var targetScopeOrder = map[string]int{
+	TargetScopeSalesperson: 0,
+	TargetScopeTeam:        1,
+	TargetScopeProductLine: 2,
+}
+
+// SalesTeam agrupa vendedores; a meta da equipe é comparada com a soma das vendas dos membros
+type SalesTeam struct {
+	ID        int               `json:"id" gorm:"primaryKey"`
+	CompanyID int               `json:"company_id" gorm:"<-:create"`
+	Name      string            `json:"name"`
+	LeaderID  *int              `json:"leader_id,omitempty"`
+	Active    bool              `json:"active" gorm:"default:true"`
+	CreatedAt time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+	Members   []SalesTeamMember `json:"members,omitempty" gorm:"foreignKey:TeamID"`
+}
+
+// TableName define o nome da tabela de equipes de vendas
+func (SalesTeam) TableName() string {
+	return "sales_teams"
+}
+
+// SalesTeamMember é um vendedor da equipe
+type SalesTeamMember struct {
+	ID     int `json:"id" gorm:"primaryKey"`
+	TeamID int `json:"team_id"`
+	UserID int `json:"user_id"`
+}
+
+// TableName define o nome da tabela de membros das equipes de vendas
+func (SalesTeamMember) TableName() string {
+	return "sales_team_members"
+}
+
+// MemberIDs retorna os vendedores da equipe
+func (t SalesTeam) MemberIDs() []int {
+	ids := make([]int, 0, len(t.Members))
+	for _, member := range t.Members {
+		ids = append(ids, member.UserID)
+	}
+	return ids
+}
+
+// SalesTeamInput reúne os dados de criação e atualização da equipe; member_ids substitui os membros
+type SalesTeamInput struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	LeaderID  *int   `json:"leader_id"`
+	MemberIDs []int  `json:"member_ids"`
+	Active    *bool  `json:"active"`
+}
+
+// ToTeam converte a entrada na equipe e nos vendedores, sem repetições
+func (in SalesTeamInput) ToTeam() (*SalesTeam, []int) {
+	team := &SalesTeam{
+		Name:     strings.TrimSpace(in.Name),
+		LeaderID: in.LeaderID,
+		Active:   in.Active == nil || *in.Active,
+	}
+	seen := make(map[int]bool, len(in.MemberIDs))
+	members := make([]int, 0, len(in.MemberIDs))
+	for _, id := range in.MemberIDs {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			members = append(members, id)
+		}
+	}
+	return team, members
+}
+
+// SalesTarget é a meta de vendas do período para um vendedor, uma equipe ou uma linha de produto
+// (grupo do produto). Diferente da meta de comissão (SalesQuota), serve ao acompanhamento e aceita
+// períodos trimestrais.
+type SalesTarget struct {
+	ID           int           `json:"id" gorm:"primaryKey"`
+	CompanyID    int           `json:"company_id" gorm:"<-:create"`
+	Scope        string        `json:"scope"`
+	UserID       *int          `json:"user_id,omitempty"`
+	TeamID       *int          `json:"team_id,omitempty"`
+	ProductLine  string        `json:"product_line,omitempty"`
+	Period       string        `json:"period"`
+	PeriodType   string        `json:"period_type"`
+	TargetAmount money.Decimal `json:"target_amount"`
+	UpdatedBy    string        `json:"updated_by"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	Team         *SalesTeam    `json:"team,omitempty" gorm:"foreignKey:TeamID"`
+}
+
+// TableName define o nome da tabela de metas de vendas
+func (SalesTarget) TableName() string {
+	return "sales_targets"
+}
+
+// SalesTargetInput define a meta do escopo no período; informe só a chave do escopo (user_id,
+// team_id ou product_line)
+type SalesTargetInput struct {
+	Scope        string         `json:"scope" binding:"required,oneof=salesperson team product_line"`
+	UserID       *int           `json:"user_id"`
+	TeamID       *int           `json:"team_id"`
+	ProductLine  string         `json:"product_line" binding:"max=100"`
+	Period       string         `json:"period" binding:"required"`
+	TargetAmount *money.Decimal `json:"target_amount" binding:"required,gte=0"`
+}
+
+// ToTarget valida a chave do escopo e o período e converte a entrada na meta
+func (in SalesTargetInput) ToTarget() (*SalesTarget, error) {
+	period, err := ParseTargetPeriod(in.Period)
+	if err != nil {
+		return nil, err
+	}
+	line := strings.TrimSpace(in.ProductLine)
+	hasUser := in.UserID != nil && *in.UserID > 0
+	hasTeam := in.TeamID != nil && *in.TeamID > 0
+
+	valid := false
+	switch in.Scope {
+	case TargetScopeSalesperson:
+		valid = hasUser && !hasTeam && line == ""
+	case TargetScopeTeam:
+		valid = hasTeam && !hasUser && line == ""
+	case TargetScopeProductLine:
+		valid = line != "" && !hasUser && !hasTeam
+	}
+	if !valid || in.TargetAmount == nil || in.TargetAmount.IsNegative() {
+		return nil, errors.ErrInvalidSalesTarget
+	}
+
+	target := &SalesTarget{
+		Scope:        in.Scope,
+		ProductLine:  line,
+		Period:       period.Period,
+		PeriodType:   period.Type,
+		TargetAmount: money.Round(*in.TargetAmount),
+	}
+	if hasUser {
+		target.UserID = in.UserID
+	}
+	if hasTeam {
+		target.TeamID = in.TeamID
+	}
+	return target, nil
+}
+
+// SalesTargetFilter filtra a lista de metas; campos vazios não filtram
+type SalesTargetFilter struct {
+	Period string
+	Scope  string
+}
+
+// TargetPeriod é o período de uma meta: o mês (AAAA-MM) ou o trimestre (AAAA-Qn), de Start
+// (inclusive) a End (exclusive)
+type TargetPeriod struct {
+	Period string
+	Type   string
+	Start  time.Time
+	End    time.Time
+}
+
+// ParseTargetPeriod valida o período da meta: AAAA-MM para metas mensais ou AAAA-Qn (n de 1 a 4)
+// para trimestrais
+func ParseTargetPeriod(period string) (TargetPeriod, error) {
+	period = strings.ToUpper(strings.TrimSpace(period))
+	if start, err := time.Parse(PeriodLayout, period); err == nil {
+		return TargetPeriod{Period: period, Type: TargetPeriodMonthly, Start: start, End: start.AddDate(0, 1, 0)}, nil
+	}
+
+	year, quarter, ok := strings.Cut(period, "-Q")
+	if !ok || len(year) != 4 || len(quarter) != 1 {
+		return TargetPeriod{}, errors.ErrInvalidTargetPeriod
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return TargetPeriod{}, errors.ErrInvalidTargetPeriod
+	}
+	q, err := strconv.Atoi(quarter)
+	if err != nil || q < 1 || q > 4 {
+		return TargetPeriod{}, errors.ErrInvalidTargetPeriod
+	}
+	start := time.Date(y, time.Month(3*(q-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	return TargetPeriod{Period: fmt.Sprintf("%04d-Q%d", y, q), Type: TargetPeriodQuarterly, Start: start, End: start.AddDate(0, 3, 0)}, nil
+}
+
+// ElapsedPercent é a parte do período já decorrida em now, de 0 a 100
+func (p TargetPeriod) ElapsedPercent(now time.Time) float64 {
+	switch {
+	case !now.After(p.Start):
+		return 0
+	case !now.Before(p.End):
+		return 100
+	}
+	return round2(float64(now.Sub(p.Start)) / float64(p.End.Sub(p.Start)) * 100)
+}
+
+// ProductLineKey normaliza a linha de produto para comparar metas e grupos dos produtos sem
+// diferenciar maiúsculas e espaços nas pontas
+func ProductLineKey(line string) string {
+	return strings.ToLower(strings.TrimSpace(line))
+}
+
+// SalesFigures são valores do período por vendedor e por linha de produto (chave de
+// ProductLineKey): a receita faturada ou o funil ponderado
+type SalesFigures struct {
+	BySalesperson map[int]money.Decimal
+	ByProductLine map[string]money.Decimal
+}
+
+// TargetAttainment é o atingimento de uma meta: o faturado no período, o funil ponderado das
+// oportunidades abertas com fechamento previsto no período e a projeção somando os dois
+type TargetAttainment struct {
+	TargetID          int           `json:"target_id"`
+	Scope             string        `json:"scope"`
+	UserID            *int          `json:"user_id,omitempty"`
+	TeamID            *int          `json:"team_id,omitempty"`
+	ProductLine       string        `json:"product_line,omitempty"`
+	Name              string        `json:"name,omitempty"`
+	TargetAmount      money.Decimal `json:"target_amount"`
+	Invoiced          money.Decimal `json:"invoiced"`
+	WeightedPipeline  money.Decimal `json:"weighted_pipeline"`
+	Projected         money.Decimal `json:"projected"`
+	Remaining         money.Decimal `json:"remaining"`
+	AttainmentPercent float64       `json:"attainment_percent"`
+	ProjectedPercent  float64       `json:"projected_percent"`
+	Status            string        `json:"status"`
+	Rank              int           `json:"rank"`
+}
+
+// AttainmentSummary conta as metas do painel por situação
+type AttainmentSummary struct {
+	Targets  int `json:"targets"`
+	Achieved int `json:"achieved"`
+	OnTrack  int `json:"on_track"`
+	AtRisk   int `json:"at_risk"`
+	Behind   int `json:"behind"`
+}
+
+// AttainmentDashboard é o painel de atingimento das metas do período, com a classificação dos
+// participantes de cada escopo
+type AttainmentDashboard struct {
+	Period         string             `json:"period"`
+	PeriodType     string             `json:"period_type"`
+	From           string             `json:"from"`
+	To             string             `json:"to"`
+	ElapsedPercent float64            `json:"elapsed_percent"`
+	Summary        AttainmentSummary  `json:"summary"`
+	Targets        []TargetAttainment `json:"targets"`
+}
+
+// BuildAttainmentDashboard calcula o atingimento de cada meta do período. A equipe soma os
+// valores dos membros atuais; a linha de produto soma os itens faturados e previstos do grupo.
+//
+// A situação compara o atingimento com o ritmo do período: achieved com a meta batida, on_track
+// com o atingimento acompanhando a parte decorrida do período, at_risk quando só o funil ponderado
+// cobre o que falta e behind nos demais casos. O rank classifica as metas de cada escopo pelo
+// atingimento, com empates na mesma posição.
+func BuildAttainmentDashboard(period TargetPeriod, targets []SalesTarget, invoiced, pipeline SalesFigures, now time.Time) AttainmentDashboard {
+	dashboard := AttainmentDashboard{
+		Period:         period.Period,
+		PeriodType:     period.Type,
+		From:           period.Start.Format("2006-01-02"),
+		To:             period.End.AddDate(0, 0, -1).Format("2006-01-02"),
+		ElapsedPercent: period.ElapsedPercent(now),
+		Targets:        make([]TargetAttainment, 0, len(targets)),
+	}
+
+	for _, target := range targets {
+		entry := TargetAttainment{
+			TargetID:     target.ID,
+			Scope:        target.Scope,
+			UserID:       target.UserID,
+			TeamID:       target.TeamID,
+			ProductLine:  target.ProductLine,
+			TargetAmount: target.TargetAmount,
+		}
+		switch target.Scope {
+		case TargetScopeSalesperson:
+			if target.UserID != nil {
+				entry.Invoiced = invoiced.BySalesperson[*target.UserID]
+				entry.WeightedPipeline = pipeline.BySalesperson[*target.UserID]
+			}
+		case TargetScopeTeam:
+			if target.Team != nil {
+				entry.Name = target.Team.Name
+				for _, userID := range target.Team.MemberIDs() {
+					entry.Invoiced = entry.Invoiced.Add(invoiced.BySalesperson[userID])
+					entry.WeightedPipeline = entry.WeightedPipeline.Add(pipeline.BySalesperson[userID])
+				}
+			}
+		case TargetScopeProductLine:
+			entry.Name = target.ProductLine
+			key := ProductLineKey(target.ProductLine)
+			entry.Invoiced = invoiced.ByProductLine[key]
+			entry.WeightedPipeline = pipeline.ByProductLine[key]
+		}
+		entry.Invoiced = money.Round(entry.Invoiced)
+		entry.WeightedPipeline = money.Round(entry.WeightedPipeline)
+		entry.Projected = entry.Invoiced.Add(entry.WeightedPipeline)
+		if entry.Invoiced.LessThan(entry.TargetAmount) {
+			entry.Remaining = entry.TargetAmount.Sub(entry.Invoiced)
+		}
+		entry.AttainmentPercent = Attainment(entry.Invoiced, entry.TargetAmount)
+		entry.ProjectedPercent = Attainment(entry.Projected, entry.TargetAmount)
+		entry.Status = targetStatus(entry, dashboard.ElapsedPercent)
+
+		dashboard.Targets = append(dashboard.Targets, entry)
+		dashboard.Summary.add(entry.Status)
+	}
+
+	sort.SliceStable(dashboard.Targets, func(i, j int) bool {
+		a, b := dashboard.Targets[i], dashboard.Targets[j]
+		if a.Scope != b.Scope {
+			return targetScopeOrder[a.Scope] < targetScopeOrder[b.Scope]
+		}
+		if a.AttainmentPercent != b.AttainmentPercent {
+			return a.AttainmentPercent > b.AttainmentPercent
+		}
+		if !a.Invoiced.Equal(b.Invoiced) {
+			return a.Invoiced.GreaterThan(b.Invoiced)
+		}
+		return a.TargetID < b.TargetID
+	})
+	for i := range dashboard.Targets {
+		current := &dashboard.Targets[i]
+		switch {
+		case i == 0 || current.Scope != dashboard.Targets[i-1].Scope:
+			current.Rank = 1
+		case current.AttainmentPercent == dashboard.Targets[i-1].AttainmentPercent:
+			current.Rank = dashboard.Targets[i-1].Rank
+		default:
+			position := 1
+			for j := i - 1; j >= 0 && dashboard.Targets[j].Scope == current.Scope; j-- {
+				position++
+			}
+			current.Rank = position
+		}
+	}
+	return dashboard
+}
+
+// targetStatus classifica a meta pelo atingimento, pelo ritmo do período e pela projeção
+func targetStatus(entry TargetAttainment, elapsed float64) string {
+	switch {
+	case entry.Invoiced.GreaterThanOrEqual(entry.TargetAmount):
+		return TargetStatusAchieved
+	case entry.AttainmentPercent >= elapsed:
+		return TargetStatusOnTrack
+	case entry.Projected.GreaterThanOrEqual(entry.TargetAmount):
+		return TargetStatusAtRisk
+	}
+	return TargetStatusBehind
+}
+
+func (s *AttainmentSummary) add(status string) {
+	s.Targets++
+	switch status {
+	case TargetStatusAchieved:
+		s.Achieved++
+	case TargetStatusOnTrack:
+		s.OnTrack++
+	case TargetStatusAtRisk:
+		s.AtRisk++
+	case TargetStatusBehind:
+		s.Behind++
+	}
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func amountPtr(v int64) *money.Decimal {
+	amount := money.FromInt(v)
+	return &amount
+}
+
+func TestParseTargetPeriod(t *testing.T) {
+	month, err := ParseTargetPeriod("2026-10")
+	require.NoError(t, err)
+	assert.Equal(t, TargetPeriodMonthly, month.Type)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), month.Start)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), month.End)
+
+	quarter, err := ParseTargetPeriod("2026-q4")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-Q4", quarter.Period)
+	assert.Equal(t, TargetPeriodQuarterly, quarter.Type)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), quarter.Start)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), quarter.End)
+
+	for _, invalid := range []string{"2026-13", "2026-Q5", "2026-Q0", "26-Q1", "2026-T1", ""} {
+		_, err := ParseTargetPeriod(invalid)
+		assert.Equal(t, errors.ErrInvalidTargetPeriod, err, invalid)
+	}
+}
+
+func TestElapsedPercent(t *testing.T) {
+	period, err := ParseTargetPeriod("2026-04")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.0, period.ElapsedPercent(time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 50.0, period.ElapsedPercent(time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 100.0, period.ElapsedPercent(time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestSalesTargetInputToTarget(t *testing.T) {
+	target, err := SalesTargetInput{Scope: TargetScopeProductLine, ProductLine: " Notebooks ", Period: "2026-Q1", TargetAmount: amountPtr(50000)}.ToTarget()
+	require.NoError(t, err)
+	assert.Equal(t, "Notebooks", target.ProductLine)
+	assert.Equal(t, TargetPeriodQuarterly, target.PeriodType)
+	assert.Nil(t, target.UserID)
+
+	invalid := []SalesTargetInput{
+		{Scope: TargetScopeSalesperson, Period: "2026-01", TargetAmount: amountPtr(1000)},
+		{Scope: TargetScopeSalesperson, UserID: intPtr(3), TeamID: intPtr(1), Period: "2026-01", TargetAmount: amountPtr(1000)},
+		{Scope: TargetScopeTeam, TeamID: intPtr(1), ProductLine: "Notebooks", Period: "2026-01", TargetAmount: amountPtr(1000)},
+		{Scope: TargetScopeProductLine, ProductLine: "  ", Period: "2026-01", TargetAmount: amountPtr(1000)},
+	}
+	for _, input := range invalid {
+		_, err := input.ToTarget()
+		assert.Equal(t, errors.ErrInvalidSalesTarget, err)
+	}
+
+	_, err = SalesTargetInput{Scope: TargetScopeTeam, TeamID: intPtr(1), Period: "2026-1", TargetAmount: amountPtr(1000)}.ToTarget()
+	assert.Equal(t, errors.ErrInvalidTargetPeriod, err)
+}
+
+func TestSalesTeamInputToTeam(t *testing.T) {
+	team, members := SalesTeamInput{Name: " Sul ", MemberIDs: []int{4, 2, 4, 0}}.ToTeam()
+	assert.Equal(t, "Sul", team.Name)
+	assert.True(t, team.Active)
+	assert.Equal(t, []int{4, 2}, members)
+}
+
+func TestBuildAttainmentDashboard(t *testing.T) {
+	period, err := ParseTargetPeriod("2026-04")
+	require.NoError(t, err)
+	now := time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC) // metade do mês
+
+	targets := []SalesTarget{
+		{ID: 1, Scope: TargetScopeSalesperson, UserID: intPtr(10), TargetAmount: money.FromInt(10000)},
+		{ID: 2, Scope: TargetScopeSalesperson, UserID: intPtr(11), TargetAmount: money.FromInt(10000)},
+		{ID: 3, Scope: TargetScopeSalesperson, UserID: intPtr(12), TargetAmount: money.FromInt(10000)},
+		{ID: 4, Scope: TargetScopeSalesperson, UserID: intPtr(13), TargetAmount: money.FromInt(10000)},
+		{ID: 5, Scope: TargetScopeTeam, TeamID: intPtr(1), TargetAmount: money.FromInt(30000), Team: &SalesTeam{
+			ID: 1, Name: "Sul", Members: []SalesTeamMember{{UserID: 10}, {UserID: 11}},
+		}},
+		{ID: 6, Scope: TargetScopeProductLine, ProductLine: "Notebooks", TargetAmount: money.FromInt(20000)},
+	}
+	invoiced := SalesFigures{
+		BySalesperson: map[int]money.Decimal{10: money.FromInt(12000), 11: money.FromInt(6000), 12: money.FromInt(2000), 13: money.FromInt(2000)},
+		ByProductLine: map[string]money.Decimal{"notebooks": money.FromInt(4000)},
+	}
+	pipeline := SalesFigures{
+		BySalesperson: map[int]money.Decimal{11: money.FromInt(5000), 12: money.FromInt(9000), 13: money.FromInt(1000)},
+		ByProductLine: map[string]money.Decimal{"notebooks": money.MustParse("3000.555")},
+	}
+
+	dashboard := BuildAttainmentDashboard(period, targets, invoiced, pipeline, now)
+
+	assert.Equal(t, "2026-04-01", dashboard.From)
+	assert.Equal(t, "2026-04-30", dashboard.To)
+	assert.Equal(t, 50.0, dashboard.ElapsedPercent)
+	require.Len(t, dashboard.Targets, 6)
+
+	byID := make(map[int]TargetAttainment)
+	for _, entry := range dashboard.Targets {
+		byID[entry.TargetID] = entry
+	}
+
+	// Meta batida
+	assert.Equal(t, TargetStatusAchieved, byID[1].Status)
+	assert.Equal(t, 120.0, byID[1].AttainmentPercent)
+	assert.Equal(t, "0.00", byID[1].Remaining.String())
+	assert.Equal(t, 1, byID[1].Rank)
+	// No ritmo: 60% faturado com metade do mês
+	assert.Equal(t, TargetStatusOnTrack, byID[2].Status)
+	assert.Equal(t, 110.0, byID[2].ProjectedPercent)
+	assert.Equal(t, 2, byID[2].Rank)
+	// Abaixo do ritmo, mas o funil ponderado cobre o que falta
+	assert.Equal(t, TargetStatusAtRisk, byID[3].Status)
+	assert.Equal(t, "8000.00", byID[3].Remaining.String())
+	// Mesmo atingimento divide a posição
+	assert.Equal(t, TargetStatusBehind, byID[4].Status)
+	assert.Equal(t, 3, byID[3].Rank)
+	assert.Equal(t, 3, byID[4].Rank)
+
+	// A equipe soma o faturado e o funil dos membros
+	assert.Equal(t, "Sul", byID[5].Name)
+	assert.Equal(t, "18000.00", byID[5].Invoiced.String())
+	assert.Equal(t, "5000.00", byID[5].WeightedPipeline.String())
+	assert.Equal(t, 60.0, byID[5].AttainmentPercent)
+	assert.Equal(t, 1, byID[5].Rank)
+
+	// A linha de produto casa com o grupo do produto sem diferenciar maiúsculas
+	assert.Equal(t, "4000.00", byID[6].Invoiced.String())
+	assert.Equal(t, "3000.56", byID[6].WeightedPipeline.String())
+	assert.Equal(t, "7000.56", byID[6].Projected.String())
+	assert.Equal(t, TargetStatusBehind, byID[6].Status)
+
+	assert.Equal(t, TargetScopeSalesperson, dashboard.Targets[0].Scope)
+	assert.Equal(t, TargetScopeProductLine, dashboard.Targets[5].Scope)
+	assert.Equal(t, AttainmentSummary{Targets: 6, Achieved: 1, OnTrack: 2, AtRisk: 1, Behind: 2}, dashboard.Summary)
+}
@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/commissions/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StatementFilter define os filtros da listagem de extratos de comissão
+type StatementFilter struct {
+	Period string
+	UserID int
+}
+
+// CommissionRepository define as operações de metas, regras e extratos de comissão
+type CommissionRepository interface {
+	GetRules(ctx context.Context) ([]models.CommissionRule, error)
+	CreateRule(ctx context.Context, rule *models.CommissionRule) error
+	UpdateRule(ctx context.Context, id int, rule *models.CommissionRule) (*models.CommissionRule, error)
+	DeleteRule(ctx context.Context, id int) error
+	UpsertQuota(ctx context.Context, quota *models.SalesQuota) error
+	GetQuotas(ctx context.Context, period string) ([]models.SalesQuota, error)
+	CalculatePeriod(ctx context.Context, period string, start, end time.Time) (*models.CommissionPeriod, error)
+	GetPeriod(ctx context.Context, period string) (*models.CommissionPeriod, error)
+	ChangePeriodStatus(ctx context.Context, period, status, user string) (*models.CommissionPeriod, error)
+	GetStatements(ctx context.Context, filter StatementFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetStatementByID(ctx context.Context, id int) (*models.CommissionStatement, error)
+}
+
+type commissionRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCommissionRepository cria uma nova instância do repositório
+func NewCommissionRepository() (CommissionRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &commissionRepository{
+		db:     db,
+		logger: logger.WithModule("commission_repository"),
+	}, nil
+}
+
+// GetRules lista as regras de comissão
+func (r *commissionRepository) GetRules(ctx context.Context) ([]models.CommissionRule, error) {
+	var rules []models.CommissionRule
+	if err := db.Conn(ctx, r.db).Order("product_category ASC NULLS FIRST, min_attainment ASC").Find(&rules).Error; err != nil {
+		r.logger.Error("erro ao listar regras de comissão", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar regras de comissão")
+	}
+	return rules, nil
+}
+
+// CreateRule cria uma regra de comissão
+func (r *commissionRepository) CreateRule(ctx context.Context, rule *models.CommissionRule) error {
+	if err := db.Conn(ctx, r.db).Create(rule).Error; err != nil {
+		r.logger.Error("erro ao criar regra de comissão", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar regra de comissão")
+	}
+
+	r.logger.Info("regra de comissão criada", zap.Int("id", rule.ID), zap.String("name", rule.Name))
+	return nil
+}
+
+// UpdateRule atualiza uma regra de comissão
+func (r *commissionRepository) UpdateRule(ctx context.Context, id int, rule *models.CommissionRule) (*models.CommissionRule, error) {
+	var existing models.CommissionRule
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCommissionRuleNotFound
+		}
+		r.logger.Error("erro ao buscar regra de comissão", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar regra de comissão")
+	}
+
+	updates := map[string]interface{}{
+		"name":             rule.Name,
+		"product_category": rule.ProductCategory,
+		"min_attainment":   rule.MinAttainment,
+		"rate":             rule.Rate,
+		"active":           rule.Active,
+	}
+	if err := db.Conn(ctx, r.db).Model(&existing).Updates(updates).Error; err != nil {
+		r.logger.Error("erro ao atualizar regra de comissão", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar regra de comissão")
+	}
+
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar regra de comissão")
+	}
+	return &existing, nil
+}
+
+// DeleteRule remove uma regra de comissão; os extratos já calculados mantêm o percentual aplicado
+func (r *commissionRepository) DeleteRule(ctx context.Context, id int) error {
+	result := db.Conn(ctx, r.db).Delete(&models.CommissionRule{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover regra de comissão", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover regra de comissão")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrCommissionRuleNotFound
+	}
+	return nil
+}
+
+// UpsertQuota cria ou atualiza a meta do vendedor no período
+func (r *commissionRepository) UpsertQuota(ctx context.Context, quota *models.SalesQuota) error {
+	if err := db.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "period"}},
+		DoUpdates: clause.AssignmentColumns([]string{"target_amount", "updated_at"}),
+	}).Create(quota).Error; err != nil {
+		r.logger.Error("erro ao gravar meta de vendas", zap.Error(err), zap.Int("user_id", quota.UserID))
+		return errors.WrapError(err, "falha ao gravar meta de vendas")
+	}
+	return nil
+}
+
+// GetQuotas lista as metas de vendas, opcionalmente filtradas pelo período
+func (r *commissionRepository) GetQuotas(ctx context.Context, period string) ([]models.SalesQuota, error) {
+	query := db.Conn(ctx, r.db).Model(&models.SalesQuota{})
+	if period != "" {
+		query = query.Where("period = ?", period)
+	}
+
+	var quotas []models.SalesQuota
+	if err := query.Order("period DESC, user_id ASC").Find(&quotas).Error; err != nil {
+		r.logger.Error("erro ao listar metas de vendas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar metas de vendas")
+	}
+	return quotas, nil
+}
+
+// CalculatePeriod (re)calcula os extratos de comissão do período a partir das faturas emitidas no mês.
+// Só é permitido enquanto o período estiver aberto.
+func (r *commissionRepository) CalculatePeriod(ctx context.Context, period string, start, end time.Time) (*models.CommissionPeriod, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	// Garante o registro do período antes de bloqueá-lo
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.CommissionPeriod{Period: period, Status: models.PeriodStatusOpen}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar período de comissão", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao criar período de comissão")
+	}
+
+	var commissionPeriod models.CommissionPeriod
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("period = ?", period).
+		First(&commissionPeriod).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar período de comissão", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao buscar período de comissão")
+	}
+
+	if commissionPeriod.Status != models.PeriodStatusOpen {
+		tx.Rollback()
+		return nil, errors.ErrCommissionPeriodLocked
+	}
+
+	company, err := tenant.Condition(ctx, "i")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var lines []models.InvoicedLine
+	if err := tx.Raw(`
+		SELECT i.id AS invoice_id, ii.id AS invoice_item_id, i.salesperson_id,
+		       ii.product_id, COALESCE(p.product_category, '') AS product_category,
+		       ROUND(ii.quantity * ii.unit_price - ii.discount, 2) AS amount
+		FROM invoice_items ii
+		JOIN invoices i ON i.id = ii.invoice_id
+		LEFT JOIN products p ON p.id = ii.product_id
+		WHERE i.salesperson_id IS NOT NULL
+		  AND i.issue_date >= ? AND i.issue_date < ?
+		  AND i.status NOT IN ?
+		  AND `+company+`
+		ORDER BY i.id ASC, ii.id ASC`,
+		start, end, []string{sales.InvoiceStatusDraft, sales.InvoiceStatusCancelled}).
+		Scan(&lines).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar itens faturados do período", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao buscar itens faturados do período")
+	}
+
+	var quotaRows []models.SalesQuota
+	if err := tx.Where("period = ?", period).Find(&quotaRows).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar metas do período", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao buscar metas do período")
+	}
+	quotas := make(map[int]money.Decimal, len(quotaRows))
+	for _, quota := range quotaRows {
+		quotas[quota.UserID] = quota.TargetAmount
+	}
+
+	var rules []models.CommissionRule
+	if err := tx.Where("active = ?", true).Find(&rules).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar regras de comissão", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar regras de comissão")
+	}
+
+	// Recalcula do zero: os extratos anteriores do período aberto são substituídos
+	if err := tx.Where("period_id = ?", commissionPeriod.ID).Delete(&models.CommissionStatement{}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao remover extratos anteriores", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao remover extratos anteriores")
+	}
+
+	statements := models.BuildStatements(lines, quotas, rules)
+	for i := range statements {
+		statements[i].PeriodID = commissionPeriod.ID
+		if err := tx.Create(&statements[i]).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao criar extrato de comissão", zap.Error(err), zap.Int("user_id", statements[i].UserID))
+			return nil, errors.WrapError(err, "falha ao criar extrato de comissão")
+		}
+	}
+
+	now := time.Now()
+	if err := tx.Model(&commissionPeriod).Update("calculated_at", now).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao atualizar período de comissão", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao atualizar período de comissão")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	commissionPeriod.CalculatedAt = &now
+	commissionPeriod.Statements = statements
+
+	r.logger.Info("comissões do período calculadas",
+		zap.String("period", period),
+		zap.Int("statements", len(statements)),
+		zap.Int("lines", len(lines)))
+	return &commissionPeriod, nil
+}
+
+// GetPeriod retorna o período com os extratos dos vendedores (sem as linhas)
+func (r *commissionRepository) GetPeriod(ctx context.Context, period string) (*models.CommissionPeriod, error) {
+	var commissionPeriod models.CommissionPeriod
+	if err := db.Conn(ctx, r.db).Preload("Statements", func(db *gorm.DB) *gorm.DB {
+		return db.Order("user_id ASC")
+	}).Where("period = ?", period).First(&commissionPeriod).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCommissionPeriodNotFound
+		}
+		r.logger.Error("erro ao buscar período de comissão", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao buscar período de comissão")
+	}
+	return &commissionPeriod, nil
+}
+
+// ChangePeriodStatus aprova ou fecha o período de comissão, registrando o responsável
+func (r *commissionRepository) ChangePeriodStatus(ctx context.Context, period, status, user string) (*models.CommissionPeriod, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var commissionPeriod models.CommissionPeriod
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("period = ?", period).
+		First(&commissionPeriod).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCommissionPeriodNotFound
+		}
+		r.logger.Error("erro ao buscar período de comissão", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao buscar período de comissão")
+	}
+
+	if !models.CanTransition(commissionPeriod.Status, status) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidCommissionStatus
+	}
+	if commissionPeriod.CalculatedAt == nil {
+		tx.Rollback()
+		return nil, errors.ErrCommissionPeriodNotCalculated
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case models.PeriodStatusApproved:
+		updates["approved_by"] = user
+		updates["approved_at"] = now
+	case models.PeriodStatusClosed:
+		updates["closed_by"] = user
+		updates["closed_at"] = now
+	}
+
+	if err := tx.Model(&commissionPeriod).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao atualizar status do período de comissão", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao atualizar status do período de comissão")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("status do período de comissão alterado",
+		zap.String("period", period), zap.String("status", status), zap.String("user", user))
+	return r.GetPeriod(ctx, period)
+}
+
+// GetStatements lista os extratos de comissão filtrados por período e vendedor
+func (r *commissionRepository) GetStatements(ctx context.Context, filter StatementFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := db.Conn(ctx, r.db).Model(&models.CommissionStatement{}).
+		Joins("JOIN commission_periods ON commission_periods.id = commission_statements.period_id")
+	if filter.Period != "" {
+		query = query.Where("commission_periods.period = ?", filter.Period)
+	}
+	if filter.UserID > 0 {
+		query = query.Where("commission_statements.user_id = ?", filter.UserID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar extratos de comissão", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar extratos de comissão")
+	}
+
+	var statements []models.CommissionStatement
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Preload("Period").
+		Order("commission_periods.period DESC, commission_statements.user_id ASC").
+		Offset(offset).Limit(params.PageSize).
+		Find(&statements).Error; err != nil {
+		r.logger.Error("erro ao listar extratos de comissão", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar extratos de comissão")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, statements), nil
+}
+
+// GetStatementByID retorna o extrato com as linhas de comissão por item faturado
+func (r *commissionRepository) GetStatementByID(ctx context.Context, id int) (*models.CommissionStatement, error) {
+	var statement models.CommissionStatement
+	if err := db.Conn(ctx, r.db).Preload("Period").Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("invoice_id ASC, invoice_item_id ASC")
+	}).First(&statement, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCommissionStatementNotFound
+		}
+		r.logger.Error("erro ao buscar extrato de comissão", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar extrato de comissão")
+	}
+	return &statement, nil
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/commissions/models"
+	crm "ERP-ONSMART/backend/internal/modules/crm/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AttainmentData reúne as metas do período com os membros das equipes, a receita faturada e o
+// funil ponderado do período
+type AttainmentData struct {
+	Targets  []models.SalesTarget
+	Invoiced models.SalesFigures
+	Pipeline models.SalesFigures
+}
+
+// SalesTargetRepository define as operações das equipes e das metas de vendas
+type SalesTargetRepository interface {
+	ListTeams(ctx context.Context) ([]models.SalesTeam, error)
+	GetTeam(ctx context.Context, id int) (*models.SalesTeam, error)
+	CreateTeam(ctx context.Context, team *models.SalesTeam, memberIDs []int) (*models.SalesTeam, error)
+	UpdateTeam(ctx context.Context, id int, team *models.SalesTeam, memberIDs []int) (*models.SalesTeam, error)
+	DeleteTeam(ctx context.Context, id int) error
+	ListTargets(ctx context.Context, filter models.SalesTargetFilter) ([]models.SalesTarget, error)
+	SetTarget(ctx context.Context, target *models.SalesTarget) (*models.SalesTarget, error)
+	DeleteTarget(ctx context.Context, id int) error
+	GetAttainmentData(ctx context.Context, period models.TargetPeriod, scope string) (*AttainmentData, error)
+}
+
+type salesTargetRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewSalesTargetRepository cria uma nova instância do repositório
+func NewSalesTargetRepository() (SalesTargetRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &salesTargetRepository{
+		db:     gormDB,
+		logger: logger.WithModule("sales_target_repository"),
+	}, nil
+}
+
+// ListTeams lista as equipes de vendas com os membros
+func (r *salesTargetRepository) ListTeams(ctx context.Context) ([]models.SalesTeam, error) {
+	var teams []models.SalesTeam
+	if err := db.Conn(ctx, r.db).Preload("Members", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("user_id ASC")
+	}).Order("name ASC, id ASC").Find(&teams).Error; err != nil {
+		r.logger.Error("erro ao listar equipes de vendas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar equipes de vendas")
+	}
+	return teams, nil
+}
+
+// GetTeam retorna a equipe com os membros
+func (r *salesTargetRepository) GetTeam(ctx context.Context, id int) (*models.SalesTeam, error) {
+	return r.findTeam(db.Conn(ctx, r.db), id)
+}
+
+// CreateTeam cria a equipe com os vendedores informados
+func (r *salesTargetRepository) CreateTeam(ctx context.Context, team *models.SalesTeam, memberIDs []int) (*models.SalesTeam, error) {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(team).Error; err != nil {
+			return errors.WrapError(err, "falha ao criar equipe de vendas")
+		}
+		return replaceMembers(tx, team.ID, memberIDs)
+	})
+	if err != nil {
+		r.logger.Error("erro ao criar equipe de vendas", zap.Error(err), zap.String("name", team.Name))
+		return nil, err
+	}
+
+	r.logger.Info("equipe de vendas criada", zap.Int("id", team.ID), zap.Int("members", len(memberIDs)))
+	return r.GetTeam(ctx, team.ID)
+}
+
+// UpdateTeam atualiza a equipe e substitui os membros
+func (r *salesTargetRepository) UpdateTeam(ctx context.Context, id int, team *models.SalesTeam, memberIDs []int) (*models.SalesTeam, error) {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		existing, err := r.findTeam(tx, id)
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"name":      team.Name,
+			"leader_id": team.LeaderID,
+			"active":    team.Active,
+		}
+		if err := tx.Model(existing).Updates(updates).Error; err != nil {
+			return errors.WrapError(err, "falha ao atualizar equipe de vendas")
+		}
+		return replaceMembers(tx, id, memberIDs)
+	})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			r.logger.Error("erro ao atualizar equipe de vendas", zap.Error(err), zap.Int("id", id))
+		}
+		return nil, err
+	}
+	return r.GetTeam(ctx, id)
+}
+
+// DeleteTeam remove a equipe; as metas da equipe são removidas junto
+func (r *salesTargetRepository) DeleteTeam(ctx context.Context, id int) error {
+	result := db.Conn(ctx, r.db).Delete(&models.SalesTeam{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover equipe de vendas", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover equipe de vendas")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrSalesTeamNotFound
+	}
+	return nil
+}
+
+// ListTargets lista as metas de vendas por período e escopo
+func (r *salesTargetRepository) ListTargets(ctx context.Context, filter models.SalesTargetFilter) ([]models.SalesTarget, error) {
+	query := db.Conn(ctx, r.db).Model(&models.SalesTarget{}).Preload("Team")
+	if filter.Period != "" {
+		query = query.Where("period = ?", filter.Period)
+	}
+	if filter.Scope != "" {
+		query = query.Where("scope = ?", filter.Scope)
+	}
+
+	var targets []models.SalesTarget
+	if err := query.Order("period DESC, scope ASC, user_id ASC, team_id ASC, product_line ASC").Find(&targets).Error; err != nil {
+		r.logger.Error("erro ao listar metas de vendas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar metas de vendas")
+	}
+	return targets, nil
+}
+
+// SetTarget cria a meta ou atualiza o valor da meta já definida para o escopo no período
+func (r *salesTargetRepository) SetTarget(ctx context.Context, target *models.SalesTarget) (*models.SalesTarget, error) {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if target.TeamID != nil {
+			if _, err := r.findTeam(tx, *target.TeamID); err != nil {
+				return err
+			}
+		}
+
+		query := tx.Where("scope = ? AND period = ? AND product_line = ?", target.Scope, target.Period, target.ProductLine)
+		if target.UserID != nil {
+			query = query.Where("user_id = ?", *target.UserID)
+		} else {
+			query = query.Where("user_id IS NULL")
+		}
+		if target.TeamID != nil {
+			query = query.Where("team_id = ?", *target.TeamID)
+		} else {
+			query = query.Where("team_id IS NULL")
+		}
+
+		var existing models.SalesTarget
+		err := query.First(&existing).Error
+		switch {
+		case err == nil:
+			target.ID = existing.ID
+			return tx.Model(&existing).Updates(map[string]interface{}{
+				"target_amount": target.TargetAmount,
+				"updated_by":    target.UpdatedBy,
+			}).Error
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(target).Error
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			r.logger.Error("erro ao gravar meta de vendas", zap.Error(err), zap.String("scope", target.Scope), zap.String("period", target.Period))
+			return nil, errors.WrapError(err, "falha ao gravar meta de vendas")
+		}
+		return nil, err
+	}
+
+	var saved models.SalesTarget
+	if err := db.Conn(ctx, r.db).Preload("Team").First(&saved, target.ID).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar meta de vendas")
+	}
+	return &saved, nil
+}
+
+// DeleteTarget remove a meta de vendas
+func (r *salesTargetRepository) DeleteTarget(ctx context.Context, id int) error {
+	result := db.Conn(ctx, r.db).Delete(&models.SalesTarget{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover meta de vendas", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover meta de vendas")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrSalesTargetNotFound
+	}
+	return nil
+}
+
+// figureRow é um valor agrupado por vendedor ou por grupo de produto
+type figureRow struct {
+	UserID       int
+	ProductGroup string
+	Amount       money.Decimal
+}
+
+// GetAttainmentData carrega as metas do período e, das faturas emitidas no período (exceto
+// rascunhos e canceladas) e das oportunidades abertas com fechamento previsto no período, os
+// valores por vendedor e por linha de produto. O faturado segue a base das comissões (quantidade
+// x preço - desconto); o funil pondera o valor pela probabilidade da oportunidade e, por linha,
+// os itens previstos.
+func (r *salesTargetRepository) GetAttainmentData(ctx context.Context, period models.TargetPeriod, scope string) (*AttainmentData, error) {
+	conn := db.Conn(ctx, r.db)
+	data := &AttainmentData{}
+
+	query := conn.Preload("Team.Members").Where("period = ?", period.Period)
+	if scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	if err := query.Order("id ASC").Find(&data.Targets).Error; err != nil {
+		r.logger.Error("erro ao buscar metas do período", zap.Error(err), zap.String("period", period.Period))
+		return nil, errors.WrapError(err, "falha ao buscar metas do período")
+	}
+
+	excluded := []string{sales.InvoiceStatusDraft, sales.InvoiceStatusCancelled}
+	invoiced := func() *gorm.DB {
+		return conn.Model(&sales.Invoice{}).
+			Joins("JOIN invoice_items ii ON ii.invoice_id = invoices.id").
+			Where("invoices.issue_date >= ? AND invoices.issue_date < ?", period.Start, period.End).
+			Where("invoices.status NOT IN ?", excluded)
+	}
+	closedStages := []string{crm.StageWon, crm.StageLost}
+	pipeline := func() *gorm.DB {
+		return conn.Model(&crm.Opportunity{}).
+			Where("crm_opportunities.stage NOT IN ?", closedStages).
+			Where("crm_opportunities.expected_close_date >= ? AND crm_opportunities.expected_close_date < ?", period.Start, period.End)
+	}
+
+	var invoicedByUser, invoicedByLine, pipelineByUser, pipelineByLine []figureRow
+	queries := []struct {
+		what string
+		run  *gorm.DB
+		rows *[]figureRow
+	}{
+		{"faturamento por vendedor", invoiced().
+			Select("invoices.salesperson_id AS user_id, SUM(ROUND(ii.quantity * ii.unit_price - ii.discount, 2)) AS amount").
+			Where("invoices.salesperson_id IS NOT NULL").
+			Group("invoices.salesperson_id"), &invoicedByUser},
+		{"faturamento por linha de produto", invoiced().
+			Select("p.product_group, SUM(ROUND(ii.quantity * ii.unit_price - ii.discount, 2)) AS amount").
+			Joins("JOIN products p ON p.id = ii.product_id").
+			Group("p.product_group"), &invoicedByLine},
+		{"funil por vendedor", pipeline().
+			Select("crm_opportunities.owner_id AS user_id, SUM(crm_opportunities.amount * crm_opportunities.probability / 100) AS amount").
+			Where("crm_opportunities.owner_id IS NOT NULL").
+			Group("crm_opportunities.owner_id"), &pipelineByUser},
+		{"funil por linha de produto", pipeline().
+			Select("p.product_group, SUM((oi.quantity * oi.unit_price - oi.discount) * crm_opportunities.probability / 100) AS amount").
+			Joins("JOIN crm_opportunity_items oi ON oi.opportunity_id = crm_opportunities.id").
+			Joins("JOIN products p ON p.id = oi.product_id").
+			Group("p.product_group"), &pipelineByLine},
+	}
+	for _, q := range queries {
+		if err := q.run.Scan(q.rows).Error; err != nil {
+			r.logger.Error("erro ao apurar metas do período", zap.Error(err), zap.String("query", q.what))
+			return nil, errors.WrapError(err, "falha ao apurar "+q.what)
+		}
+	}
+
+	data.Invoiced = toFigures(invoicedByUser, invoicedByLine)
+	data.Pipeline = toFigures(pipelineByUser, pipelineByLine)
+	return data, nil
+}
+
+// toFigures indexa os valores por vendedor e por linha de produto normalizada
+func toFigures(byUser, byLine []figureRow) models.SalesFigures {
+	figures := models.SalesFigures{
+		BySalesperson: make(map[int]money.Decimal, len(byUser)),
+		ByProductLine: make(map[string]money.Decimal, len(byLine)),
+	}
+	for _, row := range byUser {
+		figures.BySalesperson[row.UserID] = figures.BySalesperson[row.UserID].Add(row.Amount)
+	}
+	for _, row := range byLine {
+		if key := models.ProductLineKey(row.ProductGroup); key != "" {
+			figures.ByProductLine[key] = figures.ByProductLine[key].Add(row.Amount)
+		}
+	}
+	return figures
+}
+
+// findTeam busca a equipe com os membros
+func (r *salesTargetRepository) findTeam(conn *gorm.DB, id int) (*models.SalesTeam, error) {
+	var team models.SalesTeam
+	if err := conn.Preload("Members", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("user_id ASC")
+	}).First(&team, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrSalesTeamNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar equipe de vendas")
+	}
+	return &team, nil
+}
+
+// replaceMembers substitui os vendedores da equipe
+func replaceMembers(tx *gorm.DB, teamID int, memberIDs []int) error {
+	if err := tx.Where("team_id = ?", teamID).Delete(&models.SalesTeamMember{}).Error; err != nil {
+		return errors.WrapError(err, "falha ao remover membros da equipe")
+	}
+	if len(memberIDs) == 0 {
+		return nil
+	}
+	members := make([]models.SalesTeamMember, 0, len(memberIDs))
+	for _, userID := range memberIDs {
+		members = append(members, models.SalesTeamMember{TeamID: teamID, UserID: userID})
+	}
+	if err := tx.Create(&members).Error; err != nil {
+		return errors.WrapError(err, "falha ao gravar membros da equipe")
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/commissions/models"
+	"ERP-ONSMART/backend/internal/modules/commissions/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"strings"
+)
+
+// RuleInput reúne os dados de criação e atualização de uma regra de comissão
+type RuleInput struct {
+	Name            string  `json:"name" binding:"required"`
+	ProductCategory string  `json:"product_category"`
+	MinAttainment   float64 `json:"min_attainment" binding:"gte=0"`
+	Rate            float64 `json:"rate" binding:"gte=0,lte=100"`
+	Active          *bool   `json:"active"`
+}
+
+// toRule converte a entrada na regra; sem categoria, a regra vale para todos os produtos
+func (in RuleInput) toRule() *models.CommissionRule {
+	rule := &models.CommissionRule{
+		Name:          strings.TrimSpace(in.Name),
+		MinAttainment: in.MinAttainment,
+		Rate:          in.Rate,
+		Active:        in.Active == nil || *in.Active,
+	}
+	if category := strings.TrimSpace(in.ProductCategory); category != "" {
+		rule.ProductCategory = &category
+	}
+	return rule
+}
+
+// GetRules lista as regras de comissão
+func GetRules(ctx context.Context) ([]models.CommissionRule, error) {
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRules(ctx)
+}
+
+// CreateRule cria uma regra de comissão
+func CreateRule(ctx context.Context, input RuleInput) (*models.CommissionRule, error) {
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	rule := input.toRule()
+	if err := repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule atualiza uma regra de comissão
+func UpdateRule(ctx context.Context, id int, input RuleInput) (*models.CommissionRule, error) {
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.UpdateRule(ctx, id, input.toRule())
+}
+
+// DeleteRule remove uma regra de comissão
+func DeleteRule(ctx context.Context, id int) error {
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteRule(ctx, id)
+}
+
+// SetQuota define a meta de vendas do vendedor no período
+func SetQuota(ctx context.Context, quota *models.SalesQuota) error {
+	if _, _, err := models.ParsePeriod(quota.Period); err != nil {
+		return err
+	}
+
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return err
+	}
+	return repo.UpsertQuota(ctx, quota)
+}
+
+// GetQuotas lista as metas de vendas, opcionalmente filtradas pelo período
+func GetQuotas(ctx context.Context, period string) ([]models.SalesQuota, error) {
+	if period != "" {
+		if _, _, err := models.ParsePeriod(period); err != nil {
+			return nil, err
+		}
+	}
+
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetQuotas(ctx, period)
+}
+
+// CalculatePeriod calcula os extratos de comissão do mês a partir das faturas emitidas
+func CalculatePeriod(ctx context.Context, period string) (*models.CommissionPeriod, error) {
+	start, end, err := models.ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CalculatePeriod(ctx, period, start, end)
+}
+
+// GetPeriod retorna o período de comissão com os extratos dos vendedores
+func GetPeriod(ctx context.Context, period string) (*models.CommissionPeriod, error) {
+	if _, _, err := models.ParsePeriod(period); err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetPeriod(ctx, period)
+}
+
+// ApprovePeriod aprova as comissões calculadas do período
+func ApprovePeriod(ctx context.Context, period, user string) (*models.CommissionPeriod, error) {
+	return changePeriodStatus(ctx, period, models.PeriodStatusApproved, user)
+}
+
+// ClosePeriod fecha o período aprovado, impedindo novos cálculos
+func ClosePeriod(ctx context.Context, period, user string) (*models.CommissionPeriod, error) {
+	return changePeriodStatus(ctx, period, models.PeriodStatusClosed, user)
+}
+
+// GetStatements lista os extratos de comissão por período e vendedor
+func GetStatements(ctx context.Context, period string, userID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	if period != "" {
+		if _, _, err := models.ParsePeriod(period); err != nil {
+			return nil, err
+		}
+	}
+
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetStatements(ctx, repository.StatementFilter{Period: period, UserID: userID}, params)
+}
+
+// GetStatement retorna o extrato com as linhas de comissão
+func GetStatement(ctx context.Context, id int) (*models.CommissionStatement, error) {
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetStatementByID(ctx, id)
+}
+
+func changePeriodStatus(ctx context.Context, period, status, user string) (*models.CommissionPeriod, error) {
+	if _, _, err := models.ParsePeriod(period); err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewCommissionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ChangePeriodStatus(ctx, period, status, user)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/commissions/models"
+	"ERP-ONSMART/backend/internal/modules/commissions/repository"
+	"context"
+)
+
+// ListTeams lista as equipes de vendas com os membros
+func ListTeams(ctx context.Context) ([]models.SalesTeam, error) {
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListTeams(ctx)
+}
+
+// GetTeam retorna a equipe de vendas com os membros
+func GetTeam(ctx context.Context, id int) (*models.SalesTeam, error) {
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTeam(ctx, id)
+}
+
+// CreateTeam cria a equipe de vendas com os vendedores informados
+func CreateTeam(ctx context.Context, input models.SalesTeamInput) (*models.SalesTeam, error) {
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return nil, err
+	}
+	team, members := input.ToTeam()
+	return repo.CreateTeam(ctx, team, members)
+}
+
+// UpdateTeam atualiza a equipe de vendas e substitui os membros
+func UpdateTeam(ctx context.Context, id int, input models.SalesTeamInput) (*models.SalesTeam, error) {
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return nil, err
+	}
+	team, members := input.ToTeam()
+	return repo.UpdateTeam(ctx, id, team, members)
+}
+
+// DeleteTeam remove a equipe de vendas e as metas dela
+func DeleteTeam(ctx context.Context, id int) error {
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteTeam(ctx, id)
+}
+
+// ListTargets lista as metas de vendas, opcionalmente por período (AAAA-MM ou AAAA-Qn) e escopo
+func ListTargets(ctx context.Context, period, scope string) ([]models.SalesTarget, error) {
+	filter, err := targetFilter(period, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListTargets(ctx, filter)
+}
+
+// SetTarget define a meta do vendedor, da equipe ou da linha de produto no período
+func SetTarget(ctx context.Context, input models.SalesTargetInput, user string) (*models.SalesTarget, error) {
+	target, err := input.ToTarget()
+	if err != nil {
+		return nil, err
+	}
+	target.UpdatedBy = user
+
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.SetTarget(ctx, target)
+}
+
+// DeleteTarget remove a meta de vendas
+func DeleteTarget(ctx context.Context, id int) error {
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteTarget(ctx, id)
+}
+
+// GetAttainment monta o painel de atingimento das metas do período, com o faturado, o funil
+// ponderado do CRM e a classificação por escopo
+func GetAttainment(ctx context.Context, period, scope string) (*models.AttainmentDashboard, error) {
+	if period == "" {
+		period = localtime.Now(ctx).Format(models.PeriodLayout)
+	}
+	filter, err := targetFilter(period, scope)
+	if err != nil {
+		return nil, err
+	}
+	targetPeriod, _ := models.ParseTargetPeriod(filter.Period)
+
+	repo, err := repository.NewSalesTargetRepository()
+	if err != nil {
+		return nil, err
+	}
+	data, err := repo.GetAttainmentData(ctx, targetPeriod, filter.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := models.BuildAttainmentDashboard(targetPeriod, data.Targets, data.Invoiced, data.Pipeline, localtime.Now(ctx))
+	return &dashboard, nil
+}
+
+// targetFilter valida o período e o escopo dos filtros das metas
+func targetFilter(period, scope string) (models.SalesTargetFilter, error) {
+	var filter models.SalesTargetFilter
+	if period != "" {
+		parsed, err := models.ParseTargetPeriod(period)
+		if err != nil {
+			return filter, err
+		}
+		filter.Period = parsed.Period
+	}
+	switch scope {
+	case "", models.TargetScopeSalesperson, models.TargetScopeTeam, models.TargetScopeProductLine:
+		filter.Scope = scope
+	default:
+		return filter, errors.InvalidParam("scope deve ser salesperson, team ou product_line")
+	}
+	return filter, nil
+}
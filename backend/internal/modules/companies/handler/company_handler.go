@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/companies/models"
+	"ERP-ONSMART/backend/internal/modules/companies/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as empresas atendidas pela instalação
+// @Security BearerAuth
+func ListCompaniesHandler(c *gin.Context) {
+	companies, err := service.ListCompanies()
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar empresas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"companies": companies})
+}
+
+// Retorna uma empresa
+// @Security BearerAuth
+func GetCompanyHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	company, err := service.GetCompany(id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar empresa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"company": company})
+}
+
+// Cadastra uma empresa
+// @Security BearerAuth
+func CreateCompanyHandler(c *gin.Context) {
+	var input models.Company
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	company, err := service.CreateCompany(input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar empresa")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"company": company})
+}
+
+// Atualiza os dados cadastrais e a situação (active) da empresa
+// @Security BearerAuth
+func UpdateCompanyHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.Company
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	company, err := service.UpdateCompany(id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar empresa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"company": company})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Company é uma empresa (entidade legal) atendida pela instalação; os dados de negócio
+// pertencem a uma empresa pela coluna company_id
+type Company struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" binding:"required,max=100"`
+	LegalName string    `json:"legal_name" binding:"max=200"`
+	Document  *string   `json:"document" binding:"omitempty,max=20"` // CNPJ
+	Active    bool      `json:"active" gorm:"default:true"`
+	Timezone  string    `json:"timezone" gorm:"default:America/Sao_Paulo" binding:"max=64"` // nome IANA
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Company) TableName() string {
+	return "companies"
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	stderrors "errors"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/companies/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CompanyRepository define as operações do cadastro de empresas
+type CompanyRepository interface {
+	List() ([]models.Company, error)
+	GetByID(id int) (*models.Company, error)
+	Create(company *models.Company) error
+	Update(company *models.Company) error
+}
+
+type companyRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCompanyRepository cria uma nova instância do repositório
+func NewCompanyRepository() (CompanyRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &companyRepository{
+		db:     db,
+		logger: logger.WithModule("company_repository"),
+	}, nil
+}
+
+// List retorna todas as empresas, ordenadas pelo nome
+func (r *companyRepository) List() ([]models.Company, error) {
+	var companies []models.Company
+	if err := r.db.Order("name ASC").Find(&companies).Error; err != nil {
+		r.logger.Error("erro ao listar empresas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar empresas")
+	}
+	return companies, nil
+}
+
+// GetByID busca uma empresa pelo ID
+func (r *companyRepository) GetByID(id int) (*models.Company, error) {
+	var company models.Company
+	if err := r.db.First(&company, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrCompanyNotFound
+		}
+		r.logger.Error("erro ao buscar empresa", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar empresa")
+	}
+	return &company, nil
+}
+
+// Create grava uma nova empresa
+func (r *companyRepository) Create(company *models.Company) error {
+	if err := r.db.Create(company).Error; err != nil {
+		r.logger.Error("erro ao criar empresa", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar empresa")
+	}
+	return nil
+}
+
+// Update grava os dados cadastrais e a situação da empresa; o fuso vazio mantém o atual
+func (r *companyRepository) Update(company *models.Company) error {
+	fields := map[string]interface{}{
+		"name":       company.Name,
+		"legal_name": company.LegalName,
+		"document":   company.Document,
+		"active":     company.Active,
+	}
+	if company.Timezone != "" {
+		fields["timezone"] = company.Timezone
+	}
+	result := r.db.Model(&models.Company{}).Where("id = ?", company.ID).Updates(fields)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar empresa", zap.Error(result.Error), zap.Int("id", company.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar empresa")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrCompanyNotFound
+	}
+	return nil
+}
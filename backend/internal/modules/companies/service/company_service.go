@@ -0,0 +1,72 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/companies/models"
+	"ERP-ONSMART/backend/internal/modules/companies/repository"
+)
+
+// ListCompanies lista as empresas cadastradas
+func ListCompanies() ([]models.Company, error) {
+	repo, err := repository.NewCompanyRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.List()
+}
+
+// GetCompany busca uma empresa pelo ID
+func GetCompany(id int) (*models.Company, error) {
+	repo, err := repository.NewCompanyRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(id)
+}
+
+// CreateCompany cadastra uma nova empresa, ativa por padrão e, sem fuso informado, no fuso
+// padrão (DEFAULT_TIMEZONE)
+func CreateCompany(company models.Company) (*models.Company, error) {
+	if err := validateTimezone(company.Timezone); err != nil {
+		return nil, err
+	}
+	repo, err := repository.NewCompanyRepository()
+	if err != nil {
+		return nil, err
+	}
+	company.ID = 0
+	company.Active = true
+	if company.Timezone == "" {
+		company.Timezone = localtime.DefaultLocation().String()
+	}
+	if err := repo.Create(&company); err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+// UpdateCompany atualiza os dados, a situação e o fuso da empresa
+func UpdateCompany(id int, company models.Company) (*models.Company, error) {
+	if err := validateTimezone(company.Timezone); err != nil {
+		return nil, err
+	}
+	repo, err := repository.NewCompanyRepository()
+	if err != nil {
+		return nil, err
+	}
+	company.ID = id
+	if err := repo.Update(&company); err != nil {
+		return nil, err
+	}
+	localtime.Forget(id)
+	return repo.GetByID(id)
+}
+
+// validateTimezone recusa nomes de fuso desconhecidos; vazio usa o padrão
+func validateTimezone(name string) error {
+	if _, err := localtime.Load(name); err != nil {
+		return errors.ErrInvalidTimezone
+	}
+	return nil
+}
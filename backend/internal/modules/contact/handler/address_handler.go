@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os endereços do contato (cobrança, entrega e unidades), os padrões primeiro
+// @Security BearerAuth
+func ListAddressesHandler(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	addresses, err := service.ListAddresses(c.Request.Context(), contactID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar endereços")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"addresses": addresses})
+}
+
+// Cadastra um endereço no contato: tipo billing, shipping ou site, CEP com 8 dígitos e, para o
+// roteiro das entregas, latitude e longitude. O primeiro endereço de cada tipo vira o padrão.
+// @Security BearerAuth
+func CreateAddressHandler(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	address, err := service.CreateAddress(c.Request.Context(), contactID, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cadastrar endereço")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"address": address})
+}
+
+// Altera um endereço do contato. Os documentos já emitidos mantêm o endereço da emissão.
+// @Security BearerAuth
+func UpdateAddressHandler(c *gin.Context) {
+	contactID, addressID, ok := addressParams(c)
+	if !ok {
+		return
+	}
+
+	var input models.AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	address, err := service.UpdateAddress(c.Request.Context(), contactID, addressID, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar endereço")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"address": address})
+}
+
+// Remove um endereço do contato; sendo o padrão, o mais antigo do mesmo tipo assume
+// @Security BearerAuth
+func DeleteAddressHandler(c *gin.Context) {
+	contactID, addressID, ok := addressParams(c)
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteAddress(c.Request.Context(), contactID, addressID); err != nil {
+		c.Error(err).SetMeta("erro ao remover endereço")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Endereço removido com sucesso"})
+}
+
+func addressParams(c *gin.Context) (int, int, bool) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, 0, false
+	}
+	addressID, err := strconv.Atoi(c.Param("address_id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, 0, false
+	}
+	return contactID, addressID, true
+}
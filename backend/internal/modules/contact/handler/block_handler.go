@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bloqueia o contato (fraud, litigation, sanctions ou other) a partir de effective_date (padrão:
+// hoje); cotações, pedidos, entregas e faturas do contato passam a ser recusados
+// @Security BearerAuth
+func BlockContactHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.BlockInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	event, err := service.BlockContact(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao bloquear contato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"event": event})
+}
+
+// Remove o bloqueio do contato; a ação fica registrada na trilha com o usuário
+// @Security BearerAuth
+func UnblockContactHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.UnblockInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	event, err := service.UnblockContact(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao desbloquear contato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"event": event})
+}
+
+// Lista a trilha de bloqueios e desbloqueios do contato
+// @Security BearerAuth
+func ListContactBlockEventsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	events, err := service.ListBlockEvents(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar bloqueios do contato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
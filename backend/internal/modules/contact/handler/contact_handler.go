@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/contact/service"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	customFieldsService "ERP-ONSMART/backend/internal/modules/customfields/service"
 	"net/http"
 	"strconv"
 
@@ -12,19 +15,19 @@ import (
 // Cria um novo contato
 func CreateContactHandler(c *gin.Context) {
 	var contact models.Contact
-	if err := c.ShouldBindJSON(&contact); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "dados inválidos",
-			"details": err.Error(),
-		})
+	var err error
+	if err = c.ShouldBindJSON(&contact); err != nil {
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 
-	if err := service.CreateContact(contact); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "erro ao criar contato",
-			"details": err.Error(),
-		})
+	if contact.CustomFields, err = customFieldsService.ValidateValues(c.Request.Context(), customfields.EntityContact, contact.CustomFields); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := service.CreateContact(c.Request.Context(), contact); err != nil {
+		c.Error(err).SetMeta("erro ao criar contato")
 		return
 	}
 
@@ -32,13 +35,17 @@ func CreateContactHandler(c *gin.Context) {
 }
 
 // Lista todos os contatos
+// @Param cf.<chave> query string false "filtra pelo valor de um campo personalizado, ex.: cf.segmento=varejo"
 func ListContactsHandler(c *gin.Context) {
-	contacts, err := service.ListContacts()
+	filters, err := customFieldsService.ParseFilters(c.Request.Context(), customfields.EntityContact, c.Request.URL.Query())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	contacts, err := service.SearchContacts(c.Request.Context(), filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "erro ao listar contatos",
-			"details": err.Error(),
-		})
+		c.Error(err).SetMeta("erro ao listar contatos")
 		return
 	}
 
@@ -49,16 +56,13 @@ func ListContactsHandler(c *gin.Context) {
 func GetContactByIDHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
-	contact, err := service.GetContact(id)
+	contact, err := service.GetContact(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "erro ao buscar contato",
-			"details": err.Error(),
-		})
+		c.Error(err).SetMeta("erro ao buscar contato")
 		return
 	}
 
@@ -69,43 +73,41 @@ func GetContactByIDHandler(c *gin.Context) {
 func DeleteContactHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
-	if err := service.RemoveContact(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "erro ao deletar contato",
-			"details": err.Error(),
-		})
+	if err := service.RemoveContact(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao deletar contato")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Contato deletado com sucesso"})
+	c.JSON(http.StatusOK, gin.H{"message": "Contato movido para a lixeira"})
 }
 
 // Atualiza um contato pelo ID
 func UpdateContactHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
 	var contact models.Contact
 	if err := c.ShouldBindJSON(&contact); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "dados inválidos",
-			"details": err.Error(),
-		})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 
-	if err := service.UpdateContact(id, contact); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "erro ao atualizar contato",
-			"details": err.Error(),
-		})
+	if contact.CustomFields != nil {
+		if contact.CustomFields, err = customFieldsService.ValidateValues(c.Request.Context(), customfields.EntityContact, contact.CustomFields); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	if err := service.UpdateContact(c.Request.Context(), id, contact); err != nil {
+		c.Error(err).SetMeta("erro ao atualizar contato")
 		return
 	}
 
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
 	"encoding/json"
 	"net/http"
@@ -28,6 +29,8 @@ func TestMain(m *testing.M) {
 func TestCreateContactHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.POST("/contacts", CreateContactHandler)
 
 	body := []byte(`{
@@ -38,6 +41,7 @@ func TestCreateContactHandler(t *testing.T) {
 	}`)
 
 	req, _ := http.NewRequest("POST", "/contacts", bytes.NewBuffer(body))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 
@@ -50,9 +54,12 @@ func TestCreateContactHandler(t *testing.T) {
 func TestListContactsHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.GET("/contacts", ListContactsHandler)
 
 	req, _ := http.NewRequest("GET", "/contacts", nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 
 	router.ServeHTTP(resp, req)
@@ -64,6 +71,8 @@ func TestListContactsHandler(t *testing.T) {
 func TestUpdateContactHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.POST("/contacts", CreateContactHandler)
 	router.PUT("/contacts/:id", UpdateContactHandler)
 	router.GET("/contacts", ListContactsHandler)
@@ -76,6 +85,7 @@ func TestUpdateContactHandler(t *testing.T) {
 		"type": "cliente"
 	}`)
 	req, _ := http.NewRequest("POST", "/contacts", bytes.NewBuffer(body))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
@@ -86,6 +96,7 @@ func TestUpdateContactHandler(t *testing.T) {
 	// Busca ID
 	respList := httptest.NewRecorder()
 	reqList, _ := http.NewRequest("GET", "/contacts", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	router.ServeHTTP(respList, reqList)
 
 	var result struct {
@@ -107,6 +118,7 @@ func TestUpdateContactHandler(t *testing.T) {
 		"type": "fornecedor"
 	}`)
 	reqUpdate, _ := http.NewRequest("PUT", "/contacts/"+strconv.Itoa(id), bytes.NewBuffer(updateBody))
+	reqUpdate.Header.Set(middleware.CompanyHeader, "1")
 	reqUpdate.Header.Set("Content-Type", "application/json")
 	respUpdate := httptest.NewRecorder()
 	router.ServeHTTP(respUpdate, reqUpdate)
@@ -119,11 +131,14 @@ func TestUpdateContactHandler(t *testing.T) {
 func TestDeleteContactHandler_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.DELETE("/contacts/:id", DeleteContactHandler)
 
 	// Tenta deletar um contato com ID inexistente
 	invalidID := 999999
 	req, _ := http.NewRequest("DELETE", "/contacts/"+strconv.Itoa(invalidID), nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
@@ -134,14 +149,16 @@ func TestDeleteContactHandler_NotFound(t *testing.T) {
 
 	// Verifica se a mensagem de erro retornada corresponde à esperada
 	var responseBody struct {
-		Error string `json:"error"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
 	}
 	if err := json.Unmarshal(resp.Body.Bytes(), &responseBody); err != nil {
 		t.Fatalf("Erro ao decodificar a resposta: %v", err)
 	}
 
 	expected := "erro ao deletar contato"
-	if responseBody.Error != expected {
-		t.Errorf("Mensagem de erro inesperada. Esperado: '%s', obtido: '%s'", expected, responseBody.Error)
+	if responseBody.Error.Message != expected {
+		t.Errorf("Mensagem de erro inesperada. Esperado: '%s', obtido: '%s'", expected, responseBody.Error.Message)
 	}
 }
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Retorna o limite de crédito do contato, a exposição (faturas em aberto mais o valor não enviado
+// dos pedidos confirmados) e o crédito disponível
+// @Security BearerAuth
+func GetContactCreditHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	status, err := service.GetCreditStatus(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao consultar crédito do contato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"credit": status})
+}
+
+// Define o limite de crédito do contato (null remove o limite) e a política ao ultrapassá-lo:
+// block recusa o pedido; approval o deixa retido até a liberação
+// @Security BearerAuth
+func UpdateContactCreditHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.CreditInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	status, err := service.UpdateCreditLimit(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar limite de crédito")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"credit": status})
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Consulta o CNPJ (BrasilAPI, ReceitaWS ou CNPJá) e/ou o CEP (ViaCEP) e devolve o contato sugerido
+// com razão social, nome fantasia, CNAE, inscrição estadual (quando o provedor informa) e
+// endereço. As consultas ficam em cache local por REGISTRY_CACHE_TTL.
+// @Security BearerAuth
+func EnrichContactHandler(c *gin.Context) {
+	var input models.EnrichInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	result, err := service.Enrich(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao consultar CNPJ/CEP")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enrichment": result})
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os pares de contatos prováveis duplicados: mesmo CPF/CNPJ, nome normalizado igual ou
+// parecido e e-mail igual ou parecido, da maior nota (0 a 1) para a menor
+// @Security BearerAuth
+// @Param min_score query number false "Nota mínima do par (padrão 0)"
+func ListDuplicateContactsHandler(c *gin.Context) {
+	minScore := 0.0
+	if value := c.Query("min_score"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			c.Error(errors.InvalidParam("min_score deve ser um número entre 0 e 1"))
+			return
+		}
+		minScore = parsed
+	}
+
+	pairs, err := service.FindDuplicates(c.Request.Context(), minScore)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar contatos duplicados")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"duplicates": pairs})
+}
+
+// Mescla contatos duplicados: cotações, pedidos, faturas, processos de venda e os demais registros
+// dos duplicados passam para o contato mantido em uma única transação, e os duplicados vão para a
+// lixeira
+// @Security BearerAuth
+func MergeContactsHandler(c *gin.Context) {
+	var input models.MergeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	result, err := service.MergeContacts(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao mesclar contatos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"merge": result})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Retorna a condição de pagamento estruturada do contato, com o texto usado nos documentos
+// @Security BearerAuth
+func GetContactPaymentTermsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	terms, err := service.GetPaymentTerms(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao consultar condição de pagamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment_terms": terms})
+}
+
+// Define a condição de pagamento do contato: vencimento em net_days ou parcelas iguais em
+// installment_days (dias corridos ou, com business_days, úteis) e desconto de discount_percent
+// para pagamento em até discount_days. Vale para as faturas geradas dos pedidos de venda.
+// @Security BearerAuth
+func SaveContactPaymentTermsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.PaymentTermsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	terms, err := service.SavePaymentTerms(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar condição de pagamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment_terms": terms})
+}
+
+// Remove a condição de pagamento do contato; as faturas voltam a seguir o texto do pedido
+// @Security BearerAuth
+func DeleteContactPaymentTermsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	if err := service.DeletePaymentTerms(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover condição de pagamento")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Formato aceito pelos parâmetros de data do extrato
+const statementDateLayout = "2006-01-02"
+
+// Retorna o extrato do contato (faturas, pagamentos e notas de crédito) com saldo acumulado
+func GetContactStatementHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	from, err := parseStatementDate(c.Query("from"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data inicial inválida").WithDetails(err.Error()))
+		return
+	}
+
+	to, err := parseStatementDate(c.Query("to"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data final inválida").WithDetails(err.Error()))
+		return
+	}
+
+	statement, err := service.GetContactStatement(c.Request.Context(), id, from, to)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar extrato do contato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statement": statement})
+}
+
+// Retorna o saldo em aberto (a receber e a pagar) do contato
+func GetContactBalanceHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	balance, err := service.GetContactBalance(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao calcular saldo do contato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balance": balance})
+}
+
+// parseStatementDate converte o parâmetro de data; vazio resulta em data zero
+func parseStatementDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(statementDateLayout, value)
+}
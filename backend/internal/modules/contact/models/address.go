@@ -0,0 +1,140 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/utils/validation"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tipos de endereço do contato
+const (
+	AddressTypeBilling  = "billing"
+	AddressTypeShipping = "shipping"
+	// AddressTypeSite é uma unidade do contato (filial, obra, depósito) usada como entrega avulsa
+	AddressTypeSite = "site"
+)
+
+// AddressTypes lista os tipos aceitos
+var AddressTypes = []string{AddressTypeBilling, AddressTypeShipping, AddressTypeSite}
+
+// ContactAddress é um endereço do catálogo do contato. Os documentos guardam o ID e uma cópia em
+// texto, para que a alteração do catálogo não mude documentos já emitidos.
+type ContactAddress struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	CompanyID    int       `json:"company_id" gorm:"<-:create"`
+	ContactID    int       `json:"contact_id"`
+	Type         string    `json:"type"`
+	Label        string    `json:"label"`
+	ZipCode      string    `json:"zip_code"`
+	Street       string    `json:"street"`
+	Number       string    `json:"number"`
+	Complement   string    `json:"complement"`
+	Neighborhood string    `json:"neighborhood"`
+	City         string    `json:"city"`
+	State        string    `json:"state"`
+	Latitude     *float64  `json:"latitude,omitempty"`
+	Longitude    *float64  `json:"longitude,omitempty"`
+	IsDefault    bool      `json:"is_default"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName define a tabela dos endereços
+func (ContactAddress) TableName() string {
+	return "contact_addresses"
+}
+
+// AddressInput são os dados de criação e alteração de um endereço
+type AddressInput struct {
+	Type         string   `json:"type" binding:"required"`
+	Label        string   `json:"label" binding:"max=100"`
+	ZipCode      string   `json:"zip_code" binding:"required"`
+	Street       string   `json:"street" binding:"required,max=255"`
+	Number       string   `json:"number" binding:"max=20"`
+	Complement   string   `json:"complement" binding:"max=100"`
+	Neighborhood string   `json:"neighborhood" binding:"max=100"`
+	City         string   `json:"city" binding:"required,max=100"`
+	State        string   `json:"state" binding:"required"`
+	Latitude     *float64 `json:"latitude"`
+	Longitude    *float64 `json:"longitude"`
+	IsDefault    bool     `json:"is_default"`
+}
+
+// cepPattern aceita o CEP com ou sem o hífen (01001-000 ou 01001000)
+var cepPattern = regexp.MustCompile(`^\d{5}-?\d{3}$`)
+
+// NormalizeCEP confere o formato do CEP e o devolve só com os dígitos
+func NormalizeCEP(cep string) (string, error) {
+	cep = strings.TrimSpace(cep)
+	if !cepPattern.MatchString(cep) {
+		return "", errors.ErrInvalidCEP
+	}
+	return validation.OnlyDigits(cep), nil
+}
+
+// ToAddress valida os dados e monta o endereço: tipo conhecido, CEP com 8 dígitos, UF com duas
+// letras e coordenadas informadas juntas e dentro dos limites
+func (in AddressInput) ToAddress(contactID int) (*ContactAddress, error) {
+	addressType := strings.ToLower(strings.TrimSpace(in.Type))
+	known := false
+	for _, t := range AddressTypes {
+		known = known || t == addressType
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: tipo deve ser %s", errors.ErrInvalidAddress, strings.Join(AddressTypes, ", "))
+	}
+	zipCode, err := NormalizeCEP(in.ZipCode)
+	if err != nil {
+		return nil, err
+	}
+	state := strings.ToUpper(strings.TrimSpace(in.State))
+	if len(state) != 2 {
+		return nil, fmt.Errorf("%w: UF deve ter duas letras", errors.ErrInvalidAddress)
+	}
+	if (in.Latitude == nil) != (in.Longitude == nil) {
+		return nil, fmt.Errorf("%w: informe latitude e longitude juntas", errors.ErrInvalidAddress)
+	}
+	if in.Latitude != nil && (*in.Latitude < -90 || *in.Latitude > 90 || *in.Longitude < -180 || *in.Longitude > 180) {
+		return nil, fmt.Errorf("%w: coordenadas fora dos limites", errors.ErrInvalidAddress)
+	}
+
+	return &ContactAddress{
+		ContactID:    contactID,
+		Type:         addressType,
+		Label:        strings.TrimSpace(in.Label),
+		ZipCode:      zipCode,
+		Street:       strings.TrimSpace(in.Street),
+		Number:       strings.TrimSpace(in.Number),
+		Complement:   strings.TrimSpace(in.Complement),
+		Neighborhood: strings.TrimSpace(in.Neighborhood),
+		City:         strings.TrimSpace(in.City),
+		State:        state,
+		Latitude:     in.Latitude,
+		Longitude:    in.Longitude,
+		IsDefault:    in.IsDefault,
+	}, nil
+}
+
+// Format devolve o endereço em uma linha, como é copiado para os documentos:
+// "Rua X, 10 - Sala 2 - Centro - São Paulo/SP - CEP 01001-000"
+func (a ContactAddress) Format() string {
+	parts := make([]string, 0, 5)
+	street := a.Street
+	if a.Number != "" {
+		street += ", " + a.Number
+	}
+	parts = append(parts, street)
+	for _, part := range []string{a.Complement, a.Neighborhood} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	parts = append(parts, a.City+"/"+a.State)
+	if len(a.ZipCode) == 8 {
+		parts = append(parts, "CEP "+a.ZipCode[:5]+"-"+a.ZipCode[5:])
+	}
+	return strings.Join(parts, " - ")
+}
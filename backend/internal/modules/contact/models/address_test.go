@@ -0,0 +1,56 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCEP(t *testing.T) {
+	cep, err := NormalizeCEP(" 01001-000 ")
+	require.NoError(t, err)
+	assert.Equal(t, "01001000", cep)
+
+	for _, invalid := range []string{"0100100", "01001-00a", "01.001-000", ""} {
+		_, err := NormalizeCEP(invalid)
+		assert.Equal(t, errors.ErrInvalidCEP, err, invalid)
+	}
+}
+
+func TestAddressInputToAddress(t *testing.T) {
+	lat, lng := -23.55, -46.63
+	input := AddressInput{Type: "Shipping", ZipCode: "01001-000", Street: "Praça da Sé", Number: "1",
+		City: "São Paulo", State: "sp", Latitude: &lat, Longitude: &lng}
+
+	address, err := input.ToAddress(5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, address.ContactID)
+	assert.Equal(t, AddressTypeShipping, address.Type)
+	assert.Equal(t, "01001000", address.ZipCode)
+	assert.Equal(t, "SP", address.State)
+
+	input.Type = "matriz"
+	_, err = input.ToAddress(5)
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidAddress))
+
+	input.Type, input.Longitude = AddressTypeSite, nil
+	_, err = input.ToAddress(5)
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidAddress), "latitude sem longitude")
+
+	bad := 200.0
+	input.Longitude = &bad
+	_, err = input.ToAddress(5)
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidAddress), "longitude fora dos limites")
+}
+
+func TestAddressFormat(t *testing.T) {
+	address := ContactAddress{Street: "Rua X", Number: "10", Complement: "Sala 2", Neighborhood: "Centro",
+		City: "São Paulo", State: "SP", ZipCode: "01001000"}
+	assert.Equal(t, "Rua X, 10 - Sala 2 - Centro - São Paulo/SP - CEP 01001-000", address.Format())
+
+	address.Number, address.Complement, address.Neighborhood = "", "", ""
+	assert.Equal(t, "Rua X - São Paulo/SP - CEP 01001-000", address.Format())
+}
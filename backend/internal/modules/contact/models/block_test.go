@@ -0,0 +1,40 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockInputValidate(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 30, 0, 0, time.UTC)
+
+	in := BlockInput{Reason: " Fraud "}
+	require.NoError(t, in.Validate(now))
+	assert.Equal(t, BlockReasonFraud, in.Reason)
+	assert.Equal(t, time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), *in.EffectiveDate)
+
+	future := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	in = BlockInput{Reason: BlockReasonSanctions, EffectiveDate: &future}
+	require.NoError(t, in.Validate(now))
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), *in.EffectiveDate)
+
+	in = BlockInput{Reason: "debt"}
+	assert.True(t, stderrors.Is(in.Validate(now), errors.ErrInvalidContactBlock))
+
+	in = BlockInput{Reason: BlockReasonOther, Notes: "  "}
+	assert.True(t, stderrors.Is(in.Validate(now), errors.ErrInvalidContactBlock))
+}
+
+func TestContactIsBlockedOn(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	contact := Contact{BlockedFrom: &from}
+
+	assert.False(t, contact.IsBlockedOn(time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, contact.IsBlockedOn(time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)))
+	assert.False(t, Contact{}.IsBlockedOn(from))
+}
@@ -1,9 +1,14 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+)
 
 type Contact struct {
 	ID           int    `json:"id"`
+	CompanyID    int    `json:"company_id" gorm:"<-:create"`
 	PersonType   string `json:"person_type" binding:"required,oneof=pf pj"`
 	Type         string `json:"type" binding:"required,oneof=cliente fornecedor lead"`
 	Name         string `json:"name" binding:"required"`
@@ -16,6 +21,10 @@ type Contact struct {
 	CCM          string `json:"ccm"`
 	Email        string `json:"email" binding:"required,email"`
 	Phone        string `json:"phone"`
+	// CNAE principal (só dígitos), preenchido pela consulta do CNPJ em POST /contacts/enrich
+	CNAE string `json:"cnae"`
+	// Idioma dos documentos e e-mails enviados ao contato (pt-BR ou en-US); vazio segue o da requisição
+	PreferredLanguage string `json:"preferred_language" binding:"omitempty,oneof=pt-BR en-US"`
 
 	ZipCode      string `json:"zip_code" binding:"required"`
 	Street       string `json:"street"`
@@ -25,6 +34,26 @@ type Contact struct {
 	City         string `json:"city"`
 	State        string `json:"state"`
 
+	// Segmento RFM calculado pela análise de clientes (ver GET /analytics/rfm); só o recálculo grava
+	RFMSegment string `json:"rfm_segment" gorm:"<-:false"`
+	// Situação cadastral do CNPJ na última revalidação periódica; só a revalidação grava
+	RegistryStatus    string     `json:"registry_status" gorm:"<-:false"`
+	RegistryCheckedAt *time.Time `json:"registry_checked_at,omitempty" gorm:"<-:false"`
+	// Limite de crédito (nil = sem limite) e política ao ultrapassá-lo; só PUT /contacts/:id/credit grava
+	CreditLimit  *float64 `json:"credit_limit" gorm:"<-:false"`
+	CreditPolicy string   `json:"credit_policy" gorm:"<-:false"`
+	// Bloqueio (fraude, litígio, sanções) a partir de BlockedFrom; só POST /contacts/:id/block e
+	// /unblock gravam
+	BlockReason string     `json:"block_reason" gorm:"<-:false"`
+	BlockNotes  string     `json:"block_notes" gorm:"<-:false"`
+	BlockedFrom *time.Time `json:"blocked_from,omitempty" gorm:"<-:false"`
+	BlockedBy   string     `json:"blocked_by" gorm:"<-:false"`
+	// Campos personalizados definidos em /custom-fields (entidade contact)
+	CustomFields customfields.Values `json:"custom_fields" gorm:"type:jsonb"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Preenchido quando o contato está na lixeira. Não usa gorm.DeletedAt para que os
+	// documentos já emitidos continuem carregando o contato arquivado nos preloads.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"fmt"
+	"math"
+)
+
+// Políticas aplicadas ao pedido que ultrapassa o limite de crédito
+const (
+	// CreditPolicyBlock recusa o pedido
+	CreditPolicyBlock = "block"
+	// CreditPolicyApproval deixa o pedido retido até a liberação por um administrador
+	CreditPolicyApproval = "approval"
+)
+
+// CreditInput define o limite de crédito do contato; sem limite, os pedidos não são conferidos
+type CreditInput struct {
+	CreditLimit  *float64 `json:"credit_limit"`
+	CreditPolicy string   `json:"credit_policy"`
+}
+
+// Validate confere o limite (não negativo) e a política, que vale approval quando omitida
+func (in *CreditInput) Validate() error {
+	if in.CreditLimit != nil && *in.CreditLimit < 0 {
+		return fmt.Errorf("%w: o limite não pode ser negativo", errors.ErrInvalidCreditLimit)
+	}
+	switch in.CreditPolicy {
+	case "":
+		in.CreditPolicy = CreditPolicyApproval
+	case CreditPolicyBlock, CreditPolicyApproval:
+	default:
+		return fmt.Errorf("%w: a política deve ser %s ou %s", errors.ErrInvalidCreditLimit, CreditPolicyBlock, CreditPolicyApproval)
+	}
+	return nil
+}
+
+// CreditStatus é a exposição de crédito do contato: faturas em aberto mais o valor ainda não
+// entregue dos pedidos confirmados, em andamento ou retidos
+type CreditStatus struct {
+	ContactID    int      `json:"contact_id"`
+	CreditLimit  *float64 `json:"credit_limit"`
+	CreditPolicy string   `json:"credit_policy"`
+	OpenInvoices float64  `json:"open_invoices"`
+	OpenOrders   float64  `json:"open_orders"`
+	Exposure     float64  `json:"exposure"`
+	// Available é o limite menos a exposição (negativo quando já ultrapassado); nil sem limite
+	Available *float64 `json:"available"`
+}
+
+// NewCreditStatus soma a exposição e calcula o crédito disponível
+func NewCreditStatus(contactID int, limit *float64, policy string, openInvoices, openOrders float64) *CreditStatus {
+	status := &CreditStatus{
+		ContactID:    contactID,
+		CreditLimit:  limit,
+		CreditPolicy: policy,
+		OpenInvoices: round2(openInvoices),
+		OpenOrders:   round2(openOrders),
+		Exposure:     round2(openInvoices + openOrders),
+	}
+	if limit != nil {
+		available := round2(*limit - status.Exposure)
+		status.Available = &available
+	}
+	return status
+}
+
+// Allows indica se um novo pedido de amount cabe no limite
+func (s *CreditStatus) Allows(amount float64) bool {
+	return s.Available == nil || round2(amount) <= *s.Available
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
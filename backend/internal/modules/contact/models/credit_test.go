@@ -0,0 +1,41 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCreditStatusComputesAvailable(t *testing.T) {
+	limit := 1000.0
+	status := NewCreditStatus(7, &limit, CreditPolicyBlock, 300.004, 450.5)
+
+	assert.Equal(t, 750.5, status.Exposure)
+	require.NotNil(t, status.Available)
+	assert.Equal(t, 249.5, *status.Available)
+	assert.True(t, status.Allows(249.5))
+	assert.False(t, status.Allows(249.51))
+}
+
+func TestNewCreditStatusWithoutLimitAllowsAnything(t *testing.T) {
+	status := NewCreditStatus(7, nil, CreditPolicyApproval, 5000, 0)
+
+	assert.Nil(t, status.Available)
+	assert.True(t, status.Allows(1e9))
+}
+
+func TestCreditInputValidate(t *testing.T) {
+	in := CreditInput{}
+	require.NoError(t, in.Validate())
+	assert.Equal(t, CreditPolicyApproval, in.CreditPolicy)
+
+	negative := -1.0
+	in = CreditInput{CreditLimit: &negative}
+	assert.True(t, stderrors.Is(in.Validate(), errors.ErrInvalidCreditLimit))
+
+	in = CreditInput{CreditPolicy: "warn"}
+	assert.True(t, stderrors.Is(in.Validate(), errors.ErrInvalidCreditLimit))
+}
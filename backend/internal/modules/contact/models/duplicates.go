@@ -0,0 +1,205 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Motivos que apontam dois contatos como prováveis duplicados
+const (
+	DuplicateReasonDocument     = "document"
+	DuplicateReasonName         = "name"
+	DuplicateReasonSimilarName  = "similar_name"
+	DuplicateReasonEmail        = "email"
+	DuplicateReasonSimilarEmail = "similar_email"
+)
+
+// duplicateScores pesa cada motivo; o par fica com a maior nota entre os motivos encontrados
+var duplicateScores = map[string]float64{
+	DuplicateReasonDocument:     1,
+	DuplicateReasonEmail:        0.9,
+	DuplicateReasonName:         0.8,
+	DuplicateReasonSimilarEmail: 0.6,
+	DuplicateReasonSimilarName:  0.6,
+}
+
+// SimilarityThreshold é a semelhança mínima entre nomes ou e-mails normalizados para apontar o par
+const SimilarityThreshold = 0.85
+
+// companySuffixes são as formas societárias ignoradas na comparação de nomes
+var companySuffixes = map[string]bool{
+	"ltda": true, "me": true, "epp": true, "eireli": true, "sa": true, "s/a": true, "mei": true, "cia": true,
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// DuplicatePair são dois contatos que provavelmente representam a mesma pessoa ou empresa
+type DuplicatePair struct {
+	ContactID     int      `json:"contact_id"`
+	ContactName   string   `json:"contact_name"`
+	DuplicateID   int      `json:"duplicate_id"`
+	DuplicateName string   `json:"duplicate_name"`
+	Reasons       []string `json:"reasons"`
+	Score         float64  `json:"score"`
+}
+
+// MergeInput é o corpo aceito em POST /contacts/merge
+type MergeInput struct {
+	SurvivorID   int   `json:"survivor_id" binding:"required,gt=0"`
+	DuplicateIDs []int `json:"duplicate_ids" binding:"required,min=1,dive,gt=0"`
+}
+
+// MergeResult resume a mesclagem: quantos registros de cada tabela passaram para o contato mantido
+type MergeResult struct {
+	SurvivorID int              `json:"survivor_id"`
+	MergedIDs  []int            `json:"merged_ids"`
+	Updated    map[string]int64 `json:"updated"`
+}
+
+// NormalizeDocument mantém só os dígitos do CPF/CNPJ
+func NormalizeDocument(document string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, document)
+}
+
+// NormalizeName deixa o nome em minúsculas, sem acentos, pontuação e forma societária
+func NormalizeName(name string) string {
+	name = accentReplacer.Replace(strings.ToLower(strings.TrimSpace(name)))
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '/'
+	})
+	kept := words[:0]
+	for _, word := range words {
+		if !companySuffixes[word] {
+			kept = append(kept, strings.ReplaceAll(word, "/", ""))
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// NormalizeEmail deixa o e-mail em minúsculas e descarta o sufixo "+tag" do usuário
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	user, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return email
+	}
+	if i := strings.Index(user, "+"); i >= 0 {
+		user = user[:i]
+	}
+	return user + "@" + domain
+}
+
+// Similarity mede a semelhança entre dois textos (0 a 1) pela distância de edição
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// similar indica se os textos normalizados são parecidos o bastante; a diferença de tamanho já
+// descarta a maioria dos pares antes da distância de edição
+func similar(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	la, lb := len([]rune(a)), len([]rune(b))
+	shortest, longest := min(la, lb), max(la, lb)
+	if float64(shortest) < SimilarityThreshold*float64(longest) {
+		return false
+	}
+	return Similarity(a, b) >= SimilarityThreshold
+}
+
+// DuplicateReasons compara dois contatos e retorna os motivos que os apontam como duplicados
+func DuplicateReasons(a, b Contact) []string {
+	reasons := []string{}
+	if doc := NormalizeDocument(a.Document); doc != "" && doc == NormalizeDocument(b.Document) {
+		reasons = append(reasons, DuplicateReasonDocument)
+	}
+
+	nameA, nameB := NormalizeName(a.Name), NormalizeName(b.Name)
+	if nameA != "" && nameA == nameB {
+		reasons = append(reasons, DuplicateReasonName)
+	} else if similar(nameA, nameB) {
+		reasons = append(reasons, DuplicateReasonSimilarName)
+	}
+
+	emailA, emailB := NormalizeEmail(a.Email), NormalizeEmail(b.Email)
+	if emailA != "" && emailA == emailB {
+		reasons = append(reasons, DuplicateReasonEmail)
+	} else if similar(emailA, emailB) {
+		reasons = append(reasons, DuplicateReasonSimilarEmail)
+	}
+	return reasons
+}
+
+// FindDuplicates compara os contatos dois a dois e retorna os pares prováveis, da maior nota para
+// a menor. O contato mais antigo (menor ID) aparece primeiro no par, como sugestão de registro a
+// manter.
+func FindDuplicates(contacts []Contact) []DuplicatePair {
+	sorted := append([]Contact(nil), contacts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	pairs := []DuplicatePair{}
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			reasons := DuplicateReasons(sorted[i], sorted[j])
+			if len(reasons) == 0 {
+				continue
+			}
+			score := 0.0
+			for _, reason := range reasons {
+				score = max(score, duplicateScores[reason])
+			}
+			pairs = append(pairs, DuplicatePair{
+				ContactID:     sorted[i].ID,
+				ContactName:   sorted[i].Name,
+				DuplicateID:   sorted[j].ID,
+				DuplicateName: sorted[j].Name,
+				Reasons:       reasons,
+				Score:         score,
+			})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Score > pairs[j].Score })
+	return pairs
+}
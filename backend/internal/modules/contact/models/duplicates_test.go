@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "acougue sao joao", NormalizeName("  Açougue São João LTDA. "))
+	assert.Equal(t, "padaria bom pao", NormalizeName("Padaria Bom Pão - ME"))
+	assert.Equal(t, "comercio silva", NormalizeName("Comércio Silva S/A"))
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "joao@exemplo.com", NormalizeEmail(" Joao+compras@Exemplo.com "))
+	assert.Equal(t, "sem-arroba", NormalizeEmail("sem-arroba"))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("abc", "abc"))
+	assert.InDelta(t, 0.9, Similarity("distribuidora", "distribuidra"), 0.05)
+	assert.Less(t, Similarity("joao", "maria"), SimilarityThreshold)
+}
+
+func TestDuplicateReasons(t *testing.T) {
+	a := Contact{ID: 1, Name: "Mercado Central Ltda", Document: "12.345.678/0001-90", Email: "compras@central.com"}
+	b := Contact{ID: 2, Name: "MERCADO CENTRAL", Document: "12345678000190", Email: "compras+nf@central.com"}
+	assert.Equal(t, []string{DuplicateReasonDocument, DuplicateReasonName, DuplicateReasonEmail}, DuplicateReasons(a, b))
+
+	c := Contact{ID: 3, Name: "Mercado Centrall", Document: "98765432000110", Email: "contato@outro.com"}
+	assert.Equal(t, []string{DuplicateReasonSimilarName}, DuplicateReasons(a, c))
+
+	d := Contact{ID: 4, Name: "Farmácia Popular", Document: "11111111000111", Email: "vendas@farmacia.com"}
+	assert.Empty(t, DuplicateReasons(a, d))
+}
+
+func TestFindDuplicatesOrdersByScore(t *testing.T) {
+	pairs := FindDuplicates([]Contact{
+		{ID: 3, Name: "Mercado Centrall", Document: "98765432000110", Email: "contato@outro.com"},
+		{ID: 1, Name: "Mercado Central", Document: "12345678000190", Email: "compras@central.com"},
+		{ID: 2, Name: "Outro Nome", Document: "12345678000190", Email: "financeiro@central.com"},
+		{ID: 4, Name: "Farmácia Popular", Document: "11111111000111", Email: "vendas@farmacia.com"},
+	})
+
+	require.Len(t, pairs, 2)
+	assert.Equal(t, 1, pairs[0].ContactID)
+	assert.Equal(t, 2, pairs[0].DuplicateID)
+	assert.Equal(t, 1.0, pairs[0].Score)
+	assert.Equal(t, 3, pairs[1].DuplicateID)
+	assert.Equal(t, 0.6, pairs[1].Score)
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/integrations/registry"
+	"strings"
+)
+
+// Situações cadastrais gravadas pela revalidação dos CNPJs
+const (
+	RegistryStatusNotFound = "NÃO ENCONTRADO"
+)
+
+// EnrichInput é o CNPJ e/ou o CEP a consultar
+type EnrichInput struct {
+	Document string `json:"document"`
+	ZipCode  string `json:"zip_code"`
+}
+
+// Enrichment é o resultado da consulta: os dados do CNPJ, o endereço do CEP e o contato sugerido
+// para o cadastro
+type Enrichment struct {
+	Company *registry.Company `json:"company,omitempty"`
+	Address *registry.Address `json:"address,omitempty"`
+	Contact Contact           `json:"contact"`
+}
+
+// RegistryCheck é o resultado da revalidação de um CNPJ
+type RegistryCheck struct {
+	CNPJ     string `json:"cnpj"`
+	Status   string `json:"status"`
+	Active   bool   `json:"active"`
+	Contacts int64  `json:"contacts"`
+}
+
+// BuildEnrichment monta o contato sugerido: a razão social, o nome fantasia, o CNAE, a inscrição
+// estadual e o endereço vêm do CNPJ; o endereço do CEP, quando consultado, prevalece sobre o do CNPJ
+// e mantém o número e o complemento do CNPJ se o CEP for o mesmo
+func BuildEnrichment(company *registry.Company, address *registry.Address) *Enrichment {
+	result := &Enrichment{Company: company, Address: address}
+	contact := &result.Contact
+
+	if company != nil {
+		contact.PersonType = "pj"
+		contact.Document = company.CNPJ
+		contact.CompanyName = company.LegalName
+		contact.TradeName = company.TradeName
+		contact.Name = company.TradeName
+		if strings.TrimSpace(contact.Name) == "" {
+			contact.Name = company.LegalName
+		}
+		contact.CNAE = company.CNAE
+		contact.SecondaryDoc = company.StateRegistration
+		contact.Email = company.Email
+		contact.Phone = company.Phone
+		contact.RegistryStatus = company.Status
+		applyAddress(contact, company.Address)
+	}
+
+	if address != nil {
+		number, complement := contact.Number, contact.Complement
+		if contact.ZipCode != address.ZipCode {
+			number, complement = "", ""
+		}
+		applyAddress(contact, *address)
+		contact.Number = number
+		if address.Complement == "" {
+			contact.Complement = complement
+		}
+	}
+	return result
+}
+
+func applyAddress(contact *Contact, address registry.Address) {
+	contact.ZipCode = address.ZipCode
+	contact.Street = address.Street
+	contact.Number = address.Number
+	contact.Complement = address.Complement
+	contact.Neighborhood = address.Neighborhood
+	contact.City = address.City
+	contact.State = address.State
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/integrations/registry"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEnrichmentFromCompany(t *testing.T) {
+	company := &registry.Company{
+		CNPJ: "19131243000197", LegalName: "OPEN KNOWLEDGE BRASIL", Status: "ATIVA", Active: true,
+		CNAE: "9430800", StateRegistration: "123456789",
+		Address: registry.Address{ZipCode: "01311902", Street: "AVENIDA PAULISTA", Number: "37", Complement: "ANDAR 4", City: "SAO PAULO", State: "SP"},
+	}
+
+	contact := BuildEnrichment(company, nil).Contact
+	assert.Equal(t, "pj", contact.PersonType)
+	assert.Equal(t, "OPEN KNOWLEDGE BRASIL", contact.Name)
+	assert.Equal(t, "OPEN KNOWLEDGE BRASIL", contact.CompanyName)
+	assert.Equal(t, "123456789", contact.SecondaryDoc)
+	assert.Equal(t, "9430800", contact.CNAE)
+	assert.Equal(t, "37", contact.Number)
+
+	sameCEP := BuildEnrichment(company, &registry.Address{ZipCode: "01311902", Street: "Avenida Paulista", City: "São Paulo", State: "SP"}).Contact
+	assert.Equal(t, "Avenida Paulista", sameCEP.Street)
+	assert.Equal(t, "37", sameCEP.Number)
+	assert.Equal(t, "ANDAR 4", sameCEP.Complement)
+
+	otherCEP := BuildEnrichment(company, &registry.Address{ZipCode: "01001000", Street: "Praça da Sé", City: "São Paulo", State: "SP"}).Contact
+	assert.Equal(t, "Praça da Sé", otherCEP.Street)
+	assert.Empty(t, otherCEP.Number)
+	assert.Empty(t, otherCEP.Complement)
+}
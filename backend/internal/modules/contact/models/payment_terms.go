@@ -0,0 +1,157 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limites da condição de pagamento: prazo de até dez anos e o máximo de parcelas da fatura
+const (
+	maxPaymentTermDays   = 3650
+	maxPaymentTermSplits = 120
+)
+
+// PaymentTerms é a condição de pagamento estruturada do contato, aplicada ao faturar os pedidos
+// de venda: vencimento único em NetDays ou parcelas iguais em InstallmentDays (dias contados da
+// emissão, corridos ou úteis) e desconto de DiscountPercent para pagamento em até DiscountDays
+type PaymentTerms struct {
+	ID              int       `json:"id" gorm:"primaryKey"`
+	CompanyID       int       `json:"company_id" gorm:"<-:create"`
+	ContactID       int       `json:"contact_id"`
+	NetDays         int       `json:"net_days"`
+	BusinessDays    bool      `json:"business_days"`
+	InstallmentDays []int     `json:"installment_days" gorm:"serializer:json"`
+	DiscountPercent float64   `json:"discount_percent"`
+	DiscountDays    int       `json:"discount_days"`
+	Label           string    `json:"label" gorm:"-"`
+	UpdatedBy       string    `json:"updated_by"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela das condições de pagamento
+func (PaymentTerms) TableName() string {
+	return "contact_payment_terms"
+}
+
+// PaymentTermsInput é o corpo aceito em PUT /contacts/:id/payment-terms: net_days ou, para
+// parcelar, installment_days (ex.: [30, 60, 90])
+type PaymentTermsInput struct {
+	NetDays         int     `json:"net_days"`
+	BusinessDays    bool    `json:"business_days"`
+	InstallmentDays []int   `json:"installment_days"`
+	DiscountPercent float64 `json:"discount_percent"`
+	DiscountDays    int     `json:"discount_days"`
+}
+
+// ToTerms valida os prazos e o desconto e monta a condição do contato
+func (in PaymentTermsInput) ToTerms(contactID int, username string) (*PaymentTerms, error) {
+	if in.NetDays < 0 || in.NetDays > maxPaymentTermDays {
+		return nil, fmt.Errorf("%w: net_days deve estar entre 0 e %d", errors.ErrInvalidPaymentTerms, maxPaymentTermDays)
+	}
+	if len(in.InstallmentDays) > 0 {
+		if in.NetDays > 0 {
+			return nil, fmt.Errorf("%w: informe net_days ou installment_days", errors.ErrInvalidPaymentTerms)
+		}
+		if len(in.InstallmentDays) > maxPaymentTermSplits {
+			return nil, fmt.Errorf("%w: no máximo %d parcelas", errors.ErrInvalidPaymentTerms, maxPaymentTermSplits)
+		}
+		for i, days := range in.InstallmentDays {
+			if days < 0 || days > maxPaymentTermDays || (i > 0 && days <= in.InstallmentDays[i-1]) {
+				return nil, fmt.Errorf("%w: installment_days deve ser crescente, entre 0 e %d", errors.ErrInvalidPaymentTerms, maxPaymentTermDays)
+			}
+		}
+	}
+	if in.DiscountPercent < 0 || in.DiscountPercent >= 100 || in.DiscountDays < 0 {
+		return nil, fmt.Errorf("%w: o desconto deve estar entre 0 e 100%% e o prazo não pode ser negativo", errors.ErrInvalidPaymentTerms)
+	}
+
+	terms := &PaymentTerms{
+		ContactID:       contactID,
+		NetDays:         in.NetDays,
+		BusinessDays:    in.BusinessDays,
+		InstallmentDays: in.InstallmentDays,
+		DiscountPercent: math.Round(in.DiscountPercent*100) / 100,
+		DiscountDays:    in.DiscountDays,
+		UpdatedBy:       username,
+	}
+	if terms.DiscountPercent > 0 {
+		if len(terms.Days()) > 1 {
+			return nil, fmt.Errorf("%w: o desconto por antecipação vale só para vencimento único", errors.ErrInvalidPaymentTerms)
+		}
+		if terms.DiscountDays > terms.Days()[0] {
+			return nil, fmt.Errorf("%w: o prazo do desconto passa do vencimento", errors.ErrInvalidPaymentTerms)
+		}
+	} else {
+		terms.DiscountDays = 0
+	}
+	if terms.InstallmentDays == nil {
+		terms.InstallmentDays = []int{}
+	}
+	terms.Label = terms.Describe()
+	return terms, nil
+}
+
+// Days retorna os prazos de vencimento em dias a partir da emissão
+func (t *PaymentTerms) Days() []int {
+	if len(t.InstallmentDays) > 0 {
+		return t.InstallmentDays
+	}
+	return []int{t.NetDays}
+}
+
+// Describe escreve a condição no texto livre usado nos documentos ("À vista", "30 dias",
+// "30/60/90 dias úteis", com o desconto ao final), que o cálculo de vencimento por texto entende
+func (t *PaymentTerms) Describe() string {
+	days := t.Days()
+	var label string
+	if len(days) == 1 && days[0] == 0 {
+		label = "À vista"
+	} else {
+		parts := make([]string, len(days))
+		for i, d := range days {
+			parts[i] = strconv.Itoa(d)
+		}
+		label = strings.Join(parts, "/") + " dias"
+		if t.BusinessDays {
+			label += " úteis"
+		}
+	}
+	if t.DiscountPercent > 0 {
+		percent := strings.Replace(strconv.FormatFloat(t.DiscountPercent, 'f', -1, 64), ".", ",", 1)
+		label += fmt.Sprintf(", %s%% de desconto para pagamento em até %d dias", percent, t.DiscountDays)
+	}
+	return label
+}
+
+// DueDates calcula os vencimentos a partir da emissão pelo calendário da empresa: prazo zero vence
+// na emissão, dias úteis pulam fins de semana e feriados e dias corridos que caem em dia não útil
+// são adiados, como em PaymentDueDate
+func (t *PaymentTerms) DueDates(issue time.Time, schedule *calendar.Schedule) []time.Time {
+	days := t.Days()
+	dates := make([]time.Time, len(days))
+	for i, d := range days {
+		switch {
+		case d == 0:
+			dates[i] = issue
+		case t.BusinessDays:
+			dates[i] = schedule.AddBusinessDays(issue, d)
+		default:
+			dates[i] = schedule.NextBusinessDay(issue.AddDate(0, 0, d))
+		}
+	}
+	return dates
+}
+
+// DiscountUntil é o último dia para pagar com desconto; falso sem desconto na condição
+func (t *PaymentTerms) DiscountUntil(issue time.Time) (time.Time, bool) {
+	if t.DiscountPercent <= 0 {
+		return time.Time{}, false
+	}
+	return issue.AddDate(0, 0, t.DiscountDays), true
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func termsDay(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestPaymentTermsInputToTerms(t *testing.T) {
+	terms, err := PaymentTermsInput{NetDays: 28, DiscountPercent: 2.5, DiscountDays: 10}.ToTerms(7, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, 7, terms.ContactID)
+	assert.Equal(t, []int{28}, terms.Days())
+	assert.Equal(t, []int{}, terms.InstallmentDays)
+	assert.Equal(t, "28 dias, 2,5% de desconto para pagamento em até 10 dias", terms.Label)
+	assert.Equal(t, "ana", terms.UpdatedBy)
+
+	terms, err = PaymentTermsInput{NetDays: 30, DiscountDays: 10}.ToTerms(7, "ana")
+	require.NoError(t, err)
+	assert.Zero(t, terms.DiscountDays, "sem percentual não há prazo de desconto")
+
+	invalid := []PaymentTermsInput{
+		{NetDays: -1},
+		{NetDays: 4000},
+		{NetDays: 30, InstallmentDays: []int{30, 60}},
+		{InstallmentDays: []int{60, 30}},
+		{InstallmentDays: []int{30, 30}},
+		{InstallmentDays: []int{-5, 30}},
+		{NetDays: 30, DiscountPercent: 100, DiscountDays: 5},
+		{NetDays: 30, DiscountPercent: 2, DiscountDays: 45},
+		{InstallmentDays: []int{30, 60}, DiscountPercent: 2, DiscountDays: 10},
+	}
+	for _, in := range invalid {
+		_, err := in.ToTerms(7, "ana")
+		assert.True(t, stderrors.Is(err, errors.ErrInvalidPaymentTerms), "%+v: %v", in, err)
+	}
+}
+
+func TestPaymentTermsDescribe(t *testing.T) {
+	assert.Equal(t, "À vista", (&PaymentTerms{}).Describe())
+	assert.Equal(t, "30/60/90 dias", (&PaymentTerms{InstallmentDays: []int{30, 60, 90}}).Describe())
+	assert.Equal(t, "15 dias úteis", (&PaymentTerms{NetDays: 15, BusinessDays: true}).Describe())
+}
+
+func TestPaymentTermsDueDates(t *testing.T) {
+	schedule := calendar.DefaultSchedule()
+	issue := termsDay(3, 2)
+
+	terms := &PaymentTerms{InstallmentDays: []int{0, 30, 60, 90}}
+	dates := terms.DueDates(issue, schedule)
+	// 01/05 é feriado nacional e 31/05 cai num domingo: os vencimentos passam ao dia útil seguinte
+	assert.Equal(t, []time.Time{issue, termsDay(4, 1), termsDay(5, 4), termsDay(6, 1)}, dates)
+
+	// Os prazos em texto seguem o mesmo cálculo de PaymentDueDate
+	business := &PaymentTerms{NetDays: 10, BusinessDays: true}
+	assert.Equal(t, schedule.PaymentDueDate(issue, business.Describe()), business.DueDates(issue, schedule)[0])
+
+	until, ok := (&PaymentTerms{NetDays: 30, DiscountPercent: 2, DiscountDays: 10}).DiscountUntil(issue)
+	assert.True(t, ok)
+	assert.Equal(t, termsDay(3, 12), until)
+	_, ok = (&PaymentTerms{NetDays: 30}).DiscountUntil(issue)
+	assert.False(t, ok)
+}
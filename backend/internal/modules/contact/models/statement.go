@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// Tipos de documento que compõem o extrato do contato
+const (
+	StatementEntryInvoice    = "invoice"
+	StatementEntryPayment    = "payment"
+	StatementEntryCreditNote = "credit_note"
+)
+
+// StatementEntry representa uma linha do extrato com saldo acumulado
+type StatementEntry struct {
+	EntryDate    time.Time     `json:"entry_date"`
+	DocumentType string        `json:"document_type"`
+	DocumentID   int           `json:"document_id"`
+	DocumentNo   string        `json:"document_no"`
+	Description  string        `json:"description"`
+	Debit        money.Decimal `json:"debit"`
+	Credit       money.Decimal `json:"credit"`
+	Balance      money.Decimal `json:"balance"`
+}
+
+// ContactStatement representa o extrato cronológico de um contato em um período
+type ContactStatement struct {
+	ContactID      int              `json:"contact_id"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance money.Decimal    `json:"opening_balance"`
+	TotalDebits    money.Decimal    `json:"total_debits"`
+	TotalCredits   money.Decimal    `json:"total_credits"`
+	ClosingBalance money.Decimal    `json:"closing_balance"`
+	Entries        []StatementEntry `json:"entries"`
+}
+
+// ContactBalance representa a posição em aberto (a receber e a pagar) de um contato
+type ContactBalance struct {
+	ContactID           int           `json:"contact_id"`
+	OpenReceivables     money.Decimal `json:"open_receivables"`
+	OverdueReceivables  money.Decimal `json:"overdue_receivables"`
+	OpenInvoices        int           `json:"open_invoices"`
+	DisputedReceivables money.Decimal `json:"disputed_receivables"`
+	UnappliedCredits    money.Decimal `json:"unapplied_credits"`
+	OpenPayables        money.Decimal `json:"open_payables"`
+	OverduePayables     money.Decimal `json:"overdue_payables"`
+	OpenBills           int           `json:"open_bills"`
+	NetBalance          money.Decimal `json:"net_balance"`
+}
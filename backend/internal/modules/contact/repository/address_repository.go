@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AddressRepository mantém o catálogo de endereços dos contatos
+type AddressRepository interface {
+	ListAddresses(ctx context.Context, contactID int) ([]models.ContactAddress, error)
+	GetAddress(ctx context.Context, contactID, id int) (*models.ContactAddress, error)
+	CreateAddress(ctx context.Context, address *models.ContactAddress) error
+	UpdateAddress(ctx context.Context, address *models.ContactAddress) error
+	DeleteAddress(ctx context.Context, contactID, id int) error
+}
+
+type addressRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewAddressRepository cria uma nova instância do repositório
+func NewAddressRepository() (AddressRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &addressRepository{
+		db:     db,
+		logger: logger.WithModule("contact_address_repository"),
+	}, nil
+}
+
+// ListAddresses lista os endereços do contato, os padrões primeiro
+func (r *addressRepository) ListAddresses(ctx context.Context, contactID int) ([]models.ContactAddress, error) {
+	if err := ensureContact(db.Conn(ctx, r.db), contactID); err != nil {
+		return nil, err
+	}
+	var addresses []models.ContactAddress
+	if err := db.Conn(ctx, r.db).Where("contact_id = ?", contactID).
+		Order("type ASC, is_default DESC, id ASC").Find(&addresses).Error; err != nil {
+		r.logger.Error("erro ao listar endereços", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao listar endereços")
+	}
+	return addresses, nil
+}
+
+// GetAddress busca um endereço do contato
+func (r *addressRepository) GetAddress(ctx context.Context, contactID, id int) (*models.ContactAddress, error) {
+	return findAddress(db.Conn(ctx, r.db), contactID, id)
+}
+
+// CreateAddress grava o endereço; o primeiro de cada tipo vira o padrão
+func (r *addressRepository) CreateAddress(ctx context.Context, address *models.ContactAddress) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := ensureContact(tx, address.ContactID); err != nil {
+			return err
+		}
+		var defaults int64
+		if err := tx.Model(&models.ContactAddress{}).
+			Where("contact_id = ? AND type = ? AND is_default", address.ContactID, address.Type).
+			Count(&defaults).Error; err != nil {
+			return errors.WrapError(err, "falha ao verificar endereço padrão")
+		}
+		if defaults == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if err := clearDefault(tx, address.ContactID, address.Type, 0); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(address).Error; err != nil {
+			r.logger.Error("erro ao criar endereço", zap.Error(err), zap.Int("contact_id", address.ContactID))
+			return errors.WrapError(err, "falha ao criar endereço")
+		}
+		return nil
+	})
+}
+
+// UpdateAddress altera o endereço. Deixar de ser o padrão só acontece marcando outro como padrão.
+func (r *addressRepository) UpdateAddress(ctx context.Context, address *models.ContactAddress) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		existing, err := findAddress(tx, address.ContactID, address.ID)
+		if err != nil {
+			return err
+		}
+		if existing.IsDefault && existing.Type == address.Type {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if err := clearDefault(tx, address.ContactID, address.Type, address.ID); err != nil {
+				return err
+			}
+		}
+		address.CompanyID, address.CreatedAt = existing.CompanyID, existing.CreatedAt
+		if err := tx.Select("*").Omit("company_id", "created_at").Updates(address).Error; err != nil {
+			r.logger.Error("erro ao atualizar endereço", zap.Error(err), zap.Int("id", address.ID))
+			return errors.WrapError(err, "falha ao atualizar endereço")
+		}
+		if existing.IsDefault && existing.Type != address.Type {
+			return promoteDefault(tx, existing.ContactID, existing.Type)
+		}
+		return nil
+	})
+}
+
+// DeleteAddress remove o endereço; sendo o padrão, o mais antigo do mesmo tipo assume. Os
+// documentos que o usavam mantêm a cópia em texto.
+func (r *addressRepository) DeleteAddress(ctx context.Context, contactID, id int) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		existing, err := findAddress(tx, contactID, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.ContactAddress{}, id).Error; err != nil {
+			r.logger.Error("erro ao remover endereço", zap.Error(err), zap.Int("id", id))
+			return errors.WrapError(err, "falha ao remover endereço")
+		}
+		if existing.IsDefault {
+			return promoteDefault(tx, contactID, existing.Type)
+		}
+		return nil
+	})
+}
+
+// ResolveAddress devolve o endereço informado no documento, que precisa ser do contato, ou, sem
+// ID, o endereço padrão do tipo (nil se o contato não tiver)
+func ResolveAddress(tx *gorm.DB, contactID int, id *int, addressType string) (*models.ContactAddress, error) {
+	if id != nil {
+		address, err := findAddress(tx, contactID, *id)
+		if err == errors.ErrAddressNotFound {
+			return nil, fmt.Errorf("%w: o endereço %d não é do contato %d", errors.ErrInvalidAddress, *id, contactID)
+		}
+		return address, err
+	}
+
+	var addresses []models.ContactAddress
+	if err := tx.Where("contact_id = ? AND type = ? AND is_default", contactID, addressType).
+		Limit(1).Find(&addresses).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar endereço padrão")
+	}
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	return &addresses[0], nil
+}
+
+func findAddress(tx *gorm.DB, contactID, id int) (*models.ContactAddress, error) {
+	var address models.ContactAddress
+	err := tx.Where("id = ? AND contact_id = ?", id, contactID).First(&address).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.ErrAddressNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar endereço")
+	}
+	return &address, nil
+}
+
+func ensureContact(tx *gorm.DB, contactID int) error {
+	var count int64
+	if err := tx.Model(&models.Contact{}).Where("id = ? AND deleted_at IS NULL", contactID).Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar contato")
+	}
+	if count == 0 {
+		return errors.ErrContactNotFound
+	}
+	return nil
+}
+
+func clearDefault(tx *gorm.DB, contactID int, addressType string, exceptID int) error {
+	if err := tx.Model(&models.ContactAddress{}).
+		Where("contact_id = ? AND type = ? AND is_default AND id <> ?", contactID, addressType, exceptID).
+		Update("is_default", false).Error; err != nil {
+		return errors.WrapError(err, "falha ao atualizar endereço padrão")
+	}
+	return nil
+}
+
+func promoteDefault(tx *gorm.DB, contactID int, addressType string) error {
+	var next []models.ContactAddress
+	if err := tx.Where("contact_id = ? AND type = ?", contactID, addressType).
+		Order("id ASC").Limit(1).Find(&next).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar endereço padrão")
+	}
+	if len(next) == 0 {
+		return nil
+	}
+	if err := tx.Model(&next[0]).Update("is_default", true).Error; err != nil {
+		return errors.WrapError(err, "falha ao atualizar endereço padrão")
+	}
+	return nil
+}
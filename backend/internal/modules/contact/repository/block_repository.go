@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlockRepository bloqueia e desbloqueia contatos, registrando cada ação na trilha de auditoria
+type BlockRepository interface {
+	BlockContact(ctx context.Context, contactID int, input models.BlockInput, username string) (*models.BlockEvent, error)
+	UnblockContact(ctx context.Context, contactID int, notes, username string) (*models.BlockEvent, error)
+	ListBlockEvents(ctx context.Context, contactID int) ([]models.BlockEvent, error)
+}
+
+type blockRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewBlockRepository cria uma nova instância do repositório
+func NewBlockRepository() (BlockRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &blockRepository{
+		db:     db,
+		logger: logger.WithModule("contact_block_repository"),
+	}, nil
+}
+
+// BlockContact grava o bloqueio no contato (substituindo um anterior) e o evento na trilha
+func (r *blockRepository) BlockContact(ctx context.Context, contactID int, input models.BlockInput, username string) (*models.BlockEvent, error) {
+	event := &models.BlockEvent{
+		ContactID:     contactID,
+		Action:        models.BlockActionBlock,
+		Reason:        input.Reason,
+		Notes:         input.Notes,
+		EffectiveDate: input.EffectiveDate,
+		Username:      username,
+	}
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockContact(tx, contactID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Contact{}).Where("id = ?", contactID).
+			UpdateColumns(map[string]interface{}{
+				"block_reason": input.Reason,
+				"block_notes":  input.Notes,
+				"blocked_from": input.EffectiveDate,
+				"blocked_by":   username,
+			}).Error; err != nil {
+			return errors.WrapError(err, "falha ao bloquear contato")
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar bloqueio")
+		}
+		return nil
+	})
+	if err != nil {
+		if err != errors.ErrContactNotFound {
+			r.logger.Error("erro ao bloquear contato", zap.Error(err), zap.Int("contact_id", contactID))
+		}
+		return nil, err
+	}
+	InvalidateContacts(contactID)
+	return event, nil
+}
+
+// UnblockContact remove o bloqueio do contato e registra o desbloqueio na trilha
+func (r *blockRepository) UnblockContact(ctx context.Context, contactID int, notes, username string) (*models.BlockEvent, error) {
+	event := &models.BlockEvent{
+		ContactID: contactID,
+		Action:    models.BlockActionUnblock,
+		Notes:     notes,
+		Username:  username,
+	}
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		contact, err := lockContact(tx, contactID)
+		if err != nil {
+			return err
+		}
+		if contact.BlockedFrom == nil {
+			return errors.ErrContactNotBlocked
+		}
+		event.Reason = contact.BlockReason
+		if err := tx.Model(&models.Contact{}).Where("id = ?", contactID).
+			UpdateColumns(map[string]interface{}{
+				"block_reason": "",
+				"block_notes":  "",
+				"blocked_from": nil,
+				"blocked_by":   "",
+			}).Error; err != nil {
+			return errors.WrapError(err, "falha ao desbloquear contato")
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar desbloqueio")
+		}
+		return nil
+	})
+	if err != nil {
+		if err != errors.ErrContactNotFound && err != errors.ErrContactNotBlocked {
+			r.logger.Error("erro ao desbloquear contato", zap.Error(err), zap.Int("contact_id", contactID))
+		}
+		return nil, err
+	}
+	InvalidateContacts(contactID)
+	return event, nil
+}
+
+// ListBlockEvents lista a trilha de bloqueios do contato, a mais recente primeiro
+func (r *blockRepository) ListBlockEvents(ctx context.Context, contactID int) ([]models.BlockEvent, error) {
+	if err := ensureContact(db.Conn(ctx, r.db), contactID); err != nil {
+		return nil, err
+	}
+	var events []models.BlockEvent
+	if err := db.Conn(ctx, r.db).Where("contact_id = ?", contactID).
+		Order("created_at DESC, id DESC").Find(&events).Error; err != nil {
+		r.logger.Error("erro ao listar bloqueios", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao listar bloqueios")
+	}
+	return events, nil
+}
+
+// EnsureNotBlocked recusa a emissão de documento para contato com bloqueio já vigente. Usado
+// pelas cotações, pedidos, entregas e faturas, dentro da transação que cria o documento.
+func EnsureNotBlocked(tx *gorm.DB, contactID int) error {
+	var contacts []models.Contact
+	if err := tx.Model(&models.Contact{}).Select("id, block_reason, blocked_from").
+		Where("id = ? AND blocked_from IS NOT NULL AND blocked_from <= CURRENT_DATE", contactID).
+		Limit(1).Find(&contacts).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar bloqueio do contato")
+	}
+	if len(contacts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: contato %d bloqueado desde %s (%s)", errors.ErrContactBlocked,
+		contactID, contacts[0].BlockedFrom.Format("2006-01-02"), contacts[0].BlockReason)
+}
+
+func lockContact(tx *gorm.DB, contactID int) (*models.Contact, error) {
+	var contacts []models.Contact
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, block_reason, blocked_from").
+		Where("id = ? AND deleted_at IS NULL", contactID).Limit(1).Find(&contacts).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar contato")
+	}
+	if len(contacts) == 0 {
+		return nil, errors.ErrContactNotFound
+	}
+	return &contacts[0], nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/cache"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+)
+
+// contactCache guarda os contatos lidos por ID; UpdateContactByID e DeleteContactByID
+// invalidam a entrada
+var contactCache = cache.NewReference[int, models.Contact]("contacts")
+
+// GetContactByID busca o contato da empresa pelo ID, primeiro no cache de dados de referência.
+// O cache é compartilhado entre as empresas: a entrada só é usada se for da empresa do contexto.
+func GetContactByID(ctx context.Context, id int) (*models.Contact, error) {
+	if contact, ok := contactCache.Get(id); ok && visibleTo(ctx, contact) {
+		return &contact, nil
+	}
+
+	contact, err := getContactByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	contactCache.Set(id, *contact)
+	return contact, nil
+}
+
+// visibleTo indica se o contato em cache pertence à empresa do contexto
+func visibleTo(ctx context.Context, contact models.Contact) bool {
+	if tenant.IsAllCompanies(ctx) {
+		return true
+	}
+	companyID, ok := tenant.CompanyID(ctx)
+	return ok && companyID == contact.CompanyID
+}
+
+// InvalidateContacts descarta os contatos do cache após alterações feitas fora deste pacote
+func InvalidateContacts(ids ...int) {
+	contactCache.Delete(ids...)
+}
@@ -2,50 +2,76 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/contact/models"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"database/sql"
 	"fmt"
 )
 
-// Insere um novo contato no banco
-func InsertContact(contact models.Contact) error {
+// Insere um novo contato no banco, na empresa do contexto
+func InsertContact(ctx context.Context, contact models.Contact) error {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return errors.ErrTenantRequired
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	_, err = conn.Exec(`
+	_, err = conn.ExecContext(ctx, `
 		INSERT INTO contacts (
 			person_type, type, name, company_name, trade_name, document, secondary_doc, suframa, isento, ccm,
-			email, phone, zip_code, street, number, complement, neighborhood, city, state
+			email, phone, zip_code, street, number, complement, neighborhood, city, state, cnae, custom_fields,
+			preferred_language, company_id
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-			$11, $12, $13, $14, $15, $16, $17, $18, $19
+			$11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
 		)`,
 		contact.PersonType, contact.Type, contact.Name, contact.CompanyName, contact.TradeName,
 		contact.Document, contact.SecondaryDoc, contact.Suframa, contact.Isento, contact.CCM,
 		contact.Email, contact.Phone, contact.ZipCode, contact.Street, contact.Number,
-		contact.Complement, contact.Neighborhood, contact.City, contact.State,
+		contact.Complement, contact.Neighborhood, contact.City, contact.State, contact.CNAE, contact.CustomFields,
+		contact.PreferredLanguage, companyID,
 	)
 	return err
 }
 
-// Retorna todos os contatos
-func GetAllContacts() ([]models.Contact, error) {
+// Retorna todos os contatos da empresa do contexto
+func GetAllContacts(ctx context.Context) ([]models.Contact, error) {
+	return FindContacts(ctx, nil)
+}
+
+// Retorna os contatos da empresa que têm todos os campos personalizados informados (sem filtro, todos)
+func FindContacts(ctx context.Context, customFields customfields.Values) ([]models.Contact, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 
-	rows, err := conn.Query(`
+	where, args := "deleted_at IS NULL AND "+company, []interface{}{}
+	if len(customFields) > 0 {
+		where, args = where+" AND custom_fields @> $1", append(args, customFields)
+	}
+	rows, err := conn.QueryContext(ctx, `
 		SELECT 
 			id, person_type, type, name, company_name, trade_name, document, secondary_doc, suframa, isento, ccm,
-			email, phone, zip_code, street, number, complement, neighborhood, city, state,
-			created_at, updated_at
+			email, phone, zip_code, street, number, complement, neighborhood, city, state, cnae, rfm_segment,
+			registry_status, registry_checked_at, credit_limit, credit_policy,
+			block_reason, block_notes, blocked_from, blocked_by, custom_fields, preferred_language, company_id, created_at, updated_at
 		FROM contacts
-	`)
+		WHERE `+where, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -58,8 +84,9 @@ func GetAllContacts() ([]models.Contact, error) {
 			&c.ID, &c.PersonType, &c.Type, &c.Name, &c.CompanyName, &c.TradeName,
 			&c.Document, &c.SecondaryDoc, &c.Suframa, &c.Isento, &c.CCM,
 			&c.Email, &c.Phone, &c.ZipCode, &c.Street, &c.Number,
-			&c.Complement, &c.Neighborhood, &c.City, &c.State,
-			&c.CreatedAt, &c.UpdatedAt,
+			&c.Complement, &c.Neighborhood, &c.City, &c.State, &c.CNAE, &c.RFMSegment,
+			&c.RegistryStatus, &c.RegistryCheckedAt, &c.CreditLimit, &c.CreditPolicy,
+			&c.BlockReason, &c.BlockNotes, &c.BlockedFrom, &c.BlockedBy, &c.CustomFields, &c.PreferredLanguage, &c.CompanyID, &c.CreatedAt, &c.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -69,8 +96,13 @@ func GetAllContacts() ([]models.Contact, error) {
 	return contacts, nil
 }
 
-// Busca um contato pelo ID
-func GetContactByID(id int) (*models.Contact, error) {
+// Busca um contato da empresa pelo ID no banco (ver GetContactByID, que usa o cache)
+func getContactByID(ctx context.Context, id int) (*models.Contact, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
@@ -78,19 +110,20 @@ func GetContactByID(id int) (*models.Contact, error) {
 	defer conn.Close()
 
 	var contact models.Contact
-	err = conn.QueryRow(`
+	err = conn.QueryRowContext(ctx, `
         SELECT 
             id, person_type, type, name, company_name, trade_name, document, secondary_doc, suframa, isento, ccm,
-            email, phone, zip_code, street, number, complement, neighborhood, city, state,
-            created_at, updated_at
+            email, phone, zip_code, street, number, complement, neighborhood, city, state, cnae, rfm_segment,
+            registry_status, registry_checked_at, credit_limit, credit_policy,
+            block_reason, block_notes, blocked_from, blocked_by, custom_fields, preferred_language, company_id, created_at, updated_at
         FROM contacts
-        WHERE id = $1
-    `, id).Scan(
+        WHERE id = $1 AND deleted_at IS NULL AND `+company, id).Scan(
 		&contact.ID, &contact.PersonType, &contact.Type, &contact.Name, &contact.CompanyName, &contact.TradeName,
 		&contact.Document, &contact.SecondaryDoc, &contact.Suframa, &contact.Isento, &contact.CCM,
 		&contact.Email, &contact.Phone, &contact.ZipCode, &contact.Street, &contact.Number,
-		&contact.Complement, &contact.Neighborhood, &contact.City, &contact.State,
-		&contact.CreatedAt, &contact.UpdatedAt,
+		&contact.Complement, &contact.Neighborhood, &contact.City, &contact.State, &contact.CNAE, &contact.RFMSegment,
+		&contact.RegistryStatus, &contact.RegistryCheckedAt, &contact.CreditLimit, &contact.CreditPolicy,
+		&contact.BlockReason, &contact.BlockNotes, &contact.BlockedFrom, &contact.BlockedBy, &contact.CustomFields, &contact.PreferredLanguage, &contact.CompanyID, &contact.CreatedAt, &contact.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -102,15 +135,22 @@ func GetContactByID(id int) (*models.Contact, error) {
 	return &contact, nil
 }
 
-// Deleta um contato pelo ID
-func DeleteContactByID(id int) error {
+// Move o contato da empresa para a lixeira (soft delete); a remoção definitiva é feita pela lixeira
+func DeleteContactByID(ctx context.Context, id int) error {
+	defer contactCache.Delete(id)
+
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	result, err := conn.Exec("DELETE FROM contacts WHERE id = $1", id)
+	result, err := conn.ExecContext(ctx, "UPDATE contacts SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL AND "+company, id)
 	if err != nil {
 		return err
 	}
@@ -127,15 +167,28 @@ func DeleteContactByID(id int) error {
 	return nil
 }
 
-// Atualiza os dados de um contato pelo ID
-func UpdateContactByID(id int, contact models.Contact) error {
+// Atualiza os dados de um contato da empresa pelo ID; sem custom_fields no corpo, os campos
+// personalizados gravados são mantidos
+func UpdateContactByID(ctx context.Context, id int, contact models.Contact) error {
+	defer contactCache.Delete(id)
+
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	var customFields interface{}
+	if contact.CustomFields != nil {
+		customFields = contact.CustomFields
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	_, err = conn.Exec(`
+	_, err = conn.ExecContext(ctx, `
 		UPDATE contacts SET 
 			person_type = $1,
 			type = $2,
@@ -156,14 +209,16 @@ func UpdateContactByID(id int, contact models.Contact) error {
 			neighborhood = $17,
 			city = $18,
 			state = $19,
+			cnae = $20,
+			custom_fields = COALESCE($21::jsonb, custom_fields),
+			preferred_language = $22,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $20
-	`,
+		WHERE id = $23 AND deleted_at IS NULL AND `+company,
 		contact.PersonType, contact.Type, contact.Name, contact.CompanyName, contact.TradeName,
 		contact.Document, contact.SecondaryDoc, contact.Suframa, contact.Isento, contact.CCM,
 		contact.Email, contact.Phone, contact.ZipCode, contact.Street, contact.Number,
-		contact.Complement, contact.Neighborhood, contact.City, contact.State,
-		id,
+		contact.Complement, contact.Neighborhood, contact.City, contact.State, contact.CNAE,
+		customFields, contact.PreferredLanguage, id,
 	)
 	return err
 }
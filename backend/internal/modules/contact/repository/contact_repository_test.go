@@ -2,6 +2,8 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"os"
 	"testing"
 
@@ -26,12 +28,12 @@ func TestInsertAndGetContacts(t *testing.T) {
 		Type:  "fornecedor",
 	}
 
-	err := InsertContact(contact)
+	err := InsertContact(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), contact)
 	if err != nil {
 		t.Fatalf("Erro ao inserir contato: %v", err)
 	}
 
-	contacts, err := GetAllContacts()
+	contacts, err := GetAllContacts(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao buscar contatos: %v", err)
 	}
@@ -48,13 +50,13 @@ func TestUpdateContactByID(t *testing.T) {
 		Phone: "000000000",
 		Type:  "cliente",
 	}
-	err := InsertContact(contact)
+	err := InsertContact(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), contact)
 	if err != nil {
 		t.Fatalf("Erro ao inserir contato para atualização: %v", err)
 	}
 
 	// Pega o último inserido
-	contacts, _ := GetAllContacts()
+	contacts, _ := GetAllContacts(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	id := contacts[len(contacts)-1].ID
 
 	// Dados atualizados
@@ -65,13 +67,13 @@ func TestUpdateContactByID(t *testing.T) {
 		Type:  "fornecedor",
 	}
 
-	err = UpdateContactByID(id, updated)
+	err = UpdateContactByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), id, updated)
 	if err != nil {
 		t.Fatalf("Erro ao atualizar contato: %v", err)
 	}
 
 	// Confirma atualização
-	contacts, _ = GetAllContacts()
+	contacts, _ = GetAllContacts(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	found := contacts[len(contacts)-1]
 	if found.Name != updated.Name || found.Email != updated.Email || found.Phone != updated.Phone || found.Type != updated.Type {
 		t.Errorf("Contato não foi atualizado corretamente")
@@ -85,15 +87,15 @@ func TestDeleteContactByID(t *testing.T) {
 		Phone: "999999999",
 		Type:  "cliente",
 	}
-	err := InsertContact(contact)
+	err := InsertContact(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), contact)
 	if err != nil {
 		t.Fatalf("Erro ao inserir contato para deleção: %v", err)
 	}
 
-	contacts, _ := GetAllContacts()
+	contacts, _ := GetAllContacts(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	id := contacts[len(contacts)-1].ID
 
-	err = DeleteContactByID(id)
+	err = DeleteContactByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), id)
 	if err != nil {
 		t.Fatalf("Erro ao deletar contato: %v", err)
 	}
@@ -102,7 +104,7 @@ func TestDeleteContactByID(t *testing.T) {
 func TestDeleteContactByID_NotFound(t *testing.T) {
 	// Testa a tentativa de deletar um ID inexistente
 	invalidID := 999999
-	err := DeleteContactByID(invalidID)
+	err := DeleteContactByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), invalidID)
 	if err == nil {
 		t.Errorf("Esperado erro ao deletar contato inexistente (ID %d), mas não houve", invalidID)
 	}
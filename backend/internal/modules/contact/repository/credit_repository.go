@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CreditRepository consulta a exposição de crédito e grava o limite dos contatos
+type CreditRepository interface {
+	GetCreditStatus(ctx context.Context, contactID int) (*models.CreditStatus, error)
+	UpdateCreditLimit(ctx context.Context, contactID int, input models.CreditInput) error
+}
+
+type creditRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCreditRepository cria uma nova instância do repositório
+func NewCreditRepository() (CreditRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &creditRepository{
+		db:     db,
+		logger: logger.WithModule("contact_credit_repository"),
+	}, nil
+}
+
+// GetCreditStatus calcula o limite, a exposição e o crédito disponível do contato
+func (r *creditRepository) GetCreditStatus(ctx context.Context, contactID int) (*models.CreditStatus, error) {
+	status, err := LoadCreditStatus(db.Conn(ctx, r.db), contactID)
+	if err != nil && err != errors.ErrContactNotFound {
+		r.logger.Error("erro ao calcular exposição de crédito", zap.Error(err), zap.Int("contact_id", contactID))
+	}
+	return status, err
+}
+
+// UpdateCreditLimit grava o limite e a política de crédito do contato
+func (r *creditRepository) UpdateCreditLimit(ctx context.Context, contactID int, input models.CreditInput) error {
+	result := db.Conn(ctx, r.db).Model(&models.Contact{}).
+		Where("id = ? AND deleted_at IS NULL", contactID).
+		UpdateColumns(map[string]interface{}{
+			"credit_limit":  input.CreditLimit,
+			"credit_policy": input.CreditPolicy,
+		})
+	if result.Error != nil {
+		r.logger.Error("erro ao gravar limite de crédito", zap.Error(result.Error), zap.Int("contact_id", contactID))
+		return errors.WrapError(result.Error, "falha ao gravar limite de crédito")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrContactNotFound
+	}
+	InvalidateContacts(contactID)
+	return nil
+}
+
+// LoadCreditStatus calcula a exposição de crédito do contato: o saldo das faturas emitidas e não
+// pagas mais o valor ainda não enviado dos pedidos confirmados, em andamento ou retidos por
+// crédito. Usado também na conversão da cotação, dentro da transação.
+func LoadCreditStatus(tx *gorm.DB, contactID int) (*models.CreditStatus, error) {
+	var contacts []struct {
+		CreditLimit  *float64
+		CreditPolicy string
+	}
+	if err := tx.Model(&models.Contact{}).Select("credit_limit, credit_policy").
+		Where("id = ? AND deleted_at IS NULL", contactID).Find(&contacts).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar limite de crédito")
+	}
+	if len(contacts) == 0 {
+		return nil, errors.ErrContactNotFound
+	}
+
+	ctx := tx.Statement.Context
+	var openInvoices float64
+	if err := tx.Table("invoices").Scopes(tenant.Scope(ctx, "invoices")).
+		Select("COALESCE(SUM(grand_total - amount_paid), 0)").
+		Where("contact_id = ? AND deleted_at IS NULL AND status NOT IN ?", contactID, []string{"draft", "cancelled", "paid"}).
+		Scan(&openInvoices).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao somar faturas em aberto")
+	}
+
+	var openOrders float64
+	if err := tx.Table("sales_order_items si").
+		Joins("JOIN sales_orders so ON so.id = si.sales_order_id").
+		Joins(`LEFT JOIN (
+			SELECT di.so_item_id, SUM(di.quantity) AS shipped
+			FROM delivery_items di
+			JOIN deliveries d ON d.id = di.delivery_id
+			WHERE d.status <> 'returned' AND d.deleted_at IS NULL
+			GROUP BY di.so_item_id
+		) shipped ON shipped.so_item_id = si.id`).
+		Scopes(tenant.Scope(ctx, "so")).
+		Select(`COALESCE(SUM(si.total * GREATEST(si.quantity * GREATEST(si.unit_factor, 1) - COALESCE(shipped.shipped, 0), 0)
+			/ NULLIF(si.quantity * GREATEST(si.unit_factor, 1), 0)), 0)`).
+		Where("so.contact_id = ? AND so.deleted_at IS NULL AND so.status IN ?", contactID, []string{"confirmed", "processing", "credit_hold"}).
+		Scan(&openOrders).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao somar pedidos em aberto")
+	}
+
+	return models.NewCreditStatus(contactID, contacts[0].CreditLimit, contacts[0].CreditPolicy, openInvoices, openOrders), nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/registry"
+	"ERP-ONSMART/backend/internal/logger"
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RegistryRepository guarda o cache local das consultas de CNPJ e CEP e a situação cadastral dos
+// contatos. O cache é compartilhado entre as empresas: os dados são públicos.
+type RegistryRepository interface {
+	GetCachedCNPJ(ctx context.Context, cnpj string, notBefore time.Time) (*registry.Company, error)
+	SaveCNPJ(ctx context.Context, company *registry.Company) error
+	GetCachedCEP(ctx context.Context, cep string, notBefore time.Time) (*registry.Address, error)
+	SaveCEP(ctx context.Context, address *registry.Address) error
+	ListCNPJsToRevalidate(ctx context.Context, checkedBefore time.Time, limit int) ([]string, error)
+	UpdateRegistryStatus(ctx context.Context, cnpj, status string, checkedAt time.Time) (int64, error)
+}
+
+type registryRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewRegistryRepository cria uma nova instância do repositório
+func NewRegistryRepository() (RegistryRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &registryRepository{
+		db:     db,
+		logger: logger.WithModule("contact_registry_repository"),
+	}, nil
+}
+
+type registryLookup struct {
+	Data []byte
+}
+
+// GetCachedCNPJ retorna a consulta do CNPJ feita a partir de notBefore, ou nil
+func (r *registryRepository) GetCachedCNPJ(ctx context.Context, cnpj string, notBefore time.Time) (*registry.Company, error) {
+	var lookups []registryLookup
+	if err := db.Conn(ctx, r.db).Table("cnpj_lookups").Select("data").
+		Where("cnpj = ? AND fetched_at >= ?", cnpj, notBefore).Find(&lookups).Error; err != nil {
+		r.logger.Error("erro ao buscar CNPJ no cache", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar CNPJ no cache")
+	}
+	if len(lookups) == 0 {
+		return nil, nil
+	}
+	var company registry.Company
+	if err := json.Unmarshal(lookups[0].Data, &company); err != nil {
+		return nil, nil
+	}
+	return &company, nil
+}
+
+// SaveCNPJ grava (ou renova) a consulta do CNPJ no cache
+func (r *registryRepository) SaveCNPJ(ctx context.Context, company *registry.Company) error {
+	data, err := json.Marshal(company)
+	if err != nil {
+		return errors.WrapError(err, "falha ao serializar CNPJ")
+	}
+	row := map[string]interface{}{
+		"cnpj":       company.CNPJ,
+		"data":       string(data),
+		"active":     company.Active,
+		"source":     company.Source,
+		"fetched_at": time.Now(),
+	}
+	if err := db.Conn(ctx, r.db).Table("cnpj_lookups").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cnpj"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "active", "source", "fetched_at"}),
+	}).Create(row).Error; err != nil {
+		r.logger.Error("erro ao gravar CNPJ no cache", zap.Error(err))
+		return errors.WrapError(err, "falha ao gravar CNPJ no cache")
+	}
+	return nil
+}
+
+// GetCachedCEP retorna a consulta do CEP feita a partir de notBefore, ou nil
+func (r *registryRepository) GetCachedCEP(ctx context.Context, cep string, notBefore time.Time) (*registry.Address, error) {
+	var lookups []registryLookup
+	if err := db.Conn(ctx, r.db).Table("cep_lookups").Select("data").
+		Where("cep = ? AND fetched_at >= ?", cep, notBefore).Find(&lookups).Error; err != nil {
+		r.logger.Error("erro ao buscar CEP no cache", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar CEP no cache")
+	}
+	if len(lookups) == 0 {
+		return nil, nil
+	}
+	var address registry.Address
+	if err := json.Unmarshal(lookups[0].Data, &address); err != nil {
+		return nil, nil
+	}
+	return &address, nil
+}
+
+// SaveCEP grava (ou renova) a consulta do CEP no cache
+func (r *registryRepository) SaveCEP(ctx context.Context, address *registry.Address) error {
+	data, err := json.Marshal(address)
+	if err != nil {
+		return errors.WrapError(err, "falha ao serializar CEP")
+	}
+	row := map[string]interface{}{
+		"cep":        address.ZipCode,
+		"data":       string(data),
+		"fetched_at": time.Now(),
+	}
+	if err := db.Conn(ctx, r.db).Table("cep_lookups").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cep"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "fetched_at"}),
+	}).Create(row).Error; err != nil {
+		r.logger.Error("erro ao gravar CEP no cache", zap.Error(err))
+		return errors.WrapError(err, "falha ao gravar CEP no cache")
+	}
+	return nil
+}
+
+// ListCNPJsToRevalidate lista os CNPJs (só dígitos) dos contatos PJ de todas as empresas nunca
+// verificados ou verificados antes de checkedBefore, dos mais antigos para os mais recentes
+func (r *registryRepository) ListCNPJsToRevalidate(ctx context.Context, checkedBefore time.Time, limit int) ([]string, error) {
+	var cnpjs []string
+	err := db.Conn(ctx, r.db).Raw(`
+		SELECT cnpj FROM (
+			SELECT regexp_replace(document, '\D', '', 'g') AS cnpj, MIN(COALESCE(registry_checked_at, 'epoch')) AS checked_at
+			FROM contacts
+			WHERE person_type = 'pj' AND deleted_at IS NULL
+				AND (registry_checked_at IS NULL OR registry_checked_at < ?)
+			GROUP BY 1
+		) pending
+		WHERE length(cnpj) = 14
+		ORDER BY checked_at ASC, cnpj ASC
+		LIMIT ?`, checkedBefore, limit).Scan(&cnpjs).Error
+	if err != nil {
+		r.logger.Error("erro ao listar CNPJs para revalidação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar CNPJs para revalidação")
+	}
+	return cnpjs, nil
+}
+
+// UpdateRegistryStatus grava a situação cadastral nos contatos PJ do CNPJ, de todas as empresas
+func (r *registryRepository) UpdateRegistryStatus(ctx context.Context, cnpj, status string, checkedAt time.Time) (int64, error) {
+	var ids []int
+	err := db.Conn(ctx, r.db).Raw(`
+		UPDATE contacts SET registry_status = ?, registry_checked_at = ?
+		WHERE person_type = 'pj' AND deleted_at IS NULL AND regexp_replace(document, '\D', '', 'g') = ?
+		RETURNING id`, status, checkedAt, cnpj).Scan(&ids).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar situação cadastral", zap.Error(err), zap.String("cnpj", cnpj))
+		return 0, errors.WrapError(err, "falha ao gravar situação cadastral")
+	}
+	InvalidateContacts(ids...)
+	return int64(len(ids)), nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// contactReferences são as colunas que apontam para o contato. As entregas seguem o pedido de
+// venda ou de compra e não guardam o contato.
+var contactReferences = []struct {
+	Table  string
+	Column string
+}{
+	{"quotations", "contact_id"},
+	{"sales_orders", "contact_id"},
+	{"purchase_orders", "contact_id"},
+	{"invoices", "contact_id"},
+	{"sales_processes", "contact_id"},
+	{"credit_notes", "contact_id"},
+	{"supplier_bills", "contact_id"},
+	{"return_requests", "contact_id"},
+	{"service_orders", "contact_id"},
+	{"contracts", "contact_id"},
+	{"crm_leads", "contact_id"},
+	{"crm_opportunities", "contact_id"},
+	{"portal_accesses", "contact_id"},
+	{"support_tickets", "contact_id"},
+	{"products", "preferred_supplier_id"},
+	{"contact_addresses", "contact_id"},
+	{"contact_block_events", "contact_id"},
+}
+
+// MergeRepository busca os contatos para a deduplicação e mescla os duplicados
+type MergeRepository interface {
+	ListActiveContacts(ctx context.Context) ([]models.Contact, error)
+	MergeContacts(ctx context.Context, survivorID int, duplicateIDs []int) (*models.MergeResult, error)
+}
+
+type mergeRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewMergeRepository cria uma nova instância do repositório
+func NewMergeRepository() (MergeRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &mergeRepository{
+		db:     db,
+		logger: logger.WithModule("contact_merge_repository"),
+	}, nil
+}
+
+// ListActiveContacts lista os contatos da empresa fora da lixeira
+func (r *mergeRepository) ListActiveContacts(ctx context.Context) ([]models.Contact, error) {
+	var contacts []models.Contact
+	if err := db.Conn(ctx, r.db).Where("deleted_at IS NULL").Order("id ASC").Find(&contacts).Error; err != nil {
+		r.logger.Error("erro ao listar contatos para deduplicação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar contatos")
+	}
+	return contacts, nil
+}
+
+// MergeContacts passa para o contato mantido, em uma transação, os documentos, processos,
+// contratos, leads, acessos ao portal, anexos e comentários dos duplicados e os move para a
+// lixeira
+func (r *mergeRepository) MergeContacts(ctx context.Context, survivorID int, duplicateIDs []int) (*models.MergeResult, error) {
+	result := &models.MergeResult{SurvivorID: survivorID, MergedIDs: duplicateIDs, Updated: map[string]int64{}}
+
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		ids := append([]int{survivorID}, duplicateIDs...)
+		var count int64
+		if err := tx.Model(&models.Contact{}).Where("id IN ? AND deleted_at IS NULL", ids).Count(&count).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar contatos")
+		}
+		if count != int64(len(ids)) {
+			return errors.ErrContactNotFound
+		}
+
+		// O bloqueio de um duplicado não some na mesclagem: o contato mantido precisa estar bloqueado
+		var blocked []int
+		if err := tx.Model(&models.Contact{}).Where("id IN ? AND blocked_from IS NOT NULL", ids).
+			Pluck("id", &blocked).Error; err != nil {
+			return errors.WrapError(err, "falha ao verificar bloqueios dos contatos")
+		}
+		survivorBlocked := false
+		for _, id := range blocked {
+			survivorBlocked = survivorBlocked || id == survivorID
+		}
+		if len(blocked) > 0 && !survivorBlocked {
+			return fmt.Errorf("%w: o contato %d está bloqueado; bloqueie o contato mantido ou desbloqueie o duplicado", errors.ErrInvalidContactMerge, blocked[0])
+		}
+
+		// O contato mantido conserva os endereços padrão; os dos duplicados entram como adicionais
+		if err := tx.Model(&models.ContactAddress{}).
+			Where("contact_id IN ? AND is_default AND type IN (?)", duplicateIDs,
+				tx.Model(&models.ContactAddress{}).Select("type").Where("contact_id = ? AND is_default", survivorID)).
+			Update("is_default", false).Error; err != nil {
+			return errors.WrapError(err, "falha ao ajustar endereços padrão dos duplicados")
+		}
+		if err := tx.Model(&models.ContactAddress{}).
+			Where("contact_id IN ? AND is_default AND id NOT IN (?)", duplicateIDs,
+				tx.Model(&models.ContactAddress{}).Select("MIN(id)").Where("contact_id IN ? AND is_default", duplicateIDs).Group("type")).
+			Update("is_default", false).Error; err != nil {
+			return errors.WrapError(err, "falha ao ajustar endereços padrão dos duplicados")
+		}
+
+		for _, ref := range contactReferences {
+			updated := tx.Table(ref.Table).Scopes(tenant.Scope(ctx, ref.Table)).
+				Where(ref.Column+" IN ?", duplicateIDs).
+				Update(ref.Column, survivorID)
+			if updated.Error != nil {
+				r.logger.Error("erro ao transferir registros do contato", zap.Error(updated.Error), zap.String("table", ref.Table))
+				return errors.WrapError(updated.Error, "falha ao transferir registros de "+ref.Table)
+			}
+			if updated.RowsAffected > 0 {
+				result.Updated[ref.Table] += updated.RowsAffected
+			}
+		}
+
+		for _, table := range []string{"attachments", "comments"} {
+			updated := tx.Table(table).Scopes(tenant.Scope(ctx, table)).
+				Where("entity_type = ? AND entity_id IN ?", "contact", duplicateIDs).
+				Update("entity_id", survivorID)
+			if updated.Error != nil {
+				r.logger.Error("erro ao transferir registros do contato", zap.Error(updated.Error), zap.String("table", table))
+				return errors.WrapError(updated.Error, "falha ao transferir registros de "+table)
+			}
+			if updated.RowsAffected > 0 {
+				result.Updated[table] += updated.RowsAffected
+			}
+		}
+
+		// A pontuação RFM dos duplicados é refeita no próximo recálculo, já somada ao contato mantido
+		if err := tx.Exec("DELETE FROM contact_rfm_scores WHERE contact_id IN ?", duplicateIDs).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover pontuação RFM dos duplicados")
+		}
+
+		if err := tx.Model(&models.Contact{}).Where("id IN ?", duplicateIDs).
+			UpdateColumn("deleted_at", time.Now()).Error; err != nil {
+			r.logger.Error("erro ao mover duplicados para a lixeira", zap.Error(err))
+			return errors.WrapError(err, "falha ao mover duplicados para a lixeira")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	InvalidateContacts(append([]int{survivorID}, duplicateIDs...)...)
+	return result, nil
+}
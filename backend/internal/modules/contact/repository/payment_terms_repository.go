@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentTermsRepository grava a condição de pagamento estruturada dos contatos
+type PaymentTermsRepository interface {
+	GetPaymentTerms(ctx context.Context, contactID int) (*models.PaymentTerms, error)
+	SavePaymentTerms(ctx context.Context, terms *models.PaymentTerms) error
+	DeletePaymentTerms(ctx context.Context, contactID int) error
+}
+
+type paymentTermsRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewPaymentTermsRepository cria uma nova instância do repositório
+func NewPaymentTermsRepository() (PaymentTermsRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &paymentTermsRepository{
+		db:     db,
+		logger: logger.WithModule("contact_payment_terms_repository"),
+	}, nil
+}
+
+// GetPaymentTerms busca a condição de pagamento do contato
+func (r *paymentTermsRepository) GetPaymentTerms(ctx context.Context, contactID int) (*models.PaymentTerms, error) {
+	terms, err := LoadPaymentTerms(db.Conn(ctx, r.db), contactID)
+	if err != nil {
+		r.logger.Error("erro ao buscar condição de pagamento", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, err
+	}
+	if terms == nil {
+		return nil, errors.ErrPaymentTermsNotFound
+	}
+	return terms, nil
+}
+
+// SavePaymentTerms grava a condição do contato, substituindo a que já existir
+func (r *paymentTermsRepository) SavePaymentTerms(ctx context.Context, terms *models.PaymentTerms) error {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockContact(tx, terms.ContactID); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "contact_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"net_days", "business_days", "installment_days", "discount_percent", "discount_days", "updated_by", "updated_at",
+			}),
+		}).Create(terms).Error
+	})
+	if err != nil {
+		if err != errors.ErrContactNotFound {
+			r.logger.Error("erro ao gravar condição de pagamento", zap.Error(err), zap.Int("contact_id", terms.ContactID))
+			return errors.WrapError(err, "falha ao gravar condição de pagamento")
+		}
+		return err
+	}
+
+	r.logger.Info("condição de pagamento gravada", zap.Int("contact_id", terms.ContactID),
+		zap.String("label", terms.Label), zap.String("updated_by", terms.UpdatedBy))
+	return nil
+}
+
+// DeletePaymentTerms remove a condição; o faturamento volta a usar o texto do pedido
+func (r *paymentTermsRepository) DeletePaymentTerms(ctx context.Context, contactID int) error {
+	result := db.Conn(ctx, r.db).Where("contact_id = ?", contactID).Delete(&models.PaymentTerms{})
+	if result.Error != nil {
+		r.logger.Error("erro ao remover condição de pagamento", zap.Error(result.Error), zap.Int("contact_id", contactID))
+		return errors.WrapError(result.Error, "falha ao remover condição de pagamento")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrPaymentTermsNotFound
+	}
+	r.logger.Info("condição de pagamento removida", zap.Int("contact_id", contactID))
+	return nil
+}
+
+// LoadPaymentTerms busca a condição de pagamento do contato, ou nil sem condição cadastrada.
+// Usado também no faturamento do pedido de venda, dentro da transação.
+func LoadPaymentTerms(tx *gorm.DB, contactID int) (*models.PaymentTerms, error) {
+	var terms []models.PaymentTerms
+	if err := tx.Where("contact_id = ?", contactID).Limit(1).Find(&terms).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar condição de pagamento")
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	terms[0].Label = terms[0].Describe()
+	return &terms[0], nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// StatementRepository define as operações de extrato e saldo de contatos
+type StatementRepository interface {
+	GetContactStatement(ctx context.Context, contactID int, from, to time.Time) (*models.ContactStatement, error)
+	GetContactBalance(ctx context.Context, contactID int) (*models.ContactBalance, error)
+}
+
+type statementRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewStatementRepository cria uma nova instância do repositório
+func NewStatementRepository() (StatementRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &statementRepository{
+		db:     db,
+		logger: logger.WithModule("statement_repository"),
+	}, nil
+}
+
+// statementEntriesCTE reúne faturas (débito), pagamentos e notas de crédito (crédito) do contato.
+// As faturas com adiantamento abatido entram pelo valor líquido, já que o adiantamento foi
+// cobrado na fatura de adiantamento do pedido.
+// Os parâmetros posicionais são o ID do contato, repetido uma vez para cada bloco do UNION, e os
+// filtros de empresa (tenant.Condition) entram pelo Sprintf: faturas em %[1]s e notas em %[2]s.
+const statementEntriesCTE = `
+WITH entries AS (
+	SELECT i.issue_date AS entry_date, 1 AS sort_order, 'invoice' AS document_type,
+	       i.id AS document_id, i.invoice_no AS document_no,
+	       CASE
+	           WHEN i.kind = 'advance' THEN 'Fatura de adiantamento ' || i.invoice_no
+	           WHEN i.advance_deduction > 0 THEN 'Fatura ' || i.invoice_no || ' (adiantamento abatido: ' || i.advance_deduction || ')'
+	           ELSE 'Fatura ' || i.invoice_no
+	       END AS description,
+	       i.grand_total AS debit, 0::DECIMAL(12,2) AS credit
+	FROM invoices i
+	WHERE i.contact_id = ? AND i.status NOT IN ('draft', 'cancelled') AND %[1]s
+	UNION ALL
+	SELECT p.payment_date, 2, 'payment',
+	       p.id, COALESCE(NULLIF(p.reference, ''), i.invoice_no),
+	       'Pagamento da fatura ' || i.invoice_no,
+	       0, p.amount
+	FROM payments p
+	JOIN invoices i ON i.id = p.invoice_id
+	WHERE i.contact_id = ? AND %[1]s
+	UNION ALL
+	SELECT c.issue_date, 3, 'credit_note',
+	       c.id, c.credit_note_no,
+	       'Nota de crédito ' || c.credit_note_no,
+	       0, c.amount
+	FROM credit_notes c
+	WHERE c.contact_id = ? AND c.status <> 'cancelled' AND %[2]s
+)`
+
+// statementEntries monta a CTE dos lançamentos com os filtros da empresa do contexto
+func statementEntries(ctx context.Context) (string, error) {
+	invoices, err := tenant.Condition(ctx, "i")
+	if err != nil {
+		return "", err
+	}
+	notes, err := tenant.Condition(ctx, "c")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(statementEntriesCTE, invoices, notes), nil
+}
+
+// GetContactStatement monta o extrato do contato no período, com saldo acumulado calculado no banco
+func (r *statementRepository) GetContactStatement(ctx context.Context, contactID int, from, to time.Time) (*models.ContactStatement, error) {
+	if err := r.ensureContactExists(ctx, contactID); err != nil {
+		return nil, err
+	}
+
+	statement := &models.ContactStatement{
+		ContactID: contactID,
+		From:      from,
+		To:        to,
+		Entries:   []models.StatementEntry{},
+	}
+
+	entries, err := statementEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Saldo anterior ao período
+	openingQuery := entries + `
+SELECT COALESCE(SUM(debit - credit), 0) FROM entries WHERE entry_date < ?`
+	if err := db.Conn(ctx, r.db).Raw(openingQuery, contactID, contactID, contactID, from).Scan(&statement.OpeningBalance).Error; err != nil {
+		r.logger.Error("erro ao calcular saldo anterior", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao calcular saldo anterior")
+	}
+
+	// Lançamentos do período; a janela percorre todo o histórico para que o saldo já inclua o saldo anterior
+	entriesQuery := entries + `
+SELECT entry_date, document_type, document_id, document_no, description, debit, credit, balance
+FROM (
+	SELECT e.*, SUM(e.debit - e.credit) OVER (
+		ORDER BY e.entry_date, e.sort_order, e.document_id
+		ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW
+	) AS balance
+	FROM entries e
+) s
+WHERE entry_date >= ? AND entry_date < ?
+ORDER BY entry_date, sort_order, document_id`
+	if err := db.Conn(ctx, r.db).Raw(entriesQuery, contactID, contactID, contactID, from, to).Scan(&statement.Entries).Error; err != nil {
+		r.logger.Error("erro ao buscar lançamentos do extrato", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao buscar lançamentos do extrato")
+	}
+
+	statement.ClosingBalance = statement.OpeningBalance
+	for _, entry := range statement.Entries {
+		statement.TotalDebits = statement.TotalDebits.Add(entry.Debit)
+		statement.TotalCredits = statement.TotalCredits.Add(entry.Credit)
+	}
+	if len(statement.Entries) > 0 {
+		statement.ClosingBalance = statement.Entries[len(statement.Entries)-1].Balance
+	}
+
+	return statement, nil
+}
+
+// GetContactBalance retorna os valores em aberto a receber e a pagar do contato
+func (r *statementRepository) GetContactBalance(ctx context.Context, contactID int) (*models.ContactBalance, error) {
+	if err := r.ensureContactExists(ctx, contactID); err != nil {
+		return nil, err
+	}
+
+	balance := &models.ContactBalance{ContactID: contactID}
+
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	invoiceCompany, err := tenant.Condition(ctx, "i")
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+SELECT
+	(SELECT COALESCE(SUM(grand_total - amount_paid), 0) FROM invoices
+	 WHERE contact_id = @contact AND status IN ('sent', 'partial', 'overdue') AND ` + company + `) AS open_receivables,
+	(SELECT COALESCE(SUM(GREATEST(grand_total - amount_paid - ` + sales.DisputedAmountSQL("invoices") + `, 0)), 0) FROM invoices
+	 WHERE contact_id = @contact AND status IN ('sent', 'partial', 'overdue') AND due_date < @now AND ` + company + `) AS overdue_receivables,
+	(SELECT COUNT(*) FROM invoices
+	 WHERE contact_id = @contact AND status IN ('sent', 'partial', 'overdue') AND ` + company + `) AS open_invoices,
+	(SELECT COALESCE(SUM(d.amount), 0) FROM invoice_disputes d JOIN invoices i ON i.id = d.invoice_id
+	 WHERE i.contact_id = @contact AND d.status = 'open' AND ` + invoiceCompany + `) AS disputed_receivables,
+	(SELECT COALESCE(SUM(amount), 0) FROM credit_notes
+	 WHERE contact_id = @contact AND status = 'issued' AND ` + company + `) AS unapplied_credits,
+	(SELECT COALESCE(SUM(grand_total - amount_paid), 0) FROM supplier_bills
+	 WHERE contact_id = @contact AND status IN ('open', 'partial') AND ` + company + `) AS open_payables,
+	(SELECT COALESCE(SUM(grand_total - amount_paid), 0) FROM supplier_bills
+	 WHERE contact_id = @contact AND status IN ('open', 'partial') AND due_date < @now AND ` + company + `) AS overdue_payables,
+	(SELECT COUNT(*) FROM supplier_bills
+	 WHERE contact_id = @contact AND status IN ('open', 'partial') AND ` + company + `) AS open_bills`
+
+	params := map[string]interface{}{"contact": contactID, "now": time.Now()}
+	if err := db.Conn(ctx, r.db).Raw(query, params).Scan(balance).Error; err != nil {
+		r.logger.Error("erro ao calcular saldo do contato", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao calcular saldo do contato")
+	}
+
+	balance.ContactID = contactID
+	balance.NetBalance = balance.OpenReceivables.Sub(balance.UnappliedCredits).Sub(balance.OpenPayables)
+
+	return balance, nil
+}
+
+// ensureContactExists verifica se o contato existe na empresa antes de montar as consultas
+func (r *statementRepository) ensureContactExists(ctx context.Context, contactID int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := db.Conn(ctx, r.db).Raw("SELECT EXISTS(SELECT 1 FROM contacts WHERE id = ? AND "+company+")", contactID).Scan(&exists).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar contato")
+	}
+	if !exists {
+		return errors.ErrContactNotFound
+	}
+	return nil
+}
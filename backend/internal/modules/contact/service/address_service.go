@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"go.uber.org/zap"
+)
+
+// AddressService mantém o catálogo de endereços dos contatos
+type AddressService struct {
+	newRepo func() (repository.AddressRepository, error)
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.AddressRepository
+}
+
+// NewAddressService cria o serviço sobre o repositório informado
+func NewAddressService(newRepo func() (repository.AddressRepository, error)) *AddressService {
+	return &AddressService{
+		newRepo: newRepo,
+		logger:  logger.WithModule("contact_address_service"),
+	}
+}
+
+var defaultAddressService = NewAddressService(repository.NewAddressRepository)
+
+// ListAddresses lista os endereços do contato
+func ListAddresses(ctx context.Context, contactID int) ([]models.ContactAddress, error) {
+	return defaultAddressService.ListAddresses(ctx, contactID)
+}
+
+// CreateAddress cadastra um endereço no contato
+func CreateAddress(ctx context.Context, contactID int, input models.AddressInput) (*models.ContactAddress, error) {
+	return defaultAddressService.CreateAddress(ctx, contactID, input)
+}
+
+// UpdateAddress altera um endereço do contato
+func UpdateAddress(ctx context.Context, contactID, id int, input models.AddressInput) (*models.ContactAddress, error) {
+	return defaultAddressService.UpdateAddress(ctx, contactID, id, input)
+}
+
+// DeleteAddress remove um endereço do contato
+func DeleteAddress(ctx context.Context, contactID, id int) error {
+	return defaultAddressService.DeleteAddress(ctx, contactID, id)
+}
+
+func (s *AddressService) repository() (repository.AddressRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListAddresses lista os endereços do contato, os padrões primeiro
+func (s *AddressService) ListAddresses(ctx context.Context, contactID int) ([]models.ContactAddress, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListAddresses(ctx, contactID)
+}
+
+// CreateAddress valida e cadastra o endereço
+func (s *AddressService) CreateAddress(ctx context.Context, contactID int, input models.AddressInput) (*models.ContactAddress, error) {
+	address, err := input.ToAddress(contactID)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CreateAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// UpdateAddress valida e altera o endereço
+func (s *AddressService) UpdateAddress(ctx context.Context, contactID, id int, input models.AddressInput) (*models.ContactAddress, error) {
+	address, err := input.ToAddress(contactID)
+	if err != nil {
+		return nil, err
+	}
+	address.ID = id
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// DeleteAddress remove o endereço
+func (s *AddressService) DeleteAddress(ctx context.Context, contactID, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteAddress(ctx, contactID, id)
+}
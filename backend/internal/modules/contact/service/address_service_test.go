@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAddressRepo struct {
+	created []models.ContactAddress
+	updated []models.ContactAddress
+}
+
+func (r *fakeAddressRepo) ListAddresses(ctx context.Context, contactID int) ([]models.ContactAddress, error) {
+	return r.created, nil
+}
+
+func (r *fakeAddressRepo) GetAddress(ctx context.Context, contactID, id int) (*models.ContactAddress, error) {
+	return nil, appErrors.ErrAddressNotFound
+}
+
+func (r *fakeAddressRepo) CreateAddress(ctx context.Context, address *models.ContactAddress) error {
+	address.ID = len(r.created) + 1
+	r.created = append(r.created, *address)
+	return nil
+}
+
+func (r *fakeAddressRepo) UpdateAddress(ctx context.Context, address *models.ContactAddress) error {
+	r.updated = append(r.updated, *address)
+	return nil
+}
+
+func (r *fakeAddressRepo) DeleteAddress(ctx context.Context, contactID, id int) error {
+	return nil
+}
+
+func newTestAddressService(repo *fakeAddressRepo) *AddressService {
+	return NewAddressService(func() (repository.AddressRepository, error) { return repo, nil })
+}
+
+func TestCreateAddressValidatesBeforeSaving(t *testing.T) {
+	repo := &fakeAddressRepo{}
+	s := newTestAddressService(repo)
+
+	_, err := s.CreateAddress(context.Background(), 3, models.AddressInput{
+		Type: models.AddressTypeBilling, ZipCode: "1234", Street: "Rua A", City: "Campinas", State: "SP",
+	})
+	assert.Equal(t, appErrors.ErrInvalidCEP, err)
+	assert.Empty(t, repo.created)
+
+	address, err := s.CreateAddress(context.Background(), 3, models.AddressInput{
+		Type: models.AddressTypeBilling, ZipCode: "13010-000", Street: "Rua A", City: "Campinas", State: "SP",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, address.ID)
+	assert.Equal(t, "13010000", repo.created[0].ZipCode)
+}
+
+func TestUpdateAddressKeepsIDs(t *testing.T) {
+	repo := &fakeAddressRepo{}
+	s := newTestAddressService(repo)
+
+	_, err := s.UpdateAddress(context.Background(), 3, 9, models.AddressInput{
+		Type: models.AddressTypeSite, Label: "Obra Centro", ZipCode: "13010000", Street: "Rua B", City: "Campinas", State: "SP",
+	})
+	require.NoError(t, err)
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, 9, repo.updated[0].ID)
+	assert.Equal(t, 3, repo.updated[0].ContactID)
+}
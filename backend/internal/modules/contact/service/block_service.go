@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"go.uber.org/zap"
+)
+
+// BlockService bloqueia e desbloqueia contatos e consulta a trilha de auditoria
+type BlockService struct {
+	newRepo func() (repository.BlockRepository, error)
+	logger  *zap.Logger
+	now     func() time.Time
+
+	mu   sync.Mutex
+	repo repository.BlockRepository
+}
+
+// NewBlockService cria o serviço sobre o repositório informado
+func NewBlockService(newRepo func() (repository.BlockRepository, error)) *BlockService {
+	return &BlockService{
+		newRepo: newRepo,
+		logger:  logger.WithModule("contact_block_service"),
+		now:     time.Now,
+	}
+}
+
+var defaultBlockService = NewBlockService(repository.NewBlockRepository)
+
+// BlockContact bloqueia o contato para novos documentos a partir da data informada
+func BlockContact(ctx context.Context, contactID int, input models.BlockInput, username string) (*models.BlockEvent, error) {
+	return defaultBlockService.BlockContact(ctx, contactID, input, username)
+}
+
+// UnblockContact remove o bloqueio do contato
+func UnblockContact(ctx context.Context, contactID int, input models.UnblockInput, username string) (*models.BlockEvent, error) {
+	return defaultBlockService.UnblockContact(ctx, contactID, input, username)
+}
+
+// ListBlockEvents lista a trilha de bloqueios do contato
+func ListBlockEvents(ctx context.Context, contactID int) ([]models.BlockEvent, error) {
+	return defaultBlockService.ListBlockEvents(ctx, contactID)
+}
+
+func (s *BlockService) repository() (repository.BlockRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// BlockContact valida o motivo e grava o bloqueio com o usuário que o aplicou
+func (s *BlockService) BlockContact(ctx context.Context, contactID int, input models.BlockInput, username string) (*models.BlockEvent, error) {
+	if err := input.Validate(s.now()); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	event, err := repo.BlockContact(ctx, contactID, input, username)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Warn("contato bloqueado", zap.Int("contact_id", contactID), zap.String("reason", input.Reason),
+		zap.Time("effective_date", *input.EffectiveDate), zap.String("username", username))
+	return event, nil
+}
+
+// UnblockContact remove o bloqueio e registra quem desbloqueou
+func (s *BlockService) UnblockContact(ctx context.Context, contactID int, input models.UnblockInput, username string) (*models.BlockEvent, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	event, err := repo.UnblockContact(ctx, contactID, strings.TrimSpace(input.Notes), username)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Warn("contato desbloqueado", zap.Int("contact_id", contactID), zap.String("reason", event.Reason),
+		zap.String("username", username))
+	return event, nil
+}
+
+// ListBlockEvents lista a trilha de bloqueios do contato
+func (s *BlockService) ListBlockEvents(ctx context.Context, contactID int) ([]models.BlockEvent, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListBlockEvents(ctx, contactID)
+}
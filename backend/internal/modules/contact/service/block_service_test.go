@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBlockRepo struct {
+	events []models.BlockEvent
+}
+
+func (r *fakeBlockRepo) BlockContact(ctx context.Context, contactID int, input models.BlockInput, username string) (*models.BlockEvent, error) {
+	event := models.BlockEvent{ContactID: contactID, Action: models.BlockActionBlock, Reason: input.Reason,
+		Notes: input.Notes, EffectiveDate: input.EffectiveDate, Username: username}
+	r.events = append(r.events, event)
+	return &event, nil
+}
+
+func (r *fakeBlockRepo) UnblockContact(ctx context.Context, contactID int, notes, username string) (*models.BlockEvent, error) {
+	if len(r.events) == 0 || r.events[len(r.events)-1].Action != models.BlockActionBlock {
+		return nil, appErrors.ErrContactNotBlocked
+	}
+	event := models.BlockEvent{ContactID: contactID, Action: models.BlockActionUnblock,
+		Reason: r.events[len(r.events)-1].Reason, Notes: notes, Username: username}
+	r.events = append(r.events, event)
+	return &event, nil
+}
+
+func (r *fakeBlockRepo) ListBlockEvents(ctx context.Context, contactID int) ([]models.BlockEvent, error) {
+	return r.events, nil
+}
+
+func newTestBlockService(repo *fakeBlockRepo) *BlockService {
+	s := NewBlockService(func() (repository.BlockRepository, error) { return repo, nil })
+	s.now = func() time.Time { return time.Date(2024, 5, 10, 14, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestBlockContactRecordsUserAndEffectiveDate(t *testing.T) {
+	repo := &fakeBlockRepo{}
+	s := newTestBlockService(repo)
+
+	event, err := s.BlockContact(context.Background(), 4, models.BlockInput{Reason: "litigation", Notes: "processo 123"}, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, "ana", event.Username)
+	assert.Equal(t, time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), *event.EffectiveDate)
+
+	_, err = s.BlockContact(context.Background(), 4, models.BlockInput{Reason: "late"}, "ana")
+	assert.True(t, errors.Is(err, appErrors.ErrInvalidContactBlock))
+	assert.Len(t, repo.events, 1)
+}
+
+func TestUnblockContactKeepsReasonInTrail(t *testing.T) {
+	repo := &fakeBlockRepo{}
+	s := newTestBlockService(repo)
+
+	_, err := s.UnblockContact(context.Background(), 4, models.UnblockInput{}, "admin")
+	assert.Equal(t, appErrors.ErrContactNotBlocked, err)
+
+	_, err = s.BlockContact(context.Background(), 4, models.BlockInput{Reason: models.BlockReasonFraud}, "ana")
+	require.NoError(t, err)
+	event, err := s.UnblockContact(context.Background(), 4, models.UnblockInput{Notes: " acordo firmado "}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, models.BlockReasonFraud, event.Reason)
+	assert.Equal(t, "acordo firmado", event.Notes)
+
+	events, err := s.ListBlockEvents(context.Background(), 4)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+}
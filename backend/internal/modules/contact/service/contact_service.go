@@ -3,24 +3,31 @@ package service
 import (
 	"ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/contact/repository"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	"context"
 )
 
-func CreateContact(contact models.Contact) error {
-	return repository.InsertContact(contact)
+func CreateContact(ctx context.Context, contact models.Contact) error {
+	return repository.InsertContact(ctx, contact)
 }
 
-func ListContacts() ([]models.Contact, error) {
-	return repository.GetAllContacts()
+func ListContacts(ctx context.Context) ([]models.Contact, error) {
+	return repository.GetAllContacts(ctx)
 }
 
-func RemoveContact(id int) error {
-	return repository.DeleteContactByID(id)
+// SearchContacts lista os contatos com os campos personalizados informados
+func SearchContacts(ctx context.Context, customFields customfields.Values) ([]models.Contact, error) {
+	return repository.FindContacts(ctx, customFields)
 }
 
-func UpdateContact(id int, contact models.Contact) error {
-	return repository.UpdateContactByID(id, contact)
+func RemoveContact(ctx context.Context, id int) error {
+	return repository.DeleteContactByID(ctx, id)
 }
 
-func GetContact(id int) (*models.Contact, error) {
-	return repository.GetContactByID(id)
+func UpdateContact(ctx context.Context, id int, contact models.Contact) error {
+	return repository.UpdateContactByID(ctx, id, contact)
+}
+
+func GetContact(ctx context.Context, id int) (*models.Contact, error) {
+	return repository.GetContactByID(ctx, id)
 }
@@ -2,6 +2,8 @@ package service
 
 import (
 	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"os"
 	"testing"
 
@@ -28,12 +30,12 @@ func TestCreateAndListContacts(t *testing.T) {
 		Type:  "cliente",
 	}
 
-	err := CreateContact(c)
+	err := CreateContact(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), c)
 	if err != nil {
 		t.Fatalf("Erro ao criar contato: %v", err)
 	}
 
-	list, err := ListContacts()
+	list, err := ListContacts(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao listar contatos: %v", err)
 	}
@@ -50,13 +52,13 @@ func TestUpdateContact(t *testing.T) {
 		Phone: "000000000",
 		Type:  "cliente",
 	}
-	err := CreateContact(c)
+	err := CreateContact(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), c)
 	if err != nil {
 		t.Fatalf("Erro ao criar contato: %v", err)
 	}
 
 	// Pega o último contato inserido
-	list, _ := ListContacts()
+	list, _ := ListContacts(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	id := list[len(list)-1].ID
 
 	// Dados atualizados
@@ -67,13 +69,13 @@ func TestUpdateContact(t *testing.T) {
 		Type:  "fornecedor",
 	}
 
-	err = UpdateContact(id, updated)
+	err = UpdateContact(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), id, updated)
 	if err != nil {
 		t.Fatalf("Erro ao atualizar contato: %v", err)
 	}
 
 	// Confirma alteração
-	list, _ = ListContacts()
+	list, _ = ListContacts(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	changed := list[len(list)-1]
 	if changed.Name != updated.Name || changed.Email != updated.Email || changed.Phone != updated.Phone || changed.Type != updated.Type {
 		t.Errorf("Contato não foi atualizado corretamente")
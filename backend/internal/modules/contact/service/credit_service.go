@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"go.uber.org/zap"
+)
+
+// CreditService consulta a exposição de crédito e mantém o limite dos contatos
+type CreditService struct {
+	newRepo func() (repository.CreditRepository, error)
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.CreditRepository
+}
+
+// NewCreditService cria o serviço sobre o repositório informado
+func NewCreditService(newRepo func() (repository.CreditRepository, error)) *CreditService {
+	return &CreditService{
+		newRepo: newRepo,
+		logger:  logger.WithModule("contact_credit_service"),
+	}
+}
+
+var defaultCreditService = NewCreditService(repository.NewCreditRepository)
+
+// GetCreditStatus retorna o limite, a exposição e o crédito disponível do contato
+func GetCreditStatus(ctx context.Context, contactID int) (*models.CreditStatus, error) {
+	return defaultCreditService.GetCreditStatus(ctx, contactID)
+}
+
+// UpdateCreditLimit define o limite e a política de crédito do contato
+func UpdateCreditLimit(ctx context.Context, contactID int, input models.CreditInput) (*models.CreditStatus, error) {
+	return defaultCreditService.UpdateCreditLimit(ctx, contactID, input)
+}
+
+func (s *CreditService) repository() (repository.CreditRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// GetCreditStatus retorna o limite, a exposição e o crédito disponível do contato
+func (s *CreditService) GetCreditStatus(ctx context.Context, contactID int) (*models.CreditStatus, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetCreditStatus(ctx, contactID)
+}
+
+// UpdateCreditLimit valida e grava o limite e devolve a exposição recalculada
+func (s *CreditService) UpdateCreditLimit(ctx context.Context, contactID int, input models.CreditInput) (*models.CreditStatus, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateCreditLimit(ctx, contactID, input); err != nil {
+		return nil, err
+	}
+	s.logger.Info("limite de crédito alterado", zap.Int("contact_id", contactID), zap.String("policy", input.CreditPolicy))
+	return repo.GetCreditStatus(ctx, contactID)
+}
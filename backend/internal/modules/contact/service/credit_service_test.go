@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCreditRepo struct {
+	saved    []models.CreditInput
+	exposure float64
+}
+
+func (r *fakeCreditRepo) GetCreditStatus(ctx context.Context, contactID int) (*models.CreditStatus, error) {
+	var limit *float64
+	policy := models.CreditPolicyApproval
+	if n := len(r.saved); n > 0 {
+		limit, policy = r.saved[n-1].CreditLimit, r.saved[n-1].CreditPolicy
+	}
+	return models.NewCreditStatus(contactID, limit, policy, r.exposure, 0), nil
+}
+
+func (r *fakeCreditRepo) UpdateCreditLimit(ctx context.Context, contactID int, input models.CreditInput) error {
+	r.saved = append(r.saved, input)
+	return nil
+}
+
+func newTestCreditService(repo *fakeCreditRepo) *CreditService {
+	return NewCreditService(func() (repository.CreditRepository, error) { return repo, nil })
+}
+
+func TestUpdateCreditLimitReturnsRecalculatedStatus(t *testing.T) {
+	repo := &fakeCreditRepo{exposure: 400}
+	s := newTestCreditService(repo)
+
+	limit := 1000.0
+	status, err := s.UpdateCreditLimit(context.Background(), 5, models.CreditInput{CreditLimit: &limit})
+	require.NoError(t, err)
+	require.Len(t, repo.saved, 1)
+	assert.Equal(t, models.CreditPolicyApproval, repo.saved[0].CreditPolicy)
+	require.NotNil(t, status.Available)
+	assert.Equal(t, 600.0, *status.Available)
+}
+
+func TestUpdateCreditLimitRejectsInvalidInput(t *testing.T) {
+	repo := &fakeCreditRepo{}
+	s := newTestCreditService(repo)
+
+	_, err := s.UpdateCreditLimit(context.Background(), 5, models.CreditInput{CreditPolicy: "soft"})
+	assert.True(t, errors.Is(err, appErrors.ErrInvalidCreditLimit))
+	assert.Empty(t, repo.saved)
+}
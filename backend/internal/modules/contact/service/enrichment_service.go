@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/registry"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/validation"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// EnrichmentService preenche os contatos a partir do CNPJ e do CEP e revalida a situação
+// cadastral dos CNPJs
+type EnrichmentService struct {
+	newRepo func() (repository.RegistryRepository, error)
+	cnpj    registry.CNPJProvider
+	cep     registry.CEPProvider
+	now     func() time.Time
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.RegistryRepository
+}
+
+// NewEnrichmentService cria o serviço sobre o repositório e os provedores informados
+func NewEnrichmentService(newRepo func() (repository.RegistryRepository, error), cnpj registry.CNPJProvider, cep registry.CEPProvider) *EnrichmentService {
+	return &EnrichmentService{
+		newRepo: newRepo,
+		cnpj:    cnpj,
+		cep:     cep,
+		now:     time.Now,
+		logger:  logger.WithModule("contact_enrichment_service"),
+	}
+}
+
+var (
+	defaultEnrichmentOnce    sync.Once
+	defaultEnrichmentService *EnrichmentService
+)
+
+// enrichmentService monta o serviço padrão com os provedores configurados na primeira chamada,
+// depois do carregamento da configuração
+func enrichmentService() *EnrichmentService {
+	defaultEnrichmentOnce.Do(func() {
+		cnpj, cep := registry.FromConfig()
+		defaultEnrichmentService = NewEnrichmentService(repository.NewRegistryRepository, cnpj, cep)
+	})
+	return defaultEnrichmentService
+}
+
+// Enrich consulta o CNPJ e/ou o CEP e monta o contato sugerido
+func Enrich(ctx context.Context, input models.EnrichInput) (*models.Enrichment, error) {
+	return enrichmentService().Enrich(ctx, input)
+}
+
+// StartCNPJRevalidationScheduler revalida periodicamente os CNPJs dos contatos; cada CNPJ é
+// consultado de novo uma vez por intervalo, em lotes de CNPJ_REVALIDATION_BATCH
+func StartCNPJRevalidationScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("cnpj_revalidation_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				checks, err := enrichmentService().RevalidateCNPJs(ctx, interval, viper.GetInt("CNPJ_REVALIDATION_BATCH"))
+				metrics.ObserveJob("cnpj_revalidation_scheduler", err)
+				if err != nil {
+					log.Error("erro ao revalidar CNPJs", zap.Error(err))
+				}
+				for _, check := range checks {
+					if !check.Active {
+						log.Warn("CNPJ inativo na Receita Federal", zap.String("cnpj", check.CNPJ),
+							zap.String("status", check.Status), zap.Int64("contacts", check.Contacts))
+					}
+				}
+			}
+		}
+	}()
+}
+
+func (s *EnrichmentService) repository() (repository.RegistryRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// cacheTTL é a validade do cache local das consultas (REGISTRY_CACHE_TTL)
+func cacheTTL() time.Duration {
+	if ttl := viper.GetDuration("REGISTRY_CACHE_TTL"); ttl > 0 {
+		return ttl
+	}
+	return 30 * 24 * time.Hour
+}
+
+// Enrich valida o CNPJ e o CEP informados e os consulta, usando o cache local enquanto válido
+func (s *EnrichmentService) Enrich(ctx context.Context, input models.EnrichInput) (*models.Enrichment, error) {
+	document := validation.OnlyDigits(input.Document)
+	zipCode := validation.OnlyDigits(input.ZipCode)
+	if document == "" && zipCode == "" {
+		return nil, errors.InvalidParam("informe o CNPJ ou o CEP")
+	}
+	if document != "" && !validation.IsValidCNPJ(document) {
+		return nil, errors.ErrInvalidCNPJ
+	}
+	if zipCode != "" && len(zipCode) != 8 {
+		return nil, errors.ErrInvalidCEP
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	notBefore := s.now().Add(-cacheTTL())
+
+	var company *registry.Company
+	if document != "" {
+		if company, err = s.lookupCNPJ(ctx, repo, document, notBefore); err != nil {
+			return nil, err
+		}
+	}
+	var address *registry.Address
+	if zipCode != "" {
+		if address, err = s.lookupCEP(ctx, repo, zipCode, notBefore); err != nil {
+			return nil, err
+		}
+	}
+	return models.BuildEnrichment(company, address), nil
+}
+
+func (s *EnrichmentService) lookupCNPJ(ctx context.Context, repo repository.RegistryRepository, cnpj string, notBefore time.Time) (*registry.Company, error) {
+	if cached, err := repo.GetCachedCNPJ(ctx, cnpj, notBefore); err != nil || cached != nil {
+		return cached, err
+	}
+	company, err := s.cnpj.LookupCNPJ(ctx, cnpj)
+	if err != nil {
+		s.logger.Warn("falha na consulta do CNPJ", zap.String("cnpj", cnpj), zap.Error(err))
+		return nil, err
+	}
+	company.CNPJ = cnpj
+	if err := repo.SaveCNPJ(ctx, company); err != nil {
+		s.logger.Warn("falha ao gravar CNPJ no cache", zap.Error(err))
+	}
+	return company, nil
+}
+
+func (s *EnrichmentService) lookupCEP(ctx context.Context, repo repository.RegistryRepository, cep string, notBefore time.Time) (*registry.Address, error) {
+	if cached, err := repo.GetCachedCEP(ctx, cep, notBefore); err != nil || cached != nil {
+		return cached, err
+	}
+	address, err := s.cep.LookupCEP(ctx, cep)
+	if err != nil {
+		if !stderrors.Is(err, errors.ErrCEPNotFound) {
+			s.logger.Warn("falha na consulta do CEP", zap.String("cep", cep), zap.Error(err))
+			return nil, fmt.Errorf("%w: %v", errors.ErrRegistryUnavailable, err)
+		}
+		return nil, err
+	}
+	address.ZipCode = cep
+	if err := repo.SaveCEP(ctx, address); err != nil {
+		s.logger.Warn("falha ao gravar CEP no cache", zap.Error(err))
+	}
+	return address, nil
+}
+
+// RevalidateCNPJs consulta de novo, sem o cache, até limit CNPJs de contatos de todas as empresas
+// não verificados há maxAge e grava a situação cadastral nos contatos. CNPJ inexistente é gravado
+// como NÃO ENCONTRADO; a indisponibilidade dos provedores encerra o lote, retomado na próxima
+// execução.
+func (s *EnrichmentService) RevalidateCNPJs(ctx context.Context, maxAge time.Duration, limit int) ([]models.RegistryCheck, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	ctx = tenant.AllCompanies(ctx)
+	now := s.now()
+
+	cnpjs, err := repo.ListCNPJsToRevalidate(ctx, now.Add(-maxAge), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]models.RegistryCheck, 0, len(cnpjs))
+	for _, cnpj := range cnpjs {
+		check := models.RegistryCheck{CNPJ: cnpj}
+		company, err := s.cnpj.LookupCNPJ(ctx, cnpj)
+		switch {
+		case err == nil:
+			company.CNPJ = cnpj
+			check.Status, check.Active = company.Status, company.Active
+			if err := repo.SaveCNPJ(ctx, company); err != nil {
+				s.logger.Warn("falha ao gravar CNPJ no cache", zap.Error(err))
+			}
+		case stderrors.Is(err, errors.ErrCNPJNotFound):
+			check.Status = models.RegistryStatusNotFound
+		default:
+			return checks, err
+		}
+
+		if check.Contacts, err = repo.UpdateRegistryStatus(ctx, cnpj, check.Status, now); err != nil {
+			return checks, err
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
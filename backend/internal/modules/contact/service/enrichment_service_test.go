@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/registry"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRegistryRepo struct {
+	companies map[string]*registry.Company
+	addresses map[string]*registry.Address
+	pending   []string
+	statuses  map[string]string
+}
+
+func newFakeRegistryRepo() *fakeRegistryRepo {
+	return &fakeRegistryRepo{
+		companies: map[string]*registry.Company{},
+		addresses: map[string]*registry.Address{},
+		statuses:  map[string]string{},
+	}
+}
+
+func (r *fakeRegistryRepo) GetCachedCNPJ(ctx context.Context, cnpj string, notBefore time.Time) (*registry.Company, error) {
+	return r.companies[cnpj], nil
+}
+
+func (r *fakeRegistryRepo) SaveCNPJ(ctx context.Context, company *registry.Company) error {
+	r.companies[company.CNPJ] = company
+	return nil
+}
+
+func (r *fakeRegistryRepo) GetCachedCEP(ctx context.Context, cep string, notBefore time.Time) (*registry.Address, error) {
+	return r.addresses[cep], nil
+}
+
+func (r *fakeRegistryRepo) SaveCEP(ctx context.Context, address *registry.Address) error {
+	r.addresses[address.ZipCode] = address
+	return nil
+}
+
+func (r *fakeRegistryRepo) ListCNPJsToRevalidate(ctx context.Context, checkedBefore time.Time, limit int) ([]string, error) {
+	return r.pending, nil
+}
+
+func (r *fakeRegistryRepo) UpdateRegistryStatus(ctx context.Context, cnpj, status string, checkedAt time.Time) (int64, error) {
+	r.statuses[cnpj] = status
+	return 1, nil
+}
+
+type fakeCNPJProvider struct {
+	results map[string]*registry.Company
+	err     error
+	calls   int
+}
+
+func (p *fakeCNPJProvider) Name() string { return "fake" }
+
+func (p *fakeCNPJProvider) LookupCNPJ(ctx context.Context, cnpj string) (*registry.Company, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	if company, ok := p.results[cnpj]; ok {
+		copied := *company
+		return &copied, nil
+	}
+	return nil, appErrors.ErrCNPJNotFound
+}
+
+type fakeCEPProvider struct{}
+
+func (fakeCEPProvider) LookupCEP(ctx context.Context, cep string) (*registry.Address, error) {
+	return &registry.Address{ZipCode: cep, Street: "Praça da Sé", City: "São Paulo", State: "SP"}, nil
+}
+
+func newTestEnrichmentService(repo *fakeRegistryRepo, cnpj *fakeCNPJProvider) *EnrichmentService {
+	return NewEnrichmentService(func() (repository.RegistryRepository, error) { return repo, nil }, cnpj, fakeCEPProvider{})
+}
+
+func TestEnrichValidatesInput(t *testing.T) {
+	s := newTestEnrichmentService(newFakeRegistryRepo(), &fakeCNPJProvider{})
+
+	_, err := s.Enrich(context.Background(), models.EnrichInput{Document: "12.345.678/0001-00"})
+	assert.Equal(t, appErrors.ErrInvalidCNPJ, err)
+
+	_, err = s.Enrich(context.Background(), models.EnrichInput{ZipCode: "0100-100"})
+	assert.Equal(t, appErrors.ErrInvalidCEP, err)
+
+	_, err = s.Enrich(context.Background(), models.EnrichInput{})
+	assert.Error(t, err)
+}
+
+func TestEnrichUsesCache(t *testing.T) {
+	repo := newFakeRegistryRepo()
+	provider := &fakeCNPJProvider{results: map[string]*registry.Company{
+		"19131243000197": {LegalName: "OPEN KNOWLEDGE BRASIL", Status: "ATIVA", Active: true},
+	}}
+	s := newTestEnrichmentService(repo, provider)
+
+	for i := 0; i < 2; i++ {
+		result, err := s.Enrich(context.Background(), models.EnrichInput{Document: "19.131.243/0001-97", ZipCode: "01001-000"})
+		require.NoError(t, err)
+		assert.Equal(t, "19131243000197", result.Contact.Document)
+		assert.Equal(t, "Praça da Sé", result.Contact.Street)
+	}
+	assert.Equal(t, 1, provider.calls)
+	assert.NotNil(t, repo.addresses["01001000"])
+}
+
+func TestRevalidateCNPJs(t *testing.T) {
+	repo := newFakeRegistryRepo()
+	repo.pending = []string{"19131243000197", "11222333000181"}
+	provider := &fakeCNPJProvider{results: map[string]*registry.Company{
+		"19131243000197": {Status: "BAIXADA"},
+	}}
+	s := newTestEnrichmentService(repo, provider)
+
+	checks, err := s.RevalidateCNPJs(context.Background(), 24*time.Hour, 10)
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.False(t, checks[0].Active)
+	assert.Equal(t, "BAIXADA", repo.statuses["19131243000197"])
+	assert.Equal(t, models.RegistryStatusNotFound, repo.statuses["11222333000181"])
+
+	provider.err = appErrors.ErrRegistryUnavailable
+	repo.statuses = map[string]string{}
+	checks, err = s.RevalidateCNPJs(context.Background(), 24*time.Hour, 10)
+	assert.True(t, stderrors.Is(err, appErrors.ErrRegistryUnavailable))
+	assert.Empty(t, checks)
+	assert.Empty(t, repo.statuses)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"go.uber.org/zap"
+)
+
+// MergeService aponta os contatos duplicados e mescla os duplicados no contato mantido
+type MergeService struct {
+	newRepo func() (repository.MergeRepository, error)
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.MergeRepository
+}
+
+// NewMergeService cria o serviço sobre o repositório informado
+func NewMergeService(newRepo func() (repository.MergeRepository, error)) *MergeService {
+	return &MergeService{
+		newRepo: newRepo,
+		logger:  logger.WithModule("contact_merge_service"),
+	}
+}
+
+var defaultMergeService = NewMergeService(repository.NewMergeRepository)
+
+// FindDuplicates lista os pares de contatos prováveis duplicados da empresa
+func FindDuplicates(ctx context.Context, minScore float64) ([]models.DuplicatePair, error) {
+	return defaultMergeService.FindDuplicates(ctx, minScore)
+}
+
+// MergeContacts mescla os duplicados no contato mantido
+func MergeContacts(ctx context.Context, input models.MergeInput) (*models.MergeResult, error) {
+	return defaultMergeService.MergeContacts(ctx, input)
+}
+
+func (s *MergeService) repository() (repository.MergeRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// FindDuplicates compara os contatos fora da lixeira e mantém os pares com nota mínima
+func (s *MergeService) FindDuplicates(ctx context.Context, minScore float64) ([]models.DuplicatePair, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := repo.ListActiveContacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := models.FindDuplicates(contacts)
+	filtered := pairs[:0]
+	for _, pair := range pairs {
+		if pair.Score >= minScore {
+			filtered = append(filtered, pair)
+		}
+	}
+	return filtered, nil
+}
+
+// MergeContacts valida os IDs e mescla os duplicados no contato mantido
+func (s *MergeService) MergeContacts(ctx context.Context, input models.MergeInput) (*models.MergeResult, error) {
+	seen := map[int]bool{input.SurvivorID: true}
+	duplicateIDs := make([]int, 0, len(input.DuplicateIDs))
+	for _, id := range input.DuplicateIDs {
+		if id == input.SurvivorID {
+			return nil, fmt.Errorf("%w: o contato mantido não pode estar entre os duplicados", errors.ErrInvalidContactMerge)
+		}
+		if !seen[id] {
+			seen[id] = true
+			duplicateIDs = append(duplicateIDs, id)
+		}
+	}
+	if len(duplicateIDs) == 0 {
+		return nil, fmt.Errorf("%w: informe os contatos duplicados", errors.ErrInvalidContactMerge)
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	result, err := repo.MergeContacts(ctx, input.SurvivorID, duplicateIDs)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("contatos mesclados", zap.Int("survivor_id", input.SurvivorID), zap.Ints("merged_ids", duplicateIDs))
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMergeRepo struct {
+	contacts   []models.Contact
+	survivorID int
+	mergedIDs  []int
+}
+
+func (r *fakeMergeRepo) ListActiveContacts(ctx context.Context) ([]models.Contact, error) {
+	return r.contacts, nil
+}
+
+func (r *fakeMergeRepo) MergeContacts(ctx context.Context, survivorID int, duplicateIDs []int) (*models.MergeResult, error) {
+	r.survivorID, r.mergedIDs = survivorID, duplicateIDs
+	return &models.MergeResult{SurvivorID: survivorID, MergedIDs: duplicateIDs, Updated: map[string]int64{}}, nil
+}
+
+func newTestMergeService(repo *fakeMergeRepo) *MergeService {
+	return NewMergeService(func() (repository.MergeRepository, error) { return repo, nil })
+}
+
+func TestFindDuplicatesFiltersByScore(t *testing.T) {
+	s := newTestMergeService(&fakeMergeRepo{contacts: []models.Contact{
+		{ID: 1, Name: "Mercado Central", Document: "12345678000190", Email: "a@central.com"},
+		{ID: 2, Name: "Outro", Document: "12345678000190", Email: "b@outro.com"},
+		{ID: 3, Name: "Mercado Centrall", Document: "98765432000110", Email: "c@terceiro.com"},
+	}})
+
+	pairs, err := s.FindDuplicates(context.Background(), 0.9)
+	require.NoError(t, err)
+	require.Len(t, pairs, 1)
+	assert.Equal(t, 2, pairs[0].DuplicateID)
+}
+
+func TestMergeContactsValidatesIDs(t *testing.T) {
+	repo := &fakeMergeRepo{}
+	s := newTestMergeService(repo)
+
+	_, err := s.MergeContacts(context.Background(), models.MergeInput{SurvivorID: 1, DuplicateIDs: []int{2, 1}})
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidContactMerge))
+
+	result, err := s.MergeContacts(context.Background(), models.MergeInput{SurvivorID: 1, DuplicateIDs: []int{3, 2, 3}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.survivorID)
+	assert.Equal(t, []int{3, 2}, repo.mergedIDs)
+	assert.Equal(t, []int{3, 2}, result.MergedIDs)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"go.uber.org/zap"
+)
+
+// PaymentTermsService mantém a condição de pagamento estruturada dos contatos
+type PaymentTermsService struct {
+	newRepo func() (repository.PaymentTermsRepository, error)
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.PaymentTermsRepository
+}
+
+// NewPaymentTermsService cria o serviço sobre o repositório informado
+func NewPaymentTermsService(newRepo func() (repository.PaymentTermsRepository, error)) *PaymentTermsService {
+	return &PaymentTermsService{
+		newRepo: newRepo,
+		logger:  logger.WithModule("contact_payment_terms_service"),
+	}
+}
+
+var defaultPaymentTermsService = NewPaymentTermsService(repository.NewPaymentTermsRepository)
+
+// GetPaymentTerms retorna a condição de pagamento do contato
+func GetPaymentTerms(ctx context.Context, contactID int) (*models.PaymentTerms, error) {
+	return defaultPaymentTermsService.GetPaymentTerms(ctx, contactID)
+}
+
+// SavePaymentTerms define a condição de pagamento do contato
+func SavePaymentTerms(ctx context.Context, contactID int, input models.PaymentTermsInput, username string) (*models.PaymentTerms, error) {
+	return defaultPaymentTermsService.SavePaymentTerms(ctx, contactID, input, username)
+}
+
+// DeletePaymentTerms remove a condição de pagamento do contato
+func DeletePaymentTerms(ctx context.Context, contactID int) error {
+	return defaultPaymentTermsService.DeletePaymentTerms(ctx, contactID)
+}
+
+func (s *PaymentTermsService) repository() (repository.PaymentTermsRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// GetPaymentTerms retorna a condição de pagamento do contato
+func (s *PaymentTermsService) GetPaymentTerms(ctx context.Context, contactID int) (*models.PaymentTerms, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetPaymentTerms(ctx, contactID)
+}
+
+// SavePaymentTerms valida os prazos e o desconto e grava a condição do contato
+func (s *PaymentTermsService) SavePaymentTerms(ctx context.Context, contactID int, input models.PaymentTermsInput, username string) (*models.PaymentTerms, error) {
+	terms, err := input.ToTerms(contactID, username)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.SavePaymentTerms(ctx, terms); err != nil {
+		return nil, err
+	}
+	s.logger.Info("condição de pagamento alterada", zap.Int("contact_id", contactID), zap.String("label", terms.Label))
+	return terms, nil
+}
+
+// DeletePaymentTerms remove a condição; o faturamento volta a usar o texto do pedido
+func (s *PaymentTermsService) DeletePaymentTerms(ctx context.Context, contactID int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeletePaymentTerms(ctx, contactID)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePaymentTermsRepo struct {
+	terms map[int]*models.PaymentTerms
+}
+
+func (r *fakePaymentTermsRepo) GetPaymentTerms(ctx context.Context, contactID int) (*models.PaymentTerms, error) {
+	if terms, ok := r.terms[contactID]; ok {
+		return terms, nil
+	}
+	return nil, appErrors.ErrPaymentTermsNotFound
+}
+
+func (r *fakePaymentTermsRepo) SavePaymentTerms(ctx context.Context, terms *models.PaymentTerms) error {
+	r.terms[terms.ContactID] = terms
+	return nil
+}
+
+func (r *fakePaymentTermsRepo) DeletePaymentTerms(ctx context.Context, contactID int) error {
+	if _, ok := r.terms[contactID]; !ok {
+		return appErrors.ErrPaymentTermsNotFound
+	}
+	delete(r.terms, contactID)
+	return nil
+}
+
+func newTestPaymentTermsService(repo *fakePaymentTermsRepo) *PaymentTermsService {
+	return NewPaymentTermsService(func() (repository.PaymentTermsRepository, error) { return repo, nil })
+}
+
+func TestSavePaymentTermsStoresValidatedTerms(t *testing.T) {
+	repo := &fakePaymentTermsRepo{terms: map[int]*models.PaymentTerms{}}
+	s := newTestPaymentTermsService(repo)
+
+	terms, err := s.SavePaymentTerms(context.Background(), 3, models.PaymentTermsInput{InstallmentDays: []int{30, 60}}, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, "30/60 dias", terms.Label)
+	assert.Same(t, terms, repo.terms[3])
+
+	require.NoError(t, s.DeletePaymentTerms(context.Background(), 3))
+	_, err = s.GetPaymentTerms(context.Background(), 3)
+	assert.True(t, errors.Is(err, appErrors.ErrPaymentTermsNotFound))
+}
+
+func TestSavePaymentTermsRejectsInvalidInput(t *testing.T) {
+	repo := &fakePaymentTermsRepo{terms: map[int]*models.PaymentTerms{}}
+	s := newTestPaymentTermsService(repo)
+
+	_, err := s.SavePaymentTerms(context.Background(), 3, models.PaymentTermsInput{InstallmentDays: []int{60, 30}}, "ana")
+	assert.True(t, errors.Is(err, appErrors.ErrInvalidPaymentTerms))
+	assert.Empty(t, repo.terms)
+}
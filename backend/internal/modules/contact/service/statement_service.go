@@ -0,0 +1,53 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contact/repository"
+	"context"
+	"time"
+)
+
+// GetContactStatement retorna o extrato do contato entre as datas informadas (ambas inclusivas).
+// Se "to" for zero, considera até hoje.
+func GetContactStatement(ctx context.Context, contactID int, from, to time.Time) (*models.ContactStatement, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from = truncateToDay(from)
+	to = truncateToDay(to)
+
+	if to.Before(from) {
+		return nil, errors.ErrInvalidDateRange
+	}
+
+	repo, err := repository.NewStatementRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	// O limite superior é exclusivo na consulta, então avança um dia
+	statement, err := repo.GetContactStatement(ctx, contactID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	statement.To = to
+
+	return statement, nil
+}
+
+// GetContactBalance retorna os valores em aberto a receber e a pagar do contato
+func GetContactBalance(ctx context.Context, contactID int) (*models.ContactBalance, error) {
+	repo, err := repository.NewStatementRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetContactBalance(ctx, contactID)
+}
+
+func truncateToDay(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetContactStatementInvalidRange(t *testing.T) {
+	from := time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := GetContactStatement(context.Background(), 1, from, to)
+	if err != errors.ErrInvalidDateRange {
+		t.Errorf("Esperado ErrInvalidDateRange, obtido %v", err)
+	}
+}
+
+func TestTruncateToDay(t *testing.T) {
+	value := time.Date(2025, 5, 10, 15, 30, 0, 0, time.UTC)
+	truncated := truncateToDay(value)
+
+	if truncated.Hour() != 0 || truncated.Minute() != 0 || truncated.Day() != 10 {
+		t.Errorf("Esperado início do dia 10, obtido %v", truncated)
+	}
+
+	if !truncateToDay(time.Time{}).IsZero() {
+		t.Error("Data zero deveria permanecer zero")
+	}
+}
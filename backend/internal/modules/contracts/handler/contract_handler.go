@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/contracts/models"
+	"ERP-ONSMART/backend/internal/modules/contracts/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os contratos com clientes e fornecedores, os de vencimento mais próximo primeiro
+// @Security BearerAuth
+// @Param contact_id query int false "ID do cliente ou fornecedor"
+// @Param type query string false "sales ou purchase"
+// @Param status query string false "active ou cancelled"
+func ListContractsHandler(c *gin.Context) {
+	filter := models.ContractFilter{Type: c.Query("type"), Status: c.Query("status")}
+	if value := c.Query("contact_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("contact_id inválido"))
+			return
+		}
+		filter.ContactID = id
+	}
+
+	contracts, err := service.ListContracts(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar contratos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"contracts": contracts})
+}
+
+// Lista os contratos vigentes que vencem nos próximos dias, para os alertas de renovação
+// @Security BearerAuth
+// @Param days query int false "Dias até o vencimento (padrão 30)"
+func ListExpiringContractsHandler(c *gin.Context) {
+	days := 0
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.Error(errors.InvalidParam("days deve ser um inteiro positivo"))
+			return
+		}
+		days = parsed
+	}
+
+	contracts, err := service.ListExpiringContracts(c.Request.Context(), days)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar contratos a vencer")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"contracts": contracts})
+}
+
+// Busca um contrato com os preços e volumes mínimos dos itens
+// @Security BearerAuth
+// @Param id path int true "ID do contrato"
+func GetContractHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	contract, err := service.GetContract(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar contrato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"contract": contract})
+}
+
+// Cadastra um contrato de venda (com cliente) ou de compra (com fornecedor). Enquanto vigente,
+// as cotações e pedidos de compra do contato usam os preços contratados dos produtos.
+// @Security BearerAuth
+func CreateContractHandler(c *gin.Context) {
+	var input models.ContractInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	contract, err := service.CreateContract(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar contrato")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"contract": contract})
+}
+
+// Altera as condições e substitui os itens de um contrato ativo
+// @Security BearerAuth
+// @Param id path int true "ID do contrato"
+func UpdateContractHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ContractInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	contract, err := service.UpdateContract(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar contrato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"contract": contract})
+}
+
+// Cancela um contrato; os documentos já emitidos mantêm os preços
+// @Security BearerAuth
+// @Param id path int true "ID do contrato"
+func CancelContractHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.CancelContract(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao cancelar contrato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contrato cancelado com sucesso"})
+}
+
+// Compara o volume mínimo de cada item com o já pedido na vigência (pedidos de venda ou de
+// compra do contato, em unidades de estoque)
+// @Security BearerAuth
+// @Param id path int true "ID do contrato"
+func GetContractVolumesHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	volumes, err := service.GetContractVolumes(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao calcular volumes do contrato")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"volumes": volumes})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
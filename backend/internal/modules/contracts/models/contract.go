@@ -0,0 +1,211 @@
+package models
+
+import (
+	"math"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// Tipos de contrato: de venda (com cliente) e de compra (com fornecedor)
+const (
+	TypeSales    = "sales"
+	TypePurchase = "purchase"
+)
+
+// Situação do contrato; o vencimento é dado pela data final, não pela situação
+const (
+	StatusActive    = "active"
+	StatusCancelled = "cancelled"
+)
+
+// ExpiryAlertDays é a antecedência, em dias, do alerta de vencimento do contrato
+const ExpiryAlertDays = 30
+
+// ContactTypes é o tipo de contato aceito em cada tipo de contrato
+var ContactTypes = map[string]string{
+	TypeSales:    "cliente",
+	TypePurchase: "fornecedor",
+}
+
+// Contract reúne as condições negociadas com um cliente ou fornecedor no período de vigência
+// (datas inclusivas). Os preços dos itens valem por unidade de estoque.
+type Contract struct {
+	ID              int            `json:"id" gorm:"primaryKey"`
+	CompanyID       int            `json:"company_id" gorm:"<-:create"`
+	ContractNo      string         `json:"contract_no"`
+	ContactID       int            `json:"contact_id"`
+	Type            string         `json:"type"`
+	Title           string         `json:"title"`
+	StartDate       time.Time      `json:"start_date"`
+	EndDate         time.Time      `json:"end_date"`
+	Status          string         `json:"status"`
+	Notes           string         `json:"notes,omitempty"`
+	AlertEmails     []string       `json:"alert_emails" gorm:"serializer:json"`
+	ExpiryAlertedAt *time.Time     `json:"expiry_alerted_at,omitempty"`
+	CreatedBy       string         `json:"created_by"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	Items           []ContractItem `json:"items" gorm:"foreignKey:ContractID"`
+}
+
+func (Contract) TableName() string {
+	return "contracts"
+}
+
+// ContractItem é o preço fixo de um produto no contrato e o volume mínimo comprometido no período
+type ContractItem struct {
+	ID          int           `json:"id" gorm:"primaryKey"`
+	ContractID  int           `json:"contract_id"`
+	ProductID   int           `json:"product_id"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	MinQuantity int           `json:"min_quantity"`
+}
+
+func (ContractItem) TableName() string {
+	return "contract_items"
+}
+
+// ContractInput é o corpo aceito em POST e PUT /contracts
+type ContractInput struct {
+	ContractNo  string              `json:"contract_no" binding:"required,max=50"`
+	ContactID   int                 `json:"contact_id" binding:"required"`
+	Type        string              `json:"type" binding:"required,oneof=sales purchase"`
+	Title       string              `json:"title" binding:"required,max=150"`
+	StartDate   time.Time           `json:"start_date" binding:"required"`
+	EndDate     time.Time           `json:"end_date" binding:"required"`
+	Notes       string              `json:"notes"`
+	AlertEmails []string            `json:"alert_emails" binding:"max=20"`
+	Items       []ContractItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// ContractItemInput é um item do contrato no corpo da requisição
+type ContractItemInput struct {
+	ProductID   int           `json:"product_id" binding:"required"`
+	UnitPrice   money.Decimal `json:"unit_price" binding:"required,gt=0"`
+	MinQuantity int           `json:"min_quantity" binding:"gte=0"`
+}
+
+// ContractFilter restringe a listagem de contratos; campos vazios não filtram
+type ContractFilter struct {
+	ContactID int
+	Type      string
+	Status    string
+}
+
+// Active indica se o contrato vale na data: não cancelado e dentro da vigência
+func (c Contract) Active(date time.Time) bool {
+	day := calendarDay(date)
+	return c.Status == StatusActive && !day.Before(calendarDay(c.StartDate)) && !day.After(calendarDay(c.EndDate))
+}
+
+// DaysToExpiry conta os dias da data até o fim da vigência (negativo se já venceu)
+func (c Contract) DaysToExpiry(date time.Time) int {
+	return int(math.Round(calendarDay(c.EndDate).Sub(calendarDay(date)).Hours() / 24))
+}
+
+// DueForExpiryAlert indica se o alerta de vencimento deve sair na data: contrato vigente que
+// vence dentro da antecedência do alerta e ainda não foi avisado
+func (c Contract) DueForExpiryAlert(date time.Time) bool {
+	return c.ExpiryAlertedAt == nil && c.Active(date) && c.DaysToExpiry(date) <= ExpiryAlertDays
+}
+
+// Price é o preço contratado de um produto, por unidade de estoque
+type Price struct {
+	ContractID int
+	ContractNo string
+	ProductID  int
+	UnitPrice  money.Decimal
+}
+
+// PriceTable monta o preço por produto dos contratos vigentes na data. Com mais de um contrato
+// cobrindo o produto, vale o de início mais recente.
+func PriceTable(contracts []Contract, date time.Time) map[int]Price {
+	active := make([]Contract, 0, len(contracts))
+	for _, contract := range contracts {
+		if contract.Active(date) {
+			active = append(active, contract)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if !active[i].StartDate.Equal(active[j].StartDate) {
+			return active[i].StartDate.After(active[j].StartDate)
+		}
+		return active[i].ID > active[j].ID
+	})
+
+	prices := map[int]Price{}
+	for _, contract := range active {
+		for _, item := range contract.Items {
+			if _, ok := prices[item.ProductID]; ok {
+				continue
+			}
+			prices[item.ProductID] = Price{
+				ContractID: contract.ID,
+				ContractNo: contract.ContractNo,
+				ProductID:  item.ProductID,
+				UnitPrice:  item.UnitPrice,
+			}
+		}
+	}
+	return prices
+}
+
+// Volume compara o volume mínimo do item com o já pedido na vigência, em unidades de estoque
+type Volume struct {
+	ProductID   int           `json:"product_id"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	MinQuantity int           `json:"min_quantity"`
+	Ordered     int           `json:"ordered_quantity"`
+	Remaining   int           `json:"remaining_quantity"`
+	Fulfilled   float64       `json:"fulfilled_percentage"`
+}
+
+// Volumes calcula o atendimento do volume mínimo de cada item a partir das quantidades pedidas
+// por produto
+func Volumes(items []ContractItem, ordered map[int]int) []Volume {
+	volumes := make([]Volume, 0, len(items))
+	for _, item := range items {
+		volume := Volume{
+			ProductID:   item.ProductID,
+			UnitPrice:   item.UnitPrice,
+			MinQuantity: item.MinQuantity,
+			Ordered:     ordered[item.ProductID],
+		}
+		if item.MinQuantity > 0 {
+			if volume.Remaining = item.MinQuantity - volume.Ordered; volume.Remaining < 0 {
+				volume.Remaining = 0
+			}
+			volume.Fulfilled = math.Round(float64(volume.Ordered)/float64(item.MinQuantity)*10000) / 100
+		}
+		volumes = append(volumes, volume)
+	}
+	return volumes
+}
+
+// NormalizeEmails remove espaços e endereços repetidos; false se algum for inválido
+func NormalizeEmails(emails []string) ([]string, bool) {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, email := range emails {
+		address, err := mail.ParseAddress(strings.TrimSpace(email))
+		if err != nil {
+			return nil, false
+		}
+		value := strings.ToLower(address.Address)
+		if !seen[value] {
+			seen[value] = true
+			normalized = append(normalized, value)
+		}
+	}
+	return normalized, true
+}
+
+// calendarDay reduz t ao dia do calendário, em UTC, para comparar as datas do contrato (que vêm
+// do banco em UTC) com o dia de referência no fuso do servidor
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
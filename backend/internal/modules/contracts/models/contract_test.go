@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestContractActiveAndExpiry(t *testing.T) {
+	contract := Contract{Status: StatusActive, StartDate: date(2024, 1, 1), EndDate: date(2024, 6, 30)}
+
+	assert.False(t, contract.Active(date(2023, 12, 31)))
+	assert.True(t, contract.Active(date(2024, 1, 1)))
+	assert.True(t, contract.Active(time.Date(2024, 6, 30, 23, 30, 0, 0, time.FixedZone("BRT", -3*3600))))
+	assert.False(t, contract.Active(date(2024, 7, 1)))
+
+	assert.Equal(t, 30, contract.DaysToExpiry(date(2024, 5, 31)))
+	assert.Equal(t, -1, contract.DaysToExpiry(date(2024, 7, 1)))
+
+	assert.False(t, contract.DueForExpiryAlert(date(2024, 5, 30)))
+	assert.True(t, contract.DueForExpiryAlert(date(2024, 5, 31)))
+
+	alerted := date(2024, 5, 31)
+	contract.ExpiryAlertedAt = &alerted
+	assert.False(t, contract.DueForExpiryAlert(date(2024, 6, 10)))
+
+	contract.ExpiryAlertedAt = nil
+	contract.Status = StatusCancelled
+	assert.False(t, contract.DueForExpiryAlert(date(2024, 6, 10)))
+}
+
+func TestPriceTablePrefersLatestActiveContract(t *testing.T) {
+	contracts := []Contract{
+		{ID: 1, ContractNo: "C-1", Status: StatusActive, StartDate: date(2024, 1, 1), EndDate: date(2024, 12, 31),
+			Items: []ContractItem{{ProductID: 10, UnitPrice: money.FromInt(5)}, {ProductID: 20, UnitPrice: money.FromInt(8)}}},
+		{ID: 2, ContractNo: "C-2", Status: StatusActive, StartDate: date(2024, 3, 1), EndDate: date(2024, 12, 31),
+			Items: []ContractItem{{ProductID: 10, UnitPrice: money.MustParse("4.5")}}},
+		{ID: 3, ContractNo: "C-3", Status: StatusActive, StartDate: date(2024, 8, 1), EndDate: date(2024, 12, 31),
+			Items: []ContractItem{{ProductID: 20, UnitPrice: money.FromInt(7)}}},
+	}
+
+	prices := PriceTable(contracts, date(2024, 4, 15))
+
+	assert.Len(t, prices, 2)
+	assert.Equal(t, Price{ContractID: 2, ContractNo: "C-2", ProductID: 10, UnitPrice: money.MustParse("4.5")}, prices[10])
+	assert.Equal(t, Price{ContractID: 1, ContractNo: "C-1", ProductID: 20, UnitPrice: money.FromInt(8)}, prices[20])
+}
+
+func TestVolumes(t *testing.T) {
+	items := []ContractItem{
+		{ProductID: 10, UnitPrice: money.FromInt(5), MinQuantity: 200},
+		{ProductID: 20, UnitPrice: money.FromInt(8), MinQuantity: 50},
+		{ProductID: 30, UnitPrice: money.FromInt(2)},
+	}
+
+	volumes := Volumes(items, map[int]int{10: 50, 20: 80, 30: 12})
+
+	assert.Equal(t, Volume{ProductID: 10, UnitPrice: money.FromInt(5), MinQuantity: 200, Ordered: 50, Remaining: 150, Fulfilled: 25}, volumes[0])
+	assert.Equal(t, Volume{ProductID: 20, UnitPrice: money.FromInt(8), MinQuantity: 50, Ordered: 80, Remaining: 0, Fulfilled: 160}, volumes[1])
+	assert.Equal(t, Volume{ProductID: 30, UnitPrice: money.FromInt(2), Ordered: 12}, volumes[2])
+}
+
+func TestNormalizeEmails(t *testing.T) {
+	emails, ok := NormalizeEmails([]string{" Compras@Empresa.com ", "compras@empresa.com", "juridico@empresa.com"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"compras@empresa.com", "juridico@empresa.com"}, emails)
+
+	_, ok = NormalizeEmails([]string{"invalido"})
+	assert.False(t, ok)
+}
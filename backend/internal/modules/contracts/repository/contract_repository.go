@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contracts/models"
+	products "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ContractRepository mantém os contratos e seus itens, soma os volumes pedidos e busca os
+// contratos a vencer para os alertas
+type ContractRepository interface {
+	ListContracts(ctx context.Context, filter models.ContractFilter) ([]models.Contract, error)
+	GetContract(ctx context.Context, id int) (*models.Contract, error)
+	CreateContract(ctx context.Context, contract *models.Contract) error
+	UpdateContract(ctx context.Context, contract *models.Contract) error
+	CancelContract(ctx context.Context, id int) error
+
+	GetContact(ctx context.Context, id int) (*contact.Contact, error)
+	CountProducts(ctx context.Context, ids []int) (int, error)
+	OrderedQuantities(ctx context.Context, contract *models.Contract) (map[int]int, error)
+
+	ExpiringContracts(ctx context.Context, from, until time.Time) ([]models.Contract, error)
+	MarkExpiryAlerted(ctx context.Context, id int, at time.Time) error
+}
+
+type contractRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewContractRepository cria uma nova instância do repositório
+func NewContractRepository() (ContractRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &contractRepository{
+		db:     db,
+		logger: logger.WithModule("contract_repository"),
+	}, nil
+}
+
+// ListContracts lista os contratos da empresa, os de vencimento mais próximo primeiro
+func (r *contractRepository) ListContracts(ctx context.Context, filter models.ContractFilter) ([]models.Contract, error) {
+	query := r.db.WithContext(ctx).Preload("Items")
+	if filter.ContactID != 0 {
+		query = query.Where("contact_id = ?", filter.ContactID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var contracts []models.Contract
+	if err := query.Order("end_date ASC, id ASC").Find(&contracts).Error; err != nil {
+		r.logger.Error("erro ao listar contratos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar contratos")
+	}
+	return contracts, nil
+}
+
+// GetContract busca um contrato com os itens
+func (r *contractRepository) GetContract(ctx context.Context, id int) (*models.Contract, error) {
+	var contract models.Contract
+	if err := r.db.WithContext(ctx).Preload("Items").First(&contract, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrContractNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar contrato")
+	}
+	return &contract, nil
+}
+
+// CreateContract grava o contrato e os itens; o número é único na empresa
+func (r *contractRepository) CreateContract(ctx context.Context, contract *models.Contract) error {
+	if err := r.checkNumber(ctx, contract); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(contract).Error; err != nil {
+		r.logger.Error("erro ao criar contrato", zap.Error(err), zap.String("contract_no", contract.ContractNo))
+		return errors.WrapError(err, "falha ao criar contrato")
+	}
+	return nil
+}
+
+// UpdateContract altera as condições do contrato e substitui os itens. Mudar o fim da vigência
+// libera um novo alerta de vencimento.
+func (r *contractRepository) UpdateContract(ctx context.Context, contract *models.Contract) error {
+	if err := r.checkNumber(ctx, contract); err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(contract).
+			Select("contract_no", "contact_id", "type", "title", "start_date", "end_date", "notes",
+				"alert_emails", "expiry_alerted_at", "updated_at").
+			Updates(contract)
+		if result.Error != nil {
+			r.logger.Error("erro ao atualizar contrato", zap.Error(result.Error), zap.Int("id", contract.ID))
+			return errors.WrapError(result.Error, "falha ao atualizar contrato")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrContractNotFound
+		}
+
+		if err := tx.Where("contract_id = ?", contract.ID).Delete(&models.ContractItem{}).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover itens do contrato")
+		}
+		for i := range contract.Items {
+			contract.Items[i].ID = 0
+			contract.Items[i].ContractID = contract.ID
+		}
+		if err := tx.Create(&contract.Items).Error; err != nil {
+			r.logger.Error("erro ao gravar itens do contrato", zap.Error(err), zap.Int("id", contract.ID))
+			return errors.WrapError(err, "falha ao gravar itens do contrato")
+		}
+		return nil
+	})
+}
+
+// CancelContract encerra o contrato; os preços deixam de valer nos novos documentos
+func (r *contractRepository) CancelContract(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Model(&models.Contract{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": models.StatusCancelled, "updated_at": time.Now()})
+	if result.Error != nil {
+		r.logger.Error("erro ao cancelar contrato", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao cancelar contrato")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrContractNotFound
+	}
+	return nil
+}
+
+func (r *contractRepository) checkNumber(ctx context.Context, contract *models.Contract) error {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Contract{}).
+		Where("contract_no = ? AND id <> ?", contract.ContractNo, contract.ID).
+		Count(&count).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao verificar número do contrato")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateContractNo
+	}
+	return nil
+}
+
+// GetContact busca o cliente ou fornecedor do contrato
+func (r *contractRepository) GetContact(ctx context.Context, id int) (*contact.Contact, error) {
+	var c contact.Contact
+	if err := r.db.WithContext(ctx).First(&c, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrContactNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar contato")
+	}
+	return &c, nil
+}
+
+// CountProducts conta quantos dos produtos informados existem na empresa
+func (r *contractRepository) CountProducts(ctx context.Context, ids []int) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&products.Product{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return 0, errors.WrapError(err, "falha ao verificar produtos do contrato")
+	}
+	return int(count), nil
+}
+
+// OrderedQuantities soma, em unidades de estoque, os produtos do contrato pedidos pelo contato
+// na vigência: pedidos de venda nos contratos de venda e pedidos de compra nos de compra, sem os
+// cancelados
+func (r *contractRepository) OrderedQuantities(ctx context.Context, contract *models.Contract) (map[int]int, error) {
+	ordered := map[int]int{}
+	if len(contract.Items) == 0 {
+		return ordered, nil
+	}
+	productIDs := make([]int, 0, len(contract.Items))
+	for _, item := range contract.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	orders, items, cancelled := "sales_orders", "sales_order_items i ON i.sales_order_id = o.id", sales.SOStatusCancelled
+	if contract.Type == models.TypePurchase {
+		orders, items, cancelled = "purchase_orders", "purchase_order_items i ON i.purchase_order_id = o.id", sales.POStatusCancelled
+	}
+
+	var rows []struct {
+		ProductID int
+		Quantity  int
+	}
+	err := r.db.WithContext(ctx).Table(orders+" o").
+		Select("i.product_id, COALESCE(SUM(i.quantity * i.unit_factor), 0) AS quantity").
+		Joins("JOIN "+items).
+		Scopes(tenant.Scope(ctx, "o")).
+		Where("o.contact_id = ? AND o.status <> ?", contract.ContactID, cancelled).
+		Where("o.created_at >= ? AND o.created_at < ?", contract.StartDate, contract.EndDate.AddDate(0, 0, 1)).
+		Where("i.product_id IN ?", productIDs).
+		Group("i.product_id").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao somar volumes do contrato", zap.Error(err), zap.Int("id", contract.ID))
+		return nil, errors.WrapError(err, "falha ao somar volumes do contrato")
+	}
+	for _, row := range rows {
+		ordered[row.ProductID] = row.Quantity
+	}
+	return ordered, nil
+}
+
+// ExpiringContracts retorna os contratos ativos, ainda não avisados, que vencem entre as datas.
+// O contexto deve vir de tenant.AllCompanies: a rotina atende todas as empresas.
+func (r *contractRepository) ExpiringContracts(ctx context.Context, from, until time.Time) ([]models.Contract, error) {
+	var contracts []models.Contract
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expiry_alerted_at IS NULL AND end_date >= ? AND end_date <= ?",
+			models.StatusActive, from, until).
+		Order("end_date ASC, id ASC").
+		Find(&contracts).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar contratos a vencer", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar contratos a vencer")
+	}
+	return contracts, nil
+}
+
+// MarkExpiryAlerted registra o envio do alerta de vencimento
+func (r *contractRepository) MarkExpiryAlerted(ctx context.Context, id int, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.Contract{}).Where("id = ?", id).
+		Update("expiry_alerted_at", at).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao registrar alerta de vencimento do contrato")
+	}
+	return nil
+}
+
+// LoadPrices carrega os preços dos contratos vigentes na data entre a empresa e o contato, para
+// os produtos informados; a transação deve carregar o contexto da empresa
+func LoadPrices(tx *gorm.DB, contactID int, contractType string, productIDs []int, date time.Time) (map[int]models.Price, error) {
+	if contactID == 0 || len(productIDs) == 0 {
+		return map[int]models.Price{}, nil
+	}
+
+	var contracts []models.Contract
+	err := tx.Preload("Items", "product_id IN ?", productIDs).
+		Where("contact_id = ? AND type = ? AND status = ?", contactID, contractType, models.StatusActive).
+		Where("start_date <= ? AND end_date >= ?", date, date.AddDate(0, 0, -1)).
+		Find(&contracts).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar preços contratados")
+	}
+	return models.PriceTable(contracts, date), nil
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/mailer"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/modules/contracts/models"
+	"ERP-ONSMART/backend/internal/modules/contracts/repository"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	templateService "ERP-ONSMART/backend/internal/modules/templates/service"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// ContractService mantém os contratos com clientes e fornecedores e avisa os vencimentos
+type ContractService struct {
+	newRepo  func() (repository.ContractRepository, error)
+	send     func(mailer.Message) error
+	render   func(ctx context.Context, key string, data templates.Data) (*templates.Rendered, error)
+	now      func() time.Time
+	location func(ctx context.Context) *time.Location
+	logger   *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.ContractRepository
+}
+
+// NewContractService cria o serviço sobre o repositório informado
+func NewContractService(newRepo func() (repository.ContractRepository, error)) *ContractService {
+	return &ContractService{
+		newRepo:  newRepo,
+		send:     mailer.Send,
+		render:   templateService.Render,
+		now:      time.Now,
+		location: localtime.Location,
+		logger:   logger.WithModule("contract_service"),
+	}
+}
+
+var defaultService = NewContractService(repository.NewContractRepository)
+
+// ListContracts lista os contratos da empresa
+func ListContracts(ctx context.Context, filter models.ContractFilter) ([]models.Contract, error) {
+	return defaultService.ListContracts(ctx, filter)
+}
+
+// GetContract busca um contrato com os itens
+func GetContract(ctx context.Context, id int) (*models.Contract, error) {
+	return defaultService.GetContract(ctx, id)
+}
+
+// CreateContract valida e grava um contrato
+func CreateContract(ctx context.Context, input models.ContractInput, createdBy string) (*models.Contract, error) {
+	return defaultService.CreateContract(ctx, input, createdBy)
+}
+
+// UpdateContract valida e altera um contrato
+func UpdateContract(ctx context.Context, id int, input models.ContractInput) (*models.Contract, error) {
+	return defaultService.UpdateContract(ctx, id, input)
+}
+
+// CancelContract encerra um contrato
+func CancelContract(ctx context.Context, id int) error {
+	return defaultService.CancelContract(ctx, id)
+}
+
+// GetContractVolumes compara o volume mínimo dos itens com o já pedido na vigência
+func GetContractVolumes(ctx context.Context, id int) ([]models.Volume, error) {
+	return defaultService.Volumes(ctx, id)
+}
+
+// ListExpiringContracts lista os contratos vigentes que vencem nos próximos dias
+func ListExpiringContracts(ctx context.Context, days int) ([]models.Contract, error) {
+	return defaultService.ListExpiring(ctx, days)
+}
+
+// StartContractExpiryScheduler avisa periodicamente os contratos a vencer de todas as empresas
+func StartContractExpiryScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("contract_expiry_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				alerted, err := defaultService.RunExpiryAlerts(ctx)
+				metrics.ObserveJob("contract_expiry_scheduler", err)
+				if err != nil {
+					log.Error("erro ao avisar contratos a vencer", zap.Error(err))
+					continue
+				}
+				if alerted > 0 {
+					log.Info("alertas de vencimento de contratos enviados", zap.Int("contracts", alerted))
+				}
+			}
+		}
+	}()
+}
+
+func (s *ContractService) repository() (repository.ContractRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListContracts lista os contratos conforme o filtro
+func (s *ContractService) ListContracts(ctx context.Context, filter models.ContractFilter) ([]models.Contract, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListContracts(ctx, filter)
+}
+
+// GetContract busca um contrato com os itens
+func (s *ContractService) GetContract(ctx context.Context, id int) (*models.Contract, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetContract(ctx, id)
+}
+
+// CreateContract grava o contrato ativo
+func (s *ContractService) CreateContract(ctx context.Context, input models.ContractInput, createdBy string) (*models.Contract, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	contract := &models.Contract{Status: models.StatusActive, CreatedBy: createdBy}
+	if err := s.applyInput(ctx, repo, contract, input); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateContract(ctx, contract); err != nil {
+		return nil, err
+	}
+	return contract, nil
+}
+
+// UpdateContract altera as condições do contrato. Uma nova data final libera um novo alerta de
+// vencimento.
+func (s *ContractService) UpdateContract(ctx context.Context, id int, input models.ContractInput) (*models.Contract, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	contract, err := repo.GetContract(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if contract.Status == models.StatusCancelled {
+		return nil, fmt.Errorf("%w: contrato cancelado não pode ser alterado", errors.ErrInvalidContract)
+	}
+	previousEnd := contract.EndDate
+	if err := s.applyInput(ctx, repo, contract, input); err != nil {
+		return nil, err
+	}
+	if !contract.EndDate.Equal(previousEnd) {
+		contract.ExpiryAlertedAt = nil
+	}
+	if err := repo.UpdateContract(ctx, contract); err != nil {
+		return nil, err
+	}
+	return contract, nil
+}
+
+// CancelContract encerra o contrato; documentos já emitidos mantêm os preços
+func (s *ContractService) CancelContract(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.CancelContract(ctx, id)
+}
+
+// Volumes compara o volume mínimo de cada item com o já pedido pelo contato na vigência
+func (s *ContractService) Volumes(ctx context.Context, id int) ([]models.Volume, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	contract, err := repo.GetContract(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ordered, err := repo.OrderedQuantities(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	return models.Volumes(contract.Items, ordered), nil
+}
+
+// ListExpiring lista os contratos vigentes que vencem até days dias à frente (padrão
+// ExpiryAlertDays), avisados ou não
+func (s *ContractService) ListExpiring(ctx context.Context, days int) ([]models.Contract, error) {
+	if days <= 0 {
+		days = models.ExpiryAlertDays
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := repo.ListContracts(ctx, models.ContractFilter{Status: models.StatusActive})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().In(s.location(ctx))
+	expiring := []models.Contract{}
+	for _, contract := range contracts {
+		if contract.Active(now) && contract.DaysToExpiry(now) <= days {
+			expiring = append(expiring, contract)
+		}
+	}
+	return expiring, nil
+}
+
+// RunExpiryAlerts avisa por e-mail os contratos de todas as empresas que vencem dentro de
+// ExpiryAlertDays e registra o alerta, que sai uma vez por vencimento. A falha no envio de um
+// contrato fica no log e não impede os demais; o contrato volta a ser tentado no próximo ciclo.
+func (s *ContractService) RunExpiryAlerts(ctx context.Context) (int, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	// o dia de cada empresa, no fuso dela, pode ser o anterior ou o seguinte ao dia em UTC
+	today := localtime.Date(now.UTC())
+	contracts, err := repo.ExpiringContracts(tenant.AllCompanies(ctx), today.AddDate(0, 0, -1), today.AddDate(0, 0, models.ExpiryAlertDays+1))
+	if err != nil {
+		return 0, err
+	}
+
+	alerted := 0
+	for _, contract := range contracts {
+		companyCtx := tenant.WithCompany(ctx, contract.CompanyID)
+		local := now.In(s.location(companyCtx))
+		if !contract.DueForExpiryAlert(local) {
+			continue
+		}
+		if err := s.alert(ctx, contract, local); err != nil {
+			s.logger.Warn("erro ao enviar alerta de vencimento do contrato", zap.Error(err),
+				zap.Int("contract_id", contract.ID), zap.Int("company_id", contract.CompanyID))
+			continue
+		}
+		if err := repo.MarkExpiryAlerted(companyCtx, contract.ID, now); err != nil {
+			return alerted, err
+		}
+		alerted++
+	}
+	return alerted, nil
+}
+
+// alert registra o evento de vencimento no log e envia o e-mail aos destinatários do contrato,
+// com o modelo email.contract_expiring da empresa do contrato
+func (s *ContractService) alert(ctx context.Context, contract models.Contract, now time.Time) error {
+	days := contract.DaysToExpiry(now)
+	s.logger.Info("contrato a vencer",
+		zap.String("event", "contract.expiring"),
+		zap.Int("contract_id", contract.ID),
+		zap.Int("company_id", contract.CompanyID),
+		zap.String("contract_no", contract.ContractNo),
+		zap.Int("days_to_expiry", days))
+
+	message, err := s.render(tenant.WithCompany(ctx, contract.CompanyID), templates.KeyContractExpiringEmail,
+		templates.Data{Contract: &contract, Vars: map[string]interface{}{"days": days}})
+	if err != nil {
+		return err
+	}
+	for _, recipient := range contract.AlertEmails {
+		err := s.send(mailer.Message{To: recipient, Subject: message.Subject, Body: message.Body})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyInput valida o período, o contato (cliente nos contratos de venda, fornecedor nos de
+// compra), os produtos e os e-mails de alerta
+func (s *ContractService) applyInput(ctx context.Context, repo repository.ContractRepository, contract *models.Contract, input models.ContractInput) error {
+	contractNo := strings.ToUpper(strings.TrimSpace(input.ContractNo))
+	title := strings.TrimSpace(input.Title)
+	if contractNo == "" || title == "" {
+		return fmt.Errorf("%w: informe o número e o título do contrato", errors.ErrInvalidContract)
+	}
+	if input.EndDate.Before(input.StartDate) {
+		return fmt.Errorf("%w: a data final é anterior à inicial", errors.ErrInvalidContract)
+	}
+	emails, ok := models.NormalizeEmails(input.AlertEmails)
+	if !ok {
+		return fmt.Errorf("%w: e-mail de alerta inválido", errors.ErrInvalidContract)
+	}
+
+	items := make([]models.ContractItem, 0, len(input.Items))
+	seen := map[int]bool{}
+	productIDs := make([]int, 0, len(input.Items))
+	for _, item := range input.Items {
+		if seen[item.ProductID] {
+			return fmt.Errorf("%w: produto %d repetido no contrato", errors.ErrInvalidContract, item.ProductID)
+		}
+		seen[item.ProductID] = true
+		productIDs = append(productIDs, item.ProductID)
+		items = append(items, models.ContractItem{
+			ProductID:   item.ProductID,
+			UnitPrice:   item.UnitPrice,
+			MinQuantity: item.MinQuantity,
+		})
+	}
+
+	contact, err := repo.GetContact(ctx, input.ContactID)
+	if err != nil {
+		return err
+	}
+	if contact.Type != models.ContactTypes[input.Type] {
+		return fmt.Errorf("%w: contratos de %s exigem contato do tipo %s", errors.ErrInvalidContract,
+			input.Type, models.ContactTypes[input.Type])
+	}
+	count, err := repo.CountProducts(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+	if count != len(productIDs) {
+		return errors.ErrProductNotFound
+	}
+
+	contract.ContractNo = contractNo
+	contract.ContactID = input.ContactID
+	contract.Type = input.Type
+	contract.Title = title
+	contract.StartDate = input.StartDate
+	contract.EndDate = input.EndDate
+	contract.Notes = strings.TrimSpace(input.Notes)
+	contract.AlertEmails = emails
+	contract.Items = items
+	return nil
+}
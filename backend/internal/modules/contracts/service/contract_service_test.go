@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/mailer"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/contracts/models"
+	"ERP-ONSMART/backend/internal/modules/contracts/repository"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeContractRepo struct {
+	contracts []models.Contract
+	contacts  map[int]*contact.Contact
+	products  map[int]bool
+	ordered   map[int]int
+	alerted   []int
+}
+
+func (r *fakeContractRepo) ListContracts(ctx context.Context, filter models.ContractFilter) ([]models.Contract, error) {
+	return r.contracts, nil
+}
+
+func (r *fakeContractRepo) GetContract(ctx context.Context, id int) (*models.Contract, error) {
+	for i := range r.contracts {
+		if r.contracts[i].ID == id {
+			return &r.contracts[i], nil
+		}
+	}
+	return nil, appErrors.ErrContractNotFound
+}
+
+func (r *fakeContractRepo) CreateContract(ctx context.Context, contract *models.Contract) error {
+	contract.ID = len(r.contracts) + 1
+	r.contracts = append(r.contracts, *contract)
+	return nil
+}
+
+func (r *fakeContractRepo) UpdateContract(ctx context.Context, contract *models.Contract) error {
+	return nil
+}
+
+func (r *fakeContractRepo) CancelContract(ctx context.Context, id int) error {
+	return nil
+}
+
+func (r *fakeContractRepo) GetContact(ctx context.Context, id int) (*contact.Contact, error) {
+	c, ok := r.contacts[id]
+	if !ok {
+		return nil, appErrors.ErrContactNotFound
+	}
+	return c, nil
+}
+
+func (r *fakeContractRepo) CountProducts(ctx context.Context, ids []int) (int, error) {
+	count := 0
+	for _, id := range ids {
+		if r.products[id] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeContractRepo) OrderedQuantities(ctx context.Context, contract *models.Contract) (map[int]int, error) {
+	return r.ordered, nil
+}
+
+func (r *fakeContractRepo) ExpiringContracts(ctx context.Context, from, until time.Time) ([]models.Contract, error) {
+	return r.contracts, nil
+}
+
+func (r *fakeContractRepo) MarkExpiryAlerted(ctx context.Context, id int, at time.Time) error {
+	r.alerted = append(r.alerted, id)
+	return nil
+}
+
+func newTestService(repo *fakeContractRepo, now time.Time) (*ContractService, *[]mailer.Message) {
+	sent := []mailer.Message{}
+	s := NewContractService(func() (repository.ContractRepository, error) { return repo, nil })
+	s.now = func() time.Time { return now }
+	s.location = func(context.Context) *time.Location { return time.UTC }
+	s.render = func(ctx context.Context, key string, data templates.Data) (*templates.Rendered, error) {
+		return templates.RenderBuiltin(key, data)
+	}
+	s.send = func(msg mailer.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}
+	return s, &sent
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func validInput() models.ContractInput {
+	return models.ContractInput{
+		ContractNo:  " ct-2024-01 ",
+		ContactID:   1,
+		Type:        models.TypeSales,
+		Title:       "Fornecimento anual",
+		StartDate:   day(2024, 1, 1),
+		EndDate:     day(2024, 12, 31),
+		AlertEmails: []string{"Comercial@Empresa.com"},
+		Items:       []models.ContractItemInput{{ProductID: 10, UnitPrice: money.MustParse("9.9"), MinQuantity: 100}},
+	}
+}
+
+func TestCreateContractValidatesInput(t *testing.T) {
+	repo := &fakeContractRepo{
+		contacts: map[int]*contact.Contact{1: {ID: 1, Type: "cliente"}, 2: {ID: 2, Type: "fornecedor"}},
+		products: map[int]bool{10: true},
+	}
+	s, _ := newTestService(repo, day(2024, 1, 1))
+	ctx := context.Background()
+
+	contract, err := s.CreateContract(ctx, validInput(), "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "CT-2024-01", contract.ContractNo)
+	assert.Equal(t, models.StatusActive, contract.Status)
+	assert.Equal(t, []string{"comercial@empresa.com"}, contract.AlertEmails)
+
+	input := validInput()
+	input.ContactID = 2
+	_, err = s.CreateContract(ctx, input, "admin")
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidContract))
+
+	input = validInput()
+	input.EndDate = day(2023, 12, 31)
+	_, err = s.CreateContract(ctx, input, "admin")
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidContract))
+
+	input = validInput()
+	input.Items = append(input.Items, models.ContractItemInput{ProductID: 10, UnitPrice: money.FromInt(8)})
+	_, err = s.CreateContract(ctx, input, "admin")
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidContract))
+
+	input = validInput()
+	input.Items[0].ProductID = 99
+	_, err = s.CreateContract(ctx, input, "admin")
+	assert.Equal(t, appErrors.ErrProductNotFound, err)
+}
+
+func TestUpdateContractResetsExpiryAlert(t *testing.T) {
+	alerted := day(2024, 12, 1)
+	repo := &fakeContractRepo{
+		contracts: []models.Contract{{ID: 1, ContractNo: "CT-2024-01", Status: models.StatusActive,
+			StartDate: day(2024, 1, 1), EndDate: day(2024, 12, 31), ExpiryAlertedAt: &alerted}},
+		contacts: map[int]*contact.Contact{1: {ID: 1, Type: "cliente"}},
+		products: map[int]bool{10: true},
+	}
+	s, _ := newTestService(repo, day(2024, 12, 5))
+
+	input := validInput()
+	input.EndDate = day(2025, 12, 31)
+	contract, err := s.UpdateContract(context.Background(), 1, input)
+	require.NoError(t, err)
+	assert.Nil(t, contract.ExpiryAlertedAt)
+
+	repo.contracts[0].Status = models.StatusCancelled
+	_, err = s.UpdateContract(context.Background(), 1, input)
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidContract))
+}
+
+func TestRunExpiryAlerts(t *testing.T) {
+	repo := &fakeContractRepo{contracts: []models.Contract{
+		{ID: 1, CompanyID: 1, ContractNo: "CT-1", Status: models.StatusActive, StartDate: day(2024, 1, 1),
+			EndDate: day(2024, 6, 30), AlertEmails: []string{"a@empresa.com", "b@empresa.com"}},
+		{ID: 2, CompanyID: 2, ContractNo: "CT-2", Status: models.StatusActive, StartDate: day(2024, 1, 1),
+			EndDate: day(2024, 6, 15)},
+		{ID: 3, CompanyID: 1, ContractNo: "CT-3", Status: models.StatusActive, StartDate: day(2024, 1, 1),
+			EndDate: day(2024, 8, 31)},
+	}}
+	s, sent := newTestService(repo, day(2024, 6, 1))
+
+	alerted, err := s.RunExpiryAlerts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, alerted)
+	assert.Equal(t, []int{1, 2}, repo.alerted)
+	require.Len(t, *sent, 2)
+	assert.Equal(t, "a@empresa.com", (*sent)[0].To)
+	assert.Equal(t, "Contrato CT-1 vence em 29 dia(s)", (*sent)[0].Subject)
+}
+
+func TestRunExpiryAlertsKeepsFailedContractPending(t *testing.T) {
+	repo := &fakeContractRepo{contracts: []models.Contract{
+		{ID: 1, ContractNo: "CT-1", Status: models.StatusActive, StartDate: day(2024, 1, 1),
+			EndDate: day(2024, 6, 30), AlertEmails: []string{"a@empresa.com"}},
+	}}
+	s, _ := newTestService(repo, day(2024, 6, 1))
+	s.send = func(mailer.Message) error { return stderrors.New("smtp indisponível") }
+
+	alerted, err := s.RunExpiryAlerts(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, alerted)
+	assert.Empty(t, repo.alerted)
+}
+
+func TestVolumesAndExpiringList(t *testing.T) {
+	repo := &fakeContractRepo{
+		contracts: []models.Contract{
+			{ID: 1, Status: models.StatusActive, StartDate: day(2024, 1, 1), EndDate: day(2024, 6, 20),
+				Items: []models.ContractItem{{ProductID: 10, UnitPrice: money.FromInt(5), MinQuantity: 100}}},
+			{ID: 2, Status: models.StatusActive, StartDate: day(2024, 1, 1), EndDate: day(2024, 12, 31)},
+		},
+		ordered: map[int]int{10: 40},
+	}
+	s, _ := newTestService(repo, day(2024, 6, 1))
+
+	volumes, err := s.Volumes(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, volumes, 1)
+	assert.Equal(t, 60, volumes[0].Remaining)
+
+	expiring, err := s.ListExpiring(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, 1, expiring[0].ID)
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/crm/models"
+	"ERP-ONSMART/backend/internal/modules/crm/repository"
+	"ERP-ONSMART/backend/internal/modules/crm/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Formato aceito pelos parâmetros de data do CRM
+const crmDateLayout = "2006-01-02"
+
+// Cadastra um novo lead
+func CreateLeadHandler(c *gin.Context) {
+	var lead models.Lead
+	if err := c.ShouldBindJSON(&lead); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.CreateLead(c.Request.Context(), &lead); err != nil {
+		c.Error(err).SetMeta("erro ao criar lead")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"lead": lead})
+}
+
+// Lista os leads (filtros opcionais ?status=&owner_id=)
+func ListLeadsHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	ownerID, ok := parseOptionalIntQuery(c, "owner_id")
+	if !ok {
+		return
+	}
+
+	filter := repository.LeadFilter{Status: c.Query("status"), OwnerID: ownerID}
+	result, err := service.GetLeads(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar leads")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna um lead pelo ID
+func GetLeadHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	lead, err := service.GetLead(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar lead")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lead": lead})
+}
+
+// Atualiza os dados e o status de um lead
+func UpdateLeadHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var lead models.Lead
+	if err := c.ShouldBindJSON(&lead); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	updated, err := service.UpdateLead(c.Request.Context(), id, &lead)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar lead")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lead": updated})
+}
+
+// Converte o lead em oportunidade no funil de vendas
+func ConvertLeadHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.OpportunityInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	opportunity, err := service.ConvertLead(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao converter lead")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"opportunity": opportunity})
+}
+
+// Cria uma oportunidade vinculada a um lead ou contato
+func CreateOpportunityHandler(c *gin.Context) {
+	var input service.OpportunityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	opportunity, err := service.CreateOpportunity(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar oportunidade")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"opportunity": opportunity})
+}
+
+// Lista as oportunidades (filtros opcionais ?stage=&owner_id=&close_from=&close_to=)
+func ListOpportunitiesHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	filter, ok := bindOpportunityFilter(c)
+	if !ok {
+		return
+	}
+
+	result, err := service.GetOpportunities(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar oportunidades")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna a oportunidade com os itens previstos
+func GetOpportunityHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	opportunity, err := service.GetOpportunity(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar oportunidade")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"opportunity": opportunity})
+}
+
+// Atualiza os dados de uma oportunidade aberta
+func UpdateOpportunityHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.OpportunityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	opportunity, err := service.UpdateOpportunity(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar oportunidade")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"opportunity": opportunity})
+}
+
+// Move a oportunidade para outro estágio do funil
+func MoveOpportunityHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.StageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	opportunity, err := service.MoveOpportunity(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao mover oportunidade")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"opportunity": opportunity})
+}
+
+// Converte a oportunidade ganha em cotação, cadastrando o contato a partir do lead se necessário
+func ConvertOpportunityHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ExpiryDate time.Time `json:"expiry_date"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	quotation, err := service.ConvertToQuotation(c.Request.Context(), id, req.ExpiryDate)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao converter oportunidade em cotação")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"quotation": quotation})
+}
+
+// Retorna a previsão de vendas ponderada pela probabilidade das oportunidades abertas
+func GetForecastHandler(c *gin.Context) {
+	filter, ok := bindOpportunityFilter(c)
+	if !ok {
+		return
+	}
+
+	forecast, err := service.GetForecast(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao calcular previsão de vendas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"forecast": forecast})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+func parseOptionalIntQuery(c *gin.Context, name string) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		c.Error(errors.InvalidParam(name + " inválido"))
+		return 0, false
+	}
+	return id, true
+}
+
+// bindOpportunityFilter lê os filtros de estágio, responsável e período previsto de fechamento
+func bindOpportunityFilter(c *gin.Context) (repository.OpportunityFilter, bool) {
+	filter := repository.OpportunityFilter{Stage: c.Query("stage")}
+
+	ownerID, ok := parseOptionalIntQuery(c, "owner_id")
+	if !ok {
+		return filter, false
+	}
+	filter.OwnerID = ownerID
+
+	for name, target := range map[string]*time.Time{"close_from": &filter.CloseFrom, "close_to": &filter.CloseTo} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(crmDateLayout, value)
+		if err != nil {
+			c.Error(errors.InvalidParam(name + " inválido, use o formato AAAA-MM-DD"))
+			return filter, false
+		}
+		*target = date
+	}
+	return filter, true
+}
@@ -0,0 +1,261 @@
+package models
+
+import (
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status do lead
+const (
+	LeadStatusNew          = "new"
+	LeadStatusContacted    = "contacted"
+	LeadStatusQualified    = "qualified"
+	LeadStatusDisqualified = "disqualified"
+	LeadStatusConverted    = "converted"
+)
+
+// Estágios do funil de oportunidades
+const (
+	StageProspecting   = "prospecting"
+	StageQualification = "qualification"
+	StageProposal      = "proposal"
+	StageNegotiation   = "negotiation"
+	StageWon           = "won"
+	StageLost          = "lost"
+)
+
+// UnscheduledPeriod agrupa na previsão as oportunidades sem data prevista de fechamento
+const UnscheduledPeriod = "unscheduled"
+
+// stageProbability é a probabilidade padrão de fechamento de cada estágio do funil
+var stageProbability = map[string]int{
+	StageProspecting:   10,
+	StageQualification: 25,
+	StageProposal:      50,
+	StageNegotiation:   75,
+	StageWon:           100,
+	StageLost:          0,
+}
+
+// Lead é um potencial cliente ainda não cadastrado como contato
+type Lead struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	CompanyID   int       `json:"company_id" gorm:"<-:create"`
+	Name        string    `json:"name" binding:"required"`
+	CompanyName string    `json:"company_name"`
+	Email       string    `json:"email" binding:"required,email"`
+	Phone       string    `json:"phone"`
+	Document    string    `json:"document"`
+	Source      string    `json:"source"`
+	Status      string    `json:"status" gorm:"default:new" binding:"omitempty,oneof=new contacted qualified disqualified"`
+	OwnerID     *int      `json:"owner_id,omitempty"`
+	ContactID   *int      `json:"contact_id,omitempty"`
+	Notes       string    `json:"notes"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de leads
+func (Lead) TableName() string {
+	return "crm_leads"
+}
+
+// Opportunity é uma negociação em andamento no funil de vendas
+type Opportunity struct {
+	ID                int               `json:"id" gorm:"primaryKey"`
+	CompanyID         int               `json:"company_id" gorm:"<-:create"`
+	Title             string            `json:"title"`
+	LeadID            *int              `json:"lead_id,omitempty"`
+	ContactID         *int              `json:"contact_id,omitempty"`
+	OwnerID           *int              `json:"owner_id,omitempty"`
+	Stage             string            `json:"stage"`
+	Amount            money.Decimal     `json:"amount"`
+	Probability       int               `json:"probability"`
+	ExpectedCloseDate *time.Time        `json:"expected_close_date,omitempty"`
+	QuotationID       *int              `json:"quotation_id,omitempty"`
+	LostReason        string            `json:"lost_reason,omitempty"`
+	ClosedAt          *time.Time        `json:"closed_at,omitempty"`
+	Notes             string            `json:"notes"`
+	CreatedAt         time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+	Items             []OpportunityItem `json:"items,omitempty" gorm:"foreignKey:OpportunityID"`
+	Lead              *Lead             `json:"lead,omitempty" gorm:"foreignKey:LeadID"`
+}
+
+// TableName define o nome da tabela de oportunidades
+func (Opportunity) TableName() string {
+	return "crm_opportunities"
+}
+
+// OpportunityItem é um produto previsto na oportunidade
+type OpportunityItem struct {
+	ID            int           `json:"id" gorm:"primaryKey"`
+	OpportunityID int           `json:"opportunity_id"`
+	ProductID     int           `json:"product_id" binding:"required"`
+	Quantity      int           `json:"quantity" binding:"required,gt=0"`
+	UnitPrice     money.Decimal `json:"unit_price" binding:"gte=0"`
+	Discount      money.Decimal `json:"discount" binding:"gte=0"`
+}
+
+// TableName define o nome da tabela de itens da oportunidade
+func (OpportunityItem) TableName() string {
+	return "crm_opportunity_items"
+}
+
+// ProductInfo traz os dados do produto copiados para os itens da cotação
+type ProductInfo struct {
+	ID   int
+	Name string
+	SKU  string
+}
+
+// ForecastTotals acumula a quantidade, o valor e o valor ponderado das oportunidades
+type ForecastTotals struct {
+	Count          int           `json:"count"`
+	Amount         money.Decimal `json:"amount"`
+	WeightedAmount money.Decimal `json:"weighted_amount"`
+}
+
+// ForecastPeriod resume as oportunidades abertas com fechamento previsto no mês
+type ForecastPeriod struct {
+	Period string `json:"period"`
+	ForecastTotals
+}
+
+// Forecast é a previsão de vendas ponderada pela probabilidade de fechamento
+type Forecast struct {
+	ForecastTotals
+	ByPeriod []ForecastPeriod          `json:"by_period"`
+	ByStage  map[string]ForecastTotals `json:"by_stage"`
+}
+
+// IsValidStage indica se o estágio pertence ao funil
+func IsValidStage(stage string) bool {
+	_, ok := stageProbability[stage]
+	return ok
+}
+
+// IsClosedStage indica se o estágio encerra a oportunidade
+func IsClosedStage(stage string) bool {
+	return stage == StageWon || stage == StageLost
+}
+
+// DefaultProbability retorna a probabilidade padrão de fechamento do estágio
+func DefaultProbability(stage string) int {
+	return stageProbability[stage]
+}
+
+// TotalAmount soma o valor dos itens previstos (quantidade x preço - desconto)
+func (o *Opportunity) TotalAmount() money.Decimal {
+	var totals sales.DocumentTotals
+	for _, item := range o.Items {
+		totals.Add(item.Quantity, item.UnitPrice, item.Discount, money.Zero)
+	}
+	return totals.GrandTotal
+}
+
+// BuildForecast calcula a previsão ponderada das oportunidades abertas, por mês de fechamento e por estágio
+func BuildForecast(opportunities []Opportunity) *Forecast {
+	forecast := &Forecast{ByStage: make(map[string]ForecastTotals)}
+	byPeriod := make(map[string]*ForecastPeriod)
+
+	for _, opportunity := range opportunities {
+		if IsClosedStage(opportunity.Stage) {
+			continue
+		}
+		weighted := money.Round(opportunity.Amount.MulInt(opportunity.Probability).DivInt(100))
+
+		period := UnscheduledPeriod
+		if opportunity.ExpectedCloseDate != nil {
+			period = opportunity.ExpectedCloseDate.Format("2006-01")
+		}
+		entry, ok := byPeriod[period]
+		if !ok {
+			entry = &ForecastPeriod{Period: period}
+			byPeriod[period] = entry
+		}
+		entry.add(opportunity.Amount, weighted)
+
+		stage := forecast.ByStage[opportunity.Stage]
+		stage.add(opportunity.Amount, weighted)
+		forecast.ByStage[opportunity.Stage] = stage
+
+		forecast.add(opportunity.Amount, weighted)
+	}
+
+	forecast.ByPeriod = make([]ForecastPeriod, 0, len(byPeriod))
+	for _, entry := range byPeriod {
+		forecast.ByPeriod = append(forecast.ByPeriod, *entry)
+	}
+	// Meses em ordem cronológica; oportunidades sem data ficam por último
+	sort.Slice(forecast.ByPeriod, func(i, j int) bool {
+		a, b := forecast.ByPeriod[i].Period, forecast.ByPeriod[j].Period
+		if a == UnscheduledPeriod || b == UnscheduledPeriod {
+			return b == UnscheduledPeriod && a != UnscheduledPeriod
+		}
+		return a < b
+	})
+
+	return forecast
+}
+
+func (t *ForecastTotals) add(amount, weighted money.Decimal) {
+	t.Count++
+	t.Amount = t.Amount.Add(amount)
+	t.WeightedAmount = t.WeightedAmount.Add(weighted)
+}
+
+// ContactFromLead monta o cadastro do contato (cliente) a partir dos dados do lead
+func ContactFromLead(lead *Lead) *contact.Contact {
+	personType := "pf"
+	if strings.TrimSpace(lead.CompanyName) != "" {
+		personType = "pj"
+	}
+
+	return &contact.Contact{
+		PersonType:  personType,
+		Type:        "cliente",
+		Name:        lead.Name,
+		CompanyName: lead.CompanyName,
+		Document:    lead.Document,
+		Email:       lead.Email,
+		Phone:       lead.Phone,
+	}
+}
+
+// QuotationFromOpportunity monta a cotação a partir dos itens previstos da oportunidade ganha
+func QuotationFromOpportunity(opportunity *Opportunity, contactID int, products map[int]ProductInfo, expiryDate time.Time) *sales.Quotation {
+	quotation := &sales.Quotation{
+		ContactID:     contactID,
+		SalespersonID: opportunity.OwnerID,
+		Status:        sales.QuotationStatusDraft,
+		ExpiryDate:    expiryDate,
+		Notes:         opportunity.Title,
+	}
+
+	var totals sales.DocumentTotals
+	for _, item := range opportunity.Items {
+		product := products[item.ProductID]
+		unitPrice, discount := item.UnitPrice, item.Discount
+		quotation.Items = append(quotation.Items, sales.QuotationItem{
+			ProductID:   item.ProductID,
+			ProductName: product.Name,
+			ProductCode: product.SKU,
+			Quantity:    item.Quantity,
+			UnitPrice:   unitPrice,
+			Discount:    discount,
+			Total:       sales.LineTotal(item.Quantity, unitPrice, discount, money.Zero),
+		})
+		totals.Add(item.Quantity, unitPrice, discount, money.Zero)
+	}
+
+	quotation.SubTotal = totals.SubTotal
+	quotation.DiscountTotal = totals.DiscountTotal
+	quotation.TaxTotal = totals.TaxTotal
+	quotation.GrandTotal = totals.GrandTotal
+	return quotation
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func datePtr(year int, month time.Month, day int) *time.Time {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
+func TestStages(t *testing.T) {
+	assert.True(t, IsValidStage(StageProposal))
+	assert.False(t, IsValidStage("closed"))
+	assert.True(t, IsClosedStage(StageWon))
+	assert.True(t, IsClosedStage(StageLost))
+	assert.False(t, IsClosedStage(StageNegotiation))
+	assert.Equal(t, 50, DefaultProbability(StageProposal))
+	assert.Equal(t, 100, DefaultProbability(StageWon))
+}
+
+func TestTotalAmount(t *testing.T) {
+	opportunity := Opportunity{Items: []OpportunityItem{
+		{ProductID: 1, Quantity: 2, UnitPrice: money.MustParse("150.5")},
+		{ProductID: 2, Quantity: 1, UnitPrice: money.FromInt(100), Discount: money.FromInt(10)},
+	}}
+	assert.Equal(t, "391.00", opportunity.TotalAmount().String())
+}
+
+func TestBuildForecast(t *testing.T) {
+	opportunities := []Opportunity{
+		{Stage: StageProposal, Amount: money.FromInt(1000), Probability: 50, ExpectedCloseDate: datePtr(2024, 3, 10)},
+		{Stage: StageNegotiation, Amount: money.FromInt(2000), Probability: 75, ExpectedCloseDate: datePtr(2024, 3, 25)},
+		{Stage: StageProspecting, Amount: money.FromInt(500), Probability: 10, ExpectedCloseDate: datePtr(2024, 2, 1)},
+		{Stage: StageProspecting, Amount: money.FromInt(300), Probability: 10},
+		// Oportunidades encerradas não entram na previsão
+		{Stage: StageWon, Amount: money.FromInt(9000), Probability: 100, ExpectedCloseDate: datePtr(2024, 3, 1)},
+		{Stage: StageLost, Amount: money.FromInt(4000), Probability: 0},
+	}
+
+	forecast := BuildForecast(opportunities)
+	assert.Equal(t, 4, forecast.Count)
+	assert.Equal(t, "3800.00", forecast.Amount.String())
+	assert.Equal(t, "2080.00", forecast.WeightedAmount.String())
+
+	require.Len(t, forecast.ByPeriod, 3)
+	assert.Equal(t, "2024-02", forecast.ByPeriod[0].Period)
+	assert.Equal(t, "50.00", forecast.ByPeriod[0].WeightedAmount.String())
+	assert.Equal(t, "2024-03", forecast.ByPeriod[1].Period)
+	assert.Equal(t, 2, forecast.ByPeriod[1].Count)
+	assert.Equal(t, "2000.00", forecast.ByPeriod[1].WeightedAmount.String())
+	assert.Equal(t, UnscheduledPeriod, forecast.ByPeriod[2].Period)
+
+	assert.Equal(t, 2, forecast.ByStage[StageProspecting].Count)
+	assert.Equal(t, "80.00", forecast.ByStage[StageProspecting].WeightedAmount.String())
+	_, ok := forecast.ByStage[StageWon]
+	assert.False(t, ok)
+}
+
+func TestContactFromLead(t *testing.T) {
+	person := ContactFromLead(&Lead{Name: "Maria", Email: "maria@example.com", Document: "12345678900"})
+	assert.Equal(t, "pf", person.PersonType)
+	assert.Equal(t, "cliente", person.Type)
+	assert.Equal(t, "12345678900", person.Document)
+
+	company := ContactFromLead(&Lead{Name: "João", CompanyName: "ACME", Email: "joao@acme.com", Document: "12345678000190"})
+	assert.Equal(t, "pj", company.PersonType)
+	assert.Equal(t, "ACME", company.CompanyName)
+}
+
+func TestQuotationFromOpportunity(t *testing.T) {
+	ownerID := 4
+	opportunity := &Opportunity{
+		Title:   "Renovação de licenças",
+		OwnerID: &ownerID,
+		Items: []OpportunityItem{
+			{ProductID: 1, Quantity: 3, UnitPrice: money.FromInt(100), Discount: money.FromInt(30)},
+			{ProductID: 2, Quantity: 1, UnitPrice: money.FromInt(50)},
+		},
+	}
+	products := map[int]ProductInfo{
+		1: {ID: 1, Name: "Licença", SKU: "LIC-1"},
+		2: {ID: 2, Name: "Suporte", SKU: "SUP-1"},
+	}
+	expiry := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+
+	quotation := QuotationFromOpportunity(opportunity, 9, products, expiry)
+	assert.Equal(t, 9, quotation.ContactID)
+	assert.Equal(t, &ownerID, quotation.SalespersonID)
+	assert.Equal(t, sales.QuotationStatusDraft, quotation.Status)
+	assert.Equal(t, expiry, quotation.ExpiryDate)
+	assert.Equal(t, "350.00", quotation.SubTotal.String())
+	assert.Equal(t, "30.00", quotation.DiscountTotal.String())
+	assert.Equal(t, "320.00", quotation.GrandTotal.String())
+
+	require.Len(t, quotation.Items, 2)
+	assert.Equal(t, "Licença", quotation.Items[0].ProductName)
+	assert.Equal(t, "LIC-1", quotation.Items[0].ProductCode)
+	assert.Equal(t, "270.00", quotation.Items[0].Total.String())
+}
@@ -0,0 +1,594 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
+	"ERP-ONSMART/backend/internal/modules/crm/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeadFilter define os filtros da listagem de leads
+type LeadFilter struct {
+	Status  string
+	OwnerID int
+}
+
+// OpportunityFilter define os filtros da listagem de oportunidades e da previsão de vendas
+type OpportunityFilter struct {
+	Stage     string
+	OwnerID   int
+	CloseFrom time.Time
+	CloseTo   time.Time
+}
+
+// CRMRepository define as operações de leads e oportunidades do funil de vendas
+type CRMRepository interface {
+	CreateLead(ctx context.Context, lead *models.Lead) error
+	GetLeads(ctx context.Context, filter LeadFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetLeadByID(ctx context.Context, id int) (*models.Lead, error)
+	UpdateLead(ctx context.Context, id int, lead *models.Lead) (*models.Lead, error)
+	ConvertLead(ctx context.Context, id int, opportunity *models.Opportunity) (*models.Opportunity, error)
+
+	CreateOpportunity(ctx context.Context, opportunity *models.Opportunity) error
+	GetOpportunities(ctx context.Context, filter OpportunityFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetOpportunityByID(ctx context.Context, id int) (*models.Opportunity, error)
+	UpdateOpportunity(ctx context.Context, id int, opportunity *models.Opportunity) (*models.Opportunity, error)
+	ChangeStage(ctx context.Context, id int, stage string, probability *int, lostReason string) (*models.Opportunity, error)
+	GetOpenOpportunities(ctx context.Context, filter OpportunityFilter) ([]models.Opportunity, error)
+	ConvertToQuotation(ctx context.Context, id int, expiryDate time.Time) (*sales.Quotation, error)
+}
+
+type crmRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCRMRepository cria uma nova instância do repositório
+func NewCRMRepository() (CRMRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &crmRepository{
+		db:     db,
+		logger: logger.WithModule("crm_repository"),
+	}, nil
+}
+
+// CreateLead cadastra um novo lead
+func (r *crmRepository) CreateLead(ctx context.Context, lead *models.Lead) error {
+	if err := db.Conn(ctx, r.db).Create(lead).Error; err != nil {
+		r.logger.Error("erro ao criar lead", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar lead")
+	}
+
+	r.logger.Info("lead criado", zap.Int("id", lead.ID), zap.String("name", lead.Name))
+	return nil
+}
+
+// GetLeads lista os leads com filtros por status e responsável
+func (r *crmRepository) GetLeads(ctx context.Context, filter LeadFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := db.Conn(ctx, r.db).Model(&models.Lead{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.OwnerID > 0 {
+		query = query.Where("owner_id = ?", filter.OwnerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar leads", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar leads")
+	}
+
+	var leads []models.Lead
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("created_at DESC").Offset(offset).Limit(params.PageSize).Find(&leads).Error; err != nil {
+		r.logger.Error("erro ao listar leads", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar leads")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, leads), nil
+}
+
+// GetLeadByID busca um lead pelo ID
+func (r *crmRepository) GetLeadByID(ctx context.Context, id int) (*models.Lead, error) {
+	var lead models.Lead
+	if err := db.Conn(ctx, r.db).First(&lead, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrLeadNotFound
+		}
+		r.logger.Error("erro ao buscar lead", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar lead")
+	}
+	return &lead, nil
+}
+
+// UpdateLead atualiza os dados e o status de um lead ainda não convertido
+func (r *crmRepository) UpdateLead(ctx context.Context, id int, lead *models.Lead) (*models.Lead, error) {
+	existing, err := r.GetLeadByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status == models.LeadStatusConverted {
+		return nil, errors.ErrLeadAlreadyConverted
+	}
+
+	updates := map[string]interface{}{
+		"name":         lead.Name,
+		"company_name": lead.CompanyName,
+		"email":        lead.Email,
+		"phone":        lead.Phone,
+		"document":     lead.Document,
+		"source":       lead.Source,
+		"owner_id":     lead.OwnerID,
+		"notes":        lead.Notes,
+	}
+	if lead.Status != "" {
+		updates["status"] = lead.Status
+	}
+	if err := db.Conn(ctx, r.db).Model(existing).Updates(updates).Error; err != nil {
+		r.logger.Error("erro ao atualizar lead", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar lead")
+	}
+
+	return r.GetLeadByID(ctx, id)
+}
+
+// ConvertLead gera a oportunidade a partir do lead e marca o lead como convertido
+func (r *crmRepository) ConvertLead(ctx context.Context, id int, opportunity *models.Opportunity) (*models.Opportunity, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var lead models.Lead
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lead, id).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrLeadNotFound
+		}
+		r.logger.Error("erro ao buscar lead", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar lead")
+	}
+	if lead.Status == models.LeadStatusConverted {
+		tx.Rollback()
+		return nil, errors.ErrLeadAlreadyConverted
+	}
+
+	opportunity.LeadID = &lead.ID
+	opportunity.ContactID = lead.ContactID
+	if opportunity.OwnerID == nil {
+		opportunity.OwnerID = lead.OwnerID
+	}
+	if opportunity.Title == "" {
+		opportunity.Title = lead.Name
+		if lead.CompanyName != "" {
+			opportunity.Title = lead.CompanyName
+		}
+	}
+
+	if err := tx.Create(opportunity).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar oportunidade do lead", zap.Error(err), zap.Int("lead_id", id))
+		return nil, errors.WrapError(err, "falha ao criar oportunidade")
+	}
+	if err := tx.Model(&lead).Update("status", models.LeadStatusConverted).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao converter lead", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar status do lead")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("lead convertido em oportunidade", zap.Int("lead_id", id), zap.Int("opportunity_id", opportunity.ID))
+	return opportunity, nil
+}
+
+// CreateOpportunity cria uma oportunidade com os itens previstos
+func (r *crmRepository) CreateOpportunity(ctx context.Context, opportunity *models.Opportunity) error {
+	if opportunity.LeadID != nil {
+		if _, err := r.GetLeadByID(ctx, *opportunity.LeadID); err != nil {
+			return err
+		}
+	}
+
+	if err := db.Conn(ctx, r.db).Create(opportunity).Error; err != nil {
+		r.logger.Error("erro ao criar oportunidade", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar oportunidade")
+	}
+
+	r.logger.Info("oportunidade criada", zap.Int("id", opportunity.ID), zap.String("stage", opportunity.Stage))
+	return nil
+}
+
+// GetOpportunities lista as oportunidades do funil
+func (r *crmRepository) GetOpportunities(ctx context.Context, filter OpportunityFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := r.filterOpportunities(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar oportunidades", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar oportunidades")
+	}
+
+	var opportunities []models.Opportunity
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("expected_close_date ASC NULLS LAST, id DESC").
+		Offset(offset).
+		Limit(params.PageSize).
+		Find(&opportunities).Error; err != nil {
+		r.logger.Error("erro ao listar oportunidades", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar oportunidades")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, opportunities), nil
+}
+
+// GetOpportunityByID busca a oportunidade com os itens e o lead de origem
+func (r *crmRepository) GetOpportunityByID(ctx context.Context, id int) (*models.Opportunity, error) {
+	var opportunity models.Opportunity
+	if err := db.Conn(ctx, r.db).Preload("Items").Preload("Lead").First(&opportunity, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrOpportunityNotFound
+		}
+		r.logger.Error("erro ao buscar oportunidade", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar oportunidade")
+	}
+	return &opportunity, nil
+}
+
+// UpdateOpportunity atualiza os dados de uma oportunidade aberta; os itens informados substituem os atuais
+func (r *crmRepository) UpdateOpportunity(ctx context.Context, id int, opportunity *models.Opportunity) (*models.Opportunity, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	existing, err := r.lockOpportunity(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if models.IsClosedStage(existing.Stage) {
+		tx.Rollback()
+		return nil, errors.ErrOpportunityClosed
+	}
+
+	updates := map[string]interface{}{
+		"title":               opportunity.Title,
+		"contact_id":          opportunity.ContactID,
+		"owner_id":            opportunity.OwnerID,
+		"amount":              opportunity.Amount,
+		"expected_close_date": opportunity.ExpectedCloseDate,
+		"notes":               opportunity.Notes,
+	}
+	if opportunity.ContactID == nil && existing.LeadID == nil {
+		// A oportunidade precisa manter um lead ou um contato vinculado
+		updates["contact_id"] = existing.ContactID
+	}
+	if err := tx.Model(existing).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao atualizar oportunidade", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar oportunidade")
+	}
+
+	if opportunity.Items != nil {
+		if err := tx.Where("opportunity_id = ?", id).Delete(&models.OpportunityItem{}).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao remover itens da oportunidade", zap.Error(err), zap.Int("id", id))
+			return nil, errors.WrapError(err, "falha ao atualizar itens da oportunidade")
+		}
+		for i := range opportunity.Items {
+			opportunity.Items[i].ID = 0
+			opportunity.Items[i].OpportunityID = id
+		}
+		if len(opportunity.Items) > 0 {
+			if err := tx.Create(&opportunity.Items).Error; err != nil {
+				tx.Rollback()
+				r.logger.Error("erro ao criar itens da oportunidade", zap.Error(err), zap.Int("id", id))
+				return nil, errors.WrapError(err, "falha ao atualizar itens da oportunidade")
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	return r.GetOpportunityByID(ctx, id)
+}
+
+// ChangeStage move a oportunidade no funil; ganhas e perdidas ficam encerradas
+func (r *crmRepository) ChangeStage(ctx context.Context, id int, stage string, probability *int, lostReason string) (*models.Opportunity, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	existing, err := r.lockOpportunity(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if models.IsClosedStage(existing.Stage) {
+		tx.Rollback()
+		return nil, errors.ErrOpportunityClosed
+	}
+
+	updates := map[string]interface{}{
+		"stage":       stage,
+		"probability": models.DefaultProbability(stage),
+	}
+	if probability != nil && !models.IsClosedStage(stage) {
+		updates["probability"] = *probability
+	}
+	if models.IsClosedStage(stage) {
+		updates["closed_at"] = time.Now()
+	}
+	if stage == models.StageLost {
+		updates["lost_reason"] = lostReason
+	}
+
+	if err := tx.Model(existing).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao mover oportunidade no funil", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar estágio da oportunidade")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("oportunidade movida no funil",
+		zap.Int("id", id),
+		zap.String("from", existing.Stage),
+		zap.String("to", stage))
+	return r.GetOpportunityByID(ctx, id)
+}
+
+// GetOpenOpportunities retorna as oportunidades abertas usadas na previsão de vendas
+func (r *crmRepository) GetOpenOpportunities(ctx context.Context, filter OpportunityFilter) ([]models.Opportunity, error) {
+	var opportunities []models.Opportunity
+	if err := r.filterOpportunities(ctx, filter).
+		Where("stage NOT IN ?", []string{models.StageWon, models.StageLost}).
+		Find(&opportunities).Error; err != nil {
+		r.logger.Error("erro ao buscar oportunidades abertas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar oportunidades abertas")
+	}
+	return opportunities, nil
+}
+
+// ConvertToQuotation gera a cotação da oportunidade ganha, cadastrando o contato a partir do lead
+// quando necessário e iniciando o processo de venda
+func (r *crmRepository) ConvertToQuotation(ctx context.Context, id int, expiryDate time.Time) (*sales.Quotation, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	opportunity, err := r.lockOpportunity(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if opportunity.Stage != models.StageWon {
+		tx.Rollback()
+		return nil, errors.ErrOpportunityNotWon
+	}
+	if opportunity.QuotationID != nil {
+		tx.Rollback()
+		return nil, errors.ErrOpportunityAlreadyConverted
+	}
+
+	if err := tx.Where("opportunity_id = ?", id).Order("id ASC").Find(&opportunity.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar itens da oportunidade", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar itens da oportunidade")
+	}
+	if len(opportunity.Items) == 0 {
+		tx.Rollback()
+		return nil, errors.ErrEmptyOpportunity
+	}
+
+	contactID, err := r.resolveContact(tx, opportunity)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := contactRepository.EnsureNotBlocked(tx, contactID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	products, err := r.productInfo(tx, opportunity.Items)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	quotation := models.QuotationFromOpportunity(opportunity, contactID, products, expiryDate)
+	quotation.QuotationNo = nextQuotationNumber(tx)
+	if quotation.Items, err = salesRepository.PrepareQuotationItems(tx, quotation.Items); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := salesRepository.ApplyQuotationContract(tx, quotation, time.Now()); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Omit(clause.Associations).Create(quotation).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar cotação da oportunidade", zap.Error(err), zap.Int("opportunity_id", id))
+		return nil, errors.WrapError(err, "falha ao criar cotação")
+	}
+	for i := range quotation.Items {
+		quotation.Items[i].QuotationID = quotation.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&quotation.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar itens da cotação", zap.Error(err), zap.Int("quotation_id", quotation.ID))
+		return nil, errors.WrapError(err, "falha ao criar itens da cotação")
+	}
+
+	process := sales.SalesProcess{
+		ContactID:  contactID,
+		Status:     salesRepository.ProcessStatusQuotation,
+		TotalValue: quotation.GrandTotal,
+		Notes:      fmt.Sprintf("Oportunidade #%d: %s", opportunity.ID, opportunity.Title),
+	}
+	if err := tx.Omit(clause.Associations).Create(&process).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar processo de venda", zap.Error(err), zap.Int("opportunity_id", id))
+		return nil, errors.WrapError(err, "falha ao criar processo de venda")
+	}
+	if err := tx.Exec("INSERT INTO process_quotations (process_id, quotation_id) VALUES (?, ?)", process.ID, quotation.ID).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao vincular cotação ao processo", zap.Error(err), zap.Int("process_id", process.ID))
+		return nil, errors.WrapError(err, "falha ao vincular documento ao processo de venda")
+	}
+
+	if err := tx.Model(opportunity).Updates(map[string]interface{}{
+		"quotation_id": quotation.ID,
+		"contact_id":   contactID,
+	}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao vincular cotação à oportunidade", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar oportunidade")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("oportunidade convertida em cotação",
+		zap.Int("opportunity_id", id),
+		zap.Int("quotation_id", quotation.ID),
+		zap.String("quotation_no", quotation.QuotationNo))
+	return quotation, nil
+}
+
+// resolveContact retorna o contato da oportunidade; sem contato, reaproveita o cadastro com o mesmo
+// documento do lead ou cadastra um novo cliente a partir dele
+func (r *crmRepository) resolveContact(tx *gorm.DB, opportunity *models.Opportunity) (int, error) {
+	if opportunity.ContactID != nil {
+		return *opportunity.ContactID, nil
+	}
+	if opportunity.LeadID == nil {
+		return 0, errors.ErrLeadNotFound
+	}
+
+	var lead models.Lead
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lead, *opportunity.LeadID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, errors.ErrLeadNotFound
+		}
+		r.logger.Error("erro ao buscar lead", zap.Error(err), zap.Int("id", *opportunity.LeadID))
+		return 0, errors.WrapError(err, "falha ao buscar lead")
+	}
+	if lead.ContactID != nil {
+		return *lead.ContactID, nil
+	}
+	if lead.Document == "" {
+		return 0, errors.ErrLeadIncomplete
+	}
+
+	var contactIDs []int
+	if err := tx.Model(&contact.Contact{}).
+		Where("document = ?", lead.Document).
+		Order("id ASC").
+		Limit(1).
+		Pluck("id", &contactIDs).Error; err != nil {
+		r.logger.Error("erro ao buscar contato do lead", zap.Error(err), zap.Int("lead_id", lead.ID))
+		return 0, errors.WrapError(err, "falha ao buscar contato")
+	}
+
+	contactID := 0
+	if len(contactIDs) > 0 {
+		contactID = contactIDs[0]
+	} else {
+		newContact := models.ContactFromLead(&lead)
+		if err := tx.Create(newContact).Error; err != nil {
+			r.logger.Error("erro ao cadastrar contato do lead", zap.Error(err), zap.Int("lead_id", lead.ID))
+			return 0, errors.WrapError(err, "falha ao cadastrar contato")
+		}
+		contactID = newContact.ID
+		r.logger.Info("contato cadastrado a partir do lead", zap.Int("lead_id", lead.ID), zap.Int("contact_id", contactID))
+	}
+
+	if err := tx.Model(&lead).Update("contact_id", contactID).Error; err != nil {
+		r.logger.Error("erro ao vincular contato ao lead", zap.Error(err), zap.Int("lead_id", lead.ID))
+		return 0, errors.WrapError(err, "falha ao atualizar lead")
+	}
+	return contactID, nil
+}
+
+// productInfo carrega nome e código dos produtos dos itens da oportunidade
+func (r *crmRepository) productInfo(tx *gorm.DB, items []models.OpportunityItem) (map[int]models.ProductInfo, error) {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	var rows []models.ProductInfo
+	if err := tx.Table("products").Select("id, name, sku").Where("id IN ?", ids).Scan(&rows).Error; err != nil {
+		r.logger.Error("erro ao buscar produtos da oportunidade", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar produtos")
+	}
+
+	products := make(map[int]models.ProductInfo, len(rows))
+	for _, row := range rows {
+		products[row.ID] = row
+	}
+	for _, id := range ids {
+		if _, ok := products[id]; !ok {
+			return nil, errors.ErrProductNotFound
+		}
+	}
+	return products, nil
+}
+
+func (r *crmRepository) lockOpportunity(tx *gorm.DB, id int) (*models.Opportunity, error) {
+	var opportunity models.Opportunity
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&opportunity, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrOpportunityNotFound
+		}
+		r.logger.Error("erro ao buscar oportunidade", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar oportunidade")
+	}
+	return &opportunity, nil
+}
+
+func (r *crmRepository) filterOpportunities(ctx context.Context, filter OpportunityFilter) *gorm.DB {
+	query := db.Conn(ctx, r.db).Model(&models.Opportunity{})
+	if filter.Stage != "" {
+		query = query.Where("stage = ?", filter.Stage)
+	}
+	if filter.OwnerID > 0 {
+		query = query.Where("owner_id = ?", filter.OwnerID)
+	}
+	if !filter.CloseFrom.IsZero() {
+		query = query.Where("expected_close_date >= ?", filter.CloseFrom)
+	}
+	if !filter.CloseTo.IsZero() {
+		query = query.Where("expected_close_date <= ?", filter.CloseTo)
+	}
+	return query
+}
+
+// nextQuotationNumber gera o próximo número de cotação dentro da transação (QT-ANO-SEQUÊNCIA)
+func nextQuotationNumber(tx *gorm.DB) string {
+	var lastID int
+	tx.Model(&sales.Quotation{}).Select("COALESCE(MAX(id), 0)").Scan(&lastID)
+	return fmt.Sprintf("QT-%d-%06d", time.Now().Year(), lastID+1)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/crm/models"
+	"ERP-ONSMART/backend/internal/modules/crm/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"time"
+)
+
+// defaultQuotationValidity é a validade da cotação gerada quando não informada
+const defaultQuotationValidity = 30 * 24 * time.Hour
+
+// OpportunityInput reúne os dados de criação e atualização de uma oportunidade
+type OpportunityInput struct {
+	Title             string                   `json:"title" binding:"required"`
+	LeadID            *int                     `json:"lead_id"`
+	ContactID         *int                     `json:"contact_id"`
+	OwnerID           *int                     `json:"owner_id"`
+	Stage             string                   `json:"stage"`
+	Probability       *int                     `json:"probability" binding:"omitempty,gte=0,lte=100"`
+	Amount            money.Decimal            `json:"amount" binding:"gte=0"`
+	ExpectedCloseDate *time.Time               `json:"expected_close_date"`
+	Notes             string                   `json:"notes"`
+	Items             []models.OpportunityItem `json:"items" binding:"dive"`
+}
+
+// StageInput move a oportunidade no funil
+type StageInput struct {
+	Stage       string `json:"stage" binding:"required"`
+	Probability *int   `json:"probability" binding:"omitempty,gte=0,lte=100"`
+	LostReason  string `json:"lost_reason"`
+}
+
+// toOpportunity converte a entrada na oportunidade; com itens, o valor é a soma dos itens
+func (in OpportunityInput) toOpportunity() (*models.Opportunity, error) {
+	stage := in.Stage
+	if stage == "" {
+		stage = models.StageProspecting
+	}
+	if !models.IsValidStage(stage) || models.IsClosedStage(stage) {
+		return nil, errors.ErrInvalidOpportunityStage
+	}
+
+	opportunity := &models.Opportunity{
+		Title:             in.Title,
+		LeadID:            in.LeadID,
+		ContactID:         in.ContactID,
+		OwnerID:           in.OwnerID,
+		Stage:             stage,
+		Probability:       models.DefaultProbability(stage),
+		Amount:            in.Amount,
+		ExpectedCloseDate: in.ExpectedCloseDate,
+		Notes:             in.Notes,
+		Items:             in.Items,
+	}
+	if in.Probability != nil {
+		opportunity.Probability = *in.Probability
+	}
+	if len(in.Items) > 0 {
+		opportunity.Amount = opportunity.TotalAmount()
+	}
+	return opportunity, nil
+}
+
+// CreateLead cadastra um novo lead
+func CreateLead(ctx context.Context, lead *models.Lead) error {
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return err
+	}
+
+	lead.ID = 0
+	lead.ContactID = nil
+	if lead.Status == "" {
+		lead.Status = models.LeadStatusNew
+	}
+	return repo.CreateLead(ctx, lead)
+}
+
+// GetLeads lista os leads com filtros por status e responsável
+func GetLeads(ctx context.Context, filter repository.LeadFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetLeads(ctx, filter, params)
+}
+
+// GetLead retorna um lead pelo ID
+func GetLead(ctx context.Context, id int) (*models.Lead, error) {
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetLeadByID(ctx, id)
+}
+
+// UpdateLead atualiza os dados e o status de um lead
+func UpdateLead(ctx context.Context, id int, lead *models.Lead) (*models.Lead, error) {
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.UpdateLead(ctx, id, lead)
+}
+
+// ConvertLead qualifica o lead, gerando a oportunidade no funil
+func ConvertLead(ctx context.Context, id int, input OpportunityInput) (*models.Opportunity, error) {
+	opportunity, err := input.toOpportunity()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ConvertLead(ctx, id, opportunity)
+}
+
+// CreateOpportunity cria uma oportunidade vinculada a um lead ou contato
+func CreateOpportunity(ctx context.Context, input OpportunityInput) (*models.Opportunity, error) {
+	if input.LeadID == nil && input.ContactID == nil {
+		return nil, errors.ErrOpportunityWithoutParty
+	}
+
+	opportunity, err := input.toOpportunity()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CreateOpportunity(ctx, opportunity); err != nil {
+		return nil, err
+	}
+	return opportunity, nil
+}
+
+// GetOpportunities lista as oportunidades do funil
+func GetOpportunities(ctx context.Context, filter repository.OpportunityFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	if filter.Stage != "" && !models.IsValidStage(filter.Stage) {
+		return nil, errors.ErrInvalidOpportunityStage
+	}
+
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetOpportunities(ctx, filter, params)
+}
+
+// GetOpportunity retorna a oportunidade com os itens previstos
+func GetOpportunity(ctx context.Context, id int) (*models.Opportunity, error) {
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetOpportunityByID(ctx, id)
+}
+
+// UpdateOpportunity atualiza os dados de uma oportunidade aberta
+func UpdateOpportunity(ctx context.Context, id int, input OpportunityInput) (*models.Opportunity, error) {
+	// O estágio é alterado apenas pela movimentação no funil
+	input.Stage = ""
+	opportunity, err := input.toOpportunity()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.UpdateOpportunity(ctx, id, opportunity)
+}
+
+// MoveOpportunity altera o estágio da oportunidade no funil
+func MoveOpportunity(ctx context.Context, id int, input StageInput) (*models.Opportunity, error) {
+	if !models.IsValidStage(input.Stage) {
+		return nil, errors.ErrInvalidOpportunityStage
+	}
+
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ChangeStage(ctx, id, input.Stage, input.Probability, input.LostReason)
+}
+
+// GetForecast calcula a previsão de vendas ponderada pela probabilidade das oportunidades abertas
+func GetForecast(ctx context.Context, filter repository.OpportunityFilter) (*models.Forecast, error) {
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	opportunities, err := repo.GetOpenOpportunities(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildForecast(opportunities), nil
+}
+
+// ConvertToQuotation gera a cotação da oportunidade ganha
+func ConvertToQuotation(ctx context.Context, id int, expiryDate time.Time) (*sales.Quotation, error) {
+	if expiryDate.IsZero() {
+		expiryDate = time.Now().Add(defaultQuotationValidity)
+	}
+
+	repo, err := repository.NewCRMRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ConvertToQuotation(ctx, id, expiryDate)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/customfields/models"
+	"ERP-ONSMART/backend/internal/modules/customfields/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os campos personalizados, de todas as entidades ou da informada
+// @Security BearerAuth
+// @Param entity query string false "product, contact ou sales_order"
+func ListCustomFieldsHandler(c *gin.Context) {
+	definitions, err := service.ListDefinitions(c.Request.Context(), c.Query("entity"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar campos personalizados")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"custom_fields": definitions})
+}
+
+// Cria um campo personalizado (text, number, boolean, date ou select) para produtos, contatos ou
+// pedidos de venda
+// @Security BearerAuth
+func CreateCustomFieldHandler(c *gin.Context) {
+	var input models.DefinitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	definition, err := service.CreateDefinition(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar campo personalizado")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"custom_field": definition})
+}
+
+// Altera o rótulo, as opções e a obrigatoriedade do campo; entidade, chave e tipo não mudam
+// @Security BearerAuth
+func UpdateCustomFieldHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var update models.DefinitionUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	definition, err := service.UpdateDefinition(c.Request.Context(), id, update)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar campo personalizado")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"custom_field": definition})
+}
+
+// Remove o campo personalizado e o valor dele em todos os registros
+// @Security BearerAuth
+func DeleteCustomFieldHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	if err := service.DeleteDefinition(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover campo personalizado")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Campo personalizado removido com sucesso"})
+}
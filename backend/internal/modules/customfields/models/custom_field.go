@@ -0,0 +1,304 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entidades que aceitam campos personalizados
+const (
+	EntityProduct    = "product"
+	EntityContact    = "contact"
+	EntitySalesOrder = "sales_order"
+)
+
+// EntityTables relaciona cada entidade à tabela com a coluna custom_fields
+var EntityTables = map[string]string{
+	EntityProduct:    "products",
+	EntityContact:    "contacts",
+	EntitySalesOrder: "sales_orders",
+}
+
+// Tipos de campo personalizado
+const (
+	TypeText    = "text"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	// TypeDate guarda a data como AAAA-MM-DD
+	TypeDate = "date"
+	// TypeSelect aceita só um dos valores de Options
+	TypeSelect = "select"
+)
+
+// Types lista os tipos aceitos
+var Types = []string{TypeText, TypeNumber, TypeBoolean, TypeDate, TypeSelect}
+
+// FilterPrefix é o prefixo dos parâmetros de filtro das listagens: ?cf.segmento=varejo
+const FilterPrefix = "cf."
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// Values são os campos personalizados de um registro, gravados na coluna JSONB custom_fields
+type Values map[string]interface{}
+
+// Value grava os valores como objeto JSON; sem valores, grava {} para a coluna nunca ficar null
+func (v Values) Value() (driver.Value, error) {
+	if v == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(v)
+	return string(encoded), err
+}
+
+// Scan lê a coluna JSONB
+func (v *Values) Scan(value interface{}) error {
+	var raw []byte
+	switch data := value.(type) {
+	case nil:
+		*v = Values{}
+		return nil
+	case []byte:
+		raw = data
+	case string:
+		raw = []byte(data)
+	default:
+		return fmt.Errorf("tipo %T não suportado em custom_fields", value)
+	}
+	values := Values{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return err
+	}
+	*v = values
+	return nil
+}
+
+// Definition descreve um campo personalizado de uma entidade. A entidade, a chave e o tipo não
+// mudam depois de criados, para não invalidar os valores já gravados.
+type Definition struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	Entity    string    `json:"entity" gorm:"<-:create"`
+	Key       string    `json:"key" gorm:"<-:create"`
+	Label     string    `json:"label"`
+	Type      string    `json:"type" gorm:"<-:create"`
+	Options   []string  `json:"options" gorm:"serializer:json"`
+	Required  bool      `json:"required"`
+	CreatedBy string    `json:"created_by" gorm:"<-:create"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName define a tabela das definições
+func (Definition) TableName() string {
+	return "custom_field_definitions"
+}
+
+// DefinitionInput são os dados de criação de um campo
+type DefinitionInput struct {
+	Entity   string   `json:"entity" binding:"required"`
+	Key      string   `json:"key" binding:"required"`
+	Label    string   `json:"label" binding:"required,max=100"`
+	Type     string   `json:"type" binding:"required"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+// DefinitionUpdate são os dados alteráveis de um campo
+type DefinitionUpdate struct {
+	Label    string   `json:"label" binding:"required,max=100"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+// ValidEntity indica se a entidade aceita campos personalizados
+func ValidEntity(entity string) bool {
+	_, ok := EntityTables[entity]
+	return ok
+}
+
+// ToDefinition valida os dados e monta a definição
+func (in DefinitionInput) ToDefinition() (*Definition, error) {
+	if !ValidEntity(in.Entity) {
+		return nil, fmt.Errorf("%w: entidade deve ser %s, %s ou %s", errors.ErrInvalidCustomField,
+			EntityProduct, EntityContact, EntitySalesOrder)
+	}
+	if !keyPattern.MatchString(in.Key) {
+		return nil, fmt.Errorf("%w: a chave deve ter letras minúsculas, números e _, começando por letra", errors.ErrInvalidCustomField)
+	}
+	known := false
+	for _, t := range Types {
+		known = known || t == in.Type
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: tipo deve ser %s", errors.ErrInvalidCustomField, strings.Join(Types, ", "))
+	}
+	definition := &Definition{Entity: in.Entity, Key: in.Key, Type: in.Type}
+	if err := definition.Apply(DefinitionUpdate{Label: in.Label, Options: in.Options, Required: in.Required}); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// Apply altera o rótulo, as opções e a obrigatoriedade; só o tipo select tem opções
+func (d *Definition) Apply(update DefinitionUpdate) error {
+	label := strings.TrimSpace(update.Label)
+	if label == "" {
+		return fmt.Errorf("%w: informe o rótulo", errors.ErrInvalidCustomField)
+	}
+	var options []string
+	seen := map[string]bool{}
+	for _, option := range update.Options {
+		if option = strings.TrimSpace(option); option != "" && !seen[option] {
+			seen[option] = true
+			options = append(options, option)
+		}
+	}
+	if d.Type == TypeSelect && len(options) == 0 {
+		return fmt.Errorf("%w: o tipo select precisa de opções", errors.ErrInvalidCustomField)
+	}
+	if d.Type != TypeSelect && len(options) > 0 {
+		return fmt.Errorf("%w: só o tipo select aceita opções", errors.ErrInvalidCustomField)
+	}
+	d.Label, d.Options, d.Required = label, options, update.Required
+	return nil
+}
+
+// Validate confere os valores contra as definições da entidade e os devolve normalizados:
+// chaves desconhecidas são recusadas, obrigatórios precisam estar preenchidos, números viram
+// float64, datas ficam em AAAA-MM-DD e valores null removem o campo
+func Validate(definitions []Definition, values Values) (Values, error) {
+	byKey := make(map[string]Definition, len(definitions))
+	for _, definition := range definitions {
+		byKey[definition.Key] = definition
+	}
+
+	normalized := Values{}
+	for key, value := range values {
+		definition, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: campo %s não existe", errors.ErrInvalidCustomFieldValue, key)
+		}
+		if value == nil {
+			continue
+		}
+		converted, err := definition.convert(value)
+		if err != nil {
+			return nil, err
+		}
+		if text, ok := converted.(string); ok && text == "" {
+			continue
+		}
+		normalized[key] = converted
+	}
+	for _, definition := range definitions {
+		if _, ok := normalized[definition.Key]; definition.Required && !ok {
+			return nil, fmt.Errorf("%w: campo %s é obrigatório", errors.ErrInvalidCustomFieldValue, definition.Key)
+		}
+	}
+	return normalized, nil
+}
+
+// ParseFilters monta o filtro das listagens a partir dos parâmetros cf.<chave>; o registro
+// precisa ter todos os valores informados (comparação exata)
+func ParseFilters(definitions []Definition, query map[string][]string) (Values, error) {
+	byKey := make(map[string]Definition, len(definitions))
+	for _, definition := range definitions {
+		byKey[definition.Key] = definition
+	}
+
+	filters := Values{}
+	for param, raw := range query {
+		if !strings.HasPrefix(param, FilterPrefix) || len(raw) == 0 {
+			continue
+		}
+		key := strings.TrimPrefix(param, FilterPrefix)
+		definition, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: campo %s não existe", errors.ErrInvalidCustomFieldValue, key)
+		}
+		value, err := definition.parse(raw[0])
+		if err != nil {
+			return nil, err
+		}
+		filters[key] = value
+	}
+	return filters, nil
+}
+
+// convert confere o valor recebido no JSON contra o tipo do campo
+func (d Definition) convert(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case TypeNumber:
+		number, ok := value.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, d.invalid("número")
+		}
+		return number, nil
+	case TypeBoolean:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, d.invalid("verdadeiro ou falso")
+		}
+		return flag, nil
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return nil, d.invalid("texto")
+		}
+		return d.parse(text)
+	}
+}
+
+// parse converte o texto (corpo JSON ou parâmetro de filtro) no valor do tipo do campo
+func (d Definition) parse(raw string) (interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	switch d.Type {
+	case TypeNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, d.invalid("número")
+		}
+		return number, nil
+	case TypeBoolean:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, d.invalid("verdadeiro ou falso")
+		}
+		return flag, nil
+	case TypeDate:
+		if raw == "" {
+			return raw, nil
+		}
+		date, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, d.invalid("data AAAA-MM-DD")
+		}
+		return date.Format("2006-01-02"), nil
+	case TypeSelect:
+		if raw == "" {
+			return raw, nil
+		}
+		for _, option := range d.Options {
+			if option == raw {
+				return raw, nil
+			}
+		}
+		return nil, d.invalid(strings.Join(d.Options, ", "))
+	default:
+		if len([]rune(raw)) > 500 {
+			return nil, d.invalid("texto de até 500 caracteres")
+		}
+		return raw, nil
+	}
+}
+
+func (d Definition) invalid(expected string) error {
+	return fmt.Errorf("%w: campo %s deve ser %s", errors.ErrInvalidCustomFieldValue, d.Key, expected)
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDefinitions = []Definition{
+	{Key: "segmento", Type: TypeSelect, Options: []string{"varejo", "atacado"}, Required: true},
+	{Key: "voltagem", Type: TypeNumber},
+	{Key: "importado", Type: TypeBoolean},
+	{Key: "validade", Type: TypeDate},
+	{Key: "obs", Type: TypeText},
+}
+
+func TestDefinitionInputToDefinition(t *testing.T) {
+	definition, err := DefinitionInput{Entity: EntityProduct, Key: "cor", Label: " Cor ", Type: TypeSelect,
+		Options: []string{" azul", "azul", "", "verde"}}.ToDefinition()
+	require.NoError(t, err)
+	assert.Equal(t, "Cor", definition.Label)
+	assert.Equal(t, []string{"azul", "verde"}, definition.Options)
+
+	_, err = DefinitionInput{Entity: "invoice", Key: "cor", Label: "Cor", Type: TypeText}.ToDefinition()
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidCustomField))
+
+	_, err = DefinitionInput{Entity: EntityContact, Key: "Cor-1", Label: "Cor", Type: TypeText}.ToDefinition()
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidCustomField))
+
+	_, err = DefinitionInput{Entity: EntityContact, Key: "cor", Label: "Cor", Type: "color"}.ToDefinition()
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidCustomField))
+
+	_, err = DefinitionInput{Entity: EntityContact, Key: "cor", Label: "Cor", Type: TypeSelect}.ToDefinition()
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidCustomField))
+
+	_, err = DefinitionInput{Entity: EntityContact, Key: "cor", Label: "Cor", Type: TypeText, Options: []string{"a"}}.ToDefinition()
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidCustomField))
+}
+
+func TestValidateNormalizesValues(t *testing.T) {
+	values, err := Validate(testDefinitions, Values{
+		"segmento":  "varejo",
+		"voltagem":  220.0,
+		"importado": true,
+		"validade":  " 2024-05-10 ",
+		"obs":       "",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Values{"segmento": "varejo", "voltagem": 220.0, "importado": true, "validade": "2024-05-10"}, values)
+
+	values, err = Validate(testDefinitions, Values{"segmento": "atacado", "voltagem": nil})
+	require.NoError(t, err)
+	assert.Equal(t, Values{"segmento": "atacado"}, values)
+}
+
+func TestValidateRejectsInvalidValues(t *testing.T) {
+	cases := []Values{
+		{"voltagem": 110.0},                        // segmento obrigatório
+		{"segmento": "online"},                     // fora das opções
+		{"segmento": "varejo", "cor": "azul"},      // campo desconhecido
+		{"segmento": "varejo", "voltagem": "220"},  // número como texto
+		{"segmento": "varejo", "importado": "sim"}, // booleano inválido
+		{"segmento": "varejo", "validade": "10/05/2024"},
+	}
+	for _, values := range cases {
+		_, err := Validate(testDefinitions, values)
+		assert.True(t, stderrors.Is(err, errors.ErrInvalidCustomFieldValue), "%v", values)
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	filters, err := ParseFilters(testDefinitions, map[string][]string{
+		"cf.segmento":  {"varejo"},
+		"cf.voltagem":  {"220"},
+		"cf.importado": {"false"},
+		"page":         {"2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Values{"segmento": "varejo", "voltagem": 220.0, "importado": false}, filters)
+
+	_, err = ParseFilters(testDefinitions, map[string][]string{"cf.cor": {"azul"}})
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidCustomFieldValue))
+
+	_, err = ParseFilters(testDefinitions, map[string][]string{"cf.voltagem": {"alta"}})
+	assert.True(t, stderrors.Is(err, errors.ErrInvalidCustomFieldValue))
+}
+
+func TestValuesValueAndScan(t *testing.T) {
+	var empty Values
+	stored, err := empty.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "{}", stored)
+
+	var values Values
+	require.NoError(t, values.Scan([]byte(`{"voltagem": 220, "segmento": "varejo"}`)))
+	assert.Equal(t, Values{"voltagem": 220.0, "segmento": "varejo"}, values)
+
+	require.NoError(t, values.Scan(nil))
+	assert.Equal(t, Values{}, values)
+
+	assert.Error(t, values.Scan(42))
+}
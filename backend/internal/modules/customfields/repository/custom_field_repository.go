@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/customfields/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// entityNotFound é o erro devolvido quando o registro da entidade não existe
+var entityNotFound = map[string]error{
+	models.EntityProduct:    errors.ErrProductNotFound,
+	models.EntityContact:    errors.ErrContactNotFound,
+	models.EntitySalesOrder: errors.ErrSalesOrderNotFound,
+}
+
+// CustomFieldRepository mantém as definições dos campos personalizados e grava os valores
+type CustomFieldRepository interface {
+	ListDefinitions(ctx context.Context, entity string) ([]models.Definition, error)
+	GetDefinition(ctx context.Context, id int) (*models.Definition, error)
+	CreateDefinition(ctx context.Context, definition *models.Definition) error
+	UpdateDefinition(ctx context.Context, definition *models.Definition) error
+	DeleteDefinition(ctx context.Context, id int) error
+	SetValues(ctx context.Context, entity string, id int, values models.Values) error
+}
+
+type customFieldRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCustomFieldRepository cria uma nova instância do repositório
+func NewCustomFieldRepository() (CustomFieldRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &customFieldRepository{
+		db:     db,
+		logger: logger.WithModule("custom_field_repository"),
+	}, nil
+}
+
+// ListDefinitions lista os campos da entidade (todas, quando vazia) na ordem de criação
+func (r *customFieldRepository) ListDefinitions(ctx context.Context, entity string) ([]models.Definition, error) {
+	query := r.db.WithContext(ctx).Order("entity ASC, id ASC")
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	var definitions []models.Definition
+	if err := query.Find(&definitions).Error; err != nil {
+		r.logger.Error("erro ao listar campos personalizados", zap.Error(err), zap.String("entity", entity))
+		return nil, errors.WrapError(err, "falha ao listar campos personalizados")
+	}
+	return definitions, nil
+}
+
+// GetDefinition busca um campo pelo ID
+func (r *customFieldRepository) GetDefinition(ctx context.Context, id int) (*models.Definition, error) {
+	var definition models.Definition
+	err := r.db.WithContext(ctx).First(&definition, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.ErrCustomFieldNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar campo personalizado")
+	}
+	return &definition, nil
+}
+
+// CreateDefinition grava o campo; a chave é única por entidade
+func (r *customFieldRepository) CreateDefinition(ctx context.Context, definition *models.Definition) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Definition{}).
+		Where("entity = ? AND key = ?", definition.Entity, definition.Key).Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar campo personalizado")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateCustomField
+	}
+	if err := r.db.WithContext(ctx).Create(definition).Error; err != nil {
+		r.logger.Error("erro ao criar campo personalizado", zap.Error(err), zap.String("key", definition.Key))
+		return errors.WrapError(err, "falha ao criar campo personalizado")
+	}
+	return nil
+}
+
+// UpdateDefinition grava o rótulo, as opções e a obrigatoriedade do campo
+func (r *customFieldRepository) UpdateDefinition(ctx context.Context, definition *models.Definition) error {
+	result := r.db.WithContext(ctx).Model(definition).Select("label", "options", "required", "updated_at").Updates(definition)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar campo personalizado", zap.Error(result.Error), zap.Int("id", definition.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar campo personalizado")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrCustomFieldNotFound
+	}
+	return nil
+}
+
+// DeleteDefinition remove o campo e, na mesma transação, o valor dele em todos os registros
+func (r *customFieldRepository) DeleteDefinition(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var definition models.Definition
+		if err := tx.First(&definition, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrCustomFieldNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar campo personalizado")
+		}
+		table := models.EntityTables[definition.Entity]
+		if err := tx.Table(table).Scopes(tenant.Scope(ctx, table)).
+			Where("jsonb_exists(custom_fields, ?)", definition.Key).
+			Update("custom_fields", gorm.Expr("custom_fields - ?", definition.Key)).Error; err != nil {
+			r.logger.Error("erro ao remover valores do campo personalizado", zap.Error(err), zap.Int("id", id))
+			return errors.WrapError(err, "falha ao remover valores do campo personalizado")
+		}
+		if err := tx.Delete(&definition).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover campo personalizado")
+		}
+		return nil
+	})
+}
+
+// SetValues substitui os campos personalizados do registro
+func (r *customFieldRepository) SetValues(ctx context.Context, entity string, id int, values models.Values) error {
+	table := models.EntityTables[entity]
+	result := r.db.WithContext(ctx).Table(table).Scopes(tenant.Scope(ctx, table)).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("custom_fields", values)
+	if result.Error != nil {
+		r.logger.Error("erro ao gravar campos personalizados", zap.Error(result.Error), zap.String("entity", entity), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao gravar campos personalizados")
+	}
+	if result.RowsAffected == 0 {
+		return entityNotFound[entity]
+	}
+	return nil
+}
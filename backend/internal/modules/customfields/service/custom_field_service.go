@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/customfields/models"
+	"ERP-ONSMART/backend/internal/modules/customfields/repository"
+
+	"go.uber.org/zap"
+)
+
+// Service mantém as definições dos campos personalizados e valida os valores gravados nos
+// produtos, contatos e pedidos de venda
+type Service struct {
+	newRepo func() (repository.CustomFieldRepository, error)
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.CustomFieldRepository
+}
+
+// NewService cria o serviço sobre o repositório informado
+func NewService(newRepo func() (repository.CustomFieldRepository, error)) *Service {
+	return &Service{
+		newRepo: newRepo,
+		logger:  logger.WithModule("custom_field_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewCustomFieldRepository)
+
+// ListDefinitions lista os campos da entidade (todas, quando vazia)
+func ListDefinitions(ctx context.Context, entity string) ([]models.Definition, error) {
+	return defaultService.ListDefinitions(ctx, entity)
+}
+
+// CreateDefinition cria um campo personalizado
+func CreateDefinition(ctx context.Context, input models.DefinitionInput, username string) (*models.Definition, error) {
+	return defaultService.CreateDefinition(ctx, input, username)
+}
+
+// UpdateDefinition altera o rótulo, as opções e a obrigatoriedade do campo
+func UpdateDefinition(ctx context.Context, id int, update models.DefinitionUpdate) (*models.Definition, error) {
+	return defaultService.UpdateDefinition(ctx, id, update)
+}
+
+// DeleteDefinition remove o campo e os valores gravados
+func DeleteDefinition(ctx context.Context, id int) error {
+	return defaultService.DeleteDefinition(ctx, id)
+}
+
+// ValidateValues confere os valores contra os campos da entidade e os devolve normalizados
+func ValidateValues(ctx context.Context, entity string, values models.Values) (models.Values, error) {
+	return defaultService.ValidateValues(ctx, entity, values)
+}
+
+// ParseFilters monta o filtro da listagem a partir dos parâmetros cf.<chave>
+func ParseFilters(ctx context.Context, entity string, query map[string][]string) (models.Values, error) {
+	return defaultService.ParseFilters(ctx, entity, query)
+}
+
+// SetValues valida e substitui os campos personalizados do registro
+func SetValues(ctx context.Context, entity string, id int, values models.Values) (models.Values, error) {
+	return defaultService.SetValues(ctx, entity, id, values)
+}
+
+func (s *Service) repository() (repository.CustomFieldRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListDefinitions lista os campos da entidade (todas, quando vazia)
+func (s *Service) ListDefinitions(ctx context.Context, entity string) ([]models.Definition, error) {
+	if entity != "" && !models.ValidEntity(entity) {
+		return nil, fmt.Errorf("%w: entidade %s não aceita campos personalizados", errors.ErrInvalidCustomField, entity)
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDefinitions(ctx, entity)
+}
+
+// CreateDefinition valida e grava o campo, registrando quem o criou
+func (s *Service) CreateDefinition(ctx context.Context, input models.DefinitionInput, username string) (*models.Definition, error) {
+	definition, err := input.ToDefinition()
+	if err != nil {
+		return nil, err
+	}
+	definition.CreatedBy = username
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CreateDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+	s.logger.Info("campo personalizado criado", zap.String("entity", definition.Entity),
+		zap.String("key", definition.Key), zap.String("created_by", username))
+	return definition, nil
+}
+
+// UpdateDefinition altera o rótulo, as opções e a obrigatoriedade do campo. Tornar o campo
+// obrigatório vale para as próximas gravações; os registros existentes não são conferidos.
+func (s *Service) UpdateDefinition(ctx context.Context, id int, update models.DefinitionUpdate) (*models.Definition, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	definition, err := repo.GetDefinition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := definition.Apply(update); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// DeleteDefinition remove o campo e os valores gravados
+func (s *Service) DeleteDefinition(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	if err := repo.DeleteDefinition(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("campo personalizado removido", zap.Int("id", id))
+	return nil
+}
+
+// ValidateValues confere os valores contra os campos da entidade e os devolve normalizados
+func (s *Service) ValidateValues(ctx context.Context, entity string, values models.Values) (models.Values, error) {
+	definitions, err := s.ListDefinitions(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	return models.Validate(definitions, values)
+}
+
+// ParseFilters monta o filtro da listagem; sem parâmetros cf.<chave>, não consulta as definições
+func (s *Service) ParseFilters(ctx context.Context, entity string, query map[string][]string) (models.Values, error) {
+	hasFilter := false
+	for param := range query {
+		hasFilter = hasFilter || strings.HasPrefix(param, models.FilterPrefix)
+	}
+	if !hasFilter {
+		return nil, nil
+	}
+	definitions, err := s.ListDefinitions(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	return models.ParseFilters(definitions, query)
+}
+
+// SetValues valida e substitui os campos personalizados do registro
+func (s *Service) SetValues(ctx context.Context, entity string, id int, values models.Values) (models.Values, error) {
+	normalized, err := s.ValidateValues(ctx, entity, values)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.SetValues(ctx, entity, id, normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
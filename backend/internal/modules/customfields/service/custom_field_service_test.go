@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/customfields/models"
+	"ERP-ONSMART/backend/internal/modules/customfields/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCustomFieldRepo struct {
+	definitions []models.Definition
+	listed      int
+	saved       map[int]models.Values
+}
+
+func (r *fakeCustomFieldRepo) ListDefinitions(ctx context.Context, entity string) ([]models.Definition, error) {
+	r.listed++
+	var definitions []models.Definition
+	for _, definition := range r.definitions {
+		if entity == "" || definition.Entity == entity {
+			definitions = append(definitions, definition)
+		}
+	}
+	return definitions, nil
+}
+
+func (r *fakeCustomFieldRepo) GetDefinition(ctx context.Context, id int) (*models.Definition, error) {
+	for _, definition := range r.definitions {
+		if definition.ID == id {
+			return &definition, nil
+		}
+	}
+	return nil, appErrors.ErrCustomFieldNotFound
+}
+
+func (r *fakeCustomFieldRepo) CreateDefinition(ctx context.Context, definition *models.Definition) error {
+	for _, existing := range r.definitions {
+		if existing.Entity == definition.Entity && existing.Key == definition.Key {
+			return appErrors.ErrDuplicateCustomField
+		}
+	}
+	definition.ID = len(r.definitions) + 1
+	r.definitions = append(r.definitions, *definition)
+	return nil
+}
+
+func (r *fakeCustomFieldRepo) UpdateDefinition(ctx context.Context, definition *models.Definition) error {
+	for i := range r.definitions {
+		if r.definitions[i].ID == definition.ID {
+			r.definitions[i] = *definition
+		}
+	}
+	return nil
+}
+
+func (r *fakeCustomFieldRepo) DeleteDefinition(ctx context.Context, id int) error {
+	return nil
+}
+
+func (r *fakeCustomFieldRepo) SetValues(ctx context.Context, entity string, id int, values models.Values) error {
+	if r.saved == nil {
+		r.saved = map[int]models.Values{}
+	}
+	r.saved[id] = values
+	return nil
+}
+
+func newTestService(repo *fakeCustomFieldRepo) *Service {
+	return NewService(func() (repository.CustomFieldRepository, error) { return repo, nil })
+}
+
+func TestCreateDefinitionRecordsCreatorAndRejectsDuplicates(t *testing.T) {
+	repo := &fakeCustomFieldRepo{}
+	s := newTestService(repo)
+	input := models.DefinitionInput{Entity: models.EntityContact, Key: "segmento", Label: "Segmento",
+		Type: models.TypeSelect, Options: []string{"varejo", "atacado"}}
+
+	definition, err := s.CreateDefinition(context.Background(), input, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, "ana", definition.CreatedBy)
+
+	_, err = s.CreateDefinition(context.Background(), input, "ana")
+	assert.True(t, errors.Is(err, appErrors.ErrDuplicateCustomField))
+}
+
+func TestUpdateDefinitionKeepsType(t *testing.T) {
+	repo := &fakeCustomFieldRepo{definitions: []models.Definition{
+		{ID: 1, Entity: models.EntityProduct, Key: "voltagem", Label: "Voltagem", Type: models.TypeNumber},
+	}}
+	s := newTestService(repo)
+
+	definition, err := s.UpdateDefinition(context.Background(), 1, models.DefinitionUpdate{Label: "Tensão", Required: true})
+	require.NoError(t, err)
+	assert.Equal(t, "Tensão", definition.Label)
+	assert.True(t, repo.definitions[0].Required)
+
+	_, err = s.UpdateDefinition(context.Background(), 1, models.DefinitionUpdate{Label: "Tensão", Options: []string{"110"}})
+	assert.True(t, errors.Is(err, appErrors.ErrInvalidCustomField))
+
+	_, err = s.UpdateDefinition(context.Background(), 9, models.DefinitionUpdate{Label: "X"})
+	assert.True(t, errors.Is(err, appErrors.ErrCustomFieldNotFound))
+}
+
+func TestParseFiltersSkipsLookupWithoutCustomFieldParams(t *testing.T) {
+	repo := &fakeCustomFieldRepo{definitions: []models.Definition{
+		{ID: 1, Entity: models.EntitySalesOrder, Key: "canal", Type: models.TypeText},
+	}}
+	s := newTestService(repo)
+
+	filters, err := s.ParseFilters(context.Background(), models.EntitySalesOrder, map[string][]string{"status": {"draft"}})
+	require.NoError(t, err)
+	assert.Nil(t, filters)
+	assert.Zero(t, repo.listed)
+
+	filters, err = s.ParseFilters(context.Background(), models.EntitySalesOrder, map[string][]string{"cf.canal": {"revenda"}})
+	require.NoError(t, err)
+	assert.Equal(t, models.Values{"canal": "revenda"}, filters)
+}
+
+func TestSetValuesValidatesAgainstEntityDefinitions(t *testing.T) {
+	repo := &fakeCustomFieldRepo{definitions: []models.Definition{
+		{ID: 1, Entity: models.EntitySalesOrder, Key: "canal", Type: models.TypeText, Required: true},
+		{ID: 2, Entity: models.EntityProduct, Key: "voltagem", Type: models.TypeNumber},
+	}}
+	s := newTestService(repo)
+
+	values, err := s.SetValues(context.Background(), models.EntitySalesOrder, 7, models.Values{"canal": " revenda "})
+	require.NoError(t, err)
+	assert.Equal(t, models.Values{"canal": "revenda"}, repo.saved[7])
+	assert.Equal(t, repo.saved[7], values)
+
+	_, err = s.SetValues(context.Background(), models.EntitySalesOrder, 7, models.Values{"canal": "revenda", "voltagem": 220.0})
+	assert.True(t, errors.Is(err, appErrors.ErrInvalidCustomFieldValue))
+
+	_, err = s.SetValues(context.Background(), "invoice", 7, models.Values{})
+	assert.True(t, errors.Is(err, appErrors.ErrInvalidCustomField))
+}
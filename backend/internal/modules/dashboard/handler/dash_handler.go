@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Retorna os módulos disponíveis no painel
 func DashboardHandler(c *gin.Context) {
 	modules := service.ListDashboardModules()
 	c.JSON(http.StatusOK, gin.H{"modules": modules})
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/logger"
 	"ERP-ONSMART/backend/internal/modules/dropshipping/models"
 	"ERP-ONSMART/backend/internal/modules/dropshipping/service"
@@ -21,9 +22,9 @@ func init() {
 
 // ListDropshippingsHandler retorna todas as transações de dropshipping.
 func ListDropshippingsHandler(c *gin.Context) {
-	dropshippings, err := service.ListDropshippings()
+	dropshippings, err := service.ListDropshippings(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": dropshippings})
@@ -34,13 +35,13 @@ func GetDropshippingHandler(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
-	ds, err := service.GetDropshipping(id)
+	ds, err := service.GetDropshipping(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dropshipping não encontrado"})
+		c.Error(errors.ErrDropshippingNotFound)
 		return
 	}
 	logger.Logger.Info("Dropshipping recuperado", zap.Int("id", ds.ID))
@@ -51,19 +52,19 @@ func GetDropshippingHandler(c *gin.Context) {
 func CreateDropshippingHandler(c *gin.Context) {
 	var ds models.Dropshipping
 	if err := c.ShouldBindJSON(&ds); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 
 	// Validação dos dados recebidos.
 	if err := validate.Struct(ds); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 
-	created, err := service.AddDropshipping(ds)
+	created, err := service.AddDropshipping(c.Request.Context(), ds)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	logger.Logger.Info("Dropshipping criado", zap.Int("id", created.ID))
@@ -75,23 +76,23 @@ func UpdateDropshippingHandler(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
 	var ds models.Dropshipping
 	if err := c.ShouldBindJSON(&ds); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 	if err := validate.Struct(ds); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 
-	updated, err := service.ModifyDropshipping(id, ds)
+	updated, err := service.ModifyDropshipping(c.Request.Context(), id, ds)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	logger.Logger.Info("Dropshipping atualizado", zap.Int("id", updated.ID))
@@ -103,12 +104,12 @@ func DeleteDropshippingHandler(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
-	if err := service.RemoveDropshipping(id); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Dropshipping não encontrado"})
+	if err := service.RemoveDropshipping(c.Request.Context(), id); err != nil {
+		c.Error(errors.ErrDropshippingNotFound)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Dropshipping excluído com sucesso"})
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
 	"encoding/json"
 	"net/http"
@@ -34,6 +35,8 @@ func TestMain(m *testing.M) {
 func TestCreateDropshippingHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.POST("/dropshippings", CreateDropshippingHandler)
 
 	// Payload: note que os campos obrigatórios devem ser preenchidos e os nomes dos campos
@@ -49,6 +52,7 @@ func TestCreateDropshippingHandler(t *testing.T) {
 		"updated_at": "2025-04-09"
 	}`
 	req, err := http.NewRequest("POST", "/dropshippings", bytes.NewBuffer([]byte(payload)))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	if err != nil {
 		t.Fatalf("Erro ao criar requisição: %v", err)
 	}
@@ -65,9 +69,12 @@ func TestCreateDropshippingHandler(t *testing.T) {
 func TestListDropshippingsHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.GET("/dropshippings", ListDropshippingsHandler)
 
 	req, _ := http.NewRequest("GET", "/dropshippings", nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 
 	router.ServeHTTP(resp, req)
@@ -89,6 +96,8 @@ func TestListDropshippingsHandler(t *testing.T) {
 func TestGetDropshippingHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.POST("/dropshippings", CreateDropshippingHandler)
 	router.GET("/dropshippings/:id", GetDropshippingHandler)
 
@@ -104,6 +113,7 @@ func TestGetDropshippingHandler(t *testing.T) {
 		"updated_at": "2025-04-09"
 	}`
 	reqCreate, _ := http.NewRequest("POST", "/dropshippings", bytes.NewBuffer([]byte(payload)))
+	reqCreate.Header.Set(middleware.CompanyHeader, "1")
 	reqCreate.Header.Set("Content-Type", "application/json")
 	respCreate := httptest.NewRecorder()
 	router.ServeHTTP(respCreate, reqCreate)
@@ -124,6 +134,7 @@ func TestGetDropshippingHandler(t *testing.T) {
 
 	// Faz a requisição GET para recuperar o dropshipping pelo ID.
 	reqGet, _ := http.NewRequest("GET", "/dropshippings/"+strconv.Itoa(id), nil)
+	reqGet.Header.Set(middleware.CompanyHeader, "1")
 	respGet := httptest.NewRecorder()
 	router.ServeHTTP(respGet, reqGet)
 	if respGet.Code != http.StatusOK {
@@ -136,6 +147,8 @@ func TestGetDropshippingHandler(t *testing.T) {
 func TestUpdateDropshippingHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.POST("/dropshippings", CreateDropshippingHandler)
 	router.PUT("/dropshippings/:id", UpdateDropshippingHandler)
 	router.GET("/dropshippings/:id", GetDropshippingHandler)
@@ -152,6 +165,7 @@ func TestUpdateDropshippingHandler(t *testing.T) {
 		"updated_at": "2025-04-09"
 	}`
 	reqCreate, _ := http.NewRequest("POST", "/dropshippings", bytes.NewBuffer([]byte(payload)))
+	reqCreate.Header.Set(middleware.CompanyHeader, "1")
 	reqCreate.Header.Set("Content-Type", "application/json")
 	respCreate := httptest.NewRecorder()
 	router.ServeHTTP(respCreate, reqCreate)
@@ -182,6 +196,7 @@ func TestUpdateDropshippingHandler(t *testing.T) {
 		"updated_at": "2025-04-10"
 	}`
 	reqUpdate, _ := http.NewRequest("PUT", "/dropshippings/"+strconv.Itoa(id), bytes.NewBuffer([]byte(updatePayload)))
+	reqUpdate.Header.Set(middleware.CompanyHeader, "1")
 	reqUpdate.Header.Set("Content-Type", "application/json")
 	respUpdate := httptest.NewRecorder()
 	router.ServeHTTP(respUpdate, reqUpdate)
@@ -191,6 +206,7 @@ func TestUpdateDropshippingHandler(t *testing.T) {
 
 	// Verifica a atualização, fazendo um GET.
 	reqGet, _ := http.NewRequest("GET", "/dropshippings/"+strconv.Itoa(id), nil)
+	reqGet.Header.Set(middleware.CompanyHeader, "1")
 	respGet := httptest.NewRecorder()
 	router.ServeHTTP(respGet, reqGet)
 	if respGet.Code != http.StatusOK {
@@ -217,9 +233,12 @@ func TestUpdateDropshippingHandler(t *testing.T) {
 func TestDeleteDropshippingHandler_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.DELETE("/dropshippings/:id", DeleteDropshippingHandler)
 
 	req, _ := http.NewRequest("DELETE", "/dropshippings/999999", nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
@@ -2,12 +2,20 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/dropshipping/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"fmt"
 )
 
 // InsertDropshipping insere uma nova transação de dropshipping no banco de dados.
-func InsertDropshipping(ds models.Dropshipping) error {
+func InsertDropshipping(ctx context.Context, ds models.Dropshipping) error {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return errors.ErrTenantRequired
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
@@ -16,16 +24,21 @@ func InsertDropshipping(ds models.Dropshipping) error {
 
 	query := `
 		INSERT INTO dropshipping
-		    (product_id, warranty_id, cliente, price, quantity, total_price, start_date, updated_at)
+		    (product_id, warranty_id, cliente, price, quantity, total_price, start_date, updated_at, company_id)
 		VALUES 
-		    ($1, $2, $3, $4, $5, $6, $7, $8)
+		    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
-	_, err = conn.Exec(query, ds.ProductID, ds.WarrantyID, ds.Cliente, ds.Price, ds.Quantity, ds.TotalPrice, ds.StartDate, ds.UpdatedAt)
+	_, err = conn.ExecContext(ctx, query, ds.ProductID, ds.WarrantyID, ds.Cliente, ds.Price, ds.Quantity, ds.TotalPrice, ds.StartDate, ds.UpdatedAt, companyID)
 	return err
 }
 
 // GetAllDropshippings retorna todas as transações de dropshipping.
-func GetAllDropshippings() ([]models.Dropshipping, error) {
+func GetAllDropshippings(ctx context.Context) ([]models.Dropshipping, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
@@ -35,8 +48,8 @@ func GetAllDropshippings() ([]models.Dropshipping, error) {
 	query := `
 		SELECT id, product_id, warranty_id, cliente, price, quantity, total_price, start_date, updated_at
 		FROM dropshipping
-	`
-	rows, err := conn.Query(query)
+		WHERE ` + company
+	rows, err := conn.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +68,12 @@ func GetAllDropshippings() ([]models.Dropshipping, error) {
 }
 
 // GetDropshippingByID retorna uma transação de dropshipping pelo ID.
-func GetDropshippingByID(id int) (models.Dropshipping, error) {
+func GetDropshippingByID(ctx context.Context, id int) (models.Dropshipping, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return models.Dropshipping{}, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return models.Dropshipping{}, err
@@ -65,10 +83,9 @@ func GetDropshippingByID(id int) (models.Dropshipping, error) {
 	query := `
 		SELECT id, product_id, warranty_id, cliente, price, quantity, total_price, start_date, updated_at
 		FROM dropshipping
-		WHERE id = $1
-	`
+		WHERE id = $1 AND ` + company
 	var ds models.Dropshipping
-	err = conn.QueryRow(query, id).Scan(&ds.ID, &ds.ProductID, &ds.WarrantyID, &ds.Cliente, &ds.Price, &ds.Quantity, &ds.TotalPrice, &ds.StartDate, &ds.UpdatedAt)
+	err = conn.QueryRowContext(ctx, query, id).Scan(&ds.ID, &ds.ProductID, &ds.WarrantyID, &ds.Cliente, &ds.Price, &ds.Quantity, &ds.TotalPrice, &ds.StartDate, &ds.UpdatedAt)
 	if err != nil {
 		return ds, err
 	}
@@ -76,15 +93,20 @@ func GetDropshippingByID(id int) (models.Dropshipping, error) {
 }
 
 // DeleteDropshippingByID deleta uma transação de dropshipping pelo ID.
-func DeleteDropshippingByID(id int) error {
+func DeleteDropshippingByID(ctx context.Context, id int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	query := `DELETE FROM dropshipping WHERE id = $1`
-	result, err := conn.Exec(query, id)
+	query := `DELETE FROM dropshipping WHERE id = $1 AND ` + company
+	result, err := conn.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -101,7 +123,12 @@ func DeleteDropshippingByID(id int) error {
 }
 
 // UpdateDropshippingByID atualiza os dados de uma transação de dropshipping.
-func UpdateDropshippingByID(id int, ds models.Dropshipping) error {
+func UpdateDropshippingByID(ctx context.Context, id int, ds models.Dropshipping) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
@@ -111,8 +138,7 @@ func UpdateDropshippingByID(id int, ds models.Dropshipping) error {
 	query := `
 		UPDATE dropshipping
 		SET product_id = $1, warranty_id = $2, cliente = $3, price = $4, quantity = $5, total_price = $6, start_date = $7, updated_at = $8
-		WHERE id = $9
-	`
-	_, err = conn.Exec(query, ds.ProductID, ds.WarrantyID, ds.Cliente, ds.Price, ds.Quantity, ds.TotalPrice, ds.StartDate, ds.UpdatedAt, id)
+		WHERE id = $9 AND ` + company
+	_, err = conn.ExecContext(ctx, query, ds.ProductID, ds.WarrantyID, ds.Cliente, ds.Price, ds.Quantity, ds.TotalPrice, ds.StartDate, ds.UpdatedAt, id)
 	return err
 }
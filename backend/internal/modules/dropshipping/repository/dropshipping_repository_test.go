@@ -4,6 +4,9 @@ import (
 	m "ERP-ONSMART/backend/internal/modules/dropshipping/models" // Models de dropshipping
 	p "ERP-ONSMART/backend/internal/modules/products/models"     // Models de produtos
 	ps "ERP-ONSMART/backend/internal/modules/products/service"   // Service de produtos
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"os"
 	"testing"
 
@@ -26,7 +29,7 @@ func createTestProduct(t *testing.T) int {
 	product := p.Product{
 		Name:        "Produto Dropshipping Teste",
 		Description: "Produto inserido para teste de dropshipping",
-		Price:       100.00,
+		Price:       money.FromInt(100),
 		Stock:       50,
 	}
 	if err := ps.CreateProduct(&product); err != nil {
@@ -45,12 +48,12 @@ func createTestWarranty(t *testing.T, productID int) int {
 	warranty := p.Warranty{
 		ProductID:      productID,
 		DurationMonths: 12,
-		Price:          20.00,
+		Price:          money.FromInt(20),
 	}
-	if err := ps.CreateWarranty(warranty); err != nil {
+	if err := ps.CreateWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), warranty); err != nil {
 		t.Fatalf("Erro ao inserir garantia: %v", err)
 	}
-	warranties, err := ps.ListWarranties()
+	warranties, err := ps.ListWarranties(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil || len(warranties) == 0 {
 		t.Fatalf("Erro ao listar garantias: %v", err)
 	}
@@ -79,7 +82,7 @@ func TestInsertDropshipping(t *testing.T) {
 		StartDate:  "2025-04-09",
 		UpdatedAt:  "2025-04-09",
 	}
-	if err := InsertDropshipping(ds); err != nil {
+	if err := InsertDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), ds); err != nil {
 		t.Fatalf("Erro ao inserir dropshipping: %v", err)
 	}
 }
@@ -100,11 +103,11 @@ func TestGetDropshipping(t *testing.T) {
 		StartDate:  "2025-04-09",
 		UpdatedAt:  "2025-04-09",
 	}
-	if err := InsertDropshipping(ds); err != nil {
+	if err := InsertDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), ds); err != nil {
 		t.Fatalf("Erro ao inserir dropshipping: %v", err)
 	}
 
-	list, err := GetAllDropshippings()
+	list, err := GetAllDropshippings(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao listar dropshippings: %v", err)
 	}
@@ -113,7 +116,7 @@ func TestGetDropshipping(t *testing.T) {
 	}
 	last := list[len(list)-1]
 
-	retrieved, err := GetDropshippingByID(last.ID)
+	retrieved, err := GetDropshippingByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), last.ID)
 	if err != nil {
 		t.Fatalf("Erro ao recuperar dropshipping por ID: %v", err)
 	}
@@ -138,11 +141,11 @@ func TestUpdateDropshipping(t *testing.T) {
 		StartDate:  "2025-04-09",
 		UpdatedAt:  "2025-04-09",
 	}
-	if err := InsertDropshipping(ds); err != nil {
+	if err := InsertDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), ds); err != nil {
 		t.Fatalf("Erro ao inserir dropshipping: %v", err)
 	}
 
-	list, err := GetAllDropshippings()
+	list, err := GetAllDropshippings(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil || len(list) == 0 {
 		t.Fatalf("Erro ao listar dropshippings: %v", err)
 	}
@@ -154,11 +157,11 @@ func TestUpdateDropshipping(t *testing.T) {
 	last.TotalPrice = last.Price * float64(last.Quantity)
 	last.UpdatedAt = "2025-04-10"
 
-	if err := UpdateDropshippingByID(last.ID, last); err != nil {
+	if err := UpdateDropshippingByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), last.ID, last); err != nil {
 		t.Fatalf("Erro ao atualizar dropshipping: %v", err)
 	}
 
-	updated, err := GetDropshippingByID(last.ID)
+	updated, err := GetDropshippingByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), last.ID)
 	if err != nil {
 		t.Fatalf("Erro ao recuperar dropshipping atualizado: %v", err)
 	}
@@ -183,21 +186,21 @@ func TestDeleteDropshipping(t *testing.T) {
 		StartDate:  "2025-04-09",
 		UpdatedAt:  "2025-04-09",
 	}
-	if err := InsertDropshipping(ds); err != nil {
+	if err := InsertDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), ds); err != nil {
 		t.Fatalf("Erro ao inserir dropshipping: %v", err)
 	}
 
-	list, err := GetAllDropshippings()
+	list, err := GetAllDropshippings(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil || len(list) == 0 {
 		t.Fatalf("Erro ao listar dropshippings: %v", err)
 	}
 	last := list[len(list)-1]
 
-	if err := DeleteDropshippingByID(last.ID); err != nil {
+	if err := DeleteDropshippingByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), last.ID); err != nil {
 		t.Fatalf("Erro ao deletar dropshipping: %v", err)
 	}
 
-	_, err = GetDropshippingByID(last.ID)
+	_, err = GetDropshippingByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), last.ID)
 	if err == nil {
 		t.Errorf("Dropshipping com ID %d não foi excluído", last.ID)
 	}
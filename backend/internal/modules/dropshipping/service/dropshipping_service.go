@@ -3,34 +3,35 @@ package service
 import (
 	"ERP-ONSMART/backend/internal/modules/dropshipping/models"
 	"ERP-ONSMART/backend/internal/modules/dropshipping/repository"
+	"context"
 	"errors"
 )
 
 // ListDropshippings retorna todas as transações de dropshipping.
-func ListDropshippings() ([]models.Dropshipping, error) {
-	return repository.GetAllDropshippings()
+func ListDropshippings(ctx context.Context) ([]models.Dropshipping, error) {
+	return repository.GetAllDropshippings(ctx)
 }
 
 // GetDropshipping retorna uma transação de dropshipping pelo ID.
-func GetDropshipping(id int) (models.Dropshipping, error) {
-	return repository.GetDropshippingByID(id)
+func GetDropshipping(ctx context.Context, id int) (models.Dropshipping, error) {
+	return repository.GetDropshippingByID(ctx, id)
 }
 
 // AddDropshipping insere uma nova transação de dropshipping.
 // Após a inserção, retorna o objeto criado.
-func AddDropshipping(ds models.Dropshipping) (models.Dropshipping, error) {
+func AddDropshipping(ctx context.Context, ds models.Dropshipping) (models.Dropshipping, error) {
 	// Se TotalPrice não estiver informado (ou for zero), calcula como Price * Quantity.
 	if ds.TotalPrice == 0 {
 		ds.TotalPrice = ds.Price * float64(ds.Quantity)
 	}
 
 	// Insere no repositório.
-	if err := repository.InsertDropshipping(ds); err != nil {
+	if err := repository.InsertDropshipping(ctx, ds); err != nil {
 		return models.Dropshipping{}, err
 	}
 
 	// Para retornar o registro inserido, listamos os dropshippings e consideramos o último como o inserido.
-	list, err := repository.GetAllDropshippings()
+	list, err := repository.GetAllDropshippings(ctx)
 	if err != nil {
 		return models.Dropshipping{}, err
 	}
@@ -42,16 +43,16 @@ func AddDropshipping(ds models.Dropshipping) (models.Dropshipping, error) {
 
 // ModifyDropshipping atualiza os dados de uma transação de dropshipping pelo ID.
 // Após a atualização, retorna o registro atualizado.
-func ModifyDropshipping(id int, ds models.Dropshipping) (models.Dropshipping, error) {
+func ModifyDropshipping(ctx context.Context, id int, ds models.Dropshipping) (models.Dropshipping, error) {
 	// Recalcula TotalPrice para refletir Price * Quantity.
 	ds.TotalPrice = ds.Price * float64(ds.Quantity)
-	if err := repository.UpdateDropshippingByID(id, ds); err != nil {
+	if err := repository.UpdateDropshippingByID(ctx, id, ds); err != nil {
 		return models.Dropshipping{}, err
 	}
-	return repository.GetDropshippingByID(id)
+	return repository.GetDropshippingByID(ctx, id)
 }
 
 // RemoveDropshipping remove uma transação de dropshipping pelo ID.
-func RemoveDropshipping(id int) error {
-	return repository.DeleteDropshippingByID(id)
+func RemoveDropshipping(ctx context.Context, id int) error {
+	return repository.DeleteDropshippingByID(ctx, id)
 }
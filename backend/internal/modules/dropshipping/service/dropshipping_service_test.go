@@ -1,11 +1,13 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
 
 	"ERP-ONSMART/backend/internal/modules/dropshipping/models"
+	"ERP-ONSMART/backend/internal/tenant"
 
 	"github.com/spf13/viper"
 )
@@ -35,7 +37,7 @@ func TestAddDropshipping(t *testing.T) {
 		StartDate:  "2025-04-09",
 		UpdatedAt:  "2025-04-09",
 	}
-	created, err := AddDropshipping(ds)
+	created, err := AddDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), ds)
 	if err != nil {
 		t.Fatalf("AddDropshipping falhou: %v", err)
 	}
@@ -51,7 +53,7 @@ func TestAddDropshipping(t *testing.T) {
 
 // TestListDropshippings testa se a listagem retorna pelo menos um registro.
 func TestListDropshippings(t *testing.T) {
-	list, err := ListDropshippings()
+	list, err := ListDropshippings(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("ListDropshippings falhou: %v", err)
 	}
@@ -73,7 +75,7 @@ func TestModifyDropshipping(t *testing.T) {
 		StartDate:  "2025-04-09",
 		UpdatedAt:  "2025-04-09",
 	}
-	created, err := AddDropshipping(ds)
+	created, err := AddDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), ds)
 	if err != nil {
 		t.Fatalf("Erro ao criar dropshipping para modificação: %v", err)
 	}
@@ -82,7 +84,7 @@ func TestModifyDropshipping(t *testing.T) {
 	created.Price = 130.00
 	created.Quantity = 3
 	// O service recalcula o total automaticamente.
-	updated, err := ModifyDropshipping(created.ID, created)
+	updated, err := ModifyDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID, created)
 	if err != nil {
 		t.Fatalf("ModifyDropshipping falhou: %v", err)
 	}
@@ -106,17 +108,17 @@ func TestRemoveDropshipping(t *testing.T) {
 		StartDate:  "2025-04-09",
 		UpdatedAt:  "2025-04-09",
 	}
-	created, err := AddDropshipping(ds)
+	created, err := AddDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), ds)
 	if err != nil {
 		t.Fatalf("Erro ao criar dropshipping para remoção: %v", err)
 	}
 	// Remove o registro.
-	err = RemoveDropshipping(created.ID)
+	err = RemoveDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Fatalf("Erro ao remover dropshipping: %v", err)
 	}
 	// Tenta recuperar o registro removido.
-	_, err = GetDropshipping(created.ID)
+	_, err = GetDropshipping(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err == nil {
 		t.Errorf("Dropshipping com ID %d não foi removido", created.ID)
 	}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/ecommerce"
+	"ERP-ONSMART/backend/internal/modules/ecommerce/models"
+	"ERP-ONSMART/backend/internal/modules/ecommerce/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os canais de e-commerce da empresa, com o estado da última sincronização
+// @Security BearerAuth
+func ListChannelsHandler(c *gin.Context) {
+	channels, err := service.ListChannels(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar canais de e-commerce")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"channels": channels, "platforms": ecommerce.Platforms})
+}
+
+// Cadastra uma loja virtual para sincronização de pedidos, estoque, preços e rastreamento
+// @Security BearerAuth
+func CreateChannelHandler(c *gin.Context) {
+	var input models.ChannelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	channel, err := service.CreateChannel(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar canal de e-commerce")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"channel": channel})
+}
+
+// Altera o canal de e-commerce; credenciais em branco mantêm as atuais
+// @Security BearerAuth
+func UpdateChannelHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ChannelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	channel, err := service.UpdateChannel(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar canal de e-commerce")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"channel": channel})
+}
+
+// Sincroniza o canal imediatamente, sem aguardar o job agendado
+// @Security BearerAuth
+func SyncChannelHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	runs, err := service.SyncChannel(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao sincronizar canal de e-commerce")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// Lista as sincronizações mais recentes do canal
+// @Security BearerAuth
+// @Param limit query int false "quantidade (padrão 20, máx. 100)"
+func ListSyncRunsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var limit int
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.Error(errors.InvalidParam("limit deve ser um número inteiro positivo"))
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := service.ListRuns(c.Request.Context(), id, limit)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar sincronizações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
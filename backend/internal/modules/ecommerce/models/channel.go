@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// Tipos de sincronização registrados em SyncRun
+const (
+	SyncOrders   = "orders"
+	SyncStock    = "stock"
+	SyncPrices   = "prices"
+	SyncTracking = "tracking"
+)
+
+// Situação de uma sincronização
+const (
+	SyncStatusSuccess = "success" // todos os itens processados
+	SyncStatusPartial = "partial" // parte dos itens falhou
+	SyncStatusFailed  = "failed"  // a chamada à loja falhou
+)
+
+// Channel é uma loja virtual sincronizada com o ERP. As credenciais da API da loja não são
+// expostas nas respostas.
+type Channel struct {
+	ID              int        `json:"id" gorm:"primaryKey"`
+	CompanyID       int        `json:"company_id" gorm:"<-:create"`
+	Name            string     `json:"name"`
+	Platform        string     `json:"platform"`
+	BaseURL         string     `json:"base_url"`
+	APIKey          string     `json:"-"`
+	APISecret       string     `json:"-"`
+	Active          bool       `json:"active"`
+	LastOrderSyncAt *time.Time `json:"last_order_sync_at"`
+	LastStockSyncAt *time.Time `json:"last_stock_sync_at"`
+	LastError       string     `json:"last_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Channel) TableName() string {
+	return "ecommerce_channels"
+}
+
+// OrderLink associa o pedido da loja ao pedido de venda gerado no ERP
+type OrderLink struct {
+	ID               int        `json:"id" gorm:"primaryKey"`
+	CompanyID        int        `json:"company_id" gorm:"<-:create"`
+	ChannelID        int        `json:"channel_id"`
+	ExternalID       string     `json:"external_id"`
+	ExternalNumber   string     `json:"external_number"`
+	SalesOrderID     int        `json:"sales_order_id"`
+	TrackingNumber   string     `json:"tracking_number,omitempty"`
+	TrackingPushedAt *time.Time `json:"tracking_pushed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (OrderLink) TableName() string {
+	return "ecommerce_orders"
+}
+
+// SyncRun registra uma sincronização de um canal
+type SyncRun struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	CompanyID  int       `json:"company_id" gorm:"<-:create"`
+	ChannelID  int       `json:"channel_id"`
+	Kind       string    `json:"kind"`
+	Status     string    `json:"status"`
+	Processed  int       `json:"processed"`
+	Failed     int       `json:"failed"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+func (SyncRun) TableName() string {
+	return "ecommerce_sync_runs"
+}
+
+// PendingShipment é uma entrega com rastreamento de um pedido importado, ainda não enviada à loja
+type PendingShipment struct {
+	LinkID          int
+	OrderExternalID string
+	Carrier         string
+	TrackingNumber  string
+	DeliveryDate    time.Time
+}
+
+// ProductRef é o produto do ERP correspondente a um SKU da loja
+type ProductRef struct {
+	ID         int
+	Name       string
+	SKU        string
+	Stock      int
+	Price      money.Decimal
+	SalesPrice money.Decimal
+}
+
+// ChannelInput são os dados de cadastro de um canal; na alteração, credenciais vazias mantêm as atuais
+type ChannelInput struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Platform  string `json:"platform" binding:"required"`
+	BaseURL   string `json:"base_url" binding:"required,url,max=255"`
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+	Active    *bool  `json:"active"`
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
+	"ERP-ONSMART/backend/internal/modules/ecommerce/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EcommerceRepository define as operações dos canais de e-commerce e do estado da sincronização
+type EcommerceRepository interface {
+	ListChannels(ctx context.Context) ([]models.Channel, error)
+	GetChannel(ctx context.Context, id int) (*models.Channel, error)
+	CreateChannel(ctx context.Context, channel *models.Channel) error
+	UpdateChannel(ctx context.Context, channel *models.Channel) error
+	UpdateSyncState(ctx context.Context, channelID int, fields map[string]interface{}) error
+
+	IsOrderImported(ctx context.Context, channelID int, externalID string) (bool, error)
+	ImportOrder(ctx context.Context, link *models.OrderLink, customer *contact.Contact, order *sales.SalesOrder) error
+	ProductsBySKU(ctx context.Context, skus []string) (map[string]models.ProductRef, error)
+	ListSyncProducts(ctx context.Context) ([]models.ProductRef, error)
+	PendingShipments(ctx context.Context, channelID int) ([]models.PendingShipment, error)
+	MarkTrackingPushed(ctx context.Context, linkID int, trackingNumber string, at time.Time) error
+
+	SaveRun(ctx context.Context, run *models.SyncRun) error
+	ListRuns(ctx context.Context, channelID, limit int) ([]models.SyncRun, error)
+}
+
+type ecommerceRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewEcommerceRepository cria uma nova instância do repositório
+func NewEcommerceRepository() (EcommerceRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &ecommerceRepository{
+		db:     db,
+		logger: logger.WithModule("ecommerce_repository"),
+	}, nil
+}
+
+// ListChannels retorna os canais da empresa (ou de todas, nos jobs com tenant.AllCompanies)
+func (r *ecommerceRepository) ListChannels(ctx context.Context) ([]models.Channel, error) {
+	var channels []models.Channel
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&channels).Error; err != nil {
+		r.logger.Error("erro ao listar canais de e-commerce", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar canais de e-commerce")
+	}
+	return channels, nil
+}
+
+// GetChannel busca um canal pelo ID
+func (r *ecommerceRepository) GetChannel(ctx context.Context, id int) (*models.Channel, error) {
+	var channel models.Channel
+	if err := r.db.WithContext(ctx).First(&channel, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrChannelNotFound
+		}
+		r.logger.Error("erro ao buscar canal de e-commerce", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar canal de e-commerce")
+	}
+	return &channel, nil
+}
+
+// CreateChannel grava um novo canal
+func (r *ecommerceRepository) CreateChannel(ctx context.Context, channel *models.Channel) error {
+	if err := r.db.WithContext(ctx).Create(channel).Error; err != nil {
+		r.logger.Error("erro ao criar canal de e-commerce", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar canal de e-commerce")
+	}
+	return nil
+}
+
+// UpdateChannel grava os dados cadastrais do canal
+func (r *ecommerceRepository) UpdateChannel(ctx context.Context, channel *models.Channel) error {
+	if err := r.db.WithContext(ctx).Model(channel).
+		Select("name", "platform", "base_url", "api_key", "api_secret", "active").
+		Updates(channel).Error; err != nil {
+		r.logger.Error("erro ao atualizar canal de e-commerce", zap.Error(err), zap.Int("id", channel.ID))
+		return errors.WrapError(err, "falha ao atualizar canal de e-commerce")
+	}
+	return nil
+}
+
+// UpdateSyncState grava o instante da última sincronização e o último erro do canal
+func (r *ecommerceRepository) UpdateSyncState(ctx context.Context, channelID int, fields map[string]interface{}) error {
+	if err := r.db.WithContext(ctx).Model(&models.Channel{}).Where("id = ?", channelID).Updates(fields).Error; err != nil {
+		r.logger.Error("erro ao atualizar estado da sincronização", zap.Error(err), zap.Int("channel_id", channelID))
+		return errors.WrapError(err, "falha ao atualizar estado da sincronização")
+	}
+	return nil
+}
+
+// IsOrderImported indica se o pedido da loja já gerou um pedido de venda
+func (r *ecommerceRepository) IsOrderImported(ctx context.Context, channelID int, externalID string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.OrderLink{}).
+		Where("channel_id = ? AND external_id = ?", channelID, externalID).
+		Count(&count).Error; err != nil {
+		return false, errors.WrapError(err, "falha ao verificar pedido importado")
+	}
+	return count > 0, nil
+}
+
+// ImportOrder grava, em uma transação, o cliente (reaproveitando o contato com o mesmo
+// documento ou e-mail), o pedido de venda com os itens e o vínculo com o pedido da loja
+func (r *ecommerceRepository) ImportOrder(ctx context.Context, link *models.OrderLink, customer *contact.Contact, order *sales.SalesOrder) error {
+	tx := r.db.WithContext(ctx).Begin()
+
+	contactID, err := r.findOrCreateContact(tx, customer)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := contactRepository.EnsureNotBlocked(tx, contactID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	order.ContactID = contactID
+	if order.Items, err = salesRepository.PrepareSalesOrderItems(tx, order.Items); err != nil {
+		tx.Rollback()
+		return err
+	}
+	order.SONo = salesRepository.NextDocumentNumber(tx, &sales.SalesOrder{}, "SO")
+	if err := tx.Omit(clause.Associations).Create(order).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar sales order do e-commerce", zap.Error(err), zap.String("external_id", link.ExternalID))
+		return errors.WrapError(err, "falha ao criar sales order")
+	}
+	for i := range order.Items {
+		order.Items[i].SalesOrderID = order.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&order.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar itens do sales order do e-commerce", zap.Error(err), zap.Int("id", order.ID))
+		return errors.WrapError(err, "falha ao criar itens do sales order")
+	}
+
+	link.SalesOrderID = order.ID
+	if err := tx.Create(link).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao vincular pedido do e-commerce", zap.Error(err), zap.String("external_id", link.ExternalID))
+		return errors.WrapError(err, "falha ao vincular pedido do e-commerce")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("pedido do e-commerce importado",
+		zap.Int("channel_id", link.ChannelID),
+		zap.String("external_id", link.ExternalID),
+		zap.String("so_no", order.SONo))
+	return nil
+}
+
+// findOrCreateContact busca o cliente pelo documento ou, sem documento, pelo e-mail
+func (r *ecommerceRepository) findOrCreateContact(tx *gorm.DB, customer *contact.Contact) (int, error) {
+	query := tx.Model(&contact.Contact{}).Select("id").Where("deleted_at IS NULL")
+	if customer.Document != "" {
+		query = query.Where("document = ?", customer.Document)
+	} else {
+		query = query.Where("LOWER(email) = LOWER(?)", customer.Email)
+	}
+
+	var ids []int
+	if err := query.Order("id ASC").Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, errors.WrapError(err, "falha ao buscar cliente do pedido")
+	}
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+
+	if err := tx.Create(customer).Error; err != nil {
+		r.logger.Error("erro ao criar cliente do e-commerce", zap.Error(err))
+		return 0, errors.WrapError(err, "falha ao criar cliente do pedido")
+	}
+	return customer.ID, nil
+}
+
+// ProductsBySKU retorna os produtos da empresa com os SKUs informados
+func (r *ecommerceRepository) ProductsBySKU(ctx context.Context, skus []string) (map[string]models.ProductRef, error) {
+	var products []models.ProductRef
+	if err := r.products(ctx).Where("sku IN ?", skus).Scan(&products).Error; err != nil {
+		r.logger.Error("erro ao buscar produtos pelo SKU", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar produtos pelo SKU")
+	}
+
+	bySKU := make(map[string]models.ProductRef, len(products))
+	for _, product := range products {
+		if _, ok := bySKU[product.SKU]; !ok {
+			bySKU[product.SKU] = product
+		}
+	}
+	return bySKU, nil
+}
+
+// ListSyncProducts retorna os produtos ativos com SKU, cujo estoque e preço são enviados às lojas
+func (r *ecommerceRepository) ListSyncProducts(ctx context.Context) ([]models.ProductRef, error) {
+	var products []models.ProductRef
+	if err := r.products(ctx).Where("sku <> '' AND status = ?", "ativo").Scan(&products).Error; err != nil {
+		r.logger.Error("erro ao listar produtos sincronizados", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar produtos sincronizados")
+	}
+	return products, nil
+}
+
+func (r *ecommerceRepository) products(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table("products").
+		Scopes(tenant.Scope(ctx, "products")).
+		Select("id, name, sku, stock, price, sales_price").
+		Where("deleted_at IS NULL").
+		Order("id ASC")
+}
+
+// PendingShipments retorna as entregas enviadas, com código de rastreamento, dos pedidos do
+// canal cujo rastreamento ainda não foi informado à loja
+func (r *ecommerceRepository) PendingShipments(ctx context.Context, channelID int) ([]models.PendingShipment, error) {
+	var shipments []models.PendingShipment
+	err := r.db.WithContext(ctx).Table("ecommerce_orders AS eo").
+		Scopes(tenant.Scope(ctx, "eo")).
+		Select(`DISTINCT ON (eo.id) eo.id AS link_id, eo.external_id AS order_external_id,
+			d.carrier, d.tracking_number, d.delivery_date`).
+		Joins("JOIN deliveries d ON d.sales_order_id = eo.sales_order_id AND d.deleted_at IS NULL").
+		Where("eo.channel_id = ? AND eo.tracking_pushed_at IS NULL", channelID).
+		Where("d.status IN ? AND COALESCE(d.tracking_number, '') <> ''",
+			[]string{sales.DeliveryStatusShipped, sales.DeliveryStatusDelivered}).
+		Order("eo.id, d.delivery_date").
+		Scan(&shipments).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar rastreamentos pendentes", zap.Error(err), zap.Int("channel_id", channelID))
+		return nil, errors.WrapError(err, "falha ao buscar rastreamentos pendentes")
+	}
+	return shipments, nil
+}
+
+// MarkTrackingPushed registra que o rastreamento do pedido foi informado à loja
+func (r *ecommerceRepository) MarkTrackingPushed(ctx context.Context, linkID int, trackingNumber string, at time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&models.OrderLink{}).Where("id = ?", linkID).
+		Updates(map[string]interface{}{"tracking_number": trackingNumber, "tracking_pushed_at": at}).Error; err != nil {
+		return errors.WrapError(err, "falha ao registrar rastreamento enviado")
+	}
+	return nil
+}
+
+// SaveRun grava o resultado de uma sincronização
+func (r *ecommerceRepository) SaveRun(ctx context.Context, run *models.SyncRun) error {
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		r.logger.Error("erro ao gravar sincronização", zap.Error(err), zap.Int("channel_id", run.ChannelID))
+		return errors.WrapError(err, "falha ao gravar sincronização")
+	}
+	return nil
+}
+
+// ListRuns retorna as sincronizações mais recentes do canal
+func (r *ecommerceRepository) ListRuns(ctx context.Context, channelID, limit int) ([]models.SyncRun, error) {
+	var runs []models.SyncRun
+	if err := r.db.WithContext(ctx).Where("channel_id = ?", channelID).
+		Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		r.logger.Error("erro ao listar sincronizações", zap.Error(err), zap.Int("channel_id", channelID))
+		return nil, errors.WrapError(err, "falha ao listar sincronizações")
+	}
+	return runs, nil
+}
@@ -0,0 +1,519 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/ecommerce"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/ecommerce/models"
+	"ERP-ONSMART/backend/internal/modules/ecommerce/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// orderSyncOverlap relê os pedidos dos últimos minutos a cada sincronização, para não perder
+// pedidos gravados na loja com atraso; os já importados são ignorados
+const orderSyncOverlap = 10 * time.Minute
+
+// firstOrderSyncWindow é o período lido na primeira sincronização de pedidos do canal
+const firstOrderSyncWindow = 7 * 24 * time.Hour
+
+// maxRunsLimit limita a quantidade de sincronizações listadas por canal
+const maxRunsLimit = 100
+
+// Service sincroniza os canais de e-commerce com o ERP
+type Service struct {
+	newRepo      func() (repository.EcommerceRepository, error)
+	newConnector func(platform string, creds ecommerce.Credentials) (ecommerce.Connector, error)
+	now          func() time.Time
+	logger       *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.EcommerceRepository
+}
+
+// NewService cria o serviço sobre o repositório informado, com os conectores do pacote ecommerce
+func NewService(newRepo func() (repository.EcommerceRepository, error)) *Service {
+	return &Service{
+		newRepo:      newRepo,
+		newConnector: ecommerce.New,
+		now:          time.Now,
+		logger:       logger.WithModule("ecommerce_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewEcommerceRepository)
+
+// ListChannels lista os canais da empresa da requisição
+func ListChannels(ctx context.Context) ([]models.Channel, error) {
+	return defaultService.ListChannels(ctx)
+}
+
+// CreateChannel cadastra um canal na empresa da requisição
+func CreateChannel(ctx context.Context, input models.ChannelInput) (*models.Channel, error) {
+	return defaultService.CreateChannel(ctx, input)
+}
+
+// UpdateChannel altera o cadastro de um canal
+func UpdateChannel(ctx context.Context, id int, input models.ChannelInput) (*models.Channel, error) {
+	return defaultService.UpdateChannel(ctx, id, input)
+}
+
+// SyncChannel executa imediatamente a sincronização completa do canal
+func SyncChannel(ctx context.Context, id int) ([]models.SyncRun, error) {
+	return defaultService.SyncChannel(ctx, id)
+}
+
+// ListRuns retorna as sincronizações mais recentes do canal
+func ListRuns(ctx context.Context, id, limit int) ([]models.SyncRun, error) {
+	return defaultService.ListRuns(ctx, id, limit)
+}
+
+// StartSyncScheduler sincroniza periodicamente os canais ativos de todas as empresas até o
+// contexto ser cancelado
+func StartSyncScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("ecommerce_sync")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				synced, err := defaultService.SyncAll(ctx)
+				metrics.ObserveJob("ecommerce_sync", err)
+				if err != nil {
+					log.Error("erro ao sincronizar canais de e-commerce", zap.Error(err))
+					continue
+				}
+				log.Info("sincronização de e-commerce concluída", zap.Int("channels", synced))
+			}
+		}
+	}()
+}
+
+func (s *Service) repository() (repository.EcommerceRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListChannels lista os canais da empresa
+func (s *Service) ListChannels(ctx context.Context) ([]models.Channel, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListChannels(ctx)
+}
+
+// CreateChannel valida a plataforma e grava o canal, ativo por padrão
+func (s *Service) CreateChannel(ctx context.Context, input models.ChannelInput) (*models.Channel, error) {
+	channel := &models.Channel{Active: true}
+	if err := s.applyInput(channel, input); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CreateChannel(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("canal de e-commerce criado", zap.Int("id", channel.ID), zap.String("platform", channel.Platform))
+	return channel, nil
+}
+
+// UpdateChannel altera o canal; credenciais em branco mantêm as gravadas
+func (s *Service) UpdateChannel(ctx context.Context, id int, input models.ChannelInput) (*models.Channel, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	channel, err := repo.GetChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyInput(channel, input); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateChannel(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("canal de e-commerce atualizado", zap.Int("id", channel.ID))
+	return channel, nil
+}
+
+func (s *Service) applyInput(channel *models.Channel, input models.ChannelInput) error {
+	platform := strings.ToLower(strings.TrimSpace(input.Platform))
+	if _, err := s.newConnector(platform, ecommerce.Credentials{BaseURL: input.BaseURL}); err != nil {
+		return err
+	}
+
+	channel.Name = strings.TrimSpace(input.Name)
+	channel.Platform = platform
+	channel.BaseURL = strings.TrimSpace(input.BaseURL)
+	if input.APIKey != "" {
+		channel.APIKey = input.APIKey
+	}
+	if input.APISecret != "" {
+		channel.APISecret = input.APISecret
+	}
+	if input.Active != nil {
+		channel.Active = *input.Active
+	}
+	return nil
+}
+
+// ListRuns retorna as sincronizações mais recentes do canal da empresa (padrão 20, máximo 100)
+func (s *Service) ListRuns(ctx context.Context, id, limit int) ([]models.SyncRun, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxRunsLimit {
+		limit = maxRunsLimit
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	// Garante que o canal pertence à empresa da requisição
+	if _, err := repo.GetChannel(ctx, id); err != nil {
+		return nil, err
+	}
+	return repo.ListRuns(ctx, id, limit)
+}
+
+// SyncChannel sincroniza um canal ativo da empresa
+func (s *Service) SyncChannel(ctx context.Context, id int) ([]models.SyncRun, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	channel, err := repo.GetChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !channel.Active {
+		return nil, errors.ErrChannelInactive
+	}
+	return s.sync(ctx, repo, channel)
+}
+
+// SyncAll sincroniza os canais ativos de todas as empresas, cada um no contexto da sua
+// empresa; a falha de um canal fica registrada nele e não interrompe os demais
+func (s *Service) SyncAll(ctx context.Context) (int, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, err
+	}
+	channels, err := repo.ListChannels(tenant.AllCompanies(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for i := range channels {
+		channel := &channels[i]
+		if !channel.Active {
+			continue
+		}
+		if _, err := s.sync(tenant.WithCompany(ctx, channel.CompanyID), repo, channel); err != nil {
+			s.logger.Error("erro ao sincronizar canal de e-commerce", zap.Error(err), zap.Int("channel_id", channel.ID))
+			continue
+		}
+		synced++
+	}
+	return synced, nil
+}
+
+// sync importa os pedidos e envia estoque, preços e rastreamento à loja, registrando cada etapa
+func (s *Service) sync(ctx context.Context, repo repository.EcommerceRepository, channel *models.Channel) ([]models.SyncRun, error) {
+	connector, err := s.newConnector(channel.Platform, ecommerce.Credentials{
+		BaseURL: channel.BaseURL,
+		Key:     channel.APIKey,
+		Secret:  channel.APISecret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []struct {
+		kind string
+		run  func(context.Context, repository.EcommerceRepository, ecommerce.Connector, *models.Channel, *models.SyncRun) error
+	}{
+		{models.SyncOrders, s.syncOrders},
+		{models.SyncStock, s.syncStock},
+		{models.SyncPrices, s.syncPrices},
+		{models.SyncTracking, s.syncTracking},
+	}
+
+	state := map[string]interface{}{}
+	runs := make([]models.SyncRun, 0, len(steps))
+	failures := make([]string, 0)
+	for _, step := range steps {
+		run := models.SyncRun{ChannelID: channel.ID, Kind: step.kind, StartedAt: s.now()}
+		err := step.run(ctx, repo, connector, channel, &run)
+		run.FinishedAt = s.now()
+
+		switch {
+		case err != nil:
+			run.Status = models.SyncStatusFailed
+			run.Error = err.Error()
+		case run.Failed > 0:
+			run.Status = models.SyncStatusPartial
+		default:
+			run.Status = models.SyncStatusSuccess
+		}
+		if run.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s: %s", step.kind, run.Error))
+		}
+
+		// Só avança o instante da última sincronização quando a loja respondeu
+		if err == nil {
+			switch step.kind {
+			case models.SyncOrders:
+				state["last_order_sync_at"] = run.StartedAt
+			case models.SyncStock:
+				state["last_stock_sync_at"] = run.StartedAt
+			}
+		}
+
+		if err := repo.SaveRun(ctx, &run); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	state["last_error"] = strings.Join(failures, "; ")
+	if err := repo.UpdateSyncState(ctx, channel.ID, state); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("canal de e-commerce sincronizado", zap.Int("channel_id", channel.ID), zap.Int("failures", len(failures)))
+	return runs, nil
+}
+
+// syncOrders importa como pedidos de venda confirmados os pedidos pagos ainda não importados
+func (s *Service) syncOrders(ctx context.Context, repo repository.EcommerceRepository, connector ecommerce.Connector, channel *models.Channel, run *models.SyncRun) error {
+	since := run.StartedAt.Add(-firstOrderSyncWindow)
+	if channel.LastOrderSyncAt != nil {
+		since = channel.LastOrderSyncAt.Add(-orderSyncOverlap)
+	}
+
+	orders, err := connector.PullOrders(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	failures := make([]string, 0)
+	for _, order := range orders {
+		imported, err := repo.IsOrderImported(ctx, channel.ID, order.ExternalID)
+		if err != nil {
+			return err
+		}
+		if imported {
+			continue
+		}
+
+		if err := s.importOrder(ctx, repo, channel, order); err != nil {
+			run.Failed++
+			failures = append(failures, fmt.Sprintf("pedido %s: %s", order.Number, err.Error()))
+			s.logger.Warn("pedido do e-commerce não importado", zap.Error(err),
+				zap.Int("channel_id", channel.ID), zap.String("external_id", order.ExternalID))
+			continue
+		}
+		run.Processed++
+	}
+
+	run.Error = strings.Join(failures, "; ")
+	return nil
+}
+
+// importOrder grava o pedido da loja como pedido de venda. Valores que não cabem em money.Decimal
+// (preço ou total fora do intervalo) recusam o pedido em vez de derrubar a sincronização.
+func (s *Service) importOrder(ctx context.Context, repo repository.EcommerceRepository, channel *models.Channel, order ecommerce.Order) (err error) {
+	defer money.Recover(&err)
+
+	skus := make([]string, 0, len(order.Items))
+	for _, item := range order.Items {
+		skus = append(skus, item.SKU)
+	}
+	products, err := repo.ProductsBySKU(ctx, skus)
+	if err != nil {
+		return err
+	}
+
+	so := &sales.SalesOrder{
+		Status:          sales.SOStatusConfirmed,
+		ExpectedDate:    order.PlacedAt,
+		Notes:           strings.TrimSpace(fmt.Sprintf("Pedido %s da loja %s. %s", order.Number, channel.Name, order.Notes)),
+		ShippingAddress: order.ShippingAddress.String(),
+		Items:           make([]sales.SOItem, 0, len(order.Items)),
+	}
+
+	var totals sales.DocumentTotals
+	for _, item := range order.Items {
+		product, ok := products[item.SKU]
+		if !ok {
+			return fmt.Errorf("%w: %q", errors.ErrProductSKUNotFound, item.SKU)
+		}
+		unitPrice, err := money.FromFloat(item.UnitPrice)
+		if err != nil {
+			return fmt.Errorf("preço do SKU %q: %w", item.SKU, err)
+		}
+		discount, err := money.FromFloat(item.Discount)
+		if err != nil {
+			return fmt.Errorf("desconto do SKU %q: %w", item.SKU, err)
+		}
+		so.Items = append(so.Items, sales.SOItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			ProductCode: product.SKU,
+			Description: item.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   unitPrice,
+			Discount:    discount,
+			Total:       sales.LineTotal(item.Quantity, unitPrice, discount, money.Zero),
+		})
+		totals.Add(item.Quantity, unitPrice, discount, money.Zero)
+	}
+	so.SubTotal = totals.SubTotal
+	so.TaxTotal = totals.TaxTotal
+	so.DiscountTotal = totals.DiscountTotal
+	so.GrandTotal = totals.GrandTotal
+
+	link := &models.OrderLink{ChannelID: channel.ID, ExternalID: order.ExternalID, ExternalNumber: order.Number}
+	return repo.ImportOrder(ctx, link, toContact(order.Customer), so)
+}
+
+// toContact monta o cliente do pedido; o tipo de pessoa segue o tamanho do documento (CPF ou CNPJ)
+func toContact(customer ecommerce.Customer) *contact.Contact {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, customer.Document)
+
+	c := &contact.Contact{
+		PersonType:   "pf",
+		Type:         "cliente",
+		Name:         customer.Name,
+		Document:     customer.Document,
+		Email:        customer.Email,
+		Phone:        customer.Phone,
+		ZipCode:      customer.Address.ZipCode,
+		Street:       customer.Address.Street,
+		Number:       customer.Address.Number,
+		Complement:   customer.Address.Complement,
+		Neighborhood: customer.Address.Neighborhood,
+		City:         customer.Address.City,
+		State:        customer.Address.State,
+	}
+	if len(digits) > 11 {
+		c.PersonType = "pj"
+		c.CompanyName = customer.Company
+	}
+	return c
+}
+
+// syncStock envia à loja o estoque dos produtos ativos com SKU
+func (s *Service) syncStock(ctx context.Context, repo repository.EcommerceRepository, connector ecommerce.Connector, _ *models.Channel, run *models.SyncRun) error {
+	products, err := repo.ListSyncProducts(ctx)
+	if err != nil {
+		return err
+	}
+
+	levels := make([]ecommerce.StockLevel, 0, len(products))
+	for _, product := range products {
+		levels = append(levels, ecommerce.StockLevel{SKU: product.SKU, Quantity: product.Stock})
+	}
+	return pushed(run, func() (*ecommerce.PushResult, error) { return connector.PushStock(ctx, levels) })
+}
+
+// syncPrices envia à loja o preço de venda dos produtos (ou o preço de tabela, sem preço de venda)
+func (s *Service) syncPrices(ctx context.Context, repo repository.EcommerceRepository, connector ecommerce.Connector, _ *models.Channel, run *models.SyncRun) error {
+	products, err := repo.ListSyncProducts(ctx)
+	if err != nil {
+		return err
+	}
+
+	prices := make([]ecommerce.PriceUpdate, 0, len(products))
+	for _, product := range products {
+		price := product.SalesPrice
+		if !price.IsPositive() {
+			price = product.Price
+		}
+		if price.IsPositive() {
+			prices = append(prices, ecommerce.PriceUpdate{SKU: product.SKU, Price: price})
+		}
+	}
+	return pushed(run, func() (*ecommerce.PushResult, error) { return connector.PushPrices(ctx, prices) })
+}
+
+// pushed registra no run o resultado do envio; SKUs que a loja não tem não contam como falha
+func pushed(run *models.SyncRun, push func() (*ecommerce.PushResult, error)) error {
+	result, err := push()
+	if err != nil {
+		return err
+	}
+	run.Processed = result.Updated
+	return nil
+}
+
+// syncTracking informa à loja o rastreamento dos pedidos importados que já foram enviados
+func (s *Service) syncTracking(ctx context.Context, repo repository.EcommerceRepository, connector ecommerce.Connector, channel *models.Channel, run *models.SyncRun) error {
+	shipments, err := repo.PendingShipments(ctx, channel.ID)
+	if err != nil {
+		return err
+	}
+
+	failures := make([]string, 0)
+	for _, shipment := range shipments {
+		err := connector.PushTracking(ctx, ecommerce.Shipment{
+			OrderExternalID: shipment.OrderExternalID,
+			Carrier:         shipment.Carrier,
+			TrackingNumber:  shipment.TrackingNumber,
+			ShippedAt:       shipment.DeliveryDate,
+		})
+		if err == nil {
+			err = repo.MarkTrackingPushed(ctx, shipment.LinkID, shipment.TrackingNumber, s.now())
+		}
+		if err != nil {
+			run.Failed++
+			failures = append(failures, fmt.Sprintf("pedido %s: %s", shipment.OrderExternalID, err.Error()))
+			continue
+		}
+		run.Processed++
+	}
+
+	run.Error = strings.Join(failures, "; ")
+	return nil
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/ecommerce"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/ecommerce/models"
+	"ERP-ONSMART/backend/internal/modules/ecommerce/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	channels  map[int]*models.Channel
+	imported  map[string]bool
+	products  []models.ProductRef
+	shipments []models.PendingShipment
+	orders    []*sales.SalesOrder
+	contacts  []*contact.Contact
+	pushed    []int
+	runs      []models.SyncRun
+	state     map[string]interface{}
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{channels: map[int]*models.Channel{}, imported: map[string]bool{}}
+}
+
+func (f *fakeRepo) ListChannels(context.Context) ([]models.Channel, error) {
+	var channels []models.Channel
+	for _, channel := range f.channels {
+		channels = append(channels, *channel)
+	}
+	return channels, nil
+}
+
+func (f *fakeRepo) GetChannel(_ context.Context, id int) (*models.Channel, error) {
+	channel, ok := f.channels[id]
+	if !ok {
+		return nil, errors.ErrChannelNotFound
+	}
+	copied := *channel
+	return &copied, nil
+}
+
+func (f *fakeRepo) CreateChannel(_ context.Context, channel *models.Channel) error {
+	channel.ID = len(f.channels) + 1
+	f.channels[channel.ID] = channel
+	return nil
+}
+
+func (f *fakeRepo) UpdateChannel(_ context.Context, channel *models.Channel) error {
+	f.channels[channel.ID] = channel
+	return nil
+}
+
+func (f *fakeRepo) UpdateSyncState(_ context.Context, _ int, fields map[string]interface{}) error {
+	f.state = fields
+	return nil
+}
+
+func (f *fakeRepo) IsOrderImported(_ context.Context, _ int, externalID string) (bool, error) {
+	return f.imported[externalID], nil
+}
+
+func (f *fakeRepo) ImportOrder(_ context.Context, link *models.OrderLink, customer *contact.Contact, order *sales.SalesOrder) error {
+	f.imported[link.ExternalID] = true
+	f.orders = append(f.orders, order)
+	f.contacts = append(f.contacts, customer)
+	return nil
+}
+
+func (f *fakeRepo) ProductsBySKU(_ context.Context, skus []string) (map[string]models.ProductRef, error) {
+	found := map[string]models.ProductRef{}
+	for _, product := range f.products {
+		for _, sku := range skus {
+			if product.SKU == sku {
+				found[sku] = product
+			}
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeRepo) ListSyncProducts(context.Context) ([]models.ProductRef, error) {
+	return f.products, nil
+}
+
+func (f *fakeRepo) PendingShipments(context.Context, int) ([]models.PendingShipment, error) {
+	return f.shipments, nil
+}
+
+func (f *fakeRepo) MarkTrackingPushed(_ context.Context, linkID int, _ string, _ time.Time) error {
+	f.pushed = append(f.pushed, linkID)
+	return nil
+}
+
+func (f *fakeRepo) SaveRun(_ context.Context, run *models.SyncRun) error {
+	f.runs = append(f.runs, *run)
+	return nil
+}
+
+func (f *fakeRepo) ListRuns(context.Context, int, int) ([]models.SyncRun, error) {
+	return f.runs, nil
+}
+
+type fakeConnector struct {
+	orders    []ecommerce.Order
+	since     time.Time
+	stock     []ecommerce.StockLevel
+	prices    []ecommerce.PriceUpdate
+	shipments []ecommerce.Shipment
+	stockErr  error
+}
+
+func (c *fakeConnector) Platform() string { return ecommerce.PlatformWooCommerce }
+
+func (c *fakeConnector) PullOrders(_ context.Context, since time.Time) ([]ecommerce.Order, error) {
+	c.since = since
+	return c.orders, nil
+}
+
+func (c *fakeConnector) PushStock(_ context.Context, levels []ecommerce.StockLevel) (*ecommerce.PushResult, error) {
+	if c.stockErr != nil {
+		return nil, c.stockErr
+	}
+	c.stock = levels
+	return &ecommerce.PushResult{Updated: len(levels)}, nil
+}
+
+func (c *fakeConnector) PushPrices(_ context.Context, prices []ecommerce.PriceUpdate) (*ecommerce.PushResult, error) {
+	c.prices = prices
+	return &ecommerce.PushResult{Updated: len(prices)}, nil
+}
+
+func (c *fakeConnector) PushTracking(_ context.Context, shipment ecommerce.Shipment) error {
+	c.shipments = append(c.shipments, shipment)
+	return nil
+}
+
+var syncNow = time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestService(repo *fakeRepo, connector *fakeConnector) *Service {
+	s := NewService(func() (repository.EcommerceRepository, error) { return repo, nil })
+	s.newConnector = func(platform string, creds ecommerce.Credentials) (ecommerce.Connector, error) {
+		if platform != ecommerce.PlatformWooCommerce {
+			return ecommerce.New(platform, creds)
+		}
+		return connector, nil
+	}
+	s.now = func() time.Time { return syncNow }
+	return s
+}
+
+func TestCreateChannelValidatesPlatform(t *testing.T) {
+	s := newTestService(newFakeRepo(), &fakeConnector{})
+
+	_, err := s.CreateChannel(context.Background(), models.ChannelInput{Name: "Loja", Platform: "shopify", BaseURL: "https://loja.example.com"})
+	assert.Equal(t, errors.ErrPlatformNotSupported, err)
+
+	channel, err := s.CreateChannel(context.Background(), models.ChannelInput{Name: " Loja ", Platform: "WooCommerce", BaseURL: "https://loja.example.com", APIKey: "ck"})
+	require.NoError(t, err)
+	assert.Equal(t, "Loja", channel.Name)
+	assert.Equal(t, ecommerce.PlatformWooCommerce, channel.Platform)
+	assert.True(t, channel.Active)
+}
+
+func TestUpdateChannelKeepsCredentials(t *testing.T) {
+	repo := newFakeRepo()
+	repo.channels[1] = &models.Channel{ID: 1, Platform: ecommerce.PlatformWooCommerce, APIKey: "ck", APISecret: "cs", Active: true}
+	s := newTestService(repo, &fakeConnector{})
+
+	inactive := false
+	channel, err := s.UpdateChannel(context.Background(), 1, models.ChannelInput{Name: "Loja", Platform: "woocommerce", BaseURL: "https://nova.example.com", Active: &inactive})
+	require.NoError(t, err)
+	assert.Equal(t, "ck", channel.APIKey)
+	assert.Equal(t, "cs", channel.APISecret)
+	assert.False(t, channel.Active)
+}
+
+func TestSyncChannelRejectsInactive(t *testing.T) {
+	repo := newFakeRepo()
+	repo.channels[1] = &models.Channel{ID: 1, Platform: ecommerce.PlatformWooCommerce}
+	s := newTestService(repo, &fakeConnector{})
+
+	_, err := s.SyncChannel(context.Background(), 1)
+	assert.Equal(t, errors.ErrChannelInactive, err)
+}
+
+func TestSyncChannelImportsOrdersAndPushesUpdates(t *testing.T) {
+	lastSync := syncNow.Add(-time.Hour)
+	repo := newFakeRepo()
+	repo.channels[1] = &models.Channel{ID: 1, Name: "Loja", Platform: ecommerce.PlatformWooCommerce, Active: true, LastOrderSyncAt: &lastSync}
+	repo.imported["700"] = true
+	repo.products = []models.ProductRef{
+		{ID: 10, Name: "Notebook", SKU: "NB-01", Stock: 4, Price: money.FromInt(1500)},
+		{ID: 11, Name: "Mouse", SKU: "MS-02", Stock: 9, Price: money.FromInt(25), SalesPrice: money.MustParse("19.9")},
+	}
+	repo.shipments = []models.PendingShipment{{LinkID: 3, OrderExternalID: "650", Carrier: "correios", TrackingNumber: "AA1BR"}}
+
+	connector := &fakeConnector{orders: []ecommerce.Order{
+		{ExternalID: "700", Number: "700"},
+		{
+			ExternalID: "727", Number: "727", PlacedAt: syncNow.Add(-30 * time.Minute),
+			Customer:        ecommerce.Customer{Name: "ACME", Company: "ACME Ltda", Document: "12.345.678/0001-90"},
+			ShippingAddress: ecommerce.Address{Street: "Rua A", Number: "10", City: "Campinas"},
+			Items: []ecommerce.OrderItem{
+				{SKU: "NB-01", Quantity: 2, UnitPrice: 1500, Discount: 100},
+				{SKU: "MS-02", Quantity: 1, UnitPrice: 19.9},
+			},
+		},
+		{ExternalID: "728", Number: "728", Items: []ecommerce.OrderItem{{SKU: "XX-99", Quantity: 1, UnitPrice: 10}}},
+	}}
+	s := newTestService(repo, connector)
+
+	runs, err := s.SyncChannel(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, lastSync.Add(-orderSyncOverlap), connector.since)
+	require.Len(t, repo.orders, 1, "pedido já importado e pedido com SKU desconhecido não geram pedido de venda")
+	order := repo.orders[0]
+	assert.Equal(t, sales.SOStatusConfirmed, order.Status)
+	assert.Equal(t, "Rua A, 10 - Campinas", order.ShippingAddress)
+	assert.Equal(t, "3019.90", order.SubTotal.String())
+	assert.Equal(t, "100.00", order.DiscountTotal.String())
+	assert.Equal(t, "2919.90", order.GrandTotal.String())
+	assert.Equal(t, "2900.00", order.Items[0].Total.String())
+	assert.Equal(t, 10, order.Items[0].ProductID)
+	assert.Equal(t, "pj", repo.contacts[0].PersonType)
+	assert.Equal(t, "cliente", repo.contacts[0].Type)
+
+	assert.Equal(t, []ecommerce.PriceUpdate{{SKU: "NB-01", Price: money.FromInt(1500)}, {SKU: "MS-02", Price: money.MustParse("19.9")}}, connector.prices)
+	assert.Len(t, connector.stock, 2)
+	assert.Equal(t, []int{3}, repo.pushed)
+
+	require.Len(t, runs, 4)
+	assert.Equal(t, models.SyncOrders, runs[0].Kind)
+	assert.Equal(t, models.SyncStatusPartial, runs[0].Status)
+	assert.Equal(t, 1, runs[0].Processed)
+	assert.Equal(t, 1, runs[0].Failed)
+	assert.Contains(t, runs[0].Error, "XX-99")
+	for _, run := range runs[1:] {
+		assert.Equal(t, models.SyncStatusSuccess, run.Status, run.Kind)
+	}
+	assert.Equal(t, syncNow, repo.state["last_order_sync_at"])
+	assert.Equal(t, syncNow, repo.state["last_stock_sync_at"])
+	assert.Contains(t, repo.state["last_error"], "orders:")
+}
+
+func TestSyncChannelRejectsOrdersOutOfRange(t *testing.T) {
+	repo := newFakeRepo()
+	repo.channels[1] = &models.Channel{ID: 1, Platform: ecommerce.PlatformWooCommerce, Active: true}
+	repo.products = []models.ProductRef{{ID: 10, Name: "Notebook", SKU: "NB-01", Price: money.FromInt(1500)}}
+	connector := &fakeConnector{orders: []ecommerce.Order{
+		{ExternalID: "801", Number: "801", Items: []ecommerce.OrderItem{{SKU: "NB-01", Quantity: 1, UnitPrice: 1e16}}},
+		{ExternalID: "802", Number: "802", Items: []ecommerce.OrderItem{{SKU: "NB-01", Quantity: math.MaxInt32, UnitPrice: 900000000}}},
+		{ExternalID: "803", Number: "803", Items: []ecommerce.OrderItem{{SKU: "NB-01", Quantity: 1, UnitPrice: 1500}}},
+	}}
+	s := newTestService(repo, connector)
+
+	runs, err := s.SyncChannel(context.Background(), 1)
+	require.NoError(t, err)
+
+	require.Len(t, repo.orders, 1, "pedidos com valores fora do intervalo não são importados")
+	assert.Equal(t, "1500.00", repo.orders[0].GrandTotal.String())
+	assert.Equal(t, 1, runs[0].Processed)
+	assert.Equal(t, 2, runs[0].Failed)
+	assert.Contains(t, runs[0].Error, "pedido 801: preço do SKU \"NB-01\": "+money.ErrOverflow.Error())
+	assert.Contains(t, runs[0].Error, "pedido 802: "+money.ErrOverflow.Error())
+}
+
+func TestSyncChannelKeepsStockStateWhenStoreFails(t *testing.T) {
+	repo := newFakeRepo()
+	repo.channels[1] = &models.Channel{ID: 1, Platform: ecommerce.PlatformWooCommerce, Active: true}
+	connector := &fakeConnector{stockErr: assert.AnError}
+	s := newTestService(repo, connector)
+
+	runs, err := s.SyncChannel(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, syncNow.Add(-firstOrderSyncWindow), connector.since, "primeira sincronização lê os últimos 7 dias")
+	assert.Equal(t, models.SyncStatusFailed, runs[1].Status)
+	assert.NotContains(t, repo.state, "last_stock_sync_at")
+	assert.Contains(t, repo.state["last_error"], "stock: "+assert.AnError.Error())
+}
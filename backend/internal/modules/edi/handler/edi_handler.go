@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/edi/models"
+	"ERP-ONSMART/backend/internal/modules/edi/service"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Baixa a fatura em UBL 2.1 (Invoice), para importação no ERP do cliente
+// @Produce application/xml
+// @Param id path int true "ID da fatura"
+// @Security BearerAuth
+func DownloadInvoiceUBLHandler(c *gin.Context) {
+	downloadDocument(c, models.DocumentInvoice, "erro ao exportar fatura em UBL")
+}
+
+// Baixa a entrega em UBL 2.1 (DespatchAdvice), com transportadora, rastreamento e lotes
+// @Produce application/xml
+// @Param id path int true "ID da entrega"
+func DownloadDeliveryUBLHandler(c *gin.Context) {
+	downloadDocument(c, models.DocumentDelivery, "erro ao exportar entrega em UBL")
+}
+
+func downloadDocument(c *gin.Context, documentType, message string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	doc, err := service.Export(c.Request.Context(), documentType, id)
+	if err != nil {
+		c.Error(err).SetMeta(message)
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", doc.Content)
+}
+
+// Lista os parceiros EDI da empresa
+// @Security BearerAuth
+func ListPartnersHandler(c *gin.Context) {
+	partners, err := service.ListPartners(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar parceiros EDI")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"partners": partners})
+}
+
+// Cadastra um parceiro B2B que recebe faturas e entregas em UBL no próprio servidor SFTP; a
+// chave pública do servidor (host_key) é obrigatória e conferida em toda conexão
+// @Security BearerAuth
+func CreatePartnerHandler(c *gin.Context) {
+	var input models.PartnerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	partner, err := service.CreatePartner(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar parceiro EDI")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"partner": partner})
+}
+
+// Altera o parceiro EDI; senha e chave privada em branco mantêm as atuais
+// @Security BearerAuth
+func UpdatePartnerHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.PartnerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	partner, err := service.UpdatePartner(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar parceiro EDI")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"partner": partner})
+}
+
+// Envia uma fatura ou entrega em UBL ao servidor SFTP do parceiro; o envio fica registrado
+// mesmo quando o servidor recusa o arquivo
+// @Security BearerAuth
+func PushDocumentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.PushInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	transmission, err := service.Push(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao enviar documento ao parceiro EDI")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transmission": transmission})
+}
+
+// Lista os envios mais recentes ao parceiro, com o erro dos que falharam
+// @Security BearerAuth
+// @Param limit query int false "quantidade (padrão 20, máx. 100)"
+func ListTransmissionsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var limit int
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.Error(errors.InvalidParam("limit deve ser um número inteiro positivo"))
+			return
+		}
+		limit = parsed
+	}
+
+	transmissions, err := service.ListTransmissions(c.Request.Context(), id, limit)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar envios EDI")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transmissions": transmissions})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Documentos exportados em UBL 2.1
+const (
+	DocumentInvoice  = "invoice"  // fatura (Invoice)
+	DocumentDelivery = "delivery" // entrega (DespatchAdvice)
+)
+
+// Situação de um envio ao parceiro
+const (
+	TransmissionSent   = "sent"
+	TransmissionFailed = "failed"
+)
+
+// Partner é um parceiro B2B que recebe as faturas e entregas em UBL no seu servidor SFTP. A
+// senha e a chave privada não são expostas nas respostas; HostKey é a chave pública do
+// servidor (formato authorized_keys), conferida em toda conexão. Com ContactID, só os
+// documentos desse cliente podem ser enviados ao parceiro.
+type Partner struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	CompanyID  int       `json:"company_id" gorm:"<-:create"`
+	Name       string    `json:"name"`
+	ContactID  *int      `json:"contact_id"`
+	Host       string    `json:"host"`
+	Port       int       `json:"port"`
+	Username   string    `json:"username"`
+	Password   string    `json:"-"`
+	PrivateKey string    `json:"-"`
+	HostKey    string    `json:"host_key"`
+	RemoteDir  string    `json:"remote_dir"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Partner) TableName() string {
+	return "edi_partners"
+}
+
+// Transmission registra um envio de documento ao parceiro, bem-sucedido ou não
+type Transmission struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	CompanyID    int       `json:"company_id" gorm:"<-:create"`
+	PartnerID    int       `json:"partner_id"`
+	DocumentType string    `json:"document_type"`
+	DocumentID   int       `json:"document_id"`
+	FileName     string    `json:"file_name"`
+	SizeBytes    int       `json:"size_bytes"`
+	Status       string    `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	SentBy       string    `json:"sent_by"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (Transmission) TableName() string {
+	return "edi_transmissions"
+}
+
+// Document é um documento exportado, pronto para download ou envio
+type Document struct {
+	FileName  string
+	ContactID int
+	Content   []byte
+}
+
+// PartnerInput são os dados de cadastro de um parceiro; na alteração, credenciais vazias
+// mantêm as atuais
+type PartnerInput struct {
+	Name       string `json:"name" binding:"required,max=100"`
+	ContactID  *int   `json:"contact_id"`
+	Host       string `json:"host" binding:"required,hostname_rfc1123,max=255"`
+	Port       int    `json:"port" binding:"omitempty,min=1,max=65535"`
+	Username   string `json:"username" binding:"required,max=100"`
+	Password   string `json:"password"`
+	PrivateKey string `json:"private_key"`
+	HostKey    string `json:"host_key" binding:"required"`
+	RemoteDir  string `json:"remote_dir" binding:"max=255"`
+	Active     *bool  `json:"active"`
+}
+
+// PushInput indica o documento enviado ao parceiro
+type PushInput struct {
+	DocumentType string `json:"document_type" binding:"required,oneof=invoice delivery"`
+	DocumentID   int    `json:"document_id" binding:"required,gt=0"`
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	companies "ERP-ONSMART/backend/internal/modules/companies/models"
+	"ERP-ONSMART/backend/internal/modules/edi/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// EDIRepository define as operações dos parceiros EDI, dos envios e da leitura dos documentos
+// exportados
+type EDIRepository interface {
+	ListPartners(ctx context.Context) ([]models.Partner, error)
+	GetPartner(ctx context.Context, id int) (*models.Partner, error)
+	CreatePartner(ctx context.Context, partner *models.Partner) error
+	UpdatePartner(ctx context.Context, partner *models.Partner) error
+
+	SaveTransmission(ctx context.Context, transmission *models.Transmission) error
+	ListTransmissions(ctx context.Context, partnerID, limit int) ([]models.Transmission, error)
+
+	GetCompany(ctx context.Context) (*companies.Company, error)
+	GetInvoice(ctx context.Context, id int) (*sales.Invoice, error)
+	GetDelivery(ctx context.Context, id int) (*sales.Delivery, error)
+}
+
+type ediRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewEDIRepository cria uma nova instância do repositório
+func NewEDIRepository() (EDIRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &ediRepository{
+		db:     db,
+		logger: logger.WithModule("edi_repository"),
+	}, nil
+}
+
+// ListPartners retorna os parceiros da empresa
+func (r *ediRepository) ListPartners(ctx context.Context) ([]models.Partner, error) {
+	var partners []models.Partner
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&partners).Error; err != nil {
+		r.logger.Error("erro ao listar parceiros EDI", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar parceiros EDI")
+	}
+	return partners, nil
+}
+
+// GetPartner busca um parceiro pelo ID
+func (r *ediRepository) GetPartner(ctx context.Context, id int) (*models.Partner, error) {
+	var partner models.Partner
+	if err := r.db.WithContext(ctx).First(&partner, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrEDIPartnerNotFound
+		}
+		r.logger.Error("erro ao buscar parceiro EDI", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar parceiro EDI")
+	}
+	return &partner, nil
+}
+
+// CreatePartner grava um novo parceiro
+func (r *ediRepository) CreatePartner(ctx context.Context, partner *models.Partner) error {
+	if err := r.db.WithContext(ctx).Create(partner).Error; err != nil {
+		r.logger.Error("erro ao criar parceiro EDI", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar parceiro EDI")
+	}
+	return nil
+}
+
+// UpdatePartner grava os dados cadastrais do parceiro
+func (r *ediRepository) UpdatePartner(ctx context.Context, partner *models.Partner) error {
+	if err := r.db.WithContext(ctx).Model(partner).
+		Select("name", "contact_id", "host", "port", "username", "password", "private_key", "host_key", "remote_dir", "active").
+		Updates(partner).Error; err != nil {
+		r.logger.Error("erro ao atualizar parceiro EDI", zap.Error(err), zap.Int("id", partner.ID))
+		return errors.WrapError(err, "falha ao atualizar parceiro EDI")
+	}
+	return nil
+}
+
+// SaveTransmission registra um envio ao parceiro
+func (r *ediRepository) SaveTransmission(ctx context.Context, transmission *models.Transmission) error {
+	if err := r.db.WithContext(ctx).Create(transmission).Error; err != nil {
+		r.logger.Error("erro ao registrar envio EDI", zap.Error(err), zap.Int("partner_id", transmission.PartnerID))
+		return errors.WrapError(err, "falha ao registrar envio EDI")
+	}
+	return nil
+}
+
+// ListTransmissions retorna os envios mais recentes ao parceiro
+func (r *ediRepository) ListTransmissions(ctx context.Context, partnerID, limit int) ([]models.Transmission, error) {
+	var transmissions []models.Transmission
+	if err := r.db.WithContext(ctx).Where("partner_id = ?", partnerID).
+		Order("created_at DESC, id DESC").Limit(limit).
+		Find(&transmissions).Error; err != nil {
+		r.logger.Error("erro ao listar envios EDI", zap.Error(err), zap.Int("partner_id", partnerID))
+		return nil, errors.WrapError(err, "falha ao listar envios EDI")
+	}
+	return transmissions, nil
+}
+
+// GetCompany busca a empresa da requisição, emitente dos documentos exportados
+func (r *ediRepository) GetCompany(ctx context.Context) (*companies.Company, error) {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		companyID = tenant.DefaultCompanyID
+	}
+
+	var company companies.Company
+	if err := r.db.WithContext(ctx).First(&company, companyID).Error; err != nil {
+		r.logger.Error("erro ao buscar empresa emitente", zap.Error(err), zap.Int("company_id", companyID))
+		return nil, errors.WrapError(err, "falha ao buscar empresa emitente")
+	}
+	return &company, nil
+}
+
+// GetInvoice busca a fatura com o contato e os itens
+func (r *ediRepository) GetInvoice(ctx context.Context, id int) (*sales.Invoice, error) {
+	var invoice sales.Invoice
+	if err := r.db.WithContext(ctx).Preload("Contact").Preload("Items").First(&invoice, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		r.logger.Error("erro ao buscar fatura para exportação", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar fatura")
+	}
+	return &invoice, nil
+}
+
+// GetDelivery busca a entrega com os itens e o contato do pedido de venda
+func (r *ediRepository) GetDelivery(ctx context.Context, id int) (*sales.Delivery, error) {
+	var delivery sales.Delivery
+	if err := r.db.WithContext(ctx).Preload("Items").Preload("SalesOrder.Contact").First(&delivery, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		r.logger.Error("erro ao buscar entrega para exportação", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar entrega")
+	}
+	return &delivery, nil
+}
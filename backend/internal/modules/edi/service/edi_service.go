@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/sftp"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/edi/models"
+	"ERP-ONSMART/backend/internal/modules/edi/repository"
+	"ERP-ONSMART/backend/internal/modules/edi/ubl"
+
+	"go.uber.org/zap"
+)
+
+// maxTransmissionsLimit limita a quantidade de envios listados por parceiro
+const maxTransmissionsLimit = 100
+
+// Service exporta faturas e entregas em UBL 2.1 e as envia aos parceiros B2B por SFTP
+type Service struct {
+	newRepo func() (repository.EDIRepository, error)
+	upload  func(ctx context.Context, cfg sftp.Config, remotePath string, data []byte) error
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.EDIRepository
+}
+
+// NewService cria o serviço sobre o repositório informado, com o cliente SFTP do pacote sftp
+func NewService(newRepo func() (repository.EDIRepository, error)) *Service {
+	return &Service{
+		newRepo: newRepo,
+		upload:  sftp.Upload,
+		logger:  logger.WithModule("edi_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewEDIRepository)
+
+// ListPartners lista os parceiros da empresa da requisição
+func ListPartners(ctx context.Context) ([]models.Partner, error) {
+	return defaultService.ListPartners(ctx)
+}
+
+// CreatePartner cadastra um parceiro na empresa da requisição
+func CreatePartner(ctx context.Context, input models.PartnerInput) (*models.Partner, error) {
+	return defaultService.CreatePartner(ctx, input)
+}
+
+// UpdatePartner altera o cadastro de um parceiro
+func UpdatePartner(ctx context.Context, id int, input models.PartnerInput) (*models.Partner, error) {
+	return defaultService.UpdatePartner(ctx, id, input)
+}
+
+// ListTransmissions retorna os envios mais recentes ao parceiro
+func ListTransmissions(ctx context.Context, id, limit int) ([]models.Transmission, error) {
+	return defaultService.ListTransmissions(ctx, id, limit)
+}
+
+// Export gera o UBL da fatura ou da entrega
+func Export(ctx context.Context, documentType string, id int) (*models.Document, error) {
+	return defaultService.Export(ctx, documentType, id)
+}
+
+// Push envia o documento ao servidor SFTP do parceiro
+func Push(ctx context.Context, partnerID int, input models.PushInput, sentBy string) (*models.Transmission, error) {
+	return defaultService.Push(ctx, partnerID, input, sentBy)
+}
+
+func (s *Service) repository() (repository.EDIRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListPartners lista os parceiros da empresa
+func (s *Service) ListPartners(ctx context.Context) ([]models.Partner, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListPartners(ctx)
+}
+
+// CreatePartner valida as chaves e grava o parceiro, ativo por padrão
+func (s *Service) CreatePartner(ctx context.Context, input models.PartnerInput) (*models.Partner, error) {
+	partner := &models.Partner{Active: true}
+	if err := applyInput(partner, input); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CreatePartner(ctx, partner); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("parceiro EDI criado", zap.Int("id", partner.ID), zap.String("host", partner.Host))
+	return partner, nil
+}
+
+// UpdatePartner altera o parceiro; credenciais em branco mantêm as gravadas
+func (s *Service) UpdatePartner(ctx context.Context, id int, input models.PartnerInput) (*models.Partner, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	partner, err := repo.GetPartner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyInput(partner, input); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdatePartner(ctx, partner); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("parceiro EDI atualizado", zap.Int("id", partner.ID))
+	return partner, nil
+}
+
+func applyInput(partner *models.Partner, input models.PartnerInput) error {
+	partner.Name = strings.TrimSpace(input.Name)
+	partner.ContactID = input.ContactID
+	partner.Host = strings.TrimSpace(input.Host)
+	partner.Port = input.Port
+	if partner.Port == 0 {
+		partner.Port = sftp.DefaultPort
+	}
+	partner.Username = strings.TrimSpace(input.Username)
+	partner.HostKey = strings.TrimSpace(input.HostKey)
+	partner.RemoteDir = strings.TrimSpace(input.RemoteDir)
+	if input.Password != "" {
+		partner.Password = input.Password
+	}
+	if input.PrivateKey != "" {
+		partner.PrivateKey = input.PrivateKey
+	}
+	if input.Active != nil {
+		partner.Active = *input.Active
+	}
+
+	if partner.Password == "" && partner.PrivateKey == "" {
+		return errors.ErrEDICredentialsRequired
+	}
+	if err := sftpConfig(partner).Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidEDIKey, err)
+	}
+	return nil
+}
+
+func sftpConfig(partner *models.Partner) sftp.Config {
+	return sftp.Config{
+		Host:       partner.Host,
+		Port:       partner.Port,
+		User:       partner.Username,
+		Password:   partner.Password,
+		PrivateKey: partner.PrivateKey,
+		HostKey:    partner.HostKey,
+	}
+}
+
+// ListTransmissions retorna os envios mais recentes ao parceiro da empresa (padrão 20, máximo 100)
+func (s *Service) ListTransmissions(ctx context.Context, id, limit int) ([]models.Transmission, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxTransmissionsLimit {
+		limit = maxTransmissionsLimit
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	// Garante que o parceiro pertence à empresa da requisição
+	if _, err := repo.GetPartner(ctx, id); err != nil {
+		return nil, err
+	}
+	return repo.ListTransmissions(ctx, id, limit)
+}
+
+// Export gera o UBL 2.1 do documento: Invoice para faturas e DespatchAdvice para entregas, com
+// a empresa da requisição como emitente
+func (s *Service) Export(ctx context.Context, documentType string, id int) (*models.Document, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	company, err := repo.GetCompany(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch documentType {
+	case models.DocumentInvoice:
+		invoice, err := repo.GetInvoice(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		content, err := ubl.Invoice(invoice, company)
+		if err != nil {
+			return nil, errors.WrapError(err, "falha ao gerar UBL da fatura")
+		}
+		return &models.Document{
+			FileName:  ubl.FileName(documentType, invoice.InvoiceNo),
+			ContactID: invoice.ContactID,
+			Content:   content,
+		}, nil
+
+	case models.DocumentDelivery:
+		delivery, err := repo.GetDelivery(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		doc := &models.Document{FileName: ubl.FileName(documentType, delivery.DeliveryNo)}
+		if delivery.SalesOrder != nil {
+			doc.ContactID = delivery.SalesOrder.ContactID
+			doc.Content, err = ubl.DespatchAdvice(delivery, company, delivery.SalesOrder.Contact)
+		} else {
+			doc.Content, err = ubl.DespatchAdvice(delivery, company, nil)
+		}
+		if err != nil {
+			return nil, errors.WrapError(err, "falha ao gerar UBL da entrega")
+		}
+		return doc, nil
+	}
+	return nil, errors.InvalidParam("tipo de documento inválido: use invoice ou delivery")
+}
+
+// Push gera o documento e o grava no diretório do parceiro ativo. Todo envio fica registrado,
+// inclusive os que falharam, com o erro do servidor.
+func (s *Service) Push(ctx context.Context, partnerID int, input models.PushInput, sentBy string) (*models.Transmission, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	partner, err := repo.GetPartner(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !partner.Active {
+		return nil, errors.ErrEDIPartnerInactive
+	}
+
+	doc, err := s.Export(ctx, input.DocumentType, input.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if partner.ContactID != nil && *partner.ContactID != doc.ContactID {
+		return nil, errors.ErrEDIDocumentNotAllowed
+	}
+
+	transmission := &models.Transmission{
+		PartnerID:    partner.ID,
+		DocumentType: input.DocumentType,
+		DocumentID:   input.DocumentID,
+		FileName:     doc.FileName,
+		SizeBytes:    len(doc.Content),
+		Status:       models.TransmissionSent,
+		SentBy:       sentBy,
+	}
+	uploadErr := s.upload(ctx, sftpConfig(partner), path.Join(partner.RemoteDir, doc.FileName), doc.Content)
+	if uploadErr != nil {
+		transmission.Status = models.TransmissionFailed
+		transmission.Error = uploadErr.Error()
+	}
+	if err := repo.SaveTransmission(ctx, transmission); err != nil {
+		return nil, err
+	}
+
+	if uploadErr != nil {
+		s.logger.Warn("falha no envio EDI", zap.Error(uploadErr), zap.Int("partner_id", partner.ID),
+			zap.String("file", doc.FileName))
+		return transmission, fmt.Errorf("%w: %v", errors.ErrEDITransmissionFailed, uploadErr)
+	}
+	s.logger.Info("documento enviado ao parceiro EDI", zap.Int("partner_id", partner.ID),
+		zap.String("file", doc.FileName), zap.Int("bytes", transmission.SizeBytes))
+	return transmission, nil
+}
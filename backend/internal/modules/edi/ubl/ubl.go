@@ -0,0 +1,397 @@
+package ubl
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+
+	companies "ERP-ONSMART/backend/internal/modules/companies/models"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/validation"
+)
+
+// Versão e namespaces do UBL 2.1 (OASIS)
+const (
+	Version            = "2.1"
+	InvoiceNamespace   = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	DespatchNamespace  = "urn:oasis:names:specification:ubl:schema:xsd:DespatchAdvice-2"
+	AggregateNamespace = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	BasicNamespace     = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+)
+
+const (
+	commercialInvoiceCode = "380" // fatura comercial (UNCL 1001)
+	dateLayout            = "2006-01-02"
+	defaultUnitCode       = "C62"
+	countryCode           = "BR"
+	cnpjScheme            = "BR:CNPJ"
+	cpfScheme             = "BR:CPF"
+	discountReason        = "Desconto"
+)
+
+// unitCodes traduz as unidades do cadastro para os códigos da recomendação 20 da UN/ECE; as
+// demais saem como C62 (unidade)
+var unitCodes = map[string]string{
+	"UN": "C62",
+	"PC": "H87",
+	"PÇ": "H87",
+	"CX": "XBX",
+	"KG": "KGM",
+	"G":  "GRM",
+	"L":  "LTR",
+	"LT": "LTR",
+	"ML": "MLT",
+	"M":  "MTR",
+	"M2": "MTK",
+	"M3": "MTQ",
+	"H":  "HUR",
+}
+
+type amount struct {
+	Currency string `xml:"currencyID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type quantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    int    `xml:",chardata"`
+}
+
+type identifier struct {
+	SchemeID string `xml:"schemeID,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type party struct {
+	Identification *identifier    `xml:"cac:PartyIdentification>cbc:ID,omitempty"`
+	Name           string         `xml:"cac:PartyName>cbc:Name"`
+	Address        *postalAddress `xml:"cac:PostalAddress,omitempty"`
+	LegalName      string         `xml:"cac:PartyLegalEntity>cbc:RegistrationName"`
+	Contact        *partyContact  `xml:"cac:Contact,omitempty"`
+}
+
+type postalAddress struct {
+	StreetName     string       `xml:"cbc:StreetName,omitempty"`
+	Complement     string       `xml:"cbc:AdditionalStreetName,omitempty"`
+	BuildingNumber string       `xml:"cbc:BuildingNumber,omitempty"`
+	District       string       `xml:"cbc:CitySubdivisionName,omitempty"`
+	CityName       string       `xml:"cbc:CityName,omitempty"`
+	PostalZone     string       `xml:"cbc:PostalZone,omitempty"`
+	State          string       `xml:"cbc:CountrySubentity,omitempty"`
+	Line           *addressLine `xml:"cac:AddressLine,omitempty"`
+	Country        string       `xml:"cac:Country>cbc:IdentificationCode"`
+}
+
+type addressLine struct {
+	Line string `xml:"cbc:Line"`
+}
+
+type partyContact struct {
+	Telephone string `xml:"cbc:Telephone,omitempty"`
+	Email     string `xml:"cbc:ElectronicMail,omitempty"`
+}
+
+type orderReference struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type item struct {
+	Description string `xml:"cbc:Description,omitempty"`
+	Name        string `xml:"cbc:Name"`
+	SellersID   string `xml:"cac:SellersItemIdentification>cbc:ID,omitempty"`
+	LotNumber   string `xml:"cac:ItemInstance>cac:LotIdentification>cbc:LotNumberID,omitempty"`
+}
+
+type allowance struct {
+	ChargeIndicator bool   `xml:"cbc:ChargeIndicator"`
+	Reason          string `xml:"cbc:AllowanceChargeReason"`
+	Amount          amount `xml:"cbc:Amount"`
+}
+
+type taxTotal struct {
+	TaxAmount amount `xml:"cbc:TaxAmount"`
+}
+
+type monetaryTotal struct {
+	LineExtension amount `xml:"cbc:LineExtensionAmount"`
+	TaxExclusive  amount `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusive  amount `xml:"cbc:TaxInclusiveAmount"`
+	Prepaid       amount `xml:"cbc:PrepaidAmount"`
+	Payable       amount `xml:"cbc:PayableAmount"`
+}
+
+type invoiceLine struct {
+	ID            string      `xml:"cbc:ID"`
+	Quantity      quantity    `xml:"cbc:InvoicedQuantity"`
+	LineExtension amount      `xml:"cbc:LineExtensionAmount"`
+	Allowance     *allowance  `xml:"cac:AllowanceCharge,omitempty"`
+	TaxTotal      *taxTotal   `xml:"cac:TaxTotal,omitempty"`
+	Item          item        `xml:"cac:Item"`
+	Price         priceAmount `xml:"cac:Price"`
+}
+
+type priceAmount struct {
+	Amount amount `xml:"cbc:PriceAmount"`
+}
+
+type invoice struct {
+	XMLName        xml.Name        `xml:"Invoice"`
+	Namespace      string          `xml:"xmlns,attr"`
+	Aggregate      string          `xml:"xmlns:cac,attr"`
+	Basic          string          `xml:"xmlns:cbc,attr"`
+	UBLVersionID   string          `xml:"cbc:UBLVersionID"`
+	ID             string          `xml:"cbc:ID"`
+	IssueDate      string          `xml:"cbc:IssueDate"`
+	DueDate        string          `xml:"cbc:DueDate"`
+	TypeCode       string          `xml:"cbc:InvoiceTypeCode"`
+	Note           string          `xml:"cbc:Note,omitempty"`
+	CurrencyCode   string          `xml:"cbc:DocumentCurrencyCode"`
+	OrderReference *orderReference `xml:"cac:OrderReference,omitempty"`
+	Supplier       party           `xml:"cac:AccountingSupplierParty>cac:Party"`
+	Customer       party           `xml:"cac:AccountingCustomerParty>cac:Party"`
+	PaymentTerms   string          `xml:"cac:PaymentTerms>cbc:Note,omitempty"`
+	TaxTotal       taxTotal        `xml:"cac:TaxTotal"`
+	Totals         monetaryTotal   `xml:"cac:LegalMonetaryTotal"`
+	Lines          []invoiceLine   `xml:"cac:InvoiceLine"`
+}
+
+type consignment struct {
+	ID                string `xml:"cbc:ID"`
+	CarrierAssignedID string `xml:"cbc:CarrierAssignedID,omitempty"`
+	CarrierName       string `xml:"cac:CarrierParty>cac:PartyName>cbc:Name,omitempty"`
+}
+
+type shipment struct {
+	ID              string         `xml:"cbc:ID"`
+	Information     string         `xml:"cbc:Information,omitempty"`
+	Consignment     *consignment   `xml:"cac:Consignment,omitempty"`
+	DeliveryAddress *postalAddress `xml:"cac:Delivery>cac:DeliveryAddress,omitempty"`
+}
+
+type despatchLine struct {
+	ID                string   `xml:"cbc:ID"`
+	DeliveredQuantity quantity `xml:"cbc:DeliveredQuantity"`
+	OrderLineID       string   `xml:"cac:OrderLineReference>cbc:LineID"`
+	Item              item     `xml:"cac:Item"`
+}
+
+type despatchAdvice struct {
+	XMLName        xml.Name        `xml:"DespatchAdvice"`
+	Namespace      string          `xml:"xmlns,attr"`
+	Aggregate      string          `xml:"xmlns:cac,attr"`
+	Basic          string          `xml:"xmlns:cbc,attr"`
+	UBLVersionID   string          `xml:"cbc:UBLVersionID"`
+	ID             string          `xml:"cbc:ID"`
+	IssueDate      string          `xml:"cbc:IssueDate"`
+	Note           string          `xml:"cbc:Note,omitempty"`
+	OrderReference *orderReference `xml:"cac:OrderReference,omitempty"`
+	Supplier       party           `xml:"cac:DespatchSupplierParty>cac:Party"`
+	Customer       party           `xml:"cac:DeliveryCustomerParty>cac:Party"`
+	Shipment       shipment        `xml:"cac:Shipment"`
+	Lines          []despatchLine  `xml:"cac:DespatchLine"`
+}
+
+// Invoice converte a fatura (com contato e itens carregados) no documento Invoice do UBL 2.1.
+// As linhas saem pelo valor líquido do desconto, com o desconto e o imposto destacados; o valor
+// já pago vira PrepaidAmount.
+func Invoice(inv *sales.Invoice, supplier *companies.Company) ([]byte, error) {
+	currency := string(money.Default)
+	doc := invoice{
+		Namespace:    InvoiceNamespace,
+		Aggregate:    AggregateNamespace,
+		Basic:        BasicNamespace,
+		UBLVersionID: Version,
+		ID:           inv.InvoiceNo,
+		IssueDate:    formatDate(inv.IssueDate),
+		DueDate:      formatDate(inv.DueDate),
+		TypeCode:     commercialInvoiceCode,
+		Note:         inv.Notes,
+		CurrencyCode: currency,
+		Supplier:     supplierParty(supplier),
+		Customer:     customerParty(inv.Contact),
+		PaymentTerms: inv.PaymentTerms,
+		TaxTotal:     taxTotal{TaxAmount: formatAmount(inv.TaxTotal, currency)},
+		Totals: monetaryTotal{
+			LineExtension: formatAmount(inv.SubTotal.Sub(inv.DiscountTotal), currency),
+			TaxExclusive:  formatAmount(inv.SubTotal.Sub(inv.DiscountTotal), currency),
+			TaxInclusive:  formatAmount(inv.GrandTotal, currency),
+			Prepaid:       formatAmount(inv.AmountPaid, currency),
+			Payable:       formatAmount(inv.GrandTotal.Sub(inv.AmountPaid), currency),
+		},
+	}
+	if inv.SONo != "" {
+		doc.OrderReference = &orderReference{ID: inv.SONo}
+	}
+
+	for i, it := range inv.Items {
+		line := invoiceLine{
+			ID:            lineID(i),
+			Quantity:      quantity{UnitCode: unitCode(it.Unit), Value: it.Quantity},
+			LineExtension: formatAmount(it.UnitPrice.MulInt(it.Quantity).Sub(it.Discount), currency),
+			Item:          item{Description: it.Description, Name: it.ProductName, SellersID: it.ProductCode},
+			Price:         priceAmount{Amount: formatAmount(it.UnitPrice, currency)},
+		}
+		if it.Discount.IsPositive() {
+			line.Allowance = &allowance{Reason: discountReason, Amount: formatAmount(it.Discount, currency)}
+		}
+		if !it.Tax.IsZero() {
+			line.TaxTotal = &taxTotal{TaxAmount: formatAmount(it.Tax, currency)}
+		}
+		doc.Lines = append(doc.Lines, line)
+	}
+	return encode(doc)
+}
+
+// DespatchAdvice converte a entrega (com itens carregados) no aviso de despacho do UBL 2.1,
+// com a transportadora, o rastreamento e o endereço de entrega
+func DespatchAdvice(delivery *sales.Delivery, supplier *companies.Company, customer *contact.Contact) ([]byte, error) {
+	issued := delivery.DeliveryDate
+	if delivery.ShippedAt != nil {
+		issued = *delivery.ShippedAt
+	}
+	doc := despatchAdvice{
+		Namespace:    DespatchNamespace,
+		Aggregate:    AggregateNamespace,
+		Basic:        BasicNamespace,
+		UBLVersionID: Version,
+		ID:           delivery.DeliveryNo,
+		IssueDate:    formatDate(issued),
+		Note:         delivery.Notes,
+		Supplier:     supplierParty(supplier),
+		Customer:     customerParty(customer),
+		Shipment:     shipment{ID: delivery.DeliveryNo, Information: delivery.ShippingMethod},
+	}
+	if delivery.SONo != "" {
+		doc.OrderReference = &orderReference{ID: delivery.SONo}
+	}
+	if delivery.TrackingNumber != "" || delivery.Carrier != "" {
+		doc.Shipment.Consignment = &consignment{
+			ID:                firstNonEmpty(delivery.TrackingNumber, delivery.DeliveryNo),
+			CarrierAssignedID: delivery.TrackingNumber,
+			CarrierName:       delivery.Carrier,
+		}
+	}
+	if address := strings.TrimSpace(delivery.ShippingAddress); address != "" {
+		doc.Shipment.DeliveryAddress = &postalAddress{Line: &addressLine{Line: address}, Country: countryCode}
+	}
+
+	for i, it := range delivery.Items {
+		// A linha do pedido é o item do pedido de venda; entregas sem pedido repetem a própria linha
+		orderLine := lineID(i)
+		if it.SOItemID != nil {
+			orderLine = strconv.Itoa(*it.SOItemID)
+		}
+		doc.Lines = append(doc.Lines, despatchLine{
+			ID:                lineID(i),
+			DeliveredQuantity: quantity{UnitCode: unitCode(it.Unit), Value: it.Quantity},
+			OrderLineID:       orderLine,
+			Item: item{
+				Description: it.Description,
+				Name:        it.ProductName,
+				SellersID:   it.ProductCode,
+				LotNumber:   it.LotNumber,
+			},
+		})
+	}
+	return encode(doc)
+}
+
+// FileName é o nome do arquivo do documento: o tipo e o número, sem caracteres que não valem
+// em nomes de arquivo
+func FileName(documentType, number string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, number)
+	return documentType + "-" + safe + ".xml"
+}
+
+func encode(doc any) ([]byte, error) {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func supplierParty(company *companies.Company) party {
+	p := party{Name: company.Name, LegalName: firstNonEmpty(company.LegalName, company.Name)}
+	if company.Document != nil {
+		p.Identification = documentID(*company.Document)
+	}
+	return p
+}
+
+func customerParty(c *contact.Contact) party {
+	if c == nil {
+		return party{}
+	}
+	name := firstNonEmpty(c.TradeName, c.Name)
+	p := party{
+		Identification: documentID(c.Document),
+		Name:           name,
+		LegalName:      firstNonEmpty(c.CompanyName, c.Name),
+		Address: &postalAddress{
+			StreetName:     c.Street,
+			Complement:     c.Complement,
+			BuildingNumber: c.Number,
+			District:       c.Neighborhood,
+			CityName:       c.City,
+			PostalZone:     validation.OnlyDigits(c.ZipCode),
+			State:          c.State,
+			Country:        countryCode,
+		},
+	}
+	if c.Phone != "" || c.Email != "" {
+		p.Contact = &partyContact{Telephone: c.Phone, Email: c.Email}
+	}
+	return p
+}
+
+// documentID identifica a parte pelo CNPJ ou CPF, conforme a quantidade de dígitos
+func documentID(document string) *identifier {
+	digits := validation.OnlyDigits(document)
+	switch len(digits) {
+	case 14:
+		return &identifier{SchemeID: cnpjScheme, Value: digits}
+	case 11:
+		return &identifier{SchemeID: cpfScheme, Value: digits}
+	case 0:
+		return nil
+	}
+	return &identifier{Value: digits}
+}
+
+func unitCode(unit string) string {
+	if code, ok := unitCodes[strings.ToUpper(strings.TrimSpace(unit))]; ok {
+		return code
+	}
+	return defaultUnitCode
+}
+
+func formatAmount(value money.Decimal, currency string) amount {
+	return amount{Currency: currency, Value: value.StringFixed(money.Currency(currency).Places())}
+}
+
+func formatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+func lineID(index int) string {
+	return strconv.Itoa(index + 1)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+package ubl
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	companies "ERP-ONSMART/backend/internal/modules/companies/models"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSupplier() *companies.Company {
+	cnpj := "11.222.333/0001-81"
+	return &companies.Company{ID: 1, Name: "OnSmart", LegalName: "OnSmart Tecnologia Ltda", Document: &cnpj}
+}
+
+func testCustomer() *contact.Contact {
+	return &contact.Contact{
+		PersonType: "pj", Name: "Atacado Central", CompanyName: "Atacado Central S.A.",
+		Document: "44.555.666/0001-10", Email: "edi@atacado.example", ZipCode: "01001-000",
+		Street: "Praça da Sé", Number: "100", Neighborhood: "Sé", City: "São Paulo", State: "SP",
+	}
+}
+
+// Os elementos são lidos pelo nome local, como faria um parser UBL do parceiro
+type parsedAmount struct {
+	Currency string `xml:"currencyID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type parsedParty struct {
+	ID struct {
+		Scheme string `xml:"schemeID,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"Party>PartyIdentification>ID"`
+	Name       string `xml:"Party>PartyName>Name"`
+	PostalZone string `xml:"Party>PostalAddress>PostalZone"`
+	Country    string `xml:"Party>PostalAddress>Country>IdentificationCode"`
+	Email      string `xml:"Party>Contact>ElectronicMail"`
+}
+
+func TestInvoiceMapsTotalsPartiesAndLines(t *testing.T) {
+	inv := &sales.Invoice{
+		InvoiceNo:     "INV-2030/0001",
+		SONo:          "SO-0007",
+		IssueDate:     time.Date(2030, 1, 5, 10, 0, 0, 0, time.UTC),
+		DueDate:       time.Date(2030, 2, 4, 0, 0, 0, 0, time.UTC),
+		PaymentTerms:  "30 dias",
+		SubTotal:      money.FromInt(250),
+		DiscountTotal: money.FromInt(10),
+		TaxTotal:      money.MustParse("43.2"),
+		GrandTotal:    money.MustParse("283.2"),
+		AmountPaid:    money.FromInt(100),
+		Contact:       testCustomer(),
+		Items: []sales.InvoiceItem{
+			{ProductName: "Teclado", ProductCode: "TEC-01", Quantity: 2, Unit: "UN",
+				UnitPrice: money.FromInt(100), Discount: money.FromInt(10), Tax: money.MustParse("43.2")},
+			{ProductName: "Cabo", ProductCode: "CAB-02", Quantity: 1, Unit: "kg", UnitPrice: money.FromInt(50)},
+		},
+	}
+
+	out, err := Invoice(inv, testSupplier())
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `<Invoice xmlns="`+InvoiceNamespace+`" xmlns:cac="`+AggregateNamespace+`" xmlns:cbc="`+BasicNamespace+`">`)
+
+	var parsed struct {
+		XMLName      xml.Name
+		Version      string       `xml:"UBLVersionID"`
+		ID           string       `xml:"ID"`
+		IssueDate    string       `xml:"IssueDate"`
+		DueDate      string       `xml:"DueDate"`
+		TypeCode     string       `xml:"InvoiceTypeCode"`
+		Currency     string       `xml:"DocumentCurrencyCode"`
+		OrderRef     string       `xml:"OrderReference>ID"`
+		Supplier     parsedParty  `xml:"AccountingSupplierParty"`
+		Customer     parsedParty  `xml:"AccountingCustomerParty"`
+		PaymentTerms string       `xml:"PaymentTerms>Note"`
+		Tax          parsedAmount `xml:"TaxTotal>TaxAmount"`
+		LineExt      parsedAmount `xml:"LegalMonetaryTotal>LineExtensionAmount"`
+		TaxIncl      parsedAmount `xml:"LegalMonetaryTotal>TaxInclusiveAmount"`
+		Prepaid      parsedAmount `xml:"LegalMonetaryTotal>PrepaidAmount"`
+		Payable      parsedAmount `xml:"LegalMonetaryTotal>PayableAmount"`
+		Lines        []struct {
+			ID       string `xml:"ID"`
+			Quantity struct {
+				Unit  string `xml:"unitCode,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"InvoicedQuantity"`
+			LineExt   parsedAmount  `xml:"LineExtensionAmount"`
+			Allowance *parsedAmount `xml:"AllowanceCharge>Amount"`
+			Name      string        `xml:"Item>Name"`
+			SellersID string        `xml:"Item>SellersItemIdentification>ID"`
+			Price     parsedAmount  `xml:"Price>PriceAmount"`
+		} `xml:"InvoiceLine"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &parsed))
+
+	assert.Equal(t, InvoiceNamespace, parsed.XMLName.Space)
+	assert.Equal(t, "2.1", parsed.Version)
+	assert.Equal(t, "INV-2030/0001", parsed.ID)
+	assert.Equal(t, "2030-01-05", parsed.IssueDate)
+	assert.Equal(t, "2030-02-04", parsed.DueDate)
+	assert.Equal(t, "380", parsed.TypeCode)
+	assert.Equal(t, "BRL", parsed.Currency)
+	assert.Equal(t, "SO-0007", parsed.OrderRef)
+	assert.Equal(t, "30 dias", parsed.PaymentTerms)
+
+	assert.Equal(t, "BR:CNPJ", parsed.Supplier.ID.Scheme)
+	assert.Equal(t, "11222333000181", parsed.Supplier.ID.Value)
+	assert.Equal(t, "OnSmart", parsed.Supplier.Name)
+	assert.Equal(t, "44555666000110", parsed.Customer.ID.Value)
+	assert.Equal(t, "01001000", parsed.Customer.PostalZone)
+	assert.Equal(t, "BR", parsed.Customer.Country)
+	assert.Equal(t, "edi@atacado.example", parsed.Customer.Email)
+
+	assert.Equal(t, parsedAmount{"BRL", "43.20"}, parsed.Tax)
+	assert.Equal(t, parsedAmount{"BRL", "240.00"}, parsed.LineExt)
+	assert.Equal(t, parsedAmount{"BRL", "283.20"}, parsed.TaxIncl)
+	assert.Equal(t, parsedAmount{"BRL", "100.00"}, parsed.Prepaid)
+	assert.Equal(t, parsedAmount{"BRL", "183.20"}, parsed.Payable)
+
+	require.Len(t, parsed.Lines, 2)
+	assert.Equal(t, "1", parsed.Lines[0].ID)
+	assert.Equal(t, "C62", parsed.Lines[0].Quantity.Unit)
+	assert.Equal(t, "2", parsed.Lines[0].Quantity.Value)
+	assert.Equal(t, "190.00", parsed.Lines[0].LineExt.Value)
+	require.NotNil(t, parsed.Lines[0].Allowance)
+	assert.Equal(t, "10.00", parsed.Lines[0].Allowance.Value)
+	assert.Equal(t, "Teclado", parsed.Lines[0].Name)
+	assert.Equal(t, "TEC-01", parsed.Lines[0].SellersID)
+	assert.Equal(t, "100.00", parsed.Lines[0].Price.Value)
+	assert.Equal(t, "KGM", parsed.Lines[1].Quantity.Unit)
+	assert.Nil(t, parsed.Lines[1].Allowance)
+}
+
+func TestDespatchAdviceMapsShipmentAndLines(t *testing.T) {
+	shipped := time.Date(2030, 1, 8, 15, 0, 0, 0, time.UTC)
+	soItem := 42
+	delivery := &sales.Delivery{
+		DeliveryNo:      "DEL-0003",
+		SONo:            "SO-0007",
+		DeliveryDate:    time.Date(2030, 1, 6, 0, 0, 0, 0, time.UTC),
+		ShippedAt:       &shipped,
+		ShippingMethod:  "SEDEX",
+		Carrier:         "correios",
+		TrackingNumber:  "BR123456789BR",
+		ShippingAddress: "Praça da Sé, 100 - São Paulo/SP",
+		Items: []sales.DeliveryItem{
+			{SOItemID: &soItem, ProductName: "Teclado", ProductCode: "TEC-01", Quantity: 2, Unit: "UN", LotNumber: "L-77"},
+		},
+	}
+
+	out, err := DespatchAdvice(delivery, testSupplier(), testCustomer())
+	require.NoError(t, err)
+
+	var parsed struct {
+		XMLName   xml.Name
+		ID        string      `xml:"ID"`
+		IssueDate string      `xml:"IssueDate"`
+		OrderRef  string      `xml:"OrderReference>ID"`
+		Supplier  parsedParty `xml:"DespatchSupplierParty"`
+		Customer  parsedParty `xml:"DeliveryCustomerParty"`
+		Shipment  struct {
+			ID          string `xml:"ID"`
+			Information string `xml:"Information"`
+			Tracking    string `xml:"Consignment>CarrierAssignedID"`
+			Carrier     string `xml:"Consignment>CarrierParty>PartyName>Name"`
+			Address     string `xml:"Delivery>DeliveryAddress>AddressLine>Line"`
+		} `xml:"Shipment"`
+		Lines []struct {
+			Quantity  string `xml:"DeliveredQuantity"`
+			OrderLine string `xml:"OrderLineReference>LineID"`
+			Lot       string `xml:"Item>ItemInstance>LotIdentification>LotNumberID"`
+		} `xml:"DespatchLine"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &parsed))
+
+	assert.Equal(t, DespatchNamespace, parsed.XMLName.Space)
+	assert.Equal(t, "DespatchAdvice", parsed.XMLName.Local)
+	assert.Equal(t, "DEL-0003", parsed.ID)
+	assert.Equal(t, "2030-01-08", parsed.IssueDate)
+	assert.Equal(t, "SO-0007", parsed.OrderRef)
+	assert.Equal(t, "Atacado Central", parsed.Customer.Name)
+	assert.Equal(t, "SEDEX", parsed.Shipment.Information)
+	assert.Equal(t, "BR123456789BR", parsed.Shipment.Tracking)
+	assert.Equal(t, "correios", parsed.Shipment.Carrier)
+	assert.Equal(t, "Praça da Sé, 100 - São Paulo/SP", parsed.Shipment.Address)
+	require.Len(t, parsed.Lines, 1)
+	assert.Equal(t, "2", parsed.Lines[0].Quantity)
+	assert.Equal(t, "42", parsed.Lines[0].OrderLine)
+	assert.Equal(t, "L-77", parsed.Lines[0].Lot)
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "invoice-INV-2030_0001.xml", FileName("invoice", "INV-2030/0001"))
+	assert.Equal(t, "delivery-DEL_3.xml", FileName("delivery", "DEL 3"))
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/etl/models"
+	"ERP-ONSMART/backend/internal/modules/etl/service"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize limita o tamanho do arquivo enviado para importação
+const maxImportFileSize = 20 << 20
+
+// Lista os mapeamentos de importação da empresa e os campos aceitos por destino
+// @Security BearerAuth
+func ListMappingsHandler(c *gin.Context) {
+	mappings, err := service.ListMappings(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar mapeamentos de importação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mappings": mappings, "targets": models.Targets, "transforms": models.Transforms})
+}
+
+// Retorna um mapeamento de importação
+// @Security BearerAuth
+func GetMappingHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	mapping, err := service.GetMapping(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar mapeamento de importação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mapping": mapping})
+}
+
+// Cadastra um mapeamento das colunas de um arquivo CSV ou JSON para contatos, produtos ou pedidos de venda
+// @Security BearerAuth
+func CreateMappingHandler(c *gin.Context) {
+	var input models.MappingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	mapping, err := service.CreateMapping(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar mapeamento de importação")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mapping": mapping})
+}
+
+// Altera um mapeamento de importação
+// @Security BearerAuth
+func UpdateMappingHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.MappingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	mapping, err := service.UpdateMapping(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar mapeamento de importação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mapping": mapping})
+}
+
+// Exclui um mapeamento de importação e o histórico das suas execuções
+// @Security BearerAuth
+func DeleteMappingHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteMapping(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao excluir mapeamento de importação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "mapeamento de importação excluído"})
+}
+
+// Executa um mapeamento sobre o arquivo enviado como multipart (campos "mapping_id", "file" e
+// "dry_run"); na simulação nada é gravado, mas os erros por linha são devolvidos
+// @Security BearerAuth
+// @Accept multipart/form-data
+func CreateRunHandler(c *gin.Context) {
+	mappingID, err := strconv.Atoi(c.PostForm("mapping_id"))
+	if err != nil || mappingID <= 0 {
+		c.Error(errors.ToAPIError(errors.ErrInvalidRequest, "").WithFieldError("mapping_id", "informe o ID do mapeamento"))
+		return
+	}
+
+	dryRun := false
+	if value := c.PostForm("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			c.Error(errors.ToAPIError(errors.ErrInvalidRequest, "").WithFieldError("dry_run", "use true ou false"))
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(errors.InvalidRequest(err).WithFieldError("file", "campo obrigatório"))
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		c.Error(errors.NewAPIError(http.StatusRequestEntityTooLarge, "import_file_too_large", "arquivo excede o tamanho máximo permitido"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(errors.InvalidParam("erro ao abrir arquivo").WithDetails(err.Error()))
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.Error(errors.InvalidParam("erro ao ler arquivo").WithDetails(err.Error()))
+		return
+	}
+
+	run, err := service.Execute(c.Request.Context(), mappingID, fileHeader.Filename, content, dryRun, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao executar importação")
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"run": run})
+}
+
+// Lista as execuções de importação mais recentes
+// @Security BearerAuth
+// @Param mapping_id query int false "somente as execuções do mapeamento"
+// @Param limit query int false "quantidade (padrão 20, máx. 100)"
+func ListRunsHandler(c *gin.Context) {
+	mappingID, ok := parsePositiveQuery(c, "mapping_id")
+	if !ok {
+		return
+	}
+	limit, ok := parsePositiveQuery(c, "limit")
+	if !ok {
+		return
+	}
+
+	runs, err := service.ListRuns(c.Request.Context(), mappingID, limit)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar execuções de importação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// Retorna uma execução de importação com os erros por linha
+// @Security BearerAuth
+func GetRunHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	run, err := service.GetRun(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar execução de importação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+// parsePositiveQuery lê um parâmetro de consulta inteiro e positivo; ausente retorna zero
+func parsePositiveQuery(c *gin.Context, name string) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		c.Error(errors.InvalidParam(name + " deve ser um número inteiro positivo"))
+		return 0, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"time"
+
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+)
+
+// Modelos do ERP que podem receber importações
+const (
+	TargetContacts    = "contacts"
+	TargetProducts    = "products"
+	TargetSalesOrders = "sales_orders"
+)
+
+// Formatos de arquivo aceitos
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Tipos dos campos de destino; o valor lido do arquivo é validado e normalizado pelo tipo
+const (
+	FieldText    = "text"
+	FieldNumber  = "number"
+	FieldInteger = "integer"
+	FieldDate    = "date"
+)
+
+// Transformações aplicadas ao valor lido, na ordem informada
+const (
+	TransformUpper        = "upper"
+	TransformLower        = "lower"
+	TransformDigits       = "digits"        // mantém apenas os dígitos (CPF, CNPJ, CEP)
+	TransformDecimalComma = "decimal_comma" // número no padrão brasileiro: 1.234,56
+)
+
+// Transforms lista as transformações aceitas nos mapeamentos
+var Transforms = []string{TransformUpper, TransformLower, TransformDigits, TransformDecimalComma}
+
+// Situação de uma execução
+const (
+	RunStatusSuccess = "success" // todas as linhas importadas (ou válidas, na simulação)
+	RunStatusPartial = "partial" // parte das linhas foi rejeitada
+	RunStatusFailed  = "failed"  // nenhuma linha importada
+)
+
+// TargetField é um campo do modelo de destino que pode ser preenchido pelo mapeamento
+type TargetField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+}
+
+// Targets descreve os campos de cada modelo de destino. Nos pedidos de venda, cada registro é
+// uma linha do pedido; as linhas com o mesmo order_ref formam um único pedido.
+var Targets = map[string][]TargetField{
+	TargetContacts: {
+		{Name: "name", Type: FieldText, Required: true},
+		{Name: "document", Type: FieldText, Required: true},
+		{Name: "person_type", Type: FieldText},
+		{Name: "type", Type: FieldText},
+		{Name: "company_name", Type: FieldText},
+		{Name: "trade_name", Type: FieldText},
+		{Name: "secondary_doc", Type: FieldText},
+		{Name: "email", Type: FieldText},
+		{Name: "phone", Type: FieldText},
+		{Name: "zip_code", Type: FieldText},
+		{Name: "street", Type: FieldText},
+		{Name: "number", Type: FieldText},
+		{Name: "complement", Type: FieldText},
+		{Name: "neighborhood", Type: FieldText},
+		{Name: "city", Type: FieldText},
+		{Name: "state", Type: FieldText},
+	},
+	TargetProducts: {
+		{Name: "name", Type: FieldText, Required: true},
+		{Name: "sku", Type: FieldText, Required: true},
+		{Name: "price", Type: FieldNumber, Required: true},
+		{Name: "detailed_name", Type: FieldText},
+		{Name: "description", Type: FieldText},
+		{Name: "status", Type: FieldText},
+		{Name: "barcode", Type: FieldText},
+		{Name: "external_id", Type: FieldText},
+		{Name: "coin", Type: FieldText},
+		{Name: "sales_price", Type: FieldNumber},
+		{Name: "cost_price", Type: FieldNumber},
+		{Name: "stock", Type: FieldInteger},
+		{Name: "ncm", Type: FieldText},
+		{Name: "product_group", Type: FieldText},
+		{Name: "product_category", Type: FieldText},
+		{Name: "manufacturer", Type: FieldText},
+	},
+	TargetSalesOrders: {
+		{Name: "order_ref", Type: FieldText, Required: true},
+		{Name: "contact_document", Type: FieldText, Required: true},
+		{Name: "product_sku", Type: FieldText, Required: true},
+		{Name: "quantity", Type: FieldInteger, Required: true},
+		{Name: "unit_price", Type: FieldNumber, Required: true},
+		{Name: "discount", Type: FieldNumber},
+		{Name: "tax", Type: FieldNumber},
+		{Name: "expected_date", Type: FieldDate},
+		{Name: "payment_terms", Type: FieldText},
+		{Name: "shipping_address", Type: FieldText},
+		{Name: "notes", Type: FieldText},
+	},
+}
+
+// FieldMapping preenche um campo de destino com a coluna (CSV) ou o caminho com pontos (JSON)
+// do arquivo; Default é usado quando a origem está vazia ou não foi informada
+type FieldMapping struct {
+	Target     string   `json:"target"`
+	Source     string   `json:"source,omitempty"`
+	Default    string   `json:"default,omitempty"`
+	Transforms []string `json:"transforms,omitempty"`
+	// Layout Go das datas (ex.: 02/01/2006); vazio aceita 2006-01-02 e 02/01/2006
+	DateLayout string `json:"date_layout,omitempty"`
+}
+
+// MappingConfig é a configuração gravada com o mapeamento
+type MappingConfig struct {
+	// Separador do CSV; vazio detecta "," ou ";" pelo cabeçalho
+	Delimiter string `json:"delimiter,omitempty"`
+	// Caminho, com pontos, da lista de registros no JSON; vazio espera uma lista na raiz
+	RecordsPath string `json:"records_path,omitempty"`
+	// Lista dentro de cada registro JSON que é expandida em um registro por elemento (ex.: os
+	// itens de um pedido); os campos do elemento ficam sob o mesmo prefixo
+	ExpandPath string         `json:"expand_path,omitempty"`
+	Fields     []FieldMapping `json:"fields"`
+}
+
+// Mapping é um mapeamento de importação cadastrado por um administrador
+type Mapping struct {
+	ID          int           `json:"id" gorm:"primaryKey"`
+	CompanyID   int           `json:"company_id" gorm:"<-:create"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Target      string        `json:"target"`
+	Format      string        `json:"format"`
+	Config      MappingConfig `json:"config" gorm:"serializer:json"`
+	CreatedBy   string        `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Mapping) TableName() string {
+	return "etl_mappings"
+}
+
+// MappingInput são os dados de cadastro de um mapeamento
+type MappingInput struct {
+	Name        string        `json:"name" binding:"required,max=100"`
+	Description string        `json:"description"`
+	Target      string        `json:"target" binding:"required"`
+	Format      string        `json:"format" binding:"required"`
+	Config      MappingConfig `json:"config"`
+}
+
+// RowError é o motivo da rejeição de uma linha do arquivo
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Run é uma execução de um mapeamento sobre um arquivo
+type Run struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	CompanyID  int        `json:"company_id" gorm:"<-:create"`
+	MappingID  int        `json:"mapping_id"`
+	FileName   string     `json:"file_name"`
+	DryRun     bool       `json:"dry_run"`
+	Status     string     `json:"status"`
+	TotalRows  int        `json:"total_rows"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	Errors     []RowError `json:"errors" gorm:"serializer:json"`
+	CreatedBy  string     `json:"created_by"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+}
+
+func (Run) TableName() string {
+	return "etl_runs"
+}
+
+// Record é um registro do arquivo já mapeado para os campos de destino e normalizado
+type Record struct {
+	Row    int
+	Values map[string]string
+}
+
+// Entry é um registro pronto para gravação, com a linha de origem no arquivo
+type Entry[T any] struct {
+	Row  int
+	Item T
+}
+
+// Upsert é um registro que atualiza o cadastro com a mesma chave (documento do contato, SKU do
+// produto) ou cria um novo; na atualização, só as colunas mapeadas são gravadas
+type Upsert[T any] struct {
+	Item    T
+	Columns []string
+}
+
+// OrderImport é um pedido de venda montado a partir das linhas com o mesmo order_ref; o cliente
+// e os produtos são localizados pelo documento e pelos SKUs na gravação
+type OrderImport struct {
+	Ref             string
+	ContactDocument string
+	SKUs            []string // SKU de cada item, na ordem de Order.Items
+	Order           *sales.SalesOrder
+}
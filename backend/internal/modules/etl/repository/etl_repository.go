@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
+	"ERP-ONSMART/backend/internal/modules/etl/models"
+	products "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EtlRepository define as operações dos mapeamentos de importação e a gravação dos registros
+// importados em contatos, produtos e pedidos de venda
+type EtlRepository interface {
+	ListMappings(ctx context.Context) ([]models.Mapping, error)
+	GetMapping(ctx context.Context, id int) (*models.Mapping, error)
+	MappingNameExists(ctx context.Context, name string, exceptID int) (bool, error)
+	CreateMapping(ctx context.Context, mapping *models.Mapping) error
+	UpdateMapping(ctx context.Context, mapping *models.Mapping) error
+	DeleteMapping(ctx context.Context, id int) error
+
+	ImportContacts(ctx context.Context, entries []models.Entry[models.Upsert[*contact.Contact]], dryRun bool) ([]models.RowError, error)
+	ImportProducts(ctx context.Context, entries []models.Entry[models.Upsert[*products.Product]], dryRun bool) ([]models.RowError, error)
+	ImportSalesOrders(ctx context.Context, entries []models.Entry[*models.OrderImport], dryRun bool) ([]models.RowError, error)
+
+	SaveRun(ctx context.Context, run *models.Run) error
+	ListRuns(ctx context.Context, mappingID, limit int) ([]models.Run, error)
+	GetRun(ctx context.Context, id int) (*models.Run, error)
+}
+
+type etlRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewEtlRepository cria uma nova instância do repositório
+func NewEtlRepository() (EtlRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &etlRepository{
+		db:     db,
+		logger: logger.WithModule("etl_repository"),
+	}, nil
+}
+
+// ListMappings retorna os mapeamentos da empresa
+func (r *etlRepository) ListMappings(ctx context.Context) ([]models.Mapping, error) {
+	var mappings []models.Mapping
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&mappings).Error; err != nil {
+		r.logger.Error("erro ao listar mapeamentos de importação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar mapeamentos de importação")
+	}
+	return mappings, nil
+}
+
+// GetMapping busca um mapeamento pelo ID
+func (r *etlRepository) GetMapping(ctx context.Context, id int) (*models.Mapping, error) {
+	var mapping models.Mapping
+	if err := r.db.WithContext(ctx).First(&mapping, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrMappingNotFound
+		}
+		r.logger.Error("erro ao buscar mapeamento de importação", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar mapeamento de importação")
+	}
+	return &mapping, nil
+}
+
+// MappingNameExists indica se outro mapeamento da empresa já usa o nome
+func (r *etlRepository) MappingNameExists(ctx context.Context, name string, exceptID int) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Mapping{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).
+		Count(&count).Error; err != nil {
+		return false, errors.WrapError(err, "falha ao verificar nome do mapeamento")
+	}
+	return count > 0, nil
+}
+
+// CreateMapping grava um novo mapeamento
+func (r *etlRepository) CreateMapping(ctx context.Context, mapping *models.Mapping) error {
+	if err := r.db.WithContext(ctx).Create(mapping).Error; err != nil {
+		r.logger.Error("erro ao criar mapeamento de importação", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar mapeamento de importação")
+	}
+	return nil
+}
+
+// UpdateMapping grava o nome, a descrição, o destino, o formato e a configuração do mapeamento
+func (r *etlRepository) UpdateMapping(ctx context.Context, mapping *models.Mapping) error {
+	if err := r.db.WithContext(ctx).Model(mapping).
+		Select("name", "description", "target", "format", "config").
+		Updates(mapping).Error; err != nil {
+		r.logger.Error("erro ao atualizar mapeamento de importação", zap.Error(err), zap.Int("id", mapping.ID))
+		return errors.WrapError(err, "falha ao atualizar mapeamento de importação")
+	}
+	return nil
+}
+
+// DeleteMapping exclui o mapeamento e o histórico das suas execuções
+func (r *etlRepository) DeleteMapping(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.Mapping{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao excluir mapeamento de importação", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao excluir mapeamento de importação")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrMappingNotFound
+	}
+	return nil
+}
+
+// loadOperation grava um registro dentro da transação
+type loadOperation func(tx *gorm.DB, index int) error
+
+// runLoad grava os registros em uma única transação, cada um em um savepoint: a falha desfaz
+// apenas o registro e a carga continua. Na simulação, tudo é gravado e desfeito no final, para
+// que as restrições do banco e as buscas por documento e SKU também sejam validadas.
+func (r *etlRepository) runLoad(ctx context.Context, rows []int, dryRun bool, op loadOperation) ([]models.RowError, error) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, errors.WrapError(tx.Error, "falha ao iniciar transação")
+	}
+
+	rowErrors := make([]models.RowError, 0)
+	for index, row := range rows {
+		savepoint := fmt.Sprintf("etl_row_%d", index)
+		if err := tx.SavePoint(savepoint).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao criar savepoint da importação")
+		}
+
+		if err := op(tx, index); err != nil {
+			rowErrors = append(rowErrors, models.RowError{Row: row, Message: err.Error()})
+			if err := tx.RollbackTo(savepoint).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.WrapError(err, "falha ao desfazer registro da importação")
+			}
+		}
+	}
+
+	if dryRun {
+		tx.Rollback()
+		return rowErrors, nil
+	}
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da importação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+	return rowErrors, nil
+}
+
+func entryRows[T any](entries []models.Entry[T]) []int {
+	rows := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, entry.Row)
+	}
+	return rows
+}
+
+// ImportContacts atualiza os contatos com o mesmo documento (desconsiderando a pontuação) ou
+// cria novos
+func (r *etlRepository) ImportContacts(ctx context.Context, entries []models.Entry[models.Upsert[*contact.Contact]], dryRun bool) ([]models.RowError, error) {
+	rowErrors, err := r.runLoad(ctx, entryRows(entries), dryRun, func(tx *gorm.DB, index int) error {
+		upsert := entries[index].Item
+
+		var existing contact.Contact
+		err := tx.Where("deleted_at IS NULL AND regexp_replace(document, '\\D', '', 'g') = ?", onlyDigits(upsert.Item.Document)).
+			Order("id ASC").First(&existing).Error
+		switch {
+		case err == nil:
+			return tx.Model(&existing).Select(upsert.Columns).Updates(upsert.Item).Error
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(upsert.Item).Error
+		default:
+			return errors.WrapError(err, "falha ao buscar contato")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("importação de contatos processada", zap.Int("rows", len(entries)), zap.Bool("dry_run", dryRun))
+	return rowErrors, nil
+}
+
+// ImportProducts atualiza os produtos com o mesmo SKU ou cria novos
+func (r *etlRepository) ImportProducts(ctx context.Context, entries []models.Entry[models.Upsert[*products.Product]], dryRun bool) ([]models.RowError, error) {
+	rowErrors, err := r.runLoad(ctx, entryRows(entries), dryRun, func(tx *gorm.DB, index int) error {
+		upsert := entries[index].Item
+
+		var existing products.Product
+		err := tx.Where("sku = ?", upsert.Item.SKU).Order("id ASC").First(&existing).Error
+		switch {
+		case err == nil:
+			return tx.Model(&existing).Select(upsert.Columns).Updates(upsert.Item).Error
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(upsert.Item).Error
+		default:
+			return errors.WrapError(err, "falha ao buscar produto")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("importação de produtos processada", zap.Int("rows", len(entries)), zap.Bool("dry_run", dryRun))
+	return rowErrors, nil
+}
+
+// ImportSalesOrders cria os pedidos de venda, localizando o cliente pelo documento e os
+// produtos pelo SKU
+func (r *etlRepository) ImportSalesOrders(ctx context.Context, entries []models.Entry[*models.OrderImport], dryRun bool) ([]models.RowError, error) {
+	rowErrors, err := r.runLoad(ctx, entryRows(entries), dryRun, func(tx *gorm.DB, index int) error {
+		imported := entries[index].Item
+		order := imported.Order
+
+		var customer contact.Contact
+		err := tx.Select("id").
+			Where("deleted_at IS NULL AND regexp_replace(document, '\\D', '', 'g') = ?", onlyDigits(imported.ContactDocument)).
+			Order("id ASC").First(&customer).Error
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("pedido %s: nenhum contato com o documento %s", imported.Ref, imported.ContactDocument)
+		}
+		if err != nil {
+			return errors.WrapError(err, "falha ao buscar contato")
+		}
+		order.ContactID = customer.ID
+		if err := contactRepository.EnsureNotBlocked(tx, customer.ID); err != nil {
+			return fmt.Errorf("pedido %s: %w", imported.Ref, err)
+		}
+
+		for i, sku := range imported.SKUs {
+			if order.Items[i].Quantity <= 0 {
+				return fmt.Errorf("pedido %s: a quantidade do SKU %s deve ser maior que zero", imported.Ref, sku)
+			}
+
+			var product products.Product
+			err := tx.Select("id", "name").Where("sku = ?", sku).Order("id ASC").First(&product).Error
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("pedido %s: nenhum produto com o SKU %s", imported.Ref, sku)
+			}
+			if err != nil {
+				return errors.WrapError(err, "falha ao buscar produto")
+			}
+			order.Items[i].ProductID = product.ID
+			order.Items[i].ProductName = product.Name
+		}
+
+		items, err := salesRepository.PrepareSalesOrderItems(tx, order.Items)
+		if err != nil {
+			return err
+		}
+		order.Items = items
+
+		order.SONo = salesRepository.NextDocumentNumber(tx, &sales.SalesOrder{}, "SO")
+		if err := tx.Omit(clause.Associations).Create(order).Error; err != nil {
+			return errors.WrapError(err, "falha ao criar sales order")
+		}
+		for i := range order.Items {
+			order.Items[i].SalesOrderID = order.ID
+		}
+		if err := tx.Omit(clause.Associations).Create(&order.Items).Error; err != nil {
+			return errors.WrapError(err, "falha ao criar itens do sales order")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("importação de pedidos de venda processada", zap.Int("orders", len(entries)), zap.Bool("dry_run", dryRun))
+	return rowErrors, nil
+}
+
+var nonDigits = regexp.MustCompile(`\D`)
+
+func onlyDigits(value string) string {
+	return nonDigits.ReplaceAllString(value, "")
+}
+
+// SaveRun grava o resultado de uma execução
+func (r *etlRepository) SaveRun(ctx context.Context, run *models.Run) error {
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		r.logger.Error("erro ao gravar execução de importação", zap.Error(err), zap.Int("mapping_id", run.MappingID))
+		return errors.WrapError(err, "falha ao gravar execução de importação")
+	}
+	return nil
+}
+
+// ListRuns retorna as execuções mais recentes da empresa, opcionalmente de um mapeamento
+func (r *etlRepository) ListRuns(ctx context.Context, mappingID, limit int) ([]models.Run, error) {
+	query := r.db.WithContext(ctx).Omit("errors").Order("started_at DESC, id DESC").Limit(limit)
+	if mappingID > 0 {
+		query = query.Where("mapping_id = ?", mappingID)
+	}
+
+	var runs []models.Run
+	if err := query.Find(&runs).Error; err != nil {
+		r.logger.Error("erro ao listar execuções de importação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar execuções de importação")
+	}
+	return runs, nil
+}
+
+// GetRun busca uma execução, com os erros por linha
+func (r *etlRepository) GetRun(ctx context.Context, id int) (*models.Run, error) {
+	var run models.Run
+	if err := r.db.WithContext(ctx).First(&run, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrEtlRunNotFound
+		}
+		r.logger.Error("erro ao buscar execução de importação", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar execução de importação")
+	}
+	return &run, nil
+}
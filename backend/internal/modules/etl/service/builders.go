@@ -0,0 +1,188 @@
+package service
+
+import (
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/etl/models"
+	products "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// buildContacts monta os contatos; na inclusão, o tipo de pessoa segue o tamanho do documento
+// (CPF ou CNPJ) e o tipo padrão é cliente
+func buildContacts(records []models.Record) []models.Entry[models.Upsert[*contact.Contact]] {
+	entries := make([]models.Entry[models.Upsert[*contact.Contact]], 0, len(records))
+	for _, record := range records {
+		v := record.Values
+		c := &contact.Contact{
+			PersonType:   v["person_type"],
+			Type:         v["type"],
+			Name:         v["name"],
+			CompanyName:  v["company_name"],
+			TradeName:    v["trade_name"],
+			Document:     v["document"],
+			SecondaryDoc: v["secondary_doc"],
+			Email:        v["email"],
+			Phone:        v["phone"],
+			ZipCode:      v["zip_code"],
+			Street:       v["street"],
+			Number:       v["number"],
+			Complement:   v["complement"],
+			Neighborhood: v["neighborhood"],
+			City:         v["city"],
+			State:        v["state"],
+		}
+		if c.PersonType == "" {
+			c.PersonType = "pf"
+			if len(digits(c.Document)) > 11 {
+				c.PersonType = "pj"
+			}
+		}
+		if c.Type == "" {
+			c.Type = "cliente"
+		}
+
+		entries = append(entries, models.Entry[models.Upsert[*contact.Contact]]{
+			Row:  record.Row,
+			Item: models.Upsert[*contact.Contact]{Item: c, Columns: columns(v)},
+		})
+	}
+	return entries
+}
+
+// buildProducts monta os produtos; na inclusão, o produto fica ativo, em reais, e o nome
+// detalhado repete o nome quando não mapeado
+func buildProducts(records []models.Record) []models.Entry[models.Upsert[*products.Product]] {
+	entries := make([]models.Entry[models.Upsert[*products.Product]], 0, len(records))
+	for _, record := range records {
+		v := record.Values
+		p := &products.Product{
+			Name:            v["name"],
+			DetailedName:    v["detailed_name"],
+			Description:     v["description"],
+			Status:          v["status"],
+			SKU:             v["sku"],
+			Barcode:         v["barcode"],
+			ExternalID:      v["external_id"],
+			Coin:            v["coin"],
+			Price:           amount(v["price"]),
+			SalesPrice:      amount(v["sales_price"]),
+			CostPrice:       amount(v["cost_price"]),
+			Stock:           integer(v["stock"]),
+			NCM:             v["ncm"],
+			ProductGroup:    v["product_group"],
+			ProductCategory: v["product_category"],
+			Manufacturer:    v["manufacturer"],
+		}
+		if p.DetailedName == "" {
+			p.DetailedName = p.Name
+		}
+		if p.Status == "" {
+			p.Status = "ativo"
+		}
+		if p.Coin == "" {
+			p.Coin = "BRL"
+		}
+
+		entries = append(entries, models.Entry[models.Upsert[*products.Product]]{
+			Row:  record.Row,
+			Item: models.Upsert[*products.Product]{Item: p, Columns: columns(v)},
+		})
+	}
+	return entries
+}
+
+// buildSalesOrders agrupa as linhas pelo order_ref, na ordem em que cada pedido aparece no
+// arquivo. Os dados do pedido (cliente, data, condições) vêm da primeira linha; os pedidos
+// são gravados como rascunho e sem data prevista assumem a data da importação.
+func buildSalesOrders(records []models.Record, now time.Time) []models.Entry[*models.OrderImport] {
+	entries := make([]models.Entry[*models.OrderImport], 0)
+	byRef := make(map[string]int)
+	totals := make(map[string]*sales.DocumentTotals)
+
+	for _, record := range records {
+		v := record.Values
+		ref := v["order_ref"]
+
+		index, ok := byRef[ref]
+		if !ok {
+			expected := now
+			if date, err := time.Parse("2006-01-02", v["expected_date"]); err == nil {
+				expected = date
+			}
+			byRef[ref] = len(entries)
+			index = len(entries)
+			totals[ref] = &sales.DocumentTotals{}
+			entries = append(entries, models.Entry[*models.OrderImport]{
+				Row: record.Row,
+				Item: &models.OrderImport{
+					Ref:             ref,
+					ContactDocument: v["contact_document"],
+					Order: &sales.SalesOrder{
+						Status:          sales.SOStatusDraft,
+						ExpectedDate:    expected,
+						PaymentTerms:    v["payment_terms"],
+						ShippingAddress: v["shipping_address"],
+						Notes:           v["notes"],
+					},
+				},
+			})
+		}
+
+		order := entries[index].Item
+		quantity := integer(v["quantity"])
+		unitPrice, discount, tax := amount(v["unit_price"]), amount(v["discount"]), amount(v["tax"])
+		order.SKUs = append(order.SKUs, v["product_sku"])
+		order.Order.Items = append(order.Order.Items, sales.SOItem{
+			ProductCode: v["product_sku"],
+			Quantity:    quantity,
+			UnitPrice:   unitPrice,
+			Discount:    discount,
+			Tax:         tax,
+			Total:       sales.LineTotal(quantity, unitPrice, discount, tax),
+		})
+
+		total := totals[ref]
+		total.Add(quantity, unitPrice, discount, tax)
+		order.Order.SubTotal = total.SubTotal
+		order.Order.TaxTotal = total.TaxTotal
+		order.Order.DiscountTotal = total.DiscountTotal
+		order.Order.GrandTotal = total.GrandTotal
+	}
+	return entries
+}
+
+// columns retorna os campos mapeados, que são as colunas gravadas na atualização
+func columns(values map[string]string) []string {
+	result := make([]string, 0, len(values))
+	for name := range values {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// amount lê um valor monetário; vazio ou inválido é zero
+func amount(value string) money.Decimal {
+	parsed, _ := money.Parse(value)
+	return parsed
+}
+
+func integer(value string) int {
+	parsed, _ := strconv.Atoi(value)
+	return parsed
+}
+
+func digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, value)
+}
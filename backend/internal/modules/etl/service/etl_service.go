@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/etl/models"
+	"ERP-ONSMART/backend/internal/modules/etl/repository"
+
+	"go.uber.org/zap"
+)
+
+// maxRunsLimit limita a quantidade de execuções listadas
+const maxRunsLimit = 100
+
+// maxReportedErrors limita os erros por linha gravados e devolvidos em uma execução
+const maxReportedErrors = 500
+
+// Service mantém os mapeamentos de importação e executa as importações
+type Service struct {
+	newRepo func() (repository.EtlRepository, error)
+	now     func() time.Time
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.EtlRepository
+}
+
+// NewService cria o serviço sobre o repositório informado
+func NewService(newRepo func() (repository.EtlRepository, error)) *Service {
+	return &Service{
+		newRepo: newRepo,
+		now:     time.Now,
+		logger:  logger.WithModule("etl_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewEtlRepository)
+
+// ListMappings lista os mapeamentos da empresa da requisição
+func ListMappings(ctx context.Context) ([]models.Mapping, error) {
+	return defaultService.ListMappings(ctx)
+}
+
+// GetMapping busca um mapeamento da empresa da requisição
+func GetMapping(ctx context.Context, id int) (*models.Mapping, error) {
+	return defaultService.GetMapping(ctx, id)
+}
+
+// CreateMapping cadastra um mapeamento na empresa da requisição
+func CreateMapping(ctx context.Context, input models.MappingInput, createdBy string) (*models.Mapping, error) {
+	return defaultService.CreateMapping(ctx, input, createdBy)
+}
+
+// UpdateMapping altera um mapeamento
+func UpdateMapping(ctx context.Context, id int, input models.MappingInput) (*models.Mapping, error) {
+	return defaultService.UpdateMapping(ctx, id, input)
+}
+
+// DeleteMapping exclui um mapeamento e o histórico das suas execuções
+func DeleteMapping(ctx context.Context, id int) error {
+	return defaultService.DeleteMapping(ctx, id)
+}
+
+// Execute aplica o mapeamento ao arquivo enviado
+func Execute(ctx context.Context, mappingID int, fileName string, content []byte, dryRun bool, createdBy string) (*models.Run, error) {
+	return defaultService.Execute(ctx, mappingID, fileName, content, dryRun, createdBy)
+}
+
+// ListRuns retorna as execuções mais recentes, opcionalmente de um mapeamento
+func ListRuns(ctx context.Context, mappingID, limit int) ([]models.Run, error) {
+	return defaultService.ListRuns(ctx, mappingID, limit)
+}
+
+// GetRun busca uma execução com os erros por linha
+func GetRun(ctx context.Context, id int) (*models.Run, error) {
+	return defaultService.GetRun(ctx, id)
+}
+
+func (s *Service) repository() (repository.EtlRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListMappings lista os mapeamentos da empresa
+func (s *Service) ListMappings(ctx context.Context) ([]models.Mapping, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListMappings(ctx)
+}
+
+// GetMapping busca um mapeamento da empresa
+func (s *Service) GetMapping(ctx context.Context, id int) (*models.Mapping, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetMapping(ctx, id)
+}
+
+// CreateMapping valida e grava um novo mapeamento
+func (s *Service) CreateMapping(ctx context.Context, input models.MappingInput, createdBy string) (*models.Mapping, error) {
+	input = normalizeInput(input)
+	if err := ValidateMapping(input); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkName(ctx, repo, input.Name, 0); err != nil {
+		return nil, err
+	}
+
+	mapping := &models.Mapping{
+		Name:        input.Name,
+		Description: input.Description,
+		Target:      input.Target,
+		Format:      input.Format,
+		Config:      input.Config,
+		CreatedBy:   createdBy,
+	}
+	if err := repo.CreateMapping(ctx, mapping); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("mapeamento de importação criado", zap.Int("id", mapping.ID),
+		zap.String("target", mapping.Target), zap.String("created_by", createdBy))
+	return mapping, nil
+}
+
+// UpdateMapping valida e grava as alterações do mapeamento
+func (s *Service) UpdateMapping(ctx context.Context, id int, input models.MappingInput) (*models.Mapping, error) {
+	input = normalizeInput(input)
+	if err := ValidateMapping(input); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := repo.GetMapping(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkName(ctx, repo, input.Name, id); err != nil {
+		return nil, err
+	}
+
+	mapping.Name = input.Name
+	mapping.Description = input.Description
+	mapping.Target = input.Target
+	mapping.Format = input.Format
+	mapping.Config = input.Config
+	if err := repo.UpdateMapping(ctx, mapping); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("mapeamento de importação atualizado", zap.Int("id", id))
+	return mapping, nil
+}
+
+// DeleteMapping exclui o mapeamento da empresa
+func (s *Service) DeleteMapping(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	if err := repo.DeleteMapping(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("mapeamento de importação excluído", zap.Int("id", id))
+	return nil
+}
+
+func normalizeInput(input models.MappingInput) models.MappingInput {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Target = strings.ToLower(strings.TrimSpace(input.Target))
+	input.Format = strings.ToLower(strings.TrimSpace(input.Format))
+	for i := range input.Config.Fields {
+		input.Config.Fields[i].Target = strings.TrimSpace(input.Config.Fields[i].Target)
+	}
+	return input
+}
+
+func checkName(ctx context.Context, repo repository.EtlRepository, name string, exceptID int) error {
+	exists, err := repo.MappingNameExists(ctx, name, exceptID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.ErrMappingNameTaken
+	}
+	return nil
+}
+
+// Execute lê o arquivo, aplica o mapeamento e grava os registros válidos no modelo de destino.
+// Com dryRun, nada é gravado, mas a execução é registrada com os erros que a importação teria.
+// Arquivos ilegíveis são recusados sem registrar execução.
+func (s *Service) Execute(ctx context.Context, mappingID int, fileName string, content []byte, dryRun bool, createdBy string) (*models.Run, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := repo.GetMapping(ctx, mappingID)
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := s.now()
+	records, err := parseFile(mapping.Format, mapping.Config, content)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped, rowErrors := applyMapping(mapping.Target, mapping.Config, records)
+	succeeded, loadErrors, err := s.load(ctx, repo, mapping.Target, mapped, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	rowErrors = append(rowErrors, loadErrors...)
+
+	run := &models.Run{
+		MappingID:  mapping.ID,
+		FileName:   fileName,
+		DryRun:     dryRun,
+		TotalRows:  len(records),
+		Succeeded:  succeeded,
+		Failed:     len(records) - succeeded,
+		Errors:     reportedErrors(rowErrors),
+		CreatedBy:  createdBy,
+		StartedAt:  startedAt,
+		FinishedAt: s.now(),
+	}
+	switch {
+	case run.Failed == 0:
+		run.Status = models.RunStatusSuccess
+	case run.Succeeded > 0:
+		run.Status = models.RunStatusPartial
+	default:
+		run.Status = models.RunStatusFailed
+	}
+
+	if err := repo.SaveRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("importação executada", zap.Int("run_id", run.ID), zap.Int("mapping_id", mapping.ID),
+		zap.Bool("dry_run", dryRun), zap.Int("succeeded", run.Succeeded), zap.Int("failed", run.Failed))
+	return run, nil
+}
+
+// load grava os registros mapeados no destino e retorna quantos registros do arquivo foram
+// gravados (nos pedidos, cada linha gravada do pedido conta)
+func (s *Service) load(ctx context.Context, repo repository.EtlRepository, target string, records []models.Record, dryRun bool) (int, []models.RowError, error) {
+	if len(records) == 0 {
+		return 0, nil, nil
+	}
+
+	switch target {
+	case models.TargetContacts:
+		entries := buildContacts(records)
+		rowErrors, err := repo.ImportContacts(ctx, entries, dryRun)
+		return len(entries) - len(rowErrors), rowErrors, err
+	case models.TargetProducts:
+		entries := buildProducts(records)
+		rowErrors, err := repo.ImportProducts(ctx, entries, dryRun)
+		return len(entries) - len(rowErrors), rowErrors, err
+	case models.TargetSalesOrders:
+		entries := buildSalesOrders(records, s.now())
+		rowErrors, err := repo.ImportSalesOrders(ctx, entries, dryRun)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		failed := make(map[int]bool, len(rowErrors))
+		for _, rowErr := range rowErrors {
+			failed[rowErr.Row] = true
+		}
+		succeeded := 0
+		for _, entry := range entries {
+			if !failed[entry.Row] {
+				succeeded += len(entry.Item.SKUs)
+			}
+		}
+		return succeeded, rowErrors, nil
+	}
+	return 0, nil, errors.ErrInvalidMapping
+}
+
+// reportedErrors ordena os erros pela linha e limita a quantidade gravada
+func reportedErrors(rowErrors []models.RowError) []models.RowError {
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+	if len(rowErrors) > maxReportedErrors {
+		return rowErrors[:maxReportedErrors]
+	}
+	return rowErrors
+}
+
+// ListRuns retorna as execuções mais recentes da empresa (padrão 20, máximo 100)
+func (s *Service) ListRuns(ctx context.Context, mappingID, limit int) ([]models.Run, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxRunsLimit {
+		limit = maxRunsLimit
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListRuns(ctx, mappingID, limit)
+}
+
+// GetRun busca uma execução da empresa
+func (s *Service) GetRun(ctx context.Context, id int) (*models.Run, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRun(ctx, id)
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/etl/models"
+	"ERP-ONSMART/backend/internal/modules/etl/repository"
+	products "ERP-ONSMART/backend/internal/modules/products/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	mappings  map[int]*models.Mapping
+	contacts  []models.Entry[models.Upsert[*contact.Contact]]
+	products  []models.Entry[models.Upsert[*products.Product]]
+	orders    []models.Entry[*models.OrderImport]
+	loadErrs  []models.RowError
+	dryRun    bool
+	savedRuns []*models.Run
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{mappings: map[int]*models.Mapping{}}
+}
+
+func (f *fakeRepo) ListMappings(context.Context) ([]models.Mapping, error) {
+	var mappings []models.Mapping
+	for _, mapping := range f.mappings {
+		mappings = append(mappings, *mapping)
+	}
+	return mappings, nil
+}
+
+func (f *fakeRepo) GetMapping(_ context.Context, id int) (*models.Mapping, error) {
+	mapping, ok := f.mappings[id]
+	if !ok {
+		return nil, errors.ErrMappingNotFound
+	}
+	return mapping, nil
+}
+
+func (f *fakeRepo) MappingNameExists(_ context.Context, name string, exceptID int) (bool, error) {
+	for _, mapping := range f.mappings {
+		if mapping.Name == name && mapping.ID != exceptID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRepo) CreateMapping(_ context.Context, mapping *models.Mapping) error {
+	mapping.ID = len(f.mappings) + 1
+	f.mappings[mapping.ID] = mapping
+	return nil
+}
+
+func (f *fakeRepo) UpdateMapping(_ context.Context, mapping *models.Mapping) error {
+	f.mappings[mapping.ID] = mapping
+	return nil
+}
+
+func (f *fakeRepo) DeleteMapping(_ context.Context, id int) error {
+	delete(f.mappings, id)
+	return nil
+}
+
+func (f *fakeRepo) ImportContacts(_ context.Context, entries []models.Entry[models.Upsert[*contact.Contact]], dryRun bool) ([]models.RowError, error) {
+	f.contacts, f.dryRun = entries, dryRun
+	return f.loadErrs, nil
+}
+
+func (f *fakeRepo) ImportProducts(_ context.Context, entries []models.Entry[models.Upsert[*products.Product]], dryRun bool) ([]models.RowError, error) {
+	f.products, f.dryRun = entries, dryRun
+	return f.loadErrs, nil
+}
+
+func (f *fakeRepo) ImportSalesOrders(_ context.Context, entries []models.Entry[*models.OrderImport], dryRun bool) ([]models.RowError, error) {
+	f.orders, f.dryRun = entries, dryRun
+	return f.loadErrs, nil
+}
+
+func (f *fakeRepo) SaveRun(_ context.Context, run *models.Run) error {
+	run.ID = len(f.savedRuns) + 1
+	f.savedRuns = append(f.savedRuns, run)
+	return nil
+}
+
+func (f *fakeRepo) ListRuns(context.Context, int, int) ([]models.Run, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) GetRun(context.Context, int) (*models.Run, error) {
+	return nil, errors.ErrEtlRunNotFound
+}
+
+func newTestService(repo *fakeRepo) *Service {
+	s := NewService(func() (repository.EtlRepository, error) { return repo, nil })
+	s.now = func() time.Time { return time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestValidateMappingReportsEachProblem(t *testing.T) {
+	err := ValidateMapping(models.MappingInput{
+		Name:   "Contatos do CRM",
+		Target: models.TargetContacts,
+		Format: "xml",
+		Config: models.MappingConfig{Fields: []models.FieldMapping{
+			{Target: "name", Source: "Nome"},
+			{Target: "name", Source: "Razão"},
+			{Target: "cpf", Source: "CPF", Transforms: []string{"reverse"}},
+		}},
+	})
+	require.Error(t, err)
+
+	apiErr := errors.ToAPIError(err, "")
+	assert.Equal(t, "invalid_etl_mapping", apiErr.Code)
+	fields := make([]string, 0)
+	for _, fieldErr := range apiErr.FieldErrors {
+		fields = append(fields, fieldErr.Field)
+	}
+	assert.Equal(t, []string{"format", "config.fields[1].target", "config.fields[2].target", "config.fields[2].transforms", "config.fields"}, fields)
+}
+
+func TestCreateMappingRejectsDuplicateName(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	input := models.MappingInput{
+		Name:   "Produtos do fornecedor",
+		Target: " Products ",
+		Format: "CSV",
+		Config: models.MappingConfig{Fields: []models.FieldMapping{
+			{Target: "name", Source: "Descrição"},
+			{Target: "sku", Source: "Código"},
+			{Target: "price", Source: "Preço", Transforms: []string{models.TransformDecimalComma}},
+		}},
+	}
+
+	mapping, err := s.CreateMapping(context.Background(), input, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, models.TargetProducts, mapping.Target)
+	assert.Equal(t, models.FormatCSV, mapping.Format)
+
+	_, err = s.CreateMapping(context.Background(), input, "admin")
+	assert.Equal(t, errors.ErrMappingNameTaken, err)
+}
+
+func TestExecuteCSVContactsReportsRowErrors(t *testing.T) {
+	repo := newFakeRepo()
+	repo.mappings[1] = &models.Mapping{ID: 1, Target: models.TargetContacts, Format: models.FormatCSV, Config: models.MappingConfig{
+		Fields: []models.FieldMapping{
+			{Target: "name", Source: "Nome"},
+			{Target: "document", Source: "CPF/CNPJ", Transforms: []string{models.TransformDigits}},
+			{Target: "email", Source: "E-mail", Transforms: []string{models.TransformLower}},
+			{Target: "type", Default: "fornecedor"},
+		},
+	}}
+	repo.loadErrs = []models.RowError{{Row: 4, Message: "violação de restrição"}}
+	s := newTestService(repo)
+
+	content := []byte("\xef\xbb\xbfNome;CPF/CNPJ;E-mail\n" +
+		"ACME Ltda;12.345.678/0001-90;COMPRAS@ACME.COM\n" +
+		";123.456.789-09;sem@nome.com\n" +
+		"Maria Silva;123.456.789-09;\n" +
+		"\n")
+
+	run, err := s.Execute(context.Background(), 1, "contatos.csv", content, true, "admin")
+	require.NoError(t, err)
+
+	assert.True(t, repo.dryRun)
+	require.Len(t, repo.contacts, 2)
+	acme := repo.contacts[0]
+	assert.Equal(t, 2, acme.Row)
+	assert.Equal(t, "12345678000190", acme.Item.Item.Document)
+	assert.Equal(t, "pj", acme.Item.Item.PersonType)
+	assert.Equal(t, "fornecedor", acme.Item.Item.Type)
+	assert.Equal(t, "compras@acme.com", acme.Item.Item.Email)
+	assert.Equal(t, []string{"document", "email", "name", "type"}, acme.Item.Columns)
+	assert.Equal(t, "pf", repo.contacts[1].Item.Item.PersonType)
+
+	assert.Equal(t, 3, run.TotalRows)
+	assert.Equal(t, 1, run.Succeeded)
+	assert.Equal(t, 2, run.Failed)
+	assert.Equal(t, models.RunStatusPartial, run.Status)
+	assert.Equal(t, []models.RowError{
+		{Row: 3, Field: "name", Message: "campo obrigatório vazio"},
+		{Row: 4, Message: "violação de restrição"},
+	}, run.Errors)
+	assert.Len(t, repo.savedRuns, 1)
+}
+
+func TestExecuteJSONSalesOrdersGroupsExpandedItems(t *testing.T) {
+	repo := newFakeRepo()
+	repo.mappings[1] = &models.Mapping{ID: 1, Target: models.TargetSalesOrders, Format: models.FormatJSON, Config: models.MappingConfig{
+		RecordsPath: "data.orders",
+		ExpandPath:  "items",
+		Fields: []models.FieldMapping{
+			{Target: "order_ref", Source: "id"},
+			{Target: "contact_document", Source: "customer.document"},
+			{Target: "product_sku", Source: "items.sku"},
+			{Target: "quantity", Source: "items.qty"},
+			{Target: "unit_price", Source: "items.price"},
+			{Target: "expected_date", Source: "delivery", DateLayout: "02/01/2006"},
+		},
+	}}
+	s := newTestService(repo)
+
+	content := []byte(`{"data": {"orders": [
+		{"id": "A-1", "customer": {"document": "123.456.789-09"}, "delivery": "20/05/2026",
+		 "items": [{"sku": "NB-01", "qty": 2, "price": 1500}, {"sku": "MS-02", "qty": 1, "price": 19.9}]},
+		{"id": "A-2", "customer": {"document": "123.456.789-09"},
+		 "items": [{"sku": "NB-01", "qty": 1, "price": 1500}, {"sku": "MS-02", "qty": "um", "price": 19.9}]}
+	]}}`)
+
+	run, err := s.Execute(context.Background(), 1, "pedidos.json", content, false, "admin")
+	require.NoError(t, err)
+
+	require.Len(t, repo.orders, 1, "o pedido com item inválido não é importado")
+	order := repo.orders[0].Item
+	assert.Equal(t, "A-1", order.Ref)
+	assert.Equal(t, []string{"NB-01", "MS-02"}, order.SKUs)
+	assert.Equal(t, time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC), order.Order.ExpectedDate)
+	assert.Equal(t, "3019.90", order.Order.GrandTotal.String())
+	assert.Equal(t, "3000.00", order.Order.Items[0].Total.String())
+
+	assert.Equal(t, 4, run.TotalRows)
+	assert.Equal(t, 2, run.Succeeded)
+	assert.Equal(t, 2, run.Failed)
+	require.Len(t, run.Errors, 2)
+	assert.Equal(t, "quantity", run.Errors[0].Field)
+	assert.Equal(t, "order_ref", run.Errors[1].Field)
+}
+
+func TestExecuteRejectsUnreadableFile(t *testing.T) {
+	repo := newFakeRepo()
+	repo.mappings[1] = &models.Mapping{ID: 1, Target: models.TargetProducts, Format: models.FormatJSON, Config: models.MappingConfig{RecordsPath: "items"}}
+	s := newTestService(repo)
+
+	_, err := s.Execute(context.Background(), 1, "produtos.json", []byte(`{"items": {}}`), false, "admin")
+	assert.ErrorIs(t, err, errors.ErrInvalidImportFile)
+
+	_, err = s.Execute(context.Background(), 1, "produtos.json", []byte(`{"items": []}`), false, "admin")
+	assert.Equal(t, errors.ErrImportFileEmpty, err)
+	assert.Empty(t, repo.savedRuns)
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/etl/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultDateLayouts são os formatos de data aceitos quando o campo não define date_layout
+var defaultDateLayouts = []string{"2006-01-02", "02/01/2006", time.RFC3339}
+
+// ValidateMapping confere o destino, o formato e os campos do mapeamento, apontando cada
+// problema em field_errors
+func ValidateMapping(input models.MappingInput) error {
+	apiErr := errors.ToAPIError(errors.ErrInvalidMapping, "")
+	invalid := false
+	fail := func(field, message string) {
+		apiErr = apiErr.WithFieldError(field, message)
+		invalid = true
+	}
+
+	fields, ok := models.Targets[input.Target]
+	if !ok {
+		fail("target", fmt.Sprintf("destino desconhecido %q", input.Target))
+	}
+	if input.Format != models.FormatCSV && input.Format != models.FormatJSON {
+		fail("format", fmt.Sprintf("formato desconhecido %q (aceitos: csv, json)", input.Format))
+	}
+	if len([]rune(input.Config.Delimiter)) > 1 {
+		fail("config.delimiter", "informe um único caractere")
+	}
+	if len(input.Config.Fields) == 0 {
+		fail("config.fields", "informe ao menos um campo")
+	}
+
+	types := make(map[string]string, len(fields))
+	for _, field := range fields {
+		types[field.Name] = field.Type
+	}
+
+	mapped := make(map[string]bool, len(input.Config.Fields))
+	for i, field := range input.Config.Fields {
+		path := fmt.Sprintf("config.fields[%d]", i)
+		if ok {
+			if _, known := types[field.Target]; !known {
+				fail(path+".target", fmt.Sprintf("campo de destino desconhecido %q", field.Target))
+			}
+		}
+		if mapped[field.Target] {
+			fail(path+".target", fmt.Sprintf("campo de destino %q mapeado mais de uma vez", field.Target))
+		}
+		mapped[field.Target] = true
+
+		if field.Source == "" && field.Default == "" {
+			fail(path+".source", "informe a origem ou um valor padrão")
+		}
+		for _, transform := range field.Transforms {
+			if !validTransform(transform) {
+				fail(path+".transforms", fmt.Sprintf("transformação desconhecida %q", transform))
+			}
+		}
+	}
+
+	for _, field := range fields {
+		if field.Required && !mapped[field.Name] {
+			fail("config.fields", fmt.Sprintf("campo obrigatório %q não mapeado", field.Name))
+		}
+	}
+
+	if invalid {
+		return apiErr
+	}
+	return nil
+}
+
+func validTransform(name string) bool {
+	for _, transform := range models.Transforms {
+		if name == transform {
+			return true
+		}
+	}
+	return false
+}
+
+// applyMapping converte os registros do arquivo nos campos de destino, aplicando valores
+// padrão e transformações e validando os tipos. Registros com erro não seguem para a gravação.
+func applyMapping(target string, config models.MappingConfig, records []sourceRecord) ([]models.Record, []models.RowError) {
+	types := make(map[string]models.TargetField)
+	for _, field := range models.Targets[target] {
+		types[field.Name] = field
+	}
+
+	mapped := make([]models.Record, 0, len(records))
+	rowErrors := make([]models.RowError, 0)
+	failedOrders := make(map[string]bool)
+	for _, record := range records {
+		values := make(map[string]string, len(config.Fields))
+		valid := true
+		for _, field := range config.Fields {
+			value, err := mapValue(field, types[field.Target], record.values)
+			if err != nil {
+				rowErrors = append(rowErrors, models.RowError{Row: record.row, Field: field.Target, Message: err.Error()})
+				valid = false
+				continue
+			}
+			values[field.Target] = value
+		}
+		if valid {
+			mapped = append(mapped, models.Record{Row: record.row, Values: values})
+		} else if target == models.TargetSalesOrders && values["order_ref"] != "" {
+			failedOrders[values["order_ref"]] = true
+		}
+	}
+
+	// Um pedido não é importado pela metade: as demais linhas do pedido com linha inválida
+	// também são rejeitadas
+	if len(failedOrders) > 0 {
+		kept := mapped[:0]
+		for _, record := range mapped {
+			if failedOrders[record.Values["order_ref"]] {
+				rowErrors = append(rowErrors, models.RowError{Row: record.Row, Field: "order_ref",
+					Message: fmt.Sprintf("o pedido %s tem outra linha inválida", record.Values["order_ref"])})
+				continue
+			}
+			kept = append(kept, record)
+		}
+		mapped = kept
+	}
+	return mapped, rowErrors
+}
+
+// mapValue lê a origem do campo, aplica o padrão e as transformações e normaliza pelo tipo:
+// números com ponto decimal e datas em 2006-01-02
+func mapValue(field models.FieldMapping, target models.TargetField, source map[string]string) (string, error) {
+	value := ""
+	if field.Source != "" {
+		value = strings.TrimSpace(source[field.Source])
+	}
+	if value == "" {
+		value = field.Default
+	}
+
+	for _, transform := range field.Transforms {
+		switch transform {
+		case models.TransformUpper:
+			value = strings.ToUpper(value)
+		case models.TransformLower:
+			value = strings.ToLower(value)
+		case models.TransformDigits:
+			value = digits(value)
+		case models.TransformDecimalComma:
+			value = strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+		}
+	}
+
+	if value == "" {
+		if target.Required {
+			return "", fmt.Errorf("campo obrigatório vazio")
+		}
+		return "", nil
+	}
+
+	switch target.Type {
+	case models.FieldNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("número inválido %q", value)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case models.FieldInteger:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("número inteiro inválido %q", value)
+		}
+		return strconv.Itoa(number), nil
+	case models.FieldDate:
+		layouts := defaultDateLayouts
+		if field.DateLayout != "" {
+			layouts = []string{field.DateLayout}
+		}
+		for _, layout := range layouts {
+			if date, err := time.Parse(layout, value); err == nil {
+				return date.Format("2006-01-02"), nil
+			}
+		}
+		return "", fmt.Errorf("data inválida %q", value)
+	}
+	return value, nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/etl/models"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxRunRows limita a quantidade de registros de um arquivo
+const MaxRunRows = 10000
+
+// sourceRecord é um registro lido do arquivo, com os valores indexados pela coluna (CSV) ou
+// pelo caminho com pontos (JSON)
+type sourceRecord struct {
+	row    int
+	values map[string]string
+}
+
+// parseFile lê os registros do arquivo no formato do mapeamento
+func parseFile(format string, config models.MappingConfig, content []byte) ([]sourceRecord, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	var (
+		records []sourceRecord
+		err     error
+	)
+	switch format {
+	case models.FormatCSV:
+		records, err = parseCSV(config, content)
+	case models.FormatJSON:
+		records, err = parseJSON(config, content)
+	default:
+		return nil, fmt.Errorf("%w: formato %q", errors.ErrInvalidMapping, format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.ErrImportFileEmpty
+	}
+	if len(records) > MaxRunRows {
+		return nil, errors.ErrImportTooManyRows
+	}
+	return records, nil
+}
+
+// parseCSV lê um CSV com cabeçalho; a linha do registro é a linha do arquivo (o cabeçalho é a 1)
+func parseCSV(config models.MappingConfig, content []byte) ([]sourceRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = csvDelimiter(config.Delimiter, content)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidImportFile, err)
+	}
+	if len(rows) < 2 {
+		return nil, nil
+	}
+
+	header := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		header[i] = strings.TrimSpace(name)
+	}
+
+	records := make([]sourceRecord, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		values := make(map[string]string, len(header))
+		empty := true
+		for col, name := range header {
+			if col < len(row) {
+				values[name] = strings.TrimSpace(row[col])
+				empty = empty && values[name] == ""
+			}
+		}
+		if empty {
+			continue
+		}
+		records = append(records, sourceRecord{row: i + 2, values: values})
+	}
+	return records, nil
+}
+
+func csvDelimiter(configured string, content []byte) rune {
+	if configured != "" {
+		delimiter, _ := utf8.DecodeRuneInString(configured)
+		return delimiter
+	}
+
+	firstLine := content
+	if idx := bytes.IndexByte(content, '\n'); idx >= 0 {
+		firstLine = content[:idx]
+	}
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+// parseJSON lê a lista de objetos em RecordsPath; com ExpandPath, cada elemento da lista
+// interna vira um registro com os campos do objeto pai. A linha é a posição do objeto na lista.
+func parseJSON(config models.MappingConfig, content []byte) ([]sourceRecord, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidImportFile, err)
+	}
+
+	list, ok := lookupPath(document, config.RecordsPath).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: lista de registros não encontrada em %q", errors.ErrInvalidImportFile, config.RecordsPath)
+	}
+
+	records := make([]sourceRecord, 0, len(list))
+	for i, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: o registro %d não é um objeto", errors.ErrInvalidImportFile, i+1)
+		}
+
+		values := make(map[string]string)
+		flatten("", object, config.ExpandPath, values)
+		if config.ExpandPath == "" {
+			records = append(records, sourceRecord{row: i + 1, values: values})
+			continue
+		}
+
+		children, _ := lookupPath(object, config.ExpandPath).([]interface{})
+		for _, child := range children {
+			expanded := make(map[string]string, len(values))
+			for key, value := range values {
+				expanded[key] = value
+			}
+			if childObject, ok := child.(map[string]interface{}); ok {
+				flatten(config.ExpandPath, childObject, "", expanded)
+			} else {
+				expanded[config.ExpandPath] = scalar(child)
+			}
+			records = append(records, sourceRecord{row: i + 1, values: expanded})
+		}
+	}
+	return records, nil
+}
+
+// lookupPath navega pelos objetos seguindo o caminho com pontos; caminho vazio retorna a raiz
+func lookupPath(value interface{}, path string) interface{} {
+	if path == "" {
+		return value
+	}
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// flatten grava os valores escalares do objeto com a chave em caminho com pontos; listas são
+// ignoradas, exceto a expandida pelo chamador
+func flatten(prefix string, object map[string]interface{}, skip string, values map[string]string) {
+	for key, value := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if path == skip {
+			continue
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(path, v, skip, values)
+		case []interface{}:
+		default:
+			values[path] = scalar(v)
+		}
+	}
+}
+
+func scalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/expenses/models"
+	"ERP-ONSMART/backend/internal/modules/expenses/service"
+	users "ERP-ONSMART/backend/internal/modules/users/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Lista as categorias de despesa
+// @Security BearerAuth
+func ListCategoriesHandler(c *gin.Context) {
+	categories, err := service.ListCategories(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar categorias de despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+// Cadastra uma categoria de despesa, opcionalmente ligada a uma conta contábil de despesa
+// @Security BearerAuth
+func CreateCategoryHandler(c *gin.Context) {
+	var input models.CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	category, err := service.CreateCategory(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar categoria de despesa")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"category": category})
+}
+
+// Altera o nome, a conta contábil ou a situação de uma categoria de despesa
+// @Security BearerAuth
+// @Param id path int true "ID da categoria"
+func UpdateCategoryHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	category, err := service.UpdateCategory(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar categoria de despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// Lista as despesas, as mais recentes primeiro. Gestores e financeiro veem as de todos os
+// colaboradores; os demais, apenas as próprias.
+// @Security BearerAuth
+// @Param status query string false "draft, submitted, approved, rejected ou reimbursed"
+// @Param employee query string false "Usuário do colaborador"
+// @Param category_id query int false "ID da categoria"
+// @Param from query string false "Data inicial da despesa (AAAA-MM-DD)"
+// @Param to query string false "Data final da despesa, inclusiva (AAAA-MM-DD)"
+func ListExpensesHandler(c *gin.Context) {
+	filter := models.ExpenseFilter{Status: c.Query("status"), Employee: c.Query("employee")}
+	if value := c.Query("category_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("category_id inválido"))
+			return
+		}
+		filter.CategoryID = id
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.Error(errors.InvalidParam("from deve estar no formato AAAA-MM-DD"))
+			return
+		}
+		filter.From = from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.Error(errors.InvalidParam("to deve estar no formato AAAA-MM-DD"))
+			return
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	expenses, err := service.ListExpenses(c.Request.Context(), filter, currentActor(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar despesas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expenses": expenses})
+}
+
+// Busca uma despesa com a categoria
+// @Security BearerAuth
+// @Param id path int true "ID da despesa"
+func GetExpenseHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	expense, err := service.GetExpense(c.Request.Context(), id, currentActor(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+// Lança uma despesa do usuário como rascunho. O comprovante é anexado em
+// POST /documents/expense/:id/attachments antes do envio para aprovação.
+// @Security BearerAuth
+func CreateExpenseHandler(c *gin.Context) {
+	var input models.ExpenseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	expense, err := service.CreateExpense(c.Request.Context(), input, currentActor(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar despesa")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"expense": expense})
+}
+
+// Altera uma despesa do usuário em rascunho ou rejeitada
+// @Security BearerAuth
+// @Param id path int true "ID da despesa"
+func UpdateExpenseHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ExpenseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	expense, err := service.UpdateExpense(c.Request.Context(), id, input, currentActor(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+// Remove um rascunho de despesa do usuário
+// @Security BearerAuth
+// @Param id path int true "ID da despesa"
+func DeleteExpenseHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteExpense(c.Request.Context(), id, currentActor(c)); err != nil {
+		c.Error(err).SetMeta("erro ao remover despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Despesa removida com sucesso"})
+}
+
+// Envia a despesa para aprovação; exige ao menos um comprovante anexado
+// @Security BearerAuth
+// @Param id path int true "ID da despesa"
+func SubmitExpenseHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	expense, err := service.SubmitExpense(c.Request.Context(), id, currentActor(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao enviar despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+// Aprova a despesa enviada por outro colaborador
+// @Security BearerAuth
+// @Param id path int true "ID da despesa"
+func ApproveExpenseHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	expense, err := service.ApproveExpense(c.Request.Context(), id, currentActor(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao aprovar despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+// rejectRequest é o corpo de POST /expenses/:id/reject
+type rejectRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// Rejeita a despesa enviada, devolvendo-a ao colaborador com o motivo
+// @Security BearerAuth
+// @Param id path int true "ID da despesa"
+func RejectExpenseHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req rejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	expense, err := service.RejectExpense(c.Request.Context(), id, req.Reason, currentActor(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao rejeitar despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+// Registra o reembolso da despesa aprovada ao colaborador
+// @Security BearerAuth
+// @Param id path int true "ID da despesa"
+func ReimburseExpenseHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ReimbursementInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	expense, err := service.ReimburseExpense(c.Request.Context(), id, input, currentActor(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao reembolsar despesa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+// Soma as despesas aprovadas e reembolsadas por mês e categoria
+// @Security BearerAuth
+// @Param from query string false "Mês inicial (AAAA-MM, padrão janeiro do ano corrente)"
+// @Param to query string false "Mês final, inclusivo (AAAA-MM, padrão o mês atual)"
+func GetMonthlyReportHandler(c *gin.Context) {
+	report, err := service.MonthlyReport(c.Request.Context(), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar relatório de despesas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"months": report})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+// currentActor retorna o usuário do token JWT validado pelo AuthMiddleware; administradores
+// e financeiro revisam as despesas de todos
+func currentActor(c *gin.Context) models.Actor {
+	claims, ok := c.Get("claims")
+	if !ok {
+		return models.Actor{}
+	}
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return models.Actor{}
+	}
+	username, _ := mapClaims["username"].(string)
+	role, _ := mapClaims["role"].(string)
+	return models.Actor{
+		Username: username,
+		Reviewer: strings.EqualFold(role, users.RoleAdmin) || strings.EqualFold(role, users.RoleFinance),
+	}
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status da despesa: o colaborador cria o rascunho e o envia com o comprovante, o gestor aprova
+// ou rejeita (a rejeitada pode ser corrigida e reenviada) e o financeiro registra o reembolso
+const (
+	StatusDraft      = "draft"
+	StatusSubmitted  = "submitted"
+	StatusApproved   = "approved"
+	StatusRejected   = "rejected"
+	StatusReimbursed = "reimbursed"
+)
+
+// EntityType é o tipo de documento dos comprovantes no subsistema de anexos
+const EntityType = "expense"
+
+// MonthLayout é o formato dos meses do relatório (AAAA-MM)
+const MonthLayout = "2006-01"
+
+// transitions relaciona os status de destino aos status de origem permitidos
+var transitions = map[string][]string{
+	StatusSubmitted:  {StatusDraft, StatusRejected},
+	StatusApproved:   {StatusSubmitted},
+	StatusRejected:   {StatusSubmitted},
+	StatusReimbursed: {StatusApproved},
+}
+
+// ExpenseCategory agrupa as despesas nos relatórios; com conta contábil, a despesa aprovada é
+// lançada nela em vez da conta mapeada em "expenses"
+type ExpenseCategory struct {
+	ID              int       `json:"id" gorm:"primaryKey"`
+	CompanyID       int       `json:"company_id" gorm:"<-:create"`
+	Name            string    `json:"name"`
+	LedgerAccountID *int      `json:"ledger_account_id,omitempty"`
+	Active          bool      `json:"active"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Expense é uma despesa paga pelo colaborador e reembolsada pela empresa
+type Expense struct {
+	ID                     int              `json:"id" gorm:"primaryKey"`
+	CompanyID              int              `json:"company_id" gorm:"<-:create"`
+	Employee               string           `json:"employee"`
+	CategoryID             int              `json:"category_id"`
+	Description            string           `json:"description"`
+	ExpenseDate            time.Time        `json:"expense_date"`
+	Amount                 money.Decimal    `json:"amount"`
+	CostCenterID           *int             `json:"cost_center_id,omitempty"`
+	Notes                  string           `json:"notes,omitempty"`
+	Status                 string           `json:"status"`
+	SubmittedAt            *time.Time       `json:"submitted_at,omitempty"`
+	ApprovedBy             string           `json:"approved_by,omitempty"`
+	ApprovedAt             *time.Time       `json:"approved_at,omitempty"`
+	RejectedBy             string           `json:"rejected_by,omitempty"`
+	RejectedAt             *time.Time       `json:"rejected_at,omitempty"`
+	RejectionReason        string           `json:"rejection_reason,omitempty"`
+	ReimbursedBy           string           `json:"reimbursed_by,omitempty"`
+	ReimbursedAt           *time.Time       `json:"reimbursed_at,omitempty"`
+	ReimbursementReference string           `json:"reimbursement_reference,omitempty"`
+	CreatedAt              time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt              time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	Category               *ExpenseCategory `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+}
+
+// ExpenseInput é o corpo aceito em POST e PUT /expenses
+type ExpenseInput struct {
+	CategoryID   int           `json:"category_id" binding:"required"`
+	Description  string        `json:"description" binding:"required,max=255"`
+	ExpenseDate  time.Time     `json:"expense_date" binding:"required"`
+	Amount       money.Decimal `json:"amount" binding:"required"`
+	CostCenterID *int          `json:"cost_center_id"`
+	Notes        string        `json:"notes"`
+}
+
+// CategoryInput é o corpo aceito em POST e PUT /expenses/categories
+type CategoryInput struct {
+	Name            string `json:"name" binding:"required,max=100"`
+	LedgerAccountID *int   `json:"ledger_account_id"`
+	Active          *bool  `json:"active"`
+}
+
+// ReimbursementInput é o corpo aceito em POST /expenses/:id/reimburse; sem data, vale agora
+type ReimbursementInput struct {
+	Reference    string     `json:"reference" binding:"max=100"`
+	ReimbursedAt *time.Time `json:"reimbursed_at"`
+}
+
+// Actor é o usuário que opera a despesa. Revisores (gestores e financeiro) veem e tratam as
+// despesas de todos; os demais colaboradores, apenas as próprias.
+type Actor struct {
+	Username string
+	Reviewer bool
+}
+
+// CanView indica se o usuário pode ver a despesa
+func (a Actor) CanView(expense *Expense) bool {
+	return a.Reviewer || expense.Employee == a.Username
+}
+
+// ExpenseFilter restringe a listagem de despesas; campos vazios não filtram
+type ExpenseFilter struct {
+	Employee   string
+	Status     string
+	CategoryID int
+	From       time.Time
+	To         time.Time // exclusivo
+}
+
+// Validate confere o valor e a data da despesa, que não pode ser posterior a today (o dia da
+// empresa, à meia-noite UTC)
+func (in ExpenseInput) Validate(today time.Time) error {
+	if !in.Amount.IsPositive() || strings.TrimSpace(in.Description) == "" {
+		return errors.ErrInvalidExpense
+	}
+	if localtime.Date(in.ExpenseDate).After(today) {
+		return errors.ErrInvalidExpense
+	}
+	return nil
+}
+
+// Apply copia os campos editáveis para a despesa
+func (in ExpenseInput) Apply(expense *Expense) {
+	expense.CategoryID = in.CategoryID
+	expense.Description = strings.TrimSpace(in.Description)
+	expense.ExpenseDate = in.ExpenseDate
+	expense.Amount = in.Amount.Round(2)
+	expense.CostCenterID = in.CostCenterID
+	expense.Notes = in.Notes
+}
+
+// Editable indica se o colaborador ainda pode alterar a despesa
+func (e Expense) Editable() bool {
+	return e.Status == StatusDraft || e.Status == StatusRejected
+}
+
+// CanTransition indica se a despesa pode passar ao status informado
+func (e Expense) CanTransition(to string) bool {
+	for _, from := range transitions[to] {
+		if e.Status == from {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryTotal é o total das despesas de uma categoria no mês
+type CategoryTotal struct {
+	Month        string        `json:"month"`
+	CategoryID   int           `json:"category_id"`
+	CategoryName string        `json:"category_name"`
+	Count        int           `json:"count"`
+	Amount       money.Decimal `json:"amount"`
+}
+
+// MonthlyReport é o total de um mês, aberto por categoria
+type MonthlyReport struct {
+	Month      string          `json:"month"`
+	Count      int             `json:"count"`
+	Amount     money.Decimal   `json:"amount"`
+	Categories []CategoryTotal `json:"categories"`
+}
+
+// BuildMonthlyReport agrupa os totais por mês, em ordem cronológica, com as categorias da de
+// maior valor para a de menor
+func BuildMonthlyReport(rows []CategoryTotal) []MonthlyReport {
+	months := map[string]*MonthlyReport{}
+	var order []string
+	for _, row := range rows {
+		month, ok := months[row.Month]
+		if !ok {
+			month = &MonthlyReport{Month: row.Month, Categories: []CategoryTotal{}}
+			months[row.Month] = month
+			order = append(order, row.Month)
+		}
+		month.Count += row.Count
+		month.Amount = month.Amount.Add(row.Amount)
+		month.Categories = append(month.Categories, row)
+	}
+	sort.Strings(order)
+
+	report := make([]MonthlyReport, 0, len(order))
+	for _, key := range order {
+		month := months[key]
+		sort.SliceStable(month.Categories, func(i, j int) bool {
+			if !month.Categories[i].Amount.Equal(month.Categories[j].Amount) {
+				return month.Categories[i].Amount.GreaterThan(month.Categories[j].Amount)
+			}
+			return month.Categories[i].CategoryName < month.Categories[j].CategoryName
+		})
+		report = append(report, *month)
+	}
+	return report
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpenseInputValidate(t *testing.T) {
+	today := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	input := ExpenseInput{CategoryID: 1, Description: "Almoço com cliente", ExpenseDate: today.Add(15 * time.Hour), Amount: money.FromInt(90)}
+	assert.NoError(t, input.Validate(today), "despesa de hoje, com horário")
+
+	future := input
+	future.ExpenseDate = today.AddDate(0, 0, 1)
+	assert.ErrorIs(t, future.Validate(today), errors.ErrInvalidExpense)
+
+	zero := input
+	zero.Amount = money.Zero
+	assert.ErrorIs(t, zero.Validate(today), errors.ErrInvalidExpense)
+
+	blank := input
+	blank.Description = "  "
+	assert.ErrorIs(t, blank.Validate(today), errors.ErrInvalidExpense)
+}
+
+func TestExpenseTransitions(t *testing.T) {
+	cases := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{StatusDraft, StatusSubmitted, true},
+		{StatusRejected, StatusSubmitted, true},
+		{StatusSubmitted, StatusApproved, true},
+		{StatusSubmitted, StatusRejected, true},
+		{StatusApproved, StatusReimbursed, true},
+		{StatusDraft, StatusApproved, false},
+		{StatusSubmitted, StatusReimbursed, false},
+		{StatusReimbursed, StatusSubmitted, false},
+		{StatusApproved, StatusRejected, false},
+	}
+	for _, tc := range cases {
+		expense := Expense{Status: tc.from}
+		assert.Equal(t, tc.allowed, expense.CanTransition(tc.to), "%s -> %s", tc.from, tc.to)
+	}
+
+	assert.True(t, Expense{Status: StatusRejected}.Editable())
+	assert.False(t, Expense{Status: StatusSubmitted}.Editable())
+}
+
+func TestBuildMonthlyReport(t *testing.T) {
+	report := BuildMonthlyReport([]CategoryTotal{
+		{Month: "2026-02", CategoryID: 1, CategoryName: "Viagens", Count: 1, Amount: money.FromInt(100)},
+		{Month: "2026-01", CategoryID: 1, CategoryName: "Viagens", Count: 2, Amount: money.FromInt(50)},
+		{Month: "2026-01", CategoryID: 2, CategoryName: "Alimentação", Count: 3, Amount: money.FromInt(120)},
+	})
+
+	assert.Len(t, report, 2)
+	assert.Equal(t, "2026-01", report[0].Month)
+	assert.Equal(t, 5, report[0].Count)
+	assert.Equal(t, "170.00", report[0].Amount.StringFixed(2))
+	assert.Equal(t, "Alimentação", report[0].Categories[0].CategoryName, "maior valor primeiro")
+	assert.Equal(t, "2026-02", report[1].Month)
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	attachments "ERP-ONSMART/backend/internal/modules/attachments/models"
+	"ERP-ONSMART/backend/internal/modules/expenses/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ExpenseRepository mantém as despesas dos colaboradores e as suas categorias, conta os
+// comprovantes anexados e soma as despesas por mês para os relatórios
+type ExpenseRepository interface {
+	ListCategories(ctx context.Context) ([]models.ExpenseCategory, error)
+	GetCategory(ctx context.Context, id int) (*models.ExpenseCategory, error)
+	CreateCategory(ctx context.Context, category *models.ExpenseCategory) error
+	UpdateCategory(ctx context.Context, category *models.ExpenseCategory) error
+	GetLedgerAccount(ctx context.Context, id int) (*accounting.LedgerAccount, error)
+
+	ListExpenses(ctx context.Context, filter models.ExpenseFilter) ([]models.Expense, error)
+	GetExpense(ctx context.Context, id int) (*models.Expense, error)
+	CreateExpense(ctx context.Context, expense *models.Expense) error
+	UpdateExpense(ctx context.Context, expense *models.Expense) error
+	DeleteExpense(ctx context.Context, id int) error
+	ChangeStatus(ctx context.Context, expense *models.Expense, from string) error
+	CountReceipts(ctx context.Context, id int) (int64, error)
+
+	MonthlyTotals(ctx context.Context, from, to time.Time) ([]models.CategoryTotal, error)
+}
+
+type expenseRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewExpenseRepository cria uma nova instância do repositório
+func NewExpenseRepository() (ExpenseRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &expenseRepository{
+		db:     db,
+		logger: logger.WithModule("expense_repository"),
+	}, nil
+}
+
+// ListCategories lista as categorias de despesa da empresa em ordem alfabética
+func (r *expenseRepository) ListCategories(ctx context.Context) ([]models.ExpenseCategory, error) {
+	var categories []models.ExpenseCategory
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&categories).Error; err != nil {
+		r.logger.Error("erro ao listar categorias de despesa", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar categorias de despesa")
+	}
+	return categories, nil
+}
+
+// GetCategory busca uma categoria de despesa
+func (r *expenseRepository) GetCategory(ctx context.Context, id int) (*models.ExpenseCategory, error) {
+	var category models.ExpenseCategory
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrExpenseCategoryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar categoria de despesa")
+	}
+	return &category, nil
+}
+
+// CreateCategory grava uma categoria de despesa
+func (r *expenseRepository) CreateCategory(ctx context.Context, category *models.ExpenseCategory) error {
+	if err := r.db.WithContext(ctx).Create(category).Error; err != nil {
+		r.logger.Error("erro ao criar categoria de despesa", zap.Error(err), zap.String("name", category.Name))
+		return errors.WrapError(err, "falha ao criar categoria de despesa")
+	}
+	return nil
+}
+
+// UpdateCategory altera o nome, a conta contábil e a situação da categoria
+func (r *expenseRepository) UpdateCategory(ctx context.Context, category *models.ExpenseCategory) error {
+	result := r.db.WithContext(ctx).Model(category).
+		Select("name", "ledger_account_id", "active", "updated_at").
+		Updates(category)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar categoria de despesa", zap.Error(result.Error), zap.Int("id", category.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar categoria de despesa")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrExpenseCategoryNotFound
+	}
+	return nil
+}
+
+// GetLedgerAccount busca a conta contábil informada na categoria
+func (r *expenseRepository) GetLedgerAccount(ctx context.Context, id int) (*accounting.LedgerAccount, error) {
+	var account accounting.LedgerAccount
+	if err := r.db.WithContext(ctx).First(&account, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrLedgerAccountNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar conta contábil")
+	}
+	return &account, nil
+}
+
+// ListExpenses lista as despesas da empresa, as mais recentes primeiro
+func (r *expenseRepository) ListExpenses(ctx context.Context, filter models.ExpenseFilter) ([]models.Expense, error) {
+	query := r.db.WithContext(ctx).Preload("Category")
+	if filter.Employee != "" {
+		query = query.Where("employee = ?", filter.Employee)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.CategoryID != 0 {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("expense_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("expense_date < ?", filter.To)
+	}
+
+	var expenses []models.Expense
+	if err := query.Order("expense_date DESC, id DESC").Find(&expenses).Error; err != nil {
+		r.logger.Error("erro ao listar despesas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar despesas")
+	}
+	return expenses, nil
+}
+
+// GetExpense busca uma despesa com a categoria
+func (r *expenseRepository) GetExpense(ctx context.Context, id int) (*models.Expense, error) {
+	var expense models.Expense
+	if err := r.db.WithContext(ctx).Preload("Category").First(&expense, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrExpenseNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar despesa")
+	}
+	return &expense, nil
+}
+
+// CreateExpense grava o rascunho da despesa
+func (r *expenseRepository) CreateExpense(ctx context.Context, expense *models.Expense) error {
+	if err := r.db.WithContext(ctx).Omit("Category").Create(expense).Error; err != nil {
+		r.logger.Error("erro ao criar despesa", zap.Error(err), zap.String("employee", expense.Employee))
+		return errors.WrapError(err, "falha ao criar despesa")
+	}
+	return nil
+}
+
+// UpdateExpense altera os campos editáveis da despesa em rascunho ou rejeitada
+func (r *expenseRepository) UpdateExpense(ctx context.Context, expense *models.Expense) error {
+	result := r.db.WithContext(ctx).Model(expense).
+		Where("status IN ?", []string{models.StatusDraft, models.StatusRejected}).
+		Select("category_id", "description", "expense_date", "amount", "cost_center_id", "notes", "updated_at").
+		Updates(expense)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar despesa", zap.Error(result.Error), zap.Int("id", expense.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar despesa")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrInvalidExpenseTransition
+	}
+	return nil
+}
+
+// DeleteExpense remove a despesa em rascunho
+func (r *expenseRepository) DeleteExpense(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Where("status = ?", models.StatusDraft).Delete(&models.Expense{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover despesa", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover despesa")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrInvalidExpenseTransition
+	}
+	return nil
+}
+
+// ChangeStatus grava o novo status e os dados da etapa, desde que a despesa ainda esteja no
+// status from: duas aprovações simultâneas não passam as duas
+func (r *expenseRepository) ChangeStatus(ctx context.Context, expense *models.Expense, from string) error {
+	result := r.db.WithContext(ctx).Model(expense).
+		Where("status = ?", from).
+		Select("status", "submitted_at", "approved_by", "approved_at", "rejected_by", "rejected_at",
+			"rejection_reason", "reimbursed_by", "reimbursed_at", "reimbursement_reference", "updated_at").
+		Updates(expense)
+	if result.Error != nil {
+		r.logger.Error("erro ao alterar status da despesa", zap.Error(result.Error), zap.Int("id", expense.ID))
+		return errors.WrapError(result.Error, "falha ao alterar status da despesa")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrInvalidExpenseTransition
+	}
+	return nil
+}
+
+// CountReceipts conta os comprovantes anexados à despesa
+func (r *expenseRepository) CountReceipts(ctx context.Context, id int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&attachments.Attachment{}).
+		Where("entity_type = ? AND entity_id = ?", models.EntityType, id).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.WrapError(err, "falha ao contar comprovantes da despesa")
+	}
+	return count, nil
+}
+
+// MonthlyTotals soma as despesas aprovadas e reembolsadas por mês da despesa e categoria, no
+// período [from, to)
+func (r *expenseRepository) MonthlyTotals(ctx context.Context, from, to time.Time) ([]models.CategoryTotal, error) {
+	var rows []models.CategoryTotal
+	err := r.db.WithContext(ctx).Table("expenses e").
+		Scopes(tenant.Scope(ctx, "e")).
+		Select(`TO_CHAR(e.expense_date, 'YYYY-MM') AS month, e.category_id, c.name AS category_name,
+			COUNT(*) AS count, COALESCE(SUM(e.amount), 0) AS amount`).
+		Joins("JOIN expense_categories c ON c.id = e.category_id").
+		Where("e.status IN ?", []string{models.StatusApproved, models.StatusReimbursed}).
+		Where("e.expense_date >= ? AND e.expense_date < ?", from, to).
+		Group("month, e.category_id, c.name").
+		Order("month ASC").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao somar despesas por mês", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao somar despesas por mês")
+	}
+	return rows, nil
+}
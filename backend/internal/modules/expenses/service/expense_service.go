@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/expenses/models"
+	"ERP-ONSMART/backend/internal/modules/expenses/repository"
+	tasks "ERP-ONSMART/backend/internal/modules/tasks/models"
+	tasksService "ERP-ONSMART/backend/internal/modules/tasks/service"
+
+	"go.uber.org/zap"
+)
+
+// MaxReportMonths é o maior período do relatório mensal
+const MaxReportMonths = 24
+
+// ExpenseService conduz as despesas dos colaboradores do envio com comprovante ao reembolso
+type ExpenseService struct {
+	newRepo func() (repository.ExpenseRepository, error)
+	now     func() time.Time
+	today   func(ctx context.Context) time.Time
+	// openTask e closeTask mantêm a tarefa de aprovação da despesa enviada
+	openTask  func(ctx context.Context, task tasks.Task) error
+	closeTask func(ctx context.Context, sourceKey, closedBy string) error
+	logger    *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.ExpenseRepository
+}
+
+// NewExpenseService cria o serviço sobre o repositório informado
+func NewExpenseService(newRepo func() (repository.ExpenseRepository, error)) *ExpenseService {
+	return &ExpenseService{
+		newRepo:   newRepo,
+		now:       time.Now,
+		today:     localtime.Today,
+		openTask:  tasksService.Open,
+		closeTask: tasksService.Close,
+		logger:    logger.WithModule("expense_service"),
+	}
+}
+
+var defaultService = NewExpenseService(repository.NewExpenseRepository)
+
+// ListCategories lista as categorias de despesa
+func ListCategories(ctx context.Context) ([]models.ExpenseCategory, error) {
+	return defaultService.ListCategories(ctx)
+}
+
+// CreateCategory valida e grava uma categoria de despesa
+func CreateCategory(ctx context.Context, input models.CategoryInput) (*models.ExpenseCategory, error) {
+	return defaultService.CreateCategory(ctx, input)
+}
+
+// UpdateCategory valida e altera uma categoria de despesa
+func UpdateCategory(ctx context.Context, id int, input models.CategoryInput) (*models.ExpenseCategory, error) {
+	return defaultService.UpdateCategory(ctx, id, input)
+}
+
+// ListExpenses lista as despesas visíveis ao usuário
+func ListExpenses(ctx context.Context, filter models.ExpenseFilter, actor models.Actor) ([]models.Expense, error) {
+	return defaultService.ListExpenses(ctx, filter, actor)
+}
+
+// GetExpense busca uma despesa visível ao usuário
+func GetExpense(ctx context.Context, id int, actor models.Actor) (*models.Expense, error) {
+	return defaultService.GetExpense(ctx, id, actor)
+}
+
+// CreateExpense grava o rascunho de uma despesa do usuário
+func CreateExpense(ctx context.Context, input models.ExpenseInput, actor models.Actor) (*models.Expense, error) {
+	return defaultService.CreateExpense(ctx, input, actor)
+}
+
+// UpdateExpense altera uma despesa em rascunho ou rejeitada do usuário
+func UpdateExpense(ctx context.Context, id int, input models.ExpenseInput, actor models.Actor) (*models.Expense, error) {
+	return defaultService.UpdateExpense(ctx, id, input, actor)
+}
+
+// DeleteExpense remove um rascunho do usuário
+func DeleteExpense(ctx context.Context, id int, actor models.Actor) error {
+	return defaultService.DeleteExpense(ctx, id, actor)
+}
+
+// SubmitExpense envia a despesa para aprovação
+func SubmitExpense(ctx context.Context, id int, actor models.Actor) (*models.Expense, error) {
+	return defaultService.Submit(ctx, id, actor)
+}
+
+// ApproveExpense aprova a despesa enviada
+func ApproveExpense(ctx context.Context, id int, actor models.Actor) (*models.Expense, error) {
+	return defaultService.Approve(ctx, id, actor)
+}
+
+// RejectExpense rejeita a despesa enviada com o motivo
+func RejectExpense(ctx context.Context, id int, reason string, actor models.Actor) (*models.Expense, error) {
+	return defaultService.Reject(ctx, id, reason, actor)
+}
+
+// ReimburseExpense registra o reembolso da despesa aprovada
+func ReimburseExpense(ctx context.Context, id int, input models.ReimbursementInput, actor models.Actor) (*models.Expense, error) {
+	return defaultService.Reimburse(ctx, id, input, actor)
+}
+
+// MonthlyReport soma as despesas aprovadas por mês e categoria
+func MonthlyReport(ctx context.Context, from, to string) ([]models.MonthlyReport, error) {
+	return defaultService.MonthlyReport(ctx, from, to)
+}
+
+func (s *ExpenseService) repository() (repository.ExpenseRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListCategories lista as categorias de despesa
+func (s *ExpenseService) ListCategories(ctx context.Context) ([]models.ExpenseCategory, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListCategories(ctx)
+}
+
+// CreateCategory grava a categoria, ativa por padrão
+func (s *ExpenseService) CreateCategory(ctx context.Context, input models.CategoryInput) (*models.ExpenseCategory, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	category := &models.ExpenseCategory{Active: true}
+	if err := s.applyCategory(ctx, repo, category, input); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory altera a categoria; despesas já lançadas na contabilidade não mudam de conta
+func (s *ExpenseService) UpdateCategory(ctx context.Context, id int, input models.CategoryInput) (*models.ExpenseCategory, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	category, err := repo.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyCategory(ctx, repo, category, input); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// applyCategory copia os campos da categoria; a conta contábil, se informada, deve ser de despesa
+func (s *ExpenseService) applyCategory(ctx context.Context, repo repository.ExpenseRepository, category *models.ExpenseCategory, input models.CategoryInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: informe o nome da categoria", errors.ErrInvalidExpense)
+	}
+	if input.LedgerAccountID != nil {
+		account, err := repo.GetLedgerAccount(ctx, *input.LedgerAccountID)
+		if err != nil {
+			return err
+		}
+		if account.Type != accounting.AccountTypeExpense {
+			return errors.ErrInvalidAccountType
+		}
+	}
+	category.Name = name
+	category.LedgerAccountID = input.LedgerAccountID
+	if input.Active != nil {
+		category.Active = *input.Active
+	}
+	return nil
+}
+
+// ListExpenses lista as despesas conforme o filtro; quem não é revisor vê só as próprias
+func (s *ExpenseService) ListExpenses(ctx context.Context, filter models.ExpenseFilter, actor models.Actor) ([]models.Expense, error) {
+	if !actor.Reviewer {
+		filter.Employee = actor.Username
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListExpenses(ctx, filter)
+}
+
+// GetExpense busca a despesa; a de outro colaborador aparece como inexistente a quem não é revisor
+func (s *ExpenseService) GetExpense(ctx context.Context, id int, actor models.Actor) (*models.Expense, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return s.visibleExpense(ctx, repo, id, actor)
+}
+
+// CreateExpense grava o rascunho em nome do usuário; o comprovante é anexado em seguida
+func (s *ExpenseService) CreateExpense(ctx context.Context, input models.ExpenseInput, actor models.Actor) (*models.Expense, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateInput(ctx, repo, input); err != nil {
+		return nil, err
+	}
+
+	expense := &models.Expense{Employee: actor.Username, Status: models.StatusDraft}
+	input.Apply(expense)
+	if err := repo.CreateExpense(ctx, expense); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// UpdateExpense altera a despesa do próprio usuário enquanto em rascunho ou rejeitada
+func (s *ExpenseService) UpdateExpense(ctx context.Context, id int, input models.ExpenseInput, actor models.Actor) (*models.Expense, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.ownExpense(ctx, repo, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if !expense.Editable() {
+		return nil, errors.ErrInvalidExpenseTransition
+	}
+	if err := s.validateInput(ctx, repo, input); err != nil {
+		return nil, err
+	}
+
+	input.Apply(expense)
+	if err := repo.UpdateExpense(ctx, expense); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// DeleteExpense remove o rascunho do próprio usuário
+func (s *ExpenseService) DeleteExpense(ctx context.Context, id int, actor models.Actor) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	expense, err := s.ownExpense(ctx, repo, id, actor)
+	if err != nil {
+		return err
+	}
+	if expense.Status != models.StatusDraft {
+		return errors.ErrInvalidExpenseTransition
+	}
+	return repo.DeleteExpense(ctx, id)
+}
+
+// Submit envia a despesa do próprio usuário para aprovação; exige ao menos um comprovante
+func (s *ExpenseService) Submit(ctx context.Context, id int, actor models.Actor) (*models.Expense, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.ownExpense(ctx, repo, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if !expense.CanTransition(models.StatusSubmitted) {
+		return nil, errors.ErrInvalidExpenseTransition
+	}
+	receipts, err := repo.CountReceipts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if receipts == 0 {
+		return nil, errors.ErrExpenseReceiptRequired
+	}
+
+	now := s.now()
+	from := expense.Status
+	expense.Status = models.StatusSubmitted
+	expense.SubmittedAt = &now
+	expense.RejectedBy, expense.RejectedAt, expense.RejectionReason = "", nil, ""
+	if err := repo.ChangeStatus(ctx, expense, from); err != nil {
+		return nil, err
+	}
+	s.openApprovalTask(ctx, expense)
+	return expense, nil
+}
+
+// Approve aprova a despesa enviada; o colaborador não aprova a própria despesa. A despesa
+// aprovada entra no fluxo de caixa como reembolso a pagar e pode ser contabilizada.
+func (s *ExpenseService) Approve(ctx context.Context, id int, actor models.Actor) (*models.Expense, error) {
+	return s.review(ctx, id, actor, func(expense *models.Expense, now time.Time) {
+		expense.Status = models.StatusApproved
+		expense.ApprovedBy = actor.Username
+		expense.ApprovedAt = &now
+	})
+}
+
+// Reject devolve a despesa enviada ao colaborador com o motivo, para correção e reenvio
+func (s *ExpenseService) Reject(ctx context.Context, id int, reason string, actor models.Actor) (*models.Expense, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: informe o motivo da rejeição", errors.ErrInvalidExpense)
+	}
+	return s.review(ctx, id, actor, func(expense *models.Expense, now time.Time) {
+		expense.Status = models.StatusRejected
+		expense.RejectedBy = actor.Username
+		expense.RejectedAt = &now
+		expense.RejectionReason = reason
+	})
+}
+
+// review aplica a decisão do gestor sobre a despesa enviada
+func (s *ExpenseService) review(ctx context.Context, id int, actor models.Actor, decide func(*models.Expense, time.Time)) (*models.Expense, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	expense, err := repo.GetExpense(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expense.Status != models.StatusSubmitted {
+		return nil, errors.ErrInvalidExpenseTransition
+	}
+	if expense.Employee == actor.Username {
+		return nil, errors.ErrExpenseSelfApproval
+	}
+
+	decide(expense, s.now())
+	if err := repo.ChangeStatus(ctx, expense, models.StatusSubmitted); err != nil {
+		return nil, err
+	}
+	if err := s.closeTask(ctx, tasks.ApprovalKey("expense", expense.ID), actor.Username); err != nil {
+		s.logger.Warn("erro ao concluir tarefa de aprovação da despesa", zap.Error(err), zap.Int("expense_id", expense.ID))
+	}
+	return expense, nil
+}
+
+// openApprovalTask abre, para os gestores, a tarefa de aprovar a despesa enviada. A despesa já
+// foi enviada: falhas só vão para o log.
+func (s *ExpenseService) openApprovalTask(ctx context.Context, expense *models.Expense) {
+	id := expense.ID
+	key := tasks.ApprovalKey("expense", id)
+	due := tasks.DateOf(s.today(ctx))
+	task := tasks.Task{
+		Title:       fmt.Sprintf("Aprovar despesa de %s: %s", expense.Employee, expense.Description),
+		Description: fmt.Sprintf("Despesa de R$ %s em %s.", expense.Amount.String(), expense.ExpenseDate.Format("02/01/2006")),
+		DueDate:     &due,
+		EntityType:  "expense",
+		EntityID:    &id,
+		Source:      tasks.SourceApproval,
+		SourceKey:   &key,
+		CreatedBy:   expense.Employee,
+	}
+	if err := s.openTask(ctx, task); err != nil {
+		s.logger.Warn("erro ao abrir tarefa de aprovação da despesa", zap.Error(err), zap.Int("expense_id", id))
+	}
+}
+
+// Reimburse registra o pagamento do reembolso da despesa aprovada
+func (s *ExpenseService) Reimburse(ctx context.Context, id int, input models.ReimbursementInput, actor models.Actor) (*models.Expense, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	expense, err := repo.GetExpense(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !expense.CanTransition(models.StatusReimbursed) {
+		return nil, errors.ErrInvalidExpenseTransition
+	}
+
+	reimbursedAt := s.now()
+	if input.ReimbursedAt != nil {
+		reimbursedAt = *input.ReimbursedAt
+	}
+	if expense.ApprovedAt != nil && reimbursedAt.Before(*expense.ApprovedAt) {
+		return nil, fmt.Errorf("%w: o reembolso não pode ser anterior à aprovação", errors.ErrInvalidExpense)
+	}
+
+	expense.Status = models.StatusReimbursed
+	expense.ReimbursedBy = actor.Username
+	expense.ReimbursedAt = &reimbursedAt
+	expense.ReimbursementReference = strings.TrimSpace(input.Reference)
+	if err := repo.ChangeStatus(ctx, expense, models.StatusApproved); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// MonthlyReport soma as despesas aprovadas e reembolsadas por mês (AAAA-MM, inclusivos) e
+// categoria. Sem período, vale o ano corrente até o mês atual.
+func (s *ExpenseService) MonthlyReport(ctx context.Context, from, to string) ([]models.MonthlyReport, error) {
+	today := s.today(ctx)
+	start := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if from != "" {
+		if start, err = time.Parse(models.MonthLayout, from); err != nil {
+			return nil, errors.InvalidParam("from deve estar no formato AAAA-MM")
+		}
+	}
+	if to != "" {
+		if end, err = time.Parse(models.MonthLayout, to); err != nil {
+			return nil, errors.InvalidParam("to deve estar no formato AAAA-MM")
+		}
+	}
+	if end.Before(start) {
+		return nil, errors.ErrInvalidDateRange
+	}
+	if end.After(start.AddDate(0, MaxReportMonths-1, 0)) {
+		return nil, errors.InvalidParam(fmt.Sprintf("o período do relatório é de no máximo %d meses", MaxReportMonths))
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := repo.MonthlyTotals(ctx, start, end.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildMonthlyReport(rows), nil
+}
+
+// validateInput confere os campos e a categoria, que deve estar ativa
+func (s *ExpenseService) validateInput(ctx context.Context, repo repository.ExpenseRepository, input models.ExpenseInput) error {
+	if err := input.Validate(s.today(ctx)); err != nil {
+		return err
+	}
+	category, err := repo.GetCategory(ctx, input.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !category.Active {
+		return fmt.Errorf("%w: categoria inativa", errors.ErrInvalidExpense)
+	}
+	return nil
+}
+
+// visibleExpense busca a despesa, escondendo as de outros colaboradores de quem não é revisor
+func (s *ExpenseService) visibleExpense(ctx context.Context, repo repository.ExpenseRepository, id int, actor models.Actor) (*models.Expense, error) {
+	expense, err := repo.GetExpense(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanView(expense) {
+		return nil, errors.ErrExpenseNotFound
+	}
+	return expense, nil
+}
+
+// ownExpense busca a despesa do próprio usuário: só o colaborador altera, envia ou remove
+func (s *ExpenseService) ownExpense(ctx context.Context, repo repository.ExpenseRepository, id int, actor models.Actor) (*models.Expense, error) {
+	expense, err := s.visibleExpense(ctx, repo, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if expense.Employee != actor.Username {
+		return nil, errors.ErrForbidden
+	}
+	return expense, nil
+}
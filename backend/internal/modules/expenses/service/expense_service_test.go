@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/expenses/models"
+	"ERP-ONSMART/backend/internal/modules/expenses/repository"
+	tasks "ERP-ONSMART/backend/internal/modules/tasks/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	categories map[int]*models.ExpenseCategory
+	expenses   map[int]*models.Expense
+	receipts   map[int]int64
+	totals     []models.CategoryTotal
+	from, to   time.Time
+	// tarefas de aprovação abertas, pela chave
+	openTasks map[string]tasks.Task
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		categories: map[int]*models.ExpenseCategory{
+			1: {ID: 1, Name: "Viagens", Active: true},
+			2: {ID: 2, Name: "Antiga", Active: false},
+		},
+		expenses: map[int]*models.Expense{},
+		receipts: map[int]int64{},
+	}
+}
+
+func (r *fakeRepo) ListCategories(ctx context.Context) ([]models.ExpenseCategory, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetCategory(ctx context.Context, id int) (*models.ExpenseCategory, error) {
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, appErrors.ErrExpenseCategoryNotFound
+	}
+	copied := *category
+	return &copied, nil
+}
+
+func (r *fakeRepo) CreateCategory(ctx context.Context, category *models.ExpenseCategory) error {
+	category.ID = len(r.categories) + 1
+	r.categories[category.ID] = category
+	return nil
+}
+
+func (r *fakeRepo) UpdateCategory(ctx context.Context, category *models.ExpenseCategory) error {
+	r.categories[category.ID] = category
+	return nil
+}
+
+func (r *fakeRepo) GetLedgerAccount(ctx context.Context, id int) (*accounting.LedgerAccount, error) {
+	accountType := accounting.AccountTypeExpense
+	if id == 99 {
+		accountType = accounting.AccountTypeAsset
+	}
+	return &accounting.LedgerAccount{ID: id, Type: accountType}, nil
+}
+
+func (r *fakeRepo) ListExpenses(ctx context.Context, filter models.ExpenseFilter) ([]models.Expense, error) {
+	var expenses []models.Expense
+	for _, expense := range r.expenses {
+		if filter.Employee == "" || expense.Employee == filter.Employee {
+			expenses = append(expenses, *expense)
+		}
+	}
+	return expenses, nil
+}
+
+func (r *fakeRepo) GetExpense(ctx context.Context, id int) (*models.Expense, error) {
+	expense, ok := r.expenses[id]
+	if !ok {
+		return nil, appErrors.ErrExpenseNotFound
+	}
+	copied := *expense
+	return &copied, nil
+}
+
+func (r *fakeRepo) CreateExpense(ctx context.Context, expense *models.Expense) error {
+	expense.ID = len(r.expenses) + 1
+	copied := *expense
+	r.expenses[expense.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) UpdateExpense(ctx context.Context, expense *models.Expense) error {
+	copied := *expense
+	r.expenses[expense.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) DeleteExpense(ctx context.Context, id int) error {
+	delete(r.expenses, id)
+	return nil
+}
+
+func (r *fakeRepo) ChangeStatus(ctx context.Context, expense *models.Expense, from string) error {
+	if r.expenses[expense.ID].Status != from {
+		return appErrors.ErrInvalidExpenseTransition
+	}
+	copied := *expense
+	r.expenses[expense.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) CountReceipts(ctx context.Context, id int) (int64, error) {
+	return r.receipts[id], nil
+}
+
+func (r *fakeRepo) MonthlyTotals(ctx context.Context, from, to time.Time) ([]models.CategoryTotal, error) {
+	r.from, r.to = from, to
+	return r.totals, nil
+}
+
+var (
+	employee = models.Actor{Username: "ana"}
+	manager  = models.Actor{Username: "carlos", Reviewer: true}
+)
+
+func newTestService(repo *fakeRepo) *ExpenseService {
+	s := NewExpenseService(func() (repository.ExpenseRepository, error) { return repo, nil })
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC) }
+	s.today = func(ctx context.Context) time.Time { return time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC) }
+	repo.openTasks = map[string]tasks.Task{}
+	s.openTask = func(ctx context.Context, task tasks.Task) error {
+		repo.openTasks[*task.SourceKey] = task
+		return nil
+	}
+	s.closeTask = func(ctx context.Context, sourceKey, closedBy string) error {
+		delete(repo.openTasks, sourceKey)
+		return nil
+	}
+	return s
+}
+
+func expenseInput() models.ExpenseInput {
+	return models.ExpenseInput{
+		CategoryID:  1,
+		Description: "Táxi até o cliente",
+		ExpenseDate: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		Amount:      money.FromCents(4590),
+	}
+}
+
+func TestExpenseLifecycle(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	expense, err := s.CreateExpense(ctx, expenseInput(), employee)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDraft, expense.Status)
+	assert.Equal(t, "ana", expense.Employee)
+
+	_, err = s.Submit(ctx, expense.ID, employee)
+	assert.ErrorIs(t, err, appErrors.ErrExpenseReceiptRequired)
+
+	repo.receipts[expense.ID] = 1
+	_, err = s.Submit(ctx, expense.ID, manager)
+	assert.ErrorIs(t, err, appErrors.ErrForbidden, "só o colaborador envia a própria despesa")
+	expense, err = s.Submit(ctx, expense.ID, employee)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusSubmitted, expense.Status)
+	key := tasks.ApprovalKey("expense", expense.ID)
+	require.Contains(t, repo.openTasks, key, "o envio abre a tarefa de aprovação")
+	assert.Equal(t, tasks.SourceApproval, repo.openTasks[key].Source)
+
+	_, err = s.Approve(ctx, expense.ID, models.Actor{Username: "ana", Reviewer: true})
+	assert.ErrorIs(t, err, appErrors.ErrExpenseSelfApproval)
+
+	expense, err = s.Reject(ctx, expense.ID, "sem nota fiscal", manager)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusRejected, expense.Status)
+	assert.NotContains(t, repo.openTasks, key, "a decisão conclui a tarefa de aprovação")
+
+	input := expenseInput()
+	input.Amount = money.FromInt(40)
+	_, err = s.UpdateExpense(ctx, expense.ID, input, employee)
+	require.NoError(t, err)
+	expense, err = s.Submit(ctx, expense.ID, employee)
+	require.NoError(t, err)
+	assert.Empty(t, expense.RejectionReason, "o reenvio limpa a rejeição")
+
+	expense, err = s.Approve(ctx, expense.ID, manager)
+	require.NoError(t, err)
+	assert.Equal(t, "carlos", expense.ApprovedBy)
+
+	_, err = s.UpdateExpense(ctx, expense.ID, input, employee)
+	assert.ErrorIs(t, err, appErrors.ErrInvalidExpenseTransition)
+
+	expense, err = s.Reimburse(ctx, expense.ID, models.ReimbursementInput{Reference: " PIX-123 "}, manager)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusReimbursed, expense.Status)
+	assert.Equal(t, "PIX-123", expense.ReimbursementReference)
+
+	_, err = s.Reimburse(ctx, expense.ID, models.ReimbursementInput{}, manager)
+	assert.ErrorIs(t, err, appErrors.ErrInvalidExpenseTransition)
+}
+
+func TestExpenseVisibility(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	expense, err := s.CreateExpense(ctx, expenseInput(), employee)
+	require.NoError(t, err)
+
+	_, err = s.GetExpense(ctx, expense.ID, models.Actor{Username: "bruno"})
+	assert.ErrorIs(t, err, appErrors.ErrExpenseNotFound)
+	_, err = s.GetExpense(ctx, expense.ID, manager)
+	assert.NoError(t, err)
+
+	list, err := s.ListExpenses(ctx, models.ExpenseFilter{Employee: "ana"}, models.Actor{Username: "bruno"})
+	require.NoError(t, err)
+	assert.Empty(t, list, "quem não é revisor só lista as próprias")
+}
+
+func TestCreateExpenseRejectsInactiveCategory(t *testing.T) {
+	s := newTestService(newFakeRepo())
+	input := expenseInput()
+	input.CategoryID = 2
+
+	_, err := s.CreateExpense(context.Background(), input, employee)
+	assert.ErrorIs(t, err, appErrors.ErrInvalidExpense)
+}
+
+func TestCategoryLedgerAccountMustBeExpense(t *testing.T) {
+	s := newTestService(newFakeRepo())
+	account := 99
+
+	_, err := s.CreateCategory(context.Background(), models.CategoryInput{Name: "Hospedagem", LedgerAccountID: &account})
+	assert.ErrorIs(t, err, appErrors.ErrInvalidAccountType)
+
+	account = 12
+	category, err := s.CreateCategory(context.Background(), models.CategoryInput{Name: " Hospedagem ", LedgerAccountID: &account})
+	require.NoError(t, err)
+	assert.Equal(t, "Hospedagem", category.Name)
+	assert.True(t, category.Active)
+}
+
+func TestMonthlyReportPeriod(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+
+	_, err := s.MonthlyReport(context.Background(), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), repo.from)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), repo.to, "até o fim do mês atual")
+
+	_, err = s.MonthlyReport(context.Background(), "2026-05", "2026-03")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidDateRange)
+
+	_, err = s.MonthlyReport(context.Background(), "2024-01", "2026-01")
+	assert.Error(t, err, "mais de 24 meses")
+}
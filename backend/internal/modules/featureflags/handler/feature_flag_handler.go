@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/featureflags/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateFlagInput é o corpo aceito em PUT /feature-flags/:name
+type UpdateFlagInput struct {
+	Enabled     *bool  `json:"enabled" binding:"required"`
+	Description string `json:"description"`
+}
+
+// Lista as feature flags com o valor efetivo e a origem (default, config ou database)
+// @Security BearerAuth
+func ListFeatureFlagsHandler(c *gin.Context) {
+	flags, err := service.ListFlags()
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar feature flags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+// Ativa ou desativa uma feature flag sem novo deploy; as demais instâncias aplicam o valor na próxima recarga
+// @Security BearerAuth
+func UpdateFeatureFlagHandler(c *gin.Context) {
+	var input UpdateFlagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	flag, err := service.SetFlag(c.Param("name"), *input.Enabled, input.Description, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar feature flag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flag": flag})
+}
+
+// Remove o valor gravado da feature flag, que volta ao padrão da configuração
+// @Security BearerAuth
+func ResetFeatureFlagHandler(c *gin.Context) {
+	flag, err := service.ResetFlag(c.Param("name"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao restaurar feature flag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flag": flag})
+}
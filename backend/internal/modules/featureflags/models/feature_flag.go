@@ -0,0 +1,59 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// Feature flags conhecidas pelo código. Flags sem registro no banco usam o padrão da configuração.
+const (
+	// FlagAPIDocs controla a publicação da especificação OpenAPI e do Swagger UI
+	FlagAPIDocs = "api_docs"
+	// FlagTaxEngine ativa o novo motor de cálculo de impostos nos documentos de venda
+	FlagTaxEngine = "tax_engine"
+)
+
+// Defaults são os valores usados quando a flag não está na configuração nem no banco
+var Defaults = map[string]bool{
+	FlagAPIDocs:   true,
+	FlagTaxEngine: false,
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9_]{1,100}$`)
+
+// FeatureFlag é o valor de uma flag gravado no banco, alterável sem novo deploy
+type FeatureFlag struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name"`
+	Enabled     bool      `json:"enabled"`
+	Description string    `json:"description"`
+	UpdatedBy   string    `json:"updated_by"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+// ValidName indica se o nome segue o padrão das flags (letras minúsculas, números e _)
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// FlagState é o valor efetivo de uma flag e a origem desse valor
+type FlagState struct {
+	Name        string     `json:"name"`
+	Enabled     bool       `json:"enabled"`
+	Source      string     `json:"source"` // default, config ou database
+	Description string     `json:"description,omitempty"`
+	UpdatedBy   string     `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// Origem do valor efetivo da flag
+const (
+	SourceDefault  = "default"
+	SourceConfig   = "config"
+	SourceDatabase = "database"
+)
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/featureflags/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeatureFlagRepository define as operações das feature flags gravadas no banco
+type FeatureFlagRepository interface {
+	List() ([]models.FeatureFlag, error)
+	Upsert(flag *models.FeatureFlag) error
+	Delete(name string) error
+}
+
+type featureFlagRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewFeatureFlagRepository cria uma nova instância do repositório
+func NewFeatureFlagRepository() (FeatureFlagRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &featureFlagRepository{
+		db:     db,
+		logger: logger.WithModule("feature_flag_repository"),
+	}, nil
+}
+
+// List retorna todas as flags gravadas, ordenadas pelo nome
+func (r *featureFlagRepository) List() ([]models.FeatureFlag, error) {
+	var flags []models.FeatureFlag
+	if err := r.db.Order("name ASC").Find(&flags).Error; err != nil {
+		r.logger.Error("erro ao listar feature flags", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar feature flags")
+	}
+	return flags, nil
+}
+
+// Upsert grava o valor da flag, criando o registro se ainda não existir
+func (r *featureFlagRepository) Upsert(flag *models.FeatureFlag) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "description", "updated_by", "updated_at"}),
+	}).Create(flag).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar feature flag", zap.Error(err), zap.String("name", flag.Name))
+		return errors.WrapError(err, "falha ao gravar feature flag")
+	}
+	return nil
+}
+
+// Delete remove o valor gravado; a flag volta ao padrão da configuração
+func (r *featureFlagRepository) Delete(name string) error {
+	if err := r.db.Where("name = ?", name).Delete(&models.FeatureFlag{}).Error; err != nil {
+		r.logger.Error("erro ao remover feature flag", zap.Error(err), zap.String("name", name))
+		return errors.WrapError(err, "falha ao remover feature flag")
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/featureflags/models"
+	"ERP-ONSMART/backend/internal/modules/featureflags/repository"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRefresh é o intervalo de recarga das flags do banco quando Configure não é chamado
+const DefaultRefresh = 30 * time.Second
+
+// Store mantém em memória o valor efetivo das flags. Os valores gravados no banco são
+// recarregados a cada intervalo, então uma alteração feita em outra instância passa a valer
+// sem novo deploy; sem registro no banco vale o padrão da configuração.
+type Store struct {
+	newRepo func() (repository.FeatureFlagRepository, error)
+	now     func() time.Time
+	logger  *zap.Logger
+
+	mu       sync.Mutex
+	refresh  time.Duration
+	config   map[string]bool
+	repo     repository.FeatureFlagRepository
+	stored   map[string]models.FeatureFlag
+	loadedAt time.Time
+}
+
+// NewStore cria o cache de flags sobre o repositório informado
+func NewStore(newRepo func() (repository.FeatureFlagRepository, error), refresh time.Duration) *Store {
+	return &Store{
+		newRepo: newRepo,
+		now:     time.Now,
+		logger:  logger.WithModule("feature_flags"),
+		refresh: refresh,
+		config:  map[string]bool{},
+	}
+}
+
+var defaultStore = NewStore(repository.NewFeatureFlagRepository, DefaultRefresh)
+
+// Configure define os padrões vindos da configuração (config.Config.Features) e o intervalo de recarga
+func Configure(features map[string]bool, refresh time.Duration) {
+	defaultStore.Configure(features, refresh)
+}
+
+// IsEnabled indica se a funcionalidade está ativa no ambiente
+func IsEnabled(name string) bool {
+	return defaultStore.IsEnabled(name)
+}
+
+// ListFlags retorna o valor efetivo de todas as flags conhecidas
+func ListFlags() ([]models.FlagState, error) {
+	return defaultStore.List()
+}
+
+// SetFlag grava o valor da flag no banco
+func SetFlag(name string, enabled bool, description, updatedBy string) (*models.FlagState, error) {
+	return defaultStore.Set(name, enabled, description, updatedBy)
+}
+
+// ResetFlag remove o valor gravado no banco, voltando ao padrão da configuração
+func ResetFlag(name string) (*models.FlagState, error) {
+	return defaultStore.Reset(name)
+}
+
+// Configure define os padrões da configuração e o intervalo de recarga
+func (s *Store) Configure(features map[string]bool, refresh time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = map[string]bool{}
+	for name, enabled := range features {
+		s.config[name] = enabled
+	}
+	if refresh > 0 {
+		s.refresh = refresh
+	}
+}
+
+// IsEnabled indica se a flag está ativa. Se o banco estiver indisponível, vale o último valor
+// carregado (ou o padrão da configuração).
+func (s *Store) IsEnabled(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stale() {
+		// A falha já foi registrada no log; segue com os últimos valores conhecidos
+		_ = s.load()
+	}
+	return s.state(name).Enabled
+}
+
+// List recarrega as flags do banco e retorna o valor efetivo de cada uma
+func (s *Store) List() ([]models.FlagState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for name := range models.Defaults {
+		names[name] = true
+	}
+	for name := range s.config {
+		names[name] = true
+	}
+	for name := range s.stored {
+		names[name] = true
+	}
+
+	states := make([]models.FlagState, 0, len(names))
+	for name := range names {
+		states = append(states, s.state(name))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states, nil
+}
+
+// Set grava o valor da flag e o aplica imediatamente nesta instância
+func (s *Store) Set(name string, enabled bool, description, updatedBy string) (*models.FlagState, error) {
+	if !models.ValidName(name) {
+		return nil, errors.ErrInvalidFeatureFlag
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	flag := &models.FeatureFlag{Name: name, Enabled: enabled, Description: description, UpdatedBy: updatedBy, UpdatedAt: s.now()}
+	if err := repo.Upsert(flag); err != nil {
+		return nil, err
+	}
+	if s.stored == nil {
+		s.stored = map[string]models.FeatureFlag{}
+	}
+	s.stored[name] = *flag
+	s.logger.Info("feature flag alterada", zap.String("name", name), zap.Bool("enabled", enabled), zap.String("updated_by", updatedBy))
+
+	state := s.state(name)
+	return &state, nil
+}
+
+// Reset remove o valor gravado da flag, que volta ao padrão da configuração
+func (s *Store) Reset(name string) (*models.FlagState, error) {
+	if !models.ValidName(name) {
+		return nil, errors.ErrInvalidFeatureFlag
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Delete(name); err != nil {
+		return nil, err
+	}
+	delete(s.stored, name)
+
+	state := s.state(name)
+	return &state, nil
+}
+
+func (s *Store) stale() bool {
+	return s.loadedAt.IsZero() || s.now().Sub(s.loadedAt) >= s.refresh
+}
+
+// load recarrega as flags gravadas; o horário da tentativa é registrado mesmo em caso de erro
+// para não repetir a consulta a cada requisição enquanto o banco estiver indisponível
+func (s *Store) load() error {
+	s.loadedAt = s.now()
+
+	repo, err := s.repository()
+	if err != nil {
+		s.logger.Warn("erro ao conectar para carregar feature flags", zap.Error(err))
+		return err
+	}
+	flags, err := repo.List()
+	if err != nil {
+		s.logger.Warn("erro ao carregar feature flags", zap.Error(err))
+		return err
+	}
+
+	s.stored = make(map[string]models.FeatureFlag, len(flags))
+	for _, flag := range flags {
+		s.stored[flag.Name] = flag
+	}
+	return nil
+}
+
+// repository abre a conexão uma única vez e a reaproveita nas recargas
+func (s *Store) repository() (repository.FeatureFlagRepository, error) {
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// state calcula o valor efetivo: banco, depois configuração, depois padrão do código
+func (s *Store) state(name string) models.FlagState {
+	if flag, ok := s.stored[name]; ok {
+		updatedAt := flag.UpdatedAt
+		return models.FlagState{
+			Name:        name,
+			Enabled:     flag.Enabled,
+			Source:      models.SourceDatabase,
+			Description: flag.Description,
+			UpdatedBy:   flag.UpdatedBy,
+			UpdatedAt:   &updatedAt,
+		}
+	}
+	if enabled, ok := s.config[name]; ok {
+		return models.FlagState{Name: name, Enabled: enabled, Source: models.SourceConfig}
+	}
+	return models.FlagState{Name: name, Enabled: models.Defaults[name], Source: models.SourceDefault}
+}
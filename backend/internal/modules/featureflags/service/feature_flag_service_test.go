@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/featureflags/models"
+	"ERP-ONSMART/backend/internal/modules/featureflags/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	flags map[string]models.FeatureFlag
+	lists int
+	err   error
+}
+
+func (r *fakeRepo) List() ([]models.FeatureFlag, error) {
+	r.lists++
+	if r.err != nil {
+		return nil, r.err
+	}
+	var flags []models.FeatureFlag
+	for _, flag := range r.flags {
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
+
+func (r *fakeRepo) Upsert(flag *models.FeatureFlag) error {
+	r.flags[flag.Name] = *flag
+	return nil
+}
+
+func (r *fakeRepo) Delete(name string) error {
+	delete(r.flags, name)
+	return nil
+}
+
+func newTestStore(repo *fakeRepo) (*Store, *time.Time) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	store := NewStore(func() (repository.FeatureFlagRepository, error) { return repo, nil }, time.Minute)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestStorePrecedence(t *testing.T) {
+	repo := &fakeRepo{flags: map[string]models.FeatureFlag{
+		"tax_engine": {Name: "tax_engine", Enabled: true},
+	}}
+	store, _ := newTestStore(repo)
+	store.Configure(map[string]bool{"tax_engine": false, "api_docs": false, "beta_reports": true}, 0)
+
+	assert.True(t, store.IsEnabled(models.FlagTaxEngine), "banco prevalece sobre a configuração")
+	assert.False(t, store.IsEnabled(models.FlagAPIDocs), "configuração prevalece sobre o padrão do código")
+	assert.True(t, store.IsEnabled("beta_reports"))
+	assert.False(t, store.IsEnabled("desconhecida"))
+
+	states, err := store.List()
+	require.NoError(t, err)
+	sources := map[string]string{}
+	for _, state := range states {
+		sources[state.Name] = state.Source
+	}
+	assert.Equal(t, map[string]string{
+		"api_docs":     models.SourceConfig,
+		"beta_reports": models.SourceConfig,
+		"tax_engine":   models.SourceDatabase,
+	}, sources)
+}
+
+func TestStoreReloadsAfterRefresh(t *testing.T) {
+	repo := &fakeRepo{flags: map[string]models.FeatureFlag{}}
+	store, now := newTestStore(repo)
+
+	assert.False(t, store.IsEnabled(models.FlagTaxEngine))
+	// Outra instância ativa a flag direto no banco
+	repo.flags["tax_engine"] = models.FeatureFlag{Name: "tax_engine", Enabled: true}
+
+	*now = now.Add(30 * time.Second)
+	assert.False(t, store.IsEnabled(models.FlagTaxEngine), "cache ainda válido")
+	assert.Equal(t, 1, repo.lists)
+
+	*now = now.Add(30 * time.Second)
+	assert.True(t, store.IsEnabled(models.FlagTaxEngine))
+	assert.Equal(t, 2, repo.lists)
+}
+
+func TestStoreKeepsLastValuesWhenDatabaseFails(t *testing.T) {
+	repo := &fakeRepo{flags: map[string]models.FeatureFlag{
+		"tax_engine": {Name: "tax_engine", Enabled: true},
+	}}
+	store, now := newTestStore(repo)
+	require.True(t, store.IsEnabled(models.FlagTaxEngine))
+
+	repo.err = errors.New("conexão recusada")
+	*now = now.Add(2 * time.Minute)
+	assert.True(t, store.IsEnabled(models.FlagTaxEngine))
+	// A falha não é repetida a cada consulta dentro do intervalo
+	assert.True(t, store.IsEnabled(models.FlagTaxEngine))
+	assert.Equal(t, 2, repo.lists)
+}
+
+func TestStoreSetAndReset(t *testing.T) {
+	repo := &fakeRepo{flags: map[string]models.FeatureFlag{}}
+	store, _ := newTestStore(repo)
+	store.Configure(map[string]bool{"tax_engine": false}, 0)
+
+	state, err := store.Set("tax_engine", true, "novo motor de impostos", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, models.SourceDatabase, state.Source)
+	assert.Equal(t, "admin", state.UpdatedBy)
+	assert.True(t, store.IsEnabled(models.FlagTaxEngine), "alteração vale imediatamente nesta instância")
+
+	state, err = store.Reset("tax_engine")
+	require.NoError(t, err)
+	assert.Equal(t, models.SourceConfig, state.Source)
+	assert.False(t, state.Enabled)
+
+	_, err = store.Set("Tax-Engine", true, "", "admin")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidFeatureFlag)
+}
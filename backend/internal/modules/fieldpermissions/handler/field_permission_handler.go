@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os campos restritos por perfil e os campos sensíveis conhecidos (custo, lucro e margem)
+// @Security BearerAuth
+func ListFieldPermissionsHandler(c *gin.Context) {
+	permissions, err := service.ListPermissions()
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar permissões de campos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permissions": permissions, "sensitive_fields": models.SensitiveFields})
+}
+
+// Restringe um campo das respostas para o perfil: hide remove o campo e mask devolve null
+// @Security BearerAuth
+// @Param role path string true "Perfil (finance_user, marketing_user, sales_user ou colaborador)"
+// @Param field path string true "Chave JSON do campo, como cost_price ou profit"
+func SetFieldPermissionHandler(c *gin.Context) {
+	var input models.SetFieldPermissionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	permission, err := service.SetPermission(c.Param("role"), c.Param("field"), input.Action, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar permissão de campo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permission": permission})
+}
+
+// Remove a restrição; o campo volta a ser exibido para o perfil
+// @Security BearerAuth
+// @Param role path string true "Perfil"
+// @Param field path string true "Chave JSON do campo"
+func RemoveFieldPermissionHandler(c *gin.Context) {
+	if err := service.RemovePermission(c.Param("role"), c.Param("field")); err != nil {
+		c.Error(err).SetMeta("erro ao remover permissão de campo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permissão de campo removida com sucesso"})
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"time"
+)
+
+// Ações aplicadas a um campo restrito na resposta JSON
+const (
+	// ActionHide remove o campo da resposta
+	ActionHide = "hide"
+	// ActionMask mantém o campo com valor null, para a interface exibir o dado como oculto
+	ActionMask = "mask"
+)
+
+// SensitiveFields são os campos de custo, lucro e margem devolvidos pelas rotas de processos de
+// venda, faturas e lucratividade. Outros campos podem ser restritos, mas estes aparecem na
+// listagem para facilitar a configuração.
+var SensitiveFields = map[string]string{
+	"cost":                     "Custo do item ou do documento",
+	"cost_price":               "Preço de custo do produto",
+	"unit_cost":                "Custo unitário (estoque e compras)",
+	"total_cost":               "Custo total",
+	"profit":                   "Lucro do processo de venda",
+	"total_profit":             "Lucro total",
+	"average_profit":           "Lucro médio",
+	"estimated_profit":         "Lucro estimado",
+	"profit_margin":            "Margem de lucro",
+	"profit_margin_percentage": "Margem de lucro (%)",
+	"margin_percentage":        "Margem (%)",
+}
+
+var fieldPattern = regexp.MustCompile(`^[a-z0-9_]{1,100}$`)
+
+// FieldPermission restringe um campo das respostas para um perfil de acesso
+type FieldPermission struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	Role      string    `json:"role"`
+	Field     string    `json:"field"`
+	Action    string    `json:"action"`
+	UpdatedBy string    `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (FieldPermission) TableName() string {
+	return "field_permissions"
+}
+
+// SetFieldPermissionInput é o corpo aceito em PUT /field-permissions/:role/:field
+type SetFieldPermissionInput struct {
+	Action string `json:"action" binding:"required,oneof=hide mask"`
+}
+
+// ValidField indica se o nome segue o padrão das chaves JSON da API (letras minúsculas, números e _)
+func ValidField(field string) bool {
+	return fieldPattern.MatchString(field)
+}
+
+// ValidAction indica se a ação é hide ou mask
+func ValidAction(action string) bool {
+	return action == ActionHide || action == ActionMask
+}
+
+// Policy relaciona os campos restritos de um perfil à ação aplicada (hide ou mask)
+type Policy map[string]string
+
+// Apply aplica a política ao corpo JSON da resposta, em qualquer nível de objetos e listas.
+// Números são preservados sem conversão para float.
+func (p Policy) Apply(body []byte) ([]byte, error) {
+	if len(p) == 0 {
+		return body, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(p.filter(value))
+}
+
+func (p Policy) filter(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			switch p[key] {
+			case ActionHide:
+				delete(v, key)
+			case ActionMask:
+				v[key] = nil
+			default:
+				v[key] = p.filter(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = p.filter(child)
+		}
+	}
+	return value
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyApplyHidesAndMasksNestedFields(t *testing.T) {
+	policy := Policy{"profit": ActionHide, "cost_price": ActionMask}
+	body := []byte(`{"data":[{"id":1,"total_value":150.5,"profit":40,"items":[{"cost_price":12.345,"name":"Cabo"}]}],"total_items":1}`)
+
+	out, err := policy.Apply(body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":[{"id":1,"total_value":150.5,"items":[{"cost_price":null,"name":"Cabo"}]}],"total_items":1}`, string(out))
+}
+
+func TestPolicyApplyKeepsNumbersAndEmptyPolicy(t *testing.T) {
+	body := []byte(`{"id":12345678901234567,"profit":1}`)
+
+	out, err := Policy{}.Apply(body)
+	require.NoError(t, err)
+	assert.Equal(t, string(body), string(out))
+
+	out, err = Policy{"profit": ActionHide}.Apply(body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":12345678901234567}`, string(out))
+}
+
+func TestPolicyApplyInvalidJSON(t *testing.T) {
+	_, err := Policy{"profit": ActionHide}.Apply([]byte("não é json"))
+	assert.Error(t, err)
+}
+
+func TestValidField(t *testing.T) {
+	assert.True(t, ValidField("profit_margin_percentage"))
+	assert.False(t, ValidField("Profit"))
+	assert.False(t, ValidField("items.cost"))
+	assert.False(t, ValidField(""))
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FieldPermissionRepository define as operações das restrições de campos gravadas no banco
+type FieldPermissionRepository interface {
+	List() ([]models.FieldPermission, error)
+	Upsert(permission *models.FieldPermission) error
+	Delete(role, field string) error
+}
+
+type fieldPermissionRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewFieldPermissionRepository cria uma nova instância do repositório
+func NewFieldPermissionRepository() (FieldPermissionRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &fieldPermissionRepository{
+		db:     db,
+		logger: logger.WithModule("field_permission_repository"),
+	}, nil
+}
+
+// List retorna todas as restrições, ordenadas por perfil e campo
+func (r *fieldPermissionRepository) List() ([]models.FieldPermission, error) {
+	var permissions []models.FieldPermission
+	if err := r.db.Order("role ASC, field ASC").Find(&permissions).Error; err != nil {
+		r.logger.Error("erro ao listar permissões de campos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar permissões de campos")
+	}
+	return permissions, nil
+}
+
+// Upsert grava a ação do campo para o perfil, criando o registro se ainda não existir
+func (r *fieldPermissionRepository) Upsert(permission *models.FieldPermission) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}, {Name: "field"}},
+		DoUpdates: clause.AssignmentColumns([]string{"action", "updated_by", "updated_at"}),
+	}).Create(permission).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar permissão de campo", zap.Error(err),
+			zap.String("role", permission.Role), zap.String("field", permission.Field))
+		return errors.WrapError(err, "falha ao gravar permissão de campo")
+	}
+	return nil
+}
+
+// Delete remove a restrição; o campo volta a ser exibido para o perfil
+func (r *fieldPermissionRepository) Delete(role, field string) error {
+	if err := r.db.Where("role = ? AND field = ?", role, field).Delete(&models.FieldPermission{}).Error; err != nil {
+		r.logger.Error("erro ao remover permissão de campo", zap.Error(err), zap.String("role", role), zap.String("field", field))
+		return errors.WrapError(err, "falha ao remover permissão de campo")
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/repository"
+	users "ERP-ONSMART/backend/internal/modules/users/models"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRefresh é o intervalo de recarga das restrições gravadas no banco
+const DefaultRefresh = 30 * time.Second
+
+// Store mantém em memória as restrições de campos por perfil. As restrições são recarregadas a
+// cada intervalo, então uma alteração feita em outra instância passa a valer sem novo deploy.
+type Store struct {
+	newRepo func() (repository.FieldPermissionRepository, error)
+	now     func() time.Time
+	logger  *zap.Logger
+
+	mu       sync.Mutex
+	refresh  time.Duration
+	repo     repository.FieldPermissionRepository
+	stored   []models.FieldPermission
+	policies map[string]models.Policy
+	loadedAt time.Time
+}
+
+// NewStore cria o cache de restrições sobre o repositório informado
+func NewStore(newRepo func() (repository.FieldPermissionRepository, error), refresh time.Duration) *Store {
+	return &Store{
+		newRepo: newRepo,
+		now:     time.Now,
+		logger:  logger.WithModule("field_permissions"),
+		refresh: refresh,
+	}
+}
+
+var defaultStore = NewStore(repository.NewFieldPermissionRepository, DefaultRefresh)
+
+// PolicyFor retorna os campos restritos do perfil; vazio para administradores e perfis sem restrição
+func PolicyFor(role string) models.Policy {
+	return defaultStore.PolicyFor(role)
+}
+
+// ListPermissions retorna todas as restrições gravadas
+func ListPermissions() ([]models.FieldPermission, error) {
+	return defaultStore.List()
+}
+
+// SetPermission restringe o campo para o perfil
+func SetPermission(role, field, action, updatedBy string) (*models.FieldPermission, error) {
+	return defaultStore.Set(role, field, action, updatedBy)
+}
+
+// RemovePermission volta a exibir o campo para o perfil
+func RemovePermission(role, field string) error {
+	return defaultStore.Remove(role, field)
+}
+
+// PolicyFor retorna a política do perfil. Se o banco estiver indisponível, vale a última carga.
+func (s *Store) PolicyFor(role string) models.Policy {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" || role == users.RoleAdmin {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stale() {
+		// A falha já foi registrada no log; segue com as últimas restrições conhecidas
+		_ = s.load()
+	}
+	return s.policies[role]
+}
+
+// List recarrega e retorna as restrições gravadas
+func (s *Store) List() ([]models.FieldPermission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return append([]models.FieldPermission{}, s.stored...), nil
+}
+
+// Set grava a restrição e a aplica imediatamente nesta instância. Administradores não podem ser
+// restringidos.
+func (s *Store) Set(role, field, action, updatedBy string) (*models.FieldPermission, error) {
+	role, err := validate(role, field)
+	if err != nil {
+		return nil, err
+	}
+	if !models.ValidAction(action) {
+		return nil, errors.ErrInvalidFieldPermission
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	permission := &models.FieldPermission{Role: role, Field: field, Action: action, UpdatedBy: updatedBy, UpdatedAt: s.now()}
+	if err := repo.Upsert(permission); err != nil {
+		return nil, err
+	}
+	s.replace(role, field, permission)
+	s.logger.Info("permissão de campo alterada", zap.String("role", role), zap.String("field", field),
+		zap.String("action", action), zap.String("updated_by", updatedBy))
+	return permission, nil
+}
+
+// Remove apaga a restrição e a retira imediatamente nesta instância
+func (s *Store) Remove(role, field string) error {
+	role, err := validate(role, field)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	if err := repo.Delete(role, field); err != nil {
+		return err
+	}
+	s.replace(role, field, nil)
+	return nil
+}
+
+func validate(role, field string) (string, error) {
+	role, ok := users.NormalizeRole(role)
+	if !ok || role == users.RoleAdmin || !models.ValidField(field) {
+		return "", errors.ErrInvalidFieldPermission
+	}
+	return role, nil
+}
+
+func (s *Store) stale() bool {
+	return s.loadedAt.IsZero() || s.now().Sub(s.loadedAt) >= s.refresh
+}
+
+// load recarrega as restrições; o horário da tentativa é registrado mesmo em caso de erro para
+// não repetir a consulta a cada requisição enquanto o banco estiver indisponível
+func (s *Store) load() error {
+	s.loadedAt = s.now()
+
+	repo, err := s.repository()
+	if err != nil {
+		s.logger.Warn("erro ao conectar para carregar permissões de campos", zap.Error(err))
+		return err
+	}
+	permissions, err := repo.List()
+	if err != nil {
+		s.logger.Warn("erro ao carregar permissões de campos", zap.Error(err))
+		return err
+	}
+	s.stored = permissions
+	s.rebuild()
+	return nil
+}
+
+// replace troca (ou remove, com nil) a restrição do campo no cache
+func (s *Store) replace(role, field string, permission *models.FieldPermission) {
+	kept := s.stored[:0:0]
+	for _, stored := range s.stored {
+		if stored.Role != role || stored.Field != field {
+			kept = append(kept, stored)
+		}
+	}
+	if permission != nil {
+		kept = append(kept, *permission)
+	}
+	s.stored = kept
+	s.rebuild()
+}
+
+func (s *Store) rebuild() {
+	s.policies = map[string]models.Policy{}
+	for _, permission := range s.stored {
+		if s.policies[permission.Role] == nil {
+			s.policies[permission.Role] = models.Policy{}
+		}
+		s.policies[permission.Role][permission.Field] = permission.Action
+	}
+}
+
+// repository abre a conexão uma única vez e a reaproveita nas recargas
+func (s *Store) repository() (repository.FieldPermissionRepository, error) {
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+	"ERP-ONSMART/backend/internal/modules/fieldpermissions/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	permissions []models.FieldPermission
+	lists       int
+	err         error
+}
+
+func (r *fakeRepo) List() ([]models.FieldPermission, error) {
+	r.lists++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return append([]models.FieldPermission{}, r.permissions...), nil
+}
+
+func (r *fakeRepo) Upsert(permission *models.FieldPermission) error {
+	r.Delete(permission.Role, permission.Field)
+	r.permissions = append(r.permissions, *permission)
+	return nil
+}
+
+func (r *fakeRepo) Delete(role, field string) error {
+	kept := r.permissions[:0]
+	for _, permission := range r.permissions {
+		if permission.Role != role || permission.Field != field {
+			kept = append(kept, permission)
+		}
+	}
+	r.permissions = kept
+	return nil
+}
+
+func newTestStore(repo *fakeRepo) (*Store, *time.Time) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	store := NewStore(func() (repository.FieldPermissionRepository, error) { return repo, nil }, time.Minute)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestStorePolicyFor(t *testing.T) {
+	repo := &fakeRepo{permissions: []models.FieldPermission{
+		{Role: "sales_user", Field: "profit", Action: models.ActionHide},
+		{Role: "sales_user", Field: "cost_price", Action: models.ActionMask},
+		{Role: "admin", Field: "profit", Action: models.ActionHide},
+	}}
+	store, _ := newTestStore(repo)
+
+	assert.Equal(t, models.Policy{"profit": models.ActionHide, "cost_price": models.ActionMask}, store.PolicyFor("Sales_User"))
+	assert.Empty(t, store.PolicyFor("admin"), "administradores nunca são restringidos")
+	assert.Empty(t, store.PolicyFor("finance_user"))
+	assert.Empty(t, store.PolicyFor(""))
+}
+
+func TestStoreReloadsAfterRefresh(t *testing.T) {
+	repo := &fakeRepo{}
+	store, now := newTestStore(repo)
+
+	assert.Empty(t, store.PolicyFor("sales_user"))
+	repo.permissions = []models.FieldPermission{{Role: "sales_user", Field: "profit", Action: models.ActionHide}}
+	assert.Empty(t, store.PolicyFor("sales_user"), "usa o cache dentro do intervalo")
+
+	*now = now.Add(time.Minute)
+	assert.Equal(t, models.Policy{"profit": models.ActionHide}, store.PolicyFor("sales_user"))
+	assert.Equal(t, 2, repo.lists)
+}
+
+func TestStoreKeepsLastPoliciesWhenDatabaseFails(t *testing.T) {
+	repo := &fakeRepo{permissions: []models.FieldPermission{{Role: "sales_user", Field: "profit", Action: models.ActionHide}}}
+	store, now := newTestStore(repo)
+	require.NotEmpty(t, store.PolicyFor("sales_user"))
+
+	repo.err = errors.New("banco indisponível")
+	*now = now.Add(time.Minute)
+	assert.Equal(t, models.Policy{"profit": models.ActionHide}, store.PolicyFor("sales_user"))
+}
+
+func TestStoreSetAndRemove(t *testing.T) {
+	repo := &fakeRepo{}
+	store, _ := newTestStore(repo)
+
+	permission, err := store.Set("SALES_USER", "margin_percentage", models.ActionMask, "maria")
+	require.NoError(t, err)
+	assert.Equal(t, "sales_user", permission.Role)
+	assert.Equal(t, "maria", permission.UpdatedBy)
+	assert.Equal(t, models.Policy{"margin_percentage": models.ActionMask}, store.PolicyFor("sales_user"))
+
+	_, err = store.Set("sales_user", "margin_percentage", models.ActionHide, "maria")
+	require.NoError(t, err)
+	assert.Equal(t, models.Policy{"margin_percentage": models.ActionHide}, store.PolicyFor("sales_user"))
+	assert.Len(t, repo.permissions, 1)
+
+	require.NoError(t, store.Remove("sales_user", "margin_percentage"))
+	assert.Empty(t, store.PolicyFor("sales_user"))
+	assert.Empty(t, repo.permissions)
+}
+
+func TestStoreSetRejectsInvalidInput(t *testing.T) {
+	store, _ := newTestStore(&fakeRepo{})
+
+	for _, tc := range []struct{ role, field, action string }{
+		{"admin", "profit", models.ActionHide},
+		{"gerente", "profit", models.ActionHide},
+		{"sales_user", "items.cost", models.ActionHide},
+		{"sales_user", "profit", "blur"},
+	} {
+		_, err := store.Set(tc.role, tc.field, tc.action, "maria")
+		assert.ErrorIs(t, err, appErrors.ErrInvalidFieldPermission, "%+v", tc)
+	}
+}
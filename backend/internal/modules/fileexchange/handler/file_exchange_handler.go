@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/fileexchange/models"
+	"ERP-ONSMART/backend/internal/modules/fileexchange/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os servidores SFTP/FTP de troca de arquivos da empresa
+// @Security BearerAuth
+func ListEndpointsHandler(c *gin.Context) {
+	endpoints, err := service.ListEndpoints(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar servidores de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"endpoints": endpoints})
+}
+
+// Cadastra um servidor SFTP ou FTP; senha e chave privada são cifradas pelo cofre (VAULT_KEY)
+// e a chave pública do servidor (host_key) é obrigatória no SFTP
+// @Security BearerAuth
+func CreateEndpointHandler(c *gin.Context) {
+	var input models.EndpointInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	endpoint, err := service.CreateEndpoint(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar servidor de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"endpoint": endpoint})
+}
+
+// Altera o servidor de troca de arquivos; senha e chave privada em branco mantêm as atuais
+// @Security BearerAuth
+// @Param id path int true "ID do servidor"
+func UpdateEndpointHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.EndpointInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	endpoint, err := service.UpdateEndpoint(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar servidor de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"endpoint": endpoint})
+}
+
+// Lista as rotinas de envio e coleta de arquivos, com o resultado da última execução
+// @Security BearerAuth
+func ListSchedulesHandler(c *gin.Context) {
+	schedules, err := service.ListSchedules(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar rotinas de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+// Cadastra uma rotina periódica: envio de faturas em UBL (edi_invoices) ou da lista de preços
+// (price_list), ou coleta de pedidos de venda (sales_orders) ou de retornos CNAB (cnab_return)
+// @Security BearerAuth
+func CreateScheduleHandler(c *gin.Context) {
+	var input models.ScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	schedule, err := service.CreateSchedule(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar rotina de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"schedule": schedule})
+}
+
+// Altera a rotina de troca de arquivos
+// @Security BearerAuth
+// @Param id path int true "ID da rotina"
+func UpdateScheduleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	schedule, err := service.UpdateSchedule(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar rotina de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedule": schedule})
+}
+
+// Coloca a rotina na fila para execução imediata, fora do intervalo; recusada se já houver
+// execução pendente
+// @Security BearerAuth
+// @Param id path int true "ID da rotina"
+func RunScheduleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	run, err := service.RunNow(c.Request.Context(), id, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao executar rotina de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"run": run})
+}
+
+// Lista as execuções mais recentes da rotina, com tentativas, log e erro
+// @Security BearerAuth
+// @Param id path int true "ID da rotina"
+// @Param limit query int false "quantidade (padrão 20, máx. 100)"
+func ListRunsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var limit int
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.Error(errors.InvalidParam("limit deve ser um número inteiro positivo"))
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := service.ListRuns(c.Request.Context(), id, limit)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar execuções de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// Detalha uma execução de troca de arquivos
+// @Security BearerAuth
+// @Param id path int true "ID da execução"
+func GetRunHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	run, err := service.GetRun(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar execução de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+// Devolve à fila uma execução que esgotou as tentativas, com novas tentativas
+// @Security BearerAuth
+// @Param id path int true "ID da execução"
+func RetryRunHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	run, err := service.RetryRun(c.Request.Context(), id, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao reenfileirar execução de troca de arquivos")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"run": run})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
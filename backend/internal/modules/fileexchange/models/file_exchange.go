@@ -0,0 +1,200 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// Protocolos dos servidores de troca de arquivos
+const (
+	ProtocolSFTP = "sftp"
+	ProtocolFTP  = "ftp"
+)
+
+// Tipos de rotina. As de envio (push) gravam no diretório de saída do servidor; as de coleta
+// (pull) leem o diretório de entrada e movem cada arquivo processado para o de arquivamento.
+const (
+	// UBL 2.1 das faturas emitidas desde a última execução concluída
+	KindEDIInvoices = "edi_invoices"
+	// CSV com o preço de venda dos produtos ativos
+	KindPriceList = "price_list"
+	// Pedidos de venda importados pelo mapeamento ETL da rotina
+	KindSalesOrders = "sales_orders"
+	// Retornos de cobrança CNAB 240, importados como extrato da conta da rotina
+	KindCNABReturn = "cnab_return"
+)
+
+// Sentidos das rotinas
+const (
+	DirectionPush = "push"
+	DirectionPull = "pull"
+)
+
+// Origens das execuções
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Status das execuções. Uma execução com falha volta para a fila (queued) com espera crescente
+// até esgotar as tentativas; só então fica failed.
+const (
+	RunQueued    = "queued"
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// DefaultMaxAttempts é a quantidade de tentativas das rotinas que não informam outra
+const DefaultMaxAttempts = 3
+
+// KindDirection retorna o sentido do tipo de rotina; vazio para tipos desconhecidos
+func KindDirection(kind string) string {
+	switch kind {
+	case KindEDIInvoices, KindPriceList:
+		return DirectionPush
+	case KindSalesOrders, KindCNABReturn:
+		return DirectionPull
+	}
+	return ""
+}
+
+// Endpoint é um servidor SFTP ou FTP de um parceiro. A senha e a chave privada ficam cifradas
+// pelo cofre (pacote vault) e nunca voltam na API: HasPassword e HasPrivateKey só indicam se
+// foram informadas.
+type Endpoint struct {
+	ID               int       `json:"id" gorm:"primaryKey"`
+	CompanyID        int       `json:"company_id" gorm:"<-:create"`
+	Name             string    `json:"name"`
+	Protocol         string    `json:"protocol"`
+	Host             string    `json:"host"`
+	Port             int       `json:"port"`
+	Username         string    `json:"username"`
+	PasswordSealed   string    `json:"-"`
+	PrivateKeySealed string    `json:"-"`
+	HostKey          string    `json:"host_key,omitempty"`
+	InboundDir       string    `json:"inbound_dir"`
+	OutboundDir      string    `json:"outbound_dir"`
+	ArchiveDir       string    `json:"archive_dir"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	HasPassword   bool `json:"has_password" gorm:"-"`
+	HasPrivateKey bool `json:"has_private_key" gorm:"-"`
+}
+
+func (Endpoint) TableName() string {
+	return "file_exchange_endpoints"
+}
+
+// FillCredentialFlags preenche HasPassword e HasPrivateKey a partir das credenciais cifradas
+func (e *Endpoint) FillCredentialFlags() {
+	e.HasPassword = e.PasswordSealed != ""
+	e.HasPrivateKey = e.PrivateKeySealed != ""
+}
+
+// EndpointInput são os dados de cadastro do servidor. Senha e chave privada em branco mantêm as
+// gravadas; a chave do servidor (host_key) é obrigatória no SFTP.
+type EndpointInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Protocol    string `json:"protocol" binding:"required,oneof=sftp ftp"`
+	Host        string `json:"host" binding:"required,hostname_rfc1123,max=255"`
+	Port        int    `json:"port" binding:"omitempty,min=1,max=65535"`
+	Username    string `json:"username" binding:"required,max=100"`
+	Password    string `json:"password"`
+	PrivateKey  string `json:"private_key"`
+	HostKey     string `json:"host_key"`
+	InboundDir  string `json:"inbound_dir" binding:"max=255"`
+	OutboundDir string `json:"outbound_dir" binding:"max=255"`
+	ArchiveDir  string `json:"archive_dir" binding:"max=255"`
+	Active      *bool  `json:"active"`
+}
+
+// Schedule é uma rotina periódica de envio ou coleta de arquivos num servidor. Os parâmetros
+// usados dependem do tipo: ContactID (opcional) em edi_invoices, MappingID em sales_orders e
+// BankAccount em cnab_return; FilePattern filtra os arquivos coletados (ex.: *.ret).
+type Schedule struct {
+	ID              int        `json:"id" gorm:"primaryKey"`
+	CompanyID       int        `json:"company_id" gorm:"<-:create"`
+	EndpointID      int        `json:"endpoint_id"`
+	Name            string     `json:"name"`
+	Kind            string     `json:"kind"`
+	FilePattern     string     `json:"file_pattern,omitempty"`
+	ContactID       *int       `json:"contact_id,omitempty"`
+	MappingID       *int       `json:"mapping_id,omitempty"`
+	BankAccount     string     `json:"bank_account,omitempty"`
+	IntervalMinutes int        `json:"interval_minutes"`
+	MaxAttempts     int        `json:"max_attempts"`
+	Active          bool       `json:"active"`
+	NextRunAt       time.Time  `json:"next_run_at"`
+	LastRunAt       *time.Time `json:"last_run_at,omitempty"`
+	LastSuccessAt   *time.Time `json:"last_success_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	CreatedBy       string     `json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Endpoint *Endpoint `json:"endpoint,omitempty" gorm:"foreignKey:EndpointID"`
+}
+
+func (Schedule) TableName() string {
+	return "file_exchange_schedules"
+}
+
+// Matches indica se o arquivo coletado atende ao filtro da rotina (sem filtro, todos atendem)
+func (s Schedule) Matches(fileName string) bool {
+	if s.FilePattern == "" {
+		return true
+	}
+	matched, _ := path.Match(strings.ToLower(s.FilePattern), strings.ToLower(fileName))
+	return matched
+}
+
+// ScheduleInput são os dados de cadastro da rotina
+type ScheduleInput struct {
+	EndpointID      int    `json:"endpoint_id" binding:"required,gt=0"`
+	Name            string `json:"name" binding:"required,max=100"`
+	Kind            string `json:"kind" binding:"required,oneof=edi_invoices price_list sales_orders cnab_return"`
+	FilePattern     string `json:"file_pattern" binding:"max=100"`
+	ContactID       *int   `json:"contact_id" binding:"omitempty,gt=0"`
+	MappingID       *int   `json:"mapping_id" binding:"omitempty,gt=0"`
+	BankAccount     string `json:"bank_account" binding:"max=50"`
+	IntervalMinutes int    `json:"interval_minutes" binding:"required,min=5,max=10080"`
+	MaxAttempts     int    `json:"max_attempts" binding:"omitempty,min=1,max=10"`
+	Active          *bool  `json:"active"`
+}
+
+// Run é uma execução de rotina: a fila da troca de arquivos. Since e Until delimitam os
+// documentos enviados (edi_invoices) e se mantêm nas novas tentativas; Log guarda uma linha
+// por arquivo enviado ou coletado.
+type Run struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	CompanyID     int        `json:"company_id" gorm:"<-:create"`
+	ScheduleID    int        `json:"schedule_id"`
+	Trigger       string     `json:"trigger"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	Since         *time.Time `json:"since,omitempty"`
+	Until         time.Time  `json:"until"`
+	FileCount     int        `json:"file_count"`
+	Log           string     `json:"log"`
+	Error         string     `json:"error,omitempty"`
+	RequestedBy   string     `json:"requested_by,omitempty"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Run) TableName() string {
+	return "file_exchange_runs"
+}
+
+// AppendLog acrescenta uma linha ao log da execução, com o horário
+func (r *Run) AppendLog(at time.Time, line string) {
+	r.Log += at.UTC().Format(time.RFC3339) + " " + line + "\n"
+}
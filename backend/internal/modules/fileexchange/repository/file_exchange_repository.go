@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	etl "ERP-ONSMART/backend/internal/modules/etl/models"
+	"ERP-ONSMART/backend/internal/modules/fileexchange/models"
+	products "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FileExchangeRepository define as operações dos servidores, das rotinas e da fila de execuções
+// da troca de arquivos, e a leitura dos dados enviados
+type FileExchangeRepository interface {
+	ListEndpoints(ctx context.Context) ([]models.Endpoint, error)
+	GetEndpoint(ctx context.Context, id int) (*models.Endpoint, error)
+	CreateEndpoint(ctx context.Context, endpoint *models.Endpoint) error
+	UpdateEndpoint(ctx context.Context, endpoint *models.Endpoint) error
+
+	ListSchedules(ctx context.Context) ([]models.Schedule, error)
+	GetSchedule(ctx context.Context, id int) (*models.Schedule, error)
+	CreateSchedule(ctx context.Context, schedule *models.Schedule) error
+	UpdateSchedule(ctx context.Context, schedule *models.Schedule) error
+	DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error)
+	SetNextRun(ctx context.Context, scheduleID int, nextRunAt time.Time) error
+	MarkScheduleRun(ctx context.Context, scheduleID int, ranAt time.Time, successAt *time.Time, runErr string) error
+
+	CreateRun(ctx context.Context, run *models.Run) error
+	GetRun(ctx context.Context, id int) (*models.Run, error)
+	ListRuns(ctx context.Context, scheduleID, limit int) ([]models.Run, error)
+	HasPendingRun(ctx context.Context, scheduleID int) (bool, error)
+	ClaimRun(ctx context.Context, now, staleBefore time.Time) (*models.Run, error)
+	SaveRun(ctx context.Context, run *models.Run) error
+
+	MappingTarget(ctx context.Context, mappingID int) (string, error)
+	InvoicesChanged(ctx context.Context, since *time.Time, until time.Time, contactID *int) ([]int, error)
+	PriceListProducts(ctx context.Context) ([]products.Product, error)
+}
+
+type fileExchangeRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewFileExchangeRepository cria uma nova instância do repositório
+func NewFileExchangeRepository() (FileExchangeRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &fileExchangeRepository{
+		db:     db,
+		logger: logger.WithModule("file_exchange_repository"),
+	}, nil
+}
+
+// ListEndpoints retorna os servidores da empresa
+func (r *fileExchangeRepository) ListEndpoints(ctx context.Context) ([]models.Endpoint, error) {
+	var endpoints []models.Endpoint
+	if err := db.Conn(ctx, r.db).Order("id ASC").Find(&endpoints).Error; err != nil {
+		r.logger.Error("erro ao listar servidores de troca de arquivos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar servidores de troca de arquivos")
+	}
+	return endpoints, nil
+}
+
+// GetEndpoint busca um servidor pelo ID
+func (r *fileExchangeRepository) GetEndpoint(ctx context.Context, id int) (*models.Endpoint, error) {
+	var endpoint models.Endpoint
+	if err := db.Conn(ctx, r.db).First(&endpoint, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrFileEndpointNotFound
+		}
+		r.logger.Error("erro ao buscar servidor de troca de arquivos", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar servidor de troca de arquivos")
+	}
+	return &endpoint, nil
+}
+
+// CreateEndpoint grava um novo servidor
+func (r *fileExchangeRepository) CreateEndpoint(ctx context.Context, endpoint *models.Endpoint) error {
+	if err := db.Conn(ctx, r.db).Create(endpoint).Error; err != nil {
+		r.logger.Error("erro ao criar servidor de troca de arquivos", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar servidor de troca de arquivos")
+	}
+	return nil
+}
+
+// UpdateEndpoint grava os dados do servidor, inclusive as credenciais cifradas
+func (r *fileExchangeRepository) UpdateEndpoint(ctx context.Context, endpoint *models.Endpoint) error {
+	if err := db.Conn(ctx, r.db).Model(endpoint).
+		Select("name", "protocol", "host", "port", "username", "password_sealed", "private_key_sealed",
+			"host_key", "inbound_dir", "outbound_dir", "archive_dir", "active").
+		Updates(endpoint).Error; err != nil {
+		r.logger.Error("erro ao atualizar servidor de troca de arquivos", zap.Error(err), zap.Int("id", endpoint.ID))
+		return errors.WrapError(err, "falha ao atualizar servidor de troca de arquivos")
+	}
+	return nil
+}
+
+// ListSchedules retorna as rotinas da empresa
+func (r *fileExchangeRepository) ListSchedules(ctx context.Context) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	if err := db.Conn(ctx, r.db).Order("id ASC").Find(&schedules).Error; err != nil {
+		r.logger.Error("erro ao listar rotinas de troca de arquivos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar rotinas de troca de arquivos")
+	}
+	return schedules, nil
+}
+
+// GetSchedule busca uma rotina pelo ID, com o servidor
+func (r *fileExchangeRepository) GetSchedule(ctx context.Context, id int) (*models.Schedule, error) {
+	var schedule models.Schedule
+	if err := db.Conn(ctx, r.db).Preload("Endpoint").First(&schedule, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrFileScheduleNotFound
+		}
+		r.logger.Error("erro ao buscar rotina de troca de arquivos", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar rotina de troca de arquivos")
+	}
+	return &schedule, nil
+}
+
+// CreateSchedule grava uma nova rotina
+func (r *fileExchangeRepository) CreateSchedule(ctx context.Context, schedule *models.Schedule) error {
+	if err := db.Conn(ctx, r.db).Omit("Endpoint").Create(schedule).Error; err != nil {
+		r.logger.Error("erro ao criar rotina de troca de arquivos", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar rotina de troca de arquivos")
+	}
+	return nil
+}
+
+// UpdateSchedule grava os dados cadastrais da rotina e o próximo horário
+func (r *fileExchangeRepository) UpdateSchedule(ctx context.Context, schedule *models.Schedule) error {
+	if err := db.Conn(ctx, r.db).Model(schedule).
+		Select("endpoint_id", "name", "kind", "file_pattern", "contact_id", "mapping_id", "bank_account",
+			"interval_minutes", "max_attempts", "active", "next_run_at").
+		Updates(schedule).Error; err != nil {
+		r.logger.Error("erro ao atualizar rotina de troca de arquivos", zap.Error(err), zap.Int("id", schedule.ID))
+		return errors.WrapError(err, "falha ao atualizar rotina de troca de arquivos")
+	}
+	return nil
+}
+
+// DueSchedules retorna as rotinas ativas com execução vencida. O contexto deve vir de
+// tenant.AllCompanies: a rotina atende todas as empresas.
+func (r *fileExchangeRepository) DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	err := db.Conn(ctx, r.db).
+		Where("active = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").
+		Find(&schedules).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar rotinas de troca de arquivos vencidas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar rotinas vencidas")
+	}
+	return schedules, nil
+}
+
+// SetNextRun grava o próximo horário da rotina
+func (r *fileExchangeRepository) SetNextRun(ctx context.Context, scheduleID int, nextRunAt time.Time) error {
+	err := db.Conn(ctx, r.db).Model(&models.Schedule{}).Where("id = ?", scheduleID).
+		Update("next_run_at", nextRunAt).Error
+	if err != nil {
+		r.logger.Error("erro ao agendar rotina de troca de arquivos", zap.Error(err), zap.Int("id", scheduleID))
+		return errors.WrapError(err, "falha ao agendar rotina de troca de arquivos")
+	}
+	return nil
+}
+
+// MarkScheduleRun registra o resultado da última execução; successAt só é gravado quando a
+// execução foi concluída
+func (r *fileExchangeRepository) MarkScheduleRun(ctx context.Context, scheduleID int, ranAt time.Time, successAt *time.Time, runErr string) error {
+	updates := map[string]interface{}{"last_run_at": ranAt, "last_error": runErr}
+	if successAt != nil {
+		updates["last_success_at"] = *successAt
+	}
+	err := db.Conn(ctx, r.db).Model(&models.Schedule{}).Where("id = ?", scheduleID).Updates(updates).Error
+	if err != nil {
+		r.logger.Error("erro ao registrar execução da rotina", zap.Error(err), zap.Int("id", scheduleID))
+		return errors.WrapError(err, "falha ao registrar execução da rotina")
+	}
+	return nil
+}
+
+// CreateRun coloca a execução na fila
+func (r *fileExchangeRepository) CreateRun(ctx context.Context, run *models.Run) error {
+	if err := db.Conn(ctx, r.db).Create(run).Error; err != nil {
+		r.logger.Error("erro ao criar execução da troca de arquivos", zap.Error(err), zap.Int("schedule_id", run.ScheduleID))
+		return errors.WrapError(err, "falha ao criar execução da troca de arquivos")
+	}
+	return nil
+}
+
+// GetRun busca uma execução pelo ID
+func (r *fileExchangeRepository) GetRun(ctx context.Context, id int) (*models.Run, error) {
+	var run models.Run
+	if err := db.Conn(ctx, r.db).First(&run, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrFileExchangeRunNotFound
+		}
+		r.logger.Error("erro ao buscar execução da troca de arquivos", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar execução da troca de arquivos")
+	}
+	return &run, nil
+}
+
+// ListRuns retorna as execuções mais recentes da rotina
+func (r *fileExchangeRepository) ListRuns(ctx context.Context, scheduleID, limit int) ([]models.Run, error) {
+	var runs []models.Run
+	if err := db.Conn(ctx, r.db).Where("schedule_id = ?", scheduleID).
+		Order("id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		r.logger.Error("erro ao listar execuções da troca de arquivos", zap.Error(err), zap.Int("schedule_id", scheduleID))
+		return nil, errors.WrapError(err, "falha ao listar execuções da troca de arquivos")
+	}
+	return runs, nil
+}
+
+// HasPendingRun indica se a rotina já tem execução na fila ou em andamento
+func (r *fileExchangeRepository) HasPendingRun(ctx context.Context, scheduleID int) (bool, error) {
+	var count int64
+	err := db.Conn(ctx, r.db).Model(&models.Run{}).
+		Where("schedule_id = ? AND status IN ?", scheduleID, []string{models.RunQueued, models.RunRunning}).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.WrapError(err, "falha ao consultar a fila da troca de arquivos")
+	}
+	return count > 0, nil
+}
+
+// ClaimRun retira da fila, de qualquer empresa, a execução mais antiga com a tentativa vencida e
+// a marca em andamento, somando a tentativa. Execuções em andamento desde antes de staleBefore
+// (a instância que as executava parou) também são retomadas. Retorna nil quando a fila está
+// vazia; o contexto deve vir de tenant.AllCompanies.
+func (r *fileExchangeRepository) ClaimRun(ctx context.Context, now, staleBefore time.Time) (*models.Run, error) {
+	var claimed *models.Run
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var run models.Run
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND started_at < ?)",
+				models.RunQueued, now, models.RunRunning, staleBefore).
+			Order("next_attempt_at ASC, id ASC").Take(&run).Error
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		run.Status, run.StartedAt, run.Attempts = models.RunRunning, &now, run.Attempts+1
+		if err := tx.Model(&run).Updates(map[string]interface{}{
+			"status": run.Status, "started_at": now, "attempts": run.Attempts,
+		}).Error; err != nil {
+			return err
+		}
+		claimed = &run
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("erro ao retirar execução da fila da troca de arquivos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao retirar execução da fila da troca de arquivos")
+	}
+	return claimed, nil
+}
+
+// SaveRun grava o andamento e o resultado da execução
+func (r *fileExchangeRepository) SaveRun(ctx context.Context, run *models.Run) error {
+	err := db.Conn(ctx, r.db).Model(run).Select("status", "attempts", "next_attempt_at", "file_count", "log",
+		"error", "requested_by", "finished_at").Updates(run).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar execução da troca de arquivos", zap.Error(err), zap.Int("id", run.ID))
+		return errors.WrapError(err, "falha ao gravar execução da troca de arquivos")
+	}
+	return nil
+}
+
+// MappingTarget retorna o destino do mapeamento de importação
+func (r *fileExchangeRepository) MappingTarget(ctx context.Context, mappingID int) (string, error) {
+	var mapping etl.Mapping
+	if err := db.Conn(ctx, r.db).Select("id", "target").First(&mapping, mappingID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.ErrMappingNotFound
+		}
+		return "", errors.WrapError(err, "falha ao buscar mapeamento de importação")
+	}
+	return mapping.Target, nil
+}
+
+// InvoicesChanged retorna as faturas emitidas (fora rascunhos e canceladas) alteradas no
+// intervalo (since, until], do contato quando informado
+func (r *fileExchangeRepository) InvoicesChanged(ctx context.Context, since *time.Time, until time.Time, contactID *int) ([]int, error) {
+	query := db.Conn(ctx, r.db).Model(&sales.Invoice{}).
+		Where("status NOT IN ? AND updated_at <= ?", []string{sales.InvoiceStatusDraft, sales.InvoiceStatusCancelled}, until)
+	if since != nil {
+		query = query.Where("updated_at > ?", *since)
+	}
+	if contactID != nil {
+		query = query.Where("contact_id = ?", *contactID)
+	}
+
+	var ids []int
+	if err := query.Order("id ASC").Pluck("id", &ids).Error; err != nil {
+		r.logger.Error("erro ao buscar faturas para envio", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar faturas para envio")
+	}
+	return ids, nil
+}
+
+// PriceListProducts retorna os produtos ativos, por SKU
+func (r *fileExchangeRepository) PriceListProducts(ctx context.Context) ([]products.Product, error) {
+	var list []products.Product
+	if err := db.Conn(ctx, r.db).Where("status = ?", "ativo").Order("sku ASC, id ASC").Find(&list).Error; err != nil {
+		r.logger.Error("erro ao buscar produtos da tabela de preços", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar produtos da tabela de preços")
+	}
+	return list, nil
+}
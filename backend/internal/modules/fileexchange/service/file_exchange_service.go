@@ -0,0 +1,862 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/ftp"
+	"ERP-ONSMART/backend/internal/integrations/sftp"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	bankingModels "ERP-ONSMART/backend/internal/modules/banking/models"
+	bankingService "ERP-ONSMART/backend/internal/modules/banking/service"
+	ediModels "ERP-ONSMART/backend/internal/modules/edi/models"
+	ediService "ERP-ONSMART/backend/internal/modules/edi/service"
+	etlModels "ERP-ONSMART/backend/internal/modules/etl/models"
+	etlService "ERP-ONSMART/backend/internal/modules/etl/service"
+	"ERP-ONSMART/backend/internal/modules/fileexchange/models"
+	"ERP-ONSMART/backend/internal/modules/fileexchange/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/vault"
+
+	"go.uber.org/zap"
+)
+
+// maxRunsLimit limita a quantidade de execuções listadas por rotina
+const maxRunsLimit = 100
+
+// runTimeout limita cada execução; as que ficam em andamento além dele (a instância parou) são
+// retomadas pela fila
+const runTimeout = 30 * time.Minute
+
+// Espera antes de cada nova tentativa: dobra a cada falha, de retryBaseDelay até retryMaxDelay
+const (
+	retryBaseDelay = time.Minute
+	retryMaxDelay  = time.Hour
+)
+
+// importedBy identifica as importações feitas pela troca de arquivos
+const importedBy = "file_exchange"
+
+// Session é a conexão aberta com o servidor, SFTP ou FTP
+type Session interface {
+	Put(remotePath string, data []byte) error
+	Get(remotePath string) ([]byte, error)
+	List(dir string) ([]string, error)
+	Rename(from, to string) error
+	Remove(remotePath string) error
+	Close() error
+}
+
+// Service mantém os servidores SFTP/FTP dos parceiros e as rotinas de envio e coleta de
+// arquivos, executadas pela fila com novas tentativas
+type Service struct {
+	newRepo func() (repository.FileExchangeRepository, error)
+	// Conecta ao servidor com as credenciais já decifradas
+	dial func(ctx context.Context, endpoint *models.Endpoint, password, privateKey string) (Session, error)
+	// Geram e importam os arquivos; retornam o resumo gravado no log da execução
+	exportInvoice   func(ctx context.Context, id int) (*ediModels.Document, error)
+	importOrders    func(ctx context.Context, mappingID int, fileName string, content []byte) (string, error)
+	importStatement func(ctx context.Context, bankAccount, fileName string, content []byte) (string, error)
+	now             func() time.Time
+	logger          *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.FileExchangeRepository
+}
+
+// NewService cria o serviço sobre o repositório informado, com os clientes SFTP e FTP e as
+// exportações e importações dos módulos de EDI, ETL e conciliação bancária
+func NewService(newRepo func() (repository.FileExchangeRepository, error)) *Service {
+	return &Service{
+		newRepo:         newRepo,
+		dial:            dialEndpoint,
+		exportInvoice:   exportInvoice,
+		importOrders:    importOrders,
+		importStatement: importStatement,
+		now:             time.Now,
+		logger:          logger.WithModule("file_exchange_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewFileExchangeRepository)
+
+// ListEndpoints lista os servidores da empresa da requisição
+func ListEndpoints(ctx context.Context) ([]models.Endpoint, error) {
+	return defaultService.ListEndpoints(ctx)
+}
+
+// CreateEndpoint cadastra um servidor na empresa da requisição
+func CreateEndpoint(ctx context.Context, input models.EndpointInput) (*models.Endpoint, error) {
+	return defaultService.CreateEndpoint(ctx, input)
+}
+
+// UpdateEndpoint altera o cadastro de um servidor
+func UpdateEndpoint(ctx context.Context, id int, input models.EndpointInput) (*models.Endpoint, error) {
+	return defaultService.UpdateEndpoint(ctx, id, input)
+}
+
+// ListSchedules lista as rotinas da empresa da requisição
+func ListSchedules(ctx context.Context) ([]models.Schedule, error) {
+	return defaultService.ListSchedules(ctx)
+}
+
+// CreateSchedule cadastra uma rotina na empresa da requisição
+func CreateSchedule(ctx context.Context, input models.ScheduleInput, createdBy string) (*models.Schedule, error) {
+	return defaultService.CreateSchedule(ctx, input, createdBy)
+}
+
+// UpdateSchedule altera o cadastro de uma rotina
+func UpdateSchedule(ctx context.Context, id int, input models.ScheduleInput) (*models.Schedule, error) {
+	return defaultService.UpdateSchedule(ctx, id, input)
+}
+
+// RunNow coloca uma execução da rotina na fila
+func RunNow(ctx context.Context, scheduleID int, requestedBy string) (*models.Run, error) {
+	return defaultService.RunNow(ctx, scheduleID, requestedBy)
+}
+
+// ListRuns retorna as execuções mais recentes da rotina
+func ListRuns(ctx context.Context, scheduleID, limit int) ([]models.Run, error) {
+	return defaultService.ListRuns(ctx, scheduleID, limit)
+}
+
+// GetRun retorna uma execução com o log
+func GetRun(ctx context.Context, id int) (*models.Run, error) {
+	return defaultService.GetRun(ctx, id)
+}
+
+// RetryRun devolve à fila uma execução com falha
+func RetryRun(ctx context.Context, id int, requestedBy string) (*models.Run, error) {
+	return defaultService.RetryRun(ctx, id, requestedBy)
+}
+
+// StartFileExchangeRunner inicia a rotina que enfileira as rotinas vencidas e executa a fila da
+// troca de arquivos a cada intervalo, para todas as empresas
+func StartFileExchangeRunner(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("file_exchange_runner")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				processed, err := defaultService.RunPending(ctx)
+				metrics.ObserveJob("file_exchange_runner", err)
+				if err != nil {
+					log.Error("erro ao processar a fila da troca de arquivos", zap.Error(err))
+					continue
+				}
+				if processed > 0 {
+					log.Info("fila da troca de arquivos processada", zap.Int("processed", processed))
+				}
+			}
+		}
+	}()
+}
+
+func (s *Service) repository() (repository.FileExchangeRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListEndpoints lista os servidores da empresa, sem as credenciais
+func (s *Service) ListEndpoints(ctx context.Context) ([]models.Endpoint, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := repo.ListEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range endpoints {
+		endpoints[i].FillCredentialFlags()
+	}
+	return endpoints, nil
+}
+
+// CreateEndpoint valida as credenciais e grava o servidor, ativo por padrão, com a senha e a
+// chave privada cifradas
+func (s *Service) CreateEndpoint(ctx context.Context, input models.EndpointInput) (*models.Endpoint, error) {
+	endpoint := &models.Endpoint{Active: true}
+	if err := applyEndpointInput(endpoint, input); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("servidor de troca de arquivos criado", zap.Int("id", endpoint.ID),
+		zap.String("protocol", endpoint.Protocol), zap.String("host", endpoint.Host))
+	endpoint.FillCredentialFlags()
+	return endpoint, nil
+}
+
+// UpdateEndpoint altera o servidor; senha e chave privada em branco mantêm as gravadas
+func (s *Service) UpdateEndpoint(ctx context.Context, id int, input models.EndpointInput) (*models.Endpoint, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyEndpointInput(endpoint, input); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("servidor de troca de arquivos atualizado", zap.Int("id", endpoint.ID))
+	endpoint.FillCredentialFlags()
+	return endpoint, nil
+}
+
+// applyEndpointInput copia os dados do cadastro, cifra as credenciais informadas e confere as
+// chaves do SFTP. No FTP só há senha: a chave privada e a chave do servidor são descartadas.
+func applyEndpointInput(endpoint *models.Endpoint, input models.EndpointInput) error {
+	endpoint.Name = strings.TrimSpace(input.Name)
+	endpoint.Protocol = input.Protocol
+	endpoint.Host = strings.TrimSpace(input.Host)
+	endpoint.Port = input.Port
+	endpoint.Username = strings.TrimSpace(input.Username)
+	endpoint.HostKey = strings.TrimSpace(input.HostKey)
+	endpoint.InboundDir = strings.TrimSpace(input.InboundDir)
+	endpoint.OutboundDir = strings.TrimSpace(input.OutboundDir)
+	endpoint.ArchiveDir = strings.TrimSpace(input.ArchiveDir)
+	if input.Active != nil {
+		endpoint.Active = *input.Active
+	}
+
+	var err error
+	if input.Password != "" {
+		if endpoint.PasswordSealed, err = vault.Seal(input.Password); err != nil {
+			return err
+		}
+	}
+	if input.PrivateKey != "" {
+		if endpoint.PrivateKeySealed, err = vault.Seal(input.PrivateKey); err != nil {
+			return err
+		}
+	}
+
+	if endpoint.Protocol == models.ProtocolFTP {
+		endpoint.PrivateKeySealed, endpoint.HostKey = "", ""
+		if endpoint.Port == 0 {
+			endpoint.Port = ftp.DefaultPort
+		}
+		if endpoint.PasswordSealed == "" {
+			return errors.ErrFileEndpointCredentials
+		}
+		return nil
+	}
+
+	if endpoint.Port == 0 {
+		endpoint.Port = sftp.DefaultPort
+	}
+	if endpoint.PasswordSealed == "" && endpoint.PrivateKeySealed == "" {
+		return errors.ErrFileEndpointCredentials
+	}
+	password, privateKey, err := openCredentials(endpoint)
+	if err != nil {
+		return err
+	}
+	if err := sftpConfig(endpoint, password, privateKey).Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidFileEndpointKey, err)
+	}
+	return nil
+}
+
+// openCredentials decifra a senha e a chave privada do servidor
+func openCredentials(endpoint *models.Endpoint) (string, string, error) {
+	password, err := vault.Open(endpoint.PasswordSealed)
+	if err != nil {
+		return "", "", err
+	}
+	privateKey, err := vault.Open(endpoint.PrivateKeySealed)
+	if err != nil {
+		return "", "", err
+	}
+	return password, privateKey, nil
+}
+
+func sftpConfig(endpoint *models.Endpoint, password, privateKey string) sftp.Config {
+	return sftp.Config{
+		Host:       endpoint.Host,
+		Port:       endpoint.Port,
+		User:       endpoint.Username,
+		Password:   password,
+		PrivateKey: privateKey,
+		HostKey:    endpoint.HostKey,
+	}
+}
+
+// dialEndpoint conecta ao servidor pelo protocolo cadastrado
+func dialEndpoint(ctx context.Context, endpoint *models.Endpoint, password, privateKey string) (Session, error) {
+	if endpoint.Protocol == models.ProtocolFTP {
+		return ftp.Dial(ctx, ftp.Config{
+			Host:     endpoint.Host,
+			Port:     endpoint.Port,
+			User:     endpoint.Username,
+			Password: password,
+		})
+	}
+	return sftp.Dial(ctx, sftpConfig(endpoint, password, privateKey))
+}
+
+// ListSchedules lista as rotinas da empresa
+func (s *Service) ListSchedules(ctx context.Context) ([]models.Schedule, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListSchedules(ctx)
+}
+
+// CreateSchedule valida os parâmetros do tipo e grava a rotina, ativa por padrão, com a primeira
+// execução na próxima passada da fila
+func (s *Service) CreateSchedule(ctx context.Context, input models.ScheduleInput, createdBy string) (*models.Schedule, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &models.Schedule{Active: true, NextRunAt: s.now(), CreatedBy: createdBy}
+	if err := s.applyScheduleInput(ctx, repo, schedule, input); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("rotina de troca de arquivos criada", zap.Int("id", schedule.ID), zap.String("kind", schedule.Kind),
+		zap.Int("endpoint_id", schedule.EndpointID))
+	return schedule, nil
+}
+
+// UpdateSchedule altera a rotina; o próximo horário é mantido
+func (s *Service) UpdateSchedule(ctx context.Context, id int, input models.ScheduleInput) (*models.Schedule, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := repo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyScheduleInput(ctx, repo, schedule, input); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("rotina de troca de arquivos atualizada", zap.Int("id", schedule.ID))
+	return schedule, nil
+}
+
+func (s *Service) applyScheduleInput(ctx context.Context, repo repository.FileExchangeRepository, schedule *models.Schedule, input models.ScheduleInput) error {
+	endpoint, err := repo.GetEndpoint(ctx, input.EndpointID)
+	if err != nil {
+		return err
+	}
+
+	schedule.EndpointID = endpoint.ID
+	schedule.Endpoint = endpoint
+	schedule.Name = strings.TrimSpace(input.Name)
+	schedule.Kind = input.Kind
+	schedule.FilePattern = strings.TrimSpace(input.FilePattern)
+	schedule.ContactID = nil
+	schedule.MappingID = nil
+	schedule.BankAccount = ""
+	schedule.IntervalMinutes = input.IntervalMinutes
+	schedule.MaxAttempts = input.MaxAttempts
+	if schedule.MaxAttempts == 0 {
+		schedule.MaxAttempts = models.DefaultMaxAttempts
+	}
+	if input.Active != nil {
+		schedule.Active = *input.Active
+	}
+
+	if models.KindDirection(schedule.Kind) == models.DirectionPull {
+		if endpoint.ArchiveDir == "" {
+			return fmt.Errorf("%w: o servidor precisa de um diretório de arquivamento (archive_dir) para as rotinas de coleta", errors.ErrInvalidFileSchedule)
+		}
+		if _, err := path.Match(schedule.FilePattern, ""); err != nil {
+			return fmt.Errorf("%w: file_pattern inválido", errors.ErrInvalidFileSchedule)
+		}
+	} else {
+		schedule.FilePattern = ""
+	}
+
+	switch schedule.Kind {
+	case models.KindEDIInvoices:
+		schedule.ContactID = input.ContactID
+	case models.KindSalesOrders:
+		if input.MappingID == nil {
+			return fmt.Errorf("%w: informe o mapeamento de importação (mapping_id)", errors.ErrInvalidFileSchedule)
+		}
+		target, err := repo.MappingTarget(ctx, *input.MappingID)
+		if err != nil {
+			return err
+		}
+		if target != etlModels.TargetSalesOrders {
+			return fmt.Errorf("%w: o mapeamento deve importar pedidos de venda", errors.ErrInvalidFileSchedule)
+		}
+		schedule.MappingID = input.MappingID
+	case models.KindCNABReturn:
+		schedule.BankAccount = strings.TrimSpace(input.BankAccount)
+		if schedule.BankAccount == "" {
+			return fmt.Errorf("%w: informe a conta bancária do extrato (bank_account)", errors.ErrInvalidFileSchedule)
+		}
+	}
+	return nil
+}
+
+// RunNow coloca na fila uma execução manual da rotina, mesmo inativa; recusa quando já há uma
+// execução na fila ou em andamento
+func (s *Service) RunNow(ctx context.Context, scheduleID int, requestedBy string) (*models.Run, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := repo.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := repo.HasPendingRun(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, errors.ErrFileExchangeRunPending
+	}
+
+	run := s.newRun(schedule, models.TriggerManual, requestedBy)
+	if err := repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+	s.logger.Info("execução da troca de arquivos enfileirada", zap.Int("id", run.ID), zap.Int("schedule_id", schedule.ID),
+		zap.String("requested_by", requestedBy))
+	return run, nil
+}
+
+func (s *Service) newRun(schedule *models.Schedule, trigger, requestedBy string) *models.Run {
+	now := s.now()
+	run := &models.Run{
+		CompanyID:     schedule.CompanyID,
+		ScheduleID:    schedule.ID,
+		Trigger:       trigger,
+		Status:        models.RunQueued,
+		MaxAttempts:   schedule.MaxAttempts,
+		NextAttemptAt: now,
+		Since:         schedule.LastSuccessAt,
+		Until:         now,
+		RequestedBy:   requestedBy,
+	}
+	if run.MaxAttempts < 1 {
+		run.MaxAttempts = models.DefaultMaxAttempts
+	}
+	return run
+}
+
+// ListRuns retorna as execuções mais recentes da rotina da empresa (padrão 20, máximo 100)
+func (s *Service) ListRuns(ctx context.Context, scheduleID, limit int) ([]models.Run, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxRunsLimit {
+		limit = maxRunsLimit
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	// Garante que a rotina pertence à empresa da requisição
+	if _, err := repo.GetSchedule(ctx, scheduleID); err != nil {
+		return nil, err
+	}
+	return repo.ListRuns(ctx, scheduleID, limit)
+}
+
+// GetRun retorna uma execução da empresa
+func (s *Service) GetRun(ctx context.Context, id int) (*models.Run, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRun(ctx, id)
+}
+
+// RetryRun devolve à fila uma execução que esgotou as tentativas, com novas tentativas; recusa
+// as que não falharam e as de rotinas com outra execução pendente
+func (s *Service) RetryRun(ctx context.Context, id int, requestedBy string) (*models.Run, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	run, err := repo.GetRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != models.RunFailed {
+		return nil, errors.ErrFileExchangeRunNotFailed
+	}
+	pending, err := repo.HasPendingRun(ctx, run.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, errors.ErrFileExchangeRunPending
+	}
+
+	now := s.now()
+	run.Status = models.RunQueued
+	run.MaxAttempts = run.Attempts + max(run.MaxAttempts, 1)
+	run.NextAttemptAt = now
+	run.FinishedAt = nil
+	run.RequestedBy = requestedBy
+	run.AppendLog(now, "devolvida à fila por "+requestedBy)
+	if err := repo.SaveRun(ctx, run); err != nil {
+		return nil, err
+	}
+	s.logger.Info("execução da troca de arquivos devolvida à fila", zap.Int("id", run.ID), zap.String("requested_by", requestedBy))
+	return run, nil
+}
+
+// RunPending coloca na fila as rotinas vencidas e executa as execuções da fila com a tentativa
+// vencida, de todas as empresas. Retorna a quantidade de execuções processadas; o resultado de
+// cada uma fica gravado nela.
+func (s *Service) RunPending(ctx context.Context) (int, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, err
+	}
+	ctx = tenant.AllCompanies(ctx)
+
+	if err := s.enqueueDue(ctx, repo); err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for {
+		now := s.now()
+		run, err := repo.ClaimRun(ctx, now, now.Add(-runTimeout))
+		if err != nil {
+			return processed, err
+		}
+		if run == nil {
+			return processed, nil
+		}
+		s.execute(ctx, repo, run)
+		processed++
+	}
+}
+
+// enqueueDue cria a execução de cada rotina vencida e agenda a próxima. Uma rotina que ainda tem
+// execução pendente (o servidor está fora, por exemplo) só é reagendada, sem acumular a fila.
+func (s *Service) enqueueDue(ctx context.Context, repo repository.FileExchangeRepository) error {
+	due, err := repo.DueSchedules(ctx, s.now())
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		schedule := &due[i]
+		companyCtx := tenant.WithCompany(ctx, schedule.CompanyID)
+		pending, err := repo.HasPendingRun(companyCtx, schedule.ID)
+		if err != nil {
+			return err
+		}
+		if !pending {
+			if err := repo.CreateRun(companyCtx, s.newRun(schedule, models.TriggerSchedule, "")); err != nil {
+				return err
+			}
+		}
+		next := s.now().Add(time.Duration(schedule.IntervalMinutes) * time.Minute)
+		if err := repo.SetNextRun(companyCtx, schedule.ID, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute processa a execução na empresa dela e grava o resultado: concluída, de volta à fila
+// para nova tentativa ou com falha, quando as tentativas se esgotam
+func (s *Service) execute(ctx context.Context, repo repository.FileExchangeRepository, run *models.Run) {
+	companyCtx := tenant.WithCompany(ctx, run.CompanyID)
+	jobCtx, cancel := context.WithTimeout(companyCtx, runTimeout)
+	defer cancel()
+
+	run.AppendLog(s.now(), fmt.Sprintf("tentativa %d de %d", run.Attempts, run.MaxAttempts))
+	schedule, err := repo.GetSchedule(companyCtx, run.ScheduleID)
+	if err == nil {
+		err = s.process(jobCtx, repo, schedule, run)
+	}
+
+	now := s.now()
+	if err == nil {
+		run.Status, run.Error, run.FinishedAt = models.RunSucceeded, "", &now
+		run.AppendLog(now, fmt.Sprintf("concluída: %d arquivo(s)", run.FileCount))
+	} else {
+		run.Error = err.Error()
+		run.AppendLog(now, "falha: "+err.Error())
+		if run.Attempts < run.MaxAttempts {
+			run.Status, run.NextAttemptAt = models.RunQueued, now.Add(retryDelay(run.Attempts))
+			run.AppendLog(now, "nova tentativa em "+run.NextAttemptAt.UTC().Format(time.RFC3339))
+		} else {
+			run.Status, run.FinishedAt = models.RunFailed, &now
+		}
+		s.logger.Warn("falha na execução da troca de arquivos", zap.Error(err), zap.Int("id", run.ID),
+			zap.Int("schedule_id", run.ScheduleID), zap.Int("attempt", run.Attempts))
+	}
+	if err := repo.SaveRun(companyCtx, run); err != nil {
+		s.logger.Error("erro ao gravar o resultado da execução", zap.Error(err), zap.Int("id", run.ID))
+	}
+
+	if schedule == nil {
+		return
+	}
+	var successAt *time.Time
+	// Uma tentativa antiga concluída depois de uma execução mais nova não recua a janela
+	if run.Status == models.RunSucceeded && (schedule.LastSuccessAt == nil || run.Until.After(*schedule.LastSuccessAt)) {
+		successAt = &run.Until
+	}
+	if err := repo.MarkScheduleRun(companyCtx, schedule.ID, now, successAt, run.Error); err != nil {
+		s.logger.Error("erro ao registrar execução da rotina", zap.Error(err), zap.Int("schedule_id", schedule.ID))
+	}
+}
+
+// retryDelay é a espera antes da próxima tentativa, depois de attempts tentativas
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
+
+// process conecta ao servidor e envia ou coleta os arquivos da rotina
+func (s *Service) process(ctx context.Context, repo repository.FileExchangeRepository, schedule *models.Schedule, run *models.Run) error {
+	endpoint := schedule.Endpoint
+	if endpoint == nil || !endpoint.Active {
+		return errors.ErrFileEndpointInactive
+	}
+	password, privateKey, err := openCredentials(endpoint)
+	if err != nil {
+		return err
+	}
+
+	session, err := s.dial(ctx, endpoint, password, privateKey)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	switch schedule.Kind {
+	case models.KindEDIInvoices:
+		return s.pushInvoices(ctx, repo, session, schedule, run)
+	case models.KindPriceList:
+		return s.pushPriceList(ctx, repo, session, schedule, run)
+	case models.KindSalesOrders, models.KindCNABReturn:
+		return s.pull(ctx, session, schedule, run)
+	}
+	return fmt.Errorf("%w: tipo %q desconhecido", errors.ErrInvalidFileSchedule, schedule.Kind)
+}
+
+// pushInvoices grava o UBL de cada fatura emitida ou alterada na janela da execução. Uma nova
+// tentativa reenvia todas: o arquivo de cada fatura tem nome fixo e é substituído.
+func (s *Service) pushInvoices(ctx context.Context, repo repository.FileExchangeRepository, session Session, schedule *models.Schedule, run *models.Run) error {
+	ids, err := repo.InvoicesChanged(ctx, run.Since, run.Until, schedule.ContactID)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		run.AppendLog(s.now(), "nenhuma fatura emitida ou alterada no período")
+		return nil
+	}
+
+	run.FileCount = 0
+	for _, id := range ids {
+		doc, err := s.exportInvoice(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := session.Put(path.Join(schedule.Endpoint.OutboundDir, doc.FileName), doc.Content); err != nil {
+			return err
+		}
+		run.FileCount++
+		run.AppendLog(s.now(), fmt.Sprintf("enviado %s (%d bytes)", doc.FileName, len(doc.Content)))
+	}
+	return nil
+}
+
+// pushPriceList grava o CSV com o preço de venda dos produtos ativos (o preço de tabela quando
+// não há preço de venda)
+func (s *Service) pushPriceList(ctx context.Context, repo repository.FileExchangeRepository, session Session, schedule *models.Schedule, run *models.Run) error {
+	list, err := repo.PriceListProducts(ctx)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	w.Write([]string{"sku", "barcode", "name", "currency", "price", "stock"})
+	for _, product := range list {
+		price := product.SalesPrice
+		if price.IsZero() {
+			price = product.Price
+		}
+		w.Write([]string{product.SKU, product.Barcode, product.Name, product.Coin,
+			price.StringFixed(2), strconv.Itoa(product.Stock)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return errors.WrapError(err, "falha ao gerar a tabela de preços")
+	}
+
+	fileName := "price-list-" + run.Until.UTC().Format("20060102") + ".csv"
+	if err := session.Put(path.Join(schedule.Endpoint.OutboundDir, fileName), buf.Bytes()); err != nil {
+		return err
+	}
+	run.FileCount = 1
+	run.AppendLog(s.now(), fmt.Sprintf("enviado %s (%d produtos)", fileName, len(list)))
+	return nil
+}
+
+// pull importa os arquivos do diretório de entrada que atendem ao filtro e move cada um para o
+// de arquivamento. Um arquivo com falha fica no diretório de entrada para a próxima tentativa;
+// os demais seguem.
+func (s *Service) pull(ctx context.Context, session Session, schedule *models.Schedule, run *models.Run) error {
+	endpoint := schedule.Endpoint
+	inbound := endpoint.InboundDir
+	if inbound == "" {
+		inbound = "."
+	}
+	names, err := session.List(inbound)
+	if err != nil {
+		return err
+	}
+
+	var (
+		firstErr error
+		failed   int
+		total    int
+	)
+	for _, name := range names {
+		// Arquivos .part ainda estão sendo gravados pelo parceiro
+		if strings.HasSuffix(name, ".part") || !schedule.Matches(name) {
+			continue
+		}
+		total++
+		if err := s.pullFile(ctx, session, schedule, run, path.Join(inbound, name), name); err != nil {
+			failed++
+			run.AppendLog(s.now(), fmt.Sprintf("falha em %s: %v", name, err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if total == 0 {
+		run.AppendLog(s.now(), "nenhum arquivo a coletar")
+	}
+	if firstErr != nil {
+		return fmt.Errorf("%d de %d arquivo(s) com falha: %w", failed, total, firstErr)
+	}
+	return nil
+}
+
+func (s *Service) pullFile(ctx context.Context, session Session, schedule *models.Schedule, run *models.Run, remotePath, name string) error {
+	content, err := session.Get(remotePath)
+	if err != nil {
+		return err
+	}
+
+	var summary string
+	switch schedule.Kind {
+	case models.KindSalesOrders:
+		summary, err = s.importOrders(ctx, *schedule.MappingID, name, content)
+	case models.KindCNABReturn:
+		summary, err = s.importStatement(ctx, schedule.BankAccount, name, content)
+	}
+	if err != nil {
+		return err
+	}
+	run.FileCount++
+	run.AppendLog(s.now(), fmt.Sprintf("importado %s: %s", name, summary))
+
+	// O arquivo importado sai do diretório de entrada: se não puder ser arquivado, é apagado,
+	// para não ser importado de novo
+	archived := path.Join(schedule.Endpoint.ArchiveDir, name)
+	if err := session.Rename(remotePath, archived); err != nil {
+		if removeErr := session.Remove(remotePath); removeErr != nil {
+			return fmt.Errorf("importado, mas não foi possível arquivar nem apagar o arquivo: %w", err)
+		}
+		run.AppendLog(s.now(), fmt.Sprintf("%s apagado: não foi possível arquivá-lo (%v)", name, err))
+	}
+	return nil
+}
+
+func exportInvoice(ctx context.Context, id int) (*ediModels.Document, error) {
+	return ediService.Export(ctx, ediModels.DocumentInvoice, id)
+}
+
+// importOrders importa os pedidos do arquivo; linhas rejeitadas ficam no resumo, e o arquivo
+// só falha quando nenhuma linha é importada
+func importOrders(ctx context.Context, mappingID int, fileName string, content []byte) (string, error) {
+	result, err := etlService.Execute(ctx, mappingID, fileName, content, false, importedBy)
+	if err != nil {
+		return "", err
+	}
+	summary := fmt.Sprintf("importação %d: %d linha(s) importada(s), %d rejeitada(s)", result.ID, result.Succeeded, result.Failed)
+	if result.Status == etlModels.RunStatusFailed {
+		return "", fmt.Errorf("nenhuma linha importada (%s)", summary)
+	}
+	return summary, nil
+}
+
+// importStatement importa o retorno como extrato da conta; um retorno só com entradas e baixas
+// de títulos, sem liquidações, é arquivado sem gerar extrato
+func importStatement(ctx context.Context, bankAccount, fileName string, content []byte) (string, error) {
+	statement, err := bankingService.ImportBankStatement(ctx, bankAccount, fileName, bankingModels.StatementFormatCNAB240, content)
+	if err == errors.ErrEmptyStatement {
+		return "nenhuma liquidação no retorno", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("extrato %d com %d lançamento(s)", statement.ID, statement.LineCount), nil
+}
@@ -0,0 +1,574 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	ediModels "ERP-ONSMART/backend/internal/modules/edi/models"
+	etlModels "ERP-ONSMART/backend/internal/modules/etl/models"
+	"ERP-ONSMART/backend/internal/modules/fileexchange/models"
+	"ERP-ONSMART/backend/internal/modules/fileexchange/repository"
+	products "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/vault"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+type fakeRepo struct {
+	endpoints map[int]*models.Endpoint
+	schedules map[int]*models.Schedule
+	runs      []*models.Run
+	invoices  map[int]time.Time
+	mappings  map[int]string
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		endpoints: map[int]*models.Endpoint{},
+		schedules: map[int]*models.Schedule{},
+		invoices:  map[int]time.Time{},
+		mappings:  map[int]string{1: etlModels.TargetSalesOrders, 2: etlModels.TargetContacts},
+	}
+}
+
+func (f *fakeRepo) ListEndpoints(context.Context) ([]models.Endpoint, error) {
+	var endpoints []models.Endpoint
+	for _, endpoint := range f.endpoints {
+		endpoints = append(endpoints, *endpoint)
+	}
+	return endpoints, nil
+}
+
+func (f *fakeRepo) GetEndpoint(_ context.Context, id int) (*models.Endpoint, error) {
+	endpoint, ok := f.endpoints[id]
+	if !ok {
+		return nil, errors.ErrFileEndpointNotFound
+	}
+	copied := *endpoint
+	return &copied, nil
+}
+
+func (f *fakeRepo) CreateEndpoint(_ context.Context, endpoint *models.Endpoint) error {
+	endpoint.ID = len(f.endpoints) + 1
+	copied := *endpoint
+	f.endpoints[endpoint.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) UpdateEndpoint(_ context.Context, endpoint *models.Endpoint) error {
+	copied := *endpoint
+	f.endpoints[endpoint.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ListSchedules(context.Context) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	for _, schedule := range f.schedules {
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, nil
+}
+
+func (f *fakeRepo) GetSchedule(ctx context.Context, id int) (*models.Schedule, error) {
+	schedule, ok := f.schedules[id]
+	if !ok {
+		return nil, errors.ErrFileScheduleNotFound
+	}
+	copied := *schedule
+	copied.Endpoint, _ = f.GetEndpoint(ctx, schedule.EndpointID)
+	return &copied, nil
+}
+
+func (f *fakeRepo) CreateSchedule(_ context.Context, schedule *models.Schedule) error {
+	schedule.ID = len(f.schedules) + 1
+	copied := *schedule
+	f.schedules[schedule.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) UpdateSchedule(_ context.Context, schedule *models.Schedule) error {
+	copied := *schedule
+	f.schedules[schedule.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) DueSchedules(_ context.Context, now time.Time) ([]models.Schedule, error) {
+	var due []models.Schedule
+	for _, schedule := range f.schedules {
+		if schedule.Active && !schedule.NextRunAt.After(now) {
+			due = append(due, *schedule)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeRepo) SetNextRun(_ context.Context, scheduleID int, nextRunAt time.Time) error {
+	f.schedules[scheduleID].NextRunAt = nextRunAt
+	return nil
+}
+
+func (f *fakeRepo) MarkScheduleRun(_ context.Context, scheduleID int, ranAt time.Time, successAt *time.Time, runErr string) error {
+	schedule := f.schedules[scheduleID]
+	schedule.LastRunAt, schedule.LastError = &ranAt, runErr
+	if successAt != nil {
+		schedule.LastSuccessAt = successAt
+	}
+	return nil
+}
+
+func (f *fakeRepo) CreateRun(_ context.Context, run *models.Run) error {
+	run.ID = len(f.runs) + 1
+	copied := *run
+	f.runs = append(f.runs, &copied)
+	return nil
+}
+
+func (f *fakeRepo) GetRun(_ context.Context, id int) (*models.Run, error) {
+	if id < 1 || id > len(f.runs) {
+		return nil, errors.ErrFileExchangeRunNotFound
+	}
+	copied := *f.runs[id-1]
+	return &copied, nil
+}
+
+func (f *fakeRepo) ListRuns(_ context.Context, scheduleID, limit int) ([]models.Run, error) {
+	var runs []models.Run
+	for i := len(f.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		if f.runs[i].ScheduleID == scheduleID {
+			runs = append(runs, *f.runs[i])
+		}
+	}
+	return runs, nil
+}
+
+func (f *fakeRepo) HasPendingRun(_ context.Context, scheduleID int) (bool, error) {
+	for _, run := range f.runs {
+		if run.ScheduleID == scheduleID && (run.Status == models.RunQueued || run.Status == models.RunRunning) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRepo) ClaimRun(_ context.Context, now, staleBefore time.Time) (*models.Run, error) {
+	for _, run := range f.runs {
+		queued := run.Status == models.RunQueued && !run.NextAttemptAt.After(now)
+		stale := run.Status == models.RunRunning && run.StartedAt.Before(staleBefore)
+		if queued || stale {
+			run.Status, run.StartedAt, run.Attempts = models.RunRunning, &now, run.Attempts+1
+			copied := *run
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRepo) SaveRun(_ context.Context, run *models.Run) error {
+	copied := *run
+	f.runs[run.ID-1] = &copied
+	return nil
+}
+
+func (f *fakeRepo) MappingTarget(_ context.Context, mappingID int) (string, error) {
+	target, ok := f.mappings[mappingID]
+	if !ok {
+		return "", errors.ErrMappingNotFound
+	}
+	return target, nil
+}
+
+func (f *fakeRepo) InvoicesChanged(_ context.Context, since *time.Time, until time.Time, _ *int) ([]int, error) {
+	var ids []int
+	for id, updatedAt := range f.invoices {
+		if (since == nil || updatedAt.After(*since)) && !updatedAt.After(until) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (f *fakeRepo) PriceListProducts(context.Context) ([]products.Product, error) {
+	return []products.Product{
+		{SKU: "TEC-01", Name: "Teclado", Coin: "BRL", Price: money.FromInt(120), SalesPrice: money.MustParse("99.9"), Stock: 7},
+		{SKU: "MOU-01", Name: "Mouse; sem fio", Coin: "BRL", Price: money.FromInt(45)},
+	}, nil
+}
+
+// fakeServer é o servidor remoto em memória, compartilhado pelas sessões abertas pelo dial
+type fakeServer struct {
+	files    map[string][]byte
+	dialErr  error
+	dialed   []string
+	password string
+}
+
+type fakeSession struct{ server *fakeServer }
+
+func (s fakeSession) Put(remotePath string, data []byte) error {
+	s.server.files[remotePath] = data
+	return nil
+}
+
+func (s fakeSession) Get(remotePath string) ([]byte, error) {
+	data, ok := s.server.files[remotePath]
+	if !ok {
+		return nil, fmt.Errorf("%s não encontrado", remotePath)
+	}
+	return data, nil
+}
+
+func (s fakeSession) List(dir string) ([]string, error) {
+	var names []string
+	for name := range s.server.files {
+		if path.Dir(name) == dir {
+			names = append(names, path.Base(name))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s fakeSession) Rename(from, to string) error {
+	s.server.files[to] = s.server.files[from]
+	delete(s.server.files, from)
+	return nil
+}
+
+func (s fakeSession) Remove(remotePath string) error {
+	delete(s.server.files, remotePath)
+	return nil
+}
+
+func (s fakeSession) Close() error { return nil }
+
+type testEnv struct {
+	service  *Service
+	repo     *fakeRepo
+	server   *fakeServer
+	clock    time.Time
+	imported []string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	key := make([]byte, vault.KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	viper.Set("VAULT_KEY", base64.StdEncoding.EncodeToString(key))
+	t.Cleanup(func() { viper.Set("VAULT_KEY", "") })
+
+	env := &testEnv{
+		repo:   newFakeRepo(),
+		server: &fakeServer{files: map[string][]byte{}},
+		clock:  time.Date(2030, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	s := NewService(func() (repository.FileExchangeRepository, error) { return env.repo, nil })
+	s.now = func() time.Time { return env.clock }
+	s.dial = func(_ context.Context, endpoint *models.Endpoint, password, _ string) (Session, error) {
+		env.server.dialed = append(env.server.dialed, endpoint.Host)
+		env.server.password = password
+		if env.server.dialErr != nil {
+			return nil, env.server.dialErr
+		}
+		return fakeSession{env.server}, nil
+	}
+	s.exportInvoice = func(_ context.Context, id int) (*ediModels.Document, error) {
+		return &ediModels.Document{FileName: fmt.Sprintf("invoice-INV-%04d.xml", id), Content: []byte("<Invoice/>")}, nil
+	}
+	s.importStatement = func(_ context.Context, bankAccount, fileName string, content []byte) (string, error) {
+		if strings.Contains(string(content), "corrompido") {
+			return "", fmt.Errorf("linha 1 do CNAB 240 com 10 posições")
+		}
+		env.imported = append(env.imported, bankAccount+"/"+fileName)
+		return "extrato com 1 lançamento", nil
+	}
+	env.service = s
+	return env
+}
+
+func testHostKey(t *testing.T) string {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+	return string(ssh.MarshalAuthorizedKey(key))
+}
+
+func sftpInput(t *testing.T) models.EndpointInput {
+	return models.EndpointInput{
+		Name:        "Banco",
+		Protocol:    models.ProtocolSFTP,
+		Host:        "sftp.banco.example",
+		Username:    "onsmart",
+		Password:    "s3nha",
+		HostKey:     testHostKey(t),
+		InboundDir:  "/retorno",
+		OutboundDir: "/remessa",
+		ArchiveDir:  "/retorno/processados",
+	}
+}
+
+func TestEndpointCredentialsAreSealed(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	endpoint, err := env.service.CreateEndpoint(ctx, sftpInput(t))
+	require.NoError(t, err)
+	assert.Equal(t, 22, endpoint.Port)
+	assert.True(t, endpoint.HasPassword)
+	assert.False(t, endpoint.HasPrivateKey)
+	stored := env.repo.endpoints[endpoint.ID]
+	assert.NotContains(t, stored.PasswordSealed, "s3nha")
+	password, err := vault.Open(stored.PasswordSealed)
+	require.NoError(t, err)
+	assert.Equal(t, "s3nha", password)
+
+	// Senha em branco mantém a gravada
+	input := sftpInput(t)
+	input.Password = ""
+	_, err = env.service.UpdateEndpoint(ctx, endpoint.ID, input)
+	require.NoError(t, err)
+	assert.Equal(t, stored.PasswordSealed, env.repo.endpoints[endpoint.ID].PasswordSealed)
+
+	input.HostKey = "ssh-ed25519 não-é-base64"
+	_, err = env.service.UpdateEndpoint(ctx, endpoint.ID, input)
+	assert.ErrorIs(t, err, errors.ErrInvalidFileEndpointKey)
+
+	// FTP: só senha, na porta 21
+	ftpInput := models.EndpointInput{Name: "Atacado", Protocol: models.ProtocolFTP, Host: "ftp.atacado.example",
+		Username: "onsmart", HostKey: testHostKey(t)}
+	_, err = env.service.CreateEndpoint(ctx, ftpInput)
+	assert.ErrorIs(t, err, errors.ErrFileEndpointCredentials)
+	ftpInput.Password = "s3nha"
+	ftpEndpoint, err := env.service.CreateEndpoint(ctx, ftpInput)
+	require.NoError(t, err)
+	assert.Equal(t, 21, ftpEndpoint.Port)
+	assert.Empty(t, ftpEndpoint.HostKey)
+
+	viper.Set("VAULT_KEY", "")
+	_, err = env.service.CreateEndpoint(ctx, sftpInput(t))
+	assert.ErrorIs(t, err, errors.ErrVaultNotConfigured)
+}
+
+func TestCreateScheduleValidatesKindParameters(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	input := sftpInput(t)
+	input.ArchiveDir = ""
+	noArchive, err := env.service.CreateEndpoint(ctx, input)
+	require.NoError(t, err)
+	endpoint, err := env.service.CreateEndpoint(ctx, sftpInput(t))
+	require.NoError(t, err)
+
+	schedule := models.ScheduleInput{EndpointID: noArchive.ID, Name: "Retorno", Kind: models.KindCNABReturn,
+		BankAccount: "001-1234", IntervalMinutes: 60}
+	_, err = env.service.CreateSchedule(ctx, schedule, "admin")
+	assert.ErrorIs(t, err, errors.ErrInvalidFileSchedule)
+	assert.ErrorContains(t, err, "archive_dir")
+
+	schedule.EndpointID = endpoint.ID
+	schedule.BankAccount = ""
+	_, err = env.service.CreateSchedule(ctx, schedule, "admin")
+	assert.ErrorContains(t, err, "bank_account")
+
+	orders := models.ScheduleInput{EndpointID: endpoint.ID, Name: "Pedidos", Kind: models.KindSalesOrders, IntervalMinutes: 15}
+	_, err = env.service.CreateSchedule(ctx, orders, "admin")
+	assert.ErrorContains(t, err, "mapping_id")
+	contacts := 2
+	orders.MappingID = &contacts
+	_, err = env.service.CreateSchedule(ctx, orders, "admin")
+	assert.ErrorContains(t, err, "pedidos de venda")
+
+	salesOrders := 1
+	orders.MappingID = &salesOrders
+	orders.FilePattern = "*.csv"
+	created, err := env.service.CreateSchedule(ctx, orders, "admin")
+	require.NoError(t, err)
+	assert.True(t, created.Active)
+	assert.Equal(t, models.DefaultMaxAttempts, created.MaxAttempts)
+	assert.Equal(t, env.clock, created.NextRunAt)
+	assert.Equal(t, "admin", created.CreatedBy)
+
+	schedule.EndpointID = 99
+	_, err = env.service.CreateSchedule(ctx, schedule, "admin")
+	assert.ErrorIs(t, err, errors.ErrFileEndpointNotFound)
+}
+
+func TestRunPendingPushesInvoicesSinceLastSuccess(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	endpoint, err := env.service.CreateEndpoint(ctx, sftpInput(t))
+	require.NoError(t, err)
+	schedule, err := env.service.CreateSchedule(ctx, models.ScheduleInput{EndpointID: endpoint.ID, Name: "Faturas",
+		Kind: models.KindEDIInvoices, IntervalMinutes: 60}, "admin")
+	require.NoError(t, err)
+	env.repo.invoices[7] = env.clock.Add(-time.Hour)
+
+	processed, err := env.service.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, "s3nha", env.server.password)
+	assert.Contains(t, env.server.files, "/remessa/invoice-INV-0007.xml")
+
+	run := env.repo.runs[0]
+	assert.Equal(t, models.RunSucceeded, run.Status)
+	assert.Equal(t, models.TriggerSchedule, run.Trigger)
+	assert.Equal(t, 1, run.FileCount)
+	assert.Contains(t, run.Log, "enviado invoice-INV-0007.xml")
+	stored := env.repo.schedules[schedule.ID]
+	assert.Equal(t, env.clock, *stored.LastSuccessAt)
+	assert.Equal(t, env.clock.Add(time.Hour), stored.NextRunAt)
+
+	// A próxima execução só envia as faturas alteradas depois da anterior
+	delete(env.server.files, "/remessa/invoice-INV-0007.xml")
+	env.clock = env.clock.Add(time.Hour)
+	env.repo.invoices[8] = env.clock.Add(-time.Minute)
+	_, err = env.service.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, env.server.files, "/remessa/invoice-INV-0008.xml")
+	assert.NotContains(t, env.server.files, "/remessa/invoice-INV-0007.xml")
+}
+
+func TestRunPendingPullsAndArchivesFiles(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	endpoint, err := env.service.CreateEndpoint(ctx, sftpInput(t))
+	require.NoError(t, err)
+	_, err = env.service.CreateSchedule(ctx, models.ScheduleInput{EndpointID: endpoint.ID, Name: "Retorno",
+		Kind: models.KindCNABReturn, BankAccount: "001-1234", FilePattern: "*.RET", IntervalMinutes: 60}, "admin")
+	require.NoError(t, err)
+
+	env.server.files["/retorno/cob0310.ret"] = []byte("retorno")
+	env.server.files["/retorno/cob0311.ret.part"] = []byte("em gravação")
+	env.server.files["/retorno/leiame.txt"] = []byte("outro arquivo")
+
+	_, err = env.service.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"001-1234/cob0310.ret"}, env.imported)
+	assert.Contains(t, env.server.files, "/retorno/processados/cob0310.ret")
+	assert.NotContains(t, env.server.files, "/retorno/cob0310.ret")
+	assert.Contains(t, env.server.files, "/retorno/leiame.txt")
+	assert.Equal(t, models.RunSucceeded, env.repo.runs[0].Status)
+	assert.Contains(t, env.repo.runs[0].Log, "importado cob0310.ret: extrato com 1 lançamento")
+}
+
+func TestFailedRunsAreRetriedWithBackoff(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	endpoint, err := env.service.CreateEndpoint(ctx, sftpInput(t))
+	require.NoError(t, err)
+	schedule, err := env.service.CreateSchedule(ctx, models.ScheduleInput{EndpointID: endpoint.ID, Name: "Retorno",
+		Kind: models.KindCNABReturn, BankAccount: "001-1234", IntervalMinutes: 5, MaxAttempts: 2}, "admin")
+	require.NoError(t, err)
+	env.server.files["/retorno/a.ret"] = []byte("retorno")
+	env.server.files["/retorno/b.ret"] = []byte("corrompido")
+
+	_, err = env.service.RunPending(ctx)
+	require.NoError(t, err)
+	run := env.repo.runs[0]
+	assert.Equal(t, models.RunQueued, run.Status)
+	assert.Equal(t, 1, run.Attempts)
+	assert.Equal(t, env.clock.Add(time.Minute), run.NextAttemptAt)
+	assert.Contains(t, run.Error, "1 de 2 arquivo(s) com falha")
+	// O arquivo importado foi arquivado; o com falha fica para a próxima tentativa
+	assert.Contains(t, env.server.files, "/retorno/processados/a.ret")
+	assert.Contains(t, env.server.files, "/retorno/b.ret")
+	assert.Contains(t, env.repo.schedules[schedule.ID].LastError, "com falha")
+
+	// A rotina vence de novo antes da tentativa, mas a execução pendente não é duplicada
+	env.clock = env.clock.Add(5 * time.Minute)
+	env.server.dialErr = fmt.Errorf("connection refused")
+	_, err = env.service.RunPending(ctx)
+	require.NoError(t, err)
+	require.Len(t, env.repo.runs, 1)
+	run = env.repo.runs[0]
+	assert.Equal(t, models.RunFailed, run.Status)
+	assert.Equal(t, 2, run.Attempts)
+	assert.NotNil(t, run.FinishedAt)
+
+	_, err = env.service.RunNow(ctx, schedule.ID, "admin")
+	require.NoError(t, err)
+	_, err = env.service.RunNow(ctx, schedule.ID, "admin")
+	assert.ErrorIs(t, err, errors.ErrFileExchangeRunPending)
+	_, err = env.service.RetryRun(ctx, run.ID, "admin")
+	assert.ErrorIs(t, err, errors.ErrFileExchangeRunPending)
+
+	// Com o servidor de volta e o arquivo corrigido, a execução manual conclui e a com falha
+	// pode voltar à fila
+	env.server.dialErr = nil
+	env.server.files["/retorno/b.ret"] = []byte("retorno")
+	_, err = env.service.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.RunSucceeded, env.repo.runs[1].Status)
+	assert.Equal(t, models.TriggerManual, env.repo.runs[1].Trigger)
+
+	retried, err := env.service.RetryRun(ctx, run.ID, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, models.RunQueued, retried.Status)
+	assert.Equal(t, 4, retried.MaxAttempts)
+	assert.Contains(t, retried.Log, "devolvida à fila por admin")
+	_, err = env.service.RetryRun(ctx, env.repo.runs[1].ID, "admin")
+	assert.ErrorIs(t, err, errors.ErrFileExchangeRunNotFailed)
+}
+
+func TestInactiveEndpointFailsRun(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	input := sftpInput(t)
+	endpoint, err := env.service.CreateEndpoint(ctx, input)
+	require.NoError(t, err)
+	schedule, err := env.service.CreateSchedule(ctx, models.ScheduleInput{EndpointID: endpoint.ID, Name: "Preços",
+		Kind: models.KindPriceList, IntervalMinutes: 1440, MaxAttempts: 1}, "admin")
+	require.NoError(t, err)
+
+	inactive := false
+	input.Active = &inactive
+	_, err = env.service.UpdateEndpoint(ctx, endpoint.ID, input)
+	require.NoError(t, err)
+
+	_, err = env.service.RunPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.RunFailed, env.repo.runs[0].Status)
+	assert.Equal(t, errors.ErrFileEndpointInactive.Error(), env.repo.runs[0].Error)
+	assert.Empty(t, env.server.dialed)
+	assert.Nil(t, env.repo.schedules[schedule.ID].LastSuccessAt)
+}
+
+func TestPushPriceList(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	endpoint, err := env.service.CreateEndpoint(ctx, sftpInput(t))
+	require.NoError(t, err)
+	_, err = env.service.CreateSchedule(ctx, models.ScheduleInput{EndpointID: endpoint.ID, Name: "Preços",
+		Kind: models.KindPriceList, IntervalMinutes: 1440}, "admin")
+	require.NoError(t, err)
+
+	_, err = env.service.RunPending(ctx)
+	require.NoError(t, err)
+	content := string(env.server.files["/remessa/price-list-20300310.csv"])
+	assert.Equal(t, "sku;barcode;name;currency;price;stock\n"+
+		"TEC-01;;Teclado;BRL;99.90;7\n"+
+		"MOU-01;;\"Mouse; sem fio\";BRL;45.00;0\n", content)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Minute, retryDelay(1))
+	assert.Equal(t, 2*time.Minute, retryDelay(2))
+	assert.Equal(t, 8*time.Minute, retryDelay(4))
+	assert.Equal(t, time.Hour, retryDelay(10))
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as linhas do orçamento mensal
+// @Security BearerAuth
+// @Param year query int false "Ano do orçamento"
+// @Param cost_center_id query int false "ID do centro de custo"
+// @Param account_id query int false "ID da conta contábil"
+func ListBudgetsHandler(c *gin.Context) {
+	var filter models.BudgetFilter
+	if value := c.Query("year"); value != "" {
+		year, err := strconv.Atoi(value)
+		if err != nil || year < 1 || year > 9999 {
+			c.Error(errors.InvalidParam("year inválido"))
+			return
+		}
+		filter.From = strconv.Itoa(year) + "-01"
+		filter.To = strconv.Itoa(year) + "-12"
+	}
+	if value := c.Query("cost_center_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("cost_center_id inválido"))
+			return
+		}
+		filter.CostCenterID = &id
+	}
+	if value := c.Query("account_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("account_id inválido"))
+			return
+		}
+		filter.AccountID = id
+	}
+
+	budgets, err := service.ListBudgets(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar orçamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"budgets": budgets})
+}
+
+// Cadastra o valor orçado de uma conta de receita ou despesa no mês, opcionalmente por centro
+// de custo
+// @Security BearerAuth
+func CreateBudgetHandler(c *gin.Context) {
+	var input models.BudgetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	budget, err := service.CreateBudget(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar linha do orçamento")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"budget": budget})
+}
+
+// Altera uma linha do orçamento
+// @Security BearerAuth
+// @Param id path int true "ID da linha do orçamento"
+func UpdateBudgetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.BudgetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	budget, err := service.UpdateBudget(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar linha do orçamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"budget": budget})
+}
+
+// Remove uma linha do orçamento
+// @Security BearerAuth
+// @Param id path int true "ID da linha do orçamento"
+func DeleteBudgetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteBudget(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover linha do orçamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Linha do orçamento removida com sucesso"})
+}
+
+// Compara o orçado com o realizado das faturas (receita de vendas) e contas a pagar (compras) por
+// centro de custo, conta e mês, com a variação percentual e as linhas acima do orçamento
+// @Security BearerAuth
+// @Param from query string false "Mês inicial (AAAA-MM, padrão janeiro do ano corrente)"
+// @Param to query string false "Mês final (AAAA-MM, padrão dezembro do ano corrente)"
+// @Param cost_center query string false "Código do centro de custo"
+func GetBudgetVarianceHandler(c *gin.Context) {
+	report, err := service.GetBudgetVariance(c.Request.Context(), c.Query("from"), c.Query("to"), c.Query("cost_center"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao comparar orçado e realizado")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variance": report})
+}
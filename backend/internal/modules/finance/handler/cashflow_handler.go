@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/service"
+	"ERP-ONSMART/backend/internal/money"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Projeta o fluxo de caixa em semanas: entradas (faturas em aberto pelo vencimento, cobranças
+// recorrentes das locações) contra saídas (contas a pagar, pedidos de compra sem conta lançada e
+// lançamentos previstos como a folha de pagamento), com saldo acumulado por semana
+// @Security BearerAuth
+// @Param weeks query int false "Semanas projetadas (padrão 12, máximo 52)"
+// @Param opening_balance query number false "Saldo inicial do caixa (padrão 0)"
+// @Param details query bool false "Inclui os lançamentos de cada semana"
+func GetCashflowHandler(c *gin.Context) {
+	options := service.ProjectionOptions{Weeks: models.DefaultWeeks}
+	if value := c.Query("weeks"); value != "" {
+		weeks, err := strconv.Atoi(value)
+		if err != nil || weeks <= 0 || weeks > models.MaxWeeks {
+			c.Error(errors.InvalidParam("weeks deve ser um número inteiro entre 1 e 52"))
+			return
+		}
+		options.Weeks = weeks
+	}
+	if value := c.Query("opening_balance"); value != "" {
+		balance, err := money.Parse(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("opening_balance inválido"))
+			return
+		}
+		options.OpeningBalance = balance
+	}
+	if value := c.Query("details"); value != "" {
+		detailed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("details deve ser true ou false"))
+			return
+		}
+		options.Detailed = detailed
+	}
+
+	projection, err := service.ProjectCashflow(c.Request.Context(), options)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao projetar fluxo de caixa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cashflow": projection})
+}
+
+// Lista os lançamentos previstos (folha de pagamento, aluguel, impostos) usados na projeção
+// @Security BearerAuth
+func ListCashflowItemsHandler(c *gin.Context) {
+	items, err := service.ListPlannedItems(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar lançamentos previstos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// Cadastra um lançamento previsto de entrada ou saída, único, semanal ou mensal
+// @Security BearerAuth
+func CreateCashflowItemHandler(c *gin.Context) {
+	var input models.PlannedItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	item, err := service.CreatePlannedItem(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar lançamento previsto")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"item": item})
+}
+
+// Remove um lançamento previsto
+// @Security BearerAuth
+// @Param id path int true "ID do lançamento previsto"
+func DeleteCashflowItemHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeletePlannedItem(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover lançamento previsto")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lançamento previsto removido com sucesso"})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
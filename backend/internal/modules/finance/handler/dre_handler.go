@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/service"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+// Apura a DRE do período a partir dos lançamentos contábeis: receita bruta, deduções, custo das
+// mercadorias vendidas, despesas operacionais, resultado financeiro e resultado líquido.
+// format=xlsx devolve a planilha para download.
+// @Security BearerAuth
+// @Param from query string false "Data inicial (AAAA-MM-DD, padrão início do ano)"
+// @Param to query string false "Data final (AAAA-MM-DD, padrão hoje)"
+// @Param cost_center query string false "Código do centro de custo"
+// @Param format query string false "json (padrão) ou xlsx"
+func GetDREHandler(c *gin.Context) {
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return
+	}
+	costCenter := c.Query("cost_center")
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		dre, err := service.GetDRE(c.Request.Context(), from, to, costCenter)
+		if err != nil {
+			c.Error(err).SetMeta("erro ao apurar DRE")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"dre": dre})
+	case "xlsx":
+		output, err := service.ExportDRE(c.Request.Context(), from, to, costCenter)
+		if err != nil {
+			c.Error(err).SetMeta("erro ao exportar DRE")
+			return
+		}
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": output.FileName}))
+		c.Data(http.StatusOK, output.ContentType, output.Data)
+	default:
+		c.Error(errors.InvalidParam("format deve ser json ou xlsx"))
+	}
+}
+
+// Lista os centros de custo da empresa
+// @Security BearerAuth
+func ListCostCentersHandler(c *gin.Context) {
+	centers, err := service.ListCostCenters(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar centros de custo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cost_centers": centers})
+}
+
+// Cadastra um centro de custo; o código é único na empresa
+// @Security BearerAuth
+func CreateCostCenterHandler(c *gin.Context) {
+	var input models.CostCenterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	center, err := service.CreateCostCenter(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar centro de custo")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"cost_center": center})
+}
+
+// Altera código, nome e situação de um centro de custo. Centros inativos continuam na DRE, mas
+// não recebem novos documentos.
+// @Security BearerAuth
+// @Param id path int true "ID do centro de custo"
+func UpdateCostCenterHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.CostCenterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	center, err := service.UpdateCostCenter(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar centro de custo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cost_center": center})
+}
+
+// Classifica uma fatura, nota de crédito ou conta a pagar no centro de custo, junto com os
+// lançamentos contábeis já gerados a partir dela; cost_center_id nulo remove a classificação
+// @Security BearerAuth
+// @Param type path string true "invoice, credit_note ou supplier_bill"
+// @Param id path int true "ID do documento"
+func AssignCostCenterHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.AssignCostCenterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.AssignCostCenter(c.Request.Context(), c.Param("type"), id, input.CostCenterID); err != nil {
+		c.Error(err).SetMeta("erro ao classificar documento por centro de custo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Centro de custo do documento atualizado com sucesso"})
+}
+
+// parseDateQuery lê o parâmetro de data (AAAA-MM-DD); vazio resulta em data zero
+func parseDateQuery(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		c.Error(errors.InvalidParam(name + " inválido, use o formato AAAA-MM-DD"))
+		return time.Time{}, false
+	}
+	return date, true
+}
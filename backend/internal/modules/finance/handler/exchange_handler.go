@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista o histórico das cotações diárias em reais, do dia mais recente ao mais antigo
+// @Security BearerAuth
+// @Param currency query string false "Código da moeda (ex.: USD)"
+// @Param from query string false "Data inicial (AAAA-MM-DD)"
+// @Param to query string false "Data final (AAAA-MM-DD)"
+func ListExchangeRatesHandler(c *gin.Context) {
+	rates, err := service.ListExchangeRates(c.Request.Context(), c.Query("currency"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar cotações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rates": rates})
+}
+
+// Busca a cotação da moeda válida na data do documento: a do próprio dia ou, em fins de semana e
+// feriados, a do último dia anterior com cotação
+// @Security BearerAuth
+// @Param currency query string true "Código da moeda (ex.: USD)"
+// @Param date query string true "Data do documento (AAAA-MM-DD)"
+func LookupExchangeRateHandler(c *gin.Context) {
+	rate, err := service.LookupExchangeRate(c.Request.Context(), c.Query("currency"), c.Query("date"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar cotação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rate": rate})
+}
+
+// Informa manualmente a cotação da moeda no dia; ela prevalece sobre a consultada pelo job
+// @Security BearerAuth
+func SetExchangeRateHandler(c *gin.Context) {
+	var input models.ExchangeRateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	rate, err := service.SetExchangeRate(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar cotação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rate": rate})
+}
+
+// Consulta agora as cotações que faltam das moedas configuradas, sem esperar o job
+// @Security BearerAuth
+func FetchExchangeRatesHandler(c *gin.Context) {
+	rates, err := service.FetchExchangeRates(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao consultar cotações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rates": rates})
+}
+
+// Retorna a moeda estrangeira da fatura, com o valor na moeda e a cotação da emissão
+// @Security BearerAuth
+// @Param id path int true "ID da fatura"
+func GetInvoiceCurrencyHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	currency, err := service.GetInvoiceCurrency(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar moeda da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoice_currency": currency})
+}
+
+// Marca a fatura como emitida em moeda estrangeira; sem cotação informada, vale a da data de
+// emissão
+// @Security BearerAuth
+// @Param id path int true "ID da fatura"
+func SetInvoiceCurrencyHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.InvoiceCurrencyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	currency, err := service.SetInvoiceCurrency(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar moeda da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoice_currency": currency})
+}
+
+// Retorna a reavaliação cambial do mês das faturas em moeda estrangeira
+// @Security BearerAuth
+// @Param period path string true "Mês (AAAA-MM)"
+func GetRevaluationsHandler(c *gin.Context) {
+	run, err := service.GetRevaluations(c.Request.Context(), c.Param("period"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar reavaliação cambial")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revaluation": run})
+}
+
+// Reavalia pela cotação do fim do mês o saldo em aberto das faturas em moeda estrangeira;
+// refazer um mês substitui a reavaliação anterior
+// @Security BearerAuth
+// @Param period path string true "Mês encerrado (AAAA-MM)"
+func RevalueForeignInvoicesHandler(c *gin.Context) {
+	run, err := service.RevalueForeignInvoices(c.Request.Context(), c.Param("period"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao reavaliar faturas em moeda estrangeira")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revaluation": run})
+}
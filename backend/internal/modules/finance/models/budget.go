@@ -0,0 +1,234 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"time"
+
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// MonthLayout é o formato dos meses do orçamento (AAAA-MM)
+const MonthLayout = "2006-01"
+
+var monthPattern = regexp.MustCompile(`^[0-9]{4}-(0[1-9]|1[0-2])$`)
+
+// Budget é o valor orçado para uma conta de resultado em um mês, opcionalmente por centro de custo
+type Budget struct {
+	ID           int           `json:"id" gorm:"primaryKey"`
+	CompanyID    int           `json:"company_id" gorm:"<-:create"`
+	CostCenterID *int          `json:"cost_center_id,omitempty"`
+	AccountID    int           `json:"account_id"`
+	Month        string        `json:"month"`
+	Amount       money.Decimal `json:"amount"`
+	Notes        string        `json:"notes,omitempty"`
+	CreatedBy    string        `json:"created_by"`
+	UpdatedBy    string        `json:"updated_by"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Budget) TableName() string {
+	return "budgets"
+}
+
+// BudgetInput é o corpo aceito em POST e PUT /finance/budgets
+type BudgetInput struct {
+	CostCenterID *int          `json:"cost_center_id"`
+	AccountID    int           `json:"account_id" binding:"required"`
+	Month        string        `json:"month" binding:"required"`
+	Amount       money.Decimal `json:"amount" binding:"gte=0"`
+	Notes        string        `json:"notes"`
+}
+
+// BudgetFilter restringe a listagem do orçamento; campos vazios não filtram
+type BudgetFilter struct {
+	From         string
+	To           string
+	CostCenterID *int
+	AccountID    int
+}
+
+// ValidMonth indica se o mês está no formato AAAA-MM
+func ValidMonth(month string) bool {
+	return monthPattern.MatchString(month)
+}
+
+// MonthBounds converte o intervalo de meses (inclusivo) nas datas de início e de fim
+// (exclusiva) usadas nas consultas do realizado
+func MonthBounds(from, to string) (time.Time, time.Time, error) {
+	start, err := time.Parse(MonthLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse(MonthLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end.AddDate(0, 1, 0), nil
+}
+
+// BudgetActual é o valor realizado de uma conta em um mês, por centro de custo. O repositório
+// informa a origem (fatura ou conta a pagar) e o serviço resolve a conta pelos mapeamentos da
+// contabilização automática.
+type BudgetActual struct {
+	Source       string
+	CostCenterID *int
+	AccountID    int
+	Month        string
+	Amount       money.Decimal
+}
+
+// BudgetAccount identifica a conta de uma linha do relatório de variação
+type BudgetAccount struct {
+	ID   int
+	Code string
+	Name string
+	Type string
+}
+
+// VarianceLine compara orçado e realizado de uma conta e centro de custo, no mês ou no período
+// (Month vazio). A variação é realizado menos orçado; o percentual fica vazio sem orçamento.
+// OverBudget marca o realizado acima do orçado e Favorable indica se o desvio é bom: receita
+// acima ou despesa abaixo do orçamento.
+type VarianceLine struct {
+	CostCenterID       *int          `json:"cost_center_id,omitempty"`
+	CostCenterCode     string        `json:"cost_center_code,omitempty"`
+	AccountID          int           `json:"account_id"`
+	AccountCode        string        `json:"account_code"`
+	AccountName        string        `json:"account_name"`
+	AccountType        string        `json:"account_type"`
+	Month              string        `json:"month,omitempty"`
+	Budgeted           money.Decimal `json:"budgeted"`
+	Actual             money.Decimal `json:"actual"`
+	Variance           money.Decimal `json:"variance"`
+	VariancePercentage *float64      `json:"variance_percentage,omitempty"`
+	OverBudget         bool          `json:"over_budget"`
+	Favorable          bool          `json:"favorable"`
+}
+
+// VarianceReport é o relatório de orçado contra realizado no intervalo de meses
+type VarianceReport struct {
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	CostCenter *CostCenter    `json:"cost_center,omitempty"`
+	Lines      []VarianceLine `json:"lines"`
+	Totals     []VarianceLine `json:"totals"`
+	OverBudget int            `json:"over_budget_lines"`
+}
+
+type varianceKey struct {
+	costCenterID int
+	accountID    int
+	month        string
+}
+
+// BuildVariance cruza orçamento e realizado por centro de custo, conta e mês, e totaliza o
+// período por centro de custo e conta. Realizado sem orçamento aparece com orçado zero.
+func BuildVariance(budgets []Budget, actuals []BudgetActual, accounts map[int]BudgetAccount, centers map[int]CostCenter, from, to string) *VarianceReport {
+	lines := map[varianceKey]*VarianceLine{}
+	line := func(costCenterID *int, accountID int, month string) *VarianceLine {
+		key := varianceKey{costCenterKey(costCenterID), accountID, month}
+		if existing, ok := lines[key]; ok {
+			return existing
+		}
+		account := accounts[accountID]
+		created := &VarianceLine{
+			CostCenterID: costCenterID,
+			AccountID:    accountID,
+			AccountCode:  account.Code,
+			AccountName:  account.Name,
+			AccountType:  account.Type,
+			Month:        month,
+		}
+		if costCenterID != nil {
+			created.CostCenterCode = centers[*costCenterID].Code
+		}
+		lines[key] = created
+		return created
+	}
+
+	for _, budget := range budgets {
+		l := line(budget.CostCenterID, budget.AccountID, budget.Month)
+		l.Budgeted = l.Budgeted.Add(budget.Amount)
+	}
+	for _, actual := range actuals {
+		l := line(actual.CostCenterID, actual.AccountID, actual.Month)
+		l.Actual = l.Actual.Add(actual.Amount)
+	}
+
+	report := &VarianceReport{From: from, To: to, Lines: []VarianceLine{}, Totals: []VarianceLine{}}
+	totals := map[varianceKey]*VarianceLine{}
+	for key, l := range lines {
+		report.Lines = append(report.Lines, *l)
+
+		totalKey := varianceKey{key.costCenterID, key.accountID, ""}
+		total, ok := totals[totalKey]
+		if !ok {
+			copied := *l
+			copied.Month, copied.Budgeted, copied.Actual = "", money.Zero, money.Zero
+			total = &copied
+			totals[totalKey] = total
+		}
+		total.Budgeted = total.Budgeted.Add(l.Budgeted)
+		total.Actual = total.Actual.Add(l.Actual)
+	}
+	for _, total := range totals {
+		report.Totals = append(report.Totals, *total)
+	}
+
+	for i := range report.Lines {
+		finishVariance(&report.Lines[i])
+		if report.Lines[i].OverBudget {
+			report.OverBudget++
+		}
+	}
+	for i := range report.Totals {
+		finishVariance(&report.Totals[i])
+	}
+	sortVariance(report.Lines)
+	sortVariance(report.Totals)
+	return report
+}
+
+// finishVariance calcula a variação, o percentual e os indicadores da linha
+func finishVariance(l *VarianceLine) {
+	l.Budgeted = money.Round(l.Budgeted)
+	l.Actual = money.Round(l.Actual)
+	l.Variance = l.Actual.Sub(l.Budgeted)
+	if !l.Budgeted.IsZero() {
+		percentage := round2(l.Variance.Float64() / l.Budgeted.Float64() * 100)
+		l.VariancePercentage = &percentage
+	}
+	l.OverBudget = l.Actual.GreaterThan(l.Budgeted)
+	if l.AccountType == accounting.AccountTypeRevenue {
+		l.Favorable = l.Actual.GreaterThanOrEqual(l.Budgeted)
+	} else {
+		l.Favorable = l.Actual.LessThanOrEqual(l.Budgeted)
+	}
+}
+
+func sortVariance(lines []VarianceLine) {
+	sort.Slice(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if a.CostCenterCode != b.CostCenterCode {
+			return a.CostCenterCode < b.CostCenterCode
+		}
+		if a.AccountCode != b.AccountCode {
+			return a.AccountCode < b.AccountCode
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.Month < b.Month
+	})
+}
+
+// costCenterKey usa zero para as linhas sem centro de custo, como o índice único do orçamento
+func costCenterKey(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
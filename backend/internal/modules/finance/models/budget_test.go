@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildVariance(t *testing.T) {
+	sales := 4
+	accounts := map[int]BudgetAccount{
+		10: {ID: 10, Code: "3.1", Name: "Receita de vendas", Type: accounting.AccountTypeRevenue},
+		20: {ID: 20, Code: "4.1", Name: "Compras", Type: accounting.AccountTypeExpense},
+	}
+	centers := map[int]CostCenter{4: {ID: 4, Code: "VEN"}}
+	budgets := []Budget{
+		{AccountID: 10, CostCenterID: &sales, Month: "2026-01", Amount: money.FromInt(1000)},
+		{AccountID: 10, CostCenterID: &sales, Month: "2026-02", Amount: money.FromInt(1000)},
+		{AccountID: 20, Month: "2026-01", Amount: money.FromInt(500)},
+	}
+	actuals := []BudgetActual{
+		{AccountID: 10, CostCenterID: &sales, Month: "2026-01", Amount: money.FromInt(1200)},
+		{AccountID: 10, CostCenterID: &sales, Month: "2026-02", Amount: money.FromInt(700)},
+		{AccountID: 20, Month: "2026-01", Amount: money.FromInt(450)},
+		{AccountID: 20, Month: "2026-01", Amount: money.FromInt(150)},
+		{AccountID: 20, Month: "2026-02", Amount: money.FromInt(80)},
+	}
+
+	report := BuildVariance(budgets, actuals, accounts, centers, "2026-01", "2026-02")
+	require.Len(t, report.Lines, 4)
+
+	expense := report.Lines[0]
+	assert.Equal(t, "2026-01", expense.Month)
+	assert.Equal(t, "600.00", expense.Actual.String(), "realizado somado por mês")
+	assert.Equal(t, "100.00", expense.Variance.String())
+	require.NotNil(t, expense.VariancePercentage)
+	assert.Equal(t, 20.0, *expense.VariancePercentage)
+	assert.True(t, expense.OverBudget)
+	assert.False(t, expense.Favorable, "despesa acima do orçamento")
+
+	unbudgeted := report.Lines[1]
+	assert.Equal(t, "2026-02", unbudgeted.Month)
+	assert.Equal(t, "0.00", unbudgeted.Budgeted.String())
+	assert.Nil(t, unbudgeted.VariancePercentage, "sem orçamento não há percentual")
+	assert.True(t, unbudgeted.OverBudget)
+
+	revenue := report.Lines[2]
+	assert.Equal(t, "VEN", revenue.CostCenterCode)
+	assert.Equal(t, "200.00", revenue.Variance.String())
+	assert.True(t, revenue.Favorable, "receita acima do orçamento")
+
+	short := report.Lines[3]
+	assert.Equal(t, -30.0, *short.VariancePercentage)
+	assert.False(t, short.OverBudget)
+	assert.False(t, short.Favorable)
+	assert.Equal(t, 3, report.OverBudget)
+
+	require.Len(t, report.Totals, 2)
+	assert.Equal(t, "", report.Totals[0].Month)
+	assert.Equal(t, "500.00", report.Totals[0].Budgeted.String())
+	assert.Equal(t, "680.00", report.Totals[0].Actual.String())
+	assert.Equal(t, "2000.00", report.Totals[1].Budgeted.String())
+	assert.Equal(t, "1900.00", report.Totals[1].Actual.String())
+	assert.Equal(t, -5.0, *report.Totals[1].VariancePercentage)
+}
+
+func TestMonthBounds(t *testing.T) {
+	assert.True(t, ValidMonth("2026-12"))
+	assert.False(t, ValidMonth("2026-13"))
+	assert.False(t, ValidMonth("2026-1"))
+
+	from, to, err := MonthBounds("2026-01", "2026-12")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), to, "fim exclusivo")
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// Sentido do lançamento no caixa
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+// Origens dos lançamentos projetados
+const (
+	SourceInvoice       = "invoice"
+	SourceRental        = "rental"
+	SourceSupplierBill  = "supplier_bill"
+	SourcePurchaseOrder = "purchase_order"
+	SourcePlanned       = "planned"
+	SourceExpense       = "expense"
+)
+
+// Frequências dos lançamentos previstos
+const (
+	FrequencyOnce    = "once"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Limites do horizonte da projeção, em semanas
+const (
+	DefaultWeeks = 12
+	MaxWeeks     = 52
+)
+
+// Entry é um lançamento esperado no caixa: o saldo em aberto de um documento ou uma ocorrência
+// de cobrança recorrente ou de lançamento previsto
+type Entry struct {
+	Direction   string        `json:"direction"`
+	Source      string        `json:"source"`
+	SourceID    int           `json:"source_id,omitempty"`
+	Description string        `json:"description"`
+	Date        time.Time     `json:"date"`
+	Amount      money.Decimal `json:"amount"`
+}
+
+// Bucket consolida os lançamentos de uma semana (segunda a domingo)
+type Bucket struct {
+	WeekStart        time.Time                `json:"week_start"`
+	WeekEnd          time.Time                `json:"week_end"`
+	Inflows          money.Decimal            `json:"inflows"`
+	Outflows         money.Decimal            `json:"outflows"`
+	InflowsBySource  map[string]money.Decimal `json:"inflows_by_source"`
+	OutflowsBySource map[string]money.Decimal `json:"outflows_by_source"`
+	Net              money.Decimal            `json:"net"`
+	Balance          money.Decimal            `json:"balance"`
+	Shortfall        bool                     `json:"shortfall"`
+	Entries          []Entry                  `json:"entries,omitempty"`
+}
+
+// Projection é o fluxo de caixa projetado semana a semana, com saldo acumulado
+type Projection struct {
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	Weeks          int           `json:"weeks"`
+	OpeningBalance money.Decimal `json:"opening_balance"`
+	TotalInflows   money.Decimal `json:"total_inflows"`
+	TotalOutflows  money.Decimal `json:"total_outflows"`
+	ClosingBalance money.Decimal `json:"closing_balance"`
+	LowestBalance  money.Decimal `json:"lowest_balance"`
+	FirstShortfall *time.Time    `json:"first_shortfall,omitempty"`
+	Buckets        []Bucket      `json:"buckets"`
+}
+
+// WeekStart retorna a segunda-feira da semana da data, à meia-noite
+func WeekStart(date time.Time) time.Time {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	offset := (int(day.Weekday()) - int(time.Monday) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// Project distribui os lançamentos em semanas a partir da semana de from. Lançamentos vencidos
+// (anteriores à primeira semana) entram na primeira semana e os posteriores ao horizonte são
+// ignorados. A semana com saldo acumulado negativo é marcada como shortfall.
+func Project(entries []Entry, from time.Time, weeks int, openingBalance money.Decimal, detailed bool) *Projection {
+	start := WeekStart(from)
+	end := start.AddDate(0, 0, 7*weeks)
+
+	buckets := make([]Bucket, weeks)
+	for i := range buckets {
+		weekStart := start.AddDate(0, 0, 7*i)
+		buckets[i] = Bucket{
+			WeekStart:        weekStart,
+			WeekEnd:          weekStart.AddDate(0, 0, 6),
+			InflowsBySource:  map[string]money.Decimal{},
+			OutflowsBySource: map[string]money.Decimal{},
+		}
+	}
+
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	for _, entry := range sorted {
+		if weeks == 0 || !entry.Amount.IsPositive() || !entry.Date.Before(end) {
+			continue
+		}
+		index := 0
+		for index < weeks-1 && !entry.Date.Before(buckets[index+1].WeekStart) {
+			index++
+		}
+		bucket := &buckets[index]
+		if entry.Direction == DirectionOut {
+			bucket.Outflows = bucket.Outflows.Add(entry.Amount)
+			bucket.OutflowsBySource[entry.Source] = bucket.OutflowsBySource[entry.Source].Add(entry.Amount)
+		} else {
+			bucket.Inflows = bucket.Inflows.Add(entry.Amount)
+			bucket.InflowsBySource[entry.Source] = bucket.InflowsBySource[entry.Source].Add(entry.Amount)
+		}
+		if detailed {
+			bucket.Entries = append(bucket.Entries, entry)
+		}
+	}
+
+	projection := &Projection{
+		From:           start,
+		To:             end.AddDate(0, 0, -1),
+		Weeks:          weeks,
+		OpeningBalance: money.Round(openingBalance),
+		LowestBalance:  money.Round(openingBalance),
+		Buckets:        buckets,
+	}
+	balance := projection.OpeningBalance
+	for i := range buckets {
+		bucket := &buckets[i]
+		bucket.Inflows = money.Round(bucket.Inflows)
+		bucket.Outflows = money.Round(bucket.Outflows)
+		roundValues(bucket.InflowsBySource)
+		roundValues(bucket.OutflowsBySource)
+		bucket.Net = bucket.Inflows.Sub(bucket.Outflows)
+		balance = balance.Add(bucket.Net)
+		bucket.Balance = balance
+		bucket.Shortfall = balance.IsNegative()
+
+		projection.TotalInflows = projection.TotalInflows.Add(bucket.Inflows)
+		projection.TotalOutflows = projection.TotalOutflows.Add(bucket.Outflows)
+		if balance.LessThan(projection.LowestBalance) {
+			projection.LowestBalance = balance
+		}
+		if bucket.Shortfall && projection.FirstShortfall == nil {
+			weekStart := bucket.WeekStart
+			projection.FirstShortfall = &weekStart
+		}
+	}
+	projection.ClosingBalance = balance
+	return projection
+}
+
+// billingIntervals traduz o tipo de cobrança das locações para o intervalo em meses
+var billingIntervals = map[string]int{
+	"mensal":     1,
+	"bimestral":  2,
+	"trimestral": 3,
+	"semestral":  6,
+	"anual":      12,
+}
+
+// RecurringBilling é uma locação ativa, cobrada periodicamente a partir da data de início
+type RecurringBilling struct {
+	ID          int
+	ClientName  string
+	Equipment   string
+	StartDate   time.Time
+	EndDate     time.Time
+	Price       money.Decimal
+	BillingType string
+}
+
+// Entries gera as cobranças da locação entre from e to (exclusivo). Cada período começa na data
+// de início; tipos de cobrança sem periodicidade conhecida geram uma única cobrança no início.
+func (r RecurringBilling) Entries(from, to time.Time) []Entry {
+	description := strings.TrimSpace(r.ClientName + " - " + r.Equipment)
+	months, recurring := billingIntervals[strings.ToLower(strings.TrimSpace(r.BillingType))]
+	if !recurring {
+		if r.StartDate.Before(from) || !r.StartDate.Before(to) {
+			return nil
+		}
+		return []Entry{r.entry(r.StartDate, description)}
+	}
+
+	var entries []Entry
+	for i := 0; ; i++ {
+		date := addMonths(r.StartDate, months*i)
+		if !date.Before(to) || (!r.EndDate.IsZero() && date.After(r.EndDate)) {
+			break
+		}
+		if !date.Before(from) {
+			entries = append(entries, r.entry(date, description))
+		}
+	}
+	return entries
+}
+
+func (r RecurringBilling) entry(date time.Time, description string) Entry {
+	return Entry{
+		Direction:   DirectionIn,
+		Source:      SourceRental,
+		SourceID:    r.ID,
+		Description: description,
+		Date:        date,
+		Amount:      r.Price,
+	}
+}
+
+// addMonths soma meses mantendo o dia, limitado ao último dia do mês de destino (31/01 + 1 mês
+// vira 28 ou 29/02, e não 03/03)
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, date.Location())
+}
+
+func roundValues(values map[string]money.Decimal) {
+	for key, value := range values {
+		values[key] = money.Round(value)
+	}
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
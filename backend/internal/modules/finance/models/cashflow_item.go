@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// PlannedItem é um lançamento previsto fora dos documentos do sistema (folha de pagamento,
+// aluguel, impostos), repetido na frequência informada entre a data de início e a de fim
+type PlannedItem struct {
+	ID        int           `json:"id" gorm:"primaryKey"`
+	CompanyID int           `json:"company_id" gorm:"<-:create"`
+	Name      string        `json:"name"`
+	Category  string        `json:"category"`
+	Direction string        `json:"direction"`
+	Amount    money.Decimal `json:"amount"`
+	Frequency string        `json:"frequency"`
+	StartDate time.Time     `json:"start_date"`
+	EndDate   *time.Time    `json:"end_date,omitempty"`
+	CreatedBy string        `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (PlannedItem) TableName() string {
+	return "cashflow_items"
+}
+
+// PlannedItemInput é o corpo aceito em POST /finance/cashflow/items
+type PlannedItemInput struct {
+	Name      string        `json:"name" binding:"required,max=150"`
+	Category  string        `json:"category" binding:"omitempty,max=50"`
+	Direction string        `json:"direction" binding:"required,oneof=in out"`
+	Amount    money.Decimal `json:"amount" binding:"required,gt=0"`
+	Frequency string        `json:"frequency" binding:"required,oneof=once weekly monthly"`
+	StartDate time.Time     `json:"start_date" binding:"required"`
+	EndDate   *time.Time    `json:"end_date"`
+}
+
+// Entries gera as ocorrências do lançamento previsto entre from e to (exclusivo)
+func (p PlannedItem) Entries(from, to time.Time) []Entry {
+	var entries []Entry
+	for i := 0; ; i++ {
+		var date time.Time
+		switch p.Frequency {
+		case FrequencyWeekly:
+			date = p.StartDate.AddDate(0, 0, 7*i)
+		case FrequencyMonthly:
+			date = addMonths(p.StartDate, i)
+		default:
+			if i > 0 {
+				return entries
+			}
+			date = p.StartDate
+		}
+		if !date.Before(to) || (p.EndDate != nil && date.After(*p.EndDate)) {
+			return entries
+		}
+		if !date.Before(from) {
+			entries = append(entries, Entry{
+				Direction:   p.Direction,
+				Source:      SourcePlanned,
+				SourceID:    p.ID,
+				Description: p.Name,
+				Date:        date,
+				Amount:      p.Amount,
+			})
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWeekStart(t *testing.T) {
+	assert.Equal(t, date(2026, 10, 12), WeekStart(time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC)))
+	assert.Equal(t, date(2026, 10, 12), WeekStart(date(2026, 10, 12)))
+	assert.Equal(t, date(2026, 10, 12), WeekStart(date(2026, 10, 18)), "domingo fecha a semana")
+}
+
+func TestProject(t *testing.T) {
+	// Quinta-feira, 15/10/2026: a primeira semana começa na segunda, 12/10
+	now := date(2026, 10, 15)
+	entries := []Entry{
+		{Direction: DirectionIn, Source: SourceInvoice, Date: date(2026, 9, 30), Amount: money.FromInt(300)},
+		{Direction: DirectionIn, Source: SourceRental, Date: date(2026, 10, 20), Amount: money.FromInt(1000)},
+		{Direction: DirectionOut, Source: SourceSupplierBill, Date: date(2026, 10, 13), Amount: money.MustParse("500.255")},
+		{Direction: DirectionOut, Source: SourcePlanned, Date: date(2026, 10, 26), Amount: money.FromInt(1200)},
+		{Direction: DirectionOut, Source: SourcePurchaseOrder, Date: date(2026, 11, 2), Amount: money.FromInt(9999)},
+	}
+
+	projection := Project(entries, now, 3, money.FromInt(100), false)
+	require.Len(t, projection.Buckets, 3)
+	assert.Equal(t, date(2026, 10, 12), projection.From)
+	assert.Equal(t, date(2026, 11, 1), projection.To)
+
+	first := projection.Buckets[0]
+	assert.Equal(t, date(2026, 10, 18), first.WeekEnd)
+	assert.Equal(t, "300.00", first.Inflows.String(), "fatura vencida entra na primeira semana")
+	assert.Equal(t, "500.26", first.Outflows.String())
+	assert.Equal(t, "-100.26", first.Balance.String())
+	assert.True(t, first.Shortfall)
+
+	second := projection.Buckets[1]
+	assert.Equal(t, "1000.00", second.InflowsBySource[SourceRental].String())
+	assert.Equal(t, "899.74", second.Balance.String())
+	assert.False(t, second.Shortfall)
+
+	third := projection.Buckets[2]
+	assert.Equal(t, "1200.00", third.OutflowsBySource[SourcePlanned].String())
+	assert.Equal(t, "-300.26", third.Balance.String())
+
+	assert.Equal(t, "1300.00", projection.TotalInflows.String())
+	assert.Equal(t, "1700.26", projection.TotalOutflows.String(), "pedido depois do horizonte fica de fora")
+	assert.Equal(t, "-300.26", projection.ClosingBalance.String())
+	assert.Equal(t, "-300.26", projection.LowestBalance.String())
+	require.NotNil(t, projection.FirstShortfall)
+	assert.Equal(t, date(2026, 10, 12), *projection.FirstShortfall)
+	assert.Empty(t, first.Entries)
+
+	detailed := Project(entries, now, 3, money.FromInt(100), true)
+	assert.Len(t, detailed.Buckets[0].Entries, 2)
+}
+
+func TestRecurringBillingEntries(t *testing.T) {
+	rental := RecurringBilling{
+		ID: 7, ClientName: "Empresa Teste", Equipment: "Notebook",
+		StartDate: date(2026, 1, 31), EndDate: date(2026, 12, 31),
+		Price: money.FromInt(1500), BillingType: "Mensal",
+	}
+
+	entries := rental.Entries(date(2026, 10, 12), date(2027, 1, 4))
+	require.Len(t, entries, 3)
+	assert.Equal(t, date(2026, 10, 31), entries[0].Date)
+	assert.Equal(t, date(2026, 11, 30), entries[1].Date, "dia limitado ao fim do mês")
+	assert.Equal(t, date(2026, 12, 31), entries[2].Date)
+	assert.Equal(t, DirectionIn, entries[0].Direction)
+	assert.Equal(t, SourceRental, entries[0].Source)
+	assert.Equal(t, "Empresa Teste - Notebook", entries[0].Description)
+
+	rental.BillingType = "anual"
+	assert.Empty(t, rental.Entries(date(2026, 10, 12), date(2027, 1, 4)), "próxima cobrança anual fora do contrato")
+
+	rental.BillingType = "avulsa"
+	rental.StartDate = date(2026, 10, 20)
+	assert.Len(t, rental.Entries(date(2026, 10, 12), date(2027, 1, 4)), 1)
+}
+
+func TestPlannedItemEntries(t *testing.T) {
+	end := date(2026, 11, 10)
+	payroll := PlannedItem{ID: 1, Name: "Folha", Direction: DirectionOut, Amount: money.FromInt(20000), Frequency: FrequencyMonthly, StartDate: date(2026, 1, 5), EndDate: &end}
+
+	entries := payroll.Entries(date(2026, 10, 12), date(2027, 1, 4))
+	require.Len(t, entries, 1)
+	assert.Equal(t, date(2026, 11, 5), entries[0].Date)
+	assert.Equal(t, SourcePlanned, entries[0].Source)
+
+	weekly := PlannedItem{Direction: DirectionOut, Amount: money.FromInt(100), Frequency: FrequencyWeekly, StartDate: date(2026, 10, 1)}
+	assert.Len(t, weekly.Entries(date(2026, 10, 12), date(2026, 11, 2)), 3)
+
+	once := PlannedItem{Direction: DirectionIn, Amount: money.FromInt(100), Frequency: FrequencyOnce, StartDate: date(2026, 10, 1)}
+	assert.Empty(t, once.Entries(date(2026, 10, 12), date(2026, 11, 2)))
+}
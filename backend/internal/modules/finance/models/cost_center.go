@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// CostCenter classifica documentos e lançamentos contábeis para a DRE por centro de custo
+type CostCenter struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (CostCenter) TableName() string {
+	return "cost_centers"
+}
+
+// CostCenterInput é o corpo aceito em POST e PUT /finance/cost-centers
+type CostCenterInput struct {
+	Code   string `json:"code" binding:"required,max=30"`
+	Name   string `json:"name" binding:"required,max=150"`
+	Active *bool  `json:"active"`
+}
+
+// AssignCostCenterInput é o corpo aceito em PUT /finance/documents/:type/:id/cost-center;
+// cost_center_id nulo remove a classificação
+type AssignCostCenterInput struct {
+	CostCenterID *int `json:"cost_center_id"`
+}
+
+// CostCenterDocuments são os documentos classificáveis por centro de custo, com a tabela de
+// cada um. O tipo é o mesmo source_type dos lançamentos contábeis gerados pelo documento.
+var CostCenterDocuments = map[string]string{
+	"invoice":       "invoices",
+	"credit_note":   "credit_notes",
+	"supplier_bill": "supplier_bills",
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/xlsx"
+)
+
+// AccountTotal soma débitos e créditos de uma conta no período da DRE
+type AccountTotal struct {
+	AccountID   int           `json:"account_id"`
+	AccountCode string        `json:"account_code"`
+	AccountName string        `json:"account_name"`
+	AccountType string        `json:"account_type"`
+	DREGroup    *string       `json:"dre_group,omitempty"`
+	Debit       money.Decimal `json:"debit"`
+	Credit      money.Decimal `json:"credit"`
+}
+
+// DREAccount é o valor de uma conta dentro de uma linha da DRE
+type DREAccount struct {
+	AccountID int           `json:"account_id"`
+	Code      string        `json:"code"`
+	Name      string        `json:"name"`
+	Amount    money.Decimal `json:"amount"`
+}
+
+// DRELine é uma linha da DRE com as contas que a compõem. Deduções, custos e despesas são
+// apresentados em valor positivo e subtraídos nos resultados.
+type DRELine struct {
+	Group    string        `json:"group"`
+	Label    string        `json:"label"`
+	Amount   money.Decimal `json:"amount"`
+	Accounts []DREAccount  `json:"accounts"`
+}
+
+// DRE é a demonstração do resultado do exercício no formato usual brasileiro
+type DRE struct {
+	From              time.Time     `json:"from"`
+	To                time.Time     `json:"to"`
+	CostCenter        *CostCenter   `json:"cost_center,omitempty"`
+	GrossRevenue      money.Decimal `json:"gross_revenue"`
+	Deductions        money.Decimal `json:"deductions"`
+	NetRevenue        money.Decimal `json:"net_revenue"`
+	COGS              money.Decimal `json:"cogs"`
+	GrossProfit       money.Decimal `json:"gross_profit"`
+	OperatingExpenses money.Decimal `json:"operating_expenses"`
+	OperatingResult   money.Decimal `json:"operating_result"`
+	FinancialResult   money.Decimal `json:"financial_result"`
+	NetResult         money.Decimal `json:"net_result"`
+	Lines             []DRELine     `json:"lines"`
+}
+
+// dreLines define a ordem e o rótulo das linhas da DRE
+var dreLines = []struct {
+	group string
+	label string
+}{
+	{accounting.DREGrossRevenue, "Receita operacional bruta"},
+	{accounting.DREDeductions, "(-) Deduções da receita"},
+	{accounting.DRECOGS, "(-) Custo das mercadorias vendidas"},
+	{accounting.DREOperatingExpenses, "(-) Despesas operacionais"},
+	{accounting.DREFinancialResult, "Resultado financeiro"},
+}
+
+// creditNature indica as linhas apresentadas pelo saldo credor (receitas); as demais usam o
+// saldo devedor
+var creditNature = map[string]bool{
+	accounting.DREGrossRevenue:    true,
+	accounting.DREFinancialResult: true,
+}
+
+// DREGroupFor define a linha da DRE da conta: a linha configurada na conta ou, sem ela, os
+// mapeamentos da contabilização automática (descontos e devoluções como deduções, compras como
+// custo) e o tipo da conta. Contas patrimoniais sem linha configurada ficam fora da DRE.
+func DREGroupFor(row AccountTotal, mappings map[string]int) string {
+	if row.DREGroup != nil && *row.DREGroup != "" {
+		return *row.DREGroup
+	}
+	// Mapeamentos ausentes valem zero, que não é ID de nenhuma conta
+	switch row.AccountID {
+	case mappings[accounting.MappingSalesDiscounts], mappings[accounting.MappingSalesReturns]:
+		return accounting.DREDeductions
+	case mappings[accounting.MappingPurchases]:
+		return accounting.DRECOGS
+	}
+	switch row.AccountType {
+	case accounting.AccountTypeRevenue:
+		return accounting.DREGrossRevenue
+	case accounting.AccountTypeExpense:
+		return accounting.DREOperatingExpenses
+	}
+	return ""
+}
+
+// BuildDRE distribui os saldos das contas nas linhas da DRE e apura os resultados
+func BuildDRE(rows []AccountTotal, mappings map[string]int, from, to time.Time) *DRE {
+	lines := make(map[string]*DRELine, len(dreLines))
+	dre := &DRE{From: from, To: to, Lines: make([]DRELine, len(dreLines))}
+	for i, line := range dreLines {
+		dre.Lines[i] = DRELine{Group: line.group, Label: line.label, Accounts: []DREAccount{}}
+		lines[line.group] = &dre.Lines[i]
+	}
+
+	for _, row := range rows {
+		group := DREGroupFor(row, mappings)
+		line, ok := lines[group]
+		if !ok {
+			continue
+		}
+		amount := money.Round(row.Debit.Sub(row.Credit))
+		if creditNature[group] {
+			amount = amount.Neg()
+		}
+		if amount.IsZero() {
+			continue
+		}
+		line.Accounts = append(line.Accounts, DREAccount{
+			AccountID: row.AccountID,
+			Code:      row.AccountCode,
+			Name:      row.AccountName,
+			Amount:    amount,
+		})
+		line.Amount = line.Amount.Add(amount)
+	}
+
+	dre.GrossRevenue = lines[accounting.DREGrossRevenue].Amount
+	dre.Deductions = lines[accounting.DREDeductions].Amount
+	dre.NetRevenue = dre.GrossRevenue.Sub(dre.Deductions)
+	dre.COGS = lines[accounting.DRECOGS].Amount
+	dre.GrossProfit = dre.NetRevenue.Sub(dre.COGS)
+	dre.OperatingExpenses = lines[accounting.DREOperatingExpenses].Amount
+	dre.OperatingResult = dre.GrossProfit.Sub(dre.OperatingExpenses)
+	dre.FinancialResult = lines[accounting.DREFinancialResult].Amount
+	dre.NetResult = dre.OperatingResult.Add(dre.FinancialResult)
+	return dre
+}
+
+// Output é o arquivo gerado por uma exportação
+type Output struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// RenderDRE gera a DRE em planilha XLSX: cada linha com suas contas e os resultados
+// intermediários. Deduções, custos e despesas saem negativos, como na apresentação usual.
+func RenderDRE(dre *DRE) (*Output, error) {
+	workbook := xlsx.NewWorkbook()
+	sheet := workbook.AddSheet("DRE")
+	sheet.SetColumnWidth(0, 55)
+	sheet.SetColumnWidth(1, 18)
+
+	sheet.AddRow(true, "Demonstração do Resultado do Exercício")
+	sheet.AddRow(false, fmt.Sprintf("Período: %s a %s", dre.From.Format("02/01/2006"), dre.To.Format("02/01/2006")))
+	if dre.CostCenter != nil {
+		sheet.AddRow(false, fmt.Sprintf("Centro de custo: %s - %s", dre.CostCenter.Code, dre.CostCenter.Name))
+	}
+	sheet.AddRow(false)
+	sheet.AddRow(true, "Descrição", "Valor")
+
+	subtotals := map[string][2]interface{}{
+		accounting.DREDeductions:        {"(=) Receita operacional líquida", dre.NetRevenue.Float64()},
+		accounting.DRECOGS:              {"(=) Lucro bruto", dre.GrossProfit.Float64()},
+		accounting.DREOperatingExpenses: {"(=) Resultado operacional", dre.OperatingResult.Float64()},
+		accounting.DREFinancialResult:   {"(=) Resultado líquido do período", dre.NetResult.Float64()},
+	}
+	for _, line := range dre.Lines {
+		sign := 1.0
+		if !creditNature[line.Group] {
+			sign = -1
+		}
+		sheet.AddRow(true, line.Label, signed(sign, line.Amount))
+		for _, account := range line.Accounts {
+			sheet.AddRow(false, "    "+account.Code+" - "+account.Name, signed(sign, account.Amount))
+		}
+		if subtotal, ok := subtotals[line.Group]; ok {
+			sheet.AddRow(true, subtotal[0], subtotal[1])
+		}
+	}
+
+	data, err := workbook.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("dre-%s-%s", dre.From.Format("20060102"), dre.To.Format("20060102"))
+	if dre.CostCenter != nil {
+		name += "-" + dre.CostCenter.Code
+	}
+	return &Output{
+		FileName:    name + ".xlsx",
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Data:        data,
+	}, nil
+}
+
+// signed evita o -0 nas linhas zeradas
+func signed(sign float64, amount money.Decimal) float64 {
+	if amount.IsZero() {
+		return 0
+	}
+	return sign * amount.Float64()
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDRE(t *testing.T) {
+	financial := accounting.DREFinancialResult
+	mappings := map[string]int{
+		accounting.MappingSalesRevenue:   10,
+		accounting.MappingSalesDiscounts: 11,
+		accounting.MappingSalesReturns:   12,
+		accounting.MappingPurchases:      20,
+	}
+	rows := []AccountTotal{
+		{AccountID: 1, AccountCode: "1.1", AccountName: "Caixa", AccountType: accounting.AccountTypeAsset, Debit: money.FromInt(5000)},
+		{AccountID: 10, AccountCode: "3.1", AccountName: "Receita de vendas", AccountType: accounting.AccountTypeRevenue, Credit: money.FromInt(10000)},
+		{AccountID: 11, AccountCode: "3.2", AccountName: "Descontos concedidos", AccountType: accounting.AccountTypeExpense, Debit: money.FromInt(300)},
+		{AccountID: 12, AccountCode: "3.3", AccountName: "Devoluções", AccountType: accounting.AccountTypeRevenue, Debit: money.FromInt(200)},
+		{AccountID: 20, AccountCode: "4.1", AccountName: "Compras", AccountType: accounting.AccountTypeExpense, Debit: money.FromInt(4000), Credit: money.FromInt(500)},
+		{AccountID: 21, AccountCode: "4.2", AccountName: "Salários", AccountType: accounting.AccountTypeExpense, Debit: money.FromInt(2000)},
+		{AccountID: 22, AccountCode: "4.3", AccountName: "Juros pagos", AccountType: accounting.AccountTypeExpense, DREGroup: &financial, Debit: money.FromInt(150)},
+		{AccountID: 23, AccountCode: "4.4", AccountName: "Aluguel", AccountType: accounting.AccountTypeExpense, Debit: money.FromInt(100), Credit: money.FromInt(100)},
+	}
+	from, to := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	dre := BuildDRE(rows, mappings, from, to)
+	assert.Equal(t, "10000.00", dre.GrossRevenue.String())
+	assert.Equal(t, "500.00", dre.Deductions.String())
+	assert.Equal(t, "9500.00", dre.NetRevenue.String())
+	assert.Equal(t, "3500.00", dre.COGS.String())
+	assert.Equal(t, "6000.00", dre.GrossProfit.String())
+	assert.Equal(t, "2000.00", dre.OperatingExpenses.String())
+	assert.Equal(t, "4000.00", dre.OperatingResult.String())
+	assert.Equal(t, "-150.00", dre.FinancialResult.String())
+	assert.Equal(t, "3850.00", dre.NetResult.String())
+
+	require.Len(t, dre.Lines, 5)
+	assert.Equal(t, accounting.DREGrossRevenue, dre.Lines[0].Group)
+	assert.Len(t, dre.Lines[1].Accounts, 2)
+	assert.Len(t, dre.Lines[3].Accounts, 1, "contas zeradas e patrimoniais ficam fora")
+	assert.Equal(t, "Salários", dre.Lines[3].Accounts[0].Name)
+}
+
+func TestRenderDRE(t *testing.T) {
+	dre := BuildDRE([]AccountTotal{
+		{AccountID: 10, AccountCode: "3.1", AccountName: "Receita de vendas", AccountType: accounting.AccountTypeRevenue, Credit: money.FromInt(1000)},
+		{AccountID: 21, AccountCode: "4.2", AccountName: "Salários", AccountType: accounting.AccountTypeExpense, Debit: money.FromInt(400)},
+	}, nil, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC))
+	dre.CostCenter = &CostCenter{Code: "ADM", Name: "Administrativo"}
+
+	output, err := RenderDRE(dre)
+	require.NoError(t, err)
+	assert.Equal(t, "dre-20260101-20260131-ADM.xlsx", output.FileName)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", output.ContentType)
+
+	reader, err := zip.NewReader(bytes.NewReader(output.Data), int64(len(output.Data)))
+	require.NoError(t, err)
+	var sheet bytes.Buffer
+	for _, file := range reader.File {
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := file.Open()
+			require.NoError(t, err)
+			_, err = sheet.ReadFrom(rc)
+			require.NoError(t, err)
+			rc.Close()
+		}
+	}
+	assert.Contains(t, sheet.String(), "Centro de custo: ADM - Administrativo")
+	assert.Contains(t, sheet.String(), "<v>-400</v>", "despesas saem negativas")
+	assert.Contains(t, sheet.String(), "(=) Resultado líquido do período")
+	assert.Contains(t, sheet.String(), "<v>600</v>")
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// ExchangeRate é a cotação da moeda no dia, em reais por unidade da moeda. Não tem empresa: as
+// cotações são públicas e valem para todas.
+type ExchangeRate struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	Currency  string    `json:"currency"`
+	RateDate  time.Time `json:"rate_date" gorm:"type:date"`
+	Rate      float64   `json:"rate"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// ExchangeRateInput é o corpo aceito em POST /finance/exchange-rates: a cotação informada
+// manualmente substitui a consultada para a moeda no dia
+type ExchangeRateInput struct {
+	Currency string  `json:"currency" binding:"required"`
+	Date     string  `json:"date" binding:"required"`
+	Rate     float64 `json:"rate" binding:"required,gt=0"`
+}
+
+// ExchangeRateFilter restringe o histórico de cotações; campos vazios não filtram
+type ExchangeRateFilter struct {
+	Currency string
+	From     time.Time
+	To       time.Time
+}
+
+// InvoiceCurrency marca a fatura como emitida em moeda estrangeira. Os valores da fatura seguem
+// em reais, convertidos pela cotação da data de emissão (IssueRate); ForeignAmount é o total a
+// receber na moeda.
+type InvoiceCurrency struct {
+	ID            int           `json:"id" gorm:"primaryKey"`
+	CompanyID     int           `json:"company_id" gorm:"<-:create"`
+	InvoiceID     int           `json:"invoice_id"`
+	Currency      string        `json:"currency"`
+	ForeignAmount money.Decimal `json:"foreign_amount"`
+	IssueRate     float64       `json:"issue_rate"`
+	RateDate      time.Time     `json:"rate_date" gorm:"type:date"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (InvoiceCurrency) TableName() string {
+	return "invoice_currencies"
+}
+
+// InvoiceCurrencyInput é o corpo aceito em PUT /finance/invoices/:id/currency; sem rate, vale a
+// cotação da moeda na data de emissão da fatura
+type InvoiceCurrencyInput struct {
+	Currency string   `json:"currency" binding:"required"`
+	Rate     *float64 `json:"rate" binding:"omitempty,gt=0"`
+}
+
+// InvoiceRevaluation é a reavaliação do saldo em aberto da fatura em moeda estrangeira pela
+// cotação do fim do mês. BookValue é o saldo pela cotação da avaliação anterior (a de emissão ou a
+// do último mês reavaliado) e Variance, a variação cambial do mês.
+type InvoiceRevaluation struct {
+	ID                int           `json:"id" gorm:"primaryKey"`
+	CompanyID         int           `json:"company_id" gorm:"<-:create"`
+	InvoiceID         int           `json:"invoice_id"`
+	InvoiceNo         string        `json:"invoice_no" gorm:"->;-:migration"`
+	Period            string        `json:"period"`
+	Currency          string        `json:"currency"`
+	OpenForeignAmount money.Decimal `json:"open_foreign_amount"`
+	PreviousRate      float64       `json:"previous_rate"`
+	Rate              float64       `json:"rate"`
+	RateDate          time.Time     `json:"rate_date" gorm:"type:date"`
+	BookValue         money.Decimal `json:"book_value"`
+	RevaluedValue     money.Decimal `json:"revalued_value"`
+	Variance          money.Decimal `json:"variance"`
+	CreatedAt         time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (InvoiceRevaluation) TableName() string {
+	return "invoice_revaluations"
+}
+
+// OpenForeignInvoice é uma fatura em aberto em moeda estrangeira a reavaliar, com a cotação da
+// última reavaliação anterior ao mês, quando houver
+type OpenForeignInvoice struct {
+	InvoiceID     int
+	CompanyID     int
+	InvoiceNo     string
+	GrandTotal    money.Decimal
+	AmountPaid    money.Decimal
+	Currency      string
+	ForeignAmount money.Decimal
+	IssueRate     float64
+	PreviousRate  *float64
+}
+
+// RevaluationRun é o resultado da reavaliação do mês: as cotações usadas por moeda e a variação
+// cambial de cada fatura
+type RevaluationRun struct {
+	Period        string               `json:"period"`
+	Rates         map[string]float64   `json:"rates"`
+	Invoices      int                  `json:"invoices"`
+	TotalVariance money.Decimal        `json:"total_variance"`
+	Revaluations  []InvoiceRevaluation `json:"revaluations"`
+}
+
+// NormalizeCurrency valida o código da moeda estrangeira (três letras, diferente do real)
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 || code == string(money.BRL) {
+		return "", errors.ErrInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", errors.ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
+
+// ToForeign converte o valor em reais para a moeda, nas casas decimais da moeda, com a metade
+// arredondada para longe do zero
+func ToForeign(brl money.Decimal, rate float64, currency string) money.Decimal {
+	if rate <= 0 {
+		return money.Zero
+	}
+	return brl.DivRate(rate, money.Currency(currency).Places(), money.HalfAwayFromZero)
+}
+
+// ToBRL converte o valor na moeda para reais, em centavos com a metade arredondada para longe do
+// zero
+func ToBRL(foreign money.Decimal, rate float64) money.Decimal {
+	return foreign.MulRate(rate, money.BRL.Places(), money.HalfAwayFromZero)
+}
+
+// RevaluationDate valida o mês da reavaliação, que precisa estar encerrado em today, e devolve o
+// último dia do mês, a data da cotação usada
+func RevaluationDate(period string, today time.Time) (time.Time, error) {
+	if !ValidMonth(period) {
+		return time.Time{}, errors.ErrInvalidRevaluationPeriod
+	}
+	start, _ := time.Parse(MonthLayout, period)
+	next := start.AddDate(0, 1, 0)
+	if time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC).Before(next) {
+		return time.Time{}, errors.ErrInvalidRevaluationPeriod
+	}
+	return next.AddDate(0, 0, -1), nil
+}
+
+// BuildRevaluation reavalia o saldo em aberto da fatura pela cotação do fim do mês. O saldo na
+// moeda é a parte do total na moeda proporcional ao saldo em reais ainda não pago.
+func BuildRevaluation(invoice OpenForeignInvoice, period string, rate ExchangeRate) InvoiceRevaluation {
+	openForeign := money.Zero
+	if invoice.GrandTotal.IsPositive() {
+		open := money.Max(invoice.GrandTotal.Sub(invoice.AmountPaid), money.Zero)
+		openForeign = invoice.ForeignAmount.Mul(open).Div(invoice.GrandTotal).RoundTo(money.Currency(invoice.Currency))
+	}
+	previousRate := invoice.IssueRate
+	if invoice.PreviousRate != nil {
+		previousRate = *invoice.PreviousRate
+	}
+
+	revaluation := InvoiceRevaluation{
+		CompanyID:         invoice.CompanyID,
+		InvoiceID:         invoice.InvoiceID,
+		InvoiceNo:         invoice.InvoiceNo,
+		Period:            period,
+		Currency:          invoice.Currency,
+		OpenForeignAmount: openForeign,
+		PreviousRate:      previousRate,
+		Rate:              rate.Rate,
+		RateDate:          rate.RateDate,
+		BookValue:         ToBRL(openForeign, previousRate),
+		RevaluedValue:     ToBRL(openForeign, rate.Rate),
+	}
+	revaluation.Variance = revaluation.RevaluedValue.Sub(revaluation.BookValue)
+	return revaluation
+}
+
+// BuildRevaluationRun resume as reavaliações do mês
+func BuildRevaluationRun(period string, rates map[string]float64, revaluations []InvoiceRevaluation) *RevaluationRun {
+	run := &RevaluationRun{
+		Period:       period,
+		Rates:        rates,
+		Invoices:     len(revaluations),
+		Revaluations: revaluations,
+	}
+	if run.Rates == nil {
+		run.Rates = map[string]float64{}
+	}
+	if run.Revaluations == nil {
+		run.Revaluations = []InvoiceRevaluation{}
+	}
+	for _, revaluation := range revaluations {
+		run.TotalVariance = run.TotalVariance.Add(revaluation.Variance)
+	}
+	return run
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCurrency(t *testing.T) {
+	code, err := NormalizeCurrency(" usd ")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", code)
+
+	for _, invalid := range []string{"", "BRL", "brl", "US", "USDT", "U$D"} {
+		_, err := NormalizeCurrency(invalid)
+		assert.ErrorIs(t, err, errors.ErrInvalidCurrency, invalid)
+	}
+}
+
+func TestRevaluationDate(t *testing.T) {
+	today := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	end, err := RevaluationDate("2026-09", today)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), end)
+
+	end, err = RevaluationDate("2024-02", today)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), end)
+
+	// O mês corrente ainda não encerrou
+	_, err = RevaluationDate("2026-10", today)
+	assert.ErrorIs(t, err, errors.ErrInvalidRevaluationPeriod)
+	_, err = RevaluationDate("2026-13", today)
+	assert.ErrorIs(t, err, errors.ErrInvalidRevaluationPeriod)
+}
+
+func TestBuildRevaluation(t *testing.T) {
+	rateDate := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	invoice := OpenForeignInvoice{
+		InvoiceID:     7,
+		CompanyID:     2,
+		InvoiceNo:     "INV-7",
+		GrandTotal:    money.FromInt(5000),
+		AmountPaid:    money.FromInt(2000),
+		Currency:      "USD",
+		ForeignAmount: money.FromInt(1000),
+		IssueRate:     5,
+	}
+
+	// Sem reavaliação anterior, o saldo é avaliado pela cotação da emissão
+	revaluation := BuildRevaluation(invoice, "2026-09", ExchangeRate{Currency: "USD", RateDate: rateDate, Rate: 5.4})
+	assert.Equal(t, 2, revaluation.CompanyID)
+	assert.Equal(t, "600.00", revaluation.OpenForeignAmount.String())
+	assert.Equal(t, 5.0, revaluation.PreviousRate)
+	assert.Equal(t, "3000.00", revaluation.BookValue.String())
+	assert.Equal(t, "3240.00", revaluation.RevaluedValue.String())
+	assert.Equal(t, "240.00", revaluation.Variance.String())
+	assert.Equal(t, rateDate, revaluation.RateDate)
+
+	// Com o mês anterior reavaliado, a variação parte da cotação daquele mês
+	previous := 5.5
+	invoice.PreviousRate = &previous
+	revaluation = BuildRevaluation(invoice, "2026-09", ExchangeRate{Currency: "USD", RateDate: rateDate, Rate: 5.4})
+	assert.Equal(t, "3300.00", revaluation.BookValue.String())
+	assert.Equal(t, "-60.00", revaluation.Variance.String())
+
+	run := BuildRevaluationRun("2026-09", map[string]float64{"USD": 5.4}, []InvoiceRevaluation{revaluation, revaluation})
+	assert.Equal(t, 2, run.Invoices)
+	assert.Equal(t, "-120.00", run.TotalVariance.String())
+}
+
+func TestToForeign(t *testing.T) {
+	assert.Equal(t, "185.19", ToForeign(money.FromInt(1000), 5.4, "USD").String())
+	assert.Equal(t, "27027.00", ToForeign(money.FromInt(1000), 0.037, "JPY").String())
+	assert.True(t, ToForeign(money.FromInt(1000), 0, "USD").IsZero())
+}
+
+func TestToBRL(t *testing.T) {
+	// a cotação entra com as 8 casas: 1234.56 × 5.12345678 = 6325.2148...
+	assert.Equal(t, "6325.21", ToBRL(money.MustParse("1234.56"), 5.12345678).String())
+	assert.Equal(t, "0.01", ToBRL(money.MustParse("0.002"), 2.5).String())
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BudgetRepository mantém as linhas do orçamento e levanta o realizado das faturas e contas a
+// pagar para o relatório de variação
+type BudgetRepository interface {
+	ListBudgets(ctx context.Context, filter models.BudgetFilter) ([]models.Budget, error)
+	GetBudget(ctx context.Context, id int) (*models.Budget, error)
+	CreateBudget(ctx context.Context, budget *models.Budget) error
+	UpdateBudget(ctx context.Context, budget *models.Budget) error
+	DeleteBudget(ctx context.Context, id int) error
+
+	GetAccount(ctx context.Context, id int) (*accounting.LedgerAccount, error)
+	Accounts(ctx context.Context, ids []int) (map[int]models.BudgetAccount, error)
+	GetCostCenter(ctx context.Context, id int) (*models.CostCenter, error)
+	GetCostCenterByCode(ctx context.Context, code string) (*models.CostCenter, error)
+	ListCostCenters(ctx context.Context) ([]models.CostCenter, error)
+	AccountMappings(ctx context.Context) (map[string]int, error)
+	Actuals(ctx context.Context, from, to time.Time, costCenterID *int) ([]models.BudgetActual, error)
+}
+
+type budgetRepository struct {
+	*dreRepository
+}
+
+// NewBudgetRepository cria uma nova instância do repositório; centros de custo e mapeamentos de
+// contas vêm do repositório da DRE
+func NewBudgetRepository() (BudgetRepository, error) {
+	provider, err := db.OpenProvider()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &budgetRepository{&dreRepository{
+		db:     provider.Writer(),
+		reader: provider.Reader(),
+		logger: logger.WithModule("budget_repository"),
+	}}, nil
+}
+
+// ListBudgets lista as linhas do orçamento por mês, centro de custo e conta
+func (r *budgetRepository) ListBudgets(ctx context.Context, filter models.BudgetFilter) ([]models.Budget, error) {
+	query := r.db.WithContext(ctx)
+	if filter.From != "" {
+		query = query.Where("month >= ?", filter.From)
+	}
+	if filter.To != "" {
+		query = query.Where("month <= ?", filter.To)
+	}
+	if filter.CostCenterID != nil {
+		query = query.Where("cost_center_id = ?", *filter.CostCenterID)
+	}
+	if filter.AccountID != 0 {
+		query = query.Where("account_id = ?", filter.AccountID)
+	}
+
+	var budgets []models.Budget
+	if err := query.Order("month ASC, cost_center_id ASC NULLS FIRST, account_id ASC").Find(&budgets).Error; err != nil {
+		r.logger.Error("erro ao listar orçamento", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar orçamento")
+	}
+	return budgets, nil
+}
+
+// GetBudget busca uma linha do orçamento pelo ID
+func (r *budgetRepository) GetBudget(ctx context.Context, id int) (*models.Budget, error) {
+	var budget models.Budget
+	if err := r.db.WithContext(ctx).First(&budget, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrBudgetNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar linha do orçamento")
+	}
+	return &budget, nil
+}
+
+// CreateBudget grava uma linha do orçamento; cada conta, centro de custo e mês tem uma só linha
+func (r *budgetRepository) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	if err := r.checkBudgetLine(ctx, budget); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(budget).Error; err != nil {
+		r.logger.Error("erro ao criar linha do orçamento", zap.Error(err),
+			zap.Int("account_id", budget.AccountID), zap.String("month", budget.Month))
+		return errors.WrapError(err, "falha ao criar linha do orçamento")
+	}
+	return nil
+}
+
+// UpdateBudget altera conta, centro de custo, mês, valor e observações da linha
+func (r *budgetRepository) UpdateBudget(ctx context.Context, budget *models.Budget) error {
+	if err := r.checkBudgetLine(ctx, budget); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(budget).
+		Select("cost_center_id", "account_id", "month", "amount", "notes", "updated_by", "updated_at").
+		Updates(budget)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar linha do orçamento", zap.Error(result.Error), zap.Int("id", budget.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar linha do orçamento")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrBudgetNotFound
+	}
+	return nil
+}
+
+// DeleteBudget remove uma linha do orçamento
+func (r *budgetRepository) DeleteBudget(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.Budget{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover linha do orçamento", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover linha do orçamento")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrBudgetNotFound
+	}
+	return nil
+}
+
+func (r *budgetRepository) checkBudgetLine(ctx context.Context, budget *models.Budget) error {
+	query := r.db.WithContext(ctx).Model(&models.Budget{}).
+		Where("account_id = ? AND month = ? AND id <> ?", budget.AccountID, budget.Month, budget.ID)
+	if budget.CostCenterID != nil {
+		query = query.Where("cost_center_id = ?", *budget.CostCenterID)
+	} else {
+		query = query.Where("cost_center_id IS NULL")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar linha do orçamento")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateBudget
+	}
+	return nil
+}
+
+// GetAccount busca a conta contábil orçada
+func (r *budgetRepository) GetAccount(ctx context.Context, id int) (*accounting.LedgerAccount, error) {
+	var account accounting.LedgerAccount
+	if err := r.db.WithContext(ctx).First(&account, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrLedgerAccountNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar conta contábil")
+	}
+	return &account, nil
+}
+
+// Accounts busca código, nome e tipo das contas do relatório de variação
+func (r *budgetRepository) Accounts(ctx context.Context, ids []int) (map[int]models.BudgetAccount, error) {
+	accounts := make(map[int]models.BudgetAccount, len(ids))
+	if len(ids) == 0 {
+		return accounts, nil
+	}
+
+	var rows []accounting.LedgerAccount
+	if err := r.reader.WithContext(ctx).Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		r.logger.Error("erro ao buscar contas do orçamento", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar contas do orçamento")
+	}
+	for _, row := range rows {
+		accounts[row.ID] = models.BudgetAccount{ID: row.ID, Code: row.Code, Name: row.Name, Type: row.Type}
+	}
+	return accounts, nil
+}
+
+// Actuals soma, por origem, centro de custo e mês de emissão, as faturas emitidas e as contas a
+// pagar da empresa no período (limite superior exclusivo), sem impostos e sem os documentos
+// cancelados ou em rascunho
+func (r *budgetRepository) Actuals(ctx context.Context, from, to time.Time, costCenterID *int) ([]models.BudgetActual, error) {
+	sources := []struct {
+		source string
+		table  string
+		filter string
+	}{
+		{models.SourceInvoice, "invoices", "invoices.status NOT IN ('draft', 'cancelled') AND invoices.deleted_at IS NULL"},
+		{models.SourceSupplierBill, "supplier_bills", "supplier_bills.status <> 'cancelled'"},
+	}
+
+	var actuals []models.BudgetActual
+	for _, s := range sources {
+		var rows []models.BudgetActual
+		query := r.reader.WithContext(ctx).Table(s.table).
+			Select(`'`+s.source+`' AS source, `+s.table+`.cost_center_id, to_char(`+s.table+`.issue_date, 'YYYY-MM') AS month,
+				COALESCE(SUM(`+s.table+`.grand_total - `+s.table+`.tax_total), 0) AS amount`).
+			Scopes(tenant.Scope(ctx, s.table)).
+			Where(s.filter).
+			Where(s.table+".issue_date >= ? AND "+s.table+".issue_date < ?", from, to)
+		if costCenterID != nil {
+			query = query.Where(s.table+".cost_center_id = ?", *costCenterID)
+		}
+		if err := query.Group("2, 3").Order("3").Scan(&rows).Error; err != nil {
+			r.logger.Error("erro ao somar realizado do orçamento", zap.Error(err), zap.String("source", s.source))
+			return nil, errors.WrapError(err, "falha ao somar realizado do orçamento")
+		}
+		actuals = append(actuals, rows...)
+	}
+	return actuals, nil
+}
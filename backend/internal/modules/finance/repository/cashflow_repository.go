@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CashflowRepository lê os saldos em aberto dos documentos e mantém os lançamentos previstos
+// usados na projeção do fluxo de caixa
+type CashflowRepository interface {
+	OpenReceivables(ctx context.Context, until time.Time) ([]models.Entry, error)
+	OpenPayables(ctx context.Context, until time.Time) ([]models.Entry, error)
+	PendingPurchases(ctx context.Context, until time.Time) ([]models.Entry, error)
+	PendingReimbursements(ctx context.Context, until time.Time) ([]models.Entry, error)
+	ActiveRentals(ctx context.Context, from, until time.Time) ([]models.RecurringBilling, error)
+
+	ListPlannedItems(ctx context.Context) ([]models.PlannedItem, error)
+	CreatePlannedItem(ctx context.Context, item *models.PlannedItem) error
+	DeletePlannedItem(ctx context.Context, id int) error
+}
+
+type cashflowRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCashflowRepository cria uma nova instância do repositório
+func NewCashflowRepository() (CashflowRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &cashflowRepository{
+		db:     db,
+		logger: logger.WithModule("cashflow_repository"),
+	}, nil
+}
+
+// documentBalance é o saldo em aberto de um documento com vencimento
+type documentBalance struct {
+	ID      int
+	Number  string
+	DueDate time.Time
+	Balance money.Decimal
+}
+
+// OpenReceivables retorna o saldo a receber das faturas emitidas e não quitadas, pelo vencimento
+func (r *cashflowRepository) OpenReceivables(ctx context.Context, until time.Time) ([]models.Entry, error) {
+	var rows []documentBalance
+	err := r.db.WithContext(ctx).Table("invoices").
+		Scopes(tenant.Scope(ctx, "invoices")).
+		Select("id, invoice_no AS number, due_date, grand_total - amount_paid AS balance").
+		Where("deleted_at IS NULL").
+		Where("status IN ?", []string{"sent", "partial", "overdue"}).
+		Where("due_date < ? AND grand_total - amount_paid > 0", until).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar faturas em aberto", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar faturas em aberto")
+	}
+	return toEntries(rows, models.DirectionIn, models.SourceInvoice, "Fatura "), nil
+}
+
+// OpenPayables retorna o saldo a pagar das contas de fornecedor abertas, pelo vencimento
+func (r *cashflowRepository) OpenPayables(ctx context.Context, until time.Time) ([]models.Entry, error) {
+	var rows []documentBalance
+	err := r.db.WithContext(ctx).Table("supplier_bills").
+		Scopes(tenant.Scope(ctx, "supplier_bills")).
+		Select("id, bill_no AS number, due_date, grand_total - amount_paid AS balance").
+		Where("status IN ?", []string{"open", "partial"}).
+		Where("due_date < ? AND grand_total - amount_paid > 0", until).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar contas a pagar em aberto", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar contas a pagar em aberto")
+	}
+	return toEntries(rows, models.DirectionOut, models.SourceSupplierBill, "Conta a pagar "), nil
+}
+
+// PendingPurchases retorna os pedidos de compra enviados ainda sem conta a pagar lançada, pela
+// data prevista de entrega. Pedidos já faturados pelo fornecedor entram como conta a pagar.
+func (r *cashflowRepository) PendingPurchases(ctx context.Context, until time.Time) ([]models.Entry, error) {
+	var rows []documentBalance
+	err := r.db.WithContext(ctx).Table("purchase_orders").
+		Scopes(tenant.Scope(ctx, "purchase_orders")).
+		Select("id, po_no AS number, expected_date AS due_date, grand_total AS balance").
+		Where("status IN ?", []string{"sent", "confirmed", "received"}).
+		Where("(expected_date IS NULL OR expected_date < ?) AND grand_total > 0", until).
+		Where("NOT EXISTS (SELECT 1 FROM supplier_bills sb WHERE sb.purchase_order_id = purchase_orders.id AND sb.status <> ?)", "cancelled").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar pedidos de compra pendentes", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar pedidos de compra pendentes")
+	}
+	return toEntries(rows, models.DirectionOut, models.SourcePurchaseOrder, "Pedido de compra "), nil
+}
+
+// PendingReimbursements retorna as despesas aprovadas dos colaboradores ainda não reembolsadas,
+// pela data da aprovação: o reembolso é devido a partir dela
+func (r *cashflowRepository) PendingReimbursements(ctx context.Context, until time.Time) ([]models.Entry, error) {
+	var rows []documentBalance
+	err := r.db.WithContext(ctx).Table("expenses").
+		Scopes(tenant.Scope(ctx, "expenses")).
+		Select("id, employee || ': ' || description AS number, approved_at AS due_date, amount AS balance").
+		Where("status = ?", "approved").
+		Where("approved_at < ? AND amount > 0", until).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar reembolsos de despesas pendentes", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar reembolsos de despesas pendentes")
+	}
+	return toEntries(rows, models.DirectionOut, models.SourceExpense, "Reembolso de despesa "), nil
+}
+
+func toEntries(rows []documentBalance, direction, source, prefix string) []models.Entry {
+	entries := make([]models.Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, models.Entry{
+			Direction:   direction,
+			Source:      source,
+			SourceID:    row.ID,
+			Description: prefix + row.Number,
+			Date:        row.DueDate,
+			Amount:      row.Balance,
+		})
+	}
+	return entries
+}
+
+// ActiveRentals retorna as locações vigentes no período, base da cobrança recorrente
+func (r *cashflowRepository) ActiveRentals(ctx context.Context, from, until time.Time) ([]models.RecurringBilling, error) {
+	var rentals []models.RecurringBilling
+	err := r.db.WithContext(ctx).Table("rentals").
+		Scopes(tenant.Scope(ctx, "rentals")).
+		Select("id, client_name, equipment, start_date, end_date, price, billing_type").
+		Where("start_date < ? AND end_date >= ?", until, from).
+		Scan(&rentals).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar locações vigentes", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar locações vigentes")
+	}
+	return rentals, nil
+}
+
+// ListPlannedItems lista os lançamentos previstos da empresa
+func (r *cashflowRepository) ListPlannedItems(ctx context.Context) ([]models.PlannedItem, error) {
+	var items []models.PlannedItem
+	if err := r.db.WithContext(ctx).Order("start_date ASC, id ASC").Find(&items).Error; err != nil {
+		r.logger.Error("erro ao listar lançamentos previstos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar lançamentos previstos")
+	}
+	return items, nil
+}
+
+// CreatePlannedItem grava um lançamento previsto
+func (r *cashflowRepository) CreatePlannedItem(ctx context.Context, item *models.PlannedItem) error {
+	if err := r.db.WithContext(ctx).Create(item).Error; err != nil {
+		r.logger.Error("erro ao criar lançamento previsto", zap.Error(err), zap.String("name", item.Name))
+		return errors.WrapError(err, "falha ao criar lançamento previsto")
+	}
+	return nil
+}
+
+// DeletePlannedItem remove um lançamento previsto
+func (r *cashflowRepository) DeletePlannedItem(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&models.PlannedItem{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover lançamento previsto", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover lançamento previsto")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrCashflowItemNotFound
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DRERepository mantém os centros de custo, classifica os documentos e soma os lançamentos
+// contábeis por conta para a DRE
+type DRERepository interface {
+	ListCostCenters(ctx context.Context) ([]models.CostCenter, error)
+	GetCostCenter(ctx context.Context, id int) (*models.CostCenter, error)
+	GetCostCenterByCode(ctx context.Context, code string) (*models.CostCenter, error)
+	CreateCostCenter(ctx context.Context, center *models.CostCenter) error
+	UpdateCostCenter(ctx context.Context, center *models.CostCenter) error
+	AssignCostCenter(ctx context.Context, documentType string, id int, costCenterID *int) error
+
+	AccountMappings(ctx context.Context) (map[string]int, error)
+	AccountTotals(ctx context.Context, from, to time.Time, costCenterID *int) ([]models.AccountTotal, error)
+}
+
+type dreRepository struct {
+	db     *gorm.DB
+	reader *gorm.DB // réplica de leitura para as somas da DRE (ver db.Provider)
+	logger *zap.Logger
+}
+
+// NewDRERepository cria uma nova instância do repositório
+func NewDRERepository() (DRERepository, error) {
+	provider, err := db.OpenProvider()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &dreRepository{
+		db:     provider.Writer(),
+		reader: provider.Reader(),
+		logger: logger.WithModule("dre_repository"),
+	}, nil
+}
+
+// ListCostCenters lista os centros de custo da empresa pelo código
+func (r *dreRepository) ListCostCenters(ctx context.Context) ([]models.CostCenter, error) {
+	var centers []models.CostCenter
+	if err := r.db.WithContext(ctx).Order("code ASC").Find(&centers).Error; err != nil {
+		r.logger.Error("erro ao listar centros de custo", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar centros de custo")
+	}
+	return centers, nil
+}
+
+// GetCostCenter busca um centro de custo pelo ID
+func (r *dreRepository) GetCostCenter(ctx context.Context, id int) (*models.CostCenter, error) {
+	var center models.CostCenter
+	if err := r.db.WithContext(ctx).First(&center, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrCostCenterNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar centro de custo")
+	}
+	return &center, nil
+}
+
+// GetCostCenterByCode busca um centro de custo pelo código
+func (r *dreRepository) GetCostCenterByCode(ctx context.Context, code string) (*models.CostCenter, error) {
+	var center models.CostCenter
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&center).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrCostCenterNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar centro de custo")
+	}
+	return &center, nil
+}
+
+// CreateCostCenter grava um centro de custo com código único na empresa
+func (r *dreRepository) CreateCostCenter(ctx context.Context, center *models.CostCenter) error {
+	if err := r.checkCode(ctx, center); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(center).Error; err != nil {
+		r.logger.Error("erro ao criar centro de custo", zap.Error(err), zap.String("code", center.Code))
+		return errors.WrapError(err, "falha ao criar centro de custo")
+	}
+	return nil
+}
+
+// UpdateCostCenter altera código, nome e situação do centro de custo
+func (r *dreRepository) UpdateCostCenter(ctx context.Context, center *models.CostCenter) error {
+	if err := r.checkCode(ctx, center); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(center).
+		Select("code", "name", "active", "updated_at").
+		Updates(center)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar centro de custo", zap.Error(result.Error), zap.Int("id", center.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar centro de custo")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrCostCenterNotFound
+	}
+	return nil
+}
+
+func (r *dreRepository) checkCode(ctx context.Context, center *models.CostCenter) error {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.CostCenter{}).
+		Where("code = ? AND id <> ?", center.Code, center.ID).
+		Count(&count).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao verificar código do centro de custo")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateCostCenterCode
+	}
+	return nil
+}
+
+// AssignCostCenter classifica o documento e os lançamentos contábeis já gerados a partir dele
+func (r *dreRepository) AssignCostCenter(ctx context.Context, documentType string, id int, costCenterID *int) error {
+	table, ok := models.CostCenterDocuments[documentType]
+	if !ok {
+		return errors.ErrInvalidCostCenterDocument
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(table).Scopes(tenant.Scope(ctx, table)).
+			Where(table+".id = ?", id).
+			Update("cost_center_id", costCenterID)
+		if result.Error != nil {
+			r.logger.Error("erro ao classificar documento por centro de custo", zap.Error(result.Error),
+				zap.String("type", documentType), zap.Int("id", id))
+			return errors.WrapError(result.Error, "falha ao classificar documento")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrEntityNotFound
+		}
+
+		err := tx.Model(&accounting.JournalEntry{}).
+			Where("source_type = ? AND source_id = ?", documentType, id).
+			Update("cost_center_id", costCenterID).Error
+		if err != nil {
+			return errors.WrapError(err, "falha ao classificar lançamentos do documento")
+		}
+		return nil
+	})
+}
+
+// AccountMappings retorna as contas da contabilização automática, usadas na classificação
+// padrão das linhas da DRE
+func (r *dreRepository) AccountMappings(ctx context.Context) (map[string]int, error) {
+	var rows []accounting.AccountMapping
+	if err := r.reader.WithContext(ctx).Find(&rows).Error; err != nil {
+		r.logger.Error("erro ao buscar mapeamentos de contas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar mapeamentos de contas")
+	}
+	mappings := make(map[string]int, len(rows))
+	for _, row := range rows {
+		mappings[row.Key] = row.AccountID
+	}
+	return mappings, nil
+}
+
+// AccountTotals soma débitos e créditos por conta nos lançamentos da empresa no período (limite
+// superior exclusivo), opcionalmente só os do centro de custo
+func (r *dreRepository) AccountTotals(ctx context.Context, from, to time.Time, costCenterID *int) ([]models.AccountTotal, error) {
+	var rows []models.AccountTotal
+
+	query := r.reader.WithContext(ctx).Table("ledger_accounts a").
+		Select(`a.id AS account_id, a.code AS account_code, a.name AS account_name, a.type AS account_type,
+			a.dre_group, COALESCE(SUM(l.debit), 0) AS debit, COALESCE(SUM(l.credit), 0) AS credit`).
+		Joins("JOIN journal_lines l ON l.account_id = a.id").
+		Joins("JOIN journal_entries e ON e.id = l.entry_id").
+		Scopes(tenant.Scope(ctx, "e")).
+		Where("e.entry_date >= ? AND e.entry_date < ?", from, to)
+	if costCenterID != nil {
+		query = query.Where("e.cost_center_id = ?", *costCenterID)
+	}
+
+	if err := query.Group("a.id, a.code, a.name, a.type, a.dre_group").
+		Order("a.code ASC").
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("erro ao somar lançamentos da DRE", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao somar lançamentos da DRE")
+	}
+	return rows, nil
+}
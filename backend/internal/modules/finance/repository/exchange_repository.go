@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/exchange"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRepository mantém o histórico das cotações, a moeda das faturas e as reavaliações
+// cambiais do fim do mês
+type ExchangeRepository interface {
+	ListRates(ctx context.Context, filter models.ExchangeRateFilter) ([]models.ExchangeRate, error)
+	FindRate(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error)
+	LastRateDate(ctx context.Context, currency string) (*time.Time, error)
+	SaveRates(ctx context.Context, rates []models.ExchangeRate) error
+	SaveManualRate(ctx context.Context, rate *models.ExchangeRate) error
+
+	GetInvoice(ctx context.Context, id int) (*sales.Invoice, error)
+	GetInvoiceCurrency(ctx context.Context, invoiceID int) (*models.InvoiceCurrency, error)
+	SaveInvoiceCurrency(ctx context.Context, currency *models.InvoiceCurrency) error
+
+	OpenForeignInvoices(ctx context.Context, until time.Time, period string) ([]models.OpenForeignInvoice, error)
+	SaveRevaluations(ctx context.Context, period string, revaluations []models.InvoiceRevaluation) error
+	ListRevaluations(ctx context.Context, period string) ([]models.InvoiceRevaluation, error)
+	HasRevaluations(ctx context.Context, period string) (bool, error)
+}
+
+type exchangeRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewExchangeRepository cria uma nova instância do repositório
+func NewExchangeRepository() (ExchangeRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &exchangeRepository{
+		db:     gormDB,
+		logger: logger.WithModule("exchange_repository"),
+	}, nil
+}
+
+// ListRates lista o histórico de cotações, do dia mais recente ao mais antigo
+func (r *exchangeRepository) ListRates(ctx context.Context, filter models.ExchangeRateFilter) ([]models.ExchangeRate, error) {
+	query := db.Conn(ctx, r.db).Model(&models.ExchangeRate{})
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("rate_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("rate_date <= ?", filter.To)
+	}
+
+	var rates []models.ExchangeRate
+	if err := query.Order("rate_date DESC, currency ASC").Find(&rates).Error; err != nil {
+		r.logger.Error("erro ao listar cotações", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar cotações")
+	}
+	return rates, nil
+}
+
+// FindRate busca a cotação da moeda no dia ou, sem cotação no dia, a do último dia anterior; nil
+// quando não há nenhuma
+func (r *exchangeRepository) FindRate(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := db.Conn(ctx, r.db).
+		Where("currency = ? AND rate_date <= ?", currency, date.Format(exchange.DateLayout)).
+		Order("rate_date DESC").
+		First(&rate).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.WrapError(err, "falha ao buscar cotação")
+	}
+	return &rate, nil
+}
+
+// LastRateDate retorna o dia da cotação mais recente da moeda; nil quando não há nenhuma
+func (r *exchangeRepository) LastRateDate(ctx context.Context, currency string) (*time.Time, error) {
+	var last *time.Time
+	err := db.Conn(ctx, r.db).Model(&models.ExchangeRate{}).
+		Where("currency = ?", currency).
+		Select("MAX(rate_date)").
+		Scan(&last).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar última cotação")
+	}
+	return last, nil
+}
+
+// SaveRates grava as cotações consultadas; as informadas manualmente para o mesmo dia são mantidas
+func (r *exchangeRepository) SaveRates(ctx context.Context, rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	err := db.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}, {Name: "rate_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "fetched_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "exchange_rates.source <> ?", Vars: []interface{}{exchange.ProviderManual}},
+		}},
+	}).Create(&rates).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar cotações", zap.Error(err), zap.Int("rates", len(rates)))
+		return errors.WrapError(err, "falha ao gravar cotações")
+	}
+	return nil
+}
+
+// SaveManualRate grava a cotação informada manualmente, substituindo a do dia
+func (r *exchangeRepository) SaveManualRate(ctx context.Context, rate *models.ExchangeRate) error {
+	err := db.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}, {Name: "rate_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "fetched_at"}),
+	}).Create(rate).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar cotação manual", zap.Error(err), zap.String("currency", rate.Currency))
+		return errors.WrapError(err, "falha ao gravar cotação")
+	}
+	return nil
+}
+
+// GetInvoice busca a fatura pelo ID
+func (r *exchangeRepository) GetInvoice(ctx context.Context, id int) (*sales.Invoice, error) {
+	var invoice sales.Invoice
+	if err := db.Conn(ctx, r.db).First(&invoice, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar fatura")
+	}
+	return &invoice, nil
+}
+
+// GetInvoiceCurrency busca a moeda estrangeira da fatura
+func (r *exchangeRepository) GetInvoiceCurrency(ctx context.Context, invoiceID int) (*models.InvoiceCurrency, error) {
+	var currency models.InvoiceCurrency
+	if err := db.Conn(ctx, r.db).Where("invoice_id = ?", invoiceID).First(&currency).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvoiceCurrencyNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar moeda da fatura")
+	}
+	return &currency, nil
+}
+
+// SaveInvoiceCurrency grava a moeda da fatura, substituindo a anterior
+func (r *exchangeRepository) SaveInvoiceCurrency(ctx context.Context, currency *models.InvoiceCurrency) error {
+	err := db.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "invoice_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"currency", "foreign_amount", "issue_rate", "rate_date", "updated_at"}),
+	}).Create(currency).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar moeda da fatura", zap.Error(err), zap.Int("invoice_id", currency.InvoiceID))
+		return errors.WrapError(err, "falha ao gravar moeda da fatura")
+	}
+	return nil
+}
+
+// OpenForeignInvoices lista as faturas em moeda estrangeira emitidas até until e ainda em aberto,
+// com a cotação da última reavaliação anterior ao período
+func (r *exchangeRepository) OpenForeignInvoices(ctx context.Context, until time.Time, period string) ([]models.OpenForeignInvoice, error) {
+	var invoices []models.OpenForeignInvoice
+	err := db.Conn(ctx, r.db).Model(&models.InvoiceCurrency{}).
+		Select(`invoice_currencies.invoice_id, invoice_currencies.company_id, invoices.invoice_no,
+			invoices.grand_total, invoices.amount_paid, invoice_currencies.currency,
+			invoice_currencies.foreign_amount, invoice_currencies.issue_rate,
+			(SELECT rv.rate FROM invoice_revaluations rv
+				WHERE rv.invoice_id = invoice_currencies.invoice_id AND rv.period < ?
+				ORDER BY rv.period DESC LIMIT 1) AS previous_rate`, period).
+		Joins("JOIN invoices ON invoices.id = invoice_currencies.invoice_id AND invoices.deleted_at IS NULL").
+		Where("invoices.status IN ?", []string{sales.InvoiceStatusSent, sales.InvoiceStatusPartial, sales.InvoiceStatusOverdue}).
+		Where("invoices.issue_date < ?", until.AddDate(0, 0, 1)).
+		Order("invoice_currencies.invoice_id ASC").
+		Scan(&invoices).Error
+	if err != nil {
+		r.logger.Error("erro ao listar faturas em moeda estrangeira", zap.Error(err), zap.String("period", period))
+		return nil, errors.WrapError(err, "falha ao listar faturas em moeda estrangeira")
+	}
+	return invoices, nil
+}
+
+// SaveRevaluations substitui as reavaliações do período
+func (r *exchangeRepository) SaveRevaluations(ctx context.Context, period string, revaluations []models.InvoiceRevaluation) error {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("period = ?", period).Delete(&models.InvoiceRevaluation{}).Error; err != nil {
+			return err
+		}
+		if len(revaluations) == 0 {
+			return nil
+		}
+		return tx.Create(&revaluations).Error
+	})
+	if err != nil {
+		r.logger.Error("erro ao gravar reavaliação cambial", zap.Error(err), zap.String("period", period))
+		return errors.WrapError(err, "falha ao gravar reavaliação cambial")
+	}
+	return nil
+}
+
+// ListRevaluations lista as reavaliações do período com o número das faturas
+func (r *exchangeRepository) ListRevaluations(ctx context.Context, period string) ([]models.InvoiceRevaluation, error) {
+	var revaluations []models.InvoiceRevaluation
+	err := db.Conn(ctx, r.db).Model(&models.InvoiceRevaluation{}).
+		Select("invoice_revaluations.*, invoices.invoice_no").
+		Joins("JOIN invoices ON invoices.id = invoice_revaluations.invoice_id").
+		Where("invoice_revaluations.period = ?", period).
+		Order("invoice_revaluations.invoice_id ASC").
+		Find(&revaluations).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao listar reavaliação cambial")
+	}
+	return revaluations, nil
+}
+
+// HasRevaluations indica se o período já foi reavaliado
+func (r *exchangeRepository) HasRevaluations(ctx context.Context, period string) (bool, error) {
+	var count int64
+	if err := db.Conn(ctx, r.db).Model(&models.InvoiceRevaluation{}).Where("period = ?", period).Count(&count).Error; err != nil {
+		return false, errors.WrapError(err, "falha ao verificar reavaliação cambial")
+	}
+	return count > 0, nil
+}
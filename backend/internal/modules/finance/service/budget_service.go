@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/repository"
+)
+
+// actualAccounts liga a origem do realizado à conta mapeada na contabilização automática: as
+// faturas são receita de vendas e as contas a pagar, compras
+var actualAccounts = map[string]string{
+	models.SourceInvoice:      accounting.MappingSalesRevenue,
+	models.SourceSupplierBill: accounting.MappingPurchases,
+}
+
+// BudgetService mantém o orçamento mensal e o compara com o realizado
+type BudgetService struct {
+	newRepo func() (repository.BudgetRepository, error)
+	now     func() time.Time
+
+	mu   sync.Mutex
+	repo repository.BudgetRepository
+}
+
+// NewBudgetService cria o serviço sobre o repositório informado
+func NewBudgetService(newRepo func() (repository.BudgetRepository, error)) *BudgetService {
+	return &BudgetService{
+		newRepo: newRepo,
+		now:     time.Now,
+	}
+}
+
+var defaultBudgetService = NewBudgetService(repository.NewBudgetRepository)
+
+// ListBudgets lista as linhas do orçamento
+func ListBudgets(ctx context.Context, filter models.BudgetFilter) ([]models.Budget, error) {
+	return defaultBudgetService.ListBudgets(ctx, filter)
+}
+
+// CreateBudget valida e grava uma linha do orçamento
+func CreateBudget(ctx context.Context, input models.BudgetInput, username string) (*models.Budget, error) {
+	return defaultBudgetService.CreateBudget(ctx, input, username)
+}
+
+// UpdateBudget valida e altera uma linha do orçamento
+func UpdateBudget(ctx context.Context, id int, input models.BudgetInput, username string) (*models.Budget, error) {
+	return defaultBudgetService.UpdateBudget(ctx, id, input, username)
+}
+
+// DeleteBudget remove uma linha do orçamento
+func DeleteBudget(ctx context.Context, id int) error {
+	return defaultBudgetService.DeleteBudget(ctx, id)
+}
+
+// GetBudgetVariance compara orçado e realizado no intervalo de meses
+func GetBudgetVariance(ctx context.Context, from, to, costCenter string) (*models.VarianceReport, error) {
+	return defaultBudgetService.Variance(ctx, from, to, costCenter)
+}
+
+func (s *BudgetService) repository() (repository.BudgetRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListBudgets lista as linhas do orçamento conforme o filtro
+func (s *BudgetService) ListBudgets(ctx context.Context, filter models.BudgetFilter) ([]models.Budget, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListBudgets(ctx, filter)
+}
+
+// CreateBudget grava uma linha do orçamento para conta de receita ou despesa
+func (s *BudgetService) CreateBudget(ctx context.Context, input models.BudgetInput, username string) (*models.Budget, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	budget := &models.Budget{CreatedBy: username}
+	if err := s.applyBudgetInput(ctx, repo, budget, input, username); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateBudget(ctx, budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// UpdateBudget altera conta, centro de custo, mês, valor e observações da linha
+func (s *BudgetService) UpdateBudget(ctx context.Context, id int, input models.BudgetInput, username string) (*models.Budget, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	budget, err := repo.GetBudget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyBudgetInput(ctx, repo, budget, input, username); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateBudget(ctx, budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// DeleteBudget remove uma linha do orçamento
+func (s *BudgetService) DeleteBudget(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteBudget(ctx, id)
+}
+
+// applyBudgetInput valida o mês, a conta (só contas de resultado são orçadas) e o centro de custo
+func (s *BudgetService) applyBudgetInput(ctx context.Context, repo repository.BudgetRepository, budget *models.Budget, input models.BudgetInput, username string) error {
+	month := strings.TrimSpace(input.Month)
+	if !models.ValidMonth(month) {
+		return fmt.Errorf("%w: mês deve estar no formato AAAA-MM", errors.ErrInvalidBudget)
+	}
+	if input.Amount.IsNegative() {
+		return fmt.Errorf("%w: o valor orçado não pode ser negativo", errors.ErrInvalidBudget)
+	}
+
+	account, err := repo.GetAccount(ctx, input.AccountID)
+	if err != nil {
+		return err
+	}
+	if account.Type != accounting.AccountTypeRevenue && account.Type != accounting.AccountTypeExpense {
+		return fmt.Errorf("%w: só contas de receita ou despesa podem ser orçadas", errors.ErrInvalidBudget)
+	}
+	if input.CostCenterID != nil {
+		if _, err := repo.GetCostCenter(ctx, *input.CostCenterID); err != nil {
+			return err
+		}
+	}
+
+	budget.CostCenterID = input.CostCenterID
+	budget.AccountID = input.AccountID
+	budget.Month = month
+	budget.Amount = input.Amount
+	budget.Notes = strings.TrimSpace(input.Notes)
+	budget.UpdatedBy = username
+	return nil
+}
+
+// Variance compara o orçamento com o realizado das faturas e contas a pagar, mês a mês. Sem
+// meses, o intervalo é o ano corrente; o centro de custo é informado pelo código.
+func (s *BudgetService) Variance(ctx context.Context, from, to, costCenter string) (*models.VarianceReport, error) {
+	year := s.now().Year()
+	if from == "" {
+		from = fmt.Sprintf("%04d-01", year)
+	}
+	if to == "" {
+		to = fmt.Sprintf("%04d-12", year)
+	}
+	if !models.ValidMonth(from) || !models.ValidMonth(to) {
+		return nil, fmt.Errorf("%w: meses devem estar no formato AAAA-MM", errors.ErrInvalidBudget)
+	}
+	if to < from {
+		return nil, errors.ErrInvalidDateRange
+	}
+	start, end, err := models.MonthBounds(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrInvalidBudget, err)
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	var center *models.CostCenter
+	var centerID *int
+	if code := strings.ToUpper(strings.TrimSpace(costCenter)); code != "" {
+		center, err = repo.GetCostCenterByCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		centerID = &center.ID
+	}
+
+	budgets, err := repo.ListBudgets(ctx, models.BudgetFilter{From: from, To: to, CostCenterID: centerID})
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := repo.AccountMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := repo.Actuals(ctx, start, end, centerID)
+	if err != nil {
+		return nil, err
+	}
+	actuals := make([]models.BudgetActual, 0, len(loaded))
+	for _, actual := range loaded {
+		// Sem a conta mapeada não há onde comparar o realizado
+		accountID, ok := mappings[actualAccounts[actual.Source]]
+		if !ok {
+			continue
+		}
+		actual.AccountID = accountID
+		actuals = append(actuals, actual)
+	}
+
+	seen := map[int]bool{}
+	var ids []int
+	for _, budget := range budgets {
+		if !seen[budget.AccountID] {
+			seen[budget.AccountID] = true
+			ids = append(ids, budget.AccountID)
+		}
+	}
+	for _, actual := range actuals {
+		if !seen[actual.AccountID] {
+			seen[actual.AccountID] = true
+			ids = append(ids, actual.AccountID)
+		}
+	}
+	accounts, err := repo.Accounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	centers := map[int]models.CostCenter{}
+	list, err := repo.ListCostCenters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range list {
+		centers[c.ID] = c
+	}
+
+	report := models.BuildVariance(budgets, actuals, accounts, centers, from, to)
+	report.CostCenter = center
+	return report, nil
+}
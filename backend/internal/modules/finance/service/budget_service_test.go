@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/repository"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type actualsCall struct {
+	from, to     time.Time
+	costCenterID *int
+}
+
+type fakeBudgetRepo struct {
+	budgets  []models.Budget
+	accounts map[int]*accounting.LedgerAccount
+	centers  map[int]*models.CostCenter
+	mappings map[string]int
+	actuals  []models.BudgetActual
+	filters  []models.BudgetFilter
+	calls    []actualsCall
+}
+
+func (r *fakeBudgetRepo) ListBudgets(ctx context.Context, filter models.BudgetFilter) ([]models.Budget, error) {
+	r.filters = append(r.filters, filter)
+	return r.budgets, nil
+}
+
+func (r *fakeBudgetRepo) GetBudget(ctx context.Context, id int) (*models.Budget, error) {
+	for i := range r.budgets {
+		if r.budgets[i].ID == id {
+			return &r.budgets[i], nil
+		}
+	}
+	return nil, appErrors.ErrBudgetNotFound
+}
+
+func (r *fakeBudgetRepo) CreateBudget(ctx context.Context, budget *models.Budget) error {
+	budget.ID = len(r.budgets) + 1
+	r.budgets = append(r.budgets, *budget)
+	return nil
+}
+
+func (r *fakeBudgetRepo) UpdateBudget(ctx context.Context, budget *models.Budget) error {
+	return nil
+}
+
+func (r *fakeBudgetRepo) DeleteBudget(ctx context.Context, id int) error {
+	return nil
+}
+
+func (r *fakeBudgetRepo) GetAccount(ctx context.Context, id int) (*accounting.LedgerAccount, error) {
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, appErrors.ErrLedgerAccountNotFound
+	}
+	return account, nil
+}
+
+func (r *fakeBudgetRepo) Accounts(ctx context.Context, ids []int) (map[int]models.BudgetAccount, error) {
+	accounts := map[int]models.BudgetAccount{}
+	for _, id := range ids {
+		if account, ok := r.accounts[id]; ok {
+			accounts[id] = models.BudgetAccount{ID: id, Code: account.Code, Name: account.Name, Type: account.Type}
+		}
+	}
+	return accounts, nil
+}
+
+func (r *fakeBudgetRepo) GetCostCenter(ctx context.Context, id int) (*models.CostCenter, error) {
+	center, ok := r.centers[id]
+	if !ok {
+		return nil, appErrors.ErrCostCenterNotFound
+	}
+	return center, nil
+}
+
+func (r *fakeBudgetRepo) GetCostCenterByCode(ctx context.Context, code string) (*models.CostCenter, error) {
+	for _, center := range r.centers {
+		if center.Code == code {
+			return center, nil
+		}
+	}
+	return nil, appErrors.ErrCostCenterNotFound
+}
+
+func (r *fakeBudgetRepo) ListCostCenters(ctx context.Context) ([]models.CostCenter, error) {
+	var centers []models.CostCenter
+	for _, center := range r.centers {
+		centers = append(centers, *center)
+	}
+	return centers, nil
+}
+
+func (r *fakeBudgetRepo) AccountMappings(ctx context.Context) (map[string]int, error) {
+	return r.mappings, nil
+}
+
+func (r *fakeBudgetRepo) Actuals(ctx context.Context, from, to time.Time, costCenterID *int) ([]models.BudgetActual, error) {
+	r.calls = append(r.calls, actualsCall{from, to, costCenterID})
+	return r.actuals, nil
+}
+
+func newTestBudgetService(repo *fakeBudgetRepo) *BudgetService {
+	s := NewBudgetService(func() (repository.BudgetRepository, error) { return repo, nil })
+	s.now = func() time.Time { return time.Date(2026, 10, 15, 14, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestCreateBudgetValidation(t *testing.T) {
+	repo := &fakeBudgetRepo{
+		accounts: map[int]*accounting.LedgerAccount{
+			1:  {ID: 1, Type: accounting.AccountTypeAsset},
+			20: {ID: 20, Type: accounting.AccountTypeExpense},
+		},
+		centers: map[int]*models.CostCenter{4: {ID: 4, Code: "ADM"}},
+	}
+	s := newTestBudgetService(repo)
+	ctx := context.Background()
+	missing := 9
+
+	_, err := s.CreateBudget(ctx, models.BudgetInput{AccountID: 20, Month: "2026-1", Amount: money.FromInt(10)}, "ana")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidBudget)
+	_, err = s.CreateBudget(ctx, models.BudgetInput{AccountID: 1, Month: "2026-01", Amount: money.FromInt(10)}, "ana")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidBudget, "conta patrimonial não é orçada")
+	_, err = s.CreateBudget(ctx, models.BudgetInput{AccountID: 20, CostCenterID: &missing, Month: "2026-01", Amount: money.FromInt(10)}, "ana")
+	assert.ErrorIs(t, err, appErrors.ErrCostCenterNotFound)
+
+	budget, err := s.CreateBudget(ctx, models.BudgetInput{AccountID: 20, Month: " 2026-01 ", Amount: money.FromInt(10), Notes: " aluguel "}, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01", budget.Month)
+	assert.Equal(t, "aluguel", budget.Notes)
+	assert.Equal(t, "ana", budget.CreatedBy)
+	assert.Equal(t, "ana", budget.UpdatedBy)
+}
+
+func TestBudgetVarianceMapsActualsToAccounts(t *testing.T) {
+	repo := &fakeBudgetRepo{
+		budgets: []models.Budget{{AccountID: 10, Month: "2026-03", Amount: money.FromInt(1000)}},
+		accounts: map[int]*accounting.LedgerAccount{
+			10: {ID: 10, Code: "3.1", Type: accounting.AccountTypeRevenue},
+			20: {ID: 20, Code: "4.1", Type: accounting.AccountTypeExpense},
+		},
+		centers: map[int]*models.CostCenter{4: {ID: 4, Code: "VEN"}},
+		mappings: map[string]int{
+			accounting.MappingSalesRevenue: 10,
+			accounting.MappingPurchases:    20,
+		},
+		actuals: []models.BudgetActual{
+			{Source: models.SourceInvoice, Month: "2026-03", Amount: money.FromInt(900)},
+			{Source: models.SourceSupplierBill, Month: "2026-03", Amount: money.FromInt(300)},
+		},
+	}
+	s := newTestBudgetService(repo)
+
+	report, err := s.Variance(context.Background(), "", "", " ven ")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01", report.From)
+	assert.Equal(t, "2026-12", report.To)
+	require.Len(t, repo.calls, 1)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), repo.calls[0].from)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), repo.calls[0].to)
+	assert.Equal(t, 4, *repo.calls[0].costCenterID)
+	assert.Equal(t, 4, *repo.filters[0].CostCenterID)
+	assert.Equal(t, "VEN", report.CostCenter.Code)
+
+	require.Len(t, report.Lines, 2)
+	assert.Equal(t, 10, report.Lines[0].AccountID, "faturas na receita de vendas")
+	assert.Equal(t, "-100.00", report.Lines[0].Variance.String())
+	assert.Equal(t, 20, report.Lines[1].AccountID, "contas a pagar em compras")
+	assert.True(t, report.Lines[1].OverBudget)
+
+	_, err = s.Variance(context.Background(), "2026-05", "2026-04", "")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidDateRange)
+	_, err = s.Variance(context.Background(), "2026/05", "", "")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidBudget)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/repository"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// CashflowService projeta o fluxo de caixa semanal a partir dos documentos em aberto, das
+// locações e dos lançamentos previstos
+type CashflowService struct {
+	newRepo func() (repository.CashflowRepository, error)
+	now     func() time.Time
+
+	mu   sync.Mutex
+	repo repository.CashflowRepository
+}
+
+// NewCashflowService cria o serviço sobre o repositório informado
+func NewCashflowService(newRepo func() (repository.CashflowRepository, error)) *CashflowService {
+	return &CashflowService{
+		newRepo: newRepo,
+		now:     time.Now,
+	}
+}
+
+var defaultService = NewCashflowService(repository.NewCashflowRepository)
+
+// ProjectionOptions são os parâmetros da projeção
+type ProjectionOptions struct {
+	Weeks          int
+	OpeningBalance money.Decimal
+	Detailed       bool
+}
+
+// ProjectCashflow projeta entradas e saídas da empresa em semanas, com saldo acumulado
+func ProjectCashflow(ctx context.Context, options ProjectionOptions) (*models.Projection, error) {
+	return defaultService.Project(ctx, options)
+}
+
+// ListPlannedItems lista os lançamentos previstos da empresa
+func ListPlannedItems(ctx context.Context) ([]models.PlannedItem, error) {
+	return defaultService.ListPlannedItems(ctx)
+}
+
+// CreatePlannedItem valida e grava um lançamento previsto
+func CreatePlannedItem(ctx context.Context, input models.PlannedItemInput, createdBy string) (*models.PlannedItem, error) {
+	return defaultService.CreatePlannedItem(ctx, input, createdBy)
+}
+
+// DeletePlannedItem remove um lançamento previsto
+func DeletePlannedItem(ctx context.Context, id int) error {
+	return defaultService.DeletePlannedItem(ctx, id)
+}
+
+func (s *CashflowService) repository() (repository.CashflowRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// Project reúne os lançamentos esperados até o fim do horizonte e os distribui por semana.
+// Faturas e contas vencidas entram na semana atual, pois ainda devem passar pelo caixa.
+func (s *CashflowService) Project(ctx context.Context, options ProjectionOptions) (*models.Projection, error) {
+	weeks := options.Weeks
+	if weeks <= 0 {
+		weeks = models.DefaultWeeks
+	}
+	if weeks > models.MaxWeeks {
+		weeks = models.MaxWeeks
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	from := models.WeekStart(s.now())
+	until := from.AddDate(0, 0, 7*weeks)
+
+	var entries []models.Entry
+	for _, load := range []func(context.Context, time.Time) ([]models.Entry, error){
+		repo.OpenReceivables,
+		repo.OpenPayables,
+		repo.PendingPurchases,
+		repo.PendingReimbursements,
+	} {
+		loaded, err := load(ctx, until)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, loaded...)
+	}
+
+	rentals, err := repo.ActiveRentals(ctx, from, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, rental := range rentals {
+		entries = append(entries, rental.Entries(from, until)...)
+	}
+
+	items, err := repo.ListPlannedItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		entries = append(entries, item.Entries(from, until)...)
+	}
+
+	return models.Project(entries, from, weeks, options.OpeningBalance, options.Detailed), nil
+}
+
+// ListPlannedItems lista os lançamentos previstos da empresa
+func (s *CashflowService) ListPlannedItems(ctx context.Context) ([]models.PlannedItem, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListPlannedItems(ctx)
+}
+
+// CreatePlannedItem valida o período e grava o lançamento previsto
+func (s *CashflowService) CreatePlannedItem(ctx context.Context, input models.PlannedItemInput, createdBy string) (*models.PlannedItem, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: informe o nome do lançamento", errors.ErrInvalidCashflowItem)
+	}
+	if input.EndDate != nil && input.EndDate.Before(input.StartDate) {
+		return nil, fmt.Errorf("%w: a data de fim é anterior à de início", errors.ErrInvalidCashflowItem)
+	}
+	category := strings.ToLower(strings.TrimSpace(input.Category))
+	if category == "" {
+		category = "other"
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	item := &models.PlannedItem{
+		Name:      name,
+		Category:  category,
+		Direction: input.Direction,
+		Amount:    input.Amount,
+		Frequency: input.Frequency,
+		StartDate: input.StartDate,
+		EndDate:   input.EndDate,
+		CreatedBy: createdBy,
+	}
+	if err := repo.CreatePlannedItem(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DeletePlannedItem remove um lançamento previsto
+func (s *CashflowService) DeletePlannedItem(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeletePlannedItem(ctx, id)
+}
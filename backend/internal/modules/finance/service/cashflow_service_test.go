@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/repository"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	receivables []models.Entry
+	payables    []models.Entry
+	purchases   []models.Entry
+	expenses    []models.Entry
+	rentals     []models.RecurringBilling
+	items       []models.PlannedItem
+	until       time.Time
+	created     []*models.PlannedItem
+}
+
+func (r *fakeRepo) OpenReceivables(ctx context.Context, until time.Time) ([]models.Entry, error) {
+	r.until = until
+	return r.receivables, nil
+}
+
+func (r *fakeRepo) OpenPayables(ctx context.Context, until time.Time) ([]models.Entry, error) {
+	return r.payables, nil
+}
+
+func (r *fakeRepo) PendingPurchases(ctx context.Context, until time.Time) ([]models.Entry, error) {
+	return r.purchases, nil
+}
+
+func (r *fakeRepo) PendingReimbursements(ctx context.Context, until time.Time) ([]models.Entry, error) {
+	return r.expenses, nil
+}
+
+func (r *fakeRepo) ActiveRentals(ctx context.Context, from, until time.Time) ([]models.RecurringBilling, error) {
+	return r.rentals, nil
+}
+
+func (r *fakeRepo) ListPlannedItems(ctx context.Context) ([]models.PlannedItem, error) {
+	return r.items, nil
+}
+
+func (r *fakeRepo) CreatePlannedItem(ctx context.Context, item *models.PlannedItem) error {
+	item.ID = len(r.created) + 1
+	r.created = append(r.created, item)
+	return nil
+}
+
+func (r *fakeRepo) DeletePlannedItem(ctx context.Context, id int) error {
+	return appErrors.ErrCashflowItemNotFound
+}
+
+func newTestService(repo *fakeRepo) *CashflowService {
+	s := NewCashflowService(func() (repository.CashflowRepository, error) { return repo, nil })
+	s.now = func() time.Time { return time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestProjectCombinesSources(t *testing.T) {
+	repo := &fakeRepo{
+		receivables: []models.Entry{{Direction: models.DirectionIn, Source: models.SourceInvoice, Date: day(10, 14), Amount: money.FromInt(2000)}},
+		payables:    []models.Entry{{Direction: models.DirectionOut, Source: models.SourceSupplierBill, Date: day(10, 21), Amount: money.FromInt(800)}},
+		purchases:   []models.Entry{{Direction: models.DirectionOut, Source: models.SourcePurchaseOrder, Date: day(10, 28), Amount: money.FromInt(400)}},
+		expenses:    []models.Entry{{Direction: models.DirectionOut, Source: models.SourceExpense, Date: day(10, 27), Amount: money.FromInt(300)}},
+		rentals: []models.RecurringBilling{
+			{ID: 1, StartDate: day(1, 20), EndDate: day(12, 31), Price: money.FromInt(500), BillingType: "mensal"},
+		},
+		items: []models.PlannedItem{
+			{ID: 1, Name: "Folha", Direction: models.DirectionOut, Amount: money.FromInt(3000), Frequency: models.FrequencyMonthly, StartDate: day(1, 5)},
+		},
+	}
+	s := newTestService(repo)
+
+	projection, err := s.Project(context.Background(), ProjectionOptions{Weeks: 4, OpeningBalance: money.FromInt(1000)})
+	require.NoError(t, err)
+	require.Len(t, projection.Buckets, 4)
+	assert.Equal(t, day(11, 9), repo.until, "horizonte de 4 semanas a partir de 12/10")
+
+	assert.Equal(t, "3000.00", projection.Buckets[0].Balance.String())
+	assert.Equal(t, "500.00", projection.Buckets[1].InflowsBySource[models.SourceRental].String())
+	assert.Equal(t, "2700.00", projection.Buckets[1].Balance.String())
+	assert.Equal(t, "300.00", projection.Buckets[2].OutflowsBySource[models.SourceExpense].String())
+	assert.Equal(t, "2000.00", projection.Buckets[2].Balance.String())
+	assert.Equal(t, "3000.00", projection.Buckets[3].OutflowsBySource[models.SourcePlanned].String())
+	assert.Equal(t, "-1000.00", projection.ClosingBalance.String())
+	require.NotNil(t, projection.FirstShortfall)
+	assert.Equal(t, day(11, 2), *projection.FirstShortfall)
+}
+
+func TestProjectDefaultsAndCapsWeeks(t *testing.T) {
+	s := newTestService(&fakeRepo{})
+
+	projection, err := s.Project(context.Background(), ProjectionOptions{})
+	require.NoError(t, err)
+	assert.Len(t, projection.Buckets, models.DefaultWeeks)
+
+	projection, err = s.Project(context.Background(), ProjectionOptions{Weeks: 500})
+	require.NoError(t, err)
+	assert.Len(t, projection.Buckets, models.MaxWeeks)
+}
+
+func TestCreatePlannedItemValidatesPeriod(t *testing.T) {
+	repo := &fakeRepo{}
+	s := newTestService(repo)
+	end := day(1, 1)
+
+	_, err := s.CreatePlannedItem(context.Background(), models.PlannedItemInput{
+		Name: "Aluguel", Direction: models.DirectionOut, Amount: money.FromInt(100), Frequency: models.FrequencyMonthly,
+		StartDate: day(2, 1), EndDate: &end,
+	}, "admin")
+	assert.True(t, errors.Is(err, appErrors.ErrInvalidCashflowItem))
+
+	item, err := s.CreatePlannedItem(context.Background(), models.PlannedItemInput{
+		Name: " Folha ", Direction: models.DirectionOut, Amount: money.FromInt(100), Frequency: models.FrequencyMonthly,
+		StartDate: day(2, 1),
+	}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "Folha", item.Name)
+	assert.Equal(t, "other", item.Category)
+	assert.Equal(t, "admin", item.CreatedBy)
+	assert.Len(t, repo.created, 1)
+}
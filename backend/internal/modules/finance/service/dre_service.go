@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/repository"
+)
+
+// DREService mantém os centros de custo e apura a DRE a partir dos lançamentos contábeis
+type DREService struct {
+	newRepo func() (repository.DRERepository, error)
+	now     func() time.Time
+
+	mu   sync.Mutex
+	repo repository.DRERepository
+}
+
+// NewDREService cria o serviço sobre o repositório informado
+func NewDREService(newRepo func() (repository.DRERepository, error)) *DREService {
+	return &DREService{
+		newRepo: newRepo,
+		now:     time.Now,
+	}
+}
+
+var defaultDREService = NewDREService(repository.NewDRERepository)
+
+// GetDRE apura a DRE do período (datas inclusivas), opcionalmente de um centro de custo
+func GetDRE(ctx context.Context, from, to time.Time, costCenter string) (*models.DRE, error) {
+	return defaultDREService.GetDRE(ctx, from, to, costCenter)
+}
+
+// ExportDRE apura a DRE e gera a planilha XLSX
+func ExportDRE(ctx context.Context, from, to time.Time, costCenter string) (*models.Output, error) {
+	dre, err := defaultDREService.GetDRE(ctx, from, to, costCenter)
+	if err != nil {
+		return nil, err
+	}
+	return models.RenderDRE(dre)
+}
+
+// ListCostCenters lista os centros de custo da empresa
+func ListCostCenters(ctx context.Context) ([]models.CostCenter, error) {
+	return defaultDREService.ListCostCenters(ctx)
+}
+
+// CreateCostCenter cadastra um centro de custo
+func CreateCostCenter(ctx context.Context, input models.CostCenterInput) (*models.CostCenter, error) {
+	return defaultDREService.CreateCostCenter(ctx, input)
+}
+
+// UpdateCostCenter altera um centro de custo
+func UpdateCostCenter(ctx context.Context, id int, input models.CostCenterInput) (*models.CostCenter, error) {
+	return defaultDREService.UpdateCostCenter(ctx, id, input)
+}
+
+// AssignCostCenter classifica um documento (e seus lançamentos) no centro de custo
+func AssignCostCenter(ctx context.Context, documentType string, id int, costCenterID *int) error {
+	return defaultDREService.AssignCostCenter(ctx, documentType, id, costCenterID)
+}
+
+func (s *DREService) repository() (repository.DRERepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// GetDRE apura a DRE do período. Sem datas, o período vai do início do ano até hoje; o centro
+// de custo é informado pelo código.
+func (s *DREService) GetDRE(ctx context.Context, from, to time.Time, costCenter string) (*models.DRE, error) {
+	if to.IsZero() {
+		to = s.now()
+	}
+	if from.IsZero() {
+		from = time.Date(to.Year(), 1, 1, 0, 0, 0, 0, to.Location())
+	}
+	from, to = truncateToDay(from), truncateToDay(to)
+	if to.Before(from) {
+		return nil, errors.ErrInvalidDateRange
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	var center *models.CostCenter
+	var centerID *int
+	if code := strings.ToUpper(strings.TrimSpace(costCenter)); code != "" {
+		center, err = repo.GetCostCenterByCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		centerID = &center.ID
+	}
+
+	mappings, err := repo.AccountMappings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := repo.AccountTotals(ctx, from, to.AddDate(0, 0, 1), centerID)
+	if err != nil {
+		return nil, err
+	}
+
+	dre := models.BuildDRE(rows, mappings, from, to)
+	dre.CostCenter = center
+	return dre, nil
+}
+
+// ListCostCenters lista os centros de custo da empresa
+func (s *DREService) ListCostCenters(ctx context.Context) ([]models.CostCenter, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListCostCenters(ctx)
+}
+
+// CreateCostCenter cadastra um centro de custo, ativo se não informado
+func (s *DREService) CreateCostCenter(ctx context.Context, input models.CostCenterInput) (*models.CostCenter, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	center := &models.CostCenter{Active: true}
+	applyCostCenterInput(center, input)
+	if err := repo.CreateCostCenter(ctx, center); err != nil {
+		return nil, err
+	}
+	return center, nil
+}
+
+// UpdateCostCenter altera código, nome e situação do centro de custo
+func (s *DREService) UpdateCostCenter(ctx context.Context, id int, input models.CostCenterInput) (*models.CostCenter, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	center, err := repo.GetCostCenter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	applyCostCenterInput(center, input)
+	if err := repo.UpdateCostCenter(ctx, center); err != nil {
+		return nil, err
+	}
+	return center, nil
+}
+
+// AssignCostCenter classifica o documento no centro de custo; só centros ativos são aceitos
+func (s *DREService) AssignCostCenter(ctx context.Context, documentType string, id int, costCenterID *int) error {
+	if _, ok := models.CostCenterDocuments[documentType]; !ok {
+		return errors.ErrInvalidCostCenterDocument
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	if costCenterID != nil {
+		center, err := repo.GetCostCenter(ctx, *costCenterID)
+		if err != nil {
+			return err
+		}
+		if !center.Active {
+			return errors.ErrCostCenterInactive
+		}
+	}
+	return repo.AssignCostCenter(ctx, documentType, id, costCenterID)
+}
+
+func applyCostCenterInput(center *models.CostCenter, input models.CostCenterInput) {
+	center.Code = strings.ToUpper(strings.TrimSpace(input.Code))
+	center.Name = strings.TrimSpace(input.Name)
+	if input.Active != nil {
+		center.Active = *input.Active
+	}
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/repository"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type totalsCall struct {
+	from, to     time.Time
+	costCenterID *int
+}
+
+type fakeDRERepo struct {
+	centers  map[int]*models.CostCenter
+	rows     []models.AccountTotal
+	calls    []totalsCall
+	assigned map[string]*int
+}
+
+func (r *fakeDRERepo) ListCostCenters(ctx context.Context) ([]models.CostCenter, error) {
+	return nil, nil
+}
+
+func (r *fakeDRERepo) GetCostCenter(ctx context.Context, id int) (*models.CostCenter, error) {
+	center, ok := r.centers[id]
+	if !ok {
+		return nil, appErrors.ErrCostCenterNotFound
+	}
+	return center, nil
+}
+
+func (r *fakeDRERepo) GetCostCenterByCode(ctx context.Context, code string) (*models.CostCenter, error) {
+	for _, center := range r.centers {
+		if center.Code == code {
+			return center, nil
+		}
+	}
+	return nil, appErrors.ErrCostCenterNotFound
+}
+
+func (r *fakeDRERepo) CreateCostCenter(ctx context.Context, center *models.CostCenter) error {
+	center.ID = len(r.centers) + 1
+	r.centers[center.ID] = center
+	return nil
+}
+
+func (r *fakeDRERepo) UpdateCostCenter(ctx context.Context, center *models.CostCenter) error {
+	r.centers[center.ID] = center
+	return nil
+}
+
+func (r *fakeDRERepo) AssignCostCenter(ctx context.Context, documentType string, id int, costCenterID *int) error {
+	r.assigned[documentType] = costCenterID
+	return nil
+}
+
+func (r *fakeDRERepo) AccountMappings(ctx context.Context) (map[string]int, error) {
+	return map[string]int{accounting.MappingPurchases: 20}, nil
+}
+
+func (r *fakeDRERepo) AccountTotals(ctx context.Context, from, to time.Time, costCenterID *int) ([]models.AccountTotal, error) {
+	r.calls = append(r.calls, totalsCall{from, to, costCenterID})
+	return r.rows, nil
+}
+
+func newTestDREService(repo *fakeDRERepo) *DREService {
+	s := NewDREService(func() (repository.DRERepository, error) { return repo, nil })
+	s.now = func() time.Time { return time.Date(2026, 10, 15, 14, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestGetDREDefaultsAndCostCenter(t *testing.T) {
+	repo := &fakeDRERepo{
+		centers: map[int]*models.CostCenter{4: {ID: 4, Code: "ADM", Name: "Administrativo", Active: true}},
+		rows: []models.AccountTotal{
+			{AccountID: 10, AccountType: accounting.AccountTypeRevenue, Credit: money.FromInt(1000)},
+			{AccountID: 20, AccountType: accounting.AccountTypeExpense, Debit: money.FromInt(600)},
+		},
+	}
+	s := newTestDREService(repo)
+
+	dre, err := s.GetDRE(context.Background(), time.Time{}, time.Time{}, " adm ")
+	require.NoError(t, err)
+	require.Len(t, repo.calls, 1)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), repo.calls[0].from)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), repo.calls[0].to, "data final inclusiva")
+	require.NotNil(t, repo.calls[0].costCenterID)
+	assert.Equal(t, 4, *repo.calls[0].costCenterID)
+	assert.Equal(t, "600.00", dre.COGS.String(), "compras mapeadas entram como custo")
+	assert.Equal(t, "400.00", dre.NetResult.String())
+	assert.Equal(t, "ADM", dre.CostCenter.Code)
+
+	_, err = s.GetDRE(context.Background(), time.Time{}, time.Time{}, "XYZ")
+	assert.ErrorIs(t, err, appErrors.ErrCostCenterNotFound)
+
+	_, err = s.GetDRE(context.Background(), time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), "")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidDateRange)
+}
+
+func TestAssignCostCenter(t *testing.T) {
+	repo := &fakeDRERepo{
+		centers: map[int]*models.CostCenter{
+			1: {ID: 1, Code: "VEN", Active: true},
+			2: {ID: 2, Code: "OLD", Active: false},
+		},
+		assigned: map[string]*int{},
+	}
+	s := newTestDREService(repo)
+	ctx := context.Background()
+	active, inactive := 1, 2
+
+	assert.ErrorIs(t, s.AssignCostCenter(ctx, "purchase_order", 1, &active), appErrors.ErrInvalidCostCenterDocument)
+	assert.ErrorIs(t, s.AssignCostCenter(ctx, "invoice", 1, &inactive), appErrors.ErrCostCenterInactive)
+	require.NoError(t, s.AssignCostCenter(ctx, "invoice", 1, &active))
+	assert.Equal(t, &active, repo.assigned["invoice"])
+	require.NoError(t, s.AssignCostCenter(ctx, "supplier_bill", 1, nil), "nulo remove a classificação")
+}
+
+func TestCreateCostCenterNormalizesCode(t *testing.T) {
+	repo := &fakeDRERepo{centers: map[int]*models.CostCenter{}}
+	s := newTestDREService(repo)
+
+	center, err := s.CreateCostCenter(context.Background(), models.CostCenterInput{Code: " ven-sp ", Name: " Vendas SP "})
+	require.NoError(t, err)
+	assert.Equal(t, "VEN-SP", center.Code)
+	assert.Equal(t, "Vendas SP", center.Name)
+	assert.True(t, center.Active)
+}
@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/exchange"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxFetchDays limita a consulta das cotações ao buscar uma moeda pela primeira vez ou depois
+	// de uma longa parada do job
+	maxFetchDays = 30
+	// maxRateAge é a idade máxima da cotação usada para uma data sem cotação publicada (fins de
+	// semana e feriados)
+	maxRateAge = 10 * 24 * time.Hour
+)
+
+// ExchangeService consulta e guarda as cotações diárias, escolhe a cotação pela data do documento
+// e reavalia no fim do mês as faturas em aberto em moeda estrangeira
+type ExchangeService struct {
+	newRepo    func() (repository.ExchangeRepository, error)
+	provider   exchange.Provider
+	currencies []string
+	now        func() time.Time
+	logger     *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.ExchangeRepository
+}
+
+// NewExchangeService cria o serviço sobre o repositório e o provedor informados; currencies são as
+// moedas consultadas pelo job
+func NewExchangeService(newRepo func() (repository.ExchangeRepository, error), provider exchange.Provider, currencies []string) *ExchangeService {
+	return &ExchangeService{
+		newRepo:    newRepo,
+		provider:   provider,
+		currencies: currencies,
+		now:        time.Now,
+		logger:     logger.WithModule("exchange_service"),
+	}
+}
+
+var (
+	defaultExchangeOnce    sync.Once
+	defaultExchangeService *ExchangeService
+)
+
+// exchangeService monta o serviço padrão com os provedores e as moedas configurados na primeira
+// chamada, depois do carregamento da configuração
+func exchangeService() *ExchangeService {
+	defaultExchangeOnce.Do(func() {
+		var currencies []string
+		for _, code := range strings.Split(viper.GetString("EXCHANGE_RATE_CURRENCIES"), ",") {
+			if currency, err := models.NormalizeCurrency(code); err == nil {
+				currencies = append(currencies, currency)
+			}
+		}
+		defaultExchangeService = NewExchangeService(repository.NewExchangeRepository, exchange.FromConfig(), currencies)
+	})
+	return defaultExchangeService
+}
+
+// ListExchangeRates lista o histórico de cotações
+func ListExchangeRates(ctx context.Context, currency, from, to string) ([]models.ExchangeRate, error) {
+	return exchangeService().ListRates(ctx, currency, from, to)
+}
+
+// LookupExchangeRate busca a cotação da moeda válida na data do documento
+func LookupExchangeRate(ctx context.Context, currency, date string) (*models.ExchangeRate, error) {
+	return exchangeService().LookupRate(ctx, currency, date)
+}
+
+// SetExchangeRate grava uma cotação informada manualmente
+func SetExchangeRate(ctx context.Context, input models.ExchangeRateInput) (*models.ExchangeRate, error) {
+	return exchangeService().SetRate(ctx, input)
+}
+
+// FetchExchangeRates consulta as cotações que faltam das moedas configuradas
+func FetchExchangeRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	return exchangeService().FetchRates(ctx)
+}
+
+// SetInvoiceCurrency marca a fatura como emitida em moeda estrangeira
+func SetInvoiceCurrency(ctx context.Context, invoiceID int, input models.InvoiceCurrencyInput) (*models.InvoiceCurrency, error) {
+	return exchangeService().SetInvoiceCurrency(ctx, invoiceID, input)
+}
+
+// GetInvoiceCurrency retorna a moeda estrangeira da fatura
+func GetInvoiceCurrency(ctx context.Context, invoiceID int) (*models.InvoiceCurrency, error) {
+	return exchangeService().GetInvoiceCurrency(ctx, invoiceID)
+}
+
+// RevalueForeignInvoices reavalia as faturas em moeda estrangeira pela cotação do fim do mês
+func RevalueForeignInvoices(ctx context.Context, period string) (*models.RevaluationRun, error) {
+	return exchangeService().RevaluePeriod(ctx, period)
+}
+
+// GetRevaluations retorna a reavaliação cambial já feita no mês
+func GetRevaluations(ctx context.Context, period string) (*models.RevaluationRun, error) {
+	return exchangeService().Revaluations(ctx, period)
+}
+
+// StartExchangeRateScheduler consulta periodicamente as cotações das moedas configuradas em
+// EXCHANGE_RATE_CURRENCIES e, encerrado o mês, reavalia as faturas em aberto em moeda estrangeira
+// de todas as empresas
+func StartExchangeRateScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("exchange_rate_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := exchangeService().runScheduled(tenant.AllCompanies(ctx))
+				metrics.ObserveJob("exchange_rate_scheduler", err)
+				if err != nil {
+					log.Error("erro na atualização das cotações", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+func (s *ExchangeService) repository() (repository.ExchangeRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// runScheduled consulta as cotações e reavalia o mês anterior quando ainda não foi reavaliado
+func (s *ExchangeService) runScheduled(ctx context.Context) error {
+	_, fetchErr := s.FetchRates(ctx)
+
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	today := s.now()
+	period := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format(models.MonthLayout)
+	done, err := repo.HasRevaluations(ctx, period)
+	if err != nil {
+		return err
+	}
+	if !done {
+		run, err := s.RevaluePeriod(ctx, period)
+		if err != nil {
+			return err
+		}
+		if run.Invoices > 0 {
+			s.logger.Info("faturas em moeda estrangeira reavaliadas", zap.String("period", period),
+				zap.Int("invoices", run.Invoices), zap.String("variance", run.TotalVariance.String()))
+		}
+	}
+	return fetchErr
+}
+
+// ListRates lista o histórico de cotações; currency, from e to (AAAA-MM-DD) são opcionais
+func (s *ExchangeService) ListRates(ctx context.Context, currency, from, to string) ([]models.ExchangeRate, error) {
+	var filter models.ExchangeRateFilter
+	if currency != "" {
+		code, err := models.NormalizeCurrency(currency)
+		if err != nil {
+			return nil, err
+		}
+		filter.Currency = code
+	}
+	for _, bound := range []struct {
+		value string
+		date  *time.Time
+	}{{from, &filter.From}, {to, &filter.To}} {
+		if bound.value == "" {
+			continue
+		}
+		date, err := time.Parse(exchange.DateLayout, bound.value)
+		if err != nil {
+			return nil, errors.ErrInvalidRequest
+		}
+		*bound.date = date
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListRates(ctx, filter)
+}
+
+// LookupRate busca a cotação da moeda na data (AAAA-MM-DD) ou a do último dia anterior com
+// cotação
+func (s *ExchangeService) LookupRate(ctx context.Context, currency, date string) (*models.ExchangeRate, error) {
+	code, err := models.NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	day, err := time.Parse(exchange.DateLayout, date)
+	if err != nil {
+		return nil, errors.ErrInvalidRequest
+	}
+	return s.rateAt(ctx, code, day)
+}
+
+// rateAt escolhe a cotação válida no dia: a do próprio dia ou a do último dia anterior com
+// cotação, desde que não mais antiga que maxRateAge
+func (s *ExchangeService) rateAt(ctx context.Context, currency string, day time.Time) (*models.ExchangeRate, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	rate, err := repo.FindRate(ctx, currency, day)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil || day.Sub(rate.RateDate) > maxRateAge {
+		return nil, errors.ErrExchangeRateNotFound
+	}
+	return rate, nil
+}
+
+// SetRate grava a cotação informada manualmente; ela prevalece sobre a consultada no mesmo dia
+func (s *ExchangeService) SetRate(ctx context.Context, input models.ExchangeRateInput) (*models.ExchangeRate, error) {
+	code, err := models.NormalizeCurrency(input.Currency)
+	if err != nil {
+		return nil, err
+	}
+	day, err := time.Parse(exchange.DateLayout, input.Date)
+	if err != nil {
+		return nil, errors.ErrInvalidRequest
+	}
+	if input.Rate <= 0 {
+		return nil, errors.ErrInvalidRequest
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	rate := &models.ExchangeRate{
+		Currency:  code,
+		RateDate:  day,
+		Rate:      input.Rate,
+		Source:    exchange.ProviderManual,
+		FetchedAt: s.now(),
+	}
+	if err := repo.SaveManualRate(ctx, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// FetchRates consulta, para cada moeda configurada, as cotações dos dias seguintes à última
+// guardada até hoje. A falha de uma moeda não impede as demais; o erro é devolvido no fim.
+func (s *ExchangeService) FetchRates(ctx context.Context) ([]models.ExchangeRate, error) {
+	if s.provider == nil {
+		return nil, errors.ErrExchangeRateUnavailable
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var (
+		saved    []models.ExchangeRate
+		fetchErr error
+	)
+	for _, currency := range s.currencies {
+		from := today.AddDate(0, 0, -maxFetchDays)
+		last, err := repo.LastRateDate(ctx, currency)
+		if err != nil {
+			return saved, err
+		}
+		if last != nil && !last.Before(from) {
+			from = last.AddDate(0, 0, 1)
+		}
+		if from.After(today) {
+			continue
+		}
+
+		rates, err := s.provider.Rates(ctx, currency, from, today)
+		if err != nil {
+			s.logger.Warn("falha ao consultar cotações", zap.String("currency", currency), zap.Error(err))
+			fetchErr = err
+			continue
+		}
+		fetched := make([]models.ExchangeRate, 0, len(rates))
+		for _, rate := range rates {
+			fetched = append(fetched, models.ExchangeRate{
+				Currency:  currency,
+				RateDate:  rate.Date,
+				Rate:      rate.Rate,
+				Source:    rate.Source,
+				FetchedAt: now,
+			})
+		}
+		if err := repo.SaveRates(ctx, fetched); err != nil {
+			return saved, err
+		}
+		saved = append(saved, fetched...)
+	}
+	return saved, fetchErr
+}
+
+// SetInvoiceCurrency marca a fatura como emitida na moeda. Sem cotação informada, vale a da data
+// de emissão; o valor na moeda é o total da fatura convertido pela cotação.
+func (s *ExchangeService) SetInvoiceCurrency(ctx context.Context, invoiceID int, input models.InvoiceCurrencyInput) (*models.InvoiceCurrency, error) {
+	code, err := models.NormalizeCurrency(input.Currency)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	invoice, err := repo.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := invoice.IssueDate
+	if issued.IsZero() {
+		issued = invoice.CreatedAt
+	}
+	issued = time.Date(issued.Year(), issued.Month(), issued.Day(), 0, 0, 0, 0, time.UTC)
+	rate, rateDate := 0.0, issued
+	if input.Rate != nil {
+		rate = *input.Rate
+	} else {
+		found, err := s.rateAt(ctx, code, issued)
+		if err != nil {
+			return nil, err
+		}
+		rate, rateDate = found.Rate, found.RateDate
+	}
+	if rate <= 0 {
+		return nil, errors.ErrInvalidRequest
+	}
+
+	currency := &models.InvoiceCurrency{
+		CompanyID:     invoice.CompanyID,
+		InvoiceID:     invoice.ID,
+		Currency:      code,
+		ForeignAmount: models.ToForeign(invoice.GrandTotal, rate, code),
+		IssueRate:     rate,
+		RateDate:      rateDate,
+	}
+	if err := repo.SaveInvoiceCurrency(ctx, currency); err != nil {
+		return nil, err
+	}
+	return currency, nil
+}
+
+// GetInvoiceCurrency retorna a moeda estrangeira da fatura
+func (s *ExchangeService) GetInvoiceCurrency(ctx context.Context, invoiceID int) (*models.InvoiceCurrency, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GetInvoice(ctx, invoiceID); err != nil {
+		return nil, err
+	}
+	return repo.GetInvoiceCurrency(ctx, invoiceID)
+}
+
+// RevaluePeriod reavalia pela cotação do último dia do mês (ou do último dia anterior com
+// cotação) o saldo das faturas em moeda estrangeira emitidas até o fim do mês e ainda em aberto.
+// Refazer o mês substitui a reavaliação anterior.
+func (s *ExchangeService) RevaluePeriod(ctx context.Context, period string) (*models.RevaluationRun, error) {
+	end, err := models.RevaluationDate(period, s.now())
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	invoices, err := repo.OpenForeignInvoices(ctx, end, period)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := map[string]*models.ExchangeRate{}
+	used := map[string]float64{}
+	revaluations := make([]models.InvoiceRevaluation, 0, len(invoices))
+	for _, invoice := range invoices {
+		rate, ok := rates[invoice.Currency]
+		if !ok {
+			rate, err = s.rateAt(ctx, invoice.Currency, end)
+			if err != nil {
+				return nil, err
+			}
+			rates[invoice.Currency] = rate
+			used[invoice.Currency] = rate.Rate
+		}
+		revaluations = append(revaluations, models.BuildRevaluation(invoice, period, *rate))
+	}
+
+	if err := repo.SaveRevaluations(ctx, period, revaluations); err != nil {
+		return nil, err
+	}
+	return models.BuildRevaluationRun(period, used, revaluations), nil
+}
+
+// Revaluations retorna a reavaliação cambial já feita no mês
+func (s *ExchangeService) Revaluations(ctx context.Context, period string) (*models.RevaluationRun, error) {
+	if !models.ValidMonth(period) {
+		return nil, errors.ErrInvalidRevaluationPeriod
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	revaluations, err := repo.ListRevaluations(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	rates := map[string]float64{}
+	for _, revaluation := range revaluations {
+		rates[revaluation.Currency] = revaluation.Rate
+	}
+	return models.BuildRevaluationRun(period, rates, revaluations), nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/integrations/exchange"
+	"ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/finance/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExchangeRepo struct {
+	rates        []models.ExchangeRate
+	invoices     map[int]*sales.Invoice
+	currencies   map[int]*models.InvoiceCurrency
+	open         []models.OpenForeignInvoice
+	revaluations map[string][]models.InvoiceRevaluation
+	openUntil    time.Time
+}
+
+func (r *fakeExchangeRepo) ListRates(ctx context.Context, filter models.ExchangeRateFilter) ([]models.ExchangeRate, error) {
+	return r.rates, nil
+}
+
+func (r *fakeExchangeRepo) FindRate(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error) {
+	var found *models.ExchangeRate
+	for i, rate := range r.rates {
+		if rate.Currency == currency && !rate.RateDate.After(date) && (found == nil || rate.RateDate.After(found.RateDate)) {
+			found = &r.rates[i]
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeExchangeRepo) LastRateDate(ctx context.Context, currency string) (*time.Time, error) {
+	var last *time.Time
+	for _, rate := range r.rates {
+		if rate.Currency == currency && (last == nil || rate.RateDate.After(*last)) {
+			date := rate.RateDate
+			last = &date
+		}
+	}
+	return last, nil
+}
+
+func (r *fakeExchangeRepo) SaveRates(ctx context.Context, rates []models.ExchangeRate) error {
+	r.rates = append(r.rates, rates...)
+	return nil
+}
+
+func (r *fakeExchangeRepo) SaveManualRate(ctx context.Context, rate *models.ExchangeRate) error {
+	r.rates = append(r.rates, *rate)
+	return nil
+}
+
+func (r *fakeExchangeRepo) GetInvoice(ctx context.Context, id int) (*sales.Invoice, error) {
+	invoice, ok := r.invoices[id]
+	if !ok {
+		return nil, appErrors.ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (r *fakeExchangeRepo) GetInvoiceCurrency(ctx context.Context, invoiceID int) (*models.InvoiceCurrency, error) {
+	currency, ok := r.currencies[invoiceID]
+	if !ok {
+		return nil, appErrors.ErrInvoiceCurrencyNotFound
+	}
+	return currency, nil
+}
+
+func (r *fakeExchangeRepo) SaveInvoiceCurrency(ctx context.Context, currency *models.InvoiceCurrency) error {
+	if r.currencies == nil {
+		r.currencies = map[int]*models.InvoiceCurrency{}
+	}
+	r.currencies[currency.InvoiceID] = currency
+	return nil
+}
+
+func (r *fakeExchangeRepo) OpenForeignInvoices(ctx context.Context, until time.Time, period string) ([]models.OpenForeignInvoice, error) {
+	r.openUntil = until
+	return r.open, nil
+}
+
+func (r *fakeExchangeRepo) SaveRevaluations(ctx context.Context, period string, revaluations []models.InvoiceRevaluation) error {
+	if r.revaluations == nil {
+		r.revaluations = map[string][]models.InvoiceRevaluation{}
+	}
+	r.revaluations[period] = revaluations
+	return nil
+}
+
+func (r *fakeExchangeRepo) ListRevaluations(ctx context.Context, period string) ([]models.InvoiceRevaluation, error) {
+	return r.revaluations[period], nil
+}
+
+func (r *fakeExchangeRepo) HasRevaluations(ctx context.Context, period string) (bool, error) {
+	return len(r.revaluations[period]) > 0, nil
+}
+
+type rateCall struct {
+	currency string
+	from, to time.Time
+}
+
+type stubRateProvider struct {
+	rates map[string][]exchange.Rate
+	calls []rateCall
+}
+
+func (p *stubRateProvider) Name() string { return "stub" }
+
+func (p *stubRateProvider) Rates(ctx context.Context, currency string, from, to time.Time) ([]exchange.Rate, error) {
+	p.calls = append(p.calls, rateCall{currency: currency, from: from, to: to})
+	return p.rates[currency], nil
+}
+
+func rateDay(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func newTestExchangeService(repo *fakeExchangeRepo, provider exchange.Provider, now time.Time) *ExchangeService {
+	svc := NewExchangeService(func() (repository.ExchangeRepository, error) { return repo, nil }, provider, []string{"USD", "EUR"})
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestFetchRatesStartsAfterLastStoredDay(t *testing.T) {
+	repo := &fakeExchangeRepo{rates: []models.ExchangeRate{
+		{Currency: "USD", RateDate: rateDay(2026, 10, 13), Rate: 5.41, Source: exchange.ProviderPTAX},
+	}}
+	provider := &stubRateProvider{rates: map[string][]exchange.Rate{
+		"USD": {
+			{Currency: "USD", Date: rateDay(2026, 10, 14), Rate: 5.44, Source: exchange.ProviderPTAX},
+			{Currency: "USD", Date: rateDay(2026, 10, 15), Rate: 5.45, Source: exchange.ProviderPTAX},
+		},
+	}}
+	svc := newTestExchangeService(repo, provider, time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC))
+
+	saved, err := svc.FetchRates(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, saved, 2)
+	require.Len(t, provider.calls, 2)
+	assert.Equal(t, rateCall{currency: "USD", from: rateDay(2026, 10, 14), to: rateDay(2026, 10, 15)}, provider.calls[0])
+	// Moeda sem histórico: consulta os últimos maxFetchDays dias
+	assert.Equal(t, rateCall{currency: "EUR", from: rateDay(2026, 9, 15), to: rateDay(2026, 10, 15)}, provider.calls[1])
+}
+
+func TestLookupRateUsesLastPreviousDay(t *testing.T) {
+	repo := &fakeExchangeRepo{rates: []models.ExchangeRate{
+		{Currency: "USD", RateDate: rateDay(2026, 10, 9), Rate: 5.40},
+		{Currency: "USD", RateDate: rateDay(2026, 10, 16), Rate: 5.46},
+	}}
+	svc := newTestExchangeService(repo, nil, rateDay(2026, 10, 20))
+
+	// Sábado usa a cotação de sexta-feira
+	rate, err := svc.LookupRate(context.Background(), "usd", "2026-10-10")
+	require.NoError(t, err)
+	assert.Equal(t, 5.40, rate.Rate)
+
+	_, err = svc.LookupRate(context.Background(), "USD", "2026-10-08")
+	assert.ErrorIs(t, err, appErrors.ErrExchangeRateNotFound)
+	_, err = svc.LookupRate(context.Background(), "EUR", "2026-10-10")
+	assert.ErrorIs(t, err, appErrors.ErrExchangeRateNotFound)
+	_, err = svc.LookupRate(context.Background(), "USD", "10/10/2026")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidRequest)
+}
+
+func TestSetInvoiceCurrencyUsesIssueDateRate(t *testing.T) {
+	repo := &fakeExchangeRepo{
+		rates: []models.ExchangeRate{{Currency: "USD", RateDate: rateDay(2026, 9, 4), Rate: 5}},
+		invoices: map[int]*sales.Invoice{
+			3: {ID: 3, CompanyID: 2, IssueDate: rateDay(2026, 9, 5), GrandTotal: money.FromInt(5000)},
+		},
+	}
+	svc := newTestExchangeService(repo, nil, rateDay(2026, 10, 1))
+
+	currency, err := svc.SetInvoiceCurrency(context.Background(), 3, models.InvoiceCurrencyInput{Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, currency.CompanyID)
+	assert.Equal(t, 5.0, currency.IssueRate)
+	assert.Equal(t, rateDay(2026, 9, 4), currency.RateDate)
+	assert.Equal(t, "1000.00", currency.ForeignAmount.String())
+
+	_, err = svc.SetInvoiceCurrency(context.Background(), 3, models.InvoiceCurrencyInput{Currency: "BRL"})
+	assert.ErrorIs(t, err, appErrors.ErrInvalidCurrency)
+	_, err = svc.SetInvoiceCurrency(context.Background(), 9, models.InvoiceCurrencyInput{Currency: "USD"})
+	assert.ErrorIs(t, err, appErrors.ErrInvoiceNotFound)
+}
+
+func TestRevaluePeriodUsesMonthEndRate(t *testing.T) {
+	repo := &fakeExchangeRepo{
+		rates: []models.ExchangeRate{
+			{Currency: "USD", RateDate: rateDay(2026, 9, 25), Rate: 5.3},
+			// 30/09 sem cotação: vale a do último dia útil
+			{Currency: "USD", RateDate: rateDay(2026, 9, 29), Rate: 5.4},
+			{Currency: "USD", RateDate: rateDay(2026, 10, 1), Rate: 5.6},
+		},
+		open: []models.OpenForeignInvoice{{
+			InvoiceID: 3, CompanyID: 2, GrandTotal: money.FromInt(5000), Currency: "USD",
+			ForeignAmount: money.FromInt(1000), IssueRate: 5,
+		}},
+	}
+	svc := newTestExchangeService(repo, nil, rateDay(2026, 10, 2))
+
+	run, err := svc.RevaluePeriod(context.Background(), "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, rateDay(2026, 9, 30), repo.openUntil)
+	assert.Equal(t, map[string]float64{"USD": 5.4}, run.Rates)
+	assert.Equal(t, 1, run.Invoices)
+	assert.Equal(t, "400.00", run.TotalVariance.String())
+	assert.Len(t, repo.revaluations["2026-09"], 1)
+
+	_, err = svc.RevaluePeriod(context.Background(), "2026-10")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidRevaluationPeriod)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/followup/models"
+	"ERP-ONSMART/backend/internal/modules/followup/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as regras de acompanhamento dos processos de venda parados
+// @Security BearerAuth
+func ListFollowupRulesHandler(c *gin.Context) {
+	rules, err := service.ListRules(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar regras de acompanhamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// Cadastra uma regra para os processos sem atualização há mais de idle_days dias: task (tarefa de
+// retorno para o vendedor, com vencimento em task_due_days), notify (lembrete por e-mail ao
+// vendedor) ou cancel (cancela o processo, usado como prazo máximo)
+// @Security BearerAuth
+func CreateFollowupRuleHandler(c *gin.Context) {
+	var input models.RuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	rule, err := service.CreateRule(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar regra de acompanhamento")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rule": rule})
+}
+
+// Altera uma regra de acompanhamento
+// @Security BearerAuth
+// @Param id path int true "ID da regra"
+func UpdateFollowupRuleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.RuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	rule, err := service.UpdateRule(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar regra de acompanhamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+// Remove uma regra de acompanhamento; as tarefas criadas e o registro das ações ficam
+// @Security BearerAuth
+// @Param id path int true "ID da regra"
+func DeleteFollowupRuleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteRule(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover regra de acompanhamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Regra de acompanhamento removida com sucesso"})
+}
+
+// Executa na hora as regras ativas da empresa, sem esperar a rotina periódica
+// @Security BearerAuth
+func RunFollowupHandler(c *gin.Context) {
+	result, err := service.RunCompany(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao executar regras de acompanhamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+// Registro de auditoria das ações executadas pelas regras (tarefas, lembretes e cancelamentos)
+// @Security BearerAuth
+// @Param process_id query int false "ID do processo de venda"
+func ListFollowupLogHandler(c *gin.Context) {
+	processID, ok := parseOptionalIntQuery(c, "process_id")
+	if !ok {
+		return
+	}
+
+	entries, err := service.ListLog(c.Request.Context(), processID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar ações de acompanhamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+func parseOptionalIntQuery(c *gin.Context, name string) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		c.Error(errors.InvalidParam(name + " inválido"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,260 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ações das regras de acompanhamento dos processos de venda parados
+const (
+	// ActionTask cria uma tarefa de retorno para o vendedor do processo
+	ActionTask = "task"
+	// ActionNotify avisa o vendedor por e-mail
+	ActionNotify = "notify"
+	// ActionCancel cancela o processo parado além do prazo máximo
+	ActionCancel = "cancel"
+)
+
+// DefaultTaskDueDays é o prazo da tarefa de retorno quando a regra não informa o seu
+const DefaultTaskDueDays = 2
+
+// OpenStages são as etapas do processo de venda que as regras acompanham (concluídos e
+// cancelados não ficam parados)
+var OpenStages = []string{"draft", "quotation", "sales_order", "purchase", "delivery", "invoicing", "payment"}
+
+// Rule é uma regra de automação: para os processos sem atualização há mais de IdleDays dias
+// (opcionalmente só nas etapas informadas), executa a ação uma vez a cada período parado
+type Rule struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	CompanyID   int       `json:"company_id" gorm:"<-:create"`
+	Name        string    `json:"name"`
+	IdleDays    int       `json:"idle_days"`
+	Action      string    `json:"action"`
+	Stages      []string  `json:"stages" gorm:"serializer:json"`
+	TaskDueDays int       `json:"task_due_days"`
+	Active      bool      `json:"active"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Rule) TableName() string {
+	return "followup_rules"
+}
+
+// RuleInput é o corpo aceito na criação e alteração das regras
+type RuleInput struct {
+	Name        string   `json:"name" binding:"required"`
+	IdleDays    int      `json:"idle_days" binding:"required,min=1"`
+	Action      string   `json:"action" binding:"required,oneof=task notify cancel"`
+	Stages      []string `json:"stages"`
+	TaskDueDays int      `json:"task_due_days" binding:"min=0"`
+	Active      *bool    `json:"active"`
+}
+
+// Validate confere a ação, o prazo e as etapas da regra
+func (in RuleInput) Validate() error {
+	if strings.TrimSpace(in.Name) == "" {
+		return fmt.Errorf("%w: informe o nome da regra", errors.ErrInvalidFollowupRule)
+	}
+	if in.IdleDays < 1 {
+		return fmt.Errorf("%w: informe os dias sem atualização (mínimo 1)", errors.ErrInvalidFollowupRule)
+	}
+	switch in.Action {
+	case ActionTask, ActionNotify, ActionCancel:
+	default:
+		return fmt.Errorf("%w: ação deve ser task, notify ou cancel", errors.ErrInvalidFollowupRule)
+	}
+	if in.TaskDueDays < 0 {
+		return fmt.Errorf("%w: prazo da tarefa não pode ser negativo", errors.ErrInvalidFollowupRule)
+	}
+	for _, stage := range in.Stages {
+		if !isOpenStage(stage) {
+			return fmt.Errorf("%w: etapa %q não acompanhada (aceitas: %s)", errors.ErrInvalidFollowupRule,
+				stage, strings.Join(OpenStages, ", "))
+		}
+	}
+	return nil
+}
+
+// Apply copia os campos da entrada para a regra; sem active, a regra nova fica ativa
+func (in RuleInput) Apply(rule *Rule) {
+	rule.Name = strings.TrimSpace(in.Name)
+	rule.IdleDays = in.IdleDays
+	rule.Action = in.Action
+	rule.Stages = append([]string{}, in.Stages...)
+	rule.TaskDueDays = in.TaskDueDays
+	if in.Active != nil {
+		rule.Active = *in.Active
+	} else if rule.ID == 0 {
+		rule.Active = true
+	}
+}
+
+// LogEntry é o registro de auditoria de uma ação executada por uma regra. IdleSince é a última
+// atualização do processo quando a regra agiu: a mesma regra só age de novo no processo depois
+// que ele for atualizado e voltar a ficar parado.
+type LogEntry struct {
+	ID             int       `json:"id" gorm:"primaryKey"`
+	CompanyID      int       `json:"company_id" gorm:"<-:create"`
+	RuleID         int       `json:"rule_id"`
+	SalesProcessID int       `json:"sales_process_id"`
+	Action         string    `json:"action"`
+	IdleSince      time.Time `json:"idle_since"`
+	IdleDays       int       `json:"idle_days"`
+	TaskID         *int      `json:"task_id,omitempty"`
+	Recipient      string    `json:"recipient,omitempty"`
+	Message        string    `json:"message"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (LogEntry) TableName() string {
+	return "followup_log"
+}
+
+// IdleProcess é um processo de venda parado com o vendedor responsável: o do pedido de venda
+// mais recente ligado ao processo ou, sem pedido, o da cotação mais recente
+type IdleProcess struct {
+	ProcessID        int       `json:"process_id"`
+	ContactID        int       `json:"contact_id"`
+	ContactName      string    `json:"contact_name"`
+	Status           string    `json:"status"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	SalespersonID    *int      `json:"salesperson_id,omitempty"`
+	SalespersonName  string    `json:"salesperson_name,omitempty"`
+	SalespersonEmail string    `json:"salesperson_email,omitempty"`
+}
+
+// PlannedAction é uma ação de uma regra a executar em um processo parado
+type PlannedAction struct {
+	Rule     Rule
+	Process  IdleProcess
+	IdleDays int
+}
+
+// RunResult resume uma execução das regras
+type RunResult struct {
+	Tasks         int `json:"tasks"`
+	Notifications int `json:"notifications"`
+	Cancelled     int `json:"cancelled"`
+	Skipped       int `json:"skipped"`
+}
+
+// LogKey identifica a execução de uma regra em um período parado do processo
+type LogKey struct {
+	RuleID    int
+	ProcessID int
+	IdleSince time.Time
+}
+
+// Key retorna a chave da ação para conferir se a regra já agiu no período parado
+func (a PlannedAction) Key() LogKey {
+	return LogKey{RuleID: a.Rule.ID, ProcessID: a.Process.ProcessID, IdleSince: a.Process.UpdatedAt.UTC()}
+}
+
+// IdleDays conta os dias de calendário, no fuso informado, entre a última atualização e now
+func IdleDays(updatedAt, now time.Time, loc *time.Location) int {
+	from := dateOf(updatedAt.In(loc))
+	to := dateOf(now.In(loc))
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// Matches indica se a regra ativa se aplica ao processo parado há idleDays dias: mais dias do que
+// os da regra, como em GetAbandonedProcesses, e na etapa da regra quando ela restringe etapas
+func (r Rule) Matches(process IdleProcess, idleDays int) bool {
+	if !r.Active || idleDays <= r.IdleDays {
+		return false
+	}
+	if len(r.Stages) == 0 {
+		return isOpenStage(process.Status)
+	}
+	for _, stage := range r.Stages {
+		if stage == process.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanActions escolhe as ações das regras para os processos parados, sem as que já foram
+// executadas no mesmo período parado (done). Quando uma regra de cancelamento se aplica, as
+// demais ações do processo não são executadas; entre as de cancelamento vale a de menor prazo.
+func PlanActions(rules []Rule, processes []IdleProcess, done map[LogKey]bool, now time.Time, loc *time.Location) []PlannedAction {
+	sorted := append([]Rule(nil), rules...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].IdleDays != sorted[j].IdleDays {
+			return sorted[i].IdleDays < sorted[j].IdleDays
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	var planned []PlannedAction
+	for _, process := range processes {
+		days := IdleDays(process.UpdatedAt, now, loc)
+		var actions []PlannedAction
+		var cancel *PlannedAction
+		for _, rule := range sorted {
+			if !rule.Matches(process, days) {
+				continue
+			}
+			action := PlannedAction{Rule: rule, Process: process, IdleDays: days}
+			if rule.Action == ActionCancel {
+				if cancel == nil {
+					cancel = &action
+				}
+				continue
+			}
+			if !done[action.Key()] {
+				actions = append(actions, action)
+			}
+		}
+		if cancel != nil {
+			if !done[cancel.Key()] {
+				planned = append(planned, *cancel)
+			}
+			continue
+		}
+		planned = append(planned, actions...)
+	}
+	return planned
+}
+
+// TaskTitle é o título da tarefa de retorno criada pela regra
+func TaskTitle(process IdleProcess, idleDays int) string {
+	name := process.ContactName
+	if name == "" {
+		name = fmt.Sprintf("contato %d", process.ContactID)
+	}
+	return fmt.Sprintf("Retomar processo de venda %d com %s (parado há %d dias)", process.ProcessID, name, idleDays)
+}
+
+// TaskDueDate é o vencimento da tarefa: today mais o prazo da regra (ou o padrão)
+func TaskDueDate(rule Rule, today time.Time) time.Time {
+	days := rule.TaskDueDays
+	if days == 0 {
+		days = DefaultTaskDueDays
+	}
+	return dateOf(today).AddDate(0, 0, days)
+}
+
+// CancelNote é o texto acrescentado às observações do processo cancelado pela regra
+func CancelNote(rule Rule, idleDays int, now time.Time) string {
+	return fmt.Sprintf("Cancelado automaticamente em %s pela regra %q: %d dias sem atualização (máximo %d).",
+		now.Format("02/01/2006"), rule.Name, idleDays, rule.IdleDays)
+}
+
+func isOpenStage(stage string) bool {
+	for _, open := range OpenStages {
+		if open == stage {
+			return true
+		}
+	}
+	return false
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
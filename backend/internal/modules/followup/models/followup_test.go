@@ -0,0 +1,90 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var saoPaulo = time.FixedZone("BRT", -3*3600)
+
+func TestRuleInputValidate(t *testing.T) {
+	valid := RuleInput{Name: "Lembrete", IdleDays: 7, Action: ActionNotify, Stages: []string{"quotation"}}
+	assert.NoError(t, valid.Validate())
+
+	cases := []RuleInput{
+		{Name: " ", IdleDays: 7, Action: ActionNotify},
+		{Name: "Sem prazo", IdleDays: 0, Action: ActionNotify},
+		{Name: "Ação", IdleDays: 7, Action: "archive"},
+		{Name: "Etapa", IdleDays: 7, Action: ActionTask, Stages: []string{"completed"}},
+		{Name: "Prazo", IdleDays: 7, Action: ActionTask, TaskDueDays: -1},
+	}
+	for _, input := range cases {
+		assert.True(t, stderrors.Is(input.Validate(), errors.ErrInvalidFollowupRule), input.Name)
+	}
+}
+
+func TestIdleDaysCountsCompanyCalendarDays(t *testing.T) {
+	// 01:00 UTC do dia 16 ainda é dia 15 em São Paulo
+	now := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC)
+	updated := time.Date(2026, 10, 9, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 6, IdleDays(updated, now, saoPaulo))
+	assert.Equal(t, 7, IdleDays(updated, now, time.UTC))
+}
+
+func TestRuleMatches(t *testing.T) {
+	rule := Rule{ID: 1, IdleDays: 7, Action: ActionTask, Active: true}
+	process := IdleProcess{ProcessID: 1, Status: "quotation"}
+
+	assert.False(t, rule.Matches(process, 7))
+	assert.True(t, rule.Matches(process, 8))
+	assert.False(t, rule.Matches(IdleProcess{Status: "completed"}, 30))
+
+	rule.Stages = []string{"sales_order"}
+	assert.False(t, rule.Matches(process, 8))
+
+	rule.Stages = nil
+	rule.Active = false
+	assert.False(t, rule.Matches(process, 8))
+}
+
+func TestPlanActions(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	rules := []Rule{
+		{ID: 3, IdleDays: 30, Action: ActionCancel, Active: true},
+		{ID: 1, IdleDays: 7, Action: ActionTask, Active: true},
+		{ID: 2, IdleDays: 14, Action: ActionNotify, Active: true},
+	}
+	idle10 := IdleProcess{ProcessID: 10, Status: "quotation", UpdatedAt: now.AddDate(0, 0, -10)}
+	idle20 := IdleProcess{ProcessID: 20, Status: "sales_order", UpdatedAt: now.AddDate(0, 0, -20)}
+	idle40 := IdleProcess{ProcessID: 40, Status: "delivery", UpdatedAt: now.AddDate(0, 0, -40)}
+
+	// a tarefa do processo 20 já foi criada neste período parado
+	done := map[LogKey]bool{{RuleID: 1, ProcessID: 20, IdleSince: idle20.UpdatedAt}: true}
+	planned := PlanActions(rules, []IdleProcess{idle10, idle20, idle40}, done, now, time.UTC)
+
+	var got []string
+	for _, action := range planned {
+		got = append(got, action.Rule.Action)
+		assert.Equal(t, IdleDays(action.Process.UpdatedAt, now, time.UTC), action.IdleDays)
+	}
+	// processo 10: tarefa; 20: só o lembrete; 40: cancelamento, sem tarefa nem lembrete
+	assert.Equal(t, []string{ActionTask, ActionNotify, ActionCancel}, got)
+	assert.Equal(t, []int{10, 20, 40}, []int{planned[0].Process.ProcessID, planned[1].Process.ProcessID, planned[2].Process.ProcessID})
+
+	// o mesmo período parado não é cancelado duas vezes
+	done[planned[2].Key()] = true
+	planned = PlanActions(rules, []IdleProcess{idle40}, done, now, time.UTC)
+	assert.Empty(t, planned)
+}
+
+func TestTaskDueDate(t *testing.T) {
+	today := time.Date(2026, 10, 16, 21, 30, 0, 0, saoPaulo)
+
+	assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), TaskDueDate(Rule{}, today))
+	assert.Equal(t, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC), TaskDueDate(Rule{TaskDueDays: 5}, today))
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/followup/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	tasks "ERP-ONSMART/backend/internal/modules/tasks/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// FollowupRepository mantém as regras de acompanhamento e o registro das ações executadas nos
+// processos de venda parados, e grava as tarefas de retorno no módulo de tarefas
+type FollowupRepository interface {
+	ListRules(ctx context.Context) ([]models.Rule, error)
+	GetRule(ctx context.Context, id int) (*models.Rule, error)
+	CreateRule(ctx context.Context, rule *models.Rule) error
+	UpdateRule(ctx context.Context, rule *models.Rule) error
+	DeleteRule(ctx context.Context, id int) error
+	ActiveRules(ctx context.Context) ([]models.Rule, error)
+
+	IdleProcesses(ctx context.Context, cutoff time.Time) ([]models.IdleProcess, error)
+	LoggedKeys(ctx context.Context, processIDs []int) (map[models.LogKey]bool, error)
+	CreateTask(ctx context.Context, task *tasks.Task, entry *models.LogEntry) error
+	CancelProcess(ctx context.Context, idleSince time.Time, note string, entry *models.LogEntry, now time.Time) (bool, error)
+	CreateLog(ctx context.Context, entry *models.LogEntry) error
+	ListLog(ctx context.Context, processID int) ([]models.LogEntry, error)
+}
+
+type followupRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewFollowupRepository cria uma nova instância do repositório
+func NewFollowupRepository() (FollowupRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &followupRepository{
+		db:     db,
+		logger: logger.WithModule("followup_repository"),
+	}, nil
+}
+
+// ListRules lista as regras da empresa pelo prazo
+func (r *followupRepository) ListRules(ctx context.Context) ([]models.Rule, error) {
+	var rules []models.Rule
+	if err := db.Conn(ctx, r.db).Order("idle_days ASC, id ASC").Find(&rules).Error; err != nil {
+		r.logger.Error("erro ao listar regras de acompanhamento", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar regras de acompanhamento")
+	}
+	return rules, nil
+}
+
+// GetRule busca uma regra
+func (r *followupRepository) GetRule(ctx context.Context, id int) (*models.Rule, error) {
+	var rule models.Rule
+	if err := db.Conn(ctx, r.db).First(&rule, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrFollowupRuleNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar regra de acompanhamento")
+	}
+	return &rule, nil
+}
+
+// CreateRule grava uma regra
+func (r *followupRepository) CreateRule(ctx context.Context, rule *models.Rule) error {
+	if err := db.Conn(ctx, r.db).Create(rule).Error; err != nil {
+		r.logger.Error("erro ao criar regra de acompanhamento", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar regra de acompanhamento")
+	}
+	return nil
+}
+
+// UpdateRule altera uma regra
+func (r *followupRepository) UpdateRule(ctx context.Context, rule *models.Rule) error {
+	if err := db.Conn(ctx, r.db).Save(rule).Error; err != nil {
+		r.logger.Error("erro ao alterar regra de acompanhamento", zap.Error(err), zap.Int("id", rule.ID))
+		return errors.WrapError(err, "falha ao alterar regra de acompanhamento")
+	}
+	return nil
+}
+
+// DeleteRule remove a regra; as tarefas criadas por ela e o registro das ações ficam
+func (r *followupRepository) DeleteRule(ctx context.Context, id int) error {
+	result := db.Conn(ctx, r.db).Delete(&models.Rule{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover regra de acompanhamento", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover regra de acompanhamento")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrFollowupRuleNotFound
+	}
+	return nil
+}
+
+// ActiveRules retorna as regras ativas. O contexto deve vir de tenant.AllCompanies: a execução
+// periódica atende todas as empresas.
+func (r *followupRepository) ActiveRules(ctx context.Context) ([]models.Rule, error) {
+	var rules []models.Rule
+	err := db.Conn(ctx, r.db).Where("active = ?", true).Order("company_id ASC, idle_days ASC, id ASC").Find(&rules).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar regras de acompanhamento ativas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar regras de acompanhamento ativas")
+	}
+	return rules, nil
+}
+
+// IdleProcesses busca, como GetAbandonedProcesses, os processos em aberto sem atualização desde
+// antes de cutoff, com o vendedor do pedido de venda mais recente ligado ao processo ou, sem
+// pedido, o da cotação mais recente
+func (r *followupRepository) IdleProcesses(ctx context.Context, cutoff time.Time) ([]models.IdleProcess, error) {
+	var processes []models.IdleProcess
+	err := db.Conn(ctx, r.db).Table("sales_processes p").
+		Select(`p.id AS process_id, p.contact_id, c.name AS contact_name, p.status, p.updated_at,
+			sp.salesperson_id, u.nome AS salesperson_name, u.email AS salesperson_email`).
+		Joins("LEFT JOIN contacts c ON c.id = p.contact_id").
+		Joins(`LEFT JOIN LATERAL (SELECT COALESCE(
+			(SELECT so.salesperson_id FROM process_sales_orders pso JOIN sales_orders so ON so.id = pso.sales_order_id
+				WHERE pso.process_id = p.id AND so.salesperson_id IS NOT NULL AND so.deleted_at IS NULL ORDER BY so.id DESC LIMIT 1),
+			(SELECT q.salesperson_id FROM process_quotations pq JOIN quotations q ON q.id = pq.quotation_id
+				WHERE pq.process_id = p.id AND q.salesperson_id IS NOT NULL AND q.deleted_at IS NULL ORDER BY q.id DESC LIMIT 1)
+		) AS salesperson_id) sp ON TRUE`).
+		Joins("LEFT JOIN users u ON u.id = sp.salesperson_id AND u.active").
+		Scopes(tenant.Scope(ctx, "p")).
+		Where("p.updated_at < ? AND p.status NOT IN ?", cutoff,
+			[]string{salesRepository.ProcessStatusCompleted, salesRepository.ProcessStatusCancelled}).
+		Order("p.updated_at ASC, p.id ASC").
+		Scan(&processes).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar processos parados", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar processos parados")
+	}
+	return processes, nil
+}
+
+// LoggedKeys retorna as ações já registradas para os processos, por regra e período parado
+func (r *followupRepository) LoggedKeys(ctx context.Context, processIDs []int) (map[models.LogKey]bool, error) {
+	keys := map[models.LogKey]bool{}
+	if len(processIDs) == 0 {
+		return keys, nil
+	}
+	var entries []models.LogEntry
+	err := db.Conn(ctx, r.db).Select("rule_id, sales_process_id, idle_since").
+		Where("sales_process_id IN ?", processIDs).Find(&entries).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar ações de acompanhamento registradas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar ações de acompanhamento registradas")
+	}
+	for _, entry := range entries {
+		keys[models.LogKey{RuleID: entry.RuleID, ProcessID: entry.SalesProcessID, IdleSince: entry.IdleSince.UTC()}] = true
+	}
+	return keys, nil
+}
+
+// CreateTask grava a tarefa de retorno e o registro da ação na mesma transação
+func (r *followupRepository) CreateTask(ctx context.Context, task *tasks.Task, entry *models.LogEntry) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			r.logger.Error("erro ao criar tarefa de retorno", zap.Error(err), zap.Int("process_id", entry.SalesProcessID))
+			return errors.WrapError(err, "falha ao criar tarefa de retorno")
+		}
+		entry.TaskID = &task.ID
+		if err := tx.Create(entry).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar ação de acompanhamento")
+		}
+		return nil
+	})
+}
+
+// CancelProcess cancela o processo que continua parado desde idleSince, acrescenta a nota às
+// observações, cancela as tarefas de retorno em aberto e registra a ação. Retorna false quando o
+// processo foi atualizado ou encerrado nesse meio tempo.
+func (r *followupRepository) CancelProcess(ctx context.Context, idleSince time.Time, note string, entry *models.LogEntry, now time.Time) (bool, error) {
+	cancelled := false
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var previous string
+		err := tx.Table("sales_processes").Scopes(tenant.Scope(ctx, "sales_processes")).
+			Where("id = ?", entry.SalesProcessID).Select("status").Scan(&previous).Error
+		if err != nil {
+			return errors.WrapError(err, "falha ao buscar processo parado")
+		}
+		result := tx.Table("sales_processes").Scopes(tenant.Scope(ctx, "sales_processes")).
+			Where("id = ? AND updated_at <= ? AND status NOT IN ?", entry.SalesProcessID, idleSince,
+				[]string{salesRepository.ProcessStatusCompleted, salesRepository.ProcessStatusCancelled}).
+			UpdateColumns(map[string]interface{}{
+				"status":     salesRepository.ProcessStatusCancelled,
+				"notes":      gorm.Expr("CONCAT_WS(E'\\n', NULLIF(notes, ''), ?)", note),
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			r.logger.Error("erro ao cancelar processo parado", zap.Error(result.Error), zap.Int("process_id", entry.SalesProcessID))
+			return errors.WrapError(result.Error, "falha ao cancelar processo parado")
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		event := salesRepository.StatusChangedEvent(previous, salesRepository.ProcessStatusCancelled, now)
+		if err := salesRepository.RecordProcessEvent(tx, entry.SalesProcessID, event); err != nil {
+			return err
+		}
+		err = tx.Model(&tasks.Task{}).
+			Where("entity_type = ? AND entity_id = ? AND status = ?", "sales_process", entry.SalesProcessID, tasks.StatusOpen).
+			Updates(map[string]interface{}{"status": tasks.StatusCancelled, "completed_at": now, "completed_by": "acompanhamento automático"}).Error
+		if err != nil {
+			return errors.WrapError(err, "falha ao cancelar tarefas de retorno do processo")
+		}
+		if err := tx.Create(entry).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar ação de acompanhamento")
+		}
+		cancelled = true
+		return nil
+	})
+	return cancelled, err
+}
+
+// CreateLog registra uma ação executada por uma regra
+func (r *followupRepository) CreateLog(ctx context.Context, entry *models.LogEntry) error {
+	if err := db.Conn(ctx, r.db).Create(entry).Error; err != nil {
+		r.logger.Error("erro ao registrar ação de acompanhamento", zap.Error(err), zap.Int("process_id", entry.SalesProcessID))
+		return errors.WrapError(err, "falha ao registrar ação de acompanhamento")
+	}
+	return nil
+}
+
+// ListLog lista as ações executadas pelas regras, das mais recentes; processID filtra o processo
+func (r *followupRepository) ListLog(ctx context.Context, processID int) ([]models.LogEntry, error) {
+	query := db.Conn(ctx, r.db).Model(&models.LogEntry{})
+	if processID > 0 {
+		query = query.Where("sales_process_id = ?", processID)
+	}
+
+	var entries []models.LogEntry
+	if err := query.Order("created_at DESC, id DESC").Limit(500).Find(&entries).Error; err != nil {
+		r.logger.Error("erro ao listar ações de acompanhamento", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar ações de acompanhamento")
+	}
+	return entries, nil
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/mailer"
+	"ERP-ONSMART/backend/internal/metrics"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/followup/models"
+	"ERP-ONSMART/backend/internal/modules/followup/repository"
+	tasks "ERP-ONSMART/backend/internal/modules/tasks/models"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	templateService "ERP-ONSMART/backend/internal/modules/templates/service"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// FollowupService mantém as regras de acompanhamento e as executa sobre os processos de venda
+// parados: cria tarefas de retorno para o vendedor, envia lembretes e cancela os processos
+// parados além do prazo máximo, registrando cada ação
+type FollowupService struct {
+	newRepo  func() (repository.FollowupRepository, error)
+	send     func(mailer.Message) error
+	render   func(ctx context.Context, key string, data templates.Data) (*templates.Rendered, error)
+	now      func() time.Time
+	location func(ctx context.Context) *time.Location
+	logger   *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.FollowupRepository
+}
+
+// NewFollowupService cria o serviço sobre o repositório informado
+func NewFollowupService(newRepo func() (repository.FollowupRepository, error)) *FollowupService {
+	return &FollowupService{
+		newRepo:  newRepo,
+		send:     mailer.Send,
+		render:   templateService.Render,
+		now:      time.Now,
+		location: localtime.Location,
+		logger:   logger.WithModule("followup_service"),
+	}
+}
+
+var defaultService = NewFollowupService(repository.NewFollowupRepository)
+
+// ListRules lista as regras de acompanhamento da empresa
+func ListRules(ctx context.Context) ([]models.Rule, error) {
+	return defaultService.ListRules(ctx)
+}
+
+// CreateRule valida e grava uma regra
+func CreateRule(ctx context.Context, input models.RuleInput, createdBy string) (*models.Rule, error) {
+	return defaultService.CreateRule(ctx, input, createdBy)
+}
+
+// UpdateRule valida e altera uma regra
+func UpdateRule(ctx context.Context, id int, input models.RuleInput) (*models.Rule, error) {
+	return defaultService.UpdateRule(ctx, id, input)
+}
+
+// DeleteRule remove uma regra
+func DeleteRule(ctx context.Context, id int) error {
+	return defaultService.DeleteRule(ctx, id)
+}
+
+// ListLog lista as ações executadas pelas regras
+func ListLog(ctx context.Context, processID int) ([]models.LogEntry, error) {
+	return defaultService.ListLog(ctx, processID)
+}
+
+// RunCompany executa na hora as regras ativas da empresa do contexto
+func RunCompany(ctx context.Context) (*models.RunResult, error) {
+	return defaultService.RunCompany(ctx)
+}
+
+// StartFollowupScheduler executa periodicamente as regras de acompanhamento de todas as empresas
+func StartFollowupScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("followup_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				result, err := defaultService.RunAll(ctx)
+				metrics.ObserveJob("followup_scheduler", err)
+				if err != nil {
+					log.Error("erro ao executar regras de acompanhamento", zap.Error(err))
+					continue
+				}
+				if result.Tasks+result.Notifications+result.Cancelled > 0 {
+					log.Info("regras de acompanhamento executadas", zap.Int("tasks", result.Tasks),
+						zap.Int("notifications", result.Notifications), zap.Int("cancelled", result.Cancelled))
+				}
+			}
+		}
+	}()
+}
+
+func (s *FollowupService) repository() (repository.FollowupRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListRules lista as regras da empresa
+func (s *FollowupService) ListRules(ctx context.Context) ([]models.Rule, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListRules(ctx)
+}
+
+// CreateRule grava a regra, ativa se não informado
+func (s *FollowupService) CreateRule(ctx context.Context, input models.RuleInput, createdBy string) (*models.Rule, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	rule := &models.Rule{CreatedBy: createdBy}
+	input.Apply(rule)
+	if err := repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule altera a regra; as ações já registradas continuam valendo para o período parado
+func (s *FollowupService) UpdateRule(ctx context.Context, id int, input models.RuleInput) (*models.Rule, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	rule, err := repo.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	input.Apply(rule)
+	if err := repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule remove a regra
+func (s *FollowupService) DeleteRule(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteRule(ctx, id)
+}
+
+// ListLog lista as ações registradas, opcionalmente de um processo
+func (s *FollowupService) ListLog(ctx context.Context, processID int) ([]models.LogEntry, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListLog(ctx, processID)
+}
+
+// RunAll executa as regras ativas de todas as empresas, cada empresa no seu contexto
+func (s *FollowupService) RunAll(ctx context.Context) (*models.RunResult, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := repo.ActiveRules(tenant.AllCompanies(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	byCompany := map[int][]models.Rule{}
+	var companies []int
+	for _, rule := range rules {
+		if _, ok := byCompany[rule.CompanyID]; !ok {
+			companies = append(companies, rule.CompanyID)
+		}
+		byCompany[rule.CompanyID] = append(byCompany[rule.CompanyID], rule)
+	}
+
+	total := &models.RunResult{}
+	for _, companyID := range companies {
+		result, err := s.run(tenant.WithCompany(ctx, companyID), repo, byCompany[companyID])
+		if err != nil {
+			return total, err
+		}
+		total.Tasks += result.Tasks
+		total.Notifications += result.Notifications
+		total.Cancelled += result.Cancelled
+		total.Skipped += result.Skipped
+	}
+	return total, nil
+}
+
+// RunCompany executa as regras ativas da empresa do contexto
+func (s *FollowupService) RunCompany(ctx context.Context) (*models.RunResult, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := repo.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]models.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Active {
+			active = append(active, rule)
+		}
+	}
+	return s.run(ctx, repo, active)
+}
+
+// run busca os processos parados há mais dias do que a menor regra e executa as ações ainda não
+// registradas para o período parado de cada processo
+func (s *FollowupService) run(ctx context.Context, repo repository.FollowupRepository, rules []models.Rule) (*models.RunResult, error) {
+	result := &models.RunResult{}
+	if len(rules) == 0 {
+		return result, nil
+	}
+	minIdle := rules[0].IdleDays
+	for _, rule := range rules {
+		if rule.IdleDays < minIdle {
+			minIdle = rule.IdleDays
+		}
+	}
+
+	now := s.now()
+	loc := s.location(ctx)
+	cutoff := localtime.StartOfDay(localtime.Date(now.In(loc)).AddDate(0, 0, -minIdle), loc)
+	processes, err := repo.IdleProcesses(ctx, cutoff)
+	if err != nil {
+		return result, err
+	}
+	if len(processes) == 0 {
+		return result, nil
+	}
+	processIDs := make([]int, 0, len(processes))
+	for _, process := range processes {
+		processIDs = append(processIDs, process.ProcessID)
+	}
+	done, err := repo.LoggedKeys(ctx, processIDs)
+	if err != nil {
+		return result, err
+	}
+
+	for _, action := range models.PlanActions(rules, processes, done, now, loc) {
+		switch action.Rule.Action {
+		case models.ActionTask:
+			if err := s.createTask(ctx, repo, action, now.In(loc)); err != nil {
+				return result, err
+			}
+			result.Tasks++
+		case models.ActionNotify:
+			sent, err := s.notify(ctx, repo, action)
+			if err != nil {
+				return result, err
+			}
+			if sent {
+				result.Notifications++
+			} else {
+				result.Skipped++
+			}
+		case models.ActionCancel:
+			cancelled, err := s.cancel(ctx, repo, action, now)
+			if err != nil {
+				return result, err
+			}
+			if cancelled {
+				result.Cancelled++
+			} else {
+				result.Skipped++
+			}
+		}
+	}
+	return result, nil
+}
+
+// createTask cria a tarefa de retorno atribuída ao vendedor do processo
+func (s *FollowupService) createTask(ctx context.Context, repo repository.FollowupRepository, action models.PlannedAction, today time.Time) error {
+	ruleID := action.Rule.ID
+	processID := action.Process.ProcessID
+	due := models.TaskDueDate(action.Rule, today)
+	task := &tasks.Task{
+		Title:       models.TaskTitle(action.Process, action.IdleDays),
+		Description: fmt.Sprintf("Criada pela regra de acompanhamento %q.", action.Rule.Name),
+		AssignedTo:  action.Process.SalespersonID,
+		DueDate:     &due,
+		Status:      tasks.StatusOpen,
+		EntityType:  "sales_process",
+		EntityID:    &processID,
+		Source:      tasks.SourceFollowup,
+	}
+	message := "tarefa de retorno criada sem vendedor responsável"
+	if action.Process.SalespersonID != nil {
+		message = fmt.Sprintf("tarefa de retorno criada para %s", action.Process.SalespersonName)
+	}
+	entry := s.logEntry(action, action.Process.SalespersonEmail, message)
+	if err := repo.CreateTask(ctx, task, entry); err != nil {
+		return err
+	}
+	s.logger.Info("tarefa de retorno criada para processo parado", zap.Int("process_id", processID),
+		zap.Int("rule_id", ruleID), zap.Int("task_id", task.ID), zap.Int("idle_days", action.IdleDays))
+	return nil
+}
+
+// notify envia o lembrete ao vendedor do processo. Sem vendedor com e-mail, registra a ação sem
+// envio para não tentar de novo no mesmo período parado; falhas no envio só vão para o log e o
+// lembrete é tentado de novo na próxima execução.
+func (s *FollowupService) notify(ctx context.Context, repo repository.FollowupRepository, action models.PlannedAction) (bool, error) {
+	process := action.Process
+	if process.SalespersonEmail == "" {
+		entry := s.logEntry(action, "", "lembrete não enviado: processo sem vendedor com e-mail")
+		return false, repo.CreateLog(ctx, entry)
+	}
+
+	// O lembrete é para a equipe: sai no idioma padrão
+	ctx = i18n.WithLanguage(ctx, i18n.Default)
+	message, err := s.render(ctx, templates.KeyProcessFollowupEmail, templates.Data{
+		Contact: &contact.Contact{ID: process.ContactID, Name: process.ContactName},
+		Vars: map[string]interface{}{
+			"salesperson": process.SalespersonName,
+			"process_id":  process.ProcessID,
+			"stage":       i18n.Label(i18n.Default, i18n.SalesProcessStatus, process.Status),
+			"idle_since":  process.UpdatedAt,
+			"idle_days":   action.IdleDays,
+		},
+	})
+	if err == nil {
+		err = s.send(mailer.Message{To: process.SalespersonEmail, Subject: message.Subject, Body: message.Body})
+	}
+	if err != nil {
+		s.logger.Warn("erro ao enviar lembrete de processo parado", zap.Error(err), zap.Int("process_id", process.ProcessID),
+			zap.Int("rule_id", action.Rule.ID))
+		return false, nil
+	}
+
+	entry := s.logEntry(action, process.SalespersonEmail, "lembrete enviado ao vendedor")
+	return true, repo.CreateLog(ctx, entry)
+}
+
+// cancel cancela o processo parado além do prazo máximo da regra
+func (s *FollowupService) cancel(ctx context.Context, repo repository.FollowupRepository, action models.PlannedAction, now time.Time) (bool, error) {
+	note := models.CancelNote(action.Rule, action.IdleDays, now.In(s.location(ctx)))
+	entry := s.logEntry(action, "", note)
+	cancelled, err := repo.CancelProcess(ctx, action.Process.UpdatedAt, note, entry, now)
+	if err != nil || !cancelled {
+		return false, err
+	}
+	s.logger.Info("processo de venda parado cancelado automaticamente", zap.Int("process_id", action.Process.ProcessID),
+		zap.Int("rule_id", action.Rule.ID), zap.Int("idle_days", action.IdleDays))
+	return true, nil
+}
+
+func (s *FollowupService) logEntry(action models.PlannedAction, recipient, message string) *models.LogEntry {
+	return &models.LogEntry{
+		RuleID:         action.Rule.ID,
+		SalesProcessID: action.Process.ProcessID,
+		Action:         action.Rule.Action,
+		IdleSince:      action.Process.UpdatedAt,
+		IdleDays:       action.IdleDays,
+		Recipient:      recipient,
+		Message:        message,
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/mailer"
+	"ERP-ONSMART/backend/internal/modules/followup/models"
+	"ERP-ONSMART/backend/internal/modules/followup/repository"
+	tasks "ERP-ONSMART/backend/internal/modules/tasks/models"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFollowupRepo struct {
+	repository.FollowupRepository
+	rules     []models.Rule
+	processes map[int][]models.IdleProcess
+	cutoffs   []time.Time
+	tasks     []tasks.Task
+	log       []models.LogEntry
+	cancelled []int
+}
+
+func (r *fakeFollowupRepo) ListRules(ctx context.Context) ([]models.Rule, error) {
+	companyID, _ := tenant.CompanyID(ctx)
+	var rules []models.Rule
+	for _, rule := range r.rules {
+		if rule.CompanyID == companyID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (r *fakeFollowupRepo) ActiveRules(ctx context.Context) ([]models.Rule, error) {
+	var rules []models.Rule
+	for _, rule := range r.rules {
+		if rule.Active {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (r *fakeFollowupRepo) IdleProcesses(ctx context.Context, cutoff time.Time) ([]models.IdleProcess, error) {
+	r.cutoffs = append(r.cutoffs, cutoff)
+	companyID, _ := tenant.CompanyID(ctx)
+	var processes []models.IdleProcess
+	for _, process := range r.processes[companyID] {
+		if process.UpdatedAt.Before(cutoff) && !r.isCancelled(process.ProcessID) {
+			processes = append(processes, process)
+		}
+	}
+	return processes, nil
+}
+
+func (r *fakeFollowupRepo) LoggedKeys(ctx context.Context, processIDs []int) (map[models.LogKey]bool, error) {
+	keys := map[models.LogKey]bool{}
+	for _, entry := range r.log {
+		keys[models.LogKey{RuleID: entry.RuleID, ProcessID: entry.SalesProcessID, IdleSince: entry.IdleSince.UTC()}] = true
+	}
+	return keys, nil
+}
+
+func (r *fakeFollowupRepo) CreateTask(ctx context.Context, task *tasks.Task, entry *models.LogEntry) error {
+	task.ID = len(r.tasks) + 1
+	r.tasks = append(r.tasks, *task)
+	entry.TaskID = &task.ID
+	r.log = append(r.log, *entry)
+	return nil
+}
+
+func (r *fakeFollowupRepo) CancelProcess(ctx context.Context, idleSince time.Time, note string, entry *models.LogEntry, now time.Time) (bool, error) {
+	r.cancelled = append(r.cancelled, entry.SalesProcessID)
+	r.log = append(r.log, *entry)
+	return true, nil
+}
+
+func (r *fakeFollowupRepo) CreateLog(ctx context.Context, entry *models.LogEntry) error {
+	r.log = append(r.log, *entry)
+	return nil
+}
+
+func (r *fakeFollowupRepo) isCancelled(processID int) bool {
+	for _, id := range r.cancelled {
+		if id == processID {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestFollowupService(repo *fakeFollowupRepo, now time.Time) (*FollowupService, *[]mailer.Message) {
+	sent := []mailer.Message{}
+	s := NewFollowupService(func() (repository.FollowupRepository, error) { return repo, nil })
+	s.now = func() time.Time { return now }
+	s.location = func(context.Context) *time.Location { return time.UTC }
+	s.render = func(ctx context.Context, key string, data templates.Data) (*templates.Rendered, error) {
+		return templates.RenderBuiltin(key, data)
+	}
+	s.send = func(msg mailer.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}
+	return s, &sent
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestRunCompanyExecutesRulesOncePerIdlePeriod(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	repo := &fakeFollowupRepo{
+		rules: []models.Rule{
+			{ID: 1, CompanyID: 1, Name: "Retorno", IdleDays: 7, Action: models.ActionTask, TaskDueDays: 3, Active: true},
+			{ID: 2, CompanyID: 1, Name: "Lembrete", IdleDays: 14, Action: models.ActionNotify, Active: true},
+			{ID: 3, CompanyID: 1, Name: "Prazo máximo", IdleDays: 30, Action: models.ActionCancel, Active: true},
+			{ID: 4, CompanyID: 1, Name: "Inativa", IdleDays: 1, Action: models.ActionNotify},
+		},
+		processes: map[int][]models.IdleProcess{1: {
+			{ProcessID: 10, ContactID: 5, ContactName: "Cliente A", Status: "quotation", UpdatedAt: now.AddDate(0, 0, -10),
+				SalespersonID: intPtr(7), SalespersonName: "Ana Souza", SalespersonEmail: "ana@empresa.com"},
+			{ProcessID: 20, ContactID: 6, ContactName: "Cliente B", Status: "sales_order", UpdatedAt: now.AddDate(0, 0, -20),
+				SalespersonID: intPtr(7), SalespersonName: "Ana Souza", SalespersonEmail: "ana@empresa.com"},
+			{ProcessID: 40, ContactID: 8, ContactName: "Cliente C", Status: "delivery", UpdatedAt: now.AddDate(0, 0, -40)},
+		}},
+	}
+	s, sent := newTestFollowupService(repo, now)
+	ctx := tenant.WithCompany(context.Background(), 1)
+
+	result, err := s.RunCompany(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.RunResult{Tasks: 2, Notifications: 1, Cancelled: 1}, *result)
+
+	// a busca começa no início do dia de 7 dias atrás, o menor prazo entre as regras ativas
+	assert.Equal(t, time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC), repo.cutoffs[0])
+
+	require.Len(t, repo.tasks, 2)
+	assert.Equal(t, 10, *repo.tasks[0].EntityID)
+	assert.Equal(t, tasks.SourceFollowup, repo.tasks[0].Source)
+	assert.Equal(t, 7, *repo.tasks[0].AssignedTo)
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), *repo.tasks[0].DueDate)
+	assert.Equal(t, "Retomar processo de venda 10 com Cliente A (parado há 10 dias)", repo.tasks[0].Title)
+
+	require.Len(t, *sent, 1)
+	assert.Equal(t, "ana@empresa.com", (*sent)[0].To)
+	assert.Equal(t, "Processo de venda 20 parado há 20 dias", (*sent)[0].Subject)
+	assert.Contains(t, (*sent)[0].Body, "etapa Pedido de venda")
+
+	assert.Equal(t, []int{40}, repo.cancelled)
+	assert.Len(t, repo.log, 4)
+
+	// nova execução no mesmo período parado não repete as ações
+	result, err = s.RunCompany(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.RunResult{}, *result)
+	assert.Len(t, *sent, 1)
+}
+
+func TestRunCompanyRetriesFailedReminder(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	repo := &fakeFollowupRepo{
+		rules: []models.Rule{{ID: 1, CompanyID: 1, Name: "Lembrete", IdleDays: 7, Action: models.ActionNotify, Active: true}},
+		processes: map[int][]models.IdleProcess{1: {
+			{ProcessID: 10, ContactName: "Cliente A", Status: "quotation", UpdatedAt: now.AddDate(0, 0, -10),
+				SalespersonID: intPtr(7), SalespersonName: "Ana Souza", SalespersonEmail: "ana@empresa.com"},
+			{ProcessID: 11, ContactName: "Cliente B", Status: "quotation", UpdatedAt: now.AddDate(0, 0, -10)},
+		}},
+	}
+	s, _ := newTestFollowupService(repo, now)
+	s.send = func(mailer.Message) error { return stderrors.New("smtp indisponível") }
+	ctx := tenant.WithCompany(context.Background(), 1)
+
+	result, err := s.RunCompany(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.RunResult{Skipped: 2}, *result)
+	// só o processo sem vendedor fica registrado; o lembrete que falhou é tentado de novo
+	require.Len(t, repo.log, 1)
+	assert.Equal(t, 11, repo.log[0].SalesProcessID)
+}
+
+func TestRunAllRunsEachCompanyInItsContext(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	repo := &fakeFollowupRepo{
+		rules: []models.Rule{
+			{ID: 1, CompanyID: 1, IdleDays: 7, Action: models.ActionTask, Active: true},
+			{ID: 2, CompanyID: 2, IdleDays: 3, Action: models.ActionTask, Active: true},
+		},
+		processes: map[int][]models.IdleProcess{
+			1: {{ProcessID: 10, Status: "quotation", UpdatedAt: now.AddDate(0, 0, -5)}},
+			2: {{ProcessID: 20, Status: "quotation", UpdatedAt: now.AddDate(0, 0, -5)}},
+		},
+	}
+	s, _ := newTestFollowupService(repo, now)
+
+	result, err := s.RunAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Tasks)
+	require.Len(t, repo.tasks, 1)
+	assert.Equal(t, 20, *repo.tasks[0].EntityID)
+}
+
+func TestCreateRuleValidatesInput(t *testing.T) {
+	s, _ := newTestFollowupService(&fakeFollowupRepo{}, time.Now())
+
+	_, err := s.CreateRule(context.Background(), models.RuleInput{Name: "Etapa", IdleDays: 7, Action: models.ActionTask,
+		Stages: []string{"cancelled"}}, "admin")
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidFollowupRule))
+}
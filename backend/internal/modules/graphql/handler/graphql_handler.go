@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/graphql"
+	"ERP-ONSMART/backend/internal/modules/graphql/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Executa uma consulta GraphQL de leitura sobre processos de venda e seus documentos
+// O corpo é {"query", "variables", "operationName"}; as gravações seguem na API REST.
+// Erros da consulta são devolvidos com status 200 no campo "errors", como no GraphQL.
+// @Security BearerAuth
+func GraphQLHandler(c *gin.Context) {
+	var request graphql.Request
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+	if request.Query == "" {
+		c.Error(errors.ToAPIError(errors.ErrInvalidRequest, "").WithFieldError("query", "campo obrigatório"))
+		return
+	}
+
+	response, err := service.Execute(c.Request.Context(), request)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao executar consulta GraphQL")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
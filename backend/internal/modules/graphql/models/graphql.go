@@ -0,0 +1,24 @@
+package models
+
+// Documentos vinculados ao processo de venda pelas tabelas process_*
+const (
+	LinkQuotations  = "quotations"
+	LinkSalesOrders = "sales_orders"
+	LinkDeliveries  = "deliveries"
+	LinkInvoices    = "invoices"
+)
+
+// ProcessLink liga um processo de venda a um dos seus documentos
+type ProcessLink struct {
+	ProcessID  int `gorm:"column:process_id"`
+	DocumentID int `gorm:"column:document_id"`
+}
+
+// ProcessFilter filtra a listagem de processos de venda da consulta salesProcesses
+type ProcessFilter struct {
+	Status    string
+	ContactID int
+	SLAStatus string
+	Limit     int
+	Offset    int
+}
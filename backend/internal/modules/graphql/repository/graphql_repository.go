@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/graphql/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GraphQLRepository carrega em lote os registros pedidos pela API GraphQL: cada método recebe
+// os IDs de todos os objetos de um nível da consulta e faz uma única query
+type GraphQLRepository interface {
+	ListSalesProcesses(ctx context.Context, filter models.ProcessFilter) ([]sales.SalesProcess, error)
+	SalesProcessesByIDs(ctx context.Context, ids []int) ([]sales.SalesProcess, error)
+	ProcessLinks(ctx context.Context, document string, processIDs []int) ([]models.ProcessLink, error)
+
+	ContactsByIDs(ctx context.Context, ids []int) ([]contact.Contact, error)
+	QuotationsByIDs(ctx context.Context, ids []int) ([]sales.Quotation, error)
+	QuotationItems(ctx context.Context, quotationIDs []int) ([]sales.QuotationItem, error)
+	SalesOrdersByIDs(ctx context.Context, ids []int) ([]sales.SalesOrder, error)
+	SalesOrderItems(ctx context.Context, salesOrderIDs []int) ([]sales.SOItem, error)
+	DeliveriesByIDs(ctx context.Context, ids []int) ([]sales.Delivery, error)
+	DeliveriesBySalesOrders(ctx context.Context, salesOrderIDs []int) ([]sales.Delivery, error)
+	InvoicesByIDs(ctx context.Context, ids []int) ([]sales.Invoice, error)
+	InvoicesBySalesOrders(ctx context.Context, salesOrderIDs []int) ([]sales.Invoice, error)
+	PaymentsByInvoices(ctx context.Context, invoiceIDs []int) ([]sales.Payment, error)
+}
+
+// linkTables mapeia cada documento do processo para a tabela de vínculo e a coluna do documento
+var linkTables = map[string][2]string{
+	models.LinkQuotations:  {"process_quotations", "quotation_id"},
+	models.LinkSalesOrders: {"process_sales_orders", "sales_order_id"},
+	models.LinkDeliveries:  {"process_deliveries", "delivery_id"},
+	models.LinkInvoices:    {"process_invoices", "invoice_id"},
+}
+
+type graphQLRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGraphQLRepository cria uma nova instância do repositório
+func NewGraphQLRepository() (GraphQLRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &graphQLRepository{
+		db:     db,
+		logger: logger.WithModule("graphql_repository"),
+	}, nil
+}
+
+// ListSalesProcesses retorna os processos de venda mais recentes da empresa
+func (r *graphQLRepository) ListSalesProcesses(ctx context.Context, filter models.ProcessFilter) ([]sales.SalesProcess, error) {
+	query := r.db.WithContext(ctx).Model(&sales.SalesProcess{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ContactID > 0 {
+		query = query.Where("contact_id = ?", filter.ContactID)
+	}
+	if filter.SLAStatus != "" {
+		query = query.Where("sla_status = ?", filter.SLAStatus)
+	}
+
+	var processes []sales.SalesProcess
+	if err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&processes).Error; err != nil {
+		r.logger.Error("erro ao listar processos de venda", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar processos de venda")
+	}
+	return processes, nil
+}
+
+// SalesProcessesByIDs busca os processos de venda pelos IDs
+func (r *graphQLRepository) SalesProcessesByIDs(ctx context.Context, ids []int) ([]sales.SalesProcess, error) {
+	return findIn[sales.SalesProcess](ctx, r, "id", ids, "processos de venda")
+}
+
+// ProcessLinks retorna os vínculos dos processos com os documentos do tipo informado
+func (r *graphQLRepository) ProcessLinks(ctx context.Context, document string, processIDs []int) ([]models.ProcessLink, error) {
+	link, ok := linkTables[document]
+	if !ok {
+		return nil, fmt.Errorf("documento desconhecido %q", document)
+	}
+	if len(processIDs) == 0 {
+		return nil, nil
+	}
+
+	var links []models.ProcessLink
+	if err := r.db.WithContext(ctx).Table(link[0]).
+		Select("process_id, "+link[1]+" AS document_id").
+		Where("process_id IN ?", processIDs).
+		Order(link[1] + " ASC").
+		Scan(&links).Error; err != nil {
+		r.logger.Error("erro ao buscar vínculos do processo de venda", zap.Error(err), zap.String("link", link[0]))
+		return nil, errors.WrapError(err, "falha ao buscar vínculos do processo de venda")
+	}
+	return links, nil
+}
+
+// ContactsByIDs busca os contatos pelos IDs, inclusive os que estão na lixeira, que continuam
+// aparecendo nos documentos já emitidos
+func (r *graphQLRepository) ContactsByIDs(ctx context.Context, ids []int) ([]contact.Contact, error) {
+	return findIn[contact.Contact](ctx, r, "id", ids, "contatos")
+}
+
+// QuotationsByIDs busca as cotações pelos IDs
+func (r *graphQLRepository) QuotationsByIDs(ctx context.Context, ids []int) ([]sales.Quotation, error) {
+	return findIn[sales.Quotation](ctx, r, "id", ids, "cotações")
+}
+
+// QuotationItems busca os itens das cotações
+func (r *graphQLRepository) QuotationItems(ctx context.Context, quotationIDs []int) ([]sales.QuotationItem, error) {
+	return findIn[sales.QuotationItem](ctx, r, "quotation_id", quotationIDs, "itens das cotações")
+}
+
+// SalesOrdersByIDs busca os pedidos de venda pelos IDs
+func (r *graphQLRepository) SalesOrdersByIDs(ctx context.Context, ids []int) ([]sales.SalesOrder, error) {
+	return findIn[sales.SalesOrder](ctx, r, "id", ids, "pedidos de venda")
+}
+
+// SalesOrderItems busca os itens dos pedidos de venda
+func (r *graphQLRepository) SalesOrderItems(ctx context.Context, salesOrderIDs []int) ([]sales.SOItem, error) {
+	return findIn[sales.SOItem](ctx, r, "sales_order_id", salesOrderIDs, "itens dos pedidos de venda")
+}
+
+// DeliveriesByIDs busca as entregas pelos IDs
+func (r *graphQLRepository) DeliveriesByIDs(ctx context.Context, ids []int) ([]sales.Delivery, error) {
+	return findIn[sales.Delivery](ctx, r, "id", ids, "entregas")
+}
+
+// DeliveriesBySalesOrders busca as entregas dos pedidos de venda
+func (r *graphQLRepository) DeliveriesBySalesOrders(ctx context.Context, salesOrderIDs []int) ([]sales.Delivery, error) {
+	return findIn[sales.Delivery](ctx, r, "sales_order_id", salesOrderIDs, "entregas")
+}
+
+// InvoicesByIDs busca as faturas pelos IDs
+func (r *graphQLRepository) InvoicesByIDs(ctx context.Context, ids []int) ([]sales.Invoice, error) {
+	return findIn[sales.Invoice](ctx, r, "id", ids, "faturas")
+}
+
+// InvoicesBySalesOrders busca as faturas dos pedidos de venda
+func (r *graphQLRepository) InvoicesBySalesOrders(ctx context.Context, salesOrderIDs []int) ([]sales.Invoice, error) {
+	return findIn[sales.Invoice](ctx, r, "sales_order_id", salesOrderIDs, "faturas")
+}
+
+// PaymentsByInvoices busca os pagamentos das faturas
+func (r *graphQLRepository) PaymentsByInvoices(ctx context.Context, invoiceIDs []int) ([]sales.Payment, error) {
+	return findIn[sales.Payment](ctx, r, "invoice_id", invoiceIDs, "pagamentos")
+}
+
+// findIn busca os registros com a coluna em ids (WHERE column IN ?), ordenados pelo ID.
+// A empresa da requisição é aplicada pelo plugin de tenant.
+func findIn[T any](ctx context.Context, r *graphQLRepository, column string, ids []int, description string) ([]T, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var records []T
+	if err := r.db.WithContext(ctx).Where(column+" IN ?", ids).Order("id ASC").Find(&records).Error; err != nil {
+		r.logger.Error("erro ao buscar "+description, zap.Error(err), zap.Int("ids", len(ids)))
+		return nil, errors.WrapError(err, "falha ao buscar "+description)
+	}
+	return records, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/graphql"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/graphql/repository"
+
+	"go.uber.org/zap"
+)
+
+// Service executa as consultas GraphQL de leitura sobre o schema do processo de venda
+type Service struct {
+	newRepo func() (repository.GraphQLRepository, error)
+	logger  *zap.Logger
+
+	mu     sync.Mutex
+	schema *graphql.Schema
+}
+
+// NewService cria o serviço sobre o repositório informado
+func NewService(newRepo func() (repository.GraphQLRepository, error)) *Service {
+	return &Service{
+		newRepo: newRepo,
+		logger:  logger.WithModule("graphql_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewGraphQLRepository)
+
+// Execute executa a consulta GraphQL na empresa da requisição
+func Execute(ctx context.Context, request graphql.Request) (*graphql.Response, error) {
+	return defaultService.Execute(ctx, request)
+}
+
+func (s *Service) loadSchema() (*graphql.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.schema == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.schema = newSchema(repo)
+	}
+	return s.schema, nil
+}
+
+// Execute executa a consulta. Erros da consulta vão na própria resposta (campo errors);
+// o erro retornado indica apenas falha ao abrir o repositório.
+func (s *Service) Execute(ctx context.Context, request graphql.Request) (*graphql.Response, error) {
+	schema, err := s.loadSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	response := schema.Execute(ctx, request)
+	for _, queryErr := range response.Errors {
+		s.logger.Warn("erro na consulta GraphQL", zap.String("operation", request.OperationName),
+			zap.Strings("path", queryErr.Path), zap.String("error", queryErr.Message))
+	}
+	return response, nil
+}
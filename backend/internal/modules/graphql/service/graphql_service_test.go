@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/graphql"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/graphql/models"
+	"ERP-ONSMART/backend/internal/modules/graphql/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepo guarda os registros em memória e conta as buscas de cada método
+type fakeRepo struct {
+	calls     map[string]int
+	filter    models.ProcessFilter
+	processes []sales.SalesProcess
+	links     map[string][]models.ProcessLink
+	contacts  []contact.Contact
+	orders    []sales.SalesOrder
+	invoices  []sales.Invoice
+	payments  []sales.Payment
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		calls: map[string]int{},
+		processes: []sales.SalesProcess{
+			{ID: 1, ContactID: 7, Status: "invoice"},
+			{ID: 2, ContactID: 8, Status: "sales_order"},
+			{ID: 3, ContactID: 7, Status: "quotation"},
+		},
+		links: map[string][]models.ProcessLink{
+			models.LinkSalesOrders: {{ProcessID: 1, DocumentID: 10}, {ProcessID: 2, DocumentID: 11}},
+		},
+		contacts: []contact.Contact{{ID: 7, Name: "ACME Ltda"}, {ID: 8, Name: "Maria Silva"}},
+		orders:   []sales.SalesOrder{{ID: 10, SONo: "SO-10", ContactID: 7}, {ID: 11, SONo: "SO-11", ContactID: 8}},
+		invoices: []sales.Invoice{{ID: 20, InvoiceNo: "INV-20", SalesOrderID: 10}, {ID: 21, InvoiceNo: "INV-21", SalesOrderID: 10}},
+		payments: []sales.Payment{{ID: 30, InvoiceID: 20, Amount: money.FromInt(100)}, {ID: 31, InvoiceID: 20, Amount: money.MustParse("50.5")}},
+	}
+}
+
+func filterByKey[T any](records []T, ids []int, key func(*T) int) []T {
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	var found []T
+	for i := range records {
+		if wanted[key(&records[i])] {
+			found = append(found, records[i])
+		}
+	}
+	return found
+}
+
+func (f *fakeRepo) ListSalesProcesses(_ context.Context, filter models.ProcessFilter) ([]sales.SalesProcess, error) {
+	f.calls["ListSalesProcesses"]++
+	f.filter = filter
+	return f.processes, nil
+}
+
+func (f *fakeRepo) SalesProcessesByIDs(_ context.Context, ids []int) ([]sales.SalesProcess, error) {
+	f.calls["SalesProcessesByIDs"]++
+	return filterByKey(f.processes, ids, func(p *sales.SalesProcess) int { return p.ID }), nil
+}
+
+func (f *fakeRepo) ProcessLinks(_ context.Context, document string, processIDs []int) ([]models.ProcessLink, error) {
+	f.calls["ProcessLinks:"+document]++
+	return filterByKey(f.links[document], processIDs, func(l *models.ProcessLink) int { return l.ProcessID }), nil
+}
+
+func (f *fakeRepo) ContactsByIDs(_ context.Context, ids []int) ([]contact.Contact, error) {
+	f.calls["ContactsByIDs"]++
+	return filterByKey(f.contacts, ids, func(c *contact.Contact) int { return c.ID }), nil
+}
+
+func (f *fakeRepo) QuotationsByIDs(context.Context, []int) ([]sales.Quotation, error) {
+	f.calls["QuotationsByIDs"]++
+	return nil, nil
+}
+
+func (f *fakeRepo) QuotationItems(context.Context, []int) ([]sales.QuotationItem, error) {
+	f.calls["QuotationItems"]++
+	return nil, nil
+}
+
+func (f *fakeRepo) SalesOrdersByIDs(_ context.Context, ids []int) ([]sales.SalesOrder, error) {
+	f.calls["SalesOrdersByIDs"]++
+	return filterByKey(f.orders, ids, func(o *sales.SalesOrder) int { return o.ID }), nil
+}
+
+func (f *fakeRepo) SalesOrderItems(context.Context, []int) ([]sales.SOItem, error) {
+	f.calls["SalesOrderItems"]++
+	return nil, nil
+}
+
+func (f *fakeRepo) DeliveriesByIDs(context.Context, []int) ([]sales.Delivery, error) {
+	f.calls["DeliveriesByIDs"]++
+	return nil, nil
+}
+
+func (f *fakeRepo) DeliveriesBySalesOrders(context.Context, []int) ([]sales.Delivery, error) {
+	f.calls["DeliveriesBySalesOrders"]++
+	return nil, nil
+}
+
+func (f *fakeRepo) InvoicesByIDs(_ context.Context, ids []int) ([]sales.Invoice, error) {
+	f.calls["InvoicesByIDs"]++
+	return filterByKey(f.invoices, ids, func(i *sales.Invoice) int { return i.ID }), nil
+}
+
+func (f *fakeRepo) InvoicesBySalesOrders(_ context.Context, salesOrderIDs []int) ([]sales.Invoice, error) {
+	f.calls["InvoicesBySalesOrders"]++
+	return filterByKey(f.invoices, salesOrderIDs, func(i *sales.Invoice) int { return i.SalesOrderID }), nil
+}
+
+func (f *fakeRepo) PaymentsByInvoices(_ context.Context, invoiceIDs []int) ([]sales.Payment, error) {
+	f.calls["PaymentsByInvoices"]++
+	return filterByKey(f.payments, invoiceIDs, func(p *sales.Payment) int { return p.InvoiceID }), nil
+}
+
+func newTestService(repo *fakeRepo) *Service {
+	return NewService(func() (repository.GraphQLRepository, error) { return repo, nil })
+}
+
+func TestExecuteLoadsNestedProcessWithOneQueryPerLevel(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+
+	response, err := s.Execute(context.Background(), graphql.Request{Query: `query Processos($limit: Int) {
+		salesProcesses(limit: $limit, status: invoice) {
+			id
+			contact { name }
+			sales_orders {
+				so_no
+				contact { name }
+				invoices { invoice_no payments { amount } }
+				deliveries { delivery_no }
+			}
+		}
+	}`, Variables: map[string]any{"limit": float64(500)}})
+	require.NoError(t, err)
+	require.Empty(t, response.Errors)
+
+	body, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data": {"salesProcesses": [
+		{"id": 1, "contact": {"name": "ACME Ltda"}, "sales_orders": [{"so_no": "SO-10", "contact": {"name": "ACME Ltda"},
+			"invoices": [{"invoice_no": "INV-20", "payments": [{"amount": 100}, {"amount": 50.5}]}, {"invoice_no": "INV-21", "payments": []}],
+			"deliveries": []}]},
+		{"id": 2, "contact": {"name": "Maria Silva"}, "sales_orders": [{"so_no": "SO-11", "contact": {"name": "Maria Silva"},
+			"invoices": [], "deliveries": []}]},
+		{"id": 3, "contact": {"name": "ACME Ltda"}, "sales_orders": []}
+	]}}`, string(body))
+
+	assert.Equal(t, models.ProcessFilter{Status: "invoice", Limit: maxProcessesLimit}, repo.filter)
+	assert.Equal(t, map[string]int{
+		"ListSalesProcesses":        1,
+		"ContactsByIDs":             2,
+		"ProcessLinks:sales_orders": 1,
+		"SalesOrdersByIDs":          1,
+		"InvoicesBySalesOrders":     1,
+		"PaymentsByInvoices":        1,
+		"DeliveriesBySalesOrders":   1,
+	}, repo.calls, "uma busca por campo e nível, independente da quantidade de processos")
+}
+
+func TestExecuteReturnsNullForUnknownID(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+
+	response, err := s.Execute(context.Background(), graphql.Request{Query: `{
+		found: invoice(id: 20) { invoice_no }
+		missing: salesOrder(id: 99) { so_no }
+	}`})
+	require.NoError(t, err)
+
+	body, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data": {"found": {"invoice_no": "INV-20"}, "missing": null}}`, string(body))
+}
+
+func TestExecuteRejectsMutations(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+
+	response, err := s.Execute(context.Background(), graphql.Request{Query: `mutation { salesOrder(id: 1) { id } }`})
+	require.NoError(t, err)
+	require.Len(t, response.Errors, 1)
+	assert.Contains(t, response.Errors[0].Message, "use a API REST")
+	assert.Empty(t, repo.calls)
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+
+	"ERP-ONSMART/backend/internal/graphql"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/graphql/models"
+	"ERP-ONSMART/backend/internal/modules/graphql/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+)
+
+// maxQueryDepth limita o aninhamento das consultas (processo > pedido > fatura > pagamentos cabe com folga)
+const maxQueryDepth = 6
+
+// maxProcessesLimit limita a quantidade de processos de venda por consulta
+const maxProcessesLimit = 100
+
+// newSchema monta o schema de leitura do processo de venda. Os campos escalares usam os mesmos
+// nomes do JSON da API REST; os relacionamentos são carregados em lote por nível da consulta.
+func newSchema(repo repository.GraphQLRepository) *graphql.Schema {
+	contactType := graphql.NewObject("Contact", "id", "person_type", "type", "name", "company_name", "trade_name",
+		"document", "email", "phone", "zip_code", "street", "number", "complement", "neighborhood", "city", "state",
+		"created_at", "deleted_at")
+	itemType := graphql.NewObject("DocumentItem", "id", "product_id", "product_name", "product_code", "description",
+		"quantity", "unit", "unit_factor", "unit_price", "discount", "tax", "total")
+	paymentType := graphql.NewObject("Payment", "id", "invoice_id", "amount", "payment_date", "payment_method",
+		"reference", "notes")
+	invoiceType := graphql.NewObject("Invoice", "id", "invoice_no", "sales_order_id", "so_no", "contact_id", "status",
+		"issue_date", "due_date", "subtotal", "tax_total", "discount_total", "grand_total", "amount_paid",
+		"payment_terms", "notes", "created_at")
+	deliveryType := graphql.NewObject("Delivery", "id", "delivery_no", "sales_order_id", "so_no", "status",
+		"delivery_date", "received_date", "shipping_method", "carrier", "tracking_number", "shipping_address",
+		"notes", "shipped_at", "sla_status", "sla_due_at", "created_at")
+	quotationType := graphql.NewObject("Quotation", "id", "quotation_no", "contact_id", "status", "expiry_date",
+		"subtotal", "tax_total", "discount_total", "grand_total", "notes", "terms", "created_at")
+	salesOrderType := graphql.NewObject("SalesOrder", "id", "so_no", "quotation_id", "contact_id", "status",
+		"expected_date", "subtotal", "tax_total", "discount_total", "grand_total", "notes", "payment_terms",
+		"shipping_address", "created_at")
+	processType := graphql.NewObject("SalesProcess", "id", "contact_id", "status", "total_value", "profit", "notes",
+		"sla_status", "sla_due_at", "created_at", "updated_at")
+
+	contactID := func(c *contact.Contact) int { return c.ID }
+
+	invoiceType.Fields["payments"] = &graphql.Field{Type: paymentType, List: true,
+		Resolve: hasMany(func(i *sales.Invoice) int { return i.ID }, repo.PaymentsByInvoices, func(p *sales.Payment) int { return p.InvoiceID })}
+
+	quotationType.Fields["contact"] = &graphql.Field{Type: contactType,
+		Resolve: belongsTo(func(q *sales.Quotation) int { return q.ContactID }, repo.ContactsByIDs, contactID)}
+	quotationType.Fields["items"] = &graphql.Field{Type: itemType, List: true,
+		Resolve: hasMany(func(q *sales.Quotation) int { return q.ID }, repo.QuotationItems, func(i *sales.QuotationItem) int { return i.QuotationID })}
+
+	salesOrderID := func(o *sales.SalesOrder) int { return o.ID }
+	salesOrderType.Fields["contact"] = &graphql.Field{Type: contactType,
+		Resolve: belongsTo(func(o *sales.SalesOrder) int { return o.ContactID }, repo.ContactsByIDs, contactID)}
+	salesOrderType.Fields["items"] = &graphql.Field{Type: itemType, List: true,
+		Resolve: hasMany(salesOrderID, repo.SalesOrderItems, func(i *sales.SOItem) int { return i.SalesOrderID })}
+	salesOrderType.Fields["deliveries"] = &graphql.Field{Type: deliveryType, List: true,
+		Resolve: hasMany(salesOrderID, repo.DeliveriesBySalesOrders, func(d *sales.Delivery) int { return d.SalesOrderID })}
+	salesOrderType.Fields["invoices"] = &graphql.Field{Type: invoiceType, List: true,
+		Resolve: hasMany(salesOrderID, repo.InvoicesBySalesOrders, func(i *sales.Invoice) int { return i.SalesOrderID })}
+
+	processType.Fields["contact"] = &graphql.Field{Type: contactType,
+		Resolve: belongsTo(func(p *sales.SalesProcess) int { return p.ContactID }, repo.ContactsByIDs, contactID)}
+	processType.Fields["quotations"] = &graphql.Field{Type: quotationType, List: true,
+		Resolve: linked(repo, models.LinkQuotations, repo.QuotationsByIDs, func(q *sales.Quotation) int { return q.ID })}
+	processType.Fields["sales_orders"] = &graphql.Field{Type: salesOrderType, List: true,
+		Resolve: linked(repo, models.LinkSalesOrders, repo.SalesOrdersByIDs, salesOrderID)}
+	processType.Fields["deliveries"] = &graphql.Field{Type: deliveryType, List: true,
+		Resolve: linked(repo, models.LinkDeliveries, repo.DeliveriesByIDs, func(d *sales.Delivery) int { return d.ID })}
+	processType.Fields["invoices"] = &graphql.Field{Type: invoiceType, List: true,
+		Resolve: linked(repo, models.LinkInvoices, repo.InvoicesByIDs, func(i *sales.Invoice) int { return i.ID })}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"salesProcess": {Type: processType, Args: []string{"id"},
+			Resolve: byID(repo.SalesProcessesByIDs)},
+		"salesProcesses": {Type: processType, List: true, Args: []string{"status", "contact_id", "sla_status", "limit", "offset"},
+			Resolve: func(ctx context.Context, _ []any, args graphql.Args) ([]any, error) {
+				filter, err := processFilter(args)
+				if err != nil {
+					return nil, err
+				}
+				processes, err := repo.ListSalesProcesses(ctx, filter)
+				if err != nil {
+					return nil, err
+				}
+				return []any{processes}, nil
+			}},
+		"salesOrder": {Type: salesOrderType, Args: []string{"id"},
+			Resolve: byID(repo.SalesOrdersByIDs)},
+		"invoice": {Type: invoiceType, Args: []string{"id"},
+			Resolve: byID(repo.InvoicesByIDs)},
+	}}
+	return &graphql.Schema{Query: query, MaxDepth: maxQueryDepth}
+}
+
+func processFilter(args graphql.Args) (models.ProcessFilter, error) {
+	var filter models.ProcessFilter
+	var err error
+	if filter.Status, err = args.String("status", ""); err != nil {
+		return filter, err
+	}
+	if filter.ContactID, err = args.Int("contact_id", 0); err != nil {
+		return filter, err
+	}
+	if filter.SLAStatus, err = args.String("sla_status", ""); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = args.Int("limit", 20); err != nil {
+		return filter, err
+	}
+	if filter.Offset, err = args.Int("offset", 0); err != nil {
+		return filter, err
+	}
+	if filter.Limit <= 0 || filter.Limit > maxProcessesLimit {
+		filter.Limit = maxProcessesLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return filter, nil
+}
+
+type loader[T any] func(ctx context.Context, ids []int) ([]T, error)
+
+// byID resolve um campo raiz com argumento id; registro inexistente resulta em null
+func byID[T any](load loader[T]) graphql.Resolver {
+	return func(ctx context.Context, _ []any, args graphql.Args) ([]any, error) {
+		id, err := args.Int("id", 0)
+		if err != nil {
+			return nil, err
+		}
+		records, err := load(ctx, []int{id})
+		if err != nil || len(records) == 0 {
+			return []any{nil}, err
+		}
+		return []any{&records[0]}, nil
+	}
+}
+
+// belongsTo resolve o registro apontado pela chave estrangeira de cada objeto, com uma única
+// busca para todos os objetos do nível
+func belongsTo[S, T any](key func(*S) int, load loader[T], id func(*T) int) graphql.Resolver {
+	return func(ctx context.Context, sources []any, _ graphql.Args) ([]any, error) {
+		keys := make([]int, len(sources))
+		for i, source := range sources {
+			keys[i] = key(source.(*S))
+		}
+		records, err := load(ctx, uniqueIDs(keys))
+		if err != nil {
+			return nil, err
+		}
+
+		byKey := make(map[int]*T, len(records))
+		for i := range records {
+			byKey[id(&records[i])] = &records[i]
+		}
+		values := make([]any, len(sources))
+		for i, k := range keys {
+			if record, ok := byKey[k]; ok {
+				values[i] = record
+			}
+		}
+		return values, nil
+	}
+}
+
+// hasMany resolve os registros que apontam para cada objeto, com uma única busca para todos
+// os objetos do nível
+func hasMany[S, T any](id func(*S) int, load loader[T], foreignKey func(*T) int) graphql.Resolver {
+	return func(ctx context.Context, sources []any, _ graphql.Args) ([]any, error) {
+		ids := make([]int, len(sources))
+		for i, source := range sources {
+			ids[i] = id(source.(*S))
+		}
+		records, err := load(ctx, uniqueIDs(ids))
+		if err != nil {
+			return nil, err
+		}
+
+		grouped := make(map[int][]T)
+		for _, record := range records {
+			grouped[foreignKey(&record)] = append(grouped[foreignKey(&record)], record)
+		}
+		values := make([]any, len(sources))
+		for i, parentID := range ids {
+			values[i] = grouped[parentID]
+		}
+		return values, nil
+	}
+}
+
+// linked resolve os documentos vinculados a cada processo de venda pela tabela process_*:
+// uma busca para os vínculos e outra para os documentos de todos os processos do nível
+func linked[T any](repo repository.GraphQLRepository, document string, load loader[T], id func(*T) int) graphql.Resolver {
+	return func(ctx context.Context, sources []any, _ graphql.Args) ([]any, error) {
+		processIDs := make([]int, len(sources))
+		for i, source := range sources {
+			processIDs[i] = source.(*sales.SalesProcess).ID
+		}
+		links, err := repo.ProcessLinks(ctx, document, uniqueIDs(processIDs))
+		if err != nil {
+			return nil, err
+		}
+
+		documentIDs := make([]int, len(links))
+		for i, link := range links {
+			documentIDs[i] = link.DocumentID
+		}
+		records, err := load(ctx, uniqueIDs(documentIDs))
+		if err != nil {
+			return nil, err
+		}
+
+		byID := make(map[int]T, len(records))
+		for _, record := range records {
+			byID[id(&record)] = record
+		}
+		grouped := make(map[int][]T)
+		for _, link := range links {
+			if record, ok := byID[link.DocumentID]; ok {
+				grouped[link.ProcessID] = append(grouped[link.ProcessID], record)
+			}
+		}
+		values := make([]any, len(sources))
+		for i, processID := range processIDs {
+			values[i] = grouped[processID]
+		}
+		return values, nil
+	}
+}
+
+// uniqueIDs remove IDs repetidos e zerados (chaves estrangeiras não preenchidas)
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/grpcserver"
+	"ERP-ONSMART/backend/internal/middleware"
+	apikeys "ERP-ONSMART/backend/internal/modules/apikeys/models"
+	apikeyService "ERP-ONSMART/backend/internal/modules/apikeys/service"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/models"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/service"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// authenticateAPIKey é substituído nos testes
+var authenticateAPIKey = apikeyService.Authenticate
+
+// call executa o método e retorna a struct usada para preencher a resposta
+type call func(ctx context.Context, request *dynamicpb.Message) (any, error)
+
+type method struct {
+	scope string
+	call  call
+}
+
+// methods associa cada método de erp/v1/erp.proto ao escopo da chave de API exigido
+var methods = map[string]method{
+	"erp.v1.ContactService.GetContact":         {apikeys.ScopeContactsRead, getContact},
+	"erp.v1.ContactService.ListContacts":       {apikeys.ScopeContactsRead, listContacts},
+	"erp.v1.ProductService.GetProduct":         {apikeys.ScopeProductsRead, getProduct},
+	"erp.v1.ProductService.ListProducts":       {apikeys.ScopeProductsRead, listProducts},
+	"erp.v1.SalesOrderService.GetSalesOrder":   {apikeys.ScopeSalesRead, getSalesOrder},
+	"erp.v1.SalesOrderService.ListSalesOrders": {apikeys.ScopeSalesRead, listSalesOrders},
+	"erp.v1.InvoiceService.GetInvoice":         {apikeys.ScopeSalesRead, getInvoice},
+	"erp.v1.InvoiceService.ListInvoices":       {apikeys.ScopeSalesRead, listInvoices},
+}
+
+// Serve publica a API gRPC interna no endereço até o contexto ser cancelado
+func Serve(ctx context.Context, addr string) error {
+	server := grpcserver.NewServer()
+	if err := Register(server); err != nil {
+		return err
+	}
+	return server.ListenAndServe(ctx, addr)
+}
+
+// Register publica erp/v1/erp.proto no reflection e registra os métodos dos serviços
+func Register(server *grpcserver.Server) error {
+	file, err := models.NewFile()
+	if err != nil {
+		return fmt.Errorf("descritor de %s inválido: %w", models.ProtoPath, err)
+	}
+	if err := server.RegisterFile(file); err != nil {
+		return err
+	}
+
+	for i := 0; i < file.Services().Len(); i++ {
+		descriptors := file.Services().Get(i).Methods()
+		for j := 0; j < descriptors.Len(); j++ {
+			descriptor := descriptors.Get(j)
+			m, ok := methods[string(descriptor.FullName())]
+			if !ok {
+				return fmt.Errorf("método %s sem handler", descriptor.FullName())
+			}
+			server.HandleUnary(descriptor, authorize(m.scope, func(ctx context.Context, request *dynamicpb.Message) (proto.Message, error) {
+				result, err := m.call(ctx, request)
+				if err != nil {
+					return nil, err
+				}
+				response := dynamicpb.NewMessage(descriptor.Output())
+				if err := grpcserver.Fill(response, result); err != nil {
+					return nil, err
+				}
+				return response, nil
+			}))
+		}
+	}
+	return nil
+}
+
+// authorize autentica a chave de API do metadado x-api-key, exige o escopo e executa o método
+// na empresa da chave, como o APIKeyMiddleware da API REST
+func authorize(scope string, next grpcserver.UnaryHandler) grpcserver.UnaryHandler {
+	return func(ctx context.Context, request *dynamicpb.Message) (proto.Message, error) {
+		rawKey := strings.TrimSpace(grpcserver.Metadata(ctx).Get(middleware.APIKeyHeader))
+		if rawKey == "" {
+			return nil, errors.ErrMissingAPIKey
+		}
+		key, err := authenticateAPIKey(ctx, rawKey, scope, grpcserver.Peer(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return next(tenant.WithCompany(ctx, key.CompanyID), request)
+	}
+}
+
+func intField(request *dynamicpb.Message, name protoreflect.Name) int {
+	return int(request.Get(request.Descriptor().Fields().ByName(name)).Int())
+}
+
+func stringField(request *dynamicpb.Message, name protoreflect.Name) string {
+	return strings.TrimSpace(request.Get(request.Descriptor().Fields().ByName(name)).String())
+}
+
+// requestID lê o campo id de GetRequest
+func requestID(request *dynamicpb.Message) (int, error) {
+	id := intField(request, "id")
+	if id <= 0 {
+		return 0, errors.InvalidParam("id deve ser um número positivo")
+	}
+	return id, nil
+}
+
+// listFilter lê a paginação de ListRequest e ListDocumentsRequest e os filtros presentes na mensagem
+func listFilter(request *dynamicpb.Message) models.ListFilter {
+	filter := models.ListFilter{Page: intField(request, "page"), PageSize: intField(request, "page_size")}
+	fields := request.Descriptor().Fields()
+	if fields.ByName("search") != nil {
+		filter.Search = stringField(request, "search")
+	}
+	if fields.ByName("contact_id") != nil {
+		filter.ContactID = intField(request, "contact_id")
+	}
+	if fields.ByName("status") != nil {
+		filter.Status = stringField(request, "status")
+	}
+	return filter
+}
+
+func getContact(ctx context.Context, request *dynamicpb.Message) (any, error) {
+	id, err := requestID(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.GetContact(ctx, id)
+}
+
+func listContacts(ctx context.Context, request *dynamicpb.Message) (any, error) {
+	contacts, total, err := service.ListContacts(ctx, listFilter(request))
+	return struct {
+		Contacts []contact.Contact `json:"contacts"`
+		Total    int64             `json:"total"`
+	}{contacts, total}, err
+}
+
+func getProduct(ctx context.Context, request *dynamicpb.Message) (any, error) {
+	id, err := requestID(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.GetProduct(ctx, id)
+}
+
+func listProducts(ctx context.Context, request *dynamicpb.Message) (any, error) {
+	products, total, err := service.ListProducts(ctx, listFilter(request))
+	return struct {
+		Products []product.Product `json:"products"`
+		Total    int64             `json:"total"`
+	}{products, total}, err
+}
+
+func getSalesOrder(ctx context.Context, request *dynamicpb.Message) (any, error) {
+	id, err := requestID(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.GetSalesOrder(ctx, id)
+}
+
+func listSalesOrders(ctx context.Context, request *dynamicpb.Message) (any, error) {
+	orders, total, err := service.ListSalesOrders(ctx, listFilter(request))
+	return struct {
+		SalesOrders []sales.SalesOrder `json:"sales_orders"`
+		Total       int64              `json:"total"`
+	}{orders, total}, err
+}
+
+func getInvoice(ctx context.Context, request *dynamicpb.Message) (any, error) {
+	id, err := requestID(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.GetInvoice(ctx, id)
+}
+
+func listInvoices(ctx context.Context, request *dynamicpb.Message) (any, error) {
+	invoices, total, err := service.ListInvoices(ctx, listFilter(request))
+	return struct {
+		Invoices []sales.Invoice `json:"invoices"`
+		Total    int64           `json:"total"`
+	}{invoices, total}, err
+}
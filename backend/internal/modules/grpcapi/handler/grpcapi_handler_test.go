@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/grpcserver"
+	apikeys "ERP-ONSMART/backend/internal/modules/apikeys/models"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/models"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestRegisterPublishesAllServices(t *testing.T) {
+	server := grpcserver.NewServer()
+	require.NoError(t, Register(server))
+
+	for _, service := range []string{"erp.v1.ContactService", "erp.v1.InvoiceService", "erp.v1.ProductService",
+		"erp.v1.SalesOrderService", grpcserver.HealthService} {
+		assert.Contains(t, server.Services(), service)
+	}
+}
+
+func TestAuthorizeUsesKeyCompany(t *testing.T) {
+	original := authenticateAPIKey
+	authenticateAPIKey = func(_ context.Context, rawKey, scope, _ string) (*apikeys.APIKey, error) {
+		if rawKey != "erp_valida" || scope != apikeys.ScopeSalesRead {
+			return nil, errors.ErrInvalidAPIKey
+		}
+		return &apikeys.APIKey{ID: 1, CompanyID: 5}, nil
+	}
+	t.Cleanup(func() { authenticateAPIKey = original })
+
+	var companyID int
+	handler := authorize(apikeys.ScopeSalesRead, func(ctx context.Context, _ *dynamicpb.Message) (proto.Message, error) {
+		companyID, _ = tenant.CompanyID(ctx)
+		return nil, nil
+	})
+
+	file, err := models.NewFile()
+	require.NoError(t, err)
+	request := dynamicpb.NewMessage(file.Messages().ByName("GetRequest"))
+
+	_, err = handler(context.Background(), request)
+	assert.ErrorIs(t, err, errors.ErrMissingAPIKey)
+
+	_, err = handler(grpcserver.WithMetadata(context.Background(), http.Header{"X-Api-Key": {"erp_outra"}}), request)
+	assert.ErrorIs(t, err, errors.ErrInvalidAPIKey)
+
+	_, err = handler(grpcserver.WithMetadata(context.Background(), http.Header{"X-Api-Key": {"erp_valida"}}), request)
+	require.NoError(t, err)
+	assert.Equal(t, 5, companyID)
+}
+
+func TestRequestFields(t *testing.T) {
+	file, err := models.NewFile()
+	require.NoError(t, err)
+
+	get := dynamicpb.NewMessage(file.Messages().ByName("GetRequest"))
+	_, err = requestID(get)
+	assert.Equal(t, http.StatusBadRequest, errors.ToAPIError(err, "").Status)
+
+	list := dynamicpb.NewMessage(file.Messages().ByName("ListDocumentsRequest"))
+	fields := list.Descriptor().Fields()
+	list.Set(fields.ByName("page"), protoreflect.ValueOfInt32(2))
+	list.Set(fields.ByName("contact_id"), protoreflect.ValueOfInt64(7))
+	list.Set(fields.ByName("status"), protoreflect.ValueOfString(" paid "))
+	assert.Equal(t, models.ListFilter{Page: 2, ContactID: 7, Status: "paid"}, listFilter(list))
+}
+
+// Cada mensagem de resposta precisa ter correspondente nas tags json dos modelos do domínio
+func TestResponsesFillFromModels(t *testing.T) {
+	file, err := models.NewFile()
+	require.NoError(t, err)
+
+	sources := map[protoreflect.Name]any{
+		"Contact":    contact.Contact{ID: 7, Name: "ACME Ltda"},
+		"Product":    product.Product{ID: 3, SKU: "P-3", Tags: []string{"promo"}},
+		"SalesOrder": sales.SalesOrder{ID: 10, Items: []sales.SOItem{{ID: 1, Quantity: 2}}},
+		"Invoice": sales.Invoice{ID: 20, Items: []sales.InvoiceItem{{ID: 1}},
+			Payments: []sales.Payment{{ID: 30, Amount: money.MustParse("50.5")}}},
+	}
+	for name, source := range sources {
+		message := dynamicpb.NewMessage(file.Messages().ByName(name))
+		assert.NoError(t, grpcserver.Fill(message, source), name)
+	}
+
+	invoice := dynamicpb.NewMessage(file.Messages().ByName("Invoice"))
+	require.NoError(t, grpcserver.Fill(invoice, sources["Invoice"]))
+	payments := invoice.Get(invoice.Descriptor().Fields().ByName("payments")).List()
+	require.Equal(t, 1, payments.Len())
+	payment := payments.Get(0).Message()
+	assert.Equal(t, 50.5, payment.Get(payment.Descriptor().Fields().ByName("amount")).Float())
+}
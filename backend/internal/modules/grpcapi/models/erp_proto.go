@@ -0,0 +1,120 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/grpcserver"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Arquivo e pacote da API gRPC interna
+const (
+	ProtoPath    = "erp/v1/erp.proto"
+	ProtoPackage = "erp.v1"
+)
+
+// ProtoHeader é o comentário do topo do .proto publicado em backend/proto
+const ProtoHeader = `API gRPC interna do ERP (somente leitura) para os serviços da empresa, como PDV e backend mobile.
+Gerado por "make proto" a partir de backend/internal/modules/grpcapi/models; não edite.
+Autenticação: metadado x-api-key com os escopos contacts:read, products:read ou sales:read.`
+
+const (
+	typeString = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	typeInt32  = descriptorpb.FieldDescriptorProto_TYPE_INT32
+	typeInt64  = descriptorpb.FieldDescriptorProto_TYPE_INT64
+	typeDouble = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+	typeBool   = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+)
+
+func qualified(name string) string {
+	return ProtoPackage + "." + name
+}
+
+// FileDescriptorProto descreve erp/v1/erp.proto. Os nomes dos campos são as tags json dos
+// modelos, preenchidos pelo grpcserver.Fill; datas são texto RFC 3339.
+func FileDescriptorProto() *descriptorpb.FileDescriptorProto {
+	f, m, list := grpcserver.Field, grpcserver.MessageField, grpcserver.Repeated
+
+	messages := []*descriptorpb.DescriptorProto{
+		grpcserver.Message("GetRequest", f(1, "id", typeInt64)),
+		grpcserver.Message("ListRequest",
+			f(1, "page", typeInt32), f(2, "page_size", typeInt32), f(3, "search", typeString)),
+		grpcserver.Message("ListDocumentsRequest",
+			f(1, "page", typeInt32), f(2, "page_size", typeInt32), f(3, "contact_id", typeInt64), f(4, "status", typeString)),
+
+		grpcserver.Message("Contact",
+			f(1, "id", typeInt64), f(2, "person_type", typeString), f(3, "type", typeString), f(4, "name", typeString),
+			f(5, "company_name", typeString), f(6, "trade_name", typeString), f(7, "document", typeString),
+			f(8, "secondary_doc", typeString), f(9, "isento", typeBool), f(10, "email", typeString), f(11, "phone", typeString),
+			f(12, "zip_code", typeString), f(13, "street", typeString), f(14, "number", typeString),
+			f(15, "complement", typeString), f(16, "neighborhood", typeString), f(17, "city", typeString),
+			f(18, "state", typeString), f(19, "created_at", typeString), f(20, "updated_at", typeString)),
+		grpcserver.Message("ListContactsResponse",
+			list(m(1, "contacts", qualified("Contact"))), f(2, "total", typeInt64)),
+
+		grpcserver.Message("Product",
+			f(1, "id", typeInt64), f(2, "name", typeString), f(3, "detailed_name", typeString),
+			f(4, "description", typeString), f(5, "status", typeString), f(6, "sku", typeString),
+			f(7, "barcode", typeString), f(8, "coin", typeString), f(9, "price", typeDouble),
+			f(10, "sales_price", typeDouble), f(11, "cost_price", typeDouble), f(12, "stock", typeInt64),
+			f(13, "type", typeString), f(14, "product_group", typeString), f(15, "product_category", typeString),
+			f(16, "product_subcategory", typeString), list(f(17, "tags", typeString)), f(18, "manufacturer", typeString),
+			f(19, "ncm", typeString), f(20, "created_at", typeString), f(21, "updated_at", typeString)),
+		grpcserver.Message("ListProductsResponse",
+			list(m(1, "products", qualified("Product"))), f(2, "total", typeInt64)),
+
+		grpcserver.Message("DocumentItem",
+			f(1, "id", typeInt64), f(2, "product_id", typeInt64), f(3, "product_name", typeString),
+			f(4, "product_code", typeString), f(5, "description", typeString), f(6, "quantity", typeInt64),
+			f(7, "unit_price", typeDouble), f(8, "discount", typeDouble), f(9, "tax", typeDouble), f(10, "total", typeDouble),
+			f(11, "unit", typeString), f(12, "unit_factor", typeInt64)),
+
+		grpcserver.Message("SalesOrder",
+			f(1, "id", typeInt64), f(2, "so_no", typeString), f(3, "quotation_id", typeInt64), f(4, "contact_id", typeInt64),
+			f(5, "status", typeString), f(6, "expected_date", typeString), f(7, "subtotal", typeDouble),
+			f(8, "tax_total", typeDouble), f(9, "discount_total", typeDouble), f(10, "grand_total", typeDouble),
+			f(11, "notes", typeString), f(12, "payment_terms", typeString), f(13, "shipping_address", typeString),
+			f(14, "created_at", typeString), f(15, "updated_at", typeString), list(m(16, "items", qualified("DocumentItem")))),
+		grpcserver.Message("ListSalesOrdersResponse",
+			list(m(1, "sales_orders", qualified("SalesOrder"))), f(2, "total", typeInt64)),
+
+		grpcserver.Message("Payment",
+			f(1, "id", typeInt64), f(2, "invoice_id", typeInt64), f(3, "amount", typeDouble),
+			f(4, "payment_date", typeString), f(5, "payment_method", typeString), f(6, "reference", typeString),
+			f(7, "notes", typeString)),
+
+		grpcserver.Message("Invoice",
+			f(1, "id", typeInt64), f(2, "invoice_no", typeString), f(3, "sales_order_id", typeInt64),
+			f(4, "so_no", typeString), f(5, "contact_id", typeInt64), f(6, "status", typeString),
+			f(7, "issue_date", typeString), f(8, "due_date", typeString), f(9, "subtotal", typeDouble),
+			f(10, "tax_total", typeDouble), f(11, "discount_total", typeDouble), f(12, "grand_total", typeDouble),
+			f(13, "amount_paid", typeDouble), f(14, "payment_terms", typeString), f(15, "notes", typeString),
+			f(16, "created_at", typeString), f(17, "updated_at", typeString),
+			list(m(18, "items", qualified("DocumentItem"))), list(m(19, "payments", qualified("Payment")))),
+		grpcserver.Message("ListInvoicesResponse",
+			list(m(1, "invoices", qualified("Invoice"))), f(2, "total", typeInt64)),
+	}
+
+	method := func(name, input, output string) *descriptorpb.MethodDescriptorProto {
+		return grpcserver.Method(name, qualified(input), qualified(output))
+	}
+	return grpcserver.File(ProtoPath, ProtoPackage, messages,
+		grpcserver.Service("ContactService",
+			method("GetContact", "GetRequest", "Contact"),
+			method("ListContacts", "ListRequest", "ListContactsResponse")),
+		grpcserver.Service("ProductService",
+			method("GetProduct", "GetRequest", "Product"),
+			method("ListProducts", "ListRequest", "ListProductsResponse")),
+		grpcserver.Service("SalesOrderService",
+			method("GetSalesOrder", "GetRequest", "SalesOrder"),
+			method("ListSalesOrders", "ListDocumentsRequest", "ListSalesOrdersResponse")),
+		grpcserver.Service("InvoiceService",
+			method("GetInvoice", "GetRequest", "Invoice"),
+			method("ListInvoices", "ListDocumentsRequest", "ListInvoicesResponse")),
+	)
+}
+
+// NewFile valida e retorna o descritor de erp/v1/erp.proto
+func NewFile() (protoreflect.FileDescriptor, error) {
+	return grpcserver.BuildFile(FileDescriptorProto())
+}
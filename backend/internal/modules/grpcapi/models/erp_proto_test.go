@@ -0,0 +1,27 @@
+package models
+
+import (
+	"os"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/grpcserver"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// O .proto publicado para os clientes precisa acompanhar o descritor servido pelo reflection;
+// se este teste falhar, rode `make proto` na raiz do repositório
+func TestProtoFileIsUpToDate(t *testing.T) {
+	file, err := NewFile()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile("../../../../proto/" + ProtoPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), grpcserver.PrintProto(file, ProtoHeader), "erp.proto desatualizado: rode `make proto`")
+}
+
+func TestListFilterOffset(t *testing.T) {
+	assert.Equal(t, 0, ListFilter{Page: 1, PageSize: 20}.Offset())
+	assert.Equal(t, 40, ListFilter{Page: 3, PageSize: 20}.Offset())
+}
@@ -0,0 +1,15 @@
+package models
+
+// ListFilter é o filtro das listagens da API gRPC, já com a paginação normalizada
+type ListFilter struct {
+	Page      int
+	PageSize  int
+	Search    string
+	ContactID int
+	Status    string
+}
+
+// Offset retorna o deslocamento da página
+func (f ListFilter) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
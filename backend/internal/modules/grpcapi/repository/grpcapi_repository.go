@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/models"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GRPCRepository consulta os cadastros e documentos expostos pela API gRPC interna
+type GRPCRepository interface {
+	GetContact(ctx context.Context, id int) (*contact.Contact, error)
+	ListContacts(ctx context.Context, filter models.ListFilter) ([]contact.Contact, int64, error)
+	GetProduct(ctx context.Context, id int) (*product.Product, error)
+	ListProducts(ctx context.Context, filter models.ListFilter) ([]product.Product, int64, error)
+	GetSalesOrder(ctx context.Context, id int) (*sales.SalesOrder, error)
+	ListSalesOrders(ctx context.Context, filter models.ListFilter) ([]sales.SalesOrder, int64, error)
+	GetInvoice(ctx context.Context, id int) (*sales.Invoice, error)
+	ListInvoices(ctx context.Context, filter models.ListFilter) ([]sales.Invoice, int64, error)
+}
+
+type grpcRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGRPCRepository cria uma nova instância do repositório
+func NewGRPCRepository() (GRPCRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &grpcRepository{
+		db:     db,
+		logger: logger.WithModule("grpcapi_repository"),
+	}, nil
+}
+
+// first busca o registro pelo ID, trocando o "não encontrado" do gorm pelo erro do domínio
+func first[T any](ctx context.Context, r *grpcRepository, query *gorm.DB, id int, notFound error, what string) (*T, error) {
+	var record T
+	if err := query.WithContext(ctx).First(&record, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFound
+		}
+		r.logger.Error("erro ao buscar "+what, zap.Int("id", id), zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar "+what)
+	}
+	return &record, nil
+}
+
+// page conta o total do filtro e busca a página pedida, carregando as associações informadas
+func page[T any](ctx context.Context, r *grpcRepository, query *gorm.DB, filter models.ListFilter, what string, preloads ...string) ([]T, int64, error) {
+	var total int64
+	query = query.WithContext(ctx).Model(new(T))
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar "+what, zap.Error(err))
+		return nil, 0, errors.WrapError(err, "falha ao contar "+what)
+	}
+
+	for _, association := range preloads {
+		query = query.Preload(association)
+	}
+	var records []T
+	if err := query.Order("id").Limit(filter.PageSize).Offset(filter.Offset()).Find(&records).Error; err != nil {
+		r.logger.Error("erro ao listar "+what, zap.Error(err))
+		return nil, 0, errors.WrapError(err, "falha ao listar "+what)
+	}
+	return records, total, nil
+}
+
+// documentQuery aplica os filtros de contato e status dos pedidos e faturas
+func documentQuery(query *gorm.DB, filter models.ListFilter) *gorm.DB {
+	if filter.ContactID > 0 {
+		query = query.Where("contact_id = ?", filter.ContactID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return query
+}
+
+// GetContact busca um contato não excluído
+func (r *grpcRepository) GetContact(ctx context.Context, id int) (*contact.Contact, error) {
+	return first[contact.Contact](ctx, r, r.db.Where("deleted_at IS NULL"), id, errors.ErrContactNotFound, "contato")
+}
+
+// ListContacts lista os contatos não excluídos, buscando por nome, e-mail ou documento
+func (r *grpcRepository) ListContacts(ctx context.Context, filter models.ListFilter) ([]contact.Contact, int64, error) {
+	query := r.db.Where("deleted_at IS NULL")
+	if filter.Search != "" {
+		like := "%" + filter.Search + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR document ILIKE ?", like, like, like)
+	}
+	return page[contact.Contact](ctx, r, query, filter, "contatos")
+}
+
+// GetProduct busca um produto
+func (r *grpcRepository) GetProduct(ctx context.Context, id int) (*product.Product, error) {
+	return first[product.Product](ctx, r, r.db, id, errors.ErrProductNotFound, "produto")
+}
+
+// ListProducts lista os produtos, buscando por nome ou SKU
+func (r *grpcRepository) ListProducts(ctx context.Context, filter models.ListFilter) ([]product.Product, int64, error) {
+	query := r.db
+	if filter.Search != "" {
+		like := "%" + filter.Search + "%"
+		query = query.Where("name ILIKE ? OR sku ILIKE ?", like, like)
+	}
+	return page[product.Product](ctx, r, query, filter, "produtos")
+}
+
+// GetSalesOrder busca o pedido de venda com os itens
+func (r *grpcRepository) GetSalesOrder(ctx context.Context, id int) (*sales.SalesOrder, error) {
+	return first[sales.SalesOrder](ctx, r, r.db.Preload("Items"), id, errors.ErrSalesOrderNotFound, "pedido de venda")
+}
+
+// ListSalesOrders lista os pedidos de venda com os itens
+func (r *grpcRepository) ListSalesOrders(ctx context.Context, filter models.ListFilter) ([]sales.SalesOrder, int64, error) {
+	return page[sales.SalesOrder](ctx, r, documentQuery(r.db, filter), filter, "pedidos de venda", "Items")
+}
+
+// GetInvoice busca a fatura com os itens e pagamentos
+func (r *grpcRepository) GetInvoice(ctx context.Context, id int) (*sales.Invoice, error) {
+	return first[sales.Invoice](ctx, r, r.db.Preload("Items").Preload("Payments"), id, errors.ErrInvoiceNotFound, "fatura")
+}
+
+// ListInvoices lista as faturas com os itens e pagamentos
+func (r *grpcRepository) ListInvoices(ctx context.Context, filter models.ListFilter) ([]sales.Invoice, int64, error) {
+	return page[sales.Invoice](ctx, r, documentQuery(r.db, filter), filter, "faturas", "Items", "Payments")
+}
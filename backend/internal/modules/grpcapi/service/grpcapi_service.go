@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/models"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/repository"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+)
+
+// Service atende as consultas da API gRPC interna na empresa da chave de API
+type Service struct {
+	newRepo func() (repository.GRPCRepository, error)
+
+	mu   sync.Mutex
+	repo repository.GRPCRepository
+}
+
+// NewService cria o serviço sobre o repositório informado
+func NewService(newRepo func() (repository.GRPCRepository, error)) *Service {
+	return &Service{newRepo: newRepo}
+}
+
+var defaultService = NewService(repository.NewGRPCRepository)
+
+// GetContact busca um contato
+func GetContact(ctx context.Context, id int) (*contact.Contact, error) {
+	return defaultService.GetContact(ctx, id)
+}
+
+// ListContacts lista os contatos
+func ListContacts(ctx context.Context, filter models.ListFilter) ([]contact.Contact, int64, error) {
+	return defaultService.ListContacts(ctx, filter)
+}
+
+// GetProduct busca um produto
+func GetProduct(ctx context.Context, id int) (*product.Product, error) {
+	return defaultService.GetProduct(ctx, id)
+}
+
+// ListProducts lista os produtos
+func ListProducts(ctx context.Context, filter models.ListFilter) ([]product.Product, int64, error) {
+	return defaultService.ListProducts(ctx, filter)
+}
+
+// GetSalesOrder busca um pedido de venda
+func GetSalesOrder(ctx context.Context, id int) (*sales.SalesOrder, error) {
+	return defaultService.GetSalesOrder(ctx, id)
+}
+
+// ListSalesOrders lista os pedidos de venda
+func ListSalesOrders(ctx context.Context, filter models.ListFilter) ([]sales.SalesOrder, int64, error) {
+	return defaultService.ListSalesOrders(ctx, filter)
+}
+
+// GetInvoice busca uma fatura
+func GetInvoice(ctx context.Context, id int) (*sales.Invoice, error) {
+	return defaultService.GetInvoice(ctx, id)
+}
+
+// ListInvoices lista as faturas
+func ListInvoices(ctx context.Context, filter models.ListFilter) ([]sales.Invoice, int64, error) {
+	return defaultService.ListInvoices(ctx, filter)
+}
+
+func (s *Service) repository() (repository.GRPCRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// normalize aplica a página e o tamanho padrão, limitando ao máximo da API REST
+func normalize(filter models.ListFilter) models.ListFilter {
+	if filter.Page < 1 {
+		filter.Page = pagination.DefaultPage
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = pagination.DefaultPageSize
+	}
+	if filter.PageSize > pagination.MaxPageSize {
+		filter.PageSize = pagination.MaxPageSize
+	}
+	return filter
+}
+
+// GetContact busca um contato não excluído
+func (s *Service) GetContact(ctx context.Context, id int) (*contact.Contact, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetContact(ctx, id)
+}
+
+// ListContacts lista os contatos, buscando por nome, e-mail ou documento
+func (s *Service) ListContacts(ctx context.Context, filter models.ListFilter) ([]contact.Contact, int64, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, 0, err
+	}
+	return repo.ListContacts(ctx, normalize(filter))
+}
+
+// GetProduct busca um produto
+func (s *Service) GetProduct(ctx context.Context, id int) (*product.Product, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetProduct(ctx, id)
+}
+
+// ListProducts lista os produtos, buscando por nome ou SKU
+func (s *Service) ListProducts(ctx context.Context, filter models.ListFilter) ([]product.Product, int64, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, 0, err
+	}
+	return repo.ListProducts(ctx, normalize(filter))
+}
+
+// GetSalesOrder busca o pedido de venda com os itens
+func (s *Service) GetSalesOrder(ctx context.Context, id int) (*sales.SalesOrder, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetSalesOrder(ctx, id)
+}
+
+// ListSalesOrders lista os pedidos de venda, filtrando por contato e status
+func (s *Service) ListSalesOrders(ctx context.Context, filter models.ListFilter) ([]sales.SalesOrder, int64, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, 0, err
+	}
+	return repo.ListSalesOrders(ctx, normalize(filter))
+}
+
+// GetInvoice busca a fatura com os itens e pagamentos
+func (s *Service) GetInvoice(ctx context.Context, id int) (*sales.Invoice, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetInvoice(ctx, id)
+}
+
+// ListInvoices lista as faturas, filtrando por contato e status
+func (s *Service) ListInvoices(ctx context.Context, filter models.ListFilter) ([]sales.Invoice, int64, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, 0, err
+	}
+	return repo.ListInvoices(ctx, normalize(filter))
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/errors"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/models"
+	"ERP-ONSMART/backend/internal/modules/grpcapi/repository"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepo guarda o último filtro recebido pelas listagens
+type fakeRepo struct {
+	filter models.ListFilter
+}
+
+func (f *fakeRepo) GetContact(_ context.Context, id int) (*contact.Contact, error) {
+	if id != 7 {
+		return nil, errors.ErrContactNotFound
+	}
+	return &contact.Contact{ID: 7, Name: "ACME Ltda"}, nil
+}
+
+func (f *fakeRepo) ListContacts(_ context.Context, filter models.ListFilter) ([]contact.Contact, int64, error) {
+	f.filter = filter
+	return []contact.Contact{{ID: 7}}, 1, nil
+}
+
+func (f *fakeRepo) GetProduct(_ context.Context, id int) (*product.Product, error) {
+	return nil, errors.ErrProductNotFound
+}
+
+func (f *fakeRepo) ListProducts(_ context.Context, filter models.ListFilter) ([]product.Product, int64, error) {
+	f.filter = filter
+	return nil, 0, nil
+}
+
+func (f *fakeRepo) GetSalesOrder(_ context.Context, id int) (*sales.SalesOrder, error) {
+	return &sales.SalesOrder{ID: id, Items: []sales.SOItem{{ID: 1}}}, nil
+}
+
+func (f *fakeRepo) ListSalesOrders(_ context.Context, filter models.ListFilter) ([]sales.SalesOrder, int64, error) {
+	f.filter = filter
+	return nil, 0, nil
+}
+
+func (f *fakeRepo) GetInvoice(_ context.Context, id int) (*sales.Invoice, error) {
+	return nil, errors.ErrInvoiceNotFound
+}
+
+func (f *fakeRepo) ListInvoices(_ context.Context, filter models.ListFilter) ([]sales.Invoice, int64, error) {
+	f.filter = filter
+	return nil, 0, nil
+}
+
+func newTestService() (*Service, *fakeRepo) {
+	repo := &fakeRepo{}
+	return NewService(func() (repository.GRPCRepository, error) { return repo, nil }), repo
+}
+
+func TestListNormalizesPagination(t *testing.T) {
+	s, repo := newTestService()
+	ctx := context.Background()
+
+	_, total, err := s.ListContacts(ctx, models.ListFilter{Search: "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, models.ListFilter{Page: pagination.DefaultPage, PageSize: pagination.DefaultPageSize, Search: "acme"}, repo.filter)
+
+	_, _, err = s.ListProducts(ctx, models.ListFilter{Page: 3, PageSize: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, 3, repo.filter.Page)
+	assert.Equal(t, pagination.MaxPageSize, repo.filter.PageSize)
+
+	_, _, err = s.ListInvoices(ctx, models.ListFilter{Page: -1, PageSize: 20, ContactID: 7, Status: "paid"})
+	require.NoError(t, err)
+	assert.Equal(t, models.ListFilter{Page: 1, PageSize: 20, ContactID: 7, Status: "paid"}, repo.filter)
+}
+
+func TestGetReturnsRepositoryErrors(t *testing.T) {
+	s, _ := newTestService()
+	ctx := context.Background()
+
+	found, err := s.GetContact(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "ACME Ltda", found.Name)
+
+	_, err = s.GetContact(ctx, 8)
+	assert.ErrorIs(t, err, errors.ErrContactNotFound)
+	_, err = s.GetInvoice(ctx, 1)
+	assert.ErrorIs(t, err, errors.ErrInvoiceNotFound)
+}
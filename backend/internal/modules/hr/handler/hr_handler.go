@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/hr/models"
+	"ERP-ONSMART/backend/internal/modules/hr/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os departamentos pelo código
+// @Security BearerAuth
+func ListDepartmentsHandler(c *gin.Context) {
+	departments, err := service.ListDepartments(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar departamentos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"departments": departments})
+}
+
+// Cadastra um departamento, ligado ao centro de custo que recebe o custo dos seus colaboradores
+// @Security BearerAuth
+func CreateDepartmentHandler(c *gin.Context) {
+	var input models.DepartmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	department, err := service.CreateDepartment(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cadastrar departamento")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"department": department})
+}
+
+// Altera um departamento
+// @Security BearerAuth
+// @Param id path int true "ID do departamento"
+func UpdateDepartmentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.DepartmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	department, err := service.UpdateDepartment(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar departamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"department": department})
+}
+
+// Lista os colaboradores pelo nome
+// @Security BearerAuth
+// @Param department_id query int false "Departamento"
+// @Param user_id query int false "Usuário vinculado"
+// @Param active_on query string false "Só os admitidos e não desligados na data (AAAA-MM-DD)"
+func ListEmployeesHandler(c *gin.Context) {
+	var filter models.EmployeeFilter
+	if value := c.Query("department_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("department_id inválido"))
+			return
+		}
+		filter.DepartmentID = id
+	}
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("user_id inválido"))
+			return
+		}
+		filter.UserID = id
+	}
+	if value := c.Query("active_on"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.Error(errors.InvalidParam("active_on deve estar no formato AAAA-MM-DD"))
+			return
+		}
+		filter.ActiveOn = date
+	}
+
+	employees, err := service.ListEmployees(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar colaboradores")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"employees": employees})
+}
+
+// Busca um colaborador com o departamento e o rateio do custo
+// @Security BearerAuth
+// @Param id path int true "ID do colaborador"
+func GetEmployeeHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	employee, err := service.GetEmployee(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar colaborador")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"employee": employee})
+}
+
+// Cadastra um colaborador com o custo mensal e, opcionalmente, o usuário e o rateio por centro
+// de custo
+// @Security BearerAuth
+func CreateEmployeeHandler(c *gin.Context) {
+	var input models.EmployeeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	employee, err := service.CreateEmployee(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cadastrar colaborador")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"employee": employee})
+}
+
+// Altera um colaborador e substitui o rateio do custo
+// @Security BearerAuth
+// @Param id path int true "ID do colaborador"
+func UpdateEmployeeHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.EmployeeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	employee, err := service.UpdateEmployee(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar colaborador")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"employee": employee})
+}
+
+// Conta os orçamentos, pedidos, faturas, ordens de serviço e entregas do colaborador
+// @Security BearerAuth
+// @Param id path int true "ID do colaborador"
+func GetEmployeeDocumentsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	counts, err := service.CountEmployeeDocuments(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao contar documentos do colaborador")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": counts})
+}
+
+// Liga o colaborador ao documento como vendedor, técnico ou motorista; employee_id nulo remove
+// o vínculo
+// @Security BearerAuth
+// @Param type path string true "quotation, sales_order, invoice, service_order ou delivery"
+// @Param id path int true "ID do documento"
+func AssignEmployeeHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.AssignEmployeeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.AssignEmployee(c.Request.Context(), c.Param("type"), id, input.EmployeeID); err != nil {
+		c.Error(err).SetMeta("erro ao vincular colaborador ao documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"type": c.Param("type"), "id": id, "employee_id": input.EmployeeID})
+}
+
+// Rateia o custo da mão de obra do mês entre os centros de custo, refazendo o rateio ainda não
+// contabilizado
+// @Security BearerAuth
+// @Param period query string true "Mês do rateio (AAAA-MM)"
+func AllocateLaborCostsHandler(c *gin.Context) {
+	period := c.Query("period")
+	if period == "" {
+		c.Error(errors.InvalidParam("informe o mês do rateio (AAAA-MM)"))
+		return
+	}
+
+	allocations, err := service.AllocateLaborCosts(c.Request.Context(), period)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao ratear mão de obra")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"period": period, "allocations": allocations})
+}
+
+// Lista o rateio de mão de obra do mês por colaborador e centro de custo
+// @Security BearerAuth
+// @Param period query string true "Mês do rateio (AAAA-MM)"
+func ListLaborCostsHandler(c *gin.Context) {
+	period := c.Query("period")
+	if period == "" {
+		c.Error(errors.InvalidParam("informe o mês do rateio (AAAA-MM)"))
+		return
+	}
+
+	allocations, err := service.ListLaborCosts(c.Request.Context(), period)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar rateio de mão de obra")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"period": period, "allocations": allocations})
+}
+
+// Compara a receita das faturas dos vendedores de cada departamento com o custo de mão de obra
+// rateado no período
+// @Security BearerAuth
+// @Param from query string true "Mês inicial (AAAA-MM)"
+// @Param to query string true "Mês final (AAAA-MM)"
+func GetProfitabilityReportHandler(c *gin.Context) {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		c.Error(errors.InvalidParam("informe os meses inicial e final (AAAA-MM)"))
+		return
+	}
+
+	report, err := service.ProfitabilityReport(c.Request.Context(), from, to)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao apurar rentabilidade por departamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,343 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Os colaboradores são cadastrados à parte dos usuários do sistema: nem todo colaborador acessa
+// o ERP, e o vínculo opcional com o usuário liga o colaborador aos documentos em que aparece
+// como vendedor ou técnico. O custo mensal de cada colaborador é rateado entre centros de custo
+// (os do cadastro ou, sem eles, o do departamento) e contabilizado, o que permite apurar a DRE
+// e a rentabilidade por departamento.
+
+// PeriodLayout é o formato dos meses do rateio (AAAA-MM)
+const PeriodLayout = "2006-01"
+
+// MaxReportMonths é o maior período do relatório de rentabilidade
+const MaxReportMonths = 24
+
+// Department é um departamento da empresa, ligado ao centro de custo que recebe o seu custo
+type Department struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	CompanyID    int       `json:"company_id" gorm:"<-:create"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	CostCenterID *int      `json:"cost_center_id,omitempty"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Employee é um colaborador da empresa
+type Employee struct {
+	ID              int           `json:"id" gorm:"primaryKey"`
+	CompanyID       int           `json:"company_id" gorm:"<-:create"`
+	Name            string        `json:"name"`
+	Email           string        `json:"email,omitempty"`
+	Document        string        `json:"document,omitempty"`
+	Position        string        `json:"position,omitempty"`
+	DepartmentID    *int          `json:"department_id,omitempty"`
+	UserID          *int          `json:"user_id,omitempty"`
+	MonthlyCost     money.Decimal `json:"monthly_cost"`
+	HireDate        time.Time     `json:"hire_date"`
+	TerminationDate *time.Time    `json:"termination_date,omitempty"`
+	CreatedAt       time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Department *Department `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
+	CostSplits []CostSplit `json:"cost_splits,omitempty" gorm:"foreignKey:EmployeeID"`
+}
+
+// CostSplit é a parte do custo do colaborador atribuída a um centro de custo, em percentual
+type CostSplit struct {
+	ID           int           `json:"id" gorm:"primaryKey"`
+	CompanyID    int           `json:"company_id" gorm:"<-:create"`
+	EmployeeID   int           `json:"employee_id"`
+	CostCenterID int           `json:"cost_center_id" binding:"required"`
+	Percent      money.Decimal `json:"percent" binding:"required"`
+}
+
+// TableName define o nome da tabela do rateio por colaborador
+func (CostSplit) TableName() string {
+	return "employee_cost_splits"
+}
+
+// LaborCostAllocation é o custo de um colaborador no mês atribuído a um centro de custo
+type LaborCostAllocation struct {
+	ID           int           `json:"id" gorm:"primaryKey"`
+	CompanyID    int           `json:"company_id" gorm:"<-:create"`
+	Period       string        `json:"period"`
+	EmployeeID   int           `json:"employee_id"`
+	DepartmentID *int          `json:"department_id,omitempty"`
+	CostCenterID *int          `json:"cost_center_id,omitempty"`
+	Days         int           `json:"days"`
+	Amount       money.Decimal `json:"amount"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"autoCreateTime"`
+
+	Employee *Employee `json:"employee,omitempty" gorm:"foreignKey:EmployeeID"`
+}
+
+// DepartmentInput é o corpo aceito em POST e PUT /hr/departments
+type DepartmentInput struct {
+	Code         string `json:"code" binding:"required,max=30"`
+	Name         string `json:"name" binding:"required,max=150"`
+	CostCenterID *int   `json:"cost_center_id"`
+	Active       *bool  `json:"active"`
+}
+
+// EmployeeInput é o corpo aceito em POST e PUT /hr/employees. Sem rateio, o custo vai para o
+// centro de custo do departamento.
+type EmployeeInput struct {
+	Name            string        `json:"name" binding:"required,max=150"`
+	Email           string        `json:"email" binding:"max=150"`
+	Document        string        `json:"document" binding:"max=20"`
+	Position        string        `json:"position" binding:"max=100"`
+	DepartmentID    *int          `json:"department_id"`
+	UserID          *int          `json:"user_id"`
+	MonthlyCost     money.Decimal `json:"monthly_cost"`
+	HireDate        time.Time     `json:"hire_date" binding:"required"`
+	TerminationDate *time.Time    `json:"termination_date"`
+	CostSplits      []CostSplit   `json:"cost_splits" binding:"dive"`
+}
+
+// AssignEmployeeInput é o corpo aceito em PUT /hr/documents/:type/:id/employee; employee_id
+// nulo remove o vínculo
+type AssignEmployeeInput struct {
+	EmployeeID *int `json:"employee_id"`
+}
+
+// EmployeeFilter restringe a listagem de colaboradores; campos vazios não filtram
+type EmployeeFilter struct {
+	DepartmentID int
+	UserID       int
+	ActiveOn     time.Time // colaboradores admitidos e não desligados na data
+}
+
+// EmployeeDocument descreve como um tipo de documento aponta o colaborador responsável: pelo
+// usuário vinculado (vendedor, técnico) ou diretamente pelo colaborador (motorista)
+type EmployeeDocument struct {
+	Table  string
+	Column string
+	ByUser bool
+}
+
+// EmployeeDocuments são os documentos com colaborador responsável, pelo tipo usado na rota
+var EmployeeDocuments = map[string]EmployeeDocument{
+	"quotation":     {Table: "quotations", Column: "salesperson_id", ByUser: true},
+	"sales_order":   {Table: "sales_orders", Column: "salesperson_id", ByUser: true},
+	"invoice":       {Table: "invoices", Column: "salesperson_id", ByUser: true},
+	"service_order": {Table: "service_orders", Column: "technician_id", ByUser: true},
+	"delivery":      {Table: "deliveries", Column: "driver_id"},
+}
+
+// DocumentCount é o número de documentos de um tipo em que o colaborador é o responsável
+type DocumentCount struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+}
+
+// DepartmentResult é a receita e o custo de mão de obra de um departamento no período
+type DepartmentResult struct {
+	DepartmentID int           `json:"department_id"`
+	Code         string        `json:"code"`
+	Name         string        `json:"name"`
+	Revenue      money.Decimal `json:"revenue"`
+	LaborCost    money.Decimal `json:"labor_cost"`
+	Margin       money.Decimal `json:"margin"`
+}
+
+// ProfitabilityReport é a rentabilidade dos departamentos no período; a receita de faturas sem
+// vendedor ligado a um departamento fica em unassigned_revenue
+type ProfitabilityReport struct {
+	From              string             `json:"from"`
+	To                string             `json:"to"`
+	Departments       []DepartmentResult `json:"departments"`
+	UnassignedRevenue money.Decimal      `json:"unassigned_revenue"`
+}
+
+// DepartmentAmount é um valor somado por departamento; sem departamento, DepartmentID é nulo
+type DepartmentAmount struct {
+	DepartmentID *int          `json:"department_id"`
+	Amount       money.Decimal `json:"amount"`
+}
+
+// Validate confere o colaborador: custo não negativo, e-mail válido, desligamento depois da
+// admissão e rateio somando 100% em centros de custo distintos
+func (in EmployeeInput) Validate() error {
+	if strings.TrimSpace(in.Name) == "" || in.MonthlyCost.IsNegative() {
+		return errors.ErrInvalidEmployee
+	}
+	if email := strings.TrimSpace(in.Email); email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return fmt.Errorf("%w: e-mail inválido", errors.ErrInvalidEmployee)
+		}
+	}
+	if in.TerminationDate != nil && in.TerminationDate.Before(in.HireDate) {
+		return fmt.Errorf("%w: o desligamento não pode ser anterior à admissão", errors.ErrInvalidEmployee)
+	}
+	return ValidateCostSplits(in.CostSplits)
+}
+
+// Apply copia os campos do cadastro para o colaborador
+func (in EmployeeInput) Apply(employee *Employee) {
+	employee.Name = strings.TrimSpace(in.Name)
+	employee.Email = strings.TrimSpace(in.Email)
+	employee.Document = strings.TrimSpace(in.Document)
+	employee.Position = strings.TrimSpace(in.Position)
+	employee.DepartmentID = in.DepartmentID
+	employee.UserID = in.UserID
+	employee.MonthlyCost = in.MonthlyCost.Round(2)
+	employee.HireDate = in.HireDate
+	employee.TerminationDate = in.TerminationDate
+	employee.CostSplits = make([]CostSplit, len(in.CostSplits))
+	for i, split := range in.CostSplits {
+		employee.CostSplits[i] = CostSplit{CostCenterID: split.CostCenterID, Percent: split.Percent.Round(2)}
+	}
+}
+
+// ValidateCostSplits confere o rateio: vazio, ou percentuais positivos em centros de custo
+// distintos somando 100
+func ValidateCostSplits(splits []CostSplit) error {
+	if len(splits) == 0 {
+		return nil
+	}
+	total := money.Zero
+	seen := map[int]bool{}
+	for _, split := range splits {
+		if !split.Percent.IsPositive() || seen[split.CostCenterID] {
+			return errors.ErrInvalidCostSplit
+		}
+		seen[split.CostCenterID] = true
+		total = total.Add(split.Percent)
+	}
+	if !total.Round(2).Equal(money.FromInt(100)) {
+		return errors.ErrInvalidCostSplit
+	}
+	return nil
+}
+
+// DaysEmployed conta os dias do mês (de start até end, exclusivo) em que o colaborador
+// estava admitido e não desligado
+func (e Employee) DaysEmployed(start, end time.Time) int {
+	from, to := start, end
+	if hire := localtime.Date(e.HireDate); hire.After(from) {
+		from = hire
+	}
+	if e.TerminationDate != nil {
+		if last := localtime.Date(*e.TerminationDate).AddDate(0, 0, 1); last.Before(to) {
+			to = last
+		}
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// AllocateLaborCost reparte o custo do colaborador no mês period (AAAA-MM) entre os centros de
+// custo: proporcional aos dias trabalhados no mês e dividido pelos percentuais do rateio ou,
+// sem rateio, todo no centro de custo do departamento. Os centavos que sobram da divisão vão
+// para o último centro.
+func AllocateLaborCost(employee Employee, department *Department, period string) []LaborCostAllocation {
+	start, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return nil
+	}
+	end := start.AddDate(0, 1, 0)
+	days := employee.DaysEmployed(start, end)
+	monthDays := int(end.Sub(start).Hours() / 24)
+	if days == 0 || !employee.MonthlyCost.IsPositive() {
+		return nil
+	}
+
+	cost := employee.MonthlyCost
+	if days < monthDays {
+		cost = cost.Prorate(days, monthDays)
+	}
+	cost = cost.Round(2)
+
+	base := LaborCostAllocation{Period: period, EmployeeID: employee.ID, DepartmentID: employee.DepartmentID, Days: days}
+	if len(employee.CostSplits) == 0 {
+		allocation := base
+		allocation.Amount = cost
+		if department != nil {
+			allocation.CostCenterID = department.CostCenterID
+		}
+		return []LaborCostAllocation{allocation}
+	}
+
+	allocations := make([]LaborCostAllocation, 0, len(employee.CostSplits))
+	remaining := cost
+	for i, split := range employee.CostSplits {
+		amount := cost.Mul(split.Percent).DivInt(100).Round(2)
+		if i == len(employee.CostSplits)-1 {
+			amount = remaining
+		}
+		remaining = remaining.Sub(amount)
+		centerID := split.CostCenterID
+		allocation := base
+		allocation.CostCenterID = &centerID
+		allocation.Amount = amount
+		allocations = append(allocations, allocation)
+	}
+	return allocations
+}
+
+// BuildProfitabilityReport junta a receita e o custo de mão de obra de cada departamento, na
+// ordem do código. Departamentos inativos entram só se tiveram movimento no período.
+func BuildProfitabilityReport(departments []Department, revenue, labor []DepartmentAmount, from, to string) *ProfitabilityReport {
+	report := &ProfitabilityReport{From: from, To: to, Departments: []DepartmentResult{}, UnassignedRevenue: money.Zero}
+	revenueBy, laborBy := map[int]money.Decimal{}, map[int]money.Decimal{}
+	for _, row := range revenue {
+		if row.DepartmentID == nil {
+			report.UnassignedRevenue = report.UnassignedRevenue.Add(row.Amount)
+			continue
+		}
+		revenueBy[*row.DepartmentID] = revenueBy[*row.DepartmentID].Add(row.Amount)
+	}
+	for _, row := range labor {
+		if row.DepartmentID != nil {
+			laborBy[*row.DepartmentID] = laborBy[*row.DepartmentID].Add(row.Amount)
+		}
+	}
+
+	for _, department := range departments {
+		revenue, labor := revenueBy[department.ID], laborBy[department.ID]
+		if !department.Active && revenue.IsZero() && labor.IsZero() {
+			continue
+		}
+		report.Departments = append(report.Departments, DepartmentResult{
+			DepartmentID: department.ID,
+			Code:         department.Code,
+			Name:         department.Name,
+			Revenue:      revenue.Round(2),
+			LaborCost:    labor.Round(2),
+			Margin:       revenue.Sub(labor).Round(2),
+		})
+	}
+	return report
+}
+
+// ParsePeriod valida o mês no formato AAAA-MM
+func ParsePeriod(period string) (time.Time, error) {
+	month, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return time.Time{}, errors.InvalidParam("o mês deve estar no formato AAAA-MM")
+	}
+	return month, nil
+}
+
+// PeriodEnd é o último dia do mês AAAA-MM, à meia-noite UTC
+func PeriodEnd(period string) time.Time {
+	month, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return time.Time{}
+	}
+	return month.AddDate(0, 1, -1)
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestValidateCostSplits(t *testing.T) {
+	assert.NoError(t, ValidateCostSplits(nil), "sem rateio o custo vai para o departamento")
+	assert.NoError(t, ValidateCostSplits([]CostSplit{
+		{CostCenterID: 1, Percent: money.FromInt(60)},
+		{CostCenterID: 2, Percent: money.FromInt(40)},
+	}))
+
+	assert.ErrorIs(t, ValidateCostSplits([]CostSplit{
+		{CostCenterID: 1, Percent: money.FromInt(60)},
+		{CostCenterID: 2, Percent: money.FromInt(30)},
+	}), errors.ErrInvalidCostSplit, "soma diferente de 100")
+	assert.ErrorIs(t, ValidateCostSplits([]CostSplit{
+		{CostCenterID: 1, Percent: money.FromInt(50)},
+		{CostCenterID: 1, Percent: money.FromInt(50)},
+	}), errors.ErrInvalidCostSplit, "centro de custo repetido")
+	assert.ErrorIs(t, ValidateCostSplits([]CostSplit{
+		{CostCenterID: 1, Percent: money.FromInt(110)},
+		{CostCenterID: 2, Percent: money.FromInt(-10)},
+	}), errors.ErrInvalidCostSplit, "percentual negativo")
+}
+
+func TestEmployeeInputValidate(t *testing.T) {
+	hire := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	input := EmployeeInput{Name: "Ana", Email: "ana@empresa.com", MonthlyCost: money.FromInt(5000), HireDate: hire}
+	assert.NoError(t, input.Validate())
+
+	badEmail := input
+	badEmail.Email = "ana"
+	assert.ErrorIs(t, badEmail.Validate(), errors.ErrInvalidEmployee)
+
+	terminated := input
+	before := hire.AddDate(0, 0, -1)
+	terminated.TerminationDate = &before
+	assert.ErrorIs(t, terminated.Validate(), errors.ErrInvalidEmployee, "desligamento antes da admissão")
+
+	negative := input
+	negative.MonthlyCost = money.FromInt(-1)
+	assert.ErrorIs(t, negative.Validate(), errors.ErrInvalidEmployee)
+}
+
+func TestAllocateLaborCost(t *testing.T) {
+	department := &Department{ID: 3, CostCenterID: intPtr(9)}
+	employee := Employee{ID: 1, DepartmentID: intPtr(3), MonthlyCost: money.FromInt(3000), HireDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	allocations := AllocateLaborCost(employee, department, "2026-04")
+	require.Len(t, allocations, 1)
+	assert.Equal(t, 9, *allocations[0].CostCenterID, "sem rateio vai para o centro do departamento")
+	assert.Equal(t, 30, allocations[0].Days)
+	assert.Equal(t, "3000.00", allocations[0].Amount.StringFixed(2))
+
+	// Admitida em 11/04: 20 de 30 dias
+	employee.HireDate = time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC)
+	employee.CostSplits = []CostSplit{
+		{CostCenterID: 4, Percent: money.FromInt(33)},
+		{CostCenterID: 5, Percent: money.FromInt(67)},
+	}
+	allocations = AllocateLaborCost(employee, department, "2026-04")
+	require.Len(t, allocations, 2)
+	assert.Equal(t, 20, allocations[0].Days)
+	assert.Equal(t, "660.00", allocations[0].Amount.StringFixed(2))
+	assert.Equal(t, "1340.00", allocations[1].Amount.StringFixed(2))
+	assert.Equal(t, 5, *allocations[1].CostCenterID)
+
+	// Desligada em 05/04: 5 de 30 dias
+	terminated := time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)
+	employee.HireDate = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	employee.TerminationDate = &terminated
+	employee.CostSplits = nil
+	allocations = AllocateLaborCost(employee, department, "2026-04")
+	require.Len(t, allocations, 1)
+	assert.Equal(t, 5, allocations[0].Days, "o dia do desligamento conta")
+	assert.Equal(t, "500.00", allocations[0].Amount.StringFixed(2))
+	assert.Empty(t, AllocateLaborCost(employee, department, "2026-05"), "já desligada")
+}
+
+func TestBuildProfitabilityReport(t *testing.T) {
+	departments := []Department{
+		{ID: 1, Code: "COM", Name: "Comercial", Active: true},
+		{ID: 2, Code: "OLD", Name: "Antigo"},
+		{ID: 3, Code: "SUP", Name: "Suporte", Active: true},
+	}
+	revenue := []DepartmentAmount{
+		{DepartmentID: intPtr(1), Amount: money.FromInt(10000)},
+		{Amount: money.FromInt(700)},
+	}
+	labor := []DepartmentAmount{
+		{DepartmentID: intPtr(1), Amount: money.FromInt(4000)},
+		{DepartmentID: intPtr(3), Amount: money.FromInt(2500)},
+	}
+
+	report := BuildProfitabilityReport(departments, revenue, labor, "2026-01", "2026-03")
+	require.Len(t, report.Departments, 2, "departamento inativo sem movimento fica de fora")
+	assert.Equal(t, "6000.00", report.Departments[0].Margin.StringFixed(2))
+	assert.Equal(t, "-2500.00", report.Departments[1].Margin.StringFixed(2))
+	assert.Equal(t, "700.00", report.UnassignedRevenue.StringFixed(2))
+}
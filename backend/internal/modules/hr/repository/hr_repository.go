@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	accounting "ERP-ONSMART/backend/internal/modules/accounting/models"
+	finance "ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/hr/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// HRRepository mantém os departamentos, os colaboradores com o rateio do custo, o vínculo dos
+// colaboradores com os documentos e o rateio mensal da mão de obra
+type HRRepository interface {
+	ListDepartments(ctx context.Context) ([]models.Department, error)
+	GetDepartment(ctx context.Context, id int) (*models.Department, error)
+	CreateDepartment(ctx context.Context, department *models.Department) error
+	UpdateDepartment(ctx context.Context, department *models.Department) error
+
+	ListEmployees(ctx context.Context, filter models.EmployeeFilter) ([]models.Employee, error)
+	GetEmployee(ctx context.Context, id int) (*models.Employee, error)
+	CreateEmployee(ctx context.Context, employee *models.Employee) error
+	UpdateEmployee(ctx context.Context, employee *models.Employee) error
+
+	GetCostCenter(ctx context.Context, id int) (*finance.CostCenter, error)
+	UserExists(ctx context.Context, id int) (bool, error)
+
+	AssignEmployee(ctx context.Context, documentType string, id int, value *int) error
+	CountDocuments(ctx context.Context, employee *models.Employee) ([]models.DocumentCount, error)
+
+	ListEmployeesForPeriod(ctx context.Context, start, end time.Time) ([]models.Employee, error)
+	ReplaceAllocations(ctx context.Context, period string, allocations []models.LaborCostAllocation) error
+	ListAllocations(ctx context.Context, period string) ([]models.LaborCostAllocation, error)
+
+	RevenueByDepartment(ctx context.Context, from, to time.Time) ([]models.DepartmentAmount, error)
+	LaborCostByDepartment(ctx context.Context, fromPeriod, toPeriod string) ([]models.DepartmentAmount, error)
+}
+
+type hrRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewHRRepository cria uma nova instância do repositório
+func NewHRRepository() (HRRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &hrRepository{
+		db:     db,
+		logger: logger.WithModule("hr_repository"),
+	}, nil
+}
+
+// ListDepartments lista os departamentos da empresa pelo código
+func (r *hrRepository) ListDepartments(ctx context.Context) ([]models.Department, error) {
+	var departments []models.Department
+	if err := r.db.WithContext(ctx).Order("code ASC").Find(&departments).Error; err != nil {
+		r.logger.Error("erro ao listar departamentos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar departamentos")
+	}
+	return departments, nil
+}
+
+// GetDepartment busca um departamento pelo ID
+func (r *hrRepository) GetDepartment(ctx context.Context, id int) (*models.Department, error) {
+	var department models.Department
+	if err := r.db.WithContext(ctx).First(&department, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrDepartmentNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar departamento")
+	}
+	return &department, nil
+}
+
+// CreateDepartment grava um departamento com código único na empresa
+func (r *hrRepository) CreateDepartment(ctx context.Context, department *models.Department) error {
+	if err := r.checkDepartmentCode(ctx, department); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(department).Error; err != nil {
+		r.logger.Error("erro ao criar departamento", zap.Error(err), zap.String("code", department.Code))
+		return errors.WrapError(err, "falha ao criar departamento")
+	}
+	return nil
+}
+
+// UpdateDepartment altera código, nome, centro de custo e situação do departamento
+func (r *hrRepository) UpdateDepartment(ctx context.Context, department *models.Department) error {
+	if err := r.checkDepartmentCode(ctx, department); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(department).
+		Select("code", "name", "cost_center_id", "active", "updated_at").
+		Updates(department)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar departamento", zap.Error(result.Error), zap.Int("id", department.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar departamento")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrDepartmentNotFound
+	}
+	return nil
+}
+
+func (r *hrRepository) checkDepartmentCode(ctx context.Context, department *models.Department) error {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Department{}).
+		Where("code = ? AND id <> ?", department.Code, department.ID).
+		Count(&count).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao verificar código do departamento")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateDepartmentCode
+	}
+	return nil
+}
+
+// ListEmployees lista os colaboradores pelo nome, com o departamento
+func (r *hrRepository) ListEmployees(ctx context.Context, filter models.EmployeeFilter) ([]models.Employee, error) {
+	query := r.db.WithContext(ctx).Preload("Department")
+	if filter.DepartmentID > 0 {
+		query = query.Where("department_id = ?", filter.DepartmentID)
+	}
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.ActiveOn.IsZero() {
+		query = query.Where("hire_date <= ? AND (termination_date IS NULL OR termination_date >= ?)", filter.ActiveOn, filter.ActiveOn)
+	}
+
+	var employees []models.Employee
+	if err := query.Order("name ASC, id ASC").Find(&employees).Error; err != nil {
+		r.logger.Error("erro ao listar colaboradores", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar colaboradores")
+	}
+	return employees, nil
+}
+
+// GetEmployee busca o colaborador com o departamento e o rateio do custo
+func (r *hrRepository) GetEmployee(ctx context.Context, id int) (*models.Employee, error) {
+	var employee models.Employee
+	err := r.db.WithContext(ctx).
+		Preload("Department").
+		Preload("CostSplits", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&employee, id).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrEmployeeNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar colaborador")
+	}
+	return &employee, nil
+}
+
+// CreateEmployee grava o colaborador com o rateio; cada usuário fica ligado a um só colaborador
+func (r *hrRepository) CreateEmployee(ctx context.Context, employee *models.Employee) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := r.checkEmployeeUser(tx, employee); err != nil {
+			return err
+		}
+		if err := tx.Omit("Department", "CostSplits").Create(employee).Error; err != nil {
+			r.logger.Error("erro ao criar colaborador", zap.Error(err), zap.String("name", employee.Name))
+			return errors.WrapError(err, "falha ao criar colaborador")
+		}
+		return r.saveCostSplits(tx, employee)
+	})
+}
+
+// UpdateEmployee altera o cadastro do colaborador e substitui o rateio do custo
+func (r *hrRepository) UpdateEmployee(ctx context.Context, employee *models.Employee) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := r.checkEmployeeUser(tx, employee); err != nil {
+			return err
+		}
+		result := tx.Model(employee).
+			Select("name", "email", "document", "position", "department_id", "user_id", "monthly_cost",
+				"hire_date", "termination_date", "updated_at").
+			Updates(employee)
+		if result.Error != nil {
+			r.logger.Error("erro ao atualizar colaborador", zap.Error(result.Error), zap.Int("id", employee.ID))
+			return errors.WrapError(result.Error, "falha ao atualizar colaborador")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrEmployeeNotFound
+		}
+		if err := tx.Where("employee_id = ?", employee.ID).Delete(&models.CostSplit{}).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover rateio do colaborador")
+		}
+		return r.saveCostSplits(tx, employee)
+	})
+}
+
+func (r *hrRepository) saveCostSplits(tx *gorm.DB, employee *models.Employee) error {
+	if len(employee.CostSplits) == 0 {
+		return nil
+	}
+	for i := range employee.CostSplits {
+		employee.CostSplits[i].ID = 0
+		employee.CostSplits[i].EmployeeID = employee.ID
+	}
+	if err := tx.Create(&employee.CostSplits).Error; err != nil {
+		return errors.WrapError(err, "falha ao gravar rateio do colaborador")
+	}
+	return nil
+}
+
+// checkEmployeeUser recusa o usuário já ligado a outro colaborador
+func (r *hrRepository) checkEmployeeUser(tx *gorm.DB, employee *models.Employee) error {
+	if employee.UserID == nil {
+		return nil
+	}
+	var count int64
+	err := tx.Model(&models.Employee{}).
+		Where("user_id = ? AND id <> ?", *employee.UserID, employee.ID).
+		Count(&count).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao verificar usuário do colaborador")
+	}
+	if count > 0 {
+		return errors.ErrEmployeeUserTaken
+	}
+	return nil
+}
+
+// GetCostCenter busca o centro de custo que recebe o custo do departamento ou do colaborador
+func (r *hrRepository) GetCostCenter(ctx context.Context, id int) (*finance.CostCenter, error) {
+	var center finance.CostCenter
+	if err := r.db.WithContext(ctx).First(&center, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrCostCenterNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar centro de custo")
+	}
+	return &center, nil
+}
+
+// UserExists indica se o usuário pertence à empresa
+func (r *hrRepository) UserExists(ctx context.Context, id int) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("users").Scopes(tenant.Scope(ctx, "users")).
+		Where("id = ?", id).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.WrapError(err, "falha ao buscar usuário")
+	}
+	return count > 0, nil
+}
+
+// AssignEmployee grava o responsável do documento: o usuário do colaborador nos documentos de
+// vendedor e técnico, o próprio colaborador nas entregas
+func (r *hrRepository) AssignEmployee(ctx context.Context, documentType string, id int, value *int) error {
+	document, ok := models.EmployeeDocuments[documentType]
+	if !ok {
+		return errors.ErrInvalidEmployeeDocument
+	}
+
+	result := r.db.WithContext(ctx).Table(document.Table).Scopes(tenant.Scope(ctx, document.Table)).
+		Where(document.Table+".id = ?", id).
+		Update(document.Column, value)
+	if result.Error != nil {
+		r.logger.Error("erro ao vincular colaborador ao documento", zap.Error(result.Error),
+			zap.String("type", documentType), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao vincular colaborador ao documento")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrEntityNotFound
+	}
+	return nil
+}
+
+// CountDocuments conta, por tipo, os documentos em que o colaborador é o responsável
+func (r *hrRepository) CountDocuments(ctx context.Context, employee *models.Employee) ([]models.DocumentCount, error) {
+	counts := make([]models.DocumentCount, 0, len(models.EmployeeDocuments))
+	for _, documentType := range []string{"quotation", "sales_order", "invoice", "service_order", "delivery"} {
+		document := models.EmployeeDocuments[documentType]
+		value := employee.ID
+		if document.ByUser {
+			if employee.UserID == nil {
+				counts = append(counts, models.DocumentCount{Type: documentType})
+				continue
+			}
+			value = *employee.UserID
+		}
+
+		var count int64
+		err := r.db.WithContext(ctx).Table(document.Table).Scopes(tenant.Scope(ctx, document.Table)).
+			Where(document.Table+"."+document.Column+" = ?", value).
+			Count(&count).Error
+		if err != nil {
+			return nil, errors.WrapError(err, "falha ao contar documentos do colaborador")
+		}
+		counts = append(counts, models.DocumentCount{Type: documentType, Count: count})
+	}
+	return counts, nil
+}
+
+// ListEmployeesForPeriod lista os colaboradores admitidos antes de end (exclusivo) e não
+// desligados antes de start, com departamento e rateio
+func (r *hrRepository) ListEmployeesForPeriod(ctx context.Context, start, end time.Time) ([]models.Employee, error) {
+	var employees []models.Employee
+	err := r.db.WithContext(ctx).
+		Preload("Department").
+		Preload("CostSplits", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("hire_date < ? AND (termination_date IS NULL OR termination_date >= ?)", end, start).
+		Order("id ASC").
+		Find(&employees).Error
+	if err != nil {
+		r.logger.Error("erro ao listar colaboradores do mês", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar colaboradores do mês")
+	}
+	return employees, nil
+}
+
+// ReplaceAllocations substitui o rateio de mão de obra do mês. Depois que alguma linha do mês
+// foi contabilizada, o rateio fica fixo.
+func (r *hrRepository) ReplaceAllocations(ctx context.Context, period string, allocations []models.LaborCostAllocation) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var posted int64
+		err := tx.Model(&accounting.JournalEntry{}).
+			Where("source_type = ? AND source_id IN (?)", accounting.SourceLaborCost,
+				tx.Model(&models.LaborCostAllocation{}).Select("id").Where("period = ?", period)).
+			Count(&posted).Error
+		if err != nil {
+			return errors.WrapError(err, "falha ao verificar contabilização do rateio")
+		}
+		if posted > 0 {
+			return errors.ErrLaborCostsPosted
+		}
+
+		if err := tx.Where("period = ?", period).Delete(&models.LaborCostAllocation{}).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover rateio de mão de obra")
+		}
+		if len(allocations) > 0 {
+			if err := tx.Omit("Employee").Create(&allocations).Error; err != nil {
+				r.logger.Error("erro ao gravar rateio de mão de obra", zap.Error(err), zap.String("period", period))
+				return errors.WrapError(err, "falha ao gravar rateio de mão de obra")
+			}
+		}
+		r.logger.Info("rateio de mão de obra gravado", zap.String("period", period), zap.Int("rows", len(allocations)))
+		return nil
+	})
+}
+
+// ListAllocations lista o rateio de mão de obra do mês com os colaboradores
+func (r *hrRepository) ListAllocations(ctx context.Context, period string) ([]models.LaborCostAllocation, error) {
+	var allocations []models.LaborCostAllocation
+	err := r.db.WithContext(ctx).
+		Preload("Employee").
+		Where("period = ?", period).
+		Order("employee_id ASC, id ASC").
+		Find(&allocations).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao listar rateio de mão de obra")
+	}
+	return allocations, nil
+}
+
+// RevenueByDepartment soma a receita líquida de impostos das faturas emitidas no período
+// (limite superior exclusivo) pelo departamento do colaborador ligado ao vendedor
+func (r *hrRepository) RevenueByDepartment(ctx context.Context, from, to time.Time) ([]models.DepartmentAmount, error) {
+	var rows []models.DepartmentAmount
+	err := r.db.WithContext(ctx).Table("invoices i").
+		Select("e.department_id, COALESCE(SUM(i.grand_total - i.tax_total), 0) AS amount").
+		Joins("LEFT JOIN employees e ON e.user_id = i.salesperson_id AND e.company_id = i.company_id").
+		Scopes(tenant.Scope(ctx, "i")).
+		Where("i.status NOT IN ?", []string{sales.InvoiceStatusDraft, sales.InvoiceStatusCancelled}).
+		Where("i.issue_date >= ? AND i.issue_date < ?", from, to).
+		Group("e.department_id").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao somar receita por departamento", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao somar receita por departamento")
+	}
+	return rows, nil
+}
+
+// LaborCostByDepartment soma o rateio de mão de obra dos meses fromPeriod a toPeriod por
+// departamento
+func (r *hrRepository) LaborCostByDepartment(ctx context.Context, fromPeriod, toPeriod string) ([]models.DepartmentAmount, error) {
+	var rows []models.DepartmentAmount
+	err := r.db.WithContext(ctx).Model(&models.LaborCostAllocation{}).
+		Select("department_id, COALESCE(SUM(amount), 0) AS amount").
+		Where("period >= ? AND period <= ?", fromPeriod, toPeriod).
+		Group("department_id").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao somar mão de obra por departamento", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao somar mão de obra por departamento")
+	}
+	return rows, nil
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/hr/models"
+	"ERP-ONSMART/backend/internal/modules/hr/repository"
+)
+
+// HRService mantém departamentos e colaboradores, liga os colaboradores aos documentos em que
+// atuam e rateia o custo mensal da mão de obra entre os centros de custo
+type HRService struct {
+	newRepo func() (repository.HRRepository, error)
+	today   func(ctx context.Context) time.Time
+
+	mu   sync.Mutex
+	repo repository.HRRepository
+}
+
+// NewHRService cria o serviço sobre o repositório informado
+func NewHRService(newRepo func() (repository.HRRepository, error)) *HRService {
+	return &HRService{
+		newRepo: newRepo,
+		today:   localtime.Today,
+	}
+}
+
+var defaultService = NewHRService(repository.NewHRRepository)
+
+// ListDepartments lista os departamentos
+func ListDepartments(ctx context.Context) ([]models.Department, error) {
+	return defaultService.ListDepartments(ctx)
+}
+
+// CreateDepartment valida e grava um departamento
+func CreateDepartment(ctx context.Context, input models.DepartmentInput) (*models.Department, error) {
+	return defaultService.CreateDepartment(ctx, input)
+}
+
+// UpdateDepartment valida e altera um departamento
+func UpdateDepartment(ctx context.Context, id int, input models.DepartmentInput) (*models.Department, error) {
+	return defaultService.UpdateDepartment(ctx, id, input)
+}
+
+// ListEmployees lista os colaboradores
+func ListEmployees(ctx context.Context, filter models.EmployeeFilter) ([]models.Employee, error) {
+	return defaultService.ListEmployees(ctx, filter)
+}
+
+// GetEmployee busca um colaborador com o rateio
+func GetEmployee(ctx context.Context, id int) (*models.Employee, error) {
+	return defaultService.GetEmployee(ctx, id)
+}
+
+// CreateEmployee valida e grava um colaborador
+func CreateEmployee(ctx context.Context, input models.EmployeeInput) (*models.Employee, error) {
+	return defaultService.CreateEmployee(ctx, input)
+}
+
+// UpdateEmployee valida e altera um colaborador
+func UpdateEmployee(ctx context.Context, id int, input models.EmployeeInput) (*models.Employee, error) {
+	return defaultService.UpdateEmployee(ctx, id, input)
+}
+
+// CountEmployeeDocuments conta os documentos do colaborador por tipo
+func CountEmployeeDocuments(ctx context.Context, id int) ([]models.DocumentCount, error) {
+	return defaultService.CountDocuments(ctx, id)
+}
+
+// AssignEmployee liga o colaborador a um documento
+func AssignEmployee(ctx context.Context, documentType string, id int, employeeID *int) error {
+	return defaultService.AssignEmployee(ctx, documentType, id, employeeID)
+}
+
+// AllocateLaborCosts rateia o custo da mão de obra do mês
+func AllocateLaborCosts(ctx context.Context, period string) ([]models.LaborCostAllocation, error) {
+	return defaultService.AllocateLaborCosts(ctx, period)
+}
+
+// ListLaborCosts lista o rateio de mão de obra do mês
+func ListLaborCosts(ctx context.Context, period string) ([]models.LaborCostAllocation, error) {
+	return defaultService.ListLaborCosts(ctx, period)
+}
+
+// ProfitabilityReport apura a rentabilidade por departamento
+func ProfitabilityReport(ctx context.Context, from, to string) (*models.ProfitabilityReport, error) {
+	return defaultService.ProfitabilityReport(ctx, from, to)
+}
+
+func (s *HRService) repository() (repository.HRRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListDepartments lista os departamentos pelo código
+func (s *HRService) ListDepartments(ctx context.Context) ([]models.Department, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDepartments(ctx)
+}
+
+// CreateDepartment grava o departamento, ativo se a situação não for informada
+func (s *HRService) CreateDepartment(ctx context.Context, input models.DepartmentInput) (*models.Department, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	department := &models.Department{Active: true}
+	if err := s.applyDepartment(ctx, repo, department, input); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateDepartment(ctx, department); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// UpdateDepartment altera o departamento
+func (s *HRService) UpdateDepartment(ctx context.Context, id int, input models.DepartmentInput) (*models.Department, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	department, err := repo.GetDepartment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyDepartment(ctx, repo, department, input); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateDepartment(ctx, department); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// applyDepartment confere o centro de custo e copia os campos para o departamento
+func (s *HRService) applyDepartment(ctx context.Context, repo repository.HRRepository, department *models.Department, input models.DepartmentInput) error {
+	code, name := strings.TrimSpace(input.Code), strings.TrimSpace(input.Name)
+	if code == "" || name == "" {
+		return errors.InvalidParam("informe o código e o nome do departamento")
+	}
+	if input.CostCenterID != nil {
+		if err := s.checkCostCenter(ctx, repo, *input.CostCenterID); err != nil {
+			return err
+		}
+	}
+	department.Code, department.Name, department.CostCenterID = code, name, input.CostCenterID
+	if input.Active != nil {
+		department.Active = *input.Active
+	}
+	return nil
+}
+
+// checkCostCenter recusa o centro de custo inexistente ou inativo
+func (s *HRService) checkCostCenter(ctx context.Context, repo repository.HRRepository, id int) error {
+	center, err := repo.GetCostCenter(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !center.Active {
+		return errors.ErrCostCenterInactive
+	}
+	return nil
+}
+
+// ListEmployees lista os colaboradores conforme o filtro
+func (s *HRService) ListEmployees(ctx context.Context, filter models.EmployeeFilter) ([]models.Employee, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListEmployees(ctx, filter)
+}
+
+// GetEmployee busca o colaborador com o departamento e o rateio
+func (s *HRService) GetEmployee(ctx context.Context, id int) (*models.Employee, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetEmployee(ctx, id)
+}
+
+// CreateEmployee grava o colaborador depois de conferir departamento, usuário e centros de custo
+func (s *HRService) CreateEmployee(ctx context.Context, input models.EmployeeInput) (*models.Employee, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEmployee(ctx, repo, input); err != nil {
+		return nil, err
+	}
+	employee := &models.Employee{}
+	input.Apply(employee)
+	if err := repo.CreateEmployee(ctx, employee); err != nil {
+		return nil, err
+	}
+	return repo.GetEmployee(ctx, employee.ID)
+}
+
+// UpdateEmployee altera o colaborador e substitui o rateio. Meses já rateados não mudam: o novo
+// custo vale a partir do próximo rateio.
+func (s *HRService) UpdateEmployee(ctx context.Context, id int, input models.EmployeeInput) (*models.Employee, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	employee, err := repo.GetEmployee(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEmployee(ctx, repo, input); err != nil {
+		return nil, err
+	}
+	input.Apply(employee)
+	if err := repo.UpdateEmployee(ctx, employee); err != nil {
+		return nil, err
+	}
+	return repo.GetEmployee(ctx, id)
+}
+
+// checkEmployee valida o cadastro e confere se departamento, usuário e centros de custo existem
+func (s *HRService) checkEmployee(ctx context.Context, repo repository.HRRepository, input models.EmployeeInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	if input.DepartmentID != nil {
+		if _, err := repo.GetDepartment(ctx, *input.DepartmentID); err != nil {
+			return err
+		}
+	}
+	if input.UserID != nil {
+		exists, err := repo.UserExists(ctx, *input.UserID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.ErrUserNotFound
+		}
+	}
+	for _, split := range input.CostSplits {
+		if err := s.checkCostCenter(ctx, repo, split.CostCenterID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountDocuments conta os documentos em que o colaborador é o responsável
+func (s *HRService) CountDocuments(ctx context.Context, id int) ([]models.DocumentCount, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	employee, err := repo.GetEmployee(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return repo.CountDocuments(ctx, employee)
+}
+
+// AssignEmployee liga o colaborador ao documento, ou remove o vínculo com employeeID nulo. Nos
+// documentos de vendedor e técnico o vínculo é pelo usuário do colaborador, que é obrigatório.
+func (s *HRService) AssignEmployee(ctx context.Context, documentType string, id int, employeeID *int) error {
+	document, ok := models.EmployeeDocuments[documentType]
+	if !ok {
+		return errors.ErrInvalidEmployeeDocument
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	if employeeID == nil {
+		return repo.AssignEmployee(ctx, documentType, id, nil)
+	}
+
+	employee, err := repo.GetEmployee(ctx, *employeeID)
+	if err != nil {
+		return err
+	}
+	value := &employee.ID
+	if document.ByUser {
+		if employee.UserID == nil {
+			return errors.ErrEmployeeWithoutUser
+		}
+		value = employee.UserID
+	}
+	return repo.AssignEmployee(ctx, documentType, id, value)
+}
+
+// AllocateLaborCosts rateia o custo de cada colaborador no mês period (AAAA-MM) entre os centros
+// de custo, substituindo um rateio anterior do mês ainda não contabilizado. As linhas são
+// contabilizadas depois pelo lote do razão (POST /ledger/post).
+func (s *HRService) AllocateLaborCosts(ctx context.Context, period string) ([]models.LaborCostAllocation, error) {
+	month, err := models.ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	today := s.today(ctx)
+	if month.After(time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		return nil, errors.InvalidParam("o mês do rateio não pode ser futuro")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	employees, err := repo.ListEmployeesForPeriod(ctx, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := []models.LaborCostAllocation{}
+	for _, employee := range employees {
+		allocations = append(allocations, models.AllocateLaborCost(employee, employee.Department, period)...)
+	}
+	if err := repo.ReplaceAllocations(ctx, period, allocations); err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
+
+// ListLaborCosts lista o rateio de mão de obra gravado para o mês
+func (s *HRService) ListLaborCosts(ctx context.Context, period string) ([]models.LaborCostAllocation, error) {
+	if _, err := models.ParsePeriod(period); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListAllocations(ctx, period)
+}
+
+// ProfitabilityReport compara, por departamento, a receita das faturas dos vendedores do
+// departamento com o custo de mão de obra rateado nos meses from a to (AAAA-MM, inclusive)
+func (s *HRService) ProfitabilityReport(ctx context.Context, from, to string) (*models.ProfitabilityReport, error) {
+	start, err := models.ParsePeriod(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := models.ParsePeriod(to)
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, errors.InvalidParam("o mês final deve ser posterior ao inicial")
+	}
+	if end.After(start.AddDate(0, models.MaxReportMonths-1, 0)) {
+		return nil, errors.InvalidParam("o relatório de rentabilidade cobre no máximo 24 meses")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	departments, err := repo.ListDepartments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revenue, err := repo.RevenueByDepartment(ctx, start, end.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	labor, err := repo.LaborCostByDepartment(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildProfitabilityReport(departments, revenue, labor, from, to), nil
+}
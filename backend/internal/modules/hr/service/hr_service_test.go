@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	finance "ERP-ONSMART/backend/internal/modules/finance/models"
+	"ERP-ONSMART/backend/internal/modules/hr/models"
+	"ERP-ONSMART/backend/internal/modules/hr/repository"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type assignment struct {
+	documentType string
+	id           int
+	value        *int
+}
+
+type fakeRepo struct {
+	departments map[int]*models.Department
+	employees   map[int]*models.Employee
+	centers     map[int]*finance.CostCenter
+	allocations map[string][]models.LaborCostAllocation
+	posted      map[string]bool
+	assigned    []assignment
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		departments: map[int]*models.Department{},
+		employees:   map[int]*models.Employee{},
+		centers: map[int]*finance.CostCenter{
+			1: {ID: 1, Code: "VEN", Active: true},
+			2: {ID: 2, Code: "SUP", Active: true},
+			3: {ID: 3, Code: "OLD"},
+		},
+		allocations: map[string][]models.LaborCostAllocation{},
+		posted:      map[string]bool{},
+	}
+}
+
+func (r *fakeRepo) ListDepartments(ctx context.Context) ([]models.Department, error) {
+	var departments []models.Department
+	for id := 1; id <= len(r.departments); id++ {
+		departments = append(departments, *r.departments[id])
+	}
+	return departments, nil
+}
+
+func (r *fakeRepo) GetDepartment(ctx context.Context, id int) (*models.Department, error) {
+	department, ok := r.departments[id]
+	if !ok {
+		return nil, appErrors.ErrDepartmentNotFound
+	}
+	copied := *department
+	return &copied, nil
+}
+
+func (r *fakeRepo) CreateDepartment(ctx context.Context, department *models.Department) error {
+	department.ID = len(r.departments) + 1
+	copied := *department
+	r.departments[department.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) UpdateDepartment(ctx context.Context, department *models.Department) error {
+	copied := *department
+	r.departments[department.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) ListEmployees(ctx context.Context, filter models.EmployeeFilter) ([]models.Employee, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetEmployee(ctx context.Context, id int) (*models.Employee, error) {
+	employee, ok := r.employees[id]
+	if !ok {
+		return nil, appErrors.ErrEmployeeNotFound
+	}
+	copied := *employee
+	if copied.DepartmentID != nil {
+		copied.Department = r.departments[*copied.DepartmentID]
+	}
+	return &copied, nil
+}
+
+func (r *fakeRepo) CreateEmployee(ctx context.Context, employee *models.Employee) error {
+	employee.ID = len(r.employees) + 1
+	copied := *employee
+	r.employees[employee.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) UpdateEmployee(ctx context.Context, employee *models.Employee) error {
+	copied := *employee
+	r.employees[employee.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) GetCostCenter(ctx context.Context, id int) (*finance.CostCenter, error) {
+	center, ok := r.centers[id]
+	if !ok {
+		return nil, appErrors.ErrCostCenterNotFound
+	}
+	return center, nil
+}
+
+func (r *fakeRepo) UserExists(ctx context.Context, id int) (bool, error) {
+	return id == 10, nil
+}
+
+func (r *fakeRepo) AssignEmployee(ctx context.Context, documentType string, id int, value *int) error {
+	r.assigned = append(r.assigned, assignment{documentType, id, value})
+	return nil
+}
+
+func (r *fakeRepo) CountDocuments(ctx context.Context, employee *models.Employee) ([]models.DocumentCount, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) ListEmployeesForPeriod(ctx context.Context, start, end time.Time) ([]models.Employee, error) {
+	var employees []models.Employee
+	for id := 1; id <= len(r.employees); id++ {
+		employee, _ := r.GetEmployee(ctx, id)
+		employees = append(employees, *employee)
+	}
+	return employees, nil
+}
+
+func (r *fakeRepo) ReplaceAllocations(ctx context.Context, period string, allocations []models.LaborCostAllocation) error {
+	if r.posted[period] {
+		return appErrors.ErrLaborCostsPosted
+	}
+	r.allocations[period] = allocations
+	return nil
+}
+
+func (r *fakeRepo) ListAllocations(ctx context.Context, period string) ([]models.LaborCostAllocation, error) {
+	return r.allocations[period], nil
+}
+
+func (r *fakeRepo) RevenueByDepartment(ctx context.Context, from, to time.Time) ([]models.DepartmentAmount, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) LaborCostByDepartment(ctx context.Context, fromPeriod, toPeriod string) ([]models.DepartmentAmount, error) {
+	return nil, nil
+}
+
+func newTestService(repo *fakeRepo) *HRService {
+	s := NewHRService(func() (repository.HRRepository, error) { return repo, nil })
+	s.today = func(context.Context) time.Time { return time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func intPtr(v int) *int { return &v }
+
+func employeeInput(name string) models.EmployeeInput {
+	return models.EmployeeInput{
+		Name:         name,
+		DepartmentID: intPtr(1),
+		MonthlyCost:  money.FromInt(6000),
+		HireDate:     time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestCreateEmployeeChecksReferences(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	_, err := s.CreateDepartment(ctx, models.DepartmentInput{Code: "COM", Name: "Comercial", CostCenterID: intPtr(3)})
+	assert.ErrorIs(t, err, appErrors.ErrCostCenterInactive)
+	department, err := s.CreateDepartment(ctx, models.DepartmentInput{Code: "COM", Name: "Comercial", CostCenterID: intPtr(1)})
+	require.NoError(t, err)
+	assert.True(t, department.Active)
+
+	input := employeeInput("Ana")
+	input.UserID = intPtr(99)
+	_, err = s.CreateEmployee(ctx, input)
+	assert.ErrorIs(t, err, appErrors.ErrUserNotFound)
+
+	input.UserID = intPtr(10)
+	input.CostSplits = []models.CostSplit{{CostCenterID: 7, Percent: money.FromInt(100)}}
+	_, err = s.CreateEmployee(ctx, input)
+	assert.ErrorIs(t, err, appErrors.ErrCostCenterNotFound)
+
+	input.CostSplits = nil
+	input.DepartmentID = intPtr(5)
+	_, err = s.CreateEmployee(ctx, input)
+	assert.ErrorIs(t, err, appErrors.ErrDepartmentNotFound)
+
+	input.DepartmentID = intPtr(1)
+	employee, err := s.CreateEmployee(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, "COM", employee.Department.Code)
+}
+
+func TestAssignEmployee(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	_, err := s.CreateDepartment(ctx, models.DepartmentInput{Code: "COM", Name: "Comercial"})
+	require.NoError(t, err)
+	withUser := employeeInput("Ana")
+	withUser.UserID = intPtr(10)
+	_, err = s.CreateEmployee(ctx, withUser)
+	require.NoError(t, err)
+	_, err = s.CreateEmployee(ctx, employeeInput("Bruno"))
+	require.NoError(t, err)
+
+	require.NoError(t, s.AssignEmployee(ctx, "invoice", 40, intPtr(1)))
+	assert.Equal(t, 10, *repo.assigned[0].value, "vendedor é gravado pelo usuário")
+
+	err = s.AssignEmployee(ctx, "service_order", 41, intPtr(2))
+	assert.ErrorIs(t, err, appErrors.ErrEmployeeWithoutUser)
+
+	require.NoError(t, s.AssignEmployee(ctx, "delivery", 42, intPtr(2)))
+	assert.Equal(t, 2, *repo.assigned[1].value, "motorista é gravado pelo colaborador")
+
+	require.NoError(t, s.AssignEmployee(ctx, "delivery", 42, nil))
+	assert.Nil(t, repo.assigned[2].value)
+
+	assert.ErrorIs(t, s.AssignEmployee(ctx, "payment", 1, intPtr(1)), appErrors.ErrInvalidEmployeeDocument)
+	assert.ErrorIs(t, s.AssignEmployee(ctx, "invoice", 1, intPtr(9)), appErrors.ErrEmployeeNotFound)
+}
+
+func TestAllocateLaborCosts(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	_, err := s.CreateDepartment(ctx, models.DepartmentInput{Code: "COM", Name: "Comercial", CostCenterID: intPtr(1)})
+	require.NoError(t, err)
+	_, err = s.CreateEmployee(ctx, employeeInput("Ana"))
+	require.NoError(t, err)
+	split := employeeInput("Bruno")
+	split.CostSplits = []models.CostSplit{
+		{CostCenterID: 1, Percent: money.FromInt(50)},
+		{CostCenterID: 2, Percent: money.FromInt(50)},
+	}
+	_, err = s.CreateEmployee(ctx, split)
+	require.NoError(t, err)
+
+	allocations, err := s.AllocateLaborCosts(ctx, "2026-09")
+	require.NoError(t, err)
+	require.Len(t, allocations, 3)
+	assert.Equal(t, 1, *allocations[0].CostCenterID, "centro do departamento")
+	assert.Equal(t, "6000.00", allocations[0].Amount.StringFixed(2))
+	assert.Equal(t, "3000.00", allocations[2].Amount.StringFixed(2))
+	assert.Equal(t, 2, *allocations[2].CostCenterID)
+	assert.Len(t, repo.allocations["2026-09"], 3)
+
+	_, err = s.AllocateLaborCosts(ctx, "2026-11")
+	assert.Error(t, err, "mês futuro")
+
+	repo.posted["2026-09"] = true
+	_, err = s.AllocateLaborCosts(ctx, "2026-09")
+	assert.ErrorIs(t, err, appErrors.ErrLaborCostsPosted)
+}
+
+func TestProfitabilityReportPeriod(t *testing.T) {
+	s := newTestService(newFakeRepo())
+	ctx := context.Background()
+
+	report, err := s.ProfitabilityReport(ctx, "2026-01", "2026-09")
+	require.NoError(t, err)
+	assert.Empty(t, report.Departments)
+
+	_, err = s.ProfitabilityReport(ctx, "2026-09", "2026-01")
+	assert.Error(t, err)
+	_, err = s.ProfitabilityReport(ctx, "2024-01", "2026-01")
+	assert.Error(t, err, "mais de 24 meses")
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os rótulos dos status e enumerações no idioma da requisição (Accept-Language ou ?lang=)
+// @Security BearerAuth
+func ListLabelsHandler(c *gin.Context) {
+	lang := i18n.FromContext(c.Request.Context())
+	if value := c.Query("lang"); value != "" {
+		if lang = i18n.Normalize(value); lang == "" {
+			c.Error(errors.InvalidParam("idioma não suportado: " + value))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"language": lang, "languages": i18n.Languages, "labels": i18n.Labels(lang)})
+}
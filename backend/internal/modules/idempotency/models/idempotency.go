@@ -0,0 +1,79 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Situação da chave de idempotência
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+)
+
+// Tamanho máximo aceito para o header Idempotency-Key
+const MaxKeyLength = 255
+
+// IdempotencyKey guarda a resposta de uma requisição de escrita identificada pelo header Idempotency-Key.
+// A chave vale por empresa e por quem enviou a requisição (Caller): a mesma chave de outro usuário
+// ou de outra chave de API é uma requisição diferente.
+type IdempotencyKey struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	CompanyID    int       `json:"company_id" gorm:"<-:create"`
+	Caller       string    `json:"caller"`
+	Key          string    `json:"idempotency_key" gorm:"column:idempotency_key"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	RequestHash  string    `json:"request_hash"`
+	Status       string    `json:"status"`
+	StatusCode   int       `json:"status_code"`
+	ContentType  string    `json:"content_type"`
+	ResponseBody []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// NewIdempotencyKey cria a reserva da chave para a requisição do caller, válida pelo ttl informado.
+// A empresa é preenchida pelo contexto ao gravar.
+func NewIdempotencyKey(key, caller, method, path string, body []byte, now time.Time, ttl time.Duration) *IdempotencyKey {
+	return &IdempotencyKey{
+		Caller:      caller,
+		Key:         key,
+		Method:      method,
+		Path:        path,
+		RequestHash: HashRequest(method, path, body),
+		Status:      StatusProcessing,
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+// HashRequest identifica o conteúdo da requisição para impedir o reuso da chave com outro corpo
+func HashRequest(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Matches indica se a chave foi usada para a mesma requisição
+func (k *IdempotencyKey) Matches(requestHash string) bool {
+	return k.RequestHash == requestHash
+}
+
+// IsExpired indica se a chave já pode ser descartada
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+// Complete registra a resposta que será devolvida nos reenvios
+func (k *IdempotencyKey) Complete(statusCode int, contentType string, body []byte) {
+	k.Status = StatusCompleted
+	k.StatusCode = statusCode
+	k.ContentType = contentType
+	k.ResponseBody = body
+}
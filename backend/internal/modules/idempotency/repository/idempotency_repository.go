@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/idempotency/models"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository define as operações das chaves de idempotência
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, key *models.IdempotencyKey) error
+	Release(ctx context.Context, key *models.IdempotencyKey) error
+}
+
+type idempotencyRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewIdempotencyRepository cria uma nova instância do repositório
+func NewIdempotencyRepository() (IdempotencyRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &idempotencyRepository{
+		db:     db,
+		logger: logger.WithModule("idempotency_repository"),
+	}, nil
+}
+
+// Reserve grava a chave em processamento na empresa do contexto. Quando o mesmo caller já usou a
+// chave na mesma rota, retorna o registro existente em vez de reservar
+func (r *idempotencyRepository) Reserve(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	scope := r.db.WithContext(ctx).
+		Where("caller = ? AND idempotency_key = ? AND method = ? AND path = ?", key.Caller, key.Key, key.Method, key.Path).
+		Session(&gorm.Session{})
+
+	// Chaves vencidas deixam de valer e podem ser reutilizadas
+	if err := scope.Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao descartar chave de idempotência vencida")
+	}
+
+	// A restrição única resolve reenvios simultâneos: apenas uma requisição reserva a chave
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		r.logger.Error("erro ao reservar chave de idempotência", zap.Error(result.Error), zap.String("key", key.Key))
+		return nil, errors.WrapError(result.Error, "falha ao reservar chave de idempotência")
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := scope.First(&existing).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar chave de idempotência")
+	}
+	return &existing, nil
+}
+
+// Complete grava a resposta da requisição na chave reservada
+func (r *idempotencyRepository) Complete(ctx context.Context, key *models.IdempotencyKey) error {
+	err := r.db.WithContext(ctx).Model(&models.IdempotencyKey{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"status":        key.Status,
+		"status_code":   key.StatusCode,
+		"content_type":  key.ContentType,
+		"response_body": key.ResponseBody,
+	}).Error
+	if err != nil {
+		r.logger.Error("erro ao gravar resposta da chave de idempotência", zap.Error(err), zap.String("key", key.Key))
+		return errors.WrapError(err, "falha ao gravar resposta da chave de idempotência")
+	}
+	return nil
+}
+
+// Release remove a reserva para que a requisição possa ser refeita com a mesma chave
+func (r *idempotencyRepository) Release(ctx context.Context, key *models.IdempotencyKey) error {
+	if err := r.db.WithContext(ctx).Delete(&models.IdempotencyKey{}, key.ID).Error; err != nil {
+		r.logger.Error("erro ao liberar chave de idempotência", zap.Error(err), zap.String("key", key.Key))
+		return errors.WrapError(err, "falha ao liberar chave de idempotência")
+	}
+	return nil
+}
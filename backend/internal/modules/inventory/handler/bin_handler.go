@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os endereços de estoque
+// @Param zone query string false "Filtra pela zona"
+func ListBinsHandler(c *gin.Context) {
+	bins, err := service.ListBins(c.Request.Context(), c.Query("zone"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar endereços de estoque")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bins": bins})
+}
+
+// Cadastra um endereço de estoque
+// O código é gravado em maiúsculas e é o conteúdo impresso na etiqueta do endereço.
+func CreateBinHandler(c *gin.Context) {
+	var input models.BinInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	bin, err := service.CreateBin(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar endereço de estoque")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"bin": bin})
+}
+
+// Atualiza um endereço de estoque
+func UpdateBinHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input models.BinInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	bin, err := service.UpdateBin(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar endereço de estoque")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bin": bin})
+}
+
+// Remove um endereço de estoque
+// @Success 200 "Endereço removido"
+func DeleteBinHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteBin(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao excluir endereço de estoque")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Endereço de estoque removido com sucesso"})
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Retorna o custeio do produto e suas camadas de custo com saldo
+func GetProductCostingHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	costing, layers, err := service.GetProductCosting(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar custeio do produto")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"costing": costing, "layers": layers})
+}
+
+// Altera o método de custeio do produto (fifo ou average)
+func SetCostingMethodHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Method string `json:"method" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	costing, err := service.SetCostingMethod(c.Request.Context(), id, req.Method)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao alterar método de custeio")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"costing": costing})
+}
+
+// Registra o recebimento do pedido de compra, gerando as camadas de custo
+// O corpo opcional informa, por item, o lote, a validade (AAAA-MM-DD) e os números de série recebidos.
+func ReceivePurchaseOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Items []models.ReceiptLine `json:"items" binding:"dive"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	layers, err := service.ReceivePurchaseOrder(c.Request.Context(), id, req.Items)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao receber pedido de compra")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"layers": layers})
+}
+
+// Apura o CMV dos itens de uma entrega
+func RecordDeliveryCOGSHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entries, err := service.RecordDeliveryCOGS(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao apurar CMV da entrega")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+// Apura o CMV dos itens de uma fatura
+func RecordInvoiceCOGSHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entries, err := service.RecordInvoiceCOGS(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao apurar CMV da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+// Retorna o CMV apurado para um pedido de venda
+func GetSalesOrderCOGSHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	summary, err := service.GetSalesOrderCOGS(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar CMV do pedido")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cogs": summary})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
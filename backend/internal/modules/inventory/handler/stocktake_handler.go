@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Abre o inventário físico do depósito congelando o saldo dos produtos com estoque
+// product_ids inclui produtos sem saldo no depósito, que entram com saldo congelado zero. Só um
+// inventário em contagem por depósito.
+func CreateStocktakeHandler(c *gin.Context) {
+	var input models.StocktakeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	stocktake, err := service.CreateStocktake(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao abrir inventário")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"stocktake": stocktake})
+}
+
+// Lista os inventários físicos, os mais recentes primeiro
+// @Param status query string false "counting, approved ou cancelled"
+// @Param warehouse_id query int false "Inventários do depósito"
+// @Param page query int false "página"
+// @Param page_size query int false "itens por página"
+func ListStocktakesHandler(c *gin.Context) {
+	filter := models.StocktakeFilter{Status: c.Query("status")}
+	if value := c.Query("warehouse_id"); value != "" {
+		warehouseID, err := strconv.Atoi(value)
+		if err != nil || warehouseID <= 0 {
+			c.Error(errors.InvalidParam("warehouse_id inválido"))
+			return
+		}
+		filter.WarehouseID = warehouseID
+	}
+
+	params := pagination.NewPaginationParams(c.Request)
+	result, err := service.ListStocktakes(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar inventários")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Busca um inventário com os saldos congelados na abertura
+// @Param id path int true "ID do inventário"
+func GetStocktakeHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	stocktake, err := service.GetStocktake(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar inventário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stocktake": stocktake})
+}
+
+// Gera a folha de contagem por endereço de estoque, sem os saldos congelados (contagem às cegas)
+// @Param id path int true "ID do inventário"
+func GetCountSheetHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	sheet, err := service.GetCountSheet(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar folha de contagem")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sheet": sheet})
+}
+
+// Registra a quantidade contada de um produto por um contador
+// As contagens do mesmo produto são somadas (cada contador conta uma parte do depósito).
+// @Param id path int true "ID do inventário"
+func AddStocktakeCountHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input models.CountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	count, err := service.AddStocktakeCount(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao registrar contagem")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"count": count})
+}
+
+// Registra a contagem pela leitura do código de barras
+// Aceita código de barras, SKU ou PRD-<id> do produto e SKU ou código de barras da variante; sem
+// quantity, cada leitura conta uma unidade.
+// @Param id path int true "ID do inventário"
+func ScanStocktakeCountHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input models.ScanInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	count, err := service.ScanStocktakeCount(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao registrar leitura")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"count": count})
+}
+
+// Remove uma contagem registrada por engano
+// @Param id path int true "ID do inventário"
+// @Param count_id path int true "ID da contagem"
+func DeleteStocktakeCountHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	countID, ok := parseIDParam(c, "count_id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteStocktakeCount(c.Request.Context(), id, countID); err != nil {
+		c.Error(err).SetMeta("erro ao remover contagem")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Relatório de diferenças do inventário por produto e por contador
+// Produto sem contagem conta como zero; o valor da diferença usa o custo unitário da abertura.
+// @Param id path int true "ID do inventário"
+func GetStocktakeVariancesHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := service.GetStocktakeVariances(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao calcular diferenças do inventário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// Aprova o inventário: lança a diferença de cada produto no saldo do depósito
+// O aprovador é o usuário autenticado (administrador).
+// @Param id path int true "ID do inventário"
+// @Security BearerAuth
+func ApproveStocktakeHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	approval, err := service.ApproveStocktake(c.Request.Context(), id, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao aprovar inventário")
+		return
+	}
+
+	c.JSON(http.StatusOK, approval)
+}
+
+// Cancela o inventário em contagem sem alterar os saldos
+// @Param id path int true "ID do inventário"
+func CancelStocktakeHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	stocktake, err := service.CancelStocktake(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cancelar inventário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stocktake": stocktake})
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultExpiringDays é a janela padrão da consulta de lotes a vencer
+const defaultExpiringDays = 30
+
+// Registra o lote e os números de série dos itens de uma entrega de pedido de venda
+// Números recebidos por pedido de compra passam a entregues; números desconhecidos são cadastrados na saída.
+func CaptureDeliverySerialsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Items []models.ShipmentLine `json:"items" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	serials, err := service.CaptureDeliverySerials(c.Request.Context(), id, req.Items)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao registrar números de série da entrega")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"serials": serials})
+}
+
+// Retorna a rastreabilidade de um número de série
+// Traz o produto, o pedido de compra que o recebeu, a entrega que o levou, as faturas do pedido de venda e o cliente.
+// @Security BearerAuth
+func GetSerialTraceHandler(c *gin.Context) {
+	traces, err := service.GetSerialTrace(c.Request.Context(), c.Param("sn"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao rastrear número de série")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"serials": traces})
+}
+
+// Retorna as entradas e as saídas de um lote
+func GetLotTraceHandler(c *gin.Context) {
+	lot, err := service.GetLotTrace(c.Request.Context(), c.Param("lot"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao rastrear lote")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lot": lot})
+}
+
+// Lista os lotes com saldo em estoque que vencem nos próximos dias, incluindo os vencidos
+// @Param days query int false "Janela em dias (padrão 30)"
+func ListExpiringLotsHandler(c *gin.Context) {
+	days := defaultExpiringDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.Error(errors.InvalidParam("quantidade de dias inválida"))
+			return
+		}
+		days = parsed
+	}
+
+	lots, err := service.GetExpiringLots(c.Request.Context(), days)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar lotes a vencer")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lots": lots})
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os depósitos da empresa
+func ListWarehousesHandler(c *gin.Context) {
+	warehouses, err := service.ListWarehouses(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar depósitos")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"warehouses": warehouses})
+}
+
+// Cadastra um depósito (matriz, filial ou centro de distribuição)
+// contact_id é a filial como contato, destinatária das notas internas de transferência.
+func CreateWarehouseHandler(c *gin.Context) {
+	var input models.WarehouseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	warehouse, err := service.CreateWarehouse(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar depósito")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"warehouse": warehouse})
+}
+
+// Atualiza o cadastro de um depósito
+// @Param id path int true "ID do depósito"
+func UpdateWarehouseHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input models.WarehouseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	warehouse, err := service.UpdateWarehouse(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar depósito")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"warehouse": warehouse})
+}
+
+// Lista os saldos dos produtos no depósito
+// @Param id path int true "ID do depósito"
+func ListWarehouseStockHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	stock, err := service.ListWarehouseStock(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar saldos do depósito")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stock": stock})
+}
+
+// Registra a contagem de estoque do depósito
+// O saldo de cada produto contado passa a ser a quantidade informada, com um ajuste pela diferença.
+// @Param id path int true "ID do depósito"
+func CountWarehouseStockHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var count models.StockCount
+	if err := c.ShouldBindJSON(&count); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	movements, err := service.CountWarehouseStock(c.Request.Context(), id, count)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao registrar contagem do depósito")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"movements": movements})
+}
+
+// Lista o histórico de movimentações de estoque do depósito, as mais recentes primeiro
+// @Param id path int true "ID do depósito"
+// @Param product_id query int false "Filtra pelo produto"
+// @Param movement_type query string false "adjustment, transfer_out, transfer_in ou stocktake"
+// @Param page query int false "página"
+// @Param page_size query int false "itens por página"
+func ListStockMovementsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	filter := models.MovementFilter{MovementType: c.Query("movement_type")}
+	if value := c.Query("product_id"); value != "" {
+		productID, err := strconv.Atoi(value)
+		if err != nil || productID <= 0 {
+			c.Error(errors.InvalidParam("product_id inválido"))
+			return
+		}
+		filter.ProductID = productID
+	}
+
+	params := pagination.NewPaginationParams(c.Request)
+	result, err := service.ListStockMovements(c.Request.Context(), id, filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar movimentações do depósito")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Solicita uma transferência de produtos entre depósitos
+// Com internal_invoice, o envio gera a nota interna para o contato da filial de destino.
+func RequestTransferHandler(c *gin.Context) {
+	var input models.TransferInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	transfer, err := service.RequestTransfer(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao solicitar transferência")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"transfer": transfer})
+}
+
+// Lista as transferências entre depósitos, as mais recentes primeiro
+// @Param status query string false "requested, shipped, received ou cancelled"
+// @Param warehouse_id query int false "Transferências que saem do depósito ou chegam nele"
+// @Param page query int false "página"
+// @Param page_size query int false "itens por página"
+func ListTransfersHandler(c *gin.Context) {
+	filter := models.TransferFilter{Status: c.Query("status")}
+	if value := c.Query("warehouse_id"); value != "" {
+		warehouseID, err := strconv.Atoi(value)
+		if err != nil || warehouseID <= 0 {
+			c.Error(errors.InvalidParam("warehouse_id inválido"))
+			return
+		}
+		filter.WarehouseID = warehouseID
+	}
+
+	params := pagination.NewPaginationParams(c.Request)
+	result, err := service.ListTransfers(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar transferências")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Busca uma transferência com os itens e os depósitos
+// @Param id path int true "ID da transferência"
+func GetTransferHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := service.GetTransfer(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar transferência")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfer": transfer})
+}
+
+// Envia a transferência: baixa o saldo da origem e gera as entregas de saída e de entrada
+// O corpo é opcional (shipping_method, carrier e tracking_number). Com internal_invoice, gera também
+// a nota interna em rascunho.
+// @Param id path int true "ID da transferência"
+func ShipTransferHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input models.ShipTransferInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	transfer, err := service.ShipTransfer(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao enviar transferência")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfer": transfer})
+}
+
+// Recebe a transferência: dá entrada no saldo do destino e conclui as duas entregas
+// @Param id path int true "ID da transferência"
+func ReceiveTransferHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := service.ReceiveTransfer(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao receber transferência")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfer": transfer})
+}
+
+// Cancela a transferência ainda não enviada
+// @Param id path int true "ID da transferência"
+func CancelTransferHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := service.CancelTransfer(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cancelar transferência")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfer": transfer})
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// WarehouseBin é um endereço de estoque (ex.: A-01-03 = corredor A, prateleira 01, posição 03),
+// identificado pela etiqueta afixada no local
+type WarehouseBin struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Zone      string    `json:"zone"`
+	Active    bool      `json:"active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de endereços de estoque
+func (WarehouseBin) TableName() string {
+	return "warehouse_bins"
+}
+
+// BinInput são os dados de cadastro de um endereço de estoque
+type BinInput struct {
+	Code   string `json:"code" binding:"required,max=40"`
+	Name   string `json:"name" binding:"max=100"`
+	Zone   string `json:"zone" binding:"max=50"`
+	Active *bool  `json:"active"`
+}
+
+// NormalizeBinCode padroniza o código do endereço (sem espaços, em maiúsculas), que é o
+// conteúdo impresso na etiqueta
+func NormalizeBinCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// Métodos de custeio suportados
+const (
+	CostingMethodFIFO    = "fifo"
+	CostingMethodAverage = "average"
+)
+
+// Documentos que originam o custo das mercadorias vendidas
+const (
+	COGSSourceDelivery = "delivery"
+	COGSSourceInvoice  = "invoice"
+)
+
+// ProductCosting guarda o método de custeio, o custo médio e a quantidade em estoque de um produto
+type ProductCosting struct {
+	ProductID      int           `json:"product_id" gorm:"primaryKey"`
+	Method         string        `json:"method" validate:"required,oneof=fifo average"`
+	AverageCost    money.Decimal `json:"average_cost"`
+	QuantityOnHand int           `json:"quantity_on_hand"`
+	UpdatedAt      time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de custeio
+func (ProductCosting) TableName() string {
+	return "product_costing"
+}
+
+// CostLayer representa uma camada de custo gerada pelo recebimento de um item de pedido de compra
+// ou pela reentrada em estoque de um item devolvido
+type CostLayer struct {
+	ID                int           `json:"id" gorm:"primaryKey"`
+	ProductID         int           `json:"product_id" gorm:"index"`
+	PurchaseOrderID   *int          `json:"purchase_order_id,omitempty"`
+	POItemID          *int          `json:"po_item_id,omitempty" gorm:"column:po_item_id"`
+	ReturnItemID      *int          `json:"return_item_id,omitempty"`
+	ReceivedAt        time.Time     `json:"received_at"`
+	Quantity          int           `json:"quantity"`
+	RemainingQuantity int           `json:"remaining_quantity"`
+	UnitCost          money.Decimal `json:"unit_cost"`
+	LotNumber         string        `json:"lot_number,omitempty"`
+	ExpiryDate        *time.Time    `json:"expiry_date,omitempty" gorm:"type:date"`
+	CreatedAt         time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// COGSEntry representa o custo apurado para um item entregue ou faturado
+type COGSEntry struct {
+	ID           int           `json:"id" gorm:"primaryKey"`
+	ProductID    int           `json:"product_id"`
+	SalesOrderID *int          `json:"sales_order_id,omitempty"`
+	SourceType   string        `json:"source_type"`
+	SourceID     int           `json:"source_id"`
+	SourceItemID int           `json:"source_item_id"`
+	Quantity     int           `json:"quantity"`
+	UnitCost     money.Decimal `json:"unit_cost"`
+	TotalCost    money.Decimal `json:"total_cost"`
+	Method       string        `json:"method"`
+	KitProductID *int          `json:"kit_product_id,omitempty"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName define o nome da tabela de CMV
+func (COGSEntry) TableName() string {
+	return "cogs_entries"
+}
+
+// LayerConsumption indica quanto foi baixado de cada camada de custo
+type LayerConsumption struct {
+	LayerID  int           `json:"layer_id"`
+	Quantity int           `json:"quantity"`
+	UnitCost money.Decimal `json:"unit_cost"`
+}
+
+// COGSSource identifica o documento de venda que originou o CMV
+type COGSSource struct {
+	Type         string
+	ID           int
+	SalesOrderID int
+}
+
+// SalesOrderCOGS resume o custo das mercadorias vendidas de um pedido de venda
+type SalesOrderCOGS struct {
+	SalesOrderID int           `json:"sales_order_id"`
+	Entries      []COGSEntry   `json:"entries"`
+	TotalCost    money.Decimal `json:"total_cost"`
+}
+
+// CostQuery pede a estimativa do custo de venda de quantity unidades de estoque do produto
+type CostQuery struct {
+	ProductID int
+	Quantity  int
+}
+
+// CostEstimate é o custo que a venda teria agora pelo método de custeio do produto, sem baixar o
+// estoque. Kits mantidos somam o custo dos componentes e ficam sem método.
+type CostEstimate struct {
+	ProductID int           `json:"product_id"`
+	Quantity  int           `json:"quantity"`
+	Method    string        `json:"method,omitempty"`
+	TotalCost money.Decimal `json:"total_cost"`
+}
+
+// ApplyReceipt atualiza o custo médio ponderado e a quantidade em estoque após um recebimento
+func (c *ProductCosting) ApplyReceipt(quantity int, unitCost money.Decimal) {
+	if quantity <= 0 {
+		return
+	}
+
+	onHand := c.QuantityOnHand
+	if onHand < 0 {
+		onHand = 0
+	}
+
+	total := c.AverageCost.MulInt(onHand).Add(unitCost.MulInt(quantity))
+	c.QuantityOnHand = onHand + quantity
+	c.AverageCost = total.DivInt(c.QuantityOnHand)
+}
+
+// Consume baixa a quantidade das camadas (sempre na ordem de entrada) e devolve o custo total
+// conforme o método do produto, arredondado em centavos. No FIFO, a falta de camadas é custeada
+// pelo custo médio. As camadas recebidas são alteradas no lugar.
+func (c *ProductCosting) Consume(layers []CostLayer, quantity int) ([]LayerConsumption, money.Decimal) {
+	var (
+		consumptions []LayerConsumption
+		layerCost    money.Decimal
+		remaining    = quantity
+	)
+
+	for i := range layers {
+		if remaining == 0 {
+			break
+		}
+		if layers[i].RemainingQuantity == 0 {
+			continue
+		}
+
+		take := layers[i].RemainingQuantity
+		if take > remaining {
+			take = remaining
+		}
+
+		layers[i].RemainingQuantity -= take
+		remaining -= take
+		layerCost = layerCost.Add(layers[i].UnitCost.MulInt(take))
+		consumptions = append(consumptions, LayerConsumption{
+			LayerID:  layers[i].ID,
+			Quantity: take,
+			UnitCost: layers[i].UnitCost,
+		})
+	}
+
+	var total money.Decimal
+	if c.Method == CostingMethodFIFO {
+		total = layerCost.Add(c.AverageCost.MulInt(remaining))
+	} else {
+		total = c.AverageCost.MulInt(quantity)
+	}
+
+	c.QuantityOnHand -= quantity
+	if c.QuantityOnHand < 0 {
+		c.QuantityOnHand = 0
+	}
+
+	return consumptions, money.Round(total)
+}
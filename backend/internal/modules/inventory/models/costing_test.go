@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+
+	"ERP-ONSMART/backend/internal/money"
+)
+
+func TestApplyReceiptAverageCost(t *testing.T) {
+	costing := ProductCosting{Method: CostingMethodAverage}
+
+	costing.ApplyReceipt(10, money.FromInt(5))
+	costing.ApplyReceipt(10, money.FromInt(7))
+
+	if costing.QuantityOnHand != 20 {
+		t.Errorf("Esperado estoque 20, obtido %d", costing.QuantityOnHand)
+	}
+	if !costing.AverageCost.Equal(money.FromInt(6)) {
+		t.Errorf("Esperado custo médio 6, obtido %s", costing.AverageCost)
+	}
+}
+
+func TestConsumeFIFO(t *testing.T) {
+	costing := ProductCosting{Method: CostingMethodFIFO, AverageCost: money.FromInt(6), QuantityOnHand: 20}
+	layers := []CostLayer{
+		{ID: 1, RemainingQuantity: 10, UnitCost: money.FromInt(5)},
+		{ID: 2, RemainingQuantity: 10, UnitCost: money.FromInt(7)},
+	}
+
+	consumptions, total := costing.Consume(layers, 15)
+
+	if !total.Equal(money.FromInt(85)) {
+		t.Errorf("Esperado custo 85 (10x5 + 5x7), obtido %s", total)
+	}
+	if len(consumptions) != 2 || layers[0].RemainingQuantity != 0 || layers[1].RemainingQuantity != 5 {
+		t.Errorf("Baixa das camadas incorreta: %+v / %+v", consumptions, layers)
+	}
+	if costing.QuantityOnHand != 5 {
+		t.Errorf("Esperado estoque 5, obtido %d", costing.QuantityOnHand)
+	}
+}
+
+func TestConsumeAverage(t *testing.T) {
+	costing := ProductCosting{Method: CostingMethodAverage, AverageCost: money.FromInt(6), QuantityOnHand: 20}
+	layers := []CostLayer{
+		{ID: 1, RemainingQuantity: 10, UnitCost: money.FromInt(5)},
+		{ID: 2, RemainingQuantity: 10, UnitCost: money.FromInt(7)},
+	}
+
+	_, total := costing.Consume(layers, 15)
+
+	if !total.Equal(money.FromInt(90)) {
+		t.Errorf("Esperado custo 90 (15x6), obtido %s", total)
+	}
+}
+
+func TestConsumeFIFOShortage(t *testing.T) {
+	costing := ProductCosting{Method: CostingMethodFIFO, AverageCost: money.FromInt(4), QuantityOnHand: 2}
+	layers := []CostLayer{{ID: 1, RemainingQuantity: 2, UnitCost: money.FromInt(3)}}
+
+	_, total := costing.Consume(layers, 5)
+
+	if !total.Equal(money.FromInt(18)) {
+		t.Errorf("Esperado custo 18 (2x3 + 3x4), obtido %s", total)
+	}
+	if costing.QuantityOnHand != 0 {
+		t.Errorf("Estoque não deveria ficar negativo, obtido %d", costing.QuantityOnHand)
+	}
+}
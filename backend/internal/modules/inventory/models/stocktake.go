@@ -0,0 +1,319 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Situações do inventário físico
+const (
+	StocktakeStatusCounting  = "counting"
+	StocktakeStatusApproved  = "approved"
+	StocktakeStatusCancelled = "cancelled"
+)
+
+// Origem da contagem
+const (
+	CountSourceManual = "manual"
+	CountSourceScan   = "scan"
+)
+
+// Stocktake é o inventário físico de um depósito. A abertura congela o saldo de cada produto; a
+// aprovação lança no depósito a diferença entre o contado e o congelado, preservando as
+// movimentações feitas durante a contagem.
+type Stocktake struct {
+	ID          int             `json:"id" gorm:"primaryKey"`
+	CompanyID   int             `json:"company_id" gorm:"<-:create"`
+	StocktakeNo string          `json:"stocktake_no"`
+	WarehouseID int             `json:"warehouse_id"`
+	Status      string          `json:"status" gorm:"default:counting"`
+	Notes       string          `json:"notes"`
+	CreatedBy   string          `json:"created_by"`
+	FrozenAt    time.Time       `json:"frozen_at"`
+	ApprovedBy  string          `json:"approved_by"`
+	ApprovedAt  *time.Time      `json:"approved_at,omitempty"`
+	CancelledAt *time.Time      `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	Warehouse   *Warehouse      `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+	Lines       []StocktakeLine `json:"lines,omitempty" gorm:"foreignKey:StocktakeID"`
+}
+
+// TableName define o nome da tabela de inventários
+func (Stocktake) TableName() string {
+	return "stocktakes"
+}
+
+// StocktakeLine é um produto do inventário com o saldo congelado na abertura e o custo unitário
+// usado para valorizar a diferença
+type StocktakeLine struct {
+	ID          int           `json:"id" gorm:"primaryKey"`
+	StocktakeID int           `json:"stocktake_id"`
+	ProductID   int           `json:"product_id"`
+	ProductName string        `json:"product_name"`
+	ProductCode string        `json:"product_code"`
+	Barcode     string        `json:"barcode"`
+	BinCode     string        `json:"bin_code"`
+	ExpectedQty int           `json:"expected_qty"`
+	UnitCost    money.Decimal `json:"unit_cost"`
+}
+
+// TableName define o nome da tabela de produtos dos inventários
+func (StocktakeLine) TableName() string {
+	return "stocktake_lines"
+}
+
+// StocktakeCount é uma contagem registrada por um contador; o contado do produto é a soma das
+// contagens (cada contador conta uma parte do depósito)
+type StocktakeCount struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	StocktakeID int       `json:"stocktake_id"`
+	LineID      int       `json:"line_id"`
+	Counter     string    `json:"counter"`
+	Quantity    int       `json:"quantity"`
+	Source      string    `json:"source" gorm:"default:manual"`
+	Code        string    `json:"code"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName define o nome da tabela de contagens dos inventários
+func (StocktakeCount) TableName() string {
+	return "stocktake_counts"
+}
+
+// StocktakeInput abre o inventário do depósito. product_ids inclui produtos sem saldo no depósito,
+// que entram com saldo congelado zero.
+type StocktakeInput struct {
+	WarehouseID int    `json:"warehouse_id" binding:"required"`
+	Notes       string `json:"notes"`
+	CreatedBy   string `json:"created_by" binding:"max=100"`
+	ProductIDs  []int  `json:"product_ids"`
+}
+
+// CountInput registra a quantidade contada de um produto
+type CountInput struct {
+	ProductID int    `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,gt=0"`
+	Counter   string `json:"counter" binding:"required,max=100"`
+}
+
+// ScanInput registra a leitura do código de barras (código de barras, SKU ou PRD-<id> do produto,
+// ou SKU e código de barras da variante); sem quantidade, cada leitura conta uma unidade
+type ScanInput struct {
+	Code     string `json:"code" binding:"required"`
+	Quantity int    `json:"quantity" binding:"gte=0"`
+	Counter  string `json:"counter" binding:"required,max=100"`
+}
+
+// Count converte a leitura na contagem do produto encontrado
+func (in ScanInput) Count(productID int) CountInput {
+	quantity := in.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	return CountInput{ProductID: productID, Quantity: quantity, Counter: in.Counter}
+}
+
+// Validate confere a contagem
+func (in CountInput) Validate() error {
+	if in.ProductID <= 0 || in.Quantity <= 0 || strings.TrimSpace(in.Counter) == "" {
+		return errors.ErrInvalidStocktakeCount
+	}
+	return nil
+}
+
+// StocktakeApproval é o inventário aprovado com as movimentações de ajuste lançadas no depósito
+type StocktakeApproval struct {
+	Stocktake Stocktake       `json:"stocktake"`
+	Movements []StockMovement `json:"movements"`
+}
+
+// StocktakeFilter filtra a lista de inventários
+type StocktakeFilter struct {
+	Status      string
+	WarehouseID int
+}
+
+// CheckCounting confere se o inventário ainda aceita contagens, aprovação ou cancelamento
+func (s Stocktake) CheckCounting() error {
+	if s.Status != StocktakeStatusCounting {
+		return errors.ErrStocktakeNotCounting
+	}
+	return nil
+}
+
+// CountSheet é a folha de contagem do inventário: os produtos por endereço de estoque, sem o saldo
+// congelado, para a contagem às cegas
+type CountSheet struct {
+	StocktakeID int         `json:"stocktake_id"`
+	StocktakeNo string      `json:"stocktake_no"`
+	Warehouse   string      `json:"warehouse"`
+	FrozenAt    time.Time   `json:"frozen_at"`
+	Lines       []SheetLine `json:"lines"`
+}
+
+// SheetLine é um produto da folha de contagem
+type SheetLine struct {
+	LineID      int    `json:"line_id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	ProductCode string `json:"product_code"`
+	Barcode     string `json:"barcode"`
+	BinCode     string `json:"bin_code"`
+}
+
+// BuildCountSheet monta a folha de contagem na ordem de percurso: por endereço de estoque, os
+// produtos sem endereço no fim, e por nome dentro do endereço
+func BuildCountSheet(stocktake Stocktake, lines []StocktakeLine) CountSheet {
+	sheet := CountSheet{
+		StocktakeID: stocktake.ID,
+		StocktakeNo: stocktake.StocktakeNo,
+		FrozenAt:    stocktake.FrozenAt,
+		Lines:       make([]SheetLine, 0, len(lines)),
+	}
+	if stocktake.Warehouse != nil {
+		sheet.Warehouse = stocktake.Warehouse.Code
+	}
+	for _, line := range lines {
+		sheet.Lines = append(sheet.Lines, SheetLine{
+			LineID:      line.ID,
+			ProductID:   line.ProductID,
+			ProductName: line.ProductName,
+			ProductCode: line.ProductCode,
+			Barcode:     line.Barcode,
+			BinCode:     line.BinCode,
+		})
+	}
+	sort.SliceStable(sheet.Lines, func(i, j int) bool {
+		a, b := sheet.Lines[i], sheet.Lines[j]
+		if (a.BinCode == "") != (b.BinCode == "") {
+			return b.BinCode == ""
+		}
+		if a.BinCode != b.BinCode {
+			return a.BinCode < b.BinCode
+		}
+		if a.ProductName != b.ProductName {
+			return a.ProductName < b.ProductName
+		}
+		return a.ProductID < b.ProductID
+	})
+	return sheet
+}
+
+// VarianceReport é o relatório de diferenças do inventário por produto e por contador
+type VarianceReport struct {
+	StocktakeID   int               `json:"stocktake_id"`
+	StocktakeNo   string            `json:"stocktake_no"`
+	Status        string            `json:"status"`
+	Products      []ProductVariance `json:"products"`
+	Counters      []CounterSummary  `json:"counters"`
+	CountedLines  int               `json:"counted_lines"`
+	TotalLines    int               `json:"total_lines"`
+	VarianceValue money.Decimal     `json:"variance_value"`
+}
+
+// ProductVariance é a diferença de um produto: contado menos congelado, em quantidade e em valor
+// pelo custo unitário. Produto sem contagem conta como zero.
+type ProductVariance struct {
+	LineID        int           `json:"line_id"`
+	ProductID     int           `json:"product_id"`
+	ProductName   string        `json:"product_name"`
+	ProductCode   string        `json:"product_code"`
+	BinCode       string        `json:"bin_code"`
+	ExpectedQty   int           `json:"expected_qty"`
+	CountedQty    int           `json:"counted_qty"`
+	Counted       bool          `json:"counted"`
+	Variance      int           `json:"variance"`
+	VarianceValue money.Decimal `json:"variance_value"`
+	Counters      []string      `json:"counters"`
+}
+
+// CounterSummary resume as contagens de um contador e as diferenças dos produtos que ele contou
+type CounterSummary struct {
+	Counter          string `json:"counter"`
+	Counts           int    `json:"counts"`
+	Products         int    `json:"products"`
+	Quantity         int    `json:"quantity"`
+	ProductsVariance int    `json:"products_with_variance"`
+}
+
+// BuildVarianceReport calcula as diferenças por produto, na ordem das linhas, e o resumo por
+// contador, em ordem alfabética
+func BuildVarianceReport(stocktake Stocktake, lines []StocktakeLine, counts []StocktakeCount) VarianceReport {
+	counted := make(map[int]int)
+	lineCounters := make(map[int][]string)
+	type counterAcc struct {
+		counts, quantity int
+		lines            map[int]bool
+	}
+	counters := make(map[string]*counterAcc)
+	for _, count := range counts {
+		counted[count.LineID] += count.Quantity
+		acc := counters[count.Counter]
+		if acc == nil {
+			acc = &counterAcc{lines: make(map[int]bool)}
+			counters[count.Counter] = acc
+		}
+		if !acc.lines[count.LineID] {
+			lineCounters[count.LineID] = append(lineCounters[count.LineID], count.Counter)
+		}
+		acc.counts++
+		acc.quantity += count.Quantity
+		acc.lines[count.LineID] = true
+	}
+
+	report := VarianceReport{
+		StocktakeID: stocktake.ID,
+		StocktakeNo: stocktake.StocktakeNo,
+		Status:      stocktake.Status,
+		Products:    make([]ProductVariance, 0, len(lines)),
+		Counters:    make([]CounterSummary, 0, len(counters)),
+		TotalLines:  len(lines),
+	}
+	withVariance := make(map[int]bool)
+	for _, line := range lines {
+		qty, ok := counted[line.ID]
+		variance := qty - line.ExpectedQty
+		value := money.Round(line.UnitCost.MulInt(variance))
+		names := lineCounters[line.ID]
+		if names == nil {
+			names = []string{}
+		}
+		sort.Strings(names)
+		report.Products = append(report.Products, ProductVariance{
+			LineID:        line.ID,
+			ProductID:     line.ProductID,
+			ProductName:   line.ProductName,
+			ProductCode:   line.ProductCode,
+			BinCode:       line.BinCode,
+			ExpectedQty:   line.ExpectedQty,
+			CountedQty:    qty,
+			Counted:       ok,
+			Variance:      variance,
+			VarianceValue: value,
+			Counters:      names,
+		})
+		if ok {
+			report.CountedLines++
+		}
+		if variance != 0 {
+			withVariance[line.ID] = true
+		}
+		report.VarianceValue = report.VarianceValue.Add(value)
+	}
+
+	for name, acc := range counters {
+		summary := CounterSummary{Counter: name, Counts: acc.counts, Products: len(acc.lines), Quantity: acc.quantity}
+		for lineID := range acc.lines {
+			if withVariance[lineID] {
+				summary.ProductsVariance++
+			}
+		}
+		report.Counters = append(report.Counters, summary)
+	}
+	sort.Slice(report.Counters, func(i, j int) bool { return report.Counters[i].Counter < report.Counters[j].Counter })
+	return report
+}
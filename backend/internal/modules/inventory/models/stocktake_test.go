@@ -0,0 +1,86 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"reflect"
+	"testing"
+)
+
+func sampleStocktake() (Stocktake, []StocktakeLine) {
+	stocktake := Stocktake{ID: 3, StocktakeNo: "INVF-2026-000003", Status: StocktakeStatusCounting, Warehouse: &Warehouse{Code: "MATRIZ"}}
+	lines := []StocktakeLine{
+		{ID: 1, ProductID: 7, ProductName: "Cabo HDMI", BinCode: "B-01", ExpectedQty: 10, UnitCost: money.MustParse("12.5")},
+		{ID: 2, ProductID: 8, ProductName: "Mouse", ExpectedQty: 4, UnitCost: money.FromInt(30)},
+		{ID: 3, ProductID: 9, ProductName: "Adaptador", BinCode: "A-02", ExpectedQty: 2, UnitCost: money.FromInt(5)},
+		{ID: 4, ProductID: 10, ProductName: "Teclado", BinCode: "A-02", ExpectedQty: 0, UnitCost: money.FromInt(80)},
+	}
+	return stocktake, lines
+}
+
+func TestBuildCountSheet(t *testing.T) {
+	stocktake, lines := sampleStocktake()
+	sheet := BuildCountSheet(stocktake, lines)
+
+	order := make([]int, 0, len(sheet.Lines))
+	for _, line := range sheet.Lines {
+		order = append(order, line.ProductID)
+	}
+	if !reflect.DeepEqual(order, []int{9, 10, 7, 8}) {
+		t.Errorf("Ordem por endereço e nome, sem endereço no fim: %v", order)
+	}
+	if sheet.Warehouse != "MATRIZ" || sheet.StocktakeNo != "INVF-2026-000003" {
+		t.Errorf("Cabeçalho inesperado: %+v", sheet)
+	}
+}
+
+func TestBuildVarianceReport(t *testing.T) {
+	stocktake, lines := sampleStocktake()
+	counts := []StocktakeCount{
+		{LineID: 1, Counter: "joao", Quantity: 6},
+		{LineID: 1, Counter: "ana", Quantity: 3},
+		{LineID: 3, Counter: "ana", Quantity: 2},
+		{LineID: 4, Counter: "joao", Quantity: 1},
+		{LineID: 1, Counter: "joao", Quantity: 1},
+	}
+	report := BuildVarianceReport(stocktake, lines, counts)
+
+	cable := report.Products[0]
+	if cable.CountedQty != 10 || cable.Variance != 0 || !reflect.DeepEqual(cable.Counters, []string{"ana", "joao"}) {
+		t.Errorf("Contagens de dois contadores deveriam ser somadas: %+v", cable)
+	}
+	mouse := report.Products[1]
+	if mouse.Counted || mouse.CountedQty != 0 || mouse.Variance != -4 || !mouse.VarianceValue.Equal(money.FromInt(-120)) {
+		t.Errorf("Produto sem contagem conta como zero: %+v", mouse)
+	}
+	keyboard := report.Products[3]
+	if keyboard.Variance != 1 || !keyboard.VarianceValue.Equal(money.FromInt(80)) {
+		t.Errorf("Sobra de produto sem saldo congelado: %+v", keyboard)
+	}
+	if report.CountedLines != 3 || report.TotalLines != 4 || !report.VarianceValue.Equal(money.FromInt(-40)) {
+		t.Errorf("Totais inesperados: %+v", report)
+	}
+
+	want := []CounterSummary{
+		{Counter: "ana", Counts: 2, Products: 2, Quantity: 5, ProductsVariance: 0},
+		{Counter: "joao", Counts: 3, Products: 2, Quantity: 8, ProductsVariance: 1},
+	}
+	if !reflect.DeepEqual(report.Counters, want) {
+		t.Errorf("Resumo por contador inesperado: %+v", report.Counters)
+	}
+}
+
+func TestScanInputCount(t *testing.T) {
+	if count := (ScanInput{Code: "789", Counter: "ana"}).Count(7); count.Quantity != 1 || count.ProductID != 7 {
+		t.Errorf("Leitura sem quantidade conta uma unidade: %+v", count)
+	}
+	if count := (ScanInput{Code: "789", Quantity: 12, Counter: "ana"}).Count(7); count.Quantity != 12 {
+		t.Errorf("Leitura com quantidade (caixa fechada): %+v", count)
+	}
+	if err := (CountInput{ProductID: 7, Quantity: 1, Counter: "  "}).Validate(); err != errors.ErrInvalidStocktakeCount {
+		t.Errorf("Contagem sem contador: esperado ErrInvalidStocktakeCount, obtido %v", err)
+	}
+	if err := (Stocktake{Status: StocktakeStatusApproved}).CheckCounting(); err != errors.ErrStocktakeNotCounting {
+		t.Errorf("Inventário aprovado não aceita contagens: %v", err)
+	}
+}
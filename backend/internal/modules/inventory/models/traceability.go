@@ -0,0 +1,253 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"strings"
+	"time"
+)
+
+// Situação do número de série
+const (
+	SerialStatusInStock = "in_stock"
+	SerialStatusShipped = "shipped"
+)
+
+// ExpiryDateLayout é o formato das datas de validade dos lotes
+const ExpiryDateLayout = "2006-01-02"
+
+// SerialNumber registra um número de série de produto: a entrada pelo recebimento do pedido
+// de compra e a saída pelo item de entrega do pedido de venda
+type SerialNumber struct {
+	ID              int        `json:"id" gorm:"primaryKey"`
+	CompanyID       int        `json:"company_id" gorm:"<-:create"`
+	ProductID       int        `json:"product_id" gorm:"index"`
+	SerialNumber    string     `json:"serial_number" gorm:"index"`
+	LotNumber       string     `json:"lot_number,omitempty"`
+	Status          string     `json:"status" gorm:"default:in_stock"`
+	PurchaseOrderID *int       `json:"purchase_order_id,omitempty"`
+	POItemID        *int       `json:"po_item_id,omitempty" gorm:"column:po_item_id"`
+	ReceivedAt      *time.Time `json:"received_at,omitempty"`
+	DeliveryID      *int       `json:"delivery_id,omitempty"`
+	DeliveryItemID  *int       `json:"delivery_item_id,omitempty" gorm:"index"`
+	ShippedAt       *time.Time `json:"shipped_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de números de série
+func (SerialNumber) TableName() string {
+	return "serial_numbers"
+}
+
+// ReceiptLine informa o lote, a validade (AAAA-MM-DD) e os números de série de um item
+// recebido do pedido de compra
+type ReceiptLine struct {
+	POItemID   int      `json:"po_item_id" binding:"required"`
+	LotNumber  string   `json:"lot_number"`
+	ExpiryDate string   `json:"expiry_date"`
+	Serials    []string `json:"serials"`
+}
+
+// ShipmentLine informa o lote e os números de série de um item entregue
+type ShipmentLine struct {
+	DeliveryItemID int      `json:"delivery_item_id" binding:"required"`
+	LotNumber      string   `json:"lot_number"`
+	Serials        []string `json:"serials"`
+}
+
+// TraceProduct identifica o produto rastreado
+type TraceProduct struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	SKU  string `json:"sku"`
+}
+
+// TraceContact identifica o fornecedor ou o cliente
+type TraceContact struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// SerialReceipt é a entrada do número de série pelo pedido de compra
+type SerialReceipt struct {
+	PurchaseOrderID int           `json:"purchase_order_id"`
+	PONo            string        `json:"po_no"`
+	Supplier        *TraceContact `json:"supplier,omitempty"`
+	ReceivedAt      *time.Time    `json:"received_at,omitempty"`
+}
+
+// SerialShipment é a saída do número de série pela entrega do pedido de venda
+type SerialShipment struct {
+	DeliveryID   int        `json:"delivery_id"`
+	DeliveryNo   string     `json:"delivery_no"`
+	DeliveryDate time.Time  `json:"delivery_date"`
+	ShippedAt    *time.Time `json:"shipped_at,omitempty"`
+	SalesOrderID int        `json:"sales_order_id"`
+	SONo         string     `json:"so_no"`
+}
+
+// TraceInvoice é uma fatura do pedido de venda que levou o número de série
+type TraceInvoice struct {
+	ID        int       `json:"id"`
+	InvoiceNo string    `json:"invoice_no"`
+	IssueDate time.Time `json:"issue_date"`
+	Status    string    `json:"status"`
+}
+
+// SerialTrace é a rastreabilidade completa de um número de série, retornada por GET /serials/:sn
+type SerialTrace struct {
+	SerialNumber string          `json:"serial_number"`
+	Status       string          `json:"status"`
+	LotNumber    string          `json:"lot_number,omitempty"`
+	Product      TraceProduct    `json:"product"`
+	Receipt      *SerialReceipt  `json:"receipt,omitempty"`
+	Shipment     *SerialShipment `json:"shipment,omitempty"`
+	Invoices     []TraceInvoice  `json:"invoices"`
+	Customer     *TraceContact   `json:"customer,omitempty"`
+}
+
+// SerialTraceRow é a linha da consulta de rastreabilidade, com os documentos ligados ao número
+// de série em colunas anuláveis
+type SerialTraceRow struct {
+	SerialNumber    string
+	Status          string
+	LotNumber       *string
+	ProductID       int
+	ProductName     string
+	ProductSKU      string `gorm:"column:product_sku"`
+	PurchaseOrderID *int
+	PONo            *string `gorm:"column:po_no"`
+	SupplierID      *int
+	SupplierName    *string
+	ReceivedAt      *time.Time
+	DeliveryID      *int
+	DeliveryNo      *string
+	DeliveryDate    *time.Time
+	ShippedAt       *time.Time
+	SalesOrderID    *int
+	SONo            *string `gorm:"column:so_no"`
+	CustomerID      *int
+	CustomerName    *string
+}
+
+// LotReceipt é uma entrada de estoque (camada de custo) do lote
+type LotReceipt struct {
+	LayerID           int        `json:"layer_id"`
+	LotNumber         string     `json:"lot_number"`
+	ProductID         int        `json:"product_id"`
+	ProductName       string     `json:"product_name"`
+	PurchaseOrderID   *int       `json:"purchase_order_id,omitempty"`
+	PONo              *string    `json:"po_no,omitempty" gorm:"column:po_no"`
+	ReceivedAt        time.Time  `json:"received_at"`
+	ExpiryDate        *time.Time `json:"expiry_date,omitempty"`
+	Quantity          int        `json:"quantity"`
+	RemainingQuantity int        `json:"remaining_quantity"`
+}
+
+// LotShipment é uma saída do lote em item de entrega
+type LotShipment struct {
+	DeliveryItemID int       `json:"delivery_item_id"`
+	DeliveryID     int       `json:"delivery_id"`
+	DeliveryNo     string    `json:"delivery_no"`
+	DeliveryDate   time.Time `json:"delivery_date"`
+	ProductID      int       `json:"product_id"`
+	Quantity       int       `json:"quantity"`
+	SalesOrderID   int       `json:"sales_order_id"`
+	SONo           string    `json:"so_no" gorm:"column:so_no"`
+	CustomerID     *int      `json:"customer_id,omitempty"`
+	CustomerName   *string   `json:"customer_name,omitempty"`
+}
+
+// LotTrace reúne as entradas e as saídas de um lote, retornada por GET /inventory/lots/:lot
+type LotTrace struct {
+	LotNumber string        `json:"lot_number"`
+	Receipts  []LotReceipt  `json:"receipts"`
+	Shipments []LotShipment `json:"shipments"`
+}
+
+// NewSerialTrace monta a rastreabilidade a partir da linha da consulta e das faturas do pedido
+func NewSerialTrace(row SerialTraceRow, invoices []TraceInvoice) SerialTrace {
+	trace := SerialTrace{
+		SerialNumber: row.SerialNumber,
+		Status:       row.Status,
+		LotNumber:    stringValue(row.LotNumber),
+		Product:      TraceProduct{ID: row.ProductID, Name: row.ProductName, SKU: row.ProductSKU},
+		Invoices:     invoices,
+	}
+	if trace.Invoices == nil {
+		trace.Invoices = []TraceInvoice{}
+	}
+
+	if row.PurchaseOrderID != nil {
+		trace.Receipt = &SerialReceipt{
+			PurchaseOrderID: *row.PurchaseOrderID,
+			PONo:            stringValue(row.PONo),
+			Supplier:        traceContact(row.SupplierID, row.SupplierName),
+			ReceivedAt:      row.ReceivedAt,
+		}
+	}
+	if row.DeliveryID != nil {
+		trace.Shipment = &SerialShipment{
+			DeliveryID: *row.DeliveryID,
+			DeliveryNo: stringValue(row.DeliveryNo),
+			ShippedAt:  row.ShippedAt,
+			SONo:       stringValue(row.SONo),
+		}
+		if row.DeliveryDate != nil {
+			trace.Shipment.DeliveryDate = *row.DeliveryDate
+		}
+		if row.SalesOrderID != nil {
+			trace.Shipment.SalesOrderID = *row.SalesOrderID
+		}
+		trace.Customer = traceContact(row.CustomerID, row.CustomerName)
+	}
+	return trace
+}
+
+// NormalizeSerials limpa os números de série informados para um item, recusando repetidos
+// e mais números do que a quantidade do item
+func NormalizeSerials(serials []string, quantity int) ([]string, error) {
+	seen := make(map[string]bool, len(serials))
+	result := make([]string, 0, len(serials))
+	for _, serial := range serials {
+		serial = strings.TrimSpace(serial)
+		if serial == "" {
+			continue
+		}
+		if seen[serial] {
+			return nil, errors.ErrDuplicateSerial
+		}
+		seen[serial] = true
+		result = append(result, serial)
+	}
+	if len(result) > quantity {
+		return nil, errors.ErrTooManySerials
+	}
+	return result, nil
+}
+
+// ParseExpiryDate converte a validade do lote; vazio resulta em nil
+func ParseExpiryDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(ExpiryDateLayout, value)
+	if err != nil {
+		return nil, errors.InvalidParam("data de validade inválida").WithDetails(err.Error())
+	}
+	return &date, nil
+}
+
+func traceContact(id *int, name *string) *TraceContact {
+	if id == nil {
+		return nil
+	}
+	return &TraceContact{ID: *id, Name: stringValue(name)}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"testing"
+	"time"
+)
+
+func TestNormalizeSerials(t *testing.T) {
+	serials, err := NormalizeSerials([]string{" SN1 ", "", "SN2"}, 2)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(serials) != 2 || serials[0] != "SN1" || serials[1] != "SN2" {
+		t.Errorf("Esperado [SN1 SN2], obtido %v", serials)
+	}
+
+	if _, err := NormalizeSerials([]string{"SN1", "SN1"}, 2); err != errors.ErrDuplicateSerial {
+		t.Errorf("Esperado ErrDuplicateSerial, obtido %v", err)
+	}
+	if _, err := NormalizeSerials([]string{"SN1", "SN2", "SN3"}, 2); err != errors.ErrTooManySerials {
+		t.Errorf("Esperado ErrTooManySerials, obtido %v", err)
+	}
+}
+
+func TestParseExpiryDate(t *testing.T) {
+	date, err := ParseExpiryDate("2026-12-31")
+	if err != nil || date == nil || !date.Equal(time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Esperado 2026-12-31, obtido %v (%v)", date, err)
+	}
+
+	if date, err := ParseExpiryDate(""); err != nil || date != nil {
+		t.Errorf("Esperado validade vazia, obtido %v (%v)", date, err)
+	}
+	if _, err := ParseExpiryDate("31/12/2026"); err == nil {
+		t.Error("Esperado erro para data fora do formato AAAA-MM-DD")
+	}
+}
+
+func TestNewSerialTrace(t *testing.T) {
+	poID, supplierID, deliveryID, orderID, customerID := 3, 7, 11, 21, 9
+	poNo, supplier, deliveryNo, soNo, customer := "PO-1", "Fornecedor", "DEL-1", "SO-1", "Cliente"
+	shippedAt := time.Date(2026, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	row := SerialTraceRow{
+		SerialNumber:    "SN1",
+		Status:          SerialStatusShipped,
+		ProductID:       1,
+		ProductName:     "Notebook",
+		ProductSKU:      "NB-1",
+		PurchaseOrderID: &poID,
+		PONo:            &poNo,
+		SupplierID:      &supplierID,
+		SupplierName:    &supplier,
+		DeliveryID:      &deliveryID,
+		DeliveryNo:      &deliveryNo,
+		ShippedAt:       &shippedAt,
+		SalesOrderID:    &orderID,
+		SONo:            &soNo,
+		CustomerID:      &customerID,
+		CustomerName:    &customer,
+	}
+	trace := NewSerialTrace(row, []TraceInvoice{{ID: 5, InvoiceNo: "INV-1"}})
+
+	if trace.Receipt == nil || trace.Receipt.PONo != "PO-1" || trace.Receipt.Supplier.Name != "Fornecedor" {
+		t.Errorf("Recebimento inesperado: %+v", trace.Receipt)
+	}
+	if trace.Shipment == nil || trace.Shipment.SalesOrderID != 21 || trace.Shipment.SONo != "SO-1" {
+		t.Errorf("Entrega inesperada: %+v", trace.Shipment)
+	}
+	if trace.Customer == nil || trace.Customer.ID != 9 {
+		t.Errorf("Cliente inesperado: %+v", trace.Customer)
+	}
+	if len(trace.Invoices) != 1 {
+		t.Errorf("Esperada 1 fatura, obtidas %d", len(trace.Invoices))
+	}
+
+	inStock := NewSerialTrace(SerialTraceRow{SerialNumber: "SN2", Status: SerialStatusInStock}, nil)
+	if inStock.Shipment != nil || inStock.Customer != nil || inStock.Invoices == nil {
+		t.Errorf("Número em estoque não deveria ter entrega nem cliente: %+v", inStock)
+	}
+}
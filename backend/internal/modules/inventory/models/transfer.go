@@ -0,0 +1,316 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Warehouse é um depósito da empresa (matriz, filial ou centro de distribuição) com saldo próprio
+// de cada produto. O contato é a filial como destinatária da nota interna de transferência.
+type Warehouse struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	ContactID *int      `json:"contact_id,omitempty"`
+	Active    bool      `json:"active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de depósitos
+func (Warehouse) TableName() string {
+	return "warehouses"
+}
+
+// WarehouseInput são os dados de cadastro de um depósito
+type WarehouseInput struct {
+	Code      string `json:"code" binding:"required,max=20"`
+	Name      string `json:"name" binding:"required,max=100"`
+	Address   string `json:"address"`
+	ContactID *int   `json:"contact_id"`
+	Active    *bool  `json:"active"`
+}
+
+// WarehouseStock é o saldo de um produto em um depósito
+type WarehouseStock struct {
+	WarehouseID int       `json:"warehouse_id" gorm:"primaryKey"`
+	ProductID   int       `json:"product_id" gorm:"primaryKey"`
+	CompanyID   int       `json:"company_id" gorm:"<-:create"`
+	Quantity    int       `json:"quantity"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de saldos por depósito
+func (WarehouseStock) TableName() string {
+	return "warehouse_stock"
+}
+
+// StockLine é o saldo de um produto no depósito, com a identificação do produto
+type StockLine struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	ProductCode string `json:"product_code"`
+	Quantity    int    `json:"quantity"`
+}
+
+// Tipos de movimentação de estoque por depósito
+const (
+	MovementAdjustment  = "adjustment"
+	MovementTransferOut = "transfer_out"
+	MovementTransferIn  = "transfer_in"
+	MovementStocktake   = "stocktake"
+)
+
+// StockMovement registra uma entrada (quantidade positiva) ou saída (negativa) de um produto em um
+// depósito, com o saldo após a movimentação
+type StockMovement struct {
+	ID              int       `json:"id" gorm:"primaryKey"`
+	CompanyID       int       `json:"company_id" gorm:"<-:create"`
+	WarehouseID     int       `json:"warehouse_id"`
+	ProductID       int       `json:"product_id"`
+	MovementType    string    `json:"movement_type"`
+	Quantity        int       `json:"quantity"`
+	BalanceAfter    int       `json:"balance_after"`
+	StockTransferID *int      `json:"stock_transfer_id,omitempty"`
+	StocktakeID     *int      `json:"stocktake_id,omitempty"`
+	DeliveryID      *int      `json:"delivery_id,omitempty"`
+	Notes           string    `json:"notes"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName define o nome da tabela de movimentações de estoque
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+// MovementFilter filtra o histórico de movimentações do depósito
+type MovementFilter struct {
+	ProductID    int
+	MovementType string
+}
+
+// StockCount é a contagem de estoque do depósito: o saldo de cada produto informado passa a ser
+// a quantidade contada, com uma movimentação de ajuste pela diferença
+type StockCount struct {
+	Items []CountLine `json:"items" binding:"required,min=1,dive"`
+	Notes string      `json:"notes"`
+}
+
+// CountLine é a quantidade contada de um produto
+type CountLine struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"gte=0"`
+}
+
+// NextBalance aplica a movimentação ao saldo; o saldo do depósito não fica negativo
+func NextBalance(balance, quantity int) (int, error) {
+	next := balance + quantity
+	if next < 0 {
+		return balance, errors.ErrInsufficientWarehouseStock
+	}
+	return next, nil
+}
+
+// Situações da transferência entre depósitos
+const (
+	TransferStatusRequested = "requested"
+	TransferStatusShipped   = "shipped"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+// transferTransitions lista as situações de origem permitidas para cada situação de destino
+var transferTransitions = map[string]string{
+	TransferStatusShipped:   TransferStatusRequested,
+	TransferStatusReceived:  TransferStatusShipped,
+	TransferStatusCancelled: TransferStatusRequested,
+}
+
+// CheckTransferTransition confere a mudança de situação: a solicitada é enviada ou cancelada, e a
+// enviada é recebida
+func CheckTransferTransition(from, to string) error {
+	if transferTransitions[to] != from {
+		return errors.ErrInvalidTransferStatus
+	}
+	return nil
+}
+
+// StockTransfer é a transferência de produtos entre dois depósitos. O envio baixa o saldo da
+// origem e gera a entrega de saída, a entrega de entrada (pendente até o recebimento) e, com
+// internal_invoice, a nota interna para o contato do destino; o recebimento dá entrada no destino.
+type StockTransfer struct {
+	ID                     int                 `json:"id" gorm:"primaryKey"`
+	CompanyID              int                 `json:"company_id" gorm:"<-:create"`
+	TransferNo             string              `json:"transfer_no"`
+	SourceWarehouseID      int                 `json:"source_warehouse_id"`
+	DestinationWarehouseID int                 `json:"destination_warehouse_id"`
+	Status                 string              `json:"status" gorm:"default:requested"`
+	InternalInvoice        bool                `json:"internal_invoice"`
+	OutgoingDeliveryID     *int                `json:"outgoing_delivery_id,omitempty"`
+	IncomingDeliveryID     *int                `json:"incoming_delivery_id,omitempty"`
+	InvoiceID              *int                `json:"invoice_id,omitempty"`
+	Notes                  string              `json:"notes"`
+	RequestedBy            string              `json:"requested_by"`
+	RequestedAt            time.Time           `json:"requested_at"`
+	ShippedAt              *time.Time          `json:"shipped_at,omitempty"`
+	ReceivedAt             *time.Time          `json:"received_at,omitempty"`
+	CancelledAt            *time.Time          `json:"cancelled_at,omitempty"`
+	CreatedAt              time.Time           `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt              time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+	Items                  []StockTransferItem `json:"items,omitempty" gorm:"foreignKey:StockTransferID"`
+	SourceWarehouse        *Warehouse          `json:"source_warehouse,omitempty" gorm:"foreignKey:SourceWarehouseID"`
+	DestinationWarehouse   *Warehouse          `json:"destination_warehouse,omitempty" gorm:"foreignKey:DestinationWarehouseID"`
+}
+
+// TableName define o nome da tabela de transferências
+func (StockTransfer) TableName() string {
+	return "stock_transfers"
+}
+
+// Reference identifica a transferência nas entregas, na nota interna e nas movimentações
+func (t StockTransfer) Reference() string {
+	source, destination := fmt.Sprint(t.SourceWarehouseID), fmt.Sprint(t.DestinationWarehouseID)
+	if t.SourceWarehouse != nil {
+		source = t.SourceWarehouse.Code
+	}
+	if t.DestinationWarehouse != nil {
+		destination = t.DestinationWarehouse.Code
+	}
+	return fmt.Sprintf("Transferência %s de %s para %s", t.TransferNo, source, destination)
+}
+
+// StockTransferItem é um produto da transferência
+type StockTransferItem struct {
+	ID              int    `json:"id" gorm:"primaryKey"`
+	StockTransferID int    `json:"stock_transfer_id"`
+	ProductID       int    `json:"product_id"`
+	ProductName     string `json:"product_name"`
+	ProductCode     string `json:"product_code"`
+	Quantity        int    `json:"quantity"`
+}
+
+// TableName define o nome da tabela de itens das transferências
+func (StockTransferItem) TableName() string {
+	return "stock_transfer_items"
+}
+
+// TransferInput são os dados da solicitação de transferência
+type TransferInput struct {
+	SourceWarehouseID      int                 `json:"source_warehouse_id" binding:"required"`
+	DestinationWarehouseID int                 `json:"destination_warehouse_id" binding:"required"`
+	InternalInvoice        bool                `json:"internal_invoice"`
+	Notes                  string              `json:"notes"`
+	RequestedBy            string              `json:"requested_by" binding:"max=100"`
+	Items                  []TransferItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// TransferItemInput é a quantidade de um produto a transferir
+type TransferItemInput struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"required,gt=0"`
+}
+
+// Validate confere os depósitos e os itens da transferência, somando as linhas repetidas do
+// mesmo produto na ordem em que aparecem
+func (in TransferInput) Validate() ([]TransferItemInput, error) {
+	if in.SourceWarehouseID == in.DestinationWarehouseID {
+		return nil, fmt.Errorf("%w: os depósitos de origem e de destino devem ser diferentes", errors.ErrInvalidStockTransfer)
+	}
+	if len(in.Items) == 0 {
+		return nil, fmt.Errorf("%w: informe ao menos um item", errors.ErrInvalidStockTransfer)
+	}
+
+	positions := make(map[int]int)
+	items := make([]TransferItemInput, 0, len(in.Items))
+	for _, item := range in.Items {
+		if item.ProductID <= 0 || item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: produto e quantidade positiva são obrigatórios", errors.ErrInvalidStockTransfer)
+		}
+		if pos, ok := positions[item.ProductID]; ok {
+			items[pos].Quantity += item.Quantity
+			continue
+		}
+		positions[item.ProductID] = len(items)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// TransferDeliveries monta o par de entregas do envio: a de saída da origem, já enviada, e a de
+// entrada no destino, pendente até o recebimento. As duas vão para o endereço do destino.
+func TransferDeliveries(transfer StockTransfer, destination Warehouse, ship ShipTransferInput, now time.Time) (outgoing, incoming sales.Delivery) {
+	items := make([]sales.DeliveryItem, 0, len(transfer.Items))
+	for _, item := range transfer.Items {
+		items = append(items, sales.DeliveryItem{
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			ProductCode: item.ProductCode,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	outgoing = sales.Delivery{
+		Status:          sales.DeliveryStatusShipped,
+		DeliveryDate:    now,
+		ShippedAt:       &now,
+		ShippingMethod:  ship.ShippingMethod,
+		Carrier:         ship.Carrier,
+		TrackingNumber:  ship.TrackingNumber,
+		ShippingAddress: destination.Address,
+		Notes:           transfer.Reference() + " (saída)",
+		Items:           items,
+	}
+	incoming = outgoing
+	incoming.Status = sales.DeliveryStatusPending
+	incoming.ShippedAt = nil
+	incoming.Notes = transfer.Reference() + " (entrada)"
+	incoming.Items = append([]sales.DeliveryItem(nil), items...)
+	return outgoing, incoming
+}
+
+// TransferInvoice monta a nota interna da transferência para o contato da filial de destino, em
+// rascunho e sem cobrança, com os itens pelo custo unitário do produto
+func TransferInvoice(transfer StockTransfer, contactID int, unitCosts map[int]money.Decimal, now time.Time) sales.Invoice {
+	invoice := sales.Invoice{
+		ContactID: contactID,
+		Status:    sales.InvoiceStatusDraft,
+		IssueDate: now,
+		DueDate:   now,
+		Notes:     transfer.Reference() + " — nota interna de transferência, sem cobrança",
+	}
+	for _, item := range transfer.Items {
+		invoice.Items = append(invoice.Items, sales.InvoiceItem{
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			ProductCode: item.ProductCode,
+			Quantity:    item.Quantity,
+			UnitPrice:   unitCosts[item.ProductID],
+		})
+	}
+	sales.RecalculateInvoiceTotals(&invoice)
+	return invoice
+}
+
+// ShipTransferInput são os dados opcionais de transporte do envio
+type ShipTransferInput struct {
+	ShippingMethod string `json:"shipping_method"`
+	Carrier        string `json:"carrier" binding:"max=30"`
+	TrackingNumber string `json:"tracking_number"`
+}
+
+// TransferFilter filtra a lista de transferências
+type TransferFilter struct {
+	Status      string
+	WarehouseID int
+}
+
+// NormalizeWarehouseCode padroniza o código do depósito (sem espaços, em maiúsculas)
+func NormalizeWarehouseCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func sampleTransfer() StockTransfer {
+	return StockTransfer{
+		ID:                     5,
+		TransferNo:             "TRF-2026-000005",
+		SourceWarehouseID:      1,
+		DestinationWarehouseID: 2,
+		SourceWarehouse:        &Warehouse{ID: 1, Code: "MATRIZ"},
+		DestinationWarehouse:   &Warehouse{ID: 2, Code: "FILIAL-RJ", Address: "Rua A, 10 - Rio de Janeiro/RJ"},
+		Items: []StockTransferItem{
+			{ProductID: 7, ProductName: "Cabo HDMI", ProductCode: "CB-HDMI", Quantity: 10},
+			{ProductID: 8, ProductName: "Mouse", ProductCode: "MS-1", Quantity: 3},
+		},
+	}
+}
+
+func TestTransferInputValidate(t *testing.T) {
+	input := TransferInput{
+		SourceWarehouseID:      1,
+		DestinationWarehouseID: 2,
+		Items:                  []TransferItemInput{{ProductID: 7, Quantity: 2}, {ProductID: 8, Quantity: 1}, {ProductID: 7, Quantity: 3}},
+	}
+	items, err := input.Validate()
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if !reflect.DeepEqual(items, []TransferItemInput{{ProductID: 7, Quantity: 5}, {ProductID: 8, Quantity: 1}}) {
+		t.Errorf("Linhas repetidas deveriam ser somadas na ordem: %+v", items)
+	}
+
+	invalid := []TransferInput{
+		{SourceWarehouseID: 1, DestinationWarehouseID: 1, Items: input.Items},
+		{SourceWarehouseID: 1, DestinationWarehouseID: 2},
+		{SourceWarehouseID: 1, DestinationWarehouseID: 2, Items: []TransferItemInput{{ProductID: 7, Quantity: 0}}},
+	}
+	for _, in := range invalid {
+		if _, err := in.Validate(); !stderrors.Is(err, errors.ErrInvalidStockTransfer) {
+			t.Errorf("%+v: esperado ErrInvalidStockTransfer, obtido %v", in, err)
+		}
+	}
+}
+
+func TestCheckTransferTransition(t *testing.T) {
+	allowed := [][2]string{
+		{TransferStatusRequested, TransferStatusShipped},
+		{TransferStatusShipped, TransferStatusReceived},
+		{TransferStatusRequested, TransferStatusCancelled},
+	}
+	for _, pair := range allowed {
+		if err := CheckTransferTransition(pair[0], pair[1]); err != nil {
+			t.Errorf("%s -> %s deveria ser permitida: %v", pair[0], pair[1], err)
+		}
+	}
+
+	denied := [][2]string{
+		{TransferStatusShipped, TransferStatusCancelled},
+		{TransferStatusRequested, TransferStatusReceived},
+		{TransferStatusReceived, TransferStatusShipped},
+		{TransferStatusCancelled, TransferStatusShipped},
+	}
+	for _, pair := range denied {
+		if err := CheckTransferTransition(pair[0], pair[1]); err != errors.ErrInvalidTransferStatus {
+			t.Errorf("%s -> %s: esperado ErrInvalidTransferStatus, obtido %v", pair[0], pair[1], err)
+		}
+	}
+}
+
+func TestNextBalance(t *testing.T) {
+	if balance, err := NextBalance(10, -10); err != nil || balance != 0 {
+		t.Errorf("Saída de todo o saldo: %d, %v", balance, err)
+	}
+	if balance, err := NextBalance(4, 6); err != nil || balance != 10 {
+		t.Errorf("Entrada: %d, %v", balance, err)
+	}
+	if balance, err := NextBalance(4, -5); err != errors.ErrInsufficientWarehouseStock || balance != 4 {
+		t.Errorf("Saída maior que o saldo: %d, %v", balance, err)
+	}
+}
+
+func TestTransferDeliveries(t *testing.T) {
+	transfer := sampleTransfer()
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	outgoing, incoming := TransferDeliveries(transfer, *transfer.DestinationWarehouse, ShipTransferInput{Carrier: "Jadlog"}, now)
+
+	if outgoing.Status != sales.DeliveryStatusShipped || outgoing.ShippedAt == nil || outgoing.Carrier != "Jadlog" {
+		t.Errorf("Entrega de saída inesperada: %+v", outgoing)
+	}
+	if incoming.Status != sales.DeliveryStatusPending || incoming.ShippedAt != nil {
+		t.Errorf("Entrega de entrada deveria ficar pendente: %+v", incoming)
+	}
+	if outgoing.Notes != "Transferência TRF-2026-000005 de MATRIZ para FILIAL-RJ (saída)" ||
+		incoming.Notes != "Transferência TRF-2026-000005 de MATRIZ para FILIAL-RJ (entrada)" {
+		t.Errorf("Referências inesperadas: %q, %q", outgoing.Notes, incoming.Notes)
+	}
+	if outgoing.ShippingAddress != "Rua A, 10 - Rio de Janeiro/RJ" || incoming.ShippingAddress != outgoing.ShippingAddress {
+		t.Errorf("As duas entregas vão para o endereço do destino: %q, %q", outgoing.ShippingAddress, incoming.ShippingAddress)
+	}
+	if len(outgoing.Items) != 2 || outgoing.Items[0].Quantity != 10 || outgoing.Items[1].ProductCode != "MS-1" {
+		t.Fatalf("Itens inesperados: %+v", outgoing.Items)
+	}
+
+	outgoing.Items[0].DeliveryID = 99
+	if incoming.Items[0].DeliveryID != 0 {
+		t.Error("As entregas não podem compartilhar os itens")
+	}
+}
+
+func TestTransferInvoice(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	invoice := TransferInvoice(sampleTransfer(), 42, map[int]money.Decimal{7: money.MustParse("12.5"), 8: money.FromInt(30)}, now)
+
+	if invoice.ContactID != 42 || invoice.Status != sales.InvoiceStatusDraft || !invoice.DueDate.Equal(now) {
+		t.Errorf("Nota interna inesperada: %+v", invoice)
+	}
+	if len(invoice.Items) != 2 || !invoice.Items[0].Total.Equal(money.FromInt(125)) {
+		t.Fatalf("Itens inesperados: %+v", invoice.Items)
+	}
+	if !invoice.GrandTotal.Equal(money.FromInt(215)) {
+		t.Errorf("Total esperado 215, obtido %s", invoice.GrandTotal)
+	}
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BinRepository define as operações do cadastro de endereços de estoque
+type BinRepository interface {
+	ListBins(ctx context.Context, zone string) ([]models.WarehouseBin, error)
+	GetBin(ctx context.Context, id int) (*models.WarehouseBin, error)
+	CreateBin(ctx context.Context, input models.BinInput) (*models.WarehouseBin, error)
+	UpdateBin(ctx context.Context, id int, input models.BinInput) (*models.WarehouseBin, error)
+	DeleteBin(ctx context.Context, id int) error
+}
+
+type binRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewBinRepository cria uma nova instância do repositório
+func NewBinRepository() (BinRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &binRepository{
+		db:     gormDB,
+		logger: logger.WithModule("bin_repository"),
+	}, nil
+}
+
+// ListBins lista os endereços de estoque por código, opcionalmente de uma zona
+func (r *binRepository) ListBins(ctx context.Context, zone string) ([]models.WarehouseBin, error) {
+	query := db.Conn(ctx, r.db).Order("code ASC")
+	if zone != "" {
+		query = query.Where("zone = ?", zone)
+	}
+
+	var bins []models.WarehouseBin
+	if err := query.Find(&bins).Error; err != nil {
+		r.logger.Error("erro ao listar endereços de estoque", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar endereços de estoque")
+	}
+	return bins, nil
+}
+
+// GetBin busca um endereço de estoque pelo ID
+func (r *binRepository) GetBin(ctx context.Context, id int) (*models.WarehouseBin, error) {
+	var bin models.WarehouseBin
+	if err := db.Conn(ctx, r.db).First(&bin, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrBinNotFound
+		}
+		r.logger.Error("erro ao buscar endereço de estoque", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar endereço de estoque")
+	}
+	return &bin, nil
+}
+
+// CreateBin cadastra um endereço de estoque com código único na empresa
+func (r *binRepository) CreateBin(ctx context.Context, input models.BinInput) (*models.WarehouseBin, error) {
+	bin := models.WarehouseBin{
+		Code:   models.NormalizeBinCode(input.Code),
+		Name:   input.Name,
+		Zone:   input.Zone,
+		Active: input.Active == nil || *input.Active,
+	}
+	if err := r.ensureUniqueCode(ctx, bin.Code, 0); err != nil {
+		return nil, err
+	}
+
+	if err := db.Conn(ctx, r.db).Create(&bin).Error; err != nil {
+		r.logger.Error("erro ao criar endereço de estoque", zap.Error(err), zap.String("code", bin.Code))
+		return nil, errors.WrapError(err, "falha ao criar endereço de estoque")
+	}
+
+	r.logger.Info("endereço de estoque criado", zap.Int("id", bin.ID), zap.String("code", bin.Code))
+	return &bin, nil
+}
+
+// UpdateBin altera o código, o nome, a zona ou a situação do endereço de estoque
+func (r *binRepository) UpdateBin(ctx context.Context, id int, input models.BinInput) (*models.WarehouseBin, error) {
+	bin, err := r.GetBin(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	code := models.NormalizeBinCode(input.Code)
+	if code != bin.Code {
+		if err := r.ensureUniqueCode(ctx, code, id); err != nil {
+			return nil, err
+		}
+	}
+
+	updates := map[string]interface{}{
+		"code": code,
+		"name": input.Name,
+		"zone": input.Zone,
+	}
+	if input.Active != nil {
+		updates["active"] = *input.Active
+	}
+	if err := db.Conn(ctx, r.db).Model(&models.WarehouseBin{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		r.logger.Error("erro ao atualizar endereço de estoque", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar endereço de estoque")
+	}
+	return r.GetBin(ctx, id)
+}
+
+// DeleteBin remove o endereço de estoque
+func (r *binRepository) DeleteBin(ctx context.Context, id int) error {
+	result := db.Conn(ctx, r.db).Where("id = ?", id).Delete(&models.WarehouseBin{})
+	if result.Error != nil {
+		r.logger.Error("erro ao excluir endereço de estoque", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao excluir endereço de estoque")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrBinNotFound
+	}
+	return nil
+}
+
+func (r *binRepository) ensureUniqueCode(ctx context.Context, code string, exceptID int) error {
+	var count int64
+	if err := db.Conn(ctx, r.db).Model(&models.WarehouseBin{}).
+		Where("code = ? AND id <> ?", code, exceptID).
+		Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar código do endereço de estoque")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateBinCode
+	}
+	return nil
+}
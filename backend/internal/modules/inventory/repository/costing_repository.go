@@ -0,0 +1,601 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	productModels "ERP-ONSMART/backend/internal/modules/products/models"
+	products "ERP-ONSMART/backend/internal/modules/products/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CostingRepository define as operações de custeio de estoque e CMV
+type CostingRepository interface {
+	GetProductCosting(ctx context.Context, productID int) (*models.ProductCosting, error)
+	SetCostingMethod(ctx context.Context, productID int, method string) (*models.ProductCosting, error)
+	GetCostLayers(ctx context.Context, productID int) ([]models.CostLayer, error)
+
+	ReceivePurchaseOrder(ctx context.Context, purchaseOrderID int, lines []models.ReceiptLine) ([]models.CostLayer, error)
+	RecordDeliveryCOGS(ctx context.Context, deliveryID int) ([]models.COGSEntry, error)
+	RecordInvoiceCOGS(ctx context.Context, invoiceID int) ([]models.COGSEntry, error)
+	GetCOGSBySalesOrder(ctx context.Context, salesOrderID int) ([]models.COGSEntry, error)
+	EstimateCosts(ctx context.Context, queries []models.CostQuery) ([]models.CostEstimate, error)
+}
+
+type costingRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// costItem é um item de documento de venda a ser custeado. Itens de kits (a linha do kit mantido,
+// baixada nos componentes, ou as linhas desdobradas) levam o produto do kit para a margem por kit.
+type costItem struct {
+	itemID       int
+	productID    int
+	quantity     int
+	kitProductID *int
+}
+
+// NewCostingRepository cria uma nova instância do repositório
+func NewCostingRepository() (CostingRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &costingRepository{
+		db:     db,
+		logger: logger.WithModule("costing_repository"),
+	}, nil
+}
+
+// GetProductCosting retorna o custeio do produto; se ainda não existir, devolve o padrão
+// (método configurado em DEFAULT_COSTING_METHOD e custo de cadastro do produto)
+func (r *costingRepository) GetProductCosting(ctx context.Context, productID int) (*models.ProductCosting, error) {
+	return r.loadCosting(db.Conn(ctx, r.db), productID, false)
+}
+
+// SetCostingMethod altera o método de custeio do produto
+func (r *costingRepository) SetCostingMethod(ctx context.Context, productID int, method string) (*models.ProductCosting, error) {
+	costing, err := r.loadCosting(db.Conn(ctx, r.db), productID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	costing.Method = method
+	if err := db.Conn(ctx, r.db).Save(costing).Error; err != nil {
+		r.logger.Error("erro ao salvar método de custeio", zap.Error(err), zap.Int("product_id", productID))
+		return nil, errors.WrapError(err, "falha ao salvar método de custeio")
+	}
+
+	r.logger.Info("método de custeio alterado", zap.Int("product_id", productID), zap.String("method", method))
+	return costing, nil
+}
+
+// GetCostLayers retorna as camadas de custo com saldo do produto, da mais antiga para a mais nova
+func (r *costingRepository) GetCostLayers(ctx context.Context, productID int) ([]models.CostLayer, error) {
+	var layers []models.CostLayer
+	if err := db.Conn(ctx, r.db).Where("product_id = ? AND remaining_quantity > 0", productID).
+		Order("received_at ASC, id ASC").
+		Find(&layers).Error; err != nil {
+		r.logger.Error("erro ao buscar camadas de custo", zap.Error(err), zap.Int("product_id", productID))
+		return nil, errors.WrapError(err, "falha ao buscar camadas de custo")
+	}
+	return layers, nil
+}
+
+// ReceivePurchaseOrder registra o recebimento do pedido de compra, criando uma camada de custo
+// por item e atualizando o custo médio. As linhas informam o lote, a validade e os números de
+// série de cada item. Itens já recebidos são ignorados.
+func (r *costingRepository) ReceivePurchaseOrder(ctx context.Context, purchaseOrderID int, lines []models.ReceiptLine) ([]models.CostLayer, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var po sales.PurchaseOrder
+	if err := tx.Preload("Items").First(&po, purchaseOrderID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrPurchaseOrderNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar pedido de compra")
+	}
+
+	if po.Status == sales.POStatusDraft || po.Status == sales.POStatusCancelled {
+		tx.Rollback()
+		return nil, errors.ErrPurchaseOrderNotReceivable
+	}
+
+	receipts, err := indexReceiptLines(po.Items, lines)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	layers := []models.CostLayer{}
+
+	for _, item := range po.Items {
+		// As camadas de custo ficam em unidades de estoque (ex.: 2 CX de 12 entram como 24 UN)
+		quantity := item.BaseQuantity()
+		if quantity <= 0 {
+			continue
+		}
+
+		var count int64
+		if err := tx.Model(&models.CostLayer{}).Where("po_item_id = ?", item.ID).Count(&count).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao verificar recebimento do item")
+		}
+		if count > 0 {
+			continue
+		}
+
+		unitCost := item.UnitPrice.DivInt(max(item.UnitFactor, 1))
+		if item.Total.IsPositive() {
+			unitCost = item.Total.DivInt(quantity)
+		}
+
+		costing, err := r.loadCosting(tx, item.ProductID, true)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		costing.ApplyReceipt(quantity, unitCost)
+
+		if err := tx.Save(costing).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao atualizar custo médio")
+		}
+
+		receipt := receipts[item.ID]
+		poID, itemID := po.ID, item.ID
+		layer := models.CostLayer{
+			ProductID:         item.ProductID,
+			PurchaseOrderID:   &poID,
+			POItemID:          &itemID,
+			ReceivedAt:        now,
+			Quantity:          quantity,
+			RemainingQuantity: quantity,
+			UnitCost:          unitCost,
+			LotNumber:         receipt.lotNumber,
+			ExpiryDate:        receipt.expiryDate,
+		}
+		if err := tx.Create(&layer).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao criar camada de custo", zap.Error(err), zap.Int("po_item_id", item.ID))
+			return nil, errors.WrapError(err, "falha ao criar camada de custo")
+		}
+		layers = append(layers, layer)
+
+		if err := r.receiveSerials(tx, po.CompanyID, layer, receipt.serials); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if po.Status != sales.POStatusReceived {
+		if err := tx.Model(&sales.PurchaseOrder{}).Where("id = ?", po.ID).
+			Update("status", sales.POStatusReceived).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao atualizar status do pedido de compra")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("pedido de compra recebido",
+		zap.Int("purchase_order_id", po.ID), zap.Int("layers", len(layers)))
+	return layers, nil
+}
+
+// RecordDeliveryCOGS apura o CMV dos itens de uma entrega de pedido de venda
+func (r *costingRepository) RecordDeliveryCOGS(ctx context.Context, deliveryID int) ([]models.COGSEntry, error) {
+	var delivery sales.Delivery
+	if err := db.Conn(ctx, r.db).Preload("Items").First(&delivery, deliveryID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar delivery")
+	}
+
+	if delivery.SalesOrderID == 0 {
+		return nil, errors.ErrDeliveryNotOutbound
+	}
+
+	// As linhas desdobradas de kits são identificadas pelo item do pedido de venda de origem
+	soItemIDs := make([]int, 0, len(delivery.Items))
+	for _, item := range delivery.Items {
+		if item.SOItemID != nil {
+			soItemIDs = append(soItemIDs, *item.SOItemID)
+		}
+	}
+	var soItems []sales.SOItem
+	if len(soItemIDs) > 0 {
+		if err := db.Conn(ctx, r.db).Select("id", "kit_product_id").
+			Where("id IN ? AND kit_product_id IS NOT NULL", soItemIDs).
+			Find(&soItems).Error; err != nil {
+			return nil, errors.WrapError(err, "falha ao buscar itens do pedido de venda")
+		}
+	}
+	kitBySOItem := make(map[int]*int, len(soItems))
+	for _, item := range soItems {
+		kitBySOItem[item.ID] = item.KitProductID
+	}
+
+	items := make([]costItem, 0, len(delivery.Items))
+	for _, item := range delivery.Items {
+		line := costItem{itemID: item.ID, productID: item.ProductID, quantity: item.Quantity}
+		if item.SOItemID != nil {
+			line.kitProductID = kitBySOItem[*item.SOItemID]
+		}
+		items = append(items, line)
+	}
+
+	items, err := r.expandKeptKits(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.recordCOGS(ctx, models.COGSSource{
+		Type:         models.COGSSourceDelivery,
+		ID:           delivery.ID,
+		SalesOrderID: delivery.SalesOrderID,
+	}, items)
+}
+
+// RecordInvoiceCOGS apura o CMV no faturamento. Se o pedido de venda já teve o custo
+// apurado pelas entregas, a fatura não gera novo custo para evitar duplicidade.
+func (r *costingRepository) RecordInvoiceCOGS(ctx context.Context, invoiceID int) ([]models.COGSEntry, error) {
+	var invoice sales.Invoice
+	if err := db.Conn(ctx, r.db).Preload("Items").First(&invoice, invoiceID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar invoice")
+	}
+
+	if invoice.Status == sales.InvoiceStatusDraft || invoice.Status == sales.InvoiceStatusCancelled {
+		return nil, errors.ErrDocumentNotPostable
+	}
+
+	if invoice.SalesOrderID > 0 {
+		var count int64
+		if err := db.Conn(ctx, r.db).Model(&models.COGSEntry{}).
+			Where("sales_order_id = ? AND source_type = ?", invoice.SalesOrderID, models.COGSSourceDelivery).
+			Count(&count).Error; err != nil {
+			return nil, errors.WrapError(err, "falha ao verificar CMV das entregas")
+		}
+		if count > 0 {
+			return []models.COGSEntry{}, nil
+		}
+	}
+
+	items := make([]costItem, 0, len(invoice.Items))
+	for _, item := range invoice.Items {
+		items = append(items, costItem{
+			itemID:       item.ID,
+			productID:    item.ProductID,
+			quantity:     item.BaseQuantity(),
+			kitProductID: item.KitProductID,
+		})
+	}
+
+	items, err := r.expandKeptKits(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.recordCOGS(ctx, models.COGSSource{
+		Type:         models.COGSSourceInvoice,
+		ID:           invoice.ID,
+		SalesOrderID: invoice.SalesOrderID,
+	}, items)
+}
+
+// GetCOGSBySalesOrder retorna os custos apurados para um pedido de venda
+func (r *costingRepository) GetCOGSBySalesOrder(ctx context.Context, salesOrderID int) ([]models.COGSEntry, error) {
+	var entries []models.COGSEntry
+	if err := db.Conn(ctx, r.db).Where("sales_order_id = ?", salesOrderID).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error; err != nil {
+		r.logger.Error("erro ao buscar CMV do pedido", zap.Error(err), zap.Int("sales_order_id", salesOrderID))
+		return nil, errors.WrapError(err, "falha ao buscar CMV do pedido")
+	}
+	return entries, nil
+}
+
+// EstimateCosts estima o custo de venda de cada consulta como o CMV faria agora (camadas na ordem
+// de entrada no FIFO, custo médio no médio), sem gravar nada. As consultas do mesmo produto
+// consomem as camadas em sequência, como as linhas de um mesmo documento.
+func (r *costingRepository) EstimateCosts(ctx context.Context, queries []models.CostQuery) ([]models.CostEstimate, error) {
+	items := make([]costItem, 0, len(queries))
+	for i, query := range queries {
+		items = append(items, costItem{itemID: i, productID: query.ProductID, quantity: query.Quantity})
+	}
+	items, err := r.expandKeptKits(ctx, items)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar componentes dos kits")
+	}
+
+	conn := db.Conn(ctx, r.db)
+	costings := make(map[int]*models.ProductCosting)
+	layers := make(map[int][]models.CostLayer)
+	estimates := make([]models.CostEstimate, len(queries))
+	for i, query := range queries {
+		estimates[i] = models.CostEstimate{ProductID: query.ProductID, Quantity: query.Quantity}
+	}
+
+	for _, item := range items {
+		costing, ok := costings[item.productID]
+		if !ok {
+			if costing, err = r.loadCosting(conn, item.productID, false); err != nil {
+				return nil, err
+			}
+			var productLayers []models.CostLayer
+			if err := conn.Where("product_id = ? AND remaining_quantity > 0", item.productID).
+				Order("received_at ASC, id ASC").
+				Find(&productLayers).Error; err != nil {
+				r.logger.Error("erro ao buscar camadas de custo", zap.Error(err), zap.Int("product_id", item.productID))
+				return nil, errors.WrapError(err, "falha ao buscar camadas de custo")
+			}
+			costings[item.productID], layers[item.productID] = costing, productLayers
+		}
+		if item.quantity <= 0 {
+			continue
+		}
+
+		_, totalCost := costing.Consume(layers[item.productID], item.quantity)
+		estimate := &estimates[item.itemID]
+		estimate.TotalCost = estimate.TotalCost.Add(totalCost)
+		if item.kitProductID == nil {
+			estimate.Method = costing.Method
+		}
+	}
+	return estimates, nil
+}
+
+// expandKeptKits troca cada item de kit mantido (modo keep) pelos componentes, na quantidade do
+// kit multiplicada pela quantidade de cada componente; o item de origem continua o mesmo
+func (r *costingRepository) expandKeptKits(ctx context.Context, items []costItem) ([]costItem, error) {
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.productID)
+	}
+	kits, err := products.LoadKits(db.Conn(ctx, r.db), productIDs)
+	if err != nil || len(kits) == 0 {
+		return items, err
+	}
+
+	expanded := make([]costItem, 0, len(items))
+	for _, item := range items {
+		kit, ok := kits[item.productID]
+		if !ok || kit.Mode != productModels.KitModeKeep || len(kit.Components) == 0 {
+			expanded = append(expanded, item)
+			continue
+		}
+		kitProductID := item.productID
+		for _, component := range kit.Components {
+			expanded = append(expanded, costItem{
+				itemID:       item.itemID,
+				productID:    component.ComponentProductID,
+				quantity:     item.quantity * component.Quantity,
+				kitProductID: &kitProductID,
+			})
+		}
+	}
+	return expanded, nil
+}
+
+// recordCOGS baixa as camadas de custo e grava o CMV de cada item em uma única transação.
+// Itens já custeados são ignorados, tornando a operação idempotente; um item de kit mantido
+// gera uma entrada por componente.
+func (r *costingRepository) recordCOGS(ctx context.Context, source models.COGSSource, items []costItem) ([]models.COGSEntry, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+	entries := []models.COGSEntry{}
+
+	for _, item := range items {
+		if item.quantity <= 0 {
+			continue
+		}
+
+		var count int64
+		if err := tx.Model(&models.COGSEntry{}).
+			Where("source_type = ? AND source_item_id = ? AND product_id = ?", source.Type, item.itemID, item.productID).
+			Count(&count).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao verificar CMV do item")
+		}
+		if count > 0 {
+			continue
+		}
+
+		costing, err := r.loadCosting(tx, item.productID, true)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		var layers []models.CostLayer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND remaining_quantity > 0", item.productID).
+			Order("received_at ASC, id ASC").
+			Find(&layers).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao buscar camadas de custo")
+		}
+
+		consumptions, totalCost := costing.Consume(layers, item.quantity)
+
+		for _, consumption := range consumptions {
+			if err := tx.Model(&models.CostLayer{}).Where("id = ?", consumption.LayerID).
+				Update("remaining_quantity", gorm.Expr("remaining_quantity - ?", consumption.Quantity)).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.WrapError(err, "falha ao baixar camada de custo")
+			}
+		}
+
+		if err := tx.Save(costing).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao atualizar estoque custeado")
+		}
+
+		entry := models.COGSEntry{
+			ProductID:    item.productID,
+			SourceType:   source.Type,
+			SourceID:     source.ID,
+			SourceItemID: item.itemID,
+			Quantity:     item.quantity,
+			UnitCost:     totalCost.DivInt(item.quantity),
+			TotalCost:    totalCost,
+			Method:       costing.Method,
+			KitProductID: item.kitProductID,
+		}
+		if source.SalesOrderID > 0 {
+			soID := source.SalesOrderID
+			entry.SalesOrderID = &soID
+		}
+
+		if err := tx.Create(&entry).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao gravar CMV", zap.Error(err), zap.Int("item_id", item.itemID))
+			return nil, errors.WrapError(err, "falha ao gravar CMV")
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("CMV apurado",
+		zap.String("source_type", source.Type), zap.Int("source_id", source.ID), zap.Int("entries", len(entries)))
+	return entries, nil
+}
+
+// loadCosting busca o custeio do produto (com bloqueio opcional). Se não existir, monta o
+// registro padrão a partir do cadastro do produto; ele é persistido no primeiro Save.
+func (r *costingRepository) loadCosting(tx *gorm.DB, productID int, lock bool) (*models.ProductCosting, error) {
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var costing models.ProductCosting
+	err := query.Where("product_id = ?", productID).First(&costing).Error
+	if err == nil {
+		return &costing, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, errors.WrapError(err, "falha ao buscar custeio do produto")
+	}
+
+	var product struct {
+		ID        int
+		CostPrice money.Decimal
+	}
+	if err := tx.Table("products").Select("id, COALESCE(cost_price, 0) AS cost_price").
+		Where("id = ? AND deleted_at IS NULL", productID).
+		Scan(&product).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar produto")
+	}
+	if product.ID == 0 {
+		return nil, errors.ErrProductNotFound
+	}
+
+	method := viper.GetString("DEFAULT_COSTING_METHOD")
+	if method != models.CostingMethodFIFO {
+		method = models.CostingMethodAverage
+	}
+
+	return &models.ProductCosting{
+		ProductID:   productID,
+		Method:      method,
+		AverageCost: product.CostPrice,
+	}, nil
+}
+
+// receiptDetails são o lote, a validade e os números de série de um item recebido
+type receiptDetails struct {
+	lotNumber  string
+	expiryDate *time.Time
+	serials    []string
+}
+
+// indexReceiptLines valida as linhas do recebimento contra os itens do pedido de compra
+func indexReceiptLines(items []sales.POItem, lines []models.ReceiptLine) (map[int]receiptDetails, error) {
+	quantities := make(map[int]int, len(items))
+	for _, item := range items {
+		quantities[item.ID] = item.BaseQuantity()
+	}
+
+	receipts := make(map[int]receiptDetails, len(lines))
+	for _, line := range lines {
+		quantity, ok := quantities[line.POItemID]
+		if !ok {
+			return nil, errors.ErrReceiptItemNotInOrder
+		}
+		expiryDate, err := models.ParseExpiryDate(line.ExpiryDate)
+		if err != nil {
+			return nil, err
+		}
+		serials, err := models.NormalizeSerials(line.Serials, quantity)
+		if err != nil {
+			return nil, err
+		}
+		receipts[line.POItemID] = receiptDetails{
+			lotNumber:  strings.TrimSpace(line.LotNumber),
+			expiryDate: expiryDate,
+			serials:    serials,
+		}
+	}
+	return receipts, nil
+}
+
+// receiveSerials registra em estoque os números de série do item recebido
+func (r *costingRepository) receiveSerials(tx *gorm.DB, companyID int, layer models.CostLayer, serials []string) error {
+	if len(serials) == 0 {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.SerialNumber{}).
+		Where("company_id = ? AND product_id = ? AND serial_number IN ?", companyID, layer.ProductID, serials).
+		Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar números de série")
+	}
+	if count > 0 {
+		return errors.ErrSerialAlreadyReceived
+	}
+
+	records := make([]models.SerialNumber, 0, len(serials))
+	for _, serial := range serials {
+		records = append(records, models.SerialNumber{
+			CompanyID:       companyID,
+			ProductID:       layer.ProductID,
+			SerialNumber:    serial,
+			LotNumber:       layer.LotNumber,
+			Status:          models.SerialStatusInStock,
+			PurchaseOrderID: layer.PurchaseOrderID,
+			POItemID:        layer.POItemID,
+			ReceivedAt:      &layer.ReceivedAt,
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		r.logger.Error("erro ao registrar números de série", zap.Error(err), zap.Int("product_id", layer.ProductID))
+		return errors.WrapError(err, "falha ao registrar números de série")
+	}
+	return nil
+}
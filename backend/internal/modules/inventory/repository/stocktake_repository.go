@@ -0,0 +1,523 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	labels "ERP-ONSMART/backend/internal/modules/labels/models"
+	productModels "ERP-ONSMART/backend/internal/modules/products/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StocktakeRepository define as operações dos inventários físicos: abertura com saldo congelado,
+// contagens, relatório de diferenças e aprovação dos ajustes
+type StocktakeRepository interface {
+	CreateStocktake(ctx context.Context, input models.StocktakeInput, now time.Time) (*models.Stocktake, error)
+	GetStocktake(ctx context.Context, id int) (*models.Stocktake, error)
+	ListStocktakes(ctx context.Context, filter models.StocktakeFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetCountSheet(ctx context.Context, id int) (*models.CountSheet, error)
+
+	AddCount(ctx context.Context, id int, input models.CountInput) (*models.StocktakeCount, error)
+	ScanCount(ctx context.Context, id int, input models.ScanInput) (*models.StocktakeCount, error)
+	DeleteCount(ctx context.Context, id, countID int) error
+	GetVariances(ctx context.Context, id int) (*models.VarianceReport, error)
+
+	ApproveStocktake(ctx context.Context, id int, approvedBy string, now time.Time) (*models.StocktakeApproval, error)
+	CancelStocktake(ctx context.Context, id int, now time.Time) (*models.Stocktake, error)
+}
+
+type stocktakeRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewStocktakeRepository cria uma nova instância do repositório
+func NewStocktakeRepository() (StocktakeRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &stocktakeRepository{
+		db:     gormDB,
+		logger: logger.WithModule("stocktake_repository"),
+	}, nil
+}
+
+// CreateStocktake abre o inventário do depósito congelando o saldo dos produtos com estoque e dos
+// produtos informados, com o endereço de estoque e o custo unitário de cada um
+func (r *stocktakeRepository) CreateStocktake(ctx context.Context, input models.StocktakeInput, now time.Time) (*models.Stocktake, error) {
+	stocktake := models.Stocktake{
+		WarehouseID: input.WarehouseID,
+		Status:      models.StocktakeStatusCounting,
+		Notes:       input.Notes,
+		CreatedBy:   input.CreatedBy,
+		FrozenAt:    now,
+	}
+
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		warehouse, err := findWarehouse(tx, input.WarehouseID)
+		if err != nil {
+			return err
+		}
+		var open int64
+		if err := tx.Model(&models.Stocktake{}).
+			Where("warehouse_id = ? AND status = ?", input.WarehouseID, models.StocktakeStatusCounting).
+			Count(&open).Error; err != nil {
+			return errors.WrapError(err, "falha ao verificar inventários do depósito")
+		}
+		if open > 0 {
+			return errors.ErrStocktakeInProgress
+		}
+
+		var balances []models.WarehouseStock
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+			Where("warehouse_id = ? AND quantity <> 0", input.WarehouseID).
+			Find(&balances).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar saldos do depósito")
+		}
+		expected := make(map[int]int, len(balances)+len(input.ProductIDs))
+		for _, balance := range balances {
+			expected[balance.ProductID] = balance.Quantity
+		}
+		for _, productID := range input.ProductIDs {
+			if _, ok := expected[productID]; !ok {
+				expected[productID] = 0
+			}
+		}
+
+		productIDs := make([]int, 0, len(expected))
+		for productID := range expected {
+			productIDs = append(productIDs, productID)
+		}
+		sort.Ints(productIDs)
+		if len(productIDs) > 0 {
+			lines, err := snapshotLines(tx, productIDs)
+			if err != nil {
+				return err
+			}
+			for i := range lines {
+				lines[i].ExpectedQty = expected[lines[i].ProductID]
+			}
+			stocktake.Lines = lines
+		}
+
+		stocktake.StocktakeNo = salesRepository.NextDocumentNumber(tx, &models.Stocktake{}, "INVF")
+		if err := tx.Omit(clause.Associations).Create(&stocktake).Error; err != nil {
+			return errors.WrapError(err, "falha ao criar inventário")
+		}
+		if len(stocktake.Lines) > 0 {
+			for i := range stocktake.Lines {
+				stocktake.Lines[i].StocktakeID = stocktake.ID
+			}
+			if err := tx.Create(&stocktake.Lines).Error; err != nil {
+				return errors.WrapError(err, "falha ao congelar saldos do inventário")
+			}
+		}
+		stocktake.Warehouse = warehouse
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("inventário rejeitado", zap.Error(err), zap.Int("warehouse_id", input.WarehouseID))
+		return nil, err
+	}
+
+	r.logger.Info("inventário aberto", zap.Int("id", stocktake.ID), zap.String("stocktake_no", stocktake.StocktakeNo),
+		zap.Int("lines", len(stocktake.Lines)))
+	return &stocktake, nil
+}
+
+// GetStocktake busca o inventário com os produtos congelados
+func (r *stocktakeRepository) GetStocktake(ctx context.Context, id int) (*models.Stocktake, error) {
+	return findStocktake(db.Conn(ctx, r.db), id, false)
+}
+
+// ListStocktakes lista os inventários, dos mais recentes para os mais antigos
+func (r *stocktakeRepository) ListStocktakes(ctx context.Context, filter models.StocktakeFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := db.Conn(ctx, r.db).Model(&models.Stocktake{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.WarehouseID > 0 {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar inventários", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar inventários")
+	}
+
+	stocktakes := make([]models.Stocktake, 0)
+	if err := query.Preload("Warehouse").
+		Order("id DESC").
+		Limit(params.PageSize).
+		Offset(pagination.CalculateOffset(params.Page, params.PageSize)).
+		Find(&stocktakes).Error; err != nil {
+		r.logger.Error("erro ao listar inventários", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar inventários")
+	}
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, stocktakes), nil
+}
+
+// GetCountSheet monta a folha de contagem do inventário
+func (r *stocktakeRepository) GetCountSheet(ctx context.Context, id int) (*models.CountSheet, error) {
+	stocktake, err := findStocktake(db.Conn(ctx, r.db), id, false)
+	if err != nil {
+		return nil, err
+	}
+	sheet := models.BuildCountSheet(*stocktake, stocktake.Lines)
+	return &sheet, nil
+}
+
+// AddCount registra a quantidade contada de um produto
+func (r *stocktakeRepository) AddCount(ctx context.Context, id int, input models.CountInput) (*models.StocktakeCount, error) {
+	return r.addCount(ctx, id, input, models.CountSourceManual, "")
+}
+
+// ScanCount registra a contagem do produto identificado pelo código lido
+func (r *stocktakeRepository) ScanCount(ctx context.Context, id int, input models.ScanInput) (*models.StocktakeCount, error) {
+	code := strings.TrimSpace(input.Code)
+	productID, err := resolveScannedProduct(db.Conn(ctx, r.db), code)
+	if err != nil {
+		r.logger.Warn("leitura do inventário não identificada", zap.Error(err), zap.Int("id", id), zap.String("code", code))
+		return nil, err
+	}
+	return r.addCount(ctx, id, input.Count(productID), models.CountSourceScan, code)
+}
+
+// addCount registra a contagem na linha do produto. Produto encontrado no depósito sem estar no
+// inventário entra com saldo congelado zero, já que a abertura congela todo produto com saldo.
+func (r *stocktakeRepository) addCount(ctx context.Context, id int, input models.CountInput, source, code string) (*models.StocktakeCount, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	count := models.StocktakeCount{
+		StocktakeID: id,
+		Counter:     strings.TrimSpace(input.Counter),
+		Quantity:    input.Quantity,
+		Source:      source,
+		Code:        code,
+	}
+
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		stocktake, err := lockStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := stocktake.CheckCounting(); err != nil {
+			return err
+		}
+
+		var line models.StocktakeLine
+		err = tx.Where("stocktake_id = ? AND product_id = ?", id, input.ProductID).Take(&line).Error
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			lines, err := snapshotLines(tx, []int{input.ProductID})
+			if err != nil {
+				return err
+			}
+			line = lines[0]
+			line.StocktakeID = id
+			if err := tx.Create(&line).Error; err != nil {
+				return errors.WrapError(err, "falha ao incluir produto no inventário")
+			}
+		} else if err != nil {
+			return errors.WrapError(err, "falha ao buscar produto do inventário")
+		}
+
+		count.LineID = line.ID
+		if err := tx.Create(&count).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar contagem")
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("contagem do inventário rejeitada", zap.Error(err), zap.Int("id", id), zap.Int("product_id", input.ProductID))
+		return nil, err
+	}
+
+	r.logger.Info("contagem do inventário registrada", zap.Int("id", id), zap.Int("product_id", input.ProductID),
+		zap.String("counter", count.Counter), zap.Int("quantity", count.Quantity), zap.String("source", source))
+	return &count, nil
+}
+
+// DeleteCount remove uma contagem registrada por engano enquanto o inventário está em contagem
+func (r *stocktakeRepository) DeleteCount(ctx context.Context, id, countID int) error {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		stocktake, err := lockStocktake(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := stocktake.CheckCounting(); err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND stocktake_id = ?", countID, id).Delete(&models.StocktakeCount{})
+		if result.Error != nil {
+			return errors.WrapError(result.Error, "falha ao remover contagem")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrStocktakeCountNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("remoção de contagem rejeitada", zap.Error(err), zap.Int("id", id), zap.Int("count_id", countID))
+		return err
+	}
+	r.logger.Info("contagem do inventário removida", zap.Int("id", id), zap.Int("count_id", countID))
+	return nil
+}
+
+// GetVariances calcula o relatório de diferenças com as contagens registradas até o momento
+func (r *stocktakeRepository) GetVariances(ctx context.Context, id int) (*models.VarianceReport, error) {
+	conn := db.Conn(ctx, r.db)
+	stocktake, err := findStocktake(conn, id, false)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := findCounts(conn, id)
+	if err != nil {
+		return nil, err
+	}
+	report := models.BuildVarianceReport(*stocktake, stocktake.Lines, counts)
+	return &report, nil
+}
+
+// ApproveStocktake encerra a contagem e lança no depósito a diferença de cada produto. A diferença
+// é somada ao saldo atual, de modo que as movimentações feitas durante a contagem são preservadas.
+func (r *stocktakeRepository) ApproveStocktake(ctx context.Context, id int, approvedBy string, now time.Time) (*models.StocktakeApproval, error) {
+	var approval models.StocktakeApproval
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		stocktake, err := findStocktake(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if err := stocktake.CheckCounting(); err != nil {
+			return err
+		}
+		counts, err := findCounts(tx, id)
+		if err != nil {
+			return err
+		}
+
+		report := models.BuildVarianceReport(*stocktake, stocktake.Lines, counts)
+		movements := make([]models.StockMovement, 0)
+		for _, product := range report.Products {
+			if product.Variance == 0 {
+				continue
+			}
+			stock, err := lockStock(tx, stocktake.WarehouseID, product.ProductID)
+			if err != nil {
+				return err
+			}
+			movement := models.StockMovement{
+				WarehouseID:  stocktake.WarehouseID,
+				ProductID:    product.ProductID,
+				MovementType: models.MovementStocktake,
+				Quantity:     product.Variance,
+				StocktakeID:  &stocktake.ID,
+				Notes:        fmt.Sprintf("Inventário %s", stocktake.StocktakeNo),
+			}
+			if err := applyMovement(tx, stock, &movement); err != nil {
+				return err
+			}
+			movements = append(movements, movement)
+		}
+
+		stocktake.Status = models.StocktakeStatusApproved
+		stocktake.ApprovedBy = approvedBy
+		stocktake.ApprovedAt = &now
+		if err := tx.Model(stocktake).Updates(map[string]interface{}{
+			"status":      stocktake.Status,
+			"approved_by": stocktake.ApprovedBy,
+			"approved_at": now,
+		}).Error; err != nil {
+			return errors.WrapError(err, "falha ao aprovar inventário")
+		}
+		approval = models.StocktakeApproval{Stocktake: *stocktake, Movements: movements}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("aprovação do inventário rejeitada", zap.Error(err), zap.Int("id", id))
+		return nil, err
+	}
+
+	r.logger.Info("inventário aprovado", zap.Int("id", id), zap.Int("adjustments", len(approval.Movements)))
+	return &approval, nil
+}
+
+// CancelStocktake cancela o inventário em contagem sem alterar os saldos do depósito
+func (r *stocktakeRepository) CancelStocktake(ctx context.Context, id int, now time.Time) (*models.Stocktake, error) {
+	var stocktake *models.Stocktake
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if stocktake, err = findStocktake(tx, id, true); err != nil {
+			return err
+		}
+		if err := stocktake.CheckCounting(); err != nil {
+			return err
+		}
+		stocktake.Status = models.StocktakeStatusCancelled
+		stocktake.CancelledAt = &now
+		if err := tx.Model(stocktake).Updates(map[string]interface{}{
+			"status":       stocktake.Status,
+			"cancelled_at": now,
+		}).Error; err != nil {
+			return errors.WrapError(err, "falha ao cancelar inventário")
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("cancelamento do inventário rejeitado", zap.Error(err), zap.Int("id", id))
+		return nil, err
+	}
+
+	r.logger.Info("inventário cancelado", zap.Int("id", id))
+	return stocktake, nil
+}
+
+// lockStocktake bloqueia o inventário, serializando as contagens com a aprovação e o cancelamento
+func lockStocktake(tx *gorm.DB, id int) (*models.Stocktake, error) {
+	var stocktake models.Stocktake
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stocktake, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrStocktakeNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar inventário")
+	}
+	return &stocktake, nil
+}
+
+// findStocktake busca o inventário com o depósito e os produtos congelados
+func findStocktake(tx *gorm.DB, id int, lock bool) (*models.Stocktake, error) {
+	var stocktake *models.Stocktake
+	if lock {
+		var err error
+		if stocktake, err = lockStocktake(tx, id); err != nil {
+			return nil, err
+		}
+	} else {
+		stocktake = &models.Stocktake{}
+		if err := tx.First(stocktake, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.ErrStocktakeNotFound
+			}
+			return nil, errors.WrapError(err, "falha ao buscar inventário")
+		}
+	}
+
+	if err := tx.Where("stocktake_id = ?", id).Order("id ASC").Find(&stocktake.Lines).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar produtos do inventário")
+	}
+	var err error
+	if stocktake.Warehouse, err = findWarehouse(tx, stocktake.WarehouseID); err != nil {
+		return nil, err
+	}
+	return stocktake, nil
+}
+
+// findCounts busca as contagens do inventário na ordem de registro
+func findCounts(tx *gorm.DB, stocktakeID int) ([]models.StocktakeCount, error) {
+	var counts []models.StocktakeCount
+	if err := tx.Where("stocktake_id = ?", stocktakeID).Order("id ASC").Find(&counts).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar contagens do inventário")
+	}
+	return counts, nil
+}
+
+// snapshotLines monta as linhas do inventário com os dados de cada produto na abertura: nome, SKU,
+// código de barras, endereço de estoque e custo unitário
+func snapshotLines(tx *gorm.DB, productIDs []int) ([]models.StocktakeLine, error) {
+	var rows []productModels.Product
+	if err := tx.Select("id, name, sku, barcode, cost_price, bin_id").Where("id IN ?", productIDs).Find(&rows).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar produtos")
+	}
+	products := make(map[int]productModels.Product, len(rows))
+	binIDs := make([]int, 0)
+	for _, row := range rows {
+		products[row.ID] = row
+		if row.BinID != nil {
+			binIDs = append(binIDs, *row.BinID)
+		}
+	}
+	for _, id := range productIDs {
+		if _, ok := products[id]; !ok {
+			return nil, errors.ErrProductNotFound
+		}
+	}
+
+	binCodes := make(map[int]string, len(binIDs))
+	if len(binIDs) > 0 {
+		var bins []models.WarehouseBin
+		if err := tx.Select("id, code").Where("id IN ?", binIDs).Find(&bins).Error; err != nil {
+			return nil, errors.WrapError(err, "falha ao buscar endereços de estoque")
+		}
+		for _, bin := range bins {
+			binCodes[bin.ID] = bin.Code
+		}
+	}
+	unitCosts, err := productUnitCosts(tx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]models.StocktakeLine, 0, len(productIDs))
+	for _, id := range productIDs {
+		product := products[id]
+		line := models.StocktakeLine{
+			ProductID:   id,
+			ProductName: product.Name,
+			ProductCode: product.SKU,
+			Barcode:     product.Barcode,
+			UnitCost:    unitCosts[id],
+		}
+		if product.BinID != nil {
+			line.BinCode = binCodes[*product.BinID]
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// resolveScannedProduct identifica o produto lido como nas etiquetas: código de barras, SKU ou
+// PRD-<id> do produto e, por último, SKU ou código de barras da variante
+func resolveScannedProduct(tx *gorm.DB, code string) (int, error) {
+	if code == "" {
+		return 0, errors.ErrScanCodeNotFound
+	}
+	var product productModels.Product
+	err := tx.Select("id").Where("barcode = ? OR sku = ?", code, code).Take(&product).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		if id, ok := labels.ParseProductCode(code); ok {
+			err = tx.Select("id").Where("id = ?", id).Take(&product).Error
+		}
+	}
+	if err == nil {
+		return product.ID, nil
+	}
+	if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, errors.WrapError(err, "falha ao buscar produto pelo código")
+	}
+
+	var variant productModels.ProductVariant
+	err = tx.Select("id, product_id").Where("sku = ? OR barcode = ?", code, code).Take(&variant).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, errors.ErrScanCodeNotFound
+	}
+	if err != nil {
+		return 0, errors.WrapError(err, "falha ao buscar variante pelo código")
+	}
+	return variant.ProductID, nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TraceabilityRepository define as operações de rastreabilidade por número de série e lote
+type TraceabilityRepository interface {
+	CaptureDeliverySerials(ctx context.Context, deliveryID int, lines []models.ShipmentLine) ([]models.SerialNumber, error)
+	GetSerialTrace(ctx context.Context, serial string) ([]models.SerialTrace, error)
+	GetLotTrace(ctx context.Context, lotNumber string) (*models.LotTrace, error)
+	GetExpiringLots(ctx context.Context, until time.Time) ([]models.LotReceipt, error)
+}
+
+type traceabilityRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTraceabilityRepository cria uma nova instância do repositório
+func NewTraceabilityRepository() (TraceabilityRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &traceabilityRepository{
+		db:     gormDB,
+		logger: logger.WithModule("traceability_repository"),
+	}, nil
+}
+
+// CaptureDeliverySerials registra o lote e os números de série dos itens entregues. Números
+// recebidos por pedido de compra passam a entregues; os desconhecidos são cadastrados já na saída.
+func (r *traceabilityRepository) CaptureDeliverySerials(ctx context.Context, deliveryID int, lines []models.ShipmentLine) ([]models.SerialNumber, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var delivery sales.Delivery
+	if err := tx.Preload("Items").First(&delivery, deliveryID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar delivery")
+	}
+	if delivery.SalesOrderID == 0 {
+		tx.Rollback()
+		return nil, errors.ErrDeliveryNotOutbound
+	}
+
+	items := make(map[int]sales.DeliveryItem, len(delivery.Items))
+	for _, item := range delivery.Items {
+		items[item.ID] = item
+	}
+
+	now := time.Now()
+	for _, line := range lines {
+		item, ok := items[line.DeliveryItemID]
+		if !ok {
+			tx.Rollback()
+			return nil, errors.ErrDeliveryItemNotFound
+		}
+		serials, err := models.NormalizeSerials(line.Serials, item.Quantity)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		lotNumber := strings.TrimSpace(line.LotNumber)
+		if lotNumber != "" {
+			if err := tx.Model(&sales.DeliveryItem{}).Where("id = ?", item.ID).
+				Update("lot_number", lotNumber).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.WrapError(err, "falha ao registrar lote do item")
+			}
+		}
+		if len(serials) == 0 {
+			continue
+		}
+
+		// Os números já registrados no item contam para o limite da quantidade entregue
+		var captured int64
+		if err := tx.Model(&models.SerialNumber{}).
+			Where("delivery_item_id = ? AND serial_number NOT IN ?", item.ID, serials).
+			Count(&captured).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao contar números de série do item")
+		}
+		if int(captured)+len(serials) > item.Quantity {
+			tx.Rollback()
+			return nil, errors.ErrTooManySerials
+		}
+
+		for _, serial := range serials {
+			if err := r.shipSerial(tx, delivery, item, serial, lotNumber, now); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+
+	var shipped []models.SerialNumber
+	if err := tx.Where("delivery_id = ?", deliveryID).
+		Order("delivery_item_id ASC, serial_number ASC").
+		Find(&shipped).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar números de série da entrega")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("números de série da entrega registrados",
+		zap.Int("delivery_id", deliveryID), zap.Int("serials", len(shipped)))
+	return shipped, nil
+}
+
+// shipSerial marca o número de série como entregue no item; repetir a captura no mesmo item
+// não altera nada
+func (r *traceabilityRepository) shipSerial(tx *gorm.DB, delivery sales.Delivery, item sales.DeliveryItem, serial, lotNumber string, now time.Time) error {
+	deliveryID, itemID := delivery.ID, item.ID
+
+	var record models.SerialNumber
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND serial_number = ?", item.ProductID, serial).
+		Take(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		record = models.SerialNumber{
+			CompanyID:      delivery.CompanyID,
+			ProductID:      item.ProductID,
+			SerialNumber:   serial,
+			LotNumber:      lotNumber,
+			Status:         models.SerialStatusShipped,
+			DeliveryID:     &deliveryID,
+			DeliveryItemID: &itemID,
+			ShippedAt:      &now,
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			r.logger.Error("erro ao registrar número de série", zap.Error(err), zap.String("serial_number", serial))
+			return errors.WrapError(err, "falha ao registrar número de série")
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.WrapError(err, "falha ao buscar número de série")
+	}
+
+	if record.Status == models.SerialStatusShipped {
+		if record.DeliveryItemID != nil && *record.DeliveryItemID == itemID {
+			return nil
+		}
+		return errors.ErrSerialAlreadyShipped
+	}
+
+	updates := map[string]interface{}{
+		"status":           models.SerialStatusShipped,
+		"delivery_id":      deliveryID,
+		"delivery_item_id": itemID,
+		"shipped_at":       now,
+	}
+	if record.LotNumber == "" && lotNumber != "" {
+		updates["lot_number"] = lotNumber
+	}
+	if err := tx.Model(&models.SerialNumber{}).Where("id = ?", record.ID).Updates(updates).Error; err != nil {
+		r.logger.Error("erro ao baixar número de série", zap.Error(err), zap.String("serial_number", serial))
+		return errors.WrapError(err, "falha ao baixar número de série")
+	}
+	return nil
+}
+
+// GetSerialTrace retorna a rastreabilidade do número de série: o produto, o pedido de compra
+// que o recebeu, a entrega que o levou, as faturas do pedido de venda e o cliente. O mesmo número
+// pode existir em produtos diferentes, por isso o retorno é uma lista.
+func (r *traceabilityRepository) GetSerialTrace(ctx context.Context, serial string) ([]models.SerialTrace, error) {
+	var rows []models.SerialTraceRow
+	if err := db.Conn(ctx, r.db).Table("serial_numbers s").
+		Select(`s.serial_number, s.status, s.lot_number, s.product_id, p.name AS product_name, p.sku AS product_sku,
+			s.purchase_order_id, po.po_no, po.contact_id AS supplier_id, sup.name AS supplier_name, s.received_at,
+			s.delivery_id, d.delivery_no, d.delivery_date, s.shipped_at,
+			so.id AS sales_order_id, so.so_no, so.contact_id AS customer_id, cus.name AS customer_name`).
+		Joins("JOIN products p ON p.id = s.product_id").
+		Joins("LEFT JOIN purchase_orders po ON po.id = s.purchase_order_id").
+		Joins("LEFT JOIN contacts sup ON sup.id = po.contact_id").
+		Joins("LEFT JOIN deliveries d ON d.id = s.delivery_id").
+		Joins("LEFT JOIN sales_orders so ON so.id = d.sales_order_id").
+		Joins("LEFT JOIN contacts cus ON cus.id = so.contact_id").
+		Scopes(tenant.Scope(ctx, "s")).
+		Where("s.serial_number = ?", serial).
+		Order("s.id ASC").
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("erro ao rastrear número de série", zap.Error(err), zap.String("serial_number", serial))
+		return nil, errors.WrapError(err, "falha ao rastrear número de série")
+	}
+	if len(rows) == 0 {
+		return nil, errors.ErrSerialNotFound
+	}
+
+	orderIDs := make([]int, 0, len(rows))
+	for _, row := range rows {
+		if row.SalesOrderID != nil {
+			orderIDs = append(orderIDs, *row.SalesOrderID)
+		}
+	}
+	invoices, err := r.invoicesBySalesOrder(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	traces := make([]models.SerialTrace, 0, len(rows))
+	for _, row := range rows {
+		var orderInvoices []models.TraceInvoice
+		if row.SalesOrderID != nil {
+			orderInvoices = invoices[*row.SalesOrderID]
+		}
+		traces = append(traces, models.NewSerialTrace(row, orderInvoices))
+	}
+	return traces, nil
+}
+
+// invoicesBySalesOrder busca as faturas não canceladas dos pedidos de venda
+func (r *traceabilityRepository) invoicesBySalesOrder(ctx context.Context, orderIDs []int) (map[int][]models.TraceInvoice, error) {
+	result := make(map[int][]models.TraceInvoice, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return result, nil
+	}
+
+	var invoices []sales.Invoice
+	if err := db.Conn(ctx, r.db).
+		Select("id", "invoice_no", "sales_order_id", "issue_date", "status").
+		Where("sales_order_id IN ? AND status <> ?", orderIDs, sales.InvoiceStatusCancelled).
+		Order("issue_date ASC, id ASC").
+		Find(&invoices).Error; err != nil {
+		r.logger.Error("erro ao buscar faturas do pedido", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar faturas do pedido")
+	}
+
+	for _, invoice := range invoices {
+		result[invoice.SalesOrderID] = append(result[invoice.SalesOrderID], models.TraceInvoice{
+			ID:        invoice.ID,
+			InvoiceNo: invoice.InvoiceNo,
+			IssueDate: invoice.IssueDate,
+			Status:    invoice.Status,
+		})
+	}
+	return result, nil
+}
+
+// GetLotTrace retorna as entradas (recebimentos) e as saídas (itens entregues) do lote
+func (r *traceabilityRepository) GetLotTrace(ctx context.Context, lotNumber string) (*models.LotTrace, error) {
+	trace := &models.LotTrace{LotNumber: lotNumber}
+
+	if err := r.lotReceipts(ctx).
+		Where("l.lot_number = ?", lotNumber).
+		Order("l.received_at ASC, l.id ASC").
+		Scan(&trace.Receipts).Error; err != nil {
+		r.logger.Error("erro ao buscar entradas do lote", zap.Error(err), zap.String("lot_number", lotNumber))
+		return nil, errors.WrapError(err, "falha ao buscar entradas do lote")
+	}
+
+	if err := db.Conn(ctx, r.db).Table("delivery_items di").
+		Select(`di.id AS delivery_item_id, d.id AS delivery_id, d.delivery_no, d.delivery_date, di.product_id,
+			di.quantity, d.sales_order_id, so.so_no, so.contact_id AS customer_id, c.name AS customer_name`).
+		Joins("JOIN deliveries d ON d.id = di.delivery_id AND d.deleted_at IS NULL").
+		Joins("LEFT JOIN sales_orders so ON so.id = d.sales_order_id").
+		Joins("LEFT JOIN contacts c ON c.id = so.contact_id").
+		Scopes(tenant.Scope(ctx, "d")).
+		Where("di.lot_number = ?", lotNumber).
+		Order("d.delivery_date ASC, di.id ASC").
+		Scan(&trace.Shipments).Error; err != nil {
+		r.logger.Error("erro ao buscar saídas do lote", zap.Error(err), zap.String("lot_number", lotNumber))
+		return nil, errors.WrapError(err, "falha ao buscar saídas do lote")
+	}
+
+	if len(trace.Receipts) == 0 && len(trace.Shipments) == 0 {
+		return nil, errors.ErrLotNotFound
+	}
+	return trace, nil
+}
+
+// GetExpiringLots lista as entradas de lote com saldo em estoque e validade até a data informada,
+// incluindo as já vencidas
+func (r *traceabilityRepository) GetExpiringLots(ctx context.Context, until time.Time) ([]models.LotReceipt, error) {
+	receipts := []models.LotReceipt{}
+	if err := r.lotReceipts(ctx).
+		Where("l.expiry_date IS NOT NULL AND l.expiry_date <= ? AND l.remaining_quantity > 0", until).
+		Order("l.expiry_date ASC, l.id ASC").
+		Scan(&receipts).Error; err != nil {
+		r.logger.Error("erro ao buscar lotes a vencer", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar lotes a vencer")
+	}
+	return receipts, nil
+}
+
+// lotReceipts monta a consulta das camadas de custo com lote dos produtos da empresa
+func (r *traceabilityRepository) lotReceipts(ctx context.Context) *gorm.DB {
+	return db.Conn(ctx, r.db).Table("cost_layers l").
+		Select(`l.id AS layer_id, l.lot_number, l.product_id, p.name AS product_name, l.purchase_order_id,
+			po.po_no, l.received_at, l.expiry_date, l.quantity, l.remaining_quantity`).
+		Joins("JOIN products p ON p.id = l.product_id").
+		Joins("LEFT JOIN purchase_orders po ON po.id = l.purchase_order_id").
+		Scopes(tenant.Scope(ctx, "p")).
+		Where("l.lot_number IS NOT NULL AND l.lot_number <> ''")
+}
@@ -0,0 +1,680 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	productModels "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TransferRepository define as operações dos depósitos, dos saldos e movimentações por depósito e
+// das transferências entre depósitos
+type TransferRepository interface {
+	ListWarehouses(ctx context.Context) ([]models.Warehouse, error)
+	GetWarehouse(ctx context.Context, id int) (*models.Warehouse, error)
+	CreateWarehouse(ctx context.Context, input models.WarehouseInput) (*models.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, id int, input models.WarehouseInput) (*models.Warehouse, error)
+
+	ListStock(ctx context.Context, warehouseID int) ([]models.StockLine, error)
+	CountStock(ctx context.Context, warehouseID int, count models.StockCount) ([]models.StockMovement, error)
+	ListMovements(ctx context.Context, warehouseID int, filter models.MovementFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+
+	CreateTransfer(ctx context.Context, input models.TransferInput, items []models.TransferItemInput, now time.Time) (*models.StockTransfer, error)
+	GetTransfer(ctx context.Context, id int) (*models.StockTransfer, error)
+	ListTransfers(ctx context.Context, filter models.TransferFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	ShipTransfer(ctx context.Context, id int, input models.ShipTransferInput, now time.Time) (*models.StockTransfer, error)
+	ReceiveTransfer(ctx context.Context, id int, now time.Time) (*models.StockTransfer, error)
+	CancelTransfer(ctx context.Context, id int, now time.Time) (*models.StockTransfer, error)
+}
+
+type transferRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTransferRepository cria uma nova instância do repositório
+func NewTransferRepository() (TransferRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &transferRepository{
+		db:     gormDB,
+		logger: logger.WithModule("transfer_repository"),
+	}, nil
+}
+
+// ListWarehouses lista os depósitos por código
+func (r *transferRepository) ListWarehouses(ctx context.Context) ([]models.Warehouse, error) {
+	var warehouses []models.Warehouse
+	if err := db.Conn(ctx, r.db).Order("code ASC").Find(&warehouses).Error; err != nil {
+		r.logger.Error("erro ao listar depósitos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar depósitos")
+	}
+	return warehouses, nil
+}
+
+// GetWarehouse busca um depósito pelo ID
+func (r *transferRepository) GetWarehouse(ctx context.Context, id int) (*models.Warehouse, error) {
+	return findWarehouse(db.Conn(ctx, r.db), id)
+}
+
+// CreateWarehouse cadastra um depósito com código único na empresa
+func (r *transferRepository) CreateWarehouse(ctx context.Context, input models.WarehouseInput) (*models.Warehouse, error) {
+	warehouse := models.Warehouse{
+		Code:      models.NormalizeWarehouseCode(input.Code),
+		Name:      input.Name,
+		Address:   input.Address,
+		ContactID: input.ContactID,
+		Active:    input.Active == nil || *input.Active,
+	}
+	conn := db.Conn(ctx, r.db)
+	if err := r.checkWarehouse(conn, warehouse, 0); err != nil {
+		return nil, err
+	}
+
+	if err := conn.Create(&warehouse).Error; err != nil {
+		r.logger.Error("erro ao criar depósito", zap.Error(err), zap.String("code", warehouse.Code))
+		return nil, errors.WrapError(err, "falha ao criar depósito")
+	}
+
+	r.logger.Info("depósito criado", zap.Int("id", warehouse.ID), zap.String("code", warehouse.Code))
+	return &warehouse, nil
+}
+
+// UpdateWarehouse altera o cadastro do depósito; os saldos e as movimentações não mudam
+func (r *transferRepository) UpdateWarehouse(ctx context.Context, id int, input models.WarehouseInput) (*models.Warehouse, error) {
+	conn := db.Conn(ctx, r.db)
+	warehouse, err := findWarehouse(conn, id)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse.Code = models.NormalizeWarehouseCode(input.Code)
+	warehouse.Name = input.Name
+	warehouse.Address = input.Address
+	warehouse.ContactID = input.ContactID
+	if input.Active != nil {
+		warehouse.Active = *input.Active
+	}
+	if err := r.checkWarehouse(conn, *warehouse, id); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"code":       warehouse.Code,
+		"name":       warehouse.Name,
+		"address":    warehouse.Address,
+		"contact_id": warehouse.ContactID,
+		"active":     warehouse.Active,
+	}
+	if err := conn.Model(&models.Warehouse{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		r.logger.Error("erro ao atualizar depósito", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar depósito")
+	}
+	return findWarehouse(conn, id)
+}
+
+// checkWarehouse confere o código único e o contato da filial do depósito
+func (r *transferRepository) checkWarehouse(conn *gorm.DB, warehouse models.Warehouse, exceptID int) error {
+	var count int64
+	if err := conn.Model(&models.Warehouse{}).
+		Where("code = ? AND id <> ?", warehouse.Code, exceptID).
+		Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar código do depósito")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateWarehouseCode
+	}
+
+	if warehouse.ContactID != nil {
+		if err := conn.Model(&contact.Contact{}).Where("id = ?", *warehouse.ContactID).Count(&count).Error; err != nil {
+			return errors.WrapError(err, "falha ao verificar contato do depósito")
+		}
+		if count == 0 {
+			return errors.ErrContactNotFound
+		}
+	}
+	return nil
+}
+
+// ListStock lista os saldos do depósito diferentes de zero, por nome do produto
+func (r *transferRepository) ListStock(ctx context.Context, warehouseID int) ([]models.StockLine, error) {
+	conn := db.Conn(ctx, r.db)
+	if _, err := findWarehouse(conn, warehouseID); err != nil {
+		return nil, err
+	}
+
+	lines := make([]models.StockLine, 0)
+	if err := conn.Model(&models.WarehouseStock{}).
+		Select("warehouse_stock.product_id, products.name AS product_name, products.sku AS product_code, warehouse_stock.quantity").
+		Joins("JOIN products ON products.id = warehouse_stock.product_id").
+		Where("warehouse_stock.warehouse_id = ? AND warehouse_stock.quantity <> 0", warehouseID).
+		Order("products.name ASC, warehouse_stock.product_id ASC").
+		Scan(&lines).Error; err != nil {
+		r.logger.Error("erro ao listar saldos do depósito", zap.Error(err), zap.Int("warehouse_id", warehouseID))
+		return nil, errors.WrapError(err, "falha ao listar saldos do depósito")
+	}
+	return lines, nil
+}
+
+// CountStock grava a contagem do depósito: cada produto contado recebe um ajuste pela diferença
+// entre a quantidade contada e o saldo (produtos sem diferença ficam sem movimentação)
+func (r *transferRepository) CountStock(ctx context.Context, warehouseID int, count models.StockCount) ([]models.StockMovement, error) {
+	movements := make([]models.StockMovement, 0, len(count.Items))
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if _, err := findWarehouse(tx, warehouseID); err != nil {
+			return err
+		}
+		productIDs := make([]int, 0, len(count.Items))
+		for _, line := range count.Items {
+			productIDs = append(productIDs, line.ProductID)
+		}
+		if _, err := loadProducts(tx, productIDs); err != nil {
+			return err
+		}
+
+		for _, line := range count.Items {
+			stock, err := lockStock(tx, warehouseID, line.ProductID)
+			if err != nil {
+				return err
+			}
+			if line.Quantity == stock.Quantity {
+				continue
+			}
+			movement := models.StockMovement{
+				WarehouseID:  warehouseID,
+				ProductID:    line.ProductID,
+				MovementType: models.MovementAdjustment,
+				Quantity:     line.Quantity - stock.Quantity,
+				Notes:        count.Notes,
+			}
+			if err := applyMovement(tx, stock, &movement); err != nil {
+				return err
+			}
+			movements = append(movements, movement)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("contagem do depósito rejeitada", zap.Error(err), zap.Int("warehouse_id", warehouseID))
+		return nil, err
+	}
+
+	r.logger.Info("contagem do depósito registrada",
+		zap.Int("warehouse_id", warehouseID), zap.Int("adjustments", len(movements)))
+	return movements, nil
+}
+
+// ListMovements lista o histórico de movimentações do depósito, as mais recentes primeiro
+func (r *transferRepository) ListMovements(ctx context.Context, warehouseID int, filter models.MovementFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	conn := db.Conn(ctx, r.db)
+	if _, err := findWarehouse(conn, warehouseID); err != nil {
+		return nil, err
+	}
+
+	query := conn.Model(&models.StockMovement{}).Where("warehouse_id = ?", warehouseID)
+	if filter.ProductID > 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.MovementType != "" {
+		query = query.Where("movement_type = ?", filter.MovementType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar movimentações", zap.Error(err), zap.Int("warehouse_id", warehouseID))
+		return nil, errors.WrapError(err, "falha ao contar movimentações")
+	}
+
+	movements := make([]models.StockMovement, 0)
+	if err := query.Order("created_at DESC, id DESC").
+		Limit(params.PageSize).
+		Offset(pagination.CalculateOffset(params.Page, params.PageSize)).
+		Find(&movements).Error; err != nil {
+		r.logger.Error("erro ao listar movimentações", zap.Error(err), zap.Int("warehouse_id", warehouseID))
+		return nil, errors.WrapError(err, "falha ao listar movimentações")
+	}
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, movements), nil
+}
+
+// CreateTransfer registra a solicitação de transferência entre dois depósitos ativos. Com nota
+// interna, o depósito de destino precisa ter o contato da filial.
+func (r *transferRepository) CreateTransfer(ctx context.Context, input models.TransferInput, items []models.TransferItemInput, now time.Time) (*models.StockTransfer, error) {
+	transfer := models.StockTransfer{
+		SourceWarehouseID:      input.SourceWarehouseID,
+		DestinationWarehouseID: input.DestinationWarehouseID,
+		Status:                 models.TransferStatusRequested,
+		InternalInvoice:        input.InternalInvoice,
+		Notes:                  input.Notes,
+		RequestedBy:            input.RequestedBy,
+		RequestedAt:            now,
+	}
+
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		source, err := findWarehouse(tx, input.SourceWarehouseID)
+		if err != nil {
+			return err
+		}
+		destination, err := findWarehouse(tx, input.DestinationWarehouseID)
+		if err != nil {
+			return err
+		}
+		if !source.Active || !destination.Active {
+			return fmt.Errorf("%w: os dois depósitos devem estar ativos", errors.ErrInvalidStockTransfer)
+		}
+		if input.InternalInvoice && destination.ContactID == nil {
+			return errors.ErrWarehouseWithoutContact
+		}
+
+		productIDs := make([]int, 0, len(items))
+		for _, item := range items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		products, err := loadProducts(tx, productIDs)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			product := products[item.ProductID]
+			transfer.Items = append(transfer.Items, models.StockTransferItem{
+				ProductID:   item.ProductID,
+				ProductName: product.Name,
+				ProductCode: product.SKU,
+				Quantity:    item.Quantity,
+			})
+		}
+
+		transfer.TransferNo = salesRepository.NextDocumentNumber(tx, &models.StockTransfer{}, "TRF")
+		if err := tx.Omit(clause.Associations).Create(&transfer).Error; err != nil {
+			return errors.WrapError(err, "falha ao criar transferência")
+		}
+		for i := range transfer.Items {
+			transfer.Items[i].StockTransferID = transfer.ID
+		}
+		if err := tx.Create(&transfer.Items).Error; err != nil {
+			return errors.WrapError(err, "falha ao criar itens da transferência")
+		}
+		transfer.SourceWarehouse = source
+		transfer.DestinationWarehouse = destination
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("transferência rejeitada", zap.Error(err),
+			zap.Int("source_warehouse_id", input.SourceWarehouseID), zap.Int("destination_warehouse_id", input.DestinationWarehouseID))
+		return nil, err
+	}
+
+	r.logger.Info("transferência solicitada", zap.Int("id", transfer.ID), zap.String("transfer_no", transfer.TransferNo))
+	return &transfer, nil
+}
+
+// GetTransfer busca a transferência com os itens e os depósitos
+func (r *transferRepository) GetTransfer(ctx context.Context, id int) (*models.StockTransfer, error) {
+	return findTransfer(db.Conn(ctx, r.db), id, false)
+}
+
+// ListTransfers lista as transferências, as mais recentes primeiro; o filtro de depósito traz as
+// que saem dele ou chegam nele
+func (r *transferRepository) ListTransfers(ctx context.Context, filter models.TransferFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := db.Conn(ctx, r.db).Model(&models.StockTransfer{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.WarehouseID > 0 {
+		query = query.Where("source_warehouse_id = ? OR destination_warehouse_id = ?", filter.WarehouseID, filter.WarehouseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar transferências", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar transferências")
+	}
+
+	transfers := make([]models.StockTransfer, 0)
+	if err := query.Preload("SourceWarehouse").Preload("DestinationWarehouse").
+		Order("id DESC").
+		Limit(params.PageSize).
+		Offset(pagination.CalculateOffset(params.Page, params.PageSize)).
+		Find(&transfers).Error; err != nil {
+		r.logger.Error("erro ao listar transferências", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar transferências")
+	}
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, transfers), nil
+}
+
+// ShipTransfer envia a transferência: baixa o saldo da origem, gera a entrega de saída (enviada),
+// a entrega de entrada (pendente) e, se pedida, a nota interna em rascunho para a filial de destino
+func (r *transferRepository) ShipTransfer(ctx context.Context, id int, input models.ShipTransferInput, now time.Time) (*models.StockTransfer, error) {
+	var transfer *models.StockTransfer
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = findTransfer(tx, id, true); err != nil {
+			return err
+		}
+		if err := models.CheckTransferTransition(transfer.Status, models.TransferStatusShipped); err != nil {
+			return err
+		}
+
+		outgoing, incoming := models.TransferDeliveries(*transfer, *transfer.DestinationWarehouse, input, now)
+		if err := createTransferDelivery(tx, &outgoing); err != nil {
+			return err
+		}
+		if err := createTransferDelivery(tx, &incoming); err != nil {
+			return err
+		}
+
+		for _, item := range transfer.Items {
+			stock, err := lockStock(tx, transfer.SourceWarehouseID, item.ProductID)
+			if err != nil {
+				return err
+			}
+			movement := models.StockMovement{
+				WarehouseID:     transfer.SourceWarehouseID,
+				ProductID:       item.ProductID,
+				MovementType:    models.MovementTransferOut,
+				Quantity:        -item.Quantity,
+				StockTransferID: &transfer.ID,
+				DeliveryID:      &outgoing.ID,
+				Notes:           transfer.Reference(),
+			}
+			if err := applyMovement(tx, stock, &movement); err != nil {
+				return fmt.Errorf("%w: %s (SKU %s) tem %d no depósito %s",
+					err, item.ProductName, item.ProductCode, stock.Quantity, transfer.SourceWarehouse.Code)
+			}
+		}
+
+		updates := map[string]interface{}{
+			"status":               models.TransferStatusShipped,
+			"shipped_at":           now,
+			"outgoing_delivery_id": outgoing.ID,
+			"incoming_delivery_id": incoming.ID,
+		}
+		if transfer.InternalInvoice {
+			invoiceID, err := createTransferInvoice(tx, *transfer, now)
+			if err != nil {
+				return err
+			}
+			updates["invoice_id"] = invoiceID
+		}
+		if err := tx.Model(&models.StockTransfer{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return errors.WrapError(err, "falha ao atualizar transferência")
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("envio da transferência rejeitado", zap.Error(err), zap.Int("id", id))
+		return nil, err
+	}
+
+	r.logger.Info("transferência enviada", zap.Int("id", id), zap.String("transfer_no", transfer.TransferNo))
+	return r.GetTransfer(ctx, id)
+}
+
+// ReceiveTransfer recebe a transferência no destino: dá entrada no saldo do depósito e conclui as
+// duas entregas
+func (r *transferRepository) ReceiveTransfer(ctx context.Context, id int, now time.Time) (*models.StockTransfer, error) {
+	var transfer *models.StockTransfer
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = findTransfer(tx, id, true); err != nil {
+			return err
+		}
+		if err := models.CheckTransferTransition(transfer.Status, models.TransferStatusReceived); err != nil {
+			return err
+		}
+
+		for _, item := range transfer.Items {
+			stock, err := lockStock(tx, transfer.DestinationWarehouseID, item.ProductID)
+			if err != nil {
+				return err
+			}
+			movement := models.StockMovement{
+				WarehouseID:     transfer.DestinationWarehouseID,
+				ProductID:       item.ProductID,
+				MovementType:    models.MovementTransferIn,
+				Quantity:        item.Quantity,
+				StockTransferID: &transfer.ID,
+				DeliveryID:      transfer.IncomingDeliveryID,
+				Notes:           transfer.Reference(),
+			}
+			if err := applyMovement(tx, stock, &movement); err != nil {
+				return err
+			}
+		}
+
+		deliveryIDs := make([]int, 0, 2)
+		for _, deliveryID := range []*int{transfer.OutgoingDeliveryID, transfer.IncomingDeliveryID} {
+			if deliveryID != nil {
+				deliveryIDs = append(deliveryIDs, *deliveryID)
+			}
+		}
+		if err := tx.Model(&sales.Delivery{}).Where("id IN ?", deliveryIDs).
+			Updates(map[string]interface{}{"status": sales.DeliveryStatusDelivered, "received_date": now}).Error; err != nil {
+			return errors.WrapError(err, "falha ao concluir entregas da transferência")
+		}
+		if transfer.IncomingDeliveryID != nil {
+			if err := tx.Model(&sales.DeliveryItem{}).Where("delivery_id = ?", *transfer.IncomingDeliveryID).
+				Update("received_qty", gorm.Expr("quantity")).Error; err != nil {
+				return errors.WrapError(err, "falha ao registrar itens recebidos")
+			}
+		}
+
+		if err := tx.Model(&models.StockTransfer{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"status": models.TransferStatusReceived, "received_at": now}).Error; err != nil {
+			return errors.WrapError(err, "falha ao atualizar transferência")
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("recebimento da transferência rejeitado", zap.Error(err), zap.Int("id", id))
+		return nil, err
+	}
+
+	r.logger.Info("transferência recebida", zap.Int("id", id), zap.String("transfer_no", transfer.TransferNo))
+	return r.GetTransfer(ctx, id)
+}
+
+// CancelTransfer cancela a transferência ainda não enviada
+func (r *transferRepository) CancelTransfer(ctx context.Context, id int, now time.Time) (*models.StockTransfer, error) {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		transfer, err := findTransfer(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if err := models.CheckTransferTransition(transfer.Status, models.TransferStatusCancelled); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.StockTransfer{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"status": models.TransferStatusCancelled, "cancelled_at": now}).Error; err != nil {
+			return errors.WrapError(err, "falha ao cancelar transferência")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("transferência cancelada", zap.Int("id", id))
+	return r.GetTransfer(ctx, id)
+}
+
+// findWarehouse busca o depósito pelo ID
+func findWarehouse(tx *gorm.DB, id int) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	if err := tx.First(&warehouse, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrWarehouseNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar depósito")
+	}
+	return &warehouse, nil
+}
+
+// findTransfer busca a transferência com os itens e os depósitos; com lock, a linha da
+// transferência fica bloqueada até o fim da transação
+func findTransfer(tx *gorm.DB, id int, lock bool) (*models.StockTransfer, error) {
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var transfer models.StockTransfer
+	if err := query.First(&transfer, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrStockTransferNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar transferência")
+	}
+
+	if err := tx.Where("stock_transfer_id = ?", id).Order("id ASC").Find(&transfer.Items).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar itens da transferência")
+	}
+	var err error
+	if transfer.SourceWarehouse, err = findWarehouse(tx, transfer.SourceWarehouseID); err != nil {
+		return nil, err
+	}
+	if transfer.DestinationWarehouse, err = findWarehouse(tx, transfer.DestinationWarehouseID); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// loadProducts busca o nome, o SKU e o custo dos produtos; um produto inexistente devolve
+// ErrProductNotFound
+func loadProducts(tx *gorm.DB, productIDs []int) (map[int]productModels.Product, error) {
+	var rows []productModels.Product
+	if err := tx.Select("id, name, sku, cost_price").Where("id IN ?", productIDs).Find(&rows).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar produtos")
+	}
+	products := make(map[int]productModels.Product, len(rows))
+	for _, row := range rows {
+		products[row.ID] = row
+	}
+	for _, id := range productIDs {
+		if _, ok := products[id]; !ok {
+			return nil, errors.ErrProductNotFound
+		}
+	}
+	return products, nil
+}
+
+// lockStock bloqueia o saldo do produto no depósito até o fim da transação, criando-o zerado se
+// o produto ainda não tem saldo ali
+func lockStock(tx *gorm.DB, warehouseID, productID int) (*models.WarehouseStock, error) {
+	stock := models.WarehouseStock{WarehouseID: warehouseID, ProductID: productID}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&stock).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao criar saldo do depósito")
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND product_id = ?", warehouseID, productID).
+		First(&stock).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar saldo do depósito")
+	}
+	return &stock, nil
+}
+
+// applyMovement grava a movimentação e o novo saldo do depósito
+func applyMovement(tx *gorm.DB, stock *models.WarehouseStock, movement *models.StockMovement) error {
+	balance, err := models.NextBalance(stock.Quantity, movement.Quantity)
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&models.WarehouseStock{}).
+		Where("warehouse_id = ? AND product_id = ?", stock.WarehouseID, stock.ProductID).
+		Update("quantity", balance).Error; err != nil {
+		return errors.WrapError(err, "falha ao atualizar saldo do depósito")
+	}
+	movement.BalanceAfter = balance
+	if err := tx.Create(movement).Error; err != nil {
+		return errors.WrapError(err, "falha ao registrar movimentação de estoque")
+	}
+	return nil
+}
+
+// createTransferDelivery grava a entrega da transferência, sem pedido de compra nem de venda
+func createTransferDelivery(tx *gorm.DB, delivery *sales.Delivery) error {
+	delivery.DeliveryNo = salesRepository.NextDocumentNumber(tx, &sales.Delivery{}, "DLV")
+	if err := tx.Omit(clause.Associations, "PurchaseOrderID", "SalesOrderID").Create(delivery).Error; err != nil {
+		return errors.WrapError(err, "falha ao criar entrega da transferência")
+	}
+	for i := range delivery.Items {
+		delivery.Items[i].DeliveryID = delivery.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&delivery.Items).Error; err != nil {
+		return errors.WrapError(err, "falha ao criar itens da entrega da transferência")
+	}
+	return nil
+}
+
+// createTransferInvoice grava a nota interna da transferência pelo custo dos produtos: o custo
+// médio do custeio ou, sem ele, o preço de custo do cadastro
+func createTransferInvoice(tx *gorm.DB, transfer models.StockTransfer, now time.Time) (int, error) {
+	if transfer.DestinationWarehouse.ContactID == nil {
+		return 0, errors.ErrWarehouseWithoutContact
+	}
+
+	productIDs := make([]int, 0, len(transfer.Items))
+	for _, item := range transfer.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	products, err := loadProducts(tx, productIDs)
+	if err != nil {
+		return 0, err
+	}
+	unitCosts, err := productUnitCosts(tx, products)
+	if err != nil {
+		return 0, err
+	}
+
+	invoice := models.TransferInvoice(transfer, *transfer.DestinationWarehouse.ContactID, unitCosts, now)
+	invoice.InvoiceNo = salesRepository.NextDocumentNumber(tx, &sales.Invoice{}, "INV")
+	if err := tx.Omit(clause.Associations, "SalesOrderID").Create(&invoice).Error; err != nil {
+		return 0, errors.WrapError(err, "falha ao criar nota interna da transferência")
+	}
+	for i := range invoice.Items {
+		invoice.Items[i].InvoiceID = invoice.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&invoice.Items).Error; err != nil {
+		return 0, errors.WrapError(err, "falha ao criar itens da nota interna")
+	}
+	return invoice.ID, nil
+}
+
+// productUnitCosts devolve o custo unitário de cada produto: o custo médio do custeio ou, sem
+// custeio, o preço de custo do cadastro
+func productUnitCosts(tx *gorm.DB, products map[int]productModels.Product) (map[int]money.Decimal, error) {
+	unitCosts := make(map[int]money.Decimal, len(products))
+	productIDs := make([]int, 0, len(products))
+	for id, product := range products {
+		unitCosts[id] = product.CostPrice
+		productIDs = append(productIDs, id)
+	}
+	if len(productIDs) == 0 {
+		return unitCosts, nil
+	}
+	var costings []models.ProductCosting
+	if err := tx.Where("product_id IN ?", productIDs).Find(&costings).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar custeio dos produtos")
+	}
+	for _, costing := range costings {
+		if costing.AverageCost.IsPositive() {
+			unitCosts[costing.ProductID] = costing.AverageCost
+		}
+	}
+	return unitCosts, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/repository"
+	"context"
+)
+
+// ListBins lista os endereços de estoque, opcionalmente de uma zona
+func ListBins(ctx context.Context, zone string) ([]models.WarehouseBin, error) {
+	repo, err := repository.NewBinRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListBins(ctx, zone)
+}
+
+// CreateBin cadastra um endereço de estoque
+func CreateBin(ctx context.Context, input models.BinInput) (*models.WarehouseBin, error) {
+	repo, err := repository.NewBinRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CreateBin(ctx, input)
+}
+
+// UpdateBin altera um endereço de estoque
+func UpdateBin(ctx context.Context, id int, input models.BinInput) (*models.WarehouseBin, error) {
+	repo, err := repository.NewBinRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.UpdateBin(ctx, id, input)
+}
+
+// DeleteBin remove um endereço de estoque
+func DeleteBin(ctx context.Context, id int) error {
+	repo, err := repository.NewBinRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteBin(ctx, id)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/repository"
+	"context"
+)
+
+// GetProductCosting retorna o método de custeio, o custo médio e as camadas com saldo do produto
+func GetProductCosting(ctx context.Context, productID int) (*models.ProductCosting, []models.CostLayer, error) {
+	repo, err := repository.NewCostingRepository()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	costing, err := repo.GetProductCosting(ctx, productID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	layers, err := repo.GetCostLayers(ctx, productID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return costing, layers, nil
+}
+
+// SetCostingMethod altera o método de custeio (fifo ou average) do produto
+func SetCostingMethod(ctx context.Context, productID int, method string) (*models.ProductCosting, error) {
+	if method != models.CostingMethodFIFO && method != models.CostingMethodAverage {
+		return nil, errors.ErrInvalidCostingMethod
+	}
+
+	repo, err := repository.NewCostingRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.SetCostingMethod(ctx, productID, method)
+}
+
+// ReceivePurchaseOrder registra as camadas de custo do recebimento do pedido de compra, com o
+// lote, a validade e os números de série informados para cada item
+func ReceivePurchaseOrder(ctx context.Context, purchaseOrderID int, lines []models.ReceiptLine) ([]models.CostLayer, error) {
+	repo, err := repository.NewCostingRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ReceivePurchaseOrder(ctx, purchaseOrderID, lines)
+}
+
+// RecordDeliveryCOGS apura o CMV de uma entrega
+func RecordDeliveryCOGS(ctx context.Context, deliveryID int) ([]models.COGSEntry, error) {
+	repo, err := repository.NewCostingRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.RecordDeliveryCOGS(ctx, deliveryID)
+}
+
+// RecordInvoiceCOGS apura o CMV de uma fatura
+func RecordInvoiceCOGS(ctx context.Context, invoiceID int) ([]models.COGSEntry, error) {
+	repo, err := repository.NewCostingRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.RecordInvoiceCOGS(ctx, invoiceID)
+}
+
+// GetSalesOrderCOGS retorna o CMV apurado de um pedido de venda e seu total
+func GetSalesOrderCOGS(ctx context.Context, salesOrderID int) (*models.SalesOrderCOGS, error) {
+	repo, err := repository.NewCostingRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := repo.GetCOGSBySalesOrder(ctx, salesOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeCOGS(salesOrderID, entries), nil
+}
+
+// SummarizeCOGS totaliza os custos apurados de um pedido de venda
+func SummarizeCOGS(salesOrderID int, entries []models.COGSEntry) *models.SalesOrderCOGS {
+	summary := &models.SalesOrderCOGS{SalesOrderID: salesOrderID, Entries: entries}
+	if summary.Entries == nil {
+		summary.Entries = []models.COGSEntry{}
+	}
+
+	for _, entry := range entries {
+		summary.TotalCost = summary.TotalCost.Add(entry.TotalCost)
+	}
+
+	return summary
+}
+
+// EstimateCosts estima, sem baixar o estoque, o custo de venda de cada produto e quantidade pelo
+// método de custeio do produto; kits mantidos são custeados pelos componentes
+func EstimateCosts(ctx context.Context, queries []models.CostQuery) ([]models.CostEstimate, error) {
+	repo, err := repository.NewCostingRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.EstimateCosts(ctx, queries)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"testing"
+)
+
+func TestSetCostingMethodInvalid(t *testing.T) {
+	_, err := SetCostingMethod(context.Background(), 1, "lifo")
+	if err != errors.ErrInvalidCostingMethod {
+		t.Errorf("Esperado ErrInvalidCostingMethod, obtido %v", err)
+	}
+}
+
+func TestSummarizeCOGS(t *testing.T) {
+	entries := []models.COGSEntry{
+		{ProductID: 1, Quantity: 2, TotalCost: money.MustParse("10.10")},
+		{ProductID: 2, Quantity: 1, TotalCost: money.MustParse("5.25")},
+	}
+
+	summary := SummarizeCOGS(7, entries)
+	if !summary.TotalCost.Equal(money.MustParse("15.35")) {
+		t.Errorf("Esperado total 15.35, obtido %s", summary.TotalCost)
+	}
+
+	empty := SummarizeCOGS(8, nil)
+	if empty.Entries == nil || !empty.TotalCost.IsZero() {
+		t.Errorf("Resumo vazio inesperado: %+v", empty)
+	}
+}
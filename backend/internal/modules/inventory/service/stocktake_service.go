@@ -0,0 +1,99 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+)
+
+// CreateStocktake abre o inventário do depósito congelando os saldos
+func CreateStocktake(ctx context.Context, input models.StocktakeInput) (*models.Stocktake, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CreateStocktake(ctx, input, localtime.Now(ctx))
+}
+
+// GetStocktake busca um inventário
+func GetStocktake(ctx context.Context, id int) (*models.Stocktake, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetStocktake(ctx, id)
+}
+
+// ListStocktakes lista os inventários
+func ListStocktakes(ctx context.Context, filter models.StocktakeFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListStocktakes(ctx, filter, params)
+}
+
+// GetCountSheet monta a folha de contagem do inventário
+func GetCountSheet(ctx context.Context, id int) (*models.CountSheet, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetCountSheet(ctx, id)
+}
+
+// AddStocktakeCount registra a quantidade contada de um produto
+func AddStocktakeCount(ctx context.Context, id int, input models.CountInput) (*models.StocktakeCount, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.AddCount(ctx, id, input)
+}
+
+// ScanStocktakeCount registra a contagem pelo código de barras lido
+func ScanStocktakeCount(ctx context.Context, id int, input models.ScanInput) (*models.StocktakeCount, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ScanCount(ctx, id, input)
+}
+
+// DeleteStocktakeCount remove uma contagem do inventário
+func DeleteStocktakeCount(ctx context.Context, id, countID int) error {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteCount(ctx, id, countID)
+}
+
+// GetStocktakeVariances calcula o relatório de diferenças do inventário
+func GetStocktakeVariances(ctx context.Context, id int) (*models.VarianceReport, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetVariances(ctx, id)
+}
+
+// ApproveStocktake aprova o inventário em nome do usuário autenticado e lança os ajustes no depósito
+func ApproveStocktake(ctx context.Context, id int, approvedBy string) (*models.StocktakeApproval, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ApproveStocktake(ctx, id, approvedBy, localtime.Now(ctx))
+}
+
+// CancelStocktake cancela o inventário em contagem
+func CancelStocktake(ctx context.Context, id int) (*models.Stocktake, error) {
+	repo, err := repository.NewStocktakeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CancelStocktake(ctx, id, localtime.Now(ctx))
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/repository"
+	"context"
+	"time"
+)
+
+// CaptureDeliverySerials registra o lote e os números de série dos itens de uma entrega
+func CaptureDeliverySerials(ctx context.Context, deliveryID int, lines []models.ShipmentLine) ([]models.SerialNumber, error) {
+	repo, err := repository.NewTraceabilityRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CaptureDeliverySerials(ctx, deliveryID, lines)
+}
+
+// GetSerialTrace retorna a rastreabilidade completa de um número de série
+func GetSerialTrace(ctx context.Context, serial string) ([]models.SerialTrace, error) {
+	repo, err := repository.NewTraceabilityRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetSerialTrace(ctx, serial)
+}
+
+// GetLotTrace retorna as entradas e as saídas de um lote
+func GetLotTrace(ctx context.Context, lotNumber string) (*models.LotTrace, error) {
+	repo, err := repository.NewTraceabilityRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetLotTrace(ctx, lotNumber)
+}
+
+// GetExpiringLots lista os lotes com saldo que vencem nos próximos dias (ou já venceram)
+func GetExpiringLots(ctx context.Context, days int) ([]models.LotReceipt, error) {
+	repo, err := repository.NewTraceabilityRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetExpiringLots(ctx, time.Now().AddDate(0, 0, days))
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/inventory/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+)
+
+// ListWarehouses lista os depósitos
+func ListWarehouses(ctx context.Context) ([]models.Warehouse, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListWarehouses(ctx)
+}
+
+// CreateWarehouse cadastra um depósito
+func CreateWarehouse(ctx context.Context, input models.WarehouseInput) (*models.Warehouse, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CreateWarehouse(ctx, input)
+}
+
+// UpdateWarehouse altera o cadastro de um depósito
+func UpdateWarehouse(ctx context.Context, id int, input models.WarehouseInput) (*models.Warehouse, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.UpdateWarehouse(ctx, id, input)
+}
+
+// ListWarehouseStock lista os saldos dos produtos no depósito
+func ListWarehouseStock(ctx context.Context, warehouseID int) ([]models.StockLine, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListStock(ctx, warehouseID)
+}
+
+// CountWarehouseStock grava a contagem de estoque do depósito e devolve os ajustes gerados
+func CountWarehouseStock(ctx context.Context, warehouseID int, count models.StockCount) ([]models.StockMovement, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CountStock(ctx, warehouseID, count)
+}
+
+// ListStockMovements lista o histórico de movimentações do depósito
+func ListStockMovements(ctx context.Context, warehouseID int, filter models.MovementFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListMovements(ctx, warehouseID, filter, params)
+}
+
+// RequestTransfer solicita a transferência de produtos entre dois depósitos
+func RequestTransfer(ctx context.Context, input models.TransferInput) (*models.StockTransfer, error) {
+	items, err := input.Validate()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CreateTransfer(ctx, input, items, localtime.Now(ctx))
+}
+
+// GetTransfer busca uma transferência
+func GetTransfer(ctx context.Context, id int) (*models.StockTransfer, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTransfer(ctx, id)
+}
+
+// ListTransfers lista as transferências
+func ListTransfers(ctx context.Context, filter models.TransferFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListTransfers(ctx, filter, params)
+}
+
+// ShipTransfer envia a transferência a partir do depósito de origem
+func ShipTransfer(ctx context.Context, id int, input models.ShipTransferInput) (*models.StockTransfer, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ShipTransfer(ctx, id, input, localtime.Now(ctx))
+}
+
+// ReceiveTransfer recebe a transferência no depósito de destino
+func ReceiveTransfer(ctx context.Context, id int) (*models.StockTransfer, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ReceiveTransfer(ctx, id, localtime.Now(ctx))
+}
+
+// CancelTransfer cancela a transferência ainda não enviada
+func CancelTransfer(ctx context.Context, id int) (*models.StockTransfer, error) {
+	repo, err := repository.NewTransferRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CancelTransfer(ctx, id, localtime.Now(ctx))
+}
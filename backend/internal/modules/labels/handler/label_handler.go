@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/barcode"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/labels/models"
+	"ERP-ONSMART/backend/internal/modules/labels/service"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gera a etiqueta de código de barras do produto em PDF ou PNG
+// O código impresso é o código de barras (EAN) do produto, o SKU ou, na falta dos dois, PRD-<id>.
+// @Param format query string false "pdf (padrão) ou png"
+// @Param type query string false "code128 (padrão) ou qr"
+// @Param copies query int false "Cópias no PDF (padrão 1, máximo 100)"
+// @Success 200 "Arquivo da etiqueta"
+func ProductLabelHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	options, ok := labelOptions(c, barcode.KindCode128)
+	if !ok {
+		return
+	}
+
+	data, contentType, err := service.ProductLabels(c.Request.Context(), id, options)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar etiqueta do produto")
+		return
+	}
+	writeLabel(c, fmt.Sprintf("produto-%d", id), options, contentType, data)
+}
+
+// Gera as etiquetas dos volumes da entrega em PDF ou PNG
+// Cada volume recebe o código <número da entrega>-P<n>, lido pelo GET /scan/:code na conferência e no despacho.
+// @Param format query string false "pdf (padrão) ou png"
+// @Param type query string false "qr (padrão) ou code128"
+// @Param packages query int false "Quantidade de volumes (padrão: os volumes registrados na embalagem ou 1; máximo 100)"
+// @Param package query int false "Gera só a etiqueta deste volume (no PNG, padrão 1)"
+// @Param copies query int false "Cópias de cada etiqueta no PDF (padrão 1)"
+// @Success 200 "Arquivo das etiquetas"
+func DeliveryLabelHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	options, ok := labelOptions(c, barcode.KindQR)
+	if !ok {
+		return
+	}
+
+	packages, ok := queryCount(c, "packages", 0)
+	if !ok {
+		return
+	}
+	pkg, ok := queryCount(c, "package", 0)
+	if !ok {
+		return
+	}
+	if packages > 0 && pkg > packages {
+		c.Error(errors.InvalidParam("volume maior que a quantidade de volumes"))
+		return
+	}
+	if pkg == 0 && options.Format == models.FormatPNG {
+		pkg = 1
+	}
+
+	data, contentType, err := service.DeliveryLabels(c.Request.Context(), id, packages, pkg, options)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar etiquetas da entrega")
+		return
+	}
+	writeLabel(c, fmt.Sprintf("entrega-%d", id), options, contentType, data)
+}
+
+// Gera a etiqueta do endereço de estoque em PDF ou PNG
+// @Param format query string false "pdf (padrão) ou png"
+// @Param type query string false "qr (padrão) ou code128"
+// @Param copies query int false "Cópias no PDF (padrão 1, máximo 100)"
+// @Success 200 "Arquivo da etiqueta"
+func BinLabelHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	options, ok := labelOptions(c, barcode.KindQR)
+	if !ok {
+		return
+	}
+
+	data, contentType, err := service.BinLabels(c.Request.Context(), id, options)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar etiqueta do endereço de estoque")
+		return
+	}
+	writeLabel(c, fmt.Sprintf("endereco-%d", id), options, contentType, data)
+}
+
+// Resolve um código lido pelo leitor de código de barras
+// Procura, nesta ordem, endereço de estoque, volume ou número de entrega, produto (código de barras, SKU ou PRD-<id>), variante, número de série, lote, pedido de compra e pedido de venda; retorna o tipo e o registro encontrado.
+// @Security BearerAuth
+func ScanHandler(c *gin.Context) {
+	result, err := service.Scan(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao resolver código lido")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+func parseID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+func labelOptions(c *gin.Context, defaultKind string) (models.LabelOptions, bool) {
+	options, err := models.ParseLabelOptions(c.Query("format"), c.Query("type"), c.Query("copies"), defaultKind)
+	if err != nil {
+		c.Error(err)
+		return options, false
+	}
+	return options, true
+}
+
+// queryCount lê um parâmetro inteiro de 1 a MaxPackages; ausente, vale o padrão
+func queryCount(c *gin.Context, name string, fallback int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 || parsed > models.MaxPackages {
+		c.Error(errors.InvalidParam(fmt.Sprintf("parâmetro %s inválido (1 a %d)", name, models.MaxPackages)))
+		return 0, false
+	}
+	return parsed, true
+}
+
+// writeLabel envia o arquivo para exibição inline, pronto para impressão
+func writeLabel(c *gin.Context, name string, options models.LabelOptions, contentType string, data []byte) {
+	filename := fmt.Sprintf("etiqueta-%s.%s", name, options.Format)
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/barcode"
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Formatos de saída das etiquetas
+const (
+	FormatPNG = "png"
+	FormatPDF = "pdf"
+)
+
+// Limites de uma requisição de etiquetas
+const (
+	MaxCopies   = 100
+	MaxPackages = 100
+)
+
+// Tipos de registro devolvidos pela leitura de um código
+const (
+	ScanTypeBin           = "bin"
+	ScanTypePackage       = "delivery_package"
+	ScanTypeDelivery      = "delivery"
+	ScanTypeProduct       = "product"
+	ScanTypeVariant       = "variant"
+	ScanTypeSerial        = "serial"
+	ScanTypeLot           = "lot"
+	ScanTypePurchaseOrder = "purchase_order"
+	ScanTypeSalesOrder    = "sales_order"
+)
+
+// productCodePrefix identifica o produto pelo ID quando ele não tem código de barras nem SKU
+const productCodePrefix = "PRD-"
+
+// LabelOptions são as opções de geração: formato do arquivo, tipo de código e cópias de cada
+// etiqueta (só no PDF; o PNG traz uma etiqueta)
+type LabelOptions struct {
+	Format string
+	Kind   string
+	Copies int
+}
+
+// LabelData é o conteúdo de uma etiqueta: o código impresso e as linhas de texto do PDF
+type LabelData struct {
+	Code  string
+	Lines []string
+}
+
+// ScanResult é o registro encontrado para um código lido. Package traz o número do volume
+// quando o código é a etiqueta de um volume da entrega.
+type ScanResult struct {
+	Type    string `json:"type"`
+	ID      int    `json:"id"`
+	Code    string `json:"code"`
+	Package int    `json:"package,omitempty"`
+	Entity  any    `json:"entity"`
+}
+
+// ScanLot é um produto com saldo ou movimento no lote lido
+type ScanLot struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	SKU         string `json:"sku"`
+	OnHand      int    `json:"on_hand"`
+	ExpiryDate  string `json:"expiry_date,omitempty"`
+}
+
+// ParseLabelOptions interpreta os parâmetros da requisição; sem formato vale PDF, sem tipo vale
+// o tipo padrão da etiqueta e sem cópias, uma
+func ParseLabelOptions(format, kind, copies, defaultKind string) (LabelOptions, error) {
+	options := LabelOptions{Format: FormatPDF, Kind: defaultKind, Copies: 1}
+
+	switch strings.ToLower(format) {
+	case "", FormatPDF:
+	case FormatPNG:
+		options.Format = FormatPNG
+	default:
+		return options, errors.ErrInvalidLabelFormat
+	}
+
+	switch strings.ToLower(kind) {
+	case "":
+	case barcode.KindCode128, barcode.KindQR:
+		options.Kind = strings.ToLower(kind)
+	default:
+		return options, errors.ErrInvalidBarcodeType
+	}
+
+	if copies != "" {
+		value, err := strconv.Atoi(copies)
+		if err != nil || value < 1 || value > MaxCopies {
+			return options, errors.InvalidParam(fmt.Sprintf("quantidade de cópias inválida (1 a %d)", MaxCopies))
+		}
+		options.Copies = value
+	}
+	return options, nil
+}
+
+// ProductCode retorna o código da etiqueta do produto: o código de barras (EAN) cadastrado, o SKU
+// ou, na falta dos dois, PRD-<id>
+func ProductCode(id int, ean, sku string) string {
+	if code := strings.TrimSpace(ean); code != "" {
+		return code
+	}
+	if code := strings.TrimSpace(sku); code != "" {
+		return code
+	}
+	return fmt.Sprintf("%s%d", productCodePrefix, id)
+}
+
+// ParseProductCode extrai o ID de um código PRD-<id>
+func ParseProductCode(code string) (int, bool) {
+	if !strings.HasPrefix(strings.ToUpper(code), productCodePrefix) {
+		return 0, false
+	}
+	id, err := strconv.Atoi(code[len(productCodePrefix):])
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// PackageCode retorna o código da etiqueta do volume n da entrega: DLV-2026-000001-P2
+func PackageCode(deliveryNo string, n int) string {
+	return sales.DeliveryPackageCode(deliveryNo, n)
+}
+
+// ParsePackageCode separa o número da entrega e o número do volume de um código de volume
+func ParsePackageCode(code string) (string, int, bool) {
+	i := strings.LastIndex(strings.ToUpper(code), "-P")
+	if i <= 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(code[i+2:])
+	if err != nil || n <= 0 {
+		return "", 0, false
+	}
+	return code[:i], n, true
+}
+
+// PackageLabels monta as etiquetas dos volumes 1..packages da entrega, com as linhas comuns
+// (pedido, endereço...) seguidas da identificação do volume
+func PackageLabels(deliveryNo string, packages int, lines []string) []LabelData {
+	labels := make([]LabelData, 0, packages)
+	for n := 1; n <= packages; n++ {
+		packageLines := append([]string{fmt.Sprintf("Volume %d/%d", n, packages)}, lines...)
+		labels = append(labels, LabelData{Code: PackageCode(deliveryNo, n), Lines: packageLines})
+	}
+	return labels
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/barcode"
+	"ERP-ONSMART/backend/internal/errors"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelOptions(t *testing.T) {
+	options, err := ParseLabelOptions("", "", "", barcode.KindQR)
+	require.NoError(t, err)
+	assert.Equal(t, LabelOptions{Format: FormatPDF, Kind: barcode.KindQR, Copies: 1}, options)
+
+	options, err = ParseLabelOptions("PNG", "code128", "3", barcode.KindQR)
+	require.NoError(t, err)
+	assert.Equal(t, LabelOptions{Format: FormatPNG, Kind: barcode.KindCode128, Copies: 3}, options)
+
+	_, err = ParseLabelOptions("svg", "", "", barcode.KindQR)
+	assert.Equal(t, errors.ErrInvalidLabelFormat, err)
+	_, err = ParseLabelOptions("", "ean13", "", barcode.KindQR)
+	assert.Equal(t, errors.ErrInvalidBarcodeType, err)
+	_, err = ParseLabelOptions("", "", "0", barcode.KindQR)
+	assert.Error(t, err, "cópias abaixo de 1")
+	_, err = ParseLabelOptions("", "", "101", barcode.KindQR)
+	assert.Error(t, err, "cópias acima do limite")
+}
+
+func TestProductCode(t *testing.T) {
+	assert.Equal(t, "7891234567895", ProductCode(7, " 7891234567895 ", "PAR-10"))
+	assert.Equal(t, "PAR-10", ProductCode(7, "", "PAR-10"))
+	assert.Equal(t, "PRD-7", ProductCode(7, "", ""))
+
+	id, ok := ParseProductCode("prd-42")
+	assert.True(t, ok)
+	assert.Equal(t, 42, id)
+	_, ok = ParseProductCode("PRD-X")
+	assert.False(t, ok)
+}
+
+func TestPackageCode(t *testing.T) {
+	code := PackageCode("DLV-2026-000001", 3)
+	assert.Equal(t, "DLV-2026-000001-P3", code)
+
+	deliveryNo, n, ok := ParsePackageCode(code)
+	assert.True(t, ok)
+	assert.Equal(t, "DLV-2026-000001", deliveryNo)
+	assert.Equal(t, 3, n)
+
+	_, _, ok = ParsePackageCode("DLV-2026-000001")
+	assert.False(t, ok, "número de entrega sem volume")
+	_, _, ok = ParsePackageCode("-P1")
+	assert.False(t, ok)
+}
+
+func TestPackageLabels(t *testing.T) {
+	labels := PackageLabels("DLV-2026-000009", 2, []string{"Pedido: SO-2026-000004"})
+	require.Len(t, labels, 2)
+	assert.Equal(t, "DLV-2026-000009-P2", labels[1].Code)
+	assert.Equal(t, []string{"Volume 2/2", "Pedido: SO-2026-000004"}, labels[1].Lines)
+}
+
+func TestRender(t *testing.T) {
+	labels := []LabelData{{Code: "BIN-A-01", Lines: []string{"Corredor A"}}, {Code: "BIN-A-02"}}
+
+	data, contentType, err := Render(labels, LabelOptions{Format: FormatPNG, Kind: barcode.KindQR, Copies: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.True(t, bytes.HasPrefix(data, []byte("\x89PNG")))
+
+	data, contentType, err = Render(labels, LabelOptions{Format: FormatPDF, Kind: barcode.KindCode128, Copies: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+	assert.Contains(t, string(data), "/Count 4", "uma página por etiqueta e cópia")
+
+	_, _, err = Render([]LabelData{{Code: "Código"}}, LabelOptions{Format: FormatPDF, Kind: barcode.KindCode128, Copies: 1})
+	assert.Equal(t, errors.ErrLabelCodeNotEncodable, err, "Code 128 só aceita ASCII")
+	_, _, err = Render([]LabelData{{Code: strings.Repeat("X", barcode.QRMaxBytes+1)}}, LabelOptions{Format: FormatPNG, Kind: barcode.KindQR, Copies: 1})
+	assert.Equal(t, errors.ErrLabelCodeNotEncodable, err)
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/barcode"
+	"ERP-ONSMART/backend/internal/errors"
+	"bytes"
+)
+
+// Pixels por módulo no PNG: as barras do Code 128 são estreitas, os módulos do QR são quadrados
+const (
+	code128Scale = 3
+	qrScale      = 8
+)
+
+// Render gera o arquivo das etiquetas e devolve o conteúdo e o content type. O PNG traz só a
+// primeira etiqueta; o PDF traz uma página por etiqueta e cópia.
+func Render(labels []LabelData, options LabelOptions) ([]byte, string, error) {
+	if len(labels) == 0 {
+		return nil, "", errors.ErrLabelCodeNotEncodable
+	}
+
+	var buf bytes.Buffer
+	if options.Format == FormatPNG {
+		symbol, err := barcode.Encode(options.Kind, labels[0].Code)
+		if err != nil {
+			return nil, "", errors.ErrLabelCodeNotEncodable
+		}
+		scale := code128Scale
+		if !symbol.Linear() {
+			scale = qrScale
+		}
+		if err := symbol.WritePNG(&buf, scale); err != nil {
+			return nil, "", errors.WrapError(err, "falha ao gerar etiqueta PNG")
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+
+	pages := make([]barcode.Label, 0, len(labels)*max(options.Copies, 1))
+	for _, label := range labels {
+		symbol, err := barcode.Encode(options.Kind, label.Code)
+		if err != nil {
+			return nil, "", errors.ErrLabelCodeNotEncodable
+		}
+		for range max(options.Copies, 1) {
+			pages = append(pages, barcode.Label{Symbol: symbol, Lines: label.Lines})
+		}
+	}
+	if err := barcode.WriteLabelsPDF(&buf, pages); err != nil {
+		return nil, "", errors.WrapError(err, "falha ao gerar etiquetas PDF")
+	}
+	return buf.Bytes(), "application/pdf", nil
+}
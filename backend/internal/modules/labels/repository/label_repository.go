@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	inventory "ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/labels/models"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LabelRepository define as consultas das etiquetas e da leitura de códigos
+type LabelRepository interface {
+	ProductLabel(ctx context.Context, productID int) (models.LabelData, error)
+	DeliveryLabels(ctx context.Context, deliveryID, packages int) ([]models.LabelData, error)
+	BinLabel(ctx context.Context, binID int) (models.LabelData, error)
+	Scan(ctx context.Context, code string) (*models.ScanResult, error)
+}
+
+type labelRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewLabelRepository cria uma nova instância do repositório
+func NewLabelRepository() (LabelRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &labelRepository{
+		db:     gormDB,
+		logger: logger.WithModule("label_repository"),
+	}, nil
+}
+
+// ProductLabel monta a etiqueta do produto com o nome e o SKU
+func (r *labelRepository) ProductLabel(ctx context.Context, productID int) (models.LabelData, error) {
+	var p product.Product
+	if err := r.db.WithContext(ctx).Where("id = ?", productID).Take(&p).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return models.LabelData{}, errors.ErrProductNotFound
+		}
+		r.logger.Error("erro ao buscar produto", zap.Error(err), zap.Int("product_id", productID))
+		return models.LabelData{}, errors.WrapError(err, "falha ao buscar produto")
+	}
+
+	lines := []string{p.Name}
+	if p.SKU != "" {
+		lines = append(lines, "SKU: "+p.SKU)
+	}
+	return models.LabelData{Code: models.ProductCode(p.ID, p.Barcode, p.SKU), Lines: lines}, nil
+}
+
+// DeliveryLabels monta as etiquetas dos volumes da entrega com o pedido, a transportadora e o
+// endereço de entrega. Com packages zero, usa os volumes registrados na embalagem, com o peso de
+// cada um; sem volumes registrados, gera uma etiqueta.
+func (r *labelRepository) DeliveryLabels(ctx context.Context, deliveryID, packages int) ([]models.LabelData, error) {
+	var delivery sales.Delivery
+	if err := r.db.WithContext(ctx).Where("id = ?", deliveryID).Take(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		r.logger.Error("erro ao buscar entrega", zap.Error(err), zap.Int("delivery_id", deliveryID))
+		return nil, errors.WrapError(err, "falha ao buscar entrega")
+	}
+
+	var lines []string
+	if order := firstNonEmpty(delivery.SONo, delivery.PONo); order != "" {
+		lines = append(lines, "Pedido: "+order)
+	}
+	if delivery.Carrier != "" {
+		lines = append(lines, "Transportadora: "+delivery.Carrier)
+	}
+	if delivery.ShippingAddress != "" {
+		lines = append(lines, delivery.ShippingAddress)
+	}
+	if packages > 0 {
+		return models.PackageLabels(delivery.DeliveryNo, packages, lines), nil
+	}
+
+	var registered []sales.DeliveryPackage
+	if err := r.db.WithContext(ctx).Where("delivery_id = ?", deliveryID).Order("package_no ASC").Find(&registered).Error; err != nil {
+		r.logger.Error("erro ao buscar volumes da entrega", zap.Error(err), zap.Int("delivery_id", deliveryID))
+		return nil, errors.WrapError(err, "falha ao buscar volumes da entrega")
+	}
+	if len(registered) == 0 {
+		return models.PackageLabels(delivery.DeliveryNo, 1, lines), nil
+	}
+
+	labels := models.PackageLabels(delivery.DeliveryNo, len(registered), lines)
+	for i, pkg := range registered {
+		labels[i].Lines = append(labels[i].Lines, fmt.Sprintf("Peso: %.2f kg", pkg.WeightKg))
+	}
+	return labels, nil
+}
+
+// BinLabel monta a etiqueta do endereço de estoque com o nome e a zona
+func (r *labelRepository) BinLabel(ctx context.Context, binID int) (models.LabelData, error) {
+	var bin inventory.WarehouseBin
+	if err := r.db.WithContext(ctx).Where("id = ?", binID).Take(&bin).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return models.LabelData{}, errors.ErrBinNotFound
+		}
+		r.logger.Error("erro ao buscar endereço de estoque", zap.Error(err), zap.Int("bin_id", binID))
+		return models.LabelData{}, errors.WrapError(err, "falha ao buscar endereço de estoque")
+	}
+
+	var lines []string
+	if bin.Name != "" {
+		lines = append(lines, bin.Name)
+	}
+	if bin.Zone != "" {
+		lines = append(lines, "Zona: "+bin.Zone)
+	}
+	return models.LabelData{Code: bin.Code, Lines: lines}, nil
+}
+
+// Scan resolve o código lido, na ordem: endereço de estoque, volume e número de entrega,
+// produto (código de barras, SKU ou PRD-<id>), variante, número de série, lote, pedido de compra
+// e pedido de venda
+func (r *labelRepository) Scan(ctx context.Context, code string) (*models.ScanResult, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, errors.ErrScanCodeNotFound
+	}
+
+	resolvers := []func(context.Context, string) (*models.ScanResult, error){
+		r.scanBin,
+		r.scanDelivery,
+		r.scanProduct,
+		r.scanVariant,
+		r.scanSerial,
+		r.scanLot,
+		r.scanOrder,
+	}
+	for _, resolve := range resolvers {
+		result, err := resolve(ctx, code)
+		if err != nil {
+			r.logger.Error("erro ao resolver código lido", zap.Error(err), zap.String("code", code))
+			return nil, errors.WrapError(err, "falha ao resolver código lido")
+		}
+		if result != nil {
+			return result, nil
+		}
+	}
+	return nil, errors.ErrScanCodeNotFound
+}
+
+func (r *labelRepository) scanBin(ctx context.Context, code string) (*models.ScanResult, error) {
+	var bin inventory.WarehouseBin
+	found, err := r.take(ctx, &bin, "code = ?", inventory.NormalizeBinCode(code))
+	if err != nil || !found {
+		return nil, err
+	}
+	return &models.ScanResult{Type: models.ScanTypeBin, ID: bin.ID, Code: bin.Code, Entity: bin}, nil
+}
+
+func (r *labelRepository) scanDelivery(ctx context.Context, code string) (*models.ScanResult, error) {
+	if deliveryNo, n, ok := models.ParsePackageCode(code); ok {
+		delivery, err := r.delivery(ctx, deliveryNo)
+		if err != nil || delivery == nil {
+			return nil, err
+		}
+		return &models.ScanResult{Type: models.ScanTypePackage, ID: delivery.ID, Code: code, Package: n, Entity: delivery}, nil
+	}
+
+	delivery, err := r.delivery(ctx, code)
+	if err != nil || delivery == nil {
+		return nil, err
+	}
+	return &models.ScanResult{Type: models.ScanTypeDelivery, ID: delivery.ID, Code: delivery.DeliveryNo, Entity: delivery}, nil
+}
+
+// delivery busca a entrega pelo número, com os itens
+func (r *labelRepository) delivery(ctx context.Context, deliveryNo string) (*sales.Delivery, error) {
+	var delivery sales.Delivery
+	err := r.db.WithContext(ctx).Preload("Items").Where("delivery_no = ?", deliveryNo).Take(&delivery).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *labelRepository) scanProduct(ctx context.Context, code string) (*models.ScanResult, error) {
+	var p product.Product
+	found, err := r.take(ctx, &p, "barcode = ? OR sku = ?", code, code)
+	if err == nil && !found {
+		if id, ok := models.ParseProductCode(code); ok {
+			found, err = r.take(ctx, &p, "id = ?", id)
+		}
+	}
+	if err != nil || !found {
+		return nil, err
+	}
+	return &models.ScanResult{Type: models.ScanTypeProduct, ID: p.ID, Code: code, Entity: p}, nil
+}
+
+func (r *labelRepository) scanVariant(ctx context.Context, code string) (*models.ScanResult, error) {
+	var variant product.ProductVariant
+	found, err := r.take(ctx, &variant, "sku = ? OR barcode = ?", code, code)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &models.ScanResult{Type: models.ScanTypeVariant, ID: variant.ID, Code: code, Entity: variant}, nil
+}
+
+func (r *labelRepository) scanSerial(ctx context.Context, code string) (*models.ScanResult, error) {
+	var serial inventory.SerialNumber
+	found, err := r.take(ctx, &serial, "serial_number = ?", code)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &models.ScanResult{Type: models.ScanTypeSerial, ID: serial.ID, Code: serial.SerialNumber, Entity: serial}, nil
+}
+
+// scanLot devolve os produtos recebidos no lote, com o saldo atual e a validade
+func (r *labelRepository) scanLot(ctx context.Context, code string) (*models.ScanResult, error) {
+	var lots []models.ScanLot
+	if err := r.db.WithContext(ctx).Table("cost_layers l").
+		Select("p.id AS product_id, p.name AS product_name, p.sku, "+
+			"COALESCE(SUM(l.remaining_quantity), 0) AS on_hand, "+
+			"COALESCE(TO_CHAR(MIN(l.expiry_date), 'YYYY-MM-DD'), '') AS expiry_date").
+		Joins("JOIN products p ON p.id = l.product_id").
+		Scopes(tenant.Scope(ctx, "p")).
+		Where("l.lot_number = ?", code).
+		Group("p.id, p.name, p.sku").
+		Order("p.name ASC").
+		Scan(&lots).Error; err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, nil
+	}
+	return &models.ScanResult{Type: models.ScanTypeLot, Code: code, Entity: lots}, nil
+}
+
+// scanOrder procura pedidos de compra e de venda pelo número
+func (r *labelRepository) scanOrder(ctx context.Context, code string) (*models.ScanResult, error) {
+	var purchaseOrder sales.PurchaseOrder
+	found, err := r.take(ctx, &purchaseOrder, "po_no = ?", code)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return &models.ScanResult{Type: models.ScanTypePurchaseOrder, ID: purchaseOrder.ID, Code: purchaseOrder.PONo, Entity: purchaseOrder}, nil
+	}
+
+	var salesOrder sales.SalesOrder
+	found, err = r.take(ctx, &salesOrder, "so_no = ?", code)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &models.ScanResult{Type: models.ScanTypeSalesOrder, ID: salesOrder.ID, Code: salesOrder.SONo, Entity: salesOrder}, nil
+}
+
+// take busca o primeiro registro que atende à condição; found é falso quando não há nenhum
+func (r *labelRepository) take(ctx context.Context, dest any, query string, args ...any) (bool, error) {
+	err := r.db.WithContext(ctx).Where(query, args...).Order("id ASC").Take(dest).Error
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
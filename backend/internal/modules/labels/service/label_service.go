@@ -0,0 +1,63 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/labels/models"
+	"ERP-ONSMART/backend/internal/modules/labels/repository"
+	"context"
+)
+
+// ProductLabels gera as etiquetas do produto e devolve o arquivo e o content type
+func ProductLabels(ctx context.Context, productID int, options models.LabelOptions) ([]byte, string, error) {
+	repo, err := repository.NewLabelRepository()
+	if err != nil {
+		return nil, "", err
+	}
+	label, err := repo.ProductLabel(ctx, productID)
+	if err != nil {
+		return nil, "", err
+	}
+	return models.Render([]models.LabelData{label}, options)
+}
+
+// DeliveryLabels gera as etiquetas dos volumes da entrega. Com packages zero, usa os volumes
+// registrados na embalagem. Com pkg informado, gera só a etiqueta daquele volume (é a que o PNG traz).
+func DeliveryLabels(ctx context.Context, deliveryID, packages, pkg int, options models.LabelOptions) ([]byte, string, error) {
+	repo, err := repository.NewLabelRepository()
+	if err != nil {
+		return nil, "", err
+	}
+	labels, err := repo.DeliveryLabels(ctx, deliveryID, packages)
+	if err != nil {
+		return nil, "", err
+	}
+	if pkg > len(labels) {
+		return nil, "", errors.InvalidParam("volume maior que a quantidade de volumes")
+	}
+	if pkg > 0 {
+		labels = labels[pkg-1 : pkg]
+	}
+	return models.Render(labels, options)
+}
+
+// BinLabels gera as etiquetas do endereço de estoque
+func BinLabels(ctx context.Context, binID int, options models.LabelOptions) ([]byte, string, error) {
+	repo, err := repository.NewLabelRepository()
+	if err != nil {
+		return nil, "", err
+	}
+	label, err := repo.BinLabel(ctx, binID)
+	if err != nil {
+		return nil, "", err
+	}
+	return models.Render([]models.LabelData{label}, options)
+}
+
+// Scan resolve o código lido pelo leitor no registro correspondente
+func Scan(ctx context.Context, code string) (*models.ScanResult, error) {
+	repo, err := repository.NewLabelRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Scan(ctx, code)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/logger"
 	"ERP-ONSMART/backend/internal/modules/marketing/models"
 	"ERP-ONSMART/backend/internal/modules/marketing/service"
@@ -20,70 +21,135 @@ func init() {
 	validate = validator.New()
 }
 
+// Lista as campanhas de marketing
 func ListCampaignsHandler(c *gin.Context) {
-	camps, err := service.ListCampaigns()
+	camps, err := service.ListCampaigns(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar campanhas"})
+		c.Error(err).SetMeta("Erro ao buscar campanhas")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": camps})
 }
 
+// Cria uma campanha de marketing
 func CreateCampaignHandler(c *gin.Context) {
 	var camp models.Campaign
 	if err := c.ShouldBindJSON(&camp); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 	if err := validate.Struct(camp); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
-	created, err := service.AddCampaign(camp)
+	created, err := service.AddCampaign(c.Request.Context(), camp)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	logger.Logger.Info("Campanha criada", zap.Int("id", created.ID))
 	c.JSON(http.StatusCreated, created)
 }
 
+// Atualiza uma campanha de marketing
 func UpdateCampaignHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 	var camp models.Campaign
 	if err := c.ShouldBindJSON(&camp); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
 	if err := validate.Struct(camp); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
-	updated, err := service.ModifyCampaign(id, camp)
+	updated, err := service.ModifyCampaign(c.Request.Context(), id, camp)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Campanha não encontrada"})
+			c.Error(errors.ErrCampaignNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar campanha"})
+			c.Error(err).SetMeta("Erro ao atualizar campanha")
 		}
 		return
 	}
 	c.JSON(http.StatusOK, updated)
 }
 
+// Remove uma campanha de marketing
 func DeleteCampaignHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
-	if err := service.RemoveCampaign(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao deletar campanha", "details": err.Error()})
+	if err := service.RemoveCampaign(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao deletar campanha")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Campanha deletado com sucesso"})
 }
+
+// Retorna o retorno da campanha a partir das vendas atribuídas a ela
+// As cotações com campaign_id da campanha e os processos de venda vinculados geram os pedidos e a
+// receita (faturas emitidas); o custo por aquisição é o orçamento dividido pelos clientes com pedido.
+// @Security BearerAuth
+func GetCampaignROIHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	roi, err := service.GetCampaignROI(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao calcular ROI da campanha")
+		return
+	}
+	c.JSON(http.StatusOK, roi)
+}
+
+// Vincula um processo de venda à campanha para a atribuição das vendas
+// @Success 200 "Processo vinculado"
+// @Security BearerAuth
+func LinkSalesProcessHandler(c *gin.Context) {
+	id, processID, ok := campaignProcessParams(c)
+	if !ok {
+		return
+	}
+	if err := service.LinkSalesProcess(c.Request.Context(), id, processID); err != nil {
+		c.Error(err).SetMeta("erro ao vincular processo à campanha")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Processo vinculado à campanha"})
+}
+
+// Remove o vínculo entre um processo de venda e a campanha
+// @Security BearerAuth
+func UnlinkSalesProcessHandler(c *gin.Context) {
+	id, processID, ok := campaignProcessParams(c)
+	if !ok {
+		return
+	}
+	if err := service.UnlinkSalesProcess(c.Request.Context(), id, processID); err != nil {
+		c.Error(err).SetMeta("erro ao desvincular processo da campanha")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Processo desvinculado da campanha"})
+}
+
+func campaignProcessParams(c *gin.Context) (int, int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, 0, false
+	}
+	processID, err := strconv.Atoi(c.Param("process_id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, 0, false
+	}
+	return id, processID, true
+}
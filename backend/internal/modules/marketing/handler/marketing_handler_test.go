@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -11,6 +13,7 @@ import (
 	"ERP-ONSMART/backend/internal/logger"
 	"ERP-ONSMART/backend/internal/modules/marketing/models"
 	"ERP-ONSMART/backend/internal/modules/marketing/service"
+	"ERP-ONSMART/backend/internal/tenant"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
@@ -39,6 +42,8 @@ func TestMain(m *testing.M) {
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.TenantMiddleware())
 	r.GET("/campaigns", ListCampaignsHandler)
 	r.POST("/campaigns", CreateCampaignHandler)
 	r.PUT("/campaigns/:id", UpdateCampaignHandler)
@@ -50,6 +55,7 @@ func TestListCampaignsHandler(t *testing.T) {
 	router := setupRouter()
 
 	req, _ := http.NewRequest("GET", "/campaigns", nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 
 	router.ServeHTTP(resp, req)
@@ -66,7 +72,7 @@ func TestAddCampaign(t *testing.T) {
 		EndDate:     "31/05/2025",
 	}
 
-	created, err := service.AddCampaign(camp)
+	created, err := service.AddCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), camp)
 	assert.NoError(t, err)
 	assert.NotZero(t, created.ID, "ID da campanha criada deve ser diferente de zero")
 
@@ -90,7 +96,7 @@ func TestUpdateCampaignHandler(t *testing.T) {
 		EndDate:     "31/12/2025",
 	}
 
-	created, err := service.AddCampaign(camp)
+	created, err := service.AddCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), camp)
 	if err != nil {
 		t.Fatalf("Erro ao criar campanha: %v", err)
 	}
@@ -108,6 +114,7 @@ func TestUpdateCampaignHandler(t *testing.T) {
 	}`)
 
 	req, _ := http.NewRequest("PUT", "/campaigns/"+strconv.Itoa(created.ID), bytes.NewBuffer(updateBody))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 
@@ -128,6 +135,7 @@ func TestUpdateCampaignHandler_NotFound(t *testing.T) {
 	}`)
 
 	req, _ := http.NewRequest("PUT", "/campaigns/999999", bytes.NewBuffer(updateBody))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 
@@ -146,9 +154,10 @@ func TestDeleteCampaignHandler(t *testing.T) {
 		StartDate:   "01/03/2025",
 		EndDate:     "15/03/2025",
 	}
-	created, _ := service.AddCampaign(camp)
+	created, _ := service.AddCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), camp)
 
 	req, _ := http.NewRequest("DELETE", "/campaigns/"+strconv.Itoa(created.ID), nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 
 	router.ServeHTTP(resp, req)
@@ -0,0 +1,58 @@
+package models
+
+import "math"
+
+// CampaignSalesStats resume as vendas atribuídas a uma campanha: cotações com campaign_id da
+// campanha ou de processos de venda vinculados a ela, os pedidos gerados e as faturas emitidas
+type CampaignSalesStats struct {
+	Quotations          int
+	ConvertedQuotations int
+	SalesOrders         int
+	Customers           int
+	Revenue             float64
+}
+
+// CampaignROI é o retorno de GET /campaigns/:id/roi
+type CampaignROI struct {
+	CampaignID          int      `json:"campaign_id"`
+	Title               string   `json:"title"`
+	Budget              float64  `json:"budget"`
+	Quotations          int      `json:"quotations"`
+	ConvertedQuotations int      `json:"converted_quotations"`
+	SalesOrders         int      `json:"sales_orders"`
+	Customers           int      `json:"customers"`
+	Revenue             float64  `json:"revenue"`
+	ConversionRate      float64  `json:"conversion_rate"`      // % das cotações que viraram pedido
+	CostPerAcquisition  *float64 `json:"cost_per_acquisition"` // orçamento por cliente com pedido; nulo sem clientes
+	ROI                 *float64 `json:"roi"`                  // % (receita - orçamento) / orçamento; nulo sem orçamento
+}
+
+// NewCampaignROI calcula a taxa de conversão, o custo por aquisição e o ROI da campanha
+func NewCampaignROI(campaign Campaign, stats CampaignSalesStats) CampaignROI {
+	roi := CampaignROI{
+		CampaignID:          campaign.ID,
+		Title:               campaign.Title,
+		Budget:              round2(campaign.Budget),
+		Quotations:          stats.Quotations,
+		ConvertedQuotations: stats.ConvertedQuotations,
+		SalesOrders:         stats.SalesOrders,
+		Customers:           stats.Customers,
+		Revenue:             round2(stats.Revenue),
+	}
+	if stats.Quotations > 0 {
+		roi.ConversionRate = round2(float64(stats.ConvertedQuotations) / float64(stats.Quotations) * 100)
+	}
+	if stats.Customers > 0 {
+		cpa := round2(campaign.Budget / float64(stats.Customers))
+		roi.CostPerAcquisition = &cpa
+	}
+	if campaign.Budget > 0 {
+		value := round2((stats.Revenue - campaign.Budget) / campaign.Budget * 100)
+		roi.ROI = &value
+	}
+	return roi
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
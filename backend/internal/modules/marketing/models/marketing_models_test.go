@@ -1,6 +1,11 @@
 package models
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
 func TestCampaignModel(t *testing.T) {
 	c := Campaign{ID: 1, Title: "Campanha Teste", Budget: 1000, StartDate: "01/01/2023", EndDate: "31/01/2023"}
@@ -8,3 +13,34 @@ func TestCampaignModel(t *testing.T) {
 		t.Errorf("Esperado ID 1, obtido %d", c.ID)
 	}
 }
+
+func TestNewCampaignROI(t *testing.T) {
+	campaign := Campaign{ID: 7, Title: "Black Friday", Budget: 3000}
+	roi := NewCampaignROI(campaign, CampaignSalesStats{
+		Quotations:          8,
+		ConvertedQuotations: 3,
+		SalesOrders:         4,
+		Customers:           3,
+		Revenue:             12000.004,
+	})
+
+	assert.Equal(t, 7, roi.CampaignID)
+	assert.Equal(t, 12000.0, roi.Revenue)
+	assert.Equal(t, 37.5, roi.ConversionRate)
+	require.NotNil(t, roi.CostPerAcquisition)
+	assert.Equal(t, 1000.0, *roi.CostPerAcquisition)
+	require.NotNil(t, roi.ROI)
+	assert.Equal(t, 300.0, *roi.ROI)
+}
+
+func TestNewCampaignROIWithoutSales(t *testing.T) {
+	roi := NewCampaignROI(Campaign{ID: 1, Budget: 500}, CampaignSalesStats{})
+
+	assert.Zero(t, roi.ConversionRate)
+	assert.Nil(t, roi.CostPerAcquisition, "sem clientes não há custo por aquisição")
+	require.NotNil(t, roi.ROI)
+	assert.Equal(t, -100.0, *roi.ROI)
+
+	roi = NewCampaignROI(Campaign{ID: 2}, CampaignSalesStats{Customers: 1, Revenue: 100})
+	assert.Nil(t, roi.ROI, "sem orçamento não há ROI")
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/marketing/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AttributionRepository vincula processos de venda às campanhas e resume as vendas atribuídas
+type AttributionRepository interface {
+	GetCampaign(ctx context.Context, id int) (*models.Campaign, error)
+	LinkSalesProcess(ctx context.Context, campaignID, processID int) error
+	UnlinkSalesProcess(ctx context.Context, campaignID, processID int) error
+	GetSalesStats(ctx context.Context, campaignID int) (*models.CampaignSalesStats, error)
+}
+
+// Faturas em rascunho ou canceladas não contam como receita gerada
+var excludedInvoiceStatuses = []string{sales.InvoiceStatusDraft, sales.InvoiceStatusCancelled}
+
+// campaignSalesStatsQuery atribui à campanha as cotações com campaign_id e as dos processos
+// vinculados; os pedidos vêm dessas cotações ou dos processos, e a receita das faturas desses
+// pedidos ou dos processos. Tudo restrito à empresa da campanha.
+const campaignSalesStatsQuery = `
+WITH campaign AS (
+	SELECT company_id FROM campaigns WHERE id = @campaign
+), linked_processes AS (
+	SELECT process_id FROM process_campaigns WHERE campaign_id = @campaign
+), attributed_quotations AS (
+	SELECT q.id FROM quotations q
+	WHERE q.deleted_at IS NULL AND q.company_id = (SELECT company_id FROM campaign)
+	  AND (q.campaign_id = @campaign OR q.id IN (
+		SELECT pq.quotation_id FROM process_quotations pq WHERE pq.process_id IN (SELECT process_id FROM linked_processes)))
+), attributed_orders AS (
+	SELECT so.id, so.contact_id, so.quotation_id FROM sales_orders so
+	WHERE so.deleted_at IS NULL AND so.company_id = (SELECT company_id FROM campaign) AND so.status <> @cancelled
+	  AND (so.quotation_id IN (SELECT id FROM attributed_quotations) OR so.id IN (
+		SELECT pso.sales_order_id FROM process_sales_orders pso WHERE pso.process_id IN (SELECT process_id FROM linked_processes)))
+), attributed_invoices AS (
+	SELECT i.grand_total FROM invoices i
+	WHERE i.deleted_at IS NULL AND i.company_id = (SELECT company_id FROM campaign) AND i.status NOT IN @excluded
+	  AND (i.sales_order_id IN (SELECT id FROM attributed_orders) OR i.id IN (
+		SELECT pi.invoice_id FROM process_invoices pi WHERE pi.process_id IN (SELECT process_id FROM linked_processes)))
+)
+SELECT
+	(SELECT COUNT(*) FROM attributed_quotations) AS quotations,
+	(SELECT COUNT(*) FROM attributed_quotations q WHERE EXISTS (
+		SELECT 1 FROM attributed_orders o WHERE o.quotation_id = q.id)) AS converted_quotations,
+	(SELECT COUNT(*) FROM attributed_orders) AS sales_orders,
+	(SELECT COUNT(DISTINCT contact_id) FROM attributed_orders) AS customers,
+	(SELECT COALESCE(SUM(grand_total), 0) FROM attributed_invoices) AS revenue`
+
+type attributionRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewAttributionRepository cria uma nova instância do repositório
+func NewAttributionRepository() (AttributionRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &attributionRepository{
+		db:     gormDB,
+		logger: logger.WithModule("attribution_repository"),
+	}, nil
+}
+
+// GetCampaign busca a campanha da empresa do contexto
+func (r *attributionRepository) GetCampaign(ctx context.Context, id int) (*models.Campaign, error) {
+	var campaign models.Campaign
+	err := r.db.WithContext(ctx).Table("campaigns").
+		Scopes(tenant.Scope(ctx, "campaigns")).
+		Select("id, title, description, budget").
+		Where("id = ?", id).
+		Take(&campaign).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCampaignNotFound
+		}
+		r.logger.Error("erro ao buscar campanha", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar campanha")
+	}
+	return &campaign, nil
+}
+
+// LinkSalesProcess vincula o processo de venda à campanha; vincular de novo não altera nada
+func (r *attributionRepository) LinkSalesProcess(ctx context.Context, campaignID, processID int) error {
+	if _, err := r.GetCampaign(ctx, campaignID); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&sales.SalesProcess{}).Where("id = ?", processID).Count(&count).Error; err != nil {
+		r.logger.Error("erro ao buscar sales process", zap.Error(err), zap.Int("id", processID))
+		return errors.WrapError(err, "falha ao buscar sales process")
+	}
+	if count == 0 {
+		return errors.ErrSalesProcessNotFound
+	}
+
+	if err := r.db.WithContext(ctx).Exec(
+		"INSERT INTO process_campaigns (process_id, campaign_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		processID, campaignID).Error; err != nil {
+		r.logger.Error("erro ao vincular processo à campanha", zap.Error(err),
+			zap.Int("campaign_id", campaignID), zap.Int("process_id", processID))
+		return errors.WrapError(err, "falha ao vincular processo à campanha")
+	}
+
+	r.logger.Info("processo vinculado à campanha", zap.Int("campaign_id", campaignID), zap.Int("process_id", processID))
+	return nil
+}
+
+// UnlinkSalesProcess remove o vínculo entre o processo de venda e a campanha
+func (r *attributionRepository) UnlinkSalesProcess(ctx context.Context, campaignID, processID int) error {
+	if _, err := r.GetCampaign(ctx, campaignID); err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).Exec(
+		"DELETE FROM process_campaigns WHERE process_id = ? AND campaign_id = ?", processID, campaignID)
+	if result.Error != nil {
+		r.logger.Error("erro ao desvincular processo da campanha", zap.Error(result.Error),
+			zap.Int("campaign_id", campaignID), zap.Int("process_id", processID))
+		return errors.WrapError(result.Error, "falha ao desvincular processo da campanha")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrSalesProcessNotFound
+	}
+	return nil
+}
+
+// GetSalesStats resume as cotações, os pedidos, os clientes e a receita atribuídos à campanha;
+// quem chama deve validar a campanha com GetCampaign
+func (r *attributionRepository) GetSalesStats(ctx context.Context, campaignID int) (*models.CampaignSalesStats, error) {
+	var stats models.CampaignSalesStats
+	if err := r.db.WithContext(ctx).Raw(campaignSalesStatsQuery, map[string]any{
+		"campaign":  campaignID,
+		"cancelled": sales.SOStatusCancelled,
+		"excluded":  excludedInvoiceStatuses,
+	}).Scan(&stats).Error; err != nil {
+		r.logger.Error("erro ao resumir vendas da campanha", zap.Error(err), zap.Int("id", campaignID))
+		return nil, errors.WrapError(err, "falha ao resumir vendas da campanha")
+	}
+	return &stats, nil
+}
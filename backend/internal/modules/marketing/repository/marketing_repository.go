@@ -2,12 +2,20 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/marketing/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"database/sql"
 	"fmt"
 )
 
-func GetAllCampaigns() ([]models.Campaign, error) {
+func GetAllCampaigns(ctx context.Context) ([]models.Campaign, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
@@ -17,10 +25,11 @@ func GetAllCampaigns() ([]models.Campaign, error) {
 	query := `
 		SELECT id, title, description, budget, start_date, end_date
 		FROM campaigns
+		WHERE ` + company + `
 		ORDER BY id
 	`
 
-	rows, err := conn.Query(query)
+	rows, err := conn.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +52,12 @@ func GetAllCampaigns() ([]models.Campaign, error) {
 	return campaigns, nil
 }
 
-func CreateCampaign(c models.Campaign) (models.Campaign, error) {
+func CreateCampaign(ctx context.Context, c models.Campaign) (models.Campaign, error) {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return models.Campaign{}, errors.ErrTenantRequired
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return models.Campaign{}, err
@@ -51,12 +65,12 @@ func CreateCampaign(c models.Campaign) (models.Campaign, error) {
 	defer conn.Close()
 
 	query := `
-		INSERT INTO campaigns (title, description, budget, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO campaigns (title, description, budget, start_date, end_date, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
 	`
 
-	err = conn.QueryRow(query, c.Title, c.Description, c.Budget, c.StartDate, c.EndDate).Scan(&c.ID)
+	err = conn.QueryRowContext(ctx, query, c.Title, c.Description, c.Budget, c.StartDate, c.EndDate, companyID).Scan(&c.ID)
 	if err != nil {
 		return models.Campaign{}, err
 	}
@@ -64,7 +78,12 @@ func CreateCampaign(c models.Campaign) (models.Campaign, error) {
 	return c, nil
 }
 
-func UpdateCampaign(id int, updated models.Campaign) (models.Campaign, error) {
+func UpdateCampaign(ctx context.Context, id int, updated models.Campaign) (models.Campaign, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return models.Campaign{}, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return models.Campaign{}, err
@@ -78,10 +97,9 @@ func UpdateCampaign(id int, updated models.Campaign) (models.Campaign, error) {
 		    budget = $3,
 		    start_date = $4,
 		    end_date = $5
-		WHERE id = $6
-	`
+		WHERE id = $6 AND ` + company
 
-	result, err := conn.Exec(query, updated.Title, updated.Description, updated.Budget, updated.StartDate, updated.EndDate, id)
+	result, err := conn.ExecContext(ctx, query, updated.Title, updated.Description, updated.Budget, updated.StartDate, updated.EndDate, id)
 	if err != nil {
 		return models.Campaign{}, err
 	}
@@ -99,16 +117,21 @@ func UpdateCampaign(id int, updated models.Campaign) (models.Campaign, error) {
 	return updated, nil
 }
 
-func DeleteCampaign(id int) error {
+func DeleteCampaign(ctx context.Context, id int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	query := `DELETE FROM campaigns WHERE id = $1`
+	query := `DELETE FROM campaigns WHERE id = $1 AND ` + company
 
-	result, err := conn.Exec(query, id)
+	result, err := conn.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
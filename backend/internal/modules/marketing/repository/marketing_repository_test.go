@@ -2,6 +2,8 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/modules/marketing/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -29,7 +31,7 @@ func TestCreateCampaign(t *testing.T) {
 		EndDate:     "2023-01-31",
 	}
 
-	created, err := CreateCampaign(camp)
+	created, err := CreateCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), camp)
 	if err != nil {
 		t.Fatalf("Erro ao criar campanha: %v", err)
 	}
@@ -40,7 +42,7 @@ func TestCreateCampaign(t *testing.T) {
 }
 
 func TestGetCampaign(t *testing.T) {
-	camps, err := GetAllCampaigns()
+	camps, err := GetAllCampaigns(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao buscar campanhas: %v", err)
 	}
@@ -59,7 +61,7 @@ func TestUpdateCampaign(t *testing.T) {
 		EndDate:     "2023-02-28",
 	}
 
-	created, err := CreateCampaign(camp)
+	created, err := CreateCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), camp)
 	if err != nil {
 		t.Fatalf("Erro ao criar campanha: %v", err)
 	}
@@ -72,7 +74,7 @@ func TestUpdateCampaign(t *testing.T) {
 		EndDate:     "2023-03-10",
 	}
 
-	updated, err := UpdateCampaign(created.ID, update)
+	updated, err := UpdateCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID, update)
 	if err != nil {
 		t.Fatalf("Erro ao atualizar campanha: %v", err)
 	}
@@ -91,19 +93,19 @@ func TestDeleteCampaign(t *testing.T) {
 		EndDate:     "2023-04-30",
 	}
 
-	created, err := CreateCampaign(camp)
+	created, err := CreateCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), camp)
 	if err != nil {
 		t.Fatalf("Erro ao criar campanha para deletar: %v", err)
 	}
 
 	// Deleta a campanha
-	err = DeleteCampaign(created.ID)
+	err = DeleteCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Errorf("Erro ao deletar campanha: %v", err)
 	}
 
 	// Tenta deletar novamente: deve retornar erro informando que a campanha não foi encontrada
-	err = DeleteCampaign(created.ID)
+	err = DeleteCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err == nil {
 		t.Errorf("Esperava erro ao deletar campanha inexistente, mas não houve erro")
 	} else {
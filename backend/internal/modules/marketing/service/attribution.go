@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"ERP-ONSMART/backend/internal/modules/marketing/models"
+	"ERP-ONSMART/backend/internal/modules/marketing/repository"
+)
+
+// GetCampaignROI calcula a receita gerada, a conversão e o custo por aquisição da campanha
+func GetCampaignROI(ctx context.Context, campaignID int) (*models.CampaignROI, error) {
+	repo, err := repository.NewAttributionRepository()
+	if err != nil {
+		return nil, err
+	}
+	campaign, err := repo.GetCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := repo.GetSalesStats(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	roi := models.NewCampaignROI(*campaign, *stats)
+	return &roi, nil
+}
+
+// LinkSalesProcess atribui o processo de venda à campanha
+func LinkSalesProcess(ctx context.Context, campaignID, processID int) error {
+	repo, err := repository.NewAttributionRepository()
+	if err != nil {
+		return err
+	}
+	return repo.LinkSalesProcess(ctx, campaignID, processID)
+}
+
+// UnlinkSalesProcess remove a atribuição do processo de venda à campanha
+func UnlinkSalesProcess(ctx context.Context, campaignID, processID int) error {
+	repo, err := repository.NewAttributionRepository()
+	if err != nil {
+		return err
+	}
+	return repo.UnlinkSalesProcess(ctx, campaignID, processID)
+}
@@ -3,15 +3,16 @@ package service
 import (
 	"ERP-ONSMART/backend/internal/modules/marketing/models"
 	"ERP-ONSMART/backend/internal/modules/marketing/repository"
+	"context"
 	"fmt"
 	"time"
 )
 
-func ListCampaigns() ([]models.Campaign, error) {
-	return repository.GetAllCampaigns()
+func ListCampaigns(ctx context.Context) ([]models.Campaign, error) {
+	return repository.GetAllCampaigns(ctx)
 }
 
-func AddCampaign(c models.Campaign) (models.Campaign, error) {
+func AddCampaign(ctx context.Context, c models.Campaign) (models.Campaign, error) {
 	// Converte datas de dd/mm/yyyy → yyyy-mm-dd
 	layoutBR := "02/01/2006"
 	start, err := time.Parse(layoutBR, c.StartDate)
@@ -26,10 +27,10 @@ func AddCampaign(c models.Campaign) (models.Campaign, error) {
 	c.StartDate = start.Format("2006-01-02")
 	c.EndDate = end.Format("2006-01-02")
 
-	return repository.CreateCampaign(c)
+	return repository.CreateCampaign(ctx, c)
 }
 
-func ModifyCampaign(id int, c models.Campaign) (models.Campaign, error) {
+func ModifyCampaign(ctx context.Context, id int, c models.Campaign) (models.Campaign, error) {
 	layoutBR := "02/01/2006"
 	start, err := time.Parse(layoutBR, c.StartDate)
 	if err != nil {
@@ -43,9 +44,9 @@ func ModifyCampaign(id int, c models.Campaign) (models.Campaign, error) {
 	c.StartDate = start.Format("2006-01-02")
 	c.EndDate = end.Format("2006-01-02")
 
-	return repository.UpdateCampaign(id, c)
+	return repository.UpdateCampaign(ctx, id, c)
 }
 
-func RemoveCampaign(id int) error {
-	return repository.DeleteCampaign(id)
+func RemoveCampaign(ctx context.Context, id int) error {
+	return repository.DeleteCampaign(ctx, id)
 }
@@ -2,6 +2,8 @@ package service
 
 import (
 	"ERP-ONSMART/backend/internal/modules/marketing/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"os"
 	"testing"
 
@@ -28,7 +30,7 @@ func TestAddCampaign(t *testing.T) {
 		EndDate:     "25/11/2023", // Formato ajustado: dd/mm/aaaa
 	}
 
-	created, err := AddCampaign(c)
+	created, err := AddCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), c)
 	if err != nil {
 		t.Fatalf("Erro ao adicionar campanha: %v", err)
 	}
@@ -39,7 +41,7 @@ func TestAddCampaign(t *testing.T) {
 }
 
 func TestListCampaigns(t *testing.T) {
-	camps, err := ListCampaigns()
+	camps, err := ListCampaigns(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao listar campanhas: %v", err)
 	}
@@ -57,7 +59,7 @@ func TestModifyCampaign(t *testing.T) {
 		StartDate:   "01/10/2023", // Formato dd/mm/aaaa
 		EndDate:     "15/10/2023", // Formato dd/mm/aaaa
 	}
-	created, err := AddCampaign(c)
+	created, err := AddCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), c)
 	if err != nil {
 		t.Fatalf("Erro ao adicionar campanha: %v", err)
 	}
@@ -70,7 +72,7 @@ func TestModifyCampaign(t *testing.T) {
 		EndDate:     "20/10/2023", // Formato dd/mm/aaaa
 	}
 
-	updated, err := ModifyCampaign(created.ID, updatedData)
+	updated, err := ModifyCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID, updatedData)
 	if err != nil {
 		t.Fatalf("Erro ao atualizar campanha: %v", err)
 	}
@@ -88,12 +90,12 @@ func TestRemoveCampaign(t *testing.T) {
 		StartDate:   "01/12/2023", // Formato dd/mm/aaaa
 		EndDate:     "10/12/2023", // Formato dd/mm/aaaa
 	}
-	created, err := AddCampaign(c)
+	created, err := AddCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), c)
 	if err != nil {
 		t.Fatalf("Erro ao adicionar campanha para remoção: %v", err)
 	}
 
-	err = RemoveCampaign(created.ID)
+	err = RemoveCampaign(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Fatalf("Erro ao remover campanha: %v", err)
 	}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/modules/meta/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os status dos documentos de venda, compra e dos processos de venda, com os rótulos no
+// idioma da requisição (Accept-Language ou ?lang=) e as transições permitidas de cada status
+// @Security BearerAuth
+// @Param lang query string false "Idioma dos rótulos (pt-BR ou en-US)"
+func ListEnumsHandler(c *gin.Context) {
+	lang := i18n.FromContext(c.Request.Context())
+	if value := c.Query("lang"); value != "" {
+		if lang = i18n.Normalize(value); lang == "" {
+			c.Error(errors.InvalidParam("idioma não suportado: " + value))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, service.ListEnums(lang))
+}
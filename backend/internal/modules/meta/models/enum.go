@@ -0,0 +1,17 @@
+package models
+
+// EnumValue é um valor de uma enumeração com o rótulo no idioma da requisição e os valores para
+// os quais pode passar; Final indica que não há transições
+type EnumValue struct {
+	Value       string   `json:"value"`
+	Label       string   `json:"label"`
+	Transitions []string `json:"transitions"`
+	Final       bool     `json:"final"`
+}
+
+// EnumRegistry são as enumerações de status dos documentos, pelo nome (o mesmo grupo de
+// GET /i18n/labels), com os valores na ordem do fluxo
+type EnumRegistry struct {
+	Language string                 `json:"language"`
+	Enums    map[string][]EnumValue `json:"enums"`
+}
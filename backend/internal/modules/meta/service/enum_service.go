@@ -0,0 +1,40 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/modules/meta/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+)
+
+// statusMachines são os fluxos de status publicados no registro, pelo grupo de rótulos
+func statusMachines() map[string]sales.StatusMachine {
+	return map[string]sales.StatusMachine{
+		i18n.QuotationStatus:     sales.QuotationStatuses,
+		i18n.SalesOrderStatus:    sales.SalesOrderStatuses,
+		i18n.PurchaseOrderStatus: sales.PurchaseOrderStatuses,
+		i18n.DeliveryStatus:      sales.DeliveryStatuses,
+		i18n.InvoiceStatus:       sales.InvoiceStatuses,
+		i18n.SalesProcessStatus:  salesRepository.ProcessStatuses(),
+	}
+}
+
+// ListEnums monta o registro dos status dos documentos a partir dos fluxos de status, com os
+// rótulos no idioma informado
+func ListEnums(lang string) models.EnumRegistry {
+	registry := models.EnumRegistry{Language: lang, Enums: make(map[string][]models.EnumValue)}
+	for name, machine := range statusMachines() {
+		values := make([]models.EnumValue, 0, len(machine.Statuses))
+		for _, status := range machine.Statuses {
+			transitions := append([]string{}, machine.Transitions[status]...)
+			values = append(values, models.EnumValue{
+				Value:       status,
+				Label:       i18n.Label(lang, name, status),
+				Transitions: transitions,
+				Final:       machine.IsFinal(status),
+			})
+		}
+		registry.Enums[name] = values
+	}
+	return registry
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"ERP-ONSMART/backend/internal/i18n"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEnumsLabelsEveryStatus(t *testing.T) {
+	for _, lang := range i18n.Languages {
+		registry := ListEnums(lang)
+		assert.Len(t, registry.Enums, 6)
+		labels := i18n.Labels(lang)
+		for name, values := range registry.Enums {
+			require.NotEmpty(t, values, name)
+			for _, value := range values {
+				_, ok := labels[name][value.Value]
+				assert.True(t, ok, "%s: %s sem rótulo em %s", name, value.Value, lang)
+				assert.NotEmpty(t, value.Label)
+				assert.NotNil(t, value.Transitions)
+			}
+		}
+	}
+}
+
+func TestListEnumsFollowsStatusMachines(t *testing.T) {
+	registry := ListEnums("en-US")
+
+	orders := registry.Enums[i18n.SalesOrderStatus]
+	require.Len(t, orders, len(sales.SalesOrderStatuses.Statuses))
+	assert.Equal(t, sales.SOStatusDraft, orders[0].Value)
+	assert.Equal(t, "Draft", orders[0].Label)
+	assert.Equal(t, sales.SalesOrderStatuses.Transitions[sales.SOStatusDraft], orders[0].Transitions)
+
+	for _, value := range registry.Enums[i18n.InvoiceStatus] {
+		assert.Equal(t, value.Value == sales.InvoiceStatusCancelled, value.Final, value.Value)
+	}
+	processes := registry.Enums[i18n.SalesProcessStatus]
+	assert.True(t, processes[len(processes)-1].Final, "o processo cancelado é final")
+}
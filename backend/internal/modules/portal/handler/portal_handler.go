@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/portal/models"
+	"ERP-ONSMART/backend/internal/modules/portal/service"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Emite um código de acesso ao portal para o cliente; o código só é exibido nesta resposta
+// @Security BearerAuth
+// @Param id path int true "ID do contato"
+func CreatePortalAccessHandler(c *gin.Context) {
+	contactID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.CreateAccessInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	created, err := service.CreateAccess(c.Request.Context(), contactID, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar acesso ao portal")
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// Lista os códigos de acesso ao portal do cliente
+// @Security BearerAuth
+// @Param id path int true "ID do contato"
+func ListPortalAccessesHandler(c *gin.Context) {
+	contactID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	accesses, err := service.ListAccesses(c.Request.Context(), contactID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar acessos ao portal")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"accesses": accesses})
+}
+
+// Revoga os códigos de acesso ao portal do cliente
+// Os tokens já emitidos expiram em até 2 horas.
+// @Security BearerAuth
+// @Param id path int true "ID do contato"
+func RevokePortalAccessHandler(c *gin.Context) {
+	contactID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	revoked, err := service.RevokeAccesses(c.Request.Context(), contactID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao revogar acessos ao portal")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "acessos ao portal revogados", "revoked": revoked})
+}
+
+// Troca o código de acesso do cliente por um token do portal (válido por 2 horas)
+// O token só dá acesso às rotas /portal.
+// @Success 200 "Token gerado"
+func CreatePortalSessionHandler(c *gin.Context) {
+	var req models.SessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	session, err := service.StartSession(c.Request.Context(), req.Code)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao validar acesso ao portal")
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// Lista as cotações enviadas ao cliente
+// @Tags portal
+// @Security PortalAuth
+func ListQuotationsHandler(c *gin.Context) {
+	quotations, err := service.ListQuotations(c.Request.Context(), contactID(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar cotações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotations": quotations})
+}
+
+// Retorna uma cotação do cliente com os itens
+// @Tags portal
+// @Security PortalAuth
+// @Param id path int true "ID da cotação"
+func GetQuotationHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	quotation, err := service.GetQuotation(c.Request.Context(), contactID(c), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar cotação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotation": quotation})
+}
+
+// Aceita a cotação
+// Só cotações enviadas e dentro da validade podem ser aceitas.
+// @Tags portal
+// @Security PortalAuth
+// @Param id path int true "ID da cotação"
+func AcceptQuotationHandler(c *gin.Context) {
+	replyQuotation(c, sales.QuotationStatusAccepted)
+}
+
+// Recusa a cotação, com o comentário opcional
+// @Tags portal
+// @Security PortalAuth
+// @Param id path int true "ID da cotação"
+func RejectQuotationHandler(c *gin.Context) {
+	replyQuotation(c, sales.QuotationStatusRejected)
+}
+
+func replyQuotation(c *gin.Context, status string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.QuotationReplyInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	quotation, err := service.ReplyQuotation(c.Request.Context(), contactID(c), id, status, input.Comment, replyOrigin(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao responder cotação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotation": quotation})
+}
+
+// Lista as faturas do cliente com saldo, vencimento e pagamentos recebidos
+// @Tags portal
+// @Security PortalAuth
+func ListInvoicesHandler(c *gin.Context) {
+	invoices, err := service.ListInvoices(c.Request.Context(), contactID(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar faturas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoices": invoices})
+}
+
+// Retorna uma fatura do cliente com os itens e pagamentos
+// @Tags portal
+// @Security PortalAuth
+// @Param id path int true "ID da fatura"
+func GetInvoiceHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invoice, err := service.GetInvoice(c.Request.Context(), contactID(c), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoice": invoice})
+}
+
+// Baixa o PDF da fatura do cliente
+// @Tags portal
+// @Security PortalAuth
+// @Param id path int true "ID da fatura"
+func DownloadInvoicePDFHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	data, filename, err := service.InvoicePDF(c.Request.Context(), contactID(c), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar PDF da fatura")
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// Retorna a cobrança Pix do saldo em aberto da fatura do cliente, com o copia e cola
+// @Tags portal
+// @Security PortalAuth
+// @Param id path int true "ID da fatura"
+func GetInvoicePixHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	charge, err := service.InvoicePix(c.Request.Context(), contactID(c), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar Pix da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pix": charge})
+}
+
+// Retorna o QR code em PNG da cobrança Pix da fatura do cliente
+// @Tags portal
+// @Security PortalAuth
+// @Produce image/png
+// @Param id path int true "ID da fatura"
+func GetInvoicePixQRCodeHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	data, err := service.InvoicePixQRCode(c.Request.Context(), contactID(c), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar QR code do Pix da fatura")
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", data)
+}
+
+// Lista as entregas do cliente com o rastreamento
+// @Tags portal
+// @Security PortalAuth
+func ListDeliveriesHandler(c *gin.Context) {
+	deliveries, err := service.ListDeliveries(c.Request.Context(), contactID(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar entregas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// Abre um chamado de suporte, opcionalmente ligado a uma cotação, fatura ou entrega do cliente
+// @Tags portal
+// @Security PortalAuth
+func CreateTicketHandler(c *gin.Context) {
+	var input models.CreateTicketInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	ticket, err := service.OpenTicket(c.Request.Context(), contactID(c), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao abrir chamado")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"ticket": ticket})
+}
+
+// Lista os chamados de suporte do cliente
+// @Tags portal
+// @Security PortalAuth
+func ListTicketsHandler(c *gin.Context) {
+	tickets, err := service.ListTickets(c.Request.Context(), contactID(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar chamados")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tickets": tickets})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+// contactID retorna o contato do token validado pelo PortalAuthMiddleware
+func contactID(c *gin.Context) int {
+	return c.GetInt("contact_id")
+}
+
+// replyOrigin captura o IP e o navegador do cliente que respondeu a cotação
+func replyOrigin(c *gin.Context) models.ReplyOrigin {
+	return models.ReplyOrigin{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
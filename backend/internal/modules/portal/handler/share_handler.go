@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/portal/models"
+	"ERP-ONSMART/backend/internal/modules/portal/service"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gera o link público assinado da cotação para envio ao cliente; um rascunho passa a enviada
+// @Security BearerAuth
+// @Param id path int true "ID da cotação"
+func ShareQuotationHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ShareQuotationInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	link, err := service.ShareQuotation(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao compartilhar cotação")
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// Lista as respostas do cliente à cotação (aceite ou recusa, comentário, IP e horário)
+// @Security BearerAuth
+// @Param id path int true "ID da cotação"
+func ListQuotationResponsesHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	responses, err := service.ListResponses(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar respostas da cotação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"responses": responses})
+}
+
+// Retorna a cotação do link público, somente leitura
+// Não exige autenticação: o token assinado do link identifica a cotação e a empresa.
+// @Param token path string true "Token do link da cotação"
+func GetSharedQuotationHandler(c *gin.Context) {
+	quotation, err := service.GetSharedQuotation(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar cotação compartilhada")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotation": quotation})
+}
+
+// Aceita a cotação pelo link público, com o comentário opcional
+// @Param token path string true "Token do link da cotação"
+func AcceptSharedQuotationHandler(c *gin.Context) {
+	replySharedQuotation(c, sales.QuotationStatusAccepted)
+}
+
+// Recusa a cotação pelo link público, com o comentário opcional
+// @Param token path string true "Token do link da cotação"
+func RejectSharedQuotationHandler(c *gin.Context) {
+	replySharedQuotation(c, sales.QuotationStatusRejected)
+}
+
+func replySharedQuotation(c *gin.Context, status string) {
+	var input models.QuotationReplyInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	quotation, err := service.ReplySharedQuotation(c.Request.Context(), c.Param("token"), status, input.Comment, replyOrigin(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao responder cotação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotation": quotation})
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+)
+
+// TokenScope é o valor da claim "scope" dos tokens do portal do cliente. Esses tokens só
+// abrem as rotas /portal; o AuthMiddleware das rotas internas os recusa.
+const TokenScope = "portal"
+
+// SessionTTL é a validade do token do portal emitido na troca do código de acesso
+const SessionTTL = 2 * time.Hour
+
+// PortalAccess é um código de acesso ao portal entregue a um contato (cliente). Apenas o hash
+// do código é gravado; o prefixo identifica o código sem expô-lo.
+type PortalAccess struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	CompanyID  int        `json:"company_id" gorm:"<-:create"`
+	ContactID  int        `json:"contact_id" gorm:"index"`
+	Prefix     string     `json:"prefix"`
+	CodeHash   string     `json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedBy  string     `json:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (PortalAccess) TableName() string {
+	return "portal_accesses"
+}
+
+// IsValid indica se o código ainda dá acesso no instante informado
+func (a *PortalAccess) IsValid(now time.Time) bool {
+	return a.RevokedAt == nil && (a.ExpiresAt == nil || a.ExpiresAt.After(now))
+}
+
+// CreateAccessInput são os dados do novo código de acesso
+type CreateAccessInput struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAccess devolve o código em texto claro, exibido somente na criação
+type CreatedAccess struct {
+	Access *PortalAccess `json:"access"`
+	Code   string        `json:"code"`
+}
+
+// SessionRequest troca o código de acesso por um token do portal
+type SessionRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Session é o token do portal emitido para o contato
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ContactID int       `json:"contact_id"`
+}
+
+// Status dos chamados de suporte
+const (
+	TicketStatusOpen     = "open"
+	TicketStatusAnswered = "answered"
+	TicketStatusClosed   = "closed"
+)
+
+// Documentos a que um chamado pode se referir
+const (
+	TicketDocumentQuotation = "quotation"
+	TicketDocumentInvoice   = "invoice"
+	TicketDocumentDelivery  = "delivery"
+)
+
+// SupportTicket é um chamado de suporte aberto pelo cliente no portal
+type SupportTicket struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	CompanyID    int       `json:"company_id" gorm:"<-:create"`
+	ContactID    int       `json:"contact_id" gorm:"index"`
+	Subject      string    `json:"subject"`
+	Message      string    `json:"message"`
+	Status       string    `json:"status" gorm:"default:open"`
+	DocumentType string    `json:"document_type,omitempty"`
+	DocumentID   *int      `json:"document_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (SupportTicket) TableName() string {
+	return "support_tickets"
+}
+
+// CreateTicketInput são os dados do chamado; o documento, quando informado, precisa ser do cliente
+type CreateTicketInput struct {
+	Subject      string `json:"subject" binding:"required,max=200"`
+	Message      string `json:"message" binding:"required"`
+	DocumentType string `json:"document_type" binding:"omitempty,oneof=quotation invoice delivery"`
+	DocumentID   *int   `json:"document_id"`
+}
+
+// QuotationReplyInput é a resposta do cliente à cotação; o comentário é anexado às observações
+type QuotationReplyInput struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+// ReplyOrigin é o IP e o navegador de onde o cliente respondeu, gravados com a resposta
+type ReplyOrigin struct {
+	IPAddress string
+	UserAgent string
+}
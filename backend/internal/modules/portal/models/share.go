@@ -0,0 +1,89 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Canais pelos quais o cliente responde à cotação
+const (
+	ChannelPortal = "portal"
+	ChannelLink   = "link"
+)
+
+// QuotationResponse registra o aceite ou a recusa da cotação pelo cliente, com o comentário, o
+// IP e o instante da resposta
+type QuotationResponse struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	CompanyID   int       `json:"company_id" gorm:"<-:create"`
+	QuotationID int       `json:"quotation_id" gorm:"index"`
+	Decision    string    `json:"decision"`
+	Comment     string    `json:"comment"`
+	Channel     string    `json:"channel"`
+	IPAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	RespondedAt time.Time `json:"responded_at"`
+}
+
+func (QuotationResponse) TableName() string {
+	return "quotation_responses"
+}
+
+// ShareQuotationInput define a validade do link; sem data, vale QUOTATION_SHARE_TTL
+type ShareQuotationInput struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ShareLink é o link público da cotação, enviado ao cliente
+type ShareLink struct {
+	QuotationID int       `json:"quotation_id"`
+	Token       string    `json:"token"`
+	URL         string    `json:"url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// shareTokenDomain separa a assinatura dos links de cotação de outros usos do segredo
+const shareTokenDomain = "quotation-share:"
+
+// SignShareToken assina o link da cotação no formato <cotação>.<empresa>.<expiração>.<assinatura>.
+// O link não é gravado: a assinatura HMAC-SHA256 garante que a cotação, a empresa e a validade
+// não foram alteradas.
+func SignShareToken(secret string, quotationID, companyID int, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d.%d", quotationID, companyID, expiresAt.Unix())
+	return payload + "." + shareSignature(secret, payload)
+}
+
+// ParseShareToken valida a assinatura e a validade do link e retorna a cotação e a empresa
+func ParseShareToken(secret, token string, now time.Time) (quotationID, companyID int, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return 0, 0, errors.ErrInvalidShareLink
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(shareSignature(secret, payload))) {
+		return 0, 0, errors.ErrInvalidShareLink
+	}
+
+	quotationID, errQuotation := strconv.Atoi(parts[0])
+	companyID, errCompany := strconv.Atoi(parts[1])
+	expires, errExpires := strconv.ParseInt(parts[2], 10, 64)
+	if errQuotation != nil || errCompany != nil || errExpires != nil {
+		return 0, 0, errors.ErrInvalidShareLink
+	}
+	if !time.Unix(expires, 0).After(now) {
+		return 0, 0, errors.ErrShareLinkExpired
+	}
+	return quotationID, companyID, nil
+}
+
+func shareSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(shareTokenDomain + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareToken(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	token := SignShareToken("segredo", 7, 3, now.Add(time.Hour))
+
+	quotationID, companyID, err := ParseShareToken("segredo", token, now)
+	require.NoError(t, err)
+	assert.Equal(t, 7, quotationID)
+	assert.Equal(t, 3, companyID)
+
+	_, _, err = ParseShareToken("outro-segredo", token, now)
+	assert.Equal(t, errors.ErrInvalidShareLink, err, "segredo diferente")
+
+	tampered := strings.Replace(token, "7.3.", "8.3.", 1)
+	_, _, err = ParseShareToken("segredo", tampered, now)
+	assert.Equal(t, errors.ErrInvalidShareLink, err, "cotação alterada")
+
+	_, _, err = ParseShareToken("segredo", "abc", now)
+	assert.Equal(t, errors.ErrInvalidShareLink, err, "formato inválido")
+
+	_, _, err = ParseShareToken("segredo", token, now.Add(time.Hour))
+	assert.Equal(t, errors.ErrShareLinkExpired, err)
+}
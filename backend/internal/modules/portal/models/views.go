@@ -0,0 +1,231 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/localtime"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// As visões do portal expõem ao cliente apenas os dados dele de cada documento, sem campos
+// internos (vendedor, campanha, custos, observações internas)
+
+// Item é uma linha de cotação ou fatura vista pelo cliente
+type Item struct {
+	ProductName string        `json:"product_name"`
+	ProductCode string        `json:"product_code"`
+	Description string        `json:"description"`
+	Quantity    int           `json:"quantity"`
+	Unit        string        `json:"unit"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	Discount    money.Decimal `json:"discount"`
+	Tax         money.Decimal `json:"tax"`
+	Total       money.Decimal `json:"total"`
+}
+
+// Quotation é a cotação vista pelo cliente; CanReply indica se ele ainda pode aceitá-la ou recusá-la
+type Quotation struct {
+	ID            int           `json:"id"`
+	QuotationNo   string        `json:"quotation_no"`
+	Status        string        `json:"status"`
+	StatusLabel   string        `json:"status_label"`
+	CreatedAt     time.Time     `json:"created_at"`
+	ExpiryDate    time.Time     `json:"expiry_date"`
+	SubTotal      money.Decimal `json:"subtotal"`
+	TaxTotal      money.Decimal `json:"tax_total"`
+	DiscountTotal money.Decimal `json:"discount_total"`
+	GrandTotal    money.Decimal `json:"grand_total"`
+	Terms         string        `json:"terms"`
+	CanReply      bool          `json:"can_reply"`
+	sales.ShippingOption
+	Items []Item `json:"items,omitempty"`
+}
+
+// Payment é um pagamento recebido da fatura
+type Payment struct {
+	Amount        money.Decimal `json:"amount"`
+	PaymentDate   time.Time     `json:"payment_date"`
+	PaymentMethod string        `json:"payment_method"`
+}
+
+// Invoice é a fatura vista pelo cliente, com o saldo em aberto e os pagamentos recebidos
+type Invoice struct {
+	ID           int           `json:"id"`
+	InvoiceNo    string        `json:"invoice_no"`
+	SONo         string        `json:"so_no"`
+	Status       string        `json:"status"`
+	StatusLabel  string        `json:"status_label"`
+	IssueDate    time.Time     `json:"issue_date"`
+	DueDate      time.Time     `json:"due_date"`
+	GrandTotal   money.Decimal `json:"grand_total"`
+	AmountPaid   money.Decimal `json:"amount_paid"`
+	Balance      money.Decimal `json:"balance"`
+	Overdue      bool          `json:"overdue"`
+	PaymentTerms string        `json:"payment_terms"`
+	Payments     []Payment     `json:"payments"`
+	Items        []Item        `json:"items,omitempty"`
+}
+
+// PixCharge é a cobrança Pix do saldo da fatura: o copia e cola (o mesmo conteúdo do QR code)
+// e a validade
+type PixCharge struct {
+	InvoiceID int           `json:"invoice_id"`
+	Amount    money.Decimal `json:"amount"`
+	CopyPaste string        `json:"copy_paste"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+// TrackingEvent é um evento de rastreamento da entrega
+type TrackingEvent struct {
+	Status      string    `json:"status"`
+	StatusLabel string    `json:"status_label"`
+	Description string    `json:"description"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// Delivery é a entrega vista pelo cliente, com o rastreamento
+type Delivery struct {
+	ID              int             `json:"id"`
+	DeliveryNo      string          `json:"delivery_no"`
+	SONo            string          `json:"so_no"`
+	Status          string          `json:"status"`
+	StatusLabel     string          `json:"status_label"`
+	DeliveryDate    time.Time       `json:"delivery_date"`
+	ShippingMethod  string          `json:"shipping_method"`
+	Carrier         string          `json:"carrier"`
+	TrackingNumber  string          `json:"tracking_number"`
+	ShippingAddress string          `json:"shipping_address"`
+	Events          []TrackingEvent `json:"events"`
+}
+
+// ReplyError indica por que o cliente não pode mais responder à cotação: só cotações enviadas e
+// dentro da validade aceitam resposta. now deve estar no fuso da empresa: a cotação vale até o
+// fim do dia da validade.
+func ReplyError(q *sales.Quotation, now time.Time) error {
+	if q.Status != sales.QuotationStatusSent {
+		return errors.ErrQuotationNotAwaitingReply
+	}
+	if !q.ExpiryDate.IsZero() && localtime.Date(q.ExpiryDate).Before(localtime.Date(now)) {
+		return errors.ErrQuotationExpired
+	}
+	return nil
+}
+
+// QuotationView monta a visão da cotação para o cliente
+func QuotationView(q *sales.Quotation, now time.Time) Quotation {
+	view := Quotation{
+		ID:             q.ID,
+		QuotationNo:    q.QuotationNo,
+		Status:         q.Status,
+		CreatedAt:      q.CreatedAt,
+		ExpiryDate:     q.ExpiryDate,
+		SubTotal:       q.SubTotal,
+		TaxTotal:       q.TaxTotal,
+		DiscountTotal:  q.DiscountTotal,
+		GrandTotal:     q.GrandTotal,
+		Terms:          q.Terms,
+		CanReply:       ReplyError(q, now) == nil,
+		ShippingOption: q.ShippingOption,
+	}
+	for _, item := range q.Items {
+		view.Items = append(view.Items, Item{
+			ProductName: item.ProductName,
+			ProductCode: item.ProductCode,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+			UnitPrice:   item.UnitPrice,
+			Discount:    item.Discount,
+			Tax:         item.Tax,
+			Total:       item.Total,
+		})
+	}
+	return view
+}
+
+// InvoiceView monta a visão da fatura para o cliente. A fatura está vencida quando ainda tem
+// saldo depois do dia do vencimento no fuso de now (o da empresa).
+func InvoiceView(inv *sales.Invoice, now time.Time) Invoice {
+	balance := money.Max(money.Round(inv.GrandTotal.Sub(inv.AmountPaid)), money.Zero)
+	view := Invoice{
+		ID:           inv.ID,
+		InvoiceNo:    inv.InvoiceNo,
+		SONo:         inv.SONo,
+		Status:       inv.Status,
+		IssueDate:    inv.IssueDate,
+		DueDate:      inv.DueDate,
+		GrandTotal:   inv.GrandTotal,
+		AmountPaid:   inv.AmountPaid,
+		Balance:      balance,
+		Overdue:      balance.IsPositive() && inv.Status != sales.InvoiceStatusCancelled && localtime.Date(inv.DueDate).Before(localtime.Date(now)),
+		PaymentTerms: inv.PaymentTerms,
+		Payments:     []Payment{},
+	}
+	for _, payment := range inv.Payments {
+		view.Payments = append(view.Payments, Payment{
+			Amount:        payment.Amount,
+			PaymentDate:   payment.PaymentDate,
+			PaymentMethod: payment.PaymentMethod,
+		})
+	}
+	for _, item := range inv.Items {
+		view.Items = append(view.Items, Item{
+			ProductName: item.ProductName,
+			ProductCode: item.ProductCode,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			Unit:        item.Unit,
+			UnitPrice:   item.UnitPrice,
+			Discount:    item.Discount,
+			Tax:         item.Tax,
+			Total:       item.Total,
+		})
+	}
+	return view
+}
+
+// DeliveryView monta a visão da entrega com os eventos de rastreamento
+func DeliveryView(d *sales.Delivery, events []sales.DeliveryTrackingEvent) Delivery {
+	view := Delivery{
+		ID:              d.ID,
+		DeliveryNo:      d.DeliveryNo,
+		SONo:            d.SONo,
+		Status:          d.Status,
+		DeliveryDate:    d.DeliveryDate,
+		ShippingMethod:  d.ShippingMethod,
+		Carrier:         d.Carrier,
+		TrackingNumber:  d.TrackingNumber,
+		ShippingAddress: d.ShippingAddress,
+		Events:          []TrackingEvent{},
+	}
+	for _, event := range events {
+		view.Events = append(view.Events, TrackingEvent{
+			Status:      event.Status,
+			Description: event.Description,
+			Location:    event.Location,
+			OccurredAt:  event.OccurredAt,
+		})
+	}
+	return view
+}
+
+// Localize preenche o rótulo do status no idioma do cliente
+func (q *Quotation) Localize(lang string) {
+	q.StatusLabel = i18n.Label(lang, i18n.QuotationStatus, q.Status)
+}
+
+// Localize preenche o rótulo do status no idioma do cliente
+func (inv *Invoice) Localize(lang string) {
+	inv.StatusLabel = i18n.Label(lang, i18n.InvoiceStatus, inv.Status)
+}
+
+// Localize preenche os rótulos do status da entrega e dos eventos no idioma do cliente
+func (d *Delivery) Localize(lang string) {
+	d.StatusLabel = i18n.Label(lang, i18n.DeliveryStatus, d.Status)
+	for i := range d.Events {
+		d.Events[i].StatusLabel = i18n.Label(lang, i18n.TrackingStatus, d.Events[i].Status)
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplyError(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	quotation := &sales.Quotation{Status: sales.QuotationStatusSent, ExpiryDate: now.AddDate(0, 0, 5)}
+	assert.NoError(t, ReplyError(quotation, now))
+
+	quotation.ExpiryDate = now.AddDate(0, 0, -1)
+	assert.Equal(t, errors.ErrQuotationExpired, ReplyError(quotation, now))
+
+	quotation.Status = sales.QuotationStatusAccepted
+	assert.Equal(t, errors.ErrQuotationNotAwaitingReply, ReplyError(quotation, now), "cotação já respondida")
+}
+
+func TestReplyErrorUsesCompanyDay(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("tzdata indisponível")
+	}
+	// 23h de 10/05 em São Paulo já é 11/05 em UTC: a cotação com validade em 10/05 ainda vale
+	now := time.Date(2026, 5, 11, 2, 0, 0, 0, time.UTC).In(saoPaulo)
+	quotation := &sales.Quotation{Status: sales.QuotationStatusSent, ExpiryDate: time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)}
+	assert.NoError(t, ReplyError(quotation, now))
+
+	invoice := &sales.Invoice{Status: sales.InvoiceStatusSent, DueDate: quotation.ExpiryDate, GrandTotal: money.FromInt(100)}
+	assert.False(t, InvoiceView(invoice, now).Overdue, "vence no fim do dia da empresa")
+	assert.True(t, InvoiceView(invoice, now.Add(time.Hour)).Overdue)
+}
+
+func TestInvoiceView(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	invoice := &sales.Invoice{
+		ID:         3,
+		InvoiceNo:  "INV-2026-000003",
+		Status:     sales.InvoiceStatusPartial,
+		DueDate:    now.AddDate(0, 0, -2),
+		GrandTotal: money.FromInt(150),
+		AmountPaid: money.MustParse("100.1"),
+		Payments:   []sales.Payment{{Amount: money.MustParse("100.1"), PaymentMethod: "pix", Reference: "interno"}},
+	}
+
+	view := InvoiceView(invoice, now)
+	assert.Equal(t, "49.90", view.Balance.String())
+	assert.True(t, view.Overdue)
+	assert.Equal(t, []Payment{{Amount: money.MustParse("100.1"), PaymentMethod: "pix"}}, view.Payments)
+
+	invoice.AmountPaid = money.FromInt(150)
+	view = InvoiceView(invoice, now)
+	assert.Zero(t, view.Balance)
+	assert.False(t, view.Overdue, "fatura quitada não fica vencida")
+}
@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/portal/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	users "ERP-ONSMART/backend/internal/modules/users/models"
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PortalRepository define os códigos de acesso ao portal e as consultas do cliente. Todas as
+// consultas de documentos filtram pelo contato do token, além da empresa.
+type PortalRepository interface {
+	GetContact(ctx context.Context, contactID int) (*contact.Contact, error)
+	CreateAccess(ctx context.Context, access *models.PortalAccess) error
+	ListAccesses(ctx context.Context, contactID int) ([]models.PortalAccess, error)
+	RevokeAccesses(ctx context.Context, contactID int, now time.Time) (int64, error)
+	FindAccessByHash(ctx context.Context, codeHash string) (*models.PortalAccess, error)
+	TouchAccess(ctx context.Context, id int, now time.Time) error
+
+	ListQuotations(ctx context.Context, contactID int) ([]sales.Quotation, error)
+	GetQuotation(ctx context.Context, contactID, id int) (*sales.Quotation, error)
+	ReplyQuotation(ctx context.Context, contactID, id int, response *models.QuotationResponse) (*sales.Quotation, error)
+	ShareQuotation(ctx context.Context, id int) (*sales.Quotation, error)
+	GetSharedQuotation(ctx context.Context, id int) (*sales.Quotation, error)
+	ListResponses(ctx context.Context, quotationID int) ([]models.QuotationResponse, error)
+	GetUser(ctx context.Context, userID int) (*users.User, error)
+	ListInvoices(ctx context.Context, contactID int) ([]sales.Invoice, error)
+	GetInvoice(ctx context.Context, contactID, id int) (*sales.Invoice, error)
+	ListDeliveries(ctx context.Context, contactID int) ([]sales.Delivery, error)
+	GetTrackingEvents(ctx context.Context, deliveryIDs []int) (map[int][]sales.DeliveryTrackingEvent, error)
+	DocumentBelongsTo(ctx context.Context, contactID int, documentType string, documentID int) (bool, error)
+	CreateTicket(ctx context.Context, ticket *models.SupportTicket) error
+	ListTickets(ctx context.Context, contactID int) ([]models.SupportTicket, error)
+}
+
+type portalRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewPortalRepository cria uma nova instância do repositório
+func NewPortalRepository() (PortalRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &portalRepository{
+		db:     db,
+		logger: logger.WithModule("portal_repository"),
+	}, nil
+}
+
+// GetContact busca o contato que recebe o acesso ao portal
+func (r *portalRepository) GetContact(ctx context.Context, contactID int) (*contact.Contact, error) {
+	var c contact.Contact
+	if err := r.db.WithContext(ctx).First(&c, contactID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrContactNotFound
+		}
+		r.logger.Error("erro ao buscar contato", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao buscar contato")
+	}
+	return &c, nil
+}
+
+// CreateAccess grava o novo código de acesso
+func (r *portalRepository) CreateAccess(ctx context.Context, access *models.PortalAccess) error {
+	if err := r.db.WithContext(ctx).Create(access).Error; err != nil {
+		r.logger.Error("erro ao criar acesso ao portal", zap.Error(err), zap.Int("contact_id", access.ContactID))
+		return errors.WrapError(err, "falha ao criar acesso ao portal")
+	}
+	return nil
+}
+
+// ListAccesses lista os códigos de acesso do contato, mais recentes primeiro
+func (r *portalRepository) ListAccesses(ctx context.Context, contactID int) ([]models.PortalAccess, error) {
+	var accesses []models.PortalAccess
+	if err := r.db.WithContext(ctx).Where("contact_id = ?", contactID).Order("created_at DESC").Find(&accesses).Error; err != nil {
+		r.logger.Error("erro ao listar acessos ao portal", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao listar acessos ao portal")
+	}
+	return accesses, nil
+}
+
+// RevokeAccesses revoga todos os códigos ativos do contato e retorna quantos foram revogados
+func (r *portalRepository) RevokeAccesses(ctx context.Context, contactID int, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.PortalAccess{}).
+		Where("contact_id = ? AND revoked_at IS NULL", contactID).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		r.logger.Error("erro ao revogar acessos ao portal", zap.Error(result.Error), zap.Int("contact_id", contactID))
+		return 0, errors.WrapError(result.Error, "falha ao revogar acessos ao portal")
+	}
+	return result.RowsAffected, nil
+}
+
+// FindAccessByHash busca o código de acesso pelo hash, usado na troca pelo token do portal
+func (r *portalRepository) FindAccessByHash(ctx context.Context, codeHash string) (*models.PortalAccess, error) {
+	var access models.PortalAccess
+	if err := r.db.WithContext(ctx).Where("code_hash = ?", codeHash).First(&access).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidPortalAccess
+		}
+		r.logger.Error("erro ao buscar acesso ao portal pelo hash", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao validar acesso ao portal")
+	}
+	return &access, nil
+}
+
+// TouchAccess registra o último uso do código de acesso
+func (r *portalRepository) TouchAccess(ctx context.Context, id int, now time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&models.PortalAccess{}).Where("id = ?", id).Update("last_used_at", now).Error; err != nil {
+		return errors.WrapError(err, "falha ao registrar uso do acesso ao portal")
+	}
+	return nil
+}
+
+// ListQuotations lista as cotações do cliente já enviadas a ele (rascunhos ficam de fora)
+func (r *portalRepository) ListQuotations(ctx context.Context, contactID int) ([]sales.Quotation, error) {
+	var quotations []sales.Quotation
+	if err := r.db.WithContext(ctx).
+		Where("contact_id = ? AND status <> ?", contactID, sales.QuotationStatusDraft).
+		Order("created_at DESC").
+		Find(&quotations).Error; err != nil {
+		r.logger.Error("erro ao listar cotações do portal", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao listar cotações")
+	}
+	return quotations, nil
+}
+
+// GetQuotation busca uma cotação do cliente com os itens
+func (r *portalRepository) GetQuotation(ctx context.Context, contactID, id int) (*sales.Quotation, error) {
+	var quotation sales.Quotation
+	if err := r.db.WithContext(ctx).Preload("Items").Preload("Contact").
+		Where("id = ? AND contact_id = ? AND status <> ?", id, contactID, sales.QuotationStatusDraft).
+		First(&quotation).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrQuotationNotFound
+		}
+		r.logger.Error("erro ao buscar cotação do portal", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar cotação")
+	}
+	return &quotation, nil
+}
+
+// ReplyQuotation grava o aceite ou a recusa do cliente e o registro da resposta; o comentário
+// também é anexado às observações da cotação
+func (r *portalRepository) ReplyQuotation(ctx context.Context, contactID, id int, response *models.QuotationResponse) (*sales.Quotation, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var quotation sales.Quotation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND contact_id = ? AND status <> ?", id, contactID, sales.QuotationStatusDraft).
+			First(&quotation).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrQuotationNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar cotação")
+		}
+		if err := models.ReplyError(&quotation, response.RespondedAt.In(localtime.Location(ctx))); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": response.Decision}
+		if comment := strings.TrimSpace(response.Comment); comment != "" {
+			note := "Resposta do cliente (" + response.Channel + "): " + comment
+			if quotation.Notes != "" {
+				note = quotation.Notes + "\n" + note
+			}
+			updates["notes"] = note
+		}
+		if err := tx.Model(&quotation).Updates(updates).Error; err != nil {
+			r.logger.Error("erro ao gravar resposta da cotação", zap.Error(err), zap.Int("id", id))
+			return errors.WrapError(err, "falha ao gravar resposta da cotação")
+		}
+
+		response.QuotationID = id
+		if err := tx.Create(response).Error; err != nil {
+			r.logger.Error("erro ao registrar resposta da cotação", zap.Error(err), zap.Int("id", id))
+			return errors.WrapError(err, "falha ao registrar resposta da cotação")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("cotação respondida pelo cliente", zap.Int("id", id), zap.Int("contact_id", contactID),
+		zap.String("decision", response.Decision), zap.String("channel", response.Channel))
+	return r.GetQuotation(ctx, contactID, id)
+}
+
+// ShareQuotation prepara a cotação para o link público: um rascunho passa a enviada; cotações
+// já respondidas, vencidas ou canceladas não são compartilhadas
+func (r *portalRepository) ShareQuotation(ctx context.Context, id int) (*sales.Quotation, error) {
+	var quotation sales.Quotation
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&quotation, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrQuotationNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar cotação")
+		}
+
+		switch quotation.Status {
+		case sales.QuotationStatusSent:
+			return nil
+		case sales.QuotationStatusDraft:
+			if err := tx.Model(&quotation).Update("status", sales.QuotationStatusSent).Error; err != nil {
+				r.logger.Error("erro ao marcar cotação como enviada", zap.Error(err), zap.Int("id", id))
+				return errors.WrapError(err, "falha ao marcar cotação como enviada")
+			}
+			return nil
+		default:
+			return errors.ErrQuotationNotShareable
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &quotation, nil
+}
+
+// GetSharedQuotation busca a cotação do link público com os itens
+func (r *portalRepository) GetSharedQuotation(ctx context.Context, id int) (*sales.Quotation, error) {
+	var quotation sales.Quotation
+	if err := r.db.WithContext(ctx).Preload("Items").Preload("Contact").
+		Where("id = ? AND status <> ?", id, sales.QuotationStatusDraft).
+		First(&quotation).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidShareLink
+		}
+		r.logger.Error("erro ao buscar cotação compartilhada", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar cotação")
+	}
+	return &quotation, nil
+}
+
+// ListResponses lista as respostas do cliente à cotação, mais recentes primeiro
+func (r *portalRepository) ListResponses(ctx context.Context, quotationID int) ([]models.QuotationResponse, error) {
+	db := r.db.WithContext(ctx)
+
+	var count int64
+	if err := db.Model(&sales.Quotation{}).Where("id = ?", quotationID).Count(&count).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar cotação")
+	}
+	if count == 0 {
+		return nil, errors.ErrQuotationNotFound
+	}
+
+	var responses []models.QuotationResponse
+	if err := db.Where("quotation_id = ?", quotationID).Order("responded_at DESC").Find(&responses).Error; err != nil {
+		r.logger.Error("erro ao listar respostas da cotação", zap.Error(err), zap.Int("quotation_id", quotationID))
+		return nil, errors.WrapError(err, "falha ao listar respostas da cotação")
+	}
+	return responses, nil
+}
+
+// GetUser busca o usuário (vendedor) notificado das respostas
+func (r *portalRepository) GetUser(ctx context.Context, userID int) (*users.User, error) {
+	var user users.User
+	if err := r.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar usuário")
+	}
+	return &user, nil
+}
+
+// ListInvoices lista as faturas do cliente com os pagamentos recebidos
+func (r *portalRepository) ListInvoices(ctx context.Context, contactID int) ([]sales.Invoice, error) {
+	var invoices []sales.Invoice
+	if err := r.db.WithContext(ctx).Preload("Payments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("payment_date ASC")
+	}).Where("contact_id = ? AND status <> ?", contactID, sales.InvoiceStatusDraft).
+		Order("issue_date DESC, id DESC").
+		Find(&invoices).Error; err != nil {
+		r.logger.Error("erro ao listar faturas do portal", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao listar faturas")
+	}
+	return invoices, nil
+}
+
+// GetInvoice busca uma fatura do cliente com itens, pagamentos e dados do contato
+func (r *portalRepository) GetInvoice(ctx context.Context, contactID, id int) (*sales.Invoice, error) {
+	var invoice sales.Invoice
+	if err := r.db.WithContext(ctx).Preload("Items").Preload("Contact").Preload("Payments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("payment_date ASC")
+	}).Where("id = ? AND contact_id = ? AND status <> ?", id, contactID, sales.InvoiceStatusDraft).
+		First(&invoice).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		r.logger.Error("erro ao buscar fatura do portal", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar fatura")
+	}
+	return &invoice, nil
+}
+
+// ListDeliveries lista as entregas dos pedidos de venda do cliente
+func (r *portalRepository) ListDeliveries(ctx context.Context, contactID int) ([]sales.Delivery, error) {
+	var deliveries []sales.Delivery
+	if err := r.db.WithContext(ctx).
+		Where("sales_order_id IN (?)", r.db.WithContext(ctx).Model(&sales.SalesOrder{}).Select("id").Where("contact_id = ?", contactID)).
+		Order("created_at DESC").
+		Find(&deliveries).Error; err != nil {
+		r.logger.Error("erro ao listar entregas do portal", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao listar entregas")
+	}
+	return deliveries, nil
+}
+
+// GetTrackingEvents busca os eventos de rastreamento das entregas, do mais recente ao mais antigo
+func (r *portalRepository) GetTrackingEvents(ctx context.Context, deliveryIDs []int) (map[int][]sales.DeliveryTrackingEvent, error) {
+	byDelivery := make(map[int][]sales.DeliveryTrackingEvent, len(deliveryIDs))
+	if len(deliveryIDs) == 0 {
+		return byDelivery, nil
+	}
+
+	var events []sales.DeliveryTrackingEvent
+	if err := r.db.WithContext(ctx).Where("delivery_id IN ?", deliveryIDs).
+		Order("occurred_at DESC").Find(&events).Error; err != nil {
+		r.logger.Error("erro ao buscar eventos de rastreamento do portal", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar eventos de rastreamento")
+	}
+	for _, event := range events {
+		byDelivery[event.DeliveryID] = append(byDelivery[event.DeliveryID], event)
+	}
+	return byDelivery, nil
+}
+
+// DocumentBelongsTo indica se o documento referenciado no chamado é do cliente
+func (r *portalRepository) DocumentBelongsTo(ctx context.Context, contactID int, documentType string, documentID int) (bool, error) {
+	db := r.db.WithContext(ctx)
+	var query *gorm.DB
+	switch documentType {
+	case models.TicketDocumentQuotation:
+		query = db.Model(&sales.Quotation{}).Where("id = ? AND contact_id = ?", documentID, contactID)
+	case models.TicketDocumentInvoice:
+		query = db.Model(&sales.Invoice{}).Where("id = ? AND contact_id = ?", documentID, contactID)
+	case models.TicketDocumentDelivery:
+		query = db.Model(&sales.Delivery{}).Where("id = ? AND sales_order_id IN (?)", documentID,
+			db.Model(&sales.SalesOrder{}).Select("id").Where("contact_id = ?", contactID))
+	default:
+		return false, nil
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Error("erro ao verificar documento do chamado", zap.Error(err), zap.String("document_type", documentType))
+		return false, errors.WrapError(err, "falha ao verificar documento do chamado")
+	}
+	return count > 0, nil
+}
+
+// CreateTicket grava o chamado aberto pelo cliente
+func (r *portalRepository) CreateTicket(ctx context.Context, ticket *models.SupportTicket) error {
+	if err := r.db.WithContext(ctx).Create(ticket).Error; err != nil {
+		r.logger.Error("erro ao abrir chamado", zap.Error(err), zap.Int("contact_id", ticket.ContactID))
+		return errors.WrapError(err, "falha ao abrir chamado")
+	}
+	return nil
+}
+
+// ListTickets lista os chamados do cliente, mais recentes primeiro
+func (r *portalRepository) ListTickets(ctx context.Context, contactID int) ([]models.SupportTicket, error) {
+	var tickets []models.SupportTicket
+	if err := r.db.WithContext(ctx).Where("contact_id = ?", contactID).Order("created_at DESC").Find(&tickets).Error; err != nil {
+		r.logger.Error("erro ao listar chamados", zap.Error(err), zap.Int("contact_id", contactID))
+		return nil, errors.WrapError(err, "falha ao listar chamados")
+	}
+	return tickets, nil
+}
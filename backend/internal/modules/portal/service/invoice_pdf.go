@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/barcode"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/modules/portal/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/pdf"
+)
+
+// Colunas da tabela de itens da fatura, em pontos a partir da margem
+const (
+	colProduct  = 0
+	colQuantity = 270
+	colPrice    = 330
+	colTotal    = 420
+)
+
+// Lado do QR code do Pix no PDF, em pontos, e caracteres do copia e cola por linha
+const (
+	pixQRSize      = 120
+	pixPayloadLine = 90
+)
+
+// renderInvoicePDF gera o PDF A4 da fatura no idioma informado com cliente, itens, totais,
+// pagamentos recebidos e, com pixPayload, o QR code e o copia e cola do Pix, terminando com as
+// linhas do rodapé (modelo invoice.footer)
+func renderInvoicePDF(invoice *sales.Invoice, view models.Invoice, footer []string, pixPayload, lang string) ([]byte, error) {
+	text := func(key string) string { return i18n.Text(lang, key) }
+	layout := i18n.DateLayout(lang)
+
+	doc := pdf.NewDocument()
+	doc.Line(text(i18n.DocInvoice)+" "+invoice.InvoiceNo, 16, true)
+	doc.Space(4)
+	doc.Line(text(i18n.DocIssueDate)+": "+formatDate(invoice, layout), 10, false)
+	doc.Line(text(i18n.DocDueDate)+": "+invoice.DueDate.Format(layout), 10, false)
+	if invoice.SONo != "" {
+		doc.Line(text(i18n.DocOrder)+": "+invoice.SONo, 10, false)
+	}
+	if invoice.Contact != nil {
+		doc.Space(6)
+		doc.Line(text(i18n.DocCustomer), 11, true)
+		doc.Line(invoice.Contact.Name, 10, false)
+		if invoice.Contact.Document != "" {
+			doc.Line(text(i18n.DocDocument)+": "+invoice.Contact.Document, 10, false)
+		}
+	}
+
+	doc.Space(8)
+	doc.Row(10, true,
+		pdf.Column{X: colProduct, Text: text(i18n.DocProduct)},
+		pdf.Column{X: colQuantity, Text: text(i18n.DocQuantity)},
+		pdf.Column{X: colPrice, Text: text(i18n.DocUnitPrice)},
+		pdf.Column{X: colTotal, Text: text(i18n.DocTotal)},
+	)
+	doc.Rule()
+	for _, item := range invoice.Items {
+		doc.Row(10, false,
+			pdf.Column{X: colProduct, Text: truncate(item.ProductName, 48)},
+			pdf.Column{X: colQuantity, Text: fmt.Sprintf("%d %s", item.Quantity, item.Unit)},
+			pdf.Column{X: colPrice, Text: formatMoney(lang, item.UnitPrice)},
+			pdf.Column{X: colTotal, Text: formatMoney(lang, item.Total)},
+		)
+	}
+	doc.Rule()
+
+	totals := []struct {
+		label string
+		value money.Decimal
+		bold  bool
+	}{
+		{i18n.DocSubtotal, invoice.SubTotal, false},
+		{i18n.DocDiscounts, invoice.DiscountTotal, false},
+		{i18n.DocTaxes, invoice.TaxTotal, false},
+		{i18n.DocTotal, invoice.GrandTotal, true},
+		{i18n.DocPaid, view.AmountPaid, false},
+		{i18n.DocBalance, view.Balance, true},
+	}
+	for _, total := range totals {
+		doc.Row(10, total.bold,
+			pdf.Column{X: colPrice, Text: text(total.label)},
+			pdf.Column{X: colTotal, Text: formatMoney(lang, total.value)},
+		)
+	}
+
+	if len(view.Payments) > 0 {
+		doc.Space(8)
+		doc.Line(text(i18n.DocPaymentsReceived), 11, true)
+		for _, payment := range view.Payments {
+			doc.Line(fmt.Sprintf("%s  %s  %s", payment.PaymentDate.Format(layout), payment.PaymentMethod, formatMoney(lang, payment.Amount)), 10, false)
+		}
+	}
+	if pixPayload != "" {
+		symbol, err := barcode.QR(pixPayload)
+		if err != nil {
+			return nil, err
+		}
+		doc.Space(8)
+		doc.Line(text(i18n.DocPayWithPix), 11, true)
+		doc.Space(4)
+		doc.Block(pixQRSize, func(b *bytes.Buffer, x, y float64) {
+			symbol.DrawPDF(b, x, y, pixQRSize)
+		})
+		doc.Line(text(i18n.DocPixCopyPaste)+":", 9, true)
+		for start := 0; start < len(pixPayload); start += pixPayloadLine {
+			doc.Line(pixPayload[start:min(start+pixPayloadLine, len(pixPayload))], 8, false)
+		}
+	}
+	if len(footer) > 0 {
+		doc.Space(8)
+		for _, line := range footer {
+			doc.Line(line, 10, false)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func formatDate(invoice *sales.Invoice, layout string) string {
+	if invoice.IssueDate.IsZero() {
+		return invoice.CreatedAt.Format(layout)
+	}
+	return invoice.IssueDate.Format(layout)
+}
+
+// formatMoney formata o valor em reais com os separadores do idioma: R$ 1.234,56 ou R$ 1,234.56
+func formatMoney(lang string, value money.Decimal) string {
+	thousands, decimal := ".", ","
+	if lang == i18n.EnUS {
+		thousands, decimal = ",", "."
+	}
+	sign := ""
+	if value.IsNegative() {
+		sign = "-"
+		value = value.Neg()
+	}
+	text := value.StringFixed(2)
+	integer, cents, _ := strings.Cut(text, ".")
+
+	var b strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(thousands)
+		}
+		b.WriteRune(digit)
+	}
+	return "R$ " + sign + b.String() + decimal + cents
+}
+
+// truncate corta o texto para caber na coluna
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,558 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/mailer"
+	banking "ERP-ONSMART/backend/internal/modules/banking/models"
+	bankingService "ERP-ONSMART/backend/internal/modules/banking/service"
+	contactModels "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/portal/models"
+	"ERP-ONSMART/backend/internal/modules/portal/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	templateService "ERP-ONSMART/backend/internal/modules/templates/service"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// CodePrefix identifica os códigos de acesso ao portal do cliente
+const CodePrefix = "ptl_"
+
+// prefixLength é o trecho inicial do código exibido nas listagens
+const prefixLength = len(CodePrefix) + 8
+
+// Service emite os acessos ao portal e atende as consultas do cliente
+type Service struct {
+	newRepo  func() (repository.PortalRepository, error)
+	now      func() time.Time
+	location func(ctx context.Context) *time.Location
+	secret   func() string
+	send     func(mailer.Message) error
+	render   func(ctx context.Context, key string, data templates.Data) (*templates.Rendered, error)
+	pix      func(ctx context.Context, invoiceID int) (*banking.PixCharge, error)
+	logger   *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.PortalRepository
+}
+
+// NewService cria o serviço sobre o repositório informado
+func NewService(newRepo func() (repository.PortalRepository, error)) *Service {
+	return &Service{
+		newRepo:  newRepo,
+		now:      time.Now,
+		location: localtime.Location,
+		secret:   func() string { return viper.GetString("JWT_SECRET") },
+		send:     mailer.Send,
+		render:   templateService.Render,
+		pix:      portalPixCharge,
+		logger:   logger.WithModule("portal_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewPortalRepository)
+
+// CreateAccess emite um código de acesso ao portal para o contato
+func CreateAccess(ctx context.Context, contactID int, input models.CreateAccessInput, createdBy string) (*models.CreatedAccess, error) {
+	return defaultService.CreateAccess(ctx, contactID, input, createdBy)
+}
+
+// ListAccesses lista os códigos de acesso do contato
+func ListAccesses(ctx context.Context, contactID int) ([]models.PortalAccess, error) {
+	return defaultService.ListAccesses(ctx, contactID)
+}
+
+// RevokeAccesses revoga os códigos de acesso do contato
+func RevokeAccesses(ctx context.Context, contactID int) (int64, error) {
+	return defaultService.RevokeAccesses(ctx, contactID)
+}
+
+// StartSession troca o código de acesso por um token do portal
+func StartSession(ctx context.Context, code string) (*models.Session, error) {
+	return defaultService.StartSession(ctx, code)
+}
+
+// ListQuotations lista as cotações do cliente
+func ListQuotations(ctx context.Context, contactID int) ([]models.Quotation, error) {
+	return defaultService.ListQuotations(ctx, contactID)
+}
+
+// GetQuotation busca uma cotação do cliente
+func GetQuotation(ctx context.Context, contactID, id int) (*models.Quotation, error) {
+	return defaultService.GetQuotation(ctx, contactID, id)
+}
+
+// ReplyQuotation registra o aceite (accepted) ou a recusa (rejected) da cotação pelo cliente
+func ReplyQuotation(ctx context.Context, contactID, id int, status, comment string, origin models.ReplyOrigin) (*models.Quotation, error) {
+	return defaultService.ReplyQuotation(ctx, contactID, id, status, comment, origin)
+}
+
+// ListInvoices lista as faturas do cliente com a situação do pagamento
+func ListInvoices(ctx context.Context, contactID int) ([]models.Invoice, error) {
+	return defaultService.ListInvoices(ctx, contactID)
+}
+
+// GetInvoice busca uma fatura do cliente
+func GetInvoice(ctx context.Context, contactID, id int) (*models.Invoice, error) {
+	return defaultService.GetInvoice(ctx, contactID, id)
+}
+
+// InvoicePDF gera o PDF da fatura do cliente e retorna o nome do arquivo
+func InvoicePDF(ctx context.Context, contactID, id int) ([]byte, string, error) {
+	return defaultService.InvoicePDF(ctx, contactID, id)
+}
+
+// InvoicePix retorna a cobrança Pix do saldo em aberto da fatura do cliente
+func InvoicePix(ctx context.Context, contactID, id int) (*models.PixCharge, error) {
+	return defaultService.InvoicePix(ctx, contactID, id)
+}
+
+// InvoicePixQRCode retorna o QR code em PNG da cobrança Pix da fatura do cliente
+func InvoicePixQRCode(ctx context.Context, contactID, id int) ([]byte, error) {
+	return defaultService.InvoicePixQRCode(ctx, contactID, id)
+}
+
+// ListDeliveries lista as entregas do cliente com o rastreamento
+func ListDeliveries(ctx context.Context, contactID int) ([]models.Delivery, error) {
+	return defaultService.ListDeliveries(ctx, contactID)
+}
+
+// OpenTicket abre um chamado de suporte do cliente
+func OpenTicket(ctx context.Context, contactID int, input models.CreateTicketInput) (*models.SupportTicket, error) {
+	return defaultService.OpenTicket(ctx, contactID, input)
+}
+
+// ListTickets lista os chamados do cliente
+func ListTickets(ctx context.Context, contactID int) ([]models.SupportTicket, error) {
+	return defaultService.ListTickets(ctx, contactID)
+}
+
+func (s *Service) repository() (repository.PortalRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// CreateAccess gera o código e grava apenas o hash; o código em texto claro só é devolvido
+// nesta resposta, para ser enviado ao cliente
+func (s *Service) CreateAccess(ctx context.Context, contactID int, input models.CreateAccessInput, createdBy string) (*models.CreatedAccess, error) {
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return nil, errors.InvalidParam("expires_at deve ser uma data futura")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GetContact(ctx, contactID); err != nil {
+		return nil, err
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return nil, err
+	}
+	access := &models.PortalAccess{
+		ContactID: contactID,
+		Prefix:    code[:prefixLength],
+		CodeHash:  hashCode(code),
+		ExpiresAt: input.ExpiresAt,
+		CreatedBy: createdBy,
+	}
+	if err := repo.CreateAccess(ctx, access); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("acesso ao portal criado", zap.Int("id", access.ID), zap.Int("contact_id", contactID),
+		zap.String("prefix", access.Prefix), zap.String("created_by", createdBy))
+	return &models.CreatedAccess{Access: access, Code: code}, nil
+}
+
+// ListAccesses lista os códigos de acesso do contato
+func (s *Service) ListAccesses(ctx context.Context, contactID int) ([]models.PortalAccess, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GetContact(ctx, contactID); err != nil {
+		return nil, err
+	}
+	return repo.ListAccesses(ctx, contactID)
+}
+
+// RevokeAccesses revoga os códigos ativos do contato. Os tokens já emitidos continuam válidos
+// até expirarem (SessionTTL).
+func (s *Service) RevokeAccesses(ctx context.Context, contactID int) (int64, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := repo.GetContact(ctx, contactID); err != nil {
+		return 0, err
+	}
+	revoked, err := repo.RevokeAccesses(ctx, contactID, s.now())
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("acessos ao portal revogados", zap.Int("contact_id", contactID), zap.Int64("revoked", revoked))
+	return revoked, nil
+}
+
+// StartSession busca o código pelo hash em todas as empresas, recusa códigos revogados ou
+// expirados e emite o token do portal com o contato e a empresa do código
+func (s *Service) StartSession(ctx context.Context, code string) (*models.Session, error) {
+	code = strings.TrimSpace(code)
+	if !strings.HasPrefix(code, CodePrefix) {
+		return nil, errors.ErrInvalidPortalAccess
+	}
+	secret := s.secret()
+	if secret == "" {
+		return nil, errors.NewAPIError(http.StatusInternalServerError, errors.CodeInternal, "chave JWT não configurada")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = tenant.AllCompanies(ctx)
+	access, err := repo.FindAccessByHash(ctx, hashCode(code))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !access.IsValid(now) {
+		return nil, errors.ErrInvalidPortalAccess
+	}
+
+	expiresAt := now.Add(models.SessionTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"scope":      models.TokenScope,
+		"sub":        fmt.Sprintf("contact:%d", access.ContactID),
+		"contact_id": access.ContactID,
+		"company_id": access.CompanyID,
+		"exp":        expiresAt.Unix(),
+	})
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao gerar token do portal")
+	}
+
+	// Falha no registro de uso não impede o acesso
+	if err := repo.TouchAccess(ctx, access.ID, now); err != nil {
+		s.logger.Warn("erro ao registrar uso do acesso ao portal", zap.Error(err), zap.Int("id", access.ID))
+	}
+	return &models.Session{Token: signed, ExpiresAt: expiresAt, ContactID: access.ContactID}, nil
+}
+
+// ListQuotations lista as cotações enviadas ao cliente
+func (s *Service) ListQuotations(ctx context.Context, contactID int) ([]models.Quotation, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	quotations, err := repo.ListQuotations(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, lang := s.localNow(ctx), i18n.FromContext(ctx)
+	views := make([]models.Quotation, 0, len(quotations))
+	for i := range quotations {
+		view := models.QuotationView(&quotations[i], now)
+		view.Localize(lang)
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// GetQuotation busca uma cotação do cliente com os itens
+func (s *Service) GetQuotation(ctx context.Context, contactID, id int) (*models.Quotation, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	quotation, err := repo.GetQuotation(ctx, contactID, id)
+	if err != nil {
+		return nil, err
+	}
+	view := s.quotationView(ctx, quotation, s.now())
+	return &view, nil
+}
+
+// ReplyQuotation registra o aceite ou a recusa; só cotações enviadas e dentro da validade aceitam
+// resposta. O vendedor da cotação é avisado por e-mail.
+func (s *Service) ReplyQuotation(ctx context.Context, contactID, id int, status, comment string, origin models.ReplyOrigin) (*models.Quotation, error) {
+	response, err := s.newResponse(status, comment, models.ChannelPortal, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	quotation, err := repo.ReplyQuotation(ctx, contactID, id, response)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifySalesperson(ctx, repo, quotation, response)
+	view := s.quotationView(ctx, quotation, response.RespondedAt)
+	return &view, nil
+}
+
+// ListInvoices lista as faturas do cliente com saldo e pagamentos
+func (s *Service) ListInvoices(ctx context.Context, contactID int) ([]models.Invoice, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	invoices, err := repo.ListInvoices(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, lang := s.localNow(ctx), i18n.FromContext(ctx)
+	views := make([]models.Invoice, 0, len(invoices))
+	for i := range invoices {
+		view := models.InvoiceView(&invoices[i], now)
+		view.Localize(lang)
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// GetInvoice busca uma fatura do cliente com os itens
+func (s *Service) GetInvoice(ctx context.Context, contactID, id int) (*models.Invoice, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	invoice, err := repo.GetInvoice(ctx, contactID, id)
+	if err != nil {
+		return nil, err
+	}
+	view := models.InvoiceView(invoice, s.localNow(ctx))
+	view.Localize(i18n.FromContext(ctx))
+	return &view, nil
+}
+
+// InvoicePDF gera o PDF da fatura no idioma preferido do cliente ou, sem preferência, no da
+// requisição
+func (s *Service) InvoicePDF(ctx context.Context, contactID, id int) ([]byte, string, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, "", err
+	}
+	invoice, err := repo.GetInvoice(ctx, contactID, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	lang := contactLanguage(ctx, invoice.Contact)
+	ctx = i18n.WithLanguage(ctx, lang)
+	footer, err := s.render(ctx, templates.KeyInvoiceFooter, templates.Data{Invoice: invoice, Contact: invoice.Contact})
+	if err != nil {
+		return nil, "", err
+	}
+
+	view := models.InvoiceView(invoice, s.localNow(ctx))
+	data, err := renderInvoicePDF(invoice, view, footer.Lines(), s.pixPayload(ctx, invoice, view), lang)
+	if err != nil {
+		return nil, "", errors.WrapError(err, "falha ao gerar PDF da fatura")
+	}
+	return data, invoice.InvoiceNo + ".pdf", nil
+}
+
+// InvoicePix cria (ou reaproveita) a cobrança Pix do saldo em aberto da fatura do cliente
+func (s *Service) InvoicePix(ctx context.Context, contactID, id int) (*models.PixCharge, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	invoice, err := repo.GetInvoice(ctx, contactID, id)
+	if err != nil {
+		return nil, err
+	}
+	charge, err := s.pix(ctx, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PixCharge{InvoiceID: charge.InvoiceID, Amount: charge.Amount, CopyPaste: charge.Payload, ExpiresAt: charge.ExpiresAt}, nil
+}
+
+// InvoicePixQRCode desenha em PNG o QR code da cobrança Pix da fatura do cliente
+func (s *Service) InvoicePixQRCode(ctx context.Context, contactID, id int) ([]byte, error) {
+	charge, err := s.InvoicePix(ctx, contactID, id)
+	if err != nil {
+		return nil, err
+	}
+	return bankingService.PixQRCodePNG(charge.CopyPaste)
+}
+
+// pixPayload retorna o copia e cola impresso no PDF da fatura com saldo em aberto. Sem Pix
+// configurado, ou se o PSP falhar, o PDF sai sem o QR code.
+func (s *Service) pixPayload(ctx context.Context, invoice *sales.Invoice, view models.Invoice) string {
+	if !view.Balance.IsPositive() || invoice.Status == sales.InvoiceStatusCancelled {
+		return ""
+	}
+	charge, err := s.pix(ctx, invoice.ID)
+	if err != nil {
+		if err != errors.ErrChargeNotConfigured && err != errors.ErrInvoiceNotPayable {
+			s.logger.Warn("PDF da fatura gerado sem Pix", zap.Error(err), zap.Int("invoice_id", invoice.ID))
+		}
+		return ""
+	}
+	return charge.Payload
+}
+
+// portalPixCharge cria a cobrança Pix pedida pelo cliente no portal
+func portalPixCharge(ctx context.Context, invoiceID int) (*banking.PixCharge, error) {
+	return bankingService.CreatePixCharge(ctx, banking.PixChargeInput{InvoiceID: invoiceID}, "portal")
+}
+
+// localNow é o instante atual no fuso da empresa, que decide o que já venceu ou expirou
+func (s *Service) localNow(ctx context.Context) time.Time {
+	return s.now().In(s.location(ctx))
+}
+
+// quotationView monta a visão da cotação no idioma da requisição; sem termos próprios, valem os
+// do modelo quotation.terms da empresa no idioma preferido do cliente
+func (s *Service) quotationView(ctx context.Context, quotation *sales.Quotation, now time.Time) models.Quotation {
+	view := models.QuotationView(quotation, now.In(s.location(ctx)))
+	view.Localize(i18n.FromContext(ctx))
+	if view.Terms != "" {
+		return view
+	}
+	ctx = i18n.WithLanguage(ctx, contactLanguage(ctx, quotation.Contact))
+	terms, err := s.render(ctx, templates.KeyQuotationTerms, templates.Data{Quotation: quotation, Contact: quotation.Contact})
+	if err != nil {
+		s.logger.Warn("erro ao gerar termos da cotação", zap.Error(err), zap.Int("quotation_id", quotation.ID))
+		return view
+	}
+	view.Terms = strings.TrimSpace(terms.Body)
+	return view
+}
+
+// ListDeliveries lista as entregas do cliente com os eventos de rastreamento
+func (s *Service) ListDeliveries(ctx context.Context, contactID int) ([]models.Delivery, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := repo.ListDeliveries(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	events, err := repo.GetTrackingEvents(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lang := i18n.FromContext(ctx)
+	views := make([]models.Delivery, 0, len(deliveries))
+	for i := range deliveries {
+		view := models.DeliveryView(&deliveries[i], events[deliveries[i].ID])
+		view.Localize(lang)
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// OpenTicket abre o chamado; o documento referenciado, quando informado, precisa ser do cliente
+func (s *Service) OpenTicket(ctx context.Context, contactID int, input models.CreateTicketInput) (*models.SupportTicket, error) {
+	if (input.DocumentType == "") != (input.DocumentID == nil) {
+		return nil, errors.ErrInvalidTicketDocument
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if input.DocumentID != nil {
+		ok, err := repo.DocumentBelongsTo(ctx, contactID, input.DocumentType, *input.DocumentID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.ErrInvalidTicketDocument
+		}
+	}
+
+	ticket := &models.SupportTicket{
+		ContactID:    contactID,
+		Subject:      strings.TrimSpace(input.Subject),
+		Message:      strings.TrimSpace(input.Message),
+		Status:       models.TicketStatusOpen,
+		DocumentType: input.DocumentType,
+		DocumentID:   input.DocumentID,
+	}
+	if err := repo.CreateTicket(ctx, ticket); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("chamado aberto pelo portal", zap.Int("id", ticket.ID), zap.Int("contact_id", contactID))
+	return ticket, nil
+}
+
+// ListTickets lista os chamados do cliente
+func (s *Service) ListTickets(ctx context.Context, contactID int) ([]models.SupportTicket, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListTickets(ctx, contactID)
+}
+
+// contactLanguage é o idioma dos documentos do cliente: o preferido do contato ou o da requisição
+func contactLanguage(ctx context.Context, contact *contactModels.Contact) string {
+	if contact == nil {
+		return i18n.FromContext(ctx)
+	}
+	return i18n.Preferred(ctx, contact.PreferredLanguage)
+}
+
+// generateCode gera o código no formato ptl_<43 caracteres base64url>
+func generateCode() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("falha ao gerar código de acesso ao portal: %w", err)
+	}
+	return CodePrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
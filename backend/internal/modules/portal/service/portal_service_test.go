@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/mailer"
+	banking "ERP-ONSMART/backend/internal/modules/banking/models"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/portal/models"
+	"ERP-ONSMART/backend/internal/modules/portal/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	users "ERP-ONSMART/backend/internal/modules/users/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "portal-test-secret"
+
+// fakeRepo implementa só o que os testes usam; os demais métodos não são chamados
+type fakeRepo struct {
+	repository.PortalRepository
+	accesses   []*models.PortalAccess
+	lookupCtx  context.Context
+	belongs    bool
+	tickets    []models.SupportTicket
+	touchedIDs []int
+	quotation  *sales.Quotation
+	responses  []models.QuotationResponse
+	sharedCtx  context.Context
+	invoice    *sales.Invoice
+}
+
+func (f *fakeRepo) GetInvoice(_ context.Context, contactID, id int) (*sales.Invoice, error) {
+	if f.invoice == nil || f.invoice.ContactID != contactID || f.invoice.ID != id {
+		return nil, errors.ErrInvoiceNotFound
+	}
+	return f.invoice, nil
+}
+
+func (f *fakeRepo) CreateAccess(_ context.Context, access *models.PortalAccess) error {
+	access.ID = len(f.accesses) + 1
+	access.CompanyID = 5
+	f.accesses = append(f.accesses, access)
+	return nil
+}
+
+func (f *fakeRepo) GetContact(_ context.Context, id int) (*contact.Contact, error) {
+	return &contact.Contact{ID: id}, nil
+}
+
+func (f *fakeRepo) FindAccessByHash(ctx context.Context, codeHash string) (*models.PortalAccess, error) {
+	f.lookupCtx = ctx
+	for _, access := range f.accesses {
+		if access.CodeHash == codeHash {
+			return access, nil
+		}
+	}
+	return nil, errors.ErrInvalidPortalAccess
+}
+
+func (f *fakeRepo) TouchAccess(_ context.Context, id int, _ time.Time) error {
+	f.touchedIDs = append(f.touchedIDs, id)
+	return nil
+}
+
+func (f *fakeRepo) DocumentBelongsTo(context.Context, int, string, int) (bool, error) {
+	return f.belongs, nil
+}
+
+func (f *fakeRepo) CreateTicket(_ context.Context, ticket *models.SupportTicket) error {
+	ticket.ID = len(f.tickets) + 1
+	f.tickets = append(f.tickets, *ticket)
+	return nil
+}
+
+func (f *fakeRepo) ShareQuotation(_ context.Context, id int) (*sales.Quotation, error) {
+	if f.quotation == nil || f.quotation.ID != id {
+		return nil, errors.ErrQuotationNotFound
+	}
+	if f.quotation.Status == sales.QuotationStatusDraft {
+		f.quotation.Status = sales.QuotationStatusSent
+	}
+	return f.quotation, nil
+}
+
+func (f *fakeRepo) GetSharedQuotation(ctx context.Context, id int) (*sales.Quotation, error) {
+	f.sharedCtx = ctx
+	if f.quotation == nil || f.quotation.ID != id {
+		return nil, errors.ErrInvalidShareLink
+	}
+	return f.quotation, nil
+}
+
+func (f *fakeRepo) ReplyQuotation(_ context.Context, contactID, id int, response *models.QuotationResponse) (*sales.Quotation, error) {
+	if f.quotation == nil || f.quotation.ID != id || f.quotation.ContactID != contactID {
+		return nil, errors.ErrQuotationNotFound
+	}
+	if err := models.ReplyError(f.quotation, response.RespondedAt); err != nil {
+		return nil, err
+	}
+	f.quotation.Status = response.Decision
+	response.QuotationID = id
+	f.responses = append(f.responses, *response)
+	return f.quotation, nil
+}
+
+func (f *fakeRepo) GetUser(_ context.Context, id int) (*users.User, error) {
+	return &users.User{ID: id, Nome: "Vendedor", Email: "vendedor@onsmart.com"}, nil
+}
+
+func newTestService(repo *fakeRepo, now time.Time) *Service {
+	s := NewService(func() (repository.PortalRepository, error) { return repo, nil })
+	s.now = func() time.Time { return now }
+	s.location = func(context.Context) *time.Location { return time.UTC }
+	s.secret = func() string { return testSecret }
+	s.send = func(mailer.Message) error { return nil }
+	s.render = func(ctx context.Context, key string, data templates.Data) (*templates.Rendered, error) {
+		data.Language = i18n.FromContext(ctx)
+		return templates.RenderBuiltin(key, data)
+	}
+	s.pix = func(ctx context.Context, invoiceID int) (*banking.PixCharge, error) {
+		return nil, errors.ErrChargeNotConfigured
+	}
+	return s
+}
+
+func TestStartSessionIssuesPortalToken(t *testing.T) {
+	now := time.Now()
+	repo := &fakeRepo{}
+	s := newTestService(repo, now)
+
+	code, err := generateCode()
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateAccess(context.Background(), &models.PortalAccess{ContactID: 42, CodeHash: hashCode(code)}))
+
+	session, err := s.StartSession(context.Background(), " "+code+" ")
+	require.NoError(t, err)
+	assert.Equal(t, 42, session.ContactID)
+	assert.Equal(t, now.Add(models.SessionTTL), session.ExpiresAt)
+	assert.True(t, tenant.IsAllCompanies(repo.lookupCtx), "o código é buscado em todas as empresas")
+	assert.Equal(t, []int{1}, repo.touchedIDs)
+
+	token, err := jwt.Parse(session.Token, func(*jwt.Token) (interface{}, error) { return []byte(testSecret), nil })
+	require.NoError(t, err)
+	claims := token.Claims.(jwt.MapClaims)
+	assert.Equal(t, models.TokenScope, claims["scope"])
+	assert.Equal(t, float64(42), claims["contact_id"])
+	assert.Equal(t, float64(5), claims["company_id"])
+	assert.Nil(t, claims["role"], "o token do portal não carrega perfil interno")
+}
+
+func TestStartSessionRejectsInvalidCodes(t *testing.T) {
+	now := time.Now()
+	repo := &fakeRepo{}
+	s := newTestService(repo, now)
+
+	revoked, expired := now.Add(-time.Minute), now.Add(-time.Hour)
+	repo.accesses = []*models.PortalAccess{
+		{ID: 1, CodeHash: hashCode(CodePrefix + "revogado"), RevokedAt: &revoked},
+		{ID: 2, CodeHash: hashCode(CodePrefix + "expirado"), ExpiresAt: &expired},
+	}
+
+	for _, code := range []string{"", "erp_chave-interna", CodePrefix + "revogado", CodePrefix + "expirado", CodePrefix + "desconhecido"} {
+		_, err := s.StartSession(context.Background(), code)
+		assert.Equal(t, errors.ErrInvalidPortalAccess, err, code)
+	}
+	assert.Empty(t, repo.touchedIDs)
+}
+
+func TestCreateAccessKeepsOnlyHash(t *testing.T) {
+	repo := &fakeRepo{}
+	s := newTestService(repo, time.Now())
+
+	created, err := s.CreateAccess(context.Background(), 42, models.CreateAccessInput{}, "admin")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Code, CodePrefix))
+	assert.Equal(t, created.Code[:prefixLength], created.Access.Prefix)
+	assert.Equal(t, hashCode(created.Code), created.Access.CodeHash)
+	assert.NotContains(t, created.Access.CodeHash, created.Code)
+
+	past := time.Now().Add(-time.Hour)
+	_, err = s.CreateAccess(context.Background(), 42, models.CreateAccessInput{ExpiresAt: &past}, "admin")
+	assert.Error(t, err)
+}
+
+func TestOpenTicketChecksDocument(t *testing.T) {
+	repo := &fakeRepo{}
+	s := newTestService(repo, time.Now())
+	invoiceID := 9
+
+	_, err := s.OpenTicket(context.Background(), 42, models.CreateTicketInput{Subject: "Boleto", Message: "Não recebi", DocumentType: models.TicketDocumentInvoice})
+	assert.Equal(t, errors.ErrInvalidTicketDocument, err, "tipo sem documento")
+
+	_, err = s.OpenTicket(context.Background(), 42, models.CreateTicketInput{Subject: "Boleto", Message: "Não recebi", DocumentType: models.TicketDocumentInvoice, DocumentID: &invoiceID})
+	assert.Equal(t, errors.ErrInvalidTicketDocument, err, "fatura de outro cliente")
+
+	repo.belongs = true
+	ticket, err := s.OpenTicket(context.Background(), 42, models.CreateTicketInput{Subject: " Boleto ", Message: "Não recebi", DocumentType: models.TicketDocumentInvoice, DocumentID: &invoiceID})
+	require.NoError(t, err)
+	assert.Equal(t, "Boleto", ticket.Subject)
+	assert.Equal(t, models.TicketStatusOpen, ticket.Status)
+	assert.Equal(t, 42, ticket.ContactID)
+}
+
+func TestShareAndReplyQuotationByLink(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	salesperson := 4
+	repo := &fakeRepo{quotation: &sales.Quotation{
+		ID: 7, QuotationNo: "COT-7", ContactID: 42, SalespersonID: &salesperson,
+		Status: sales.QuotationStatusDraft, ExpiryDate: now.AddDate(0, 0, 10),
+	}}
+	s := newTestService(repo, now)
+	var sent []mailer.Message
+	s.send = func(msg mailer.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}
+
+	link, err := s.ShareQuotation(tenant.WithCompany(context.Background(), 3), 7, models.ShareQuotationInput{})
+	require.NoError(t, err)
+	assert.Equal(t, sales.QuotationStatusSent, repo.quotation.Status, "rascunho passa a enviada")
+	assert.Equal(t, now.Add(defaultShareTTL), link.ExpiresAt)
+	assert.True(t, strings.HasSuffix(link.URL, "/cotacao?token="+link.Token))
+
+	view, err := s.GetSharedQuotation(context.Background(), link.Token)
+	require.NoError(t, err)
+	assert.True(t, view.CanReply)
+	companyID, _ := tenant.CompanyID(repo.sharedCtx)
+	assert.Equal(t, 3, companyID, "a empresa vem do link")
+
+	_, err = s.ReplySharedQuotation(context.Background(), link.Token+"x", sales.QuotationStatusAccepted, "", models.ReplyOrigin{})
+	assert.Equal(t, errors.ErrInvalidShareLink, err)
+
+	origin := models.ReplyOrigin{IPAddress: "203.0.113.9", UserAgent: "Mozilla/5.0"}
+	_, err = s.ReplySharedQuotation(context.Background(), link.Token, sales.QuotationStatusAccepted, " Pode faturar ", origin)
+	require.NoError(t, err)
+	assert.Equal(t, sales.QuotationStatusAccepted, repo.quotation.Status)
+	require.Len(t, repo.responses, 1)
+	assert.Equal(t, models.QuotationResponse{
+		QuotationID: 7, Decision: sales.QuotationStatusAccepted, Comment: "Pode faturar",
+		Channel: models.ChannelLink, IPAddress: "203.0.113.9", UserAgent: "Mozilla/5.0", RespondedAt: now,
+	}, repo.responses[0])
+	require.Len(t, sent, 1)
+	assert.Equal(t, "vendedor@onsmart.com", sent[0].To)
+	assert.Contains(t, sent[0].Subject, "COT-7 aceita")
+	assert.Contains(t, sent[0].Body, "Pode faturar")
+
+	_, err = s.ReplySharedQuotation(context.Background(), link.Token, sales.QuotationStatusRejected, "", origin)
+	assert.Equal(t, errors.ErrQuotationNotAwaitingReply, err, "a cotação já foi respondida")
+}
+
+func TestShareQuotationRejectsPastExpiry(t *testing.T) {
+	now := time.Now()
+	repo := &fakeRepo{quotation: &sales.Quotation{ID: 7, Status: sales.QuotationStatusSent}}
+	s := newTestService(repo, now)
+
+	past := now.Add(-time.Minute)
+	_, err := s.ShareQuotation(context.Background(), 7, models.ShareQuotationInput{ExpiresAt: &past})
+	assert.Error(t, err)
+}
+
+func TestRenderInvoicePDF(t *testing.T) {
+	invoice := &sales.Invoice{
+		InvoiceNo:  "INV-2026-000007",
+		IssueDate:  time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC),
+		DueDate:    time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		GrandTotal: money.MustParse("1234.5"),
+		Items:      []sales.InvoiceItem{{ProductName: "Parafuso (caixa)", Quantity: 10, Unit: "CX", UnitPrice: money.MustParse("123.45"), Total: money.MustParse("1234.5")}},
+	}
+
+	data, err := renderInvoicePDF(invoice, models.InvoiceView(invoice, invoice.IssueDate), []string{"Pagamento via PIX"}, "", i18n.PtBR)
+	require.NoError(t, err)
+	out := string(data)
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4"))
+	assert.Contains(t, out, "(Fatura INV-2026-000007) Tj")
+	assert.Contains(t, out, `(Parafuso \(caixa\)) Tj`)
+	assert.Contains(t, out, "(R$ 1.234,50) Tj")
+	assert.Contains(t, out, "(Pagamento via PIX) Tj")
+
+	data, err = renderInvoicePDF(invoice, models.InvoiceView(invoice, invoice.IssueDate), nil, "", i18n.EnUS)
+	require.NoError(t, err)
+	out = string(data)
+	assert.Contains(t, out, "(Invoice INV-2026-000007) Tj")
+	assert.Contains(t, out, "(Due date: 06/01/2026) Tj")
+	assert.Contains(t, out, "(R$ 1,234.50) Tj")
+}
+
+func TestInvoicePDFUsesContactLanguage(t *testing.T) {
+	invoice := &sales.Invoice{
+		ID: 3, InvoiceNo: "INV-3", ContactID: 9, PaymentTerms: "30 days",
+		IssueDate: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		Contact: &contact.Contact{ID: 9, Name: "Acme Inc", PreferredLanguage: i18n.EnUS},
+	}
+	s := newTestService(&fakeRepo{invoice: invoice}, invoice.IssueDate)
+
+	data, name, err := s.InvoicePDF(context.Background(), 9, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "INV-3.pdf", name)
+	assert.Contains(t, string(data), "(Invoice INV-3) Tj")
+	assert.Contains(t, string(data), "(Payment terms: 30 days) Tj")
+
+	// Sem preferência do contato, vale o idioma da requisição
+	invoice.Contact.PreferredLanguage = ""
+	data, _, err = s.InvoicePDF(i18n.WithLanguage(context.Background(), i18n.PtBR), 9, 3)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "(Fatura INV-3) Tj")
+}
+
+func TestInvoicePix(t *testing.T) {
+	invoice := &sales.Invoice{
+		ID: 3, InvoiceNo: "INV-3", ContactID: 9, Status: sales.InvoiceStatusSent,
+		IssueDate: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		GrandTotal: money.FromInt(500), Contact: &contact.Contact{ID: 9, Name: "Acme"},
+	}
+	s := newTestService(&fakeRepo{invoice: invoice}, invoice.IssueDate)
+	payload := "00020101021226580014br.gov.bcb.pix2536pix.psp.com.br/qr/v2/cobv/FAT3abc5204000053039865802BR5907Onsmart6009Sao Paulo62070503***6304ABCD"
+	s.pix = func(ctx context.Context, invoiceID int) (*banking.PixCharge, error) {
+		return &banking.PixCharge{InvoiceID: invoiceID, Amount: money.FromInt(500), Payload: payload}, nil
+	}
+
+	charge, err := s.InvoicePix(context.Background(), 9, 3)
+	require.NoError(t, err)
+	assert.Equal(t, payload, charge.CopyPaste)
+	_, err = s.InvoicePix(context.Background(), 10, 3)
+	assert.Equal(t, errors.ErrInvoiceNotFound, err, "fatura de outro cliente")
+
+	png, err := s.InvoicePixQRCode(context.Background(), 9, 3)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(png), "\x89PNG"))
+
+	data, _, err := s.InvoicePDF(i18n.WithLanguage(context.Background(), i18n.PtBR), 9, 3)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "(Pague com Pix) Tj")
+	assert.Contains(t, string(data), "re f", "QR code desenhado")
+	assert.Contains(t, string(data), "("+payload[:90]+") Tj")
+
+	invoice.AmountPaid = money.FromInt(500)
+	data, _, err = s.InvoicePDF(i18n.WithLanguage(context.Background(), i18n.PtBR), 9, 3)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Pague com Pix", "fatura quitada sai sem Pix")
+}
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "R$ 0,00", formatMoney(i18n.PtBR, money.Zero))
+	assert.Equal(t, "R$ 999,90", formatMoney(i18n.PtBR, money.MustParse("999.9")))
+	assert.Equal(t, "R$ 1.234.567,89", formatMoney(i18n.PtBR, money.MustParse("1234567.891")))
+	assert.Equal(t, "R$ -1.000,00", formatMoney(i18n.PtBR, money.FromInt(-1000)))
+	assert.Equal(t, "R$ 1,234,567.89", formatMoney(i18n.EnUS, money.MustParse("1234567.891")))
+}
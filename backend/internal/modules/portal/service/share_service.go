@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/mailer"
+	"ERP-ONSMART/backend/internal/modules/portal/models"
+	"ERP-ONSMART/backend/internal/modules/portal/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// defaultShareTTL vale quando QUOTATION_SHARE_TTL não está configurado
+const defaultShareTTL = 7 * 24 * time.Hour
+
+// ShareQuotation gera o link público assinado da cotação
+func ShareQuotation(ctx context.Context, id int, input models.ShareQuotationInput) (*models.ShareLink, error) {
+	return defaultService.ShareQuotation(ctx, id, input)
+}
+
+// GetSharedQuotation retorna a cotação do link público, somente leitura
+func GetSharedQuotation(ctx context.Context, token string) (*models.Quotation, error) {
+	return defaultService.GetSharedQuotation(ctx, token)
+}
+
+// ReplySharedQuotation registra o aceite (accepted) ou a recusa (rejected) pelo link público
+func ReplySharedQuotation(ctx context.Context, token, status, comment string, origin models.ReplyOrigin) (*models.Quotation, error) {
+	return defaultService.ReplySharedQuotation(ctx, token, status, comment, origin)
+}
+
+// ListResponses lista as respostas do cliente à cotação
+func ListResponses(ctx context.Context, quotationID int) ([]models.QuotationResponse, error) {
+	return defaultService.ListResponses(ctx, quotationID)
+}
+
+// ShareQuotation assina o link da cotação com a empresa atual e a validade informada (ou
+// QUOTATION_SHARE_TTL). Um rascunho passa a enviada ao ser compartilhado.
+func (s *Service) ShareQuotation(ctx context.Context, id int, input models.ShareQuotationInput) (*models.ShareLink, error) {
+	now := s.now()
+	expiresAt := now.Add(shareTTL())
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(now) {
+			return nil, errors.InvalidParam("expires_at deve ser uma data futura")
+		}
+		expiresAt = *input.ExpiresAt
+	}
+	secret := s.secret()
+	if secret == "" {
+		return nil, errors.NewAPIError(http.StatusInternalServerError, errors.CodeInternal, "chave JWT não configurada")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.ShareQuotation(ctx, id); err != nil {
+		return nil, err
+	}
+
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		companyID = tenant.DefaultCompanyID
+	}
+	token := models.SignShareToken(secret, id, companyID, expiresAt)
+
+	s.logger.Info("link de cotação gerado", zap.Int("quotation_id", id), zap.Time("expires_at", expiresAt))
+	return &models.ShareLink{
+		QuotationID: id,
+		Token:       token,
+		URL:         strings.TrimRight(viper.GetString("FRONTEND_URL"), "/") + "/cotacao?token=" + token,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// GetSharedQuotation valida o link e busca a cotação na empresa indicada por ele
+func (s *Service) GetSharedQuotation(ctx context.Context, token string) (*models.Quotation, error) {
+	ctx, id, err := s.sharedContext(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	quotation, err := repo.GetSharedQuotation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	view := s.quotationView(ctx, quotation, s.now())
+	return &view, nil
+}
+
+// ReplySharedQuotation registra a resposta feita pelo link público, com as mesmas regras do portal
+func (s *Service) ReplySharedQuotation(ctx context.Context, token, status, comment string, origin models.ReplyOrigin) (*models.Quotation, error) {
+	ctx, id, err := s.sharedContext(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.newResponse(status, comment, models.ChannelLink, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	shared, err := repo.GetSharedQuotation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	quotation, err := repo.ReplyQuotation(ctx, shared.ContactID, id, response)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifySalesperson(ctx, repo, quotation, response)
+	view := s.quotationView(ctx, quotation, response.RespondedAt)
+	return &view, nil
+}
+
+// ListResponses lista as respostas registradas para a cotação
+func (s *Service) ListResponses(ctx context.Context, quotationID int) ([]models.QuotationResponse, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListResponses(ctx, quotationID)
+}
+
+// sharedContext valida o link e devolve o contexto na empresa da cotação
+func (s *Service) sharedContext(ctx context.Context, token string) (context.Context, int, error) {
+	secret := s.secret()
+	if secret == "" {
+		return nil, 0, errors.NewAPIError(http.StatusInternalServerError, errors.CodeInternal, "chave JWT não configurada")
+	}
+	id, companyID, err := models.ParseShareToken(secret, strings.TrimSpace(token), s.now())
+	if err != nil {
+		return nil, 0, err
+	}
+	return tenant.WithCompany(ctx, companyID), id, nil
+}
+
+func (s *Service) newResponse(status, comment, channel string, origin models.ReplyOrigin) (*models.QuotationResponse, error) {
+	if status != sales.QuotationStatusAccepted && status != sales.QuotationStatusRejected {
+		return nil, errors.InvalidParam("resposta da cotação deve ser accepted ou rejected")
+	}
+	return &models.QuotationResponse{
+		Decision:    status,
+		Comment:     strings.TrimSpace(comment),
+		Channel:     channel,
+		IPAddress:   origin.IPAddress,
+		UserAgent:   truncate(origin.UserAgent, 255),
+		RespondedAt: s.now(),
+	}, nil
+}
+
+// notifySalesperson avisa o vendedor da cotação sobre a resposta. A resposta já está gravada,
+// então falhas no aviso só são registradas no log.
+func (s *Service) notifySalesperson(ctx context.Context, repo repository.PortalRepository, quotation *sales.Quotation, response *models.QuotationResponse) {
+	if quotation.SalespersonID == nil {
+		return
+	}
+	user, err := repo.GetUser(ctx, *quotation.SalespersonID)
+	if err != nil {
+		s.logger.Warn("vendedor da cotação não encontrado", zap.Error(err), zap.Int("quotation_id", quotation.ID))
+		return
+	}
+	if user.Email == "" {
+		return
+	}
+
+	// O aviso é para a equipe: sai no idioma padrão, não no do cliente que respondeu
+	ctx = i18n.WithLanguage(ctx, i18n.Default)
+	decision := strings.ToLower(i18n.Label(i18n.Default, i18n.QuotationStatus, response.Decision))
+	message, err := s.render(ctx, templates.KeyQuotationResponseEmail, templates.Data{Quotation: quotation, Vars: map[string]interface{}{
+		"salesperson":  user.Nome,
+		"decision":     decision,
+		"responded_at": response.RespondedAt,
+		"channel":      response.Channel,
+		"ip_address":   response.IPAddress,
+		"comment":      response.Comment,
+	}})
+	if err != nil {
+		s.logger.Warn("erro ao gerar e-mail da resposta da cotação", zap.Error(err), zap.Int("quotation_id", quotation.ID))
+		return
+	}
+	err = s.send(mailer.Message{To: user.Email, Subject: message.Subject, Body: message.Body})
+	if err != nil {
+		s.logger.Warn("erro ao avisar vendedor da resposta da cotação", zap.Error(err), zap.Int("quotation_id", quotation.ID))
+	}
+}
+
+func shareTTL() time.Duration {
+	if ttl := viper.GetDuration("QUOTATION_SHARE_TTL"); ttl > 0 {
+		return ttl
+	}
+	return defaultShareTTL
+}
@@ -1,25 +1,33 @@
 package dtos
 
-import "time"
+import (
+	"time"
+
+	"ERP-ONSMART/backend/internal/money"
+)
 
 // ProductCreateDTO representa os dados para criar um product
 type ProductCreateDTO struct {
 	Name         string `json:"name" validate:"required"`
 	DetailedName string `json:"detailed_name" validate:"required"`
 	Description  string `json:"description,omitempty"`
-	Status       string `json:"status" validate:"required,oneof=ativo desativado descontinuado"`
+	Status       string `json:"status" validate:"required,oneof=ativo desativado descontinuado fim_de_vida"`
 	SKU          string `json:"sku,omitempty"`
 	Barcode      string `json:"barcode,omitempty"`
 	ExternalID   string `json:"external_id,omitempty"`
 
 	// Price related
-	Coin       string  `json:"coin" validate:"required,oneof=BRL USD EUR CAD ADOBE_USD"`
-	Price      float64 `json:"price" validate:"required,gte=0"`
-	SalesPrice float64 `json:"sales_price" validate:"gte=0"`
-	CostPrice  float64 `json:"cost_price" validate:"gte=0"`
+	Coin       string        `json:"coin" validate:"required,oneof=BRL USD EUR CAD ADOBE_USD"`
+	Price      money.Decimal `json:"price" validate:"required,gte=0"`
+	SalesPrice money.Decimal `json:"sales_price" validate:"gte=0"`
+	CostPrice  money.Decimal `json:"cost_price" validate:"gte=0"`
 
 	// Inventory
-	Stock int `json:"stock" validate:"gte=0"`
+	Stock               int  `json:"stock" validate:"gte=0"`
+	ReorderPoint        int  `json:"reorder_point" validate:"gte=0"`
+	ReorderQuantity     int  `json:"reorder_quantity" validate:"gte=0"`
+	PreferredSupplierID *int `json:"preferred_supplier_id,omitempty"`
+	BinID               *int `json:"bin_id,omitempty"`
 
 	// Classification
 	Type               string   `json:"type,omitempty"`
@@ -46,19 +54,23 @@ type ProductUpdateDTO struct {
 	Name         *string `json:"name,omitempty"`
 	DetailedName *string `json:"detailed_name,omitempty"`
 	Description  *string `json:"description,omitempty"`
-	Status       *string `json:"status,omitempty" validate:"omitempty,oneof=ativo desativado descontinuado"`
+	Status       *string `json:"status,omitempty" validate:"omitempty,oneof=ativo desativado descontinuado fim_de_vida"`
 	SKU          *string `json:"sku,omitempty"`
 	Barcode      *string `json:"barcode,omitempty"`
 	ExternalID   *string `json:"external_id,omitempty"`
 
 	// Price related
-	Coin       *string  `json:"coin,omitempty" validate:"omitempty,oneof=BRL USD EUR CAD ADOBE_USD"`
-	Price      *float64 `json:"price,omitempty" validate:"omitempty,gte=0"`
-	SalesPrice *float64 `json:"sales_price,omitempty" validate:"omitempty,gte=0"`
-	CostPrice  *float64 `json:"cost_price,omitempty" validate:"omitempty,gte=0"`
+	Coin       *string        `json:"coin,omitempty" validate:"omitempty,oneof=BRL USD EUR CAD ADOBE_USD"`
+	Price      *money.Decimal `json:"price,omitempty" validate:"omitempty,gte=0"`
+	SalesPrice *money.Decimal `json:"sales_price,omitempty" validate:"omitempty,gte=0"`
+	CostPrice  *money.Decimal `json:"cost_price,omitempty" validate:"omitempty,gte=0"`
 
 	// Inventory
-	Stock *int `json:"stock,omitempty" validate:"omitempty,gte=0"`
+	Stock               *int `json:"stock,omitempty" validate:"omitempty,gte=0"`
+	ReorderPoint        *int `json:"reorder_point,omitempty" validate:"omitempty,gte=0"`
+	ReorderQuantity     *int `json:"reorder_quantity,omitempty" validate:"omitempty,gte=0"`
+	PreferredSupplierID *int `json:"preferred_supplier_id,omitempty"`
+	BinID               *int `json:"bin_id,omitempty"`
 
 	// Classification
 	Type               *string   `json:"type,omitempty"`
@@ -92,13 +104,17 @@ type ProductResponseDTO struct {
 	ExternalID   string `json:"external_id,omitempty"`
 
 	// Price related
-	Coin       string  `json:"coin"`
-	Price      float64 `json:"price"`
-	SalesPrice float64 `json:"sales_price"`
-	CostPrice  float64 `json:"cost_price"`
+	Coin       string        `json:"coin"`
+	Price      money.Decimal `json:"price"`
+	SalesPrice money.Decimal `json:"sales_price"`
+	CostPrice  money.Decimal `json:"cost_price"`
 
 	// Inventory
-	Stock int `json:"stock"`
+	Stock               int  `json:"stock"`
+	ReorderPoint        int  `json:"reorder_point"`
+	ReorderQuantity     int  `json:"reorder_quantity"`
+	PreferredSupplierID *int `json:"preferred_supplier_id,omitempty"`
+	BinID               *int `json:"bin_id,omitempty"`
 
 	// Classification
 	Type               string   `json:"type,omitempty"`
@@ -125,24 +141,24 @@ type ProductResponseDTO struct {
 
 // ProductListItemDTO representa uma versão resumida para listagens
 type ProductListItemDTO struct {
-	ID           int     `json:"id"`
-	Name         string  `json:"name"`
-	SKU          string  `json:"sku,omitempty"`
-	Barcode      string  `json:"barcode,omitempty"`
-	Status       string  `json:"status"`
-	Price        float64 `json:"price"`
-	SalesPrice   float64 `json:"sales_price"`
-	Stock        int     `json:"stock"`
-	Category     string  `json:"category,omitempty"`
-	Manufacturer string  `json:"manufacturer,omitempty"`
-	ImageURL     string  `json:"image_url,omitempty"`
+	ID           int           `json:"id"`
+	Name         string        `json:"name"`
+	SKU          string        `json:"sku,omitempty"`
+	Barcode      string        `json:"barcode,omitempty"`
+	Status       string        `json:"status"`
+	Price        money.Decimal `json:"price"`
+	SalesPrice   money.Decimal `json:"sales_price"`
+	Stock        int           `json:"stock"`
+	Category     string        `json:"category,omitempty"`
+	Manufacturer string        `json:"manufacturer,omitempty"`
+	ImageURL     string        `json:"image_url,omitempty"`
 }
 
 // WarrantyCreateDTO representa os dados para criar uma warranty
 type WarrantyCreateDTO struct {
-	ProductID      int     `json:"product_id" validate:"required"`
-	DurationMonths int     `json:"duration_months" validate:"required,gt=0"`
-	Price          float64 `json:"price" validate:"required,gt=0"`
+	ProductID      int           `json:"product_id" validate:"required"`
+	DurationMonths int           `json:"duration_months" validate:"required,gt=0"`
+	Price          money.Decimal `json:"price" validate:"required,gt=0"`
 }
 
 // WarrantyResponseDTO representa os dados retornados de uma warranty
@@ -151,5 +167,5 @@ type WarrantyResponseDTO struct {
 	ProductID      int                 `json:"product_id"`
 	Product        *ProductListItemDTO `json:"product,omitempty"`
 	DurationMonths int                 `json:"duration_months"`
-	Price          float64             `json:"price"`
+	Price          money.Decimal       `json:"price"`
 }
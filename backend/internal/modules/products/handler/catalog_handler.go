@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	attachmentsService "ERP-ONSMART/backend/internal/modules/attachments/service"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Define a categoria do produto e a publicação no catálogo público (published e featured)
+// Todos os campos são gravados: category_id nulo tira o produto da categoria.
+// @Param id path int true "ID do produto"
+func SetProductCatalogHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var settings models.CatalogSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	product, err := service.SetProductCatalog(c.Request.Context(), id, settings)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao publicar produto")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"product": product})
+}
+
+// Lista as imagens do produto na ordem de exibição; a primeira é a capa
+// @Param id path int true "ID do produto"
+func ListProductImagesHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	images, err := service.ListProductImages(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar imagens do produto")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+// Envia uma imagem do produto como multipart (campo "file"; opcionais "alt_text" e "uploaded_by")
+// O arquivo (JPEG, PNG, WebP ou GIF) fica nos anexos do produto e a imagem entra no fim da lista.
+// @Accept multipart/form-data
+// @Param id path int true "ID do produto"
+func UploadProductImageHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Error(errors.InvalidRequest(err).WithFieldError("file", "campo obrigatório"))
+		return
+	}
+	if max := attachmentsService.MaxUploadSize(); max > 0 && fileHeader.Size > max {
+		c.Error(errors.ErrAttachmentTooLarge)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+	defer file.Close()
+
+	image, err := service.AddProductImage(c.Request.Context(), id, service.ImageUpload{
+		FileName:    fileHeader.Filename,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Size:        fileHeader.Size,
+		Content:     file,
+		AltText:     c.PostForm("alt_text"),
+		UploadedBy:  c.PostForm("uploaded_by"),
+	})
+	if err != nil {
+		c.Error(err).SetMeta("erro ao enviar imagem do produto")
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"image": image})
+}
+
+// Reordena as imagens do produto; image_ids traz todas as imagens e a primeira vira a capa
+// @Param id path int true "ID do produto"
+func ReorderProductImagesHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var order models.ImageOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	images, err := service.ReorderProductImages(c.Request.Context(), id, order)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao reordenar imagens do produto")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+// Remove a imagem do produto e o anexo com o arquivo
+// @Param id path int true "ID do produto"
+// @Param image_id path int true "ID da imagem"
+// @Success 200 "Imagem removida"
+func DeleteProductImageHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	imageID, err := strconv.Atoi(c.Param("image_id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	if err := service.DeleteProductImage(c.Request.Context(), id, imageID); err != nil {
+		c.Error(err).SetMeta("erro ao remover imagem do produto")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Imagem removida com sucesso"})
+}
+
+// Lista os produtos publicados do catálogo público (sem autenticação), com os destaques primeiro
+// Só aparecem produtos publicados e ativos, sem custos nem dados fiscais; usado pela loja e pelo portal do cliente.
+// @Param category_id query int false "ID da categoria (inclui as subcategorias)"
+// @Param search query string false "busca por nome, SKU ou tag"
+// @Param featured query bool false "apenas produtos em destaque"
+// @Param page query int false "página"
+// @Param page_size query int false "itens por página"
+func ListCatalogProductsHandler(c *gin.Context) {
+	filter := models.CatalogFilter{Search: c.Query("search")}
+	if value := c.Query("category_id"); value != "" {
+		categoryID, err := strconv.Atoi(value)
+		if err != nil || categoryID <= 0 {
+			c.Error(errors.InvalidParam("category_id inválido"))
+			return
+		}
+		filter.CategoryID = categoryID
+	}
+	if value := c.Query("featured"); value != "" {
+		featured, err := strconv.ParseBool(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("featured deve ser true ou false"))
+			return
+		}
+		filter.Featured = featured
+	}
+
+	params := pagination.NewPaginationParams(c.Request)
+	result, err := service.ListCatalogProducts(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar catálogo")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Busca um produto publicado do catálogo público (sem autenticação)
+// @Param id path int true "ID do produto"
+func GetCatalogProductHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	product, err := service.GetCatalogProduct(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar produto do catálogo")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"product": product})
+}
+
+// Retorna o arquivo de uma imagem de produto publicado (sem autenticação)
+// @Produce image/*
+// @Param id path int true "ID da imagem"
+func CatalogImageHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	attachment, content, err := service.OpenCatalogImage(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar imagem do catálogo")
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Cache-Control", "public, max-age=3600")
+	if attachment.Size > 0 {
+		c.Header("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	}
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, content)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista a árvore de categorias do catálogo de produtos
+func ListCategoriesHandler(c *gin.Context) {
+	categories, err := service.ListCategoryTree(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar categorias")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+// Cria uma categoria de produtos, na raiz ou abaixo de parent_id
+// Sem slug, ele é gerado a partir do nome (ex.: "Cabos e Adaptadores" vira cabos-e-adaptadores).
+func CreateCategoryHandler(c *gin.Context) {
+	var input models.CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	category, err := service.CreateCategory(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar categoria")
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"category": category})
+}
+
+// Atualiza a categoria ou a move na árvore (parent_id nulo a leva para a raiz)
+// @Param id path int true "ID da categoria"
+func UpdateCategoryHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var input models.CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	category, err := service.UpdateCategory(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar categoria")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+// Remove uma categoria sem subcategorias; os produtos dela ficam sem categoria
+// @Param id path int true "ID da categoria"
+// @Success 200 "Categoria removida"
+func DeleteCategoryHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	if err := service.DeleteCategory(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover categoria")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Categoria removida com sucesso"})
+}
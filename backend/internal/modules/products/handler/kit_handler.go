@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const kitDateLayout = "2006-01-02"
+
+// Retorna a composição do kit, com o estoque dos componentes e a quantidade de kits montável
+func GetKitHandler(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	kit, err := service.GetKit(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar kit")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"kit": kit})
+}
+
+// Define a composição do kit (componentes e quantidades) e o modo nos documentos de venda
+// Com mode=explode as cotações, pedidos e faturas recebem as linhas dos componentes com o preço rateado;
+// com mode=keep a linha do kit é mantida e o estoque e o custo são baixados nos componentes.
+func SetKitHandler(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var input models.KitInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	kit, err := service.SetKit(c.Request.Context(), productID, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao definir kit")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"kit": kit})
+}
+
+// Desfaz o kit; o produto volta a ser vendido como item simples
+// @Success 200 "Kit removido"
+func DeleteKitHandler(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	if err := service.DeleteKit(c.Request.Context(), productID); err != nil {
+		c.Error(err).SetMeta("erro ao excluir kit")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Kit removido com sucesso"})
+}
+
+// Lista a receita faturada, o custo e a margem de cada kit (?from=AAAA-MM-DD&to=AAAA-MM-DD, padrão: mês corrente)
+func ListKitMarginsHandler(c *gin.Context) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := now
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse(kitDateLayout, value)
+		if err != nil {
+			c.Error(errors.InvalidParam("data inicial inválida").WithDetails(err.Error()))
+			return
+		}
+		from = date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse(kitDateLayout, value)
+		if err != nil {
+			c.Error(errors.InvalidParam("data final inválida").WithDetails(err.Error()))
+			return
+		}
+		to = date
+	}
+	if to.Before(from) {
+		c.Error(errors.ErrInvalidDateRange)
+		return
+	}
+
+	// A data final é inclusiva
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+	margins, err := service.ListKitMargins(c.Request.Context(), from, end)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao apurar margem dos kits")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from.Format(kitDateLayout),
+		"to":      to.Format(kitDateLayout),
+		"margins": margins,
+	})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Muda o estado do produto (ativo, desativado, descontinuado ou fim_de_vida) e o substituto
+// Produtos descontinuados ou em fim de vida não entram em novas cotações; fim de vida não volta atrás.
+// Todos os campos são gravados: replacement_product_id nulo remove o substituto.
+// @Param id path int true "ID do produto"
+func SetProductLifecycleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var input models.LifecycleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	product, err := service.SetProductLifecycle(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao alterar ciclo de vida do produto")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"product": product})
+}
+
+// Sugere o substituto do produto: o substituto cadastrado ou o produto ativo da mesma categoria
+// com o preço mais próximo (replacement nulo quando não há sugestão)
+// @Param id path int true "ID do produto"
+func GetProductReplacementHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	suggestion, err := service.GetProductReplacement(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao sugerir substituto do produto")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"replacement": suggestion})
+}
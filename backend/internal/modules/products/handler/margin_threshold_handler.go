@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista a margem mínima de cada categoria, própria ou herdada da categoria mãe mais próxima
+func ListMinMarginsHandler(c *gin.Context) {
+	margins, err := service.ListMinMargins(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar margens mínimas")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"min_margins": margins})
+}
+
+// Define a margem mínima da categoria (em % da receita), que vale também para as subcategorias
+// sem margem própria. Faturas com margem abaixo do mínimo só são contabilizadas com liberação.
+// @Param id path int true "ID da categoria"
+// @Security BearerAuth
+func SetMinMarginHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var input models.MarginThresholdInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	threshold, err := service.SetMinMargin(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao definir margem mínima")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"threshold": threshold})
+}
+
+// Remove a margem mínima própria da categoria, que passa a seguir a da categoria mãe
+// @Param id path int true "ID da categoria"
+// @Security BearerAuth
+func DeleteMinMarginHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	if err := service.DeleteMinMargin(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover margem mínima")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
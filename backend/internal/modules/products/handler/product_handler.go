@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/errors"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	customFieldsService "ERP-ONSMART/backend/internal/modules/customfields/service"
 	"ERP-ONSMART/backend/internal/modules/products/models"
 	"ERP-ONSMART/backend/internal/modules/products/service"
 	"log"
@@ -10,76 +13,100 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Cadastra um produto
 func CreateProductHandler(c *gin.Context) {
 	var p models.Product
-	if err := c.ShouldBindJSON(&p); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos", "details": err.Error()})
+	var err error
+	if err = c.ShouldBindJSON(&p); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+	if p.CustomFields, err = customFieldsService.ValidateValues(c.Request.Context(), customfields.EntityProduct, p.CustomFields); err != nil {
+		c.Error(err)
 		return
 	}
 	if err := service.CreateProduct(&p); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar produto", "details": err.Error()})
+		c.Error(err).SetMeta("erro ao criar produto")
 		return
 	}
 	c.JSON(http.StatusCreated, p)
 }
 
+// Lista os produtos
+// @Param cf.<chave> query string false "filtra pelo valor de um campo personalizado, ex.: cf.voltagem=220"
 func ListProductsHandler(c *gin.Context) {
-	products, err := service.ListProducts()
+	filters, err := customFieldsService.ParseFilters(c.Request.Context(), customfields.EntityProduct, c.Request.URL.Query())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	products, err := service.SearchProducts(filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao listar produtos"})
+		c.Error(err).SetMeta("erro ao listar produtos")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"products": products})
 }
 
+// Busca um produto pelo ID
 func GetProductByIDHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
 	product, err := service.ListProductByID(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado", "details": err.Error()})
+		c.Error(errors.ErrProductNotFound)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"product": product})
 }
 
+// Atualiza um produto pelo ID
 func UpdateProductHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 	var p models.Product
 	if err := c.ShouldBindJSON(&p); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos", "details": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
+	// Sem custom_fields no corpo, os campos personalizados gravados são mantidos
+	if p.CustomFields != nil {
+		if p.CustomFields, err = customFieldsService.ValidateValues(c.Request.Context(), customfields.EntityProduct, p.CustomFields); err != nil {
+			c.Error(err)
+			return
+		}
+	}
 	if err := service.UpdateProduct(id, p); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao atualizar produto", "details": err.Error()})
+		c.Error(err).SetMeta("erro ao atualizar produto")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Produto atualizado com sucesso"})
 }
 
+// Move o produto para a lixeira (soft delete)
 func DeleteProductHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 	log.Printf("[prod/handler]: Tentando deletar produto com ID: %d", id)
 
 	if err := service.DeleteProduct(id); err != nil {
 		log.Printf("[prod/handler]: Erro ao deletar produto com ID %d: %v", id, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado"})
+		c.Error(errors.ErrProductNotFound)
 		return
 	}
 
 	log.Printf("Produto com ID %d deletado com sucesso", id)
-	c.JSON(http.StatusOK, gin.H{"message": "Produto deletado com sucesso"})
+	c.JSON(http.StatusOK, gin.H{"message": "Produto movido para a lixeira"})
 }
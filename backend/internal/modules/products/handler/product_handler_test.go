@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
 	"encoding/json"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 
 	"ERP-ONSMART/backend/internal/logger"
 	models "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
@@ -37,6 +39,7 @@ func TestMain(m *testing.M) {
 func TestCreateProductHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 	r.POST("/products", CreateProductHandler)
 
 	product := models.ProductToAdd
@@ -58,6 +61,7 @@ func TestCreateProductHandler(t *testing.T) {
 func TestListProductsHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 	r.GET("/products", ListProductsHandler)
 
 	req, _ := http.NewRequest("GET", "/products", nil)
@@ -72,6 +76,7 @@ func TestListProductsHandler(t *testing.T) {
 func TestGetProductByIDHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 	r.POST("/products", CreateProductHandler)
 	r.GET("/products/:id", GetProductByIDHandler)
 
@@ -122,6 +127,7 @@ func TestGetProductByIDHandler(t *testing.T) {
 func TestUpdateProductHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 	r.POST("/products", CreateProductHandler)
 	r.PUT("/products/:id", UpdateProductHandler)
 	r.GET("/products", ListProductsHandler)
@@ -158,7 +164,7 @@ func TestUpdateProductHandler(t *testing.T) {
 	updatedProduct := product
 	updatedProduct.Name = "Produto Atualizado"
 	updatedProduct.Description = "Descrição Atualizada"
-	updatedProduct.Price = 199.99
+	updatedProduct.Price = money.MustParse("199.99")
 	updatedProduct.Stock = 15
 
 	updateBody, err := json.Marshal(updatedProduct)
@@ -179,6 +185,7 @@ func TestUpdateProductHandler(t *testing.T) {
 func TestDeleteProductHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
 	r.POST("/products", CreateProductHandler)
 	r.DELETE("/products/:id", DeleteProductHandler)
 	r.GET("/products", ListProductsHandler)
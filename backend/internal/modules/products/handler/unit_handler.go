@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Retorna as unidades de medida do produto, com o fator de conversão para a unidade de estoque
+func GetUnitsHandler(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	units, err := service.GetUnits(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar unidades do produto")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"base_unit": units.Base().Unit, "units": units})
+}
+
+// Define as unidades de medida do produto: a unidade de estoque (fator 1) e as embalagens,
+// com a unidade padrão de compra (is_purchase) e a de venda (is_sales)
+func SetUnitsHandler(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var input models.UnitsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	units, err := service.SetUnits(c.Request.Context(), productID, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao definir unidades do produto")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"base_unit": units.Base().Unit, "units": units})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista a grade de variantes (tamanho x cor) do produto
+func ListVariantsHandler(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	variants, err := service.ListVariants(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar variantes")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"variants": variants})
+}
+
+// Gera a grade de variantes do produto a partir dos tamanhos e cores
+// Cria apenas as combinações que ainda não existem; o SKU de cada variante é o SKU do produto seguido do tamanho e da cor.
+func GenerateVariantMatrixHandler(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var matrix models.VariantMatrix
+	if err := c.ShouldBindJSON(&matrix); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	variants, err := service.GenerateVariantMatrix(c.Request.Context(), productID, matrix)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar grade de variantes")
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"variants": variants})
+}
+
+// Atualiza o SKU, o código de barras, o estoque ou a situação de uma variante
+func UpdateVariantHandler(c *gin.Context) {
+	productID, variantID, ok := variantParams(c)
+	if !ok {
+		return
+	}
+	var update models.VariantUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	variant, err := service.UpdateVariant(c.Request.Context(), productID, variantID, update)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar variante")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"variant": variant})
+}
+
+// Remove uma variante do produto
+// @Success 200 "Variante removida"
+func DeleteVariantHandler(c *gin.Context) {
+	productID, variantID, ok := variantParams(c)
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteVariant(c.Request.Context(), productID, variantID); err != nil {
+		c.Error(err).SetMeta("erro ao excluir variante")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Variante removida com sucesso"})
+}
+
+func variantParams(c *gin.Context) (int, int, bool) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, 0, false
+	}
+	variantID, err := strconv.Atoi(c.Param("variant_id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, 0, false
+	}
+	return productID, variantID, true
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/products/models"
 	"ERP-ONSMART/backend/internal/modules/products/service"
 	"net/http"
@@ -9,54 +10,58 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Registra uma garantia
 func CreateWarrantyHandler(c *gin.Context) {
 	var w models.Warranty
 	if err := c.ShouldBindJSON(&w); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos", "details": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
-	if err := service.CreateWarranty(w); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar garantia", "details": err.Error()})
+	if err := service.CreateWarranty(c.Request.Context(), w); err != nil {
+		c.Error(err).SetMeta("erro ao criar garantia")
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"message": "Garantia criada com sucesso"})
 }
 
+// Lista as garantias
 func ListWarrantiesHandler(c *gin.Context) {
-	warranties, err := service.ListWarranties()
+	warranties, err := service.ListWarranties(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao listar garantias", "details": err.Error()})
+		c.Error(err).SetMeta("erro ao listar garantias")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"warranties": warranties})
 }
 
+// Atualiza uma garantia
 func UpdateWarrantyHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 	var w models.Warranty
 	if err := c.ShouldBindJSON(&w); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos", "details": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
-	if err := service.UpdateWarranty(id, w); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao atualizar garantia", "details": err.Error()})
+	if err := service.UpdateWarranty(c.Request.Context(), id, w); err != nil {
+		c.Error(err).SetMeta("erro ao atualizar garantia")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Garantia atualizada com sucesso"})
 }
 
+// Remove uma garantia
 func DeleteWarrantyHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
-	if err := service.DeleteWarranty(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao deletar garantia", "details": err.Error()})
+	if err := service.DeleteWarranty(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao deletar garantia")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Garantia deletada com sucesso"})
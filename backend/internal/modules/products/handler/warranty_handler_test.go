@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
 	"encoding/json"
 	"net/http"
@@ -17,6 +18,8 @@ import (
 func getValidProductID(t *testing.T) int {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.POST("/products", CreateProductHandler)
 	router.GET("/products", ListProductsHandler)
 
@@ -28,11 +31,13 @@ func getValidProductID(t *testing.T) int {
 		"stock": 20
 	}`)
 	reqCreate, _ := http.NewRequest("POST", "/products", bytes.NewBuffer(productBody))
+	reqCreate.Header.Set(middleware.CompanyHeader, "1")
 	reqCreate.Header.Set("Content-Type", "application/json")
 	respCreate := httptest.NewRecorder()
 	router.ServeHTTP(respCreate, reqCreate)
 	// Não esperamos o ID na resposta, então vamos listar os produtos para encontrar o último inserido.
 	reqList, _ := http.NewRequest("GET", "/products", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	respList := httptest.NewRecorder()
 	router.ServeHTTP(respList, reqList)
 
@@ -53,6 +58,8 @@ func getValidProductID(t *testing.T) int {
 func TestCreateWarrantyHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 
 	// Registra endpoints necessários
 	router.POST("/products", CreateProductHandler)
@@ -74,6 +81,7 @@ func TestCreateWarrantyHandler(t *testing.T) {
 	}
 
 	req, _ := http.NewRequest("POST", "/warranties", bytes.NewBuffer(payloadBytes))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
@@ -86,9 +94,12 @@ func TestCreateWarrantyHandler(t *testing.T) {
 func TestListWarrantiesHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.GET("/warranties", ListWarrantiesHandler)
 
 	req, _ := http.NewRequest("GET", "/warranties", nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
@@ -112,6 +123,8 @@ func TestListWarrantiesHandler(t *testing.T) {
 func TestUpdateWarrantyHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	// Registra os endpoints necessários
 	router.POST("/products", CreateProductHandler)
 	router.GET("/products", ListProductsHandler)
@@ -130,6 +143,7 @@ func TestUpdateWarrantyHandler(t *testing.T) {
 	}
 	payloadBytes, _ := json.Marshal(warrantyPayload)
 	reqCreate, _ := http.NewRequest("POST", "/warranties", bytes.NewBuffer(payloadBytes))
+	reqCreate.Header.Set(middleware.CompanyHeader, "1")
 	reqCreate.Header.Set("Content-Type", "application/json")
 	respCreate := httptest.NewRecorder()
 	router.ServeHTTP(respCreate, reqCreate)
@@ -139,6 +153,7 @@ func TestUpdateWarrantyHandler(t *testing.T) {
 
 	// Lista warranties e obtém o ID da última inserida
 	reqList, _ := http.NewRequest("GET", "/warranties", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	respList := httptest.NewRecorder()
 	router.ServeHTTP(respList, reqList)
 
@@ -163,6 +178,7 @@ func TestUpdateWarrantyHandler(t *testing.T) {
 	}
 	updatedBytes, _ := json.Marshal(updatedPayload)
 	reqUpdate, _ := http.NewRequest("PUT", "/warranties/"+strconv.Itoa(warrantyID), bytes.NewBuffer(updatedBytes))
+	reqUpdate.Header.Set(middleware.CompanyHeader, "1")
 	reqUpdate.Header.Set("Content-Type", "application/json")
 	respUpdate := httptest.NewRecorder()
 	router.ServeHTTP(respUpdate, reqUpdate)
@@ -175,6 +191,8 @@ func TestUpdateWarrantyHandler(t *testing.T) {
 func TestDeleteWarrantyHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	// Registra endpoints necessários
 	router.POST("/products", CreateProductHandler)
 	router.GET("/products", ListProductsHandler)
@@ -193,6 +211,7 @@ func TestDeleteWarrantyHandler(t *testing.T) {
 	}
 	payloadBytes, _ := json.Marshal(warrantyPayload)
 	reqCreate, _ := http.NewRequest("POST", "/warranties", bytes.NewBuffer(payloadBytes))
+	reqCreate.Header.Set(middleware.CompanyHeader, "1")
 	reqCreate.Header.Set("Content-Type", "application/json")
 	respCreate := httptest.NewRecorder()
 	router.ServeHTTP(respCreate, reqCreate)
@@ -202,6 +221,7 @@ func TestDeleteWarrantyHandler(t *testing.T) {
 
 	// Lista warranties para obter o ID da última inserida
 	reqList, _ := http.NewRequest("GET", "/warranties", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	respList := httptest.NewRecorder()
 	router.ServeHTTP(respList, reqList)
 	var listResult struct {
@@ -219,6 +239,7 @@ func TestDeleteWarrantyHandler(t *testing.T) {
 
 	// Deleta a warranty
 	reqDelete, _ := http.NewRequest("DELETE", "/warranties/"+strconv.Itoa(warrantyID), nil)
+	reqDelete.Header.Set(middleware.CompanyHeader, "1")
 	respDelete := httptest.NewRecorder()
 	router.ServeHTTP(respDelete, reqDelete)
 	if respDelete.Code != http.StatusOK {
@@ -0,0 +1,341 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	attachments "ERP-ONSMART/backend/internal/modules/attachments/models"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProductCategory é uma categoria da árvore de categorias do catálogo. Children só é preenchido
+// na árvore montada por BuildCategoryTree.
+type ProductCategory struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	CompanyID   int       `json:"company_id" gorm:"<-:create"`
+	ParentID    *int      `json:"parent_id,omitempty"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description,omitempty"`
+	Position    int       `json:"position"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Children []ProductCategory `json:"children,omitempty" gorm:"-"`
+}
+
+// TableName define o nome da tabela de categorias
+func (ProductCategory) TableName() string {
+	return "product_categories"
+}
+
+// CategoryInput são os dados de uma categoria; sem slug, ele é gerado a partir do nome. Position
+// ordena a categoria entre as irmãs.
+type CategoryInput struct {
+	ParentID    *int   `json:"parent_id"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Slug        string `json:"slug" binding:"max=120"`
+	Description string `json:"description"`
+	Position    int    `json:"position"`
+}
+
+// Category monta a categoria a partir da entrada, com o slug normalizado
+func (in CategoryInput) Category() (ProductCategory, error) {
+	slug := in.Slug
+	if strings.TrimSpace(slug) == "" {
+		slug = in.Name
+	}
+	slug = Slugify(slug)
+	if slug == "" {
+		return ProductCategory{}, errors.ErrInvalidCategorySlug
+	}
+	return ProductCategory{
+		ParentID:    in.ParentID,
+		Name:        strings.TrimSpace(in.Name),
+		Slug:        slug,
+		Description: strings.TrimSpace(in.Description),
+		Position:    in.Position,
+	}, nil
+}
+
+// unaccent troca as letras acentuadas do português pela letra sem acento nos slugs
+var unaccent = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c",
+)
+
+// Slugify gera o identificador da categoria na URL da loja: letras minúsculas sem acento e
+// números, com hífen no lugar dos demais caracteres
+func Slugify(text string) string {
+	var b strings.Builder
+	for _, r := range unaccent.Replace(strings.ToLower(strings.TrimSpace(text))) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// BuildCategoryTree monta a árvore das categorias, com as irmãs ordenadas pela posição e pelo
+// nome. Categorias cuja mãe não está na lista ficam na raiz.
+func BuildCategoryTree(categories []ProductCategory) []ProductCategory {
+	byID := make(map[int]bool, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = true
+	}
+	children := make(map[int][]ProductCategory)
+	var roots []ProductCategory
+	for _, category := range categories {
+		category.Children = nil
+		if category.ParentID != nil && byID[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+			continue
+		}
+		roots = append(roots, category)
+	}
+
+	var attach func(level []ProductCategory) []ProductCategory
+	attach = func(level []ProductCategory) []ProductCategory {
+		sort.SliceStable(level, func(i, j int) bool {
+			if level[i].Position != level[j].Position {
+				return level[i].Position < level[j].Position
+			}
+			return level[i].Name < level[j].Name
+		})
+		for i := range level {
+			level[i].Children = attach(children[level[i].ID])
+		}
+		return level
+	}
+	return attach(roots)
+}
+
+// CategoryWithDescendants retorna o ID da categoria e os das suas subcategorias em qualquer
+// nível, para filtrar o catálogo por um ramo da árvore
+func CategoryWithDescendants(categories []ProductCategory, id int) []int {
+	children := make(map[int][]int)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []int{id}
+	seen := map[int]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// CreatesCycle informa se colocar a categoria id abaixo de parentID a tornaria descendente de
+// si mesma
+func CreatesCycle(categories []ProductCategory, id, parentID int) bool {
+	parents := make(map[int]*int, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	seen := make(map[int]bool)
+	for current := &parentID; current != nil; current = parents[*current] {
+		if *current == id {
+			return true
+		}
+		if seen[*current] {
+			return false
+		}
+		seen[*current] = true
+	}
+	return false
+}
+
+// CatalogSettings define a categoria e a publicação do produto no catálogo público. Todos os
+// campos são gravados: category_id nulo tira o produto da categoria.
+type CatalogSettings struct {
+	CategoryID *int `json:"category_id"`
+	Published  bool `json:"published"`
+	Featured   bool `json:"featured"`
+}
+
+// Tipos de arquivo aceitos como imagem do produto
+var catalogImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/gif":  true,
+}
+
+// IsCatalogImage informa se o tipo do arquivo enviado pode ser exibido como imagem na loja
+func IsCatalogImage(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && catalogImageTypes[mediaType]
+}
+
+// ProductImage é uma imagem do produto. O arquivo é um anexo do produto (entidade product do
+// módulo de anexos); Position é a ordem de exibição, e a imagem de menor posição é a capa.
+type ProductImage struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	CompanyID    int       `json:"company_id" gorm:"<-:create"`
+	ProductID    int       `json:"product_id" gorm:"index"`
+	AttachmentID int       `json:"attachment_id"`
+	Position     int       `json:"position"`
+	AltText      string    `json:"alt_text,omitempty"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Attachment *attachments.Attachment `json:"attachment,omitempty" gorm:"foreignKey:AttachmentID"`
+}
+
+// TableName define o nome da tabela de imagens dos produtos
+func (ProductImage) TableName() string {
+	return "product_images"
+}
+
+// ImageOrder é a nova ordem das imagens do produto; a primeira vira a capa
+type ImageOrder struct {
+	ImageIDs []int `json:"image_ids" binding:"required"`
+}
+
+// Validate confere se a ordem traz cada imagem do produto exatamente uma vez
+func (o ImageOrder) Validate(images []ProductImage) error {
+	if len(o.ImageIDs) != len(images) {
+		return errors.ErrInvalidImageOrder
+	}
+	current := make(map[int]bool, len(images))
+	for _, image := range images {
+		current[image.ID] = true
+	}
+	for _, id := range o.ImageIDs {
+		if !current[id] {
+			return errors.ErrInvalidImageOrder
+		}
+		delete(current, id)
+	}
+	return nil
+}
+
+// CatalogFilter filtra o catálogo público: CategoryID inclui as subcategorias (CategoryIDs, que
+// o serviço preenche com o ramo da árvore) e Search procura as palavras no índice de busca (nome,
+// nome detalhado e tags), o início do SKU e as tags exatas
+type CatalogFilter struct {
+	CategoryID  int
+	CategoryIDs []int
+	Search      string
+	Featured    bool
+}
+
+// CatalogCategoryRef é a categoria do produto no catálogo público, com o caminho desde a raiz
+type CatalogCategoryRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	Path string `json:"path"`
+}
+
+// CatalogImage é uma imagem do produto no catálogo público
+type CatalogImage struct {
+	ID          int    `json:"id"`
+	URL         string `json:"url"`
+	AltText     string `json:"alt_text,omitempty"`
+	ContentType string `json:"content_type"`
+}
+
+// CatalogProduct é o produto publicado como a loja e o portal o exibem, sem custos, estoque
+// mínimo ou dados fiscais. Price é o preço de venda (ou o preço de tabela, sem preço de venda).
+type CatalogProduct struct {
+	ID              int                 `json:"id"`
+	SKU             string              `json:"sku,omitempty"`
+	Name            string              `json:"name"`
+	DetailedName    string              `json:"detailed_name"`
+	Description     string              `json:"description,omitempty"`
+	RichDescription string              `json:"rich_description,omitempty"`
+	Coin            string              `json:"coin"`
+	Price           money.Decimal       `json:"price"`
+	Manufacturer    string              `json:"manufacturer,omitempty"`
+	Tags            []string            `json:"tags,omitempty"`
+	Featured        bool                `json:"featured"`
+	InStock         bool                `json:"in_stock"`
+	Category        *CatalogCategoryRef `json:"category,omitempty"`
+	Images          []CatalogImage      `json:"images"`
+	PublishedAt     *time.Time          `json:"published_at,omitempty"`
+}
+
+// CatalogImageURL é o caminho público do arquivo da imagem
+func CatalogImageURL(imageID int) string {
+	return fmt.Sprintf("/catalog/images/%d", imageID)
+}
+
+// NewCatalogProduct monta a visão pública do produto com as imagens em ordem e a categoria
+// (categories, indexadas pelo ID, dão o caminho da categoria)
+func NewCatalogProduct(product Product, images []ProductImage, categories map[int]ProductCategory) CatalogProduct {
+	price := product.SalesPrice
+	if !price.IsPositive() {
+		price = product.Price
+	}
+	view := CatalogProduct{
+		ID:              product.ID,
+		SKU:             product.SKU,
+		Name:            product.Name,
+		DetailedName:    product.DetailedName,
+		Description:     product.Description,
+		RichDescription: product.RichDescription,
+		Coin:            product.Coin,
+		Price:           price,
+		Manufacturer:    product.Manufacturer,
+		Tags:            product.Tags,
+		Featured:        product.Featured,
+		InStock:         product.Stock > 0,
+		Images:          make([]CatalogImage, 0, len(images)),
+		PublishedAt:     product.PublishedAt,
+	}
+
+	if product.CategoryID != nil {
+		if category, ok := categories[*product.CategoryID]; ok {
+			view.Category = &CatalogCategoryRef{
+				ID:   category.ID,
+				Name: category.Name,
+				Slug: category.Slug,
+				Path: categoryPath(category, categories),
+			}
+		}
+	}
+
+	for _, image := range images {
+		catalogImage := CatalogImage{ID: image.ID, URL: CatalogImageURL(image.ID), AltText: image.AltText}
+		if catalogImage.AltText == "" {
+			catalogImage.AltText = product.Name
+		}
+		if image.Attachment != nil {
+			catalogImage.ContentType = image.Attachment.ContentType
+		}
+		view.Images = append(view.Images, catalogImage)
+	}
+	return view
+}
+
+// categoryPath monta o caminho da categoria desde a raiz, ex.: "Informática > Notebooks"
+func categoryPath(category ProductCategory, categories map[int]ProductCategory) string {
+	names := []string{category.Name}
+	seen := map[int]bool{category.ID: true}
+	for parentID := category.ParentID; parentID != nil && !seen[*parentID]; {
+		parent, ok := categories[*parentID]
+		if !ok {
+			break
+		}
+		seen[parent.ID] = true
+		names = append([]string{parent.Name}, names...)
+		parentID = parent.ParentID
+	}
+	return strings.Join(names, " > ")
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	attachments "ERP-ONSMART/backend/internal/modules/attachments/models"
+	"ERP-ONSMART/backend/internal/money"
+	"reflect"
+	"testing"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// categoryTree: Informática > Notebooks > Gamer, Informática > Cabos, Papelaria
+func categoryTree() []ProductCategory {
+	return []ProductCategory{
+		{ID: 1, Name: "Informática", Slug: "informatica", Position: 1},
+		{ID: 2, Name: "Notebooks", Slug: "notebooks", ParentID: intPtr(1), Position: 2},
+		{ID: 3, Name: "Cabos", Slug: "cabos", ParentID: intPtr(1), Position: 1},
+		{ID: 4, Name: "Gamer", Slug: "gamer", ParentID: intPtr(2)},
+		{ID: 5, Name: "Papelaria", Slug: "papelaria"},
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Cabos e Adaptadores":         "cabos-e-adaptadores",
+		"  Informática / Acessórios ": "informatica-acessorios",
+		"Ração & Petiscos!":           "racao-petiscos",
+		"TV 4K":                       "tv-4k",
+		"***":                         "",
+	}
+	for input, want := range cases {
+		if got := Slugify(input); got != want {
+			t.Errorf("Slugify(%q) = %q, esperado %q", input, got, want)
+		}
+	}
+}
+
+func TestCategoryInput(t *testing.T) {
+	category, err := CategoryInput{Name: " Cabos e Adaptadores ", ParentID: intPtr(1)}.Category()
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if category.Slug != "cabos-e-adaptadores" || category.Name != "Cabos e Adaptadores" || *category.ParentID != 1 {
+		t.Errorf("Categoria inesperada: %+v", category)
+	}
+
+	category, err = CategoryInput{Name: "Cabos", Slug: "Cabos USB"}.Category()
+	if err != nil || category.Slug != "cabos-usb" {
+		t.Errorf("Slug informado deveria ser normalizado: %+v, %v", category, err)
+	}
+
+	if _, err := (CategoryInput{Name: "???"}).Category(); err != errors.ErrInvalidCategorySlug {
+		t.Errorf("Esperado ErrInvalidCategorySlug, obtido %v", err)
+	}
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	tree := BuildCategoryTree(categoryTree())
+	if len(tree) != 2 || tree[0].Name != "Papelaria" || tree[1].Name != "Informática" {
+		t.Fatalf("Raízes inesperadas: %+v", tree)
+	}
+	children := tree[1].Children
+	if len(children) != 2 || children[0].Name != "Cabos" || children[1].Name != "Notebooks" {
+		t.Fatalf("Subcategorias fora da ordem de posição: %+v", children)
+	}
+	if len(children[1].Children) != 1 || children[1].Children[0].Name != "Gamer" {
+		t.Errorf("Terceiro nível inesperado: %+v", children[1].Children)
+	}
+
+	orphan := []ProductCategory{{ID: 9, Name: "Órfã", ParentID: intPtr(99)}}
+	if tree := BuildCategoryTree(orphan); len(tree) != 1 {
+		t.Errorf("Categoria sem mãe na lista deveria ficar na raiz: %+v", tree)
+	}
+}
+
+func TestCategoryWithDescendants(t *testing.T) {
+	if got := CategoryWithDescendants(categoryTree(), 1); !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+		t.Errorf("Ramo inesperado: %v", got)
+	}
+	if got := CategoryWithDescendants(categoryTree(), 5); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("Categoria sem filhas deveria retornar só ela: %v", got)
+	}
+}
+
+func TestCreatesCycle(t *testing.T) {
+	categories := categoryTree()
+	if !CreatesCycle(categories, 1, 4) {
+		t.Error("Mover Informática para baixo de Gamer deveria criar ciclo")
+	}
+	if !CreatesCycle(categories, 2, 2) {
+		t.Error("A categoria não pode ser mãe de si mesma")
+	}
+	if CreatesCycle(categories, 4, 3) {
+		t.Error("Mover Gamer para Cabos não cria ciclo")
+	}
+}
+
+func TestIsCatalogImage(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"image/png":                  true,
+		"image/jpeg; charset=binary": true,
+		"image/svg+xml":              false,
+		"application/pdf":            false,
+		"":                           false,
+	} {
+		if got := IsCatalogImage(contentType); got != want {
+			t.Errorf("IsCatalogImage(%q) = %v, esperado %v", contentType, got, want)
+		}
+	}
+}
+
+func TestImageOrderValidate(t *testing.T) {
+	images := []ProductImage{{ID: 10}, {ID: 11}, {ID: 12}}
+	if err := (ImageOrder{ImageIDs: []int{12, 10, 11}}).Validate(images); err != nil {
+		t.Errorf("Ordem válida recusada: %v", err)
+	}
+	for _, ids := range [][]int{{10, 11}, {10, 10, 11}, {10, 11, 99}} {
+		if err := (ImageOrder{ImageIDs: ids}).Validate(images); err != errors.ErrInvalidImageOrder {
+			t.Errorf("Ordem %v: esperado ErrInvalidImageOrder, obtido %v", ids, err)
+		}
+	}
+}
+
+func TestNewCatalogProduct(t *testing.T) {
+	categories := make(map[int]ProductCategory)
+	for _, category := range categoryTree() {
+		categories[category.ID] = category
+	}
+	product := Product{
+		ID: 7, Name: "Notebook X", SKU: "NB-X", Coin: "BRL", Price: money.FromInt(5000), SalesPrice: money.FromInt(4500), CostPrice: money.FromInt(3000),
+		Stock: 0, CategoryID: intPtr(4), Featured: true,
+	}
+	images := []ProductImage{
+		{ID: 21, Attachment: &attachments.Attachment{ContentType: "image/png"}},
+		{ID: 22, AltText: "Lateral", Attachment: &attachments.Attachment{ContentType: "image/jpeg"}},
+	}
+
+	view := NewCatalogProduct(product, images, categories)
+	if !view.Price.Equal(money.FromInt(4500)) || view.InStock || !view.Featured {
+		t.Errorf("Visão pública inesperada: %+v", view)
+	}
+	if view.Category == nil || view.Category.Path != "Informática > Notebooks > Gamer" || view.Category.Slug != "gamer" {
+		t.Fatalf("Categoria inesperada: %+v", view.Category)
+	}
+	if len(view.Images) != 2 || view.Images[0].URL != "/catalog/images/21" || view.Images[0].AltText != "Notebook X" ||
+		view.Images[1].AltText != "Lateral" || view.Images[1].ContentType != "image/jpeg" {
+		t.Errorf("Imagens inesperadas: %+v", view.Images)
+	}
+
+	product.SalesPrice = money.Zero
+	product.CategoryID = nil
+	view = NewCatalogProduct(product, nil, categories)
+	if !view.Price.Equal(money.FromInt(5000)) || view.Category != nil || view.Images == nil {
+		t.Errorf("Sem preço de venda vale o preço de tabela, e as imagens são uma lista vazia: %+v", view)
+	}
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"math"
+	"time"
+)
+
+// Tratamento do kit nos itens de cotação, pedido de venda e fatura
+const (
+	// KitModeExplode substitui a linha do kit pelas linhas dos componentes, com o preço rateado
+	KitModeExplode = "explode"
+	// KitModeKeep mantém a linha do kit; o estoque e o custo são baixados nos componentes
+	KitModeKeep = "keep"
+)
+
+// ProductKit é um produto composto por outros produtos (componentes) em quantidades fixas
+type ProductKit struct {
+	ID         int            `json:"id" gorm:"primaryKey"`
+	CompanyID  int            `json:"company_id" gorm:"<-:create"`
+	ProductID  int            `json:"product_id" gorm:"uniqueIndex"`
+	Mode       string         `json:"mode"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	Components []KitComponent `json:"components" gorm:"foreignKey:KitID"`
+
+	// Quantidade de kits que o estoque atual dos componentes permite montar
+	Available int `json:"available" gorm:"-"`
+}
+
+// TableName define o nome da tabela de kits
+func (ProductKit) TableName() string {
+	return "product_kits"
+}
+
+// KitComponent é um item do kit: o produto componente e a quantidade por kit
+type KitComponent struct {
+	ID                 int `json:"id" gorm:"primaryKey"`
+	KitID              int `json:"kit_id" gorm:"index"`
+	ComponentProductID int `json:"component_product_id"`
+	Quantity           int `json:"quantity"`
+
+	// Dados do produto componente, carregados com o kit
+	ProductName string        `json:"product_name" gorm:"-"`
+	ProductCode string        `json:"product_code" gorm:"-"`
+	Unit        string        `json:"unit" gorm:"-"`
+	UnitPrice   money.Decimal `json:"unit_price" gorm:"-"`
+	UnitCost    money.Decimal `json:"unit_cost" gorm:"-"`
+	Stock       int           `json:"stock" gorm:"-"`
+}
+
+// TableName define o nome da tabela de componentes do kit
+func (KitComponent) TableName() string {
+	return "product_kit_components"
+}
+
+// KitInput define a composição do kit e como ele entra nos documentos de venda
+type KitInput struct {
+	Mode       string              `json:"mode" binding:"required,oneof=explode keep"`
+	Components []KitComponentInput `json:"components" binding:"required,min=1,dive"`
+}
+
+// KitComponentInput é um componente informado na composição do kit
+type KitComponentInput struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"required,gt=0"`
+}
+
+// KitLine é a linha de um componente gerada a partir de uma linha do kit
+type KitLine struct {
+	ProductID   int
+	ProductName string
+	ProductCode string
+	Unit        string
+	Quantity    int
+	UnitPrice   money.Decimal
+	Discount    money.Decimal
+	Tax         money.Decimal
+}
+
+// KitMargin resume a receita faturada e o custo apurado de um kit no período
+type KitMargin struct {
+	ProductID   int           `json:"product_id"`
+	ProductName string        `json:"product_name"`
+	SKU         string        `json:"sku"`
+	Mode        string        `json:"mode"`
+	Revenue     money.Decimal `json:"revenue"`
+	Cost        money.Decimal `json:"cost"`
+	Margin      money.Decimal `json:"margin"`
+	MarginPct   float64       `json:"margin_pct"`
+}
+
+// ValidateKitComponents confere a composição: sem o próprio kit entre os componentes e sem
+// componentes repetidos
+func ValidateKitComponents(kitProductID int, components []KitComponentInput) error {
+	seen := make(map[int]bool, len(components))
+	for _, component := range components {
+		if component.ProductID == kitProductID {
+			return errors.ErrKitContainsItself
+		}
+		if seen[component.ProductID] {
+			return errors.ErrDuplicateKitComponent
+		}
+		seen[component.ProductID] = true
+	}
+	return nil
+}
+
+// Explode desdobra quantity kits (em unidades de estoque do kit) nas linhas dos componentes. O valor da linha (quantidade x
+// preço), o desconto e o imposto são rateados pelo preço de tabela de cada componente (ou pela
+// quantidade, se nenhum tiver preço); o último componente absorve as diferenças de arredondamento.
+func (k ProductKit) Explode(quantity int, unitPrice, discount, tax money.Decimal) []KitLine {
+	if len(k.Components) == 0 || quantity <= 0 {
+		return nil
+	}
+
+	weights := make([]money.Decimal, len(k.Components))
+	var totalWeight money.Decimal
+	for i, component := range k.Components {
+		weights[i] = component.UnitPrice.MulInt(component.Quantity)
+		totalWeight = totalWeight.Add(weights[i])
+	}
+	if totalWeight.IsZero() {
+		for i, component := range k.Components {
+			weights[i] = money.FromInt(int64(component.Quantity))
+			totalWeight = totalWeight.Add(weights[i])
+		}
+	}
+
+	lineValue := unitPrice.MulInt(quantity)
+	lines := make([]KitLine, 0, len(k.Components))
+	var allocatedValue, allocatedDiscount, allocatedTax money.Decimal
+	for i, component := range k.Components {
+		componentQty := quantity * component.Quantity
+
+		value := money.Round(lineValue.Mul(weights[i]).Div(totalWeight))
+		lineDiscount := money.Round(discount.Mul(weights[i]).Div(totalWeight))
+		lineTax := money.Round(tax.Mul(weights[i]).Div(totalWeight))
+		if i == len(k.Components)-1 {
+			value = lineValue.Sub(allocatedValue)
+			lineDiscount = discount.Sub(allocatedDiscount)
+			lineTax = tax.Sub(allocatedTax)
+		}
+		allocatedValue = allocatedValue.Add(value)
+		allocatedDiscount = allocatedDiscount.Add(lineDiscount)
+		allocatedTax = allocatedTax.Add(lineTax)
+
+		lines = append(lines, KitLine{
+			ProductID:   component.ComponentProductID,
+			ProductName: component.ProductName,
+			ProductCode: component.ProductCode,
+			Unit:        component.Unit,
+			Quantity:    componentQty,
+			UnitPrice:   value.DivInt(componentQty),
+			Discount:    lineDiscount,
+			Tax:         lineTax,
+		})
+	}
+	return lines
+}
+
+// BuildableQuantity calcula quantos kits o estoque dos componentes permite montar
+func (k ProductKit) BuildableQuantity() int {
+	if len(k.Components) == 0 {
+		return 0
+	}
+	buildable := math.MaxInt
+	for _, component := range k.Components {
+		if component.Quantity <= 0 {
+			continue
+		}
+		buildable = min(buildable, max(component.Stock, 0)/component.Quantity)
+	}
+	if buildable == math.MaxInt {
+		return 0
+	}
+	return buildable
+}
+
+// ApplyMargin calcula a margem bruta e o percentual sobre a receita
+func (m *KitMargin) ApplyMargin() {
+	m.Revenue = money.Round(m.Revenue)
+	m.Cost = money.Round(m.Cost)
+	m.Margin = m.Revenue.Sub(m.Cost)
+	if !m.Revenue.IsZero() {
+		m.MarginPct = round2(m.Margin.Float64() / m.Revenue.Float64() * 100)
+	}
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+)
+
+func testKit() ProductKit {
+	return ProductKit{
+		ProductID: 10,
+		Mode:      KitModeExplode,
+		Components: []KitComponent{
+			{ComponentProductID: 1, Quantity: 1, UnitPrice: money.FromInt(300), Stock: 7},
+			{ComponentProductID: 2, Quantity: 2, UnitPrice: money.FromInt(50), Stock: 9},
+		},
+	}
+}
+
+func TestKitExplodeAllocatesByListPrice(t *testing.T) {
+	lines := testKit().Explode(2, money.FromInt(360), money.FromInt(20), money.FromInt(36))
+	if len(lines) != 2 {
+		t.Fatalf("Esperadas 2 linhas, obtidas %d", len(lines))
+	}
+
+	// Preço de tabela do kit: 300 + 2 x 50 = 400; o componente 1 responde por 75% do valor
+	if lines[0].ProductID != 1 || lines[0].Quantity != 2 || !lines[0].UnitPrice.Equal(money.FromInt(270)) {
+		t.Errorf("Linha inesperada para o componente 1: %+v", lines[0])
+	}
+	if lines[1].ProductID != 2 || lines[1].Quantity != 4 || !lines[1].UnitPrice.Equal(money.FromInt(45)) {
+		t.Errorf("Linha inesperada para o componente 2: %+v", lines[1])
+	}
+	if !lines[0].Discount.Add(lines[1].Discount).Equal(money.FromInt(20)) || !lines[0].Tax.Add(lines[1].Tax).Equal(money.FromInt(36)) {
+		t.Errorf("Desconto e imposto devem somar os da linha do kit: %+v", lines)
+	}
+}
+
+func TestKitExplodeRoundingGoesToLastComponent(t *testing.T) {
+	kit := ProductKit{Components: []KitComponent{
+		{ComponentProductID: 1, Quantity: 1},
+		{ComponentProductID: 2, Quantity: 1},
+		{ComponentProductID: 3, Quantity: 1},
+	}}
+	lines := kit.Explode(1, money.FromInt(100), money.MustParse("0.10"), money.Zero)
+
+	var value, discount money.Decimal
+	for _, line := range lines {
+		value = value.Add(line.UnitPrice.MulInt(line.Quantity))
+		discount = discount.Add(line.Discount)
+	}
+	if !value.Equal(money.FromInt(100)) || !discount.Equal(money.MustParse("0.10")) {
+		t.Errorf("Rateio deve preservar o valor e o desconto da linha: valor %s, desconto %s", value, discount)
+	}
+	if !lines[2].UnitPrice.Equal(money.MustParse("33.34")) {
+		t.Errorf("O último componente deve absorver o arredondamento, obtido %s", lines[2].UnitPrice)
+	}
+}
+
+func TestKitBuildableQuantity(t *testing.T) {
+	if got := testKit().BuildableQuantity(); got != 4 {
+		t.Errorf("Esperados 4 kits montáveis (9 / 2 do componente 2), obtidos %d", got)
+	}
+	if got := (ProductKit{}).BuildableQuantity(); got != 0 {
+		t.Errorf("Kit sem componentes não é montável, obtido %d", got)
+	}
+}
+
+func TestValidateKitComponents(t *testing.T) {
+	if err := ValidateKitComponents(10, []KitComponentInput{{ProductID: 10, Quantity: 1}}); err != errors.ErrKitContainsItself {
+		t.Errorf("Esperado ErrKitContainsItself, obtido %v", err)
+	}
+	if err := ValidateKitComponents(10, []KitComponentInput{{ProductID: 1, Quantity: 1}, {ProductID: 1, Quantity: 2}}); err != errors.ErrDuplicateKitComponent {
+		t.Errorf("Esperado ErrDuplicateKitComponent, obtido %v", err)
+	}
+	if err := ValidateKitComponents(10, []KitComponentInput{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 2}}); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+}
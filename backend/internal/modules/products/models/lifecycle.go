@@ -0,0 +1,128 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+)
+
+// Estados do ciclo de vida do produto. Descontinuado sai das novas cotações, mas pode voltar a
+// ativo; fim de vida é definitivo. Cotações, pedidos e faturas já emitidos continuam válidos em
+// qualquer estado.
+const (
+	StatusActive       = "ativo"
+	StatusInactive     = "desativado"
+	StatusDiscontinued = "descontinuado"
+	StatusEndOfLife    = "fim_de_vida"
+)
+
+// BlocksNewQuotations informa se o estado impede o produto de entrar em novas cotações
+func BlocksNewQuotations(status string) bool {
+	return status == StatusDiscontinued || status == StatusEndOfLife
+}
+
+// CheckTransition recusa a saída do fim de vida, que é o último estado do produto
+func CheckTransition(from, to string) error {
+	if from == StatusEndOfLife && to != StatusEndOfLife {
+		return fmt.Errorf("%w: o produto está em fim de vida", errors.ErrInvalidLifecycleTransition)
+	}
+	return nil
+}
+
+// LifecycleInput muda o estado do produto e o produto que o substitui nas novas cotações.
+// Todos os campos são gravados: replacement_product_id nulo remove o substituto.
+type LifecycleInput struct {
+	Status               string `json:"status" binding:"required,oneof=ativo desativado descontinuado fim_de_vida"`
+	ReplacementProductID *int   `json:"replacement_product_id"`
+}
+
+// LifecycleProduct são os dados do produto (lidos da tabela products) usados nas regras do ciclo
+// de vida e na sugestão de substituto
+type LifecycleProduct struct {
+	ID                   int           `json:"id"`
+	Name                 string        `json:"name"`
+	SKU                  string        `json:"sku"`
+	Status               string        `json:"status"`
+	Price                money.Decimal `json:"-"`
+	SalesPrice           money.Decimal `json:"-"`
+	CategoryID           *int          `json:"-"`
+	ProductCategory      string        `json:"-"`
+	ReplacementProductID *int          `json:"-"`
+}
+
+// SellingPrice é o preço de venda do produto, ou o preço de tabela sem preço de venda
+func (p LifecycleProduct) SellingPrice() money.Decimal {
+	if p.SalesPrice.IsPositive() {
+		return p.SalesPrice
+	}
+	return p.Price
+}
+
+// Origem da sugestão de substituto
+const (
+	ReplacementFromLink     = "link"
+	ReplacementFromCategory = "category"
+)
+
+// ReplacementSuggestion é o produto sugerido no lugar de um produto bloqueado: o substituto
+// cadastrado (seguindo a cadeia de substitutos até um produto vendável) ou, sem ele, o produto
+// ativo da mesma categoria com o preço mais próximo
+type ReplacementSuggestion struct {
+	ProductID int    `json:"product_id"`
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+}
+
+// FollowReplacements segue os substitutos a partir do produto até o primeiro que pode entrar em
+// novas cotações. lookup busca um produto pelo ID (nil se não existir); cadeias circulares ou
+// que terminam em produto bloqueado não sugerem nada.
+func FollowReplacements(product LifecycleProduct, lookup func(id int) *LifecycleProduct) *LifecycleProduct {
+	seen := map[int]bool{product.ID: true}
+	for next := product.ReplacementProductID; next != nil && !seen[*next]; {
+		seen[*next] = true
+		candidate := lookup(*next)
+		if candidate == nil {
+			return nil
+		}
+		if !BlocksNewQuotations(candidate.Status) && candidate.Status != StatusInactive {
+			return candidate
+		}
+		next = candidate.ReplacementProductID
+	}
+	return nil
+}
+
+// CreatesReplacementCycle informa se ligar o produto id ao substituto replacementID faria a
+// cadeia de substitutos voltar ao próprio produto
+func CreatesReplacementCycle(id, replacementID int, lookup func(id int) *LifecycleProduct) bool {
+	seen := make(map[int]bool)
+	for current := &replacementID; current != nil; {
+		if *current == id {
+			return true
+		}
+		if seen[*current] {
+			return false
+		}
+		seen[*current] = true
+		product := lookup(*current)
+		if product == nil {
+			return false
+		}
+		current = product.ReplacementProductID
+	}
+	return false
+}
+
+// BlockedProductError monta o erro de produto bloqueado com o substituto sugerido, se houver
+func BlockedProductError(product LifecycleProduct, suggestion *ReplacementSuggestion) error {
+	state := "descontinuado"
+	if product.Status == StatusEndOfLife {
+		state = "em fim de vida"
+	}
+	detail := fmt.Sprintf("%s (SKU %s) está %s", product.Name, product.SKU, state)
+	if suggestion != nil {
+		detail += fmt.Sprintf("; substituto sugerido: %s (SKU %s, ID %d)", suggestion.Name, suggestion.SKU, suggestion.ProductID)
+	}
+	return fmt.Errorf("%w: %s", errors.ErrProductDiscontinued, detail)
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	stderrors "errors"
+	"strings"
+	"testing"
+)
+
+// replacementChain: 1 (descontinuado) -> 2 (fim de vida) -> 3 (desativado) -> 4 (ativo)
+func replacementChain() map[int]*LifecycleProduct {
+	return map[int]*LifecycleProduct{
+		1: {ID: 1, Name: "Mouse V1", SKU: "MS-1", Status: StatusDiscontinued, ReplacementProductID: intPtr(2)},
+		2: {ID: 2, Name: "Mouse V2", SKU: "MS-2", Status: StatusEndOfLife, ReplacementProductID: intPtr(3)},
+		3: {ID: 3, Name: "Mouse V3", SKU: "MS-3", Status: StatusInactive, ReplacementProductID: intPtr(4)},
+		4: {ID: 4, Name: "Mouse V4", SKU: "MS-4", Status: StatusActive},
+	}
+}
+
+func lookupIn(products map[int]*LifecycleProduct) func(id int) *LifecycleProduct {
+	return func(id int) *LifecycleProduct { return products[id] }
+}
+
+func TestBlocksNewQuotations(t *testing.T) {
+	for status, want := range map[string]bool{
+		StatusActive:       false,
+		StatusInactive:     false,
+		StatusDiscontinued: true,
+		StatusEndOfLife:    true,
+	} {
+		if got := BlocksNewQuotations(status); got != want {
+			t.Errorf("BlocksNewQuotations(%q) = %v, esperado %v", status, got, want)
+		}
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	if err := CheckTransition(StatusDiscontinued, StatusActive); err != nil {
+		t.Errorf("Descontinuado pode voltar a ativo: %v", err)
+	}
+	if err := CheckTransition(StatusActive, StatusEndOfLife); err != nil {
+		t.Errorf("Ativo pode ir para fim de vida: %v", err)
+	}
+	if err := CheckTransition(StatusEndOfLife, StatusEndOfLife); err != nil {
+		t.Errorf("Manter o fim de vida não é transição: %v", err)
+	}
+	if err := CheckTransition(StatusEndOfLife, StatusActive); !stderrors.Is(err, errors.ErrInvalidLifecycleTransition) {
+		t.Errorf("Esperado ErrInvalidLifecycleTransition, obtido %v", err)
+	}
+}
+
+func TestFollowReplacements(t *testing.T) {
+	products := replacementChain()
+	if got := FollowReplacements(*products[1], lookupIn(products)); got == nil || got.ID != 4 {
+		t.Errorf("Deveria pular substitutos bloqueados e desativados até o 4: %+v", got)
+	}
+
+	products[4].Status = StatusDiscontinued
+	if got := FollowReplacements(*products[1], lookupIn(products)); got != nil {
+		t.Errorf("Cadeia sem produto vendável não sugere nada: %+v", got)
+	}
+
+	products[4].ReplacementProductID = intPtr(1)
+	if got := FollowReplacements(*products[1], lookupIn(products)); got != nil {
+		t.Errorf("Cadeia circular não sugere nada: %+v", got)
+	}
+
+	if got := FollowReplacements(*products[4], lookupIn(map[int]*LifecycleProduct{})); got != nil {
+		t.Errorf("Substituto removido não sugere nada: %+v", got)
+	}
+}
+
+func TestCreatesReplacementCycle(t *testing.T) {
+	products := replacementChain()
+	if !CreatesReplacementCycle(4, 1, lookupIn(products)) {
+		t.Error("Ligar o 4 ao 1 fecharia o ciclo 1 -> 2 -> 3 -> 4 -> 1")
+	}
+	if CreatesReplacementCycle(1, 3, lookupIn(products)) {
+		t.Error("Ligar o 1 ao 3 não cria ciclo")
+	}
+	if CreatesReplacementCycle(5, 9, lookupIn(products)) {
+		t.Error("Substituto inexistente não cria ciclo")
+	}
+}
+
+func TestBlockedProductError(t *testing.T) {
+	product := *replacementChain()[2]
+	err := BlockedProductError(product, &ReplacementSuggestion{ProductID: 4, SKU: "MS-4", Name: "Mouse V4", Source: ReplacementFromLink})
+	if !stderrors.Is(err, errors.ErrProductDiscontinued) {
+		t.Fatalf("Esperado ErrProductDiscontinued, obtido %v", err)
+	}
+	if !strings.Contains(err.Error(), "Mouse V2 (SKU MS-2) está em fim de vida; substituto sugerido: Mouse V4 (SKU MS-4, ID 4)") {
+		t.Errorf("Mensagem inesperada: %v", err)
+	}
+
+	err = BlockedProductError(*replacementChain()[1], nil)
+	if strings.Contains(err.Error(), "substituto") || !strings.Contains(err.Error(), "está descontinuado") {
+		t.Errorf("Sem sugestão a mensagem não cita substituto: %v", err)
+	}
+}
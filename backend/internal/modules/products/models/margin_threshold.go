@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// CategoryMarginThreshold é a margem mínima (em % da receita) das vendas dos produtos da
+// categoria e das subcategorias sem margem própria, conferida na contabilização das faturas
+type CategoryMarginThreshold struct {
+	CategoryID       int       `json:"category_id" gorm:"primaryKey;autoIncrement:false"`
+	CompanyID        int       `json:"company_id" gorm:"<-:create"`
+	MinMarginPercent float64   `json:"min_margin_percent"`
+	UpdatedBy        string    `json:"updated_by"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de margens mínimas
+func (CategoryMarginThreshold) TableName() string {
+	return "category_margin_thresholds"
+}
+
+// MarginThresholdInput define a margem mínima da categoria; negativa, tolera vender abaixo do custo
+// até o limite (ex.: ponta de estoque)
+type MarginThresholdInput struct {
+	MinMarginPercent *float64 `json:"min_margin_percent" binding:"required,gte=-100,lt=100"`
+}
+
+// MinMargin é a margem mínima que vale para uma categoria e a categoria em que foi definida
+type MinMargin struct {
+	CategoryID       int     `json:"category_id"`
+	CategoryName     string  `json:"category_name"`
+	MinMarginPercent float64 `json:"min_margin_percent"`
+	SourceCategoryID int     `json:"source_category_id"`
+	Inherited        bool    `json:"inherited"`
+}
+
+// ResolveMinMargins devolve a margem mínima de cada categoria: a própria ou a da categoria mãe
+// mais próxima que tiver. Categorias sem margem na cadeia ficam fora do mapa.
+func ResolveMinMargins(categories []ProductCategory, thresholds []CategoryMarginThreshold) map[int]MinMargin {
+	byID := make(map[int]ProductCategory, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	own := make(map[int]float64, len(thresholds))
+	for _, threshold := range thresholds {
+		own[threshold.CategoryID] = threshold.MinMarginPercent
+	}
+
+	margins := make(map[int]MinMargin)
+	for _, category := range categories {
+		visited := make(map[int]bool)
+		current, ok := category, true
+		for ok && !visited[current.ID] {
+			visited[current.ID] = true
+			if percent, found := own[current.ID]; found {
+				margins[category.ID] = MinMargin{
+					CategoryID:       category.ID,
+					CategoryName:     category.Name,
+					MinMarginPercent: percent,
+					SourceCategoryID: current.ID,
+					Inherited:        current.ID != category.ID,
+				}
+				break
+			}
+			if current.ParentID == nil {
+				break
+			}
+			current, ok = byID[*current.ParentID]
+		}
+	}
+	return margins
+}
@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestResolveMinMargins(t *testing.T) {
+	// Informática (1) -> Cabos (2) -> HDMI (3); Móveis (4) sem margem
+	categories := []ProductCategory{
+		{ID: 1, Name: "Informática"},
+		{ID: 2, ParentID: intPtr(1), Name: "Cabos"},
+		{ID: 3, ParentID: intPtr(2), Name: "HDMI"},
+		{ID: 4, Name: "Móveis"},
+	}
+	margins := ResolveMinMargins(categories, []CategoryMarginThreshold{
+		{CategoryID: 1, MinMarginPercent: 15},
+		{CategoryID: 2, MinMarginPercent: 8},
+	})
+
+	if m := margins[1]; m.MinMarginPercent != 15 || m.Inherited {
+		t.Errorf("Margem própria da raiz: %+v", m)
+	}
+	if m := margins[3]; m.MinMarginPercent != 8 || m.SourceCategoryID != 2 || !m.Inherited || m.CategoryName != "HDMI" {
+		t.Errorf("HDMI deveria herdar a margem de Cabos, a mãe mais próxima: %+v", m)
+	}
+	if _, ok := margins[4]; ok {
+		t.Error("Categoria sem margem na cadeia fica fora do mapa")
+	}
+
+	// Cadeia circular gravada por engano não trava a resolução
+	cycle := []ProductCategory{{ID: 5, ParentID: intPtr(6)}, {ID: 6, ParentID: intPtr(5)}}
+	if margins := ResolveMinMargins(cycle, []CategoryMarginThreshold{{CategoryID: 9, MinMarginPercent: 1}}); len(margins) != 0 {
+		t.Errorf("Ciclo sem margem não deveria resolver nada: %+v", margins)
+	}
+}
@@ -3,6 +3,9 @@ package models
 import (
 	"time"
 
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	"ERP-ONSMART/backend/internal/money"
+
 	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
@@ -11,22 +14,31 @@ type Product struct {
 	gorm.Model
 	// Identification fields
 	ID           int    `gorm:"primaryKey" json:"id"`
+	CompanyID    int    `json:"company_id" gorm:"<-:create"`
 	Name         string `gorm:"column:name" json:"name" binding:"required"`
 	DetailedName string `gorm:"column:detailed_name" json:"detailed_name" binding:"required"`
 	Description  string `gorm:"column:description" json:"description"`
-	Status       string `gorm:"column:status" json:"status" binding:"required,oneof=ativo desativado descontinuado"`
-	SKU          string `gorm:"column:sku" json:"sku"`
-	Barcode      string `gorm:"column:barcode" json:"barcode"`
-	ExternalID   string `gorm:"column:external_id" json:"external_id,omitempty"`
+	Status       string `gorm:"column:status" json:"status" binding:"required,oneof=ativo desativado descontinuado fim_de_vida"`
+	// Produto sugerido no lugar deste quando descontinuado ou em fim de vida; alterado por
+	// PUT /products/:id/lifecycle
+	ReplacementProductID *int   `gorm:"column:replacement_product_id;<-:false" json:"replacement_product_id,omitempty"`
+	SKU                  string `gorm:"column:sku" json:"sku"`
+	Barcode              string `gorm:"column:barcode" json:"barcode"`
+	ExternalID           string `gorm:"column:external_id" json:"external_id,omitempty"`
 
 	// Price related
-	Coin       string  `gorm:"column:coin" json:"coin" binding:"required,oneof=BRL USD EUR CAD ADOBE_USD"`
-	Price      float64 `gorm:"column:price" json:"price" binding:"required,gte=0"`
-	SalesPrice float64 `gorm:"column:sales_price" json:"sales_price" binding:"gte=0"`
-	CostPrice  float64 `gorm:"column:cost_price" json:"cost_price" binding:"gte=0"`
+	Coin       string        `gorm:"column:coin" json:"coin" binding:"required,oneof=BRL USD EUR CAD ADOBE_USD"`
+	Price      money.Decimal `gorm:"column:price" json:"price" binding:"required,gte=0"`
+	SalesPrice money.Decimal `gorm:"column:sales_price" json:"sales_price" binding:"gte=0"`
+	CostPrice  money.Decimal `gorm:"column:cost_price" json:"cost_price" binding:"gte=0"`
 
 	// Inventory related
-	Stock int `gorm:"column:stock" json:"stock" binding:"gte=0"`
+	Stock               int  `gorm:"column:stock" json:"stock" binding:"gte=0"`
+	ReorderPoint        int  `gorm:"column:reorder_point" json:"reorder_point" binding:"gte=0"`
+	ReorderQuantity     int  `gorm:"column:reorder_quantity" json:"reorder_quantity" binding:"gte=0"`
+	PreferredSupplierID *int `gorm:"column:preferred_supplier_id" json:"preferred_supplier_id,omitempty"`
+	// Endereço de estoque padrão, usado na lista de separação das entregas
+	BinID *int `gorm:"column:bin_id" json:"bin_id,omitempty"`
 
 	// Classification
 	Type               string         `gorm:"column:type" json:"type"`
@@ -37,12 +49,23 @@ type Product struct {
 	Manufacturer       string         `gorm:"column:manufacturer" json:"manufacturer"`
 	ManufacturerCode   string         `gorm:"column:manufacturer_code" json:"manufacturer_code"`
 
+	// Catálogo público: a categoria e a publicação são alteradas por PUT /products/:id/catalog;
+	// RichDescription é a descrição em HTML ou Markdown exibida na loja
+	CategoryID      *int       `gorm:"column:category_id;<-:false" json:"category_id,omitempty"`
+	RichDescription string     `gorm:"column:rich_description" json:"rich_description,omitempty"`
+	Published       bool       `gorm:"column:published;<-:false" json:"published"`
+	Featured        bool       `gorm:"column:featured;<-:false" json:"featured"`
+	PublishedAt     *time.Time `gorm:"column:published_at;<-:false" json:"published_at,omitempty"`
+
 	// Fiscal related
 	NCM    string `gorm:"column:ncm" json:"ncm"`
 	CEST   string `gorm:"column:cest" json:"cest"`
 	CNAE   string `gorm:"column:cnae" json:"cnae"`
 	Origin string `gorm:"column:origin" json:"origin"`
 
+	// Campos personalizados definidos em /custom-fields (entidade product)
+	CustomFields customfields.Values `gorm:"column:custom_fields;type:jsonb" json:"custom_fields"`
+
 	// Campos temporais
 	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
@@ -54,8 +77,8 @@ type Product struct {
 
 // Warranty representa a garantia do produto.
 type Warranty struct {
-	ID             int     `json:"id"`
-	ProductID      int     `json:"product_id" binding:"required"`
-	DurationMonths int     `json:"duration_months"`
-	Price          float64 `json:"price" binding:"required,gt=0"`
+	ID             int           `json:"id"`
+	ProductID      int           `json:"product_id" binding:"required"`
+	DurationMonths int           `json:"duration_months"`
+	Price          money.Decimal `json:"price" binding:"required,gt=0"`
 }
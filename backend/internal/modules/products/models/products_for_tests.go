@@ -1,6 +1,8 @@
 // This file contains test data for the products module.
 package models
 
+import "ERP-ONSMART/backend/internal/money"
+
 var ProductToAdd = Product{
 	Name:               "Produto Teste",
 	DetailedName:       "Produto Teste Detalhado",
@@ -10,9 +12,9 @@ var ProductToAdd = Product{
 	Barcode:            "1234567890123",
 	ExternalID:         "EXT123",
 	Coin:               "BRL",
-	Price:              123.45,
-	SalesPrice:         110.00,
-	CostPrice:          100.00,
+	Price:              money.MustParse("123.45"),
+	SalesPrice:         money.FromInt(110),
+	CostPrice:          money.FromInt(100),
 	Stock:              10,
 	Type:               "Produto",
 	ProductGroup:       "Grupo Teste",
@@ -38,9 +40,9 @@ var ProductToUpdate = Product{
 	Barcode:            "9876543210987",
 	ExternalID:         "EXT456",
 	Coin:               "USD",
-	Price:              200.00,
-	SalesPrice:         180.00,
-	CostPrice:          150.00,
+	Price:              money.FromInt(200),
+	SalesPrice:         money.FromInt(180),
+	CostPrice:          money.FromInt(150),
 	Stock:              20,
 	Type:               "Serviço",
 	ProductGroup:       "Grupo Atualizado",
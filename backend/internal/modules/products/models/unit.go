@@ -0,0 +1,143 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"strings"
+	"time"
+)
+
+// DefaultUnit é a unidade de estoque dos produtos sem unidades cadastradas
+const DefaultUnit = "UN"
+
+// Finalidade da unidade, usada para escolher a unidade padrão de uma linha sem unidade
+const (
+	UnitPurposePurchase = "purchase"
+	UnitPurposeSales    = "sales"
+)
+
+// ProductUnit é uma unidade de medida do produto. Factor é a quantidade de unidades de estoque
+// contida em uma unidade (ex.: CX com fator 12); a unidade de estoque tem fator 1.
+type ProductUnit struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	CompanyID  int       `json:"company_id" gorm:"<-:create"`
+	ProductID  int       `json:"product_id" gorm:"index"`
+	Unit       string    `json:"unit"`
+	Name       string    `json:"name"`
+	Factor     int       `json:"factor"`
+	IsPurchase bool      `json:"is_purchase"`
+	IsSales    bool      `json:"is_sales"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de unidades de medida dos produtos
+func (ProductUnit) TableName() string {
+	return "product_units"
+}
+
+// UnitsInput define todas as unidades de medida do produto
+type UnitsInput struct {
+	Units []ProductUnitInput `json:"units" binding:"required,min=1,dive"`
+}
+
+// ProductUnitInput é uma unidade informada no cadastro de unidades do produto
+type ProductUnitInput struct {
+	Unit       string `json:"unit" binding:"required,max=10"`
+	Name       string `json:"name" binding:"max=50"`
+	Factor     int    `json:"factor" binding:"required,gt=0"`
+	IsPurchase bool   `json:"is_purchase"`
+	IsSales    bool   `json:"is_sales"`
+}
+
+// UnitSet são as unidades de medida cadastradas para um produto
+type UnitSet []ProductUnit
+
+// NormalizeUnit padroniza o código da unidade (sem espaços, em maiúsculas)
+func NormalizeUnit(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidateUnits confere o cadastro: exatamente uma unidade de estoque (fator 1), sem códigos
+// repetidos e no máximo uma unidade padrão de compra e uma de venda. Os códigos são normalizados.
+func ValidateUnits(units []ProductUnitInput) error {
+	seen := make(map[string]bool, len(units))
+	var base, purchase, sales int
+	for i := range units {
+		units[i].Unit = NormalizeUnit(units[i].Unit)
+		unit := units[i]
+		if unit.Unit == "" {
+			return errors.ErrUnknownUnit
+		}
+		if seen[unit.Unit] {
+			return errors.ErrDuplicateUnit
+		}
+		seen[unit.Unit] = true
+		if unit.Factor == 1 {
+			base++
+		}
+		if unit.IsPurchase {
+			purchase++
+		}
+		if unit.IsSales {
+			sales++
+		}
+	}
+	if base != 1 {
+		return errors.ErrBaseUnitRequired
+	}
+	if purchase > 1 || sales > 1 {
+		return errors.ErrMultipleDefaultUnits
+	}
+	return nil
+}
+
+// Base retorna a unidade de estoque; produtos sem unidades cadastradas usam DefaultUnit
+func (s UnitSet) Base() ProductUnit {
+	for _, unit := range s {
+		if unit.Factor == 1 {
+			return unit
+		}
+	}
+	return ProductUnit{Unit: DefaultUnit, Factor: 1}
+}
+
+// Resolve retorna a unidade da linha de um documento. Sem código, vale a unidade padrão da
+// finalidade (compra ou venda) ou, na falta dela, a unidade de estoque.
+func (s UnitSet) Resolve(code, purpose string) (ProductUnit, error) {
+	code = NormalizeUnit(code)
+	if code == "" {
+		for _, unit := range s {
+			if (purpose == UnitPurposePurchase && unit.IsPurchase) || (purpose == UnitPurposeSales && unit.IsSales) {
+				return unit, nil
+			}
+		}
+		return s.Base(), nil
+	}
+
+	for _, unit := range s {
+		if unit.Unit == code {
+			return unit, nil
+		}
+	}
+	if base := s.Base(); base.Unit == code {
+		return base, nil
+	}
+	return ProductUnit{}, errors.ErrUnknownUnit
+}
+
+// ToBase converte a quantidade na unidade de fator informado para unidades de estoque
+func ToBase(quantity, factor int) int {
+	if factor <= 0 {
+		factor = 1
+	}
+	return quantity * factor
+}
+
+// FromBase converte a quantidade em unidades de estoque para a unidade de fator informado,
+// arredondando para cima (uma caixa a mais em vez de faltar unidades)
+func FromBase(quantity, factor int) int {
+	if factor <= 1 {
+		return quantity
+	}
+	return (quantity + factor - 1) / factor
+}
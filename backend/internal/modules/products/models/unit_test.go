@@ -0,0 +1,74 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func boxUnits() UnitSet {
+	return UnitSet{
+		{Unit: "UN", Factor: 1, IsSales: true},
+		{Unit: "CX", Factor: 12, IsPurchase: true},
+	}
+}
+
+func TestValidateUnits(t *testing.T) {
+	units := []ProductUnitInput{{Unit: " un ", Factor: 1}, {Unit: "cx", Factor: 12, IsPurchase: true}}
+	require.NoError(t, ValidateUnits(units))
+	assert.Equal(t, "UN", units[0].Unit)
+	assert.Equal(t, "CX", units[1].Unit)
+
+	assert.Equal(t, errors.ErrBaseUnitRequired, ValidateUnits([]ProductUnitInput{{Unit: "CX", Factor: 12}}))
+	assert.Equal(t, errors.ErrBaseUnitRequired,
+		ValidateUnits([]ProductUnitInput{{Unit: "UN", Factor: 1}, {Unit: "PC", Factor: 1}}))
+	assert.Equal(t, errors.ErrDuplicateUnit,
+		ValidateUnits([]ProductUnitInput{{Unit: "UN", Factor: 1}, {Unit: "un", Factor: 6}}))
+	assert.Equal(t, errors.ErrMultipleDefaultUnits, ValidateUnits([]ProductUnitInput{
+		{Unit: "UN", Factor: 1, IsSales: true}, {Unit: "CX", Factor: 12, IsSales: true}}))
+}
+
+func TestUnitSetResolve(t *testing.T) {
+	units := boxUnits()
+
+	purchase, err := units.Resolve("", UnitPurposePurchase)
+	require.NoError(t, err)
+	assert.Equal(t, "CX", purchase.Unit)
+	assert.Equal(t, 12, purchase.Factor)
+
+	sales, err := units.Resolve("", UnitPurposeSales)
+	require.NoError(t, err)
+	assert.Equal(t, "UN", sales.Unit)
+
+	box, err := units.Resolve("cx", UnitPurposeSales)
+	require.NoError(t, err)
+	assert.Equal(t, 12, box.Factor)
+
+	_, err = units.Resolve("PT", UnitPurposeSales)
+	assert.Equal(t, errors.ErrUnknownUnit, err)
+}
+
+func TestUnitSetResolveWithoutUnits(t *testing.T) {
+	var units UnitSet
+
+	unit, err := units.Resolve("", UnitPurposePurchase)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultUnit, unit.Unit)
+	assert.Equal(t, 1, unit.Factor)
+
+	_, err = units.Resolve("un", UnitPurposeSales)
+	assert.NoError(t, err)
+
+	_, err = units.Resolve("CX", UnitPurposeSales)
+	assert.Equal(t, errors.ErrUnknownUnit, err)
+}
+
+func TestUnitConversion(t *testing.T) {
+	assert.Equal(t, 24, ToBase(2, 12))
+	assert.Equal(t, 5, ToBase(5, 0))
+	assert.Equal(t, 3, FromBase(25, 12))
+	assert.Equal(t, 2, FromBase(24, 12))
+	assert.Equal(t, 7, FromBase(7, 1))
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ProductVariant é uma combinação de tamanho e cor do produto, com SKU e estoque próprios
+type ProductVariant struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	ProductID int       `json:"product_id" gorm:"index"`
+	SKU       string    `json:"sku" gorm:"column:sku"`
+	Size      string    `json:"size"`
+	Color     string    `json:"color"`
+	Barcode   string    `json:"barcode,omitempty"`
+	Stock     int       `json:"stock"`
+	Active    bool      `json:"active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela de variantes
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// VariantMatrix são os tamanhos e as cores da grade do produto
+type VariantMatrix struct {
+	Sizes  []string `json:"sizes"`
+	Colors []string `json:"colors"`
+}
+
+// VariantUpdate são os campos alteráveis de uma variante
+type VariantUpdate struct {
+	SKU     *string `json:"sku"`
+	Barcode *string `json:"barcode"`
+	Stock   *int    `json:"stock" binding:"omitempty,gte=0"`
+	Active  *bool   `json:"active"`
+}
+
+// BuildVariantMatrix monta as variantes da grade (tamanho x cor); sem tamanhos ou sem cores a
+// grade tem uma dimensão só. O SKU de cada variante é o SKU base (ou P<id> se o produto não tiver
+// SKU) seguido do tamanho e da cor.
+func BuildVariantMatrix(productID int, baseSKU string, matrix VariantMatrix) ([]ProductVariant, error) {
+	sizes := uniqueValues(matrix.Sizes)
+	colors := uniqueValues(matrix.Colors)
+	if len(sizes) == 0 && len(colors) == 0 {
+		return nil, errors.ErrEmptyVariantMatrix
+	}
+	if len(sizes) == 0 {
+		sizes = []string{""}
+	}
+	if len(colors) == 0 {
+		colors = []string{""}
+	}
+
+	if baseSKU == "" {
+		baseSKU = fmt.Sprintf("P%d", productID)
+	}
+
+	variants := make([]ProductVariant, 0, len(sizes)*len(colors))
+	for _, size := range sizes {
+		for _, color := range colors {
+			variants = append(variants, ProductVariant{
+				ProductID: productID,
+				SKU:       VariantSKU(baseSKU, size, color),
+				Size:      size,
+				Color:     color,
+				Active:    true,
+			})
+		}
+	}
+	return variants, nil
+}
+
+// VariantSKU compõe o SKU da variante: "CAM-01", "M" e "Azul Marinho" resultam em "CAM-01-M-AZULMARINHO"
+func VariantSKU(baseSKU, size, color string) string {
+	parts := []string{baseSKU}
+	for _, value := range []string{size, color} {
+		if code := skuCode(value); code != "" {
+			parts = append(parts, code)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// skuCode mantém apenas letras e dígitos do valor, em maiúsculas
+func skuCode(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// uniqueValues remove valores vazios e repetidos, preservando a ordem informada
+func uniqueValues(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		key := strings.ToLower(value)
+		if value == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, value)
+	}
+	return result
+}
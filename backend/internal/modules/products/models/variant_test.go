@@ -0,0 +1,39 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"testing"
+)
+
+func TestBuildVariantMatrix(t *testing.T) {
+	variants, err := BuildVariantMatrix(4, "CAM-01", VariantMatrix{
+		Sizes:  []string{"P", "M", "m"},
+		Colors: []string{"Azul Marinho", "Preto"},
+	})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(variants) != 4 {
+		t.Fatalf("Esperadas 4 variantes (2 tamanhos x 2 cores), obtidas %d", len(variants))
+	}
+	if variants[0].SKU != "CAM-01-P-AZULMARINHO" || variants[3].SKU != "CAM-01-M-PRETO" {
+		t.Errorf("SKUs inesperados: %s, %s", variants[0].SKU, variants[3].SKU)
+	}
+	if variants[0].ProductID != 4 || !variants[0].Active {
+		t.Errorf("Variante inesperada: %+v", variants[0])
+	}
+}
+
+func TestBuildVariantMatrixSingleDimension(t *testing.T) {
+	variants, err := BuildVariantMatrix(4, "", VariantMatrix{Colors: []string{"Verde"}})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(variants) != 1 || variants[0].SKU != "P4-VERDE" || variants[0].Size != "" {
+		t.Errorf("Variante inesperada: %+v", variants)
+	}
+
+	if _, err := BuildVariantMatrix(4, "CAM-01", VariantMatrix{Sizes: []string{" "}}); err != errors.ErrEmptyVariantMatrix {
+		t.Errorf("Esperado ErrEmptyVariantMatrix, obtido %v", err)
+	}
+}
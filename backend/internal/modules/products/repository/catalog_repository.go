@@ -0,0 +1,391 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Situação dos produtos exibidos no catálogo público, além de publicados
+const catalogStatus = "ativo"
+
+// Vetor de busca do produto calculado pela função do banco, a mesma usada pela trigger
+const productSearchVector = "product_search_vector(name, detailed_name, tags)"
+
+// CatalogRepository define as operações do catálogo de produtos: a árvore de categorias, as
+// imagens e a publicação dos produtos, e a leitura do catálogo público
+type CatalogRepository interface {
+	ListCategories(ctx context.Context) ([]models.ProductCategory, error)
+	GetCategory(ctx context.Context, id int) (*models.ProductCategory, error)
+	CreateCategory(ctx context.Context, category *models.ProductCategory) error
+	UpdateCategory(ctx context.Context, category *models.ProductCategory) error
+	DeleteCategory(ctx context.Context, id int) error
+
+	SetCatalogSettings(ctx context.Context, productID int, settings models.CatalogSettings, now time.Time) (*models.Product, error)
+	ListImages(ctx context.Context, productID int) ([]models.ProductImage, error)
+	GetImage(ctx context.Context, productID, imageID int) (*models.ProductImage, error)
+	CreateImage(ctx context.Context, image *models.ProductImage) error
+	ReorderImages(ctx context.Context, productID int, imageIDs []int) error
+
+	ListPublished(ctx context.Context, filter models.CatalogFilter, params *pagination.PaginationParams) ([]models.Product, int64, error)
+	GetPublished(ctx context.Context, id int) (*models.Product, error)
+	ImagesByProduct(ctx context.Context, productIDs []int) (map[int][]models.ProductImage, error)
+	GetPublishedImage(ctx context.Context, imageID int) (*models.ProductImage, error)
+
+	RebuildSearchIndex(ctx context.Context) (int64, error)
+}
+
+type catalogRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewCatalogRepository cria uma nova instância do repositório
+func NewCatalogRepository() (CatalogRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &catalogRepository{
+		db:     gormDB,
+		logger: logger.WithModule("catalog_repository"),
+	}, nil
+}
+
+// ListCategories lista todas as categorias da empresa, para montar a árvore
+func (r *catalogRepository) ListCategories(ctx context.Context) ([]models.ProductCategory, error) {
+	var categories []models.ProductCategory
+	if err := db.Conn(ctx, r.db).Order("position ASC, name ASC, id ASC").Find(&categories).Error; err != nil {
+		r.logger.Error("erro ao listar categorias", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar categorias")
+	}
+	return categories, nil
+}
+
+// GetCategory busca uma categoria pelo ID
+func (r *catalogRepository) GetCategory(ctx context.Context, id int) (*models.ProductCategory, error) {
+	var category models.ProductCategory
+	if err := db.Conn(ctx, r.db).First(&category, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrCategoryNotFound
+		}
+		r.logger.Error("erro ao buscar categoria", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar categoria")
+	}
+	return &category, nil
+}
+
+// CreateCategory grava uma nova categoria; o slug não pode se repetir na empresa
+func (r *catalogRepository) CreateCategory(ctx context.Context, category *models.ProductCategory) error {
+	if err := r.checkSlug(ctx, category.Slug, 0); err != nil {
+		return err
+	}
+	if err := db.Conn(ctx, r.db).Omit("Children").Create(category).Error; err != nil {
+		r.logger.Error("erro ao criar categoria", zap.Error(err), zap.String("slug", category.Slug))
+		return errors.WrapError(err, "falha ao criar categoria")
+	}
+
+	r.logger.Info("categoria criada", zap.Int("id", category.ID), zap.String("slug", category.Slug))
+	return nil
+}
+
+// UpdateCategory grava os dados e a posição na árvore da categoria
+func (r *catalogRepository) UpdateCategory(ctx context.Context, category *models.ProductCategory) error {
+	if err := r.checkSlug(ctx, category.Slug, category.ID); err != nil {
+		return err
+	}
+	result := db.Conn(ctx, r.db).Model(&models.ProductCategory{}).Where("id = ?", category.ID).Updates(map[string]interface{}{
+		"parent_id":   category.ParentID,
+		"name":        category.Name,
+		"slug":        category.Slug,
+		"description": category.Description,
+		"position":    category.Position,
+	})
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar categoria", zap.Error(result.Error), zap.Int("id", category.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar categoria")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrCategoryNotFound
+	}
+	return nil
+}
+
+// checkSlug recusa o slug já usado por outra categoria da empresa
+func (r *catalogRepository) checkSlug(ctx context.Context, slug string, exceptID int) error {
+	var count int64
+	if err := db.Conn(ctx, r.db).Model(&models.ProductCategory{}).
+		Where("slug = ? AND id <> ?", slug, exceptID).
+		Count(&count).Error; err != nil {
+		r.logger.Error("erro ao verificar slug da categoria", zap.Error(err), zap.String("slug", slug))
+		return errors.WrapError(err, "falha ao verificar slug da categoria")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateCategorySlug
+	}
+	return nil
+}
+
+// DeleteCategory remove uma categoria sem subcategorias; os produtos dela ficam sem categoria
+func (r *catalogRepository) DeleteCategory(ctx context.Context, id int) error {
+	conn := db.Conn(ctx, r.db)
+
+	var children int64
+	if err := conn.Model(&models.ProductCategory{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		r.logger.Error("erro ao verificar subcategorias", zap.Error(err), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao verificar subcategorias")
+	}
+	if children > 0 {
+		return errors.ErrCategoryHasChildren
+	}
+
+	var productIDs []int
+	if err := conn.Model(&models.Product{}).Unscoped().Where("category_id = ?", id).Pluck("id", &productIDs).Error; err != nil {
+		r.logger.Error("erro ao buscar produtos da categoria", zap.Error(err), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao buscar produtos da categoria")
+	}
+
+	result := conn.Delete(&models.ProductCategory{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover categoria", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover categoria")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrCategoryNotFound
+	}
+
+	// A chave estrangeira tirou a categoria dos produtos, que podem estar no cache
+	InvalidateProducts(productIDs...)
+	return nil
+}
+
+// SetCatalogSettings grava a categoria e a publicação do produto. A data de publicação é a da
+// primeira publicação desde que o produto saiu do catálogo.
+func (r *catalogRepository) SetCatalogSettings(ctx context.Context, productID int, settings models.CatalogSettings, now time.Time) (*models.Product, error) {
+	defer productCache.Delete(productID)
+
+	conn := db.Conn(ctx, r.db)
+	var product models.Product
+	if err := conn.First(&product, productID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrProductNotFound
+		}
+		r.logger.Error("erro ao buscar produto", zap.Error(err), zap.Int("id", productID))
+		return nil, errors.WrapError(err, "falha ao buscar produto")
+	}
+
+	publishedAt := product.PublishedAt
+	switch {
+	case !settings.Published:
+		publishedAt = nil
+	case !product.Published || publishedAt == nil:
+		publishedAt = &now
+	}
+
+	fields := map[string]interface{}{
+		"category_id":  settings.CategoryID,
+		"published":    settings.Published,
+		"featured":     settings.Featured,
+		"published_at": publishedAt,
+	}
+	if err := conn.Model(&models.Product{}).Where("id = ?", productID).Updates(fields).Error; err != nil {
+		r.logger.Error("erro ao gravar publicação do produto", zap.Error(err), zap.Int("id", productID))
+		return nil, errors.WrapError(err, "falha ao gravar publicação do produto")
+	}
+
+	product.CategoryID = settings.CategoryID
+	product.Published = settings.Published
+	product.Featured = settings.Featured
+	product.PublishedAt = publishedAt
+
+	r.logger.Info("publicação do produto atualizada",
+		zap.Int("id", productID), zap.Bool("published", settings.Published), zap.Bool("featured", settings.Featured))
+	return &product, nil
+}
+
+// ListImages lista as imagens do produto na ordem de exibição, com os anexos
+func (r *catalogRepository) ListImages(ctx context.Context, productID int) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	if err := db.Conn(ctx, r.db).Preload("Attachment").
+		Where("product_id = ?", productID).
+		Order("position ASC, id ASC").
+		Find(&images).Error; err != nil {
+		r.logger.Error("erro ao listar imagens do produto", zap.Error(err), zap.Int("product_id", productID))
+		return nil, errors.WrapError(err, "falha ao listar imagens do produto")
+	}
+	return images, nil
+}
+
+// GetImage busca uma imagem do produto
+func (r *catalogRepository) GetImage(ctx context.Context, productID, imageID int) (*models.ProductImage, error) {
+	var image models.ProductImage
+	if err := db.Conn(ctx, r.db).Preload("Attachment").
+		Where("product_id = ?", productID).
+		First(&image, imageID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrProductImageNotFound
+		}
+		r.logger.Error("erro ao buscar imagem do produto", zap.Error(err), zap.Int("id", imageID))
+		return nil, errors.WrapError(err, "falha ao buscar imagem do produto")
+	}
+	return &image, nil
+}
+
+// CreateImage grava a imagem depois das demais imagens do produto
+func (r *catalogRepository) CreateImage(ctx context.Context, image *models.ProductImage) error {
+	conn := db.Conn(ctx, r.db)
+
+	var next int
+	if err := conn.Model(&models.ProductImage{}).
+		Where("product_id = ?", image.ProductID).
+		Select("COALESCE(MAX(position) + 1, 0)").
+		Scan(&next).Error; err != nil {
+		r.logger.Error("erro ao calcular posição da imagem", zap.Error(err), zap.Int("product_id", image.ProductID))
+		return errors.WrapError(err, "falha ao calcular posição da imagem")
+	}
+	image.Position = next
+
+	if err := conn.Omit("Attachment").Create(image).Error; err != nil {
+		r.logger.Error("erro ao gravar imagem do produto", zap.Error(err), zap.Int("product_id", image.ProductID))
+		return errors.WrapError(err, "falha ao gravar imagem do produto")
+	}
+	return nil
+}
+
+// ReorderImages grava a posição de cada imagem conforme a ordem informada
+func (r *catalogRepository) ReorderImages(ctx context.Context, productID int, imageIDs []int) error {
+	tx := db.Conn(ctx, r.db).Begin()
+	for position, id := range imageIDs {
+		if err := tx.Model(&models.ProductImage{}).
+			Where("id = ? AND product_id = ?", id, productID).
+			Update("position", position).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao reordenar imagens", zap.Error(err), zap.Int("product_id", productID))
+			return errors.WrapError(err, "falha ao reordenar imagens")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return errors.WrapError(err, "falha ao confirmar transação")
+	}
+	return nil
+}
+
+// publishedProducts restringe a consulta aos produtos do catálogo público
+func (r *catalogRepository) publishedProducts(ctx context.Context) *gorm.DB {
+	return db.Conn(ctx, r.db).Model(&models.Product{}).
+		Where("published AND status = ?", catalogStatus)
+}
+
+// ListPublished lista uma página dos produtos publicados, com os destaques primeiro
+func (r *catalogRepository) ListPublished(ctx context.Context, filter models.CatalogFilter, params *pagination.PaginationParams) ([]models.Product, int64, error) {
+	query := r.publishedProducts(ctx)
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("category_id IN ?", filter.CategoryIDs)
+	}
+	if filter.Featured {
+		query = query.Where("featured")
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		// As palavras são procuradas no índice de busca; o SKU continua aceitando o início do código
+		query = query.Where("(search_vector @@ websearch_to_tsquery('portuguese', ?) OR sku ILIKE ? OR ? = ANY(tags))",
+			search, search+"%", search)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar produtos do catálogo", zap.Error(err))
+		return nil, 0, errors.WrapError(err, "falha ao contar produtos do catálogo")
+	}
+
+	var products []models.Product
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("featured DESC, name ASC, id ASC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&products).Error; err != nil {
+		r.logger.Error("erro ao listar produtos do catálogo", zap.Error(err))
+		return nil, 0, errors.WrapError(err, "falha ao listar produtos do catálogo")
+	}
+	return products, total, nil
+}
+
+// GetPublished busca um produto publicado; os demais não existem para o catálogo público
+func (r *catalogRepository) GetPublished(ctx context.Context, id int) (*models.Product, error) {
+	var product models.Product
+	if err := r.publishedProducts(ctx).Where("id = ?", id).First(&product).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrProductNotFound
+		}
+		r.logger.Error("erro ao buscar produto do catálogo", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar produto do catálogo")
+	}
+	return &product, nil
+}
+
+// ImagesByProduct retorna as imagens dos produtos em ordem de exibição, agrupadas pelo produto
+func (r *catalogRepository) ImagesByProduct(ctx context.Context, productIDs []int) (map[int][]models.ProductImage, error) {
+	byProduct := make(map[int][]models.ProductImage, len(productIDs))
+	if len(productIDs) == 0 {
+		return byProduct, nil
+	}
+
+	var images []models.ProductImage
+	if err := db.Conn(ctx, r.db).Preload("Attachment").
+		Where("product_id IN ?", productIDs).
+		Order("product_id ASC, position ASC, id ASC").
+		Find(&images).Error; err != nil {
+		r.logger.Error("erro ao buscar imagens dos produtos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar imagens dos produtos")
+	}
+	for _, image := range images {
+		byProduct[image.ProductID] = append(byProduct[image.ProductID], image)
+	}
+	return byProduct, nil
+}
+
+// GetPublishedImage busca a imagem de um produto publicado, com o anexo
+func (r *catalogRepository) GetPublishedImage(ctx context.Context, imageID int) (*models.ProductImage, error) {
+	var image models.ProductImage
+	if err := db.Conn(ctx, r.db).Preload("Attachment").
+		Where("product_id IN (?)", r.publishedProducts(ctx).Select("id")).
+		First(&image, imageID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrProductImageNotFound
+		}
+		r.logger.Error("erro ao buscar imagem do catálogo", zap.Error(err), zap.Int("id", imageID))
+		return nil, errors.WrapError(err, "falha ao buscar imagem do catálogo")
+	}
+	return &image, nil
+}
+
+// RebuildSearchIndex recalcula o vetor de busca dos produtos desatualizados e reconstrói o índice,
+// retornando quantos produtos foram atualizados. O REINDEX bloqueia as gravações em products
+// enquanto roda.
+func (r *catalogRepository) RebuildSearchIndex(ctx context.Context) (int64, error) {
+	result := db.Conn(ctx, r.db).Model(&models.Product{}).
+		Where("search_vector IS DISTINCT FROM "+productSearchVector).
+		UpdateColumn("search_vector", gorm.Expr(productSearchVector))
+	if result.Error != nil {
+		r.logger.Error("erro ao recalcular o índice de busca", zap.Error(result.Error))
+		return 0, errors.WrapError(result.Error, "falha ao recalcular o índice de busca")
+	}
+
+	if err := db.Conn(ctx, r.db).Exec("REINDEX INDEX idx_products_search_vector").Error; err != nil {
+		r.logger.Error("erro ao reconstruir o índice de busca", zap.Error(err))
+		return 0, errors.WrapError(err, "falha ao reconstruir o índice de busca")
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// KitRepository define as operações de composição dos kits e a margem de venda por kit
+type KitRepository interface {
+	GetKit(ctx context.Context, productID int) (*models.ProductKit, error)
+	SetKit(ctx context.Context, productID int, input models.KitInput) (*models.ProductKit, error)
+	DeleteKit(ctx context.Context, productID int) error
+	ListKitMargins(ctx context.Context, from, to time.Time) ([]models.KitMargin, error)
+}
+
+type kitRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewKitRepository cria uma nova instância do repositório
+func NewKitRepository() (KitRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &kitRepository{
+		db:     gormDB,
+		logger: logger.WithModule("kit_repository"),
+	}, nil
+}
+
+// GetKit retorna a composição do kit com o estoque dos componentes e a quantidade montável
+func (r *kitRepository) GetKit(ctx context.Context, productID int) (*models.ProductKit, error) {
+	kits, err := LoadKits(db.Conn(ctx, r.db), []int{productID})
+	if err != nil {
+		r.logger.Error("erro ao buscar kit", zap.Error(err), zap.Int("product_id", productID))
+		return nil, err
+	}
+	kit, ok := kits[productID]
+	if !ok {
+		return nil, errors.ErrKitNotFound
+	}
+	kit.Available = kit.BuildableQuantity()
+	return &kit, nil
+}
+
+// SetKit define (ou substitui) a composição e o modo do kit do produto
+func (r *kitRepository) SetKit(ctx context.Context, productID int, input models.KitInput) (*models.ProductKit, error) {
+	if err := models.ValidateKitComponents(productID, input.Components); err != nil {
+		return nil, err
+	}
+
+	tx := db.Conn(ctx, r.db).Begin()
+
+	ids := make([]int, 0, len(input.Components)+1)
+	ids = append(ids, productID)
+	for _, component := range input.Components {
+		ids = append(ids, component.ProductID)
+	}
+	var found int64
+	if err := tx.Table("products").
+		Scopes(tenant.Scope(ctx, "products")).
+		Where("id IN ? AND deleted_at IS NULL", ids).
+		Count(&found).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar produtos do kit")
+	}
+	if int(found) != len(ids) {
+		tx.Rollback()
+		return nil, errors.ErrProductNotFound
+	}
+
+	// Kits não são aninhados: nenhum componente pode ser kit, e o kit não pode ser componente de outro
+	var nested int64
+	if err := tx.Model(&models.ProductKit{}).
+		Where("product_id IN ?", ids[1:]).
+		Count(&nested).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao verificar componentes do kit")
+	}
+	if nested == 0 {
+		if err := tx.Model(&models.KitComponent{}).
+			Where("component_product_id = ?", productID).
+			Count(&nested).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao verificar uso do produto em kits")
+		}
+	}
+	if nested > 0 {
+		tx.Rollback()
+		return nil, errors.ErrNestedKit
+	}
+
+	var kit models.ProductKit
+	err := tx.Where("product_id = ?", productID).First(&kit).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		kit = models.ProductKit{ProductID: productID, Mode: input.Mode}
+		if err := tx.Omit("Components").Create(&kit).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao criar kit", zap.Error(err), zap.Int("product_id", productID))
+			return nil, errors.WrapError(err, "falha ao criar kit")
+		}
+	case err != nil:
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar kit")
+	default:
+		if err := tx.Model(&kit).Update("mode", input.Mode).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao atualizar kit")
+		}
+		if err := tx.Where("kit_id = ?", kit.ID).Delete(&models.KitComponent{}).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao substituir componentes do kit")
+		}
+	}
+
+	components := make([]models.KitComponent, 0, len(input.Components))
+	for _, component := range input.Components {
+		components = append(components, models.KitComponent{
+			KitID:              kit.ID,
+			ComponentProductID: component.ProductID,
+			Quantity:           component.Quantity,
+		})
+	}
+	if err := tx.Create(&components).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao gravar componentes do kit", zap.Error(err), zap.Int("kit_id", kit.ID))
+		return nil, errors.WrapError(err, "falha ao gravar componentes do kit")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("kit definido", zap.Int("product_id", productID),
+		zap.String("mode", input.Mode), zap.Int("components", len(components)))
+	return r.GetKit(ctx, productID)
+}
+
+// DeleteKit desfaz o kit; o produto volta a ser vendido como item simples
+func (r *kitRepository) DeleteKit(ctx context.Context, productID int) error {
+	result := db.Conn(ctx, r.db).Where("product_id = ?", productID).Delete(&models.ProductKit{})
+	if result.Error != nil {
+		r.logger.Error("erro ao excluir kit", zap.Error(result.Error), zap.Int("product_id", productID))
+		return errors.WrapError(result.Error, "falha ao excluir kit")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrKitNotFound
+	}
+	return nil
+}
+
+// ListKitMargins apura, por kit, a receita das faturas emitidas no período (a linha do kit mantido
+// ou as linhas desdobradas dos componentes) e o CMV apurado para o kit no mesmo período
+func (r *kitRepository) ListKitMargins(ctx context.Context, from, to time.Time) ([]models.KitMargin, error) {
+	var margins []models.KitMargin
+	if err := db.Conn(ctx, r.db).Table("product_kits AS k").
+		Scopes(tenant.Scope(ctx, "k")).
+		Select(`k.product_id, p.name AS product_name, p.sku, k.mode,
+			COALESCE(revenue.revenue, 0) AS revenue, COALESCE(cost.cost, 0) AS cost`).
+		Joins("JOIN products p ON p.id = k.product_id").
+		Joins(`LEFT JOIN (
+			SELECT COALESCE(ii.kit_product_id, ii.product_id) AS product_id,
+			       SUM(ii.quantity * ii.unit_price - ii.discount) AS revenue
+			FROM invoice_items ii
+			JOIN invoices i ON i.id = ii.invoice_id
+			WHERE i.deleted_at IS NULL AND i.status NOT IN ('draft', 'cancelled')
+			  AND i.issue_date >= ? AND i.issue_date < ?
+			GROUP BY COALESCE(ii.kit_product_id, ii.product_id)
+		) revenue ON revenue.product_id = k.product_id`, from, to).
+		Joins(`LEFT JOIN (
+			SELECT kit_product_id AS product_id, SUM(total_cost) AS cost
+			FROM cogs_entries
+			WHERE kit_product_id IS NOT NULL AND created_at >= ? AND created_at < ?
+			GROUP BY kit_product_id
+		) cost ON cost.product_id = k.product_id`, from, to).
+		Where("revenue.revenue IS NOT NULL OR cost.cost IS NOT NULL").
+		Order("revenue DESC, k.product_id ASC").
+		Scan(&margins).Error; err != nil {
+		r.logger.Error("erro ao apurar margem dos kits", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao apurar margem dos kits")
+	}
+
+	for i := range margins {
+		margins[i].ApplyMargin()
+	}
+	return margins, nil
+}
+
+// LoadKits carrega os kits dos produtos informados, com os componentes e os dados de cada
+// produto componente (nome, SKU, unidade de estoque, preço de venda, custo e estoque). Produtos que não são kit
+// ficam fora do mapa.
+func LoadKits(tx *gorm.DB, productIDs []int) (map[int]models.ProductKit, error) {
+	kits := make(map[int]models.ProductKit)
+	if len(productIDs) == 0 {
+		return kits, nil
+	}
+
+	var rows []models.ProductKit
+	if err := tx.Preload("Components", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("product_id IN ?", productIDs).Find(&rows).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar kits")
+	}
+	if len(rows) == 0 {
+		return kits, nil
+	}
+
+	componentIDs := make([]int, 0)
+	for _, kit := range rows {
+		for _, component := range kit.Components {
+			componentIDs = append(componentIDs, component.ComponentProductID)
+		}
+	}
+
+	var products []struct {
+		ID         int
+		Name       string
+		SKU        string
+		Price      money.Decimal
+		SalesPrice money.Decimal
+		CostPrice  money.Decimal
+		Stock      int
+	}
+	if err := tx.Table("products").
+		Select("id, name, sku, price, sales_price, cost_price, stock").
+		Where("id IN ?", componentIDs).
+		Scan(&products).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar componentes dos kits")
+	}
+	units, err := LoadUnits(tx, componentIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]int, len(products))
+	for i, product := range products {
+		byID[product.ID] = i
+	}
+
+	for _, kit := range rows {
+		for i := range kit.Components {
+			component := &kit.Components[i]
+			index, ok := byID[component.ComponentProductID]
+			if !ok {
+				continue
+			}
+			product := products[index]
+			component.ProductName = product.Name
+			component.ProductCode = product.SKU
+			component.Unit = units[product.ID].Base().Unit
+			component.UnitPrice = product.SalesPrice
+			if component.UnitPrice.IsZero() {
+				component.UnitPrice = product.Price
+			}
+			component.UnitCost = product.CostPrice
+			component.Stock = product.Stock
+		}
+		kits[kit.ProductID] = kit
+	}
+	return kits, nil
+}
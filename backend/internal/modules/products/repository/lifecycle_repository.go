@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Colunas de products lidas nas regras do ciclo de vida
+const lifecycleColumns = "id, name, sku, status, price, sales_price, category_id, product_category, replacement_product_id"
+
+// LifecycleRepository define as operações do ciclo de vida dos produtos: a mudança de estado com
+// o substituto e a sugestão de substituto para um produto bloqueado
+type LifecycleRepository interface {
+	SetLifecycle(ctx context.Context, productID int, input models.LifecycleInput) (*models.Product, error)
+	GetReplacement(ctx context.Context, productID int) (*models.ReplacementSuggestion, error)
+}
+
+type lifecycleRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewLifecycleRepository cria uma nova instância do repositório
+func NewLifecycleRepository() (LifecycleRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &lifecycleRepository{
+		db:     gormDB,
+		logger: logger.WithModule("lifecycle_repository"),
+	}, nil
+}
+
+// SetLifecycle grava o estado e o substituto do produto. O substituto precisa existir, ser outro
+// produto e não fechar um ciclo de substitutos; o fim de vida não volta atrás.
+func (r *lifecycleRepository) SetLifecycle(ctx context.Context, productID int, input models.LifecycleInput) (*models.Product, error) {
+	defer productCache.Delete(productID)
+
+	var product models.Product
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&product, productID).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrProductNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar produto")
+		}
+		if err := models.CheckTransition(product.Status, input.Status); err != nil {
+			return err
+		}
+
+		if input.ReplacementProductID != nil {
+			replacementID := *input.ReplacementProductID
+			if replacementID == productID {
+				return errors.ErrInvalidReplacementProduct
+			}
+			replacement, err := loadLifecycleProduct(tx, replacementID)
+			if err != nil {
+				return err
+			}
+			if replacement == nil {
+				return errors.ErrInvalidReplacementProduct
+			}
+			cycle := models.CreatesReplacementCycle(productID, replacementID, func(id int) *models.LifecycleProduct {
+				next, _ := loadLifecycleProduct(tx, id)
+				return next
+			})
+			if cycle {
+				return errors.ErrInvalidReplacementProduct
+			}
+		}
+
+		fields := map[string]interface{}{
+			"status":                 input.Status,
+			"replacement_product_id": input.ReplacementProductID,
+		}
+		if err := tx.Model(&models.Product{}).Where("id = ?", productID).Updates(fields).Error; err != nil {
+			return errors.WrapError(err, "falha ao gravar ciclo de vida do produto")
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("erro ao gravar ciclo de vida do produto", zap.Error(err), zap.Int("id", productID))
+		return nil, err
+	}
+
+	product.Status = input.Status
+	product.ReplacementProductID = input.ReplacementProductID
+
+	r.logger.Info("ciclo de vida do produto atualizado",
+		zap.Int("id", productID), zap.String("status", input.Status))
+	return &product, nil
+}
+
+// GetReplacement sugere o substituto do produto; nil quando não há produto vendável para sugerir
+func (r *lifecycleRepository) GetReplacement(ctx context.Context, productID int) (*models.ReplacementSuggestion, error) {
+	conn := db.Conn(ctx, r.db)
+	product, err := loadLifecycleProduct(conn, productID)
+	if err != nil {
+		r.logger.Error("erro ao buscar produto", zap.Error(err), zap.Int("id", productID))
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.ErrProductNotFound
+	}
+	return SuggestReplacement(conn, *product)
+}
+
+// EnsureQuotable recusa produtos descontinuados ou em fim de vida nos itens de uma nova cotação.
+// O erro traz o primeiro produto bloqueado e o substituto sugerido para ele.
+func EnsureQuotable(tx *gorm.DB, productIDs []int) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	var blocked []models.LifecycleProduct
+	if err := tx.Model(&models.Product{}).Select(lifecycleColumns).
+		Where("id IN ? AND status IN ?", productIDs, []string{models.StatusDiscontinued, models.StatusEndOfLife}).
+		Order("id ASC").Limit(1).
+		Scan(&blocked).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar situação dos produtos")
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+
+	suggestion, err := SuggestReplacement(tx, blocked[0])
+	if err != nil {
+		return err
+	}
+	return models.BlockedProductError(blocked[0], suggestion)
+}
+
+// SuggestReplacement sugere o substituto de um produto: o substituto cadastrado, seguindo a
+// cadeia até um produto vendável, ou o produto ativo da mesma categoria com o preço de venda mais
+// próximo
+func SuggestReplacement(tx *gorm.DB, product models.LifecycleProduct) (*models.ReplacementSuggestion, error) {
+	var lookupErr error
+	linked := models.FollowReplacements(product, func(id int) *models.LifecycleProduct {
+		next, err := loadLifecycleProduct(tx, id)
+		if err != nil {
+			lookupErr = err
+		}
+		return next
+	})
+	if lookupErr != nil {
+		return nil, lookupErr
+	}
+	if linked != nil {
+		return &models.ReplacementSuggestion{
+			ProductID: linked.ID, SKU: linked.SKU, Name: linked.Name, Source: models.ReplacementFromLink,
+		}, nil
+	}
+
+	query := tx.Model(&models.Product{}).Select(lifecycleColumns).
+		Where("status = ? AND id <> ?", models.StatusActive, product.ID)
+	switch {
+	case product.CategoryID != nil:
+		query = query.Where("category_id = ?", *product.CategoryID)
+	case product.ProductCategory != "":
+		query = query.Where("product_category = ?", product.ProductCategory)
+	default:
+		return nil, nil
+	}
+
+	var candidates []models.LifecycleProduct
+	if err := query.
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ABS(COALESCE(NULLIF(sales_price, 0), price) - ?) ASC, id ASC",
+			Vars: []interface{}{product.SellingPrice()},
+		}}).
+		Limit(1).Scan(&candidates).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar substituto do produto")
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	return &models.ReplacementSuggestion{
+		ProductID: candidates[0].ID, SKU: candidates[0].SKU, Name: candidates[0].Name, Source: models.ReplacementFromCategory,
+	}, nil
+}
+
+// loadLifecycleProduct busca os dados de ciclo de vida de um produto; nil se ele não existir
+func loadLifecycleProduct(tx *gorm.DB, id int) (*models.LifecycleProduct, error) {
+	var rows []models.LifecycleProduct
+	if err := tx.Model(&models.Product{}).Select(lifecycleColumns).
+		Where("id = ?", id).Limit(1).Scan(&rows).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar produto")
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"sort"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MarginThresholdRepository define as operações das margens mínimas por categoria
+type MarginThresholdRepository interface {
+	ListMinMargins(ctx context.Context) ([]models.MinMargin, error)
+	SetThreshold(ctx context.Context, categoryID int, percent float64, updatedBy string) (*models.CategoryMarginThreshold, error)
+	DeleteThreshold(ctx context.Context, categoryID int) error
+}
+
+type marginThresholdRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewMarginThresholdRepository cria uma nova instância do repositório
+func NewMarginThresholdRepository() (MarginThresholdRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &marginThresholdRepository{
+		db:     gormDB,
+		logger: logger.WithModule("margin_threshold_repository"),
+	}, nil
+}
+
+// ListMinMargins lista a margem mínima de cada categoria que tem uma, própria ou herdada
+func (r *marginThresholdRepository) ListMinMargins(ctx context.Context) ([]models.MinMargin, error) {
+	margins, err := LoadMinMargins(db.Conn(ctx, r.db))
+	if err != nil {
+		r.logger.Error("erro ao listar margens mínimas", zap.Error(err))
+		return nil, err
+	}
+	list := make([]models.MinMargin, 0, len(margins))
+	for _, margin := range margins {
+		list = append(list, margin)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CategoryName != list[j].CategoryName {
+			return list[i].CategoryName < list[j].CategoryName
+		}
+		return list[i].CategoryID < list[j].CategoryID
+	})
+	return list, nil
+}
+
+// SetThreshold grava a margem mínima própria da categoria
+func (r *marginThresholdRepository) SetThreshold(ctx context.Context, categoryID int, percent float64, updatedBy string) (*models.CategoryMarginThreshold, error) {
+	conn := db.Conn(ctx, r.db)
+	var category models.ProductCategory
+	if err := conn.Select("id").First(&category, categoryID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrCategoryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar categoria")
+	}
+
+	threshold := models.CategoryMarginThreshold{
+		CategoryID:       categoryID,
+		MinMarginPercent: percent,
+		UpdatedBy:        updatedBy,
+	}
+	if err := conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min_margin_percent", "updated_by", "updated_at"}),
+	}).Create(&threshold).Error; err != nil {
+		r.logger.Error("erro ao gravar margem mínima", zap.Error(err), zap.Int("category_id", categoryID))
+		return nil, errors.WrapError(err, "falha ao gravar margem mínima")
+	}
+
+	r.logger.Info("margem mínima da categoria definida", zap.Int("category_id", categoryID),
+		zap.Float64("min_margin_percent", percent), zap.String("updated_by", updatedBy))
+	return &threshold, nil
+}
+
+// DeleteThreshold remove a margem mínima própria da categoria, que passa a seguir a da mãe
+func (r *marginThresholdRepository) DeleteThreshold(ctx context.Context, categoryID int) error {
+	result := db.Conn(ctx, r.db).Where("category_id = ?", categoryID).Delete(&models.CategoryMarginThreshold{})
+	if result.Error != nil {
+		r.logger.Error("erro ao remover margem mínima", zap.Error(result.Error), zap.Int("category_id", categoryID))
+		return errors.WrapError(result.Error, "falha ao remover margem mínima")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrMarginThresholdNotFound
+	}
+	r.logger.Info("margem mínima da categoria removida", zap.Int("category_id", categoryID))
+	return nil
+}
+
+// LoadMinMargins carrega a margem mínima de cada categoria, própria ou herdada da mãe, para a
+// conferência das faturas
+func LoadMinMargins(tx *gorm.DB) (map[int]models.MinMargin, error) {
+	var thresholds []models.CategoryMarginThreshold
+	if err := tx.Find(&thresholds).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar margens mínimas")
+	}
+	if len(thresholds) == 0 {
+		return map[int]models.MinMargin{}, nil
+	}
+	var categories []models.ProductCategory
+	if err := tx.Select("id, parent_id, name").Find(&categories).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar categorias")
+	}
+	return models.ResolveMinMargins(categories, thresholds), nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"slices"
+
+	"ERP-ONSMART/backend/internal/cache"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+)
+
+// productCache guarda os produtos lidos por ID. UpdateProductByID e DeleteProductByID invalidam
+// a entrada; módulos que alteram a tabela products diretamente (ex.: devoluções, que repõem o
+// estoque) chamam InvalidateProducts após o commit.
+var productCache = cache.NewReference[int, models.Product]("products")
+
+// GetProductByID busca o produto pelo ID, primeiro no cache de dados de referência
+func GetProductByID(id int) (*models.Product, error) {
+	if product, ok := productCache.Get(id); ok {
+		product = cloneProduct(product)
+		return &product, nil
+	}
+
+	product, err := getProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	productCache.Set(id, cloneProduct(*product))
+	return product, nil
+}
+
+// InvalidateProducts descarta os produtos do cache após alterações feitas fora deste pacote
+func InvalidateProducts(ids ...int) {
+	productCache.Delete(ids...)
+}
+
+// cloneProduct copia as listas do produto, para que alterações feitas por quem leu não
+// cheguem à cópia em cache
+func cloneProduct(product models.Product) models.Product {
+	product.Tags = slices.Clone(product.Tags)
+	product.Images = slices.Clone(product.Images)
+	product.Documents = slices.Clone(product.Documents)
+	return product
+}
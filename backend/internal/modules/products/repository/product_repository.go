@@ -2,6 +2,7 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
 	"ERP-ONSMART/backend/internal/modules/products/models"
 	"fmt"
 
@@ -22,19 +23,28 @@ func CreateProduct(p *models.Product) error {
 }
 
 func GetAllProducts() ([]models.Product, error) {
+	return FindProducts(nil)
+}
+
+// FindProducts retorna os produtos que têm todos os campos personalizados informados
+func FindProducts(customFields customfields.Values) ([]models.Product, error) {
 	conn, err := db.OpenGormDB()
 	if err != nil {
 		return nil, err
 	}
 
+	query := conn.Model(&models.Product{})
+	if len(customFields) > 0 {
+		query = query.Where("custom_fields @> ?", customFields)
+	}
 	var products []models.Product
-	if err := conn.Find(&products).Error; err != nil {
+	if err := query.Find(&products).Error; err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-func GetProductByID(id int) (*models.Product, error) {
+func getProductByID(id int) (*models.Product, error) {
 	conn, err := db.OpenGormDB()
 	if err != nil {
 		return nil, err
@@ -52,6 +62,8 @@ func GetProductByID(id int) (*models.Product, error) {
 }
 
 func UpdateProductByID(id int, updated models.Product) error {
+	defer productCache.Delete(id)
+
 	conn, err := db.OpenGormDB()
 	if err != nil {
 		return err
@@ -71,13 +83,15 @@ func UpdateProductByID(id int, updated models.Product) error {
 }
 
 func DeleteProductByID(id int) error {
+	defer productCache.Delete(id)
+
 	conn, err := db.OpenGormDB()
 	if err != nil {
 		return err
 	}
 
-	// Hard delete direto por chave primária
-	result := conn.Unscoped().Delete(&models.Product{}, id)
+	// Soft delete: o produto vai para a lixeira e pode ser restaurado
+	result := conn.Delete(&models.Product{}, id)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// UnitRepository define as operações de unidades de medida dos produtos
+type UnitRepository interface {
+	GetUnits(ctx context.Context, productID int) (models.UnitSet, error)
+	SetUnits(ctx context.Context, productID int, input models.UnitsInput) (models.UnitSet, error)
+}
+
+type unitRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewUnitRepository cria uma nova instância do repositório
+func NewUnitRepository() (UnitRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &unitRepository{
+		db:     gormDB,
+		logger: logger.WithModule("unit_repository"),
+	}, nil
+}
+
+// GetUnits retorna as unidades do produto; sem cadastro, retorna só a unidade de estoque padrão
+func (r *unitRepository) GetUnits(ctx context.Context, productID int) (models.UnitSet, error) {
+	if err := r.ensureProduct(ctx, db.Conn(ctx, r.db), productID); err != nil {
+		return nil, err
+	}
+
+	units, err := LoadUnits(db.Conn(ctx, r.db), []int{productID})
+	if err != nil {
+		r.logger.Error("erro ao buscar unidades do produto", zap.Error(err), zap.Int("product_id", productID))
+		return nil, err
+	}
+	if set, ok := units[productID]; ok {
+		return set, nil
+	}
+	base := models.UnitSet{}.Base()
+	base.ProductID = productID
+	return models.UnitSet{base}, nil
+}
+
+// SetUnits substitui as unidades de medida do produto. As linhas já gravadas nos documentos
+// guardam o fator da época e não são alteradas.
+func (r *unitRepository) SetUnits(ctx context.Context, productID int, input models.UnitsInput) (models.UnitSet, error) {
+	if err := models.ValidateUnits(input.Units); err != nil {
+		return nil, err
+	}
+
+	tx := db.Conn(ctx, r.db).Begin()
+
+	if err := r.ensureProduct(ctx, tx, productID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Where("product_id = ?", productID).Delete(&models.ProductUnit{}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao remover unidades do produto", zap.Error(err), zap.Int("product_id", productID))
+		return nil, errors.WrapError(err, "falha ao substituir unidades do produto")
+	}
+
+	units := make(models.UnitSet, 0, len(input.Units))
+	for _, unit := range input.Units {
+		units = append(units, models.ProductUnit{
+			ProductID:  productID,
+			Unit:       unit.Unit,
+			Name:       unit.Name,
+			Factor:     unit.Factor,
+			IsPurchase: unit.IsPurchase,
+			IsSales:    unit.IsSales,
+		})
+	}
+	if err := tx.Create(&units).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao gravar unidades do produto", zap.Error(err), zap.Int("product_id", productID))
+		return nil, errors.WrapError(err, "falha ao gravar unidades do produto")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("unidades do produto definidas", zap.Int("product_id", productID), zap.Int("units", len(units)))
+	return units, nil
+}
+
+// ensureProduct confere se o produto existe na empresa da requisição
+func (r *unitRepository) ensureProduct(ctx context.Context, tx *gorm.DB, productID int) error {
+	var found int64
+	if err := tx.Table("products").
+		Scopes(tenant.Scope(ctx, "products")).
+		Where("id = ? AND deleted_at IS NULL", productID).
+		Count(&found).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar produto")
+	}
+	if found == 0 {
+		return errors.ErrProductNotFound
+	}
+	return nil
+}
+
+// LoadUnits carrega as unidades de medida dos produtos informados. Produtos sem unidades
+// cadastradas ficam fora do mapa (usam a unidade de estoque padrão).
+func LoadUnits(tx *gorm.DB, productIDs []int) (map[int]models.UnitSet, error) {
+	units := make(map[int]models.UnitSet)
+	if len(productIDs) == 0 {
+		return units, nil
+	}
+
+	var rows []models.ProductUnit
+	if err := tx.Where("product_id IN ?", productIDs).
+		Order("product_id ASC, factor ASC, id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar unidades dos produtos")
+	}
+	for _, row := range rows {
+		units[row.ProductID] = append(units[row.ProductID], row)
+	}
+	return units, nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// VariantRepository define as operações da grade de variantes dos produtos
+type VariantRepository interface {
+	ListVariants(ctx context.Context, productID int) ([]models.ProductVariant, error)
+	GenerateMatrix(ctx context.Context, productID int, matrix models.VariantMatrix) ([]models.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productID, variantID int, update models.VariantUpdate) (*models.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID, variantID int) error
+}
+
+type variantRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewVariantRepository cria uma nova instância do repositório
+func NewVariantRepository() (VariantRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &variantRepository{
+		db:     gormDB,
+		logger: logger.WithModule("variant_repository"),
+	}, nil
+}
+
+// ListVariants lista as variantes do produto por tamanho e cor
+func (r *variantRepository) ListVariants(ctx context.Context, productID int) ([]models.ProductVariant, error) {
+	if _, err := r.productSKU(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	var variants []models.ProductVariant
+	if err := db.Conn(ctx, r.db).
+		Where("product_id = ?", productID).
+		Order("size ASC, color ASC, id ASC").
+		Find(&variants).Error; err != nil {
+		r.logger.Error("erro ao listar variantes", zap.Error(err), zap.Int("product_id", productID))
+		return nil, errors.WrapError(err, "falha ao listar variantes")
+	}
+	return variants, nil
+}
+
+// GenerateMatrix cria as combinações da grade que o produto ainda não tem e devolve as criadas;
+// as variantes existentes não são alteradas
+func (r *variantRepository) GenerateMatrix(ctx context.Context, productID int, matrix models.VariantMatrix) ([]models.ProductVariant, error) {
+	baseSKU, err := r.productSKU(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := models.BuildVariantMatrix(productID, baseSKU, matrix)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var existing []models.ProductVariant
+	if err := tx.Select("size", "color").Where("product_id = ?", productID).Find(&existing).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar variantes do produto")
+	}
+	combinations := make(map[string]bool, len(existing))
+	for _, variant := range existing {
+		combinations[combinationKey(variant.Size, variant.Color)] = true
+	}
+
+	created := make([]models.ProductVariant, 0, len(variants))
+	skus := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		if combinations[combinationKey(variant.Size, variant.Color)] {
+			continue
+		}
+		created = append(created, variant)
+		skus = append(skus, variant.SKU)
+	}
+	if len(created) == 0 {
+		tx.Rollback()
+		return created, nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.ProductVariant{}).Where("sku IN ?", skus).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao verificar SKU das variantes")
+	}
+	if count > 0 {
+		tx.Rollback()
+		return nil, errors.ErrDuplicateVariantSKU
+	}
+
+	if err := tx.Create(&created).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar variantes", zap.Error(err), zap.Int("product_id", productID))
+		return nil, errors.WrapError(err, "falha ao criar variantes")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("grade de variantes gerada", zap.Int("product_id", productID), zap.Int("created", len(created)))
+	return created, nil
+}
+
+// UpdateVariant altera o SKU, o código de barras, o estoque ou a situação da variante
+func (r *variantRepository) UpdateVariant(ctx context.Context, productID, variantID int, update models.VariantUpdate) (*models.ProductVariant, error) {
+	variant, err := r.getVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if update.SKU != nil && *update.SKU != variant.SKU {
+		var count int64
+		if err := db.Conn(ctx, r.db).Model(&models.ProductVariant{}).
+			Where("sku = ? AND id <> ?", *update.SKU, variantID).
+			Count(&count).Error; err != nil {
+			return nil, errors.WrapError(err, "falha ao verificar SKU da variante")
+		}
+		if count > 0 {
+			return nil, errors.ErrDuplicateVariantSKU
+		}
+		updates["sku"] = *update.SKU
+	}
+	if update.Barcode != nil {
+		updates["barcode"] = *update.Barcode
+	}
+	if update.Stock != nil {
+		updates["stock"] = *update.Stock
+	}
+	if update.Active != nil {
+		updates["active"] = *update.Active
+	}
+	if len(updates) == 0 {
+		return variant, nil
+	}
+
+	if err := db.Conn(ctx, r.db).Model(&models.ProductVariant{}).
+		Where("id = ?", variantID).
+		Updates(updates).Error; err != nil {
+		r.logger.Error("erro ao atualizar variante", zap.Error(err), zap.Int("id", variantID))
+		return nil, errors.WrapError(err, "falha ao atualizar variante")
+	}
+	return r.getVariant(ctx, productID, variantID)
+}
+
+// DeleteVariant remove a variante do produto
+func (r *variantRepository) DeleteVariant(ctx context.Context, productID, variantID int) error {
+	result := db.Conn(ctx, r.db).
+		Where("id = ? AND product_id = ?", variantID, productID).
+		Delete(&models.ProductVariant{})
+	if result.Error != nil {
+		r.logger.Error("erro ao excluir variante", zap.Error(result.Error), zap.Int("id", variantID))
+		return errors.WrapError(result.Error, "falha ao excluir variante")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrVariantNotFound
+	}
+	return nil
+}
+
+// productSKU confere o produto da empresa do contexto e devolve o SKU base da grade
+func (r *variantRepository) productSKU(ctx context.Context, productID int) (string, error) {
+	var product struct {
+		ID  int
+		SKU string
+	}
+	err := db.Conn(ctx, r.db).Table("products").
+		Scopes(tenant.Scope(ctx, "products")).
+		Select("id, sku").
+		Where("id = ? AND deleted_at IS NULL", productID).
+		Take(&product).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", errors.ErrProductNotFound
+		}
+		r.logger.Error("erro ao buscar produto", zap.Error(err), zap.Int("product_id", productID))
+		return "", errors.WrapError(err, "falha ao buscar produto")
+	}
+	return product.SKU, nil
+}
+
+func (r *variantRepository) getVariant(ctx context.Context, productID, variantID int) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	if err := db.Conn(ctx, r.db).
+		Where("id = ? AND product_id = ?", variantID, productID).
+		First(&variant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrVariantNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar variante")
+	}
+	return &variant, nil
+}
+
+// combinationKey identifica a combinação de tamanho e cor sem diferenciar maiúsculas
+func combinationKey(size, color string) string {
+	return strings.ToLower(size) + "|" + strings.ToLower(color)
+}
@@ -2,26 +2,39 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"database/sql"
 	"fmt"
 )
 
 // CreateWarranty insere uma nova garantia no banco.
-func CreateWarranty(w models.Warranty) error {
+func CreateWarranty(ctx context.Context, w models.Warranty) error {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return errors.ErrTenantRequired
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	_, err = conn.Exec(`INSERT INTO warranties (product_id, duration_months, price) VALUES ($1, $2, $3)`,
-		w.ProductID, w.DurationMonths, w.Price)
+	_, err = conn.ExecContext(ctx, `INSERT INTO warranties (product_id, duration_months, price, company_id) VALUES ($1, $2, $3, $4)`,
+		w.ProductID, w.DurationMonths, w.Price, companyID)
 	return err
 }
 
 // GetWarrantyByID recupera uma garantia pelo seu ID.
-func GetWarrantyByID(id int) (*models.Warranty, error) {
+func GetWarrantyByID(ctx context.Context, id int) (*models.Warranty, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
@@ -29,7 +42,7 @@ func GetWarrantyByID(id int) (*models.Warranty, error) {
 	defer conn.Close()
 
 	var w models.Warranty
-	err = conn.QueryRow(`SELECT id, product_id, duration_months, price FROM warranties WHERE id = $1`, id).
+	err = conn.QueryRowContext(ctx, `SELECT id, product_id, duration_months, price FROM warranties WHERE id = $1 AND `+company, id).
 		Scan(&w.ID, &w.ProductID, &w.DurationMonths, &w.Price)
 	if err != nil {
 		return nil, err
@@ -38,14 +51,19 @@ func GetWarrantyByID(id int) (*models.Warranty, error) {
 }
 
 // GetWarranties retorna todas as garantias cadastradas.
-func GetWarranties() ([]models.Warranty, error) {
+func GetWarranties(ctx context.Context) ([]models.Warranty, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 
-	rows, err := conn.Query(`SELECT id, product_id, duration_months, price FROM warranties`)
+	rows, err := conn.QueryContext(ctx, `SELECT id, product_id, duration_months, price FROM warranties WHERE `+company)
 	if err != nil {
 		return nil, err
 	}
@@ -63,14 +81,19 @@ func GetWarranties() ([]models.Warranty, error) {
 }
 
 // UpdateWarrantyByID atualiza uma garantia com base em seu ID.
-func UpdateWarrantyByID(id int, updated models.Warranty) error {
+func UpdateWarrantyByID(ctx context.Context, id int, updated models.Warranty) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	res, err := conn.Exec(`UPDATE warranties SET product_id=$1, duration_months=$2, price=$3 WHERE id=$4`,
+	res, err := conn.ExecContext(ctx, `UPDATE warranties SET product_id=$1, duration_months=$2, price=$3 WHERE id=$4 AND `+company,
 		updated.ProductID, updated.DurationMonths, updated.Price, id)
 	if err != nil {
 		return err
@@ -87,14 +110,19 @@ func UpdateWarrantyByID(id int, updated models.Warranty) error {
 }
 
 // DeleteWarrantyByID remove uma garantia com base em seu ID.
-func DeleteWarrantyByID(id int) error {
+func DeleteWarrantyByID(ctx context.Context, id int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	result, err := conn.Exec(`DELETE FROM warranties WHERE id = $1`, id)
+	result, err := conn.ExecContext(ctx, `DELETE FROM warranties WHERE id = $1 AND `+company, id)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"testing"
 )
 
@@ -10,7 +13,7 @@ func TestInsertWarranty(t *testing.T) {
 	p := models.Product{
 		Name:        "Produto para Garantia",
 		Description: "Produto para teste de garantia",
-		Price:       100.0,
+		Price:       money.FromInt(100),
 		Stock:       10,
 	}
 	if err := CreateProduct(&p); err != nil {
@@ -26,15 +29,15 @@ func TestInsertWarranty(t *testing.T) {
 	w := models.Warranty{
 		ProductID:      productID,
 		DurationMonths: 12,
-		Price:          20.0,
+		Price:          money.FromInt(20),
 	}
-	if err := CreateWarranty(w); err != nil {
+	if err := CreateWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), w); err != nil {
 		t.Fatalf("Erro ao criar garantia: %v", err)
 	}
 }
 
 func TestListWarranties(t *testing.T) {
-	warranties, err := GetWarranties()
+	warranties, err := GetWarranties(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao listar garantias: %v", err)
 	}
@@ -48,7 +51,7 @@ func TestUpdateWarrantyByID(t *testing.T) {
 	p := models.Product{
 		Name:        "Produto para Atualização de Garantia",
 		Description: "Produto para teste de atualização de garantia",
-		Price:       100.0,
+		Price:       money.FromInt(100),
 		Stock:       10,
 	}
 	if err := CreateProduct(&p); err != nil {
@@ -64,12 +67,12 @@ func TestUpdateWarrantyByID(t *testing.T) {
 	w := models.Warranty{
 		ProductID:      productID,
 		DurationMonths: 12,
-		Price:          20.0,
+		Price:          money.FromInt(20),
 	}
-	if err := CreateWarranty(w); err != nil {
+	if err := CreateWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), w); err != nil {
 		t.Fatalf("Erro ao criar garantia para update: %v", err)
 	}
-	warranties, err := GetWarranties()
+	warranties, err := GetWarranties(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil || len(warranties) == 0 {
 		t.Fatalf("Erro ao buscar garantias para update: %v", err)
 	}
@@ -79,9 +82,9 @@ func TestUpdateWarrantyByID(t *testing.T) {
 	updatedWarranty := models.Warranty{
 		ProductID:      productID,
 		DurationMonths: 24,
-		Price:          25.5,
+		Price:          money.MustParse("25.5"),
 	}
-	if err := UpdateWarrantyByID(warrantyID, updatedWarranty); err != nil {
+	if err := UpdateWarrantyByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), warrantyID, updatedWarranty); err != nil {
 		t.Fatalf("Erro ao atualizar garantia: %v", err)
 	}
 }
@@ -91,7 +94,7 @@ func TestDeleteWarrantyByID(t *testing.T) {
 	p := models.Product{
 		Name:        "Produto para Deleção de Garantia",
 		Description: "Produto para teste de deleção de garantia",
-		Price:       100.0,
+		Price:       money.FromInt(100),
 		Stock:       10,
 	}
 	if err := CreateProduct(&p); err != nil {
@@ -107,19 +110,19 @@ func TestDeleteWarrantyByID(t *testing.T) {
 	w := models.Warranty{
 		ProductID:      productID,
 		DurationMonths: 12,
-		Price:          20.0,
+		Price:          money.FromInt(20),
 	}
-	if err := CreateWarranty(w); err != nil {
+	if err := CreateWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), w); err != nil {
 		t.Fatalf("Erro ao criar garantia para delete: %v", err)
 	}
-	warranties, err := GetWarranties()
+	warranties, err := GetWarranties(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil || len(warranties) == 0 {
 		t.Fatalf("Erro ao listar garantias para delete: %v", err)
 	}
 	warrantyID := warranties[len(warranties)-1].ID
 
 	// Deleta a garantia
-	if err := DeleteWarrantyByID(warrantyID); err != nil {
+	if err := DeleteWarrantyByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), warrantyID); err != nil {
 		t.Fatalf("Erro ao deletar garantia: %v", err)
 	}
 }
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	attachments "ERP-ONSMART/backend/internal/modules/attachments/models"
+	attachmentsService "ERP-ONSMART/backend/internal/modules/attachments/service"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+)
+
+// Tipo de documento das imagens no módulo de anexos
+const productEntity = "product"
+
+// Dependências do catálogo, substituídas nos testes
+var (
+	newCatalogRepository = repository.NewCatalogRepository
+	uploadAttachment     = attachmentsService.UploadAttachment
+	deleteAttachment     = attachmentsService.DeleteAttachment
+	openAttachment       = attachmentsService.OpenAttachment
+)
+
+// ImageUpload traz o arquivo de uma nova imagem do produto
+type ImageUpload struct {
+	FileName    string
+	ContentType string
+	Size        int64
+	Content     io.Reader
+	AltText     string
+	UploadedBy  string
+}
+
+// ListCategoryTree retorna a árvore de categorias do catálogo
+func ListCategoryTree(ctx context.Context) ([]models.ProductCategory, error) {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildCategoryTree(categories), nil
+}
+
+// CreateCategory cria uma categoria, na raiz ou abaixo de parent_id
+func CreateCategory(ctx context.Context, input models.CategoryInput) (*models.ProductCategory, error) {
+	category, err := input.Category()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	if category.ParentID != nil {
+		if _, err := repo.GetCategory(ctx, *category.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if err := repo.CreateCategory(ctx, &category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory altera a categoria; a nova mãe não pode ser a própria categoria nem uma das
+// suas subcategorias
+func UpdateCategory(ctx context.Context, id int, input models.CategoryInput) (*models.ProductCategory, error) {
+	category, err := input.Category()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !hasCategory(categories, id) {
+		return nil, errors.ErrCategoryNotFound
+	}
+	if category.ParentID != nil {
+		if !hasCategory(categories, *category.ParentID) {
+			return nil, errors.ErrCategoryNotFound
+		}
+		if models.CreatesCycle(categories, id, *category.ParentID) {
+			return nil, errors.ErrCategoryCycle
+		}
+	}
+
+	category.ID = id
+	if err := repo.UpdateCategory(ctx, &category); err != nil {
+		return nil, err
+	}
+	return repo.GetCategory(ctx, id)
+}
+
+// DeleteCategory remove uma categoria sem subcategorias
+func DeleteCategory(ctx context.Context, id int) error {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteCategory(ctx, id)
+}
+
+// hasCategory informa se a categoria está na lista
+func hasCategory(categories []models.ProductCategory, id int) bool {
+	for _, category := range categories {
+		if category.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SetProductCatalog define a categoria do produto e a publicação no catálogo público
+func SetProductCatalog(ctx context.Context, productID int, settings models.CatalogSettings) (*models.Product, error) {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	if settings.CategoryID != nil {
+		if _, err := repo.GetCategory(ctx, *settings.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	return repo.SetCatalogSettings(ctx, productID, settings, localtime.Now(ctx))
+}
+
+// ListProductImages lista as imagens do produto na ordem de exibição
+func ListProductImages(ctx context.Context, productID int) ([]models.ProductImage, error) {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListImages(ctx, productID)
+}
+
+// AddProductImage grava o arquivo como anexo do produto e o inclui no fim das imagens
+func AddProductImage(ctx context.Context, productID int, upload ImageUpload) (*models.ProductImage, error) {
+	if !models.IsCatalogImage(upload.ContentType) {
+		return nil, errors.ErrInvalidProductImage
+	}
+
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := uploadAttachment(ctx, productEntity, productID, attachmentsService.Upload{
+		FileName:    upload.FileName,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		Content:     upload.Content,
+		Description: upload.AltText,
+		UploadedBy:  upload.UploadedBy,
+	})
+	if err != nil {
+		if err == errors.ErrEntityNotFound {
+			return nil, errors.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	image := &models.ProductImage{
+		ProductID:    productID,
+		AttachmentID: attachment.ID,
+		AltText:      strings.TrimSpace(upload.AltText),
+		Attachment:   attachment,
+	}
+	if err := repo.CreateImage(ctx, image); err != nil {
+		// Sem a imagem, o anexo fica na lista de anexos do produto, de onde pode ser removido
+		return nil, err
+	}
+	return image, nil
+}
+
+// DeleteProductImage remove a imagem e o anexo com o arquivo
+func DeleteProductImage(ctx context.Context, productID, imageID int) error {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return err
+	}
+	image, err := repo.GetImage(ctx, productID, imageID)
+	if err != nil {
+		return err
+	}
+	// A imagem é removida junto com o anexo (ON DELETE CASCADE)
+	return deleteAttachment(ctx, image.AttachmentID)
+}
+
+// ReorderProductImages grava a nova ordem das imagens do produto
+func ReorderProductImages(ctx context.Context, productID int, order models.ImageOrder) ([]models.ProductImage, error) {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	images, err := repo.ListImages(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := order.Validate(images); err != nil {
+		return nil, err
+	}
+	if err := repo.ReorderImages(ctx, productID, order.ImageIDs); err != nil {
+		return nil, err
+	}
+	return repo.ListImages(ctx, productID)
+}
+
+// ListCatalogProducts lista os produtos publicados e ativos na visão pública, com as imagens e
+// a categoria
+func ListCatalogProducts(ctx context.Context, filter models.CatalogFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := categoriesByID(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if filter.CategoryID > 0 {
+		if _, ok := categories[filter.CategoryID]; !ok {
+			return nil, errors.ErrCategoryNotFound
+		}
+		filter.CategoryIDs = models.CategoryWithDescendants(categoryList(categories), filter.CategoryID)
+	}
+
+	products, total, err := repo.ListPublished(ctx, filter, params)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	images, err := repo.ImagesByProduct(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]models.CatalogProduct, 0, len(products))
+	for _, product := range products {
+		views = append(views, models.NewCatalogProduct(product, images[product.ID], categories))
+	}
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, views), nil
+}
+
+// RebuildSearchIndex recalcula o índice de busca do catálogo (erpctl reindex) e retorna quantos
+// produtos estavam com o vetor de busca desatualizado
+func RebuildSearchIndex(ctx context.Context) (int64, error) {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return 0, err
+	}
+	return repo.RebuildSearchIndex(ctx)
+}
+
+// GetCatalogProduct busca um produto publicado na visão pública
+func GetCatalogProduct(ctx context.Context, id int) (*models.CatalogProduct, error) {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, err
+	}
+	product, err := repo.GetPublished(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := categoriesByID(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	images, err := repo.ListImages(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	view := models.NewCatalogProduct(*product, images, categories)
+	return &view, nil
+}
+
+// OpenCatalogImage abre o arquivo de uma imagem de produto publicado
+func OpenCatalogImage(ctx context.Context, imageID int) (*attachments.Attachment, io.ReadCloser, error) {
+	repo, err := newCatalogRepository()
+	if err != nil {
+		return nil, nil, err
+	}
+	image, err := repo.GetPublishedImage(ctx, imageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return openAttachment(ctx, image.AttachmentID)
+}
+
+// categoriesByID indexa as categorias da empresa pelo ID
+func categoriesByID(ctx context.Context, repo repository.CatalogRepository) (map[int]models.ProductCategory, error) {
+	categories, err := repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.ProductCategory, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	return byID, nil
+}
+
+// categoryList devolve as categorias indexadas como lista
+func categoryList(byID map[int]models.ProductCategory) []models.ProductCategory {
+	categories := make([]models.ProductCategory, 0, len(byID))
+	for _, category := range byID {
+		categories = append(categories, category)
+	}
+	return categories
+}
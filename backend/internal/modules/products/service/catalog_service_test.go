@@ -0,0 +1,265 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	attachments "ERP-ONSMART/backend/internal/modules/attachments/models"
+	attachmentsService "ERP-ONSMART/backend/internal/modules/attachments/service"
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCatalogRepository guarda categorias, imagens e produtos publicados em memória
+type fakeCatalogRepository struct {
+	categories []models.ProductCategory
+	images     []models.ProductImage
+	published  []models.Product
+	settings   map[int]models.CatalogSettings
+	filter     models.CatalogFilter
+}
+
+func (f *fakeCatalogRepository) ListCategories(ctx context.Context) ([]models.ProductCategory, error) {
+	return f.categories, nil
+}
+
+func (f *fakeCatalogRepository) GetCategory(ctx context.Context, id int) (*models.ProductCategory, error) {
+	for _, category := range f.categories {
+		if category.ID == id {
+			return &category, nil
+		}
+	}
+	return nil, errors.ErrCategoryNotFound
+}
+
+func (f *fakeCatalogRepository) CreateCategory(ctx context.Context, category *models.ProductCategory) error {
+	category.ID = len(f.categories) + 1
+	f.categories = append(f.categories, *category)
+	return nil
+}
+
+func (f *fakeCatalogRepository) UpdateCategory(ctx context.Context, category *models.ProductCategory) error {
+	for i := range f.categories {
+		if f.categories[i].ID == category.ID {
+			f.categories[i] = *category
+			return nil
+		}
+	}
+	return errors.ErrCategoryNotFound
+}
+
+func (f *fakeCatalogRepository) DeleteCategory(ctx context.Context, id int) error {
+	return nil
+}
+
+func (f *fakeCatalogRepository) SetCatalogSettings(ctx context.Context, productID int, settings models.CatalogSettings, now time.Time) (*models.Product, error) {
+	if f.settings == nil {
+		f.settings = make(map[int]models.CatalogSettings)
+	}
+	f.settings[productID] = settings
+	return &models.Product{ID: productID, CategoryID: settings.CategoryID, Published: settings.Published}, nil
+}
+
+func (f *fakeCatalogRepository) ListImages(ctx context.Context, productID int) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	for _, image := range f.images {
+		if image.ProductID == productID {
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+func (f *fakeCatalogRepository) GetImage(ctx context.Context, productID, imageID int) (*models.ProductImage, error) {
+	for _, image := range f.images {
+		if image.ID == imageID && image.ProductID == productID {
+			return &image, nil
+		}
+	}
+	return nil, errors.ErrProductImageNotFound
+}
+
+func (f *fakeCatalogRepository) CreateImage(ctx context.Context, image *models.ProductImage) error {
+	image.ID = 100 + len(f.images)
+	image.Position = len(f.images)
+	f.images = append(f.images, *image)
+	return nil
+}
+
+func (f *fakeCatalogRepository) ReorderImages(ctx context.Context, productID int, imageIDs []int) error {
+	return nil
+}
+
+func (f *fakeCatalogRepository) ListPublished(ctx context.Context, filter models.CatalogFilter, params *pagination.PaginationParams) ([]models.Product, int64, error) {
+	f.filter = filter
+	return f.published, int64(len(f.published)), nil
+}
+
+func (f *fakeCatalogRepository) GetPublished(ctx context.Context, id int) (*models.Product, error) {
+	for _, product := range f.published {
+		if product.ID == id {
+			return &product, nil
+		}
+	}
+	return nil, errors.ErrProductNotFound
+}
+
+func (f *fakeCatalogRepository) ImagesByProduct(ctx context.Context, productIDs []int) (map[int][]models.ProductImage, error) {
+	byProduct := make(map[int][]models.ProductImage)
+	for _, image := range f.images {
+		byProduct[image.ProductID] = append(byProduct[image.ProductID], image)
+	}
+	return byProduct, nil
+}
+
+func (f *fakeCatalogRepository) GetPublishedImage(ctx context.Context, imageID int) (*models.ProductImage, error) {
+	return nil, errors.ErrProductImageNotFound
+}
+
+func (f *fakeCatalogRepository) RebuildSearchIndex(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// useFakeCatalog troca o repositório e os anexos do catálogo pelos falsos durante o teste
+func useFakeCatalog(t *testing.T) *fakeCatalogRepository {
+	repo := &fakeCatalogRepository{
+		categories: []models.ProductCategory{
+			{ID: 1, Name: "Informática", Slug: "informatica"},
+			{ID: 2, Name: "Notebooks", Slug: "notebooks", ParentID: intPtr(1)},
+			{ID: 3, Name: "Gamer", Slug: "gamer", ParentID: intPtr(2)},
+			{ID: 4, Name: "Papelaria", Slug: "papelaria"},
+		},
+	}
+	originalRepo, originalUpload, originalDelete := newCatalogRepository, uploadAttachment, deleteAttachment
+	t.Cleanup(func() {
+		newCatalogRepository, uploadAttachment, deleteAttachment = originalRepo, originalUpload, originalDelete
+	})
+	newCatalogRepository = func() (repository.CatalogRepository, error) { return repo, nil }
+	return repo
+}
+
+func TestCatalogUpdateCategoryRejectsCycle(t *testing.T) {
+	repo := useFakeCatalog(t)
+
+	_, err := UpdateCategory(context.Background(), 1, models.CategoryInput{Name: "Informática", ParentID: intPtr(3)})
+	if err != errors.ErrCategoryCycle {
+		t.Fatalf("Esperado ErrCategoryCycle, obtido %v", err)
+	}
+	if _, err := UpdateCategory(context.Background(), 9, models.CategoryInput{Name: "Nova"}); err != errors.ErrCategoryNotFound {
+		t.Errorf("Esperado ErrCategoryNotFound, obtido %v", err)
+	}
+
+	moved, err := UpdateCategory(context.Background(), 3, models.CategoryInput{Name: "Gamer", ParentID: intPtr(1)})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if *moved.ParentID != 1 || *repo.categories[2].ParentID != 1 {
+		t.Errorf("Categoria não foi movida: %+v", moved)
+	}
+}
+
+func TestCatalogSetProductCatalogChecksCategory(t *testing.T) {
+	repo := useFakeCatalog(t)
+
+	settings := models.CatalogSettings{CategoryID: intPtr(99), Published: true}
+	if _, err := SetProductCatalog(context.Background(), 7, settings); err != errors.ErrCategoryNotFound {
+		t.Fatalf("Esperado ErrCategoryNotFound, obtido %v", err)
+	}
+	settings.CategoryID = intPtr(2)
+	product, err := SetProductCatalog(context.Background(), 7, settings)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if !product.Published || !reflect.DeepEqual(repo.settings[7], settings) {
+		t.Errorf("Publicação não gravada: %+v", repo.settings[7])
+	}
+}
+
+func TestCatalogAddProductImage(t *testing.T) {
+	repo := useFakeCatalog(t)
+	var uploaded []attachmentsService.Upload
+	uploadAttachment = func(ctx context.Context, entityType string, entityID int, upload attachmentsService.Upload) (*attachments.Attachment, error) {
+		if entityType != "product" || entityID != 7 {
+			return nil, errors.ErrEntityNotFound
+		}
+		uploaded = append(uploaded, upload)
+		return &attachments.Attachment{ID: 50 + len(uploaded), EntityType: entityType, EntityID: entityID, ContentType: upload.ContentType}, nil
+	}
+
+	_, err := AddProductImage(context.Background(), 7, ImageUpload{FileName: "manual.pdf", ContentType: "application/pdf"})
+	if err != errors.ErrInvalidProductImage {
+		t.Fatalf("Esperado ErrInvalidProductImage, obtido %v", err)
+	}
+	if len(uploaded) != 0 {
+		t.Fatal("Arquivo recusado não deveria ser gravado nos anexos")
+	}
+
+	image, err := AddProductImage(context.Background(), 7, ImageUpload{
+		FileName: "frente.png", ContentType: "image/png", Content: strings.NewReader("png"), AltText: " Frente ",
+	})
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if image.AttachmentID != 51 || image.AltText != "Frente" || len(repo.images) != 1 {
+		t.Errorf("Imagem inesperada: %+v", image)
+	}
+
+	if _, err := AddProductImage(context.Background(), 8, ImageUpload{ContentType: "image/png"}); err != errors.ErrProductNotFound {
+		t.Errorf("Esperado ErrProductNotFound, obtido %v", err)
+	}
+}
+
+func TestCatalogDeleteProductImageRemovesAttachment(t *testing.T) {
+	repo := useFakeCatalog(t)
+	repo.images = []models.ProductImage{{ID: 10, ProductID: 7, AttachmentID: 51}}
+	var deleted []int
+	deleteAttachment = func(ctx context.Context, id int) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	if err := DeleteProductImage(context.Background(), 8, 10); err != errors.ErrProductImageNotFound {
+		t.Fatalf("Imagem de outro produto: esperado ErrProductImageNotFound, obtido %v", err)
+	}
+	if err := DeleteProductImage(context.Background(), 7, 10); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if !reflect.DeepEqual(deleted, []int{51}) {
+		t.Errorf("Anexos removidos: %v", deleted)
+	}
+}
+
+func TestCatalogListProductsFiltersCategoryBranch(t *testing.T) {
+	repo := useFakeCatalog(t)
+	repo.published = []models.Product{
+		{ID: 7, Name: "Notebook Gamer", Coin: "BRL", Price: money.FromInt(8000), CostPrice: money.FromInt(6000), Stock: 2, CategoryID: intPtr(3)},
+	}
+	repo.images = []models.ProductImage{{ID: 10, ProductID: 7, AttachmentID: 51}}
+
+	params := pagination.PaginationParams{Page: 1, PageSize: 10}
+	result, err := ListCatalogProducts(context.Background(), models.CatalogFilter{CategoryID: 1}, &params)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if !reflect.DeepEqual(repo.filter.CategoryIDs, []int{1, 2, 3}) {
+		t.Errorf("Filtro deveria incluir as subcategorias: %v", repo.filter.CategoryIDs)
+	}
+	products := result.Items.([]models.CatalogProduct)
+	if len(products) != 1 || products[0].Category.Path != "Informática > Notebooks > Gamer" ||
+		len(products[0].Images) != 1 || !products[0].InStock {
+		t.Errorf("Catálogo inesperado: %+v", products)
+	}
+
+	if _, err := ListCatalogProducts(context.Background(), models.CatalogFilter{CategoryID: 99}, &params); err != errors.ErrCategoryNotFound {
+		t.Errorf("Esperado ErrCategoryNotFound, obtido %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/repository"
+)
+
+// GetKit retorna a composição do kit do produto
+func GetKit(ctx context.Context, productID int) (*models.ProductKit, error) {
+	repo, err := repository.NewKitRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetKit(ctx, productID)
+}
+
+// SetKit define a composição do kit e como ele entra nos documentos de venda
+func SetKit(ctx context.Context, productID int, input models.KitInput) (*models.ProductKit, error) {
+	repo, err := repository.NewKitRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.SetKit(ctx, productID, input)
+}
+
+// DeleteKit desfaz o kit do produto
+func DeleteKit(ctx context.Context, productID int) error {
+	repo, err := repository.NewKitRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteKit(ctx, productID)
+}
+
+// ListKitMargins apura a receita, o custo e a margem de cada kit vendido no período
+func ListKitMargins(ctx context.Context, from, to time.Time) ([]models.KitMargin, error) {
+	repo, err := repository.NewKitRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListKitMargins(ctx, from, to)
+}
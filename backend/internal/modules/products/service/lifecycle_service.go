@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/repository"
+)
+
+// SetProductLifecycle muda o estado do produto e o substituto sugerido nas novas cotações
+func SetProductLifecycle(ctx context.Context, productID int, input models.LifecycleInput) (*models.Product, error) {
+	repo, err := repository.NewLifecycleRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.SetLifecycle(ctx, productID, input)
+}
+
+// GetProductReplacement sugere o substituto do produto; nil sem produto vendável para sugerir
+func GetProductReplacement(ctx context.Context, productID int) (*models.ReplacementSuggestion, error) {
+	repo, err := repository.NewLifecycleRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetReplacement(ctx, productID)
+}
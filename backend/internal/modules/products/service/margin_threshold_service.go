@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/repository"
+)
+
+// ListMinMargins lista a margem mínima de cada categoria, própria ou herdada
+func ListMinMargins(ctx context.Context) ([]models.MinMargin, error) {
+	repo, err := repository.NewMarginThresholdRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListMinMargins(ctx)
+}
+
+// SetMinMargin define a margem mínima própria da categoria
+func SetMinMargin(ctx context.Context, categoryID int, input models.MarginThresholdInput, updatedBy string) (*models.CategoryMarginThreshold, error) {
+	repo, err := repository.NewMarginThresholdRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.SetThreshold(ctx, categoryID, *input.MinMarginPercent, updatedBy)
+}
+
+// DeleteMinMargin remove a margem mínima própria da categoria
+func DeleteMinMargin(ctx context.Context, categoryID int) error {
+	repo, err := repository.NewMarginThresholdRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteThreshold(ctx, categoryID)
+}
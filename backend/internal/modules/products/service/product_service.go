@@ -1,9 +1,9 @@
 package service
 
 import (
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
 	"ERP-ONSMART/backend/internal/modules/products/models"
 	"ERP-ONSMART/backend/internal/modules/products/repository"
-	"log"
 )
 
 func CreateProduct(p *models.Product) error {
@@ -14,18 +14,30 @@ func ListProducts() ([]models.Product, error) {
 	return repository.GetAllProducts()
 }
 
+// SearchProducts lista os produtos com os campos personalizados informados
+func SearchProducts(customFields customfields.Values) ([]models.Product, error) {
+	return repository.FindProducts(customFields)
+}
+
 func ListProductByID(id int) (*models.Product, error) {
 	return repository.GetProductByID(id)
 }
 
+// UpdateProduct atualiza o produto; o estado segue as regras do ciclo de vida (fim de vida não
+// volta atrás)
 func UpdateProduct(id int, updated models.Product) error {
+	if updated.Status != "" {
+		current, err := repository.GetProductByID(id)
+		if err != nil {
+			return err
+		}
+		if err := models.CheckTransition(current.Status, updated.Status); err != nil {
+			return err
+		}
+	}
 	return repository.UpdateProductByID(id, updated)
 }
 
 func DeleteProduct(id int) error {
-	err := repository.DeleteProductByID(id)
-	if err != nil {
-		log.Fatalf("[prod/service]: Erro ao deletar produto com ID: %d, erro: %v", id, err)
-	}
-	return err
+	return repository.DeleteProductByID(id)
 }
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/repository"
+)
+
+// GetUnits retorna as unidades de medida do produto
+func GetUnits(ctx context.Context, productID int) (models.UnitSet, error) {
+	repo, err := repository.NewUnitRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetUnits(ctx, productID)
+}
+
+// SetUnits substitui as unidades de medida do produto
+func SetUnits(ctx context.Context, productID int, input models.UnitsInput) (models.UnitSet, error) {
+	repo, err := repository.NewUnitRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.SetUnits(ctx, productID, input)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/products/repository"
+)
+
+// ListVariants lista a grade de variantes do produto
+func ListVariants(ctx context.Context, productID int) ([]models.ProductVariant, error) {
+	repo, err := repository.NewVariantRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListVariants(ctx, productID)
+}
+
+// GenerateVariantMatrix cria as variantes de tamanho x cor que ainda não existem
+func GenerateVariantMatrix(ctx context.Context, productID int, matrix models.VariantMatrix) ([]models.ProductVariant, error) {
+	repo, err := repository.NewVariantRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GenerateMatrix(ctx, productID, matrix)
+}
+
+// UpdateVariant altera os dados de uma variante do produto
+func UpdateVariant(ctx context.Context, productID, variantID int, update models.VariantUpdate) (*models.ProductVariant, error) {
+	repo, err := repository.NewVariantRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.UpdateVariant(ctx, productID, variantID, update)
+}
+
+// DeleteVariant remove uma variante do produto
+func DeleteVariant(ctx context.Context, productID, variantID int) error {
+	repo, err := repository.NewVariantRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteVariant(ctx, productID, variantID)
+}
@@ -3,29 +3,30 @@ package service
 import (
 	"ERP-ONSMART/backend/internal/modules/products/models"
 	"ERP-ONSMART/backend/internal/modules/products/repository"
+	"context"
 )
 
 // CreateWarranty cria uma nova garantia.
-func CreateWarranty(w models.Warranty) error {
-	return repository.CreateWarranty(w)
+func CreateWarranty(ctx context.Context, w models.Warranty) error {
+	return repository.CreateWarranty(ctx, w)
 }
 
 // GetWarrantyByID recupera uma garantia pelo seu ID.
-func GetWarrantyByID(id int) (*models.Warranty, error) {
-	return repository.GetWarrantyByID(id)
+func GetWarrantyByID(ctx context.Context, id int) (*models.Warranty, error) {
+	return repository.GetWarrantyByID(ctx, id)
 }
 
 // ListWarranties retorna todas as garantias.
-func ListWarranties() ([]models.Warranty, error) {
-	return repository.GetWarranties()
+func ListWarranties(ctx context.Context) ([]models.Warranty, error) {
+	return repository.GetWarranties(ctx)
 }
 
 // UpdateWarranty atualiza uma garantia com base em seu ID.
-func UpdateWarranty(id int, updated models.Warranty) error {
-	return repository.UpdateWarrantyByID(id, updated)
+func UpdateWarranty(ctx context.Context, id int, updated models.Warranty) error {
+	return repository.UpdateWarrantyByID(ctx, id, updated)
 }
 
 // DeleteWarranty deleta uma garantia com base em seu ID.
-func DeleteWarranty(id int) error {
-	return repository.DeleteWarrantyByID(id)
+func DeleteWarranty(ctx context.Context, id int) error {
+	return repository.DeleteWarrantyByID(ctx, id)
 }
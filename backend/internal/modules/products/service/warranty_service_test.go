@@ -2,6 +2,9 @@ package service
 
 import (
 	"ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"testing"
 )
 
@@ -12,7 +15,7 @@ func TestInsertWarranty(t *testing.T) {
 	product := models.Product{
 		Name:        "Product for Warranty",
 		Description: "Test product for warranty insertion",
-		Price:       100.0,
+		Price:       money.FromInt(100),
 		Stock:       10,
 	}
 	if err := CreateProduct(&product); err != nil {
@@ -28,9 +31,9 @@ func TestInsertWarranty(t *testing.T) {
 	warranty := models.Warranty{
 		ProductID:      productID,
 		DurationMonths: 12,
-		Price:          15.0,
+		Price:          money.FromInt(15),
 	}
-	if err := CreateWarranty(warranty); err != nil {
+	if err := CreateWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), warranty); err != nil {
 		t.Fatalf("Erro ao criar garantia: %v", err)
 	}
 }
@@ -38,7 +41,7 @@ func TestInsertWarranty(t *testing.T) {
 // TestGetWarranty recupera uma garantia pelo seu ID e compara seus dados.
 func TestGetWarranty(t *testing.T) {
 	// Lista todas as garantias cadastradas
-	warranties, err := ListWarranties()
+	warranties, err := ListWarranties(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao listar garantias: %v", err)
 	}
@@ -49,7 +52,7 @@ func TestGetWarranty(t *testing.T) {
 	lastWarranty := warranties[len(warranties)-1]
 
 	// Recupera a garantia pelo ID
-	retrieved, err := GetWarrantyByID(lastWarranty.ID)
+	retrieved, err := GetWarrantyByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), lastWarranty.ID)
 	if err != nil {
 		t.Fatalf("Erro ao obter garantia: %v", err)
 	}
@@ -64,7 +67,7 @@ func TestGetWarranty(t *testing.T) {
 
 func TestListWarranties(t *testing.T) {
 	// Tenta listar todas as garantias existentes
-	warranties, err := ListWarranties()
+	warranties, err := ListWarranties(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao listar garantias: %v", err)
 	}
@@ -78,7 +81,7 @@ func TestUpdateWarranty(t *testing.T) {
 	product := models.Product{
 		Name:        "Product for Warranty Update",
 		Description: "Product for warranty update testing",
-		Price:       150.0,
+		Price:       money.FromInt(150),
 		Stock:       5,
 	}
 	if err := CreateProduct(&product); err != nil {
@@ -94,12 +97,12 @@ func TestUpdateWarranty(t *testing.T) {
 	warranty := models.Warranty{
 		ProductID:      productID,
 		DurationMonths: 6,
-		Price:          20.0,
+		Price:          money.FromInt(20),
 	}
-	if err := CreateWarranty(warranty); err != nil {
+	if err := CreateWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), warranty); err != nil {
 		t.Fatalf("Erro ao criar garantia: %v", err)
 	}
-	warranties, err := ListWarranties()
+	warranties, err := ListWarranties(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil || len(warranties) == 0 {
 		t.Fatalf("Erro ao listar garantias: %v", err)
 	}
@@ -109,14 +112,14 @@ func TestUpdateWarranty(t *testing.T) {
 	updatedWarranty := models.Warranty{
 		ProductID:      productID,
 		DurationMonths: 12,
-		Price:          25.5,
+		Price:          money.MustParse("25.5"),
 	}
-	if err := UpdateWarranty(createdWarranty.ID, updatedWarranty); err != nil {
+	if err := UpdateWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), createdWarranty.ID, updatedWarranty); err != nil {
 		t.Fatalf("Erro ao atualizar garantia: %v", err)
 	}
 
 	// Recupera a garantia atualizada e verifica os dados
-	retrieved, err := GetWarrantyByID(createdWarranty.ID)
+	retrieved, err := GetWarrantyByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), createdWarranty.ID)
 	if err != nil {
 		t.Fatalf("Erro ao buscar garantia atualizada: %v", err)
 	}
@@ -130,7 +133,7 @@ func TestDeleteWarranty(t *testing.T) {
 	product := models.Product{
 		Name:        "Product for Warranty Deletion",
 		Description: "Product for warranty deletion testing",
-		Price:       120.0,
+		Price:       money.FromInt(120),
 		Stock:       8,
 	}
 	if err := CreateProduct(&product); err != nil {
@@ -146,24 +149,24 @@ func TestDeleteWarranty(t *testing.T) {
 	warranty := models.Warranty{
 		ProductID:      productID,
 		DurationMonths: 9,
-		Price:          18.0,
+		Price:          money.FromInt(18),
 	}
-	if err := CreateWarranty(warranty); err != nil {
+	if err := CreateWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), warranty); err != nil {
 		t.Fatalf("Erro ao criar garantia: %v", err)
 	}
-	warranties, err := ListWarranties()
+	warranties, err := ListWarranties(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil || len(warranties) == 0 {
 		t.Fatalf("Erro ao listar garantias: %v", err)
 	}
 	createdWarranty := warranties[len(warranties)-1]
 
 	// Deleta a garantia
-	if err := DeleteWarranty(createdWarranty.ID); err != nil {
+	if err := DeleteWarranty(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), createdWarranty.ID); err != nil {
 		t.Fatalf("Erro ao deletar garantia: %v", err)
 	}
 
 	// Tenta recuperar a garantia deletada; espera erro
-	_, err = GetWarrantyByID(createdWarranty.ID)
+	_, err = GetWarrantyByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), createdWarranty.ID)
 	if err == nil {
 		t.Errorf("Garantia com ID %d ainda existe após deleção", createdWarranty.ID)
 	}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxInboundEmailSize limita o tamanho das mensagens recebidas pelo webhook
+const maxInboundEmailSize = 25 << 20
+
+// Recebe um e-mail com notas fiscais de fornecedores (XML da NF-e e DANFE em PDF)
+// O provedor mailgun envia o formulário da rota de entrada, assinado com a chave do Mailgun; o
+// provedor raw envia a mensagem MIME completa com a assinatura HMAC-SHA256 do corpo em X-Signature.
+// Ambos usam INBOUND_EMAIL_SECRET.
+// @Param provider path string true "Provedor (mailgun ou raw)"
+func InboundEmailWebhookHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundEmailSize)
+
+	var (
+		result *models.InboundResult
+		err    error
+	)
+	switch c.Param("provider") {
+	case models.ProviderMailgun:
+		if err := c.Request.ParseMultipartForm(maxInboundEmailSize); err != nil {
+			c.Error(errors.ErrInvalidInboundEmail)
+			return
+		}
+		result, err = service.ReceiveMailgunEmail(c.Request.Context(), c.Request.MultipartForm)
+	case models.ProviderRaw:
+		body, readErr := io.ReadAll(c.Request.Body)
+		if readErr != nil {
+			c.Error(errors.InvalidRequest(readErr))
+			return
+		}
+		result, err = service.ReceiveRawEmail(c.Request.Context(), body, c.GetHeader("X-Signature"))
+	default:
+		c.Error(errors.ErrUnknownInboundProvider)
+		return
+	}
+	if err != nil {
+		c.Error(err).SetMeta("erro ao processar e-mail de entrada")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Lista os documentos recebidos por e-mail; status=pending_review traz a fila de revisão
+// @Security BearerAuth
+// @Param status query string false "processed, duplicate, pending_review, resolved ou discarded"
+func ListInboundDocumentsHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.ListInboundDocuments(c.Request.Context(), c.Query("status"), &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar documentos recebidos")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna um documento recebido por e-mail; o arquivo fica nos anexos (inbound_document)
+// @Security BearerAuth
+// @Param id path int true "ID do documento"
+func GetInboundDocumentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	document, err := service.GetInboundDocument(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar documento recebido")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"document": document})
+}
+
+// Aprova a conta a pagar em rascunho importada da NF-e, que passa a aberta
+// @Security BearerAuth
+// @Param id path int true "ID do documento"
+func ApproveInboundBillHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	bill, err := service.ApproveInboundBill(c.Request.Context(), id, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao aprovar conta a pagar")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"supplier_bill": bill})
+}
+
+// Conclui a revisão manual do documento, opcionalmente ligando-o à conta a pagar lançada
+// @Security BearerAuth
+// @Param id path int true "ID do documento"
+func ResolveInboundDocumentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ResolveInboundInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	document, err := service.ResolveInboundDocument(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao concluir revisão do documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"document": document})
+}
+
+// Descarta o documento pendente de revisão
+// @Security BearerAuth
+// @Param id path int true "ID do documento"
+func DiscardInboundDocumentHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	document, err := service.DiscardInboundDocument(c.Request.Context(), id, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao descartar documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"document": document})
+}
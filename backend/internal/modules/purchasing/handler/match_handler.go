@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Registra as quantidades recebidas dos itens de uma entrega de pedido de compra (em unidades de
+// estoque) e confere de novo as contas do pedido aguardando conferência
+// @Security BearerAuth
+// @Param id path int true "ID da entrega"
+func ReceiveDeliveryHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ReceiveInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	delivery, err := service.ReceiveDelivery(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao registrar recebimento da entrega")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"delivery": delivery})
+}
+
+// Mostra, por item do pedido de compra, o pedido, o recebido, o cobrado e o pendente
+// @Security BearerAuth
+// @Param id path int true "ID do pedido de compra"
+func GetReceivingHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	lines, err := service.GetReceiving(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar recebimento do pedido de compra")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purchase_order_id": id, "items": lines})
+}
+
+// Confere a conta a pagar em rascunho com o pedido de compra (quantidade e preço) e o recebimento
+// @Security BearerAuth
+// @Param id path int true "ID da conta a pagar"
+func MatchBillHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := service.MatchBill(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao conferir conta a pagar")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"match": result})
+}
+
+// Retorna a última conferência de três vias da conta a pagar
+// @Security BearerAuth
+// @Param id path int true "ID da conta a pagar"
+func GetBillMatchHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := service.GetBillMatch(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar conferência da conta a pagar")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"match": result})
+}
+
+// Liga o item da conta em rascunho ao item do pedido de compra (po_item_id nulo desfaz o
+// vínculo) e confere a conta de novo
+// @Security BearerAuth
+// @Param id path int true "ID da conta a pagar"
+// @Param item_id path int true "ID do item da conta"
+func LinkBillItemHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := parseIDParam(c, "item_id")
+	if !ok {
+		return
+	}
+
+	var input models.LinkBillItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	result, err := service.LinkBillItem(c.Request.Context(), id, itemID, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao ligar item da conta ao pedido")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"match": result})
+}
+
+// Aceita (libera a aprovação e o pagamento) ou recusa as divergências da conferência
+// @Security BearerAuth
+// @Param id path int true "ID da conta a pagar"
+func ReviewBillMatchHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ReviewMatchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	result, err := service.ReviewBillMatch(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao revisar conferência da conta a pagar")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"match": result})
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/purchasing/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gera pedidos de compra sugeridos para os produtos abaixo do ponto de reposição
+func GenerateSuggestionsHandler(c *gin.Context) {
+	result, err := service.GenerateSuggestions(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar sugestões de compra")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Lista os pedidos de compra sugeridos aguardando revisão
+func ListSuggestedOrdersHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.GetSuggestedOrders(c.Request.Context(), &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar sugestões de compra")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Confirma o pedido de compra sugerido
+func ConfirmSuggestedOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	po, err := service.ConfirmSuggestedOrder(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao confirmar sugestão de compra")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purchase_order": po})
+}
+
+// Descarta o pedido de compra sugerido
+func DiscardSuggestedOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DiscardSuggestedOrder(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao descartar sugestão de compra")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "sugestão de compra descartada"})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,280 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Provedores de e-mail de entrada aceitos no webhook
+const (
+	// ProviderMailgun recebe o formulário multipart da rota de entrada do Mailgun
+	ProviderMailgun = "mailgun"
+	// ProviderRaw recebe a mensagem MIME completa (SES, relays SMTP e scripts de leitura IMAP)
+	ProviderRaw = "raw"
+)
+
+// Status dos documentos recebidos por e-mail
+const (
+	// InboundStatusProcessed: virou conta a pagar em rascunho
+	InboundStatusProcessed = "processed"
+	// InboundStatusDuplicate: a NF-e já tinha sido importada
+	InboundStatusDuplicate = "duplicate"
+	// InboundStatusPendingReview: não foi possível importar; aguarda revisão manual
+	InboundStatusPendingReview = "pending_review"
+	InboundStatusResolved      = "resolved"
+	InboundStatusDiscarded     = "discarded"
+)
+
+// InboundEntityType é o tipo de documento dos anexos que guardam os arquivos recebidos
+const InboundEntityType = "inbound_document"
+
+// InboundDocument é um anexo (XML ou PDF) recebido por e-mail. O arquivo fica nos anexos do
+// documento; os que não viraram conta a pagar ficam na fila de revisão.
+type InboundDocument struct {
+	ID              int        `json:"id" gorm:"primaryKey"`
+	CompanyID       int        `json:"company_id" gorm:"<-:create"`
+	Provider        string     `json:"provider"`
+	MessageID       string     `json:"message_id"`
+	Sender          string     `json:"sender"`
+	Subject         string     `json:"subject"`
+	FileName        string     `json:"file_name"`
+	ContentType     string     `json:"content_type"`
+	Size            int64      `json:"size"`
+	Status          string     `json:"status"`
+	NFeKey          string     `json:"nfe_key,omitempty" gorm:"column:nfe_key"`
+	SupplierBillID  *int       `json:"supplier_bill_id,omitempty"`
+	PurchaseOrderID *int       `json:"purchase_order_id,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	ReviewedBy      string     `json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (InboundDocument) TableName() string {
+	return "inbound_documents"
+}
+
+// ResolveInboundInput encerra a revisão de um documento, opcionalmente ligando-o à conta a
+// pagar lançada manualmente
+type ResolveInboundInput struct {
+	SupplierBillID *int `json:"supplier_bill_id"`
+}
+
+// InboundResult resume o processamento de um e-mail recebido
+type InboundResult struct {
+	MessageID string            `json:"message_id"`
+	Documents []InboundDocument `json:"documents"`
+}
+
+// Email é a mensagem recebida, já normalizada a partir do formato do provedor
+type Email struct {
+	MessageID   string
+	From        string
+	Subject     string
+	Attachments []EmailAttachment
+}
+
+// EmailAttachment é um arquivo anexado à mensagem
+type EmailAttachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// IsXML indica se o anexo é um XML (candidato a NF-e)
+func (a EmailAttachment) IsXML() bool {
+	return strings.EqualFold(filepath.Ext(a.FileName), ".xml") || strings.Contains(a.ContentType, "xml")
+}
+
+// IsPDF indica se o anexo é um PDF (em geral o DANFE)
+func (a EmailAttachment) IsPDF() bool {
+	return strings.EqualFold(filepath.Ext(a.FileName), ".pdf") || strings.Contains(a.ContentType, "pdf")
+}
+
+// ParseRawEmail lê a mensagem MIME completa e extrai os anexos, em qualquer nível de
+// multipart. Partes sem nome de arquivo (corpo, assinatura em linha) são ignoradas.
+func ParseRawEmail(raw []byte) (*Email, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.ErrInvalidInboundEmail
+	}
+
+	decoder := new(mime.WordDecoder)
+	email := &Email{
+		MessageID: strings.TrimSpace(msg.Header.Get("Message-Id")),
+		From:      decodeHeader(decoder, msg.Header.Get("From")),
+		Subject:   decodeHeader(decoder, msg.Header.Get("Subject")),
+	}
+	if addr, err := mail.ParseAddress(email.From); err == nil {
+		email.From = addr.Address
+	}
+
+	if err := collectParts(email, msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body); err != nil {
+		return nil, err
+	}
+	return email, nil
+}
+
+func collectParts(email *Email, contentType, encoding, disposition string, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return errors.ErrInvalidInboundEmail
+			}
+			err = collectParts(email, part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"), part)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	fileName := params["name"]
+	if _, dispParams, err := mime.ParseMediaType(disposition); err == nil && dispParams["filename"] != "" {
+		fileName = dispParams["filename"]
+	}
+	if fileName == "" {
+		return nil
+	}
+
+	content, err := io.ReadAll(decodeTransfer(encoding, body))
+	if err != nil {
+		return errors.ErrInvalidInboundEmail
+	}
+	email.Attachments = append(email.Attachments, EmailAttachment{
+		FileName:    decodeHeader(new(mime.WordDecoder), fileName),
+		ContentType: mediaType,
+		Content:     content,
+	})
+	return nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// newlineStripper remove as quebras de linha do base64 das mensagens, que o decodificador
+// padrão não aceita no meio do bloco
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	count, err := n.r.Read(p)
+	kept := 0
+	for _, b := range p[:count] {
+		if b != '\r' && b != '\n' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+func decodeHeader(decoder *mime.WordDecoder, value string) string {
+	if decoded, err := decoder.DecodeHeader(value); err == nil {
+		value = decoded
+	}
+	return strings.TrimSpace(value)
+}
+
+// ParseMailgunForm lê o formulário da rota de entrada do Mailgun (campos sender, subject,
+// Message-Id e arquivos attachment-1..N)
+func ParseMailgunForm(form *multipart.Form) (*Email, error) {
+	if form == nil {
+		return nil, errors.ErrInvalidInboundEmail
+	}
+
+	email := &Email{
+		MessageID: formValue(form, "Message-Id"),
+		From:      formValue(form, "sender"),
+		Subject:   formValue(form, "subject"),
+	}
+	for field, files := range form.File {
+		if !strings.HasPrefix(field, "attachment") {
+			continue
+		}
+		for _, header := range files {
+			file, err := header.Open()
+			if err != nil {
+				return nil, errors.ErrInvalidInboundEmail
+			}
+			content, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return nil, errors.ErrInvalidInboundEmail
+			}
+			email.Attachments = append(email.Attachments, EmailAttachment{
+				FileName:    header.Filename,
+				ContentType: header.Header.Get("Content-Type"),
+				Content:     content,
+			})
+		}
+	}
+	return email, nil
+}
+
+// VerifyMailgunSignature confere a assinatura do Mailgun: HMAC-SHA256 (hex) de timestamp+token
+// com a chave de assinatura da conta
+func VerifyMailgunSignature(key string, form *multipart.Form) bool {
+	if key == "" || form == nil {
+		return false
+	}
+	return verifyHMAC(key, []byte(formValue(form, "timestamp")+formValue(form, "token")), formValue(form, "signature"))
+}
+
+// VerifyRawSignature confere a assinatura HMAC-SHA256 (hex, com ou sem prefixo "sha256=") do
+// corpo da mensagem enviada em formato raw
+func VerifyRawSignature(secret string, body []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	return verifyHMAC(secret, body, strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+}
+
+func verifyHMAC(secret string, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func formValue(form *multipart.Form, name string) string {
+	if values := form.Value[name]; len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
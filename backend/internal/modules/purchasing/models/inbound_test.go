@@ -0,0 +1,100 @@
+package models
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRawEmail(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(testNFe))
+	var wrapped strings.Builder
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded)
+
+	raw := "From: =?UTF-8?Q?Fornecedor_Jo=C3=A3o?= <nfe@fornecedor.com.br>\r\n" +
+		"Subject: =?UTF-8?Q?NF-e_1234_emiss=C3=A3o?=\r\n" +
+		"Message-Id: <abc@fornecedor.com.br>\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"externo\"\r\n\r\n" +
+		"--externo\r\n" +
+		"Content-Type: multipart/alternative; boundary=\"interno\"\r\n\r\n" +
+		"--interno\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\nSegue a nota.\r\n--interno--\r\n" +
+		"--externo\r\n" +
+		"Content-Type: application/xml; name=\"nota.xml\"\r\n" +
+		"Content-Disposition: attachment; filename=\"nota.xml\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\n" + wrapped.String() + "\r\n" +
+		"--externo\r\n" +
+		"Content-Type: application/pdf; name=\"danfe.pdf\"\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n%PDF-1.4 =C3=A9\r\n" +
+		"--externo--\r\n"
+
+	email, err := ParseRawEmail([]byte(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "nfe@fornecedor.com.br", email.From)
+	assert.Equal(t, "NF-e 1234 emissão", email.Subject)
+	assert.Equal(t, "<abc@fornecedor.com.br>", email.MessageID)
+	require.Len(t, email.Attachments, 2, "o corpo em texto não é anexo")
+
+	assert.Equal(t, "nota.xml", email.Attachments[0].FileName)
+	assert.True(t, email.Attachments[0].IsXML())
+	assert.Equal(t, testNFe, string(email.Attachments[0].Content))
+
+	assert.Equal(t, "danfe.pdf", email.Attachments[1].FileName)
+	assert.True(t, email.Attachments[1].IsPDF())
+	assert.Equal(t, "%PDF-1.4 é", string(email.Attachments[1].Content))
+}
+
+func TestVerifySignatures(t *testing.T) {
+	sign := func(secret, payload string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	body := []byte("From: a@b.c\r\n\r\n")
+	assert.True(t, VerifyRawSignature("segredo", body, "sha256="+sign("segredo", string(body))))
+	assert.False(t, VerifyRawSignature("segredo", body, sign("outro", string(body))))
+	assert.False(t, VerifyRawSignature("", body, sign("", string(body))), "sem segredo configurado")
+
+	form := &multipart.Form{Value: map[string][]string{
+		"timestamp": {"1714650000"},
+		"token":     {"abc123"},
+		"signature": {sign("chave", "1714650000abc123")},
+	}}
+	assert.True(t, VerifyMailgunSignature("chave", form))
+	form.Value["token"] = []string{"outro"}
+	assert.False(t, VerifyMailgunSignature("chave", form))
+}
+
+func TestParseMailgunForm(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("sender", "nfe@fornecedor.com.br"))
+	require.NoError(t, writer.WriteField("subject", "NF-e 1234"))
+	part, err := writer.CreateFormFile("attachment-1", "nota.xml")
+	require.NoError(t, err)
+	part.Write([]byte(testNFe))
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+
+	email, err := ParseMailgunForm(form)
+	require.NoError(t, err)
+	assert.Equal(t, "nfe@fornecedor.com.br", email.From)
+	require.Len(t, email.Attachments, 1)
+	assert.Equal(t, "nota.xml", email.Attachments[0].FileName)
+	assert.Equal(t, testNFe, string(email.Attachments[0].Content))
+}
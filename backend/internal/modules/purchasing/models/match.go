@@ -0,0 +1,311 @@
+package models
+
+import (
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status das linhas da conferência de três vias
+const (
+	MatchLineOK          = "ok"
+	MatchLineDiscrepancy = "discrepancy"
+)
+
+// Decisões do comprador sobre as divergências da conferência
+const (
+	MatchDecisionAccept = "accept"
+	MatchDecisionReject = "reject"
+)
+
+// MatchTolerance é a diferença aceita, em percentual, entre a quantidade cobrada e a recebida
+// (e entre a recebida e a pedida) e entre o preço cobrado e o do pedido
+type MatchTolerance struct {
+	QuantityPercent money.Decimal `json:"quantity_percent"`
+	PricePercent    money.Decimal `json:"price_percent"`
+}
+
+// BillMatchLine é o resultado da conferência de um item do pedido cobrado na conta. As
+// quantidades ficam na unidade do pedido de compra; a cobrada soma as outras contas do mesmo
+// pedido, para que a mesma entrega não seja paga duas vezes.
+type BillMatchLine struct {
+	ID              int           `json:"id" gorm:"primaryKey"`
+	CompanyID       int           `json:"company_id" gorm:"<-:create"`
+	SupplierBillID  int           `json:"supplier_bill_id"`
+	POItemID        *int          `json:"po_item_id,omitempty" gorm:"column:po_item_id"`
+	BillItemID      *int          `json:"bill_item_id,omitempty"`
+	ProductCode     string        `json:"product_code"`
+	Description     string        `json:"description"`
+	OrderedQty      money.Decimal `json:"ordered_qty"`
+	ReceivedQty     money.Decimal `json:"received_qty"`
+	BilledQty       money.Decimal `json:"billed_qty"`
+	POUnitPrice     money.Decimal `json:"po_unit_price" gorm:"column:po_unit_price"`
+	BilledUnitPrice money.Decimal `json:"billed_unit_price"`
+	Status          string        `json:"status"`
+	Message         string        `json:"message,omitempty"`
+}
+
+func (BillMatchLine) TableName() string {
+	return "supplier_bill_match_lines"
+}
+
+// MatchResult é a conferência de três vias de uma conta a pagar
+type MatchResult struct {
+	SupplierBillID  int             `json:"supplier_bill_id"`
+	PurchaseOrderID int             `json:"purchase_order_id"`
+	Status          string          `json:"status"`
+	Tolerance       MatchTolerance  `json:"tolerance"`
+	MatchedAt       *time.Time      `json:"matched_at,omitempty"`
+	ReviewedBy      string          `json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time      `json:"reviewed_at,omitempty"`
+	Notes           string          `json:"notes,omitempty"`
+	Lines           []BillMatchLine `json:"lines"`
+}
+
+// ReceiveInput registra as quantidades recebidas de uma entrega de pedido de compra, em unidades
+// de estoque
+type ReceiveInput struct {
+	Items []ReceiveItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// ReceiveItem é a quantidade recebida de um item da entrega
+type ReceiveItem struct {
+	DeliveryItemID int `json:"delivery_item_id" binding:"required"`
+	ReceivedQty    int `json:"received_qty" binding:"min=0"`
+}
+
+// LinkBillItemInput liga o item da conta ao item do pedido; nulo desfaz o vínculo
+type LinkBillItemInput struct {
+	POItemID *int `json:"po_item_id"`
+}
+
+// ReviewMatchInput é a decisão do comprador sobre as divergências da conferência
+type ReviewMatchInput struct {
+	Decision string `json:"decision" binding:"required,oneof=accept reject"`
+	Notes    string `json:"notes"`
+}
+
+// ReceivingLine é a posição de um item do pedido de compra: pedido, recebido, cobrado e
+// pendente de recebimento, na unidade do pedido
+type ReceivingLine struct {
+	POItemID    int           `json:"po_item_id"`
+	ProductID   int           `json:"product_id"`
+	ProductCode string        `json:"product_code"`
+	Description string        `json:"description"`
+	Unit        string        `json:"unit"`
+	OrderedQty  money.Decimal `json:"ordered_qty"`
+	ReceivedQty money.Decimal `json:"received_qty"`
+	BilledQty   money.Decimal `json:"billed_qty"`
+	PendingQty  money.Decimal `json:"pending_qty"`
+}
+
+// AllocateReceipts distribui o recebido de cada produto (em unidades de estoque) entre os itens
+// do pedido com esse produto, na ordem dos itens; o que passar do pedido fica no último item do
+// produto. Retorna as quantidades por item do pedido, em unidades de estoque.
+func AllocateReceipts(items []sales.POItem, received map[int]int) map[int]int {
+	sorted := append([]sales.POItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	remaining := make(map[int]int, len(received))
+	for productID, quantity := range received {
+		remaining[productID] = quantity
+	}
+	last := map[int]int{}
+	allocated := map[int]int{}
+	for _, item := range sorted {
+		quantity := min(remaining[item.ProductID], item.BaseQuantity())
+		allocated[item.ID] = quantity
+		remaining[item.ProductID] -= quantity
+		last[item.ProductID] = item.ID
+	}
+	for productID, id := range last {
+		if remaining[productID] > 0 {
+			allocated[id] += remaining[productID]
+		}
+	}
+	return allocated
+}
+
+// LinkBillItems liga os itens da conta aos itens do pedido pelo código do produto (o cProd da
+// NF-e igual ao código do item do pedido). Os itens sem correspondência ficam sem vínculo e
+// aparecem como divergência até serem ligados manualmente.
+func LinkBillItems(items []sales.SupplierBillItem, poItems []sales.POItem) {
+	byCode := make(map[string]int, len(poItems))
+	for _, poItem := range poItems {
+		code := strings.ToUpper(strings.TrimSpace(poItem.ProductCode))
+		if _, ok := byCode[code]; !ok && code != "" {
+			byCode[code] = poItem.ID
+		}
+	}
+	for i := range items {
+		if id, ok := byCode[strings.ToUpper(strings.TrimSpace(items[i].ProductCode))]; ok {
+			items[i].POItemID = &id
+		}
+	}
+}
+
+// ThreeWayMatch confere a conta com o pedido de compra e o recebimento. received é o recebido por
+// item do pedido em unidades de estoque (ver AllocateReceipts) e billedElsewhere a quantidade já
+// cobrada do item em outras contas do pedido, na unidade do pedido. Há divergência quando o
+// cobrado passa do recebido, o recebido passa do pedido ou o preço cobrado difere do pedido além
+// da tolerância, e quando um item da conta não está ligado a um item do pedido.
+func ThreeWayMatch(po *sales.PurchaseOrder, bill *sales.SupplierBill, received map[int]int, billedElsewhere map[int]money.Decimal, tolerance MatchTolerance) MatchResult {
+	result := MatchResult{
+		SupplierBillID:  bill.ID,
+		PurchaseOrderID: po.ID,
+		Status:          sales.BillMatchMatched,
+		Tolerance:       tolerance,
+		Lines:           []BillMatchLine{},
+	}
+
+	poItems := make(map[int]sales.POItem, len(po.Items))
+	for _, item := range po.Items {
+		poItems[item.ID] = item
+	}
+
+	// Itens da conta agrupados pelo item do pedido, na ordem em que aparecem
+	var order []int
+	billed := map[int][]sales.SupplierBillItem{}
+	for _, item := range bill.Items {
+		if item.POItemID == nil {
+			result.Lines = append(result.Lines, unlinkedLine(item, "item da conta sem item do pedido correspondente"))
+			continue
+		}
+		if _, ok := poItems[*item.POItemID]; !ok {
+			result.Lines = append(result.Lines, unlinkedLine(item, "item do pedido ligado não pertence ao pedido da conta"))
+			continue
+		}
+		if _, ok := billed[*item.POItemID]; !ok {
+			order = append(order, *item.POItemID)
+		}
+		billed[*item.POItemID] = append(billed[*item.POItemID], item)
+	}
+
+	for _, poItemID := range order {
+		result.Lines = append(result.Lines, matchLine(poItems[poItemID], billed[poItemID], received[poItemID], billedElsewhere[poItemID], tolerance))
+	}
+
+	if len(bill.Items) == 0 {
+		result.Lines = append(result.Lines, BillMatchLine{
+			SupplierBillID: bill.ID,
+			Status:         MatchLineDiscrepancy,
+			Message:        "conta sem itens para conferir com o pedido",
+		})
+	}
+	for _, line := range result.Lines {
+		if line.Status == MatchLineDiscrepancy {
+			result.Status = sales.BillMatchDiscrepancy
+			break
+		}
+	}
+	return result
+}
+
+func unlinkedLine(item sales.SupplierBillItem, message string) BillMatchLine {
+	id := item.ID
+	return BillMatchLine{
+		SupplierBillID:  item.SupplierBillID,
+		BillItemID:      &id,
+		ProductCode:     item.ProductCode,
+		Description:     item.Description,
+		BilledQty:       item.Quantity,
+		BilledUnitPrice: item.UnitPrice,
+		Status:          MatchLineDiscrepancy,
+		Message:         message,
+	}
+}
+
+// matchLine confere os itens da conta ligados a um item do pedido
+func matchLine(poItem sales.POItem, items []sales.SupplierBillItem, receivedBase int, billedElsewhere money.Decimal, tolerance MatchTolerance) BillMatchLine {
+	quantity, total := money.Zero, money.Zero
+	for _, item := range items {
+		quantity = quantity.Add(item.Quantity)
+		total = total.Add(item.UnitPrice.Mul(item.Quantity))
+	}
+
+	poItemID, billItemID := poItem.ID, items[0].ID
+	line := BillMatchLine{
+		SupplierBillID:  items[0].SupplierBillID,
+		POItemID:        &poItemID,
+		BillItemID:      &billItemID,
+		ProductCode:     poItem.ProductCode,
+		Description:     poItem.Description,
+		OrderedQty:      money.FromInt(int64(poItem.Quantity)),
+		ReceivedQty:     toPOUnit(receivedBase, poItem.UnitFactor),
+		BilledQty:       quantity.Add(billedElsewhere),
+		POUnitPrice:     poItem.UnitPrice,
+		BilledUnitPrice: total.Div(quantity).Round(4),
+		Status:          MatchLineOK,
+	}
+	if line.Description == "" {
+		line.Description = poItem.ProductName
+	}
+
+	var problems []string
+	if line.BilledQty.GreaterThan(withTolerance(line.ReceivedQty, tolerance.QuantityPercent)) {
+		problems = append(problems, fmt.Sprintf("cobrado %s acima do recebido %s", line.BilledQty.String(), line.ReceivedQty.String()))
+	}
+	if line.ReceivedQty.GreaterThan(withTolerance(line.OrderedQty, tolerance.QuantityPercent)) {
+		problems = append(problems, fmt.Sprintf("recebido %s acima do pedido %s", line.ReceivedQty.String(), line.OrderedQty.String()))
+	}
+	allowed := line.POUnitPrice.Mul(tolerance.PricePercent).DivInt(100)
+	if line.BilledUnitPrice.Sub(line.POUnitPrice).Abs().GreaterThan(allowed) {
+		problems = append(problems, fmt.Sprintf("preço cobrado %s difere do pedido %s", line.BilledUnitPrice.StringFixed(2), line.POUnitPrice.StringFixed(2)))
+	}
+	if len(problems) > 0 {
+		line.Status = MatchLineDiscrepancy
+		line.Message = strings.Join(problems, "; ")
+	}
+	return line
+}
+
+// BuildReceivingLines monta a posição de recebimento e cobrança de cada item do pedido. received
+// está em unidades de estoque por item (ver AllocateReceipts) e billed na unidade do pedido.
+func BuildReceivingLines(po *sales.PurchaseOrder, received map[int]int, billed map[int]money.Decimal) []ReceivingLine {
+	lines := make([]ReceivingLine, 0, len(po.Items))
+	for _, item := range po.Items {
+		line := ReceivingLine{
+			POItemID:    item.ID,
+			ProductID:   item.ProductID,
+			ProductCode: item.ProductCode,
+			Description: item.Description,
+			Unit:        item.Unit,
+			OrderedQty:  money.FromInt(int64(item.Quantity)),
+			ReceivedQty: toPOUnit(received[item.ID], item.UnitFactor),
+			BilledQty:   billed[item.ID],
+		}
+		if line.Description == "" {
+			line.Description = item.ProductName
+		}
+		line.PendingQty = money.Max(line.OrderedQty.Sub(line.ReceivedQty), money.Zero)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// FullyReceived indica se todos os itens do pedido foram recebidos por completo
+func FullyReceived(po *sales.PurchaseOrder, received map[int]int) bool {
+	if len(po.Items) == 0 {
+		return false
+	}
+	for _, item := range po.Items {
+		if received[item.ID] < item.BaseQuantity() {
+			return false
+		}
+	}
+	return true
+}
+
+func toPOUnit(base, factor int) money.Decimal {
+	if factor <= 1 {
+		return money.FromInt(int64(base))
+	}
+	return money.FromInt(int64(base)).DivInt(factor)
+}
+
+func withTolerance(quantity, percent money.Decimal) money.Decimal {
+	return quantity.Add(quantity.Mul(percent).DivInt(100))
+}
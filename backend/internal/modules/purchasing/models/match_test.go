@@ -0,0 +1,106 @@
+package models
+
+import (
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func matchOrder() *sales.PurchaseOrder {
+	return &sales.PurchaseOrder{ID: 31, Items: []sales.POItem{
+		{ID: 1, ProductID: 7, ProductCode: "PAR-10", Quantity: 10, UnitFactor: 1, UnitPrice: money.FromInt(50)},
+		{ID: 2, ProductID: 8, ProductCode: "BUC-06", Quantity: 4, UnitFactor: 12, UnitPrice: money.FromInt(120)},
+	}}
+}
+
+func billItem(id int, poItemID *int, quantity, price int64) sales.SupplierBillItem {
+	return sales.SupplierBillItem{ID: id, SupplierBillID: 5, POItemID: poItemID, Quantity: money.FromInt(quantity), UnitPrice: money.FromInt(price)}
+}
+
+var defaultTolerance = MatchTolerance{QuantityPercent: money.Zero, PricePercent: money.FromInt(2)}
+
+func TestAllocateReceipts(t *testing.T) {
+	items := []sales.POItem{
+		{ID: 3, ProductID: 7, Quantity: 5, UnitFactor: 1},
+		{ID: 1, ProductID: 7, Quantity: 10, UnitFactor: 1},
+		{ID: 2, ProductID: 8, Quantity: 2, UnitFactor: 12},
+	}
+	allocated := AllocateReceipts(items, map[int]int{7: 18, 8: 12})
+	assert.Equal(t, 10, allocated[1], "primeiro item do produto recebe primeiro")
+	assert.Equal(t, 8, allocated[3], "o excedente fica no último item do produto")
+	assert.Equal(t, 12, allocated[2])
+}
+
+func TestLinkBillItems(t *testing.T) {
+	items := []sales.SupplierBillItem{{ProductCode: " par-10"}, {ProductCode: "XYZ"}}
+	LinkBillItems(items, matchOrder().Items)
+	assert.Equal(t, 1, *items[0].POItemID)
+	assert.Nil(t, items[1].POItemID)
+}
+
+func TestThreeWayMatchWithinTolerance(t *testing.T) {
+	bill := &sales.SupplierBill{ID: 5, Items: []sales.SupplierBillItem{
+		billItem(11, intPtr(1), 10, 51),
+		billItem(12, intPtr(2), 2, 120),
+	}}
+	// 10 parafusos e 24 unidades (2 caixas de 12) de bucha recebidas
+	result := ThreeWayMatch(matchOrder(), bill, map[int]int{1: 10, 2: 24}, nil, defaultTolerance)
+
+	assert.Equal(t, sales.BillMatchMatched, result.Status)
+	require.Len(t, result.Lines, 2)
+	assert.Equal(t, MatchLineOK, result.Lines[0].Status, "preço 2% acima do pedido está na tolerância")
+	assert.Equal(t, "2.00", result.Lines[1].ReceivedQty.String(), "recebido na unidade do pedido")
+	assert.Equal(t, "4.00", result.Lines[1].OrderedQty.String())
+}
+
+func TestThreeWayMatchDiscrepancies(t *testing.T) {
+	bill := &sales.SupplierBill{ID: 5, Items: []sales.SupplierBillItem{
+		billItem(11, intPtr(1), 6, 50),
+		billItem(12, intPtr(2), 1, 130),
+		billItem(13, nil, 1, 10),
+	}}
+	// Outra conta já cobrou 5 parafusos; só 10 foram recebidos
+	billed := map[int]money.Decimal{1: money.FromInt(5)}
+	result := ThreeWayMatch(matchOrder(), bill, map[int]int{1: 10, 2: 12}, billed, defaultTolerance)
+
+	assert.Equal(t, sales.BillMatchDiscrepancy, result.Status)
+	require.Len(t, result.Lines, 3)
+	assert.Equal(t, MatchLineDiscrepancy, result.Lines[0].Status, "item sem vínculo com o pedido")
+	assert.Equal(t, 13, *result.Lines[0].BillItemID)
+
+	assert.Equal(t, "11.00", result.Lines[1].BilledQty.String(), "soma as outras contas do pedido")
+	assert.Contains(t, result.Lines[1].Message, "acima do recebido")
+
+	assert.Contains(t, result.Lines[2].Message, "preço cobrado 130.00 difere do pedido 120.00")
+	assert.NotContains(t, result.Lines[2].Message, "recebido")
+}
+
+func TestThreeWayMatchQuantityTolerance(t *testing.T) {
+	bill := &sales.SupplierBill{ID: 5, Items: []sales.SupplierBillItem{billItem(11, intPtr(1), 11, 50)}}
+	received := map[int]int{1: 10}
+
+	result := ThreeWayMatch(matchOrder(), bill, received, nil, defaultTolerance)
+	assert.Equal(t, sales.BillMatchDiscrepancy, result.Status)
+
+	tolerance := MatchTolerance{QuantityPercent: money.FromInt(10), PricePercent: money.Zero}
+	result = ThreeWayMatch(matchOrder(), bill, received, nil, tolerance)
+	assert.Equal(t, sales.BillMatchMatched, result.Status, "11 cobrados de 10 recebidos com 10% de tolerância")
+
+	empty := ThreeWayMatch(matchOrder(), &sales.SupplierBill{ID: 6}, received, nil, tolerance)
+	assert.Equal(t, sales.BillMatchDiscrepancy, empty.Status, "conta sem itens não confere")
+}
+
+func TestBuildReceivingLines(t *testing.T) {
+	po := matchOrder()
+	lines := BuildReceivingLines(po, map[int]int{1: 4, 2: 48}, map[int]money.Decimal{1: money.FromInt(4)})
+	require.Len(t, lines, 2)
+	assert.Equal(t, "6.00", lines[0].PendingQty.String())
+	assert.Equal(t, "4.00", lines[0].BilledQty.String())
+	assert.Equal(t, "0.00", lines[1].PendingQty.String())
+
+	assert.False(t, FullyReceived(po, map[int]int{1: 4, 2: 48}))
+	assert.True(t, FullyReceived(po, map[int]int{1: 10, 2: 48}))
+}
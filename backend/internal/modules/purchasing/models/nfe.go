@@ -0,0 +1,232 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NFe reúne os dados da nota fiscal eletrônica usados na conta a pagar
+type NFe struct {
+	// Chave de acesso (44 dígitos)
+	Key    string `json:"key"`
+	Number string `json:"number"`
+	Series string `json:"series"`
+	// CNPJ (ou CPF) do emitente, o fornecedor, e do destinatário, a empresa
+	IssuerDocument    string    `json:"issuer_document"`
+	IssuerName        string    `json:"issuer_name"`
+	RecipientDocument string    `json:"recipient_document"`
+	IssueDate         time.Time `json:"issue_date"`
+	// Primeiro vencimento das duplicatas; vazio quando a nota não traz cobrança
+	DueDate *time.Time `json:"due_date,omitempty"`
+	// Número do pedido de compra informado pelo fornecedor (xPed)
+	PurchaseOrderNo string        `json:"purchase_order_no,omitempty"`
+	Items           []NFeItem     `json:"items"`
+	SubTotal        money.Decimal `json:"subtotal"`
+	TaxTotal        money.Decimal `json:"tax_total"`
+	GrandTotal      money.Decimal `json:"grand_total"`
+}
+
+// NFeItem é um item (det/prod) da nota
+type NFeItem struct {
+	Code      string        `json:"code"`
+	Name      string        `json:"name"`
+	Unit      string        `json:"unit"`
+	Quantity  float64       `json:"quantity"`
+	UnitPrice money.Decimal `json:"unit_price"`
+	Total     money.Decimal `json:"total"`
+}
+
+// infNFe espelha o trecho do leiaute 4.00 lido na importação; as tags valem com ou sem o
+// namespace do portal da NF-e
+type infNFe struct {
+	ID  string `xml:"Id,attr"`
+	Ide struct {
+		Number string `xml:"nNF"`
+		Series string `xml:"serie"`
+		DhEmi  string `xml:"dhEmi"`
+		DEmi   string `xml:"dEmi"`
+	} `xml:"ide"`
+	Emit struct {
+		CNPJ string `xml:"CNPJ"`
+		CPF  string `xml:"CPF"`
+		Name string `xml:"xNome"`
+	} `xml:"emit"`
+	Dest struct {
+		CNPJ string `xml:"CNPJ"`
+		CPF  string `xml:"CPF"`
+	} `xml:"dest"`
+	Det []struct {
+		Prod struct {
+			Code      string `xml:"cProd"`
+			Name      string `xml:"xProd"`
+			Unit      string `xml:"uCom"`
+			Quantity  string `xml:"qCom"`
+			UnitPrice string `xml:"vUnCom"`
+			Total     string `xml:"vProd"`
+			Order     string `xml:"xPed"`
+		} `xml:"prod"`
+	} `xml:"det"`
+	Total struct {
+		ICMSTot struct {
+			ST      string `xml:"vST"`
+			IPI     string `xml:"vIPI"`
+			Invoice string `xml:"vNF"`
+		} `xml:"ICMSTot"`
+	} `xml:"total"`
+	Cobr struct {
+		Dup []struct {
+			DueDate string `xml:"dVenc"`
+		} `xml:"dup"`
+	} `xml:"cobr"`
+	Compra struct {
+		Order string `xml:"xPed"`
+	} `xml:"compra"`
+}
+
+// ParseNFe lê o XML da NF-e (nfeProc autorizada ou NFe avulsa). Os impostos somados ao total
+// da nota (IPI e ICMS-ST) formam o TaxTotal; o restante do valor da nota é o SubTotal.
+func ParseNFe(data []byte) (*NFe, error) {
+	info, err := decodeInfNFe(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.TrimPrefix(strings.TrimSpace(info.ID), "NFe")
+	if len(key) != 44 || strings.Trim(key, "0123456789") != "" {
+		return nil, fmt.Errorf("%w: chave de acesso ausente ou inválida", errors.ErrInvalidNFe)
+	}
+
+	issued := strings.TrimSpace(info.Ide.DhEmi)
+	if issued == "" {
+		issued = strings.TrimSpace(info.Ide.DEmi)
+	}
+	issueDate, err := parseNFeDate(issued)
+	if err != nil {
+		return nil, fmt.Errorf("%w: data de emissão inválida", errors.ErrInvalidNFe)
+	}
+
+	nfe := &NFe{
+		Key:               key,
+		Number:            strings.TrimSpace(info.Ide.Number),
+		Series:            strings.TrimSpace(info.Ide.Series),
+		IssuerDocument:    firstNonEmpty(info.Emit.CNPJ, info.Emit.CPF),
+		IssuerName:        strings.TrimSpace(info.Emit.Name),
+		RecipientDocument: firstNonEmpty(info.Dest.CNPJ, info.Dest.CPF),
+		IssueDate:         issueDate,
+		PurchaseOrderNo:   strings.TrimSpace(info.Compra.Order),
+	}
+	if nfe.IssuerDocument == "" {
+		return nil, fmt.Errorf("%w: emitente sem CNPJ/CPF", errors.ErrInvalidNFe)
+	}
+
+	for _, det := range info.Det {
+		item := NFeItem{
+			Code: strings.TrimSpace(det.Prod.Code),
+			Name: strings.TrimSpace(det.Prod.Name),
+			Unit: strings.TrimSpace(det.Prod.Unit),
+		}
+		if item.Quantity, err = parseNFeDecimal(det.Prod.Quantity); err == nil {
+			if item.UnitPrice, err = parseNFeAmount(det.Prod.UnitPrice); err == nil {
+				item.Total, err = parseNFeAmount(det.Prod.Total)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: valores do item %q", errors.ErrInvalidNFe, item.Code)
+		}
+		if nfe.PurchaseOrderNo == "" {
+			nfe.PurchaseOrderNo = strings.TrimSpace(det.Prod.Order)
+		}
+		nfe.Items = append(nfe.Items, item)
+	}
+	if len(nfe.Items) == 0 {
+		return nil, fmt.Errorf("%w: nota sem itens", errors.ErrInvalidNFe)
+	}
+
+	totals := info.Total.ICMSTot
+	grand, err := parseNFeAmount(totals.Invoice)
+	if err != nil || !grand.IsPositive() {
+		return nil, fmt.Errorf("%w: valor total da nota inválido", errors.ErrInvalidNFe)
+	}
+	st, _ := parseNFeAmount(totals.ST)
+	ipi, _ := parseNFeAmount(totals.IPI)
+	nfe.GrandTotal = money.Round(grand)
+	nfe.TaxTotal = money.Round(st.Add(ipi))
+	nfe.SubTotal = nfe.GrandTotal.Sub(nfe.TaxTotal)
+
+	for _, dup := range info.Cobr.Dup {
+		due, err := parseNFeDate(dup.DueDate)
+		if err != nil {
+			continue
+		}
+		if nfe.DueDate == nil || due.Before(*nfe.DueDate) {
+			nfe.DueDate = &due
+		}
+	}
+	return nfe, nil
+}
+
+// decodeInfNFe procura o elemento infNFe em qualquer nível do XML
+func decodeInfNFe(data []byte) (*infNFe, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: elemento infNFe não encontrado", errors.ErrInvalidNFe)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNFe, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "infNFe" {
+			continue
+		}
+
+		var info infNFe
+		if err := decoder.DecodeElement(&info, &start); err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidNFe, err)
+		}
+		return &info, nil
+	}
+}
+
+// parseNFeDate aceita data e hora com fuso (dhEmi) ou só a data (dEmi, dVenc)
+func parseNFeDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func parseNFeDecimal(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseNFeAmount lê um valor da nota (vUnCom, vProd, vNF) sem passar por float64
+func parseNFeAmount(value string) (money.Decimal, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return money.Zero, nil
+	}
+	return money.Parse(value)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
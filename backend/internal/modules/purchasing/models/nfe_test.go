@@ -0,0 +1,73 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNFeKey = "35260512345678000190550010000012341000012345"
+
+const testNFe = `<?xml version="1.0" encoding="UTF-8"?>
+<nfeProc xmlns="http://www.portalfiscal.inf.br/nfe" versao="4.00">
+  <NFe>
+    <infNFe Id="NFe` + testNFeKey + `" versao="4.00">
+      <ide><serie>1</serie><nNF>1234</nNF><dhEmi>2026-05-02T10:30:00-03:00</dhEmi></ide>
+      <emit><CNPJ>12345678000190</CNPJ><xNome>Parafusos Brasil Ltda</xNome></emit>
+      <dest><CNPJ>98765432000110</CNPJ></dest>
+      <det nItem="1">
+        <prod><cProd>PAR-10</cProd><xProd>Parafuso 10mm</xProd><uCom>CX</uCom><qCom>10.0000</qCom>
+          <vUnCom>50.00</vUnCom><vProd>500.00</vProd><xPed>PO-2026-000031</xPed></prod>
+      </det>
+      <det nItem="2">
+        <prod><cProd>POR-10</cProd><xProd>Porca 10mm</xProd><uCom>CX</uCom><qCom>5</qCom>
+          <vUnCom>20.00</vUnCom><vProd>100.00</vProd></prod>
+      </det>
+      <total><ICMSTot><vProd>600.00</vProd><vST>12.50</vST><vIPI>30.00</vIPI><vNF>662.50</vNF></ICMSTot></total>
+      <cobr>
+        <dup><nDup>002</nDup><dVenc>2026-07-01</dVenc><vDup>331.25</vDup></dup>
+        <dup><nDup>001</nDup><dVenc>2026-06-01</dVenc><vDup>331.25</vDup></dup>
+      </cobr>
+    </infNFe>
+  </NFe>
+  <protNFe versao="4.00"><infProt><chNFe>` + testNFeKey + `</chNFe></infProt></protNFe>
+</nfeProc>`
+
+func TestParseNFe(t *testing.T) {
+	nfe, err := ParseNFe([]byte(testNFe))
+	require.NoError(t, err)
+
+	assert.Equal(t, testNFeKey, nfe.Key)
+	assert.Equal(t, "1234", nfe.Number)
+	assert.Equal(t, "1", nfe.Series)
+	assert.Equal(t, "12345678000190", nfe.IssuerDocument)
+	assert.Equal(t, "98765432000110", nfe.RecipientDocument)
+	assert.Equal(t, "PO-2026-000031", nfe.PurchaseOrderNo, "xPed do item")
+	assert.True(t, nfe.IssueDate.Equal(time.Date(2026, 5, 2, 13, 30, 0, 0, time.UTC)))
+	require.NotNil(t, nfe.DueDate)
+	assert.Equal(t, "2026-06-01", nfe.DueDate.Format("2006-01-02"), "primeiro vencimento")
+	require.Len(t, nfe.Items, 2)
+	assert.Equal(t, NFeItem{Code: "PAR-10", Name: "Parafuso 10mm", Unit: "CX", Quantity: 10, UnitPrice: money.FromInt(50), Total: money.FromInt(500)}, nfe.Items[0])
+	assert.Equal(t, "662.50", nfe.GrandTotal.String())
+	assert.Equal(t, "42.50", nfe.TaxTotal.String())
+	assert.Equal(t, "620.00", nfe.SubTotal.String())
+}
+
+func TestParseNFeRejectsInvalidDocuments(t *testing.T) {
+	cases := map[string]string{
+		"não é XML":      "%PDF-1.4",
+		"sem infNFe":     `<nfeProc><NFe/></nfeProc>`,
+		"chave inválida": `<NFe><infNFe Id="NFe123"><ide><dhEmi>2026-05-02T10:30:00-03:00</dhEmi></ide></infNFe></NFe>`,
+		"sem itens": `<NFe><infNFe Id="NFe` + testNFeKey + `"><ide><dhEmi>2026-05-02T10:30:00-03:00</dhEmi></ide>` +
+			`<emit><CNPJ>12345678000190</CNPJ></emit><total><ICMSTot><vNF>10.00</vNF></ICMSTot></total></infNFe></NFe>`,
+	}
+	for name, xml := range cases {
+		_, err := ParseNFe([]byte(xml))
+		assert.True(t, stderrors.Is(err, errors.ErrInvalidNFe), name)
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"sort"
+)
+
+// ReorderCandidate reúne a posição de estoque de um produto usada no cálculo da reposição.
+// As quantidades e o custo são da unidade de estoque.
+type ReorderCandidate struct {
+	CompanyID       int           `json:"company_id"`
+	ProductID       int           `json:"product_id"`
+	ProductName     string        `json:"product_name"`
+	SKU             string        `json:"sku"`
+	Stock           int           `json:"stock"`
+	ReorderPoint    int           `json:"reorder_point"`
+	ReorderQuantity int           `json:"reorder_quantity"`
+	SupplierID      *int          `json:"supplier_id,omitempty"`
+	SupplierName    string        `json:"supplier_name,omitempty"`
+	UnitCost        money.Decimal `json:"unit_cost"`
+	// Quantidade reservada por pedidos de venda em aberto e ainda não entregue
+	Committed int `json:"committed"`
+	// Quantidade já pedida aos fornecedores em pedidos de compra abertos
+	OnOrder int `json:"on_order"`
+	// Unidade padrão de compra e quantas unidades de estoque ela contém
+	PurchaseUnit   string `json:"purchase_unit"`
+	PurchaseFactor int    `json:"purchase_factor"`
+}
+
+// Projected retorna o estoque projetado: físico - comprometido + em pedido
+func (c ReorderCandidate) Projected() int {
+	return c.Stock - c.Committed + c.OnOrder
+}
+
+// NeedsReorder indica se o estoque projetado atingiu o ponto de reposição
+// ou não cobre os pedidos de venda em aberto
+func (c ReorderCandidate) NeedsReorder() bool {
+	projected := c.Projected()
+	if projected < 0 {
+		return true
+	}
+	return c.ReorderPoint > 0 && projected <= c.ReorderPoint
+}
+
+// OrderQuantity calcula a quantidade a comprar para levar o estoque projetado
+// ao ponto de reposição acrescido do lote de reposição
+func (c ReorderCandidate) OrderQuantity() int {
+	quantity := c.ReorderPoint + c.ReorderQuantity - c.Projected()
+	if quantity < 1 {
+		return 1
+	}
+	return quantity
+}
+
+// SuggestionLine é um item proposto para compra. Estoque, comprometido, em pedido e ponto de
+// reposição ficam em unidades de estoque; a quantidade e o custo, na unidade de compra.
+type SuggestionLine struct {
+	ProductID    int           `json:"product_id"`
+	ProductName  string        `json:"product_name"`
+	SKU          string        `json:"sku"`
+	Stock        int           `json:"stock"`
+	Committed    int           `json:"committed"`
+	OnOrder      int           `json:"on_order"`
+	ReorderPoint int           `json:"reorder_point"`
+	Quantity     int           `json:"quantity"`
+	Unit         string        `json:"unit"`
+	UnitFactor   int           `json:"unit_factor"`
+	UnitCost     money.Decimal `json:"unit_cost"`
+	Total        money.Decimal `json:"total"`
+}
+
+// SupplierSuggestion agrupa as linhas sugeridas de um mesmo fornecedor
+type SupplierSuggestion struct {
+	SupplierID      int              `json:"supplier_id"`
+	SupplierName    string           `json:"supplier_name"`
+	Lines           []SuggestionLine `json:"lines"`
+	Total           money.Decimal    `json:"total"`
+	PurchaseOrderID int              `json:"purchase_order_id,omitempty"`
+	PONo            string           `json:"po_no,omitempty"`
+}
+
+// GenerationResult resume uma execução da geração de sugestões
+type GenerationResult struct {
+	Scanned    int                  `json:"scanned"`
+	Suggested  []SupplierSuggestion `json:"suggested"`
+	Unassigned []SuggestionLine     `json:"unassigned"`
+}
+
+// BuildSuggestions seleciona os produtos abaixo do ponto de reposição e agrupa as linhas
+// por fornecedor preferencial. Produtos sem fornecedor são devolvidos à parte,
+// para que o comprador defina o fornecedor manualmente.
+func BuildSuggestions(candidates []ReorderCandidate) ([]SupplierSuggestion, []SuggestionLine) {
+	bySupplier := make(map[int]*SupplierSuggestion)
+	var unassigned []SuggestionLine
+
+	for _, candidate := range candidates {
+		if !candidate.NeedsReorder() {
+			continue
+		}
+
+		// A quantidade em unidades de estoque é arredondada para cima na unidade de compra
+		factor := max(candidate.PurchaseFactor, 1)
+		quantity := product.FromBase(candidate.OrderQuantity(), factor)
+		unitCost := candidate.UnitCost.MulInt(factor)
+		line := SuggestionLine{
+			ProductID:    candidate.ProductID,
+			ProductName:  candidate.ProductName,
+			SKU:          candidate.SKU,
+			Stock:        candidate.Stock,
+			Committed:    candidate.Committed,
+			OnOrder:      candidate.OnOrder,
+			ReorderPoint: candidate.ReorderPoint,
+			Quantity:     quantity,
+			Unit:         candidate.PurchaseUnit,
+			UnitFactor:   factor,
+			UnitCost:     unitCost,
+			Total:        money.Round(unitCost.MulInt(quantity)),
+		}
+
+		if candidate.SupplierID == nil {
+			unassigned = append(unassigned, line)
+			continue
+		}
+
+		suggestion, ok := bySupplier[*candidate.SupplierID]
+		if !ok {
+			suggestion = &SupplierSuggestion{
+				SupplierID:   *candidate.SupplierID,
+				SupplierName: candidate.SupplierName,
+			}
+			bySupplier[*candidate.SupplierID] = suggestion
+		}
+		suggestion.Lines = append(suggestion.Lines, line)
+		suggestion.Total = suggestion.Total.Add(line.Total)
+	}
+
+	suggestions := make([]SupplierSuggestion, 0, len(bySupplier))
+	for _, suggestion := range bySupplier {
+		suggestions = append(suggestions, *suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].SupplierID < suggestions[j].SupplierID
+	})
+
+	return suggestions, unassigned
+}
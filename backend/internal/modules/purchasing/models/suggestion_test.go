@@ -0,0 +1,77 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestNeedsReorder(t *testing.T) {
+	assert.True(t, ReorderCandidate{Stock: 5, ReorderPoint: 5}.NeedsReorder())
+	assert.False(t, ReorderCandidate{Stock: 6, ReorderPoint: 5}.NeedsReorder())
+
+	// Pedidos de venda em aberto consomem o estoque projetado
+	assert.True(t, ReorderCandidate{Stock: 10, Committed: 6, ReorderPoint: 5}.NeedsReorder())
+	// Compras em andamento já cobrem a reposição
+	assert.False(t, ReorderCandidate{Stock: 10, Committed: 6, OnOrder: 5, ReorderPoint: 5}.NeedsReorder())
+
+	// Sem ponto de reposição, só sugere quando o estoque não cobre as vendas
+	assert.False(t, ReorderCandidate{Stock: 0}.NeedsReorder())
+	assert.True(t, ReorderCandidate{Stock: 2, Committed: 3}.NeedsReorder())
+}
+
+func TestOrderQuantity(t *testing.T) {
+	assert.Equal(t, 25, ReorderCandidate{Stock: 5, Committed: 5, ReorderPoint: 10, ReorderQuantity: 15}.OrderQuantity())
+	assert.Equal(t, 3, ReorderCandidate{Stock: 2, Committed: 5}.OrderQuantity())
+	assert.Equal(t, 1, ReorderCandidate{Stock: 5, ReorderPoint: 5}.OrderQuantity())
+}
+
+func TestBuildSuggestionsGroupsBySupplier(t *testing.T) {
+	candidates := []ReorderCandidate{
+		{ProductID: 1, Stock: 2, ReorderPoint: 5, ReorderQuantity: 3, SupplierID: intPtr(20), SupplierName: "Beta", UnitCost: money.FromInt(10)},
+		{ProductID: 2, Stock: 0, ReorderPoint: 2, SupplierID: intPtr(10), SupplierName: "Alfa", UnitCost: money.MustParse("2.5")},
+		{ProductID: 3, Stock: 1, ReorderPoint: 4, SupplierID: intPtr(20), SupplierName: "Beta", UnitCost: money.MustParse("1.1")},
+		{ProductID: 4, Stock: 50, ReorderPoint: 5, SupplierID: intPtr(10), SupplierName: "Alfa", UnitCost: money.FromInt(7)},
+		{ProductID: 5, Stock: 0, ReorderPoint: 1, UnitCost: money.FromInt(3)},
+	}
+
+	suggestions, unassigned := BuildSuggestions(candidates)
+	require.Len(t, suggestions, 2)
+
+	assert.Equal(t, 10, suggestions[0].SupplierID)
+	require.Len(t, suggestions[0].Lines, 1)
+	assert.Equal(t, 2, suggestions[0].Lines[0].Quantity)
+	assert.Equal(t, "5.00", suggestions[0].Total.String())
+
+	assert.Equal(t, 20, suggestions[1].SupplierID)
+	require.Len(t, suggestions[1].Lines, 2)
+	assert.Equal(t, 6, suggestions[1].Lines[0].Quantity)
+	assert.Equal(t, 3, suggestions[1].Lines[1].Quantity)
+	assert.Equal(t, "63.30", suggestions[1].Total.String())
+
+	require.Len(t, unassigned, 1)
+	assert.Equal(t, 5, unassigned[0].ProductID)
+}
+
+func TestBuildSuggestionsUsesPurchaseUnit(t *testing.T) {
+	candidates := []ReorderCandidate{
+		// Faltam 25 unidades: compra 3 caixas de 12
+		{ProductID: 1, Stock: 5, ReorderPoint: 10, ReorderQuantity: 20, SupplierID: intPtr(10), UnitCost: money.FromInt(2),
+			PurchaseUnit: "CX", PurchaseFactor: 12},
+	}
+
+	suggestions, _ := BuildSuggestions(candidates)
+	require.Len(t, suggestions, 1)
+	line := suggestions[0].Lines[0]
+	assert.Equal(t, 3, line.Quantity)
+	assert.Equal(t, "CX", line.Unit)
+	assert.Equal(t, 12, line.UnitFactor)
+	assert.Equal(t, "24.00", line.UnitCost.String())
+	assert.Equal(t, "72.00", line.Total.String())
+}
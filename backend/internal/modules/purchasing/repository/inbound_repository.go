@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	companies "ERP-ONSMART/backend/internal/modules/companies/models"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// digitsOnly compara documentos (CNPJ/CPF) gravados com ou sem máscara
+const digitsOnly = "regexp_replace(document, '[^0-9]', '', 'g') = ?"
+
+// InboundRepository define a importação das notas recebidas por e-mail e a fila de revisão
+type InboundRepository interface {
+	FindCompanyByDocument(ctx context.Context, document string) (int, error)
+	FindSupplierByDocument(ctx context.Context, document string) (*contact.Contact, error)
+	FindPurchaseOrder(ctx context.Context, poNo string, supplierID int) (*sales.PurchaseOrder, error)
+	FindBillByNFeKey(ctx context.Context, key string) (*sales.SupplierBill, error)
+	ImportBill(ctx context.Context, bill *sales.SupplierBill, document *models.InboundDocument) error
+	CreateDocument(ctx context.Context, document *models.InboundDocument) error
+	ListDocuments(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetDocument(ctx context.Context, id int) (*models.InboundDocument, error)
+	ResolveDocument(ctx context.Context, id int, status string, billID *int, reviewedBy string, now time.Time) (*models.InboundDocument, error)
+	ApproveBill(ctx context.Context, id int, reviewedBy string, now time.Time) (*sales.SupplierBill, error)
+}
+
+type inboundRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewInboundRepository cria uma nova instância do repositório
+func NewInboundRepository() (InboundRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &inboundRepository{
+		db:     db,
+		logger: logger.WithModule("inbound_repository"),
+	}, nil
+}
+
+// FindCompanyByDocument retorna a empresa ativa com o CNPJ informado, ou 0 se não houver
+func (r *inboundRepository) FindCompanyByDocument(ctx context.Context, document string) (int, error) {
+	var company companies.Company
+	err := db.Conn(ctx, r.db).Where(digitsOnly+" AND active = ?", document, true).First(&company).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		r.logger.Error("erro ao buscar empresa pelo CNPJ", zap.Error(err))
+		return 0, errors.WrapError(err, "falha ao buscar empresa")
+	}
+	return company.ID, nil
+}
+
+// FindSupplierByDocument busca o contato (fora da lixeira) com o CNPJ/CPF do emitente; nil se
+// não houver
+func (r *inboundRepository) FindSupplierByDocument(ctx context.Context, document string) (*contact.Contact, error) {
+	var supplier contact.Contact
+	err := db.Conn(ctx, r.db).Where(digitsOnly+" AND deleted_at IS NULL", document).
+		Order("CASE WHEN type = 'fornecedor' THEN 0 ELSE 1 END, id").
+		First(&supplier).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error("erro ao buscar fornecedor pelo documento", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar fornecedor")
+	}
+	return &supplier, nil
+}
+
+// FindPurchaseOrder busca o pedido de compra do fornecedor pelo número, com os itens; nil se não
+// houver
+func (r *inboundRepository) FindPurchaseOrder(ctx context.Context, poNo string, supplierID int) (*sales.PurchaseOrder, error) {
+	var po sales.PurchaseOrder
+	err := db.Conn(ctx, r.db).Preload("Items").Where("po_no = ? AND contact_id = ? AND status <> ?", poNo, supplierID, sales.POStatusCancelled).
+		First(&po).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error("erro ao buscar pedido de compra da nota", zap.Error(err), zap.String("po_no", poNo))
+		return nil, errors.WrapError(err, "falha ao buscar pedido de compra")
+	}
+	return &po, nil
+}
+
+// FindBillByNFeKey busca a conta a pagar já importada da NF-e; nil se não houver
+func (r *inboundRepository) FindBillByNFeKey(ctx context.Context, key string) (*sales.SupplierBill, error) {
+	var bill sales.SupplierBill
+	err := db.Conn(ctx, r.db).Where("nfe_key = ?", key).First(&bill).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar conta a pagar da NF-e")
+	}
+	return &bill, nil
+}
+
+// ImportBill grava a conta a pagar em rascunho e o documento recebido que a originou
+func (r *inboundRepository) ImportBill(ctx context.Context, bill *sales.SupplierBill, document *models.InboundDocument) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		create := tx
+		if bill.PurchaseOrderID == 0 {
+			// Sem pedido vinculado a coluna fica nula (chave estrangeira)
+			create = tx.Omit("PurchaseOrderID")
+		}
+		if err := create.Create(bill).Error; err != nil {
+			r.logger.Error("erro ao criar conta a pagar da NF-e", zap.Error(err), zap.String("nfe_key", bill.NFeKey))
+			return errors.WrapError(err, "falha ao criar conta a pagar")
+		}
+
+		document.SupplierBillID = &bill.ID
+		if err := tx.Create(document).Error; err != nil {
+			r.logger.Error("erro ao registrar documento recebido", zap.Error(err), zap.String("file_name", document.FileName))
+			return errors.WrapError(err, "falha ao registrar documento recebido")
+		}
+		return nil
+	})
+}
+
+// CreateDocument registra um documento recebido que não gerou conta a pagar
+func (r *inboundRepository) CreateDocument(ctx context.Context, document *models.InboundDocument) error {
+	if err := db.Conn(ctx, r.db).Create(document).Error; err != nil {
+		r.logger.Error("erro ao registrar documento recebido", zap.Error(err), zap.String("file_name", document.FileName))
+		return errors.WrapError(err, "falha ao registrar documento recebido")
+	}
+	return nil
+}
+
+// ListDocuments lista os documentos recebidos, mais recentes primeiro, opcionalmente por status
+func (r *inboundRepository) ListDocuments(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var documents []models.InboundDocument
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.InboundDocument{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar documentos recebidos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar documentos recebidos")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Offset(offset).Limit(params.PageSize).Order("created_at DESC, id DESC").Find(&documents).Error; err != nil {
+		r.logger.Error("erro ao listar documentos recebidos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar documentos recebidos")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, documents), nil
+}
+
+// GetDocument busca um documento recebido
+func (r *inboundRepository) GetDocument(ctx context.Context, id int) (*models.InboundDocument, error) {
+	var document models.InboundDocument
+	if err := db.Conn(ctx, r.db).First(&document, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInboundDocumentNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar documento recebido")
+	}
+	return &document, nil
+}
+
+// ResolveDocument encerra a revisão de um documento pendente (resolvido ou descartado),
+// opcionalmente ligando-o à conta a pagar lançada manualmente
+func (r *inboundRepository) ResolveDocument(ctx context.Context, id int, status string, billID *int, reviewedBy string, now time.Time) (*models.InboundDocument, error) {
+	var document models.InboundDocument
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&document, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrInboundDocumentNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar documento recebido")
+		}
+		if document.Status != models.InboundStatusPendingReview {
+			return errors.ErrInboundDocumentNotPending
+		}
+
+		if billID != nil {
+			var count int64
+			if err := tx.Model(&sales.SupplierBill{}).Where("id = ?", *billID).Count(&count).Error; err != nil {
+				return errors.WrapError(err, "falha ao buscar conta a pagar")
+			}
+			if count == 0 {
+				return errors.ErrSupplierBillNotFound
+			}
+		}
+
+		updates := map[string]interface{}{
+			"status":           status,
+			"supplier_bill_id": billID,
+			"reviewed_by":      reviewedBy,
+			"reviewed_at":      now,
+		}
+		if err := tx.Model(&document).Updates(updates).Error; err != nil {
+			r.logger.Error("erro ao concluir revisão do documento recebido", zap.Error(err), zap.Int("id", id))
+			return errors.WrapError(err, "falha ao concluir revisão do documento")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetDocument(ctx, id)
+}
+
+// ApproveBill confirma a conta a pagar em rascunho gerada pelo documento, que passa a aberta. A
+// conta ligada a pedido de compra precisa ter passado na conferência de três vias.
+func (r *inboundRepository) ApproveBill(ctx context.Context, id int, reviewedBy string, now time.Time) (*sales.SupplierBill, error) {
+	var bill sales.SupplierBill
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var document models.InboundDocument
+		if err := tx.First(&document, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrInboundDocumentNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar documento recebido")
+		}
+		if document.Status != models.InboundStatusProcessed || document.SupplierBillID == nil {
+			return errors.ErrSupplierBillNotDraft
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bill, *document.SupplierBillID).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrSupplierBillNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar conta a pagar")
+		}
+		if bill.Status != sales.SupplierBillStatusDraft {
+			return errors.ErrSupplierBillNotDraft
+		}
+		if !sales.BillMatchAllowsApproval(bill.MatchStatus) {
+			return errors.ErrThreeWayMatchRequired
+		}
+
+		if err := tx.Model(&bill).Update("status", sales.SupplierBillStatusOpen).Error; err != nil {
+			r.logger.Error("erro ao aprovar conta a pagar", zap.Error(err), zap.Int("id", bill.ID))
+			return errors.WrapError(err, "falha ao aprovar conta a pagar")
+		}
+		if err := tx.Model(&document).Updates(map[string]interface{}{"reviewed_by": reviewedBy, "reviewed_at": now}).Error; err != nil {
+			return errors.WrapError(err, "falha ao registrar revisão do documento")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &bill, nil
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MatchRepository define o recebimento das entregas de pedidos de compra e a conferência de três
+// vias das contas a pagar
+type MatchRepository interface {
+	GetBill(ctx context.Context, id int) (*sales.SupplierBill, error)
+	GetPurchaseOrder(ctx context.Context, id int) (*sales.PurchaseOrder, error)
+	ReceivedByProduct(ctx context.Context, poID int) (map[int]int, error)
+	BilledByPOItem(ctx context.Context, poID, excludeBillID int) (map[int]money.Decimal, error)
+	SaveMatch(ctx context.Context, result *models.MatchResult, now time.Time) error
+	ListMatchLines(ctx context.Context, billID int) ([]models.BillMatchLine, error)
+	ReviewMatch(ctx context.Context, billID int, status, reviewedBy, notes string, now time.Time) error
+	LinkBillItem(ctx context.Context, billID, itemID int, poItemID *int) error
+	ReceiveDelivery(ctx context.Context, id int, items []models.ReceiveItem, now time.Time) (*sales.Delivery, error)
+	MarkOrderReceived(ctx context.Context, poID int) error
+	ListBillsToMatch(ctx context.Context, poID int) ([]int, error)
+}
+
+type matchRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewMatchRepository cria uma nova instância do repositório
+func NewMatchRepository() (MatchRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &matchRepository{
+		db:     db,
+		logger: logger.WithModule("match_repository"),
+	}, nil
+}
+
+// GetBill busca a conta a pagar com os itens cobrados
+func (r *matchRepository) GetBill(ctx context.Context, id int) (*sales.SupplierBill, error) {
+	var bill sales.SupplierBill
+	err := db.Conn(ctx, r.db).Preload("Items", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		First(&bill, id).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrSupplierBillNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar conta a pagar")
+	}
+	return &bill, nil
+}
+
+// GetPurchaseOrder busca o pedido de compra com os itens
+func (r *matchRepository) GetPurchaseOrder(ctx context.Context, id int) (*sales.PurchaseOrder, error) {
+	var po sales.PurchaseOrder
+	err := db.Conn(ctx, r.db).Preload("Items", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		First(&po, id).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrPurchaseOrderNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar pedido de compra")
+	}
+	return &po, nil
+}
+
+// ReceivedByProduct soma, por produto, o recebido (em unidades de estoque) nas entregas do
+// pedido de compra que não foram devolvidas
+func (r *matchRepository) ReceivedByProduct(ctx context.Context, poID int) (map[int]int, error) {
+	var rows []struct {
+		ProductID int
+		Quantity  int
+	}
+	err := db.Conn(ctx, r.db).Table("delivery_items").
+		Select("delivery_items.product_id, COALESCE(SUM(delivery_items.received_qty), 0) AS quantity").
+		Joins("JOIN deliveries ON deliveries.id = delivery_items.delivery_id").
+		Scopes(tenant.Scope(ctx, "deliveries")).
+		Where("deliveries.purchase_order_id = ? AND deliveries.status <> ? AND deliveries.deleted_at IS NULL",
+			poID, sales.DeliveryStatusReturned).
+		Group("delivery_items.product_id").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao somar recebimento do pedido de compra", zap.Error(err), zap.Int("po_id", poID))
+		return nil, errors.WrapError(err, "falha ao somar recebimento do pedido de compra")
+	}
+
+	received := make(map[int]int, len(rows))
+	for _, row := range rows {
+		received[row.ProductID] = row.Quantity
+	}
+	return received, nil
+}
+
+// BilledByPOItem soma, por item do pedido, o cobrado nas contas do pedido que não foram
+// canceladas nem recusadas na conferência, exceto a conta informada
+func (r *matchRepository) BilledByPOItem(ctx context.Context, poID, excludeBillID int) (map[int]money.Decimal, error) {
+	var rows []struct {
+		POItemID int
+		Quantity money.Decimal
+	}
+	err := db.Conn(ctx, r.db).Table("supplier_bill_items").
+		Select("supplier_bill_items.po_item_id, SUM(supplier_bill_items.quantity) AS quantity").
+		Joins("JOIN supplier_bills ON supplier_bills.id = supplier_bill_items.supplier_bill_id").
+		Scopes(tenant.Scope(ctx, "supplier_bills")).
+		Where("supplier_bills.purchase_order_id = ? AND supplier_bills.id <> ?", poID, excludeBillID).
+		Where("supplier_bills.status <> ? AND supplier_bills.match_status <> ?",
+			sales.SupplierBillStatusCancelled, sales.BillMatchRejected).
+		Where("supplier_bill_items.po_item_id IS NOT NULL").
+		Group("supplier_bill_items.po_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("erro ao somar cobrado do pedido de compra", zap.Error(err), zap.Int("po_id", poID))
+		return nil, errors.WrapError(err, "falha ao somar cobrado do pedido de compra")
+	}
+
+	billed := make(map[int]money.Decimal, len(rows))
+	for _, row := range rows {
+		billed[row.POItemID] = row.Quantity
+	}
+	return billed, nil
+}
+
+// SaveMatch substitui as linhas da conferência da conta e grava o resultado, desfazendo uma
+// revisão anterior do comprador
+func (r *matchRepository) SaveMatch(ctx context.Context, result *models.MatchResult, now time.Time) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("supplier_bill_id = ?", result.SupplierBillID).Delete(&models.BillMatchLine{}).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover conferência anterior")
+		}
+		if len(result.Lines) > 0 {
+			if err := tx.Create(&result.Lines).Error; err != nil {
+				r.logger.Error("erro ao gravar conferência de três vias", zap.Error(err), zap.Int("bill_id", result.SupplierBillID))
+				return errors.WrapError(err, "falha ao gravar conferência de três vias")
+			}
+		}
+
+		updates := map[string]interface{}{
+			"match_status":      result.Status,
+			"matched_at":        now,
+			"match_reviewed_by": "",
+			"match_reviewed_at": nil,
+			"match_notes":       "",
+		}
+		if err := tx.Model(&sales.SupplierBill{}).Where("id = ?", result.SupplierBillID).Updates(updates).Error; err != nil {
+			return errors.WrapError(err, "falha ao atualizar conferência da conta a pagar")
+		}
+		return nil
+	})
+}
+
+// ListMatchLines lista as linhas da última conferência da conta
+func (r *matchRepository) ListMatchLines(ctx context.Context, billID int) ([]models.BillMatchLine, error) {
+	lines := []models.BillMatchLine{}
+	if err := db.Conn(ctx, r.db).Where("supplier_bill_id = ?", billID).Order("id").Find(&lines).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao listar conferência da conta a pagar")
+	}
+	return lines, nil
+}
+
+// ReviewMatch registra a decisão do comprador sobre a conta com divergências
+func (r *matchRepository) ReviewMatch(ctx context.Context, billID int, status, reviewedBy, notes string, now time.Time) error {
+	result := db.Conn(ctx, r.db).Model(&sales.SupplierBill{}).
+		Where("id = ? AND match_status = ?", billID, sales.BillMatchDiscrepancy).
+		Updates(map[string]interface{}{
+			"match_status":      status,
+			"match_reviewed_by": reviewedBy,
+			"match_reviewed_at": now,
+			"match_notes":       notes,
+		})
+	if result.Error != nil {
+		r.logger.Error("erro ao revisar conferência da conta a pagar", zap.Error(result.Error), zap.Int("bill_id", billID))
+		return errors.WrapError(result.Error, "falha ao revisar conferência da conta a pagar")
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetBill(ctx, billID); err != nil {
+			return err
+		}
+		return errors.ErrBillMatchNotInReview
+	}
+	return nil
+}
+
+// LinkBillItem liga o item da conta em rascunho a um item do pedido da conta; a conferência
+// volta a ficar pendente
+func (r *matchRepository) LinkBillItem(ctx context.Context, billID, itemID int, poItemID *int) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var bill sales.SupplierBill
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bill, billID).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrSupplierBillNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar conta a pagar")
+		}
+		if bill.Status != sales.SupplierBillStatusDraft {
+			return errors.ErrSupplierBillNotDraft
+		}
+		if bill.PurchaseOrderID == 0 {
+			return errors.ErrBillWithoutPurchaseOrder
+		}
+
+		if poItemID != nil {
+			var count int64
+			err := tx.Model(&sales.POItem{}).Where("id = ? AND purchase_order_id = ?", *poItemID, bill.PurchaseOrderID).
+				Count(&count).Error
+			if err != nil {
+				return errors.WrapError(err, "falha ao buscar item do pedido de compra")
+			}
+			if count == 0 {
+				return errors.ErrPurchaseItemNotFound
+			}
+		}
+
+		result := tx.Model(&sales.SupplierBillItem{}).Where("id = ? AND supplier_bill_id = ?", itemID, billID).
+			Update("po_item_id", poItemID)
+		if result.Error != nil {
+			return errors.WrapError(result.Error, "falha ao ligar item da conta ao pedido")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrSupplierBillItemNotFound
+		}
+		if err := tx.Model(&bill).Update("match_status", sales.BillMatchPending).Error; err != nil {
+			return errors.WrapError(err, "falha ao atualizar conferência da conta a pagar")
+		}
+		return nil
+	})
+}
+
+// ReceiveDelivery grava as quantidades recebidas nos itens da entrega de um pedido de compra.
+// A entrega passa a entregue quando todos os itens foram recebidos por completo.
+func (r *matchRepository) ReceiveDelivery(ctx context.Context, id int, items []models.ReceiveItem, now time.Time) (*sales.Delivery, error) {
+	var delivery sales.Delivery
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&delivery, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrDeliveryNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar entrega")
+		}
+		if delivery.PurchaseOrderID == 0 || delivery.Status == sales.DeliveryStatusReturned {
+			return errors.ErrDeliveryNotReceivable
+		}
+
+		byID := make(map[int]*sales.DeliveryItem, len(delivery.Items))
+		for i := range delivery.Items {
+			byID[delivery.Items[i].ID] = &delivery.Items[i]
+		}
+		for _, input := range items {
+			item, ok := byID[input.DeliveryItemID]
+			if !ok {
+				return errors.ErrDeliveryItemNotFound
+			}
+			if input.ReceivedQty < 0 || input.ReceivedQty > item.Quantity {
+				return errors.ErrInvalidReceipt
+			}
+			if err := tx.Model(&sales.DeliveryItem{}).Where("id = ?", item.ID).
+				Update("received_qty", input.ReceivedQty).Error; err != nil {
+				r.logger.Error("erro ao registrar recebimento do item", zap.Error(err), zap.Int("item_id", item.ID))
+				return errors.WrapError(err, "falha ao registrar recebimento")
+			}
+			item.ReceivedQty = input.ReceivedQty
+		}
+
+		complete := true
+		for _, item := range delivery.Items {
+			if item.ReceivedQty < item.Quantity {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			delivery.Status = sales.DeliveryStatusDelivered
+			delivery.ReceivedDate = now
+			if err := tx.Model(&sales.Delivery{}).Where("id = ?", delivery.ID).
+				Updates(map[string]interface{}{"status": delivery.Status, "received_date": now}).Error; err != nil {
+				return errors.WrapError(err, "falha ao atualizar status da entrega")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// MarkOrderReceived marca como recebido o pedido de compra enviado ou confirmado
+func (r *matchRepository) MarkOrderReceived(ctx context.Context, poID int) error {
+	err := db.Conn(ctx, r.db).Model(&sales.PurchaseOrder{}).
+		Where("id = ? AND status IN ?", poID, []string{sales.POStatusSent, sales.POStatusConfirmed}).
+		Update("status", sales.POStatusReceived).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao atualizar status do pedido de compra")
+	}
+	return nil
+}
+
+// ListBillsToMatch lista as contas em rascunho do pedido com a conferência pendente ou com
+// divergências ainda não revisadas
+func (r *matchRepository) ListBillsToMatch(ctx context.Context, poID int) ([]int, error) {
+	var ids []int
+	err := db.Conn(ctx, r.db).Model(&sales.SupplierBill{}).
+		Where("purchase_order_id = ? AND status = ? AND match_status IN ?", poID, sales.SupplierBillStatusDraft,
+			[]string{sales.BillMatchPending, sales.BillMatchDiscrepancy}).
+		Order("id").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao listar contas a conferir")
+	}
+	return ids, nil
+}
@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	productModels "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SuggestionRepository define as operações de sugestão automática de pedidos de compra
+type SuggestionRepository interface {
+	GetReorderCandidates(ctx context.Context) ([]models.ReorderCandidate, error)
+	CreateSuggestedOrders(ctx context.Context, suggestions []models.SupplierSuggestion, expectedDate time.Time) ([]models.SupplierSuggestion, error)
+	GetSuggestedOrders(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	ConfirmSuggestedOrder(ctx context.Context, id int) (*sales.PurchaseOrder, error)
+	DiscardSuggestedOrder(ctx context.Context, id int) error
+}
+
+type suggestionRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewSuggestionRepository cria uma nova instância do repositório
+func NewSuggestionRepository() (SuggestionRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &suggestionRepository{
+		db:     db,
+		logger: logger.WithModule("suggestion_repository"),
+	}, nil
+}
+
+// openPOStatuses são os status de pedido de compra cuja quantidade ainda vai entrar em estoque
+var openPOStatuses = []string{sales.POStatusDraft, sales.POStatusSent, sales.POStatusConfirmed}
+
+// openSOStatuses são os status de pedido de venda que reservam estoque (de kits mantidos como
+// uma linha, a reserva é dos componentes)
+var openSOStatuses = []string{sales.SOStatusConfirmed, sales.SOStatusProcessing}
+
+// GetReorderCandidates retorna a posição de estoque dos produtos ativos que participam da reposição:
+// com ponto de reposição definido ou com pedidos de venda em aberto. As quantidades ficam em
+// unidades de estoque; a unidade padrão de compra vem junto para montar o pedido sugerido.
+func (r *suggestionRepository) GetReorderCandidates(ctx context.Context) ([]models.ReorderCandidate, error) {
+	products, err := tenant.Condition(ctx, "p")
+	if err != nil {
+		return nil, err
+	}
+	salesOrders, err := tenant.Condition(ctx, "so")
+	if err != nil {
+		return nil, err
+	}
+	purchaseOrders, err := tenant.Condition(ctx, "po")
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.ReorderCandidate
+	if err := db.Conn(ctx, r.db).Raw(`
+		WITH open_lines AS (
+			SELECT soi.product_id,
+			       GREATEST(soi.quantity * soi.unit_factor - COALESCE(shipped.quantity, 0), 0) AS quantity
+			FROM sales_order_items soi
+			JOIN sales_orders so ON so.id = soi.sales_order_id
+			LEFT JOIN (
+				SELECT di.so_item_id, SUM(di.quantity) AS quantity
+				FROM delivery_items di
+				JOIN deliveries d ON d.id = di.delivery_id
+				WHERE di.so_item_id IS NOT NULL AND d.status <> ?
+				GROUP BY di.so_item_id
+			) shipped ON shipped.so_item_id = soi.id
+			WHERE so.status IN ? AND `+salesOrders+`
+		), committed AS (
+			-- Kits mantidos como uma linha reservam o estoque dos componentes
+			SELECT COALESCE(kc.component_product_id, ol.product_id) AS product_id,
+			       SUM(ol.quantity * COALESCE(kc.quantity, 1)) AS quantity
+			FROM open_lines ol
+			LEFT JOIN product_kits k ON k.product_id = ol.product_id AND k.mode = 'keep'
+			LEFT JOIN product_kit_components kc ON kc.kit_id = k.id
+			GROUP BY COALESCE(kc.component_product_id, ol.product_id)
+		), on_order AS (
+			SELECT poi.product_id, SUM(poi.quantity * poi.unit_factor) AS quantity
+			FROM purchase_order_items poi
+			JOIN purchase_orders po ON po.id = poi.purchase_order_id
+			WHERE po.status IN ? AND `+purchaseOrders+`
+			GROUP BY poi.product_id
+		)
+		SELECT p.company_id, p.id AS product_id, p.name AS product_name, p.sku, p.stock,
+		       p.reorder_point, p.reorder_quantity,
+		       p.preferred_supplier_id AS supplier_id, COALESCE(c.name, '') AS supplier_name,
+		       COALESCE(NULLIF(pc.average_cost, 0), p.cost_price, 0) AS unit_cost,
+		       COALESCE(committed.quantity, 0) AS committed,
+		       COALESCE(on_order.quantity, 0) AS on_order,
+		       COALESCE(pu.unit, bu.unit, ?) AS purchase_unit, COALESCE(pu.factor, 1) AS purchase_factor
+		FROM products p
+		LEFT JOIN contacts c ON c.id = p.preferred_supplier_id
+		LEFT JOIN product_units pu ON pu.product_id = p.id AND pu.is_purchase
+		LEFT JOIN product_units bu ON bu.product_id = p.id AND bu.factor = 1
+		LEFT JOIN product_costing pc ON pc.product_id = p.id
+		LEFT JOIN committed ON committed.product_id = p.id
+		LEFT JOIN on_order ON on_order.product_id = p.id
+		WHERE p.deleted_at IS NULL AND p.status = 'ativo' AND `+products+`
+		  AND (p.reorder_point > 0 OR committed.quantity > 0)
+		ORDER BY p.company_id ASC, p.id ASC`,
+		sales.DeliveryStatusReturned, openSOStatuses, openPOStatuses, productModels.DefaultUnit).
+		Scan(&candidates).Error; err != nil {
+		r.logger.Error("erro ao buscar posição de estoque para reposição", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar posição de estoque para reposição")
+	}
+
+	return candidates, nil
+}
+
+// CreateSuggestedOrders cria um pedido de compra em rascunho para cada fornecedor sugerido,
+// marcado como gerado automaticamente para revisão do comprador
+func (r *suggestionRepository) CreateSuggestedOrders(ctx context.Context, suggestions []models.SupplierSuggestion, expectedDate time.Time) ([]models.SupplierSuggestion, error) {
+	if len(suggestions) == 0 {
+		return suggestions, nil
+	}
+
+	tx := db.Conn(ctx, r.db).Begin()
+
+	// Serializa a geração para evitar sugestões duplicadas em execuções simultâneas
+	if err := tx.Exec("LOCK TABLE purchase_orders IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao bloquear pedidos de compra", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao bloquear pedidos de compra")
+	}
+
+	for i := range suggestions {
+		suggestion := &suggestions[i]
+
+		po := sales.PurchaseOrder{
+			PONo:          r.generatePONumber(tx),
+			ContactID:     suggestion.SupplierID,
+			Status:        sales.POStatusDraft,
+			ExpectedDate:  expectedDate,
+			SubTotal:      suggestion.Total,
+			GrandTotal:    suggestion.Total,
+			Notes:         "Sugestão automática gerada pelo ponto de reposição",
+			AutoGenerated: true,
+			Items:         make([]sales.POItem, 0, len(suggestion.Lines)),
+		}
+		for _, line := range suggestion.Lines {
+			po.Items = append(po.Items, sales.POItem{
+				ProductID:   line.ProductID,
+				ProductName: line.ProductName,
+				ProductCode: line.SKU,
+				Quantity:    line.Quantity,
+				Unit:        line.Unit,
+				UnitFactor:  line.UnitFactor,
+				UnitPrice:   line.UnitCost,
+				Total:       line.Total,
+			})
+		}
+
+		// O custo de reposição dá lugar ao preço do contrato de compra vigente com o fornecedor
+		if err := salesRepository.ApplyPurchaseOrderContract(tx, &po, time.Now()); err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao aplicar preços contratados na sugestão", zap.Error(err), zap.Int("supplier_id", suggestion.SupplierID))
+			return nil, err
+		}
+		suggestion.Total = po.GrandTotal
+
+		if err := tx.Omit(clause.Associations, "SalesOrderID").Create(&po).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao criar pedido de compra sugerido", zap.Error(err), zap.Int("supplier_id", suggestion.SupplierID))
+			return nil, errors.WrapError(err, "falha ao criar pedido de compra sugerido")
+		}
+
+		for i := range po.Items {
+			po.Items[i].PurchaseOrderID = po.ID
+		}
+		if err := tx.Omit(clause.Associations).Create(&po.Items).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao criar itens do pedido de compra sugerido", zap.Error(err), zap.Int("purchase_order_id", po.ID))
+			return nil, errors.WrapError(err, "falha ao criar itens do pedido de compra sugerido")
+		}
+
+		suggestion.PurchaseOrderID = po.ID
+		suggestion.PONo = po.PONo
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("pedidos de compra sugeridos criados", zap.Int("count", len(suggestions)))
+	return suggestions, nil
+}
+
+// GetSuggestedOrders lista os pedidos de compra sugeridos ainda em rascunho
+func (r *suggestionRepository) GetSuggestedOrders(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var orders []sales.PurchaseOrder
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&sales.PurchaseOrder{}).
+		Where("auto_generated = ? AND status = ?", true, sales.POStatusDraft)
+
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar pedidos de compra sugeridos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar pedidos de compra sugeridos")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Preload("Contact").Preload("Items").
+		Offset(offset).Limit(params.PageSize).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		r.logger.Error("erro ao listar pedidos de compra sugeridos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar pedidos de compra sugeridos")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, orders), nil
+}
+
+// ConfirmSuggestedOrder aprova a sugestão revisada pelo comprador, confirmando o pedido de compra
+func (r *suggestionRepository) ConfirmSuggestedOrder(ctx context.Context, id int) (*sales.PurchaseOrder, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	po, err := r.lockSuggestedOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Recalcula os totais a partir dos itens, que podem ter sido ajustados na revisão
+	if err := tx.Where("purchase_order_id = ?", id).Order("id ASC").Find(&po.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar itens do pedido de compra", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar itens do pedido de compra")
+	}
+	if len(po.Items) == 0 {
+		tx.Rollback()
+		return nil, errors.ErrEmptyPurchaseOrder
+	}
+
+	var subtotal, taxTotal, discountTotal money.Decimal
+	for _, item := range po.Items {
+		subtotal = subtotal.Add(item.Total)
+		taxTotal = taxTotal.Add(item.Tax)
+		discountTotal = discountTotal.Add(item.Discount)
+	}
+
+	updates := map[string]interface{}{
+		"status":         sales.POStatusConfirmed,
+		"subtotal":       subtotal,
+		"tax_total":      taxTotal,
+		"discount_total": discountTotal,
+		"grand_total":    subtotal.Add(taxTotal).Sub(discountTotal),
+	}
+	if err := tx.Model(po).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao confirmar pedido de compra sugerido", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao confirmar pedido de compra sugerido")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("pedido de compra sugerido confirmado", zap.Int("id", id), zap.String("po_no", po.PONo))
+	return po, nil
+}
+
+// DiscardSuggestedOrder cancela a sugestão rejeitada pelo comprador
+func (r *suggestionRepository) DiscardSuggestedOrder(ctx context.Context, id int) error {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	po, err := r.lockSuggestedOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(po).Update("status", sales.POStatusCancelled).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao descartar pedido de compra sugerido", zap.Error(err), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao descartar pedido de compra sugerido")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("pedido de compra sugerido descartado", zap.Int("id", id), zap.String("po_no", po.PONo))
+	return nil
+}
+
+// lockSuggestedOrder bloqueia o pedido de compra e valida que é uma sugestão pendente
+func (r *suggestionRepository) lockSuggestedOrder(tx *gorm.DB, id int) (*sales.PurchaseOrder, error) {
+	var po sales.PurchaseOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&po, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrPurchaseOrderNotFound
+		}
+		r.logger.Error("erro ao buscar pedido de compra", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar pedido de compra")
+	}
+
+	if !po.AutoGenerated || po.Status != sales.POStatusDraft {
+		return nil, errors.ErrNotSuggestedOrder
+	}
+	return &po, nil
+}
+
+// generatePONumber gera um número único para o pedido de compra
+func (r *suggestionRepository) generatePONumber(tx *gorm.DB) string {
+	var last sales.PurchaseOrder
+	tx.Order("id DESC").Limit(1).Find(&last)
+	return fmt.Sprintf("PO-%d-%06d", time.Now().Year(), last.ID+1)
+}
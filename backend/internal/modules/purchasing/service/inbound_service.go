@@ -0,0 +1,397 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	attachments "ERP-ONSMART/backend/internal/modules/attachments/service"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InboundService importa as notas fiscais de fornecedores recebidas por e-mail
+type InboundService struct {
+	newRepo func() (repository.InboundRepository, error)
+	// store grava o arquivo recebido nos anexos do documento
+	store  func(ctx context.Context, documentID int, attachment models.EmailAttachment) error
+	secret func() string
+	// match confere a conta ligada a um pedido de compra antes da aprovação
+	match  func(ctx context.Context, billID int) error
+	now    func() time.Time
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.InboundRepository
+}
+
+// NewInboundService cria o serviço sobre o repositório informado
+func NewInboundService(newRepo func() (repository.InboundRepository, error)) *InboundService {
+	return &InboundService{
+		newRepo: newRepo,
+		store:   storeInboundFile,
+		secret:  func() string { return viper.GetString("INBOUND_EMAIL_SECRET") },
+		match:   func(ctx context.Context, billID int) error { return defaultMatch.EnsureMatched(ctx, billID) },
+		now:     time.Now,
+		logger:  logger.WithModule("inbound_service"),
+	}
+}
+
+var defaultInbound = NewInboundService(repository.NewInboundRepository)
+
+// ReceiveRawEmail processa a mensagem MIME completa assinada com INBOUND_EMAIL_SECRET
+func ReceiveRawEmail(ctx context.Context, body []byte, signature string) (*models.InboundResult, error) {
+	return defaultInbound.ReceiveRawEmail(ctx, body, signature)
+}
+
+// ReceiveMailgunEmail processa o formulário enviado pela rota de entrada do Mailgun
+func ReceiveMailgunEmail(ctx context.Context, form *multipart.Form) (*models.InboundResult, error) {
+	return defaultInbound.ReceiveMailgunEmail(ctx, form)
+}
+
+// ListInboundDocuments lista os documentos recebidos por e-mail
+func ListInboundDocuments(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	return defaultInbound.ListDocuments(ctx, status, params)
+}
+
+// GetInboundDocument busca um documento recebido por e-mail
+func GetInboundDocument(ctx context.Context, id int) (*models.InboundDocument, error) {
+	return defaultInbound.GetDocument(ctx, id)
+}
+
+// ResolveInboundDocument conclui a revisão manual do documento
+func ResolveInboundDocument(ctx context.Context, id int, input models.ResolveInboundInput, reviewedBy string) (*models.InboundDocument, error) {
+	return defaultInbound.ResolveDocument(ctx, id, input, reviewedBy)
+}
+
+// DiscardInboundDocument descarta o documento pendente de revisão
+func DiscardInboundDocument(ctx context.Context, id int, reviewedBy string) (*models.InboundDocument, error) {
+	return defaultInbound.DiscardDocument(ctx, id, reviewedBy)
+}
+
+// ApproveInboundBill aprova a conta a pagar em rascunho importada do documento
+func ApproveInboundBill(ctx context.Context, id int, reviewedBy string) (*sales.SupplierBill, error) {
+	return defaultInbound.ApproveBill(ctx, id, reviewedBy)
+}
+
+func (s *InboundService) repository() (repository.InboundRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ReceiveRawEmail confere a assinatura do corpo e importa os anexos da mensagem
+func (s *InboundService) ReceiveRawEmail(ctx context.Context, body []byte, signature string) (*models.InboundResult, error) {
+	if !models.VerifyRawSignature(s.secret(), body, signature) {
+		return nil, errors.ErrInvalidInboundSignature
+	}
+	email, err := models.ParseRawEmail(body)
+	if err != nil {
+		return nil, err
+	}
+	return s.Ingest(ctx, models.ProviderRaw, email)
+}
+
+// ReceiveMailgunEmail confere a assinatura do Mailgun e importa os anexos da mensagem
+func (s *InboundService) ReceiveMailgunEmail(ctx context.Context, form *multipart.Form) (*models.InboundResult, error) {
+	if !models.VerifyMailgunSignature(s.secret(), form) {
+		return nil, errors.ErrInvalidInboundSignature
+	}
+	email, err := models.ParseMailgunForm(form)
+	if err != nil {
+		return nil, err
+	}
+	return s.Ingest(ctx, models.ProviderMailgun, email)
+}
+
+// Ingest importa os anexos XML e PDF da mensagem. Cada XML de NF-e vira uma conta a pagar em
+// rascunho do fornecedor emitente, ligada ao pedido de compra informado na nota; o que não puder
+// ser importado entra na fila de revisão. Um PDF acompanha a conta quando a mensagem gerou uma
+// única conta (o DANFE da própria nota); senão também vai para revisão.
+func (s *InboundService) Ingest(ctx context.Context, provider string, email *models.Email) (*models.InboundResult, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.InboundResult{MessageID: email.MessageID, Documents: []models.InboundDocument{}}
+	var pdfs []models.EmailAttachment
+	var billIDs []int
+	var billCtx context.Context
+	for _, attachment := range email.Attachments {
+		switch {
+		case attachment.IsXML():
+			document, docCtx, err := s.importXML(ctx, repo, newInboundDocument(provider, email, attachment), attachment)
+			if err != nil {
+				return nil, err
+			}
+			if document.Status == models.InboundStatusProcessed {
+				billIDs = append(billIDs, *document.SupplierBillID)
+				billCtx = docCtx
+			}
+			result.Documents = append(result.Documents, *document)
+		case attachment.IsPDF():
+			pdfs = append(pdfs, attachment)
+		}
+	}
+
+	for _, attachment := range pdfs {
+		document := newInboundDocument(provider, email, attachment)
+		docCtx := ctx
+		if len(billIDs) == 1 {
+			document.Status = models.InboundStatusProcessed
+			document.SupplierBillID = &billIDs[0]
+			docCtx = billCtx
+		} else {
+			document.Error = "PDF sem XML de NF-e correspondente na mensagem"
+		}
+		if err := s.save(docCtx, repo, document, attachment); err != nil {
+			return nil, err
+		}
+		result.Documents = append(result.Documents, *document)
+	}
+
+	s.logger.Info("e-mail de entrada processado", zap.String("provider", provider), zap.String("message_id", email.MessageID),
+		zap.String("from", email.From), zap.Int("documents", len(result.Documents)), zap.Int("bills", len(billIDs)))
+	return result, nil
+}
+
+// importXML lê a NF-e e cria a conta a pagar na empresa destinatária da nota; devolve o contexto
+// da empresa usada para que o DANFE fique junto da conta
+func (s *InboundService) importXML(ctx context.Context, repo repository.InboundRepository, document *models.InboundDocument, attachment models.EmailAttachment) (*models.InboundDocument, context.Context, error) {
+	nfe, err := models.ParseNFe(attachment.Content)
+	if err != nil {
+		document.Error = err.Error()
+		return document, ctx, s.save(ctx, repo, document, attachment)
+	}
+	document.NFeKey = nfe.Key
+
+	if nfe.RecipientDocument != "" {
+		companyID, err := repo.FindCompanyByDocument(ctx, nfe.RecipientDocument)
+		if err != nil {
+			return nil, nil, err
+		}
+		if companyID > 0 {
+			ctx = tenant.WithCompany(ctx, companyID)
+		}
+	}
+
+	existing, err := repo.FindBillByNFeKey(ctx, nfe.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil {
+		document.Status = models.InboundStatusDuplicate
+		document.SupplierBillID = &existing.ID
+		return document, ctx, s.save(ctx, repo, document, attachment)
+	}
+
+	supplier, err := repo.FindSupplierByDocument(ctx, nfe.IssuerDocument)
+	if err != nil {
+		return nil, nil, err
+	}
+	if supplier == nil {
+		document.Error = fmt.Sprintf("fornecedor %s (CNPJ/CPF %s) não cadastrado", nfe.IssuerName, nfe.IssuerDocument)
+		return document, ctx, s.save(ctx, repo, document, attachment)
+	}
+
+	bill, err := BuildDraftBill(nfe, supplier.ID)
+	if err != nil {
+		document.Error = err.Error()
+		return document, ctx, s.save(ctx, repo, document, attachment)
+	}
+	if nfe.PurchaseOrderNo != "" {
+		po, err := repo.FindPurchaseOrder(ctx, nfe.PurchaseOrderNo, supplier.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if po != nil {
+			bill.PurchaseOrderID = po.ID
+			bill.MatchStatus = sales.BillMatchPending
+			models.LinkBillItems(bill.Items, po.Items)
+			document.PurchaseOrderID = &po.ID
+		} else {
+			bill.Notes += fmt.Sprintf("\nPedido de compra %s informado na nota não encontrado.", nfe.PurchaseOrderNo)
+		}
+	}
+
+	document.Status = models.InboundStatusProcessed
+	if err := repo.ImportBill(ctx, bill, document); err != nil {
+		return nil, nil, err
+	}
+	if err := s.store(ctx, document.ID, attachment); err != nil {
+		return nil, nil, err
+	}
+	return document, ctx, nil
+}
+
+// BuildDraftBill monta a conta a pagar em rascunho da NF-e, com os itens cobrados. Sem duplicatas
+// na nota, o vencimento fica na data de emissão e deve ser ajustado na conferência. Quantidades
+// que não cabem em money.Decimal recusam a nota, que fica para revisão.
+func BuildDraftBill(nfe *models.NFe, supplierID int) (*sales.SupplierBill, error) {
+	due := nfe.IssueDate
+	if nfe.DueDate != nil {
+		due = *nfe.DueDate
+	}
+	items := make([]sales.SupplierBillItem, 0, len(nfe.Items))
+	for _, item := range nfe.Items {
+		quantity, err := money.FromFloat(item.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("quantidade do item %q: %w", item.Code, err)
+		}
+		items = append(items, sales.SupplierBillItem{
+			ProductCode: item.Code,
+			Description: item.Name,
+			Unit:        item.Unit,
+			Quantity:    quantity,
+			UnitPrice:   item.UnitPrice,
+			Total:       item.Total,
+		})
+	}
+	return &sales.SupplierBill{
+		BillNo:     nfe.Key,
+		ContactID:  supplierID,
+		Status:     sales.SupplierBillStatusDraft,
+		IssueDate:  nfe.IssueDate,
+		DueDate:    due,
+		SubTotal:   nfe.SubTotal,
+		TaxTotal:   nfe.TaxTotal,
+		GrandTotal: nfe.GrandTotal,
+		NFeKey:     nfe.Key,
+		Notes:      fmt.Sprintf("Importada da NF-e %s série %s de %s, recebida por e-mail.", nfe.Number, nfe.Series, nfe.IssuerName),
+		Items:      items,
+	}, nil
+}
+
+// save registra o documento (na fila de revisão quando não tem status) e grava o arquivo
+func (s *InboundService) save(ctx context.Context, repo repository.InboundRepository, document *models.InboundDocument, attachment models.EmailAttachment) error {
+	if document.Status == "" {
+		document.Status = models.InboundStatusPendingReview
+	}
+	if err := repo.CreateDocument(ctx, document); err != nil {
+		return err
+	}
+	return s.store(ctx, document.ID, attachment)
+}
+
+// ListDocuments lista os documentos recebidos; status vazio lista todos
+func (s *InboundService) ListDocuments(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	switch status {
+	case "", models.InboundStatusProcessed, models.InboundStatusDuplicate, models.InboundStatusPendingReview,
+		models.InboundStatusResolved, models.InboundStatusDiscarded:
+	default:
+		return nil, errors.InvalidParam("status inválido")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDocuments(ctx, status, params)
+}
+
+// GetDocument busca um documento recebido
+func (s *InboundService) GetDocument(ctx context.Context, id int) (*models.InboundDocument, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetDocument(ctx, id)
+}
+
+// ResolveDocument marca o documento pendente como resolvido, com a conta lançada manualmente
+func (s *InboundService) ResolveDocument(ctx context.Context, id int, input models.ResolveInboundInput, reviewedBy string) (*models.InboundDocument, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ResolveDocument(ctx, id, models.InboundStatusResolved, input.SupplierBillID, reviewedBy, s.now())
+}
+
+// DiscardDocument descarta o documento pendente (spam, nota de outra empresa, arquivo repetido)
+func (s *InboundService) DiscardDocument(ctx context.Context, id int, reviewedBy string) (*models.InboundDocument, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ResolveDocument(ctx, id, models.InboundStatusDiscarded, nil, reviewedBy, s.now())
+}
+
+// ApproveBill confirma a conta a pagar em rascunho após a conferência com a nota. A conta ligada
+// a um pedido de compra ainda não conferida é conferida antes; só é aprovada se conferir dentro
+// da tolerância ou tiver as divergências aceitas pelo comprador.
+func (s *InboundService) ApproveBill(ctx context.Context, id int, reviewedBy string) (*sales.SupplierBill, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	document, err := repo.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document.SupplierBillID != nil {
+		if err := s.match(ctx, *document.SupplierBillID); err != nil {
+			return nil, err
+		}
+	}
+	bill, err := repo.ApproveBill(ctx, id, reviewedBy, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("conta a pagar importada aprovada", zap.Int("document_id", id), zap.Int("bill_id", bill.ID),
+		zap.String("reviewed_by", reviewedBy))
+	return bill, nil
+}
+
+func newInboundDocument(provider string, email *models.Email, attachment models.EmailAttachment) *models.InboundDocument {
+	return &models.InboundDocument{
+		Provider:    provider,
+		MessageID:   truncate(email.MessageID, 255),
+		Sender:      truncate(email.From, 255),
+		Subject:     truncate(email.Subject, 255),
+		FileName:    truncate(attachment.FileName, 255),
+		ContentType: truncate(attachment.ContentType, 100),
+		Size:        int64(len(attachment.Content)),
+	}
+}
+
+// storeInboundFile grava o arquivo nos anexos do documento recebido
+func storeInboundFile(ctx context.Context, documentID int, attachment models.EmailAttachment) error {
+	_, err := attachments.UploadAttachment(ctx, models.InboundEntityType, documentID, attachments.Upload{
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        int64(len(attachment.Content)),
+		Content:     bytes.NewReader(attachment.Content),
+		UploadedBy:  "e-mail",
+	})
+	return err
+}
+
+func truncate(value string, max int) string {
+	value = strings.TrimSpace(value)
+	if runes := []rune(value); len(runes) > max {
+		return string(runes[:max])
+	}
+	return value
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inboundKey = "35260512345678000190550010000012341000012345"
+
+const inboundNFe = `<nfeProc xmlns="http://www.portalfiscal.inf.br/nfe"><NFe><infNFe Id="NFe` + inboundKey + `">
+<ide><serie>1</serie><nNF>1234</nNF><dhEmi>2026-05-02T10:30:00-03:00</dhEmi></ide>
+<emit><CNPJ>12345678000190</CNPJ><xNome>Parafusos Brasil</xNome></emit><dest><CNPJ>98765432000110</CNPJ></dest>
+<det nItem="1"><prod><cProd>PAR-10</cProd><xProd>Parafuso</xProd><uCom>CX</uCom><qCom>10</qCom><vUnCom>50</vUnCom><vProd>500</vProd></prod></det>
+<total><ICMSTot><vIPI>25.00</vIPI><vNF>525.00</vNF></ICMSTot></total>
+<compra><xPed>PO-31</xPed></compra></infNFe></NFe></nfeProc>`
+
+// fakeInboundRepo implementa só o que os testes usam; os demais métodos não são chamados
+type fakeInboundRepo struct {
+	repository.InboundRepository
+	supplier  *contact.Contact
+	po        *sales.PurchaseOrder
+	existing  *sales.SupplierBill
+	bills     []*sales.SupplierBill
+	documents []*models.InboundDocument
+	billCtx   context.Context
+}
+
+func (f *fakeInboundRepo) FindCompanyByDocument(_ context.Context, document string) (int, error) {
+	if document == "98765432000110" {
+		return 3, nil
+	}
+	return 0, nil
+}
+
+func (f *fakeInboundRepo) FindSupplierByDocument(context.Context, string) (*contact.Contact, error) {
+	return f.supplier, nil
+}
+
+func (f *fakeInboundRepo) FindPurchaseOrder(_ context.Context, poNo string, _ int) (*sales.PurchaseOrder, error) {
+	if f.po != nil && f.po.PONo == poNo {
+		return f.po, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeInboundRepo) FindBillByNFeKey(context.Context, string) (*sales.SupplierBill, error) {
+	return f.existing, nil
+}
+
+func (f *fakeInboundRepo) ImportBill(ctx context.Context, bill *sales.SupplierBill, document *models.InboundDocument) error {
+	bill.ID = len(f.bills) + 1
+	f.bills = append(f.bills, bill)
+	f.billCtx = ctx
+	document.SupplierBillID = &bill.ID
+	return f.CreateDocument(ctx, document)
+}
+
+func (f *fakeInboundRepo) CreateDocument(_ context.Context, document *models.InboundDocument) error {
+	document.ID = len(f.documents) + 1
+	f.documents = append(f.documents, document)
+	return nil
+}
+
+func newTestInboundService(repo *fakeInboundRepo) (*InboundService, *[]int) {
+	s := NewInboundService(func() (repository.InboundRepository, error) { return repo, nil })
+	stored := []int{}
+	s.store = func(_ context.Context, documentID int, _ models.EmailAttachment) error {
+		stored = append(stored, documentID)
+		return nil
+	}
+	return s, &stored
+}
+
+func inboundEmail(attachments ...models.EmailAttachment) *models.Email {
+	return &models.Email{MessageID: "<abc@fornecedor>", From: "nfe@fornecedor.com.br", Subject: "NF-e 1234", Attachments: attachments}
+}
+
+var (
+	xmlAttachment = models.EmailAttachment{FileName: "nota.xml", ContentType: "application/xml", Content: []byte(inboundNFe)}
+	pdfAttachment = models.EmailAttachment{FileName: "danfe.pdf", ContentType: "application/pdf", Content: []byte("%PDF-1.4")}
+)
+
+func TestIngestCreatesDraftBillLinkedToPurchaseOrder(t *testing.T) {
+	po := &sales.PurchaseOrder{ID: 31, PONo: "PO-31", Items: []sales.POItem{{ID: 90, ProductCode: "par-10"}}}
+	repo := &fakeInboundRepo{supplier: &contact.Contact{ID: 8}, po: po}
+	s, stored := newTestInboundService(repo)
+
+	logo := models.EmailAttachment{FileName: "logo.png", ContentType: "image/png"}
+	result, err := s.Ingest(context.Background(), models.ProviderRaw, inboundEmail(logo, pdfAttachment, xmlAttachment))
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 2, "a imagem é ignorada")
+
+	require.Len(t, repo.bills, 1)
+	bill := repo.bills[0]
+	assert.Equal(t, sales.SupplierBillStatusDraft, bill.Status)
+	assert.Equal(t, inboundKey, bill.NFeKey)
+	assert.Equal(t, 8, bill.ContactID)
+	assert.Equal(t, 31, bill.PurchaseOrderID)
+	assert.Equal(t, "525.00", bill.GrandTotal.String())
+	assert.Equal(t, "25.00", bill.TaxTotal.String())
+	assert.Equal(t, bill.IssueDate, bill.DueDate, "sem duplicatas vence na emissão")
+	assert.Equal(t, sales.BillMatchPending, bill.MatchStatus, "aguarda a conferência de três vias")
+	require.Len(t, bill.Items, 1)
+	assert.Equal(t, 90, *bill.Items[0].POItemID, "ligado ao item do pedido pelo código")
+	assert.Equal(t, "10.00", bill.Items[0].Quantity.String())
+	companyID, _ := tenant.CompanyID(repo.billCtx)
+	assert.Equal(t, 3, companyID, "empresa destinatária da nota")
+
+	xmlDoc, pdfDoc := result.Documents[0], result.Documents[1]
+	assert.Equal(t, models.InboundStatusProcessed, xmlDoc.Status)
+	assert.Equal(t, 31, *xmlDoc.PurchaseOrderID)
+	assert.Equal(t, models.InboundStatusProcessed, pdfDoc.Status, "o DANFE acompanha a conta")
+	assert.Equal(t, bill.ID, *pdfDoc.SupplierBillID)
+	assert.Equal(t, []int{1, 2}, *stored)
+}
+
+func TestIngestQueuesUnparsedDocumentsForReview(t *testing.T) {
+	repo := &fakeInboundRepo{}
+	s, stored := newTestInboundService(repo)
+
+	broken := models.EmailAttachment{FileName: "nota.xml", Content: []byte("<nfeProc>")}
+	result, err := s.Ingest(context.Background(), models.ProviderMailgun, inboundEmail(broken, xmlAttachment, pdfAttachment))
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 3)
+	assert.Empty(t, repo.bills)
+
+	for _, document := range result.Documents {
+		assert.Equal(t, models.InboundStatusPendingReview, document.Status, document.FileName)
+		assert.NotEmpty(t, document.Error)
+	}
+	assert.Contains(t, result.Documents[1].Error, "não cadastrado", "fornecedor desconhecido")
+	assert.Equal(t, inboundKey, result.Documents[1].NFeKey)
+	assert.Len(t, *stored, 3, "todos os arquivos ficam guardados")
+}
+
+func TestIngestSkipsDuplicateNFe(t *testing.T) {
+	repo := &fakeInboundRepo{supplier: &contact.Contact{ID: 8}, existing: &sales.SupplierBill{ID: 12}}
+	s, _ := newTestInboundService(repo)
+
+	result, err := s.Ingest(context.Background(), models.ProviderRaw, inboundEmail(xmlAttachment))
+	require.NoError(t, err)
+	assert.Empty(t, repo.bills)
+	assert.Equal(t, models.InboundStatusDuplicate, result.Documents[0].Status)
+	assert.Equal(t, 12, *result.Documents[0].SupplierBillID)
+}
+
+func TestBuildDraftBillNotesMissingPurchaseOrder(t *testing.T) {
+	repo := &fakeInboundRepo{supplier: &contact.Contact{ID: 8}}
+	s, _ := newTestInboundService(repo)
+
+	_, err := s.Ingest(context.Background(), models.ProviderRaw, inboundEmail(xmlAttachment))
+	require.NoError(t, err)
+	require.Len(t, repo.bills, 1)
+	assert.Zero(t, repo.bills[0].PurchaseOrderID)
+	assert.Empty(t, repo.bills[0].MatchStatus, "sem pedido a conferência não se aplica")
+	assert.True(t, strings.Contains(repo.bills[0].Notes, "PO-31"), repo.bills[0].Notes)
+	assert.Equal(t, time.Date(2026, 5, 2, 13, 30, 0, 0, time.UTC), repo.bills[0].IssueDate.UTC())
+}
+
+func TestIngestQueuesNFeWithQuantityOutOfRange(t *testing.T) {
+	repo := &fakeInboundRepo{supplier: &contact.Contact{ID: 8}}
+	s, _ := newTestInboundService(repo)
+
+	content := strings.Replace(inboundNFe, "<qCom>10</qCom>", "<qCom>1e16</qCom>", 1)
+	huge := models.EmailAttachment{FileName: "nota.xml", ContentType: "application/xml", Content: []byte(content)}
+	result, err := s.Ingest(context.Background(), models.ProviderRaw, inboundEmail(huge))
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 1)
+	assert.Empty(t, repo.bills)
+	assert.Equal(t, models.InboundStatusPendingReview, result.Documents[0].Status)
+	assert.Contains(t, result.Documents[0].Error, `quantidade do item "PAR-10"`)
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	tasks "ERP-ONSMART/backend/internal/modules/tasks/models"
+	tasksService "ERP-ONSMART/backend/internal/modules/tasks/service"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// MatchService registra o recebimento das entregas de pedidos de compra e confere as contas a
+// pagar com o pedido (quantidade e preço) e o recebimento
+type MatchService struct {
+	newRepo   func() (repository.MatchRepository, error)
+	tolerance func() models.MatchTolerance
+	now       func() time.Time
+	// openTask e closeTask mantêm a tarefa de revisão das divergências da conferência
+	openTask  func(ctx context.Context, task tasks.Task) error
+	closeTask func(ctx context.Context, sourceKey, closedBy string) error
+	logger    *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.MatchRepository
+}
+
+// NewMatchService cria o serviço sobre o repositório informado
+func NewMatchService(newRepo func() (repository.MatchRepository, error)) *MatchService {
+	return &MatchService{
+		newRepo:   newRepo,
+		tolerance: configuredTolerance,
+		now:       time.Now,
+		openTask:  tasksService.Open,
+		closeTask: tasksService.Close,
+		logger:    logger.WithModule("match_service"),
+	}
+}
+
+var defaultMatch = NewMatchService(repository.NewMatchRepository)
+
+// ReceiveDelivery registra o recebimento de uma entrega de pedido de compra
+func ReceiveDelivery(ctx context.Context, id int, input models.ReceiveInput) (*sales.Delivery, error) {
+	return defaultMatch.ReceiveDelivery(ctx, id, input)
+}
+
+// GetReceiving retorna a posição de recebimento e cobrança dos itens do pedido de compra
+func GetReceiving(ctx context.Context, poID int) ([]models.ReceivingLine, error) {
+	return defaultMatch.Receiving(ctx, poID)
+}
+
+// MatchBill confere a conta a pagar com o pedido de compra e o recebimento
+func MatchBill(ctx context.Context, billID int) (*models.MatchResult, error) {
+	return defaultMatch.MatchBill(ctx, billID)
+}
+
+// GetBillMatch retorna a última conferência da conta a pagar
+func GetBillMatch(ctx context.Context, billID int) (*models.MatchResult, error) {
+	return defaultMatch.GetMatch(ctx, billID)
+}
+
+// LinkBillItem liga o item da conta ao item do pedido e refaz a conferência
+func LinkBillItem(ctx context.Context, billID, itemID int, input models.LinkBillItemInput) (*models.MatchResult, error) {
+	return defaultMatch.LinkBillItem(ctx, billID, itemID, input)
+}
+
+// ReviewBillMatch registra a decisão do comprador sobre as divergências da conferência
+func ReviewBillMatch(ctx context.Context, billID int, input models.ReviewMatchInput, reviewedBy string) (*models.MatchResult, error) {
+	return defaultMatch.ReviewMatch(ctx, billID, input, reviewedBy)
+}
+
+// configuredTolerance lê as tolerâncias da conferência, em percentual. Valores inválidos já
+// impedem a subida em config.LoadConfig; sem configuração a conferência fica sem tolerância.
+func configuredTolerance() models.MatchTolerance {
+	tolerance := func(key string) money.Decimal {
+		value, err := money.Parse(viper.GetString(key))
+		if err != nil {
+			return money.Zero
+		}
+		return value
+	}
+	return models.MatchTolerance{
+		QuantityPercent: tolerance("THREE_WAY_MATCH_QTY_TOLERANCE"),
+		PricePercent:    tolerance("THREE_WAY_MATCH_PRICE_TOLERANCE"),
+	}
+}
+
+func (s *MatchService) repository() (repository.MatchRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ReceiveDelivery grava o recebido nos itens da entrega. Com tudo recebido, o pedido de compra
+// passa a recebido; as contas em rascunho do pedido aguardando conferência são conferidas de novo
+// com o recebimento atualizado.
+func (s *MatchService) ReceiveDelivery(ctx context.Context, id int, input models.ReceiveInput) (*sales.Delivery, error) {
+	if len(input.Items) == 0 {
+		return nil, errors.ErrInvalidReceipt
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := repo.ReceiveDelivery(ctx, id, input.Items, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	po, received, err := s.receipts(ctx, repo, delivery.PurchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if models.FullyReceived(po, received) {
+		if err := repo.MarkOrderReceived(ctx, po.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	billIDs, err := repo.ListBillsToMatch(ctx, po.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, billID := range billIDs {
+		if _, err := s.MatchBill(ctx, billID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.Info("entrega de pedido de compra recebida", zap.Int("delivery_id", id), zap.Int("po_id", po.ID),
+		zap.String("status", delivery.Status), zap.Int("bills_matched", len(billIDs)))
+	return delivery, nil
+}
+
+// Receiving monta a posição de recebimento e cobrança dos itens do pedido de compra
+func (s *MatchService) Receiving(ctx context.Context, poID int) ([]models.ReceivingLine, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	po, received, err := s.receipts(ctx, repo, poID)
+	if err != nil {
+		return nil, err
+	}
+	billed, err := repo.BilledByPOItem(ctx, poID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return models.BuildReceivingLines(po, received, billed), nil
+}
+
+// MatchBill confere a conta em rascunho ligada a um pedido de compra e grava o resultado
+func (s *MatchService) MatchBill(ctx context.Context, billID int) (*models.MatchResult, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	bill, err := repo.GetBill(ctx, billID)
+	if err != nil {
+		return nil, err
+	}
+	if bill.PurchaseOrderID == 0 {
+		return nil, errors.ErrBillWithoutPurchaseOrder
+	}
+	if bill.Status != sales.SupplierBillStatusDraft {
+		return nil, errors.ErrSupplierBillNotDraft
+	}
+
+	po, received, err := s.receipts(ctx, repo, bill.PurchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	billed, err := repo.BilledByPOItem(ctx, po.ID, bill.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.ThreeWayMatch(po, bill, received, billed, s.tolerance())
+	now := s.now()
+	if err := repo.SaveMatch(ctx, &result, now); err != nil {
+		return nil, err
+	}
+	result.MatchedAt = &now
+	s.syncReviewTask(ctx, bill, result.Status)
+
+	s.logger.Info("conta a pagar conferida com o pedido de compra", zap.Int("bill_id", bill.ID), zap.Int("po_id", po.ID),
+		zap.String("status", result.Status))
+	return &result, nil
+}
+
+// syncReviewTask abre a tarefa de revisão da conta com divergências e a conclui quando a conta
+// passa a conferir. A conferência já foi gravada: falhas só vão para o log.
+func (s *MatchService) syncReviewTask(ctx context.Context, bill *sales.SupplierBill, status string) {
+	id := bill.ID
+	key := tasks.ApprovalKey("supplier_bill", id)
+	var err error
+	if status == sales.BillMatchDiscrepancy {
+		due := tasks.DateOf(s.now())
+		err = s.openTask(ctx, tasks.Task{
+			Title:       fmt.Sprintf("Revisar divergências da conta a pagar %s", bill.BillNo),
+			Description: fmt.Sprintf("A conta diverge do pedido de compra %d ou do recebimento.", bill.PurchaseOrderID),
+			DueDate:     &due,
+			EntityType:  "supplier_bill",
+			EntityID:    &id,
+			Source:      tasks.SourceApproval,
+			SourceKey:   &key,
+		})
+	} else {
+		err = s.closeTask(ctx, key, "conferência automática")
+	}
+	if err != nil {
+		s.logger.Warn("erro ao atualizar tarefa de revisão da conferência", zap.Error(err), zap.Int("bill_id", id))
+	}
+}
+
+// EnsureMatched confere a conta que ainda aguarda a conferência; as já conferidas mantêm o
+// resultado (e a revisão do comprador)
+func (s *MatchService) EnsureMatched(ctx context.Context, billID int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	bill, err := repo.GetBill(ctx, billID)
+	if err != nil {
+		return err
+	}
+	if bill.MatchStatus != sales.BillMatchPending || bill.Status != sales.SupplierBillStatusDraft {
+		return nil
+	}
+	_, err = s.MatchBill(ctx, billID)
+	return err
+}
+
+// GetMatch retorna a conferência gravada da conta com a revisão do comprador
+func (s *MatchService) GetMatch(ctx context.Context, billID int) (*models.MatchResult, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	bill, err := repo.GetBill(ctx, billID)
+	if err != nil {
+		return nil, err
+	}
+	if bill.PurchaseOrderID == 0 {
+		return nil, errors.ErrBillWithoutPurchaseOrder
+	}
+	lines, err := repo.ListMatchLines(ctx, billID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MatchResult{
+		SupplierBillID:  bill.ID,
+		PurchaseOrderID: bill.PurchaseOrderID,
+		Status:          bill.MatchStatus,
+		Tolerance:       s.tolerance(),
+		MatchedAt:       bill.MatchedAt,
+		ReviewedBy:      bill.MatchReviewedBy,
+		ReviewedAt:      bill.MatchReviewedAt,
+		Notes:           bill.MatchNotes,
+		Lines:           lines,
+	}, nil
+}
+
+// LinkBillItem liga o item da conta ao item do pedido (ou desfaz o vínculo) e confere a conta de
+// novo
+func (s *MatchService) LinkBillItem(ctx context.Context, billID, itemID int, input models.LinkBillItemInput) (*models.MatchResult, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.LinkBillItem(ctx, billID, itemID, input.POItemID); err != nil {
+		return nil, err
+	}
+	return s.MatchBill(ctx, billID)
+}
+
+// ReviewMatch aceita ou recusa as divergências da conferência. Aceitas, a conta pode ser
+// aprovada e paga; recusadas, fica bloqueada até ser conferida de novo (após novo recebimento ou
+// ajuste dos itens).
+func (s *MatchService) ReviewMatch(ctx context.Context, billID int, input models.ReviewMatchInput, reviewedBy string) (*models.MatchResult, error) {
+	status := sales.BillMatchAccepted
+	switch input.Decision {
+	case models.MatchDecisionAccept:
+	case models.MatchDecisionReject:
+		status = sales.BillMatchRejected
+	default:
+		return nil, errors.InvalidParam("decisão deve ser accept ou reject")
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.ReviewMatch(ctx, billID, status, reviewedBy, input.Notes, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.closeTask(ctx, tasks.ApprovalKey("supplier_bill", billID), reviewedBy); err != nil {
+		s.logger.Warn("erro ao concluir tarefa de revisão da conferência", zap.Error(err), zap.Int("bill_id", billID))
+	}
+
+	s.logger.Info("divergências da conferência revisadas", zap.Int("bill_id", billID), zap.String("decision", input.Decision),
+		zap.String("reviewed_by", reviewedBy))
+	return s.GetMatch(ctx, billID)
+}
+
+// receipts busca o pedido com os itens e o recebido por item, em unidades de estoque
+func (s *MatchService) receipts(ctx context.Context, repo repository.MatchRepository, poID int) (*sales.PurchaseOrder, map[int]int, error) {
+	po, err := repo.GetPurchaseOrder(ctx, poID)
+	if err != nil {
+		return nil, nil, err
+	}
+	byProduct, err := repo.ReceivedByProduct(ctx, poID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return po, models.AllocateReceipts(po.Items, byProduct), nil
+}
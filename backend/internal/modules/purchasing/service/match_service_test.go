@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	tasks "ERP-ONSMART/backend/internal/modules/tasks/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMatchRepo implementa só o que os testes usam; os demais métodos não são chamados
+type fakeMatchRepo struct {
+	repository.MatchRepository
+	bill     *sales.SupplierBill
+	po       *sales.PurchaseOrder
+	received map[int]int
+	saved    []models.MatchResult
+	poStatus string
+	// tarefas de revisão abertas, pela chave
+	openTasks map[string]tasks.Task
+}
+
+func (f *fakeMatchRepo) GetBill(_ context.Context, id int) (*sales.SupplierBill, error) {
+	if f.bill == nil || f.bill.ID != id {
+		return nil, appErrors.ErrSupplierBillNotFound
+	}
+	copied := *f.bill
+	return &copied, nil
+}
+
+func (f *fakeMatchRepo) GetPurchaseOrder(context.Context, int) (*sales.PurchaseOrder, error) {
+	return f.po, nil
+}
+
+func (f *fakeMatchRepo) ReceivedByProduct(context.Context, int) (map[int]int, error) {
+	return f.received, nil
+}
+
+func (f *fakeMatchRepo) BilledByPOItem(context.Context, int, int) (map[int]money.Decimal, error) {
+	return nil, nil
+}
+
+func (f *fakeMatchRepo) SaveMatch(_ context.Context, result *models.MatchResult, _ time.Time) error {
+	f.saved = append(f.saved, *result)
+	f.bill.MatchStatus = result.Status
+	return nil
+}
+
+func (f *fakeMatchRepo) ReceiveDelivery(_ context.Context, id int, items []models.ReceiveItem, _ time.Time) (*sales.Delivery, error) {
+	for _, item := range items {
+		f.received[7] += item.ReceivedQty
+	}
+	return &sales.Delivery{ID: id, PurchaseOrderID: f.po.ID, Status: sales.DeliveryStatusDelivered}, nil
+}
+
+func (f *fakeMatchRepo) MarkOrderReceived(context.Context, int) error {
+	f.poStatus = sales.POStatusReceived
+	return nil
+}
+
+func (f *fakeMatchRepo) ListBillsToMatch(context.Context, int) ([]int, error) {
+	return []int{f.bill.ID}, nil
+}
+
+func newTestMatchService(repo *fakeMatchRepo) *MatchService {
+	s := NewMatchService(func() (repository.MatchRepository, error) { return repo, nil })
+	s.tolerance = func() models.MatchTolerance { return models.MatchTolerance{PricePercent: money.FromInt(2)} }
+	s.now = func() time.Time { return time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC) }
+	repo.openTasks = map[string]tasks.Task{}
+	s.openTask = func(ctx context.Context, task tasks.Task) error {
+		repo.openTasks[*task.SourceKey] = task
+		return nil
+	}
+	s.closeTask = func(ctx context.Context, sourceKey, closedBy string) error {
+		delete(repo.openTasks, sourceKey)
+		return nil
+	}
+	return s
+}
+
+func newMatchRepo() *fakeMatchRepo {
+	poItemID := 1
+	return &fakeMatchRepo{
+		po: &sales.PurchaseOrder{ID: 31, Items: []sales.POItem{
+			{ID: 1, ProductID: 7, ProductCode: "PAR-10", Quantity: 10, UnitFactor: 1, UnitPrice: money.FromInt(50)},
+		}},
+		bill: &sales.SupplierBill{ID: 5, PurchaseOrderID: 31, Status: sales.SupplierBillStatusDraft, MatchStatus: sales.BillMatchPending,
+			Items: []sales.SupplierBillItem{{ID: 11, SupplierBillID: 5, POItemID: &poItemID, Quantity: money.FromInt(10), UnitPrice: money.FromInt(50)}}},
+		received: map[int]int{},
+	}
+}
+
+func TestReceiveDeliveryRematchesPendingBills(t *testing.T) {
+	repo := newMatchRepo()
+	s := newTestMatchService(repo)
+	ctx := context.Background()
+
+	result, err := s.MatchBill(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, sales.BillMatchDiscrepancy, result.Status, "nada recebido ainda")
+	key := tasks.ApprovalKey("supplier_bill", 5)
+	assert.Contains(t, repo.openTasks, key, "a divergência abre a tarefa de revisão")
+
+	_, err = s.ReceiveDelivery(ctx, 40, models.ReceiveInput{Items: []models.ReceiveItem{{DeliveryItemID: 1, ReceivedQty: 10}}})
+	require.NoError(t, err)
+	assert.Equal(t, sales.POStatusReceived, repo.poStatus)
+	require.Len(t, repo.saved, 2)
+	assert.Equal(t, sales.BillMatchMatched, repo.saved[1].Status, "conferida de novo com o recebimento")
+	assert.NotContains(t, repo.openTasks, key, "a conta conferida conclui a tarefa de revisão")
+
+	_, err = s.ReceiveDelivery(ctx, 40, models.ReceiveInput{})
+	assert.ErrorIs(t, err, appErrors.ErrInvalidReceipt)
+}
+
+func TestMatchBillRequiresDraftWithPurchaseOrder(t *testing.T) {
+	repo := newMatchRepo()
+	s := newTestMatchService(repo)
+	ctx := context.Background()
+
+	repo.bill.Status = sales.SupplierBillStatusOpen
+	_, err := s.MatchBill(ctx, 5)
+	assert.ErrorIs(t, err, appErrors.ErrSupplierBillNotDraft)
+
+	repo.bill.PurchaseOrderID = 0
+	_, err = s.MatchBill(ctx, 5)
+	assert.ErrorIs(t, err, appErrors.ErrBillWithoutPurchaseOrder)
+
+	_, err = s.ReviewMatch(ctx, 5, models.ReviewMatchInput{Decision: "maybe"}, "ana")
+	assert.Error(t, err)
+}
+
+func TestEnsureMatchedKeepsReviewedBills(t *testing.T) {
+	repo := newMatchRepo()
+	s := newTestMatchService(repo)
+	ctx := context.Background()
+
+	repo.bill.MatchStatus = sales.BillMatchAccepted
+	require.NoError(t, s.EnsureMatched(ctx, 5))
+	assert.Empty(t, repo.saved, "divergências aceitas não são conferidas de novo")
+
+	repo.bill.MatchStatus = sales.BillMatchPending
+	require.NoError(t, s.EnsureMatched(ctx, 5))
+	require.Len(t, repo.saved, 1)
+	assert.False(t, sales.BillMatchAllowsApproval(repo.bill.MatchStatus), "sem recebimento a aprovação fica bloqueada")
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/modules/purchasing/models"
+	"ERP-ONSMART/backend/internal/modules/purchasing/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// GenerateSuggestions analisa o estoque e os pedidos de venda em aberto e cria pedidos de compra
+// em rascunho, agrupados por fornecedor preferencial, para os produtos abaixo do ponto de reposição
+// da empresa do contexto
+func GenerateSuggestions(ctx context.Context) (*models.GenerationResult, error) {
+	repo, err := repository.NewSuggestionRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := repo.GetReorderCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return generate(ctx, repo, candidates)
+}
+
+// GenerateAllSuggestions gera as sugestões de todas as empresas, cada empresa no seu contexto
+func GenerateAllSuggestions(ctx context.Context) (*models.GenerationResult, error) {
+	repo, err := repository.NewSuggestionRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := repo.GetReorderCandidates(tenant.AllCompanies(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	byCompany := map[int][]models.ReorderCandidate{}
+	var companies []int
+	for _, candidate := range candidates {
+		if _, ok := byCompany[candidate.CompanyID]; !ok {
+			companies = append(companies, candidate.CompanyID)
+		}
+		byCompany[candidate.CompanyID] = append(byCompany[candidate.CompanyID], candidate)
+	}
+
+	total := &models.GenerationResult{
+		Suggested:  []models.SupplierSuggestion{},
+		Unassigned: []models.SuggestionLine{},
+	}
+	for _, companyID := range companies {
+		result, err := generate(tenant.WithCompany(ctx, companyID), repo, byCompany[companyID])
+		if err != nil {
+			return total, err
+		}
+		total.Scanned += result.Scanned
+		total.Suggested = append(total.Suggested, result.Suggested...)
+		total.Unassigned = append(total.Unassigned, result.Unassigned...)
+	}
+	return total, nil
+}
+
+// generate monta e grava os pedidos sugeridos a partir da posição de estoque de uma empresa
+func generate(ctx context.Context, repo repository.SuggestionRepository, candidates []models.ReorderCandidate) (*models.GenerationResult, error) {
+	suggestions, unassigned := models.BuildSuggestions(candidates)
+	suggestions, err := repo.CreateSuggestedOrders(ctx, suggestions, expectedDate(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GenerationResult{
+		Scanned:    len(candidates),
+		Suggested:  suggestions,
+		Unassigned: unassigned,
+	}, nil
+}
+
+// GetSuggestedOrders lista os pedidos de compra sugeridos aguardando revisão
+func GetSuggestedOrders(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewSuggestionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetSuggestedOrders(ctx, params)
+}
+
+// ConfirmSuggestedOrder confirma o pedido de compra sugerido após a revisão do comprador
+func ConfirmSuggestedOrder(ctx context.Context, id int) (*sales.PurchaseOrder, error) {
+	repo, err := repository.NewSuggestionRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ConfirmSuggestedOrder(ctx, id)
+}
+
+// DiscardSuggestedOrder cancela o pedido de compra sugerido
+func DiscardSuggestedOrder(ctx context.Context, id int) error {
+	repo, err := repository.NewSuggestionRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DiscardSuggestedOrder(ctx, id)
+}
+
+// StartReorderScheduler gera sugestões de compra periodicamente até o contexto ser cancelado
+func StartReorderScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("reorder_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				result, err := GenerateAllSuggestions(ctx)
+				metrics.ObserveJob("reorder_scheduler", err)
+				if err != nil {
+					log.Error("erro ao gerar sugestões de compra", zap.Error(err))
+					continue
+				}
+				log.Info("geração de sugestões de compra concluída",
+					zap.Int("scanned", result.Scanned),
+					zap.Int("purchase_orders", len(result.Suggested)),
+					zap.Int("unassigned", len(result.Unassigned)))
+			}
+		}
+	}()
+}
+
+// expectedDate calcula a data prevista de entrega a partir do prazo padrão dos fornecedores
+func expectedDate(now time.Time) time.Time {
+	days := viper.GetInt("PURCHASE_LEAD_TIME_DAYS")
+	if days <= 0 {
+		days = 7
+	}
+	return now.AddDate(0, 0, days)
+}
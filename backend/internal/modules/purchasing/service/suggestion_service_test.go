@@ -0,0 +1,19 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectedDateUsesLeadTime(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+
+	viper.Set("PURCHASE_LEAD_TIME_DAYS", 10)
+	assert.Equal(t, now.AddDate(0, 0, 10), expectedDate(now))
+
+	viper.Set("PURCHASE_LEAD_TIME_DAYS", 0)
+	assert.Equal(t, now.AddDate(0, 0, 7), expectedDate(now))
+}
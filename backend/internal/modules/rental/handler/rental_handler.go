@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/rental/models"
 	"ERP-ONSMART/backend/internal/modules/rental/service"
 	"net/http"
@@ -9,54 +10,58 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Registra um aluguel
 func CreateRentalHandler(c *gin.Context) {
 	var r models.Rental
 	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos", "details": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
-	if err := service.CreateRental(r); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao criar locação", "details": err.Error()})
+	if err := service.CreateRental(c.Request.Context(), r); err != nil {
+		c.Error(err).SetMeta("erro ao criar locação")
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"message": "Locação criada com sucesso"})
 }
 
+// Lista os aluguéis
 func ListRentalsHandler(c *gin.Context) {
-	rentals, err := service.ListRentals()
+	rentals, err := service.ListRentals(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao listar locações", "details": err.Error()})
+		c.Error(err).SetMeta("erro ao listar locações")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"rentals": rentals})
 }
 
+// Atualiza um aluguel
 func UpdateRentalHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 	var r models.Rental
 	if err := c.ShouldBindJSON(&r); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos", "details": err.Error()})
+		c.Error(errors.InvalidRequest(err))
 		return
 	}
-	if err := service.UpdateRental(id, r); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao atualizar locação", "details": err.Error()})
+	if err := service.UpdateRental(c.Request.Context(), id, r); err != nil {
+		c.Error(err).SetMeta("erro ao atualizar locação")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Locação atualizada com sucesso"})
 }
 
+// Remove um aluguel
 func DeleteRentalHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
-	if err := service.RemoveRental(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao deletar locação", "details": err.Error()})
+	if err := service.RemoveRental(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao deletar locação")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Locação deletada com sucesso"})
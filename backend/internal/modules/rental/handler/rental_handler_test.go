@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
 	"encoding/json"
 	"net/http"
@@ -26,6 +27,8 @@ func TestMain(m *testing.M) {
 func TestCreateRentalHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.TenantMiddleware())
 	r.POST("/rentals", CreateRentalHandler)
 
 	body := []byte(`{
@@ -38,6 +41,7 @@ func TestCreateRentalHandler(t *testing.T) {
 	}`)
 
 	req, _ := http.NewRequest("POST", "/rentals", bytes.NewBuffer(body))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	r.ServeHTTP(resp, req)
@@ -50,9 +54,12 @@ func TestCreateRentalHandler(t *testing.T) {
 func TestListRentalsHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.TenantMiddleware())
 	r.GET("/rentals", ListRentalsHandler)
 
 	req, _ := http.NewRequest("GET", "/rentals", nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 	r.ServeHTTP(resp, req)
 
@@ -64,6 +71,8 @@ func TestListRentalsHandler(t *testing.T) {
 func TestUpdateRentalHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.TenantMiddleware())
 	r.POST("/rentals", CreateRentalHandler)
 	r.PUT("/rentals/:id", UpdateRentalHandler)
 	r.GET("/rentals", ListRentalsHandler)
@@ -77,11 +86,13 @@ func TestUpdateRentalHandler(t *testing.T) {
 		"billing_type": "anual"
 	}`)
 	req, _ := http.NewRequest("POST", "/rentals", bytes.NewBuffer(createBody))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	r.ServeHTTP(resp, req)
 
 	reqList, _ := http.NewRequest("GET", "/rentals", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	respList := httptest.NewRecorder()
 	r.ServeHTTP(respList, reqList)
 
@@ -105,6 +116,7 @@ func TestUpdateRentalHandler(t *testing.T) {
 		"billing_type": "mensal"
 	}`)
 	reqUpdate, _ := http.NewRequest("PUT", "/rentals/"+strconv.Itoa(id), bytes.NewBuffer(updateBody))
+	reqUpdate.Header.Set(middleware.CompanyHeader, "1")
 	reqUpdate.Header.Set("Content-Type", "application/json")
 	respUpdate := httptest.NewRecorder()
 	r.ServeHTTP(respUpdate, reqUpdate)
@@ -117,6 +129,8 @@ func TestUpdateRentalHandler(t *testing.T) {
 func TestDeleteRentalHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.TenantMiddleware())
 	r.POST("/rentals", CreateRentalHandler)
 	r.DELETE("/rentals/:id", DeleteRentalHandler)
 	r.GET("/rentals", ListRentalsHandler)
@@ -130,11 +144,13 @@ func TestDeleteRentalHandler(t *testing.T) {
 		"billing_type": "trimestral"
 	}`)
 	req, _ := http.NewRequest("POST", "/rentals", bytes.NewBuffer(createBody))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	r.ServeHTTP(resp, req)
 
 	reqList, _ := http.NewRequest("GET", "/rentals", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	respList := httptest.NewRecorder()
 	r.ServeHTTP(respList, reqList)
 
@@ -150,6 +166,7 @@ func TestDeleteRentalHandler(t *testing.T) {
 	id := result.Rentals[len(result.Rentals)-1].ID
 
 	reqDel, _ := http.NewRequest("DELETE", "/rentals/"+strconv.Itoa(id), nil)
+	reqDel.Header.Set(middleware.CompanyHeader, "1")
 	delResp := httptest.NewRecorder()
 	r.ServeHTTP(delResp, reqDel)
 
@@ -2,30 +2,43 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/rental/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"fmt"
 )
 
-func InsertRental(r models.Rental) error {
+func InsertRental(ctx context.Context, r models.Rental) error {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return errors.ErrTenantRequired
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	_, err = conn.Exec(`INSERT INTO rentals (client_name, equipment, start_date, end_date, price, billing_type) VALUES ($1, $2, $3, $4, $5, $6)`,
-		r.ClientName, r.Equipment, r.StartDate, r.EndDate, r.Price, r.BillingType)
+	_, err = conn.ExecContext(ctx, `INSERT INTO rentals (client_name, equipment, start_date, end_date, price, billing_type, company_id) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		r.ClientName, r.Equipment, r.StartDate, r.EndDate, r.Price, r.BillingType, companyID)
 	return err
 }
 
-func GetAllRentals() ([]models.Rental, error) {
+func GetAllRentals(ctx context.Context) ([]models.Rental, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 
-	rows, err := conn.Query(`SELECT id, client_name, equipment, start_date, end_date, price, billing_type FROM rentals`)
+	rows, err := conn.QueryContext(ctx, `SELECT id, client_name, equipment, start_date, end_date, price, billing_type FROM rentals WHERE `+company)
 	if err != nil {
 		return nil, err
 	}
@@ -42,26 +55,36 @@ func GetAllRentals() ([]models.Rental, error) {
 	return rentals, nil
 }
 
-func UpdateRentalByID(id int, r models.Rental) error {
+func UpdateRentalByID(ctx context.Context, id int, r models.Rental) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	_, err = conn.Exec(`UPDATE rentals SET client_name=$1, equipment=$2, start_date=$3, end_date=$4, price=$5, billing_type=$6 WHERE id=$7`,
+	_, err = conn.ExecContext(ctx, `UPDATE rentals SET client_name=$1, equipment=$2, start_date=$3, end_date=$4, price=$5, billing_type=$6 WHERE id=$7 AND `+company,
 		r.ClientName, r.Equipment, r.StartDate, r.EndDate, r.Price, r.BillingType, id)
 	return err
 }
 
-func DeleteRentalByID(id int) error {
+func DeleteRentalByID(ctx context.Context, id int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	result, err := conn.Exec(`DELETE FROM rentals WHERE id = $1`, id)
+	result, err := conn.ExecContext(ctx, `DELETE FROM rentals WHERE id = $1 AND `+company, id)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/modules/rental/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"os"
 	"testing"
 
@@ -27,12 +29,12 @@ func TestInsertAndGetRentals(t *testing.T) {
 		Price:       100.0,
 		BillingType: "mensal",
 	}
-	err := InsertRental(r)
+	err := InsertRental(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), r)
 	if err != nil {
 		t.Fatalf("Erro ao inserir locação: %v", err)
 	}
 
-	rentals, err := GetAllRentals()
+	rentals, err := GetAllRentals(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao buscar locações: %v", err)
 	}
@@ -50,8 +52,8 @@ func TestUpdateRentalByID(t *testing.T) {
 		Price:       200,
 		BillingType: "anual",
 	}
-	InsertRental(r)
-	rentals, _ := GetAllRentals()
+	InsertRental(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), r)
+	rentals, _ := GetAllRentals(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	id := rentals[len(rentals)-1].ID
 
 	updated := models.Rental{
@@ -62,7 +64,7 @@ func TestUpdateRentalByID(t *testing.T) {
 		Price:       250,
 		BillingType: "trimestral",
 	}
-	err := UpdateRentalByID(id, updated)
+	err := UpdateRentalByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), id, updated)
 	if err != nil {
 		t.Errorf("Erro ao atualizar locação: %v", err)
 	}
@@ -77,11 +79,11 @@ func TestDeleteRentalByID(t *testing.T) {
 		Price:       999.99,
 		BillingType: "mensal",
 	}
-	InsertRental(r)
-	rentals, _ := GetAllRentals()
+	InsertRental(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), r)
+	rentals, _ := GetAllRentals(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	id := rentals[len(rentals)-1].ID
 
-	err := DeleteRentalByID(id)
+	err := DeleteRentalByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), id)
 	if err != nil {
 		t.Errorf("Erro ao deletar locação: %v", err)
 	}
@@ -3,20 +3,21 @@ package service
 import (
 	"ERP-ONSMART/backend/internal/modules/rental/models"
 	"ERP-ONSMART/backend/internal/modules/rental/repository"
+	"context"
 )
 
-func CreateRental(r models.Rental) error {
-	return repository.InsertRental(r)
+func CreateRental(ctx context.Context, r models.Rental) error {
+	return repository.InsertRental(ctx, r)
 }
 
-func ListRentals() ([]models.Rental, error) {
-	return repository.GetAllRentals()
+func ListRentals(ctx context.Context) ([]models.Rental, error) {
+	return repository.GetAllRentals(ctx)
 }
 
-func UpdateRental(id int, r models.Rental) error {
-	return repository.UpdateRentalByID(id, r)
+func UpdateRental(ctx context.Context, id int, r models.Rental) error {
+	return repository.UpdateRentalByID(ctx, id, r)
 }
 
-func RemoveRental(id int) error {
-	return repository.DeleteRentalByID(id)
+func RemoveRental(ctx context.Context, id int) error {
+	return repository.DeleteRentalByID(ctx, id)
 }
@@ -2,6 +2,8 @@ package service
 
 import (
 	"ERP-ONSMART/backend/internal/modules/rental/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"os"
 	"testing"
 
@@ -27,10 +29,10 @@ func TestCreateAndListRentals(t *testing.T) {
 		Price:       450,
 		BillingType: "mensal",
 	}
-	if err := CreateRental(r); err != nil {
+	if err := CreateRental(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), r); err != nil {
 		t.Fatalf("Erro ao criar locação: %v", err)
 	}
-	list, err := ListRentals()
+	list, err := ListRentals(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil || len(list) == 0 {
 		t.Errorf("Erro ao listar locações ou lista vazia")
 	}
@@ -45,8 +47,8 @@ func TestUpdateAndDeleteRental(t *testing.T) {
 		Price:       200,
 		BillingType: "anual",
 	}
-	CreateRental(r)
-	rentals, _ := ListRentals()
+	CreateRental(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), r)
+	rentals, _ := ListRentals(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	id := rentals[len(rentals)-1].ID
 
 	rUpdated := models.Rental{
@@ -57,11 +59,11 @@ func TestUpdateAndDeleteRental(t *testing.T) {
 		Price:       300,
 		BillingType: "mensal",
 	}
-	if err := UpdateRental(id, rUpdated); err != nil {
+	if err := UpdateRental(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), id, rUpdated); err != nil {
 		t.Errorf("Erro ao atualizar locação: %v", err)
 	}
 
-	if err := RemoveRental(id); err != nil {
+	if err := RemoveRental(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), id); err != nil {
 		t.Errorf("Erro ao deletar locação: %v", err)
 	}
 }
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	fieldPermissions "ERP-ONSMART/backend/internal/modules/fieldpermissions/service"
+	"ERP-ONSMART/backend/internal/modules/reports/models"
+	"ERP-ONSMART/backend/internal/modules/reports/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as entidades, campos e tipos disponíveis para compor relatórios
+// @Security BearerAuth
+func ListReportEntitiesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"entities": service.ListEntities()})
+}
+
+// Lista os relatórios definidos na empresa
+// @Security BearerAuth
+func ListReportsHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.ListReports(c.Request.Context(), &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar relatórios")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna a definição de um relatório
+// @Security BearerAuth
+// @Param id path int true "ID do relatório"
+func GetReportHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := service.GetReport(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar relatório")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// Cria um relatório: entidade, filtros, agrupamentos (campo ou data:day|week|month|year) e
+// agregações (count, sum, avg, min, max), ou colunas para a listagem detalhada
+// @Security BearerAuth
+func CreateReportHandler(c *gin.Context) {
+	var input models.ReportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	report, err := service.CreateReport(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar relatório")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"report": report})
+}
+
+// Altera o nome, a descrição e a definição do relatório
+// @Security BearerAuth
+// @Param id path int true "ID do relatório"
+func UpdateReportHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ReportInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	report, err := service.UpdateReport(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar relatório")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// Remove o relatório e seus agendamentos
+// @Security BearerAuth
+// @Param id path int true "ID do relatório"
+func DeleteReportHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteReport(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover relatório")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Relatório removido com sucesso"})
+}
+
+// Executa o relatório na empresa atual; format=csv ou pdf devolve o arquivo para download.
+// Colunas de campos restritos ao perfil (permissões de campos) saem ocultas ou vazias.
+// @Security BearerAuth
+// @Param id path int true "ID do relatório"
+// @Param format query string false "json (padrão), csv ou pdf"
+func RunReportHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	policy := fieldPermissions.PolicyFor(middleware.CurrentRole(c))
+
+	format := c.DefaultQuery("format", models.FormatJSON)
+	if format == models.FormatJSON {
+		report, result, err := service.RunReport(c.Request.Context(), id, policy)
+		if err != nil {
+			c.Error(err).SetMeta("erro ao executar relatório")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"report": report, "result": result})
+		return
+	}
+
+	output, err := service.ExportReport(c.Request.Context(), id, format, policy)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao exportar relatório")
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": output.FileName}))
+	c.Data(http.StatusOK, output.ContentType, output.Data)
+}
+
+// Lista os envios agendados do relatório
+// @Security BearerAuth
+// @Param id path int true "ID do relatório"
+func ListReportSchedulesHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	schedules, err := service.ListSchedules(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar agendamentos do relatório")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+// Agenda o envio do relatório por e-mail (diário, semanal às segundas ou mensal no dia 1), na
+// hora informada, em CSV ou PDF
+// @Security BearerAuth
+// @Param id path int true "ID do relatório"
+func CreateReportScheduleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	schedule, err := service.CreateSchedule(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao agendar relatório")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"schedule": schedule})
+}
+
+// Remove um envio agendado do relatório
+// @Security BearerAuth
+// @Param id path int true "ID do relatório"
+// @Param schedule_id path int true "ID do agendamento"
+func DeleteReportScheduleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	scheduleID, ok := parseIDParam(c, "schedule_id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteSchedule(c.Request.Context(), id, scheduleID); err != nil {
+		c.Error(err).SetMeta("erro ao remover agendamento do relatório")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Agendamento removido com sucesso"})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
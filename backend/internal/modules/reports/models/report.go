@@ -0,0 +1,464 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tipos dos campos das entidades, que definem os filtros e agregações aceitos
+const (
+	FieldText   = "text"
+	FieldNumber = "number"
+	FieldDate   = "date"
+)
+
+// Limites das linhas devolvidas por execução
+const (
+	DefaultLimit = 1000
+	MaxLimit     = 10000
+)
+
+// Field é um campo de uma entidade disponível nos relatórios. O nome é a chave JSON da API, o que
+// permite aplicar as permissões de campos por perfil às colunas do relatório. Instant marca as
+// datas com horário (created_at), gravadas em UTC: os filtros e agrupamentos por dia as
+// convertem para o fuso da empresa; as demais datas já são o dia de calendário.
+type Field struct {
+	Column  string `json:"-"`
+	Type    string `json:"type"`
+	Instant bool   `json:"-"`
+}
+
+// Entity é uma tabela que pode ser consultada pelos relatórios
+type Entity struct {
+	Name       string           `json:"name"`
+	Label      string           `json:"label"`
+	Table      string           `json:"-"`
+	SoftDelete bool             `json:"-"`
+	Fields     map[string]Field `json:"fields"`
+}
+
+func documentFields(extra map[string]Field) map[string]Field {
+	fields := map[string]Field{
+		"id":             {Column: "id", Type: FieldNumber},
+		"contact_id":     {Column: "contact_id", Type: FieldNumber},
+		"status":         {Column: "status", Type: FieldText},
+		"created_at":     {Column: "created_at", Type: FieldDate, Instant: true},
+		"subtotal":       {Column: "subtotal", Type: FieldNumber},
+		"tax_total":      {Column: "tax_total", Type: FieldNumber},
+		"grand_total":    {Column: "grand_total", Type: FieldNumber},
+		"discount_total": {Column: "discount_total", Type: FieldNumber},
+	}
+	for name, field := range extra {
+		fields[name] = field
+	}
+	return fields
+}
+
+// Entities são as entidades aceitas nas definições de relatório
+var Entities = map[string]Entity{
+	"sales_processes": {
+		Name: "sales_processes", Label: "Processos de venda", Table: "sales_processes",
+		Fields: map[string]Field{
+			"id":          {Column: "id", Type: FieldNumber},
+			"contact_id":  {Column: "contact_id", Type: FieldNumber},
+			"status":      {Column: "status", Type: FieldText},
+			"created_at":  {Column: "created_at", Type: FieldDate, Instant: true},
+			"total_value": {Column: "total_value", Type: FieldNumber},
+			"profit":      {Column: "profit", Type: FieldNumber},
+		},
+	},
+	"quotations": {
+		Name: "quotations", Label: "Cotações", Table: "quotations", SoftDelete: true,
+		Fields: documentFields(map[string]Field{
+			"quotation_no":   {Column: "quotation_no", Type: FieldText},
+			"salesperson_id": {Column: "salesperson_id", Type: FieldNumber},
+			"expiry_date":    {Column: "expiry_date", Type: FieldDate},
+		}),
+	},
+	"sales_orders": {
+		Name: "sales_orders", Label: "Pedidos de venda", Table: "sales_orders", SoftDelete: true,
+		Fields: documentFields(map[string]Field{
+			"so_no":          {Column: "so_no", Type: FieldText},
+			"salesperson_id": {Column: "salesperson_id", Type: FieldNumber},
+			"expected_date":  {Column: "expected_date", Type: FieldDate},
+		}),
+	},
+	"invoices": {
+		Name: "invoices", Label: "Faturas", Table: "invoices", SoftDelete: true,
+		Fields: documentFields(map[string]Field{
+			"invoice_no":     {Column: "invoice_no", Type: FieldText},
+			"salesperson_id": {Column: "salesperson_id", Type: FieldNumber},
+			"issue_date":     {Column: "issue_date", Type: FieldDate},
+			"due_date":       {Column: "due_date", Type: FieldDate},
+			"amount_paid":    {Column: "amount_paid", Type: FieldNumber},
+		}),
+	},
+	"purchase_orders": {
+		Name: "purchase_orders", Label: "Pedidos de compra", Table: "purchase_orders",
+		Fields: documentFields(map[string]Field{
+			"po_no":         {Column: "po_no", Type: FieldText},
+			"expected_date": {Column: "expected_date", Type: FieldDate},
+		}),
+	},
+	"supplier_bills": {
+		Name: "supplier_bills", Label: "Contas a pagar", Table: "supplier_bills",
+		Fields: map[string]Field{
+			"id":          {Column: "id", Type: FieldNumber},
+			"bill_no":     {Column: "bill_no", Type: FieldText},
+			"contact_id":  {Column: "contact_id", Type: FieldNumber},
+			"status":      {Column: "status", Type: FieldText},
+			"created_at":  {Column: "created_at", Type: FieldDate, Instant: true},
+			"issue_date":  {Column: "issue_date", Type: FieldDate},
+			"due_date":    {Column: "due_date", Type: FieldDate},
+			"subtotal":    {Column: "subtotal", Type: FieldNumber},
+			"tax_total":   {Column: "tax_total", Type: FieldNumber},
+			"grand_total": {Column: "grand_total", Type: FieldNumber},
+			"amount_paid": {Column: "amount_paid", Type: FieldNumber},
+		},
+	},
+	"products": {
+		Name: "products", Label: "Produtos", Table: "products", SoftDelete: true,
+		Fields: map[string]Field{
+			"id":          {Column: "id", Type: FieldNumber},
+			"name":        {Column: "name", Type: FieldText},
+			"sku":         {Column: "sku", Type: FieldText},
+			"status":      {Column: "status", Type: FieldText},
+			"created_at":  {Column: "created_at", Type: FieldDate, Instant: true},
+			"price":       {Column: "price", Type: FieldNumber},
+			"sales_price": {Column: "sales_price", Type: FieldNumber},
+			"cost_price":  {Column: "cost_price", Type: FieldNumber},
+			"stock":       {Column: "stock", Type: FieldNumber},
+		},
+	},
+}
+
+// Operadores aceitos nos filtros
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpIn       = "in"
+	OpContains = "contains"
+	// OpLastDays filtra datas dos últimos N dias, contados a partir da execução; é o filtro
+	// usado nos relatórios agendados
+	OpLastDays = "last_days"
+)
+
+var comparisons = map[string]string{OpEq: "=", OpNe: "<>", OpGt: ">", OpGte: ">=", OpLt: "<", OpLte: "<="}
+
+// Funções de agregação aceitas
+var aggregateFuncs = map[string]bool{"count": true, "sum": true, "avg": true, "min": true, "max": true}
+
+// Agrupamentos de datas aceitos em group_by (campo:periodo)
+var datePeriods = map[string]bool{"day": true, "week": true, "month": true, "year": true}
+
+var aliasPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// Filter restringe as linhas consultadas
+type Filter struct {
+	Field string      `json:"field"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// Aggregation calcula um valor por grupo (ou no total, sem group_by)
+type Aggregation struct {
+	Func  string `json:"func"`
+	Field string `json:"field,omitempty"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// Definition descreve a consulta de um relatório. Com agregações, group_by define as colunas de
+// agrupamento; sem elas, columns lista os campos das linhas detalhadas.
+type Definition struct {
+	Entity       string        `json:"entity" binding:"required"`
+	Columns      []string      `json:"columns,omitempty"`
+	Filters      []Filter      `json:"filters,omitempty"`
+	GroupBy      []string      `json:"group_by,omitempty"`
+	Aggregations []Aggregation `json:"aggregations,omitempty"`
+	// Colunas do resultado; "-" no início indica ordem decrescente
+	Sort  []string `json:"sort,omitempty"`
+	Limit int      `json:"limit,omitempty"`
+}
+
+// Report é uma definição de relatório gravada pelos administradores
+type Report struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	CompanyID   int        `json:"company_id" gorm:"<-:create"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Definition  Definition `json:"definition" gorm:"serializer:json"`
+	CreatedBy   string     `json:"created_by"`
+	UpdatedBy   string     `json:"updated_by"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Report) TableName() string {
+	return "reports"
+}
+
+// ReportInput é o corpo aceito na criação e na alteração de relatórios
+type ReportInput struct {
+	Name        string     `json:"name" binding:"required,max=150"`
+	Description string     `json:"description" binding:"max=1000"`
+	Definition  Definition `json:"definition" binding:"required"`
+}
+
+// Column é uma coluna do resultado e o campo da entidade de onde ela vem (vazio em count)
+type Column struct {
+	Name  string `json:"name"`
+	Field string `json:"field,omitempty"`
+}
+
+// Condition é um trecho do WHERE com seus parâmetros
+type Condition struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Plan é a consulta montada a partir da definição; todos os identificadores vêm do catálogo de
+// entidades, e os valores dos filtros seguem como parâmetros
+type Plan struct {
+	Table      string
+	SoftDelete bool
+	Selects    []string
+	Columns    []Column
+	Conditions []Condition
+	GroupBy    []string
+	OrderBy    []string
+	Limit      int
+}
+
+// Plan valida a definição e monta a consulta. now, no fuso da empresa, é a referência dos
+// filtros last_days e dos agrupamentos por período das datas com horário.
+func (d Definition) Plan(now time.Time) (*Plan, error) {
+	entity, ok := Entities[d.Entity]
+	if !ok {
+		return nil, invalid("entidade desconhecida %q (aceitas: %s)", d.Entity, strings.Join(EntityNames(), ", "))
+	}
+
+	plan := &Plan{Table: entity.Table, SoftDelete: entity.SoftDelete, Limit: d.Limit}
+	if plan.Limit <= 0 {
+		plan.Limit = DefaultLimit
+	}
+	if plan.Limit > MaxLimit {
+		return nil, invalid("limit acima de %d", MaxLimit)
+	}
+
+	for _, filter := range d.Filters {
+		condition, err := entity.condition(filter, now)
+		if err != nil {
+			return nil, err
+		}
+		plan.Conditions = append(plan.Conditions, condition)
+	}
+
+	if len(d.Aggregations) == 0 {
+		if len(d.GroupBy) > 0 {
+			return nil, invalid("group_by exige ao menos uma agregação")
+		}
+		if len(d.Columns) == 0 {
+			return nil, invalid("informe columns ou aggregations")
+		}
+		for _, name := range d.Columns {
+			field, err := entity.field(name)
+			if err != nil {
+				return nil, err
+			}
+			plan.add(entity.Table+"."+field.Column, Column{Name: name, Field: name})
+		}
+	} else {
+		if len(d.Columns) > 0 {
+			return nil, invalid("columns não pode ser usado com aggregations; use group_by")
+		}
+		for _, group := range d.GroupBy {
+			expr, column, err := entity.group(group, now.Location())
+			if err != nil {
+				return nil, err
+			}
+			plan.add(expr, column)
+			plan.GroupBy = append(plan.GroupBy, expr)
+		}
+		for _, aggregation := range d.Aggregations {
+			expr, column, err := entity.aggregate(aggregation)
+			if err != nil {
+				return nil, err
+			}
+			plan.add(expr, column)
+		}
+	}
+
+	names := map[string]bool{}
+	for _, column := range plan.Columns {
+		if names[column.Name] {
+			return nil, invalid("coluna repetida %q; use alias", column.Name)
+		}
+		names[column.Name] = true
+	}
+	for _, item := range d.Sort {
+		name := strings.TrimPrefix(item, "-")
+		if !names[name] {
+			return nil, invalid("sort por coluna fora do resultado: %q", name)
+		}
+		direction := "ASC"
+		if strings.HasPrefix(item, "-") {
+			direction = "DESC"
+		}
+		plan.OrderBy = append(plan.OrderBy, fmt.Sprintf("%q %s", name, direction))
+	}
+	return plan, nil
+}
+
+func (p *Plan) add(expr string, column Column) {
+	p.Selects = append(p.Selects, fmt.Sprintf("%s AS %q", expr, column.Name))
+	p.Columns = append(p.Columns, column)
+}
+
+func (e Entity) field(name string) (Field, error) {
+	field, ok := e.Fields[name]
+	if !ok {
+		return Field{}, invalid("campo %q não existe em %s", name, e.Name)
+	}
+	return field, nil
+}
+
+func (e Entity) condition(filter Filter, now time.Time) (Condition, error) {
+	field, err := e.field(filter.Field)
+	if err != nil {
+		return Condition{}, err
+	}
+	column := e.Table + "." + field.Column
+
+	if sqlOp, ok := comparisons[filter.Op]; ok {
+		if !scalar(filter.Value) {
+			return Condition{}, invalid("filtro %s %s exige um valor simples", filter.Field, filter.Op)
+		}
+		return Condition{SQL: fmt.Sprintf("%s %s ?", column, sqlOp), Args: []interface{}{filter.Value}}, nil
+	}
+
+	switch filter.Op {
+	case OpIn:
+		values, ok := filter.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return Condition{}, invalid("filtro %s in exige uma lista de valores", filter.Field)
+		}
+		for _, value := range values {
+			if !scalar(value) {
+				return Condition{}, invalid("filtro %s in exige valores simples", filter.Field)
+			}
+		}
+		return Condition{SQL: column + " IN ?", Args: []interface{}{values}}, nil
+	case OpContains:
+		text, ok := filter.Value.(string)
+		if field.Type != FieldText || !ok {
+			return Condition{}, invalid("filtro contains só vale para campos de texto")
+		}
+		return Condition{SQL: column + " ILIKE ?", Args: []interface{}{"%" + text + "%"}}, nil
+	case OpLastDays:
+		days, ok := filter.Value.(float64)
+		if field.Type != FieldDate || !ok || days < 1 || days != float64(int(days)) {
+			return Condition{}, invalid("filtro last_days exige um campo de data e um número inteiro de dias")
+		}
+		from := localtime.Date(now).AddDate(0, 0, -int(days))
+		if field.Instant {
+			from = localtime.StartOfDay(from, now.Location())
+		}
+		return Condition{SQL: column + " >= ?", Args: []interface{}{from}}, nil
+	default:
+		return Condition{}, invalid("operador %q inválido", filter.Op)
+	}
+}
+
+func (e Entity) group(group string, loc *time.Location) (string, Column, error) {
+	name, period, hasPeriod := strings.Cut(group, ":")
+	field, err := e.field(name)
+	if err != nil {
+		return "", Column{}, err
+	}
+	column := e.Table + "." + field.Column
+	if !hasPeriod {
+		return column, Column{Name: name, Field: name}, nil
+	}
+	if field.Type != FieldDate || !datePeriods[period] {
+		return "", Column{}, invalid("agrupamento %q inválido (datas aceitam day, week, month e year)", group)
+	}
+	if field.Instant {
+		column = inLocation(column, loc)
+	}
+	return fmt.Sprintf("date_trunc('%s', %s)", period, column), Column{Name: name + "_" + period, Field: name}, nil
+}
+
+// inLocation converte a coluna com horário em UTC para o horário local do fuso
+func inLocation(column string, loc *time.Location) string {
+	name := loc.String()
+	if loc == time.UTC || name == "UTC" || name == "Local" {
+		return column
+	}
+	return fmt.Sprintf("(%s AT TIME ZONE 'UTC' AT TIME ZONE '%s')", column, strings.ReplaceAll(name, "'", "''"))
+}
+
+func (e Entity) aggregate(aggregation Aggregation) (string, Column, error) {
+	if !aggregateFuncs[aggregation.Func] {
+		return "", Column{}, invalid("agregação %q inválida (aceitas: count, sum, avg, min, max)", aggregation.Func)
+	}
+	if aggregation.Alias != "" && !aliasPattern.MatchString(aggregation.Alias) {
+		return "", Column{}, invalid("alias %q inválido", aggregation.Alias)
+	}
+
+	column := Column{Name: aggregation.Alias}
+	var expr string
+	if aggregation.Field == "" {
+		if aggregation.Func != "count" {
+			return "", Column{}, invalid("a agregação %s exige um campo", aggregation.Func)
+		}
+		expr = "COUNT(*)"
+		if column.Name == "" {
+			column.Name = "count"
+		}
+	} else {
+		field, err := e.field(aggregation.Field)
+		if err != nil {
+			return "", Column{}, err
+		}
+		if field.Type != FieldNumber && (aggregation.Func == "sum" || aggregation.Func == "avg") {
+			return "", Column{}, invalid("%s só vale para campos numéricos", aggregation.Func)
+		}
+		expr = fmt.Sprintf("%s(%s.%s)", strings.ToUpper(aggregation.Func), e.Table, field.Column)
+		column.Field = aggregation.Field
+		if column.Name == "" {
+			column.Name = aggregation.Func + "_" + aggregation.Field
+		}
+	}
+	return expr, column, nil
+}
+
+func scalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64, bool:
+		return true
+	}
+	return false
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errors.ErrInvalidReportDefinition, fmt.Sprintf(format, args...))
+}
+
+// EntityNames lista as entidades aceitas, em ordem alfabética
+func EntityNames() []string {
+	names := make([]string, 0, len(Entities))
+	for name := range Entities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var planNow = time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC)
+
+func TestPlanAggregatedReport(t *testing.T) {
+	definition := Definition{
+		Entity: "invoices",
+		Filters: []Filter{
+			{Field: "status", Op: OpIn, Value: []interface{}{"sent", "paid"}},
+			{Field: "issue_date", Op: OpLastDays, Value: float64(30)},
+		},
+		GroupBy:      []string{"status", "issue_date:month"},
+		Aggregations: []Aggregation{{Func: "count"}, {Func: "sum", Field: "grand_total", Alias: "revenue"}},
+		Sort:         []string{"-revenue"},
+	}
+
+	plan, err := definition.Plan(planNow)
+	require.NoError(t, err)
+	assert.Equal(t, "invoices", plan.Table)
+	assert.True(t, plan.SoftDelete)
+	assert.Equal(t, []string{
+		`invoices.status AS "status"`,
+		`date_trunc('month', invoices.issue_date) AS "issue_date_month"`,
+		`COUNT(*) AS "count"`,
+		`SUM(invoices.grand_total) AS "revenue"`,
+	}, plan.Selects)
+	assert.Equal(t, []Column{
+		{Name: "status", Field: "status"},
+		{Name: "issue_date_month", Field: "issue_date"},
+		{Name: "count"},
+		{Name: "revenue", Field: "grand_total"},
+	}, plan.Columns)
+	assert.Equal(t, []string{"invoices.status", "date_trunc('month', invoices.issue_date)"}, plan.GroupBy)
+	assert.Equal(t, []string{`"revenue" DESC`}, plan.OrderBy)
+	assert.Equal(t, DefaultLimit, plan.Limit)
+
+	require.Len(t, plan.Conditions, 2)
+	assert.Equal(t, "invoices.status IN ?", plan.Conditions[0].SQL)
+	assert.Equal(t, "invoices.issue_date >= ?", plan.Conditions[1].SQL)
+	assert.Equal(t, time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC), plan.Conditions[1].Args[0])
+}
+
+func TestPlanDetailReport(t *testing.T) {
+	definition := Definition{
+		Entity:  "products",
+		Columns: []string{"name", "cost_price"},
+		Filters: []Filter{{Field: "name", Op: OpContains, Value: "cabo"}},
+		Limit:   50,
+	}
+
+	plan, err := definition.Plan(planNow)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`products.name AS "name"`, `products.cost_price AS "cost_price"`}, plan.Selects)
+	assert.Equal(t, "products.name ILIKE ?", plan.Conditions[0].SQL)
+	assert.Equal(t, []interface{}{"%cabo%"}, plan.Conditions[0].Args)
+	assert.Equal(t, 50, plan.Limit)
+}
+
+func TestPlanRejectsInvalidDefinitions(t *testing.T) {
+	cases := map[string]Definition{
+		"entidade":           {Entity: "users", Columns: []string{"id"}},
+		"campo":              {Entity: "invoices", Columns: []string{"password"}},
+		"sem colunas":        {Entity: "invoices"},
+		"group sem agregado": {Entity: "invoices", GroupBy: []string{"status"}},
+		"agregação":          {Entity: "invoices", Aggregations: []Aggregation{{Func: "median", Field: "grand_total"}}},
+		"sum em texto":       {Entity: "invoices", Aggregations: []Aggregation{{Func: "sum", Field: "status"}}},
+		"periodo em texto":   {Entity: "invoices", GroupBy: []string{"status:month"}, Aggregations: []Aggregation{{Func: "count"}}},
+		"alias":              {Entity: "invoices", Aggregations: []Aggregation{{Func: "count", Alias: `x"; DROP`}}},
+		"operador":           {Entity: "invoices", Columns: []string{"id"}, Filters: []Filter{{Field: "id", Op: "like", Value: "1"}}},
+		"valor composto":     {Entity: "invoices", Columns: []string{"id"}, Filters: []Filter{{Field: "id", Op: OpEq, Value: []interface{}{1.0}}}},
+		"last_days":          {Entity: "invoices", Columns: []string{"id"}, Filters: []Filter{{Field: "status", Op: OpLastDays, Value: 7.0}}},
+		"sort":               {Entity: "invoices", Columns: []string{"id"}, Sort: []string{"grand_total"}},
+		"coluna repetida":    {Entity: "invoices", Aggregations: []Aggregation{{Func: "count"}, {Func: "count"}}},
+		"limit":              {Entity: "invoices", Columns: []string{"id"}, Limit: MaxLimit + 1},
+	}
+	for name, definition := range cases {
+		_, err := definition.Plan(planNow)
+		assert.ErrorIs(t, err, appErrors.ErrInvalidReportDefinition, name)
+	}
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	fieldpermissions "ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+	"ERP-ONSMART/backend/internal/pdf"
+)
+
+// Formatos de saída dos relatórios
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatPDF  = "pdf"
+)
+
+// Result é o resultado de uma execução
+type Result struct {
+	Columns []Column        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// Output é o arquivo gerado por uma execução (CSV ou PDF)
+type Output struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// ApplyPolicy aplica as permissões de campos do perfil às colunas: hide remove a coluna e mask
+// deixa os valores vazios
+func (r *Result) ApplyPolicy(policy fieldpermissions.Policy) {
+	if len(policy) == 0 {
+		return
+	}
+
+	kept := make([]int, 0, len(r.Columns))
+	columns := make([]Column, 0, len(r.Columns))
+	for i, column := range r.Columns {
+		if policy[column.Field] == fieldpermissions.ActionHide {
+			continue
+		}
+		kept = append(kept, i)
+		columns = append(columns, column)
+	}
+
+	for rowIndex, row := range r.Rows {
+		filtered := make([]interface{}, 0, len(kept))
+		for _, i := range kept {
+			value := row[i]
+			if policy[r.Columns[i].Field] == fieldpermissions.ActionMask {
+				value = nil
+			}
+			filtered = append(filtered, value)
+		}
+		r.Rows[rowIndex] = filtered
+	}
+	r.Columns = columns
+}
+
+// Render gera o arquivo do resultado no formato pedido (csv ou pdf)
+func Render(report *Report, result *Result, format string, now time.Time) (*Output, error) {
+	name := fileName(report.Name, now)
+	switch format {
+	case FormatCSV:
+		data, err := renderCSV(result)
+		if err != nil {
+			return nil, err
+		}
+		return &Output{FileName: name + ".csv", ContentType: "text/csv; charset=utf-8", Data: data}, nil
+	case FormatPDF:
+		data, err := renderPDF(report, result, now)
+		if err != nil {
+			return nil, err
+		}
+		return &Output{FileName: name + ".pdf", ContentType: "application/pdf", Data: data}, nil
+	default:
+		return nil, invalid("formato %q inválido (aceitos: json, csv, pdf)", format)
+	}
+}
+
+func renderCSV(result *Result) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := make([]string, len(result.Columns))
+	for i, column := range result.Columns {
+		header[i] = column.Name
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	for _, row := range result.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = FormatValue(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// Largura útil da página A4 (descontadas as margens), dividida entre as colunas do relatório
+const pdfTableWidth = 495.0
+
+func renderPDF(report *Report, result *Result, now time.Time) ([]byte, error) {
+	doc := pdf.NewDocument()
+	doc.Line(report.Name, 16, true)
+	if report.Description != "" {
+		doc.Line(report.Description, 10, false)
+	}
+	doc.Line("Gerado em "+now.Format("02/01/2006 15:04"), 9, false)
+	doc.Space(8)
+
+	width := pdfTableWidth
+	if len(result.Columns) > 0 {
+		width = pdfTableWidth / float64(len(result.Columns))
+	}
+	// Cerca de 5 pontos por caractere no corpo 9
+	maxChars := int(width/5) - 1
+
+	header := make([]pdf.Column, len(result.Columns))
+	for i, column := range result.Columns {
+		header[i] = pdf.Column{X: float64(i) * width, Text: truncate(column.Name, maxChars)}
+	}
+	doc.Row(9, true, header...)
+	doc.Rule()
+	for _, row := range result.Rows {
+		cells := make([]pdf.Column, len(row))
+		for i, value := range row {
+			cells[i] = pdf.Column{X: float64(i) * width, Text: truncate(FormatValue(value), maxChars)}
+		}
+		doc.Row(9, false, cells...)
+	}
+	doc.Rule()
+	doc.Line(fmt.Sprintf("%d linha(s)", len(result.Rows)), 9, false)
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatValue converte o valor da coluna em texto: números sem zeros à direita, datas no formato
+// ISO e valores mascarados ou nulos como vazio
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// truncate corta o texto que não cabe na coluna do PDF (a fonte padrão não tem reticências)
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if max < 4 || len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+// unaccent troca as letras acentuadas do português pela letra sem acento nos nomes de arquivo
+var unaccent = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c",
+)
+
+// fileName monta o nome do arquivo a partir do nome do relatório e da data da execução
+func fileName(name string, now time.Time) string {
+	var b strings.Builder
+	for _, r := range unaccent.Replace(strings.ToLower(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = "relatorio"
+	}
+	return slug + "-" + now.Format("20060102")
+}
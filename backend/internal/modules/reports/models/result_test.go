@@ -0,0 +1,63 @@
+package models
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	fieldpermissions "ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleResult() *Result {
+	return &Result{
+		Columns: []Column{{Name: "status", Field: "status"}, {Name: "sum_profit", Field: "profit"}, {Name: "max_cost_price", Field: "cost_price"}},
+		Rows: [][]interface{}{
+			{"won", 1250.5, 80.0},
+			{"lost", 0.0, nil},
+		},
+	}
+}
+
+func TestApplyPolicyHidesAndMasksColumns(t *testing.T) {
+	result := sampleResult()
+	result.ApplyPolicy(fieldpermissions.Policy{"profit": fieldpermissions.ActionHide, "cost_price": fieldpermissions.ActionMask})
+
+	assert.Equal(t, []Column{{Name: "status", Field: "status"}, {Name: "max_cost_price", Field: "cost_price"}}, result.Columns)
+	assert.Equal(t, [][]interface{}{{"won", nil}, {"lost", nil}}, result.Rows)
+}
+
+func TestRenderCSV(t *testing.T) {
+	report := &Report{Name: "Lucro por situação"}
+	output, err := Render(report, sampleResult(), FormatCSV, time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, "lucro-por-situacao-20261015.csv", output.FileName)
+	assert.Equal(t, "text/csv; charset=utf-8", output.ContentType)
+	assert.Equal(t, "status,sum_profit,max_cost_price\nwon,1250.5,80\nlost,0,\n", string(output.Data))
+}
+
+func TestRenderPDF(t *testing.T) {
+	output, err := Render(&Report{Name: "Vendas"}, sampleResult(), FormatPDF, time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, "application/pdf", output.ContentType)
+	assert.True(t, bytes.HasPrefix(output.Data, []byte("%PDF-")))
+	assert.Contains(t, string(output.Data), "(sum_profit)")
+	assert.Contains(t, string(output.Data), "(1250.5)")
+}
+
+func TestRenderRejectsUnknownFormat(t *testing.T) {
+	_, err := Render(&Report{Name: "Vendas"}, sampleResult(), "xlsx", time.Now())
+	assert.Error(t, err)
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "", FormatValue(nil))
+	assert.Equal(t, "10.25", FormatValue(10.25))
+	assert.Equal(t, "2026-10-01", FormatValue(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2026-10-01T09:30:00Z", FormatValue(time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)))
+	assert.Equal(t, "true", FormatValue(true))
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Frequências de envio dos relatórios agendados
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Schedule envia o relatório por e-mail periodicamente, no formato escolhido
+type Schedule struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	CompanyID  int        `json:"company_id" gorm:"<-:create"`
+	ReportID   int        `json:"report_id"`
+	Format     string     `json:"format"`
+	Frequency  string     `json:"frequency"`
+	Hour       int        `json:"hour"`
+	Recipients []string   `json:"recipients" gorm:"serializer:json"`
+	Active     bool       `json:"active"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Report *Report `json:"report,omitempty" gorm:"foreignKey:ReportID"`
+}
+
+func (Schedule) TableName() string {
+	return "report_schedules"
+}
+
+// ScheduleInput é o corpo aceito em POST /reports/:id/schedules
+type ScheduleInput struct {
+	Format     string   `json:"format" binding:"required,oneof=csv pdf"`
+	Frequency  string   `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	Hour       int      `json:"hour" binding:"gte=0,lte=23"`
+	Recipients []string `json:"recipients" binding:"required,min=1,max=20,dive,email"`
+}
+
+// NormalizeRecipients remove espaços e endereços repetidos; false se algum for inválido
+func NormalizeRecipients(recipients []string) ([]string, bool) {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(strings.TrimSpace(recipient))
+		if err != nil {
+			return nil, false
+		}
+		email := strings.ToLower(address.Address)
+		if !seen[email] {
+			seen[email] = true
+			normalized = append(normalized, email)
+		}
+	}
+	return normalized, len(normalized) > 0
+}
+
+// NextRun calcula o próximo envio, na hora do dia escolhida, estritamente depois de after.
+// Os envios semanais saem às segundas-feiras e os mensais no primeiro dia do mês. A hora e o dia
+// seguem o fuso de after (o da empresa).
+func NextRun(frequency string, hour int, after time.Time) time.Time {
+	run := time.Date(after.Year(), after.Month(), after.Day(), hour, 0, 0, 0, after.Location())
+	switch frequency {
+	case FrequencyWeekly:
+		offset := (int(time.Monday) - int(run.Weekday()) + 7) % 7
+		run = run.AddDate(0, 0, offset)
+		if !run.After(after) {
+			run = run.AddDate(0, 0, 7)
+		}
+	case FrequencyMonthly:
+		run = time.Date(after.Year(), after.Month(), 1, hour, 0, 0, 0, after.Location())
+		if !run.After(after) {
+			run = run.AddDate(0, 1, 0)
+		}
+	default:
+		if !run.After(after) {
+			run = run.AddDate(0, 0, 1)
+		}
+	}
+	return run
+}
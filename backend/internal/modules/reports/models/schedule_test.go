@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextRun(t *testing.T) {
+	// Quinta-feira, 15/10/2026, 14h30
+	now := time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC), NextRun(FrequencyDaily, 18, now))
+	assert.Equal(t, time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC), NextRun(FrequencyDaily, 8, now))
+	assert.Equal(t, time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC), NextRun(FrequencyWeekly, 8, now))
+	assert.Equal(t, time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC), NextRun(FrequencyMonthly, 8, now))
+
+	monday := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 26, 8, 0, 0, 0, time.UTC), NextRun(FrequencyWeekly, 8, monday), "estritamente depois")
+	assert.Equal(t, time.Date(2027, 1, 1, 6, 0, 0, 0, time.UTC), NextRun(FrequencyMonthly, 6, time.Date(2026, 12, 1, 6, 0, 0, 0, time.UTC)))
+}
+
+func TestNormalizeRecipients(t *testing.T) {
+	recipients, ok := NormalizeRecipients([]string{" Ana@Exemplo.com ", "ana@exemplo.com", "bia@exemplo.com"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"ana@exemplo.com", "bia@exemplo.com"}, recipients)
+
+	_, ok = NormalizeRecipients([]string{"não é e-mail"})
+	assert.False(t, ok)
+	_, ok = NormalizeRecipients(nil)
+	assert.False(t, ok)
+}
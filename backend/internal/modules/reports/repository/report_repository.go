@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/reports/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReportRepository define as definições de relatório, a execução das consultas e os agendamentos
+type ReportRepository interface {
+	ListReports(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetReport(ctx context.Context, id int) (*models.Report, error)
+	CreateReport(ctx context.Context, report *models.Report) error
+	UpdateReport(ctx context.Context, report *models.Report) error
+	DeleteReport(ctx context.Context, id int) error
+	Run(ctx context.Context, plan *models.Plan) (*models.Result, error)
+
+	ListSchedules(ctx context.Context, reportID int) ([]models.Schedule, error)
+	CreateSchedule(ctx context.Context, schedule *models.Schedule) error
+	DeleteSchedule(ctx context.Context, reportID, id int) error
+	DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error)
+	MarkScheduleRun(ctx context.Context, id int, ranAt, nextRunAt time.Time, runErr string) error
+}
+
+type reportRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewReportRepository cria uma nova instância do repositório
+func NewReportRepository() (ReportRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &reportRepository{
+		db:     db,
+		logger: logger.WithModule("report_repository"),
+	}, nil
+}
+
+// ListReports lista as definições de relatório da empresa, em ordem alfabética
+func (r *reportRepository) ListReports(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var reports []models.Report
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Report{})
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar relatórios", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar relatórios")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Offset(offset).Limit(params.PageSize).Order("name ASC, id ASC").Find(&reports).Error; err != nil {
+		r.logger.Error("erro ao listar relatórios", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar relatórios")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, reports), nil
+}
+
+// GetReport busca uma definição de relatório
+func (r *reportRepository) GetReport(ctx context.Context, id int) (*models.Report, error) {
+	var report models.Report
+	if err := r.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrReportNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar relatório")
+	}
+	return &report, nil
+}
+
+// CreateReport grava uma nova definição de relatório
+func (r *reportRepository) CreateReport(ctx context.Context, report *models.Report) error {
+	if err := r.db.WithContext(ctx).Create(report).Error; err != nil {
+		r.logger.Error("erro ao criar relatório", zap.Error(err), zap.String("name", report.Name))
+		return errors.WrapError(err, "falha ao criar relatório")
+	}
+	return nil
+}
+
+// UpdateReport altera nome, descrição e definição do relatório
+func (r *reportRepository) UpdateReport(ctx context.Context, report *models.Report) error {
+	result := r.db.WithContext(ctx).Model(report).
+		Select("name", "description", "definition", "updated_by", "updated_at").
+		Updates(report)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar relatório", zap.Error(result.Error), zap.Int("id", report.ID))
+		return errors.WrapError(result.Error, "falha ao atualizar relatório")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrReportNotFound
+	}
+	return nil
+}
+
+// DeleteReport remove o relatório e seus agendamentos
+func (r *reportRepository) DeleteReport(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("report_id = ?", id).Delete(&models.Schedule{}).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover agendamentos do relatório")
+		}
+		result := tx.Delete(&models.Report{}, id)
+		if result.Error != nil {
+			r.logger.Error("erro ao remover relatório", zap.Error(result.Error), zap.Int("id", id))
+			return errors.WrapError(result.Error, "falha ao remover relatório")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+// Run executa a consulta montada pela definição, filtrada pela empresa do contexto
+func (r *reportRepository) Run(ctx context.Context, plan *models.Plan) (*models.Result, error) {
+	query := r.db.WithContext(ctx).Table(plan.Table).
+		Scopes(tenant.Scope(ctx, plan.Table)).
+		Select(strings.Join(plan.Selects, ", "))
+	if plan.SoftDelete {
+		query = query.Where(plan.Table + ".deleted_at IS NULL")
+	}
+	for _, condition := range plan.Conditions {
+		query = query.Where(condition.SQL, condition.Args...)
+	}
+	if len(plan.GroupBy) > 0 {
+		query = query.Group(strings.Join(plan.GroupBy, ", "))
+	}
+	for _, order := range plan.OrderBy {
+		query = query.Order(order)
+	}
+
+	rows, err := query.Limit(plan.Limit).Rows()
+	if err != nil {
+		r.logger.Error("erro ao executar relatório", zap.Error(err), zap.String("table", plan.Table))
+		return nil, errors.WrapError(err, "falha ao executar relatório")
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao ler colunas do relatório")
+	}
+
+	result := &models.Result{Columns: plan.Columns, Rows: [][]interface{}{}}
+	for rows.Next() {
+		values := make([]interface{}, len(types))
+		pointers := make([]interface{}, len(types))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, errors.WrapError(err, "falha ao ler linha do relatório")
+		}
+		for i, value := range values {
+			values[i] = normalize(value, types[i].DatabaseTypeName())
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, "falha ao ler linhas do relatório")
+	}
+	return result, nil
+}
+
+// normalize converte os valores do driver: NUMERIC chega como texto e inteiros como int64
+func normalize(value interface{}, databaseType string) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		value = string(v)
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	}
+	if text, ok := value.(string); ok && databaseType == "NUMERIC" {
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number
+		}
+	}
+	return value
+}
+
+// ListSchedules lista os agendamentos do relatório
+func (r *reportRepository) ListSchedules(ctx context.Context, reportID int) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	if err := r.db.WithContext(ctx).Where("report_id = ?", reportID).Order("id ASC").Find(&schedules).Error; err != nil {
+		r.logger.Error("erro ao listar agendamentos de relatório", zap.Error(err), zap.Int("report_id", reportID))
+		return nil, errors.WrapError(err, "falha ao listar agendamentos")
+	}
+	return schedules, nil
+}
+
+// CreateSchedule grava um agendamento de envio
+func (r *reportRepository) CreateSchedule(ctx context.Context, schedule *models.Schedule) error {
+	if err := r.db.WithContext(ctx).Create(schedule).Error; err != nil {
+		r.logger.Error("erro ao criar agendamento de relatório", zap.Error(err), zap.Int("report_id", schedule.ReportID))
+		return errors.WrapError(err, "falha ao criar agendamento")
+	}
+	return nil
+}
+
+// DeleteSchedule remove um agendamento do relatório
+func (r *reportRepository) DeleteSchedule(ctx context.Context, reportID, id int) error {
+	result := r.db.WithContext(ctx).Where("report_id = ?", reportID).Delete(&models.Schedule{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover agendamento de relatório", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover agendamento")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrReportScheduleNotFound
+	}
+	return nil
+}
+
+// DueSchedules retorna os agendamentos ativos com envio vencido, com o relatório carregado. O
+// contexto deve vir de tenant.AllCompanies: a rotina atende todas as empresas.
+func (r *reportRepository) DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	var schedules []models.Schedule
+	err := r.db.WithContext(ctx).Preload("Report").
+		Where("active = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").
+		Find(&schedules).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar agendamentos de relatório vencidos", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar agendamentos vencidos")
+	}
+	return schedules, nil
+}
+
+// MarkScheduleRun registra o envio (ou a falha) e o próximo horário
+func (r *reportRepository) MarkScheduleRun(ctx context.Context, id int, ranAt, nextRunAt time.Time, runErr string) error {
+	err := r.db.WithContext(ctx).Model(&models.Schedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_run_at": ranAt,
+		"next_run_at": nextRunAt,
+		"last_error":  runErr,
+	}).Error
+	if err != nil {
+		r.logger.Error("erro ao registrar envio de relatório agendado", zap.Error(err), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao registrar envio do relatório")
+	}
+	return nil
+}
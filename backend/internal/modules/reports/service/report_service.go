@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/mailer"
+	"ERP-ONSMART/backend/internal/metrics"
+	fieldpermissions "ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+	"ERP-ONSMART/backend/internal/modules/reports/models"
+	"ERP-ONSMART/backend/internal/modules/reports/repository"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	templateService "ERP-ONSMART/backend/internal/modules/templates/service"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"go.uber.org/zap"
+)
+
+// ReportService compõe, executa e envia por e-mail os relatórios definidos pelos administradores
+type ReportService struct {
+	newRepo  func() (repository.ReportRepository, error)
+	send     func(mailer.Message) error
+	render   func(ctx context.Context, key string, data templates.Data) (*templates.Rendered, error)
+	now      func() time.Time
+	location func(ctx context.Context) *time.Location
+	logger   *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.ReportRepository
+}
+
+// NewReportService cria o serviço sobre o repositório informado
+func NewReportService(newRepo func() (repository.ReportRepository, error)) *ReportService {
+	return &ReportService{
+		newRepo:  newRepo,
+		send:     mailer.Send,
+		render:   templateService.Render,
+		now:      time.Now,
+		location: localtime.Location,
+		logger:   logger.WithModule("report_service"),
+	}
+}
+
+var defaultService = NewReportService(repository.NewReportRepository)
+
+// ListEntities retorna as entidades e campos disponíveis para compor relatórios
+func ListEntities() []models.Entity {
+	entities := make([]models.Entity, 0, len(models.Entities))
+	for _, name := range models.EntityNames() {
+		entities = append(entities, models.Entities[name])
+	}
+	return entities
+}
+
+// ListReports lista as definições de relatório da empresa
+func ListReports(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	return defaultService.ListReports(ctx, params)
+}
+
+// GetReport busca uma definição de relatório
+func GetReport(ctx context.Context, id int) (*models.Report, error) {
+	return defaultService.GetReport(ctx, id)
+}
+
+// CreateReport valida e grava uma nova definição de relatório
+func CreateReport(ctx context.Context, input models.ReportInput, createdBy string) (*models.Report, error) {
+	return defaultService.CreateReport(ctx, input, createdBy)
+}
+
+// UpdateReport valida e altera a definição de relatório
+func UpdateReport(ctx context.Context, id int, input models.ReportInput, updatedBy string) (*models.Report, error) {
+	return defaultService.UpdateReport(ctx, id, input, updatedBy)
+}
+
+// DeleteReport remove o relatório e seus agendamentos
+func DeleteReport(ctx context.Context, id int) error {
+	return defaultService.DeleteReport(ctx, id)
+}
+
+// RunReport executa o relatório, aplicando as permissões de campos do perfil
+func RunReport(ctx context.Context, id int, policy fieldpermissions.Policy) (*models.Report, *models.Result, error) {
+	return defaultService.Run(ctx, id, policy)
+}
+
+// ExportReport executa o relatório e gera o arquivo CSV ou PDF
+func ExportReport(ctx context.Context, id int, format string, policy fieldpermissions.Policy) (*models.Output, error) {
+	return defaultService.Export(ctx, id, format, policy)
+}
+
+// ListSchedules lista os agendamentos de envio do relatório
+func ListSchedules(ctx context.Context, reportID int) ([]models.Schedule, error) {
+	return defaultService.ListSchedules(ctx, reportID)
+}
+
+// CreateSchedule agenda o envio periódico do relatório por e-mail
+func CreateSchedule(ctx context.Context, reportID int, input models.ScheduleInput, createdBy string) (*models.Schedule, error) {
+	return defaultService.CreateSchedule(ctx, reportID, input, createdBy)
+}
+
+// DeleteSchedule remove um agendamento do relatório
+func DeleteSchedule(ctx context.Context, reportID, id int) error {
+	return defaultService.DeleteSchedule(ctx, reportID, id)
+}
+
+// StartReportScheduler envia periodicamente os relatórios agendados de todas as empresas
+func StartReportScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("report_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sent, err := defaultService.RunDueSchedules(ctx)
+				metrics.ObserveJob("report_scheduler", err)
+				if err != nil {
+					log.Error("erro ao enviar relatórios agendados", zap.Error(err))
+					continue
+				}
+				if sent > 0 {
+					log.Info("relatórios agendados enviados", zap.Int("sent", sent))
+				}
+			}
+		}
+	}()
+}
+
+func (s *ReportService) repository() (repository.ReportRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListReports lista as definições de relatório da empresa
+func (s *ReportService) ListReports(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListReports(ctx, params)
+}
+
+// GetReport busca uma definição de relatório
+func (s *ReportService) GetReport(ctx context.Context, id int) (*models.Report, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetReport(ctx, id)
+}
+
+// CreateReport valida a definição (entidade, campos, filtros e agregações) e a grava
+func (s *ReportService) CreateReport(ctx context.Context, input models.ReportInput, createdBy string) (*models.Report, error) {
+	if _, err := input.Definition.Plan(s.now()); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.Report{
+		Name:        strings.TrimSpace(input.Name),
+		Description: strings.TrimSpace(input.Description),
+		Definition:  input.Definition,
+		CreatedBy:   createdBy,
+		UpdatedBy:   createdBy,
+	}
+	if err := repo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// UpdateReport valida a nova definição e a grava
+func (s *ReportService) UpdateReport(ctx context.Context, id int, input models.ReportInput, updatedBy string) (*models.Report, error) {
+	if _, err := input.Definition.Plan(s.now()); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.Report{
+		ID:          id,
+		Name:        strings.TrimSpace(input.Name),
+		Description: strings.TrimSpace(input.Description),
+		Definition:  input.Definition,
+		UpdatedBy:   updatedBy,
+		UpdatedAt:   s.now(),
+	}
+	if err := repo.UpdateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return repo.GetReport(ctx, id)
+}
+
+// DeleteReport remove o relatório e seus agendamentos
+func (s *ReportService) DeleteReport(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteReport(ctx, id)
+}
+
+// Run executa o relatório na empresa do contexto e aplica as permissões de campos do perfil
+func (s *ReportService) Run(ctx context.Context, id int, policy fieldpermissions.Policy) (*models.Report, *models.Result, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := repo.GetReport(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := s.execute(ctx, repo, report)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.ApplyPolicy(policy)
+	return report, result, nil
+}
+
+// Export executa o relatório e gera o arquivo no formato pedido (csv ou pdf)
+func (s *ReportService) Export(ctx context.Context, id int, format string, policy fieldpermissions.Policy) (*models.Output, error) {
+	if format != models.FormatCSV && format != models.FormatPDF {
+		return nil, fmt.Errorf("%w: formato %q inválido (aceitos: json, csv, pdf)", errors.ErrInvalidReportDefinition, format)
+	}
+	report, result, err := s.Run(ctx, id, policy)
+	if err != nil {
+		return nil, err
+	}
+	return models.Render(report, result, format, s.localNow(ctx))
+}
+
+// localNow é o instante atual no fuso da empresa: define o dia dos filtros last_days, os
+// agrupamentos por período e o horário dos envios agendados
+func (s *ReportService) localNow(ctx context.Context) time.Time {
+	return s.now().In(s.location(ctx))
+}
+
+func (s *ReportService) execute(ctx context.Context, repo repository.ReportRepository, report *models.Report) (*models.Result, error) {
+	plan, err := report.Definition.Plan(s.localNow(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return repo.Run(ctx, plan)
+}
+
+// ListSchedules lista os agendamentos do relatório
+func (s *ReportService) ListSchedules(ctx context.Context, reportID int) ([]models.Schedule, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GetReport(ctx, reportID); err != nil {
+		return nil, err
+	}
+	return repo.ListSchedules(ctx, reportID)
+}
+
+// CreateSchedule agenda o envio do relatório; o primeiro envio sai no próximo horário da frequência
+func (s *ReportService) CreateSchedule(ctx context.Context, reportID int, input models.ScheduleInput, createdBy string) (*models.Schedule, error) {
+	recipients, ok := models.NormalizeRecipients(input.Recipients)
+	if !ok {
+		return nil, fmt.Errorf("%w: destinatários inválidos", errors.ErrInvalidReportDefinition)
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GetReport(ctx, reportID); err != nil {
+		return nil, err
+	}
+
+	schedule := &models.Schedule{
+		ReportID:   reportID,
+		Format:     input.Format,
+		Frequency:  input.Frequency,
+		Hour:       input.Hour,
+		Recipients: recipients,
+		Active:     true,
+		NextRunAt:  models.NextRun(input.Frequency, input.Hour, s.localNow(ctx)).UTC(),
+		CreatedBy:  createdBy,
+	}
+	if err := repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule remove um agendamento do relatório
+func (s *ReportService) DeleteSchedule(ctx context.Context, reportID, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteSchedule(ctx, reportID, id)
+}
+
+// RunDueSchedules envia os relatórios com envio vencido, de todas as empresas. A falha de um
+// agendamento fica registrada nele (last_error) e não impede os demais; o próximo envio é
+// remarcado mesmo assim, para não repetir a tentativa a cada ciclo.
+func (s *ReportService) RunDueSchedules(ctx context.Context) (int, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	schedules, err := repo.DueSchedules(tenant.AllCompanies(ctx), now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, schedule := range schedules {
+		companyCtx := tenant.WithCompany(ctx, schedule.CompanyID)
+		runErr := s.deliver(companyCtx, repo, schedule)
+		message := ""
+		if runErr != nil {
+			message = runErr.Error()
+			s.logger.Warn("erro ao enviar relatório agendado", zap.Error(runErr),
+				zap.Int("schedule_id", schedule.ID), zap.Int("report_id", schedule.ReportID))
+		} else {
+			sent++
+		}
+		next := models.NextRun(schedule.Frequency, schedule.Hour, now.In(s.location(companyCtx))).UTC()
+		if err := repo.MarkScheduleRun(companyCtx, schedule.ID, now, next, message); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+func (s *ReportService) deliver(ctx context.Context, repo repository.ReportRepository, schedule models.Schedule) error {
+	if schedule.Report == nil {
+		return errors.ErrReportNotFound
+	}
+	result, err := s.execute(ctx, repo, schedule.Report)
+	if err != nil {
+		return err
+	}
+	output, err := models.Render(schedule.Report, result, schedule.Format, s.localNow(ctx))
+	if err != nil {
+		return err
+	}
+
+	message, err := s.render(ctx, templates.KeyScheduledReportEmail, templates.Data{Report: schedule.Report,
+		Vars: map[string]interface{}{"rows": len(result.Rows), "generated_at": s.localNow(ctx)}})
+	if err != nil {
+		return err
+	}
+
+	recipients := append([]string(nil), schedule.Recipients...)
+	sort.Strings(recipients)
+	for _, recipient := range recipients {
+		err := s.send(mailer.Message{
+			To:          recipient,
+			Subject:     message.Subject,
+			Body:        message.Body,
+			Attachments: []mailer.Attachment{{FileName: output.FileName, ContentType: output.ContentType, Data: output.Data}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/mailer"
+	fieldpermissions "ERP-ONSMART/backend/internal/modules/fieldpermissions/models"
+	"ERP-ONSMART/backend/internal/modules/reports/models"
+	"ERP-ONSMART/backend/internal/modules/reports/repository"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scheduleRun struct {
+	id        int
+	companyID int
+	next      time.Time
+	err       string
+}
+
+type fakeRepo struct {
+	reports   map[int]*models.Report
+	schedules []models.Schedule
+	result    *models.Result
+	runErr    error
+	plans     []*models.Plan
+	runs      []scheduleRun
+	created   []*models.Schedule
+}
+
+func (r *fakeRepo) ListReports(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetReport(ctx context.Context, id int) (*models.Report, error) {
+	report, ok := r.reports[id]
+	if !ok {
+		return nil, appErrors.ErrReportNotFound
+	}
+	return report, nil
+}
+
+func (r *fakeRepo) CreateReport(ctx context.Context, report *models.Report) error {
+	report.ID = len(r.reports) + 1
+	r.reports[report.ID] = report
+	return nil
+}
+
+func (r *fakeRepo) UpdateReport(ctx context.Context, report *models.Report) error {
+	if _, ok := r.reports[report.ID]; !ok {
+		return appErrors.ErrReportNotFound
+	}
+	r.reports[report.ID] = report
+	return nil
+}
+
+func (r *fakeRepo) DeleteReport(ctx context.Context, id int) error {
+	delete(r.reports, id)
+	return nil
+}
+
+func (r *fakeRepo) Run(ctx context.Context, plan *models.Plan) (*models.Result, error) {
+	r.plans = append(r.plans, plan)
+	if r.runErr != nil {
+		return nil, r.runErr
+	}
+	copied := &models.Result{Columns: append([]models.Column(nil), r.result.Columns...)}
+	for _, row := range r.result.Rows {
+		copied.Rows = append(copied.Rows, append([]interface{}(nil), row...))
+	}
+	return copied, nil
+}
+
+func (r *fakeRepo) ListSchedules(ctx context.Context, reportID int) ([]models.Schedule, error) {
+	return r.schedules, nil
+}
+
+func (r *fakeRepo) CreateSchedule(ctx context.Context, schedule *models.Schedule) error {
+	r.created = append(r.created, schedule)
+	return nil
+}
+
+func (r *fakeRepo) DeleteSchedule(ctx context.Context, reportID, id int) error {
+	return nil
+}
+
+func (r *fakeRepo) DueSchedules(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	if !tenant.IsAllCompanies(ctx) {
+		return nil, errors.New("a rotina deve consultar todas as empresas")
+	}
+	return r.schedules, nil
+}
+
+func (r *fakeRepo) MarkScheduleRun(ctx context.Context, id int, ranAt, nextRunAt time.Time, runErr string) error {
+	companyID, _ := tenant.CompanyID(ctx)
+	r.runs = append(r.runs, scheduleRun{id: id, companyID: companyID, next: nextRunAt, err: runErr})
+	return nil
+}
+
+var testNow = time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+func newTestService(repo *fakeRepo) (*ReportService, *[]mailer.Message) {
+	var sent []mailer.Message
+	svc := NewReportService(func() (repository.ReportRepository, error) { return repo, nil })
+	svc.now = func() time.Time { return testNow }
+	svc.location = func(context.Context) *time.Location { return time.UTC }
+	svc.render = func(ctx context.Context, key string, data templates.Data) (*templates.Rendered, error) {
+		return templates.RenderBuiltin(key, data)
+	}
+	svc.send = func(msg mailer.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}
+	return svc, &sent
+}
+
+func profitReport() *models.Report {
+	return &models.Report{ID: 1, CompanyID: 2, Name: "Lucro por situação", Definition: models.Definition{
+		Entity:       "sales_processes",
+		GroupBy:      []string{"status"},
+		Aggregations: []models.Aggregation{{Func: "sum", Field: "profit"}},
+	}}
+}
+
+func profitResult() *models.Result {
+	return &models.Result{
+		Columns: []models.Column{{Name: "status", Field: "status"}, {Name: "sum_profit", Field: "profit"}},
+		Rows:    [][]interface{}{{"won", 1500.0}},
+	}
+}
+
+func TestCreateReportValidatesDefinition(t *testing.T) {
+	repo := &fakeRepo{reports: map[int]*models.Report{}}
+	svc, _ := newTestService(repo)
+
+	_, err := svc.CreateReport(context.Background(), models.ReportInput{
+		Name:       "Inválido",
+		Definition: models.Definition{Entity: "sales_processes", Columns: []string{"senha"}},
+	}, "admin")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidReportDefinition)
+	assert.Empty(t, repo.reports)
+
+	report, err := svc.CreateReport(context.Background(), models.ReportInput{
+		Name:       " Lucro ",
+		Definition: profitReport().Definition,
+	}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "Lucro", report.Name)
+	assert.Equal(t, "admin", report.CreatedBy)
+}
+
+func TestRunAppliesFieldPolicy(t *testing.T) {
+	repo := &fakeRepo{reports: map[int]*models.Report{1: profitReport()}, result: profitResult()}
+	svc, _ := newTestService(repo)
+
+	_, result, err := svc.Run(context.Background(), 1, fieldpermissions.Policy{"profit": fieldpermissions.ActionHide})
+	require.NoError(t, err)
+	assert.Equal(t, []models.Column{{Name: "status", Field: "status"}}, result.Columns)
+	assert.Equal(t, [][]interface{}{{"won"}}, result.Rows)
+
+	output, err := svc.Export(context.Background(), 1, models.FormatCSV, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "lucro-por-situacao-20261015.csv", output.FileName)
+	assert.Equal(t, "status,sum_profit\nwon,1500\n", string(output.Data))
+
+	_, err = svc.Export(context.Background(), 1, "xlsx", nil)
+	assert.ErrorIs(t, err, appErrors.ErrInvalidReportDefinition)
+	_, _, err = svc.Run(context.Background(), 9, nil)
+	assert.ErrorIs(t, err, appErrors.ErrReportNotFound)
+}
+
+func TestCreateScheduleComputesFirstRun(t *testing.T) {
+	repo := &fakeRepo{reports: map[int]*models.Report{1: profitReport()}}
+	svc, _ := newTestService(repo)
+
+	schedule, err := svc.CreateSchedule(context.Background(), 1, models.ScheduleInput{
+		Format: models.FormatPDF, Frequency: models.FrequencyWeekly, Hour: 7,
+		Recipients: []string{"Diretoria@Exemplo.com", "diretoria@exemplo.com"},
+	}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"diretoria@exemplo.com"}, schedule.Recipients)
+	assert.Equal(t, time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC), schedule.NextRunAt)
+	assert.True(t, schedule.Active)
+
+	_, err = svc.CreateSchedule(context.Background(), 9, models.ScheduleInput{
+		Format: models.FormatPDF, Frequency: models.FrequencyDaily, Recipients: []string{"a@exemplo.com"},
+	}, "admin")
+	assert.ErrorIs(t, err, appErrors.ErrReportNotFound)
+}
+
+func TestRunDueSchedulesSendsAndReschedules(t *testing.T) {
+	broken := profitReport()
+	broken.ID = 2
+	broken.Definition.Entity = "removida"
+	repo := &fakeRepo{
+		reports: map[int]*models.Report{},
+		result:  profitResult(),
+		schedules: []models.Schedule{
+			{ID: 10, CompanyID: 2, ReportID: 1, Format: models.FormatCSV, Frequency: models.FrequencyDaily, Hour: 6,
+				Recipients: []string{"b@exemplo.com", "a@exemplo.com"}, Report: profitReport()},
+			{ID: 11, CompanyID: 3, ReportID: 2, Format: models.FormatPDF, Frequency: models.FrequencyMonthly, Hour: 6,
+				Recipients: []string{"c@exemplo.com"}, Report: broken},
+		},
+	}
+	svc, sent := newTestService(repo)
+
+	count, err := svc.RunDueSchedules(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.Len(t, *sent, 2)
+	assert.Equal(t, "a@exemplo.com", (*sent)[0].To)
+	assert.Equal(t, "Relatório: Lucro por situação", (*sent)[0].Subject)
+	require.Len(t, (*sent)[0].Attachments, 1)
+	assert.Equal(t, "status,sum_profit\nwon,1500\n", string((*sent)[0].Attachments[0].Data))
+
+	require.Len(t, repo.runs, 2)
+	assert.Equal(t, scheduleRun{id: 10, companyID: 2, next: time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)}, repo.runs[0])
+	assert.Equal(t, 11, repo.runs[1].id)
+	assert.Equal(t, 3, repo.runs[1].companyID)
+	assert.Equal(t, time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), repo.runs[1].next)
+	assert.Contains(t, repo.runs[1].err, "entidade desconhecida")
+}
+
+func TestSchedulesFollowCompanyTimezone(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+	repo := &fakeRepo{
+		reports: map[int]*models.Report{1: profitReport()},
+		result:  profitResult(),
+		schedules: []models.Schedule{
+			{ID: 10, CompanyID: 2, ReportID: 1, Format: models.FormatCSV, Frequency: models.FrequencyDaily, Hour: 6,
+				Recipients: []string{"a@exemplo.com"}, Report: profitReport()},
+		},
+	}
+	svc, _ := newTestService(repo)
+	svc.location = func(context.Context) *time.Location { return saoPaulo }
+
+	// 08:00 UTC são 05:00 em São Paulo: o envio das 6h ainda sai hoje, às 09:00 UTC
+	schedule, err := svc.CreateSchedule(context.Background(), 1, models.ScheduleInput{
+		Format: models.FormatCSV, Frequency: models.FrequencyDaily, Hour: 6, Recipients: []string{"a@exemplo.com"},
+	}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), schedule.NextRunAt)
+
+	_, err = svc.RunDueSchedules(context.Background())
+	require.NoError(t, err)
+	require.Len(t, repo.runs, 1)
+	assert.Equal(t, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), repo.runs[0].next)
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/returns/models"
+	"ERP-ONSMART/backend/internal/modules/returns/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const returnDateLayout = "2006-01-02"
+
+// Lista as devoluções (filtro opcional ?status=)
+// @Security BearerAuth
+func ListReturnsHandler(c *gin.Context) {
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.GetAllReturns(c.Request.Context(), c.Query("status"), &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar devoluções")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Abre uma solicitação de devolução para itens de uma entrega ou fatura
+// @Security BearerAuth
+func CreateReturnHandler(c *gin.Context) {
+	var input service.CreateReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	ret, err := service.CreateReturn(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar devolução")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"return": ret})
+}
+
+// Retorna uma devolução com seus itens
+// @Security BearerAuth
+func GetReturnHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ret, err := service.GetReturn(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar devolução")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": ret})
+}
+
+// Aprova a devolução solicitada
+// O aprovador é o usuário autenticado (administrador).
+// @Security BearerAuth
+func ApproveReturnHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ret, err := service.ApproveReturn(c.Request.Context(), id, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao aprovar devolução")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": ret})
+}
+
+// Rejeita a devolução solicitada
+// @Security BearerAuth
+func RejectReturnHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	ret, err := service.RejectReturn(c.Request.Context(), id, req.Reason)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao rejeitar devolução")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": ret})
+}
+
+// Cancela a devolução antes do recebimento
+// @Security BearerAuth
+func CancelReturnHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	ret, err := service.CancelReturn(c.Request.Context(), id, req.Reason)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cancelar devolução")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": ret})
+}
+
+// Registra o recebimento dos itens devolvidos; sem corpo, todos são recebidos como revendáveis
+// @Security BearerAuth
+func ReceiveReturnHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Items []models.ReceiveLine `json:"items" binding:"dive"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	ret, err := service.ReceiveReturn(c.Request.Context(), id, req.Items)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao receber devolução")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": ret})
+}
+
+// Gera a nota de crédito da devolução recebida
+// @Security BearerAuth
+func IssueReturnCreditNoteHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	note, err := service.IssueCreditNote(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar nota de crédito da devolução")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"credit_note": note})
+}
+
+// Retorna os indicadores de devolução do período (?from=AAAA-MM-DD&to=AAAA-MM-DD)
+// @Security BearerAuth
+func GetReturnAnalyticsHandler(c *gin.Context) {
+	from, err := parseReturnDate(c.Query("from"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data inicial inválida").WithDetails(err.Error()))
+		return
+	}
+	to, err := parseReturnDate(c.Query("to"))
+	if err != nil {
+		c.Error(errors.InvalidParam("data final inválida").WithDetails(err.Error()))
+		return
+	}
+
+	analytics, err := service.GetReturnAnalytics(c.Request.Context(), from, to)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar indicadores de devolução")
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+// parseReturnDate converte o parâmetro de data; vazio resulta em data zero
+func parseReturnDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(returnDateLayout, value)
+}
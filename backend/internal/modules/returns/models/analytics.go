@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// ReturnAnalytics resume as devoluções do período
+type ReturnAnalytics struct {
+	From              time.Time             `json:"from"`
+	To                time.Time             `json:"to"`
+	TotalReturns      int                   `json:"total_returns"`
+	CountByStatus     map[string]int        `json:"count_by_status"`
+	ByReason          []ReturnReasonStat    `json:"by_reason"`
+	TopProducts       []ProductReturnStat   `json:"top_products"`
+	ReturnedQuantity  int                   `json:"returned_quantity"`
+	DeliveredQuantity int                   `json:"delivered_quantity"`
+	ReturnRate        float64               `json:"return_rate"`
+	RequestedAmount   float64               `json:"requested_amount"`
+	CreditedAmount    float64               `json:"credited_amount"`
+	Restock           ReturnRestockStat     `json:"restock"`
+	AverageDays       ReturnProcessingTimes `json:"average_days"`
+}
+
+// ReturnReasonStat agrupa as devoluções por motivo
+type ReturnReasonStat struct {
+	Reason   string  `json:"reason"`
+	Items    int     `json:"items"`
+	Quantity int     `json:"quantity"`
+	Amount   float64 `json:"amount"`
+}
+
+// ProductReturnStat agrupa as devoluções por produto
+type ProductReturnStat struct {
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	Amount      float64 `json:"amount"`
+}
+
+// ReturnRestockStat separa as quantidades recebidas que voltaram ao estoque das avariadas
+type ReturnRestockStat struct {
+	Resellable int `json:"resellable"`
+	Damaged    int `json:"damaged"`
+}
+
+// ReturnProcessingTimes contém os tempos médios (em dias) das etapas da devolução
+type ReturnProcessingTimes struct {
+	ToApproval float64 `json:"to_approval"`
+	ToReceipt  float64 `json:"to_receipt"`
+	ToCredit   float64 `json:"to_credit"`
+}
+
+// ReturnRate calcula o percentual de itens devolvidos sobre os itens entregues
+func ReturnRate(returned, delivered int) float64 {
+	if delivered <= 0 {
+		return 0
+	}
+	return round2(float64(returned) / float64(delivered) * 100)
+}
@@ -0,0 +1,266 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"math"
+	"time"
+)
+
+// Status da solicitação de devolução
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusReceived  = "received"
+	ReturnStatusCredited  = "credited"
+	ReturnStatusCancelled = "cancelled"
+)
+
+// Motivos de devolução por item
+const (
+	ReturnReasonDamaged        = "damaged"
+	ReturnReasonDefective      = "defective"
+	ReturnReasonWrongItem      = "wrong_item"
+	ReturnReasonNotAsDescribed = "not_as_described"
+	ReturnReasonNoLongerNeeded = "no_longer_needed"
+	ReturnReasonOther          = "other"
+)
+
+// Condição do item no recebimento: apenas itens revendáveis retornam ao estoque
+const (
+	ReturnConditionResellable = "resellable"
+	ReturnConditionDamaged    = "damaged"
+)
+
+// returnTransitions define as transições de status permitidas
+var returnTransitions = map[string][]string{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected, ReturnStatusCancelled},
+	ReturnStatusApproved:  {ReturnStatusReceived, ReturnStatusCancelled},
+	ReturnStatusReceived:  {ReturnStatusCredited},
+}
+
+// ReturnRequest é uma solicitação de devolução (RMA) de itens entregues ou faturados
+type ReturnRequest struct {
+	ID              int           `json:"id" gorm:"primaryKey"`
+	CompanyID       int           `json:"company_id" gorm:"<-:create"`
+	ReturnNo        string        `json:"return_no" gorm:"uniqueIndex"`
+	ContactID       int           `json:"contact_id" gorm:"index"`
+	SalesOrderID    *int          `json:"sales_order_id,omitempty"`
+	DeliveryID      *int          `json:"delivery_id,omitempty" gorm:"index"`
+	InvoiceID       *int          `json:"invoice_id,omitempty" gorm:"index"`
+	Status          string        `json:"status" gorm:"default:requested"`
+	Reason          string        `json:"reason"`
+	Notes           string        `json:"notes,omitempty"`
+	RejectionReason string        `json:"rejection_reason,omitempty"`
+	TotalAmount     money.Decimal `json:"total_amount"`
+	CreditNoteID    *int          `json:"credit_note_id,omitempty"`
+	RequestedAt     time.Time     `json:"requested_at"`
+	ApprovedAt      *time.Time    `json:"approved_at,omitempty"`
+	ApprovedBy      string        `json:"approved_by,omitempty"`
+	ReceivedAt      *time.Time    `json:"received_at,omitempty"`
+	CreditedAt      *time.Time    `json:"credited_at,omitempty"`
+	CreatedAt       time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	Items           []ReturnItem  `json:"items,omitempty" gorm:"foreignKey:ReturnID"`
+}
+
+// TableName define o nome da tabela de devoluções
+func (ReturnRequest) TableName() string {
+	return "return_requests"
+}
+
+// ReturnItem é um item da devolução, vinculado ao item da entrega ou da fatura de origem
+type ReturnItem struct {
+	ID             int           `json:"id" gorm:"primaryKey"`
+	ReturnID       int           `json:"return_id" gorm:"index"`
+	DeliveryItemID *int          `json:"delivery_item_id,omitempty"`
+	InvoiceItemID  *int          `json:"invoice_item_id,omitempty"`
+	ProductID      int           `json:"product_id"`
+	ProductName    string        `json:"product_name"`
+	ProductCode    string        `json:"product_code"`
+	Quantity       int           `json:"quantity"`
+	ReceivedQty    int           `json:"received_qty"`
+	UnitPrice      money.Decimal `json:"unit_price"`
+	Total          money.Decimal `json:"total"`
+	ReasonCode     string        `json:"reason_code"`
+	Condition      string        `json:"condition,omitempty" gorm:"default:null"`
+	Restocked      bool          `json:"restocked"`
+	Notes          string        `json:"notes,omitempty"`
+}
+
+// TableName define o nome da tabela de itens devolvidos
+func (ReturnItem) TableName() string {
+	return "return_items"
+}
+
+// ReturnableLine é uma linha do documento de origem com o saldo ainda disponível para devolução
+type ReturnableLine struct {
+	SourceItemID int
+	ProductID    int
+	ProductName  string
+	ProductCode  string
+	Quantity     int
+	Returned     int
+	UnitPrice    money.Decimal
+}
+
+// Available retorna a quantidade que ainda pode ser devolvida
+func (l ReturnableLine) Available() int {
+	if available := l.Quantity - l.Returned; available > 0 {
+		return available
+	}
+	return 0
+}
+
+// ReturnLineRequest é a linha solicitada pelo cliente, referenciando o item do documento de origem
+type ReturnLineRequest struct {
+	SourceItemID int    `json:"source_item_id" binding:"required"`
+	Quantity     int    `json:"quantity" binding:"required,gt=0"`
+	ReasonCode   string `json:"reason_code" binding:"required"`
+	Notes        string `json:"notes"`
+}
+
+// ReceiveLine informa a quantidade recebida e a condição de um item devolvido
+type ReceiveLine struct {
+	ItemID      int    `json:"item_id" binding:"required"`
+	ReceivedQty int    `json:"received_qty" binding:"gte=0"`
+	Condition   string `json:"condition"`
+}
+
+// CanTransition indica se a devolução pode passar do status atual para o novo status
+func CanTransition(from, to string) bool {
+	for _, allowed := range returnTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidReason verifica se o motivo de devolução é suportado
+func IsValidReason(reason string) bool {
+	switch reason {
+	case ReturnReasonDamaged, ReturnReasonDefective, ReturnReasonWrongItem,
+		ReturnReasonNotAsDescribed, ReturnReasonNoLongerNeeded, ReturnReasonOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// BuildReturnItems valida as linhas solicitadas contra o saldo devolvível do documento de origem
+// e monta os itens da devolução com o valor total a creditar. fromInvoice indica se o
+// SourceItemID referencia itens de fatura (true) ou de entrega (false).
+func BuildReturnItems(lines []ReturnLineRequest, returnable map[int]ReturnableLine, fromInvoice bool) ([]ReturnItem, money.Decimal, error) {
+	if len(lines) == 0 {
+		return nil, money.Zero, errors.ErrEmptyReturn
+	}
+
+	requested := make(map[int]int)
+	items := make([]ReturnItem, 0, len(lines))
+	var total money.Decimal
+
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, money.Zero, errors.ErrEmptyReturn
+		}
+		if !IsValidReason(line.ReasonCode) {
+			return nil, money.Zero, errors.ErrInvalidReturnReason
+		}
+
+		source, ok := returnable[line.SourceItemID]
+		if !ok {
+			return nil, money.Zero, errors.ErrReturnItemNotInDocument
+		}
+
+		requested[line.SourceItemID] += line.Quantity
+		if requested[line.SourceItemID] > source.Available() {
+			return nil, money.Zero, errors.ErrReturnQuantityExceeded
+		}
+
+		sourceID := line.SourceItemID
+		item := ReturnItem{
+			ProductID:   source.ProductID,
+			ProductName: source.ProductName,
+			ProductCode: source.ProductCode,
+			Quantity:    line.Quantity,
+			UnitPrice:   source.UnitPrice,
+			Total:       money.Round(source.UnitPrice.MulInt(line.Quantity)),
+			ReasonCode:  line.ReasonCode,
+			Notes:       line.Notes,
+		}
+		if fromInvoice {
+			item.InvoiceItemID = &sourceID
+		} else {
+			item.DeliveryItemID = &sourceID
+		}
+
+		total = total.Add(item.Total)
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
+
+// ApplyReceipt registra o recebimento dos itens devolvidos. Sem linhas informadas, todos os
+// itens são considerados recebidos integralmente em condição de revenda.
+func ApplyReceipt(items []ReturnItem, lines []ReceiveLine) error {
+	if len(lines) == 0 {
+		for i := range items {
+			items[i].ReceivedQty = items[i].Quantity
+			items[i].Condition = ReturnConditionResellable
+		}
+		return nil
+	}
+
+	index := make(map[int]int, len(items))
+	for i := range items {
+		index[items[i].ID] = i
+		items[i].ReceivedQty = 0
+		items[i].Condition = ReturnConditionResellable
+	}
+
+	for _, line := range lines {
+		i, ok := index[line.ItemID]
+		if !ok {
+			return errors.ErrReturnItemNotInDocument
+		}
+
+		condition := line.Condition
+		if condition == "" {
+			condition = ReturnConditionResellable
+		}
+		if condition != ReturnConditionResellable && condition != ReturnConditionDamaged {
+			return errors.ErrInvalidReturnCondition
+		}
+		if line.ReceivedQty < 0 || line.ReceivedQty > items[i].Quantity {
+			return errors.ErrReturnQuantityExceeded
+		}
+
+		items[i].ReceivedQty = line.ReceivedQty
+		items[i].Condition = condition
+	}
+	return nil
+}
+
+// CreditAmount calcula o valor a creditar ao cliente pelas quantidades efetivamente recebidas
+func CreditAmount(items []ReturnItem) money.Decimal {
+	var total money.Decimal
+	for _, item := range items {
+		total = total.Add(item.UnitPrice.MulInt(item.ReceivedQty))
+	}
+	return money.Round(total)
+}
+
+// UnitValue calcula o valor unitário líquido de um item de documento de venda
+func UnitValue(total, unitPrice money.Decimal, quantity int) money.Decimal {
+	if total.IsPositive() && quantity > 0 {
+		return money.Round(total.DivInt(quantity))
+	}
+	return unitPrice
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func returnableFixture() map[int]ReturnableLine {
+	return map[int]ReturnableLine{
+		10: {SourceItemID: 10, ProductID: 1, ProductName: "Notebook", Quantity: 5, Returned: 1, UnitPrice: money.FromInt(100)},
+		11: {SourceItemID: 11, ProductID: 2, ProductName: "Mouse", Quantity: 2, UnitPrice: money.MustParse("25.5")},
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(ReturnStatusRequested, ReturnStatusApproved))
+	assert.True(t, CanTransition(ReturnStatusRequested, ReturnStatusRejected))
+	assert.True(t, CanTransition(ReturnStatusApproved, ReturnStatusReceived))
+	assert.True(t, CanTransition(ReturnStatusReceived, ReturnStatusCredited))
+
+	assert.False(t, CanTransition(ReturnStatusRequested, ReturnStatusReceived))
+	assert.False(t, CanTransition(ReturnStatusReceived, ReturnStatusCancelled))
+	assert.False(t, CanTransition(ReturnStatusCredited, ReturnStatusApproved))
+	assert.False(t, CanTransition(ReturnStatusRejected, ReturnStatusApproved))
+}
+
+func TestBuildReturnItemsFromDelivery(t *testing.T) {
+	lines := []ReturnLineRequest{
+		{SourceItemID: 10, Quantity: 2, ReasonCode: ReturnReasonDefective},
+		{SourceItemID: 11, Quantity: 1, ReasonCode: ReturnReasonWrongItem, Notes: "cor errada"},
+	}
+
+	items, total, err := BuildReturnItems(lines, returnableFixture(), false)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "225.50", total.String())
+	require.NotNil(t, items[0].DeliveryItemID)
+	assert.Equal(t, 10, *items[0].DeliveryItemID)
+	assert.Nil(t, items[0].InvoiceItemID)
+	assert.Equal(t, "200.00", items[0].Total.String())
+	assert.Equal(t, "cor errada", items[1].Notes)
+}
+
+func TestBuildReturnItemsFromInvoice(t *testing.T) {
+	lines := []ReturnLineRequest{{SourceItemID: 11, Quantity: 2, ReasonCode: ReturnReasonDamaged}}
+
+	items, _, err := BuildReturnItems(lines, returnableFixture(), true)
+	require.NoError(t, err)
+	require.NotNil(t, items[0].InvoiceItemID)
+	assert.Nil(t, items[0].DeliveryItemID)
+}
+
+func TestBuildReturnItemsValidation(t *testing.T) {
+	returnable := returnableFixture()
+
+	_, _, err := BuildReturnItems(nil, returnable, false)
+	assert.Equal(t, errors.ErrEmptyReturn, err)
+
+	_, _, err = BuildReturnItems([]ReturnLineRequest{{SourceItemID: 10, Quantity: 1, ReasonCode: "arrependimento"}}, returnable, false)
+	assert.Equal(t, errors.ErrInvalidReturnReason, err)
+
+	_, _, err = BuildReturnItems([]ReturnLineRequest{{SourceItemID: 99, Quantity: 1, ReasonCode: ReturnReasonOther}}, returnable, false)
+	assert.Equal(t, errors.ErrReturnItemNotInDocument, err)
+
+	// Saldo do item 10: 5 entregues - 1 já devolvido = 4
+	_, _, err = BuildReturnItems([]ReturnLineRequest{{SourceItemID: 10, Quantity: 5, ReasonCode: ReturnReasonOther}}, returnable, false)
+	assert.Equal(t, errors.ErrReturnQuantityExceeded, err)
+
+	// Linhas repetidas do mesmo item somam a quantidade
+	_, _, err = BuildReturnItems([]ReturnLineRequest{
+		{SourceItemID: 10, Quantity: 3, ReasonCode: ReturnReasonOther},
+		{SourceItemID: 10, Quantity: 2, ReasonCode: ReturnReasonDamaged},
+	}, returnable, false)
+	assert.Equal(t, errors.ErrReturnQuantityExceeded, err)
+}
+
+func TestApplyReceiptDefaultsToFullResellable(t *testing.T) {
+	items := []ReturnItem{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 1}}
+
+	require.NoError(t, ApplyReceipt(items, nil))
+	for _, item := range items {
+		assert.Equal(t, item.Quantity, item.ReceivedQty)
+		assert.Equal(t, ReturnConditionResellable, item.Condition)
+	}
+}
+
+func TestApplyReceiptWithLines(t *testing.T) {
+	items := []ReturnItem{{ID: 1, Quantity: 3, UnitPrice: money.FromInt(10)}, {ID: 2, Quantity: 1, UnitPrice: money.FromInt(50)}}
+
+	err := ApplyReceipt(items, []ReceiveLine{{ItemID: 1, ReceivedQty: 2, Condition: ReturnConditionDamaged}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, items[0].ReceivedQty)
+	assert.Equal(t, ReturnConditionDamaged, items[0].Condition)
+	assert.Equal(t, 0, items[1].ReceivedQty, "item não informado não foi recebido")
+	assert.Equal(t, "20.00", CreditAmount(items).String())
+}
+
+func TestApplyReceiptValidation(t *testing.T) {
+	items := []ReturnItem{{ID: 1, Quantity: 3}}
+
+	assert.Equal(t, errors.ErrReturnItemNotInDocument, ApplyReceipt(items, []ReceiveLine{{ItemID: 9, ReceivedQty: 1}}))
+	assert.Equal(t, errors.ErrReturnQuantityExceeded, ApplyReceipt(items, []ReceiveLine{{ItemID: 1, ReceivedQty: 4}}))
+	assert.Equal(t, errors.ErrInvalidReturnCondition, ApplyReceipt(items, []ReceiveLine{{ItemID: 1, ReceivedQty: 1, Condition: "usado"}}))
+}
+
+func TestUnitValueAndReturnRate(t *testing.T) {
+	assert.Equal(t, "9.50", UnitValue(money.FromInt(19), money.FromInt(10), 2).String())
+	assert.Equal(t, "10.00", UnitValue(money.Zero, money.FromInt(10), 2).String())
+
+	assert.Equal(t, 2.5, ReturnRate(5, 200))
+	assert.Equal(t, 0.0, ReturnRate(5, 0))
+}
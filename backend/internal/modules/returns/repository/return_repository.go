@@ -0,0 +1,755 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	inventory "ERP-ONSMART/backend/internal/modules/inventory/models"
+	products "ERP-ONSMART/backend/internal/modules/products/repository"
+	"ERP-ONSMART/backend/internal/modules/returns/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReturnRepository define as operações de devoluções (RMA)
+type ReturnRepository interface {
+	CreateReturn(ctx context.Context, ret *models.ReturnRequest, lines []models.ReturnLineRequest) (*models.ReturnRequest, error)
+	GetReturnByID(ctx context.Context, id int) (*models.ReturnRequest, error)
+	GetAllReturns(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	ChangeStatus(ctx context.Context, id int, status, note, user string) (*models.ReturnRequest, error)
+	ReceiveReturn(ctx context.Context, id int, lines []models.ReceiveLine) (*models.ReturnRequest, error)
+	IssueCreditNote(ctx context.Context, id int) (*sales.CreditNote, error)
+	GetAnalytics(ctx context.Context, from, to time.Time) (*models.ReturnAnalytics, error)
+}
+
+type returnRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewReturnRepository cria uma nova instância do repositório
+func NewReturnRepository() (ReturnRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &returnRepository{
+		db:     db,
+		logger: logger.WithModule("return_repository"),
+	}, nil
+}
+
+// closedReturnStatuses são os status que liberam a quantidade solicitada na devolução
+var closedReturnStatuses = []string{models.ReturnStatusRejected, models.ReturnStatusCancelled}
+
+// CreateReturn cria a solicitação de devolução a partir de uma entrega ou de uma fatura,
+// validando as quantidades contra o saldo ainda não devolvido de cada item
+func (r *returnRepository) CreateReturn(ctx context.Context, ret *models.ReturnRequest, lines []models.ReturnLineRequest) (*models.ReturnRequest, error) {
+	if ret.DeliveryID == nil && ret.InvoiceID == nil {
+		return nil, errors.ErrReturnSourceRequired
+	}
+
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var (
+		returnable  map[int]models.ReturnableLine
+		fromInvoice bool
+		err         error
+	)
+	if ret.DeliveryID != nil {
+		returnable, err = r.loadDeliveryLines(ctx, tx, ret)
+	} else {
+		fromInvoice = true
+		returnable, err = r.loadInvoiceLines(ctx, tx, ret)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Fatura informada junto com a entrega serve apenas de referência para a nota de crédito
+	if ret.DeliveryID != nil && ret.InvoiceID != nil {
+		var invoice sales.Invoice
+		if err := tx.Select("id", "contact_id").First(&invoice, *ret.InvoiceID).Error; err != nil {
+			tx.Rollback()
+			if err == gorm.ErrRecordNotFound {
+				return nil, errors.ErrInvoiceNotFound
+			}
+			return nil, errors.WrapError(err, "falha ao buscar invoice")
+		}
+		if invoice.ContactID != ret.ContactID {
+			tx.Rollback()
+			return nil, errors.ErrReturnItemNotInDocument
+		}
+	}
+
+	items, total, err := models.BuildReturnItems(lines, returnable, fromInvoice)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	ret.ReturnNo = r.generateReturnNumber(tx)
+	ret.Status = models.ReturnStatusRequested
+	ret.RequestedAt = time.Now()
+	ret.TotalAmount = total
+	ret.Items = items
+
+	if err := tx.Create(ret).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar devolução", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao criar devolução")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("devolução criada", zap.Int("id", ret.ID), zap.String("return_no", ret.ReturnNo))
+	return ret, nil
+}
+
+// GetReturnByID busca a devolução com seus itens
+func (r *returnRepository) GetReturnByID(ctx context.Context, id int) (*models.ReturnRequest, error) {
+	var ret models.ReturnRequest
+	if err := db.Conn(ctx, r.db).Preload("Items").First(&ret, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrReturnNotFound
+		}
+		r.logger.Error("erro ao buscar devolução por ID", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar devolução")
+	}
+	return &ret, nil
+}
+
+// GetAllReturns lista as devoluções, opcionalmente filtradas por status
+func (r *returnRepository) GetAllReturns(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var returns []models.ReturnRequest
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.ReturnRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar devoluções", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar devoluções")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Preload("Items").
+		Order("requested_at DESC, id DESC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&returns).Error; err != nil {
+		r.logger.Error("erro ao buscar devoluções", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar devoluções")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, returns), nil
+}
+
+// ChangeStatus aprova, rejeita ou cancela a devolução conforme o fluxo permitido; na aprovação,
+// user é registrado como aprovador
+func (r *returnRepository) ChangeStatus(ctx context.Context, id int, status, note, user string) (*models.ReturnRequest, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	ret, err := r.lockReturn(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !models.CanTransition(ret.Status, status) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidReturnStatus
+	}
+
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case models.ReturnStatusApproved:
+		updates["approved_at"] = time.Now()
+		updates["approved_by"] = user
+	case models.ReturnStatusRejected:
+		updates["rejection_reason"] = note
+	case models.ReturnStatusCancelled:
+		if note != "" {
+			updates["notes"] = appendNote(ret.Notes, "Cancelada: "+note)
+		}
+	}
+
+	if err := tx.Model(&models.ReturnRequest{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao atualizar status da devolução", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar status da devolução")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("status da devolução atualizado", zap.Int("id", id), zap.String("status", status))
+	return r.GetReturnByID(ctx, id)
+}
+
+// ReceiveReturn registra o recebimento físico dos itens: os revendáveis voltam ao estoque com
+// o custo apurado na venda e geram uma nova camada de custo
+func (r *returnRepository) ReceiveReturn(ctx context.Context, id int, lines []models.ReceiveLine) (*models.ReturnRequest, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	ret, err := r.lockReturn(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !models.CanTransition(ret.Status, models.ReturnStatusReceived) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidReturnStatus
+	}
+
+	if err := tx.Where("return_id = ?", id).Order("id ASC").Find(&ret.Items).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar itens da devolução")
+	}
+
+	if err := models.ApplyReceipt(ret.Items, lines); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range ret.Items {
+		item := &ret.Items[i]
+
+		if item.Condition == models.ReturnConditionResellable && item.ReceivedQty > 0 {
+			if err := r.restockItem(tx, item, now); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			item.Restocked = true
+		}
+
+		if err := tx.Model(&models.ReturnItem{}).Where("id = ?", item.ID).
+			Updates(map[string]interface{}{
+				"received_qty": item.ReceivedQty,
+				"condition":    item.Condition,
+				"restocked":    item.Restocked,
+			}).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao atualizar item da devolução", zap.Error(err), zap.Int("item_id", item.ID))
+			return nil, errors.WrapError(err, "falha ao atualizar item da devolução")
+		}
+	}
+
+	if err := tx.Model(&models.ReturnRequest{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      models.ReturnStatusReceived,
+			"received_at": now,
+		}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao atualizar status da devolução")
+	}
+
+	if ret.DeliveryID != nil {
+		if err := r.markDeliveryReturned(tx, *ret.DeliveryID, ret.ReturnNo); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	// O estoque dos produtos repostos mudou: descarta as leituras em cache
+	for _, item := range ret.Items {
+		if item.Restocked {
+			products.InvalidateProducts(item.ProductID)
+		}
+	}
+
+	r.logger.Info("devolução recebida", zap.Int("id", id), zap.String("return_no", ret.ReturnNo))
+	return r.GetReturnByID(ctx, id)
+}
+
+// IssueCreditNote gera a nota de crédito da devolução recebida pelo valor dos itens recebidos
+func (r *returnRepository) IssueCreditNote(ctx context.Context, id int) (*sales.CreditNote, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	ret, err := r.lockReturn(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !models.CanTransition(ret.Status, models.ReturnStatusCredited) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidReturnStatus
+	}
+
+	if err := tx.Where("return_id = ?", id).Find(&ret.Items).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar itens da devolução")
+	}
+
+	amount := models.CreditAmount(ret.Items)
+	if !amount.IsPositive() {
+		tx.Rollback()
+		return nil, errors.ErrEmptyReturn
+	}
+
+	now := time.Now()
+	note := sales.CreditNote{
+		CreditNoteNo: r.generateCreditNoteNumber(tx),
+		ContactID:    ret.ContactID,
+		Status:       sales.CreditNoteStatusIssued,
+		IssueDate:    now,
+		Amount:       amount,
+		Reason:       fmt.Sprintf("Devolução %s", ret.ReturnNo),
+	}
+
+	create := tx
+	if ret.InvoiceID != nil {
+		note.InvoiceID = *ret.InvoiceID
+	} else {
+		create = tx.Omit("InvoiceID")
+	}
+	if err := create.Create(&note).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar nota de crédito da devolução", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao criar nota de crédito")
+	}
+
+	if err := tx.Model(&models.ReturnRequest{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":         models.ReturnStatusCredited,
+			"credit_note_id": note.ID,
+			"credited_at":    now,
+		}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao atualizar status da devolução")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("nota de crédito da devolução emitida",
+		zap.Int("id", id), zap.String("credit_note_no", note.CreditNoteNo), zap.Stringer("amount", amount))
+	return &note, nil
+}
+
+// GetAnalytics consolida as devoluções solicitadas no período
+func (r *returnRepository) GetAnalytics(ctx context.Context, from, to time.Time) (*models.ReturnAnalytics, error) {
+	analytics := &models.ReturnAnalytics{
+		From:          from,
+		To:            to,
+		CountByStatus: make(map[string]int),
+		ByReason:      make([]models.ReturnReasonStat, 0),
+		TopProducts:   make([]models.ProductReturnStat, 0),
+	}
+
+	period := db.Conn(ctx, r.db).Table("return_requests rr").
+		Scopes(tenant.Scope(ctx, "rr")).
+		Where("rr.requested_at BETWEEN ? AND ?", from, to).
+		Session(&gorm.Session{})
+
+	var statusRows []struct {
+		Status string
+		Count  int
+	}
+	if err := period.
+		Select("rr.status, COUNT(*) AS count").
+		Group("rr.status").
+		Scan(&statusRows).Error; err != nil {
+		r.logger.Error("erro ao agrupar devoluções por status", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao agrupar devoluções por status")
+	}
+	for _, row := range statusRows {
+		analytics.CountByStatus[row.Status] = row.Count
+		analytics.TotalReturns += row.Count
+	}
+
+	// Daqui em diante, apenas devoluções que não foram rejeitadas nem canceladas
+	active := period.Where("rr.status NOT IN ?", closedReturnStatuses).Session(&gorm.Session{})
+
+	if err := active.
+		Joins("JOIN return_items ri ON ri.return_id = rr.id").
+		Select("ri.reason_code AS reason, COUNT(*) AS items, COALESCE(SUM(ri.quantity), 0) AS quantity, COALESCE(SUM(ri.total), 0) AS amount").
+		Group("ri.reason_code").
+		Order("quantity DESC").
+		Scan(&analytics.ByReason).Error; err != nil {
+		r.logger.Error("erro ao agrupar devoluções por motivo", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao agrupar devoluções por motivo")
+	}
+
+	if err := active.
+		Joins("JOIN return_items ri ON ri.return_id = rr.id").
+		Select("ri.product_id, MAX(ri.product_name) AS product_name, COALESCE(SUM(ri.quantity), 0) AS quantity, COALESCE(SUM(ri.total), 0) AS amount").
+		Group("ri.product_id").
+		Order("quantity DESC").
+		Limit(10).
+		Scan(&analytics.TopProducts).Error; err != nil {
+		r.logger.Error("erro ao agrupar devoluções por produto", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao agrupar devoluções por produto")
+	}
+
+	var totals struct {
+		RequestedAmount float64
+		ToApproval      float64
+		ToReceipt       float64
+		ToCredit        float64
+	}
+	if err := active.
+		Select(`COALESCE(SUM(rr.total_amount), 0) AS requested_amount,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (rr.approved_at - rr.requested_at)) / 86400), 0) AS to_approval,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (rr.received_at - rr.approved_at)) / 86400), 0) AS to_receipt,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (rr.credited_at - rr.received_at)) / 86400), 0) AS to_credit`).
+		Scan(&totals).Error; err != nil {
+		r.logger.Error("erro ao totalizar devoluções", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao totalizar devoluções")
+	}
+	analytics.RequestedAmount = totals.RequestedAmount
+	analytics.AverageDays = models.ReturnProcessingTimes{
+		ToApproval: totals.ToApproval,
+		ToReceipt:  totals.ToReceipt,
+		ToCredit:   totals.ToCredit,
+	}
+
+	var conditionRows []struct {
+		Condition string
+		Quantity  int
+	}
+	if err := active.
+		Joins("JOIN return_items ri ON ri.return_id = rr.id").
+		Where("ri.condition IS NOT NULL").
+		Select("ri.condition, COALESCE(SUM(ri.received_qty), 0) AS quantity").
+		Group("ri.condition").
+		Scan(&conditionRows).Error; err != nil {
+		r.logger.Error("erro ao agrupar itens recebidos por condição", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao agrupar itens recebidos por condição")
+	}
+	for _, row := range conditionRows {
+		analytics.ReturnedQuantity += row.Quantity
+		switch row.Condition {
+		case models.ReturnConditionResellable:
+			analytics.Restock.Resellable = row.Quantity
+		case models.ReturnConditionDamaged:
+			analytics.Restock.Damaged = row.Quantity
+		}
+	}
+
+	if err := db.Conn(ctx, r.db).Table("credit_notes cn").
+		Joins("JOIN return_requests rr ON rr.credit_note_id = cn.id").
+		Scopes(tenant.Scope(ctx, "cn")).
+		Where("rr.requested_at BETWEEN ? AND ? AND cn.status <> ?", from, to, sales.CreditNoteStatusCancelled).
+		Select("COALESCE(SUM(cn.amount), 0)").
+		Scan(&analytics.CreditedAmount).Error; err != nil {
+		r.logger.Error("erro ao totalizar notas de crédito de devoluções", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao totalizar notas de crédito de devoluções")
+	}
+
+	if err := db.Conn(ctx, r.db).Table("delivery_items di").
+		Joins("JOIN deliveries d ON d.id = di.delivery_id").
+		Scopes(tenant.Scope(ctx, "d")).
+		Where("d.sales_order_id > 0 AND d.status IN ? AND d.delivery_date BETWEEN ? AND ?",
+			[]string{sales.DeliveryStatusShipped, sales.DeliveryStatusDelivered, sales.DeliveryStatusReturned}, from, to).
+		Select("COALESCE(SUM(di.quantity), 0)").
+		Scan(&analytics.DeliveredQuantity).Error; err != nil {
+		r.logger.Error("erro ao totalizar itens entregues", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao totalizar itens entregues")
+	}
+
+	analytics.ReturnRate = models.ReturnRate(analytics.ReturnedQuantity, analytics.DeliveredQuantity)
+	return analytics, nil
+}
+
+// lockReturn busca a devolução com bloqueio para atualização
+func (r *returnRepository) lockReturn(tx *gorm.DB, id int) (*models.ReturnRequest, error) {
+	var ret models.ReturnRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ret, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrReturnNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar devolução")
+	}
+	return &ret, nil
+}
+
+// loadDeliveryLines carrega os itens da entrega de venda com o saldo devolvível e o preço do pedido
+func (r *returnRepository) loadDeliveryLines(ctx context.Context, tx *gorm.DB, ret *models.ReturnRequest) (map[int]models.ReturnableLine, error) {
+	var delivery sales.Delivery
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&delivery, *ret.DeliveryID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar delivery")
+	}
+
+	if delivery.SalesOrderID == 0 {
+		return nil, errors.ErrDeliveryNotOutbound
+	}
+	if delivery.Status != sales.DeliveryStatusShipped && delivery.Status != sales.DeliveryStatusDelivered {
+		return nil, errors.ErrDocumentNotReturnable
+	}
+
+	var order sales.SalesOrder
+	if err := tx.Preload("Items").First(&order, delivery.SalesOrderID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrSalesOrderNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar pedido de venda")
+	}
+
+	var items []sales.DeliveryItem
+	if err := tx.Where("delivery_id = ?", delivery.ID).Find(&items).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar itens da delivery")
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	returned, err := r.returnedQuantities(tx, "delivery_item_id", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[int]models.ReturnableLine, len(items))
+	for _, item := range items {
+		lines[item.ID] = models.ReturnableLine{
+			SourceItemID: item.ID,
+			ProductID:    item.ProductID,
+			ProductName:  item.ProductName,
+			ProductCode:  item.ProductCode,
+			Quantity:     item.Quantity,
+			Returned:     returned[item.ID],
+			UnitPrice:    orderUnitValue(order.Items, item),
+		}
+	}
+
+	soID := order.ID
+	ret.SalesOrderID = &soID
+	ret.ContactID = order.ContactID
+	return lines, nil
+}
+
+// loadInvoiceLines carrega os itens da fatura com o saldo devolvível e o valor líquido faturado,
+// em unidades de estoque
+func (r *returnRepository) loadInvoiceLines(ctx context.Context, tx *gorm.DB, ret *models.ReturnRequest) (map[int]models.ReturnableLine, error) {
+	var invoice sales.Invoice
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, *ret.InvoiceID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar invoice")
+	}
+
+	if invoice.Status == sales.InvoiceStatusDraft || invoice.Status == sales.InvoiceStatusCancelled {
+		return nil, errors.ErrDocumentNotReturnable
+	}
+
+	var items []sales.InvoiceItem
+	if err := tx.Where("invoice_id = ?", invoice.ID).Find(&items).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar itens da invoice")
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	returned, err := r.returnedQuantities(tx, "invoice_item_id", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[int]models.ReturnableLine, len(items))
+	for _, item := range items {
+		lines[item.ID] = models.ReturnableLine{
+			SourceItemID: item.ID,
+			ProductID:    item.ProductID,
+			ProductName:  item.ProductName,
+			ProductCode:  item.ProductCode,
+			Quantity:     item.BaseQuantity(),
+			Returned:     returned[item.ID],
+			UnitPrice:    models.UnitValue(item.Total, item.UnitPrice.DivInt(max(item.UnitFactor, 1)), item.BaseQuantity()),
+		}
+	}
+
+	if invoice.SalesOrderID > 0 {
+		soID := invoice.SalesOrderID
+		ret.SalesOrderID = &soID
+	}
+	ret.ContactID = invoice.ContactID
+	return lines, nil
+}
+
+// returnedQuantities soma, por item de origem, o que já foi devolvido: a quantidade solicitada
+// nas devoluções em andamento e a quantidade recebida nas já recebidas
+func (r *returnRepository) returnedQuantities(tx *gorm.DB, column string, ids []int) (map[int]int, error) {
+	result := make(map[int]int)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		SourceID int
+		Quantity int
+	}
+	if err := tx.Table("return_items ri").
+		Joins("JOIN return_requests rr ON rr.id = ri.return_id").
+		Where("ri."+column+" IN ? AND rr.status NOT IN ?", ids, closedReturnStatuses).
+		Select("ri."+column+" AS source_id, COALESCE(SUM(CASE WHEN rr.status IN (?) THEN ri.received_qty ELSE ri.quantity END), 0) AS quantity",
+			[]string{models.ReturnStatusReceived, models.ReturnStatusCredited}).
+		Group("ri." + column).
+		Scan(&rows).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar quantidades já devolvidas")
+	}
+
+	for _, row := range rows {
+		result[row.SourceID] = row.Quantity
+	}
+	return result, nil
+}
+
+// restockItem devolve o item ao estoque e cria a camada de custo com o custo apurado na venda
+// (ou o custo médio atual, se a venda não teve CMV apurado)
+func (r *returnRepository) restockItem(tx *gorm.DB, item *models.ReturnItem, receivedAt time.Time) error {
+	if err := tx.Table("products").Where("id = ?", item.ProductID).
+		Update("stock", gorm.Expr("stock + ?", item.ReceivedQty)).Error; err != nil {
+		r.logger.Error("erro ao devolver item ao estoque", zap.Error(err), zap.Int("product_id", item.ProductID))
+		return errors.WrapError(err, "falha ao devolver item ao estoque")
+	}
+
+	var costing inventory.ProductCosting
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", item.ProductID).
+		First(&costing).Error
+	if err == gorm.ErrRecordNotFound {
+		// Produto sem custeio apurado: não há camadas a recompor
+		return nil
+	}
+	if err != nil {
+		return errors.WrapError(err, "falha ao buscar custeio do produto")
+	}
+
+	unitCost := costing.AverageCost
+	var sold inventory.COGSEntry
+	query := tx.Model(&inventory.COGSEntry{})
+	switch {
+	case item.DeliveryItemID != nil:
+		query = query.Where("source_type = ? AND source_item_id = ?", inventory.COGSSourceDelivery, *item.DeliveryItemID)
+	case item.InvoiceItemID != nil:
+		query = query.Where("source_type = ? AND source_item_id = ?", inventory.COGSSourceInvoice, *item.InvoiceItemID)
+	}
+	if err := query.Limit(1).Find(&sold).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar CMV do item devolvido")
+	}
+	if sold.ID > 0 {
+		unitCost = sold.UnitCost
+	}
+
+	costing.ApplyReceipt(item.ReceivedQty, unitCost)
+	if err := tx.Save(&costing).Error; err != nil {
+		return errors.WrapError(err, "falha ao atualizar custo médio")
+	}
+
+	itemID := item.ID
+	layer := inventory.CostLayer{
+		ProductID:         item.ProductID,
+		ReturnItemID:      &itemID,
+		ReceivedAt:        receivedAt,
+		Quantity:          item.ReceivedQty,
+		RemainingQuantity: item.ReceivedQty,
+		UnitCost:          unitCost,
+	}
+	if err := tx.Create(&layer).Error; err != nil {
+		r.logger.Error("erro ao criar camada de custo da devolução", zap.Error(err), zap.Int("return_item_id", item.ID))
+		return errors.WrapError(err, "falha ao criar camada de custo")
+	}
+	return nil
+}
+
+// markDeliveryReturned marca a entrega como devolvida quando todos os seus itens foram recebidos de volta
+func (r *returnRepository) markDeliveryReturned(tx *gorm.DB, deliveryID int, returnNo string) error {
+	var pending int64
+	if err := tx.Table("delivery_items di").
+		Where(`di.delivery_id = ? AND di.quantity > COALESCE((
+			SELECT SUM(ri.received_qty) FROM return_items ri
+			JOIN return_requests rr ON rr.id = ri.return_id
+			WHERE ri.delivery_item_id = di.id AND rr.status IN ?), 0)`,
+			deliveryID, []string{models.ReturnStatusReceived, models.ReturnStatusCredited}).
+		Count(&pending).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar itens devolvidos da delivery")
+	}
+	if pending > 0 {
+		return nil
+	}
+
+	var delivery sales.Delivery
+	if err := tx.Select("id", "notes").First(&delivery, deliveryID).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar delivery")
+	}
+
+	if err := tx.Model(&sales.Delivery{}).Where("id = ?", deliveryID).
+		Updates(map[string]interface{}{
+			"status": sales.DeliveryStatusReturned,
+			"notes":  appendNote(delivery.Notes, "Devolvido: "+returnNo),
+		}).Error; err != nil {
+		return errors.WrapError(err, "falha ao marcar delivery como devolvida")
+	}
+	return nil
+}
+
+// orderUnitValue busca o valor unitário líquido (por unidade de estoque) do item do pedido
+// correspondente ao item entregue
+func orderUnitValue(orderItems []sales.SOItem, item sales.DeliveryItem) money.Decimal {
+	for _, so := range orderItems {
+		if item.SOItemID != nil && so.ID == *item.SOItemID {
+			return models.UnitValue(so.Total, so.UnitPrice.DivInt(max(so.UnitFactor, 1)), so.BaseQuantity())
+		}
+	}
+	for _, so := range orderItems {
+		if so.ProductID == item.ProductID {
+			return models.UnitValue(so.Total, so.UnitPrice.DivInt(max(so.UnitFactor, 1)), so.BaseQuantity())
+		}
+	}
+	return money.Zero
+}
+
+// appendNote acrescenta uma observação às notas existentes
+func appendNote(notes, note string) string {
+	if notes == "" {
+		return note
+	}
+	return notes + " | " + note
+}
+
+// generateReturnNumber gera um número único para a devolução
+func (r *returnRepository) generateReturnNumber(tx *gorm.DB) string {
+	var last models.ReturnRequest
+	tx.Order("id DESC").Limit(1).Find(&last)
+	return fmt.Sprintf("RMA-%d-%06d", time.Now().Year(), last.ID+1)
+}
+
+// generateCreditNoteNumber gera um número único para a nota de crédito
+func (r *returnRepository) generateCreditNoteNumber(tx *gorm.DB) string {
+	var last sales.CreditNote
+	tx.Order("id DESC").Limit(1).Find(&last)
+	return fmt.Sprintf("CN-%d-%06d", time.Now().Year(), last.ID+1)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/returns/models"
+	"ERP-ONSMART/backend/internal/modules/returns/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"time"
+)
+
+// CreateReturnInput reúne os dados da solicitação de devolução
+type CreateReturnInput struct {
+	DeliveryID *int                       `json:"delivery_id"`
+	InvoiceID  *int                       `json:"invoice_id"`
+	Reason     string                     `json:"reason"`
+	Notes      string                     `json:"notes"`
+	Items      []models.ReturnLineRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// CreateReturn abre uma solicitação de devolução para itens de uma entrega ou fatura
+func CreateReturn(ctx context.Context, input CreateReturnInput) (*models.ReturnRequest, error) {
+	if input.DeliveryID == nil && input.InvoiceID == nil {
+		return nil, errors.ErrReturnSourceRequired
+	}
+
+	repo, err := repository.NewReturnRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &models.ReturnRequest{
+		DeliveryID: input.DeliveryID,
+		InvoiceID:  input.InvoiceID,
+		Reason:     input.Reason,
+		Notes:      input.Notes,
+	}
+	return repo.CreateReturn(ctx, ret, input.Items)
+}
+
+// GetReturn retorna uma devolução com seus itens
+func GetReturn(ctx context.Context, id int) (*models.ReturnRequest, error) {
+	repo, err := repository.NewReturnRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetReturnByID(ctx, id)
+}
+
+// GetAllReturns lista as devoluções, opcionalmente filtradas por status
+func GetAllReturns(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewReturnRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAllReturns(ctx, status, params)
+}
+
+// ApproveReturn aprova a devolução solicitada, registrando o aprovador
+func ApproveReturn(ctx context.Context, id int, approvedBy string) (*models.ReturnRequest, error) {
+	return changeStatus(ctx, id, models.ReturnStatusApproved, "", approvedBy)
+}
+
+// RejectReturn rejeita a devolução solicitada, registrando o motivo
+func RejectReturn(ctx context.Context, id int, reason string) (*models.ReturnRequest, error) {
+	return changeStatus(ctx, id, models.ReturnStatusRejected, reason, "")
+}
+
+// CancelReturn cancela a devolução antes do recebimento dos itens
+func CancelReturn(ctx context.Context, id int, reason string) (*models.ReturnRequest, error) {
+	return changeStatus(ctx, id, models.ReturnStatusCancelled, reason, "")
+}
+
+// ReceiveReturn registra o recebimento dos itens devolvidos e a reentrada em estoque
+func ReceiveReturn(ctx context.Context, id int, lines []models.ReceiveLine) (*models.ReturnRequest, error) {
+	repo, err := repository.NewReturnRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ReceiveReturn(ctx, id, lines)
+}
+
+// IssueCreditNote gera a nota de crédito da devolução recebida
+func IssueCreditNote(ctx context.Context, id int) (*sales.CreditNote, error) {
+	repo, err := repository.NewReturnRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.IssueCreditNote(ctx, id)
+}
+
+// GetReturnAnalytics consolida as devoluções do período; sem datas, considera os últimos 30 dias
+func GetReturnAnalytics(ctx context.Context, from, to time.Time) (*models.ReturnAnalytics, error) {
+	from, to, err := analyticsPeriod(from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewReturnRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAnalytics(ctx, from, to)
+}
+
+func changeStatus(ctx context.Context, id int, status, note, user string) (*models.ReturnRequest, error) {
+	repo, err := repository.NewReturnRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ChangeStatus(ctx, id, status, note, user)
+}
+
+// analyticsPeriod aplica o período padrão e inclui o dia final inteiro
+func analyticsPeriod(from, to, now time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = now
+	} else {
+		to = time.Date(to.Year(), to.Month(), to.Day(), 23, 59, 59, 0, to.Location())
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if from.After(to) {
+		return from, to, errors.ErrInvalidDateRange
+	}
+	return from, to, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateReturnRequiresSource(t *testing.T) {
+	_, err := CreateReturn(context.Background(), CreateReturnInput{})
+	assert.Equal(t, errors.ErrReturnSourceRequired, err)
+}
+
+func TestAnalyticsPeriodDefaults(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+
+	from, to, err := analyticsPeriod(time.Time{}, time.Time{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, to)
+	assert.Equal(t, now.AddDate(0, 0, -30), from)
+}
+
+func TestAnalyticsPeriodIncludesLastDay(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	_, end, err := analyticsPeriod(from, to, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 23, end.Hour())
+	assert.Equal(t, 10, end.Day())
+
+	_, _, err = analyticsPeriod(to.AddDate(0, 0, 1), to, time.Now())
+	assert.Equal(t, errors.ErrInvalidDateRange, err)
+}
@@ -0,0 +1,30 @@
+package dtos
+
+// Os lotes aceitam até models.MaxBatchSize itens. Com atomic=true, qualquer item rejeitado
+// desfaz o lote inteiro; caso contrário, cada item é gravado ou rejeitado de forma independente.
+
+// InvoiceBatchDTO representa um lote de faturas a criar
+type InvoiceBatchDTO struct {
+	Atomic bool               `json:"atomic"`
+	Items  []InvoiceCreateDTO `json:"items"`
+}
+
+// PaymentBatchDTO representa um lote de pagamentos a registrar
+type PaymentBatchDTO struct {
+	Atomic bool               `json:"atomic"`
+	Items  []PaymentCreateDTO `json:"items"`
+}
+
+// DeliveryStatusBatchDTO representa um lote de alterações de status de entregas
+type DeliveryStatusBatchDTO struct {
+	Atomic bool                         `json:"atomic"`
+	Items  []DeliveryStatusBatchItemDTO `json:"items"`
+}
+
+// DeliveryStatusBatchItemDTO representa a alteração de status de uma entrega
+type DeliveryStatusBatchItemDTO struct {
+	DeliveryID     int    `json:"delivery_id" validate:"required"`
+	Status         string `json:"status" validate:"required,oneof=pending shipped delivered returned"`
+	TrackingNumber string `json:"tracking_number,omitempty" validate:"max=100"`
+	Notes          string `json:"notes,omitempty"`
+}
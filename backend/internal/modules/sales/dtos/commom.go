@@ -1,6 +1,9 @@
 package dtos
 
-import "time"
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
 
 // DateRange representa um intervalo de datas
 type DateRange struct {
@@ -38,16 +41,16 @@ type SortOptions struct {
 
 // ItemDTO representa um item genérico usado em vários documentos
 type ItemDTO struct {
-	ID          int     `json:"id,omitempty"`
-	ProductID   int     `json:"product_id" validate:"required"`
-	ProductName string  `json:"product_name"`
-	ProductCode string  `json:"product_code"`
-	Description string  `json:"description,omitempty"`
-	Quantity    int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
-	Discount    float64 `json:"discount" validate:"min=0,max=100"`
-	Tax         float64 `json:"tax" validate:"min=0"`
-	Total       float64 `json:"total"`
+	ID          int           `json:"id,omitempty"`
+	ProductID   int           `json:"product_id" validate:"required"`
+	ProductName string        `json:"product_name"`
+	ProductCode string        `json:"product_code"`
+	Description string        `json:"description,omitempty"`
+	Quantity    int           `json:"quantity" validate:"required,gt=0"`
+	UnitPrice   money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	Discount    money.Decimal `json:"discount" validate:"min=0,max=100"`
+	Tax         money.Decimal `json:"tax" validate:"min=0"`
+	Total       money.Decimal `json:"total"`
 }
 
 // StatusCount representa contagem por status
@@ -90,3 +93,12 @@ type TimeRange struct {
 	Start time.Time `json:"start"`
 	End   time.Time `json:"end"`
 }
+
+// ShippingOptionDTO representa o frete escolhido na cotação ou no pedido de venda
+type ShippingOptionDTO struct {
+	Carrier      string        `json:"carrier"`
+	ServiceCode  string        `json:"service_code,omitempty"`
+	ServiceName  string        `json:"service_name"`
+	Cost         money.Decimal `json:"cost"`
+	DeliveryDays int           `json:"delivery_days"`
+}
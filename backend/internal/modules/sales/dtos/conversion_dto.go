@@ -0,0 +1,46 @@
+package dtos
+
+import (
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// QuotationConversionDTO representa os dados opcionais do pedido gerado a partir da cotação
+type QuotationConversionDTO struct {
+	ExpectedDate      time.Time           `json:"expected_date"`
+	PaymentTerms      string              `json:"payment_terms,omitempty" validate:"max=100"`
+	ShippingAddress   string              `json:"shipping_address,omitempty"`
+	ShippingAddressID *int                `json:"shipping_address_id,omitempty"`
+	BillingAddressID  *int                `json:"billing_address_id,omitempty"`
+	CustomFields      customfields.Values `json:"custom_fields,omitempty"`
+	// DownPayment pede um adiantamento na confirmação, faturado junto com o pedido
+	DownPayment *DownPaymentDTO `json:"down_payment,omitempty"`
+}
+
+// DownPaymentDTO representa o adiantamento pedido: percentual do total do pedido ou valor fixo
+type DownPaymentDTO struct {
+	Percent float64       `json:"percent,omitempty" validate:"gte=0,lte=100"`
+	Amount  money.Decimal `json:"amount,omitempty"`
+}
+
+// InvoiceGenerationDTO representa os dados opcionais da fatura gerada a partir do pedido de venda
+type InvoiceGenerationDTO struct {
+	IssueDate time.Time `json:"issue_date"`
+	DueDate   time.Time `json:"due_date" validate:"omitempty,gtefield=IssueDate"`
+}
+
+// AdvanceInvoiceGenerationDTO representa o adiantamento e as datas opcionais da fatura de
+// adiantamento do pedido de venda
+type AdvanceInvoiceGenerationDTO struct {
+	DownPaymentDTO
+	IssueDate time.Time `json:"issue_date"`
+	DueDate   time.Time `json:"due_date" validate:"omitempty,gtefield=IssueDate"`
+}
+
+// DeliveryGenerationDTO representa os dados opcionais da entrega gerada a partir do pedido de venda
+type DeliveryGenerationDTO struct {
+	DeliveryDate   time.Time `json:"delivery_date"`
+	ShippingMethod string    `json:"shipping_method,omitempty" validate:"max=100"`
+	Carrier        string    `json:"carrier,omitempty" validate:"max=100"`
+}
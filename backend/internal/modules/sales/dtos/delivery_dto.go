@@ -8,6 +8,7 @@ type DeliveryCreateDTO struct {
 	SalesOrderID    int                     `json:"sales_order_id,omitempty"`
 	DeliveryDate    time.Time               `json:"delivery_date" validate:"required"`
 	ShippingMethod  string                  `json:"shipping_method,omitempty"`
+	Carrier         string                  `json:"carrier,omitempty"`
 	ShippingAddress string                  `json:"shipping_address" validate:"required"`
 	Notes           string                  `json:"notes,omitempty"`
 	Items           []DeliveryItemCreateDTO `json:"items" validate:"required,min=1,dive"`
@@ -18,6 +19,7 @@ type DeliveryUpdateDTO struct {
 	DeliveryDate    *time.Time `json:"delivery_date,omitempty"`
 	ReceivedDate    *time.Time `json:"received_date,omitempty"`
 	ShippingMethod  *string    `json:"shipping_method,omitempty"`
+	Carrier         *string    `json:"carrier,omitempty"`
 	TrackingNumber  *string    `json:"tracking_number,omitempty"`
 	ShippingAddress *string    `json:"shipping_address,omitempty"`
 	Notes           *string    `json:"notes,omitempty"`
@@ -25,23 +27,26 @@ type DeliveryUpdateDTO struct {
 
 // DeliveryResponseDTO representa os dados retornados de uma delivery
 type DeliveryResponseDTO struct {
-	ID              int                       `json:"id"`
-	DeliveryNo      string                    `json:"delivery_no"`
-	PurchaseOrderID int                       `json:"purchase_order_id,omitempty"`
-	PONo            string                    `json:"po_no,omitempty"`
-	SalesOrderID    int                       `json:"sales_order_id,omitempty"`
-	SONo            string                    `json:"so_no,omitempty"`
-	Status          string                    `json:"status"`
-	CreatedAt       time.Time                 `json:"created_at"`
-	UpdatedAt       time.Time                 `json:"updated_at"`
-	DeliveryDate    time.Time                 `json:"delivery_date"`
-	ReceivedDate    *time.Time                `json:"received_date,omitempty"`
-	ShippingMethod  string                    `json:"shipping_method,omitempty"`
-	TrackingNumber  string                    `json:"tracking_number,omitempty"`
-	ShippingAddress string                    `json:"shipping_address"`
-	Notes           string                    `json:"notes,omitempty"`
-	Items           []DeliveryItemResponseDTO `json:"items,omitempty"`
-	Contact         *ContactBasicInfo         `json:"contact,omitempty"`
+	ID                int                       `json:"id"`
+	DeliveryNo        string                    `json:"delivery_no"`
+	PurchaseOrderID   int                       `json:"purchase_order_id,omitempty"`
+	PONo              string                    `json:"po_no,omitempty"`
+	SalesOrderID      int                       `json:"sales_order_id,omitempty"`
+	SONo              string                    `json:"so_no,omitempty"`
+	Status            string                    `json:"status"`
+	CreatedAt         time.Time                 `json:"created_at"`
+	UpdatedAt         time.Time                 `json:"updated_at"`
+	DeliveryDate      time.Time                 `json:"delivery_date"`
+	ReceivedDate      *time.Time                `json:"received_date,omitempty"`
+	ShippingMethod    string                    `json:"shipping_method,omitempty"`
+	Carrier           string                    `json:"carrier,omitempty"`
+	TrackingNumber    string                    `json:"tracking_number,omitempty"`
+	ShippingAddress   string                    `json:"shipping_address"`
+	ShippingAddressID *int                      `json:"shipping_address_id,omitempty"`
+	Notes             string                    `json:"notes,omitempty"`
+	DriverID          *int                      `json:"driver_id,omitempty"`
+	Items             []DeliveryItemResponseDTO `json:"items,omitempty"`
+	Contact           *ContactBasicInfo         `json:"contact,omitempty"`
 }
 
 // DeliveryListItemDTO representa uma versão resumida para listagens
@@ -64,6 +69,7 @@ type DeliveryItemCreateDTO struct {
 	ProductCode string `json:"product_code,omitempty"`
 	Description string `json:"description,omitempty"`
 	Quantity    int    `json:"quantity" validate:"required,gt=0"`
+	Unit        string `json:"unit,omitempty" validate:"omitempty,max=10"`
 	Notes       string `json:"notes,omitempty"`
 }
 
@@ -82,6 +88,7 @@ type DeliveryItemResponseDTO struct {
 	ProductCode string `json:"product_code"`
 	Description string `json:"description,omitempty"`
 	Quantity    int    `json:"quantity"`
+	Unit        string `json:"unit"`
 	ReceivedQty int    `json:"received_qty"`
 	Notes       string `json:"notes,omitempty"`
 	Status      string `json:"status"` // pending, partial, complete
@@ -97,6 +104,7 @@ type DeliveryStatusUpdateDTO struct {
 type MarkAsShippedDTO struct {
 	TrackingNumber string `json:"tracking_number" validate:"required"`
 	ShippingMethod string `json:"shipping_method,omitempty"`
+	Carrier        string `json:"carrier,omitempty"`
 	Notes          string `json:"notes,omitempty"`
 }
 
@@ -1,51 +1,79 @@
 package dtos
 
-import "time"
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
 
 // InvoiceCreateDTO representa os dados para criar uma invoice
 type InvoiceCreateDTO struct {
-	SalesOrderID int                    `json:"sales_order_id,omitempty"`
-	ContactID    int                    `json:"contact_id" validate:"required"`
-	IssueDate    time.Time              `json:"issue_date" validate:"required"`
-	DueDate      time.Time              `json:"due_date" validate:"required"`
-	PaymentTerms string                 `json:"payment_terms,omitempty"`
-	Notes        string                 `json:"notes,omitempty"`
-	Items        []InvoiceItemCreateDTO `json:"items" validate:"required,min=1,dive"`
+	SalesOrderID  int                    `json:"sales_order_id,omitempty"`
+	ContactID     int                    `json:"contact_id" validate:"required"`
+	SalespersonID *int                   `json:"salesperson_id,omitempty"`
+	IssueDate     time.Time              `json:"issue_date" validate:"required"`
+	DueDate       time.Time              `json:"due_date" validate:"required,gtefield=IssueDate"`
+	PaymentTerms  string                 `json:"payment_terms,omitempty"`
+	Notes         string                 `json:"notes,omitempty"`
+	Items         []InvoiceItemCreateDTO `json:"items" validate:"required,min=1,dive"`
 }
 
 // InvoiceUpdateDTO representa os dados para atualizar uma invoice
 type InvoiceUpdateDTO struct {
-	IssueDate    *time.Time `json:"issue_date,omitempty"`
-	DueDate      *time.Time `json:"due_date,omitempty"`
-	PaymentTerms *string    `json:"payment_terms,omitempty"`
-	Notes        *string    `json:"notes,omitempty"`
+	SalespersonID *int       `json:"salesperson_id,omitempty"`
+	IssueDate     *time.Time `json:"issue_date,omitempty"`
+	DueDate       *time.Time `json:"due_date,omitempty"`
+	PaymentTerms  *string    `json:"payment_terms,omitempty"`
+	Notes         *string    `json:"notes,omitempty"`
+}
+
+// InvoiceInstallmentPlanDTO divide a invoice em parcelas: informe installments com vencimento e
+// valor de cada uma, ou count para parcelas iguais a partir de first_due_date (padrão: o
+// vencimento da invoice), a cada interval_days dias ou, sem intervalo, mês a mês
+type InvoiceInstallmentPlanDTO struct {
+	Count        int                     `json:"count,omitempty" validate:"required_without=Installments,omitempty,min=1,max=120"`
+	FirstDueDate *time.Time              `json:"first_due_date,omitempty"`
+	IntervalDays int                     `json:"interval_days,omitempty" validate:"omitempty,min=1"`
+	Installments []InvoiceInstallmentDTO `json:"installments,omitempty" validate:"omitempty,max=120,dive"`
+}
+
+// InvoiceInstallmentDTO é uma parcela informada explicitamente
+type InvoiceInstallmentDTO struct {
+	DueDate time.Time     `json:"due_date" validate:"required"`
+	Amount  money.Decimal `json:"amount" validate:"required"`
 }
 
 // InvoiceResponseDTO representa os dados retornados de uma invoice
 type InvoiceResponseDTO struct {
-	ID            int                      `json:"id"`
-	InvoiceNo     string                   `json:"invoice_no"`
-	SalesOrderID  int                      `json:"sales_order_id,omitempty"`
-	SONo          string                   `json:"so_no,omitempty"`
-	ContactID     int                      `json:"contact_id"`
-	Contact       *ContactBasicInfo        `json:"contact,omitempty"`
-	Status        string                   `json:"status"`
-	CreatedAt     time.Time                `json:"created_at"`
-	UpdatedAt     time.Time                `json:"updated_at"`
-	IssueDate     time.Time                `json:"issue_date"`
-	DueDate       time.Time                `json:"due_date"`
-	SubTotal      float64                  `json:"subtotal"`
-	TaxTotal      float64                  `json:"tax_total"`
-	DiscountTotal float64                  `json:"discount_total"`
-	GrandTotal    float64                  `json:"grand_total"`
-	AmountPaid    float64                  `json:"amount_paid"`
-	BalanceDue    float64                  `json:"balance_due"`
-	PaymentTerms  string                   `json:"payment_terms,omitempty"`
-	Notes         string                   `json:"notes,omitempty"`
-	Items         []InvoiceItemResponseDTO `json:"items,omitempty"`
-	Payments      []PaymentResponseDTO     `json:"payments,omitempty"`
-	IsOverdue     bool                     `json:"is_overdue"`
-	DaysOverdue   int                      `json:"days_overdue,omitempty"`
+	ID            int               `json:"id"`
+	InvoiceNo     string            `json:"invoice_no"`
+	SalesOrderID  int               `json:"sales_order_id,omitempty"`
+	SONo          string            `json:"so_no,omitempty"`
+	ContactID     int               `json:"contact_id"`
+	SalespersonID *int              `json:"salesperson_id,omitempty"`
+	Contact       *ContactBasicInfo `json:"contact,omitempty"`
+	Status        string            `json:"status"`
+	Kind          string            `json:"kind"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	IssueDate     time.Time         `json:"issue_date"`
+	DueDate       time.Time         `json:"due_date"`
+	SubTotal      money.Decimal     `json:"subtotal"`
+	TaxTotal      money.Decimal     `json:"tax_total"`
+	DiscountTotal money.Decimal     `json:"discount_total"`
+	GrandTotal    money.Decimal     `json:"grand_total"`
+	AmountPaid    money.Decimal     `json:"amount_paid"`
+	BalanceDue    money.Decimal     `json:"balance_due"`
+	PaymentTerms  string            `json:"payment_terms,omitempty"`
+	Notes         string            `json:"notes,omitempty"`
+	// Desconto por pagamento antecipado da condição do contato
+	EarlyDiscountAmount money.Decimal `json:"early_discount_amount"`
+	EarlyDiscountUntil  *time.Time    `json:"early_discount_until,omitempty"`
+	// Adiantamento do pedido abatido da fatura; GrandTotal já vem líquido
+	AdvanceDeduction money.Decimal            `json:"advance_deduction"`
+	Items            []InvoiceItemResponseDTO `json:"items,omitempty"`
+	Payments         []PaymentResponseDTO     `json:"payments,omitempty"`
+	IsOverdue        bool                     `json:"is_overdue"`
+	DaysOverdue      int                      `json:"days_overdue,omitempty"`
 }
 
 // InvoiceListItemDTO representa uma versão resumida para listagens
@@ -57,47 +85,50 @@ type InvoiceListItemDTO struct {
 	Status      string            `json:"status"`
 	IssueDate   time.Time         `json:"issue_date"`
 	DueDate     time.Time         `json:"due_date"`
-	GrandTotal  float64           `json:"grand_total"`
-	AmountPaid  float64           `json:"amount_paid"`
-	BalanceDue  float64           `json:"balance_due"`
+	GrandTotal  money.Decimal     `json:"grand_total"`
+	AmountPaid  money.Decimal     `json:"amount_paid"`
+	BalanceDue  money.Decimal     `json:"balance_due"`
 	IsOverdue   bool              `json:"is_overdue"`
 	DaysOverdue int               `json:"days_overdue,omitempty"`
 }
 
 // InvoiceItemCreateDTO representa os dados para criar um item de invoice
 type InvoiceItemCreateDTO struct {
-	ProductID   int     `json:"product_id" validate:"required"`
-	ProductName string  `json:"product_name,omitempty"`
-	ProductCode string  `json:"product_code,omitempty"`
-	Description string  `json:"description,omitempty"`
-	Quantity    int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
-	Discount    float64 `json:"discount" validate:"min=0,max=100"`
-	Tax         float64 `json:"tax" validate:"min=0"`
+	ProductID   int           `json:"product_id" validate:"required"`
+	ProductName string        `json:"product_name,omitempty"`
+	ProductCode string        `json:"product_code,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Quantity    int           `json:"quantity" validate:"required,gt=0"`
+	Unit        string        `json:"unit,omitempty" validate:"omitempty,max=10"`
+	UnitPrice   money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	Discount    money.Decimal `json:"discount" validate:"min=0,max=100"`
+	Tax         money.Decimal `json:"tax" validate:"min=0"`
 }
 
 // InvoiceItemUpdateDTO representa os dados para atualizar um item
 type InvoiceItemUpdateDTO struct {
-	Quantity    *int     `json:"quantity,omitempty" validate:"omitempty,gt=0"`
-	UnitPrice   *float64 `json:"unit_price,omitempty" validate:"omitempty,gt=0"`
-	Discount    *float64 `json:"discount,omitempty" validate:"omitempty,min=0,max=100"`
-	Tax         *float64 `json:"tax,omitempty" validate:"omitempty,min=0"`
-	Description *string  `json:"description,omitempty"`
+	Quantity    *int           `json:"quantity,omitempty" validate:"omitempty,gt=0"`
+	UnitPrice   *money.Decimal `json:"unit_price,omitempty" validate:"omitempty,gt=0"`
+	Discount    *money.Decimal `json:"discount,omitempty" validate:"omitempty,min=0,max=100"`
+	Tax         *money.Decimal `json:"tax,omitempty" validate:"omitempty,min=0"`
+	Description *string        `json:"description,omitempty"`
 }
 
 // InvoiceItemResponseDTO representa os dados retornados de um item
 type InvoiceItemResponseDTO struct {
-	ID          int     `json:"id"`
-	InvoiceID   int     `json:"invoice_id"`
-	ProductID   int     `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	ProductCode string  `json:"product_code"`
-	Description string  `json:"description,omitempty"`
-	Quantity    int     `json:"quantity"`
-	UnitPrice   float64 `json:"unit_price"`
-	Discount    float64 `json:"discount"`
-	Tax         float64 `json:"tax"`
-	Total       float64 `json:"total"`
+	ID          int           `json:"id"`
+	InvoiceID   int           `json:"invoice_id"`
+	ProductID   int           `json:"product_id"`
+	ProductName string        `json:"product_name"`
+	ProductCode string        `json:"product_code"`
+	Description string        `json:"description,omitempty"`
+	Quantity    int           `json:"quantity"`
+	Unit        string        `json:"unit"`
+	UnitFactor  int           `json:"unit_factor"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	Discount    money.Decimal `json:"discount"`
+	Tax         money.Decimal `json:"tax"`
+	Total       money.Decimal `json:"total"`
 }
 
 // InvoiceStatusUpdateDTO representa dados para atualizar status
@@ -108,21 +139,21 @@ type InvoiceStatusUpdateDTO struct {
 
 // InvoicePaymentSummaryDTO representa resumo de pagamentos
 type InvoicePaymentSummaryDTO struct {
-	InvoiceID       int        `json:"invoice_id"`
-	InvoiceNo       string     `json:"invoice_no"`
-	GrandTotal      float64    `json:"grand_total"`
-	AmountPaid      float64    `json:"amount_paid"`
-	BalanceDue      float64    `json:"balance_due"`
-	LastPaymentDate *time.Time `json:"last_payment_date,omitempty"`
-	PaymentCount    int        `json:"payment_count"`
-	Status          string     `json:"status"`
+	InvoiceID       int           `json:"invoice_id"`
+	InvoiceNo       string        `json:"invoice_no"`
+	GrandTotal      money.Decimal `json:"grand_total"`
+	AmountPaid      money.Decimal `json:"amount_paid"`
+	BalanceDue      money.Decimal `json:"balance_due"`
+	LastPaymentDate *time.Time    `json:"last_payment_date,omitempty"`
+	PaymentCount    int           `json:"payment_count"`
+	Status          string        `json:"status"`
 }
 
 // CreateInvoiceFromSODTO representa dados para criar invoice de SO
 type CreateInvoiceFromSODTO struct {
 	SalesOrderID    int       `json:"sales_order_id" validate:"required"`
 	IssueDate       time.Time `json:"issue_date" validate:"required"`
-	DueDate         time.Time `json:"due_date" validate:"required"`
+	DueDate         time.Time `json:"due_date" validate:"required,gtefield=IssueDate"`
 	PaymentTerms    string    `json:"payment_terms,omitempty"`
 	Notes           string    `json:"notes,omitempty"`
 	IncludeAllItems bool      `json:"include_all_items"`
@@ -141,7 +172,7 @@ type InvoiceSendDTO struct {
 type InvoiceCloneDTO struct {
 	ContactID int       `json:"contact_id,omitempty"`
 	IssueDate time.Time `json:"issue_date" validate:"required"`
-	DueDate   time.Time `json:"due_date" validate:"required"`
+	DueDate   time.Time `json:"due_date" validate:"required,gtefield=IssueDate"`
 	Notes     string    `json:"notes,omitempty"`
 }
 
@@ -1,24 +1,41 @@
 package dtos
 
-import "time"
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
 
 // PaymentCreateDTO representa os dados para criar um payment
 type PaymentCreateDTO struct {
-	InvoiceID     int       `json:"invoice_id" validate:"required"`
-	Amount        float64   `json:"amount" validate:"required,gt=0"`
-	PaymentDate   time.Time `json:"payment_date" validate:"required"`
-	PaymentMethod string    `json:"payment_method" validate:"required"`
-	Reference     string    `json:"reference,omitempty"`
-	Notes         string    `json:"notes,omitempty"`
+	InvoiceID     int           `json:"invoice_id" validate:"required"`
+	Amount        money.Decimal `json:"amount" validate:"required,gt=0"`
+	PaymentDate   time.Time     `json:"payment_date" validate:"required"`
+	PaymentMethod string        `json:"payment_method" validate:"required"`
+	Reference     string        `json:"reference,omitempty"`
+	Notes         string        `json:"notes,omitempty"`
+	// Allocations distribui o pagamento entre faturas do mesmo contato; sem alocações, o valor
+	// vai para invoice_id até o saldo em aberto
+	Allocations []PaymentAllocationDTO `json:"allocations,omitempty" validate:"omitempty,dive"`
+}
+
+// PaymentAllocationDTO é a parte do pagamento destinada a uma fatura
+type PaymentAllocationDTO struct {
+	InvoiceID int           `json:"invoice_id" validate:"required"`
+	Amount    money.Decimal `json:"amount" validate:"required"`
+}
+
+// PaymentAllocationsUpdateDTO substitui a distribuição de um pagamento entre as faturas
+type PaymentAllocationsUpdateDTO struct {
+	Allocations []PaymentAllocationDTO `json:"allocations" validate:"required,min=1,dive"`
 }
 
 // PaymentUpdateDTO representa os dados para atualizar um payment
 type PaymentUpdateDTO struct {
-	Amount        *float64   `json:"amount,omitempty" validate:"omitempty,gt=0"`
-	PaymentDate   *time.Time `json:"payment_date,omitempty"`
-	PaymentMethod *string    `json:"payment_method,omitempty"`
-	Reference     *string    `json:"reference,omitempty"`
-	Notes         *string    `json:"notes,omitempty"`
+	Amount        *money.Decimal `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	PaymentDate   *time.Time     `json:"payment_date,omitempty"`
+	PaymentMethod *string        `json:"payment_method,omitempty"`
+	Reference     *string        `json:"reference,omitempty"`
+	Notes         *string        `json:"notes,omitempty"`
 }
 
 // PaymentResponseDTO representa os dados retornados de um payment
@@ -26,7 +43,7 @@ type PaymentResponseDTO struct {
 	ID            int               `json:"id"`
 	InvoiceID     int               `json:"invoice_id"`
 	InvoiceNo     string            `json:"invoice_no,omitempty"`
-	Amount        float64           `json:"amount"`
+	Amount        money.Decimal     `json:"amount"`
 	PaymentDate   time.Time         `json:"payment_date"`
 	PaymentMethod string            `json:"payment_method"`
 	Reference     string            `json:"reference,omitempty"`
@@ -42,7 +59,7 @@ type PaymentListItemDTO struct {
 	InvoiceID     int               `json:"invoice_id"`
 	InvoiceNo     string            `json:"invoice_no"`
 	Contact       *ContactBasicInfo `json:"contact,omitempty"`
-	Amount        float64           `json:"amount"`
+	Amount        money.Decimal     `json:"amount"`
 	PaymentDate   time.Time         `json:"payment_date"`
 	PaymentMethod string            `json:"payment_method"`
 	Reference     string            `json:"reference,omitempty"`
@@ -58,13 +75,13 @@ type PaymentReconciliationDTO struct {
 
 // ProcessInvoicePaymentDTO representa dados para processar pagamento
 type ProcessInvoicePaymentDTO struct {
-	InvoiceID     int       `json:"invoice_id" validate:"required"`
-	Amount        float64   `json:"amount" validate:"required,gt=0"`
-	PaymentMethod string    `json:"payment_method" validate:"required"`
-	Reference     string    `json:"reference,omitempty"`
-	PaymentDate   time.Time `json:"payment_date,omitempty"`
-	SendReceipt   bool      `json:"send_receipt"`
-	Notes         string    `json:"notes,omitempty"`
+	InvoiceID     int           `json:"invoice_id" validate:"required"`
+	Amount        money.Decimal `json:"amount" validate:"required,gt=0"`
+	PaymentMethod string        `json:"payment_method" validate:"required"`
+	Reference     string        `json:"reference,omitempty"`
+	PaymentDate   time.Time     `json:"payment_date,omitempty"`
+	SendReceipt   bool          `json:"send_receipt"`
+	Notes         string        `json:"notes,omitempty"`
 }
 
 // PaymentMethodSummaryDTO representa resumo por método de pagamento
@@ -88,13 +105,13 @@ type BulkPaymentDTO struct {
 
 // PaymentScheduleDTO representa agendamento de pagamento
 type PaymentScheduleDTO struct {
-	InvoiceID     int       `json:"invoice_id" validate:"required"`
-	Amount        float64   `json:"amount" validate:"required,gt=0"`
-	ScheduleDate  time.Time `json:"schedule_date" validate:"required"`
-	PaymentMethod string    `json:"payment_method" validate:"required"`
-	IsRecurring   bool      `json:"is_recurring"`
-	Frequency     string    `json:"frequency,omitempty" validate:"omitempty,oneof=weekly monthly quarterly"`
-	Notes         string    `json:"notes,omitempty"`
+	InvoiceID     int           `json:"invoice_id" validate:"required"`
+	Amount        money.Decimal `json:"amount" validate:"required,gt=0"`
+	ScheduleDate  time.Time     `json:"schedule_date" validate:"required"`
+	PaymentMethod string        `json:"payment_method" validate:"required"`
+	IsRecurring   bool          `json:"is_recurring"`
+	Frequency     string        `json:"frequency,omitempty" validate:"omitempty,oneof=weekly monthly quarterly"`
+	Notes         string        `json:"notes,omitempty"`
 }
 
 // PaymentReceiptDTO representa dados para recibo de pagamento
@@ -109,22 +126,22 @@ type PaymentReceiptDTO struct {
 
 // RefundDTO representa dados para reembolso
 type RefundDTO struct {
-	PaymentID    int       `json:"payment_id" validate:"required"`
-	Amount       float64   `json:"amount" validate:"required,gt=0"`
-	RefundDate   time.Time `json:"refund_date" validate:"required"`
-	Reason       string    `json:"reason" validate:"required"`
-	RefundMethod string    `json:"refund_method,omitempty"`
-	Reference    string    `json:"reference,omitempty"`
-	Notes        string    `json:"notes,omitempty"`
+	PaymentID    int           `json:"payment_id" validate:"required"`
+	Amount       money.Decimal `json:"amount" validate:"required,gt=0"`
+	RefundDate   time.Time     `json:"refund_date" validate:"required"`
+	Reason       string        `json:"reason" validate:"required"`
+	RefundMethod string        `json:"refund_method,omitempty"`
+	Reference    string        `json:"reference,omitempty"`
+	Notes        string        `json:"notes,omitempty"`
 }
 
 // PaymentHistoryDTO representa histórico de pagamentos
 type PaymentHistoryDTO struct {
 	InvoiceID       int                  `json:"invoice_id"`
 	InvoiceNo       string               `json:"invoice_no"`
-	GrandTotal      float64              `json:"grand_total"`
-	AmountPaid      float64              `json:"amount_paid"`
-	BalanceDue      float64              `json:"balance_due"`
+	GrandTotal      money.Decimal        `json:"grand_total"`
+	AmountPaid      money.Decimal        `json:"amount_paid"`
+	BalanceDue      money.Decimal        `json:"balance_due"`
 	Payments        []PaymentResponseDTO `json:"payments"`
 	LastPaymentDate *time.Time           `json:"last_payment_date,omitempty"`
 	Status          string               `json:"status"`
@@ -0,0 +1,37 @@
+package dtos
+
+// PickRecordDTO representa as quantidades separadas dos itens da entrega
+type PickRecordDTO struct {
+	Items []PickLineDTO `json:"items" validate:"required,min=1,dive"`
+}
+
+// PickLineDTO representa a quantidade separada de um item da entrega
+type PickLineDTO struct {
+	DeliveryItemID int `json:"delivery_item_id" validate:"required"`
+	PickedQty      int `json:"picked_qty" validate:"gte=0"`
+}
+
+// PackDeliveryDTO representa os volumes em que a entrega separada foi embalada
+type PackDeliveryDTO struct {
+	Packages []PackageDTO `json:"packages" validate:"required,min=1,max=100,dive"`
+}
+
+// PackageDTO representa um volume: peso, dimensões e os itens embalados nele
+type PackageDTO struct {
+	WeightKg float64          `json:"weight_kg" validate:"required,gt=0"`
+	LengthCm float64          `json:"length_cm" validate:"gte=0"`
+	WidthCm  float64          `json:"width_cm" validate:"gte=0"`
+	HeightCm float64          `json:"height_cm" validate:"gte=0"`
+	Items    []PackageItemDTO `json:"items" validate:"required,min=1,dive"`
+}
+
+// PackageItemDTO representa a quantidade de um item da entrega colocada no volume
+type PackageItemDTO struct {
+	DeliveryItemID int `json:"delivery_item_id" validate:"required"`
+	Quantity       int `json:"quantity" validate:"required,gt=0"`
+}
+
+// DeliveryShipDTO representa os dados opcionais do envio da entrega
+type DeliveryShipDTO struct {
+	TrackingNumber string `json:"tracking_number,omitempty" validate:"max=100"`
+}
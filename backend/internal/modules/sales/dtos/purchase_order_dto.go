@@ -1,6 +1,9 @@
 package dtos
 
-import "time"
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
 
 // PurchaseOrderCreateDTO representa os dados para criar um purchase order
 type PurchaseOrderCreateDTO struct {
@@ -34,10 +37,10 @@ type PurchaseOrderResponseDTO struct {
 	CreatedAt       time.Time           `json:"created_at"`
 	UpdatedAt       time.Time           `json:"updated_at"`
 	ExpectedDate    time.Time           `json:"expected_date"`
-	SubTotal        float64             `json:"subtotal"`
-	TaxTotal        float64             `json:"tax_total"`
-	DiscountTotal   float64             `json:"discount_total"`
-	GrandTotal      float64             `json:"grand_total"`
+	SubTotal        money.Decimal       `json:"subtotal"`
+	TaxTotal        money.Decimal       `json:"tax_total"`
+	DiscountTotal   money.Decimal       `json:"discount_total"`
+	GrandTotal      money.Decimal       `json:"grand_total"`
 	Notes           string              `json:"notes,omitempty"`
 	PaymentTerms    string              `json:"payment_terms,omitempty"`
 	ShippingAddress string              `json:"shipping_address,omitempty"`
@@ -57,7 +60,7 @@ type PurchaseOrderListItemDTO struct {
 	Status        string            `json:"status"`
 	CreatedAt     time.Time         `json:"created_at"`
 	ExpectedDate  time.Time         `json:"expected_date"`
-	GrandTotal    float64           `json:"grand_total"`
+	GrandTotal    money.Decimal     `json:"grand_total"`
 	ItemCount     int               `json:"item_count"`
 	DeliveryCount int               `json:"delivery_count"`
 	IsOverdue     bool              `json:"is_overdue"`
@@ -66,40 +69,43 @@ type PurchaseOrderListItemDTO struct {
 
 // POItemCreateDTO representa os dados para criar um item de PO
 type POItemCreateDTO struct {
-	ProductID   int     `json:"product_id" validate:"required"`
-	ProductName string  `json:"product_name,omitempty"`
-	ProductCode string  `json:"product_code,omitempty"`
-	Description string  `json:"description,omitempty"`
-	Quantity    int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
-	Discount    float64 `json:"discount" validate:"min=0,max=100"`
-	Tax         float64 `json:"tax" validate:"min=0"`
+	ProductID   int           `json:"product_id" validate:"required"`
+	ProductName string        `json:"product_name,omitempty"`
+	ProductCode string        `json:"product_code,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Quantity    int           `json:"quantity" validate:"required,gt=0"`
+	Unit        string        `json:"unit,omitempty" validate:"omitempty,max=10"`
+	UnitPrice   money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	Discount    money.Decimal `json:"discount" validate:"min=0,max=100"`
+	Tax         money.Decimal `json:"tax" validate:"min=0"`
 }
 
 // POItemUpdateDTO representa os dados para atualizar um item
 type POItemUpdateDTO struct {
-	Quantity    *int     `json:"quantity,omitempty" validate:"omitempty,gt=0"`
-	UnitPrice   *float64 `json:"unit_price,omitempty" validate:"omitempty,gt=0"`
-	Discount    *float64 `json:"discount,omitempty" validate:"omitempty,min=0,max=100"`
-	Tax         *float64 `json:"tax,omitempty" validate:"omitempty,min=0"`
-	Description *string  `json:"description,omitempty"`
+	Quantity    *int           `json:"quantity,omitempty" validate:"omitempty,gt=0"`
+	UnitPrice   *money.Decimal `json:"unit_price,omitempty" validate:"omitempty,gt=0"`
+	Discount    *money.Decimal `json:"discount,omitempty" validate:"omitempty,min=0,max=100"`
+	Tax         *money.Decimal `json:"tax,omitempty" validate:"omitempty,min=0"`
+	Description *string        `json:"description,omitempty"`
 }
 
 // POItemResponseDTO representa os dados retornados de um item
 type POItemResponseDTO struct {
-	ID              int     `json:"id"`
-	PurchaseOrderID int     `json:"purchase_order_id"`
-	ProductID       int     `json:"product_id"`
-	ProductName     string  `json:"product_name"`
-	ProductCode     string  `json:"product_code"`
-	Description     string  `json:"description,omitempty"`
-	Quantity        int     `json:"quantity"`
-	UnitPrice       float64 `json:"unit_price"`
-	Discount        float64 `json:"discount"`
-	Tax             float64 `json:"tax"`
-	Total           float64 `json:"total"`
-	ReceivedQty     int     `json:"received_qty,omitempty"`
-	PendingQty      int     `json:"pending_qty,omitempty"`
+	ID              int           `json:"id"`
+	PurchaseOrderID int           `json:"purchase_order_id"`
+	ProductID       int           `json:"product_id"`
+	ProductName     string        `json:"product_name"`
+	ProductCode     string        `json:"product_code"`
+	Description     string        `json:"description,omitempty"`
+	Quantity        int           `json:"quantity"`
+	Unit            string        `json:"unit"`
+	UnitFactor      int           `json:"unit_factor"`
+	UnitPrice       money.Decimal `json:"unit_price"`
+	Discount        money.Decimal `json:"discount"`
+	Tax             money.Decimal `json:"tax"`
+	Total           money.Decimal `json:"total"`
+	ReceivedQty     int           `json:"received_qty,omitempty"`
+	PendingQty      int           `json:"pending_qty,omitempty"`
 }
 
 // POStatusUpdateDTO representa dados para atualizar status
@@ -122,10 +128,10 @@ type CreatePOFromSODTO struct {
 
 // ItemMappingDTO representa mapeamento de itens entre documentos
 type ItemMappingDTO struct {
-	FromItemID  int     `json:"from_item_id" validate:"required"`
-	ToProductID int     `json:"to_product_id" validate:"required"`
-	Quantity    int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
+	FromItemID  int           `json:"from_item_id" validate:"required"`
+	ToProductID int           `json:"to_product_id" validate:"required"`
+	Quantity    int           `json:"quantity" validate:"required,gt=0"`
+	UnitPrice   money.Decimal `json:"unit_price" validate:"required,gt=0"`
 }
 
 // PurchaseOrderSendDTO representa dados para enviar PO
@@ -177,13 +183,13 @@ type POPriceComparisonDTO struct {
 
 // SupplierPriceDTO representa preço de fornecedor
 type SupplierPriceDTO struct {
-	ContactID      int       `json:"contact_id"`
-	ContactName    string    `json:"contact_name"`
-	UnitPrice      float64   `json:"unit_price"`
-	MinQuantity    int       `json:"min_quantity,omitempty"`
-	LeadTimeDays   int       `json:"lead_time_days,omitempty"`
-	LastUpdateDate time.Time `json:"last_update_date"`
-	Notes          string    `json:"notes,omitempty"`
+	ContactID      int           `json:"contact_id"`
+	ContactName    string        `json:"contact_name"`
+	UnitPrice      money.Decimal `json:"unit_price"`
+	MinQuantity    int           `json:"min_quantity,omitempty"`
+	LeadTimeDays   int           `json:"lead_time_days,omitempty"`
+	LastUpdateDate time.Time     `json:"last_update_date"`
+	Notes          string        `json:"notes,omitempty"`
 }
 
 // POApprovalDTO representa dados de aprovação de PO
@@ -206,10 +212,10 @@ type BulkPOCreateDTO struct {
 
 // BulkPOItemDTO representa item para PO em massa
 type BulkPOItemDTO struct {
-	ProductID  int     `json:"product_id" validate:"required"`
-	Quantity   int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice  float64 `json:"unit_price" validate:"required,gt=0"`
-	SupplierID int     `json:"supplier_id,omitempty"`
+	ProductID  int           `json:"product_id" validate:"required"`
+	Quantity   int           `json:"quantity" validate:"required,gt=0"`
+	UnitPrice  money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	SupplierID int           `json:"supplier_id,omitempty"`
 }
 
 // PurchaseOrderCommonDTO representa dados comuns para PO
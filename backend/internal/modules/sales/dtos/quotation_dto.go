@@ -1,21 +1,28 @@
 package dtos
 
-import "time"
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
 
 // QuotationCreateDTO representa os dados para criar uma quotation
 type QuotationCreateDTO struct {
-	ContactID  int                      `json:"contact_id" validate:"required"`
-	ExpiryDate time.Time                `json:"expiry_date" validate:"required"`
-	Notes      string                   `json:"notes,omitempty"`
-	Terms      string                   `json:"terms,omitempty"`
-	Items      []QuotationItemCreateDTO `json:"items" validate:"required,min=1,dive"`
+	ContactID     int                      `json:"contact_id" validate:"required"`
+	SalespersonID *int                     `json:"salesperson_id,omitempty"`
+	CampaignID    *int                     `json:"campaign_id,omitempty"`
+	ExpiryDate    time.Time                `json:"expiry_date" validate:"required"`
+	Notes         string                   `json:"notes,omitempty"`
+	Terms         string                   `json:"terms,omitempty"`
+	Items         []QuotationItemCreateDTO `json:"items" validate:"required,min=1,dive"`
 }
 
 // QuotationUpdateDTO representa os dados para atualizar uma quotation
 type QuotationUpdateDTO struct {
-	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
-	Notes      *string    `json:"notes,omitempty"`
-	Terms      *string    `json:"terms,omitempty"`
+	SalespersonID *int       `json:"salesperson_id,omitempty"`
+	CampaignID    *int       `json:"campaign_id,omitempty"`
+	ExpiryDate    *time.Time `json:"expiry_date,omitempty"`
+	Notes         *string    `json:"notes,omitempty"`
+	Terms         *string    `json:"terms,omitempty"`
 }
 
 // QuotationResponseDTO representa os dados retornados de uma quotation
@@ -23,17 +30,20 @@ type QuotationResponseDTO struct {
 	ID            int                        `json:"id"`
 	QuotationNo   string                     `json:"quotation_no"`
 	ContactID     int                        `json:"contact_id"`
+	SalespersonID *int                       `json:"salesperson_id,omitempty"`
+	CampaignID    *int                       `json:"campaign_id,omitempty"`
 	Contact       *ContactBasicInfo          `json:"contact,omitempty"`
 	Status        string                     `json:"status"`
 	CreatedAt     time.Time                  `json:"created_at"`
 	UpdatedAt     time.Time                  `json:"updated_at"`
 	ExpiryDate    time.Time                  `json:"expiry_date"`
-	SubTotal      float64                    `json:"subtotal"`
-	TaxTotal      float64                    `json:"tax_total"`
-	DiscountTotal float64                    `json:"discount_total"`
-	GrandTotal    float64                    `json:"grand_total"`
+	SubTotal      money.Decimal              `json:"subtotal"`
+	TaxTotal      money.Decimal              `json:"tax_total"`
+	DiscountTotal money.Decimal              `json:"discount_total"`
+	GrandTotal    money.Decimal              `json:"grand_total"`
 	Notes         string                     `json:"notes,omitempty"`
 	Terms         string                     `json:"terms,omitempty"`
+	Shipping      *ShippingOptionDTO         `json:"shipping,omitempty"`
 	Items         []QuotationItemResponseDTO `json:"items,omitempty"`
 	IsExpired     bool                       `json:"is_expired"`
 	DaysToExpiry  int                        `json:"days_to_expiry,omitempty"`
@@ -48,45 +58,48 @@ type QuotationListItemDTO struct {
 	Status       string            `json:"status"`
 	CreatedAt    time.Time         `json:"created_at"`
 	ExpiryDate   time.Time         `json:"expiry_date"`
-	GrandTotal   float64           `json:"grand_total"`
+	GrandTotal   money.Decimal     `json:"grand_total"`
 	IsExpired    bool              `json:"is_expired"`
 	DaysToExpiry int               `json:"days_to_expiry,omitempty"`
 }
 
 // QuotationItemCreateDTO representa os dados para criar um item de quotation
 type QuotationItemCreateDTO struct {
-	ProductID   int     `json:"product_id" validate:"required"`
-	ProductName string  `json:"product_name,omitempty"`
-	ProductCode string  `json:"product_code,omitempty"`
-	Description string  `json:"description,omitempty"`
-	Quantity    int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
-	Discount    float64 `json:"discount" validate:"min=0,max=100"`
-	Tax         float64 `json:"tax" validate:"min=0"`
+	ProductID   int           `json:"product_id" validate:"required"`
+	ProductName string        `json:"product_name,omitempty"`
+	ProductCode string        `json:"product_code,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Quantity    int           `json:"quantity" validate:"required,gt=0"`
+	Unit        string        `json:"unit,omitempty" validate:"omitempty,max=10"`
+	UnitPrice   money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	Discount    money.Decimal `json:"discount" validate:"min=0,max=100"`
+	Tax         money.Decimal `json:"tax" validate:"min=0"`
 }
 
 // QuotationItemUpdateDTO representa os dados para atualizar um item
 type QuotationItemUpdateDTO struct {
-	Quantity    *int     `json:"quantity,omitempty" validate:"omitempty,gt=0"`
-	UnitPrice   *float64 `json:"unit_price,omitempty" validate:"omitempty,gt=0"`
-	Discount    *float64 `json:"discount,omitempty" validate:"omitempty,min=0,max=100"`
-	Tax         *float64 `json:"tax,omitempty" validate:"omitempty,min=0"`
-	Description *string  `json:"description,omitempty"`
+	Quantity    *int           `json:"quantity,omitempty" validate:"omitempty,gt=0"`
+	UnitPrice   *money.Decimal `json:"unit_price,omitempty" validate:"omitempty,gt=0"`
+	Discount    *money.Decimal `json:"discount,omitempty" validate:"omitempty,min=0,max=100"`
+	Tax         *money.Decimal `json:"tax,omitempty" validate:"omitempty,min=0"`
+	Description *string        `json:"description,omitempty"`
 }
 
 // QuotationItemResponseDTO representa os dados retornados de um item
 type QuotationItemResponseDTO struct {
-	ID          int     `json:"id"`
-	QuotationID int     `json:"quotation_id"`
-	ProductID   int     `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	ProductCode string  `json:"product_code"`
-	Description string  `json:"description,omitempty"`
-	Quantity    int     `json:"quantity"`
-	UnitPrice   float64 `json:"unit_price"`
-	Discount    float64 `json:"discount"`
-	Tax         float64 `json:"tax"`
-	Total       float64 `json:"total"`
+	ID          int           `json:"id"`
+	QuotationID int           `json:"quotation_id"`
+	ProductID   int           `json:"product_id"`
+	ProductName string        `json:"product_name"`
+	ProductCode string        `json:"product_code"`
+	Description string        `json:"description,omitempty"`
+	Quantity    int           `json:"quantity"`
+	Unit        string        `json:"unit"`
+	UnitFactor  int           `json:"unit_factor"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	Discount    money.Decimal `json:"discount"`
+	Tax         money.Decimal `json:"tax"`
+	Total       money.Decimal `json:"total"`
 }
 
 // QuotationStatusUpdateDTO representa dados para atualizar status
@@ -0,0 +1,19 @@
+package dtos
+
+// SaleCreateDTO representa os dados para registrar uma venda simples
+type SaleCreateDTO struct {
+	Product          string  `json:"product" validate:"required,max=100"`
+	Quantity         int     `json:"quantity" validate:"required,gt=0"`
+	Price            float64 `json:"price" validate:"required,gt=0"`
+	Customer         string  `json:"customer" validate:"required,email,max=100"`
+	CustomerDocument string  `json:"customer_document,omitempty" validate:"omitempty,cpf_cnpj"`
+}
+
+// SaleUpdateDTO representa os dados para atualizar uma venda simples (substituição completa)
+type SaleUpdateDTO struct {
+	Product          string  `json:"product" validate:"required,max=100"`
+	Quantity         int     `json:"quantity" validate:"required,gt=0"`
+	Price            float64 `json:"price" validate:"required,gt=0"`
+	Customer         string  `json:"customer" validate:"required,email,max=100"`
+	CustomerDocument string  `json:"customer_document,omitempty" validate:"omitempty,cpf_cnpj"`
+}
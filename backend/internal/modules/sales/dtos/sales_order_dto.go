@@ -1,11 +1,15 @@
 package dtos
 
-import "time"
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
 
 // SalesOrderCreateDTO representa os dados para criar um sales order
 type SalesOrderCreateDTO struct {
 	QuotationID     int               `json:"quotation_id,omitempty"`
 	ContactID       int               `json:"contact_id" validate:"required"`
+	SalespersonID   *int              `json:"salesperson_id,omitempty"`
 	ExpectedDate    time.Time         `json:"expected_date" validate:"required"`
 	PaymentTerms    string            `json:"payment_terms,omitempty"`
 	ShippingAddress string            `json:"shipping_address,omitempty"`
@@ -15,6 +19,7 @@ type SalesOrderCreateDTO struct {
 
 // SalesOrderUpdateDTO representa os dados para atualizar um sales order
 type SalesOrderUpdateDTO struct {
+	SalespersonID   *int       `json:"salesperson_id,omitempty"`
 	ExpectedDate    *time.Time `json:"expected_date,omitempty"`
 	PaymentTerms    *string    `json:"payment_terms,omitempty"`
 	ShippingAddress *string    `json:"shipping_address,omitempty"`
@@ -23,27 +28,32 @@ type SalesOrderUpdateDTO struct {
 
 // SalesOrderResponseDTO representa os dados retornados de um sales order
 type SalesOrderResponseDTO struct {
-	ID              int                 `json:"id"`
-	SONo            string              `json:"so_no"`
-	QuotationID     int                 `json:"quotation_id,omitempty"`
-	ContactID       int                 `json:"contact_id"`
-	Contact         *ContactBasicInfo   `json:"contact,omitempty"`
-	Status          string              `json:"status"`
-	CreatedAt       time.Time           `json:"created_at"`
-	UpdatedAt       time.Time           `json:"updated_at"`
-	ExpectedDate    time.Time           `json:"expected_date"`
-	SubTotal        float64             `json:"subtotal"`
-	TaxTotal        float64             `json:"tax_total"`
-	DiscountTotal   float64             `json:"discount_total"`
-	GrandTotal      float64             `json:"grand_total"`
-	Notes           string              `json:"notes,omitempty"`
-	PaymentTerms    string              `json:"payment_terms,omitempty"`
-	ShippingAddress string              `json:"shipping_address,omitempty"`
-	Items           []SOItemResponseDTO `json:"items,omitempty"`
-	InvoiceCount    int                 `json:"invoice_count"`
-	POCount         int                 `json:"po_count"`
-	DeliveryCount   int                 `json:"delivery_count"`
-	FulfillmentRate float64             `json:"fulfillment_rate"`
+	ID                int                 `json:"id"`
+	SONo              string              `json:"so_no"`
+	QuotationID       int                 `json:"quotation_id,omitempty"`
+	ContactID         int                 `json:"contact_id"`
+	SalespersonID     *int                `json:"salesperson_id,omitempty"`
+	Contact           *ContactBasicInfo   `json:"contact,omitempty"`
+	Status            string              `json:"status"`
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+	ExpectedDate      time.Time           `json:"expected_date"`
+	SubTotal          money.Decimal       `json:"subtotal"`
+	TaxTotal          money.Decimal       `json:"tax_total"`
+	DiscountTotal     money.Decimal       `json:"discount_total"`
+	GrandTotal        money.Decimal       `json:"grand_total"`
+	Notes             string              `json:"notes,omitempty"`
+	PaymentTerms      string              `json:"payment_terms,omitempty"`
+	ShippingAddress   string              `json:"shipping_address,omitempty"`
+	ShippingAddressID *int                `json:"shipping_address_id,omitempty"`
+	BillingAddressID  *int                `json:"billing_address_id,omitempty"`
+	BillingAddress    string              `json:"billing_address,omitempty"`
+	Shipping          *ShippingOptionDTO  `json:"shipping,omitempty"`
+	Items             []SOItemResponseDTO `json:"items,omitempty"`
+	InvoiceCount      int                 `json:"invoice_count"`
+	POCount           int                 `json:"po_count"`
+	DeliveryCount     int                 `json:"delivery_count"`
+	FulfillmentRate   float64             `json:"fulfillment_rate"`
 }
 
 // SalesOrderListItemDTO representa uma versão resumida para listagens
@@ -55,7 +65,7 @@ type SalesOrderListItemDTO struct {
 	Status          string            `json:"status"`
 	CreatedAt       time.Time         `json:"created_at"`
 	ExpectedDate    time.Time         `json:"expected_date"`
-	GrandTotal      float64           `json:"grand_total"`
+	GrandTotal      money.Decimal     `json:"grand_total"`
 	ItemCount       int               `json:"item_count"`
 	InvoiceCount    int               `json:"invoice_count"`
 	DeliveryCount   int               `json:"delivery_count"`
@@ -64,41 +74,44 @@ type SalesOrderListItemDTO struct {
 
 // SOItemCreateDTO representa os dados para criar um item de SO
 type SOItemCreateDTO struct {
-	ProductID   int     `json:"product_id" validate:"required"`
-	ProductName string  `json:"product_name,omitempty"`
-	ProductCode string  `json:"product_code,omitempty"`
-	Description string  `json:"description,omitempty"`
-	Quantity    int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
-	Discount    float64 `json:"discount" validate:"min=0,max=100"`
-	Tax         float64 `json:"tax" validate:"min=0"`
+	ProductID   int           `json:"product_id" validate:"required"`
+	ProductName string        `json:"product_name,omitempty"`
+	ProductCode string        `json:"product_code,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Quantity    int           `json:"quantity" validate:"required,gt=0"`
+	Unit        string        `json:"unit,omitempty" validate:"omitempty,max=10"`
+	UnitPrice   money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	Discount    money.Decimal `json:"discount" validate:"min=0,max=100"`
+	Tax         money.Decimal `json:"tax" validate:"min=0"`
 }
 
 // SOItemUpdateDTO representa os dados para atualizar um item
 type SOItemUpdateDTO struct {
-	Quantity    *int     `json:"quantity,omitempty" validate:"omitempty,gt=0"`
-	UnitPrice   *float64 `json:"unit_price,omitempty" validate:"omitempty,gt=0"`
-	Discount    *float64 `json:"discount,omitempty" validate:"omitempty,min=0,max=100"`
-	Tax         *float64 `json:"tax,omitempty" validate:"omitempty,min=0"`
-	Description *string  `json:"description,omitempty"`
+	Quantity    *int           `json:"quantity,omitempty" validate:"omitempty,gt=0"`
+	UnitPrice   *money.Decimal `json:"unit_price,omitempty" validate:"omitempty,gt=0"`
+	Discount    *money.Decimal `json:"discount,omitempty" validate:"omitempty,min=0,max=100"`
+	Tax         *money.Decimal `json:"tax,omitempty" validate:"omitempty,min=0"`
+	Description *string        `json:"description,omitempty"`
 }
 
 // SOItemResponseDTO representa os dados retornados de um item
 type SOItemResponseDTO struct {
-	ID           int     `json:"id"`
-	SalesOrderID int     `json:"sales_order_id"`
-	ProductID    int     `json:"product_id"`
-	ProductName  string  `json:"product_name"`
-	ProductCode  string  `json:"product_code"`
-	Description  string  `json:"description,omitempty"`
-	Quantity     int     `json:"quantity"`
-	UnitPrice    float64 `json:"unit_price"`
-	Discount     float64 `json:"discount"`
-	Tax          float64 `json:"tax"`
-	Total        float64 `json:"total"`
-	DeliveredQty int     `json:"delivered_qty"`
-	InvoicedQty  int     `json:"invoiced_qty"`
-	PendingQty   int     `json:"pending_qty"`
+	ID           int           `json:"id"`
+	SalesOrderID int           `json:"sales_order_id"`
+	ProductID    int           `json:"product_id"`
+	ProductName  string        `json:"product_name"`
+	ProductCode  string        `json:"product_code"`
+	Description  string        `json:"description,omitempty"`
+	Quantity     int           `json:"quantity"`
+	Unit         string        `json:"unit"`
+	UnitFactor   int           `json:"unit_factor"`
+	UnitPrice    money.Decimal `json:"unit_price"`
+	Discount     money.Decimal `json:"discount"`
+	Tax          money.Decimal `json:"tax"`
+	Total        money.Decimal `json:"total"`
+	DeliveredQty int           `json:"delivered_qty"`
+	InvoicedQty  int           `json:"invoiced_qty"`
+	PendingQty   int           `json:"pending_qty"`
 }
 
 // SOStatusUpdateDTO representa dados para atualizar status
@@ -1,19 +1,22 @@
 package dtos
 
-import "time"
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
 
 // SalesProcessCreateDTO representa os dados para criar um sales process
 type SalesProcessCreateDTO struct {
-	ContactID    int     `json:"contact_id" validate:"required"`
-	Notes        string  `json:"notes,omitempty"`
-	InitialValue float64 `json:"initial_value,omitempty"`
+	ContactID    int           `json:"contact_id" validate:"required"`
+	Notes        string        `json:"notes,omitempty"`
+	InitialValue money.Decimal `json:"initial_value,omitempty"`
 }
 
 // SalesProcessUpdateDTO representa os dados para atualizar um sales process
 type SalesProcessUpdateDTO struct {
-	Notes      *string  `json:"notes,omitempty"`
-	TotalValue *float64 `json:"total_value,omitempty"`
-	Profit     *float64 `json:"profit,omitempty"`
+	Notes      *string        `json:"notes,omitempty"`
+	TotalValue *money.Decimal `json:"total_value,omitempty"`
+	Profit     *money.Decimal `json:"profit,omitempty"`
 }
 
 // SalesProcessResponseDTO representa os dados retornados de um sales process
@@ -24,9 +27,9 @@ type SalesProcessResponseDTO struct {
 	Status             string             `json:"status"`
 	CreatedAt          time.Time          `json:"created_at"`
 	UpdatedAt          time.Time          `json:"updated_at"`
-	TotalValue         float64            `json:"total_value"`
-	TotalCost          float64            `json:"total_cost"`
-	Profit             float64            `json:"profit"`
+	TotalValue         money.Decimal      `json:"total_value"`
+	TotalCost          money.Decimal      `json:"total_cost"`
+	Profit             money.Decimal      `json:"profit"`
 	ProfitMargin       float64            `json:"profit_margin"`
 	Notes              string             `json:"notes,omitempty"`
 	CurrentStage       string             `json:"current_stage"`
@@ -44,8 +47,8 @@ type SalesProcessListItemDTO struct {
 	Contact        *ContactBasicInfo `json:"contact,omitempty"`
 	Status         string            `json:"status"`
 	CreatedAt      time.Time         `json:"created_at"`
-	TotalValue     float64           `json:"total_value"`
-	Profit         float64           `json:"profit"`
+	TotalValue     money.Decimal     `json:"total_value"`
+	Profit         money.Decimal     `json:"profit"`
 	ProfitMargin   float64           `json:"profit_margin"`
 	CurrentStage   string            `json:"current_stage"`
 	CompletionRate float64           `json:"completion_rate"`
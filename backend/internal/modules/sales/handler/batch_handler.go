@@ -0,0 +1,92 @@
+package handler
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+	"ERP-ONSMART/backend/internal/utils/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Cria até 500 faturas em uma transação, com o resultado de cada item
+// Com atomic=true, qualquer item rejeitado desfaz o lote inteiro.
+// @Success 200 "Resultado por item (index, status ok/error/not_applied, id, number, error)"
+// @Security BearerAuth
+func CreateInvoicesBatchHandler(c *gin.Context) {
+	var req dtos.InvoiceBatchDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	result, err := service.CreateInvoicesBatch(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao processar lote de faturas")
+		return
+	}
+	c.JSON(http.StatusOK, localizeBatchErrors(c, result))
+}
+
+// Registra até 500 pagamentos em uma transação, atualizando o saldo das faturas
+// Com atomic=true, qualquer item rejeitado desfaz o lote inteiro.
+// @Success 200 "Resultado por item (index, status ok/error/not_applied, id, error)"
+func CreatePaymentsBatchHandler(c *gin.Context) {
+	var req dtos.PaymentBatchDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	result, err := service.CreatePaymentsBatch(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao processar lote de pagamentos")
+		return
+	}
+	c.JSON(http.StatusOK, localizeBatchErrors(c, result))
+}
+
+// Altera o status de até 500 entregas em uma transação
+// Com atomic=true, qualquer item rejeitado desfaz o lote inteiro.
+// @Success 200 "Resultado por item (index, status ok/error/not_applied, id, number, error)"
+func UpdateDeliveryStatusesBatchHandler(c *gin.Context) {
+	var req dtos.DeliveryStatusBatchDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	result, err := service.UpdateDeliveryStatusesBatch(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao processar lote de status de entregas")
+		return
+	}
+	c.JSON(http.StatusOK, localizeBatchErrors(c, result))
+}
+
+// localizeBatchErrors lista os campos reprovados de cada item, com mensagens no idioma do
+// Accept-Language, como o middleware.ErrorHandler faz para requisições individuais
+func localizeBatchErrors(c *gin.Context, result *models.BatchResult) *models.BatchResult {
+	lang := validation.Language(c.GetHeader("Accept-Language"))
+	for i, item := range result.Items {
+		var validationErrors validator.ValidationErrors
+		if item.Error == nil || !stderrors.As(item.Error, &validationErrors) {
+			continue
+		}
+		apiErr := *item.Error
+		apiErr.Details = ""
+		for _, fe := range validationErrors {
+			apiErr.FieldErrors = append(apiErr.FieldErrors, errors.FieldError{
+				Field:   validation.FieldPath(fe),
+				Message: validation.Message(fe, lang),
+			})
+		}
+		result.Items[i].Error = &apiErr
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postBatch(t *testing.T, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.POST("/payments/batch", CreatePaymentsBatchHandler)
+
+	req, _ := http.NewRequest(http.MethodPost, "/payments/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "pt-BR")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestPaymentsBatchRejectsEmptyBatch(t *testing.T) {
+	resp := postBatch(t, `{"items": []}`)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"batch_empty"`)
+}
+
+// Um lote atômico com item inválido é rejeitado sem acessar o banco, com os campos de cada item
+func TestPaymentsBatchAtomicValidationFailure(t *testing.T) {
+	resp := postBatch(t, `{"atomic": true, "items": [
+		{"invoice_id": 1, "amount": 10, "payment_date": "2026-05-10T00:00:00Z", "payment_method": "pix"},
+		{"invoice_id": 2, "amount": -5, "payment_date": "2026-05-10T00:00:00Z"}
+	]}`)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var result models.BatchResult
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.True(t, result.RolledBack)
+	assert.Equal(t, 1, result.Failed)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, models.BatchItemNotApplied, result.Items[0].Status)
+
+	failed := result.Items[1]
+	assert.Equal(t, models.BatchItemError, failed.Status)
+	require.NotNil(t, failed.Error)
+	assert.Equal(t, "invalid_request", failed.Error.Code)
+	fields := make([]string, 0, len(failed.Error.FieldErrors))
+	for _, fe := range failed.Error.FieldErrors {
+		fields = append(fields, fe.Field)
+	}
+	assert.ElementsMatch(t, []string{"amount", "payment_method"}, fields)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"ERP-ONSMART/backend/internal/errors"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	customFieldsService "ERP-ONSMART/backend/internal/modules/customfields/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Substitui os campos personalizados do pedido de venda, validados contra as definições de sales_order
+// @Security BearerAuth
+func SetSalesOrderCustomFieldsHandler(c *gin.Context) {
+	id, ok := parseDocumentID(c)
+	if !ok {
+		return
+	}
+
+	var values customfields.Values
+	if err := c.ShouldBindJSON(&values); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	values, err := customFieldsService.SetValues(c.Request.Context(), customfields.EntitySalesOrder, id, values)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar campos personalizados do pedido de venda")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"custom_fields": values})
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Move a cotação para a lixeira (soft delete)
+func DeleteQuotationHandler(c *gin.Context) {
+	id, ok := parseDocumentID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.ArchiveQuotation(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao excluir cotação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "cotação movida para a lixeira"})
+}
+
+// Move o pedido de venda para a lixeira (soft delete)
+// @Security BearerAuth
+func DeleteSalesOrderHandler(c *gin.Context) {
+	id, ok := parseDocumentID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.ArchiveSalesOrder(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao excluir pedido de venda")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "pedido de venda movido para a lixeira"})
+}
+
+// Move a fatura para a lixeira (soft delete)
+// @Security BearerAuth
+func DeleteInvoiceHandler(c *gin.Context) {
+	id, ok := parseDocumentID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.ArchiveInvoice(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao excluir fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "fatura movida para a lixeira"})
+}
+
+// Move a entrega para a lixeira (soft delete)
+func DeleteDeliveryHandler(c *gin.Context) {
+	id, ok := parseDocumentID(c)
+	if !ok {
+		return
+	}
+
+	if err := service.ArchiveDelivery(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao excluir entrega")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "entrega movida para a lixeira"})
+}
+
+func parseDocumentID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	customFieldsService "ERP-ONSMART/backend/internal/modules/customfields/service"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/mapper"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Converte a cotação em pedido de venda, copiando os itens e recalculando os totais
+func ConvertQuotationToSalesOrderHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.QuotationConversionDTO
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	if req.CustomFields, err = customFieldsService.ValidateValues(c.Request.Context(), customfields.EntitySalesOrder, req.CustomFields); err != nil {
+		c.Error(err)
+		return
+	}
+
+	salesOrder, err := service.ConvertQuotationToSalesOrder(c.Request.Context(), id, mapper.ToSalesOrderConversion(req))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao converter cotação em pedido de venda")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"sales_order": salesOrder})
+}
+
+// Gera a fatura com o saldo ainda não faturado do pedido de venda
+// @Security BearerAuth
+func GenerateInvoiceHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.InvoiceGenerationDTO
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	invoice, err := service.GenerateInvoice(c.Request.Context(), id, mapper.ToInvoiceGeneration(req))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar fatura do pedido de venda")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invoice": invoice})
+}
+
+// Gera a fatura de adiantamento do pedido confirmado: percent do total do pedido ou amount fixo,
+// vencendo na emissão sem due_date. O adiantamento é abatido das faturas seguintes do pedido.
+// @Param id path int true "ID do pedido de venda"
+// @Security BearerAuth
+func GenerateAdvanceInvoiceHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.AdvanceInvoiceGenerationDTO
+	if !bindAndValidate(c, &req) {
+		return
+	}
+
+	invoice, err := service.GenerateAdvanceInvoice(c.Request.Context(), id, mapper.ToAdvanceInvoiceGeneration(req))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar fatura de adiantamento do pedido de venda")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invoice": invoice})
+}
+
+// Gera a entrega com o saldo ainda não enviado do pedido de venda
+// @Security BearerAuth
+func GenerateDeliveryHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.DeliveryGenerationDTO
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	delivery, err := service.GenerateDelivery(c.Request.Context(), id, mapper.ToDeliveryGeneration(req))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar entrega do pedido de venda")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"delivery": delivery})
+}
+
+// Libera o pedido de venda retido pelo limite de crédito do cliente, registrando quem aprovou
+// @Security BearerAuth
+func ApproveSalesOrderCreditHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	salesOrder, err := service.ApproveCreditHold(c.Request.Context(), id, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao liberar crédito do pedido de venda")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sales_order": salesOrder})
+}
+
+// bindOptionalJSON lê e valida o corpo da requisição quando informado; sem corpo, mantém os valores padrão
+func bindOptionalJSON(c *gin.Context, obj interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	return bindAndValidate(c, obj)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	customFieldsService "ERP-ONSMART/backend/internal/modules/customfields/service"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+	"ERP-ONSMART/backend/internal/utils/listquery"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listQuery reúne os parâmetros comuns das listagens de documentos
+type listQuery struct {
+	status    []string
+	contactID int
+	search    string
+	sort      []listquery.SortField
+	fields    []string
+}
+
+// Lista os pedidos de venda com ordenação e seleção de campos
+// @Param status query string false "status separados por vírgula"
+// @Param contact_id query int false "ID do contato"
+// @Param search query string false "busca por número, observações ou nome do contato"
+// @Param sort query string false "ordenação, ex.: -grand_total,expected_date (so_no, status, created_at, updated_at, expected_date, subtotal, grand_total)"
+// @Param fields query string false "campos retornados, ex.: id,so_no,status,grand_total,contact"
+// @Param cf.<chave> query string false "filtra pelo valor de um campo personalizado, ex.: cf.canal=revenda"
+// @Param page query int false "página"
+// @Param page_size query int false "itens por página (máx. 100)"
+// @Security BearerAuth
+func ListSalesOrdersHandler(c *gin.Context) {
+	query, ok := parseListQuery(c, repository.SalesOrderListSpec)
+	if !ok {
+		return
+	}
+	customFields, err := customFieldsService.ParseFilters(c.Request.Context(), customfields.EntitySalesOrder, c.Request.URL.Query())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.ListSalesOrders(c.Request.Context(), repository.SalesOrderFilter{
+		Status:       query.status,
+		ContactID:    query.contactID,
+		SearchQuery:  query.search,
+		Sort:         query.sort,
+		Fields:       query.fields,
+		CustomFields: customFields,
+	}, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar pedidos de venda")
+		return
+	}
+
+	respondList(c, result, query.fields)
+}
+
+// Lista as faturas com ordenação e seleção de campos
+// @Param status query string false "status separados por vírgula"
+// @Param contact_id query int false "ID do contato"
+// @Param search query string false "busca por número, observações ou nome do contato"
+// @Param sort query string false "ordenação, ex.: due_date,-grand_total (invoice_no, status, created_at, updated_at, issue_date, due_date, grand_total, amount_paid)"
+// @Param fields query string false "campos retornados, ex.: id,invoice_no,due_date,grand_total,contact"
+// @Param page query int false "página"
+// @Param page_size query int false "itens por página (máx. 100)"
+// @Security BearerAuth
+func ListInvoicesHandler(c *gin.Context) {
+	query, ok := parseListQuery(c, repository.InvoiceListSpec)
+	if !ok {
+		return
+	}
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.ListInvoices(c.Request.Context(), repository.InvoiceFilter{
+		Status:      query.status,
+		ContactID:   query.contactID,
+		SearchQuery: query.search,
+		Sort:        query.sort,
+		Fields:      query.fields,
+	}, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar faturas")
+		return
+	}
+
+	respondList(c, result, query.fields)
+}
+
+// Lista as entregas com ordenação e seleção de campos
+// @Param status query string false "status separados por vírgula"
+// @Param contact_id query int false "ID do contato do pedido de compra ou de venda"
+// @Param search query string false "busca por número, rastreio ou observações"
+// @Param sla_status query string false "status do SLA de envio (on_track, met ou breached)"
+// @Param sort query string false "ordenação, ex.: -delivery_date (delivery_no, status, created_at, updated_at, delivery_date, received_date, carrier, sla_due_at)"
+// @Param fields query string false "campos retornados, ex.: id,delivery_no,status,tracking_number"
+// @Param page query int false "página"
+// @Param page_size query int false "itens por página (máx. 100)"
+func ListDeliveriesHandler(c *gin.Context) {
+	query, ok := parseListQuery(c, repository.DeliveryListSpec)
+	if !ok {
+		return
+	}
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.ListDeliveries(c.Request.Context(), repository.DeliveryFilter{
+		Status:      query.status,
+		ContactID:   query.contactID,
+		SLAStatus:   c.Query("sla_status"),
+		SearchQuery: query.search,
+		Sort:        query.sort,
+		Fields:      query.fields,
+	}, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar entregas")
+		return
+	}
+
+	respondList(c, result, query.fields)
+}
+
+// parseListQuery valida ?sort= e ?fields= contra os campos permitidos da entidade
+func parseListQuery(c *gin.Context, spec listquery.Spec) (listQuery, bool) {
+	var query listQuery
+	var err error
+
+	if query.sort, err = spec.ParseSort(c.Query("sort")); err != nil {
+		c.Error(err)
+		return query, false
+	}
+	if query.fields, err = spec.ParseFields(c.Query("fields")); err != nil {
+		c.Error(err)
+		return query, false
+	}
+
+	if raw := c.Query("contact_id"); raw != "" {
+		if query.contactID, err = strconv.Atoi(raw); err != nil || query.contactID <= 0 {
+			c.Error(errors.InvalidParam("contact_id inválido"))
+			return query, false
+		}
+	}
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			query.status = append(query.status, status)
+		}
+	}
+	query.search = strings.TrimSpace(c.Query("search"))
+	return query, true
+}
+
+// respondList devolve a página; com ?fields=, cada item traz só os campos pedidos
+func respondList(c *gin.Context, result *pagination.PaginatedResult, fields []string) {
+	if len(fields) > 0 {
+		items, err := listquery.Project(result.Items, fields)
+		if err != nil {
+			c.Error(err).SetMeta("erro ao selecionar campos da listagem")
+			return
+		}
+		result.Items = items
+	}
+	c.JSON(http.StatusOK, result)
+}
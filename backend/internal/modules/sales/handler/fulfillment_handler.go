@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Retorna o atendimento do pedido de venda: entregas parciais, saldo e backorder por linha
+// @Security BearerAuth
+func GetSalesOrderFulfillmentHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	fulfillment, err := service.GetSalesOrderFulfillment(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar atendimento do pedido")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fulfillment": fulfillment})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/mapper"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as parcelas da fatura com o valor pago e o status de cada uma
+// @Security BearerAuth
+func GetInvoiceInstallmentsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	installments, err := service.GetInvoiceInstallments(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar parcelas da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"installments": installments})
+}
+
+// Divide a fatura em parcelas, substituindo as atuais, e gera o boleto e o Pix de cada uma
+// @Security BearerAuth
+func UpdateInvoiceInstallmentsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.InvoiceInstallmentPlanDTO
+	if !bindAndValidate(c, &req) {
+		return
+	}
+
+	installments, err := service.ReplaceInvoiceInstallments(c.Request.Context(), id, mapper.FromInvoiceInstallmentPlanDTO(&req))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao parcelar fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"installments": installments})
+}
+
+// Gera de novo o boleto e o Pix da parcela com o saldo em aberto; depois do vencimento, com
+// vencimento hoje e a multa e os juros por atraso (detalhados em charge)
+// @Security BearerAuth
+func RegenerateInstallmentChargeHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	installment, charge, err := service.RegenerateInstallmentCharge(c.Request.Context(), id, number)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gerar cobrança da parcela")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"installment": installment, "charge": charge})
+}
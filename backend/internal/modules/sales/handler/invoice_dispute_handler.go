@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as contestações das faturas, das mais recentes
+// @Security BearerAuth
+// @Param status query string false "open ou resolved"
+// @Param invoice_id query int false "ID da fatura"
+func ListInvoiceDisputesHandler(c *gin.Context) {
+	filter := models.DisputeFilter{Status: c.Query("status")}
+	if value := c.Query("invoice_id"); value != "" {
+		invoiceID, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("invoice_id inválido"))
+			return
+		}
+		filter.InvoiceID = invoiceID
+	}
+
+	disputes, err := service.ListInvoiceDisputes(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar contestações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"disputes": disputes})
+}
+
+// Lista as contestações da fatura e das parcelas, das mais recentes
+// @Security BearerAuth
+// @Param id path int true "ID da fatura"
+func GetInvoiceDisputesHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	disputes, err := service.ListInvoiceDisputes(c.Request.Context(), models.DisputeFilter{InvoiceID: id})
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar contestações da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"disputes": disputes})
+}
+
+// Abre a contestação da fatura ou, com installment_number, da parcela. Enquanto aberta, a fatura
+// fica fora da cobrança e o valor contestado não entra nos indicadores de vencidos.
+// @Security BearerAuth
+// @Param id path int true "ID da fatura"
+func OpenInvoiceDisputeHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.InvoiceDisputeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	dispute, err := service.OpenInvoiceDispute(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao abrir contestação da fatura")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"dispute": dispute})
+}
+
+// Resolve a contestação: credit_note emite a nota de crédito, adjusted_due_date muda o vencimento
+// e write_off baixa o valor. Sem amount, a nota de crédito e a baixa são do valor contestado.
+// @Security BearerAuth
+// @Param id path int true "ID da fatura"
+// @Param dispute_id path int true "ID da contestação"
+func ResolveInvoiceDisputeHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	disputeID, err := strconv.Atoi(c.Param("dispute_id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.DisputeResolutionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	dispute, err := service.ResolveInvoiceDispute(c.Request.Context(), id, disputeID, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao resolver contestação da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dispute": dispute})
+}
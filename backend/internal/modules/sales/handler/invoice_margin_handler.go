@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Confere a margem da fatura por categoria contra a margem mínima de cada uma
+// O custo de cada linha é o CMV já apurado para a fatura ou, sem ele, o custo atual do estoque.
+// blocked indica que a fatura não será contabilizada sem liberação.
+// @Param id path int true "ID da fatura"
+// @Security BearerAuth
+func GetInvoiceMarginHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	check, err := service.CheckInvoiceMargin(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao conferir margem da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"margin": check})
+}
+
+// Libera a contabilização da fatura com margem abaixo do mínimo, registrando quem aprovou e o motivo
+// @Param id path int true "ID da fatura"
+// @Security BearerAuth
+func ApproveInvoiceMarginHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var input models.MarginApprovalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	check, err := service.ApproveInvoiceMargin(c.Request.Context(), id, middleware.CurrentUsername(c), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao liberar margem da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"margin": check})
+}
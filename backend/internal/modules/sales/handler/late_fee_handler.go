@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista a regra padrão de multa e juros por atraso e as regras próprias dos contatos
+// @Security BearerAuth
+func ListLateFeeRulesHandler(c *gin.Context) {
+	rules, err := service.ListLateFeeRules(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar regras de multa e juros")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// Define a regra padrão de multa e juros ou, com contact_id, a regra própria do contato. A multa
+// (fine_percent do saldo vencido mais fine_amount) é cobrada uma vez; os juros (interest_percent
+// ao mês) correm pró-rata por dia desde o vencimento, passados os grace_days de carência.
+// @Security BearerAuth
+func SaveLateFeeRuleHandler(c *gin.Context) {
+	var input models.LateFeeRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	rule, err := service.SaveLateFeeRule(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar regra de multa e juros")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rule": rule})
+}
+
+// Remove a regra de multa e juros; as faturas do contato passam a seguir a regra padrão
+// @Security BearerAuth
+// @Param id path int true "ID da regra"
+func DeleteLateFeeRuleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	if err := service.DeleteLateFeeRule(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover regra de multa e juros")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Retorna o valor para quitar a fatura na data: o saldo em aberto de cada vencimento com a multa
+// e os juros por atraso da regra do contato
+// @Param id path int true "ID da fatura"
+// @Param as_of query date false "Data de referência (AAAA-MM-DD); padrão: hoje"
+// @Security BearerAuth
+func GetInvoiceAmountDueHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+	var asOf time.Time
+	if value := c.Query("as_of"); value != "" {
+		asOf, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			c.Error(errors.InvalidParam("as_of deve estar no formato AAAA-MM-DD"))
+			return
+		}
+	}
+
+	due, err := service.GetInvoiceAmountDue(c.Request.Context(), id, asOf)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao calcular valor devido da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"amount_due": due})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/mapper"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as faturas quitadas pelo pagamento e o valor alocado em cada uma
+func GetPaymentAllocationsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	allocations, err := service.GetPaymentAllocations(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar alocações do pagamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"allocations": allocations})
+}
+
+// Redistribui o pagamento entre faturas do mesmo contato, recalculando o valor pago e o status
+// das faturas afetadas
+func UpdatePaymentAllocationsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.PaymentAllocationsUpdateDTO
+	if !bindAndValidate(c, &req) {
+		return
+	}
+
+	allocations, err := service.ReplacePaymentAllocations(c.Request.Context(), id, mapper.FromPaymentAllocationDTOs(req.Allocations))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao alocar pagamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"allocations": allocations})
+}
+
+// Lista os pagamentos alocados na fatura
+// @Security BearerAuth
+func GetInvoiceAllocationsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	allocations, err := service.GetInvoiceAllocations(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar pagamentos da fatura")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"allocations": allocations})
+}
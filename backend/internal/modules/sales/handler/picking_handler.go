@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/mapper"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Inicia a separação da entrega de pedido de venda
+// A entrega pendente passa para picking e a resposta traz a lista de separação agrupada por endereço de estoque.
+func StartPickingHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	pickList, err := service.StartPicking(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao iniciar separação da entrega")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pick_list": pickList})
+}
+
+// Retorna a lista de separação da entrega agrupada por endereço de estoque
+func GetPickListHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	pickList, err := service.GetPickList(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar lista de separação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pick_list": pickList})
+}
+
+// Registra as quantidades separadas dos itens da entrega em separação
+// A quantidade separada de cada item substitui a anterior e não pode passar da quantidade do item.
+func RecordPicksHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.PickRecordDTO
+	if !bindAndValidate(c, &req) {
+		return
+	}
+
+	pickList, err := service.RecordPicks(c.Request.Context(), id, mapper.ToPickLines(req))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao registrar separação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pick_list": pickList})
+}
+
+// Registra os volumes da entrega separada e a marca como embalada
+// Todos os itens precisam estar separados e cada quantidade separada deve estar distribuída nos volumes. Reenviar substitui os volumes.
+func PackDeliveryHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.PackDeliveryDTO
+	if !bindAndValidate(c, &req) {
+		return
+	}
+
+	packages, err := service.PackDelivery(c.Request.Context(), id, mapper.ToPackageInputs(req))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao embalar entrega")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"packages": packages})
+}
+
+// Lista os volumes da entrega com peso, dimensões e itens
+func ListDeliveryPackagesHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	packages, err := service.GetDeliveryPackages(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar volumes da entrega")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"packages": packages})
+}
+
+// Marca a entrega como enviada
+// Entregas de pedido de venda só podem ser enviadas depois de embaladas; recebimentos de pedido de compra, quando pendentes.
+func ShipDeliveryHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var req dtos.DeliveryShipDTO
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	if err := service.ShipDelivery(c.Request.Context(), id, req.TrackingNumber); err != nil {
+		c.Error(err).SetMeta("erro ao marcar entrega como enviada")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "entrega marcada como enviada"})
+}
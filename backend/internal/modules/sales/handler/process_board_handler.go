@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Quadro dos processos de venda: uma coluna por etapa com a quantidade, o valor total e os
+// cartões na ordem da coluna
+// @Security BearerAuth
+// @Param contact_id query int false "ID do cliente"
+// @Param include_closed query bool false "Inclui as colunas dos concluídos e cancelados"
+// @Param limit query int false "Cartões por coluna (padrão 50, máximo 200)"
+func GetProcessBoardHandler(c *gin.Context) {
+	var filter models.ProcessBoardFilter
+	if value := c.Query("contact_id"); value != "" {
+		contactID, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("contact_id inválido"))
+			return
+		}
+		filter.ContactID = contactID
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > 200 {
+			c.Error(errors.InvalidParam("limit deve estar entre 1 e 200"))
+			return
+		}
+		filter.Limit = limit
+	}
+	filter.IncludeClosed, _ = strconv.ParseBool(c.Query("include_closed"))
+
+	board, err := service.GetProcessBoard(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar quadro de processos")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// Move o processo de venda para a etapa e a posição da coluna (1 é o topo; sem posição, vai
+// para o fim). Na mesma etapa, só reordena.
+// @Security BearerAuth
+// @Param id path int true "ID do processo de venda"
+func MoveSalesProcessHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.MoveProcessInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	card, err := service.MoveSalesProcess(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao mover processo de venda")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"process": card})
+}
+
+// Linha do tempo do processo de venda, paginada e em ordem cronológica: documentos vinculados,
+// mudanças de etapa, pagamentos e os lançamentos dos usuários
+// @Security BearerAuth
+// @Param id path int true "ID do processo de venda"
+// @Param page query int false "Página"
+// @Param page_size query int false "Eventos por página"
+// @Param order query string false "asc (padrão) ou desc, do evento mais recente ao mais antigo"
+func GetSalesProcessTimelineHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	order := c.DefaultQuery("order", "asc")
+	if order != "asc" && order != "desc" {
+		c.Error(errors.InvalidParam("order deve ser asc ou desc"))
+		return
+	}
+
+	params := pagination.NewPaginationParams(c.Request)
+	result, err := service.GetProcessTimeline(c.Request.Context(), id, order == "desc", &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar linha do tempo do processo")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Lança na linha do tempo do processo de venda uma ligação, reunião, e-mail ou anotação; sem
+// occurred_at, o evento fica com a data e a hora do lançamento
+// @Security BearerAuth
+// @Param id path int true "ID do processo de venda"
+func AddSalesProcessEventHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.ProcessEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	event, err := service.AddProcessEvent(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao lançar evento do processo")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"event": event})
+}
+
+// sseKeepAlive é o intervalo dos comentários que mantêm a conexão de eventos aberta em proxies
+const sseKeepAlive = 25 * time.Second
+
+// Acompanha em tempo real os eventos da linha do tempo do processo de venda (server-sent
+// events), como os pagamentos Pix recebidos; a conexão fica aberta até o cliente encerrar
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param id path int true "ID do processo de venda"
+func StreamSalesProcessEventsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	events, cancel, err := service.SubscribeProcessEvents(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao acompanhar processo de venda")
+		return
+	}
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			c.SSEvent(event.Type, event.Data)
+		case <-ticker.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
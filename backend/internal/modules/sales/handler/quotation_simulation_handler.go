@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Simula a margem da cotação com descontos, preços, quantidades ou custos hipotéticos, usando o
+// custo real do estoque; a cotação não é alterada
+// @Security BearerAuth
+// @Param id path int true "ID da cotação"
+func SimulateQuotationHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	var input models.QuotationSimulationInput
+	if !bindOptionalJSON(c, &input) {
+		return
+	}
+
+	simulation, err := service.SimulateQuotation(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao simular margem da cotação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"simulation": simulation})
+}
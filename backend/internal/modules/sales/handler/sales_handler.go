@@ -5,45 +5,43 @@ import (
 	"net/http"
 	"strconv"
 
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/logger"
-	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/mapper"
 	"ERP-ONSMART/backend/internal/modules/sales/service"
+	"ERP-ONSMART/backend/internal/utils/validation"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 )
 
-var validate *validator.Validate
-
-func init() {
-	validate = validator.New()
-}
-
+// Lista as vendas simples
 func ListSalesHandler(c *gin.Context) {
-	sales, err := service.ListSales()
+	sales, err := service.ListSales(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar vendas"})
+		c.Error(err).SetMeta("Erro ao buscar vendas")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": sales})
 }
 
+// Busca uma venda simples pelo ID
 func GetSaleHandler(c *gin.Context) {
 	// Parse the ID parameter from the URL
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
-	sale, err := service.GetSale(id)
+	sale, err := service.GetSale(c.Request.Context(), id)
 	if err != nil {
 		// Check if it's "not found" error
 		if err.Error() == sql.ErrNoRows.Error() || err.Error() == "venda com ID "+strconv.Itoa(id)+" não encontrada" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Venda não encontrada"})
+			c.Error(errors.ErrSaleNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar venda"})
+			c.Error(err).SetMeta("Erro ao buscar venda")
 		}
 		return
 	}
@@ -51,25 +49,17 @@ func GetSaleHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, sale)
 }
 
+// Registra uma venda simples
 func CreateSaleHandler(c *gin.Context) {
-	var sale models.Sale
-
-	// Tenta fazer o bind do JSON recebido
-	if err := c.ShouldBindJSON(&sale); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Valida os campos da struct
-	if err := validate.Struct(sale); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dtos.SaleCreateDTO
+	if !bindAndValidate(c, &req) {
 		return
 	}
 
 	// Chama o service para salvar no banco
-	created, err := service.AddSale(sale)
+	created, err := service.AddSale(c.Request.Context(), mapper.ToSaleModel(req))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar venda"})
+		c.Error(err).SetMeta("Erro ao criar venda")
 		return
 	}
 
@@ -78,30 +68,25 @@ func CreateSaleHandler(c *gin.Context) {
 	c.JSON(http.StatusCreated, created)
 }
 
+// Atualiza uma venda simples
 func UpdateSaleHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
 
-	var sale models.Sale
-	if err := c.ShouldBindJSON(&sale); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dtos.SaleUpdateDTO
+	if !bindAndValidate(c, &req) {
 		return
 	}
 
-	if err := validate.Struct(sale); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	updated, err := service.ModifySale(id, sale)
+	updated, err := service.ModifySale(c.Request.Context(), id, mapper.SaleUpdateToModel(req))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Venda não encontrada"})
+			c.Error(errors.ErrSaleNotFound)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar venda"})
+			c.Error(err).SetMeta("Erro ao atualizar venda")
 		}
 		return
 	}
@@ -109,15 +94,30 @@ func UpdateSaleHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, updated)
 }
 
+// Remove uma venda simples
 func DeleteSaleHandler(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		c.Error(errors.ErrInvalidID)
 		return
 	}
-	if err := service.RemoveSale(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "erro ao deletar venda", "details": err.Error()})
+	if err := service.RemoveSale(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao deletar venda")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Venda deletado com sucesso"})
 }
+
+// bindAndValidate lê o corpo JSON e aplica as regras do DTO; os erros por campo saem no
+// idioma do Accept-Language pelo middleware.ErrorHandler
+func bindAndValidate(c *gin.Context, dto interface{}) bool {
+	if err := c.ShouldBindJSON(dto); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return false
+	}
+	if err := validation.Struct(dto); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return false
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"ERP-ONSMART/backend/internal/middleware"
 	"bytes"
 	"encoding/json"
 	"net/http"
@@ -36,6 +37,8 @@ func TestMain(m *testing.M) {
 func TestCreateSaleHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.POST("/sales", CreateSaleHandler)
 
 	// Exemplo de JSON com dados válidos
@@ -47,6 +50,7 @@ func TestCreateSaleHandler(t *testing.T) {
 	}`)
 
 	req, _ := http.NewRequest("POST", "/sales", bytes.NewBuffer(body))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
@@ -59,9 +63,12 @@ func TestCreateSaleHandler(t *testing.T) {
 func TestListSalesHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	router.GET("/sales", ListSalesHandler)
 
 	req, _ := http.NewRequest("GET", "/sales", nil)
+	req.Header.Set(middleware.CompanyHeader, "1")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
@@ -86,6 +93,8 @@ func TestListSalesHandler(t *testing.T) {
 func TestGetSaleHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 
 	// Setup routes
 	router.POST("/sales", CreateSaleHandler)
@@ -100,6 +109,7 @@ func TestGetSaleHandler(t *testing.T) {
 	}`)
 
 	req, _ := http.NewRequest("POST", "/sales", bytes.NewBuffer(createBody))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
@@ -118,6 +128,7 @@ func TestGetSaleHandler(t *testing.T) {
 
 	// 3. Test successful retrieval
 	getReq, _ := http.NewRequest("GET", "/sales/"+strconv.Itoa(createdSale.ID), nil)
+	getReq.Header.Set(middleware.CompanyHeader, "1")
 	getResp := httptest.NewRecorder()
 	router.ServeHTTP(getResp, getReq)
 
@@ -144,6 +155,7 @@ func TestGetSaleHandler(t *testing.T) {
 	// 5. Test non-existent sale
 	nonExistingID := 99999
 	nonExistReq, _ := http.NewRequest("GET", "/sales/"+strconv.Itoa(nonExistingID), nil)
+	nonExistReq.Header.Set(middleware.CompanyHeader, "1")
 	nonExistResp := httptest.NewRecorder()
 	router.ServeHTTP(nonExistResp, nonExistReq)
 
@@ -153,6 +165,7 @@ func TestGetSaleHandler(t *testing.T) {
 
 	// 6. Test invalid ID format
 	invalidReq, _ := http.NewRequest("GET", "/sales/abc", nil)
+	invalidReq.Header.Set(middleware.CompanyHeader, "1")
 	invalidResp := httptest.NewRecorder()
 	router.ServeHTTP(invalidResp, invalidReq)
 
@@ -164,6 +177,8 @@ func TestGetSaleHandler(t *testing.T) {
 func TestUpdateSaleHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	// Cria as rotas necessárias
 	router.POST("/sales", CreateSaleHandler)
 	router.PUT("/sales/:id", UpdateSaleHandler)
@@ -177,6 +192,7 @@ func TestUpdateSaleHandler(t *testing.T) {
 		"customer": "cliente@example.com"
 	}`)
 	req, _ := http.NewRequest("POST", "/sales", bytes.NewBuffer(createBody))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	createResp := httptest.NewRecorder()
 	router.ServeHTTP(createResp, req)
@@ -187,6 +203,7 @@ func TestUpdateSaleHandler(t *testing.T) {
 	// Recupera o ID da venda criada via GET
 	listResp := httptest.NewRecorder()
 	reqList, _ := http.NewRequest("GET", "/sales", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	router.ServeHTTP(listResp, reqList)
 	var result struct {
 		Data []struct {
@@ -209,6 +226,7 @@ func TestUpdateSaleHandler(t *testing.T) {
 		"customer": "cliente@exemplo.com"
 	}`)
 	reqUpdate, _ := http.NewRequest("PUT", "/sales/"+strconv.Itoa(id), bytes.NewBuffer(updateBody))
+	reqUpdate.Header.Set(middleware.CompanyHeader, "1")
 	reqUpdate.Header.Set("Content-Type", "application/json")
 	updateResp := httptest.NewRecorder()
 	router.ServeHTTP(updateResp, reqUpdate)
@@ -220,6 +238,8 @@ func TestUpdateSaleHandler(t *testing.T) {
 func TestDeleteSaleHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
 	// Cria as rotas necessárias
 	router.POST("/sales", CreateSaleHandler)
 	router.DELETE("/sales/:id", DeleteSaleHandler)
@@ -233,6 +253,7 @@ func TestDeleteSaleHandler(t *testing.T) {
 		"customer": "cliente@exemplo.com"
 	}`)
 	req, _ := http.NewRequest("POST", "/sales", bytes.NewBuffer(body))
+	req.Header.Set(middleware.CompanyHeader, "1")
 	req.Header.Set("Content-Type", "application/json")
 	createResp := httptest.NewRecorder()
 	router.ServeHTTP(createResp, req)
@@ -243,6 +264,7 @@ func TestDeleteSaleHandler(t *testing.T) {
 	// Recupera o ID da última venda criada
 	listResp := httptest.NewRecorder()
 	reqList, _ := http.NewRequest("GET", "/sales", nil)
+	reqList.Header.Set(middleware.CompanyHeader, "1")
 	router.ServeHTTP(listResp, reqList)
 	var result struct {
 		Data []struct {
@@ -259,9 +281,108 @@ func TestDeleteSaleHandler(t *testing.T) {
 
 	// Deleta a venda
 	reqDel, _ := http.NewRequest("DELETE", "/sales/"+strconv.Itoa(id), nil)
+	reqDel.Header.Set(middleware.CompanyHeader, "1")
 	delResp := httptest.NewRecorder()
 	router.ServeHTTP(delResp, reqDel)
 	if delResp.Code != http.StatusOK {
 		t.Errorf("Esperado status 200 ao deletar, obtido %d", delResp.Code)
 	}
 }
+
+func TestCreateSaleHandlerValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
+	router.POST("/sales", CreateSaleHandler)
+
+	body := []byte(`{
+		"product": "Produto A",
+		"quantity": -1,
+		"price": 10,
+		"customer": "cliente@example.com",
+		"customer_document": "111.111.111-11"
+	}`)
+
+	var result struct {
+		Error struct {
+			Code        string `json:"code"`
+			FieldErrors []struct {
+				Field   string `json:"field"`
+				Message string `json:"message"`
+			} `json:"field_errors"`
+		} `json:"error"`
+	}
+
+	cases := map[string]map[string]string{
+		"pt-BR":          {"quantity": "valor deve ser maior que 0", "customer_document": "CPF/CNPJ inválido"},
+		"en-US,en;q=0.9": {"quantity": "value must be greater than 0", "customer_document": "invalid CPF/CNPJ"},
+	}
+	for language, expected := range cases {
+		req, _ := http.NewRequest("POST", "/sales", bytes.NewBuffer(body))
+		req.Header.Set(middleware.CompanyHeader, "1")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", language)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("Esperado status 400, obtido %d", resp.Code)
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Erro ao decodificar resposta: %v", err)
+		}
+		if result.Error.Code != "invalid_request" {
+			t.Errorf("Código inesperado: %s", result.Error.Code)
+		}
+		got := map[string]string{}
+		for _, fe := range result.Error.FieldErrors {
+			got[fe.Field] = fe.Message
+		}
+		for field, message := range expected {
+			if got[field] != message {
+				t.Errorf("[%s] mensagem de %s: esperado %q, obtido %q", language, field, message, got[field])
+			}
+		}
+	}
+}
+
+func TestListDocumentsHandlerInvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TenantMiddleware())
+	router.GET("/sales-orders", ListSalesOrdersHandler)
+	router.GET("/invoices", ListInvoicesHandler)
+	router.GET("/deliveries", ListDeliveriesHandler)
+
+	cases := map[string]string{
+		"/sales-orders?sort=-password":      "invalid_sort_field",
+		"/invoices?sort=due_date,-nope":     "invalid_sort_field",
+		"/invoices?fields=id,secret":        "invalid_field_selection",
+		"/deliveries?fields=tracking,items": "invalid_field_selection",
+		"/sales-orders?contact_id=abc":      "invalid_parameter",
+	}
+	for url, code := range cases {
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set(middleware.CompanyHeader, "1")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("[%s] esperado status 400, obtido %d", url, resp.Code)
+			continue
+		}
+		var result struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Erro ao decodificar resposta: %v", err)
+		}
+		if result.Error.Code != code {
+			t.Errorf("[%s] código esperado %s, obtido %s", url, code, result.Error.Code)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package mapper
 import (
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
 )
 
 // ToContactBasicInfo converte Contact model para ContactBasicInfo DTO
@@ -37,3 +38,17 @@ func ToAddressDTO(contact *contact.Contact) *dtos.AddressDTO {
 		Country:    "Brasil", // Default, já que não temos esse campo no model
 	}
 }
+
+// ToShippingOptionDTO converte o frete escolhido no documento; nil quando não há frete escolhido
+func ToShippingOptionDTO(option models.ShippingOption) *dtos.ShippingOptionDTO {
+	if option.ShippingCarrier == "" {
+		return nil
+	}
+	return &dtos.ShippingOptionDTO{
+		Carrier:      option.ShippingCarrier,
+		ServiceCode:  option.ShippingServiceCode,
+		ServiceName:  option.ShippingService,
+		Cost:         option.ShippingCost,
+		DeliveryDays: option.ShippingDays,
+	}
+}
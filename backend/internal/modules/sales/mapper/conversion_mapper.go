@@ -0,0 +1,54 @@
+package mapper
+
+import (
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+)
+
+// ToSalesOrderConversion converte QuotationConversionDTO para as opções da conversão
+func ToSalesOrderConversion(dto dtos.QuotationConversionDTO) models.SalesOrderConversion {
+	return models.SalesOrderConversion{
+		ExpectedDate:      dto.ExpectedDate,
+		PaymentTerms:      dto.PaymentTerms,
+		ShippingAddress:   dto.ShippingAddress,
+		ShippingAddressID: dto.ShippingAddressID,
+		BillingAddressID:  dto.BillingAddressID,
+		CustomFields:      dto.CustomFields,
+		DownPayment:       toDownPayment(dto.DownPayment),
+	}
+}
+
+// toDownPayment converte o adiantamento pedido na conversão; nil sem adiantamento
+func toDownPayment(dto *dtos.DownPaymentDTO) *models.DownPayment {
+	if dto == nil {
+		return nil
+	}
+	return &models.DownPayment{Percent: dto.Percent, Amount: dto.Amount}
+}
+
+// ToAdvanceInvoiceGeneration converte AdvanceInvoiceGenerationDTO para as opções da fatura de
+// adiantamento
+func ToAdvanceInvoiceGeneration(dto dtos.AdvanceInvoiceGenerationDTO) models.AdvanceInvoiceGeneration {
+	return models.AdvanceInvoiceGeneration{
+		DownPayment: models.DownPayment{Percent: dto.Percent, Amount: dto.Amount},
+		IssueDate:   dto.IssueDate,
+		DueDate:     dto.DueDate,
+	}
+}
+
+// ToInvoiceGeneration converte InvoiceGenerationDTO para as opções da geração da fatura
+func ToInvoiceGeneration(dto dtos.InvoiceGenerationDTO) models.InvoiceGeneration {
+	return models.InvoiceGeneration{
+		IssueDate: dto.IssueDate,
+		DueDate:   dto.DueDate,
+	}
+}
+
+// ToDeliveryGeneration converte DeliveryGenerationDTO para as opções da geração da entrega
+func ToDeliveryGeneration(dto dtos.DeliveryGenerationDTO) models.DeliveryGeneration {
+	return models.DeliveryGeneration{
+		DeliveryDate:   dto.DeliveryDate,
+		ShippingMethod: dto.ShippingMethod,
+		Carrier:        dto.Carrier,
+	}
+}
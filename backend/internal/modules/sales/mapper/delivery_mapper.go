@@ -12,20 +12,23 @@ func ToDeliveryResponseDTO(delivery *models.Delivery) *dtos.DeliveryResponseDTO
 	}
 
 	dto := &dtos.DeliveryResponseDTO{
-		ID:              delivery.ID,
-		DeliveryNo:      delivery.DeliveryNo,
-		PurchaseOrderID: delivery.PurchaseOrderID,
-		PONo:            delivery.PONo,
-		SalesOrderID:    delivery.SalesOrderID,
-		SONo:            delivery.SONo,
-		Status:          delivery.Status,
-		CreatedAt:       delivery.CreatedAt,
-		UpdatedAt:       delivery.UpdatedAt,
-		DeliveryDate:    delivery.DeliveryDate,
-		ShippingMethod:  delivery.ShippingMethod,
-		TrackingNumber:  delivery.TrackingNumber,
-		ShippingAddress: delivery.ShippingAddress,
-		Notes:           delivery.Notes,
+		ID:                delivery.ID,
+		DeliveryNo:        delivery.DeliveryNo,
+		PurchaseOrderID:   delivery.PurchaseOrderID,
+		PONo:              delivery.PONo,
+		SalesOrderID:      delivery.SalesOrderID,
+		SONo:              delivery.SONo,
+		Status:            delivery.Status,
+		CreatedAt:         delivery.CreatedAt,
+		UpdatedAt:         delivery.UpdatedAt,
+		DeliveryDate:      delivery.DeliveryDate,
+		ShippingMethod:    delivery.ShippingMethod,
+		Carrier:           delivery.Carrier,
+		TrackingNumber:    delivery.TrackingNumber,
+		ShippingAddress:   delivery.ShippingAddress,
+		ShippingAddressID: delivery.ShippingAddressID,
+		Notes:             delivery.Notes,
+		DriverID:          delivery.DriverID,
 	}
 
 	// ReceivedDate pode estar vazio
@@ -60,6 +63,7 @@ func ToDeliveryItemResponseDTO(item *models.DeliveryItem) *dtos.DeliveryItemResp
 		ProductCode: item.ProductCode,
 		Description: item.Description,
 		Quantity:    item.Quantity,
+		Unit:        item.Unit,
 		ReceivedQty: item.ReceivedQty,
 		Notes:       item.Notes,
 	}
@@ -87,6 +91,7 @@ func FromDeliveryCreateDTO(dto *dtos.DeliveryCreateDTO) *models.Delivery {
 		SalesOrderID:    dto.SalesOrderID,
 		DeliveryDate:    dto.DeliveryDate,
 		ShippingMethod:  dto.ShippingMethod,
+		Carrier:         dto.Carrier,
 		ShippingAddress: dto.ShippingAddress,
 		Notes:           dto.Notes,
 		Status:          models.DeliveryStatusPending,
@@ -113,6 +118,7 @@ func FromDeliveryItemCreateDTO(dto *dtos.DeliveryItemCreateDTO) *models.Delivery
 		ProductCode: dto.ProductCode,
 		Description: dto.Description,
 		Quantity:    dto.Quantity,
+		Unit:        dto.Unit,
 		Notes:       dto.Notes,
 		ReceivedQty: 0, // Inicialmente não recebido
 	}
@@ -142,3 +148,13 @@ func ToDeliveryItemResponseDTOList(items []models.DeliveryItem) []dtos.DeliveryI
 	}
 	return result
 }
+
+// ToDeliveryStatusChange converte o item do lote de status para o model
+func ToDeliveryStatusChange(dto dtos.DeliveryStatusBatchItemDTO) models.DeliveryStatusChange {
+	return models.DeliveryStatusChange{
+		DeliveryID:     dto.DeliveryID,
+		Status:         dto.Status,
+		TrackingNumber: dto.TrackingNumber,
+		Notes:          dto.Notes,
+	}
+}
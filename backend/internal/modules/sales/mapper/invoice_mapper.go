@@ -17,7 +17,9 @@ func ToInvoiceResponseDTO(invoice *models.Invoice) *dtos.InvoiceResponseDTO {
 		InvoiceNo:     invoice.InvoiceNo,
 		SalesOrderID:  invoice.SalesOrderID,
 		ContactID:     invoice.ContactID,
+		SalespersonID: invoice.SalespersonID,
 		Status:        invoice.Status,
+		Kind:          invoice.Kind,
 		CreatedAt:     invoice.CreatedAt,
 		UpdatedAt:     invoice.UpdatedAt,
 		IssueDate:     invoice.IssueDate,
@@ -27,9 +29,13 @@ func ToInvoiceResponseDTO(invoice *models.Invoice) *dtos.InvoiceResponseDTO {
 		DiscountTotal: invoice.DiscountTotal,
 		GrandTotal:    invoice.GrandTotal,
 		AmountPaid:    invoice.AmountPaid,
-		BalanceDue:    invoice.GrandTotal - invoice.AmountPaid, // Calculado
+		BalanceDue:    invoice.GrandTotal.Sub(invoice.AmountPaid), // Calculado
 		PaymentTerms:  invoice.PaymentTerms,
 		Notes:         invoice.Notes,
+
+		EarlyDiscountAmount: invoice.EarlyDiscountAmount,
+		EarlyDiscountUntil:  invoice.EarlyDiscountUntil,
+		AdvanceDeduction:    invoice.AdvanceDeduction,
 	}
 
 	// Mapear relações
@@ -74,6 +80,8 @@ func ToInvoiceItemResponseDTO(item *models.InvoiceItem) *dtos.InvoiceItemRespons
 		ProductCode: item.ProductCode,
 		Description: item.Description,
 		Quantity:    item.Quantity,
+		Unit:        item.Unit,
+		UnitFactor:  item.UnitFactor,
 		UnitPrice:   item.UnitPrice,
 		Discount:    item.Discount,
 		Tax:         item.Tax,
@@ -88,13 +96,14 @@ func FromInvoiceCreateDTO(dto *dtos.InvoiceCreateDTO) *models.Invoice {
 	}
 
 	invoice := &models.Invoice{
-		SalesOrderID: dto.SalesOrderID,
-		ContactID:    dto.ContactID,
-		IssueDate:    dto.IssueDate,
-		DueDate:      dto.DueDate,
-		PaymentTerms: dto.PaymentTerms,
-		Notes:        dto.Notes,
-		Status:       models.InvoiceStatusDraft, // Status inicial
+		SalesOrderID:  dto.SalesOrderID,
+		ContactID:     dto.ContactID,
+		SalespersonID: dto.SalespersonID,
+		IssueDate:     dto.IssueDate,
+		DueDate:       dto.DueDate,
+		PaymentTerms:  dto.PaymentTerms,
+		Notes:         dto.Notes,
+		Status:        models.InvoiceStatusDraft, // Status inicial
 	}
 
 	// Mapear itens
@@ -118,6 +127,7 @@ func FromInvoiceItemCreateDTO(dto *dtos.InvoiceItemCreateDTO) *models.InvoiceIte
 		ProductCode: dto.ProductCode,
 		Description: dto.Description,
 		Quantity:    dto.Quantity,
+		Unit:        dto.Unit,
 		UnitPrice:   dto.UnitPrice,
 		Discount:    dto.Discount,
 		Tax:         dto.Tax,
@@ -148,3 +158,15 @@ func ToInvoiceItemResponseDTOList(items []models.InvoiceItem) []dtos.InvoiceItem
 	}
 	return result
 }
+
+// FromInvoiceInstallmentPlanDTO converte o plano de parcelas informado para o model
+func FromInvoiceInstallmentPlanDTO(dto *dtos.InvoiceInstallmentPlanDTO) models.InstallmentPlan {
+	plan := models.InstallmentPlan{Count: dto.Count, IntervalDays: dto.IntervalDays}
+	if dto.FirstDueDate != nil {
+		plan.FirstDueDate = *dto.FirstDueDate
+	}
+	for _, installment := range dto.Installments {
+		plan.Installments = append(plan.Installments, models.InstallmentSpec{DueDate: installment.DueDate, Amount: installment.Amount})
+	}
+	return plan
+}
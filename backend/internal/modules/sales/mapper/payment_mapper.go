@@ -73,9 +73,22 @@ func FromPaymentCreateDTO(dto *dtos.PaymentCreateDTO) *models.Payment {
 		PaymentMethod: dto.PaymentMethod,
 		Reference:     dto.Reference,
 		Notes:         dto.Notes,
+		Allocations:   FromPaymentAllocationDTOs(dto.Allocations),
 	}
 }
 
+// FromPaymentAllocationDTOs converte as alocações informadas para o model
+func FromPaymentAllocationDTOs(dtoList []dtos.PaymentAllocationDTO) []models.PaymentAllocation {
+	if len(dtoList) == 0 {
+		return nil
+	}
+	allocations := make([]models.PaymentAllocation, 0, len(dtoList))
+	for _, dto := range dtoList {
+		allocations = append(allocations, models.PaymentAllocation{InvoiceID: dto.InvoiceID, Amount: dto.Amount})
+	}
+	return allocations
+}
+
 // FromPaymentUpdateDTO converte PaymentUpdateDTO para Payment model (apenas campos não nulos)
 func FromPaymentUpdateDTO(dto *dtos.PaymentUpdateDTO, payment *models.Payment) {
 	if dto == nil || payment == nil {
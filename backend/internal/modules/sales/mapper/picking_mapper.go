@@ -0,0 +1,34 @@
+package mapper
+
+import (
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+)
+
+// ToPickLines converte PickRecordDTO para as quantidades separadas dos itens
+func ToPickLines(dto dtos.PickRecordDTO) []models.PickLine {
+	lines := make([]models.PickLine, len(dto.Items))
+	for i, item := range dto.Items {
+		lines[i] = models.PickLine{DeliveryItemID: item.DeliveryItemID, PickedQty: item.PickedQty}
+	}
+	return lines
+}
+
+// ToPackageInputs converte PackDeliveryDTO para os volumes da entrega
+func ToPackageInputs(dto dtos.PackDeliveryDTO) []models.PackageInput {
+	inputs := make([]models.PackageInput, len(dto.Packages))
+	for i, pkg := range dto.Packages {
+		items := make([]models.PackageItemInput, len(pkg.Items))
+		for j, item := range pkg.Items {
+			items[j] = models.PackageItemInput{DeliveryItemID: item.DeliveryItemID, Quantity: item.Quantity}
+		}
+		inputs[i] = models.PackageInput{
+			WeightKg: pkg.WeightKg,
+			LengthCm: pkg.LengthCm,
+			WidthCm:  pkg.WidthCm,
+			HeightCm: pkg.HeightCm,
+			Items:    items,
+		}
+	}
+	return inputs
+}
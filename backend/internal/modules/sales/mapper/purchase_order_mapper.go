@@ -107,6 +107,8 @@ func ToPOItemResponseDTO(item *models.POItem) *dtos.POItemResponseDTO {
 		ProductCode:     item.ProductCode,
 		Description:     item.Description,
 		Quantity:        item.Quantity,
+		Unit:            item.Unit,
+		UnitFactor:      item.UnitFactor,
 		UnitPrice:       item.UnitPrice,
 		Discount:        item.Discount,
 		Tax:             item.Tax,
@@ -155,6 +157,7 @@ func FromPOItemCreateDTO(dto *dtos.POItemCreateDTO) *models.POItem {
 		ProductCode: dto.ProductCode,
 		Description: dto.Description,
 		Quantity:    dto.Quantity,
+		Unit:        dto.Unit,
 		UnitPrice:   dto.UnitPrice,
 		Discount:    dto.Discount,
 		Tax:         dto.Tax,
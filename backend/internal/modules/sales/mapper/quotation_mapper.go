@@ -16,6 +16,8 @@ func ToQuotationResponseDTO(quotation *models.Quotation) *dtos.QuotationResponse
 		ID:            quotation.ID,
 		QuotationNo:   quotation.QuotationNo,
 		ContactID:     quotation.ContactID,
+		SalespersonID: quotation.SalespersonID,
+		CampaignID:    quotation.CampaignID,
 		Status:        quotation.Status,
 		CreatedAt:     quotation.CreatedAt,
 		UpdatedAt:     quotation.UpdatedAt,
@@ -26,6 +28,7 @@ func ToQuotationResponseDTO(quotation *models.Quotation) *dtos.QuotationResponse
 		GrandTotal:    quotation.GrandTotal,
 		Notes:         quotation.Notes,
 		Terms:         quotation.Terms,
+		Shipping:      ToShippingOptionDTO(quotation.ShippingOption),
 	}
 
 	// Mapear Contact
@@ -97,6 +100,8 @@ func ToQuotationItemResponseDTO(item *models.QuotationItem) *dtos.QuotationItemR
 		ProductCode: item.ProductCode,
 		Description: item.Description,
 		Quantity:    item.Quantity,
+		Unit:        item.Unit,
+		UnitFactor:  item.UnitFactor,
 		UnitPrice:   item.UnitPrice,
 		Discount:    item.Discount,
 		Tax:         item.Tax,
@@ -111,11 +116,13 @@ func FromQuotationCreateDTO(dto *dtos.QuotationCreateDTO) *models.Quotation {
 	}
 
 	quotation := &models.Quotation{
-		ContactID:  dto.ContactID,
-		ExpiryDate: dto.ExpiryDate,
-		Notes:      dto.Notes,
-		Terms:      dto.Terms,
-		Status:     models.QuotationStatusDraft,
+		ContactID:     dto.ContactID,
+		SalespersonID: dto.SalespersonID,
+		CampaignID:    dto.CampaignID,
+		ExpiryDate:    dto.ExpiryDate,
+		Notes:         dto.Notes,
+		Terms:         dto.Terms,
+		Status:        models.QuotationStatusDraft,
 	}
 
 	// Mapear itens
@@ -139,6 +146,7 @@ func FromQuotationItemCreateDTO(dto *dtos.QuotationItemCreateDTO) *models.Quotat
 		ProductCode: dto.ProductCode,
 		Description: dto.Description,
 		Quantity:    dto.Quantity,
+		Unit:        dto.Unit,
 		UnitPrice:   dto.UnitPrice,
 		Discount:    dto.Discount,
 		Tax:         dto.Tax,
@@ -151,6 +159,14 @@ func FromQuotationUpdateDTO(dto *dtos.QuotationUpdateDTO, quotation *models.Quot
 		return
 	}
 
+	if dto.SalespersonID != nil {
+		quotation.SalespersonID = dto.SalespersonID
+	}
+
+	if dto.CampaignID != nil {
+		quotation.CampaignID = dto.CampaignID
+	}
+
 	if dto.ExpiryDate != nil {
 		quotation.ExpiryDate = *dto.ExpiryDate
 	}
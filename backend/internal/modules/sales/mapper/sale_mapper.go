@@ -0,0 +1,23 @@
+package mapper
+
+import (
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/validation"
+)
+
+// ToSaleModel converte SaleCreateDTO para o model Sale
+func ToSaleModel(dto dtos.SaleCreateDTO) models.Sale {
+	return models.Sale{
+		Product:          dto.Product,
+		Quantity:         dto.Quantity,
+		Price:            dto.Price,
+		Customer:         dto.Customer,
+		CustomerDocument: validation.OnlyDigits(dto.CustomerDocument),
+	}
+}
+
+// SaleUpdateToModel converte SaleUpdateDTO para o model Sale
+func SaleUpdateToModel(dto dtos.SaleUpdateDTO) models.Sale {
+	return ToSaleModel(dtos.SaleCreateDTO(dto))
+}
@@ -12,21 +12,26 @@ func ToSalesOrderResponseDTO(so *models.SalesOrder) *dtos.SalesOrderResponseDTO
 	}
 
 	dto := &dtos.SalesOrderResponseDTO{
-		ID:              so.ID,
-		SONo:            so.SONo,
-		QuotationID:     so.QuotationID,
-		ContactID:       so.ContactID,
-		Status:          so.Status,
-		CreatedAt:       so.CreatedAt,
-		UpdatedAt:       so.UpdatedAt,
-		ExpectedDate:    so.ExpectedDate,
-		SubTotal:        so.SubTotal,
-		TaxTotal:        so.TaxTotal,
-		DiscountTotal:   so.DiscountTotal,
-		GrandTotal:      so.GrandTotal,
-		Notes:           so.Notes,
-		PaymentTerms:    so.PaymentTerms,
-		ShippingAddress: so.ShippingAddress,
+		ID:                so.ID,
+		SONo:              so.SONo,
+		QuotationID:       so.QuotationID,
+		ContactID:         so.ContactID,
+		SalespersonID:     so.SalespersonID,
+		Status:            so.Status,
+		CreatedAt:         so.CreatedAt,
+		UpdatedAt:         so.UpdatedAt,
+		ExpectedDate:      so.ExpectedDate,
+		SubTotal:          so.SubTotal,
+		TaxTotal:          so.TaxTotal,
+		DiscountTotal:     so.DiscountTotal,
+		GrandTotal:        so.GrandTotal,
+		Notes:             so.Notes,
+		PaymentTerms:      so.PaymentTerms,
+		ShippingAddress:   so.ShippingAddress,
+		ShippingAddressID: so.ShippingAddressID,
+		BillingAddressID:  so.BillingAddressID,
+		BillingAddress:    so.BillingAddress,
+		Shipping:          ToShippingOptionDTO(so.ShippingOption),
 	}
 
 	// Mapear Contact
@@ -91,6 +96,8 @@ func ToSOItemResponseDTO(item *models.SOItem) *dtos.SOItemResponseDTO {
 		ProductCode:  item.ProductCode,
 		Description:  item.Description,
 		Quantity:     item.Quantity,
+		Unit:         item.Unit,
+		UnitFactor:   item.UnitFactor,
 		UnitPrice:    item.UnitPrice,
 		Discount:     item.Discount,
 		Tax:          item.Tax,
@@ -114,6 +121,7 @@ func FromSalesOrderCreateDTO(dto *dtos.SalesOrderCreateDTO) *models.SalesOrder {
 	so := &models.SalesOrder{
 		QuotationID:     dto.QuotationID,
 		ContactID:       dto.ContactID,
+		SalespersonID:   dto.SalespersonID,
 		ExpectedDate:    dto.ExpectedDate,
 		PaymentTerms:    dto.PaymentTerms,
 		ShippingAddress: dto.ShippingAddress,
@@ -142,6 +150,7 @@ func FromSOItemCreateDTO(dto *dtos.SOItemCreateDTO) *models.SOItem {
 		ProductCode: dto.ProductCode,
 		Description: dto.Description,
 		Quantity:    dto.Quantity,
+		Unit:        dto.Unit,
 		UnitPrice:   dto.UnitPrice,
 		Discount:    dto.Discount,
 		Tax:         dto.Tax,
@@ -154,6 +163,10 @@ func FromSalesOrderUpdateDTO(dto *dtos.SalesOrderUpdateDTO, so *models.SalesOrde
 		return
 	}
 
+	if dto.SalespersonID != nil {
+		so.SalespersonID = dto.SalespersonID
+	}
+
 	if dto.ExpectedDate != nil {
 		so.ExpectedDate = *dto.ExpectedDate
 	}
@@ -3,6 +3,7 @@ package mapper
 import (
 	"ERP-ONSMART/backend/internal/modules/sales/dtos"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
 	"time"
 )
 
@@ -19,7 +20,7 @@ func ToSalesProcessResponseDTO(sp *models.SalesProcess) *dtos.SalesProcessRespon
 		CreatedAt:  sp.CreatedAt,
 		UpdatedAt:  sp.UpdatedAt,
 		TotalValue: sp.TotalValue,
-		TotalCost:  sp.TotalValue - sp.Profit, // Calculado
+		TotalCost:  sp.TotalValue.Sub(sp.Profit), // Calculado
 		Profit:     sp.Profit,
 		Notes:      sp.Notes,
 	}
@@ -30,8 +31,8 @@ func ToSalesProcessResponseDTO(sp *models.SalesProcess) *dtos.SalesProcessRespon
 	}
 
 	// Calcular margem de lucro
-	if sp.TotalValue > 0 {
-		dto.ProfitMargin = (sp.Profit / sp.TotalValue) * 100
+	if sp.TotalValue.IsPositive() {
+		dto.ProfitMargin = sp.Profit.Float64() / sp.TotalValue.Float64() * 100
 	}
 
 	// Campos calculados - seriam obtidos de outros lugares
@@ -67,8 +68,8 @@ func ToSalesProcessListItemDTO(sp *models.SalesProcess) *dtos.SalesProcessListIt
 	}
 
 	// Calcular margem de lucro
-	if sp.TotalValue > 0 {
-		dto.ProfitMargin = (sp.Profit / sp.TotalValue) * 100
+	if sp.TotalValue.IsPositive() {
+		dto.ProfitMargin = sp.Profit.Float64() / sp.TotalValue.Float64() * 100
 	}
 
 	// Taxa de conclusão seria calculada
@@ -87,8 +88,8 @@ func FromSalesProcessCreateDTO(dto *dtos.SalesProcessCreateDTO) *models.SalesPro
 		ContactID:  dto.ContactID,
 		Notes:      dto.Notes,
 		TotalValue: dto.InitialValue,
-		Status:     "open", // Status inicial
-		Profit:     0,      // Inicialmente sem lucro
+		Status:     "open",     // Status inicial
+		Profit:     money.Zero, // Inicialmente sem lucro
 	}
 }
 
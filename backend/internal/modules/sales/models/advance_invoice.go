@@ -0,0 +1,94 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tipos de fatura
+const (
+	InvoiceKindStandard = "standard"
+	// InvoiceKindAdvance é a fatura do adiantamento pedido na confirmação do pedido de venda, sem
+	// itens; o valor é abatido das faturas seguintes do pedido
+	InvoiceKindAdvance = "advance"
+)
+
+// DownPayment é o adiantamento pedido ao cliente: percentual do total do pedido ou valor fixo
+type DownPayment struct {
+	Percent float64       `json:"percent"`
+	Amount  money.Decimal `json:"amount"`
+}
+
+// AdvanceInvoiceGeneration traz o adiantamento e as datas opcionais da fatura de adiantamento;
+// sem vencimento, vence na emissão
+type AdvanceInvoiceGeneration struct {
+	DownPayment
+	IssueDate time.Time `json:"issue_date"`
+	DueDate   time.Time `json:"due_date"`
+}
+
+// AdvanceAmount calcula o valor do adiantamento sobre o total do pedido, que somado aos
+// adiantamentos já faturados (requested) não pode passar do total
+func (d DownPayment) AdvanceAmount(orderTotal, requested money.Decimal) (money.Decimal, error) {
+	var amount money.Decimal
+	switch {
+	case d.Percent != 0 && !d.Amount.IsZero():
+		return money.Zero, fmt.Errorf("%w: percent e amount são exclusivos", errors.ErrInvalidDownPayment)
+	case d.Percent != 0:
+		if d.Percent < 0 || d.Percent > 100 {
+			return money.Zero, fmt.Errorf("%w: percent deve estar entre 0 e 100", errors.ErrInvalidDownPayment)
+		}
+		amount = money.Round(orderTotal.MulFloat(d.Percent / 100))
+	default:
+		amount = money.Round(d.Amount)
+	}
+	if !amount.IsPositive() {
+		return money.Zero, errors.ErrInvalidDownPayment
+	}
+	if available := orderTotal.Sub(requested); amount.GreaterThan(available) {
+		return money.Zero, fmt.Errorf("%w: adiantamento de %s, saldo do pedido %s", errors.ErrInvalidDownPayment,
+			amount.StringFixed(2), money.Max(available, money.Zero).StringFixed(2))
+	}
+	return amount, nil
+}
+
+// Describe escreve o adiantamento nas observações da fatura
+func (d DownPayment) Describe(so *SalesOrder) string {
+	if d.Percent != 0 {
+		percent := strings.Replace(strconv.FormatFloat(d.Percent, 'f', -1, 64), ".", ",", 1)
+		return fmt.Sprintf("Adiantamento de %s%% do pedido %s", percent, so.SONo)
+	}
+	return fmt.Sprintf("Adiantamento do pedido %s", so.SONo)
+}
+
+// AdvanceInvoiceFromSalesOrder monta a fatura de adiantamento do pedido, sem itens, com o valor
+// do adiantamento como total
+func AdvanceInvoiceFromSalesOrder(so *SalesOrder, down DownPayment, amount money.Decimal) *Invoice {
+	return &Invoice{
+		SalesOrderID:  so.ID,
+		SONo:          so.SONo,
+		ContactID:     so.ContactID,
+		SalespersonID: so.SalespersonID,
+		Status:        InvoiceStatusDraft,
+		Kind:          InvoiceKindAdvance,
+		SubTotal:      amount,
+		GrandTotal:    amount,
+		PaymentTerms:  "À vista",
+		Notes:         down.Describe(so),
+
+		BillingAddressID: so.BillingAddressID,
+		BillingAddress:   so.BillingAddress,
+	}
+}
+
+// DeductAdvance abate da fatura o adiantamento ainda não deduzido do pedido (available), até o
+// total da fatura
+func (inv *Invoice) DeductAdvance(available money.Decimal) {
+	deduction := money.Max(money.Min(available, inv.GrandTotal), money.Zero)
+	inv.AdvanceDeduction = deduction
+	inv.GrandTotal = inv.GrandTotal.Sub(deduction)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownPaymentAdvanceAmount(t *testing.T) {
+	total := money.MustParse("1234.50")
+
+	amount, err := DownPayment{Percent: 30}.AdvanceAmount(total, money.Zero)
+	require.NoError(t, err)
+	assert.Equal(t, "370.35", amount.StringFixed(2))
+
+	amount, err = DownPayment{Amount: money.FromInt(500)}.AdvanceAmount(total, money.FromInt(700))
+	require.NoError(t, err)
+	assert.Equal(t, "500.00", amount.StringFixed(2))
+
+	invalid := []struct {
+		down      DownPayment
+		requested money.Decimal
+	}{
+		{DownPayment{}, money.Zero},
+		{DownPayment{Percent: 10, Amount: money.FromInt(10)}, money.Zero},
+		{DownPayment{Percent: -5}, money.Zero},
+		{DownPayment{Percent: 120}, money.Zero},
+		{DownPayment{Amount: money.FromInt(-10)}, money.Zero},
+		{DownPayment{Amount: money.FromInt(600)}, money.FromInt(700)},
+	}
+	for _, tc := range invalid {
+		_, err := tc.down.AdvanceAmount(total, tc.requested)
+		assert.True(t, stderrors.Is(err, errors.ErrInvalidDownPayment), "%+v: %v", tc.down, err)
+	}
+}
+
+func TestAdvanceInvoiceFromSalesOrder(t *testing.T) {
+	so := &SalesOrder{ID: 8, SONo: "SO-2026-000008", ContactID: 3, BillingAddress: "Rua A, 10"}
+
+	invoice := AdvanceInvoiceFromSalesOrder(so, DownPayment{Percent: 12.5}, money.FromInt(250))
+	assert.Equal(t, InvoiceKindAdvance, invoice.Kind)
+	assert.Equal(t, InvoiceStatusDraft, invoice.Status)
+	assert.Equal(t, 8, invoice.SalesOrderID)
+	assert.Equal(t, "250.00", invoice.GrandTotal.StringFixed(2))
+	assert.Empty(t, invoice.Items)
+	assert.Equal(t, "Adiantamento de 12,5% do pedido SO-2026-000008", invoice.Notes)
+	assert.Equal(t, "Rua A, 10", invoice.BillingAddress)
+}
+
+func TestInvoiceDeductAdvance(t *testing.T) {
+	invoice := &Invoice{GrandTotal: money.FromInt(1000)}
+	invoice.DeductAdvance(money.FromInt(300))
+	assert.Equal(t, "300.00", invoice.AdvanceDeduction.StringFixed(2))
+	assert.Equal(t, "700.00", invoice.GrandTotal.StringFixed(2))
+
+	partial := &Invoice{GrandTotal: money.FromInt(200)}
+	partial.DeductAdvance(money.FromInt(300))
+	assert.Equal(t, "200.00", partial.AdvanceDeduction.StringFixed(2), "abate no máximo o total da fatura")
+	assert.True(t, partial.GrandTotal.IsZero())
+
+	none := &Invoice{GrandTotal: money.FromInt(200)}
+	none.DeductAdvance(money.FromInt(-50))
+	assert.True(t, none.AdvanceDeduction.IsZero())
+	assert.Equal(t, "200.00", none.GrandTotal.StringFixed(2))
+}
@@ -0,0 +1,96 @@
+package models
+
+import "ERP-ONSMART/backend/internal/errors"
+
+// MaxBatchSize é a quantidade máxima de itens aceita em uma requisição de lote
+const MaxBatchSize = 500
+
+// Situação de cada item no resultado do lote
+const (
+	BatchItemOK         = "ok"          // gravado
+	BatchItemError      = "error"       // reprovado na validação ou na gravação
+	BatchItemNotApplied = "not_applied" // desfeito ou não processado porque o lote atômico falhou
+)
+
+// BatchEntry associa o item validado à sua posição na requisição
+type BatchEntry[T any] struct {
+	Index int
+	Item  T
+}
+
+// BatchItemResult é o resultado de um item do lote, identificado pela posição na requisição
+type BatchItemResult struct {
+	Index  int              `json:"index"`
+	Status string           `json:"status"`
+	ID     int              `json:"id,omitempty"`
+	Number string           `json:"number,omitempty"`
+	Error  *errors.APIError `json:"error,omitempty"`
+}
+
+// BatchResult resume o processamento do lote. Em lotes atômicos, qualquer falha desfaz todos
+// os itens (rolled_back); nos demais, cada item é gravado ou rejeitado de forma independente.
+type BatchResult struct {
+	Atomic     bool              `json:"atomic"`
+	RolledBack bool              `json:"rolled_back"`
+	Total      int               `json:"total"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	Items      []BatchItemResult `json:"items"`
+}
+
+// DeliveryStatusChange é a alteração de status de uma entrega recebida em lote
+type DeliveryStatusChange struct {
+	DeliveryID     int
+	Status         string
+	TrackingNumber string
+	Notes          string
+}
+
+// BatchItemSucceeded registra o item gravado com o ID (e número, se houver) do registro
+func BatchItemSucceeded(index, id int, number string) BatchItemResult {
+	return BatchItemResult{Index: index, Status: BatchItemOK, ID: id, Number: number}
+}
+
+// BatchItemFailed registra o item rejeitado com o erro no envelope padrão da API
+func BatchItemFailed(index int, err error) BatchItemResult {
+	return BatchItemResult{Index: index, Status: BatchItemError, Error: errors.ToAPIError(err, "falha ao gravar item do lote")}
+}
+
+// NewBatchResult monta o resultado de um lote com total itens, ordenado pela posição na
+// requisição. Itens sem resultado (não processados após a falha de um lote atômico) e, em lotes
+// atômicos com falha, os itens gravados e desfeitos são marcados como não aplicados.
+func NewBatchResult(atomic bool, total int, items []BatchItemResult) *BatchResult {
+	byIndex := make(map[int]BatchItemResult, len(items))
+	for _, item := range items {
+		byIndex[item.Index] = item
+	}
+
+	result := &BatchResult{Atomic: atomic, Total: total, Items: make([]BatchItemResult, 0, total)}
+	for i := 0; i < total; i++ {
+		item, ok := byIndex[i]
+		if !ok {
+			item = BatchItemResult{Index: i, Status: BatchItemNotApplied}
+		}
+		if item.Status == BatchItemError {
+			result.Failed++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	if atomic && result.Failed > 0 {
+		result.RolledBack = true
+		for i := range result.Items {
+			if result.Items[i].Status == BatchItemOK {
+				result.Items[i] = BatchItemResult{Index: i, Status: BatchItemNotApplied}
+			}
+		}
+		return result
+	}
+
+	for _, item := range result.Items {
+		if item.Status == BatchItemOK {
+			result.Succeeded++
+		}
+	}
+	return result
+}
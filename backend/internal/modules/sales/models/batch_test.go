@@ -0,0 +1,62 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBatchResultCountsItemsInRequestOrder(t *testing.T) {
+	result := NewBatchResult(false, 3, []BatchItemResult{
+		BatchItemSucceeded(2, 12, "INV-2026-000012"),
+		BatchItemFailed(1, errors.ErrInvoiceNotFound),
+		BatchItemSucceeded(0, 10, "INV-2026-000010"),
+	})
+
+	assert.False(t, result.RolledBack)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	require.Len(t, result.Items, 3)
+	for i, item := range result.Items {
+		assert.Equal(t, i, item.Index)
+	}
+	assert.Equal(t, "invoice_not_found", result.Items[1].Error.Code)
+}
+
+func TestNewBatchResultAtomicFailureMarksOthersNotApplied(t *testing.T) {
+	// Item 2 não foi processado porque o lote parou na falha do item 1
+	result := NewBatchResult(true, 3, []BatchItemResult{
+		BatchItemSucceeded(0, 10, "INV-2026-000010"),
+		BatchItemFailed(1, errors.ErrInvoiceNotPayable),
+	})
+
+	assert.True(t, result.RolledBack)
+	assert.Equal(t, 0, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, BatchItemResult{Index: 0, Status: BatchItemNotApplied}, result.Items[0], "o ID desfeito não é exposto")
+	assert.Equal(t, BatchItemError, result.Items[1].Status)
+	assert.Equal(t, BatchItemNotApplied, result.Items[2].Status)
+}
+
+func TestRecalculateInvoiceTotals(t *testing.T) {
+	invoice := &Invoice{
+		GrandTotal: money.FromInt(1),
+		Items: []InvoiceItem{
+			{ProductID: 100, Quantity: 2, UnitPrice: money.FromInt(1500), Discount: money.FromInt(100), Tax: money.FromInt(50), Total: money.FromInt(1)},
+			{ProductID: 200, Quantity: 3, UnitPrice: money.MustParse("19.9")},
+		},
+	}
+
+	RecalculateInvoiceTotals(invoice)
+
+	assert.Equal(t, "2950.00", invoice.Items[0].Total.String())
+	assert.Equal(t, "59.70", invoice.Items[1].Total.String())
+	assert.Equal(t, "3059.70", invoice.SubTotal.String())
+	assert.Equal(t, "100.00", invoice.DiscountTotal.String())
+	assert.Equal(t, "50.00", invoice.TaxTotal.String())
+	assert.Equal(t, "3009.70", invoice.GrandTotal.String())
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	contracts "ERP-ONSMART/backend/internal/modules/contracts/models"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// O preço contratado vale por unidade de estoque: na linha, é multiplicado pelo fator da unidade
+// e substitui o preço informado. A linha guarda o contrato de onde veio o preço.
+
+// ApplyQuotationContractPrices aplica os preços contratados às linhas da cotação e recalcula os
+// totais; false se nenhuma linha tinha preço contratado
+func ApplyQuotationContractPrices(quotation *Quotation, prices map[int]contracts.Price) bool {
+	applied := false
+	for i := range quotation.Items {
+		item := &quotation.Items[i]
+		price, ok := prices[item.ProductID]
+		if !ok {
+			continue
+		}
+		item.UnitPrice = contractLinePrice(price, item.UnitFactor)
+		item.ContractID = &price.ContractID
+		applied = true
+	}
+	if applied {
+		RecalculateQuotationTotals(quotation)
+	}
+	return applied
+}
+
+// ApplyPurchaseOrderContractPrices aplica os preços contratados às linhas do pedido de compra e
+// recalcula os totais; false se nenhuma linha tinha preço contratado
+func ApplyPurchaseOrderContractPrices(po *PurchaseOrder, prices map[int]contracts.Price) bool {
+	applied := false
+	for i := range po.Items {
+		item := &po.Items[i]
+		price, ok := prices[item.ProductID]
+		if !ok {
+			continue
+		}
+		item.UnitPrice = contractLinePrice(price, item.UnitFactor)
+		item.ContractID = &price.ContractID
+		applied = true
+	}
+	if applied {
+		RecalculatePurchaseOrderTotals(po)
+	}
+	return applied
+}
+
+func contractLinePrice(price contracts.Price, unitFactor int) money.Decimal {
+	if unitFactor <= 0 {
+		unitFactor = 1
+	}
+	return money.Round(price.UnitPrice.MulInt(unitFactor))
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	contracts "ERP-ONSMART/backend/internal/modules/contracts/models"
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyQuotationContractPrices(t *testing.T) {
+	quotation := &Quotation{Items: []QuotationItem{
+		{ProductID: 10, Quantity: 2, UnitFactor: 12, UnitPrice: money.FromInt(70), Discount: money.FromInt(4), Tax: money.FromInt(2)},
+		{ProductID: 20, Quantity: 3, UnitFactor: 1, UnitPrice: money.FromInt(10)},
+	}}
+	prices := map[int]contracts.Price{10: {ContractID: 7, ProductID: 10, UnitPrice: money.MustParse("5.25")}}
+
+	require.True(t, ApplyQuotationContractPrices(quotation, prices))
+
+	assert.Equal(t, "63.00", quotation.Items[0].UnitPrice.String())
+	assert.Equal(t, "124.00", quotation.Items[0].Total.String())
+	require.NotNil(t, quotation.Items[0].ContractID)
+	assert.Equal(t, 7, *quotation.Items[0].ContractID)
+	assert.Nil(t, quotation.Items[1].ContractID)
+	assert.Equal(t, "156.00", quotation.SubTotal.String())
+	assert.Equal(t, "154.00", quotation.GrandTotal.String())
+
+	assert.False(t, ApplyQuotationContractPrices(&Quotation{Items: []QuotationItem{{ProductID: 30}}}, prices))
+}
+
+func TestApplyPurchaseOrderContractPrices(t *testing.T) {
+	po := &PurchaseOrder{Items: []POItem{{ProductID: 10, Quantity: 10, UnitPrice: money.FromInt(6)}}}
+	prices := map[int]contracts.Price{10: {ContractID: 3, ProductID: 10, UnitPrice: money.MustParse("4.5")}}
+
+	require.True(t, ApplyPurchaseOrderContractPrices(po, prices))
+
+	assert.Equal(t, "4.50", po.Items[0].UnitPrice.String())
+	assert.Equal(t, "45.00", po.Items[0].Total.String())
+	assert.Equal(t, "45.00", po.GrandTotal.String())
+}
@@ -0,0 +1,209 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// SalesOrderConversion traz os dados opcionais do pedido de venda gerado a partir da cotação. Os
+// endereços vêm do catálogo do contato: sem ID, valem os padrões de entrega e de cobrança; o texto
+// livre em ShippingAddress só é usado sem ShippingAddressID.
+type SalesOrderConversion struct {
+	ExpectedDate      time.Time `json:"expected_date"`
+	PaymentTerms      string    `json:"payment_terms"`
+	ShippingAddress   string    `json:"shipping_address"`
+	ShippingAddressID *int      `json:"shipping_address_id"`
+	BillingAddressID  *int      `json:"billing_address_id"`
+	// CustomFields já validados contra as definições de sales_order
+	CustomFields customfields.Values `json:"custom_fields"`
+	// DownPayment pede um adiantamento, faturado junto com o pedido
+	DownPayment *DownPayment `json:"down_payment"`
+}
+
+// InvoiceGeneration traz os dados opcionais da fatura gerada a partir do pedido de venda
+type InvoiceGeneration struct {
+	IssueDate time.Time `json:"issue_date"`
+	DueDate   time.Time `json:"due_date"`
+}
+
+// DeliveryGeneration traz os dados opcionais da entrega gerada a partir do pedido de venda; sem
+// método de envio ou transportadora, valem os do frete escolhido no pedido
+type DeliveryGeneration struct {
+	DeliveryDate   time.Time `json:"delivery_date"`
+	ShippingMethod string    `json:"shipping_method"`
+	Carrier        string    `json:"carrier"`
+}
+
+// DocumentTotals agrupa os totais recalculados de um documento de venda
+type DocumentTotals struct {
+	SubTotal      money.Decimal
+	TaxTotal      money.Decimal
+	DiscountTotal money.Decimal
+	GrandTotal    money.Decimal
+}
+
+// Add acumula uma linha nos totais do documento, arredondados nas casas da moeda
+func (t *DocumentTotals) Add(quantity int, unitPrice, discount, tax money.Decimal) {
+	t.SubTotal = money.Round(t.SubTotal.Add(unitPrice.MulInt(quantity)))
+	t.DiscountTotal = money.Round(t.DiscountTotal.Add(discount))
+	t.TaxTotal = money.Round(t.TaxTotal.Add(tax))
+	t.GrandTotal = t.SubTotal.Sub(t.DiscountTotal).Add(t.TaxTotal)
+}
+
+// LineTotal calcula o total da linha: quantidade x preço - desconto + imposto
+func LineTotal(quantity int, unitPrice, discount, tax money.Decimal) money.Decimal {
+	return money.Round(unitPrice.MulInt(quantity).Sub(discount).Add(tax))
+}
+
+// prorate distribui um valor da linha (desconto ou imposto) proporcionalmente à quantidade parcial
+func prorate(value money.Decimal, quantity, total int) money.Decimal {
+	if total <= 0 || quantity == total {
+		return value
+	}
+	return money.Round(value.Prorate(quantity, total))
+}
+
+// SalesOrderFromQuotation monta o pedido de venda a partir da cotação, copiando os itens
+// e recalculando os totais
+func SalesOrderFromQuotation(quotation *Quotation) *SalesOrder {
+	so := &SalesOrder{
+		QuotationID:    quotation.ID,
+		ContactID:      quotation.ContactID,
+		SalespersonID:  quotation.SalespersonID,
+		Status:         SOStatusConfirmed,
+		Notes:          quotation.Notes,
+		ShippingOption: quotation.ShippingOption,
+		Items:          make([]SOItem, 0, len(quotation.Items)),
+	}
+
+	var totals DocumentTotals
+	for _, item := range quotation.Items {
+		so.Items = append(so.Items, SOItem{
+			ProductID:    item.ProductID,
+			KitProductID: item.KitProductID,
+			ProductName:  item.ProductName,
+			ProductCode:  item.ProductCode,
+			Description:  item.Description,
+			Quantity:     item.Quantity,
+			Unit:         item.Unit,
+			UnitFactor:   item.UnitFactor,
+			UnitPrice:    item.UnitPrice,
+			Discount:     item.Discount,
+			Tax:          item.Tax,
+			Total:        LineTotal(item.Quantity, item.UnitPrice, item.Discount, item.Tax),
+		})
+		totals.Add(item.Quantity, item.UnitPrice, item.Discount, item.Tax)
+	}
+	so.applyTotals(totals)
+
+	return so
+}
+
+// InvoiceFromSalesOrder monta a fatura com o saldo ainda não faturado de cada linha do pedido,
+// na unidade da linha. invoiced traz a quantidade já faturada por produto em unidades de estoque,
+// consumida na ordem das linhas; um saldo menor que uma unidade da linha (ex.: unidades soltas de
+// uma caixa faturadas à parte) não entra na fatura gerada.
+func InvoiceFromSalesOrder(so *SalesOrder, invoiced map[int]int) (*Invoice, error) {
+	invoice := &Invoice{
+		SalesOrderID:  so.ID,
+		SONo:          so.SONo,
+		ContactID:     so.ContactID,
+		SalespersonID: so.SalespersonID,
+		Status:        InvoiceStatusDraft,
+		PaymentTerms:  so.PaymentTerms,
+		Items:         make([]InvoiceItem, 0, len(so.Items)),
+
+		BillingAddressID: so.BillingAddressID,
+		BillingAddress:   so.BillingAddress,
+	}
+
+	remaining := make(map[int]int, len(invoiced))
+	for productID, quantity := range invoiced {
+		remaining[productID] = quantity
+	}
+
+	var totals DocumentTotals
+	for _, item := range so.Items {
+		base := item.BaseQuantity()
+		if already := remaining[item.ProductID]; already > 0 {
+			used := min(already, base)
+			remaining[item.ProductID] -= used
+			base -= used
+		}
+		quantity := base / max(item.UnitFactor, 1)
+		if quantity <= 0 {
+			continue
+		}
+
+		discount := prorate(item.Discount, quantity, item.Quantity)
+		tax := prorate(item.Tax, quantity, item.Quantity)
+		invoice.Items = append(invoice.Items, InvoiceItem{
+			ProductID:    item.ProductID,
+			KitProductID: item.KitProductID,
+			ProductName:  item.ProductName,
+			ProductCode:  item.ProductCode,
+			Description:  item.Description,
+			Quantity:     quantity,
+			Unit:         item.Unit,
+			UnitFactor:   item.UnitFactor,
+			UnitPrice:    item.UnitPrice,
+			Discount:     discount,
+			Tax:          tax,
+			Total:        LineTotal(quantity, item.UnitPrice, discount, tax),
+		})
+		totals.Add(quantity, item.UnitPrice, discount, tax)
+	}
+
+	if len(invoice.Items) == 0 {
+		return nil, errors.ErrNothingToInvoice
+	}
+
+	invoice.SubTotal = totals.SubTotal
+	invoice.TaxTotal = totals.TaxTotal
+	invoice.DiscountTotal = totals.DiscountTotal
+	invoice.GrandTotal = totals.GrandTotal
+	return invoice, nil
+}
+
+// DeliveryFromSalesOrder monta a entrega com o saldo ainda não enviado de cada linha do pedido,
+// em unidades de estoque, com a transportadora e o serviço do frete escolhido no pedido
+func DeliveryFromSalesOrder(so *SalesOrder, fulfillment *SalesOrderFulfillment) (*Delivery, error) {
+	delivery := &Delivery{
+		SalesOrderID:      so.ID,
+		SONo:              so.SONo,
+		Status:            DeliveryStatusPending,
+		ShippingAddress:   so.ShippingAddress,
+		ShippingAddressID: so.ShippingAddressID,
+		ShippingMethod:    so.ShippingService,
+		Carrier:           so.ShippingCarrier,
+		Items:             make([]DeliveryItem, 0, len(fulfillment.Items)),
+	}
+
+	for _, line := range fulfillment.Items {
+		if line.RemainingQty <= 0 {
+			continue
+		}
+		soItemID := line.SOItemID
+		delivery.Items = append(delivery.Items, DeliveryItem{
+			SOItemID:    &soItemID,
+			ProductID:   line.ProductID,
+			ProductName: line.ProductName,
+			ProductCode: line.ProductCode,
+			Quantity:    line.RemainingQty,
+		})
+	}
+
+	if len(delivery.Items) == 0 {
+		return nil, errors.ErrNothingToDeliver
+	}
+	return delivery, nil
+}
+
+func (so *SalesOrder) applyTotals(totals DocumentTotals) {
+	so.SubTotal = totals.SubTotal
+	so.TaxTotal = totals.TaxTotal
+	so.DiscountTotal = totals.DiscountTotal
+	so.GrandTotal = totals.GrandTotal
+}
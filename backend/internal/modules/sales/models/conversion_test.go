@@ -0,0 +1,99 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSalesOrderFromQuotationRecalculatesTotals(t *testing.T) {
+	quotation := &Quotation{
+		ID:        7,
+		ContactID: 3,
+		Notes:     "entrega em 10 dias",
+		ShippingOption: ShippingOption{
+			ShippingCarrier: "jadlog", ShippingServiceCode: "3", ShippingService: ".Package", ShippingCost: money.MustParse("55.3"), ShippingDays: 4,
+		},
+		Items: []QuotationItem{
+			{ProductID: 100, ProductName: "Notebook", Quantity: 2, UnitPrice: money.FromInt(1500), Discount: money.FromInt(100), Tax: money.FromInt(50), Total: money.FromInt(1)},
+			{ProductID: 200, ProductName: "Mouse", Quantity: 3, UnitPrice: money.MustParse("19.9")},
+		},
+	}
+
+	so := SalesOrderFromQuotation(quotation)
+	require.Len(t, so.Items, 2)
+
+	assert.Equal(t, 7, so.QuotationID)
+	assert.Equal(t, 3, so.ContactID)
+	assert.Equal(t, SOStatusConfirmed, so.Status)
+	assert.Equal(t, quotation.ShippingOption, so.ShippingOption, "frete escolhido passa para o pedido")
+	assert.Equal(t, "2950.00", so.Items[0].Total.String(), "total da linha é recalculado")
+	assert.Equal(t, "59.70", so.Items[1].Total.String())
+
+	assert.Equal(t, "3059.70", so.SubTotal.String())
+	assert.Equal(t, "100.00", so.DiscountTotal.String())
+	assert.Equal(t, "50.00", so.TaxTotal.String())
+	assert.Equal(t, "3009.70", so.GrandTotal.String())
+}
+
+func TestInvoiceFromSalesOrderInvoicesRemainingQuantities(t *testing.T) {
+	order := testOrder()
+	order.ContactID = 3
+	order.BillingAddressID = intPtr(8)
+	order.BillingAddress = "Rua B, 20 - São Paulo/SP"
+	order.Items[0].UnitPrice = money.FromInt(10)
+	order.Items[0].Discount = money.FromInt(10)
+	order.Items[1].UnitPrice = money.FromInt(4)
+
+	invoice, err := InvoiceFromSalesOrder(order, map[int]int{100: 6})
+	require.NoError(t, err)
+	require.Len(t, invoice.Items, 2)
+	assert.Equal(t, intPtr(8), invoice.BillingAddressID)
+	assert.Equal(t, "Rua B, 20 - São Paulo/SP", invoice.BillingAddress)
+
+	assert.Equal(t, 1, invoice.SalesOrderID)
+	assert.Equal(t, InvoiceStatusDraft, invoice.Status)
+	assert.Equal(t, 4, invoice.Items[0].Quantity)
+	assert.Equal(t, "4.00", invoice.Items[0].Discount.String(), "desconto proporcional à quantidade faturada")
+	assert.Equal(t, "36.00", invoice.Items[0].Total.String())
+	assert.Equal(t, 5, invoice.Items[1].Quantity)
+	assert.Equal(t, "56.00", invoice.GrandTotal.String())
+
+	_, err = InvoiceFromSalesOrder(order, map[int]int{100: 10, 200: 5})
+	assert.Equal(t, errors.ErrNothingToInvoice, err)
+}
+
+func TestDeliveryFromSalesOrderShipsRemainingQuantities(t *testing.T) {
+	order := testOrder()
+	order.ShippingAddress = "Rua A, 10"
+	order.ShippingAddressID = intPtr(7)
+	order.ShippingOption = ShippingOption{ShippingCarrier: "correios", ShippingService: "SEDEX", ShippingCost: money.MustParse("32.4")}
+
+	fulfillment := BuildFulfillment(order, []ShippedLine{
+		{SOItemID: intPtr(10), ProductID: 100, Quantity: 4},
+		{SOItemID: intPtr(11), ProductID: 200, Quantity: 5},
+	}, nil)
+
+	delivery, err := DeliveryFromSalesOrder(order, fulfillment)
+	require.NoError(t, err)
+	require.Len(t, delivery.Items, 1)
+
+	assert.Equal(t, DeliveryStatusPending, delivery.Status)
+	assert.Equal(t, "Rua A, 10", delivery.ShippingAddress)
+	assert.Equal(t, intPtr(7), delivery.ShippingAddressID, "endereço do catálogo herdado do pedido")
+	assert.Equal(t, "correios", delivery.Carrier, "transportadora do frete escolhido no pedido")
+	assert.Equal(t, "SEDEX", delivery.ShippingMethod)
+	require.NotNil(t, delivery.Items[0].SOItemID)
+	assert.Equal(t, 10, *delivery.Items[0].SOItemID)
+	assert.Equal(t, 6, delivery.Items[0].Quantity)
+
+	fulfillment = BuildFulfillment(order, []ShippedLine{
+		{SOItemID: intPtr(10), ProductID: 100, Quantity: 10},
+		{SOItemID: intPtr(11), ProductID: 200, Quantity: 5},
+	}, nil)
+	_, err = DeliveryFromSalesOrder(order, fulfillment)
+	assert.Equal(t, errors.ErrNothingToDeliver, err)
+}
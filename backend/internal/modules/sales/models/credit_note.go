@@ -0,0 +1,27 @@
+package models
+
+import (
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// CreditNote represents a credit issued to a client, usually against an invoice
+type CreditNote struct {
+	ID           int           `json:"id" gorm:"primaryKey"`
+	CompanyID    int           `json:"company_id" gorm:"<-:create"`
+	CreditNoteNo string        `json:"credit_note_no" validate:"required" gorm:"uniqueIndex"`
+	InvoiceID    int           `json:"invoice_id,omitempty" gorm:"index"`
+	ContactID    int           `json:"contact_id" validate:"required" gorm:"index"`
+	CostCenterID *int          `json:"cost_center_id,omitempty"`
+	Status       string        `json:"status" validate:"required" gorm:"default:issued"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	IssueDate    time.Time     `json:"issue_date"`
+	Amount       money.Decimal `json:"amount" validate:"required,gt=0"`
+	Reason       string        `json:"reason"`
+
+	// Relationships
+	Contact *contact.Contact `json:"contact,omitempty" gorm:"foreignKey:ContactID"`
+	Invoice *Invoice         `json:"invoice,omitempty" gorm:"foreignKey:InvoiceID"`
+}
@@ -3,42 +3,65 @@ package models
 import (
 	product "ERP-ONSMART/backend/internal/modules/products/models"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Delivery represents a delivery of items
 type Delivery struct {
-	ID              int       `json:"id" gorm:"primaryKey"`
-	DeliveryNo      string    `json:"delivery_no" validate:"required" gorm:"uniqueIndex"`
-	PurchaseOrderID int       `json:"purchase_order_id" gorm:"index"`
-	PONo            string    `json:"po_no"`
-	SalesOrderID    int       `json:"sales_order_id" gorm:"index"`
-	SONo            string    `json:"so_no"`
-	Status          string    `json:"status" validate:"required" gorm:"default:pending"`
-	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	DeliveryDate    time.Time `json:"delivery_date"`
-	ReceivedDate    time.Time `json:"received_date"`
-	ShippingMethod  string    `json:"shipping_method"`
-	TrackingNumber  string    `json:"tracking_number"`
-	ShippingAddress string    `json:"shipping_address"`
-	Notes           string    `json:"notes"`
+	ID              int            `json:"id" gorm:"primaryKey"`
+	CompanyID       int            `json:"company_id" gorm:"<-:create"`
+	DeliveryNo      string         `json:"delivery_no" validate:"required" gorm:"uniqueIndex"`
+	PurchaseOrderID int            `json:"purchase_order_id" gorm:"index"`
+	PONo            string         `json:"po_no"`
+	SalesOrderID    int            `json:"sales_order_id" gorm:"index"`
+	SONo            string         `json:"so_no"`
+	Status          string         `json:"status" validate:"required" gorm:"default:pending"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	DeliveryDate    time.Time      `json:"delivery_date"`
+	ReceivedDate    time.Time      `json:"received_date"`
+	ShippingMethod  string         `json:"shipping_method"`
+	Carrier         string         `json:"carrier" gorm:"size:30"`
+	TrackingNumber  string         `json:"tracking_number"`
+	ShippingAddress string         `json:"shipping_address"`
+	// Endereço de entrega do catálogo do contato, herdado do pedido de venda
+	ShippingAddressID *int   `json:"shipping_address_id,omitempty"`
+	Notes             string `json:"notes"`
+	// Colaborador que faz a entrega, ligado pelo módulo hr
+	DriverID *int `json:"driver_id,omitempty"`
+
+	// Separação e embalagem (entregas de pedido de venda)
+	PickingStartedAt *time.Time `json:"picking_started_at,omitempty"`
+	PackedAt         *time.Time `json:"packed_at,omitempty"`
+	ShippedAt        *time.Time `json:"shipped_at,omitempty"`
+
+	// SLA de envio, calculado periodicamente pelo módulo sla (vazio sem SLA configurado)
+	SLAStatus *string    `json:"sla_status,omitempty" gorm:"column:sla_status"`
+	SLADueAt  *time.Time `json:"sla_due_at,omitempty" gorm:"column:sla_due_at"`
 
 	// Relationships
-	PurchaseOrder *PurchaseOrder `json:"purchase_order,omitempty" gorm:"foreignKey:PurchaseOrderID"`
-	SalesOrder    *SalesOrder    `json:"sales_order,omitempty" gorm:"foreignKey:SalesOrderID"`
-	Items         []DeliveryItem `json:"items,omitempty" gorm:"foreignKey:DeliveryID"`
+	PurchaseOrder *PurchaseOrder    `json:"purchase_order,omitempty" gorm:"foreignKey:PurchaseOrderID"`
+	SalesOrder    *SalesOrder       `json:"sales_order,omitempty" gorm:"foreignKey:SalesOrderID"`
+	Items         []DeliveryItem    `json:"items,omitempty" gorm:"foreignKey:DeliveryID"`
+	Packages      []DeliveryPackage `json:"packages,omitempty" gorm:"foreignKey:DeliveryID"`
 }
 
 // DeliveryItem represents an item in a delivery
 type DeliveryItem struct {
 	ID          int    `json:"id" gorm:"primaryKey"`
 	DeliveryID  int    `json:"delivery_id" gorm:"index"`
+	SOItemID    *int   `json:"so_item_id,omitempty" gorm:"column:so_item_id;index"`
 	ProductID   int    `json:"product_id" validate:"required" gorm:"index"`
 	ProductName string `json:"product_name"`
 	ProductCode string `json:"product_code"`
 	Description string `json:"description"`
 	Quantity    int    `json:"quantity" validate:"required,gt=0"`
+	Unit        string `json:"unit" gorm:"default:UN"`
 	ReceivedQty int    `json:"received_qty" gorm:"default:0"`
+	PickedQty   int    `json:"picked_qty" gorm:"default:0"`
+	LotNumber   string `json:"lot_number,omitempty"`
 	Notes       string `json:"notes"`
 
 	// Relationships
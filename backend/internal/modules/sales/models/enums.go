@@ -16,6 +16,8 @@ const (
 	SOStatusProcessing = "processing"
 	SOStatusCompleted  = "completed"
 	SOStatusCancelled  = "cancelled"
+	// SOStatusCreditHold é o pedido que ultrapassou o limite de crédito do cliente e aguarda liberação
+	SOStatusCreditHold = "credit_hold"
 
 	// Purchase Order statuses
 	POStatusDraft     = "draft"
@@ -26,6 +28,8 @@ const (
 
 	// Delivery statuses
 	DeliveryStatusPending   = "pending"
+	DeliveryStatusPicking   = "picking"
+	DeliveryStatusPacked    = "packed"
 	DeliveryStatusShipped   = "shipped"
 	DeliveryStatusDelivered = "delivered"
 	DeliveryStatusReturned  = "returned"
@@ -37,4 +41,26 @@ const (
 	InvoiceStatusPaid      = "paid"
 	InvoiceStatusOverdue   = "overdue"
 	InvoiceStatusCancelled = "cancelled"
+
+	// Credit Note statuses
+	CreditNoteStatusIssued    = "issued"
+	CreditNoteStatusApplied   = "applied"
+	CreditNoteStatusCancelled = "cancelled"
+
+	// Supplier Bill statuses (draft: importada de NF-e, aguardando conferência)
+	SupplierBillStatusDraft     = "draft"
+	SupplierBillStatusOpen      = "open"
+	SupplierBillStatusPartial   = "partial"
+	SupplierBillStatusPaid      = "paid"
+	SupplierBillStatusCancelled = "cancelled"
+
+	// Conferência de três vias das contas a pagar (pending: aguardando conferência; discrepancy:
+	// divergências acima da tolerância aguardando o comprador; accepted e rejected: decisão do
+	// comprador sobre as divergências)
+	BillMatchNotRequired = "not_required"
+	BillMatchPending     = "pending"
+	BillMatchMatched     = "matched"
+	BillMatchDiscrepancy = "discrepancy"
+	BillMatchAccepted    = "accepted"
+	BillMatchRejected    = "rejected"
 )
@@ -0,0 +1,229 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"time"
+)
+
+// Fulfillment statuses for sales order lines and orders
+const (
+	FulfillmentStatusPending   = "pending"
+	FulfillmentStatusPartial   = "partial"
+	FulfillmentStatusFulfilled = "fulfilled"
+)
+
+// ShippedLine represents a delivery item already allocated to a sales order
+type ShippedLine struct {
+	SOItemID  *int `json:"so_item_id"`
+	ProductID int  `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+	Delivered bool `json:"delivered"`
+}
+
+// SOItemFulfillment represents the fulfillment position of a sales order line. Quantities are in
+// stock units; Unit and UnitFactor describe the unit the line was ordered in.
+type SOItemFulfillment struct {
+	SOItemID       int    `json:"so_item_id"`
+	ProductID      int    `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	ProductCode    string `json:"product_code"`
+	Unit           string `json:"unit"`
+	UnitFactor     int    `json:"unit_factor"`
+	OrderedQty     int    `json:"ordered_qty"`
+	ShippedQty     int    `json:"shipped_qty"`
+	DeliveredQty   int    `json:"delivered_qty"`
+	RemainingQty   int    `json:"remaining_qty"`
+	BackorderedQty int    `json:"backordered_qty"`
+	Status         string `json:"status"`
+}
+
+// FulfillmentDelivery summarizes a delivery that fulfills part of a sales order
+type FulfillmentDelivery struct {
+	DeliveryID   int       `json:"delivery_id"`
+	DeliveryNo   string    `json:"delivery_no"`
+	Status       string    `json:"status"`
+	DeliveryDate time.Time `json:"delivery_date"`
+	TotalQty     int       `json:"total_qty"`
+}
+
+// SalesOrderFulfillment represents the fulfillment of a sales order across its deliveries
+type SalesOrderFulfillment struct {
+	SalesOrderID    int                   `json:"sales_order_id"`
+	SONo            string                `json:"so_no"`
+	OrderStatus     string                `json:"order_status"`
+	Status          string                `json:"status"`
+	TotalOrdered    int                   `json:"total_ordered"`
+	TotalShipped    int                   `json:"total_shipped"`
+	TotalDelivered  int                   `json:"total_delivered"`
+	TotalRemaining  int                   `json:"total_remaining"`
+	TotalBackorder  int                   `json:"total_backordered"`
+	FulfillmentRate float64               `json:"fulfillment_rate"`
+	Items           []SOItemFulfillment   `json:"items"`
+	Deliveries      []FulfillmentDelivery `json:"deliveries"`
+}
+
+// BuildFulfillment computes shipped, delivered, remaining and backordered quantities per line,
+// in stock units (delivery items are always stored in stock units).
+// Lines without so_item_id (legacy deliveries) are allocated by product to the first line with room.
+// Backordered is the remaining quantity not covered by the available stock of the product.
+func BuildFulfillment(order *SalesOrder, shipped []ShippedLine, stock map[int]int) *SalesOrderFulfillment {
+	f := &SalesOrderFulfillment{
+		SalesOrderID: order.ID,
+		SONo:         order.SONo,
+		OrderStatus:  order.Status,
+		Items:        make([]SOItemFulfillment, 0, len(order.Items)),
+		Deliveries:   []FulfillmentDelivery{},
+	}
+
+	index := make(map[int]int, len(order.Items))
+	for i, item := range order.Items {
+		index[item.ID] = i
+		f.Items = append(f.Items, SOItemFulfillment{
+			SOItemID:    item.ID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			ProductCode: item.ProductCode,
+			Unit:        item.Unit,
+			UnitFactor:  item.UnitFactor,
+			OrderedQty:  item.BaseQuantity(),
+		})
+	}
+
+	for _, line := range shipped {
+		pos := -1
+		if line.SOItemID != nil {
+			if i, ok := index[*line.SOItemID]; ok {
+				pos = i
+			}
+		}
+		if pos < 0 {
+			pos = findLineForProduct(f.Items, line.ProductID, line.Quantity)
+		}
+		if pos < 0 {
+			continue
+		}
+
+		f.Items[pos].ShippedQty += line.Quantity
+		if line.Delivered {
+			f.Items[pos].DeliveredQty += line.Quantity
+		}
+	}
+
+	// O estoque disponível é consumido na ordem das linhas do pedido
+	available := make(map[int]int, len(stock))
+	for productID, qty := range stock {
+		available[productID] = qty
+	}
+
+	for i := range f.Items {
+		item := &f.Items[i]
+
+		item.RemainingQty = item.OrderedQty - item.ShippedQty
+		if item.RemainingQty < 0 {
+			item.RemainingQty = 0
+		}
+
+		if item.RemainingQty > 0 {
+			covered := available[item.ProductID]
+			if covered > item.RemainingQty {
+				covered = item.RemainingQty
+			}
+			if covered < 0 {
+				covered = 0
+			}
+			available[item.ProductID] -= covered
+			item.BackorderedQty = item.RemainingQty - covered
+		}
+
+		switch {
+		case item.ShippedQty == 0:
+			item.Status = FulfillmentStatusPending
+		case item.RemainingQty > 0:
+			item.Status = FulfillmentStatusPartial
+		default:
+			item.Status = FulfillmentStatusFulfilled
+		}
+
+		f.TotalOrdered += item.OrderedQty
+		f.TotalShipped += item.ShippedQty
+		f.TotalDelivered += item.DeliveredQty
+		f.TotalRemaining += item.RemainingQty
+		f.TotalBackorder += item.BackorderedQty
+	}
+
+	switch {
+	case f.TotalShipped == 0:
+		f.Status = FulfillmentStatusPending
+	case f.TotalRemaining > 0:
+		f.Status = FulfillmentStatusPartial
+	default:
+		f.Status = FulfillmentStatusFulfilled
+	}
+
+	if f.TotalOrdered > 0 {
+		shippedWithinOrder := f.TotalOrdered - f.TotalRemaining
+		f.FulfillmentRate = float64(shippedWithinOrder) / float64(f.TotalOrdered) * 100
+	}
+
+	return f
+}
+
+// AllocateShipment links each new delivery item to a sales order line and checks that no line
+// is shipped beyond its ordered quantity. It returns the 1-based index of the offending item.
+func AllocateShipment(order *SalesOrder, shipped []ShippedLine, items []DeliveryItem) (int, error) {
+	f := BuildFulfillment(order, shipped, nil)
+
+	remaining := make(map[int]int, len(f.Items))
+	for _, item := range f.Items {
+		remaining[item.SOItemID] = item.RemainingQty
+	}
+
+	for i := range items {
+		item := &items[i]
+
+		if item.SOItemID == nil {
+			pos := findLineForProduct(f.Items, item.ProductID, 0)
+			// Prefere a linha com saldo suficiente para o item
+			for j, line := range f.Items {
+				if line.ProductID == item.ProductID && remaining[line.SOItemID] >= item.Quantity {
+					pos = j
+					break
+				}
+			}
+			if pos < 0 {
+				return i + 1, errors.ErrDeliveryItemNotInOrder
+			}
+			soItemID := f.Items[pos].SOItemID
+			item.SOItemID = &soItemID
+		}
+
+		left, ok := remaining[*item.SOItemID]
+		if !ok {
+			return i + 1, errors.ErrDeliveryItemNotInOrder
+		}
+		if item.Quantity > left {
+			return i + 1, errors.ErrOverShipment
+		}
+		remaining[*item.SOItemID] = left - item.Quantity
+	}
+
+	return 0, nil
+}
+
+// findLineForProduct returns the first line of the product with room for the quantity,
+// falling back to the first line of the product
+func findLineForProduct(items []SOItemFulfillment, productID, quantity int) int {
+	fallback := -1
+	for i, item := range items {
+		if item.ProductID != productID {
+			continue
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+		if item.OrderedQty-item.ShippedQty >= quantity {
+			return i
+		}
+	}
+	return fallback
+}
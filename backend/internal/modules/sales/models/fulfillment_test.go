@@ -0,0 +1,79 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int { return &v }
+
+func testOrder() *SalesOrder {
+	return &SalesOrder{
+		ID:     1,
+		SONo:   "SO-1",
+		Status: SOStatusConfirmed,
+		Items: []SOItem{
+			{ID: 10, ProductID: 100, Quantity: 10},
+			{ID: 11, ProductID: 200, Quantity: 5},
+		},
+	}
+}
+
+func TestBuildFulfillmentPartial(t *testing.T) {
+	shipped := []ShippedLine{
+		{SOItemID: intPtr(10), ProductID: 100, Quantity: 4, Delivered: true},
+		{ProductID: 100, Quantity: 2}, // entrega antiga sem vínculo com a linha
+	}
+
+	f := BuildFulfillment(testOrder(), shipped, map[int]int{100: 1, 200: 10})
+
+	require.Len(t, f.Items, 2)
+	assert.Equal(t, 6, f.Items[0].ShippedQty)
+	assert.Equal(t, 4, f.Items[0].DeliveredQty)
+	assert.Equal(t, 4, f.Items[0].RemainingQty)
+	assert.Equal(t, 3, f.Items[0].BackorderedQty, "apenas 1 unidade em estoque para 4 pendentes")
+	assert.Equal(t, FulfillmentStatusPartial, f.Items[0].Status)
+
+	assert.Equal(t, 0, f.Items[1].BackorderedQty)
+	assert.Equal(t, FulfillmentStatusPending, f.Items[1].Status)
+
+	assert.Equal(t, FulfillmentStatusPartial, f.Status)
+	assert.Equal(t, 9, f.TotalRemaining)
+	assert.InDelta(t, 40.0, f.FulfillmentRate, 0.01)
+}
+
+func TestAllocateShipmentResolvesLines(t *testing.T) {
+	items := []DeliveryItem{
+		{ProductID: 100, Quantity: 6},
+		{ProductID: 200, Quantity: 5},
+	}
+
+	index, err := AllocateShipment(testOrder(), []ShippedLine{{SOItemID: intPtr(10), ProductID: 100, Quantity: 4}}, items)
+	require.NoError(t, err)
+	assert.Equal(t, 0, index)
+	require.NotNil(t, items[0].SOItemID)
+	assert.Equal(t, 10, *items[0].SOItemID)
+	assert.Equal(t, 11, *items[1].SOItemID)
+}
+
+func TestAllocateShipmentBlocksOverShipment(t *testing.T) {
+	items := []DeliveryItem{
+		{SOItemID: intPtr(10), ProductID: 100, Quantity: 4},
+		{SOItemID: intPtr(10), ProductID: 100, Quantity: 3},
+	}
+
+	index, err := AllocateShipment(testOrder(), []ShippedLine{{SOItemID: intPtr(10), ProductID: 100, Quantity: 4}}, items)
+	assert.Equal(t, errors.ErrOverShipment, err)
+	assert.Equal(t, 2, index)
+}
+
+func TestAllocateShipmentRejectsUnknownProduct(t *testing.T) {
+	items := []DeliveryItem{{ProductID: 999, Quantity: 1}}
+
+	index, err := AllocateShipment(testOrder(), nil, items)
+	assert.Equal(t, errors.ErrDeliveryItemNotInOrder, err)
+	assert.Equal(t, 1, index)
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"sort"
+	"time"
+)
+
+// Uma fatura pode ser dividida em parcelas, cada uma com vencimento, valor e cobrança (boleto e
+// Pix) próprios. O valor pago da fatura quita as parcelas da mais antiga para a mais nova, e o
+// status da fatura passa a ser derivado do estado das parcelas.
+
+const (
+	InstallmentStatusOpen    = "open"
+	InstallmentStatusPartial = "partial"
+	InstallmentStatusPaid    = "paid"
+	InstallmentStatusOverdue = "overdue"
+
+	// MaxInstallments é o maior número de parcelas de uma fatura
+	MaxInstallments = 120
+)
+
+// InvoiceInstallment é uma parcela da fatura
+type InvoiceInstallment struct {
+	ID         int           `json:"id" gorm:"primaryKey"`
+	CompanyID  int           `json:"company_id" gorm:"<-:create"`
+	InvoiceID  int           `json:"invoice_id" gorm:"index"`
+	Number     int           `json:"number"`
+	DueDate    time.Time     `json:"due_date"`
+	Amount     money.Decimal `json:"amount"`
+	AmountPaid money.Decimal `json:"amount_paid" gorm:"default:0"`
+	Status     string        `json:"status" gorm:"default:open"`
+
+	// Cobrança gerada para a parcela
+	BoletoOurNumber     string `json:"boleto_our_number,omitempty"`
+	BoletoBarcode       string `json:"boleto_barcode,omitempty"`
+	BoletoDigitableLine string `json:"boleto_digitable_line,omitempty"`
+	PixPayload          string `json:"pix_payload,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// InstallmentSpec é uma parcela informada explicitamente no plano
+type InstallmentSpec struct {
+	DueDate time.Time
+	Amount  money.Decimal
+}
+
+// InstallmentPlan descreve a divisão da fatura: parcelas explícitas, parcelas iguais nos
+// vencimentos de DueDates ou Count parcelas iguais a partir de FirstDueDate (padrão: o
+// vencimento da fatura), a cada IntervalDays dias ou, sem intervalo, no mesmo dia dos meses
+// seguintes
+type InstallmentPlan struct {
+	Count        int
+	FirstDueDate time.Time
+	IntervalDays int
+	DueDates     []time.Time
+	Installments []InstallmentSpec
+}
+
+// BuildInstallments monta as parcelas da fatura pelo plano. Nas parcelas iguais, os centavos
+// que sobram da divisão vão para a primeira; nas explícitas, a soma precisa bater com o total.
+func BuildInstallments(invoice *Invoice, plan InstallmentPlan) ([]InvoiceInstallment, error) {
+	if !invoice.GrandTotal.IsPositive() {
+		return nil, errors.ErrInvalidInstallmentPlan
+	}
+
+	specs := plan.Installments
+	if len(specs) == 0 {
+		var err error
+		if specs, err = equalInstallments(invoice, plan); err != nil {
+			return nil, err
+		}
+	}
+	if len(specs) > MaxInstallments {
+		return nil, errors.ErrInvalidInstallmentPlan
+	}
+
+	sort.SliceStable(specs, func(i, j int) bool { return specs[i].DueDate.Before(specs[j].DueDate) })
+	total := money.Zero
+	installments := make([]InvoiceInstallment, 0, len(specs))
+	for i, spec := range specs {
+		if !spec.Amount.IsPositive() || spec.DueDate.IsZero() {
+			return nil, errors.ErrInvalidInstallmentPlan
+		}
+		total = total.Add(spec.Amount)
+		installments = append(installments, InvoiceInstallment{
+			InvoiceID: invoice.ID,
+			Number:    i + 1,
+			DueDate:   spec.DueDate,
+			Amount:    spec.Amount,
+			Status:    InstallmentStatusOpen,
+		})
+	}
+	if !total.Equal(invoice.GrandTotal) {
+		return nil, errors.ErrInstallmentsMismatch
+	}
+	return installments, nil
+}
+
+// equalInstallments divide o total da fatura em plan.Count parcelas
+func equalInstallments(invoice *Invoice, plan InstallmentPlan) ([]InstallmentSpec, error) {
+	if len(plan.DueDates) > 0 {
+		plan.Count = len(plan.DueDates)
+	}
+	if plan.Count < 1 || plan.Count > MaxInstallments || plan.IntervalDays < 0 {
+		return nil, errors.ErrInvalidInstallmentPlan
+	}
+	first := plan.FirstDueDate
+	if first.IsZero() {
+		first = invoice.DueDate
+	}
+	if first.IsZero() {
+		return nil, errors.ErrInvalidInstallmentPlan
+	}
+
+	cents := invoice.GrandTotal.Round(2).Cents()
+	share := cents / int64(plan.Count)
+	remainder := cents - share*int64(plan.Count)
+	if share <= 0 {
+		return nil, errors.ErrInvalidInstallmentPlan
+	}
+
+	specs := make([]InstallmentSpec, plan.Count)
+	for i := range specs {
+		amount := share
+		if i == 0 {
+			amount += remainder
+		}
+		due := first.AddDate(0, i, 0)
+		switch {
+		case len(plan.DueDates) > 0:
+			due = plan.DueDates[i]
+		case plan.IntervalDays > 0:
+			due = first.AddDate(0, 0, i*plan.IntervalDays)
+		}
+		specs[i] = InstallmentSpec{DueDate: due, Amount: money.FromCents(amount)}
+	}
+	return specs, nil
+}
+
+// DistributeInstallmentPayments reparte o valor pago da fatura entre as parcelas, da que vence
+// primeiro para a última, e atualiza o status de cada uma na data today (dia da empresa)
+func DistributeInstallmentPayments(installments []InvoiceInstallment, amountPaid money.Decimal, today time.Time) {
+	sort.SliceStable(installments, func(i, j int) bool { return installments[i].Number < installments[j].Number })
+	remaining := amountPaid
+	for i := range installments {
+		installment := &installments[i]
+		paid := money.Max(money.Min(remaining, installment.Amount), money.Zero)
+		remaining = remaining.Sub(paid)
+		installment.AmountPaid = paid
+
+		switch {
+		case paid.GreaterThanOrEqual(installment.Amount):
+			installment.Status = InstallmentStatusPaid
+		case installment.DueDate.Before(today):
+			installment.Status = InstallmentStatusOverdue
+		case paid.IsPositive():
+			installment.Status = InstallmentStatusPartial
+		default:
+			installment.Status = InstallmentStatusOpen
+		}
+	}
+}
+
+// InstallmentsInvoiceStatus é o status da fatura derivado das parcelas: paga com todas pagas,
+// vencida com alguma vencida, parcial com alguma paga e, sem pagamento, o status atual (enviada
+// se estava paga, parcial ou vencida). Sem parcelas, o status atual não muda.
+func InstallmentsInvoiceStatus(installments []InvoiceInstallment, current string) string {
+	if len(installments) == 0 || current == InvoiceStatusCancelled || current == InvoiceStatusDraft {
+		return current
+	}
+
+	paid, started := 0, false
+	for _, installment := range installments {
+		switch installment.Status {
+		case InstallmentStatusOverdue:
+			return InvoiceStatusOverdue
+		case InstallmentStatusPaid:
+			paid++
+			started = true
+		case InstallmentStatusPartial:
+			started = true
+		}
+	}
+	switch {
+	case paid == len(installments):
+		return InvoiceStatusPaid
+	case started:
+		return InvoiceStatusPartial
+	case current == InvoiceStatusPaid || current == InvoiceStatusPartial || current == InvoiceStatusOverdue:
+		return InvoiceStatusSent
+	}
+	return current
+}
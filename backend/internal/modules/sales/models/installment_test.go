@@ -0,0 +1,114 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBuildInstallmentsSplitsEquallyMonthByMonth(t *testing.T) {
+	invoice := &Invoice{ID: 9, GrandTotal: money.FromCents(10000), DueDate: date(2026, 1, 31)}
+
+	installments, err := BuildInstallments(invoice, InstallmentPlan{Count: 3})
+	require.NoError(t, err)
+	require.Len(t, installments, 3)
+
+	assert.Equal(t, "33.34", installments[0].Amount.StringFixed(2), "centavos da divisão vão para a primeira")
+	assert.Equal(t, "33.33", installments[1].Amount.StringFixed(2))
+	assert.Equal(t, "33.33", installments[2].Amount.StringFixed(2))
+	assert.Equal(t, date(2026, 1, 31), installments[0].DueDate)
+	assert.Equal(t, date(2026, 3, 3), installments[1].DueDate)
+	for i, installment := range installments {
+		assert.Equal(t, i+1, installment.Number)
+		assert.Equal(t, 9, installment.InvoiceID)
+		assert.Equal(t, InstallmentStatusOpen, installment.Status)
+	}
+}
+
+func TestBuildInstallmentsWithIntervalDays(t *testing.T) {
+	invoice := &Invoice{GrandTotal: money.FromInt(90)}
+	plan := InstallmentPlan{Count: 3, FirstDueDate: date(2026, 5, 1), IntervalDays: 30}
+
+	installments, err := BuildInstallments(invoice, plan)
+	require.NoError(t, err)
+	assert.Equal(t, date(2026, 5, 31), installments[1].DueDate)
+	assert.Equal(t, date(2026, 6, 30), installments[2].DueDate)
+}
+
+func TestBuildInstallmentsExplicitPlanIsSortedAndMustMatchTotal(t *testing.T) {
+	invoice := &Invoice{GrandTotal: money.FromInt(100)}
+	plan := InstallmentPlan{Installments: []InstallmentSpec{
+		{DueDate: date(2026, 7, 1), Amount: money.FromInt(70)},
+		{DueDate: date(2026, 6, 1), Amount: money.FromInt(30)},
+	}}
+
+	installments, err := BuildInstallments(invoice, plan)
+	require.NoError(t, err)
+	assert.Equal(t, date(2026, 6, 1), installments[0].DueDate)
+	assert.Equal(t, 1, installments[0].Number)
+
+	plan.Installments[0].Amount = money.FromInt(60)
+	_, err = BuildInstallments(invoice, plan)
+	assert.ErrorIs(t, err, errors.ErrInstallmentsMismatch)
+}
+
+func TestBuildInstallmentsRejectsInvalidPlans(t *testing.T) {
+	invoice := &Invoice{GrandTotal: money.FromInt(100), DueDate: date(2026, 6, 1)}
+	cases := map[string]InstallmentPlan{
+		"sem parcelas":       {},
+		"parcelas demais":    {Count: MaxInstallments + 1},
+		"intervalo negativo": {Count: 2, IntervalDays: -1},
+		"valor zero":         {Installments: []InstallmentSpec{{DueDate: date(2026, 6, 1)}}},
+		"sem vencimento":     {Installments: []InstallmentSpec{{Amount: money.FromInt(100)}}},
+	}
+	for name, plan := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := BuildInstallments(invoice, plan)
+			assert.ErrorIs(t, err, errors.ErrInvalidInstallmentPlan)
+		})
+	}
+
+	_, err := BuildInstallments(&Invoice{GrandTotal: money.FromCents(2)}, InstallmentPlan{Count: 3, FirstDueDate: date(2026, 6, 1)})
+	assert.ErrorIs(t, err, errors.ErrInvalidInstallmentPlan, "parcela menor que um centavo")
+}
+
+func TestDistributeInstallmentPaymentsOldestFirst(t *testing.T) {
+	installments := []InvoiceInstallment{
+		{Number: 1, DueDate: date(2026, 3, 1), Amount: money.FromInt(50)},
+		{Number: 2, DueDate: date(2026, 4, 1), Amount: money.FromInt(50)},
+		{Number: 3, DueDate: date(2026, 5, 1), Amount: money.FromInt(50)},
+	}
+
+	DistributeInstallmentPayments(installments, money.FromInt(70), date(2026, 4, 15))
+
+	assert.Equal(t, InstallmentStatusPaid, installments[0].Status)
+	assert.Equal(t, InstallmentStatusOverdue, installments[1].Status, "venceu com saldo em aberto")
+	assert.Equal(t, "20.00", installments[1].AmountPaid.StringFixed(2))
+	assert.Equal(t, InstallmentStatusOpen, installments[2].Status)
+	assert.True(t, installments[2].AmountPaid.IsZero())
+	assert.Equal(t, InvoiceStatusOverdue, InstallmentsInvoiceStatus(installments, InvoiceStatusSent))
+
+	DistributeInstallmentPayments(installments, money.FromInt(120), date(2026, 4, 15))
+	assert.Equal(t, InstallmentStatusPartial, installments[2].Status)
+	assert.Equal(t, InvoiceStatusPartial, InstallmentsInvoiceStatus(installments, InvoiceStatusOverdue))
+
+	DistributeInstallmentPayments(installments, money.FromInt(150), date(2026, 4, 15))
+	assert.Equal(t, InvoiceStatusPaid, InstallmentsInvoiceStatus(installments, InvoiceStatusPartial))
+}
+
+func TestInstallmentsInvoiceStatusKeepsCurrentWithoutPayments(t *testing.T) {
+	installments := []InvoiceInstallment{{Number: 1, Status: InstallmentStatusOpen}}
+
+	assert.Equal(t, InvoiceStatusSent, InstallmentsInvoiceStatus(installments, InvoiceStatusSent))
+	assert.Equal(t, InvoiceStatusSent, InstallmentsInvoiceStatus(installments, InvoiceStatusPaid), "estorno volta a enviada")
+	assert.Equal(t, InvoiceStatusCancelled, InstallmentsInvoiceStatus(installments, InvoiceStatusCancelled))
+	assert.Equal(t, InvoiceStatusDraft, InstallmentsInvoiceStatus(nil, InvoiceStatusDraft))
+}
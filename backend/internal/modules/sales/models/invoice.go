@@ -3,49 +3,71 @@ package models
 import (
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Invoice represents an invoice to a client
 type Invoice struct {
-	ID            int       `json:"id" gorm:"primaryKey"`
-	InvoiceNo     string    `json:"invoice_no" validate:"required" gorm:"uniqueIndex"`
-	SalesOrderID  int       `json:"sales_order_id" gorm:"index"`
-	SONo          string    `json:"so_no"`
-	ContactID     int       `json:"contact_id" validate:"required" gorm:"index"`
-	Status        string    `json:"status" validate:"required" gorm:"default:draft"`
-	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	IssueDate     time.Time `json:"issue_date"`
-	DueDate       time.Time `json:"due_date" validate:"required"`
-	SubTotal      float64   `json:"subtotal" gorm:"column:subtotal"`
-	TaxTotal      float64   `json:"tax_total" gorm:"column:tax_total"`
-	DiscountTotal float64   `json:"discount_total" gorm:"column:discount_total"`
-	GrandTotal    float64   `json:"grand_total" gorm:"column:grand_total"`
-	AmountPaid    float64   `json:"amount_paid" gorm:"default:0"`
-	PaymentTerms  string    `json:"payment_terms"`
-	Notes         string    `json:"notes"`
+	ID            int    `json:"id" gorm:"primaryKey"`
+	CompanyID     int    `json:"company_id" gorm:"<-:create"`
+	InvoiceNo     string `json:"invoice_no" validate:"required" gorm:"uniqueIndex"`
+	SalesOrderID  int    `json:"sales_order_id" gorm:"index"`
+	SONo          string `json:"so_no"`
+	ContactID     int    `json:"contact_id" validate:"required" gorm:"index"`
+	SalespersonID *int   `json:"salesperson_id,omitempty" gorm:"index"`
+	CostCenterID  *int   `json:"cost_center_id,omitempty"`
+	Status        string `json:"status" validate:"required" gorm:"default:draft"`
+	// Kind distingue a fatura de adiantamento do pedido (advance) das faturas dos itens (standard)
+	Kind          string         `json:"kind" gorm:"default:standard"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	IssueDate     time.Time      `json:"issue_date"`
+	DueDate       time.Time      `json:"due_date" validate:"required"`
+	SubTotal      money.Decimal  `json:"subtotal" gorm:"column:subtotal"`
+	TaxTotal      money.Decimal  `json:"tax_total" gorm:"column:tax_total"`
+	DiscountTotal money.Decimal  `json:"discount_total" gorm:"column:discount_total"`
+	GrandTotal    money.Decimal  `json:"grand_total" gorm:"column:grand_total"`
+	AmountPaid    money.Decimal  `json:"amount_paid" gorm:"default:0"`
+	PaymentTerms  string         `json:"payment_terms"`
+	Notes         string         `json:"notes"`
+	// Desconto por pagamento antecipado da condição do contato, válido até EarlyDiscountUntil
+	EarlyDiscountAmount money.Decimal `json:"early_discount_amount" gorm:"default:0"`
+	EarlyDiscountUntil  *time.Time    `json:"early_discount_until,omitempty" gorm:"type:date"`
+	// Adiantamento do pedido abatido desta fatura; GrandTotal já vem líquido do abatimento
+	AdvanceDeduction money.Decimal `json:"advance_deduction" gorm:"default:0"`
+	// Endereço de cobrança do catálogo do contato, herdado do pedido de venda
+	BillingAddressID *int   `json:"billing_address_id,omitempty"`
+	BillingAddress   string `json:"billing_address"`
 
 	// Relationships
 	Contact    *contact.Contact `json:"contact,omitempty" gorm:"foreignKey:ContactID"`
 	SalesOrder *SalesOrder      `json:"sales_order,omitempty" gorm:"foreignKey:SalesOrderID"`
 	Items      []InvoiceItem    `json:"items,omitempty" gorm:"foreignKey:InvoiceID"`
 	Payments   []Payment        `json:"payments,omitempty" gorm:"foreignKey:InvoiceID"`
+	// Parcelas da fatura, quando dividida (ver installment.go)
+	Installments []InvoiceInstallment `json:"installments,omitempty" gorm:"foreignKey:InvoiceID"`
 }
 
 // InvoiceItem represents items in an invoice
 type InvoiceItem struct {
-	ID          int     `json:"id" gorm:"primaryKey"`
-	InvoiceID   int     `json:"invoice_id" gorm:"index"`
-	ProductID   int     `json:"product_id" validate:"required" gorm:"index"`
-	ProductName string  `json:"product_name"`
-	ProductCode string  `json:"product_code"`
-	Description string  `json:"description"`
-	Quantity    int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
-	Discount    float64 `json:"discount" gorm:"default:0"`
-	Tax         float64 `json:"tax" gorm:"default:0"`
-	Total       float64 `json:"total"`
+	ID           int           `json:"id" gorm:"primaryKey"`
+	InvoiceID    int           `json:"invoice_id" gorm:"index"`
+	ProductID    int           `json:"product_id" validate:"required" gorm:"index"`
+	KitProductID *int          `json:"kit_product_id,omitempty"`
+	ProductName  string        `json:"product_name"`
+	ProductCode  string        `json:"product_code"`
+	Description  string        `json:"description"`
+	Quantity     int           `json:"quantity" validate:"required,gt=0"`
+	Unit         string        `json:"unit" gorm:"default:UN"`
+	UnitFactor   int           `json:"unit_factor" gorm:"default:1"`
+	UnitPrice    money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	Discount     money.Decimal `json:"discount" gorm:"default:0"`
+	Tax          money.Decimal `json:"tax" gorm:"default:0"`
+	Total        money.Decimal `json:"total"`
 
 	// Relationships
 	Product *product.Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Uma contestação registra a discordância do cliente (ou do contas a receber) sobre parte do valor
+// de uma fatura ou de uma parcela. Enquanto aberta, a fatura fica suspensa da cobrança e o valor
+// contestado sai dos indicadores de vencidos; a resolução registra a ação tomada.
+
+const (
+	DisputeStatusOpen     = "open"
+	DisputeStatusResolved = "resolved"
+
+	// Quem abriu a contestação: o próprio cliente ou o contas a receber
+	DisputeRaisedByCustomer = "customer"
+	DisputeRaisedByAR       = "ar"
+
+	// Ações de resolução
+	DisputeResolutionCreditNote = "credit_note"
+	DisputeResolutionDueDate    = "adjusted_due_date"
+	DisputeResolutionWriteOff   = "write_off"
+)
+
+// InvoiceDispute é a contestação de uma fatura ou, com InstallmentID, de uma das parcelas
+type InvoiceDispute struct {
+	ID                int           `json:"id" gorm:"primaryKey"`
+	CompanyID         int           `json:"company_id" gorm:"<-:create"`
+	InvoiceID         int           `json:"invoice_id"`
+	InstallmentID     *int          `json:"installment_id,omitempty"`
+	RaisedBy          string        `json:"raised_by"`
+	Reason            string        `json:"reason"`
+	Amount            money.Decimal `json:"amount"`
+	Status            string        `json:"status" gorm:"default:open"`
+	OpenedBy          string        `json:"opened_by,omitempty"`
+	Resolution        string        `json:"resolution,omitempty"`
+	ResolutionAmount  money.Decimal `json:"resolution_amount,omitzero"`
+	CreditNoteID      *int          `json:"credit_note_id,omitempty"`
+	NewDueDate        *time.Time    `json:"new_due_date,omitempty"`
+	ResolutionNotes   string        `json:"resolution_notes,omitempty"`
+	ResolvedBy        string        `json:"resolved_by,omitempty"`
+	ResolvedAt        *time.Time    `json:"resolved_at,omitempty"`
+	CreatedAt         time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	InstallmentNumber int           `json:"installment_number,omitempty" gorm:"->;-:migration"`
+}
+
+// TableName define o nome da tabela das contestações
+func (InvoiceDispute) TableName() string {
+	return "invoice_disputes"
+}
+
+// InvoiceDisputeInput é o corpo aceito em POST /invoices/:id/disputes; com installment_number, a
+// contestação é da parcela
+type InvoiceDisputeInput struct {
+	InstallmentNumber *int          `json:"installment_number"`
+	RaisedBy          string        `json:"raised_by" binding:"required"`
+	Reason            string        `json:"reason" binding:"required"`
+	Amount            money.Decimal `json:"amount"`
+}
+
+// DisputeResolutionInput é o corpo aceito em POST /invoices/:id/disputes/:dispute_id/resolve. Sem
+// amount, a nota de crédito e a baixa são do valor contestado.
+type DisputeResolutionInput struct {
+	Action  string         `json:"action" binding:"required"`
+	Amount  *money.Decimal `json:"amount"`
+	DueDate *time.Time     `json:"due_date"`
+	Notes   string         `json:"notes"`
+}
+
+// DisputeFilter filtra a listagem das contestações
+type DisputeFilter struct {
+	Status    string
+	InvoiceID int
+}
+
+// ToDispute valida a contestação contra o saldo em aberto da fatura (ou da parcela) e monta o
+// registro aberto
+func (in InvoiceDisputeInput) ToDispute(invoiceID int, installmentID *int, openBalance money.Decimal, username string) (*InvoiceDispute, error) {
+	raisedBy := strings.ToLower(strings.TrimSpace(in.RaisedBy))
+	reason := strings.TrimSpace(in.Reason)
+	if (raisedBy != DisputeRaisedByCustomer && raisedBy != DisputeRaisedByAR) || reason == "" ||
+		!in.Amount.IsPositive() || in.Amount.GreaterThan(openBalance) {
+		return nil, errors.ErrInvalidInvoiceDispute
+	}
+	return &InvoiceDispute{
+		InvoiceID:     invoiceID,
+		InstallmentID: installmentID,
+		RaisedBy:      raisedBy,
+		Reason:        reason,
+		Amount:        money.Round(in.Amount),
+		Status:        DisputeStatusOpen,
+		OpenedBy:      username,
+	}, nil
+}
+
+// Resolve confere a ação e aplica a resolução à contestação aberta. O novo vencimento é um dia
+// a partir de today (o dia da empresa); a nota de crédito e a baixa valem até o valor contestado.
+func (d *InvoiceDispute) Resolve(in DisputeResolutionInput, username string, today, now time.Time) error {
+	if d.Status != DisputeStatusOpen {
+		return errors.ErrInvoiceDisputeClosed
+	}
+
+	action := strings.ToLower(strings.TrimSpace(in.Action))
+	switch action {
+	case DisputeResolutionCreditNote, DisputeResolutionWriteOff:
+		amount := d.Amount
+		if in.Amount != nil {
+			amount = money.Round(*in.Amount)
+		}
+		if !amount.IsPositive() || amount.GreaterThan(d.Amount) {
+			return errors.ErrInvalidDisputeResolution
+		}
+		d.ResolutionAmount = amount
+	case DisputeResolutionDueDate:
+		if in.DueDate == nil {
+			return errors.ErrInvalidDisputeResolution
+		}
+		due := time.Date(in.DueDate.Year(), in.DueDate.Month(), in.DueDate.Day(), 0, 0, 0, 0, today.Location())
+		if due.Before(today) {
+			return errors.ErrInvalidDisputeResolution
+		}
+		d.NewDueDate = &due
+	default:
+		return errors.ErrInvalidDisputeResolution
+	}
+
+	d.Status = DisputeStatusResolved
+	d.Resolution = action
+	d.ResolutionNotes = strings.TrimSpace(in.Notes)
+	d.ResolvedBy = username
+	d.ResolvedAt = &now
+	return nil
+}
+
+// CreditNote monta a nota de crédito da resolução, contra a fatura contestada
+func (d *InvoiceDispute) CreditNote(invoice *Invoice, number string, issueDate time.Time) CreditNote {
+	return CreditNote{
+		CreditNoteNo: number,
+		InvoiceID:    invoice.ID,
+		ContactID:    invoice.ContactID,
+		CostCenterID: invoice.CostCenterID,
+		Status:       CreditNoteStatusIssued,
+		IssueDate:    issueDate,
+		Amount:       d.ResolutionAmount,
+		Reason:       fmt.Sprintf("Contestação da fatura %s: %s", invoice.InvoiceNo, d.Reason),
+	}
+}
+
+// DisputedAmountSQL é a expressão SQL do valor contestado da fatura do alias informado: o das
+// contestações abertas e o baixado nas resolvidas. Os indicadores de vencidos descontam esse valor
+// do saldo em aberto; a nota de crédito emitida já aparece nos créditos do cliente.
+func DisputedAmountSQL(alias string) string {
+	return fmt.Sprintf(`(SELECT COALESCE(SUM(CASE WHEN d.status = '%s' THEN d.amount
+		WHEN d.resolution = '%s' THEN d.resolution_amount ELSE 0 END), 0)
+		FROM invoice_disputes d WHERE d.invoice_id = %s.id)`, DisputeStatusOpen, DisputeResolutionWriteOff, alias)
+}
+
+// OpenDisputeSQL é a condição SQL das faturas do alias informado com contestação aberta, que
+// ficam suspensas da cobrança
+func OpenDisputeSQL(alias string) string {
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM invoice_disputes d WHERE d.invoice_id = %s.id AND d.status = '%s')`,
+		alias, DisputeStatusOpen)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func TestInvoiceDisputeInputToDispute(t *testing.T) {
+	balance := money.FromInt(300)
+	installmentID := 7
+
+	dispute, err := InvoiceDisputeInput{RaisedBy: " Customer ", Reason: " Item avariado ", Amount: money.MustParse("120.50")}.
+		ToDispute(4, &installmentID, balance, "ana")
+	if err != nil {
+		t.Fatalf("Contestação válida recusada: %v", err)
+	}
+	if dispute.RaisedBy != DisputeRaisedByCustomer || dispute.Reason != "Item avariado" || dispute.Amount.String() != "120.50" ||
+		dispute.Status != DisputeStatusOpen || *dispute.InstallmentID != 7 || dispute.OpenedBy != "ana" {
+		t.Errorf("Contestação inesperada: %+v", dispute)
+	}
+
+	invalid := []InvoiceDisputeInput{
+		{RaisedBy: "vendor", Reason: "Preço", Amount: money.FromInt(10)},
+		{RaisedBy: DisputeRaisedByAR, Reason: "  ", Amount: money.FromInt(10)},
+		{RaisedBy: DisputeRaisedByAR, Reason: "Preço", Amount: money.Zero},
+		{RaisedBy: DisputeRaisedByAR, Reason: "Preço", Amount: money.FromInt(301)},
+	}
+	for _, in := range invalid {
+		if _, err := in.ToDispute(4, nil, balance, "ana"); !stderrors.Is(err, errors.ErrInvalidInvoiceDispute) {
+			t.Errorf("%+v: esperado ErrInvalidInvoiceDispute, obtido %v", in, err)
+		}
+	}
+}
+
+func TestInvoiceDisputeResolve(t *testing.T) {
+	today := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	now := today.Add(14 * time.Hour)
+	open := func() *InvoiceDispute {
+		return &InvoiceDispute{ID: 1, InvoiceID: 4, Reason: "Preço", Amount: money.FromInt(100), Status: DisputeStatusOpen}
+	}
+
+	dispute := open()
+	if err := dispute.Resolve(DisputeResolutionInput{Action: "credit_note", Notes: " acordo "}, "bia", today, now); err != nil {
+		t.Fatalf("Nota de crédito recusada: %v", err)
+	}
+	if dispute.Status != DisputeStatusResolved || dispute.ResolutionAmount.String() != "100.00" ||
+		dispute.ResolutionNotes != "acordo" || dispute.ResolvedBy != "bia" || !dispute.ResolvedAt.Equal(now) {
+		t.Errorf("Sem amount, a nota é do valor contestado: %+v", dispute)
+	}
+
+	partial := money.FromInt(40)
+	dispute = open()
+	if err := dispute.Resolve(DisputeResolutionInput{Action: DisputeResolutionWriteOff, Amount: &partial}, "bia", today, now); err != nil ||
+		dispute.ResolutionAmount.String() != "40.00" {
+		t.Errorf("Baixa parcial: %+v, %v", dispute, err)
+	}
+
+	due := time.Date(2026, 6, 1, 18, 30, 0, 0, time.FixedZone("BRT", -3*3600))
+	dispute = open()
+	if err := dispute.Resolve(DisputeResolutionInput{Action: DisputeResolutionDueDate, DueDate: &due}, "bia", today, now); err != nil ||
+		!dispute.NewDueDate.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)) || dispute.ResolutionAmount.IsPositive() {
+		t.Errorf("Novo vencimento é o dia informado: %+v, %v", dispute, err)
+	}
+
+	over, zero := money.FromInt(101), money.Zero
+	yesterday := today.AddDate(0, 0, -1)
+	invalid := []DisputeResolutionInput{
+		{Action: "refund"},
+		{Action: DisputeResolutionCreditNote, Amount: &over},
+		{Action: DisputeResolutionWriteOff, Amount: &zero},
+		{Action: DisputeResolutionDueDate},
+		{Action: DisputeResolutionDueDate, DueDate: &yesterday},
+	}
+	for _, in := range invalid {
+		dispute := open()
+		if err := dispute.Resolve(in, "bia", today, now); !stderrors.Is(err, errors.ErrInvalidDisputeResolution) || dispute.Status != DisputeStatusOpen {
+			t.Errorf("%+v: esperado ErrInvalidDisputeResolution, obtido %v", in, err)
+		}
+	}
+
+	dispute = open()
+	dispute.Status = DisputeStatusResolved
+	if err := dispute.Resolve(DisputeResolutionInput{Action: DisputeResolutionWriteOff}, "bia", today, now); !stderrors.Is(err, errors.ErrInvoiceDisputeClosed) {
+		t.Errorf("Contestação resolvida não é resolvida de novo: %v", err)
+	}
+}
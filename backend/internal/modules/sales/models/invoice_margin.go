@@ -0,0 +1,174 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Origem do custo das linhas na conferência da margem
+const (
+	MarginCostCOGS     = "cogs"
+	MarginCostEstimate = "estimate"
+)
+
+// InvoiceMarginApproval libera a contabilização da fatura com margem abaixo do mínimo das
+// categorias. MarginPercent é a margem da fatura no momento da liberação.
+type InvoiceMarginApproval struct {
+	ID            int       `json:"id" gorm:"primaryKey"`
+	CompanyID     int       `json:"company_id" gorm:"<-:create"`
+	InvoiceID     int       `json:"invoice_id"`
+	ApprovedBy    string    `json:"approved_by"`
+	Reason        string    `json:"reason"`
+	MarginPercent float64   `json:"margin_percent"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName define o nome da tabela de liberações de margem
+func (InvoiceMarginApproval) TableName() string {
+	return "invoice_margin_approvals"
+}
+
+// MarginApprovalInput traz o motivo da liberação (ex.: queima de estoque, acordo comercial)
+type MarginApprovalInput struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// LineCost é o custo de uma linha da fatura: o CMV já apurado para a fatura ou, sem ele, a
+// estimativa pelo custeio do estoque
+type LineCost struct {
+	Cost   money.Decimal
+	Source string
+}
+
+// InvoiceMarginLine é a margem de uma linha da fatura; a receita é o bruto menos o desconto, sem
+// impostos
+type InvoiceMarginLine struct {
+	ItemID      int    `json:"item_id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	CategoryID  *int   `json:"category_id,omitempty"`
+	Quantity    int    `json:"quantity"`
+	CostSource  string `json:"cost_source"`
+	MarginSummary
+}
+
+// CategoryMargin é a margem das linhas de uma categoria com margem mínima, própria ou herdada
+type CategoryMargin struct {
+	CategoryID       int     `json:"category_id"`
+	CategoryName     string  `json:"category_name"`
+	MinMarginPercent float64 `json:"min_margin_percent"`
+	SourceCategoryID int     `json:"source_category_id"`
+	BelowMinimum     bool    `json:"below_minimum"`
+	MarginSummary
+}
+
+// InvoiceMarginCheck é a conferência da margem da fatura por categoria. Blocked indica que a
+// fatura tem categoria abaixo do mínimo e não tem liberação.
+type InvoiceMarginCheck struct {
+	InvoiceID    int                    `json:"invoice_id"`
+	InvoiceNo    string                 `json:"invoice_no"`
+	Lines        []InvoiceMarginLine    `json:"lines"`
+	Categories   []CategoryMargin       `json:"categories"`
+	Total        MarginSummary          `json:"total"`
+	BelowMinimum bool                   `json:"below_minimum"`
+	Approval     *InvoiceMarginApproval `json:"approval,omitempty"`
+	Blocked      bool                   `json:"blocked"`
+}
+
+// BuildInvoiceMarginCheck calcula a margem de cada linha com o custo informado (na ordem dos
+// itens) e soma as linhas por categoria do produto. Só as categorias com margem mínima são
+// conferidas; produtos sem categoria ou de categorias sem mínimo entram apenas no total.
+func BuildInvoiceMarginCheck(invoice *Invoice, costs []LineCost, categoryOf map[int]int, minMargins map[int]product.MinMargin, approval *InvoiceMarginApproval) *InvoiceMarginCheck {
+	check := &InvoiceMarginCheck{
+		InvoiceID:  invoice.ID,
+		InvoiceNo:  invoice.InvoiceNo,
+		Lines:      make([]InvoiceMarginLine, 0, len(invoice.Items)),
+		Categories: make([]CategoryMargin, 0),
+		Approval:   approval,
+	}
+
+	type totals struct{ revenue, cost money.Decimal }
+	byCategory := make(map[int]*totals)
+	var revenue, cost money.Decimal
+	for i, item := range invoice.Items {
+		lineCost := LineCost{Source: MarginCostEstimate}
+		if i < len(costs) {
+			lineCost = costs[i]
+		}
+		line := InvoiceMarginLine{
+			ItemID:        item.ID,
+			ProductID:     item.ProductID,
+			ProductName:   item.ProductName,
+			Quantity:      item.Quantity,
+			CostSource:    lineCost.Source,
+			MarginSummary: newMarginSummary(LineTotal(item.Quantity, item.UnitPrice, item.Discount, money.Zero), lineCost.Cost),
+		}
+		if categoryID, ok := categoryOf[item.ProductID]; ok {
+			line.CategoryID = &categoryID
+			if _, checked := minMargins[categoryID]; checked {
+				acc := byCategory[categoryID]
+				if acc == nil {
+					acc = &totals{}
+					byCategory[categoryID] = acc
+				}
+				acc.revenue = acc.revenue.Add(line.Revenue)
+				acc.cost = acc.cost.Add(line.Cost)
+			}
+		}
+		revenue = revenue.Add(line.Revenue)
+		cost = cost.Add(line.Cost)
+		check.Lines = append(check.Lines, line)
+	}
+	check.Total = newMarginSummary(revenue, cost)
+
+	for categoryID, acc := range byCategory {
+		minMargin := minMargins[categoryID]
+		category := CategoryMargin{
+			CategoryID:       categoryID,
+			CategoryName:     minMargin.CategoryName,
+			MinMarginPercent: minMargin.MinMarginPercent,
+			SourceCategoryID: minMargin.SourceCategoryID,
+			MarginSummary:    newMarginSummary(acc.revenue, acc.cost),
+		}
+		category.BelowMinimum = category.belowMinimum()
+		check.BelowMinimum = check.BelowMinimum || category.BelowMinimum
+		check.Categories = append(check.Categories, category)
+	}
+	sort.Slice(check.Categories, func(i, j int) bool {
+		if check.Categories[i].CategoryName != check.Categories[j].CategoryName {
+			return check.Categories[i].CategoryName < check.Categories[j].CategoryName
+		}
+		return check.Categories[i].CategoryID < check.Categories[j].CategoryID
+	})
+	check.Blocked = check.BelowMinimum && approval == nil
+	return check
+}
+
+// belowMinimum compara a margem com o mínimo; sem receita, qualquer custo fica abaixo
+func (c CategoryMargin) belowMinimum() bool {
+	if !c.Revenue.IsPositive() {
+		return c.Cost.IsPositive()
+	}
+	return c.MarginPercent < c.MinMarginPercent
+}
+
+// Err devolve ErrMarginBelowMinimum com as categorias abaixo do mínimo quando a fatura está
+// bloqueada
+func (c *InvoiceMarginCheck) Err() error {
+	if !c.Blocked {
+		return nil
+	}
+	var details []string
+	for _, category := range c.Categories {
+		if category.BelowMinimum {
+			details = append(details, fmt.Sprintf("%s com margem de %.2f%% (mínimo %.2f%%)",
+				category.CategoryName, category.MarginPercent, category.MinMarginPercent))
+		}
+	}
+	return fmt.Errorf("%w: fatura %s: %s", errors.ErrMarginBelowMinimum, c.InvoiceNo, strings.Join(details, "; "))
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"strings"
+	"testing"
+)
+
+func marginInvoice() *Invoice {
+	return &Invoice{ID: 4, InvoiceNo: "INV-2026-000004", Items: []InvoiceItem{
+		{ID: 1, ProductID: 10, ProductName: "Cabo HDMI", Quantity: 10, UnitPrice: money.FromInt(20), Discount: money.FromInt(20), Tax: money.FromInt(18)},
+		{ID: 2, ProductID: 11, ProductName: "Cabo USB", Quantity: 5, UnitPrice: money.FromInt(10)},
+		{ID: 3, ProductID: 12, ProductName: "Cadeira", Quantity: 1, UnitPrice: money.FromInt(300)},
+		{ID: 4, ProductID: 13, ProductName: "Brinde", Quantity: 1, UnitPrice: money.FromInt(50)},
+	}}
+}
+
+func TestBuildInvoiceMarginCheck(t *testing.T) {
+	costs := []LineCost{
+		{Cost: money.FromInt(150), Source: MarginCostCOGS},
+		{Cost: money.FromInt(45), Source: MarginCostEstimate},
+		{Cost: money.FromInt(310), Source: MarginCostEstimate},
+		{Cost: money.FromInt(60), Source: MarginCostEstimate},
+	}
+	categoryOf := map[int]int{10: 3, 11: 3, 12: 4}
+	minMargins := map[int]product.MinMargin{3: {CategoryID: 3, CategoryName: "Cabos", MinMarginPercent: 20, SourceCategoryID: 1}}
+
+	check := BuildInvoiceMarginCheck(marginInvoice(), costs, categoryOf, minMargins, nil)
+
+	if line := check.Lines[0]; line.Revenue.String() != "180.00" || line.Margin.String() != "30.00" || line.CostSource != MarginCostCOGS {
+		t.Errorf("Receita sem impostos e com desconto: %+v", line)
+	}
+	if len(check.Categories) != 1 {
+		t.Fatalf("Só a categoria com mínimo é conferida: %+v", check.Categories)
+	}
+	cables := check.Categories[0]
+	if cables.Revenue.String() != "230.00" || cables.Cost.String() != "195.00" || cables.MarginPercent != 15.22 || !cables.BelowMinimum {
+		t.Errorf("Cabos somam as duas linhas e ficam abaixo dos 20%%: %+v", cables)
+	}
+	if check.Total.Revenue.String() != "580.00" || !check.BelowMinimum || !check.Blocked {
+		t.Errorf("Totais inesperados: %+v", check)
+	}
+
+	err := check.Err()
+	if !stderrors.Is(err, errors.ErrMarginBelowMinimum) || !strings.Contains(err.Error(), "Cabos com margem de 15.22% (mínimo 20.00%)") {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+
+	approved := BuildInvoiceMarginCheck(marginInvoice(), costs, categoryOf, minMargins, &InvoiceMarginApproval{ApprovedBy: "admin"})
+	if !approved.BelowMinimum || approved.Blocked || approved.Err() != nil {
+		t.Errorf("A liberação desbloqueia a fatura: %+v", approved)
+	}
+}
+
+func TestCategoryMarginWithoutRevenue(t *testing.T) {
+	free := CategoryMargin{MinMarginPercent: -50, MarginSummary: newMarginSummary(money.Zero, money.FromInt(10))}
+	if !free.belowMinimum() {
+		t.Error("Sem receita e com custo, a margem fica abaixo de qualquer mínimo")
+	}
+	empty := CategoryMargin{MinMarginPercent: 10, MarginSummary: newMarginSummary(money.Zero, money.Zero)}
+	if empty.belowMinimum() {
+		t.Error("Sem receita e sem custo não há o que conferir")
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"fmt"
+)
+
+// ExplodeQuotationKits substitui as linhas de kits com modo explode pelas linhas dos componentes,
+// com o preço, o desconto e o imposto rateados. As linhas dos componentes ficam na unidade de
+// estoque de cada componente. Os demais itens são mantidos como recebidos.
+func ExplodeQuotationKits(items []QuotationItem, kits map[int]product.ProductKit) []QuotationItem {
+	result := make([]QuotationItem, 0, len(items))
+	for _, item := range items {
+		kit, ok := explodable(kits, item.ProductID, item.KitProductID)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+		for _, line := range kit.Explode(item.BaseQuantity(), baseUnitPrice(item.UnitPrice, item.UnitFactor), item.Discount, item.Tax) {
+			result = append(result, QuotationItem{
+				QuotationID:  item.QuotationID,
+				ProductID:    line.ProductID,
+				KitProductID: &kit.ProductID,
+				ProductName:  line.ProductName,
+				ProductCode:  line.ProductCode,
+				Description:  kitLineDescription(item.ProductName, item.Description),
+				Quantity:     line.Quantity,
+				Unit:         line.Unit,
+				UnitFactor:   1,
+				UnitPrice:    line.UnitPrice,
+				Discount:     line.Discount,
+				Tax:          line.Tax,
+				Total:        LineTotal(line.Quantity, line.UnitPrice, line.Discount, line.Tax),
+			})
+		}
+	}
+	return result
+}
+
+// ExplodeSalesOrderKits substitui as linhas de kits com modo explode pelas linhas dos componentes
+func ExplodeSalesOrderKits(items []SOItem, kits map[int]product.ProductKit) []SOItem {
+	result := make([]SOItem, 0, len(items))
+	for _, item := range items {
+		kit, ok := explodable(kits, item.ProductID, item.KitProductID)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+		for _, line := range kit.Explode(item.BaseQuantity(), baseUnitPrice(item.UnitPrice, item.UnitFactor), item.Discount, item.Tax) {
+			result = append(result, SOItem{
+				SalesOrderID: item.SalesOrderID,
+				ProductID:    line.ProductID,
+				KitProductID: &kit.ProductID,
+				ProductName:  line.ProductName,
+				ProductCode:  line.ProductCode,
+				Description:  kitLineDescription(item.ProductName, item.Description),
+				Quantity:     line.Quantity,
+				Unit:         line.Unit,
+				UnitFactor:   1,
+				UnitPrice:    line.UnitPrice,
+				Discount:     line.Discount,
+				Tax:          line.Tax,
+				Total:        LineTotal(line.Quantity, line.UnitPrice, line.Discount, line.Tax),
+			})
+		}
+	}
+	return result
+}
+
+// ExplodeInvoiceKits substitui as linhas de kits com modo explode pelas linhas dos componentes
+func ExplodeInvoiceKits(items []InvoiceItem, kits map[int]product.ProductKit) []InvoiceItem {
+	result := make([]InvoiceItem, 0, len(items))
+	for _, item := range items {
+		kit, ok := explodable(kits, item.ProductID, item.KitProductID)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+		for _, line := range kit.Explode(item.BaseQuantity(), baseUnitPrice(item.UnitPrice, item.UnitFactor), item.Discount, item.Tax) {
+			result = append(result, InvoiceItem{
+				InvoiceID:    item.InvoiceID,
+				ProductID:    line.ProductID,
+				KitProductID: &kit.ProductID,
+				ProductName:  line.ProductName,
+				ProductCode:  line.ProductCode,
+				Description:  kitLineDescription(item.ProductName, item.Description),
+				Quantity:     line.Quantity,
+				Unit:         line.Unit,
+				UnitFactor:   1,
+				UnitPrice:    line.UnitPrice,
+				Discount:     line.Discount,
+				Tax:          line.Tax,
+				Total:        LineTotal(line.Quantity, line.UnitPrice, line.Discount, line.Tax),
+			})
+		}
+	}
+	return result
+}
+
+// explodable indica se a linha é de um kit a desdobrar; linhas já desdobradas não são reprocessadas
+func explodable(kits map[int]product.ProductKit, productID int, kitProductID *int) (product.ProductKit, bool) {
+	if kitProductID != nil {
+		return product.ProductKit{}, false
+	}
+	kit, ok := kits[productID]
+	if !ok || kit.Mode != product.KitModeExplode || len(kit.Components) == 0 {
+		return product.ProductKit{}, false
+	}
+	return kit, true
+}
+
+// kitLineDescription identifica nas linhas dos componentes o kit de origem
+func kitLineDescription(kitName, description string) string {
+	if kitName == "" {
+		return description
+	}
+	if description == "" {
+		return fmt.Sprintf("Componente do kit %s", kitName)
+	}
+	return fmt.Sprintf("Componente do kit %s - %s", kitName, description)
+}
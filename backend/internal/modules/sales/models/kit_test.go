@@ -0,0 +1,60 @@
+package models
+
+import (
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKits() map[int]product.ProductKit {
+	components := []product.KitComponent{
+		{ComponentProductID: 1, Quantity: 1, UnitPrice: money.FromInt(80), ProductName: "Câmera", ProductCode: "CAM"},
+		{ComponentProductID: 2, Quantity: 2, UnitPrice: money.FromInt(10), ProductName: "Cabo", ProductCode: "CAB"},
+	}
+	return map[int]product.ProductKit{
+		50: {ProductID: 50, Mode: product.KitModeExplode, Components: components},
+		60: {ProductID: 60, Mode: product.KitModeKeep, Components: components},
+	}
+}
+
+func TestExplodeSalesOrderKits(t *testing.T) {
+	items := []SOItem{
+		{ProductID: 50, ProductName: "Kit Segurança", Quantity: 3, UnitPrice: money.FromInt(90), Discount: money.FromInt(9)},
+		{ProductID: 60, ProductName: "Kit Mantido", Quantity: 1, UnitPrice: money.FromInt(90)},
+		{ProductID: 7, ProductName: "Avulso", Quantity: 1, UnitPrice: money.FromInt(5)},
+	}
+
+	exploded := ExplodeSalesOrderKits(items, testKits())
+	require.Len(t, exploded, 4)
+
+	assert.Equal(t, 1, exploded[0].ProductID)
+	assert.Equal(t, 3, exploded[0].Quantity)
+	assert.Equal(t, "72.00", exploded[0].UnitPrice.String(), "80% do preço do kit")
+	assert.Equal(t, 2, exploded[1].ProductID)
+	assert.Equal(t, 6, exploded[1].Quantity)
+	assert.Equal(t, "9.00", exploded[1].UnitPrice.String())
+	require.NotNil(t, exploded[0].KitProductID)
+	assert.Equal(t, 50, *exploded[0].KitProductID)
+	assert.Equal(t, "Componente do kit Kit Segurança", exploded[0].Description)
+	assert.Equal(t, items[0].Discount, exploded[0].Discount.Add(exploded[1].Discount))
+
+	var total money.Decimal
+	for _, item := range exploded[:2] {
+		total = total.Add(item.Total)
+	}
+	assert.Equal(t, LineTotal(3, money.FromInt(90), money.FromInt(9), money.Zero), total, "o desdobramento preserva o valor da linha")
+
+	assert.Equal(t, 60, exploded[2].ProductID, "kit com modo keep segue como uma linha")
+	assert.Nil(t, exploded[2].KitProductID)
+	assert.Equal(t, 7, exploded[3].ProductID)
+}
+
+func TestExplodeKitsSkipsExplodedLines(t *testing.T) {
+	kitID := 50
+	items := []InvoiceItem{{ProductID: 50, KitProductID: &kitID, Quantity: 1, UnitPrice: money.FromInt(90)}}
+
+	assert.Equal(t, items, ExplodeInvoiceKits(items, testKits()))
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// interestMonthDays é o mês comercial usado no pró-rata dos juros ao mês
+const interestMonthDays = 30
+
+// LateFeeRule é a regra de multa e juros de mora das faturas vencidas. Sem ContactID é a regra
+// padrão da empresa; com ContactID, substitui a padrão nas faturas do contato.
+type LateFeeRule struct {
+	ID              int           `json:"id" gorm:"primaryKey"`
+	CompanyID       int           `json:"company_id" gorm:"<-:create"`
+	ContactID       *int          `json:"contact_id,omitempty"`
+	ContactName     string        `json:"contact_name,omitempty" gorm:"->;-:migration"`
+	FinePercent     float64       `json:"fine_percent"`
+	FineAmount      money.Decimal `json:"fine_amount" gorm:"default:0"`
+	InterestPercent float64       `json:"interest_percent"`
+	GraceDays       int           `json:"grace_days"`
+	UpdatedBy       string        `json:"updated_by"`
+	CreatedAt       time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela das regras de multa e juros
+func (LateFeeRule) TableName() string {
+	return "late_fee_rules"
+}
+
+// LateFeeRuleInput é o corpo aceito em PUT /late-fee-rules: sem contact_id, define a regra padrão
+// da empresa. interest_percent é ao mês; a multa é fine_percent do saldo vencido mais fine_amount.
+type LateFeeRuleInput struct {
+	ContactID       *int          `json:"contact_id"`
+	FinePercent     float64       `json:"fine_percent"`
+	FineAmount      money.Decimal `json:"fine_amount"`
+	InterestPercent float64       `json:"interest_percent"`
+	GraceDays       int           `json:"grace_days"`
+}
+
+// ToRule valida os percentuais, a multa fixa e a carência e monta a regra
+func (in LateFeeRuleInput) ToRule(username string) (*LateFeeRule, error) {
+	if in.ContactID != nil && *in.ContactID <= 0 {
+		return nil, errors.ErrInvalidLateFeeRule
+	}
+	if in.FinePercent < 0 || in.FinePercent > 100 || in.InterestPercent < 0 || in.InterestPercent > 100 ||
+		in.FineAmount.IsNegative() || in.GraceDays < 0 {
+		return nil, errors.ErrInvalidLateFeeRule
+	}
+	return &LateFeeRule{
+		ContactID:       in.ContactID,
+		FinePercent:     in.FinePercent,
+		FineAmount:      money.Round(in.FineAmount),
+		InterestPercent: in.InterestPercent,
+		GraceDays:       in.GraceDays,
+		UpdatedBy:       username,
+	}, nil
+}
+
+// LateCharge é o valor devido de um vencimento (a parcela, ou a fatura sem parcelas) na data de
+// referência: o saldo em aberto, a multa e os juros pelos dias de atraso
+type LateCharge struct {
+	Number      int           `json:"number,omitempty"`
+	DueDate     time.Time     `json:"due_date"`
+	DaysOverdue int           `json:"days_overdue"`
+	Principal   money.Decimal `json:"principal"`
+	Fine        money.Decimal `json:"fine"`
+	Interest    money.Decimal `json:"interest"`
+	Total       money.Decimal `json:"total"`
+}
+
+// Overdue indica se o vencimento já passou na data de referência
+func (c LateCharge) Overdue() bool {
+	return c.DaysOverdue > 0
+}
+
+// Charge calcula a multa e os juros do saldo vencido em dueDate até asOf (datas sem horário).
+// Dentro da carência não há encargos; passada a carência, a multa é cobrada uma vez e os juros
+// correm desde o vencimento, pró-rata por dia sobre o mês de 30 dias.
+func (r LateFeeRule) Charge(principal money.Decimal, dueDate, asOf time.Time) LateCharge {
+	charge := LateCharge{DueDate: dueDate, Principal: principal, Total: principal}
+	days := int(asOf.Sub(dueDate).Hours() / 24)
+	if days <= 0 || !principal.IsPositive() {
+		return charge
+	}
+	charge.DaysOverdue = days
+	if days <= r.GraceDays {
+		return charge
+	}
+
+	charge.Fine = money.Round(principal.MulFloat(r.FinePercent / 100).Add(r.FineAmount))
+	charge.Interest = money.Round(principal.MulFloat(r.InterestPercent / 100).MulInt(days).DivInt(interestMonthDays))
+	charge.Total = principal.Add(charge.Fine).Add(charge.Interest)
+	return charge
+}
+
+// AmountDue é o valor para quitar a fatura na data de referência, com o detalhe de cada
+// vencimento em aberto e a regra de multa e juros aplicada (nenhuma, sem regra cadastrada)
+type AmountDue struct {
+	InvoiceID int           `json:"invoice_id"`
+	InvoiceNo string        `json:"invoice_no"`
+	AsOf      time.Time     `json:"as_of"`
+	Principal money.Decimal `json:"principal"`
+	Fine      money.Decimal `json:"fine"`
+	Interest  money.Decimal `json:"interest"`
+	Total     money.Decimal `json:"total"`
+	// EarlyDiscount é o desconto por antecipação da condição de pagamento, para quitar a fatura
+	// inteira até EarlyDiscountUntil; informativo, não é abatido de Total
+	EarlyDiscount      money.Decimal `json:"early_discount"`
+	EarlyDiscountUntil *time.Time    `json:"early_discount_until,omitempty"`
+	Rule               *LateFeeRule  `json:"rule,omitempty"`
+	Items              []LateCharge  `json:"items"`
+}
+
+// Charges é a multa mais os juros, o que se soma ao saldo em aberto nas cobranças atualizadas
+func (d AmountDue) Charges() money.Decimal {
+	return d.Fine.Add(d.Interest)
+}
+
+// Item retorna o vencimento da parcela informada
+func (d AmountDue) Item(number int) (LateCharge, bool) {
+	for _, item := range d.Items {
+		if item.Number == number {
+			return item, true
+		}
+	}
+	return LateCharge{}, false
+}
+
+// BuildAmountDue calcula o valor devido da fatura em asOf: cada parcela em aberto vence na sua
+// data; a fatura sem parcelas vence na data da fatura. Faturas quitadas não têm itens.
+func BuildAmountDue(invoice *Invoice, rule *LateFeeRule, asOf time.Time) AmountDue {
+	applied := LateFeeRule{}
+	if rule != nil {
+		applied = *rule
+	}
+	due := AmountDue{InvoiceID: invoice.ID, InvoiceNo: invoice.InvoiceNo, AsOf: asOf, Rule: rule, Items: []LateCharge{}}
+
+	if len(invoice.Installments) > 0 {
+		for _, installment := range invoice.Installments {
+			balance := installment.Amount.Sub(installment.AmountPaid)
+			if installment.Status == InstallmentStatusPaid || !balance.IsPositive() {
+				continue
+			}
+			item := applied.Charge(balance, installment.DueDate, asOf)
+			item.Number = installment.Number
+			due.Items = append(due.Items, item)
+		}
+	} else if balance := invoice.GrandTotal.Sub(invoice.AmountPaid); balance.IsPositive() {
+		due.Items = append(due.Items, applied.Charge(balance, invoice.DueDate, asOf))
+	}
+
+	for _, item := range due.Items {
+		due.Principal = due.Principal.Add(item.Principal)
+		due.Fine = due.Fine.Add(item.Fine)
+		due.Interest = due.Interest.Add(item.Interest)
+	}
+	due.Total = due.Principal.Add(due.Fine).Add(due.Interest)
+	if until := invoice.EarlyDiscountUntil; until != nil && !asOf.After(*until) && !invoice.AmountPaid.IsPositive() {
+		due.EarlyDiscount, due.EarlyDiscountUntil = invoice.EarlyDiscountAmount, until
+	}
+	return due
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func lateDay(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestLateFeeRuleCharge(t *testing.T) {
+	rule := LateFeeRule{FinePercent: 2, FineAmount: money.FromInt(5), InterestPercent: 1, GraceDays: 3}
+	principal := money.FromInt(1000)
+
+	charge := rule.Charge(principal, lateDay(3, 10), lateDay(3, 25))
+	if charge.DaysOverdue != 15 || charge.Fine.String() != "25.00" || charge.Interest.String() != "5.00" || charge.Total.String() != "1030.00" {
+		t.Errorf("Multa de 2%% + R$ 5 e juros de 1%% ao mês por 15 dias: %+v", charge)
+	}
+
+	if charge := rule.Charge(principal, lateDay(3, 10), lateDay(3, 13)); charge.DaysOverdue != 3 || charge.Total.String() != "1000.00" {
+		t.Errorf("Sem encargos dentro da carência: %+v", charge)
+	}
+	if charge := rule.Charge(principal, lateDay(3, 10), lateDay(3, 10)); charge.Overdue() || charge.Total.String() != "1000.00" {
+		t.Errorf("No vencimento não há atraso: %+v", charge)
+	}
+	if charge := (LateFeeRule{}).Charge(principal, lateDay(3, 10), lateDay(4, 10)); !charge.Overdue() || charge.Total.String() != "1000.00" {
+		t.Errorf("Sem regra, só o saldo: %+v", charge)
+	}
+}
+
+func TestBuildAmountDue(t *testing.T) {
+	rule := &LateFeeRule{FinePercent: 2, InterestPercent: 3}
+	invoice := &Invoice{ID: 9, InvoiceNo: "INV-2026-000009", GrandTotal: money.FromInt(900), AmountPaid: money.FromInt(400),
+		Installments: []InvoiceInstallment{
+			{Number: 1, DueDate: lateDay(4, 1), Amount: money.FromInt(300), AmountPaid: money.FromInt(300), Status: InstallmentStatusPaid},
+			{Number: 2, DueDate: lateDay(5, 1), Amount: money.FromInt(300), AmountPaid: money.FromInt(100), Status: InstallmentStatusOverdue},
+			{Number: 3, DueDate: lateDay(6, 1), Amount: money.FromInt(300), Status: InstallmentStatusOpen},
+		}}
+
+	due := BuildAmountDue(invoice, rule, lateDay(5, 11))
+	if len(due.Items) != 2 {
+		t.Fatalf("Só as parcelas em aberto: %+v", due.Items)
+	}
+	if item, _ := due.Item(2); item.DaysOverdue != 10 || item.Fine.String() != "4.00" || item.Interest.String() != "2.00" {
+		t.Errorf("Parcela 2 vencida há 10 dias: %+v", item)
+	}
+	if item, _ := due.Item(3); item.Overdue() || item.Total.String() != "300.00" {
+		t.Errorf("Parcela 3 a vencer: %+v", item)
+	}
+	if due.Principal.String() != "500.00" || due.Charges().String() != "6.00" || due.Total.String() != "506.00" || due.Rule != rule {
+		t.Errorf("Totais inesperados: %+v", due)
+	}
+
+	invoice.Installments = nil
+	invoice.DueDate = lateDay(5, 1)
+	if due := BuildAmountDue(invoice, nil, lateDay(5, 11)); len(due.Items) != 1 || due.Items[0].DaysOverdue != 10 || due.Total.String() != "500.00" {
+		t.Errorf("Fatura sem parcelas e sem regra: %+v", due)
+	}
+
+	invoice.AmountPaid = invoice.GrandTotal
+	if due := BuildAmountDue(invoice, rule, lateDay(5, 11)); len(due.Items) != 0 || due.Total.IsPositive() {
+		t.Errorf("Fatura quitada nada deve: %+v", due)
+	}
+}
+
+func TestLateFeeRuleInputToRule(t *testing.T) {
+	contactID := 12
+	rule, err := LateFeeRuleInput{ContactID: &contactID, FinePercent: 2, FineAmount: money.MustParse("3.456"), InterestPercent: 1, GraceDays: 5}.ToRule("ana")
+	if err != nil || *rule.ContactID != 12 || rule.FineAmount.String() != "3.46" || rule.UpdatedBy != "ana" {
+		t.Errorf("Regra válida: %+v, %v", rule, err)
+	}
+
+	zero := 0
+	invalid := []LateFeeRuleInput{
+		{FinePercent: -1},
+		{FinePercent: 101},
+		{InterestPercent: 150},
+		{FineAmount: money.FromInt(-1)},
+		{GraceDays: -2},
+		{ContactID: &zero},
+	}
+	for _, in := range invalid {
+		if _, err := in.ToRule("ana"); !stderrors.Is(err, errors.ErrInvalidLateFeeRule) {
+			t.Errorf("%+v: esperado ErrInvalidLateFeeRule, obtido %v", in, err)
+		}
+	}
+}
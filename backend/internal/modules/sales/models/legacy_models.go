@@ -6,4 +6,6 @@ type Sale struct {
 	Quantity int     `json:"quantity" validate:"required,gt=0"`
 	Price    float64 `json:"price" validate:"required,gt=0"`
 	Customer string  `json:"customer" validate:"required,email"`
+
+	CustomerDocument string `json:"customer_document,omitempty"`
 }
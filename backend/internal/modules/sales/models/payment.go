@@ -1,18 +1,23 @@
 package models
 
 import (
+	"ERP-ONSMART/backend/internal/money"
 	"time"
 )
 
 // Payment represents a payment made against an invoice
 type Payment struct {
-	ID            int       `json:"id" gorm:"primaryKey"`
-	InvoiceID     int       `json:"invoice_id" gorm:"index"`
-	Amount        float64   `json:"amount" validate:"required,gt=0"`
-	PaymentDate   time.Time `json:"payment_date" gorm:"autoCreateTime"`
-	PaymentMethod string    `json:"payment_method"`
-	Reference     string    `json:"reference"`
-	Notes         string    `json:"notes"`
+	ID            int           `json:"id" gorm:"primaryKey"`
+	CompanyID     int           `json:"company_id" gorm:"<-:create"`
+	InvoiceID     int           `json:"invoice_id" gorm:"index"`
+	Amount        money.Decimal `json:"amount" validate:"required,gt=0"`
+	PaymentDate   time.Time     `json:"payment_date" gorm:"autoCreateTime"`
+	PaymentMethod string        `json:"payment_method"`
+	Reference     string        `json:"reference"`
+	Notes         string        `json:"notes"`
+
+	// Fatias do pagamento por fatura; sem alocações informadas, o valor todo vai para InvoiceID
+	Allocations []PaymentAllocation `json:"allocations,omitempty" gorm:"foreignKey:PaymentID"`
 
 	// Relationships
 	Invoice *Invoice `json:"-" gorm:"foreignKey:InvoiceID"`
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// Um pagamento pode quitar várias faturas do mesmo contato e uma fatura pode receber vários
+// pagamentos: as alocações dizem quanto de cada pagamento vai para cada fatura. O valor pago da
+// fatura é sempre a soma das suas alocações.
+
+// PaymentAllocation é a parte de um pagamento destinada a uma fatura
+type PaymentAllocation struct {
+	ID        int           `json:"id" gorm:"primaryKey"`
+	CompanyID int           `json:"company_id" gorm:"<-:create"`
+	PaymentID int           `json:"payment_id" gorm:"index"`
+	InvoiceID int           `json:"invoice_id" validate:"required" gorm:"index"`
+	Amount    money.Decimal `json:"amount" validate:"required"`
+	CreatedAt time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Invoice *Invoice `json:"invoice,omitempty" gorm:"foreignKey:InvoiceID"`
+}
+
+// DefaultAllocations devolve as alocações do pagamento. Sem nenhuma informada, o pagamento vai
+// para a sua fatura até o saldo em aberto; o que sobrar fica sem alocação, como crédito.
+func (p *Payment) DefaultAllocations(openBalance money.Decimal) []PaymentAllocation {
+	if len(p.Allocations) > 0 {
+		return p.Allocations
+	}
+	amount := p.Amount
+	if openBalance.IsPositive() && amount.GreaterThan(openBalance) {
+		amount = openBalance
+	}
+	return []PaymentAllocation{{InvoiceID: p.InvoiceID, Amount: amount}}
+}
+
+// AllocationCheck reúne o que a validação das alocações precisa das faturas envolvidas
+type AllocationCheck struct {
+	PaymentAmount money.Decimal
+	ContactID     int
+	// Invoices são as faturas alocadas, por ID
+	Invoices map[int]*Invoice
+	// AllocatedElsewhere é o que outros pagamentos já alocaram em cada fatura
+	AllocatedElsewhere map[int]money.Decimal
+}
+
+// ValidateAllocations confere as alocações de um pagamento: faturas distintas, existentes, não
+// canceladas e do contato do pagamento, valores positivos que não passem do saldo em aberto da
+// fatura e soma que não passe do valor do pagamento
+func ValidateAllocations(allocations []PaymentAllocation, check AllocationCheck) error {
+	if len(allocations) == 0 {
+		return errors.ErrInvalidAllocation
+	}
+
+	seen := make(map[int]bool, len(allocations))
+	total := money.Zero
+	for _, allocation := range allocations {
+		if !allocation.Amount.IsPositive() || seen[allocation.InvoiceID] {
+			return errors.ErrInvalidAllocation
+		}
+		seen[allocation.InvoiceID] = true
+
+		invoice, ok := check.Invoices[allocation.InvoiceID]
+		if !ok {
+			return errors.ErrInvoiceNotFound
+		}
+		if invoice.Status == InvoiceStatusCancelled {
+			return errors.ErrInvoiceNotPayable
+		}
+		if invoice.ContactID != check.ContactID {
+			return errors.ErrAllocationContactMismatch
+		}
+		balance := invoice.GrandTotal.Sub(check.AllocatedElsewhere[invoice.ID])
+		if allocation.Amount.GreaterThan(balance) {
+			return errors.ErrAllocationExceedsBalance
+		}
+		total = total.Add(allocation.Amount)
+	}
+
+	if total.GreaterThan(check.PaymentAmount) {
+		return errors.ErrAllocationExceedsPayment
+	}
+	return nil
+}
+
+// InvoicePaymentStatus é o status da fatura para o valor pago: paga ao atingir o total, parcial
+// com algum valor pago e, sem pagamento, de volta a enviada se estava paga ou parcial
+func InvoicePaymentStatus(current string, amountPaid, grandTotal money.Decimal) string {
+	switch {
+	case amountPaid.IsPositive() && amountPaid.GreaterThanOrEqual(grandTotal):
+		return InvoiceStatusPaid
+	case amountPaid.IsPositive():
+		return InvoiceStatusPartial
+	case current == InvoiceStatusPaid || current == InvoiceStatusPartial:
+		return InvoiceStatusSent
+	}
+	return current
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func allocationCheck() AllocationCheck {
+	return AllocationCheck{
+		PaymentAmount: money.FromInt(500),
+		ContactID:     7,
+		Invoices: map[int]*Invoice{
+			1: {ID: 1, ContactID: 7, Status: InvoiceStatusSent, GrandTotal: money.FromInt(300)},
+			2: {ID: 2, ContactID: 7, Status: InvoiceStatusPartial, GrandTotal: money.FromInt(400)},
+			3: {ID: 3, ContactID: 8, Status: InvoiceStatusSent, GrandTotal: money.FromInt(100)},
+			4: {ID: 4, ContactID: 7, Status: InvoiceStatusCancelled, GrandTotal: money.FromInt(100)},
+		},
+		AllocatedElsewhere: map[int]money.Decimal{2: money.FromInt(250)},
+	}
+}
+
+func TestValidateAllocationsSplitsPaymentAcrossInvoices(t *testing.T) {
+	allocations := []PaymentAllocation{
+		{InvoiceID: 1, Amount: money.FromInt(300)},
+		{InvoiceID: 2, Amount: money.FromInt(150)},
+	}
+	assert.NoError(t, ValidateAllocations(allocations, allocationCheck()), "sobra de 50 fica como crédito")
+}
+
+func TestValidateAllocationsRejections(t *testing.T) {
+	cases := []struct {
+		name        string
+		allocations []PaymentAllocation
+		want        error
+	}{
+		{"sem alocações", nil, errors.ErrInvalidAllocation},
+		{"valor zero", []PaymentAllocation{{InvoiceID: 1}}, errors.ErrInvalidAllocation},
+		{"fatura repetida", []PaymentAllocation{{InvoiceID: 1, Amount: money.FromInt(10)}, {InvoiceID: 1, Amount: money.FromInt(10)}}, errors.ErrInvalidAllocation},
+		{"fatura inexistente", []PaymentAllocation{{InvoiceID: 9, Amount: money.FromInt(10)}}, errors.ErrInvoiceNotFound},
+		{"fatura cancelada", []PaymentAllocation{{InvoiceID: 4, Amount: money.FromInt(10)}}, errors.ErrInvoiceNotPayable},
+		{"outro contato", []PaymentAllocation{{InvoiceID: 3, Amount: money.FromInt(10)}}, errors.ErrAllocationContactMismatch},
+		{"acima do saldo", []PaymentAllocation{{InvoiceID: 2, Amount: money.FromInt(151)}}, errors.ErrAllocationExceedsBalance},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ValidateAllocations(tc.allocations, allocationCheck()))
+		})
+	}
+
+	check := allocationCheck()
+	check.PaymentAmount = money.FromInt(400)
+	over := []PaymentAllocation{{InvoiceID: 1, Amount: money.FromInt(300)}, {InvoiceID: 2, Amount: money.FromInt(150)}}
+	assert.Equal(t, errors.ErrAllocationExceedsPayment, ValidateAllocations(over, check))
+}
+
+func TestDefaultAllocationsCapsAtOpenBalance(t *testing.T) {
+	payment := &Payment{InvoiceID: 1, Amount: money.FromInt(120)}
+
+	allocations := payment.DefaultAllocations(money.FromInt(100))
+	assert.Equal(t, []PaymentAllocation{{InvoiceID: 1, Amount: money.FromInt(100)}}, allocations)
+	assert.Equal(t, "120.00", payment.DefaultAllocations(money.FromInt(500))[0].Amount.String())
+}
+
+func TestInvoicePaymentStatus(t *testing.T) {
+	total := money.FromInt(100)
+	assert.Equal(t, InvoiceStatusPaid, InvoicePaymentStatus(InvoiceStatusSent, money.FromInt(100), total))
+	assert.Equal(t, InvoiceStatusPartial, InvoicePaymentStatus(InvoiceStatusPaid, money.FromInt(40), total))
+	assert.Equal(t, InvoiceStatusSent, InvoicePaymentStatus(InvoiceStatusPartial, money.Zero, total))
+	assert.Equal(t, InvoiceStatusDraft, InvoicePaymentStatus(InvoiceStatusDraft, money.Zero, total))
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// ApplyPaymentTerms aplica à fatura a condição de pagamento estruturada do contato: o texto da
+// condição, o vencimento (o da primeira parcela, quando não informado) e o desconto por
+// antecipação sobre o total. Retorna os vencimentos das parcelas iguais a criar quando a
+// condição é parcelada e o vencimento não foi informado na geração.
+func ApplyPaymentTerms(invoice *Invoice, terms *contact.PaymentTerms, schedule *calendar.Schedule) []time.Time {
+	invoice.PaymentTerms = terms.Describe()
+	dates := terms.DueDates(invoice.IssueDate, schedule)
+	if !invoice.DueDate.IsZero() {
+		dates = nil
+	} else {
+		invoice.DueDate = dates[0]
+	}
+
+	if until, ok := terms.DiscountUntil(invoice.IssueDate); ok && invoice.GrandTotal.IsPositive() {
+		invoice.EarlyDiscountAmount = money.Round(invoice.GrandTotal.MulFloat(terms.DiscountPercent / 100))
+		invoice.EarlyDiscountUntil = &until
+	}
+
+	if len(dates) < 2 || !invoice.GrandTotal.IsPositive() {
+		return nil
+	}
+	return dates
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPaymentTermsSplitsInstallments(t *testing.T) {
+	invoice := &Invoice{ID: 4, IssueDate: date(2026, 3, 2), GrandTotal: money.FromCents(100000), PaymentTerms: "a combinar"}
+	terms := &contact.PaymentTerms{InstallmentDays: []int{30, 60, 90}}
+
+	dates := ApplyPaymentTerms(invoice, terms, calendar.DefaultSchedule())
+	require.Len(t, dates, 3)
+	assert.Equal(t, "30/60/90 dias", invoice.PaymentTerms)
+	assert.Equal(t, date(2026, 4, 1), invoice.DueDate, "vence na primeira parcela")
+	assert.Nil(t, invoice.EarlyDiscountUntil)
+
+	installments, err := BuildInstallments(invoice, InstallmentPlan{DueDates: dates})
+	require.NoError(t, err)
+	require.Len(t, installments, 3)
+	assert.Equal(t, "333.34", installments[0].Amount.StringFixed(2))
+	assert.Equal(t, date(2026, 5, 4), installments[1].DueDate, "01/05 é feriado")
+	assert.Equal(t, date(2026, 6, 1), installments[2].DueDate)
+}
+
+func TestApplyPaymentTermsWithEarlyDiscount(t *testing.T) {
+	invoice := &Invoice{IssueDate: date(2026, 3, 2), GrandTotal: money.MustParse("1234.50")}
+	terms := &contact.PaymentTerms{NetDays: 28, DiscountPercent: 2, DiscountDays: 10}
+
+	assert.Nil(t, ApplyPaymentTerms(invoice, terms, calendar.DefaultSchedule()))
+	assert.Equal(t, date(2026, 3, 30), invoice.DueDate)
+	assert.Equal(t, "24.69", invoice.EarlyDiscountAmount.StringFixed(2))
+	require.NotNil(t, invoice.EarlyDiscountUntil)
+	assert.Equal(t, date(2026, 3, 12), *invoice.EarlyDiscountUntil)
+
+	due := BuildAmountDue(invoice, nil, date(2026, 3, 12))
+	assert.Equal(t, "24.69", due.EarlyDiscount.StringFixed(2))
+	assert.Equal(t, "1234.50", due.Total.StringFixed(2), "o desconto não é abatido do total")
+	assert.True(t, BuildAmountDue(invoice, nil, date(2026, 3, 13)).EarlyDiscount.IsZero(), "prazo do desconto vencido")
+
+	invoice.AmountPaid = money.FromInt(100)
+	assert.True(t, BuildAmountDue(invoice, nil, date(2026, 3, 5)).EarlyDiscount.IsZero(), "só para quitar a fatura inteira")
+}
+
+func TestApplyPaymentTermsKeepsInformedDueDate(t *testing.T) {
+	due := date(2026, 3, 20)
+	invoice := &Invoice{IssueDate: date(2026, 3, 2), DueDate: due, GrandTotal: money.FromInt(300)}
+	terms := &contact.PaymentTerms{InstallmentDays: []int{30, 60}}
+
+	assert.Nil(t, ApplyPaymentTerms(invoice, terms, calendar.DefaultSchedule()), "vencimento informado não é parcelado")
+	assert.Equal(t, due, invoice.DueDate)
+	assert.Equal(t, "30/60 dias", invoice.PaymentTerms)
+}
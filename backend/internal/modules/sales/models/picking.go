@@ -0,0 +1,221 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// As entregas de pedido de venda passam por separação e embalagem antes do envio:
+// pending -> picking (lista de separação gerada) -> packed (volumes registrados) -> shipped.
+// Entregas de pedido de compra (recebimento) não têm essas etapas.
+
+// DeliveryPackage é um volume da entrega, com peso e dimensões, identificado na etiqueta pelo
+// código <número da entrega>-P<número do volume>
+type DeliveryPackage struct {
+	ID         int                   `json:"id" gorm:"primaryKey"`
+	CompanyID  int                   `json:"company_id" gorm:"<-:create"`
+	DeliveryID int                   `json:"delivery_id" gorm:"index"`
+	PackageNo  int                   `json:"package_no"`
+	Code       string                `json:"code" gorm:"-"`
+	WeightKg   float64               `json:"weight_kg"`
+	LengthCm   float64               `json:"length_cm"`
+	WidthCm    float64               `json:"width_cm"`
+	HeightCm   float64               `json:"height_cm"`
+	CreatedAt  time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	Items      []DeliveryPackageItem `json:"items" gorm:"foreignKey:PackageID"`
+}
+
+// TableName define o nome da tabela de volumes da entrega
+func (DeliveryPackage) TableName() string {
+	return "delivery_packages"
+}
+
+// DeliveryPackageItem é a quantidade de um item da entrega embalada no volume
+type DeliveryPackageItem struct {
+	ID             int `json:"id" gorm:"primaryKey"`
+	PackageID      int `json:"package_id" gorm:"index"`
+	DeliveryItemID int `json:"delivery_item_id"`
+	Quantity       int `json:"quantity"`
+}
+
+// TableName define o nome da tabela de itens dos volumes
+func (DeliveryPackageItem) TableName() string {
+	return "delivery_package_items"
+}
+
+// PickLine informa a quantidade separada de um item da entrega
+type PickLine struct {
+	DeliveryItemID int
+	PickedQty      int
+}
+
+// PackageInput descreve um volume: peso, dimensões e os itens embalados nele
+type PackageInput struct {
+	WeightKg float64
+	LengthCm float64
+	WidthCm  float64
+	HeightCm float64
+	Items    []PackageItemInput
+}
+
+// PackageItemInput é a quantidade de um item da entrega colocada no volume
+type PackageItemInput struct {
+	DeliveryItemID int
+	Quantity       int
+}
+
+// PickList é a lista de separação da entrega, agrupada por endereço de estoque na ordem de
+// percurso (código do endereço); itens sem endereço ficam no fim
+type PickList struct {
+	DeliveryID int       `json:"delivery_id"`
+	DeliveryNo string    `json:"delivery_no"`
+	Status     string    `json:"status"`
+	TotalQty   int       `json:"total_qty"`
+	PickedQty  int       `json:"picked_qty"`
+	Complete   bool      `json:"complete"`
+	Bins       []PickBin `json:"bins"`
+}
+
+// PickBin são os itens a separar em um endereço de estoque
+type PickBin struct {
+	BinID   *int       `json:"bin_id"`
+	BinCode string     `json:"bin_code"`
+	Zone    string     `json:"zone"`
+	Items   []PickItem `json:"items"`
+}
+
+// PickItem é um item da entrega na lista de separação, com o endereço do produto
+type PickItem struct {
+	DeliveryItemID int    `json:"delivery_item_id"`
+	ProductID      int    `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	ProductCode    string `json:"product_code"`
+	Unit           string `json:"unit"`
+	Quantity       int    `json:"quantity"`
+	PickedQty      int    `json:"picked_qty"`
+	LotNumber      string `json:"lot_number,omitempty"`
+	BinID          *int   `json:"-"`
+	BinCode        string `json:"-"`
+	Zone           string `json:"-"`
+}
+
+// DeliveryPackageCode retorna o código do volume n da entrega: DLV-2026-000001-P2
+func DeliveryPackageCode(deliveryNo string, n int) string {
+	return fmt.Sprintf("%s-P%d", deliveryNo, n)
+}
+
+// BuildPickList agrupa os itens por endereço de estoque e totaliza o que já foi separado
+func BuildPickList(delivery *Delivery, items []PickItem) *PickList {
+	list := &PickList{
+		DeliveryID: delivery.ID,
+		DeliveryNo: delivery.DeliveryNo,
+		Status:     delivery.Status,
+		Bins:       []PickBin{},
+	}
+
+	sorted := append([]PickItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if (sorted[i].BinCode == "") != (sorted[j].BinCode == "") {
+			return sorted[j].BinCode == ""
+		}
+		return sorted[i].BinCode < sorted[j].BinCode
+	})
+
+	for _, item := range sorted {
+		list.TotalQty += item.Quantity
+		list.PickedQty += item.PickedQty
+		if n := len(list.Bins); n == 0 || list.Bins[n-1].BinCode != item.BinCode {
+			list.Bins = append(list.Bins, PickBin{BinID: item.BinID, BinCode: item.BinCode, Zone: item.Zone})
+		}
+		bin := &list.Bins[len(list.Bins)-1]
+		bin.Items = append(bin.Items, item)
+	}
+	list.Complete = len(items) > 0 && list.PickedQty == list.TotalQty
+	return list
+}
+
+// ApplyPicks grava nos itens as quantidades separadas informadas; a quantidade separada não
+// pode passar da quantidade do item
+func ApplyPicks(items []DeliveryItem, picks []PickLine) error {
+	byID := make(map[int]*DeliveryItem, len(items))
+	for i := range items {
+		byID[items[i].ID] = &items[i]
+	}
+	for _, pick := range picks {
+		item, ok := byID[pick.DeliveryItemID]
+		if !ok {
+			return errors.ErrDeliveryItemNotFound
+		}
+		if pick.PickedQty > item.Quantity {
+			return errors.ErrOverPick
+		}
+		item.PickedQty = pick.PickedQty
+	}
+	return nil
+}
+
+// BuildPackages monta os volumes da entrega. Todos os itens precisam estar separados e a soma
+// das quantidades embaladas de cada item tem de ser igual à quantidade separada.
+func BuildPackages(delivery *Delivery, inputs []PackageInput) ([]DeliveryPackage, error) {
+	remaining := make(map[int]int, len(delivery.Items))
+	for _, item := range delivery.Items {
+		if item.PickedQty < item.Quantity {
+			return nil, errors.ErrPickingIncomplete
+		}
+		remaining[item.ID] = item.PickedQty
+	}
+
+	packages := make([]DeliveryPackage, 0, len(inputs))
+	for i, input := range inputs {
+		pkg := DeliveryPackage{
+			DeliveryID: delivery.ID,
+			PackageNo:  i + 1,
+			Code:       DeliveryPackageCode(delivery.DeliveryNo, i+1),
+			WeightKg:   input.WeightKg,
+			LengthCm:   input.LengthCm,
+			WidthCm:    input.WidthCm,
+			HeightCm:   input.HeightCm,
+		}
+		for _, line := range input.Items {
+			left, ok := remaining[line.DeliveryItemID]
+			if !ok {
+				return nil, errors.ErrDeliveryItemNotFound
+			}
+			if line.Quantity > left {
+				return nil, errors.ErrPackingMismatch
+			}
+			remaining[line.DeliveryItemID] = left - line.Quantity
+			pkg.Items = append(pkg.Items, DeliveryPackageItem{DeliveryItemID: line.DeliveryItemID, Quantity: line.Quantity})
+		}
+		packages = append(packages, pkg)
+	}
+
+	for _, left := range remaining {
+		if left != 0 {
+			return nil, errors.ErrPackingMismatch
+		}
+	}
+	return packages, nil
+}
+
+// RequiresPacking indica se a entrega precisa estar embalada para ser enviada: entregas de
+// pedido de venda passam pela separação e embalagem
+func (d *Delivery) RequiresPacking() bool {
+	return d.SalesOrderID != 0
+}
+
+// CanShip indica se a entrega pode ser marcada como enviada
+func (d *Delivery) CanShip() error {
+	if d.RequiresPacking() {
+		if d.Status != DeliveryStatusPacked {
+			return errors.ErrDeliveryNotPacked
+		}
+		return nil
+	}
+	if d.Status != DeliveryStatusPending {
+		return errors.ErrDeliveryNotPending
+	}
+	return nil
+}
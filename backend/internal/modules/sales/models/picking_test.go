@@ -0,0 +1,93 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPickListGroupsByBinWithUnassignedLast(t *testing.T) {
+	binA, binB := 1, 2
+	delivery := &Delivery{ID: 7, DeliveryNo: "DLV-2026-000007", Status: DeliveryStatusPicking}
+	list := BuildPickList(delivery, []PickItem{
+		{DeliveryItemID: 1, Quantity: 2, PickedQty: 2},
+		{DeliveryItemID: 2, Quantity: 5, PickedQty: 1, BinID: &binB, BinCode: "B-01", Zone: "B"},
+		{DeliveryItemID: 3, Quantity: 1, BinID: &binA, BinCode: "A-03", Zone: "A"},
+		{DeliveryItemID: 4, Quantity: 4, PickedQty: 4, BinID: &binB, BinCode: "B-01", Zone: "B"},
+	})
+
+	require.Len(t, list.Bins, 3)
+	assert.Equal(t, "A-03", list.Bins[0].BinCode)
+	assert.Equal(t, "B-01", list.Bins[1].BinCode)
+	assert.Equal(t, "B", list.Bins[1].Zone)
+	assert.Len(t, list.Bins[1].Items, 2)
+	assert.Equal(t, "", list.Bins[2].BinCode, "itens sem endereço ficam no fim")
+	assert.Nil(t, list.Bins[2].BinID)
+	assert.Equal(t, 12, list.TotalQty)
+	assert.Equal(t, 7, list.PickedQty)
+	assert.False(t, list.Complete)
+
+	empty := BuildPickList(delivery, nil)
+	assert.Empty(t, empty.Bins)
+	assert.False(t, empty.Complete)
+}
+
+func TestApplyPicks(t *testing.T) {
+	items := []DeliveryItem{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 2}}
+
+	require.NoError(t, ApplyPicks(items, []PickLine{{DeliveryItemID: 1, PickedQty: 3}, {DeliveryItemID: 2, PickedQty: 1}}))
+	assert.Equal(t, 3, items[0].PickedQty)
+	assert.Equal(t, 1, items[1].PickedQty)
+
+	assert.Equal(t, errors.ErrOverPick, ApplyPicks(items, []PickLine{{DeliveryItemID: 2, PickedQty: 3}}))
+	assert.Equal(t, errors.ErrDeliveryItemNotFound, ApplyPicks(items, []PickLine{{DeliveryItemID: 9, PickedQty: 1}}))
+}
+
+func TestBuildPackages(t *testing.T) {
+	delivery := &Delivery{
+		ID:         7,
+		DeliveryNo: "DLV-2026-000007",
+		Items:      []DeliveryItem{{ID: 1, Quantity: 3, PickedQty: 3}, {ID: 2, Quantity: 2, PickedQty: 2}},
+	}
+
+	packages, err := BuildPackages(delivery, []PackageInput{
+		{WeightKg: 4.5, LengthCm: 40, WidthCm: 30, HeightCm: 20, Items: []PackageItemInput{{DeliveryItemID: 1, Quantity: 2}}},
+		{WeightKg: 3, Items: []PackageItemInput{{DeliveryItemID: 1, Quantity: 1}, {DeliveryItemID: 2, Quantity: 2}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, packages, 2)
+	assert.Equal(t, 2, packages[1].PackageNo)
+	assert.Equal(t, "DLV-2026-000007-P2", packages[1].Code)
+	assert.Equal(t, 4.5, packages[0].WeightKg)
+	assert.Len(t, packages[1].Items, 2)
+
+	_, err = BuildPackages(delivery, []PackageInput{
+		{WeightKg: 1, Items: []PackageItemInput{{DeliveryItemID: 1, Quantity: 3}}},
+	})
+	assert.Equal(t, errors.ErrPackingMismatch, err, "item 2 ficou fora dos volumes")
+
+	_, err = BuildPackages(delivery, []PackageInput{
+		{WeightKg: 1, Items: []PackageItemInput{{DeliveryItemID: 1, Quantity: 4}, {DeliveryItemID: 2, Quantity: 2}}},
+	})
+	assert.Equal(t, errors.ErrPackingMismatch, err, "mais que o separado")
+
+	delivery.Items[1].PickedQty = 1
+	_, err = BuildPackages(delivery, nil)
+	assert.Equal(t, errors.ErrPickingIncomplete, err)
+}
+
+func TestDeliveryCanShip(t *testing.T) {
+	outgoing := &Delivery{SalesOrderID: 3, Status: DeliveryStatusPicking}
+	assert.Equal(t, errors.ErrDeliveryNotPacked, outgoing.CanShip())
+	outgoing.Status = DeliveryStatusPending
+	assert.Equal(t, errors.ErrDeliveryNotPacked, outgoing.CanShip(), "pedido de venda não pula a embalagem")
+	outgoing.Status = DeliveryStatusPacked
+	assert.NoError(t, outgoing.CanShip())
+
+	incoming := &Delivery{PurchaseOrderID: 4, Status: DeliveryStatusPending}
+	assert.NoError(t, incoming.CanShip())
+	incoming.Status = DeliveryStatusShipped
+	assert.Equal(t, errors.ErrDeliveryNotPending, incoming.CanShip())
+}
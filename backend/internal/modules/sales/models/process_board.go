@@ -0,0 +1,86 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// DefaultBoardColumnLimit é a quantidade de cartões por coluna do quadro quando a requisição não
+// informa o limite
+const DefaultBoardColumnLimit = 50
+
+// ProcessBoardFilter filtra os processos do quadro; campos vazios não filtram
+type ProcessBoardFilter struct {
+	ContactID int
+	// IncludeClosed inclui as colunas dos processos concluídos e cancelados
+	IncludeClosed bool
+	// Limit é a quantidade de cartões por coluna; a contagem e o total consideram todos
+	Limit int
+}
+
+// BoardCard é um processo de venda no quadro
+type BoardCard struct {
+	ID          int           `json:"id"`
+	Status      string        `json:"-"`
+	ContactID   int           `json:"contact_id"`
+	ContactName string        `json:"contact_name"`
+	TotalValue  money.Decimal `json:"total_value"`
+	Position    int           `json:"position" gorm:"column:board_position"`
+	SLAStatus   *string       `json:"sla_status,omitempty"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// BoardColumnTotal é a quantidade e o valor dos processos de uma etapa
+type BoardColumnTotal struct {
+	Status     string        `json:"-"`
+	Count      int           `json:"count"`
+	TotalValue money.Decimal `json:"total_value"`
+}
+
+// BoardColumn é uma etapa do quadro com os cartões na ordem da coluna
+type BoardColumn struct {
+	Stage      string        `json:"stage"`
+	Label      string        `json:"label"`
+	Count      int           `json:"count"`
+	TotalValue money.Decimal `json:"total_value"`
+	Cards      []BoardCard   `json:"cards"`
+}
+
+// ProcessBoard é o quadro dos processos de venda por etapa
+type ProcessBoard struct {
+	Columns    []BoardColumn `json:"columns"`
+	Count      int           `json:"count"`
+	TotalValue money.Decimal `json:"total_value"`
+}
+
+// MoveProcessInput move o processo para a etapa e a posição (1 é o topo da coluna; sem
+// posição, o processo vai para o fim)
+type MoveProcessInput struct {
+	Stage    string `json:"stage" binding:"required"`
+	Position *int   `json:"position" binding:"omitempty,min=1"`
+}
+
+// BuildProcessBoard monta as colunas das etapas na ordem informada, com os totais da etapa e os
+// cartões na ordem em que vieram; label dá o rótulo de cada etapa
+func BuildProcessBoard(stages []string, totals []BoardColumnTotal, cards []BoardCard, label func(stage string) string) ProcessBoard {
+	byStage := make(map[string]*BoardColumn, len(stages))
+	board := ProcessBoard{Columns: make([]BoardColumn, len(stages))}
+	for i, stage := range stages {
+		board.Columns[i] = BoardColumn{Stage: stage, Label: label(stage), Cards: []BoardCard{}}
+		byStage[stage] = &board.Columns[i]
+	}
+	for _, total := range totals {
+		if column, ok := byStage[total.Status]; ok {
+			column.Count = total.Count
+			column.TotalValue = total.TotalValue
+			board.Count += total.Count
+			board.TotalValue = board.TotalValue.Add(total.TotalValue)
+		}
+	}
+	for _, card := range cards {
+		if column, ok := byStage[card.Status]; ok {
+			column.Cards = append(column.Cards, card)
+		}
+	}
+	return board
+}
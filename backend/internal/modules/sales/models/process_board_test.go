@@ -0,0 +1,36 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProcessBoard(t *testing.T) {
+	stages := []string{"draft", "quotation", "sales_order"}
+	totals := []BoardColumnTotal{
+		{Status: "quotation", Count: 3, TotalValue: money.FromInt(300)},
+		{Status: "draft", Count: 1, TotalValue: money.FromInt(50)},
+		{Status: "completed", Count: 9, TotalValue: money.FromInt(900)},
+	}
+	cards := []BoardCard{
+		{ID: 1, Status: "draft", Position: 1},
+		{ID: 4, Status: "quotation", Position: 0},
+		{ID: 2, Status: "quotation", Position: 1},
+		{ID: 8, Status: "completed", Position: 1},
+	}
+
+	board := BuildProcessBoard(stages, totals, cards, func(stage string) string { return "label:" + stage })
+	require.Len(t, board.Columns, 3)
+	assert.Equal(t, "quotation", board.Columns[1].Stage)
+	assert.Equal(t, "label:quotation", board.Columns[1].Label)
+	assert.Equal(t, 3, board.Columns[1].Count, "a contagem considera os processos além do limite de cartões")
+	assert.Equal(t, "300.00", board.Columns[1].TotalValue.String())
+	assert.Equal(t, []int{4, 2}, []int{board.Columns[1].Cards[0].ID, board.Columns[1].Cards[1].ID})
+	assert.NotNil(t, board.Columns[2].Cards, "coluna vazia sai como lista vazia")
+	assert.Empty(t, board.Columns[2].Cards)
+	assert.Equal(t, 4, board.Count, "as etapas fora do quadro não entram no total")
+	assert.Equal(t, "350.00", board.TotalValue.String())
+}
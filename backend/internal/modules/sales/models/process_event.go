@@ -0,0 +1,174 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tipos dos eventos da linha do tempo do processo de venda
+const (
+	ProcessEventCreated              = "process_created"
+	ProcessEventQuotationCreated     = "quotation_created"
+	ProcessEventSalesOrderCreated    = "sales_order_created"
+	ProcessEventPurchaseOrderCreated = "purchase_order_created"
+	ProcessEventDeliveryCreated      = "delivery_created"
+	ProcessEventInvoiceCreated       = "invoice_created"
+	ProcessEventPaymentReceived      = "payment_received"
+	ProcessEventStatusChanged        = "status_changed"
+
+	// Eventos lançados manualmente pelos usuários
+	ProcessEventCall    = "call"
+	ProcessEventMeeting = "meeting"
+	ProcessEventNote    = "note"
+	ProcessEventEmail   = "email"
+)
+
+// ManualProcessEvents são os tipos aceitos nos eventos lançados pelos usuários
+var ManualProcessEvents = []string{ProcessEventCall, ProcessEventMeeting, ProcessEventNote, ProcessEventEmail}
+
+// maxProcessEventDescription limita a descrição dos eventos manuais
+const maxProcessEventDescription = 2000
+
+// ProcessEvent é um evento da linha do tempo do processo de venda, gravado quando o evento
+// acontece: documento vinculado, mudança de etapa, pagamento recebido ou lançamento manual
+type ProcessEvent struct {
+	ID           int           `json:"id,omitempty" gorm:"primaryKey"`
+	CompanyID    int           `json:"-" gorm:"<-:create"`
+	ProcessID    int           `json:"process_id,omitempty"`
+	Timestamp    time.Time     `json:"timestamp" gorm:"column:occurred_at"`
+	EventType    string        `json:"event_type"`
+	Description  string        `json:"description"`
+	DocumentType string        `json:"document_type,omitempty"`
+	DocumentID   int           `json:"document_id,omitempty"`
+	DocumentNo   string        `json:"document_no,omitempty"`
+	Value        money.Decimal `json:"value,omitzero"`
+	Manual       bool          `json:"manual,omitempty"`
+	CreatedBy    string        `json:"created_by,omitempty"`
+	CreatedAt    time.Time     `json:"-" gorm:"autoCreateTime"`
+}
+
+// TableName define o nome da tabela dos eventos da linha do tempo
+func (ProcessEvent) TableName() string {
+	return "process_events"
+}
+
+// ProcessEventInput é o corpo aceito em POST /sales-processes/:id/timeline; sem occurred_at, o
+// evento é registrado no momento do lançamento
+type ProcessEventInput struct {
+	EventType   string     `json:"event_type" binding:"required"`
+	Description string     `json:"description" binding:"required"`
+	OccurredAt  *time.Time `json:"occurred_at"`
+}
+
+// ToEvent valida o lançamento manual e monta o evento do processo. A linha do tempo registra o
+// que já aconteceu: datas no futuro são recusadas.
+func (in ProcessEventInput) ToEvent(processID int, username string, now time.Time) (*ProcessEvent, error) {
+	eventType := strings.ToLower(strings.TrimSpace(in.EventType))
+	description := strings.TrimSpace(in.Description)
+	if !isManualProcessEvent(eventType) || description == "" || len([]rune(description)) > maxProcessEventDescription {
+		return nil, errors.ErrInvalidProcessEvent
+	}
+	occurredAt := now
+	if in.OccurredAt != nil {
+		if in.OccurredAt.After(now) {
+			return nil, errors.ErrInvalidProcessEvent
+		}
+		occurredAt = *in.OccurredAt
+	}
+	return &ProcessEvent{
+		ProcessID:   processID,
+		Timestamp:   occurredAt,
+		EventType:   eventType,
+		Description: description,
+		Manual:      true,
+		CreatedBy:   username,
+	}, nil
+}
+
+func isManualProcessEvent(eventType string) bool {
+	for _, manual := range ManualProcessEvents {
+		if eventType == manual {
+			return true
+		}
+	}
+	return false
+}
+
+// QuotationEvent é o evento da cotação vinculada ao processo
+func QuotationEvent(quotation *Quotation) ProcessEvent {
+	return ProcessEvent{
+		Timestamp:    quotation.CreatedAt,
+		EventType:    ProcessEventQuotationCreated,
+		Description:  fmt.Sprintf("Cotação %s criada", quotation.QuotationNo),
+		DocumentType: "quotation",
+		DocumentID:   quotation.ID,
+		DocumentNo:   quotation.QuotationNo,
+		Value:        quotation.GrandTotal,
+	}
+}
+
+// SalesOrderEvent é o evento do pedido de venda vinculado ao processo
+func SalesOrderEvent(salesOrder *SalesOrder) ProcessEvent {
+	return ProcessEvent{
+		Timestamp:    salesOrder.CreatedAt,
+		EventType:    ProcessEventSalesOrderCreated,
+		Description:  fmt.Sprintf("Pedido de venda %s criado", salesOrder.SONo),
+		DocumentType: "sales_order",
+		DocumentID:   salesOrder.ID,
+		DocumentNo:   salesOrder.SONo,
+		Value:        salesOrder.GrandTotal,
+	}
+}
+
+// PurchaseOrderEvent é o evento da ordem de compra do pedido de venda do processo
+func PurchaseOrderEvent(purchaseOrder *PurchaseOrder) ProcessEvent {
+	return ProcessEvent{
+		Timestamp:    purchaseOrder.CreatedAt,
+		EventType:    ProcessEventPurchaseOrderCreated,
+		Description:  fmt.Sprintf("Ordem de compra %s criada", purchaseOrder.PONo),
+		DocumentType: "purchase_order",
+		DocumentID:   purchaseOrder.ID,
+		DocumentNo:   purchaseOrder.PONo,
+		Value:        purchaseOrder.GrandTotal,
+	}
+}
+
+// DeliveryEvent é o evento da entrega vinculada ao processo
+func DeliveryEvent(delivery *Delivery) ProcessEvent {
+	return ProcessEvent{
+		Timestamp:    delivery.CreatedAt,
+		EventType:    ProcessEventDeliveryCreated,
+		Description:  fmt.Sprintf("Entrega %s criada", delivery.DeliveryNo),
+		DocumentType: "delivery",
+		DocumentID:   delivery.ID,
+		DocumentNo:   delivery.DeliveryNo,
+	}
+}
+
+// InvoiceEvent é o evento da fatura vinculada ao processo
+func InvoiceEvent(invoice *Invoice) ProcessEvent {
+	return ProcessEvent{
+		Timestamp:    invoice.CreatedAt,
+		EventType:    ProcessEventInvoiceCreated,
+		Description:  fmt.Sprintf("Fatura %s criada", invoice.InvoiceNo),
+		DocumentType: "invoice",
+		DocumentID:   invoice.ID,
+		DocumentNo:   invoice.InvoiceNo,
+		Value:        invoice.GrandTotal,
+	}
+}
+
+// PaymentEvent é o evento do pagamento recebido de uma fatura do processo
+func PaymentEvent(payment *Payment) ProcessEvent {
+	return ProcessEvent{
+		Timestamp:    payment.PaymentDate,
+		EventType:    ProcessEventPaymentReceived,
+		Description:  fmt.Sprintf("Pagamento de %s recebido", payment.Amount),
+		DocumentType: "payment",
+		DocumentID:   payment.ID,
+		Value:        payment.Amount,
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessEventInputToEvent(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+
+	event, err := ProcessEventInput{EventType: " Call ", Description: " Cliente pediu nova proposta "}.ToEvent(7, "ana", now)
+	require.NoError(t, err)
+	assert.Equal(t, 7, event.ProcessID)
+	assert.Equal(t, ProcessEventCall, event.EventType)
+	assert.Equal(t, "Cliente pediu nova proposta", event.Description)
+	assert.Equal(t, now, event.Timestamp)
+	assert.True(t, event.Manual)
+	assert.Equal(t, "ana", event.CreatedBy)
+
+	// Reunião lançada depois de acontecer fica na data informada
+	meeting := now.Add(-48 * time.Hour)
+	event, err = ProcessEventInput{EventType: "meeting", Description: "Visita técnica", OccurredAt: &meeting}.ToEvent(7, "ana", now)
+	require.NoError(t, err)
+	assert.Equal(t, meeting, event.Timestamp)
+
+	future := now.Add(time.Hour)
+	invalid := []ProcessEventInput{
+		{EventType: "invoice_created", Description: "Fatura"},
+		{EventType: "note", Description: "   "},
+		{EventType: "note", Description: string(make([]rune, maxProcessEventDescription+1))},
+		{EventType: "note", Description: "Retornar amanhã", OccurredAt: &future},
+	}
+	for _, input := range invalid {
+		_, err := input.ToEvent(7, "ana", now)
+		assert.ErrorIs(t, err, errors.ErrInvalidProcessEvent, input.EventType)
+	}
+}
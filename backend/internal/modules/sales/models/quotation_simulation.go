@@ -0,0 +1,238 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"math"
+)
+
+// QuotationSimulationInput traz as condições hipotéticas da simulação de margem. DiscountPercent
+// aplica o mesmo desconto sobre o bruto de todas as linhas, no lugar dos descontos gravados;
+// CostChangePercent reajusta os custos (5 simula custos 5% maiores). Lines altera linhas
+// específicas e prevalece sobre as condições gerais.
+type QuotationSimulationInput struct {
+	DiscountPercent   *float64                  `json:"discount_percent"`
+	CostChangePercent float64                   `json:"cost_change_percent"`
+	Lines             []QuotationSimulationLine `json:"lines"`
+}
+
+// QuotationSimulationLine altera uma linha da cotação na simulação; campos vazios mantêm o valor
+// da linha. Discount é o valor do desconto da linha e DiscountPercent, o percentual sobre o bruto;
+// UnitCost fixa o custo por unidade da linha no lugar do custo do estoque.
+type QuotationSimulationLine struct {
+	ItemID          int            `json:"item_id" binding:"required"`
+	Quantity        *int           `json:"quantity"`
+	UnitPrice       *money.Decimal `json:"unit_price"`
+	Discount        *money.Decimal `json:"discount"`
+	DiscountPercent *float64       `json:"discount_percent"`
+	UnitCost        *money.Decimal `json:"unit_cost"`
+}
+
+// MarginSummary é a receita (bruto menos desconto, sem impostos), o custo e a margem de uma linha
+// ou da cotação
+type MarginSummary struct {
+	Revenue       money.Decimal `json:"revenue"`
+	Cost          money.Decimal `json:"cost"`
+	Margin        money.Decimal `json:"margin"`
+	MarginPercent float64       `json:"margin_percent"`
+}
+
+// SimulatedLine é uma linha da cotação com as condições simuladas e a margem atual e simulada.
+// UnitCost é o custo simulado por unidade da linha.
+type SimulatedLine struct {
+	ItemID      int           `json:"item_id"`
+	ProductID   int           `json:"product_id"`
+	ProductName string        `json:"product_name"`
+	Unit        string        `json:"unit"`
+	Quantity    int           `json:"quantity"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	Discount    money.Decimal `json:"discount"`
+	Tax         money.Decimal `json:"tax"`
+	UnitCost    money.Decimal `json:"unit_cost"`
+	CostMethod  string        `json:"cost_method,omitempty"`
+	Current     MarginSummary `json:"current"`
+	Simulated   MarginSummary `json:"simulated"`
+
+	item     QuotationItem
+	unitCost *money.Decimal
+}
+
+// QuotationSimulation é o resultado da simulação: as linhas e os totais atuais e simulados. Nada
+// é gravado na cotação.
+type QuotationSimulation struct {
+	QuotationID         int             `json:"quotation_id"`
+	QuotationNo         string          `json:"quotation_no"`
+	CostChangePercent   float64         `json:"cost_change_percent"`
+	Lines               []SimulatedLine `json:"lines"`
+	Current             MarginSummary   `json:"current"`
+	Simulated           MarginSummary   `json:"simulated"`
+	MarginChange        money.Decimal   `json:"margin_change"`
+	GrandTotal          money.Decimal   `json:"grand_total"`
+	SimulatedGrandTotal money.Decimal   `json:"simulated_grand_total"`
+}
+
+// PrepareSimulation confere a entrada e monta as linhas simuladas da cotação, ainda sem custos
+func (in QuotationSimulationInput) PrepareSimulation(quotation *Quotation) ([]SimulatedLine, error) {
+	if in.DiscountPercent != nil && (*in.DiscountPercent < 0 || *in.DiscountPercent >= 100) {
+		return nil, fmt.Errorf("%w: discount_percent deve estar entre 0 e 100", errors.ErrInvalidQuotationSimulation)
+	}
+	if in.CostChangePercent <= -100 {
+		return nil, fmt.Errorf("%w: cost_change_percent deve ser maior que -100", errors.ErrInvalidQuotationSimulation)
+	}
+	if len(quotation.Items) == 0 {
+		return nil, fmt.Errorf("%w: a cotação não tem itens", errors.ErrInvalidQuotationSimulation)
+	}
+
+	changes := make(map[int]QuotationSimulationLine, len(in.Lines))
+	for _, line := range in.Lines {
+		if _, repeated := changes[line.ItemID]; repeated {
+			return nil, fmt.Errorf("%w: item %d informado mais de uma vez", errors.ErrInvalidQuotationSimulation, line.ItemID)
+		}
+		changes[line.ItemID] = line
+	}
+
+	lines := make([]SimulatedLine, 0, len(quotation.Items))
+	for _, item := range quotation.Items {
+		line := SimulatedLine{
+			ItemID:      item.ID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			Unit:        item.Unit,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Discount:    item.Discount,
+			Tax:         item.Tax,
+			item:        item,
+		}
+		if in.DiscountPercent != nil {
+			line.Discount = percentOf(line.UnitPrice.MulInt(line.Quantity), *in.DiscountPercent)
+		}
+
+		if change, ok := changes[item.ID]; ok {
+			delete(changes, item.ID)
+			if err := line.apply(change, in.DiscountPercent); err != nil {
+				return nil, err
+			}
+		}
+		lines = append(lines, line)
+	}
+	for itemID := range changes {
+		return nil, fmt.Errorf("%w: item %d não pertence à cotação", errors.ErrInvalidQuotationSimulation, itemID)
+	}
+	return lines, nil
+}
+
+// apply aplica à linha as alterações informadas para ela. Sem desconto na alteração, o desconto
+// geral (discountPercent) é recalculado sobre o novo bruto e o desconto gravado acompanha a
+// quantidade.
+func (l *SimulatedLine) apply(change QuotationSimulationLine, discountPercent *float64) error {
+	if change.Quantity != nil {
+		if *change.Quantity <= 0 {
+			return fmt.Errorf("%w: a quantidade do item %d deve ser positiva", errors.ErrInvalidQuotationSimulation, l.ItemID)
+		}
+		if l.Quantity > 0 && discountPercent == nil && change.Discount == nil && change.DiscountPercent == nil {
+			l.Discount = money.Round(l.Discount.MulInt(*change.Quantity).DivInt(l.Quantity))
+		}
+		l.Quantity = *change.Quantity
+	}
+	if change.UnitPrice != nil {
+		if change.UnitPrice.IsNegative() {
+			return fmt.Errorf("%w: o preço do item %d não pode ser negativo", errors.ErrInvalidQuotationSimulation, l.ItemID)
+		}
+		l.UnitPrice = *change.UnitPrice
+	}
+
+	gross := l.UnitPrice.MulInt(l.Quantity)
+	switch {
+	case change.Discount != nil && change.DiscountPercent != nil:
+		return fmt.Errorf("%w: informe discount ou discount_percent no item %d", errors.ErrInvalidQuotationSimulation, l.ItemID)
+	case change.Discount != nil:
+		l.Discount = money.Round(*change.Discount)
+	case change.DiscountPercent != nil:
+		if *change.DiscountPercent < 0 || *change.DiscountPercent >= 100 {
+			return fmt.Errorf("%w: discount_percent do item %d deve estar entre 0 e 100", errors.ErrInvalidQuotationSimulation, l.ItemID)
+		}
+		l.Discount = percentOf(gross, *change.DiscountPercent)
+	case discountPercent != nil:
+		l.Discount = percentOf(gross, *discountPercent)
+	}
+	if l.Discount.IsNegative() || l.Discount.GreaterThan(gross) {
+		return fmt.Errorf("%w: o desconto do item %d deve estar entre zero e o valor bruto", errors.ErrInvalidQuotationSimulation, l.ItemID)
+	}
+
+	if change.UnitCost != nil {
+		if change.UnitCost.IsNegative() {
+			return fmt.Errorf("%w: o custo do item %d não pode ser negativo", errors.ErrInvalidQuotationSimulation, l.ItemID)
+		}
+		unitCost := *change.UnitCost
+		l.unitCost = &unitCost
+	}
+	return nil
+}
+
+// StockQuantities retorna a quantidade atual e a simulada da linha em unidades de estoque, para
+// estimar o custo
+func (l SimulatedLine) StockQuantities() (current, simulated int) {
+	return l.item.BaseQuantity(), product.ToBase(l.Quantity, l.item.UnitFactor)
+}
+
+// ApplyCosts calcula a margem atual com o custo do estoque atual e a simulada com o custo do
+// estoque da quantidade simulada, reajustado em costChangePercent (ou o custo informado na linha)
+func (l *SimulatedLine) ApplyCosts(current, simulated money.Decimal, method string, costChangePercent float64) {
+	l.CostMethod = method
+	l.Current = newMarginSummary(LineTotal(l.item.Quantity, l.item.UnitPrice, l.item.Discount, money.Zero), current)
+
+	cost := money.Round(simulated.MulFloat(1 + costChangePercent/100))
+	if l.unitCost != nil {
+		cost = money.Round(l.unitCost.MulInt(l.Quantity))
+	}
+	if l.Quantity > 0 {
+		l.UnitCost = money.Round(cost.DivInt(l.Quantity))
+	}
+	l.Simulated = newMarginSummary(LineTotal(l.Quantity, l.UnitPrice, l.Discount, money.Zero), cost)
+}
+
+// SummarizeSimulation soma as linhas já custeadas nos totais atuais e simulados da cotação
+func SummarizeSimulation(quotation *Quotation, lines []SimulatedLine, costChangePercent float64) *QuotationSimulation {
+	simulation := &QuotationSimulation{
+		QuotationID:       quotation.ID,
+		QuotationNo:       quotation.QuotationNo,
+		CostChangePercent: costChangePercent,
+		Lines:             lines,
+	}
+
+	var currentRevenue, currentCost, simulatedRevenue, simulatedCost money.Decimal
+	var currentTotals, simulatedTotals DocumentTotals
+	for _, line := range lines {
+		currentRevenue = currentRevenue.Add(line.Current.Revenue)
+		currentCost = currentCost.Add(line.Current.Cost)
+		simulatedRevenue = simulatedRevenue.Add(line.Simulated.Revenue)
+		simulatedCost = simulatedCost.Add(line.Simulated.Cost)
+		currentTotals.Add(line.item.Quantity, line.item.UnitPrice, line.item.Discount, line.item.Tax)
+		simulatedTotals.Add(line.Quantity, line.UnitPrice, line.Discount, line.Tax)
+	}
+
+	simulation.Current = newMarginSummary(currentRevenue, currentCost)
+	simulation.Simulated = newMarginSummary(simulatedRevenue, simulatedCost)
+	simulation.MarginChange = simulation.Simulated.Margin.Sub(simulation.Current.Margin)
+	simulation.GrandTotal = currentTotals.GrandTotal
+	simulation.SimulatedGrandTotal = simulatedTotals.GrandTotal
+	return simulation
+}
+
+// newMarginSummary calcula a margem da receita e do custo; sem receita, o percentual fica zerado
+func newMarginSummary(revenue, cost money.Decimal) MarginSummary {
+	summary := MarginSummary{Revenue: money.Round(revenue), Cost: money.Round(cost)}
+	summary.Margin = summary.Revenue.Sub(summary.Cost)
+	if summary.Revenue.IsPositive() {
+		summary.MarginPercent = math.Round(summary.Margin.Float64()/summary.Revenue.Float64()*10000) / 100
+	}
+	return summary
+}
+
+// percentOf retorna percent% do valor, arredondado nas casas da moeda
+func percentOf(value money.Decimal, percent float64) money.Decimal {
+	return money.Round(value.MulFloat(percent / 100))
+}
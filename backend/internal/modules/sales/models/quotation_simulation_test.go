@@ -0,0 +1,95 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func simulationQuotation() *Quotation {
+	return &Quotation{
+		ID: 12, QuotationNo: "QT-12",
+		Items: []QuotationItem{
+			{ID: 1, ProductID: 100, ProductName: "Notebook", Quantity: 2, UnitFactor: 1, UnitPrice: money.FromInt(1500), Discount: money.FromInt(100), Tax: money.FromInt(50)},
+			{ID: 2, ProductID: 200, ProductName: "Mouse", Quantity: 3, Unit: "CX", UnitFactor: 10, UnitPrice: money.FromInt(200)},
+		},
+	}
+}
+
+func TestPrepareSimulation(t *testing.T) {
+	ten := 10.0
+	quantity := 4
+	price := money.FromInt(1400)
+	lines, err := QuotationSimulationInput{
+		DiscountPercent: &ten,
+		Lines:           []QuotationSimulationLine{{ItemID: 1, Quantity: &quantity, UnitPrice: &price}},
+	}.PrepareSimulation(simulationQuotation())
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, 4, lines[0].Quantity)
+	assert.Equal(t, "560.00", lines[0].Discount.String(), "10% do novo bruto (4 x 1400)")
+	assert.Equal(t, "60.00", lines[1].Discount.String(), "desconto geral vale nas demais linhas")
+	current, simulated := lines[1].StockQuantities()
+	assert.Equal(t, 30, current, "caixas de 10 em unidades de estoque")
+	assert.Equal(t, 30, simulated)
+
+	// Sem desconto geral, o desconto gravado acompanha a quantidade
+	lines, err = QuotationSimulationInput{
+		Lines: []QuotationSimulationLine{{ItemID: 1, Quantity: &quantity}},
+	}.PrepareSimulation(simulationQuotation())
+	require.NoError(t, err)
+	assert.Equal(t, "200.00", lines[0].Discount.String())
+
+	hundred := 100.0
+	negative := money.FromInt(-1)
+	tooMuch := money.FromInt(5000)
+	zero := 0
+	invalid := []QuotationSimulationInput{
+		{DiscountPercent: &hundred},
+		{CostChangePercent: -100},
+		{Lines: []QuotationSimulationLine{{ItemID: 9}}},
+		{Lines: []QuotationSimulationLine{{ItemID: 1}, {ItemID: 1}}},
+		{Lines: []QuotationSimulationLine{{ItemID: 1, Quantity: &zero}}},
+		{Lines: []QuotationSimulationLine{{ItemID: 1, UnitPrice: &negative}}},
+		{Lines: []QuotationSimulationLine{{ItemID: 1, Discount: &tooMuch}}},
+		{Lines: []QuotationSimulationLine{{ItemID: 1, Discount: &tooMuch, DiscountPercent: &ten}}},
+		{Lines: []QuotationSimulationLine{{ItemID: 1, UnitCost: &negative}}},
+	}
+	for _, input := range invalid {
+		_, err := input.PrepareSimulation(simulationQuotation())
+		assert.True(t, stderrors.Is(err, errors.ErrInvalidQuotationSimulation), "%+v: %v", input, err)
+	}
+}
+
+func TestSummarizeSimulation(t *testing.T) {
+	quotation := simulationQuotation()
+	unitCost := money.FromInt(1000)
+	lines, err := QuotationSimulationInput{
+		CostChangePercent: 10,
+		Lines:             []QuotationSimulationLine{{ItemID: 1, UnitCost: &unitCost}},
+	}.PrepareSimulation(quotation)
+	require.NoError(t, err)
+
+	lines[0].ApplyCosts(money.FromInt(1800), money.FromInt(1800), "fifo", 10)
+	lines[1].ApplyCosts(money.FromInt(300), money.FromInt(300), "average", 10)
+
+	assert.Equal(t, "2900.00", lines[0].Current.Revenue.String(), "bruto menos desconto, sem imposto")
+	assert.Equal(t, "1100.00", lines[0].Current.Margin.String())
+	assert.Equal(t, 37.93, lines[0].Current.MarginPercent)
+	assert.Equal(t, "2000.00", lines[0].Simulated.Cost.String(), "custo informado na linha prevalece")
+	assert.Equal(t, "330.00", lines[1].Simulated.Cost.String(), "custo do estoque reajustado em 10%")
+	assert.Equal(t, "110.00", lines[1].UnitCost.String(), "por caixa")
+
+	simulation := SummarizeSimulation(quotation, lines, 10)
+	assert.Equal(t, "3500.00", simulation.Current.Revenue.String())
+	assert.Equal(t, "1400.00", simulation.Current.Margin.String())
+	assert.Equal(t, "1170.00", simulation.Simulated.Margin.String())
+	assert.Equal(t, "-230.00", simulation.MarginChange.String())
+	assert.Equal(t, "3550.00", simulation.GrandTotal.String())
+	assert.Equal(t, "3550.00", simulation.SimulatedGrandTotal.String())
+}
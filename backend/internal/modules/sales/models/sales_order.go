@@ -2,27 +2,48 @@ package models
 
 import (
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
 	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // SalesOrder represents a sales order from a client
 type SalesOrder struct {
-	ID              int       `json:"id" gorm:"primaryKey"`
-	SONo            string    `json:"so_no" validate:"required" gorm:"uniqueIndex"`
-	QuotationID     int       `json:"quotation_id" gorm:"index"`
-	ContactID       int       `json:"contact_id" validate:"required" gorm:"index"`
-	Status          string    `json:"status" validate:"required" gorm:"default:draft"`
-	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	ExpectedDate    time.Time `json:"expected_date"`
-	SubTotal        float64   `json:"subtotal" gorm:"column:subtotal"`
-	TaxTotal        float64   `json:"tax_total" gorm:"column:tax_total"`
-	DiscountTotal   float64   `json:"discount_total" gorm:"column:discount_total"`
-	GrandTotal      float64   `json:"grand_total" gorm:"column:grand_total"`
-	Notes           string    `json:"notes"`
-	PaymentTerms    string    `json:"payment_terms"`
-	ShippingAddress string    `json:"shipping_address"`
+	ID              int            `json:"id" gorm:"primaryKey"`
+	CompanyID       int            `json:"company_id" gorm:"<-:create"`
+	SONo            string         `json:"so_no" validate:"required" gorm:"uniqueIndex"`
+	QuotationID     int            `json:"quotation_id" gorm:"index"`
+	ContactID       int            `json:"contact_id" validate:"required" gorm:"index"`
+	SalespersonID   *int           `json:"salesperson_id,omitempty" gorm:"index"`
+	Status          string         `json:"status" validate:"required" gorm:"default:draft"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ExpectedDate    time.Time      `json:"expected_date"`
+	SubTotal        money.Decimal  `json:"subtotal" gorm:"column:subtotal"`
+	TaxTotal        money.Decimal  `json:"tax_total" gorm:"column:tax_total"`
+	DiscountTotal   money.Decimal  `json:"discount_total" gorm:"column:discount_total"`
+	GrandTotal      money.Decimal  `json:"grand_total" gorm:"column:grand_total"`
+	Notes           string         `json:"notes"`
+	PaymentTerms    string         `json:"payment_terms"`
+	ShippingAddress string         `json:"shipping_address"`
+	// Endereços do catálogo do contato; os textos guardam o endereço como estava na emissão
+	ShippingAddressID *int   `json:"shipping_address_id,omitempty"`
+	BillingAddressID  *int   `json:"billing_address_id,omitempty"`
+	BillingAddress    string `json:"billing_address"`
+
+	// Liberação do pedido retido pelo limite de crédito
+	CreditApprovedBy *string    `json:"credit_approved_by,omitempty"`
+	CreditApprovedAt *time.Time `json:"credit_approved_at,omitempty"`
+
+	// Frete escolhido (ver ShippingOption)
+	ShippingOption
+
+	// Campos personalizados definidos pelo administrador (ver customfields)
+	CustomFields customfields.Values `json:"custom_fields" gorm:"type:jsonb"`
 
 	// Relationships
 	Contact   *contact.Contact `json:"contact,omitempty" gorm:"foreignKey:ContactID"`
@@ -32,17 +53,20 @@ type SalesOrder struct {
 
 // SOItem represents items in a sales order
 type SOItem struct {
-	ID           int     `json:"id" gorm:"primaryKey"`
-	SalesOrderID int     `json:"sales_order_id" gorm:"index"`
-	ProductID    int     `json:"product_id" validate:"required" gorm:"index"`
-	ProductName  string  `json:"product_name"`
-	ProductCode  string  `json:"product_code"`
-	Description  string  `json:"description"`
-	Quantity     int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice    float64 `json:"unit_price" validate:"required,gt=0"`
-	Discount     float64 `json:"discount" gorm:"default:0"`
-	Tax          float64 `json:"tax" gorm:"default:0"`
-	Total        float64 `json:"total"`
+	ID           int           `json:"id" gorm:"primaryKey"`
+	SalesOrderID int           `json:"sales_order_id" gorm:"index"`
+	ProductID    int           `json:"product_id" validate:"required" gorm:"index"`
+	KitProductID *int          `json:"kit_product_id,omitempty"`
+	ProductName  string        `json:"product_name"`
+	ProductCode  string        `json:"product_code"`
+	Description  string        `json:"description"`
+	Quantity     int           `json:"quantity" validate:"required,gt=0"`
+	Unit         string        `json:"unit" gorm:"default:UN"`
+	UnitFactor   int           `json:"unit_factor" gorm:"default:1"`
+	UnitPrice    money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	Discount     money.Decimal `json:"discount" gorm:"default:0"`
+	Tax          money.Decimal `json:"tax" gorm:"default:0"`
+	Total        money.Decimal `json:"total"`
 
 	// Relationships
 	Product    *product.Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
@@ -4,21 +4,22 @@ package models
 import (
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
 	"time"
 )
 
 // SalesItem represents an item in a quotation, SO, or PO
 type SalesItem struct {
-	ID          int     `json:"id" gorm:"primaryKey"`
-	ProductID   int     `json:"product_id" validate:"required" gorm:"index"`
-	ProductName string  `json:"product_name"`
-	ProductCode string  `json:"product_code"`
-	Description string  `json:"description"`
-	Quantity    int     `json:"quantity" validate:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" validate:"required,gt=0"`
-	Discount    float64 `json:"discount" gorm:"default:0"`
-	Tax         float64 `json:"tax" gorm:"default:0"`
-	Total       float64 `json:"total"`
+	ID          int           `json:"id" gorm:"primaryKey"`
+	ProductID   int           `json:"product_id" validate:"required" gorm:"index"`
+	ProductName string        `json:"product_name"`
+	ProductCode string        `json:"product_code"`
+	Description string        `json:"description"`
+	Quantity    int           `json:"quantity" validate:"required,gt=0"`
+	UnitPrice   money.Decimal `json:"unit_price" validate:"required,gt=0"`
+	Discount    money.Decimal `json:"discount" gorm:"default:0"`
+	Tax         money.Decimal `json:"tax" gorm:"default:0"`
+	Total       money.Decimal `json:"total"`
 
 	// Relationships (not stored in DB)
 	Product *product.Product `json:"product,omitempty" gorm:"-"`
@@ -26,14 +27,19 @@ type SalesItem struct {
 
 // SalesProcess represents the full sales process linking all documents
 type SalesProcess struct {
-	ID         int       `json:"id" gorm:"primaryKey"`
-	ContactID  int       `json:"contact_id" validate:"required" gorm:"index"`
-	Status     string    `json:"status" validate:"required"`
-	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	TotalValue float64   `json:"total_value"`
-	Profit     float64   `json:"profit"`
-	Notes      string    `json:"notes"`
+	ID         int           `json:"id" gorm:"primaryKey"`
+	CompanyID  int           `json:"company_id" gorm:"<-:create"`
+	ContactID  int           `json:"contact_id" validate:"required" gorm:"index"`
+	Status     string        `json:"status" validate:"required"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	TotalValue money.Decimal `json:"total_value"`
+	Profit     money.Decimal `json:"profit"`
+	Notes      string        `json:"notes"`
+
+	// SLA de conclusão, calculado periodicamente pelo módulo sla (vazio sem SLA configurado)
+	SLAStatus *string    `json:"sla_status,omitempty" gorm:"column:sla_status"`
+	SLADueAt  *time.Time `json:"sla_due_at,omitempty" gorm:"column:sla_due_at"`
 
 	// Relationships
 	Contact       *contact.Contact `json:"contact,omitempty" gorm:"foreignKey:ContactID"`
@@ -0,0 +1,36 @@
+package models
+
+import "ERP-ONSMART/backend/internal/money"
+
+// ShippingOption é a opção de frete escolhida na cotação ou no pedido de venda, a partir da
+// cotação nas transportadoras ou informada manualmente. O custo entra na lucratividade do
+// processo de venda e a transportadora e o serviço passam para a entrega gerada do pedido.
+type ShippingOption struct {
+	ShippingCarrier     string        `json:"shipping_carrier"`
+	ShippingServiceCode string        `json:"shipping_service_code"`
+	ShippingService     string        `json:"shipping_service"`
+	ShippingCost        money.Decimal `json:"shipping_cost"`
+	ShippingDays        int           `json:"shipping_days"`
+}
+
+// Columns retorna as colunas da opção de frete para atualização
+func (o ShippingOption) Columns() map[string]interface{} {
+	return map[string]interface{}{
+		"shipping_carrier":      o.ShippingCarrier,
+		"shipping_service_code": o.ShippingServiceCode,
+		"shipping_service":      o.ShippingService,
+		"shipping_cost":         o.ShippingCost,
+		"shipping_days":         o.ShippingDays,
+	}
+}
+
+// CanChangeShipping indica se o frete da cotação pode ser alterado: só antes de aceita ou encerrada
+func (q *Quotation) CanChangeShipping() bool {
+	return q.Status == QuotationStatusDraft || q.Status == QuotationStatusSent
+}
+
+// CanChangeShipping indica se o frete do pedido pode ser alterado: não em pedidos concluídos ou
+// cancelados
+func (so *SalesOrder) CanChangeShipping() bool {
+	return so.Status != SOStatusCompleted && so.Status != SOStatusCancelled
+}
@@ -0,0 +1,95 @@
+package models
+
+// StatusMachine descreve os status de um documento, na ordem do fluxo, e as transições
+// permitidas a partir de cada um; os status sem transições são finais
+type StatusMachine struct {
+	Statuses    []string
+	Transitions map[string][]string
+}
+
+// CanTransition indica se o documento pode passar do status atual para o novo status
+func (m StatusMachine) CanTransition(from, to string) bool {
+	for _, allowed := range m.Transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal indica se o status não tem transições
+func (m StatusMachine) IsFinal(status string) bool {
+	return len(m.Transitions[status]) == 0
+}
+
+// QuotationStatuses é o fluxo das cotações: a conversão em pedido aceita a cotação em rascunho
+// ou enviada, e o cliente aceita ou recusa a cotação enviada pelo portal
+var QuotationStatuses = StatusMachine{
+	Statuses: []string{
+		QuotationStatusDraft, QuotationStatusSent, QuotationStatusAccepted,
+		QuotationStatusRejected, QuotationStatusExpired, QuotationStatusCancelled,
+	},
+	Transitions: map[string][]string{
+		QuotationStatusDraft:   {QuotationStatusSent, QuotationStatusAccepted, QuotationStatusCancelled},
+		QuotationStatusSent:    {QuotationStatusAccepted, QuotationStatusRejected, QuotationStatusExpired, QuotationStatusCancelled},
+		QuotationStatusExpired: {QuotationStatusCancelled},
+	},
+}
+
+// SalesOrderStatuses é o fluxo dos pedidos de venda: o pedido acima do limite de crédito fica
+// bloqueado até a liberação, e a primeira entrega ou fatura coloca o confirmado em andamento
+var SalesOrderStatuses = StatusMachine{
+	Statuses: []string{
+		SOStatusDraft, SOStatusCreditHold, SOStatusConfirmed,
+		SOStatusProcessing, SOStatusCompleted, SOStatusCancelled,
+	},
+	Transitions: map[string][]string{
+		SOStatusDraft:      {SOStatusCreditHold, SOStatusConfirmed, SOStatusCancelled},
+		SOStatusCreditHold: {SOStatusConfirmed, SOStatusCancelled},
+		SOStatusConfirmed:  {SOStatusProcessing, SOStatusCompleted, SOStatusCancelled},
+		SOStatusProcessing: {SOStatusCompleted, SOStatusCancelled},
+	},
+}
+
+// PurchaseOrderStatuses é o fluxo dos pedidos de compra, até o recebimento das mercadorias
+var PurchaseOrderStatuses = StatusMachine{
+	Statuses: []string{POStatusDraft, POStatusSent, POStatusConfirmed, POStatusReceived, POStatusCancelled},
+	Transitions: map[string][]string{
+		POStatusDraft:     {POStatusSent, POStatusConfirmed, POStatusCancelled},
+		POStatusSent:      {POStatusConfirmed, POStatusReceived, POStatusCancelled},
+		POStatusConfirmed: {POStatusReceived, POStatusCancelled},
+	},
+}
+
+// DeliveryStatuses é o fluxo das entregas: a separação e a embalagem antecedem o despacho das
+// entregas de pedido de venda; os recebimentos de pedido de compra são despachados pendentes
+var DeliveryStatuses = StatusMachine{
+	Statuses: []string{
+		DeliveryStatusPending, DeliveryStatusPicking, DeliveryStatusPacked,
+		DeliveryStatusShipped, DeliveryStatusDelivered, DeliveryStatusReturned,
+	},
+	Transitions: map[string][]string{
+		DeliveryStatusPending:   {DeliveryStatusPicking, DeliveryStatusShipped},
+		DeliveryStatusPicking:   {DeliveryStatusPacked},
+		DeliveryStatusPacked:    {DeliveryStatusShipped},
+		DeliveryStatusShipped:   {DeliveryStatusDelivered, DeliveryStatusReturned},
+		DeliveryStatusDelivered: {DeliveryStatusReturned},
+	},
+}
+
+// InvoiceStatuses é o fluxo das faturas: depois de emitida, o status segue os pagamentos e as
+// parcelas (InvoicePaymentStatus e InstallmentsInvoiceStatus) e volta a emitida quando os
+// pagamentos são estornados
+var InvoiceStatuses = StatusMachine{
+	Statuses: []string{
+		InvoiceStatusDraft, InvoiceStatusSent, InvoiceStatusPartial,
+		InvoiceStatusPaid, InvoiceStatusOverdue, InvoiceStatusCancelled,
+	},
+	Transitions: map[string][]string{
+		InvoiceStatusDraft:   {InvoiceStatusSent, InvoiceStatusCancelled},
+		InvoiceStatusSent:    {InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusOverdue, InvoiceStatusCancelled},
+		InvoiceStatusPartial: {InvoiceStatusSent, InvoiceStatusPaid, InvoiceStatusOverdue, InvoiceStatusCancelled},
+		InvoiceStatusOverdue: {InvoiceStatusSent, InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusCancelled},
+		InvoiceStatusPaid:    {InvoiceStatusSent, InvoiceStatusPartial},
+	},
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusMachinesReferenceKnownStatuses(t *testing.T) {
+	machines := map[string]StatusMachine{
+		"quotation":      QuotationStatuses,
+		"sales_order":    SalesOrderStatuses,
+		"purchase_order": PurchaseOrderStatuses,
+		"delivery":       DeliveryStatuses,
+		"invoice":        InvoiceStatuses,
+	}
+	for name, machine := range machines {
+		known := make(map[string]bool, len(machine.Statuses))
+		for _, status := range machine.Statuses {
+			known[status] = true
+		}
+		for from, targets := range machine.Transitions {
+			assert.True(t, known[from], "%s: %s", name, from)
+			for _, to := range targets {
+				assert.True(t, known[to], "%s: %s -> %s", name, from, to)
+				assert.NotEqual(t, from, to, name)
+			}
+		}
+	}
+}
+
+func TestInvoiceDerivedStatusesAreAllowedTransitions(t *testing.T) {
+	total := money.FromInt(100)
+	for _, current := range []string{InvoiceStatusSent, InvoiceStatusPartial, InvoiceStatusPaid, InvoiceStatusOverdue} {
+		for _, paid := range []money.Decimal{money.Zero, money.FromInt(40), total} {
+			next := InvoicePaymentStatus(current, paid, total)
+			assert.True(t, next == current || InvoiceStatuses.CanTransition(current, next), "%s -> %s", current, next)
+		}
+	}
+	assert.True(t, InvoiceStatuses.IsFinal(InvoiceStatusCancelled))
+	assert.False(t, InvoiceStatuses.IsFinal(InvoiceStatusPaid), "o estorno do pagamento reabre a fatura")
+}
+
+func TestSalesOrderCreditHoldFlow(t *testing.T) {
+	assert.True(t, SalesOrderStatuses.CanTransition(SOStatusDraft, SOStatusCreditHold))
+	assert.True(t, SalesOrderStatuses.CanTransition(SOStatusCreditHold, SOStatusConfirmed))
+	assert.False(t, SalesOrderStatuses.CanTransition(SOStatusCreditHold, SOStatusProcessing))
+	assert.False(t, DeliveryStatuses.CanTransition(DeliveryStatusPicking, DeliveryStatusShipped), "a entrega em separação precisa ser embalada")
+}
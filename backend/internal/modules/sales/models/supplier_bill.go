@@ -0,0 +1,68 @@
+package models
+
+import (
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/money"
+	"time"
+)
+
+// SupplierBill represents a bill received from a supplier (accounts payable)
+type SupplierBill struct {
+	ID              int           `json:"id" gorm:"primaryKey"`
+	CompanyID       int           `json:"company_id" gorm:"<-:create"`
+	BillNo          string        `json:"bill_no" validate:"required" gorm:"uniqueIndex"`
+	PurchaseOrderID int           `json:"purchase_order_id,omitempty" gorm:"index"`
+	ContactID       int           `json:"contact_id" validate:"required" gorm:"index"`
+	CostCenterID    *int          `json:"cost_center_id,omitempty"`
+	Status          string        `json:"status" validate:"required" gorm:"default:open"`
+	CreatedAt       time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	IssueDate       time.Time     `json:"issue_date"`
+	DueDate         time.Time     `json:"due_date" validate:"required"`
+	SubTotal        money.Decimal `json:"subtotal" gorm:"column:subtotal"`
+	TaxTotal        money.Decimal `json:"tax_total" gorm:"column:tax_total"`
+	GrandTotal      money.Decimal `json:"grand_total" gorm:"column:grand_total"`
+	AmountPaid      money.Decimal `json:"amount_paid" gorm:"default:0"`
+	Notes           string        `json:"notes"`
+	// Chave de acesso da NF-e que originou a conta, quando importada
+	NFeKey string `json:"nfe_key,omitempty" gorm:"column:nfe_key"`
+
+	// Conferência de três vias (pedido, recebimento e conta) das contas ligadas a um pedido de
+	// compra; ver BillMatchAllowsApproval
+	MatchStatus     string     `json:"match_status" gorm:"default:not_required"`
+	MatchedAt       *time.Time `json:"matched_at,omitempty"`
+	MatchReviewedBy string     `json:"match_reviewed_by,omitempty"`
+	MatchReviewedAt *time.Time `json:"match_reviewed_at,omitempty"`
+	MatchNotes      string     `json:"match_notes,omitempty"`
+
+	// Relationships
+	Contact       *contact.Contact   `json:"contact,omitempty" gorm:"foreignKey:ContactID"`
+	PurchaseOrder *PurchaseOrder     `json:"purchase_order,omitempty" gorm:"foreignKey:PurchaseOrderID"`
+	Items         []SupplierBillItem `json:"items,omitempty" gorm:"foreignKey:SupplierBillID"`
+}
+
+// SupplierBillItem é um item cobrado na conta a pagar (da NF-e), ligado ao item do pedido de
+// compra correspondente para a conferência de três vias
+type SupplierBillItem struct {
+	ID             int           `json:"id" gorm:"primaryKey"`
+	CompanyID      int           `json:"company_id" gorm:"<-:create"`
+	SupplierBillID int           `json:"supplier_bill_id"`
+	POItemID       *int          `json:"po_item_id,omitempty" gorm:"column:po_item_id"`
+	ProductCode    string        `json:"product_code"`
+	Description    string        `json:"description"`
+	Unit           string        `json:"unit"`
+	Quantity       money.Decimal `json:"quantity"`
+	UnitPrice      money.Decimal `json:"unit_price"`
+	Total          money.Decimal `json:"total"`
+}
+
+// BillMatchAllowsApproval indica se a conferência de três vias libera a aprovação e o pagamento
+// da conta: sem pedido de compra ela não se aplica; com pedido, a conta precisa ter conferido
+// dentro da tolerância ou ter as divergências aceitas pelo comprador
+func BillMatchAllowsApproval(status string) bool {
+	switch status {
+	case BillMatchNotRequired, BillMatchMatched, BillMatchAccepted:
+		return true
+	}
+	return false
+}
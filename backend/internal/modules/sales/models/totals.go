@@ -0,0 +1,167 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// Os totais de cotações, pedidos e faturas são sempre derivados dos itens no servidor: o total
+// de cada linha é quantidade x preço - desconto + imposto, e o documento soma as linhas. Os
+// valores enviados pelo cliente só são conferidos; zero vale como não informado.
+
+// pricedLine é a linha de documento que entra no cálculo dos totais
+type pricedLine[T any] interface {
+	*T
+	amounts() (quantity int, unitPrice, discount, tax money.Decimal)
+	lineTotal() *money.Decimal
+}
+
+func (i *QuotationItem) amounts() (int, money.Decimal, money.Decimal, money.Decimal) {
+	return i.Quantity, i.UnitPrice, i.Discount, i.Tax
+}
+func (i *QuotationItem) lineTotal() *money.Decimal { return &i.Total }
+
+func (i *SOItem) amounts() (int, money.Decimal, money.Decimal, money.Decimal) {
+	return i.Quantity, i.UnitPrice, i.Discount, i.Tax
+}
+func (i *SOItem) lineTotal() *money.Decimal { return &i.Total }
+
+func (i *InvoiceItem) amounts() (int, money.Decimal, money.Decimal, money.Decimal) {
+	return i.Quantity, i.UnitPrice, i.Discount, i.Tax
+}
+func (i *InvoiceItem) lineTotal() *money.Decimal { return &i.Total }
+
+func (i *POItem) amounts() (int, money.Decimal, money.Decimal, money.Decimal) {
+	return i.Quantity, i.UnitPrice, i.Discount, i.Tax
+}
+func (i *POItem) lineTotal() *money.Decimal { return &i.Total }
+
+// recalculateLines grava o total de cada linha e devolve os totais do documento. Com check, uma
+// linha com total informado diferente do calculado devolve ErrTotalsMismatch.
+func recalculateLines[T any, L pricedLine[T]](items []T, check bool) (DocumentTotals, error) {
+	var totals DocumentTotals
+	for i := range items {
+		line := L(&items[i])
+		quantity, unitPrice, discount, tax := line.amounts()
+		total := LineTotal(quantity, unitPrice, discount, tax)
+		if check && !line.lineTotal().IsZero() && !line.lineTotal().Equal(total) {
+			return DocumentTotals{}, errors.ErrTotalsMismatch
+		}
+		*line.lineTotal() = total
+		totals.Add(quantity, unitPrice, discount, tax)
+	}
+	return totals, nil
+}
+
+// Verify confere os totais informados pelo cliente com os calculados
+func (t DocumentTotals) Verify(informed DocumentTotals) error {
+	pairs := [][2]money.Decimal{
+		{informed.SubTotal, t.SubTotal},
+		{informed.TaxTotal, t.TaxTotal},
+		{informed.DiscountTotal, t.DiscountTotal},
+		{informed.GrandTotal, t.GrandTotal},
+	}
+	for _, pair := range pairs {
+		if !pair[0].IsZero() && !pair[0].Equal(pair[1]) {
+			return errors.ErrTotalsMismatch
+		}
+	}
+	return nil
+}
+
+// applyTotals calcula os totais das linhas, confere os informados quando check e os devolve
+func applyTotals[T any, L pricedLine[T]](items []T, informed DocumentTotals, check bool) (DocumentTotals, error) {
+	totals, err := recalculateLines[T, L](items, check)
+	if err != nil {
+		return DocumentTotals{}, err
+	}
+	if check {
+		if err := totals.Verify(informed); err != nil {
+			return DocumentTotals{}, err
+		}
+	}
+	return totals, nil
+}
+
+// ApplyQuotationTotals confere os totais informados na cotação e grava os calculados dos itens
+func ApplyQuotationTotals(quotation *Quotation) error {
+	totals, err := applyTotals(quotation.Items, quotation.totals(), true)
+	if err != nil {
+		return err
+	}
+	quotation.setTotals(totals)
+	return nil
+}
+
+// RecalculateQuotationTotals grava os totais da cotação calculados dos itens, sem conferência
+func RecalculateQuotationTotals(quotation *Quotation) {
+	totals, _ := applyTotals(quotation.Items, DocumentTotals{}, false)
+	quotation.setTotals(totals)
+}
+
+// ApplySalesOrderTotals confere os totais informados no pedido e grava os calculados dos itens
+func ApplySalesOrderTotals(so *SalesOrder) error {
+	totals, err := applyTotals(so.Items, so.totals(), true)
+	if err != nil {
+		return err
+	}
+	so.applyTotals(totals)
+	return nil
+}
+
+// RecalculateSalesOrderTotals grava os totais do pedido calculados dos itens, sem conferência
+func RecalculateSalesOrderTotals(so *SalesOrder) {
+	totals, _ := applyTotals(so.Items, DocumentTotals{}, false)
+	so.applyTotals(totals)
+}
+
+// ApplyInvoiceTotals confere os totais informados na fatura e grava os calculados dos itens
+func ApplyInvoiceTotals(invoice *Invoice) error {
+	totals, err := applyTotals(invoice.Items, invoice.totals(), true)
+	if err != nil {
+		return err
+	}
+	invoice.setTotals(totals)
+	return nil
+}
+
+// RecalculateInvoiceTotals grava os totais da fatura calculados dos itens, sem conferência
+func RecalculateInvoiceTotals(invoice *Invoice) {
+	totals, _ := applyTotals(invoice.Items, DocumentTotals{}, false)
+	invoice.setTotals(totals)
+}
+
+// RecalculatePurchaseOrderTotals grava os totais do pedido de compra calculados dos itens
+func RecalculatePurchaseOrderTotals(po *PurchaseOrder) {
+	totals, _ := applyTotals(po.Items, DocumentTotals{}, false)
+	po.SubTotal = totals.SubTotal
+	po.TaxTotal = totals.TaxTotal
+	po.DiscountTotal = totals.DiscountTotal
+	po.GrandTotal = totals.GrandTotal
+}
+
+func (q *Quotation) totals() DocumentTotals {
+	return DocumentTotals{SubTotal: q.SubTotal, TaxTotal: q.TaxTotal, DiscountTotal: q.DiscountTotal, GrandTotal: q.GrandTotal}
+}
+
+func (q *Quotation) setTotals(totals DocumentTotals) {
+	q.SubTotal = totals.SubTotal
+	q.TaxTotal = totals.TaxTotal
+	q.DiscountTotal = totals.DiscountTotal
+	q.GrandTotal = totals.GrandTotal
+}
+
+func (so *SalesOrder) totals() DocumentTotals {
+	return DocumentTotals{SubTotal: so.SubTotal, TaxTotal: so.TaxTotal, DiscountTotal: so.DiscountTotal, GrandTotal: so.GrandTotal}
+}
+
+func (inv *Invoice) totals() DocumentTotals {
+	return DocumentTotals{SubTotal: inv.SubTotal, TaxTotal: inv.TaxTotal, DiscountTotal: inv.DiscountTotal, GrandTotal: inv.GrandTotal}
+}
+
+func (inv *Invoice) setTotals(totals DocumentTotals) {
+	inv.SubTotal = totals.SubTotal
+	inv.TaxTotal = totals.TaxTotal
+	inv.DiscountTotal = totals.DiscountTotal
+	inv.GrandTotal = totals.GrandTotal
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySalesOrderTotalsFillsMissingTotals(t *testing.T) {
+	so := &SalesOrder{
+		Items: []SOItem{
+			{ProductID: 1, Quantity: 2, UnitPrice: money.FromInt(100), Discount: money.FromInt(10), Tax: money.FromInt(5)},
+			{ProductID: 2, Quantity: 1, UnitPrice: money.MustParse("49.9"), Total: money.MustParse("49.90")},
+		},
+	}
+
+	require.NoError(t, ApplySalesOrderTotals(so))
+	assert.Equal(t, "195.00", so.Items[0].Total.String())
+	assert.Equal(t, "249.90", so.SubTotal.String())
+	assert.Equal(t, "10.00", so.DiscountTotal.String())
+	assert.Equal(t, "5.00", so.TaxTotal.String())
+	assert.Equal(t, "244.90", so.GrandTotal.String())
+}
+
+func TestApplyTotalsRejectsMismatchedClientTotals(t *testing.T) {
+	quotation := &Quotation{
+		GrandTotal: money.FromInt(150),
+		Items:      []QuotationItem{{ProductID: 1, Quantity: 2, UnitPrice: money.FromInt(100)}},
+	}
+	assert.Equal(t, errors.ErrTotalsMismatch, ApplyQuotationTotals(quotation))
+	assert.Equal(t, "150.00", quotation.GrandTotal.String(), "totais rejeitados não são alterados")
+
+	invoice := &Invoice{
+		Items: []InvoiceItem{{ProductID: 1, Quantity: 2, UnitPrice: money.FromInt(100), Total: money.FromInt(180)}},
+	}
+	assert.Equal(t, errors.ErrTotalsMismatch, ApplyInvoiceTotals(invoice), "total da linha também é conferido")
+
+	invoice.Items[0].Total = money.FromInt(200)
+	invoice.GrandTotal = money.MustParse("200.0000")
+	assert.NoError(t, ApplyInvoiceTotals(invoice))
+}
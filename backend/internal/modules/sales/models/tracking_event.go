@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Status normalizados dos eventos de rastreamento das transportadoras
+const (
+	TrackingStatusPosted         = "posted"
+	TrackingStatusInTransit      = "in_transit"
+	TrackingStatusOutForDelivery = "out_for_delivery"
+	TrackingStatusDelivered      = "delivered"
+	TrackingStatusException      = "exception"
+	TrackingStatusReturned       = "returned"
+)
+
+// DeliveryTrackingEvent é um evento de rastreamento recebido da transportadora para uma entrega
+type DeliveryTrackingEvent struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	DeliveryID  int       `json:"delivery_id" gorm:"index"`
+	Carrier     string    `json:"carrier"`
+	ExternalID  string    `json:"external_id"`
+	EventCode   string    `json:"event_code,omitempty"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	RawPayload  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName define o nome da tabela de eventos de rastreamento
+func (DeliveryTrackingEvent) TableName() string {
+	return "delivery_tracking_events"
+}
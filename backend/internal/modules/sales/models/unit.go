@@ -0,0 +1,104 @@
+package models
+
+import (
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// A quantidade das linhas de cotação, pedido de venda, fatura e pedido de compra fica na unidade
+// da linha (ex.: CX); UnitFactor guarda quantas unidades de estoque a unidade tinha quando a
+// linha foi gravada. Entregas, camadas de custo e CMV trabalham sempre em unidades de estoque.
+
+// BaseQuantity retorna a quantidade da linha em unidades de estoque
+func (i QuotationItem) BaseQuantity() int {
+	return product.ToBase(i.Quantity, i.UnitFactor)
+}
+
+// BaseQuantity retorna a quantidade da linha em unidades de estoque
+func (i SOItem) BaseQuantity() int {
+	return product.ToBase(i.Quantity, i.UnitFactor)
+}
+
+// BaseQuantity retorna a quantidade da linha em unidades de estoque
+func (i InvoiceItem) BaseQuantity() int {
+	return product.ToBase(i.Quantity, i.UnitFactor)
+}
+
+// BaseQuantity retorna a quantidade da linha em unidades de estoque
+func (i POItem) BaseQuantity() int {
+	return product.ToBase(i.Quantity, i.UnitFactor)
+}
+
+// ApplyQuotationUnits resolve a unidade de cada linha da cotação (sem unidade, vale a unidade
+// padrão de venda do produto) e grava o fator vigente
+func ApplyQuotationUnits(items []QuotationItem, units map[int]product.UnitSet) error {
+	for i := range items {
+		unit, err := units[items[i].ProductID].Resolve(items[i].Unit, product.UnitPurposeSales)
+		if err != nil {
+			return err
+		}
+		items[i].Unit, items[i].UnitFactor = unit.Unit, unit.Factor
+	}
+	return nil
+}
+
+// ApplySalesOrderUnits resolve a unidade de cada linha do pedido de venda
+func ApplySalesOrderUnits(items []SOItem, units map[int]product.UnitSet) error {
+	for i := range items {
+		unit, err := units[items[i].ProductID].Resolve(items[i].Unit, product.UnitPurposeSales)
+		if err != nil {
+			return err
+		}
+		items[i].Unit, items[i].UnitFactor = unit.Unit, unit.Factor
+	}
+	return nil
+}
+
+// ApplyInvoiceUnits resolve a unidade de cada linha da fatura
+func ApplyInvoiceUnits(items []InvoiceItem, units map[int]product.UnitSet) error {
+	for i := range items {
+		unit, err := units[items[i].ProductID].Resolve(items[i].Unit, product.UnitPurposeSales)
+		if err != nil {
+			return err
+		}
+		items[i].Unit, items[i].UnitFactor = unit.Unit, unit.Factor
+	}
+	return nil
+}
+
+// ApplyPurchaseOrderUnits resolve a unidade de cada linha do pedido de compra (sem unidade, vale
+// a unidade padrão de compra do produto)
+func ApplyPurchaseOrderUnits(items []POItem, units map[int]product.UnitSet) error {
+	for i := range items {
+		unit, err := units[items[i].ProductID].Resolve(items[i].Unit, product.UnitPurposePurchase)
+		if err != nil {
+			return err
+		}
+		items[i].Unit, items[i].UnitFactor = unit.Unit, unit.Factor
+	}
+	return nil
+}
+
+// ApplyDeliveryUnits converte os itens da entrega para a unidade de estoque. Sem unidade, a
+// quantidade já está em unidades de estoque; com unidade (ex.: CX), é multiplicada pelo fator.
+func ApplyDeliveryUnits(items []DeliveryItem, units map[int]product.UnitSet) error {
+	for i := range items {
+		set := units[items[i].ProductID]
+		unit, err := set.Resolve(items[i].Unit, "")
+		if err != nil {
+			return err
+		}
+		items[i].Quantity = product.ToBase(items[i].Quantity, unit.Factor)
+		items[i].ReceivedQty = product.ToBase(items[i].ReceivedQty, unit.Factor)
+		items[i].Unit = set.Base().Unit
+	}
+	return nil
+}
+
+// baseUnitPrice converte o preço da unidade da linha para o preço por unidade de estoque
+func baseUnitPrice(unitPrice money.Decimal, factor int) money.Decimal {
+	if factor <= 1 {
+		return unitPrice
+	}
+	return unitPrice.DivInt(factor)
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testUnits() map[int]product.UnitSet {
+	return map[int]product.UnitSet{
+		1: {
+			{ProductID: 1, Unit: "UN", Factor: 1},
+			{ProductID: 1, Unit: "CX", Factor: 12, IsPurchase: true, IsSales: true},
+		},
+	}
+}
+
+func TestApplySalesOrderUnits(t *testing.T) {
+	items := []SOItem{
+		{ProductID: 1, Quantity: 2},
+		{ProductID: 1, Unit: "un", Quantity: 5},
+		{ProductID: 9, Quantity: 1},
+	}
+
+	require.NoError(t, ApplySalesOrderUnits(items, testUnits()))
+	assert.Equal(t, "CX", items[0].Unit, "sem unidade vale a unidade padrão de venda")
+	assert.Equal(t, 24, items[0].BaseQuantity())
+	assert.Equal(t, "UN", items[1].Unit)
+	assert.Equal(t, 5, items[1].BaseQuantity())
+	assert.Equal(t, product.DefaultUnit, items[2].Unit, "produto sem unidades usa a unidade padrão")
+	assert.Equal(t, 1, items[2].UnitFactor)
+
+	invalid := []SOItem{{ProductID: 9, Unit: "CX", Quantity: 1}}
+	assert.Equal(t, errors.ErrUnknownUnit, ApplySalesOrderUnits(invalid, testUnits()))
+}
+
+func TestApplyDeliveryUnitsConvertsToStockUnit(t *testing.T) {
+	items := []DeliveryItem{
+		{ProductID: 1, Unit: "CX", Quantity: 2},
+		{ProductID: 1, Quantity: 7},
+	}
+
+	require.NoError(t, ApplyDeliveryUnits(items, testUnits()))
+	assert.Equal(t, 24, items[0].Quantity)
+	assert.Equal(t, "UN", items[0].Unit)
+	assert.Equal(t, 7, items[1].Quantity, "sem unidade a quantidade já está na unidade de estoque")
+}
+
+func TestInvoiceFromSalesOrderKeepsLineUnit(t *testing.T) {
+	so := &SalesOrder{
+		ID: 1,
+		Items: []SOItem{
+			{ProductID: 1, Unit: "CX", UnitFactor: 12, Quantity: 3, UnitPrice: money.FromInt(120)},
+		},
+	}
+
+	// 12 unidades (uma caixa) já faturadas
+	invoice, err := InvoiceFromSalesOrder(so, map[int]int{1: 12})
+	require.NoError(t, err)
+	require.Len(t, invoice.Items, 1)
+	assert.Equal(t, 2, invoice.Items[0].Quantity)
+	assert.Equal(t, "CX", invoice.Items[0].Unit)
+	assert.Equal(t, 12, invoice.Items[0].UnitFactor)
+	assert.Equal(t, "240.00", invoice.GrandTotal.String())
+}
+
+func TestFulfillmentInStockUnits(t *testing.T) {
+	so := &SalesOrder{
+		ID:    1,
+		Items: []SOItem{{ID: 10, ProductID: 1, Unit: "CX", UnitFactor: 12, Quantity: 2}},
+	}
+	soItemID := 10
+	shipped := []ShippedLine{{SOItemID: &soItemID, ProductID: 1, Quantity: 12}}
+
+	f := BuildFulfillment(so, shipped, nil)
+	require.Len(t, f.Items, 1)
+	assert.Equal(t, 24, f.Items[0].OrderedQty)
+	assert.Equal(t, 12, f.Items[0].RemainingQty)
+	assert.Equal(t, "CX", f.Items[0].Unit)
+
+	delivery, err := DeliveryFromSalesOrder(so, f)
+	require.NoError(t, err)
+	assert.Equal(t, 12, delivery.Items[0].Quantity)
+
+	// Mais 13 unidades excedem o saldo de 12
+	index, err := AllocateShipment(so, shipped, []DeliveryItem{{SOItemID: &soItemID, ProductID: 1, Quantity: 13}})
+	assert.Equal(t, errors.ErrOverShipment, err)
+	assert.Equal(t, 1, index)
+}
+
+func TestExplodeKitInBoxes(t *testing.T) {
+	items := []SOItem{{ProductID: 50, Unit: "CX", UnitFactor: 2, Quantity: 1, UnitPrice: money.FromInt(180)}}
+
+	exploded := ExplodeSalesOrderKits(items, testKits())
+	require.Len(t, exploded, 2)
+	assert.Equal(t, 2, exploded[0].Quantity, "uma caixa com dois kits")
+	assert.Equal(t, 4, exploded[1].Quantity)
+	assert.Equal(t, 1, exploded[0].UnitFactor)
+	assert.Equal(t, "180.00", exploded[0].Total.Add(exploded[1].Total).String())
+}
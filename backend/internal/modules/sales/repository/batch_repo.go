@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BatchRepository grava lotes de faturas, pagamentos e alterações de status de entregas
+// recebidos das integrações, em uma única transação por lote
+type BatchRepository interface {
+	CreateInvoices(ctx context.Context, entries []models.BatchEntry[*models.Invoice], atomic bool) ([]models.BatchItemResult, error)
+	CreatePayments(ctx context.Context, entries []models.BatchEntry[*models.Payment], atomic bool) ([]models.BatchItemResult, error)
+	UpdateDeliveryStatuses(ctx context.Context, entries []models.BatchEntry[models.DeliveryStatusChange], atomic bool) ([]models.BatchItemResult, error)
+}
+
+type batchRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewBatchRepository cria uma nova instância do repositório
+func NewBatchRepository() (BatchRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &batchRepository{
+		db:     db,
+		logger: logger.WithModule("batch_repository"),
+	}, nil
+}
+
+// batchOperation grava um item do lote dentro da transação e retorna o seu resultado
+type batchOperation func(tx *gorm.DB, index int) (models.BatchItemResult, error)
+
+// runBatch executa op para cada item em uma única transação. Fora do modo atômico, cada item
+// roda em um savepoint: a falha desfaz apenas as escritas daquele item e o lote continua. No
+// modo atômico, a primeira falha interrompe o lote e desfaz a transação inteira.
+func (r *batchRepository) runBatch(ctx context.Context, indexes []int, atomic bool, op batchOperation) ([]models.BatchItemResult, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+	if tx.Error != nil {
+		return nil, errors.WrapError(tx.Error, "falha ao iniciar transação")
+	}
+
+	results := make([]models.BatchItemResult, 0, len(indexes))
+	failed := false
+	for _, index := range indexes {
+		savepoint := fmt.Sprintf("batch_item_%d", index)
+		if !atomic {
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				tx.Rollback()
+				return nil, errors.WrapError(err, "falha ao criar savepoint do lote")
+			}
+		}
+
+		result, err := op(tx, index)
+		if err == nil {
+			results = append(results, result)
+			continue
+		}
+
+		results = append(results, models.BatchItemFailed(index, err))
+		if atomic {
+			failed = true
+			break
+		}
+		if err := tx.RollbackTo(savepoint).Error; err != nil {
+			tx.Rollback()
+			return nil, errors.WrapError(err, "falha ao desfazer item do lote")
+		}
+	}
+
+	if failed {
+		tx.Rollback()
+		return results, nil
+	}
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit do lote", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+	return results, nil
+}
+
+// indexEntries indexa os itens do lote pela posição na requisição, mantendo a ordem recebida
+func indexEntries[T any](entries []models.BatchEntry[T]) (map[int]T, []int) {
+	byIndex := make(map[int]T, len(entries))
+	indexes := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		byIndex[entry.Index] = entry.Item
+		indexes = append(indexes, entry.Index)
+	}
+	return byIndex, indexes
+}
+
+// CreateInvoices cria as faturas do lote, numeradas em sequência dentro da transação
+func (r *batchRepository) CreateInvoices(ctx context.Context, entries []models.BatchEntry[*models.Invoice], atomic bool) ([]models.BatchItemResult, error) {
+	byIndex, indexes := indexEntries(entries)
+
+	results, err := r.runBatch(ctx, indexes, atomic, func(tx *gorm.DB, index int) (models.BatchItemResult, error) {
+		invoice := byIndex[index]
+		if invoice.SalesOrderID > 0 {
+			var salesOrder models.SalesOrder
+			if err := tx.Select("id", "so_no").First(&salesOrder, invoice.SalesOrderID).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return models.BatchItemResult{}, errors.ErrSalesOrderNotFound
+				}
+				return models.BatchItemResult{}, errors.WrapError(err, "falha ao buscar sales order")
+			}
+			invoice.SONo = salesOrder.SONo
+		}
+
+		if err := contactRepository.EnsureNotBlocked(tx, invoice.ContactID); err != nil {
+			return models.BatchItemResult{}, err
+		}
+
+		items, err := PrepareInvoiceItems(tx, invoice.Items)
+		if err != nil {
+			return models.BatchItemResult{}, err
+		}
+		invoice.Items = items
+
+		invoice.InvoiceNo = NextDocumentNumber(tx, &models.Invoice{}, "INV")
+		if err := tx.Omit(clause.Associations).Create(invoice).Error; err != nil {
+			return models.BatchItemResult{}, errors.WrapError(err, "falha ao criar invoice")
+		}
+		for i := range invoice.Items {
+			invoice.Items[i].InvoiceID = invoice.ID
+		}
+		if err := tx.Omit(clause.Associations).Create(&invoice.Items).Error; err != nil {
+			return models.BatchItemResult{}, errors.WrapError(err, "falha ao criar itens da invoice")
+		}
+		return models.BatchItemSucceeded(index, invoice.ID, invoice.InvoiceNo), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("lote de invoices processado", zap.Int("items", len(entries)), zap.Bool("atomic", atomic))
+	return results, nil
+}
+
+// CreatePayments registra os pagamentos do lote e as suas alocações, recalculando o valor pago e
+// o status das faturas. A fatura é bloqueada para que pagamentos da mesma fatura no lote se acumulem.
+func (r *batchRepository) CreatePayments(ctx context.Context, entries []models.BatchEntry[*models.Payment], atomic bool) ([]models.BatchItemResult, error) {
+	byIndex, indexes := indexEntries(entries)
+
+	results, err := r.runBatch(ctx, indexes, atomic, func(tx *gorm.DB, index int) (models.BatchItemResult, error) {
+		payment := byIndex[index]
+
+		var invoice models.Invoice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, payment.InvoiceID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.BatchItemResult{}, errors.ErrInvoiceNotFound
+			}
+			return models.BatchItemResult{}, errors.WrapError(err, "falha ao buscar invoice")
+		}
+		if invoice.Status == models.InvoiceStatusCancelled {
+			return models.BatchItemResult{}, errors.ErrInvoiceNotPayable
+		}
+
+		allocations := payment.DefaultAllocations(invoice.GrandTotal.Sub(invoice.AmountPaid))
+		if err := tx.Omit(clause.Associations).Create(payment).Error; err != nil {
+			return models.BatchItemResult{}, errors.WrapError(err, "falha ao criar payment")
+		}
+		if err := replaceAllocations(tx, payment, invoice.ContactID, allocations); err != nil {
+			return models.BatchItemResult{}, err
+		}
+		if err := recordInvoiceEvent(tx, payment.InvoiceID, models.PaymentEvent(payment)); err != nil {
+			return models.BatchItemResult{}, err
+		}
+		return models.BatchItemSucceeded(index, payment.ID, ""), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("lote de payments processado", zap.Int("items", len(entries)), zap.Bool("atomic", atomic))
+	return results, nil
+}
+
+// UpdateDeliveryStatuses altera o status das entregas do lote. Ao marcar como enviada, exige que
+// a entrega de pedido de venda esteja embalada e grava o código de rastreamento e a data de envio; ao marcar como entregue, a data de recebimento e as
+// quantidades recebidas dos itens.
+func (r *batchRepository) UpdateDeliveryStatuses(ctx context.Context, entries []models.BatchEntry[models.DeliveryStatusChange], atomic bool) ([]models.BatchItemResult, error) {
+	byIndex, indexes := indexEntries(entries)
+
+	results, err := r.runBatch(ctx, indexes, atomic, func(tx *gorm.DB, index int) (models.BatchItemResult, error) {
+		change := byIndex[index]
+
+		var delivery models.Delivery
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&delivery, change.DeliveryID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.BatchItemResult{}, errors.ErrDeliveryNotFound
+			}
+			return models.BatchItemResult{}, errors.WrapError(err, "falha ao buscar delivery")
+		}
+
+		updateData := map[string]interface{}{"status": change.Status}
+		switch change.Status {
+		case models.DeliveryStatusShipped:
+			if delivery.RequiresPacking() && delivery.Status != models.DeliveryStatusPacked {
+				return models.BatchItemResult{}, errors.ErrDeliveryNotPacked
+			}
+			if change.TrackingNumber != "" {
+				updateData["tracking_number"] = change.TrackingNumber
+			}
+			if delivery.DeliveryDate.IsZero() {
+				updateData["delivery_date"] = time.Now()
+			}
+		case models.DeliveryStatusDelivered:
+			if delivery.ReceivedDate.IsZero() {
+				updateData["received_date"] = time.Now()
+			}
+		}
+		if (change.Status == models.DeliveryStatusShipped || change.Status == models.DeliveryStatusDelivered) && delivery.ShippedAt == nil {
+			updateData["shipped_at"] = time.Now()
+		}
+		if change.Notes != "" {
+			updateData["notes"] = change.Notes
+		}
+
+		if err := tx.Model(&models.Delivery{}).Where("id = ?", delivery.ID).Updates(updateData).Error; err != nil {
+			return models.BatchItemResult{}, errors.WrapError(err, "falha ao atualizar status da delivery")
+		}
+		if change.Status == models.DeliveryStatusDelivered {
+			if err := tx.Model(&models.DeliveryItem{}).
+				Where("delivery_id = ?", delivery.ID).
+				Update("received_qty", gorm.Expr("quantity")).Error; err != nil {
+				return models.BatchItemResult{}, errors.WrapError(err, "falha ao atualizar itens da delivery")
+			}
+		}
+		return models.BatchItemSucceeded(index, delivery.ID, delivery.DeliveryNo), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("lote de status de deliveries processado", zap.Int("items", len(entries)), zap.Bool("atomic", atomic))
+	return results, nil
+}
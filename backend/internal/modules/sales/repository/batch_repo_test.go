@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func paymentEntries(firstInvoiceID, secondInvoiceID int) []models.BatchEntry[*models.Payment] {
+	return []models.BatchEntry[*models.Payment]{
+		{Index: 0, Item: &models.Payment{InvoiceID: firstInvoiceID, Amount: money.FromInt(40), PaymentMethod: "pix"}},
+		{Index: 1, Item: &models.Payment{InvoiceID: secondInvoiceID, Amount: money.FromInt(10), PaymentMethod: "pix"}},
+	}
+}
+
+// reloadInvoice relê a fatura gravada pelo lote
+func reloadInvoice(t *testing.T, tx *gorm.DB, id int) models.Invoice {
+	t.Helper()
+	var invoice models.Invoice
+	require.NoError(t, tx.First(&invoice, id).Error)
+	return invoice
+}
+
+// Fora do modo atômico, a falha de um item desfaz apenas o seu savepoint e o lote é confirmado
+func TestCreatePaymentsIsolatesFailedItemsOnPostgres(t *testing.T) {
+	tx := testutils.PostgresTx(t)
+	repo := &batchRepository{db: tx, logger: zap.NewNop()}
+	invoice := testutils.CreateInvoice(t, tx)
+
+	// O segundo item paga uma fatura inexistente
+	entries := paymentEntries(invoice.ID, -1)
+	results, err := repo.CreatePayments(context.Background(), entries, false)
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+	assert.NotZero(t, entries[0].Item.ID)
+	assert.Equal(t, models.BatchItemSucceeded(0, entries[0].Item.ID, ""), results[0])
+	assert.Equal(t, models.BatchItemError, results[1].Status)
+	assert.Equal(t, "invoice_not_found", results[1].Error.Code)
+
+	paid := reloadInvoice(t, tx, invoice.ID)
+	assert.Equal(t, "40.00", paid.AmountPaid.StringFixed(2))
+	assert.Equal(t, models.InvoiceStatusPartial, paid.Status)
+}
+
+// No modo atômico, a primeira falha interrompe o lote e desfaz a transação
+func TestCreatePaymentsAtomicRollsBackOnPostgres(t *testing.T) {
+	tx := testutils.PostgresTx(t)
+	repo := &batchRepository{db: tx, logger: zap.NewNop()}
+	invoice := testutils.CreateInvoice(t, tx)
+	cancelled := testutils.CreateInvoice(t, tx, func(i *models.Invoice) { i.Status = models.InvoiceStatusCancelled })
+
+	results, err := repo.CreatePayments(context.Background(), paymentEntries(invoice.ID, cancelled.ID), true)
+	require.NoError(t, err)
+
+	result := models.NewBatchResult(true, 2, results)
+	assert.True(t, result.RolledBack)
+	assert.Equal(t, models.BatchItemNotApplied, result.Items[0].Status)
+	assert.Equal(t, "invoice_not_payable", result.Items[1].Error.Code)
+
+	// O pagamento do primeiro item também foi desfeito
+	var payments int64
+	require.NoError(t, tx.Model(&models.Payment{}).Where("invoice_id = ?", invoice.ID).Count(&payments).Error)
+	assert.Zero(t, payments)
+	assert.True(t, reloadInvoice(t, tx, invoice.ID).AmountPaid.IsZero())
+}
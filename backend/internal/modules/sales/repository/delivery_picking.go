@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StartPicking inicia a separação de uma entrega de pedido de venda pendente e devolve a lista
+// de separação
+func (r *deliveryRepository) StartPicking(ctx context.Context, id int) (*models.PickList, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	delivery, err := lockDelivery(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !delivery.RequiresPacking() {
+		tx.Rollback()
+		return nil, errors.ErrDeliveryNotOutbound
+	}
+	if delivery.Status != models.DeliveryStatusPending {
+		tx.Rollback()
+		return nil, errors.ErrDeliveryNotPending
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.Delivery{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": models.DeliveryStatusPicking, "picking_started_at": now}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao iniciar separação", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao iniciar separação da entrega")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("separação da entrega iniciada", zap.Int("id", id), zap.String("delivery_no", delivery.DeliveryNo))
+	return r.GetPickList(ctx, id)
+}
+
+// GetPickList retorna a lista de separação da entrega agrupada pelo endereço de estoque dos produtos
+func (r *deliveryRepository) GetPickList(ctx context.Context, id int) (*models.PickList, error) {
+	db := db.Conn(ctx, r.db)
+
+	var delivery models.Delivery
+	if err := db.First(&delivery, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		r.logger.Error("erro ao buscar delivery", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar delivery")
+	}
+
+	var items []models.PickItem
+	if err := db.Table("delivery_items di").
+		Select("di.id AS delivery_item_id, di.product_id, di.product_name, di.product_code, di.unit, "+
+			"di.quantity, di.picked_qty, COALESCE(di.lot_number, '') AS lot_number, "+
+			"b.id AS bin_id, COALESCE(b.code, '') AS bin_code, COALESCE(b.zone, '') AS zone").
+		Joins("LEFT JOIN products p ON p.id = di.product_id").
+		Joins("LEFT JOIN warehouse_bins b ON b.id = p.bin_id").
+		Where("di.delivery_id = ?", id).
+		Order("di.id ASC").
+		Scan(&items).Error; err != nil {
+		r.logger.Error("erro ao montar lista de separação", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao montar lista de separação")
+	}
+
+	return models.BuildPickList(&delivery, items), nil
+}
+
+// RecordPicks grava as quantidades separadas dos itens de uma entrega em separação
+func (r *deliveryRepository) RecordPicks(ctx context.Context, id int, picks []models.PickLine) (*models.PickList, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	delivery, err := lockDelivery(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if delivery.Status != models.DeliveryStatusPicking {
+		tx.Rollback()
+		return nil, errors.ErrDeliveryNotPicking
+	}
+
+	if err := models.ApplyPicks(delivery.Items, picks); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, item := range delivery.Items {
+		if err := tx.Model(&models.DeliveryItem{}).Where("id = ?", item.ID).
+			Update("picked_qty", item.PickedQty).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao gravar quantidade separada", zap.Error(err), zap.Int("item_id", item.ID))
+			return nil, errors.WrapError(err, "falha ao gravar quantidade separada")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+	return r.GetPickList(ctx, id)
+}
+
+// PackDelivery registra os volumes de uma entrega já separada e a marca como embalada. Em uma
+// entrega já embalada, os volumes são substituídos.
+func (r *deliveryRepository) PackDelivery(ctx context.Context, id int, inputs []models.PackageInput) ([]models.DeliveryPackage, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	delivery, err := lockDelivery(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if delivery.Status != models.DeliveryStatusPicking && delivery.Status != models.DeliveryStatusPacked {
+		tx.Rollback()
+		return nil, errors.ErrDeliveryNotPicking
+	}
+
+	packages, err := models.BuildPackages(delivery, inputs)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Where("delivery_id = ?", id).Delete(&models.DeliveryPackage{}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao remover volumes da entrega", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao substituir volumes da entrega")
+	}
+	if err := tx.Create(&packages).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao gravar volumes da entrega", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao gravar volumes da entrega")
+	}
+
+	if err := tx.Model(&models.Delivery{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": models.DeliveryStatusPacked, "packed_at": time.Now()}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao marcar entrega como embalada", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao marcar entrega como embalada")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("entrega embalada", zap.Int("id", id), zap.Int("packages", len(packages)))
+	return packages, nil
+}
+
+// GetPackages lista os volumes da entrega com os itens de cada um
+func (r *deliveryRepository) GetPackages(ctx context.Context, id int) ([]models.DeliveryPackage, error) {
+	db := db.Conn(ctx, r.db)
+
+	var delivery models.Delivery
+	if err := db.Select("id", "delivery_no").First(&delivery, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar delivery")
+	}
+
+	var packages []models.DeliveryPackage
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("delivery_id = ?", id).Order("package_no ASC").Find(&packages).Error; err != nil {
+		r.logger.Error("erro ao listar volumes da entrega", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao listar volumes da entrega")
+	}
+	for i := range packages {
+		packages[i].Code = models.DeliveryPackageCode(delivery.DeliveryNo, packages[i].PackageNo)
+	}
+	return packages, nil
+}
+
+// lockDelivery bloqueia a entrega e carrega os itens dentro da transação
+func lockDelivery(tx *gorm.DB, id int) (*models.Delivery, error) {
+	var delivery models.Delivery
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&delivery, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar delivery")
+	}
+	if err := tx.Where("delivery_id = ?", id).Order("id ASC").Find(&delivery.Items).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar itens da delivery")
+	}
+	return &delivery, nil
+}
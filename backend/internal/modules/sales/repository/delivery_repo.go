@@ -2,11 +2,16 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/db/sqlexpr"
 	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
 	"ERP-ONSMART/backend/internal/logger"
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/listquery"
 	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
 	"fmt"
 	"time"
 
@@ -16,28 +21,33 @@ import (
 
 // DeliveryRepository define as operações do repositório de deliveries
 type DeliveryRepository interface {
-	CreateDelivery(delivery *models.Delivery) error
-	GetDeliveryByID(id int) (*models.Delivery, error)
-	GetAllDeliveries(params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	UpdateDelivery(id int, delivery *models.Delivery) error
-	DeleteDelivery(id int) error
-	GetDeliveriesByStatus(status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetDeliveriesByPurchaseOrder(purchaseOrderID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetDeliveriesBySalesOrder(salesOrderID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetDeliveriesByPeriod(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetDeliveriesByDeliveryDate(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetDeliveriesByReceivedDate(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	SearchDeliveries(filter DeliveryFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetDeliveryStats(filter DeliveryFilter) (*DeliveryStats, error)
-	GetContactDeliveriesSummary(contactID int, deliveryType string) (*ContactDeliveriesSummary, error)
-	UpdateDeliveryStatus(id int, status string) error
-	UpdateDeliveryItem(deliveryID int, itemID int, receivedQty int) error
-	MarkAsShipped(id int, trackingNumber string) error
-	MarkAsDelivered(id int) error
-	MarkAsReturned(id int, reason string) error
-	GetPendingDeliveries(params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetOverdueDeliveries(params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetDeliveryTrackingInfo(id int) (*DeliveryTrackingInfo, error)
+	CreateDelivery(ctx context.Context, delivery *models.Delivery) error
+	GetDeliveryByID(ctx context.Context, id int) (*models.Delivery, error)
+	GetAllDeliveries(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	UpdateDelivery(ctx context.Context, id int, delivery *models.Delivery) error
+	DeleteDelivery(ctx context.Context, id int) error
+	GetDeliveriesByStatus(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetDeliveriesByPurchaseOrder(ctx context.Context, purchaseOrderID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetDeliveriesBySalesOrder(ctx context.Context, salesOrderID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetDeliveriesByPeriod(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetDeliveriesByDeliveryDate(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetDeliveriesByReceivedDate(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	SearchDeliveries(ctx context.Context, filter DeliveryFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetDeliveryStats(ctx context.Context, filter DeliveryFilter) (*DeliveryStats, error)
+	GetContactDeliveriesSummary(ctx context.Context, contactID int, deliveryType string) (*ContactDeliveriesSummary, error)
+	UpdateDeliveryStatus(ctx context.Context, id int, status string) error
+	UpdateDeliveryItem(ctx context.Context, deliveryID int, itemID int, receivedQty int) error
+	MarkAsShipped(ctx context.Context, id int, trackingNumber string) error
+	MarkAsDelivered(ctx context.Context, id int) error
+	MarkAsReturned(ctx context.Context, id int, reason string) error
+	GetPendingDeliveries(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetOverdueDeliveries(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetDeliveryTrackingInfo(ctx context.Context, id int) (*DeliveryTrackingInfo, error)
+	StartPicking(ctx context.Context, id int) (*models.PickList, error)
+	GetPickList(ctx context.Context, id int) (*models.PickList, error)
+	RecordPicks(ctx context.Context, id int, picks []models.PickLine) (*models.PickList, error)
+	PackDelivery(ctx context.Context, id int, packages []models.PackageInput) ([]models.DeliveryPackage, error)
+	GetPackages(ctx context.Context, id int) ([]models.DeliveryPackage, error)
 }
 
 // DeliveryFilter define os filtros para busca avançada
@@ -55,8 +65,11 @@ type DeliveryFilter struct {
 	ShippingMethod    string
 	HasTrackingNumber *bool
 	IsOverdue         *bool
+	SLAStatus         string // on_track, met ou breached
 	SearchQuery       string
-	DeliveryType      string // "incoming" (from PO) or "outgoing" (from SO)
+	DeliveryType      string                // "incoming" (from PO) or "outgoing" (from SO)
+	Sort              []listquery.SortField // ?sort=; vazio ordena por created_at DESC
+	Fields            []string              // ?fields=; vazio carrega PurchaseOrder, SalesOrder e Items
 }
 
 // DeliveryStats representa estatísticas de deliveries
@@ -91,15 +104,17 @@ type ContactDeliveriesSummary struct {
 
 // DeliveryTrackingInfo representa informações de rastreamento da entrega
 type DeliveryTrackingInfo struct {
-	DeliveryID      int                  `json:"delivery_id"`
-	DeliveryNo      string               `json:"delivery_no"`
-	Status          string               `json:"status"`
-	TrackingNumber  string               `json:"tracking_number"`
-	ShippingMethod  string               `json:"shipping_method"`
-	ShippingAddress string               `json:"shipping_address"`
-	DeliveryDate    time.Time            `json:"delivery_date"`
-	ReceivedDate    time.Time            `json:"received_date"`
-	Items           []DeliveryItemStatus `json:"items"`
+	DeliveryID      int                            `json:"delivery_id"`
+	DeliveryNo      string                         `json:"delivery_no"`
+	Status          string                         `json:"status"`
+	TrackingNumber  string                         `json:"tracking_number"`
+	ShippingMethod  string                         `json:"shipping_method"`
+	Carrier         string                         `json:"carrier"`
+	ShippingAddress string                         `json:"shipping_address"`
+	DeliveryDate    time.Time                      `json:"delivery_date"`
+	ReceivedDate    time.Time                      `json:"received_date"`
+	Items           []DeliveryItemStatus           `json:"items"`
+	Events          []models.DeliveryTrackingEvent `json:"events"`
 }
 
 // DeliveryItemStatus representa o status de um item na entrega
@@ -115,27 +130,29 @@ type DeliveryItemStatus struct {
 
 type deliveryRepository struct {
 	db     *gorm.DB
+	reader *gorm.DB // réplica de leitura para as consultas analíticas (ver db.Provider)
 	logger *zap.Logger
 }
 
 // NewDeliveryRepository cria uma nova instância do repositório
 func NewDeliveryRepository() (DeliveryRepository, error) {
-	db, err := db.OpenGormDB()
+	provider, err := db.OpenProvider()
 	if err != nil {
 		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
 	}
 
 	return &deliveryRepository{
-		db:     db,
+		db:     provider.Writer(),
+		reader: provider.Reader(),
 		logger: logger.WithModule("delivery_repository"),
 	}, nil
 }
 
 // CreateDelivery cria uma nova delivery no banco
-func (r *deliveryRepository) CreateDelivery(delivery *models.Delivery) error {
+func (r *deliveryRepository) CreateDelivery(ctx context.Context, delivery *models.Delivery) error {
 	// Gera o número da delivery se não foi fornecido
 	if delivery.DeliveryNo == "" {
-		delivery.DeliveryNo = r.generateDeliveryNumber()
+		delivery.DeliveryNo = r.generateDeliveryNumber(ctx)
 	}
 
 	// Define status padrão se não foi fornecido
@@ -144,7 +161,37 @@ func (r *deliveryRepository) CreateDelivery(delivery *models.Delivery) error {
 	}
 
 	// Inicia transação
-	tx := r.db.Begin()
+	tx := db.Conn(ctx, r.db).Begin()
+
+	// Os itens são gravados na unidade de estoque
+	if err := PrepareDeliveryItems(tx, delivery.Items); err != nil {
+		tx.Rollback()
+		r.logger.Warn("itens da delivery rejeitados", zap.Error(err))
+		return err
+	}
+
+	// Entregas de pedido de venda não podem exceder o saldo das linhas do pedido nem sair para
+	// cliente bloqueado
+	if delivery.SalesOrderID > 0 {
+		var contactIDs []int
+		if err := tx.Model(&models.SalesOrder{}).Where("id = ?", delivery.SalesOrderID).
+			Pluck("contact_id", &contactIDs).Error; err != nil {
+			tx.Rollback()
+			return errors.WrapError(err, "falha ao buscar pedido de venda da entrega")
+		}
+		if len(contactIDs) > 0 {
+			if err := contactRepository.EnsureNotBlocked(tx, contactIDs[0]); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if index, err := checkShipment(tx, delivery); err != nil {
+			tx.Rollback()
+			r.logger.Warn("entrega rejeitada", zap.Error(err),
+				zap.Int("sales_order_id", delivery.SalesOrderID), zap.Int("item", index))
+			return err
+		}
+	}
 
 	// Cria a delivery
 	if err := tx.Create(delivery).Error; err != nil {
@@ -180,10 +227,10 @@ func (r *deliveryRepository) CreateDelivery(delivery *models.Delivery) error {
 }
 
 // GetDeliveryByID busca uma delivery pelo ID
-func (r *deliveryRepository) GetDeliveryByID(id int) (*models.Delivery, error) {
+func (r *deliveryRepository) GetDeliveryByID(ctx context.Context, id int) (*models.Delivery, error) {
 	var delivery models.Delivery
 
-	query := r.db.Preload("PurchaseOrder").
+	query := db.Conn(ctx, r.db).Preload("PurchaseOrder").
 		Preload("PurchaseOrder.Contact").
 		Preload("SalesOrder").
 		Preload("SalesOrder.Contact").
@@ -202,12 +249,12 @@ func (r *deliveryRepository) GetDeliveryByID(id int) (*models.Delivery, error) {
 }
 
 // GetAllDeliveries retorna todas as deliveries com paginação
-func (r *deliveryRepository) GetAllDeliveries(params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *deliveryRepository) GetAllDeliveries(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
 	// Query base
-	query := r.db.Model(&models.Delivery{})
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -233,10 +280,10 @@ func (r *deliveryRepository) GetAllDeliveries(params *pagination.PaginationParam
 }
 
 // UpdateDelivery atualiza uma delivery existente
-func (r *deliveryRepository) UpdateDelivery(id int, delivery *models.Delivery) error {
+func (r *deliveryRepository) UpdateDelivery(ctx context.Context, id int, delivery *models.Delivery) error {
 	// Verifica se a delivery existe
 	var existing models.Delivery
-	if err := r.db.First(&existing, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrDeliveryNotFound
 		}
@@ -245,7 +292,7 @@ func (r *deliveryRepository) UpdateDelivery(id int, delivery *models.Delivery) e
 
 	// Atualiza os campos
 	delivery.ID = id
-	if err := r.db.Save(delivery).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(delivery).Error; err != nil {
 		r.logger.Error("erro ao atualizar delivery", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao atualizar delivery")
 	}
@@ -254,11 +301,11 @@ func (r *deliveryRepository) UpdateDelivery(id int, delivery *models.Delivery) e
 	return nil
 }
 
-// DeleteDelivery remove uma delivery
-func (r *deliveryRepository) DeleteDelivery(id int) error {
+// DeleteDelivery move uma delivery para a lixeira (soft delete)
+func (r *deliveryRepository) DeleteDelivery(ctx context.Context, id int) error {
 	// Verifica o status da delivery
 	var delivery models.Delivery
-	if err := r.db.First(&delivery, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&delivery, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrDeliveryNotFound
 		}
@@ -270,8 +317,8 @@ func (r *deliveryRepository) DeleteDelivery(id int) error {
 		return errors.WrapError(gorm.ErrInvalidData, "não é possível deletar entregas concluídas")
 	}
 
-	// Remove a delivery (cascade removerá os itens)
-	result := r.db.Delete(&models.Delivery{}, id)
+	// Soft delete: os itens são mantidos para permitir a restauração
+	result := db.Conn(ctx, r.db).Delete(&models.Delivery{}, id)
 	if result.Error != nil {
 		r.logger.Error("erro ao deletar delivery", zap.Error(result.Error), zap.Int("id", id))
 		return errors.WrapError(result.Error, "falha ao deletar delivery")
@@ -286,11 +333,11 @@ func (r *deliveryRepository) DeleteDelivery(id int) error {
 }
 
 // GetDeliveriesByStatus busca deliveries por status
-func (r *deliveryRepository) GetDeliveriesByStatus(status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *deliveryRepository) GetDeliveriesByStatus(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
-	query := r.db.Model(&models.Delivery{}).Where("status = ?", status)
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{}).Where("status = ?", status)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -315,11 +362,11 @@ func (r *deliveryRepository) GetDeliveriesByStatus(status string, params *pagina
 }
 
 // GetDeliveriesByPurchaseOrder busca deliveries por purchase order
-func (r *deliveryRepository) GetDeliveriesByPurchaseOrder(purchaseOrderID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *deliveryRepository) GetDeliveriesByPurchaseOrder(ctx context.Context, purchaseOrderID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
-	query := r.db.Model(&models.Delivery{}).Where("purchase_order_id = ?", purchaseOrderID)
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{}).Where("purchase_order_id = ?", purchaseOrderID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -344,11 +391,11 @@ func (r *deliveryRepository) GetDeliveriesByPurchaseOrder(purchaseOrderID int, p
 }
 
 // GetDeliveriesBySalesOrder busca deliveries por sales order
-func (r *deliveryRepository) GetDeliveriesBySalesOrder(salesOrderID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *deliveryRepository) GetDeliveriesBySalesOrder(ctx context.Context, salesOrderID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
-	query := r.db.Model(&models.Delivery{}).Where("sales_order_id = ?", salesOrderID)
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{}).Where("sales_order_id = ?", salesOrderID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -373,11 +420,11 @@ func (r *deliveryRepository) GetDeliveriesBySalesOrder(salesOrderID int, params
 }
 
 // GetDeliveriesByPeriod busca deliveries por período (usando created_at)
-func (r *deliveryRepository) GetDeliveriesByPeriod(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *deliveryRepository) GetDeliveriesByPeriod(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
-	query := r.db.Model(&models.Delivery{}).
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{}).
 		Where("created_at >= ? AND created_at <= ?", startDate, endDate)
 
 	// Conta o total
@@ -404,11 +451,11 @@ func (r *deliveryRepository) GetDeliveriesByPeriod(startDate, endDate time.Time,
 }
 
 // GetDeliveriesByDeliveryDate busca deliveries por data de entrega
-func (r *deliveryRepository) GetDeliveriesByDeliveryDate(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *deliveryRepository) GetDeliveriesByDeliveryDate(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
-	query := r.db.Model(&models.Delivery{}).
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{}).
 		Where("delivery_date >= ? AND delivery_date <= ?", startDate, endDate)
 
 	// Conta o total
@@ -435,11 +482,11 @@ func (r *deliveryRepository) GetDeliveriesByDeliveryDate(startDate, endDate time
 }
 
 // GetDeliveriesByReceivedDate busca deliveries por data de recebimento
-func (r *deliveryRepository) GetDeliveriesByReceivedDate(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *deliveryRepository) GetDeliveriesByReceivedDate(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
-	query := r.db.Model(&models.Delivery{}).
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{}).
 		Where("received_date >= ? AND received_date <= ?", startDate, endDate)
 
 	// Conta o total
@@ -466,11 +513,11 @@ func (r *deliveryRepository) GetDeliveriesByReceivedDate(startDate, endDate time
 }
 
 // SearchDeliveries busca deliveries com filtros combinados
-func (r *deliveryRepository) SearchDeliveries(filter DeliveryFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *deliveryRepository) SearchDeliveries(ctx context.Context, filter DeliveryFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
-	query := r.db.Model(&models.Delivery{})
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{})
 
 	// Aplica os filtros
 	if len(filter.Status) > 0 {
@@ -494,8 +541,8 @@ func (r *deliveryRepository) SearchDeliveries(filter DeliveryFilter, params *pag
 
 	// Filtro por contato (através de PO ou SO)
 	if filter.ContactID > 0 {
-		poSubquery := r.db.Model(&models.PurchaseOrder{}).Select("id").Where("contact_id = ?", filter.ContactID)
-		soSubquery := r.db.Model(&models.SalesOrder{}).Select("id").Where("contact_id = ?", filter.ContactID)
+		poSubquery := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).Select("id").Where("contact_id = ?", filter.ContactID)
+		soSubquery := db.Conn(ctx, r.db).Model(&models.SalesOrder{}).Select("id").Where("contact_id = ?", filter.ContactID)
 		query = query.Where("purchase_order_id IN (?) OR sales_order_id IN (?)", poSubquery, soSubquery)
 	}
 
@@ -512,6 +559,11 @@ func (r *deliveryRepository) SearchDeliveries(filter DeliveryFilter, params *pag
 		query = query.Where("received_date >= ? AND received_date <= ?", filter.ReceivedDateStart, filter.ReceivedDateEnd)
 	}
 
+	// Filtro pelo status de SLA de envio
+	if filter.SLAStatus != "" {
+		query = query.Where("sla_status = ?", filter.SLAStatus)
+	}
+
 	// Filtro por método de envio
 	if filter.ShippingMethod != "" {
 		query = query.Where("shipping_method = ?", filter.ShippingMethod)
@@ -528,8 +580,7 @@ func (r *deliveryRepository) SearchDeliveries(filter DeliveryFilter, params *pag
 
 	// Filtro de overdue (vencido)
 	if filter.IsOverdue != nil && *filter.IsOverdue {
-		now := time.Now()
-		query = query.Where("delivery_date < ? AND status IN ?", now, []string{models.DeliveryStatusPending, models.DeliveryStatusShipped})
+		query = query.Where("delivery_date < ? AND status IN ?", localtime.Today(ctx), []string{models.DeliveryStatusPending, models.DeliveryStatusPicking, models.DeliveryStatusPacked, models.DeliveryStatusShipped})
 	}
 
 	// Busca textual
@@ -545,12 +596,9 @@ func (r *deliveryRepository) SearchDeliveries(filter DeliveryFilter, params *pag
 		return nil, errors.WrapError(err, "falha ao contar deliveries na busca")
 	}
 
-	// Aplica paginação e busca os dados
+	// Aplica ordenação, seleção de campos e paginação e busca os dados
 	offset := pagination.CalculateOffset(params.Page, params.PageSize)
-	if err := query.Preload("PurchaseOrder").
-		Preload("SalesOrder").
-		Preload("Items").
-		Order("created_at DESC").
+	if err := DeliveryListSpec.Apply(query, filter.Sort, filter.Fields).
 		Limit(params.PageSize).
 		Offset(offset).
 		Find(&deliveries).Error; err != nil {
@@ -563,12 +611,12 @@ func (r *deliveryRepository) SearchDeliveries(filter DeliveryFilter, params *pag
 }
 
 // GetDeliveryStats retorna estatísticas de deliveries
-func (r *deliveryRepository) GetDeliveryStats(filter DeliveryFilter) (*DeliveryStats, error) {
+func (r *deliveryRepository) GetDeliveryStats(ctx context.Context, filter DeliveryFilter) (*DeliveryStats, error) {
 	stats := &DeliveryStats{
 		CountByStatus: make(map[string]int),
 	}
 
-	query := r.db.Model(&models.Delivery{})
+	query := r.reader.WithContext(ctx).Model(&models.Delivery{})
 
 	// Aplica filtros básicos
 	if !filter.DateRangeStart.IsZero() && !filter.DateRangeEnd.IsZero() {
@@ -622,9 +670,9 @@ func (r *deliveryRepository) GetDeliveryStats(filter DeliveryFilter) (*DeliveryS
 	var avgDeliveryTime struct {
 		AvgDays float64
 	}
-	if err := r.db.Model(&models.Delivery{}).
+	if err := r.reader.WithContext(ctx).Model(&models.Delivery{}).
 		Where("status = ? AND received_date IS NOT NULL AND delivery_date IS NOT NULL", models.DeliveryStatusDelivered).
-		Select("AVG(JULIANDAY(received_date) - JULIANDAY(delivery_date)) as avg_days").
+		Select(sqlexpr.AvgDaysBetween(r.reader, "delivery_date", "received_date") + " as avg_days").
 		Scan(&avgDeliveryTime).Error; err == nil {
 		stats.AverageDeliveryTime = avgDeliveryTime.AvgDays
 	}
@@ -633,7 +681,7 @@ func (r *deliveryRepository) GetDeliveryStats(filter DeliveryFilter) (*DeliveryS
 }
 
 // GetContactDeliveriesSummary retorna um resumo das deliveries de um contato
-func (r *deliveryRepository) GetContactDeliveriesSummary(contactID int, deliveryType string) (*ContactDeliveriesSummary, error) {
+func (r *deliveryRepository) GetContactDeliveriesSummary(ctx context.Context, contactID int, deliveryType string) (*ContactDeliveriesSummary, error) {
 	summary := &ContactDeliveriesSummary{
 		ContactID:    contactID,
 		DeliveryType: deliveryType,
@@ -641,7 +689,7 @@ func (r *deliveryRepository) GetContactDeliveriesSummary(contactID int, delivery
 
 	// Busca informações do contato
 	var contact contact.Contact
-	if err := r.db.First(&contact, contactID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&contact, contactID).Error; err != nil {
 		return nil, errors.WrapError(err, "falha ao buscar contato")
 	}
 
@@ -652,14 +700,14 @@ func (r *deliveryRepository) GetContactDeliveriesSummary(contactID int, delivery
 	summary.ContactType = contact.Type
 
 	// Query base dependendo do tipo de delivery
-	query := r.db.Model(&models.Delivery{})
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{})
 	if deliveryType == "incoming" {
 		// Deliveries de Purchase Orders (entrada)
-		poSubquery := r.db.Model(&models.PurchaseOrder{}).Select("id").Where("contact_id = ?", contactID)
+		poSubquery := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).Select("id").Where("contact_id = ?", contactID)
 		query = query.Where("purchase_order_id IN (?)", poSubquery)
 	} else if deliveryType == "outgoing" {
 		// Deliveries de Sales Orders (saída)
-		soSubquery := r.db.Model(&models.SalesOrder{}).Select("id").Where("contact_id = ?", contactID)
+		soSubquery := db.Conn(ctx, r.db).Model(&models.SalesOrder{}).Select("id").Where("contact_id = ?", contactID)
 		query = query.Where("sales_order_id IN (?)", soSubquery)
 	}
 
@@ -695,9 +743,8 @@ func (r *deliveryRepository) GetContactDeliveriesSummary(contactID int, delivery
 	}
 
 	// Deliveries vencidas
-	now := time.Now()
 	var overdueCount int64
-	if err := query.Where("delivery_date < ? AND status IN ?", now, []string{models.DeliveryStatusPending, models.DeliveryStatusShipped}).
+	if err := query.Where("delivery_date < ? AND status IN ?", localtime.Today(ctx), []string{models.DeliveryStatusPending, models.DeliveryStatusPicking, models.DeliveryStatusPacked, models.DeliveryStatusShipped}).
 		Count(&overdueCount).Error; err != nil {
 		r.logger.Warn("erro ao contar deliveries vencidas", zap.Error(err))
 	}
@@ -719,10 +766,10 @@ func (r *deliveryRepository) GetContactDeliveriesSummary(contactID int, delivery
 }
 
 // UpdateDeliveryStatus atualiza o status de uma delivery
-func (r *deliveryRepository) UpdateDeliveryStatus(id int, status string) error {
+func (r *deliveryRepository) UpdateDeliveryStatus(ctx context.Context, id int, status string) error {
 	// Verifica se a delivery existe
 	var delivery models.Delivery
-	if err := r.db.First(&delivery, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&delivery, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrDeliveryNotFound
 		}
@@ -737,7 +784,13 @@ func (r *deliveryRepository) UpdateDeliveryStatus(id int, status string) error {
 		delivery.ReceivedDate = time.Now()
 	}
 
-	if err := r.db.Save(&delivery).Error; err != nil {
+	// Registra o momento do envio, base do SLA de envio
+	if (status == models.DeliveryStatusShipped || status == models.DeliveryStatusDelivered) && delivery.ShippedAt == nil {
+		now := time.Now()
+		delivery.ShippedAt = &now
+	}
+
+	if err := db.Conn(ctx, r.db).Save(&delivery).Error; err != nil {
 		r.logger.Error("erro ao atualizar status da delivery", zap.Error(err), zap.Int("id", id), zap.String("status", status))
 		return errors.WrapError(err, "falha ao atualizar status da delivery")
 	}
@@ -747,10 +800,10 @@ func (r *deliveryRepository) UpdateDeliveryStatus(id int, status string) error {
 }
 
 // UpdateDeliveryItem atualiza a quantidade recebida de um item
-func (r *deliveryRepository) UpdateDeliveryItem(deliveryID int, itemID int, receivedQty int) error {
+func (r *deliveryRepository) UpdateDeliveryItem(ctx context.Context, deliveryID int, itemID int, receivedQty int) error {
 	// Busca o item
 	var item models.DeliveryItem
-	if err := r.db.Where("delivery_id = ? AND id = ?", deliveryID, itemID).First(&item).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Where("delivery_id = ? AND id = ?", deliveryID, itemID).First(&item).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrDeliveryItemNotFound
 		}
@@ -764,14 +817,14 @@ func (r *deliveryRepository) UpdateDeliveryItem(deliveryID int, itemID int, rece
 
 	// Atualiza a quantidade recebida
 	item.ReceivedQty = receivedQty
-	if err := r.db.Save(&item).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&item).Error; err != nil {
 		r.logger.Error("erro ao atualizar item da delivery", zap.Error(err), zap.Int("delivery_id", deliveryID), zap.Int("item_id", itemID))
 		return errors.WrapError(err, "falha ao atualizar item da delivery")
 	}
 
 	// Verifica se todos os itens foram recebidos para atualizar o status da delivery
 	var pendingItems int64
-	if err := r.db.Model(&models.DeliveryItem{}).
+	if err := db.Conn(ctx, r.db).Model(&models.DeliveryItem{}).
 		Where("delivery_id = ? AND received_qty < quantity", deliveryID).
 		Count(&pendingItems).Error; err != nil {
 		r.logger.Warn("erro ao contar itens pendentes", zap.Error(err))
@@ -779,7 +832,7 @@ func (r *deliveryRepository) UpdateDeliveryItem(deliveryID int, itemID int, rece
 
 	// Se todos os itens foram recebidos, atualiza o status da delivery para delivered
 	if pendingItems == 0 {
-		if err := r.UpdateDeliveryStatus(deliveryID, models.DeliveryStatusDelivered); err != nil {
+		if err := r.UpdateDeliveryStatus(ctx, deliveryID, models.DeliveryStatusDelivered); err != nil {
 			r.logger.Warn("erro ao atualizar status da delivery para delivered", zap.Error(err))
 		}
 	}
@@ -788,11 +841,12 @@ func (r *deliveryRepository) UpdateDeliveryItem(deliveryID int, itemID int, rece
 	return nil
 }
 
-// MarkAsShipped marca uma delivery como enviada
-func (r *deliveryRepository) MarkAsShipped(id int, trackingNumber string) error {
+// MarkAsShipped marca uma delivery como enviada. Entregas de pedido de venda precisam estar
+// embaladas; recebimentos de pedido de compra, pendentes.
+func (r *deliveryRepository) MarkAsShipped(ctx context.Context, id int, trackingNumber string) error {
 	// Busca a delivery
 	var delivery models.Delivery
-	if err := r.db.First(&delivery, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&delivery, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrDeliveryNotFound
 		}
@@ -800,18 +854,20 @@ func (r *deliveryRepository) MarkAsShipped(id int, trackingNumber string) error
 	}
 
 	// Verifica se o status permite marcação como shipped
-	if delivery.Status != models.DeliveryStatusPending {
-		return errors.WrapError(gorm.ErrInvalidData, "apenas deliveries pendentes podem ser marcadas como enviadas")
+	if err := delivery.CanShip(); err != nil {
+		return err
 	}
 
 	// Atualiza o status e o tracking number
 	delivery.Status = models.DeliveryStatusShipped
 	delivery.TrackingNumber = trackingNumber
+	now := time.Now()
 	if delivery.DeliveryDate.IsZero() {
-		delivery.DeliveryDate = time.Now()
+		delivery.DeliveryDate = now
 	}
+	delivery.ShippedAt = &now
 
-	if err := r.db.Save(&delivery).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&delivery).Error; err != nil {
 		r.logger.Error("erro ao marcar delivery como shipped", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao marcar delivery como shipped")
 	}
@@ -821,10 +877,10 @@ func (r *deliveryRepository) MarkAsShipped(id int, trackingNumber string) error
 }
 
 // MarkAsDelivered marca uma delivery como entregue
-func (r *deliveryRepository) MarkAsDelivered(id int) error {
+func (r *deliveryRepository) MarkAsDelivered(ctx context.Context, id int) error {
 	// Busca a delivery
 	var delivery models.Delivery
-	if err := r.db.First(&delivery, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&delivery, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrDeliveryNotFound
 		}
@@ -840,13 +896,13 @@ func (r *deliveryRepository) MarkAsDelivered(id int) error {
 	delivery.Status = models.DeliveryStatusDelivered
 	delivery.ReceivedDate = time.Now()
 
-	if err := r.db.Save(&delivery).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&delivery).Error; err != nil {
 		r.logger.Error("erro ao marcar delivery como delivered", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao marcar delivery como delivered")
 	}
 
 	// Atualiza todos os itens como recebidos (quantidade total)
-	if err := r.db.Model(&models.DeliveryItem{}).
+	if err := db.Conn(ctx, r.db).Model(&models.DeliveryItem{}).
 		Where("delivery_id = ?", id).
 		Updates(map[string]interface{}{
 			"received_qty": gorm.Expr("quantity"),
@@ -859,10 +915,10 @@ func (r *deliveryRepository) MarkAsDelivered(id int) error {
 }
 
 // MarkAsReturned marca uma delivery como devolvida
-func (r *deliveryRepository) MarkAsReturned(id int, reason string) error {
+func (r *deliveryRepository) MarkAsReturned(ctx context.Context, id int, reason string) error {
 	// Busca a delivery
 	var delivery models.Delivery
-	if err := r.db.First(&delivery, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&delivery, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrDeliveryNotFound
 		}
@@ -878,7 +934,7 @@ func (r *deliveryRepository) MarkAsReturned(id int, reason string) error {
 		delivery.Notes += "Devolvido: " + reason
 	}
 
-	if err := r.db.Save(&delivery).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&delivery).Error; err != nil {
 		r.logger.Error("erro ao marcar delivery como returned", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao marcar delivery como returned")
 	}
@@ -888,18 +944,18 @@ func (r *deliveryRepository) MarkAsReturned(id int, reason string) error {
 }
 
 // GetPendingDeliveries busca deliveries pendentes
-func (r *deliveryRepository) GetPendingDeliveries(params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
-	return r.GetDeliveriesByStatus(models.DeliveryStatusPending, params)
+func (r *deliveryRepository) GetPendingDeliveries(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	return r.GetDeliveriesByStatus(ctx, models.DeliveryStatusPending, params)
 }
 
-// GetOverdueDeliveries busca deliveries vencidas
-func (r *deliveryRepository) GetOverdueDeliveries(params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+// GetOverdueDeliveries busca deliveries vencidas: data prevista anterior ao dia atual no fuso
+// da empresa
+func (r *deliveryRepository) GetOverdueDeliveries(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var deliveries []models.Delivery
 	var total int64
 
-	now := time.Now()
-	query := r.db.Model(&models.Delivery{}).
-		Where("delivery_date < ? AND status IN ?", now, []string{models.DeliveryStatusPending, models.DeliveryStatusShipped})
+	query := db.Conn(ctx, r.db).Model(&models.Delivery{}).
+		Where("delivery_date < ? AND status IN ?", localtime.Today(ctx), []string{models.DeliveryStatusPending, models.DeliveryStatusPicking, models.DeliveryStatusPacked, models.DeliveryStatusShipped})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -924,9 +980,9 @@ func (r *deliveryRepository) GetOverdueDeliveries(params *pagination.PaginationP
 }
 
 // GetDeliveryTrackingInfo retorna informações detalhadas de rastreamento
-func (r *deliveryRepository) GetDeliveryTrackingInfo(id int) (*DeliveryTrackingInfo, error) {
+func (r *deliveryRepository) GetDeliveryTrackingInfo(ctx context.Context, id int) (*DeliveryTrackingInfo, error) {
 	// Busca a delivery com todos os relacionamentos
-	delivery, err := r.GetDeliveryByID(id)
+	delivery, err := r.GetDeliveryByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -937,10 +993,12 @@ func (r *deliveryRepository) GetDeliveryTrackingInfo(id int) (*DeliveryTrackingI
 		Status:          delivery.Status,
 		TrackingNumber:  delivery.TrackingNumber,
 		ShippingMethod:  delivery.ShippingMethod,
+		Carrier:         delivery.Carrier,
 		ShippingAddress: delivery.ShippingAddress,
 		DeliveryDate:    delivery.DeliveryDate,
 		ReceivedDate:    delivery.ReceivedDate,
 		Items:           make([]DeliveryItemStatus, 0),
+		Events:          make([]models.DeliveryTrackingEvent, 0),
 	}
 
 	// Processa os itens
@@ -966,15 +1024,22 @@ func (r *deliveryRepository) GetDeliveryTrackingInfo(id int) (*DeliveryTrackingI
 		tracking.Items = append(tracking.Items, itemStatus)
 	}
 
+	// Histórico de eventos informados pela transportadora
+	if err := db.Conn(ctx, r.db).Where("delivery_id = ?", id).
+		Order("occurred_at ASC, id ASC").
+		Find(&tracking.Events).Error; err != nil {
+		r.logger.Warn("erro ao buscar eventos de rastreamento", zap.Error(err), zap.Int("id", id))
+	}
+
 	return tracking, nil
 }
 
 // generateDeliveryNumber gera um número único para a delivery
-func (r *deliveryRepository) generateDeliveryNumber() string {
+func (r *deliveryRepository) generateDeliveryNumber(ctx context.Context) string {
 	// Implementação simples - você pode melhorar isso
 	var lastDelivery models.Delivery
 
-	r.db.Order("id DESC").First(&lastDelivery)
+	db.Conn(ctx, r.db).Order("id DESC").First(&lastDelivery)
 
 	year := time.Now().Year()
 	sequence := lastDelivery.ID + 1
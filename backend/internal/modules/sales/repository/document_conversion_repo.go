@@ -0,0 +1,736 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	calendarRepository "ERP-ONSMART/backend/internal/modules/calendar/repository"
+	contactModels "ERP-ONSMART/backend/internal/modules/contact/models"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DocumentConversionRepository define as conversões entre documentos do processo de venda
+type DocumentConversionRepository interface {
+	ConvertQuotationToSalesOrder(ctx context.Context, quotationID int, opts models.SalesOrderConversion) (*models.SalesOrder, error)
+	LinkSalesOrderToProcess(ctx context.Context, salesOrder *models.SalesOrder) error
+	GenerateInvoice(ctx context.Context, salesOrderID int, opts models.InvoiceGeneration) (*models.Invoice, error)
+	GenerateAdvanceInvoice(ctx context.Context, salesOrderID int, opts models.AdvanceInvoiceGeneration) (*models.Invoice, error)
+	GenerateDelivery(ctx context.Context, salesOrderID int, opts models.DeliveryGeneration) (*models.Delivery, error)
+	ApproveCreditHold(ctx context.Context, salesOrderID int, approvedBy string) (*models.SalesOrder, error)
+}
+
+type documentConversionRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewDocumentConversionRepository cria uma nova instância do repositório
+func NewDocumentConversionRepository() (DocumentConversionRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &documentConversionRepository{
+		db:     db,
+		logger: logger.WithModule("document_conversion_repository"),
+	}, nil
+}
+
+// LinkSalesOrderToProcess vincula o pedido convertido, e a fatura de adiantamento emitida com
+// ele, ao processo de venda da cotação de origem, criando o processo se a cotação ainda não tiver um
+func (r *documentConversionRepository) LinkSalesOrderToProcess(ctx context.Context, salesOrder *models.SalesOrder) error {
+	return db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var quotation models.Quotation
+		if err := tx.First(&quotation, salesOrder.QuotationID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrQuotationNotFound
+			}
+			r.logger.Error("erro ao buscar cotação", zap.Error(err), zap.Int("id", salesOrder.QuotationID))
+			return errors.WrapError(err, "falha ao buscar cotação")
+		}
+
+		var advances []models.Invoice
+		if err := tx.Where("sales_order_id = ? AND kind = ?", salesOrder.ID, models.InvoiceKindAdvance).
+			Order("id ASC").Find(&advances).Error; err != nil {
+			r.logger.Error("erro ao buscar faturas de adiantamento", zap.Error(err), zap.Int("sales_order_id", salesOrder.ID))
+			return errors.WrapError(err, "falha ao buscar faturas de adiantamento")
+		}
+
+		processID, err := r.ensureProcess(tx, quotation.ContactID, "process_quotations", "quotation_id", models.QuotationEvent(&quotation), quotation.GrandTotal)
+		if err != nil {
+			return err
+		}
+		if err := r.linkToProcess(tx, processID, "process_sales_orders", "sales_order_id", models.SalesOrderEvent(salesOrder),
+			ProcessStatusSalesOrder, &salesOrder.GrandTotal); err != nil {
+			return err
+		}
+		for i := range advances {
+			if err := r.linkToProcess(tx, processID, "process_invoices", "invoice_id", models.InvoiceEvent(&advances[i]), ProcessStatusSalesOrder, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// processStageOrder define a ordem das etapas do processo de venda; o processo só avança
+var processStageOrder = map[string]int{
+	ProcessStatusDraft:      0,
+	ProcessStatusQuotation:  1,
+	ProcessStatusSalesOrder: 2,
+	ProcessStatusPurchase:   3,
+	ProcessStatusDelivery:   4,
+	ProcessStatusInvoicing:  5,
+	ProcessStatusPayment:    6,
+	ProcessStatusCompleted:  7,
+}
+
+// ConvertQuotationToSalesOrder gera o pedido de venda a partir da cotação, copiando os itens e
+// marcando a cotação como aceita. O vínculo com o processo de venda fica com
+// LinkSalesOrderToProcess, chamado pelo serviço na mesma unidade de trabalho.
+func (r *documentConversionRepository) ConvertQuotationToSalesOrder(ctx context.Context, quotationID int, opts models.SalesOrderConversion) (*models.SalesOrder, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var quotation models.Quotation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&quotation, quotationID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrQuotationNotFound
+		}
+		r.logger.Error("erro ao buscar cotação", zap.Error(err), zap.Int("id", quotationID))
+		return nil, errors.WrapError(err, "falha ao buscar cotação")
+	}
+
+	switch quotation.Status {
+	case models.QuotationStatusDraft, models.QuotationStatusSent, models.QuotationStatusAccepted:
+	default:
+		tx.Rollback()
+		return nil, errors.ErrQuotationNotConvertible
+	}
+	if err := contactRepository.EnsureNotBlocked(tx, quotation.ContactID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var converted int64
+	if err := tx.Model(&models.SalesOrder{}).
+		Where("quotation_id = ? AND status <> ?", quotationID, models.SOStatusCancelled).
+		Count(&converted).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao verificar conversão da cotação", zap.Error(err), zap.Int("id", quotationID))
+		return nil, errors.WrapError(err, "falha ao verificar conversão da cotação")
+	}
+	if converted > 0 {
+		tx.Rollback()
+		return nil, errors.ErrQuotationAlreadyConverted
+	}
+
+	if err := tx.Where("quotation_id = ?", quotationID).Order("id ASC").Find(&quotation.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar itens da cotação", zap.Error(err), zap.Int("id", quotationID))
+		return nil, errors.WrapError(err, "falha ao buscar itens da cotação")
+	}
+	if len(quotation.Items) == 0 {
+		tx.Rollback()
+		return nil, errors.ErrQuotationNotConvertible
+	}
+
+	salesOrder := models.SalesOrderFromQuotation(&quotation)
+	salesOrder.SONo = NextDocumentNumber(tx, &models.SalesOrder{}, "SO")
+	salesOrder.ExpectedDate = opts.ExpectedDate
+	salesOrder.PaymentTerms = opts.PaymentTerms
+	salesOrder.ShippingAddress = opts.ShippingAddress
+	salesOrder.CustomFields = opts.CustomFields
+	if err := applyConversionAddresses(tx, salesOrder, opts); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Acima do limite de crédito, o pedido é recusado ou fica retido até a liberação
+	credit, err := contactRepository.LoadCreditStatus(tx, salesOrder.ContactID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !credit.Allows(salesOrder.GrandTotal.Float64()) {
+		if credit.CreditPolicy == contactModels.CreditPolicyBlock {
+			tx.Rollback()
+			return nil, fmt.Errorf("%w: exposição %.2f + pedido %s, limite %.2f", errors.ErrCreditLimitExceeded,
+				credit.Exposure, salesOrder.GrandTotal, *credit.CreditLimit)
+		}
+		salesOrder.Status = models.SOStatusCreditHold
+	}
+
+	if err := tx.Omit(clause.Associations).Create(salesOrder).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar sales order", zap.Error(err), zap.Int("quotation_id", quotationID))
+		return nil, errors.WrapError(err, "falha ao criar sales order")
+	}
+	for i := range salesOrder.Items {
+		salesOrder.Items[i].SalesOrderID = salesOrder.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&salesOrder.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar itens do sales order", zap.Error(err), zap.Int("id", salesOrder.ID))
+		return nil, errors.WrapError(err, "falha ao criar itens do sales order")
+	}
+
+	if quotation.Status != models.QuotationStatusAccepted {
+		if err := tx.Model(&quotation).Update("status", models.QuotationStatusAccepted).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao aceitar cotação", zap.Error(err), zap.Int("id", quotationID))
+			return nil, errors.WrapError(err, "falha ao atualizar status da cotação")
+		}
+	}
+
+	// O adiantamento pedido na confirmação é faturado junto com o pedido
+	if opts.DownPayment != nil {
+		if _, err := r.createAdvanceInvoice(tx, salesOrder, models.AdvanceInvoiceGeneration{DownPayment: *opts.DownPayment}); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("cotação convertida em sales order",
+		zap.Int("quotation_id", quotationID),
+		zap.Int("sales_order_id", salesOrder.ID),
+		zap.String("so_no", salesOrder.SONo))
+	return salesOrder, nil
+}
+
+// GenerateInvoice gera a fatura com o saldo ainda não faturado do pedido de venda
+func (r *documentConversionRepository) GenerateInvoice(ctx context.Context, salesOrderID int, opts models.InvoiceGeneration) (*models.Invoice, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	salesOrder, err := r.lockSalesOrder(tx, salesOrderID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	switch salesOrder.Status {
+	case models.SOStatusConfirmed, models.SOStatusProcessing, models.SOStatusCompleted:
+	default:
+		tx.Rollback()
+		return nil, errors.ErrSalesOrderNotInvoiceable
+	}
+	if err := contactRepository.EnsureNotBlocked(tx, salesOrder.ContactID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	invoiced, err := invoicedQuantities(tx, salesOrderID)
+	if err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar itens já faturados", zap.Error(err), zap.Int("id", salesOrderID))
+		return nil, err
+	}
+
+	invoice, err := models.InvoiceFromSalesOrder(salesOrder, invoiced)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Os adiantamentos do pedido ainda não deduzidos são abatidos desta fatura
+	requested, deducted, err := advanceBalance(tx, salesOrderID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	invoice.DeductAdvance(requested.Sub(deducted))
+
+	invoice.InvoiceNo = NextDocumentNumber(tx, &models.Invoice{}, "INV")
+	invoice.IssueDate = opts.IssueDate
+	invoice.DueDate = opts.DueDate
+	terms, err := contactRepository.LoadPaymentTerms(tx, salesOrder.ContactID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var installmentDates []time.Time
+	if terms != nil || invoice.DueDate.IsZero() {
+		// A condição estruturada do contato prevalece sobre o texto do pedido; sem vencimento
+		// informado, ambas são calculadas pelo calendário da empresa
+		schedule, err := calendarRepository.LoadSchedule(tx)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if terms != nil {
+			installmentDates = models.ApplyPaymentTerms(invoice, terms, schedule)
+		} else {
+			invoice.DueDate = schedule.PaymentDueDate(invoice.IssueDate, invoice.PaymentTerms)
+		}
+	}
+
+	if err := tx.Omit(clause.Associations).Create(invoice).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar invoice", zap.Error(err), zap.Int("sales_order_id", salesOrderID))
+		return nil, errors.WrapError(err, "falha ao criar invoice")
+	}
+	for i := range invoice.Items {
+		invoice.Items[i].InvoiceID = invoice.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&invoice.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar itens da invoice", zap.Error(err), zap.Int("id", invoice.ID))
+		return nil, errors.WrapError(err, "falha ao criar itens da invoice")
+	}
+	if len(installmentDates) > 0 {
+		installments, err := models.BuildInstallments(invoice, models.InstallmentPlan{DueDates: installmentDates})
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Create(&installments).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao criar parcelas da invoice", zap.Error(err), zap.Int("id", invoice.ID))
+			return nil, errors.WrapError(err, "falha ao criar parcelas da invoice")
+		}
+		invoice.Installments = installments
+	}
+
+	processID, err := r.ensureProcess(tx, salesOrder.ContactID, "process_sales_orders", "sales_order_id", models.SalesOrderEvent(salesOrder), salesOrder.GrandTotal)
+	if err == nil {
+		err = r.linkToProcess(tx, processID, "process_invoices", "invoice_id", models.InvoiceEvent(invoice), ProcessStatusInvoicing, nil)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("invoice gerada a partir do sales order",
+		zap.Int("sales_order_id", salesOrderID),
+		zap.Int("invoice_id", invoice.ID),
+		zap.String("invoice_no", invoice.InvoiceNo),
+		zap.String("advance_deduction", invoice.AdvanceDeduction.StringFixed(2)))
+	return invoice, nil
+}
+
+// GenerateAdvanceInvoice gera a fatura de adiantamento do pedido confirmado, abatida depois das
+// faturas dos itens
+func (r *documentConversionRepository) GenerateAdvanceInvoice(ctx context.Context, salesOrderID int, opts models.AdvanceInvoiceGeneration) (*models.Invoice, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	salesOrder, err := r.lockSalesOrder(tx, salesOrderID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	switch salesOrder.Status {
+	case models.SOStatusConfirmed, models.SOStatusProcessing:
+	default:
+		tx.Rollback()
+		return nil, errors.ErrSalesOrderNotInvoiceable
+	}
+	if err := contactRepository.EnsureNotBlocked(tx, salesOrder.ContactID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	invoice, err := r.createAdvanceInvoice(tx, salesOrder, opts)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	processID, err := r.ensureProcess(tx, salesOrder.ContactID, "process_sales_orders", "sales_order_id", models.SalesOrderEvent(salesOrder), salesOrder.GrandTotal)
+	if err == nil {
+		err = r.linkToProcess(tx, processID, "process_invoices", "invoice_id", models.InvoiceEvent(invoice), ProcessStatusSalesOrder, nil)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("fatura de adiantamento gerada",
+		zap.Int("sales_order_id", salesOrderID),
+		zap.Int("invoice_id", invoice.ID),
+		zap.String("amount", invoice.GrandTotal.StringFixed(2)))
+	return invoice, nil
+}
+
+// createAdvanceInvoice cria a fatura de adiantamento do pedido dentro da transação; sem emissão,
+// emite hoje e, sem vencimento, vence na emissão
+func (r *documentConversionRepository) createAdvanceInvoice(tx *gorm.DB, salesOrder *models.SalesOrder, opts models.AdvanceInvoiceGeneration) (*models.Invoice, error) {
+	requested, _, err := advanceBalance(tx, salesOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := opts.AdvanceAmount(salesOrder.GrandTotal, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := models.AdvanceInvoiceFromSalesOrder(salesOrder, opts.DownPayment, amount)
+	invoice.InvoiceNo = NextDocumentNumber(tx, &models.Invoice{}, "INV")
+	invoice.IssueDate = opts.IssueDate
+	if invoice.IssueDate.IsZero() {
+		invoice.IssueDate = time.Now()
+	}
+	invoice.DueDate = opts.DueDate
+	if invoice.DueDate.IsZero() {
+		invoice.DueDate = invoice.IssueDate
+	}
+
+	if err := tx.Omit(clause.Associations).Create(invoice).Error; err != nil {
+		r.logger.Error("erro ao criar fatura de adiantamento", zap.Error(err), zap.Int("sales_order_id", salesOrder.ID))
+		return nil, errors.WrapError(err, "falha ao criar fatura de adiantamento")
+	}
+	return invoice, nil
+}
+
+// advanceBalance soma as faturas de adiantamento do pedido (requested) e o quanto delas já foi
+// abatido nas faturas dos itens (deducted), desconsiderando as canceladas
+func advanceBalance(tx *gorm.DB, salesOrderID int) (requested, deducted money.Decimal, err error) {
+	var row struct {
+		Requested money.Decimal
+		Deducted  money.Decimal
+	}
+	if err := tx.Model(&models.Invoice{}).
+		Select("COALESCE(SUM(CASE WHEN kind = ? THEN grand_total ELSE 0 END), 0) AS requested, COALESCE(SUM(advance_deduction), 0) AS deducted",
+			models.InvoiceKindAdvance).
+		Where("sales_order_id = ? AND status <> ?", salesOrderID, models.InvoiceStatusCancelled).
+		Scan(&row).Error; err != nil {
+		return money.Zero, money.Zero, errors.WrapError(err, "falha ao somar adiantamentos do pedido")
+	}
+	return row.Requested, row.Deducted, nil
+}
+
+// GenerateDelivery gera a entrega com o saldo ainda não enviado do pedido de venda
+func (r *documentConversionRepository) GenerateDelivery(ctx context.Context, salesOrderID int, opts models.DeliveryGeneration) (*models.Delivery, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	salesOrder, err := r.lockSalesOrder(tx, salesOrderID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	switch salesOrder.Status {
+	case models.SOStatusDraft, models.SOStatusCreditHold, models.SOStatusCancelled:
+		tx.Rollback()
+		return nil, errors.ErrSalesOrderNotShippable
+	}
+	if err := contactRepository.EnsureNotBlocked(tx, salesOrder.ContactID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	shipped, err := loadShippedLines(tx, salesOrderID)
+	if err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao buscar itens entregues", zap.Error(err), zap.Int("id", salesOrderID))
+		return nil, err
+	}
+
+	fulfillment := models.BuildFulfillment(salesOrder, shipped, nil)
+	delivery, err := models.DeliveryFromSalesOrder(salesOrder, fulfillment)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := PrepareDeliveryItems(tx, delivery.Items); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	delivery.DeliveryNo = NextDocumentNumber(tx, &models.Delivery{}, "DLV")
+	delivery.DeliveryDate = opts.DeliveryDate
+	if opts.ShippingMethod != "" {
+		delivery.ShippingMethod = opts.ShippingMethod
+	}
+	if opts.Carrier != "" {
+		delivery.Carrier = opts.Carrier
+	}
+
+	if err := tx.Omit(clause.Associations, "PurchaseOrderID").Create(delivery).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar delivery", zap.Error(err), zap.Int("sales_order_id", salesOrderID))
+		return nil, errors.WrapError(err, "falha ao criar delivery")
+	}
+	for i := range delivery.Items {
+		delivery.Items[i].DeliveryID = delivery.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&delivery.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar itens da delivery", zap.Error(err), zap.Int("id", delivery.ID))
+		return nil, errors.WrapError(err, "falha ao criar itens da delivery")
+	}
+
+	if salesOrder.Status == models.SOStatusConfirmed {
+		if err := tx.Model(salesOrder).Update("status", models.SOStatusProcessing).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao atualizar status do sales order", zap.Error(err), zap.Int("id", salesOrderID))
+			return nil, errors.WrapError(err, "falha ao atualizar status do sales order")
+		}
+	}
+
+	processID, err := r.ensureProcess(tx, salesOrder.ContactID, "process_sales_orders", "sales_order_id", models.SalesOrderEvent(salesOrder), salesOrder.GrandTotal)
+	if err == nil {
+		err = r.linkToProcess(tx, processID, "process_deliveries", "delivery_id", models.DeliveryEvent(delivery), ProcessStatusDelivery, nil)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("delivery gerada a partir do sales order",
+		zap.Int("sales_order_id", salesOrderID),
+		zap.Int("delivery_id", delivery.ID),
+		zap.String("delivery_no", delivery.DeliveryNo))
+	return delivery, nil
+}
+
+// lockSalesOrder bloqueia o pedido de venda e suas linhas, serializando as gerações concorrentes
+// ApproveCreditHold libera o pedido retido pelo limite de crédito, que passa a confirmado
+func (r *documentConversionRepository) ApproveCreditHold(ctx context.Context, salesOrderID int, approvedBy string) (*models.SalesOrder, error) {
+	var salesOrder models.SalesOrder
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&salesOrder, salesOrderID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrSalesOrderNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar sales order")
+		}
+		if salesOrder.Status != models.SOStatusCreditHold {
+			return errors.ErrNotOnCreditHold
+		}
+
+		now := time.Now()
+		salesOrder.Status = models.SOStatusConfirmed
+		salesOrder.CreditApprovedBy = &approvedBy
+		salesOrder.CreditApprovedAt = &now
+		if err := tx.Model(&salesOrder).Updates(map[string]interface{}{
+			"status":             salesOrder.Status,
+			"credit_approved_by": approvedBy,
+			"credit_approved_at": now,
+		}).Error; err != nil {
+			r.logger.Error("erro ao liberar crédito do sales order", zap.Error(err), zap.Int("id", salesOrderID))
+			return errors.WrapError(err, "falha ao liberar crédito do sales order")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &salesOrder, nil
+}
+
+func (r *documentConversionRepository) lockSalesOrder(tx *gorm.DB, id int) (*models.SalesOrder, error) {
+	var salesOrder models.SalesOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&salesOrder, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrSalesOrderNotFound
+		}
+		r.logger.Error("erro ao buscar sales order", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar sales order")
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sales_order_id = ?", id).
+		Order("id ASC").
+		Find(&salesOrder.Items).Error; err != nil {
+		r.logger.Error("erro ao buscar itens do sales order", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar itens do sales order")
+	}
+	return &salesOrder, nil
+}
+
+// ensureProcess retorna o processo de venda vinculado ao documento de origem,
+// criando e vinculando um novo processo quando ainda não existir
+func (r *documentConversionRepository) ensureProcess(tx *gorm.DB, contactID int, linkTable, linkColumn string, document models.ProcessEvent, totalValue money.Decimal) (int, error) {
+	var processIDs []int
+	if err := tx.Table(linkTable).
+		Where(linkColumn+" = ?", document.DocumentID).
+		Order("process_id ASC").
+		Limit(1).
+		Pluck("process_id", &processIDs).Error; err != nil {
+		r.logger.Error("erro ao buscar processo de venda", zap.Error(err), zap.String("link", linkTable))
+		return 0, errors.WrapError(err, "falha ao buscar processo de venda")
+	}
+	if len(processIDs) > 0 {
+		return processIDs[0], nil
+	}
+
+	status := ProcessStatusQuotation
+	if linkTable == "process_sales_orders" {
+		status = ProcessStatusSalesOrder
+	}
+
+	process := models.SalesProcess{
+		ContactID:  contactID,
+		Status:     status,
+		TotalValue: totalValue,
+	}
+	if err := tx.Omit(clause.Associations).Create(&process).Error; err != nil {
+		r.logger.Error("erro ao criar processo de venda", zap.Error(err))
+		return 0, errors.WrapError(err, "falha ao criar processo de venda")
+	}
+
+	if err := linkDocument(tx, process.ID, linkTable, linkColumn, document.DocumentID); err != nil {
+		r.logger.Error("erro ao vincular documento ao processo", zap.Error(err), zap.Int("process_id", process.ID))
+		return 0, err
+	}
+	created := models.ProcessEvent{
+		Timestamp:    process.CreatedAt,
+		EventType:    models.ProcessEventCreated,
+		Description:  "Processo de venda iniciado",
+		DocumentType: "sales_process",
+		DocumentID:   process.ID,
+	}
+	for _, event := range []models.ProcessEvent{created, document} {
+		if err := RecordProcessEvent(tx, process.ID, event); err != nil {
+			r.logger.Error("erro ao registrar evento do processo", zap.Error(err), zap.Int("process_id", process.ID))
+			return 0, err
+		}
+	}
+	return process.ID, nil
+}
+
+// linkToProcess vincula o documento gerado ao processo, avança a etapa do processo e registra
+// ambos na linha do tempo
+func (r *documentConversionRepository) linkToProcess(tx *gorm.DB, processID int, linkTable, linkColumn string, document models.ProcessEvent, stage string, totalValue *money.Decimal) error {
+	if err := linkDocument(tx, processID, linkTable, linkColumn, document.DocumentID); err != nil {
+		r.logger.Error("erro ao vincular documento ao processo", zap.Error(err), zap.Int("process_id", processID))
+		return err
+	}
+	if err := RecordProcessEvent(tx, processID, document); err != nil {
+		r.logger.Error("erro ao registrar evento do processo", zap.Error(err), zap.Int("process_id", processID))
+		return err
+	}
+
+	var process models.SalesProcess
+	if err := tx.First(&process, processID).Error; err != nil {
+		r.logger.Error("erro ao buscar processo de venda", zap.Error(err), zap.Int("process_id", processID))
+		return errors.WrapError(err, "falha ao buscar processo de venda")
+	}
+
+	previous := process.Status
+	updates := map[string]interface{}{}
+	if process.Status != ProcessStatusCancelled && processStageOrder[stage] > processStageOrder[process.Status] {
+		// no quadro, o processo entra no topo da coluna da nova etapa
+		updates["status"] = stage
+		updates["board_position"] = 0
+	}
+	if totalValue != nil {
+		updates["total_value"] = *totalValue
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	if err := tx.Model(&process).Updates(updates).Error; err != nil {
+		r.logger.Error("erro ao atualizar processo de venda", zap.Error(err), zap.Int("process_id", processID))
+		return errors.WrapError(err, "falha ao atualizar processo de venda")
+	}
+	if _, advanced := updates["status"]; advanced {
+		if err := RecordProcessEvent(tx, processID, StatusChangedEvent(previous, stage, time.Now())); err != nil {
+			r.logger.Error("erro ao registrar evento do processo", zap.Error(err), zap.Int("process_id", processID))
+			return err
+		}
+	}
+	return nil
+}
+
+// linkDocument grava o vínculo entre o processo e o documento, ignorando vínculos já existentes
+func linkDocument(tx *gorm.DB, processID int, linkTable, linkColumn string, documentID int) error {
+	if err := tx.Exec(
+		fmt.Sprintf("INSERT INTO %s (process_id, %s) VALUES (?, ?) ON CONFLICT DO NOTHING", linkTable, linkColumn),
+		processID, documentID).Error; err != nil {
+		return errors.WrapError(err, "falha ao vincular documento ao processo de venda")
+	}
+	return nil
+}
+
+// invoicedQuantities retorna a quantidade já faturada por produto (em unidades de estoque) nas
+// faturas não canceladas do pedido
+func invoicedQuantities(tx *gorm.DB, salesOrderID int) (map[int]int, error) {
+	var rows []struct {
+		ProductID int
+		Quantity  int
+	}
+	if err := tx.Raw(`
+		SELECT ii.product_id, COALESCE(SUM(ii.quantity * ii.unit_factor), 0) AS quantity
+		FROM invoice_items ii
+		JOIN invoices i ON i.id = ii.invoice_id
+		WHERE i.sales_order_id = ? AND i.status <> ?
+		GROUP BY ii.product_id`,
+		salesOrderID, models.InvoiceStatusCancelled).
+		Scan(&rows).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar itens já faturados do pedido")
+	}
+
+	invoiced := make(map[int]int, len(rows))
+	for _, row := range rows {
+		invoiced[row.ProductID] = row.Quantity
+	}
+	return invoiced, nil
+}
+
+// NextDocumentNumber gera o próximo número do documento dentro da transação (PREFIXO-ANO-SEQUÊNCIA);
+// usado também pelos módulos que criam documentos de venda (ex.: importação de pedidos do e-commerce)
+func NextDocumentNumber(tx *gorm.DB, model interface{}, prefix string) string {
+	var lastID int
+	tx.Model(model).Select("COALESCE(MAX(id), 0)").Scan(&lastID)
+	return fmt.Sprintf("%s-%d-%06d", prefix, time.Now().Year(), lastID+1)
+}
+
+// applyConversionAddresses preenche os endereços do pedido pelo catálogo do contato: os informados
+// na conversão ou, sem ID, os padrões de entrega e de cobrança. O texto livre de entrega informado
+// sem ID prevalece sobre o padrão.
+func applyConversionAddresses(tx *gorm.DB, salesOrder *models.SalesOrder, opts models.SalesOrderConversion) error {
+	if opts.ShippingAddressID != nil || opts.ShippingAddress == "" {
+		address, err := contactRepository.ResolveAddress(tx, salesOrder.ContactID, opts.ShippingAddressID, contactModels.AddressTypeShipping)
+		if err != nil {
+			return err
+		}
+		if address != nil {
+			salesOrder.ShippingAddressID = &address.ID
+			salesOrder.ShippingAddress = address.Format()
+		}
+	}
+
+	address, err := contactRepository.ResolveAddress(tx, salesOrder.ContactID, opts.BillingAddressID, contactModels.AddressTypeBilling)
+	if err != nil {
+		return err
+	}
+	if address != nil {
+		salesOrder.BillingAddressID = &address.ID
+		salesOrder.BillingAddress = address.Format()
+	}
+	return nil
+}
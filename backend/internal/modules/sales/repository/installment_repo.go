@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InstallmentRepository grava as parcelas das faturas e a cobrança de cada uma
+type InstallmentRepository interface {
+	GetInstallments(ctx context.Context, invoiceID int) ([]models.InvoiceInstallment, error)
+	GetInstallment(ctx context.Context, invoiceID, number int) (*models.Invoice, *models.InvoiceInstallment, error)
+	ReplaceInstallments(ctx context.Context, invoiceID int, plan models.InstallmentPlan) (*models.Invoice, []models.InvoiceInstallment, error)
+	SaveCharge(ctx context.Context, installment *models.InvoiceInstallment) error
+}
+
+type installmentRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewInstallmentRepository cria uma nova instância do repositório
+func NewInstallmentRepository() (InstallmentRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &installmentRepository{
+		db:     gormDB,
+		logger: logger.WithModule("installment_repository"),
+	}, nil
+}
+
+// GetInstallments lista as parcelas da fatura com o status do dia: parcelas que venceram desde
+// o último pagamento passam a vencidas, e a fatura acompanha
+func (r *installmentRepository) GetInstallments(ctx context.Context, invoiceID int) ([]models.InvoiceInstallment, error) {
+	var installments []models.InvoiceInstallment
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var invoice models.Invoice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, invoiceID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrInvoiceNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar invoice")
+		}
+
+		status, err := applyInstallmentPayments(tx, &invoice, invoice.AmountPaid)
+		if err != nil {
+			return err
+		}
+		if status != invoice.Status {
+			if err := tx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).Update("status", status).Error; err != nil {
+				return errors.WrapError(err, "falha ao atualizar status da invoice")
+			}
+		}
+		installments = invoice.Installments
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// GetInstallment busca a parcela pelo número, com a fatura
+func (r *installmentRepository) GetInstallment(ctx context.Context, invoiceID, number int) (*models.Invoice, *models.InvoiceInstallment, error) {
+	conn := db.Conn(ctx, r.db)
+	var invoice models.Invoice
+	if err := conn.First(&invoice, invoiceID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, errors.ErrInvoiceNotFound
+		}
+		return nil, nil, errors.WrapError(err, "falha ao buscar invoice")
+	}
+
+	var installment models.InvoiceInstallment
+	if err := conn.Where("invoice_id = ? AND number = ?", invoiceID, number).First(&installment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, errors.ErrInstallmentNotFound
+		}
+		return nil, nil, errors.WrapError(err, "falha ao buscar parcela")
+	}
+	return &invoice, &installment, nil
+}
+
+// ReplaceInstallments divide a fatura pelo plano, no lugar das parcelas atuais. O valor já pago
+// é redistribuído entre as novas parcelas e o status da fatura é recalculado.
+func (r *installmentRepository) ReplaceInstallments(ctx context.Context, invoiceID int, plan models.InstallmentPlan) (*models.Invoice, []models.InvoiceInstallment, error) {
+	var invoice models.Invoice
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, invoiceID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrInvoiceNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar invoice")
+		}
+		if invoice.Status == models.InvoiceStatusCancelled {
+			return errors.ErrInvoiceNotPayable
+		}
+
+		installments, err := models.BuildInstallments(&invoice, plan)
+		if err != nil {
+			return err
+		}
+		models.DistributeInstallmentPayments(installments, invoice.AmountPaid, localtime.Today(ctx))
+
+		if err := tx.Where("invoice_id = ?", invoice.ID).Delete(&models.InvoiceInstallment{}).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover parcelas da invoice")
+		}
+		if err := tx.Create(&installments).Error; err != nil {
+			return errors.WrapError(err, "falha ao criar parcelas da invoice")
+		}
+
+		status := models.InstallmentsInvoiceStatus(installments, invoice.Status)
+		if status != invoice.Status {
+			if err := tx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).Update("status", status).Error; err != nil {
+				return errors.WrapError(err, "falha ao atualizar status da invoice")
+			}
+			invoice.Status = status
+		}
+		invoice.Installments = installments
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.logger.Info("parcelas da invoice gravadas", zap.Int("invoice_id", invoiceID), zap.Int("installments", len(invoice.Installments)))
+	return &invoice, invoice.Installments, nil
+}
+
+// SaveCharge grava o boleto e o Pix gerados para a parcela
+func (r *installmentRepository) SaveCharge(ctx context.Context, installment *models.InvoiceInstallment) error {
+	err := db.Conn(ctx, r.db).Model(&models.InvoiceInstallment{}).
+		Where("id = ?", installment.ID).
+		Updates(map[string]interface{}{
+			"boleto_our_number":     installment.BoletoOurNumber,
+			"boleto_barcode":        installment.BoletoBarcode,
+			"boleto_digitable_line": installment.BoletoDigitableLine,
+			"pix_payload":           installment.PixPayload,
+		}).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao gravar cobrança da parcela")
+	}
+	return nil
+}
+
+// applyInstallmentPayments reparte o valor pago entre as parcelas da fatura, grava as que
+// mudaram e devolve o status da fatura: o das parcelas quando dividida, ou o do valor pago
+func applyInstallmentPayments(tx *gorm.DB, invoice *models.Invoice, paid money.Decimal) (string, error) {
+	var installments []models.InvoiceInstallment
+	if err := tx.Where("invoice_id = ?", invoice.ID).Order("number").Find(&installments).Error; err != nil {
+		return "", errors.WrapError(err, "falha ao buscar parcelas da invoice")
+	}
+	invoice.Installments = installments
+	if len(installments) == 0 {
+		return models.InvoicePaymentStatus(invoice.Status, paid, invoice.GrandTotal), nil
+	}
+
+	previous := make([]models.InvoiceInstallment, len(installments))
+	copy(previous, installments)
+	models.DistributeInstallmentPayments(installments, paid, localtime.Today(tx.Statement.Context))
+	for i, installment := range installments {
+		if installment.Status == previous[i].Status && installment.AmountPaid.Equal(previous[i].AmountPaid) {
+			continue
+		}
+		if err := tx.Model(&models.InvoiceInstallment{}).Where("id = ?", installment.ID).
+			Updates(map[string]interface{}{"amount_paid": installment.AmountPaid, "status": installment.Status}).Error; err != nil {
+			return "", errors.WrapError(err, "falha ao atualizar parcela")
+		}
+	}
+	return models.InstallmentsInvoiceStatus(installments, invoice.Status), nil
+}
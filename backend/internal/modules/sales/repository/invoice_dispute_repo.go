@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Status das faturas emitidas que ainda têm saldo a cobrar
+var disputableInvoiceStatuses = []string{
+	models.InvoiceStatusSent, models.InvoiceStatusPartial, models.InvoiceStatusOverdue,
+}
+
+// InvoiceDisputeRepository grava as contestações das faturas e as ações que as resolvem
+type InvoiceDisputeRepository interface {
+	ListDisputes(ctx context.Context, filter models.DisputeFilter) ([]models.InvoiceDispute, error)
+	GetDispute(ctx context.Context, invoiceID, id int) (*models.InvoiceDispute, error)
+	OpenDispute(ctx context.Context, invoiceID int, input models.InvoiceDisputeInput, username string) (*models.InvoiceDispute, error)
+	ResolveDispute(ctx context.Context, invoiceID, id int, input models.DisputeResolutionInput, username string, now time.Time) (*models.InvoiceDispute, error)
+}
+
+type invoiceDisputeRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewInvoiceDisputeRepository cria uma nova instância do repositório
+func NewInvoiceDisputeRepository() (InvoiceDisputeRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &invoiceDisputeRepository{
+		db:     gormDB,
+		logger: logger.WithModule("invoice_dispute_repository"),
+	}, nil
+}
+
+// disputeQuery seleciona as contestações com o número da parcela contestada
+func disputeQuery(conn *gorm.DB) *gorm.DB {
+	return conn.Model(&models.InvoiceDispute{}).
+		Select("invoice_disputes.*, ii.number AS installment_number").
+		Joins("LEFT JOIN invoice_installments ii ON ii.id = invoice_disputes.installment_id")
+}
+
+// ListDisputes lista as contestações conforme o filtro, das mais recentes
+func (r *invoiceDisputeRepository) ListDisputes(ctx context.Context, filter models.DisputeFilter) ([]models.InvoiceDispute, error) {
+	query := disputeQuery(db.Conn(ctx, r.db))
+	if filter.Status != "" {
+		query = query.Where("invoice_disputes.status = ?", filter.Status)
+	}
+	if filter.InvoiceID > 0 {
+		query = query.Where("invoice_disputes.invoice_id = ?", filter.InvoiceID)
+	}
+
+	disputes := make([]models.InvoiceDispute, 0)
+	if err := query.Order("invoice_disputes.created_at DESC, invoice_disputes.id DESC").Find(&disputes).Error; err != nil {
+		r.logger.Error("erro ao listar contestações", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar contestações")
+	}
+	return disputes, nil
+}
+
+// GetDispute busca a contestação da fatura
+func (r *invoiceDisputeRepository) GetDispute(ctx context.Context, invoiceID, id int) (*models.InvoiceDispute, error) {
+	var dispute models.InvoiceDispute
+	err := disputeQuery(db.Conn(ctx, r.db)).
+		Where("invoice_disputes.invoice_id = ? AND invoice_disputes.id = ?", invoiceID, id).
+		Take(&dispute).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvoiceDisputeNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar contestação")
+	}
+	return &dispute, nil
+}
+
+// OpenDispute abre a contestação da fatura ou da parcela, com a fatura travada: o valor contestado
+// vai até o saldo em aberto, e a fatura (ou a parcela) tem no máximo uma contestação aberta
+func (r *invoiceDisputeRepository) OpenDispute(ctx context.Context, invoiceID int, input models.InvoiceDisputeInput, username string) (*models.InvoiceDispute, error) {
+	var dispute *models.InvoiceDispute
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		invoice, err := lockDisputedInvoice(tx, invoiceID)
+		if err != nil {
+			return err
+		}
+		if !isDisputable(invoice) {
+			return errors.ErrInvoiceNotDisputable
+		}
+
+		var installmentID *int
+		balance := invoice.GrandTotal.Sub(invoice.AmountPaid)
+		if input.InstallmentNumber != nil {
+			var installment models.InvoiceInstallment
+			err := tx.Where("invoice_id = ? AND number = ?", invoiceID, *input.InstallmentNumber).First(&installment).Error
+			if err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.ErrInstallmentNotFound
+				}
+				return errors.WrapError(err, "falha ao buscar parcela")
+			}
+			installmentID = &installment.ID
+			balance = money.Min(balance, installment.Amount.Sub(installment.AmountPaid))
+		}
+
+		var open int64
+		query := tx.Model(&models.InvoiceDispute{}).Where("invoice_id = ? AND status = ?", invoiceID, models.DisputeStatusOpen)
+		if installmentID != nil {
+			query = query.Where("installment_id = ?", *installmentID)
+		} else {
+			query = query.Where("installment_id IS NULL")
+		}
+		if err := query.Count(&open).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar contestações da fatura")
+		}
+		if open > 0 {
+			return errors.ErrInvoiceAlreadyDisputed
+		}
+
+		dispute, err = input.ToDispute(invoiceID, installmentID, balance, username)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(dispute).Error; err != nil {
+			return errors.WrapError(err, "falha ao gravar contestação")
+		}
+		if input.InstallmentNumber != nil {
+			dispute.InstallmentNumber = *input.InstallmentNumber
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("contestação não aberta", zap.Error(err), zap.Int("invoice_id", invoiceID))
+		return nil, err
+	}
+
+	r.logger.Info("contestação aberta", zap.Int("id", dispute.ID), zap.Int("invoice_id", invoiceID),
+		zap.Stringer("amount", dispute.Amount))
+	return dispute, nil
+}
+
+// ResolveDispute resolve a contestação aberta e aplica a ação na mesma transação: emite a nota de
+// crédito contra a fatura, muda o vencimento (da parcela contestada ou das parcelas em aberto
+// vencidas antes da nova data) e recalcula o status da fatura, ou registra a baixa do valor
+func (r *invoiceDisputeRepository) ResolveDispute(ctx context.Context, invoiceID, id int, input models.DisputeResolutionInput, username string, now time.Time) (*models.InvoiceDispute, error) {
+	var dispute models.InvoiceDispute
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		invoice, err := lockDisputedInvoice(tx, invoiceID)
+		if err != nil {
+			return err
+		}
+		if err := disputeQuery(tx).Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "invoice_disputes"}}).
+			Where("invoice_disputes.invoice_id = ? AND invoice_disputes.id = ?", invoiceID, id).
+			Take(&dispute).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrInvoiceDisputeNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar contestação")
+		}
+		if err := dispute.Resolve(input, username, localtime.Today(ctx), now); err != nil {
+			return err
+		}
+
+		switch dispute.Resolution {
+		case models.DisputeResolutionCreditNote:
+			note := dispute.CreditNote(invoice, NextDocumentNumber(tx, &models.CreditNote{}, "CN"), now)
+			if err := tx.Omit(clause.Associations).Create(&note).Error; err != nil {
+				return errors.WrapError(err, "falha ao criar nota de crédito")
+			}
+			dispute.CreditNoteID = &note.ID
+		case models.DisputeResolutionDueDate:
+			if err := adjustDueDate(tx, invoice, dispute.InstallmentID, *dispute.NewDueDate); err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&models.InvoiceDispute{}).Where("id = ?", dispute.ID).Updates(map[string]interface{}{
+			"status":            dispute.Status,
+			"resolution":        dispute.Resolution,
+			"resolution_amount": dispute.ResolutionAmount,
+			"credit_note_id":    dispute.CreditNoteID,
+			"new_due_date":      dispute.NewDueDate,
+			"resolution_notes":  dispute.ResolutionNotes,
+			"resolved_by":       dispute.ResolvedBy,
+			"resolved_at":       dispute.ResolvedAt,
+			"updated_at":        now,
+		}).Error
+		if err != nil {
+			return errors.WrapError(err, "falha ao resolver contestação")
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("contestação não resolvida", zap.Error(err), zap.Int("id", id))
+		return nil, err
+	}
+
+	r.logger.Info("contestação resolvida", zap.Int("id", id), zap.String("resolution", dispute.Resolution),
+		zap.Stringer("amount", dispute.ResolutionAmount))
+	return &dispute, nil
+}
+
+// lockDisputedInvoice trava a fatura contestada
+func lockDisputedInvoice(tx *gorm.DB, invoiceID int) (*models.Invoice, error) {
+	var invoice models.Invoice
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, invoiceID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar invoice")
+	}
+	return &invoice, nil
+}
+
+// isDisputable indica se a fatura foi emitida e ainda tem saldo a cobrar
+func isDisputable(invoice *models.Invoice) bool {
+	if !invoice.GrandTotal.GreaterThan(invoice.AmountPaid) {
+		return false
+	}
+	for _, status := range disputableInvoiceStatuses {
+		if invoice.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// adjustDueDate grava o novo vencimento acertado na contestação e recalcula o status das parcelas
+// e da fatura, que deixa de estar vencida quando nada mais venceu
+func adjustDueDate(tx *gorm.DB, invoice *models.Invoice, installmentID *int, dueDate time.Time) error {
+	installments := tx.Model(&models.InvoiceInstallment{})
+	if installmentID != nil {
+		installments = installments.Where("id = ?", *installmentID)
+	} else {
+		installments = installments.Where("invoice_id = ? AND status <> ? AND due_date < ?",
+			invoice.ID, models.InstallmentStatusPaid, dueDate)
+		if err := tx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).Update("due_date", dueDate).Error; err != nil {
+			return errors.WrapError(err, "falha ao atualizar vencimento da invoice")
+		}
+		invoice.DueDate = dueDate
+	}
+	if err := installments.Update("due_date", dueDate).Error; err != nil {
+		return errors.WrapError(err, "falha ao atualizar vencimento das parcelas")
+	}
+
+	status, err := applyInstallmentPayments(tx, invoice, invoice.AmountPaid)
+	if err != nil {
+		return err
+	}
+	if len(invoice.Installments) == 0 && status == models.InvoiceStatusOverdue {
+		status = models.InvoicePaymentStatus(models.InvoiceStatusSent, invoice.AmountPaid, invoice.GrandTotal)
+	}
+	if status != invoice.Status {
+		if err := tx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).Update("status", status).Error; err != nil {
+			return errors.WrapError(err, "falha ao atualizar status da invoice")
+		}
+		invoice.Status = status
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	inventory "ERP-ONSMART/backend/internal/modules/inventory/models"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	products "ERP-ONSMART/backend/internal/modules/products/repository"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	stderrors "errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InvoiceMarginData reúne o que a conferência da margem da fatura precisa: a fatura com os itens,
+// o CMV já apurado por item, a categoria de cada produto, as margens mínimas das categorias e a
+// liberação, se houver
+type InvoiceMarginData struct {
+	Invoice       *models.Invoice
+	RecordedCosts map[int]money.Decimal
+	CategoryOf    map[int]int
+	MinMargins    map[int]product.MinMargin
+	Approval      *models.InvoiceMarginApproval
+}
+
+// InvoiceMarginRepository lê os dados da conferência de margem e grava as liberações
+type InvoiceMarginRepository interface {
+	GetMarginData(ctx context.Context, invoiceID int) (*InvoiceMarginData, error)
+	SaveApproval(ctx context.Context, approval *models.InvoiceMarginApproval) error
+}
+
+type invoiceMarginRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewInvoiceMarginRepository cria uma nova instância do repositório
+func NewInvoiceMarginRepository() (InvoiceMarginRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &invoiceMarginRepository{
+		db:     gormDB,
+		logger: logger.WithModule("invoice_margin_repository"),
+	}, nil
+}
+
+// GetMarginData carrega a fatura e os dados da conferência de margem
+func (r *invoiceMarginRepository) GetMarginData(ctx context.Context, invoiceID int) (*InvoiceMarginData, error) {
+	conn := db.Conn(ctx, r.db)
+	var invoice models.Invoice
+	if err := conn.First(&invoice, invoiceID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar invoice")
+	}
+	if err := conn.Where("invoice_id = ?", invoiceID).Order("id ASC").Find(&invoice.Items).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar itens da invoice")
+	}
+	data := &InvoiceMarginData{
+		Invoice:       &invoice,
+		RecordedCosts: make(map[int]money.Decimal),
+		CategoryOf:    make(map[int]int),
+	}
+
+	var err error
+	if data.MinMargins, err = products.LoadMinMargins(conn); err != nil {
+		return nil, err
+	}
+	if len(invoice.Items) > 0 {
+		productIDs := make([]int, 0, len(invoice.Items))
+		for _, item := range invoice.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		var rows []product.Product
+		if err := conn.Select("id, category_id").Where("id IN ? AND category_id IS NOT NULL", productIDs).Find(&rows).Error; err != nil {
+			return nil, errors.WrapError(err, "falha ao buscar categorias dos produtos")
+		}
+		for _, row := range rows {
+			data.CategoryOf[row.ID] = *row.CategoryID
+		}
+
+		var costs []struct {
+			SourceItemID int
+			Total        money.Decimal
+		}
+		if err := conn.Model(&inventory.COGSEntry{}).
+			Select("source_item_id, SUM(total_cost) AS total").
+			Where("source_type = ? AND source_id = ?", inventory.COGSSourceInvoice, invoiceID).
+			Group("source_item_id").
+			Scan(&costs).Error; err != nil {
+			return nil, errors.WrapError(err, "falha ao buscar CMV da invoice")
+		}
+		for _, cost := range costs {
+			data.RecordedCosts[cost.SourceItemID] = cost.Total
+		}
+	}
+
+	var approval models.InvoiceMarginApproval
+	err = conn.Where("invoice_id = ?", invoiceID).Take(&approval).Error
+	if err == nil {
+		data.Approval = &approval
+	} else if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WrapError(err, "falha ao buscar liberação de margem")
+	}
+	return data, nil
+}
+
+// SaveApproval grava a liberação de margem da fatura; uma nova liberação substitui a anterior
+func (r *invoiceMarginRepository) SaveApproval(ctx context.Context, approval *models.InvoiceMarginApproval) error {
+	if err := db.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "invoice_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"approved_by", "reason", "margin_percent", "created_at"}),
+	}).Create(approval).Error; err != nil {
+		r.logger.Error("erro ao gravar liberação de margem", zap.Error(err), zap.Int("invoice_id", approval.InvoiceID))
+		return errors.WrapError(err, "falha ao gravar liberação de margem")
+	}
+
+	r.logger.Info("margem da fatura liberada", zap.Int("invoice_id", approval.InvoiceID),
+		zap.String("approved_by", approval.ApprovedBy), zap.Float64("margin_percent", approval.MarginPercent))
+	return nil
+}
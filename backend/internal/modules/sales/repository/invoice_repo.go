@@ -3,10 +3,14 @@ package repository
 import (
 	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
 	"ERP-ONSMART/backend/internal/logger"
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/listquery"
 	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
 	"fmt"
 	"time"
 
@@ -16,22 +20,22 @@ import (
 
 // InvoiceRepository define as operações do repositório de invoices
 type InvoiceRepository interface {
-	CreateInvoice(invoice *models.Invoice) error
-	GetInvoiceByID(id int) (*models.Invoice, error)
-	GetAllInvoices(params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	UpdateInvoice(id int, invoice *models.Invoice) error
-	DeleteInvoice(id int) error
-	GetInvoicesByStatus(status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetInvoicesByContact(contactID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetOverdueInvoices(params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetInvoicesBySalesOrder(salesOrderID int) ([]models.Invoice, error)
-	GetInvoicesByPeriod(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetInvoicesByDueDateRange(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetInvoicesByIssueDateRange(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	SearchInvoices(filter InvoiceFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetInvoiceStats(filter InvoiceFilter) (*InvoiceStats, error)
-	GetContactInvoicesSummary(contactID int) (*ContactInvoicesSummary, error)
-	GetInvoicesByContactType(contactType string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	CreateInvoice(ctx context.Context, invoice *models.Invoice) error
+	GetInvoiceByID(ctx context.Context, id int) (*models.Invoice, error)
+	GetAllInvoices(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	UpdateInvoice(ctx context.Context, id int, invoice *models.Invoice) error
+	DeleteInvoice(ctx context.Context, id int) error
+	GetInvoicesByStatus(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetInvoicesByContact(ctx context.Context, contactID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetOverdueInvoices(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetInvoicesBySalesOrder(ctx context.Context, salesOrderID int) ([]models.Invoice, error)
+	GetInvoicesByPeriod(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetInvoicesByDueDateRange(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetInvoicesByIssueDateRange(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	SearchInvoices(ctx context.Context, filter InvoiceFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetInvoiceStats(ctx context.Context, filter InvoiceFilter) (*InvoiceStats, error)
+	GetContactInvoicesSummary(ctx context.Context, contactID int) (*ContactInvoicesSummary, error)
+	GetInvoicesByContactType(ctx context.Context, contactType string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
 }
 
 // InvoiceFilter define os filtros para busca avançada
@@ -49,6 +53,8 @@ type InvoiceFilter struct {
 	HasPayment     *bool
 	IsOverdue      *bool
 	SearchQuery    string
+	Sort           []listquery.SortField // ?sort=; vazio ordena por created_at DESC
+	Fields         []string              // ?fields=; vazio carrega Contact e Items
 }
 
 // InvoiceStats representa estatísticas de invoices
@@ -76,31 +82,54 @@ type ContactInvoicesSummary struct {
 
 type invoiceRepository struct {
 	db     *gorm.DB
+	reader *gorm.DB // réplica de leitura para as consultas analíticas (ver db.Provider)
 	logger *zap.Logger
 }
 
 // NewInvoiceRepository cria uma nova instância do repositório
 func NewInvoiceRepository() (InvoiceRepository, error) {
-	db, err := db.OpenGormDB()
+	provider, err := db.OpenProvider()
 	if err != nil {
 		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
 	}
 
 	return &invoiceRepository{
-		db:     db,
+		db:     provider.Writer(),
+		reader: provider.Reader(),
 		logger: logger.WithModule("invoice_repository"),
 	}, nil
 }
 
 // CreateInvoice cria uma nova invoice no banco
-func (r *invoiceRepository) CreateInvoice(invoice *models.Invoice) error {
+func (r *invoiceRepository) CreateInvoice(ctx context.Context, invoice *models.Invoice) error {
 	// Gera o número da invoice se não foi fornecido
 	if invoice.InvoiceNo == "" {
-		invoice.InvoiceNo = r.generateInvoiceNumber()
+		invoice.InvoiceNo = r.generateInvoiceNumber(ctx)
+	}
+
+	// Totais informados pelo cliente precisam conferir com os itens
+	if err := models.ApplyInvoiceTotals(invoice); err != nil {
+		return err
 	}
 
 	// Inicia transação
-	tx := r.db.Begin()
+	tx := db.Conn(ctx, r.db).Begin()
+
+	// Contato bloqueado não recebe novas faturas
+	if err := contactRepository.EnsureNotBlocked(tx, invoice.ContactID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Confere as unidades e desdobra os kits com modo explode nas linhas dos componentes
+	items, err := PrepareInvoiceItems(tx, invoice.Items)
+	if err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao preparar itens da invoice", zap.Error(err))
+		return err
+	}
+	invoice.Items = items
+	models.RecalculateInvoiceTotals(invoice)
 
 	// Cria a invoice
 	if err := tx.Create(invoice).Error; err != nil {
@@ -132,10 +161,10 @@ func (r *invoiceRepository) CreateInvoice(invoice *models.Invoice) error {
 }
 
 // GetInvoiceByID busca uma invoice pelo ID
-func (r *invoiceRepository) GetInvoiceByID(id int) (*models.Invoice, error) {
+func (r *invoiceRepository) GetInvoiceByID(ctx context.Context, id int) (*models.Invoice, error) {
 	var invoice models.Invoice
 
-	query := r.db.Preload("Contact").
+	query := db.Conn(ctx, r.db).Preload("Contact").
 		Preload("SalesOrder").
 		Preload("Items").
 		Preload("Items.Product").
@@ -153,12 +182,12 @@ func (r *invoiceRepository) GetInvoiceByID(id int) (*models.Invoice, error) {
 }
 
 // GetAllInvoices retorna todas as invoices com paginação
-func (r *invoiceRepository) GetAllInvoices(params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *invoiceRepository) GetAllInvoices(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
 	// Query base
-	query := r.db.Model(&models.Invoice{})
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -183,19 +212,35 @@ func (r *invoiceRepository) GetAllInvoices(params *pagination.PaginationParams)
 }
 
 // UpdateInvoice atualiza uma invoice existente
-func (r *invoiceRepository) UpdateInvoice(id int, invoice *models.Invoice) error {
+func (r *invoiceRepository) UpdateInvoice(ctx context.Context, id int, invoice *models.Invoice) error {
 	// Verifica se a invoice existe
 	var existing models.Invoice
-	if err := r.db.First(&existing, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrInvoiceNotFound
 		}
 		return errors.WrapError(err, "falha ao verificar invoice existente")
 	}
 
+	// Os totais vêm dos itens; sem itens no payload, valem os já gravados
+	if len(invoice.Items) > 0 {
+		if err := models.ApplyInvoiceTotals(invoice); err != nil {
+			return err
+		}
+	} else {
+		if err := db.Conn(ctx, r.db).Where("invoice_id = ?", id).Find(&invoice.Items).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar itens da invoice")
+		}
+		err := models.ApplyInvoiceTotals(invoice)
+		invoice.Items = nil
+		if err != nil {
+			return err
+		}
+	}
+
 	// Atualiza os campos
 	invoice.ID = id
-	if err := r.db.Save(invoice).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(invoice).Error; err != nil {
 		r.logger.Error("erro ao atualizar invoice", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao atualizar invoice")
 	}
@@ -204,11 +249,11 @@ func (r *invoiceRepository) UpdateInvoice(id int, invoice *models.Invoice) error
 	return nil
 }
 
-// DeleteInvoice remove uma invoice
-func (r *invoiceRepository) DeleteInvoice(id int) error {
+// DeleteInvoice move uma invoice para a lixeira (soft delete)
+func (r *invoiceRepository) DeleteInvoice(ctx context.Context, id int) error {
 	// Verifica se existem pagamentos relacionados
 	var paymentCount int64
-	if err := r.db.Model(&models.Payment{}).Where("invoice_id = ?", id).Count(&paymentCount).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Model(&models.Payment{}).Where("invoice_id = ?", id).Count(&paymentCount).Error; err != nil {
 		return errors.WrapError(err, "falha ao verificar pagamentos relacionados")
 	}
 
@@ -216,8 +261,24 @@ func (r *invoiceRepository) DeleteInvoice(id int) error {
 		return errors.ErrRelatedRecordsExist
 	}
 
-	// Remove a invoice (cascade removerá os itens)
-	result := r.db.Delete(&models.Invoice{}, id)
+	// A fatura de adiantamento já abatida nas faturas do pedido não pode sair
+	var invoices []models.Invoice
+	if err := db.Conn(ctx, r.db).Select("id, kind, sales_order_id, status, grand_total").
+		Where("id = ?", id).Limit(1).Find(&invoices).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar invoice")
+	}
+	if len(invoices) > 0 && invoices[0].Kind == models.InvoiceKindAdvance && invoices[0].Status != models.InvoiceStatusCancelled {
+		requested, deducted, err := advanceBalance(db.Conn(ctx, r.db), invoices[0].SalesOrderID)
+		if err != nil {
+			return err
+		}
+		if requested.Sub(invoices[0].GrandTotal).LessThan(deducted) {
+			return errors.ErrAdvanceInvoiceDeducted
+		}
+	}
+
+	// Soft delete: os itens são mantidos para permitir a restauração
+	result := db.Conn(ctx, r.db).Delete(&models.Invoice{}, id)
 	if result.Error != nil {
 		r.logger.Error("erro ao deletar invoice", zap.Error(result.Error), zap.Int("id", id))
 		return errors.WrapError(result.Error, "falha ao deletar invoice")
@@ -232,11 +293,11 @@ func (r *invoiceRepository) DeleteInvoice(id int) error {
 }
 
 // GetInvoicesByStatus busca invoices por status
-func (r *invoiceRepository) GetInvoicesByStatus(status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *invoiceRepository) GetInvoicesByStatus(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
-	query := r.db.Model(&models.Invoice{}).Where("status = ?", status)
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{}).Where("status = ?", status)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -260,11 +321,11 @@ func (r *invoiceRepository) GetInvoicesByStatus(status string, params *paginatio
 }
 
 // GetInvoicesByContact busca invoices por contato
-func (r *invoiceRepository) GetInvoicesByContact(contactID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *invoiceRepository) GetInvoicesByContact(ctx context.Context, contactID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
-	query := r.db.Model(&models.Invoice{}).Where("contact_id = ?", contactID)
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{}).Where("contact_id = ?", contactID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -288,14 +349,14 @@ func (r *invoiceRepository) GetInvoicesByContact(contactID int, params *paginati
 	return result, nil
 }
 
-// GetOverdueInvoices busca invoices vencidas
-func (r *invoiceRepository) GetOverdueInvoices(params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+// GetOverdueInvoices busca invoices vencidas: vencimento anterior ao dia atual no fuso da empresa
+func (r *invoiceRepository) GetOverdueInvoices(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
-	now := time.Now()
-	query := r.db.Model(&models.Invoice{}).
-		Where("due_date < ? AND status != ?", now, models.InvoiceStatusPaid).
+	today := localtime.Today(ctx)
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{}).
+		Where("due_date < ? AND status != ?", today, models.InvoiceStatusPaid).
 		Where("status != ?", models.InvoiceStatusCancelled)
 
 	// Conta o total
@@ -320,10 +381,10 @@ func (r *invoiceRepository) GetOverdueInvoices(params *pagination.PaginationPara
 }
 
 // GetInvoicesBySalesOrder busca invoices por pedido de venda
-func (r *invoiceRepository) GetInvoicesBySalesOrder(salesOrderID int) ([]models.Invoice, error) {
+func (r *invoiceRepository) GetInvoicesBySalesOrder(ctx context.Context, salesOrderID int) ([]models.Invoice, error) {
 	var invoices []models.Invoice
 
-	if err := r.db.Where("sales_order_id = ?", salesOrderID).
+	if err := db.Conn(ctx, r.db).Where("sales_order_id = ?", salesOrderID).
 		Preload("Contact").
 		Preload("Items").
 		Find(&invoices).Error; err != nil {
@@ -335,11 +396,11 @@ func (r *invoiceRepository) GetInvoicesBySalesOrder(salesOrderID int) ([]models.
 }
 
 // GetInvoicesByPeriod busca invoices por período (usando created_at)
-func (r *invoiceRepository) GetInvoicesByPeriod(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *invoiceRepository) GetInvoicesByPeriod(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
-	query := r.db.Model(&models.Invoice{}).
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{}).
 		Where("created_at >= ? AND created_at <= ?", startDate, endDate)
 
 	// Conta o total
@@ -365,11 +426,11 @@ func (r *invoiceRepository) GetInvoicesByPeriod(startDate, endDate time.Time, pa
 }
 
 // GetInvoicesByDueDateRange busca invoices por período de vencimento
-func (r *invoiceRepository) GetInvoicesByDueDateRange(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *invoiceRepository) GetInvoicesByDueDateRange(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
-	query := r.db.Model(&models.Invoice{}).
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{}).
 		Where("due_date >= ? AND due_date <= ?", startDate, endDate)
 
 	// Conta o total
@@ -395,11 +456,11 @@ func (r *invoiceRepository) GetInvoicesByDueDateRange(startDate, endDate time.Ti
 }
 
 // GetInvoicesByIssueDateRange busca invoices por período de emissão
-func (r *invoiceRepository) GetInvoicesByIssueDateRange(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *invoiceRepository) GetInvoicesByIssueDateRange(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
-	query := r.db.Model(&models.Invoice{}).
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{}).
 		Where("issue_date >= ? AND issue_date <= ?", startDate, endDate)
 
 	// Conta o total
@@ -425,11 +486,11 @@ func (r *invoiceRepository) GetInvoicesByIssueDateRange(startDate, endDate time.
 }
 
 // SearchInvoices busca invoices com filtros combinados
-func (r *invoiceRepository) SearchInvoices(filter InvoiceFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *invoiceRepository) SearchInvoices(ctx context.Context, filter InvoiceFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
-	query := r.db.Model(&models.Invoice{})
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{})
 
 	// Aplica os filtros
 	if len(filter.Status) > 0 {
@@ -442,7 +503,7 @@ func (r *invoiceRepository) SearchInvoices(filter InvoiceFilter, params *paginat
 
 	// Filtro por tipo de contato ou pessoa
 	if filter.ContactType != "" || filter.PersonType != "" {
-		contactQuery := r.db.Model(&contact.Contact{})
+		contactQuery := db.Conn(ctx, r.db).Model(&contact.Contact{})
 		if filter.ContactType != "" {
 			contactQuery = contactQuery.Where("type = ?", filter.ContactType)
 		}
@@ -476,8 +537,7 @@ func (r *invoiceRepository) SearchInvoices(filter InvoiceFilter, params *paginat
 
 	// Filtro de vencimento
 	if filter.IsOverdue != nil && *filter.IsOverdue {
-		now := time.Now()
-		query = query.Where("due_date < ? AND status != ?", now, models.InvoiceStatusPaid).
+		query = query.Where("due_date < ? AND status != ?", localtime.Today(ctx), models.InvoiceStatusPaid).
 			Where("status != ?", models.InvoiceStatusCancelled)
 	}
 
@@ -504,11 +564,9 @@ func (r *invoiceRepository) SearchInvoices(filter InvoiceFilter, params *paginat
 		return nil, errors.WrapError(err, "falha ao contar invoices na busca")
 	}
 
-	// Aplica paginação e busca os dados
+	// Aplica ordenação, seleção de campos e paginação e busca os dados
 	offset := pagination.CalculateOffset(params.Page, params.PageSize)
-	if err := query.Preload("Contact").
-		Preload("Items").
-		Order("created_at DESC").
+	if err := InvoiceListSpec.Apply(query, filter.Sort, filter.Fields).
 		Limit(params.PageSize).
 		Offset(offset).
 		Find(&invoices).Error; err != nil {
@@ -521,12 +579,12 @@ func (r *invoiceRepository) SearchInvoices(filter InvoiceFilter, params *paginat
 }
 
 // GetInvoiceStats retorna estatísticas de invoices
-func (r *invoiceRepository) GetInvoiceStats(filter InvoiceFilter) (*InvoiceStats, error) {
+func (r *invoiceRepository) GetInvoiceStats(ctx context.Context, filter InvoiceFilter) (*InvoiceStats, error) {
 	stats := &InvoiceStats{
 		CountByStatus: make(map[string]int),
 	}
 
-	query := r.db.Model(&models.Invoice{})
+	query := r.reader.WithContext(ctx).Model(&models.Invoice{})
 
 	// Aplica filtros básicos
 	if filter.ContactID > 0 {
@@ -555,11 +613,10 @@ func (r *invoiceRepository) GetInvoiceStats(filter InvoiceFilter) (*InvoiceStats
 	stats.TotalPending = stats.TotalValue - stats.TotalPaid
 
 	// Valor vencido
-	now := time.Now()
 	var overdueValue float64
-	if err := query.Where("due_date < ? AND status != ?", now, models.InvoiceStatusPaid).
+	if err := query.Where("due_date < ? AND status != ?", localtime.Today(ctx), models.InvoiceStatusPaid).
 		Where("status != ?", models.InvoiceStatusCancelled).
-		Select("SUM(grand_total - amount_paid)").
+		Select("SUM(GREATEST(grand_total - amount_paid - " + models.DisputedAmountSQL("invoices") + ", 0))").
 		Scan(&overdueValue).Error; err != nil {
 		r.logger.Warn("erro ao calcular valor vencido", zap.Error(err))
 	}
@@ -587,14 +644,14 @@ func (r *invoiceRepository) GetInvoiceStats(filter InvoiceFilter) (*InvoiceStats
 }
 
 // GetContactInvoicesSummary retorna um resumo das invoices de um contato
-func (r *invoiceRepository) GetContactInvoicesSummary(contactID int) (*ContactInvoicesSummary, error) {
+func (r *invoiceRepository) GetContactInvoicesSummary(ctx context.Context, contactID int) (*ContactInvoicesSummary, error) {
 	summary := &ContactInvoicesSummary{
 		ContactID: contactID,
 	}
 
 	// Busca informações do contato
 	var contact contact.Contact
-	if err := r.db.First(&contact, contactID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&contact, contactID).Error; err != nil {
 		return nil, errors.WrapError(err, "falha ao buscar contato")
 	}
 
@@ -611,7 +668,7 @@ func (r *invoiceRepository) GetContactInvoicesSummary(contactID int) (*ContactIn
 		TotalPaid  float64
 	}
 
-	if err := r.db.Model(&models.Invoice{}).
+	if err := db.Conn(ctx, r.db).Model(&models.Invoice{}).
 		Where("contact_id = ?", contactID).
 		Select("COUNT(*) as count, SUM(grand_total) as total_value, SUM(amount_paid) as total_paid").
 		Scan(&stats).Error; err != nil {
@@ -624,16 +681,16 @@ func (r *invoiceRepository) GetContactInvoicesSummary(contactID int) (*ContactIn
 	summary.TotalPending = stats.TotalValue - stats.TotalPaid
 
 	// Invoices vencidas
-	now := time.Now()
 	var overdueStats struct {
 		Count int
 		Value float64
 	}
 
-	if err := r.db.Model(&models.Invoice{}).
-		Where("contact_id = ? AND due_date < ? AND status != ?", contactID, now, models.InvoiceStatusPaid).
+	if err := db.Conn(ctx, r.db).Model(&models.Invoice{}).
+		Where("contact_id = ? AND due_date < ? AND status != ?", contactID, localtime.Today(ctx), models.InvoiceStatusPaid).
 		Where("status != ?", models.InvoiceStatusCancelled).
-		Select("COUNT(*) as count, SUM(grand_total - amount_paid) as value").
+		Where("grand_total - amount_paid > " + models.DisputedAmountSQL("invoices")).
+		Select("COUNT(*) as count, SUM(grand_total - amount_paid - " + models.DisputedAmountSQL("invoices") + ") as value").
 		Scan(&overdueStats).Error; err != nil {
 		r.logger.Warn("erro ao calcular invoices vencidas do contato", zap.Error(err))
 	}
@@ -645,13 +702,13 @@ func (r *invoiceRepository) GetContactInvoicesSummary(contactID int) (*ContactIn
 }
 
 // GetInvoicesByContactType busca invoices por tipo de contato
-func (r *invoiceRepository) GetInvoicesByContactType(contactType string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *invoiceRepository) GetInvoicesByContactType(ctx context.Context, contactType string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var invoices []models.Invoice
 	var total int64
 
 	// Primeiro, busca os IDs dos contatos do tipo especificado
 	var contactIDs []int
-	if err := r.db.Model(&contact.Contact{}).
+	if err := db.Conn(ctx, r.db).Model(&contact.Contact{}).
 		Where("type = ?", contactType).
 		Pluck("id", &contactIDs).Error; err != nil {
 		return nil, errors.WrapError(err, "falha ao buscar contatos por tipo")
@@ -663,7 +720,7 @@ func (r *invoiceRepository) GetInvoicesByContactType(contactType string, params
 	}
 
 	// Busca as invoices dos contatos encontrados
-	query := r.db.Model(&models.Invoice{}).Where("contact_id IN ?", contactIDs)
+	query := db.Conn(ctx, r.db).Model(&models.Invoice{}).Where("contact_id IN ?", contactIDs)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -688,11 +745,11 @@ func (r *invoiceRepository) GetInvoicesByContactType(contactType string, params
 }
 
 // generateInvoiceNumber gera um número único para a invoice
-func (r *invoiceRepository) generateInvoiceNumber() string {
+func (r *invoiceRepository) generateInvoiceNumber(ctx context.Context) string {
 	// Implementação simples - você pode melhorar isso
 	var lastInvoice models.Invoice
 
-	r.db.Order("id DESC").First(&lastInvoice)
+	db.Conn(ctx, r.db).Order("id DESC").First(&lastInvoice)
 
 	year := time.Now().Year()
 	sequence := lastInvoice.ID + 1
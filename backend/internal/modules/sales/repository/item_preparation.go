@@ -0,0 +1,142 @@
+package repository
+
+import (
+	contractModels "ERP-ONSMART/backend/internal/modules/contracts/models"
+	contracts "ERP-ONSMART/backend/internal/modules/contracts/repository"
+	products "ERP-ONSMART/backend/internal/modules/products/repository"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Os itens dos documentos passam por aqui antes de serem gravados:
+//   - nas cotações, produtos descontinuados ou em fim de vida são recusados com o substituto
+//     sugerido (os documentos já emitidos com eles continuam válidos);
+//   - a unidade de cada linha é conferida com as unidades do produto e o fator vigente é gravado
+//     na linha (sem unidade, vale a unidade padrão de venda ou de compra do produto);
+//   - nos documentos de venda, os kits com modo explode viram as linhas dos componentes; os kits
+//     com modo keep seguem como uma linha só (o estoque e o custo são baixados nos componentes
+//     pelo custeio);
+//   - nas cotações e pedidos de compra, o preço das linhas cobertas por contrato vigente com o
+//     contato vem do contrato (ver ApplyQuotationContract e ApplyPurchaseOrderContract).
+
+// PrepareQuotationItems recusa produtos descontinuados, resolve as unidades e desdobra os kits
+// dos itens da cotação
+func PrepareQuotationItems(tx *gorm.DB, items []models.QuotationItem) ([]models.QuotationItem, error) {
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	if err := products.EnsureQuotable(tx, productIDs); err != nil {
+		return nil, err
+	}
+	units, err := products.LoadUnits(tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ApplyQuotationUnits(items, units); err != nil {
+		return nil, err
+	}
+	kits, err := products.LoadKits(tx, productIDs)
+	if err != nil || len(kits) == 0 {
+		return items, err
+	}
+	return models.ExplodeQuotationKits(items, kits), nil
+}
+
+// PrepareSalesOrderItems resolve as unidades e desdobra os kits dos itens do pedido de venda
+func PrepareSalesOrderItems(tx *gorm.DB, items []models.SOItem) ([]models.SOItem, error) {
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	units, err := products.LoadUnits(tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ApplySalesOrderUnits(items, units); err != nil {
+		return nil, err
+	}
+	kits, err := products.LoadKits(tx, productIDs)
+	if err != nil || len(kits) == 0 {
+		return items, err
+	}
+	return models.ExplodeSalesOrderKits(items, kits), nil
+}
+
+// PrepareInvoiceItems resolve as unidades e desdobra os kits dos itens da fatura
+func PrepareInvoiceItems(tx *gorm.DB, items []models.InvoiceItem) ([]models.InvoiceItem, error) {
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	units, err := products.LoadUnits(tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ApplyInvoiceUnits(items, units); err != nil {
+		return nil, err
+	}
+	kits, err := products.LoadKits(tx, productIDs)
+	if err != nil || len(kits) == 0 {
+		return items, err
+	}
+	return models.ExplodeInvoiceKits(items, kits), nil
+}
+
+// PreparePurchaseOrderItems resolve as unidades dos itens do pedido de compra
+func PreparePurchaseOrderItems(tx *gorm.DB, items []models.POItem) error {
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	units, err := products.LoadUnits(tx, productIDs)
+	if err != nil {
+		return err
+	}
+	return models.ApplyPurchaseOrderUnits(items, units)
+}
+
+// PrepareDeliveryItems converte os itens da entrega para a unidade de estoque
+func PrepareDeliveryItems(tx *gorm.DB, items []models.DeliveryItem) error {
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	units, err := products.LoadUnits(tx, productIDs)
+	if err != nil {
+		return err
+	}
+	return models.ApplyDeliveryUnits(items, units)
+}
+
+// ApplyQuotationContract aplica aos itens da cotação os preços do contrato de venda vigente com
+// o cliente na data e recalcula os totais
+func ApplyQuotationContract(tx *gorm.DB, quotation *models.Quotation, date time.Time) error {
+	productIDs := make([]int, 0, len(quotation.Items))
+	for _, item := range quotation.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	prices, err := contracts.LoadPrices(tx, quotation.ContactID, contractModels.TypeSales, productIDs, date)
+	if err != nil {
+		return err
+	}
+	models.ApplyQuotationContractPrices(quotation, prices)
+	return nil
+}
+
+// ApplyPurchaseOrderContract aplica aos itens do pedido de compra os preços do contrato de
+// compra vigente com o fornecedor na data e recalcula os totais
+func ApplyPurchaseOrderContract(tx *gorm.DB, po *models.PurchaseOrder, date time.Time) error {
+	productIDs := make([]int, 0, len(po.Items))
+	for _, item := range po.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	prices, err := contracts.LoadPrices(tx, po.ContactID, contractModels.TypePurchase, productIDs, date)
+	if err != nil {
+		return err
+	}
+	models.ApplyPurchaseOrderContractPrices(po, prices)
+	return nil
+}
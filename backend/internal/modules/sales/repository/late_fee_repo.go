@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"context"
+	stderrors "errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LateFeeRepository grava as regras de multa e juros por atraso e busca a regra que vale para
+// as faturas de cada contato
+type LateFeeRepository interface {
+	ListRules(ctx context.Context) ([]models.LateFeeRule, error)
+	SaveRule(ctx context.Context, rule *models.LateFeeRule) error
+	DeleteRule(ctx context.Context, id int) error
+	GetRule(ctx context.Context, contactID int) (*models.LateFeeRule, error)
+	GetInvoice(ctx context.Context, invoiceID int) (*models.Invoice, error)
+}
+
+type lateFeeRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewLateFeeRepository cria uma nova instância do repositório
+func NewLateFeeRepository() (LateFeeRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &lateFeeRepository{
+		db:     gormDB,
+		logger: logger.WithModule("late_fee_repository"),
+	}, nil
+}
+
+// lateFeeRuleQuery seleciona as regras com o nome do contato
+func lateFeeRuleQuery(conn *gorm.DB) *gorm.DB {
+	return conn.Model(&models.LateFeeRule{}).
+		Select("late_fee_rules.*, COALESCE(c.name, '') AS contact_name").
+		Joins("LEFT JOIN contacts c ON c.id = late_fee_rules.contact_id")
+}
+
+// ListRules lista a regra padrão da empresa (primeiro) e as regras dos contatos, pelo nome
+func (r *lateFeeRepository) ListRules(ctx context.Context) ([]models.LateFeeRule, error) {
+	rules := make([]models.LateFeeRule, 0)
+	err := lateFeeRuleQuery(db.Conn(ctx, r.db)).
+		Order("late_fee_rules.contact_id IS NOT NULL, contact_name ASC, late_fee_rules.id ASC").
+		Find(&rules).Error
+	if err != nil {
+		r.logger.Error("erro ao listar regras de multa e juros", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar regras de multa e juros")
+	}
+	return rules, nil
+}
+
+// SaveRule grava a regra padrão ou a do contato, substituindo a que já existir
+func (r *lateFeeRepository) SaveRule(ctx context.Context, rule *models.LateFeeRule) error {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+		if rule.ContactID != nil {
+			var count int64
+			if err := tx.Model(&contact.Contact{}).Where("id = ?", *rule.ContactID).Count(&count).Error; err != nil {
+				return errors.WrapError(err, "falha ao buscar contato")
+			}
+			if count == 0 {
+				return errors.ErrContactNotFound
+			}
+			query = query.Where("contact_id = ?", *rule.ContactID)
+		} else {
+			query = query.Where("contact_id IS NULL")
+		}
+
+		var existing models.LateFeeRule
+		err := query.Take(&existing).Error
+		switch {
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(rule).Error; err != nil {
+				return errors.WrapError(err, "falha ao criar regra de multa e juros")
+			}
+		case err != nil:
+			return errors.WrapError(err, "falha ao buscar regra de multa e juros")
+		default:
+			err = tx.Model(&existing).Updates(map[string]interface{}{
+				"fine_percent":     rule.FinePercent,
+				"fine_amount":      rule.FineAmount,
+				"interest_percent": rule.InterestPercent,
+				"grace_days":       rule.GraceDays,
+				"updated_by":       rule.UpdatedBy,
+			}).Error
+			if err != nil {
+				return errors.WrapError(err, "falha ao atualizar regra de multa e juros")
+			}
+			rule.ID = existing.ID
+		}
+		if err := lateFeeRuleQuery(tx).Where("late_fee_rules.id = ?", rule.ID).Take(rule).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar regra de multa e juros")
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Warn("regra de multa e juros não gravada", zap.Error(err))
+		return err
+	}
+
+	r.logger.Info("regra de multa e juros gravada", zap.Int("id", rule.ID), zap.Float64("fine_percent", rule.FinePercent),
+		zap.Float64("interest_percent", rule.InterestPercent), zap.String("updated_by", rule.UpdatedBy))
+	return nil
+}
+
+// DeleteRule remove a regra; sem a do contato, as faturas dele seguem a regra padrão
+func (r *lateFeeRepository) DeleteRule(ctx context.Context, id int) error {
+	result := db.Conn(ctx, r.db).Delete(&models.LateFeeRule{}, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao remover regra de multa e juros", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao remover regra de multa e juros")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrLateFeeRuleNotFound
+	}
+	r.logger.Info("regra de multa e juros removida", zap.Int("id", id))
+	return nil
+}
+
+// GetRule busca a regra que vale para as faturas do contato: a dele ou, sem ela, a padrão da
+// empresa. Sem nenhuma regra cadastrada, retorna nil.
+func (r *lateFeeRepository) GetRule(ctx context.Context, contactID int) (*models.LateFeeRule, error) {
+	var rules []models.LateFeeRule
+	err := lateFeeRuleQuery(db.Conn(ctx, r.db)).
+		Where("late_fee_rules.contact_id = ? OR late_fee_rules.contact_id IS NULL", contactID).
+		Order("late_fee_rules.contact_id IS NULL").Limit(1).Find(&rules).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar regra de multa e juros")
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &rules[0], nil
+}
+
+// GetInvoice busca a fatura com as parcelas, para o cálculo do valor devido
+func (r *lateFeeRepository) GetInvoice(ctx context.Context, invoiceID int) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := db.Conn(ctx, r.db).
+		Preload("Installments", func(tx *gorm.DB) *gorm.DB { return tx.Order("number ASC") }).
+		First(&invoice, invoiceID).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar invoice")
+	}
+	return &invoice, nil
+}
@@ -2,12 +2,20 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"database/sql"
 	"fmt"
 )
 
-func GetAllSales() ([]models.Sale, error) {
+func GetAllSales(ctx context.Context) ([]models.Sale, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return nil, err
@@ -15,12 +23,13 @@ func GetAllSales() ([]models.Sale, error) {
 	defer conn.Close()
 
 	query := `
-		SELECT id, product, quantity, price, customer
+		SELECT id, product, quantity, price, customer, COALESCE(customer_document, '')
 		FROM sales
+		WHERE ` + company + `
 		ORDER BY id
 	`
 
-	rows, err := conn.Query(query)
+	rows, err := conn.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +38,7 @@ func GetAllSales() ([]models.Sale, error) {
 	var sales []models.Sale
 	for rows.Next() {
 		var s models.Sale
-		if err := rows.Scan(&s.ID, &s.Product, &s.Quantity, &s.Price, &s.Customer); err != nil {
+		if err := rows.Scan(&s.ID, &s.Product, &s.Quantity, &s.Price, &s.Customer, &s.CustomerDocument); err != nil {
 			return nil, err
 		}
 		sales = append(sales, s)
@@ -38,7 +47,12 @@ func GetAllSales() ([]models.Sale, error) {
 	return sales, nil
 }
 
-func GetSaleByID(id int) (models.Sale, error) {
+func GetSaleByID(ctx context.Context, id int) (models.Sale, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return models.Sale{}, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return models.Sale{}, err
@@ -46,18 +60,18 @@ func GetSaleByID(id int) (models.Sale, error) {
 	defer conn.Close()
 
 	query := `
-		SELECT id, product, quantity, price, customer
+		SELECT id, product, quantity, price, customer, COALESCE(customer_document, '')
 		FROM sales
-		WHERE id = $1
-	`
+		WHERE id = $1 AND ` + company
 
 	var sale models.Sale
-	err = conn.QueryRow(query, id).Scan(
+	err = conn.QueryRowContext(ctx, query, id).Scan(
 		&sale.ID,
 		&sale.Product,
 		&sale.Quantity,
 		&sale.Price,
 		&sale.Customer,
+		&sale.CustomerDocument,
 	)
 
 	if err != nil {
@@ -70,7 +84,12 @@ func GetSaleByID(id int) (models.Sale, error) {
 	return sale, nil
 }
 
-func CreateSale(s models.Sale) (models.Sale, error) {
+func CreateSale(ctx context.Context, s models.Sale) (models.Sale, error) {
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		return models.Sale{}, errors.ErrTenantRequired
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return models.Sale{}, err
@@ -78,12 +97,12 @@ func CreateSale(s models.Sale) (models.Sale, error) {
 	defer conn.Close()
 
 	query := `
-		INSERT INTO sales (product, quantity, price, customer)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO sales (product, quantity, price, customer, customer_document, company_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
 		RETURNING id
 	`
 
-	err = conn.QueryRow(query, s.Product, s.Quantity, s.Price, s.Customer).Scan(&s.ID)
+	err = conn.QueryRowContext(ctx, query, s.Product, s.Quantity, s.Price, s.Customer, s.CustomerDocument, companyID).Scan(&s.ID)
 	if err != nil {
 		return models.Sale{}, err
 	}
@@ -91,7 +110,12 @@ func CreateSale(s models.Sale) (models.Sale, error) {
 	return s, nil
 }
 
-func UpdateSale(id int, updated models.Sale) (models.Sale, error) {
+func UpdateSale(ctx context.Context, id int, updated models.Sale) (models.Sale, error) {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return models.Sale{}, err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return models.Sale{}, err
@@ -103,11 +127,11 @@ func UpdateSale(id int, updated models.Sale) (models.Sale, error) {
 		SET product = $1,
 		    quantity = $2,
 		    price = $3,
-		    customer = $4
-		WHERE id = $5
-	`
+		    customer = $4,
+		    customer_document = NULLIF($5, '')
+		WHERE id = $6 AND ` + company
 
-	result, err := conn.Exec(query, updated.Product, updated.Quantity, updated.Price, updated.Customer, id)
+	result, err := conn.ExecContext(ctx, query, updated.Product, updated.Quantity, updated.Price, updated.Customer, updated.CustomerDocument, id)
 	if err != nil {
 		return models.Sale{}, err
 	}
@@ -125,16 +149,21 @@ func UpdateSale(id int, updated models.Sale) (models.Sale, error) {
 	return updated, nil
 }
 
-func DeleteSale(id int) error {
+func DeleteSale(ctx context.Context, id int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
 	conn, err := db.OpenDB()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	query := `DELETE FROM sales WHERE id = $1`
+	query := `DELETE FROM sales WHERE id = $1 AND ` + company
 
-	result, err := conn.Exec(query, id)
+	result, err := conn.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
+	"context"
 	"os"
 	"testing"
 
@@ -16,7 +19,7 @@ func TestMain(m *testing.M) {
 	if err := viper.ReadInConfig(); err != nil {
 		panic("Erro ao carregar .env: " + err.Error())
 	}
-	os.Exit(m.Run())
+	os.Exit(testutils.RunWithPostgres(m))
 }
 
 func TestCreateSale(t *testing.T) {
@@ -27,7 +30,7 @@ func TestCreateSale(t *testing.T) {
 		Customer: "cliente@teste.com",
 	}
 
-	created, err := CreateSale(s)
+	created, err := CreateSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), s)
 	if err != nil {
 		t.Fatalf("Erro ao criar venda: %v", err)
 	}
@@ -38,7 +41,7 @@ func TestCreateSale(t *testing.T) {
 }
 
 func TestGetAllSales(t *testing.T) {
-	sales, err := GetAllSales()
+	sales, err := GetAllSales(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao buscar vendas: %v", err)
 	}
@@ -53,12 +56,12 @@ func TestGetSaleByID(t *testing.T) {
 		Customer: "getbyid@teste.com",
 	}
 
-	created, err := CreateSale(s)
+	created, err := CreateSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), s)
 	if err != nil {
 		t.Fatalf("Erro ao criar venda para teste: %v", err)
 	}
 
-	retrieved, err := GetSaleByID(created.ID)
+	retrieved, err := GetSaleByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Fatalf("Erro ao buscar venda por ID: %v", err)
 	}
@@ -80,12 +83,12 @@ func TestGetSaleByID(t *testing.T) {
 	}
 
 	nonExistingID := 99999
-	_, err = GetSaleByID(nonExistingID)
+	_, err = GetSaleByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), nonExistingID)
 	if err == nil {
 		t.Error("Esperava erro ao buscar venda inexistente, mas não ocorreu")
 	}
 
-	err = DeleteSale(created.ID)
+	err = DeleteSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Logf("Aviso: Não foi possível limpar a venda de teste: %v", err)
 	}
@@ -98,7 +101,7 @@ func TestUpdateSale(t *testing.T) {
 		Price:    10.0,
 		Customer: "antigo@cliente.com",
 	}
-	created, err := CreateSale(s)
+	created, err := CreateSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), s)
 	if err != nil {
 		t.Fatalf("Erro ao criar venda para update: %v", err)
 	}
@@ -109,7 +112,7 @@ func TestUpdateSale(t *testing.T) {
 		Price:    55.0,
 		Customer: "novo@cliente.com",
 	}
-	result, err := UpdateSale(created.ID, updated)
+	result, err := UpdateSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID, updated)
 	if err != nil {
 		t.Fatalf("Erro ao atualizar venda: %v", err)
 	}
@@ -126,18 +129,18 @@ func TestDeleteSale(t *testing.T) {
 		Price:    20.0,
 		Customer: "deletar@cliente.com",
 	}
-	created, err := CreateSale(s)
+	created, err := CreateSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), s)
 	if err != nil {
 		t.Fatalf("Erro ao criar venda para deletar: %v", err)
 	}
 
-	err = DeleteSale(created.ID)
+	err = DeleteSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Errorf("Erro ao deletar venda: %v", err)
 	}
 
 	// Confirma se a venda foi removida tentando deletar novamente
-	err = DeleteSale(created.ID)
+	err = DeleteSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err == nil {
 		t.Error("Esperava erro ao deletar venda inexistente, mas não ocorreu")
 	}
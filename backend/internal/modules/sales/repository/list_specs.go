@@ -0,0 +1,53 @@
+package repository
+
+import "ERP-ONSMART/backend/internal/utils/listquery"
+
+// Campos aceitos em ?sort= e ?fields= nas listagens de documentos de venda
+
+// SalesOrderListSpec define a ordenação e a seleção de campos da listagem de pedidos de venda
+var SalesOrderListSpec = listquery.Spec{
+	Table:    "sales_orders",
+	Sortable: []string{"so_no", "status", "created_at", "updated_at", "expected_date", "subtotal", "grand_total"},
+	Columns: []string{"so_no", "quotation_id", "contact_id", "salesperson_id", "status", "created_at", "updated_at",
+		"expected_date", "subtotal", "tax_total", "discount_total", "grand_total", "notes", "payment_terms", "shipping_address",
+		"custom_fields"},
+	Relations: map[string]listquery.Relation{
+		"contact":   {Preload: "Contact", ForeignKey: "contact_id"},
+		"quotation": {Preload: "Quotation", ForeignKey: "quotation_id"},
+		"items":     {Preload: "Items"},
+	},
+	Preloads:    []string{"Contact", "Items"},
+	DefaultSort: "created_at DESC",
+}
+
+// InvoiceListSpec define a ordenação e a seleção de campos da listagem de faturas
+var InvoiceListSpec = listquery.Spec{
+	Table:    "invoices",
+	Sortable: []string{"invoice_no", "status", "created_at", "updated_at", "issue_date", "due_date", "grand_total", "amount_paid"},
+	Columns: []string{"invoice_no", "sales_order_id", "so_no", "contact_id", "salesperson_id", "status", "created_at", "updated_at",
+		"issue_date", "due_date", "subtotal", "tax_total", "discount_total", "grand_total", "amount_paid", "payment_terms", "notes"},
+	Relations: map[string]listquery.Relation{
+		"contact":     {Preload: "Contact", ForeignKey: "contact_id"},
+		"sales_order": {Preload: "SalesOrder", ForeignKey: "sales_order_id"},
+		"items":       {Preload: "Items"},
+		"payments":    {Preload: "Payments"},
+	},
+	Preloads:    []string{"Contact", "Items"},
+	DefaultSort: "created_at DESC",
+}
+
+// DeliveryListSpec define a ordenação e a seleção de campos da listagem de entregas
+var DeliveryListSpec = listquery.Spec{
+	Table:    "deliveries",
+	Sortable: []string{"delivery_no", "status", "created_at", "updated_at", "delivery_date", "received_date", "carrier", "sla_due_at"},
+	Columns: []string{"delivery_no", "purchase_order_id", "po_no", "sales_order_id", "so_no", "status", "created_at", "updated_at",
+		"delivery_date", "received_date", "shipping_method", "carrier", "tracking_number", "shipping_address", "notes",
+		"shipped_at", "sla_status", "sla_due_at"},
+	Relations: map[string]listquery.Relation{
+		"purchase_order": {Preload: "PurchaseOrder", ForeignKey: "purchase_order_id"},
+		"sales_order":    {Preload: "SalesOrder", ForeignKey: "sales_order_id"},
+		"items":          {Preload: "Items"},
+	},
+	Preloads:    []string{"PurchaseOrder", "SalesOrder", "Items"},
+	DefaultSort: "created_at DESC",
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentAllocationRepository consulta e ajusta a distribuição dos pagamentos entre as faturas
+type PaymentAllocationRepository interface {
+	GetPaymentAllocations(ctx context.Context, paymentID int) ([]models.PaymentAllocation, error)
+	GetInvoiceAllocations(ctx context.Context, invoiceID int) ([]models.PaymentAllocation, error)
+	ReplacePaymentAllocations(ctx context.Context, paymentID int, allocations []models.PaymentAllocation) ([]models.PaymentAllocation, error)
+}
+
+type paymentAllocationRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewPaymentAllocationRepository cria uma nova instância do repositório
+func NewPaymentAllocationRepository() (PaymentAllocationRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &paymentAllocationRepository{
+		db:     gormDB,
+		logger: logger.WithModule("payment_allocation_repository"),
+	}, nil
+}
+
+// GetPaymentAllocations lista as faturas que o pagamento quita e quanto vai para cada uma
+func (r *paymentAllocationRepository) GetPaymentAllocations(ctx context.Context, paymentID int) ([]models.PaymentAllocation, error) {
+	conn := db.Conn(ctx, r.db)
+	if err := conn.Select("id").First(&models.Payment{}, paymentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrPaymentNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar payment")
+	}
+
+	var allocations []models.PaymentAllocation
+	if err := conn.Preload("Invoice").Where("payment_id = ?", paymentID).Order("id").Find(&allocations).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar alocações do payment")
+	}
+	return allocations, nil
+}
+
+// GetInvoiceAllocations lista os pagamentos alocados na fatura
+func (r *paymentAllocationRepository) GetInvoiceAllocations(ctx context.Context, invoiceID int) ([]models.PaymentAllocation, error) {
+	conn := db.Conn(ctx, r.db)
+	if err := conn.Select("id").First(&models.Invoice{}, invoiceID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrInvoiceNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar invoice")
+	}
+
+	var allocations []models.PaymentAllocation
+	if err := conn.Where("invoice_id = ?", invoiceID).Order("id").Find(&allocations).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar alocações da invoice")
+	}
+	return allocations, nil
+}
+
+// ReplacePaymentAllocations troca a distribuição do pagamento pelas alocações informadas e
+// recalcula o valor pago e o status das faturas que ganharam ou perderam alocação
+func (r *paymentAllocationRepository) ReplacePaymentAllocations(ctx context.Context, paymentID int, allocations []models.PaymentAllocation) ([]models.PaymentAllocation, error) {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, paymentID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.ErrPaymentNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar payment")
+		}
+
+		var primary models.Invoice
+		if err := tx.Select("id", "contact_id").First(&primary, payment.InvoiceID).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar invoice do payment")
+		}
+		return replaceAllocations(tx, &payment, primary.ContactID, allocations)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("alocações do payment atualizadas", zap.Int("payment_id", paymentID), zap.Int("allocations", len(allocations)))
+	return allocations, nil
+}
+
+// replaceAllocations confere e grava as alocações do pagamento no lugar das atuais e recalcula
+// as faturas afetadas. As faturas alocadas ficam bloqueadas até o fim da transação.
+func replaceAllocations(tx *gorm.DB, payment *models.Payment, contactID int, allocations []models.PaymentAllocation) error {
+	var previous []models.PaymentAllocation
+	if err := tx.Where("payment_id = ?", payment.ID).Find(&previous).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar alocações do payment")
+	}
+
+	ids := make([]int, 0, len(allocations))
+	for _, allocation := range allocations {
+		ids = append(ids, allocation.InvoiceID)
+	}
+	check := models.AllocationCheck{
+		PaymentAmount:      payment.Amount,
+		ContactID:          contactID,
+		Invoices:           make(map[int]*models.Invoice, len(ids)),
+		AllocatedElsewhere: make(map[int]money.Decimal, len(ids)),
+	}
+	if len(ids) > 0 {
+		var invoices []models.Invoice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Find(&invoices).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar invoices da alocação")
+		}
+		for i := range invoices {
+			check.Invoices[invoices[i].ID] = &invoices[i]
+		}
+
+		var others []struct {
+			InvoiceID int
+			Amount    money.Decimal
+		}
+		if err := tx.Model(&models.PaymentAllocation{}).
+			Select("invoice_id, COALESCE(SUM(amount), 0) AS amount").
+			Where("invoice_id IN ? AND payment_id <> ?", ids, payment.ID).
+			Group("invoice_id").
+			Scan(&others).Error; err != nil {
+			return errors.WrapError(err, "falha ao somar alocações das invoices")
+		}
+		for _, other := range others {
+			check.AllocatedElsewhere[other.InvoiceID] = other.Amount
+		}
+	}
+	if err := models.ValidateAllocations(allocations, check); err != nil {
+		return err
+	}
+
+	if len(previous) > 0 {
+		if err := tx.Where("payment_id = ?", payment.ID).Delete(&models.PaymentAllocation{}).Error; err != nil {
+			return errors.WrapError(err, "falha ao remover alocações do payment")
+		}
+	}
+	for i := range allocations {
+		allocations[i].ID = 0
+		allocations[i].PaymentID = payment.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&allocations).Error; err != nil {
+		return errors.WrapError(err, "falha ao gravar alocações do payment")
+	}
+	payment.Allocations = allocations
+
+	for _, allocation := range previous {
+		ids = append(ids, allocation.InvoiceID)
+	}
+	return recomputeInvoicesPaid(tx, ids)
+}
+
+// removeAllocations apaga as alocações do pagamento e recalcula as faturas que as recebiam
+func removeAllocations(tx *gorm.DB, paymentID int) error {
+	var previous []models.PaymentAllocation
+	if err := tx.Where("payment_id = ?", paymentID).Find(&previous).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar alocações do payment")
+	}
+	if len(previous) == 0 {
+		return nil
+	}
+	if err := tx.Where("payment_id = ?", paymentID).Delete(&models.PaymentAllocation{}).Error; err != nil {
+		return errors.WrapError(err, "falha ao remover alocações do payment")
+	}
+
+	ids := make([]int, 0, len(previous))
+	for _, allocation := range previous {
+		ids = append(ids, allocation.InvoiceID)
+	}
+	return recomputeInvoicesPaid(tx, ids)
+}
+
+// recomputeInvoicesPaid grava em cada fatura o valor pago (a soma das alocações) e o status
+// correspondente
+func recomputeInvoicesPaid(tx *gorm.DB, invoiceIDs []int) error {
+	done := make(map[int]bool, len(invoiceIDs))
+	for _, id := range invoiceIDs {
+		if done[id] {
+			continue
+		}
+		done[id] = true
+
+		var invoice models.Invoice
+		if err := tx.Select("id", "status", "grand_total").First(&invoice, id).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar invoice")
+		}
+		var sum struct{ Amount money.Decimal }
+		if err := tx.Model(&models.PaymentAllocation{}).
+			Select("COALESCE(SUM(amount), 0) AS amount").
+			Where("invoice_id = ?", id).
+			Scan(&sum).Error; err != nil {
+			return errors.WrapError(err, "falha ao somar alocações da invoice")
+		}
+		paid := sum.Amount
+
+		status, err := applyInstallmentPayments(tx, &invoice, paid)
+		if err != nil {
+			return err
+		}
+		updateData := map[string]interface{}{
+			"amount_paid": paid,
+			"status":      status,
+		}
+		if err := tx.Model(&models.Invoice{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
+			return errors.WrapError(err, "falha ao atualizar invoice")
+		}
+	}
+	return nil
+}
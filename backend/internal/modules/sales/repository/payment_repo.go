@@ -1,36 +1,41 @@
 package repository
 
 import (
+	"context"
+
 	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
 	"ERP-ONSMART/backend/internal/logger"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
 	"ERP-ONSMART/backend/internal/utils/pagination"
 	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PaymentRepository define as operações do repositório de payments
 type PaymentRepository interface {
-	CreatePayment(payment *models.Payment) error
-	GetPaymentByID(id int) (*models.Payment, error)
-	GetAllPayments(params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	UpdatePayment(id int, payment *models.Payment) error
-	DeletePayment(id int) error
-	GetPaymentsByInvoice(invoiceID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetPaymentsByPeriod(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetPaymentsByMethod(method string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	SearchPayments(filter PaymentFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetPaymentStats(filter PaymentFilter) (*PaymentStats, error)
-	GetPaymentMethodStats(startDate, endDate time.Time) (*PaymentMethodStats, error)
-	GetDailyPaymentSummary(date time.Time) (*DailyPaymentSummary, error)
-	GetMonthlyPaymentSummary(year int, month int) (*MonthlyPaymentSummary, error)
-	GetPendingReconciliations(params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	ReconcilePayment(paymentID int, reference string) error
-	ProcessInvoicePayment(invoiceID int, amount float64, method string, reference string) error
-	GetPaymentHistory(invoiceID int) ([]models.Payment, error)
+	CreatePayment(ctx context.Context, payment *models.Payment) error
+	GetPaymentByID(ctx context.Context, id int) (*models.Payment, error)
+	GetAllPayments(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	UpdatePayment(ctx context.Context, id int, payment *models.Payment) error
+	DeletePayment(ctx context.Context, id int) error
+	GetPaymentsByInvoice(ctx context.Context, invoiceID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetPaymentsByPeriod(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetPaymentsByMethod(ctx context.Context, method string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	SearchPayments(ctx context.Context, filter PaymentFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetPaymentStats(ctx context.Context, filter PaymentFilter) (*PaymentStats, error)
+	GetPaymentMethodStats(ctx context.Context, startDate, endDate time.Time) (*PaymentMethodStats, error)
+	GetDailyPaymentSummary(ctx context.Context, date time.Time) (*DailyPaymentSummary, error)
+	GetMonthlyPaymentSummary(ctx context.Context, year int, month int) (*MonthlyPaymentSummary, error)
+	GetPendingReconciliations(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	ReconcilePayment(ctx context.Context, paymentID int, reference string) error
+	ProcessInvoicePayment(ctx context.Context, invoiceID int, amount money.Decimal, method string, reference string) error
+	GetPaymentHistory(ctx context.Context, invoiceID int) ([]models.Payment, error)
 }
 
 // PaymentFilter define os filtros para busca avançada
@@ -112,27 +117,29 @@ type PaymentComparisonStats struct {
 
 type paymentRepository struct {
 	db     *gorm.DB
+	reader *gorm.DB // réplica de leitura para as consultas analíticas (ver db.Provider)
 	logger *zap.Logger
 }
 
 // NewPaymentRepository cria uma nova instância do repositório
 func NewPaymentRepository() (PaymentRepository, error) {
-	db, err := db.OpenGormDB()
+	provider, err := db.OpenProvider()
 	if err != nil {
 		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
 	}
 
 	return &paymentRepository{
-		db:     db,
+		db:     provider.Writer(),
+		reader: provider.Reader(),
 		logger: logger.WithModule("payment_repository"),
 	}, nil
 }
 
 // CreatePayment cria um novo payment no banco
-func (r *paymentRepository) CreatePayment(payment *models.Payment) error {
+func (r *paymentRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
 	// Valida se a invoice existe
 	var invoice models.Invoice
-	if err := r.db.First(&invoice, payment.InvoiceID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&invoice, payment.InvoiceID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrInvoiceNotFound
 		}
@@ -140,32 +147,28 @@ func (r *paymentRepository) CreatePayment(payment *models.Payment) error {
 	}
 
 	// Inicia transação
-	tx := r.db.Begin()
+	tx := db.Conn(ctx, r.db).Begin()
 
 	// Cria o payment
-	if err := tx.Create(payment).Error; err != nil {
+	allocations := payment.DefaultAllocations(invoice.GrandTotal.Sub(invoice.AmountPaid))
+	if err := tx.Omit(clause.Associations).Create(payment).Error; err != nil {
 		tx.Rollback()
 		r.logger.Error("erro ao criar payment", zap.Error(err))
 		return errors.WrapError(err, "falha ao criar payment")
 	}
 
-	// Atualiza o valor pago na invoice
-	totalPaid := invoice.AmountPaid + payment.Amount
-	updateData := map[string]interface{}{
-		"amount_paid": totalPaid,
-	}
-
-	// Atualiza o status da invoice se necessário
-	if totalPaid >= invoice.GrandTotal {
-		updateData["status"] = models.InvoiceStatusPaid
-	} else if totalPaid > 0 {
-		updateData["status"] = models.InvoiceStatusPartial
+	// Distribui o pagamento entre as faturas e recalcula o valor pago de cada uma
+	if err := replaceAllocations(tx, payment, invoice.ContactID, allocations); err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao alocar payment", zap.Error(err))
+		return err
 	}
 
-	if err := tx.Model(&models.Invoice{}).Where("id = ?", payment.InvoiceID).Updates(updateData).Error; err != nil {
+	// Registra o recebimento na linha do tempo dos processos da fatura
+	if err := recordInvoiceEvent(tx, payment.InvoiceID, models.PaymentEvent(payment)); err != nil {
 		tx.Rollback()
-		r.logger.Error("erro ao atualizar invoice", zap.Error(err))
-		return errors.WrapError(err, "falha ao atualizar invoice")
+		r.logger.Error("erro ao registrar payment no processo", zap.Error(err))
+		return err
 	}
 
 	// Commit da transação
@@ -174,15 +177,15 @@ func (r *paymentRepository) CreatePayment(payment *models.Payment) error {
 		return errors.WrapError(err, "falha ao confirmar transação")
 	}
 
-	r.logger.Info("payment criado com sucesso", zap.Int("id", payment.ID), zap.Float64("amount", payment.Amount))
+	r.logger.Info("payment criado com sucesso", zap.Int("id", payment.ID), zap.Stringer("amount", payment.Amount))
 	return nil
 }
 
 // GetPaymentByID busca um payment pelo ID
-func (r *paymentRepository) GetPaymentByID(id int) (*models.Payment, error) {
+func (r *paymentRepository) GetPaymentByID(ctx context.Context, id int) (*models.Payment, error) {
 	var payment models.Payment
 
-	query := r.db.Preload("Invoice").
+	query := db.Conn(ctx, r.db).Preload("Invoice").
 		Preload("Invoice.Contact")
 
 	if err := query.First(&payment, id).Error; err != nil {
@@ -197,12 +200,12 @@ func (r *paymentRepository) GetPaymentByID(id int) (*models.Payment, error) {
 }
 
 // GetAllPayments retorna todos os payments com paginação
-func (r *paymentRepository) GetAllPayments(params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *paymentRepository) GetAllPayments(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var payments []models.Payment
 	var total int64
 
 	// Query base
-	query := r.db.Model(&models.Payment{})
+	query := db.Conn(ctx, r.db).Model(&models.Payment{})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -226,10 +229,10 @@ func (r *paymentRepository) GetAllPayments(params *pagination.PaginationParams)
 }
 
 // UpdatePayment atualiza um payment existente
-func (r *paymentRepository) UpdatePayment(id int, payment *models.Payment) error {
+func (r *paymentRepository) UpdatePayment(ctx context.Context, id int, payment *models.Payment) error {
 	// Verifica se o payment existe
 	var existing models.Payment
-	if err := r.db.First(&existing, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrPaymentNotFound
 		}
@@ -238,43 +241,46 @@ func (r *paymentRepository) UpdatePayment(id int, payment *models.Payment) error
 
 	// Busca a invoice para atualizar o valor pago
 	var invoice models.Invoice
-	if err := r.db.First(&invoice, existing.InvoiceID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&invoice, existing.InvoiceID).Error; err != nil {
 		return errors.WrapError(err, "falha ao buscar invoice")
 	}
 
-	// Inicia transação
-	tx := r.db.Begin()
+	// Sem alocações no payload, um pagamento de uma fatura só continua nela; com várias, as
+	// alocações atuais são mantidas e precisam caber no novo valor
+	var current []models.PaymentAllocation
+	if err := db.Conn(ctx, r.db).Where("payment_id = ?", id).Find(&current).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar alocações do payment")
+	}
+	allocations := payment.Allocations
+	if len(allocations) == 0 {
+		if len(current) <= 1 {
+			openBalance := invoice.GrandTotal.Sub(invoice.AmountPaid)
+			if len(current) == 1 && current[0].InvoiceID == invoice.ID {
+				openBalance = openBalance.Add(current[0].Amount)
+			}
+			allocations = (&models.Payment{InvoiceID: existing.InvoiceID, Amount: payment.Amount}).DefaultAllocations(openBalance)
+		} else {
+			allocations = current
+		}
+	}
 
-	// Calcula a diferença do valor
-	diff := payment.Amount - existing.Amount
-	newAmountPaid := invoice.AmountPaid + diff
+	// Inicia transação
+	tx := db.Conn(ctx, r.db).Begin()
 
 	// Atualiza o payment
 	payment.ID = id
-	if err := tx.Save(payment).Error; err != nil {
+	payment.InvoiceID = existing.InvoiceID
+	if err := tx.Omit(clause.Associations).Save(payment).Error; err != nil {
 		tx.Rollback()
 		r.logger.Error("erro ao atualizar payment", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao atualizar payment")
 	}
 
-	// Atualiza a invoice
-	updateData := map[string]interface{}{
-		"amount_paid": newAmountPaid,
-	}
-
-	// Atualiza o status da invoice se necessário
-	if newAmountPaid >= invoice.GrandTotal {
-		updateData["status"] = models.InvoiceStatusPaid
-	} else if newAmountPaid > 0 {
-		updateData["status"] = models.InvoiceStatusPartial
-	} else {
-		updateData["status"] = models.InvoiceStatusSent
-	}
-
-	if err := tx.Model(&models.Invoice{}).Where("id = ?", existing.InvoiceID).Updates(updateData).Error; err != nil {
+	// Redistribui o pagamento e recalcula o valor pago das faturas
+	if err := replaceAllocations(tx, payment, invoice.ContactID, allocations); err != nil {
 		tx.Rollback()
-		r.logger.Error("erro ao atualizar invoice", zap.Error(err))
-		return errors.WrapError(err, "falha ao atualizar invoice")
+		r.logger.Error("erro ao alocar payment", zap.Error(err), zap.Int("id", id))
+		return err
 	}
 
 	// Commit da transação
@@ -288,53 +294,31 @@ func (r *paymentRepository) UpdatePayment(id int, payment *models.Payment) error
 }
 
 // DeletePayment remove um payment
-func (r *paymentRepository) DeletePayment(id int) error {
+func (r *paymentRepository) DeletePayment(ctx context.Context, id int) error {
 	// Busca o payment
 	var payment models.Payment
-	if err := r.db.First(&payment, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&payment, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrPaymentNotFound
 		}
 		return errors.WrapError(err, "falha ao buscar payment")
 	}
 
-	// Busca a invoice para atualizar o valor pago
-	var invoice models.Invoice
-	if err := r.db.First(&invoice, payment.InvoiceID).Error; err != nil {
-		return errors.WrapError(err, "falha ao buscar invoice")
-	}
-
 	// Inicia transação
-	tx := r.db.Begin()
+	tx := db.Conn(ctx, r.db).Begin()
 
-	// Remove o payment
+	// Desfaz as alocações, recalculando o valor pago das faturas, e remove o payment
+	if err := removeAllocations(tx, id); err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao remover alocações do payment", zap.Error(err), zap.Int("id", id))
+		return err
+	}
 	if err := tx.Delete(&payment).Error; err != nil {
 		tx.Rollback()
 		r.logger.Error("erro ao deletar payment", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao deletar payment")
 	}
 
-	// Atualiza a invoice
-	newAmountPaid := invoice.AmountPaid - payment.Amount
-	updateData := map[string]interface{}{
-		"amount_paid": newAmountPaid,
-	}
-
-	// Atualiza o status da invoice se necessário
-	if newAmountPaid >= invoice.GrandTotal {
-		updateData["status"] = models.InvoiceStatusPaid
-	} else if newAmountPaid > 0 {
-		updateData["status"] = models.InvoiceStatusPartial
-	} else {
-		updateData["status"] = models.InvoiceStatusSent
-	}
-
-	if err := tx.Model(&models.Invoice{}).Where("id = ?", payment.InvoiceID).Updates(updateData).Error; err != nil {
-		tx.Rollback()
-		r.logger.Error("erro ao atualizar invoice", zap.Error(err))
-		return errors.WrapError(err, "falha ao atualizar invoice")
-	}
-
 	// Commit da transação
 	if err := tx.Commit().Error; err != nil {
 		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
@@ -346,11 +330,11 @@ func (r *paymentRepository) DeletePayment(id int) error {
 }
 
 // GetPaymentsByInvoice busca payments por invoice
-func (r *paymentRepository) GetPaymentsByInvoice(invoiceID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *paymentRepository) GetPaymentsByInvoice(ctx context.Context, invoiceID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var payments []models.Payment
 	var total int64
 
-	query := r.db.Model(&models.Payment{}).Where("invoice_id = ?", invoiceID)
+	query := db.Conn(ctx, r.db).Model(&models.Payment{}).Where("invoice_id = ?", invoiceID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -373,11 +357,11 @@ func (r *paymentRepository) GetPaymentsByInvoice(invoiceID int, params *paginati
 }
 
 // GetPaymentsByPeriod busca payments por período
-func (r *paymentRepository) GetPaymentsByPeriod(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *paymentRepository) GetPaymentsByPeriod(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var payments []models.Payment
 	var total int64
 
-	query := r.db.Model(&models.Payment{}).
+	query := db.Conn(ctx, r.db).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date <= ?", startDate, endDate)
 
 	// Conta o total
@@ -402,11 +386,11 @@ func (r *paymentRepository) GetPaymentsByPeriod(startDate, endDate time.Time, pa
 }
 
 // GetPaymentsByMethod busca payments por método de pagamento
-func (r *paymentRepository) GetPaymentsByMethod(method string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *paymentRepository) GetPaymentsByMethod(ctx context.Context, method string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var payments []models.Payment
 	var total int64
 
-	query := r.db.Model(&models.Payment{}).Where("payment_method = ?", method)
+	query := db.Conn(ctx, r.db).Model(&models.Payment{}).Where("payment_method = ?", method)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -430,11 +414,11 @@ func (r *paymentRepository) GetPaymentsByMethod(method string, params *paginatio
 }
 
 // SearchPayments busca payments com filtros combinados
-func (r *paymentRepository) SearchPayments(filter PaymentFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *paymentRepository) SearchPayments(ctx context.Context, filter PaymentFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var payments []models.Payment
 	var total int64
 
-	query := r.db.Model(&models.Payment{})
+	query := db.Conn(ctx, r.db).Model(&models.Payment{})
 
 	// Aplica os filtros
 	if filter.InvoiceID > 0 {
@@ -443,7 +427,7 @@ func (r *paymentRepository) SearchPayments(filter PaymentFilter, params *paginat
 
 	// Filtro por contato (através da invoice)
 	if filter.ContactID > 0 {
-		invoiceSubquery := r.db.Model(&models.Invoice{}).Select("id").Where("contact_id = ?", filter.ContactID)
+		invoiceSubquery := db.Conn(ctx, r.db).Model(&models.Invoice{}).Select("id").Where("contact_id = ?", filter.ContactID)
 		query = query.Where("invoice_id IN (?)", invoiceSubquery)
 	}
 
@@ -502,14 +486,15 @@ func (r *paymentRepository) SearchPayments(filter PaymentFilter, params *paginat
 	return result, nil
 }
 
-// GetPaymentStats retorna estatísticas de payments
-func (r *paymentRepository) GetPaymentStats(filter PaymentFilter) (*PaymentStats, error) {
+// GetPaymentStats retorna estatísticas de payments; hoje e o mês corrente seguem o fuso da
+// empresa
+func (r *paymentRepository) GetPaymentStats(ctx context.Context, filter PaymentFilter) (*PaymentStats, error) {
 	stats := &PaymentStats{
 		CountByMethod:  make(map[string]int),
 		AmountByMethod: make(map[string]float64),
 	}
 
-	query := r.db.Model(&models.Payment{})
+	query := r.reader.WithContext(ctx).Model(&models.Payment{})
 
 	// Aplica filtros básicos
 	if filter.InvoiceID > 0 {
@@ -557,14 +542,13 @@ func (r *paymentRepository) GetPaymentStats(filter PaymentFilter) (*PaymentStats
 	}
 
 	// Estatísticas de hoje
-	today := time.Now().Truncate(24 * time.Hour)
-	tomorrow := today.Add(24 * time.Hour)
+	today, tomorrow := localtime.DayBounds(ctx)
 
 	var todayStats struct {
 		Count int
 		Total float64
 	}
-	if err := r.db.Model(&models.Payment{}).
+	if err := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", today, tomorrow).
 		Select("COUNT(*) as count, COALESCE(SUM(amount), 0) as total").
 		Scan(&todayStats).Error; err != nil {
@@ -574,14 +558,17 @@ func (r *paymentRepository) GetPaymentStats(filter PaymentFilter) (*PaymentStats
 	stats.TodayAmount = todayStats.Total
 
 	// Estatísticas do mês
-	firstDay := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
-	lastDay := firstDay.AddDate(0, 1, 0)
+	loc := localtime.Location(ctx)
+	month := localtime.Today(ctx)
+	month = month.AddDate(0, 0, 1-month.Day())
+	firstDay := localtime.StartOfDay(month, loc)
+	lastDay := localtime.StartOfDay(month.AddDate(0, 1, 0), loc)
 
 	var monthStats struct {
 		Count int
 		Total float64
 	}
-	if err := r.db.Model(&models.Payment{}).
+	if err := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", firstDay, lastDay).
 		Select("COUNT(*) as count, COALESCE(SUM(amount), 0) as total").
 		Scan(&monthStats).Error; err != nil {
@@ -594,9 +581,9 @@ func (r *paymentRepository) GetPaymentStats(filter PaymentFilter) (*PaymentStats
 }
 
 // GetPaymentMethodStats retorna estatísticas por método de pagamento
-func (r *paymentRepository) GetPaymentMethodStats(startDate, endDate time.Time) (*PaymentMethodStats, error) {
+func (r *paymentRepository) GetPaymentMethodStats(ctx context.Context, startDate, endDate time.Time) (*PaymentMethodStats, error) {
 	// Query base com período
-	query := r.db.Model(&models.Payment{}).
+	query := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date <= ?", startDate, endDate)
 
 	// Total geral para calcular percentuais
@@ -643,7 +630,7 @@ func (r *paymentRepository) GetPaymentMethodStats(startDate, endDate time.Time)
 }
 
 // GetDailyPaymentSummary retorna resumo diário de pagamentos
-func (r *paymentRepository) GetDailyPaymentSummary(date time.Time) (*DailyPaymentSummary, error) {
+func (r *paymentRepository) GetDailyPaymentSummary(ctx context.Context, date time.Time) (*DailyPaymentSummary, error) {
 	startOfDay := date.Truncate(24 * time.Hour)
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
@@ -658,7 +645,7 @@ func (r *paymentRepository) GetDailyPaymentSummary(date time.Time) (*DailyPaymen
 		Count int
 		Total float64
 	}
-	if err := r.db.Model(&models.Payment{}).
+	if err := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", startOfDay, endOfDay).
 		Select("COUNT(*) as count, COALESCE(SUM(amount), 0) as total").
 		Scan(&dayTotal).Error; err != nil {
@@ -668,7 +655,7 @@ func (r *paymentRepository) GetDailyPaymentSummary(date time.Time) (*DailyPaymen
 	summary.TotalAmount = dayTotal.Total
 
 	// Por método de pagamento
-	methodQuery := r.db.Model(&models.Payment{}).
+	methodQuery := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", startOfDay, endOfDay)
 
 	rows, err := methodQuery.Select("payment_method, COUNT(*) as count, SUM(amount) as total_amount, AVG(amount) as average_amount").
@@ -695,7 +682,7 @@ func (r *paymentRepository) GetDailyPaymentSummary(date time.Time) (*DailyPaymen
 	}
 
 	// Por hora
-	hourRows, err := r.db.Model(&models.Payment{}).
+	hourRows, err := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", startOfDay, endOfDay).
 		Select("HOUR(payment_date) as hour, COUNT(*) as count, SUM(amount) as amount").
 		Group("HOUR(payment_date)").
@@ -718,10 +705,12 @@ func (r *paymentRepository) GetDailyPaymentSummary(date time.Time) (*DailyPaymen
 	return summary, nil
 }
 
-// GetMonthlyPaymentSummary retorna resumo mensal de pagamentos
-func (r *paymentRepository) GetMonthlyPaymentSummary(year int, month int) (*MonthlyPaymentSummary, error) {
-	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
-	lastDay := firstDay.AddDate(0, 1, 0)
+// GetMonthlyPaymentSummary retorna resumo mensal de pagamentos; o mês e os dias seguem o fuso
+// da empresa
+func (r *paymentRepository) GetMonthlyPaymentSummary(ctx context.Context, year int, month int) (*MonthlyPaymentSummary, error) {
+	loc := localtime.Location(ctx)
+	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc).UTC()
+	lastDay := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, loc).UTC()
 
 	summary := &MonthlyPaymentSummary{
 		Year:     year,
@@ -735,7 +724,7 @@ func (r *paymentRepository) GetMonthlyPaymentSummary(year int, month int) (*Mont
 		Count int
 		Total float64
 	}
-	if err := r.db.Model(&models.Payment{}).
+	if err := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", firstDay, lastDay).
 		Select("COUNT(*) as count, COALESCE(SUM(amount), 0) as total").
 		Scan(&monthTotal).Error; err != nil {
@@ -745,7 +734,7 @@ func (r *paymentRepository) GetMonthlyPaymentSummary(year int, month int) (*Mont
 	summary.TotalAmount = monthTotal.Total
 
 	// Por método de pagamento
-	methodQuery := r.db.Model(&models.Payment{}).
+	methodQuery := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", firstDay, lastDay)
 
 	rows, err := methodQuery.Select("payment_method, COUNT(*) as count, SUM(amount) as total_amount, AVG(amount) as average_amount").
@@ -772,10 +761,10 @@ func (r *paymentRepository) GetMonthlyPaymentSummary(year int, month int) (*Mont
 	}
 
 	// Por dia
-	dayRows, err := r.db.Model(&models.Payment{}).
+	dayRows, err := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", firstDay, lastDay).
-		Select("DAY(payment_date) as day, COUNT(*) as count, SUM(amount) as amount").
-		Group("DAY(payment_date)").
+		Select("EXTRACT(DAY FROM (payment_date AT TIME ZONE 'UTC') AT TIME ZONE ?)::int as day, COUNT(*) as count, SUM(amount) as amount", loc.String()).
+		Group("1").
 		Rows()
 	if err != nil {
 		r.logger.Warn("erro ao calcular estatísticas por dia", zap.Error(err))
@@ -793,14 +782,14 @@ func (r *paymentRepository) GetMonthlyPaymentSummary(year int, month int) (*Mont
 	}
 
 	// Comparação com mês anterior
-	prevFirstDay := firstDay.AddDate(0, -1, 0)
+	prevFirstDay := time.Date(year, time.Month(month)-1, 1, 0, 0, 0, 0, loc).UTC()
 	prevLastDay := firstDay
 
 	var prevMonthStats struct {
 		Count int
 		Total float64
 	}
-	if err := r.db.Model(&models.Payment{}).
+	if err := r.reader.WithContext(ctx).Model(&models.Payment{}).
 		Where("payment_date >= ? AND payment_date < ?", prevFirstDay, prevLastDay).
 		Select("COUNT(*) as count, COALESCE(SUM(amount), 0) as total").
 		Scan(&prevMonthStats).Error; err != nil {
@@ -822,12 +811,12 @@ func (r *paymentRepository) GetMonthlyPaymentSummary(year int, month int) (*Mont
 }
 
 // GetPendingReconciliations busca pagamentos pendentes de reconciliação
-func (r *paymentRepository) GetPendingReconciliations(params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *paymentRepository) GetPendingReconciliations(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var payments []models.Payment
 	var total int64
 
 	// Pagamentos sem referência
-	query := r.db.Model(&models.Payment{}).Where("reference IS NULL OR reference = ''")
+	query := db.Conn(ctx, r.db).Model(&models.Payment{}).Where("reference IS NULL OR reference = ''")
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -851,10 +840,10 @@ func (r *paymentRepository) GetPendingReconciliations(params *pagination.Paginat
 }
 
 // ReconcilePayment reconcilia um pagamento com uma referência
-func (r *paymentRepository) ReconcilePayment(paymentID int, reference string) error {
+func (r *paymentRepository) ReconcilePayment(ctx context.Context, paymentID int, reference string) error {
 	// Busca o payment
 	var payment models.Payment
-	if err := r.db.First(&payment, paymentID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&payment, paymentID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrPaymentNotFound
 		}
@@ -863,7 +852,7 @@ func (r *paymentRepository) ReconcilePayment(paymentID int, reference string) er
 
 	// Atualiza a referência
 	payment.Reference = reference
-	if err := r.db.Save(&payment).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&payment).Error; err != nil {
 		r.logger.Error("erro ao reconciliar payment", zap.Error(err), zap.Int("payment_id", paymentID))
 		return errors.WrapError(err, "falha ao reconciliar payment")
 	}
@@ -873,7 +862,7 @@ func (r *paymentRepository) ReconcilePayment(paymentID int, reference string) er
 }
 
 // ProcessInvoicePayment processa um pagamento para uma invoice
-func (r *paymentRepository) ProcessInvoicePayment(invoiceID int, amount float64, method string, reference string) error {
+func (r *paymentRepository) ProcessInvoicePayment(ctx context.Context, invoiceID int, amount money.Decimal, method string, reference string) error {
 	payment := &models.Payment{
 		InvoiceID:     invoiceID,
 		Amount:        amount,
@@ -882,14 +871,14 @@ func (r *paymentRepository) ProcessInvoicePayment(invoiceID int, amount float64,
 		PaymentDate:   time.Now(),
 	}
 
-	return r.CreatePayment(payment)
+	return r.CreatePayment(ctx, payment)
 }
 
 // GetPaymentHistory retorna o histórico de pagamentos de uma invoice
-func (r *paymentRepository) GetPaymentHistory(invoiceID int) ([]models.Payment, error) {
+func (r *paymentRepository) GetPaymentHistory(ctx context.Context, invoiceID int) ([]models.Payment, error) {
 	var payments []models.Payment
 
-	if err := r.db.Where("invoice_id = ?", invoiceID).
+	if err := db.Conn(ctx, r.db).Where("invoice_id = ?", invoiceID).
 		Order("payment_date DESC").
 		Find(&payments).Error; err != nil {
 		r.logger.Error("erro ao buscar histórico de pagamentos", zap.Error(err), zap.Int("invoice_id", invoiceID))
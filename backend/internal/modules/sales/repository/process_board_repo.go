@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OpenProcessStages são as etapas dos processos em andamento, na ordem das colunas do quadro
+var OpenProcessStages = []string{
+	ProcessStatusDraft,
+	ProcessStatusQuotation,
+	ProcessStatusSalesOrder,
+	ProcessStatusPurchase,
+	ProcessStatusDelivery,
+	ProcessStatusInvoicing,
+	ProcessStatusPayment,
+}
+
+// BoardStages retorna as colunas do quadro; com includeClosed, as dos concluídos e cancelados
+// vêm ao final
+func BoardStages(includeClosed bool) []string {
+	stages := append([]string(nil), OpenProcessStages...)
+	if includeClosed {
+		stages = append(stages, ProcessStatusCompleted, ProcessStatusCancelled)
+	}
+	return stages
+}
+
+// ValidateProcessMove confere a movimentação do processo no quadro: na mesma etapa só muda a
+// posição; o processo em andamento avança para qualquer etapa seguinte, só é concluído a partir
+// do pagamento e pode ser cancelado em qualquer etapa. Não volta etapas (elas acompanham os
+// documentos emitidos), e os processos concluídos e cancelados não mudam de etapa.
+func ValidateProcessMove(from, to string) error {
+	if _, ok := processStageOrder[to]; !ok && to != ProcessStatusCancelled {
+		return fmt.Errorf("%w: etapa %q desconhecida", errors.ErrInvalidProcessMove, to)
+	}
+	switch {
+	case from == to:
+		return nil
+	case from == ProcessStatusCompleted || from == ProcessStatusCancelled:
+		return fmt.Errorf("%w: o processo encerrado não muda de etapa", errors.ErrInvalidProcessMove)
+	case to == ProcessStatusCancelled:
+		return nil
+	case to == ProcessStatusCompleted && from != ProcessStatusPayment:
+		return fmt.Errorf("%w: o processo só é concluído a partir da etapa de pagamento", errors.ErrInvalidProcessMove)
+	case processStageOrder[to] < processStageOrder[from]:
+		return fmt.Errorf("%w: o processo não volta para uma etapa anterior", errors.ErrInvalidProcessMove)
+	}
+	return nil
+}
+
+// ProcessStatuses é o fluxo das etapas dos processos de venda, com as transições permitidas por
+// ValidateProcessMove
+func ProcessStatuses() models.StatusMachine {
+	stages := BoardStages(true)
+	machine := models.StatusMachine{Statuses: stages, Transitions: make(map[string][]string, len(stages))}
+	for _, from := range stages {
+		for _, to := range stages {
+			if from != to && ValidateProcessMove(from, to) == nil {
+				machine.Transitions[from] = append(machine.Transitions[from], to)
+			}
+		}
+	}
+	return machine
+}
+
+// ProcessBoardRepository lê o quadro dos processos de venda por etapa e move os processos entre
+// as colunas
+type ProcessBoardRepository interface {
+	Board(ctx context.Context, stages []string, filter models.ProcessBoardFilter) ([]models.BoardColumnTotal, []models.BoardCard, error)
+	MoveProcess(ctx context.Context, id int, input models.MoveProcessInput, now time.Time) (*models.BoardCard, error)
+}
+
+type processBoardRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewProcessBoardRepository cria uma nova instância do repositório
+func NewProcessBoardRepository() (ProcessBoardRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &processBoardRepository{
+		db:     gormDB,
+		logger: logger.WithModule("process_board_repository"),
+	}, nil
+}
+
+// Board retorna a quantidade e o valor dos processos de cada etapa e os primeiros cartões de
+// cada coluna, pela posição (os processos que chegaram à etapa sem posição ficam no topo)
+func (r *processBoardRepository) Board(ctx context.Context, stages []string, filter models.ProcessBoardFilter) ([]models.BoardColumnTotal, []models.BoardCard, error) {
+	scoped := func() *gorm.DB {
+		query := db.Conn(ctx, r.db).Table("sales_processes p").Scopes(tenant.Scope(ctx, "p")).
+			Where("p.status IN ?", stages)
+		if filter.ContactID > 0 {
+			query = query.Where("p.contact_id = ?", filter.ContactID)
+		}
+		return query
+	}
+
+	var totals []models.BoardColumnTotal
+	err := scoped().Select("p.status, COUNT(*) AS count, COALESCE(SUM(p.total_value), 0) AS total_value").
+		Group("p.status").Scan(&totals).Error
+	if err != nil {
+		r.logger.Error("erro ao totalizar o quadro de processos", zap.Error(err))
+		return nil, nil, errors.WrapError(err, "falha ao totalizar o quadro de processos")
+	}
+
+	ranked := scoped().
+		Select(`p.id, p.status, p.contact_id, c.name AS contact_name, p.total_value, p.board_position, p.sla_status,
+			p.updated_at, ROW_NUMBER() OVER (PARTITION BY p.status ORDER BY p.board_position ASC, p.id ASC) AS board_rank`).
+		Joins("LEFT JOIN contacts c ON c.id = p.contact_id")
+	var cards []models.BoardCard
+	err = db.Conn(ctx, r.db).Table("(?) AS b", ranked).Where("b.board_rank <= ?", filter.Limit).
+		Order("b.status ASC, b.board_rank ASC").Scan(&cards).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar o quadro de processos", zap.Error(err))
+		return nil, nil, errors.WrapError(err, "falha ao buscar o quadro de processos")
+	}
+	return totals, cards, nil
+}
+
+// MoveProcess move o processo para a etapa e a posição informadas e renumera a coluna de
+// destino (1, 2, 3...), na mesma transação e com as linhas da coluna travadas, para que as
+// posições fiquem estáveis entre movimentações simultâneas
+func (r *processBoardRepository) MoveProcess(ctx context.Context, id int, input models.MoveProcessInput, now time.Time) (*models.BoardCard, error) {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var process models.SalesProcess
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&process, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrSalesProcessNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar processo de venda")
+		}
+		if err := ValidateProcessMove(process.Status, input.Stage); err != nil {
+			return err
+		}
+
+		var column []int
+		err := tx.Model(&models.SalesProcess{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND id <> ?", input.Stage, id).
+			Order("board_position ASC, id ASC").Pluck("id", &column).Error
+		if err != nil {
+			return errors.WrapError(err, "falha ao buscar a coluna do quadro")
+		}
+		index := len(column)
+		if input.Position != nil && *input.Position-1 < index {
+			index = *input.Position - 1
+		}
+		ordered := make([]int64, 0, len(column)+1)
+		for _, other := range column[:index] {
+			ordered = append(ordered, int64(other))
+		}
+		ordered = append(ordered, int64(id))
+		for _, other := range column[index:] {
+			ordered = append(ordered, int64(other))
+		}
+
+		if previous := process.Status; previous != input.Stage {
+			err := tx.Model(&process).UpdateColumns(map[string]interface{}{"status": input.Stage, "updated_at": now}).Error
+			if err != nil {
+				r.logger.Error("erro ao mover processo de venda", zap.Error(err), zap.Int("id", id))
+				return errors.WrapError(err, "falha ao mover processo de venda")
+			}
+			if err := RecordProcessEvent(tx, id, StatusChangedEvent(previous, input.Stage, now)); err != nil {
+				return err
+			}
+		}
+		err = tx.Exec(`UPDATE sales_processes p SET board_position = v.position
+			FROM unnest(?::int[]) WITH ORDINALITY AS v(id, position)
+			WHERE p.id = v.id AND p.board_position <> v.position`, pq.Array(ordered)).Error
+		if err != nil {
+			r.logger.Error("erro ao reordenar coluna do quadro", zap.Error(err), zap.String("stage", input.Stage))
+			return errors.WrapError(err, "falha ao reordenar coluna do quadro")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var card models.BoardCard
+	err = db.Conn(ctx, r.db).Table("sales_processes p").Scopes(tenant.Scope(ctx, "p")).
+		Select("p.id, p.status, p.contact_id, c.name AS contact_name, p.total_value, p.board_position, p.sla_status, p.updated_at").
+		Joins("LEFT JOIN contacts c ON c.id = p.contact_id").
+		Where("p.id = ?", id).Scan(&card).Error
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar processo de venda")
+	}
+	return &card, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"testing"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateProcessMove(t *testing.T) {
+	allowed := [][2]string{
+		{ProcessStatusQuotation, ProcessStatusQuotation},
+		{ProcessStatusCompleted, ProcessStatusCompleted},
+		{ProcessStatusDraft, ProcessStatusQuotation},
+		{ProcessStatusQuotation, ProcessStatusDelivery},
+		{ProcessStatusSalesOrder, ProcessStatusCancelled},
+		{ProcessStatusPayment, ProcessStatusCompleted},
+	}
+	for _, move := range allowed {
+		assert.NoError(t, ValidateProcessMove(move[0], move[1]), "%s -> %s", move[0], move[1])
+	}
+
+	rejected := [][2]string{
+		{ProcessStatusDelivery, ProcessStatusQuotation},
+		{ProcessStatusInvoicing, ProcessStatusCompleted},
+		{ProcessStatusCancelled, ProcessStatusDraft},
+		{ProcessStatusCompleted, ProcessStatusCancelled},
+		{ProcessStatusDraft, "archived"},
+	}
+	for _, move := range rejected {
+		assert.ErrorIs(t, ValidateProcessMove(move[0], move[1]), errors.ErrInvalidProcessMove, "%s -> %s", move[0], move[1])
+	}
+}
+
+func TestBoardStages(t *testing.T) {
+	assert.Equal(t, OpenProcessStages, BoardStages(false))
+	stages := BoardStages(true)
+	assert.Equal(t, []string{ProcessStatusCompleted, ProcessStatusCancelled}, stages[len(stages)-2:])
+	assert.Len(t, OpenProcessStages, 7, "as colunas abertas não são alteradas")
+}
+
+func TestProcessStatusesFollowsValidateProcessMove(t *testing.T) {
+	machine := ProcessStatuses()
+	assert.Equal(t, BoardStages(true), machine.Statuses)
+	assert.Equal(t, []string{ProcessStatusCompleted, ProcessStatusCancelled}, machine.Transitions[ProcessStatusPayment])
+	assert.True(t, machine.CanTransition(ProcessStatusDraft, ProcessStatusDelivery))
+	assert.False(t, machine.CanTransition(ProcessStatusInvoicing, ProcessStatusCompleted))
+	assert.True(t, machine.IsFinal(ProcessStatusCompleted))
+	assert.True(t, machine.IsFinal(ProcessStatusCancelled))
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProcessTimelineRepository lê a linha do tempo gravada dos processos de venda e registra os
+// eventos lançados pelos usuários
+type ProcessTimelineRepository interface {
+	GetProcess(ctx context.Context, id int) (*models.SalesProcess, error)
+	ListEvents(ctx context.Context, processID int, newestFirst bool, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	CreateEvent(ctx context.Context, event *models.ProcessEvent) error
+}
+
+type processTimelineRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewProcessTimelineRepository cria uma nova instância do repositório
+func NewProcessTimelineRepository() (ProcessTimelineRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &processTimelineRepository{
+		db:     gormDB,
+		logger: logger.WithModule("process_timeline_repository"),
+	}, nil
+}
+
+// GetProcess busca o processo de venda da empresa
+func (r *processTimelineRepository) GetProcess(ctx context.Context, id int) (*models.SalesProcess, error) {
+	var process models.SalesProcess
+	if err := db.Conn(ctx, r.db).First(&process, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrSalesProcessNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar processo de venda")
+	}
+	return &process, nil
+}
+
+// ListEvents lista uma página da linha do tempo do processo em ordem cronológica (ou do mais
+// recente ao mais antigo)
+func (r *processTimelineRepository) ListEvents(ctx context.Context, processID int, newestFirst bool, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := db.Conn(ctx, r.db).Model(&models.ProcessEvent{}).Where("process_id = ?", processID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar eventos do processo", zap.Error(err), zap.Int("process_id", processID))
+		return nil, errors.WrapError(err, "falha ao contar eventos do processo")
+	}
+
+	order := "occurred_at ASC, id ASC"
+	if newestFirst {
+		order = "occurred_at DESC, id DESC"
+	}
+	events := make([]models.ProcessEvent, 0)
+	if err := query.Order(order).
+		Limit(params.PageSize).
+		Offset(pagination.CalculateOffset(params.Page, params.PageSize)).
+		Find(&events).Error; err != nil {
+		r.logger.Error("erro ao listar eventos do processo", zap.Error(err), zap.Int("process_id", processID))
+		return nil, errors.WrapError(err, "falha ao listar eventos do processo")
+	}
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, events), nil
+}
+
+// CreateEvent grava o evento lançado pelo usuário
+func (r *processTimelineRepository) CreateEvent(ctx context.Context, event *models.ProcessEvent) error {
+	if err := db.Conn(ctx, r.db).Create(event).Error; err != nil {
+		r.logger.Error("erro ao gravar evento do processo", zap.Error(err), zap.Int("process_id", event.ProcessID))
+		return errors.WrapError(err, "falha ao gravar evento do processo")
+	}
+	return nil
+}
+
+// processEvents lê a linha do tempo completa do processo em ordem cronológica
+func processEvents(conn *gorm.DB, processID int) ([]models.ProcessEvent, error) {
+	events := make([]models.ProcessEvent, 0)
+	if err := conn.Where("process_id = ?", processID).Order("occurred_at ASC, id ASC").Find(&events).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar linha do tempo do processo")
+	}
+	return events, nil
+}
+
+// RecordProcessEvent grava o evento na linha do tempo do processo, na transação de quem mudou o
+// processo. A empresa é a do processo, o que vale também para os jobs que atuam em todas as
+// empresas; o mesmo documento não gera dois eventos no processo.
+func RecordProcessEvent(tx *gorm.DB, processID int, event models.ProcessEvent) error {
+	return insertProcessEvents(tx, "p.id = ?", processID, event)
+}
+
+// recordInvoiceEvent grava o evento na linha do tempo dos processos vinculados à fatura
+func recordInvoiceEvent(tx *gorm.DB, invoiceID int, event models.ProcessEvent) error {
+	return insertProcessEvents(tx, "p.id IN (SELECT process_id FROM process_invoices WHERE invoice_id = ?)", invoiceID, event)
+}
+
+func insertProcessEvents(tx *gorm.DB, where string, arg int, event models.ProcessEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	err := tx.Exec(`
+		INSERT INTO process_events (company_id, process_id, event_type, description, document_type, document_id,
+			document_no, value, occurred_at, manual, created_by)
+		SELECT p.company_id, p.id, ?, ?, NULLIF(?, ''), NULLIF(?, 0), NULLIF(?, ''), ?, ?, ?, NULLIF(?, '')
+		FROM sales_processes p
+		WHERE `+where+`
+		ON CONFLICT DO NOTHING`,
+		event.EventType, event.Description, event.DocumentType, event.DocumentID, event.DocumentNo,
+		event.Value, event.Timestamp, event.Manual, event.CreatedBy, arg).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao gravar evento na linha do tempo do processo")
+	}
+	return nil
+}
+
+// StatusChangedEvent é o evento da mudança de etapa do processo, com as etapas pelo nome exibido
+func StatusChangedEvent(from, to string, at time.Time) models.ProcessEvent {
+	label := func(status string) string { return i18n.Label(i18n.Default, i18n.SalesProcessStatus, status) }
+	return models.ProcessEvent{
+		Timestamp:   at,
+		EventType:   models.ProcessEventStatusChanged,
+		Description: fmt.Sprintf("Etapa alterada de %s para %s", label(from), label(to)),
+	}
+}
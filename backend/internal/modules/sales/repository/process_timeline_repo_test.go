@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// A linha do tempo sai em ordem cronológica, qualquer que seja a ordem de gravação, e o mesmo
+// documento não aparece duas vezes no processo
+func TestProcessTimelineOnPostgres(t *testing.T) {
+	tx := testutils.PostgresTx(t)
+	repo := &processTimelineRepository{db: tx, logger: zap.NewNop()}
+
+	contact := testutils.CreateContact(t, tx)
+	process := testutils.CreateSalesProcess(t, tx, func(p *models.SalesProcess) { p.ContactID = contact.ID })
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	invoice := models.ProcessEvent{Timestamp: start.Add(48 * time.Hour), EventType: models.ProcessEventInvoiceCreated,
+		Description: "Fatura INV-1 criada", DocumentType: "invoice", DocumentID: 11, DocumentNo: "INV-1"}
+	require.NoError(t, RecordProcessEvent(tx, process.ID, invoice))
+	require.NoError(t, RecordProcessEvent(tx, process.ID, invoice))
+	require.NoError(t, RecordProcessEvent(tx, process.ID, StatusChangedEvent(ProcessStatusQuotation, ProcessStatusInvoicing, start.Add(49*time.Hour))))
+	require.NoError(t, repo.CreateEvent(context.Background(), &models.ProcessEvent{CompanyID: process.CompanyID, ProcessID: process.ID,
+		Timestamp: start, EventType: models.ProcessEventCall, Description: "Primeiro contato", Manual: true, CreatedBy: "ana"}))
+
+	result, err := repo.ListEvents(context.Background(), process.ID, false, &pagination.PaginationParams{Page: 1, PageSize: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.TotalItems)
+	events := result.Items.([]models.ProcessEvent)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.ProcessEventCall, events[0].EventType)
+	assert.Equal(t, "INV-1", events[1].DocumentNo)
+
+	result, err = repo.ListEvents(context.Background(), process.ID, true, &pagination.PaginationParams{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	events = result.Items.([]models.ProcessEvent)
+	require.Len(t, events, 3)
+	assert.Equal(t, models.ProcessEventStatusChanged, events[0].EventType)
+	assert.Equal(t, process.CompanyID, events[0].CompanyID)
+}
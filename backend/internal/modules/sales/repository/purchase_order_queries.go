@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/utils/pagination"
@@ -35,7 +37,7 @@ func (r *purchaseOrderRepository) GetAllPurchaseOrders(ctx context.Context, para
 	var total int64
 
 	// Query base
-	query := r.db.Model(&models.PurchaseOrder{})
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -64,7 +66,7 @@ func (r *purchaseOrderRepository) GetPurchaseOrdersByStatus(ctx context.Context,
 	var purchaseOrders []models.PurchaseOrder
 	var total int64
 
-	query := r.db.Model(&models.PurchaseOrder{}).Where("status = ?", status)
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).Where("status = ?", status)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -92,7 +94,7 @@ func (r *purchaseOrderRepository) GetPurchaseOrdersByContact(ctx context.Context
 	var purchaseOrders []models.PurchaseOrder
 	var total int64
 
-	query := r.db.Model(&models.PurchaseOrder{}).Where("contact_id = ?", contactID)
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).Where("contact_id = ?", contactID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -121,7 +123,7 @@ func (r *purchaseOrderRepository) GetPurchaseOrdersBySalesOrder(ctx context.Cont
 	var purchaseOrders []models.PurchaseOrder
 	var total int64
 
-	query := r.db.Model(&models.PurchaseOrder{}).Where("sales_order_id = ?", salesOrderID)
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).Where("sales_order_id = ?", salesOrderID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -151,7 +153,7 @@ func (r *purchaseOrderRepository) GetPurchaseOrdersByPeriod(ctx context.Context,
 	var purchaseOrders []models.PurchaseOrder
 	var total int64
 
-	query := r.db.Model(&models.PurchaseOrder{}).
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).
 		Where("created_at >= ? AND created_at <= ?", startDate, endDate)
 
 	// Conta o total
@@ -181,7 +183,7 @@ func (r *purchaseOrderRepository) GetPurchaseOrdersByExpectedDateRange(ctx conte
 	var purchaseOrders []models.PurchaseOrder
 	var total int64
 
-	query := r.db.Model(&models.PurchaseOrder{}).
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).
 		Where("expected_date >= ? AND expected_date <= ?", startDate, endDate)
 
 	// Conta o total
@@ -213,7 +215,7 @@ func (r *purchaseOrderRepository) GetPurchaseOrdersByContactType(ctx context.Con
 
 	// Primeiro, busca os IDs dos contatos do tipo especificado
 	var contactIDs []int
-	if err := r.db.Model(&contact.Contact{}).
+	if err := db.Conn(ctx, r.db).Model(&contact.Contact{}).
 		Where("type = ?", contactType).
 		Pluck("id", &contactIDs).Error; err != nil {
 		return nil, errors.WrapError(err, "falha ao buscar contatos por tipo")
@@ -225,7 +227,7 @@ func (r *purchaseOrderRepository) GetPurchaseOrdersByContactType(ctx context.Con
 	}
 
 	// Busca os purchase orders dos contatos encontrados
-	query := r.db.Model(&models.PurchaseOrder{}).Where("contact_id IN ?", contactIDs)
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).Where("contact_id IN ?", contactIDs)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -255,7 +257,7 @@ func (r *purchaseOrderRepository) GetPendingPurchaseOrders(ctx context.Context,
 	var total int64
 
 	pendingStatuses := []string{models.POStatusDraft, models.POStatusSent, models.POStatusConfirmed}
-	query := r.db.Model(&models.PurchaseOrder{}).Where("status IN ?", pendingStatuses)
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).Where("status IN ?", pendingStatuses)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -278,14 +280,14 @@ func (r *purchaseOrderRepository) GetPendingPurchaseOrders(ctx context.Context,
 	return result, nil
 }
 
-// GetOverduePurchaseOrders busca purchase orders vencidos
+// GetOverduePurchaseOrders busca purchase orders vencidos: data prevista anterior ao dia atual
+// no fuso da empresa
 func (r *purchaseOrderRepository) GetOverduePurchaseOrders(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var purchaseOrders []models.PurchaseOrder
 	var total int64
 
-	now := time.Now()
-	query := r.db.Model(&models.PurchaseOrder{}).
-		Where("expected_date < ? AND status IN ?", now, []string{models.POStatusDraft, models.POStatusSent, models.POStatusConfirmed})
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).
+		Where("expected_date < ? AND status IN ?", localtime.Today(ctx), []string{models.POStatusDraft, models.POStatusSent, models.POStatusConfirmed})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -313,7 +315,7 @@ func (r *purchaseOrderRepository) SearchPurchaseOrders(ctx context.Context, filt
 	var purchaseOrders []models.PurchaseOrder
 	var total int64
 
-	query := r.db.Model(&models.PurchaseOrder{})
+	query := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{})
 
 	// Aplica os filtros
 	if len(filter.Status) > 0 {
@@ -330,7 +332,7 @@ func (r *purchaseOrderRepository) SearchPurchaseOrders(ctx context.Context, filt
 
 	// Filtro por tipo de contato ou pessoa
 	if filter.ContactType != "" || filter.PersonType != "" {
-		contactQuery := r.db.Model(&contact.Contact{})
+		contactQuery := db.Conn(ctx, r.db).Model(&contact.Contact{})
 		if filter.ContactType != "" {
 			contactQuery = contactQuery.Where("type = ?", filter.ContactType)
 		}
@@ -364,21 +366,20 @@ func (r *purchaseOrderRepository) SearchPurchaseOrders(ctx context.Context, filt
 
 	// Filtro de overdue (vencido)
 	if filter.IsOverdue != nil && *filter.IsOverdue {
-		now := time.Now()
-		query = query.Where("expected_date < ? AND status IN ?", now, []string{models.POStatusDraft, models.POStatusSent, models.POStatusConfirmed})
+		query = query.Where("expected_date < ? AND status IN ?", localtime.Today(ctx), []string{models.POStatusDraft, models.POStatusSent, models.POStatusConfirmed})
 	}
 
 	// Filtro de delivery
 	if filter.HasDelivery != nil {
 		if *filter.HasDelivery {
 			var poIDs []int
-			r.db.Model(&models.Delivery{}).Distinct("purchase_order_id").Where("purchase_order_id IS NOT NULL").Pluck("purchase_order_id", &poIDs)
+			db.Conn(ctx, r.db).Model(&models.Delivery{}).Distinct("purchase_order_id").Where("purchase_order_id IS NOT NULL").Pluck("purchase_order_id", &poIDs)
 			if len(poIDs) > 0 {
 				query = query.Where("id IN ?", poIDs)
 			}
 		} else {
 			var poIDs []int
-			r.db.Model(&models.Delivery{}).Distinct("purchase_order_id").Where("purchase_order_id IS NOT NULL").Pluck("purchase_order_id", &poIDs)
+			db.Conn(ctx, r.db).Model(&models.Delivery{}).Distinct("purchase_order_id").Where("purchase_order_id IS NOT NULL").Pluck("purchase_order_id", &poIDs)
 			if len(poIDs) > 0 {
 				query = query.Where("id NOT IN ?", poIDs)
 			}
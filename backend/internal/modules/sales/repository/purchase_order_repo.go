@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/utils/pagination"
@@ -66,7 +67,7 @@ func (r *purchaseOrderRepository) CreatePurchaseOrder(ctx context.Context, purch
 
 	// Preparação do purchase order
 	if purchaseOrder.PONo == "" {
-		purchaseOrder.PONo = r.generatePurchaseOrderNumber()
+		purchaseOrder.PONo = r.generatePurchaseOrderNumber(ctx)
 	}
 
 	if purchaseOrder.Status == "" {
@@ -74,7 +75,7 @@ func (r *purchaseOrderRepository) CreatePurchaseOrder(ctx context.Context, purch
 	}
 
 	// Inicia transação com contexto
-	tx := r.db.WithContext(ctx).Begin()
+	tx := db.Conn(ctx, r.db).Begin()
 
 	// Verifica novamente o contexto após iniciar transação
 	if ctx.Err() != nil {
@@ -82,6 +83,20 @@ func (r *purchaseOrderRepository) CreatePurchaseOrder(ctx context.Context, purch
 		return errors.WrapError(ctx.Err(), "contexto expirou após iniciar transação")
 	}
 
+	// Confere as unidades dos itens (sem unidade, vale a unidade padrão de compra do produto)
+	if err := PreparePurchaseOrderItems(tx, purchaseOrder.Items); err != nil {
+		tx.Rollback()
+		r.logger.Warn("itens do purchase order rejeitados", zap.Error(err))
+		return err
+	}
+
+	// Preços dos produtos cobertos por contrato de compra vigente com o fornecedor
+	if err := ApplyPurchaseOrderContract(tx, purchaseOrder, time.Now()); err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao aplicar preços contratados no purchase order", zap.Error(err))
+		return err
+	}
+
 	// Cria o purchase order, omitindo sales_order_id se for 0 (para permitir NULL)
 	var err error
 	if purchaseOrder.SalesOrderID == 0 {
@@ -151,7 +166,7 @@ func (r *purchaseOrderRepository) GetPurchaseOrderByID(ctx context.Context, id i
 
 	var purchaseOrder models.PurchaseOrder
 
-	query := r.db.WithContext(ctx).Preload("Contact").
+	query := db.Conn(ctx, r.db).Preload("Contact").
 		Preload("SalesOrder").
 		Preload("Items").
 		Preload("Items.Product")
@@ -185,7 +200,7 @@ func (r *purchaseOrderRepository) UpdatePurchaseOrder(ctx context.Context, id in
 
 	// Verifica se o purchase order existe
 	var existing models.PurchaseOrder
-	if err := r.db.WithContext(ctx).First(&existing, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrPurchaseOrderNotFound
 		}
@@ -203,9 +218,9 @@ func (r *purchaseOrderRepository) UpdatePurchaseOrder(ctx context.Context, id in
 	// Trata SalesOrderID = 0 como omissão (para manter NULL no banco)
 	var err error
 	if purchaseOrder.SalesOrderID == 0 {
-		err = r.db.WithContext(ctx).Omit("sales_order_id").Save(purchaseOrder).Error
+		err = db.Conn(ctx, r.db).Omit("sales_order_id").Save(purchaseOrder).Error
 	} else {
-		err = r.db.WithContext(ctx).Save(purchaseOrder).Error
+		err = db.Conn(ctx, r.db).Save(purchaseOrder).Error
 	}
 
 	if err != nil {
@@ -235,7 +250,7 @@ func (r *purchaseOrderRepository) DeletePurchaseOrder(ctx context.Context, id in
 
 	// Verifica se existem deliveries relacionadas
 	var deliveryCount int64
-	if err := r.db.WithContext(ctx).Model(&models.Delivery{}).Where("purchase_order_id = ?", id).Count(&deliveryCount).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Model(&models.Delivery{}).Where("purchase_order_id = ?", id).Count(&deliveryCount).Error; err != nil {
 		return errors.WrapError(err, "falha ao verificar deliveries relacionadas")
 	}
 
@@ -254,7 +269,7 @@ func (r *purchaseOrderRepository) DeletePurchaseOrder(ctx context.Context, id in
 	}
 
 	// Remove o purchase order (cascade removerá os itens)
-	result := r.db.WithContext(ctx).Delete(&models.PurchaseOrder{}, id)
+	result := db.Conn(ctx, r.db).Delete(&models.PurchaseOrder{}, id)
 	if result.Error != nil {
 		r.logger.Error("erro ao deletar purchase order", zap.Error(result.Error), zap.Int("id", id))
 		return errors.WrapError(result.Error, "falha ao deletar purchase order")
@@ -269,11 +284,11 @@ func (r *purchaseOrderRepository) DeletePurchaseOrder(ctx context.Context, id in
 }
 
 // generatePurchaseOrderNumber gera um número único para o purchase order
-func (r *purchaseOrderRepository) generatePurchaseOrderNumber() string {
+func (r *purchaseOrderRepository) generatePurchaseOrderNumber(ctx context.Context) string {
 	// Implementação simples - você pode melhorar isso
 	var lastPurchaseOrder models.PurchaseOrder
 
-	r.db.Order("id DESC").First(&lastPurchaseOrder)
+	db.Conn(ctx, r.db).Order("id DESC").First(&lastPurchaseOrder)
 
 	year := time.Now().Year()
 	sequence := lastPurchaseOrder.ID + 1
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/utils/pagination"
@@ -33,7 +35,7 @@ func (r *quotationRepository) GetAllQuotations(ctx context.Context, params *pagi
 	var total int64
 
 	// Query base
-	query := r.db.WithContext(ctx).Model(&models.Quotation{})
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -62,7 +64,7 @@ func (r *quotationRepository) GetQuotationsByStatus(ctx context.Context, status
 	var quotations []models.Quotation
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&models.Quotation{}).Where("status = ?", status)
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{}).Where("status = ?", status)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -90,7 +92,7 @@ func (r *quotationRepository) GetQuotationsByContact(ctx context.Context, contac
 	var quotations []models.Quotation
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&models.Quotation{}).Where("contact_id = ?", contactID)
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{}).Where("contact_id = ?", contactID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -119,9 +121,8 @@ func (r *quotationRepository) GetExpiredQuotations(ctx context.Context, params *
 	var quotations []models.Quotation
 	var total int64
 
-	now := time.Now()
-	query := r.db.WithContext(ctx).Model(&models.Quotation{}).
-		Where("expiry_date < ? AND status NOT IN ?", now, []string{models.QuotationStatusAccepted, models.QuotationStatusRejected, models.QuotationStatusCancelled})
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{}).
+		Where("expiry_date < ? AND status NOT IN ?", localtime.Today(ctx), []string{models.QuotationStatusAccepted, models.QuotationStatusRejected, models.QuotationStatusCancelled})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -149,7 +150,7 @@ func (r *quotationRepository) GetQuotationsByDateRange(ctx context.Context, star
 	var quotations []models.Quotation
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&models.Quotation{}).
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{}).
 		Where("created_at >= ? AND created_at <= ?", startDate, endDate)
 
 	// Conta o total
@@ -179,7 +180,7 @@ func (r *quotationRepository) GetQuotationsByExpiryRange(ctx context.Context, st
 	var quotations []models.Quotation
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&models.Quotation{}).
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{}).
 		Where("expiry_date >= ? AND expiry_date <= ?", startDate, endDate)
 
 	// Conta o total
@@ -209,7 +210,7 @@ func (r *quotationRepository) SearchQuotations(ctx context.Context, filter Quota
 	var quotations []models.Quotation
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&models.Quotation{})
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{})
 
 	// Aplica os filtros
 	if len(filter.Status) > 0 {
@@ -222,7 +223,7 @@ func (r *quotationRepository) SearchQuotations(ctx context.Context, filter Quota
 
 	// Filtro por tipo de contato ou pessoa
 	if filter.ContactType != "" || filter.PersonType != "" {
-		contactQuery := r.db.WithContext(ctx).Model(&contact.Contact{})
+		contactQuery := db.Conn(ctx, r.db).Model(&contact.Contact{})
 		if filter.ContactType != "" {
 			contactQuery = contactQuery.Where("type = ?", filter.ContactType)
 		}
@@ -256,8 +257,7 @@ func (r *quotationRepository) SearchQuotations(ctx context.Context, filter Quota
 
 	// Filtro de expiração
 	if filter.IsExpired != nil && *filter.IsExpired {
-		now := time.Now()
-		query = query.Where("expiry_date < ? AND status NOT IN ?", now, []string{models.QuotationStatusAccepted, models.QuotationStatusRejected, models.QuotationStatusCancelled})
+		query = query.Where("expiry_date < ? AND status NOT IN ?", localtime.Today(ctx), []string{models.QuotationStatusAccepted, models.QuotationStatusRejected, models.QuotationStatusCancelled})
 	}
 
 	// Busca textual
@@ -297,7 +297,7 @@ func (r *quotationRepository) GetQuotationsByContactType(ctx context.Context, co
 
 	// Primeiro, busca os IDs dos contatos do tipo especificado
 	var contactIDs []int
-	if err := r.db.WithContext(ctx).Model(&contact.Contact{}).
+	if err := db.Conn(ctx, r.db).Model(&contact.Contact{}).
 		Where("type = ?", contactType).
 		Pluck("id", &contactIDs).Error; err != nil {
 		return nil, errors.WrapError(err, "falha ao buscar contatos por tipo")
@@ -309,7 +309,7 @@ func (r *quotationRepository) GetQuotationsByContactType(ctx context.Context, co
 	}
 
 	// Busca as quotations dos contatos encontrados
-	query := r.db.WithContext(ctx).Model(&models.Quotation{}).Where("contact_id IN ?", contactIDs)
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{}).Where("contact_id IN ?", contactIDs)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -338,11 +338,11 @@ func (r *quotationRepository) GetExpiringQuotations(ctx context.Context, days in
 	var quotations []models.Quotation
 	var total int64
 
-	now := time.Now()
-	expiryLimit := now.AddDate(0, 0, days)
+	today := localtime.Today(ctx)
+	expiryLimit := today.AddDate(0, 0, days)
 
-	query := r.db.WithContext(ctx).Model(&models.Quotation{}).
-		Where("expiry_date >= ? AND expiry_date <= ?", now, expiryLimit).
+	query := db.Conn(ctx, r.db).Model(&models.Quotation{}).
+		Where("expiry_date >= ? AND expiry_date <= ?", today, expiryLimit).
 		Where("status IN ?", []string{models.QuotationStatusDraft, models.QuotationStatusSent})
 
 	// Conta o total
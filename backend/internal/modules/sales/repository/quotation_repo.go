@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/errors"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
+	products "ERP-ONSMART/backend/internal/modules/products/repository"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/utils/pagination"
 	"fmt"
@@ -70,15 +73,20 @@ func (r *quotationRepository) CreateQuotation(ctx context.Context, quotation *mo
 	// Preparação da cotação
 	if quotation.QuotationNo == "" {
 		// Ideal seria passar o contexto aqui também
-		quotation.QuotationNo = r.generateQuotationNumber()
+		quotation.QuotationNo = r.generateQuotationNumber(ctx)
 	}
 
 	if quotation.Status == "" {
 		quotation.Status = models.QuotationStatusDraft
 	}
 
+	// Totais informados pelo cliente precisam conferir com os itens
+	if err := models.ApplyQuotationTotals(quotation); err != nil {
+		return err
+	}
+
 	// Inicia transação
-	tx := r.db.WithContext(ctx).Begin()
+	tx := db.Conn(ctx, r.db).Begin()
 
 	// Verifica novamente o contexto após iniciar transação (pode ter atingido timeout)
 	if ctx.Err() != nil {
@@ -86,6 +94,29 @@ func (r *quotationRepository) CreateQuotation(ctx context.Context, quotation *mo
 		return errors.WrapError(ctx.Err(), "contexto expirou após iniciar transação")
 	}
 
+	// Contato bloqueado não recebe novas cotações
+	if err := contactRepository.EnsureNotBlocked(tx, quotation.ContactID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Confere as unidades e desdobra os kits com modo explode nas linhas dos componentes
+	items, err := PrepareQuotationItems(tx, quotation.Items)
+	if err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao preparar itens da quotation", zap.Error(err))
+		return err
+	}
+	quotation.Items = items
+
+	// Preços dos produtos cobertos por contrato de venda vigente com o cliente
+	if err := ApplyQuotationContract(tx, quotation, time.Now()); err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao aplicar preços contratados na quotation", zap.Error(err))
+		return err
+	}
+	models.RecalculateQuotationTotals(quotation)
+
 	// Cria a quotation
 	if err := tx.Create(quotation).Error; err != nil {
 		tx.Rollback()
@@ -134,7 +165,7 @@ func (r *quotationRepository) CreateQuotation(ctx context.Context, quotation *mo
 func (r *quotationRepository) GetQuotationByID(ctx context.Context, id int) (*models.Quotation, error) {
 	var quotation models.Quotation
 
-	query := r.db.WithContext(ctx).Preload("Contact").
+	query := db.Conn(ctx, r.db).Preload("Contact").
 		Preload("Items").
 		Preload("Items.Product")
 
@@ -153,16 +184,37 @@ func (r *quotationRepository) GetQuotationByID(ctx context.Context, id int) (*mo
 func (r *quotationRepository) UpdateQuotation(ctx context.Context, id int, quotation *models.Quotation) error {
 	// Verifica se a quotation existe
 	var existing models.Quotation
-	if err := r.db.WithContext(ctx).First(&existing, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrQuotationNotFound
 		}
 		return errors.WrapError(err, "falha ao verificar quotation existente")
 	}
 
-	// Atualiza os campos
+	// Atualiza os campos; o frete escolhido só muda pelo endpoint de frete
 	quotation.ID = id
-	if err := r.db.WithContext(ctx).Save(quotation).Error; err != nil {
+	quotation.ShippingOption = existing.ShippingOption
+
+	// Os totais vêm dos itens; sem itens no payload, valem os já gravados
+	if len(quotation.Items) > 0 {
+		if err := r.ensureNewItemsQuotable(ctx, id, quotation.Items); err != nil {
+			return err
+		}
+		if err := models.ApplyQuotationTotals(quotation); err != nil {
+			return err
+		}
+	} else {
+		if err := db.Conn(ctx, r.db).Where("quotation_id = ?", id).Find(&quotation.Items).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar itens da quotation")
+		}
+		err := models.ApplyQuotationTotals(quotation)
+		quotation.Items = nil
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := db.Conn(ctx, r.db).Save(quotation).Error; err != nil {
 		r.logger.Error("erro ao atualizar quotation", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao atualizar quotation")
 	}
@@ -171,11 +223,33 @@ func (r *quotationRepository) UpdateQuotation(ctx context.Context, id int, quota
 	return nil
 }
 
-// DeleteQuotation remove uma quotation
+// ensureNewItemsQuotable recusa produtos descontinuados entre os itens que a atualização
+// acrescenta; os que já estavam na cotação continuam válidos
+func (r *quotationRepository) ensureNewItemsQuotable(ctx context.Context, id int, items []models.QuotationItem) error {
+	var current []int
+	if err := db.Conn(ctx, r.db).Model(&models.QuotationItem{}).
+		Where("quotation_id = ?", id).Pluck("product_id", &current).Error; err != nil {
+		return errors.WrapError(err, "falha ao buscar itens da quotation")
+	}
+	existing := make(map[int]bool, len(current))
+	for _, productID := range current {
+		existing[productID] = true
+	}
+
+	added := make([]int, 0, len(items))
+	for _, item := range items {
+		if !existing[item.ProductID] {
+			added = append(added, item.ProductID)
+		}
+	}
+	return products.EnsureQuotable(db.Conn(ctx, r.db), added)
+}
+
+// DeleteQuotation move uma quotation para a lixeira (soft delete)
 func (r *quotationRepository) DeleteQuotation(ctx context.Context, id int) error {
 	// Verifica se existem sales orders relacionadas
 	var salesOrderCount int64
-	if err := r.db.WithContext(ctx).Model(&models.SalesOrder{}).Where("quotation_id = ?", id).Count(&salesOrderCount).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Model(&models.SalesOrder{}).Where("quotation_id = ?", id).Count(&salesOrderCount).Error; err != nil {
 		return errors.WrapError(err, "falha ao verificar pedidos de venda relacionados")
 	}
 
@@ -183,8 +257,8 @@ func (r *quotationRepository) DeleteQuotation(ctx context.Context, id int) error
 		return errors.ErrRelatedRecordsExist
 	}
 
-	// Remove a quotation (cascade removerá os itens)
-	result := r.db.WithContext(ctx).Delete(&models.Quotation{}, id)
+	// Soft delete: os itens são mantidos para permitir a restauração
+	result := db.Conn(ctx, r.db).Delete(&models.Quotation{}, id)
 	if result.Error != nil {
 		r.logger.Error("erro ao deletar quotation", zap.Error(result.Error), zap.Int("id", id))
 		return errors.WrapError(result.Error, "falha ao deletar quotation")
@@ -199,9 +273,9 @@ func (r *quotationRepository) DeleteQuotation(ctx context.Context, id int) error
 }
 
 // generateQuotationNumber gera um número único para a quotation
-func (r *quotationRepository) generateQuotationNumber() string {
+func (r *quotationRepository) generateQuotationNumber(ctx context.Context) string {
 	var lastQuotation models.Quotation
-	err := r.db.Order("id DESC").First(&lastQuotation).Error
+	err := db.Conn(ctx, r.db).Order("id DESC").First(&lastQuotation).Error
 	year := time.Now().Year()
 	if err != nil {
 		// Se não houver registro, inicia a sequência em 1
@@ -214,11 +288,11 @@ func (r *quotationRepository) generateQuotationNumber() string {
 	return fmt.Sprintf("QT-%d-%06d", year, sequence)
 }
 
-func (r *quotationRepository) generateSalesOrderNumber(tx *gorm.DB) string { // --> mover para SalesOrder
+func (r *quotationRepository) generateSalesOrderNumber(ctx context.Context, tx *gorm.DB) string { // --> mover para SalesOrder
 	var lastOrder models.SalesOrder
 
-	// Se tx for nil, usa r.db
-	db := r.db
+	// Se tx for nil, usa db.Conn(ctx, r.db)
+	db := db.Conn(ctx, r.db)
 	if tx != nil {
 		db = tx
 	}
@@ -236,5 +310,5 @@ func (r *quotationRepository) generateSalesOrderNumber(tx *gorm.DB) string { //
 
 // Apenas para uso em testes -> mover para testes
 func (r *quotationRepository) SetCreatedAtForTesting(ctx context.Context, quotationID int, createdAt time.Time) error {
-	return r.db.WithContext(ctx).Exec("UPDATE quotations SET created_at = ? WHERE id = ?", createdAt, quotationID).Error
+	return db.Conn(ctx, r.db).Exec("UPDATE quotations SET created_at = ? WHERE id = ?", createdAt, quotationID).Error
 }
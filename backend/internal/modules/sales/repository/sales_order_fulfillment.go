@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetSalesOrderFulfillment retorna o atendimento do pedido por linha (entregue, pendente e em backorder)
+func (r *salesOrderRepository) GetSalesOrderFulfillment(ctx context.Context, id int) (*models.SalesOrderFulfillment, error) {
+	if ctx.Err() != nil {
+		return nil, errors.WrapError(ctx.Err(), "erro de contexto ao buscar atendimento do sales order")
+	}
+
+	db := db.Conn(ctx, r.db)
+
+	var salesOrder models.SalesOrder
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&salesOrder, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrSalesOrderNotFound
+		}
+		r.logger.Error("erro ao buscar sales order", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar sales order")
+	}
+
+	shipped, err := loadShippedLines(db, id)
+	if err != nil {
+		r.logger.Error("erro ao buscar itens entregues", zap.Error(err), zap.Int("id", id))
+		return nil, err
+	}
+
+	stock, err := loadProductStock(db, salesOrder.Items)
+	if err != nil {
+		r.logger.Error("erro ao buscar estoque dos produtos", zap.Error(err), zap.Int("id", id))
+		return nil, err
+	}
+
+	fulfillment := models.BuildFulfillment(&salesOrder, shipped, stock)
+
+	if err := db.Raw(`
+		SELECT d.id AS delivery_id, d.delivery_no, d.status, d.delivery_date,
+		       COALESCE(SUM(di.quantity), 0) AS total_qty
+		FROM deliveries d
+		LEFT JOIN delivery_items di ON di.delivery_id = d.id
+		WHERE d.sales_order_id = ?
+		GROUP BY d.id, d.delivery_no, d.status, d.delivery_date
+		ORDER BY d.created_at ASC`, id).
+		Scan(&fulfillment.Deliveries).Error; err != nil {
+		r.logger.Error("erro ao buscar entregas do pedido", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar entregas do pedido")
+	}
+
+	return fulfillment, nil
+}
+
+// checkShipment valida uma nova entrega contra o saldo do pedido de venda, dentro da transação.
+// As linhas do pedido são bloqueadas para evitar que entregas simultâneas excedam o pedido.
+// Em caso de erro, retorna também a posição (1-based) do item rejeitado, quando houver.
+func checkShipment(tx *gorm.DB, delivery *models.Delivery) (int, error) {
+	var salesOrder models.SalesOrder
+	if err := tx.First(&salesOrder, delivery.SalesOrderID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, errors.ErrSalesOrderNotFound
+		}
+		return 0, errors.WrapError(err, "falha ao buscar sales order")
+	}
+
+	if salesOrder.Status == models.SOStatusDraft || salesOrder.Status == models.SOStatusCancelled {
+		return 0, errors.ErrSalesOrderNotShippable
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sales_order_id = ?", salesOrder.ID).
+		Order("id ASC").
+		Find(&salesOrder.Items).Error; err != nil {
+		return 0, errors.WrapError(err, "falha ao buscar itens do sales order")
+	}
+
+	shipped, err := loadShippedLines(tx, salesOrder.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return models.AllocateShipment(&salesOrder, shipped, delivery.Items)
+}
+
+// loadShippedLines retorna os itens já alocados ao pedido por entregas não devolvidas
+func loadShippedLines(db *gorm.DB, salesOrderID int) ([]models.ShippedLine, error) {
+	var lines []models.ShippedLine
+	if err := db.Raw(`
+		SELECT di.so_item_id, di.product_id, di.quantity,
+		       (d.status = ?) AS delivered
+		FROM delivery_items di
+		JOIN deliveries d ON d.id = di.delivery_id
+		WHERE d.sales_order_id = ? AND d.status <> ?
+		ORDER BY d.created_at ASC, di.id ASC`,
+		models.DeliveryStatusDelivered, salesOrderID, models.DeliveryStatusReturned).
+		Scan(&lines).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar itens entregues do pedido")
+	}
+	return lines, nil
+}
+
+// loadProductStock retorna o estoque atual dos produtos das linhas do pedido
+func loadProductStock(db *gorm.DB, items []models.SOItem) (map[int]int, error) {
+	stock := make(map[int]int)
+	if len(items) == 0 {
+		return stock, nil
+	}
+
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	var rows []struct {
+		ID    int
+		Stock int
+	}
+	if err := db.Table("products").Select("id, stock").
+		Where("id IN ?", productIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar estoque dos produtos")
+	}
+
+	for _, row := range rows {
+		stock[row.ID] = row.Stock
+	}
+	return stock, nil
+}
@@ -1,9 +1,13 @@
 package repository
 
 import (
+	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/errors"
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	customfields "ERP-ONSMART/backend/internal/modules/customfields/models"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/listquery"
 	"ERP-ONSMART/backend/internal/utils/pagination"
 	"context"
 	"fmt"
@@ -28,6 +32,9 @@ type SalesOrderFilter struct {
 	HasInvoice        *bool
 	HasPurchaseOrder  *bool
 	SearchQuery       string
+	Sort              []listquery.SortField // ?sort=; vazio ordena por created_at DESC
+	Fields            []string              // ?fields=; vazio carrega Contact e Items
+	CustomFields      customfields.Values   // ?cf.<chave>=; igualdade exata nos campos personalizados
 }
 
 // GetAllSalesOrders retorna todos os sales orders com paginação
@@ -50,7 +57,7 @@ func (r *salesOrderRepository) GetAllSalesOrders(ctx context.Context, params *pa
 	var total int64
 
 	// Query base com contexto
-	query := r.db.WithContext(ctx).Model(&models.SalesOrder{})
+	query := db.Conn(ctx, r.db).Model(&models.SalesOrder{})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -103,7 +110,7 @@ func (r *salesOrderRepository) GetSalesOrdersByStatus(ctx context.Context, statu
 	var total int64
 
 	// Query base com contexto e filtro por status
-	query := r.db.WithContext(ctx).Model(&models.SalesOrder{}).Where("status = ?", status)
+	query := db.Conn(ctx, r.db).Model(&models.SalesOrder{}).Where("status = ?", status)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -155,7 +162,7 @@ func (r *salesOrderRepository) GetSalesOrdersByContact(ctx context.Context, cont
 	var total int64
 
 	// Query base com contexto e filtro por contato
-	query := r.db.WithContext(ctx).Model(&models.SalesOrder{}).Where("contact_id = ?", contactID)
+	query := db.Conn(ctx, r.db).Model(&models.SalesOrder{}).Where("contact_id = ?", contactID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -208,7 +215,7 @@ func (r *salesOrderRepository) GetSalesOrdersByQuotation(ctx context.Context, qu
 	var total int64
 
 	// Query base com contexto e filtro por quotation
-	query := r.db.WithContext(ctx).Model(&models.SalesOrder{}).Where("quotation_id = ?", quotationID)
+	query := db.Conn(ctx, r.db).Model(&models.SalesOrder{}).Where("quotation_id = ?", quotationID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -264,7 +271,7 @@ func (r *salesOrderRepository) GetSalesOrdersByPeriod(ctx context.Context, start
 	var total int64
 
 	// Query base com contexto e filtro por período
-	query := r.db.WithContext(ctx).Model(&models.SalesOrder{}).
+	query := db.Conn(ctx, r.db).Model(&models.SalesOrder{}).
 		Where("created_at >= ? AND created_at <= ?", startDate, endDate)
 
 	// Conta o total
@@ -320,7 +327,7 @@ func (r *salesOrderRepository) GetSalesOrdersByDateRange(ctx context.Context, st
 	var total int64
 
 	// Query base com contexto e filtro por data esperada
-	query := r.db.WithContext(ctx).Model(&models.SalesOrder{}).
+	query := db.Conn(ctx, r.db).Model(&models.SalesOrder{}).
 		Where("expected_date >= ? AND expected_date <= ?", startDate, endDate)
 
 	// Conta o total
@@ -374,7 +381,7 @@ func (r *salesOrderRepository) SearchSalesOrders(ctx context.Context, filter Sal
 	var total int64
 
 	// Inicia a query base com contexto
-	query := r.db.WithContext(ctx).Model(&models.SalesOrder{})
+	query := db.Conn(ctx, r.db).Model(&models.SalesOrder{})
 
 	// Aplica os diversos filtros usando métodos auxiliares
 	query = r.applyStatusFilter(query, filter)
@@ -389,6 +396,7 @@ func (r *salesOrderRepository) SearchSalesOrders(ctx context.Context, filter Sal
 
 	query = r.applyRelatedEntityFilters(ctx, query, filter)
 	query = r.applyTextSearchFilter(query, filter)
+	query = r.applyCustomFieldsFilter(query, filter)
 
 	// Verifica contexto antes da contagem
 	if ctx.Err() != nil {
@@ -406,11 +414,9 @@ func (r *salesOrderRepository) SearchSalesOrders(ctx context.Context, filter Sal
 		return nil, errors.WrapError(ctx.Err(), "contexto expirou antes da busca principal")
 	}
 
-	// Aplica paginação e busca os dados
+	// Aplica ordenação, seleção de campos e paginação e busca os dados
 	offset := pagination.CalculateOffset(params.Page, params.PageSize)
-	if err := query.Preload("Contact").
-		Preload("Items").
-		Order("created_at DESC").
+	if err := SalesOrderListSpec.Apply(query, filter.Sort, filter.Fields).
 		Limit(params.PageSize).
 		Offset(offset).
 		Find(&salesOrders).Error; err != nil {
@@ -440,7 +446,7 @@ func (r *salesOrderRepository) applyContactFilter(ctx context.Context, query *go
 	// Filtro por tipo de contato ou pessoa
 	if filter.ContactType != "" || filter.PersonType != "" {
 		var contactIDs []int
-		contactQuery := r.db.WithContext(ctx).Model(&contact.Contact{})
+		contactQuery := db.Conn(ctx, r.db).Model(&contact.Contact{})
 
 		if filter.ContactType != "" {
 			contactQuery = contactQuery.Where("type = ?", filter.ContactType)
@@ -503,7 +509,8 @@ func (r *salesOrderRepository) getRelatedOrderIDs(ctx context.Context, entityTyp
 	}
 
 	var orderIDs []int
-	query := r.db.WithContext(ctx).Table(tableName).
+	query := db.Conn(ctx, r.db).Table(tableName).
+		Scopes(tenant.Scope(ctx, tableName)).
 		Distinct("sales_order_id").
 		Where("sales_order_id IS NOT NULL")
 
@@ -541,6 +548,14 @@ func (r *salesOrderRepository) applyRelatedEntityFilters(ctx context.Context, qu
 	return query
 }
 
+// Método auxiliar para filtrar pelos campos personalizados (containment no JSONB)
+func (r *salesOrderRepository) applyCustomFieldsFilter(query *gorm.DB, filter SalesOrderFilter) *gorm.DB {
+	if len(filter.CustomFields) > 0 {
+		return query.Where("sales_orders.custom_fields @> ?", filter.CustomFields)
+	}
+	return query
+}
+
 // Método auxiliar para busca textual
 func (r *salesOrderRepository) applyTextSearchFilter(query *gorm.DB, filter SalesOrderFilter) *gorm.DB {
 	if filter.SearchQuery != "" {
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"ERP-ONSMART/backend/internal/db"
 	"ERP-ONSMART/backend/internal/errors"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/utils/pagination"
 	"context"
@@ -30,6 +32,9 @@ type SalesOrderRepository interface {
 
 	// Busca avançada (opcional, considere mover para serviço se contiver muita lógica de negócio)
 	SearchSalesOrders(ctx context.Context, filter SalesOrderFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+
+	// Atendimento (entregas parciais e backorder)
+	GetSalesOrderFulfillment(ctx context.Context, id int) (*models.SalesOrderFulfillment, error)
 }
 
 type salesOrderRepository struct {
@@ -63,15 +68,20 @@ func (r *salesOrderRepository) CreateSalesOrder(ctx context.Context, salesOrder
 
 	// Preparação do sales order
 	if salesOrder.SONo == "" {
-		salesOrder.SONo = r.generateSalesOrderNumber()
+		salesOrder.SONo = r.generateSalesOrderNumber(ctx)
 	}
 
 	if salesOrder.Status == "" {
 		salesOrder.Status = models.SOStatusDraft
 	}
 
+	// Totais informados pelo cliente precisam conferir com os itens
+	if err := models.ApplySalesOrderTotals(salesOrder); err != nil {
+		return err
+	}
+
 	// Inicia transação com contexto
-	tx := r.db.WithContext(ctx).Begin()
+	tx := db.Conn(ctx, r.db).Begin()
 
 	// Verifica novamente o contexto após iniciar transação
 	if ctx.Err() != nil {
@@ -79,8 +89,23 @@ func (r *salesOrderRepository) CreateSalesOrder(ctx context.Context, salesOrder
 		return errors.WrapError(ctx.Err(), "contexto expirou após iniciar transação")
 	}
 
+	// Contato bloqueado não recebe novos pedidos
+	if err := contactRepository.EnsureNotBlocked(tx, salesOrder.ContactID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Confere as unidades e desdobra os kits com modo explode nas linhas dos componentes
+	items, err := PrepareSalesOrderItems(tx, salesOrder.Items)
+	if err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao preparar itens do sales order", zap.Error(err))
+		return err
+	}
+	salesOrder.Items = items
+	models.RecalculateSalesOrderTotals(salesOrder)
+
 	// Cria o sales order, omitindo quotation_id se for 0 (para permitir NULL)
-	var err error
 	if salesOrder.QuotationID == 0 {
 		err = tx.Omit("quotation_id").Create(salesOrder).Error
 	} else {
@@ -148,7 +173,7 @@ func (r *salesOrderRepository) GetSalesOrderByID(ctx context.Context, id int) (*
 
 	var salesOrder models.SalesOrder
 
-	query := r.db.WithContext(ctx).Preload("Contact").
+	query := db.Conn(ctx, r.db).Preload("Contact").
 		Preload("Quotation").
 		Preload("Items").
 		Preload("Items.Product")
@@ -182,7 +207,7 @@ func (r *salesOrderRepository) UpdateSalesOrder(ctx context.Context, id int, sal
 
 	// Verifica se o sales order existe
 	var existing models.SalesOrder
-	if err := r.db.WithContext(ctx).First(&existing, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesOrderNotFound
 		}
@@ -194,15 +219,32 @@ func (r *salesOrderRepository) UpdateSalesOrder(ctx context.Context, id int, sal
 		return errors.WrapError(ctx.Err(), "contexto expirou antes do update")
 	}
 
-	// Atualiza os campos
+	// Atualiza os campos; o frete escolhido só muda pelo endpoint de frete
 	salesOrder.ID = id
+	salesOrder.ShippingOption = existing.ShippingOption
+
+	// Os totais vêm dos itens; sem itens no payload, valem os já gravados
+	if len(salesOrder.Items) > 0 {
+		if err := models.ApplySalesOrderTotals(salesOrder); err != nil {
+			return err
+		}
+	} else {
+		if err := db.Conn(ctx, r.db).Where("sales_order_id = ?", id).Find(&salesOrder.Items).Error; err != nil {
+			return errors.WrapError(err, "falha ao buscar itens do sales order")
+		}
+		err := models.ApplySalesOrderTotals(salesOrder)
+		salesOrder.Items = nil
+		if err != nil {
+			return err
+		}
+	}
 
 	// Trata QuotationID = 0 como omissão (para manter NULL no banco)
 	var err error
 	if salesOrder.QuotationID == 0 {
-		err = r.db.WithContext(ctx).Omit("quotation_id").Save(salesOrder).Error
+		err = db.Conn(ctx, r.db).Omit("quotation_id").Save(salesOrder).Error
 	} else {
-		err = r.db.WithContext(ctx).Save(salesOrder).Error
+		err = db.Conn(ctx, r.db).Save(salesOrder).Error
 	}
 
 	if err != nil {
@@ -214,7 +256,7 @@ func (r *salesOrderRepository) UpdateSalesOrder(ctx context.Context, id int, sal
 	return nil
 }
 
-// DeleteSalesOrder remove um sales order
+// DeleteSalesOrder move um sales order para a lixeira (soft delete)
 func (r *salesOrderRepository) DeleteSalesOrder(ctx context.Context, id int) error {
 	// Verificação inicial do contexto
 	if ctx.Err() != nil {
@@ -232,7 +274,7 @@ func (r *salesOrderRepository) DeleteSalesOrder(ctx context.Context, id int) err
 
 	// Verifica se existem invoices ou purchase orders relacionados
 	var invoiceCount int64
-	if err := r.db.WithContext(ctx).Model(&models.Invoice{}).Where("sales_order_id = ?", id).Count(&invoiceCount).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Model(&models.Invoice{}).Where("sales_order_id = ?", id).Count(&invoiceCount).Error; err != nil {
 		return errors.WrapError(err, "falha ao verificar invoices relacionadas")
 	}
 
@@ -246,7 +288,7 @@ func (r *salesOrderRepository) DeleteSalesOrder(ctx context.Context, id int) err
 	}
 
 	var poCount int64
-	if err := r.db.WithContext(ctx).Model(&models.PurchaseOrder{}).Where("sales_order_id = ?", id).Count(&poCount).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Model(&models.PurchaseOrder{}).Where("sales_order_id = ?", id).Count(&poCount).Error; err != nil {
 		return errors.WrapError(err, "falha ao verificar purchase orders relacionadas")
 	}
 
@@ -259,8 +301,8 @@ func (r *salesOrderRepository) DeleteSalesOrder(ctx context.Context, id int) err
 		return errors.WrapError(ctx.Err(), "contexto expirou antes do delete")
 	}
 
-	// Remove o sales order (cascade removerá os itens)
-	result := r.db.WithContext(ctx).Delete(&models.SalesOrder{}, id)
+	// Soft delete: os itens são mantidos para permitir a restauração
+	result := db.Conn(ctx, r.db).Delete(&models.SalesOrder{}, id)
 	if result.Error != nil {
 		r.logger.Error("erro ao deletar sales order", zap.Error(result.Error), zap.Int("id", id))
 		return errors.WrapError(result.Error, "falha ao deletar sales order")
@@ -275,11 +317,11 @@ func (r *salesOrderRepository) DeleteSalesOrder(ctx context.Context, id int) err
 }
 
 // generateSalesOrderNumber gera um número único para o sales order
-func (r *salesOrderRepository) generateSalesOrderNumber() string {
+func (r *salesOrderRepository) generateSalesOrderNumber(ctx context.Context) string {
 	// Implementação simples - você pode melhorar isso
 	var lastSalesOrder models.SalesOrder
 
-	r.db.Order("id DESC").First(&lastSalesOrder)
+	db.Conn(ctx, r.db).Order("id DESC").First(&lastSalesOrder)
 
 	year := time.Now().Year()
 	sequence := lastSalesOrder.ID + 1
@@ -2,11 +2,16 @@ package repository
 
 import (
 	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/db/sqlexpr"
 	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
 	"ERP-ONSMART/backend/internal/logger"
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
 	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -17,37 +22,38 @@ import (
 
 // SalesProcessRepository define as operações do repositório de sales process
 type SalesProcessRepository interface {
-	CreateSalesProcess(salesProcess *models.SalesProcess) error
-	GetSalesProcessByID(id int) (*models.SalesProcess, error)
-	GetAllSalesProcesses(params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	UpdateSalesProcess(id int, salesProcess *models.SalesProcess) error
-	DeleteSalesProcess(id int) error
-	GetSalesProcessesByStatus(status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetSalesProcessesByContact(contactID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetSalesProcessesByPeriod(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	SearchSalesProcesses(filter SalesProcessFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetSalesProcessStats(filter SalesProcessFilter) (*SalesProcessStats, error)
-	GetContactSalesProcessSummary(contactID int) (*ContactSalesProcessSummary, error)
+	CreateSalesProcess(ctx context.Context, salesProcess *models.SalesProcess) error
+	GetSalesProcessByID(ctx context.Context, id int) (*models.SalesProcess, error)
+	GetAllSalesProcesses(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	UpdateSalesProcess(ctx context.Context, id int, salesProcess *models.SalesProcess) error
+	DeleteSalesProcess(ctx context.Context, id int) error
+	GetSalesProcessesByStatus(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetSalesProcessesByContact(ctx context.Context, contactID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetSalesProcessesByPeriod(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	SearchSalesProcesses(ctx context.Context, filter SalesProcessFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetSalesProcessStats(ctx context.Context, filter SalesProcessFilter) (*SalesProcessStats, error)
+	GetContactSalesProcessSummary(ctx context.Context, contactID int) (*ContactSalesProcessSummary, error)
 
 	// Process flow methods
-	InitiateFromQuotation(quotationID int) (*models.SalesProcess, error)
-	LinkQuotation(processID int, quotationID int) error
-	LinkSalesOrder(processID int, salesOrderID int) error
-	LinkPurchaseOrder(processID int, purchaseOrderID int) error
-	LinkDelivery(processID int, deliveryID int) error
-	LinkInvoice(processID int, invoiceID int) error
+	InitiateFromQuotation(ctx context.Context, quotationID int) (*models.SalesProcess, error)
+	LinkQuotation(ctx context.Context, processID int, quotationID int) error
+	LinkSalesOrder(ctx context.Context, processID int, salesOrderID int) error
+	LinkPurchaseOrder(ctx context.Context, processID int, purchaseOrderID int) error
+	LinkDelivery(ctx context.Context, processID int, deliveryID int) error
+	LinkInvoice(ctx context.Context, processID int, invoiceID int) error
 
 	// Status transitions
-	UpdateProcessStatus(id int, status string) error
-	CalculateProfitability(id int) error
+	UpdateProcessStatus(ctx context.Context, id int, status string) error
+	CalculateProfitability(ctx context.Context, id int) error
+	ProcessIDsByPeriod(ctx context.Context, startDate, endDate time.Time) ([]int, error)
 
 	// Complex queries
-	GetCompleteProcessFlow(id int) (*CompleteProcessFlow, error)
-	GetProcessTimeline(id int) (*ProcessTimeline, error)
-	GetProfitabilityAnalysis(filter SalesProcessFilter) (*ProfitabilityAnalysis, error)
-	GetSalesConversionMetrics(filter SalesProcessFilter) (*SalesConversionMetrics, error)
-	GetProcessesByStage(stage string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
-	GetAbandonedProcesses(days int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetCompleteProcessFlow(ctx context.Context, id int) (*CompleteProcessFlow, error)
+	GetProcessTimeline(ctx context.Context, id int) (*ProcessTimeline, error)
+	GetProfitabilityAnalysis(ctx context.Context, filter SalesProcessFilter) (*ProfitabilityAnalysis, error)
+	GetSalesConversionMetrics(ctx context.Context, filter SalesProcessFilter) (*SalesConversionMetrics, error)
+	GetProcessesByStage(ctx context.Context, stage string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetAbandonedProcesses(ctx context.Context, days int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
 }
 
 // SalesProcessFilter define os filtros para busca avançada
@@ -118,20 +124,13 @@ type ProcessTimeline struct {
 }
 
 // ProcessEvent representa um evento na linha do tempo
-type ProcessEvent struct {
-	Timestamp   time.Time `json:"timestamp"`
-	EventType   string    `json:"event_type"`
-	Description string    `json:"description"`
-	DocumentID  int       `json:"document_id,omitempty"`
-	DocumentNo  string    `json:"document_no,omitempty"`
-	Value       float64   `json:"value,omitempty"`
-}
+type ProcessEvent = models.ProcessEvent
 
 // ProfitabilityAnalysis representa análise de lucratividade
 type ProfitabilityAnalysis struct {
-	TotalRevenue    float64                 `json:"total_revenue"`
-	TotalCosts      float64                 `json:"total_costs"`
-	TotalProfit     float64                 `json:"total_profit"`
+	TotalRevenue    money.Decimal           `json:"total_revenue"`
+	TotalCosts      money.Decimal           `json:"total_costs"`
+	TotalProfit     money.Decimal           `json:"total_profit"`
 	ProfitMargin    float64                 `json:"profit_margin_percentage"`
 	ByProduct       []ProductProfitability  `json:"by_product"`
 	ByCustomer      []CustomerProfitability `json:"by_customer"`
@@ -173,13 +172,17 @@ type PeriodProfitability struct {
 
 // SalesConversionMetrics representa métricas de conversão de vendas
 type SalesConversionMetrics struct {
-	TotalQuotations       int                     `json:"total_quotations"`
-	QuotationToSORate     float64                 `json:"quotation_to_so_rate"`
-	SOToInvoiceRate       float64                 `json:"so_to_invoice_rate"`
-	InvoiceToPaymentRate  float64                 `json:"invoice_to_payment_rate"`
-	OverallConversionRate float64                 `json:"overall_conversion_rate"`
-	AverageConversionTime float64                 `json:"average_conversion_time_days"`
-	ByStage               map[string]StageMetrics `json:"by_stage"`
+	TotalLeads                 int                     `json:"total_leads"`
+	TotalOpportunities         int                     `json:"total_opportunities"`
+	LeadToOpportunityRate      float64                 `json:"lead_to_opportunity_rate"`
+	OpportunityToQuotationRate float64                 `json:"opportunity_to_quotation_rate"`
+	TotalQuotations            int                     `json:"total_quotations"`
+	QuotationToSORate          float64                 `json:"quotation_to_so_rate"`
+	SOToInvoiceRate            float64                 `json:"so_to_invoice_rate"`
+	InvoiceToPaymentRate       float64                 `json:"invoice_to_payment_rate"`
+	OverallConversionRate      float64                 `json:"overall_conversion_rate"`
+	AverageConversionTime      float64                 `json:"average_conversion_time_days"`
+	ByStage                    map[string]StageMetrics `json:"by_stage"`
 }
 
 // StageMetrics representa métricas por estágio do processo
@@ -190,6 +193,12 @@ type StageMetrics struct {
 	AbandonmentRate float64 `json:"abandonment_rate"`
 }
 
+// Estágios do CRM anteriores à cotação, incluídos nas métricas de conversão
+const (
+	PipelineStageLead        = "lead"
+	PipelineStageOpportunity = "opportunity"
+)
+
 // ProcessStatus define os status possíveis do processo
 const (
 	ProcessStatusDraft      = "draft"
@@ -205,31 +214,33 @@ const (
 
 type salesProcessRepository struct {
 	db     *gorm.DB
+	reader *gorm.DB // réplica de leitura para as consultas analíticas (ver db.Provider)
 	logger *zap.Logger
 }
 
 // NewSalesProcessRepository cria uma nova instância do repositório
 func NewSalesProcessRepository() (SalesProcessRepository, error) {
-	db, err := db.OpenGormDB()
+	provider, err := db.OpenProvider()
 	if err != nil {
 		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
 	}
 
 	return &salesProcessRepository{
-		db:     db,
+		db:     provider.Writer(),
+		reader: provider.Reader(),
 		logger: logger.WithModule("sales_process_repository"),
 	}, nil
 }
 
 // CreateSalesProcess cria um novo sales process no banco
-func (r *salesProcessRepository) CreateSalesProcess(salesProcess *models.SalesProcess) error {
+func (r *salesProcessRepository) CreateSalesProcess(ctx context.Context, salesProcess *models.SalesProcess) error {
 	// Define status padrão se não foi fornecido
 	if salesProcess.Status == "" {
 		salesProcess.Status = ProcessStatusDraft
 	}
 
 	// Cria o sales process
-	if err := r.db.Create(salesProcess).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Create(salesProcess).Error; err != nil {
 		r.logger.Error("erro ao criar sales process", zap.Error(err))
 		return errors.WrapError(err, "falha ao criar sales process")
 	}
@@ -238,11 +249,11 @@ func (r *salesProcessRepository) CreateSalesProcess(salesProcess *models.SalesPr
 	return nil
 }
 
-// GetSalesProcessByID busca um sales process pelo ID
-func (r *salesProcessRepository) GetSalesProcessByID(id int) (*models.SalesProcess, error) {
+// GetSalesProcessByID busca um sales process pelo ID com os documentos relacionados
+func (r *salesProcessRepository) GetSalesProcessByID(ctx context.Context, id int) (*models.SalesProcess, error) {
 	var salesProcess models.SalesProcess
 
-	query := r.db.Preload("Contact")
+	query := db.Conn(ctx, r.db).Preload("Contact")
 
 	if err := query.First(&salesProcess, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -253,20 +264,21 @@ func (r *salesProcessRepository) GetSalesProcessByID(id int) (*models.SalesProce
 	}
 
 	// Carrega os documentos relacionados
-	if err := r.loadRelatedDocuments(&salesProcess); err != nil {
+	processes := []models.SalesProcess{salesProcess}
+	if err := r.loadRelatedDocuments(ctx, processes); err != nil {
 		r.logger.Warn("erro ao carregar documentos relacionados", zap.Error(err))
 	}
 
-	return &salesProcess, nil
+	return &processes[0], nil
 }
 
-// GetAllSalesProcesses retorna todos os sales processes com paginação
-func (r *salesProcessRepository) GetAllSalesProcesses(params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+// GetAllSalesProcesses retorna todos os sales processes com paginação e os documentos relacionados
+func (r *salesProcessRepository) GetAllSalesProcesses(ctx context.Context, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var salesProcesses []models.SalesProcess
 	var total int64
 
 	// Query base
-	query := r.db.Model(&models.SalesProcess{})
+	query := db.Conn(ctx, r.db).Model(&models.SalesProcess{})
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -285,15 +297,20 @@ func (r *salesProcessRepository) GetAllSalesProcesses(params *pagination.Paginat
 		return nil, errors.WrapError(err, "falha ao buscar sales processes")
 	}
 
+	// Documentos relacionados de toda a página, com uma query por tipo de documento
+	if err := r.loadRelatedDocuments(ctx, salesProcesses); err != nil {
+		r.logger.Warn("erro ao carregar documentos relacionados", zap.Error(err))
+	}
+
 	result := pagination.NewPaginatedResult(total, params.Page, params.PageSize, salesProcesses)
 	return result, nil
 }
 
 // UpdateSalesProcess atualiza um sales process existente
-func (r *salesProcessRepository) UpdateSalesProcess(id int, salesProcess *models.SalesProcess) error {
+func (r *salesProcessRepository) UpdateSalesProcess(ctx context.Context, id int, salesProcess *models.SalesProcess) error {
 	// Verifica se o sales process existe
 	var existing models.SalesProcess
-	if err := r.db.First(&existing, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesProcessNotFound
 		}
@@ -302,7 +319,7 @@ func (r *salesProcessRepository) UpdateSalesProcess(id int, salesProcess *models
 
 	// Atualiza os campos
 	salesProcess.ID = id
-	if err := r.db.Save(salesProcess).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(salesProcess).Error; err != nil {
 		r.logger.Error("erro ao atualizar sales process", zap.Error(err), zap.Int("id", id))
 		return errors.WrapError(err, "falha ao atualizar sales process")
 	}
@@ -312,10 +329,10 @@ func (r *salesProcessRepository) UpdateSalesProcess(id int, salesProcess *models
 }
 
 // DeleteSalesProcess remove um sales process
-func (r *salesProcessRepository) DeleteSalesProcess(id int) error {
+func (r *salesProcessRepository) DeleteSalesProcess(ctx context.Context, id int) error {
 	// Verifica se o sales process existe
 	var existing models.SalesProcess
-	if err := r.db.First(&existing, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&existing, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesProcessNotFound
 		}
@@ -328,7 +345,7 @@ func (r *salesProcessRepository) DeleteSalesProcess(id int) error {
 	}
 
 	// Remove o sales process
-	result := r.db.Delete(&models.SalesProcess{}, id)
+	result := db.Conn(ctx, r.db).Delete(&models.SalesProcess{}, id)
 	if result.Error != nil {
 		r.logger.Error("erro ao deletar sales process", zap.Error(result.Error), zap.Int("id", id))
 		return errors.WrapError(result.Error, "falha ao deletar sales process")
@@ -343,11 +360,11 @@ func (r *salesProcessRepository) DeleteSalesProcess(id int) error {
 }
 
 // GetSalesProcessesByStatus busca sales processes por status
-func (r *salesProcessRepository) GetSalesProcessesByStatus(status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *salesProcessRepository) GetSalesProcessesByStatus(ctx context.Context, status string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var salesProcesses []models.SalesProcess
 	var total int64
 
-	query := r.db.Model(&models.SalesProcess{}).Where("status = ?", status)
+	query := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).Where("status = ?", status)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -371,11 +388,11 @@ func (r *salesProcessRepository) GetSalesProcessesByStatus(status string, params
 }
 
 // GetSalesProcessesByContact busca sales processes por contato
-func (r *salesProcessRepository) GetSalesProcessesByContact(contactID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *salesProcessRepository) GetSalesProcessesByContact(ctx context.Context, contactID int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var salesProcesses []models.SalesProcess
 	var total int64
 
-	query := r.db.Model(&models.SalesProcess{}).Where("contact_id = ?", contactID)
+	query := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).Where("contact_id = ?", contactID)
 
 	// Conta o total
 	if err := query.Count(&total).Error; err != nil {
@@ -399,11 +416,11 @@ func (r *salesProcessRepository) GetSalesProcessesByContact(contactID int, param
 }
 
 // GetSalesProcessesByPeriod busca sales processes por período
-func (r *salesProcessRepository) GetSalesProcessesByPeriod(startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *salesProcessRepository) GetSalesProcessesByPeriod(ctx context.Context, startDate, endDate time.Time, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var salesProcesses []models.SalesProcess
 	var total int64
 
-	query := r.db.Model(&models.SalesProcess{}).
+	query := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).
 		Where("created_at >= ? AND created_at <= ?", startDate, endDate)
 
 	// Conta o total
@@ -428,11 +445,11 @@ func (r *salesProcessRepository) GetSalesProcessesByPeriod(startDate, endDate ti
 }
 
 // SearchSalesProcesses busca sales processes com filtros combinados
-func (r *salesProcessRepository) SearchSalesProcesses(filter SalesProcessFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *salesProcessRepository) SearchSalesProcesses(ctx context.Context, filter SalesProcessFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var salesProcesses []models.SalesProcess
 	var total int64
 
-	query := r.db.Model(&models.SalesProcess{})
+	query := db.Conn(ctx, r.db).Model(&models.SalesProcess{})
 
 	// Aplica os filtros
 	if len(filter.Status) > 0 {
@@ -445,7 +462,7 @@ func (r *salesProcessRepository) SearchSalesProcesses(filter SalesProcessFilter,
 
 	// Filtro por tipo de contato
 	if filter.ContactType != "" {
-		contactQuery := r.db.Model(&contact.Contact{}).Select("id").Where("type = ?", filter.ContactType)
+		contactQuery := db.Conn(ctx, r.db).Model(&contact.Contact{}).Select("id").Where("type = ?", filter.ContactType)
 		query = query.Where("contact_id IN (?)", contactQuery)
 	}
 
@@ -511,12 +528,12 @@ func (r *salesProcessRepository) SearchSalesProcesses(filter SalesProcessFilter,
 }
 
 // GetSalesProcessStats retorna estatísticas de sales processes
-func (r *salesProcessRepository) GetSalesProcessStats(filter SalesProcessFilter) (*SalesProcessStats, error) {
+func (r *salesProcessRepository) GetSalesProcessStats(ctx context.Context, filter SalesProcessFilter) (*SalesProcessStats, error) {
 	stats := &SalesProcessStats{
 		CountByStatus: make(map[string]int),
 	}
 
-	query := r.db.Model(&models.SalesProcess{})
+	query := r.reader.WithContext(ctx).Model(&models.SalesProcess{})
 
 	// Aplica filtros básicos
 	if filter.ContactID > 0 {
@@ -583,9 +600,9 @@ func (r *salesProcessRepository) GetSalesProcessStats(filter SalesProcessFilter)
 	var avgCycleTime struct {
 		AvgDays float64
 	}
-	if err := r.db.Model(&models.SalesProcess{}).
+	if err := r.reader.WithContext(ctx).Model(&models.SalesProcess{}).
 		Where("status = ?", ProcessStatusCompleted).
-		Select("AVG(JULIANDAY(updated_at) - JULIANDAY(created_at)) as avg_days").
+		Select(sqlexpr.AvgDaysBetween(r.reader, "created_at", "updated_at") + " as avg_days").
 		Scan(&avgCycleTime).Error; err == nil {
 		stats.AverageCycleTime = avgCycleTime.AvgDays
 	}
@@ -594,14 +611,14 @@ func (r *salesProcessRepository) GetSalesProcessStats(filter SalesProcessFilter)
 }
 
 // GetContactSalesProcessSummary retorna um resumo dos processos de um contato
-func (r *salesProcessRepository) GetContactSalesProcessSummary(contactID int) (*ContactSalesProcessSummary, error) {
+func (r *salesProcessRepository) GetContactSalesProcessSummary(ctx context.Context, contactID int) (*ContactSalesProcessSummary, error) {
 	summary := &ContactSalesProcessSummary{
 		ContactID: contactID,
 	}
 
 	// Busca informações do contato
 	var contact contact.Contact
-	if err := r.db.First(&contact, contactID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&contact, contactID).Error; err != nil {
 		return nil, errors.WrapError(err, "falha ao buscar contato")
 	}
 
@@ -619,7 +636,7 @@ func (r *salesProcessRepository) GetContactSalesProcessSummary(contactID int) (*
 		AvgValue    float64
 	}
 
-	if err := r.db.Model(&models.SalesProcess{}).
+	if err := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).
 		Where("contact_id = ?", contactID).
 		Select("COUNT(*) as count, SUM(total_value) as total_value, SUM(profit) as total_profit, AVG(total_value) as avg_value").
 		Scan(&stats).Error; err != nil {
@@ -633,7 +650,7 @@ func (r *salesProcessRepository) GetContactSalesProcessSummary(contactID int) (*
 
 	// Conta processos ativos e completos
 	var activeCount int64
-	if err := r.db.Model(&models.SalesProcess{}).
+	if err := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).
 		Where("contact_id = ? AND status NOT IN ?", contactID, []string{ProcessStatusCompleted, ProcessStatusCancelled}).
 		Count(&activeCount).Error; err != nil {
 		r.logger.Warn("erro ao contar processos ativos", zap.Error(err))
@@ -641,7 +658,7 @@ func (r *salesProcessRepository) GetContactSalesProcessSummary(contactID int) (*
 	summary.ActiveProcesses = int(activeCount)
 
 	var completedCount int64
-	if err := r.db.Model(&models.SalesProcess{}).
+	if err := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).
 		Where("contact_id = ? AND status = ?", contactID, ProcessStatusCompleted).
 		Count(&completedCount).Error; err != nil {
 		r.logger.Warn("erro ao contar processos completos", zap.Error(err))
@@ -655,7 +672,7 @@ func (r *salesProcessRepository) GetContactSalesProcessSummary(contactID int) (*
 
 	// Último processo
 	var lastProcess models.SalesProcess
-	if err := r.db.Model(&models.SalesProcess{}).
+	if err := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).
 		Where("contact_id = ?", contactID).
 		Order("created_at DESC").
 		First(&lastProcess).Error; err == nil {
@@ -666,10 +683,10 @@ func (r *salesProcessRepository) GetContactSalesProcessSummary(contactID int) (*
 }
 
 // InitiateFromQuotation inicia um processo a partir de uma cotação
-func (r *salesProcessRepository) InitiateFromQuotation(quotationID int) (*models.SalesProcess, error) {
+func (r *salesProcessRepository) InitiateFromQuotation(ctx context.Context, quotationID int) (*models.SalesProcess, error) {
 	// Busca a quotation
 	var quotation models.Quotation
-	if err := r.db.Preload("Contact").First(&quotation, quotationID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Preload("Contact").First(&quotation, quotationID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.ErrQuotationNotFound
 		}
@@ -685,7 +702,7 @@ func (r *salesProcessRepository) InitiateFromQuotation(quotationID int) (*models
 	}
 
 	// Inicia transação
-	tx := r.db.Begin()
+	tx := db.Conn(ctx, r.db).Begin()
 
 	// Cria o processo
 	if err := tx.Create(process).Error; err != nil {
@@ -709,10 +726,10 @@ func (r *salesProcessRepository) InitiateFromQuotation(quotationID int) (*models
 }
 
 // LinkQuotation vincula uma quotation ao processo
-func (r *salesProcessRepository) LinkQuotation(processID int, quotationID int) error {
+func (r *salesProcessRepository) LinkQuotation(ctx context.Context, processID int, quotationID int) error {
 	// Verifica se o processo existe
 	var process models.SalesProcess
-	if err := r.db.First(&process, processID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&process, processID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesProcessNotFound
 		}
@@ -721,7 +738,7 @@ func (r *salesProcessRepository) LinkQuotation(processID int, quotationID int) e
 
 	// Verifica se a quotation existe
 	var quotation models.Quotation
-	if err := r.db.First(&quotation, quotationID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&quotation, quotationID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrQuotationNotFound
 		}
@@ -732,7 +749,7 @@ func (r *salesProcessRepository) LinkQuotation(processID int, quotationID int) e
 	process.Status = ProcessStatusQuotation
 	process.TotalValue = quotation.GrandTotal
 
-	if err := r.db.Save(&process).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&process).Error; err != nil {
 		return errors.WrapError(err, "falha ao atualizar processo")
 	}
 
@@ -744,10 +761,10 @@ func (r *salesProcessRepository) LinkQuotation(processID int, quotationID int) e
 }
 
 // LinkSalesOrder vincula um sales order ao processo
-func (r *salesProcessRepository) LinkSalesOrder(processID int, salesOrderID int) error {
+func (r *salesProcessRepository) LinkSalesOrder(ctx context.Context, processID int, salesOrderID int) error {
 	// Verifica se o processo existe
 	var process models.SalesProcess
-	if err := r.db.First(&process, processID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&process, processID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesProcessNotFound
 		}
@@ -756,7 +773,7 @@ func (r *salesProcessRepository) LinkSalesOrder(processID int, salesOrderID int)
 
 	// Verifica se o sales order existe
 	var salesOrder models.SalesOrder
-	if err := r.db.First(&salesOrder, salesOrderID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&salesOrder, salesOrderID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesOrderNotFound
 		}
@@ -767,7 +784,7 @@ func (r *salesProcessRepository) LinkSalesOrder(processID int, salesOrderID int)
 	process.Status = ProcessStatusSalesOrder
 	process.TotalValue = salesOrder.GrandTotal
 
-	if err := r.db.Save(&process).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&process).Error; err != nil {
 		return errors.WrapError(err, "falha ao atualizar processo")
 	}
 
@@ -779,10 +796,10 @@ func (r *salesProcessRepository) LinkSalesOrder(processID int, salesOrderID int)
 }
 
 // LinkPurchaseOrder vincula um purchase order ao processo
-func (r *salesProcessRepository) LinkPurchaseOrder(processID int, purchaseOrderID int) error {
+func (r *salesProcessRepository) LinkPurchaseOrder(ctx context.Context, processID int, purchaseOrderID int) error {
 	// Verifica se o processo existe
 	var process models.SalesProcess
-	if err := r.db.First(&process, processID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&process, processID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesProcessNotFound
 		}
@@ -791,7 +808,7 @@ func (r *salesProcessRepository) LinkPurchaseOrder(processID int, purchaseOrderI
 
 	// Verifica se o purchase order existe
 	var purchaseOrder models.PurchaseOrder
-	if err := r.db.First(&purchaseOrder, purchaseOrderID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&purchaseOrder, purchaseOrderID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrPurchaseOrderNotFound
 		}
@@ -805,9 +822,9 @@ func (r *salesProcessRepository) LinkPurchaseOrder(processID int, purchaseOrderI
 
 	// Calcula o custo (simplificado - você pode melhorar isso)
 	cost := purchaseOrder.GrandTotal
-	process.Profit = process.TotalValue - cost
+	process.Profit = process.TotalValue.Sub(cost)
 
-	if err := r.db.Save(&process).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&process).Error; err != nil {
 		return errors.WrapError(err, "falha ao atualizar processo")
 	}
 
@@ -819,10 +836,10 @@ func (r *salesProcessRepository) LinkPurchaseOrder(processID int, purchaseOrderI
 }
 
 // LinkDelivery vincula uma delivery ao processo
-func (r *salesProcessRepository) LinkDelivery(processID int, deliveryID int) error {
+func (r *salesProcessRepository) LinkDelivery(ctx context.Context, processID int, deliveryID int) error {
 	// Verifica se o processo existe
 	var process models.SalesProcess
-	if err := r.db.First(&process, processID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&process, processID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesProcessNotFound
 		}
@@ -831,7 +848,7 @@ func (r *salesProcessRepository) LinkDelivery(processID int, deliveryID int) err
 
 	// Verifica se a delivery existe
 	var delivery models.Delivery
-	if err := r.db.First(&delivery, deliveryID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&delivery, deliveryID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrDeliveryNotFound
 		}
@@ -843,7 +860,7 @@ func (r *salesProcessRepository) LinkDelivery(processID int, deliveryID int) err
 		process.Status = ProcessStatusDelivery
 	}
 
-	if err := r.db.Save(&process).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&process).Error; err != nil {
 		return errors.WrapError(err, "falha ao atualizar processo")
 	}
 
@@ -855,10 +872,10 @@ func (r *salesProcessRepository) LinkDelivery(processID int, deliveryID int) err
 }
 
 // LinkInvoice vincula uma invoice ao processo
-func (r *salesProcessRepository) LinkInvoice(processID int, invoiceID int) error {
+func (r *salesProcessRepository) LinkInvoice(ctx context.Context, processID int, invoiceID int) error {
 	// Verifica se o processo existe
 	var process models.SalesProcess
-	if err := r.db.First(&process, processID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&process, processID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesProcessNotFound
 		}
@@ -867,7 +884,7 @@ func (r *salesProcessRepository) LinkInvoice(processID int, invoiceID int) error
 
 	// Verifica se a invoice existe
 	var invoice models.Invoice
-	if err := r.db.First(&invoice, invoiceID).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&invoice, invoiceID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrInvoiceNotFound
 		}
@@ -878,11 +895,11 @@ func (r *salesProcessRepository) LinkInvoice(processID int, invoiceID int) error
 	process.Status = ProcessStatusInvoicing
 
 	// Verifica se está totalmente paga
-	if invoice.AmountPaid >= invoice.GrandTotal {
+	if invoice.AmountPaid.GreaterThanOrEqual(invoice.GrandTotal) {
 		process.Status = ProcessStatusCompleted
 	}
 
-	if err := r.db.Save(&process).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(&process).Error; err != nil {
 		return errors.WrapError(err, "falha ao atualizar processo")
 	}
 
@@ -894,19 +911,29 @@ func (r *salesProcessRepository) LinkInvoice(processID int, invoiceID int) error
 }
 
 // UpdateProcessStatus atualiza o status de um processo
-func (r *salesProcessRepository) UpdateProcessStatus(id int, status string) error {
+func (r *salesProcessRepository) UpdateProcessStatus(ctx context.Context, id int, status string) error {
 	// Verifica se o processo existe
 	var process models.SalesProcess
-	if err := r.db.First(&process, id).Error; err != nil {
+	if err := db.Conn(ctx, r.db).First(&process, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrSalesProcessNotFound
 		}
 		return errors.WrapError(err, "falha ao buscar processo")
 	}
 
-	// Atualiza o status
+	// Atualiza o status e registra a mudança na linha do tempo
+	previous := process.Status
 	process.Status = status
-	if err := r.db.Save(&process).Error; err != nil {
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&process).Error; err != nil {
+			return err
+		}
+		if previous == status {
+			return nil
+		}
+		return RecordProcessEvent(tx, id, StatusChangedEvent(previous, status, time.Now()))
+	})
+	if err != nil {
 		r.logger.Error("erro ao atualizar status do processo", zap.Error(err), zap.Int("id", id), zap.String("status", status))
 		return errors.WrapError(err, "falha ao atualizar status do processo")
 	}
@@ -916,109 +943,155 @@ func (r *salesProcessRepository) UpdateProcessStatus(id int, status string) erro
 }
 
 // CalculateProfitability calcula a lucratividade de um processo
-func (r *salesProcessRepository) CalculateProfitability(id int) error {
+func (r *salesProcessRepository) CalculateProfitability(ctx context.Context, id int) error {
 	// Busca o processo com todos os documentos relacionados
-	process, err := r.GetCompleteProcessFlow(id)
+	process, err := r.GetCompleteProcessFlow(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// Calcula receita (invoices)
-	var revenue float64
+	var revenue money.Decimal
 	for _, invoice := range process.Invoices {
-		revenue += invoice.GrandTotal
+		revenue = revenue.Add(invoice.GrandTotal)
 	}
 
-	// Calcula custos (purchase orders)
-	var costs float64
-	for _, po := range process.PurchaseOrders {
-		costs += po.GrandTotal
+	// Calcula custos pelo CMV dos itens vendidos mais o frete escolhido no pedido
+	var costs money.Decimal
+	if process.SalesOrder != nil && process.SalesOrder.ID > 0 {
+		costs, err = r.calculateCostOfGoodsSold(ctx, process.SalesOrder.ID)
+		if err != nil {
+			return err
+		}
+		costs = costs.Add(process.SalesOrder.ShippingCost)
 	}
 
 	// Atualiza o processo
 	process.Process.TotalValue = revenue
-	process.Process.Profit = revenue - costs
+	process.Process.Profit = revenue.Sub(costs)
 
-	if err := r.db.Save(process.Process).Error; err != nil {
+	if err := db.Conn(ctx, r.db).Save(process.Process).Error; err != nil {
 		return errors.WrapError(err, "falha ao atualizar lucratividade")
 	}
 
 	r.logger.Info("lucratividade calculada",
 		zap.Int("process_id", id),
-		zap.Float64("revenue", revenue),
-		zap.Float64("costs", costs),
-		zap.Float64("profit", process.Process.Profit))
+		zap.Stringer("revenue", revenue),
+		zap.Stringer("costs", costs),
+		zap.Stringer("profit", process.Process.Profit))
 
 	return nil
 }
 
-// GetCompleteProcessFlow retorna o fluxo completo de um processo
-func (r *salesProcessRepository) GetCompleteProcessFlow(id int) (*CompleteProcessFlow, error) {
-	flow := &CompleteProcessFlow{
-		Timeline: make([]ProcessEvent, 0),
+// ProcessIDsByPeriod lista, em ordem, os processos criados entre as datas (fim exclusivo)
+func (r *salesProcessRepository) ProcessIDsByPeriod(ctx context.Context, startDate, endDate time.Time) ([]int, error) {
+	var ids []int
+	err := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).
+		Where("created_at >= ? AND created_at < ?", startDate, endDate).
+		Order("id ASC").Pluck("id", &ids).Error
+	if err != nil {
+		r.logger.Error("erro ao listar processos do período", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar processos do período")
 	}
+	return ids, nil
+}
 
-	// Busca o processo
-	process, err := r.GetSalesProcessByID(id)
+// calculateCostOfGoodsSold retorna o CMV apurado do pedido de venda. Enquanto não houver
+// custo apurado, estima pelos itens faturados ao custo médio (ou de cadastro) do produto.
+func (r *salesProcessRepository) calculateCostOfGoodsSold(ctx context.Context, salesOrderID int) (money.Decimal, error) {
+	orderCompany, err := tenant.Condition(ctx, "so")
 	if err != nil {
-		return nil, err
+		return money.Zero, err
+	}
+	invoiceCompany, err := tenant.Condition(ctx, "i")
+	if err != nil {
+		return money.Zero, err
 	}
-	flow.Process = process
 
-	// Carrega todos os documentos relacionados
-	// Nota: Em um cenário real, você precisaria de tabelas de relacionamento
-	// ou campos de process_id em cada modelo para fazer essas queries
+	var result struct {
+		Entries int64
+		Total   money.Decimal
+	}
 
-	// Busca quotations do contato (simplificado)
-	if err := r.db.Where("contact_id = ?", process.ContactID).
-		Order("created_at DESC").
-		First(&flow.Quotation).Error; err != nil && err != gorm.ErrRecordNotFound {
-		r.logger.Warn("erro ao buscar quotation", zap.Error(err))
+	if err := db.Conn(ctx, r.db).Raw(`
+		SELECT COUNT(*) AS entries, COALESCE(SUM(ce.total_cost), 0) AS total
+		FROM cogs_entries ce
+		JOIN sales_orders so ON so.id = ce.sales_order_id
+		WHERE ce.sales_order_id = ? AND `+orderCompany, salesOrderID).Scan(&result).Error; err != nil {
+		return money.Zero, errors.WrapError(err, "falha ao buscar CMV do pedido")
 	}
 
-	// Busca sales orders
-	if err := r.db.Where("contact_id = ?", process.ContactID).
-		Order("created_at DESC").
-		First(&flow.SalesOrder).Error; err != nil && err != gorm.ErrRecordNotFound {
-		r.logger.Warn("erro ao buscar sales order", zap.Error(err))
+	if result.Entries > 0 {
+		return result.Total, nil
 	}
 
-	// Busca purchase orders
-	if err := r.db.Where("sales_order_id = ?", flow.SalesOrder.ID).
-		Find(&flow.PurchaseOrders).Error; err != nil {
-		r.logger.Warn("erro ao buscar purchase orders", zap.Error(err))
+	var estimated money.Decimal
+	if err := db.Conn(ctx, r.db).Raw(`
+		SELECT COALESCE(SUM(ii.quantity * COALESCE(pc.average_cost, p.cost_price, 0)), 0)
+		FROM invoice_items ii
+		JOIN invoices i ON i.id = ii.invoice_id
+		LEFT JOIN product_costing pc ON pc.product_id = ii.product_id
+		LEFT JOIN products p ON p.id = ii.product_id
+		WHERE i.sales_order_id = ? AND i.status NOT IN ('draft', 'cancelled') AND `+invoiceCompany, salesOrderID).
+		Scan(&estimated).Error; err != nil {
+		return money.Zero, errors.WrapError(err, "falha ao estimar custo dos itens faturados")
 	}
 
-	// Busca deliveries
-	if err := r.db.Where("sales_order_id = ?", flow.SalesOrder.ID).
-		Find(&flow.Deliveries).Error; err != nil {
-		r.logger.Warn("erro ao buscar deliveries", zap.Error(err))
+	return estimated, nil
+}
+
+// GetCompleteProcessFlow retorna o fluxo completo de um processo
+func (r *salesProcessRepository) GetCompleteProcessFlow(ctx context.Context, id int) (*CompleteProcessFlow, error) {
+	flow := &CompleteProcessFlow{
+		Timeline: make([]ProcessEvent, 0),
 	}
 
-	// Busca invoices
-	if err := r.db.Where("sales_order_id = ?", flow.SalesOrder.ID).
-		Find(&flow.Invoices).Error; err != nil {
-		r.logger.Warn("erro ao buscar invoices", zap.Error(err))
+	// Busca o processo já com cotação, sales order, deliveries e invoices
+	process, err := r.GetSalesProcessByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	flow.Process = process
+	flow.Quotation = process.Quotation
+	flow.SalesOrder = process.SalesOrder
+	flow.Deliveries = process.Deliveries
+	flow.Invoices = process.Invoices
+
+	// Busca todas as purchase orders do sales order
+	if flow.SalesOrder != nil {
+		if err := db.Conn(ctx, r.db).Where("sales_order_id = ?", flow.SalesOrder.ID).
+			Order("id").
+			Find(&flow.PurchaseOrders).Error; err != nil {
+			r.logger.Warn("erro ao buscar purchase orders", zap.Error(err))
+		}
 	}
 
-	// Busca payments
-	for _, invoice := range flow.Invoices {
-		var payments []models.Payment
-		if err := r.db.Where("invoice_id = ?", invoice.ID).
-			Find(&payments).Error; err == nil {
-			flow.Payments = append(flow.Payments, payments...)
+	// Busca os payments de todas as invoices de uma vez
+	if len(flow.Invoices) > 0 {
+		invoiceIDs := make([]int, 0, len(flow.Invoices))
+		for _, invoice := range flow.Invoices {
+			invoiceIDs = append(invoiceIDs, invoice.ID)
+		}
+		if err := db.Conn(ctx, r.db).Where("invoice_id IN ?", invoiceIDs).
+			Order("id").
+			Find(&flow.Payments).Error; err != nil {
+			r.logger.Warn("erro ao buscar payments", zap.Error(err))
 		}
 	}
 
-	// Monta a timeline
-	flow.Timeline = r.buildTimeline(flow)
+	// Linha do tempo gravada, em ordem cronológica
+	timeline, err := processEvents(db.Conn(ctx, r.db), id)
+	if err != nil {
+		return nil, err
+	}
+	flow.Timeline = timeline
 
 	return flow, nil
 }
 
 // GetProcessTimeline retorna a linha do tempo de um processo
-func (r *salesProcessRepository) GetProcessTimeline(id int) (*ProcessTimeline, error) {
-	flow, err := r.GetCompleteProcessFlow(id)
+func (r *salesProcessRepository) GetProcessTimeline(ctx context.Context, id int) (*ProcessTimeline, error) {
+	flow, err := r.GetCompleteProcessFlow(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -1041,7 +1114,7 @@ func (r *salesProcessRepository) GetProcessTimeline(id int) (*ProcessTimeline, e
 }
 
 // GetProfitabilityAnalysis retorna análise de lucratividade
-func (r *salesProcessRepository) GetProfitabilityAnalysis(filter SalesProcessFilter) (*ProfitabilityAnalysis, error) {
+func (r *salesProcessRepository) GetProfitabilityAnalysis(ctx context.Context, filter SalesProcessFilter) (*ProfitabilityAnalysis, error) {
 	analysis := &ProfitabilityAnalysis{
 		ByProduct:  make([]ProductProfitability, 0),
 		ByCustomer: make([]CustomerProfitability, 0),
@@ -1049,15 +1122,15 @@ func (r *salesProcessRepository) GetProfitabilityAnalysis(filter SalesProcessFil
 	}
 
 	// Query base com filtros
-	query := r.db.Model(&models.SalesProcess{})
+	query := r.reader.WithContext(ctx).Model(&models.SalesProcess{})
 	if !filter.DateRangeStart.IsZero() && !filter.DateRangeEnd.IsZero() {
 		query = query.Where("created_at >= ? AND created_at <= ?", filter.DateRangeStart, filter.DateRangeEnd)
 	}
 
 	// Totais gerais
 	var totals struct {
-		Revenue float64
-		Profit  float64
+		Revenue money.Decimal
+		Profit  money.Decimal
 	}
 	if err := query.Select("SUM(total_value) as revenue, SUM(profit) as profit").
 		Scan(&totals).Error; err != nil {
@@ -1066,10 +1139,10 @@ func (r *salesProcessRepository) GetProfitabilityAnalysis(filter SalesProcessFil
 
 	analysis.TotalRevenue = totals.Revenue
 	analysis.TotalProfit = totals.Profit
-	analysis.TotalCosts = totals.Revenue - totals.Profit
+	analysis.TotalCosts = totals.Revenue.Sub(totals.Profit)
 
-	if analysis.TotalRevenue > 0 {
-		analysis.ProfitMargin = (analysis.TotalProfit / analysis.TotalRevenue) * 100
+	if analysis.TotalRevenue.IsPositive() {
+		analysis.ProfitMargin = analysis.TotalProfit.Div(analysis.TotalRevenue).Float64() * 100
 	}
 
 	// Por cliente
@@ -1114,13 +1187,13 @@ func (r *salesProcessRepository) GetProfitabilityAnalysis(filter SalesProcessFil
 }
 
 // GetSalesConversionMetrics retorna métricas de conversão
-func (r *salesProcessRepository) GetSalesConversionMetrics(filter SalesProcessFilter) (*SalesConversionMetrics, error) {
+func (r *salesProcessRepository) GetSalesConversionMetrics(ctx context.Context, filter SalesProcessFilter) (*SalesConversionMetrics, error) {
 	metrics := &SalesConversionMetrics{
 		ByStage: make(map[string]StageMetrics),
 	}
 
 	// Query base
-	query := r.db.Model(&models.SalesProcess{})
+	query := r.reader.WithContext(ctx).Model(&models.SalesProcess{})
 	if !filter.DateRangeStart.IsZero() && !filter.DateRangeEnd.IsZero() {
 		query = query.Where("created_at >= ? AND created_at <= ?", filter.DateRangeStart, filter.DateRangeEnd)
 	}
@@ -1130,6 +1203,11 @@ func (r *salesProcessRepository) GetSalesConversionMetrics(filter SalesProcessFi
 	query.Count(&totalProcesses)
 	metrics.TotalQuotations = int(totalProcesses)
 
+	// Estágios anteriores à cotação: leads e oportunidades do CRM
+	if err := r.collectPipelineMetrics(ctx, filter, metrics); err != nil {
+		return nil, err
+	}
+
 	// Conta por estágio
 	stages := []string{
 		ProcessStatusQuotation,
@@ -1187,9 +1265,9 @@ func (r *salesProcessRepository) GetSalesConversionMetrics(filter SalesProcessFi
 	var avgCycleTime struct {
 		AvgDays float64
 	}
-	if err := r.db.Model(&models.SalesProcess{}).
+	if err := r.reader.WithContext(ctx).Model(&models.SalesProcess{}).
 		Where("status = ?", ProcessStatusCompleted).
-		Select("AVG(JULIANDAY(updated_at) - JULIANDAY(created_at)) as avg_days").
+		Select(sqlexpr.AvgDaysBetween(r.reader, "created_at", "updated_at") + " as avg_days").
 		Scan(&avgCycleTime).Error; err == nil {
 		metrics.AverageConversionTime = avgCycleTime.AvgDays
 	}
@@ -1197,8 +1275,58 @@ func (r *salesProcessRepository) GetSalesConversionMetrics(filter SalesProcessFi
 	return metrics, nil
 }
 
+// collectPipelineMetrics calcula as conversões do CRM (lead → oportunidade → cotação)
+func (r *salesProcessRepository) collectPipelineMetrics(ctx context.Context, filter SalesProcessFilter, metrics *SalesConversionMetrics) error {
+	inPeriod := func(table string) *gorm.DB {
+		query := r.reader.WithContext(ctx).Table(table).Scopes(tenant.Scope(ctx, table))
+		if !filter.DateRangeStart.IsZero() && !filter.DateRangeEnd.IsZero() {
+			query = query.Where("created_at >= ? AND created_at <= ?", filter.DateRangeStart, filter.DateRangeEnd)
+		}
+		return query
+	}
+
+	var leads, convertedLeads, opportunities, quotedOpportunities int64
+	if err := inPeriod("crm_leads").Count(&leads).Error; err != nil {
+		r.logger.Error("erro ao contar leads", zap.Error(err))
+		return errors.WrapError(err, "falha ao calcular métricas do funil")
+	}
+	if err := inPeriod("crm_leads").Where("status = ?", "converted").Count(&convertedLeads).Error; err != nil {
+		r.logger.Error("erro ao contar leads convertidos", zap.Error(err))
+		return errors.WrapError(err, "falha ao calcular métricas do funil")
+	}
+	if err := inPeriod("crm_opportunities").Count(&opportunities).Error; err != nil {
+		r.logger.Error("erro ao contar oportunidades", zap.Error(err))
+		return errors.WrapError(err, "falha ao calcular métricas do funil")
+	}
+	if err := inPeriod("crm_opportunities").Where("quotation_id IS NOT NULL").Count(&quotedOpportunities).Error; err != nil {
+		r.logger.Error("erro ao contar oportunidades convertidas", zap.Error(err))
+		return errors.WrapError(err, "falha ao calcular métricas do funil")
+	}
+
+	metrics.TotalLeads = int(leads)
+	metrics.TotalOpportunities = int(opportunities)
+
+	leadStage := StageMetrics{Count: int(leads)}
+	if leads > 0 {
+		metrics.LeadToOpportunityRate = (float64(convertedLeads) / float64(leads)) * 100
+		leadStage.ConversionRate = metrics.LeadToOpportunityRate
+		leadStage.AbandonmentRate = 100 - leadStage.ConversionRate
+	}
+	metrics.ByStage[PipelineStageLead] = leadStage
+
+	opportunityStage := StageMetrics{Count: int(opportunities)}
+	if opportunities > 0 {
+		metrics.OpportunityToQuotationRate = (float64(quotedOpportunities) / float64(opportunities)) * 100
+		opportunityStage.ConversionRate = metrics.OpportunityToQuotationRate
+		opportunityStage.AbandonmentRate = 100 - opportunityStage.ConversionRate
+	}
+	metrics.ByStage[PipelineStageOpportunity] = opportunityStage
+
+	return nil
+}
+
 // GetProcessesByStage busca processos por estágio
-func (r *salesProcessRepository) GetProcessesByStage(stage string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+func (r *salesProcessRepository) GetProcessesByStage(ctx context.Context, stage string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	// Mapeia estágio para status
 	statusMap := map[string]string{
 		"quotation":   ProcessStatusQuotation,
@@ -1216,17 +1344,18 @@ func (r *salesProcessRepository) GetProcessesByStage(stage string, params *pagin
 		return nil, errors.WrapError(gorm.ErrInvalidData, "estágio inválido")
 	}
 
-	return r.GetSalesProcessesByStatus(status, params)
+	return r.GetSalesProcessesByStatus(ctx, status, params)
 }
 
-// GetAbandonedProcesses busca processos abandonados
-func (r *salesProcessRepository) GetAbandonedProcesses(days int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+// GetAbandonedProcesses busca processos abandonados: sem atualização desde o início do dia de
+// N dias atrás, no fuso da empresa
+func (r *salesProcessRepository) GetAbandonedProcesses(ctx context.Context, days int, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
 	var salesProcesses []models.SalesProcess
 	var total int64
 
-	cutoffDate := time.Now().AddDate(0, 0, -days)
+	cutoffDate := localtime.DaysAgo(ctx, days)
 
-	query := r.db.Model(&models.SalesProcess{}).
+	query := db.Conn(ctx, r.db).Model(&models.SalesProcess{}).
 		Where("updated_at < ? AND status NOT IN ?", cutoffDate, []string{ProcessStatusCompleted, ProcessStatusCancelled})
 
 	// Conta o total
@@ -1252,136 +1381,95 @@ func (r *salesProcessRepository) GetAbandonedProcesses(days int, params *paginat
 
 // Funções auxiliares privadas
 
-// loadRelatedDocuments carrega os documentos relacionados ao processo
-func (r *salesProcessRepository) loadRelatedDocuments(process *models.SalesProcess) error {
-	// Esta é uma implementação simplificada
-	// Em um cenário real, você precisaria de relacionamentos apropriados no banco
+// loadRelatedDocuments carrega os documentos relacionados de uma lista de processos com
+// cinco queries no total (uma por tipo de documento), qualquer que seja o tamanho da lista.
+// Como não há vínculo direto no banco, cada processo recebe a cotação e o sales order mais
+// recentes do contato e os documentos gerados a partir desse sales order.
+func (r *salesProcessRepository) loadRelatedDocuments(ctx context.Context, processes []models.SalesProcess) error {
+	if len(processes) == 0 {
+		return nil
+	}
+	db := db.Conn(ctx, r.db)
+
+	contactIDs := make([]int, 0, len(processes))
+	seenContacts := make(map[int]bool, len(processes))
+	for _, process := range processes {
+		if !seenContacts[process.ContactID] {
+			seenContacts[process.ContactID] = true
+			contactIDs = append(contactIDs, process.ContactID)
+		}
+	}
 
-	// Carrega quotation
-	if err := r.db.Where("contact_id = ?", process.ContactID).
-		Order("created_at DESC").
-		First(&process.Quotation).Error; err != nil && err != gorm.ErrRecordNotFound {
+	// Cotação e sales order mais recentes de cada contato (DISTINCT ON do PostgreSQL)
+	var quotations []models.Quotation
+	if err := db.Select("DISTINCT ON (contact_id) *").
+		Where("contact_id IN ?", contactIDs).
+		Order("contact_id, created_at DESC").
+		Find(&quotations).Error; err != nil {
 		return err
 	}
 
-	// Carrega sales order
-	if err := r.db.Where("contact_id = ?", process.ContactID).
-		Order("created_at DESC").
-		First(&process.SalesOrder).Error; err != nil && err != gorm.ErrRecordNotFound {
+	var salesOrders []models.SalesOrder
+	if err := db.Select("DISTINCT ON (contact_id) *").
+		Where("contact_id IN ?", contactIDs).
+		Order("contact_id, created_at DESC").
+		Find(&salesOrders).Error; err != nil {
 		return err
 	}
 
-	// Carrega purchase orders
-	if process.SalesOrder != nil {
-		if err := r.db.Where("sales_order_id = ?", process.SalesOrder.ID).
-			Find(&process.PurchaseOrder).Error; err != nil {
-			return err
-		}
+	quotationByContact := make(map[int]*models.Quotation, len(quotations))
+	for i := range quotations {
+		quotationByContact[quotations[i].ContactID] = &quotations[i]
+	}
+	salesOrderByContact := make(map[int]*models.SalesOrder, len(salesOrders))
+	salesOrderIDs := make([]int, 0, len(salesOrders))
+	for i := range salesOrders {
+		salesOrderByContact[salesOrders[i].ContactID] = &salesOrders[i]
+		salesOrderIDs = append(salesOrderIDs, salesOrders[i].ID)
 	}
 
-	// Carrega deliveries
-	if process.SalesOrder != nil {
-		if err := r.db.Where("sales_order_id = ?", process.SalesOrder.ID).
-			Find(&process.Deliveries).Error; err != nil {
+	// Purchase orders, deliveries e invoices dos sales orders encontrados
+	var purchaseOrders []models.PurchaseOrder
+	var deliveries []models.Delivery
+	var invoices []models.Invoice
+	if len(salesOrderIDs) > 0 {
+		if err := db.Where("sales_order_id IN ?", salesOrderIDs).Order("id").Find(&purchaseOrders).Error; err != nil {
 			return err
 		}
-	}
-
-	// Carrega invoices
-	if process.SalesOrder != nil {
-		if err := r.db.Where("sales_order_id = ?", process.SalesOrder.ID).
-			Find(&process.Invoices).Error; err != nil {
+		if err := db.Where("sales_order_id IN ?", salesOrderIDs).Order("id").Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if err := db.Where("sales_order_id IN ?", salesOrderIDs).Order("id").Find(&invoices).Error; err != nil {
 			return err
 		}
 	}
 
-	return nil
-}
-
-// buildTimeline constrói a linha do tempo do processo
-func (r *salesProcessRepository) buildTimeline(flow *CompleteProcessFlow) []ProcessEvent {
-	timeline := make([]ProcessEvent, 0)
-
-	// Adiciona evento de criação do processo
-	timeline = append(timeline, ProcessEvent{
-		Timestamp:   flow.Process.CreatedAt,
-		EventType:   "process_created",
-		Description: "Processo de venda iniciado",
-		DocumentID:  flow.Process.ID,
-	})
+	purchaseOrderBySO := make(map[int]*models.PurchaseOrder, len(purchaseOrders))
+	for i := range purchaseOrders {
+		if _, ok := purchaseOrderBySO[purchaseOrders[i].SalesOrderID]; !ok {
+			purchaseOrderBySO[purchaseOrders[i].SalesOrderID] = &purchaseOrders[i]
+		}
+	}
+	deliveriesBySO := make(map[int][]models.Delivery)
+	for _, delivery := range deliveries {
+		deliveriesBySO[delivery.SalesOrderID] = append(deliveriesBySO[delivery.SalesOrderID], delivery)
+	}
+	invoicesBySO := make(map[int][]models.Invoice)
+	for _, invoice := range invoices {
+		invoicesBySO[invoice.SalesOrderID] = append(invoicesBySO[invoice.SalesOrderID], invoice)
+	}
 
-	// Adiciona evento da quotation
-	if flow.Quotation != nil {
-		timeline = append(timeline, ProcessEvent{
-			Timestamp:   flow.Quotation.CreatedAt,
-			EventType:   "quotation_created",
-			Description: fmt.Sprintf("Cotação %s criada", flow.Quotation.QuotationNo),
-			DocumentID:  flow.Quotation.ID,
-			DocumentNo:  flow.Quotation.QuotationNo,
-			Value:       flow.Quotation.GrandTotal,
-		})
+	for i := range processes {
+		process := &processes[i]
+		process.Quotation = quotationByContact[process.ContactID]
+		process.SalesOrder = salesOrderByContact[process.ContactID]
+		if process.SalesOrder == nil {
+			continue
+		}
+		process.PurchaseOrder = purchaseOrderBySO[process.SalesOrder.ID]
+		process.Deliveries = deliveriesBySO[process.SalesOrder.ID]
+		process.Invoices = invoicesBySO[process.SalesOrder.ID]
 	}
 
-	// Adiciona evento do sales order
-	if flow.SalesOrder != nil {
-		timeline = append(timeline, ProcessEvent{
-			Timestamp:   flow.SalesOrder.CreatedAt,
-			EventType:   "sales_order_created",
-			Description: fmt.Sprintf("Pedido de venda %s criado", flow.SalesOrder.SONo),
-			DocumentID:  flow.SalesOrder.ID,
-			DocumentNo:  flow.SalesOrder.SONo,
-			Value:       flow.SalesOrder.GrandTotal,
-		})
-	}
-
-	// Adiciona eventos de purchase orders
-	for _, po := range flow.PurchaseOrders {
-		timeline = append(timeline, ProcessEvent{
-			Timestamp:   po.CreatedAt,
-			EventType:   "purchase_order_created",
-			Description: fmt.Sprintf("Ordem de compra %s criada", po.PONo),
-			DocumentID:  po.ID,
-			DocumentNo:  po.PONo,
-			Value:       po.GrandTotal,
-		})
-	}
-
-	// Adiciona eventos de deliveries
-	for _, delivery := range flow.Deliveries {
-		timeline = append(timeline, ProcessEvent{
-			Timestamp:   delivery.CreatedAt,
-			EventType:   "delivery_created",
-			Description: fmt.Sprintf("Entrega %s criada", delivery.DeliveryNo),
-			DocumentID:  delivery.ID,
-			DocumentNo:  delivery.DeliveryNo,
-		})
-	}
-
-	// Adiciona eventos de invoices
-	for _, invoice := range flow.Invoices {
-		timeline = append(timeline, ProcessEvent{
-			Timestamp:   invoice.CreatedAt,
-			EventType:   "invoice_created",
-			Description: fmt.Sprintf("Fatura %s criada", invoice.InvoiceNo),
-			DocumentID:  invoice.ID,
-			DocumentNo:  invoice.InvoiceNo,
-			Value:       invoice.GrandTotal,
-		})
-	}
-
-	// Adiciona eventos de payments
-	for _, payment := range flow.Payments {
-		timeline = append(timeline, ProcessEvent{
-			Timestamp:   payment.PaymentDate,
-			EventType:   "payment_received",
-			Description: fmt.Sprintf("Pagamento de %.2f recebido", payment.Amount),
-			DocumentID:  payment.ID,
-			Value:       payment.Amount,
-		})
-	}
-
-	// Ordena a timeline por timestamp
-	// Aqui você usaria um sort.Slice para ordenar
-
-	return timeline
+	return nil
 }
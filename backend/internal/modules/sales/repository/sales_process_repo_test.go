@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db/querystats"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// A listagem de processos carrega os documentos relacionados com um número fixo de queries,
+// qualquer que seja a quantidade de processos na página
+func TestGetAllSalesProcessesQueryBudgetOnPostgres(t *testing.T) {
+	tx := testutils.PostgresTx(t)
+	require.NoError(t, tx.Exec("TRUNCATE sales_processes CASCADE").Error)
+	repo := &salesProcessRepository{db: tx, logger: zap.NewNop()}
+
+	acme := testutils.CreateContact(t, tx)
+	globex := testutils.CreateContact(t, tx)
+	now := time.Now()
+	process := func(contactID int, status string, age time.Duration) func(*models.SalesProcess) {
+		return func(p *models.SalesProcess) {
+			p.ContactID, p.Status, p.CreatedAt = contactID, status, now.Add(-age)
+		}
+	}
+	testutils.CreateSalesProcess(t, tx, process(acme.ID, ProcessStatusSalesOrder, 0))
+	testutils.CreateSalesProcess(t, tx, process(acme.ID, ProcessStatusQuotation, time.Hour))
+	testutils.CreateSalesProcess(t, tx, process(globex.ID, ProcessStatusDraft, 2*time.Hour))
+
+	// Só a cotação mais recente de cada contato entra no processo
+	testutils.CreateQuotation(t, tx, func(q *models.Quotation) { q.ContactID, q.CreatedAt = acme.ID, now.AddDate(0, 0, -2) })
+	acmeQuotation := testutils.CreateQuotation(t, tx, func(q *models.Quotation) { q.ContactID = acme.ID })
+	globexQuotation := testutils.CreateQuotation(t, tx, func(q *models.Quotation) { q.ContactID = globex.ID })
+	salesOrder := testutils.CreateSalesOrder(t, tx, func(so *models.SalesOrder) {
+		so.ContactID, so.QuotationID = acme.ID, acmeQuotation.ID
+	})
+	purchaseOrder := testutils.CreatePurchaseOrder(t, tx, func(po *models.PurchaseOrder) { po.SalesOrderID = salesOrder.ID })
+	testutils.CreatePurchaseOrder(t, tx, func(po *models.PurchaseOrder) { po.SalesOrderID = salesOrder.ID })
+	testutils.CreateDelivery(t, tx, func(d *models.Delivery) { d.SalesOrderID = salesOrder.ID })
+	for i := 0; i < 2; i++ {
+		testutils.CreateInvoice(t, tx, func(inv *models.Invoice) {
+			inv.ContactID, inv.SalesOrderID = acme.ID, salesOrder.ID
+		})
+	}
+
+	ctx, stats := querystats.NewContext(context.Background())
+	result, err := repo.GetAllSalesProcesses(ctx, &pagination.PaginationParams{Page: 1, PageSize: 10})
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, stats.Queries())
+
+	processes := result.Items.([]models.SalesProcess)
+	require.Len(t, processes, 3)
+	for _, process := range processes[:2] {
+		require.NotNil(t, process.SalesOrder)
+		assert.Equal(t, salesOrder.ID, process.SalesOrder.ID)
+		assert.Equal(t, acmeQuotation.ID, process.Quotation.ID)
+		assert.Equal(t, purchaseOrder.ID, process.PurchaseOrder.ID)
+		assert.Len(t, process.Deliveries, 1)
+		assert.Len(t, process.Invoices, 2)
+	}
+	assert.Equal(t, globexQuotation.ID, processes[2].Quotation.ID)
+	assert.Nil(t, processes[2].SalesOrder)
+	assert.Empty(t, processes[2].Invoices)
+}
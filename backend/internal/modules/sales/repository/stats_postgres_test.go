@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Os testes abaixo rodam as consultas analíticas num PostgreSQL real (testutils.PostgresTx)
+// para detectar SQL de outro dialeto, que o sqlmock não valida
+
+// closedDB é uma conexão já fechada: qualquer query feita nela falha
+func closedDB(t *testing.T) *gorm.DB {
+	conn, err := sql.Open("postgres", "")
+	require.NoError(t, err)
+	conn.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return gormDB
+}
+
+// As estatísticas são calculadas na réplica de leitura; o primário (fechado) não recebe queries
+func TestGetSalesProcessStatsOnPostgres(t *testing.T) {
+	tx := testutils.PostgresTx(t)
+	require.NoError(t, tx.Exec("TRUNCATE sales_processes CASCADE").Error)
+	day := func(d int, hour int) time.Time { return time.Date(2026, 1, d, hour, 0, 0, 0, time.UTC) }
+	process := func(status string, created, updated time.Time, value, profit int64) {
+		testutils.CreateSalesProcess(t, tx, func(p *models.SalesProcess) {
+			p.Status, p.CreatedAt, p.UpdatedAt = status, created, updated
+			p.TotalValue, p.Profit = money.FromInt(value), money.FromInt(profit)
+		})
+	}
+	process(ProcessStatusCompleted, day(1, 0), day(3, 12), 100, 20)
+	process(ProcessStatusCompleted, day(1, 0), day(2, 0), 200, 40)
+	process(ProcessStatusQuotation, day(5, 0), day(5, 0), 0, 0)
+	repo := &salesProcessRepository{db: closedDB(t), reader: tx, logger: zap.NewNop()}
+
+	stats, err := repo.GetSalesProcessStats(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), SalesProcessFilter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stats.TotalProcesses)
+	assert.Equal(t, 2, stats.CountByStatus[ProcessStatusCompleted])
+	assert.InDelta(t, 1.75, stats.AverageCycleTime, 0.0001)
+}
+
+func TestGetDeliveryStatsOnPostgres(t *testing.T) {
+	tx := testutils.PostgresTx(t)
+	require.NoError(t, tx.Exec("TRUNCATE deliveries CASCADE").Error)
+	require.NoError(t, tx.Exec(`INSERT INTO deliveries (delivery_no, status, delivery_date, received_date) VALUES
+		('DEL-PG-1', 'delivered', '2026-02-01 08:00', '2026-02-04 08:00'),
+		('DEL-PG-2', 'delivered', '2026-02-01 08:00', '2026-02-02 20:00'),
+		('DEL-PG-3', 'pending', NULL, NULL)`).Error)
+	repo := &deliveryRepository{db: tx, reader: tx, logger: zap.NewNop()}
+
+	stats, err := repo.GetDeliveryStats(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), DeliveryFilter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stats.TotalDeliveries)
+	assert.Equal(t, 2, stats.TotalDelivered)
+	assert.InDelta(t, 2.25, stats.AverageDeliveryTime, 0.0001)
+}
@@ -4,6 +4,9 @@ import (
 	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
 	"context"
 	"testing"
 	"time"
@@ -19,17 +22,17 @@ func createTestQuotation(t *testing.T, db *gorm.DB, logger *zap.Logger) *models.
 	repo := repository.NewQuotationRepository(db, logger)
 
 	// Cria um contexto para as chamadas
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria a cotação
 	quotation := &models.Quotation{
-		ContactID:     1,
+		ContactID:     testutils.CreateContact(t, db).ID,
 		Status:        "",
 		ExpiryDate:    time.Now().AddDate(0, 1, 0),
-		SubTotal:      1000.0,
-		TaxTotal:      100.0,
-		DiscountTotal: 50.0,
-		GrandTotal:    1050.0,
+		SubTotal:      money.FromInt(1000),
+		TaxTotal:      money.FromInt(100),
+		DiscountTotal: money.FromInt(50),
+		GrandTotal:    money.FromInt(1050),
 		Notes:         "Cotação de teste via testes automatizados",
 		Terms:         "Condições de pagamento: 30 dias",
 	}
@@ -53,7 +56,7 @@ func createTestQuotationWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger)
 	repo := repository.NewQuotationRepository(db, logger)
 
 	// Cria contexto para as operações
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Busca a cotação para ter o ID correto
 	var err error
@@ -69,10 +72,10 @@ func createTestQuotationWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger)
 			ProductCode: "P001",
 			Description: "Descrição do produto 1",
 			Quantity:    2,
-			UnitPrice:   100.0,
-			Discount:    10.0,
-			Tax:         18.0,
-			Total:       208.0, // (2 * 100 - 10) * 1.18
+			UnitPrice:   money.FromInt(100),
+			Discount:    money.FromInt(10),
+			Tax:         money.FromInt(18),
+			Total:       money.FromInt(208), // (2 * 100 - 10) * 1.18
 		},
 		{
 			QuotationID: quotation.ID,
@@ -81,10 +84,10 @@ func createTestQuotationWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger)
 			ProductCode: "P002",
 			Description: "Descrição do produto 2",
 			Quantity:    1,
-			UnitPrice:   50.0,
-			Discount:    0.0,
-			Tax:         18.0,
-			Total:       59.0, // (1 * 50) * 1.18
+			UnitPrice:   money.FromInt(50),
+			Discount:    money.Zero,
+			Tax:         money.FromInt(18),
+			Total:       money.FromInt(59), // (1 * 50) * 1.18
 		},
 	}
 
@@ -95,9 +98,9 @@ func createTestQuotationWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger)
 	}
 
 	// Atualiza o valor total da cotação
-	quotation.SubTotal = 240.0   // (2*100) + (1*50) - 10
-	quotation.TaxTotal = 43.2    // 240 * 0.18
-	quotation.GrandTotal = 283.2 // 240 + 43.2
+	quotation.SubTotal = money.FromInt(240)         // (2*100) + (1*50) - 10
+	quotation.TaxTotal = money.MustParse("43.2")    // 240 * 0.18
+	quotation.GrandTotal = money.MustParse("283.2") // 240 + 43.2
 
 	// Adiciona contexto ao updateQuotation
 	err = repo.UpdateQuotation(ctx, quotation.ID, quotation)
@@ -113,37 +116,19 @@ func createTestQuotationWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger)
 // Função auxiliar para criar contato do tipo cliente
 func createTestClient(t *testing.T, db *gorm.DB, logger *zap.Logger) *contact.Contact {
 	t.Helper()
-	cli := &contact.Contact{
-		PersonType: "pf",
-		Type:       "cliente",
-		Name:       "Cliente Teste",
-		Document:   "123.456.789-00",
-		Email:      "cliente@teste.com",
-		ZipCode:    "01001-000",
-		CreatedAt:  time.Now(),
-	}
-	err := db.Create(cli).Error
-	assert.NoError(t, err)
-	assert.NotZero(t, cli.ID)
-	return cli
+	return testutils.CreateContact(t, db, func(c *contact.Contact) {
+		c.PersonType = "pf"
+		c.Name = "Cliente Teste"
+	})
 }
 
 // Função auxiliar para criar contato do tipo fornecedor
 func createTestSupplier(t *testing.T, db *gorm.DB, logger *zap.Logger) *contact.Contact {
 	t.Helper()
-	fn := &contact.Contact{
-		PersonType: "pj",
-		Type:       "fornecedor",
-		Name:       "Fornecedor Teste",
-		Document:   "12.345.678/0001-99",
-		Email:      "fornecedor@teste.com",
-		ZipCode:    "20010-000",
-		CreatedAt:  time.Now(),
-	}
-	err := db.Create(fn).Error
-	assert.NoError(t, err)
-	assert.NotZero(t, fn.ID)
-	return fn
+	return testutils.CreateContact(t, db, func(c *contact.Contact) {
+		c.Type = "fornecedor"
+		c.Name = "Fornecedor Teste"
+	})
 }
 
 // Função auxiliar para criar sales order de teste
@@ -152,18 +137,18 @@ func createTestSalesOrder(t *testing.T, db *gorm.DB, logger *zap.Logger) *models
 	repo := repository.NewSalesOrderRepository(db, logger)
 
 	// Cria um contexto para as chamadas
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria o sales order (sem QuotationID para evitar problemas de FK)
 	salesOrder := &models.SalesOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, db).ID,
 		// QuotationID omitido (será tratado como NULL)
 		Status:          "",
 		ExpectedDate:    time.Now().AddDate(0, 0, 30), // 30 dias
-		SubTotal:        1000.0,
-		TaxTotal:        180.0,
-		DiscountTotal:   50.0,
-		GrandTotal:      1130.0,
+		SubTotal:        money.FromInt(1000),
+		TaxTotal:        money.FromInt(180),
+		DiscountTotal:   money.FromInt(50),
+		GrandTotal:      money.FromInt(1130),
 		Notes:           "Sales order de teste via testes automatizados",
 		PaymentTerms:    "30 dias",
 		ShippingAddress: "Rua de Teste, 123 - Cidade Teste",
@@ -189,7 +174,7 @@ func createTestSalesOrderWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger
 
 	// Adiciona itens ao sales order
 	repo := repository.NewSalesOrderRepository(db, logger)
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Criamos itens manualmente sem definir IDs
 	items := []models.SOItem{
@@ -200,10 +185,10 @@ func createTestSalesOrderWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger
 			ProductCode:  "P001",
 			Description:  "Descrição do produto 1",
 			Quantity:     2,
-			UnitPrice:    100.0,
-			Discount:     10.0,
-			Tax:          18.0,
-			Total:        208.0, // (2 * 100 - 10) * 1.18
+			UnitPrice:    money.FromInt(100),
+			Discount:     money.FromInt(10),
+			Tax:          money.FromInt(18),
+			Total:        money.FromInt(208), // (2 * 100 - 10) * 1.18
 		},
 		{
 			SalesOrderID: salesOrder.ID,
@@ -212,10 +197,10 @@ func createTestSalesOrderWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger
 			ProductCode:  "P002",
 			Description:  "Descrição do produto 2",
 			Quantity:     1,
-			UnitPrice:    50.0,
-			Discount:     0.0,
-			Tax:          18.0,
-			Total:        59.0, // (1 * 50) * 1.18
+			UnitPrice:    money.FromInt(50),
+			Discount:     money.Zero,
+			Tax:          money.FromInt(18),
+			Total:        money.FromInt(59), // (1 * 50) * 1.18
 		},
 	}
 
@@ -226,9 +211,9 @@ func createTestSalesOrderWithItems(t *testing.T, db *gorm.DB, logger *zap.Logger
 	}
 
 	// Atualiza o valor total do sales order
-	salesOrder.SubTotal = 240.0   // (2*100) + (1*50) - 10
-	salesOrder.TaxTotal = 43.2    // 240 * 0.18
-	salesOrder.GrandTotal = 283.2 // 240 + 43.2
+	salesOrder.SubTotal = money.FromInt(240)         // (2*100) + (1*50) - 10
+	salesOrder.TaxTotal = money.MustParse("43.2")    // 240 * 0.18
+	salesOrder.GrandTotal = money.MustParse("283.2") // 240 + 43.2
 
 	err = repo.UpdateSalesOrder(ctx, salesOrder.ID, salesOrder)
 	assert.NoError(t, err)
@@ -246,18 +231,18 @@ func createTestSalesOrderFromQuotation(t *testing.T, db *gorm.DB, logger *zap.Lo
 	repo := repository.NewSalesOrderRepository(db, logger)
 
 	// Cria um contexto para as chamadas
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria o sales order baseado na quotation
 	salesOrder := &models.SalesOrder{
 		QuotationID:     quotationID,
-		ContactID:       1,
+		ContactID:       testutils.CreateContact(t, db).ID,
 		Status:          models.SOStatusDraft,
 		ExpectedDate:    time.Now().AddDate(0, 0, 30),
-		SubTotal:        1000.0,
-		TaxTotal:        180.0,
-		DiscountTotal:   50.0,
-		GrandTotal:      1130.0,
+		SubTotal:        money.FromInt(1000),
+		TaxTotal:        money.FromInt(180),
+		DiscountTotal:   money.FromInt(50),
+		GrandTotal:      money.FromInt(1130),
 		Notes:           "Sales order criado a partir de quotation",
 		PaymentTerms:    "30 dias",
 		ShippingAddress: "Rua de Entrega, 456 - Cidade Entrega",
@@ -275,14 +260,15 @@ func createTestSalesOrderFromQuotation(t *testing.T, db *gorm.DB, logger *zap.Lo
 // Função auxiliar para criar múltiplos sales orders para teste de paginação
 func createMultipleSalesOrders(t *testing.T, db *gorm.DB, logger *zap.Logger, count int) []*models.SalesOrder {
 	var salesOrders []*models.SalesOrder
+	contacts := []*contact.Contact{testutils.CreateContact(t, db), testutils.CreateContact(t, db), testutils.CreateContact(t, db)}
 
 	for i := 0; i < count; i++ {
 		salesOrder := createTestSalesOrder(t, db, logger)
 
 		// Varia alguns campos para tornar os dados mais realistas
-		salesOrder.ContactID = (i % 3) + 1                      // Varia entre contatos 1, 2, 3
-		salesOrder.ExpectedDate = time.Now().AddDate(0, 0, i*7) // Varia datas de entrega
-		salesOrder.GrandTotal = 1000.0 + float64(i*100)         // Varia valores
+		salesOrder.ContactID = contacts[i%len(contacts)].ID        // Varia entre três contatos
+		salesOrder.ExpectedDate = time.Now().AddDate(0, 0, i*7)    // Varia datas de entrega
+		salesOrder.GrandTotal = money.FromInt(int64(1000 + i*100)) // Varia valores
 
 		if i%2 == 0 {
 			salesOrder.Status = models.SOStatusConfirmed
@@ -292,7 +278,7 @@ func createMultipleSalesOrders(t *testing.T, db *gorm.DB, logger *zap.Logger, co
 
 		// Atualiza no banco
 		repo := repository.NewSalesOrderRepository(db, logger)
-		ctx := context.Background()
+		ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 		err := repo.UpdateSalesOrder(ctx, salesOrder.ID, salesOrder)
 		assert.NoError(t, err)
 
@@ -305,7 +291,7 @@ func createMultipleSalesOrders(t *testing.T, db *gorm.DB, logger *zap.Logger, co
 // Função auxiliar para limpar sales orders de teste
 func cleanupSalesOrders(t *testing.T, db *gorm.DB, logger *zap.Logger, salesOrders []*models.SalesOrder) {
 	repo := repository.NewSalesOrderRepository(db, logger)
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	for _, salesOrder := range salesOrders {
 		err := repo.DeleteSalesOrder(ctx, salesOrder.ID)
@@ -321,18 +307,18 @@ func createTestPurchaseOrder(t *testing.T, db *gorm.DB, logger *zap.Logger) *mod
 	repo := repository.NewPurchaseOrderRepository(db, logger)
 
 	// Cria um contexto para as chamadas
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria o purchase order (sem SalesOrderID para evitar problemas de FK)
 	purchaseOrder := &models.PurchaseOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, db).ID,
 		// SalesOrderID omitido (será tratado como NULL)
 		Status:          "",
 		ExpectedDate:    time.Now().AddDate(0, 0, 30), // 30 dias
-		SubTotal:        2000.0,
-		TaxTotal:        360.0,
-		DiscountTotal:   100.0,
-		GrandTotal:      2260.0,
+		SubTotal:        money.FromInt(2000),
+		TaxTotal:        money.FromInt(360),
+		DiscountTotal:   money.FromInt(100),
+		GrandTotal:      money.FromInt(2260),
 		Notes:           "Purchase order de teste via testes automatizados",
 		PaymentTerms:    "30 dias",
 		ShippingAddress: "Rua de Fornecedor, 456 - Cidade Fornecedor",
@@ -358,7 +344,7 @@ func createTestPurchaseOrderWithItems(t *testing.T, db *gorm.DB, logger *zap.Log
 
 	// Adiciona itens ao purchase order
 	repo := repository.NewPurchaseOrderRepository(db, logger)
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Criamos itens manualmente sem definir IDs
 	items := []models.POItem{
@@ -369,10 +355,10 @@ func createTestPurchaseOrderWithItems(t *testing.T, db *gorm.DB, logger *zap.Log
 			ProductCode:     "PC001",
 			Description:     "Descrição do produto para compra 1",
 			Quantity:        5,
-			UnitPrice:       200.0,
-			Discount:        20.0,
-			Tax:             18.0,
-			Total:           1144.0, // (5 * 200 - 20) * 1.18
+			UnitPrice:       money.FromInt(200),
+			Discount:        money.FromInt(20),
+			Tax:             money.FromInt(18),
+			Total:           money.FromInt(1144), // (5 * 200 - 20) * 1.18
 		},
 		{
 			PurchaseOrderID: purchaseOrder.ID,
@@ -381,10 +367,10 @@ func createTestPurchaseOrderWithItems(t *testing.T, db *gorm.DB, logger *zap.Log
 			ProductCode:     "PC002",
 			Description:     "Descrição do produto para compra 2",
 			Quantity:        3,
-			UnitPrice:       150.0,
-			Discount:        0.0,
-			Tax:             18.0,
-			Total:           531.0, // (3 * 150) * 1.18
+			UnitPrice:       money.FromInt(150),
+			Discount:        money.Zero,
+			Tax:             money.FromInt(18),
+			Total:           money.FromInt(531), // (3 * 150) * 1.18
 		},
 	}
 
@@ -395,9 +381,9 @@ func createTestPurchaseOrderWithItems(t *testing.T, db *gorm.DB, logger *zap.Log
 	}
 
 	// Atualiza o valor total do purchase order
-	purchaseOrder.SubTotal = 1480.0   // (5*200) + (3*150) - 20
-	purchaseOrder.TaxTotal = 266.4    // 1480 * 0.18
-	purchaseOrder.GrandTotal = 1746.4 // 1480 + 266.4
+	purchaseOrder.SubTotal = money.FromInt(1480)         // (5*200) + (3*150) - 20
+	purchaseOrder.TaxTotal = money.MustParse("266.4")    // 1480 * 0.18
+	purchaseOrder.GrandTotal = money.MustParse("1746.4") // 1480 + 266.4
 
 	err = repo.UpdatePurchaseOrder(ctx, purchaseOrder.ID, purchaseOrder)
 	assert.NoError(t, err)
@@ -415,7 +401,7 @@ func createTestPurchaseOrderFromSalesOrder(t *testing.T, db *gorm.DB, logger *za
 	repo := repository.NewPurchaseOrderRepository(db, logger)
 
 	// Cria um contexto para as chamadas
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Busca o sales order para pegar alguns dados
 	salesRepo := repository.NewSalesOrderRepository(db, logger)
@@ -450,14 +436,15 @@ func createTestPurchaseOrderFromSalesOrder(t *testing.T, db *gorm.DB, logger *za
 // Função auxiliar para criar múltiplos purchase orders para teste de paginação
 func createMultiplePurchaseOrders(t *testing.T, db *gorm.DB, logger *zap.Logger, count int) []*models.PurchaseOrder {
 	var purchaseOrders []*models.PurchaseOrder
+	contacts := []*contact.Contact{testutils.CreateContact(t, db), testutils.CreateContact(t, db), testutils.CreateContact(t, db)}
 
 	for i := 0; i < count; i++ {
 		purchaseOrder := createTestPurchaseOrder(t, db, logger)
 
 		// Varia alguns campos para tornar os dados mais realistas
-		purchaseOrder.ContactID = (i % 3) + 1                       // Varia entre contatos 1, 2, 3
-		purchaseOrder.ExpectedDate = time.Now().AddDate(0, 0, i*10) // Varia datas de entrega
-		purchaseOrder.GrandTotal = 2000.0 + float64(i*200)          // Varia valores
+		purchaseOrder.ContactID = contacts[i%len(contacts)].ID        // Varia entre três contatos
+		purchaseOrder.ExpectedDate = time.Now().AddDate(0, 0, i*10)   // Varia datas de entrega
+		purchaseOrder.GrandTotal = money.FromInt(int64(2000 + i*200)) // Varia valores
 
 		if i%3 == 0 {
 			purchaseOrder.Status = models.POStatusConfirmed
@@ -469,7 +456,7 @@ func createMultiplePurchaseOrders(t *testing.T, db *gorm.DB, logger *zap.Logger,
 
 		// Atualiza no banco
 		repo := repository.NewPurchaseOrderRepository(db, logger)
-		ctx := context.Background()
+		ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 		err := repo.UpdatePurchaseOrder(ctx, purchaseOrder.ID, purchaseOrder)
 		assert.NoError(t, err)
 
@@ -482,7 +469,7 @@ func createMultiplePurchaseOrders(t *testing.T, db *gorm.DB, logger *zap.Logger,
 // Função auxiliar para limpar purchase orders de teste
 func cleanupPurchaseOrders(t *testing.T, db *gorm.DB, logger *zap.Logger, purchaseOrders []*models.PurchaseOrder) {
 	repo := repository.NewPurchaseOrderRepository(db, logger)
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	for _, purchaseOrder := range purchaseOrders {
 		err := repo.DeletePurchaseOrder(ctx, purchaseOrder.ID)
@@ -0,0 +1,12 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testutils.RunWithPostgres(m))
+}
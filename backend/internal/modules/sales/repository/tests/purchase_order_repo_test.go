@@ -2,9 +2,10 @@ package repository_test
 
 import (
 	"ERP-ONSMART/backend/internal/errors"
-	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
 	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
 	"context"
 	"testing"
@@ -15,32 +16,30 @@ import (
 )
 
 func Test_PurchaseOrderRepository_NotFound(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
 	// Cria o repositório com um logger noop (sem saída)
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
 
-	_, err := repo.GetPurchaseOrderByID(context.Background(), 999999)
+	_, err := repo.GetPurchaseOrderByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), 999999)
 	assert.ErrorIs(t, err, errors.ErrPurchaseOrderNotFound)
 }
 
 func Test_PurchaseOrderRepository_Create(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	purchaseOrder := &models.PurchaseOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, tx).ID,
 		// SalesOrderID não definido (será 0, que precisa ser tratado como NULL)
 		Status:          models.POStatusDraft,
 		ExpectedDate:    time.Now().AddDate(0, 0, 30),
-		SubTotal:        2000.0,
-		TaxTotal:        200.0,
-		DiscountTotal:   100.0,
-		GrandTotal:      2100.0,
+		SubTotal:        money.FromInt(2000),
+		TaxTotal:        money.FromInt(200),
+		DiscountTotal:   money.FromInt(100),
+		GrandTotal:      money.FromInt(2100),
 		Notes:           "Purchase order de teste",
 		PaymentTerms:    "30 dias",
 		ShippingAddress: "Rua de Teste, 123",
@@ -58,14 +57,13 @@ func Test_PurchaseOrderRepository_Create(t *testing.T) {
 }
 
 func Test_PurchaseOrderRepository_GetByID(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria um purchase order primeiro
-	purchaseOrder := createTestPurchaseOrder(t, dbTest.GormDB, zap.NewNop())
+	purchaseOrder := createTestPurchaseOrder(t, tx, zap.NewNop())
 	defer repo.DeletePurchaseOrder(ctx, purchaseOrder.ID)
 
 	// Testa a busca
@@ -80,42 +78,26 @@ func Test_PurchaseOrderRepository_GetByID(t *testing.T) {
 	// Verifica se o slice de Items é inicializado (pode estar vazio)
 	assert.NotNil(t, foundPurchaseOrder.Items, "Items deve ser inicializado")
 
-	// Para Contact, vamos verificar se o preload está funcionando
-	// mas sem falhar se o ContactID dos seeds não existir
-	t.Logf("ContactID do purchase order: %d", foundPurchaseOrder.ContactID)
-	if foundPurchaseOrder.Contact != nil {
-		t.Logf("Contact carregado: ID=%d, Name=%s", foundPurchaseOrder.Contact.ID, foundPurchaseOrder.Contact.Name)
+	// O contato criado pela factory é carregado pelo preload
+	if assert.NotNil(t, foundPurchaseOrder.Contact) {
 		assert.Equal(t, purchaseOrder.ContactID, foundPurchaseOrder.Contact.ID)
-	} else {
-		t.Logf("Contact não carregado - verificando se ContactID existe no banco...")
-
-		// Verifica se o contato existe no banco
-		var existingContact contact.Contact
-		err := dbTest.GormDB.First(&existingContact, purchaseOrder.ContactID).Error
-		if err != nil {
-			t.Logf("ContactID %d não existe no banco: %v", purchaseOrder.ContactID, err)
-			t.Logf("Isso é esperado se os seeds não criaram contatos suficientes")
-		} else {
-			t.Errorf("ContactID %d existe no banco mas não foi carregado via preload", purchaseOrder.ContactID)
-		}
 	}
 }
 
 func Test_PurchaseOrderRepository_Update(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria um purchase order primeiro
-	purchaseOrder := createTestPurchaseOrder(t, dbTest.GormDB, zap.NewNop())
+	purchaseOrder := createTestPurchaseOrder(t, tx, zap.NewNop())
 	defer repo.DeletePurchaseOrder(ctx, purchaseOrder.ID)
 
 	// Atualiza o purchase order
 	purchaseOrder.Status = models.POStatusConfirmed
 	purchaseOrder.Notes = "Purchase order atualizado"
-	purchaseOrder.GrandTotal = 3000.0
+	purchaseOrder.GrandTotal = money.FromInt(3000)
 
 	err := repo.UpdatePurchaseOrder(ctx, purchaseOrder.ID, purchaseOrder)
 	assert.NoError(t, err)
@@ -125,18 +107,17 @@ func Test_PurchaseOrderRepository_Update(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, models.POStatusConfirmed, updatedPurchaseOrder.Status)
 	assert.Equal(t, "Purchase order atualizado", updatedPurchaseOrder.Notes)
-	assert.Equal(t, 3000.0, updatedPurchaseOrder.GrandTotal)
+	assert.Equal(t, "3000.00", updatedPurchaseOrder.GrandTotal.String())
 }
 
 func Test_PurchaseOrderRepository_Delete(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria um purchase order primeiro
-	purchaseOrder := createTestPurchaseOrder(t, dbTest.GormDB, zap.NewNop())
+	purchaseOrder := createTestPurchaseOrder(t, tx, zap.NewNop())
 
 	// Verifica que existe
 	foundPurchaseOrder, err := repo.GetPurchaseOrderByID(ctx, purchaseOrder.ID)
@@ -153,14 +134,13 @@ func Test_PurchaseOrderRepository_Delete(t *testing.T) {
 }
 
 func Test_PurchaseOrderRepository_FullWorkflow(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// 1. Cria um purchase order
-	purchaseOrder := createTestPurchaseOrder(t, dbTest.GormDB, zap.NewNop())
+	purchaseOrder := createTestPurchaseOrder(t, tx, zap.NewNop())
 
 	// 2. Verifica que foi criado com status draft
 	assert.Equal(t, models.POStatusDraft, purchaseOrder.Status)
@@ -198,14 +178,13 @@ func Test_PurchaseOrderRepository_FullWorkflow(t *testing.T) {
 }
 
 func Test_PurchaseOrderRepository_UpdateNotFound(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	purchaseOrder := &models.PurchaseOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, tx).ID,
 		Status:    models.POStatusConfirmed,
 	}
 
@@ -214,28 +193,26 @@ func Test_PurchaseOrderRepository_UpdateNotFound(t *testing.T) {
 }
 
 func Test_PurchaseOrderRepository_DeleteNotFound(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	err := repo.DeletePurchaseOrder(ctx, 999999)
 	assert.ErrorIs(t, err, errors.ErrPurchaseOrderNotFound)
 }
 
 func Test_PurchaseOrderRepository_ContextTimeout(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
 
 	// Cria um contexto já cancelado
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	cancel() // Cancela imediatamente
 
 	purchaseOrder := &models.PurchaseOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, tx).ID,
 		// SalesOrderID omitido
 		Status: models.POStatusDraft,
 	}
@@ -259,17 +236,16 @@ func Test_PurchaseOrderRepository_ContextTimeout(t *testing.T) {
 }
 
 func Test_PurchaseOrderRepository_ContextDeadline(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
 
 	// Cria um contexto com deadline já expirado
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	ctx, cancel := context.WithDeadline(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), time.Now().Add(-time.Second))
 	defer cancel()
 
 	purchaseOrder := &models.PurchaseOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, tx).ID,
 		// SalesOrderID omitido
 		Status: models.POStatusDraft,
 	}
@@ -294,21 +270,20 @@ func Test_PurchaseOrderRepository_ContextDeadline(t *testing.T) {
 
 // Teste para criação de Purchase Order com relacionamento a Sales Order
 func Test_PurchaseOrderRepository_CreateFromSalesOrder(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria um sales order primeiro
-	salesOrder := createTestSalesOrder(t, dbTest.GormDB, zap.NewNop())
+	salesOrder := createTestSalesOrder(t, tx, zap.NewNop())
 	defer func() {
-		salesRepo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
+		salesRepo := repository.NewSalesOrderRepository(tx, zap.NewNop())
 		salesRepo.DeleteSalesOrder(ctx, salesOrder.ID)
 	}()
 
 	// Cria um purchase order baseado no sales order
-	purchaseOrder := createTestPurchaseOrderFromSalesOrder(t, dbTest.GormDB, zap.NewNop(), salesOrder.ID)
+	purchaseOrder := createTestPurchaseOrderFromSalesOrder(t, tx, zap.NewNop(), salesOrder.ID)
 	defer repo.DeletePurchaseOrder(ctx, purchaseOrder.ID)
 
 	// Verifica se o relacionamento foi criado corretamente
@@ -326,14 +301,13 @@ func Test_PurchaseOrderRepository_CreateFromSalesOrder(t *testing.T) {
 
 // Teste para fluxo completo de status de Purchase Order
 func Test_PurchaseOrderRepository_StatusWorkflow(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// 1. Cria purchase order em draft
-	purchaseOrder := createTestPurchaseOrder(t, dbTest.GormDB, zap.NewNop())
+	purchaseOrder := createTestPurchaseOrder(t, tx, zap.NewNop())
 	assert.Equal(t, models.POStatusDraft, purchaseOrder.Status)
 
 	// 2. Draft -> Sent
@@ -365,7 +339,7 @@ func Test_PurchaseOrderRepository_StatusWorkflow(t *testing.T) {
 
 	// 5. Teste de cancelamento (pode ser feito de qualquer status)
 	// Vamos criar outro PO para testar cancelamento
-	cancelTestPO := createTestPurchaseOrder(t, dbTest.GormDB, zap.NewNop())
+	cancelTestPO := createTestPurchaseOrder(t, tx, zap.NewNop())
 
 	// Draft -> Cancelled
 	cancelTestPO.Status = models.POStatusCancelled
@@ -385,21 +359,20 @@ func Test_PurchaseOrderRepository_StatusWorkflow(t *testing.T) {
 
 // Teste para geração automática de número PO
 func Test_PurchaseOrderRepository_AutoGeneratePONumber(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria purchase order sem definir PONo
 	purchaseOrder := &models.PurchaseOrder{
-		ContactID:       1,
+		ContactID:       testutils.CreateContact(t, tx).ID,
 		Status:          models.POStatusDraft,
 		ExpectedDate:    time.Now().AddDate(0, 0, 30),
-		SubTotal:        1000.0,
-		TaxTotal:        180.0,
-		DiscountTotal:   0.0,
-		GrandTotal:      1180.0,
+		SubTotal:        money.FromInt(1000),
+		TaxTotal:        money.FromInt(180),
+		DiscountTotal:   money.Zero,
+		GrandTotal:      money.FromInt(1180),
 		Notes:           "Teste geração automática de número",
 		PaymentTerms:    "30 dias",
 		ShippingAddress: "Rua Auto Number, 123",
@@ -423,27 +396,26 @@ func Test_PurchaseOrderRepository_AutoGeneratePONumber(t *testing.T) {
 
 // Teste de transação - falha na criação de itens deve fazer rollback
 func Test_PurchaseOrderRepository_TransactionRollback(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewPurchaseOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewPurchaseOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Simula um erro forçando um contexto com timeout muito curto
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Nanosecond)
+	ctx, cancel := context.WithTimeout(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), 1*time.Nanosecond)
 	defer cancel()
 
 	// Aguarda o contexto expirar
 	time.Sleep(2 * time.Nanosecond)
 
 	purchaseOrder := &models.PurchaseOrder{
-		ContactID:       1,
+		ContactID:       testutils.CreateContact(t, tx).ID,
 		Status:          models.POStatusDraft,
 		ExpectedDate:    time.Now().AddDate(0, 0, 30),
-		SubTotal:        1000.0,
-		TaxTotal:        180.0,
-		DiscountTotal:   0.0,
-		GrandTotal:      1180.0,
+		SubTotal:        money.FromInt(1000),
+		TaxTotal:        money.FromInt(180),
+		DiscountTotal:   money.Zero,
+		GrandTotal:      money.FromInt(1180),
 		Notes:           "Teste rollback",
 		PaymentTerms:    "30 dias",
 		ShippingAddress: "Rua Rollback, 123",
@@ -454,10 +426,10 @@ func Test_PurchaseOrderRepository_TransactionRollback(t *testing.T) {
 				ProductCode: "PR001",
 				Description: "Item que deve causar rollback",
 				Quantity:    1,
-				UnitPrice:   1000.0,
-				Discount:    0.0,
-				Tax:         18.0,
-				Total:       1180.0,
+				UnitPrice:   money.FromInt(1000),
+				Discount:    money.Zero,
+				Tax:         money.FromInt(18),
+				Total:       money.FromInt(1180),
 			},
 		},
 	}
@@ -3,6 +3,7 @@ package repository_test
 import (
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/tenant"
 	"ERP-ONSMART/backend/internal/utils/pagination"
 	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
 	"context"
@@ -14,10 +15,9 @@ import (
 )
 
 func Test_GetAllQuotations(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	err := dbTest.GormDB.
+	err := tx.
 		Exec("DELETE FROM quotations").
 		Error
 	assert.NoError(t, err, "deveria conseguir apagar todas as quotations antes do teste")
@@ -25,13 +25,13 @@ func Test_GetAllQuotations(t *testing.T) {
 	logger := zap.NewNop()
 
 	// Inicializa o repositório
-	repo := repository.NewQuotationRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewQuotationRepository(tx, zap.NewNop())
 
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria algumas cotações para teste
 	for i := 0; i < 3; i++ {
-		createTestQuotation(t, dbTest.GormDB, logger)
+		createTestQuotation(t, tx, logger)
 	}
 
 	// Busca todas as cotações com paginação
@@ -52,17 +52,16 @@ func Test_GetAllQuotations(t *testing.T) {
 }
 
 func Test_GetQuotationsByStatus(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 	logger := zap.NewNop()
 
 	// Inicializa o repositório
-	repo := repository.NewQuotationRepository(dbTest.GormDB, logger)
+	repo := repository.NewQuotationRepository(tx, logger)
 
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria uma cotação com status específico
-	quotation := createTestQuotation(t, dbTest.GormDB, logger)
+	quotation := createTestQuotation(t, tx, logger)
 	quotation.Status = models.QuotationStatusSent
 	err := repo.UpdateQuotation(ctx, quotation.ID, quotation)
 	assert.NoError(t, err)
@@ -92,26 +91,25 @@ func Test_GetQuotationsByStatus(t *testing.T) {
 }
 
 func Test_GetQuotationsByContact(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 	logger := zap.NewNop()
 
 	// Inicializa o repositório
-	repo := repository.NewQuotationRepository(dbTest.GormDB, logger)
-	ctx := context.Background()
+	repo := repository.NewQuotationRepository(tx, logger)
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria contatos explicitamente para garantir que existem
-	testContact := createTestClient(t, dbTest.GormDB, logger)
+	testContact := createTestClient(t, tx, logger)
 	contactID := testContact.ID
 
-	otherContact := createTestSupplier(t, dbTest.GormDB, logger)
+	otherContact := createTestSupplier(t, tx, logger)
 	otherContactID := otherContact.ID
 
 	var createdQuotations []*models.Quotation
 
 	// Cria 3 cotações para o contato principal
 	for i := 0; i < 3; i++ {
-		quotation := createTestQuotation(t, dbTest.GormDB, logger)
+		quotation := createTestQuotation(t, tx, logger)
 		quotation.ContactID = contactID
 		err := repo.UpdateQuotation(ctx, quotation.ID, quotation)
 		assert.NoError(t, err)
@@ -119,7 +117,7 @@ func Test_GetQuotationsByContact(t *testing.T) {
 	}
 
 	// Cria uma cotação para um contato diferente
-	otherQuotation := createTestQuotation(t, dbTest.GormDB, logger)
+	otherQuotation := createTestQuotation(t, tx, logger)
 	otherQuotation.ContactID = otherContactID
 	err := repo.UpdateQuotation(ctx, otherQuotation.ID, otherQuotation)
 	assert.NoError(t, err)
@@ -191,30 +189,29 @@ func Test_GetQuotationsByContact(t *testing.T) {
 }
 
 func Test_GetQuotationsByDateRange(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 	logger := zap.NewNop()
 
 	// Inicializa o repositório
-	repo := repository.NewQuotationRepository(dbTest.GormDB, logger)
-	ctx := context.Background()
+	repo := repository.NewQuotationRepository(tx, logger)
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria contatos explicitamente para garantir que existem
-	testContact := createTestClient(t, dbTest.GormDB, logger)
+	testContact := createTestClient(t, tx, logger)
 	contactID := testContact.ID
 
 	// Cria cotações com diferentes datas
 	quotations := []*models.Quotation{}
 
 	// Cotação atual (dentro do período de teste)
-	currentQuotation := createTestQuotation(t, dbTest.GormDB, logger)
+	currentQuotation := createTestQuotation(t, tx, logger)
 	currentQuotation.ContactID = contactID
 	err := repo.UpdateQuotation(ctx, currentQuotation.ID, currentQuotation)
 	assert.NoError(t, err)
 	quotations = append(quotations, currentQuotation)
 
 	// Cotação antiga - vamos manipular a data de criação
-	oldQuotation := createTestQuotation(t, dbTest.GormDB, logger)
+	oldQuotation := createTestQuotation(t, tx, logger)
 	oldQuotation.ContactID = contactID
 	err = repo.UpdateQuotation(ctx, oldQuotation.ID, oldQuotation)
 	assert.NoError(t, err)
@@ -310,26 +307,25 @@ func Test_GetQuotationsByDateRange(t *testing.T) {
 }
 
 func Test_GetQuotationsByExpiryRange(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 	logger := zap.NewNop()
 
-	err := dbTest.GormDB.Exec("DELETE FROM quotations").Error
+	err := tx.Exec("DELETE FROM quotations").Error
 	assert.NoError(t, err, "deveria conseguir apagar todas as quotations antes do teste")
 
 	// Inicializa o repositório
-	repo := repository.NewQuotationRepository(dbTest.GormDB, logger)
-	ctx := context.Background()
+	repo := repository.NewQuotationRepository(tx, logger)
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria contatos explicitamente para garantir que existem
-	testContact := createTestClient(t, dbTest.GormDB, logger)
+	testContact := createTestClient(t, tx, logger)
 	contactID := testContact.ID
 
 	// Cria cotações com diferentes datas de expiração
 	quotations := []*models.Quotation{}
 
 	// Cotação que expira em 1 mês
-	upcomingQuotation := createTestQuotation(t, dbTest.GormDB, logger)
+	upcomingQuotation := createTestQuotation(t, tx, logger)
 	upcomingQuotation.ContactID = contactID
 	upcomingQuotation.ExpiryDate = time.Now().AddDate(0, 1, 0) // Expira em 1 mês
 	err = repo.UpdateQuotation(ctx, upcomingQuotation.ID, upcomingQuotation)
@@ -337,7 +333,7 @@ func Test_GetQuotationsByExpiryRange(t *testing.T) {
 	quotations = append(quotations, upcomingQuotation)
 
 	// Cotação que expira em 2 semanas
-	midRangeQuotation := createTestQuotation(t, dbTest.GormDB, logger)
+	midRangeQuotation := createTestQuotation(t, tx, logger)
 	midRangeQuotation.ContactID = contactID
 	midRangeQuotation.ExpiryDate = time.Now().AddDate(0, 0, 14) // Expira em 2 semanas
 	err = repo.UpdateQuotation(ctx, midRangeQuotation.ID, midRangeQuotation)
@@ -345,7 +341,7 @@ func Test_GetQuotationsByExpiryRange(t *testing.T) {
 	quotations = append(quotations, midRangeQuotation)
 
 	// Cotação que já expirou
-	expiredQuotation := createTestQuotation(t, dbTest.GormDB, logger)
+	expiredQuotation := createTestQuotation(t, tx, logger)
 	expiredQuotation.ContactID = contactID
 	expiredQuotation.ExpiryDate = time.Now().AddDate(0, 0, -10) // Expirou há 10 dias
 	err = repo.UpdateQuotation(ctx, expiredQuotation.ID, expiredQuotation)
@@ -456,31 +452,30 @@ func Test_GetQuotationsByExpiryRange(t *testing.T) {
 
 func Test_GetQuotationsByContactType(t *testing.T) {
 	// 1) Prepara o DB de teste
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 	logger := zap.NewNop()
 
 	// 2) Limpa as tabelas que vamos usar
-	assert.NoError(t, dbTest.GormDB.Exec("DELETE FROM quotations").Error)
-	assert.NoError(t, dbTest.GormDB.Exec("DELETE FROM contacts").Error)
+	assert.NoError(t, tx.Exec("DELETE FROM quotations").Error)
+	assert.NoError(t, tx.Exec("DELETE FROM contacts").Error)
 
 	// 3) Cria dois contatos de teste com tipos diferentes
-	cli := createTestClient(t, dbTest.GormDB, logger)
+	cli := createTestClient(t, tx, logger)
 
-	fn := createTestSupplier(t, dbTest.GormDB, logger)
+	fn := createTestSupplier(t, tx, logger)
 
 	// 4) Inicializa repositório e contexto
-	repo := repository.NewQuotationRepository(dbTest.GormDB, logger)
-	ctx := context.Background()
+	repo := repository.NewQuotationRepository(tx, logger)
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// 5) Gera 3 cotações para cada contato
 	for i := 0; i < 3; i++ {
-		q := createTestQuotation(t, dbTest.GormDB, logger)
+		q := createTestQuotation(t, tx, logger)
 		q.ContactID = cli.ID
 		assert.NoError(t, repo.UpdateQuotation(ctx, q.ID, q))
 	}
 	for i := 0; i < 2; i++ {
-		q := createTestQuotation(t, dbTest.GormDB, logger)
+		q := createTestQuotation(t, tx, logger)
 		q.ContactID = fn.ID
 		assert.NoError(t, repo.UpdateQuotation(ctx, q.ID, q))
 	}
@@ -523,20 +518,19 @@ func Test_GetQuotationsByContactType(t *testing.T) {
 }
 
 func Test_SearchQuotations(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 	logger := zap.NewNop()
 
-	err := dbTest.GormDB.Exec("DELETE FROM quotations").Error
+	err := tx.Exec("DELETE FROM quotations").Error
 	assert.NoError(t, err, "deveria conseguir apagar todas as quotations antes do teste")
 
 	// Inicializa o repositório
-	repo := repository.NewQuotationRepository(dbTest.GormDB, logger)
+	repo := repository.NewQuotationRepository(tx, logger)
 
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria uma cotação para pesquisa
-	searchQuotation := createTestQuotation(t, dbTest.GormDB, logger)
+	searchQuotation := createTestQuotation(t, tx, logger)
 	searchQuotation.Notes = "Cotação PESQUISÁVEL especial"
 	err = repo.UpdateQuotation(ctx, searchQuotation.ID, searchQuotation)
 	assert.NoError(t, err)
@@ -544,7 +538,7 @@ func Test_SearchQuotations(t *testing.T) {
 	// Define filtros de pesquisa
 	filter := repository.QuotationFilter{
 		Status:      []string{models.QuotationStatusDraft},
-		ContactID:   1,
+		ContactID:   testutils.CreateContact(t, tx).ID,
 		SearchQuery: "PESQUISÁVEL",
 	}
 
@@ -4,6 +4,8 @@ import (
 	"ERP-ONSMART/backend/internal/errors"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
 	"ERP-ONSMART/backend/internal/utils/pagination"
 	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
 	"context"
@@ -15,33 +17,31 @@ import (
 )
 
 func Test_QuotationRepository_NotFound(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
 	// Cria o repositório com um logger noop (sem saída)
-	repo := repository.NewQuotationRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewQuotationRepository(tx, zap.NewNop())
 
-	_, err := repo.GetQuotationByID(context.Background(), 999999)
+	_, err := repo.GetQuotationByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), 999999)
 	assert.ErrorIs(t, err, errors.ErrQuotationNotFound)
 }
 
 func Test_ExpiredAndExpiringQuotations(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
 	// Inicializa o repositório usando testEnv
-	repo := repository.NewQuotationRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewQuotationRepository(tx, zap.NewNop())
 
 	// Criar um contexto para as operações
-	ctx := context.Background()
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria uma cotação que vai expirar em breve
 	expiringQuotation := &models.Quotation{
-		ContactID:  1,
+		ContactID:  testutils.CreateContact(t, tx).ID,
 		Status:     models.QuotationStatusSent,
 		ExpiryDate: time.Now().AddDate(0, 0, 2), // Expira em 2 dias
-		SubTotal:   500.0,
-		GrandTotal: 500.0,
+		SubTotal:   money.FromInt(500),
+		GrandTotal: money.FromInt(500),
 		Notes:      "Cotação a expirar em breve",
 	}
 	err := repo.CreateQuotation(ctx, expiringQuotation)
@@ -49,11 +49,11 @@ func Test_ExpiredAndExpiringQuotations(t *testing.T) {
 
 	// Cria uma cotação já expirada
 	expiredQuotation := &models.Quotation{
-		ContactID:  1,
+		ContactID:  testutils.CreateContact(t, tx).ID,
 		Status:     models.QuotationStatusSent,
 		ExpiryDate: time.Now().AddDate(0, 0, -5), // Expirou há 5 dias
-		SubTotal:   300.0,
-		GrandTotal: 300.0,
+		SubTotal:   money.FromInt(300),
+		GrandTotal: money.FromInt(300),
 		Notes:      "Cotação já expirada",
 	}
 	err = repo.CreateQuotation(ctx, expiredQuotation)
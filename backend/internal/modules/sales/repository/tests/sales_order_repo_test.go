@@ -2,9 +2,10 @@ package repository_test
 
 import (
 	"ERP-ONSMART/backend/internal/errors"
-	contact "ERP-ONSMART/backend/internal/modules/contact/models"
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
 	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
 	"context"
 	"testing"
@@ -15,32 +16,30 @@ import (
 )
 
 func Test_SalesOrderRepository_NotFound(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
 	// Cria o repositório com um logger noop (sem saída)
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
 
-	_, err := repo.GetSalesOrderByID(context.Background(), 999999)
+	_, err := repo.GetSalesOrderByID(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), 999999)
 	assert.ErrorIs(t, err, errors.ErrSalesOrderNotFound)
 }
 
 func Test_SalesOrderRepository_Create(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	salesOrder := &models.SalesOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, tx).ID,
 		// QuotationID não definido (será 0, que precisa ser tratado como NULL)
 		Status:          models.SOStatusDraft,
 		ExpectedDate:    time.Now().AddDate(0, 0, 30),
-		SubTotal:        1000.0,
-		TaxTotal:        100.0,
-		DiscountTotal:   50.0,
-		GrandTotal:      1050.0,
+		SubTotal:        money.FromInt(1000),
+		TaxTotal:        money.FromInt(100),
+		DiscountTotal:   money.FromInt(50),
+		GrandTotal:      money.FromInt(1050),
 		Notes:           "Sales order de teste",
 		PaymentTerms:    "30 dias",
 		ShippingAddress: "Rua de Teste, 123",
@@ -58,14 +57,13 @@ func Test_SalesOrderRepository_Create(t *testing.T) {
 }
 
 func Test_SalesOrderRepository_GetByID(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria um sales order primeiro
-	salesOrder := createTestSalesOrder(t, dbTest.GormDB, zap.NewNop())
+	salesOrder := createTestSalesOrder(t, tx, zap.NewNop())
 	defer repo.DeleteSalesOrder(ctx, salesOrder.ID)
 
 	// Testa a busca
@@ -80,42 +78,26 @@ func Test_SalesOrderRepository_GetByID(t *testing.T) {
 	// Verifica se o slice de Items é inicializado (pode estar vazio)
 	assert.NotNil(t, foundSalesOrder.Items, "Items deve ser inicializado")
 
-	// Para Contact, vamos verificar se o preload está funcionando
-	// mas sem falhar se o ContactID dos seeds não existir
-	t.Logf("ContactID do sales order: %d", foundSalesOrder.ContactID)
-	if foundSalesOrder.Contact != nil {
-		t.Logf("Contact carregado: ID=%d, Name=%s", foundSalesOrder.Contact.ID, foundSalesOrder.Contact.Name)
+	// O contato criado pela factory é carregado pelo preload
+	if assert.NotNil(t, foundSalesOrder.Contact) {
 		assert.Equal(t, salesOrder.ContactID, foundSalesOrder.Contact.ID)
-	} else {
-		t.Logf("Contact não carregado - verificando se ContactID existe no banco...")
-
-		// Verifica se o contato existe no banco
-		var existingContact contact.Contact
-		err := dbTest.GormDB.First(&existingContact, salesOrder.ContactID).Error
-		if err != nil {
-			t.Logf("ContactID %d não existe no banco: %v", salesOrder.ContactID, err)
-			t.Logf("Isso é esperado se os seeds não criaram contatos suficientes")
-		} else {
-			t.Errorf("ContactID %d existe no banco mas não foi carregado via preload", salesOrder.ContactID)
-		}
 	}
 }
 
 func Test_SalesOrderRepository_Update(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria um sales order primeiro
-	salesOrder := createTestSalesOrder(t, dbTest.GormDB, zap.NewNop())
+	salesOrder := createTestSalesOrder(t, tx, zap.NewNop())
 	defer repo.DeleteSalesOrder(ctx, salesOrder.ID)
 
 	// Atualiza o sales order
 	salesOrder.Status = models.SOStatusConfirmed
 	salesOrder.Notes = "Sales order atualizado"
-	salesOrder.GrandTotal = 2000.0
+	salesOrder.GrandTotal = money.FromInt(2000)
 
 	err := repo.UpdateSalesOrder(ctx, salesOrder.ID, salesOrder)
 	assert.NoError(t, err)
@@ -125,18 +107,17 @@ func Test_SalesOrderRepository_Update(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, models.SOStatusConfirmed, updatedSalesOrder.Status)
 	assert.Equal(t, "Sales order atualizado", updatedSalesOrder.Notes)
-	assert.Equal(t, 2000.0, updatedSalesOrder.GrandTotal)
+	assert.Equal(t, "2000.00", updatedSalesOrder.GrandTotal.String())
 }
 
 func Test_SalesOrderRepository_Delete(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// Cria um sales order primeiro
-	salesOrder := createTestSalesOrder(t, dbTest.GormDB, zap.NewNop())
+	salesOrder := createTestSalesOrder(t, tx, zap.NewNop())
 
 	// Verifica que existe
 	foundSalesOrder, err := repo.GetSalesOrderByID(ctx, salesOrder.ID)
@@ -153,14 +134,13 @@ func Test_SalesOrderRepository_Delete(t *testing.T) {
 }
 
 func Test_SalesOrderRepository_FullWorkflow(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	// 1. Cria um sales order
-	salesOrder := createTestSalesOrder(t, dbTest.GormDB, zap.NewNop())
+	salesOrder := createTestSalesOrder(t, tx, zap.NewNop())
 
 	// 2. Verifica que foi criado com status draft
 	assert.Equal(t, models.SOStatusDraft, salesOrder.Status)
@@ -198,14 +178,13 @@ func Test_SalesOrderRepository_FullWorkflow(t *testing.T) {
 }
 
 func Test_SalesOrderRepository_UpdateNotFound(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	salesOrder := &models.SalesOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, tx).ID,
 		Status:    models.SOStatusConfirmed,
 	}
 
@@ -214,28 +193,26 @@ func Test_SalesOrderRepository_UpdateNotFound(t *testing.T) {
 }
 
 func Test_SalesOrderRepository_DeleteNotFound(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
-	ctx := context.Background()
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
+	ctx := tenant.WithCompany(context.Background(), tenant.DefaultCompanyID)
 
 	err := repo.DeleteSalesOrder(ctx, 999999)
 	assert.ErrorIs(t, err, errors.ErrSalesOrderNotFound)
 }
 
 func Test_SalesOrderRepository_ContextTimeout(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
 
 	// Cria um contexto já cancelado
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	cancel() // Cancela imediatamente
 
 	salesOrder := &models.SalesOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, tx).ID,
 		// QuotationID omitido
 		Status: models.SOStatusDraft,
 	}
@@ -259,17 +236,16 @@ func Test_SalesOrderRepository_ContextTimeout(t *testing.T) {
 }
 
 func Test_SalesOrderRepository_ContextDeadline(t *testing.T) {
-	dbTest := testutils.NewDBTest(t)
-	defer dbTest.Cleanup()
+	tx := testutils.PostgresTx(t)
 
-	repo := repository.NewSalesOrderRepository(dbTest.GormDB, zap.NewNop())
+	repo := repository.NewSalesOrderRepository(tx, zap.NewNop())
 
 	// Cria um contexto com deadline já expirado
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	ctx, cancel := context.WithDeadline(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), time.Now().Add(-time.Second))
 	defer cancel()
 
 	salesOrder := &models.SalesOrder{
-		ContactID: 1,
+		ContactID: testutils.CreateContact(t, tx).ID,
 		// QuotationID omitido
 		Status: models.SOStatusDraft,
 	}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/dtos"
+	"ERP-ONSMART/backend/internal/modules/sales/mapper"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/utils/validation"
+	"context"
+)
+
+// CreateInvoicesBatch valida e cria as faturas do lote, com os totais calculados a partir dos itens
+func CreateInvoicesBatch(ctx context.Context, req dtos.InvoiceBatchDTO) (*models.BatchResult, error) {
+	return processBatch(ctx, req.Atomic, req.Items,
+		func(dto dtos.InvoiceCreateDTO) *models.Invoice {
+			invoice := mapper.FromInvoiceCreateDTO(&dto)
+			models.RecalculateInvoiceTotals(invoice)
+			return invoice
+		},
+		repository.BatchRepository.CreateInvoices)
+}
+
+// CreatePaymentsBatch valida e registra os pagamentos do lote
+func CreatePaymentsBatch(ctx context.Context, req dtos.PaymentBatchDTO) (*models.BatchResult, error) {
+	return processBatch(ctx, req.Atomic, req.Items,
+		func(dto dtos.PaymentCreateDTO) *models.Payment { return mapper.FromPaymentCreateDTO(&dto) },
+		repository.BatchRepository.CreatePayments)
+}
+
+// UpdateDeliveryStatusesBatch valida e aplica as alterações de status de entregas do lote
+func UpdateDeliveryStatusesBatch(ctx context.Context, req dtos.DeliveryStatusBatchDTO) (*models.BatchResult, error) {
+	return processBatch(ctx, req.Atomic, req.Items, mapper.ToDeliveryStatusChange,
+		repository.BatchRepository.UpdateDeliveryStatuses)
+}
+
+// batchWriter é o método do repositório que grava os itens válidos do lote
+type batchWriter[M any] func(repo repository.BatchRepository, ctx context.Context, entries []models.BatchEntry[M], atomic bool) ([]models.BatchItemResult, error)
+
+// processBatch valida cada item do lote e grava os válidos. Itens reprovados na validação saem
+// com status error; em lotes atômicos, basta um item reprovado para nada ser gravado.
+func processBatch[D, M any](ctx context.Context, atomic bool, items []D, toModel func(D) M, write batchWriter[M]) (*models.BatchResult, error) {
+	if len(items) == 0 {
+		return nil, errors.ErrBatchEmpty
+	}
+	if len(items) > models.MaxBatchSize {
+		return nil, errors.ErrBatchTooLarge
+	}
+
+	var rejected []models.BatchItemResult
+	entries := make([]models.BatchEntry[M], 0, len(items))
+	for i, item := range items {
+		if err := validation.Struct(item); err != nil {
+			rejected = append(rejected, models.BatchItemFailed(i, errors.InvalidRequest(err)))
+			continue
+		}
+		entries = append(entries, models.BatchEntry[M]{Index: i, Item: toModel(item)})
+	}
+
+	if len(entries) == 0 || (atomic && len(rejected) > 0) {
+		return models.NewBatchResult(atomic, len(items), rejected), nil
+	}
+
+	repo, err := repository.NewBatchRepository()
+	if err != nil {
+		return nil, err
+	}
+	written, err := write(repo, ctx, entries, atomic)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewBatchResult(atomic, len(items), append(rejected, written...)), nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+	"time"
+)
+
+// DeliveryService concentra as regras das entregas de pedido de venda: geração, separação,
+// embalagem e envio
+type DeliveryService interface {
+	GenerateDelivery(ctx context.Context, salesOrderID int, opts models.DeliveryGeneration) (*models.Delivery, error)
+	StartPicking(ctx context.Context, deliveryID int) (*models.PickList, error)
+	GetPickList(ctx context.Context, deliveryID int) (*models.PickList, error)
+	RecordPicks(ctx context.Context, deliveryID int, picks []models.PickLine) (*models.PickList, error)
+	PackDelivery(ctx context.Context, deliveryID int, packages []models.PackageInput) ([]models.DeliveryPackage, error)
+	GetDeliveryPackages(ctx context.Context, deliveryID int) ([]models.DeliveryPackage, error)
+	ShipDelivery(ctx context.Context, deliveryID int, trackingNumber string) error
+}
+
+type deliveryService struct {
+	conversions repository.DocumentConversionRepository
+	deliveries  repository.DeliveryRepository
+	now         func() time.Time
+}
+
+// NewDeliveryService cria o serviço de entregas; now define a data de entrega padrão
+func NewDeliveryService(conversions repository.DocumentConversionRepository, deliveries repository.DeliveryRepository, now func() time.Time) DeliveryService {
+	return &deliveryService{conversions: conversions, deliveries: deliveries, now: now}
+}
+
+// GenerateDelivery gera a entrega com o saldo ainda não enviado do pedido de venda
+func GenerateDelivery(ctx context.Context, salesOrderID int, opts models.DeliveryGeneration) (*models.Delivery, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Deliveries.GenerateDelivery(ctx, salesOrderID, opts)
+}
+
+// StartPicking inicia a separação da entrega de pedido de venda e retorna a lista de separação
+func StartPicking(ctx context.Context, deliveryID int) (*models.PickList, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Deliveries.StartPicking(ctx, deliveryID)
+}
+
+// GetPickList retorna a lista de separação da entrega agrupada por endereço de estoque
+func GetPickList(ctx context.Context, deliveryID int) (*models.PickList, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Deliveries.GetPickList(ctx, deliveryID)
+}
+
+// RecordPicks grava as quantidades separadas e retorna a lista de separação atualizada
+func RecordPicks(ctx context.Context, deliveryID int, picks []models.PickLine) (*models.PickList, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Deliveries.RecordPicks(ctx, deliveryID, picks)
+}
+
+// PackDelivery registra os volumes da entrega separada e a marca como embalada
+func PackDelivery(ctx context.Context, deliveryID int, packages []models.PackageInput) ([]models.DeliveryPackage, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Deliveries.PackDelivery(ctx, deliveryID, packages)
+}
+
+// GetDeliveryPackages lista os volumes registrados na embalagem da entrega
+func GetDeliveryPackages(ctx context.Context, deliveryID int) ([]models.DeliveryPackage, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Deliveries.GetDeliveryPackages(ctx, deliveryID)
+}
+
+// ShipDelivery marca a entrega como enviada; entregas de pedido de venda precisam estar embaladas
+func ShipDelivery(ctx context.Context, deliveryID int, trackingNumber string) error {
+	services, err := current()
+	if err != nil {
+		return err
+	}
+	return services.Deliveries.ShipDelivery(ctx, deliveryID, trackingNumber)
+}
+
+// GenerateDelivery gera a entrega do saldo do pedido; sem data informada, a entrega é para hoje
+func (s *deliveryService) GenerateDelivery(ctx context.Context, salesOrderID int, opts models.DeliveryGeneration) (*models.Delivery, error) {
+	if opts.DeliveryDate.IsZero() {
+		opts.DeliveryDate = s.now()
+	}
+	return s.conversions.GenerateDelivery(ctx, salesOrderID, opts)
+}
+
+func (s *deliveryService) StartPicking(ctx context.Context, deliveryID int) (*models.PickList, error) {
+	return s.deliveries.StartPicking(ctx, deliveryID)
+}
+
+func (s *deliveryService) GetPickList(ctx context.Context, deliveryID int) (*models.PickList, error) {
+	return s.deliveries.GetPickList(ctx, deliveryID)
+}
+
+func (s *deliveryService) RecordPicks(ctx context.Context, deliveryID int, picks []models.PickLine) (*models.PickList, error) {
+	return s.deliveries.RecordPicks(ctx, deliveryID, picks)
+}
+
+func (s *deliveryService) PackDelivery(ctx context.Context, deliveryID int, packages []models.PackageInput) ([]models.DeliveryPackage, error) {
+	return s.deliveries.PackDelivery(ctx, deliveryID, packages)
+}
+
+func (s *deliveryService) GetDeliveryPackages(ctx context.Context, deliveryID int) ([]models.DeliveryPackage, error) {
+	return s.deliveries.GetPackages(ctx, deliveryID)
+}
+
+func (s *deliveryService) ShipDelivery(ctx context.Context, deliveryID int, trackingNumber string) error {
+	return s.deliveries.MarkAsShipped(ctx, deliveryID, trackingNumber)
+}
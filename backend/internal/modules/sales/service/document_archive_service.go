@@ -0,0 +1,47 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+)
+
+// ArchiveQuotation move a cotação para a lixeira
+func ArchiveQuotation(ctx context.Context, id int) error {
+	conn, err := db.OpenGormDB()
+	if err != nil {
+		return errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+	repo := repository.NewQuotationRepository(conn, logger.WithModule("quotation_repository"))
+	return repo.DeleteQuotation(ctx, id)
+}
+
+// ArchiveSalesOrder move o pedido de venda para a lixeira, mantendo os itens
+func ArchiveSalesOrder(ctx context.Context, id int) error {
+	conn, err := db.OpenGormDB()
+	if err != nil {
+		return errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+	repo := repository.NewSalesOrderRepository(conn, logger.WithModule("sales_order_repository"))
+	return repo.DeleteSalesOrder(ctx, id)
+}
+
+// ArchiveInvoice move a fatura para a lixeira
+func ArchiveInvoice(ctx context.Context, id int) error {
+	repo, err := repository.NewInvoiceRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteInvoice(ctx, id)
+}
+
+// ArchiveDelivery move a entrega para a lixeira
+func ArchiveDelivery(ctx context.Context, id int) error {
+	repo, err := repository.NewDeliveryRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteDelivery(ctx, id)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+)
+
+// ListSalesOrders lista os pedidos de venda com filtros, ordenação (?sort=) e seleção de campos (?fields=)
+func ListSalesOrders(ctx context.Context, filter repository.SalesOrderFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	repo := repository.NewSalesOrderRepository(gormDB, logger.GetLogger())
+	return repo.SearchSalesOrders(ctx, filter, params)
+}
+
+// ListInvoices lista as faturas com filtros, ordenação (?sort=) e seleção de campos (?fields=)
+func ListInvoices(ctx context.Context, filter repository.InvoiceFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewInvoiceRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.SearchInvoices(ctx, filter, params)
+}
+
+// ListDeliveries lista as entregas com filtros, ordenação (?sort=) e seleção de campos (?fields=)
+func ListDeliveries(ctx context.Context, filter repository.DeliveryFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewDeliveryRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.SearchDeliveries(ctx, filter, params)
+}
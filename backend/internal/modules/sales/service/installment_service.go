@@ -0,0 +1,155 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/billing"
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// newTxManager abre o coordenador da unidade de trabalho; substituído nos testes
+var newTxManager = db.NewTxManager
+
+// boletoConfig lê a conta de cobrança do boleto da configuração
+func boletoConfig() billing.BoletoConfig {
+	return billing.BoletoConfig{
+		BankCode: viper.GetString("BOLETO_BANK_CODE"),
+		Agency:   viper.GetString("BOLETO_AGENCY"),
+		Wallet:   viper.GetString("BOLETO_WALLET"),
+		Account:  viper.GetString("BOLETO_ACCOUNT"),
+	}
+}
+
+// pixConfig lê o recebedor do Pix da configuração
+func pixConfig() billing.PixConfig {
+	return billing.PixConfig{
+		Key:          viper.GetString("PIX_KEY"),
+		MerchantName: viper.GetString("PIX_MERCHANT_NAME"),
+		MerchantCity: viper.GetString("PIX_MERCHANT_CITY"),
+	}
+}
+
+// GetInvoiceInstallments lista as parcelas da fatura com o status do dia
+func GetInvoiceInstallments(ctx context.Context, invoiceID int) ([]models.InvoiceInstallment, error) {
+	repo, err := repository.NewInstallmentRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetInstallments(ctx, invoiceID)
+}
+
+// ReplaceInvoiceInstallments divide a fatura pelo plano e gera o boleto e o Pix de cada parcela
+// quando configurados. As parcelas e as cobranças são gravadas juntas.
+func ReplaceInvoiceInstallments(ctx context.Context, invoiceID int, plan models.InstallmentPlan) ([]models.InvoiceInstallment, error) {
+	repo, err := repository.NewInstallmentRepository()
+	if err != nil {
+		return nil, err
+	}
+	txManager, err := newTxManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var installments []models.InvoiceInstallment
+	err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		invoice, created, err := repo.ReplaceInstallments(ctx, invoiceID, plan)
+		if err != nil {
+			return err
+		}
+		installments = created
+
+		boleto, pix := boletoConfig(), pixConfig()
+		if !boleto.Enabled() && !pix.Enabled() {
+			return nil
+		}
+		for i := range installments {
+			installment := &installments[i]
+			amount := installment.Amount.Sub(installment.AmountPaid)
+			if err := generateCharge(invoice, installment, amount, installment.DueDate, boleto, pix); err != nil {
+				return err
+			}
+			if err := repo.SaveCharge(ctx, &installments[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// RegenerateInstallmentCharge gera de novo o boleto e o Pix da parcela, por exemplo depois de
+// alterar a conta de cobrança. Depois do vencimento, a cobrança é atualizada: vence hoje e leva
+// a multa e os juros da regra do contato, devolvidos com o valor cobrado.
+func RegenerateInstallmentCharge(ctx context.Context, invoiceID, number int) (*models.InvoiceInstallment, *models.LateCharge, error) {
+	boleto, pix := boletoConfig(), pixConfig()
+	if !boleto.Enabled() && !pix.Enabled() {
+		return nil, nil, errors.ErrChargeNotConfigured
+	}
+
+	repo, err := repository.NewInstallmentRepository()
+	if err != nil {
+		return nil, nil, err
+	}
+	invoice, installment, err := repo.GetInstallment(ctx, invoiceID, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	if invoice.Status == models.InvoiceStatusCancelled || installment.Status == models.InstallmentStatusPaid {
+		return nil, nil, errors.ErrInvoiceNotPayable
+	}
+
+	charge, err := installmentLateCharge(ctx, invoice, installment)
+	if err != nil {
+		return nil, nil, err
+	}
+	charge.Number = installment.Number
+	dueDate := installment.DueDate
+	if charge.Overdue() {
+		dueDate = localtime.Today(ctx)
+	}
+
+	if err := generateCharge(invoice, installment, charge.Total, dueDate, boleto, pix); err != nil {
+		return nil, nil, err
+	}
+	if err := repo.SaveCharge(ctx, installment); err != nil {
+		return nil, nil, err
+	}
+	return installment, &charge, nil
+}
+
+// generateCharge preenche o boleto (nosso número = id da parcela) e o Pix (txid = número da
+// fatura + "P" + número da parcela) com o valor e o vencimento informados
+func generateCharge(invoice *models.Invoice, installment *models.InvoiceInstallment, amount money.Decimal, dueDate time.Time, boleto billing.BoletoConfig, pix billing.PixConfig) error {
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	if boleto.Enabled() {
+		b, err := billing.NewBoleto(boleto, installment.ID, dueDate, amount)
+		if err != nil {
+			return errors.WrapError(err, "falha ao gerar boleto da parcela")
+		}
+		installment.BoletoOurNumber = b.OurNumber
+		installment.BoletoBarcode = b.Barcode
+		installment.BoletoDigitableLine = b.DigitableLine
+	}
+	if pix.Enabled() {
+		payload, err := billing.PixPayload(pix, amount, invoice.InvoiceNo+"P"+strconv.Itoa(installment.Number))
+		if err != nil {
+			return errors.WrapError(err, "falha ao gerar Pix da parcela")
+		}
+		installment.PixPayload = payload
+	}
+	return nil
+}
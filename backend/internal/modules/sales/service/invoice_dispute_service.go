@@ -0,0 +1,67 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	tasks "ERP-ONSMART/backend/internal/modules/tasks/models"
+	tasksService "ERP-ONSMART/backend/internal/modules/tasks/service"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// newInvoiceDisputeRepository e closeDunningTask são substituídos nos testes
+	newInvoiceDisputeRepository = repository.NewInvoiceDisputeRepository
+	closeDunningTask            = tasksService.Close
+)
+
+// ListInvoiceDisputes lista as contestações das faturas, das mais recentes; status filtra as
+// abertas (open) ou as resolvidas (resolved)
+func ListInvoiceDisputes(ctx context.Context, filter models.DisputeFilter) ([]models.InvoiceDispute, error) {
+	if filter.Status != "" && filter.Status != models.DisputeStatusOpen && filter.Status != models.DisputeStatusResolved {
+		return nil, errors.InvalidParam("status deve ser open ou resolved")
+	}
+	repo, err := newInvoiceDisputeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDisputes(ctx, filter)
+}
+
+// OpenInvoiceDispute abre a contestação da fatura (ou da parcela) e suspende a cobrança: a tarefa
+// de cobrança aberta é concluída, e o job não cria outra enquanto a contestação estiver aberta
+func OpenInvoiceDispute(ctx context.Context, invoiceID int, input models.InvoiceDisputeInput, username string) (*models.InvoiceDispute, error) {
+	repo, err := newInvoiceDisputeRepository()
+	if err != nil {
+		return nil, err
+	}
+	dispute, err := repo.OpenDispute(ctx, invoiceID, input, username)
+	if err != nil {
+		return nil, err
+	}
+
+	closedBy := username
+	if closedBy == "" {
+		closedBy = "contestação da fatura"
+	}
+	if err := closeDunningTask(ctx, tasks.DunningKey(invoiceID), closedBy); err != nil {
+		// a contestação já vale: o job de cobrança conclui a tarefa na próxima execução
+		logger.WithModule("invoice_dispute_service").Warn("tarefa de cobrança não concluída",
+			zap.Error(err), zap.Int("invoice_id", invoiceID))
+	}
+	return dispute, nil
+}
+
+// ResolveInvoiceDispute resolve a contestação com nota de crédito, novo vencimento ou baixa do
+// valor; a fatura volta à cobrança pelo saldo que restar
+func ResolveInvoiceDispute(ctx context.Context, invoiceID, id int, input models.DisputeResolutionInput, username string) (*models.InvoiceDispute, error) {
+	repo, err := newInvoiceDisputeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ResolveDispute(ctx, invoiceID, id, input, username, time.Now())
+}
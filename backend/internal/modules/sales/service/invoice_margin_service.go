@@ -0,0 +1,120 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	inventory "ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+)
+
+// newInvoiceMarginRepository é substituído nos testes; o custo vem de estimateCosts, como na
+// simulação de cotações
+var newInvoiceMarginRepository = repository.NewInvoiceMarginRepository
+
+// CheckInvoiceMargin confere a margem da fatura por categoria. O custo de cada linha é o CMV já
+// apurado para a fatura ou, sem ele, o custo pelo custeio do estoque (camadas no FIFO, custo médio
+// no médio), como na simulação de cotações.
+func CheckInvoiceMargin(ctx context.Context, invoiceID int) (*models.InvoiceMarginCheck, error) {
+	repo, err := newInvoiceMarginRepository()
+	if err != nil {
+		return nil, err
+	}
+	data, err := repo.GetMarginData(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	return buildMarginCheck(ctx, data)
+}
+
+// EnsureInvoiceMargin recusa com ErrMarginBelowMinimum a fatura com categoria abaixo da margem
+// mínima e sem liberação; chamada na contabilização da fatura
+func EnsureInvoiceMargin(ctx context.Context, invoiceID int) error {
+	repo, err := newInvoiceMarginRepository()
+	if err != nil {
+		return err
+	}
+	data, err := repo.GetMarginData(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+	if data.Approval != nil || !hasMinMargin(data) {
+		return nil
+	}
+	check, err := buildMarginCheck(ctx, data)
+	if err != nil {
+		return err
+	}
+	return check.Err()
+}
+
+// ApproveInvoiceMargin libera a contabilização da fatura com margem abaixo do mínimo, registrando
+// quem aprovou e o motivo
+func ApproveInvoiceMargin(ctx context.Context, invoiceID int, approvedBy string, input models.MarginApprovalInput) (*models.InvoiceMarginCheck, error) {
+	repo, err := newInvoiceMarginRepository()
+	if err != nil {
+		return nil, err
+	}
+	data, err := repo.GetMarginData(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	check, err := buildMarginCheck(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if !check.BelowMinimum {
+		return nil, errors.ErrMarginApprovalNotRequired
+	}
+
+	approval := &models.InvoiceMarginApproval{
+		InvoiceID:     invoiceID,
+		ApprovedBy:    approvedBy,
+		Reason:        input.Reason,
+		MarginPercent: check.Total.MarginPercent,
+	}
+	if err := repo.SaveApproval(ctx, approval); err != nil {
+		return nil, err
+	}
+	check.Approval = approval
+	check.Blocked = false
+	return check, nil
+}
+
+// hasMinMargin indica se algum produto da fatura é de categoria com margem mínima
+func hasMinMargin(data *repository.InvoiceMarginData) bool {
+	for _, item := range data.Invoice.Items {
+		if categoryID, ok := data.CategoryOf[item.ProductID]; ok {
+			if _, checked := data.MinMargins[categoryID]; checked {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildMarginCheck custeia as linhas sem CMV apurado e monta a conferência
+func buildMarginCheck(ctx context.Context, data *repository.InvoiceMarginData) (*models.InvoiceMarginCheck, error) {
+	items := data.Invoice.Items
+	costs := make([]models.LineCost, len(items))
+	queries := make([]inventory.CostQuery, 0, len(items))
+	pending := make([]int, 0, len(items))
+	for i, item := range items {
+		if cost, ok := data.RecordedCosts[item.ID]; ok {
+			costs[i] = models.LineCost{Cost: cost, Source: models.MarginCostCOGS}
+			continue
+		}
+		queries = append(queries, inventory.CostQuery{ProductID: item.ProductID, Quantity: item.BaseQuantity()})
+		pending = append(pending, i)
+	}
+	if len(queries) > 0 {
+		estimates, err := estimateCosts(ctx, queries)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range pending {
+			costs[i] = models.LineCost{Cost: estimates[j].TotalCost, Source: models.MarginCostEstimate}
+		}
+	}
+	return models.BuildInvoiceMarginCheck(data.Invoice, costs, data.CategoryOf, data.MinMargins, data.Approval), nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	inventory "ERP-ONSMART/backend/internal/modules/inventory/models"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMarginRepository struct {
+	data  *repository.InvoiceMarginData
+	saved *models.InvoiceMarginApproval
+}
+
+func (f *fakeMarginRepository) GetMarginData(ctx context.Context, invoiceID int) (*repository.InvoiceMarginData, error) {
+	if f.data.Invoice.ID != invoiceID {
+		return nil, errors.ErrInvoiceNotFound
+	}
+	return f.data, nil
+}
+
+func (f *fakeMarginRepository) SaveApproval(ctx context.Context, approval *models.InvoiceMarginApproval) error {
+	f.saved = approval
+	return nil
+}
+
+func stubMarginDependencies(t *testing.T, repo *fakeMarginRepository) *[][]inventory.CostQuery {
+	originalRepo, originalEstimate := newInvoiceMarginRepository, estimateCosts
+	t.Cleanup(func() { newInvoiceMarginRepository, estimateCosts = originalRepo, originalEstimate })
+
+	newInvoiceMarginRepository = func() (repository.InvoiceMarginRepository, error) { return repo, nil }
+	var queries [][]inventory.CostQuery
+	estimateCosts = func(ctx context.Context, q []inventory.CostQuery) ([]inventory.CostEstimate, error) {
+		queries = append(queries, q)
+		estimates := make([]inventory.CostEstimate, len(q))
+		for i, query := range q {
+			estimates[i] = inventory.CostEstimate{ProductID: query.ProductID, Quantity: query.Quantity, TotalCost: money.FromInt(9).MulInt(query.Quantity)}
+		}
+		return estimates, nil
+	}
+	return &queries
+}
+
+func marginData() *repository.InvoiceMarginData {
+	return &repository.InvoiceMarginData{
+		Invoice: &models.Invoice{ID: 8, InvoiceNo: "INV-8", Items: []models.InvoiceItem{
+			{ID: 1, ProductID: 10, Quantity: 2, Unit: "CX", UnitFactor: 5, UnitPrice: money.FromInt(50)},
+			{ID: 2, ProductID: 11, Quantity: 1, UnitFactor: 1, UnitPrice: money.FromInt(100)},
+		}},
+		RecordedCosts: map[int]money.Decimal{2: money.FromInt(40)},
+		CategoryOf:    map[int]int{10: 3, 11: 3},
+		MinMargins:    map[int]product.MinMargin{3: {CategoryID: 3, CategoryName: "Cabos", MinMarginPercent: 30}},
+	}
+}
+
+func TestEnsureInvoiceMargin(t *testing.T) {
+	repo := &fakeMarginRepository{data: marginData()}
+	queries := stubMarginDependencies(t, repo)
+
+	// Linha 1: 10 unidades de estoque a 9 = 90 de custo; linha 2: CMV apurado de 40.
+	// Receita 200, custo 130: margem de 35%.
+	require.NoError(t, EnsureInvoiceMargin(context.Background(), 8))
+	require.Len(t, *queries, 1)
+	assert.Equal(t, []inventory.CostQuery{{ProductID: 10, Quantity: 10}}, (*queries)[0], "só a linha sem CMV é estimada, em unidades de estoque")
+
+	repo.data.MinMargins[3] = product.MinMargin{CategoryID: 3, CategoryName: "Cabos", MinMarginPercent: 40}
+	err := EnsureInvoiceMargin(context.Background(), 8)
+	assert.True(t, stderrors.Is(err, errors.ErrMarginBelowMinimum), "esperado ErrMarginBelowMinimum, obtido %v", err)
+
+	check, err := ApproveInvoiceMargin(context.Background(), 8, "gerente", models.MarginApprovalInput{Reason: "queima de estoque"})
+	require.NoError(t, err)
+	assert.False(t, check.Blocked)
+	require.NotNil(t, repo.saved)
+	assert.Equal(t, 35.0, repo.saved.MarginPercent)
+	assert.Equal(t, "gerente", repo.saved.ApprovedBy)
+
+	repo.data.Approval = repo.saved
+	assert.NoError(t, EnsureInvoiceMargin(context.Background(), 8))
+}
+
+func TestEnsureInvoiceMarginWithoutThresholds(t *testing.T) {
+	data := marginData()
+	data.MinMargins = map[int]product.MinMargin{}
+	queries := stubMarginDependencies(t, &fakeMarginRepository{data: data})
+
+	require.NoError(t, EnsureInvoiceMargin(context.Background(), 8))
+	assert.Empty(t, *queries, "sem margem mínima nas categorias da fatura não há custeio")
+
+	_, err := ApproveInvoiceMargin(context.Background(), 8, "gerente", models.MarginApprovalInput{Reason: "x"})
+	assert.Equal(t, errors.ErrMarginApprovalNotRequired, err)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+	"time"
+)
+
+// InvoiceService concentra as regras do faturamento dos pedidos de venda
+type InvoiceService interface {
+	GenerateInvoice(ctx context.Context, salesOrderID int, opts models.InvoiceGeneration) (*models.Invoice, error)
+	GenerateAdvanceInvoice(ctx context.Context, salesOrderID int, opts models.AdvanceInvoiceGeneration) (*models.Invoice, error)
+}
+
+type invoiceService struct {
+	conversions repository.DocumentConversionRepository
+	now         func() time.Time
+}
+
+// NewInvoiceService cria o serviço de faturas; now define a data de emissão padrão
+func NewInvoiceService(conversions repository.DocumentConversionRepository, now func() time.Time) InvoiceService {
+	return &invoiceService{conversions: conversions, now: now}
+}
+
+// GenerateInvoice gera a fatura com o saldo ainda não faturado do pedido de venda
+func GenerateInvoice(ctx context.Context, salesOrderID int, opts models.InvoiceGeneration) (*models.Invoice, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Invoices.GenerateInvoice(ctx, salesOrderID, opts)
+}
+
+// GenerateInvoice valida as datas informadas e, sem data de emissão, emite na data atual; sem
+// vencimento, o repositório aplica a condição de pagamento pelo calendário da empresa
+func (s *invoiceService) GenerateInvoice(ctx context.Context, salesOrderID int, opts models.InvoiceGeneration) (*models.Invoice, error) {
+	if !opts.DueDate.IsZero() && !opts.IssueDate.IsZero() && opts.DueDate.Before(opts.IssueDate) {
+		return nil, errors.ErrInvalidDateRange
+	}
+	if opts.IssueDate.IsZero() {
+		opts.IssueDate = s.now()
+	}
+	return s.conversions.GenerateInvoice(ctx, salesOrderID, opts)
+}
+
+// GenerateAdvanceInvoice gera a fatura de adiantamento do pedido de venda
+func GenerateAdvanceInvoice(ctx context.Context, salesOrderID int, opts models.AdvanceInvoiceGeneration) (*models.Invoice, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Invoices.GenerateAdvanceInvoice(ctx, salesOrderID, opts)
+}
+
+// GenerateAdvanceInvoice valida as datas e, sem data de emissão, emite na data atual; o valor do
+// adiantamento é conferido pelo repositório contra o saldo do pedido ainda não adiantado
+func (s *invoiceService) GenerateAdvanceInvoice(ctx context.Context, salesOrderID int, opts models.AdvanceInvoiceGeneration) (*models.Invoice, error) {
+	if !opts.DueDate.IsZero() && !opts.IssueDate.IsZero() && opts.DueDate.Before(opts.IssueDate) {
+		return nil, errors.ErrInvalidDateRange
+	}
+	if opts.IssueDate.IsZero() {
+		opts.IssueDate = s.now()
+	}
+	return s.conversions.GenerateAdvanceInvoice(ctx, salesOrderID, opts)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+	"time"
+)
+
+// newLateFeeRepository é substituído nos testes
+var newLateFeeRepository = repository.NewLateFeeRepository
+
+// ListLateFeeRules lista a regra padrão de multa e juros e as regras dos contatos
+func ListLateFeeRules(ctx context.Context) ([]models.LateFeeRule, error) {
+	repo, err := newLateFeeRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListRules(ctx)
+}
+
+// SaveLateFeeRule grava a regra padrão da empresa ou, com contact_id, a regra do contato
+func SaveLateFeeRule(ctx context.Context, input models.LateFeeRuleInput, username string) (*models.LateFeeRule, error) {
+	rule, err := input.ToRule(username)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := newLateFeeRepository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.SaveRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteLateFeeRule remove a regra de multa e juros
+func DeleteLateFeeRule(ctx context.Context, id int) error {
+	repo, err := newLateFeeRepository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteRule(ctx, id)
+}
+
+// GetInvoiceAmountDue calcula o valor para quitar a fatura em asOf (padrão: hoje, no fuso da
+// empresa): o saldo em aberto de cada vencimento com a multa e os juros da regra do contato
+func GetInvoiceAmountDue(ctx context.Context, invoiceID int, asOf time.Time) (*models.AmountDue, error) {
+	repo, err := newLateFeeRepository()
+	if err != nil {
+		return nil, err
+	}
+	invoice, err := repo.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoiceStatusDraft || invoice.Status == models.InvoiceStatusCancelled {
+		return nil, errors.ErrInvoiceNotPayable
+	}
+	rule, err := repo.GetRule(ctx, invoice.ContactID)
+	if err != nil {
+		return nil, err
+	}
+
+	if asOf.IsZero() {
+		asOf = localtime.Today(ctx)
+	}
+	due := models.BuildAmountDue(invoice, rule, localtime.Date(asOf))
+	return &due, nil
+}
+
+// installmentLateCharge calcula o valor atualizado da parcela hoje, para as cobranças geradas de
+// novo depois do vencimento
+func installmentLateCharge(ctx context.Context, invoice *models.Invoice, installment *models.InvoiceInstallment) (models.LateCharge, error) {
+	repo, err := newLateFeeRepository()
+	if err != nil {
+		return models.LateCharge{}, err
+	}
+	rule, err := repo.GetRule(ctx, invoice.ContactID)
+	if err != nil {
+		return models.LateCharge{}, err
+	}
+	if rule == nil {
+		rule = &models.LateFeeRule{}
+	}
+	return rule.Charge(installment.Amount.Sub(installment.AmountPaid), installment.DueDate, localtime.Today(ctx)), nil
+}
@@ -3,24 +3,25 @@ package service
 import (
 	"ERP-ONSMART/backend/internal/modules/sales/models"
 	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
 )
 
-func ListSales() ([]models.Sale, error) {
-	return repository.GetAllSales()
+func ListSales(ctx context.Context) ([]models.Sale, error) {
+	return repository.GetAllSales(ctx)
 }
 
-func GetSale(id int) (models.Sale, error) {
-	return repository.GetSaleByID(id)
+func GetSale(ctx context.Context, id int) (models.Sale, error) {
+	return repository.GetSaleByID(ctx, id)
 }
 
-func AddSale(s models.Sale) (models.Sale, error) {
-	return repository.CreateSale(s)
+func AddSale(ctx context.Context, s models.Sale) (models.Sale, error) {
+	return repository.CreateSale(ctx, s)
 }
 
-func ModifySale(id int, s models.Sale) (models.Sale, error) {
-	return repository.UpdateSale(id, s)
+func ModifySale(ctx context.Context, id int, s models.Sale) (models.Sale, error) {
+	return repository.UpdateSale(ctx, id, s)
 }
 
-func RemoveSale(id int) error {
-	return repository.DeleteSale(id)
+func RemoveSale(ctx context.Context, id int) error {
+	return repository.DeleteSale(ctx, id)
 }
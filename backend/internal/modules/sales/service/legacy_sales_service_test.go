@@ -2,6 +2,8 @@ package service
 
 import (
 	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
 	"os"
 	"testing"
 
@@ -27,7 +29,7 @@ func TestAddSale(t *testing.T) {
 		Customer: "cliente@service.com",
 	}
 
-	created, err := AddSale(s)
+	created, err := AddSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), s)
 	if err != nil {
 		t.Fatalf("Erro ao adicionar venda: %v", err)
 	}
@@ -38,7 +40,7 @@ func TestAddSale(t *testing.T) {
 }
 
 func TestListSales(t *testing.T) {
-	sales, err := ListSales()
+	sales, err := ListSales(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID))
 	if err != nil {
 		t.Fatalf("Erro ao listar vendas: %v", err)
 	}
@@ -54,12 +56,12 @@ func TestGetSale(t *testing.T) {
 		Customer: "getsale@service.com",
 	}
 
-	created, err := AddSale(s)
+	created, err := AddSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), s)
 	if err != nil {
 		t.Fatalf("Erro ao criar venda para teste: %v", err)
 	}
 
-	retrieved, err := GetSale(created.ID)
+	retrieved, err := GetSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Fatalf("Erro ao obter venda por ID: %v", err)
 	}
@@ -83,12 +85,12 @@ func TestGetSale(t *testing.T) {
 
 	// Test trying to retrieve a non-existent sale
 	nonExistingID := 99999
-	_, err = GetSale(nonExistingID)
+	_, err = GetSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), nonExistingID)
 	if err == nil {
 		t.Error("Esperava erro ao buscar venda inexistente, mas não ocorreu")
 	}
 
-	err = RemoveSale(created.ID)
+	err = RemoveSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Logf("Aviso: Não foi possível limpar a venda de teste: %v", err)
 	}
@@ -102,7 +104,7 @@ func TestModifySale(t *testing.T) {
 		Customer: "cliente@original.com",
 	}
 
-	created, err := AddSale(original)
+	created, err := AddSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), original)
 	if err != nil {
 		t.Fatalf("Erro ao criar venda para update: %v", err)
 	}
@@ -114,7 +116,7 @@ func TestModifySale(t *testing.T) {
 		Customer: "cliente@atualizado.com",
 	}
 
-	updated, err := ModifySale(created.ID, update)
+	updated, err := ModifySale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID, update)
 	if err != nil {
 		t.Fatalf("Erro ao atualizar venda: %v", err)
 	}
@@ -132,17 +134,17 @@ func TestRemoveSale(t *testing.T) {
 		Customer: "cliente@remocao.com",
 	}
 
-	created, err := AddSale(s)
+	created, err := AddSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), s)
 	if err != nil {
 		t.Fatalf("Erro ao criar venda para remoção: %v", err)
 	}
 
-	err = RemoveSale(created.ID)
+	err = RemoveSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err != nil {
 		t.Fatalf("Erro ao remover venda: %v", err)
 	}
 
-	err = RemoveSale(created.ID)
+	err = RemoveSale(tenant.WithCompany(context.Background(), tenant.DefaultCompanyID), created.ID)
 	if err == nil {
 		t.Error("Esperava erro ao tentar remover venda já deletada")
 	}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+)
+
+// GetPaymentAllocations lista as faturas quitadas pelo pagamento
+func GetPaymentAllocations(ctx context.Context, paymentID int) ([]models.PaymentAllocation, error) {
+	repo, err := repository.NewPaymentAllocationRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetPaymentAllocations(ctx, paymentID)
+}
+
+// GetInvoiceAllocations lista os pagamentos alocados na fatura
+func GetInvoiceAllocations(ctx context.Context, invoiceID int) ([]models.PaymentAllocation, error) {
+	repo, err := repository.NewPaymentAllocationRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetInvoiceAllocations(ctx, invoiceID)
+}
+
+// ReplacePaymentAllocations redistribui o pagamento entre as faturas informadas
+func ReplacePaymentAllocations(ctx context.Context, paymentID int, allocations []models.PaymentAllocation) ([]models.PaymentAllocation, error) {
+	repo, err := repository.NewPaymentAllocationRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ReplacePaymentAllocations(ctx, paymentID, allocations)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+	"time"
+)
+
+// GetProcessBoard monta o quadro dos processos de venda: uma coluna por etapa, com a quantidade
+// e o valor dos processos e os primeiros cartões pela posição
+func GetProcessBoard(ctx context.Context, filter models.ProcessBoardFilter) (*models.ProcessBoard, error) {
+	repo, err := repository.NewProcessBoardRepository()
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = models.DefaultBoardColumnLimit
+	}
+
+	stages := repository.BoardStages(filter.IncludeClosed)
+	totals, cards, err := repo.Board(ctx, stages, filter)
+	if err != nil {
+		return nil, err
+	}
+	lang := i18n.FromContext(ctx)
+	board := models.BuildProcessBoard(stages, totals, cards, func(stage string) string {
+		return i18n.Label(lang, i18n.SalesProcessStatus, stage)
+	})
+	return &board, nil
+}
+
+// MoveSalesProcess move o processo para a etapa e a posição do quadro, conferindo a transição
+func MoveSalesProcess(ctx context.Context, id int, input models.MoveProcessInput) (*models.BoardCard, error) {
+	repo, err := repository.NewProcessBoardRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.MoveProcess(ctx, id, input, time.Now())
+}
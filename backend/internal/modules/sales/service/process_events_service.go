@@ -0,0 +1,29 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/realtime"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+)
+
+// SubscribeProcessEvents inscreve o chamador nos eventos em tempo real do processo de venda (como
+// os pagamentos Pix recebidos), depois de conferir que o processo é da empresa da requisição.
+// cancel encerra a inscrição.
+func SubscribeProcessEvents(ctx context.Context, id int) (<-chan realtime.Event, func(), error) {
+	repo, err := repository.NewSalesProcessRepository()
+	if err != nil {
+		return nil, nil, err
+	}
+	process, err := repo.GetSalesProcessByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		companyID = tenant.DefaultCompanyID
+	}
+	events, cancel := realtime.Subscribe(realtime.ProcessTopic(companyID, process.ID))
+	return events, cancel, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/realtime"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	"time"
+)
+
+// GetProcessTimeline retorna uma página da linha do tempo gravada do processo de venda, em ordem
+// cronológica; com newestFirst, do evento mais recente ao mais antigo
+func GetProcessTimeline(ctx context.Context, id int, newestFirst bool, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewProcessTimelineRepository()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GetProcess(ctx, id); err != nil {
+		return nil, err
+	}
+	return repo.ListEvents(ctx, id, newestFirst, params)
+}
+
+// AddProcessEvent lança na linha do tempo do processo uma ligação, reunião, e-mail ou anotação
+// do usuário e avisa quem acompanha o processo em tempo real
+func AddProcessEvent(ctx context.Context, id int, input models.ProcessEventInput, username string) (*models.ProcessEvent, error) {
+	repo, err := repository.NewProcessTimelineRepository()
+	if err != nil {
+		return nil, err
+	}
+	process, err := repo.GetProcess(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	event, err := input.ToEvent(process.ID, username, now)
+	if err != nil {
+		return nil, err
+	}
+	event.CompanyID = process.CompanyID
+	if err := repo.CreateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	realtime.Publish(realtime.ProcessTopic(process.CompanyID, process.ID), realtime.Event{
+		Type:       event.EventType,
+		Data:       event,
+		OccurredAt: now,
+	})
+	return event, nil
+}
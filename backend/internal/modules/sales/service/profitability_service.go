@@ -0,0 +1,47 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RecomputeProfitability recalcula o valor e o lucro dos processos de venda criados no período
+// (fim exclusivo) pelas faturas e pelo CMV apurado até agora, e retorna quantos foram
+// recalculados. Um processo com erro não interrompe os demais; o primeiro erro é devolvido ao
+// final, com a quantidade de falhas.
+func RecomputeProfitability(ctx context.Context, from, to time.Time) (int, error) {
+	if !to.After(from) {
+		return 0, errors.InvalidParam("o fim do período deve ser posterior ao início")
+	}
+	repo, err := repository.NewSalesProcessRepository()
+	if err != nil {
+		return 0, err
+	}
+	ids, err := repo.ProcessIDsByPeriod(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	log := logger.WithModule("sales_process_service")
+	var firstErr error
+	failed := 0
+	for _, id := range ids {
+		if err := repo.CalculateProfitability(ctx, id); err != nil {
+			log.Error("erro ao recalcular lucratividade", zap.Error(err), zap.Int("process_id", id))
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if firstErr != nil {
+		return len(ids) - failed, errors.WrapError(firstErr, fmt.Sprintf("falha ao recalcular %d de %d processos", failed, len(ids)))
+	}
+	return len(ids), nil
+}
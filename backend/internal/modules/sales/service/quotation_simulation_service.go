@@ -0,0 +1,63 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	inventory "ERP-ONSMART/backend/internal/modules/inventory/models"
+	inventoryService "ERP-ONSMART/backend/internal/modules/inventory/service"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+)
+
+// Dependências da simulação de margem; substituídas nos testes
+var (
+	loadQuotation = getQuotation
+	estimateCosts = inventoryService.EstimateCosts
+)
+
+// getQuotation busca a cotação com os itens
+func getQuotation(ctx context.Context, id int) (*models.Quotation, error) {
+	conn, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+	return repository.NewQuotationRepository(conn, logger.WithModule("quotation_repository")).GetQuotationByID(ctx, id)
+}
+
+// SimulateQuotation recalcula a margem das linhas e da cotação com descontos, preços, quantidades
+// ou custos hipotéticos, sem gravar nada. O custo vem do custeio do estoque (camadas no FIFO,
+// custo médio no médio), como o CMV seria apurado se a venda saísse agora.
+func SimulateQuotation(ctx context.Context, id int, input models.QuotationSimulationInput) (*models.QuotationSimulation, error) {
+	quotation, err := loadQuotation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := input.PrepareSimulation(quotation)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make([]inventory.CostQuery, 0, len(lines))
+	simulated := make([]inventory.CostQuery, 0, len(lines))
+	for _, line := range lines {
+		currentQuantity, simulatedQuantity := line.StockQuantities()
+		current = append(current, inventory.CostQuery{ProductID: line.ProductID, Quantity: currentQuantity})
+		simulated = append(simulated, inventory.CostQuery{ProductID: line.ProductID, Quantity: simulatedQuantity})
+	}
+	currentCosts, err := estimateCosts(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	simulatedCosts, err := estimateCosts(ctx, simulated)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range lines {
+		lines[i].ApplyCosts(currentCosts[i].TotalCost, simulatedCosts[i].TotalCost,
+			simulatedCosts[i].Method, input.CostChangePercent)
+	}
+	return models.SummarizeSimulation(quotation, lines, input.CostChangePercent), nil
+}
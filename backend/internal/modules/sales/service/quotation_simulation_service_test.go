@@ -0,0 +1,52 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	inventory "ERP-ONSMART/backend/internal/modules/inventory/models"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateQuotation(t *testing.T) {
+	originalLoad, originalEstimate := loadQuotation, estimateCosts
+	t.Cleanup(func() { loadQuotation, estimateCosts = originalLoad, originalEstimate })
+
+	loadQuotation = func(ctx context.Context, id int) (*models.Quotation, error) {
+		if id != 12 {
+			return nil, errors.ErrQuotationNotFound
+		}
+		return &models.Quotation{ID: 12, QuotationNo: "QT-12", Items: []models.QuotationItem{
+			{ID: 1, ProductID: 200, Quantity: 3, Unit: "CX", UnitFactor: 10, UnitPrice: money.FromInt(200)},
+		}}, nil
+	}
+	var queries [][]inventory.CostQuery
+	estimateCosts = func(ctx context.Context, q []inventory.CostQuery) ([]inventory.CostEstimate, error) {
+		queries = append(queries, q)
+		estimates := make([]inventory.CostEstimate, len(q))
+		for i, query := range q {
+			estimates[i] = inventory.CostEstimate{ProductID: query.ProductID, Quantity: query.Quantity, Method: "average", TotalCost: money.MustParse("12.5").MulInt(query.Quantity)}
+		}
+		return estimates, nil
+	}
+
+	quantity := 5
+	simulation, err := SimulateQuotation(context.Background(), 12, models.QuotationSimulationInput{
+		Lines: []models.QuotationSimulationLine{{ItemID: 1, Quantity: &quantity}},
+	})
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, 30, queries[0][0].Quantity, "custo atual pela quantidade gravada, em unidades de estoque")
+	assert.Equal(t, 50, queries[1][0].Quantity, "custo simulado pela nova quantidade")
+	assert.Equal(t, "375.00", simulation.Current.Cost.String())
+	assert.Equal(t, "625.00", simulation.Simulated.Cost.String())
+	assert.Equal(t, "375.00", simulation.Simulated.Margin.String())
+	assert.Equal(t, "average", simulation.Lines[0].CostMethod)
+
+	_, err = SimulateQuotation(context.Background(), 99, models.QuotationSimulationInput{})
+	assert.Equal(t, errors.ErrQuotationNotFound, err)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+)
+
+// SalesService concentra as regras do pedido de venda: conversão da cotação, liberação do
+// crédito retido e acompanhamento do atendimento
+type SalesService interface {
+	ConvertQuotationToSalesOrder(ctx context.Context, quotationID int, opts models.SalesOrderConversion) (*models.SalesOrder, error)
+	ApproveCreditHold(ctx context.Context, salesOrderID int, approvedBy string) (*models.SalesOrder, error)
+	GetSalesOrderFulfillment(ctx context.Context, id int) (*models.SalesOrderFulfillment, error)
+}
+
+type salesService struct {
+	conversions repository.DocumentConversionRepository
+	orders      repository.SalesOrderRepository
+	tx          db.TxManager
+}
+
+// NewSalesService cria o serviço de pedidos de venda sobre os repositórios informados; tx abre a
+// unidade de trabalho das conversões que passam por mais de um repositório
+func NewSalesService(conversions repository.DocumentConversionRepository, orders repository.SalesOrderRepository, tx db.TxManager) SalesService {
+	return &salesService{conversions: conversions, orders: orders, tx: tx}
+}
+
+// ConvertQuotationToSalesOrder gera o pedido de venda a partir da cotação e o vincula ao
+// processo de venda em uma única transação
+func ConvertQuotationToSalesOrder(ctx context.Context, quotationID int, opts models.SalesOrderConversion) (*models.SalesOrder, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Sales.ConvertQuotationToSalesOrder(ctx, quotationID, opts)
+}
+
+// ApproveCreditHold libera o pedido de venda retido pelo limite de crédito do cliente
+func ApproveCreditHold(ctx context.Context, salesOrderID int, approvedBy string) (*models.SalesOrder, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Sales.ApproveCreditHold(ctx, salesOrderID, approvedBy)
+}
+
+// GetSalesOrderFulfillment retorna as quantidades entregues, pendentes e em backorder de cada linha do pedido
+func GetSalesOrderFulfillment(ctx context.Context, id int) (*models.SalesOrderFulfillment, error) {
+	services, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return services.Sales.GetSalesOrderFulfillment(ctx, id)
+}
+
+// ConvertQuotationToSalesOrder gera o pedido e o vincula ao processo na mesma unidade de trabalho:
+// se o vínculo falhar, o pedido, os itens e a aceitação da cotação são desfeitos
+func (s *salesService) ConvertQuotationToSalesOrder(ctx context.Context, quotationID int, opts models.SalesOrderConversion) (*models.SalesOrder, error) {
+	var salesOrder *models.SalesOrder
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		salesOrder, err = s.conversions.ConvertQuotationToSalesOrder(ctx, quotationID, opts)
+		if err != nil {
+			return err
+		}
+		return s.conversions.LinkSalesOrderToProcess(ctx, salesOrder)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return salesOrder, nil
+}
+
+func (s *salesService) ApproveCreditHold(ctx context.Context, salesOrderID int, approvedBy string) (*models.SalesOrder, error) {
+	return s.conversions.ApproveCreditHold(ctx, salesOrderID, approvedBy)
+}
+
+func (s *salesService) GetSalesOrderFulfillment(ctx context.Context, id int) (*models.SalesOrderFulfillment, error) {
+	return s.orders.GetSalesOrderFulfillment(ctx, id)
+}
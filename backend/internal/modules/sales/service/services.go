@@ -0,0 +1,68 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"sync"
+	"time"
+)
+
+// Services reúne os serviços do processo de venda com os repositórios e o relógio que usam.
+// O main.go monta os serviços uma vez e os registra com Configure.
+type Services struct {
+	Sales      SalesService
+	Invoices   InvoiceService
+	Deliveries DeliveryService
+}
+
+// NewServices abre os repositórios de vendas e monta os serviços com o relógio do sistema
+func NewServices() (*Services, error) {
+	conversions, err := repository.NewDocumentConversionRepository()
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := repository.NewDeliveryRepository()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+	orders := repository.NewSalesOrderRepository(conn, logger.WithModule("sales_order_repository"))
+
+	return &Services{
+		Sales:      NewSalesService(conversions, orders, db.NewTxManagerWithDB(conn)),
+		Invoices:   NewInvoiceService(conversions, time.Now),
+		Deliveries: NewDeliveryService(conversions, deliveries, time.Now),
+	}, nil
+}
+
+var (
+	servicesMu sync.Mutex
+	configured *Services
+)
+
+// Configure registra os serviços usados pelas funções do pacote (e pelos handlers)
+func Configure(services *Services) {
+	servicesMu.Lock()
+	defer servicesMu.Unlock()
+	configured = services
+}
+
+// current retorna os serviços registrados; sem Configure (ferramentas, testes), os repositórios
+// são abertos na primeira chamada e reaproveitados nas seguintes
+func current() (*Services, error) {
+	servicesMu.Lock()
+	defer servicesMu.Unlock()
+	if configured == nil {
+		services, err := NewServices()
+		if err != nil {
+			return nil, err
+		}
+		configured = services
+	}
+	return configured, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixedNow = time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+
+func fixedClock() time.Time { return fixedNow }
+
+// fakeConversions guarda as opções recebidas pelas gerações de documentos
+type fakeConversions struct {
+	repository.DocumentConversionRepository
+	invoiceOpts  *models.InvoiceGeneration
+	advanceOpts  *models.AdvanceInvoiceGeneration
+	deliveryOpts *models.DeliveryGeneration
+	linkErr      error
+	steps        []string
+}
+
+func (f *fakeConversions) ConvertQuotationToSalesOrder(ctx context.Context, quotationID int, _ models.SalesOrderConversion) (*models.SalesOrder, error) {
+	f.steps = append(f.steps, "convert:"+ctx.Value(txMarker{}).(string))
+	return &models.SalesOrder{ID: 7, QuotationID: quotationID}, nil
+}
+
+func (f *fakeConversions) LinkSalesOrderToProcess(ctx context.Context, salesOrder *models.SalesOrder) error {
+	f.steps = append(f.steps, "link:"+ctx.Value(txMarker{}).(string))
+	return f.linkErr
+}
+
+type txMarker struct{}
+
+// recordingTx marca o contexto da unidade de trabalho e guarda o erro que a desfez
+type recordingTx struct {
+	rolledBack error
+}
+
+func (r *recordingTx) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(context.WithValue(ctx, txMarker{}, "tx"))
+	r.rolledBack = err
+	return err
+}
+
+func (f *fakeConversions) GenerateInvoice(_ context.Context, salesOrderID int, opts models.InvoiceGeneration) (*models.Invoice, error) {
+	f.invoiceOpts = &opts
+	return &models.Invoice{SalesOrderID: salesOrderID, IssueDate: opts.IssueDate}, nil
+}
+
+func (f *fakeConversions) GenerateAdvanceInvoice(_ context.Context, salesOrderID int, opts models.AdvanceInvoiceGeneration) (*models.Invoice, error) {
+	f.advanceOpts = &opts
+	return &models.Invoice{SalesOrderID: salesOrderID, Kind: models.InvoiceKindAdvance, IssueDate: opts.IssueDate}, nil
+}
+
+func (f *fakeConversions) GenerateDelivery(_ context.Context, salesOrderID int, opts models.DeliveryGeneration) (*models.Delivery, error) {
+	f.deliveryOpts = &opts
+	return &models.Delivery{SalesOrderID: salesOrderID, DeliveryDate: opts.DeliveryDate}, nil
+}
+
+// fakeDeliveries registra o envio da entrega
+type fakeDeliveries struct {
+	repository.DeliveryRepository
+	shipped string
+}
+
+func (f *fakeDeliveries) MarkAsShipped(_ context.Context, _ int, trackingNumber string) error {
+	f.shipped = trackingNumber
+	return nil
+}
+
+func TestGenerateInvoiceDefaultsIssueDateToClock(t *testing.T) {
+	conversions := &fakeConversions{}
+	invoices := NewInvoiceService(conversions, fixedClock)
+
+	invoice, err := invoices.GenerateInvoice(context.Background(), 3, models.InvoiceGeneration{})
+	require.NoError(t, err)
+	assert.Equal(t, fixedNow, invoice.IssueDate)
+	assert.True(t, conversions.invoiceOpts.DueDate.IsZero(), "o vencimento padrão fica com o calendário da empresa")
+}
+
+func TestGenerateInvoiceRejectsDueDateBeforeIssue(t *testing.T) {
+	conversions := &fakeConversions{}
+	invoices := NewInvoiceService(conversions, fixedClock)
+
+	_, err := invoices.GenerateInvoice(context.Background(), 3, models.InvoiceGeneration{
+		IssueDate: fixedNow,
+		DueDate:   fixedNow.AddDate(0, 0, -1),
+	})
+	assert.Equal(t, errors.ErrInvalidDateRange, err)
+	assert.Nil(t, conversions.invoiceOpts, "datas inválidas não chegam ao repositório")
+}
+
+func TestGenerateAdvanceInvoiceDefaultsIssueDateAndChecksDates(t *testing.T) {
+	conversions := &fakeConversions{}
+	invoices := NewInvoiceService(conversions, fixedClock)
+
+	down := models.DownPayment{Percent: 30}
+	invoice, err := invoices.GenerateAdvanceInvoice(context.Background(), 3, models.AdvanceInvoiceGeneration{DownPayment: down})
+	require.NoError(t, err)
+	assert.Equal(t, fixedNow, invoice.IssueDate)
+	assert.Equal(t, down, conversions.advanceOpts.DownPayment)
+
+	conversions.advanceOpts = nil
+	_, err = invoices.GenerateAdvanceInvoice(context.Background(), 3, models.AdvanceInvoiceGeneration{
+		DownPayment: down,
+		IssueDate:   fixedNow,
+		DueDate:     fixedNow.AddDate(0, 0, -1),
+	})
+	assert.Equal(t, errors.ErrInvalidDateRange, err)
+	assert.Nil(t, conversions.advanceOpts)
+}
+
+func TestGenerateDeliveryDefaultsDateToClock(t *testing.T) {
+	conversions := &fakeConversions{}
+	deliveries := NewDeliveryService(conversions, &fakeDeliveries{}, fixedClock)
+
+	delivery, err := deliveries.GenerateDelivery(context.Background(), 5, models.DeliveryGeneration{})
+	require.NoError(t, err)
+	assert.Equal(t, fixedNow, delivery.DeliveryDate)
+
+	scheduled := fixedNow.AddDate(0, 0, 2)
+	delivery, err = deliveries.GenerateDelivery(context.Background(), 5, models.DeliveryGeneration{DeliveryDate: scheduled})
+	require.NoError(t, err)
+	assert.Equal(t, scheduled, delivery.DeliveryDate)
+}
+
+func TestConvertQuotationRunsConversionAndLinkInOneTransaction(t *testing.T) {
+	conversions := &fakeConversions{}
+	tx := &recordingTx{}
+	sales := NewSalesService(conversions, nil, tx)
+
+	salesOrder, err := sales.ConvertQuotationToSalesOrder(context.Background(), 4, models.SalesOrderConversion{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, salesOrder.QuotationID)
+	assert.Equal(t, []string{"convert:tx", "link:tx"}, conversions.steps)
+
+	conversions.steps = nil
+	conversions.linkErr = errors.ErrQuotationNotFound
+	salesOrder, err = sales.ConvertQuotationToSalesOrder(context.Background(), 4, models.SalesOrderConversion{})
+	assert.Equal(t, errors.ErrQuotationNotFound, err)
+	assert.Nil(t, salesOrder, "o pedido desfeito não é devolvido")
+	assert.Equal(t, errors.ErrQuotationNotFound, tx.rolledBack)
+}
+
+func TestConfiguredServicesServePackageFunctions(t *testing.T) {
+	deliveryRepo := &fakeDeliveries{}
+	Configure(&Services{Deliveries: NewDeliveryService(&fakeConversions{}, deliveryRepo, fixedClock)})
+	t.Cleanup(func() { Configure(nil) })
+
+	require.NoError(t, ShipDelivery(context.Background(), 9, "BR123"))
+	assert.Equal(t, "BR123", deliveryRepo.shipped)
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/serviceorders/models"
+	"ERP-ONSMART/backend/internal/modules/serviceorders/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+const serviceOrderDateLayout = "2006-01-02"
+
+// Lista as ordens de serviço
+// Os filtros from/to selecionam as ordens com agendamento no período (agenda dos técnicos).
+// @Param status query string false "Status da ordem"
+// @Param technician_id query int false "Técnico agendado"
+// @Param serial_number query string false "Número de série do equipamento"
+// @Param from query string false "Agendamentos a partir de (AAAA-MM-DD)"
+// @Param to query string false "Agendamentos até (AAAA-MM-DD)"
+// @Security BearerAuth
+func ListServiceOrdersHandler(c *gin.Context) {
+	filter := models.ServiceOrderFilter{
+		Status:       c.Query("status"),
+		SerialNumber: c.Query("serial_number"),
+	}
+	if value := c.Query("technician_id"); value != "" {
+		technicianID, err := strconv.Atoi(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("técnico inválido").WithDetails(err.Error()))
+			return
+		}
+		filter.TechnicianID = technicianID
+	}
+
+	var err error
+	if filter.From, err = parseServiceOrderDate(c.Query("from")); err != nil {
+		c.Error(errors.InvalidParam("data inicial inválida").WithDetails(err.Error()))
+		return
+	}
+	if filter.To, err = parseServiceOrderDate(c.Query("to")); err != nil {
+		c.Error(errors.InvalidParam("data final inválida").WithDetails(err.Error()))
+		return
+	}
+	if !filter.To.IsZero() {
+		// Inclui o dia final inteiro
+		filter.To = filter.To.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	params := pagination.NewPaginationParams(c.Request)
+	result, err := service.GetAllServiceOrders(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar ordens de serviço")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Abre uma ordem de serviço para um item faturado
+// O item deve pertencer a uma fatura emitida; a garantia é calculada a partir da emissão da fatura.
+// @Security BearerAuth
+func CreateServiceOrderHandler(c *gin.Context) {
+	var input service.CreateServiceOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	order, err := service.CreateServiceOrder(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"service_order": order})
+}
+
+// Retorna uma ordem de serviço com as peças e as horas apontadas
+// @Security BearerAuth
+func GetServiceOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := service.GetServiceOrder(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service_order": order})
+}
+
+// Agenda o técnico para o atendimento
+// Recusa horários em que o técnico já tem outra ordem agendada ou em andamento.
+// @Security BearerAuth
+func ScheduleServiceOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.ScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	order, err := service.ScheduleServiceOrder(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao agendar ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service_order": order})
+}
+
+// Inicia o atendimento
+// @Success 200 "Atendimento iniciado"
+// @Security BearerAuth
+func StartServiceOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := service.StartServiceOrder(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao iniciar ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service_order": order})
+}
+
+// Conclui o atendimento registrando a solução
+// @Success 200 "Atendimento concluído"
+// @Security BearerAuth
+func CompleteServiceOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Resolution string `json:"resolution" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	order, err := service.CompleteServiceOrder(c.Request.Context(), id, req.Resolution)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao concluir ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service_order": order})
+}
+
+// Cancela a ordem de serviço antes do início do atendimento
+// @Success 200 "Ordem cancelada"
+// @Security BearerAuth
+func CancelServiceOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := service.CancelServiceOrder(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cancelar ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service_order": order})
+}
+
+// Registra uma peça consumida no atendimento
+// A quantidade é baixada do estoque do produto; sem saldo suficiente a peça é recusada.
+// @Security BearerAuth
+func AddServiceOrderPartHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.AddPartInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	order, err := service.AddPart(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao registrar peça da ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"service_order": order})
+}
+
+// Aponta horas de mão de obra no atendimento
+// @Security BearerAuth
+func AddServiceOrderLaborHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.AddLaborInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+	workDate, err := parseServiceOrderDate(input.WorkDate)
+	if err != nil {
+		c.Error(errors.InvalidParam("data do apontamento inválida").WithDetails(err.Error()))
+		return
+	}
+
+	order, err := service.AddLabor(c.Request.Context(), id, input, workDate)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao apontar horas da ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"service_order": order})
+}
+
+// Gera a fatura do atendimento fora da garantia
+// A fatura em rascunho traz uma linha por peça consumida e uma linha com as horas pelo valor da hora da ordem.
+// @Security BearerAuth
+func BillServiceOrderHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invoice, err := service.BillServiceOrder(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao faturar ordem de serviço")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invoice": invoice})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+// parseServiceOrderDate converte o parâmetro de data; vazio resulta em data zero
+func parseServiceOrderDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(serviceOrderDateLayout, value)
+}
@@ -0,0 +1,224 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Status da ordem de serviço
+const (
+	ServiceOrderStatusOpen       = "open"
+	ServiceOrderStatusScheduled  = "scheduled"
+	ServiceOrderStatusInProgress = "in_progress"
+	ServiceOrderStatusCompleted  = "completed"
+	ServiceOrderStatusBilled     = "billed"
+	ServiceOrderStatusCancelled  = "cancelled"
+)
+
+// serviceOrderTransitions define as transições de status permitidas; reagendar mantém o status
+var serviceOrderTransitions = map[string][]string{
+	ServiceOrderStatusOpen:       {ServiceOrderStatusScheduled, ServiceOrderStatusInProgress, ServiceOrderStatusCancelled},
+	ServiceOrderStatusScheduled:  {ServiceOrderStatusScheduled, ServiceOrderStatusInProgress, ServiceOrderStatusCancelled},
+	ServiceOrderStatusInProgress: {ServiceOrderStatusCompleted},
+	ServiceOrderStatusCompleted:  {ServiceOrderStatusBilled},
+}
+
+// ServiceOrder é um chamado de assistência técnica pós-venda de um item faturado
+type ServiceOrder struct {
+	ID              int                 `json:"id" gorm:"primaryKey"`
+	CompanyID       int                 `json:"company_id" gorm:"<-:create"`
+	OrderNo         string              `json:"order_no"`
+	ContactID       int                 `json:"contact_id" gorm:"index"`
+	InvoiceID       int                 `json:"invoice_id" gorm:"index"`
+	InvoiceItemID   int                 `json:"invoice_item_id" gorm:"index"`
+	ProductID       int                 `json:"product_id"`
+	SerialNumber    string              `json:"serial_number" gorm:"index"`
+	Issue           string              `json:"issue"`
+	Status          string              `json:"status" gorm:"default:open"`
+	UnderWarranty   bool                `json:"under_warranty"`
+	WarrantyUntil   *time.Time          `json:"warranty_until,omitempty" gorm:"type:date"`
+	TechnicianID    *int                `json:"technician_id,omitempty"`
+	ScheduledStart  *time.Time          `json:"scheduled_start,omitempty"`
+	ScheduledEnd    *time.Time          `json:"scheduled_end,omitempty"`
+	LaborRate       money.Decimal       `json:"labor_rate"`
+	Resolution      string              `json:"resolution,omitempty"`
+	BilledInvoiceID *int                `json:"billed_invoice_id,omitempty"`
+	OpenedAt        time.Time           `json:"opened_at"`
+	CompletedAt     *time.Time          `json:"completed_at,omitempty"`
+	CreatedAt       time.Time           `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+	Parts           []ServiceOrderPart  `json:"parts,omitempty" gorm:"foreignKey:ServiceOrderID"`
+	Labor           []ServiceOrderLabor `json:"labor,omitempty" gorm:"foreignKey:ServiceOrderID"`
+}
+
+// TableName define o nome da tabela de ordens de serviço
+func (ServiceOrder) TableName() string {
+	return "service_orders"
+}
+
+// ServiceOrderPart é uma peça consumida no atendimento, baixada do estoque
+type ServiceOrderPart struct {
+	ID             int           `json:"id" gorm:"primaryKey"`
+	ServiceOrderID int           `json:"service_order_id" gorm:"index"`
+	ProductID      int           `json:"product_id"`
+	ProductName    string        `json:"product_name"`
+	ProductCode    string        `json:"product_code"`
+	Quantity       int           `json:"quantity"`
+	UnitPrice      money.Decimal `json:"unit_price"`
+	Total          money.Decimal `json:"total"`
+	ConsumedAt     time.Time     `json:"consumed_at"`
+}
+
+// TableName define o nome da tabela de peças consumidas
+func (ServiceOrderPart) TableName() string {
+	return "service_order_parts"
+}
+
+// ServiceOrderLabor é um apontamento de horas de mão de obra
+type ServiceOrderLabor struct {
+	ID             int       `json:"id" gorm:"primaryKey"`
+	ServiceOrderID int       `json:"service_order_id" gorm:"index"`
+	TechnicianID   *int      `json:"technician_id,omitempty"`
+	WorkDate       time.Time `json:"work_date" gorm:"type:date"`
+	Hours          float64   `json:"hours"`
+	Description    string    `json:"description,omitempty"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName define o nome da tabela de horas apontadas
+func (ServiceOrderLabor) TableName() string {
+	return "service_order_labor"
+}
+
+// ServiceOrderFilter são os filtros da listagem de ordens de serviço
+type ServiceOrderFilter struct {
+	Status       string
+	TechnicianID int
+	SerialNumber string
+	From         time.Time // agendamentos a partir de
+	To           time.Time // agendamentos até
+}
+
+// CanTransition indica se a ordem pode passar do status atual para o novo status
+func CanTransition(from, to string) bool {
+	for _, allowed := range serviceOrderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsWork indica se a ordem ainda aceita peças e horas (antes da conclusão)
+func AcceptsWork(status string) bool {
+	switch status {
+	case ServiceOrderStatusOpen, ServiceOrderStatusScheduled, ServiceOrderStatusInProgress:
+		return true
+	default:
+		return false
+	}
+}
+
+// WarrantyUntil calcula o fim da garantia a partir da emissão da fatura; sem garantia
+// cadastrada para o produto (months <= 0) retorna nil
+func WarrantyUntil(issueDate time.Time, months int) *time.Time {
+	if months <= 0 {
+		return nil
+	}
+	until := issueDate.AddDate(0, months, 0)
+	return &until
+}
+
+// IsUnderWarranty indica se o chamado aberto em openedAt está coberto pela garantia; o último
+// dia da garantia ainda é coberto
+func IsUnderWarranty(until *time.Time, openedAt time.Time) bool {
+	if until == nil {
+		return false
+	}
+	lastDay := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, until.Location())
+	return openedAt.Before(lastDay.AddDate(0, 0, 1))
+}
+
+// ValidateSchedule confere o período do agendamento
+func ValidateSchedule(start, end time.Time) error {
+	if start.IsZero() || !end.After(start) {
+		return errors.ErrInvalidSchedule
+	}
+	return nil
+}
+
+// Overlaps indica se os períodos [aStart, aEnd) e [bStart, bEnd) se sobrepõem
+func Overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+// LaborHours soma as horas apontadas na ordem
+func LaborHours(labor []ServiceOrderLabor) float64 {
+	var hours float64
+	for _, entry := range labor {
+		hours += entry.Hours
+	}
+	return round2(hours)
+}
+
+// InvoiceFromServiceOrder monta a fatura (rascunho) do atendimento fora da garantia: uma linha
+// por peça consumida e uma linha com o total da mão de obra, referenciando o produto atendido
+func InvoiceFromServiceOrder(order *ServiceOrder, productName, productCode string) (*sales.Invoice, error) {
+	if order.UnderWarranty {
+		return nil, errors.ErrServiceOrderUnderWarranty
+	}
+
+	invoice := &sales.Invoice{
+		ContactID: order.ContactID,
+		Status:    sales.InvoiceStatusDraft,
+		Notes:     fmt.Sprintf("Ordem de serviço %s (série %s)", order.OrderNo, order.SerialNumber),
+		Items:     make([]sales.InvoiceItem, 0, len(order.Parts)+1),
+	}
+
+	var totals sales.DocumentTotals
+	for _, part := range order.Parts {
+		unitPrice := part.UnitPrice
+		invoice.Items = append(invoice.Items, sales.InvoiceItem{
+			ProductID:   part.ProductID,
+			ProductName: part.ProductName,
+			ProductCode: part.ProductCode,
+			Description: "Peça - " + order.OrderNo,
+			Quantity:    part.Quantity,
+			UnitPrice:   unitPrice,
+			Total:       sales.LineTotal(part.Quantity, unitPrice, money.Zero, money.Zero),
+		})
+		totals.Add(part.Quantity, unitPrice, money.Zero, money.Zero)
+	}
+
+	hours := LaborHours(order.Labor)
+	if labor := money.Round(order.LaborRate.MulFloat(hours)); labor.IsPositive() {
+		invoice.Items = append(invoice.Items, sales.InvoiceItem{
+			ProductID:   order.ProductID,
+			ProductName: "Mão de obra - " + productName,
+			ProductCode: productCode,
+			Description: fmt.Sprintf("%.2f h x %s", hours, order.LaborRate.StringFixed(2)),
+			Quantity:    1,
+			UnitPrice:   labor,
+			Total:       labor,
+		})
+		totals.Add(1, labor, money.Zero, money.Zero)
+	}
+
+	if len(invoice.Items) == 0 || !totals.GrandTotal.IsPositive() {
+		return nil, errors.ErrNothingToBill
+	}
+
+	invoice.SubTotal = totals.SubTotal
+	invoice.TaxTotal = totals.TaxTotal
+	invoice.DiscountTotal = totals.DiscountTotal
+	invoice.GrandTotal = totals.GrandTotal
+	return invoice, nil
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
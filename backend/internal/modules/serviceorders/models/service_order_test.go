@@ -0,0 +1,90 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(ServiceOrderStatusOpen, ServiceOrderStatusScheduled))
+	assert.True(t, CanTransition(ServiceOrderStatusScheduled, ServiceOrderStatusScheduled))
+	assert.True(t, CanTransition(ServiceOrderStatusScheduled, ServiceOrderStatusInProgress))
+	assert.True(t, CanTransition(ServiceOrderStatusInProgress, ServiceOrderStatusCompleted))
+	assert.True(t, CanTransition(ServiceOrderStatusCompleted, ServiceOrderStatusBilled))
+
+	assert.False(t, CanTransition(ServiceOrderStatusOpen, ServiceOrderStatusCompleted))
+	assert.False(t, CanTransition(ServiceOrderStatusInProgress, ServiceOrderStatusCancelled))
+	assert.False(t, CanTransition(ServiceOrderStatusBilled, ServiceOrderStatusCompleted))
+	assert.False(t, CanTransition(ServiceOrderStatusCancelled, ServiceOrderStatusOpen))
+
+	assert.True(t, AcceptsWork(ServiceOrderStatusInProgress))
+	assert.False(t, AcceptsWork(ServiceOrderStatusCompleted))
+}
+
+func TestWarranty(t *testing.T) {
+	issued := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, WarrantyUntil(issued, 0))
+
+	until := WarrantyUntil(issued, 12)
+	require.NotNil(t, until)
+	assert.Equal(t, time.Date(2027, 1, 15, 10, 0, 0, 0, time.UTC), *until)
+
+	assert.True(t, IsUnderWarranty(until, time.Date(2027, 1, 15, 23, 59, 0, 0, time.UTC)))
+	assert.False(t, IsUnderWarranty(until, time.Date(2027, 1, 16, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, IsUnderWarranty(nil, issued))
+}
+
+func TestSchedule(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	assert.NoError(t, ValidateSchedule(start, end))
+	assert.ErrorIs(t, ValidateSchedule(end, start), errors.ErrInvalidSchedule)
+	assert.ErrorIs(t, ValidateSchedule(time.Time{}, end), errors.ErrInvalidSchedule)
+
+	assert.True(t, Overlaps(start, end, start.Add(time.Hour), end.Add(time.Hour)))
+	// Atendimentos encostados não conflitam
+	assert.False(t, Overlaps(start, end, end, end.Add(time.Hour)))
+}
+
+func TestInvoiceFromServiceOrder(t *testing.T) {
+	order := &ServiceOrder{
+		OrderNo:      "OS-2026-000001",
+		ContactID:    7,
+		ProductID:    3,
+		SerialNumber: "SN123",
+		LaborRate:    money.FromInt(80),
+		Parts: []ServiceOrderPart{
+			{ProductID: 9, ProductName: "Fonte", ProductCode: "FNT-1", Quantity: 2, UnitPrice: money.MustParse("45.5")},
+		},
+		Labor: []ServiceOrderLabor{{Hours: 1.5}, {Hours: 0.75}},
+	}
+
+	invoice, err := InvoiceFromServiceOrder(order, "Notebook", "NB-1")
+	require.NoError(t, err)
+	require.Len(t, invoice.Items, 2)
+
+	assert.Equal(t, 7, invoice.ContactID)
+	assert.Equal(t, sales.InvoiceStatusDraft, invoice.Status)
+	assert.Equal(t, "91.00", invoice.Items[0].Total.String())
+	assert.Equal(t, 3, invoice.Items[1].ProductID)
+	assert.Equal(t, "Mão de obra - Notebook", invoice.Items[1].ProductName)
+	assert.Equal(t, "180.00", invoice.Items[1].UnitPrice.String())
+	assert.Equal(t, "271.00", invoice.GrandTotal.String())
+}
+
+func TestInvoiceFromServiceOrderRejected(t *testing.T) {
+	_, err := InvoiceFromServiceOrder(&ServiceOrder{UnderWarranty: true, LaborRate: money.FromInt(80),
+		Labor: []ServiceOrderLabor{{Hours: 1}}}, "Notebook", "NB-1")
+	assert.ErrorIs(t, err, errors.ErrServiceOrderUnderWarranty)
+
+	_, err = InvoiceFromServiceOrder(&ServiceOrder{Labor: []ServiceOrderLabor{{Hours: 1}}}, "Notebook", "NB-1")
+	assert.ErrorIs(t, err, errors.ErrNothingToBill)
+}
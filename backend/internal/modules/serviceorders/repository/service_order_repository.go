@@ -0,0 +1,494 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	calendarRepository "ERP-ONSMART/backend/internal/modules/calendar/repository"
+	contactRepository "ERP-ONSMART/backend/internal/modules/contact/repository"
+	products "ERP-ONSMART/backend/internal/modules/products/repository"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/modules/serviceorders/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ServiceOrderRepository define as operações das ordens de serviço (assistência técnica)
+type ServiceOrderRepository interface {
+	CreateServiceOrder(ctx context.Context, order *models.ServiceOrder) (*models.ServiceOrder, error)
+	GetServiceOrderByID(ctx context.Context, id int) (*models.ServiceOrder, error)
+	GetAllServiceOrders(ctx context.Context, filter models.ServiceOrderFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	Schedule(ctx context.Context, id, technicianID int, start, end time.Time) (*models.ServiceOrder, error)
+	ChangeStatus(ctx context.Context, id int, status, resolution string) (*models.ServiceOrder, error)
+	AddPart(ctx context.Context, id, productID, quantity int) (*models.ServiceOrder, error)
+	AddLabor(ctx context.Context, id int, labor models.ServiceOrderLabor) (*models.ServiceOrder, error)
+	Bill(ctx context.Context, id int) (*sales.Invoice, error)
+}
+
+// busyStatuses são os status em que o técnico agendado está reservado para a ordem
+var busyStatuses = []string{models.ServiceOrderStatusScheduled, models.ServiceOrderStatusInProgress}
+
+type serviceOrderRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewServiceOrderRepository cria uma nova instância do repositório
+func NewServiceOrderRepository() (ServiceOrderRepository, error) {
+	gormDB, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &serviceOrderRepository{
+		db:     gormDB,
+		logger: logger.WithModule("service_order_repository"),
+	}, nil
+}
+
+// CreateServiceOrder abre o chamado para um item de fatura emitida; o contato, a fatura e o
+// produto vêm do item, e a garantia é calculada pela maior garantia cadastrada para o produto
+func (r *serviceOrderRepository) CreateServiceOrder(ctx context.Context, order *models.ServiceOrder) (*models.ServiceOrder, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	var item sales.InvoiceItem
+	if err := tx.First(&item, order.InvoiceItemID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrServiceItemNotInvoiced
+		}
+		return nil, errors.WrapError(err, "falha ao buscar item da fatura")
+	}
+
+	var invoice sales.Invoice
+	if err := tx.Select("id", "contact_id", "status", "issue_date").First(&invoice, item.InvoiceID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrServiceItemNotInvoiced
+		}
+		return nil, errors.WrapError(err, "falha ao buscar fatura")
+	}
+	if invoice.Status == sales.InvoiceStatusDraft || invoice.Status == sales.InvoiceStatusCancelled ||
+		(order.ContactID > 0 && order.ContactID != invoice.ContactID) {
+		tx.Rollback()
+		return nil, errors.ErrServiceItemNotInvoiced
+	}
+
+	var months int
+	if err := tx.Table("warranties").Scopes(tenant.Scope(ctx, "warranties")).
+		Where("product_id = ?", item.ProductID).
+		Select("COALESCE(MAX(duration_months), 0)").
+		Scan(&months).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar garantia do produto")
+	}
+
+	order.OrderNo = salesRepository.NextDocumentNumber(tx, &models.ServiceOrder{}, "OS")
+	order.ContactID = invoice.ContactID
+	order.InvoiceID = invoice.ID
+	order.ProductID = item.ProductID
+	order.Status = models.ServiceOrderStatusOpen
+	order.OpenedAt = time.Now()
+	order.WarrantyUntil = models.WarrantyUntil(invoice.IssueDate, months)
+	order.UnderWarranty = models.IsUnderWarranty(order.WarrantyUntil, order.OpenedAt)
+
+	if err := tx.Omit(clause.Associations).Create(order).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar ordem de serviço", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao criar ordem de serviço")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("ordem de serviço criada",
+		zap.Int("id", order.ID), zap.String("order_no", order.OrderNo), zap.Bool("under_warranty", order.UnderWarranty))
+	return r.GetServiceOrderByID(ctx, order.ID)
+}
+
+// GetServiceOrderByID busca a ordem de serviço com as peças e as horas apontadas
+func (r *serviceOrderRepository) GetServiceOrderByID(ctx context.Context, id int) (*models.ServiceOrder, error) {
+	var order models.ServiceOrder
+	if err := db.Conn(ctx, r.db).
+		Preload("Parts", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Labor", func(db *gorm.DB) *gorm.DB { return db.Order("work_date ASC, id ASC") }).
+		First(&order, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrServiceOrderNotFound
+		}
+		r.logger.Error("erro ao buscar ordem de serviço por ID", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar ordem de serviço")
+	}
+	return &order, nil
+}
+
+// GetAllServiceOrders lista as ordens de serviço com os filtros de status, técnico, número de
+// série e período do agendamento
+func (r *serviceOrderRepository) GetAllServiceOrders(ctx context.Context, filter models.ServiceOrderFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	var orders []models.ServiceOrder
+	var total int64
+
+	query := db.Conn(ctx, r.db).Model(&models.ServiceOrder{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.TechnicianID > 0 {
+		query = query.Where("technician_id = ?", filter.TechnicianID)
+	}
+	if filter.SerialNumber != "" {
+		query = query.Where("serial_number = ?", filter.SerialNumber)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("scheduled_end >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("scheduled_start <= ?", filter.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar ordens de serviço", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar ordens de serviço")
+	}
+
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.
+		Order("opened_at DESC, id DESC").
+		Limit(params.PageSize).
+		Offset(offset).
+		Find(&orders).Error; err != nil {
+		r.logger.Error("erro ao buscar ordens de serviço", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar ordens de serviço")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, orders), nil
+}
+
+// Schedule agenda (ou reagenda) o atendimento com o técnico, recusando horários em que ele já
+// tem outra ordem agendada ou em andamento
+func (r *serviceOrderRepository) Schedule(ctx context.Context, id, technicianID int, start, end time.Time) (*models.ServiceOrder, error) {
+	if err := models.ValidateSchedule(start, end); err != nil {
+		return nil, err
+	}
+
+	tx := db.Conn(ctx, r.db).Begin()
+
+	order, err := r.lockServiceOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !models.CanTransition(order.Status, models.ServiceOrderStatusScheduled) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidServiceOrderStatus
+	}
+
+	var technicians int64
+	if err := tx.Table("users").Scopes(tenant.Scope(ctx, "users")).
+		Where("id = ?", technicianID).
+		Count(&technicians).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar técnico")
+	}
+	if technicians == 0 {
+		tx.Rollback()
+		return nil, errors.ErrUserNotFound
+	}
+
+	// Bloqueia as ordens do técnico para serializar agendamentos concorrentes
+	var busy []models.ServiceOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "scheduled_start", "scheduled_end").
+		Where("technician_id = ? AND id <> ? AND status IN ?", technicianID, id, busyStatuses).
+		Find(&busy).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar agenda do técnico")
+	}
+	for _, other := range busy {
+		if other.ScheduledStart != nil && other.ScheduledEnd != nil &&
+			models.Overlaps(start, end, *other.ScheduledStart, *other.ScheduledEnd) {
+			tx.Rollback()
+			return nil, errors.ErrTechnicianUnavailable
+		}
+	}
+
+	if err := tx.Model(&models.ServiceOrder{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          models.ServiceOrderStatusScheduled,
+			"technician_id":   technicianID,
+			"scheduled_start": start,
+			"scheduled_end":   end,
+		}).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao agendar ordem de serviço", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao agendar ordem de serviço")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("ordem de serviço agendada",
+		zap.Int("id", id), zap.Int("technician_id", technicianID), zap.Time("scheduled_start", start))
+	return r.GetServiceOrderByID(ctx, id)
+}
+
+// ChangeStatus inicia, conclui ou cancela a ordem conforme o fluxo permitido
+func (r *serviceOrderRepository) ChangeStatus(ctx context.Context, id int, status, resolution string) (*models.ServiceOrder, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	order, err := r.lockServiceOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if status == models.ServiceOrderStatusScheduled || status == models.ServiceOrderStatusBilled ||
+		!models.CanTransition(order.Status, status) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidServiceOrderStatus
+	}
+
+	updates := map[string]interface{}{"status": status}
+	if resolution != "" {
+		updates["resolution"] = resolution
+	}
+	if status == models.ServiceOrderStatusCompleted {
+		updates["completed_at"] = time.Now()
+	}
+
+	if err := tx.Model(&models.ServiceOrder{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao atualizar status da ordem de serviço", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao atualizar status da ordem de serviço")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("status da ordem de serviço atualizado", zap.Int("id", id), zap.String("status", status))
+	return r.GetServiceOrderByID(ctx, id)
+}
+
+// AddPart registra a peça consumida e baixa o estoque do produto pelo preço de venda atual
+func (r *serviceOrderRepository) AddPart(ctx context.Context, id, productID, quantity int) (*models.ServiceOrder, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	order, err := r.lockServiceOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !models.AcceptsWork(order.Status) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidServiceOrderStatus
+	}
+
+	var product struct {
+		ID         int
+		Name       string
+		SKU        string
+		Price      money.Decimal
+		SalesPrice money.Decimal
+		Stock      int
+	}
+	if err := tx.Table("products").Scopes(tenant.Scope(ctx, "products")).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, name, sku, price, sales_price, stock").
+		Where("id = ? AND deleted_at IS NULL", productID).
+		Take(&product).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrProductNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar produto")
+	}
+	if product.Stock < quantity {
+		tx.Rollback()
+		return nil, errors.ErrInsufficientStock
+	}
+
+	if err := tx.Table("products").Where("id = ?", productID).
+		Update("stock", gorm.Expr("stock - ?", quantity)).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao baixar peça do estoque", zap.Error(err), zap.Int("product_id", productID))
+		return nil, errors.WrapError(err, "falha ao baixar peça do estoque")
+	}
+
+	unitPrice := product.SalesPrice
+	if !unitPrice.IsPositive() {
+		unitPrice = product.Price
+	}
+	part := models.ServiceOrderPart{
+		ServiceOrderID: id,
+		ProductID:      productID,
+		ProductName:    product.Name,
+		ProductCode:    product.SKU,
+		Quantity:       quantity,
+		UnitPrice:      unitPrice,
+		Total:          sales.LineTotal(quantity, unitPrice, money.Zero, money.Zero),
+		ConsumedAt:     time.Now(),
+	}
+	if err := tx.Create(&part).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao registrar peça da ordem de serviço", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao registrar peça da ordem de serviço")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	// O estoque do produto mudou: descarta a leitura em cache
+	products.InvalidateProducts(productID)
+
+	r.logger.Info("peça consumida na ordem de serviço",
+		zap.Int("id", id), zap.Int("product_id", productID), zap.Int("quantity", quantity))
+	return r.GetServiceOrderByID(ctx, id)
+}
+
+// AddLabor registra as horas trabalhadas; sem técnico informado, vale o técnico agendado
+func (r *serviceOrderRepository) AddLabor(ctx context.Context, id int, labor models.ServiceOrderLabor) (*models.ServiceOrder, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	order, err := r.lockServiceOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !models.AcceptsWork(order.Status) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidServiceOrderStatus
+	}
+
+	labor.ID = 0
+	labor.ServiceOrderID = id
+	if labor.TechnicianID == nil {
+		labor.TechnicianID = order.TechnicianID
+	}
+	if labor.WorkDate.IsZero() {
+		labor.WorkDate = time.Now()
+	}
+	if err := tx.Create(&labor).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao apontar horas da ordem de serviço", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao apontar horas da ordem de serviço")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+	return r.GetServiceOrderByID(ctx, id)
+}
+
+// Bill gera a fatura em rascunho das peças e horas da ordem concluída fora da garantia
+func (r *serviceOrderRepository) Bill(ctx context.Context, id int) (*sales.Invoice, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+
+	order, err := r.lockServiceOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if order.UnderWarranty {
+		tx.Rollback()
+		return nil, errors.ErrServiceOrderUnderWarranty
+	}
+	if !models.CanTransition(order.Status, models.ServiceOrderStatusBilled) {
+		tx.Rollback()
+		return nil, errors.ErrInvalidServiceOrderStatus
+	}
+
+	if err := tx.Where("service_order_id = ?", id).Order("id ASC").Find(&order.Parts).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar peças da ordem de serviço")
+	}
+	if err := tx.Where("service_order_id = ?", id).Find(&order.Labor).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar horas da ordem de serviço")
+	}
+
+	var product struct {
+		Name string
+		SKU  string
+	}
+	if err := tx.Table("products").Select("name, sku").Where("id = ?", order.ProductID).
+		Scan(&product).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao buscar produto atendido")
+	}
+
+	invoice, err := models.InvoiceFromServiceOrder(order, product.Name, product.SKU)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := contactRepository.EnsureNotBlocked(tx, invoice.ContactID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	invoice.InvoiceNo = salesRepository.NextDocumentNumber(tx, &sales.Invoice{}, "INV")
+	invoice.IssueDate = time.Now()
+	schedule, err := calendarRepository.LoadSchedule(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	invoice.DueDate = schedule.PaymentDueDate(invoice.IssueDate, invoice.PaymentTerms)
+
+	// Fatura avulsa: sem pedido de venda de origem
+	if err := tx.Omit(clause.Associations, "SalesOrderID").Create(invoice).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar fatura da ordem de serviço", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao criar fatura da ordem de serviço")
+	}
+	for i := range invoice.Items {
+		invoice.Items[i].InvoiceID = invoice.ID
+	}
+	if err := tx.Omit(clause.Associations).Create(&invoice.Items).Error; err != nil {
+		tx.Rollback()
+		r.logger.Error("erro ao criar itens da fatura da ordem de serviço", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao criar itens da fatura")
+	}
+
+	if err := tx.Model(&models.ServiceOrder{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":            models.ServiceOrderStatusBilled,
+			"billed_invoice_id": invoice.ID,
+		}).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.WrapError(err, "falha ao atualizar status da ordem de serviço")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.Error("erro ao fazer commit da transação", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	r.logger.Info("ordem de serviço faturada",
+		zap.Int("id", id), zap.String("invoice_no", invoice.InvoiceNo), zap.Stringer("grand_total", invoice.GrandTotal))
+	return invoice, nil
+}
+
+// lockServiceOrder busca a ordem de serviço com bloqueio para atualização
+func (r *serviceOrderRepository) lockServiceOrder(tx *gorm.DB, id int) (*models.ServiceOrder, error) {
+	var order models.ServiceOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrServiceOrderNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar ordem de serviço")
+	}
+	return &order, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/serviceorders/models"
+	"ERP-ONSMART/backend/internal/modules/serviceorders/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+)
+
+// CreateServiceOrderInput reúne os dados do chamado de assistência técnica
+type CreateServiceOrderInput struct {
+	InvoiceItemID int           `json:"invoice_item_id" binding:"required"`
+	ContactID     int           `json:"contact_id"`
+	SerialNumber  string        `json:"serial_number" binding:"required"`
+	Issue         string        `json:"issue" binding:"required"`
+	LaborRate     money.Decimal `json:"labor_rate" binding:"gte=0"`
+}
+
+// ScheduleInput é o agendamento do técnico
+type ScheduleInput struct {
+	TechnicianID   int       `json:"technician_id" binding:"required"`
+	ScheduledStart time.Time `json:"scheduled_start" binding:"required"`
+	ScheduledEnd   time.Time `json:"scheduled_end" binding:"required"`
+}
+
+// AddPartInput é a peça consumida no atendimento
+type AddPartInput struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"required,gt=0"`
+}
+
+// AddLaborInput é o apontamento de horas (work_date no formato AAAA-MM-DD; vazio usa hoje)
+type AddLaborInput struct {
+	TechnicianID *int    `json:"technician_id"`
+	WorkDate     string  `json:"work_date"`
+	Hours        float64 `json:"hours" binding:"required,gt=0"`
+	Description  string  `json:"description"`
+}
+
+// CreateServiceOrder abre o chamado para o item faturado
+func CreateServiceOrder(ctx context.Context, input CreateServiceOrderInput) (*models.ServiceOrder, error) {
+	repo, err := repository.NewServiceOrderRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.ServiceOrder{
+		InvoiceItemID: input.InvoiceItemID,
+		ContactID:     input.ContactID,
+		SerialNumber:  input.SerialNumber,
+		Issue:         input.Issue,
+		LaborRate:     input.LaborRate,
+	}
+	return repo.CreateServiceOrder(ctx, order)
+}
+
+// GetServiceOrder retorna a ordem de serviço com peças e horas
+func GetServiceOrder(ctx context.Context, id int) (*models.ServiceOrder, error) {
+	repo, err := repository.NewServiceOrderRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetServiceOrderByID(ctx, id)
+}
+
+// GetAllServiceOrders lista as ordens de serviço com os filtros informados
+func GetAllServiceOrders(ctx context.Context, filter models.ServiceOrderFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewServiceOrderRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAllServiceOrders(ctx, filter, params)
+}
+
+// ScheduleServiceOrder agenda o técnico para o atendimento
+func ScheduleServiceOrder(ctx context.Context, id int, input ScheduleInput) (*models.ServiceOrder, error) {
+	repo, err := repository.NewServiceOrderRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Schedule(ctx, id, input.TechnicianID, input.ScheduledStart, input.ScheduledEnd)
+}
+
+// StartServiceOrder marca o início do atendimento
+func StartServiceOrder(ctx context.Context, id int) (*models.ServiceOrder, error) {
+	return changeStatus(ctx, id, models.ServiceOrderStatusInProgress, "")
+}
+
+// CompleteServiceOrder conclui o atendimento registrando a solução
+func CompleteServiceOrder(ctx context.Context, id int, resolution string) (*models.ServiceOrder, error) {
+	return changeStatus(ctx, id, models.ServiceOrderStatusCompleted, resolution)
+}
+
+// CancelServiceOrder cancela a ordem antes do início do atendimento
+func CancelServiceOrder(ctx context.Context, id int) (*models.ServiceOrder, error) {
+	return changeStatus(ctx, id, models.ServiceOrderStatusCancelled, "")
+}
+
+// AddPart registra a peça consumida, baixando o estoque
+func AddPart(ctx context.Context, id int, input AddPartInput) (*models.ServiceOrder, error) {
+	repo, err := repository.NewServiceOrderRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.AddPart(ctx, id, input.ProductID, input.Quantity)
+}
+
+// AddLabor registra as horas trabalhadas no atendimento
+func AddLabor(ctx context.Context, id int, input AddLaborInput, workDate time.Time) (*models.ServiceOrder, error) {
+	repo, err := repository.NewServiceOrderRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.AddLabor(ctx, id, models.ServiceOrderLabor{
+		TechnicianID: input.TechnicianID,
+		WorkDate:     workDate,
+		Hours:        input.Hours,
+		Description:  input.Description,
+	})
+}
+
+// BillServiceOrder gera a fatura das peças e horas do atendimento fora da garantia
+func BillServiceOrder(ctx context.Context, id int) (*sales.Invoice, error) {
+	repo, err := repository.NewServiceOrderRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Bill(ctx, id)
+}
+
+func changeStatus(ctx context.Context, id int, status, resolution string) (*models.ServiceOrder, error) {
+	repo, err := repository.NewServiceOrderRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ChangeStatus(ctx, id, status, resolution)
+}
@@ -0,0 +1,106 @@
+package carriers
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Nomes das transportadoras integradas
+const (
+	CarrierCorreios = "correios"
+	CarrierJadlog   = "jadlog"
+	CarrierWebhook  = "webhook"
+)
+
+// carrierTime é o fuso dos horários sem fuso informados pelas APIs da Correios e da Jadlog
+// (horário de Brasília); os eventos são gravados em UTC
+var carrierTime = func() *time.Location {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		return time.FixedZone("BRT", -3*60*60)
+	}
+	return loc
+}()
+
+// Event é um evento de rastreamento já normalizado para os status do ERP
+type Event struct {
+	ExternalID  string
+	Code        string
+	Status      string
+	Description string
+	Location    string
+	OccurredAt  time.Time
+	Raw         string
+}
+
+// Update agrupa os eventos recebidos para um código de rastreamento
+type Update struct {
+	TrackingNumber string
+	Events         []Event
+}
+
+// Carrier é uma transportadora integrada; as capacidades (rastreamento, webhook, cotação de
+// frete) são as interfaces abaixo
+type Carrier interface {
+	Name() string
+}
+
+// Tracker é implementado pelas transportadoras consultadas ativamente (polling)
+type Tracker interface {
+	Carrier
+	Track(ctx context.Context, trackingNumber string) ([]Event, error)
+}
+
+// WebhookReceiver é implementado pelas transportadoras que enviam eventos por webhook
+type WebhookReceiver interface {
+	Carrier
+	ParseWebhook(body []byte) ([]Update, error)
+}
+
+// defaultTimeout limita o tempo das chamadas às APIs das transportadoras
+const defaultTimeout = 15 * time.Second
+
+// Get retorna a integração da transportadora configurada nas variáveis de ambiente
+func Get(name string) (Carrier, error) {
+	client := &http.Client{Timeout: defaultTimeout}
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case CarrierCorreios:
+		return NewCorreios(viper.GetString("CORREIOS_API_URL"), viper.GetString("CORREIOS_API_TOKEN"), client).
+			WithRates(viper.GetString("CORREIOS_RATE_API_URL"), viper.GetString("CORREIOS_RATE_SERVICES")), nil
+	case CarrierJadlog:
+		return NewJadlog(viper.GetString("JADLOG_API_URL"), viper.GetString("JADLOG_API_TOKEN"), client).
+			WithRates(viper.GetString("JADLOG_CNPJ"), viper.GetString("JADLOG_ACCOUNT"), viper.GetString("JADLOG_RATE_SERVICES")), nil
+	case CarrierWebhook:
+		return NewWebhook(), nil
+	default:
+		return nil, errors.ErrUnknownCarrier
+	}
+}
+
+// Resolve identifica a transportadora da entrega; quando o campo carrier não está preenchido,
+// tenta deduzi-la pelo método de envio (ex.: "SEDEX", "PAC", "Jadlog .Package")
+func Resolve(carrier, shippingMethod string) string {
+	if c := strings.ToLower(strings.TrimSpace(carrier)); c != "" {
+		return c
+	}
+
+	method := strings.ToLower(shippingMethod)
+	if strings.Contains(method, "jadlog") {
+		return CarrierJadlog
+	}
+	if strings.Contains(method, "correios") || strings.Contains(method, "sedex") {
+		return CarrierCorreios
+	}
+	for _, word := range strings.Fields(method) {
+		if word == "pac" {
+			return CarrierCorreios
+		}
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package carriers
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	assert.Equal(t, CarrierJadlog, Resolve("Jadlog", "SEDEX"))
+	assert.Equal(t, CarrierCorreios, Resolve("", "SEDEX 10"))
+	assert.Equal(t, CarrierCorreios, Resolve("", "PAC"))
+	assert.Equal(t, CarrierJadlog, Resolve("", "Jadlog .Package"))
+	assert.Equal(t, "", Resolve("", "Retirada no local"))
+}
+
+func TestGetUnknownCarrier(t *testing.T) {
+	_, err := Get("transportadora-x")
+	assert.Equal(t, errors.ErrUnknownCarrier, err)
+}
+
+func TestCorreiosTrack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/objetos/AA123456789BR", r.URL.Path)
+		assert.Equal(t, "Bearer token-correios", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"objetos":[{"codObjeto":"AA123456789BR","eventos":[
+			{"codigo":"BDE","tipo":"01","dtHrCriado":"2025-05-12T14:30:00","descricao":"Objeto entregue ao destinatário","unidade":{"endereco":{"cidade":"SAO PAULO","uf":"SP"}}},
+			{"codigo":"OEC","tipo":"01","dtHrCriado":"2025-05-12T08:10:00","descricao":"Objeto saiu para entrega ao destinatário","unidade":{"endereco":{"cidade":"SAO PAULO","uf":"SP"}}},
+			{"codigo":"PO","tipo":"01","dtHrCriado":"2025-05-10T16:00:00","descricao":"Objeto postado","unidade":{"endereco":{"cidade":"CAMPINAS","uf":"SP"}}}
+		]}]}`))
+	}))
+	defer server.Close()
+
+	events, err := NewCorreios(server.URL, "token-correios", server.Client()).Track(context.Background(), "AA123456789BR")
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, sales.TrackingStatusDelivered, events[0].Status)
+	assert.Equal(t, "SAO PAULO - SP", events[0].Location)
+	assert.Equal(t, "BDE-01-2025-05-12T14:30:00", events[0].ExternalID)
+	assert.Equal(t, sales.TrackingStatusOutForDelivery, events[1].Status)
+	assert.Equal(t, sales.TrackingStatusPosted, events[2].Status)
+}
+
+func TestCorreiosTrackHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := NewCorreios(server.URL, "", server.Client()).Track(context.Background(), "AA123456789BR")
+	assert.Error(t, err)
+}
+
+func TestCorreiosStatus(t *testing.T) {
+	assert.Equal(t, sales.TrackingStatusDelivered, correiosStatus("BDI", "01"))
+	assert.Equal(t, sales.TrackingStatusReturned, correiosStatus("BDE", "23"))
+	assert.Equal(t, sales.TrackingStatusException, correiosStatus("BDE", "20"))
+	assert.Equal(t, sales.TrackingStatusInTransit, correiosStatus("RO", "01"))
+}
+
+func TestJadlogTrack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tracking/consultar", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var req jadlogRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Consulta, 1)
+		assert.Equal(t, "10084000123456", req.Consulta[0].Codigo)
+
+		w.Write([]byte(`{"consulta":[{"codigo":"10084000123456","tracking":{"status":"ENTREGUE","eventos":[
+			{"data":"2025-05-09 10:00:00","status":"EMISSAO","unidade":"CO CAMPINAS"},
+			{"data":"2025-05-10 07:45:00","status":"EM ROTA","unidade":"FL SAO PAULO"},
+			{"data":"2025-05-10 15:20:00","status":"ENTREGUE","unidade":"FL SAO PAULO"}
+		]}}]}`))
+	}))
+	defer server.Close()
+
+	events, err := NewJadlog(server.URL, "token", server.Client()).Track(context.Background(), "10084000123456")
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, sales.TrackingStatusPosted, events[0].Status)
+	assert.Equal(t, sales.TrackingStatusOutForDelivery, events[1].Status)
+	assert.Equal(t, sales.TrackingStatusDelivered, events[2].Status)
+	assert.Equal(t, "FL SAO PAULO", events[2].Location)
+}
+
+func TestJadlogTrackError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"consulta":[{"codigo":"X","error":{"id":-1,"descricao":"Remessa não encontrada"}}]}`))
+	}))
+	defer server.Close()
+
+	_, err := NewJadlog(server.URL, "", server.Client()).Track(context.Background(), "X")
+	assert.Error(t, err)
+}
+
+func TestWebhookParse(t *testing.T) {
+	body := []byte(`{"tracking_number":" BR123 ","events":[
+		{"id":"ev-1","status":"in_transit","description":"Em trânsito","occurred_at":"2025-05-10T10:00:00Z"},
+		{"status":"delivered","description":"Entregue","occurred_at":"2025-05-11T12:00:00Z"}
+	]}`)
+
+	updates, err := NewWebhook().ParseWebhook(body)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "BR123", updates[0].TrackingNumber)
+	require.Len(t, updates[0].Events, 2)
+	assert.Equal(t, "ev-1", updates[0].Events[0].ExternalID)
+	assert.Equal(t, "delivered-2025-05-11T12:00:00Z", updates[0].Events[1].ExternalID)
+}
+
+func TestWebhookParseList(t *testing.T) {
+	body := []byte(`[
+		{"tracking_number":"A1","events":[{"id":"1","status":"posted","occurred_at":"2025-05-10T10:00:00Z"}]},
+		{"tracking_number":"A2","events":[{"id":"2","status":"returned","occurred_at":"2025-05-10T11:00:00Z"}]}
+	]`)
+
+	updates, err := NewWebhook().ParseWebhook(body)
+	require.NoError(t, err)
+	assert.Len(t, updates, 2)
+}
+
+func TestWebhookParseInvalid(t *testing.T) {
+	cases := []string{
+		`not json`,
+		`{"tracking_number":"","events":[{"status":"delivered","occurred_at":"2025-05-10T10:00:00Z"}]}`,
+		`{"tracking_number":"A1","events":[]}`,
+		`{"tracking_number":"A1","events":[{"status":"lost","occurred_at":"2025-05-10T10:00:00Z"}]}`,
+		`{"tracking_number":"A1","events":[{"status":"delivered"}]}`,
+	}
+
+	for _, body := range cases {
+		_, err := NewWebhook().ParseWebhook([]byte(body))
+		assert.Equal(t, errors.ErrInvalidWebhookPayload, err, body)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"tracking_number":"A1"}`)
+	mac := hmac.New(sha256.New, []byte("segredo"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, VerifySignature("segredo", body, signature))
+	assert.True(t, VerifySignature("segredo", body, "sha256="+signature))
+	assert.False(t, VerifySignature("outro", body, signature))
+	assert.False(t, VerifySignature("", body, signature))
+	assert.False(t, VerifySignature("segredo", body, "zz"))
+}
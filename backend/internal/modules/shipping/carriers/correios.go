@@ -0,0 +1,259 @@
+package carriers
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// correiosTimeLayout é o formato de data/hora retornado pela API SRO Rastro
+const correiosTimeLayout = "2006-01-02T15:04:05"
+
+// Correios consulta a API SRO Rastro dos Correios e as APIs de preço e prazo
+type Correios struct {
+	baseURL  string
+	token    string
+	client   *http.Client
+	rateURL  string
+	services []service
+}
+
+// NewCorreios cria a integração com os Correios
+func NewCorreios(baseURL, token string, client *http.Client) *Correios {
+	return &Correios{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+// WithRates configura a cotação de frete: a raiz das APIs de preço e prazo e os serviços cotados
+// (ex.: "03220:SEDEX,03298:PAC")
+func (c *Correios) WithRates(rateURL, services string) *Correios {
+	c.rateURL = strings.TrimRight(rateURL, "/")
+	c.services = parseServices(services)
+	return c
+}
+
+// Name retorna o identificador da transportadora
+func (c *Correios) Name() string {
+	return CarrierCorreios
+}
+
+type correiosResponse struct {
+	Objetos []struct {
+		CodObjeto string `json:"codObjeto"`
+		Mensagem  string `json:"mensagem"`
+		Eventos   []struct {
+			Codigo     string `json:"codigo"`
+			Tipo       string `json:"tipo"`
+			DtHrCriado string `json:"dtHrCriado"`
+			Descricao  string `json:"descricao"`
+			Unidade    struct {
+				Endereco struct {
+					Cidade string `json:"cidade"`
+					UF     string `json:"uf"`
+				} `json:"endereco"`
+			} `json:"unidade"`
+		} `json:"eventos"`
+	} `json:"objetos"`
+}
+
+// Track consulta todos os eventos do objeto postado
+func (c *Correios) Track(ctx context.Context, trackingNumber string) ([]Event, error) {
+	endpoint := fmt.Sprintf("%s/objetos/%s?resultado=T", c.baseURL, url.PathEscape(trackingNumber))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao montar consulta aos Correios")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao consultar rastreamento nos Correios")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao ler resposta dos Correios")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("correios retornou status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var parsed correiosResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, errors.WrapError(err, "resposta inválida dos Correios")
+	}
+
+	events := make([]Event, 0)
+	for _, obj := range parsed.Objetos {
+		for _, ev := range obj.Eventos {
+			occurredAt, err := time.ParseInLocation(correiosTimeLayout, ev.DtHrCriado, carrierTime)
+			if err != nil {
+				return nil, errors.WrapError(err, "data de evento inválida na resposta dos Correios")
+			}
+
+			location := ev.Unidade.Endereco.Cidade
+			if ev.Unidade.Endereco.UF != "" {
+				location = strings.TrimSpace(location + " - " + ev.Unidade.Endereco.UF)
+			}
+
+			raw, _ := json.Marshal(ev)
+			events = append(events, Event{
+				ExternalID:  fmt.Sprintf("%s-%s-%s", ev.Codigo, ev.Tipo, ev.DtHrCriado),
+				Code:        ev.Codigo + "/" + ev.Tipo,
+				Status:      correiosStatus(ev.Codigo, ev.Tipo),
+				Description: ev.Descricao,
+				Location:    location,
+				OccurredAt:  occurredAt.UTC(),
+				Raw:         string(raw),
+			})
+		}
+	}
+
+	return events, nil
+}
+
+// correiosStatus converte o par código/tipo do SRO para o status normalizado
+func correiosStatus(code, eventType string) string {
+	switch strings.ToUpper(code) {
+	case "PO", "PAR":
+		return sales.TrackingStatusPosted
+	case "OEC":
+		return sales.TrackingStatusOutForDelivery
+	case "BDE", "BDI", "BDR":
+		switch eventType {
+		case "01":
+			return sales.TrackingStatusDelivered
+		case "23":
+			return sales.TrackingStatusReturned
+		default:
+			return sales.TrackingStatusException
+		}
+	case "FC", "LDI":
+		return sales.TrackingStatusException
+	default:
+		return sales.TrackingStatusInTransit
+	}
+}
+
+type correiosPriceResponse struct {
+	CoProduto string `json:"coProduto"`
+	PcFinal   string `json:"pcFinal"`
+	TxErro    string `json:"txErro"`
+}
+
+type correiosDeadlineResponse struct {
+	CoProduto    string `json:"coProduto"`
+	PrazoEntrega int    `json:"prazoEntrega"`
+	TxErro       string `json:"txErro"`
+}
+
+// Quote cota cada serviço configurado nas APIs de preço e prazo. Os Correios cotam um objeto por
+// vez: o preço é a soma dos volumes e o prazo, o maior entre eles.
+func (c *Correios) Quote(ctx context.Context, req RateRequest) ([]Rate, error) {
+	if c.rateURL == "" || len(c.services) == 0 {
+		return nil, errors.ErrRatesNotSupported
+	}
+
+	rates := make([]Rate, 0, len(c.services))
+	for _, svc := range c.services {
+		rate := Rate{Carrier: CarrierCorreios, ServiceCode: svc.Code, ServiceName: svc.Name}
+		for _, parcel := range req.Parcels {
+			params := url.Values{}
+			params.Set("cepOrigem", req.OriginCEP)
+			params.Set("cepDestino", req.DestinationCEP)
+			params.Set("psObjeto", strconv.Itoa(int(math.Ceil(parcel.WeightKg*1000))))
+			params.Set("tpObjeto", "2")
+			params.Set("comprimento", formatCm(parcel.LengthCm))
+			params.Set("largura", formatCm(parcel.WidthCm))
+			params.Set("altura", formatCm(parcel.HeightCm))
+			if req.DeclaredValue > 0 {
+				params.Set("vlDeclarado", strconv.FormatFloat(req.DeclaredValue/float64(len(req.Parcels)), 'f', 2, 64))
+			}
+
+			var price correiosPriceResponse
+			if err := c.getJSON(ctx, fmt.Sprintf("%s/preco/v1/nacional/%s?%s", c.rateURL, url.PathEscape(svc.Code), params.Encode()), &price); err != nil {
+				return nil, err
+			}
+			if price.TxErro != "" {
+				return nil, fmt.Errorf("correios: %s", price.TxErro)
+			}
+			value, err := parseCorreiosDecimal(price.PcFinal)
+			if err != nil {
+				return nil, errors.WrapError(err, "preço inválido na resposta dos Correios")
+			}
+			rate.Price += value
+		}
+
+		params := url.Values{}
+		params.Set("cepOrigem", req.OriginCEP)
+		params.Set("cepDestino", req.DestinationCEP)
+		var deadline correiosDeadlineResponse
+		if err := c.getJSON(ctx, fmt.Sprintf("%s/prazo/v1/nacional/%s?%s", c.rateURL, url.PathEscape(svc.Code), params.Encode()), &deadline); err != nil {
+			return nil, err
+		}
+		if deadline.TxErro != "" {
+			return nil, fmt.Errorf("correios: %s", deadline.TxErro)
+		}
+		rate.DeliveryDays = deadline.PrazoEntrega
+		rate.Price = math.Round(rate.Price*100) / 100
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// getJSON faz um GET autenticado nas APIs dos Correios e decodifica a resposta
+func (c *Correios) getJSON(ctx context.Context, endpoint string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errors.WrapError(err, "falha ao montar consulta aos Correios")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.WrapError(err, "falha ao cotar frete nos Correios")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.WrapError(err, "falha ao ler resposta dos Correios")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("correios retornou status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, dest); err != nil {
+		return errors.WrapError(err, "resposta inválida dos Correios")
+	}
+	return nil
+}
+
+// parseCorreiosDecimal converte os valores no formato brasileiro ("1.234,56") devolvidos pela API
+func parseCorreiosDecimal(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ".", "")
+	return strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+}
+
+// formatCm arredonda a dimensão para centímetros inteiros, como a API de preço espera
+func formatCm(value float64) string {
+	return strconv.Itoa(int(math.Ceil(value)))
+}
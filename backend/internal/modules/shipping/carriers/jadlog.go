@@ -0,0 +1,278 @@
+package carriers
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jadlogTimeLayout é o formato de data/hora retornado pela API de tracking da Jadlog
+const jadlogTimeLayout = "2006-01-02 15:04:05"
+
+// Jadlog consulta as APIs de tracking e de frete do embarcador Jadlog
+type Jadlog struct {
+	baseURL  string
+	token    string
+	client   *http.Client
+	cnpj     string
+	account  string
+	services []service
+}
+
+// NewJadlog cria a integração com a Jadlog
+func NewJadlog(baseURL, token string, client *http.Client) *Jadlog {
+	return &Jadlog{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+// WithRates configura a cotação de frete: CNPJ e conta corrente do embarcador e as modalidades
+// cotadas (ex.: "3:.Package,0:Expresso")
+func (j *Jadlog) WithRates(cnpj, account, services string) *Jadlog {
+	j.cnpj = cnpj
+	j.account = account
+	j.services = parseServices(services)
+	return j
+}
+
+// Name retorna o identificador da transportadora
+func (j *Jadlog) Name() string {
+	return CarrierJadlog
+}
+
+type jadlogRequest struct {
+	Consulta []jadlogQuery `json:"consulta"`
+}
+
+type jadlogQuery struct {
+	Codigo string `json:"codigo"`
+}
+
+type jadlogResponse struct {
+	Consulta []struct {
+		Codigo   string `json:"codigo"`
+		Tracking struct {
+			Status  string `json:"status"`
+			Eventos []struct {
+				Data    string `json:"data"`
+				Status  string `json:"status"`
+				Unidade string `json:"unidade"`
+			} `json:"eventos"`
+		} `json:"tracking"`
+		Error *struct {
+			ID        int    `json:"id"`
+			Descricao string `json:"descricao"`
+		} `json:"error"`
+	} `json:"consulta"`
+}
+
+// Track consulta os eventos da remessa pelo código de rastreamento
+func (j *Jadlog) Track(ctx context.Context, trackingNumber string) ([]Event, error) {
+	payload, err := json.Marshal(jadlogRequest{Consulta: []jadlogQuery{{Codigo: trackingNumber}}})
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao montar consulta à Jadlog")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.baseURL+"/tracking/consultar", bytes.NewReader(payload))
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao montar consulta à Jadlog")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if j.token != "" {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao consultar rastreamento na Jadlog")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao ler resposta da Jadlog")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jadlog retornou status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var parsed jadlogResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, errors.WrapError(err, "resposta inválida da Jadlog")
+	}
+
+	events := make([]Event, 0)
+	for _, result := range parsed.Consulta {
+		if result.Error != nil {
+			return nil, fmt.Errorf("jadlog: %s", result.Error.Descricao)
+		}
+
+		for _, ev := range result.Tracking.Eventos {
+			occurredAt, err := time.ParseInLocation(jadlogTimeLayout, ev.Data, carrierTime)
+			if err != nil {
+				return nil, errors.WrapError(err, "data de evento inválida na resposta da Jadlog")
+			}
+
+			raw, _ := json.Marshal(ev)
+			events = append(events, Event{
+				ExternalID:  fmt.Sprintf("%s-%s", ev.Status, ev.Data),
+				Code:        ev.Status,
+				Status:      jadlogStatus(ev.Status),
+				Description: ev.Status,
+				Location:    ev.Unidade,
+				OccurredAt:  occurredAt.UTC(),
+				Raw:         string(raw),
+			})
+		}
+	}
+
+	return events, nil
+}
+
+// jadlogStatus converte o status textual da Jadlog para o status normalizado
+func jadlogStatus(status string) string {
+	s := strings.ToUpper(status)
+	switch {
+	case strings.Contains(s, "DEVOLV"):
+		return sales.TrackingStatusReturned
+	case strings.Contains(s, "ENTREGUE"):
+		return sales.TrackingStatusDelivered
+	case strings.Contains(s, "EM ROTA"), strings.Contains(s, "SAIU PARA ENTREGA"):
+		return sales.TrackingStatusOutForDelivery
+	case strings.Contains(s, "INSUCESSO"), strings.Contains(s, "AVARIA"), strings.Contains(s, "EXTRAVIO"):
+		return sales.TrackingStatusException
+	case strings.Contains(s, "EMISSAO"), strings.Contains(s, "COLETA"):
+		return sales.TrackingStatusPosted
+	default:
+		return sales.TrackingStatusInTransit
+	}
+}
+
+type jadlogRateRequest struct {
+	Frete []jadlogFreight `json:"frete"`
+}
+
+type jadlogFreight struct {
+	CepOri      string  `json:"cepori"`
+	CepDes      string  `json:"cepdes"`
+	Frap        *string `json:"frap"`
+	Peso        float64 `json:"peso"`
+	CNPJ        string  `json:"cnpj"`
+	Conta       string  `json:"conta"`
+	Contrato    *string `json:"contrato"`
+	Modalidade  int     `json:"modalidade"`
+	TpEntrega   string  `json:"tpentrega"`
+	TpSeguro    string  `json:"tpseguro"`
+	VlDeclarado float64 `json:"vldeclarado"`
+	VlColeta    *string `json:"vlcoleta"`
+}
+
+type jadlogRateResponse struct {
+	Frete []struct {
+		Peso    float64 `json:"peso"`
+		Prazo   int     `json:"prazo"`
+		VlTotal float64 `json:"vltotal"`
+	} `json:"frete"`
+	Error *struct {
+		ID        int    `json:"id"`
+		Descricao string `json:"descricao"`
+	} `json:"error"`
+}
+
+// Quote cota cada modalidade configurada na API de frete, com um item por volume pelo peso
+// taxado (real ou cubado)
+func (j *Jadlog) Quote(ctx context.Context, req RateRequest) ([]Rate, error) {
+	if j.cnpj == "" || len(j.services) == 0 {
+		return nil, errors.ErrRatesNotSupported
+	}
+
+	rates := make([]Rate, 0, len(j.services))
+	for _, svc := range j.services {
+		modality, err := strconv.Atoi(svc.Code)
+		if err != nil {
+			return nil, fmt.Errorf("modalidade Jadlog inválida: %s", svc.Code)
+		}
+
+		payload := jadlogRateRequest{Frete: make([]jadlogFreight, 0, len(req.Parcels))}
+		for _, parcel := range req.Parcels {
+			payload.Frete = append(payload.Frete, jadlogFreight{
+				CepOri:      req.OriginCEP,
+				CepDes:      req.DestinationCEP,
+				Peso:        math.Round(parcel.BillableWeightKg()*1000) / 1000,
+				CNPJ:        j.cnpj,
+				Conta:       j.account,
+				Modalidade:  modality,
+				TpEntrega:   "D",
+				TpSeguro:    "N",
+				VlDeclarado: math.Round(req.DeclaredValue/float64(len(req.Parcels))*100) / 100,
+			})
+		}
+
+		parsed, err := j.quote(ctx, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		rate := Rate{Carrier: CarrierJadlog, ServiceCode: svc.Code, ServiceName: svc.Name}
+		for _, freight := range parsed.Frete {
+			rate.Price += freight.VlTotal
+			if freight.Prazo > rate.DeliveryDays {
+				rate.DeliveryDays = freight.Prazo
+			}
+		}
+		rate.Price = math.Round(rate.Price*100) / 100
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// quote envia a simulação de frete à Jadlog
+func (j *Jadlog) quote(ctx context.Context, payload jadlogRateRequest) (*jadlogRateResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao montar cotação na Jadlog")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.baseURL+"/frete/valor", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao montar cotação na Jadlog")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if j.token != "" {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao cotar frete na Jadlog")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao ler resposta da Jadlog")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jadlog retornou status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var parsed jadlogRateResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, errors.WrapError(err, "resposta inválida da Jadlog")
+	}
+	if parsed.Error != nil {
+		return nil, fmt.Errorf("jadlog: %s", parsed.Error.Descricao)
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,111 @@
+package carriers
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"math"
+	"strings"
+)
+
+// cubingFactor converte o volume em cm³ no peso cubado em kg, pelo fator 6.000 usado pelos
+// Correios e pelas transportadoras rodoviárias
+const cubingFactor = 6000
+
+// Parcel é um volume a cotar, com peso e dimensões
+type Parcel struct {
+	WeightKg float64 `json:"weight_kg"`
+	LengthCm float64 `json:"length_cm"`
+	WidthCm  float64 `json:"width_cm"`
+	HeightCm float64 `json:"height_cm"`
+}
+
+// BillableWeightKg retorna o maior entre o peso real e o peso cubado do volume
+func (p Parcel) BillableWeightKg() float64 {
+	cubed := p.LengthCm * p.WidthCm * p.HeightCm / cubingFactor
+	return math.Max(p.WeightKg, cubed)
+}
+
+// RateRequest são os dados de uma cotação: CEPs de origem e destino (só dígitos), volumes e
+// valor declarado para o seguro
+type RateRequest struct {
+	OriginCEP      string
+	DestinationCEP string
+	Parcels        []Parcel
+	DeclaredValue  float64
+}
+
+// Rate é uma opção de frete devolvida pela transportadora. O preço é o total dos volumes e o
+// prazo, em dias úteis, o do volume mais demorado.
+type Rate struct {
+	Carrier      string  `json:"carrier"`
+	ServiceCode  string  `json:"service_code"`
+	ServiceName  string  `json:"service_name"`
+	Price        float64 `json:"price"`
+	DeliveryDays int     `json:"delivery_days"`
+	// EstimatedDeliveryDate é a data prevista de entrega (AAAA-MM-DD), contando o prazo em dias
+	// úteis pelo calendário da empresa
+	EstimatedDeliveryDate string `json:"estimated_delivery_date,omitempty"`
+}
+
+// RateQuoter é implementado pelas transportadoras que cotam frete
+type RateQuoter interface {
+	Carrier
+	Quote(ctx context.Context, req RateRequest) ([]Rate, error)
+}
+
+// RateCarriers são as transportadoras consultadas quando a cotação não escolhe nenhuma
+var RateCarriers = []string{CarrierCorreios, CarrierJadlog}
+
+// GetQuoter retorna a integração de cotação da transportadora
+func GetQuoter(name string) (RateQuoter, error) {
+	carrier, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+	quoter, ok := carrier.(RateQuoter)
+	if !ok {
+		return nil, errors.ErrRatesNotSupported
+	}
+	return quoter, nil
+}
+
+// NormalizeCEP mantém só os dígitos do CEP; ok é falso quando não restam 8 dígitos
+func NormalizeCEP(cep string) (string, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		if r == '-' || r == '.' || r == ' ' {
+			return -1
+		}
+		return 'x'
+	}, cep)
+	if len(digits) != 8 || strings.ContainsRune(digits, 'x') {
+		return "", false
+	}
+	return digits, true
+}
+
+// service é um serviço (modalidade) cotado na transportadora
+type service struct {
+	Code string
+	Name string
+}
+
+// parseServices interpreta a lista de serviços configurada no formato código:nome separado por
+// vírgulas (ex.: "03220:SEDEX,03298:PAC"); sem nome, o código é usado como nome
+func parseServices(value string) []service {
+	var services []service
+	for _, item := range strings.Split(value, ",") {
+		code, name, _ := strings.Cut(strings.TrimSpace(item), ":")
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		if name = strings.TrimSpace(name); name == "" {
+			name = code
+		}
+		services = append(services, service{Code: code, Name: name})
+	}
+	return services
+}
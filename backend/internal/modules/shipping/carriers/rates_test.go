@@ -0,0 +1,126 @@
+package carriers
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCEP(t *testing.T) {
+	cep, ok := NormalizeCEP(" 01310-100 ")
+	assert.True(t, ok)
+	assert.Equal(t, "01310100", cep)
+
+	_, ok = NormalizeCEP("1310-100")
+	assert.False(t, ok)
+	_, ok = NormalizeCEP("01310-10A")
+	assert.False(t, ok)
+}
+
+func TestParcelBillableWeight(t *testing.T) {
+	assert.Equal(t, 2.0, Parcel{WeightKg: 2, LengthCm: 20, WidthCm: 15, HeightCm: 10}.BillableWeightKg())
+	assert.Equal(t, 8.0, Parcel{WeightKg: 2, LengthCm: 60, WidthCm: 40, HeightCm: 20}.BillableWeightKg(), "peso cubado")
+}
+
+func TestParseServices(t *testing.T) {
+	assert.Equal(t, []service{{Code: "03220", Name: "SEDEX"}, {Code: "03298", Name: "PAC"}}, parseServices("03220:SEDEX, 03298:PAC"))
+	assert.Equal(t, []service{{Code: "3", Name: "3"}}, parseServices("3,,"))
+	assert.Empty(t, parseServices(""))
+}
+
+func TestCorreiosQuote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-correios", r.Header.Get("Authorization"))
+		query := r.URL.Query()
+		assert.Equal(t, "13010000", query.Get("cepOrigem"))
+		assert.Equal(t, "01310100", query.Get("cepDestino"))
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/preco/v1/nacional/03220"):
+			assert.Equal(t, "1500", query.Get("psObjeto"))
+			w.Write([]byte(`{"coProduto":"03220","pcFinal":"32,40"}`))
+		case strings.HasPrefix(r.URL.Path, "/preco/v1/nacional/03298"):
+			w.Write([]byte(`{"coProduto":"03298","pcFinal":"1.020,10"}`))
+		case r.URL.Path == "/prazo/v1/nacional/03220":
+			w.Write([]byte(`{"coProduto":"03220","prazoEntrega":1}`))
+		case r.URL.Path == "/prazo/v1/nacional/03298":
+			w.Write([]byte(`{"coProduto":"03298","prazoEntrega":5}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	correios := NewCorreios("", "token-correios", server.Client()).WithRates(server.URL, "03220:SEDEX,03298:PAC")
+	rates, err := correios.Quote(context.Background(), RateRequest{
+		OriginCEP:      "13010000",
+		DestinationCEP: "01310100",
+		Parcels:        []Parcel{{WeightKg: 1.5, LengthCm: 20, WidthCm: 15, HeightCm: 10}, {WeightKg: 1.5}},
+	})
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+
+	assert.Equal(t, Rate{Carrier: CarrierCorreios, ServiceCode: "03220", ServiceName: "SEDEX", Price: 64.8, DeliveryDays: 1}, rates[0])
+	assert.Equal(t, 2040.2, rates[1].Price, "soma dos dois volumes")
+	assert.Equal(t, 5, rates[1].DeliveryDays)
+}
+
+func TestCorreiosQuoteNotConfigured(t *testing.T) {
+	_, err := NewCorreios("", "", http.DefaultClient).Quote(context.Background(), RateRequest{})
+	assert.Equal(t, errors.ErrRatesNotSupported, err)
+}
+
+func TestJadlogQuote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/frete/valor", r.URL.Path)
+
+		var req jadlogRateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Frete, 2)
+		assert.Equal(t, "12345678000190", req.Frete[0].CNPJ)
+		assert.Equal(t, 3, req.Frete[0].Modalidade)
+		assert.Equal(t, 8.0, req.Frete[0].Peso, "peso cubado do primeiro volume")
+		assert.Equal(t, 250.0, req.Frete[1].VlDeclarado, "valor declarado dividido entre os volumes")
+
+		w.Write([]byte(`{"frete":[{"peso":8,"prazo":4,"vltotal":55.3},{"peso":1,"prazo":3,"vltotal":21.25}]}`))
+	}))
+	defer server.Close()
+
+	jadlog := NewJadlog(server.URL, "", server.Client()).WithRates("12345678000190", "123", "3:.Package")
+	rates, err := jadlog.Quote(context.Background(), RateRequest{
+		OriginCEP:      "13010000",
+		DestinationCEP: "01310100",
+		Parcels:        []Parcel{{WeightKg: 2, LengthCm: 60, WidthCm: 40, HeightCm: 20}, {WeightKg: 1}},
+		DeclaredValue:  500,
+	})
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, Rate{Carrier: CarrierJadlog, ServiceCode: "3", ServiceName: ".Package", Price: 76.55, DeliveryDays: 4}, rates[0])
+}
+
+func TestJadlogQuoteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":{"id":-1,"descricao":"CEP de destino não atendido"}}`))
+	}))
+	defer server.Close()
+
+	jadlog := NewJadlog(server.URL, "", server.Client()).WithRates("12345678000190", "", "3")
+	_, err := jadlog.Quote(context.Background(), RateRequest{Parcels: []Parcel{{WeightKg: 1}}})
+	assert.ErrorContains(t, err, "CEP de destino não atendido")
+}
+
+func TestGetQuoter(t *testing.T) {
+	_, err := GetQuoter(CarrierWebhook)
+	assert.Equal(t, errors.ErrRatesNotSupported, err)
+
+	quoter, err := GetQuoter(CarrierCorreios)
+	require.NoError(t, err)
+	assert.Equal(t, CarrierCorreios, quoter.Name())
+}
@@ -0,0 +1,123 @@
+package carriers
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Webhook recebe eventos enviados por transportadoras ou plataformas de frete
+// em um formato JSON genérico
+type Webhook struct{}
+
+// NewWebhook cria o receptor genérico de webhooks
+func NewWebhook() *Webhook {
+	return &Webhook{}
+}
+
+// Name retorna o identificador da transportadora
+func (w *Webhook) Name() string {
+	return CarrierWebhook
+}
+
+type webhookPayload struct {
+	TrackingNumber string         `json:"tracking_number"`
+	Events         []webhookEvent `json:"events"`
+}
+
+type webhookEvent struct {
+	ID          string    `json:"id"`
+	Code        string    `json:"code"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// ParseWebhook aceita um único objeto ou uma lista de objetos no formato
+// {"tracking_number": "...", "events": [{"id", "code", "status", "description", "location", "occurred_at"}]}
+func (w *Webhook) ParseWebhook(body []byte) ([]Update, error) {
+	var payloads []webhookPayload
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(body, &payloads); err != nil {
+			return nil, errors.ErrInvalidWebhookPayload
+		}
+	} else {
+		var single webhookPayload
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil, errors.ErrInvalidWebhookPayload
+		}
+		payloads = append(payloads, single)
+	}
+
+	updates := make([]Update, 0, len(payloads))
+	for _, p := range payloads {
+		if strings.TrimSpace(p.TrackingNumber) == "" || len(p.Events) == 0 {
+			return nil, errors.ErrInvalidWebhookPayload
+		}
+
+		update := Update{TrackingNumber: strings.TrimSpace(p.TrackingNumber)}
+		for _, ev := range p.Events {
+			if !isValidTrackingStatus(ev.Status) || ev.OccurredAt.IsZero() {
+				return nil, errors.ErrInvalidWebhookPayload
+			}
+
+			externalID := ev.ID
+			if externalID == "" {
+				externalID = fmt.Sprintf("%s-%s", ev.Status, ev.OccurredAt.UTC().Format(time.RFC3339))
+			}
+
+			raw, _ := json.Marshal(ev)
+			update.Events = append(update.Events, Event{
+				ExternalID:  externalID,
+				Code:        ev.Code,
+				Status:      ev.Status,
+				Description: ev.Description,
+				Location:    ev.Location,
+				OccurredAt:  ev.OccurredAt,
+				Raw:         string(raw),
+			})
+		}
+		updates = append(updates, update)
+	}
+
+	return updates, nil
+}
+
+// VerifySignature confere a assinatura HMAC-SHA256 (hex, com ou sem prefixo "sha256=") do corpo do webhook
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// isValidTrackingStatus verifica se o status informado é um dos status normalizados
+func isValidTrackingStatus(status string) bool {
+	switch status {
+	case sales.TrackingStatusPosted,
+		sales.TrackingStatusInTransit,
+		sales.TrackingStatusOutForDelivery,
+		sales.TrackingStatusDelivered,
+		sales.TrackingStatusException,
+		sales.TrackingStatusReturned:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/shipping/models"
+	"ERP-ONSMART/backend/internal/modules/shipping/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cota o frete nas transportadoras a partir dos volumes e do CEP de destino
+// Retorna as opções (transportadora, serviço, preço e prazo em dias úteis) da mais barata para a mais cara e as transportadoras que falharam.
+// @Security BearerAuth
+func QuoteRatesHandler(c *gin.Context) {
+	var input models.RateQuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	quote, err := service.QuoteRates(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cotar frete")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quote": quote})
+}
+
+// Cota o frete da cotação de venda
+// Sem CEP de destino ou valor declarado, usa o CEP do cliente e o total da cotação.
+func QuoteQuotationRatesHandler(c *gin.Context) {
+	quoteDocumentRates(c, models.DocumentQuotation)
+}
+
+// Cota o frete do pedido de venda
+// Sem CEP de destino ou valor declarado, usa o CEP do cliente e o total do pedido.
+// @Security BearerAuth
+func QuoteSalesOrderRatesHandler(c *gin.Context) {
+	quoteDocumentRates(c, models.DocumentSalesOrder)
+}
+
+// Grava a opção de frete escolhida na cotação de venda
+// Permitido em cotações em rascunho ou enviadas; a opção passa para o pedido gerado na conversão.
+func SetQuotationShippingHandler(c *gin.Context) {
+	setShippingOption(c, models.DocumentQuotation)
+}
+
+// Grava a opção de frete escolhida no pedido de venda
+// O custo entra na lucratividade do processo de venda, e a transportadora e o serviço passam para as entregas geradas.
+// @Security BearerAuth
+func SetSalesOrderShippingHandler(c *gin.Context) {
+	setShippingOption(c, models.DocumentSalesOrder)
+}
+
+func quoteDocumentRates(c *gin.Context, document string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.RateQuoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	quote, err := service.QuoteDocumentRates(c.Request.Context(), document, id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao cotar frete do documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quote": quote})
+}
+
+func setShippingOption(c *gin.Context, document string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.ShippingOptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	option, err := service.SetShippingOption(c.Request.Context(), document, id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar frete do documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shipping": option})
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/shipping/service"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Retorna o histórico de eventos de rastreamento da entrega
+func GetDeliveryTrackingEventsHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	events, err := service.GetTrackingEvents(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar eventos de rastreamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// Consulta imediatamente a transportadora da entrega e grava os novos eventos
+func RefreshDeliveryTrackingHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := service.RefreshTracking(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao consultar rastreamento da entrega")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+// Consulta o rastreamento de todas as entregas enviadas
+func PollTrackingHandler(c *gin.Context) {
+	summary, err := service.PollShippedDeliveries(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao consultar rastreamento das entregas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"summary": summary})
+}
+
+// Recebe eventos de rastreamento enviados pela transportadora (assinatura em X-Signature)
+func CarrierWebhookHandler(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	results, err := service.HandleCarrierWebhook(c.Request.Context(), c.Param("carrier"), body, c.GetHeader("X-Signature"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao processar webhook de rastreamento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/modules/shipping/carriers"
+	"ERP-ONSMART/backend/internal/money"
+)
+
+// Documentos de venda que recebem a opção de frete
+const (
+	DocumentQuotation  = "quotation"
+	DocumentSalesOrder = "sales_order"
+)
+
+// RateQuoteInput são os dados da cotação de frete. Sem CEP de origem, vale o SHIPPING_ORIGIN_CEP;
+// sem transportadoras, são consultadas todas as configuradas. Na cotação de um documento, o CEP de
+// destino e o valor declarado, quando ausentes, vêm do cliente e do total do documento.
+type RateQuoteInput struct {
+	OriginCEP      string         `json:"origin_cep"`
+	DestinationCEP string         `json:"destination_cep"`
+	DeclaredValue  float64        `json:"declared_value" binding:"gte=0"`
+	Packages       []PackageInput `json:"packages" binding:"required,min=1,max=100,dive"`
+	Carriers       []string       `json:"carriers"`
+}
+
+// PackageInput é um volume a cotar, com peso e dimensões
+type PackageInput struct {
+	WeightKg float64 `json:"weight_kg" binding:"required,gt=0"`
+	LengthCm float64 `json:"length_cm" binding:"gte=0"`
+	WidthCm  float64 `json:"width_cm" binding:"gte=0"`
+	HeightCm float64 `json:"height_cm" binding:"gte=0"`
+}
+
+// Parcels converte os volumes informados para a cotação nas transportadoras
+func (in RateQuoteInput) Parcels() []carriers.Parcel {
+	parcels := make([]carriers.Parcel, len(in.Packages))
+	for i, pkg := range in.Packages {
+		parcels[i] = carriers.Parcel{WeightKg: pkg.WeightKg, LengthCm: pkg.LengthCm, WidthCm: pkg.WidthCm, HeightCm: pkg.HeightCm}
+	}
+	return parcels
+}
+
+// RateQuote é o resultado da cotação: as opções de todas as transportadoras, da mais barata para a
+// mais cara, e as transportadoras que falharam
+type RateQuote struct {
+	OriginCEP      string          `json:"origin_cep"`
+	DestinationCEP string          `json:"destination_cep"`
+	DeclaredValue  float64         `json:"declared_value"`
+	Rates          []carriers.Rate `json:"rates"`
+	Failures       []RateFailure   `json:"failures"`
+}
+
+// RateFailure registra uma transportadora cuja cotação falhou
+type RateFailure struct {
+	Carrier string `json:"carrier"`
+	Error   string `json:"error"`
+}
+
+// ShippingOptionInput é a opção de frete escolhida para o documento, normalmente uma das opções
+// devolvidas pela cotação
+type ShippingOptionInput struct {
+	Carrier      string        `json:"carrier" binding:"required,max=50"`
+	ServiceCode  string        `json:"service_code" binding:"max=20"`
+	ServiceName  string        `json:"service_name" binding:"required,max=100"`
+	Price        money.Decimal `json:"price" binding:"gte=0"`
+	DeliveryDays int           `json:"delivery_days" binding:"gte=0"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// TrackingSyncResult resume a gravação dos eventos de rastreamento de uma entrega
+type TrackingSyncResult struct {
+	DeliveryID      int    `json:"delivery_id"`
+	TrackingNumber  string `json:"tracking_number"`
+	Carrier         string `json:"carrier"`
+	NewEvents       int    `json:"new_events"`
+	Status          string `json:"status"`
+	MarkedDelivered bool   `json:"marked_as_delivered"`
+}
+
+// PollSummary resume uma rodada de consulta às transportadoras
+type PollSummary struct {
+	Checked   int                  `json:"checked"`
+	NewEvents int                  `json:"new_events"`
+	Delivered int                  `json:"delivered"`
+	Skipped   int                  `json:"skipped"`
+	Failures  []PollFailure        `json:"failures"`
+	Results   []TrackingSyncResult `json:"results"`
+}
+
+// PollFailure registra uma entrega cuja consulta falhou
+type PollFailure struct {
+	DeliveryID int    `json:"delivery_id"`
+	Error      string `json:"error"`
+}
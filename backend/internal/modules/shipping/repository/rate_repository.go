@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/shipping/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RateRepository define as consultas e a gravação do frete nos documentos de venda
+type RateRepository interface {
+	GetQuoteDefaults(ctx context.Context, document string, id int) (*QuoteDefaults, error)
+	SetShippingOption(ctx context.Context, document string, id int, option sales.ShippingOption) error
+}
+
+// QuoteDefaults são os dados do documento usados na cotação: o CEP do cliente e o total
+type QuoteDefaults struct {
+	ZipCode    string
+	GrandTotal float64
+}
+
+type rateRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewRateRepository cria uma nova instância do repositório
+func NewRateRepository() (RateRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &rateRepository{
+		db:     db,
+		logger: logger.WithModule("rate_repository"),
+	}, nil
+}
+
+// GetQuoteDefaults busca o CEP de entrega e o total da cotação ou do pedido de venda: o endereço
+// de entrega do pedido, o endereço de entrega padrão do cliente ou o CEP do cadastro do cliente
+func (r *rateRepository) GetQuoteDefaults(ctx context.Context, document string, id int) (*QuoteDefaults, error) {
+	table, notFound := documentTable(document)
+
+	zipCode := "COALESCE(da.zip_code, c.zip_code, '')"
+	query := r.db.WithContext(ctx).Table(table + " d").
+		Joins("LEFT JOIN contacts c ON c.id = d.contact_id").
+		Joins("LEFT JOIN contact_addresses da ON da.contact_id = d.contact_id AND da.type = 'shipping' AND da.is_default")
+	if document != models.DocumentQuotation {
+		zipCode = "COALESCE(sa.zip_code, da.zip_code, c.zip_code, '')"
+		query = query.Joins("LEFT JOIN contact_addresses sa ON sa.id = d.shipping_address_id")
+	}
+
+	var defaults []QuoteDefaults
+	if err := query.
+		Select(zipCode+" AS zip_code, d.grand_total").
+		Scopes(tenant.Scope(ctx, "d")).
+		Where("d.id = ? AND d.deleted_at IS NULL", id).
+		Limit(1).
+		Scan(&defaults).Error; err != nil {
+		r.logger.Error("erro ao buscar dados do documento para cotação", zap.Error(err), zap.String("document", document), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar dados do documento para cotação")
+	}
+	if len(defaults) == 0 {
+		return nil, notFound
+	}
+	return &defaults[0], nil
+}
+
+// SetShippingOption grava a opção de frete escolhida na cotação (rascunho ou enviada) ou no pedido
+// de venda (não concluído nem cancelado)
+func (r *rateRepository) SetShippingOption(ctx context.Context, document string, id int, option sales.ShippingOption) error {
+	db := r.db.WithContext(ctx)
+
+	var target interface{}
+	switch document {
+	case models.DocumentQuotation:
+		var quotation sales.Quotation
+		if err := db.First(&quotation, id).Error; err != nil {
+			return notFoundOr(err, errors.ErrQuotationNotFound)
+		}
+		if !quotation.CanChangeShipping() {
+			return errors.ErrShippingNotEditable
+		}
+		target = &quotation
+	default:
+		var salesOrder sales.SalesOrder
+		if err := db.First(&salesOrder, id).Error; err != nil {
+			return notFoundOr(err, errors.ErrSalesOrderNotFound)
+		}
+		if !salesOrder.CanChangeShipping() {
+			return errors.ErrShippingNotEditable
+		}
+		target = &salesOrder
+	}
+
+	if err := db.Model(target).Updates(option.Columns()).Error; err != nil {
+		r.logger.Error("erro ao gravar frete do documento", zap.Error(err), zap.String("document", document), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao gravar frete do documento")
+	}
+
+	r.logger.Info("frete do documento atualizado",
+		zap.String("document", document),
+		zap.Int("id", id),
+		zap.String("carrier", option.ShippingCarrier),
+		zap.Stringer("cost", option.ShippingCost))
+	return nil
+}
+
+// documentTable retorna a tabela do documento e o erro de documento não encontrado
+func documentTable(document string) (string, error) {
+	if document == models.DocumentQuotation {
+		return "quotations", errors.ErrQuotationNotFound
+	}
+	return "sales_orders", errors.ErrSalesOrderNotFound
+}
+
+func notFoundOr(err, notFound error) error {
+	if err == gorm.ErrRecordNotFound {
+		return notFound
+	}
+	return errors.WrapError(err, "falha ao buscar documento")
+}
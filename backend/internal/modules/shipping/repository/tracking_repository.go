@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/shipping/models"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TrackingRepository define as operações de rastreamento de entregas junto às transportadoras
+type TrackingRepository interface {
+	GetDelivery(ctx context.Context, deliveryID int) (*sales.Delivery, error)
+	GetDeliveriesByTrackingNumber(ctx context.Context, trackingNumber string) ([]sales.Delivery, error)
+	GetDeliveriesToPoll(ctx context.Context) ([]sales.Delivery, error)
+	GetEvents(ctx context.Context, deliveryID int) ([]sales.DeliveryTrackingEvent, error)
+	SaveEvents(ctx context.Context, deliveryID int, events []sales.DeliveryTrackingEvent) (*models.TrackingSyncResult, error)
+}
+
+type trackingRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTrackingRepository cria uma nova instância do repositório
+func NewTrackingRepository() (TrackingRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &trackingRepository{
+		db:     db,
+		logger: logger.WithModule("tracking_repository"),
+	}, nil
+}
+
+// GetDelivery busca a entrega a ser rastreada
+func (r *trackingRepository) GetDelivery(ctx context.Context, deliveryID int) (*sales.Delivery, error) {
+	var delivery sales.Delivery
+	if err := db.Conn(ctx, r.db).First(&delivery, deliveryID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar delivery")
+	}
+	return &delivery, nil
+}
+
+// GetDeliveriesByTrackingNumber busca as entregas com o código de rastreamento informado
+func (r *trackingRepository) GetDeliveriesByTrackingNumber(ctx context.Context, trackingNumber string) ([]sales.Delivery, error) {
+	var deliveries []sales.Delivery
+	if err := db.Conn(ctx, r.db).Where("tracking_number = ?", trackingNumber).Find(&deliveries).Error; err != nil {
+		r.logger.Error("erro ao buscar deliveries por código de rastreamento", zap.Error(err), zap.String("tracking_number", trackingNumber))
+		return nil, errors.WrapError(err, "falha ao buscar deliveries por código de rastreamento")
+	}
+	return deliveries, nil
+}
+
+// GetDeliveriesToPoll busca as entregas enviadas que possuem código de rastreamento
+func (r *trackingRepository) GetDeliveriesToPoll(ctx context.Context) ([]sales.Delivery, error) {
+	var deliveries []sales.Delivery
+	if err := db.Conn(ctx, r.db).Where("status = ? AND tracking_number <> ''", sales.DeliveryStatusShipped).
+		Order("id ASC").
+		Find(&deliveries).Error; err != nil {
+		r.logger.Error("erro ao buscar deliveries em trânsito", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar deliveries em trânsito")
+	}
+	return deliveries, nil
+}
+
+// GetEvents retorna o histórico de eventos de rastreamento da entrega em ordem cronológica
+func (r *trackingRepository) GetEvents(ctx context.Context, deliveryID int) ([]sales.DeliveryTrackingEvent, error) {
+	events := make([]sales.DeliveryTrackingEvent, 0)
+	if err := db.Conn(ctx, r.db).Where("delivery_id = ?", deliveryID).
+		Order("occurred_at ASC, id ASC").
+		Find(&events).Error; err != nil {
+		r.logger.Error("erro ao buscar eventos de rastreamento", zap.Error(err), zap.Int("delivery_id", deliveryID))
+		return nil, errors.WrapError(err, "falha ao buscar eventos de rastreamento")
+	}
+	return events, nil
+}
+
+// SaveEvents grava os eventos ainda não registrados e, quando a transportadora confirma
+// a entrega, marca a delivery enviada como entregue na data informada pelo evento
+func (r *trackingRepository) SaveEvents(ctx context.Context, deliveryID int, events []sales.DeliveryTrackingEvent) (*models.TrackingSyncResult, error) {
+	tx := db.Conn(ctx, r.db).Begin()
+	if tx.Error != nil {
+		return nil, errors.WrapError(tx.Error, "falha ao iniciar transação")
+	}
+
+	var delivery sales.Delivery
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&delivery, deliveryID).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrDeliveryNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar delivery")
+	}
+
+	result := &models.TrackingSyncResult{
+		DeliveryID:     delivery.ID,
+		TrackingNumber: delivery.TrackingNumber,
+		Carrier:        delivery.Carrier,
+	}
+
+	var deliveredEvent *sales.DeliveryTrackingEvent
+	for i := range events {
+		event := events[i]
+		event.DeliveryID = deliveryID
+
+		// Eventos já recebidos (mesma transportadora e identificador) são ignorados
+		insert := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "delivery_id"}, {Name: "carrier"}, {Name: "external_id"}},
+			DoNothing: true,
+		}).Create(&event)
+		if insert.Error != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao gravar evento de rastreamento", zap.Error(insert.Error), zap.Int("delivery_id", deliveryID))
+			return nil, errors.WrapError(insert.Error, "falha ao gravar evento de rastreamento")
+		}
+		result.NewEvents += int(insert.RowsAffected)
+
+		if event.Status == sales.TrackingStatusDelivered &&
+			(deliveredEvent == nil || event.OccurredAt.Before(deliveredEvent.OccurredAt)) {
+			deliveredEvent = &events[i]
+		}
+	}
+
+	if deliveredEvent != nil && delivery.Status == sales.DeliveryStatusShipped {
+		if err := tx.Model(&sales.Delivery{}).
+			Where("id = ?", deliveryID).
+			Updates(map[string]interface{}{
+				"status":        sales.DeliveryStatusDelivered,
+				"received_date": deliveredEvent.OccurredAt,
+			}).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao marcar delivery como delivered", zap.Error(err), zap.Int("delivery_id", deliveryID))
+			return nil, errors.WrapError(err, "falha ao marcar delivery como delivered")
+		}
+
+		if err := tx.Model(&sales.DeliveryItem{}).
+			Where("delivery_id = ?", deliveryID).
+			Update("received_qty", gorm.Expr("quantity")).Error; err != nil {
+			tx.Rollback()
+			r.logger.Error("erro ao atualizar itens como recebidos", zap.Error(err), zap.Int("delivery_id", deliveryID))
+			return nil, errors.WrapError(err, "falha ao atualizar itens da delivery")
+		}
+
+		delivery.Status = sales.DeliveryStatusDelivered
+		result.MarkedDelivered = true
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WrapError(err, "falha ao confirmar transação")
+	}
+
+	result.Status = delivery.Status
+	if result.NewEvents > 0 {
+		r.logger.Info("eventos de rastreamento gravados",
+			zap.Int("delivery_id", deliveryID),
+			zap.Int("new_events", result.NewEvents),
+			zap.Bool("delivered", result.MarkedDelivered))
+	}
+	return result, nil
+}
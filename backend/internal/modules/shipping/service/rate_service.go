@@ -0,0 +1,156 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+	calendarService "ERP-ONSMART/backend/internal/modules/calendar/service"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/shipping/carriers"
+	"ERP-ONSMART/backend/internal/modules/shipping/models"
+	"ERP-ONSMART/backend/internal/modules/shipping/repository"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// QuoteRates cota o frete nas transportadoras, em paralelo, e devolve as opções da mais barata
+// para a mais cara. Uma transportadora que falha não impede as demais; sem nenhuma opção, a
+// cotação falha com shipping_quote_unavailable.
+func QuoteRates(ctx context.Context, input models.RateQuoteInput) (*models.RateQuote, error) {
+	origin := input.OriginCEP
+	if origin == "" {
+		origin = viper.GetString("SHIPPING_ORIGIN_CEP")
+		if origin == "" {
+			return nil, errors.ErrShippingOriginNotConfigured
+		}
+	}
+	originCEP, ok := carriers.NormalizeCEP(origin)
+	if !ok {
+		return nil, errors.ErrInvalidCEP
+	}
+	destinationCEP, ok := carriers.NormalizeCEP(input.DestinationCEP)
+	if !ok {
+		return nil, errors.ErrInvalidCEP
+	}
+
+	// Sem transportadoras escolhidas, as que não têm cotação configurada são ignoradas
+	names, explicit := input.Carriers, len(input.Carriers) > 0
+	if !explicit {
+		names = carriers.RateCarriers
+	}
+	quoters := make([]carriers.RateQuoter, 0, len(names))
+	for _, name := range names {
+		quoter, err := carriers.GetQuoter(name)
+		if err != nil {
+			return nil, err
+		}
+		quoters = append(quoters, quoter)
+	}
+
+	req := carriers.RateRequest{
+		OriginCEP:      originCEP,
+		DestinationCEP: destinationCEP,
+		Parcels:        input.Parcels(),
+		DeclaredValue:  input.DeclaredValue,
+	}
+	quote := &models.RateQuote{
+		OriginCEP:      originCEP,
+		DestinationCEP: destinationCEP,
+		DeclaredValue:  input.DeclaredValue,
+		Rates:          make([]carriers.Rate, 0),
+		Failures:       make([]models.RateFailure, 0),
+	}
+
+	log := logger.WithModule("rate_service")
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, quoter := range quoters {
+		wg.Add(1)
+		go func(quoter carriers.RateQuoter) {
+			defer wg.Done()
+			rates, err := quoter.Quote(ctx, req)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == errors.ErrRatesNotSupported && !explicit {
+				return
+			}
+			if err != nil {
+				log.Warn("falha ao cotar frete", zap.String("carrier", quoter.Name()), zap.Error(err))
+				quote.Failures = append(quote.Failures, models.RateFailure{Carrier: quoter.Name(), Error: err.Error()})
+				return
+			}
+			quote.Rates = append(quote.Rates, rates...)
+		}(quoter)
+	}
+	wg.Wait()
+
+	if len(quote.Rates) == 0 {
+		return nil, errors.ErrShippingQuoteUnavailable
+	}
+
+	// O prazo das transportadoras é em dias úteis; sem o calendário da empresa, vale o padrão
+	schedule, err := calendarService.Schedule(ctx)
+	if err != nil {
+		log.Warn("falha ao carregar calendário para a previsão de entrega", zap.Error(err))
+		schedule = calendar.DefaultSchedule()
+	}
+	now := time.Now()
+	for i := range quote.Rates {
+		quote.Rates[i].EstimatedDeliveryDate = schedule.AddBusinessDays(now, quote.Rates[i].DeliveryDays).Format("2006-01-02")
+	}
+	sort.SliceStable(quote.Rates, func(i, j int) bool {
+		if quote.Rates[i].Price != quote.Rates[j].Price {
+			return quote.Rates[i].Price < quote.Rates[j].Price
+		}
+		return quote.Rates[i].DeliveryDays < quote.Rates[j].DeliveryDays
+	})
+	sort.Slice(quote.Failures, func(i, j int) bool { return quote.Failures[i].Carrier < quote.Failures[j].Carrier })
+	return quote, nil
+}
+
+// QuoteDocumentRates cota o frete da cotação ou do pedido de venda; sem CEP de destino ou valor
+// declarado, usa o CEP do cliente e o total do documento
+func QuoteDocumentRates(ctx context.Context, document string, id int, input models.RateQuoteInput) (*models.RateQuote, error) {
+	repo, err := repository.NewRateRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := repo.GetQuoteDefaults(ctx, document, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.DestinationCEP == "" {
+		input.DestinationCEP = defaults.ZipCode
+	}
+	if input.DeclaredValue == 0 {
+		input.DeclaredValue = defaults.GrandTotal
+	}
+	return QuoteRates(ctx, input)
+}
+
+// SetShippingOption grava a opção de frete escolhida na cotação ou no pedido de venda
+func SetShippingOption(ctx context.Context, document string, id int, input models.ShippingOptionInput) (*sales.ShippingOption, error) {
+	repo, err := repository.NewRateRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	option := sales.ShippingOption{
+		ShippingCarrier:     input.Carrier,
+		ShippingServiceCode: input.ServiceCode,
+		ShippingService:     input.ServiceName,
+		ShippingCost:        input.Price,
+		ShippingDays:        input.DeliveryDays,
+	}
+	if err := repo.SetShippingOption(ctx, document, id, option); err != nil {
+		return nil, err
+	}
+	return &option, nil
+}
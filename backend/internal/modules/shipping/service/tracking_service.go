@@ -0,0 +1,254 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/shipping/carriers"
+	"ERP-ONSMART/backend/internal/modules/shipping/models"
+	"ERP-ONSMART/backend/internal/modules/shipping/repository"
+	webhooks "ERP-ONSMART/backend/internal/modules/webhooks/models"
+	webhooksService "ERP-ONSMART/backend/internal/modules/webhooks/service"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// GetTrackingEvents retorna o histórico de eventos de rastreamento da entrega
+func GetTrackingEvents(ctx context.Context, deliveryID int) ([]sales.DeliveryTrackingEvent, error) {
+	repo, err := repository.NewTrackingRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := repo.GetDelivery(ctx, deliveryID); err != nil {
+		return nil, err
+	}
+	return repo.GetEvents(ctx, deliveryID)
+}
+
+// RefreshTracking consulta a transportadora da entrega e grava os novos eventos
+func RefreshTracking(ctx context.Context, deliveryID int) (*models.TrackingSyncResult, error) {
+	repo, err := repository.NewTrackingRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return refreshDelivery(ctx, repo, delivery)
+}
+
+// PollShippedDeliveries consulta todas as entregas enviadas cuja transportadora permite consulta ativa.
+// Com tenant.AllCompanies (consulta agendada), cada entrega é atualizada no contexto da sua empresa.
+func PollShippedDeliveries(ctx context.Context) (*models.PollSummary, error) {
+	repo, err := repository.NewTrackingRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := repo.GetDeliveriesToPoll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metrics.QueueDepth.Set(float64(len(deliveries)), "tracking_poll")
+
+	summary := &models.PollSummary{
+		Failures: make([]models.PollFailure, 0),
+		Results:  make([]models.TrackingSyncResult, 0),
+	}
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return summary, ctx.Err()
+		}
+
+		deliveryCtx := ctx
+		if tenant.IsAllCompanies(ctx) {
+			deliveryCtx = tenant.WithCompany(ctx, deliveries[i].CompanyID)
+		}
+
+		result, err := refreshDelivery(deliveryCtx, repo, &deliveries[i])
+		if err == errors.ErrTrackingNotSupported || err == errors.ErrUnknownCarrier {
+			summary.Skipped++
+			continue
+		}
+
+		summary.Checked++
+		if err != nil {
+			summary.Failures = append(summary.Failures, models.PollFailure{DeliveryID: deliveries[i].ID, Error: err.Error()})
+			continue
+		}
+
+		summary.NewEvents += result.NewEvents
+		if result.MarkedDelivered {
+			summary.Delivered++
+		}
+		summary.Results = append(summary.Results, *result)
+	}
+
+	return summary, nil
+}
+
+// HandleCarrierWebhook valida a assinatura do webhook e grava os eventos nas entregas
+// com os códigos de rastreamento informados, de qualquer empresa: a transportadora não
+// conhece a empresa, então cada entrega é atualizada no contexto da sua. O webhook fica
+// gravado, e um que falhou pode ser processado de novo por `erpctl webhook-replay`.
+func HandleCarrierWebhook(ctx context.Context, carrierName string, body []byte, signature string) ([]models.TrackingSyncResult, error) {
+	receiver, err := webhookReceiver(carrierName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !carriers.VerifySignature(viper.GetString("CARRIER_WEBHOOK_SECRET"), body, signature) {
+		return nil, errors.ErrInvalidWebhookSignature
+	}
+
+	var results []models.TrackingSyncResult
+	err = webhooksService.Receive(ctx, webhooks.SourceCarrier, carrierName, body, func(ctx context.Context) error {
+		var err error
+		results, err = applyCarrierWebhook(ctx, receiver, body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ReplayCarrierWebhook processa de novo um webhook de transportadora gravado, com a assinatura
+// já conferida
+func ReplayCarrierWebhook(ctx context.Context, delivery *webhooks.WebhookDelivery) error {
+	receiver, err := webhookReceiver(delivery.Provider)
+	if err != nil {
+		return err
+	}
+	_, err = applyCarrierWebhook(ctx, receiver, delivery.Payload)
+	return err
+}
+
+// webhookReceiver retorna a transportadora que envia webhooks de rastreamento
+func webhookReceiver(carrierName string) (carriers.WebhookReceiver, error) {
+	carrier, err := carriers.Get(carrierName)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver, ok := carrier.(carriers.WebhookReceiver)
+	if !ok {
+		return nil, errors.ErrWebhookNotSupported
+	}
+	return receiver, nil
+}
+
+// applyCarrierWebhook grava os eventos do webhook nas entregas dos códigos de rastreamento
+func applyCarrierWebhook(ctx context.Context, receiver carriers.WebhookReceiver, body []byte) ([]models.TrackingSyncResult, error) {
+	updates, err := receiver.ParseWebhook(body)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewTrackingRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	log := logger.WithModule("tracking_service")
+	results := make([]models.TrackingSyncResult, 0)
+	for _, update := range updates {
+		deliveries, err := repo.GetDeliveriesByTrackingNumber(tenant.AllCompanies(ctx), update.TrackingNumber)
+		if err != nil {
+			return nil, err
+		}
+		if len(deliveries) == 0 {
+			log.Warn("webhook com código de rastreamento desconhecido", zap.String("tracking_number", update.TrackingNumber))
+			continue
+		}
+
+		for _, delivery := range deliveries {
+			result, err := repo.SaveEvents(tenant.WithCompany(ctx, delivery.CompanyID), delivery.ID, toTrackingEvents(receiver.Name(), update.Events))
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, *result)
+		}
+	}
+
+	return results, nil
+}
+
+// StartTrackingPoller consulta periodicamente as transportadoras até o contexto ser cancelado
+func StartTrackingPoller(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("tracking_poller")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				summary, err := PollShippedDeliveries(tenant.AllCompanies(ctx))
+				metrics.ObserveJob("tracking_poller", err)
+				if err != nil {
+					log.Error("erro ao consultar rastreamento das entregas", zap.Error(err))
+					continue
+				}
+				log.Info("consulta de rastreamento concluída",
+					zap.Int("checked", summary.Checked),
+					zap.Int("new_events", summary.NewEvents),
+					zap.Int("delivered", summary.Delivered),
+					zap.Int("failures", len(summary.Failures)))
+			}
+		}
+	}()
+}
+
+// refreshDelivery consulta a transportadora de uma entrega e grava os eventos retornados
+func refreshDelivery(ctx context.Context, repo repository.TrackingRepository, delivery *sales.Delivery) (*models.TrackingSyncResult, error) {
+	if delivery.TrackingNumber == "" {
+		return nil, errors.ErrMissingTrackingNumber
+	}
+
+	carrier, err := carriers.Get(carriers.Resolve(delivery.Carrier, delivery.ShippingMethod))
+	if err != nil {
+		return nil, err
+	}
+
+	tracker, ok := carrier.(carriers.Tracker)
+	if !ok {
+		return nil, errors.ErrTrackingNotSupported
+	}
+
+	events, err := tracker.Track(ctx, delivery.TrackingNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.SaveEvents(ctx, delivery.ID, toTrackingEvents(tracker.Name(), events))
+}
+
+// toTrackingEvents converte os eventos da transportadora para o modelo persistido
+func toTrackingEvents(carrier string, events []carriers.Event) []sales.DeliveryTrackingEvent {
+	result := make([]sales.DeliveryTrackingEvent, 0, len(events))
+	for _, ev := range events {
+		result = append(result, sales.DeliveryTrackingEvent{
+			Carrier:     carrier,
+			ExternalID:  ev.ExternalID,
+			EventCode:   ev.Code,
+			Status:      ev.Status,
+			Description: ev.Description,
+			Location:    ev.Location,
+			OccurredAt:  ev.OccurredAt,
+			RawPayload:  ev.Raw,
+		})
+	}
+	return result
+}
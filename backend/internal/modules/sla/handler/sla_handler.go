@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/sla/models"
+	"ERP-ONSMART/backend/internal/modules/sla/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+// Lista as definições de SLA da empresa (envio das entregas e conclusão dos processos de venda)
+// @Security BearerAuth
+func ListSLADefinitionsHandler(c *gin.Context) {
+	definitions, err := service.ListDefinitions(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar definições de SLA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"definitions": definitions})
+}
+
+// Cadastra o prazo de SLA de um alvo: delivery_shipment (envio da entrega a partir do pedido de
+// venda) ou process_completion (conclusão do processo de venda), em business_days, days ou hours
+// @Security BearerAuth
+func CreateSLADefinitionHandler(c *gin.Context) {
+	var input models.DefinitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	definition, err := service.CreateDefinition(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar definição de SLA")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"definition": definition})
+}
+
+// Altera uma definição de SLA; o status das entregas ou processos é recalculado na próxima
+// avaliação
+// @Security BearerAuth
+// @Param id path int true "ID da definição"
+func UpdateSLADefinitionHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.DefinitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	definition, err := service.UpdateDefinition(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar definição de SLA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"definition": definition})
+}
+
+// Remove uma definição de SLA e o status calculado do alvo
+// @Security BearerAuth
+// @Param id path int true "ID da definição"
+func DeleteSLADefinitionHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteDefinition(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("erro ao remover definição de SLA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Definição de SLA removida com sucesso"})
+}
+
+// Avalia na hora o SLA das entregas e processos de venda da empresa, sem esperar a rotina
+// periódica
+// @Security BearerAuth
+func EvaluateSLAHandler(c *gin.Context) {
+	updated, err := service.EvaluateCompany(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("erro ao avaliar SLA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// Relatório de cumprimento do SLA por alvo: registros com vencimento no período cumpridos, violados
+// e em aberto, e o percentual de cumprimento
+// @Security BearerAuth
+// @Param from query string false "Data inicial (AAAA-MM-DD, padrão início do mês)"
+// @Param to query string false "Data final (AAAA-MM-DD, padrão fim do mês)"
+func GetSLAComplianceHandler(c *gin.Context) {
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return
+	}
+
+	report, err := service.GetCompliance(c.Request.Context(), from, to)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao apurar cumprimento de SLA")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"compliance": report})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+// parseDateQuery lê o parâmetro de data (AAAA-MM-DD); vazio resulta em data zero
+func parseDateQuery(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		c.Error(errors.InvalidParam(name + " inválido, use o formato AAAA-MM-DD"))
+		return time.Time{}, false
+	}
+	return date, true
+}
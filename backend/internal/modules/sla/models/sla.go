@@ -0,0 +1,187 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+)
+
+// Alvos de SLA: o que é medido e de onde o prazo começa a contar
+const (
+	// TargetDeliveryShipment mede o envio da entrega a partir da confirmação do pedido de venda
+	TargetDeliveryShipment = "delivery_shipment"
+	// TargetProcessCompletion mede a conclusão do processo de venda a partir da abertura
+	TargetProcessCompletion = "process_completion"
+)
+
+// Unidades do prazo
+const (
+	UnitBusinessDays = "business_days"
+	UnitDays         = "days"
+	UnitHours        = "hours"
+)
+
+// Status de SLA gravados nas entregas e processos de venda
+const (
+	// StatusOnTrack: em aberto e dentro do prazo
+	StatusOnTrack = "on_track"
+	// StatusMet: concluído dentro do prazo
+	StatusMet = "met"
+	// StatusBreached: concluído após o prazo ou em aberto com o prazo vencido
+	StatusBreached = "breached"
+)
+
+// Definition é o prazo de SLA da empresa para um alvo
+type Definition struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	Name      string    `json:"name"`
+	Target    string    `json:"target"`
+	Duration  int       `json:"duration"`
+	Unit      string    `json:"unit"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName define o nome da tabela
+func (Definition) TableName() string {
+	return "sla_definitions"
+}
+
+// DefinitionInput é o corpo aceito em POST e PUT /sla/definitions
+type DefinitionInput struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Target   string `json:"target" binding:"required,oneof=delivery_shipment process_completion"`
+	Duration int    `json:"duration" binding:"required,gt=0"`
+	Unit     string `json:"unit" binding:"required,oneof=business_days days hours"`
+	Active   *bool  `json:"active"`
+}
+
+// DueAt calcula o vencimento do prazo a partir do início; os dias úteis seguem o calendário da
+// empresa (nil usa o calendário padrão)
+func (d Definition) DueAt(start time.Time, schedule *calendar.Schedule) time.Time {
+	switch d.Unit {
+	case UnitHours:
+		return start.Add(time.Duration(d.Duration) * time.Hour)
+	case UnitDays:
+		return start.AddDate(0, 0, d.Duration)
+	default:
+		if schedule == nil {
+			schedule = calendar.DefaultSchedule()
+		}
+		return schedule.AddBusinessDays(start, d.Duration)
+	}
+}
+
+// Evaluate classifica o SLA pelo vencimento, pela conclusão (nil se em aberto) e pelo momento atual
+func Evaluate(due time.Time, completedAt *time.Time, now time.Time) string {
+	if completedAt != nil {
+		if completedAt.After(due) {
+			return StatusBreached
+		}
+		return StatusMet
+	}
+	if now.After(due) {
+		return StatusBreached
+	}
+	return StatusOnTrack
+}
+
+// Subject é uma entrega ou processo de venda sob avaliação, com o status gravado na última
+// avaliação
+type Subject struct {
+	ID          int
+	StartedAt   time.Time
+	CompletedAt *time.Time
+	Status      *string
+	DueAt       *time.Time
+}
+
+// Evaluation é o status de SLA a gravar em uma entrega ou processo de venda
+type Evaluation struct {
+	ID     int
+	Status string
+	DueAt  time.Time
+}
+
+// EvaluateSubjects avalia os registros pela definição e retorna só os que mudaram de status ou
+// de vencimento
+func EvaluateSubjects(definition Definition, schedule *calendar.Schedule, subjects []Subject, now time.Time) []Evaluation {
+	evaluations := []Evaluation{}
+	for _, subject := range subjects {
+		due := definition.DueAt(subject.StartedAt, schedule)
+		status := Evaluate(due, subject.CompletedAt, now)
+		if subject.Status != nil && *subject.Status == status && subject.DueAt != nil && subject.DueAt.Equal(due) {
+			continue
+		}
+		evaluations = append(evaluations, Evaluation{ID: subject.ID, Status: status, DueAt: due})
+	}
+	return evaluations
+}
+
+// StatusCount é a quantidade de registros do alvo em um status de SLA
+type StatusCount struct {
+	Target string
+	Status string
+	Count  int
+}
+
+// ComplianceLine é o cumprimento do SLA de um alvo no período
+type ComplianceLine struct {
+	Target     string  `json:"target"`
+	Name       string  `json:"name"`
+	Duration   int     `json:"duration"`
+	Unit       string  `json:"unit"`
+	Active     bool    `json:"active"`
+	Total      int     `json:"total"`
+	Met        int     `json:"met"`
+	Breached   int     `json:"breached"`
+	OnTrack    int     `json:"on_track"`
+	Compliance float64 `json:"compliance_percentage"`
+}
+
+// ComplianceReport é o relatório de cumprimento de SLA dos registros com vencimento no período
+type ComplianceReport struct {
+	From  string           `json:"from"`
+	To    string           `json:"to"`
+	Lines []ComplianceLine `json:"lines"`
+}
+
+// BuildCompliance monta uma linha por definição. O percentual considera só os registros já
+// decididos (cumpridos ou violados); sem nenhum, fica em 100%.
+func BuildCompliance(definitions []Definition, counts []StatusCount) []ComplianceLine {
+	lines := make([]ComplianceLine, 0, len(definitions))
+	for _, definition := range definitions {
+		line := ComplianceLine{
+			Target:   definition.Target,
+			Name:     definition.Name,
+			Duration: definition.Duration,
+			Unit:     definition.Unit,
+			Active:   definition.Active,
+		}
+		for _, count := range counts {
+			if count.Target != definition.Target {
+				continue
+			}
+			switch count.Status {
+			case StatusMet:
+				line.Met += count.Count
+			case StatusBreached:
+				line.Breached += count.Count
+			case StatusOnTrack:
+				line.OnTrack += count.Count
+			default:
+				continue
+			}
+			line.Total += count.Count
+		}
+		line.Compliance = 100
+		if decided := line.Met + line.Breached; decided > 0 {
+			line.Compliance = math.Round(float64(line.Met)/float64(decided)*10000) / 100
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefinitionDueAt(t *testing.T) {
+	start := time.Date(2024, 5, 17, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 5, 21, 10, 0, 0, 0, time.UTC), Definition{Duration: 2, Unit: UnitBusinessDays}.DueAt(start, nil))
+	assert.Equal(t, time.Date(2024, 6, 16, 10, 0, 0, 0, time.UTC), Definition{Duration: 30, Unit: UnitDays}.DueAt(start, nil))
+	assert.Equal(t, time.Date(2024, 5, 18, 10, 0, 0, 0, time.UTC), Definition{Duration: 24, Unit: UnitHours}.DueAt(start, nil))
+
+	saturday := time.Date(2024, 5, 18, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 5, 21, 8, 0, 0, 0, time.UTC), Definition{Duration: 1, Unit: UnitBusinessDays}.DueAt(saturday, nil))
+}
+
+func TestDefinitionDueAtUsesCompanyCalendar(t *testing.T) {
+	state := "SP"
+	schedule := calendar.NewSchedule(
+		calendar.Calendar{State: &state, WorkingDays: []int{1, 2, 3, 4, 5, 6}, OpensAt: "08:00", ClosesAt: "18:00"},
+		[]calendar.Holiday{{Date: time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC), Name: "Revolução Constitucionalista", State: &state}},
+	)
+	friday := time.Date(2024, 7, 5, 10, 0, 0, 0, time.UTC)
+
+	// sábado conta como dia útil e o feriado estadual de terça não
+	assert.Equal(t, time.Date(2024, 7, 10, 10, 0, 0, 0, time.UTC), Definition{Duration: 3, Unit: UnitBusinessDays}.DueAt(friday, schedule))
+}
+
+func TestEvaluate(t *testing.T) {
+	due := time.Date(2024, 5, 21, 10, 0, 0, 0, time.UTC)
+	early := due.Add(-time.Hour)
+	late := due.Add(time.Hour)
+
+	assert.Equal(t, StatusMet, Evaluate(due, &early, late))
+	assert.Equal(t, StatusMet, Evaluate(due, &due, late))
+	assert.Equal(t, StatusBreached, Evaluate(due, &late, late))
+	assert.Equal(t, StatusOnTrack, Evaluate(due, nil, early))
+	assert.Equal(t, StatusBreached, Evaluate(due, nil, late))
+}
+
+func TestEvaluateSubjectsReturnsOnlyChanges(t *testing.T) {
+	definition := Definition{Duration: 2, Unit: UnitBusinessDays}
+	start := time.Date(2024, 5, 20, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 5, 22, 10, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 5, 21, 8, 0, 0, 0, time.UTC)
+	onTrack := StatusOnTrack
+	shipped := time.Date(2024, 5, 21, 7, 0, 0, 0, time.UTC)
+
+	evaluations := EvaluateSubjects(definition, nil, []Subject{
+		{ID: 1, StartedAt: start},
+		{ID: 2, StartedAt: start, Status: &onTrack, DueAt: &due},
+		{ID: 3, StartedAt: start, Status: &onTrack, DueAt: &due, CompletedAt: &shipped},
+	}, now)
+
+	require.Len(t, evaluations, 2)
+	assert.Equal(t, Evaluation{ID: 1, Status: StatusOnTrack, DueAt: due}, evaluations[0])
+	assert.Equal(t, Evaluation{ID: 3, Status: StatusMet, DueAt: due}, evaluations[1])
+}
+
+func TestBuildCompliance(t *testing.T) {
+	definitions := []Definition{
+		{Name: "Envio em 2 dias úteis", Target: TargetDeliveryShipment, Duration: 2, Unit: UnitBusinessDays, Active: true},
+		{Name: "Processo em 30 dias", Target: TargetProcessCompletion, Duration: 30, Unit: UnitDays, Active: true},
+	}
+	counts := []StatusCount{
+		{Target: TargetDeliveryShipment, Status: StatusMet, Count: 17},
+		{Target: TargetDeliveryShipment, Status: StatusBreached, Count: 3},
+		{Target: TargetDeliveryShipment, Status: StatusOnTrack, Count: 5},
+	}
+
+	lines := BuildCompliance(definitions, counts)
+
+	require.Len(t, lines, 2)
+	assert.Equal(t, 25, lines[0].Total)
+	assert.Equal(t, 85.0, lines[0].Compliance)
+	assert.Equal(t, 5, lines[0].OnTrack)
+	assert.Zero(t, lines[1].Total)
+	assert.Equal(t, 100.0, lines[1].Compliance)
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+	calendarRepository "ERP-ONSMART/backend/internal/modules/calendar/repository"
+	salesRepository "ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/modules/sla/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SLARepository mantém as definições de SLA e lê e grava o status de SLA das entregas e dos
+// processos de venda
+type SLARepository interface {
+	ListDefinitions(ctx context.Context) ([]models.Definition, error)
+	GetDefinition(ctx context.Context, id int) (*models.Definition, error)
+	CreateDefinition(ctx context.Context, definition *models.Definition) error
+	UpdateDefinition(ctx context.Context, definition *models.Definition) error
+	DeleteDefinition(ctx context.Context, id int) error
+
+	ActiveDefinitions(ctx context.Context) ([]models.Definition, error)
+	Subjects(ctx context.Context, target string) ([]models.Subject, error)
+	SaveEvaluations(ctx context.Context, target string, evaluations []models.Evaluation) error
+	ClearCancelledProcesses(ctx context.Context) error
+	StatusCounts(ctx context.Context, from, to time.Time) ([]models.StatusCount, error)
+	Schedule(ctx context.Context) (*calendar.Schedule, error)
+}
+
+// subjectTables são as tabelas que recebem o status de SLA de cada alvo
+var subjectTables = map[string]string{
+	models.TargetDeliveryShipment:  "deliveries",
+	models.TargetProcessCompletion: "sales_processes",
+}
+
+type slaRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewSLARepository cria uma nova instância do repositório
+func NewSLARepository() (SLARepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &slaRepository{
+		db:     db,
+		logger: logger.WithModule("sla_repository"),
+	}, nil
+}
+
+// ListDefinitions lista as definições de SLA da empresa
+func (r *slaRepository) ListDefinitions(ctx context.Context) ([]models.Definition, error) {
+	var definitions []models.Definition
+	if err := r.db.WithContext(ctx).Order("target ASC").Find(&definitions).Error; err != nil {
+		r.logger.Error("erro ao listar definições de SLA", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar definições de SLA")
+	}
+	return definitions, nil
+}
+
+// GetDefinition busca uma definição de SLA
+func (r *slaRepository) GetDefinition(ctx context.Context, id int) (*models.Definition, error) {
+	var definition models.Definition
+	if err := r.db.WithContext(ctx).First(&definition, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrSLADefinitionNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar definição de SLA")
+	}
+	return &definition, nil
+}
+
+// CreateDefinition grava a definição; cada alvo tem uma definição por empresa
+func (r *slaRepository) CreateDefinition(ctx context.Context, definition *models.Definition) error {
+	if err := r.checkTarget(ctx, definition); err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(definition).Error; err != nil {
+		r.logger.Error("erro ao criar definição de SLA", zap.Error(err), zap.String("target", definition.Target))
+		return errors.WrapError(err, "falha ao criar definição de SLA")
+	}
+	return nil
+}
+
+// UpdateDefinition altera a definição e apaga o status de SLA calculado com a regra anterior,
+// nos dois alvos se o alvo mudou; a próxima avaliação recalcula tudo
+func (r *slaRepository) UpdateDefinition(ctx context.Context, definition *models.Definition) error {
+	if err := r.checkTarget(ctx, definition); err != nil {
+		return err
+	}
+	previous, err := r.GetDefinition(ctx, definition.ID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(definition).
+			Select("name", "target", "duration", "unit", "active", "updated_at").
+			Updates(definition)
+		if result.Error != nil {
+			r.logger.Error("erro ao atualizar definição de SLA", zap.Error(result.Error), zap.Int("id", definition.ID))
+			return errors.WrapError(result.Error, "falha ao atualizar definição de SLA")
+		}
+		if err := r.clearStatuses(ctx, tx, previous.Target); err != nil {
+			return err
+		}
+		if previous.Target != definition.Target {
+			return r.clearStatuses(ctx, tx, definition.Target)
+		}
+		return nil
+	})
+}
+
+// DeleteDefinition remove a definição e o status de SLA do alvo
+func (r *slaRepository) DeleteDefinition(ctx context.Context, id int) error {
+	definition, err := r.GetDefinition(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Definition{}, id).Error; err != nil {
+			r.logger.Error("erro ao remover definição de SLA", zap.Error(err), zap.Int("id", id))
+			return errors.WrapError(err, "falha ao remover definição de SLA")
+		}
+		return r.clearStatuses(ctx, tx, definition.Target)
+	})
+}
+
+func (r *slaRepository) checkTarget(ctx context.Context, definition *models.Definition) error {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Definition{}).
+		Where("target = ? AND id <> ?", definition.Target, definition.ID).
+		Count(&count).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao verificar definição de SLA")
+	}
+	if count > 0 {
+		return errors.ErrDuplicateSLADefinition
+	}
+	return nil
+}
+
+// clearStatuses apaga o status de SLA dos registros do alvo na empresa do contexto
+func (r *slaRepository) clearStatuses(ctx context.Context, tx *gorm.DB, target string) error {
+	table := subjectTables[target]
+	err := tx.Table(table).Scopes(tenant.Scope(ctx, table)).
+		Where("(sla_status IS NOT NULL OR sla_due_at IS NOT NULL)").
+		UpdateColumns(map[string]interface{}{"sla_status": nil, "sla_due_at": nil}).Error
+	if err != nil {
+		r.logger.Error("erro ao limpar status de SLA", zap.Error(err), zap.String("target", target))
+		return errors.WrapError(err, "falha ao limpar status de SLA")
+	}
+	return nil
+}
+
+// ActiveDefinitions retorna as definições ativas. O contexto deve vir de tenant.AllCompanies:
+// a avaliação periódica atende todas as empresas.
+func (r *slaRepository) ActiveDefinitions(ctx context.Context) ([]models.Definition, error) {
+	var definitions []models.Definition
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Order("company_id ASC, target ASC").Find(&definitions).Error; err != nil {
+		r.logger.Error("erro ao buscar definições de SLA ativas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar definições de SLA ativas")
+	}
+	return definitions, nil
+}
+
+// Subjects retorna os registros do alvo ainda não decididos (sem status ou dentro do prazo).
+// Nas entregas de pedido de venda o prazo conta da criação do pedido (os pedidos nascem
+// confirmados na conversão da cotação e nas lojas virtuais) até o envio; nos processos de venda,
+// da abertura até a conclusão, cuja data é a última alteração do processo concluído. Os processos
+// cancelados ficam fora.
+func (r *slaRepository) Subjects(ctx context.Context, target string) ([]models.Subject, error) {
+	var query *gorm.DB
+	var alias string
+	switch target {
+	case models.TargetDeliveryShipment:
+		alias = "d"
+		query = r.db.WithContext(ctx).Table("deliveries d").
+			Select("d.id, so.created_at AS started_at, d.shipped_at AS completed_at, d.sla_status AS status, d.sla_due_at AS due_at").
+			Joins("JOIN sales_orders so ON so.id = d.sales_order_id").
+			Scopes(tenant.Scope(ctx, "d")).
+			Where("d.deleted_at IS NULL")
+	case models.TargetProcessCompletion:
+		alias = "p"
+		query = r.db.WithContext(ctx).Table("sales_processes p").
+			Select("p.id, p.created_at AS started_at, CASE WHEN p.status = ? THEN p.updated_at END AS completed_at, p.sla_status AS status, p.sla_due_at AS due_at",
+				salesRepository.ProcessStatusCompleted).
+			Scopes(tenant.Scope(ctx, "p")).
+			Where("p.status <> ?", salesRepository.ProcessStatusCancelled)
+	default:
+		return nil, errors.ErrInvalidSLADefinition
+	}
+
+	var subjects []models.Subject
+	err := query.Where("("+alias+".sla_status IS NULL OR "+alias+".sla_status = ?)", models.StatusOnTrack).
+		Order(alias + ".id ASC").
+		Scan(&subjects).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar registros para o SLA", zap.Error(err), zap.String("target", target))
+		return nil, errors.WrapError(err, "falha ao buscar registros para o SLA")
+	}
+	return subjects, nil
+}
+
+// SaveEvaluations grava o status de SLA sem alterar o updated_at dos registros
+func (r *slaRepository) SaveEvaluations(ctx context.Context, target string, evaluations []models.Evaluation) error {
+	table, ok := subjectTables[target]
+	if !ok {
+		return errors.ErrInvalidSLADefinition
+	}
+	if len(evaluations) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, evaluation := range evaluations {
+			err := tx.Table(table).Scopes(tenant.Scope(ctx, table)).
+				Where("id = ?", evaluation.ID).
+				UpdateColumns(map[string]interface{}{"sla_status": evaluation.Status, "sla_due_at": evaluation.DueAt}).Error
+			if err != nil {
+				r.logger.Error("erro ao gravar status de SLA", zap.Error(err), zap.String("target", target), zap.Int("id", evaluation.ID))
+				return errors.WrapError(err, "falha ao gravar status de SLA")
+			}
+		}
+		return nil
+	})
+}
+
+// ClearCancelledProcesses apaga o status de SLA dos processos de venda cancelados
+func (r *slaRepository) ClearCancelledProcesses(ctx context.Context) error {
+	err := r.db.WithContext(ctx).Table("sales_processes").Scopes(tenant.Scope(ctx, "sales_processes")).
+		Where("status = ? AND sla_status IS NOT NULL", salesRepository.ProcessStatusCancelled).
+		UpdateColumns(map[string]interface{}{"sla_status": nil, "sla_due_at": nil}).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao limpar SLA dos processos cancelados")
+	}
+	return nil
+}
+
+// StatusCounts conta, por alvo e status, os registros com vencimento do SLA entre as datas
+func (r *slaRepository) StatusCounts(ctx context.Context, from, to time.Time) ([]models.StatusCount, error) {
+	counts := []models.StatusCount{}
+	for _, target := range []string{models.TargetDeliveryShipment, models.TargetProcessCompletion} {
+		table := subjectTables[target]
+		query := r.db.WithContext(ctx).Table(table).
+			Select("sla_status AS status, COUNT(*) AS count").
+			Scopes(tenant.Scope(ctx, table)).
+			Where("sla_status IS NOT NULL AND sla_due_at >= ? AND sla_due_at < ?", from, to.AddDate(0, 0, 1))
+		if target == models.TargetDeliveryShipment {
+			query = query.Where("deleted_at IS NULL")
+		}
+
+		var rows []models.StatusCount
+		if err := query.Group("sla_status").Scan(&rows).Error; err != nil {
+			r.logger.Error("erro ao contar status de SLA", zap.Error(err), zap.String("target", target))
+			return nil, errors.WrapError(err, "falha ao contar status de SLA")
+		}
+		for _, row := range rows {
+			row.Target = target
+			counts = append(counts, row)
+		}
+	}
+	return counts, nil
+}
+
+// Schedule monta o calendário de dias úteis da empresa do contexto para os prazos em dias úteis
+func (r *slaRepository) Schedule(ctx context.Context) (*calendar.Schedule, error) {
+	return calendarRepository.LoadSchedule(r.db.WithContext(ctx))
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/modules/sla/models"
+	"ERP-ONSMART/backend/internal/modules/sla/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+const dateLayout = "2006-01-02"
+
+// SLAService mantém as definições de SLA, avalia as entregas e processos de venda e apura o
+// cumprimento por período
+type SLAService struct {
+	newRepo func() (repository.SLARepository, error)
+	now     func() time.Time
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.SLARepository
+}
+
+// NewSLAService cria o serviço sobre o repositório informado
+func NewSLAService(newRepo func() (repository.SLARepository, error)) *SLAService {
+	return &SLAService{
+		newRepo: newRepo,
+		now:     time.Now,
+		logger:  logger.WithModule("sla_service"),
+	}
+}
+
+var defaultService = NewSLAService(repository.NewSLARepository)
+
+// ListDefinitions lista as definições de SLA da empresa
+func ListDefinitions(ctx context.Context) ([]models.Definition, error) {
+	return defaultService.ListDefinitions(ctx)
+}
+
+// CreateDefinition valida e grava uma definição de SLA
+func CreateDefinition(ctx context.Context, input models.DefinitionInput) (*models.Definition, error) {
+	return defaultService.CreateDefinition(ctx, input)
+}
+
+// UpdateDefinition valida e altera uma definição de SLA
+func UpdateDefinition(ctx context.Context, id int, input models.DefinitionInput) (*models.Definition, error) {
+	return defaultService.UpdateDefinition(ctx, id, input)
+}
+
+// DeleteDefinition remove uma definição de SLA
+func DeleteDefinition(ctx context.Context, id int) error {
+	return defaultService.DeleteDefinition(ctx, id)
+}
+
+// EvaluateCompany avalia na hora o SLA da empresa do contexto
+func EvaluateCompany(ctx context.Context) (int, error) {
+	return defaultService.EvaluateCompany(ctx)
+}
+
+// GetCompliance apura o cumprimento do SLA dos registros com vencimento no período
+func GetCompliance(ctx context.Context, from, to time.Time) (*models.ComplianceReport, error) {
+	return defaultService.Compliance(ctx, from, to)
+}
+
+// StartSLAScheduler avalia periodicamente o SLA das entregas e processos de todas as empresas
+func StartSLAScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("sla_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				updated, err := defaultService.RunEvaluation(ctx)
+				metrics.ObserveJob("sla_scheduler", err)
+				if err != nil {
+					log.Error("erro ao avaliar SLA", zap.Error(err))
+					continue
+				}
+				if updated > 0 {
+					log.Info("status de SLA atualizados", zap.Int("records", updated))
+				}
+			}
+		}
+	}()
+}
+
+func (s *SLAService) repository() (repository.SLARepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListDefinitions lista as definições de SLA da empresa
+func (s *SLAService) ListDefinitions(ctx context.Context) ([]models.Definition, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDefinitions(ctx)
+}
+
+// CreateDefinition grava a definição, ativa se não informado
+func (s *SLAService) CreateDefinition(ctx context.Context, input models.DefinitionInput) (*models.Definition, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	definition := &models.Definition{Active: true}
+	if err := applyInput(definition, input); err != nil {
+		return nil, err
+	}
+	if err := repo.CreateDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// UpdateDefinition altera a definição; o status já calculado é refeito na próxima avaliação
+func (s *SLAService) UpdateDefinition(ctx context.Context, id int, input models.DefinitionInput) (*models.Definition, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	definition, err := repo.GetDefinition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyInput(definition, input); err != nil {
+		return nil, err
+	}
+	if err := repo.UpdateDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// DeleteDefinition remove a definição e o status de SLA do alvo
+func (s *SLAService) DeleteDefinition(ctx context.Context, id int) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return repo.DeleteDefinition(ctx, id)
+}
+
+// RunEvaluation avalia as definições ativas de todas as empresas e retorna quantos registros
+// mudaram de status
+func (s *SLAService) RunEvaluation(ctx context.Context) (int, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, err
+	}
+	definitions, err := repo.ActiveDefinitions(tenant.AllCompanies(ctx))
+	if err != nil {
+		return 0, err
+	}
+	return s.evaluate(ctx, repo, definitions)
+}
+
+// EvaluateCompany avalia as definições ativas da empresa do contexto
+func (s *SLAService) EvaluateCompany(ctx context.Context) (int, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, err
+	}
+	definitions, err := repo.ListDefinitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	active := make([]models.Definition, 0, len(definitions))
+	for _, definition := range definitions {
+		if definition.Active {
+			active = append(active, definition)
+		}
+	}
+	return s.evaluate(ctx, repo, active)
+}
+
+// evaluate avalia cada definição no contexto da empresa dona e grava só os status que mudaram
+func (s *SLAService) evaluate(ctx context.Context, repo repository.SLARepository, definitions []models.Definition) (int, error) {
+	now := s.now()
+	updated := 0
+	for _, definition := range definitions {
+		companyCtx := tenant.WithCompany(ctx, definition.CompanyID)
+		if definition.Target == models.TargetProcessCompletion {
+			if err := repo.ClearCancelledProcesses(companyCtx); err != nil {
+				return updated, err
+			}
+		}
+		subjects, err := repo.Subjects(companyCtx, definition.Target)
+		if err != nil {
+			return updated, err
+		}
+		schedule, err := repo.Schedule(companyCtx)
+		if err != nil {
+			return updated, err
+		}
+		evaluations := models.EvaluateSubjects(definition, schedule, subjects, now)
+		if err := repo.SaveEvaluations(companyCtx, definition.Target, evaluations); err != nil {
+			return updated, err
+		}
+		updated += len(evaluations)
+	}
+	return updated, nil
+}
+
+// Compliance apura o cumprimento por definição no período (padrão: mês corrente)
+func (s *SLAService) Compliance(ctx context.Context, from, to time.Time) (*models.ComplianceReport, error) {
+	now := s.now()
+	if to.IsZero() {
+		to = time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location())
+	}
+	if from.IsZero() {
+		from = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location())
+	}
+	if to.Before(from) {
+		return nil, errors.ErrInvalidDateRange
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := repo.ListDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := repo.StatusCounts(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ComplianceReport{
+		From:  from.Format(dateLayout),
+		To:    to.Format(dateLayout),
+		Lines: models.BuildCompliance(definitions, counts),
+	}, nil
+}
+
+func applyInput(definition *models.Definition, input models.DefinitionInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: informe o nome", errors.ErrInvalidSLADefinition)
+	}
+	if input.Duration <= 0 {
+		return fmt.Errorf("%w: o prazo deve ser positivo", errors.ErrInvalidSLADefinition)
+	}
+	switch input.Target {
+	case models.TargetDeliveryShipment, models.TargetProcessCompletion:
+	default:
+		return fmt.Errorf("%w: alvo %q desconhecido", errors.ErrInvalidSLADefinition, input.Target)
+	}
+	switch input.Unit {
+	case models.UnitBusinessDays, models.UnitDays, models.UnitHours:
+	default:
+		return fmt.Errorf("%w: unidade %q desconhecida", errors.ErrInvalidSLADefinition, input.Unit)
+	}
+
+	definition.Name = name
+	definition.Target = input.Target
+	definition.Duration = input.Duration
+	definition.Unit = input.Unit
+	if input.Active != nil {
+		definition.Active = *input.Active
+	}
+	return nil
+}
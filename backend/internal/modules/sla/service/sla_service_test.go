@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	calendar "ERP-ONSMART/backend/internal/modules/calendar/models"
+	"ERP-ONSMART/backend/internal/modules/sla/models"
+	"ERP-ONSMART/backend/internal/modules/sla/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type savedEvaluations struct {
+	companyID   int
+	target      string
+	evaluations []models.Evaluation
+}
+
+type fakeSLARepo struct {
+	definitions []models.Definition
+	subjects    map[string][]models.Subject
+	counts      []models.StatusCount
+	saved       []savedEvaluations
+	cleared     int
+	countRange  [2]time.Time
+}
+
+func (r *fakeSLARepo) ListDefinitions(ctx context.Context) ([]models.Definition, error) {
+	return r.definitions, nil
+}
+
+func (r *fakeSLARepo) GetDefinition(ctx context.Context, id int) (*models.Definition, error) {
+	for i := range r.definitions {
+		if r.definitions[i].ID == id {
+			definition := r.definitions[i]
+			return &definition, nil
+		}
+	}
+	return nil, appErrors.ErrSLADefinitionNotFound
+}
+
+func (r *fakeSLARepo) CreateDefinition(ctx context.Context, definition *models.Definition) error {
+	definition.ID = len(r.definitions) + 1
+	r.definitions = append(r.definitions, *definition)
+	return nil
+}
+
+func (r *fakeSLARepo) UpdateDefinition(ctx context.Context, definition *models.Definition) error {
+	return nil
+}
+
+func (r *fakeSLARepo) DeleteDefinition(ctx context.Context, id int) error {
+	return nil
+}
+
+func (r *fakeSLARepo) ActiveDefinitions(ctx context.Context) ([]models.Definition, error) {
+	active := []models.Definition{}
+	for _, definition := range r.definitions {
+		if definition.Active {
+			active = append(active, definition)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeSLARepo) Subjects(ctx context.Context, target string) ([]models.Subject, error) {
+	return r.subjects[target], nil
+}
+
+func (r *fakeSLARepo) SaveEvaluations(ctx context.Context, target string, evaluations []models.Evaluation) error {
+	companyID, _ := tenant.CompanyID(ctx)
+	r.saved = append(r.saved, savedEvaluations{companyID: companyID, target: target, evaluations: evaluations})
+	return nil
+}
+
+func (r *fakeSLARepo) ClearCancelledProcesses(ctx context.Context) error {
+	r.cleared++
+	return nil
+}
+
+func (r *fakeSLARepo) StatusCounts(ctx context.Context, from, to time.Time) ([]models.StatusCount, error) {
+	r.countRange = [2]time.Time{from, to}
+	return r.counts, nil
+}
+
+func (r *fakeSLARepo) Schedule(ctx context.Context) (*calendar.Schedule, error) {
+	return calendar.DefaultSchedule(), nil
+}
+
+func newTestService(repo *fakeSLARepo, now time.Time) *SLAService {
+	s := NewSLAService(func() (repository.SLARepository, error) { return repo, nil })
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestRunEvaluationPerCompany(t *testing.T) {
+	start := time.Date(2024, 5, 20, 10, 0, 0, 0, time.UTC)
+	repo := &fakeSLARepo{
+		definitions: []models.Definition{
+			{ID: 1, CompanyID: 1, Target: models.TargetDeliveryShipment, Duration: 2, Unit: models.UnitBusinessDays, Active: true},
+			{ID: 2, CompanyID: 2, Target: models.TargetProcessCompletion, Duration: 30, Unit: models.UnitDays, Active: true},
+			{ID: 3, CompanyID: 3, Target: models.TargetDeliveryShipment, Duration: 1, Unit: models.UnitDays, Active: false},
+		},
+		subjects: map[string][]models.Subject{
+			models.TargetDeliveryShipment:  {{ID: 10, StartedAt: start}},
+			models.TargetProcessCompletion: {{ID: 20, StartedAt: start}, {ID: 21, StartedAt: start.AddDate(0, -2, 0)}},
+		},
+	}
+	s := newTestService(repo, time.Date(2024, 5, 23, 12, 0, 0, 0, time.UTC))
+
+	updated, err := s.RunEvaluation(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated)
+	assert.Equal(t, 1, repo.cleared)
+
+	require.Len(t, repo.saved, 2)
+	assert.Equal(t, 1, repo.saved[0].companyID)
+	assert.Equal(t, models.StatusBreached, repo.saved[0].evaluations[0].Status)
+	assert.Equal(t, 2, repo.saved[1].companyID)
+	assert.Equal(t, models.StatusOnTrack, repo.saved[1].evaluations[0].Status)
+	assert.Equal(t, models.StatusBreached, repo.saved[1].evaluations[1].Status)
+}
+
+func TestCreateDefinitionValidatesInput(t *testing.T) {
+	repo := &fakeSLARepo{}
+	s := newTestService(repo, time.Now())
+	ctx := context.Background()
+
+	definition, err := s.CreateDefinition(ctx, models.DefinitionInput{
+		Name: " Envio em 2 dias úteis ", Target: models.TargetDeliveryShipment, Duration: 2, Unit: models.UnitBusinessDays,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Envio em 2 dias úteis", definition.Name)
+	assert.True(t, definition.Active)
+
+	_, err = s.CreateDefinition(ctx, models.DefinitionInput{
+		Name: "Inválida", Target: "invoice_payment", Duration: 2, Unit: models.UnitDays,
+	})
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidSLADefinition))
+
+	inactive := false
+	definition, err = s.UpdateDefinition(ctx, 1, models.DefinitionInput{
+		Name: "Envio em 3 dias úteis", Target: models.TargetDeliveryShipment, Duration: 3, Unit: models.UnitBusinessDays, Active: &inactive,
+	})
+	require.NoError(t, err)
+	assert.False(t, definition.Active)
+	assert.Equal(t, 3, definition.Duration)
+}
+
+func TestComplianceDefaultsToCurrentMonth(t *testing.T) {
+	repo := &fakeSLARepo{
+		definitions: []models.Definition{{Name: "Envio", Target: models.TargetDeliveryShipment, Duration: 2, Unit: models.UnitBusinessDays}},
+		counts: []models.StatusCount{
+			{Target: models.TargetDeliveryShipment, Status: models.StatusMet, Count: 9},
+			{Target: models.TargetDeliveryShipment, Status: models.StatusBreached, Count: 1},
+		},
+	}
+	s := newTestService(repo, time.Date(2024, 2, 10, 15, 0, 0, 0, time.UTC))
+
+	report, err := s.Compliance(context.Background(), time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-01", report.From)
+	assert.Equal(t, "2024-02-29", report.To)
+	assert.Equal(t, 90.0, report.Lines[0].Compliance)
+
+	_, err = s.Compliance(context.Background(), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, appErrors.ErrInvalidDateRange, err)
+}
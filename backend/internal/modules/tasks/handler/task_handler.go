@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/tasks/models"
+	"ERP-ONSMART/backend/internal/modules/tasks/service"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista as tarefas da empresa pelo vencimento
+// @Security BearerAuth
+// @Param status query string false "Status (open, done, cancelled)"
+// @Param assigned_to query int false "ID do responsável"
+// @Param unassigned query bool false "Somente as tarefas sem responsável"
+// @Param entity_type query string false "Tipo do registro ligado (invoice, sales_process, expense...)"
+// @Param entity_id query int false "ID do registro ligado"
+// @Param source query string false "Origem (manual, followup, approval, dunning)"
+// @Param overdue query bool false "Somente as tarefas abertas atrasadas"
+func ListTasksHandler(c *gin.Context) {
+	filter := models.TaskFilter{
+		Status:     c.Query("status"),
+		EntityType: c.Query("entity_type"),
+		Source:     c.Query("source"),
+	}
+	var ok bool
+	if filter.AssignedTo, ok = parseOptionalIntQuery(c, "assigned_to"); !ok {
+		return
+	}
+	if filter.EntityID, ok = parseOptionalIntQuery(c, "entity_id"); !ok {
+		return
+	}
+	filter.Unassigned, _ = strconv.ParseBool(c.Query("unassigned"))
+	if overdue, _ := strconv.ParseBool(c.Query("overdue")); overdue {
+		today := localtime.Today(c.Request.Context())
+		filter.DueBefore = &today
+	}
+
+	tasks, err := service.ListTasks(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar tarefas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// Minhas tarefas: as abertas do usuário logado, separadas em atrasadas, do dia, próximas e sem
+// prazo
+// @Security BearerAuth
+func GetMyTasksHandler(c *gin.Context) {
+	mine, err := service.GetMyTasks(c.Request.Context(), middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar minhas tarefas")
+		return
+	}
+
+	c.JSON(http.StatusOK, mine)
+}
+
+// Busca uma tarefa
+// @Security BearerAuth
+// @Param id path int true "ID da tarefa"
+func GetTaskHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	task, err := service.GetTask(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar tarefa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task": task})
+}
+
+// Cria uma tarefa com título, responsável, prazo e, opcionalmente, o registro do ERP ligado
+// (entity_type e entity_id)
+// @Security BearerAuth
+func CreateTaskHandler(c *gin.Context) {
+	var input models.TaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	task, err := service.CreateTask(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao criar tarefa")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"task": task})
+}
+
+// Altera uma tarefa aberta (título, descrição, responsável, prazo e registro ligado)
+// @Security BearerAuth
+// @Param id path int true "ID da tarefa"
+func UpdateTaskHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.TaskInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	task, err := service.UpdateTask(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atualizar tarefa")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task": task})
+}
+
+// Conclui uma tarefa aberta com o resultado
+// @Security BearerAuth
+// @Param id path int true "ID da tarefa"
+func CompleteTaskHandler(c *gin.Context) {
+	closeTask(c, service.CompleteTask, "erro ao concluir tarefa")
+}
+
+// Cancela uma tarefa aberta com o motivo
+// @Security BearerAuth
+// @Param id path int true "ID da tarefa"
+func CancelTaskHandler(c *gin.Context) {
+	closeTask(c, service.CancelTask, "erro ao cancelar tarefa")
+}
+
+// closeTask encerra a tarefa; o corpo com as observações é opcional
+func closeTask(c *gin.Context, closeFn func(ctx context.Context, id int, input models.CompleteInput, by string) (*models.Task, error), message string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.CompleteInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	task, err := closeFn(c.Request.Context(), id, input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta(message)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task": task})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
+
+func parseOptionalIntQuery(c *gin.Context, name string) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		c.Error(errors.InvalidParam(name + " inválido"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,220 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status das tarefas
+const (
+	StatusOpen      = "open"
+	StatusDone      = "done"
+	StatusCancelled = "cancelled"
+)
+
+// Origens das tarefas: as manuais são criadas pelos usuários; as demais, pelas automações
+const (
+	SourceManual   = "manual"
+	SourceFollowup = "followup"
+	SourceApproval = "approval"
+	SourceDunning  = "dunning"
+)
+
+// entityTables relaciona os tipos de registro aos quais uma tarefa pode ser ligada às suas tabelas
+var entityTables = map[string]string{
+	"contact":        "contacts",
+	"lead":           "crm_leads",
+	"opportunity":    "crm_opportunities",
+	"quotation":      "quotations",
+	"sales_order":    "sales_orders",
+	"sales_process":  "sales_processes",
+	"purchase_order": "purchase_orders",
+	"invoice":        "invoices",
+	"supplier_bill":  "supplier_bills",
+	"service_order":  "service_orders",
+	"contract":       "contracts",
+	"expense":        "expenses",
+}
+
+// Task é um item de trabalho com responsável e prazo, opcionalmente ligado a um registro do ERP.
+// SourceKey identifica a origem das tarefas das automações: há no máximo uma tarefa aberta por
+// chave, e a automação a conclui quando o motivo deixa de existir.
+type Task struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	CompanyID   int        `json:"company_id" gorm:"<-:create"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	AssignedTo  *int       `json:"assigned_to,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty" gorm:"type:date"`
+	Status      string     `json:"status"`
+	EntityType  string     `json:"entity_type,omitempty"`
+	EntityID    *int       `json:"entity_id,omitempty"`
+	Source      string     `json:"source"`
+	SourceKey   *string    `json:"source_key,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CompletedBy string     `json:"completed_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Task) TableName() string {
+	return "tasks"
+}
+
+// TaskInput é o corpo aceito na criação e alteração das tarefas manuais
+type TaskInput struct {
+	Title       string     `json:"title" binding:"required,max=255"`
+	Description string     `json:"description"`
+	AssignedTo  *int       `json:"assigned_to"`
+	DueDate     *time.Time `json:"due_date"`
+	EntityType  string     `json:"entity_type"`
+	EntityID    *int       `json:"entity_id"`
+}
+
+// Validate confere o título e o registro ligado à tarefa
+func (in TaskInput) Validate() error {
+	if strings.TrimSpace(in.Title) == "" {
+		return fmt.Errorf("%w: informe o título da tarefa", errors.ErrInvalidTask)
+	}
+	if (in.EntityType == "") != (in.EntityID == nil) {
+		return fmt.Errorf("%w: informe o tipo e o ID do registro ligado à tarefa", errors.ErrInvalidTask)
+	}
+	if in.EntityType != "" {
+		if _, ok := EntityTable(in.EntityType); !ok {
+			return fmt.Errorf("%w: tipo de registro %q não aceita tarefas", errors.ErrInvalidTask, in.EntityType)
+		}
+	}
+	return nil
+}
+
+// Apply copia os campos da entrada para a tarefa, com o vencimento só com a data
+func (in TaskInput) Apply(task *Task) {
+	task.Title = strings.TrimSpace(in.Title)
+	task.Description = strings.TrimSpace(in.Description)
+	task.AssignedTo = in.AssignedTo
+	task.DueDate = nil
+	if in.DueDate != nil {
+		due := DateOf(*in.DueDate)
+		task.DueDate = &due
+	}
+	task.EntityType = in.EntityType
+	task.EntityID = in.EntityID
+}
+
+// TaskFilter filtra as tarefas; campos vazios não filtram
+type TaskFilter struct {
+	Status     string
+	AssignedTo int
+	Unassigned bool
+	EntityType string
+	EntityID   int
+	Source     string
+	// DueBefore traz só as tarefas abertas vencidas antes da data (as atrasadas)
+	DueBefore *time.Time
+}
+
+// CompleteInput conclui a tarefa com o resultado
+type CompleteInput struct {
+	Notes string `json:"notes"`
+}
+
+// MyTasks é a visão das tarefas abertas do usuário pelo prazo
+type MyTasks struct {
+	Overdue   []Task `json:"overdue"`
+	Today     []Task `json:"today"`
+	Upcoming  []Task `json:"upcoming"`
+	NoDueDate []Task `json:"no_due_date"`
+}
+
+// GroupMine separa as tarefas abertas em atrasadas, do dia, próximas e sem prazo, cada grupo pelo
+// vencimento
+func GroupMine(tasks []Task, today time.Time) MyTasks {
+	today = DateOf(today)
+	mine := MyTasks{Overdue: []Task{}, Today: []Task{}, Upcoming: []Task{}, NoDueDate: []Task{}}
+	sorted := append([]Task(nil), tasks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].DueDate == nil || sorted[j].DueDate == nil {
+			return sorted[j].DueDate == nil && sorted[i].DueDate != nil
+		}
+		return sorted[i].DueDate.Before(*sorted[j].DueDate)
+	})
+	for _, task := range sorted {
+		if task.Status != StatusOpen {
+			continue
+		}
+		switch {
+		case task.DueDate == nil:
+			mine.NoDueDate = append(mine.NoDueDate, task)
+		case DateOf(*task.DueDate).Before(today):
+			mine.Overdue = append(mine.Overdue, task)
+		case DateOf(*task.DueDate).Equal(today):
+			mine.Today = append(mine.Today, task)
+		default:
+			mine.Upcoming = append(mine.Upcoming, task)
+		}
+	}
+	return mine
+}
+
+// EntityTable retorna a tabela do tipo de registro e se ele aceita tarefas
+func EntityTable(entityType string) (string, bool) {
+	table, ok := entityTables[entityType]
+	return table, ok
+}
+
+// ApprovalKey é a chave da tarefa de aprovação de um registro (a despesa enviada, a conta com
+// divergências na conferência)
+func ApprovalKey(entityType string, id int) string {
+	return fmt.Sprintf("approval:%s:%d", entityType, id)
+}
+
+// DunningKey é a chave da tarefa de cobrança da fatura vencida
+func DunningKey(invoiceID int) string {
+	return fmt.Sprintf("dunning:invoice:%d", invoiceID)
+}
+
+// OverdueInvoice é uma fatura vencida em aberto, com o vendedor responsável pela cobrança
+type OverdueInvoice struct {
+	InvoiceID     int           `json:"invoice_id"`
+	CompanyID     int           `json:"company_id"`
+	InvoiceNo     string        `json:"invoice_no"`
+	ContactID     int           `json:"contact_id"`
+	ContactName   string        `json:"contact_name"`
+	ContactPhone  string        `json:"contact_phone"`
+	DueDate       time.Time     `json:"due_date"`
+	Balance       money.Decimal `json:"balance"`
+	SalespersonID *int          `json:"salesperson_id,omitempty"`
+}
+
+// DunningTask monta a tarefa de ligação de cobrança da fatura vencida, com vencimento no dia
+func DunningTask(invoice OverdueInvoice, today time.Time) Task {
+	id := invoice.InvoiceID
+	key := DunningKey(id)
+	due := DateOf(today)
+	description := fmt.Sprintf("Fatura vencida em %s com saldo de R$ %s.", invoice.DueDate.Format("02/01/2006"), invoice.Balance.String())
+	if invoice.ContactPhone != "" {
+		description += " Telefone: " + invoice.ContactPhone + "."
+	}
+	return Task{
+		Title:       fmt.Sprintf("Ligar para %s: cobrança da fatura %s", invoice.ContactName, invoice.InvoiceNo),
+		Description: description,
+		AssignedTo:  invoice.SalespersonID,
+		DueDate:     &due,
+		Status:      StatusOpen,
+		EntityType:  "invoice",
+		EntityID:    &id,
+		Source:      SourceDunning,
+		SourceKey:   &key,
+	}
+}
+
+// DateOf retorna a data (meia-noite em UTC) do instante, no fuso do próprio instante
+func DateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(day int) *time.Time {
+	d := time.Date(2026, 10, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+func TestTaskInputValidate(t *testing.T) {
+	id := 3
+	assert.NoError(t, TaskInput{Title: "Ligar para o cliente"}.Validate())
+	assert.NoError(t, TaskInput{Title: "Revisar pedido", EntityType: "sales_order", EntityID: &id}.Validate())
+
+	cases := map[string]TaskInput{
+		"sem título":    {Title: "  "},
+		"sem ID":        {Title: "Tarefa", EntityType: "invoice"},
+		"sem tipo":      {Title: "Tarefa", EntityID: &id},
+		"tipo inválido": {Title: "Tarefa", EntityType: "users", EntityID: &id},
+	}
+	for name, input := range cases {
+		assert.True(t, stderrors.Is(input.Validate(), errors.ErrInvalidTask), name)
+	}
+}
+
+func TestTaskInputApplyKeepsOnlyTheDueDate(t *testing.T) {
+	due := time.Date(2026, 10, 20, 18, 30, 0, 0, time.FixedZone("BRT", -3*3600))
+	var task Task
+	TaskInput{Title: " Enviar proposta ", DueDate: &due}.Apply(&task)
+
+	assert.Equal(t, "Enviar proposta", task.Title)
+	assert.Equal(t, *date(20), *task.DueDate)
+}
+
+func TestGroupMine(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Status: StatusOpen, DueDate: date(20)},
+		{ID: 2, Status: StatusOpen},
+		{ID: 3, Status: StatusOpen, DueDate: date(16)},
+		{ID: 4, Status: StatusOpen, DueDate: date(12)},
+		{ID: 5, Status: StatusDone, DueDate: date(10)},
+		{ID: 6, Status: StatusOpen, DueDate: date(18)},
+	}
+	// 23:00 do dia 16: o dia ainda é 16
+	mine := GroupMine(tasks, time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC))
+
+	ids := func(tasks []Task) []int {
+		out := []int{}
+		for _, task := range tasks {
+			out = append(out, task.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []int{4}, ids(mine.Overdue))
+	assert.Equal(t, []int{3}, ids(mine.Today))
+	assert.Equal(t, []int{6, 1}, ids(mine.Upcoming))
+	assert.Equal(t, []int{2}, ids(mine.NoDueDate))
+}
+
+func TestDunningTask(t *testing.T) {
+	salesperson := 7
+	invoice := OverdueInvoice{
+		InvoiceID:     42,
+		InvoiceNo:     "FAT-0042",
+		ContactName:   "Cliente A",
+		ContactPhone:  "(11) 3333-4444",
+		DueDate:       *date(10),
+		Balance:       money.FromInt(150),
+		SalespersonID: &salesperson,
+	}
+
+	task := DunningTask(invoice, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	assert.Equal(t, "Ligar para Cliente A: cobrança da fatura FAT-0042", task.Title)
+	assert.Equal(t, "Fatura vencida em 10/10/2026 com saldo de R$ 150.00. Telefone: (11) 3333-4444.", task.Description)
+	assert.Equal(t, *date(16), *task.DueDate)
+	assert.Equal(t, &salesperson, task.AssignedTo)
+	assert.Equal(t, SourceDunning, task.Source)
+	require.NotNil(t, task.SourceKey)
+	assert.Equal(t, "dunning:invoice:42", *task.SourceKey)
+	assert.Equal(t, "invoice", task.EntityType)
+	assert.Equal(t, 42, *task.EntityID)
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/tasks/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskRepository mantém as tarefas e lê as faturas vencidas para a cobrança
+type TaskRepository interface {
+	ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	GetTask(ctx context.Context, id int) (*models.Task, error)
+	CreateTask(ctx context.Context, task *models.Task) error
+	UpdateTask(ctx context.Context, task *models.Task) error
+	CloseTask(ctx context.Context, id int, status, notes, closedBy string, now time.Time) (*models.Task, error)
+	EntityExists(ctx context.Context, entityType string, id int) error
+	UserExists(ctx context.Context, id int) error
+	FindUserID(ctx context.Context, username string) (int, error)
+
+	OpenAutomatic(ctx context.Context, task *models.Task) (bool, error)
+	CloseAutomatic(ctx context.Context, sourceKey, closedBy string, now time.Time) (int64, error)
+	OverdueInvoices(ctx context.Context, dueBefore time.Time) ([]models.OverdueInvoice, error)
+	CloseSettledDunning(ctx context.Context, now time.Time) (int64, error)
+}
+
+type taskRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTaskRepository cria uma nova instância do repositório
+func NewTaskRepository() (TaskRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &taskRepository{
+		db:     db,
+		logger: logger.WithModule("task_repository"),
+	}, nil
+}
+
+// ListTasks lista as tarefas conforme o filtro, pelo vencimento (sem prazo por último)
+func (r *taskRepository) ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	query := db.Conn(ctx, r.db).Model(&models.Task{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.AssignedTo > 0 {
+		query = query.Where("assigned_to = ?", filter.AssignedTo)
+	}
+	if filter.Unassigned {
+		query = query.Where("assigned_to IS NULL")
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID > 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("status = ? AND due_date < ?", models.StatusOpen, *filter.DueBefore)
+	}
+
+	var tasks []models.Task
+	if err := query.Order("due_date ASC NULLS LAST, id ASC").Find(&tasks).Error; err != nil {
+		r.logger.Error("erro ao listar tarefas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar tarefas")
+	}
+	return tasks, nil
+}
+
+// GetTask busca uma tarefa
+func (r *taskRepository) GetTask(ctx context.Context, id int) (*models.Task, error) {
+	var task models.Task
+	if err := db.Conn(ctx, r.db).First(&task, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrTaskNotFound
+		}
+		return nil, errors.WrapError(err, "falha ao buscar tarefa")
+	}
+	return &task, nil
+}
+
+// CreateTask grava uma tarefa
+func (r *taskRepository) CreateTask(ctx context.Context, task *models.Task) error {
+	if err := db.Conn(ctx, r.db).Create(task).Error; err != nil {
+		r.logger.Error("erro ao criar tarefa", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar tarefa")
+	}
+	return nil
+}
+
+// UpdateTask altera a tarefa
+func (r *taskRepository) UpdateTask(ctx context.Context, task *models.Task) error {
+	if err := db.Conn(ctx, r.db).Save(task).Error; err != nil {
+		r.logger.Error("erro ao alterar tarefa", zap.Error(err), zap.Int("id", task.ID))
+		return errors.WrapError(err, "falha ao alterar tarefa")
+	}
+	return nil
+}
+
+// CloseTask conclui ou cancela a tarefa em aberto
+func (r *taskRepository) CloseTask(ctx context.Context, id int, status, notes, closedBy string, now time.Time) (*models.Task, error) {
+	var task models.Task
+	err := db.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrTaskNotFound
+			}
+			return errors.WrapError(err, "falha ao buscar tarefa")
+		}
+		if task.Status != models.StatusOpen {
+			return errors.ErrTaskNotOpen
+		}
+		task.Status = status
+		if notes != "" {
+			task.Notes = notes
+		}
+		task.CompletedAt = &now
+		task.CompletedBy = closedBy
+		if err := tx.Save(&task).Error; err != nil {
+			r.logger.Error("erro ao encerrar tarefa", zap.Error(err), zap.Int("id", id))
+			return errors.WrapError(err, "falha ao encerrar tarefa")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// EntityExists verifica se o registro ligado à tarefa existe na empresa
+func (r *taskRepository) EntityExists(ctx context.Context, entityType string, id int) error {
+	table, ok := models.EntityTable(entityType)
+	if !ok {
+		return errors.ErrInvalidTask
+	}
+	var count int64
+	err := db.Conn(ctx, r.db).Table(table).Scopes(tenant.Scope(ctx, table)).Where("id = ?", id).Count(&count).Error
+	if err != nil {
+		r.logger.Error("erro ao verificar registro da tarefa", zap.Error(err), zap.String("entity_type", entityType), zap.Int("entity_id", id))
+		return errors.WrapError(err, "falha ao verificar registro da tarefa")
+	}
+	if count == 0 {
+		return errors.ErrEntityNotFound
+	}
+	return nil
+}
+
+// UserExists verifica se o responsável é um usuário ativo da empresa
+func (r *taskRepository) UserExists(ctx context.Context, id int) error {
+	var count int64
+	err := db.Conn(ctx, r.db).Table("users").Scopes(tenant.Scope(ctx, "users")).
+		Where("id = ? AND active", id).Count(&count).Error
+	if err != nil {
+		return errors.WrapError(err, "falha ao buscar usuário")
+	}
+	if count == 0 {
+		return errors.ErrUserNotFound
+	}
+	return nil
+}
+
+// FindUserID busca o ID do usuário da empresa pelo login
+func (r *taskRepository) FindUserID(ctx context.Context, username string) (int, error) {
+	var ids []int
+	err := db.Conn(ctx, r.db).Table("users").Scopes(tenant.Scope(ctx, "users")).
+		Where("username = ?", username).Limit(1).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, errors.WrapError(err, "falha ao buscar usuário")
+	}
+	if len(ids) == 0 {
+		return 0, errors.ErrUserNotFound
+	}
+	return ids[0], nil
+}
+
+// OpenAutomatic grava a tarefa de uma automação se não houver outra aberta com a mesma chave
+// (índice único parcial); retorna se a tarefa foi criada
+func (r *taskRepository) OpenAutomatic(ctx context.Context, task *models.Task) (bool, error) {
+	result := db.Conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(task)
+	if result.Error != nil {
+		r.logger.Error("erro ao criar tarefa automática", zap.Error(result.Error), zap.String("source", task.Source))
+		return false, errors.WrapError(result.Error, "falha ao criar tarefa automática")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CloseAutomatic conclui a tarefa aberta da chave, quando o motivo da automação deixa de existir
+func (r *taskRepository) CloseAutomatic(ctx context.Context, sourceKey, closedBy string, now time.Time) (int64, error) {
+	result := db.Conn(ctx, r.db).Model(&models.Task{}).
+		Where("source_key = ? AND status = ?", sourceKey, models.StatusOpen).
+		Updates(map[string]interface{}{
+			"status":       models.StatusDone,
+			"completed_at": now,
+			"completed_by": closedBy,
+		})
+	if result.Error != nil {
+		r.logger.Error("erro ao concluir tarefa automática", zap.Error(result.Error), zap.String("source_key", sourceKey))
+		return 0, errors.WrapError(result.Error, "falha ao concluir tarefa automática")
+	}
+	return result.RowsAffected, nil
+}
+
+// OverdueInvoices busca as faturas com saldo vencidas antes de dueBefore, com o telefone do
+// cliente e o vendedor. As faturas com contestação aberta ficam suspensas da cobrança, e o valor
+// baixado nas contestações resolvidas sai do saldo. O contexto deve vir de tenant.AllCompanies:
+// a cobrança atende todas as empresas.
+func (r *taskRepository) OverdueInvoices(ctx context.Context, dueBefore time.Time) ([]models.OverdueInvoice, error) {
+	var invoices []models.OverdueInvoice
+	balance := "i.grand_total - i.amount_paid - " + sales.DisputedAmountSQL("i")
+	err := db.Conn(ctx, r.db).Table("invoices i").
+		Select(`i.id AS invoice_id, i.company_id, i.invoice_no, i.contact_id, c.name AS contact_name,
+			COALESCE(c.phone, '') AS contact_phone, i.due_date, `+balance+` AS balance, i.salesperson_id`).
+		Joins("JOIN contacts c ON c.id = i.contact_id").
+		Scopes(tenant.Scope(ctx, "i")).
+		Where("i.deleted_at IS NULL AND i.status IN ? AND i.due_date < ? AND "+balance+" > 0 AND NOT "+sales.OpenDisputeSQL("i"),
+			[]string{sales.InvoiceStatusSent, sales.InvoiceStatusPartial, sales.InvoiceStatusOverdue}, dueBefore).
+		Order("i.company_id ASC, i.due_date ASC, i.id ASC").
+		Scan(&invoices).Error
+	if err != nil {
+		r.logger.Error("erro ao buscar faturas vencidas", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar faturas vencidas")
+	}
+	return invoices, nil
+}
+
+// CloseSettledDunning conclui as tarefas de cobrança abertas das faturas pagas, canceladas,
+// removidas ou suspensas por contestação. O contexto deve vir de tenant.AllCompanies.
+func (r *taskRepository) CloseSettledDunning(ctx context.Context, now time.Time) (int64, error) {
+	result := db.Conn(ctx, r.db).Model(&models.Task{}).
+		Where("source = ? AND status = ?", models.SourceDunning, models.StatusOpen).
+		Where(`NOT EXISTS (SELECT 1 FROM invoices i WHERE i.id = tasks.entity_id AND i.deleted_at IS NULL
+			AND i.status NOT IN ? AND i.grand_total - i.amount_paid > `+sales.DisputedAmountSQL("i")+`
+			AND NOT `+sales.OpenDisputeSQL("i")+`)`,
+			[]string{sales.InvoiceStatusPaid, sales.InvoiceStatusCancelled}).
+		Updates(map[string]interface{}{
+			"status":       models.StatusDone,
+			"completed_at": now,
+			"completed_by": "cobrança automática",
+		})
+	if result.Error != nil {
+		r.logger.Error("erro ao concluir tarefas de cobrança", zap.Error(result.Error))
+		return 0, errors.WrapError(result.Error, "falha ao concluir tarefas de cobrança")
+	}
+	return result.RowsAffected, nil
+}
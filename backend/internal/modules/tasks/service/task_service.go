@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/localtime"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/modules/tasks/models"
+	"ERP-ONSMART/backend/internal/modules/tasks/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// TaskService mantém as tarefas dos usuários, manuais ou criadas pelas automações (acompanhamento
+// dos processos parados, aprovações pendentes e cobrança das faturas vencidas)
+type TaskService struct {
+	newRepo     func() (repository.TaskRepository, error)
+	now         func() time.Time
+	location    func(ctx context.Context) *time.Location
+	dunningDays func() int
+	logger      *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.TaskRepository
+}
+
+// NewTaskService cria o serviço sobre o repositório informado
+func NewTaskService(newRepo func() (repository.TaskRepository, error)) *TaskService {
+	return &TaskService{
+		newRepo:     newRepo,
+		now:         time.Now,
+		location:    localtime.Location,
+		dunningDays: func() int { return viper.GetInt("TASKS_DUNNING_DAYS") },
+		logger:      logger.WithModule("task_service"),
+	}
+}
+
+var defaultService = NewTaskService(repository.NewTaskRepository)
+
+// ListTasks lista as tarefas da empresa
+func ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	return defaultService.ListTasks(ctx, filter)
+}
+
+// GetMyTasks retorna as tarefas abertas do usuário separadas pelo prazo
+func GetMyTasks(ctx context.Context, username string) (*models.MyTasks, error) {
+	return defaultService.Mine(ctx, username)
+}
+
+// GetTask busca uma tarefa
+func GetTask(ctx context.Context, id int) (*models.Task, error) {
+	return defaultService.GetTask(ctx, id)
+}
+
+// CreateTask valida e grava uma tarefa manual
+func CreateTask(ctx context.Context, input models.TaskInput, createdBy string) (*models.Task, error) {
+	return defaultService.CreateTask(ctx, input, createdBy)
+}
+
+// UpdateTask valida e altera uma tarefa aberta
+func UpdateTask(ctx context.Context, id int, input models.TaskInput) (*models.Task, error) {
+	return defaultService.UpdateTask(ctx, id, input)
+}
+
+// CompleteTask conclui uma tarefa
+func CompleteTask(ctx context.Context, id int, input models.CompleteInput, completedBy string) (*models.Task, error) {
+	return defaultService.CloseTask(ctx, id, models.StatusDone, input.Notes, completedBy)
+}
+
+// CancelTask cancela uma tarefa
+func CancelTask(ctx context.Context, id int, input models.CompleteInput, cancelledBy string) (*models.Task, error) {
+	return defaultService.CloseTask(ctx, id, models.StatusCancelled, input.Notes, cancelledBy)
+}
+
+// Open cria a tarefa de uma automação na empresa do contexto, se não houver outra aberta com a
+// mesma chave
+func Open(ctx context.Context, task models.Task) error {
+	return defaultService.Open(ctx, task)
+}
+
+// Close conclui a tarefa aberta da automação com a chave informada
+func Close(ctx context.Context, sourceKey, closedBy string) error {
+	return defaultService.Close(ctx, sourceKey, closedBy)
+}
+
+// StartTaskAutomationScheduler cria periodicamente as tarefas de cobrança das faturas vencidas de
+// todas as empresas e conclui as das faturas quitadas
+func StartTaskAutomationScheduler(ctx context.Context, interval time.Duration) {
+	log := logger.WithModule("task_automation_scheduler")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				created, closed, err := defaultService.RunDunning(ctx)
+				metrics.ObserveJob("task_automation_scheduler", err)
+				if err != nil {
+					log.Error("erro ao gerar tarefas de cobrança", zap.Error(err))
+					continue
+				}
+				if created+closed > 0 {
+					log.Info("tarefas de cobrança atualizadas", zap.Int("created", created), zap.Int("closed", closed))
+				}
+			}
+		}
+	}()
+}
+
+func (s *TaskService) repository() (repository.TaskRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListTasks lista as tarefas conforme o filtro
+func (s *TaskService) ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	switch filter.Status {
+	case "", models.StatusOpen, models.StatusDone, models.StatusCancelled:
+	default:
+		return nil, errors.InvalidParam("status deve ser open, done ou cancelled")
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListTasks(ctx, filter)
+}
+
+// Mine busca as tarefas abertas atribuídas ao usuário e separa pelo prazo, no dia da empresa
+func (s *TaskService) Mine(ctx context.Context, username string) (*models.MyTasks, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	userID, err := repo.FindUserID(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := repo.ListTasks(ctx, models.TaskFilter{Status: models.StatusOpen, AssignedTo: userID})
+	if err != nil {
+		return nil, err
+	}
+	mine := models.GroupMine(tasks, s.now().In(s.location(ctx)))
+	return &mine, nil
+}
+
+// GetTask busca uma tarefa
+func (s *TaskService) GetTask(ctx context.Context, id int) (*models.Task, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTask(ctx, id)
+}
+
+// CreateTask grava a tarefa manual aberta, conferindo o responsável e o registro ligado
+func (s *TaskService) CreateTask(ctx context.Context, input models.TaskInput, createdBy string) (*models.Task, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(ctx, repo, input); err != nil {
+		return nil, err
+	}
+	task := &models.Task{Status: models.StatusOpen, Source: models.SourceManual, CreatedBy: createdBy}
+	input.Apply(task)
+	if err := repo.CreateTask(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// UpdateTask altera a tarefa aberta; as das automações mantêm a origem e a chave
+func (s *TaskService) UpdateTask(ctx context.Context, id int, input models.TaskInput) (*models.Task, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	task, err := repo.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != models.StatusOpen {
+		return nil, errors.ErrTaskNotOpen
+	}
+	if err := s.validate(ctx, repo, input); err != nil {
+		return nil, err
+	}
+	input.Apply(task)
+	if err := repo.UpdateTask(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// CloseTask conclui ou cancela a tarefa aberta
+func (s *TaskService) CloseTask(ctx context.Context, id int, status, notes, closedBy string) (*models.Task, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CloseTask(ctx, id, status, strings.TrimSpace(notes), closedBy, s.now())
+}
+
+// Open grava a tarefa aberta da automação; com a chave de uma tarefa já aberta, não faz nada
+func (s *TaskService) Open(ctx context.Context, task models.Task) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	task.Status = models.StatusOpen
+	created, err := repo.OpenAutomatic(ctx, &task)
+	if err != nil {
+		return err
+	}
+	if created {
+		s.logger.Info("tarefa automática criada", zap.Int("task_id", task.ID), zap.String("source", task.Source),
+			zap.String("entity_type", task.EntityType))
+	}
+	return nil
+}
+
+// Close conclui a tarefa aberta da automação; sem tarefa aberta com a chave, não faz nada
+func (s *TaskService) Close(ctx context.Context, sourceKey, closedBy string) error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	_, err = repo.CloseAutomatic(ctx, sourceKey, closedBy, s.now())
+	return err
+}
+
+// RunDunning cria, para cada fatura com saldo vencida há mais de TASKS_DUNNING_DAYS dias, a tarefa
+// de ligação de cobrança para o vendedor, e conclui as tarefas das faturas já quitadas
+func (s *TaskService) RunDunning(ctx context.Context) (int, int, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return 0, 0, err
+	}
+	now := s.now()
+	all := tenant.AllCompanies(ctx)
+	closed, err := repo.CloseSettledDunning(all, now)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// o dia de cada empresa, no fuso dela, pode ser o anterior ou o seguinte ao dia em UTC
+	days := s.dunningDays()
+	dueBefore := localtime.Date(now.UTC()).AddDate(0, 0, 1-days)
+	invoices, err := repo.OverdueInvoices(all, dueBefore)
+	if err != nil {
+		return 0, int(closed), err
+	}
+
+	created := 0
+	for _, invoice := range invoices {
+		companyCtx := tenant.WithCompany(ctx, invoice.CompanyID)
+		today := models.DateOf(now.In(s.location(companyCtx)))
+		if !models.DateOf(invoice.DueDate).Before(today.AddDate(0, 0, -days)) {
+			continue
+		}
+		task := models.DunningTask(invoice, today)
+		ok, err := repo.OpenAutomatic(companyCtx, &task)
+		if err != nil {
+			return created, int(closed), err
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, int(closed), nil
+}
+
+// validate confere a entrada, o responsável e o registro ligado à tarefa
+func (s *TaskService) validate(ctx context.Context, repo repository.TaskRepository, input models.TaskInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	if input.AssignedTo != nil {
+		if err := repo.UserExists(ctx, *input.AssignedTo); err != nil {
+			return err
+		}
+	}
+	if input.EntityID != nil {
+		if err := repo.EntityExists(ctx, input.EntityType, *input.EntityID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
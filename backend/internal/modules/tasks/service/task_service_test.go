@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/tasks/models"
+	"ERP-ONSMART/backend/internal/modules/tasks/repository"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTaskRepo implementa só o que os testes usam; os demais métodos não são chamados
+type fakeTaskRepo struct {
+	repository.TaskRepository
+	tasks     []models.Task
+	users     map[string]int
+	entities  map[string]bool
+	invoices  []models.OverdueInvoice
+	dueBefore time.Time
+	companies []int
+}
+
+func (r *fakeTaskRepo) CreateTask(ctx context.Context, task *models.Task) error {
+	task.ID = len(r.tasks) + 1
+	r.tasks = append(r.tasks, *task)
+	return nil
+}
+
+func (r *fakeTaskRepo) ListTasks(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	for _, task := range r.tasks {
+		if task.Status == filter.Status && task.AssignedTo != nil && *task.AssignedTo == filter.AssignedTo {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (r *fakeTaskRepo) UserExists(ctx context.Context, id int) error {
+	for _, userID := range r.users {
+		if userID == id {
+			return nil
+		}
+	}
+	return appErrors.ErrUserNotFound
+}
+
+func (r *fakeTaskRepo) FindUserID(ctx context.Context, username string) (int, error) {
+	if id, ok := r.users[username]; ok {
+		return id, nil
+	}
+	return 0, appErrors.ErrUserNotFound
+}
+
+func (r *fakeTaskRepo) EntityExists(ctx context.Context, entityType string, id int) error {
+	if !r.entities[entityType] {
+		return appErrors.ErrEntityNotFound
+	}
+	return nil
+}
+
+func (r *fakeTaskRepo) OpenAutomatic(ctx context.Context, task *models.Task) (bool, error) {
+	for _, open := range r.tasks {
+		if open.Status == models.StatusOpen && open.SourceKey != nil && *open.SourceKey == *task.SourceKey {
+			return false, nil
+		}
+	}
+	companyID, _ := tenant.CompanyID(ctx)
+	r.companies = append(r.companies, companyID)
+	return true, r.CreateTask(ctx, task)
+}
+
+func (r *fakeTaskRepo) CloseSettledDunning(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeTaskRepo) OverdueInvoices(ctx context.Context, dueBefore time.Time) ([]models.OverdueInvoice, error) {
+	r.dueBefore = dueBefore
+	var invoices []models.OverdueInvoice
+	for _, invoice := range r.invoices {
+		if invoice.DueDate.Before(dueBefore) {
+			invoices = append(invoices, invoice)
+		}
+	}
+	return invoices, nil
+}
+
+var saoPaulo = time.FixedZone("BRT", -3*3600)
+
+func newTestService(repo *fakeTaskRepo, now time.Time) *TaskService {
+	s := NewTaskService(func() (repository.TaskRepository, error) { return repo, nil })
+	s.now = func() time.Time { return now }
+	s.location = func(ctx context.Context) *time.Location {
+		if companyID, _ := tenant.CompanyID(ctx); companyID == 2 {
+			return saoPaulo
+		}
+		return time.UTC
+	}
+	s.dunningDays = func() int { return 3 }
+	return s
+}
+
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCreateTaskChecksAssigneeAndEntity(t *testing.T) {
+	repo := &fakeTaskRepo{users: map[string]int{"ana": 7}, entities: map[string]bool{"invoice": true}}
+	s := newTestService(repo, day(16))
+	ctx := context.Background()
+
+	ana, other, invoiceID := 7, 9, 42
+	task, err := s.CreateTask(ctx, models.TaskInput{Title: "Ligar", AssignedTo: &ana, EntityType: "invoice", EntityID: &invoiceID}, "carlos")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusOpen, task.Status)
+	assert.Equal(t, models.SourceManual, task.Source)
+	assert.Equal(t, "carlos", task.CreatedBy)
+
+	_, err = s.CreateTask(ctx, models.TaskInput{Title: "Ligar", AssignedTo: &other}, "carlos")
+	assert.ErrorIs(t, err, appErrors.ErrUserNotFound)
+	_, err = s.CreateTask(ctx, models.TaskInput{Title: "Ligar", EntityType: "contract", EntityID: &invoiceID}, "carlos")
+	assert.ErrorIs(t, err, appErrors.ErrEntityNotFound)
+	_, err = s.CreateTask(ctx, models.TaskInput{Title: " "}, "carlos")
+	assert.ErrorIs(t, err, appErrors.ErrInvalidTask)
+	assert.Len(t, repo.tasks, 1)
+}
+
+func TestMineGroupsOpenTasksOfTheUser(t *testing.T) {
+	ana, bruno := 7, 8
+	overdue, today := day(14), day(16)
+	repo := &fakeTaskRepo{
+		users: map[string]int{"ana": ana},
+		tasks: []models.Task{
+			{ID: 1, Status: models.StatusOpen, AssignedTo: &ana, DueDate: &overdue},
+			{ID: 2, Status: models.StatusOpen, AssignedTo: &ana, DueDate: &today},
+			{ID: 3, Status: models.StatusOpen, AssignedTo: &bruno, DueDate: &today},
+			{ID: 4, Status: models.StatusOpen, AssignedTo: &ana},
+		},
+	}
+	s := newTestService(repo, time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC))
+
+	mine, err := s.Mine(context.Background(), "ana")
+	require.NoError(t, err)
+	require.Len(t, mine.Overdue, 1)
+	assert.Equal(t, 1, mine.Overdue[0].ID)
+	require.Len(t, mine.Today, 1)
+	assert.Equal(t, 2, mine.Today[0].ID)
+	assert.Empty(t, mine.Upcoming)
+	require.Len(t, mine.NoDueDate, 1)
+
+	_, err = s.Mine(context.Background(), "desconhecido")
+	assert.ErrorIs(t, err, appErrors.ErrUserNotFound)
+}
+
+func TestRunDunningUsesEachCompanyDay(t *testing.T) {
+	salesperson := 7
+	repo := &fakeTaskRepo{invoices: []models.OverdueInvoice{
+		{InvoiceID: 1, CompanyID: 1, InvoiceNo: "FAT-1", ContactName: "A", DueDate: day(12), Balance: money.FromInt(100), SalespersonID: &salesperson},
+		{InvoiceID: 2, CompanyID: 2, InvoiceNo: "FAT-2", ContactName: "B", DueDate: day(12), Balance: money.FromInt(50)},
+		{InvoiceID: 3, CompanyID: 1, InvoiceNo: "FAT-3", ContactName: "C", DueDate: day(13), Balance: money.FromInt(10)},
+	}}
+	// 01:00 UTC do dia 16 ainda é dia 15 em São Paulo
+	s := newTestService(repo, time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC))
+
+	created, closed, err := s.RunDunning(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, created, "só a fatura vencida há mais de 3 dias no dia da empresa")
+	assert.Equal(t, 0, closed)
+	assert.Equal(t, []int{1}, repo.companies)
+	assert.Equal(t, "dunning:invoice:1", *repo.tasks[0].SourceKey)
+	assert.Equal(t, &salesperson, repo.tasks[0].AssignedTo)
+
+	created, _, err = s.RunDunning(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, created, "a tarefa aberta não é criada de novo")
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/templates/models"
+	"ERP-ONSMART/backend/internal/modules/templates/service"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os modelos em vigor dos documentos (e-mails, termos da cotação, rodapé da fatura) no idioma
+// da requisição ou de ?lang=
+// @Security BearerAuth
+func ListTemplatesHandler(c *gin.Context) {
+	ctx, ok := languageContext(c)
+	if !ok {
+		return
+	}
+
+	templates, err := service.ListTemplates(ctx)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar modelos de documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// Busca o modelo em vigor do documento, com as variáveis disponíveis
+// @Security BearerAuth
+func GetTemplateHandler(c *gin.Context) {
+	ctx, ok := languageContext(c)
+	if !ok {
+		return
+	}
+
+	template, err := service.GetTemplate(ctx, c.Param("key"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar modelo de documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// Grava uma nova versão do modelo; o conteúdo é conferido com os dados de exemplo do documento
+// @Security BearerAuth
+func SaveTemplateHandler(c *gin.Context) {
+	ctx, ok := languageContext(c)
+	if !ok {
+		return
+	}
+
+	var input models.TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	template, err := service.SaveTemplate(ctx, c.Param("key"), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao gravar modelo de documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// Lista as versões gravadas do modelo, da mais recente para a mais antiga
+// @Security BearerAuth
+func ListTemplateVersionsHandler(c *gin.Context) {
+	ctx, ok := languageContext(c)
+	if !ok {
+		return
+	}
+
+	versions, err := service.ListVersions(ctx, c.Param("key"))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar versões do modelo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// Restaura uma versão anterior do modelo como nova versão; a versão 0 volta ao padrão do sistema
+// @Security BearerAuth
+func RestoreTemplateVersionHandler(c *gin.Context) {
+	ctx, ok := languageContext(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 0 {
+		c.Error(errors.ErrInvalidID)
+		return
+	}
+
+	template, err := service.RestoreVersion(ctx, c.Param("key"), version, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao restaurar versão do modelo")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// Pré-visualiza o documento com dados de exemplo; sem assunto ou corpo no pedido, usa o modelo em vigor
+// @Security BearerAuth
+func PreviewTemplateHandler(c *gin.Context) {
+	ctx, ok := languageContext(c)
+	if !ok {
+		return
+	}
+
+	var input models.PreviewInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	rendered, err := service.Preview(ctx, c.Param("key"), input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao pré-visualizar modelo de documento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preview": rendered})
+}
+
+// languageContext aplica ao contexto o idioma do modelo pedido em ?lang= (pt-BR ou en-US); sem
+// o parâmetro vale o idioma da requisição
+func languageContext(c *gin.Context) (context.Context, bool) {
+	ctx := c.Request.Context()
+	value := c.Query("lang")
+	if value == "" {
+		return ctx, true
+	}
+	if !i18n.IsSupported(value) {
+		c.Error(errors.InvalidParam("idioma não suportado: " + value))
+		return nil, false
+	}
+	return i18n.WithLanguage(ctx, value), true
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	contracts "ERP-ONSMART/backend/internal/modules/contracts/models"
+	reports "ERP-ONSMART/backend/internal/modules/reports/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"fmt"
+	"time"
+)
+
+// Chaves dos documentos que usam modelos
+const (
+	KeyInvoiceFooter          = "invoice.footer"
+	KeyQuotationTerms         = "quotation.terms"
+	KeyQuotationResponseEmail = "email.quotation_response"
+	KeyContractExpiringEmail  = "email.contract_expiring"
+	KeyScheduledReportEmail   = "email.scheduled_report"
+	KeyUserInvitationEmail    = "email.user_invitation"
+	KeyPasswordResetEmail     = "email.password_reset"
+	KeyProcessFollowupEmail   = "email.process_followup"
+)
+
+// Builtin é um documento que usa modelo, com o conteúdo padrão do sistema e os dados de exemplo
+// usados na validação e na pré-visualização. Subject e Body são o padrão em português.
+type Builtin struct {
+	Key       string
+	Name      string
+	Email     bool
+	Subject   string
+	Body      string
+	Variables []string
+	Sample    func() Data
+	// Translations traz o padrão nos demais idiomas, por idioma (en-US)
+	Translations map[string]Translation
+}
+
+// Translation é o conteúdo padrão do documento em outro idioma
+type Translation struct {
+	Subject string
+	Body    string
+}
+
+// Default devolve o assunto e o corpo padrão do documento no idioma; sem tradução vale o português
+func (b Builtin) Default(lang string) (string, string) {
+	if translation, ok := b.Translations[i18n.Normalize(lang)]; ok {
+		return translation.Subject, translation.Body
+	}
+	return b.Subject, b.Body
+}
+
+// Builtins lista os documentos na ordem exibida
+var Builtins = []Builtin{
+	{
+		Key:       KeyInvoiceFooter,
+		Name:      "Rodapé do PDF da fatura",
+		Body:      "{{if .Invoice.PaymentTerms}}Condições de pagamento: {{.Invoice.PaymentTerms}}{{end}}",
+		Variables: []string{".Invoice.InvoiceNo", ".Invoice.SONo", ".Invoice.IssueDate", ".Invoice.DueDate", ".Invoice.GrandTotal", ".Invoice.PaymentTerms", ".Contact.Name", ".Contact.Document"},
+		Sample:    func() Data { return Data{Invoice: sampleInvoice(), Contact: sampleContact()} },
+		Translations: map[string]Translation{
+			i18n.EnUS: {
+				Body: "{{if .Invoice.PaymentTerms}}Payment terms: {{.Invoice.PaymentTerms}}{{end}}",
+			},
+		},
+	},
+	{
+		Key:       KeyQuotationTerms,
+		Name:      "Termos da cotação (quando a cotação não traz os seus)",
+		Variables: []string{".Quotation.QuotationNo", ".Quotation.ExpiryDate", ".Quotation.GrandTotal", ".Contact.Name", ".Contact.Document"},
+		Sample:    func() Data { return Data{Quotation: sampleQuotation(), Contact: sampleContact()} },
+	},
+	{
+		Key:     KeyQuotationResponseEmail,
+		Name:    "E-mail ao vendedor com a resposta do cliente à cotação",
+		Email:   true,
+		Subject: "Cotação {{.Quotation.QuotationNo}} {{.Vars.decision}} pelo cliente",
+		Body: "Olá, {{.Vars.salesperson}}!\n\nA cotação {{.Quotation.QuotationNo}} foi {{.Vars.decision}} pelo cliente em " +
+			"{{datetime .Vars.responded_at}} ({{.Vars.channel}}, IP {{.Vars.ip_address}}).\n\n" +
+			"Comentário:\n{{if .Vars.comment}}{{.Vars.comment}}{{else}}(sem comentário){{end}}\n",
+		Variables: []string{".Quotation.QuotationNo", ".Quotation.GrandTotal", ".Vars.salesperson", ".Vars.decision", ".Vars.responded_at", ".Vars.channel", ".Vars.ip_address", ".Vars.comment"},
+		Sample: func() Data {
+			return Data{Quotation: sampleQuotation(), Vars: map[string]interface{}{
+				"salesperson": "Ana Souza", "decision": "aceita", "responded_at": sampleDate.Add(14 * time.Hour),
+				"channel": "link", "ip_address": "203.0.113.9", "comment": "Pode faturar",
+			}}
+		},
+		Translations: map[string]Translation{
+			i18n.EnUS: {
+				Subject: "Quotation {{.Quotation.QuotationNo}} {{.Vars.decision}} by the customer",
+				Body: "Hello, {{.Vars.salesperson}}!\n\nQuotation {{.Quotation.QuotationNo}} was {{.Vars.decision}} by the customer on " +
+					"{{datetime .Vars.responded_at}} ({{.Vars.channel}}, IP {{.Vars.ip_address}}).\n\n" +
+					"Comment:\n{{if .Vars.comment}}{{.Vars.comment}}{{else}}(no comment){{end}}\n",
+			},
+		},
+	},
+	{
+		Key:     KeyContractExpiringEmail,
+		Name:    "E-mail de aviso de contrato a vencer",
+		Email:   true,
+		Subject: "Contrato {{.Contract.ContractNo}} vence em {{.Vars.days}} dia(s)",
+		Body: "O contrato {{.Contract.ContractNo}} ({{.Contract.Title}}) vence em {{date .Contract.EndDate}}. " +
+			"Renove ou encerre o contrato para manter os preços negociados nas cotações e pedidos de compra.",
+		Variables: []string{".Contract.ContractNo", ".Contract.Title", ".Contract.StartDate", ".Contract.EndDate", ".Vars.days"},
+		Sample: func() Data {
+			return Data{Contract: &contracts.Contract{ContractNo: "CT-2024-01", Title: "Fornecimento anual",
+				StartDate: sampleDate.AddDate(-1, 0, 0), EndDate: sampleDate.AddDate(0, 0, 30)},
+				Vars: map[string]interface{}{"days": 30}}
+		},
+		Translations: map[string]Translation{
+			i18n.EnUS: {
+				Subject: "Contract {{.Contract.ContractNo}} expires in {{.Vars.days}} day(s)",
+				Body: "Contract {{.Contract.ContractNo}} ({{.Contract.Title}}) expires on {{date .Contract.EndDate}}. " +
+					"Renew or close the contract to keep the negotiated prices in quotations and purchase orders.",
+			},
+		},
+	},
+	{
+		Key:       KeyScheduledReportEmail,
+		Name:      "E-mail do relatório agendado",
+		Email:     true,
+		Subject:   "Relatório: {{.Report.Name}}",
+		Body:      `Segue em anexo o relatório {{printf "%q" .Report.Name}} ({{.Vars.rows}} linha(s)), gerado em {{datetime .Vars.generated_at}}.`,
+		Variables: []string{".Report.Name", ".Report.Description", ".Vars.rows", ".Vars.generated_at"},
+		Sample: func() Data {
+			return Data{Report: &reports.Report{Name: "Vendas por vendedor", Description: "Total vendido no mês"},
+				Vars: map[string]interface{}{"rows": 12, "generated_at": sampleDate.Add(8 * time.Hour)}}
+		},
+		Translations: map[string]Translation{
+			i18n.EnUS: {
+				Subject: "Report: {{.Report.Name}}",
+				Body:    `Please find attached the report {{printf "%q" .Report.Name}} ({{.Vars.rows}} row(s)), generated on {{datetime .Vars.generated_at}}.`,
+			},
+		},
+	},
+	{
+		Key:     KeyUserInvitationEmail,
+		Name:    "E-mail de convite de usuário",
+		Email:   true,
+		Subject: "Convite de acesso ao ERP",
+		Body: "Olá, {{.Vars.name}}!\n\n{{.Vars.invited_by}} convidou você para acessar o ERP.\n" +
+			"Crie seu usuário e senha pelo link abaixo, válido até {{datetime .Vars.expires_at}}:\n\n{{.Vars.link}}\n",
+		Variables: []string{".Vars.name", ".Vars.email", ".Vars.invited_by", ".Vars.expires_at", ".Vars.link"},
+		Sample: func() Data {
+			return Data{Vars: map[string]interface{}{"name": "Ana Souza", "email": "ana@exemplo.com", "invited_by": "admin",
+				"expires_at": sampleDate.AddDate(0, 0, 7), "link": "https://erp.exemplo.com/convite?token=..."}}
+		},
+		Translations: map[string]Translation{
+			i18n.EnUS: {
+				Subject: "Invitation to access the ERP",
+				Body: "Hello, {{.Vars.name}}!\n\n{{.Vars.invited_by}} invited you to access the ERP.\n" +
+					"Create your username and password using the link below, valid until {{datetime .Vars.expires_at}}:\n\n{{.Vars.link}}\n",
+			},
+		},
+	},
+	{
+		Key:     KeyPasswordResetEmail,
+		Name:    "E-mail de redefinição de senha",
+		Email:   true,
+		Subject: "Redefinição de senha do ERP",
+		Body: "Olá, {{.Vars.name}}!\n\nRecebemos um pedido para redefinir a senha do usuário {{.Vars.username}}.\n" +
+			"Defina a nova senha pelo link abaixo, válido até {{datetime .Vars.expires_at}}:\n\n{{.Vars.link}}\n\n" +
+			"Se você não fez o pedido, ignore este e-mail.\n",
+		Variables: []string{".Vars.name", ".Vars.username", ".Vars.expires_at", ".Vars.link"},
+		Sample: func() Data {
+			return Data{Vars: map[string]interface{}{"name": "Ana Souza", "username": "ana",
+				"expires_at": sampleDate.Add(time.Hour), "link": "https://erp.exemplo.com/redefinir-senha?token=..."}}
+		},
+		Translations: map[string]Translation{
+			i18n.EnUS: {
+				Subject: "ERP password reset",
+				Body: "Hello, {{.Vars.name}}!\n\nWe received a request to reset the password of the user {{.Vars.username}}.\n" +
+					"Set the new password using the link below, valid until {{datetime .Vars.expires_at}}:\n\n{{.Vars.link}}\n\n" +
+					"If you did not make this request, please ignore this e-mail.\n",
+			},
+		},
+	},
+	{
+		Key:     KeyProcessFollowupEmail,
+		Name:    "E-mail de lembrete ao vendedor de processo de venda parado",
+		Email:   true,
+		Subject: "Processo de venda {{.Vars.process_id}} parado há {{.Vars.idle_days}} dias",
+		Body: "Olá, {{.Vars.salesperson}}!\n\nO processo de venda {{.Vars.process_id}} de {{.Contact.Name}} está na etapa " +
+			"{{.Vars.stage}} sem atualização desde {{date .Vars.idle_since}} ({{.Vars.idle_days}} dias).\n\n" +
+			"Retome o contato com o cliente ou encerre o processo.\n",
+		Variables: []string{".Contact.Name", ".Vars.salesperson", ".Vars.process_id", ".Vars.stage", ".Vars.idle_since", ".Vars.idle_days"},
+		Sample: func() Data {
+			return Data{Contact: sampleContact(), Vars: map[string]interface{}{
+				"salesperson": "Ana Souza", "process_id": 42, "stage": "Cotação",
+				"idle_since": sampleDate.AddDate(0, 0, -15), "idle_days": 15,
+			}}
+		},
+		Translations: map[string]Translation{
+			i18n.EnUS: {
+				Subject: "Sales process {{.Vars.process_id}} idle for {{.Vars.idle_days}} days",
+				Body: "Hello, {{.Vars.salesperson}}!\n\nSales process {{.Vars.process_id}} of {{.Contact.Name}} is at the " +
+					"{{.Vars.stage}} stage with no updates since {{date .Vars.idle_since}} ({{.Vars.idle_days}} days).\n\n" +
+					"Get back in touch with the customer or close the process.\n",
+			},
+		},
+	},
+}
+
+// FindBuiltin busca o documento pela chave
+func FindBuiltin(key string) (Builtin, bool) {
+	for _, builtin := range Builtins {
+		if builtin.Key == key {
+			return builtin, true
+		}
+	}
+	return Builtin{}, false
+}
+
+// RenderBuiltin executa o modelo padrão do documento, sem consultar as versões da empresa
+func RenderBuiltin(key string, data Data) (*Rendered, error) {
+	builtin, ok := FindBuiltin(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrTemplateNotFound, key)
+	}
+	return builtin.RenderDefault(data)
+}
+
+var sampleDate = time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func sampleContact() *contact.Contact {
+	return &contact.Contact{ID: 1, Name: "Comercial Exemplo Ltda", Document: "12.345.678/0001-95", Email: "compras@exemplo.com"}
+}
+
+func sampleQuotation() *sales.Quotation {
+	return &sales.Quotation{ID: 1, QuotationNo: "QT-0001", ContactID: 1, Status: sales.QuotationStatusSent,
+		ExpiryDate: sampleDate.AddDate(0, 0, 30), SubTotal: money.FromInt(1500), GrandTotal: money.FromInt(1500), Contact: sampleContact()}
+}
+
+func sampleInvoice() *sales.Invoice {
+	return &sales.Invoice{ID: 1, InvoiceNo: "INV-0001", SONo: "SO-0001", ContactID: 1, Status: "sent",
+		IssueDate: sampleDate, DueDate: sampleDate.AddDate(0, 0, 30), SubTotal: money.FromInt(1500), GrandTotal: money.FromInt(1500),
+		PaymentTerms: "30 dias", Contact: sampleContact()}
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	contracts "ERP-ONSMART/backend/internal/modules/contracts/models"
+	reports "ERP-ONSMART/backend/internal/modules/reports/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Limites do assunto e do corpo de um modelo
+const (
+	MaxSubjectLength = 200
+	MaxBodyLength    = 20000
+)
+
+// Data são as variáveis disponíveis nos modelos ({{.Contact.Name}}, {{.Invoice.DueDate}}); cada
+// documento preenche só as suas, listadas em Builtin.Variables
+type Data struct {
+	Contact   *contact.Contact
+	Quotation *sales.Quotation
+	Invoice   *sales.Invoice
+	Contract  *contracts.Contract
+	Report    *reports.Report
+	// Vars traz os valores que não vêm de uma entidade, como o link do convite: {{.Vars.link}}
+	Vars map[string]interface{}
+	// Language é o idioma do documento (pt-BR, en-US), que define o formato de datas e valores
+	Language string
+}
+
+// Version é uma versão gravada do modelo de uma empresa
+type Version struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	CompanyID int       `json:"company_id" gorm:"<-:create"`
+	Key       string    `json:"key"`
+	Language  string    `json:"language"`
+	Version   int       `json:"version"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName define a tabela das versões dos modelos
+func (Version) TableName() string {
+	return "document_templates"
+}
+
+// Template é o modelo em vigor de um documento no idioma: a última versão da empresa ou, na
+// versão 0, o padrão do sistema
+type Template struct {
+	Key       string     `json:"key"`
+	Language  string     `json:"language"`
+	Name      string     `json:"name"`
+	Email     bool       `json:"email"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Version   int        `json:"version"`
+	Variables []string   `json:"variables"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// Padrão do sistema, para o administrador comparar ou voltar a ele
+	DefaultSubject string `json:"default_subject,omitempty"`
+	DefaultBody    string `json:"default_body"`
+}
+
+// TemplateInput é o conteúdo de uma nova versão; o assunto só vale para os e-mails
+type TemplateInput struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// PreviewInput é o conteúdo a pré-visualizar; o campo omitido usa o do modelo em vigor
+type PreviewInput struct {
+	Subject *string `json:"subject"`
+	Body    *string `json:"body"`
+}
+
+// Rendered é o documento gerado a partir do modelo
+type Rendered struct {
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+// Lines devolve as linhas não vazias do corpo, para os documentos escritos linha a linha (PDF)
+func (r *Rendered) Lines() []string {
+	var lines []string
+	for _, line := range strings.Split(r.Body, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Current monta o modelo em vigor no idioma a partir da última versão da empresa (nil usa o padrão)
+func (b Builtin) Current(lang string, latest *Version) Template {
+	subject, body := b.Default(lang)
+	template := Template{
+		Key:            b.Key,
+		Language:       lang,
+		Name:           b.Name,
+		Email:          b.Email,
+		Subject:        subject,
+		Body:           body,
+		Variables:      b.Variables,
+		DefaultSubject: subject,
+		DefaultBody:    body,
+	}
+	if latest != nil {
+		template.Subject, template.Body, template.Version = latest.Subject, latest.Body, latest.Version
+		template.UpdatedBy = latest.CreatedBy
+		createdAt := latest.CreatedAt
+		template.UpdatedAt = &createdAt
+	}
+	return template
+}
+
+// Validate confere o conteúdo de uma nova versão: os e-mails precisam de assunto e corpo, e o
+// modelo precisa compilar e executar com os dados de exemplo do documento
+func (b Builtin) Validate(lang string, input *TemplateInput) error {
+	input.Subject = strings.TrimSpace(input.Subject)
+	if !b.Email {
+		input.Subject = ""
+	}
+	if b.Email && (input.Subject == "" || strings.TrimSpace(input.Body) == "") {
+		return fmt.Errorf("%w: o e-mail precisa de assunto e corpo", errors.ErrInvalidTemplate)
+	}
+	if len([]rune(input.Subject)) > MaxSubjectLength {
+		return fmt.Errorf("%w: assunto com mais de %d caracteres", errors.ErrInvalidTemplate, MaxSubjectLength)
+	}
+	if len([]rune(input.Body)) > MaxBodyLength {
+		return fmt.Errorf("%w: corpo com mais de %d caracteres", errors.ErrInvalidTemplate, MaxBodyLength)
+	}
+	_, err := b.Render(input.Subject, input.Body, b.SampleIn(lang))
+	return err
+}
+
+// SampleIn devolve os dados de exemplo do documento no idioma
+func (b Builtin) SampleIn(lang string) Data {
+	data := b.Sample()
+	data.Language = lang
+	return data
+}
+
+// Render executa o assunto e o corpo com os dados. Variáveis inexistentes são erro; o assunto
+// fica em uma linha só.
+func (b Builtin) Render(subject, body string, data Data) (*Rendered, error) {
+	renderedSubject, err := execute(b.Key+".subject", subject, data)
+	if err != nil {
+		return nil, err
+	}
+	renderedBody, err := execute(b.Key+".body", body, data)
+	if err != nil {
+		return nil, err
+	}
+	return &Rendered{Subject: strings.Join(strings.Fields(renderedSubject), " "), Body: renderedBody}, nil
+}
+
+// RenderDefault executa o modelo padrão do documento no idioma dos dados
+func (b Builtin) RenderDefault(data Data) (*Rendered, error) {
+	subject, body := b.Default(data.Language)
+	return b.Render(subject, body, data)
+}
+
+func execute(name, text string, data Data) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := template.New(name).Funcs(funcsFor(data.Language)).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errors.ErrInvalidTemplate, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%w: %v", errors.ErrInvalidTemplate, err)
+	}
+	return buf.String(), nil
+}
+
+// funcsFor devolve as funções de formatação disponíveis, no formato do idioma:
+// {{date .Invoice.DueDate}}, {{money .Invoice.GrandTotal}}
+func funcsFor(lang string) template.FuncMap {
+	layout := i18n.DateLayout(lang)
+	return template.FuncMap{
+		"date":     func(value interface{}) string { return formatTime(value, layout) },
+		"datetime": func(value interface{}) string { return formatTime(value, layout+" 15:04") },
+		"money":    func(value money.Decimal) string { return formatMoney(lang, value) },
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+	}
+}
+
+func formatTime(value interface{}, layout string) string {
+	switch t := value.(type) {
+	case time.Time:
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	case *time.Time:
+		if t == nil || t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// formatMoney formata o valor em reais com os separadores do idioma: R$ 1.234,56 ou R$ 1,234.56
+func formatMoney(lang string, value money.Decimal) string {
+	thousands, decimal := ".", ","
+	if i18n.Normalize(lang) == i18n.EnUS {
+		thousands, decimal = ",", "."
+	}
+	sign := ""
+	if value.IsNegative() {
+		sign = "-"
+		value = value.Neg()
+	}
+	text := value.StringFixed(2)
+	integer, cents, _ := strings.Cut(text, ".")
+
+	var b strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(thousands)
+		}
+		b.WriteRune(digit)
+	}
+	return "R$ " + sign + b.String() + decimal + cents
+}
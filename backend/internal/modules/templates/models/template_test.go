@@ -0,0 +1,111 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	contracts "ERP-ONSMART/backend/internal/modules/contracts/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinsRenderWithSampleData(t *testing.T) {
+	for _, builtin := range Builtins {
+		for _, lang := range i18n.Languages {
+			rendered, err := builtin.RenderDefault(builtin.SampleIn(lang))
+			require.NoError(t, err, builtin.Key)
+			assert.Equal(t, builtin.Email, rendered.Subject != "", builtin.Key)
+		}
+	}
+}
+
+func TestRenderBuiltinContractExpiringEmail(t *testing.T) {
+	rendered, err := RenderBuiltin(KeyContractExpiringEmail, Data{
+		Contract: &contracts.Contract{ContractNo: "CT-1", Title: "Locação", EndDate: time.Date(2024, 7, 30, 0, 0, 0, 0, time.UTC)},
+		Vars:     map[string]interface{}{"days": 29},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Contrato CT-1 vence em 29 dia(s)", rendered.Subject)
+	assert.Equal(t, "O contrato CT-1 (Locação) vence em 30/07/2024. Renove ou encerre o contrato para manter os preços "+
+		"negociados nas cotações e pedidos de compra.", rendered.Body)
+
+	rendered, err = RenderBuiltin(KeyContractExpiringEmail, Data{
+		Contract: &contracts.Contract{ContractNo: "CT-1", Title: "Lease", EndDate: time.Date(2024, 7, 30, 0, 0, 0, 0, time.UTC)},
+		Vars:     map[string]interface{}{"days": 29},
+		Language: i18n.EnUS,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Contract CT-1 expires in 29 day(s)", rendered.Subject)
+	assert.Contains(t, rendered.Body, "(Lease) expires on 07/30/2024.")
+
+	_, err = RenderBuiltin("email.unknown", Data{})
+	assert.True(t, stderrors.Is(err, errors.ErrTemplateNotFound))
+}
+
+func TestInvoiceFooterSkipsEmptyPaymentTerms(t *testing.T) {
+	builtin, ok := FindBuiltin(KeyInvoiceFooter)
+	require.True(t, ok)
+
+	rendered, err := builtin.RenderDefault(Data{Invoice: &sales.Invoice{}})
+	require.NoError(t, err)
+	assert.Empty(t, rendered.Lines())
+
+	rendered, err = builtin.Render("", "Fatura {{.Invoice.InvoiceNo}}\n\n  \nVence em {{date .Invoice.DueDate}}: {{money .Invoice.GrandTotal}}",
+		Data{Invoice: &sales.Invoice{InvoiceNo: "INV-7", DueDate: time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), GrandTotal: money.MustParse("1234.5")}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Fatura INV-7", "Vence em 10/05/2024: R$ 1.234,50"}, rendered.Lines())
+
+	rendered, err = builtin.Render("", "Due {{date .Invoice.DueDate}}: {{money .Invoice.GrandTotal}}",
+		Data{Invoice: &sales.Invoice{DueDate: time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), GrandTotal: money.MustParse("1234.5")}, Language: i18n.EnUS})
+	require.NoError(t, err)
+	assert.Equal(t, "Due 05/10/2024: R$ 1,234.50", rendered.Body)
+}
+
+func TestBuiltinValidate(t *testing.T) {
+	email, _ := FindBuiltin(KeyScheduledReportEmail)
+	input := TemplateInput{Subject: "  Relatório {{.Report.Name}} ", Body: "{{.Vars.rows}} linha(s)"}
+	require.NoError(t, email.Validate(i18n.PtBR, &input))
+	assert.Equal(t, "Relatório {{.Report.Name}}", input.Subject)
+
+	cases := []TemplateInput{
+		{Subject: "", Body: "corpo"},                           // e-mail sem assunto
+		{Subject: "Relatório", Body: "{{.Report.Name"},         // não compila
+		{Subject: "Relatório", Body: "{{.Invoice.InvoiceNo}}"}, // variável de outro documento
+		{Subject: "Relatório", Body: "{{.Vars.link}}"},         // variável inexistente
+		{Subject: "Relatório", Body: "{{.Report.Owner}}"},      // campo inexistente
+	}
+	for _, input := range cases {
+		assert.True(t, stderrors.Is(email.Validate(i18n.PtBR, &input), errors.ErrInvalidTemplate), "%+v", input)
+	}
+
+	terms, _ := FindBuiltin(KeyQuotationTerms)
+	input = TemplateInput{Subject: "ignorado", Body: ""}
+	require.NoError(t, terms.Validate(i18n.PtBR, &input))
+	assert.Empty(t, input.Subject)
+}
+
+func TestCurrentUsesLatestVersion(t *testing.T) {
+	builtin, _ := FindBuiltin(KeyPasswordResetEmail)
+
+	template := builtin.Current(i18n.PtBR, nil)
+	assert.Zero(t, template.Version)
+	assert.Equal(t, builtin.Body, template.Body)
+
+	createdAt := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	template = builtin.Current(i18n.PtBR, &Version{Version: 3, Subject: "Nova senha", Body: "Link: {{.Vars.link}}", CreatedBy: "ana", CreatedAt: createdAt})
+	assert.Equal(t, 3, template.Version)
+	assert.Equal(t, "Nova senha", template.Subject)
+	assert.Equal(t, builtin.Subject, template.DefaultSubject)
+	assert.Equal(t, "ana", template.UpdatedBy)
+	assert.Equal(t, createdAt, *template.UpdatedAt)
+
+	english := builtin.Current(i18n.EnUS, nil)
+	assert.Equal(t, i18n.EnUS, english.Language)
+	assert.Equal(t, "ERP password reset", english.Subject)
+	assert.Equal(t, english.Subject, english.DefaultSubject)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/templates/models"
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TemplateRepository guarda as versões dos modelos de documento da empresa
+type TemplateRepository interface {
+	LatestVersions(ctx context.Context, language string) (map[string]models.Version, error)
+	ListVersions(ctx context.Context, key, language string) ([]models.Version, error)
+	GetVersion(ctx context.Context, key, language string, version int) (*models.Version, error)
+	CreateVersion(ctx context.Context, version *models.Version) error
+}
+
+type templateRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTemplateRepository cria uma nova instância do repositório
+func NewTemplateRepository() (TemplateRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &templateRepository{
+		db:     db,
+		logger: logger.WithModule("template_repository"),
+	}, nil
+}
+
+// LatestVersions devolve a última versão de cada modelo alterado pela empresa no idioma, por chave
+func (r *templateRepository) LatestVersions(ctx context.Context, language string) (map[string]models.Version, error) {
+	var versions []models.Version
+	if err := r.db.WithContext(ctx).Select("DISTINCT ON (key) *").Where("language = ?", language).
+		Order("key ASC, version DESC").Find(&versions).Error; err != nil {
+		r.logger.Error("erro ao buscar modelos de documento", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar modelos de documento")
+	}
+	latest := make(map[string]models.Version, len(versions))
+	for _, version := range versions {
+		latest[version.Key] = version
+	}
+	return latest, nil
+}
+
+// ListVersions lista as versões do modelo no idioma, da mais recente para a mais antiga
+func (r *templateRepository) ListVersions(ctx context.Context, key, language string) ([]models.Version, error) {
+	var versions []models.Version
+	if err := r.db.WithContext(ctx).Where("key = ? AND language = ?", key, language).Order("version DESC").Find(&versions).Error; err != nil {
+		r.logger.Error("erro ao listar versões do modelo", zap.Error(err), zap.String("key", key))
+		return nil, errors.WrapError(err, "falha ao listar versões do modelo")
+	}
+	return versions, nil
+}
+
+// GetVersion busca uma versão do modelo no idioma
+func (r *templateRepository) GetVersion(ctx context.Context, key, language string, version int) (*models.Version, error) {
+	var found models.Version
+	err := r.db.WithContext(ctx).Where("key = ? AND language = ? AND version = ?", key, language, version).First(&found).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao buscar versão do modelo")
+	}
+	return &found, nil
+}
+
+// CreateVersion grava a próxima versão do modelo; a numeração é por empresa, chave e idioma
+func (r *templateRepository) CreateVersion(ctx context.Context, version *models.Version) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last int
+		if err := tx.Model(&models.Version{}).Where("key = ? AND language = ?", version.Key, version.Language).
+			Select("COALESCE(MAX(version), 0)").Scan(&last).Error; err != nil {
+			return errors.WrapError(err, "falha ao numerar versão do modelo")
+		}
+		version.Version = last + 1
+		if err := tx.Create(version).Error; err != nil {
+			r.logger.Error("erro ao gravar versão do modelo", zap.Error(err), zap.String("key", version.Key))
+			return errors.WrapError(err, "falha ao gravar versão do modelo")
+		}
+		return nil
+	})
+}
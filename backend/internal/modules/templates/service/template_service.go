@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/templates/models"
+	"ERP-ONSMART/backend/internal/modules/templates/repository"
+
+	"go.uber.org/zap"
+)
+
+// Service mantém as versões dos modelos de documento e gera os textos usados pelos PDFs e
+// e-mails do sistema
+type Service struct {
+	newRepo func() (repository.TemplateRepository, error)
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	repo repository.TemplateRepository
+}
+
+// NewService cria o serviço sobre o repositório informado
+func NewService(newRepo func() (repository.TemplateRepository, error)) *Service {
+	return &Service{
+		newRepo: newRepo,
+		logger:  logger.WithModule("template_service"),
+	}
+}
+
+var defaultService = NewService(repository.NewTemplateRepository)
+
+// ListTemplates lista os modelos em vigor de todos os documentos
+func ListTemplates(ctx context.Context) ([]models.Template, error) {
+	return defaultService.ListTemplates(ctx)
+}
+
+// GetTemplate busca o modelo em vigor do documento
+func GetTemplate(ctx context.Context, key string) (*models.Template, error) {
+	return defaultService.GetTemplate(ctx, key)
+}
+
+// SaveTemplate grava uma nova versão do modelo
+func SaveTemplate(ctx context.Context, key string, input models.TemplateInput, username string) (*models.Template, error) {
+	return defaultService.SaveTemplate(ctx, key, input, username)
+}
+
+// ListVersions lista as versões gravadas do modelo
+func ListVersions(ctx context.Context, key string) ([]models.Version, error) {
+	return defaultService.ListVersions(ctx, key)
+}
+
+// RestoreVersion grava uma nova versão com o conteúdo de uma versão anterior
+func RestoreVersion(ctx context.Context, key string, version int, username string) (*models.Template, error) {
+	return defaultService.RestoreVersion(ctx, key, version, username)
+}
+
+// Preview gera o documento com os dados de exemplo
+func Preview(ctx context.Context, key string, input models.PreviewInput) (*models.Rendered, error) {
+	return defaultService.Preview(ctx, key, input)
+}
+
+// Render gera o documento com o modelo em vigor da empresa
+func Render(ctx context.Context, key string, data models.Data) (*models.Rendered, error) {
+	return defaultService.Render(ctx, key, data)
+}
+
+func (s *Service) repository() (repository.TemplateRepository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.repo == nil {
+		repo, err := s.newRepo()
+		if err != nil {
+			return nil, err
+		}
+		s.repo = repo
+	}
+	return s.repo, nil
+}
+
+// ListTemplates lista os modelos em vigor de todos os documentos no idioma do contexto
+func (s *Service) ListTemplates(ctx context.Context) ([]models.Template, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	lang := i18n.FromContext(ctx)
+	latest, err := repo.LatestVersions(ctx, lang)
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]models.Template, 0, len(models.Builtins))
+	for _, builtin := range models.Builtins {
+		templates = append(templates, builtin.Current(lang, latestOf(latest, builtin.Key)))
+	}
+	return templates, nil
+}
+
+// GetTemplate busca o modelo em vigor do documento no idioma do contexto
+func (s *Service) GetTemplate(ctx context.Context, key string) (*models.Template, error) {
+	builtin, err := findBuiltin(key)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	lang := i18n.FromContext(ctx)
+	latest, err := repo.LatestVersions(ctx, lang)
+	if err != nil {
+		return nil, err
+	}
+	template := builtin.Current(lang, latestOf(latest, key))
+	return &template, nil
+}
+
+// SaveTemplate valida o conteúdo com os dados de exemplo e grava uma nova versão do modelo no
+// idioma do contexto
+func (s *Service) SaveTemplate(ctx context.Context, key string, input models.TemplateInput, username string) (*models.Template, error) {
+	builtin, err := findBuiltin(key)
+	if err != nil {
+		return nil, err
+	}
+	lang := i18n.FromContext(ctx)
+	if err := builtin.Validate(lang, &input); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	version := &models.Version{Key: key, Language: lang, Subject: input.Subject, Body: input.Body, CreatedBy: username}
+	if err := repo.CreateVersion(ctx, version); err != nil {
+		return nil, err
+	}
+	s.logger.Info("modelo de documento alterado", zap.String("key", key), zap.String("language", lang),
+		zap.Int("version", version.Version), zap.String("created_by", username))
+	template := builtin.Current(lang, version)
+	return &template, nil
+}
+
+// ListVersions lista as versões gravadas do modelo no idioma do contexto, da mais recente para a
+// mais antiga
+func (s *Service) ListVersions(ctx context.Context, key string) ([]models.Version, error) {
+	if _, err := findBuiltin(key); err != nil {
+		return nil, err
+	}
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListVersions(ctx, key, i18n.FromContext(ctx))
+}
+
+// RestoreVersion grava uma nova versão com o conteúdo de uma versão anterior; a versão 0 volta
+// ao padrão do sistema. O histórico não é reescrito.
+func (s *Service) RestoreVersion(ctx context.Context, key string, version int, username string) (*models.Template, error) {
+	builtin, err := findBuiltin(key)
+	if err != nil {
+		return nil, err
+	}
+	lang := i18n.FromContext(ctx)
+	subject, body := builtin.Default(lang)
+	input := models.TemplateInput{Subject: subject, Body: body}
+	if version != 0 {
+		repo, err := s.repository()
+		if err != nil {
+			return nil, err
+		}
+		previous, err := repo.GetVersion(ctx, key, lang, version)
+		if err != nil {
+			return nil, err
+		}
+		input = models.TemplateInput{Subject: previous.Subject, Body: previous.Body}
+	}
+	return s.SaveTemplate(ctx, key, input, username)
+}
+
+// Preview gera o documento com os dados de exemplo; o assunto ou o corpo omitidos vêm do modelo
+// em vigor
+func (s *Service) Preview(ctx context.Context, key string, input models.PreviewInput) (*models.Rendered, error) {
+	builtin, err := findBuiltin(key)
+	if err != nil {
+		return nil, err
+	}
+	lang := i18n.FromContext(ctx)
+	subject, body := builtin.Default(lang)
+	if input.Subject == nil || input.Body == nil {
+		current, err := s.GetTemplate(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		subject, body = current.Subject, current.Body
+	}
+	if input.Subject != nil {
+		subject = *input.Subject
+	}
+	if input.Body != nil {
+		body = *input.Body
+	}
+	return builtin.Render(subject, body, builtin.SampleIn(lang))
+}
+
+// Render gera o documento com o modelo em vigor da empresa no idioma do contexto. Se a consulta
+// das versões ou a execução do modelo da empresa falhar, o erro fica no log e vale o modelo
+// padrão, para que o PDF ou o e-mail não deixe de sair.
+func (s *Service) Render(ctx context.Context, key string, data models.Data) (*models.Rendered, error) {
+	builtin, err := findBuiltin(key)
+	if err != nil {
+		return nil, err
+	}
+	data.Language = i18n.FromContext(ctx)
+	repo, err := s.repository()
+	if err != nil {
+		s.logger.Warn("modelos de documento indisponíveis, usando o padrão", zap.Error(err), zap.String("key", key))
+		return builtin.RenderDefault(data)
+	}
+	latest, err := repo.LatestVersions(ctx, data.Language)
+	if err != nil {
+		s.logger.Warn("modelos de documento indisponíveis, usando o padrão", zap.Error(err), zap.String("key", key))
+		return builtin.RenderDefault(data)
+	}
+	version, ok := latest[key]
+	if !ok {
+		return builtin.RenderDefault(data)
+	}
+	rendered, err := builtin.Render(version.Subject, version.Body, data)
+	if err != nil {
+		s.logger.Warn("erro ao gerar documento com o modelo da empresa, usando o padrão", zap.Error(err),
+			zap.String("key", key), zap.Int("version", version.Version))
+		return builtin.RenderDefault(data)
+	}
+	return rendered, nil
+}
+
+func findBuiltin(key string) (models.Builtin, error) {
+	builtin, ok := models.FindBuiltin(key)
+	if !ok {
+		return models.Builtin{}, fmt.Errorf("%w: %s", errors.ErrTemplateNotFound, key)
+	}
+	return builtin, nil
+}
+
+func latestOf(latest map[string]models.Version, key string) *models.Version {
+	if version, ok := latest[key]; ok {
+		return &version
+	}
+	return nil
+}
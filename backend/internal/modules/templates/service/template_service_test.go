@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	appErrors "ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/i18n"
+	"ERP-ONSMART/backend/internal/modules/templates/models"
+	"ERP-ONSMART/backend/internal/modules/templates/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTemplateRepo struct {
+	versions []models.Version
+	err      error
+}
+
+func (r *fakeTemplateRepo) LatestVersions(ctx context.Context, language string) (map[string]models.Version, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	latest := map[string]models.Version{}
+	for _, version := range r.versions {
+		if version.Language == language && version.Version > latest[version.Key].Version {
+			latest[version.Key] = version
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakeTemplateRepo) ListVersions(ctx context.Context, key, language string) ([]models.Version, error) {
+	var versions []models.Version
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if r.versions[i].Key == key && r.versions[i].Language == language {
+			versions = append(versions, r.versions[i])
+		}
+	}
+	return versions, nil
+}
+
+func (r *fakeTemplateRepo) GetVersion(ctx context.Context, key, language string, version int) (*models.Version, error) {
+	for _, found := range r.versions {
+		if found.Key == key && found.Language == language && found.Version == version {
+			return &found, nil
+		}
+	}
+	return nil, appErrors.ErrTemplateNotFound
+}
+
+func (r *fakeTemplateRepo) CreateVersion(ctx context.Context, version *models.Version) error {
+	version.Version = 1
+	for _, existing := range r.versions {
+		if existing.Key == version.Key && existing.Language == version.Language && existing.Version >= version.Version {
+			version.Version = existing.Version + 1
+		}
+	}
+	version.CreatedAt = time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	r.versions = append(r.versions, *version)
+	return nil
+}
+
+func newTestService(repo *fakeTemplateRepo) *Service {
+	return NewService(func() (repository.TemplateRepository, error) { return repo, nil })
+}
+
+func TestSaveTemplateCreatesVersions(t *testing.T) {
+	repo := &fakeTemplateRepo{}
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	template, err := s.SaveTemplate(ctx, models.KeyQuotationTerms, models.TemplateInput{Body: "Validade: {{date .Quotation.ExpiryDate}}"}, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, 1, template.Version)
+	assert.Equal(t, "ana", template.UpdatedBy)
+
+	template, err = s.SaveTemplate(ctx, models.KeyQuotationTerms, models.TemplateInput{Body: "Frete FOB"}, "bruno")
+	require.NoError(t, err)
+	assert.Equal(t, 2, template.Version)
+
+	_, err = s.SaveTemplate(ctx, models.KeyQuotationTerms, models.TemplateInput{Body: "{{.Contract.Title}}"}, "ana")
+	assert.True(t, stderrors.Is(err, appErrors.ErrInvalidTemplate))
+	_, err = s.SaveTemplate(ctx, "quotation.footer", models.TemplateInput{Body: "x"}, "ana")
+	assert.True(t, stderrors.Is(err, appErrors.ErrTemplateNotFound))
+
+	versions, err := s.ListVersions(ctx, models.KeyQuotationTerms)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "Frete FOB", versions[0].Body)
+}
+
+func TestListTemplatesMergesDefaultsAndVersions(t *testing.T) {
+	repo := &fakeTemplateRepo{versions: []models.Version{
+		{Key: models.KeyInvoiceFooter, Language: i18n.PtBR, Version: 1, Body: "Obrigado pela preferência"},
+	}}
+	s := newTestService(repo)
+
+	templates, err := s.ListTemplates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, templates, len(models.Builtins))
+	for _, template := range templates {
+		if template.Key == models.KeyInvoiceFooter {
+			assert.Equal(t, 1, template.Version)
+			assert.Equal(t, "Obrigado pela preferência", template.Body)
+		} else {
+			assert.Zero(t, template.Version, template.Key)
+		}
+	}
+}
+
+func TestRestoreVersion(t *testing.T) {
+	repo := &fakeTemplateRepo{}
+	s := newTestService(repo)
+	ctx := context.Background()
+	_, err := s.SaveTemplate(ctx, models.KeyContractExpiringEmail, models.TemplateInput{Subject: "Vencimento {{.Contract.ContractNo}}", Body: "Em {{.Vars.days}} dias"}, "ana")
+	require.NoError(t, err)
+	_, err = s.SaveTemplate(ctx, models.KeyContractExpiringEmail, models.TemplateInput{Subject: "Aviso", Body: "Contrato a vencer"}, "ana")
+	require.NoError(t, err)
+
+	template, err := s.RestoreVersion(ctx, models.KeyContractExpiringEmail, 1, "bruno")
+	require.NoError(t, err)
+	assert.Equal(t, 3, template.Version)
+	assert.Equal(t, "Vencimento {{.Contract.ContractNo}}", template.Subject)
+
+	builtin, _ := models.FindBuiltin(models.KeyContractExpiringEmail)
+	template, err = s.RestoreVersion(ctx, models.KeyContractExpiringEmail, 0, "bruno")
+	require.NoError(t, err)
+	assert.Equal(t, 4, template.Version)
+	assert.Equal(t, builtin.Body, template.Body)
+
+	_, err = s.RestoreVersion(ctx, models.KeyContractExpiringEmail, 9, "bruno")
+	assert.True(t, stderrors.Is(err, appErrors.ErrTemplateNotFound))
+}
+
+func TestPreviewUsesCurrentTemplateForOmittedParts(t *testing.T) {
+	repo := &fakeTemplateRepo{versions: []models.Version{
+		{Key: models.KeyScheduledReportEmail, Language: i18n.PtBR, Version: 1, Subject: "Relatório {{.Report.Name}}", Body: "{{.Vars.rows}} linha(s)"},
+	}}
+	s := newTestService(repo)
+
+	preview, err := s.Preview(context.Background(), models.KeyScheduledReportEmail, models.PreviewInput{})
+	require.NoError(t, err)
+	assert.Equal(t, "Relatório Vendas por vendedor", preview.Subject)
+	assert.Equal(t, "12 linha(s)", preview.Body)
+
+	body := "Gerado em {{date .Vars.generated_at}}"
+	preview, err = s.Preview(context.Background(), models.KeyScheduledReportEmail, models.PreviewInput{Body: &body})
+	require.NoError(t, err)
+	assert.Equal(t, "Relatório Vendas por vendedor", preview.Subject)
+	assert.Equal(t, "Gerado em 10/03/2026", preview.Body)
+}
+
+func TestRenderFallsBackToDefault(t *testing.T) {
+	data := models.Data{Vars: map[string]interface{}{"name": "Ana", "username": "ana",
+		"expires_at": time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC), "link": "https://erp/redefinir"}}
+
+	repo := &fakeTemplateRepo{versions: []models.Version{
+		{Key: models.KeyPasswordResetEmail, Language: i18n.PtBR, Version: 1, Subject: "Senha", Body: "Acesse {{.Vars.link}}"},
+	}}
+	rendered, err := newTestService(repo).Render(context.Background(), models.KeyPasswordResetEmail, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Acesse https://erp/redefinir", rendered.Body)
+
+	// Versão que não executa com os dados reais cai no padrão
+	repo.versions = append(repo.versions, models.Version{Key: models.KeyPasswordResetEmail, Language: i18n.PtBR, Version: 2, Subject: "Senha", Body: "{{.Vars.code}}"})
+	rendered, err = newTestService(repo).Render(context.Background(), models.KeyPasswordResetEmail, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Redefinição de senha do ERP", rendered.Subject)
+
+	rendered, err = newTestService(&fakeTemplateRepo{err: stderrors.New("banco indisponível")}).
+		Render(context.Background(), models.KeyPasswordResetEmail, data)
+	require.NoError(t, err)
+	assert.Contains(t, rendered.Body, "válido até 10/05/2024 10:00")
+}
+
+func TestTemplatesAreKeptPerLanguage(t *testing.T) {
+	repo := &fakeTemplateRepo{}
+	s := newTestService(repo)
+	english := i18n.WithLanguage(context.Background(), i18n.EnUS)
+
+	template, err := s.SaveTemplate(english, models.KeyInvoiceFooter, models.TemplateInput{Body: "Thank you"}, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, i18n.EnUS, template.Language)
+	assert.Equal(t, 1, template.Version)
+
+	portuguese, err := s.GetTemplate(context.Background(), models.KeyInvoiceFooter)
+	require.NoError(t, err)
+	assert.Zero(t, portuguese.Version)
+	assert.Contains(t, portuguese.Body, "Condições de pagamento")
+
+	data := models.Data{Vars: map[string]interface{}{"name": "Ana", "username": "ana",
+		"expires_at": time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC), "link": "https://erp/reset"}}
+	rendered, err := s.Render(english, models.KeyPasswordResetEmail, data)
+	require.NoError(t, err)
+	assert.Equal(t, "ERP password reset", rendered.Subject)
+	assert.Contains(t, rendered.Body, "valid until 05/10/2024 10:00")
+}
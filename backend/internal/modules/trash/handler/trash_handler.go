@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/trash/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrashHandler lista os registros do recurso que estão na lixeira
+// @Security BearerAuth
+func ListTrashHandler(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := pagination.NewPaginationParams(c.Request)
+
+		result, err := service.GetTrash(c.Request.Context(), resource, &params)
+		if err != nil {
+			c.Error(err).SetMeta("erro ao listar lixeira")
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// RestoreHandler retira o registro do recurso da lixeira
+// @Security BearerAuth
+func RestoreHandler(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		if err := service.Restore(c.Request.Context(), resource, id); err != nil {
+			c.Error(err).SetMeta("erro ao restaurar registro")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "registro restaurado"})
+	}
+}
+
+// PurgeHandler exclui definitivamente um registro da lixeira (restrito a administradores nas rotas)
+// @Security BearerAuth
+func PurgeHandler(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		if err := service.Purge(c.Request.Context(), resource, id); err != nil {
+			c.Error(err).SetMeta("erro ao excluir registro definitivamente")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "registro excluído definitivamente"})
+	}
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	product "ERP-ONSMART/backend/internal/modules/products/models"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+)
+
+// Recursos com soft delete expostos na lixeira (segmento da rota)
+const (
+	ResourceContacts    = "contacts"
+	ResourceProducts    = "products"
+	ResourceQuotations  = "quotations"
+	ResourceSalesOrders = "sales-orders"
+	ResourceInvoices    = "invoices"
+	ResourceDeliveries  = "deliveries"
+)
+
+// Resource descreve um cadastro ou documento arquivado por soft delete (coluna deleted_at)
+type Resource struct {
+	Name     string
+	Table    string
+	NotFound error
+	// NewList cria o slice tipado que recebe os registros da lixeira
+	NewList func() interface{}
+}
+
+var resources = map[string]Resource{
+	ResourceContacts: {
+		Name: ResourceContacts, Table: "contacts", NotFound: errors.ErrContactNotFound,
+		NewList: func() interface{} { return &[]contact.Contact{} },
+	},
+	ResourceProducts: {
+		Name: ResourceProducts, Table: "products", NotFound: errors.ErrProductNotFound,
+		NewList: func() interface{} { return &[]product.Product{} },
+	},
+	ResourceQuotations: {
+		Name: ResourceQuotations, Table: "quotations", NotFound: errors.ErrQuotationNotFound,
+		NewList: func() interface{} { return &[]sales.Quotation{} },
+	},
+	ResourceSalesOrders: {
+		Name: ResourceSalesOrders, Table: "sales_orders", NotFound: errors.ErrSalesOrderNotFound,
+		NewList: func() interface{} { return &[]sales.SalesOrder{} },
+	},
+	ResourceInvoices: {
+		Name: ResourceInvoices, Table: "invoices", NotFound: errors.ErrInvoiceNotFound,
+		NewList: func() interface{} { return &[]sales.Invoice{} },
+	},
+	ResourceDeliveries: {
+		Name: ResourceDeliveries, Table: "deliveries", NotFound: errors.ErrDeliveryNotFound,
+		NewList: func() interface{} { return &[]sales.Delivery{} },
+	},
+}
+
+// GetResource retorna a definição do recurso da lixeira pelo nome
+func GetResource(name string) (Resource, error) {
+	resource, ok := resources[name]
+	if !ok {
+		return Resource{}, errors.ErrUnsupportedTrashResource
+	}
+	return resource, nil
+}
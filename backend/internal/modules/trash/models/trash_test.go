@@ -0,0 +1,32 @@
+package models
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	sales "ERP-ONSMART/backend/internal/modules/sales/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetResource(t *testing.T) {
+	resource, err := GetResource(ResourceSalesOrders)
+	require.NoError(t, err)
+	assert.Equal(t, "sales_orders", resource.Table)
+	assert.Equal(t, errors.ErrSalesOrderNotFound, resource.NotFound)
+	assert.IsType(t, &[]sales.SalesOrder{}, resource.NewList())
+
+	_, err = GetResource("users")
+	assert.Equal(t, errors.ErrUnsupportedTrashResource, err)
+}
+
+func TestResourcesAreComplete(t *testing.T) {
+	for _, name := range []string{ResourceContacts, ResourceProducts, ResourceQuotations, ResourceSalesOrders, ResourceInvoices, ResourceDeliveries} {
+		resource, err := GetResource(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, name, resource.Name)
+		assert.NotEmpty(t, resource.Table)
+		assert.True(t, errors.IsNotFound(resource.NotFound), name)
+		assert.NotNil(t, resource.NewList())
+	}
+}
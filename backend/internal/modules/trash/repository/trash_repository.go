@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/trash/models"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+	stderrors "errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Código do PostgreSQL para violação de chave estrangeira
+const foreignKeyViolation = "23503"
+
+// TrashRepository define as operações da lixeira dos registros com soft delete
+type TrashRepository interface {
+	GetDeleted(ctx context.Context, resource models.Resource, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	Restore(ctx context.Context, resource models.Resource, id int) error
+	Purge(ctx context.Context, resource models.Resource, id int) error
+}
+
+type trashRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewTrashRepository cria uma nova instância do repositório
+func NewTrashRepository() (TrashRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &trashRepository{
+		db:     db,
+		logger: logger.WithModule("trash_repository"),
+	}, nil
+}
+
+// GetDeleted lista os registros do recurso que estão na lixeira, dos mais recentes aos mais antigos
+func (r *trashRepository) GetDeleted(ctx context.Context, resource models.Resource, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := db.Conn(ctx, r.db).Unscoped().Table(resource.Table).
+		Scopes(tenant.Scope(ctx, resource.Table)).
+		Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar registros da lixeira", zap.Error(err), zap.String("resource", resource.Name))
+		return nil, errors.WrapError(err, "falha ao contar registros da lixeira")
+	}
+
+	list := resource.NewList()
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("deleted_at DESC").Offset(offset).Limit(params.PageSize).Find(list).Error; err != nil {
+		r.logger.Error("erro ao listar registros da lixeira", zap.Error(err), zap.String("resource", resource.Name))
+		return nil, errors.WrapError(err, "falha ao listar registros da lixeira")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, list), nil
+}
+
+// Restore retira o registro da lixeira
+func (r *trashRepository) Restore(ctx context.Context, resource models.Resource, id int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	result := db.Conn(ctx, r.db).Exec("UPDATE "+resource.Table+" SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL AND "+company, id)
+	if result.Error != nil {
+		r.logger.Error("erro ao restaurar registro", zap.Error(result.Error), zap.String("resource", resource.Name), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao restaurar registro")
+	}
+	if result.RowsAffected == 0 {
+		return r.missingFromTrash(ctx, resource, id)
+	}
+
+	r.logger.Info("registro restaurado da lixeira", zap.String("resource", resource.Name), zap.Int("id", id))
+	return nil
+}
+
+// Purge remove definitivamente um registro que já está na lixeira (os itens saem em cascata)
+func (r *trashRepository) Purge(ctx context.Context, resource models.Resource, id int) error {
+	company, err := tenant.Condition(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	result := db.Conn(ctx, r.db).Exec("DELETE FROM "+resource.Table+" WHERE id = ? AND deleted_at IS NOT NULL AND "+company, id)
+	if result.Error != nil {
+		// Documentos ainda referenciados (ex.: pedido com entregas) não podem ser apagados
+		var pgErr interface{ SQLState() string }
+		if stderrors.As(result.Error, &pgErr) && pgErr.SQLState() == foreignKeyViolation {
+			return errors.ErrRelatedRecordsExist
+		}
+		r.logger.Error("erro ao excluir registro definitivamente", zap.Error(result.Error), zap.String("resource", resource.Name), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao excluir registro definitivamente")
+	}
+	if result.RowsAffected == 0 {
+		return r.missingFromTrash(ctx, resource, id)
+	}
+
+	r.logger.Info("registro excluído definitivamente", zap.String("resource", resource.Name), zap.Int("id", id))
+	return nil
+}
+
+// missingFromTrash diferencia o registro ativo (fora da lixeira) do inexistente
+func (r *trashRepository) missingFromTrash(ctx context.Context, resource models.Resource, id int) error {
+	var count int64
+	if err := db.Conn(ctx, r.db).Table(resource.Table).Scopes(tenant.Scope(ctx, resource.Table)).Where("id = ?", id).Count(&count).Error; err != nil {
+		return errors.WrapError(err, "falha ao verificar registro")
+	}
+	if count > 0 {
+		return errors.ErrNotInTrash
+	}
+	return resource.NotFound
+}
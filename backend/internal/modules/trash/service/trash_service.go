@@ -0,0 +1,64 @@
+package service
+
+import (
+	contacts "ERP-ONSMART/backend/internal/modules/contact/repository"
+	products "ERP-ONSMART/backend/internal/modules/products/repository"
+	"ERP-ONSMART/backend/internal/modules/trash/models"
+	"ERP-ONSMART/backend/internal/modules/trash/repository"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"context"
+)
+
+// GetTrash lista os registros do recurso que estão na lixeira
+func GetTrash(ctx context.Context, resourceName string, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	resource, err := models.GetResource(resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewTrashRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetDeleted(ctx, resource, params)
+}
+
+// Restore retira o registro da lixeira
+func Restore(ctx context.Context, resourceName string, id int) error {
+	resource, err := models.GetResource(resourceName)
+	if err != nil {
+		return err
+	}
+
+	repo, err := repository.NewTrashRepository()
+	if err != nil {
+		return err
+	}
+	defer invalidateCache(resource, id)
+	return repo.Restore(ctx, resource, id)
+}
+
+// Purge exclui definitivamente um registro da lixeira
+func Purge(ctx context.Context, resourceName string, id int) error {
+	resource, err := models.GetResource(resourceName)
+	if err != nil {
+		return err
+	}
+
+	repo, err := repository.NewTrashRepository()
+	if err != nil {
+		return err
+	}
+	defer invalidateCache(resource, id)
+	return repo.Purge(ctx, resource, id)
+}
+
+// invalidateCache descarta o registro do cache de dados de referência do recurso, se houver
+func invalidateCache(resource models.Resource, id int) {
+	switch resource.Name {
+	case models.ResourceProducts:
+		products.InvalidateProducts(id)
+	case models.ResourceContacts:
+		contacts.InvalidateContacts(id)
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/users/models"
+	"ERP-ONSMART/backend/internal/modules/users/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Aceita o convite recebido por e-mail, criando o usuário com a senha escolhida
+func AcceptInvitationHandler(c *gin.Context) {
+	var input models.AcceptInvitationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	user, err := service.AcceptInvitation(input)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao aceitar convite")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"user": user})
+}
+
+// Envia o link de redefinição de senha; a resposta é a mesma para e-mails não cadastrados
+func ForgotPasswordHandler(c *gin.Context) {
+	var input models.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.RequestPasswordReset(input.Email); err != nil {
+		c.Error(err).SetMeta("erro ao solicitar redefinição de senha")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "se o e-mail estiver cadastrado, enviaremos o link de redefinição"})
+}
+
+// Define a nova senha a partir do link de redefinição
+func ResetPasswordHandler(c *gin.Context) {
+	var input models.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	if err := service.ResetPassword(input); err != nil {
+		c.Error(err).SetMeta("erro ao redefinir senha")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "senha redefinida com sucesso"})
+}
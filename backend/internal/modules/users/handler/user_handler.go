@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/users/models"
+	"ERP-ONSMART/backend/internal/modules/users/repository"
+	"ERP-ONSMART/backend/internal/modules/users/service"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lista os usuários da empresa
+// @Security BearerAuth
+// @Param search query string false "busca por nome, username ou e-mail"
+// @Param role query string false "perfil de acesso (admin, finance_user, marketing_user, sales_user, colaborador)"
+// @Param active query bool false "situação da conta"
+// @Param page query int false "página"
+// @Param page_size query int false "itens por página (máx. 100)"
+func ListUsersHandler(c *gin.Context) {
+	filter := repository.UserFilter{
+		Search: c.Query("search"),
+		Role:   c.Query("role"),
+	}
+	if value := c.Query("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			c.Error(errors.InvalidParam("active deve ser true ou false"))
+			return
+		}
+		filter.Active = &active
+	}
+	params := pagination.NewPaginationParams(c.Request)
+
+	result, err := service.ListUsers(c.Request.Context(), filter, &params)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar usuários")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Retorna um usuário da empresa
+// @Security BearerAuth
+func GetUserHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := service.GetUser(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao buscar usuário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// Convida um usuário por e-mail; a conta é criada quando o convite é aceito
+// @Security BearerAuth
+func InviteUserHandler(c *gin.Context) {
+	var input models.InviteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	invitation, err := service.InviteUser(c.Request.Context(), input, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao convidar usuário")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invitation": invitation})
+}
+
+// Lista os convites da empresa
+// @Security BearerAuth
+// @Param pending query bool false "apenas convites não aceitos e dentro da validade"
+func ListInvitationsHandler(c *gin.Context) {
+	pending, _ := strconv.ParseBool(c.Query("pending"))
+
+	invitations, err := service.ListInvitations(c.Request.Context(), pending)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao listar convites")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// Atribui o perfil de acesso do usuário (vale a partir do próximo login)
+// @Security BearerAuth
+func AssignRoleHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input models.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errors.InvalidRequest(err))
+		return
+	}
+
+	user, err := service.AssignRole(c.Request.Context(), id, input.Role, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao atribuir perfil")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// Desativa a conta do usuário, que deixa de conseguir fazer login
+// @Security BearerAuth
+func DeactivateUserHandler(c *gin.Context) {
+	setUserActive(c, false)
+}
+
+// Reativa a conta do usuário
+// @Security BearerAuth
+func ActivateUserHandler(c *gin.Context) {
+	setUserActive(c, true)
+}
+
+// Desbloqueia a conta bloqueada por tentativas de login sem sucesso
+// @Security BearerAuth
+func UnlockUserHandler(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := service.UnlockUser(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("erro ao desbloquear usuário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+func setUserActive(c *gin.Context, active bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := service.SetUserActive(c.Request.Context(), id, active, middleware.CurrentUsername(c))
+	if err != nil {
+		c.Error(err).SetMeta("erro ao alterar situação do usuário")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.Error(errors.ErrInvalidID)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Perfis de acesso aceitos pelo RBACMiddleware (claim role do token)
+const (
+	RoleAdmin       = "admin"
+	RoleFinance     = "finance_user"
+	RoleMarketing   = "marketing_user"
+	RoleSales       = "sales_user"
+	RoleColaborador = "colaborador"
+)
+
+// Roles lista os perfis que podem ser atribuídos aos usuários
+var Roles = []string{RoleAdmin, RoleFinance, RoleMarketing, RoleSales, RoleColaborador}
+
+// NormalizeRole retorna o perfil em minúsculas e se ele é um dos perfis aceitos
+func NormalizeRole(role string) (string, bool) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	for _, valid := range Roles {
+		if role == valid {
+			return role, true
+		}
+	}
+	return role, false
+}
+
+// User é a conta de acesso ao ERP; o perfil (coluna cargo) vai na claim role do token
+type User struct {
+	ID                  int        `json:"id" gorm:"primaryKey"`
+	CompanyID           int        `json:"company_id" gorm:"<-:create"`
+	Username            string     `json:"username"`
+	Password            string     `json:"-"`
+	Email               string     `json:"email"`
+	Nome                string     `json:"nome"`
+	Telefone            string     `json:"telefone"`
+	Role                string     `json:"role" gorm:"column:cargo"`
+	Active              bool       `json:"active" gorm:"default:true"`
+	FailedLoginAttempts int        `json:"failed_login_attempts"`
+	LockedUntil         *time.Time `json:"locked_until"`
+	LastLoginAt         *time.Time `json:"last_login_at"`
+	PasswordChangedAt   *time.Time `json:"password_changed_at"`
+	CreatedAt           time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
+
+// IsLocked indica se a conta está bloqueada no instante informado
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(now)
+}
+
+// UserInvitation é o convite enviado por e-mail; o usuário é criado quando o convite é aceito
+type UserInvitation struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	CompanyID  int        `json:"company_id" gorm:"<-:create"`
+	Email      string     `json:"email"`
+	Nome       string     `json:"nome"`
+	Role       string     `json:"role" gorm:"column:cargo"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  string     `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	UserID     *int       `json:"user_id"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (UserInvitation) TableName() string {
+	return "user_invitations"
+}
+
+// PasswordResetToken é o pedido de redefinição de senha, válido uma única vez
+type PasswordResetToken struct {
+	ID        int        `json:"id" gorm:"primaryKey"`
+	UserID    int        `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
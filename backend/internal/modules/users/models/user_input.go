@@ -0,0 +1,41 @@
+package models
+
+// InviteInput são os dados do convite enviado pelo administrador
+type InviteInput struct {
+	Email string `json:"email" binding:"required,email,max=100"`
+	Nome  string `json:"nome" binding:"required,max=100"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// AcceptInvitationInput cria a conta a partir do token recebido por e-mail
+type AcceptInvitationInput struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required"`
+	Telefone string `json:"telefone" binding:"max=20"`
+}
+
+// CreateAdminInput cria um administrador direto, sem convite (erpctl create-admin)
+type CreateAdminInput struct {
+	CompanyID int
+	Username  string
+	Email     string
+	Nome      string
+	Password  string
+}
+
+// ForgotPasswordInput solicita o e-mail de redefinição de senha
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordInput define a nova senha a partir do token recebido por e-mail
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RoleInput atribui o perfil de acesso do usuário
+type RoleInput struct {
+	Role string `json:"role" binding:"required"`
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/users/models"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// UserFilter filtra a listagem de usuários
+type UserFilter struct {
+	Search string
+	Role   string
+	Active *bool
+}
+
+// UserRepository define as operações de gestão de usuários, convites e redefinição de senha
+type UserRepository interface {
+	List(ctx context.Context, filter UserFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Exists(ctx context.Context, username, email string) (usernameTaken bool, emailTaken bool, err error)
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
+	Create(ctx context.Context, user *models.User) error
+
+	CreateInvitation(ctx context.Context, invitation *models.UserInvitation) error
+	ListInvitations(ctx context.Context, pendingOnly bool) ([]models.UserInvitation, error)
+	FindInvitation(ctx context.Context, tokenHash string) (*models.UserInvitation, error)
+	AcceptInvitation(ctx context.Context, invitation *models.UserInvitation, user *models.User) error
+
+	CreateResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	FindResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	ResetPassword(ctx context.Context, token *models.PasswordResetToken, passwordHash string, now time.Time) error
+}
+
+type userRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewUserRepository cria uma nova instância do repositório
+func NewUserRepository() (UserRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &userRepository{
+		db:     db,
+		logger: logger.WithModule("user_repository"),
+	}, nil
+}
+
+// List lista os usuários da empresa com filtros por perfil, situação e busca por nome, username ou e-mail
+func (r *userRepository) List(ctx context.Context, filter UserFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	query := r.db.WithContext(ctx).Model(&models.User{})
+	if filter.Role != "" {
+		query = query.Where("LOWER(cargo) = ?", strings.ToLower(filter.Role))
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+	if filter.Search != "" {
+		term := "%" + strings.ToLower(filter.Search) + "%"
+		query = query.Where("LOWER(username) LIKE ? OR LOWER(nome) LIKE ? OR LOWER(email) LIKE ?", term, term, term)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("erro ao contar usuários", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao contar usuários")
+	}
+
+	var users []models.User
+	offset := pagination.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("nome ASC, id ASC").Offset(offset).Limit(params.PageSize).Find(&users).Error; err != nil {
+		r.logger.Error("erro ao listar usuários", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar usuários")
+	}
+
+	return pagination.NewPaginatedResult(total, params.Page, params.PageSize, users), nil
+}
+
+// GetByID busca um usuário pelo ID
+func (r *userRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		r.logger.Error("erro ao buscar usuário", zap.Error(err), zap.Int("id", id))
+		return nil, errors.WrapError(err, "falha ao buscar usuário")
+	}
+	return &user, nil
+}
+
+// FindByEmail busca o usuário pelo e-mail, sem diferenciar maiúsculas
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).
+		Order("id ASC").First(&user).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		r.logger.Error("erro ao buscar usuário por e-mail", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar usuário")
+	}
+	return &user, nil
+}
+
+// Exists indica se o username ou o e-mail já pertencem a algum usuário
+func (r *userRepository) Exists(ctx context.Context, username, email string) (bool, bool, error) {
+	var usernameCount, emailCount int64
+	db := r.db.WithContext(ctx)
+	if username != "" {
+		if err := db.Model(&models.User{}).Where("LOWER(username) = ?", strings.ToLower(username)).Count(&usernameCount).Error; err != nil {
+			r.logger.Error("erro ao verificar username", zap.Error(err))
+			return false, false, errors.WrapError(err, "falha ao verificar usuário")
+		}
+	}
+	if email != "" {
+		if err := db.Model(&models.User{}).Where("LOWER(email) = ?", strings.ToLower(email)).Count(&emailCount).Error; err != nil {
+			r.logger.Error("erro ao verificar e-mail", zap.Error(err))
+			return false, false, errors.WrapError(err, "falha ao verificar usuário")
+		}
+	}
+	return usernameCount > 0, emailCount > 0, nil
+}
+
+// Update altera os campos informados do usuário
+func (r *userRepository) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	fields["updated_at"] = time.Now()
+	result := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		r.logger.Error("erro ao atualizar usuário", zap.Error(result.Error), zap.Int("id", id))
+		return errors.WrapError(result.Error, "falha ao atualizar usuário")
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrUserNotFound
+	}
+	return nil
+}
+
+// Create grava o usuário
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+		r.logger.Error("erro ao criar usuário", zap.Error(err), zap.String("username", user.Username))
+		return errors.WrapError(err, "falha ao criar usuário")
+	}
+	return nil
+}
+
+// CreateInvitation grava o convite
+func (r *userRepository) CreateInvitation(ctx context.Context, invitation *models.UserInvitation) error {
+	if err := r.db.WithContext(ctx).Create(invitation).Error; err != nil {
+		r.logger.Error("erro ao criar convite", zap.Error(err))
+		return errors.WrapError(err, "falha ao criar convite")
+	}
+	return nil
+}
+
+// ListInvitations lista os convites da empresa, mais recentes primeiro
+func (r *userRepository) ListInvitations(ctx context.Context, pendingOnly bool) ([]models.UserInvitation, error) {
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if pendingOnly {
+		query = query.Where("accepted_at IS NULL AND expires_at > ?", time.Now())
+	}
+
+	var invitations []models.UserInvitation
+	if err := query.Find(&invitations).Error; err != nil {
+		r.logger.Error("erro ao listar convites", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar convites")
+	}
+	return invitations, nil
+}
+
+// FindInvitation busca o convite pelo hash do token
+func (r *userRepository) FindInvitation(ctx context.Context, tokenHash string) (*models.UserInvitation, error) {
+	var invitation models.UserInvitation
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidUserToken
+		}
+		r.logger.Error("erro ao buscar convite", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar convite")
+	}
+	return &invitation, nil
+}
+
+// AcceptInvitation cria o usuário e marca o convite como aceito na mesma transação; um
+// convite já aceito (inclusive por uma requisição concorrente) é recusado
+func (r *userRepository) AcceptInvitation(ctx context.Context, invitation *models.UserInvitation, user *models.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.UserInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			r.logger.Error("erro ao aceitar convite", zap.Error(result.Error), zap.Int("invitation_id", invitation.ID))
+			return errors.WrapError(result.Error, "falha ao aceitar convite")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrInvalidUserToken
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			r.logger.Error("erro ao criar usuário do convite", zap.Error(err), zap.Int("invitation_id", invitation.ID))
+			return errors.WrapError(err, "falha ao criar usuário")
+		}
+
+		if err := tx.Model(&models.UserInvitation{}).Where("id = ?", invitation.ID).Update("user_id", user.ID).Error; err != nil {
+			r.logger.Error("erro ao vincular usuário ao convite", zap.Error(err), zap.Int("invitation_id", invitation.ID))
+			return errors.WrapError(err, "falha ao aceitar convite")
+		}
+
+		invitation.AcceptedAt = &now
+		invitation.UserID = &user.ID
+		return nil
+	})
+}
+
+// CreateResetToken grava o pedido de redefinição, invalidando os pedidos anteriores do usuário
+func (r *userRepository) CreateResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", time.Now()).Error; err != nil {
+			r.logger.Error("erro ao invalidar tokens de redefinição", zap.Error(err), zap.Int("user_id", token.UserID))
+			return errors.WrapError(err, "falha ao solicitar redefinição de senha")
+		}
+		if err := tx.Create(token).Error; err != nil {
+			r.logger.Error("erro ao criar token de redefinição", zap.Error(err), zap.Int("user_id", token.UserID))
+			return errors.WrapError(err, "falha ao solicitar redefinição de senha")
+		}
+		return nil
+	})
+}
+
+// FindResetToken busca o pedido de redefinição pelo hash do token
+func (r *userRepository) FindResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidUserToken
+		}
+		r.logger.Error("erro ao buscar token de redefinição", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao buscar token de redefinição")
+	}
+	return &token, nil
+}
+
+// ResetPassword consome o token e grava a nova senha, desbloqueando a conta
+func (r *userRepository) ResetPassword(ctx context.Context, token *models.PasswordResetToken, passwordHash string, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			r.logger.Error("erro ao consumir token de redefinição", zap.Error(result.Error), zap.Int("token_id", token.ID))
+			return errors.WrapError(result.Error, "falha ao redefinir senha")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrInvalidUserToken
+		}
+
+		result = tx.Model(&models.User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"password":              passwordHash,
+			"password_changed_at":   now,
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+			"updated_at":            now,
+		})
+		if result.Error != nil {
+			r.logger.Error("erro ao gravar nova senha", zap.Error(result.Error), zap.Int("user_id", token.UserID))
+			return errors.WrapError(result.Error, "falha ao redefinir senha")
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrUserNotFound
+		}
+		return nil
+	})
+}
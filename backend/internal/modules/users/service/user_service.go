@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/mailer"
+	templates "ERP-ONSMART/backend/internal/modules/templates/models"
+	templateService "ERP-ONSMART/backend/internal/modules/templates/service"
+	"ERP-ONSMART/backend/internal/modules/users/models"
+	"ERP-ONSMART/backend/internal/modules/users/repository"
+	"ERP-ONSMART/backend/internal/tenant"
+	"ERP-ONSMART/backend/internal/utils/pagination"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultInviteTTL        = 72 * time.Hour
+	defaultPasswordResetTTL = time.Hour
+	minPasswordLength       = 8
+)
+
+// ListUsers lista os usuários da empresa da requisição
+func ListUsers(ctx context.Context, filter repository.UserFilter, params *pagination.PaginationParams) (*pagination.PaginatedResult, error) {
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.List(ctx, filter, params)
+}
+
+// GetUser busca um usuário da empresa da requisição
+func GetUser(ctx context.Context, id int) (*models.User, error) {
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+// InviteUser cria o convite na empresa da requisição e envia o link de cadastro por e-mail
+func InviteUser(ctx context.Context, input models.InviteInput, invitedBy string) (*models.UserInvitation, error) {
+	role, ok := models.NormalizeRole(input.Role)
+	if !ok {
+		return nil, errors.ErrInvalidRole
+	}
+
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	// E-mail e username são únicos na instalação, não apenas na empresa
+	_, emailTaken, err := repo.Exists(tenant.AllCompanies(ctx), "", input.Email)
+	if err != nil {
+		return nil, err
+	}
+	if emailTaken {
+		return nil, errors.ErrEmailTaken
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	companyID, ok := tenant.CompanyID(ctx)
+	if !ok {
+		companyID = tenant.DefaultCompanyID
+	}
+	invitation := &models.UserInvitation{
+		CompanyID: companyID,
+		Email:     strings.TrimSpace(input.Email),
+		Nome:      input.Nome,
+		Role:      role,
+		TokenHash: tokenHash,
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(ttl("USER_INVITE_TTL", defaultInviteTTL)),
+	}
+	if err := repo.CreateInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	message, err := templateService.Render(ctx, templates.KeyUserInvitationEmail, templates.Data{Vars: map[string]interface{}{
+		"name":       invitation.Nome,
+		"email":      invitation.Email,
+		"invited_by": invitedBy,
+		"expires_at": invitation.ExpiresAt,
+		"link":       frontendLink("/convite", token),
+	}})
+	if err != nil {
+		return nil, err
+	}
+	err = mailer.Send(mailer.Message{To: invitation.Email, Subject: message.Subject, Body: message.Body})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// ListInvitations lista os convites da empresa da requisição
+func ListInvitations(ctx context.Context, pendingOnly bool) ([]models.UserInvitation, error) {
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListInvitations(ctx, pendingOnly)
+}
+
+// AcceptInvitation cria a conta do convidado, na empresa e com o perfil definidos no convite
+func AcceptInvitation(input models.AcceptInvitationInput) (*models.User, error) {
+	if err := ValidatePassword(input.Password); err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	// O token identifica o convite em qualquer empresa; a requisição é anônima
+	ctx := tenant.AllCompanies(context.Background())
+	invitation, err := repo.FindInvitation(ctx, hashToken(input.Token))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if invitation.AcceptedAt != nil || !invitation.ExpiresAt.After(now) {
+		return nil, errors.ErrInvalidUserToken
+	}
+
+	usernameTaken, emailTaken, err := repo.Exists(ctx, input.Username, invitation.Email)
+	if err != nil {
+		return nil, err
+	}
+	if usernameTaken {
+		return nil, errors.ErrUsernameTaken
+	}
+	if emailTaken {
+		return nil, errors.ErrEmailTaken
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		CompanyID:         invitation.CompanyID,
+		Username:          input.Username,
+		Password:          string(hashed),
+		Email:             invitation.Email,
+		Nome:              invitation.Nome,
+		Telefone:          input.Telefone,
+		Role:              invitation.Role,
+		Active:            true,
+		PasswordChangedAt: &now,
+	}
+	if err := repo.AcceptInvitation(ctx, invitation, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateAdmin cria um administrador ativo na empresa informada, sem convite: é o primeiro acesso
+// de uma instalação nova ou de uma empresa recém-criada, feito pelo operador (erpctl)
+func CreateAdmin(input models.CreateAdminInput) (*models.User, error) {
+	input.Username, input.Email = strings.TrimSpace(input.Username), strings.TrimSpace(input.Email)
+	if len(input.Username) < 3 || !strings.Contains(input.Email, "@") {
+		return nil, errors.InvalidParam("informe o username (ao menos 3 caracteres) e um e-mail válido")
+	}
+	if err := ValidatePassword(input.Password); err != nil {
+		return nil, err
+	}
+	if input.CompanyID <= 0 {
+		input.CompanyID = tenant.DefaultCompanyID
+	}
+	if strings.TrimSpace(input.Nome) == "" {
+		input.Nome = input.Username
+	}
+
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	// Username e e-mail são únicos entre todas as empresas
+	ctx := tenant.WithCompany(context.Background(), input.CompanyID)
+	usernameTaken, emailTaken, err := repo.Exists(tenant.AllCompanies(ctx), input.Username, input.Email)
+	if err != nil {
+		return nil, err
+	}
+	if usernameTaken {
+		return nil, errors.ErrUsernameTaken
+	}
+	if emailTaken {
+		return nil, errors.ErrEmailTaken
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user := &models.User{
+		CompanyID:         input.CompanyID,
+		Username:          input.Username,
+		Password:          string(hashed),
+		Email:             input.Email,
+		Nome:              input.Nome,
+		Role:              models.RoleAdmin,
+		Active:            true,
+		PasswordChangedAt: &now,
+	}
+	if err := repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	logger.WithModule("user_service").Info("administrador criado", zap.Int("id", user.ID),
+		zap.String("username", user.Username), zap.Int("company_id", user.CompanyID))
+	return user, nil
+}
+
+// RequestPasswordReset envia o link de redefinição de senha. E-mails desconhecidos ou de contas
+// desativadas não geram erro, para que a resposta não revele quais e-mails estão cadastrados.
+func RequestPasswordReset(email string) error {
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return err
+	}
+
+	ctx := tenant.AllCompanies(context.Background())
+	user, err := repo.FindByEmail(ctx, email)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.Active {
+		logger.WithModule("user_service").Info("redefinição de senha ignorada para usuário desativado",
+			zap.Int("user_id", user.ID))
+		return nil
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return err
+	}
+	resetToken := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl("PASSWORD_RESET_TTL", defaultPasswordResetTTL)),
+	}
+	if err := repo.CreateResetToken(ctx, resetToken); err != nil {
+		return err
+	}
+
+	message, err := templateService.Render(tenant.WithCompany(ctx, user.CompanyID), templates.KeyPasswordResetEmail,
+		templates.Data{Vars: map[string]interface{}{
+			"name":       user.Nome,
+			"username":   user.Username,
+			"expires_at": resetToken.ExpiresAt,
+			"link":       frontendLink("/redefinir-senha", token),
+		}})
+	if err != nil {
+		return err
+	}
+	return mailer.Send(mailer.Message{To: user.Email, Subject: message.Subject, Body: message.Body})
+}
+
+// ResetPassword grava a nova senha a partir do token recebido por e-mail e desbloqueia a conta
+func ResetPassword(input models.ResetPasswordInput) error {
+	if err := ValidatePassword(input.Password); err != nil {
+		return err
+	}
+
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return err
+	}
+
+	ctx := tenant.AllCompanies(context.Background())
+	token, err := repo.FindResetToken(ctx, hashToken(input.Token))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return errors.ErrInvalidUserToken
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return repo.ResetPassword(ctx, token, string(hashed), now)
+}
+
+// SetUserActive ativa ou desativa a conta; contas desativadas não conseguem fazer login
+func SetUserActive(ctx context.Context, id int, active bool, actor string) (*models.User, error) {
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+	user, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !active && strings.EqualFold(user.Username, actor) {
+		return nil, errors.ErrCannotChangeSelf
+	}
+
+	if err := repo.Update(ctx, id, map[string]interface{}{"active": active}); err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+// AssignRole define o perfil de acesso usado pelo RBACMiddleware, válido a partir do próximo login
+func AssignRole(ctx context.Context, id int, role string, actor string) (*models.User, error) {
+	role, ok := models.NormalizeRole(role)
+	if !ok {
+		return nil, errors.ErrInvalidRole
+	}
+
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+	user, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.RoleAdmin && strings.EqualFold(user.Username, actor) {
+		return nil, errors.ErrCannotChangeSelf
+	}
+
+	if err := repo.Update(ctx, id, map[string]interface{}{"cargo": role}); err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+// UnlockUser remove o bloqueio por tentativas de login sem sucesso
+func UnlockUser(ctx context.Context, id int) (*models.User, error) {
+	repo, err := repository.NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Update(ctx, id, map[string]interface{}{"failed_login_attempts": 0, "locked_until": nil}); err != nil {
+		return nil, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+// ValidatePassword aplica a política de senha: ao menos 8 caracteres, com letras e números
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < minPasswordLength {
+		return errors.ErrWeakPassword
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errors.ErrWeakPassword
+	}
+	return nil
+}
+
+// newToken gera o token enviado por e-mail e o hash que é gravado no banco
+func newToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("falha ao gerar token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+func ttl(key string, fallback time.Duration) time.Duration {
+	if d := viper.GetDuration(key); d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// frontendLink monta o link do front-end (FRONTEND_URL) com o token
+func frontendLink(path, token string) string {
+	return strings.TrimRight(viper.GetString("FRONTEND_URL"), "/") + path + "?token=" + token
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/users/models"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePassword(t *testing.T) {
+	for _, password := range []string{"", "abc123", "somenteletras", "1234567890"} {
+		assert.ErrorIs(t, ValidatePassword(password), errors.ErrWeakPassword, password)
+	}
+	assert.NoError(t, ValidatePassword("senhaForte1"))
+	assert.NoError(t, ValidatePassword("ação2024"))
+}
+
+func TestNewTokenStoresOnlyTheHash(t *testing.T) {
+	token, hash, err := newToken()
+	require.NoError(t, err)
+
+	assert.Len(t, token, 43)
+	assert.Len(t, hash, 64)
+	assert.NotContains(t, hash, token)
+	assert.Equal(t, hash, hashToken(token))
+	assert.Equal(t, hash, hashToken(" "+token+"\n"), "espaços copiados junto com o link são ignorados")
+
+	other, _, err := newToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestNormalizeRole(t *testing.T) {
+	role, ok := models.NormalizeRole(" Finance_User ")
+	assert.True(t, ok)
+	assert.Equal(t, models.RoleFinance, role)
+
+	_, ok = models.NormalizeRole("superuser")
+	assert.False(t, ok)
+}
+
+func TestUserIsLocked(t *testing.T) {
+	now := time.Now()
+	until := now.Add(time.Minute)
+	user := models.User{LockedUntil: &until}
+
+	assert.True(t, user.IsLocked(now))
+	assert.False(t, user.IsLocked(until.Add(time.Second)))
+	assert.False(t, (&models.User{}).IsLocked(now))
+}
+
+func TestLinksAndTTL(t *testing.T) {
+	viper.Set("FRONTEND_URL", "https://erp.onsmart.com/")
+	viper.Set("USER_INVITE_TTL", "48h")
+	t.Cleanup(func() {
+		viper.Set("FRONTEND_URL", nil)
+		viper.Set("USER_INVITE_TTL", nil)
+	})
+
+	assert.Equal(t, "https://erp.onsmart.com/convite?token=abc", frontendLink("/convite", "abc"))
+	assert.Equal(t, 48*time.Hour, ttl("USER_INVITE_TTL", defaultInviteTTL))
+	assert.Equal(t, defaultPasswordResetTTL, ttl("PASSWORD_RESET_TTL", defaultPasswordResetTTL))
+}
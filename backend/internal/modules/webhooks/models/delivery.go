@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Origem do webhook recebido
+const (
+	SourcePix     = "pix"
+	SourceCarrier = "carrier"
+)
+
+// Situação do webhook recebido
+const (
+	StatusReceived  = "received"
+	StatusProcessed = "processed"
+	StatusFailed    = "failed"
+)
+
+// Quantidade padrão de webhooks reprocessados por execução
+const DefaultReplayLimit = 100
+
+// WebhookDelivery é um webhook recebido de um parceiro, gravado depois de conferida a assinatura.
+// Provider identifica o parceiro dentro da origem (a transportadora, por exemplo).
+type WebhookDelivery struct {
+	ID          int        `json:"id" gorm:"primaryKey"`
+	Source      string     `json:"source"`
+	Provider    string     `json:"provider"`
+	Payload     []byte     `json:"-"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error"`
+	ReceivedAt  time.Time  `json:"received_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// ReplayFilter seleciona os webhooks com falha a reprocessar: um webhook pelo ID ou os de uma
+// origem recebidos a partir de Since, dos mais antigos aos mais novos, até Limit
+type ReplayFilter struct {
+	ID     int
+	Source string
+	Since  time.Time
+	Limit  int
+}
+
+// ReplayFailure é um webhook que falhou de novo no reprocessamento
+type ReplayFailure struct {
+	DeliveryID int    `json:"delivery_id"`
+	Error      string `json:"error"`
+}
+
+// ReplaySummary resume o reprocessamento dos webhooks com falha
+type ReplaySummary struct {
+	Replayed  int             `json:"replayed"`
+	Processed int             `json:"processed"`
+	Failures  []ReplayFailure `json:"failures"`
+}
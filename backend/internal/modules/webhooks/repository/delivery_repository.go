@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/webhooks/models"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DeliveryRepository define as operações dos webhooks recebidos
+type DeliveryRepository interface {
+	Create(ctx context.Context, delivery *models.WebhookDelivery) error
+	Finish(ctx context.Context, id int, processErr error, now time.Time) error
+	ListFailed(ctx context.Context, filter models.ReplayFilter) ([]models.WebhookDelivery, error)
+}
+
+type deliveryRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewDeliveryRepository cria uma nova instância do repositório
+func NewDeliveryRepository() (DeliveryRepository, error) {
+	db, err := db.OpenGormDB()
+	if err != nil {
+		return nil, errors.WrapError(err, "falha ao abrir conexão com o banco")
+	}
+
+	return &deliveryRepository{
+		db:     db,
+		logger: logger.WithModule("webhook_delivery_repository"),
+	}, nil
+}
+
+// Create grava o webhook recebido, ainda não processado
+func (r *deliveryRepository) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.Status = models.StatusReceived
+	if err := db.Conn(ctx, r.db).Create(delivery).Error; err != nil {
+		r.logger.Error("erro ao gravar webhook recebido", zap.Error(err), zap.String("source", delivery.Source))
+		return errors.WrapError(err, "falha ao gravar webhook recebido")
+	}
+	return nil
+}
+
+// Finish registra uma tentativa de processamento: sem erro o webhook fica processado, com erro
+// fica com falha e a mensagem do erro
+func (r *deliveryRepository) Finish(ctx context.Context, id int, processErr error, now time.Time) error {
+	updates := map[string]interface{}{
+		"status":       models.StatusProcessed,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   "",
+		"processed_at": now,
+	}
+	if processErr != nil {
+		updates["status"] = models.StatusFailed
+		updates["last_error"] = processErr.Error()
+		delete(updates, "processed_at")
+	}
+
+	if err := db.Conn(ctx, r.db).Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		r.logger.Error("erro ao registrar processamento do webhook", zap.Error(err), zap.Int("id", id))
+		return errors.WrapError(err, "falha ao registrar processamento do webhook")
+	}
+	return nil
+}
+
+// ListFailed lista os webhooks com falha do filtro, dos mais antigos aos mais novos
+func (r *deliveryRepository) ListFailed(ctx context.Context, filter models.ReplayFilter) ([]models.WebhookDelivery, error) {
+	query := db.Conn(ctx, r.db).Where("status = ?", models.StatusFailed)
+	if filter.ID > 0 {
+		query = query.Where("id = ?", filter.ID)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("received_at >= ?", filter.Since)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("received_at ASC, id ASC").Limit(filter.Limit).Find(&deliveries).Error; err != nil {
+		r.logger.Error("erro ao listar webhooks com falha", zap.Error(err))
+		return nil, errors.WrapError(err, "falha ao listar webhooks com falha")
+	}
+	return deliveries, nil
+}
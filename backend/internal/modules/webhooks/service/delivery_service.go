@@ -0,0 +1,89 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/logger"
+	"ERP-ONSMART/backend/internal/modules/webhooks/models"
+	"ERP-ONSMART/backend/internal/modules/webhooks/repository"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newDeliveryRepository abre o repositório dos webhooks recebidos; substituído nos testes
+var newDeliveryRepository = repository.NewDeliveryRepository
+
+// Processor processa de novo o conteúdo de um webhook gravado, já com a assinatura conferida
+type Processor func(ctx context.Context, delivery *models.WebhookDelivery) error
+
+// Receive grava o webhook recebido, processa com process e registra o resultado. Um webhook que
+// não pôde ser gravado não é processado: o parceiro recebe o erro e reenvia. O erro de process
+// volta para quem recebeu o webhook e fica gravado para o reprocessamento.
+func Receive(ctx context.Context, source, provider string, payload []byte, process func(ctx context.Context) error) error {
+	repo, err := newDeliveryRepository()
+	if err != nil {
+		return err
+	}
+
+	delivery := &models.WebhookDelivery{Source: source, Provider: provider, Payload: payload, ReceivedAt: time.Now()}
+	if err := repo.Create(ctx, delivery); err != nil {
+		return err
+	}
+
+	processErr := process(ctx)
+	if err := repo.Finish(ctx, delivery.ID, processErr, time.Now()); err != nil {
+		logger.WithModule("webhook_service").Warn("resultado do webhook não registrado",
+			zap.Error(err), zap.Int("delivery_id", delivery.ID), zap.NamedError("process_error", processErr))
+	}
+	return processErr
+}
+
+// Replay processa de novo os webhooks com falha do filtro, cada um pelo processador da sua
+// origem, e registra o novo resultado. Uma falha no reprocessamento entra no resumo e não
+// interrompe os demais.
+func Replay(ctx context.Context, filter models.ReplayFilter, processors map[string]Processor) (*models.ReplaySummary, error) {
+	if filter.Source != "" {
+		if _, ok := processors[filter.Source]; !ok {
+			return nil, errors.ErrUnknownWebhookSource
+		}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = models.DefaultReplayLimit
+	}
+
+	repo, err := newDeliveryRepository()
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := repo.ListFailed(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.ReplaySummary{Failures: make([]models.ReplayFailure, 0)}
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return summary, ctx.Err()
+		}
+
+		delivery := &deliveries[i]
+		process, ok := processors[delivery.Source]
+		if !ok {
+			summary.Failures = append(summary.Failures, models.ReplayFailure{DeliveryID: delivery.ID, Error: errors.ErrUnknownWebhookSource.Error()})
+			continue
+		}
+
+		summary.Replayed++
+		processErr := process(ctx, delivery)
+		if err := repo.Finish(ctx, delivery.ID, processErr, time.Now()); err != nil {
+			return summary, err
+		}
+		if processErr != nil {
+			summary.Failures = append(summary.Failures, models.ReplayFailure{DeliveryID: delivery.ID, Error: processErr.Error()})
+			continue
+		}
+		summary.Processed++
+	}
+	return summary, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"ERP-ONSMART/backend/internal/errors"
+	"ERP-ONSMART/backend/internal/modules/webhooks/models"
+	"ERP-ONSMART/backend/internal/modules/webhooks/repository"
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDeliveryRepository guarda os webhooks recebidos em memória
+type fakeDeliveryRepository struct {
+	deliveries []models.WebhookDelivery
+}
+
+func (f *fakeDeliveryRepository) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.ID = len(f.deliveries) + 1
+	delivery.Status = models.StatusReceived
+	f.deliveries = append(f.deliveries, *delivery)
+	return nil
+}
+
+func (f *fakeDeliveryRepository) Finish(ctx context.Context, id int, processErr error, now time.Time) error {
+	delivery := &f.deliveries[id-1]
+	delivery.Attempts++
+	delivery.Status, delivery.LastError, delivery.ProcessedAt = models.StatusProcessed, "", &now
+	if processErr != nil {
+		delivery.Status, delivery.LastError, delivery.ProcessedAt = models.StatusFailed, processErr.Error(), nil
+	}
+	return nil
+}
+
+func (f *fakeDeliveryRepository) ListFailed(ctx context.Context, filter models.ReplayFilter) ([]models.WebhookDelivery, error) {
+	var failed []models.WebhookDelivery
+	for _, delivery := range f.deliveries {
+		if delivery.Status != models.StatusFailed || (filter.ID > 0 && delivery.ID != filter.ID) ||
+			(filter.Source != "" && delivery.Source != filter.Source) {
+			continue
+		}
+		if len(failed) < filter.Limit {
+			failed = append(failed, delivery)
+		}
+	}
+	return failed, nil
+}
+
+func useFakeDeliveries(t *testing.T) *fakeDeliveryRepository {
+	repo := &fakeDeliveryRepository{}
+	original := newDeliveryRepository
+	t.Cleanup(func() { newDeliveryRepository = original })
+	newDeliveryRepository = func() (repository.DeliveryRepository, error) { return repo, nil }
+	return repo
+}
+
+func TestReceiveRecordsResult(t *testing.T) {
+	repo := useFakeDeliveries(t)
+	failure := stderrors.New("banco indisponível")
+
+	require.NoError(t, Receive(context.Background(), models.SourcePix, "", []byte(`{"pix":[]}`), func(ctx context.Context) error { return nil }))
+	err := Receive(context.Background(), models.SourceCarrier, "webhook", []byte(`{}`), func(ctx context.Context) error { return failure })
+	assert.Equal(t, failure, err, "o erro volta para o parceiro")
+
+	require.Len(t, repo.deliveries, 2)
+	assert.Equal(t, models.StatusProcessed, repo.deliveries[0].Status)
+	assert.NotNil(t, repo.deliveries[0].ProcessedAt)
+	assert.Equal(t, models.StatusFailed, repo.deliveries[1].Status)
+	assert.Equal(t, "webhook", repo.deliveries[1].Provider)
+	assert.Equal(t, "banco indisponível", repo.deliveries[1].LastError)
+	assert.Equal(t, []byte(`{}`), repo.deliveries[1].Payload)
+}
+
+func TestReplay(t *testing.T) {
+	repo := useFakeDeliveries(t)
+	failure := stderrors.New("cobrança não encontrada")
+	for _, source := range []string{models.SourcePix, models.SourceCarrier, models.SourcePix} {
+		Receive(context.Background(), source, "", []byte(source), func(ctx context.Context) error { return failure })
+	}
+
+	var replayed []int
+	processors := map[string]Processor{
+		models.SourcePix: func(ctx context.Context, delivery *models.WebhookDelivery) error {
+			replayed = append(replayed, delivery.ID)
+			if delivery.ID == 3 {
+				return failure
+			}
+			return nil
+		},
+	}
+
+	summary, err := Replay(context.Background(), models.ReplayFilter{Source: models.SourcePix}, processors)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, replayed)
+	assert.Equal(t, 2, summary.Replayed)
+	assert.Equal(t, 1, summary.Processed)
+	assert.Equal(t, []models.ReplayFailure{{DeliveryID: 3, Error: "cobrança não encontrada"}}, summary.Failures)
+	assert.Equal(t, models.StatusProcessed, repo.deliveries[0].Status)
+	assert.Equal(t, 2, repo.deliveries[2].Attempts)
+
+	summary, err = Replay(context.Background(), models.ReplayFilter{}, processors)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Replayed, "só o webhook com processador")
+	assert.Equal(t, []models.ReplayFailure{
+		{DeliveryID: 2, Error: errors.ErrUnknownWebhookSource.Error()},
+		{DeliveryID: 3, Error: "cobrança não encontrada"},
+	}, summary.Failures)
+
+	_, err = Replay(context.Background(), models.ReplayFilter{Source: "boleto"}, processors)
+	assert.Equal(t, errors.ErrUnknownWebhookSource, err)
+}
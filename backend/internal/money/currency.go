@@ -0,0 +1,41 @@
+package money
+
+import "strings"
+
+// Currency é o código ISO 4217 da moeda
+type Currency string
+
+// Moedas com regra de arredondamento conhecida
+const (
+	BRL Currency = "BRL"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	ARS Currency = "ARS"
+	CLP Currency = "CLP"
+	PYG Currency = "PYG"
+	JPY Currency = "JPY"
+)
+
+// Default é a moeda dos documentos (pedidos, faturas, contas a pagar)
+const Default = BRL
+
+// places são as casas decimais de cada moeda; as demais usam 2
+var places = map[Currency]int{
+	CLP: 0,
+	PYG: 0,
+	JPY: 0,
+}
+
+// Places retorna as casas decimais em que os valores da moeda são arredondados
+func (c Currency) Places() int {
+	if p, ok := places[Currency(strings.ToUpper(string(c)))]; ok {
+		return p
+	}
+	return 2
+}
+
+// Round arredonda o valor para as casas da moeda padrão, a regra dos totais dos documentos
+func Round(d Decimal) Decimal {
+	return d.RoundTo(Default)
+}
@@ -0,0 +1,544 @@
+// Package money representa valores monetários em ponto fixo. Decimal guarda o valor como um
+// inteiro de décimos de milésimo (4 casas), então somas e subtrações são exatas e os
+// arredondamentos acontecem só onde a regra de negócio pede (Round, RoundTo), ao contrário de
+// float64, em que 0.1 + 0.2 != 0.3 e os totais acumulam diferenças de centavos.
+//
+// As colunas de valores são NUMERIC: Decimal é gravado e lido como texto decimal, sem passar
+// por float64. No JSON sai como número (150.5 → 150.50).
+//
+// O intervalo representável é de ±922.337.203.685.477,5807 (int64 em décimos de milésimo). Parse,
+// FromFloat, UnmarshalJSON e Scan recusam valores fora dele com ErrOverflow e entradas que não são
+// números com ErrInvalid; as operações aritméticas que ultrapassam o intervalo entram em pânico
+// com ErrOverflow em vez de voltar com o sinal trocado. Os cálculos sobre valores externos
+// convertem esse pânico em erro com Recover.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale é a quantidade de casas decimais guardadas; preços unitários e rateios usam as 4 casas,
+// os totais dos documentos são arredondados para as casas da moeda
+const Scale = 4
+
+const unit = 10000 // 10^Scale
+
+// Decimal é um valor monetário com Scale casas decimais. O valor zero é 0.
+type Decimal struct {
+	units int64
+}
+
+// Zero é o valor 0
+var Zero = Decimal{}
+
+// ErrOverflow indica um valor fora do intervalo representável por Decimal
+var ErrOverflow = errors.New("money: valor fora do intervalo suportado")
+
+// RoundingMode é a regra de arredondamento das conversões por taxa (MulRate, DivRate)
+type RoundingMode int
+
+const (
+	// HalfAwayFromZero arredonda a metade para longe do zero (2.345 → 2.35), a regra de Round
+	HalfAwayFromZero RoundingMode = iota
+	// HalfEven arredonda a metade para o par (2.345 → 2.34, 2.355 → 2.36), o arredondamento bancário
+	HalfEven
+)
+
+// ErrInvalid indica uma entrada que não é um valor monetário (texto vazio ou malformado, NaN ou
+// infinito)
+var ErrInvalid = errors.New("money: valor inválido")
+
+// New monta o valor a partir do inteiro value e do expoente decimal exp: New(12345, -2) é
+// 123.45. Casas além de Scale são arredondadas. Como FromInt e FromCents, serve para valores que o
+// código já sabe estarem no intervalo e entra em pânico com ErrOverflow fora dele; a entrada
+// externa passa por Parse ou FromFloat.
+func New(value int64, exp int) Decimal {
+	switch {
+	case exp == -Scale:
+		return Decimal{units: value}
+	case exp > -Scale:
+		return Decimal{units: mulUnits(value, pow10(exp+Scale))}
+	default:
+		return Decimal{units: mustDivRound(big.NewInt(value), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exp-Scale)), nil))}
+	}
+}
+
+// FromInt converte um número inteiro
+func FromInt(value int64) Decimal {
+	return Decimal{units: mulUnits(value, unit)}
+}
+
+// FromCents converte um valor em centavos
+func FromCents(cents int64) Decimal {
+	return Decimal{units: mulUnits(cents, unit/100)}
+}
+
+// FromFloat converte um float64 arredondando para Scale casas, pela representação decimal mais
+// curta do float, o que evita levar o erro binário (2.675 → 2.67499...). Serve para os valores
+// que ainda chegam como float (pedidos das lojas, cotações de frete, quantidades lidas de notas);
+// NaN e infinito retornam ErrInvalid e valores fora do intervalo, ErrOverflow.
+func FromFloat(value float64) (Decimal, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Zero, fmt.Errorf("%w: %v", ErrInvalid, value)
+	}
+	return Parse(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// Parse lê o valor em notação decimal ("1234.5", "-0.01", "1e3"); casas além de Scale são
+// arredondadas. Texto vazio ou malformado retorna ErrInvalid e valores fora do intervalo,
+// ErrOverflow.
+func Parse(text string) (Decimal, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Zero, fmt.Errorf("%w: vazio", ErrInvalid)
+	}
+	rat, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return Zero, fmt.Errorf("%w: %q", ErrInvalid, text)
+	}
+	scaled := new(big.Int).Mul(rat.Num(), big.NewInt(unit))
+	units, ok := divRound(scaled, rat.Denom())
+	if !ok {
+		return Zero, fmt.Errorf("%w: %q", ErrOverflow, text)
+	}
+	return Decimal{units: units}, nil
+}
+
+// MustParse é Parse para constantes do código; entra em pânico com um valor inválido
+func MustParse(text string) Decimal {
+	d, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Sum soma os valores
+func Sum(values ...Decimal) Decimal {
+	var total Decimal
+	for _, v := range values {
+		total.units = addUnits(total.units, v.units)
+	}
+	return total
+}
+
+// Add retorna d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{units: addUnits(d.units, other.units)}
+}
+
+// Sub retorna d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	return Decimal{units: subUnits(d.units, other.units)}
+}
+
+// Neg retorna -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: subUnits(0, d.units)}
+}
+
+// Abs retorna o valor absoluto
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Mul retorna d × other, arredondado para Scale casas
+func (d Decimal) Mul(other Decimal) Decimal {
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(other.units))
+	return Decimal{units: mustDivRound(product, big.NewInt(unit))}
+}
+
+// MulInt retorna d × n (quantidade × preço unitário)
+func (d Decimal) MulInt(n int) Decimal {
+	return Decimal{units: mulUnits(d.units, int64(n))}
+}
+
+// MulFloat retorna d × factor, para percentuais e taxas que ainda são float64. Como as demais
+// operações, entra em pânico (ErrInvalid ou ErrOverflow) com um fator que não cabe em Decimal.
+func (d Decimal) MulFloat(factor float64) Decimal {
+	f, err := FromFloat(factor)
+	if err != nil {
+		panic(err)
+	}
+	return d.Mul(f)
+}
+
+// Div retorna d ÷ other, arredondado para Scale casas; divisão por zero retorna zero
+func (d Decimal) Div(other Decimal) Decimal {
+	if other.units == 0 {
+		return Zero
+	}
+	dividend := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(unit))
+	return Decimal{units: mustDivRound(dividend, big.NewInt(other.units))}
+}
+
+// DivInt retorna d ÷ n, arredondado para Scale casas; divisão por zero retorna zero
+func (d Decimal) DivInt(n int) Decimal {
+	if n == 0 {
+		return Zero
+	}
+	return Decimal{units: mustDivRound(big.NewInt(d.units), big.NewInt(int64(n)))}
+}
+
+// Prorate retorna a parte de d proporcional a part/total (rateio por quantidade), arredondada
+// para Scale casas; total zero retorna zero
+func (d Decimal) Prorate(part, total int) Decimal {
+	if total == 0 {
+		return Zero
+	}
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(int64(part)))
+	return Decimal{units: mustDivRound(product, big.NewInt(int64(total)))}
+}
+
+// MulRate retorna d × rate com places casas (0 a Scale), arredondadas por mode. A taxa (câmbio,
+// com até 8 casas) entra pela sua representação decimal mais curta e o produto é exato até o
+// arredondamento final, sem passar por float64. Como as demais operações, entra em pânico com
+// taxa NaN ou infinita e com resultado fora do intervalo.
+func (d Decimal) MulRate(rate float64, places int, mode RoundingMode) Decimal {
+	value := new(big.Rat).SetFrac(big.NewInt(d.units), big.NewInt(unit))
+	return fromRat(value.Mul(value, rateRat(rate)), places, mode)
+}
+
+// DivRate retorna d ÷ rate com places casas, arredondadas por mode como em MulRate; taxa zero
+// retorna zero
+func (d Decimal) DivRate(rate float64, places int, mode RoundingMode) Decimal {
+	r := rateRat(rate)
+	if r.Sign() == 0 {
+		return Zero
+	}
+	value := new(big.Rat).SetFrac(big.NewInt(d.units), big.NewInt(unit))
+	return fromRat(value.Quo(value, r), places, mode)
+}
+
+// Round arredonda para places casas (0 a Scale), com metade afastando do zero (2.345 → 2.35,
+// -2.345 → -2.35), a regra usual dos valores monetários
+func (d Decimal) Round(places int) Decimal {
+	if places >= Scale {
+		return d
+	}
+	if places < 0 {
+		places = 0
+	}
+	step := pow10(Scale - places)
+	return Decimal{units: mulUnits(mustDivRound(big.NewInt(d.units), big.NewInt(step)), step)}
+}
+
+// RoundTo arredonda para as casas decimais da moeda
+func (d Decimal) RoundTo(currency Currency) Decimal {
+	return d.Round(currency.Places())
+}
+
+// Cmp compara d com other: -1 se menor, 0 se igual, 1 se maior
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Equal indica se os valores são iguais
+func (d Decimal) Equal(other Decimal) bool {
+	return d.units == other.units
+}
+
+// GreaterThan indica se d > other
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.units > other.units
+}
+
+// GreaterThanOrEqual indica se d >= other
+func (d Decimal) GreaterThanOrEqual(other Decimal) bool {
+	return d.units >= other.units
+}
+
+// LessThan indica se d < other
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.units < other.units
+}
+
+// LessThanOrEqual indica se d <= other
+func (d Decimal) LessThanOrEqual(other Decimal) bool {
+	return d.units <= other.units
+}
+
+// IsZero indica se o valor é zero
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// IsPositive indica se o valor é maior que zero
+func (d Decimal) IsPositive() bool {
+	return d.units > 0
+}
+
+// IsNegative indica se o valor é menor que zero
+func (d Decimal) IsNegative() bool {
+	return d.units < 0
+}
+
+// Sign retorna -1, 0 ou 1 conforme o sinal do valor
+func (d Decimal) Sign() int {
+	return d.Cmp(Zero)
+}
+
+// Min retorna o menor dos valores
+func Min(a, b Decimal) Decimal {
+	if b.units < a.units {
+		return b
+	}
+	return a
+}
+
+// Max retorna o maior dos valores
+func Max(a, b Decimal) Decimal {
+	if b.units > a.units {
+		return b
+	}
+	return a
+}
+
+// Float64 converte para float64, para cálculos que não são valores monetários (percentuais,
+// médias de indicadores) e para bibliotecas que exigem float
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / unit
+}
+
+// Cents retorna o valor em centavos, arredondado
+func (d Decimal) Cents() int64 {
+	return d.Round(2).units / (unit / 100)
+}
+
+// String formata o valor com ao menos duas casas e sem zeros à direita além delas
+// ("150.50", "0.00", "1.2345")
+func (d Decimal) String() string {
+	return d.StringFixed(d.places())
+}
+
+// StringFixed formata o valor com exatamente places casas (0 a Scale), arredondando
+func (d Decimal) StringFixed(places int) string {
+	if places > Scale {
+		places = Scale
+	}
+	if places < 0 {
+		places = 0
+	}
+	rounded := d.Round(places).units
+	sign := ""
+	if rounded < 0 {
+		sign = "-"
+		rounded = -rounded
+	}
+	integer, fraction := rounded/unit, rounded%unit
+	if places == 0 {
+		return sign + strconv.FormatInt(integer, 10)
+	}
+	digits := fmt.Sprintf("%04d", fraction)[:places]
+	return sign + strconv.FormatInt(integer, 10) + "." + digits
+}
+
+// places é a quantidade de casas do valor: 2, ou mais se houver casas significativas além delas
+func (d Decimal) places() int {
+	fraction := d.units % unit
+	places := Scale
+	for places > 2 && fraction%10 == 0 {
+		fraction /= 10
+		places--
+	}
+	return places
+}
+
+// MarshalJSON escreve o valor como número JSON
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON aceita número, texto com número ou null (zero); valores fora do intervalo
+// retornam ErrOverflow
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "null" {
+		*d = Zero
+		return nil
+	}
+	text = strings.Trim(text, `"`)
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value grava o valor como texto decimal, sem passar por float64
+func (d Decimal) Value() (driver.Value, error) {
+	return d.StringFixed(Scale), nil
+}
+
+// Scan lê colunas NUMERIC (texto), inteiras ou de ponto flutuante; NULL vira zero e valores fora
+// do intervalo retornam ErrOverflow
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+		return nil
+	case []byte:
+		return d.scanText(string(v))
+	case string:
+		return d.scanText(v)
+	case int64:
+		if v > math.MaxInt64/unit || v < math.MinInt64/unit {
+			return fmt.Errorf("%w: %d", ErrOverflow, v)
+		}
+		*d = FromInt(v)
+		return nil
+	case float64:
+		parsed, err := FromFloat(v)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	default:
+		return fmt.Errorf("money: tipo %T não suportado", src)
+	}
+}
+
+func (d *Decimal) scanText(text string) error {
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// rateRat converte a taxa pela representação decimal mais curta do float
+func rateRat(rate float64) *big.Rat {
+	if math.IsNaN(rate) || math.IsInf(rate, 0) {
+		panic(fmt.Errorf("%w: taxa %v", ErrInvalid, rate))
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	return r
+}
+
+// fromRat arredonda a fração para places casas (0 a Scale) por mode
+func fromRat(value *big.Rat, places int, mode RoundingMode) Decimal {
+	if places > Scale {
+		places = Scale
+	}
+	if places < 0 {
+		places = 0
+	}
+	scaled := new(big.Int).Mul(value.Num(), big.NewInt(pow10(places)))
+	rounded := quoRound(scaled, value.Denom(), mode)
+	rounded.Mul(rounded, big.NewInt(pow10(Scale-places)))
+	if !rounded.IsInt64() {
+		panic(ErrOverflow)
+	}
+	return Decimal{units: rounded.Int64()}
+}
+
+// Recover converte em erro o pânico das operações aritméticas (ErrOverflow ou ErrInvalid), para
+// os cálculos sobre valores externos recusarem a entrada em vez de derrubar o processo:
+//
+//	func importOrder(...) (err error) {
+//		defer money.Recover(&err)
+//
+// Outros pânicos seguem adiante.
+func Recover(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if e, ok := r.(error); ok && (errors.Is(e, ErrOverflow) || errors.Is(e, ErrInvalid)) {
+		*err = e
+		return
+	}
+	panic(r)
+}
+
+// divRound divide arredondando a metade para longe do zero; ok é false se o resultado não cabe
+// em int64
+func divRound(num, den *big.Int) (units int64, ok bool) {
+	quotient := quoRound(num, den, HalfAwayFromZero)
+	if !quotient.IsInt64() {
+		return 0, false
+	}
+	return quotient.Int64(), true
+}
+
+// quoRound divide num por den arredondando o quociente por mode
+func quoRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+	doubled := new(big.Int).Abs(remainder)
+	doubled.Lsh(doubled, 1)
+	half := doubled.Cmp(new(big.Int).Abs(den))
+	if half > 0 || half == 0 && (mode == HalfAwayFromZero || quotient.Bit(0) == 1) {
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+// mustDivRound é divRound para as operações aritméticas, que não retornam erro
+func mustDivRound(num, den *big.Int) int64 {
+	units, ok := divRound(num, den)
+	if !ok {
+		panic(ErrOverflow)
+	}
+	return units
+}
+
+func addUnits(a, b int64) int64 {
+	sum := a + b
+	if (sum > a) != (b > 0) {
+		panic(ErrOverflow)
+	}
+	return sum
+}
+
+func subUnits(a, b int64) int64 {
+	diff := a - b
+	if (diff < a) != (b > 0) {
+		panic(ErrOverflow)
+	}
+	return diff
+}
+
+func mulUnits(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		panic(ErrOverflow)
+	}
+	return product
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result = mulUnits(result, 10)
+	}
+	return result
+}
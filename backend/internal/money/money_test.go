@@ -0,0 +1,184 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSumIsExact(t *testing.T) {
+	// em float64, 0.1 + 0.2 = 0.30000000000000004
+	a, err := FromFloat(0.1)
+	require.NoError(t, err)
+	b, err := FromFloat(0.2)
+	require.NoError(t, err)
+	assert.Equal(t, "0.30", a.Add(b).String())
+
+	total := Zero
+	for i := 0; i < 1000; i++ {
+		total = total.Add(MustParse("0.01"))
+	}
+	assert.True(t, total.Equal(FromInt(10)))
+}
+
+func TestRoundHalfAwayFromZero(t *testing.T) {
+	cases := map[string]string{
+		"2.345":  "2.35",
+		"2.344":  "2.34",
+		"-2.345": "-2.35",
+		"2.675":  "2.68", // em float64, math.Round(2.675*100)/100 = 2.67
+		"0.005":  "0.01",
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, MustParse(input).Round(2).String(), input)
+	}
+	value, err := FromFloat(1.675)
+	require.NoError(t, err)
+	assert.Equal(t, "1.68", value.Round(2).String())
+}
+
+func TestRoundToCurrency(t *testing.T) {
+	value := MustParse("1234.567")
+	assert.Equal(t, "1234.57", value.RoundTo(BRL).String())
+	assert.Equal(t, "1235", value.RoundTo(JPY).StringFixed(0))
+	assert.Equal(t, 0, Currency("clp").Places())
+	assert.Equal(t, 2, Currency("XYZ").Places())
+}
+
+func TestArithmetic(t *testing.T) {
+	price := MustParse("19.99")
+	assert.Equal(t, "59.97", price.MulInt(3).String())
+	assert.Equal(t, "6.6633", price.DivInt(3).String())
+	assert.Equal(t, "2.40", price.Mul(MustParse("0.12")).Round(2).String())
+	assert.Equal(t, "13.3267", price.Prorate(2, 3).String())
+	assert.Equal(t, "2.00", MustParse("10").Div(MustParse("5")).String())
+	assert.True(t, MustParse("10").Div(Zero).IsZero())
+	assert.Equal(t, int64(1999), price.Cents())
+	assert.Equal(t, "1.23", New(123, -2).String())
+	assert.Equal(t, "1200.00", New(12, 2).String())
+	assert.Equal(t, "0.0001", New(5, -5).String())
+}
+
+func TestRateConversion(t *testing.T) {
+	// 1000 / 5.4 = 185.185185...; 1000 / 0.037 = 27027.027...
+	assert.Equal(t, "185.19", FromInt(1000).DivRate(5.4, 2, HalfAwayFromZero).String())
+	assert.Equal(t, "27027", FromInt(1000).DivRate(0.037, 0, HalfAwayFromZero).StringFixed(0))
+	assert.True(t, FromInt(1000).DivRate(0, 2, HalfAwayFromZero).IsZero())
+
+	// a taxa com 8 casas entra inteira: 1234.56 × 5.12345678 = 6325.2148...
+	assert.Equal(t, "6325.21", MustParse("1234.56").MulRate(5.12345678, 2, HalfAwayFromZero).String())
+	assert.Equal(t, "-6325.21", MustParse("-1234.56").MulRate(5.12345678, 2, HalfAwayFromZero).String())
+
+	half := MustParse("2.345")
+	assert.Equal(t, "2.35", half.MulRate(1, 2, HalfAwayFromZero).String())
+	assert.Equal(t, "2.34", half.MulRate(1, 2, HalfEven).String())
+	assert.Equal(t, "2.36", MustParse("2.355").MulRate(1, 2, HalfEven).String())
+	assert.Equal(t, "-2.34", half.Neg().MulRate(1, 2, HalfEven).String())
+
+	limit := MustParse("922337203685477.5807")
+	assert.PanicsWithValue(t, ErrOverflow, func() { limit.MulRate(5.4, 2, HalfAwayFromZero) })
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var payload struct {
+		Price Decimal  `json:"price"`
+		Total Decimal  `json:"total"`
+		Text  Decimal  `json:"text"`
+		Null  Decimal  `json:"null"`
+		Ptr   *Decimal `json:"ptr"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"price": 10.5, "total": 1e3, "text": "0.1", "null": null, "ptr": 2}`), &payload))
+	assert.Equal(t, "10.50", payload.Price.String())
+	assert.Equal(t, "1000.00", payload.Total.String())
+	assert.Equal(t, "0.10", payload.Text.String())
+	assert.True(t, payload.Null.IsZero())
+	require.NotNil(t, payload.Ptr)
+
+	data, err := json.Marshal(map[string]Decimal{"price": MustParse("10.5"), "rate": MustParse("0.1234")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"price": 10.50, "rate": 0.1234}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"price": "abc"}`), &payload))
+}
+
+func TestSQLValueAndScan(t *testing.T) {
+	value, err := MustParse("-12.3").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "-12.3000", value)
+
+	var d Decimal
+	require.NoError(t, d.Scan([]byte("1500.75")))
+	assert.Equal(t, "1500.75", d.String())
+	require.NoError(t, d.Scan(int64(3)))
+	assert.Equal(t, "3.00", d.String())
+	require.NoError(t, d.Scan(0.1))
+	assert.Equal(t, "0.10", d.String())
+	require.NoError(t, d.Scan(nil))
+	assert.True(t, d.IsZero())
+	assert.Error(t, d.Scan(true))
+}
+
+func TestOverflow(t *testing.T) {
+	_, err := Parse("922337203685477.5807")
+	require.NoError(t, err)
+
+	_, err = Parse("922337203685477.5808")
+	assert.ErrorIs(t, err, ErrOverflow)
+	_, err = Parse("-1e20")
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	var d Decimal
+	assert.ErrorIs(t, json.Unmarshal([]byte(`1e30`), &d), ErrOverflow)
+	assert.ErrorIs(t, d.Scan([]byte("99999999999999999999")), ErrOverflow)
+	assert.ErrorIs(t, d.Scan(int64(1)<<62), ErrOverflow)
+
+	limit := MustParse("922337203685477.5807")
+	assert.PanicsWithValue(t, ErrOverflow, func() { limit.Add(MustParse("0.0001")) })
+	assert.PanicsWithValue(t, ErrOverflow, func() { limit.Neg().Sub(MustParse("0.0002")) })
+	assert.PanicsWithValue(t, ErrOverflow, func() { limit.MulInt(2) })
+	assert.PanicsWithValue(t, ErrOverflow, func() { FromInt(1 << 60) })
+	assert.PanicsWithValue(t, ErrOverflow, func() { limit.Mul(FromInt(2)) })
+}
+
+func TestInvalidInput(t *testing.T) {
+	_, err := Parse("")
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = Parse("12,50")
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	_, err = FromFloat(math.NaN())
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = FromFloat(math.Inf(-1))
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = FromFloat(1e16)
+	assert.ErrorIs(t, err, ErrOverflow)
+
+	var d Decimal
+	assert.ErrorIs(t, d.Scan(math.NaN()), ErrInvalid)
+	assert.PanicsWithError(t, "money: valor inválido: NaN", func() { FromInt(1).MulFloat(math.NaN()) })
+}
+
+func TestRecover(t *testing.T) {
+	overflow := func() (err error) {
+		defer Recover(&err)
+		MustParse("922337203685477.5807").MulInt(2)
+		return nil
+	}
+	assert.ErrorIs(t, overflow(), ErrOverflow)
+
+	invalid := func() (err error) {
+		defer Recover(&err)
+		FromInt(10).MulRate(math.Inf(1), 2, HalfAwayFromZero)
+		return nil
+	}
+	assert.ErrorIs(t, invalid(), ErrInvalid)
+
+	other := func() (err error) {
+		defer Recover(&err)
+		panic("outro erro")
+	}
+	assert.PanicsWithValue(t, "outro erro", func() { _ = other() })
+}
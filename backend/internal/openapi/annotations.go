@@ -0,0 +1,207 @@
+package openapi
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Annotation reúne a documentação de um handler: a primeira linha do comentário vira o
+// resumo e as anotações no formato do swaggo (@Summary, @Param, ...) complementam a operação
+type Annotation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Params      []Parameter
+	SuccessCode int
+	SuccessDesc string
+	Accept      string
+	Produce     string
+	Security    bool
+	Scheme      string
+
+	// Factory indica funções que devolvem um gin.HandlerFunc; só a documentação delas
+	// vale para as closures registradas nas rotas
+	Factory bool
+}
+
+// ParseAnnotations lê os comentários das funções de todos os pacotes abaixo de dir.
+// As chaves seguem o nome que o Gin atribui ao handler: "<import path>.<Função>".
+func ParseAnnotations(moduleRoot, dir string) (map[string]Annotation, error) {
+	modulePath, err := readModulePath(filepath.Join(moduleRoot, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	if moduleRoot, err = filepath.Abs(moduleRoot); err != nil {
+		return nil, err
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]Annotation{}
+	fset := token.NewFileSet()
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("erro ao ler %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(moduleRoot, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		importPath := modulePath + "/" + filepath.ToSlash(rel)
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Doc == nil {
+				continue
+			}
+			annotation := parseAnnotation(fn.Name.Name, fn.Doc.Text())
+			annotation.Factory = returnsHandlerFunc(fn)
+			annotations[importPath+"."+fn.Name.Name] = annotation
+		}
+		return nil
+	})
+	return annotations, err
+}
+
+func returnsHandlerFunc(fn *ast.FuncDecl) bool {
+	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+		return false
+	}
+	sel, ok := fn.Type.Results.List[0].Type.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "HandlerFunc"
+}
+
+func parseAnnotation(funcName, doc string) Annotation {
+	var a Annotation
+	var description []string
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "@") {
+			description = append(description, line)
+			continue
+		}
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		switch strings.ToLower(keyword) {
+		case "@summary":
+			a.Summary = rest
+		case "@description":
+			description = append(description, rest)
+		case "@tags":
+			for _, tag := range strings.Split(rest, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					a.Tags = append(a.Tags, tag)
+				}
+			}
+		case "@param":
+			if param, ok := parseParam(rest); ok {
+				a.Params = append(a.Params, param)
+			}
+		case "@success":
+			a.SuccessCode, a.SuccessDesc = parseSuccess(rest)
+		case "@accept":
+			a.Accept = rest
+		case "@produce":
+			a.Produce = rest
+		case "@security":
+			a.Security = true
+			if fields := strings.Fields(rest); len(fields) > 0 {
+				a.Scheme = fields[0]
+			}
+		}
+	}
+
+	if a.Summary == "" && len(description) > 0 {
+		a.Summary, description = description[0], description[1:]
+		// Comentários no estilo godoc ("ListTrashHandler lista ...") perdem o nome da função
+		if rest, ok := strings.CutPrefix(a.Summary, funcName+" "); ok && rest != "" {
+			a.Summary = strings.ToUpper(rest[:1]) + rest[1:]
+		}
+	}
+	a.Description = strings.Join(description, "\n")
+	return a
+}
+
+// parseParam interpreta `nome local tipo obrigatório "descrição"` (ex.: from query string false "data inicial")
+func parseParam(value string) (Parameter, bool) {
+	head, desc, _ := strings.Cut(value, `"`)
+	fields := strings.Fields(head)
+	if len(fields) < 4 {
+		return Parameter{}, false
+	}
+	required, _ := strconv.ParseBool(fields[3])
+	return Parameter{
+		Name:        fields[0],
+		In:          fields[1],
+		Required:    required || fields[1] == "path",
+		Description: strings.TrimSuffix(desc, `"`),
+		Schema:      schemaFor(fields[2]),
+	}, true
+}
+
+// parseSuccess interpreta `201 {object} Tipo "descrição"`; o tipo é opcional e ignorado
+func parseSuccess(value string) (int, string) {
+	head, desc, _ := strings.Cut(value, `"`)
+	fields := strings.Fields(head)
+	if len(fields) == 0 {
+		return 0, ""
+	}
+	code, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, ""
+	}
+	return code, strings.TrimSuffix(desc, `"`)
+}
+
+func schemaFor(typ string) Schema {
+	switch typ {
+	case "int", "integer":
+		return Schema{Type: "integer"}
+	case "number", "float", "float64":
+		return Schema{Type: "number"}
+	case "bool", "boolean":
+		return Schema{Type: "boolean"}
+	case "date":
+		return Schema{Type: "string", Format: "date"}
+	case "file":
+		return Schema{Type: "string", Format: "binary"}
+	default:
+		return Schema{Type: "string"}
+	}
+}
+
+func readModulePath(goMod string) (string, error) {
+	file, err := os.Open(goMod)
+	if err != nil {
+		return "", fmt.Errorf("erro ao abrir go.mod: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.TrimSpace(module), nil
+		}
+	}
+	return "", fmt.Errorf("declaração module não encontrada em %s", goMod)
+}
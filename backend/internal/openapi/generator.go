@@ -0,0 +1,267 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Versão da especificação e dados gerais da API
+const (
+	Version = "3.0.3"
+	Title   = "ERP Inteligente - On Smart Tech"
+
+	// integrationsPrefix agrupa as rotas autenticadas por chave de API (header X-API-Key)
+	integrationsPrefix = "/integrations/"
+)
+
+var (
+	pathParamPattern = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+	closureSuffix    = regexp.MustCompile(`(\.func\d+)+$`)
+)
+
+// Generate monta a especificação a partir das rotas registradas no Gin e da
+// documentação dos handlers (ver ParseAnnotations)
+func Generate(routes gin.RoutesInfo, annotations map[string]Annotation) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       Title,
+			Description: "Especificação gerada a partir das rotas de routes.SetupRoutes. Regenere com `make openapi`.",
+			Version:     "1.0.0",
+		},
+		Paths:      map[string]PathItem{},
+		Components: components(),
+	}
+
+	// Handlers reaproveitados em várias rotas (ex.: lixeira) recebem operationId derivado do caminho
+	handlerCount := map[string]int{}
+	for _, route := range routes {
+		if !isIntegration(route.Path) {
+			handlerCount[handlerName(route.Handler)]++
+		}
+	}
+
+	tags := map[string]bool{}
+	for _, route := range routes {
+		name := handlerName(route.Handler)
+		annotation := annotations[name]
+		if name != route.Handler && !annotation.Factory {
+			// Closure anônima declarada na própria rota (ex.: GET /ping)
+			annotation = Annotation{}
+		}
+		path := pathParamPattern.ReplaceAllString(route.Path, "{$1}")
+
+		op := &Operation{
+			Tags:        annotation.Tags,
+			Summary:     annotation.Summary,
+			Description: annotation.Description,
+			OperationID: operationID(route, name, handlerCount[name] > 1 || isIntegration(route.Path)),
+			Parameters:  parameters(route.Path, annotation.Params),
+			Responses:   responses(route.Method, annotation),
+		}
+		if len(op.Tags) == 0 {
+			op.Tags = []string{defaultTag(route.Path)}
+		}
+		if op.Summary == "" {
+			op.Summary = route.Method + " " + path
+		}
+		if annotation.Security {
+			scheme := annotation.Scheme
+			if scheme == "" {
+				scheme = "BearerAuth"
+			}
+			op.Security = []map[string][]string{{scheme: {}}}
+		}
+		// As rotas de integração reutilizam os handlers, mas autenticam pela chave de API
+		if isIntegration(route.Path) {
+			op.Tags = []string{"integrations"}
+			op.Security = []map[string][]string{{"ApiKeyAuth": {}}}
+		}
+		if hasBody(route.Method) {
+			op.RequestBody = requestBody(annotation.Accept)
+		}
+
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	return doc
+}
+
+// Marshal serializa a especificação de forma determinística (mapas com chaves ordenadas)
+func Marshal(doc *Document) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func isIntegration(path string) bool {
+	return strings.HasPrefix(path, integrationsPrefix)
+}
+
+// handlerName remove o sufixo das closures (.func1) para encontrar a função documentada
+func handlerName(handler string) string {
+	return closureSuffix.ReplaceAllString(handler, "")
+}
+
+func operationID(route gin.RouteInfo, name string, shared bool) string {
+	if !shared && !strings.Contains(route.Handler, ".func") {
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+	slug := strings.NewReplacer("/", "_", ":", "", "*", "", "-", "_").Replace(strings.Trim(route.Path, "/"))
+	if slug == "" {
+		slug = "root"
+	}
+	return strings.ToLower(route.Method) + "_" + slug
+}
+
+func defaultTag(path string) string {
+	segment := strings.SplitN(strings.Trim(path, "/"), "/", 2)[0]
+	if segment == "" || strings.HasPrefix(segment, ":") {
+		return "geral"
+	}
+	return segment
+}
+
+func parameters(path string, annotated []Parameter) []Parameter {
+	var params []Parameter
+	declared := map[string]bool{}
+	for _, p := range annotated {
+		declared[p.In+":"+p.Name] = true
+	}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		if declared["path:"+match[1]] {
+			continue
+		}
+		schema := Schema{Type: "string"}
+		if match[1] == "id" || strings.HasSuffix(match[1], "_id") {
+			schema = Schema{Type: "integer"}
+		}
+		params = append(params, Parameter{Name: match[1], In: "path", Required: true, Schema: schema})
+	}
+
+	for _, p := range annotated {
+		if p.In != "body" && p.In != "formData" {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+func hasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+func requestBody(accept string) *RequestBody {
+	if strings.Contains(accept, "multipart") || accept == "mpfd" {
+		return &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"multipart/form-data": {Schema: Schema{Type: "object"}}},
+		}
+	}
+	return &RequestBody{
+		Required: false,
+		Content:  map[string]MediaType{"application/json": {Schema: Schema{Type: "object"}}},
+	}
+}
+
+func responses(method string, annotation Annotation) map[string]Response {
+	code, desc := annotation.SuccessCode, annotation.SuccessDesc
+	if code == 0 {
+		code = http.StatusOK
+		if method == http.MethodPost {
+			code = http.StatusCreated
+		}
+	}
+	if desc == "" {
+		desc = http.StatusText(code)
+	}
+
+	content := map[string]MediaType{"application/json": {Schema: Schema{Type: "object"}}}
+	if mediaType := produceMediaType(annotation.Produce); mediaType != "application/json" {
+		content = map[string]MediaType{mediaType: {Schema: Schema{Type: "string"}}}
+	}
+
+	return map[string]Response{
+		strconv.Itoa(code): {Description: desc, Content: content},
+		"default":          {Ref: "#/components/responses/Error"},
+	}
+}
+
+// produceMediaType traduz os apelidos do swaggo (@Produce html) para o tipo de conteúdo
+func produceMediaType(produce string) string {
+	switch produce {
+	case "", "json":
+		return "application/json"
+	case "html":
+		return "text/html"
+	case "plain":
+		return "text/plain"
+	default:
+		return produce
+	}
+}
+
+// components descreve o envelope de erro padrão ({"error": {code, message, details, field_errors}})
+func components() Components {
+	fieldError := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"field":   {Type: "string"},
+			"message": {Type: "string"},
+		},
+	}
+	apiError := Schema{
+		Type:     "object",
+		Required: []string{"code", "message"},
+		Properties: map[string]Schema{
+			"code":         {Type: "string"},
+			"message":      {Type: "string"},
+			"details":      {Type: "string"},
+			"field_errors": {Type: "array", Items: &fieldError},
+		},
+	}
+
+	return Components{
+		Schemas: map[string]Schema{
+			"APIError": apiError,
+			"ErrorEnvelope": {
+				Type:       "object",
+				Required:   []string{"error"},
+				Properties: map[string]Schema{"error": {Ref: "#/components/schemas/APIError"}},
+			},
+		},
+		Responses: map[string]Response{
+			"Error": {
+				Description: "Erro no envelope padrão",
+				Content: map[string]MediaType{
+					"application/json": {Schema: Schema{Ref: "#/components/schemas/ErrorEnvelope"}},
+				},
+			},
+		},
+		SecuritySchemes: map[string]SecurityScheme{
+			"BearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			"ApiKeyAuth": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			// Token do portal do cliente (claim scope=portal), emitido em POST /portal/auth/token
+			"PortalAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	}
+}
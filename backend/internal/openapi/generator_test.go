@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAnnotation(t *testing.T) {
+	a := parseAnnotation("ListJournalEntriesHandler", `ListJournalEntriesHandler lista os lançamentos do período
+Inclui lançamentos manuais e automáticos
+@Tags contabilidade
+@Param from query date false "Data inicial"
+@Param id path int true "ID"
+@Success 200 {object} models.JournalEntry "Lançamentos"
+@Security BearerAuth
+`)
+
+	assert.Equal(t, "Lista os lançamentos do período", a.Summary)
+	assert.Equal(t, "Inclui lançamentos manuais e automáticos", a.Description)
+	assert.Equal(t, []string{"contabilidade"}, a.Tags)
+	require.Len(t, a.Params, 2)
+	assert.Equal(t, Parameter{Name: "from", In: "query", Description: "Data inicial", Schema: Schema{Type: "string", Format: "date"}}, a.Params[0])
+	assert.True(t, a.Params[1].Required)
+	assert.Equal(t, 200, a.SuccessCode)
+	assert.Equal(t, "Lançamentos", a.SuccessDesc)
+	assert.True(t, a.Security)
+	assert.Equal(t, "BearerAuth", a.Scheme)
+}
+
+func TestGenerate(t *testing.T) {
+	routes := gin.RoutesInfo{
+		{Method: "GET", Path: "/invoices/:id", Handler: "app/sales/handler.GetInvoiceHandler"},
+		{Method: "POST", Path: "/invoices/", Handler: "app/sales/handler.CreateInvoiceHandler"},
+		{Method: "GET", Path: "/invoices/trash", Handler: "app/trash/handler.ListTrashHandler.func1"},
+		{Method: "GET", Path: "/contacts/trash", Handler: "app/trash/handler.ListTrashHandler.func1"},
+		{Method: "GET", Path: "/ping", Handler: "app/routes.SetupRoutes.func2"},
+		{Method: "GET", Path: "/integrations/invoices/:id", Handler: "app/sales/handler.GetInvoiceHandler"},
+		{Method: "GET", Path: "/portal/invoices", Handler: "app/portal/handler.ListInvoicesHandler"},
+	}
+	annotations := map[string]Annotation{
+		"app/sales/handler.GetInvoiceHandler":    {Summary: "Retorna a fatura"},
+		"app/trash/handler.ListTrashHandler":     {Summary: "Lista a lixeira", Factory: true},
+		"app/routes.SetupRoutes":                 {Summary: "Configura as rotas"},
+		"app/portal/handler.ListInvoicesHandler": {Summary: "Lista as faturas do cliente", Security: true, Scheme: "PortalAuth"},
+	}
+
+	doc := Generate(routes, annotations)
+
+	get := doc.Paths["/invoices/{id}"]["get"]
+	require.NotNil(t, get)
+	assert.Equal(t, "GetInvoiceHandler", get.OperationID)
+	assert.Equal(t, "Retorna a fatura", get.Summary)
+	assert.Equal(t, []string{"invoices"}, get.Tags)
+	assert.Equal(t, []Parameter{{Name: "id", In: "path", Required: true, Schema: Schema{Type: "integer"}}}, get.Parameters)
+	assert.Contains(t, get.Responses, "200")
+	assert.Equal(t, "#/components/responses/Error", get.Responses["default"].Ref)
+
+	post := doc.Paths["/invoices/"]["post"]
+	require.NotNil(t, post)
+	assert.Contains(t, post.Responses, "201")
+	require.NotNil(t, post.RequestBody)
+
+	// Closures de fábricas documentadas reaproveitam o comentário e ganham operationId único
+	assert.Equal(t, "Lista a lixeira", doc.Paths["/contacts/trash"]["get"].Summary)
+	assert.Equal(t, "get_contacts_trash", doc.Paths["/contacts/trash"]["get"].OperationID)
+	assert.Equal(t, "get_invoices_trash", doc.Paths["/invoices/trash"]["get"].OperationID)
+
+	// Closures anônimas não herdam o comentário da função que registra as rotas
+	assert.Equal(t, "GET /ping", doc.Paths["/ping"]["get"].Summary)
+
+	// Rotas de integração reutilizam o handler, mas autenticam pela chave de API
+	integration := doc.Paths["/integrations/invoices/{id}"]["get"]
+	require.NotNil(t, integration)
+	assert.Equal(t, "Retorna a fatura", integration.Summary)
+	assert.Equal(t, []string{"integrations"}, integration.Tags)
+	assert.Equal(t, "get_integrations_invoices_id", integration.OperationID)
+	assert.Equal(t, []map[string][]string{{"ApiKeyAuth": {}}}, integration.Security)
+	assert.Contains(t, doc.Components.SecuritySchemes, "ApiKeyAuth")
+
+	// O esquema informado em @Security substitui o BearerAuth padrão
+	portal := doc.Paths["/portal/invoices"]["get"]
+	require.NotNil(t, portal)
+	assert.Equal(t, []map[string][]string{{"PortalAuth": {}}}, portal.Security)
+	assert.Contains(t, doc.Components.SecuritySchemes, "PortalAuth")
+
+	data, err := Marshal(doc)
+	require.NoError(t, err)
+	again, err := Marshal(Generate(routes, annotations))
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+}
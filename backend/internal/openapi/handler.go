@@ -0,0 +1,44 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Spec é a especificação gerada por `make openapi` (backend/cmd/openapi)
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Página do Swagger UI carregada da CDN, apontando para /openapi.json
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+  <meta charset="utf-8">
+  <title>` + Title + ` - API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
+
+// Retorna a especificação OpenAPI da API
+func SpecHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", Spec)
+}
+
+// Exibe a documentação interativa (Swagger UI)
+//
+// @Produce html
+func SwaggerUIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -0,0 +1,90 @@
+package openapi
+
+// Document é o subconjunto da especificação OpenAPI 3 gerado pela API
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	Tags       []Tag               `json:"tags,omitempty"`
+}
+
+// Info descreve a API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag agrupa as operações de um grupo de rotas
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem associa o método HTTP (em minúsculas) à operação
+type PathItem map[string]*Operation
+
+// Operation descreve uma rota registrada no Gin
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter descreve um parâmetro de rota, de consulta ou de header
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+	Schema      Schema `json:"schema"`
+}
+
+// RequestBody descreve o corpo aceito pela operação
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response descreve uma resposta da operação
+type Response struct {
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+	Ref         string               `json:"$ref,omitempty"`
+}
+
+// MediaType associa o tipo de conteúdo ao schema
+type MediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+// Schema é um schema JSON simplificado
+type Schema struct {
+	Ref        string            `json:"$ref,omitempty"`
+	Type       string            `json:"type,omitempty"`
+	Format     string            `json:"format,omitempty"`
+	Properties map[string]Schema `json:"properties,omitempty"`
+	Items      *Schema           `json:"items,omitempty"`
+	Required   []string          `json:"required,omitempty"`
+}
+
+// Components reúne os schemas, respostas e esquemas de segurança reutilizáveis
+type Components struct {
+	Schemas         map[string]Schema         `json:"schemas"`
+	Responses       map[string]Response       `json:"responses"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme descreve a autenticação da API
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
@@ -0,0 +1,329 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validator confere requisições e respostas com a especificação, para os testes de contrato
+// entre os handlers e o openapi.json. Cobre o subconjunto de schema que o gerador emite:
+// $ref, type, format (inteiros), properties, items e required.
+type Validator struct {
+	doc *Document
+}
+
+// NewValidator cria o validador de uma especificação
+func NewValidator(doc *Document) *Validator {
+	return &Validator{doc: doc}
+}
+
+// LoadValidator cria o validador a partir do JSON da especificação (ex.: Spec)
+func LoadValidator(data []byte) (*Validator, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("especificação inválida: %w", err)
+	}
+	return NewValidator(&doc), nil
+}
+
+// FindOperation encontra a operação de um método e caminho concreto (ex.: GET /invoices/7) e
+// devolve o caminho da especificação (/invoices/{id}) e os valores dos parâmetros de rota.
+// Como no Gin, segmentos fixos têm prioridade sobre parâmetros (/invoices/trash).
+func (v *Validator) FindOperation(method, path string) (*Operation, string, map[string]string, bool) {
+	segments := strings.Split(path, "/")
+	var (
+		best       *Operation
+		bestPath   string
+		bestParams map[string]string
+		bestScore  = -1
+	)
+	for template, item := range v.doc.Paths {
+		op := item[strings.ToLower(method)]
+		if op == nil {
+			continue
+		}
+		params, score, ok := matchPath(strings.Split(template, "/"), segments)
+		if ok && (score > bestScore || score == bestScore && template < bestPath) {
+			best, bestPath, bestParams, bestScore = op, template, params, score
+		}
+	}
+	return best, bestPath, bestParams, best != nil
+}
+
+// matchPath compara os segmentos e conta os fixos; um parâmetro não casa com segmento vazio
+func matchPath(template, segments []string) (map[string]string, int, bool) {
+	if len(template) != len(segments) {
+		return nil, 0, false
+	}
+	params := map[string]string{}
+	score := 0
+	for i, part := range template {
+		if name, ok := strings.CutPrefix(part, "{"); ok {
+			if segments[i] == "" {
+				return nil, 0, false
+			}
+			params[strings.TrimSuffix(name, "}")] = segments[i]
+			continue
+		}
+		if part != segments[i] {
+			return nil, 0, false
+		}
+		score++
+	}
+	return params, score, true
+}
+
+// ValidateRequest confere os parâmetros de rota e de consulta e o corpo da requisição com a
+// operação documentada
+func (v *Validator) ValidateRequest(req *http.Request, body []byte) error {
+	op, template, pathParams, ok := v.FindOperation(req.Method, req.URL.Path)
+	if !ok {
+		return fmt.Errorf("%s %s não está documentada", req.Method, req.URL.Path)
+	}
+
+	var errs []error
+	query := req.URL.Query()
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			if err := v.validateParam(param, pathParams[param.Name]); err != nil {
+				errs = append(errs, err)
+			}
+		case "query":
+			value, present := query[param.Name]
+			if !present {
+				if param.Required {
+					errs = append(errs, fmt.Errorf("parâmetro de consulta obrigatório %q ausente", param.Name))
+				}
+				continue
+			}
+			if err := v.validateParam(param, value[0]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(body) > 0 || op.RequestBody != nil && op.RequestBody.Required {
+		if err := v.validateRequestBody(op.RequestBody, req.Header.Get("Content-Type"), body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return wrapErrors(req.Method+" "+template+" (requisição)", errs)
+}
+
+func (v *Validator) validateParam(param Parameter, value string) error {
+	if problems := v.validateValue(param.Schema, parseParamValue(param.Schema, value), param.In+"."+param.Name); len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// parseParamValue converte o texto do parâmetro para o tipo do schema; se não converter, o
+// texto segue como string e a validação aponta o tipo errado
+func parseParamValue(schema Schema, value string) any {
+	switch schema.Type {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func (v *Validator) validateRequestBody(requestBody *RequestBody, contentType string, body []byte) error {
+	if requestBody == nil {
+		return errors.New("a operação não aceita corpo")
+	}
+	if len(body) == 0 {
+		return errors.New("corpo obrigatório ausente")
+	}
+	mediaType, schema, err := v.mediaType(requestBody.Content, contentType)
+	if err != nil {
+		return err
+	}
+	return v.validateBody(mediaType, schema, body, "body")
+}
+
+// ValidateResponse confere a resposta de uma requisição com a operação documentada: o status
+// precisa estar documentado (ou cair no default), o tipo de conteúdo precisa ser um dos
+// documentados e o corpo JSON precisa seguir o schema
+func (v *Validator) ValidateResponse(req *http.Request, status int, header http.Header, body []byte) error {
+	op, template, _, ok := v.FindOperation(req.Method, req.URL.Path)
+	if !ok {
+		return fmt.Errorf("%s %s não está documentada", req.Method, req.URL.Path)
+	}
+	name := fmt.Sprintf("%s %s (resposta %d)", req.Method, template, status)
+
+	response, ok := v.response(op, status)
+	if !ok {
+		return fmt.Errorf("%s: status não documentado", name)
+	}
+	if len(response.Content) == 0 || len(body) == 0 && status == http.StatusNoContent {
+		if len(body) > 0 {
+			return fmt.Errorf("%s: corpo não documentado", name)
+		}
+		return nil
+	}
+
+	mediaType, schema, err := v.mediaType(response.Content, header.Get("Content-Type"))
+	if err == nil {
+		err = v.validateBody(mediaType, schema, body, "body")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// response escolhe a resposta do status exato, da faixa (2XX) ou a default, resolvendo o $ref
+func (v *Validator) response(op *Operation, status int) (Response, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		response, ok := op.Responses[key]
+		if !ok {
+			continue
+		}
+		if name, ok := strings.CutPrefix(response.Ref, "#/components/responses/"); ok {
+			response, ok = v.doc.Components.Responses[name]
+			return response, ok
+		}
+		return response, true
+	}
+	return Response{}, false
+}
+
+// mediaType encontra o tipo de conteúdo documentado, ignorando parâmetros como charset
+func (v *Validator) mediaType(content map[string]MediaType, contentType string) (string, Schema, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	if media, ok := content[mediaType]; ok {
+		return mediaType, media.Schema, nil
+	}
+	documented := make([]string, 0, len(content))
+	for name := range content {
+		documented = append(documented, name)
+	}
+	sort.Strings(documented)
+	return "", Schema{}, fmt.Errorf("Content-Type %q não documentado (esperado: %s)", contentType, strings.Join(documented, ", "))
+}
+
+func (v *Validator) validateBody(mediaType string, schema Schema, body []byte, pointer string) error {
+	if mediaType != "application/json" {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("corpo não é JSON válido: %w", err)
+	}
+	if problems := v.validateValue(schema, value, pointer); len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateValue confere um valor JSON decodificado com o schema e lista as divergências
+func (v *Validator) validateValue(schema Schema, value any, pointer string) []string {
+	if name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/"); ok {
+		resolved, ok := v.doc.Components.Schemas[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: schema %q não encontrado", pointer, schema.Ref)}
+		}
+		schema = resolved
+	}
+	if value == nil {
+		// Campos opcionais e restritos por perfil saem com null
+		return nil
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []string{typeProblem(pointer, schema.Type, value)}
+		}
+		var problems []string
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: campo obrigatório %q ausente", pointer, name))
+			}
+		}
+		for name, property := range schema.Properties {
+			if field, ok := object[name]; ok {
+				problems = append(problems, v.validateValue(property, field, pointer+"."+name)...)
+			}
+		}
+		sort.Strings(problems)
+		return problems
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return []string{typeProblem(pointer, schema.Type, value)}
+		}
+		var problems []string
+		if schema.Items != nil {
+			for i, item := range items {
+				problems = append(problems, v.validateValue(*schema.Items, item, fmt.Sprintf("%s[%d]", pointer, i))...)
+			}
+		}
+		return problems
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			return []string{typeProblem(pointer, schema.Type, value)}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{typeProblem(pointer, schema.Type, value)}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{typeProblem(pointer, schema.Type, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{typeProblem(pointer, schema.Type, value)}
+		}
+	}
+	return nil
+}
+
+func typeProblem(pointer, expected string, value any) string {
+	return fmt.Sprintf("%s: esperado %s, recebido %v", pointer, expected, jsonType(value))
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func wrapErrors(name string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", name, errors.Join(errs...))
+}
@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func contractValidator() *Validator {
+	return NewValidator(Generate(gin.RoutesInfo{
+		{Method: "GET", Path: "/invoices/:id", Handler: "app/sales/handler.GetInvoiceHandler"},
+		{Method: "GET", Path: "/invoices/trash", Handler: "app/trash/handler.ListTrashHandler"},
+		{Method: "POST", Path: "/invoices/", Handler: "app/sales/handler.CreateInvoiceHandler"},
+		{Method: "GET", Path: "/docs", Handler: "app/openapi.SwaggerUIHandler"},
+	}, map[string]Annotation{
+		"app/sales/handler.GetInvoiceHandler": {Params: []Parameter{
+			{Name: "from", In: "query", Required: true, Schema: Schema{Type: "string", Format: "date"}},
+		}},
+		"app/openapi.SwaggerUIHandler": {Produce: "html"},
+	}))
+}
+
+func TestValidatorFindOperationPrefersStaticSegments(t *testing.T) {
+	v := contractValidator()
+
+	_, template, params, ok := v.FindOperation("GET", "/invoices/trash")
+	require.True(t, ok)
+	assert.Equal(t, "/invoices/trash", template)
+	assert.Empty(t, params)
+
+	_, template, params, ok = v.FindOperation("GET", "/invoices/7")
+	require.True(t, ok)
+	assert.Equal(t, "/invoices/{id}", template)
+	assert.Equal(t, map[string]string{"id": "7"}, params)
+
+	_, _, _, ok = v.FindOperation("DELETE", "/invoices/7")
+	assert.False(t, ok)
+}
+
+func TestValidatorValidateRequest(t *testing.T) {
+	v := contractValidator()
+
+	assert.NoError(t, v.ValidateRequest(httptest.NewRequest("GET", "/invoices/7?from=2030-01-01", nil), nil))
+
+	err := v.ValidateRequest(httptest.NewRequest("GET", "/invoices/abc", nil), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "path.id: esperado integer")
+	assert.Contains(t, err.Error(), `parâmetro de consulta obrigatório "from" ausente`)
+
+	body := []byte(`{"contact_id": 1}`)
+	req := httptest.NewRequest("POST", "/invoices/", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	assert.NoError(t, v.ValidateRequest(req, body))
+
+	req.Header.Set("Content-Type", "text/csv")
+	assert.ErrorContains(t, v.ValidateRequest(req, body), `Content-Type "text/csv" não documentado`)
+}
+
+func TestValidatorValidateResponse(t *testing.T) {
+	v := contractValidator()
+	req := httptest.NewRequest("GET", "/invoices/7", nil)
+	jsonHeader := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+
+	assert.NoError(t, v.ValidateResponse(req, http.StatusOK, jsonHeader, []byte(`{"id": 7}`)))
+	// Erros caem na resposta default: o envelope padrão
+	assert.NoError(t, v.ValidateResponse(req, http.StatusNotFound, jsonHeader,
+		[]byte(`{"error": {"code": "invoice_not_found", "message": "fatura não encontrada"}}`)))
+
+	err := v.ValidateResponse(req, http.StatusNotFound, jsonHeader, []byte(`{"error": "fatura não encontrada"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "body.error: esperado object, recebido string")
+
+	err = v.ValidateResponse(req, http.StatusInternalServerError, jsonHeader, []byte(`{"error": {"message": "falhou"}}`))
+	assert.ErrorContains(t, err, `body.error: campo obrigatório "code" ausente`)
+
+	err = v.ValidateResponse(req, http.StatusOK, jsonHeader, []byte(`[{"id": 7}]`))
+	assert.ErrorContains(t, err, "body: esperado object, recebido array")
+
+	// O tipo de conteúdo vem do @Produce
+	docs := httptest.NewRequest("GET", "/docs", nil)
+	assert.NoError(t, v.ValidateResponse(docs, http.StatusOK, http.Header{"Content-Type": {"text/html; charset=utf-8"}}, []byte("<html></html>")))
+	assert.ErrorContains(t, v.ValidateResponse(docs, http.StatusOK, jsonHeader, []byte(`{}`)), "não documentado")
+}
@@ -0,0 +1,82 @@
+package pdf
+
+import (
+	"bytes"
+	"io"
+)
+
+// Dimensões da página A4 e margem dos documentos, em pontos
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+	margin   = 50.0
+)
+
+// Column é um texto posicionado a x pontos da margem esquerda em uma linha de tabela
+type Column struct {
+	X    float64
+	Text string
+}
+
+// Document monta um documento A4 de texto linha a linha, começando uma nova página quando a
+// linha não cabe mais na atual
+type Document struct {
+	pages   []string
+	current bytes.Buffer
+	y       float64
+}
+
+// NewDocument cria um documento com a primeira página em branco
+func NewDocument() *Document {
+	return &Document{y: A4Height - margin}
+}
+
+// Line escreve uma linha de texto na margem esquerda
+func (d *Document) Line(text string, size float64, bold bool) {
+	d.Row(size, bold, Column{Text: text})
+}
+
+// Row escreve uma linha com os textos nas colunas informadas
+func (d *Document) Row(size float64, bold bool, columns ...Column) {
+	d.advance(size * 1.4)
+	for _, column := range columns {
+		if bold {
+			BoldText(&d.current, margin+column.X, d.y, size, column.Text)
+		} else {
+			Text(&d.current, margin+column.X, d.y, size, column.Text)
+		}
+	}
+}
+
+// Rule desenha uma linha horizontal de largura total
+func (d *Document) Rule() {
+	d.advance(6)
+	Rect(&d.current, margin, d.y+2, A4Width-2*margin, 0.5)
+}
+
+// Space avança o cursor sem escrever
+func (d *Document) Space(height float64) {
+	d.advance(height)
+}
+
+// Block reserva height pontos na largura da página e chama draw com o canto inferior esquerdo
+// do bloco, para desenhos que não são texto (como um QR code)
+func (d *Document) Block(height float64, draw func(b *bytes.Buffer, x, y float64)) {
+	d.advance(height)
+	draw(&d.current, margin, d.y)
+}
+
+// Write grava o documento como PDF
+func (d *Document) Write(w io.Writer) error {
+	pages := append(append([]string(nil), d.pages...), d.current.String())
+	return Write(w, A4Width, A4Height, pages)
+}
+
+func (d *Document) advance(height float64) {
+	if d.y-height < margin {
+		d.pages = append(d.pages, d.current.String())
+		d.current.Reset()
+		d.y = A4Height - margin
+	}
+	d.y -= height
+}
@@ -0,0 +1,102 @@
+// Package pdf gera PDFs simples (texto e retângulos preenchidos) sem dependências externas.
+// O texto usa as fontes padrão Helvetica (F1) e Helvetica-Bold (F2) em WinAnsi.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Write grava as páginas em um PDF; cada página é um fluxo de desenho com as dimensões
+// informadas, em pontos
+func Write(w io.Writer, width, height float64, pages []string) error {
+	if len(pages) == 0 {
+		return fmt.Errorf("pdf: nenhuma página para gerar")
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // páginas, preenchido depois de conhecer os objetos de cada página
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	kids := make([]string, 0, len(pages))
+	for _, content := range pages {
+		pageID := len(objects) + 1
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				Number(width), Number(height), pageID+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Text escreve o texto na posição (x, y) com a fonte regular
+func Text(b *bytes.Buffer, x, y, size float64, text string) {
+	writeText(b, "F1", x, y, size, text)
+}
+
+// BoldText escreve o texto na posição (x, y) em negrito
+func BoldText(b *bytes.Buffer, x, y, size float64, text string) {
+	writeText(b, "F2", x, y, size, text)
+}
+
+// Rect desenha um retângulo preenchido
+func Rect(b *bytes.Buffer, x, y, width, height float64) {
+	fmt.Fprintf(b, "%s %s %s %s re f\n", Number(x), Number(y), Number(width), Number(height))
+}
+
+func writeText(b *bytes.Buffer, font string, x, y, size float64, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(b, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, Number(size), Number(x), Number(y), String(text))
+}
+
+// String escapa o texto para uma string PDF em WinAnsi; caracteres fora do Latin-1 viram "?"
+func String(text string) string {
+	var b bytes.Buffer
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < 32:
+			b.WriteByte(' ')
+		case r < 256:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// Number formata a coordenada com duas casas decimais
+func Number(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
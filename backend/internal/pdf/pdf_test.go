@@ -0,0 +1,49 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, 100, 50, []string{"0 0 10 10 re f", "0 0 20 20 re f"}))
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, "/Count 2")
+	assert.Contains(t, out, "/F2 4 0 R")
+
+	start := strings.LastIndex(out, "startxref\n")
+	xref := strings.Index(out, "xref\n0 ")
+	assert.Contains(t, out[start:], "\n"+strconv.Itoa(xref)+"\n", "startxref aponta para a tabela xref")
+
+	assert.Error(t, Write(&buf, 100, 50, nil))
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "Fatura \\(2\\) \\\\ emiss\xe3o", String("Fatura (2) \\ emissão"), "Latin-1 em um byte")
+	assert.Equal(t, "R? 1", String("R€ 1"), "fora do Latin-1")
+}
+
+func TestDocumentPaginates(t *testing.T) {
+	doc := NewDocument()
+	doc.Line("Fatura INV-2026-000001", 16, true)
+	doc.Rule()
+	for i := range 80 {
+		doc.Row(10, false, Column{Text: "Item " + strconv.Itoa(i)}, Column{X: 300, Text: "R$ 10,00"})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, doc.Write(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "/Count 2", "80 linhas de 14 pontos não cabem em uma página A4")
+	assert.Contains(t, out, "/F2 16.00 Tf")
+	assert.Contains(t, out, "(Item 79) Tj")
+}
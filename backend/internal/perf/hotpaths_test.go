@@ -0,0 +1,175 @@
+package perf
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/config"
+	"ERP-ONSMART/backend/internal/middleware"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/modules/sales/repository"
+	"ERP-ONSMART/backend/internal/routes"
+	testutils "ERP-ONSMART/backend/internal/utils/test_utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const perfSecret = "perf-test-secret"
+
+// hotPath é um caminho crítico com o seu orçamento. Os tetos de queries valem em qualquer
+// máquina; os de latência são do banco descartável do `make bench` (PostgreSQL local, sem
+// rede) e devem ser revistos junto com as mudanças que prometem ganho de desempenho.
+type hotPath struct {
+	Scenario
+	Budget Budget
+}
+
+var hotPaths = []hotPath{
+	{
+		Scenario: Scenario{Name: "list_invoices_filtered", Method: http.MethodGet,
+			Path: "/invoices/?status=sent,partial,overdue&sort=-due_date,invoice_no&page_size=50"},
+		// Contagem, página e os preloads de contato e itens
+		Budget: Budget{P95: 150 * time.Millisecond, MaxQueries: 4},
+	},
+	{
+		Scenario: Scenario{Name: "search_sales_orders", Method: http.MethodGet,
+			Path: "/sales-orders/?search=Cliente&fields=id,so_no,status,grand_total,contact&page_size=50"},
+		// Contagem, página e o preload do contato pedido em ?fields=
+		Budget: Budget{P95: 150 * time.Millisecond, MaxQueries: 3},
+	},
+	{
+		Scenario: Scenario{Name: "process_board", Method: http.MethodGet,
+			Path: "/sales-processes/board?include_closed=true"},
+		// Totais por etapa e cartões de todas as colunas numa única consulta
+		Budget: Budget{P95: 200 * time.Millisecond, MaxQueries: 2},
+	},
+}
+
+// Volume dos dados de carga, criados uma vez por execução
+const (
+	perfContacts  = 20
+	perfDocuments = 300
+)
+
+var (
+	perfOnce   sync.Once
+	perfRouter *gin.Engine
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testutils.RunWithPostgres(m))
+}
+
+// hotPathRouter aponta a aplicação (DB_*) para o banco de teste, grava os dados de carga e
+// devolve o router com todas as rotas, como no servidor
+func hotPathRouter(tb testing.TB) *gin.Engine {
+	tb.Helper()
+	conn := testutils.PostgresDB(tb)
+
+	perfOnce.Do(func() {
+		cfg := config.LoadTestDBConfig()
+		viper.Set("DB_HOST", cfg.Host)
+		viper.Set("DB_PORT", cfg.Port)
+		viper.Set("DB_USER", cfg.User)
+		viper.Set("DB_PASSWORD", cfg.Password)
+		viper.Set("DB_NAME", cfg.DBName)
+		viper.Set("JWT_SECRET", perfSecret)
+
+		contacts := make([]int, perfContacts)
+		for i := range contacts {
+			contacts[i] = testutils.CreateContact(tb, conn).ID
+		}
+		invoiceStatuses := []string{models.InvoiceStatusSent, models.InvoiceStatusPartial, models.InvoiceStatusOverdue, models.InvoiceStatusPaid}
+		stages := repository.BoardStages(true)
+		for i := 0; i < perfDocuments; i++ {
+			contactID := contacts[i%perfContacts]
+			testutils.CreateInvoice(tb, conn, func(inv *models.Invoice) {
+				inv.ContactID = contactID
+				inv.Status = invoiceStatuses[i%len(invoiceStatuses)]
+				inv.DueDate = time.Now().AddDate(0, 0, i%60)
+			})
+			testutils.CreateSalesOrder(tb, conn, func(so *models.SalesOrder) { so.ContactID = contactID })
+			testutils.CreateSalesProcess(tb, conn, func(p *models.SalesProcess) {
+				p.ContactID = contactID
+				p.Status = stages[i%len(stages)]
+			})
+		}
+
+		gin.SetMode(gin.ReleaseMode)
+		perfRouter = gin.New()
+		routes.SetupRoutes(perfRouter)
+	})
+	if perfRouter == nil {
+		tb.Fatal("dados de carga não criados")
+	}
+	return perfRouter
+}
+
+// perfHeader autentica as requisições como administrador da empresa padrão
+func perfHeader(tb testing.TB) http.Header {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"username":   "perf_user",
+		"role":       "admin",
+		"company_id": 1,
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(perfSecret))
+	if err != nil {
+		tb.Fatalf("Erro ao assinar o token: %v", err)
+	}
+	return http.Header{"Authorization": {"Bearer " + signed}}
+}
+
+// Os caminhos críticos precisam responder dentro do orçamento sob carga concorrente
+func TestHotPathsWithinBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("carga dos caminhos críticos pulada com -short")
+	}
+	router := hotPathRouter(t)
+	header := perfHeader(t)
+
+	for _, path := range hotPaths {
+		t.Run(path.Name, func(t *testing.T) {
+			scenario := path.Scenario
+			scenario.Header = header
+
+			// Aquecimento: conexões e planos de execução fora da medição
+			Run(router, scenario, 10, 1)
+			report := Run(router, scenario, 200, 8)
+			t.Log(report)
+			assert.NoError(t, report.Check(path.Budget))
+		})
+	}
+}
+
+// Compare duas versões com `go test -bench HotPaths -count 10` e o benchstat
+func BenchmarkHotPaths(b *testing.B) {
+	router := hotPathRouter(b)
+	header := perfHeader(b)
+
+	for _, path := range hotPaths {
+		b.Run(path.Name, func(b *testing.B) {
+			scenario := path.Scenario
+			scenario.Header = header
+
+			queries := 0
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				resp := Serve(router, scenario)
+				if resp.Code != http.StatusOK {
+					b.Fatalf("%s: status %d: %s", path.Name, resp.Code, resp.Body.String())
+				}
+				n, _ := strconv.Atoi(resp.Header().Get(middleware.QueryCountHeader))
+				queries += n
+			}
+			b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+		})
+	}
+}
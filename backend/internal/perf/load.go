@@ -0,0 +1,133 @@
+package perf
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"ERP-ONSMART/backend/internal/middleware"
+)
+
+// Scenario é uma requisição de um caminho crítico da API
+type Scenario struct {
+	Name   string
+	Method string
+	Path   string
+	Header http.Header
+}
+
+// Budget é o teto aceito para um cenário: o p95 da latência e as queries por requisição
+type Budget struct {
+	P95        time.Duration
+	MaxQueries int
+}
+
+// Report resume a carga de um cenário
+type Report struct {
+	Scenario   string
+	Requests   int
+	Failures   int
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+	MaxQueries int
+	Throughput float64
+}
+
+// Serve executa uma requisição do cenário e devolve a resposta
+func Serve(handler http.Handler, scenario Scenario) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(scenario.Method, scenario.Path, nil)
+	for name, values := range scenario.Header {
+		req.Header[name] = values
+	}
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	return resp
+}
+
+// Run executa o cenário requests vezes contra o router, sem rede, com concurrency requisições
+// simultâneas, e resume os percentis da latência e o máximo de queries por requisição (header
+// X-Query-Count do middleware.QueryStats). Respostas 4xx e 5xx contam como falhas, mas entram
+// nos percentis.
+func Run(handler http.Handler, scenario Scenario, requests, concurrency int) Report {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	latencies := make([]time.Duration, requests)
+	queries := make([]int, requests)
+	failed := make([]bool, requests)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				began := time.Now()
+				resp := Serve(handler, scenario)
+				latencies[i] = time.Since(began)
+				queries[i], _ = strconv.Atoi(resp.Header().Get(middleware.QueryCountHeader))
+				failed[i] = resp.Code >= http.StatusBadRequest
+			}
+		}()
+	}
+	for i := 0; i < requests; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := Report{Scenario: scenario.Name, Requests: requests}
+	for i := range latencies {
+		if failed[i] {
+			report.Failures++
+		}
+		report.MaxQueries = max(report.MaxQueries, queries[i])
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P50 = percentile(latencies, 50)
+	report.P95 = percentile(latencies, 95)
+	report.P99 = percentile(latencies, 99)
+	if requests > 0 {
+		report.Max = latencies[requests-1]
+		report.Throughput = float64(requests) / elapsed.Seconds()
+	}
+	return report
+}
+
+// percentile usa o método do posto mais próximo sobre as latências ordenadas
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// Check compara o relatório com o orçamento; um teto zerado não é verificado
+func (r Report) Check(budget Budget) error {
+	if r.Failures > 0 {
+		return fmt.Errorf("%s: %d de %d requisições falharam", r.Scenario, r.Failures, r.Requests)
+	}
+	if budget.P95 > 0 && r.P95 > budget.P95 {
+		return fmt.Errorf("%s: p95 de %v acima do orçamento de %v", r.Scenario, r.P95, budget.P95)
+	}
+	if budget.MaxQueries > 0 && r.MaxQueries > budget.MaxQueries {
+		return fmt.Errorf("%s: %d queries por requisição, acima do orçamento de %d", r.Scenario, r.MaxQueries, budget.MaxQueries)
+	}
+	return nil
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("%s: %d req (%d falhas), p50 %v, p95 %v, p99 %v, máx %v, %.0f req/s, até %d queries",
+		r.Scenario, r.Requests, r.Failures, r.P50.Round(time.Microsecond), r.P95.Round(time.Microsecond),
+		r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond), r.Throughput, r.MaxQueries)
+}
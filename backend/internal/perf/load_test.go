@@ -0,0 +1,65 @@
+package perf
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCountsRequestsFailuresAndQueries(t *testing.T) {
+	var served atomic.Int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := served.Add(1)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set(middleware.QueryCountHeader, "3")
+		if n == 1 {
+			w.Header().Set(middleware.QueryCountHeader, "7")
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	report := Run(handler, Scenario{
+		Name:   "lista",
+		Method: http.MethodGet,
+		Path:   "/invoices/",
+		Header: http.Header{"Authorization": {"Bearer token"}},
+	}, 40, 4)
+
+	assert.Equal(t, int64(40), served.Load())
+	assert.Equal(t, "lista", report.Scenario)
+	assert.Equal(t, 40, report.Requests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 7, report.MaxQueries)
+	assert.LessOrEqual(t, report.P50, report.P95)
+	assert.LessOrEqual(t, report.P99, report.Max)
+	assert.Positive(t, report.Throughput)
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 20)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, 10*time.Millisecond, percentile(sorted, 50))
+	assert.Equal(t, 19*time.Millisecond, percentile(sorted, 95))
+	assert.Equal(t, 20*time.Millisecond, percentile(sorted, 99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 95))
+}
+
+func TestReportCheck(t *testing.T) {
+	report := Report{Scenario: "lista", Requests: 10, P95: 80 * time.Millisecond, MaxQueries: 4}
+
+	assert.NoError(t, report.Check(Budget{P95: 100 * time.Millisecond, MaxQueries: 4}))
+	assert.NoError(t, report.Check(Budget{}))
+	assert.EqualError(t, report.Check(Budget{P95: 50 * time.Millisecond}), "lista: p95 de 80ms acima do orçamento de 50ms")
+	assert.EqualError(t, report.Check(Budget{MaxQueries: 3}), "lista: 4 queries por requisição, acima do orçamento de 3")
+
+	report.Failures = 2
+	assert.EqualError(t, report.Check(Budget{}), "lista: 2 de 10 requisições falharam")
+}
@@ -0,0 +1,94 @@
+// Package realtime distribui eventos aos clientes conectados (Server-Sent Events) por tópico. Os
+// assinantes ficam na memória da instância: um evento publicado chega aos clientes conectados à
+// mesma instância, e quem perder um evento o encontra na consulta normal do recurso.
+package realtime
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// bufferSize é quantos eventos esperam por um assinante lento antes de serem descartados
+const bufferSize = 16
+
+// Event é um evento publicado em um tópico
+type Event struct {
+	Type       string      `json:"type"`
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// Hub guarda os assinantes de cada tópico
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+// NewHub cria um hub sem assinantes
+func NewHub() *Hub {
+	return &Hub{subscribers: map[string]map[chan Event]struct{}{}}
+}
+
+var defaultHub = NewHub()
+
+// Subscribe assina o tópico no hub padrão
+func Subscribe(topic string) (<-chan Event, func()) {
+	return defaultHub.Subscribe(topic)
+}
+
+// Publish publica o evento no hub padrão
+func Publish(topic string, event Event) int {
+	return defaultHub.Publish(topic, event)
+}
+
+// Subscribe assina o tópico e devolve o canal dos eventos e a função que cancela a assinatura
+// (e fecha o canal)
+func (h *Hub) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, bufferSize)
+
+	h.mu.Lock()
+	if h.subscribers[topic] == nil {
+		h.subscribers[topic] = map[chan Event]struct{}{}
+	}
+	h.subscribers[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subscribers[topic], ch)
+			if len(h.subscribers[topic]) == 0 {
+				delete(h.subscribers, topic)
+			}
+			close(ch)
+		})
+	}
+}
+
+// Publish entrega o evento aos assinantes do tópico sem bloquear: quem estiver com o buffer
+// cheio perde o evento. Retorna quantos assinantes o receberam.
+func (h *Hub) Publish(topic string, event Event) int {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delivered := 0
+	for ch := range h.subscribers[topic] {
+		select {
+		case ch <- event:
+			delivered++
+		default:
+		}
+	}
+	return delivered
+}
+
+// ProcessTopic é o tópico da linha do tempo do processo de venda na empresa
+func ProcessTopic(companyID, processID int) string {
+	return fmt.Sprintf("company:%d:sales_process:%d", companyID, processID)
+}
@@ -0,0 +1,32 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	topic := ProcessTopic(1, 42)
+	events, cancel := hub.Subscribe(topic)
+	other, cancelOther := hub.Subscribe(ProcessTopic(2, 42))
+	defer cancelOther()
+
+	assert.Equal(t, 1, hub.Publish(topic, Event{Type: "payment_received", Data: 750}))
+	event := <-events
+	assert.Equal(t, "payment_received", event.Type)
+	assert.False(t, event.OccurredAt.IsZero())
+	assert.Empty(t, other, "outra empresa não recebe o evento")
+
+	for range bufferSize + 5 {
+		hub.Publish(topic, Event{Type: "x"})
+	}
+	assert.Len(t, events, bufferSize, "assinante lento perde o excedente sem travar a publicação")
+
+	cancel()
+	cancel()
+	assert.Equal(t, 0, hub.Publish(topic, Event{Type: "x"}))
+	for range events {
+	}
+}
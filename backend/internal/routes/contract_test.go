@@ -0,0 +1,221 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contractSecret = "contract-test-secret"
+
+// Valores dos parâmetros de rota que não são IDs numéricos
+var contractPathParams = map[string]string{
+	"entity_type": "invoice",
+	"source":      "invoice",
+	"key":         "sales_revenue",
+	"username":    "contract_user",
+	"token":       "token-de-contrato",
+	"code":        "SKU-1",
+	"sn":          "SN-1",
+	"carrier":     "correios",
+	"period":      "2030-01",
+	"role":        "Colaborador",
+}
+
+// Valores dos parâmetros de consulta obrigatórios, pelo nome
+var contractQueryParams = map[string]string{
+	"period": "2030-01",
+	"from":   "2030-01-01",
+	"to":     "2030-01-31",
+}
+
+// Corpos realistas das principais rotas de escrita; as demais recebem um objeto vazio
+var contractPayloads = map[string]string{
+	"POST /auth/login": `{"username": "contract_user", "password": "s3nh@-forte"}`,
+	"POST /auth/register": `{"username": "contract_user", "password": "s3nh@-forte", "email": "contrato@example.com",
+		"nome": "Usuário Contrato"}`,
+	"POST /auth/password/reset": `{"token": "token-de-contrato", "password": "n0v@-senha-forte"}`,
+	"POST /contacts/": `{"person_type": "pj", "type": "cliente", "name": "Cliente Contrato", "document": "11222333000181",
+		"email": "cliente@example.com", "zip_code": "01001000"}`,
+	"POST /products/": `{"name": "Produto Contrato", "detailed_name": "Produto de contrato", "status": "ativo",
+		"sku": "SKU-1", "coin": "BRL", "price": 100, "cost_price": 60, "stock": 10}`,
+	"POST /invoices/batch": `{"atomic": true, "items": [{"contact_id": 1, "issue_date": "2030-01-01T00:00:00Z",
+		"due_date": "2030-01-31T00:00:00Z", "items": [{"product_id": 1, "quantity": 2, "unit_price": "50.00"}]}]}`,
+	"POST /payments/batch": `{"atomic": false, "items": [{"invoice_id": 1, "amount": "40.00",
+		"payment_date": "2030-01-10T00:00:00Z", "payment_method": "pix"}]}`,
+	"POST /accounting/":       `{"description": "Venda à vista", "amount": "100.00", "date": "05/01/2030"}`,
+	"POST /ledger/accounts":   `{"code": "1.1.01", "name": "Caixa", "type": "asset"}`,
+	"POST /crm/leads":         `{"name": "Lead Contrato", "email": "lead@example.com", "source": "site"}`,
+	"POST /portal/auth/token": `{"code": "ABCD-1234"}`,
+	"POST /tasks/":            `{"title": "Ligar para o cliente", "due_date": "2030-01-10T00:00:00Z"}`,
+	"POST /expenses/": `{"category_id": 1, "description": "Combustível", "expense_date": "2030-01-05T00:00:00Z",
+		"amount": "120.50"}`,
+	"POST /custom-fields/":    `{"entity": "product", "key": "cor", "label": "Cor", "type": "text"}`,
+	"POST /api-keys/":         `{"name": "Integração de contrato", "scopes": ["products:read"]}`,
+	"POST /users/invitations": `{"email": "convidado@example.com", "nome": "Convidado", "role": "Colaborador"}`,
+}
+
+// Os handlers precisam responder o que o openapi.json documenta: status previstos, o tipo de
+// conteúdo e o envelope de erro, e 401 só nas operações com segurança documentada. Cada rota é
+// chamada sem credenciais e com as credenciais do seu esquema; sem banco, a maioria termina na
+// validação ou em erro de banco, que também precisam seguir o contrato.
+func TestHandlersFollowOpenAPIContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	viper.Set("JWT_SECRET", contractSecret)
+	t.Cleanup(func() { viper.Set("JWT_SECRET", nil) })
+
+	router := gin.New()
+	SetupRoutes(router)
+	validator, err := openapi.LoadValidator(openapi.Spec)
+	require.NoError(t, err)
+
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			path := contractPath(route.Path)
+			op, _, _, ok := validator.FindOperation(route.Method, path)
+			require.True(t, ok, "rota não documentada: rode `make openapi`")
+
+			resp, req := contractRequest(t, router, validator, route, path, "")
+			assert.NoError(t, validator.ValidateResponse(req, resp.Code, resp.Header(), resp.Body.Bytes()))
+			if len(op.Security) == 0 {
+				assert.NotEqual(t, "missing_token", errorCode(resp), "a rota exige autenticação, mas a operação não documenta @Security")
+				return
+			}
+			assert.Equal(t, http.StatusUnauthorized, resp.Code, "a operação documenta @Security, mas a rota responde sem credenciais")
+
+			resp, req = contractRequest(t, router, validator, route, path, contractCredential(t, op.Security))
+			assert.NoError(t, validator.ValidateResponse(req, resp.Code, resp.Header(), resp.Body.Bytes()))
+		})
+	}
+
+	for route := range contractPayloads {
+		assert.True(t, registered[route], "corpo de exemplo para rota inexistente: %s", route)
+	}
+}
+
+// contractPath preenche os parâmetros da rota
+func contractPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+		segments[i] = "1"
+		if value, ok := contractPathParams[name]; ok {
+			segments[i] = value
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// contractRequest monta a requisição da rota, confere-a com a especificação e a executa; a
+// credencial é o valor do header informado ("Authorization: ..." ou "X-API-Key: ...")
+func contractRequest(t *testing.T, router *gin.Engine, validator *openapi.Validator, route gin.RouteInfo, path, credential string) (*httptest.ResponseRecorder, *http.Request) {
+	t.Helper()
+
+	op, _, _, _ := validator.FindOperation(route.Method, path)
+	var body []byte
+	var contentType string
+	if op.RequestBody != nil {
+		if _, multipartBody := op.RequestBody.Content["multipart/form-data"]; multipartBody {
+			body, contentType = contractMultipart(t)
+		} else {
+			payload, ok := contractPayloads[route.Method+" "+route.Path]
+			if !ok {
+				payload = "{}"
+			}
+			body, contentType = []byte(payload), "application/json"
+		}
+	}
+
+	query := url.Values{}
+	for _, param := range op.Parameters {
+		if param.In == "query" && param.Required {
+			query.Set(param.Name, contractQueryParams[param.Name])
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req := httptest.NewRequest(route.Method, path, bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if header, value, ok := strings.Cut(credential, ": "); ok {
+		req.Header.Set(header, value)
+	}
+	require.NoError(t, validator.ValidateRequest(req, body))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp, req
+}
+
+// errorCode lê o código do envelope de erro da resposta
+func errorCode(resp *httptest.ResponseRecorder) string {
+	var envelope struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &envelope)
+	return envelope.Error.Code
+}
+
+// contractMultipart monta um upload com um CSV pequeno no campo file
+func contractMultipart(t *testing.T) ([]byte, string) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", "contrato.csv")
+	require.NoError(t, err)
+	_, err = io.WriteString(part, "name,email\nCliente Contrato,cliente@example.com\n")
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes(), writer.FormDataContentType()
+}
+
+// contractCredential devolve o header do esquema de segurança da operação: token de
+// administrador, token do portal ou chave de API
+func contractCredential(t *testing.T, security []map[string][]string) string {
+	claims := jwt.MapClaims{
+		"username":   "contract_user",
+		"role":       "admin",
+		"company_id": 1,
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	}
+	for scheme := range security[0] {
+		switch scheme {
+		case "ApiKeyAuth":
+			return "X-API-Key: erp_contrato_chave_invalida"
+		case "PortalAuth":
+			claims = jwt.MapClaims{
+				"scope":      "portal",
+				"contact_id": 1,
+				"company_id": 1,
+				"exp":        time.Now().Add(time.Hour).Unix(),
+			}
+		}
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(contractSecret))
+	require.NoError(t, err)
+	return "Authorization: Bearer " + signed
+}
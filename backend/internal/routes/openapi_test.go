@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"testing"
+
+	"ERP-ONSMART/backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A especificação servida em /openapi.json precisa acompanhar as rotas registradas;
+// se este teste falhar, rode `make openapi` na raiz do repositório
+func TestOpenAPISpecIsUpToDate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRoutes(router)
+
+	annotations, err := openapi.ParseAnnotations("../../..", "..")
+	require.NoError(t, err)
+
+	data, err := openapi.Marshal(openapi.Generate(router.Routes(), annotations))
+	require.NoError(t, err)
+	assert.Equal(t, string(openapi.Spec), string(data), "openapi.json desatualizado: rode `make openapi`")
+}
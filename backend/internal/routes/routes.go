@@ -1,21 +1,84 @@
 package routes
 
 import (
+	"ERP-ONSMART/backend/internal/metrics"
+	"ERP-ONSMART/backend/internal/middleware"
 	accountingHandler "ERP-ONSMART/backend/internal/modules/accounting/handler"
+	analyticsHandler "ERP-ONSMART/backend/internal/modules/analytics/handler"
+	apiKeysHandler "ERP-ONSMART/backend/internal/modules/apikeys/handler"
+	apiKeysModels "ERP-ONSMART/backend/internal/modules/apikeys/models"
+	assetsHandler "ERP-ONSMART/backend/internal/modules/assets/handler"
+	attachmentsHandler "ERP-ONSMART/backend/internal/modules/attachments/handler"
 	authHandler "ERP-ONSMART/backend/internal/modules/auth/handler"
+	backupsHandler "ERP-ONSMART/backend/internal/modules/backups/handler"
+	bankingHandler "ERP-ONSMART/backend/internal/modules/banking/handler"
+	calendarHandler "ERP-ONSMART/backend/internal/modules/calendar/handler"
+	commissionsHandler "ERP-ONSMART/backend/internal/modules/commissions/handler"
+	companiesHandler "ERP-ONSMART/backend/internal/modules/companies/handler"
 	contactHandler "ERP-ONSMART/backend/internal/modules/contact/handler"
+	contractsHandler "ERP-ONSMART/backend/internal/modules/contracts/handler"
+	crmHandler "ERP-ONSMART/backend/internal/modules/crm/handler"
+	customFieldsHandler "ERP-ONSMART/backend/internal/modules/customfields/handler"
 	dashboardHandler "ERP-ONSMART/backend/internal/modules/dashboard/handler"
 	dropshippingHandler "ERP-ONSMART/backend/internal/modules/dropshipping/handler"
+	ecommerceHandler "ERP-ONSMART/backend/internal/modules/ecommerce/handler"
+	ediHandler "ERP-ONSMART/backend/internal/modules/edi/handler"
+	etlHandler "ERP-ONSMART/backend/internal/modules/etl/handler"
+	expensesHandler "ERP-ONSMART/backend/internal/modules/expenses/handler"
+	featureFlagsHandler "ERP-ONSMART/backend/internal/modules/featureflags/handler"
+	featureFlagsModels "ERP-ONSMART/backend/internal/modules/featureflags/models"
+	fieldPermissionsHandler "ERP-ONSMART/backend/internal/modules/fieldpermissions/handler"
+	fieldPermissionsService "ERP-ONSMART/backend/internal/modules/fieldpermissions/service"
+	fileExchangeHandler "ERP-ONSMART/backend/internal/modules/fileexchange/handler"
+	financeHandler "ERP-ONSMART/backend/internal/modules/finance/handler"
+	followupHandler "ERP-ONSMART/backend/internal/modules/followup/handler"
+	graphqlHandler "ERP-ONSMART/backend/internal/modules/graphql/handler"
+	hrHandler "ERP-ONSMART/backend/internal/modules/hr/handler"
+	i18nHandler "ERP-ONSMART/backend/internal/modules/i18n/handler"
+	idempotencyRepository "ERP-ONSMART/backend/internal/modules/idempotency/repository"
+	inventoryHandler "ERP-ONSMART/backend/internal/modules/inventory/handler"
+	labelsHandler "ERP-ONSMART/backend/internal/modules/labels/handler"
 	marketingHandler "ERP-ONSMART/backend/internal/modules/marketing/handler"
+	metaHandler "ERP-ONSMART/backend/internal/modules/meta/handler"
+	portalHandler "ERP-ONSMART/backend/internal/modules/portal/handler"
 	productsHandler "ERP-ONSMART/backend/internal/modules/products/handler"
+	purchasingHandler "ERP-ONSMART/backend/internal/modules/purchasing/handler"
 	rentalHandler "ERP-ONSMART/backend/internal/modules/rental/handler"
+	reportsHandler "ERP-ONSMART/backend/internal/modules/reports/handler"
+	returnsHandler "ERP-ONSMART/backend/internal/modules/returns/handler"
 	salesHandler "ERP-ONSMART/backend/internal/modules/sales/handler"
+	serviceOrdersHandler "ERP-ONSMART/backend/internal/modules/serviceorders/handler"
+	shippingHandler "ERP-ONSMART/backend/internal/modules/shipping/handler"
+	slaHandler "ERP-ONSMART/backend/internal/modules/sla/handler"
+	taskHandler "ERP-ONSMART/backend/internal/modules/tasks/handler"
+	templatesHandler "ERP-ONSMART/backend/internal/modules/templates/handler"
+	trashHandler "ERP-ONSMART/backend/internal/modules/trash/handler"
+	trashModels "ERP-ONSMART/backend/internal/modules/trash/models"
+	usersHandler "ERP-ONSMART/backend/internal/modules/users/handler"
+	"ERP-ONSMART/backend/internal/openapi"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes configura todas as rotas da aplicação.
 func SetupRoutes(router *gin.Engine) {
+	// Latência e total de requisições por rota (GET /metrics)
+	router.Use(middleware.Metrics())
+	// Quantidade de queries e tempo de banco por requisição (headers X-Query-Count e Server-Timing)
+	router.Use(middleware.QueryStats())
+	// Idioma da requisição (Accept-Language: pt-BR ou en-US) das mensagens, rótulos e documentos
+	router.Use(middleware.LanguageMiddleware())
+	// Empresa da requisição (claim company_id ou header X-Company-ID), aplicada às queries GORM
+	router.Use(middleware.TenantMiddleware())
+	// Reenvios de POST/PUT com o header Idempotency-Key devolvem a resposta já gravada para a mesma
+	// empresa e o mesmo usuário ou chave de API, autenticados como no AuthMiddleware e no APIKeyMiddleware
+	router.Use(middleware.IdempotencyMiddleware(idempotencyRepository.NewIdempotencyRepository))
+	// Erros registrados com c.Error são respondidos no envelope padrão {"error": {code, message, ...}},
+	// assim como os valores monetários fora do intervalo de money.Decimal (400)
+	router.Use(middleware.ErrorHandler())
+	// Campos de custo, lucro e margem restritos ao perfil do usuário saem removidos ou com null
+	router.Use(middleware.FieldPermissionsMiddleware(fieldPermissionsService.PolicyFor))
+
 	// Rota pública de boas-vindas
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "Bem-vindo ao ERP Inteligente da On Smart Tech"})
@@ -26,12 +89,22 @@ func SetupRoutes(router *gin.Engine) {
 		c.JSON(200, gin.H{"message": "pong"})
 	})
 
+	// Especificação OpenAPI (gerada com `make openapi`) e Swagger UI, desativáveis pela flag api_docs
+	router.GET("/openapi.json", middleware.RequireFeature(featureFlagsModels.FlagAPIDocs), openapi.SpecHandler)
+	router.GET("/docs", middleware.RequireFeature(featureFlagsModels.FlagAPIDocs), openapi.SwaggerUIHandler)
+
+	// Métricas no formato do Prometheus (protegidas por METRICS_TOKEN, se definido)
+	router.GET("/metrics", metrics.ScrapeHandler)
+
 	authGroup := router.Group("/auth")
 	{
 		authGroup.POST("/login", authHandler.LoginHandler)
 		authGroup.POST("/register", authHandler.RegisterHandler)
 		authGroup.GET("/profile", authHandler.ProfileHandler)
 		authGroup.DELETE("/:username", authHandler.DeleteUserHandler)
+		authGroup.POST("/invitations/accept", usersHandler.AcceptInvitationHandler)
+		authGroup.POST("/password/forgot", usersHandler.ForgotPasswordHandler)
+		authGroup.POST("/password/reset", usersHandler.ResetPasswordHandler)
 	}
 
 	// Grupo de rotas para o módulo de vendas
@@ -53,6 +126,23 @@ func SetupRoutes(router *gin.Engine) {
 		accountingGroup.DELETE("/:id", accountingHandler.DeleteTransactionHandler)
 	}
 
+	// Grupo de rotas para o razão geral (partidas dobradas)
+	ledgerGroup := router.Group("/ledger", middleware.AuthMiddleware())
+	{
+		ledgerGroup.GET("/accounts", accountingHandler.ListLedgerAccountsHandler)
+		ledgerGroup.POST("/accounts", middleware.RBACMiddleware("admin"), accountingHandler.CreateLedgerAccountHandler)
+		ledgerGroup.PUT("/accounts/:id", middleware.RBACMiddleware("admin"), accountingHandler.UpdateLedgerAccountHandler)
+		ledgerGroup.GET("/account-mappings", accountingHandler.GetAccountMappingsHandler)
+		ledgerGroup.PUT("/account-mappings/:key", middleware.RBACMiddleware("admin"), accountingHandler.SetAccountMappingHandler)
+		ledgerGroup.GET("/journal-entries", accountingHandler.ListJournalEntriesHandler)
+		ledgerGroup.GET("/journal-entries/:id", accountingHandler.GetJournalEntryHandler)
+		ledgerGroup.POST("/journal-entries", middleware.RBACMiddleware("admin", "finance_user"), accountingHandler.CreateJournalEntryHandler)
+		ledgerGroup.POST("/post", middleware.RBACMiddleware("admin", "finance_user"), accountingHandler.PostPendingDocumentsHandler)
+		ledgerGroup.POST("/post/:source/:id", middleware.RBACMiddleware("admin", "finance_user"), accountingHandler.PostDocumentHandler)
+		ledgerGroup.GET("/trial-balance", accountingHandler.GetTrialBalanceHandler)
+		ledgerGroup.GET("/profit-and-loss", accountingHandler.GetProfitAndLossHandler)
+	}
+
 	// Grupo de rotas para o módulo de marketing
 	marketingGroup := router.Group("/marketing")
 	{
@@ -62,14 +152,48 @@ func SetupRoutes(router *gin.Engine) {
 		marketingGroup.DELETE("/:id", marketingHandler.DeleteCampaignHandler)
 	}
 
+	// Grupo de rotas para a atribuição de vendas às campanhas (processos vinculados e ROI)
+	campaignGroup := router.Group("/campaigns", middleware.AuthMiddleware())
+	{
+		campaignGroup.GET("/:id/roi", marketingHandler.GetCampaignROIHandler)
+		campaignGroup.POST("/:id/sales-processes/:process_id", marketingHandler.LinkSalesProcessHandler)
+		campaignGroup.DELETE("/:id/sales-processes/:process_id", marketingHandler.UnlinkSalesProcessHandler)
+	}
+
 	// Grupo de rotas para o módulo de contatos (clientes e fornecedores)
 	contactGroup := router.Group("/contacts")
 	{
 		contactGroup.GET("/", contactHandler.ListContactsHandler)
+		// Deduplicação: pares prováveis e mesclagem no contato mantido
+		contactGroup.GET("/duplicates", middleware.AuthMiddleware(), contactHandler.ListDuplicateContactsHandler)
+		contactGroup.POST("/merge", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), contactHandler.MergeContactsHandler)
+		contactGroup.POST("/enrich", middleware.AuthMiddleware(), contactHandler.EnrichContactHandler)
 		contactGroup.GET("/:id", contactHandler.GetContactByIDHandler)
+		contactGroup.GET("/:id/statement", contactHandler.GetContactStatementHandler)
+		contactGroup.GET("/:id/balance", contactHandler.GetContactBalanceHandler)
+		contactGroup.GET("/:id/credit", middleware.AuthMiddleware(), contactHandler.GetContactCreditHandler)
+		contactGroup.PUT("/:id/credit", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), contactHandler.UpdateContactCreditHandler)
+		contactGroup.GET("/:id/payment-terms", middleware.AuthMiddleware(), contactHandler.GetContactPaymentTermsHandler)
+		contactGroup.PUT("/:id/payment-terms", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), contactHandler.SaveContactPaymentTermsHandler)
+		contactGroup.DELETE("/:id/payment-terms", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), contactHandler.DeleteContactPaymentTermsHandler)
+		contactGroup.POST("/:id/block", middleware.AuthMiddleware(), contactHandler.BlockContactHandler)
+		contactGroup.POST("/:id/unblock", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), contactHandler.UnblockContactHandler)
+		contactGroup.GET("/:id/blocks", middleware.AuthMiddleware(), contactHandler.ListContactBlockEventsHandler)
 		contactGroup.POST("/", contactHandler.CreateContactHandler)
 		contactGroup.PUT("/:id", contactHandler.UpdateContactHandler)
 		contactGroup.DELETE("/:id", contactHandler.DeleteContactHandler)
+		registerTrashRoutes(contactGroup, trashModels.ResourceContacts)
+
+		// Acessos do cliente ao portal
+		contactGroup.GET("/:id/portal-access", middleware.AuthMiddleware(), portalHandler.ListPortalAccessesHandler)
+		contactGroup.POST("/:id/portal-access", middleware.AuthMiddleware(), portalHandler.CreatePortalAccessHandler)
+		contactGroup.DELETE("/:id/portal-access", middleware.AuthMiddleware(), portalHandler.RevokePortalAccessHandler)
+
+		// Catálogo de endereços (cobrança, entrega e unidades) usado nos documentos
+		contactGroup.GET("/:id/addresses", middleware.AuthMiddleware(), contactHandler.ListAddressesHandler)
+		contactGroup.POST("/:id/addresses", middleware.AuthMiddleware(), contactHandler.CreateAddressHandler)
+		contactGroup.PUT("/:id/addresses/:address_id", middleware.AuthMiddleware(), contactHandler.UpdateAddressHandler)
+		contactGroup.DELETE("/:id/addresses/:address_id", middleware.AuthMiddleware(), contactHandler.DeleteAddressHandler)
 	}
 
 	//Grupo de rotas para o módulo de produtos
@@ -80,6 +204,46 @@ func SetupRoutes(router *gin.Engine) {
 		productGroup.POST("/", productsHandler.CreateProductHandler)
 		productGroup.PUT("/:id", productsHandler.UpdateProductHandler)
 		productGroup.DELETE("/:id", productsHandler.DeleteProductHandler)
+		productGroup.GET("/:id/variants", productsHandler.ListVariantsHandler)
+		productGroup.POST("/:id/variants/matrix", productsHandler.GenerateVariantMatrixHandler)
+		productGroup.PUT("/:id/variants/:variant_id", productsHandler.UpdateVariantHandler)
+		productGroup.DELETE("/:id/variants/:variant_id", productsHandler.DeleteVariantHandler)
+		productGroup.GET("/kits/margins", productsHandler.ListKitMarginsHandler)
+		productGroup.GET("/:id/kit", productsHandler.GetKitHandler)
+		productGroup.PUT("/:id/kit", productsHandler.SetKitHandler)
+		productGroup.DELETE("/:id/kit", productsHandler.DeleteKitHandler)
+		productGroup.GET("/:id/units", productsHandler.GetUnitsHandler)
+		productGroup.PUT("/:id/units", productsHandler.SetUnitsHandler)
+		productGroup.PUT("/:id/catalog", productsHandler.SetProductCatalogHandler)
+		productGroup.PUT("/:id/lifecycle", productsHandler.SetProductLifecycleHandler)
+		productGroup.GET("/:id/replacement", productsHandler.GetProductReplacementHandler)
+		productGroup.GET("/:id/images", productsHandler.ListProductImagesHandler)
+		productGroup.POST("/:id/images", productsHandler.UploadProductImageHandler)
+		productGroup.PUT("/:id/images/order", productsHandler.ReorderProductImagesHandler)
+		productGroup.DELETE("/:id/images/:image_id", productsHandler.DeleteProductImageHandler)
+		registerTrashRoutes(productGroup, trashModels.ResourceProducts)
+	}
+
+	// Árvore de categorias do catálogo de produtos e margem mínima de cada categoria
+	productCategoryGroup := router.Group("/product-categories")
+	{
+		productCategoryGroup.GET("/", productsHandler.ListCategoriesHandler)
+		productCategoryGroup.POST("/", productsHandler.CreateCategoryHandler)
+		productCategoryGroup.PUT("/:id", productsHandler.UpdateCategoryHandler)
+		productCategoryGroup.DELETE("/:id", productsHandler.DeleteCategoryHandler)
+		productCategoryGroup.GET("/min-margins", productsHandler.ListMinMarginsHandler)
+		productCategoryGroup.PUT("/:id/min-margin", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), productsHandler.SetMinMarginHandler)
+		productCategoryGroup.DELETE("/:id/min-margin", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), productsHandler.DeleteMinMarginHandler)
+	}
+
+	// Catálogo público, somente leitura e sem autenticação: produtos publicados, categorias e
+	// imagens, lidos pelo conector de e-commerce e pelo portal do cliente
+	catalogGroup := router.Group("/catalog")
+	{
+		catalogGroup.GET("/products", productsHandler.ListCatalogProductsHandler)
+		catalogGroup.GET("/products/:id", productsHandler.GetCatalogProductHandler)
+		catalogGroup.GET("/categories", productsHandler.ListCategoriesHandler)
+		catalogGroup.GET("/images/:id", productsHandler.CatalogImageHandler)
 	}
 
 	//Grupo de rotas para o módulo de locação
@@ -110,7 +274,689 @@ func SetupRoutes(router *gin.Engine) {
 		dropshippingGroup.DELETE("/:id", dropshippingHandler.DeleteDropshippingHandler)
 	}
 
+	// Grupo de rotas para cotações
+	quotationGroup := router.Group("/quotations")
+	{
+		quotationGroup.POST("/:id/convert-to-sales-order", salesHandler.ConvertQuotationToSalesOrderHandler)
+		quotationGroup.POST("/:id/simulate", middleware.AuthMiddleware(), salesHandler.SimulateQuotationHandler)
+		quotationGroup.POST("/:id/shipping-rates", shippingHandler.QuoteQuotationRatesHandler)
+		quotationGroup.PUT("/:id/shipping", shippingHandler.SetQuotationShippingHandler)
+		quotationGroup.DELETE("/:id", salesHandler.DeleteQuotationHandler)
+		quotationGroup.POST("/:id/share", middleware.AuthMiddleware(), portalHandler.ShareQuotationHandler)
+		quotationGroup.GET("/:id/responses", middleware.AuthMiddleware(), portalHandler.ListQuotationResponsesHandler)
+		registerTrashRoutes(quotationGroup, trashModels.ResourceQuotations)
+	}
+
+	// Grupo de rotas para pedidos de venda
+	salesOrderGroup := router.Group("/sales-orders", middleware.AuthMiddleware())
+	{
+		salesOrderGroup.GET("/", salesHandler.ListSalesOrdersHandler)
+		salesOrderGroup.GET("/:id/fulfillment", salesHandler.GetSalesOrderFulfillmentHandler)
+		salesOrderGroup.POST("/:id/generate-invoice", salesHandler.GenerateInvoiceHandler)
+		salesOrderGroup.POST("/:id/advance-invoice", salesHandler.GenerateAdvanceInvoiceHandler)
+		salesOrderGroup.POST("/:id/generate-delivery", salesHandler.GenerateDeliveryHandler)
+		salesOrderGroup.POST("/:id/approve-credit", middleware.RBACMiddleware("admin"), salesHandler.ApproveSalesOrderCreditHandler)
+		salesOrderGroup.POST("/:id/shipping-rates", shippingHandler.QuoteSalesOrderRatesHandler)
+		salesOrderGroup.PUT("/:id/shipping", shippingHandler.SetSalesOrderShippingHandler)
+		salesOrderGroup.PUT("/:id/custom-fields", salesHandler.SetSalesOrderCustomFieldsHandler)
+		salesOrderGroup.DELETE("/:id", salesHandler.DeleteSalesOrderHandler)
+		registerTrashRoutes(salesOrderGroup, trashModels.ResourceSalesOrders)
+	}
+
+	// Quadro dos processos de venda por etapa, movimentação entre as colunas e eventos em tempo real
+	salesProcessGroup := router.Group("/sales-processes", middleware.AuthMiddleware())
+	{
+		salesProcessGroup.GET("/board", salesHandler.GetProcessBoardHandler)
+		salesProcessGroup.POST("/:id/move", salesHandler.MoveSalesProcessHandler)
+		salesProcessGroup.GET("/:id/events", salesHandler.StreamSalesProcessEventsHandler)
+		salesProcessGroup.GET("/:id/timeline", salesHandler.GetSalesProcessTimelineHandler)
+		salesProcessGroup.POST("/:id/timeline", salesHandler.AddSalesProcessEventHandler)
+	}
+
+	// Grupo de rotas para faturas
+	invoiceGroup := router.Group("/invoices", middleware.AuthMiddleware())
+	{
+		invoiceGroup.GET("/", salesHandler.ListInvoicesHandler)
+		invoiceGroup.POST("/batch", salesHandler.CreateInvoicesBatchHandler)
+		invoiceGroup.GET("/:id/allocations", salesHandler.GetInvoiceAllocationsHandler)
+		invoiceGroup.GET("/:id/installments", salesHandler.GetInvoiceInstallmentsHandler)
+		invoiceGroup.PUT("/:id/installments", salesHandler.UpdateInvoiceInstallmentsHandler)
+		invoiceGroup.POST("/:id/installments/:number/charges", salesHandler.RegenerateInstallmentChargeHandler)
+		invoiceGroup.GET("/:id/ubl", ediHandler.DownloadInvoiceUBLHandler)
+		invoiceGroup.GET("/:id/margin", salesHandler.GetInvoiceMarginHandler)
+		invoiceGroup.POST("/:id/approve-margin", middleware.RBACMiddleware("admin"), salesHandler.ApproveInvoiceMarginHandler)
+		invoiceGroup.GET("/:id/amount-due", salesHandler.GetInvoiceAmountDueHandler)
+		invoiceGroup.GET("/:id/disputes", salesHandler.GetInvoiceDisputesHandler)
+		invoiceGroup.POST("/:id/disputes", salesHandler.OpenInvoiceDisputeHandler)
+		invoiceGroup.POST("/:id/disputes/:dispute_id/resolve", salesHandler.ResolveInvoiceDisputeHandler)
+		invoiceGroup.DELETE("/:id", salesHandler.DeleteInvoiceHandler)
+		registerTrashRoutes(invoiceGroup, trashModels.ResourceInvoices)
+	}
+
+	// Regras de multa e juros por atraso: a padrão da empresa e as próprias dos contatos
+	lateFeeGroup := router.Group("/late-fee-rules", middleware.AuthMiddleware())
+	{
+		lateFeeGroup.GET("/", salesHandler.ListLateFeeRulesHandler)
+		lateFeeGroup.PUT("/", middleware.RBACMiddleware("admin"), salesHandler.SaveLateFeeRuleHandler)
+		lateFeeGroup.DELETE("/:id", middleware.RBACMiddleware("admin"), salesHandler.DeleteLateFeeRuleHandler)
+	}
+
+	// Contestações das faturas em aberto e resolvidas, para o contas a receber
+	router.GET("/invoice-disputes", middleware.AuthMiddleware(), salesHandler.ListInvoiceDisputesHandler)
+
+	// Grupo de rotas para pagamentos de faturas e a sua alocação entre faturas
+	paymentGroup := router.Group("/payments")
+	{
+		paymentGroup.POST("/batch", salesHandler.CreatePaymentsBatchHandler)
+		paymentGroup.GET("/:id/allocations", salesHandler.GetPaymentAllocationsHandler)
+		paymentGroup.PUT("/:id/allocations", salesHandler.UpdatePaymentAllocationsHandler)
+	}
+
+	// Grupo de rotas para entregas: separação, embalagem, envio e rastreamento nas transportadoras
+	deliveryGroup := router.Group("/deliveries")
+	{
+		deliveryGroup.GET("/", salesHandler.ListDeliveriesHandler)
+		deliveryGroup.POST("/batch/status", salesHandler.UpdateDeliveryStatusesBatchHandler)
+		deliveryGroup.POST("/:id/picking", salesHandler.StartPickingHandler)
+		deliveryGroup.GET("/:id/pick-list", salesHandler.GetPickListHandler)
+		deliveryGroup.PUT("/:id/picks", salesHandler.RecordPicksHandler)
+		deliveryGroup.POST("/:id/packages", salesHandler.PackDeliveryHandler)
+		deliveryGroup.GET("/:id/packages", salesHandler.ListDeliveryPackagesHandler)
+		deliveryGroup.POST("/:id/ship", salesHandler.ShipDeliveryHandler)
+		deliveryGroup.GET("/:id/tracking/events", shippingHandler.GetDeliveryTrackingEventsHandler)
+		deliveryGroup.POST("/:id/tracking/refresh", shippingHandler.RefreshDeliveryTrackingHandler)
+		deliveryGroup.GET("/:id/ubl", ediHandler.DownloadDeliveryUBLHandler)
+		deliveryGroup.DELETE("/:id", salesHandler.DeleteDeliveryHandler)
+		registerTrashRoutes(deliveryGroup, trashModels.ResourceDeliveries)
+	}
+
+	trackingGroup := router.Group("/tracking")
+	{
+		trackingGroup.POST("/poll", shippingHandler.PollTrackingHandler)
+		trackingGroup.POST("/webhooks/:carrier", shippingHandler.CarrierWebhookHandler)
+	}
+
+	// Cotação de frete nas transportadoras
+	router.POST("/shipping/rates", middleware.AuthMiddleware(), shippingHandler.QuoteRatesHandler)
+
+	// Grupo de rotas para devoluções (RMA)
+	returnsGroup := router.Group("/returns", middleware.AuthMiddleware())
+	{
+		returnsGroup.GET("/", returnsHandler.ListReturnsHandler)
+		returnsGroup.POST("/", returnsHandler.CreateReturnHandler)
+		returnsGroup.GET("/analytics", returnsHandler.GetReturnAnalyticsHandler)
+		returnsGroup.GET("/:id", returnsHandler.GetReturnHandler)
+		returnsGroup.POST("/:id/approve", middleware.RBACMiddleware("admin"), returnsHandler.ApproveReturnHandler)
+		returnsGroup.POST("/:id/reject", middleware.RBACMiddleware("admin"), returnsHandler.RejectReturnHandler)
+		returnsGroup.POST("/:id/cancel", returnsHandler.CancelReturnHandler)
+		returnsGroup.POST("/:id/receive", returnsHandler.ReceiveReturnHandler)
+		returnsGroup.POST("/:id/credit-note", middleware.RBACMiddleware("admin", "finance_user"), returnsHandler.IssueReturnCreditNoteHandler)
+	}
+
+	// Grupo de rotas para ordens de serviço (assistência técnica pós-venda)
+	serviceOrdersGroup := router.Group("/service-orders", middleware.AuthMiddleware())
+	{
+		serviceOrdersGroup.GET("/", serviceOrdersHandler.ListServiceOrdersHandler)
+		serviceOrdersGroup.POST("/", serviceOrdersHandler.CreateServiceOrderHandler)
+		serviceOrdersGroup.GET("/:id", serviceOrdersHandler.GetServiceOrderHandler)
+		serviceOrdersGroup.POST("/:id/schedule", serviceOrdersHandler.ScheduleServiceOrderHandler)
+		serviceOrdersGroup.POST("/:id/start", serviceOrdersHandler.StartServiceOrderHandler)
+		serviceOrdersGroup.POST("/:id/complete", serviceOrdersHandler.CompleteServiceOrderHandler)
+		serviceOrdersGroup.POST("/:id/cancel", serviceOrdersHandler.CancelServiceOrderHandler)
+		serviceOrdersGroup.POST("/:id/parts", serviceOrdersHandler.AddServiceOrderPartHandler)
+		serviceOrdersGroup.POST("/:id/labor", serviceOrdersHandler.AddServiceOrderLaborHandler)
+		serviceOrdersGroup.POST("/:id/invoice", serviceOrdersHandler.BillServiceOrderHandler)
+	}
+
+	// Grupo de rotas para anexos e comentários internos de qualquer documento
+	documentsGroup := router.Group("/documents/:entity_type/:entity_id")
+	{
+		documentsGroup.GET("/attachments", attachmentsHandler.ListAttachmentsHandler)
+		documentsGroup.POST("/attachments", attachmentsHandler.UploadAttachmentHandler)
+		documentsGroup.GET("/comments", attachmentsHandler.ListCommentsHandler)
+		documentsGroup.POST("/comments", attachmentsHandler.AddCommentHandler)
+	}
+	router.GET("/attachments/:id/download", attachmentsHandler.DownloadAttachmentHandler)
+	router.DELETE("/attachments/:id", attachmentsHandler.DeleteAttachmentHandler)
+	router.DELETE("/comments/:id", attachmentsHandler.DeleteCommentHandler)
+
+	// Grupo de rotas para o CRM (leads e oportunidades do funil de vendas)
+	crmGroup := router.Group("/crm")
+	{
+		crmGroup.GET("/leads", crmHandler.ListLeadsHandler)
+		crmGroup.POST("/leads", crmHandler.CreateLeadHandler)
+		crmGroup.GET("/leads/:id", crmHandler.GetLeadHandler)
+		crmGroup.PUT("/leads/:id", crmHandler.UpdateLeadHandler)
+		crmGroup.POST("/leads/:id/convert", crmHandler.ConvertLeadHandler)
+		crmGroup.GET("/opportunities", crmHandler.ListOpportunitiesHandler)
+		crmGroup.POST("/opportunities", crmHandler.CreateOpportunityHandler)
+		crmGroup.GET("/opportunities/forecast", crmHandler.GetForecastHandler)
+		crmGroup.GET("/opportunities/:id", crmHandler.GetOpportunityHandler)
+		crmGroup.PUT("/opportunities/:id", crmHandler.UpdateOpportunityHandler)
+		crmGroup.POST("/opportunities/:id/stage", crmHandler.MoveOpportunityHandler)
+		crmGroup.POST("/opportunities/:id/convert", crmHandler.ConvertOpportunityHandler)
+	}
+
+	// Grupo de rotas para metas de vendas e comissões
+	commissionsGroup := router.Group("/commissions", middleware.AuthMiddleware())
+	{
+		commissionsGroup.GET("/rules", commissionsHandler.ListCommissionRulesHandler)
+		commissionsGroup.POST("/rules", commissionsHandler.CreateCommissionRuleHandler)
+		commissionsGroup.PUT("/rules/:id", commissionsHandler.UpdateCommissionRuleHandler)
+		commissionsGroup.DELETE("/rules/:id", commissionsHandler.DeleteCommissionRuleHandler)
+		commissionsGroup.GET("/quotas", commissionsHandler.ListSalesQuotasHandler)
+		commissionsGroup.PUT("/quotas", commissionsHandler.SetSalesQuotaHandler)
+		commissionsGroup.GET("/periods/:period", commissionsHandler.GetCommissionPeriodHandler)
+		commissionsGroup.POST("/periods/:period/calculate", commissionsHandler.CalculateCommissionPeriodHandler)
+		commissionsGroup.POST("/periods/:period/approve", middleware.RBACMiddleware("admin"), commissionsHandler.ApproveCommissionPeriodHandler)
+		commissionsGroup.POST("/periods/:period/close", middleware.RBACMiddleware("admin"), commissionsHandler.CloseCommissionPeriodHandler)
+		commissionsGroup.GET("/statements", commissionsHandler.ListCommissionStatementsHandler)
+		commissionsGroup.GET("/statements/:id", commissionsHandler.GetCommissionStatementHandler)
+		commissionsGroup.GET("/teams", commissionsHandler.ListSalesTeamsHandler)
+		commissionsGroup.POST("/teams", commissionsHandler.CreateSalesTeamHandler)
+		commissionsGroup.GET("/teams/:id", commissionsHandler.GetSalesTeamHandler)
+		commissionsGroup.PUT("/teams/:id", commissionsHandler.UpdateSalesTeamHandler)
+		commissionsGroup.DELETE("/teams/:id", commissionsHandler.DeleteSalesTeamHandler)
+		commissionsGroup.GET("/targets", commissionsHandler.ListSalesTargetsHandler)
+		commissionsGroup.PUT("/targets", commissionsHandler.SetSalesTargetHandler)
+		commissionsGroup.GET("/targets/attainment", commissionsHandler.GetSalesTargetAttainmentHandler)
+		commissionsGroup.DELETE("/targets/:id", commissionsHandler.DeleteSalesTargetHandler)
+	}
+
+	// Grupo de rotas para sugestão automática de compras, para as notas de fornecedores
+	// recebidas por e-mail e para o recebimento com conferência de três vias
+	purchasingGroup := router.Group("/purchasing")
+	{
+		purchasingGroup.POST("/suggestions/generate", purchasingHandler.GenerateSuggestionsHandler)
+		purchasingGroup.GET("/suggestions", purchasingHandler.ListSuggestedOrdersHandler)
+		purchasingGroup.POST("/suggestions/:id/confirm", purchasingHandler.ConfirmSuggestedOrderHandler)
+		purchasingGroup.DELETE("/suggestions/:id", purchasingHandler.DiscardSuggestedOrderHandler)
+		purchasingGroup.GET("/inbound-documents", middleware.AuthMiddleware(), purchasingHandler.ListInboundDocumentsHandler)
+		purchasingGroup.GET("/inbound-documents/:id", middleware.AuthMiddleware(), purchasingHandler.GetInboundDocumentHandler)
+		purchasingGroup.POST("/inbound-documents/:id/approve", middleware.AuthMiddleware(), purchasingHandler.ApproveInboundBillHandler)
+		purchasingGroup.POST("/inbound-documents/:id/resolve", middleware.AuthMiddleware(), purchasingHandler.ResolveInboundDocumentHandler)
+		purchasingGroup.POST("/inbound-documents/:id/discard", middleware.AuthMiddleware(), purchasingHandler.DiscardInboundDocumentHandler)
+		purchasingGroup.POST("/deliveries/:id/receive", middleware.AuthMiddleware(), purchasingHandler.ReceiveDeliveryHandler)
+		purchasingGroup.GET("/orders/:id/receiving", middleware.AuthMiddleware(), purchasingHandler.GetReceivingHandler)
+		purchasingGroup.POST("/bills/:id/match", middleware.AuthMiddleware(), purchasingHandler.MatchBillHandler)
+		purchasingGroup.GET("/bills/:id/match", middleware.AuthMiddleware(), purchasingHandler.GetBillMatchHandler)
+		purchasingGroup.PUT("/bills/:id/items/:item_id/link", middleware.AuthMiddleware(), purchasingHandler.LinkBillItemHandler)
+		purchasingGroup.POST("/bills/:id/match/review", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), purchasingHandler.ReviewBillMatchHandler)
+	}
+
+	// Webhook de e-mail de entrada com as notas fiscais dos fornecedores (autenticado pela
+	// assinatura do provedor, sem token)
+	router.POST("/inbound/email/:provider", purchasingHandler.InboundEmailWebhookHandler)
+
+	// Grupo de rotas para custeio de estoque, CMV, rastreabilidade de lotes e números de série,
+	// depósitos, transferências entre depósitos e inventários físicos
+	inventoryGroup := router.Group("/inventory")
+	{
+		inventoryGroup.GET("/products/:id/costing", inventoryHandler.GetProductCostingHandler)
+		inventoryGroup.PUT("/products/:id/costing", inventoryHandler.SetCostingMethodHandler)
+		inventoryGroup.POST("/purchase-orders/:id/receive", inventoryHandler.ReceivePurchaseOrderHandler)
+		inventoryGroup.POST("/deliveries/:id/cogs", inventoryHandler.RecordDeliveryCOGSHandler)
+		inventoryGroup.POST("/invoices/:id/cogs", inventoryHandler.RecordInvoiceCOGSHandler)
+		inventoryGroup.GET("/sales-orders/:id/cogs", inventoryHandler.GetSalesOrderCOGSHandler)
+		inventoryGroup.POST("/deliveries/:id/serials", inventoryHandler.CaptureDeliverySerialsHandler)
+		inventoryGroup.GET("/lots/expiring", inventoryHandler.ListExpiringLotsHandler)
+		inventoryGroup.GET("/lots/:lot", inventoryHandler.GetLotTraceHandler)
+		inventoryGroup.GET("/bins", inventoryHandler.ListBinsHandler)
+		inventoryGroup.POST("/bins", inventoryHandler.CreateBinHandler)
+		inventoryGroup.PUT("/bins/:id", inventoryHandler.UpdateBinHandler)
+		inventoryGroup.DELETE("/bins/:id", inventoryHandler.DeleteBinHandler)
+		inventoryGroup.GET("/warehouses", inventoryHandler.ListWarehousesHandler)
+		inventoryGroup.POST("/warehouses", inventoryHandler.CreateWarehouseHandler)
+		inventoryGroup.PUT("/warehouses/:id", inventoryHandler.UpdateWarehouseHandler)
+		inventoryGroup.GET("/warehouses/:id/stock", inventoryHandler.ListWarehouseStockHandler)
+		inventoryGroup.POST("/warehouses/:id/counts", inventoryHandler.CountWarehouseStockHandler)
+		inventoryGroup.GET("/warehouses/:id/movements", inventoryHandler.ListStockMovementsHandler)
+		inventoryGroup.GET("/transfers", inventoryHandler.ListTransfersHandler)
+		inventoryGroup.POST("/transfers", inventoryHandler.RequestTransferHandler)
+		inventoryGroup.GET("/transfers/:id", inventoryHandler.GetTransferHandler)
+		inventoryGroup.POST("/transfers/:id/ship", inventoryHandler.ShipTransferHandler)
+		inventoryGroup.POST("/transfers/:id/receive", inventoryHandler.ReceiveTransferHandler)
+		inventoryGroup.POST("/transfers/:id/cancel", inventoryHandler.CancelTransferHandler)
+		inventoryGroup.GET("/stocktakes", inventoryHandler.ListStocktakesHandler)
+		inventoryGroup.POST("/stocktakes", inventoryHandler.CreateStocktakeHandler)
+		inventoryGroup.GET("/stocktakes/:id", inventoryHandler.GetStocktakeHandler)
+		inventoryGroup.GET("/stocktakes/:id/sheet", inventoryHandler.GetCountSheetHandler)
+		inventoryGroup.POST("/stocktakes/:id/counts", inventoryHandler.AddStocktakeCountHandler)
+		inventoryGroup.POST("/stocktakes/:id/scan", inventoryHandler.ScanStocktakeCountHandler)
+		inventoryGroup.DELETE("/stocktakes/:id/counts/:count_id", inventoryHandler.DeleteStocktakeCountHandler)
+		inventoryGroup.GET("/stocktakes/:id/variances", inventoryHandler.GetStocktakeVariancesHandler)
+		inventoryGroup.POST("/stocktakes/:id/approve", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), inventoryHandler.ApproveStocktakeHandler)
+		inventoryGroup.POST("/stocktakes/:id/cancel", inventoryHandler.CancelStocktakeHandler)
+	}
+
+	// Grupo de rotas para etiquetas de código de barras/QR (PNG ou PDF)
+	labelsGroup := router.Group("/labels")
+	{
+		labelsGroup.GET("/products/:id", labelsHandler.ProductLabelHandler)
+		labelsGroup.GET("/deliveries/:id", labelsHandler.DeliveryLabelHandler)
+		labelsGroup.GET("/bins/:id", labelsHandler.BinLabelHandler)
+	}
+
+	// Leitura de códigos pelos aplicativos de armazém (recebimento, separação e conferência)
+	router.GET("/scan/:code", middleware.AuthMiddleware(), labelsHandler.ScanHandler)
+
+	// Rastreabilidade por número de série (recebimento, entrega, faturas e cliente)
+	router.GET("/serials/:sn", middleware.AuthMiddleware(), inventoryHandler.GetSerialTraceHandler)
+
+	// Grupo de rotas para conciliação bancária
+	bankStatementGroup := router.Group("/bank-statements")
+	{
+		bankStatementGroup.GET("/", bankingHandler.ListBankStatementsHandler)
+		bankStatementGroup.POST("/import", bankingHandler.ImportBankStatementHandler)
+		bankStatementGroup.GET("/:id", bankingHandler.GetBankStatementHandler)
+		bankStatementGroup.POST("/:id/suggestions", bankingHandler.SuggestReconciliationsHandler)
+	}
+
+	bankLineGroup := router.Group("/bank-statement-lines")
+	{
+		bankLineGroup.POST("/:id/confirm", bankingHandler.ConfirmBankLineHandler)
+		bankLineGroup.POST("/:id/match", bankingHandler.MatchBankLineHandler)
+		bankLineGroup.POST("/:id/unmatch", bankingHandler.UnmatchBankLineHandler)
+		bankLineGroup.POST("/:id/ignore", bankingHandler.IgnoreBankLineHandler)
+	}
+
+	// Cobrança registrada: remessas CNAB 240 dos boletos e processamento dos retornos do banco
+	collectionGroup := router.Group("/collection", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin", "finance_user"))
+	{
+		collectionGroup.GET("/remittances", bankingHandler.ListRemittancesHandler)
+		collectionGroup.POST("/remittances", bankingHandler.GenerateRemittanceHandler)
+		collectionGroup.GET("/remittances/:id", bankingHandler.GetRemittanceHandler)
+		collectionGroup.GET("/remittances/:id/file", bankingHandler.DownloadRemittanceHandler)
+		collectionGroup.GET("/returns", bankingHandler.ListBankReturnsHandler)
+		collectionGroup.POST("/returns", bankingHandler.ProcessBankReturnHandler)
+		collectionGroup.GET("/returns/:id", bankingHandler.GetBankReturnHandler)
+		collectionGroup.GET("/boletos", bankingHandler.ListBoletosHandler)
+		collectionGroup.POST("/boletos", bankingHandler.RegisterBoletosHandler)
+		collectionGroup.GET("/boletos/:id", bankingHandler.GetBoletoHandler)
+		collectionGroup.GET("/boletos/:id/pdf", bankingHandler.DownloadBoletoPDFHandler)
+		collectionGroup.POST("/boletos/:id/instructions", bankingHandler.SendBoletoInstructionHandler)
+		collectionGroup.GET("/pix/charges", bankingHandler.ListPixChargesHandler)
+		collectionGroup.POST("/pix/charges", bankingHandler.CreatePixChargeHandler)
+		collectionGroup.GET("/pix/charges/:id", bankingHandler.GetPixChargeHandler)
+		collectionGroup.GET("/pix/charges/:id/qrcode", bankingHandler.GetPixChargeQRCodeHandler)
+	}
+
+	// Webhook dos PSPs Pix, autenticado pelo token do caminho
+	pixWebhookGroup := router.Group("/pix/webhook")
+	{
+		pixWebhookGroup.POST("/:token", bankingHandler.PixWebhookHandler)
+		pixWebhookGroup.POST("/:token/pix", bankingHandler.PixWebhookHandler)
+	}
+
 	// Dentro de SetupRoutes:
 	router.GET("/dashboard", dashboardHandler.DashboardHandler)
 
+	// Previsões de vendas e segmentação RFM dos clientes (o recálculo manual é restrito a administradores)
+	analyticsGroup := router.Group("/analytics", middleware.AuthMiddleware())
+	{
+		analyticsGroup.GET("/forecast", analyticsHandler.GetSalesForecastHandler)
+		analyticsGroup.GET("/rfm", analyticsHandler.ListRFMScoresHandler)
+		analyticsGroup.GET("/rfm/summary", analyticsHandler.GetRFMSummaryHandler)
+		analyticsGroup.POST("/rfm/score", middleware.RBACMiddleware("admin"), analyticsHandler.ScoreRFMHandler)
+	}
+
+	// Feature flags alteráveis sem novo deploy (restrito a administradores)
+	featureFlagGroup := router.Group("/feature-flags", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		featureFlagGroup.GET("/", featureFlagsHandler.ListFeatureFlagsHandler)
+		featureFlagGroup.PUT("/:name", featureFlagsHandler.UpdateFeatureFlagHandler)
+		featureFlagGroup.DELETE("/:name", featureFlagsHandler.ResetFeatureFlagHandler)
+	}
+
+	// Relatórios compostos pelos administradores (entidade, filtros, agrupamentos e agregações),
+	// executados sob demanda em JSON, CSV ou PDF e enviados por e-mail nos agendamentos
+	reportGroup := router.Group("/reports", middleware.AuthMiddleware())
+	{
+		reportGroup.GET("/entities", reportsHandler.ListReportEntitiesHandler)
+		reportGroup.GET("/", reportsHandler.ListReportsHandler)
+		reportGroup.GET("/:id", reportsHandler.GetReportHandler)
+		reportGroup.POST("/:id/run", reportsHandler.RunReportHandler)
+		reportGroup.POST("/", middleware.RBACMiddleware("admin"), reportsHandler.CreateReportHandler)
+		reportGroup.PUT("/:id", middleware.RBACMiddleware("admin"), reportsHandler.UpdateReportHandler)
+		reportGroup.DELETE("/:id", middleware.RBACMiddleware("admin"), reportsHandler.DeleteReportHandler)
+		reportGroup.GET("/:id/schedules", middleware.RBACMiddleware("admin"), reportsHandler.ListReportSchedulesHandler)
+		reportGroup.POST("/:id/schedules", middleware.RBACMiddleware("admin"), reportsHandler.CreateReportScheduleHandler)
+		reportGroup.DELETE("/:id/schedules/:schedule_id", middleware.RBACMiddleware("admin"), reportsHandler.DeleteReportScheduleHandler)
+	}
+
+	// Fluxo de caixa projetado por semana a partir dos documentos em aberto, das locações e dos
+	// lançamentos previstos cadastrados pelos administradores; DRE por período e centro de custo;
+	// cotações diárias das moedas e reavaliação cambial das faturas em moeda estrangeira
+	financeGroup := router.Group("/finance", middleware.AuthMiddleware())
+	{
+		financeGroup.GET("/cashflow", financeHandler.GetCashflowHandler)
+		financeGroup.GET("/cashflow/items", financeHandler.ListCashflowItemsHandler)
+		financeGroup.POST("/cashflow/items", middleware.RBACMiddleware("admin"), financeHandler.CreateCashflowItemHandler)
+		financeGroup.DELETE("/cashflow/items/:id", middleware.RBACMiddleware("admin"), financeHandler.DeleteCashflowItemHandler)
+		financeGroup.GET("/dre", financeHandler.GetDREHandler)
+		financeGroup.GET("/cost-centers", financeHandler.ListCostCentersHandler)
+		financeGroup.POST("/cost-centers", middleware.RBACMiddleware("admin"), financeHandler.CreateCostCenterHandler)
+		financeGroup.PUT("/cost-centers/:id", middleware.RBACMiddleware("admin"), financeHandler.UpdateCostCenterHandler)
+		financeGroup.PUT("/documents/:type/:id/cost-center", financeHandler.AssignCostCenterHandler)
+		financeGroup.GET("/budgets", financeHandler.ListBudgetsHandler)
+		financeGroup.GET("/budgets/variance", financeHandler.GetBudgetVarianceHandler)
+		financeGroup.POST("/budgets", middleware.RBACMiddleware("admin"), financeHandler.CreateBudgetHandler)
+		financeGroup.PUT("/budgets/:id", middleware.RBACMiddleware("admin"), financeHandler.UpdateBudgetHandler)
+		financeGroup.DELETE("/budgets/:id", middleware.RBACMiddleware("admin"), financeHandler.DeleteBudgetHandler)
+		financeGroup.GET("/exchange-rates", financeHandler.ListExchangeRatesHandler)
+		financeGroup.GET("/exchange-rates/lookup", financeHandler.LookupExchangeRateHandler)
+		financeGroup.POST("/exchange-rates", middleware.RBACMiddleware("admin", "finance_user"), financeHandler.SetExchangeRateHandler)
+		financeGroup.POST("/exchange-rates/fetch", middleware.RBACMiddleware("admin"), financeHandler.FetchExchangeRatesHandler)
+		financeGroup.GET("/invoices/:id/currency", financeHandler.GetInvoiceCurrencyHandler)
+		financeGroup.PUT("/invoices/:id/currency", middleware.RBACMiddleware("admin", "finance_user"), financeHandler.SetInvoiceCurrencyHandler)
+		financeGroup.GET("/exchange-revaluations/:period", financeHandler.GetRevaluationsHandler)
+		financeGroup.POST("/exchange-revaluations/:period", middleware.RBACMiddleware("admin", "finance_user"), financeHandler.RevalueForeignInvoicesHandler)
+	}
+
+	// Contratos com clientes e fornecedores: preços fixos aplicados nas cotações e pedidos de
+	// compra, volumes mínimos e alertas de vencimento
+	contractGroup := router.Group("/contracts", middleware.AuthMiddleware())
+	{
+		contractGroup.GET("/", contractsHandler.ListContractsHandler)
+		contractGroup.GET("/expiring", contractsHandler.ListExpiringContractsHandler)
+		contractGroup.GET("/:id", contractsHandler.GetContractHandler)
+		contractGroup.GET("/:id/volumes", contractsHandler.GetContractVolumesHandler)
+		contractGroup.POST("/", middleware.RBACMiddleware("admin"), contractsHandler.CreateContractHandler)
+		contractGroup.PUT("/:id", middleware.RBACMiddleware("admin"), contractsHandler.UpdateContractHandler)
+		contractGroup.POST("/:id/cancel", middleware.RBACMiddleware("admin"), contractsHandler.CancelContractHandler)
+	}
+
+	// Despesas dos colaboradores: lançamento com comprovante, aprovação pelos gestores, reembolso
+	// pelo financeiro e relatório mensal por categoria
+	expenseGroup := router.Group("/expenses", middleware.AuthMiddleware())
+	{
+		expenseGroup.GET("/", expensesHandler.ListExpensesHandler)
+		expenseGroup.POST("/", expensesHandler.CreateExpenseHandler)
+		expenseGroup.GET("/categories", expensesHandler.ListCategoriesHandler)
+		expenseGroup.POST("/categories", middleware.RBACMiddleware("admin"), expensesHandler.CreateCategoryHandler)
+		expenseGroup.PUT("/categories/:id", middleware.RBACMiddleware("admin"), expensesHandler.UpdateCategoryHandler)
+		expenseGroup.GET("/reports/monthly", middleware.RBACMiddleware("admin", "finance_user"), expensesHandler.GetMonthlyReportHandler)
+		expenseGroup.GET("/:id", expensesHandler.GetExpenseHandler)
+		expenseGroup.PUT("/:id", expensesHandler.UpdateExpenseHandler)
+		expenseGroup.DELETE("/:id", expensesHandler.DeleteExpenseHandler)
+		expenseGroup.POST("/:id/submit", expensesHandler.SubmitExpenseHandler)
+		expenseGroup.POST("/:id/approve", middleware.RBACMiddleware("admin"), expensesHandler.ApproveExpenseHandler)
+		expenseGroup.POST("/:id/reject", middleware.RBACMiddleware("admin"), expensesHandler.RejectExpenseHandler)
+		expenseGroup.POST("/:id/reimburse", middleware.RBACMiddleware("admin", "finance_user"), expensesHandler.ReimburseExpenseHandler)
+	}
+
+	// Ativo imobilizado: cadastro dos bens (inclusive a partir de pedidos de compra), local e
+	// responsável, depreciação linear mensal e baixa por venda ou perda
+	assetGroup := router.Group("/assets", middleware.AuthMiddleware())
+	{
+		assetGroup.GET("/", assetsHandler.ListAssetsHandler)
+		assetGroup.POST("/", middleware.RBACMiddleware("admin", "finance_user"), assetsHandler.CreateAssetHandler)
+		assetGroup.POST("/depreciation/run", middleware.RBACMiddleware("admin", "finance_user"), assetsHandler.RunDepreciationHandler)
+		assetGroup.GET("/:id", assetsHandler.GetAssetHandler)
+		assetGroup.PUT("/:id", middleware.RBACMiddleware("admin", "finance_user"), assetsHandler.UpdateAssetHandler)
+		assetGroup.POST("/:id/transfer", middleware.RBACMiddleware("admin", "finance_user"), assetsHandler.TransferAssetHandler)
+		assetGroup.GET("/:id/schedule", assetsHandler.GetDepreciationScheduleHandler)
+		assetGroup.POST("/:id/dispose", middleware.RBACMiddleware("admin", "finance_user"), assetsHandler.DisposeAssetHandler)
+	}
+
+	// Colaboradores e departamentos (à parte dos usuários), vínculo dos colaboradores com os
+	// documentos, rateio mensal da mão de obra por centro de custo e rentabilidade por departamento
+	hrGroup := router.Group("/hr", middleware.AuthMiddleware())
+	{
+		hrGroup.GET("/departments", hrHandler.ListDepartmentsHandler)
+		hrGroup.POST("/departments", middleware.RBACMiddleware("admin"), hrHandler.CreateDepartmentHandler)
+		hrGroup.PUT("/departments/:id", middleware.RBACMiddleware("admin"), hrHandler.UpdateDepartmentHandler)
+		hrGroup.GET("/employees", middleware.RBACMiddleware("admin", "finance_user"), hrHandler.ListEmployeesHandler)
+		hrGroup.POST("/employees", middleware.RBACMiddleware("admin"), hrHandler.CreateEmployeeHandler)
+		hrGroup.GET("/employees/:id", middleware.RBACMiddleware("admin", "finance_user"), hrHandler.GetEmployeeHandler)
+		hrGroup.PUT("/employees/:id", middleware.RBACMiddleware("admin"), hrHandler.UpdateEmployeeHandler)
+		hrGroup.GET("/employees/:id/documents", hrHandler.GetEmployeeDocumentsHandler)
+		hrGroup.PUT("/documents/:type/:id/employee", middleware.RBACMiddleware("admin"), hrHandler.AssignEmployeeHandler)
+		hrGroup.POST("/labor-costs/allocate", middleware.RBACMiddleware("admin", "finance_user"), hrHandler.AllocateLaborCostsHandler)
+		hrGroup.GET("/labor-costs", middleware.RBACMiddleware("admin", "finance_user"), hrHandler.ListLaborCostsHandler)
+		hrGroup.GET("/reports/profitability", middleware.RBACMiddleware("admin", "finance_user"), hrHandler.GetProfitabilityReportHandler)
+	}
+
+	// Prazos de SLA das entregas e processos de venda, avaliados periodicamente, e relatório de
+	// cumprimento por período
+	slaGroup := router.Group("/sla", middleware.AuthMiddleware())
+	{
+		slaGroup.GET("/definitions", slaHandler.ListSLADefinitionsHandler)
+		slaGroup.POST("/definitions", middleware.RBACMiddleware("admin"), slaHandler.CreateSLADefinitionHandler)
+		slaGroup.PUT("/definitions/:id", middleware.RBACMiddleware("admin"), slaHandler.UpdateSLADefinitionHandler)
+		slaGroup.DELETE("/definitions/:id", middleware.RBACMiddleware("admin"), slaHandler.DeleteSLADefinitionHandler)
+		slaGroup.POST("/evaluate", middleware.RBACMiddleware("admin"), slaHandler.EvaluateSLAHandler)
+		slaGroup.GET("/compliance", slaHandler.GetSLAComplianceHandler)
+	}
+
+	// Regras de acompanhamento dos processos de venda parados: tarefas de retorno para o vendedor
+	// (no módulo de tarefas), lembretes e cancelamento automático, com o registro das ações
+	followupGroup := router.Group("/followup", middleware.AuthMiddleware())
+	{
+		followupGroup.GET("/rules", followupHandler.ListFollowupRulesHandler)
+		followupGroup.POST("/rules", middleware.RBACMiddleware("admin"), followupHandler.CreateFollowupRuleHandler)
+		followupGroup.PUT("/rules/:id", middleware.RBACMiddleware("admin"), followupHandler.UpdateFollowupRuleHandler)
+		followupGroup.DELETE("/rules/:id", middleware.RBACMiddleware("admin"), followupHandler.DeleteFollowupRuleHandler)
+		followupGroup.POST("/run", middleware.RBACMiddleware("admin"), followupHandler.RunFollowupHandler)
+		followupGroup.GET("/log", followupHandler.ListFollowupLogHandler)
+	}
+
+	// Tarefas dos usuários ligadas aos registros do ERP, manuais ou criadas pelas automações
+	// (acompanhamento, aprovações e cobrança), com a visão das minhas tarefas
+	tasksGroup := router.Group("/tasks", middleware.AuthMiddleware())
+	{
+		tasksGroup.GET("/", taskHandler.ListTasksHandler)
+		tasksGroup.GET("/mine", taskHandler.GetMyTasksHandler)
+		tasksGroup.GET("/:id", taskHandler.GetTaskHandler)
+		tasksGroup.POST("/", taskHandler.CreateTaskHandler)
+		tasksGroup.PUT("/:id", taskHandler.UpdateTaskHandler)
+		tasksGroup.POST("/:id/complete", taskHandler.CompleteTaskHandler)
+		tasksGroup.POST("/:id/cancel", taskHandler.CancelTaskHandler)
+	}
+
+	// Calendário de dias úteis da empresa (expediente e feriados), usado nos vencimentos das
+	// faturas, nos prazos de SLA e na estimativa de entrega dos fretes
+	calendarGroup := router.Group("/calendar", middleware.AuthMiddleware())
+	{
+		calendarGroup.GET("/", calendarHandler.GetCalendarHandler)
+		calendarGroup.PUT("/", middleware.RBACMiddleware("admin"), calendarHandler.UpdateCalendarHandler)
+		calendarGroup.GET("/holidays", calendarHandler.ListHolidaysHandler)
+		calendarGroup.POST("/holidays", middleware.RBACMiddleware("admin"), calendarHandler.CreateHolidayHandler)
+		calendarGroup.PUT("/holidays/:id", middleware.RBACMiddleware("admin"), calendarHandler.UpdateHolidayHandler)
+		calendarGroup.DELETE("/holidays/:id", middleware.RBACMiddleware("admin"), calendarHandler.DeleteHolidayHandler)
+		calendarGroup.POST("/add-business-days", calendarHandler.AddBusinessDaysHandler)
+	}
+
+	// Campos ocultos ou mascarados por perfil nas respostas da API (restrito a administradores)
+	fieldPermissionGroup := router.Group("/field-permissions", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		fieldPermissionGroup.GET("/", fieldPermissionsHandler.ListFieldPermissionsHandler)
+		fieldPermissionGroup.PUT("/:role/:field", fieldPermissionsHandler.SetFieldPermissionHandler)
+		fieldPermissionGroup.DELETE("/:role/:field", fieldPermissionsHandler.RemoveFieldPermissionHandler)
+	}
+
+	// Campos personalizados de produtos, contatos e pedidos de venda (definições restritas a administradores)
+	customFieldGroup := router.Group("/custom-fields", middleware.AuthMiddleware())
+	{
+		customFieldGroup.GET("/", customFieldsHandler.ListCustomFieldsHandler)
+		customFieldGroup.POST("/", middleware.RBACMiddleware("admin"), customFieldsHandler.CreateCustomFieldHandler)
+		customFieldGroup.PUT("/:id", middleware.RBACMiddleware("admin"), customFieldsHandler.UpdateCustomFieldHandler)
+		customFieldGroup.DELETE("/:id", middleware.RBACMiddleware("admin"), customFieldsHandler.DeleteCustomFieldHandler)
+	}
+
+	// Modelos de documento com versões e pré-visualização (alterações restritas a administradores)
+	templateGroup := router.Group("/templates", middleware.AuthMiddleware())
+	{
+		templateGroup.GET("/", templatesHandler.ListTemplatesHandler)
+		templateGroup.GET("/:key", templatesHandler.GetTemplateHandler)
+		templateGroup.PUT("/:key", middleware.RBACMiddleware("admin"), templatesHandler.SaveTemplateHandler)
+		templateGroup.GET("/:key/versions", templatesHandler.ListTemplateVersionsHandler)
+		templateGroup.POST("/:key/versions/:version/restore", middleware.RBACMiddleware("admin"), templatesHandler.RestoreTemplateVersionHandler)
+		templateGroup.POST("/:key/preview", templatesHandler.PreviewTemplateHandler)
+	}
+
+	// Rótulos traduzidos dos status e enumerações (pt-BR ou en-US)
+	i18nGroup := router.Group("/i18n", middleware.AuthMiddleware())
+	{
+		i18nGroup.GET("/labels", i18nHandler.ListLabelsHandler)
+	}
+
+	// Registro dos status dos documentos com rótulos e transições permitidas, para os clientes não
+	// repetirem as constantes do backend
+	metaGroup := router.Group("/meta", middleware.AuthMiddleware())
+	{
+		metaGroup.GET("/enums", metaHandler.ListEnumsHandler)
+	}
+
+	// Gestão de usuários da empresa: convites, perfis de acesso e situação das contas (restrito a administradores)
+	userGroup := router.Group("/users", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		userGroup.GET("/", usersHandler.ListUsersHandler)
+		userGroup.GET("/invitations", usersHandler.ListInvitationsHandler)
+		userGroup.POST("/invitations", usersHandler.InviteUserHandler)
+		userGroup.GET("/:id", usersHandler.GetUserHandler)
+		userGroup.PUT("/:id/role", usersHandler.AssignRoleHandler)
+		userGroup.POST("/:id/deactivate", usersHandler.DeactivateUserHandler)
+		userGroup.POST("/:id/activate", usersHandler.ActivateUserHandler)
+		userGroup.POST("/:id/unlock", usersHandler.UnlockUserHandler)
+	}
+
+	// Chaves de API das integrações: emissão, revogação e uso (restrito a administradores)
+	apiKeyGroup := router.Group("/api-keys", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		apiKeyGroup.GET("/", apiKeysHandler.ListAPIKeysHandler)
+		apiKeyGroup.POST("/", apiKeysHandler.CreateAPIKeyHandler)
+		apiKeyGroup.GET("/:id", apiKeysHandler.GetAPIKeyHandler)
+		apiKeyGroup.GET("/:id/usage", apiKeysHandler.GetAPIKeyUsageHandler)
+		apiKeyGroup.POST("/:id/revoke", apiKeysHandler.RevokeAPIKeyHandler)
+	}
+
+	// Canais de e-commerce: cadastro das lojas e sincronização manual (restrito a administradores)
+	ecommerceGroup := router.Group("/ecommerce/channels", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		ecommerceGroup.GET("/", ecommerceHandler.ListChannelsHandler)
+		ecommerceGroup.POST("/", ecommerceHandler.CreateChannelHandler)
+		ecommerceGroup.PUT("/:id", ecommerceHandler.UpdateChannelHandler)
+		ecommerceGroup.POST("/:id/sync", ecommerceHandler.SyncChannelHandler)
+		ecommerceGroup.GET("/:id/runs", ecommerceHandler.ListSyncRunsHandler)
+	}
+
+	// Parceiros EDI: faturas e entregas em UBL enviadas por SFTP (restrito a administradores)
+	ediGroup := router.Group("/edi/partners", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		ediGroup.GET("/", ediHandler.ListPartnersHandler)
+		ediGroup.POST("/", ediHandler.CreatePartnerHandler)
+		ediGroup.PUT("/:id", ediHandler.UpdatePartnerHandler)
+		ediGroup.POST("/:id/push", ediHandler.PushDocumentHandler)
+		ediGroup.GET("/:id/transmissions", ediHandler.ListTransmissionsHandler)
+	}
+
+	// Troca de arquivos com parceiros por SFTP/FTP: servidores, rotinas periódicas de envio e
+	// coleta e a fila de execuções (restrito a administradores)
+	fileExchangeGroup := router.Group("/file-exchange", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		fileExchangeGroup.GET("/endpoints", fileExchangeHandler.ListEndpointsHandler)
+		fileExchangeGroup.POST("/endpoints", fileExchangeHandler.CreateEndpointHandler)
+		fileExchangeGroup.PUT("/endpoints/:id", fileExchangeHandler.UpdateEndpointHandler)
+		fileExchangeGroup.GET("/schedules", fileExchangeHandler.ListSchedulesHandler)
+		fileExchangeGroup.POST("/schedules", fileExchangeHandler.CreateScheduleHandler)
+		fileExchangeGroup.PUT("/schedules/:id", fileExchangeHandler.UpdateScheduleHandler)
+		fileExchangeGroup.POST("/schedules/:id/run", fileExchangeHandler.RunScheduleHandler)
+		fileExchangeGroup.GET("/schedules/:id/runs", fileExchangeHandler.ListRunsHandler)
+		fileExchangeGroup.GET("/runs/:id", fileExchangeHandler.GetRunHandler)
+		fileExchangeGroup.POST("/runs/:id/retry", fileExchangeHandler.RetryRunHandler)
+	}
+
+	// Importações configuráveis (ETL): mapeamentos de arquivos CSV/JSON e execuções (restrito a administradores)
+	etlGroup := router.Group("/etl", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		etlGroup.GET("/mappings", etlHandler.ListMappingsHandler)
+		etlGroup.POST("/mappings", etlHandler.CreateMappingHandler)
+		etlGroup.GET("/mappings/:id", etlHandler.GetMappingHandler)
+		etlGroup.PUT("/mappings/:id", etlHandler.UpdateMappingHandler)
+		etlGroup.DELETE("/mappings/:id", etlHandler.DeleteMappingHandler)
+		etlGroup.POST("/runs", etlHandler.CreateRunHandler)
+		etlGroup.GET("/runs", etlHandler.ListRunsHandler)
+		etlGroup.GET("/runs/:id", etlHandler.GetRunHandler)
+	}
+
+	// Rotas das integrações (e-commerce, BI), autenticadas pelo header X-API-Key com o escopo de cada rota
+	integrationGroup := router.Group("/integrations")
+	{
+		integrationGroup.GET("/products", middleware.APIKeyMiddleware(apiKeysModels.ScopeProductsRead), productsHandler.ListProductsHandler)
+		integrationGroup.GET("/products/:id", middleware.APIKeyMiddleware(apiKeysModels.ScopeProductsRead), productsHandler.GetProductByIDHandler)
+		integrationGroup.GET("/contacts", middleware.APIKeyMiddleware(apiKeysModels.ScopeContactsRead), contactHandler.ListContactsHandler)
+		integrationGroup.GET("/contacts/:id", middleware.APIKeyMiddleware(apiKeysModels.ScopeContactsRead), contactHandler.GetContactByIDHandler)
+		integrationGroup.POST("/contacts", middleware.APIKeyMiddleware(apiKeysModels.ScopeContactsWrite), contactHandler.CreateContactHandler)
+		integrationGroup.GET("/sales-orders", middleware.APIKeyMiddleware(apiKeysModels.ScopeSalesRead), salesHandler.ListSalesOrdersHandler)
+		integrationGroup.GET("/invoices", middleware.APIKeyMiddleware(apiKeysModels.ScopeSalesRead), salesHandler.ListInvoicesHandler)
+		integrationGroup.GET("/deliveries", middleware.APIKeyMiddleware(apiKeysModels.ScopeSalesRead), salesHandler.ListDeliveriesHandler)
+		integrationGroup.POST("/invoices/batch", middleware.APIKeyMiddleware(apiKeysModels.ScopeSalesWrite), salesHandler.CreateInvoicesBatchHandler)
+		integrationGroup.POST("/payments/batch", middleware.APIKeyMiddleware(apiKeysModels.ScopeSalesWrite), salesHandler.CreatePaymentsBatchHandler)
+		integrationGroup.POST("/deliveries/batch/status", middleware.APIKeyMiddleware(apiKeysModels.ScopeSalesWrite), salesHandler.UpdateDeliveryStatusesBatchHandler)
+		integrationGroup.GET("/dashboard", middleware.APIKeyMiddleware(apiKeysModels.ScopeReportsRead), dashboardHandler.DashboardHandler)
+	}
+
+	// Portal do cliente: o código de acesso é trocado por um token de escopo "portal", que só
+	// abre estas rotas e enxerga apenas os documentos do próprio contato
+	router.POST("/portal/auth/token", portalHandler.CreatePortalSessionHandler)
+	portalGroup := router.Group("/portal", middleware.PortalAuthMiddleware())
+	{
+		portalGroup.GET("/quotations", portalHandler.ListQuotationsHandler)
+		portalGroup.GET("/quotations/:id", portalHandler.GetQuotationHandler)
+		portalGroup.POST("/quotations/:id/accept", portalHandler.AcceptQuotationHandler)
+		portalGroup.POST("/quotations/:id/reject", portalHandler.RejectQuotationHandler)
+		portalGroup.GET("/invoices", portalHandler.ListInvoicesHandler)
+		portalGroup.GET("/invoices/:id", portalHandler.GetInvoiceHandler)
+		portalGroup.GET("/invoices/:id/pdf", portalHandler.DownloadInvoicePDFHandler)
+		portalGroup.GET("/invoices/:id/pix", portalHandler.GetInvoicePixHandler)
+		portalGroup.GET("/invoices/:id/pix/qrcode", portalHandler.GetInvoicePixQRCodeHandler)
+		portalGroup.GET("/deliveries", portalHandler.ListDeliveriesHandler)
+		portalGroup.GET("/tickets", portalHandler.ListTicketsHandler)
+		portalGroup.POST("/tickets", portalHandler.CreateTicketHandler)
+	}
+
+	// Link público da cotação: o token assinado identifica a cotação e a empresa, sem login
+	publicQuotationGroup := router.Group("/public/quotations")
+	{
+		publicQuotationGroup.GET("/:token", portalHandler.GetSharedQuotationHandler)
+		publicQuotationGroup.POST("/:token/accept", portalHandler.AcceptSharedQuotationHandler)
+		publicQuotationGroup.POST("/:token/reject", portalHandler.RejectSharedQuotationHandler)
+	}
+
+	// Consultas GraphQL de leitura para as telas com dados aninhados (as gravações seguem na API REST)
+	router.POST("/graphql", middleware.AuthMiddleware(), graphqlHandler.GraphQLHandler)
+
+	// Empresas (tenants) do ERP (restrito a administradores)
+	companyGroup := router.Group("/companies", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		companyGroup.GET("/", companiesHandler.ListCompaniesHandler)
+		companyGroup.GET("/:id", companiesHandler.GetCompanyHandler)
+		companyGroup.POST("/", companiesHandler.CreateCompanyHandler)
+		companyGroup.PUT("/:id", companiesHandler.UpdateCompanyHandler)
+	}
+
+	// Backups do banco (pg_dump) e restaurações, de todas as empresas, e exportação completa dos
+	// dados da empresa para o encerramento da conta, processados pela fila (restrito a administradores)
+	adminGroup := router.Group("/admin", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"))
+	{
+		adminGroup.GET("/backups", backupsHandler.ListBackupsHandler)
+		adminGroup.POST("/backups", backupsHandler.RequestBackupHandler)
+		adminGroup.GET("/backups/restores", backupsHandler.ListRestoresHandler)
+		adminGroup.GET("/backups/:id", backupsHandler.GetBackupHandler)
+		adminGroup.POST("/backups/:id/restore", backupsHandler.RestoreBackupHandler)
+		adminGroup.GET("/exports", backupsHandler.ListExportsHandler)
+		adminGroup.POST("/exports", backupsHandler.RequestExportHandler)
+		adminGroup.GET("/exports/:id", backupsHandler.GetExportHandler)
+		adminGroup.GET("/exports/:id/download", backupsHandler.DownloadExportHandler)
+	}
+
+}
+
+// registerTrashRoutes registra a lixeira do recurso: listagem, restauração e exclusão
+// definitiva, todas autenticadas e a última restrita a administradores
+func registerTrashRoutes(group *gin.RouterGroup, resource string) {
+	group.GET("/trash", middleware.AuthMiddleware(), trashHandler.ListTrashHandler(resource))
+	group.POST("/:id/restore", middleware.AuthMiddleware(), trashHandler.RestoreHandler(resource))
+	group.DELETE("/:id/permanent", middleware.AuthMiddleware(), middleware.RBACMiddleware("admin"), trashHandler.PurgeHandler(resource))
 }
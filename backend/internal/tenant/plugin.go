@@ -0,0 +1,114 @@
+package tenant
+
+import (
+	"reflect"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Plugin filtra por company_id as consultas, atualizações e exclusões dos models com o campo
+// CompanyID e preenche o campo nas inclusões, usando a empresa do contexto da query
+// (db.WithContext(ctx)). SQL puro (Raw/Exec) não é alterado: use Scope ou filtre company_id.
+//
+// Sem empresa no contexto a query segue sem filtro, como antes do multi-tenant; com Strict,
+// ela falha com ErrTenantRequired para que nenhum caminho sem contexto exponha outra empresa.
+type Plugin struct {
+	Strict bool
+}
+
+// Name identifica o plugin no GORM
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+// Initialize registra os callbacks antes da execução do SQL de cada operação
+func (p Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("tenant:query", p.scope); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant:row", p.scope); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:update", p.scope); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:delete", p.scope); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register("tenant:create", p.stamp)
+}
+
+// companyField retorna o campo CompanyID do model da query, se houver
+func companyField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField("CompanyID")
+}
+
+// companyFor retorna a empresa que deve ser aplicada à query
+func (p Plugin) companyFor(db *gorm.DB) (int, bool) {
+	ctx := db.Statement.Context
+	if IsAllCompanies(ctx) {
+		return 0, false
+	}
+	companyID, ok := CompanyID(ctx)
+	if !ok && p.Strict {
+		db.AddError(errors.ErrTenantRequired)
+	}
+	return companyID, ok
+}
+
+func (p Plugin) scope(db *gorm.DB) {
+	field := companyField(db)
+	if field == nil {
+		return
+	}
+	companyID, ok := p.companyFor(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: companyID},
+	}})
+}
+
+// stamp preenche CompanyID nos registros novos; registros já associados a outra empresa são recusados
+func (p Plugin) stamp(db *gorm.DB) {
+	field := companyField(db)
+	if field == nil {
+		return
+	}
+	companyID, ok := p.companyFor(db)
+	if !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	setCompany := func(record reflect.Value) {
+		value, isZero := field.ValueOf(ctx, record)
+		if isZero {
+			if err := field.Set(ctx, record, companyID); err != nil {
+				db.AddError(err)
+			}
+			return
+		}
+		if id, ok := value.(int); ok && id != companyID {
+			db.AddError(errors.ErrTenantMismatch)
+		}
+	}
+
+	switch rv := reflect.Indirect(db.Statement.ReflectValue); rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			setCompany(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		setCompany(rv)
+	}
+}
@@ -0,0 +1,123 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type invoice struct {
+	ID        int
+	CompanyID int
+	InvoiceNo string
+}
+
+type country struct {
+	ID   int
+	Name string
+}
+
+func dryRunDB(t *testing.T, strict bool) *gorm.DB {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{DryRun: true, SkipDefaultTransaction: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(Plugin{Strict: strict}))
+	return db
+}
+
+func TestPluginScopesQueriesByCompany(t *testing.T) {
+	db := dryRunDB(t, false)
+	ctx := WithCompany(context.Background(), 7)
+
+	var invoices []invoice
+	stmt := db.WithContext(ctx).Where("invoice_no = ?", "FAT-1").Find(&invoices).Statement
+	assert.Contains(t, stmt.SQL.String(), `"invoices"."company_id" = $`)
+	assert.Contains(t, stmt.Vars, 7)
+
+	stmt = db.WithContext(ctx).Model(&invoice{ID: 1}).Update("invoice_no", "FAT-2").Statement
+	assert.Contains(t, stmt.SQL.String(), `"invoices"."company_id" = $`)
+
+	stmt = db.WithContext(ctx).Delete(&invoice{}, 1).Statement
+	assert.Contains(t, stmt.SQL.String(), `"invoices"."company_id" = $`)
+}
+
+func TestPluginIgnoresModelsWithoutCompany(t *testing.T) {
+	db := dryRunDB(t, true)
+	ctx := WithCompany(context.Background(), 7)
+
+	var countries []country
+	stmt := db.WithContext(ctx).Find(&countries).Statement
+	assert.NotContains(t, stmt.SQL.String(), "company_id")
+
+	result := db.Find(&countries)
+	assert.NoError(t, result.Error)
+}
+
+func TestPluginStampsCompanyOnCreate(t *testing.T) {
+	db := dryRunDB(t, false)
+	ctx := WithCompany(context.Background(), 7)
+
+	record := invoice{InvoiceNo: "FAT-1"}
+	require.NoError(t, db.WithContext(ctx).Create(&record).Error)
+	assert.Equal(t, 7, record.CompanyID)
+
+	records := []invoice{{InvoiceNo: "FAT-2"}, {InvoiceNo: "FAT-3", CompanyID: 7}}
+	require.NoError(t, db.WithContext(ctx).Create(&records).Error)
+	assert.Equal(t, 7, records[0].CompanyID)
+
+	other := invoice{InvoiceNo: "FAT-4", CompanyID: 8}
+	assert.ErrorIs(t, db.WithContext(ctx).Create(&other).Error, errors.ErrTenantMismatch)
+}
+
+func TestPluginWithoutCompany(t *testing.T) {
+	var invoices []invoice
+
+	stmt := dryRunDB(t, false).Find(&invoices).Statement
+	assert.NoError(t, stmt.Error)
+	assert.NotContains(t, stmt.SQL.String(), "company_id")
+
+	strict := dryRunDB(t, true)
+	assert.ErrorIs(t, strict.Find(&invoices).Error, errors.ErrTenantRequired)
+
+	stmt = strict.WithContext(AllCompanies(context.Background())).Find(&invoices).Statement
+	assert.NoError(t, stmt.Error)
+	assert.NotContains(t, stmt.SQL.String(), "company_id")
+}
+
+func TestScopeFiltersTable(t *testing.T) {
+	db := dryRunDB(t, false)
+	var total int64
+
+	stmt := db.Table("invoices").Scopes(Scope(WithCompany(context.Background(), 7), "invoices")).Count(&total).Statement
+	assert.Contains(t, stmt.SQL.String(), "invoices.company_id = $")
+
+	err := db.Table("invoices").Scopes(Scope(context.Background(), "invoices")).Count(&total).Error
+	assert.ErrorIs(t, err, errors.ErrTenantRequired)
+}
+
+func TestCondition(t *testing.T) {
+	condition, err := Condition(WithCompany(context.Background(), 7), "i")
+	require.NoError(t, err)
+	assert.Equal(t, "i.company_id = 7", condition)
+
+	condition, err = Condition(WithCompany(context.Background(), 7), "")
+	require.NoError(t, err)
+	assert.Equal(t, "company_id = 7", condition)
+
+	condition, err = Condition(AllCompanies(context.Background()), "i")
+	require.NoError(t, err)
+	assert.Equal(t, "TRUE", condition)
+
+	_, err = Condition(context.Background(), "i")
+	assert.ErrorIs(t, err, errors.ErrTenantRequired)
+}
@@ -0,0 +1,86 @@
+// Package tenant isola os dados por empresa: a empresa da requisição viaja no context.Context
+// (ver middleware.TenantMiddleware) e o Plugin do GORM filtra e preenche company_id nos models
+// que possuem o campo CompanyID.
+package tenant
+
+import (
+	"context"
+	"strconv"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"gorm.io/gorm"
+)
+
+// Column é a coluna que identifica a empresa dona do registro
+const Column = "company_id"
+
+// DefaultCompanyID é a empresa criada pela migração, dona dos dados anteriores ao multi-tenant
+const DefaultCompanyID = 1
+
+type companyKey struct{}
+type allCompaniesKey struct{}
+
+// WithCompany associa a empresa ao contexto
+func WithCompany(ctx context.Context, companyID int) context.Context {
+	return context.WithValue(ctx, companyKey{}, companyID)
+}
+
+// CompanyID retorna a empresa do contexto
+func CompanyID(ctx context.Context) (int, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	id, ok := ctx.Value(companyKey{}).(int)
+	return id, ok && id > 0
+}
+
+// AllCompanies marca o contexto de rotinas que atuam sobre todas as empresas (jobs e
+// consolidações); as consultas desse contexto não são filtradas nem no modo estrito
+func AllCompanies(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allCompaniesKey{}, true)
+}
+
+// IsAllCompanies indica se o contexto foi marcado com AllCompanies
+func IsAllCompanies(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	all, _ := ctx.Value(allCompaniesKey{}).(bool)
+	return all
+}
+
+// Scope filtra pela empresa do contexto as consultas montadas com Table() ou Joins, que o
+// Plugin não consegue associar a um model: db.Scopes(tenant.Scope(ctx, "invoices"))
+func Scope(ctx context.Context, table string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if IsAllCompanies(ctx) {
+			return db
+		}
+		companyID, ok := CompanyID(ctx)
+		if !ok {
+			db.AddError(errors.ErrTenantRequired)
+			return db
+		}
+		return db.Where(table+"."+Column+" = ?", companyID)
+	}
+}
+
+// Condition é o filtro de empresa para SQL puro (Raw, Exec e database/sql), que o Plugin não
+// altera: "alias.company_id = N" com a empresa do contexto, ou TRUE nas rotinas de todas as
+// empresas. Sem empresa no contexto retorna ErrTenantRequired, para que o SQL puro nunca rode
+// sem filtro. O ID é inteiro e vem do contexto, então pode ser concatenado ao SQL.
+func Condition(ctx context.Context, alias string) (string, error) {
+	if IsAllCompanies(ctx) {
+		return "TRUE", nil
+	}
+	companyID, ok := CompanyID(ctx)
+	if !ok {
+		return "", errors.ErrTenantRequired
+	}
+	column := Column
+	if alias != "" {
+		column = alias + "." + Column
+	}
+	return column + " = " + strconv.Itoa(companyID), nil
+}
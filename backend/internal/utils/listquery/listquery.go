@@ -0,0 +1,154 @@
+package listquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"gorm.io/gorm"
+)
+
+// SortField é um critério de ordenação vindo de ?sort=due_date,-grand_total
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Relation descreve um relacionamento que pode ser pedido em ?fields=
+type Relation struct {
+	Preload    string // nome do relacionamento no model (ex.: "Contact")
+	ForeignKey string // coluna necessária para o preload (ex.: "contact_id"); vazio para has-many
+}
+
+// Spec é a lista de campos permitidos de uma entidade para ordenação e seleção
+type Spec struct {
+	Table       string
+	Sortable    []string            // campos aceitos em ?sort= (mesmo nome da coluna)
+	Columns     []string            // campos escalares aceitos em ?fields= (mesmo nome da coluna)
+	Relations   map[string]Relation // relacionamentos aceitos em ?fields=
+	Preloads    []string            // relacionamentos carregados quando ?fields= não é informado
+	DefaultSort string              // ex.: "created_at DESC"
+}
+
+// ParseSort interpreta ?sort=campo,-campo2 ("-" indica ordem decrescente)
+func (s Spec) ParseSort(raw string) ([]SortField, error) {
+	var fields []SortField
+	for _, part := range splitList(raw) {
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !contains(s.Sortable, field.Field) {
+			return nil, fmt.Errorf("%w: %s (permitidos: %s)", errors.ErrInvalidSortField, field.Field, strings.Join(s.Sortable, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// ParseFields interpreta ?fields=id,status,contact
+func (s Spec) ParseFields(raw string) ([]string, error) {
+	fields := splitList(raw)
+	for _, field := range fields {
+		if _, ok := s.Relations[field]; !ok && !contains(s.Columns, field) {
+			return nil, fmt.Errorf("%w: %s (permitidos: %s)", errors.ErrInvalidFieldSelection, field, strings.Join(s.allowedFields(), ", "))
+		}
+	}
+	return fields, nil
+}
+
+// Apply aplica a ordenação e, com ?fields=, seleciona só as colunas e relacionamentos pedidos
+func (s Spec) Apply(query *gorm.DB, sortFields []SortField, fields []string) *gorm.DB {
+	if len(sortFields) == 0 {
+		query = query.Order(s.qualify(s.DefaultSort))
+	}
+	for _, field := range sortFields {
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+		query = query.Order(s.qualify(field.Field) + " " + direction)
+	}
+	// Desempate estável para a paginação
+	query = query.Order(s.qualify("id") + " ASC")
+
+	if len(fields) == 0 {
+		for _, preload := range s.Preloads {
+			query = query.Preload(preload)
+		}
+		return query
+	}
+
+	columns := []string{s.qualify("id")}
+	for _, field := range fields {
+		if relation, ok := s.Relations[field]; ok {
+			if relation.ForeignKey != "" {
+				columns = append(columns, s.qualify(relation.ForeignKey))
+			}
+			query = query.Preload(relation.Preload)
+			continue
+		}
+		columns = append(columns, s.qualify(field))
+	}
+	return query.Select(columns)
+}
+
+// Project reduz cada item da lista aos campos pedidos em ?fields= (o id é sempre mantido)
+func Project(items interface{}, fields []string) ([]map[string]json.RawMessage, error) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	keep := map[string]bool{"id": true}
+	for _, field := range fields {
+		keep[field] = true
+	}
+	for _, row := range rows {
+		for key := range row {
+			if !keep[key] {
+				delete(row, key)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (s Spec) qualify(column string) string {
+	if s.Table == "" || strings.Contains(column, ".") {
+		return column
+	}
+	return s.Table + "." + column
+}
+
+func (s Spec) allowedFields() []string {
+	allowed := append([]string{}, s.Columns...)
+	for name := range s.Relations {
+		allowed = append(allowed, name)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+func splitList(raw string) []string {
+	var values []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package listquery
+
+import (
+	"testing"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type order struct {
+	ID         int      `json:"id"`
+	Status     string   `json:"status"`
+	GrandTotal float64  `json:"grand_total"`
+	ContactID  int      `json:"contact_id"`
+	Contact    *contact `json:"contact,omitempty"`
+}
+
+type contact struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+var orderSpec = Spec{
+	Table:       "orders",
+	Sortable:    []string{"status", "grand_total"},
+	Columns:     []string{"status", "grand_total", "contact_id"},
+	Relations:   map[string]Relation{"contact": {Preload: "Contact", ForeignKey: "contact_id"}},
+	Preloads:    []string{"Contact"},
+	DefaultSort: "created_at DESC",
+}
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	return db
+}
+
+func TestParseSort(t *testing.T) {
+	fields, err := orderSpec.ParseSort("-grand_total, status")
+	require.NoError(t, err)
+	assert.Equal(t, []SortField{{Field: "grand_total", Desc: true}, {Field: "status"}}, fields)
+
+	fields, err = orderSpec.ParseSort("")
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+
+	_, err = orderSpec.ParseSort("status,password")
+	assert.ErrorIs(t, err, errors.ErrInvalidSortField)
+	assert.Contains(t, err.Error(), "password")
+	assert.Contains(t, err.Error(), "grand_total")
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := orderSpec.ParseFields("status,contact")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"status", "contact"}, fields)
+
+	_, err = orderSpec.ParseFields("status,items")
+	assert.ErrorIs(t, err, errors.ErrInvalidFieldSelection)
+}
+
+func TestApplyDefaults(t *testing.T) {
+	db := dryRunDB(t)
+	var orders []order
+
+	stmt := orderSpec.Apply(db.Model(&order{}), nil, nil).Find(&orders).Statement
+	assert.Contains(t, stmt.SQL.String(), "ORDER BY orders.created_at DESC,orders.id ASC")
+	assert.Contains(t, stmt.SQL.String(), "SELECT *")
+}
+
+func TestApplySortAndFields(t *testing.T) {
+	db := dryRunDB(t)
+	var orders []order
+
+	sort := []SortField{{Field: "grand_total", Desc: true}, {Field: "status"}}
+	stmt := orderSpec.Apply(db.Model(&order{}), sort, []string{"status", "contact"}).Find(&orders).Statement
+	sql := stmt.SQL.String()
+	assert.Contains(t, sql, "ORDER BY orders.grand_total DESC,orders.status ASC,orders.id ASC")
+	assert.Contains(t, sql, "SELECT orders.id,orders.status,orders.contact_id FROM")
+	assert.Contains(t, stmt.Preloads, "Contact")
+}
+
+func TestProject(t *testing.T) {
+	items := []order{{ID: 1, Status: "draft", GrandTotal: 10, ContactID: 2, Contact: &contact{ID: 2, Name: "ACME"}}}
+
+	rows, err := Project(items, []string{"status", "contact"})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Len(t, rows[0], 3)
+	assert.JSONEq(t, `"draft"`, string(rows[0]["status"]))
+	assert.JSONEq(t, `{"id":2,"name":"ACME"}`, string(rows[0]["contact"]))
+	assert.NotContains(t, rows[0], "grand_total")
+}
@@ -2,6 +2,7 @@ package testutils
 
 import (
 	"database/sql"
+	"fmt"
 	"testing"
 
 	"ERP-ONSMART/backend/internal/config"
@@ -57,3 +58,20 @@ func (dt *DBTest) Cleanup() {
 		dt.CleanupFn()
 	}
 }
+
+// SkipWithoutPostgres pula o teste quando o PostgreSQL de teste (TEST_DB_*) não está acessível,
+// para que os testes de integração não quebrem `go test ./...` fora do ambiente com banco
+func SkipWithoutPostgres(t testing.TB) {
+	t.Helper()
+
+	cfg := config.LoadTestDBConfig()
+	conn, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=postgres sslmode=disable connect_timeout=2",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password))
+	if err == nil {
+		defer conn.Close()
+		err = conn.Ping()
+	}
+	if err != nil {
+		t.Skipf("PostgreSQL de teste indisponível em %s:%s: %v", cfg.Host, cfg.Port, err)
+	}
+}
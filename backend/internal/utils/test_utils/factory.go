@@ -0,0 +1,191 @@
+package testutils
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	contact "ERP-ONSMART/backend/internal/modules/contact/models"
+	products "ERP-ONSMART/backend/internal/modules/products/models"
+	"ERP-ONSMART/backend/internal/modules/sales/models"
+	"ERP-ONSMART/backend/internal/money"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// As factories abaixo gravam um registro válido com valores padrão e devolvem o model com o ID.
+// Os ajustes (overrides) são aplicados antes da gravação, e os documentos são numerados por
+// uma sequência do processo para não colidir entre testes na mesma transação. Os IDs de
+// documentos relacionados que ficarem zerados não são gravados (NULL), como nos repositórios.
+
+var factorySeq atomic.Int64
+
+func nextSeq() int64 {
+	return factorySeq.Add(1)
+}
+
+// create aplica os ajustes e grava o model sem as associações, omitindo as colunas informadas
+func create[T any](t testing.TB, db *gorm.DB, record *T, overrides []func(*T), omit func(*T) []string) *T {
+	t.Helper()
+	for _, override := range overrides {
+		override(record)
+	}
+	columns := []string{clause.Associations}
+	if omit != nil {
+		columns = append(columns, omit(record)...)
+	}
+	if err := db.Omit(columns...).Create(record).Error; err != nil {
+		t.Fatalf("Erro ao criar %T pela factory: %v", record, err)
+	}
+	return record
+}
+
+// zeroIDs retorna as colunas cujo ID está zerado
+func zeroIDs(ids map[string]int) []string {
+	var columns []string
+	for column, id := range ids {
+		if id == 0 {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// CreateContact grava um cliente pessoa jurídica
+func CreateContact(t testing.TB, db *gorm.DB, overrides ...func(*contact.Contact)) *contact.Contact {
+	n := nextSeq()
+	return create(t, db, &contact.Contact{
+		CompanyID:  tenant.DefaultCompanyID,
+		PersonType: "pj",
+		Type:       "cliente",
+		Name:       fmt.Sprintf("Cliente %d", n),
+		Document:   fmt.Sprintf("%014d", n),
+		Email:      fmt.Sprintf("cliente%d@example.com", n),
+		ZipCode:    "01001000",
+	}, overrides, nil)
+}
+
+// CreateProduct grava um produto ativo em reais
+func CreateProduct(t testing.TB, db *gorm.DB, overrides ...func(*products.Product)) *products.Product {
+	n := nextSeq()
+	return create(t, db, &products.Product{
+		CompanyID:    tenant.DefaultCompanyID,
+		Name:         fmt.Sprintf("Produto %d", n),
+		DetailedName: fmt.Sprintf("Produto de teste %d", n),
+		Status:       "ativo",
+		SKU:          fmt.Sprintf("SKU-%d", n),
+		Coin:         "BRL",
+		Price:        money.FromInt(100),
+		CostPrice:    money.FromInt(60),
+		Stock:        10,
+	}, overrides, nil)
+}
+
+// CreateSalesProcess grava um processo de venda em rascunho; sem ContactID, cria o contato
+func CreateSalesProcess(t testing.TB, db *gorm.DB, overrides ...func(*models.SalesProcess)) *models.SalesProcess {
+	return create(t, db, &models.SalesProcess{
+		CompanyID: tenant.DefaultCompanyID,
+		Status:    "draft",
+	}, append(overrides, withContact(t, db, func(p *models.SalesProcess) *int { return &p.ContactID })), nil)
+}
+
+// CreateQuotation grava uma cotação enviada de R$ 100,00 válida por 30 dias; sem ContactID,
+// cria o contato
+func CreateQuotation(t testing.TB, db *gorm.DB, overrides ...func(*models.Quotation)) *models.Quotation {
+	return create(t, db, &models.Quotation{
+		CompanyID:   tenant.DefaultCompanyID,
+		QuotationNo: fmt.Sprintf("QT-T-%d", nextSeq()),
+		Status:      models.QuotationStatusSent,
+		ExpiryDate:  time.Now().AddDate(0, 0, 30),
+		SubTotal:    money.FromInt(100),
+		GrandTotal:  money.FromInt(100),
+	}, append(overrides, withContact(t, db, func(q *models.Quotation) *int { return &q.ContactID })), nil)
+}
+
+// CreateSalesOrder grava um pedido de venda confirmado de R$ 100,00; sem ContactID, cria o
+// contato
+func CreateSalesOrder(t testing.TB, db *gorm.DB, overrides ...func(*models.SalesOrder)) *models.SalesOrder {
+	return create(t, db, &models.SalesOrder{
+		CompanyID:    tenant.DefaultCompanyID,
+		SONo:         fmt.Sprintf("SO-T-%d", nextSeq()),
+		Status:       models.SOStatusConfirmed,
+		ExpectedDate: time.Now().AddDate(0, 0, 7),
+		SubTotal:     money.FromInt(100),
+		GrandTotal:   money.FromInt(100),
+	}, append(overrides, withContact(t, db, func(so *models.SalesOrder) *int { return &so.ContactID })),
+		func(so *models.SalesOrder) []string {
+			return zeroIDs(map[string]int{"quotation_id": so.QuotationID})
+		})
+}
+
+// CreatePurchaseOrder grava um pedido de compra em rascunho de R$ 60,00; sem ContactID, cria o
+// contato (fornecedor)
+func CreatePurchaseOrder(t testing.TB, db *gorm.DB, overrides ...func(*models.PurchaseOrder)) *models.PurchaseOrder {
+	return create(t, db, &models.PurchaseOrder{
+		CompanyID:    tenant.DefaultCompanyID,
+		PONo:         fmt.Sprintf("PO-T-%d", nextSeq()),
+		Status:       models.POStatusDraft,
+		ExpectedDate: time.Now().AddDate(0, 0, 7),
+		SubTotal:     money.FromInt(60),
+		GrandTotal:   money.FromInt(60),
+	}, append(overrides, withContact(t, db, func(po *models.PurchaseOrder) *int { return &po.ContactID },
+		func(c *contact.Contact) { c.Type = "fornecedor" })),
+		func(po *models.PurchaseOrder) []string {
+			return zeroIDs(map[string]int{"sales_order_id": po.SalesOrderID})
+		})
+}
+
+// CreateDelivery grava uma entrega pendente
+func CreateDelivery(t testing.TB, db *gorm.DB, overrides ...func(*models.Delivery)) *models.Delivery {
+	return create(t, db, &models.Delivery{
+		CompanyID:  tenant.DefaultCompanyID,
+		DeliveryNo: fmt.Sprintf("DLV-T-%d", nextSeq()),
+		Status:     models.DeliveryStatusPending,
+	}, overrides, func(d *models.Delivery) []string {
+		return zeroIDs(map[string]int{"sales_order_id": d.SalesOrderID, "purchase_order_id": d.PurchaseOrderID})
+	})
+}
+
+// CreateInvoice grava uma fatura enviada de R$ 100,00 com vencimento em 30 dias; sem
+// ContactID, cria o contato
+func CreateInvoice(t testing.TB, db *gorm.DB, overrides ...func(*models.Invoice)) *models.Invoice {
+	return create(t, db, &models.Invoice{
+		CompanyID:  tenant.DefaultCompanyID,
+		InvoiceNo:  fmt.Sprintf("INV-T-%d", nextSeq()),
+		Status:     models.InvoiceStatusSent,
+		IssueDate:  time.Now(),
+		DueDate:    time.Now().AddDate(0, 0, 30),
+		SubTotal:   money.FromInt(100),
+		GrandTotal: money.FromInt(100),
+	}, append(overrides, withContact(t, db, func(i *models.Invoice) *int { return &i.ContactID })),
+		func(i *models.Invoice) []string {
+			return zeroIDs(map[string]int{"sales_order_id": i.SalesOrderID})
+		})
+}
+
+// CreatePayment grava um pagamento de R$ 10,00 em pix, sem alocações nem atualização da fatura;
+// sem InvoiceID, cria a fatura
+func CreatePayment(t testing.TB, db *gorm.DB, overrides ...func(*models.Payment)) *models.Payment {
+	return create(t, db, &models.Payment{
+		CompanyID:     tenant.DefaultCompanyID,
+		Amount:        money.FromInt(10),
+		PaymentMethod: "pix",
+	}, append(overrides, func(p *models.Payment) {
+		if p.InvoiceID == 0 {
+			p.InvoiceID = CreateInvoice(t, db).ID
+		}
+	}), nil)
+}
+
+// withContact retorna o ajuste que cria o contato do documento quando o ID ficou zerado; é
+// aplicado depois dos ajustes do teste
+func withContact[T any](t testing.TB, db *gorm.DB, contactID func(*T) *int, overrides ...func(*contact.Contact)) func(*T) {
+	return func(record *T) {
+		if id := contactID(record); *id == 0 {
+			*id = CreateContact(t, db, overrides...).ID
+		}
+	}
+}
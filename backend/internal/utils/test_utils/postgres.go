@@ -0,0 +1,165 @@
+package testutils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ERP-ONSMART/backend/internal/config"
+	"ERP-ONSMART/backend/internal/db"
+	"ERP-ONSMART/backend/internal/db/querystats"
+	"ERP-ONSMART/backend/internal/tenant"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// postgresImage é a imagem do PostgreSQL descartável, a mesma do docker-compose
+const postgresImage = "postgres:16-alpine"
+
+var (
+	postgresOnce sync.Once
+	postgresDB   *gorm.DB
+	postgresErr  error
+)
+
+// RunWithPostgres roda os testes do pacote (m.Run) e retorna o código de saída, para uso no
+// TestMain. Com TEST_DB_DOCKER=1, sobe antes um PostgreSQL descartável em container, numa porta
+// livre, aponta TEST_DB_* para ele e o remove ao final; sem a variável, os testes usam o banco
+// de TEST_DB_* (e os de integração são pulados se ele não estiver acessível).
+func RunWithPostgres(m *testing.M) int {
+	if os.Getenv("TEST_DB_DOCKER") != "1" {
+		return m.Run()
+	}
+
+	containerID, err := startPostgresContainer()
+	if err != nil {
+		log.Printf("Erro ao subir o PostgreSQL de teste em container: %v", err)
+		return 1
+	}
+	defer func() {
+		if out, err := exec.Command("docker", "rm", "-f", containerID).CombinedOutput(); err != nil {
+			log.Printf("Aviso: erro ao remover o container %s: %v: %s", containerID, err, out)
+		}
+	}()
+	return m.Run()
+}
+
+// startPostgresContainer sobe o container, exporta TEST_DB_* e espera o banco aceitar conexões
+func startPostgresContainer() (string, error) {
+	out, err := exec.Command("docker", "run", "-d", "--rm", "-P",
+		"-e", "POSTGRES_USER=erp_user", "-e", "POSTGRES_PASSWORD=changeme", "-e", "POSTGRES_DB=postgres",
+		postgresImage).Output()
+	if err != nil {
+		return "", fmt.Errorf("docker run: %w", err)
+	}
+	containerID := strings.TrimSpace(string(out))
+
+	// A saída tem uma linha por interface ("0.0.0.0:49153"); a porta é a mesma em todas
+	out, err = exec.Command("docker", "port", containerID, "5432/tcp").Output()
+	if err != nil {
+		exec.Command("docker", "rm", "-f", containerID).Run()
+		return "", fmt.Errorf("docker port: %w", err)
+	}
+	binding := strings.SplitN(strings.TrimSpace(string(out)), "\n", 2)[0]
+	port := binding[strings.LastIndex(binding, ":")+1:]
+
+	os.Setenv("TEST_DB_HOST", "localhost")
+	os.Setenv("TEST_DB_PORT", port)
+	os.Setenv("TEST_DB_USER", "erp_user")
+	os.Setenv("TEST_DB_PASSWORD", "changeme")
+
+	// O servidor temporário da inicialização da imagem só escuta no socket local: a primeira
+	// conexão TCP aceita já é a do servidor definitivo
+	cfg := config.LoadTestDBConfig()
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=postgres sslmode=disable connect_timeout=2",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password)
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		conn, err := sql.Open("postgres", dsn)
+		if err == nil {
+			err = conn.Ping()
+			conn.Close()
+		}
+		if err == nil {
+			log.Printf("PostgreSQL de teste no container %.12s, porta %s", containerID, port)
+			return containerID, nil
+		}
+		if time.Now().After(deadline) {
+			exec.Command("docker", "rm", "-f", containerID).Run()
+			return "", fmt.Errorf("o banco não ficou pronto em 60s: %w", err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// PostgresDB retorna a conexão com o banco de teste (TEST_DB_*), criado e migrado uma vez por
+// pacote, sem seeds, e com os plugins que a aplicação registra (querystats e tenant, sem
+// Strict). Sem PostgreSQL acessível, o teste é pulado.
+func PostgresDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	SkipWithoutPostgres(t)
+
+	postgresOnce.Do(func() {
+		postgresDB, postgresErr = openPostgres()
+	})
+	if postgresErr != nil {
+		t.Fatalf("Erro ao preparar o PostgreSQL de teste: %v", postgresErr)
+	}
+	return postgresDB
+}
+
+func openPostgres() (*gorm.DB, error) {
+	if err := SetupTestDB(); err != nil {
+		return nil, err
+	}
+	if err := db.RunTestMigrations(); err != nil {
+		return nil, err
+	}
+
+	conn, err := sql.Open("postgres", config.LoadTestDBConfig().DSN())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao banco de dados de teste: %w", err)
+	}
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("erro ao criar conexão Gorm: %w", err)
+	}
+	if err := gormDB.Use(querystats.Plugin{}); err != nil {
+		return nil, err
+	}
+	if err := gormDB.Use(tenant.Plugin{}); err != nil {
+		return nil, err
+	}
+
+	// CleanTestTables (NewDBTest) também esvazia companies: a empresa padrão das factories é
+	// recriada caso outro teste tenha usado o mesmo banco
+	if err := gormDB.Exec(`INSERT INTO companies (id, name) VALUES (?, 'Empresa padrão') ON CONFLICT (id) DO NOTHING`,
+		tenant.DefaultCompanyID).Error; err != nil {
+		return nil, fmt.Errorf("erro ao criar a empresa padrão: %w", err)
+	}
+	return gormDB, nil
+}
+
+// PostgresTx retorna uma transação no banco de teste desfeita ao final do teste, para que cada
+// teste parta do mesmo estado e os testes não dependam da ordem de execução. É a transação de
+// uma unidade de trabalho (db.BeginUnitOfWork): o Begin e o Transaction dos repositórios
+// criados sobre ela viram savepoints, e o commit deles não confirma nada no banco.
+func PostgresTx(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	tx, committer, err := db.BeginUnitOfWork(context.Background(), PostgresDB(t))
+	if err != nil {
+		t.Fatalf("Erro ao abrir a transação de teste: %v", err)
+	}
+	t.Cleanup(func() { committer.Rollback() })
+	return tx
+}
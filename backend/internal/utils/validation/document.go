@@ -0,0 +1,63 @@
+package validation
+
+import "strings"
+
+// OnlyDigits remove pontuação e espaços de documentos como CPF e CNPJ
+func OnlyDigits(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// IsValidCPF verifica os dígitos verificadores do CPF (com ou sem máscara)
+func IsValidCPF(value string) bool {
+	digits := OnlyDigits(value)
+	if len(digits) != 11 || allSameDigit(digits) {
+		return false
+	}
+	return checkDigit(digits[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[9] &&
+		checkDigit(digits[:10], []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[10]
+}
+
+// IsValidCNPJ verifica os dígitos verificadores do CNPJ (com ou sem máscara)
+func IsValidCNPJ(value string) bool {
+	digits := OnlyDigits(value)
+	if len(digits) != 14 || allSameDigit(digits) {
+		return false
+	}
+	return checkDigit(digits[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[12] &&
+		checkDigit(digits[:13], []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[13]
+}
+
+// IsValidCPFOrCNPJ aceita tanto CPF quanto CNPJ, conforme a quantidade de dígitos
+func IsValidCPFOrCNPJ(value string) bool {
+	switch len(OnlyDigits(value)) {
+	case 11:
+		return IsValidCPF(value)
+	case 14:
+		return IsValidCNPJ(value)
+	}
+	return false
+}
+
+// checkDigit calcula o dígito verificador pelo módulo 11
+func checkDigit(digits string, weights []int) byte {
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
+
+// Sequências como 000.000.000-00 passam no cálculo, mas não são documentos válidos
+func allSameDigit(digits string) bool {
+	return strings.Count(digits, digits[:1]) == len(digits)
+}
@@ -0,0 +1,124 @@
+package validation
+
+import (
+	"strings"
+	"unicode"
+
+	"ERP-ONSMART/backend/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Idiomas suportados nas mensagens de validação
+const (
+	LangPT = "pt"
+	LangEN = "en"
+)
+
+// DefaultLanguage é usado quando o cliente não envia Accept-Language ou pede um idioma não suportado
+const DefaultLanguage = LangPT
+
+// Language escolhe o idioma das mensagens a partir do header Accept-Language
+// (ex.: "en-US,en;q=0.9,pt-BR;q=0.8"), respeitando os pesos q (ver i18n.Parse)
+func Language(acceptLanguage string) string {
+	return Short(i18n.Parse(acceptLanguage))
+}
+
+// Short converte o idioma do i18n (pt-BR, en-US) no idioma das mensagens de validação
+func Short(lang string) string {
+	if i18n.Normalize(lang) == i18n.EnUS {
+		return LangEN
+	}
+	return LangPT
+}
+
+// Message traduz a regra reprovada para o idioma informado
+func Message(fe validator.FieldError, lang string) string {
+	param := fe.Param()
+	if strings.HasSuffix(fe.Tag(), "field") {
+		// gtefield=IssueDate referencia o campo do struct; expõe o nome usado no JSON
+		param = snakeCase(param)
+	}
+
+	if lang == LangEN {
+		return englishMessage(fe.Tag(), param)
+	}
+	return portugueseMessage(fe.Tag(), param)
+}
+
+func portugueseMessage(tag, param string) string {
+	switch tag {
+	case "required":
+		return "campo obrigatório"
+	case "email":
+		return "e-mail inválido"
+	case "oneof":
+		return "valor deve ser um de: " + param
+	case "min", "gte":
+		return "valor mínimo: " + param
+	case "max", "lte":
+		return "valor máximo: " + param
+	case "gt":
+		return "valor deve ser maior que " + param
+	case "lt":
+		return "valor deve ser menor que " + param
+	case "gtefield":
+		return "deve ser maior ou igual a " + param
+	case "gtfield":
+		return "deve ser maior que " + param
+	case "cpf":
+		return "CPF inválido"
+	case "cnpj":
+		return "CNPJ inválido"
+	case "cpf_cnpj":
+		return "CPF/CNPJ inválido"
+	default:
+		return "valor inválido (" + tag + ")"
+	}
+}
+
+func englishMessage(tag, param string) string {
+	switch tag {
+	case "required":
+		return "field is required"
+	case "email":
+		return "invalid e-mail"
+	case "oneof":
+		return "value must be one of: " + param
+	case "min", "gte":
+		return "minimum value: " + param
+	case "max", "lte":
+		return "maximum value: " + param
+	case "gt":
+		return "value must be greater than " + param
+	case "lt":
+		return "value must be less than " + param
+	case "gtefield":
+		return "must be greater than or equal to " + param
+	case "gtfield":
+		return "must be greater than " + param
+	case "cpf":
+		return "invalid CPF"
+	case "cnpj":
+		return "invalid CNPJ"
+	case "cpf_cnpj":
+		return "invalid CPF/CNPJ"
+	default:
+		return "invalid value (" + tag + ")"
+	}
+}
+
+// snakeCase converte o nome do campo Go (IssueDate) para o formato do JSON (issue_date)
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
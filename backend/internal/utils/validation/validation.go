@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"ERP-ONSMART/backend/internal/money"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	engine     *validator.Validate
+	engineOnce sync.Once
+)
+
+// Struct valida as tags `validate` do DTO, incluindo as regras de domínio (cpf, cnpj, cpf_cnpj)
+func Struct(obj interface{}) error {
+	engineOnce.Do(func() {
+		engine = validator.New()
+		Register(engine)
+	})
+	return engine.Struct(obj)
+}
+
+// Register adiciona as regras de domínio e o nome JSON dos campos a um validador existente
+// (ex.: o engine do binding do Gin, que usa as tags `binding`). Os valores money.Decimal são
+// validados pelo número (gt=0, gte=0).
+func Register(v *validator.Validate) {
+	v.RegisterTagNameFunc(jsonFieldName)
+	v.RegisterCustomTypeFunc(decimalValue, money.Decimal{})
+	v.RegisterValidation("cpf", func(fl validator.FieldLevel) bool {
+		return IsValidCPF(fl.Field().String())
+	})
+	v.RegisterValidation("cnpj", func(fl validator.FieldLevel) bool {
+		return IsValidCNPJ(fl.Field().String())
+	})
+	v.RegisterValidation("cpf_cnpj", func(fl validator.FieldLevel) bool {
+		return IsValidCPFOrCNPJ(fl.Field().String())
+	})
+}
+
+// FieldPath remove o nome do struct raiz do caminho do campo (ex.: Lead.items[0].quantity)
+func FieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+func decimalValue(field reflect.Value) interface{} {
+	if d, ok := field.Interface().(money.Decimal); ok {
+		return d.Float64()
+	}
+	return nil
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidCPF(t *testing.T) {
+	assert.True(t, IsValidCPF("529.982.247-25"))
+	assert.True(t, IsValidCPF("52998224725"))
+	assert.False(t, IsValidCPF("529.982.247-24"))
+	assert.False(t, IsValidCPF("111.111.111-11"))
+	assert.False(t, IsValidCPF("1234"))
+}
+
+func TestIsValidCNPJ(t *testing.T) {
+	assert.True(t, IsValidCNPJ("11.222.333/0001-81"))
+	assert.True(t, IsValidCNPJ("11222333000181"))
+	assert.False(t, IsValidCNPJ("11.222.333/0001-80"))
+	assert.False(t, IsValidCNPJ("00000000000000"))
+}
+
+func TestIsValidCPFOrCNPJ(t *testing.T) {
+	assert.True(t, IsValidCPFOrCNPJ("529.982.247-25"))
+	assert.True(t, IsValidCPFOrCNPJ("11.222.333/0001-81"))
+	assert.False(t, IsValidCPFOrCNPJ("123456789012"))
+}
+
+func TestLanguage(t *testing.T) {
+	assert.Equal(t, LangPT, Language(""))
+	assert.Equal(t, LangEN, Language("en-US,en;q=0.9"))
+	assert.Equal(t, LangPT, Language("pt-BR,pt;q=0.9,en;q=0.8"))
+	assert.Equal(t, LangEN, Language("fr-FR,en;q=0.5,pt;q=0.3"))
+	assert.Equal(t, LangPT, Language("de-DE"))
+}
+
+func TestStructMessages(t *testing.T) {
+	type invoice struct {
+		Document  string    `json:"document" validate:"required,cpf_cnpj"`
+		Quantity  int       `json:"quantity" validate:"gt=0"`
+		IssueDate time.Time `json:"issue_date"`
+		DueDate   time.Time `json:"due_date" validate:"gtefield=IssueDate"`
+	}
+
+	issue := time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC)
+	err := Struct(invoice{Document: "123", IssueDate: issue, DueDate: issue.AddDate(0, 0, -1)})
+
+	var validationErrors validator.ValidationErrors
+	require.ErrorAs(t, err, &validationErrors)
+	require.Len(t, validationErrors, 3)
+
+	messages := map[string][2]string{}
+	for _, fe := range validationErrors {
+		messages[FieldPath(fe)] = [2]string{Message(fe, LangPT), Message(fe, LangEN)}
+	}
+	assert.Equal(t, [2]string{"CPF/CNPJ inválido", "invalid CPF/CNPJ"}, messages["document"])
+	assert.Equal(t, [2]string{"valor deve ser maior que 0", "value must be greater than 0"}, messages["quantity"])
+	assert.Equal(t, [2]string{"deve ser maior ou igual a issue_date", "must be greater than or equal to issue_date"}, messages["due_date"])
+}
@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"github.com/spf13/viper"
+)
+
+// Cofre das credenciais gravadas no banco (senhas e chaves privadas dos servidores dos
+// parceiros): AES-256-GCM com a chave VAULT_KEY, que fica só na configuração do servidor. Um
+// dump do banco, um backup ou uma exportação não bastam para ler as credenciais.
+
+// KeySize é o tamanho da chave, em bytes, antes da codificação em base64
+const KeySize = 32
+
+// sealedPrefix identifica o formato do valor cifrado, para permitir trocar o algoritmo depois
+const sealedPrefix = "v1:"
+
+// Seal cifra o texto com a chave configurada; texto vazio continua vazio
+func Seal(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.WrapError(err, "falha ao gerar o nonce")
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decifra um valor produzido por Seal; valor vazio continua vazio
+func Open(sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	encoded, ok := strings.CutPrefix(sealed, sealedPrefix)
+	if !ok {
+		return "", fmt.Errorf("credencial cifrada em formato desconhecido")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("credencial cifrada inválida: %w", err)
+	}
+
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+	if len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("credencial cifrada inválida")
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("não foi possível abrir a credencial: a VAULT_KEY mudou depois da gravação?")
+	}
+	return string(plaintext), nil
+}
+
+// ParseKey decodifica a chave em base64 e confere o tamanho
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("a chave deve estar em base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("a chave deve ter %d bytes (tem %d)", KeySize, len(key))
+	}
+	return key, nil
+}
+
+func newAEAD() (cipher.AEAD, error) {
+	encoded := viper.GetString("VAULT_KEY")
+	if encoded == "" {
+		return nil, errors.ErrVaultNotConfigured
+	}
+	key, err := ParseKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("VAULT_KEY inválida: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,80 @@
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"ERP-ONSMART/backend/internal/errors"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	encoded := base64.StdEncoding.EncodeToString(key)
+	viper.Set("VAULT_KEY", encoded)
+	t.Cleanup(func() { viper.Set("VAULT_KEY", "") })
+	return encoded
+}
+
+func TestSealAndOpen(t *testing.T) {
+	setKey(t)
+
+	sealed, err := Seal("s3nha")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "s3nha")
+	assert.Regexp(t, `^v1:`, sealed)
+
+	// O nonce é aleatório: o mesmo texto gera valores diferentes
+	again, err := Seal("s3nha")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+
+	opened, err := Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "s3nha", opened)
+
+	empty, err := Seal("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+	opened, err = Open("")
+	require.NoError(t, err)
+	assert.Empty(t, opened)
+}
+
+func TestOpenRejectsOtherKeyAndTampering(t *testing.T) {
+	setKey(t)
+	sealed, err := Seal("s3nha")
+	require.NoError(t, err)
+
+	tampered := []byte(sealed)
+	tampered[len(tampered)-2] ^= 0x01
+	_, err = Open(string(tampered))
+	assert.Error(t, err)
+	_, err = Open("s3nha")
+	assert.ErrorContains(t, err, "formato desconhecido")
+
+	setKey(t)
+	_, err = Open(sealed)
+	assert.ErrorContains(t, err, "VAULT_KEY mudou")
+}
+
+func TestKeyConfiguration(t *testing.T) {
+	viper.Set("VAULT_KEY", "")
+	_, err := Seal("s3nha")
+	assert.ErrorIs(t, err, errors.ErrVaultNotConfigured)
+
+	viper.Set("VAULT_KEY", base64.StdEncoding.EncodeToString([]byte("curta")))
+	t.Cleanup(func() { viper.Set("VAULT_KEY", "") })
+	_, err = Seal("s3nha")
+	assert.ErrorContains(t, err, "deve ter 32 bytes")
+
+	_, err = ParseKey("não é base64")
+	assert.ErrorContains(t, err, "base64")
+}
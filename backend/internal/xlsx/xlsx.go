@@ -0,0 +1,262 @@
+// Package xlsx gera planilhas do Excel (Office Open XML) simples sem dependências externas:
+// textos e números, com negrito opcional e números no formato #,##0.00.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Estilos declarados em styles.xml, na ordem de cellXfs
+const (
+	styleDefault = iota
+	styleBold
+	styleNumber
+	styleBoldNumber
+)
+
+// Tamanho máximo do nome da aba aceito pelo Excel
+const maxSheetName = 31
+
+// Workbook é uma pasta de trabalho com uma ou mais abas
+type Workbook struct {
+	sheets []*Sheet
+}
+
+// Sheet é uma aba da planilha, preenchida linha a linha
+type Sheet struct {
+	name   string
+	rows   [][]cell
+	widths map[int]float64
+}
+
+type cell struct {
+	value interface{}
+	bold  bool
+}
+
+// NewWorkbook cria uma pasta de trabalho vazia
+func NewWorkbook() *Workbook {
+	return &Workbook{}
+}
+
+// AddSheet acrescenta uma aba; nomes longos são cortados no limite do Excel
+func (w *Workbook) AddSheet(name string) *Sheet {
+	runes := []rune(strings.TrimSpace(name))
+	if len(runes) > maxSheetName {
+		runes = runes[:maxSheetName]
+	}
+	if len(runes) == 0 {
+		runes = []rune(fmt.Sprintf("Planilha%d", len(w.sheets)+1))
+	}
+	sheet := &Sheet{name: string(runes), widths: map[int]float64{}}
+	w.sheets = append(w.sheets, sheet)
+	return sheet
+}
+
+// AddRow acrescenta uma linha. Números (int e float64) viram células numéricas; nil deixa a
+// célula vazia e os demais valores são gravados como texto.
+func (s *Sheet) AddRow(bold bool, values ...interface{}) {
+	row := make([]cell, len(values))
+	for i, value := range values {
+		row[i] = cell{value: value, bold: bold}
+	}
+	s.rows = append(s.rows, row)
+}
+
+// SetColumnWidth define a largura da coluna (começando em 0), em caracteres
+func (s *Sheet) SetColumnWidth(column int, width float64) {
+	s.widths[column] = width
+}
+
+// part é um arquivo do pacote .xlsx (um zip de XMLs)
+type part struct {
+	name    string
+	content string
+}
+
+// Write grava a pasta de trabalho no formato .xlsx
+func (w *Workbook) Write(out io.Writer) error {
+	if len(w.sheets) == 0 {
+		return fmt.Errorf("xlsx: nenhuma aba para gerar")
+	}
+
+	files := []part{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", w.workbook()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+		{"xl/styles.xml", styles},
+	}
+	for i, sheet := range w.sheets {
+		files = append(files, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.xml()})
+	}
+
+	archive := zip.NewWriter(out)
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, file.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// Bytes gera o arquivo .xlsx em memória
+func (w *Workbook) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const rootRels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// styles declara a fonte normal e a negrita e os estilos de célula: padrão, negrito, número
+// (formato embutido 4, #,##0.00) e número em negrito
+const styles = xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1" applyNumberFormat="1"/>` +
+	`</cellXfs></styleSheet>`
+
+func (w *Workbook) contentTypes() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func (w *Workbook) workbook() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range w.sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheet.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func (w *Workbook) workbookRels() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.sheets)+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func (s *Sheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	if len(s.widths) > 0 {
+		b.WriteString(`<cols>`)
+		for column := 0; column <= maxKey(s.widths); column++ {
+			if width, ok := s.widths[column]; ok {
+				fmt.Fprintf(&b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, column+1, column+1, strconv.FormatFloat(width, 'f', -1, 64))
+			}
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := ColumnName(c) + strconv.Itoa(r+1)
+			number, isNumber := numeric(cell.value)
+			switch {
+			case cell.value == nil:
+				continue
+			case isNumber:
+				style := styleNumber
+				if cell.bold {
+					style = styleBoldNumber
+				}
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(number, 'f', -1, 64))
+			default:
+				style := styleDefault
+				if cell.bold {
+					style = styleBold
+				}
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(fmt.Sprint(cell.value)))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// ColumnName converte o índice da coluna (começando em 0) na letra do Excel: 0 = A, 26 = AA
+func ColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func numeric(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func maxKey(values map[int]float64) int {
+	max := 0
+	for key := range values {
+		if key > max {
+			max = key
+		}
+	}
+	return max
+}
+
+func escape(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
@@ -0,0 +1,60 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", ColumnName(0))
+	assert.Equal(t, "Z", ColumnName(25))
+	assert.Equal(t, "AA", ColumnName(26))
+	assert.Equal(t, "AZ", ColumnName(51))
+	assert.Equal(t, "BA", ColumnName(52))
+}
+
+func TestWrite(t *testing.T) {
+	workbook := NewWorkbook()
+	sheet := workbook.AddSheet("Demonstração do resultado do exercício 2026")
+	sheet.SetColumnWidth(0, 40)
+	sheet.AddRow(true, "Conta", "Valor")
+	sheet.AddRow(false, "Receita <bruta> & serviços", 1234.5)
+	sheet.AddRow(false, nil, 10)
+
+	data, err := workbook.Bytes()
+	require.NoError(t, err)
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		assert.Contains(t, files, name)
+	}
+	assert.Contains(t, files["xl/workbook.xml"], `name="Demonstração do resultado do ex"`, "nome limitado a 31 caracteres")
+
+	sheetXML := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheetXML, `<col min="1" max="1" width="40" customWidth="1"/>`)
+	assert.Contains(t, sheetXML, `<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Conta</t></is></c>`)
+	assert.Contains(t, sheetXML, `Receita &lt;bruta&gt; &amp; serviços`)
+	assert.Contains(t, sheetXML, `<c r="B2" s="2"><v>1234.5</v></c>`)
+	assert.Contains(t, sheetXML, `<c r="B3" s="2"><v>10</v></c>`)
+	assert.False(t, strings.Contains(sheetXML, `r="A3"`), "célula nil fica vazia")
+
+	_, err = NewWorkbook().Bytes()
+	assert.Error(t, err)
+}